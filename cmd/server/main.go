@@ -68,6 +68,8 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -81,12 +83,15 @@ import (
 	"github.com/msgfy/linktor/internal/adapters/ai/anthropic"
 	"github.com/msgfy/linktor/internal/adapters/ai/ollama"
 	"github.com/msgfy/linktor/internal/adapters/ai/openai"
+	"github.com/msgfy/linktor/internal/adapters/custom"
 	"github.com/msgfy/linktor/internal/adapters/email"
 	"github.com/msgfy/linktor/internal/adapters/facebook"
 	"github.com/msgfy/linktor/internal/adapters/instagram"
 	"github.com/msgfy/linktor/internal/adapters/rcs"
 	"github.com/msgfy/linktor/internal/adapters/sms"
+	"github.com/msgfy/linktor/internal/adapters/teams"
 	"github.com/msgfy/linktor/internal/adapters/telegram"
+	"github.com/msgfy/linktor/internal/adapters/wallet"
 	"github.com/msgfy/linktor/internal/adapters/webchat"
 	"github.com/msgfy/linktor/internal/adapters/whatsapp"
 	whatsappofficial "github.com/msgfy/linktor/internal/adapters/whatsapp_official"
@@ -95,18 +100,32 @@ import (
 	"github.com/msgfy/linktor/internal/application/service"
 	"github.com/msgfy/linktor/internal/application/usecase"
 	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/antivirus"
+	"github.com/msgfy/linktor/internal/infrastructure/calendar"
 	"github.com/msgfy/linktor/internal/infrastructure/config"
 	"github.com/msgfy/linktor/internal/infrastructure/database"
+	"github.com/msgfy/linktor/internal/infrastructure/egress"
+	"github.com/msgfy/linktor/internal/infrastructure/encryption"
+	"github.com/msgfy/linktor/internal/infrastructure/lock"
 	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/internal/infrastructure/ocr"
+	"github.com/msgfy/linktor/internal/infrastructure/scripting"
+	"github.com/msgfy/linktor/internal/infrastructure/secrets"
 	storageLib "github.com/msgfy/linktor/internal/infrastructure/storage"
+	"github.com/msgfy/linktor/internal/infrastructure/stt"
+	"github.com/msgfy/linktor/internal/infrastructure/transcode"
+	"github.com/msgfy/linktor/internal/infrastructure/webhook"
 	"github.com/msgfy/linktor/internal/whatsapp/analytics"
 	"github.com/msgfy/linktor/internal/whatsapp/calling"
 	"github.com/msgfy/linktor/internal/whatsapp/ctwa"
 	"github.com/msgfy/linktor/internal/whatsapp/payments"
+	"github.com/msgfy/linktor/pkg/errors"
 	"github.com/msgfy/linktor/pkg/logger"
 	"github.com/msgfy/linktor/pkg/plugin"
 
 	"github.com/go-redis/redis/v8"
+	goredis "github.com/redis/go-redis/v9"
 )
 
 func main() {
@@ -151,17 +170,23 @@ func main() {
 		logger.Error("Failed to seed database: " + err.Error())
 	}
 
-	// Initialize NATS (optional - will work without it but messaging features disabled)
+	// Initialize NATS. When it is down the client keeps connecting in the background:
+	// publishes are held briefly and consumers start once it is reachable. Messaging
+	// is only disabled when the NATS settings are unusable.
 	logger.Info("Connecting to NATS JetStream...")
 	natsClient, err := nats.NewClient(&cfg.NATS)
 	var producer *nats.Producer
 	var consumer *nats.Consumer
 	if err != nil {
-		logger.Warn(fmt.Sprintf("Failed to connect to NATS (%s): %v - messaging features disabled", cfg.NATS.URL, err))
+		logger.Warn(fmt.Sprintf("Failed to set up NATS (%s): %v - messaging features disabled", cfg.NATS.URL, err))
 	} else {
 		defer natsClient.Close()
+		if !natsClient.IsConnected() {
+			logger.Warn(fmt.Sprintf("NATS is unreachable (%s) - messaging starts once it connects", cfg.NATS.URL))
+		}
 		producer = nats.NewProducer(natsClient)
 		consumer = nats.NewConsumer(natsClient)
+		consumer.SetConcurrency(cfg.NATS.Concurrency)
 	}
 
 	// Initialize repositories
@@ -177,6 +202,21 @@ func main() {
 	aiResponseRepo := database.NewAIResponseRepository(db)
 	kbRepo := database.NewKnowledgeBaseRepository(db)
 	kiRepo := database.NewKnowledgeItemRepository(db)
+	knowledgeInboxRepo := database.NewKnowledgeInboxRepository(db)
+	knowledgeFeedbackRepo := database.NewKnowledgeFeedbackRepository(db)
+	knowledgeCurationRepo := database.NewKnowledgeCurationRepository(db)
+	knowledgeVersionRepo := database.NewKnowledgeVersionRepository(db)
+	copilotRepo := database.NewCopilotRepository(db)
+	conversationSummaryRepo := database.NewConversationSummaryRepository(db)
+	maintenanceRepo := database.NewMaintenanceRepository(db)
+	channelProbeRepo := database.NewChannelProbeRepository(db)
+	channelTokenRepo := database.NewChannelTokenRepository(db)
+	channelCredentialHealthRepo := database.NewChannelCredentialHealthRepository(db)
+	notificationRepo := database.NewNotificationRepository(db)
+	channelMaintenanceRepo := database.NewChannelMaintenanceRepository(db)
+	complianceRepo := database.NewComplianceRepository(db)
+	botToolRepo := database.NewBotToolRepository(db)
+	botExperimentRepo := database.NewBotExperimentRepository(db)
 	flowRepo := database.NewFlowRepository(db)
 	analyticsRepo := database.NewAnalyticsRepository(db)
 	templateRepo := database.NewTemplateRepository(db)
@@ -184,6 +224,64 @@ func main() {
 	paymentRepo := database.NewPaymentRepository(db)
 	observabilityRepo := database.NewObservabilityRepository(db)
 	apiKeyRepo := database.NewAPIKeyRepository(db)
+	phoneNumberRepo := database.NewWhatsAppPhoneNumberRepository(db)
+	conversationCostRepo := database.NewWhatsAppConversationCostRepository(db)
+	postbackRepo := database.NewPostbackRepository(db)
+	qaRepo := database.NewQARepository(db)
+	statusPageRepo := database.NewStatusPageRepository(db)
+	widgetSettingsRepo := database.NewWidgetSettingsRepository(db)
+	onboardingRepo := database.NewOnboardingRepository(db)
+	messageHookRepo := database.NewMessageHookRepository(db)
+	conversationFormRepo := database.NewConversationFormRepository(db)
+	newsletterRepo := database.NewNewsletterRepository(db)
+	documentPipelineRepo := database.NewDocumentPipelineRepository(db)
+	encryptionKeyRepo := database.NewEncryptionKeyRepository(db)
+	jobRepo := database.NewJobRepository(db)
+	bookingCalendarRepo := database.NewBookingCalendarRepository(db)
+	bookingRepo := database.NewBookingRepository(db)
+	linkedObjectRepo := database.NewLinkedObjectRepository(db)
+	linkedObjectConnectorRepo := database.NewLinkedObjectConnectorRepository(db)
+	watchRepo := database.NewWatchRepository(db)
+	conversationOperationRepo := database.NewConversationOperationRepository(db)
+	conversationReassignmentRepo := database.NewConversationReassignmentRepository(db)
+	channelLeaseRepo := database.NewChannelLeaseRepository(db)
+	contactSegmentRepo := database.NewContactSegmentRepository(db)
+	contactMergeRepo := database.NewContactMergeRepository(db)
+	contactFieldRepo := database.NewContactFieldRepository(db)
+	walletPassTemplateRepo := database.NewWalletPassTemplateRepository(db)
+	walletPassRepo := database.NewWalletPassRepository(db)
+	webhookSubscriptionRepo := database.NewWebhookSubscriptionRepository(db)
+	webhookDeliveryRepo := database.NewWebhookDeliveryRepository(db)
+	labelRepo := database.NewLabelRepository(db)
+	dispositionRepo := database.NewDispositionRepository(db)
+	conversationReopenRepo := database.NewConversationReopenRepository(db)
+	embeddingCacheRepo := database.NewEmbeddingCacheRepository(db)
+	routingRepo := database.NewRoutingRepository(db)
+	automationTraceRepo := database.NewAutomationTraceRepository(db)
+	quotaRepo := database.NewQuotaRepository(db)
+	uploadRepo := database.NewUploadRepository(db)
+	callTranscriptRepo := database.NewCallTranscriptRepository(db)
+
+	// Initialize per-tenant encryption of message content; the master key protects
+	// platform managed and tenant supplied keys, Vault transit acts as external KMS
+	var masterKey encryption.KeyWrapper
+	if encoded := os.Getenv("ENCRYPTION_MASTER_KEY"); encoded != "" {
+		if wrapper, err := encryption.NewAESWrapperFromBase64(encoded); err != nil {
+			logger.Warn("Invalid ENCRYPTION_MASTER_KEY, platform and tenant keys disabled: " + err.Error())
+		} else {
+			masterKey = wrapper
+		}
+	}
+	encryptionService := service.NewEncryptionService(encryptionKeyRepo, masterKey)
+	if vaultAddr := os.Getenv("VAULT_ADDR"); vaultAddr != "" {
+		encryptionService.SetKMS(encryption.NewVaultTransitWrapper(vaultAddr, os.Getenv("VAULT_TOKEN"), os.Getenv("VAULT_TRANSIT_MOUNT")))
+	}
+	messageRepo.SetCipher(encryptionService)
+
+	// Resolve provider credentials stored as secret://<backend>/<path>#<field>
+	// references from Vault KV or AWS Secrets Manager
+	secretResolver := newSecretResolver()
+	channelRepo.SetSecretResolver(secretResolver)
 
 	// Initialize services
 	logger.Info("Initializing services...")
@@ -191,33 +289,72 @@ func main() {
 	authService := service.NewAuthService(userRepo, &cfg.JWT)
 	userService := service.NewUserService(userRepo, tenantRepo)
 	apiKeyService := service.NewAPIKeyService(apiKeyRepo)
+	tenantConfigService := service.NewTenantConfigService(channelRepo, botRepo, flowRepo, messageHookRepo)
+	// Jobs run in-process when NATS is down, so never hand them a nil producer
+	var jobEvents nats.Publisher
+	if producer != nil {
+		jobEvents = producer
+	}
+	jobService := service.NewJobService(jobRepo, jobEvents)
+	jobService.Register(service.JobTypeConfigImport, tenantConfigService.RunImportJob)
+	// Meter API calls, AI requests and renders against plan quotas
+	quotaService := service.NewQuotaService(quotaRepo, tenantRepo, jobEvents)
+	// Organizations group tenants as workspaces sharing accounts and billing
+	organizationService := service.NewOrganizationService(database.NewOrganizationRepository(db), tenantRepo, userRepo, quotaRepo)
+	authService.SetWorkspaceDirectory(organizationService)
+	// TOTP second factor, enforced per tenant by the mfa_required settings
+	authService.SetMFARepository(database.NewUserMFARepository(db))
+	authService.SetTenantRepository(tenantRepo)
 
 	// Initialize AI services
 	logger.Info("Initializing AI services...")
 	aiFactory := service.GetAIProviderFactory()
+	// Meter AI tokens and cost per tenant and pause AI features over the hard budget
+	aiUsageService := service.NewAIUsageService(database.NewAIUsageRepository(db), tenantRepo, jobEvents)
+	aiFactory.SetUsageMeter(aiUsageService)
+
+	// API keys may reference a secret; providers are registered again with the
+	// new key when it is rotated
+	aiKeyProviders := map[string]func(apiKey string){
+		"OPENAI_API_KEY": func(apiKey string) {
+			aiFactory.Register(openai.NewProvider(&openai.ProviderConfig{
+				APIKey:       apiKey,
+				OrgID:        os.Getenv("OPENAI_ORG_ID"),
+				DefaultModel: os.Getenv("OPENAI_DEFAULT_MODEL"),
+			}))
+		},
+		"ANTHROPIC_API_KEY": func(apiKey string) {
+			aiFactory.Register(anthropic.NewProvider(&anthropic.ProviderConfig{
+				APIKey:       apiKey,
+				DefaultModel: os.Getenv("ANTHROPIC_DEFAULT_MODEL"),
+			}))
+		},
+	}
+	secretResolver.OnRotate(func(ctx context.Context, rotation secrets.Rotation) {
+		for name, register := range aiKeyProviders {
+			if !rotation.Affects(os.Getenv(name)) {
+				continue
+			}
+			if apiKey := resolveSecretEnv(ctx, secretResolver, name); apiKey != "" {
+				register(apiKey)
+				logger.Info("AI provider registered with rotated " + name)
+			}
+		}
+	})
 
 	// Register OpenAI provider if configured
-	openAIKey := os.Getenv("OPENAI_API_KEY")
+	openAIKey := resolveSecretEnv(context.Background(), secretResolver, "OPENAI_API_KEY")
 	if openAIKey != "" {
-		openAIProvider := openai.NewProvider(&openai.ProviderConfig{
-			APIKey:       openAIKey,
-			OrgID:        os.Getenv("OPENAI_ORG_ID"),
-			DefaultModel: os.Getenv("OPENAI_DEFAULT_MODEL"),
-		})
-		aiFactory.Register(openAIProvider)
+		aiKeyProviders["OPENAI_API_KEY"](openAIKey)
 		logger.Info("OpenAI provider registered")
 	} else {
 		logger.Warn("OpenAI API key not configured - AI features limited")
 	}
 
 	// Register Anthropic provider if configured
-	anthropicKey := os.Getenv("ANTHROPIC_API_KEY")
+	anthropicKey := resolveSecretEnv(context.Background(), secretResolver, "ANTHROPIC_API_KEY")
 	if anthropicKey != "" {
-		anthropicProvider := anthropic.NewProvider(&anthropic.ProviderConfig{
-			APIKey:       anthropicKey,
-			DefaultModel: os.Getenv("ANTHROPIC_DEFAULT_MODEL"),
-		})
-		aiFactory.Register(anthropicProvider)
+		aiKeyProviders["ANTHROPIC_API_KEY"](anthropicKey)
 		logger.Info("Anthropic provider registered")
 	}
 
@@ -242,37 +379,114 @@ func main() {
 
 	// Initialize flow services
 	flowEngine := service.NewFlowEngineService(flowRepo, contextRepo)
+	// Contact segments are evaluated on use by flows, newsletters and the segments API
+	contactSegmentService := service.NewContactSegmentService(contactSegmentRepo, contactRepo, conversationRepo)
+	flowEngine.SetSegmentChecker(contactSegmentService)
+	// Tenant-defined contact fields validate contact values and feed flow conditions
+	contactFieldService := service.NewContactFieldService(contactFieldRepo, contactRepo, conversationRepo)
+	flowEngine.SetContactLoader(contactFieldService)
 	flowService := service.NewFlowService(flowRepo)
 
+	// Initialize conversation variables used by flow and VRE templates
+	variablesService := service.NewConversationVariablesService(conversationRepo, contactRepo, contextService)
+	variablesService.SetLinkedObjectRepository(linkedObjectRepo)
+	flowEngine.SetVariableResolver(variablesService)
+
+	// Initialize automation traces (flow and bot decision snapshots per message)
+	automationTraceService := service.NewAutomationTraceService(automationTraceRepo, conversationRepo)
+	flowEngine.SetTracer(automationTraceService)
+
+	// Initialize postback registry that routes interactive replies to automations
+	postbackService := service.NewPostbackService(postbackRepo, flowEngine, contextService, producer, cfg.JWT.Secret)
+
+	// Initialize QA reviews of agent conversations
+	qaService := service.NewQAService(qaRepo, producer)
+	qaService.SetNotifier(handlers.QAReviewWSNotifier{})
+
+	// Initialize tenant status pages driven by channel health
+	statusPageService := service.NewStatusPageService(statusPageRepo, channelRepo, producer)
+	widgetSettingsService := service.NewWidgetSettingsService(widgetSettingsRepo)
+
+	// Initialize scripted message hooks with the interpreters available on this host
+	var hookRuntimes []scripting.Runtime
+	if jsRuntime, err := scripting.NewJavaScriptRuntime(os.Getenv("HOOKS_NODE_PATH")); err != nil {
+		logger.Warn("JavaScript message hooks disabled: " + err.Error())
+	} else {
+		hookRuntimes = append(hookRuntimes, jsRuntime)
+	}
+	if luaRuntime, err := scripting.NewLuaRuntime(os.Getenv("HOOKS_LUA_PATH")); err != nil {
+		logger.Warn("Lua message hooks disabled: " + err.Error())
+	} else {
+		hookRuntimes = append(hookRuntimes, luaRuntime)
+	}
+	messageHookService := service.NewMessageHookService(messageHookRepo, scripting.NewRegistry(hookRuntimes...))
+
+	// Initialize conversation form service (AI pre-fill uses the registered providers)
+	conversationFormService := service.NewConversationFormService(conversationFormRepo, conversationRepo, messageRepo, aiFactory, producer)
+
+	// Initialize AI conversation titles, enabled per tenant through the conversation_titles setting
+	conversationTitleService := service.NewConversationTitleService(conversationRepo, messageRepo, tenantRepo, aiFactory)
+
+	// Initialize document parsing with the OCR tools available on this host
+	ocrEngine := ocr.NewCommandEngine(os.Getenv("OCR_TESSERACT_PATH"), os.Getenv("OCR_PDFTOTEXT_PATH"), os.Getenv("OCR_LANGUAGES"))
+	documentParsingService := service.NewDocumentParsingService(documentPipelineRepo, conversationRepo, messageRepo, ocrEngine, aiFactory, producer)
+	documentParsingService.SetFlowEngine(flowEngine, contextService)
+
 	// Initialize analytics service
 	analyticsService := service.NewAnalyticsService(analyticsRepo, nil)
 	observabilityService := service.NewObservabilityService(observabilityRepo, nats.NewMonitor(natsClient))
 
+	// Initialize stream retention tuning, replay and dead letters for system administrators
+	var streamAdminHandler *handlers.StreamAdminHandler
+	if natsClient != nil {
+		streamAdminService := service.NewStreamAdminService(nats.NewMonitor(natsClient))
+		streamAdminService.SetDeadLetterReplays(database.NewDeadLetterReplayRepository(db))
+		streamAdminHandler = handlers.NewStreamAdminHandler(streamAdminService)
+	}
+
+	// Initialize compliance checks of outbound marketing content
+	complianceService := service.NewComplianceService(complianceRepo, tenantRepo)
+	complianceHandler := handlers.NewComplianceHandler(complianceService)
+
 	// Initialize template service
 	templateService := service.NewTemplateService(templateRepo, channelRepo)
+	templateService.SetComplianceService(complianceService)
+
+	// Initialize multi-number service for WhatsApp Official channels
+	phoneNumberService := service.NewWhatsAppPhoneNumberService(phoneNumberRepo, channelRepo)
+
+	// Initialize quality guard that pauses marketing sends on flagged numbers
+	qualityGuardService := service.NewWhatsAppQualityGuardService(channelRepo, templateRepo, producer)
+
+	// Initialize WhatsApp conversation cost attribution
+	whatsAppCostService := service.NewWhatsAppCostService(conversationCostRepo, messageRepo)
 
 	// Initialize coexistence monitor service
 	coexistenceMonitor := service.NewCoexistenceMonitorService(channelRepo, producer)
 
+	// Initialize phone, email and username normalization
+	identifierService := service.NewIdentifierService(tenantRepo, contactRepo)
+
 	// Initialize history import service for WhatsApp Coexistence
-	_ = service.NewHistoryImportService(channelRepo, conversationRepo, messageRepo, contactRepo, historyImportRepo)
+	historyImportService := service.NewHistoryImportService(channelRepo, conversationRepo, messageRepo, contactRepo, historyImportRepo)
+	historyImportService.SetIdentifierService(identifierService)
 
 	// Initialize VRE (Visual Response Engine) service
 	logger.Info("Initializing VRE service...")
 	var vreService *service.VREService
 	var redisClient *redis.Client
 
-	// Connect to Redis if configured (for VRE caching)
+	// Connect to Redis if configured (for VRE and embedding caching)
 	redisURL := os.Getenv("REDIS_URL")
 	if redisURL != "" {
 		opt, err := redis.ParseURL(redisURL)
 		if err == nil {
 			redisClient = redis.NewClient(opt)
 			if err := redisClient.Ping(context.Background()).Err(); err != nil {
-				logger.Warn("Failed to connect to Redis - VRE caching disabled: " + err.Error())
+				logger.Warn("Failed to connect to Redis - VRE and embedding caching disabled: " + err.Error())
 				redisClient = nil
 			} else {
-				logger.Info("Redis connected for VRE caching")
+				logger.Info("Redis connected for VRE and embedding caching")
 			}
 		}
 	}
@@ -305,6 +519,14 @@ func main() {
 			logger.Info("VRE storage configured: " + uploadDir)
 		}
 
+		// Async renders run as background jobs and report completion as events and callbacks
+		if producer != nil {
+			vreService.SetProducer(producer)
+		}
+		vreService.SetCallbackSender(webhook.NewWebhookProducer())
+		vreService.SetUsageRecorder(quotaService)
+		jobService.Register(service.JobTypeVRERender, vreService.RunRenderJob)
+
 		// Cleanup VRE on shutdown
 		defer vreService.Close()
 	}
@@ -317,6 +539,13 @@ func main() {
 		contactRepo,
 		producer,
 	)
+	sendMessageUC.SetPhoneNumberService(phoneNumberService)
+	sendMessageUC.AddOutboundGuard(qualityGuardService)
+	duplicateSendGuard := service.NewDuplicateSendGuard(messageRepo, conversationRepo, tenantRepo, producer)
+	duplicateSendGuard.SetObservability(observabilityService)
+	sendMessageUC.AddOutboundGuard(duplicateSendGuard)
+	sendMessageUC.SetMessageHooks(messageHookService)
+	sendMessageUC.SetRecipientNormalizer(identifierService)
 	receiveMessageUC := usecase.NewReceiveMessageUseCase(
 		messageRepo,
 		conversationRepo,
@@ -325,15 +554,51 @@ func main() {
 		producer,
 		normalizer,
 	)
+	receiveMessageUC.SetPostbackRouter(postbackService)
+	receiveMessageUC.SetMessageHooks(messageHookService)
+	receiveMessageUC.SetDocumentProcessor(documentParsingService)
+	receiveMessageUC.SetConversationTitler(conversationTitleService)
 
 	// Initialize embedding service
 	embeddingService := service.NewEmbeddingService(aiFactory, nil)
+	embeddingCache := service.NewTieredEmbeddingCache(embeddingCacheRepo, redisClient, service.DefaultEmbeddingTTL)
+	embeddingService.SetCache(embeddingCache)
 
 	// Initialize vector store (pgvector)
 	vectorStore := database.NewPgVectorStore(db)
 
 	// Initialize knowledge service
 	knowledgeService := service.NewKnowledgeService(kbRepo, kiRepo, embeddingService, vectorStore)
+	knowledgeService.SetVersionRepository(knowledgeVersionRepo)
+	knowledgeService.SetJobService(jobService)
+	knowledgeService.SetSharingRepository(database.NewKnowledgeSharingRepository(db))
+	jobService.Register(service.JobTypeKnowledgeEmbeddings, knowledgeService.RunEmbeddingJob)
+
+	// Initialize knowledge inboxes (emails to designated addresses feed knowledge bases after review)
+	knowledgeInboxService := service.NewKnowledgeInboxService(knowledgeInboxRepo, knowledgeService, ocrEngine)
+	receiveMessageUC.SetKnowledgeIngester(knowledgeInboxService)
+
+	// Initialize answer feedback (customer ratings of bot answers feed the knowledge gap queue)
+	knowledgeFeedbackService := service.NewKnowledgeFeedbackService(knowledgeFeedbackRepo, postbackService)
+	postbackService.SetAnswerFeedbackRecorder(knowledgeFeedbackService)
+
+	// Initialize knowledge curation (AI-proposed Q&A pairs from resolved conversations, published after review)
+	knowledgeCurationService := service.NewKnowledgeCurationService(knowledgeCurationRepo, conversationRepo, messageRepo, knowledgeService, aiFactory)
+
+	// Initialize the agent copilot (suggested replies, articles and summaries on escalated conversations)
+	copilotService := service.NewCopilotService(copilotRepo, conversationRepo, messageRepo, tenantRepo, knowledgeService, aiFactory)
+	copilotService.SetNotifier(handlers.CopilotWSNotifier{})
+	receiveMessageUC.SetAgentCopilot(copilotService)
+
+	// Initialize conversation summaries (on request and when conversations are resolved)
+	conversationSummaryService := service.NewConversationSummaryService(conversationSummaryRepo, conversationRepo, messageRepo, tenantRepo, aiFactory)
+	if producer != nil {
+		conversationSummaryService.SetProducer(producer)
+	}
+	// Summary embeddings let the copilot suggest the first replies of similar resolved
+	// conversations when one is assigned
+	conversationSummaryService.SetEmbeddingService(embeddingService)
+	copilotService.SetSimilarReplySources(conversationSummaryRepo, embeddingService)
 
 	// Initialize AI use cases
 	analyzeMessageUC := usecase.NewAnalyzeMessageUseCase(
@@ -350,6 +615,20 @@ func main() {
 		knowledgeService,
 		producer,
 	)
+	generateAIResponseUC.SetTracer(automationTraceService)
+	generateAIResponseUC.SetUsageRecorder(quotaService)
+
+	// Initialize the HTTP tools the AI of bots can call
+	botToolService := service.NewBotToolService(botToolRepo, botRepo)
+	generateAIResponseUC.SetToolService(botToolService)
+
+	// Initialize bot versions and experiments
+	botExperimentService := service.NewBotExperimentService(botExperimentRepo, botRepo)
+	generateAIResponseUC.SetExperimentService(botExperimentService)
+
+	// Relay the conversations of external bots to their Rasa, Botpress or custom endpoint
+	externalBotService := service.NewExternalBotService()
+	generateAIResponseUC.SetExternalBotService(externalBotService)
 
 	// Initialize bot service
 	botService := service.NewBotService(
@@ -360,6 +639,8 @@ func main() {
 		aiFactory,
 		flowEngine,
 	)
+	botService.SetUsageRecorder(quotaService)
+	botService.SetExternalBotService(externalBotService)
 
 	// Initialize escalation use case
 	escalateConversationUC := usecase.NewEscalateConversationUseCase(
@@ -374,6 +655,14 @@ func main() {
 		producer,
 	)
 
+	// Route escalations by the tenant's skill, language and load rules
+	routingService := service.NewRoutingService(routingRepo, userRepo, conversationRepo, contactRepo)
+	routingService.SetMessageRepository(messageRepo)
+	routingService.SetChannelRepository(channelRepo)
+	escalateConversationUC.SetRouter(routingService)
+	escalateConversationUC.SetEscalationRecorder(botExperimentService)
+	escalateConversationUC.SetAssignmentListener(copilotService)
+
 	// Initialize WebChat adapter
 	logger.Info("Initializing WebChat adapter...")
 	webchatAdapter := webchat.NewAdapter()
@@ -433,6 +722,40 @@ func main() {
 	// Note: Email adapter supports multiple providers (SMTP, SendGrid, Mailgun, SES, Postmark)
 	plugin.Register(plugin.ChannelTypeEmail, emailAdapter)
 
+	// Initialize Teams adapter
+	logger.Info("Initializing Teams adapter...")
+	// Note: Teams adapter requires per-channel initialization with bot credentials
+	plugin.Register(plugin.ChannelTypeTeams, teams.NewAdapter())
+	var outboundPublisher nats.Publisher
+	if producer != nil {
+		outboundPublisher = producer
+	}
+	loadChannelConfig := func(ctx context.Context, channelID string) (map[string]string, error) {
+		channel, err := channelRepo.FindByID(ctx, channelID)
+		if err != nil {
+			return nil, err
+		}
+		config := make(map[string]string, len(channel.Config)+len(channel.Credentials))
+		for k, v := range channel.Config {
+			config[k] = v
+		}
+		for k, v := range channel.Credentials {
+			config[k] = v
+		}
+		return config, nil
+	}
+	teamsSender := teams.NewOutboundSender(loadChannelConfig, outboundPublisher)
+
+	// Initialize custom channel adapter
+	logger.Info("Initializing custom channel adapter...")
+	// Note: Custom channels deliver to a per-channel HTTP endpoint configured by the tenant
+	plugin.Register(plugin.ChannelTypeCustom, custom.NewAdapter())
+	customSender := custom.NewOutboundSender(loadChannelConfig, outboundPublisher)
+
+	// Email channels send over pooled SMTP sessions or provider APIs; deferred emails
+	// stay queued in NATS and are retried with a backoff
+	emailSender := email.NewOutboundSender(loadChannelConfig, outboundPublisher)
+
 	// Create WebChat handler
 	webchatHandler := webchat.NewHandler(
 		webchatAdapter,
@@ -441,12 +764,28 @@ func main() {
 		contactRepo,
 		producer,
 	)
+	webchatHandler.SetIncidentBannerProvider(statusPageService)
+	webchatHandler.SetWidgetSettingsProvider(widgetSettingsService)
+	widgetSettingsService.SetNotifier(webchatHandler)
+
+	// Create public chat API handler (custom in-app chat on webchat channels)
+	publicChatService := service.NewPublicChatService(channelRepo, contactRepo, conversationRepo, messageRepo, producer)
+	publicChatHandler := handlers.NewPublicChatHandler(publicChatService)
+	faqWidgetService := service.NewFAQWidgetService(channelRepo, botRepo, knowledgeService, aiFactory, producer)
+	faqWidgetHandler := handlers.NewFAQWidgetHandler(faqWidgetService)
+	statusPageService.SetNotifier(webchatHandler)
 
 	// Create webhook handler
 	webhookHandler := handlers.NewWebhookHandler(channelRepo, producer, templateService)
+	webhookHandler.SetPhoneNumberService(phoneNumberService)
+	webhookHandler.SetQualityGuard(qualityGuardService)
+	webhookHandler.SetCostService(whatsAppCostService)
+	webhookHandler.SetLabelSyncService(service.NewCoexistenceLabelSyncService(contactRepo, channelRepo))
 
 	// Create bot handler
 	botHandler := handlers.NewBotHandler(botService)
+	botToolHandler := handlers.NewBotToolHandler(botToolService)
+	botExperimentHandler := handlers.NewBotExperimentHandler(botExperimentService)
 
 	// Create AI handler
 	aiHandler := handlers.NewAIHandler(
@@ -459,19 +798,166 @@ func main() {
 
 	// Create knowledge handler
 	knowledgeHandler := handlers.NewKnowledgeHandler(knowledgeService)
+	globalKnowledgeHandler := handlers.NewGlobalKnowledgeHandler(knowledgeService)
+	knowledgeInboxHandler := handlers.NewKnowledgeInboxHandler(knowledgeInboxService)
+	knowledgeFeedbackHandler := handlers.NewKnowledgeFeedbackHandler(knowledgeFeedbackService)
+	knowledgeCurationHandler := handlers.NewKnowledgeCurationHandler(knowledgeCurationService)
+	copilotHandler := handlers.NewCopilotHandler(copilotService)
 	observabilityHandler := handlers.NewObservabilityHandler(observabilityService)
 
 	// Create contact service and handler
 	contactService := service.NewContactService(contactRepo)
+	contactService.SetFieldValidator(contactFieldService)
+	contactService.SetIdentifierService(identifierService)
 	contactHandler := handlers.NewContactHandler(contactService)
+	identifierHandler := handlers.NewIdentifierHandler(identifierService)
+	contactFieldHandler := handlers.NewContactFieldHandler(contactFieldService)
+	contactSegmentHandler := handlers.NewContactSegmentHandler(contactSegmentService)
+
+	// Duplicate contacts of the same person across channels are merged on request, and
+	// new channel identities are linked to the contact with the same phone or email
+	contactMergeService := service.NewContactMergeService(contactMergeRepo, contactRepo)
+	receiveMessageUC.SetContactMatcher(contactMergeService)
+	contactMergeHandler := handlers.NewContactMergeHandler(contactMergeService)
+
+	// Tenant labels attached to conversations
+	labelService := service.NewLabelService(labelRepo, conversationRepo)
+	labelHandler := handlers.NewLabelHandler(labelService)
+
+	// Disposition taxonomy picked when resolving conversations
+	dispositionService := service.NewDispositionService(dispositionRepo, tenantRepo, conversationRepo)
+	dispositionHandler := handlers.NewDispositionHandler(dispositionService)
+	aiUsageHandler := handlers.NewAIUsageHandler(aiUsageService)
+	routingHandler := handlers.NewRoutingHandler(routingService)
+	automationTraceHandler := handlers.NewAutomationTraceHandler(automationTraceService)
+
+	// Contact profile cards for the agent sidebar, rebuilt when contacts send new messages
+	contactSummaryService := service.NewContactSummaryService(contactRepo, conversationRepo, contextRepo, paymentRepo)
+	receiveMessageUC.SetContactSummaries(contactSummaryService)
+	contactSummaryHandler := handlers.NewContactSummaryHandler(contactSummaryService)
+
+	// Versioned payload contracts of tenant webhooks
+	webhookSchemaHandler := handlers.NewWebhookSchemaHandler()
+
+	// Tenant webhook subscriptions, delivered from the event stream; deliveries are
+	// queued on NATS, so never hand them a nil producer
+	var webhookEvents nats.Publisher
+	if producer != nil {
+		webhookEvents = producer
+	}
+	webhookSubscriptionService := service.NewWebhookSubscriptionService(webhookSubscriptionRepo, webhookDeliveryRepo, webhookEvents, webhook.NewWebhookProducer(webhook.WithHTTPClient(egress.Client)))
+	webhookSubscriptionHandler := handlers.NewWebhookSubscriptionHandler(webhookSubscriptionService)
+
+	// Watchers follow conversations and contacts without being assigned
+	watchService := service.NewWatchService(watchRepo, conversationRepo, contactRepo, userRepo)
+	watchService.SetNotifier(handlers.WatchWSNotifier{})
+	receiveMessageUC.SetWatchHandler(watchService)
+	watchHandler := handlers.NewWatchHandler(watchService)
+	conversationTitleHandler := handlers.NewConversationTitleHandler(conversationTitleService)
+	conversationSummaryHandler := handlers.NewConversationSummaryHandler(conversationSummaryService)
+	conversationOperationService := service.NewConversationOperationService(conversationOperationRepo, conversationRepo, messageRepo)
+	conversationOperationHandler := handlers.NewConversationOperationHandler(conversationOperationService)
 
 	// Create conversation service and handler
 	conversationService := service.NewConversationService(conversationRepo, contactRepo, channelRepo)
+	conversationService.SetWatchService(watchService)
+	conversationService.SetSummaryService(conversationSummaryService)
+	conversationService.SetExperimentService(botExperimentService)
+	conversationService.SetDispositionService(dispositionService)
+
+	// Customers writing shortly after a resolution reopen the conversation; later
+	// ones start a follow-up linked to it
+	conversationReopenService := service.NewConversationReopenService(conversationRepo, tenantRepo, conversationReopenRepo)
+	receiveMessageUC.SetConversationReopener(conversationReopenService)
+	conversationService.SetReopenService(conversationReopenService)
+	conversationService.SetCopilotService(copilotService)
+	if producer != nil {
+		conversationService.SetProducer(producer)
+	}
 	conversationHandler := handlers.NewConversationHandler(conversationService, escalateConversationUC)
+	takeoverConversationUC := usecase.NewTakeoverConversationUseCase(conversationRepo, botRepo, sendMessageUC, producer)
+	takeoverConversationUC.SetAssignmentListener(copilotService)
+	conversationHandler.SetTakeoverUseCase(takeoverConversationUC)
+	conversationHandler.SetReopenService(conversationReopenService)
 
 	// Create message service and handler
 	messageService := service.NewMessageService(messageRepo, conversationRepo, channelRepo, contactRepo, producer)
+	messageService.SetPhoneNumberService(phoneNumberService)
+	messageService.SetQualityGuard(qualityGuardService)
+	messageService.SetDuplicateGuard(duplicateSendGuard)
+	messageService.SetIdentifierService(identifierService)
+	messageService.SetWatchService(watchService)
 	messageHandler := handlers.NewMessageHandler(messageService)
+	messageTranslationRepo := database.NewMessageTranslationRepository(db)
+	messageHandler.SetTranslationService(service.NewMessageTranslationService(messageTranslationRepo, routingRepo, aiFactory))
+
+	// Create newsletter service and handler; inbound keywords manage subscriptions
+	messageStatusService := service.NewMessageStatusService(messageRepo, conversationRepo, contactRepo)
+	messageStatusService.SetTemplateRepository(templateRepo)
+
+	// Group conversations: WhatsApp groups and email threads with several recipients
+	conversationParticipantRepo := database.NewConversationParticipantRepository(db)
+	conversationParticipantService := service.NewConversationParticipantService(conversationParticipantRepo, conversationRepo, contactRepo, channelRepo, messageRepo)
+	receiveMessageUC.SetGroupConversations(conversationParticipantService)
+	sendMessageUC.SetGroupRecipients(conversationParticipantService)
+	messageService.SetParticipantService(conversationParticipantService)
+	messageStatusService.SetParticipantService(conversationParticipantService)
+	conversationParticipantHandler := handlers.NewConversationParticipantHandler(conversationParticipantService)
+
+	newsletterService := service.NewNewsletterService(newsletterRepo, contactRepo, conversationRepo, channelRepo, messageService, producer)
+	newsletterService.SetVariablesService(variablesService)
+	newsletterService.SetSegmentService(contactSegmentService)
+	newsletterService.SetIdentifierService(identifierService)
+	newsletterService.SetComplianceService(complianceService)
+	newsletterService.SetTenantRepository(tenantRepo)
+	if vreService != nil {
+		newsletterService.SetImageRenderer(vreService)
+	}
+	receiveMessageUC.SetSubscriptionHandler(newsletterService)
+	newsletterHandler := handlers.NewNewsletterHandler(newsletterService)
+
+	// Channel maintenance windows: inbound notices, queued conversations, paused bots,
+	// agent timers and newsletters
+	channelMaintenanceService := service.NewChannelMaintenanceService(channelMaintenanceRepo, channelRepo, conversationRepo, messageService)
+	receiveMessageUC.SetMaintenanceNotifier(channelMaintenanceService)
+	escalateConversationUC.SetMaintenanceWindows(channelMaintenanceService)
+	botService.SetChannelMaintenance(channelMaintenanceService)
+	newsletterService.SetChannelMaintenance(channelMaintenanceService)
+	channelMaintenanceHandler := handlers.NewChannelMaintenanceHandler(channelMaintenanceService)
+
+	// Create interactive message builder handler
+	interactiveHandler := handlers.NewInteractiveHandler(service.NewInteractiveBuilderService())
+
+	// Create conversation variables handler
+	variablesHandler := handlers.NewVariablesHandler(variablesService)
+
+	// Create postback registry handler
+	postbackHandler := handlers.NewPostbackHandler(postbackService)
+
+	// Create QA handler
+	qaHandler := handlers.NewQAHandler(qaService)
+
+	// Create status page handler
+	statusPageHandler := handlers.NewStatusPageHandler(statusPageService)
+	widgetSettingsHandler := handlers.NewWidgetSettingsHandler(widgetSettingsService)
+
+	// Create message hook handler
+	messageHookHandler := handlers.NewMessageHookHandler(messageHookService)
+
+	// Create conversation form handler
+	conversationFormHandler := handlers.NewConversationFormHandler(conversationFormService)
+
+	// Create document pipeline handler
+	documentPipelineHandler := handlers.NewDocumentPipelineHandler(documentParsingService)
+
+	// Create encryption key handler
+	encryptionHandler := handlers.NewEncryptionHandler(encryptionService)
+
+	// Create tenant config handler
+	tenantConfigHandler := handlers.NewTenantConfigHandler(tenantConfigService, jobService)
+
+	// Create job handler
+	jobHandler := handlers.NewJobHandler(jobService)
 
 	// Create flow handler
 	flowHandler := handlers.NewFlowHandler(flowService)
@@ -480,15 +966,82 @@ func main() {
 	channelService := service.NewChannelService(channelRepo, plugin.GetGlobalRegistry(), producer)
 	channelHandler := handlers.NewChannelHandler(channelService, producer)
 
+	// Keep WhatsApp unofficial sessions in PostgreSQL so any instance can resume them
+	if cfg.WhatsApp.SessionStore == "postgres" {
+		channelService.SetWhatsAppSessionStore(cfg.Database.DSN())
+	}
+
+	// Disappearing messages of WhatsApp chats: timers follow the phone and expired
+	// messages are kept, redacted or deleted as the channel's disappearing_retention says
+	disappearingMessageService := service.NewDisappearingMessageService(conversationRepo, contactRepo, channelRepo, messageRepo, channelService)
+	channelService.SetDisappearingListener(disappearingMessageService.ApplyChatTimer)
+	disappearingMessageHandler := handlers.NewDisappearingMessageHandler(disappearingMessageService)
+
+	// Create channel failover service (standby instances take over WhatsApp channels)
+	instanceID := cfg.WhatsApp.InstanceID
+	if instanceID == "" {
+		hostname, _ := os.Hostname()
+		instanceID = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	var failoverEvents nats.Publisher
+	if producer != nil {
+		failoverEvents = producer
+	}
+	// Channel ownership is locked in PostgreSQL, or in Redis when configured; the
+	// audit trail of ownership changes stays in PostgreSQL either way
+	var channelLeases repository.ChannelLeaseRepository = channelLeaseRepo
+	if cfg.WhatsApp.LeaseStore == "redis" {
+		if redisClient != nil {
+			channelLeases = lock.NewChannelLeaseRepository(redisClient, channelLeaseRepo)
+		} else {
+			logger.Warn("Redis channel locks require REDIS_URL - locking channels in PostgreSQL")
+		}
+	}
+	channelFailoverService := service.NewChannelFailoverService(
+		channelLeases,
+		channelRepo,
+		channelService,
+		failoverEvents,
+		instanceID,
+		time.Duration(cfg.WhatsApp.LeaseTTL)*time.Second,
+	)
+	channelFailoverHandler := handlers.NewChannelFailoverHandler(channelFailoverService)
+	whatsAppFailover := cfg.WhatsApp.Failover
+	if whatsAppFailover && cfg.WhatsApp.SessionStore != "postgres" {
+		logger.Warn("WhatsApp failover requires the postgres session store - failover disabled")
+		whatsAppFailover = false
+	}
+
 	// Create tenant service and handler
 	tenantService := service.NewTenantService(tenantRepo, userRepo, channelRepo, contactRepo)
+	tenantService.SetWhatsAppCostService(whatsAppCostService)
 	tenantHandler := handlers.NewTenantHandler(tenantService)
+	tenantHandler.SetQuotaService(quotaService)
+
+	// Create onboarding wizard
+	onboardingService := service.NewOnboardingService(
+		onboardingRepo,
+		tenantService,
+		userService,
+		channelService,
+		botService,
+		contactService,
+		conversationService,
+		messageService,
+		producer,
+	)
+	onboardingHandler := handlers.NewOnboardingHandler(onboardingService)
 
 	// Create analytics handler
 	analyticsHandler := handlers.NewAnalyticsHandler(analyticsService)
+	analyticsHandler.SetWhatsAppCostService(whatsAppCostService)
+	analyticsHandler.SetQAService(qaService)
+	analyticsHandler.SetWidgetSettingsService(widgetSettingsService)
+	analyticsHandler.SetCopilotService(copilotService)
 
 	// Create WhatsApp Analytics handler
 	whatsappAnalyticsHandler := handlers.NewWhatsAppAnalyticsHandler()
+	whatsappAnalyticsHandler.SetTemplatePerformanceService(service.NewTemplatePerformanceService(templateRepo, analyticsRepo, channelRepo))
 
 	// Create Payments handler
 	paymentsHandler := handlers.NewPaymentsHandler()
@@ -499,20 +1052,137 @@ func main() {
 	// Create CTWA handler
 	ctwaHandler := handlers.NewCTWAHandler()
 
+	// Public URL providers reach this server at
+	baseURL := os.Getenv("BASE_URL")
+	if baseURL == "" {
+		baseURL = fmt.Sprintf("http://%s:%d", cfg.Server.Host, cfg.Server.Port)
+	}
+
+	// Registers channel webhooks with Telegram, Twilio and Meta
+	webhookRegistrationService := service.NewWebhookRegistrationService(channelRepo, baseURL)
+	webhookRegistrationHandler := handlers.NewWebhookRegistrationHandler(webhookRegistrationService)
+
+	// Probes channel credentials, webhook reachability and sending when channels are
+	// created or connected
+	channelProbeService := service.NewChannelProbeService(channelProbeRepo, channelRepo, webhookRegistrationService)
+	channelProbeHandler := handlers.NewChannelProbeHandler(channelProbeService)
+
+	// Tracks when Meta channel access tokens expire and refreshes them ahead of time
+	channelTokenService := service.NewChannelTokenService(channelTokenRepo, channelRepo, producer)
+	channelTokenHandler := handlers.NewChannelTokenHandler(channelTokenService)
+
+	// Notification center of tenants; validates channel credentials daily and raises
+	// channels whose credentials fail or expire soon in it
+	notificationService := service.NewNotificationService(notificationRepo)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	channelCredentialHealthService := service.NewChannelCredentialHealthService(channelCredentialHealthRepo, channelRepo)
+	channelCredentialHealthService.SetNotificationService(notificationService)
+	channelCredentialHealthHandler := handlers.NewChannelCredentialHealthHandler(channelCredentialHealthService)
+	channelHandler.SetCredentialHealthService(channelCredentialHealthService)
+
+	// Copies inbound media into object storage and serves it from signed URLs
+	var mediaHandler *handlers.MediaHandler
+	var mediaService *service.MediaService
+	mediaStorage, err := newMediaStorage(cfg.Storage)
+	if err != nil {
+		logger.Warn("Failed to initialize media storage - inbound media keeps provider URLs: " + err.Error())
+	} else if mediaStorage != nil {
+		signingKey := cfg.Storage.SigningKey
+		if signingKey == "" {
+			signingKey = cfg.JWT.Secret
+		}
+		mediaService = service.NewMediaService(mediaStorage, baseURL, signingKey)
+		receiveMessageUC.SetMediaStore(mediaService)
+		messageService.SetMediaService(mediaService)
+		mediaHandler = handlers.NewMediaHandler(mediaService)
+		logger.Info("Media storage configured: " + cfg.Storage.Driver)
+	}
+
+	// Resumable uploads of large campaign and knowledge files stage encrypted chunks in
+	// media storage; completed files are scanned by clamd when CLAMAV_ADDR is set and
+	// videos transcoded by ffmpeg when installed
+	var uploadService *service.UploadService
+	var uploadHandler *handlers.UploadHandler
+	if mediaStorage != nil {
+		uploadService = service.NewUploadService(uploadRepo, mediaStorage, jobEvents)
+		uploadService.SetCipher(encryptionService)
+		uploadService.SetJobService(jobService)
+		if clamavAddr := os.Getenv("CLAMAV_ADDR"); clamavAddr != "" {
+			uploadService.SetScanner(antivirus.NewClamAVScanner(clamavAddr))
+		} else {
+			logger.Warn("CLAMAV_ADDR not set - uploads are not scanned for viruses")
+		}
+		if transcoder := transcode.NewFFmpegTranscoder(os.Getenv("FFMPEG_PATH")); transcoder.Available() {
+			uploadService.SetTranscoder(transcoder)
+		} else {
+			logger.Warn("ffmpeg not found - uploaded videos are not transcoded")
+		}
+		jobService.Register(service.JobTypeUploadProcess, uploadService.RunProcessJob)
+		uploadHandler = handlers.NewUploadHandler(uploadService)
+	}
+
+	// Transcribes voice call recordings with the speech-to-text provider chosen by
+	// STT_PROVIDER into the callers' conversations, keeping the transcripts searchable.
+	// Transcripts are posted through NATS like any inbound message.
+	var callTranscriptHandler *handlers.CallTranscriptHandler
+	if transcriber, provider := newSpeechTranscriber(openAIKey); transcriber != nil && mediaService != nil && producer != nil {
+		callTranscriptionService := service.NewCallTranscriptionService(callTranscriptRepo, channelRepo, mediaService, transcriber, provider, producer)
+		callTranscriptionService.SetJobService(jobService)
+		jobService.Register(service.JobTypeCallTranscribe, callTranscriptionService.RunTranscribeJob)
+		callTranscriptHandler = handlers.NewCallTranscriptHandler(callTranscriptionService)
+		logger.Info("Call transcription configured: " + provider)
+	}
+
 	channelService.SetLifecycleHooks(service.ChannelLifecycleHooks{
+		OnCreated: func(ctx context.Context, channel *entity.Channel) {
+			webhookRegistrationService.RegisterCreated(ctx, channel)
+			channelProbeService.ProbeInBackground(ctx, channel, entity.ChannelProbeTriggerCreate)
+			channelTokenService.TrackInBackground(ctx, channel)
+		},
 		OnConnected: func(ctx context.Context, channel *entity.Channel) {
+			channelProbeService.ProbeInBackground(ctx, channel, entity.ChannelProbeTriggerConnect)
+			channelTokenService.TrackInBackground(ctx, channel)
+			channelCredentialHealthService.CheckInBackground(ctx, channel)
 			registerWhatsAppAdvancedClient(channel, paymentRepo, whatsappAnalyticsHandler, paymentsHandler, callingHandler, ctwaHandler)
+			if whatsAppFailover && (channel.Type == entity.ChannelTypeWhatsApp || channel.Type == entity.ChannelTypeWhatsAppUnofficial) {
+				channelFailoverService.Claim(ctx, channel)
+			}
 		},
 		OnUpdated: func(ctx context.Context, channel *entity.Channel) {
 			if channel.IsConnected() {
 				registerWhatsAppAdvancedClient(channel, paymentRepo, whatsappAnalyticsHandler, paymentsHandler, callingHandler, ctwaHandler)
 			}
+			if channel.Type == entity.ChannelTypeTeams {
+				teamsSender.Forget(channel.ID)
+			}
+			if channel.Type == entity.ChannelTypeCustom {
+				customSender.Forget(channel.ID)
+			}
+			if channel.Type == entity.ChannelTypeEmail {
+				emailSender.Forget(channel.ID)
+			}
 		},
 		OnDisconnected: func(ctx context.Context, channel *entity.Channel) {
 			unregisterWhatsAppAdvancedClient(channel.ID, whatsappAnalyticsHandler, paymentsHandler, callingHandler, ctwaHandler)
+			if channel.Type == entity.ChannelTypeTeams {
+				teamsSender.Forget(channel.ID)
+			}
+			if channel.Type == entity.ChannelTypeCustom {
+				customSender.Forget(channel.ID)
+			}
+			if channel.Type == entity.ChannelTypeEmail {
+				emailSender.Forget(channel.ID)
+			}
 		},
 	})
 
+	// Reload live adapters of channels whose credentials reference a rotated secret
+	secretResolver.OnRotate(func(ctx context.Context, rotation secrets.Rotation) {
+		if _, err := channelService.ReloadSecretCredentials(ctx, rotation.Secret()); err != nil {
+			logger.Warn("Failed to reload channels after secret rotation: " + err.Error())
+		}
+	})
+
 	registerWhatsAppAdvancedClients(
 		context.Background(),
 		channelRepo,
@@ -523,26 +1193,76 @@ func main() {
 		ctwaHandler,
 	)
 
-	// Reconnect WhatsApp channels with stored sessions
-	logger.Info("Reconnecting WhatsApp channels...")
-	if reconnected, err := channelService.ReconnectWhatsAppChannels(context.Background()); err != nil {
-		logger.Warn("Failed to reconnect some WhatsApp channels: " + err.Error())
-	} else if reconnected > 0 {
-		logger.Info(fmt.Sprintf("Reconnected %d WhatsApp channel(s)", reconnected))
+	// Reconnect WhatsApp channels with stored sessions. With failover the channels
+	// are split between instances by lease instead of connected everywhere.
+	if whatsAppFailover {
+		logger.Info("Claiming WhatsApp channels as instance " + instanceID + "...")
+		if takenOver, err := channelFailoverService.RunLeases(context.Background()); err != nil {
+			logger.Warn("Failed to claim WhatsApp channels: " + err.Error())
+		} else if takenOver > 0 {
+			logger.Info(fmt.Sprintf("Took over %d WhatsApp channel(s)", takenOver))
+		}
+	} else {
+		logger.Info("Reconnecting WhatsApp channels...")
+		if reconnected, err := channelService.ReconnectWhatsAppChannels(context.Background()); err != nil {
+			logger.Warn("Failed to reconnect some WhatsApp channels: " + err.Error())
+		} else if reconnected > 0 {
+			logger.Info(fmt.Sprintf("Reconnected %d WhatsApp channel(s)", reconnected))
+		}
 	}
 
 	// Create VRE handler (if VRE service is available)
 	var vreHandler *handlers.VREHandler
 	if vreService != nil {
 		vreHandler = handlers.NewVREHandler(vreService, producer)
+		vreHandler.SetVariablesService(variablesService)
+		vreHandler.SetJobService(jobService)
 	}
 
 	// Create OAuth handler
-	baseURL := os.Getenv("BASE_URL")
-	if baseURL == "" {
-		baseURL = fmt.Sprintf("http://%s:%d", cfg.Server.Host, cfg.Server.Port)
-	}
 	oauthHandler := handlers.NewOAuthHandler(channelRepo, baseURL)
+	oauthHandler.SetTokenService(channelTokenService)
+
+	// Create scheduling service and handler; replies to slot offers and reminders
+	// book, move or cancel appointments on Google or Microsoft calendars
+	bookingLinkBaseURL := os.Getenv("BOOKING_LINK_BASE_URL")
+	if bookingLinkBaseURL == "" {
+		bookingLinkBaseURL = strings.TrimRight(baseURL, "/") + "/api/v1/public/bookings"
+	}
+	schedulingService := service.NewSchedulingService(bookingCalendarRepo, bookingRepo, conversationRepo, contactRepo, channelRepo, messageService, producer, bookingLinkBaseURL)
+	schedulingService.SetCalendarProvider(calendar.NewGoogleProvider(os.Getenv("GOOGLE_CALENDAR_CLIENT_ID"), os.Getenv("GOOGLE_CALENDAR_CLIENT_SECRET")))
+	schedulingService.SetCalendarProvider(calendar.NewMicrosoftProvider(os.Getenv("MICROSOFT_CALENDAR_CLIENT_ID"), os.Getenv("MICROSOFT_CALENDAR_CLIENT_SECRET"), os.Getenv("MICROSOFT_CALENDAR_TENANT")))
+	receiveMessageUC.SetBookingHandler(schedulingService)
+	schedulingHandler := handlers.NewSchedulingHandler(schedulingService)
+
+	// Orders, tickets and bookings shown alongside conversations, synced from external systems
+	linkedObjectService := service.NewLinkedObjectService(linkedObjectRepo, linkedObjectConnectorRepo, conversationRepo, contactRepo, messageService, producer)
+	linkedObjectService.SetVariablesService(variablesService)
+	linkedObjectHandler := handlers.NewLinkedObjectHandler(linkedObjectService)
+
+	// Apple Wallet and Google Wallet passes for bookings and loyalty cards, updated
+	// when their linked objects change
+	walletPassService := service.NewWalletPassService(walletPassTemplateRepo, walletPassRepo, linkedObjectRepo, contactRepo, conversationRepo, messageService, baseURL)
+	if passTypeID := os.Getenv("APPLE_PASS_TYPE_ID"); passTypeID != "" {
+		appleConfig, err := wallet.LoadAppleConfig(passTypeID, os.Getenv("APPLE_TEAM_ID"),
+			os.Getenv("APPLE_PASS_CERT_FILE"), os.Getenv("APPLE_PASS_KEY_FILE"),
+			os.Getenv("APPLE_WWDR_CERT_FILE"), os.Getenv("APPLE_PASS_ICON_FILE"))
+		if err != nil {
+			logger.Warn("Apple Wallet passes disabled: " + err.Error())
+		} else {
+			walletPassService.SetAppleIssuer(wallet.NewAppleIssuer(*appleConfig))
+		}
+	}
+	if issuerID := os.Getenv("GOOGLE_WALLET_ISSUER_ID"); issuerID != "" {
+		googleConfig, err := wallet.LoadGoogleConfig(issuerID, os.Getenv("GOOGLE_WALLET_CREDENTIALS_FILE"))
+		if err != nil {
+			logger.Warn("Google Wallet passes disabled: " + err.Error())
+		} else {
+			walletPassService.SetGoogleIssuer(wallet.NewGoogleIssuer(*googleConfig))
+		}
+	}
+	linkedObjectService.SetObserver(walletPassService)
+	walletPassHandler := handlers.NewWalletPassHandler(walletPassService)
 
 	// Create WhatsApp Embedded Signup handler for Coexistence
 	waEmbeddedSignupHandler := handlers.NewWhatsAppEmbeddedSignupHandler(channelRepo, baseURL)
@@ -550,55 +1270,381 @@ func main() {
 	// Create template handler
 	templateHandler := handlers.NewTemplateHandler(templateService)
 
-	// Create auth middleware
-	authMiddleware := middleware.NewAuthMiddleware(authService)
+	// Create WhatsApp phone number handler
+	phoneNumberHandler := handlers.NewWhatsAppPhoneNumberHandler(phoneNumberService)
+
+	// Create WhatsApp quality guard handler
+	qualityGuardHandler := handlers.NewWhatsAppQualityGuardHandler(qualityGuardService)
+
+	// Create auth middleware
+	authMiddleware := middleware.NewAuthMiddleware(authService)
+	// External systems authenticate with scoped tenant API keys instead of logging in
+	authMiddleware.SetAPIKeyService(apiKeyService)
+
+	// Create auth handler
+	authHandler := handlers.NewAuthHandler(authService, userService)
+
+	// Tenants sign their users in with their own OIDC or SAML identity provider
+	ssoService := service.NewSSOService(database.NewSSOConfigRepository(db), tenantRepo, userRepo, authService, baseURL)
+	ssoService.SetSecretResolver(secretResolver)
+	if redisClient != nil {
+		ssoService.SetRedis(redisClient)
+	}
+	ssoHandler := handlers.NewSSOHandler(ssoService)
+
+	// Create user handler
+	userHandler := handlers.NewUserHandler(userService)
+	organizationHandler := handlers.NewOrganizationHandler(organizationService)
+
+	// Create API key handler
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+
+	// Initialize Agent WebSocket Hub
+	logger.Info("Starting Agent WebSocket Hub...")
+	agentHub := handlers.GetAgentHub()
+	// Relay hub messages through NATS so agents connected to other instances get them
+	if natsClient != nil {
+		if err := agentHub.SetRelay(nats.NewBroadcaster(natsClient, instanceID)); err != nil {
+			logger.Warn("Failed to relay WebSocket messages between instances: " + err.Error())
+		}
+	}
+	wsHandler := handlers.NewWebSocketHandler(agentHub, cfg.JWT.Secret)
+	agentPresenceHandler := handlers.NewAgentPresenceHandler(agentHub, userService)
+
+	// Delta sync for mobile agents
+	syncRepo := database.NewSyncRepository(db)
+	syncRepo.SetCipher(encryptionService)
+	syncHandler := handlers.NewSyncHandler(service.NewSyncService(syncRepo, agentHub))
+
+	// Only auto-assign escalations to agents that are online
+	escalateConversationUC.SetAgentAvailability(agentHub)
+
+	// Re-route waiting conversations of agents who went offline or idle
+	autoUnassignService := service.NewAutoUnassignService(conversationRepo, conversationReassignmentRepo, tenantRepo, userRepo, agentHub, producer)
+	autoUnassignService.SetRouter(routingService)
+	autoUnassignService.SetNotifier(handlers.AgentAwayWSNotifier{})
+	autoUnassignService.SetChannelMaintenance(channelMaintenanceService)
+	conversationReassignmentHandler := handlers.NewConversationReassignmentHandler(autoUnassignService)
+
+	// Start message consumers (only if NATS is available)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Start coexistence monitor background job (runs every hour)
+	go func() {
+		ticker := time.NewTicker(1 * time.Hour)
+		defer ticker.Stop()
+
+		// Run immediately on startup
+		if err := coexistenceMonitor.MonitorCoexistenceActivity(ctx); err != nil {
+			logger.Warn("Coexistence monitor check failed: " + err.Error())
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				logger.Info("Coexistence monitor stopped")
+				return
+			case <-ticker.C:
+				if err := coexistenceMonitor.MonitorCoexistenceActivity(ctx); err != nil {
+					logger.Warn("Coexistence monitor check failed: " + err.Error())
+				}
+			}
+		}
+	}()
+	logger.Info("Coexistence monitor started (runs every hour)")
+
+	// Start expired postback cleanup (runs every hour)
+	go func() {
+		ticker := time.NewTicker(1 * time.Hour)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := postbackService.CleanupExpired(ctx); err != nil {
+					logger.Warn("Postback cleanup failed: " + err.Error())
+				}
+			}
+		}
+	}()
+
+	// Prune old entries of the embedding cache (runs every hour)
+	go func() {
+		ticker := time.NewTicker(1 * time.Hour)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := embeddingCache.Prune(ctx); err != nil {
+					logger.Warn("Embedding cache pruning failed: " + err.Error())
+				}
+			}
+		}
+	}()
+
+	// Prune per-call AI usage records past their retention (runs every hour)
+	go func() {
+		ticker := time.NewTicker(1 * time.Hour)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := aiUsageService.PruneRecords(ctx); err != nil {
+					logger.Warn("AI usage pruning failed: " + err.Error())
+				}
+			}
+		}
+	}()
+
+	// Refresh Meta channel access tokens about to expire (runs every hour)
+	go func() {
+		ticker := time.NewTicker(1 * time.Hour)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := channelTokenService.Run(ctx); err != nil {
+					logger.Warn("Channel token refresh failed: " + err.Error())
+				}
+			}
+		}
+	}()
+
+	// Validate channel credentials not checked in the last day (runs every hour)
+	go func() {
+		ticker := time.NewTicker(1 * time.Hour)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := channelCredentialHealthService.Run(ctx); err != nil {
+					logger.Warn("Channel credential checks failed: " + err.Error())
+				}
+			}
+		}
+	}()
+
+	// Start QA conversation sampling (runs every hour)
+	go func() {
+		ticker := time.NewTicker(1 * time.Hour)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := qaService.RunAllRules(ctx); err != nil {
+					logger.Warn("QA sampling failed: " + err.Error())
+				}
+			}
+		}
+	}()
+
+	// Apply the retention of expired disappearing messages (runs every minute)
+	go func() {
+		ticker := time.NewTicker(1 * time.Minute)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := disappearingMessageService.ExpireMessages(ctx); err != nil {
+					logger.Warn("Disappearing message expiration failed: " + err.Error())
+				}
+			}
+		}
+	}()
+
+	// Start automatic unassignment of unavailable agents (runs every minute)
+	go func() {
+		ticker := time.NewTicker(1 * time.Minute)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := autoUnassignService.Run(ctx); err != nil {
+					logger.Warn("Automatic unassignment failed: " + err.Error())
+				}
+			}
+		}
+	}()
+
+	// Start channel health sync for status pages (runs every minute)
+	go func() {
+		ticker := time.NewTicker(1 * time.Minute)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := statusPageService.SyncChannelHealth(ctx); err != nil {
+					logger.Warn("Status page health sync failed: " + err.Error())
+				}
+			}
+		}
+	}()
+
+	// Renew WhatsApp channel leases and take over channels of failed instances
+	if whatsAppFailover {
+		go func() {
+			ticker := time.NewTicker(channelFailoverService.RenewInterval())
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if _, err := channelFailoverService.RunLeases(ctx); err != nil {
+						logger.Warn("WhatsApp channel lease renewal failed: " + err.Error())
+					}
+				}
+			}
+		}()
+	}
+
+	// Start scheduled newsletter sends (runs every minute)
+	go func() {
+		ticker := time.NewTicker(1 * time.Minute)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := newsletterService.RunDue(ctx); err != nil {
+					logger.Warn("Newsletter scheduling failed: " + err.Error())
+				}
+			}
+		}
+	}()
+
+	// Renew secret leases and pick up rotated secrets (runs every minute)
+	if len(secretResolver.Backends()) > 0 {
+		go secretResolver.Run(ctx, time.Minute)
+	}
 
-	// Create auth handler
-	authHandler := handlers.NewAuthHandler(authService, userService)
+	// Flush metered usage and send quota alerts (runs every 30 seconds)
+	go func() {
+		ticker := time.NewTicker(30 * time.Second)
+		defer ticker.Stop()
 
-	// Create user handler
-	userHandler := handlers.NewUserHandler(userService)
+		for {
+			select {
+			case <-ctx.Done():
+				// Keep the usage counted since the last flush
+				if err := quotaService.Flush(context.Background()); err != nil {
+					logger.Warn("Usage flush failed: " + err.Error())
+				}
+				if err := aiUsageService.Flush(context.Background()); err != nil {
+					logger.Warn("AI usage flush failed: " + err.Error())
+				}
+				return
+			case <-ticker.C:
+				if err := quotaService.Flush(ctx); err != nil {
+					logger.Warn("Usage flush failed: " + err.Error())
+				}
+				if err := aiUsageService.Flush(ctx); err != nil {
+					logger.Warn("AI usage flush failed: " + err.Error())
+				}
+			}
+		}
+	}()
 
-	// Create API key handler
-	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	// Send due booking reminders (runs every minute)
+	go func() {
+		ticker := time.NewTicker(1 * time.Minute)
+		defer ticker.Stop()
 
-	// Initialize Agent WebSocket Hub
-	logger.Info("Starting Agent WebSocket Hub...")
-	agentHub := handlers.GetAgentHub()
-	wsHandler := handlers.NewWebSocketHandler(agentHub, cfg.JWT.Secret)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := schedulingService.RunDueReminders(ctx); err != nil {
+					logger.Warn("Booking reminders failed: " + err.Error())
+				}
+			}
+		}
+	}()
 
-	// Start message consumers (only if NATS is available)
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// Discard uploads abandoned before they finished (runs every hour)
+	if uploadService != nil {
+		go func() {
+			ticker := time.NewTicker(1 * time.Hour)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if _, err := uploadService.CleanupExpired(ctx); err != nil {
+						logger.Warn("Upload cleanup failed: " + err.Error())
+					}
+				}
+			}
+		}()
+	}
 
-	// Start coexistence monitor background job (runs every hour)
+	// Requeue background jobs whose worker never started or stopped (runs every minute)
 	go func() {
-		ticker := time.NewTicker(1 * time.Hour)
+		ticker := time.NewTicker(1 * time.Minute)
 		defer ticker.Stop()
 
-		// Run immediately on startup
-		if err := coexistenceMonitor.MonitorCoexistenceActivity(ctx); err != nil {
-			logger.Warn("Coexistence monitor check failed: " + err.Error())
-		}
-
 		for {
 			select {
 			case <-ctx.Done():
-				logger.Info("Coexistence monitor stopped")
 				return
 			case <-ticker.C:
-				if err := coexistenceMonitor.MonitorCoexistenceActivity(ctx); err != nil {
-					logger.Warn("Coexistence monitor check failed: " + err.Error())
+				if _, err := jobService.RequeueStale(ctx); err != nil {
+					logger.Warn("Job requeue failed: " + err.Error())
 				}
 			}
 		}
 	}()
-	logger.Info("Coexistence monitor started (runs every hour)")
 
 	var aiConsumer *nats.AIConsumer
 
+	// System-wide maintenance mode: webhooks are buffered and consumers hold back while it is on
+	maintenanceService := service.NewMaintenanceService(maintenanceRepo)
+	maintenanceHandler := handlers.NewMaintenanceHandler(maintenanceService)
+
+	// Instances share the consumers; with Redis a conversation's messages are handled
+	// by one instance at a time, besides one worker at a time within an instance
+	var orderingLock nats.KeyLocker
+	if redisClient != nil {
+		orderingLock = lock.NewRedisLocker(redisClient, lock.DefaultPrefix)
+	}
+
 	if consumer != nil {
 		logger.Info("Starting message consumers...")
+		consumer.SetPause(maintenanceService.Paused)
+		consumer.SetOrderingLock(orderingLock)
 		// Subscribe to inbound messages
 		if err := consumer.SubscribeAllInbound(ctx, func(ctx context.Context, msg *nats.InboundMessage) error {
 			_, err := receiveMessageUC.Execute(ctx, msg)
@@ -608,15 +1654,39 @@ func main() {
 		}
 
 		// Subscribe to status updates
-		if err := consumer.SubscribeStatus(ctx, func(ctx context.Context, status *nats.StatusUpdate) error {
-			return messageRepo.UpdateStatus(ctx, status.MessageID, toMessageStatus(status.Status), status.ErrorMessage)
-		}); err != nil {
+		if err := consumer.SubscribeStatus(ctx, messageStatusService.HandleStatusUpdate); err != nil {
 			logger.Warn("Failed to subscribe to status updates")
 		}
 
+		// Deliver outbound Teams messages
+		if err := consumer.SubscribeOutbound(ctx, string(entity.ChannelTypeTeams), teamsSender.HandleOutbound); err != nil {
+			logger.Warn("Failed to subscribe to Teams outbound messages: " + err.Error())
+		}
+
+		// Deliver outbound custom channel messages
+		if err := consumer.SubscribeOutbound(ctx, string(entity.ChannelTypeCustom), customSender.HandleOutbound); err != nil {
+			logger.Warn("Failed to subscribe to custom channel outbound messages: " + err.Error())
+		}
+
+		// Deliver outbound emails, retrying deferrals
+		if err := consumer.SubscribeEmailOutbound(ctx, emailSender.HandleOutbound); err != nil {
+			logger.Warn("Failed to subscribe to email outbound messages: " + err.Error())
+		}
+
+		// Queue events for tenant webhook subscriptions and deliver them
+		if err := consumer.SubscribeEvents(ctx, webhookSubscriptionService.HandleEvent); err != nil {
+			logger.Warn("Failed to subscribe to events: " + err.Error())
+		}
+		if err := consumer.SubscribeWebhooks(ctx, webhookSubscriptionService.HandleDelivery); err != nil {
+			logger.Warn("Failed to subscribe to webhook deliveries: " + err.Error())
+		}
+
 		// Initialize AI consumer
 		logger.Info("Starting AI consumers...")
 		aiConsumer = nats.NewAIConsumer(natsClient)
+		aiConsumer.SetConcurrency(cfg.NATS.Concurrency)
+		aiConsumer.SetPause(maintenanceService.Paused)
+		aiConsumer.SetOrderingLock(orderingLock)
 		if err := aiConsumer.EnsureStream(ctx); err != nil {
 			logger.Warn("Failed to create AI stream: " + err.Error())
 		} else {
@@ -636,6 +1706,11 @@ func main() {
 
 			// Subscribe to bot response requests
 			if err := aiConsumer.SubscribeBotResponse(ctx, func(ctx context.Context, req *nats.BotResponseRequest) error {
+				// Skip jobs queued before an agent took over from the bot
+				if conversation, err := conversationRepo.FindByID(ctx, req.ConversationID); err == nil && conversation.IsBotPaused() {
+					return nil
+				}
+
 				result, err := generateAIResponseUC.Execute(ctx, &usecase.GenerateAIResponseInput{
 					MessageID:      req.MessageID,
 					ConversationID: req.ConversationID,
@@ -648,19 +1723,70 @@ func main() {
 				}
 
 				// If response was generated, send it via the send message use case
-				if result != nil && result.Response != "" && !result.ShouldEscalate {
-					_, err = sendMessageUC.Execute(ctx, &usecase.SendMessageInput{
+				if result != nil && result.Response != "" && (!result.ShouldEscalate || result.Handover) {
+					sent, err := sendMessageUC.Execute(ctx, &usecase.SendMessageInput{
 						TenantID:       req.TenantID,
 						ConversationID: req.ConversationID,
 						SenderID:       req.BotID,
 						SenderType:     entity.SenderTypeBot,
 						ContentType:    entity.ContentTypeText,
 						Content:        result.Response,
+						QuickReplies:   result.QuickReplies,
 						Metadata: map[string]string{
 							"ai_model":      result.Model,
 							"ai_confidence": fmt.Sprintf("%.2f", result.Confidence),
 						},
 					})
+					// An agent took over while the reply was being generated
+					if appErr := errors.GetAppError(err); appErr != nil && appErr.Code == errors.ErrCodeBotPaused {
+						return nil
+					}
+					if err != nil {
+						return err
+					}
+
+					// Ask whether an answer built from the knowledge base helped
+					if result.AnswerFeedback != nil {
+						prompt, err := knowledgeFeedbackService.BuildPrompt(ctx, &service.AnswerFeedbackPromptInput{
+							TenantID:        req.TenantID,
+							ConversationID:  req.ConversationID,
+							MessageID:       sent.Message.ID,
+							Question:        req.Content,
+							KnowledgeBaseID: result.KnowledgeBaseID,
+							ItemIDs:         result.KnowledgeItemIDs,
+							Config:          result.AnswerFeedback,
+						})
+						if err != nil {
+							logger.Warn("Failed to build answer feedback prompt: " + err.Error())
+							return nil
+						}
+						if _, err := sendMessageUC.Execute(ctx, &usecase.SendMessageInput{
+							TenantID:       req.TenantID,
+							ConversationID: req.ConversationID,
+							SenderID:       req.BotID,
+							SenderType:     entity.SenderTypeBot,
+							ContentType:    entity.ContentTypeText,
+							Content:        prompt.Text,
+							QuickReplies:   prompt.QuickReplies,
+							Metadata:       map[string]string{"answer_feedback": sent.Message.ID},
+						}); err != nil {
+							logger.Warn("Failed to send answer feedback prompt: " + err.Error())
+						}
+					}
+				}
+
+				// External bots hand the conversation to an agent when they fail or ask to
+				if result != nil && result.Handover {
+					if _, err := escalateConversationUC.Execute(ctx, &usecase.EscalateConversationInput{
+						ConversationID: req.ConversationID,
+						TenantID:       req.TenantID,
+						ChannelID:      req.ChannelID,
+						BotID:          req.BotID,
+						Reason:         result.EscalateReason,
+						RequestedBy:    "bot",
+					}); err != nil {
+						return err
+					}
 				}
 				return err
 			}); err != nil {
@@ -669,7 +1795,36 @@ func main() {
 
 			logger.Info("AI consumers started")
 		}
+
+		// Start background job workers; without them jobs run in-process
+		jobConcurrency, _ := strconv.Atoi(os.Getenv("JOB_WORKER_CONCURRENCY"))
+		jobConsumer := nats.NewJobConsumer(natsClient, jobConcurrency)
+		if err := jobConsumer.EnsureStream(ctx); err != nil {
+			logger.Warn("Failed to create jobs stream: " + err.Error())
+		} else if err := jobConsumer.SubscribeJobs(ctx, jobService.HandleJobRequest); err != nil {
+			logger.Warn("Failed to subscribe to jobs: " + err.Error())
+		} else {
+			jobService.SetQueue(producer)
+			logger.Info("Job workers started")
+		}
+	}
+
+	// Rate limit the public chat API per signed contact; counters are kept in memory without Redis
+	publicChatRateLimit, _ := strconv.Atoi(os.Getenv("PUBLIC_CHAT_RATE_LIMIT"))
+	if publicChatRateLimit <= 0 {
+		publicChatRateLimit = 60
+	}
+	var rateLimitRedis *goredis.Client
+	if redisClient != nil {
+		if opt, err := goredis.ParseURL(redisURL); err == nil {
+			rateLimitRedis = goredis.NewClient(opt)
+		}
 	}
+	publicChatLimiter := middleware.NewRateLimiter(rateLimitRedis, &middleware.RateLimiterConfig{
+		RequestsPerWindow: publicChatRateLimit,
+		WindowDuration:    time.Minute,
+		KeyPrefix:         "ratelimit:",
+	})
 
 	// Initialize Gin router
 	logger.Info("Initializing HTTP router...")
@@ -678,6 +1833,7 @@ func main() {
 	router.Use(middleware.RequestID())
 	router.Use(middleware.Logger())
 	router.Use(middleware.CORS())
+	maintenanceService.SetReplayer(router)
 
 	// Health check endpoints
 	router.GET("/health", func(c *gin.Context) {
@@ -689,16 +1845,14 @@ func main() {
 			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready", "error": "database unavailable"})
 			return
 		}
-		// Check NATS (optional)
+		// Check NATS (optional): messaging degrades while it is down instead of failing
 		natsStatus := "disabled"
+		var messaging *nats.MessagingState
 		if natsClient != nil {
-			if natsClient.IsConnected() {
-				natsStatus = "connected"
-			} else {
-				natsStatus = "disconnected"
-			}
+			messaging = natsClient.State()
+			natsStatus = string(messaging.Status)
 		}
-		c.JSON(http.StatusOK, gin.H{"status": "ready", "nats": natsStatus})
+		c.JSON(http.StatusOK, gin.H{"status": "ready", "nats": natsStatus, "messaging": messaging})
 	})
 
 	// Swagger documentation endpoint
@@ -715,13 +1869,106 @@ func main() {
 		{
 			auth.POST("/login", authHandler.Login)
 			auth.POST("/refresh", authHandler.RefreshToken)
+			// Challenged logins send their MFA token, signed-in users their bearer token
+			auth.POST("/mfa/setup", authMiddleware.OptionalAuth(), authHandler.SetupMFA)
+			auth.POST("/mfa/verify", authMiddleware.OptionalAuth(), authHandler.VerifyMFA)
+			// Single sign-on with the tenant's identity provider; SAML providers post to the callback
+			auth.GET("/sso/:tenant/login", ssoHandler.Login)
+			auth.GET("/sso/:tenant/callback", ssoHandler.Callback)
+			auth.POST("/sso/:tenant/callback", ssoHandler.Callback)
+			auth.GET("/sso/:tenant/metadata", ssoHandler.Metadata)
 		}
 
 		// WebChat widget config (no auth required)
 		api.GET("/webchat/:channelId/config", webchatHandler.GetWidgetConfig)
 
+		// Public status pages (no auth required)
+		api.GET("/status/:slug", statusPageHandler.GetPublicStatus)
+
+		// Published webhook payload schemas (no auth required)
+		api.GET("/webhook-schemas", webhookSchemaHandler.List)
+		api.GET("/webhook-schemas/:version", webhookSchemaHandler.Get)
+
+		// Public chat API for custom in-app chat (auth via signed contact ID)
+		publicChat := api.Group("/public/chat/:channelId")
+		publicChat.Use(publicChatLimiter.LimitByKey(publicChatHandler.RateLimitKey), publicChatHandler.Authenticate())
+		{
+			publicChat.POST("/conversations", publicChatHandler.StartConversation)
+			publicChat.POST("/conversations/:conversationId/messages", publicChatHandler.PostMessage)
+			publicChat.GET("/conversations/:conversationId/messages", publicChatHandler.ListMessages)
+		}
+
+		// Embeddable FAQ widget answering from the channel's knowledge base (no auth required)
+		publicFAQ := api.Group("/public/faq/:channelId")
+		publicFAQ.Use(publicChatLimiter.LimitByKey(faqWidgetHandler.RateLimitKey))
+		{
+			publicFAQ.POST("/ask", faqWidgetHandler.Ask)
+			publicFAQ.POST("/escalate", faqWidgetHandler.Escalate)
+		}
+
+		// Booking reschedule and cancel links (auth via link token)
+		publicBookings := api.Group("/public/bookings")
+		{
+			publicBookings.GET("/:token", schedulingHandler.GetPublicBooking)
+			publicBookings.POST("/:token/reschedule", schedulingHandler.ReschedulePublicBooking)
+			publicBookings.POST("/:token/cancel", schedulingHandler.CancelPublicBooking)
+		}
+
+		// Wallet pass downloads (auth via link token) and the Apple Wallet web service
+		// (auth via the pass token in the ApplePass authorization header)
+		api.GET("/wallet/passes/:id/apple", walletPassHandler.DownloadApple)
+		appleWallet := api.Group("/wallet/apple/v1")
+		{
+			appleWallet.POST("/devices/:deviceId/registrations/:passTypeId/:serial", walletPassHandler.AppleRegisterDevice)
+			appleWallet.DELETE("/devices/:deviceId/registrations/:passTypeId/:serial", walletPassHandler.AppleUnregisterDevice)
+			appleWallet.GET("/devices/:deviceId/registrations/:passTypeId", walletPassHandler.AppleUpdatedSerials)
+			appleWallet.GET("/passes/:passTypeId/:serial", walletPassHandler.AppleLatestPass)
+			appleWallet.POST("/log", walletPassHandler.AppleLog)
+		}
+
+		// Stored inbound media (auth via signed URL)
+		if mediaHandler != nil {
+			api.GET("/media/*key", mediaHandler.Get)
+		}
+
+		// System-wide maintenance mode and stream administration (system administration token required)
+		system := api.Group("/system")
+		system.Use(middleware.RequireAdminToken(os.Getenv("SYSTEM_ADMIN_TOKEN")))
+		{
+			system.GET("/maintenance", maintenanceHandler.Status)
+			system.POST("/maintenance/enable", maintenanceHandler.Enable)
+			system.POST("/maintenance/disable", maintenanceHandler.Disable)
+			if streamAdminHandler != nil {
+				system.GET("/streams", streamAdminHandler.List)
+				system.GET("/streams/:stream", streamAdminHandler.Get)
+				system.PUT("/streams/:stream", streamAdminHandler.Update)
+				system.POST("/streams/:stream/replay", streamAdminHandler.Replay)
+				system.GET("/streams/:stream/dead-letters", streamAdminHandler.DeadLetters)
+				system.GET("/streams/:stream/dead-letters/replays", streamAdminHandler.DeadLetterReplays)
+				system.GET("/streams/:stream/dead-letters/:seq", streamAdminHandler.DeadLetter)
+				system.POST("/streams/:stream/dead-letters/replay", streamAdminHandler.ReplayDeadLetters)
+				system.POST("/streams/:stream/dead-letters/:seq/replay", streamAdminHandler.ReplayDeadLetter)
+			}
+
+			// Global knowledge bases, attached read-only to tenants
+			system.GET("/knowledge-bases", globalKnowledgeHandler.List)
+			system.POST("/knowledge-bases", globalKnowledgeHandler.Create)
+			system.GET("/knowledge-bases/:id", globalKnowledgeHandler.Get)
+			system.PUT("/knowledge-bases/:id", globalKnowledgeHandler.Update)
+			system.DELETE("/knowledge-bases/:id", globalKnowledgeHandler.Delete)
+			system.GET("/knowledge-bases/:id/items", globalKnowledgeHandler.ListItems)
+			system.POST("/knowledge-bases/:id/items", globalKnowledgeHandler.AddItem)
+			system.PUT("/knowledge-bases/:id/items/:itemId", globalKnowledgeHandler.UpdateItem)
+			system.DELETE("/knowledge-bases/:id/items/:itemId", globalKnowledgeHandler.DeleteItem)
+			system.POST("/knowledge-bases/:id/regenerate-embeddings", globalKnowledgeHandler.RegenerateEmbeddings)
+			system.GET("/knowledge-bases/:id/tenants", globalKnowledgeHandler.ListTenants)
+			system.POST("/knowledge-bases/:id/tenants", globalKnowledgeHandler.Attach)
+			system.DELETE("/knowledge-bases/:id/tenants/:tenantId", globalKnowledgeHandler.Detach)
+		}
+
 		// Webhook routes (auth via signature verification)
 		webhooks := api.Group("/webhooks")
+		webhooks.Use(middleware.BufferDuringMaintenance(maintenanceService))
 		{
 			webhooks.Any("/whatsapp/:channelId", webhookHandler.WhatsAppWebhook)
 			webhooks.POST("/telegram/:channelId", webhookHandler.TelegramWebhook)
@@ -731,6 +1978,7 @@ func main() {
 			webhooks.Any("/messenger/:channelId", webhookHandler.FacebookWebhook) // Alias for Facebook
 			webhooks.Any("/instagram/:channelId", webhookHandler.InstagramWebhook)
 			webhooks.Any("/rcs/:channelId", webhookHandler.RCSWebhook)
+			webhooks.POST("/teams/:channelId", webhookHandler.TeamsWebhook)
 			webhooks.Any("/email/:channelId", webhookHandler.EmailWebhook)
 			webhooks.Any("/email/:channelId/sendgrid", webhookHandler.EmailWebhook)
 			webhooks.Any("/email/:channelId/mailgun", webhookHandler.EmailWebhook)
@@ -743,21 +1991,43 @@ func main() {
 			webhooks.POST("/payments/:channelId", paymentsHandler.HandleWebhook)
 			webhooks.POST("/calls/:channelId", callingHandler.HandleWebhook)
 			webhooks.POST("/ctwa/:channelId", ctwaHandler.ProcessReferralWebhook)
+
+			// Linked object sync from external systems
+			webhooks.POST("/linked-objects/:connectorId", linkedObjectHandler.HandleWebhook)
 		}
 
 		// Protected routes
 		protected := api.Group("")
-		protected.Use(authMiddleware.Authenticate())
+		protected.Use(authMiddleware.Authenticate(), middleware.MeterUsage(quotaService))
 		{
 			// User info
 			protected.GET("/me", authHandler.Me)
 			protected.PUT("/me", authHandler.UpdateMe)
 			protected.PUT("/me/password", authHandler.ChangePassword)
+			protected.POST("/auth/switch-workspace", authHandler.SwitchWorkspace)
+			protected.GET("/auth/mfa", authHandler.GetMFAStatus)
+			protected.POST("/auth/mfa/recovery-codes", authHandler.RegenerateRecoveryCodes)
+			protected.POST("/auth/mfa/disable", authHandler.DisableMFA)
 
 			// Tenant/Organization
 			protected.GET("/tenant", tenantHandler.Get)
 			protected.PUT("/tenant", tenantHandler.Update)
 			protected.GET("/tenant/usage", tenantHandler.GetUsage)
+			protected.GET("/tenant/quotas", tenantHandler.GetQuotas)
+
+			// Organization workspaces; membership roles are checked by the service
+			organization := protected.Group("/organization")
+			{
+				organization.POST("", authMiddleware.RequireRole("owner"), organizationHandler.Create)
+				organization.GET("", organizationHandler.Get)
+				organization.PUT("", organizationHandler.Update)
+				organization.GET("/workspaces", organizationHandler.ListWorkspaces)
+				organization.POST("/workspaces", organizationHandler.CreateWorkspace)
+				organization.GET("/members", organizationHandler.ListMembers)
+				organization.POST("/members", organizationHandler.SaveMember)
+				organization.DELETE("/members/:email", organizationHandler.RemoveMember)
+				organization.GET("/usage", organizationHandler.Usage)
+			}
 
 			// Conversations
 			conversations := protected.Group("/conversations")
@@ -769,8 +2039,40 @@ func main() {
 				conversations.POST("/:id/assign", conversationHandler.Assign)
 				conversations.POST("/:id/resolve", conversationHandler.Resolve)
 				conversations.POST("/:id/reopen", conversationHandler.Reopen)
+				conversations.POST("/:id/csat", botExperimentHandler.RecordCSAT)
 				conversations.GET("/:id/escalation-context", conversationHandler.GetEscalationContext)
 				conversations.POST("/:id/escalate", conversationHandler.Escalate)
+				conversations.POST("/:id/takeover", authMiddleware.RequireRole("supervisor", "admin", "owner"), conversationHandler.Takeover)
+				conversations.POST("/:id/resume-bot", authMiddleware.RequireRole("supervisor", "admin", "owner"), conversationHandler.ResumeBot)
+				conversations.GET("/:id/variables", variablesHandler.GetConversationVariables)
+				conversations.GET("/:id/forms", conversationFormHandler.ListConversationSubmissions)
+				conversations.POST("/:id/forms", conversationFormHandler.StartSubmission)
+				conversations.GET("/:id/documents", documentPipelineHandler.ListConversationDocuments)
+				conversations.GET("/:id/linked-objects", linkedObjectHandler.ListConversationObjects)
+				conversations.GET("/:id/labels", labelHandler.ListConversationLabels)
+				conversations.POST("/:id/labels", labelHandler.AttachToConversation)
+				conversations.DELETE("/:id/labels/:labelId", labelHandler.DetachFromConversation)
+				conversations.GET("/:id/disposition", dispositionHandler.GetConversationDisposition)
+				conversations.PUT("/:id/disposition", dispositionHandler.SetConversationDisposition)
+				conversations.GET("/:id/history", conversationHandler.GetHistory)
+				conversations.GET("/:id/watchers", watchHandler.ListConversationWatchers)
+				conversations.GET("/:id/participants", conversationParticipantHandler.List)
+				conversations.POST("/:id/participants", conversationParticipantHandler.Add)
+				conversations.DELETE("/:id/participants/:contactId", conversationParticipantHandler.Remove)
+				conversations.PUT("/:id/title", conversationTitleHandler.SetTitle)
+				conversations.POST("/:id/title/regenerate", conversationTitleHandler.Regenerate)
+				conversations.POST("/:id/summarize", conversationSummaryHandler.Summarize)
+				conversations.GET("/:id/summary", conversationSummaryHandler.Get)
+				conversations.GET("/:id/disappearing-messages", disappearingMessageHandler.Get)
+				conversations.PUT("/:id/disappearing-messages", disappearingMessageHandler.Set)
+				conversations.GET("/:id/reassignments", conversationReassignmentHandler.ListConversation)
+				conversations.POST("/:id/merge", conversationOperationHandler.Merge)
+				conversations.POST("/:id/split", conversationOperationHandler.Split)
+				conversations.GET("/:id/operations", conversationOperationHandler.ListOperations)
+				conversations.GET("/:id/automation-trace", automationTraceHandler.List)
+				conversations.GET("/:id/copilot", copilotHandler.ListSuggestions)
+				conversations.POST("/:id/copilot", copilotHandler.Suggest)
+				conversations.POST("/:id/messages/:messageId/parse", documentPipelineHandler.ParseMessage)
 				// Messages within a conversation
 				conversations.GET("/:id/messages", messageHandler.List)
 				conversations.POST("/:id/messages", messageHandler.Send)
@@ -779,6 +2081,295 @@ func main() {
 
 			// Messages (direct access by ID)
 			protected.GET("/messages/:id", messageHandler.Get)
+			protected.GET("/messages/:id/recipients", conversationParticipantHandler.ListRecipients)
+
+			// Agent outcomes of copilot suggestions
+			protected.POST("/copilot/suggestions/:id/respond", copilotHandler.Respond)
+
+			// Interactive message builder
+			interactive := protected.Group("/interactive")
+			{
+				interactive.POST("/build", interactiveHandler.Build)
+				interactive.GET("/constraints/:channelType", interactiveHandler.GetConstraints)
+			}
+
+			// Template variable preview
+			protected.POST("/variables/preview", variablesHandler.Preview)
+
+			// Postback registry for interactive replies
+			postbacks := protected.Group("/postbacks")
+			{
+				postbacks.POST("", postbackHandler.Register)
+				postbacks.GET("/polls/:pollId", postbackHandler.GetPollResults)
+			}
+
+			// Quality assurance reviews
+			qa := protected.Group("/qa")
+			{
+				reviewers := authMiddleware.RequireRole("supervisor", "admin", "owner")
+				qa.GET("/scorecards", qaHandler.ListScorecards)
+				qa.GET("/scorecards/:id", qaHandler.GetScorecard)
+				qa.POST("/scorecards", reviewers, qaHandler.CreateScorecard)
+				qa.PUT("/scorecards/:id", reviewers, qaHandler.UpdateScorecard)
+				qa.DELETE("/scorecards/:id", reviewers, qaHandler.DeleteScorecard)
+				qa.GET("/rules", reviewers, qaHandler.ListRules)
+				qa.POST("/rules", reviewers, qaHandler.CreateRule)
+				qa.PUT("/rules/:id", reviewers, qaHandler.UpdateRule)
+				qa.DELETE("/rules/:id", reviewers, qaHandler.DeleteRule)
+				qa.POST("/rules/:id/run", reviewers, qaHandler.RunRule)
+				qa.GET("/reviews", reviewers, qaHandler.ListReviews)
+				qa.GET("/reviews/:id", reviewers, qaHandler.GetReview)
+				qa.POST("/reviews/:id/submit", reviewers, qaHandler.SubmitReview)
+			}
+
+			// Onboarding wizard
+			onboarding := protected.Group("/onboarding")
+			{
+				onboardingManagers := authMiddleware.RequireRole("admin", "owner")
+				onboarding.GET("", onboardingHandler.GetStatus)
+				onboarding.POST("/workspace", onboardingManagers, onboardingHandler.SetupWorkspace)
+				onboarding.POST("/invites", onboardingManagers, onboardingHandler.InviteUsers)
+				onboarding.POST("/channel", onboardingManagers, onboardingHandler.ConnectChannel)
+				onboarding.POST("/bot", onboardingManagers, onboardingHandler.CreateBot)
+				onboarding.POST("/test-message", onboardingManagers, onboardingHandler.SendTestMessage)
+				onboarding.POST("/samples", onboardingManagers, onboardingHandler.ProvisionSamples)
+				onboarding.POST("/steps/:step/skip", onboardingManagers, onboardingHandler.SkipStep)
+			}
+
+			// Status page and incidents
+			statusPage := protected.Group("/status-page")
+			{
+				statusPage.GET("", statusPageHandler.GetPage)
+				statusPage.PUT("", authMiddleware.RequireRole("admin", "owner"), statusPageHandler.SavePage)
+				statusPage.GET("/preview", statusPageHandler.PreviewPage)
+			}
+			incidents := protected.Group("/incidents")
+			{
+				incidentManagers := authMiddleware.RequireRole("supervisor", "admin", "owner")
+				incidents.GET("", statusPageHandler.ListIncidents)
+				incidents.GET("/:id", statusPageHandler.GetIncident)
+				incidents.POST("", incidentManagers, statusPageHandler.CreateIncident)
+				incidents.POST("/:id/updates", incidentManagers, statusPageHandler.UpdateIncident)
+				incidents.DELETE("/:id", incidentManagers, statusPageHandler.DeleteIncident)
+			}
+
+			// Webchat widget quick replies and emoji sets
+			widgetSettings := protected.Group("/widget-settings")
+			{
+				widgetSettings.GET("", widgetSettingsHandler.Get)
+				widgetSettings.PUT("", authMiddleware.RequireRole("admin", "owner"), widgetSettingsHandler.Save)
+			}
+
+			// Identity provider of the tenant
+			ssoRoutes := protected.Group("/sso")
+			ssoRoutes.Use(authMiddleware.RequireRole("admin", "owner"))
+			{
+				ssoRoutes.GET("", ssoHandler.GetConfig)
+				ssoRoutes.PUT("", authMiddleware.RequireRole("owner"), ssoHandler.SaveConfig)
+				ssoRoutes.DELETE("", ssoHandler.DeleteConfig)
+			}
+
+			// Scripted message hooks
+			hooks := protected.Group("/hooks")
+			hooks.Use(authMiddleware.RequireRole("admin", "owner"))
+			{
+				hooks.GET("", messageHookHandler.List)
+				hooks.POST("", messageHookHandler.Create)
+				hooks.GET("/languages", messageHookHandler.Languages)
+				hooks.POST("/test", messageHookHandler.Test)
+				hooks.GET("/:id", messageHookHandler.Get)
+				hooks.PUT("/:id", messageHookHandler.Update)
+				hooks.DELETE("/:id", messageHookHandler.Delete)
+			}
+
+			// Conversation forms
+			forms := protected.Group("/forms")
+			{
+				formManagers := authMiddleware.RequireRole("supervisor", "admin", "owner")
+				forms.GET("", conversationFormHandler.ListForms)
+				forms.GET("/:id", conversationFormHandler.GetForm)
+				forms.POST("", formManagers, conversationFormHandler.CreateForm)
+				forms.PUT("/:id", formManagers, conversationFormHandler.UpdateForm)
+				forms.DELETE("/:id", formManagers, conversationFormHandler.DeleteForm)
+				forms.GET("/:id/export", formManagers, conversationFormHandler.ExportSubmissions)
+			}
+			formSubmissions := protected.Group("/form-submissions")
+			{
+				formSubmissions.GET("", conversationFormHandler.ListSubmissions)
+				formSubmissions.GET("/:id", conversationFormHandler.GetSubmission)
+				formSubmissions.PUT("/:id", conversationFormHandler.UpdateSubmission)
+				formSubmissions.POST("/:id/submit", conversationFormHandler.Submit)
+				formSubmissions.POST("/:id/prefill", conversationFormHandler.Prefill)
+				formSubmissions.DELETE("/:id", conversationFormHandler.DeleteSubmission)
+			}
+
+			// Newsletters and broadcast lists
+			newsletters := protected.Group("/newsletters")
+			{
+				newsletterManagers := authMiddleware.RequireRole("supervisor", "admin", "owner")
+				newsletters.GET("", newsletterHandler.List)
+				newsletters.GET("/:id", newsletterHandler.Get)
+				newsletters.POST("", newsletterManagers, newsletterHandler.Create)
+				newsletters.PUT("/:id", newsletterManagers, newsletterHandler.Update)
+				newsletters.DELETE("/:id", newsletterManagers, newsletterHandler.Delete)
+				newsletters.GET("/:id/subscribers", newsletterHandler.ListSubscribers)
+				newsletters.POST("/:id/subscribers", newsletterHandler.Subscribe)
+				newsletters.DELETE("/:id/subscribers/:contactId", newsletterHandler.Unsubscribe)
+				newsletters.POST("/:id/send", newsletterManagers, newsletterHandler.Send)
+				newsletters.GET("/:id/editions", newsletterHandler.ListEditions)
+				newsletters.GET("/:id/editions/:editionId", newsletterHandler.GetEdition)
+				newsletters.GET("/:id/render-stats", newsletterHandler.RenderStats)
+			}
+
+			// Compliance rules for campaign and template content
+			compliance := protected.Group("/compliance")
+			{
+				complianceAdmins := authMiddleware.RequireRole("admin", "owner")
+				compliance.POST("/scan", complianceHandler.Scan)
+				compliance.GET("/rules", complianceHandler.ListRules)
+				compliance.POST("/rules", complianceAdmins, complianceHandler.CreateRule)
+				compliance.PUT("/rules/:id", complianceAdmins, complianceHandler.UpdateRule)
+				compliance.DELETE("/rules/:id", complianceAdmins, complianceHandler.DeleteRule)
+				compliance.GET("/overrides", complianceAdmins, complianceHandler.ListOverrides)
+			}
+
+			// Document pipelines (OCR and field extraction of customer documents)
+			documentPipelines := protected.Group("/document-pipelines")
+			{
+				pipelineManagers := authMiddleware.RequireRole("supervisor", "admin", "owner")
+				documentPipelines.GET("", documentPipelineHandler.List)
+				documentPipelines.GET("/:id", documentPipelineHandler.Get)
+				documentPipelines.POST("", pipelineManagers, documentPipelineHandler.Create)
+				documentPipelines.POST("/test", pipelineManagers, documentPipelineHandler.Test)
+				documentPipelines.PUT("/:id", pipelineManagers, documentPipelineHandler.Update)
+				documentPipelines.DELETE("/:id", pipelineManagers, documentPipelineHandler.Delete)
+			}
+
+			// Tenant encryption keys (admin only)
+			encryptionRoutes := protected.Group("/encryption")
+			encryptionRoutes.Use(authMiddleware.RequireRole("admin", "owner"))
+			{
+				encryptionRoutes.GET("", encryptionHandler.Status)
+				encryptionRoutes.POST("/enable", encryptionHandler.Enable)
+				encryptionRoutes.POST("/rotate", encryptionHandler.Rotate)
+				encryptionRoutes.POST("/disable", encryptionHandler.Disable)
+			}
+
+			// Background jobs
+			jobs := protected.Group("/jobs")
+			{
+				jobs.GET("", jobHandler.List)
+				jobs.GET("/:id", jobHandler.Get)
+				jobs.POST("/:id/cancel", jobHandler.Cancel)
+				jobs.POST("/:id/retry", jobHandler.Retry)
+			}
+
+			// Resumable uploads (tus protocol)
+			if uploadHandler != nil {
+				uploads := protected.Group("/uploads")
+				{
+					uploads.POST("", uploadHandler.Create)
+					uploads.HEAD("/:id", uploadHandler.Head)
+					uploads.PATCH("/:id", uploadHandler.Patch)
+					uploads.GET("/:id", uploadHandler.Get)
+					uploads.DELETE("/:id", uploadHandler.Delete)
+				}
+			}
+
+			// Voice call transcripts
+			if callTranscriptHandler != nil {
+				callTranscripts := protected.Group("/call-transcripts")
+				{
+					callTranscripts.GET("", callTranscriptHandler.List)
+					callTranscripts.GET("/:id", callTranscriptHandler.Get)
+					callTranscripts.POST("", callTranscriptHandler.Create)
+				}
+			}
+
+			// Booking calendars and appointments booked from conversations
+			bookingCalendars := protected.Group("/booking-calendars")
+			{
+				calendarManagers := authMiddleware.RequireRole("admin", "owner")
+				bookingCalendars.GET("", schedulingHandler.ListCalendars)
+				bookingCalendars.GET("/:id", schedulingHandler.GetCalendar)
+				bookingCalendars.GET("/:id/slots", schedulingHandler.ListSlots)
+				bookingCalendars.POST("", calendarManagers, schedulingHandler.CreateCalendar)
+				bookingCalendars.PUT("/:id", calendarManagers, schedulingHandler.UpdateCalendar)
+				bookingCalendars.DELETE("/:id", calendarManagers, schedulingHandler.DeleteCalendar)
+			}
+			bookings := protected.Group("/bookings")
+			{
+				bookings.GET("", schedulingHandler.ListBookings)
+				bookings.POST("", schedulingHandler.CreateBooking)
+				bookings.POST("/offers", schedulingHandler.OfferSlots)
+				bookings.GET("/:id", schedulingHandler.GetBooking)
+				bookings.POST("/:id/reschedule", schedulingHandler.RescheduleBooking)
+				bookings.POST("/:id/cancel", schedulingHandler.CancelBooking)
+			}
+
+			// Orders, tickets and bookings linked to conversations
+			linkedObjects := protected.Group("/linked-objects")
+			{
+				linkedObjects.GET("", linkedObjectHandler.List)
+				linkedObjects.POST("", linkedObjectHandler.Create)
+				linkedObjects.GET("/:id", linkedObjectHandler.Get)
+				linkedObjects.PUT("/:id", linkedObjectHandler.Update)
+				linkedObjects.DELETE("/:id", linkedObjectHandler.Delete)
+			}
+			linkedObjectConnectors := protected.Group("/linked-object-connectors")
+			linkedObjectConnectors.Use(authMiddleware.RequireRole("admin", "owner"))
+			{
+				linkedObjectConnectors.GET("", linkedObjectHandler.ListConnectors)
+				linkedObjectConnectors.POST("", linkedObjectHandler.CreateConnector)
+				linkedObjectConnectors.GET("/:id", linkedObjectHandler.GetConnector)
+				linkedObjectConnectors.PUT("/:id", linkedObjectHandler.UpdateConnector)
+				linkedObjectConnectors.POST("/:id/rotate-secret", linkedObjectHandler.RotateConnectorSecret)
+				linkedObjectConnectors.DELETE("/:id", linkedObjectHandler.DeleteConnector)
+			}
+
+			// Tenant webhook subscriptions to Linktor events (admin only)
+			webhookSubscriptions := protected.Group("/webhook-subscriptions")
+			webhookSubscriptions.Use(authMiddleware.RequireRole("admin", "owner"))
+			{
+				webhookSubscriptions.GET("/event-types", webhookSubscriptionHandler.EventTypes)
+				webhookSubscriptions.GET("", webhookSubscriptionHandler.List)
+				webhookSubscriptions.POST("", webhookSubscriptionHandler.Create)
+				webhookSubscriptions.GET("/:id", webhookSubscriptionHandler.Get)
+				webhookSubscriptions.PUT("/:id", webhookSubscriptionHandler.Update)
+				webhookSubscriptions.POST("/:id/rotate-secret", webhookSubscriptionHandler.RotateSecret)
+				webhookSubscriptions.DELETE("/:id", webhookSubscriptionHandler.Delete)
+				webhookSubscriptions.GET("/:id/deliveries", webhookSubscriptionHandler.ListDeliveries)
+				webhookSubscriptions.GET("/:id/deliveries/:deliveryId", webhookSubscriptionHandler.GetDelivery)
+				webhookSubscriptions.POST("/:id/deliveries/:deliveryId/redeliver", webhookSubscriptionHandler.Redeliver)
+			}
+
+			// Apple Wallet and Google Wallet passes
+			walletPassTemplates := protected.Group("/wallet-pass-templates")
+			{
+				walletPassTemplates.GET("", walletPassHandler.ListTemplates)
+				walletPassTemplates.GET("/:id", walletPassHandler.GetTemplate)
+				walletPassTemplates.POST("", authMiddleware.RequireRole("admin", "owner"), walletPassHandler.CreateTemplate)
+				walletPassTemplates.PUT("/:id", authMiddleware.RequireRole("admin", "owner"), walletPassHandler.UpdateTemplate)
+				walletPassTemplates.DELETE("/:id", authMiddleware.RequireRole("admin", "owner"), walletPassHandler.DeleteTemplate)
+			}
+			walletPasses := protected.Group("/wallet-passes")
+			{
+				walletPasses.POST("", walletPassHandler.Issue)
+				walletPasses.GET("/:id", walletPassHandler.Get)
+				walletPasses.POST("/:id/send", walletPassHandler.Send)
+			}
+
+			// Configuration as code (admin only)
+			configRoutes := protected.Group("/config")
+			configRoutes.Use(authMiddleware.RequireRole("admin", "owner"))
+			{
+				configRoutes.GET("/export", tenantConfigHandler.Export)
+				configRoutes.POST("/import/preview", tenantConfigHandler.PreviewImport)
+				configRoutes.POST("/import", tenantConfigHandler.Import)
+			}
+
+			// Identifier validation
+			protected.POST("/identifiers/validate", identifierHandler.Validate)
 
 			// Contacts
 			contacts := protected.Group("/contacts")
@@ -786,10 +2377,97 @@ func main() {
 				contacts.GET("", contactHandler.List)
 				contacts.POST("", contactHandler.Create)
 				contacts.GET("/:id", contactHandler.Get)
+				contacts.GET("/:id/summary", contactSummaryHandler.Get)
 				contacts.PUT("/:id", contactHandler.Update)
 				contacts.DELETE("/:id", contactHandler.Delete)
 				contacts.POST("/:id/identities", contactHandler.AddIdentity)
 				contacts.DELETE("/:id/identities/:identityId", contactHandler.RemoveIdentity)
+				contacts.POST("/:id/merge", contactMergeHandler.Merge)
+				contacts.GET("/:id/duplicates", contactMergeHandler.Duplicates)
+				contacts.GET("/:id/merges", contactMergeHandler.ListMerges)
+				contacts.GET("/:id/watchers", watchHandler.ListContactWatchers)
+			}
+
+			// Custom contact field definitions
+			contactFields := protected.Group("/contact-fields")
+			{
+				contactFields.GET("", contactFieldHandler.List)
+				contactFields.GET("/:id", contactFieldHandler.Get)
+				contactFields.POST("", authMiddleware.RequireRole("admin", "owner"), contactFieldHandler.Create)
+				contactFields.PUT("/:id", authMiddleware.RequireRole("admin", "owner"), contactFieldHandler.Update)
+				contactFields.DELETE("/:id", authMiddleware.RequireRole("admin", "owner"), contactFieldHandler.Delete)
+			}
+
+			// Rule-based contact segments
+			segments := protected.Group("/segments")
+			{
+				segments.GET("", contactSegmentHandler.List)
+				segments.GET("/:id", contactSegmentHandler.Get)
+				segments.GET("/:id/contacts", contactSegmentHandler.ListContacts)
+				segments.GET("/:id/stats", contactSegmentHandler.Stats)
+				segments.POST("", authMiddleware.RequireRole("supervisor", "admin", "owner"), contactSegmentHandler.Create)
+				segments.PUT("/:id", authMiddleware.RequireRole("supervisor", "admin", "owner"), contactSegmentHandler.Update)
+				segments.DELETE("/:id", authMiddleware.RequireRole("supervisor", "admin", "owner"), contactSegmentHandler.Delete)
+			}
+
+			// Conversation labels
+			labels := protected.Group("/labels")
+			{
+				labels.GET("", labelHandler.List)
+				labels.GET("/:id", labelHandler.Get)
+				labels.POST("", authMiddleware.RequireRole("supervisor", "admin", "owner"), labelHandler.Create)
+				labels.PUT("/:id", authMiddleware.RequireRole("supervisor", "admin", "owner"), labelHandler.Update)
+				labels.DELETE("/:id", authMiddleware.RequireRole("supervisor", "admin", "owner"), labelHandler.Delete)
+			}
+
+			// AI usage, cost and budget
+			usage := protected.Group("/usage")
+			{
+				usage.GET("", aiUsageHandler.GetReport)
+				usage.GET("/monthly", aiUsageHandler.GetMonths)
+				usage.GET("/records", aiUsageHandler.ListRecords)
+				usage.GET("/budget", aiUsageHandler.GetBudget)
+				usage.PUT("/budget", authMiddleware.RequireRole("admin", "owner"), aiUsageHandler.UpdateBudget)
+			}
+
+			// Conversation dispositions
+			dispositions := protected.Group("/dispositions")
+			{
+				dispositions.GET("", dispositionHandler.List)
+				dispositions.GET("/:id", dispositionHandler.Get)
+				dispositions.POST("", authMiddleware.RequireRole("supervisor", "admin", "owner"), dispositionHandler.Create)
+				dispositions.PUT("/:id", authMiddleware.RequireRole("supervisor", "admin", "owner"), dispositionHandler.Update)
+				dispositions.DELETE("/:id", authMiddleware.RequireRole("supervisor", "admin", "owner"), dispositionHandler.Delete)
+			}
+
+			// Routing of escalated conversations to agents
+			routingRules := protected.Group("/routing-rules")
+			routingRules.Use(authMiddleware.RequireRole("admin", "owner"))
+			{
+				routingRules.GET("", routingHandler.ListRules)
+				routingRules.POST("", routingHandler.CreateRule)
+				routingRules.GET("/agents", routingHandler.ListProfiles)
+				routingRules.PUT("/agents/:userId", routingHandler.SaveProfile)
+				routingRules.GET("/:id", routingHandler.GetRule)
+				routingRules.PUT("/:id", routingHandler.UpdateRule)
+				routingRules.DELETE("/:id", routingHandler.DeleteRule)
+			}
+
+			// Watch lists of conversations and contacts
+			watches := protected.Group("/watches")
+			{
+				watches.GET("", watchHandler.List)
+				watches.POST("", watchHandler.Create)
+				watches.DELETE("/:targetType/:targetId", watchHandler.Delete)
+			}
+
+			// Notification center
+			notifications := protected.Group("/notifications")
+			{
+				notifications.GET("", notificationHandler.List)
+				notifications.GET("/unread-count", notificationHandler.UnreadCount)
+				notifications.POST("/read-all", notificationHandler.MarkAllRead)
+				notifications.POST("/:id/read", notificationHandler.MarkRead)
 			}
 
 			// Channels
@@ -798,6 +2476,7 @@ func main() {
 				channels.GET("", channelHandler.List)
 				channels.POST("", channelHandler.Create)
 				// Specific routes must come before generic /:id
+				channels.GET("/credential-health", channelCredentialHealthHandler.List)
 				channels.POST("/test", channelHandler.TestConnection)
 				channels.POST("/test-whatsapp", channelHandler.TestWhatsAppConnection)
 				channels.POST("/test-telegram", channelHandler.TestTelegramConnection)
@@ -812,6 +2491,31 @@ func main() {
 				// WhatsApp Coexistence routes
 				channels.GET("/:id/coexistence-status", waEmbeddedSignupHandler.GetCoexistenceStatus)
 				channels.POST("/:id/subscribe-echoes", waEmbeddedSignupHandler.SubscribeMessageEchoes)
+				// WhatsApp multi-number routes
+				channels.GET("/:id/phone-numbers", phoneNumberHandler.List)
+				channels.POST("/:id/phone-numbers", phoneNumberHandler.Add)
+				channels.PUT("/:id/phone-numbers/:numberId", phoneNumberHandler.Update)
+				channels.DELETE("/:id/phone-numbers/:numberId", phoneNumberHandler.Remove)
+				// WhatsApp quality guard routes (re-enabling requires an admin)
+				channels.GET("/:id/marketing-status", qualityGuardHandler.GetStatus)
+				channels.POST("/:id/marketing-resume", authMiddleware.RequireRole("admin"), qualityGuardHandler.Resume)
+				// Public chat API secret for webchat channels
+				channels.POST("/:id/public-chat-secret", authMiddleware.RequireRole("admin", "owner"), publicChatHandler.RotateSecret)
+				channels.GET("/:id/failover", channelFailoverHandler.GetStatus)
+				// Provider-side webhook registration
+				channels.GET("/:id/webhook", webhookRegistrationHandler.GetStatus)
+				channels.POST("/:id/webhook/register", authMiddleware.RequireRole("admin", "owner"), webhookRegistrationHandler.Register)
+				channels.GET("/:id/probe", channelProbeHandler.Get)
+				channels.POST("/:id/probe", authMiddleware.RequireRole("admin", "owner"), channelProbeHandler.Probe)
+				channels.GET("/:id/token", channelTokenHandler.Get)
+				channels.POST("/:id/token/refresh", authMiddleware.RequireRole("admin", "owner"), channelTokenHandler.Refresh)
+				channels.GET("/:id/credential-health", channelCredentialHealthHandler.Get)
+				channels.POST("/:id/credential-health/check", authMiddleware.RequireRole("admin", "owner"), channelCredentialHealthHandler.Check)
+				channels.GET("/:id/maintenance", channelMaintenanceHandler.List)
+				channels.POST("/:id/maintenance", authMiddleware.RequireRole("admin", "owner"), channelMaintenanceHandler.Create)
+				channels.PUT("/:id/maintenance/:windowId", authMiddleware.RequireRole("admin", "owner"), channelMaintenanceHandler.Update)
+				channels.POST("/:id/maintenance/:windowId/end", authMiddleware.RequireRole("admin", "owner"), channelMaintenanceHandler.End)
+				channels.DELETE("/:id/maintenance/:windowId", authMiddleware.RequireRole("admin", "owner"), channelMaintenanceHandler.Delete)
 				// Generic routes last
 				channels.GET("/:id", channelHandler.Get)
 				channels.PUT("/:id", channelHandler.Update)
@@ -859,6 +2563,24 @@ func main() {
 				bots.PUT("/:id/config", botHandler.UpdateConfig)
 				bots.POST("/:id/escalation-rules", botHandler.AddEscalationRule)
 				bots.POST("/:id/test", botHandler.Test)
+
+				botToolAdmins := authMiddleware.RequireRole("admin", "owner")
+				bots.GET("/:id/tools", botToolHandler.List)
+				bots.POST("/:id/tools", botToolAdmins, botToolHandler.Create)
+				bots.PUT("/:id/tools/:toolId", botToolAdmins, botToolHandler.Update)
+				bots.DELETE("/:id/tools/:toolId", botToolAdmins, botToolHandler.Delete)
+				bots.GET("/:id/tool-executions", botToolHandler.ListExecutions)
+
+				botExperimentAdmins := authMiddleware.RequireRole("admin", "owner")
+				bots.GET("/:id/versions", botExperimentHandler.ListVersions)
+				bots.POST("/:id/versions", botExperimentAdmins, botExperimentHandler.CreateVersion)
+				bots.GET("/:id/versions/:versionId", botExperimentHandler.GetVersion)
+				bots.POST("/:id/versions/:versionId/restore", botExperimentAdmins, botExperimentHandler.RestoreVersion)
+				bots.GET("/:id/experiments", botExperimentHandler.ListExperiments)
+				bots.POST("/:id/experiments", botExperimentAdmins, botExperimentHandler.StartExperiment)
+				bots.GET("/:id/experiments/:experimentId", botExperimentHandler.GetExperiment)
+				bots.POST("/:id/experiments/:experimentId/stop", botExperimentAdmins, botExperimentHandler.StopExperiment)
+				bots.GET("/:id/experiments/:experimentId/report", botExperimentHandler.Report)
 			}
 
 			// AI
@@ -879,6 +2601,7 @@ func main() {
 			{
 				knowledge.GET("", knowledgeHandler.ListKnowledgeBases)
 				knowledge.POST("", knowledgeHandler.CreateKnowledgeBase)
+				knowledge.GET("/shared", knowledgeHandler.ListSharedKnowledgeBases)
 				knowledge.GET("/:id", knowledgeHandler.GetKnowledgeBase)
 				knowledge.PUT("/:id", knowledgeHandler.UpdateKnowledgeBase)
 				knowledge.DELETE("/:id", knowledgeHandler.DeleteKnowledgeBase)
@@ -888,8 +2611,58 @@ func main() {
 				knowledge.GET("/:id/items/:itemId", knowledgeHandler.GetItem)
 				knowledge.PUT("/:id/items/:itemId", knowledgeHandler.UpdateItem)
 				knowledge.DELETE("/:id/items/:itemId", knowledgeHandler.DeleteItem)
+				knowledge.GET("/:id/items/:itemId/versions", knowledgeHandler.ListItemVersions)
+				knowledge.GET("/:id/items/:itemId/diff", knowledgeHandler.DiffItemVersions)
+				knowledge.POST("/:id/items/:itemId/publish", authMiddleware.RequireRole("supervisor", "admin", "owner"), knowledgeHandler.PublishItem)
+				knowledge.POST("/:id/items/:itemId/rollback", authMiddleware.RequireRole("supervisor", "admin", "owner"), knowledgeHandler.RollbackItem)
+				knowledge.POST("/:id/publish", authMiddleware.RequireRole("supervisor", "admin", "owner"), knowledgeHandler.PublishKnowledgeBase)
+				knowledge.GET("/:id/audit", knowledgeHandler.ListAuditEvents)
 				knowledge.POST("/:id/search", knowledgeHandler.Search)
 				knowledge.POST("/:id/regenerate-embeddings", knowledgeHandler.RegenerateEmbeddings)
+				knowledge.GET("/:id/quality", knowledgeFeedbackHandler.QualityReport)
+			}
+
+			// Knowledge gaps revealed by negative answer feedback
+			knowledgeGaps := protected.Group("/knowledge-gaps")
+			knowledgeGaps.Use(authMiddleware.RequireRole("supervisor", "admin", "owner"))
+			{
+				knowledgeGaps.GET("", knowledgeFeedbackHandler.ListGaps)
+				knowledgeGaps.POST("/:id/resolve", knowledgeFeedbackHandler.ResolveGap)
+				knowledgeGaps.POST("/:id/dismiss", knowledgeFeedbackHandler.DismissGap)
+			}
+
+			// Knowledge inboxes
+			knowledgeInboxes := protected.Group("/knowledge-inboxes")
+			knowledgeInboxes.Use(authMiddleware.RequireRole("admin", "owner"))
+			{
+				knowledgeInboxes.GET("", knowledgeInboxHandler.ListInboxes)
+				knowledgeInboxes.POST("", knowledgeInboxHandler.CreateInbox)
+				knowledgeInboxes.GET("/:id", knowledgeInboxHandler.GetInbox)
+				knowledgeInboxes.PUT("/:id", knowledgeInboxHandler.UpdateInbox)
+				knowledgeInboxes.DELETE("/:id", knowledgeInboxHandler.DeleteInbox)
+			}
+
+			// Knowledge submissions awaiting review
+			knowledgeSubmissions := protected.Group("/knowledge-submissions")
+			knowledgeSubmissions.Use(authMiddleware.RequireRole("supervisor", "admin", "owner"))
+			{
+				knowledgeSubmissions.GET("", knowledgeInboxHandler.ListSubmissions)
+				knowledgeSubmissions.GET("/:id", knowledgeInboxHandler.GetSubmission)
+				knowledgeSubmissions.PUT("/:id", knowledgeInboxHandler.UpdateSubmission)
+				knowledgeSubmissions.POST("/:id/approve", knowledgeInboxHandler.ApproveSubmission)
+				knowledgeSubmissions.POST("/:id/reject", knowledgeInboxHandler.RejectSubmission)
+			}
+
+			// Q&A pairs mined from resolved conversations awaiting review
+			knowledgeCandidates := protected.Group("/knowledge-candidates")
+			knowledgeCandidates.Use(authMiddleware.RequireRole("supervisor", "admin", "owner"))
+			{
+				knowledgeCandidates.GET("", knowledgeCurationHandler.ListCandidates)
+				knowledgeCandidates.POST("/mine", knowledgeCurationHandler.Mine)
+				knowledgeCandidates.GET("/:id", knowledgeCurationHandler.GetCandidate)
+				knowledgeCandidates.PUT("/:id", knowledgeCurationHandler.UpdateCandidate)
+				knowledgeCandidates.POST("/:id/publish", knowledgeCurationHandler.PublishCandidate)
+				knowledgeCandidates.POST("/:id/reject", knowledgeCurationHandler.RejectCandidate)
 			}
 
 			// Observability
@@ -940,6 +2713,15 @@ func main() {
 				analyticsRoutes.GET("/flows", analyticsHandler.GetFlows)
 				analyticsRoutes.GET("/escalations", analyticsHandler.GetEscalations)
 				analyticsRoutes.GET("/channels", analyticsHandler.GetChannels)
+				analyticsRoutes.GET("/labels", analyticsHandler.GetLabels)
+				analyticsRoutes.GET("/dispositions", analyticsHandler.GetDispositions)
+				analyticsRoutes.GET("/reopens", analyticsHandler.GetReopens)
+				analyticsRoutes.GET("/languages", analyticsHandler.GetLanguages)
+				analyticsRoutes.GET("/whatsapp-costs", analyticsHandler.GetWhatsAppCosts)
+				analyticsRoutes.GET("/whatsapp-costs/export", analyticsHandler.ExportWhatsAppCosts)
+				analyticsRoutes.GET("/agent-quality", analyticsHandler.GetAgentQuality)
+				analyticsRoutes.GET("/quick-replies", analyticsHandler.GetQuickReplies)
+				analyticsRoutes.GET("/copilot", analyticsHandler.GetCopilot)
 			}
 
 			// WhatsApp Analytics (per-channel)
@@ -948,6 +2730,7 @@ func main() {
 				waAnalytics.GET("/conversations", whatsappAnalyticsHandler.GetConversationAnalytics)
 				waAnalytics.GET("/phone", whatsappAnalyticsHandler.GetPhoneNumberAnalytics)
 				waAnalytics.GET("/templates/:templateId", whatsappAnalyticsHandler.GetTemplateAnalytics)
+				waAnalytics.GET("/template-performance", whatsappAnalyticsHandler.GetTemplatePerformance)
 				waAnalytics.GET("/stats", whatsappAnalyticsHandler.GetAggregatedStats)
 				waAnalytics.GET("/export", whatsappAnalyticsHandler.ExportAnalytics)
 				waAnalytics.GET("/dashboard", whatsappAnalyticsHandler.GetDashboardData)
@@ -961,6 +2744,9 @@ func main() {
 				paymentsRoutes.GET("/:paymentId", paymentsHandler.GetPayment)
 				paymentsRoutes.GET("/reference/:referenceId", paymentsHandler.GetPaymentByReference)
 				paymentsRoutes.POST("/:paymentId/refund", paymentsHandler.ProcessRefund)
+				paymentsRoutes.GET("/refunds", authMiddleware.RequireRole("finance", "admin", "owner"), paymentsHandler.GetRefundReport)
+				paymentsRoutes.POST("/refunds/:refundId/approve", authMiddleware.RequireRole("finance"), paymentsHandler.ApproveRefund)
+				paymentsRoutes.POST("/refunds/:refundId/reject", authMiddleware.RequireRole("finance"), paymentsHandler.RejectRefund)
 				paymentsRoutes.GET("/customer/:phone", paymentsHandler.GetCustomerPayments)
 			}
 
@@ -999,6 +2785,7 @@ func main() {
 				{
 					vreRoutes.POST("/render", vreHandler.Render)
 					vreRoutes.POST("/render-and-send", vreHandler.RenderAndSend)
+					vreRoutes.POST("/render-async", vreHandler.RenderAsync)
 					vreRoutes.GET("/templates", vreHandler.ListTemplates)
 					vreRoutes.GET("/templates/:id/preview", vreHandler.PreviewTemplate)
 					vreRoutes.POST("/templates/:id", vreHandler.UploadTemplate)
@@ -1022,6 +2809,21 @@ func main() {
 				convMgmt.GET("/:id/escalation-context", conversationHandler.GetEscalationContext)
 			}
 
+			// Agent presence
+			agents := protected.Group("/agents")
+			{
+				agents.GET("/presence", agentPresenceHandler.List)
+				agents.PUT("/presence", agentPresenceHandler.Set)
+				agents.GET("/reassignments", authMiddleware.RequireRole("supervisor", "admin", "owner"), conversationReassignmentHandler.List)
+			}
+
+			// Mobile agents
+			mobile := protected.Group("/mobile")
+			mobile.Use(middleware.Gzip())
+			{
+				mobile.GET("/sync", syncHandler.Changes)
+			}
+
 			// User management (admin only)
 			users := protected.Group("/users")
 			users.Use(authMiddleware.RequireRole("admin"))
@@ -1039,6 +2841,7 @@ func main() {
 			{
 				apiKeys.GET("", apiKeyHandler.List)
 				apiKeys.POST("", apiKeyHandler.Create)
+				apiKeys.POST("/:id/revoke", apiKeyHandler.Revoke)
 				apiKeys.DELETE("/:id", apiKeyHandler.Delete)
 			}
 		}
@@ -1082,6 +2885,11 @@ func main() {
 		aiConsumer.Stop()
 	}
 
+	// Hand WhatsApp channels over to standby instances
+	if whatsAppFailover {
+		channelFailoverService.ReleaseAll(context.Background())
+	}
+
 	// Disconnect adapters
 	webchatAdapter.Disconnect(context.Background())
 	whatsappOfficialAdapter.Disconnect(context.Background())
@@ -1092,6 +2900,7 @@ func main() {
 	instagramAdapter.Disconnect(context.Background())
 	rcsAdapter.Disconnect(context.Background())
 	emailAdapter.Disconnect(context.Background())
+	emailSender.Close()
 
 	// Create context with timeout for shutdown
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeout)*time.Second)
@@ -1179,6 +2988,8 @@ func registerWhatsAppAdvancedClient(
 		OrganizationID: channel.TenantID,
 		ChannelID:      channel.ID,
 		Store:          paymentRepo,
+		RefundStore:    paymentRepo,
+		RefundPolicy:   paymentRefundPolicy(channel),
 	}))
 	callingHandler.RegisterClient(channel.ID, calling.NewClient(&calling.ClientConfig{
 		AccessToken:   accessToken,
@@ -1215,6 +3026,89 @@ func channelConfigValue(channel *entity.Channel, key string) string {
 	return ""
 }
 
+// newMediaStorage creates the object storage for inbound media, or nil when disabled
+// newSecretResolver creates the resolver of secret references with the backends
+// configured: Vault KV (VAULT_ADDR, VAULT_TOKEN, VAULT_KV_MOUNT) and AWS Secrets
+// Manager (AWS_SECRETS_REGION with the standard AWS credential variables).
+// SECRETS_CACHE_TTL sets how long secrets are cached.
+func newSecretResolver() *secrets.Resolver {
+	ttl, _ := time.ParseDuration(os.Getenv("SECRETS_CACHE_TTL"))
+	resolver := secrets.NewResolver(ttl)
+	if vaultAddr := os.Getenv("VAULT_ADDR"); vaultAddr != "" {
+		resolver.Register(secrets.NewVaultBackend(vaultAddr, os.Getenv("VAULT_TOKEN"), os.Getenv("VAULT_KV_MOUNT")))
+		logger.Info("Vault secrets backend configured")
+	}
+	if region := os.Getenv("AWS_SECRETS_REGION"); region != "" {
+		resolver.Register(secrets.NewAWSBackend(region, os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"), os.Getenv("AWS_SESSION_TOKEN")))
+		logger.Info("AWS Secrets Manager backend configured")
+	}
+	return resolver
+}
+
+// resolveSecretEnv returns the environment variable name, resolved when it
+// references a secret. A secret that cannot be resolved yields an empty value.
+func resolveSecretEnv(ctx context.Context, resolver *secrets.Resolver, name string) string {
+	value, err := resolver.Resolve(ctx, os.Getenv(name))
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Failed to resolve secret of %s: %v", name, err))
+		return ""
+	}
+	return value
+}
+
+func newMediaStorage(cfg config.StorageConfig) (storageLib.ObjectStorage, error) {
+	switch cfg.Driver {
+	case "", "none":
+		return nil, nil
+	case "local":
+		return storageLib.NewLocalClient(cfg.LocalDir, ""), nil
+	case "minio":
+		return storageLib.NewMinIOClient(cfg.Endpoint, cfg.AccessKey, cfg.SecretKey, cfg.Bucket, cfg.Region, cfg.UseSSL)
+	case "s3":
+		return storageLib.NewS3Client(cfg.AccessKey, cfg.SecretKey, cfg.Bucket, cfg.Region)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
+
+// newSpeechTranscriber creates the speech-to-text provider named by STT_PROVIDER:
+// openai (Whisper, the default when OPENAI_API_KEY is set), whispercpp (a local
+// whisper.cpp server at WHISPER_CPP_URL) or google (GOOGLE_STT_API_KEY). It returns
+// nil when no provider is configured.
+func newSpeechTranscriber(openAIKey string) (service.SpeechTranscriber, string) {
+	provider := os.Getenv("STT_PROVIDER")
+	if provider == "" && openAIKey != "" {
+		provider = stt.ProviderOpenAI
+	}
+
+	switch provider {
+	case "":
+		return nil, ""
+	case stt.ProviderOpenAI:
+		if openAIKey == "" {
+			logger.Warn("STT_PROVIDER is openai but OPENAI_API_KEY is not set - calls are not transcribed")
+			return nil, ""
+		}
+		return openai.NewClient(&openai.ClientConfig{APIKey: openAIKey, OrgID: os.Getenv("OPENAI_ORG_ID")}), provider
+	case stt.ProviderWhisperCpp:
+		whisperURL := os.Getenv("WHISPER_CPP_URL")
+		if whisperURL == "" {
+			whisperURL = "http://localhost:8080"
+		}
+		return stt.NewWhisperCppTranscriber(whisperURL), provider
+	case stt.ProviderGoogle:
+		apiKey := os.Getenv("GOOGLE_STT_API_KEY")
+		if apiKey == "" {
+			logger.Warn("STT_PROVIDER is google but GOOGLE_STT_API_KEY is not set - calls are not transcribed")
+			return nil, ""
+		}
+		return stt.NewGoogleTranscriber(apiKey), provider
+	default:
+		logger.Warn(fmt.Sprintf("Unknown STT_PROVIDER %q - calls are not transcribed", provider))
+		return nil, ""
+	}
+}
+
 func paymentGatewayConfig(config map[string]string) *payments.GatewayConfig {
 	if config == nil {
 		return nil
@@ -1239,20 +3133,15 @@ func paymentGatewayConfig(config map[string]string) *payments.GatewayConfig {
 	}
 }
 
-// Helper function to convert status string to entity.MessageStatus
-func toMessageStatus(status string) entity.MessageStatus {
-	switch status {
-	case "sent":
-		return entity.MessageStatusSent
-	case "delivered":
-		return entity.MessageStatusDelivered
-	case "read":
-		return entity.MessageStatusRead
-	case "failed":
-		return entity.MessageStatusFailed
-	default:
-		return entity.MessageStatusPending
+// paymentRefundPolicy reads the refund approval thresholds of a channel. An
+// invalid spec requires approval for every refund rather than none.
+func paymentRefundPolicy(channel *entity.Channel) *payments.RefundPolicy {
+	policy, err := payments.ParseRefundPolicy(channelConfigValue(channel, "refund_approval_thresholds"))
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Invalid refund approval thresholds for channel %s, all refunds need approval: %v", channel.ID, err))
+		return &payments.RefundPolicy{Thresholds: map[string]int64{"*": 0}}
 	}
+	return policy
 }
 
 // ListParams alias for database package
@@ -77,6 +77,21 @@ type MessageRequest struct {
 	StopSeq     []string      `json:"stop_sequences,omitempty"`
 	Stream      bool          `json:"stream,omitempty"`
 	Metadata    *Metadata     `json:"metadata,omitempty"`
+	Tools       []Tool        `json:"tools,omitempty"`
+	ToolChoice  *ToolChoice   `json:"tool_choice,omitempty"`
+}
+
+// Tool declares a tool the model may use
+type Tool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+// ToolChoice controls how the model uses tools
+type ToolChoice struct {
+	Type string `json:"type"`           // auto, any, tool, none
+	Name string `json:"name,omitempty"` // Tool to use when type is tool
 }
 
 // Message represents a message in the conversation
@@ -87,9 +102,19 @@ type Message struct {
 
 // ContentBlock represents a content block in a message
 type ContentBlock struct {
-	Type string `json:"type"` // text, image
+	Type string `json:"type"` // text, image, tool_use, tool_result
 	Text string `json:"text,omitempty"`
 	// Image content would go here for multimodal
+
+	// tool_use blocks
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+
+	// tool_result blocks
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   string `json:"content,omitempty"`
+	IsError   bool   `json:"is_error,omitempty"`
 }
 
 // Metadata represents optional metadata
@@ -76,9 +76,34 @@ func (p *Provider) Complete(ctx context.Context, req *service.CompletionRequest)
 
 	// Extract system prompt and convert messages
 	for _, msg := range req.Messages {
-		if msg.Role == "system" {
+		switch {
+		case msg.Role == "system":
 			systemPrompt = msg.Content
 			continue
+		case msg.Role == "tool":
+			// The results of the tools called in an assistant turn go back together
+			// in the next user message
+			block := ContentBlock{Type: "tool_result", ToolUseID: msg.ToolCallID, Content: msg.Content}
+			if last := len(messages) - 1; last >= 0 && isToolResultMessage(messages[last]) {
+				messages[last].Content = append(messages[last].Content, block)
+			} else {
+				messages = append(messages, Message{Role: "user", Content: []ContentBlock{block}})
+			}
+			continue
+		case msg.Role == "assistant" && len(msg.ToolCalls) > 0:
+			message := Message{Role: "assistant"}
+			if msg.Content != "" {
+				message.Content = append(message.Content, ContentBlock{Type: "text", Text: msg.Content})
+			}
+			for _, call := range msg.ToolCalls {
+				input := json.RawMessage("{}")
+				if len(call.Arguments) > 0 {
+					input, _ = json.Marshal(call.Arguments)
+				}
+				message.Content = append(message.Content, ContentBlock{Type: "tool_use", ID: call.ID, Name: call.Name, Input: input})
+			}
+			messages = append(messages, message)
+			continue
 		}
 		// Anthropic only accepts "user" and "assistant" roles
 		role := msg.Role
@@ -118,6 +143,23 @@ func (p *Provider) Complete(ctx context.Context, req *service.CompletionRequest)
 		Temperature: temperature,
 		System:      systemPrompt,
 	}
+	for _, tool := range req.Tools {
+		msgReq.Tools = append(msgReq.Tools, Tool{
+			Name:        tool.Name,
+			Description: tool.Description,
+			InputSchema: tool.Parameters,
+		})
+	}
+	if len(msgReq.Tools) > 0 && req.ToolChoice != "" {
+		switch req.ToolChoice {
+		case "auto", "none":
+			msgReq.ToolChoice = &ToolChoice{Type: req.ToolChoice}
+		case "required":
+			msgReq.ToolChoice = &ToolChoice{Type: "any"}
+		default:
+			msgReq.ToolChoice = &ToolChoice{Type: "tool", Name: req.ToolChoice}
+		}
+	}
 
 	// Call Anthropic API
 	resp, err := p.client.CreateMessage(ctx, msgReq)
@@ -125,9 +167,22 @@ func (p *Provider) Complete(ctx context.Context, req *service.CompletionRequest)
 		return nil, fmt.Errorf("Anthropic completion failed: %w", err)
 	}
 
-	// Extract text content
+	// Extract text content and tool calls
 	content := GetTextContent(resp)
-	if content == "" {
+	var toolCalls []*entity.ToolCall
+	for _, block := range resp.Content {
+		if block.Type != "tool_use" {
+			continue
+		}
+		toolCall := &entity.ToolCall{ID: block.ID, Name: block.Name}
+		if len(block.Input) > 0 {
+			if err := toolCall.ParseArguments(block.Input); err != nil {
+				return nil, fmt.Errorf("invalid input for tool %s: %w", block.Name, err)
+			}
+		}
+		toolCalls = append(toolCalls, toolCall)
+	}
+	if content == "" && len(toolCalls) == 0 {
 		return nil, fmt.Errorf("no text content in Anthropic response")
 	}
 
@@ -139,6 +194,8 @@ func (p *Provider) Complete(ctx context.Context, req *service.CompletionRequest)
 		finishReason = "length"
 	} else if resp.StopReason == "stop_sequence" {
 		finishReason = "stop"
+	} else if resp.StopReason == "tool_use" {
+		finishReason = "tool_calls"
 	}
 
 	return &service.CompletionResponse{
@@ -149,9 +206,20 @@ func (p *Provider) Complete(ctx context.Context, req *service.CompletionRequest)
 		CompTokens:   resp.Usage.OutputTokens,
 		FinishReason: finishReason,
 		LatencyMs:    latencyMs,
+		ToolCalls:    toolCalls,
 	}, nil
 }
 
+// SupportsTools reports that Anthropic models can use tools
+func (p *Provider) SupportsTools() bool {
+	return true
+}
+
+// isToolResultMessage checks if a message carries tool results
+func isToolResultMessage(message Message) bool {
+	return message.Role == "user" && len(message.Content) > 0 && message.Content[0].Type == "tool_result"
+}
+
 // Embed generates embeddings for text
 // Note: Anthropic doesn't have a native embedding API, so we return an error
 func (p *Provider) Embed(ctx context.Context, req *service.EmbeddingRequest) (*service.EmbeddingResponse, error) {
@@ -196,6 +196,62 @@ func TestProvider_Complete_MaxTokensFinishReason(t *testing.T) {
 	assert.Equal(t, "length", resp.FinishReason)
 }
 
+func TestProvider_Complete_WithTools(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req MessageRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		require.Len(t, req.Tools, 1)
+		assert.Equal(t, "order_status", req.Tools[0].Name)
+		require.NotNil(t, req.ToolChoice)
+		assert.Equal(t, "any", req.ToolChoice.Type)
+
+		// The assistant turn carries tool_use blocks and both results share one user message
+		require.Len(t, req.Messages, 3)
+		assert.Equal(t, "assistant", req.Messages[1].Role)
+		require.Len(t, req.Messages[1].Content, 3)
+		assert.Equal(t, "tool_use", req.Messages[1].Content[1].Type)
+		assert.JSONEq(t, `{"order_id":"A-1"}`, string(req.Messages[1].Content[1].Input))
+		assert.JSONEq(t, `{}`, string(req.Messages[1].Content[2].Input))
+		assert.Equal(t, "user", req.Messages[2].Role)
+		require.Len(t, req.Messages[2].Content, 2)
+		assert.Equal(t, "tool_result", req.Messages[2].Content[0].Type)
+		assert.Equal(t, "call-2", req.Messages[2].Content[1].ToolUseID)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(MessageResponse{
+			Model:      "claude-3-5-sonnet-20241022",
+			StopReason: "tool_use",
+			Content: []ContentBlock{
+				{Type: "tool_use", ID: "call-3", Name: "order_status", Input: json.RawMessage(`{"order_id":"B-2"}`)},
+			},
+		})
+	}))
+	defer server.Close()
+
+	p := NewProvider(&ProviderConfig{APIKey: "test-key", BaseURL: server.URL})
+	assert.True(t, service.SupportsTools(p))
+
+	resp, err := p.Complete(context.Background(), &service.CompletionRequest{
+		Messages: []service.Message{
+			{Role: "user", Content: "Where are A-1 and my invoice?"},
+			{Role: "assistant", Content: "Let me check.", ToolCalls: []*entity.ToolCall{
+				{ID: "call-1", Name: "order_status", Arguments: map[string]interface{}{"order_id": "A-1"}},
+				{ID: "call-2", Name: "last_invoice"},
+			}},
+			{Role: "tool", Content: `{"status":"shipped"}`, ToolCallID: "call-1"},
+			{Role: "tool", Content: `{"total":10}`, ToolCallID: "call-2"},
+		},
+		Tools:      []*entity.Tool{{Name: "order_status", Description: "Order status", Parameters: map[string]interface{}{"type": "object"}}},
+		ToolChoice: "required",
+	})
+
+	require.NoError(t, err)
+	require.Len(t, resp.ToolCalls, 1)
+	assert.Equal(t, "call-3", resp.ToolCalls[0].ID)
+	assert.Equal(t, "B-2", resp.ToolCalls[0].Arguments["order_id"])
+	assert.Equal(t, "tool_calls", resp.FinishReason)
+}
+
 func TestProvider_Embed(t *testing.T) {
 	p := NewProvider(&ProviderConfig{APIKey: "test"})
 
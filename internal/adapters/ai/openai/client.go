@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -81,13 +83,54 @@ type ChatCompletionRequest struct {
 	FrequencyPenalty float64           `json:"frequency_penalty,omitempty"`
 	User             string            `json:"user,omitempty"`
 	ResponseFormat   *ResponseFormat   `json:"response_format,omitempty"`
+	Tools            []ToolDefinition  `json:"tools,omitempty"`
+	ToolChoice       interface{}       `json:"tool_choice,omitempty"` // auto, none, required or a ToolChoice
 }
 
 // ChatMessage represents a message in the chat
 type ChatMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
-	Name    string `json:"name,omitempty"`
+	Role       string     `json:"role"`
+	Content    string     `json:"content"`
+	Name       string     `json:"name,omitempty"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`   // Functions called by the assistant
+	ToolCallID string     `json:"tool_call_id,omitempty"` // Call answered by a tool message
+}
+
+// ToolDefinition declares a function the model may call
+type ToolDefinition struct {
+	Type     string             `json:"type"` // function
+	Function FunctionDefinition `json:"function"`
+}
+
+// FunctionDefinition describes a function and its JSON Schema parameters
+type FunctionDefinition struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// ToolChoice forces the model to call a specific function
+type ToolChoice struct {
+	Type     string       `json:"type"` // function
+	Function FunctionName `json:"function"`
+}
+
+// FunctionName names a function
+type FunctionName struct {
+	Name string `json:"name"`
+}
+
+// ToolCall is a function call made by the model
+type ToolCall struct {
+	ID       string       `json:"id"`
+	Type     string       `json:"type"` // function
+	Function FunctionCall `json:"function"`
+}
+
+// FunctionCall carries the name of the called function and its arguments as a JSON string
+type FunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
 }
 
 // ResponseFormat specifies the format of the response
@@ -127,6 +170,13 @@ type EmbeddingRequest struct {
 	User  string `json:"user,omitempty"`
 }
 
+// BatchEmbeddingRequest represents an embedding request for several inputs
+type BatchEmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+	User  string   `json:"user,omitempty"`
+}
+
 // EmbeddingResponse represents an embedding response
 type EmbeddingResponse struct {
 	Object string          `json:"object"`
@@ -142,6 +192,20 @@ type EmbeddingData struct {
 	Index     int       `json:"index"`
 }
 
+// TranscriptionRequest represents an audio transcription request
+type TranscriptionRequest struct {
+	Model    string `json:"model"`
+	File     []byte `json:"-"`
+	Filename string `json:"-"`
+	Language string `json:"language,omitempty"` // ISO-639-1 code
+	Prompt   string `json:"prompt,omitempty"`
+}
+
+// TranscriptionResponse represents an audio transcription response
+type TranscriptionResponse struct {
+	Text string `json:"text"`
+}
+
 // APIError represents an OpenAI API error
 type APIError struct {
 	Error struct {
@@ -200,6 +264,15 @@ func (c *Client) CreateChatCompletion(ctx context.Context, req *ChatCompletionRe
 
 // CreateEmbedding creates an embedding
 func (c *Client) CreateEmbedding(ctx context.Context, req *EmbeddingRequest) (*EmbeddingResponse, error) {
+	return c.createEmbeddings(ctx, req)
+}
+
+// CreateEmbeddings creates the embeddings of several inputs in one request
+func (c *Client) CreateEmbeddings(ctx context.Context, req *BatchEmbeddingRequest) (*EmbeddingResponse, error) {
+	return c.createEmbeddings(ctx, req)
+}
+
+func (c *Client) createEmbeddings(ctx context.Context, req interface{}) (*EmbeddingResponse, error) {
 	// Wait for rate limiter
 	if err := c.rateLimiter.Wait(ctx); err != nil {
 		return nil, fmt.Errorf("rate limit wait: %w", err)
@@ -244,6 +317,96 @@ func (c *Client) CreateEmbedding(ctx context.Context, req *EmbeddingRequest) (*E
 	return &result, nil
 }
 
+// CreateTranscription transcribes audio to text
+func (c *Client) CreateTranscription(ctx context.Context, req *TranscriptionRequest) (*TranscriptionResponse, error) {
+	// Wait for rate limiter
+	if err := c.rateLimiter.Wait(ctx); err != nil {
+		return nil, fmt.Errorf("rate limit wait: %w", err)
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	filename := req.Filename
+	if filename == "" {
+		filename = "audio.wav"
+	}
+	part, err := writer.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, fmt.Errorf("create form file: %w", err)
+	}
+	if _, err := part.Write(req.File); err != nil {
+		return nil, fmt.Errorf("write form file: %w", err)
+	}
+	fields := map[string]string{
+		"model":    req.Model,
+		"language": req.Language,
+		"prompt":   req.Prompt,
+	}
+	for name, value := range fields {
+		if value == "" {
+			continue
+		}
+		if err := writer.WriteField(name, value); err != nil {
+			return nil, fmt.Errorf("write form field: %w", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("close form: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/audio/transcriptions", &body)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+
+	c.setHeaders(httpReq)
+	httpReq.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr APIError
+		if err := json.Unmarshal(respBody, &apiErr); err == nil && apiErr.Error.Message != "" {
+			return nil, fmt.Errorf("API error (%d): %s", resp.StatusCode, apiErr.Error.Message)
+		}
+		return nil, fmt.Errorf("API error (%d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var result TranscriptionResponse
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, fmt.Errorf("unmarshal response: %w", err)
+	}
+
+	return &result, nil
+}
+
+// Transcribe transcribes audio with the default transcription model. Language
+// tags such as "pt-BR" are reduced to their ISO-639-1 code.
+func (c *Client) Transcribe(ctx context.Context, audio []byte, filename, language string) (string, error) {
+	if i := strings.IndexAny(language, "-_"); i > 0 {
+		language = language[:i]
+	}
+	resp, err := c.CreateTranscription(ctx, &TranscriptionRequest{
+		Model:    c.DefaultTranscriptionModel(),
+		File:     audio,
+		Filename: filename,
+		Language: strings.ToLower(language),
+	})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(resp.Text), nil
+}
+
 // setHeaders sets the required headers for API requests
 func (c *Client) setHeaders(req *http.Request) {
 	req.Header.Set("Content-Type", "application/json")
@@ -292,3 +455,8 @@ func (c *Client) DefaultModel() string {
 func (c *Client) DefaultEmbeddingModel() string {
 	return "text-embedding-ada-002"
 }
+
+// DefaultTranscriptionModel returns the default audio transcription model
+func (c *Client) DefaultTranscriptionModel() string {
+	return "whisper-1"
+}
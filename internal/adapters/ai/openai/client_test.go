@@ -3,6 +3,7 @@ package openai
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -251,3 +252,36 @@ func TestClient_CreateEmbedding(t *testing.T) {
 	assert.Len(t, resp.Data[0].Embedding, 5)
 	assert.Equal(t, 5, resp.Usage.TotalTokens)
 }
+
+func TestClient_Transcribe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/audio/transcriptions", r.URL.Path)
+		assert.Equal(t, "Bearer sk-audio-key", r.Header.Get("Authorization"))
+
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		assert.Equal(t, "whisper-1", r.FormValue("model"))
+		assert.Equal(t, "pt", r.FormValue("language"))
+
+		file, header, err := r.FormFile("file")
+		require.NoError(t, err)
+		defer file.Close()
+		assert.Equal(t, "voicemail.wav", header.Filename)
+		data, _ := io.ReadAll(file)
+		assert.Equal(t, "RIFF-audio", string(data))
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(TranscriptionResponse{Text: " Olá, me liguem de volta. "})
+	}))
+	defer server.Close()
+
+	client := NewClient(&ClientConfig{
+		APIKey:  "sk-audio-key",
+		BaseURL: server.URL,
+	})
+
+	text, err := client.Transcribe(context.Background(), []byte("RIFF-audio"), "voicemail.wav", "pt-BR")
+
+	require.NoError(t, err)
+	assert.Equal(t, "Olá, me liguem de volta.", text)
+}
@@ -93,10 +93,23 @@ func (p *Provider) Complete(ctx context.Context, req *service.CompletionRequest)
 
 	// Convert messages
 	for _, msg := range req.Messages {
-		messages = append(messages, ChatMessage{
-			Role:    msg.Role,
-			Content: msg.Content,
-		})
+		message := ChatMessage{
+			Role:       msg.Role,
+			Content:    msg.Content,
+			ToolCallID: msg.ToolCallID,
+		}
+		for _, call := range msg.ToolCalls {
+			arguments := []byte("{}")
+			if len(call.Arguments) > 0 {
+				arguments, _ = json.Marshal(call.Arguments)
+			}
+			message.ToolCalls = append(message.ToolCalls, ToolCall{
+				ID:       call.ID,
+				Type:     "function",
+				Function: FunctionCall{Name: call.Name, Arguments: string(arguments)},
+			})
+		}
+		messages = append(messages, message)
 	}
 
 	// Determine model to use
@@ -123,6 +136,24 @@ func (p *Provider) Complete(ctx context.Context, req *service.CompletionRequest)
 		MaxTokens:   maxTokens,
 		Temperature: temperature,
 	}
+	for _, tool := range req.Tools {
+		chatReq.Tools = append(chatReq.Tools, ToolDefinition{
+			Type: "function",
+			Function: FunctionDefinition{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  tool.Parameters,
+			},
+		})
+	}
+	if len(chatReq.Tools) > 0 && req.ToolChoice != "" {
+		switch req.ToolChoice {
+		case "auto", "none", "required":
+			chatReq.ToolChoice = req.ToolChoice
+		default:
+			chatReq.ToolChoice = ToolChoice{Type: "function", Function: FunctionName{Name: req.ToolChoice}}
+		}
+	}
 
 	// Call OpenAI API
 	resp, err := p.client.CreateChatCompletion(ctx, chatReq)
@@ -137,7 +168,7 @@ func (p *Provider) Complete(ctx context.Context, req *service.CompletionRequest)
 
 	latencyMs := time.Since(startTime).Milliseconds()
 
-	return &service.CompletionResponse{
+	completion := &service.CompletionResponse{
 		Content:      resp.Choices[0].Message.Content,
 		Model:        resp.Model,
 		TokensUsed:   resp.Usage.TotalTokens,
@@ -145,7 +176,22 @@ func (p *Provider) Complete(ctx context.Context, req *service.CompletionRequest)
 		CompTokens:   resp.Usage.CompletionTokens,
 		FinishReason: resp.Choices[0].FinishReason,
 		LatencyMs:    latencyMs,
-	}, nil
+	}
+	for _, call := range resp.Choices[0].Message.ToolCalls {
+		toolCall := &entity.ToolCall{ID: call.ID, Name: call.Function.Name}
+		if call.Function.Arguments != "" {
+			if err := toolCall.ParseArguments(json.RawMessage(call.Function.Arguments)); err != nil {
+				return nil, fmt.Errorf("invalid arguments for tool %s: %w", call.Function.Name, err)
+			}
+		}
+		completion.ToolCalls = append(completion.ToolCalls, toolCall)
+	}
+	return completion, nil
+}
+
+// SupportsTools reports that OpenAI models can call tools
+func (p *Provider) SupportsTools() bool {
+	return true
 }
 
 // Embed generates embeddings for text
@@ -176,6 +222,41 @@ func (p *Provider) Embed(ctx context.Context, req *service.EmbeddingRequest) (*s
 	}, nil
 }
 
+// EmbedBatch generates the embeddings of several texts in one request
+func (p *Provider) EmbedBatch(ctx context.Context, req *service.BatchEmbeddingRequest) (*service.BatchEmbeddingResponse, error) {
+	model := req.Model
+	if model == "" {
+		model = p.embeddingModel
+	}
+
+	resp, err := p.client.CreateEmbeddings(ctx, &BatchEmbeddingRequest{
+		Model: model,
+		Input: req.Texts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("OpenAI batch embedding failed: %w", err)
+	}
+
+	if len(resp.Data) != len(req.Texts) {
+		return nil, fmt.Errorf("expected %d embeddings, got %d", len(req.Texts), len(resp.Data))
+	}
+
+	// Data is not guaranteed to be in input order
+	embeddings := make([][]float64, len(req.Texts))
+	for _, data := range resp.Data {
+		if data.Index < 0 || data.Index >= len(embeddings) {
+			return nil, fmt.Errorf("embedding index %d out of range", data.Index)
+		}
+		embeddings[data.Index] = data.Embedding
+	}
+
+	return &service.BatchEmbeddingResponse{
+		Embeddings: embeddings,
+		Model:      resp.Model,
+		TokensUsed: resp.Usage.TotalTokens,
+	}, nil
+}
+
 // ClassifyIntent classifies message intent using the LLM
 func (p *Provider) ClassifyIntent(ctx context.Context, req *service.IntentClassificationRequest) (*entity.IntentResult, error) {
 	// Build prompt for intent classification
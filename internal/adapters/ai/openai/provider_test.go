@@ -230,6 +230,41 @@ func TestProvider_Embed(t *testing.T) {
 	assert.Equal(t, 3, resp.TokensUsed)
 }
 
+func TestProvider_EmbedBatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/embeddings", r.URL.Path)
+
+		var req BatchEmbeddingRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		assert.Equal(t, []string{"first", "second"}, req.Input)
+
+		// Out of input order
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(EmbeddingResponse{
+			Data: []EmbeddingData{
+				{Embedding: []float64{0.2}, Index: 1},
+				{Embedding: []float64{0.1}, Index: 0},
+			},
+			Model: "text-embedding-ada-002",
+			Usage: Usage{TotalTokens: 4},
+		})
+	}))
+	defer server.Close()
+
+	p := NewProvider(&ProviderConfig{
+		APIKey:  "sk-test",
+		BaseURL: server.URL,
+	})
+
+	resp, err := p.EmbedBatch(context.Background(), &service.BatchEmbeddingRequest{
+		Texts: []string{"first", "second"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, [][]float64{{0.1}, {0.2}}, resp.Embeddings)
+	assert.Equal(t, 4, resp.TokensUsed)
+}
+
 func TestProvider_Embed_Error(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
@@ -260,3 +295,53 @@ func TestProvider_Embed_Error(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "OpenAI embedding failed")
 }
+
+func TestProvider_Complete_WithTools(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatCompletionRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		require.Len(t, req.Tools, 1)
+		assert.Equal(t, "order_status", req.Tools[0].Function.Name)
+		assert.Equal(t, "none", req.ToolChoice)
+		require.Len(t, req.Messages, 3)
+		assert.Equal(t, `{"order_id":"A-1"}`, req.Messages[1].ToolCalls[0].Function.Arguments)
+		assert.Equal(t, "tool", req.Messages[2].Role)
+		assert.Equal(t, "call-1", req.Messages[2].ToolCallID)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ChatCompletionResponse{
+			Model: "gpt-4-turbo-preview",
+			Choices: []Choice{{
+				Message: ChatMessage{
+					Role: "assistant",
+					ToolCalls: []ToolCall{{
+						ID:       "call-2",
+						Type:     "function",
+						Function: FunctionCall{Name: "order_status", Arguments: `{"order_id":"B-2"}`},
+					}},
+				},
+				FinishReason: "tool_calls",
+			}},
+		})
+	}))
+	defer server.Close()
+
+	p := NewProvider(&ProviderConfig{APIKey: "sk-test", BaseURL: server.URL})
+	assert.True(t, service.SupportsTools(p))
+
+	resp, err := p.Complete(context.Background(), &service.CompletionRequest{
+		Messages: []service.Message{
+			{Role: "user", Content: "Where is A-1?"},
+			{Role: "assistant", ToolCalls: []*entity.ToolCall{{ID: "call-1", Name: "order_status", Arguments: map[string]interface{}{"order_id": "A-1"}}}},
+			{Role: "tool", Content: `{"status":"shipped"}`, ToolCallID: "call-1"},
+		},
+		Tools:      []*entity.Tool{{Name: "order_status", Description: "Order status", Parameters: map[string]interface{}{"type": "object"}}},
+		ToolChoice: "none",
+	})
+
+	require.NoError(t, err)
+	require.Len(t, resp.ToolCalls, 1)
+	assert.Equal(t, "call-2", resp.ToolCalls[0].ID)
+	assert.Equal(t, "B-2", resp.ToolCalls[0].Arguments["order_id"])
+	assert.Equal(t, "tool_calls", resp.FinishReason)
+}
@@ -0,0 +1,139 @@
+package custom
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/msgfy/linktor/pkg/plugin"
+)
+
+// Adapter implements custom channels: outbound messages are delivered to a
+// tenant-configured HTTP endpoint and inbound messages arrive on the generic
+// webhook, both shaped by the channel's templates and mappings.
+type Adapter struct {
+	*plugin.BaseAdapter
+
+	mu     sync.RWMutex
+	client *Client
+	config *Config
+}
+
+// NewAdapter creates a new custom channel adapter
+func NewAdapter() *Adapter {
+	info := &plugin.ChannelInfo{
+		Type:        plugin.ChannelTypeCustom,
+		Name:        "Custom Channel",
+		Description: "Integration with proprietary messaging systems over HTTP",
+		Version:     "1.0.0",
+		Author:      "Linktor Team",
+		Capabilities: &plugin.ChannelCapabilities{
+			SupportedContentTypes: []plugin.ContentType{
+				plugin.ContentTypeText,
+				plugin.ContentTypeImage,
+				plugin.ContentTypeVideo,
+				plugin.ContentTypeAudio,
+				plugin.ContentTypeDocument,
+				plugin.ContentTypeInteractive,
+			},
+			SupportsMedia:           true,
+			SupportsLocation:        false,
+			SupportsTemplates:       false,
+			SupportsInteractive:     false,
+			SupportsReadReceipts:    false,
+			SupportsTypingIndicator: false,
+			SupportsReactions:       false,
+			SupportsReplies:         false,
+			SupportsForwarding:      false,
+			MaxMessageLength:        MaxMessageLength,
+			MaxAttachments:          10,
+		},
+	}
+
+	return &Adapter{
+		BaseAdapter: plugin.NewBaseAdapter(plugin.ChannelTypeCustom, info),
+		config:      &Config{},
+	}
+}
+
+// Initialize configures the adapter from the channel's config and credentials
+func (a *Adapter) Initialize(config map[string]string) error {
+	if err := a.BaseAdapter.Initialize(config); err != nil {
+		return err
+	}
+
+	parsed, err := ParseConfig(config)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	a.config = parsed
+	a.mu.Unlock()
+	return nil
+}
+
+// Connect prepares the HTTP client. Endpoints are not probed since they only accept messages.
+func (a *Adapter) Connect(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.client = NewClient(a.config)
+	a.SetConnected(true)
+	return nil
+}
+
+// Disconnect releases the HTTP client
+func (a *Adapter) Disconnect(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.client = nil
+	a.SetConnected(false)
+	return nil
+}
+
+// SendMessage delivers a message to the custom endpoint
+func (a *Adapter) SendMessage(ctx context.Context, msg *plugin.OutboundMessage) (*plugin.SendResult, error) {
+	a.mu.RLock()
+	client := a.client
+	a.mu.RUnlock()
+
+	if client == nil {
+		return &plugin.SendResult{
+			Success:   false,
+			Status:    plugin.MessageStatusFailed,
+			Error:     "adapter not connected",
+			Timestamp: time.Now(),
+		}, nil
+	}
+
+	resp, err := client.Send(ctx, msg)
+	if err != nil {
+		return &plugin.SendResult{
+			Success:   false,
+			Status:    plugin.MessageStatusFailed,
+			Error:     err.Error(),
+			Timestamp: time.Now(),
+		}, nil
+	}
+
+	return &plugin.SendResult{
+		Success:    true,
+		ExternalID: resp.ExternalID,
+		Status:     plugin.MessageStatusSent,
+		Timestamp:  time.Now(),
+	}, nil
+}
+
+// GetConfig returns the parsed channel configuration
+func (a *Adapter) GetConfig() *Config {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.config
+}
+
+// GetWebhookPath returns the webhook path for this adapter
+func (a *Adapter) GetWebhookPath() string {
+	return "/webhooks/generic"
+}
@@ -0,0 +1,121 @@
+package custom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/msgfy/linktor/pkg/plugin"
+)
+
+// maxResponseSize caps how much of an endpoint response is read
+const maxResponseSize = 1 << 20
+
+// SendResponse is the outcome of a delivery read with the response mapping
+type SendResponse struct {
+	ExternalID string
+	StatusCode int
+}
+
+// Client delivers outbound messages to a custom channel's HTTP endpoint
+type Client struct {
+	config     *Config
+	httpClient *http.Client
+}
+
+// NewClient creates a new custom channel client
+func NewClient(config *Config) *Client {
+	return &Client{
+		config:     config,
+		httpClient: httpClient,
+	}
+}
+
+// Send renders an outbound message with the payload template, sends it with the
+// configured auth and reads the external message ID from the response
+func (c *Client) Send(ctx context.Context, msg *plugin.OutboundMessage) (*SendResponse, error) {
+	body, err := c.config.RenderPayload(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, c.config.OutboundMethod, c.config.OutboundURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", c.config.RequestContentType)
+	for key, value := range c.config.Headers {
+		req.Header.Set(key, value)
+	}
+	c.authenticate(req, body)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("endpoint returned %d: %s", resp.StatusCode, truncate(string(respBody), 200))
+	}
+
+	return c.readResponse(resp.StatusCode, respBody)
+}
+
+// authenticate adds the configured credentials to a request
+func (c *Client) authenticate(req *http.Request, body []byte) {
+	switch c.config.AuthType {
+	case AuthBearer:
+		req.Header.Set("Authorization", "Bearer "+c.config.AuthToken)
+	case AuthBasic:
+		req.SetBasicAuth(c.config.AuthUsername, c.config.AuthPassword)
+	case AuthHMAC:
+		req.Header.Set(SignatureHeader, Sign(body, c.config.HMACSecret))
+	}
+}
+
+// readResponse applies the response mapping to a successful HTTP response. Endpoints
+// answering without a JSON body succeed without an external ID.
+func (c *Client) readResponse(statusCode int, body []byte) (*SendResponse, error) {
+	result := &SendResponse{StatusCode: statusCode}
+
+	var data interface{}
+	if len(bytes.TrimSpace(body)) == 0 || json.Unmarshal(body, &data) != nil {
+		if c.config.ResponseSuccessValue != "" {
+			return nil, fmt.Errorf("endpoint response has no %s", c.config.ResponseStatusPath)
+		}
+		return result, nil
+	}
+
+	if message := LookupString(data, c.config.ResponseErrorPath); message != "" {
+		return nil, fmt.Errorf("endpoint reported an error: %s", message)
+	}
+	if c.config.ResponseSuccessValue != "" {
+		status := LookupString(data, c.config.ResponseStatusPath)
+		if !strings.EqualFold(status, c.config.ResponseSuccessValue) {
+			return nil, fmt.Errorf("endpoint reported status %q", status)
+		}
+	}
+
+	result.ExternalID = LookupString(data, c.config.ResponseIDPath)
+	return result, nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,186 @@
+package custom
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/msgfy/linktor/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// allowLoopback lets the adapter reach the test servers, which listen on loopback
+func allowLoopback(t *testing.T) {
+	t.Helper()
+	check, client := checkOutboundURL, httpClient
+	checkOutboundURL = func(ctx context.Context, raw string) error { return nil }
+	httpClient = http.DefaultClient
+	t.Cleanup(func() { checkOutboundURL, httpClient = check, client })
+}
+
+func newTestAdapter(t *testing.T, server *httptest.Server, values map[string]string) *Adapter {
+	t.Helper()
+	allowLoopback(t)
+	config := map[string]string{"outbound_url": server.URL + "/send"}
+	for k, v := range values {
+		config[k] = v
+	}
+	adapter := NewAdapter()
+	require.NoError(t, adapter.Initialize(config))
+	require.NoError(t, adapter.Connect(context.Background()))
+	return adapter
+}
+
+func TestNewAdapter_ChannelInfo(t *testing.T) {
+	adapter := NewAdapter()
+	assert.Equal(t, plugin.ChannelTypeCustom, adapter.GetChannelType())
+	assert.Equal(t, "/webhooks/generic", adapter.GetWebhookPath())
+
+	info := adapter.GetChannelInfo()
+	require.NotNil(t, info)
+	assert.Equal(t, MaxMessageLength, info.Capabilities.MaxMessageLength)
+}
+
+func TestSendMessage_BearerAndHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/send", r.URL.Path)
+		assert.Equal(t, "Bearer secret-token", r.Header.Get("Authorization"))
+		assert.Equal(t, "acme", r.Header.Get("X-Tenant"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		body, _ := io.ReadAll(r.Body)
+		assert.JSONEq(t, `{"to": "user-42", "text": "Hello \"world\""}`, string(body))
+
+		w.Write([]byte(`{"data": {"id": "ext-77"}}`))
+	}))
+	defer server.Close()
+
+	adapter := newTestAdapter(t, server, map[string]string{
+		"outbound_method":  "PUT",
+		"auth_type":        "bearer",
+		"auth_token":       "secret-token",
+		"headers":          `{"X-Tenant": "acme"}`,
+		"payload_template": `{"to": {{json .RecipientID}}, "text": {{json .Content}}}`,
+		"response_id_path": "data.id",
+	})
+
+	result, err := adapter.SendMessage(context.Background(), testMessage())
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, "ext-77", result.ExternalID)
+	assert.Equal(t, plugin.MessageStatusSent, result.Status)
+}
+
+func TestSendMessage_BasicAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "linktor", username)
+		assert.Equal(t, "pass", password)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	adapter := newTestAdapter(t, server, map[string]string{
+		"auth_type":     "basic",
+		"auth_username": "linktor",
+		"auth_password": "pass",
+	})
+
+	result, err := adapter.SendMessage(context.Background(), testMessage())
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Empty(t, result.ExternalID)
+}
+
+func TestSendMessage_HMAC(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, Sign(body, "hmac-secret"), r.Header.Get(SignatureHeader))
+		w.Write([]byte(`{"message_id": "ext-1"}`))
+	}))
+	defer server.Close()
+
+	adapter := newTestAdapter(t, server, map[string]string{
+		"auth_type":   "hmac",
+		"hmac_secret": "hmac-secret",
+	})
+
+	result, err := adapter.SendMessage(context.Background(), testMessage())
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, "ext-1", result.ExternalID)
+}
+
+func TestSendMessage_ResponseMapping(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		response string
+		success  bool
+	}{
+		{"success value", http.StatusOK, `{"result": "OK", "message_id": "ext-1"}`, true},
+		{"wrong status value", http.StatusOK, `{"result": "queued_failed"}`, false},
+		{"error path set", http.StatusOK, `{"result": "ok", "error": {"message": "recipient blocked"}}`, false},
+		{"non-JSON body", http.StatusOK, `accepted`, false},
+		{"http error", http.StatusBadGateway, `{"result": "ok"}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			adapter := newTestAdapter(t, server, map[string]string{
+				"response_status_path":   "result",
+				"response_success_value": "ok",
+				"response_error_path":    "error.message",
+			})
+
+			result, err := adapter.SendMessage(context.Background(), testMessage())
+			require.NoError(t, err)
+			assert.Equal(t, tt.success, result.Success)
+			if !tt.success {
+				assert.Equal(t, plugin.MessageStatusFailed, result.Status)
+				assert.NotEmpty(t, result.Error)
+			}
+		})
+	}
+}
+
+func TestSendMessage_RefusesInternalAddressesWhenDialing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the request must not reach the server")
+	}))
+	defer server.Close()
+
+	// The URL passed validation, e.g. before its name was pointed at an internal address
+	check := checkOutboundURL
+	checkOutboundURL = func(ctx context.Context, raw string) error { return nil }
+	defer func() { checkOutboundURL = check }()
+
+	adapter := NewAdapter()
+	require.NoError(t, adapter.Initialize(map[string]string{"outbound_url": server.URL + "/send"}))
+	require.NoError(t, adapter.Connect(context.Background()))
+
+	result, err := adapter.SendMessage(context.Background(), testMessage())
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	assert.Contains(t, result.Error, "not allowed")
+}
+
+func TestSendMessage_NotConnected(t *testing.T) {
+	adapter := NewAdapter()
+	require.NoError(t, adapter.Initialize(map[string]string{"outbound_url": "https://example.com"}))
+
+	result, err := adapter.SendMessage(context.Background(), testMessage())
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+}
@@ -0,0 +1,129 @@
+package custom
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LookupPath returns the value at a dotted path of decoded JSON. Path segments are
+// object keys or array indexes: "data.messages.0.id".
+func LookupPath(data interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return nil, false
+	}
+	current := data
+	for _, segment := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			value, ok := node[segment]
+			if !ok {
+				return nil, false
+			}
+			current = value
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return nil, false
+			}
+			current = node[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// LookupString returns the value at a dotted path as a string. Numbers and booleans
+// are formatted; objects and arrays are returned as JSON.
+func LookupString(data interface{}, path string) string {
+	value, ok := LookupPath(data, path)
+	if !ok || value == nil {
+		return ""
+	}
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(encoded)
+	}
+}
+
+// InboundPayload is an inbound message read from a custom webhook payload
+type InboundPayload struct {
+	MessageID   string
+	SenderID    string
+	SenderName  string
+	ContentType string
+	Content     string
+	MediaURL    string
+	Metadata    map[string]string
+}
+
+// ParseInbound reads an inbound webhook payload with the channel's inbound mapping
+func (c *Config) ParseInbound(body []byte) (*InboundPayload, error) {
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPayload, err)
+	}
+	if _, ok := data.(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("%w: expected a JSON object", ErrInvalidPayload)
+	}
+
+	mapping := c.InboundMapping
+	payload := &InboundPayload{
+		MessageID:   LookupString(data, mapping.MessageIDPath),
+		SenderID:    LookupString(data, mapping.SenderIDPath),
+		SenderName:  LookupString(data, mapping.SenderNamePath),
+		ContentType: LookupString(data, mapping.ContentTypePath),
+		Content:     LookupString(data, mapping.ContentPath),
+		MediaURL:    LookupString(data, mapping.MediaURLPath),
+		Metadata:    make(map[string]string),
+	}
+	if payload.ContentType == "" {
+		payload.ContentType = "text"
+		if payload.MediaURL != "" {
+			payload.ContentType = "document"
+		}
+	}
+
+	if metadata, ok := LookupPath(data, mapping.MetadataPath); ok {
+		if fields, ok := metadata.(map[string]interface{}); ok {
+			for key := range fields {
+				payload.Metadata[key] = LookupString(fields, key)
+			}
+		}
+	}
+	return payload, nil
+}
+
+// Sign returns the signature of a body: "sha256=<hex HMAC-SHA256>"
+func Sign(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks the signature of an inbound webhook. Channels without an
+// inbound secret accept every request.
+func (c *Config) VerifySignature(body []byte, signature string) error {
+	if c.InboundSecret == "" {
+		return nil
+	}
+	if signature == "" || !hmac.Equal([]byte(signature), []byte(Sign(body, c.InboundSecret))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package custom
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupPath(t *testing.T) {
+	var data interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"data": {"messages": [{"id": "m-1", "seq": 42, "ok": true}]},
+		"name": "x"
+	}`), &data))
+
+	assert.Equal(t, "m-1", LookupString(data, "data.messages.0.id"))
+	assert.Equal(t, "42", LookupString(data, "data.messages.0.seq"))
+	assert.Equal(t, "true", LookupString(data, "data.messages.0.ok"))
+	assert.Equal(t, "x", LookupString(data, "name"))
+	assert.Equal(t, "", LookupString(data, "data.messages.1.id"))
+	assert.Equal(t, "", LookupString(data, "data.missing"))
+	assert.Equal(t, "", LookupString(data, "name.nested"))
+	assert.Equal(t, "", LookupString(data, ""))
+	assert.JSONEq(t, `{"id": "m-1", "seq": 42, "ok": true}`, LookupString(data, "data.messages.0"))
+}
+
+func TestParseInbound_DefaultMapping(t *testing.T) {
+	config := InboundConfig(nil)
+
+	payload, err := config.ParseInbound([]byte(`{
+		"message_id": "ext-1",
+		"sender_id": "user-42",
+		"sender_name": "Alice",
+		"content": "Hello",
+		"metadata": {"source": "api", "attempt": 2}
+	}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "ext-1", payload.MessageID)
+	assert.Equal(t, "user-42", payload.SenderID)
+	assert.Equal(t, "Alice", payload.SenderName)
+	assert.Equal(t, "text", payload.ContentType)
+	assert.Equal(t, "Hello", payload.Content)
+	assert.Equal(t, map[string]string{"source": "api", "attempt": "2"}, payload.Metadata)
+}
+
+func TestParseInbound_CustomMapping(t *testing.T) {
+	config := InboundConfig(map[string]string{
+		"inbound_message_id_path":  "event.id",
+		"inbound_sender_id_path":   "event.from.phone",
+		"inbound_sender_name_path": "event.from.name",
+		"inbound_content_path":     "event.text",
+		"inbound_media_url_path":   "event.files.0.url",
+	})
+
+	payload, err := config.ParseInbound([]byte(`{
+		"event": {
+			"id": 991,
+			"from": {"phone": "5511999998888", "name": "Bob"},
+			"text": "see attached",
+			"files": [{"url": "https://files.example.com/a.pdf"}]
+		}
+	}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "991", payload.MessageID)
+	assert.Equal(t, "5511999998888", payload.SenderID)
+	assert.Equal(t, "Bob", payload.SenderName)
+	assert.Equal(t, "see attached", payload.Content)
+	assert.Equal(t, "https://files.example.com/a.pdf", payload.MediaURL)
+	assert.Equal(t, "document", payload.ContentType)
+	assert.Empty(t, payload.Metadata)
+}
+
+func TestParseInbound_Invalid(t *testing.T) {
+	config := InboundConfig(nil)
+
+	_, err := config.ParseInbound([]byte(`{bad`))
+	assert.True(t, errors.Is(err, ErrInvalidPayload))
+
+	_, err = config.ParseInbound([]byte(`["not", "an", "object"]`))
+	assert.True(t, errors.Is(err, ErrInvalidPayload))
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"content": "hi"}`)
+
+	open := InboundConfig(nil)
+	assert.NoError(t, open.VerifySignature(body, ""))
+
+	config := InboundConfig(map[string]string{"inbound_secret": "shh"})
+	assert.NoError(t, config.VerifySignature(body, Sign(body, "shh")))
+	assert.ErrorIs(t, config.VerifySignature(body, ""), ErrInvalidSignature)
+	assert.ErrorIs(t, config.VerifySignature(body, Sign(body, "other")), ErrInvalidSignature)
+	assert.ErrorIs(t, config.VerifySignature([]byte(`{}`), Sign(body, "shh")), ErrInvalidSignature)
+}
@@ -0,0 +1,104 @@
+package custom
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/msgfy/linktor/pkg/plugin"
+)
+
+// PayloadData is what payload templates render, e.g.
+//
+//	{"to": {{json .RecipientID}}, "text": {{json .Content}}, "ref": {{json .MessageID}}}
+type PayloadData struct {
+	MessageID      string            `json:"message_id"`
+	ConversationID string            `json:"conversation_id"`
+	RecipientID    string            `json:"recipient_id"`
+	ContentType    string            `json:"content_type"`
+	Content        string            `json:"content"`
+	MediaURL       string            `json:"media_url,omitempty"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+	Attachments    []PayloadMedia    `json:"attachments,omitempty"`
+	Timestamp      string            `json:"timestamp"`
+}
+
+// PayloadMedia is an attachment of an outbound message
+type PayloadMedia struct {
+	Type     string `json:"type"`
+	URL      string `json:"url"`
+	Filename string `json:"filename,omitempty"`
+	MimeType string `json:"mime_type,omitempty"`
+}
+
+// templateFuncs are the functions available to payload templates
+var templateFuncs = template.FuncMap{
+	// json encodes a value as a JSON literal, quoting and escaping strings
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	// default returns the fallback when the value is empty
+	"default": func(fallback, value string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+func parsePayloadTemplate(text string) (*template.Template, error) {
+	return template.New("payload").Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+}
+
+// NewPayloadData collects the fields of an outbound message templates can use
+func NewPayloadData(msg *plugin.OutboundMessage) *PayloadData {
+	data := &PayloadData{
+		MessageID:      msg.ID,
+		ConversationID: msg.ConversationID,
+		RecipientID:    msg.RecipientID,
+		ContentType:    string(msg.ContentType),
+		Content:        msg.Content,
+		Metadata:       msg.Metadata,
+		Timestamp:      time.Now().UTC().Format(time.RFC3339),
+	}
+	if data.Metadata == nil {
+		data.Metadata = make(map[string]string)
+	}
+	for _, attachment := range msg.Attachments {
+		data.Attachments = append(data.Attachments, PayloadMedia{
+			Type:     attachment.Type,
+			URL:      attachment.URL,
+			Filename: attachment.Filename,
+			MimeType: attachment.MimeType,
+		})
+	}
+	data.MediaURL = data.Metadata["media_url"]
+	if data.MediaURL == "" && len(data.Attachments) > 0 {
+		data.MediaURL = data.Attachments[0].URL
+	}
+	return data
+}
+
+// RenderPayload builds the request body of an outbound message with the channel's
+// payload template, or as the JSON of the payload data when there is none
+func (c *Config) RenderPayload(msg *plugin.OutboundMessage) ([]byte, error) {
+	data := NewPayloadData(msg)
+	if c.template == nil {
+		return json.Marshal(data)
+	}
+
+	var buf bytes.Buffer
+	if err := c.template.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render payload template: %w", err)
+	}
+	if strings.Contains(c.RequestContentType, "json") && !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("payload template rendered invalid JSON")
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,98 @@
+package custom
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/msgfy/linktor/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testMessage() *plugin.OutboundMessage {
+	return &plugin.OutboundMessage{
+		ID:             "msg-1",
+		ConversationID: "conv-1",
+		RecipientID:    "user-42",
+		ContentType:    plugin.ContentTypeText,
+		Content:        `Hello "world"`,
+		Metadata:       map[string]string{"priority": "high"},
+	}
+}
+
+func TestRenderPayload_Default(t *testing.T) {
+	config, err := ParseConfig(map[string]string{"outbound_url": "https://example.com"})
+	require.NoError(t, err)
+
+	body, err := config.RenderPayload(testMessage())
+	require.NoError(t, err)
+
+	var data PayloadData
+	require.NoError(t, json.Unmarshal(body, &data))
+	assert.Equal(t, "msg-1", data.MessageID)
+	assert.Equal(t, "user-42", data.RecipientID)
+	assert.Equal(t, `Hello "world"`, data.Content)
+	assert.Equal(t, "high", data.Metadata["priority"])
+	assert.NotEmpty(t, data.Timestamp)
+}
+
+func TestRenderPayload_Template(t *testing.T) {
+	config, err := ParseConfig(map[string]string{
+		"outbound_url":     "https://example.com",
+		"payload_template": `{"to": {{json .RecipientID}}, "body": {{json .Content}}, "kind": {{json (upper .ContentType)}}, "priority": {{json (default "normal" .Metadata.priority)}}, "ref": {{json (default "none" .Metadata.ref)}}}`,
+	})
+	require.NoError(t, err)
+
+	body, err := config.RenderPayload(testMessage())
+	require.NoError(t, err)
+
+	var data map[string]string
+	require.NoError(t, json.Unmarshal(body, &data))
+	assert.Equal(t, map[string]string{
+		"to":       "user-42",
+		"body":     `Hello "world"`,
+		"kind":     "TEXT",
+		"priority": "high",
+		"ref":      "none",
+	}, data)
+}
+
+func TestRenderPayload_MediaURL(t *testing.T) {
+	config, err := ParseConfig(map[string]string{
+		"outbound_url":     "https://example.com",
+		"payload_template": `{"media": {{json .MediaURL}}}`,
+	})
+	require.NoError(t, err)
+
+	msg := testMessage()
+	msg.ContentType = plugin.ContentTypeImage
+	msg.Attachments = []*plugin.Attachment{{Type: "image", URL: "https://cdn.example.com/a.png"}}
+
+	body, err := config.RenderPayload(msg)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"media": "https://cdn.example.com/a.png"}`, string(body))
+}
+
+func TestRenderPayload_InvalidJSON(t *testing.T) {
+	config, err := ParseConfig(map[string]string{
+		"outbound_url":     "https://example.com",
+		"payload_template": `{"body": {{.Content}}}`,
+	})
+	require.NoError(t, err)
+
+	_, err = config.RenderPayload(testMessage())
+	assert.Error(t, err)
+}
+
+func TestRenderPayload_NonJSONContentType(t *testing.T) {
+	config, err := ParseConfig(map[string]string{
+		"outbound_url":         "https://example.com",
+		"request_content_type": "application/x-www-form-urlencoded",
+		"payload_template":     `to={{.RecipientID}}&text=hi`,
+	})
+	require.NoError(t, err)
+
+	body, err := config.RenderPayload(testMessage())
+	require.NoError(t, err)
+	assert.Equal(t, "to=user-42&text=hi", string(body))
+}
@@ -0,0 +1,251 @@
+package custom
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/msgfy/linktor/internal/infrastructure/egress"
+)
+
+// Errors
+var (
+	ErrInvalidSignature = errors.New("invalid signature")
+	ErrInvalidPayload   = errors.New("invalid payload")
+)
+
+// Outbound URLs are set by tenants, so they are checked against internal addresses
+// and called through the guarded client. Tests swap both to reach local servers.
+var (
+	checkOutboundURL = egress.CheckURL
+	httpClient       = egress.Client
+)
+
+// Auth types of the outbound endpoint
+const (
+	AuthNone   = "none"
+	AuthBearer = "bearer"
+	AuthBasic  = "basic"
+	AuthHMAC   = "hmac"
+)
+
+const (
+	// SignatureHeader carries "sha256=<hex HMAC of the body>", the same scheme Linktor
+	// signs its own webhooks with
+	SignatureHeader = "X-Linktor-Signature"
+
+	// DefaultTimeout is how long the outbound endpoint has to answer
+	DefaultTimeout = 15 * time.Second
+
+	// MaxMessageLength is the maximum text length sent to a custom endpoint
+	MaxMessageLength = 65536
+)
+
+// Config holds the configuration of a custom channel: the HTTP endpoint outbound
+// messages are delivered to, how requests are authenticated and built, how the
+// endpoint's response is read, and how inbound webhook payloads map to messages.
+// Every field comes from the channel's config and credentials, so proprietary
+// systems are integrated without code changes.
+type Config struct {
+	// OutboundURL receives outbound messages
+	OutboundURL string `json:"outbound_url"`
+
+	// OutboundMethod is POST (default), PUT or PATCH
+	OutboundMethod string `json:"outbound_method,omitempty"`
+
+	// Headers are extra request headers, given as a JSON object
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// AuthType is none, bearer, basic or hmac
+	AuthType     string `json:"auth_type,omitempty"`
+	AuthToken    string `json:"auth_token,omitempty"`
+	AuthUsername string `json:"auth_username,omitempty"`
+	AuthPassword string `json:"auth_password,omitempty"`
+	HMACSecret   string `json:"hmac_secret,omitempty"`
+
+	// PayloadTemplate is a Go text/template rendering the request body; the default
+	// body is the message as JSON
+	PayloadTemplate string `json:"payload_template,omitempty"`
+
+	// RequestContentType is the Content-Type of the request body (default application/json)
+	RequestContentType string `json:"request_content_type,omitempty"`
+
+	// Response mapping: dotted JSON paths ("data.messages.0.id") into the response
+	ResponseIDPath       string `json:"response_id_path,omitempty"`
+	ResponseStatusPath   string `json:"response_status_path,omitempty"`
+	ResponseSuccessValue string `json:"response_success_value,omitempty"` // Status value meaning success
+	ResponseErrorPath    string `json:"response_error_path,omitempty"`    // A non-empty value means failure
+
+	// Timeout is how long the endpoint has to answer
+	Timeout time.Duration `json:"timeout,omitempty"`
+
+	// InboundSecret verifies the signature of inbound webhooks when set
+	InboundSecret string `json:"inbound_secret,omitempty"`
+
+	// Inbound mapping: dotted JSON paths into inbound webhook payloads. Unset paths use
+	// the fields of the standard generic payload.
+	InboundMapping InboundMapping `json:"inbound_mapping"`
+
+	template *template.Template
+}
+
+// InboundMapping locates message fields in inbound webhook payloads
+type InboundMapping struct {
+	MessageIDPath   string `json:"message_id_path"`
+	SenderIDPath    string `json:"sender_id_path"`
+	SenderNamePath  string `json:"sender_name_path"`
+	ContentPath     string `json:"content_path"`
+	ContentTypePath string `json:"content_type_path"`
+	MediaURLPath    string `json:"media_url_path,omitempty"`
+	MetadataPath    string `json:"metadata_path"`
+}
+
+// DefaultInboundMapping reads the standard generic webhook payload
+var DefaultInboundMapping = InboundMapping{
+	MessageIDPath:   "message_id",
+	SenderIDPath:    "sender_id",
+	SenderNamePath:  "sender_name",
+	ContentPath:     "content",
+	ContentTypePath: "content_type",
+	MediaURLPath:    "media_url",
+	MetadataPath:    "metadata",
+}
+
+// ParseConfig builds a configuration from a channel's config and credentials
+func ParseConfig(values map[string]string) (*Config, error) {
+	config := &Config{
+		OutboundURL:          strings.TrimSpace(values["outbound_url"]),
+		OutboundMethod:       strings.ToUpper(strings.TrimSpace(values["outbound_method"])),
+		AuthType:             strings.ToLower(strings.TrimSpace(values["auth_type"])),
+		AuthToken:            values["auth_token"],
+		AuthUsername:         values["auth_username"],
+		AuthPassword:         values["auth_password"],
+		HMACSecret:           values["hmac_secret"],
+		PayloadTemplate:      values["payload_template"],
+		RequestContentType:   values["request_content_type"],
+		ResponseIDPath:       values["response_id_path"],
+		ResponseStatusPath:   values["response_status_path"],
+		ResponseSuccessValue: values["response_success_value"],
+		ResponseErrorPath:    values["response_error_path"],
+		InboundSecret:        values["inbound_secret"],
+		Timeout:              DefaultTimeout,
+		InboundMapping:       parseInboundMapping(values),
+	}
+
+	if config.OutboundMethod == "" {
+		config.OutboundMethod = http.MethodPost
+	}
+	if config.AuthType == "" {
+		config.AuthType = AuthNone
+	}
+	if config.RequestContentType == "" {
+		config.RequestContentType = "application/json"
+	}
+	if config.ResponseIDPath == "" {
+		config.ResponseIDPath = "message_id"
+	}
+
+	if headers := values["headers"]; headers != "" {
+		if err := json.Unmarshal([]byte(headers), &config.Headers); err != nil {
+			return nil, fmt.Errorf("headers must be a JSON object of strings: %w", err)
+		}
+	}
+	if timeout := values["timeout_seconds"]; timeout != "" {
+		seconds, err := strconv.Atoi(timeout)
+		if err != nil || seconds <= 0 || seconds > 120 {
+			return nil, errors.New("timeout_seconds must be between 1 and 120")
+		}
+		config.Timeout = time.Duration(seconds) * time.Second
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// InboundConfig builds the inbound half of a configuration: the webhook secret and
+// payload mapping. The generic webhook uses it for every channel type, so channels
+// without an outbound endpoint still accept the standard payload.
+func InboundConfig(values map[string]string) *Config {
+	return &Config{
+		InboundSecret:  values["inbound_secret"],
+		InboundMapping: parseInboundMapping(values),
+	}
+}
+
+func parseInboundMapping(values map[string]string) InboundMapping {
+	mapping := DefaultInboundMapping
+	for key, path := range map[string]*string{
+		"inbound_message_id_path":   &mapping.MessageIDPath,
+		"inbound_sender_id_path":    &mapping.SenderIDPath,
+		"inbound_sender_name_path":  &mapping.SenderNamePath,
+		"inbound_content_path":      &mapping.ContentPath,
+		"inbound_content_type_path": &mapping.ContentTypePath,
+		"inbound_media_url_path":    &mapping.MediaURLPath,
+		"inbound_metadata_path":     &mapping.MetadataPath,
+	} {
+		if value := strings.TrimSpace(values[key]); value != "" {
+			*path = value
+		}
+	}
+	return mapping
+}
+
+// Validate validates the configuration and compiles the payload template
+func (c *Config) Validate() error {
+	if c.OutboundURL == "" {
+		return errors.New("outbound_url is required")
+	}
+	u, err := url.Parse(c.OutboundURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("outbound_url must be an http or https URL")
+	}
+	if err := checkOutboundURL(context.Background(), c.OutboundURL); err != nil {
+		return errors.New("outbound_url must not point to a private, loopback or link-local address")
+	}
+
+	switch c.OutboundMethod {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+	default:
+		return fmt.Errorf("outbound_method must be POST, PUT or PATCH, got %q", c.OutboundMethod)
+	}
+
+	switch c.AuthType {
+	case AuthNone:
+	case AuthBearer:
+		if c.AuthToken == "" {
+			return errors.New("auth_token is required for bearer auth")
+		}
+	case AuthBasic:
+		if c.AuthUsername == "" {
+			return errors.New("auth_username is required for basic auth")
+		}
+	case AuthHMAC:
+		if c.HMACSecret == "" {
+			return errors.New("hmac_secret is required for hmac auth")
+		}
+	default:
+		return fmt.Errorf("auth_type must be none, bearer, basic or hmac, got %q", c.AuthType)
+	}
+
+	if c.ResponseSuccessValue != "" && c.ResponseStatusPath == "" {
+		return errors.New("response_status_path is required with response_success_value")
+	}
+
+	if c.PayloadTemplate != "" {
+		tmpl, err := parsePayloadTemplate(c.PayloadTemplate)
+		if err != nil {
+			return fmt.Errorf("invalid payload_template: %w", err)
+		}
+		c.template = tmpl
+	}
+	return nil
+}
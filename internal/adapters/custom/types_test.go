@@ -0,0 +1,92 @@
+package custom
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig_Defaults(t *testing.T) {
+	config, err := ParseConfig(map[string]string{"outbound_url": "https://example.com/messages"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "POST", config.OutboundMethod)
+	assert.Equal(t, AuthNone, config.AuthType)
+	assert.Equal(t, "application/json", config.RequestContentType)
+	assert.Equal(t, "message_id", config.ResponseIDPath)
+	assert.Equal(t, DefaultTimeout, config.Timeout)
+	assert.Equal(t, DefaultInboundMapping, config.InboundMapping)
+}
+
+func TestParseConfig_AllFields(t *testing.T) {
+	config, err := ParseConfig(map[string]string{
+		"outbound_url":           "https://example.com/messages",
+		"outbound_method":        "put",
+		"auth_type":              "Bearer",
+		"auth_token":             "secret-token",
+		"headers":                `{"X-Tenant": "acme"}`,
+		"timeout_seconds":        "30",
+		"payload_template":       `{"text": {{json .Content}}}`,
+		"response_id_path":       "data.id",
+		"response_status_path":   "result",
+		"response_success_value": "ok",
+		"inbound_content_path":   "message.body",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "PUT", config.OutboundMethod)
+	assert.Equal(t, AuthBearer, config.AuthType)
+	assert.Equal(t, map[string]string{"X-Tenant": "acme"}, config.Headers)
+	assert.Equal(t, 30*time.Second, config.Timeout)
+	assert.Equal(t, "data.id", config.ResponseIDPath)
+	assert.Equal(t, "message.body", config.InboundMapping.ContentPath)
+	assert.Equal(t, "sender_id", config.InboundMapping.SenderIDPath)
+	assert.NotNil(t, config.template)
+}
+
+func TestParseConfig_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[string]string
+	}{
+		{"missing url", map[string]string{}},
+		{"bad scheme", map[string]string{"outbound_url": "ftp://example.com"}},
+		{"metadata service", map[string]string{"outbound_url": "http://169.254.169.254/latest/meta-data/"}},
+		{"loopback", map[string]string{"outbound_url": "http://127.0.0.1:8080/send"}},
+		{"private network", map[string]string{"outbound_url": "https://10.0.0.7/send"}},
+		{"localhost", map[string]string{"outbound_url": "http://localhost/send"}},
+		{"bad method", map[string]string{"outbound_url": "https://example.com", "outbound_method": "GET"}},
+		{"unknown auth", map[string]string{"outbound_url": "https://example.com", "auth_type": "oauth"}},
+		{"bearer without token", map[string]string{"outbound_url": "https://example.com", "auth_type": "bearer"}},
+		{"basic without username", map[string]string{"outbound_url": "https://example.com", "auth_type": "basic"}},
+		{"hmac without secret", map[string]string{"outbound_url": "https://example.com", "auth_type": "hmac"}},
+		{"bad headers", map[string]string{"outbound_url": "https://example.com", "headers": "X-Tenant: acme"}},
+		{"bad timeout", map[string]string{"outbound_url": "https://example.com", "timeout_seconds": "0"}},
+		{"bad template", map[string]string{"outbound_url": "https://example.com", "payload_template": "{{.Content"}},
+		{"success value without path", map[string]string{"outbound_url": "https://example.com", "response_success_value": "ok"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConfig(tt.values)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestInboundConfig(t *testing.T) {
+	config := InboundConfig(map[string]string{
+		"inbound_secret":          "shh",
+		"inbound_sender_id_path":  "from.id",
+		"inbound_metadata_path":   " ",
+		"outbound_url":            "",
+		"inbound_message_id_path": "id",
+	})
+
+	assert.Equal(t, "shh", config.InboundSecret)
+	assert.Equal(t, "from.id", config.InboundMapping.SenderIDPath)
+	assert.Equal(t, "id", config.InboundMapping.MessageIDPath)
+	assert.Equal(t, "metadata", config.InboundMapping.MetadataPath)
+}
@@ -123,6 +123,9 @@ func (a *Adapter) Initialize(config map[string]string) error {
 	if interval := config["imap_poll_interval"]; interval != "" {
 		fmt.Sscanf(interval, "%d", &a.config.IMAPPollInterval)
 	}
+	if connections := config["max_connections"]; connections != "" {
+		fmt.Sscanf(connections, "%d", &a.config.MaxConnections)
+	}
 
 	return nil
 }
@@ -180,6 +183,9 @@ func (a *Adapter) Disconnect(ctx context.Context) error {
 		a.imapClient = nil
 	}
 
+	if a.client != nil {
+		a.client.Close()
+	}
 	a.client = nil
 	a.SetConnected(false)
 	return nil
@@ -3,6 +3,8 @@ package email
 import (
 	"context"
 	"fmt"
+	"io"
+	"time"
 )
 
 // EmailProvider defines the interface for email providers
@@ -20,10 +22,12 @@ type EmailProvider interface {
 	TestConnection(ctx context.Context) error
 }
 
-// Client wraps an email provider with common functionality
+// Client wraps an email provider with common functionality. It sends at most
+// Config.SendConcurrency emails at once.
 type Client struct {
 	provider EmailProvider
 	config   *Config
+	slots    chan struct{}
 }
 
 // NewClient creates a new email client with the appropriate provider
@@ -57,16 +61,32 @@ func NewClient(config *Config) (*Client, error) {
 	return &Client{
 		provider: provider,
 		config:   config,
+		slots:    make(chan struct{}, config.SendConcurrency()),
 	}, nil
 }
 
-// Send sends an email through the configured provider
+// Send sends an email through the configured provider, waiting for a free slot
+// when the provider's concurrency limit is reached
 func (c *Client) Send(ctx context.Context, email *OutboundEmail) (*SendResult, error) {
 	// Set defaults from config
 	if email.ReplyTo == "" && c.config.ReplyTo != "" {
 		email.ReplyTo = c.config.ReplyTo
 	}
 
+	if c.slots != nil {
+		select {
+		case c.slots <- struct{}{}:
+			defer func() { <-c.slots }()
+		case <-ctx.Done():
+			return &SendResult{
+				Success:   false,
+				Error:     "timed out waiting to send: " + ctx.Err().Error(),
+				Temporary: true,
+				Timestamp: time.Now(),
+			}, nil
+		}
+	}
+
 	return c.provider.Send(ctx, email)
 }
 
@@ -98,6 +118,14 @@ func (c *Client) SendWithAttachments(ctx context.Context, to, subject, body stri
 	})
 }
 
+// Close releases the connections the provider keeps open
+func (c *Client) Close() error {
+	if closer, ok := c.provider.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
 // TestConnection tests the connection to the email provider
 func (c *Client) TestConnection(ctx context.Context) error {
 	return c.provider.TestConnection(ctx)
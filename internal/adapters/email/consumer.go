@@ -0,0 +1,173 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/logger"
+	"github.com/msgfy/linktor/pkg/plugin"
+	"go.uber.org/zap"
+)
+
+// ChannelConfigLoader returns the configuration and credentials of an email channel
+type ChannelConfigLoader func(ctx context.Context, channelID string) (map[string]string, error)
+
+// OutboundSender delivers outbound messages of email channels queued on NATS. A
+// connected adapter is kept per channel, and with it its pool of SMTP sessions.
+// Deferred emails stay queued and are retried with a backoff; bounces are reported
+// as failures with the server's reply code so they can be classified.
+type OutboundSender struct {
+	loadConfig ChannelConfigLoader
+	producer   nats.Publisher
+
+	mu       sync.Mutex
+	adapters map[string]*Adapter
+}
+
+// NewOutboundSender creates a new email outbound sender
+func NewOutboundSender(loadConfig ChannelConfigLoader, producer nats.Publisher) *OutboundSender {
+	return &OutboundSender{
+		loadConfig: loadConfig,
+		producer:   producer,
+		adapters:   make(map[string]*Adapter),
+	}
+}
+
+// HandleOutbound sends an outbound email on its given delivery and publishes its
+// delivery status. Returning an error lets NATS redeliver the email, which is done
+// for deferrals until the last delivery.
+func (s *OutboundSender) HandleOutbound(ctx context.Context, msg *nats.OutboundMessage, delivery int) error {
+	adapter, err := s.adapter(ctx, msg.ChannelID)
+	if err != nil {
+		if delivery >= nats.EmailMaxDeliver {
+			s.publishStatus(ctx, msg, "", "failed", "", err.Error())
+			return nil
+		}
+		return err
+	}
+
+	pluginMsg := &plugin.OutboundMessage{
+		ID:             msg.ID,
+		ConversationID: msg.ConversationID,
+		RecipientID:    msg.RecipientID,
+		ContentType:    plugin.ContentType(msg.ContentType),
+		Content:        msg.Content,
+		Metadata:       msg.Metadata,
+	}
+	for _, att := range msg.Attachments {
+		pluginMsg.Attachments = append(pluginMsg.Attachments, &plugin.Attachment{
+			Type:         att.Type,
+			URL:          att.URL,
+			Filename:     att.Filename,
+			MimeType:     att.MimeType,
+			SizeBytes:    att.SizeBytes,
+			ThumbnailURL: att.ThumbnailURL,
+			Metadata:     att.Metadata,
+		})
+	}
+
+	// The adapter may have been disconnected by Forget since it was looked up
+	result := &SendResult{Error: "adapter not connected", Temporary: true}
+	if client := adapter.GetClient(); client != nil {
+		sent, err := client.Send(ctx, adapter.buildOutboundEmail(pluginMsg))
+		if err != nil {
+			sent = &SendResult{Error: err.Error(), Temporary: true}
+		}
+		result = sent
+	}
+	if result.Success {
+		externalID := result.ExternalID
+		if externalID == "" {
+			externalID = result.MessageID
+		}
+		s.publishStatus(ctx, msg, externalID, "sent", "", "")
+		return nil
+	}
+
+	if result.Temporary && delivery < nats.EmailMaxDeliver {
+		logger.Warn("Email deferred, retrying later",
+			zap.String("message_id", msg.ID),
+			zap.Int("delivery", delivery),
+			zap.String("error", result.Error),
+		)
+		return fmt.Errorf("email deferred: %s", result.Error)
+	}
+
+	s.publishStatus(ctx, msg, "", "failed", result.ErrorCode, result.Error)
+	return nil
+}
+
+// Forget disconnects the adapter of a channel so changed credentials are picked up
+func (s *OutboundSender) Forget(channelID string) {
+	s.mu.Lock()
+	adapter, ok := s.adapters[channelID]
+	delete(s.adapters, channelID)
+	s.mu.Unlock()
+
+	if ok {
+		adapter.Disconnect(context.Background())
+	}
+}
+
+// Close disconnects every adapter, ending their pooled SMTP sessions
+func (s *OutboundSender) Close() {
+	s.mu.Lock()
+	adapters := s.adapters
+	s.adapters = make(map[string]*Adapter)
+	s.mu.Unlock()
+
+	for _, adapter := range adapters {
+		adapter.Disconnect(context.Background())
+	}
+}
+
+// adapter returns the connected adapter of a channel, connecting it on first use
+func (s *OutboundSender) adapter(ctx context.Context, channelID string) (*Adapter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if adapter, ok := s.adapters[channelID]; ok {
+		return adapter, nil
+	}
+
+	config, err := s.loadConfig(ctx, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load email channel: %w", err)
+	}
+	adapter := NewAdapter()
+	if err := adapter.Initialize(config); err != nil {
+		return nil, fmt.Errorf("invalid email channel configuration: %w", err)
+	}
+	if err := adapter.Connect(ctx); err != nil {
+		return nil, err
+	}
+
+	s.adapters[channelID] = adapter
+	return adapter, nil
+}
+
+// publishStatus publishes the delivery status of a message
+func (s *OutboundSender) publishStatus(ctx context.Context, msg *nats.OutboundMessage, externalID, status, errorCode, errorMsg string) {
+	if s.producer == nil {
+		return
+	}
+
+	update := &nats.StatusUpdate{
+		MessageID:    msg.ID,
+		ExternalID:   externalID,
+		ChannelType:  msg.ChannelType,
+		Status:       status,
+		ErrorCode:    errorCode,
+		ErrorMessage: errorMsg,
+		Timestamp:    time.Now(),
+	}
+	if err := s.producer.PublishStatusUpdate(ctx, update); err != nil {
+		logger.Error("Failed to publish email status update",
+			zap.String("message_id", msg.ID),
+			zap.Error(err),
+		)
+	}
+}
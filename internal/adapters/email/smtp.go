@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"net"
 	"net/smtp"
 	"strings"
 	"time"
@@ -11,14 +12,18 @@ import (
 	"github.com/google/uuid"
 )
 
-// SMTPProvider implements the EmailProvider interface using SMTP
+// SMTPProvider implements the EmailProvider interface using SMTP. Authenticated
+// sessions are pooled and reused across sends.
 type SMTPProvider struct {
 	config *Config
+	pool   *smtpPool
 }
 
 // NewSMTPProvider creates a new SMTP provider
 func NewSMTPProvider(config *Config) (*SMTPProvider, error) {
-	return &SMTPProvider{config: config}, nil
+	p := &SMTPProvider{config: config}
+	p.pool = newSMTPPool(config.SendConcurrency(), p.dial)
+	return p, nil
 }
 
 // ValidateConfig validates the SMTP configuration
@@ -42,36 +47,51 @@ func (p *SMTPProvider) GetProviderName() string {
 
 // TestConnection tests the SMTP connection
 func (p *SMTPProvider) TestConnection(ctx context.Context) error {
-	addr := fmt.Sprintf("%s:%d", p.config.SMTPHost, p.config.SMTPPort)
+	conn, err := p.dial(ctx)
+	if err != nil {
+		return err
+	}
+	conn.quit()
+	return nil
+}
 
-	var client *smtp.Client
-	var err error
+// Close ends the pooled SMTP sessions
+func (p *SMTPProvider) Close() error {
+	p.pool.close()
+	return nil
+}
 
-	switch p.config.SMTPEncryption {
-	case "tls":
-		conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: p.config.SMTPHost})
-		if err != nil {
-			return fmt.Errorf("failed to connect with TLS: %w", err)
-		}
-		defer conn.Close()
+// dial opens an SMTP session, starting TLS and authenticating as configured
+func (p *SMTPProvider) dial(ctx context.Context) (*smtpConn, error) {
+	addr := fmt.Sprintf("%s:%d", p.config.SMTPHost, p.config.SMTPPort)
+	dialer := &net.Dialer{Timeout: 15 * time.Second}
 
-		client, err = smtp.NewClient(conn, p.config.SMTPHost)
+	var conn net.Conn
+	var err error
+	if p.config.SMTPEncryption == "tls" {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: p.config.SMTPHost}}).DialContext(ctx, "tcp", addr)
 		if err != nil {
-			return fmt.Errorf("failed to create SMTP client: %w", err)
+			return nil, fmt.Errorf("failed to connect with TLS: %w", err)
 		}
-	default:
-		client, err = smtp.Dial(addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
 		if err != nil {
-			return fmt.Errorf("failed to connect to SMTP server: %w", err)
+			return nil, fmt.Errorf("failed to connect to SMTP server: %w", err)
 		}
 	}
+	conn.SetDeadline(time.Now().Add(smtpCommandTimeout))
 
-	defer client.Close()
+	client, err := smtp.NewClient(conn, p.config.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create SMTP client: %w", err)
+	}
 
 	// Start TLS if using STARTTLS
 	if p.config.SMTPEncryption == "starttls" {
 		if err := client.StartTLS(&tls.Config{ServerName: p.config.SMTPHost}); err != nil {
-			return fmt.Errorf("failed to start TLS: %w", err)
+			client.Close()
+			return nil, fmt.Errorf("failed to start TLS: %w", err)
 		}
 	}
 
@@ -79,14 +99,16 @@ func (p *SMTPProvider) TestConnection(ctx context.Context) error {
 	if p.config.SMTPUsername != "" && p.config.SMTPPassword != "" {
 		auth := smtp.PlainAuth("", p.config.SMTPUsername, p.config.SMTPPassword, p.config.SMTPHost)
 		if err := client.Auth(auth); err != nil {
-			return fmt.Errorf("authentication failed: %w", err)
+			client.Close()
+			return nil, fmt.Errorf("authentication failed: %w", err)
 		}
 	}
 
-	return nil
+	return &smtpConn{conn: conn, client: client, lastUsed: time.Now()}, nil
 }
 
-// Send sends an email via SMTP
+// Send sends an email via SMTP. Failures report the server's reply code, and
+// whether the server deferred the message rather than rejecting it.
 func (p *SMTPProvider) Send(ctx context.Context, email *OutboundEmail) (*SendResult, error) {
 	if len(email.To) == 0 {
 		return &SendResult{
@@ -107,28 +129,13 @@ func (p *SMTPProvider) Send(ctx context.Context, email *OutboundEmail) (*SendRes
 	recipients = append(recipients, email.CC...)
 	recipients = append(recipients, email.BCC...)
 
-	// Send email
-	addr := fmt.Sprintf("%s:%d", p.config.SMTPHost, p.config.SMTPPort)
-
-	var auth smtp.Auth
-	if p.config.SMTPUsername != "" && p.config.SMTPPassword != "" {
-		auth = smtp.PlainAuth("", p.config.SMTPUsername, p.config.SMTPPassword, p.config.SMTPHost)
-	}
-
-	var err error
-	switch p.config.SMTPEncryption {
-	case "tls":
-		err = p.sendWithTLS(addr, auth, p.config.FromEmail, recipients, msg)
-	case "starttls":
-		err = p.sendWithSTARTTLS(addr, auth, p.config.FromEmail, recipients, msg)
-	default:
-		err = smtp.SendMail(addr, auth, p.config.FromEmail, recipients, msg)
-	}
-
-	if err != nil {
+	if err := p.pool.send(ctx, p.config.FromEmail, recipients, msg); err != nil {
+		code, temporary := smtpErrorDetails(err)
 		return &SendResult{
 			Success:   false,
 			Error:     err.Error(),
+			ErrorCode: code,
+			Temporary: temporary,
 			Timestamp: time.Now(),
 		}, nil
 	}
@@ -229,98 +236,6 @@ func (p *SMTPProvider) buildMessage(email *OutboundEmail, messageID string) []by
 	return []byte(sb.String())
 }
 
-// sendWithTLS sends email using direct TLS connection
-func (p *SMTPProvider) sendWithTLS(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
-	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: p.config.SMTPHost})
-	if err != nil {
-		return fmt.Errorf("failed to connect with TLS: %w", err)
-	}
-	defer conn.Close()
-
-	client, err := smtp.NewClient(conn, p.config.SMTPHost)
-	if err != nil {
-		return fmt.Errorf("failed to create SMTP client: %w", err)
-	}
-	defer client.Close()
-
-	if auth != nil {
-		if err := client.Auth(auth); err != nil {
-			return fmt.Errorf("authentication failed: %w", err)
-		}
-	}
-
-	if err := client.Mail(from); err != nil {
-		return fmt.Errorf("MAIL command failed: %w", err)
-	}
-
-	for _, recipient := range to {
-		if err := client.Rcpt(recipient); err != nil {
-			return fmt.Errorf("RCPT command failed for %s: %w", recipient, err)
-		}
-	}
-
-	w, err := client.Data()
-	if err != nil {
-		return fmt.Errorf("DATA command failed: %w", err)
-	}
-
-	_, err = w.Write(msg)
-	if err != nil {
-		return fmt.Errorf("failed to write message: %w", err)
-	}
-
-	if err := w.Close(); err != nil {
-		return fmt.Errorf("failed to close message: %w", err)
-	}
-
-	return client.Quit()
-}
-
-// sendWithSTARTTLS sends email using STARTTLS
-func (p *SMTPProvider) sendWithSTARTTLS(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
-	client, err := smtp.Dial(addr)
-	if err != nil {
-		return fmt.Errorf("failed to connect: %w", err)
-	}
-	defer client.Close()
-
-	if err := client.StartTLS(&tls.Config{ServerName: p.config.SMTPHost}); err != nil {
-		return fmt.Errorf("STARTTLS failed: %w", err)
-	}
-
-	if auth != nil {
-		if err := client.Auth(auth); err != nil {
-			return fmt.Errorf("authentication failed: %w", err)
-		}
-	}
-
-	if err := client.Mail(from); err != nil {
-		return fmt.Errorf("MAIL command failed: %w", err)
-	}
-
-	for _, recipient := range to {
-		if err := client.Rcpt(recipient); err != nil {
-			return fmt.Errorf("RCPT command failed for %s: %w", recipient, err)
-		}
-	}
-
-	w, err := client.Data()
-	if err != nil {
-		return fmt.Errorf("DATA command failed: %w", err)
-	}
-
-	_, err = w.Write(msg)
-	if err != nil {
-		return fmt.Errorf("failed to write message: %w", err)
-	}
-
-	if err := w.Close(); err != nil {
-		return fmt.Errorf("failed to close message: %w", err)
-	}
-
-	return client.Quit()
-}
-
 // extractDomain extracts the domain from an email address
 func extractDomain(email string) string {
 	parts := strings.Split(email, "@")
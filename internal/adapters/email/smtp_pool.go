@@ -0,0 +1,183 @@
+package email
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// SMTP connection pool settings
+const (
+	smtpIdleTimeout    = 30 * time.Second // Servers usually drop idle sessions after a minute
+	smtpMaxMessages    = 100              // Messages sent over a session before it is renewed
+	smtpCommandTimeout = 2 * time.Minute  // Deadline of a whole send when ctx has none
+	smtpServiceClosing = 421              // Service not available, the server is closing the session
+)
+
+// smtpConn is an open, authenticated SMTP session
+type smtpConn struct {
+	conn     net.Conn
+	client   *smtp.Client
+	lastUsed time.Time
+	sent     int
+}
+
+// smtpPool keeps authenticated SMTP sessions open between sends so each message
+// doesn't pay for the TCP, TLS and AUTH handshakes. Sessions are renewed after
+// smtpMaxMessages messages and dropped once idle for smtpIdleTimeout.
+type smtpPool struct {
+	dial    func(ctx context.Context) (*smtpConn, error)
+	maxIdle int
+
+	mu     sync.Mutex
+	idle   []*smtpConn
+	closed bool
+}
+
+// newSMTPPool creates a pool keeping up to maxIdle sessions open
+func newSMTPPool(maxIdle int, dial func(ctx context.Context) (*smtpConn, error)) *smtpPool {
+	if maxIdle <= 0 {
+		maxIdle = 1
+	}
+	return &smtpPool{dial: dial, maxIdle: maxIdle}
+}
+
+// send delivers a message over a pooled session. A reused session the server has
+// closed in the meantime is replaced once before giving up.
+func (p *smtpPool) send(ctx context.Context, from string, to []string, msg []byte) error {
+	conn, reused, err := p.get(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = deliverSMTP(ctx, conn, from, to, msg)
+	if err != nil && reused && smtpSessionLost(err) {
+		conn.client.Close()
+		if conn, err = p.dial(ctx); err != nil {
+			return err
+		}
+		err = deliverSMTP(ctx, conn, from, to, msg)
+	}
+
+	p.put(conn, err)
+	return err
+}
+
+// get returns an idle session, or opens a new one
+func (p *smtpPool) get(ctx context.Context) (*smtpConn, bool, error) {
+	p.mu.Lock()
+	for len(p.idle) > 0 {
+		conn := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		if time.Since(conn.lastUsed) < smtpIdleTimeout {
+			p.mu.Unlock()
+			return conn, true, nil
+		}
+		go conn.client.Close()
+	}
+	p.mu.Unlock()
+
+	conn, err := p.dial(ctx)
+	return conn, false, err
+}
+
+// put returns a session to the pool after a send. Sessions stay usable after the
+// server rejects a message, but not after a network failure.
+func (p *smtpPool) put(conn *smtpConn, sendErr error) {
+	if sendErr != nil {
+		if smtpSessionLost(sendErr) || conn.client.Reset() != nil {
+			conn.client.Close()
+			return
+		}
+	} else {
+		conn.sent++
+	}
+	if conn.sent >= smtpMaxMessages {
+		go conn.quit()
+		return
+	}
+
+	conn.lastUsed = time.Now()
+	conn.conn.SetDeadline(time.Time{})
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed || len(p.idle) >= p.maxIdle {
+		go conn.quit()
+		return
+	}
+	p.idle = append(p.idle, conn)
+}
+
+// close ends the idle sessions; sessions in use are ended when returned
+func (p *smtpPool) close() {
+	p.mu.Lock()
+	idle := p.idle
+	p.idle = nil
+	p.closed = true
+	p.mu.Unlock()
+
+	for _, conn := range idle {
+		go conn.quit()
+	}
+}
+
+// quit ends a session politely, without waiting long on an unresponsive server
+func (c *smtpConn) quit() {
+	c.conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if err := c.client.Quit(); err != nil {
+		c.client.Close()
+	}
+}
+
+// deliverSMTP sends a message over a session within the deadline of ctx
+func deliverSMTP(ctx context.Context, conn *smtpConn, from string, to []string, msg []byte) error {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(smtpCommandTimeout)
+	}
+	conn.conn.SetDeadline(deadline)
+
+	if err := conn.client.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range to {
+		if err := conn.client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+
+	w, err := conn.client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// smtpSessionLost reports whether a send failed because the session broke rather
+// than because the server rejected the message
+func smtpSessionLost(err error) bool {
+	var reply *textproto.Error
+	if errors.As(err, &reply) {
+		return reply.Code == smtpServiceClosing
+	}
+	return true
+}
+
+// smtpErrorDetails returns the reply code of a failed send and whether it may
+// succeed later: 4xx replies defer a message, and broken sessions are retried
+func smtpErrorDetails(err error) (string, bool) {
+	var reply *textproto.Error
+	if errors.As(err, &reply) {
+		return strconv.Itoa(reply.Code), reply.Code >= 400 && reply.Code < 500
+	}
+	return "", true
+}
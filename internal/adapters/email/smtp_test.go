@@ -1,7 +1,13 @@
 package email
 
 import (
+	"context"
 	"encoding/base64"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -105,3 +111,164 @@ func TestBase64EncodedLen(t *testing.T) {
 		assert.Equal(t, tt.expected, base64EncodedLen(tt.input))
 	}
 }
+
+// fakeSMTPServer accepts SMTP sessions, refusing recipients listed in rejects with
+// their reply, and counts the sessions opened and messages accepted
+type fakeSMTPServer struct {
+	listener net.Listener
+	rejects  map[string]string
+
+	mu       sync.Mutex
+	sessions int
+	messages int
+}
+
+func newFakeSMTPServer(t *testing.T, rejects map[string]string) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &fakeSMTPServer{listener: listener, rejects: rejects}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			server.mu.Lock()
+			server.sessions++
+			server.mu.Unlock()
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+	text.PrintfLine("220 fake ESMTP")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch {
+		case command == "EHLO" || command == "HELO":
+			text.PrintfLine("250 fake")
+		case command == "RCPT":
+			reply := "250 ok"
+			for address, rejection := range s.rejects {
+				if strings.Contains(line, address) {
+					reply = rejection
+				}
+			}
+			text.PrintfLine("%s", reply)
+		case command == "DATA":
+			text.PrintfLine("354 go ahead")
+			if _, err := text.ReadDotBytes(); err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.messages++
+			s.mu.Unlock()
+			text.PrintfLine("250 queued")
+		case command == "QUIT":
+			text.PrintfLine("221 bye")
+			return
+		default:
+			text.PrintfLine("250 ok")
+		}
+	}
+}
+
+func (s *fakeSMTPServer) provider(t *testing.T) *SMTPProvider {
+	host, port, err := net.SplitHostPort(s.listener.Addr().String())
+	require.NoError(t, err)
+	portNumber, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	provider, err := NewSMTPProvider(&Config{
+		Provider:  ProviderSMTP,
+		FromEmail: "support@example.com",
+		SMTPHost:  host,
+		SMTPPort:  portNumber,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { provider.Close() })
+	return provider
+}
+
+func TestSMTPProvider_Send_ReusesSessions(t *testing.T) {
+	server := newFakeSMTPServer(t, nil)
+	provider := server.provider(t)
+
+	for i := 0; i < 3; i++ {
+		result, err := provider.Send(context.Background(), &OutboundEmail{To: []string{"customer@example.org"}, Subject: "Hi", TextBody: "Hello"})
+		require.NoError(t, err)
+		require.True(t, result.Success, result.Error)
+		assert.NotEmpty(t, result.MessageID)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	assert.Equal(t, 3, server.messages)
+	assert.Equal(t, 1, server.sessions)
+}
+
+func TestSMTPProvider_Send_ClassifiesRejections(t *testing.T) {
+	server := newFakeSMTPServer(t, map[string]string{
+		"greylisted@example.org": "451 4.7.1 Greylisted, try again later",
+		"unknown@example.org":    "550 5.1.1 No such user",
+	})
+	provider := server.provider(t)
+
+	deferred, err := provider.Send(context.Background(), &OutboundEmail{To: []string{"greylisted@example.org"}, Subject: "Hi", TextBody: "Hello"})
+	require.NoError(t, err)
+	assert.False(t, deferred.Success)
+	assert.True(t, deferred.Temporary)
+	assert.Equal(t, "451", deferred.ErrorCode)
+
+	bounced, err := provider.Send(context.Background(), &OutboundEmail{To: []string{"unknown@example.org"}, Subject: "Hi", TextBody: "Hello"})
+	require.NoError(t, err)
+	assert.False(t, bounced.Success)
+	assert.False(t, bounced.Temporary)
+	assert.Equal(t, "550", bounced.ErrorCode)
+	assert.Contains(t, bounced.Error, "5.1.1")
+
+	// The session survives rejections
+	sent, err := provider.Send(context.Background(), &OutboundEmail{To: []string{"customer@example.org"}, Subject: "Hi", TextBody: "Hello"})
+	require.NoError(t, err)
+	assert.True(t, sent.Success)
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	assert.Equal(t, 1, server.sessions)
+}
+
+func TestSMTPProvider_Send_ReplacesClosedSessions(t *testing.T) {
+	server := newFakeSMTPServer(t, nil)
+	provider := server.provider(t)
+	email := &OutboundEmail{To: []string{"customer@example.org"}, Subject: "Hi", TextBody: "Hello"}
+
+	_, err := provider.Send(context.Background(), email)
+	require.NoError(t, err)
+
+	// The server drops the idle session
+	provider.pool.mu.Lock()
+	provider.pool.idle[0].conn.Close()
+	provider.pool.mu.Unlock()
+
+	result, err := provider.Send(context.Background(), email)
+	require.NoError(t, err)
+	assert.True(t, result.Success, result.Error)
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	assert.Equal(t, 2, server.sessions)
+	assert.Equal(t, 2, server.messages)
+}
+
+func TestConfig_SendConcurrency(t *testing.T) {
+	assert.Equal(t, 5, (&Config{Provider: ProviderSMTP}).SendConcurrency())
+	assert.Equal(t, 10, (&Config{Provider: ProviderSES}).SendConcurrency())
+	assert.Equal(t, 2, (&Config{Provider: ProviderSendGrid, MaxConnections: 2}).SendConcurrency())
+}
@@ -56,6 +56,31 @@ type Config struct {
 
 	// Webhook
 	WebhookSecret string `json:"webhook_secret,omitempty"`
+
+	// MaxConnections caps the emails sent at once, and so the SMTP sessions kept
+	// open; the provider default when zero
+	MaxConnections int `json:"max_connections,omitempty"`
+}
+
+// defaultSendConcurrency is how many emails each provider is sent at once by default.
+// SMTP servers commonly refuse more than a few sessions per account.
+var defaultSendConcurrency = map[Provider]int{
+	ProviderSMTP:     5,
+	ProviderSendGrid: 20,
+	ProviderMailgun:  20,
+	ProviderSES:      10,
+	ProviderPostmark: 20,
+}
+
+// SendConcurrency returns how many emails may be sent at once
+func (c *Config) SendConcurrency() int {
+	if c.MaxConnections > 0 {
+		return c.MaxConnections
+	}
+	if n, ok := defaultSendConcurrency[c.Provider]; ok {
+		return n
+	}
+	return 5
 }
 
 // Validate validates the configuration
@@ -164,6 +189,8 @@ type SendResult struct {
 	MessageID  string    `json:"message_id,omitempty"`
 	ExternalID string    `json:"external_id,omitempty"`
 	Error      string    `json:"error,omitempty"`
+	ErrorCode  string    `json:"error_code,omitempty"` // Reply code of the server that refused the email
+	Temporary  bool      `json:"temporary,omitempty"`  // The email was deferred and may go through on a retry
 	Timestamp  time.Time `json:"timestamp"`
 }
 
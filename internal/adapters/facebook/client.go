@@ -150,6 +150,11 @@ func (c *Client) SubscribeToWebhooks(ctx context.Context) error {
 	return c.api.SubscribeToWebhook(ctx, c.config.PageID, fields)
 }
 
+// GetSubscribedApps lists the apps subscribed to the page's webhooks
+func (c *Client) GetSubscribedApps(ctx context.Context) ([]meta.SubscribedApp, error) {
+	return c.api.GetSubscribedApps(ctx, c.config.PageID)
+}
+
 // UnsubscribeFromWebhooks unsubscribes the page from webhook events
 func (c *Client) UnsubscribeFromWebhooks(ctx context.Context) error {
 	return c.api.UnsubscribeFromWebhook(ctx, c.config.PageID)
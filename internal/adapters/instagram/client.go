@@ -102,6 +102,11 @@ func (c *Client) SubscribeToWebhooks(ctx context.Context) error {
 	return c.api.SubscribeToWebhook(ctx, c.config.InstagramID, fields)
 }
 
+// GetSubscribedApps lists the apps subscribed to the account's webhooks
+func (c *Client) GetSubscribedApps(ctx context.Context) ([]meta.SubscribedApp, error) {
+	return c.api.GetSubscribedApps(ctx, c.config.InstagramID)
+}
+
 // UnsubscribeFromWebhooks unsubscribes from webhook events
 func (c *Client) UnsubscribeFromWebhooks(ctx context.Context) error {
 	return c.api.UnsubscribeFromWebhook(ctx, c.config.InstagramID)
@@ -344,6 +344,72 @@ func (c *Client) SubscribeToWebhook(ctx context.Context, pageID string, fields [
 	return nil
 }
 
+// SubscribeWithCallback subscribes a WhatsApp Business Account to the app's webhooks,
+// delivering its events to callbackURL instead of the app's default callback
+func (c *Client) SubscribeWithCallback(ctx context.Context, accountID, callbackURL, verifyToken string) error {
+	endpoint := fmt.Sprintf("%s/subscribed_apps", accountID)
+
+	params := url.Values{}
+	params.Set("override_callback_uri", callbackURL)
+	params.Set("verify_token", verifyToken)
+
+	respBody, err := c.doRequestWithQuery(ctx, http.MethodPost, endpoint, params, nil)
+	if err != nil {
+		return err
+	}
+
+	var resp SubscribedAppsResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if !resp.Success {
+		return fmt.Errorf("failed to subscribe to webhooks")
+	}
+
+	return nil
+}
+
+// GetSubscribedApps lists the apps subscribed to the webhooks of a page or account
+func (c *Client) GetSubscribedApps(ctx context.Context, id string) ([]SubscribedApp, error) {
+	endpoint := fmt.Sprintf("%s/subscribed_apps", id)
+
+	respBody, err := c.doRequestWithQuery(ctx, http.MethodGet, endpoint, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data []SubscribedApp `json:"data"`
+	}
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse subscribed apps: %w", err)
+	}
+
+	return resp.Data, nil
+}
+
+// DebugToken inspects the client's access token, reporting whether it is valid and
+// the permissions it was granted
+func (c *Client) DebugToken(ctx context.Context) (*TokenInfo, error) {
+	params := url.Values{}
+	params.Set("input_token", c.accessToken)
+
+	respBody, err := c.doRequestWithQuery(ctx, http.MethodGet, "debug_token", params, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data TokenInfo `json:"data"`
+	}
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse token info: %w", err)
+	}
+
+	return &resp.Data, nil
+}
+
 // UnsubscribeFromWebhook unsubscribes from webhook events
 func (c *Client) UnsubscribeFromWebhook(ctx context.Context, pageID string) error {
 	endpoint := fmt.Sprintf("%s/subscribed_apps", pageID)
@@ -196,6 +196,21 @@ func TestClient_SubscribeToWebhook(t *testing.T) {
 	})
 }
 
+func TestClient_DebugToken(t *testing.T) {
+	c, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Contains(t, r.URL.Path, "/debug_token")
+		assert.Equal(t, "test-access-token", r.URL.Query().Get("input_token"))
+		w.Write([]byte(`{"data":{"app_id":"app-1","type":"PAGE","is_valid":true,"expires_at":0,"scopes":["pages_messaging","pages_show_list"]}}`))
+	})
+	defer server.Close()
+
+	info, err := c.DebugToken(context.Background())
+	require.NoError(t, err)
+	assert.True(t, info.IsValid)
+	assert.Equal(t, "PAGE", info.Type)
+	assert.Equal(t, []string{"pages_messaging", "pages_show_list"}, info.Scopes)
+}
+
 func TestClient_ExchangeCodeForToken(t *testing.T) {
 	c, server := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
 		assert.Contains(t, r.URL.Path, "/oauth/access_token")
@@ -275,6 +275,27 @@ type SubscribedAppsResponse struct {
 	Error   *APIError `json:"error,omitempty"`
 }
 
+// SubscribedApp represents an app subscribed to the webhooks of a page or account
+type SubscribedApp struct {
+	ID                  string   `json:"id,omitempty"`
+	Name                string   `json:"name,omitempty"`
+	SubscribedFields    []string `json:"subscribed_fields,omitempty"`
+	OverrideCallbackURI string   `json:"override_callback_uri,omitempty"` // WhatsApp Business Accounts only
+	WhatsAppAPIData     *struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"whatsapp_business_api_data,omitempty"`
+}
+
+// TokenInfo is what Meta reports about an access token
+type TokenInfo struct {
+	AppID     string   `json:"app_id,omitempty"`
+	Type      string   `json:"type,omitempty"` // USER, PAGE, SYSTEM_USER...
+	IsValid   bool     `json:"is_valid"`
+	ExpiresAt int64    `json:"expires_at,omitempty"` // Unix time, 0 when it never expires
+	Scopes    []string `json:"scopes,omitempty"`
+}
+
 // SenderAction represents typing indicator or mark seen action
 type SenderAction struct {
 	Recipient    MessageRecipient `json:"recipient"`
@@ -2,9 +2,11 @@ package sms
 
 import (
 	"fmt"
+	"net/http"
 
 	"github.com/twilio/twilio-go"
 	twilioApi "github.com/twilio/twilio-go/rest/api/v2010"
+	twilioMessaging "github.com/twilio/twilio-go/rest/messaging/v1"
 )
 
 // Client wraps the Twilio REST API client
@@ -124,6 +126,90 @@ func (c *Client) GetMessage(messageSID string) (*twilioApi.ApiV2010Message, erro
 	return c.api.Api.FetchMessage(messageSID, params)
 }
 
+// SetIncomingWebhook points the incoming message URL of the messaging service, or of
+// the phone number when there is none, at inboundURL. Status callbacks of the
+// messaging service go to statusCallbackURL when it is set.
+func (c *Client) SetIncomingWebhook(inboundURL, statusCallbackURL string) error {
+	if c.config.MessagingServiceSID != "" {
+		params := &twilioMessaging.UpdateServiceParams{}
+		params.SetInboundRequestUrl(inboundURL)
+		params.SetInboundMethod(http.MethodPost)
+		if statusCallbackURL != "" {
+			params.SetStatusCallback(statusCallbackURL)
+		}
+		if _, err := c.api.MessagingV1.UpdateService(c.config.MessagingServiceSID, params); err != nil {
+			return fmt.Errorf("failed to update messaging service: %w", err)
+		}
+		return nil
+	}
+
+	sid, err := c.incomingPhoneNumberSID()
+	if err != nil {
+		return err
+	}
+	params := &twilioApi.UpdateIncomingPhoneNumberParams{}
+	params.SetPathAccountSid(c.config.AccountSID)
+	params.SetSmsUrl(inboundURL)
+	params.SetSmsMethod(http.MethodPost)
+	if _, err := c.api.Api.UpdateIncomingPhoneNumber(sid, params); err != nil {
+		return fmt.Errorf("failed to update phone number: %w", err)
+	}
+	return nil
+}
+
+// GetIncomingWebhook returns the incoming message URL configured on the messaging
+// service, or on the phone number when there is none
+func (c *Client) GetIncomingWebhook() (string, error) {
+	if c.config.MessagingServiceSID != "" {
+		service, err := c.api.MessagingV1.FetchService(c.config.MessagingServiceSID)
+		if err != nil {
+			return "", fmt.Errorf("failed to fetch messaging service: %w", err)
+		}
+		if service.InboundRequestUrl == nil {
+			return "", nil
+		}
+		return *service.InboundRequestUrl, nil
+	}
+
+	number, err := c.incomingPhoneNumber()
+	if err != nil {
+		return "", err
+	}
+	if number.SmsUrl == nil {
+		return "", nil
+	}
+	return *number.SmsUrl, nil
+}
+
+func (c *Client) incomingPhoneNumberSID() (string, error) {
+	number, err := c.incomingPhoneNumber()
+	if err != nil {
+		return "", err
+	}
+	if number.Sid == nil {
+		return "", fmt.Errorf("phone number %s has no SID", c.config.PhoneNumber)
+	}
+	return *number.Sid, nil
+}
+
+func (c *Client) incomingPhoneNumber() (*twilioApi.ApiV2010IncomingPhoneNumber, error) {
+	if c.config.PhoneNumber == "" {
+		return nil, fmt.Errorf("either phone_number or messaging_service_sid is required")
+	}
+	params := &twilioApi.ListIncomingPhoneNumberParams{}
+	params.SetPathAccountSid(c.config.AccountSID)
+	params.SetPhoneNumber(c.config.PhoneNumber)
+	params.SetLimit(1)
+	numbers, err := c.api.Api.ListIncomingPhoneNumber(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up phone number: %w", err)
+	}
+	if len(numbers) == 0 {
+		return nil, fmt.Errorf("phone number %s not found in the Twilio account", c.config.PhoneNumber)
+	}
+	return &numbers[0], nil
+}
+
 // GetPhoneNumber returns the configured phone number
 func (c *Client) GetPhoneNumber() string {
 	return c.config.PhoneNumber
@@ -0,0 +1,219 @@
+package teams
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/msgfy/linktor/pkg/plugin"
+)
+
+// Adapter implements the Microsoft Teams channel adapter over the Bot Framework
+// REST API. Recipients are Teams conversation IDs, so replies reach personal chats,
+// group chats and channel threads alike.
+type Adapter struct {
+	*plugin.BaseAdapter
+
+	mu     sync.RWMutex
+	client *Client
+	config *Config
+}
+
+// NewAdapter creates a new Teams adapter
+func NewAdapter() *Adapter {
+	info := &plugin.ChannelInfo{
+		Type:        plugin.ChannelTypeTeams,
+		Name:        "Microsoft Teams",
+		Description: "Microsoft Teams bot integration via Bot Framework",
+		Version:     "1.0.0",
+		Author:      "Linktor Team",
+		Capabilities: &plugin.ChannelCapabilities{
+			SupportedContentTypes: []plugin.ContentType{
+				plugin.ContentTypeText,
+				plugin.ContentTypeImage,
+				plugin.ContentTypeVideo,
+				plugin.ContentTypeAudio,
+				plugin.ContentTypeDocument,
+				plugin.ContentTypeInteractive,
+			},
+			SupportsMedia:           true,
+			SupportsLocation:        false,
+			SupportsTemplates:       false,
+			SupportsInteractive:     true, // Adaptive cards
+			SupportsReadReceipts:    false,
+			SupportsTypingIndicator: true,
+			SupportsReactions:       false,
+			SupportsReplies:         true,
+			SupportsForwarding:      false,
+			MaxMessageLength:        MaxMessageLength,
+			MaxMediaSize:            4 * 1024 * 1024, // 4MB inline
+			MaxAttachments:          10,
+			SupportedMediaTypes: []string{
+				"image/jpeg", "image/png", "image/gif",
+				"video/mp4",
+				"audio/mpeg",
+				"application/pdf",
+			},
+		},
+	}
+
+	return &Adapter{
+		BaseAdapter: plugin.NewBaseAdapter(plugin.ChannelTypeTeams, info),
+		config:      &Config{},
+	}
+}
+
+// Initialize configures the adapter with credentials
+func (a *Adapter) Initialize(config map[string]string) error {
+	if err := a.BaseAdapter.Initialize(config); err != nil {
+		return err
+	}
+
+	a.config = &Config{
+		AppID:             config["app_id"],
+		AppPassword:       config["app_password"],
+		AppTenantID:       config["app_tenant_id"],
+		ServiceURL:        config["service_url"],
+		TokenURL:          config["token_url"],
+		OpenIDMetadataURL: config["openid_metadata_url"],
+	}
+
+	return a.config.Validate()
+}
+
+// Connect verifies the bot credentials by requesting a Bot Connector token
+func (a *Adapter) Connect(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	client, err := NewClient(a.config)
+	if err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	if _, err := client.Token(ctx); err != nil {
+		return fmt.Errorf("failed to verify Teams credentials: %w", err)
+	}
+
+	a.client = client
+	a.SetConnected(true)
+	return nil
+}
+
+// Disconnect closes the Teams connection
+func (a *Adapter) Disconnect(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.client = nil
+	a.SetConnected(false)
+	return nil
+}
+
+// SendMessage sends a message to a Teams conversation
+func (a *Adapter) SendMessage(ctx context.Context, msg *plugin.OutboundMessage) (*plugin.SendResult, error) {
+	a.mu.RLock()
+	client := a.client
+	a.mu.RUnlock()
+
+	if client == nil {
+		return &plugin.SendResult{
+			Success:   false,
+			Status:    plugin.MessageStatusFailed,
+			Error:     "adapter not connected",
+			Timestamp: time.Now(),
+		}, nil
+	}
+
+	result, err := client.SendActivity(ctx, msg.Metadata["service_url"], msg.RecipientID, BuildActivity(msg))
+	if err != nil {
+		return &plugin.SendResult{
+			Success:   false,
+			Status:    plugin.MessageStatusFailed,
+			Error:     err.Error(),
+			Timestamp: time.Now(),
+		}, nil
+	}
+
+	return &plugin.SendResult{
+		Success:    true,
+		ExternalID: result.ID,
+		Status:     plugin.MessageStatusSent,
+		Timestamp:  time.Now(),
+	}, nil
+}
+
+// SendTypingIndicator shows the bot typing in a Teams conversation
+func (a *Adapter) SendTypingIndicator(ctx context.Context, indicator *plugin.TypingIndicator) error {
+	if !indicator.IsTyping {
+		return nil // Teams clears the indicator with the next message
+	}
+
+	a.mu.RLock()
+	client := a.client
+	a.mu.RUnlock()
+
+	if client == nil {
+		return fmt.Errorf("adapter not connected")
+	}
+	_, err := client.SendActivity(ctx, "", indicator.RecipientID, &Activity{Type: ActivityTypeTyping})
+	return err
+}
+
+// GetWebhookPath returns the webhook path for this adapter
+func (a *Adapter) GetWebhookPath() string {
+	return "/webhooks/teams"
+}
+
+// BuildActivity converts an outbound message to a Teams activity. Interactive
+// messages are rendered as adaptive cards and fall back to their text when the
+// interactive type has no card equivalent.
+func BuildActivity(msg *plugin.OutboundMessage) *Activity {
+	activity := &Activity{
+		Type:       ActivityTypeMessage,
+		Text:       msg.Content,
+		TextFormat: "markdown",
+	}
+
+	switch msg.ContentType {
+	case plugin.ContentTypeImage, plugin.ContentTypeVideo, plugin.ContentTypeAudio, plugin.ContentTypeDocument:
+		for _, attachment := range msg.Attachments {
+			activity.Attachments = append(activity.Attachments, Attachment{
+				ContentType:  attachment.MimeType,
+				ContentURL:   attachment.URL,
+				Name:         attachment.Filename,
+				ThumbnailURL: attachment.ThumbnailURL,
+			})
+		}
+		if len(activity.Attachments) == 0 && msg.Metadata["media_url"] != "" {
+			activity.Attachments = append(activity.Attachments, Attachment{
+				ContentType: mediaContentType(msg.ContentType),
+				ContentURL:  msg.Metadata["media_url"],
+			})
+		}
+
+	case plugin.ContentTypeInteractive:
+		if interactive := msg.Metadata["interactive"]; interactive != "" {
+			if card, err := CardFromInteractive(interactive, msg.Content); err == nil {
+				activity.Text = ""
+				activity.Attachments = []Attachment{card.Attachment()}
+			}
+		}
+	}
+
+	return activity
+}
+
+// mediaContentType returns a generic MIME type for media sent without attachment details
+func mediaContentType(contentType plugin.ContentType) string {
+	switch contentType {
+	case plugin.ContentTypeImage:
+		return "image/*"
+	case plugin.ContentTypeVideo:
+		return "video/*"
+	case plugin.ContentTypeAudio:
+		return "audio/*"
+	default:
+		return "application/octet-stream"
+	}
+}
@@ -0,0 +1,148 @@
+package teams
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/msgfy/linktor/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ========== NewAdapter ==========
+
+func TestNewAdapter_ChannelInfo(t *testing.T) {
+	adapter := NewAdapter()
+	assert.Equal(t, plugin.ChannelTypeTeams, adapter.GetChannelType())
+
+	info := adapter.GetChannelInfo()
+	require.NotNil(t, info)
+	assert.Equal(t, "Microsoft Teams", info.Name)
+	require.NotNil(t, info.Capabilities)
+	assert.True(t, info.Capabilities.SupportsInteractive)
+	assert.Equal(t, MaxMessageLength, info.Capabilities.MaxMessageLength)
+}
+
+func TestAdapter_GetWebhookPath(t *testing.T) {
+	assert.Equal(t, "/webhooks/teams", NewAdapter().GetWebhookPath())
+}
+
+// ========== Initialize ==========
+
+func TestAdapter_Initialize(t *testing.T) {
+	adapter := NewAdapter()
+	err := adapter.Initialize(map[string]string{"app_id": "app-1", "app_password": "secret"})
+	require.NoError(t, err)
+	assert.Equal(t, "app-1", adapter.config.AppID)
+}
+
+func TestAdapter_Initialize_MissingPassword(t *testing.T) {
+	adapter := NewAdapter()
+	err := adapter.Initialize(map[string]string{"app_id": "app-1"})
+	assert.Error(t, err)
+}
+
+// ========== SendMessage ==========
+
+func TestAdapter_SendMessage_NotConnected(t *testing.T) {
+	adapter := NewAdapter()
+	result, err := adapter.SendMessage(context.Background(), &plugin.OutboundMessage{RecipientID: "conv-1", Content: "hi"})
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+	assert.Equal(t, plugin.MessageStatusFailed, result.Status)
+}
+
+func TestAdapter_SendMessage(t *testing.T) {
+	var received Activity
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "tok", "expires_in": 3600})
+			return
+		}
+		path = r.URL.Path
+		assert.Equal(t, "Bearer tok", r.Header.Get("Authorization"))
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		_ = json.NewEncoder(w).Encode(ResourceResponse{ID: "activity-1"})
+	}))
+	defer server.Close()
+
+	adapter := NewAdapter()
+	require.NoError(t, adapter.Initialize(map[string]string{
+		"app_id":       "app-1",
+		"app_password": "secret",
+		"token_url":    server.URL + "/token",
+		"service_url":  server.URL,
+	}))
+	require.NoError(t, adapter.Connect(context.Background()))
+	assert.True(t, adapter.IsConnected())
+
+	result, err := adapter.SendMessage(context.Background(), &plugin.OutboundMessage{
+		RecipientID: "a:conv-1",
+		ContentType: plugin.ContentTypeText,
+		Content:     "Hello",
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, "activity-1", result.ExternalID)
+	assert.Equal(t, "/v3/conversations/a:conv-1/activities", path)
+	assert.Equal(t, ActivityTypeMessage, received.Type)
+	assert.Equal(t, "Hello", received.Text)
+}
+
+func TestAdapter_Connect_InvalidCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+	}))
+	defer server.Close()
+
+	adapter := NewAdapter()
+	require.NoError(t, adapter.Initialize(map[string]string{
+		"app_id":       "app-1",
+		"app_password": "wrong",
+		"token_url":    server.URL,
+	}))
+	assert.Error(t, adapter.Connect(context.Background()))
+	assert.False(t, adapter.IsConnected())
+}
+
+// ========== BuildActivity ==========
+
+func TestBuildActivity_Media(t *testing.T) {
+	activity := BuildActivity(&plugin.OutboundMessage{
+		ContentType: plugin.ContentTypeImage,
+		Content:     "caption",
+		Attachments: []*plugin.Attachment{{URL: "https://cdn.example.com/a.png", MimeType: "image/png", Filename: "a.png"}},
+	})
+	require.Len(t, activity.Attachments, 1)
+	assert.Equal(t, "image/png", activity.Attachments[0].ContentType)
+	assert.Equal(t, "https://cdn.example.com/a.png", activity.Attachments[0].ContentURL)
+	assert.Equal(t, "caption", activity.Text)
+}
+
+func TestBuildActivity_Interactive(t *testing.T) {
+	activity := BuildActivity(&plugin.OutboundMessage{
+		ContentType: plugin.ContentTypeInteractive,
+		Content:     "Pick one",
+		Metadata: map[string]string{
+			"interactive": `{"type":"button","body":{"text":"Pick one"},"action":{"buttons":[{"type":"reply","reply":{"id":"yes","title":"Yes"}}]}}`,
+		},
+	})
+	require.Len(t, activity.Attachments, 1)
+	assert.Equal(t, ContentTypeAdaptiveCard, activity.Attachments[0].ContentType)
+	assert.Empty(t, activity.Text)
+}
+
+func TestBuildActivity_UnsupportedInteractiveFallsBackToText(t *testing.T) {
+	activity := BuildActivity(&plugin.OutboundMessage{
+		ContentType: plugin.ContentTypeInteractive,
+		Content:     "Our catalog",
+		Metadata:    map[string]string{"interactive": `{"type":"product_list"}`},
+	})
+	assert.Empty(t, activity.Attachments)
+	assert.Equal(t, "Our catalog", activity.Text)
+}
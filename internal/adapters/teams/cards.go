@@ -0,0 +1,194 @@
+package teams
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// ContentTypeAdaptiveCard is the attachment content type of adaptive cards
+	ContentTypeAdaptiveCard = "application/vnd.microsoft.card.adaptive"
+
+	adaptiveCardSchema  = "http://adaptivecards.io/schemas/adaptive-card.json"
+	adaptiveCardVersion = "1.4"
+
+	// listChoiceID is the input a list card submits its selected row under
+	listChoiceID = "list_id"
+)
+
+// AdaptiveCard is an adaptive card rendered in Teams
+type AdaptiveCard struct {
+	Type    string        `json:"type"`
+	Schema  string        `json:"$schema"`
+	Version string        `json:"version"`
+	Body    []CardElement `json:"body,omitempty"`
+	Actions []CardAction  `json:"actions,omitempty"`
+}
+
+// CardElement is a text block, image or input of an adaptive card
+type CardElement struct {
+	Type        string       `json:"type"`
+	Text        string       `json:"text,omitempty"`
+	Wrap        bool         `json:"wrap,omitempty"`
+	Weight      string       `json:"weight,omitempty"`
+	Size        string       `json:"size,omitempty"`
+	IsSubtle    bool         `json:"isSubtle,omitempty"`
+	URL         string       `json:"url,omitempty"`
+	ID          string       `json:"id,omitempty"`
+	Style       string       `json:"style,omitempty"`
+	Placeholder string       `json:"placeholder,omitempty"`
+	Choices     []CardChoice `json:"choices,omitempty"`
+	IsRequired  bool         `json:"isRequired,omitempty"`
+}
+
+// CardChoice is an option of a choice set input
+type CardChoice struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// CardAction is a submit or open URL action of an adaptive card
+type CardAction struct {
+	Type  string                 `json:"type"`
+	Title string                 `json:"title"`
+	URL   string                 `json:"url,omitempty"`
+	Data  map[string]interface{} `json:"data,omitempty"`
+}
+
+// interactiveMessage is the WhatsApp interactive message format Linktor stores in
+// the "interactive" metadata of outbound messages
+type interactiveMessage struct {
+	Type   string `json:"type"`
+	Header *struct {
+		Type  string `json:"type"`
+		Text  string `json:"text"`
+		Image *struct {
+			Link string `json:"link"`
+		} `json:"image"`
+	} `json:"header"`
+	Body *struct {
+		Text string `json:"text"`
+	} `json:"body"`
+	Footer *struct {
+		Text string `json:"text"`
+	} `json:"footer"`
+	Action struct {
+		Button  string `json:"button"`
+		Buttons []struct {
+			Reply struct {
+				ID    string `json:"id"`
+				Title string `json:"title"`
+			} `json:"reply"`
+		} `json:"buttons"`
+		Sections []struct {
+			Title string `json:"title"`
+			Rows  []struct {
+				ID          string `json:"id"`
+				Title       string `json:"title"`
+				Description string `json:"description"`
+			} `json:"rows"`
+		} `json:"sections"`
+		Parameters *struct {
+			DisplayText string `json:"display_text"`
+			URL         string `json:"url"`
+		} `json:"parameters"`
+	} `json:"action"`
+}
+
+// CardFromInteractive renders an interactive message as an adaptive card. Reply
+// buttons become submit actions echoing their title in the chat, lists a choice set,
+// and call-to-action URLs open URL actions. Submissions come back with the button or
+// row ID under the same button_id and list_id keys as WhatsApp replies.
+func CardFromInteractive(interactiveJSON, fallbackText string) (*AdaptiveCard, error) {
+	var msg interactiveMessage
+	if err := json.Unmarshal([]byte(interactiveJSON), &msg); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedCard, err)
+	}
+
+	card := &AdaptiveCard{
+		Type:    "AdaptiveCard",
+		Schema:  adaptiveCardSchema,
+		Version: adaptiveCardVersion,
+	}
+
+	if msg.Header != nil {
+		switch {
+		case msg.Header.Image != nil && msg.Header.Image.Link != "":
+			card.Body = append(card.Body, CardElement{Type: "Image", URL: msg.Header.Image.Link})
+		case msg.Header.Text != "":
+			card.Body = append(card.Body, CardElement{Type: "TextBlock", Text: msg.Header.Text, Weight: "Bolder", Size: "Medium", Wrap: true})
+		}
+	}
+	text := fallbackText
+	if msg.Body != nil && msg.Body.Text != "" {
+		text = msg.Body.Text
+	}
+	if text != "" {
+		card.Body = append(card.Body, CardElement{Type: "TextBlock", Text: text, Wrap: true})
+	}
+
+	switch msg.Type {
+	case "button":
+		for _, button := range msg.Action.Buttons {
+			card.Actions = append(card.Actions, CardAction{
+				Type:  "Action.Submit",
+				Title: button.Reply.Title,
+				Data: map[string]interface{}{
+					"button_id": button.Reply.ID,
+					"title":     button.Reply.Title,
+					// messageBack shows the choice in the chat like a typed reply
+					"msteams": map[string]interface{}{
+						"type":        "messageBack",
+						"displayText": button.Reply.Title,
+						"text":        button.Reply.Title,
+					},
+				},
+			})
+		}
+	case "list":
+		choices := []CardChoice{}
+		for _, section := range msg.Action.Sections {
+			for _, row := range section.Rows {
+				title := row.Title
+				if row.Description != "" {
+					title += " - " + row.Description
+				}
+				choices = append(choices, CardChoice{Title: title, Value: row.ID})
+			}
+		}
+		placeholder := msg.Action.Button
+		if placeholder == "" {
+			placeholder = "Options"
+		}
+		card.Body = append(card.Body, CardElement{
+			Type:        "Input.ChoiceSet",
+			ID:          listChoiceID,
+			Style:       "compact",
+			Placeholder: placeholder,
+			Choices:     choices,
+			IsRequired:  true,
+		})
+		card.Actions = append(card.Actions, CardAction{Type: "Action.Submit", Title: placeholder})
+	case "cta_url":
+		if msg.Action.Parameters == nil || msg.Action.Parameters.URL == "" {
+			return nil, fmt.Errorf("%w: cta_url without url", ErrUnsupportedCard)
+		}
+		card.Actions = append(card.Actions, CardAction{
+			Type:  "Action.OpenUrl",
+			Title: msg.Action.Parameters.DisplayText,
+			URL:   msg.Action.Parameters.URL,
+		})
+	default:
+		return nil, fmt.Errorf("%w: type %q", ErrUnsupportedCard, msg.Type)
+	}
+
+	if msg.Footer != nil && msg.Footer.Text != "" {
+		card.Body = append(card.Body, CardElement{Type: "TextBlock", Text: msg.Footer.Text, IsSubtle: true, Size: "Small", Wrap: true})
+	}
+	return card, nil
+}
+
+// Attachment wraps the card as an activity attachment
+func (c *AdaptiveCard) Attachment() Attachment {
+	return Attachment{ContentType: ContentTypeAdaptiveCard, Content: c}
+}
@@ -0,0 +1,86 @@
+package teams
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCardFromInteractive_Buttons(t *testing.T) {
+	card, err := CardFromInteractive(`{
+		"type": "button",
+		"header": {"type": "text", "text": "Order #42"},
+		"body": {"text": "Confirm your order?"},
+		"footer": {"text": "Reply within 24h"},
+		"action": {"buttons": [
+			{"type": "reply", "reply": {"id": "confirm", "title": "Confirm"}},
+			{"type": "reply", "reply": {"id": "cancel", "title": "Cancel"}}
+		]}
+	}`, "")
+	require.NoError(t, err)
+
+	assert.Equal(t, "AdaptiveCard", card.Type)
+	require.Len(t, card.Body, 3)
+	assert.Equal(t, "Order #42", card.Body[0].Text)
+	assert.Equal(t, "Confirm your order?", card.Body[1].Text)
+	assert.True(t, card.Body[2].IsSubtle)
+
+	require.Len(t, card.Actions, 2)
+	assert.Equal(t, "Action.Submit", card.Actions[0].Type)
+	assert.Equal(t, "Confirm", card.Actions[0].Title)
+	assert.Equal(t, "confirm", card.Actions[0].Data["button_id"])
+}
+
+func TestCardFromInteractive_List(t *testing.T) {
+	card, err := CardFromInteractive(`{
+		"type": "list",
+		"body": {"text": "Choose a plan"},
+		"action": {"button": "Plans", "sections": [
+			{"title": "Monthly", "rows": [
+				{"id": "basic", "title": "Basic", "description": "1 seat"},
+				{"id": "pro", "title": "Pro"}
+			]}
+		]}
+	}`, "")
+	require.NoError(t, err)
+
+	require.Len(t, card.Body, 2)
+	choices := card.Body[1]
+	assert.Equal(t, "Input.ChoiceSet", choices.Type)
+	assert.Equal(t, "list_id", choices.ID)
+	require.Len(t, choices.Choices, 2)
+	assert.Equal(t, CardChoice{Title: "Basic - 1 seat", Value: "basic"}, choices.Choices[0])
+	require.Len(t, card.Actions, 1)
+	assert.Equal(t, "Plans", card.Actions[0].Title)
+}
+
+func TestCardFromInteractive_CTAURL(t *testing.T) {
+	card, err := CardFromInteractive(`{
+		"type": "cta_url",
+		"action": {"name": "cta_url", "parameters": {"display_text": "Track", "url": "https://example.com/track"}}
+	}`, "Your order shipped")
+	require.NoError(t, err)
+
+	require.Len(t, card.Body, 1)
+	assert.Equal(t, "Your order shipped", card.Body[0].Text)
+	require.Len(t, card.Actions, 1)
+	assert.Equal(t, "Action.OpenUrl", card.Actions[0].Type)
+	assert.Equal(t, "https://example.com/track", card.Actions[0].URL)
+}
+
+func TestCardFromInteractive_Unsupported(t *testing.T) {
+	_, err := CardFromInteractive(`{"type":"flow"}`, "")
+	assert.True(t, errors.Is(err, ErrUnsupportedCard))
+
+	_, err = CardFromInteractive(`not json`, "")
+	assert.True(t, errors.Is(err, ErrUnsupportedCard))
+}
+
+func TestAdaptiveCard_Attachment(t *testing.T) {
+	card := &AdaptiveCard{Type: "AdaptiveCard"}
+	attachment := card.Attachment()
+	assert.Equal(t, ContentTypeAdaptiveCard, attachment.ContentType)
+	assert.Same(t, card, attachment.Content)
+}
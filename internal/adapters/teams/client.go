@@ -0,0 +1,285 @@
+package teams
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// tokenRefreshMargin renews bot tokens before they expire
+	tokenRefreshMargin = 5 * time.Minute
+	// signingKeysTTL is how long channel request signing keys are cached
+	signingKeysTTL = 24 * time.Hour
+)
+
+// Client is the Bot Connector API client of a Teams bot
+type Client struct {
+	config     *Config
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	token       string
+	tokenExpiry time.Time
+	keys        map[string]*rsa.PublicKey
+	keysFetched time.Time
+}
+
+// NewClient creates a new Teams client
+func NewClient(config *Config) (*Client, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		config: config,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}, nil
+}
+
+// tokenResponse is the OAuth client credentials response
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+	Error       string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+// Token returns a bot token for Bot Connector, requesting a new one when the cached
+// token is about to expire
+func (c *Client) Token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && time.Until(c.tokenExpiry) > tokenRefreshMargin {
+		return c.token, nil
+	}
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {c.config.AppID},
+		"client_secret": {c.config.AppPassword},
+		"scope":         {tokenScope},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.config.GetTokenURL(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request bot token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var token tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to decode bot token: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || token.AccessToken == "" {
+		return "", fmt.Errorf("%w: %s %s", ErrMissingCredentials, token.Error, token.Description)
+	}
+
+	c.token = token.AccessToken
+	c.tokenExpiry = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	return c.token, nil
+}
+
+// SendActivity sends an activity to a conversation
+func (c *Client) SendActivity(ctx context.Context, serviceURL, conversationID string, activity *Activity) (*ResourceResponse, error) {
+	if serviceURL == "" {
+		serviceURL = c.config.GetServiceURL()
+	}
+	endpoint := strings.TrimSuffix(serviceURL, "/") + "/v3/conversations/" + url.PathEscape(conversationID) + "/activities"
+
+	var result ResourceResponse
+	if err := c.doRequest(ctx, http.MethodPost, endpoint, activity, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// doRequest performs an authenticated Bot Connector request
+func (c *Client) doRequest(ctx context.Context, method, endpoint string, body, result interface{}) error {
+	token, err := c.Token(ctx)
+	if err != nil {
+		return err
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		var errResp ErrorResponse
+		if json.Unmarshal(respBody, &errResp) == nil && errResp.Error.Message != "" {
+			return fmt.Errorf("bot connector error %s: %s", errResp.Error.Code, errResp.Error.Message)
+		}
+		return fmt.Errorf("bot connector error: status %d", resp.StatusCode)
+	}
+
+	if result != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, result); err != nil {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+	return nil
+}
+
+// ValidateRequest verifies the bearer token Bot Framework sends with every activity:
+// it must be signed by a published Bot Framework key, issued for this bot and, when
+// the activity names one, for the activity's service URL
+func (c *Client) ValidateRequest(ctx context.Context, authHeader, serviceURL string) error {
+	raw, ok := strings.CutPrefix(authHeader, "Bearer ")
+	if !ok || raw == "" {
+		return ErrInvalidToken
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return c.signingKey(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256"}),
+		jwt.WithIssuer(tokenIssuer),
+		jwt.WithAudience(c.config.AppID),
+		jwt.WithLeeway(5*time.Minute),
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if serviceURL != "" {
+		if claimed, _ := claims["serviceurl"].(string); claimed != "" && claimed != serviceURL {
+			return fmt.Errorf("%w: service URL mismatch", ErrInvalidToken)
+		}
+	}
+	return nil
+}
+
+// openIDMetadata is the discovery document of Bot Framework
+type openIDMetadata struct {
+	JWKSURI string `json:"jwks_uri"`
+}
+
+// jsonWebKeys is a JSON Web Key Set
+type jsonWebKeys struct {
+	Keys []struct {
+		Kid string `json:"kid"`
+		Kty string `json:"kty"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+// signingKey returns the Bot Framework key with the given ID, refreshing the cached
+// keys when they are stale or the key is unknown
+func (c *Client) signingKey(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if key, ok := c.keys[kid]; ok && time.Since(c.keysFetched) < signingKeysTTL {
+		return key, nil
+	}
+
+	keys, err := c.fetchSigningKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.keys = keys
+	c.keysFetched = time.Now()
+
+	key, ok := keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	return key, nil
+}
+
+// fetchSigningKeys downloads the RSA keys Bot Framework signs channel requests with
+func (c *Client) fetchSigningKeys(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	var metadata openIDMetadata
+	if err := c.getJSON(ctx, c.config.GetOpenIDMetadataURL(), &metadata); err != nil {
+		return nil, fmt.Errorf("failed to load OpenID metadata: %w", err)
+	}
+	var set jsonWebKeys
+	if err := c.getJSON(ctx, metadata.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("failed to load signing keys: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, key := range set.Keys {
+		if key.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			continue
+		}
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			continue
+		}
+		keys[key.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	return keys, nil
+}
+
+// getJSON fetches and decodes an unauthenticated JSON document
+func (c *Client) getJSON(ctx context.Context, endpoint string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
@@ -0,0 +1,134 @@
+package teams
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newKeyServer serves OpenID metadata and a key set holding the public key
+func newKeyServer(t *testing.T, kid string, key *rsa.PublicKey) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/openid":
+			_ = json.NewEncoder(w).Encode(map[string]string{"jwks_uri": server.URL + "/keys"})
+		case "/keys":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"keys": []map[string]string{{
+					"kid": kid,
+					"kty": "RSA",
+					"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+					"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+				}},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func TestClient_ValidateRequest(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	server := newKeyServer(t, "key-1", &key.PublicKey)
+
+	client, err := NewClient(&Config{AppID: "app-1", AppPassword: "secret", OpenIDMetadataURL: server.URL + "/openid"})
+	require.NoError(t, err)
+
+	claims := func(audience, serviceURL string) jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":        tokenIssuer,
+			"aud":        audience,
+			"exp":        time.Now().Add(time.Hour).Unix(),
+			"serviceurl": serviceURL,
+		}
+	}
+	serviceURL := "https://smba.trafficmanager.net/emea/"
+	ctx := context.Background()
+
+	valid := signToken(t, key, "key-1", claims("app-1", serviceURL))
+	assert.NoError(t, client.ValidateRequest(ctx, "Bearer "+valid, serviceURL))
+
+	// Another bot's token
+	other := signToken(t, key, "key-1", claims("app-2", serviceURL))
+	assert.True(t, errors.Is(client.ValidateRequest(ctx, "Bearer "+other, serviceURL), ErrInvalidToken))
+
+	// Activity claiming a different service URL
+	assert.True(t, errors.Is(client.ValidateRequest(ctx, "Bearer "+valid, "https://attacker.example.com/"), ErrInvalidToken))
+
+	// Unknown signing key
+	forged, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	unknown := signToken(t, forged, "key-2", claims("app-1", serviceURL))
+	assert.True(t, errors.Is(client.ValidateRequest(ctx, "Bearer "+unknown, serviceURL), ErrInvalidToken))
+
+	// Missing header
+	assert.True(t, errors.Is(client.ValidateRequest(ctx, "", serviceURL), ErrInvalidToken))
+}
+
+func TestClient_Token_Cached(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "app-1", r.PostForm.Get("client_id"))
+		assert.Equal(t, tokenScope, r.PostForm.Get("scope"))
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "tok", "expires_in": 3600})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{AppID: "app-1", AppPassword: "secret", TokenURL: server.URL})
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		token, err := client.Token(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "tok", token)
+	}
+	assert.Equal(t, 1, requests)
+}
+
+func TestClient_SendActivity_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "tok", "expires_in": 3600})
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"error": map[string]string{"code": "BotNotInConversationRoster", "message": "bot not in roster"}})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&Config{AppID: "app-1", AppPassword: "secret", TokenURL: server.URL + "/token"})
+	require.NoError(t, err)
+
+	_, err = client.SendActivity(context.Background(), server.URL, "a:conv", &Activity{Type: ActivityTypeMessage, Text: "hi"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "BotNotInConversationRoster")
+}
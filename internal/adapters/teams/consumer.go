@@ -0,0 +1,132 @@
+package teams
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/logger"
+	"github.com/msgfy/linktor/pkg/plugin"
+	"go.uber.org/zap"
+)
+
+// ChannelConfigLoader returns the configuration and credentials of a Teams channel
+type ChannelConfigLoader func(ctx context.Context, channelID string) (map[string]string, error)
+
+// OutboundSender delivers outbound messages of Teams channels queued on NATS. Each
+// channel has its own bot, so a connected adapter is kept per channel.
+type OutboundSender struct {
+	loadConfig ChannelConfigLoader
+	producer   nats.Publisher
+
+	mu       sync.Mutex
+	adapters map[string]*Adapter
+}
+
+// NewOutboundSender creates a new Teams outbound sender
+func NewOutboundSender(loadConfig ChannelConfigLoader, producer nats.Publisher) *OutboundSender {
+	return &OutboundSender{
+		loadConfig: loadConfig,
+		producer:   producer,
+		adapters:   make(map[string]*Adapter),
+	}
+}
+
+// HandleOutbound sends an outbound message and publishes its delivery status.
+// Returning an error lets NATS redeliver the message.
+func (s *OutboundSender) HandleOutbound(ctx context.Context, msg *nats.OutboundMessage) error {
+	adapter, err := s.adapter(ctx, msg.ChannelID)
+	if err != nil {
+		s.publishStatus(ctx, msg, "", "failed", err.Error())
+		return err
+	}
+
+	pluginMsg := &plugin.OutboundMessage{
+		ID:             msg.ID,
+		ConversationID: msg.ConversationID,
+		RecipientID:    msg.RecipientID,
+		ContentType:    plugin.ContentType(msg.ContentType),
+		Content:        msg.Content,
+		Metadata:       msg.Metadata,
+	}
+	for _, att := range msg.Attachments {
+		pluginMsg.Attachments = append(pluginMsg.Attachments, &plugin.Attachment{
+			Type:         att.Type,
+			URL:          att.URL,
+			Filename:     att.Filename,
+			MimeType:     att.MimeType,
+			SizeBytes:    att.SizeBytes,
+			ThumbnailURL: att.ThumbnailURL,
+			Metadata:     att.Metadata,
+		})
+	}
+
+	result, err := adapter.SendMessage(ctx, pluginMsg)
+	if err != nil {
+		s.publishStatus(ctx, msg, "", "failed", err.Error())
+		return err
+	}
+	if !result.Success {
+		s.publishStatus(ctx, msg, "", "failed", result.Error)
+		return fmt.Errorf("send failed: %s", result.Error)
+	}
+
+	s.publishStatus(ctx, msg, result.ExternalID, "sent", "")
+	return nil
+}
+
+// Forget drops the adapter of a channel so changed credentials are picked up
+func (s *OutboundSender) Forget(channelID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.adapters, channelID)
+}
+
+// adapter returns the connected adapter of a channel, connecting it on first use
+func (s *OutboundSender) adapter(ctx context.Context, channelID string) (*Adapter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if adapter, ok := s.adapters[channelID]; ok {
+		return adapter, nil
+	}
+
+	config, err := s.loadConfig(ctx, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Teams channel: %w", err)
+	}
+	adapter := NewAdapter()
+	if err := adapter.Initialize(config); err != nil {
+		return nil, fmt.Errorf("invalid Teams channel configuration: %w", err)
+	}
+	if err := adapter.Connect(ctx); err != nil {
+		return nil, err
+	}
+
+	s.adapters[channelID] = adapter
+	return adapter, nil
+}
+
+// publishStatus publishes the delivery status of a message
+func (s *OutboundSender) publishStatus(ctx context.Context, msg *nats.OutboundMessage, externalID, status, errorMsg string) {
+	if s.producer == nil {
+		return
+	}
+
+	update := &nats.StatusUpdate{
+		MessageID:    msg.ID,
+		ExternalID:   externalID,
+		ChannelType:  msg.ChannelType,
+		Status:       status,
+		ErrorMessage: errorMsg,
+		Timestamp:    time.Now(),
+	}
+	if err := s.producer.PublishStatusUpdate(ctx, update); err != nil {
+		logger.Error("Failed to publish Teams status update",
+			zap.String("message_id", msg.ID),
+			zap.Error(err),
+		)
+	}
+}
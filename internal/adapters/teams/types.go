@@ -0,0 +1,241 @@
+package teams
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Errors
+var (
+	ErrMissingCredentials = errors.New("missing Teams credentials")
+	ErrInvalidToken       = errors.New("invalid Bot Framework token")
+	ErrUnsupportedCard    = errors.New("unsupported interactive message")
+)
+
+const (
+	// DefaultServiceURL is the global Bot Connector endpoint of Teams, valid for every region
+	DefaultServiceURL = "https://smba.trafficmanager.net/teams/"
+	// DefaultTokenTenant issues tokens for multi-tenant bots
+	DefaultTokenTenant = "botframework.com"
+	// DefaultOpenIDMetadataURL describes the keys Bot Framework signs channel requests with
+	DefaultOpenIDMetadataURL = "https://login.botframework.com/v1/.well-known/openidconfiguration"
+
+	tokenURLFormat = "https://login.microsoftonline.com/%s/oauth2/v2.0/token"
+	tokenScope     = "https://api.botframework.com/.default"
+	tokenIssuer    = "https://api.botframework.com"
+
+	// MaxMessageLength is the maximum text length of a Teams message
+	MaxMessageLength = 28000
+)
+
+// Activity types
+const (
+	ActivityTypeMessage            = "message"
+	ActivityTypeTyping             = "typing"
+	ActivityTypeConversationUpdate = "conversationUpdate"
+	ActivityTypeInvoke             = "invoke"
+)
+
+// Config holds the configuration of a Teams bot registered with Azure Bot Service
+type Config struct {
+	// AppID is the Microsoft App ID of the bot
+	AppID string `json:"app_id"`
+
+	// AppPassword is the client secret of the bot's app registration
+	AppPassword string `json:"app_password"`
+
+	// AppTenantID is the Azure AD tenant of single-tenant bots; multi-tenant bots leave it empty
+	AppTenantID string `json:"app_tenant_id,omitempty"`
+
+	// ServiceURL is the Bot Connector endpoint messages are sent to (optional)
+	ServiceURL string `json:"service_url,omitempty"`
+
+	// TokenURL overrides the token endpoint (optional, for sovereign clouds and tests)
+	TokenURL string `json:"token_url,omitempty"`
+
+	// OpenIDMetadataURL overrides where channel request signing keys are discovered (optional)
+	OpenIDMetadataURL string `json:"openid_metadata_url,omitempty"`
+}
+
+// Validate validates the configuration
+func (c *Config) Validate() error {
+	if c.AppID == "" {
+		return errors.New("app_id is required")
+	}
+	if c.AppPassword == "" {
+		return errors.New("app_password is required")
+	}
+	return nil
+}
+
+// GetServiceURL returns the Bot Connector endpoint
+func (c *Config) GetServiceURL() string {
+	if c.ServiceURL != "" {
+		return c.ServiceURL
+	}
+	return DefaultServiceURL
+}
+
+// GetTokenURL returns the endpoint bot tokens are requested from
+func (c *Config) GetTokenURL() string {
+	if c.TokenURL != "" {
+		return c.TokenURL
+	}
+	tenant := c.AppTenantID
+	if tenant == "" {
+		tenant = DefaultTokenTenant
+	}
+	return fmt.Sprintf(tokenURLFormat, tenant)
+}
+
+// GetOpenIDMetadataURL returns where channel request signing keys are discovered
+func (c *Config) GetOpenIDMetadataURL() string {
+	if c.OpenIDMetadataURL != "" {
+		return c.OpenIDMetadataURL
+	}
+	return DefaultOpenIDMetadataURL
+}
+
+// ChannelAccount identifies a user or bot
+type ChannelAccount struct {
+	ID          string `json:"id"`
+	Name        string `json:"name,omitempty"`
+	AADObjectID string `json:"aadObjectId,omitempty"`
+}
+
+// ConversationAccount identifies a personal chat, group chat or channel thread
+type ConversationAccount struct {
+	ID               string `json:"id"`
+	Name             string `json:"name,omitempty"`
+	ConversationType string `json:"conversationType,omitempty"` // personal, groupChat or channel
+	TenantID         string `json:"tenantId,omitempty"`
+	IsGroup          bool   `json:"isGroup,omitempty"`
+}
+
+// Attachment is a file, image or card of an activity
+type Attachment struct {
+	ContentType  string      `json:"contentType"`
+	ContentURL   string      `json:"contentUrl,omitempty"`
+	Content      interface{} `json:"content,omitempty"`
+	Name         string      `json:"name,omitempty"`
+	ThumbnailURL string      `json:"thumbnailUrl,omitempty"`
+}
+
+// Activity is a Bot Framework activity, both received from and sent to Teams
+type Activity struct {
+	Type         string               `json:"type"`
+	ID           string               `json:"id,omitempty"`
+	Timestamp    *time.Time           `json:"timestamp,omitempty"`
+	ServiceURL   string               `json:"serviceUrl,omitempty"`
+	ChannelID    string               `json:"channelId,omitempty"`
+	From         *ChannelAccount      `json:"from,omitempty"`
+	Conversation *ConversationAccount `json:"conversation,omitempty"`
+	Recipient    *ChannelAccount      `json:"recipient,omitempty"`
+	Text         string               `json:"text,omitempty"`
+	TextFormat   string               `json:"textFormat,omitempty"`
+	Attachments  []Attachment         `json:"attachments,omitempty"`
+	Value        json.RawMessage      `json:"value,omitempty"`
+	ReplyToID    string               `json:"replyToId,omitempty"`
+	ChannelData  json.RawMessage      `json:"channelData,omitempty"`
+}
+
+// ResourceResponse is returned by Bot Connector for a sent activity
+type ResourceResponse struct {
+	ID string `json:"id"`
+}
+
+// ErrorResponse is the error body of Bot Connector
+type ErrorResponse struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// CardSubmit is the data an adaptive card sent by Linktor submits back
+type CardSubmit struct {
+	ButtonID string `json:"button_id,omitempty"`
+	ListID   string `json:"list_id,omitempty"`
+	Title    string `json:"title,omitempty"`
+}
+
+// ParseActivity parses an activity posted to the bot's messaging endpoint
+func ParseActivity(body []byte) (*Activity, error) {
+	var activity Activity
+	if err := json.Unmarshal(body, &activity); err != nil {
+		return nil, err
+	}
+	if activity.Type == "" {
+		return nil, errors.New("activity type is required")
+	}
+	return &activity, nil
+}
+
+var mentionPattern = regexp.MustCompile(`(?s)<at[^>]*>.*?</at>`)
+
+// PlainText returns the text of the activity without the bot mentions Teams adds in
+// group chats and channels
+func (a *Activity) PlainText() string {
+	return strings.TrimSpace(mentionPattern.ReplaceAllString(a.Text, ""))
+}
+
+// CardSubmit returns the data submitted from an adaptive card, or nil when the
+// activity is not a card submission
+func (a *Activity) CardSubmit() *CardSubmit {
+	if len(a.Value) == 0 {
+		return nil
+	}
+	var submit CardSubmit
+	if err := json.Unmarshal(a.Value, &submit); err != nil {
+		return nil
+	}
+	if submit.ButtonID == "" && submit.ListID == "" {
+		return nil
+	}
+	return &submit
+}
+
+// FileAttachments returns the files and images the user sent, skipping the cards
+// and HTML renderings of the message Teams attaches as well
+func (a *Activity) FileAttachments() []Attachment {
+	var files []Attachment
+	for _, attachment := range a.Attachments {
+		switch {
+		case attachment.ContentType == "application/vnd.microsoft.teams.file.download.info":
+			content, _ := attachment.Content.(map[string]interface{})
+			if url, ok := content["downloadUrl"].(string); ok {
+				fileType, _ := content["fileType"].(string)
+				files = append(files, Attachment{ContentType: mimeTypeFromExtension(fileType), ContentURL: url, Name: attachment.Name})
+			}
+		case attachment.ContentURL != "" && !strings.HasPrefix(attachment.ContentType, "application/vnd.microsoft.card"):
+			files = append(files, attachment)
+		}
+	}
+	return files
+}
+
+// mimeTypeFromExtension maps the file types Teams reports to MIME types
+func mimeTypeFromExtension(extension string) string {
+	switch strings.ToLower(extension) {
+	case "png":
+		return "image/png"
+	case "jpg", "jpeg":
+		return "image/jpeg"
+	case "gif":
+		return "image/gif"
+	case "pdf":
+		return "application/pdf"
+	case "mp4":
+		return "video/mp4"
+	case "mp3":
+		return "audio/mpeg"
+	case "txt":
+		return "text/plain"
+	default:
+		return "application/octet-stream"
+	}
+}
@@ -0,0 +1,77 @@
+package teams
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfig_Defaults(t *testing.T) {
+	config := &Config{AppID: "app-1", AppPassword: "secret"}
+	assert.Equal(t, DefaultServiceURL, config.GetServiceURL())
+	assert.Equal(t, "https://login.microsoftonline.com/botframework.com/oauth2/v2.0/token", config.GetTokenURL())
+	assert.Equal(t, DefaultOpenIDMetadataURL, config.GetOpenIDMetadataURL())
+
+	config.AppTenantID = "contoso-tenant"
+	assert.Equal(t, "https://login.microsoftonline.com/contoso-tenant/oauth2/v2.0/token", config.GetTokenURL())
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.Error(t, (&Config{}).Validate())
+	assert.Error(t, (&Config{AppID: "app-1"}).Validate())
+	assert.NoError(t, (&Config{AppID: "app-1", AppPassword: "secret"}).Validate())
+}
+
+func TestParseActivity(t *testing.T) {
+	activity, err := ParseActivity([]byte(`{
+		"type": "message",
+		"id": "1",
+		"serviceUrl": "https://smba.trafficmanager.net/emea/",
+		"from": {"id": "29:user", "name": "Ana", "aadObjectId": "aad-1"},
+		"conversation": {"id": "a:conv", "conversationType": "personal", "tenantId": "t-1"},
+		"text": "<at>Linktor</at> hello there"
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, ActivityTypeMessage, activity.Type)
+	assert.Equal(t, "aad-1", activity.From.AADObjectID)
+	assert.Equal(t, "a:conv", activity.Conversation.ID)
+	assert.Equal(t, "hello there", activity.PlainText())
+
+	_, err = ParseActivity([]byte(`{}`))
+	assert.Error(t, err)
+}
+
+func TestActivity_CardSubmit(t *testing.T) {
+	activity := &Activity{Value: []byte(`{"button_id":"confirm","title":"Confirm"}`)}
+	submit := activity.CardSubmit()
+	require.NotNil(t, submit)
+	assert.Equal(t, "confirm", submit.ButtonID)
+
+	activity = &Activity{Value: []byte(`{"list_id":"pro"}`)}
+	require.NotNil(t, activity.CardSubmit())
+	assert.Equal(t, "pro", activity.CardSubmit().ListID)
+
+	assert.Nil(t, (&Activity{}).CardSubmit())
+	assert.Nil(t, (&Activity{Value: []byte(`{"other":"x"}`)}).CardSubmit())
+}
+
+func TestActivity_FileAttachments(t *testing.T) {
+	activity := &Activity{Attachments: []Attachment{
+		{ContentType: "text/html", Content: "<p>hi</p>"},
+		{ContentType: "application/vnd.microsoft.card.adaptive", ContentURL: "https://ignored"},
+		{ContentType: "image/png", ContentURL: "https://teams.example.com/img.png"},
+		{
+			ContentType: "application/vnd.microsoft.teams.file.download.info",
+			Name:        "report.pdf",
+			Content:     map[string]interface{}{"downloadUrl": "https://sharepoint.example.com/report.pdf", "fileType": "pdf"},
+		},
+	}}
+
+	files := activity.FileAttachments()
+	require.Len(t, files, 2)
+	assert.Equal(t, "image/png", files[0].ContentType)
+	assert.Equal(t, "application/pdf", files[1].ContentType)
+	assert.Equal(t, "report.pdf", files[1].Name)
+	assert.Equal(t, "https://sharepoint.example.com/report.pdf", files[1].ContentURL)
+}
@@ -47,6 +47,27 @@ func (c *Client) SetWebhook(webhookURL string) error {
 	return nil
 }
 
+// SetWebhookWithSecret configures the webhook URL and the secret token Telegram sends
+// in the X-Telegram-Bot-Api-Secret-Token header of every update
+func (c *Client) SetWebhookWithSecret(webhookURL, secretToken string) error {
+	params := tgbotapi.Params{"url": webhookURL}
+	params.AddNonEmpty("secret_token", secretToken)
+
+	if _, err := c.api.MakeRequest("setWebhook", params); err != nil {
+		return fmt.Errorf("failed to set webhook: %w", err)
+	}
+	return nil
+}
+
+// GetWebhookInfo returns the current webhook registration of the bot
+func (c *Client) GetWebhookInfo() (tgbotapi.WebhookInfo, error) {
+	info, err := c.api.GetWebhookInfo()
+	if err != nil {
+		return info, fmt.Errorf("failed to get webhook info: %w", err)
+	}
+	return info, nil
+}
+
 // DeleteWebhook removes the current webhook
 func (c *Client) DeleteWebhook() error {
 	dw := tgbotapi.DeleteWebhookConfig{
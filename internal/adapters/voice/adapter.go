@@ -49,6 +49,7 @@ type Adapter struct {
 	provider Provider
 	config   VoiceConfig
 	handler  WebhookHandler
+	queues   *QueueManager
 }
 
 // WebhookHandler handles incoming voice webhooks
@@ -73,9 +74,15 @@ func NewAdapter(config VoiceConfig) (*Adapter, error) {
 		return nil, fmt.Errorf("unsupported voice provider: %s", config.Provider)
 	}
 
+	queues := NewQueueManager()
+	if aware, ok := provider.(QueueAware); ok {
+		aware.SetQueueManager(queues)
+	}
+
 	return &Adapter{
 		provider: provider,
 		config:   config,
+		queues:   queues,
 	}, nil
 }
 
@@ -89,6 +96,21 @@ func (a *Adapter) SetWebhookHandler(handler WebhookHandler) {
 	a.handler = handler
 }
 
+// Queues returns the manager of the inbound call queues
+func (a *Adapter) Queues() *QueueManager {
+	return a.queues
+}
+
+// SetCallbackRouter sets where callback requests from queued callers are routed
+func (a *Adapter) SetCallbackRouter(router CallbackRouter) {
+	a.queues.SetCallbackRouter(router)
+}
+
+// SetVoicemailHandler sets where voicemails of unanswered queued callers are delivered
+func (a *Adapter) SetVoicemailHandler(handler VoicemailHandler) {
+	a.queues.SetVoicemailHandler(handler)
+}
+
 // Name returns the adapter name
 func (a *Adapter) Name() string {
 	return "voice"
@@ -174,6 +196,14 @@ func (a *Adapter) HandleWebhook(ctx context.Context, headers map[string]string,
 		return nil, fmt.Errorf("failed to parse webhook: %w", err)
 	}
 
+	// Callers pressing the callback digit leave the queue with a callback request
+	if actions, handled, err := a.handleQueueEvent(ctx, event); handled {
+		if err != nil {
+			return nil, err
+		}
+		return a.provider.GenerateIVRResponse(actions)
+	}
+
 	// If no handler is set, return empty response
 	if a.handler == nil {
 		return a.provider.GenerateIVRResponse(nil)
@@ -189,6 +219,30 @@ func (a *Adapter) HandleWebhook(ctx context.Context, headers map[string]string,
 	return a.provider.GenerateIVRResponse(actions)
 }
 
+// handleQueueEvent answers the callback request of a queued caller and drops ended
+// calls from their queue. It reports whether the event was handled.
+func (a *Adapter) handleQueueEvent(ctx context.Context, event *WebhookEvent) ([]IVRAction, bool, error) {
+	entry, ok := a.queues.Entry(event.CallID)
+	if !ok {
+		return nil, false, nil
+	}
+
+	if event.Type == "call.completed" {
+		a.queues.Leave(event.CallID)
+		return nil, false, nil
+	}
+
+	config, _ := a.queues.Queue(entry.Queue)
+	if config == nil || config.CallbackDigit == "" || event.Digits != config.CallbackDigit {
+		return nil, false, nil
+	}
+
+	if _, err := a.queues.RequestCallback(ctx, event.CallID, ""); err != nil {
+		return nil, true, fmt.Errorf("failed to request callback: %w", err)
+	}
+	return BuildCallbackConfirmation(config.Language), true, nil
+}
+
 // GenerateIVRResponse generates IVR response for the current provider
 func (a *Adapter) GenerateIVRResponse(actions []IVRAction) (interface{}, error) {
 	return a.provider.GenerateIVRResponse(actions)
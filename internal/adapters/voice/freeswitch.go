@@ -8,9 +8,11 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -29,6 +31,12 @@ type FreeSWITCHProvider struct {
 	// Active calls tracking
 	calls      map[string]*Call
 	callsMutex sync.RWMutex
+
+	// Inbound queues fed by mod_callcenter events
+	queues *QueueManager
+
+	// Receives call recordings to transcribe
+	recordings RecordingHandler
 }
 
 // FreeSWITCH ESL event
@@ -234,6 +242,11 @@ func (p *FreeSWITCHProvider) handleESLEvent(event *ESLEvent) {
 	defer p.callsMutex.Unlock()
 
 	switch eventName {
+	case "CUSTOM":
+		if event.Headers["Event-Subclass"] == "callcenter::info" {
+			p.handleCallcenterEvent(event)
+		}
+
 	case "CHANNEL_CREATE":
 		call := &Call{
 			ID:         callID,
@@ -262,7 +275,230 @@ func (p *FreeSWITCHProvider) handleESLEvent(event *ESLEvent) {
 				call.Duration = int(now.Sub(*call.AnsweredAt).Seconds())
 			}
 		}
+		if p.queues != nil {
+			p.queues.CancelVoicemail(callID)
+		}
+
+	case "RECORD_STOP":
+		p.handleRecordStop(event)
+	}
+}
+
+// SetQueueManager sets the queue manager fed by mod_callcenter events
+func (p *FreeSWITCHProvider) SetQueueManager(manager *QueueManager) {
+	p.queues = manager
+}
+
+// handleCallcenterEvent tracks queue members from mod_callcenter events. Callers
+// hold callsMutex.
+func (p *FreeSWITCHProvider) handleCallcenterEvent(event *ESLEvent) {
+	if p.queues == nil {
+		return
+	}
+
+	callID := event.Headers["Unique-ID"]
+	switch event.Headers["CC-Action"] {
+	case "member-queue-start":
+		call, ok := p.calls[callID]
+		if !ok {
+			call = &Call{
+				ID:   callID,
+				From: event.Headers["CC-Member-CID-Number"],
+			}
+		}
+		if call.CallerName == "" {
+			call.CallerName = event.Headers["CC-Member-CID-Name"]
+		}
+		p.queues.Enqueue(event.Headers["CC-Queue"], call)
+
+	case "bridge-agent-start":
+		p.queues.Answer(callID)
+
+	case "member-queue-end":
+		p.queues.Leave(callID)
+	}
+}
+
+// AnnounceQueuePositions tells the queued callers whose announcement is due their
+// position and estimated wait. Call it periodically.
+func (p *FreeSWITCHProvider) AnnounceQueuePositions(now time.Time) error {
+	if p.queues == nil {
+		return nil
+	}
+	if p.eslConn == nil {
+		return fmt.Errorf("ESL not connected")
+	}
+
+	for _, entry := range p.queues.DueAnnouncements(now) {
+		for _, action := range p.queues.Announcement(entry.CallID) {
+			say, ok := action.(IVRSay)
+			if !ok {
+				continue
+			}
+			voice := p.mapVoice(say.Voice, say.Language)
+			cmd := fmt.Sprintf("bgapi uuid_broadcast %s speak::%s|%s|%s aleg", entry.CallID, voice, say.Language, say.Text)
+			if err := p.eslSendCommand(cmd); err != nil {
+				return fmt.Errorf("failed to announce queue position: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// SendDueVoicemails moves the queued callers who waited longer than their queue's
+// voicemail SLA out of mod_callcenter to record a voicemail. Call it periodically.
+func (p *FreeSWITCHProvider) SendDueVoicemails(now time.Time) error {
+	if p.queues == nil {
+		return nil
+	}
+	if p.eslConn == nil {
+		return fmt.Errorf("ESL not connected")
+	}
+
+	for _, voicemail := range p.queues.DueVoicemails(now) {
+		config, _ := p.queues.Queue(voicemail.Queue)
+		var maxLength time.Duration
+		if config != nil {
+			maxLength = config.VoicemailMaxLength
+		}
+
+		// Inline dialplan applications are separated by "^" since tone streams contain commas
+		var apps []string
+		for _, action := range BuildVoicemailPrompt(maxLength, voicemail.Language) {
+			switch a := action.(type) {
+			case IVRSay:
+				voice := p.mapVoice(a.Voice, a.Language)
+				apps = append(apps, fmt.Sprintf("speak:%s|%s|%s", voice, a.Language, a.Text))
+			case IVRRecord:
+				if a.PlayBeep {
+					apps = append(apps, "playback:tone_stream://%(500,0,800)")
+				}
+				if a.FinishOnKey != "" {
+					apps = append(apps, "set:playback_terminators="+a.FinishOnKey)
+				}
+				apps = append(apps, fmt.Sprintf("record:%s %d 200 %d", p.voicemailPath(voicemail.CallID), a.MaxLength, a.Timeout))
+			case IVRHangup:
+				apps = append(apps, "hangup")
+			}
+		}
+
+		cmd := fmt.Sprintf("bgapi uuid_transfer %s 'm:^:%s' inline", voicemail.CallID, strings.Join(apps, "^"))
+		if err := p.eslSendCommand(cmd); err != nil {
+			p.queues.CancelVoicemail(voicemail.CallID)
+			return fmt.Errorf("failed to send call to voicemail: %w", err)
+		}
+	}
+	return nil
+}
+
+// voicemailPath returns where FreeSWITCH records the voicemail of a call
+func (p *FreeSWITCHProvider) voicemailPath(callID string) string {
+	dir := p.config.Credentials["recordings_path"]
+	if dir == "" {
+		dir = "/var/lib/freeswitch/recordings"
+	}
+	return fmt.Sprintf("%s/voicemail/%s.wav", strings.TrimSuffix(dir, "/"), callID)
+}
+
+// SetRecordingHandler sets where call recordings are delivered
+func (p *FreeSWITCHProvider) SetRecordingHandler(handler RecordingHandler) {
+	p.recordings = handler
+}
+
+// handleRecordStop delivers a recording once it stopped: voicemails to the queue
+// manager and, when calls are transcribed, other recordings to the recording
+// handler. Callers hold callsMutex.
+func (p *FreeSWITCHProvider) handleRecordStop(event *ESLEvent) {
+	callID := event.Headers["Unique-ID"]
+	path := event.Headers["Record-File-Path"]
+	duration, _ := strconv.Atoi(event.Headers["variable_record_seconds"])
+	if path != p.voicemailPath(callID) {
+		p.deliverRecording(callID, path, duration)
+		return
+	}
+	if p.queues == nil {
+		return
+	}
+
+	voicemail, ok := p.queues.RecordedVoicemail(callID, path, duration)
+	if !ok {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		defer cancel()
+
+		audio, err := p.downloadRecording(ctx, path)
+		if err != nil {
+			return
+		}
+		_ = p.queues.DeliverVoicemail(ctx, voicemail, audio, "audio/wav")
+	}()
+}
+
+// deliverRecording hands a call recording to the recording handler. Callers hold
+// callsMutex.
+func (p *FreeSWITCHProvider) deliverRecording(callID, path string, duration int) {
+	if p.recordings == nil || !p.config.TranscribeCalls || path == "" {
+		return
+	}
+
+	recording := &CallRecording{
+		CallID:     callID,
+		Language:   p.config.DefaultLanguage,
+		Path:       path,
+		Duration:   duration,
+		RecordedAt: time.Now(),
+	}
+	if call, ok := p.calls[callID]; ok {
+		recording.Direction = call.Direction
+		recording.From = call.From
+		recording.To = call.To
+		recording.CallerName = call.CallerName
+	}
+	handler := p.recordings
+
+	go func() {
+		// Transcribing a long call takes a while
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+
+		audio, err := p.downloadRecording(ctx, path)
+		if err != nil {
+			return
+		}
+		_ = handler.HandleRecording(ctx, recording, audio, "audio/wav")
+	}()
+}
+
+// downloadRecording fetches a recording from the web server exposing the recordings
+// directory
+func (p *FreeSWITCHProvider) downloadRecording(ctx context.Context, path string) ([]byte, error) {
+	webURL := p.config.Credentials["recordings_url"]
+	if webURL == "" {
+		webURL = "http://localhost/recordings"
+	}
+	dir := p.config.Credentials["recordings_path"]
+	if dir == "" {
+		dir = "/var/lib/freeswitch/recordings"
 	}
+	relative := strings.TrimPrefix(strings.TrimPrefix(path, strings.TrimSuffix(dir, "/")), "/")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/%s", strings.TrimSuffix(webURL, "/"), relative), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download recording: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download recording: status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
 }
 
 // parseDirection parses FreeSWITCH call direction
@@ -643,6 +879,19 @@ func (p *FreeSWITCHProvider) GenerateIVRResponse(actions []IVRAction) (interface
 
 		case IVRQueue:
 			// Add to call queue using mod_callcenter
+			if a.WaitURL != "" {
+				fsActions = append(fsActions, FSAction{
+					Application: "set",
+					Data:        fmt.Sprintf("cc_moh_override=%s", a.WaitURL),
+				})
+			}
+			if a.CallbackDigit != "" {
+				// Pressing the digit leaves the queue and resumes the dialplan
+				fsActions = append(fsActions, FSAction{
+					Application: "set",
+					Data:        fmt.Sprintf("cc_exit_keys=%s", a.CallbackDigit),
+				})
+			}
 			fsActions = append(fsActions, FSAction{
 				Application: "callcenter",
 				Data:        a.Name,
@@ -0,0 +1,498 @@
+package voice
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QueueConfig configures an inbound call queue
+type QueueConfig struct {
+	Name              string        `json:"name"`
+	HoldMusicURL      string        `json:"holdMusicUrl,omitempty"`
+	AnnounceInterval  time.Duration `json:"announceInterval,omitempty"`  // How often callers hear their position; 0 disables announcements
+	DefaultHandleTime time.Duration `json:"defaultHandleTime,omitempty"` // Wait per caller ahead until answers are measured
+	MaxWait           time.Duration `json:"maxWait,omitempty"`
+	CallbackDigit     string        `json:"callbackDigit,omitempty"` // DTMF digit that leaves the queue with a callback request
+	Language          string        `json:"language,omitempty"`
+
+	VoicemailAfter     time.Duration `json:"voicemailAfter,omitempty"`     // Wait after which callers leave a voicemail; 0 disables voicemail
+	VoicemailMaxLength time.Duration `json:"voicemailMaxLength,omitempty"` // Longest voicemail recorded
+}
+
+// QueueEntry is a caller waiting in a queue
+type QueueEntry struct {
+	CallID          string        `json:"callId"`
+	Queue           string        `json:"queue"`
+	From            string        `json:"from"`
+	CallerName      string        `json:"callerName,omitempty"`
+	Position        int           `json:"position"` // 1 is the next caller answered
+	EstimatedWait   time.Duration `json:"estimatedWait"`
+	EnqueuedAt      time.Time     `json:"enqueuedAt"`
+	LastAnnouncedAt *time.Time    `json:"lastAnnouncedAt,omitempty"`
+
+	seq          uint64 // Arrival order, as clocks may tie
+	joinPosition int    // Position when the call joined
+}
+
+// QueueStats summarizes the callers waiting in a queue
+type QueueStats struct {
+	Queue         string        `json:"queue"`
+	Waiting       int           `json:"waiting"`
+	LongestWait   time.Duration `json:"longestWait"`
+	AverageWait   time.Duration `json:"averageWait"`   // Measured on recently answered calls
+	EstimatedWait time.Duration `json:"estimatedWait"` // For a caller joining now
+	Callbacks     int           `json:"callbacks"`     // Callback requests not completed yet
+}
+
+// CallbackStatus is the state of a callback request
+type CallbackStatus string
+
+const (
+	CallbackStatusPending   CallbackStatus = "pending"
+	CallbackStatusAssigned  CallbackStatus = "assigned"
+	CallbackStatusCompleted CallbackStatus = "completed"
+	CallbackStatusCanceled  CallbackStatus = "canceled"
+)
+
+// CallbackRequest is a caller who left a queue asking to be called back. The router
+// assigns it to an agent and links the contact's conversation for context.
+type CallbackRequest struct {
+	ID             string         `json:"id"`
+	Queue          string         `json:"queue"`
+	CallID         string         `json:"callId"`
+	Phone          string         `json:"phone"`
+	CallerName     string         `json:"callerName,omitempty"`
+	ContactID      string         `json:"contactId,omitempty"`
+	ConversationID string         `json:"conversationId,omitempty"`
+	AssignedTo     string         `json:"assignedTo,omitempty"`
+	Status         CallbackStatus `json:"status"`
+	Position       int            `json:"position"` // Position the caller gave up
+	WaitedFor      time.Duration  `json:"waitedFor"`
+	RequestedAt    time.Time      `json:"requestedAt"`
+	CompletedAt    *time.Time     `json:"completedAt,omitempty"`
+}
+
+// CallbackRouter turns callback requests into tasks for agents. Implementations set
+// ContactID, ConversationID and AssignedTo on the request.
+type CallbackRouter interface {
+	RouteCallback(ctx context.Context, request *CallbackRequest) error
+}
+
+// QueueAware is implemented by providers that feed queue events to a QueueManager
+type QueueAware interface {
+	SetQueueManager(manager *QueueManager)
+}
+
+const (
+	// defaultHandleTime is the wait per caller ahead before any answer is measured
+	defaultHandleTime = 2 * time.Minute
+
+	// waitSamples is how many recent answers the wait estimate averages
+	waitSamples = 20
+)
+
+// QueueManager keeps inbound call queues in memory: who is waiting, their position,
+// the estimated wait, the callback requests of callers who gave up waiting and the
+// voicemails of callers no agent answered in time
+type QueueManager struct {
+	mu               sync.RWMutex
+	queues           map[string]*QueueConfig
+	entries          map[string]*QueueEntry // By call ID
+	samples          map[string][]time.Duration
+	callbacks        map[string]*CallbackRequest
+	voicemails       map[string]*Voicemail // Being recorded, by call ID
+	router           CallbackRouter
+	voicemailHandler VoicemailHandler
+	nextSeq          uint64
+}
+
+// NewQueueManager creates an empty queue manager
+func NewQueueManager() *QueueManager {
+	return &QueueManager{
+		queues:     make(map[string]*QueueConfig),
+		entries:    make(map[string]*QueueEntry),
+		samples:    make(map[string][]time.Duration),
+		callbacks:  make(map[string]*CallbackRequest),
+		voicemails: make(map[string]*Voicemail),
+	}
+}
+
+// SetCallbackRouter sets where callback requests are routed
+func (m *QueueManager) SetCallbackRouter(router CallbackRouter) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.router = router
+}
+
+// RegisterQueue adds or replaces a queue
+func (m *QueueManager) RegisterQueue(config QueueConfig) error {
+	if config.Name == "" {
+		return fmt.Errorf("queue name is required")
+	}
+	if config.DefaultHandleTime <= 0 {
+		config.DefaultHandleTime = defaultHandleTime
+	}
+	if config.Language == "" {
+		config.Language = "pt-BR"
+	}
+	if config.VoicemailMaxLength <= 0 {
+		config.VoicemailMaxLength = defaultVoicemailMaxLength
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.queues[config.Name] = &config
+	return nil
+}
+
+// Queue returns the configuration of a queue
+func (m *QueueManager) Queue(name string) (*QueueConfig, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	config, ok := m.queues[name]
+	return config, ok
+}
+
+// Enqueue puts a call at the end of a queue. Calls already waiting keep their place.
+func (m *QueueManager) Enqueue(queueName string, call *Call) (*QueueEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.queues[queueName]; !ok {
+		return nil, fmt.Errorf("queue not found: %s", queueName)
+	}
+	if entry, ok := m.entries[call.ID]; ok {
+		return m.snapshot(entry), nil
+	}
+
+	m.nextSeq++
+	entry := &QueueEntry{
+		CallID:     call.ID,
+		Queue:      queueName,
+		From:       call.From,
+		CallerName: call.CallerName,
+		EnqueuedAt: time.Now(),
+		seq:        m.nextSeq,
+	}
+	m.entries[call.ID] = entry
+
+	snapshot := m.snapshot(entry)
+	entry.joinPosition = snapshot.Position
+	return snapshot, nil
+}
+
+// Answer removes a call answered by an agent from its queue and measures its wait
+func (m *QueueManager) Answer(callID string) (*QueueEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[callID]
+	if !ok {
+		return nil, false
+	}
+	snapshot := m.snapshot(entry)
+	delete(m.entries, callID)
+
+	// Every caller ahead when it joined was answered during the wait, so the wait per
+	// position is the time one caller takes to get through
+	perCaller := time.Since(entry.EnqueuedAt) / time.Duration(entry.joinPosition)
+	samples := append(m.samples[entry.Queue], perCaller)
+	if len(samples) > waitSamples {
+		samples = samples[len(samples)-waitSamples:]
+	}
+	m.samples[entry.Queue] = samples
+	return snapshot, true
+}
+
+// Leave removes a call that hung up or timed out from its queue
+func (m *QueueManager) Leave(callID string) (*QueueEntry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[callID]
+	if !ok {
+		return nil, false
+	}
+	snapshot := m.snapshot(entry)
+	delete(m.entries, callID)
+	return snapshot, true
+}
+
+// Entry returns a waiting call with its current position and estimated wait
+func (m *QueueManager) Entry(callID string) (*QueueEntry, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entry, ok := m.entries[callID]
+	if !ok {
+		return nil, false
+	}
+	return m.snapshot(entry), true
+}
+
+// Entries lists the calls waiting in a queue in answering order
+func (m *QueueManager) Entries(queueName string) []*QueueEntry {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	waiting := m.waiting(queueName)
+	result := make([]*QueueEntry, len(waiting))
+	for i, entry := range waiting {
+		result[i] = m.snapshot(entry)
+	}
+	return result
+}
+
+// Stats summarizes a queue
+func (m *QueueManager) Stats(queueName string) (*QueueStats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, ok := m.queues[queueName]; !ok {
+		return nil, fmt.Errorf("queue not found: %s", queueName)
+	}
+
+	waiting := m.waiting(queueName)
+	stats := &QueueStats{
+		Queue:         queueName,
+		Waiting:       len(waiting),
+		EstimatedWait: m.estimate(queueName, len(waiting)+1),
+	}
+	if len(waiting) > 0 {
+		stats.LongestWait = time.Since(waiting[0].EnqueuedAt)
+	}
+	if samples := m.samples[queueName]; len(samples) > 0 {
+		stats.AverageWait = m.perCaller(queueName)
+	}
+	for _, request := range m.callbacks {
+		if request.Queue == queueName && (request.Status == CallbackStatusPending || request.Status == CallbackStatusAssigned) {
+			stats.Callbacks++
+		}
+	}
+	return stats, nil
+}
+
+// DueAnnouncements returns the waiting calls whose position announcement is due and
+// marks them announced
+func (m *QueueManager) DueAnnouncements(now time.Time) []*QueueEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var due []*QueueEntry
+	for _, entry := range m.entries {
+		config := m.queues[entry.Queue]
+		if config == nil || config.AnnounceInterval <= 0 {
+			continue
+		}
+		last := entry.EnqueuedAt
+		if entry.LastAnnouncedAt != nil {
+			last = *entry.LastAnnouncedAt
+		}
+		if now.Sub(last) < config.AnnounceInterval {
+			continue
+		}
+		announcedAt := now
+		entry.LastAnnouncedAt = &announcedAt
+		due = append(due, m.snapshot(entry))
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].seq < due[j].seq })
+	return due
+}
+
+// Announcement builds the position and estimated wait message for a waiting call,
+// offering a callback when the queue allows it
+func (m *QueueManager) Announcement(callID string) []IVRAction {
+	entry, ok := m.Entry(callID)
+	if !ok {
+		return nil
+	}
+	config, _ := m.Queue(entry.Queue)
+	return BuildQueueAnnouncement(entry.Position, entry.EstimatedWait, config.CallbackDigit, config.Language)
+}
+
+// RequestCallback takes a waiting call out of its queue and creates a callback request
+// for the given phone, or the caller's number when empty, routing it to an agent
+func (m *QueueManager) RequestCallback(ctx context.Context, callID, phone string) (*CallbackRequest, error) {
+	entry, ok := m.Leave(callID)
+	if !ok {
+		return nil, fmt.Errorf("call is not queued: %s", callID)
+	}
+	if phone == "" {
+		phone = entry.From
+	}
+
+	request := &CallbackRequest{
+		ID:          uuid.New().String(),
+		Queue:       entry.Queue,
+		CallID:      callID,
+		Phone:       phone,
+		CallerName:  entry.CallerName,
+		Status:      CallbackStatusPending,
+		Position:    entry.Position,
+		WaitedFor:   time.Since(entry.EnqueuedAt),
+		RequestedAt: time.Now(),
+	}
+
+	m.mu.RLock()
+	router := m.router
+	m.mu.RUnlock()
+	if router != nil {
+		if err := router.RouteCallback(ctx, request); err != nil {
+			return nil, fmt.Errorf("failed to route callback: %w", err)
+		}
+		if request.AssignedTo != "" {
+			request.Status = CallbackStatusAssigned
+		}
+	}
+
+	m.mu.Lock()
+	m.callbacks[request.ID] = request
+	m.mu.Unlock()
+
+	copied := *request
+	return &copied, nil
+}
+
+// Callbacks lists the callback requests of a queue, oldest first. An empty status
+// lists them all.
+func (m *QueueManager) Callbacks(queueName string, status CallbackStatus) []*CallbackRequest {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []*CallbackRequest
+	for _, request := range m.callbacks {
+		if request.Queue != queueName || (status != "" && request.Status != status) {
+			continue
+		}
+		copied := *request
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].RequestedAt.Before(result[j].RequestedAt) })
+	return result
+}
+
+// CompleteCallback marks a callback request done, or canceled when the customer
+// could not be reached
+func (m *QueueManager) CompleteCallback(id string, reached bool) (*CallbackRequest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	request, ok := m.callbacks[id]
+	if !ok {
+		return nil, fmt.Errorf("callback request not found: %s", id)
+	}
+	if request.Status == CallbackStatusCompleted || request.Status == CallbackStatusCanceled {
+		return nil, fmt.Errorf("callback request already closed: %s", id)
+	}
+
+	now := time.Now()
+	request.Status = CallbackStatusCompleted
+	if !reached {
+		request.Status = CallbackStatusCanceled
+	}
+	request.CompletedAt = &now
+
+	copied := *request
+	return &copied, nil
+}
+
+// waiting lists the entries of a queue in answering order. Callers hold the lock.
+func (m *QueueManager) waiting(queueName string) []*QueueEntry {
+	var waiting []*QueueEntry
+	for _, entry := range m.entries {
+		if entry.Queue == queueName {
+			waiting = append(waiting, entry)
+		}
+	}
+	sort.Slice(waiting, func(i, j int) bool { return waiting[i].seq < waiting[j].seq })
+	return waiting
+}
+
+// snapshot copies an entry with its current position and estimate. Callers hold the lock.
+func (m *QueueManager) snapshot(entry *QueueEntry) *QueueEntry {
+	copied := *entry
+	copied.Position = 1
+	for _, other := range m.entries {
+		if other.Queue == entry.Queue && other.seq < entry.seq {
+			copied.Position++
+		}
+	}
+	copied.EstimatedWait = m.estimate(entry.Queue, copied.Position)
+	return &copied
+}
+
+// estimate is the expected wait at a position. Callers hold the lock.
+func (m *QueueManager) estimate(queueName string, position int) time.Duration {
+	return m.perCaller(queueName) * time.Duration(position)
+}
+
+// perCaller averages the recent wait per position, falling back to the queue's
+// default handle time. Callers hold the lock.
+func (m *QueueManager) perCaller(queueName string) time.Duration {
+	samples := m.samples[queueName]
+	if len(samples) == 0 {
+		if config := m.queues[queueName]; config != nil {
+			return config.DefaultHandleTime
+		}
+		return defaultHandleTime
+	}
+	var total time.Duration
+	for _, sample := range samples {
+		total += sample
+	}
+	return total / time.Duration(len(samples))
+}
+
+// BuildQueueAnnouncement creates IVR actions telling a caller their position and the
+// estimated wait, with the callback option when a digit is given
+func BuildQueueAnnouncement(position int, estimatedWait time.Duration, callbackDigit, language string) []IVRAction {
+	if language == "" {
+		language = "pt-BR"
+	}
+
+	minutes := int((estimatedWait + time.Minute - 1) / time.Minute)
+	if minutes < 1 {
+		minutes = 1
+	}
+
+	var text string
+	if position <= 1 {
+		text = "Você é o próximo a ser atendido."
+	} else {
+		text = fmt.Sprintf("Você é o %dº da fila.", position)
+	}
+	if minutes == 1 {
+		text += " O tempo estimado de espera é de 1 minuto."
+	} else {
+		text += fmt.Sprintf(" O tempo estimado de espera é de %d minutos.", minutes)
+	}
+
+	actions := []IVRAction{IVRSay{Text: text, Language: language}}
+	if callbackDigit != "" {
+		actions = append(actions, IVRSay{
+			Text:     fmt.Sprintf("Se preferir que retornemos sua ligação, pressione %s.", callbackDigit),
+			Language: language,
+		})
+	}
+	return actions
+}
+
+// BuildCallbackConfirmation creates IVR actions confirming a callback request and
+// ending the call
+func BuildCallbackConfirmation(language string) []IVRAction {
+	if language == "" {
+		language = "pt-BR"
+	}
+	return []IVRAction{
+		IVRSay{
+			Text:     "Obrigado. Um de nossos atendentes retornará sua ligação em breve.",
+			Language: language,
+		},
+		IVRHangup{},
+	}
+}
@@ -0,0 +1,170 @@
+package voice
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type routerFunc func(ctx context.Context, request *CallbackRequest) error
+
+func (f routerFunc) RouteCallback(ctx context.Context, request *CallbackRequest) error {
+	return f(ctx, request)
+}
+
+func newTestQueueManager(t *testing.T) *QueueManager {
+	manager := NewQueueManager()
+	require.NoError(t, manager.RegisterQueue(QueueConfig{
+		Name:              "support",
+		AnnounceInterval:  30 * time.Second,
+		DefaultHandleTime: time.Minute,
+		CallbackDigit:     "9",
+	}))
+	return manager
+}
+
+func TestQueueManager_Positions(t *testing.T) {
+	manager := newTestQueueManager(t)
+
+	_, err := manager.Enqueue("sales", &Call{ID: "call-0"})
+	assert.Error(t, err)
+
+	first, err := manager.Enqueue("support", &Call{ID: "call-1", From: "+5511111111111"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, first.Position)
+	assert.Equal(t, time.Minute, first.EstimatedWait)
+
+	second, err := manager.Enqueue("support", &Call{ID: "call-2", From: "+5511222222222"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, second.Position)
+	assert.Equal(t, 2*time.Minute, second.EstimatedWait)
+
+	_, ok := manager.Answer("call-1")
+	require.True(t, ok)
+
+	entry, ok := manager.Entry("call-2")
+	require.True(t, ok)
+	assert.Equal(t, 1, entry.Position)
+	assert.Less(t, entry.EstimatedWait, time.Minute, "the measured answer replaces the default handle time")
+
+	stats, err := manager.Stats("support")
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Waiting)
+
+	_, ok = manager.Leave("call-2")
+	assert.True(t, ok)
+	assert.Empty(t, manager.Entries("support"))
+}
+
+func TestQueueManager_DueAnnouncements(t *testing.T) {
+	manager := newTestQueueManager(t)
+	_, err := manager.Enqueue("support", &Call{ID: "call-1"})
+	require.NoError(t, err)
+
+	now := time.Now()
+	assert.Empty(t, manager.DueAnnouncements(now))
+
+	due := manager.DueAnnouncements(now.Add(31 * time.Second))
+	require.Len(t, due, 1)
+	assert.Equal(t, "call-1", due[0].CallID)
+	assert.Empty(t, manager.DueAnnouncements(now.Add(40*time.Second)), "announced calls wait another interval")
+
+	actions := manager.Announcement("call-1")
+	require.Len(t, actions, 2)
+	assert.Equal(t, "Você é o próximo a ser atendido. O tempo estimado de espera é de 1 minuto.", actions[0].(IVRSay).Text)
+	assert.Contains(t, actions[1].(IVRSay).Text, "pressione 9")
+}
+
+func TestBuildQueueAnnouncement(t *testing.T) {
+	actions := BuildQueueAnnouncement(3, 150*time.Second, "", "")
+	require.Len(t, actions, 1)
+	say := actions[0].(IVRSay)
+	assert.Equal(t, "Você é o 3º da fila. O tempo estimado de espera é de 3 minutos.", say.Text)
+	assert.Equal(t, "pt-BR", say.Language)
+}
+
+func TestQueueManager_RequestCallback(t *testing.T) {
+	manager := newTestQueueManager(t)
+	manager.SetCallbackRouter(routerFunc(func(ctx context.Context, request *CallbackRequest) error {
+		request.ContactID = "contact-1"
+		request.ConversationID = "conv-1"
+		request.AssignedTo = "agent-1"
+		return nil
+	}))
+
+	_, err := manager.RequestCallback(context.Background(), "call-1", "")
+	assert.Error(t, err)
+
+	_, err = manager.Enqueue("support", &Call{ID: "call-1", From: "+5511111111111", CallerName: "John"})
+	require.NoError(t, err)
+
+	request, err := manager.RequestCallback(context.Background(), "call-1", "")
+	require.NoError(t, err)
+	assert.Equal(t, "+5511111111111", request.Phone)
+	assert.Equal(t, "conv-1", request.ConversationID)
+	assert.Equal(t, CallbackStatusAssigned, request.Status)
+	assert.Equal(t, 1, request.Position)
+
+	_, ok := manager.Entry("call-1")
+	assert.False(t, ok, "the caller leaves the queue")
+
+	stats, err := manager.Stats("support")
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Callbacks)
+
+	completed, err := manager.CompleteCallback(request.ID, true)
+	require.NoError(t, err)
+	assert.Equal(t, CallbackStatusCompleted, completed.Status)
+	assert.NotNil(t, completed.CompletedAt)
+
+	_, err = manager.CompleteCallback(request.ID, false)
+	assert.Error(t, err)
+
+	assert.Len(t, manager.Callbacks("support", ""), 1)
+	assert.Empty(t, manager.Callbacks("support", CallbackStatusPending))
+}
+
+func TestAdapter_HandleWebhook_QueueCallback(t *testing.T) {
+	adapter, err := NewAdapter(VoiceConfig{Provider: "freeswitch"})
+	require.NoError(t, err)
+	require.NoError(t, adapter.Queues().RegisterQueue(QueueConfig{Name: "support", CallbackDigit: "9"}))
+
+	var routed *CallbackRequest
+	adapter.SetCallbackRouter(routerFunc(func(ctx context.Context, request *CallbackRequest) error {
+		routed = request
+		return nil
+	}))
+
+	fs := adapter.provider.(*FreeSWITCHProvider)
+	fs.handleESLEvent(&ESLEvent{Headers: map[string]string{
+		"Event-Name":           "CUSTOM",
+		"Event-Subclass":       "callcenter::info",
+		"CC-Action":            "member-queue-start",
+		"CC-Queue":             "support",
+		"Unique-ID":            "call-1",
+		"CC-Member-CID-Number": "5511111111111",
+	}})
+	_, ok := adapter.Queues().Entry("call-1")
+	require.True(t, ok)
+
+	_, err = adapter.HandleWebhook(context.Background(), nil, []byte(`{"Event-Name":"DTMF","Unique-ID":"call-1","DTMF-Digit":"9"}`))
+	require.NoError(t, err)
+	require.NotNil(t, routed)
+	assert.Equal(t, "5511111111111", routed.Phone)
+	assert.Empty(t, adapter.Queues().Entries("support"))
+}
+
+func TestFreeSWITCH_GenerateIVRResponse_QueueCallbackDigit(t *testing.T) {
+	provider := NewFreeSWITCHProvider()
+	response, err := provider.GenerateIVRResponse([]IVRAction{IVRQueue{Name: "support", WaitURL: "local_stream://moh", CallbackDigit: "9"}})
+	require.NoError(t, err)
+
+	actions := response.([]FSAction)
+	require.Len(t, actions, 3)
+	assert.Equal(t, "cc_moh_override=local_stream://moh", actions[0].Data)
+	assert.Equal(t, "cc_exit_keys=9", actions[1].Data)
+	assert.Equal(t, "callcenter", actions[2].Application)
+}
@@ -0,0 +1,38 @@
+package voice
+
+import (
+	"context"
+	"time"
+)
+
+// CallRecording is the recording of a call, other than a voicemail
+type CallRecording struct {
+	CallID     string        `json:"callId"`
+	Direction  CallDirection `json:"direction"`
+	From       string        `json:"from"`
+	To         string        `json:"to"`
+	CallerName string        `json:"callerName,omitempty"`
+	Language   string        `json:"language,omitempty"`
+	Path       string        `json:"path"`
+	Duration   int           `json:"duration"` // in seconds
+	RecordedAt time.Time     `json:"recordedAt"`
+}
+
+// RecordingHandler receives call recordings with their audio, to transcribe them
+// into the caller's conversation
+type RecordingHandler interface {
+	HandleRecording(ctx context.Context, recording *CallRecording, audio []byte, mimeType string) error
+}
+
+// RecordingAware is implemented by providers that deliver call recordings as they stop
+type RecordingAware interface {
+	SetRecordingHandler(handler RecordingHandler)
+}
+
+// SetRecordingHandler sets where call recordings are delivered. Recordings are
+// delivered only when the channel transcribes calls.
+func (a *Adapter) SetRecordingHandler(handler RecordingHandler) {
+	if aware, ok := a.provider.(RecordingAware); ok {
+		aware.SetRecordingHandler(handler)
+	}
+}
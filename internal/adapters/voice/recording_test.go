@@ -0,0 +1,138 @@
+package voice
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingHandlerFunc func(ctx context.Context, recording *CallRecording, audio []byte, mimeType string) error
+
+func (f recordingHandlerFunc) HandleRecording(ctx context.Context, recording *CallRecording, audio []byte, mimeType string) error {
+	return f(ctx, recording, audio, mimeType)
+}
+
+func TestFreeSWITCH_RecordStop_DeliversCallRecording(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/calls/call-1.wav", r.URL.Path)
+		w.Write([]byte("RIFF-call"))
+	}))
+	defer server.Close()
+
+	delivered := make(chan *CallRecording, 1)
+	provider := NewFreeSWITCHProvider()
+	provider.config.TranscribeCalls = true
+	provider.config.DefaultLanguage = "pt-BR"
+	provider.config.Credentials = map[string]string{
+		"recordings_url":  server.URL,
+		"recordings_path": "/var/recordings",
+	}
+	provider.SetRecordingHandler(recordingHandlerFunc(func(ctx context.Context, recording *CallRecording, audio []byte, mimeType string) error {
+		assert.Equal(t, "RIFF-call", string(audio))
+		assert.Equal(t, "audio/wav", mimeType)
+		delivered <- recording
+		return nil
+	}))
+
+	provider.handleESLEvent(&ESLEvent{Headers: map[string]string{
+		"Event-Name":                "CHANNEL_CREATE",
+		"Unique-ID":                 "call-1",
+		"Caller-Caller-ID-Number":   "+5511999990000",
+		"Caller-Destination-Number": "+551130000000",
+		"Call-Direction":            "inbound",
+	}})
+	provider.handleESLEvent(&ESLEvent{Headers: map[string]string{
+		"Event-Name":              "RECORD_STOP",
+		"Unique-ID":               "call-1",
+		"Record-File-Path":        "/var/recordings/calls/call-1.wav",
+		"variable_record_seconds": "95",
+	}})
+
+	select {
+	case recording := <-delivered:
+		assert.Equal(t, "call-1", recording.CallID)
+		assert.Equal(t, CallDirectionInbound, recording.Direction)
+		assert.Equal(t, "+5511999990000", recording.From)
+		assert.Equal(t, "+551130000000", recording.To)
+		assert.Equal(t, "pt-BR", recording.Language)
+		assert.Equal(t, 95, recording.Duration)
+	case <-time.After(5 * time.Second):
+		t.Fatal("recording was not delivered")
+	}
+}
+
+func TestFreeSWITCH_RecordStop_SkipsWhenNotTranscribing(t *testing.T) {
+	provider := NewFreeSWITCHProvider()
+	provider.SetRecordingHandler(recordingHandlerFunc(func(ctx context.Context, recording *CallRecording, audio []byte, mimeType string) error {
+		t.Error("recordings are not delivered unless the channel transcribes calls")
+		return nil
+	}))
+	provider.handleESLEvent(&ESLEvent{Headers: map[string]string{
+		"Event-Name":       "RECORD_STOP",
+		"Unique-ID":        "call-1",
+		"Record-File-Path": "/var/recordings/call-1.wav",
+	}})
+}
+
+func TestTwilio_RecordingStatusCallback_DeliversCallRecording(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "ACtest", user)
+		assert.Equal(t, "token", pass)
+
+		switch r.URL.Path {
+		case "/Accounts/ACtest/Calls/CA123.json":
+			w.Write([]byte(`{"sid":"CA123","direction":"inbound","from":"+5511999990000","to":"+551130000000"}`))
+		case "/Accounts/ACtest/Recordings/RE123.wav":
+			w.Write([]byte("RIFF-call"))
+		default:
+			t.Errorf("unexpected request to %s", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	delivered := make(chan *CallRecording, 1)
+	provider := NewTwilioProvider()
+	provider.baseURL = server.URL
+	provider.accountSID = "ACtest"
+	provider.authToken = "token"
+	provider.config.TranscribeCalls = true
+	provider.config.DefaultLanguage = "pt-BR"
+	provider.SetRecordingHandler(recordingHandlerFunc(func(ctx context.Context, recording *CallRecording, audio []byte, mimeType string) error {
+		assert.Equal(t, "RIFF-call", string(audio))
+		assert.Equal(t, "audio/wav", mimeType)
+		delivered <- recording
+		return nil
+	}))
+
+	body := url.Values{
+		"CallSid":           {"CA123"},
+		"RecordingSid":      {"RE123"},
+		"RecordingUrl":      {server.URL + "/Accounts/ACtest/Recordings/RE123"},
+		"RecordingStatus":   {"completed"},
+		"RecordingDuration": {"95"},
+	}.Encode()
+	event, err := provider.ParseWebhook(context.Background(), nil, []byte(body))
+	assert.NoError(t, err)
+	assert.Equal(t, "recording", event.Type)
+	assert.Equal(t, 95, event.Duration)
+
+	select {
+	case recording := <-delivered:
+		assert.Equal(t, "CA123", recording.CallID)
+		assert.Equal(t, CallDirectionInbound, recording.Direction)
+		assert.Equal(t, "+5511999990000", recording.From)
+		assert.Equal(t, "+551130000000", recording.To)
+		assert.Equal(t, "pt-BR", recording.Language)
+		assert.Equal(t, 95, recording.Duration)
+	case <-time.After(5 * time.Second):
+		t.Fatal("recording was not delivered")
+	}
+}
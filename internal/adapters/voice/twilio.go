@@ -6,7 +6,9 @@ import (
 	"crypto/sha1"
 	"encoding/base64"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"sort"
@@ -22,6 +24,8 @@ type TwilioProvider struct {
 	phoneNumber string
 	baseURL     string
 	httpClient  *http.Client
+	config      VoiceConfig
+	recordings  RecordingHandler
 }
 
 // NewTwilioProvider creates a new Twilio Voice provider
@@ -52,6 +56,7 @@ func (p *TwilioProvider) Initialize(ctx context.Context, config VoiceConfig) err
 	p.accountSID = accountSID
 	p.authToken = authToken
 	p.phoneNumber = config.PhoneNumber
+	p.config = config
 
 	return nil
 }
@@ -93,6 +98,11 @@ func (p *TwilioProvider) MakeCall(ctx context.Context, input MakeCallInput) (*Ma
 	}
 	if input.Record {
 		data.Set("Record", "true")
+		if input.StatusURL != "" {
+			// Recordings are ready a while after the call ends
+			data.Set("RecordingStatusCallback", input.StatusURL)
+			data.Set("RecordingStatusCallbackEvent", "completed")
+		}
 	}
 	if input.Timeout > 0 {
 		data.Set("Timeout", strconv.Itoa(input.Timeout))
@@ -246,7 +256,7 @@ func (p *TwilioProvider) TransferCall(ctx context.Context, callID, destination s
 	endpoint := fmt.Sprintf("%s/Accounts/%s/Calls/%s.json", p.baseURL, p.accountSID, callID)
 
 	// Generate TwiML for transfer
-	twiml := fmt.Sprintf(`<Response><Dial>%s</Dial></Response>`, destination)
+	twiml := fmt.Sprintf(`<Response><Dial>%s</Dial></Response>`, twimlEscape(destination))
 
 	data := url.Values{}
 	data.Set("Twiml", twiml)
@@ -292,6 +302,9 @@ func (p *TwilioProvider) GetRecording(ctx context.Context, recordingID string) (
 	if resp.StatusCode == 404 {
 		return nil, fmt.Errorf("recording not found: %s", recordingID)
 	}
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("failed to get recording: status %d", resp.StatusCode)
+	}
 
 	var recResp struct {
 		SID         string `json:"sid"`
@@ -308,7 +321,7 @@ func (p *TwilioProvider) GetRecording(ctx context.Context, recordingID string) (
 	recording := &Recording{
 		ID:       recResp.SID,
 		CallID:   recResp.CallSID,
-		URL:      fmt.Sprintf("https://api.twilio.com/2010-04-01/Accounts/%s/Recordings/%s.mp3", p.accountSID, recResp.SID),
+		URL:      p.recordingURL(recResp.SID, "mp3"),
 		Duration: duration,
 		Format:   "mp3",
 	}
@@ -346,6 +359,88 @@ func (p *TwilioProvider) DeleteRecording(ctx context.Context, recordingID string
 	return nil
 }
 
+// DownloadRecording fetches the audio of a recording in the given format, "wav" or
+// "mp3", and returns it with its MIME type
+func (p *TwilioProvider) DownloadRecording(ctx context.Context, recordingID, format string) ([]byte, string, error) {
+	mimeType := "audio/mpeg"
+	if format == "wav" {
+		mimeType = "audio/wav"
+	} else {
+		format = "mp3"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", p.recordingURL(recordingID, format), nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	req.SetBasicAuth(p.accountSID, p.authToken)
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 404 {
+		return nil, "", fmt.Errorf("recording not found: %s", recordingID)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, "", fmt.Errorf("failed to download recording: status %d", resp.StatusCode)
+	}
+
+	audio, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return audio, mimeType, nil
+}
+
+// recordingURL returns where the audio of a recording is served in a format
+func (p *TwilioProvider) recordingURL(recordingID, format string) string {
+	return fmt.Sprintf("%s/Accounts/%s/Recordings/%s.%s", p.baseURL, p.accountSID, recordingID, format)
+}
+
+// SetRecordingHandler sets where call recordings are delivered
+func (p *TwilioProvider) SetRecordingHandler(handler RecordingHandler) {
+	p.recordings = handler
+}
+
+// deliverRecording hands a completed call recording to the recording handler. The
+// recording status callback only names the call, so its parties are looked up.
+func (p *TwilioProvider) deliverRecording(callID, recordingID string, duration int) {
+	if p.recordings == nil || !p.config.TranscribeCalls || recordingID == "" {
+		return
+	}
+
+	recording := &CallRecording{
+		CallID:     callID,
+		Language:   p.config.DefaultLanguage,
+		Path:       p.recordingURL(recordingID, "wav"),
+		Duration:   duration,
+		RecordedAt: time.Now(),
+	}
+	handler := p.recordings
+
+	go func() {
+		// Transcribing a long call takes a while
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+
+		if call, err := p.GetCall(ctx, callID); err == nil {
+			recording.Direction = call.Direction
+			recording.From = call.From
+			recording.To = call.To
+		}
+
+		audio, mimeType, err := p.DownloadRecording(ctx, recordingID, "wav")
+		if err != nil {
+			return
+		}
+		_ = handler.HandleRecording(ctx, recording, audio, mimeType)
+	}()
+}
+
 // GenerateIVRResponse generates TwiML response
 func (p *TwilioProvider) GenerateIVRResponse(actions []IVRAction) (interface{}, error) {
 	var twiml strings.Builder
@@ -354,14 +449,14 @@ func (p *TwilioProvider) GenerateIVRResponse(actions []IVRAction) (interface{},
 	for _, action := range actions {
 		switch a := action.(type) {
 		case IVRSay:
-			twiml.WriteString(fmt.Sprintf("  <Say language=\"%s\"", a.Language))
+			twiml.WriteString(fmt.Sprintf("  <Say language=\"%s\"", twimlEscape(a.Language)))
 			if a.Voice != "" {
-				twiml.WriteString(fmt.Sprintf(" voice=\"%s\"", a.Voice))
+				twiml.WriteString(fmt.Sprintf(" voice=\"%s\"", twimlEscape(a.Voice)))
 			}
 			if a.Loop > 0 {
 				twiml.WriteString(fmt.Sprintf(" loop=\"%d\"", a.Loop))
 			}
-			twiml.WriteString(fmt.Sprintf(">%s</Say>\n", a.Text))
+			twiml.WriteString(fmt.Sprintf(">%s</Say>\n", twimlEscape(a.Text)))
 
 		case IVRPlay:
 			twiml.WriteString("  <Play")
@@ -369,14 +464,14 @@ func (p *TwilioProvider) GenerateIVRResponse(actions []IVRAction) (interface{},
 				twiml.WriteString(fmt.Sprintf(" loop=\"%d\"", a.Loop))
 			}
 			if a.Digits != "" {
-				twiml.WriteString(fmt.Sprintf(" digits=\"%s\"", a.Digits))
+				twiml.WriteString(fmt.Sprintf(" digits=\"%s\"", twimlEscape(a.Digits)))
 			}
-			twiml.WriteString(fmt.Sprintf(">%s</Play>\n", a.URL))
+			twiml.WriteString(fmt.Sprintf(">%s</Play>\n", twimlEscape(a.URL)))
 
 		case IVRGather:
 			twiml.WriteString("  <Gather")
 			if len(a.Input) > 0 {
-				twiml.WriteString(fmt.Sprintf(" input=\"%s\"", strings.Join(a.Input, " ")))
+				twiml.WriteString(fmt.Sprintf(" input=\"%s\"", twimlEscape(strings.Join(a.Input, " "))))
 			}
 			if a.Timeout > 0 {
 				twiml.WriteString(fmt.Sprintf(" timeout=\"%d\"", a.Timeout))
@@ -385,25 +480,25 @@ func (p *TwilioProvider) GenerateIVRResponse(actions []IVRAction) (interface{},
 				twiml.WriteString(fmt.Sprintf(" numDigits=\"%d\"", a.NumDigits))
 			}
 			if a.FinishOnKey != "" {
-				twiml.WriteString(fmt.Sprintf(" finishOnKey=\"%s\"", a.FinishOnKey))
+				twiml.WriteString(fmt.Sprintf(" finishOnKey=\"%s\"", twimlEscape(a.FinishOnKey)))
 			}
 			if a.ActionURL != "" {
-				twiml.WriteString(fmt.Sprintf(" action=\"%s\"", a.ActionURL))
+				twiml.WriteString(fmt.Sprintf(" action=\"%s\"", twimlEscape(a.ActionURL)))
 			}
 			if a.Language != "" {
-				twiml.WriteString(fmt.Sprintf(" language=\"%s\"", a.Language))
+				twiml.WriteString(fmt.Sprintf(" language=\"%s\"", twimlEscape(a.Language)))
 			}
 			if len(a.Hints) > 0 {
-				twiml.WriteString(fmt.Sprintf(" hints=\"%s\"", strings.Join(a.Hints, ",")))
+				twiml.WriteString(fmt.Sprintf(" hints=\"%s\"", twimlEscape(strings.Join(a.Hints, ","))))
 			}
 			twiml.WriteString(">\n")
 
 			// Nested actions
 			for _, nested := range a.Nested {
 				if say, ok := nested.(IVRSay); ok {
-					twiml.WriteString(fmt.Sprintf("    <Say language=\"%s\">%s</Say>\n", say.Language, say.Text))
+					twiml.WriteString(fmt.Sprintf("    <Say language=\"%s\">%s</Say>\n", twimlEscape(say.Language), twimlEscape(say.Text)))
 				} else if play, ok := nested.(IVRPlay); ok {
-					twiml.WriteString(fmt.Sprintf("    <Play>%s</Play>\n", play.URL))
+					twiml.WriteString(fmt.Sprintf("    <Play>%s</Play>\n", twimlEscape(play.URL)))
 				}
 			}
 
@@ -418,7 +513,7 @@ func (p *TwilioProvider) GenerateIVRResponse(actions []IVRAction) (interface{},
 				twiml.WriteString(fmt.Sprintf(" timeout=\"%d\"", a.Timeout))
 			}
 			if a.FinishOnKey != "" {
-				twiml.WriteString(fmt.Sprintf(" finishOnKey=\"%s\"", a.FinishOnKey))
+				twiml.WriteString(fmt.Sprintf(" finishOnKey=\"%s\"", twimlEscape(a.FinishOnKey)))
 			}
 			if a.Transcribe {
 				twiml.WriteString(" transcribe=\"true\"")
@@ -427,7 +522,7 @@ func (p *TwilioProvider) GenerateIVRResponse(actions []IVRAction) (interface{},
 				twiml.WriteString(" playBeep=\"true\"")
 			}
 			if a.ActionURL != "" {
-				twiml.WriteString(fmt.Sprintf(" action=\"%s\"", a.ActionURL))
+				twiml.WriteString(fmt.Sprintf(" action=\"%s\"", twimlEscape(a.ActionURL)))
 			}
 			twiml.WriteString("/>\n")
 
@@ -437,21 +532,21 @@ func (p *TwilioProvider) GenerateIVRResponse(actions []IVRAction) (interface{},
 				twiml.WriteString(fmt.Sprintf(" timeout=\"%d\"", a.Timeout))
 			}
 			if a.CallerID != "" {
-				twiml.WriteString(fmt.Sprintf(" callerId=\"%s\"", a.CallerID))
+				twiml.WriteString(fmt.Sprintf(" callerId=\"%s\"", twimlEscape(a.CallerID)))
 			}
 			if a.Record {
 				twiml.WriteString(" record=\"record-from-answer\"")
 			}
 			if a.ActionURL != "" {
-				twiml.WriteString(fmt.Sprintf(" action=\"%s\"", a.ActionURL))
+				twiml.WriteString(fmt.Sprintf(" action=\"%s\"", twimlEscape(a.ActionURL)))
 			}
 			twiml.WriteString(">")
 			if a.Number != "" {
-				twiml.WriteString(a.Number)
+				twiml.WriteString(twimlEscape(a.Number))
 			} else if a.SIPEndpoint != "" {
-				twiml.WriteString(fmt.Sprintf("<Sip>%s</Sip>", a.SIPEndpoint))
+				twiml.WriteString(fmt.Sprintf("<Sip>%s</Sip>", twimlEscape(a.SIPEndpoint)))
 			} else if a.Queue != "" {
-				twiml.WriteString(fmt.Sprintf("<Queue>%s</Queue>", a.Queue))
+				twiml.WriteString(fmt.Sprintf("<Queue>%s</Queue>", twimlEscape(a.Queue)))
 			}
 			twiml.WriteString("</Dial>\n")
 
@@ -462,17 +557,17 @@ func (p *TwilioProvider) GenerateIVRResponse(actions []IVRAction) (interface{},
 			twiml.WriteString(fmt.Sprintf("  <Pause length=\"%d\"/>\n", a.Length))
 
 		case IVRRedirect:
-			twiml.WriteString(fmt.Sprintf("  <Redirect method=\"%s\">%s</Redirect>\n", a.Method, a.URL))
+			twiml.WriteString(fmt.Sprintf("  <Redirect method=\"%s\">%s</Redirect>\n", twimlEscape(a.Method), twimlEscape(a.URL)))
 
 		case IVRQueue:
 			twiml.WriteString("  <Enqueue")
 			if a.WaitURL != "" {
-				twiml.WriteString(fmt.Sprintf(" waitUrl=\"%s\"", a.WaitURL))
+				twiml.WriteString(fmt.Sprintf(" waitUrl=\"%s\"", twimlEscape(a.WaitURL)))
 			}
 			if a.ActionURL != "" {
-				twiml.WriteString(fmt.Sprintf(" action=\"%s\"", a.ActionURL))
+				twiml.WriteString(fmt.Sprintf(" action=\"%s\"", twimlEscape(a.ActionURL)))
 			}
-			twiml.WriteString(fmt.Sprintf(">%s</Enqueue>\n", a.Name))
+			twiml.WriteString(fmt.Sprintf(">%s</Enqueue>\n", twimlEscape(a.Name)))
 
 		case IVRConference:
 			twiml.WriteString("  <Dial><Conference")
@@ -486,7 +581,7 @@ func (p *TwilioProvider) GenerateIVRResponse(actions []IVRAction) (interface{},
 				twiml.WriteString(" endConferenceOnExit=\"true\"")
 			}
 			if a.WaitURL != "" {
-				twiml.WriteString(fmt.Sprintf(" waitUrl=\"%s\"", a.WaitURL))
+				twiml.WriteString(fmt.Sprintf(" waitUrl=\"%s\"", twimlEscape(a.WaitURL)))
 			}
 			if a.MaxParticipants > 0 {
 				twiml.WriteString(fmt.Sprintf(" maxParticipants=\"%d\"", a.MaxParticipants))
@@ -494,7 +589,7 @@ func (p *TwilioProvider) GenerateIVRResponse(actions []IVRAction) (interface{},
 			if a.Record {
 				twiml.WriteString(" record=\"record-from-start\"")
 			}
-			twiml.WriteString(fmt.Sprintf(">%s</Conference></Dial>\n", a.Name))
+			twiml.WriteString(fmt.Sprintf(">%s</Conference></Dial>\n", twimlEscape(a.Name)))
 		}
 	}
 
@@ -526,6 +621,13 @@ func (p *TwilioProvider) ParseWebhook(ctx context.Context, headers map[string]st
 		}
 	}
 
+	// Status callbacks carry when the event happened
+	if timestamp := values.Get("Timestamp"); timestamp != "" {
+		if t, err := time.Parse(time.RFC1123Z, timestamp); err == nil {
+			event.Timestamp = t
+		}
+	}
+
 	// Determine event type and status
 	callStatus := values.Get("CallStatus")
 	event.Status = p.mapStatus(callStatus)
@@ -540,6 +642,12 @@ func (p *TwilioProvider) ParseWebhook(ctx context.Context, headers map[string]st
 	} else if recordingURL := values.Get("RecordingUrl"); recordingURL != "" {
 		event.Type = "recording"
 		event.RecordingURL = recordingURL
+		if duration, err := strconv.Atoi(values.Get("RecordingDuration")); err == nil {
+			event.Duration = duration
+		}
+		if values.Get("RecordingStatus") == "completed" {
+			p.deliverRecording(event.CallID, values.Get("RecordingSid"), event.Duration)
+		}
 	} else if transcription := values.Get("TranscriptionText"); transcription != "" {
 		event.Type = "transcription"
 		event.Transcription = transcription
@@ -611,6 +719,13 @@ func (p *TwilioProvider) ValidateWebhook(ctx context.Context, headers map[string
 	return hmac.Equal([]byte(signature), []byte(expectedSignature))
 }
 
+// twimlEscape escapes text and attribute values placed in TwiML
+func twimlEscape(value string) string {
+	var escaped strings.Builder
+	_ = xml.EscapeText(&escaped, []byte(value))
+	return escaped.String()
+}
+
 // mapStatus maps Twilio status to CallStatus
 func (p *TwilioProvider) mapStatus(status string) CallStatus {
 	switch strings.ToLower(status) {
@@ -1044,3 +1044,68 @@ func TestTwilioProvider_GenerateIVRResponse_XMLStructure(t *testing.T) {
 	assert.True(t, strings.Contains(twiml, "<Response>"))
 	assert.True(t, strings.HasSuffix(twiml, "</Response>"))
 }
+
+func TestTwilioProvider_GenerateIVRResponse_EscapesText(t *testing.T) {
+	p := NewTwilioProvider()
+
+	resp, err := p.GenerateIVRResponse([]IVRAction{
+		IVRSay{Text: "Sales & <Support>", Language: "en-US"},
+		IVRPlay{URL: "https://example.com/hold.mp3?a=1&b=2"},
+	})
+	require.NoError(t, err)
+
+	twiml := resp.(string)
+	assert.Contains(t, twiml, ">Sales &amp; &lt;Support&gt;</Say>")
+	assert.Contains(t, twiml, ">https://example.com/hold.mp3?a=1&amp;b=2</Play>")
+}
+
+func TestTwilioProvider_MakeCall_RecordingStatusCallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "true", r.FormValue("Record"))
+		assert.Equal(t, "https://example.com/voice/status", r.FormValue("RecordingStatusCallback"))
+		assert.Equal(t, "completed", r.FormValue("RecordingStatusCallbackEvent"))
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{"sid": "CA_rec", "status": "queued"})
+	}))
+	defer server.Close()
+
+	p := NewTwilioProvider()
+	p.baseURL = server.URL
+	p.accountSID = "ACtest"
+	p.authToken = "testtoken"
+
+	_, err := p.MakeCall(context.Background(), MakeCallInput{
+		To:        "+5511999999999",
+		From:      "+5511888888888",
+		StatusURL: "https://example.com/voice/status",
+		Record:    true,
+	})
+	require.NoError(t, err)
+}
+
+func TestTwilioProvider_DownloadRecording(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/Accounts/ACtest/Recordings/RE_test.mp3" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("ID3-audio"))
+	}))
+	defer server.Close()
+
+	p := NewTwilioProvider()
+	p.baseURL = server.URL
+	p.accountSID = "ACtest"
+	p.authToken = "testtoken"
+
+	audio, mimeType, err := p.DownloadRecording(context.Background(), "RE_test", "mp3")
+	require.NoError(t, err)
+	assert.Equal(t, "ID3-audio", string(audio))
+	assert.Equal(t, "audio/mpeg", mimeType)
+
+	_, _, err = p.DownloadRecording(context.Background(), "RE_missing", "wav")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "recording not found")
+}
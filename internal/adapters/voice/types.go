@@ -163,6 +163,7 @@ type IVRQueue struct {
 	WaitURL    string `json:"waitUrl,omitempty"`
 	ActionURL  string `json:"actionUrl,omitempty"`
 	MaxWait    int    `json:"maxWait,omitempty"` // max wait time in seconds
+	CallbackDigit string `json:"callbackDigit,omitempty"` // DTMF digit to leave the queue and request a callback
 }
 
 func (a IVRQueue) ActionType() string { return "queue" }
@@ -0,0 +1,156 @@
+package voice
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// defaultVoicemailMaxLength is how long a voicemail can be when the queue sets no limit
+const defaultVoicemailMaxLength = 2 * time.Minute
+
+// Voicemail is a message left by a caller no agent answered in time
+type Voicemail struct {
+	ID            string        `json:"id"`
+	CallID        string        `json:"callId"`
+	Queue         string        `json:"queue"`
+	From          string        `json:"from"`
+	CallerName    string        `json:"callerName,omitempty"`
+	Language      string        `json:"language,omitempty"`
+	WaitedFor     time.Duration `json:"waitedFor"` // Time in the queue before the voicemail prompt
+	RecordingPath string        `json:"recordingPath,omitempty"`
+	Duration      int           `json:"duration"` // in seconds
+	RequestedAt   time.Time     `json:"requestedAt"`
+	RecordedAt    *time.Time    `json:"recordedAt,omitempty"`
+}
+
+// VoicemailHandler receives recorded voicemails with their audio, to store,
+// transcribe and post them to the caller's conversation
+type VoicemailHandler interface {
+	HandleVoicemail(ctx context.Context, voicemail *Voicemail, audio []byte, mimeType string) error
+}
+
+// SetVoicemailHandler sets where recorded voicemails are delivered
+func (m *QueueManager) SetVoicemailHandler(handler VoicemailHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.voicemailHandler = handler
+}
+
+// DueVoicemails takes the calls that waited longer than their queue's voicemail SLA
+// out of the queue and returns the voicemails they are about to record
+func (m *QueueManager) DueVoicemails(now time.Time) []*Voicemail {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var due []*QueueEntry
+	for _, entry := range m.entries {
+		config := m.queues[entry.Queue]
+		if config == nil || config.VoicemailAfter <= 0 || now.Sub(entry.EnqueuedAt) < config.VoicemailAfter {
+			continue
+		}
+		due = append(due, entry)
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].seq < due[j].seq })
+
+	voicemails := make([]*Voicemail, 0, len(due))
+	for _, entry := range due {
+		delete(m.entries, entry.CallID)
+		voicemail := &Voicemail{
+			ID:          uuid.New().String(),
+			CallID:      entry.CallID,
+			Queue:       entry.Queue,
+			From:        entry.From,
+			CallerName:  entry.CallerName,
+			Language:    m.queues[entry.Queue].Language,
+			WaitedFor:   now.Sub(entry.EnqueuedAt),
+			RequestedAt: now,
+		}
+		m.voicemails[entry.CallID] = voicemail
+		copied := *voicemail
+		voicemails = append(voicemails, &copied)
+	}
+	return voicemails
+}
+
+// PendingVoicemail returns the voicemail a call is recording
+func (m *QueueManager) PendingVoicemail(callID string) (*Voicemail, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	voicemail, ok := m.voicemails[callID]
+	if !ok {
+		return nil, false
+	}
+	copied := *voicemail
+	return &copied, true
+}
+
+// RecordedVoicemail takes the voicemail of a call out of the pending ones once its
+// recording stopped
+func (m *QueueManager) RecordedVoicemail(callID, recordingPath string, duration int) (*Voicemail, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	voicemail, ok := m.voicemails[callID]
+	if !ok {
+		return nil, false
+	}
+	delete(m.voicemails, callID)
+
+	now := time.Now()
+	voicemail.RecordingPath = recordingPath
+	voicemail.Duration = duration
+	voicemail.RecordedAt = &now
+	return voicemail, true
+}
+
+// DeliverVoicemail hands a recorded voicemail to the voicemail handler. Empty
+// recordings, left by callers who hung up at the beep, are dropped.
+func (m *QueueManager) DeliverVoicemail(ctx context.Context, voicemail *Voicemail, audio []byte, mimeType string) error {
+	m.mu.RLock()
+	handler := m.voicemailHandler
+	m.mu.RUnlock()
+
+	if handler == nil || len(audio) == 0 {
+		return nil
+	}
+	if err := handler.HandleVoicemail(ctx, voicemail, audio, mimeType); err != nil {
+		return fmt.Errorf("failed to handle voicemail: %w", err)
+	}
+	return nil
+}
+
+// CancelVoicemail forgets the voicemail of a call that hung up before recording
+func (m *QueueManager) CancelVoicemail(callID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.voicemails, callID)
+}
+
+// BuildVoicemailPrompt creates IVR actions telling the caller no agent is available
+// and recording their message
+func BuildVoicemailPrompt(maxLength time.Duration, language string) []IVRAction {
+	if language == "" {
+		language = "pt-BR"
+	}
+	if maxLength <= 0 {
+		maxLength = defaultVoicemailMaxLength
+	}
+	return []IVRAction{
+		IVRSay{
+			Text:     "Nossos atendentes estão ocupados no momento. Deixe sua mensagem após o sinal e retornaremos o contato.",
+			Language: language,
+		},
+		IVRRecord{
+			MaxLength:   int(maxLength / time.Second),
+			Timeout:     5,
+			FinishOnKey: "#",
+			PlayBeep:    true,
+		},
+		IVRHangup{},
+	}
+}
@@ -0,0 +1,144 @@
+package voice
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type voicemailHandlerFunc func(ctx context.Context, voicemail *Voicemail, audio []byte, mimeType string) error
+
+func (f voicemailHandlerFunc) HandleVoicemail(ctx context.Context, voicemail *Voicemail, audio []byte, mimeType string) error {
+	return f(ctx, voicemail, audio, mimeType)
+}
+
+func newVoicemailQueueManager(t *testing.T) *QueueManager {
+	manager := NewQueueManager()
+	require.NoError(t, manager.RegisterQueue(QueueConfig{
+		Name:           "support",
+		Language:       "pt-BR",
+		VoicemailAfter: 3 * time.Minute,
+	}))
+	return manager
+}
+
+func TestQueueManager_DueVoicemails(t *testing.T) {
+	manager := newVoicemailQueueManager(t)
+	_, err := manager.Enqueue("support", &Call{ID: "call-1", From: "+5511111111111", CallerName: "Maria"})
+	require.NoError(t, err)
+
+	now := time.Now()
+	assert.Empty(t, manager.DueVoicemails(now))
+
+	due := manager.DueVoicemails(now.Add(3*time.Minute + time.Second))
+	require.Len(t, due, 1)
+	assert.Equal(t, "call-1", due[0].CallID)
+	assert.Equal(t, "+5511111111111", due[0].From)
+	assert.Equal(t, "Maria", due[0].CallerName)
+	assert.Equal(t, "pt-BR", due[0].Language)
+
+	_, ok := manager.Entry("call-1")
+	assert.False(t, ok, "calls sent to voicemail leave the queue")
+	_, ok = manager.PendingVoicemail("call-1")
+	assert.True(t, ok)
+
+	config, _ := manager.Queue("support")
+	assert.Equal(t, defaultVoicemailMaxLength, config.VoicemailMaxLength)
+}
+
+func TestQueueManager_DeliverVoicemail(t *testing.T) {
+	manager := newVoicemailQueueManager(t)
+	var delivered []*Voicemail
+	manager.SetVoicemailHandler(voicemailHandlerFunc(func(ctx context.Context, voicemail *Voicemail, audio []byte, mimeType string) error {
+		assert.Equal(t, "audio/wav", mimeType)
+		delivered = append(delivered, voicemail)
+		return nil
+	}))
+
+	_, err := manager.Enqueue("support", &Call{ID: "call-1"})
+	require.NoError(t, err)
+	_, err = manager.Enqueue("support", &Call{ID: "call-2"})
+	require.NoError(t, err)
+	require.Len(t, manager.DueVoicemails(time.Now().Add(time.Hour)), 2)
+
+	voicemail, ok := manager.RecordedVoicemail("call-1", "/recordings/voicemail/call-1.wav", 12)
+	require.True(t, ok)
+	assert.Equal(t, 12, voicemail.Duration)
+	require.NoError(t, manager.DeliverVoicemail(context.Background(), voicemail, []byte("RIFF"), "audio/wav"))
+	require.Len(t, delivered, 1)
+	assert.Equal(t, "/recordings/voicemail/call-1.wav", delivered[0].RecordingPath)
+
+	_, ok = manager.RecordedVoicemail("call-1", "/recordings/voicemail/call-1.wav", 12)
+	assert.False(t, ok, "voicemails are delivered once")
+
+	// Callers hanging up at the beep leave nothing to deliver
+	manager.CancelVoicemail("call-2")
+	_, ok = manager.RecordedVoicemail("call-2", "/recordings/voicemail/call-2.wav", 0)
+	assert.False(t, ok)
+	require.NoError(t, manager.DeliverVoicemail(context.Background(), voicemail, nil, "audio/wav"))
+	assert.Len(t, delivered, 1)
+}
+
+func TestFreeSWITCH_RecordStop_DeliversVoicemail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/voicemail/call-1.wav", r.URL.Path)
+		w.Write([]byte("RIFF-voicemail"))
+	}))
+	defer server.Close()
+
+	manager := newVoicemailQueueManager(t)
+	delivered := make(chan []byte, 1)
+	manager.SetVoicemailHandler(voicemailHandlerFunc(func(ctx context.Context, voicemail *Voicemail, audio []byte, mimeType string) error {
+		assert.Equal(t, "call-1", voicemail.CallID)
+		assert.Equal(t, 20, voicemail.Duration)
+		delivered <- audio
+		return nil
+	}))
+
+	provider := NewFreeSWITCHProvider()
+	provider.config.Credentials = map[string]string{
+		"recordings_url":  server.URL,
+		"recordings_path": "/var/recordings",
+	}
+	provider.SetQueueManager(manager)
+
+	_, err := manager.Enqueue("support", &Call{ID: "call-1"})
+	require.NoError(t, err)
+	require.Len(t, manager.DueVoicemails(time.Now().Add(time.Hour)), 1)
+
+	// Other recordings of the call are not voicemails
+	provider.handleESLEvent(&ESLEvent{Headers: map[string]string{
+		"Event-Name":       "RECORD_STOP",
+		"Unique-ID":        "call-1",
+		"Record-File-Path": "/var/recordings/call-1.wav",
+	}})
+	_, ok := manager.PendingVoicemail("call-1")
+	assert.True(t, ok)
+
+	provider.handleESLEvent(&ESLEvent{Headers: map[string]string{
+		"Event-Name":              "RECORD_STOP",
+		"Unique-ID":               "call-1",
+		"Record-File-Path":        "/var/recordings/voicemail/call-1.wav",
+		"variable_record_seconds": "20",
+	}})
+
+	select {
+	case audio := <-delivered:
+		assert.Equal(t, "RIFF-voicemail", string(audio))
+	case <-time.After(5 * time.Second):
+		t.Fatal("voicemail was not delivered")
+	}
+}
+
+func TestBuildVoicemailPrompt(t *testing.T) {
+	actions := BuildVoicemailPrompt(0, "")
+	require.Len(t, actions, 3)
+	assert.Equal(t, "pt-BR", actions[0].(IVRSay).Language)
+	assert.Equal(t, 120, actions[1].(IVRRecord).MaxLength)
+	assert.IsType(t, IVRHangup{}, actions[2])
+}
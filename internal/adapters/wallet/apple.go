@@ -0,0 +1,320 @@
+package wallet
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha1"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ApplePassMIMEType is the content type of .pkpass files
+const ApplePassMIMEType = "application/vnd.apple.pkpass"
+
+const (
+	defaultAPNsURL  = "https://api.push.apple.com"
+	appleDateLayout = "2006-01-02T15:04:05Z07:00"
+)
+
+// AppleConfig holds the Pass Type ID certificate passes are signed with
+type AppleConfig struct {
+	PassTypeID  string
+	TeamID      string
+	Certificate *x509.Certificate
+	PrivateKey  crypto.Signer
+	WWDR        *x509.Certificate // Apple Worldwide Developer Relations intermediate
+	Icon        []byte            // icon.png every pass must include
+	APNsURL     string
+}
+
+// LoadAppleConfig reads the PEM encoded pass certificate, its private key, the WWDR
+// intermediate and the PNG icon from files
+func LoadAppleConfig(passTypeID, teamID, certFile, keyFile, wwdrFile, iconFile string) (*AppleConfig, error) {
+	certificate, err := readCertificate(certFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pass certificate: %w", err)
+	}
+	wwdr, err := readCertificate(wwdrFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read WWDR certificate: %w", err)
+	}
+	keyPEM, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pass key: %w", err)
+	}
+	key, err := parsePrivateKey(keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pass key: %w", err)
+	}
+	icon, err := os.ReadFile(iconFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pass icon: %w", err)
+	}
+
+	return &AppleConfig{
+		PassTypeID:  passTypeID,
+		TeamID:      teamID,
+		Certificate: certificate,
+		PrivateKey:  key,
+		WWDR:        wwdr,
+		Icon:        icon,
+	}, nil
+}
+
+// AppleIssuer builds signed .pkpass files and tells devices holding a pass that it
+// changed
+type AppleIssuer struct {
+	config     AppleConfig
+	httpClient *http.Client
+}
+
+// NewAppleIssuer creates a new Apple Wallet issuer
+func NewAppleIssuer(config AppleConfig) *AppleIssuer {
+	if config.APNsURL == "" {
+		config.APNsURL = defaultAPNsURL
+	}
+
+	tlsConfig := &tls.Config{}
+	if config.Certificate != nil && config.PrivateKey != nil {
+		tlsConfig.Certificates = []tls.Certificate{{
+			Certificate: [][]byte{config.Certificate.Raw},
+			PrivateKey:  config.PrivateKey,
+			Leaf:        config.Certificate,
+		}}
+	}
+
+	return &AppleIssuer{
+		config: config,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig:   tlsConfig,
+				ForceAttemptHTTP2: true, // APNs only speaks HTTP/2
+			},
+		},
+	}
+}
+
+// PassTypeID returns the pass type identifier of the issued passes
+func (a *AppleIssuer) PassTypeID() string {
+	return a.config.PassTypeID
+}
+
+// Build creates the signed .pkpass bundle of a pass
+func (a *AppleIssuer) Build(pass *Pass) ([]byte, error) {
+	passJSON, err := json.Marshal(a.passJSON(pass))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode pass: %w", err)
+	}
+
+	files := map[string][]byte{
+		"pass.json":   passJSON,
+		"icon.png":    a.config.Icon,
+		"icon@2x.png": a.config.Icon,
+	}
+	if len(pass.Logo) > 0 {
+		files["logo.png"] = pass.Logo
+		files["logo@2x.png"] = pass.Logo
+	}
+
+	manifest := make(map[string]string, len(files))
+	for name, data := range files {
+		sum := sha1.Sum(data)
+		manifest[name] = hex.EncodeToString(sum[:])
+	}
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	files["manifest.json"] = manifestJSON
+
+	var chain []*x509.Certificate
+	if a.config.WWDR != nil {
+		chain = append(chain, a.config.WWDR)
+	}
+	signature, err := signDetached(manifestJSON, a.config.Certificate, a.config.PrivateKey, chain, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	files["signature"] = signature
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, name := range names {
+		w, err := archive.Create(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add %s: %w", name, err)
+		}
+		if _, err := w.Write(files[name]); err != nil {
+			return nil, fmt.Errorf("failed to add %s: %w", name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write pass bundle: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// passJSON builds the pass.json document of a pass
+func (a *AppleIssuer) passJSON(pass *Pass) map[string]interface{} {
+	doc := map[string]interface{}{
+		"formatVersion":      1,
+		"passTypeIdentifier": a.config.PassTypeID,
+		"teamIdentifier":     a.config.TeamID,
+		"serialNumber":       pass.SerialNumber,
+		"organizationName":   pass.OrganizationName,
+		"description":        pass.Description,
+	}
+	if pass.LogoText != "" {
+		doc["logoText"] = pass.LogoText
+	}
+	for key, color := range map[string]string{
+		"backgroundColor": pass.BackgroundColor,
+		"foregroundColor": pass.ForegroundColor,
+		"labelColor":      pass.LabelColor,
+	} {
+		if rgb := rgbColor(color); rgb != "" {
+			doc[key] = rgb
+		}
+	}
+	if pass.WebServiceURL != "" && pass.AuthToken != "" {
+		doc["webServiceURL"] = pass.WebServiceURL
+		doc["authenticationToken"] = pass.AuthToken
+	}
+	if pass.Barcode.Message != "" {
+		barcode := map[string]string{
+			"format":          appleBarcodeFormat(pass.Barcode.Format),
+			"message":         pass.Barcode.Message,
+			"messageEncoding": "iso-8859-1",
+		}
+		if pass.Barcode.AltText != "" {
+			barcode["altText"] = pass.Barcode.AltText
+		}
+		doc["barcodes"] = []map[string]string{barcode}
+	}
+	if pass.RelevantDate != nil {
+		doc["relevantDate"] = pass.RelevantDate.Format(appleDateLayout)
+	}
+	if pass.ExpiresAt != nil {
+		doc["expirationDate"] = pass.ExpiresAt.Format(appleDateLayout)
+	}
+	if pass.Voided {
+		doc["voided"] = true
+	}
+
+	style := map[string]interface{}{}
+	for position, key := range map[FieldPosition]string{
+		FieldHeader:    "headerFields",
+		FieldPrimary:   "primaryFields",
+		FieldSecondary: "secondaryFields",
+		FieldAuxiliary: "auxiliaryFields",
+		FieldBack:      "backFields",
+	} {
+		fields := fieldsAt(pass.Fields, position)
+		if len(fields) == 0 {
+			continue
+		}
+		entries := make([]map[string]string, 0, len(fields))
+		for _, field := range fields {
+			entry := map[string]string{"key": field.Key, "value": field.Value}
+			if field.Label != "" {
+				entry["label"] = field.Label
+			}
+			entries = append(entries, entry)
+		}
+		style[key] = entries
+	}
+	if pass.Kind == PassKindLoyalty {
+		doc["storeCard"] = style
+	} else {
+		doc["eventTicket"] = style
+	}
+	return doc
+}
+
+// Push tells the device behind a push token that its passes changed. Apple Wallet
+// then asks the web service which passes changed and downloads them.
+func (a *AppleIssuer) Push(ctx context.Context, pushToken string) error {
+	url := fmt.Sprintf("%s/3/device/%s", strings.TrimSuffix(a.config.APNsURL, "/"), pushToken)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader("{}"))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("apns-topic", a.config.PassTypeID)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send pass update push: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("APNs error (%d): %s", resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+func appleBarcodeFormat(format BarcodeFormat) string {
+	switch format {
+	case BarcodePDF417:
+		return "PKBarcodeFormatPDF417"
+	case BarcodeAztec:
+		return "PKBarcodeFormatAztec"
+	case BarcodeCode128:
+		return "PKBarcodeFormatCode128"
+	}
+	return "PKBarcodeFormatQR"
+}
+
+func readCertificate(file string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if block, _ := pem.Decode(data); block != nil {
+		data = block.Bytes
+	}
+	return x509.ParseCertificate(data)
+}
+
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	return signer, nil
+}
@@ -0,0 +1,335 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	defaultGoogleAPIURL   = "https://walletobjects.googleapis.com/walletobjects/v1"
+	defaultGoogleTokenURL = "https://oauth2.googleapis.com/token"
+	googleSaveURL         = "https://pay.google.com/gp/v/save/"
+	googleWalletScope     = "https://www.googleapis.com/auth/wallet_object.issuer"
+)
+
+// GoogleConfig holds the issuer account and the service account passes are signed with
+type GoogleConfig struct {
+	IssuerID            string
+	ServiceAccountEmail string
+	PrivateKey          *rsa.PrivateKey
+	Origins             []string // Sites allowed to show the "Add to Google Wallet" button
+	APIURL              string
+	TokenURL            string
+}
+
+// LoadGoogleConfig reads the key of a service account from its JSON credentials file
+func LoadGoogleConfig(issuerID, credentialsFile string) (*GoogleConfig, error) {
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account: %w", err)
+	}
+	var credentials struct {
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+	}
+	if err := json.Unmarshal(data, &credentials); err != nil {
+		return nil, fmt.Errorf("failed to parse service account: %w", err)
+	}
+
+	block, _ := pem.Decode([]byte(credentials.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("service account has no private key")
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse service account key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("service account key is not an RSA key")
+	}
+
+	return &GoogleConfig{
+		IssuerID:            issuerID,
+		ServiceAccountEmail: credentials.ClientEmail,
+		PrivateKey:          rsaKey,
+	}, nil
+}
+
+// GoogleIssuer creates "Add to Google Wallet" links and updates saved passes
+type GoogleIssuer struct {
+	config     GoogleConfig
+	httpClient *http.Client
+
+	mu          sync.Mutex
+	accessToken string
+	tokenExpiry time.Time
+}
+
+// NewGoogleIssuer creates a new Google Wallet issuer
+func NewGoogleIssuer(config GoogleConfig) *GoogleIssuer {
+	if config.APIURL == "" {
+		config.APIURL = defaultGoogleAPIURL
+	}
+	if config.TokenURL == "" {
+		config.TokenURL = defaultGoogleTokenURL
+	}
+	return &GoogleIssuer{
+		config:     config,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// SaveURL returns the link that adds a pass to Google Wallet. The link carries the
+// pass and its class, so nothing is created until the customer saves it.
+func (g *GoogleIssuer) SaveURL(pass *Pass) (string, error) {
+	classKey, objectKey := "eventTicketClasses", "eventTicketObjects"
+	if pass.Kind == PassKindLoyalty {
+		classKey, objectKey = "loyaltyClasses", "loyaltyObjects"
+	}
+
+	claims := jwt.MapClaims{
+		"iss": g.config.ServiceAccountEmail,
+		"aud": "google",
+		"typ": "savetowallet",
+		"iat": time.Now().Unix(),
+		"payload": map[string]interface{}{
+			classKey:  []interface{}{g.classJSON(pass)},
+			objectKey: []interface{}{g.objectJSON(pass)},
+		},
+	}
+	if len(g.config.Origins) > 0 {
+		claims["origins"] = g.config.Origins
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(g.config.PrivateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign save link: %w", err)
+	}
+	return googleSaveURL + token, nil
+}
+
+// UpdateObject replaces a saved pass with its current content. Passes nobody saved
+// yet do not exist at Google and are skipped.
+func (g *GoogleIssuer) UpdateObject(ctx context.Context, pass *Pass) error {
+	body, err := json.Marshal(g.objectJSON(pass))
+	if err != nil {
+		return fmt.Errorf("failed to encode pass: %w", err)
+	}
+	token, err := g.token(ctx)
+	if err != nil {
+		return err
+	}
+
+	resource := "eventTicketObject"
+	if pass.Kind == PassKindLoyalty {
+		resource = "loyaltyObject"
+	}
+	endpoint := fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(g.config.APIURL, "/"), resource, url.PathEscape(g.objectID(pass)))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to update pass: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("Google Wallet API error (%d): %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+// token returns an OAuth access token of the service account, refreshing it shortly
+// before it expires
+func (g *GoogleIssuer) token(ctx context.Context) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.accessToken != "" && time.Now().Add(time.Minute).Before(g.tokenExpiry) {
+		return g.accessToken, nil
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   g.config.ServiceAccountEmail,
+		"scope": googleWalletScope,
+		"aud":   g.config.TokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(g.config.PrivateKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token request: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := g.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request access token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("token error (%d): %s", resp.StatusCode, string(respBody))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode access token: %w", err)
+	}
+
+	g.accessToken = result.AccessToken
+	g.tokenExpiry = now.Add(time.Duration(result.ExpiresIn) * time.Second)
+	return g.accessToken, nil
+}
+
+func (g *GoogleIssuer) classID(pass *Pass) string {
+	return g.config.IssuerID + "." + sanitizeID(pass.ClassID)
+}
+
+func (g *GoogleIssuer) objectID(pass *Pass) string {
+	return g.config.IssuerID + "." + sanitizeID(pass.SerialNumber)
+}
+
+// classJSON builds the class shared by the passes of a template
+func (g *GoogleIssuer) classJSON(pass *Pass) map[string]interface{} {
+	class := map[string]interface{}{
+		"id":           g.classID(pass),
+		"issuerName":   pass.OrganizationName,
+		"reviewStatus": "UNDER_REVIEW",
+	}
+	if pass.BackgroundColor != "" {
+		class["hexBackgroundColor"] = pass.BackgroundColor
+	}
+	logo := map[string]interface{}{"sourceUri": map[string]string{"uri": pass.LogoURL}}
+
+	title := firstNonEmptyString(pass.LogoText, pass.Description, pass.OrganizationName)
+	if pass.Kind == PassKindLoyalty {
+		class["programName"] = title
+		class["programLogo"] = logo
+	} else {
+		class["eventName"] = localizedString(title)
+		if pass.LogoURL != "" {
+			class["logo"] = logo
+		}
+	}
+	return class
+}
+
+// objectJSON builds the pass object holding the fields and barcode of a pass
+func (g *GoogleIssuer) objectJSON(pass *Pass) map[string]interface{} {
+	state := "ACTIVE"
+	if pass.Voided {
+		state = "INACTIVE"
+	} else if pass.ExpiresAt != nil && pass.ExpiresAt.Before(time.Now()) {
+		state = "EXPIRED"
+	}
+
+	object := map[string]interface{}{
+		"id":      g.objectID(pass),
+		"classId": g.classID(pass),
+		"state":   state,
+	}
+	if pass.BackgroundColor != "" {
+		object["hexBackgroundColor"] = pass.BackgroundColor
+	}
+	if pass.Barcode.Message != "" {
+		barcode := map[string]string{
+			"type":  googleBarcodeType(pass.Barcode.Format),
+			"value": pass.Barcode.Message,
+		}
+		if pass.Barcode.AltText != "" {
+			barcode["alternateText"] = pass.Barcode.AltText
+		}
+		object["barcode"] = barcode
+	}
+
+	modules := make([]map[string]string, 0, len(pass.Fields))
+	for _, field := range pass.Fields {
+		modules = append(modules, map[string]string{
+			"id":     sanitizeID(field.Key),
+			"header": firstNonEmptyString(field.Label, field.Key),
+			"body":   field.Value,
+		})
+	}
+	if len(modules) > 0 {
+		object["textModulesData"] = modules
+	}
+
+	if pass.Kind == PassKindLoyalty {
+		object["accountId"] = pass.SerialNumber
+		object["accountName"] = pass.HolderName
+	} else {
+		if pass.HolderName != "" {
+			object["ticketHolderName"] = pass.HolderName
+		}
+		if pass.RelevantDate != nil {
+			object["validTimeInterval"] = map[string]interface{}{
+				"start": map[string]string{"date": pass.RelevantDate.Format(time.RFC3339)},
+			}
+		}
+	}
+	return object
+}
+
+func googleBarcodeType(format BarcodeFormat) string {
+	switch format {
+	case BarcodePDF417:
+		return "PDF_417"
+	case BarcodeAztec:
+		return "AZTEC"
+	case BarcodeCode128:
+		return "CODE_128"
+	}
+	return "QR_CODE"
+}
+
+func localizedString(value string) map[string]interface{} {
+	return map[string]interface{}{
+		"defaultValue": map[string]string{"language": "pt-BR", "value": value},
+	}
+}
+
+func firstNonEmptyString(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
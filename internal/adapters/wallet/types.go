@@ -0,0 +1,130 @@
+package wallet
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PassKind is the kind of pass, which decides its layout in each wallet
+type PassKind string
+
+const (
+	PassKindBooking PassKind = "booking" // Apple event ticket, Google event ticket
+	PassKindLoyalty PassKind = "loyalty" // Apple store card, Google loyalty card
+)
+
+// BarcodeFormat is the symbology of a pass barcode
+type BarcodeFormat string
+
+const (
+	BarcodeQR      BarcodeFormat = "qr"
+	BarcodePDF417  BarcodeFormat = "pdf417"
+	BarcodeAztec   BarcodeFormat = "aztec"
+	BarcodeCode128 BarcodeFormat = "code128"
+)
+
+// FieldPosition is where a field is shown on the pass
+type FieldPosition string
+
+const (
+	FieldHeader    FieldPosition = "header"
+	FieldPrimary   FieldPosition = "primary"
+	FieldSecondary FieldPosition = "secondary"
+	FieldAuxiliary FieldPosition = "auxiliary"
+	FieldBack      FieldPosition = "back"
+)
+
+// Field is a labeled value shown on a pass
+type Field struct {
+	Key      string        `json:"key"`
+	Label    string        `json:"label,omitempty"`
+	Value    string        `json:"value"`
+	Position FieldPosition `json:"position,omitempty"`
+}
+
+// Barcode is the code scanned to redeem a pass
+type Barcode struct {
+	Format  BarcodeFormat `json:"format"`
+	Message string        `json:"message"`
+	AltText string        `json:"alt_text,omitempty"`
+}
+
+// Pass is a wallet pass ready to be built for Apple Wallet or Google Wallet
+type Pass struct {
+	SerialNumber     string
+	AuthToken        string // Authenticates the Apple web service requests of the pass
+	WebServiceURL    string // Apple web service devices register with for updates
+	ClassID          string // Google class shared by the passes of a template
+	Kind             PassKind
+	OrganizationName string
+	Description      string
+	LogoText         string
+	LogoURL          string // Google requires a public logo for loyalty cards
+	Logo             []byte // PNG shown by Apple Wallet
+	BackgroundColor  string // #RRGGBB
+	ForegroundColor  string // #RRGGBB
+	LabelColor       string // #RRGGBB
+	Fields           []Field
+	Barcode          Barcode
+	HolderName       string
+	RelevantDate     *time.Time
+	ExpiresAt        *time.Time
+	Voided           bool
+	UpdatedAt        time.Time
+}
+
+// IsValidBarcodeFormat checks if a barcode format is supported
+func IsValidBarcodeFormat(format BarcodeFormat) bool {
+	switch format {
+	case BarcodeQR, BarcodePDF417, BarcodeAztec, BarcodeCode128:
+		return true
+	}
+	return false
+}
+
+// IsValidColor checks if a color is in #RRGGBB form
+func IsValidColor(color string) bool {
+	if len(color) != 7 || color[0] != '#' {
+		return false
+	}
+	_, err := strconv.ParseUint(color[1:], 16, 32)
+	return err == nil
+}
+
+// rgbColor converts #RRGGBB to the rgb(r, g, b) form of pass.json
+func rgbColor(color string) string {
+	if !IsValidColor(color) {
+		return ""
+	}
+	value, _ := strconv.ParseUint(color[1:], 16, 32)
+	return fmt.Sprintf("rgb(%d, %d, %d)", value>>16, (value>>8)&0xff, value&0xff)
+}
+
+// fieldsAt returns the fields shown at a position. Fields without a position are
+// secondary.
+func fieldsAt(fields []Field, position FieldPosition) []Field {
+	var result []Field
+	for _, field := range fields {
+		fieldPosition := field.Position
+		if fieldPosition == "" {
+			fieldPosition = FieldSecondary
+		}
+		if fieldPosition == position {
+			result = append(result, field)
+		}
+	}
+	return result
+}
+
+// sanitizeID keeps the characters wallet IDs accept
+func sanitizeID(id string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			return r
+		}
+		return '_'
+	}, id)
+}
@@ -0,0 +1,207 @@
+package wallet
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCertificate(t *testing.T) (*x509.Certificate, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "Pass Type ID: pass.com.example.linktor"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return certificate, key
+}
+
+func newTestPass() *Pass {
+	relevant := time.Date(2026, 11, 20, 19, 30, 0, 0, time.UTC)
+	return &Pass{
+		SerialNumber:     "pass-1",
+		AuthToken:        "0123456789abcdef0123",
+		WebServiceURL:    "https://linktor.example.com/api/v1/wallet/apple",
+		ClassID:          "template-1",
+		Kind:             PassKindBooking,
+		OrganizationName: "Clínica Sorriso",
+		Description:      "Consulta confirmada",
+		BackgroundColor:  "#1A73E8",
+		Fields: []Field{
+			{Key: "when", Label: "Data", Value: "20/11 19:30", Position: FieldPrimary},
+			{Key: "reference", Label: "Reserva", Value: "BK-1001"},
+		},
+		Barcode:      Barcode{Format: BarcodeQR, Message: "BK-1001"},
+		HolderName:   "Maria",
+		RelevantDate: &relevant,
+	}
+}
+
+func TestAppleIssuer_Build(t *testing.T) {
+	certificate, key := newTestCertificate(t)
+	issuer := NewAppleIssuer(AppleConfig{
+		PassTypeID:  "pass.com.example.linktor",
+		TeamID:      "TEAM123",
+		Certificate: certificate,
+		PrivateKey:  key,
+		Icon:        []byte("icon-png"),
+	})
+
+	bundle, err := issuer.Build(newTestPass())
+	require.NoError(t, err)
+
+	archive, err := zip.NewReader(bytes.NewReader(bundle), int64(len(bundle)))
+	require.NoError(t, err)
+	files := map[string][]byte{}
+	for _, file := range archive.File {
+		r, err := file.Open()
+		require.NoError(t, err)
+		files[file.Name], _ = io.ReadAll(r)
+		r.Close()
+	}
+	require.Contains(t, files, "pass.json")
+	require.Contains(t, files, "manifest.json")
+	require.Contains(t, files, "signature")
+
+	var pass map[string]interface{}
+	require.NoError(t, json.Unmarshal(files["pass.json"], &pass))
+	assert.Equal(t, "pass.com.example.linktor", pass["passTypeIdentifier"])
+	assert.Equal(t, "rgb(26, 115, 232)", pass["backgroundColor"])
+	assert.Equal(t, "2026-11-20T19:30:00Z", pass["relevantDate"])
+	ticket := pass["eventTicket"].(map[string]interface{})
+	assert.Len(t, ticket["primaryFields"], 1)
+	assert.Len(t, ticket["secondaryFields"], 1)
+	barcode := pass["barcodes"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "PKBarcodeFormatQR", barcode["format"])
+	assert.Equal(t, "BK-1001", barcode["message"])
+
+	var manifest map[string]string
+	require.NoError(t, json.Unmarshal(files["manifest.json"], &manifest))
+	for name, hash := range manifest {
+		sum := sha1.Sum(files[name])
+		assert.Equal(t, hex.EncodeToString(sum[:]), hash, name)
+	}
+
+	// The signature is a detached PKCS#7 over the manifest
+	var contentInfo pkcs7ContentInfo
+	_, err = asn1.Unmarshal(files["signature"], &contentInfo)
+	require.NoError(t, err)
+	assert.True(t, contentInfo.ContentType.Equal(oidSignedData))
+	var signedData pkcs7SignedData
+	_, err = asn1.Unmarshal(contentInfo.Content.Bytes, &signedData)
+	require.NoError(t, err)
+	require.Len(t, signedData.SignerInfos, 1)
+	signer := signedData.SignerInfos[0]
+	assert.Equal(t, 0, signer.IssuerAndSerialNumber.SerialNumber.Cmp(certificate.SerialNumber))
+
+	manifestDigest := sha256.Sum256(files["manifest.json"])
+	assert.True(t, bytes.Contains(signer.AuthenticatedAttributes.Bytes, manifestDigest[:]))
+	signedAttributes, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSet, IsCompound: true, Bytes: signer.AuthenticatedAttributes.Bytes})
+	require.NoError(t, err)
+	attributesDigest := sha256.Sum256(signedAttributes)
+	assert.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, attributesDigest[:], signer.EncryptedDigest))
+}
+
+func TestAppleIssuer_Push(t *testing.T) {
+	var path, topic string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		topic = r.Header.Get("apns-topic")
+	}))
+	defer server.Close()
+
+	issuer := NewAppleIssuer(AppleConfig{PassTypeID: "pass.com.example.linktor", APNsURL: server.URL})
+	require.NoError(t, issuer.Push(context.Background(), "device-token"))
+	assert.Equal(t, "/3/device/device-token", path)
+	assert.Equal(t, "pass.com.example.linktor", topic)
+}
+
+func TestGoogleIssuer_SaveURL(t *testing.T) {
+	_, key := newTestCertificate(t)
+	issuer := NewGoogleIssuer(GoogleConfig{
+		IssuerID:            "3388000000012345678",
+		ServiceAccountEmail: "wallet@linktor.iam.gserviceaccount.com",
+		PrivateKey:          key,
+	})
+
+	link, err := issuer.SaveURL(newTestPass())
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(link, googleSaveURL))
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(strings.TrimPrefix(link, googleSaveURL), claims, func(token *jwt.Token) (interface{}, error) {
+		return &key.PublicKey, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "savetowallet", claims["typ"])
+
+	payload := claims["payload"].(map[string]interface{})
+	object := payload["eventTicketObjects"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "3388000000012345678.pass-1", object["id"])
+	assert.Equal(t, "3388000000012345678.template-1", object["classId"])
+	assert.Equal(t, "ACTIVE", object["state"])
+	assert.Equal(t, "BK-1001", object["barcode"].(map[string]interface{})["value"])
+}
+
+func TestGoogleIssuer_UpdateObject(t *testing.T) {
+	_, key := newTestCertificate(t)
+	var updated map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "access-1", "expires_in": 3600})
+		case "/loyaltyObject/issuer.pass-1":
+			assert.Equal(t, http.MethodPut, r.Method)
+			assert.Equal(t, "Bearer access-1", r.Header.Get("Authorization"))
+			json.NewDecoder(r.Body).Decode(&updated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	issuer := NewGoogleIssuer(GoogleConfig{
+		IssuerID:            "issuer",
+		ServiceAccountEmail: "wallet@linktor.iam.gserviceaccount.com",
+		PrivateKey:          key,
+		APIURL:              server.URL,
+		TokenURL:            server.URL + "/token",
+	})
+
+	pass := newTestPass()
+	pass.Kind = PassKindLoyalty
+	pass.Voided = true
+	require.NoError(t, issuer.UpdateObject(context.Background(), pass))
+	assert.Equal(t, "INACTIVE", updated["state"])
+
+	// Passes nobody saved are not an error
+	pass.SerialNumber = "pass-2"
+	assert.NoError(t, issuer.UpdateObject(context.Background(), pass))
+}
@@ -3,6 +3,7 @@ package webchat
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -10,6 +11,10 @@ import (
 	"github.com/msgfy/linktor/pkg/plugin"
 )
 
+// PublicAPIIdentityPrefix marks the contact identities created through the public
+// chat API. Those contacts poll for replies instead of holding a WebSocket open.
+const PublicAPIIdentityPrefix = "api:"
+
 // Adapter implements the WebChat channel adapter
 type Adapter struct {
 	*plugin.BaseAdapter
@@ -129,6 +134,16 @@ func (a *Adapter) Disconnect(ctx context.Context) error {
 
 // SendMessage sends a message to a WebSocket client
 func (a *Adapter) SendMessage(ctx context.Context, msg *plugin.OutboundMessage) (*plugin.SendResult, error) {
+	// Public API contacts fetch the stored reply when they poll
+	if strings.HasPrefix(msg.RecipientID, PublicAPIIdentityPrefix) {
+		return &plugin.SendResult{
+			Success:    true,
+			ExternalID: msg.ID,
+			Status:     plugin.MessageStatusSent,
+			Timestamp:  time.Now(),
+		}, nil
+	}
+
 	a.mu.RLock()
 	hub := a.hub
 	a.mu.RUnlock()
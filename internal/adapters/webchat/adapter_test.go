@@ -139,6 +139,24 @@ func TestAdapter_SendMessage_ClientNotFound(t *testing.T) {
 	assert.Equal(t, "client not connected", result.Error)
 }
 
+func TestAdapter_SendMessage_PublicAPIRecipient(t *testing.T) {
+	a := NewAdapter()
+	a.Initialize(map[string]string{})
+	ctx := context.Background()
+
+	// Public API contacts poll for replies, so no WebSocket client is needed
+	result, err := a.SendMessage(ctx, &plugin.OutboundMessage{
+		ID:          "msg-1",
+		RecipientID: PublicAPIIdentityPrefix + "user-42",
+		Content:     "hello",
+		ContentType: plugin.ContentTypeText,
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.Equal(t, plugin.MessageStatusSent, result.Status)
+	assert.Equal(t, "msg-1", result.ExternalID)
+}
+
 func TestAdapter_SendTypingIndicator_NotConnected(t *testing.T) {
 	a := NewAdapter()
 	a.Initialize(map[string]string{})
@@ -24,6 +24,8 @@ type Handler struct {
 	contactRepo     repository.ContactRepository
 	producer        nats.Publisher
 	upgrader        websocket.Upgrader
+	banners         IncidentBannerProvider
+	widgetSettings  WidgetSettingsProvider
 }
 
 // NewHandler creates a new WebChat handler
@@ -133,6 +135,16 @@ func (h *Handler) WebSocketHandler(c *gin.Context) {
 		},
 	})
 
+	// Show banners of incidents affecting the channel
+	for _, banner := range h.activeBanners(c.Request.Context(), channel) {
+		client.SendMessage(bannerMessage(banner))
+	}
+
+	// Offer the tenant's quick replies and emoji sets
+	if h.widgetSettings != nil {
+		client.SendMessage(widgetConfigMessage(h.tenantWidgetSettings(c.Request.Context(), channel)))
+	}
+
 	// Handle connect event
 	h.adapter.HandleClientConnect(context.Background(), sessionID, client.Metadata)
 
@@ -186,6 +198,9 @@ func (h *Handler) handleClientMessage(ctx context.Context, client *Client, chann
 		Attachments: attachments,
 		Timestamp:   time.Now(),
 	}
+	if quickReplyID := h.recordQuickReplyClick(ctx, channel, conversation.ID, msg); quickReplyID != "" {
+		inbound.Metadata[entity.MetadataQuickReplyID] = quickReplyID
+	}
 
 	return h.producer.PublishInbound(ctx, inbound)
 }
@@ -298,6 +313,7 @@ func (h *Handler) GetWidgetConfig(c *gin.Context) {
 	}
 
 	config := h.adapter.GetConfig()
+	settings := h.tenantWidgetSettings(c.Request.Context(), channel)
 
 	c.JSON(http.StatusOK, gin.H{
 		"channel_id":        channel.ID,
@@ -311,6 +327,9 @@ func (h *Handler) GetWidgetConfig(c *gin.Context) {
 		"require_name":      config.RequireName,
 		"enabled":           channel.Enabled,
 		"connection_status": channel.ConnectionStatus,
+		"incident_banners":  h.activeBanners(c.Request.Context(), channel),
+		"quick_replies":     settings.QuickReplies,
+		"emoji_sets":        settings.EmojiSets,
 	})
 }
 
@@ -0,0 +1,87 @@
+package webchat
+
+import (
+	"context"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// IncidentBannerProvider returns the banners of the incidents affecting a channel
+type IncidentBannerProvider interface {
+	ActiveBanners(ctx context.Context, tenantID, channelID string) ([]entity.IncidentBanner, error)
+}
+
+// SetIncidentBannerProvider enables incident banners in widgets
+func (h *Handler) SetIncidentBannerProvider(provider IncidentBannerProvider) {
+	h.banners = provider
+}
+
+// activeBanners returns the incident banners of a channel, or none when banners are disabled
+func (h *Handler) activeBanners(ctx context.Context, channel *entity.Channel) []entity.IncidentBanner {
+	banners := []entity.IncidentBanner{}
+	if h.banners == nil {
+		return banners
+	}
+	if active, err := h.banners.ActiveBanners(ctx, channel.TenantID, channel.ID); err == nil {
+		banners = active
+	}
+	return banners
+}
+
+// NotifyIncidentChanged shows the banner of an active incident in the connected widgets
+// of every affected webchat channel, or clears it once the incident is over
+func (h *Handler) NotifyIncidentChanged(ctx context.Context, incident *entity.Incident) {
+	hub := h.adapter.GetHub()
+	if hub == nil {
+		return
+	}
+
+	channels, err := h.channelRepo.FindByType(ctx, incident.TenantID, entity.ChannelTypeWebChat)
+	if err != nil {
+		return
+	}
+
+	active := incident.IsActive(time.Now())
+	for _, channel := range channels {
+		if incident.Affects(channel.ID) {
+			hub.BroadcastToChannel(channel.ID, incidentBannerMessage(incident, active))
+		}
+	}
+}
+
+// incidentBannerMessage builds the WebSocket message that shows or clears a banner
+func incidentBannerMessage(incident *entity.Incident, active bool) *WebSocketMessage {
+	visible := "false"
+	if active {
+		visible = "true"
+	}
+
+	return &WebSocketMessage{
+		Type: MessageTypeIncident,
+		Payload: MessagePayload{
+			ID:      incident.ID,
+			Content: incident.Message,
+			Metadata: map[string]string{
+				"title":  incident.Title,
+				"kind":   string(incident.Kind),
+				"impact": string(incident.Impact),
+				"status": string(incident.Status),
+				"active": visible,
+			},
+			Timestamp: time.Now().Format(time.RFC3339),
+		},
+	}
+}
+
+// bannerMessage builds the WebSocket message of a banner sent when a widget connects
+func bannerMessage(banner entity.IncidentBanner) *WebSocketMessage {
+	return incidentBannerMessage(&entity.Incident{
+		ID:      banner.IncidentID,
+		Kind:    banner.Kind,
+		Title:   banner.Title,
+		Message: banner.Message,
+		Impact:  banner.Impact,
+		Status:  banner.Status,
+	}, true)
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/msgfy/linktor/internal/domain/entity"
 )
 
 const (
@@ -31,6 +32,9 @@ const (
 	MessageTypeError    = "error"
 	MessageTypeAck      = "ack"
 	MessageTypePresence = "presence"
+	MessageTypeIncident = "incident_banner"
+	// MessageTypeWidgetConfig replaces the quick replies and emoji sets of the widget
+	MessageTypeWidgetConfig = "widget_config"
 )
 
 // WebSocketMessage represents a WebSocket protocol message
@@ -52,6 +56,10 @@ type MessagePayload struct {
 	Timestamp   string              `json:"timestamp,omitempty"`
 	IsTyping    bool                `json:"is_typing,omitempty"`
 	Error       string              `json:"error,omitempty"`
+
+	// Widget configuration, sent with MessageTypeWidgetConfig
+	QuickReplies []entity.WidgetQuickReply `json:"quick_replies,omitempty"`
+	EmojiSets    []entity.WidgetEmojiSet   `json:"emoji_sets,omitempty"`
 }
 
 // AttachmentPayload represents an attachment in a message
@@ -200,6 +208,23 @@ func (h *Hub) BroadcastToConversation(conversationID string, msg *WebSocketMessa
 	}
 }
 
+// BroadcastToChannel sends a message to all clients connected to a channel
+func (h *Hub) BroadcastToChannel(channelID string, msg *WebSocketMessage) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, client := range h.clients {
+		if client.ChannelID != channelID {
+			continue
+		}
+		select {
+		case client.send <- msg:
+		default:
+			// Client buffer full
+		}
+	}
+}
+
 // ClientCount returns the number of connected clients
 func (h *Hub) ClientCount() int {
 	h.mu.RLock()
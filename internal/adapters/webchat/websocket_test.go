@@ -145,6 +145,43 @@ func TestHub_BroadcastToConversation(t *testing.T) {
 	assert.Equal(t, "hello conv-1", received.Payload.Content)
 }
 
+func TestHub_BroadcastToChannel(t *testing.T) {
+	hub := NewHub()
+	go hub.Run()
+	defer hub.Stop()
+	time.Sleep(10 * time.Millisecond)
+
+	client1 := &Client{
+		hub:       hub,
+		SessionID: "session-1",
+		ChannelID: "channel-1",
+		send:      make(chan *WebSocketMessage, 256),
+		Metadata:  make(map[string]string),
+	}
+	client2 := &Client{
+		hub:       hub,
+		SessionID: "session-2",
+		ChannelID: "channel-2", // Different channel
+		send:      make(chan *WebSocketMessage, 256),
+		Metadata:  make(map[string]string),
+	}
+
+	hub.register <- client1
+	hub.register <- client2
+	time.Sleep(10 * time.Millisecond)
+
+	hub.BroadcastToChannel("channel-1", &WebSocketMessage{
+		Type:    MessageTypeIncident,
+		Payload: MessagePayload{Content: "Messages are delayed"},
+	})
+
+	assert.Len(t, client1.send, 1)
+	assert.Len(t, client2.send, 0)
+
+	received := <-client1.send
+	assert.Equal(t, MessageTypeIncident, received.Type)
+}
+
 func TestHub_Broadcast(t *testing.T) {
 	hub := NewHub()
 	go hub.Run()
@@ -0,0 +1,93 @@
+package webchat
+
+import (
+	"context"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// WidgetSettingsProvider returns the quick replies and emoji sets of a tenant's widgets
+// and records the quick replies visitors click
+type WidgetSettingsProvider interface {
+	Get(ctx context.Context, tenantID string) (*entity.WidgetSettings, error)
+	RecordQuickReplyClick(ctx context.Context, click *entity.QuickReplyClick) error
+}
+
+// SetWidgetSettingsProvider enables tenant quick replies and emoji sets in widgets
+func (h *Handler) SetWidgetSettingsProvider(provider WidgetSettingsProvider) {
+	h.widgetSettings = provider
+}
+
+// tenantWidgetSettings returns the widget settings of a channel's tenant, or none when
+// they are disabled
+func (h *Handler) tenantWidgetSettings(ctx context.Context, channel *entity.Channel) *entity.WidgetSettings {
+	settings := &entity.WidgetSettings{
+		TenantID:     channel.TenantID,
+		QuickReplies: []entity.WidgetQuickReply{},
+		EmojiSets:    []entity.WidgetEmojiSet{},
+	}
+	if h.widgetSettings == nil {
+		return settings
+	}
+	if found, err := h.widgetSettings.Get(ctx, channel.TenantID); err == nil {
+		settings = found
+	}
+	return settings
+}
+
+// NotifyWidgetSettingsChanged pushes new widget settings to the connected widgets of
+// every webchat channel of the tenant
+func (h *Handler) NotifyWidgetSettingsChanged(ctx context.Context, settings *entity.WidgetSettings) {
+	hub := h.adapter.GetHub()
+	if hub == nil {
+		return
+	}
+
+	channels, err := h.channelRepo.FindByType(ctx, settings.TenantID, entity.ChannelTypeWebChat)
+	if err != nil {
+		return
+	}
+
+	msg := widgetConfigMessage(settings)
+	for _, channel := range channels {
+		hub.BroadcastToChannel(channel.ID, msg)
+	}
+}
+
+// recordQuickReplyClick records the quick reply a visitor's message came from, if any.
+// Unknown quick replies are ignored, so visitors cannot skew the analytics.
+func (h *Handler) recordQuickReplyClick(ctx context.Context, channel *entity.Channel, conversationID string, msg *MessagePayload) string {
+	quickReplyID := msg.Metadata[entity.MetadataQuickReplyID]
+	if quickReplyID == "" || h.widgetSettings == nil {
+		return ""
+	}
+
+	settings := h.tenantWidgetSettings(ctx, channel)
+	reply, ok := settings.QuickReply(quickReplyID)
+	if !ok {
+		return ""
+	}
+
+	_ = h.widgetSettings.RecordQuickReplyClick(ctx, &entity.QuickReplyClick{
+		TenantID:       channel.TenantID,
+		ChannelID:      channel.ID,
+		ConversationID: conversationID,
+		QuickReplyID:   reply.ID,
+		Label:          reply.Label,
+	})
+	return reply.ID
+}
+
+// widgetConfigMessage builds the WebSocket message that replaces the quick replies and
+// emoji sets of a widget. A missing list means the tenant has none.
+func widgetConfigMessage(settings *entity.WidgetSettings) *WebSocketMessage {
+	return &WebSocketMessage{
+		Type: MessageTypeWidgetConfig,
+		Payload: MessagePayload{
+			QuickReplies: settings.QuickReplies,
+			EmojiSets:    settings.EmojiSets,
+			Timestamp:    time.Now().Format(time.RFC3339),
+		},
+	}
+}
@@ -0,0 +1,65 @@
+package webchat
+
+import (
+	"context"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeWidgetSettings struct {
+	settings *entity.WidgetSettings
+	clicks   []*entity.QuickReplyClick
+}
+
+func (f *fakeWidgetSettings) Get(ctx context.Context, tenantID string) (*entity.WidgetSettings, error) {
+	return f.settings, nil
+}
+
+func (f *fakeWidgetSettings) RecordQuickReplyClick(ctx context.Context, click *entity.QuickReplyClick) error {
+	f.clicks = append(f.clicks, click)
+	return nil
+}
+
+func TestHandler_RecordQuickReplyClick(t *testing.T) {
+	provider := &fakeWidgetSettings{settings: &entity.WidgetSettings{
+		TenantID:     "tenant-1",
+		QuickReplies: []entity.WidgetQuickReply{{ID: "pricing", Label: "Pricing", Message: "How much does it cost?"}},
+	}}
+	h := NewHandler(NewAdapter(), nil, nil, nil, nil)
+	h.SetWidgetSettingsProvider(provider)
+	channel := &entity.Channel{ID: "channel-1", TenantID: "tenant-1", Type: entity.ChannelTypeWebChat}
+	ctx := context.Background()
+
+	id := h.recordQuickReplyClick(ctx, channel, "conv-1", &MessagePayload{
+		Content:  "How much does it cost?",
+		Metadata: map[string]string{entity.MetadataQuickReplyID: "pricing"},
+	})
+	assert.Equal(t, "pricing", id)
+	require.Len(t, provider.clicks, 1)
+	assert.Equal(t, "channel-1", provider.clicks[0].ChannelID)
+	assert.Equal(t, "conv-1", provider.clicks[0].ConversationID)
+	assert.Equal(t, "Pricing", provider.clicks[0].Label)
+
+	id = h.recordQuickReplyClick(ctx, channel, "conv-1", &MessagePayload{
+		Metadata: map[string]string{entity.MetadataQuickReplyID: "made-up"},
+	})
+	assert.Empty(t, id, "unknown quick replies are not counted")
+	assert.Empty(t, h.recordQuickReplyClick(ctx, channel, "conv-1", &MessagePayload{Content: "hi"}))
+	assert.Len(t, provider.clicks, 1)
+}
+
+func TestWidgetConfigMessage(t *testing.T) {
+	msg := widgetConfigMessage(&entity.WidgetSettings{
+		QuickReplies: []entity.WidgetQuickReply{{ID: "hours", Label: "Opening hours"}},
+		EmojiSets:    []entity.WidgetEmojiSet{{Name: "Brand", Emojis: []entity.WidgetEmoji{{Shortcode: "logo", ImageURL: "https://cdn.example.com/logo.png"}}}},
+	})
+
+	assert.Equal(t, MessageTypeWidgetConfig, msg.Type)
+	require.Len(t, msg.Payload.QuickReplies, 1)
+	assert.Equal(t, "hours", msg.Payload.QuickReplies[0].ID)
+	require.Len(t, msg.Payload.EmojiSets, 1)
+	assert.Equal(t, "logo", msg.Payload.EmojiSets[0].Emojis[0].Shortcode)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
@@ -21,14 +22,15 @@ type ConnectionHandler func(ctx context.Context, connected bool, reason string)
 type Adapter struct {
 	*plugin.BaseAdapter
 
-	mu                sync.RWMutex
-	client            *Client
-	messageHandler    plugin.MessageHandler
-	statusHandler     plugin.StatusHandler
-	connectionHandler ConnectionHandler
-	config            *Config
-	stopCh            chan struct{}
-	eventLoopDone     chan struct{}
+	mu                  sync.RWMutex
+	client              *Client
+	messageHandler      plugin.MessageHandler
+	statusHandler       plugin.StatusHandler
+	connectionHandler   ConnectionHandler
+	disappearingHandler DisappearingHandler
+	config              *Config
+	stopCh              chan struct{}
+	eventLoopDone       chan struct{}
 }
 
 // NewAdapter creates a new WhatsApp unofficial adapter
@@ -86,6 +88,8 @@ func (a *Adapter) Initialize(config map[string]string) error {
 	a.config = &Config{
 		ChannelID:    config["channel_id"],
 		DatabasePath: config["database_path"],
+		SessionDSN:   config["session_dsn"],
+		DeviceJID:    config["device_jid"],
 		DeviceName:   config["device_name"],
 		PlatformType: config["platform_type"],
 		LogLevel:     config["log_level"],
@@ -225,6 +229,13 @@ func (a *Adapter) SendMessage(ctx context.Context, msg *plugin.OutboundMessage)
 		}, nil
 	}
 
+	// The conversation knows the chat's disappearing timer across restarts
+	if value := msg.Metadata["disappearing_timer"]; value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil {
+			client.rememberDisappearingTimer(msg.RecipientID, time.Duration(seconds)*time.Second)
+		}
+	}
+
 	var resp *SendMessageResponse
 	var err error
 
@@ -425,6 +436,38 @@ func (a *Adapter) SetStatusHandler(handler plugin.StatusHandler) {
 	a.statusHandler = handler
 }
 
+// SetDisappearingHandler sets the handler for disappearing message setting changes
+func (a *Adapter) SetDisappearingHandler(handler DisappearingHandler) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.disappearingHandler = handler
+}
+
+// SetDisappearingTimer turns disappearing messages on or off in a chat
+func (a *Adapter) SetDisappearingTimer(ctx context.Context, to string, timer time.Duration) error {
+	a.mu.RLock()
+	client := a.client
+	a.mu.RUnlock()
+
+	if client == nil {
+		return ErrClientNotReady
+	}
+	return client.SetDisappearingTimer(ctx, to, timer)
+}
+
+// GetDisappearingTimer returns the disappearing timer of a chat, see
+// Client.GetDisappearingTimer
+func (a *Adapter) GetDisappearingTimer(ctx context.Context, to string) (time.Duration, bool, error) {
+	a.mu.RLock()
+	client := a.client
+	a.mu.RUnlock()
+
+	if client == nil {
+		return 0, false, ErrClientNotReady
+	}
+	return client.GetDisappearingTimer(ctx, to)
+}
+
 // SetConnectionHandler sets the handler for connection state changes
 func (a *Adapter) SetConnectionHandler(handler ConnectionHandler) {
 	a.mu.Lock()
@@ -498,6 +541,7 @@ func (a *Adapter) eventLoop() {
 			msgHandler := a.messageHandler
 			statusHandler := a.statusHandler
 			connHandler := a.connectionHandler
+			disappearingHandler := a.disappearingHandler
 			a.mu.RUnlock()
 
 			switch v := evt.(type) {
@@ -517,6 +561,13 @@ func (a *Adapter) eventLoop() {
 					}
 				}
 
+			case *DisappearingTimerChange:
+				if disappearingHandler != nil {
+					if err := disappearingHandler(context.Background(), v); err != nil {
+						// Log error but continue
+					}
+				}
+
 			case ConnectionEvent:
 				connected := v.State == DeviceStateConnected
 				a.mu.Lock()
@@ -563,6 +614,9 @@ func convertToInboundMessage(msg *IncomingMessage) *plugin.InboundMessage {
 			"msg_type":   msg.MessageType,
 		},
 	}
+	if msg.IsGroup {
+		inbound.Metadata["group_id"] = msg.ChatJID.String()
+	}
 
 	// Set content type based on message type
 	switch msg.MessageType {
@@ -600,6 +654,15 @@ func convertToInboundMessage(msg *IncomingMessage) *plugin.InboundMessage {
 		inbound.Metadata["quoted_text"] = msg.ReplyTo.Text
 	}
 
+	// Reflect disappearing messages, so stored messages expire like on the phone
+	if msg.HasExpiration && !msg.IsGroup {
+		inbound.Metadata["disappearing_timer"] = strconv.FormatUint(uint64(msg.Expiration), 10)
+		if msg.Expiration > 0 {
+			expiresAt := msg.Timestamp.Add(time.Duration(msg.Expiration) * time.Second)
+			inbound.Metadata["ephemeral_expires_at"] = expiresAt.UTC().Format(time.RFC3339)
+		}
+	}
+
 	// Handle reaction
 	if msg.Reaction != nil {
 		inbound.Metadata["reaction"] = msg.Reaction.Emoji
@@ -628,12 +691,18 @@ func convertToStatusCallback(receipt *Receipt) *plugin.StatusCallback {
 		messageID = receipt.MessageIDs[0]
 	}
 
-	return &plugin.StatusCallback{
+	callback := &plugin.StatusCallback{
 		MessageID:  messageID,
 		ExternalID: messageID,
 		Status:     status,
 		Timestamp:  receipt.Timestamp,
 	}
+
+	// Receipts of group messages come from each member
+	if receipt.ChatJID.Server == types.GroupServer {
+		callback.Recipient = receipt.SenderJID.User
+	}
+	return callback
 }
 
 // getMediaData extracts media data from an attachment
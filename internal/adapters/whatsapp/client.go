@@ -16,6 +16,7 @@ import (
 	waLog "go.mau.fi/whatsmeow/util/log"
 	"google.golang.org/protobuf/proto"
 
+	_ "github.com/lib/pq"
 	_ "github.com/mattn/go-sqlite3"
 )
 
@@ -31,6 +32,9 @@ type Client struct {
 	eventCh  chan any
 	qrCh     chan QRCodeEvent
 	stopCh   chan struct{}
+
+	// Disappearing timers of the chats seen
+	disappearing disappearingTimers
 }
 
 // NewClient creates a new WhatsApp client
@@ -40,18 +44,11 @@ func NewClient(config *Config) (*Client, error) {
 	}
 	config.SetDefaults()
 
-	// Ensure storage directory exists
-	dir := filepath.Dir(config.DatabasePath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create storage directory: %w", err)
-	}
-
 	// Create logger
 	logger := waLog.Stdout("WhatsApp", config.LogLevel, true)
 
 	// Initialize database container
-	dbURI := fmt.Sprintf("file:%s?_foreign_keys=on", config.DatabasePath)
-	container, err := sqlstore.New(context.Background(), "sqlite3", dbURI, logger)
+	container, err := newSessionStore(config, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create database: %w", err)
 	}
@@ -299,7 +296,7 @@ func (c *Client) SendTextMessage(ctx context.Context, to, text string) (*SendMes
 		Conversation: proto.String(text),
 	}
 
-	resp, err := client.SendMessage(ctx, jid, msg)
+	resp, err := client.SendMessage(ctx, jid, c.withExpiration(jid, msg))
 	if err != nil {
 		return nil, fmt.Errorf("failed to send message: %w", err)
 	}
@@ -337,7 +334,7 @@ func (c *Client) SendTextMessageWithReply(ctx context.Context, to, text, replyTo
 		},
 	}
 
-	resp, err := client.SendMessage(ctx, jid, msg)
+	resp, err := client.SendMessage(ctx, jid, c.withExpiration(jid, msg))
 	if err != nil {
 		return nil, fmt.Errorf("failed to send reply: %w", err)
 	}
@@ -382,7 +379,7 @@ func (c *Client) SendImageMessage(ctx context.Context, to string, imageData []by
 		},
 	}
 
-	resp, err := client.SendMessage(ctx, jid, msg)
+	resp, err := client.SendMessage(ctx, jid, c.withExpiration(jid, msg))
 	if err != nil {
 		return nil, fmt.Errorf("failed to send image: %w", err)
 	}
@@ -426,7 +423,7 @@ func (c *Client) SendVideoMessage(ctx context.Context, to string, videoData []by
 		},
 	}
 
-	resp, err := client.SendMessage(ctx, jid, msg)
+	resp, err := client.SendMessage(ctx, jid, c.withExpiration(jid, msg))
 	if err != nil {
 		return nil, fmt.Errorf("failed to send video: %w", err)
 	}
@@ -470,7 +467,7 @@ func (c *Client) SendAudioMessage(ctx context.Context, to string, audioData []by
 		},
 	}
 
-	resp, err := client.SendMessage(ctx, jid, msg)
+	resp, err := client.SendMessage(ctx, jid, c.withExpiration(jid, msg))
 	if err != nil {
 		return nil, fmt.Errorf("failed to send audio: %w", err)
 	}
@@ -515,7 +512,7 @@ func (c *Client) SendDocumentMessage(ctx context.Context, to string, docData []b
 		},
 	}
 
-	resp, err := client.SendMessage(ctx, jid, msg)
+	resp, err := client.SendMessage(ctx, jid, c.withExpiration(jid, msg))
 	if err != nil {
 		return nil, fmt.Errorf("failed to send document: %w", err)
 	}
@@ -559,7 +556,7 @@ func (c *Client) SendStickerMessage(ctx context.Context, to string, stickerData
 		},
 	}
 
-	resp, err := client.SendMessage(ctx, jid, msg)
+	resp, err := client.SendMessage(ctx, jid, c.withExpiration(jid, msg))
 	if err != nil {
 		return nil, fmt.Errorf("failed to send sticker: %w", err)
 	}
@@ -594,7 +591,7 @@ func (c *Client) SendLocationMessage(ctx context.Context, to string, lat, lon fl
 		},
 	}
 
-	resp, err := client.SendMessage(ctx, jid, msg)
+	resp, err := client.SendMessage(ctx, jid, c.withExpiration(jid, msg))
 	if err != nil {
 		return nil, fmt.Errorf("failed to send location: %w", err)
 	}
@@ -778,14 +775,18 @@ func (c *Client) GetGroupInfo(ctx context.Context, jid types.JID) (*GroupInfo, e
 		}
 	}
 
-	return &GroupInfo{
+	groupInfo := &GroupInfo{
 		JID:          info.JID,
 		Name:         info.Name,
 		Topic:        info.Topic,
 		Participants: participants,
 		CreatedAt:    info.GroupCreated,
 		CreatedBy:    info.OwnerJID,
-	}, nil
+	}
+	if info.IsEphemeral {
+		groupInfo.DisappearingTimer = time.Duration(info.DisappearingTimer) * time.Second
+	}
+	return groupInfo, nil
 }
 
 // GetEventChannel returns the event channel
@@ -811,8 +812,44 @@ func (c *Client) Close() error {
 	return nil
 }
 
+// newSessionStore opens the shared PostgreSQL session store when configured, or the
+// channel's own SQLite file otherwise
+func newSessionStore(config *Config, logger waLog.Logger) (*sqlstore.Container, error) {
+	if config.SessionDSN != "" {
+		return sqlstore.New(context.Background(), "postgres", config.SessionDSN, logger)
+	}
+
+	// Ensure storage directory exists
+	dir := filepath.Dir(config.DatabasePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	dbURI := fmt.Sprintf("file:%s?_foreign_keys=on", config.DatabasePath)
+	return sqlstore.New(context.Background(), "sqlite3", dbURI, logger)
+}
+
 // getOrCreateDevice gets existing device or creates a new one
 func (c *Client) getOrCreateDevice(ctx context.Context) (*store.Device, error) {
+	// A shared store holds the devices of every channel, so look this one up by JID
+	if c.config.SessionDSN != "" {
+		if c.config.DeviceJID == "" {
+			return c.store.NewDevice(), nil
+		}
+		jid, err := types.ParseJID(c.config.DeviceJID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid device JID: %w", err)
+		}
+		device, err := c.store.GetDevice(ctx, jid)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get device: %w", err)
+		}
+		if device == nil {
+			device = c.store.NewDevice()
+		}
+		return device, nil
+	}
+
 	// Try to get first device
 	device, err := c.store.GetFirstDevice(ctx)
 	if err != nil {
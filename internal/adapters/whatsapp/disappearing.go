@@ -0,0 +1,251 @@
+package whatsapp
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
+)
+
+// DisappearingTimerChange is emitted when disappearing messages are turned on, off or
+// changed in a chat, from the linked phone, by the contact or by a group admin
+type DisappearingTimerChange struct {
+	ChatJID   types.JID     `json:"chat_jid"`
+	SenderJID types.JID     `json:"sender_jid"`
+	IsFromMe  bool          `json:"is_from_me"`
+	IsGroup   bool          `json:"is_group"`
+	Timer     time.Duration `json:"timer"` // Zero when turned off
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// DisappearingHandler handles disappearing message setting changes
+type DisappearingHandler func(ctx context.Context, change *DisappearingTimerChange) error
+
+// ValidDisappearingTimer reports whether WhatsApp apps honour a timer: off, 24 hours,
+// 7 days or 90 days
+func ValidDisappearingTimer(timer time.Duration) bool {
+	switch timer {
+	case whatsmeow.DisappearingTimerOff, whatsmeow.DisappearingTimer24Hours,
+		whatsmeow.DisappearingTimer7Days, whatsmeow.DisappearingTimer90Days:
+		return true
+	default:
+		return false
+	}
+}
+
+// disappearingTimers remembers the disappearing timer of each chat, learned from the
+// messages and setting changes seen, so sent messages disappear like on the phone
+type disappearingTimers struct {
+	mu     sync.RWMutex
+	timers map[string]time.Duration
+}
+
+func (d *disappearingTimers) get(chat types.JID) (time.Duration, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	timer, ok := d.timers[chatKey(chat)]
+	return timer, ok
+}
+
+func (d *disappearingTimers) set(chat types.JID, timer time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.timers == nil {
+		d.timers = make(map[string]time.Duration)
+	}
+	d.timers[chatKey(chat)] = timer
+}
+
+// chatKey identifies a chat. A bare phone number parses as a JID without user, which
+// is the same chat as the number's user JID.
+func chatKey(chat types.JID) string {
+	if chat.User == "" {
+		chat = types.NewJID(chat.Server, types.DefaultUserServer)
+	}
+	return chat.ToNonAD().String()
+}
+
+// parseChatJID parses a chat JID or a bare phone number
+func parseChatJID(to string) types.JID {
+	if !strings.Contains(to, "@") {
+		return types.NewJID(to, types.DefaultUserServer)
+	}
+	jid, err := types.ParseJID(to)
+	if err != nil {
+		return types.NewJID(to, types.DefaultUserServer)
+	}
+	return jid
+}
+
+// SetDisappearingTimer turns disappearing messages on or off in a chat, like the
+// setting of the chat on the phone
+func (c *Client) SetDisappearingTimer(ctx context.Context, to string, timer time.Duration) error {
+	c.mu.RLock()
+	client := c.client
+	c.mu.RUnlock()
+
+	if client == nil || !client.IsConnected() {
+		return ErrClientNotReady
+	}
+
+	jid := parseChatJID(to)
+	if err := client.SetDisappearingTimer(ctx, jid, timer, time.Now()); err != nil {
+		return err
+	}
+	c.disappearing.set(jid, timer)
+	return nil
+}
+
+// GetDisappearingTimer returns the disappearing timer of a chat. Group timers are
+// read from the group; WhatsApp offers no query for private chats, so their timer
+// is the last one seen and known is false when none was.
+func (c *Client) GetDisappearingTimer(ctx context.Context, to string) (timer time.Duration, known bool, err error) {
+	jid := parseChatJID(to)
+	if jid.Server == types.GroupServer {
+		info, err := c.GetGroupInfo(ctx, jid)
+		if err != nil {
+			return 0, false, err
+		}
+		c.disappearing.set(jid, info.DisappearingTimer)
+		return info.DisappearingTimer, true, nil
+	}
+
+	timer, known = c.disappearing.get(jid)
+	return timer, known, nil
+}
+
+// rememberDisappearingTimer records the timer of a chat the application knows of,
+// such as the one stored with its conversation
+func (c *Client) rememberDisappearingTimer(to string, timer time.Duration) {
+	c.disappearing.set(parseChatJID(to), timer)
+}
+
+// withExpiration makes a message sent to a chat with disappearing messages expire
+// with the chat's timer
+func (c *Client) withExpiration(chat types.JID, msg *waE2E.Message) *waE2E.Message {
+	timer, ok := c.disappearing.get(chat)
+	if !ok || timer <= 0 {
+		return msg
+	}
+	expiration := &waE2E.ContextInfo{Expiration: proto.Uint32(uint32(timer.Seconds()))}
+
+	// Plain text messages carry no context, so they are sent as extended text
+	if text := msg.GetConversation(); text != "" {
+		msg.Conversation = nil
+		msg.ExtendedTextMessage = &waE2E.ExtendedTextMessage{Text: proto.String(text)}
+	}
+
+	var contexts []**waE2E.ContextInfo
+	if m := msg.GetExtendedTextMessage(); m != nil {
+		contexts = append(contexts, &m.ContextInfo)
+	}
+	if m := msg.GetImageMessage(); m != nil {
+		contexts = append(contexts, &m.ContextInfo)
+	}
+	if m := msg.GetVideoMessage(); m != nil {
+		contexts = append(contexts, &m.ContextInfo)
+	}
+	if m := msg.GetAudioMessage(); m != nil {
+		contexts = append(contexts, &m.ContextInfo)
+	}
+	if m := msg.GetDocumentMessage(); m != nil {
+		contexts = append(contexts, &m.ContextInfo)
+	}
+	if m := msg.GetStickerMessage(); m != nil {
+		contexts = append(contexts, &m.ContextInfo)
+	}
+	if m := msg.GetLocationMessage(); m != nil {
+		contexts = append(contexts, &m.ContextInfo)
+	}
+	for _, ctx := range contexts {
+		if *ctx == nil {
+			*ctx = expiration
+		} else {
+			(*ctx).Expiration = expiration.Expiration
+		}
+	}
+	return msg
+}
+
+// messageExpiration returns the disappearing timer, in seconds, a message was sent
+// with. ok is false for messages that cannot disappear, such as reactions.
+func messageExpiration(msg *waE2E.Message) (expiration uint32, ok bool) {
+	if msg.GetConversation() != "" {
+		return 0, true
+	}
+
+	var ctx *waE2E.ContextInfo
+	switch {
+	case msg.GetExtendedTextMessage() != nil:
+		ctx = msg.GetExtendedTextMessage().GetContextInfo()
+	case msg.GetImageMessage() != nil:
+		ctx = msg.GetImageMessage().GetContextInfo()
+	case msg.GetVideoMessage() != nil:
+		ctx = msg.GetVideoMessage().GetContextInfo()
+	case msg.GetAudioMessage() != nil:
+		ctx = msg.GetAudioMessage().GetContextInfo()
+	case msg.GetDocumentMessage() != nil:
+		ctx = msg.GetDocumentMessage().GetContextInfo()
+	case msg.GetStickerMessage() != nil:
+		ctx = msg.GetStickerMessage().GetContextInfo()
+	case msg.GetLocationMessage() != nil:
+		ctx = msg.GetLocationMessage().GetContextInfo()
+	case msg.GetContactMessage() != nil:
+		ctx = msg.GetContactMessage().GetContextInfo()
+	default:
+		return 0, false
+	}
+	return ctx.GetExpiration(), true
+}
+
+// convertDisappearingSetting returns the disappearing timer change a message carries
+// in a private chat, or nil for other messages
+func convertDisappearingSetting(evt *events.Message) *DisappearingTimerChange {
+	if evt == nil || evt.Message == nil {
+		return nil
+	}
+	protocol := evt.Message.GetProtocolMessage()
+	if protocol == nil || protocol.GetType() != waE2E.ProtocolMessage_EPHEMERAL_SETTING {
+		return nil
+	}
+
+	timestamp := evt.Info.Timestamp
+	if ts := protocol.GetEphemeralSettingTimestamp(); ts > 0 {
+		timestamp = time.Unix(ts, 0)
+	}
+	return &DisappearingTimerChange{
+		ChatJID:   evt.Info.Chat,
+		SenderJID: evt.Info.Sender,
+		IsFromMe:  evt.Info.IsFromMe,
+		IsGroup:   evt.Info.IsGroup,
+		Timer:     time.Duration(protocol.GetEphemeralExpiration()) * time.Second,
+		Timestamp: timestamp,
+	}
+}
+
+// convertGroupDisappearingSetting returns the disappearing timer change of a group
+// info update, or nil when the update changes something else
+func convertGroupDisappearingSetting(evt *events.GroupInfo) *DisappearingTimerChange {
+	if evt == nil || evt.Ephemeral == nil {
+		return nil
+	}
+
+	change := &DisappearingTimerChange{
+		ChatJID:   evt.JID,
+		IsGroup:   true,
+		Timestamp: evt.Timestamp,
+	}
+	if evt.Sender != nil {
+		change.SenderJID = *evt.Sender
+	}
+	if evt.Ephemeral.IsEphemeral {
+		change.Timer = time.Duration(evt.Ephemeral.DisappearingTimer) * time.Second
+	}
+	return change
+}
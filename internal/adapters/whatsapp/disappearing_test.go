@@ -0,0 +1,146 @@
+package whatsapp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
+)
+
+// DisappearingTestSuite tests disappearing message support
+type DisappearingTestSuite struct {
+	suite.Suite
+}
+
+func TestDisappearingTestSuite(t *testing.T) {
+	suite.Run(t, new(DisappearingTestSuite))
+}
+
+func (suite *DisappearingTestSuite) TestValidDisappearingTimer() {
+	assert.True(suite.T(), ValidDisappearingTimer(0))
+	assert.True(suite.T(), ValidDisappearingTimer(24*time.Hour))
+	assert.True(suite.T(), ValidDisappearingTimer(7*24*time.Hour))
+	assert.True(suite.T(), ValidDisappearingTimer(90*24*time.Hour))
+	assert.False(suite.T(), ValidDisappearingTimer(time.Hour))
+}
+
+func (suite *DisappearingTestSuite) TestConvertMessage_Expiration() {
+	evt := &events.Message{
+		Info: createMessageInfo("msg-1", "5511999999999", false, false),
+		Message: &waE2E.Message{
+			ExtendedTextMessage: &waE2E.ExtendedTextMessage{
+				Text:        proto.String("Some secret"),
+				ContextInfo: &waE2E.ContextInfo{Expiration: proto.Uint32(604800)},
+			},
+		},
+	}
+
+	msg := convertMessage(evt)
+	require.NotNil(suite.T(), msg)
+	assert.True(suite.T(), msg.HasExpiration)
+	assert.Equal(suite.T(), uint32(604800), msg.Expiration)
+
+	inbound := convertToInboundMessage(msg)
+	assert.Equal(suite.T(), "604800", inbound.Metadata["disappearing_timer"])
+	expiresAt, err := time.Parse(time.RFC3339, inbound.Metadata["ephemeral_expires_at"])
+	require.NoError(suite.T(), err)
+	assert.WithinDuration(suite.T(), msg.Timestamp.Add(7*24*time.Hour), expiresAt, time.Second)
+}
+
+func (suite *DisappearingTestSuite) TestConvertMessage_NoExpiration() {
+	evt := &events.Message{
+		Info:    createMessageInfo("msg-1", "5511999999999", false, false),
+		Message: &waE2E.Message{Conversation: proto.String("Hello")},
+	}
+
+	inbound := convertToInboundMessage(convertMessage(evt))
+	assert.Equal(suite.T(), "0", inbound.Metadata["disappearing_timer"], "plain messages report the timer is off")
+	assert.Empty(suite.T(), inbound.Metadata["ephemeral_expires_at"])
+
+	// Reactions say nothing about the chat's timer
+	evt.Message = &waE2E.Message{ReactionMessage: &waE2E.ReactionMessage{Text: proto.String("👍")}}
+	inbound = convertToInboundMessage(convertMessage(evt))
+	_, ok := inbound.Metadata["disappearing_timer"]
+	assert.False(suite.T(), ok)
+}
+
+func (suite *DisappearingTestSuite) TestHandleEvent_SettingChange() {
+	client := &Client{eventCh: make(chan any, 10)}
+	chat := types.NewJID("5511999999999", types.DefaultUserServer)
+
+	client.handleEvent(&events.Message{
+		Info: createMessageInfo("msg-1", "5511999999999", false, false),
+		Message: &waE2E.Message{
+			ProtocolMessage: &waE2E.ProtocolMessage{
+				Type:                      waE2E.ProtocolMessage_EPHEMERAL_SETTING.Enum(),
+				EphemeralExpiration:       proto.Uint32(86400),
+				EphemeralSettingTimestamp: proto.Int64(1760000000),
+			},
+		},
+	})
+
+	require.Len(suite.T(), client.eventCh, 1)
+	change, ok := (<-client.eventCh).(*DisappearingTimerChange)
+	require.True(suite.T(), ok, "setting changes are not delivered as messages")
+	assert.Equal(suite.T(), chat, change.ChatJID)
+	assert.Equal(suite.T(), 24*time.Hour, change.Timer)
+	assert.Equal(suite.T(), int64(1760000000), change.Timestamp.Unix())
+
+	timer, known := client.disappearing.get(chat)
+	assert.True(suite.T(), known)
+	assert.Equal(suite.T(), 24*time.Hour, timer)
+}
+
+func (suite *DisappearingTestSuite) TestHandleEvent_GroupSettingChange() {
+	client := &Client{eventCh: make(chan any, 10)}
+	group := types.NewJID("123456789", types.GroupServer)
+
+	client.handleEvent(&events.GroupInfo{
+		JID:       group,
+		Ephemeral: &types.GroupEphemeral{IsEphemeral: false},
+	})
+
+	change, ok := (<-client.eventCh).(*DisappearingTimerChange)
+	require.True(suite.T(), ok)
+	assert.True(suite.T(), change.IsGroup)
+	assert.Equal(suite.T(), time.Duration(0), change.Timer)
+
+	// Other group updates are not setting changes
+	client.handleEvent(&events.GroupInfo{JID: group, Name: &types.GroupName{Name: "Team"}})
+	assert.Len(suite.T(), client.eventCh, 0)
+}
+
+func (suite *DisappearingTestSuite) TestWithExpiration() {
+	client := &Client{}
+	chat := types.NewJID("5511999999999", types.DefaultUserServer)
+
+	msg := client.withExpiration(chat, &waE2E.Message{Conversation: proto.String("Hi")})
+	assert.Equal(suite.T(), "Hi", msg.GetConversation(), "chats without a timer are left alone")
+
+	client.rememberDisappearingTimer("5511999999999", 7*24*time.Hour)
+
+	msg = client.withExpiration(chat, &waE2E.Message{Conversation: proto.String("Hi")})
+	assert.Empty(suite.T(), msg.GetConversation())
+	assert.Equal(suite.T(), "Hi", msg.GetExtendedTextMessage().GetText())
+	assert.Equal(suite.T(), uint32(604800), msg.GetExtendedTextMessage().GetContextInfo().GetExpiration())
+
+	msg = client.withExpiration(chat, &waE2E.Message{
+		ImageMessage: &waE2E.ImageMessage{
+			Caption:     proto.String("photo"),
+			ContextInfo: &waE2E.ContextInfo{StanzaID: proto.String("quoted")},
+		},
+	})
+	assert.Equal(suite.T(), uint32(604800), msg.GetImageMessage().GetContextInfo().GetExpiration())
+	assert.Equal(suite.T(), "quoted", msg.GetImageMessage().GetContextInfo().GetStanzaID(), "existing context is kept")
+
+	// Senders parse bare numbers into a JID without user
+	bare, _ := types.ParseJID("5511999999999")
+	msg = client.withExpiration(bare, &waE2E.Message{Conversation: proto.String("Hi")})
+	assert.Equal(suite.T(), uint32(604800), msg.GetExtendedTextMessage().GetContextInfo().GetExpiration())
+}
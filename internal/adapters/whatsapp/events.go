@@ -46,8 +46,20 @@ func (c *Client) handleEvent(evt any) {
 		}
 
 	case *events.Message:
+		if change := convertDisappearingSetting(v); change != nil {
+			c.disappearing.set(change.ChatJID, change.Timer)
+			select {
+			case eventCh <- change:
+			default:
+			}
+			return
+		}
+
 		msg := convertMessage(v)
 		if msg != nil {
+			if msg.HasExpiration && !msg.IsGroup {
+				c.disappearing.set(msg.ChatJID, time.Duration(msg.Expiration)*time.Second)
+			}
 			select {
 			case eventCh <- msg:
 			default:
@@ -93,6 +105,15 @@ func (c *Client) handleEvent(evt any) {
 		default:
 		}
 
+	case *events.GroupInfo:
+		if change := convertGroupDisappearingSetting(v); change != nil {
+			c.disappearing.set(change.ChatJID, change.Timer)
+			select {
+			case eventCh <- change:
+			default:
+			}
+		}
+
 	case *events.HistorySync:
 		select {
 		case eventCh <- HistorySyncEvent{
@@ -260,6 +281,8 @@ func convertMessage(evt *events.Message) *IncomingMessage {
 		msg.Text = contact.GetDisplayName()
 	}
 
+	msg.Expiration, msg.HasExpiration = messageExpiration(evt.Message)
+
 	// Handle reaction message
 	if reaction := evt.Message.GetReactionMessage(); reaction != nil {
 		msg.MessageType = "reaction"
@@ -35,6 +35,13 @@ type Config struct {
 	// DatabasePath is the path to the SQLite database for storing session data
 	DatabasePath string
 
+	// SessionDSN is a PostgreSQL connection string. When set, the session is kept in
+	// PostgreSQL instead of the SQLite file, so any server instance can resume it.
+	SessionDSN string
+
+	// DeviceJID selects this channel's device in a shared session store
+	DeviceJID string
+
 	// AutoReconnect enables automatic reconnection
 	AutoReconnect bool
 
@@ -127,6 +134,12 @@ type IncomingMessage struct {
 	Reaction     *Reaction    `json:"reaction,omitempty"`
 	MessageType  string       `json:"message_type"`
 	RawMessage   any          `json:"raw_message,omitempty"`
+
+	// Disappearing timer of the chat in seconds when the message was sent; the message
+	// expires that long after its timestamp. HasExpiration is false for messages that
+	// cannot disappear, such as reactions.
+	Expiration    uint32 `json:"expiration,omitempty"`
+	HasExpiration bool   `json:"-"`
 }
 
 // Attachment represents a media attachment
@@ -229,6 +242,7 @@ type GroupInfo struct {
 	Participants []GroupParticipant `json:"participants"`
 	CreatedAt   time.Time        `json:"created_at"`
 	CreatedBy   types.JID        `json:"created_by"`
+	DisappearingTimer time.Duration `json:"disappearing_timer,omitempty"`
 }
 
 // GroupParticipant represents a participant in a group
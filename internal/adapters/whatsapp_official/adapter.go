@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -175,8 +176,8 @@ func (a *Adapter) SendMessage(ctx context.Context, msg *plugin.OutboundMessage)
 		}, nil
 	}
 
-	// Send message
-	resp, err := client.SendMessage(ctx, req)
+	// Send message, honouring the sender number selected for multi-number channels
+	resp, err := client.SendMessageFrom(ctx, msg.Metadata["phone_number_id"], req)
 	if err != nil {
 		return &plugin.SendResult{
 			Success:   false,
@@ -436,6 +437,16 @@ func (a *Adapter) buildInteractiveFromMetadata(msg *plugin.OutboundMessage) (*In
 		return nil, fmt.Errorf("interactive_type is required in metadata")
 	}
 
+	// Location and address requests may carry just the body text
+	if !strings.HasPrefix(strings.TrimSpace(msg.Content), "{") {
+		switch interactiveType {
+		case InteractiveTypeLocationRequest:
+			return NewLocationRequestMessage(msg.Content), nil
+		case InteractiveTypeAddress:
+			return NewAddressMessage(msg.Content, msg.Metadata["address_country"], nil, nil)
+		}
+	}
+
 	// Parse the full interactive object from content
 	var interactive InteractiveObject
 	if err := json.Unmarshal([]byte(msg.Content), &interactive); err != nil {
@@ -0,0 +1,164 @@
+package whatsapp_official
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// Interactive types that ask the customer for a location or a delivery address
+const (
+	InteractiveTypeLocationRequest = "location_request_message"
+	InteractiveTypeAddress         = "address_message"
+)
+
+// addressMessageCountries are the countries where WhatsApp supports address messages
+var addressMessageCountries = map[string]bool{
+	"IN": true,
+	"SG": true,
+}
+
+// IsAddressMessageCountry reports whether address messages are supported in a country
+func IsAddressMessageCountry(country string) bool {
+	return addressMessageCountries[strings.ToUpper(country)]
+}
+
+// AddressValues holds the fields of an address message. India uses in_pin_code and
+// Singapore sg_post_code.
+type AddressValues struct {
+	Name         string `json:"name,omitempty"`
+	PhoneNumber  string `json:"phone_number,omitempty"`
+	InPinCode    string `json:"in_pin_code,omitempty"`
+	SgPostCode   string `json:"sg_post_code,omitempty"`
+	HouseNumber  string `json:"house_number,omitempty"`
+	FloorNumber  string `json:"floor_number,omitempty"`
+	TowerNumber  string `json:"tower_number,omitempty"`
+	BuildingName string `json:"building_name,omitempty"`
+	Address      string `json:"address,omitempty"`
+	LandmarkArea string `json:"landmark_area,omitempty"`
+	UnitNumber   string `json:"unit_number,omitempty"`
+	City         string `json:"city,omitempty"`
+	State        string `json:"state,omitempty"`
+}
+
+// SavedAddress is an address the customer can pick instead of filling in the form
+type SavedAddress struct {
+	ID    string        `json:"id"`
+	Value AddressValues `json:"value"`
+}
+
+// AddressMessageParameters are the action parameters of an address message
+type AddressMessageParameters struct {
+	Country          string            `json:"country"`
+	Values           *AddressValues    `json:"values,omitempty"`
+	SavedAddresses   []SavedAddress    `json:"saved_addresses,omitempty"`
+	ValidationErrors map[string]string `json:"validation_errors,omitempty"`
+}
+
+// AddressResponse is the customer's reply to an address message
+type AddressResponse struct {
+	SavedAddressID string        `json:"saved_address_id,omitempty"`
+	Values         AddressValues `json:"values"`
+}
+
+// NewLocationRequestMessage creates a message asking the customer to share their location
+func NewLocationRequestMessage(bodyText string) *InteractiveObject {
+	return &InteractiveObject{
+		Type:   InteractiveTypeLocationRequest,
+		Body:   &InteractiveBody{Text: bodyText},
+		Action: &InteractiveAction{Name: "send_location"},
+	}
+}
+
+// NewAddressMessage creates a message asking the customer for a delivery address.
+// values prefills the form and may be nil.
+func NewAddressMessage(bodyText, country string, values *AddressValues, savedAddresses []SavedAddress) (*InteractiveObject, error) {
+	country = strings.ToUpper(country)
+	if !IsAddressMessageCountry(country) {
+		return nil, fmt.Errorf("address messages are not supported in country %q", country)
+	}
+
+	return &InteractiveObject{
+		Type: InteractiveTypeAddress,
+		Body: &InteractiveBody{Text: bodyText},
+		Action: &InteractiveAction{
+			Name: InteractiveTypeAddress,
+			Parameters: &AddressMessageParameters{
+				Country:        country,
+				Values:         values,
+				SavedAddresses: savedAddresses,
+			},
+		},
+	}, nil
+}
+
+// SendLocationRequest asks the customer to share their location
+func (s *InteractiveSender) SendLocationRequest(ctx context.Context, to, bodyText string) (*SendMessageResponse, error) {
+	return s.SendInteractive(ctx, to, NewLocationRequestMessage(bodyText))
+}
+
+// SendAddressMessage asks the customer for a delivery address
+func (s *InteractiveSender) SendAddressMessage(ctx context.Context, to, bodyText, country string, values *AddressValues, savedAddresses []SavedAddress) (*SendMessageResponse, error) {
+	interactive, err := NewAddressMessage(bodyText, country, values, savedAddresses)
+	if err != nil {
+		return nil, err
+	}
+	return s.SendInteractive(ctx, to, interactive)
+}
+
+// ParseAddressResponse parses the response_json of an address message reply
+func ParseAddressResponse(responseJSON string) (*AddressResponse, error) {
+	var response AddressResponse
+	if err := json.Unmarshal([]byte(responseJSON), &response); err != nil {
+		return nil, fmt.Errorf("failed to parse address response: %w", err)
+	}
+	return &response, nil
+}
+
+// ContactAddress normalizes the address fields into a contact address
+func (v AddressValues) ContactAddress() entity.ContactAddress {
+	address := entity.ContactAddress{
+		Source:    entity.ContactAddressSourceAddressMessage,
+		Name:      v.Name,
+		Phone:     v.PhoneNumber,
+		Landmark:  v.LandmarkArea,
+		City:      v.City,
+		State:     v.State,
+		UpdatedAt: time.Now(),
+	}
+
+	switch {
+	case v.InPinCode != "":
+		address.PostalCode = v.InPinCode
+		address.Country = "IN"
+	case v.SgPostCode != "":
+		address.PostalCode = v.SgPostCode
+		address.Country = "SG"
+	}
+
+	var street []string
+	for _, part := range []string{v.HouseNumber, v.UnitNumber, v.FloorNumber, v.TowerNumber, v.BuildingName, v.Address} {
+		if part = strings.TrimSpace(part); part != "" {
+			street = append(street, part)
+		}
+	}
+	address.Street = strings.Join(street, ", ")
+
+	return address
+}
+
+// LocationContactAddress normalizes a shared location into a contact address
+func LocationContactAddress(latitude, longitude float64, name, address string) entity.ContactAddress {
+	return entity.ContactAddress{
+		Source:    entity.ContactAddressSourceLocation,
+		Name:      name,
+		Street:    address,
+		Latitude:  &latitude,
+		Longitude: &longitude,
+		UpdatedAt: time.Now(),
+	}
+}
@@ -0,0 +1,129 @@
+package whatsapp_official
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewLocationRequestMessage(t *testing.T) {
+	data, err := json.Marshal(NewLocationRequestMessage("Where should we deliver?"))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "location_request_message",
+		"body": {"text": "Where should we deliver?"},
+		"action": {"name": "send_location"}
+	}`, string(data))
+}
+
+func TestNewAddressMessage(t *testing.T) {
+	interactive, err := NewAddressMessage("Your delivery address?", "in", &AddressValues{Name: "Asha", InPinCode: "400063"}, nil)
+	require.NoError(t, err)
+
+	data, err := json.Marshal(interactive)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"type": "address_message",
+		"body": {"text": "Your delivery address?"},
+		"action": {
+			"name": "address_message",
+			"parameters": {"country": "IN", "values": {"name": "Asha", "in_pin_code": "400063"}}
+		}
+	}`, string(data))
+
+	_, err = NewAddressMessage("Your delivery address?", "BR", nil, nil)
+	assert.Error(t, err)
+}
+
+func TestAdapter_BuildSendRequest_LocationAndAddress(t *testing.T) {
+	a := NewAdapter()
+
+	req, err := a.buildSendRequest(&plugin.OutboundMessage{
+		RecipientID: "919999999999",
+		ContentType: plugin.ContentTypeInteractive,
+		Content:     "Share your location",
+		Metadata:    map[string]string{"interactive_type": InteractiveTypeLocationRequest},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, InteractiveTypeLocationRequest, req.Interactive.Type)
+	assert.Equal(t, "send_location", req.Interactive.Action.Name)
+
+	req, err = a.buildSendRequest(&plugin.OutboundMessage{
+		RecipientID: "6599999999",
+		ContentType: plugin.ContentTypeInteractive,
+		Content:     "Your delivery address?",
+		Metadata:    map[string]string{"interactive_type": InteractiveTypeAddress, "address_country": "SG"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "SG", req.Interactive.Action.Parameters.(*AddressMessageParameters).Country)
+
+	// JSON content is passed through, keeping named action parameters
+	req, err = a.buildSendRequest(&plugin.OutboundMessage{
+		RecipientID: "919999999999",
+		ContentType: plugin.ContentTypeInteractive,
+		Content:     `{"type":"address_message","body":{"text":"Address?"},"action":{"name":"address_message","parameters":{"country":"IN"}}}`,
+		Metadata:    map[string]string{"interactive_type": InteractiveTypeAddress},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"country": "IN"}, req.Interactive.Action.Parameters)
+}
+
+func TestAddressValues_ContactAddress(t *testing.T) {
+	response, err := ParseAddressResponse(`{
+		"saved_address_id": "home",
+		"values": {
+			"name": "Asha", "phone_number": "+919999999999", "in_pin_code": "400063",
+			"house_number": "12", "floor_number": "3", "building_name": "Sunrise Towers",
+			"address": "MG Road", "landmark_area": "Near the park", "city": "Mumbai", "state": "Maharashtra"
+		}
+	}`)
+	require.NoError(t, err)
+	assert.Equal(t, "home", response.SavedAddressID)
+
+	address := response.Values.ContactAddress()
+	assert.Equal(t, entity.ContactAddressSourceAddressMessage, address.Source)
+	assert.Equal(t, "12, 3, Sunrise Towers, MG Road", address.Street)
+	assert.Equal(t, "400063", address.PostalCode)
+	assert.Equal(t, "IN", address.Country)
+	assert.Equal(t, "Mumbai", address.City)
+	assert.Equal(t, "Near the park", address.Landmark)
+}
+
+func TestExtractMessages_Interactive_AddressReply(t *testing.T) {
+	proc := testProcessor()
+	payload := buildMessagePayload(
+		IncomingMessage{
+			ID:        "msg-address-1",
+			From:      "6599999999",
+			Timestamp: "1700000000",
+			Type:      MessageTypeInteractive,
+			Interactive: &InteractiveResponse{
+				Type: "nfm_reply",
+				NfmReply: &NfmReplyData{
+					Name:         InteractiveTypeAddress,
+					Body:         "1 Raffles Place",
+					ResponseJSON: `{"values":{"name":"Wei","sg_post_code":"048616","address":"1 Raffles Place","unit_number":"#10-01"}}`,
+				},
+			},
+		},
+		defaultContacts(),
+	)
+
+	msgs := proc.ExtractMessages(payload)
+	require.Len(t, msgs, 1)
+	m := msgs[0]
+
+	assert.Equal(t, "1 Raffles Place", m.Content)
+	assert.Equal(t, "true", m.Metadata["is_address_response"])
+	assert.Empty(t, m.Metadata["is_flow_response"])
+
+	var address entity.ContactAddress
+	require.NoError(t, json.Unmarshal([]byte(m.Metadata["contact_address"]), &address))
+	assert.Equal(t, "SG", address.Country)
+	assert.Equal(t, "048616", address.PostalCode)
+	assert.Equal(t, "#10-01, 1 Raffles Place", address.Street)
+}
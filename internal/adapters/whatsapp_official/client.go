@@ -49,6 +49,16 @@ func NewClient(config *Config) *Client {
 
 // SendMessage sends a message via the WhatsApp Cloud API
 func (c *Client) SendMessage(ctx context.Context, req *SendMessageRequest) (*SendMessageResponse, error) {
+	return c.SendMessageFrom(ctx, c.config.PhoneNumberID, req)
+}
+
+// SendMessageFrom sends a message from a specific phone number of the WABA.
+// An empty phoneNumberID falls back to the configured phone number.
+func (c *Client) SendMessageFrom(ctx context.Context, phoneNumberID string, req *SendMessageRequest) (*SendMessageResponse, error) {
+	if phoneNumberID == "" {
+		phoneNumberID = c.config.PhoneNumberID
+	}
+
 	// Wait for rate limiter
 	if err := c.rateLimiter.Wait(ctx); err != nil {
 		return nil, fmt.Errorf("rate limiter error: %w", err)
@@ -59,7 +69,7 @@ func (c *Client) SendMessage(ctx context.Context, req *SendMessageRequest) (*Sen
 		req.RecipientType = "individual"
 	}
 
-	endpoint := c.buildURL(fmt.Sprintf("/%s/messages", c.config.PhoneNumberID))
+	endpoint := c.buildURL(fmt.Sprintf("/%s/messages", phoneNumberID))
 
 	body, err := json.Marshal(req)
 	if err != nil {
@@ -8,7 +8,7 @@ import (
 
 // InteractiveObject represents an interactive message
 type InteractiveObject struct {
-	Type   string              `json:"type"` // button, list, product, product_list
+	Type   string              `json:"type"` // button, list, product, product_list, location_request_message, address_message
 	Header *InteractiveHeader  `json:"header,omitempty"`
 	Body   *InteractiveBody    `json:"body"`
 	Footer *InteractiveFooter  `json:"footer,omitempty"`
@@ -47,6 +47,10 @@ type InteractiveAction struct {
 	CatalogID         string          `json:"catalog_id,omitempty"`
 	ProductRetailerID string          `json:"product_retailer_id,omitempty"`
 	ProductSections   []ProductSection `json:"product_sections,omitempty"`
+
+	// For named actions: send_location, address_message, cta_url
+	Name       string      `json:"name,omitempty"`
+	Parameters interface{} `json:"parameters,omitempty"`
 }
 
 // InteractiveButton represents a button in interactive message (max 3)
@@ -99,6 +99,38 @@ type WebhookChangeValue struct {
 	MaxPhoneNumbersPerBusiness   int    `json:"max_phone_numbers_per_business,omitempty"`
 	MaxPhoneNumbersPerWaba       int    `json:"max_phone_numbers_per_waba,omitempty"`
 	CoexistenceStatus            string `json:"coexistence_status,omitempty"`
+
+	// smb_app_state_sync field (coexistence numbers)
+	StateSync []StateSyncItem `json:"state_sync,omitempty"`
+}
+
+// StateSyncItem is a change made in the WhatsApp Business App of a coexistence number
+type StateSyncItem struct {
+	Type     string             `json:"type"`   // contact or label
+	Action   string             `json:"action"` // add, edit or remove
+	Contact  *StateSyncContact  `json:"contact,omitempty"`
+	Label    *StateSyncLabel    `json:"label,omitempty"`
+	Metadata *StateSyncMetadata `json:"metadata,omitempty"`
+}
+
+// StateSyncContact is a Business App address book entry. Labels is only present when
+// the Cloud API exposes the labels of the contact; an empty list means none.
+type StateSyncContact struct {
+	FullName    string           `json:"full_name,omitempty"`
+	FirstName   string           `json:"first_name,omitempty"`
+	PhoneNumber string           `json:"phone_number"`
+	Labels      []StateSyncLabel `json:"labels,omitempty"`
+}
+
+// StateSyncLabel is a Business App label
+type StateSyncLabel struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// StateSyncMetadata holds the time of a state sync change
+type StateSyncMetadata struct {
+	Timestamp string `json:"timestamp"`
 }
 
 // WebhookMetadata represents metadata in the webhook
@@ -335,6 +367,7 @@ type ReferralContent struct {
 	ImageURL     string `json:"image_url,omitempty"`
 	VideoURL     string `json:"video_url,omitempty"`
 	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+	CtwaClid     string `json:"ctwa_clid,omitempty"` // Click ID for conversion reporting
 }
 
 // StatusUpdate represents a message status update
@@ -580,6 +613,7 @@ const (
 	WebhookFieldFlows                        WebhookField = "flows"
 	WebhookFieldBusinessCapabilityUpdate     WebhookField = "business_capability_update"
 	WebhookFieldMessageEchoes                WebhookField = "message_echoes"
+	WebhookFieldSMBAppStateSync              WebhookField = "smb_app_state_sync"
 )
 
 // TemplateStatusUpdateValue represents a template status update webhook value
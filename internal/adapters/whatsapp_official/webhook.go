@@ -273,6 +273,10 @@ func (p *WebhookProcessor) parseMessage(msg *IncomingMessage, contacts map[strin
 			if msg.Location.Address != "" {
 				parsed.Metadata["location_address"] = msg.Location.Address
 			}
+			address := LocationContactAddress(msg.Location.Latitude, msg.Location.Longitude, msg.Location.Name, msg.Location.Address)
+			if data, err := json.Marshal(address); err == nil {
+				parsed.Metadata["contact_address"] = string(data)
+			}
 		}
 
 	case MessageTypeContacts:
@@ -298,6 +302,18 @@ func (p *WebhookProcessor) parseMessage(msg *IncomingMessage, contacts map[strin
 				if msg.Interactive.ListReply.Description != "" {
 					parsed.Metadata["list_description"] = msg.Interactive.ListReply.Description
 				}
+			} else if msg.Interactive.NfmReply != nil && msg.Interactive.NfmReply.Name == InteractiveTypeAddress {
+				// Reply to an address message
+				parsed.Metadata["is_address_response"] = "true"
+				parsed.Content = msg.Interactive.NfmReply.Body
+				if response, err := ParseAddressResponse(msg.Interactive.NfmReply.ResponseJSON); err == nil {
+					if address, err := json.Marshal(response.Values.ContactAddress()); err == nil {
+						parsed.Metadata["contact_address"] = string(address)
+					}
+					if response.SavedAddressID != "" {
+						parsed.Metadata["saved_address_id"] = response.SavedAddressID
+					}
+				}
 			} else if msg.Interactive.NfmReply != nil {
 				// WhatsApp Flow response (Native Flow Message)
 				parsed.Metadata["is_flow_response"] = "true"
@@ -364,6 +380,19 @@ func (p *WebhookProcessor) parseMessage(msg *IncomingMessage, contacts map[strin
 		if msg.Referral.Body != "" {
 			parsed.Metadata["referral_body"] = msg.Referral.Body
 		}
+		optional := map[string]string{
+			"referral_source_url":    msg.Referral.SourceURL,
+			"referral_media_type":    msg.Referral.MediaType,
+			"referral_image_url":     msg.Referral.ImageURL,
+			"referral_video_url":     msg.Referral.VideoURL,
+			"referral_thumbnail_url": msg.Referral.ThumbnailURL,
+			"referral_ctwa_clid":     msg.Referral.CtwaClid,
+		}
+		for key, value := range optional {
+			if value != "" {
+				parsed.Metadata[key] = value
+			}
+		}
 	}
 
 	return parsed
@@ -488,6 +517,7 @@ const (
 	FieldFlows                    WebhookFieldType = "flows"
 	FieldBusinessCapabilityUpdate WebhookFieldType = "business_capability_update"
 	FieldMessageEchoes            WebhookFieldType = "message_echoes"
+	FieldSMBAppStateSync          WebhookFieldType = "smb_app_state_sync"
 )
 
 // GetWebhookFields returns all fields present in the webhook payload
@@ -983,3 +1013,57 @@ func IsMessageEchoWebhook(payload *WebhookPayload) bool {
 	}
 	return false
 }
+
+// ParsedStateSync is a contact or label change made in the WhatsApp Business App of a
+// coexistence number
+type ParsedStateSync struct {
+	Type          string // contact or label
+	Action        string // add, edit or remove
+	PhoneNumber   string
+	FullName      string
+	Labels        []StateSyncLabel // Labels of the contact
+	HasLabels     bool             // Whether the payload exposed the contact's labels
+	Label         *StateSyncLabel
+	PhoneNumberID string
+	Timestamp     time.Time
+}
+
+// ExtractStateSync extracts Business App contact and label changes from webhook payload
+func (p *WebhookProcessor) ExtractStateSync(payload *WebhookPayload) []*ParsedStateSync {
+	var changes []*ParsedStateSync
+
+	for _, entry := range payload.Entry {
+		for _, change := range entry.Changes {
+			if change.Field != string(FieldSMBAppStateSync) {
+				continue
+			}
+
+			for _, item := range change.Value.StateSync {
+				parsed := &ParsedStateSync{
+					Type:          item.Type,
+					Action:        item.Action,
+					Label:         item.Label,
+					PhoneNumberID: change.Value.Metadata.PhoneNumberID,
+					Timestamp:     time.Now(),
+				}
+				if item.Metadata != nil {
+					if ts, err := strconv.ParseInt(item.Metadata.Timestamp, 10, 64); err == nil {
+						parsed.Timestamp = time.Unix(ts, 0)
+					}
+				}
+				if item.Contact != nil {
+					parsed.PhoneNumber = item.Contact.PhoneNumber
+					parsed.FullName = item.Contact.FullName
+					if parsed.FullName == "" {
+						parsed.FullName = item.Contact.FirstName
+					}
+					parsed.Labels = item.Contact.Labels
+					parsed.HasLabels = item.Contact.Labels != nil
+				}
+				changes = append(changes, parsed)
+			}
+		}
+	}
+
+	return changes
+}
@@ -703,6 +703,8 @@ func TestExtractMessages_Referral(t *testing.T) {
 				SourceID:   "ad-12345",
 				Headline:   "Summer Sale",
 				Body:       "50% off",
+				SourceURL:  "https://fb.me/summer",
+				CtwaClid:   "clid-123",
 			},
 		},
 		defaultContacts(),
@@ -716,6 +718,9 @@ func TestExtractMessages_Referral(t *testing.T) {
 	assert.Equal(t, "ad-12345", m.Metadata["referral_source_id"])
 	assert.Equal(t, "Summer Sale", m.Metadata["referral_headline"])
 	assert.Equal(t, "50% off", m.Metadata["referral_body"])
+	assert.Equal(t, "https://fb.me/summer", m.Metadata["referral_source_url"])
+	assert.Equal(t, "clid-123", m.Metadata["referral_ctwa_clid"])
+	assert.NotContains(t, m.Metadata, "referral_image_url", "empty referral fields are not stored")
 }
 
 // ---------------------------------------------------------------------------
@@ -1494,6 +1499,53 @@ func TestExtractStatuses_InvalidTimestamp(t *testing.T) {
 	assert.True(t, statuses[0].Timestamp.Before(after))
 }
 
+// ---------------------------------------------------------------------------
+// ExtractStateSync: Business App contacts and labels
+// ---------------------------------------------------------------------------
+
+func TestExtractStateSync_ContactsAndLabels(t *testing.T) {
+	proc := testProcessor()
+	body := []byte(`{
+		"object": "whatsapp_business_account",
+		"entry": [{"id": "WABA", "changes": [{"field": "smb_app_state_sync", "value": {
+			"messaging_product": "whatsapp",
+			"metadata": {"display_phone_number": "15550001111", "phone_number_id": "PN-1"},
+			"state_sync": [
+				{"type": "contact", "action": "add", "metadata": {"timestamp": "1700000000"},
+				 "contact": {"full_name": "Maria Silva", "phone_number": "5511999998888",
+				             "labels": [{"id": "1", "name": "VIP"}]}},
+				{"type": "contact", "action": "add", "contact": {"first_name": "Joao", "phone_number": "5511977776666", "labels": []}},
+				{"type": "contact", "action": "add", "contact": {"full_name": "No Labels", "phone_number": "5511911112222"}},
+				{"type": "label", "action": "edit", "label": {"id": "1", "name": "Gold"}}
+			]
+		}}]}]
+	}`)
+
+	payload, err := proc.ParseWebhook(body)
+	require.NoError(t, err)
+
+	changes := proc.ExtractStateSync(payload)
+	require.Len(t, changes, 4)
+
+	assert.Equal(t, "contact", changes[0].Type)
+	assert.Equal(t, "Maria Silva", changes[0].FullName)
+	assert.Equal(t, "PN-1", changes[0].PhoneNumberID)
+	assert.Equal(t, time.Unix(1700000000, 0), changes[0].Timestamp)
+	assert.True(t, changes[0].HasLabels)
+	assert.Equal(t, []StateSyncLabel{{ID: "1", Name: "VIP"}}, changes[0].Labels)
+
+	assert.Equal(t, "Joao", changes[1].FullName)
+	assert.True(t, changes[1].HasLabels, "an empty list means the contact has no labels")
+	assert.Empty(t, changes[1].Labels)
+	assert.False(t, changes[2].HasLabels)
+
+	assert.Equal(t, "label", changes[3].Type)
+	assert.Equal(t, "edit", changes[3].Action)
+	assert.Equal(t, "Gold", changes[3].Label.Name)
+
+	assert.Empty(t, proc.ExtractStateSync(buildFieldPayload("messages", WebhookChangeValue{})))
+}
+
 // ---------------------------------------------------------------------------
 // Suppress unused import warning for fmt (used via helpers)
 // ---------------------------------------------------------------------------
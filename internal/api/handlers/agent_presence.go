@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+)
+
+// maxPresenceAgents caps how many users the presence listing loads per tenant
+const maxPresenceAgents = 500
+
+// AgentPresenceHandler exposes agent availability tracked by the WebSocket hub
+type AgentPresenceHandler struct {
+	hub         *AgentHub
+	userService *service.UserService
+}
+
+// NewAgentPresenceHandler creates a new agent presence handler
+func NewAgentPresenceHandler(hub *AgentHub, userService *service.UserService) *AgentPresenceHandler {
+	return &AgentPresenceHandler{
+		hub:         hub,
+		userService: userService,
+	}
+}
+
+// AgentPresenceResponse lists the availability of a tenant's agents
+type AgentPresenceResponse struct {
+	Agents  []*entity.AgentPresence `json:"agents"`
+	Summary entity.PresenceSummary  `json:"summary"`
+}
+
+// SetPresenceRequest represents a presence status change
+type SetPresenceRequest struct {
+	Status string `json:"status" binding:"required"` // online, away, busy
+}
+
+// List godoc
+// @Summary      List agent presence
+// @Description  Returns the availability of every active user of the tenant. Agents without a live WebSocket connection or whose heartbeats stopped are reported as offline.
+// @Tags         agents
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=AgentPresenceResponse}
+// @Failure      401 {object} Response
+// @Router       /agents/presence [get]
+func (h *AgentPresenceHandler) List(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	byUser := make(map[string]*entity.AgentPresence)
+	for _, presence := range h.hub.GetPresence(tenantID) {
+		byUser[presence.UserID] = presence
+	}
+
+	params := repository.NewListParams()
+	params.PageSize = maxPresenceAgents
+	users, _, err := h.userService.List(c.Request.Context(), tenantID, params)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	agents := make([]*entity.AgentPresence, 0, len(users))
+	for _, user := range users {
+		if user.Status != entity.UserStatusActive {
+			continue
+		}
+		presence, ok := byUser[user.ID]
+		if !ok {
+			presence = &entity.AgentPresence{
+				UserID:   user.ID,
+				TenantID: tenantID,
+				Status:   entity.PresenceStatusOffline,
+				LastSeen: user.LastLoginAt,
+			}
+		}
+		presence.Name = user.Name
+		presence.Email = user.Email
+		agents = append(agents, presence)
+	}
+	sort.Slice(agents, func(i, j int) bool {
+		return agents[i].Name < agents[j].Name
+	})
+
+	resp := &AgentPresenceResponse{Agents: agents}
+	for _, agent := range agents {
+		switch agent.Status {
+		case entity.PresenceStatusOnline:
+			resp.Summary.Online++
+		case entity.PresenceStatusAway:
+			resp.Summary.Away++
+		case entity.PresenceStatusBusy:
+			resp.Summary.Busy++
+		default:
+			resp.Summary.Offline++
+		}
+	}
+
+	RespondSuccess(c, resp)
+}
+
+// Set godoc
+// @Summary      Set own presence
+// @Description  Sets the current user's availability. Only online agents are picked by automatic assignment; away and busy agents keep their connection but receive no new conversations.
+// @Tags         agents
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body SetPresenceRequest true "Presence status"
+// @Success      200 {object} Response{data=entity.AgentPresence}
+// @Failure      400 {object} Response
+// @Failure      401 {object} Response
+// @Router       /agents/presence [put]
+func (h *AgentPresenceHandler) Set(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req SetPresenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	presence, err := h.hub.SetPresence(tenantID, middleware.GetUserID(c), entity.PresenceStatus(req.Status))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, presence)
+}
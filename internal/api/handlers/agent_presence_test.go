@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgentPresenceHandler_List(t *testing.T) {
+	userRepo := testutil.NewMockUserRepository()
+	for _, u := range []*entity.User{
+		{ID: "user-1", TenantID: "tenant-1", Name: "Alice", Role: entity.UserRoleAgent, Status: entity.UserStatusActive},
+		{ID: "user-2", TenantID: "tenant-1", Name: "Bob", Role: entity.UserRoleAgent, Status: entity.UserStatusActive},
+		{ID: "user-3", TenantID: "tenant-1", Name: "Carol", Role: entity.UserRoleAgent, Status: entity.UserStatusSuspended},
+	} {
+		userRepo.Users[u.ID] = u
+	}
+	hub := NewAgentHub()
+	client := &AgentClient{hub: hub, UserID: "user-1", TenantID: "tenant-1"}
+	hub.clients[client.UserID] = client
+	hub.markConnected(client)
+
+	handler := NewAgentPresenceHandler(hub, service.NewUserService(userRepo, testutil.NewMockTenantRepository()))
+
+	w, c := newTestContext(http.MethodGet, "/agents/presence", nil)
+	c.Set("tenant_id", "tenant-1")
+	handler.List(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data AgentPresenceResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Data.Agents, 2)
+	assert.Equal(t, "Alice", resp.Data.Agents[0].Name)
+	assert.Equal(t, entity.PresenceStatusOnline, resp.Data.Agents[0].Status)
+	assert.Equal(t, entity.PresenceStatusOffline, resp.Data.Agents[1].Status)
+	assert.Equal(t, entity.PresenceSummary{Online: 1, Offline: 1}, resp.Data.Summary)
+}
+
+func TestAgentPresenceHandler_Set(t *testing.T) {
+	hub := NewAgentHub()
+	handler := NewAgentPresenceHandler(hub, nil)
+
+	w, c := newTestContext(http.MethodPut, "/agents/presence", SetPresenceRequest{Status: "away"})
+	c.Set("tenant_id", "tenant-1")
+	c.Set("user_id", "user-1")
+	handler.Set(c)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, entity.PresenceStatusAway, hub.presence["user-1"].Status)
+
+	w, c = newTestContext(http.MethodPut, "/agents/presence", SetPresenceRequest{Status: "sleeping"})
+	c.Set("tenant_id", "tenant-1")
+	c.Set("user_id", "user-1")
+	handler.Set(c)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestAgentHub_UnavailableSince(t *testing.T) {
+	start := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	now := start
+	hub := NewAgentHub()
+	hub.now = func() time.Time { return now }
+	hub.started = start
+
+	status, since, unavailable := hub.UnavailableSince("tenant-1", "user-1")
+	assert.True(t, unavailable)
+	assert.Equal(t, entity.PresenceStatusOffline, status)
+	assert.Equal(t, start, since, "agents never seen count from the hub start")
+
+	client := &AgentClient{hub: hub, UserID: "user-1", TenantID: "tenant-1"}
+	hub.clients[client.UserID] = client
+	hub.markConnected(client)
+	_, _, unavailable = hub.UnavailableSince("tenant-1", "user-1")
+	assert.False(t, unavailable)
+
+	now = start.Add(time.Minute)
+	_, err := hub.SetPresence("tenant-1", "user-1", entity.PresenceStatusAway)
+	require.NoError(t, err)
+	status, since, unavailable = hub.UnavailableSince("tenant-1", "user-1")
+	assert.True(t, unavailable)
+	assert.Equal(t, entity.PresenceStatusAway, status)
+	assert.Equal(t, now, since)
+
+	now = start.Add(2 * time.Minute)
+	delete(hub.clients, client.UserID)
+	hub.markDisconnected(client)
+	status, since, unavailable = hub.UnavailableSince("tenant-1", "user-1")
+	assert.True(t, unavailable)
+	assert.Equal(t, entity.PresenceStatusOffline, status)
+	assert.Equal(t, now, since)
+}
+
+func TestAgentHub_PresenceChangedSince(t *testing.T) {
+	start := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	now := start
+	hub := NewAgentHub()
+	hub.now = func() time.Time { return now }
+
+	client := &AgentClient{hub: hub, UserID: "user-1", TenantID: "tenant-1"}
+	hub.clients[client.UserID] = client
+	hub.markConnected(client)
+
+	changed := hub.PresenceChangedSince("tenant-1", start.Add(-time.Second))
+	require.Len(t, changed, 1)
+	assert.Equal(t, entity.PresenceStatusOnline, changed[0].Status)
+	assert.Empty(t, hub.PresenceChangedSince("tenant-1", start))
+	assert.Empty(t, hub.PresenceChangedSince("tenant-2", start.Add(-time.Second)))
+
+	// Heartbeats stopping turns the agent offline without an update of its own
+	now = start.Add(presenceTimeout + time.Second)
+	changed = hub.PresenceChangedSince("tenant-1", start)
+	require.Len(t, changed, 1)
+	assert.Equal(t, entity.PresenceStatusOffline, changed[0].Status)
+	assert.Equal(t, start.Add(presenceTimeout), changed[0].UpdatedAt)
+}
@@ -129,7 +129,8 @@ func (h *AIHandler) Complete(c *gin.Context) {
 		Temperature: temperature,
 	}
 
-	response, err := provider.Complete(c.Request.Context(), completionReq)
+	ctx := service.WithAIUsage(c.Request.Context(), middleware.MustGetTenantID(c), "", entity.AIFeaturePlayground)
+	response, err := provider.Complete(ctx, completionReq)
 	if err != nil {
 		RespondError(c, err)
 		return
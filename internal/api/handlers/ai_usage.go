@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// AIUsageHandler handles the AI usage, cost and budget of tenants
+type AIUsageHandler struct {
+	usageService *service.AIUsageService
+}
+
+// NewAIUsageHandler creates a new AI usage handler
+func NewAIUsageHandler(usageService *service.AIUsageService) *AIUsageHandler {
+	return &AIUsageHandler{usageService: usageService}
+}
+
+// GetReport godoc
+// @Summary      Get AI usage
+// @Description  Returns the AI requests, tokens and estimated cost of a month grouped by bot, feature and model, with the budget of the current month
+// @Tags         usage
+// @Produce      json
+// @Security     BearerAuth
+// @Param        month query string false "Month as YYYY-MM (default: current month)"
+// @Success      200 {object} Response{data=entity.AIUsageReport}
+// @Failure      400 {object} Response
+// @Router       /usage [get]
+func (h *AIUsageHandler) GetReport(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	month, ok := parseUsageMonth(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.usageService.GetReport(c.Request.Context(), tenantID, month)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, report)
+}
+
+// GetMonths godoc
+// @Summary      Get monthly AI usage
+// @Description  Returns the AI requests, tokens and estimated cost of each of the last months, oldest first
+// @Tags         usage
+// @Produce      json
+// @Security     BearerAuth
+// @Param        months query int false "Number of months, up to 24 (default: 12)"
+// @Success      200 {object} Response{data=[]entity.AIUsageMonth}
+// @Router       /usage/monthly [get]
+func (h *AIUsageHandler) GetMonths(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	months, _ := strconv.Atoi(c.Query("months"))
+	usage, err := h.usageService.GetMonths(c.Request.Context(), tenantID, months)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, usage)
+}
+
+// ListRecords godoc
+// @Summary      List AI calls
+// @Description  Lists the metered AI calls of a month, newest first. Calls are kept for 90 days.
+// @Tags         usage
+// @Produce      json
+// @Security     BearerAuth
+// @Param        month query string false "Month as YYYY-MM (default: current month)"
+// @Param        limit query int false "Maximum calls, up to 500"
+// @Success      200 {object} Response{data=[]entity.AIUsageRecord}
+// @Failure      400 {object} Response
+// @Router       /usage/records [get]
+func (h *AIUsageHandler) ListRecords(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	month, ok := parseUsageMonth(c)
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	records, err := h.usageService.ListRecords(c.Request.Context(), tenantID, month, limit)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, records)
+}
+
+// GetBudget godoc
+// @Summary      Get AI budget
+// @Description  Returns the AI spending of the current month against the tenant's soft and hard limits
+// @Tags         usage
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=entity.AIBudgetStatus}
+// @Router       /usage/budget [get]
+func (h *AIUsageHandler) GetBudget(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	budget, err := h.usageService.GetBudget(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, budget)
+}
+
+// UpdateBudget godoc
+// @Summary      Update AI budget
+// @Description  Sets the monthly AI spending limits in USD. Crossing the soft limit alerts the tenant; reaching the hard limit pauses AI features until the month ends or the limit is raised. Zero removes a limit.
+// @Tags         usage
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body entity.AIBudget true "Budget"
+// @Success      200 {object} Response{data=entity.AIBudgetStatus}
+// @Failure      400 {object} Response
+// @Router       /usage/budget [put]
+func (h *AIUsageHandler) UpdateBudget(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req entity.AIBudget
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	budget, err := h.usageService.UpdateBudget(c.Request.Context(), tenantID, req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, budget)
+}
+
+// parseUsageMonth reads the month query parameter, defaulting to the current month
+func parseUsageMonth(c *gin.Context) (time.Time, bool) {
+	value := c.Query("month")
+	if value == "" {
+		return time.Now(), true
+	}
+	month, err := time.Parse("2006-01", value)
+	if err != nil {
+		RespondValidationError(c, "month must be formatted as YYYY-MM", nil)
+		return time.Time{}, false
+	}
+	return month, true
+}
@@ -13,6 +13,10 @@ import (
 // AnalyticsHandler handles analytics endpoints
 type AnalyticsHandler struct {
 	analyticsService *service.AnalyticsService
+	costService      *service.WhatsAppCostService
+	qaService        *service.QAService
+	widgetService    *service.WidgetSettingsService
+	copilotService   *service.CopilotService
 }
 
 // NewAnalyticsHandler creates a new analytics handler
@@ -22,6 +26,26 @@ func NewAnalyticsHandler(analyticsService *service.AnalyticsService) *AnalyticsH
 	}
 }
 
+// SetWhatsAppCostService enables the WhatsApp cost attribution endpoints
+func (h *AnalyticsHandler) SetWhatsAppCostService(costService *service.WhatsAppCostService) {
+	h.costService = costService
+}
+
+// SetQAService enables the agent quality endpoint
+func (h *AnalyticsHandler) SetQAService(qaService *service.QAService) {
+	h.qaService = qaService
+}
+
+// SetWidgetSettingsService enables the quick reply analytics endpoint
+func (h *AnalyticsHandler) SetWidgetSettingsService(widgetService *service.WidgetSettingsService) {
+	h.widgetService = widgetService
+}
+
+// SetCopilotService enables the agent copilot analytics endpoint
+func (h *AnalyticsHandler) SetCopilotService(copilotService *service.CopilotService) {
+	h.copilotService = copilotService
+}
+
 // parseAnalyticsParams extracts common analytics parameters from the request
 func (h *AnalyticsHandler) parseAnalyticsParams(c *gin.Context) (entity.AnalyticsPeriod, time.Time, time.Time) {
 	periodStr := c.DefaultQuery("period", "weekly")
@@ -182,3 +206,285 @@ func (h *AnalyticsHandler) GetChannels(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"data": channels})
 }
+
+// GetLabels godoc
+// @Summary      Get label analytics
+// @Description  Returns conversation counts and average resolution time for each conversation label
+// @Tags         analytics
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        period query string false "Time period (daily, weekly, monthly)" default(weekly)
+// @Param        start_date query string false "Custom start date (YYYY-MM-DD)"
+// @Param        end_date query string false "Custom end date (YYYY-MM-DD)"
+// @Success      200 {object} Response{data=[]entity.LabelAnalytics}
+// @Failure      401 {object} Response
+// @Failure      500 {object} Response
+// @Router       /analytics/labels [get]
+func (h *AnalyticsHandler) GetLabels(c *gin.Context) {
+	tenantID := middleware.GetTenantID(c)
+	_, startDate, endDate := h.parseAnalyticsParams(c)
+
+	labels, err := h.analyticsService.GetLabelAnalytics(c.Request.Context(), tenantID, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get label analytics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": labels})
+}
+
+// GetDispositions godoc
+// @Summary      Get disposition analytics
+// @Description  Returns the volume, share, handle time, resolution time and CSAT of the conversations resolved with each disposition, to show what customers contact about
+// @Tags         analytics
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        period query string false "Time period (daily, weekly, monthly)" default(weekly)
+// @Param        start_date query string false "Custom start date (YYYY-MM-DD)"
+// @Param        end_date query string false "Custom end date (YYYY-MM-DD)"
+// @Success      200 {object} Response{data=[]entity.DispositionAnalytics}
+// @Failure      401 {object} Response
+// @Failure      500 {object} Response
+// @Router       /analytics/dispositions [get]
+func (h *AnalyticsHandler) GetDispositions(c *gin.Context) {
+	tenantID := middleware.GetTenantID(c)
+	_, startDate, endDate := h.parseAnalyticsParams(c)
+
+	dispositions, err := h.analyticsService.GetDispositionAnalytics(c.Request.Context(), tenantID, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get disposition analytics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": dispositions})
+}
+
+// GetReopens godoc
+// @Summary      Get reopen analytics
+// @Description  Returns the reopen rate of conversations resolved in the period, split into customer and agent reopens, with the follow-up conversations started after the reopen window
+// @Tags         analytics
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        period query string false "Time period (daily, weekly, monthly)" default(weekly)
+// @Param        start_date query string false "Custom start date (YYYY-MM-DD)"
+// @Param        end_date query string false "Custom end date (YYYY-MM-DD)"
+// @Success      200 {object} Response{data=entity.ReopenAnalytics}
+// @Failure      401 {object} Response
+// @Failure      500 {object} Response
+// @Router       /analytics/reopens [get]
+func (h *AnalyticsHandler) GetReopens(c *gin.Context) {
+	tenantID := middleware.GetTenantID(c)
+	_, startDate, endDate := h.parseAnalyticsParams(c)
+
+	reopens, err := h.analyticsService.GetReopenAnalytics(c.Request.Context(), tenantID, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get reopen analytics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": reopens})
+}
+
+// GetLanguages godoc
+// @Summary      Get language analytics
+// @Description  Returns conversation volume, translation-assisted routing, response and resolution times and QA scores for each conversation language
+// @Tags         analytics
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        period query string false "Time period (daily, weekly, monthly)" default(weekly)
+// @Param        start_date query string false "Custom start date (YYYY-MM-DD)"
+// @Param        end_date query string false "Custom end date (YYYY-MM-DD)"
+// @Success      200 {object} Response{data=[]entity.LanguageAnalytics}
+// @Failure      401 {object} Response
+// @Failure      500 {object} Response
+// @Router       /analytics/languages [get]
+func (h *AnalyticsHandler) GetLanguages(c *gin.Context) {
+	tenantID := middleware.GetTenantID(c)
+	_, startDate, endDate := h.parseAnalyticsParams(c)
+
+	languages, err := h.analyticsService.GetLanguageAnalytics(c.Request.Context(), tenantID, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get language analytics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": languages})
+}
+
+// GetWhatsAppCosts godoc
+// @Summary      Get WhatsApp cost analytics
+// @Description  Returns WhatsApp conversation costs aggregated per channel or per campaign
+// @Tags         analytics
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        period query string false "Time period (daily, weekly, monthly)" default(weekly)
+// @Param        start_date query string false "Custom start date (YYYY-MM-DD)"
+// @Param        end_date query string false "Custom end date (YYYY-MM-DD)"
+// @Param        group_by query string false "Aggregation (channel, campaign)" default(channel)
+// @Success      200 {object} Response{data=[]entity.WhatsAppCostSummary}
+// @Failure      401 {object} Response
+// @Failure      500 {object} Response
+// @Router       /analytics/whatsapp-costs [get]
+func (h *AnalyticsHandler) GetWhatsAppCosts(c *gin.Context) {
+	if h.costService == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "WhatsApp cost attribution is not configured"})
+		return
+	}
+
+	tenantID := middleware.GetTenantID(c)
+	_, startDate, endDate := h.parseAnalyticsParams(c)
+
+	var costs []entity.WhatsAppCostSummary
+	var err error
+	switch c.DefaultQuery("group_by", "channel") {
+	case "campaign":
+		costs, err = h.costService.GetCostsByCampaign(c.Request.Context(), tenantID, startDate, endDate)
+	case "channel":
+		costs, err = h.costService.GetCostsByChannel(c.Request.Context(), tenantID, startDate, endDate)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "group_by must be channel or campaign"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get WhatsApp cost analytics"})
+		return
+	}
+
+	totals, err := h.costService.GetTotals(c.Request.Context(), tenantID, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get WhatsApp cost analytics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": costs, "totals": totals})
+}
+
+// ExportWhatsAppCosts godoc
+// @Summary      Export WhatsApp costs
+// @Description  Exports WhatsApp conversation costs as CSV for usage metering
+// @Tags         analytics
+// @Produce      text/csv
+// @Security     BearerAuth
+// @Param        period query string false "Time period (daily, weekly, monthly)" default(weekly)
+// @Param        start_date query string false "Custom start date (YYYY-MM-DD)"
+// @Param        end_date query string false "Custom end date (YYYY-MM-DD)"
+// @Success      200 {file} file
+// @Failure      401 {object} Response
+// @Failure      500 {object} Response
+// @Router       /analytics/whatsapp-costs/export [get]
+func (h *AnalyticsHandler) ExportWhatsAppCosts(c *gin.Context) {
+	if h.costService == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "WhatsApp cost attribution is not configured"})
+		return
+	}
+
+	tenantID := middleware.GetTenantID(c)
+	_, startDate, endDate := h.parseAnalyticsParams(c)
+
+	data, err := h.costService.ExportCSV(c.Request.Context(), tenantID, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export WhatsApp costs"})
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=whatsapp-costs.csv")
+	c.Data(http.StatusOK, "text/csv", data)
+}
+
+// GetAgentQuality godoc
+// @Summary      Get agent quality scores
+// @Description  Returns QA review scores aggregated per agent for the period
+// @Tags         analytics
+// @Produce      json
+// @Security     BearerAuth
+// @Param        period query string false "Time period (daily, weekly, monthly)" default(weekly)
+// @Param        start_date query string false "Custom start date (YYYY-MM-DD)"
+// @Param        end_date query string false "Custom end date (YYYY-MM-DD)"
+// @Success      200 {array} entity.AgentQualitySummary
+// @Failure      401 {object} Response
+// @Failure      500 {object} Response
+// @Router       /analytics/agent-quality [get]
+func (h *AnalyticsHandler) GetAgentQuality(c *gin.Context) {
+	if h.qaService == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Quality assurance is not configured"})
+		return
+	}
+
+	tenantID := middleware.GetTenantID(c)
+	_, startDate, endDate := h.parseAnalyticsParams(c)
+
+	summaries, err := h.qaService.GetAgentQuality(c.Request.Context(), tenantID, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get agent quality analytics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": summaries})
+}
+
+// GetQuickReplies godoc
+// @Summary      Get quick reply analytics
+// @Description  Returns how often visitors clicked each webchat quick reply in the period, most clicked first
+// @Tags         analytics
+// @Produce      json
+// @Security     BearerAuth
+// @Param        period query string false "Time period (daily, weekly, monthly)" default(weekly)
+// @Param        start_date query string false "Custom start date (YYYY-MM-DD)"
+// @Param        end_date query string false "Custom end date (YYYY-MM-DD)"
+// @Success      200 {array} entity.QuickReplyStats
+// @Failure      401 {object} Response
+// @Failure      500 {object} Response
+// @Router       /analytics/quick-replies [get]
+func (h *AnalyticsHandler) GetQuickReplies(c *gin.Context) {
+	if h.widgetService == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Widget settings are not configured"})
+		return
+	}
+
+	tenantID := middleware.GetTenantID(c)
+	_, startDate, endDate := h.parseAnalyticsParams(c)
+
+	stats, err := h.widgetService.GetQuickReplyStats(c.Request.Context(), tenantID, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get quick reply analytics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": stats})
+}
+
+// GetCopilot godoc
+// @Summary      Get agent copilot analytics
+// @Description  Returns how many copilot suggestions of each type agents accepted, edited or rejected in the period
+// @Tags         analytics
+// @Produce      json
+// @Security     BearerAuth
+// @Param        period query string false "Time period (daily, weekly, monthly)" default(weekly)
+// @Param        start_date query string false "Custom start date (YYYY-MM-DD)"
+// @Param        end_date query string false "Custom end date (YYYY-MM-DD)"
+// @Success      200 {array} entity.CopilotStats
+// @Failure      401 {object} Response
+// @Failure      500 {object} Response
+// @Router       /analytics/copilot [get]
+func (h *AnalyticsHandler) GetCopilot(c *gin.Context) {
+	if h.copilotService == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Agent copilot is not configured"})
+		return
+	}
+
+	tenantID := middleware.GetTenantID(c)
+	_, startDate, endDate := h.parseAnalyticsParams(c)
+
+	stats, err := h.copilotService.Stats(c.Request.Context(), tenantID, startDate, endDate)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get copilot analytics"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": stats})
+}
@@ -56,6 +56,41 @@ func (m *mockAnalyticsRepository) GetChannelAnalytics(ctx context.Context, filte
 	return []entity.ChannelAnalytics{}, nil
 }
 
+func (m *mockAnalyticsRepository) GetLabelAnalytics(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.LabelAnalytics, error) {
+	if m.ReturnError != nil {
+		return nil, m.ReturnError
+	}
+	return []entity.LabelAnalytics{{LabelID: "label-1", LabelName: "billing", TotalConversations: 3}}, nil
+}
+
+func (m *mockAnalyticsRepository) GetDispositionAnalytics(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.DispositionAnalytics, error) {
+	if m.ReturnError != nil {
+		return nil, m.ReturnError
+	}
+	return []entity.DispositionAnalytics{{DispositionID: "disp-1", Code: "billing.refund", Conversations: 5, Share: 50, AvgCSAT: 4.2}}, nil
+}
+
+func (m *mockAnalyticsRepository) GetReopenAnalytics(ctx context.Context, filter entity.AnalyticsFilter) (*entity.ReopenAnalytics, error) {
+	if m.ReturnError != nil {
+		return nil, m.ReturnError
+	}
+	return &entity.ReopenAnalytics{Resolutions: 20, Reopens: 3, ReopenRate: 15, CustomerReopens: 2, AgentReopens: 1, FollowUps: 4}, nil
+}
+
+func (m *mockAnalyticsRepository) GetLanguageAnalytics(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.LanguageAnalytics, error) {
+	if m.ReturnError != nil {
+		return nil, m.ReturnError
+	}
+	return []entity.LanguageAnalytics{{Language: "pt", TotalConversations: 4, TranslatedConversations: 1}}, nil
+}
+
+func (m *mockAnalyticsRepository) GetTemplateDeliveryStats(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.TemplateDeliveryStats, error) {
+	if m.ReturnError != nil {
+		return nil, m.ReturnError
+	}
+	return []entity.TemplateDeliveryStats{}, nil
+}
+
 // ============================================================================
 // Setup helper
 // ============================================================================
@@ -186,3 +221,79 @@ func TestAnalyticsHandler_GetChannels(t *testing.T) {
 	require.NoError(t, err)
 	assert.NotNil(t, resp["data"])
 }
+
+func TestAnalyticsHandler_GetLabels(t *testing.T) {
+	handler := setupAnalyticsTest(t)
+
+	w, c := newTestContext(http.MethodGet, "/analytics/labels", nil)
+	c.Set("tenant_id", "tenant-1")
+
+	handler.GetLabels(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Data []entity.LabelAnalytics `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Data, 1)
+	assert.Equal(t, "billing", resp.Data[0].LabelName)
+	assert.Equal(t, int64(3), resp.Data[0].TotalConversations)
+}
+
+func TestAnalyticsHandler_GetDispositions(t *testing.T) {
+	handler := setupAnalyticsTest(t)
+
+	w, c := newTestContext(http.MethodGet, "/analytics/dispositions", nil)
+	c.Set("tenant_id", "tenant-1")
+
+	handler.GetDispositions(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Data []entity.DispositionAnalytics `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Data, 1)
+	assert.Equal(t, "billing.refund", resp.Data[0].Code)
+	assert.Equal(t, int64(5), resp.Data[0].Conversations)
+}
+
+func TestAnalyticsHandler_GetReopens(t *testing.T) {
+	handler := setupAnalyticsTest(t)
+
+	w, c := newTestContext(http.MethodGet, "/analytics/reopens", nil)
+	c.Set("tenant_id", "tenant-1")
+
+	handler.GetReopens(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Data entity.ReopenAnalytics `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, int64(3), resp.Data.Reopens)
+	assert.Equal(t, 15.0, resp.Data.ReopenRate)
+	assert.Equal(t, int64(4), resp.Data.FollowUps)
+}
+
+func TestAnalyticsHandler_GetLanguages(t *testing.T) {
+	handler := setupAnalyticsTest(t)
+
+	w, c := newTestContext(http.MethodGet, "/analytics/languages", nil)
+	c.Set("tenant_id", "tenant-1")
+
+	handler.GetLanguages(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		Data []entity.LanguageAnalytics `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Data, 1)
+	assert.Equal(t, "pt", resp.Data[0].Language)
+	assert.Equal(t, int64(1), resp.Data[0].TranslatedConversations)
+}
@@ -29,6 +29,7 @@ type APIKeyResponse struct {
 	Scopes     []string   `json:"scopes"`
 	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
 	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
 	CreatedAt  time.Time  `json:"created_at"`
 }
 
@@ -38,7 +39,8 @@ type CreateAPIKeyResponse struct {
 	Key string `json:"key"`
 }
 
-// CreateAPIKeyRequest represents a create API key request.
+// CreateAPIKeyRequest represents a create API key request. Scopes are "*" or
+// "<resource>:<read|write|*>", such as "conversations:read"; they default to "*".
 type CreateAPIKeyRequest struct {
 	Name      string     `json:"name" binding:"required"`
 	Scopes    []string   `json:"scopes"`
@@ -92,6 +94,22 @@ func (h *APIKeyHandler) Create(c *gin.Context) {
 	})
 }
 
+// Revoke stops an API key from authenticating while keeping it listed.
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	apiKey, err := h.apiKeyService.Revoke(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, toAPIKeyResponse(apiKey))
+}
+
 // Delete removes an API key for the current tenant.
 func (h *APIKeyHandler) Delete(c *gin.Context) {
 	tenantID := middleware.MustGetTenantID(c)
@@ -123,6 +141,7 @@ func toAPIKeyResponse(apiKey *entity.APIKey) *APIKeyResponse {
 		Scopes:     apiKey.Scopes,
 		LastUsedAt: apiKey.LastUsedAt,
 		ExpiresAt:  apiKey.ExpiresAt,
+		RevokedAt:  apiKey.RevokedAt,
 		CreatedAt:  apiKey.CreatedAt,
 	}
 }
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"io"
+
 	"github.com/gin-gonic/gin"
 	"github.com/msgfy/linktor/internal/api/middleware"
 	"github.com/msgfy/linktor/internal/application/service"
@@ -27,12 +29,18 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required,min=6"`
 }
 
-// LoginResponse represents a login response
+// LoginResponse represents a login response. When a second factor is enabled or
+// required, only mfa_required, mfa_enrollment and mfa_token are set, and
+// /auth/mfa/verify finishes the login.
 type LoginResponse struct {
-	User         *UserResponse `json:"user"`
-	AccessToken  string        `json:"access_token"`
-	RefreshToken string        `json:"refresh_token"`
-	ExpiresIn    int64         `json:"expires_in"`
+	User          *UserResponse `json:"user,omitempty"`
+	AccessToken   string        `json:"access_token,omitempty"`
+	RefreshToken  string        `json:"refresh_token,omitempty"`
+	ExpiresIn     int64         `json:"expires_in"`
+	MFARequired   bool          `json:"mfa_required,omitempty"`
+	MFAEnrollment bool          `json:"mfa_enrollment,omitempty"`
+	MFAToken      string        `json:"mfa_token,omitempty"`
+	RecoveryCodes []string      `json:"recovery_codes,omitempty"`
 }
 
 // RefreshRequest represents a token refresh request
@@ -47,6 +55,41 @@ type RefreshResponse struct {
 	ExpiresIn    int64  `json:"expires_in"`
 }
 
+// SwitchWorkspaceRequest represents a request to switch to another workspace
+type SwitchWorkspaceRequest struct {
+	TenantID string `json:"tenant_id" binding:"required"`
+}
+
+// MFASetupRequest represents a request to set up TOTP. Signed-in users send no body;
+// logins challenged for enrollment send their MFA token.
+type MFASetupRequest struct {
+	MFAToken string `json:"mfa_token"`
+}
+
+// MFAVerifyRequest represents a request to verify a TOTP or recovery code. With an
+// MFA token it finishes a login, otherwise it confirms the signed-in user's setup.
+type MFAVerifyRequest struct {
+	MFAToken     string `json:"mfa_token"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// MFACodeRequest represents a request confirmed with a TOTP code
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// DisableMFARequest represents a request to turn off MFA
+type DisableMFARequest struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// MFARecoveryCodesResponse represents newly generated recovery codes, shown only once
+type MFARecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
 // ChangePasswordRequest represents a change password request
 type ChangePasswordRequest struct {
 	CurrentPassword string `json:"current_password" binding:"required"`
@@ -55,7 +98,7 @@ type ChangePasswordRequest struct {
 
 // Login godoc
 // @Summary      Login user
-// @Description  Authenticate user with email and password and receive JWT tokens
+// @Description  Authenticate user with email and password and receive JWT tokens, or an MFA challenge token to finish the login at /auth/mfa/verify
 // @Tags         auth
 // @Accept       json
 // @Produce      json
@@ -77,12 +120,181 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	RespondSuccess(c, LoginResponse{
-		User:         toUserResponse(result.User),
-		AccessToken:  result.AccessToken,
-		RefreshToken: result.RefreshToken,
-		ExpiresIn:    result.ExpiresIn,
-	})
+	RespondSuccess(c, toLoginResponse(result))
+}
+
+// SetupMFA godoc
+// @Summary      Set up MFA
+// @Description  Generate a TOTP secret and otpauth URL for an authenticator app. Signed-in users call it with their bearer token; logins challenged for enrollment send their mfa_token. The secret protects logins once /auth/mfa/verify confirmed a code.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request body MFASetupRequest false "MFA token of a login challenged for enrollment"
+// @Success      200 {object} Response{data=service.MFASetup}
+// @Failure      401 {object} Response
+// @Failure      409 {object} Response
+// @Router       /auth/mfa/setup [post]
+func (h *AuthHandler) SetupMFA(c *gin.Context) {
+	var req MFASetupRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	var setup *service.MFASetup
+	var err error
+	if req.MFAToken != "" {
+		setup, err = h.authService.SetupLoginMFA(c.Request.Context(), req.MFAToken)
+	} else {
+		userID := middleware.GetUserID(c)
+		if userID == "" {
+			RespondUnauthorized(c, "User not authenticated")
+			return
+		}
+		setup, err = h.authService.SetupMFA(c.Request.Context(), userID)
+	}
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, setup)
+}
+
+// VerifyMFA godoc
+// @Summary      Verify MFA code
+// @Description  With an mfa_token, finish a challenged login with a TOTP code or recovery code and receive JWT tokens (and recovery codes when the login enrolled the user). Without one, confirm the signed-in user's TOTP setup and receive their recovery codes.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request body MFAVerifyRequest true "Code to verify"
+// @Success      200 {object} Response{data=LoginResponse}
+// @Failure      400 {object} Response
+// @Failure      401 {object} Response
+// @Failure      429 {object} Response
+// @Router       /auth/mfa/verify [post]
+func (h *AuthHandler) VerifyMFA(c *gin.Context) {
+	var req MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	if req.MFAToken != "" {
+		result, err := h.authService.VerifyLoginMFA(c.Request.Context(), req.MFAToken, req.Code, req.RecoveryCode)
+		if err != nil {
+			RespondError(c, err)
+			return
+		}
+		RespondSuccess(c, toLoginResponse(result))
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		RespondUnauthorized(c, "User not authenticated")
+		return
+	}
+	codes, err := h.authService.EnableMFA(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, MFARecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// GetMFAStatus godoc
+// @Summary      Get MFA status
+// @Description  Returns whether the current user has MFA enabled, whether their workspace requires it and how many recovery codes are left
+// @Tags         auth
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=service.MFAStatus}
+// @Failure      401 {object} Response
+// @Router       /auth/mfa [get]
+func (h *AuthHandler) GetMFAStatus(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		RespondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	status, err := h.authService.GetMFAStatus(c.Request.Context(), userID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, status)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary      Regenerate MFA recovery codes
+// @Description  Replace the current user's recovery codes after verifying a TOTP code
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body MFACodeRequest true "TOTP code"
+// @Success      200 {object} Response{data=MFARecoveryCodesResponse}
+// @Failure      400 {object} Response
+// @Failure      401 {object} Response
+// @Router       /auth/mfa/recovery-codes [post]
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		RespondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	codes, err := h.authService.RegenerateRecoveryCodes(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, MFARecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableMFA godoc
+// @Summary      Disable MFA
+// @Description  Turn off the current user's second factor after verifying a TOTP code or recovery code. Not allowed when their workspace requires MFA.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body DisableMFARequest true "TOTP code or recovery code"
+// @Success      200 {object} Response{data=object{message=string}}
+// @Failure      400 {object} Response
+// @Failure      401 {object} Response
+// @Failure      403 {object} Response
+// @Router       /auth/mfa/disable [post]
+func (h *AuthHandler) DisableMFA(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		RespondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req DisableMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	if err := h.authService.DisableMFA(c.Request.Context(), userID, req.Code, req.RecoveryCode); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, gin.H{"message": "Multi-factor authentication disabled"})
 }
 
 // RefreshToken godoc
@@ -116,6 +328,41 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	})
 }
 
+// SwitchWorkspace godoc
+// @Summary      Switch workspace
+// @Description  Exchange the current tokens for tokens of the caller's account in another workspace of their organization
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body SwitchWorkspaceRequest true "Workspace"
+// @Success      200 {object} Response{data=LoginResponse}
+// @Failure      400 {object} Response
+// @Failure      401 {object} Response
+// @Failure      403 {object} Response
+// @Router       /auth/switch-workspace [post]
+func (h *AuthHandler) SwitchWorkspace(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == "" {
+		RespondUnauthorized(c, "User not authenticated")
+		return
+	}
+
+	var req SwitchWorkspaceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	result, err := h.authService.SwitchWorkspace(c.Request.Context(), userID, req.TenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, toLoginResponse(result))
+}
+
 // Me godoc
 // @Summary      Get current user
 // @Description  Returns the currently authenticated user's profile
@@ -224,3 +471,20 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 
 	RespondSuccess(c, gin.H{"message": "Password changed successfully"})
 }
+
+// toLoginResponse converts a login result, full or challenged for MFA, to a response
+func toLoginResponse(result *service.LoginResult) LoginResponse {
+	response := LoginResponse{
+		AccessToken:   result.AccessToken,
+		RefreshToken:  result.RefreshToken,
+		ExpiresIn:     result.ExpiresIn,
+		MFARequired:   result.MFARequired,
+		MFAEnrollment: result.MFAEnrollment,
+		MFAToken:      result.MFAToken,
+		RecoveryCodes: result.RecoveryCodes,
+	}
+	if result.User != nil {
+		response.User = toUserResponse(result.User)
+	}
+	return response
+}
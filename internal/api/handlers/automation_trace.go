@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+)
+
+// AutomationTraceHandler handles the automation decision snapshots of conversations
+type AutomationTraceHandler struct {
+	traceService *service.AutomationTraceService
+}
+
+// NewAutomationTraceHandler creates a new automation trace handler
+func NewAutomationTraceHandler(traceService *service.AutomationTraceService) *AutomationTraceHandler {
+	return &AutomationTraceHandler{traceService: traceService}
+}
+
+// List godoc
+// @Summary      Get conversation automation trace
+// @Description  Lists the flow nodes visited, conditions evaluated, variables and AI confidence recorded for each message, newest first. Use at to inspect the state as of a point in time.
+// @Tags         conversations
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Param        message_id query string false "Only traces of this message"
+// @Param        at query string false "Only traces recorded up to this time (RFC3339)"
+// @Param        page query int false "Page number"
+// @Param        page_size query int false "Page size"
+// @Success      200 {object} Response{data=[]entity.AutomationTrace}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/automation-trace [get]
+func (h *AutomationTraceHandler) List(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	filter := &entity.AutomationTraceFilter{MessageID: c.Query("message_id")}
+	if at := c.Query("at"); at != "" {
+		before, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			RespondValidationError(c, "Invalid at, expected RFC3339", nil)
+			return
+		}
+		filter.Before = &before
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	params := &repository.ListParams{
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	traces, total, err := h.traceService.List(c.Request.Context(), tenantID, c.Param("id"), filter, params)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondPaginated(c, traces, total, params.Page, params.PageSize)
+}
@@ -27,11 +27,13 @@ func NewBotHandler(botService *service.BotServiceImpl) *BotHandler {
 type CreateBotRequest struct {
 	Name         string  `json:"name" binding:"required"`
 	Type         string  `json:"type" binding:"required"` // customer_service, sales, faq
-	Provider     string  `json:"provider" binding:"required"` // openai, anthropic, ollama
-	Model        string  `json:"model" binding:"required"`
+	Provider     string  `json:"provider"` // openai, anthropic, ollama; required unless the type is external
+	Model        string  `json:"model"`    // required unless the type is external
 	SystemPrompt string  `json:"system_prompt"`
 	Temperature  float64 `json:"temperature"`
 	MaxTokens    int     `json:"max_tokens"`
+
+	External *entity.ExternalBotConfig `json:"external,omitempty"` // Rasa, Botpress or custom endpoint of an external bot
 }
 
 // UpdateBotRequest represents an update bot request
@@ -57,6 +59,7 @@ type UpdateBotConfigRequest struct {
 	EscalationRules     []entity.EscalationRule    `json:"escalation_rules"`
 	WorkingHours        *entity.WorkingHours       `json:"working_hours"`
 	KnowledgeBaseID     *string                    `json:"knowledge_base_id"`
+	External            *entity.ExternalBotConfig  `json:"external"`
 }
 
 // AssignChannelRequest represents a channel assignment request
@@ -118,7 +121,7 @@ func (h *BotHandler) List(c *gin.Context) {
 
 // Create godoc
 // @Summary      Create bot
-// @Description  Create a new AI bot
+// @Description  Create a new AI bot, or an external bot relaying its conversations to a Rasa, Botpress or custom endpoint
 // @Tags         bots
 // @Accept       json
 // @Produce      json
@@ -139,6 +142,10 @@ func (h *BotHandler) Create(c *gin.Context) {
 		RespondValidationError(c, "Invalid request body", nil)
 		return
 	}
+	if entity.BotType(req.Type) != entity.BotTypeExternal && (req.Provider == "" || req.Model == "") {
+		RespondValidationError(c, "provider and model are required", nil)
+		return
+	}
 
 	input := &service.CreateBotInput{
 		TenantID:     tenantID,
@@ -149,6 +156,7 @@ func (h *BotHandler) Create(c *gin.Context) {
 		SystemPrompt: req.SystemPrompt,
 		Temperature:  req.Temperature,
 		MaxTokens:    req.MaxTokens,
+		External:     req.External,
 	}
 
 	bot, err := h.botService.Create(c.Request.Context(), input)
@@ -453,6 +461,9 @@ func (h *BotHandler) UpdateConfig(c *gin.Context) {
 	if req.KnowledgeBaseID != nil {
 		config.KnowledgeBaseID = req.KnowledgeBaseID
 	}
+	if req.External != nil {
+		config.External = req.External
+	}
 
 	if err := h.botService.UpdateConfig(c.Request.Context(), id, config); err != nil {
 		RespondError(c, err)
@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// BotExperimentHandler handles bot versions and experiments
+type BotExperimentHandler struct {
+	experimentService *service.BotExperimentService
+}
+
+// NewBotExperimentHandler creates a new bot experiment handler
+func NewBotExperimentHandler(experimentService *service.BotExperimentService) *BotExperimentHandler {
+	return &BotExperimentHandler{experimentService: experimentService}
+}
+
+// ListVersions godoc
+// @Summary      List bot versions
+// @Description  Returns the saved versions of the prompt, model and configuration of the bot, newest first
+// @Tags         bots
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Bot ID"
+// @Success      200 {object} Response{data=[]entity.BotVersion}
+// @Failure      404 {object} Response
+// @Router       /bots/{id}/versions [get]
+func (h *BotExperimentHandler) ListVersions(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	versions, err := h.experimentService.ListVersions(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, versions)
+}
+
+// CreateVersion godoc
+// @Summary      Save bot version
+// @Description  Saves the bot's current model and configuration as a new version, with the given model, system prompt or configuration instead when set. The bot itself is not changed.
+// @Tags         bots
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Bot ID"
+// @Param        request body service.BotVersionInput true "Version"
+// @Success      201 {object} Response{data=entity.BotVersion}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /bots/{id}/versions [post]
+func (h *BotExperimentHandler) CreateVersion(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.BotVersionInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	version, err := h.experimentService.CreateVersion(c.Request.Context(), tenantID, c.Param("id"), middleware.GetUserID(c), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, version)
+}
+
+// GetVersion godoc
+// @Summary      Get bot version
+// @Tags         bots
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Bot ID"
+// @Param        versionId path string true "Version ID"
+// @Success      200 {object} Response{data=entity.BotVersion}
+// @Failure      404 {object} Response
+// @Router       /bots/{id}/versions/{versionId} [get]
+func (h *BotExperimentHandler) GetVersion(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	version, err := h.experimentService.GetVersion(c.Request.Context(), tenantID, c.Param("id"), c.Param("versionId"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, version)
+}
+
+// RestoreVersion godoc
+// @Summary      Restore bot version
+// @Description  Makes the version the bot's current model and configuration
+// @Tags         bots
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Bot ID"
+// @Param        versionId path string true "Version ID"
+// @Success      200 {object} Response{data=entity.Bot}
+// @Failure      404 {object} Response
+// @Router       /bots/{id}/versions/{versionId}/restore [post]
+func (h *BotExperimentHandler) RestoreVersion(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	bot, err := h.experimentService.RestoreVersion(c.Request.Context(), tenantID, c.Param("id"), c.Param("versionId"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, bot)
+}
+
+// ListExperiments godoc
+// @Summary      List bot experiments
+// @Tags         bots
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Bot ID"
+// @Success      200 {object} Response{data=[]entity.BotExperiment}
+// @Failure      404 {object} Response
+// @Router       /bots/{id}/experiments [get]
+func (h *BotExperimentHandler) ListExperiments(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	experiments, err := h.experimentService.ListExperiments(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, experiments)
+}
+
+// StartExperiment godoc
+// @Summary      Start bot experiment
+// @Description  Splits the conversations the bot answers between versions by weight; each conversation keeps its version. A bot runs one experiment at a time.
+// @Tags         bots
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Bot ID"
+// @Param        request body service.BotExperimentInput true "Experiment"
+// @Success      201 {object} Response{data=entity.BotExperiment}
+// @Failure      400 {object} Response
+// @Failure      409 {object} Response
+// @Router       /bots/{id}/experiments [post]
+func (h *BotExperimentHandler) StartExperiment(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.BotExperimentInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	experiment, err := h.experimentService.StartExperiment(c.Request.Context(), tenantID, c.Param("id"), middleware.GetUserID(c), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, experiment)
+}
+
+// GetExperiment godoc
+// @Summary      Get bot experiment
+// @Tags         bots
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Bot ID"
+// @Param        experimentId path string true "Experiment ID"
+// @Success      200 {object} Response{data=entity.BotExperiment}
+// @Failure      404 {object} Response
+// @Router       /bots/{id}/experiments/{experimentId} [get]
+func (h *BotExperimentHandler) GetExperiment(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	experiment, err := h.experimentService.GetExperiment(c.Request.Context(), tenantID, c.Param("id"), c.Param("experimentId"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, experiment)
+}
+
+// StopExperiment godoc
+// @Summary      Stop bot experiment
+// @Description  Stops assigning conversations; the bot answers with its own configuration again. Outcomes of conversations already assigned keep being counted.
+// @Tags         bots
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Bot ID"
+// @Param        experimentId path string true "Experiment ID"
+// @Success      200 {object} Response{data=entity.BotExperiment}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /bots/{id}/experiments/{experimentId}/stop [post]
+func (h *BotExperimentHandler) StopExperiment(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	experiment, err := h.experimentService.StopExperiment(c.Request.Context(), tenantID, c.Param("id"), c.Param("experimentId"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, experiment)
+}
+
+// Report godoc
+// @Summary      Compare bot experiment versions
+// @Description  Returns the conversations, escalation rate, resolution rate and average CSAT of each version of the experiment
+// @Tags         bots
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Bot ID"
+// @Param        experimentId path string true "Experiment ID"
+// @Success      200 {object} Response{data=entity.BotExperimentReport}
+// @Failure      404 {object} Response
+// @Router       /bots/{id}/experiments/{experimentId}/report [get]
+func (h *BotExperimentHandler) Report(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	report, err := h.experimentService.Report(c.Request.Context(), tenantID, c.Param("id"), c.Param("experimentId"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, report)
+}
+
+// RecordCSATRequest represents the satisfaction score of a conversation
+type RecordCSATRequest struct {
+	Score int `json:"score" binding:"required"`
+}
+
+// RecordCSAT godoc
+// @Summary      Record conversation CSAT
+// @Description  Records the satisfaction score, 1 to 5, the customer gave the conversation, counted in disposition analytics and in the bot experiment the conversation takes part in, if any
+// @Tags         conversations
+// @Accept       json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Param        request body RecordCSATRequest true "Score"
+// @Success      204
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/csat [post]
+func (h *BotExperimentHandler) RecordCSAT(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req RecordCSATRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	if err := h.experimentService.RecordCSAT(c.Request.Context(), tenantID, c.Param("id"), req.Score); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
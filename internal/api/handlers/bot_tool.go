@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/repository"
+)
+
+// BotToolHandler handles the HTTP tools the AI of a bot can call
+type BotToolHandler struct {
+	toolService *service.BotToolService
+}
+
+// NewBotToolHandler creates a new bot tool handler
+func NewBotToolHandler(toolService *service.BotToolService) *BotToolHandler {
+	return &BotToolHandler{toolService: toolService}
+}
+
+// List godoc
+// @Summary      List bot tools
+// @Description  Returns the HTTP tools the AI of the bot can call while answering
+// @Tags         bots
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Bot ID"
+// @Success      200 {object} Response{data=[]entity.BotTool}
+// @Failure      404 {object} Response
+// @Router       /bots/{id}/tools [get]
+func (h *BotToolHandler) List(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	tools, err := h.toolService.List(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, tools)
+}
+
+// Create godoc
+// @Summary      Register bot tool
+// @Description  Registers an HTTP endpoint as a tool the AI of the bot can call. The AI sees the name, description and JSON Schema parameters; POST tools receive the arguments as a JSON body and GET tools as query parameters
+// @Tags         bots
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Bot ID"
+// @Param        request body service.BotToolInput true "Tool"
+// @Success      201 {object} Response{data=entity.BotTool}
+// @Failure      400 {object} Response
+// @Failure      409 {object} Response
+// @Router       /bots/{id}/tools [post]
+func (h *BotToolHandler) Create(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.BotToolInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	tool, err := h.toolService.Create(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, tool)
+}
+
+// Update godoc
+// @Summary      Update bot tool
+// @Tags         bots
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Bot ID"
+// @Param        toolId path string true "Tool ID"
+// @Param        request body service.BotToolInput true "Tool"
+// @Success      200 {object} Response{data=entity.BotTool}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /bots/{id}/tools/{toolId} [put]
+func (h *BotToolHandler) Update(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.BotToolInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	tool, err := h.toolService.Update(c.Request.Context(), tenantID, c.Param("id"), c.Param("toolId"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, tool)
+}
+
+// Delete godoc
+// @Summary      Delete bot tool
+// @Tags         bots
+// @Security     BearerAuth
+// @Param        id path string true "Bot ID"
+// @Param        toolId path string true "Tool ID"
+// @Success      204
+// @Failure      404 {object} Response
+// @Router       /bots/{id}/tools/{toolId} [delete]
+func (h *BotToolHandler) Delete(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.toolService.Delete(c.Request.Context(), tenantID, c.Param("id"), c.Param("toolId")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
+
+// ListExecutions godoc
+// @Summary      List bot tool executions
+// @Description  Returns the audit log of the tools the AI of the bot called, with the arguments, the response and how long it took, newest first
+// @Tags         bots
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Bot ID"
+// @Param        page query int false "Page number" default(1)
+// @Param        page_size query int false "Page size" default(20)
+// @Success      200 {object} Response{data=[]entity.ToolExecution,meta=MetaResponse}
+// @Failure      404 {object} Response
+// @Router       /bots/{id}/tool-executions [get]
+func (h *BotToolHandler) ListExecutions(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	params := &repository.ListParams{
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	executions, total, err := h.toolService.ListExecutions(c.Request.Context(), tenantID, c.Param("id"), params)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondPaginated(c, executions, total, params.Page, params.PageSize)
+}
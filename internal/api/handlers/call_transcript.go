@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+)
+
+// CallTranscriptHandler handles voice call transcript endpoints
+type CallTranscriptHandler struct {
+	transcriptionService *service.CallTranscriptionService
+}
+
+// NewCallTranscriptHandler creates a new call transcript handler
+func NewCallTranscriptHandler(transcriptionService *service.CallTranscriptionService) *CallTranscriptHandler {
+	return &CallTranscriptHandler{transcriptionService: transcriptionService}
+}
+
+// List godoc
+// @Summary      Search call transcripts
+// @Description  Searches the transcripts of the tenant's voice calls. With q, transcripts containing
+// @Description  its words are returned best match first with a highlighted snippet; otherwise
+// @Description  newest first.
+// @Tags         call-transcripts
+// @Produce      json
+// @Security     BearerAuth
+// @Param        q          query string false "Words to search for"
+// @Param        channel_id query string false "Voice channel ID"
+// @Param        call_id    query string false "Call ID"
+// @Param        phone      query string false "Number of either party"
+// @Param        page       query int    false "Page number" default(1)
+// @Param        page_size  query int    false "Page size" default(20)
+// @Success      200 {object} Response{data=[]entity.CallTranscript}
+// @Router       /call-transcripts [get]
+func (h *CallTranscriptHandler) List(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	params := &repository.ListParams{
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	filter := &entity.CallTranscriptFilter{
+		Query:     c.Query("q"),
+		ChannelID: c.Query("channel_id"),
+		CallID:    c.Query("call_id"),
+		Phone:     c.Query("phone"),
+	}
+
+	transcripts, total, err := h.transcriptionService.Search(c.Request.Context(), tenantID, filter, params)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondPaginated(c, transcripts, total, params.Page, params.PageSize)
+}
+
+// Get godoc
+// @Summary      Get a call transcript
+// @Description  Returns the transcript of a voice call and the conversation it was posted to
+// @Tags         call-transcripts
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Call transcript ID"
+// @Success      200 {object} Response{data=entity.CallTranscript}
+// @Failure      404 {object} Response
+// @Router       /call-transcripts/{id} [get]
+func (h *CallTranscriptHandler) Get(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	transcript, err := h.transcriptionService.Get(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, transcript)
+}
+
+// Create godoc
+// @Summary      Transcribe a call recording
+// @Description  Downloads a call recording and transcribes it into the caller's conversation as a
+// @Description  background job. Recordings of FreeSWITCH channels that transcribe calls are
+// @Description  transcribed as they stop, without a request.
+// @Tags         call-transcripts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.TranscribeCallInput true "Recording to transcribe"
+// @Success      202 {object} Response{data=entity.Job}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /call-transcripts [post]
+func (h *CallTranscriptHandler) Create(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var input service.TranscribeCallInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	job, err := h.transcriptionService.RequestTranscription(c.Request.Context(), tenantID, middleware.GetUserID(c), &input)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondAccepted(c, job)
+}
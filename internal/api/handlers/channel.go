@@ -11,20 +11,26 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/adapters/custom"
 	"github.com/msgfy/linktor/internal/adapters/facebook"
 	"github.com/msgfy/linktor/internal/adapters/instagram"
 	"github.com/msgfy/linktor/internal/adapters/rcs"
 	"github.com/msgfy/linktor/internal/adapters/sms"
+	"github.com/msgfy/linktor/internal/adapters/teams"
 	"github.com/msgfy/linktor/internal/adapters/telegram"
 	"github.com/msgfy/linktor/internal/api/middleware"
 	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
 	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
 )
 
 // ChannelHandler handles channel endpoints
 type ChannelHandler struct {
-	channelService *service.ChannelService
-	producer       nats.Publisher
+	channelService   *service.ChannelService
+	producer         nats.Publisher
+	credentialHealth *service.ChannelCredentialHealthService
 }
 
 // NewChannelHandler creates a new channel handler
@@ -35,6 +41,12 @@ func NewChannelHandler(channelService *service.ChannelService, producer nats.Pub
 	}
 }
 
+// SetCredentialHealthService includes the latest credential check of channels in
+// channel responses
+func (h *ChannelHandler) SetCredentialHealthService(credentialHealth *service.ChannelCredentialHealthService) {
+	h.credentialHealth = credentialHealth
+}
+
 // CreateChannelRequest represents a create channel request
 type CreateChannelRequest struct {
 	Type        string            `json:"type" binding:"required"`
@@ -52,7 +64,7 @@ type TestChannelRequest struct {
 
 // List godoc
 // @Summary      List channels
-// @Description  Returns all channels for the current tenant
+// @Description  Returns all channels for the current tenant, with the latest check of their credentials
 // @Tags         channels
 // @Accept       json
 // @Produce      json
@@ -71,6 +83,7 @@ func (h *ChannelHandler) List(c *gin.Context) {
 		RespondError(c, err)
 		return
 	}
+	h.annotateCredentialHealth(c, tenantID, channels)
 
 	RespondSuccess(c, channels)
 }
@@ -175,7 +188,7 @@ func (h *ChannelHandler) testConnection(c *gin.Context, forcedType string) {
 
 // Get godoc
 // @Summary      Get channel
-// @Description  Returns a channel by ID
+// @Description  Returns a channel by ID, with the latest check of its credentials
 // @Tags         channels
 // @Accept       json
 // @Produce      json
@@ -192,15 +205,32 @@ func (h *ChannelHandler) Get(c *gin.Context) {
 		return
 	}
 
-	channel, err := h.channelService.GetByID(c.Request.Context(), id)
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	channel, err := h.channelService.GetByID(c.Request.Context(), tenantID, id)
 	if err != nil {
 		RespondError(c, err)
 		return
 	}
+	h.annotateCredentialHealth(c, tenantID, []*entity.Channel{channel})
 
 	RespondSuccess(c, channel)
 }
 
+// annotateCredentialHealth adds the latest credential checks to channels; failing
+// to load them never keeps channels from being listed
+func (h *ChannelHandler) annotateCredentialHealth(c *gin.Context, tenantID string, channels []*entity.Channel) {
+	if h.credentialHealth == nil {
+		return
+	}
+	if err := h.credentialHealth.Annotate(c.Request.Context(), tenantID, channels); err != nil {
+		logger.Warn("Failed to load channel credential health", zap.String("tenant_id", tenantID), zap.Error(err))
+	}
+}
+
 // Update godoc
 // @Summary      Update channel
 // @Description  Update a channel's configuration
@@ -222,6 +252,11 @@ func (h *ChannelHandler) Update(c *gin.Context) {
 		return
 	}
 
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
 	var req CreateChannelRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		RespondValidationError(c, "Invalid request body", nil)
@@ -235,7 +270,7 @@ func (h *ChannelHandler) Update(c *gin.Context) {
 		Credentials: req.Credentials,
 	}
 
-	channel, err := h.channelService.Update(c.Request.Context(), id, input)
+	channel, err := h.channelService.Update(c.Request.Context(), tenantID, id, input)
 	if err != nil {
 		RespondError(c, err)
 		return
@@ -263,7 +298,12 @@ func (h *ChannelHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.channelService.Delete(c.Request.Context(), id); err != nil {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.channelService.Delete(c.Request.Context(), tenantID, id); err != nil {
 		RespondError(c, err)
 		return
 	}
@@ -291,7 +331,12 @@ func (h *ChannelHandler) Connect(c *gin.Context) {
 		return
 	}
 
-	result, err := h.channelService.Connect(c.Request.Context(), id)
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	result, err := h.channelService.Connect(c.Request.Context(), tenantID, id)
 	if err != nil {
 		RespondError(c, err)
 		return
@@ -320,7 +365,12 @@ func (h *ChannelHandler) Disconnect(c *gin.Context) {
 		return
 	}
 
-	if err := h.channelService.Disconnect(c.Request.Context(), id); err != nil {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.channelService.Disconnect(c.Request.Context(), tenantID, id); err != nil {
 		RespondError(c, err)
 		return
 	}
@@ -354,13 +404,18 @@ func (h *ChannelHandler) UpdateStatus(c *gin.Context) {
 		return
 	}
 
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
 	var req UpdateStatusRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		RespondValidationError(c, "Invalid status. Must be 'active' or 'inactive'", nil)
 		return
 	}
 
-	channel, err := h.channelService.UpdateStatus(c.Request.Context(), id, req.Status)
+	channel, err := h.channelService.UpdateStatus(c.Request.Context(), tenantID, id, req.Status)
 	if err != nil {
 		RespondError(c, err)
 		return
@@ -390,13 +445,18 @@ func (h *ChannelHandler) UpdateEnabled(c *gin.Context) {
 		return
 	}
 
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
 	var req UpdateEnabledRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		RespondValidationError(c, "Invalid request body", nil)
 		return
 	}
 
-	channel, err := h.channelService.UpdateEnabled(c.Request.Context(), id, req.Enabled)
+	channel, err := h.channelService.UpdateEnabled(c.Request.Context(), tenantID, id, req.Enabled)
 	if err != nil {
 		RespondError(c, err)
 		return
@@ -436,13 +496,18 @@ func (h *ChannelHandler) RequestPairCode(c *gin.Context) {
 		return
 	}
 
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
 	var req PairCodeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		RespondValidationError(c, "phone_number is required", nil)
 		return
 	}
 
-	result, err := h.channelService.RequestPairCode(c.Request.Context(), id, req.PhoneNumber)
+	result, err := h.channelService.RequestPairCode(c.Request.Context(), tenantID, id, req.PhoneNumber)
 	if err != nil {
 		RespondError(c, err)
 		return
@@ -574,6 +639,11 @@ func validateChannelTestConfig(channelType string, config map[string]string) err
 			cfg.Provider = rcs.ProviderZenvia
 		}
 		return cfg.Validate()
+	case "teams":
+		return teams.NewAdapter().Initialize(config)
+	case "custom":
+		_, err := custom.ParseConfig(config)
+		return err
 	default:
 		return fmt.Errorf("unsupported channel type: %s", channelType)
 	}
@@ -598,7 +668,7 @@ func (h *ChannelHandler) TelegramWebhook(c *gin.Context) {
 	channelID := c.Param("channelId")
 
 	// Get channel
-	channel, err := h.channelService.GetByID(c.Request.Context(), channelID)
+	channel, err := h.channelService.GetForWebhook(c.Request.Context(), channelID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "channel not found"})
 		return
@@ -749,7 +819,7 @@ func (h *ChannelHandler) TwilioWebhook(c *gin.Context) {
 	channelID := c.Param("channelId")
 
 	// Get channel
-	channel, err := h.channelService.GetByID(c.Request.Context(), channelID)
+	channel, err := h.channelService.GetForWebhook(c.Request.Context(), channelID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "channel not found"})
 		return
@@ -864,6 +934,7 @@ func (h *ChannelHandler) TwilioWebhook(c *gin.Context) {
 				ExternalID:   payload.MessageSID,
 				ChannelType:  "sms",
 				Status:       status,
+				ErrorCode:    payload.ErrorCode,
 				ErrorMessage: payload.ErrorMessage,
 				Timestamp:    time.Now(),
 			}
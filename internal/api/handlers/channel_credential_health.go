@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// ChannelCredentialHealthHandler handles the credential checks of channels
+type ChannelCredentialHealthHandler struct {
+	healthService *service.ChannelCredentialHealthService
+}
+
+// NewChannelCredentialHealthHandler creates a new channel credential health handler
+func NewChannelCredentialHealthHandler(healthService *service.ChannelCredentialHealthService) *ChannelCredentialHealthHandler {
+	return &ChannelCredentialHealthHandler{healthService: healthService}
+}
+
+// List godoc
+// @Summary      List channel credential checks
+// @Description  Returns the latest daily credential check of each channel of the tenant; with flagged, only channels whose credentials fail or expire soon
+// @Tags         channels
+// @Produce      json
+// @Security     BearerAuth
+// @Param        flagged query bool false "Only channels needing attention"
+// @Success      200 {object} Response{data=[]entity.ChannelCredentialHealth}
+// @Failure      401 {object} Response
+// @Router       /channels/credential-health [get]
+func (h *ChannelCredentialHealthHandler) List(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	checks, err := h.healthService.List(c.Request.Context(), tenantID, c.Query("flagged") == "true")
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, checks)
+}
+
+// Get godoc
+// @Summary      Get channel credential check
+// @Description  Returns the latest credential check of the channel
+// @Tags         channels
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Channel ID"
+// @Success      200 {object} Response{data=entity.ChannelCredentialHealth}
+// @Failure      404 {object} Response
+// @Router       /channels/{id}/credential-health [get]
+func (h *ChannelCredentialHealthHandler) Get(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	health, err := h.healthService.Get(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, health)
+}
+
+// Check godoc
+// @Summary      Check channel credentials
+// @Description  Validates the stored credentials of the channel with its provider now, as the daily check does
+// @Tags         channels
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Channel ID"
+// @Success      200 {object} Response{data=entity.ChannelCredentialHealth}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /channels/{id}/credential-health/check [post]
+func (h *ChannelCredentialHealthHandler) Check(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	health, err := h.healthService.Check(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, health)
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// ChannelFailoverHandler exposes WhatsApp channel ownership across server instances
+type ChannelFailoverHandler struct {
+	failoverService *service.ChannelFailoverService
+}
+
+// NewChannelFailoverHandler creates a new channel failover handler
+func NewChannelFailoverHandler(failoverService *service.ChannelFailoverService) *ChannelFailoverHandler {
+	return &ChannelFailoverHandler{failoverService: failoverService}
+}
+
+// GetStatus godoc
+// @Summary      Get channel failover status
+// @Description  Returns the server instance holding the channel's WhatsApp session and its recent takeovers
+// @Tags         channels
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Channel ID"
+// @Success      200 {object} Response{data=service.ChannelFailoverStatus}
+// @Failure      404 {object} Response
+// @Router       /channels/{id}/failover [get]
+func (h *ChannelFailoverHandler) GetStatus(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	status, err := h.failoverService.Status(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, status)
+}
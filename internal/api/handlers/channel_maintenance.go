@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// ChannelMaintenanceHandler handles the maintenance windows of channels
+type ChannelMaintenanceHandler struct {
+	maintenanceService *service.ChannelMaintenanceService
+}
+
+// NewChannelMaintenanceHandler creates a new channel maintenance handler
+func NewChannelMaintenanceHandler(maintenanceService *service.ChannelMaintenanceService) *ChannelMaintenanceHandler {
+	return &ChannelMaintenanceHandler{maintenanceService: maintenanceService}
+}
+
+// List godoc
+// @Summary      List channel maintenance windows
+// @Description  Lists the current and upcoming maintenance windows of a channel
+// @Tags         channels
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Channel ID"
+// @Success      200 {object} Response{data=[]entity.ChannelMaintenanceWindow}
+// @Failure      404 {object} Response
+// @Router       /channels/{id}/maintenance [get]
+func (h *ChannelMaintenanceHandler) List(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	windows, err := h.maintenanceService.List(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, windows)
+}
+
+// Create godoc
+// @Summary      Schedule channel maintenance
+// @Description  Declares a maintenance window on a channel. While it is active, contacts writing in receive its notice, conversations are queued instead of routed, bots and agent timers pause and newsletters are deferred.
+// @Tags         channels
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Channel ID"
+// @Param        request body service.ChannelMaintenanceInput true "Maintenance window"
+// @Success      201 {object} Response{data=entity.ChannelMaintenanceWindow}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /channels/{id}/maintenance [post]
+func (h *ChannelMaintenanceHandler) Create(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.ChannelMaintenanceInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	window, err := h.maintenanceService.Create(c.Request.Context(), tenantID, c.Param("id"), middleware.GetUserID(c), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, window)
+}
+
+// Update godoc
+// @Summary      Update channel maintenance
+// @Description  Reschedules or rewords a maintenance window that has not ended; an ongoing window keeps its start
+// @Tags         channels
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Channel ID"
+// @Param        windowId path string true "Maintenance window ID"
+// @Param        request body service.ChannelMaintenanceInput true "Maintenance window"
+// @Success      200 {object} Response{data=entity.ChannelMaintenanceWindow}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /channels/{id}/maintenance/{windowId} [put]
+func (h *ChannelMaintenanceHandler) Update(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.ChannelMaintenanceInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	window, err := h.maintenanceService.Update(c.Request.Context(), tenantID, c.Param("id"), c.Param("windowId"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, window)
+}
+
+// End godoc
+// @Summary      End channel maintenance
+// @Description  Ends an ongoing maintenance window now; queued conversations are left for agents to pick up
+// @Tags         channels
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Channel ID"
+// @Param        windowId path string true "Maintenance window ID"
+// @Success      200 {object} Response{data=entity.ChannelMaintenanceWindow}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /channels/{id}/maintenance/{windowId}/end [post]
+func (h *ChannelMaintenanceHandler) End(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	window, err := h.maintenanceService.End(c.Request.Context(), tenantID, c.Param("id"), c.Param("windowId"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, window)
+}
+
+// Delete godoc
+// @Summary      Cancel channel maintenance
+// @Description  Cancels a maintenance window that has not started
+// @Tags         channels
+// @Security     BearerAuth
+// @Param        id path string true "Channel ID"
+// @Param        windowId path string true "Maintenance window ID"
+// @Success      204
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /channels/{id}/maintenance/{windowId} [delete]
+func (h *ChannelMaintenanceHandler) Delete(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.maintenanceService.Delete(c.Request.Context(), tenantID, c.Param("id"), c.Param("windowId")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// ChannelProbeHandler handles channel capability probes
+type ChannelProbeHandler struct {
+	probeService *service.ChannelProbeService
+}
+
+// NewChannelProbeHandler creates a new channel probe handler
+func NewChannelProbeHandler(probeService *service.ChannelProbeService) *ChannelProbeHandler {
+	return &ChannelProbeHandler{probeService: probeService}
+}
+
+// Probe godoc
+// @Summary      Probe channel capabilities
+// @Description  Checks the channel's credentials and their permissions with the provider, verifies its webhook URL can be reached and sends a test message to the sandbox_recipient set in the channel config
+// @Tags         channels
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Channel ID"
+// @Success      200 {object} Response{data=entity.ChannelProbe}
+// @Failure      404 {object} Response
+// @Router       /channels/{id}/probe [post]
+func (h *ChannelProbeHandler) Probe(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	probe, err := h.probeService.Probe(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, probe)
+}
+
+// Get godoc
+// @Summary      Get channel probe
+// @Description  Returns the latest capability probe of the channel, run when it was created or connected or on request
+// @Tags         channels
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Channel ID"
+// @Success      200 {object} Response{data=entity.ChannelProbe}
+// @Failure      404 {object} Response
+// @Router       /channels/{id}/probe [get]
+func (h *ChannelProbeHandler) Get(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	probe, err := h.probeService.Get(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, probe)
+}
@@ -168,9 +168,8 @@ func TestChannelGet_NotFound(t *testing.T) {
 
 	handler.Get(c)
 
-	// The mock repo returns a plain error, which maps to 500 via RespondError
-	if w.Code != http.StatusInternalServerError {
-		t.Fatalf("expected 500, got %d", w.Code)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
 	}
 	resp := parseChannelResponse(t, w)
 	if resp.Success {
@@ -352,8 +351,8 @@ func TestChannelUpdate_NotFound(t *testing.T) {
 
 	handler.Update(c)
 
-	if w.Code != http.StatusInternalServerError {
-		t.Fatalf("expected 500, got %d", w.Code)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
 	}
 }
 
@@ -420,9 +419,8 @@ func TestChannelDelete_NotFound(t *testing.T) {
 
 	handler.Delete(c)
 
-	// Mock repo Delete is a no-op on missing key, so handler returns 204.
-	if c.Writer.Status() != http.StatusNoContent {
-		t.Fatalf("expected 204, got %d", c.Writer.Status())
+	if c.Writer.Status() != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", c.Writer.Status())
 	}
 }
 
@@ -523,8 +521,8 @@ func TestChannelUpdateStatus_NotFound(t *testing.T) {
 
 	handler.UpdateStatus(c)
 
-	if w.Code != http.StatusInternalServerError {
-		t.Fatalf("expected 500, got %d", w.Code)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
 	}
 }
 
@@ -598,8 +596,8 @@ func TestChannelUpdateEnabled_NotFound(t *testing.T) {
 
 	handler.UpdateEnabled(c)
 
-	if w.Code != http.StatusInternalServerError {
-		t.Fatalf("expected 500, got %d", w.Code)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
 	}
 }
 
@@ -638,8 +636,8 @@ func TestChannelConnect_NotFound(t *testing.T) {
 
 	handler.Connect(c)
 
-	if w.Code != http.StatusInternalServerError {
-		t.Fatalf("expected 500, got %d", w.Code)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
 	}
 }
 
@@ -691,8 +689,8 @@ func TestChannelDisconnect_NotFound(t *testing.T) {
 
 	handler.Disconnect(c)
 
-	if w.Code != http.StatusInternalServerError {
-		t.Fatalf("expected 500, got %d", w.Code)
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", w.Code)
 	}
 }
 
@@ -749,3 +747,52 @@ func TestChannelWhatsAppWebhook(t *testing.T) {
 		t.Fatal("expected success=true")
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Cross-tenant access
+// ---------------------------------------------------------------------------
+
+func TestChannel_OtherTenant_Returns404(t *testing.T) {
+	handler, repo, _ := setupChannelHandler()
+	seedChannel(repo, "ch-other", "tenant-2", "Other Tenant Chat", entity.ChannelTypeWebChat)
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		call   func(c *gin.Context)
+	}{
+		{"Get", http.MethodGet, "", handler.Get},
+		{"Update", http.MethodPut, `{"type":"webchat","name":"Hijacked"}`, handler.Update},
+		{"Delete", http.MethodDelete, "", handler.Delete},
+		{"Connect", http.MethodPost, "", handler.Connect},
+		{"Disconnect", http.MethodPost, "", handler.Disconnect},
+		{"UpdateStatus", http.MethodPatch, `{"status":"inactive"}`, handler.UpdateStatus},
+		{"UpdateEnabled", http.MethodPut, `{"enabled":false}`, handler.UpdateEnabled},
+		{"RequestPairCode", http.MethodPost, `{"phone_number":"+5511999999999"}`, handler.RequestPairCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body []byte
+			if tt.body != "" {
+				body = []byte(tt.body)
+			}
+			c, w := newChannelAuthContext(tt.method, "/channels/ch-other", body)
+			c.Params = gin.Params{{Key: "id", Value: "ch-other"}}
+
+			tt.call(c)
+
+			if w.Code != http.StatusNotFound {
+				t.Fatalf("expected 404, got %d; body: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+
+	ch, exists := repo.Channels["ch-other"]
+	if !exists {
+		t.Fatal("expected the other tenant's channel to be kept")
+	}
+	if ch.Name != "Other Tenant Chat" || !ch.Enabled {
+		t.Fatal("expected the other tenant's channel to be unchanged")
+	}
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// ChannelTokenHandler handles the access token lifecycle of channels
+type ChannelTokenHandler struct {
+	tokenService *service.ChannelTokenService
+}
+
+// NewChannelTokenHandler creates a new channel token handler
+func NewChannelTokenHandler(tokenService *service.ChannelTokenService) *ChannelTokenHandler {
+	return &ChannelTokenHandler{tokenService: tokenService}
+}
+
+// Get godoc
+// @Summary      Get channel access token state
+// @Description  Returns when the access token of a Facebook, Instagram or WhatsApp channel expires and whether it was refreshed, inspecting it with Meta when it was never tracked
+// @Tags         channels
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Channel ID"
+// @Success      200 {object} Response{data=entity.ChannelToken}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /channels/{id}/token [get]
+func (h *ChannelTokenHandler) Get(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	token, err := h.tokenService.Get(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, token)
+}
+
+// Refresh godoc
+// @Summary      Refresh channel access token
+// @Description  Exchanges the access token of the channel for a new long-lived one using the app_id and app_secret stored with it
+// @Tags         channels
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Channel ID"
+// @Success      200 {object} Response{data=entity.ChannelToken}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Failure      500 {object} Response
+// @Router       /channels/{id}/token/refresh [post]
+func (h *ChannelTokenHandler) Refresh(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	token, err := h.tokenService.Refresh(c.Request.Context(), tenantID, c.Param("id"), "", "")
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, token)
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/repository"
+)
+
+// ComplianceHandler handles the compliance rules outbound marketing content is checked against
+type ComplianceHandler struct {
+	complianceService *service.ComplianceService
+}
+
+// NewComplianceHandler creates a new compliance handler
+func NewComplianceHandler(complianceService *service.ComplianceService) *ComplianceHandler {
+	return &ComplianceHandler{complianceService: complianceService}
+}
+
+// authorizeComplianceOverride fills in who overrides blocking compliance findings.
+// Only admins and owners may override; anyone else gets a forbidden response.
+func authorizeComplianceOverride(c *gin.Context, override *service.ComplianceOverrideInput) bool {
+	if override == nil {
+		return true
+	}
+	switch middleware.GetUserRole(c) {
+	case "admin", "owner":
+		override.UserID = middleware.GetUserID(c)
+		return true
+	}
+	RespondForbidden(c, "Only admins can override compliance rules")
+	return false
+}
+
+// Scan godoc
+// @Summary      Scan content for compliance
+// @Description  Checks campaign or template content against the built-in and tenant compliance rules for its country, channel and template category, without submitting it
+// @Tags         compliance
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.ComplianceContent true "Content"
+// @Success      200 {object} Response{data=entity.ComplianceReport}
+// @Failure      400 {object} Response
+// @Router       /compliance/scan [post]
+func (h *ComplianceHandler) Scan(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.ComplianceContent
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	report, err := h.complianceService.Scan(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, report)
+}
+
+// ListRules godoc
+// @Summary      List compliance rules
+// @Description  Returns the built-in compliance rules followed by the tenant's own
+// @Tags         compliance
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=[]entity.ComplianceRule}
+// @Router       /compliance/rules [get]
+func (h *ComplianceHandler) ListRules(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	rules, err := h.complianceService.ListRules(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, rules)
+}
+
+// CreateRule godoc
+// @Summary      Create compliance rule
+// @Tags         compliance
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.ComplianceRuleInput true "Rule"
+// @Success      201 {object} Response{data=entity.ComplianceRule}
+// @Failure      400 {object} Response
+// @Router       /compliance/rules [post]
+func (h *ComplianceHandler) CreateRule(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.ComplianceRuleInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	rule, err := h.complianceService.CreateRule(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, rule)
+}
+
+// UpdateRule godoc
+// @Summary      Update compliance rule
+// @Description  Updates a rule of the tenant; built-in rules cannot be changed
+// @Tags         compliance
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Rule ID"
+// @Param        request body service.ComplianceRuleInput true "Rule"
+// @Success      200 {object} Response{data=entity.ComplianceRule}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /compliance/rules/{id} [put]
+func (h *ComplianceHandler) UpdateRule(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.ComplianceRuleInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	rule, err := h.complianceService.UpdateRule(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, rule)
+}
+
+// DeleteRule godoc
+// @Summary      Delete compliance rule
+// @Tags         compliance
+// @Security     BearerAuth
+// @Param        id path string true "Rule ID"
+// @Success      204
+// @Failure      404 {object} Response
+// @Router       /compliance/rules/{id} [delete]
+func (h *ComplianceHandler) DeleteRule(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.complianceService.DeleteRule(c.Request.Context(), tenantID, c.Param("id")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
+
+// ListOverrides godoc
+// @Summary      List compliance overrides
+// @Description  Returns the log of content submitted despite blocking compliance findings, with who allowed it and why
+// @Tags         compliance
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page query int false "Page number" default(1)
+// @Param        page_size query int false "Page size" default(20)
+// @Success      200 {object} Response{data=[]entity.ComplianceOverride,meta=MetaResponse}
+// @Router       /compliance/overrides [get]
+func (h *ComplianceHandler) ListOverrides(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	params := &repository.ListParams{
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	overrides, total, err := h.complianceService.ListOverrides(c.Request.Context(), tenantID, params)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondPaginated(c, overrides, total, params.Page, params.PageSize)
+}
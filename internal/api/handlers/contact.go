@@ -42,7 +42,10 @@ type CreateContactRequest struct {
 // @Param        page query int false "Page number" default(1)
 // @Param        page_size query int false "Page size" default(20)
 // @Param        search query string false "Search by name, email or phone"
+// @Param        custom_fields query object false "Custom field values to match, as custom_fields[key]=value"
+// @Param        If-None-Match header string false "ETag of a previous response"
 // @Success      200 {object} Response{data=[]entity.Contact,meta=MetaResponse}
+// @Success      304
 // @Failure      401 {object} Response
 // @Router       /contacts [get]
 func (h *ContactHandler) List(c *gin.Context) {
@@ -61,6 +64,9 @@ func (h *ContactHandler) List(c *gin.Context) {
 	if search := strings.TrimSpace(c.Query("search")); search != "" {
 		params.Filters["search"] = search
 	}
+	if customFields := c.QueryMap("custom_fields"); len(customFields) > 0 {
+		params.Filters["custom_fields"] = customFields
+	}
 
 	contacts, total, err := h.contactService.List(c.Request.Context(), tenantID, params)
 	if err != nil {
@@ -68,7 +74,7 @@ func (h *ContactHandler) List(c *gin.Context) {
 		return
 	}
 
-	RespondWithMeta(c, contacts, &MetaResponse{
+	RespondCacheable(c, contacts, &MetaResponse{
 		Page:       params.Page,
 		PageSize:   params.PageSize,
 		TotalItems: total,
@@ -129,7 +135,9 @@ func (h *ContactHandler) Create(c *gin.Context) {
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id path string true "Contact ID"
+// @Param        If-None-Match header string false "ETag of a previous response"
 // @Success      200 {object} Response{data=entity.Contact}
+// @Success      304
 // @Failure      401 {object} Response
 // @Failure      404 {object} Response
 // @Router       /contacts/{id} [get]
@@ -140,13 +148,18 @@ func (h *ContactHandler) Get(c *gin.Context) {
 		return
 	}
 
-	contact, err := h.contactService.GetByID(c.Request.Context(), id)
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	contact, err := h.contactService.GetByID(c.Request.Context(), tenantID, id)
 	if err != nil {
 		RespondError(c, err)
 		return
 	}
 
-	RespondSuccess(c, contact)
+	RespondCacheable(c, contact, nil)
 }
 
 // Update godoc
@@ -170,6 +183,11 @@ func (h *ContactHandler) Update(c *gin.Context) {
 		return
 	}
 
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
 	var req CreateContactRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		RespondValidationError(c, "Invalid request body", nil)
@@ -185,7 +203,7 @@ func (h *ContactHandler) Update(c *gin.Context) {
 		Tags:         req.Tags,
 	}
 
-	contact, err := h.contactService.Update(c.Request.Context(), id, input)
+	contact, err := h.contactService.Update(c.Request.Context(), tenantID, id, input)
 	if err != nil {
 		RespondError(c, err)
 		return
@@ -213,7 +231,12 @@ func (h *ContactHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.contactService.Delete(c.Request.Context(), id); err != nil {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.contactService.Delete(c.Request.Context(), tenantID, id); err != nil {
 		RespondError(c, err)
 		return
 	}
@@ -249,13 +272,18 @@ func (h *ContactHandler) AddIdentity(c *gin.Context) {
 		return
 	}
 
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
 	var req AddIdentityRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		RespondValidationError(c, "Invalid request body", nil)
 		return
 	}
 
-	contact, err := h.contactService.AddIdentity(c.Request.Context(), id, req.ChannelType, req.Identifier, req.Metadata)
+	contact, err := h.contactService.AddIdentity(c.Request.Context(), tenantID, id, req.ChannelType, req.Identifier, req.Metadata)
 	if err != nil {
 		RespondError(c, err)
 		return
@@ -287,7 +315,12 @@ func (h *ContactHandler) RemoveIdentity(c *gin.Context) {
 		return
 	}
 
-	contact, err := h.contactService.RemoveIdentity(c.Request.Context(), id, identityID)
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	contact, err := h.contactService.RemoveIdentity(c.Request.Context(), tenantID, id, identityID)
 	if err != nil {
 		RespondError(c, err)
 		return
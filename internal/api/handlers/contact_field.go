@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// ContactFieldHandler handles the custom fields tenants define for contacts
+type ContactFieldHandler struct {
+	fieldService *service.ContactFieldService
+}
+
+// NewContactFieldHandler creates a new contact field handler
+func NewContactFieldHandler(fieldService *service.ContactFieldService) *ContactFieldHandler {
+	return &ContactFieldHandler{fieldService: fieldService}
+}
+
+// List godoc
+// @Summary      List contact fields
+// @Tags         contact-fields
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=[]entity.ContactField}
+// @Router       /contact-fields [get]
+func (h *ContactFieldHandler) List(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	fields, err := h.fieldService.List(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, fields)
+}
+
+// Create godoc
+// @Summary      Create contact field
+// @Description  Defines a custom contact field with its type and validation. Contact values are stored under the field's key in custom_fields.
+// @Tags         contact-fields
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.ContactFieldInput true "Contact field"
+// @Success      201 {object} Response{data=entity.ContactField}
+// @Failure      400 {object} Response
+// @Failure      409 {object} Response
+// @Router       /contact-fields [post]
+func (h *ContactFieldHandler) Create(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.ContactFieldInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	field, err := h.fieldService.Create(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, field)
+}
+
+// Get godoc
+// @Summary      Get contact field
+// @Tags         contact-fields
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Contact field ID"
+// @Success      200 {object} Response{data=entity.ContactField}
+// @Failure      404 {object} Response
+// @Router       /contact-fields/{id} [get]
+func (h *ContactFieldHandler) Get(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	field, err := h.fieldService.Get(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, field)
+}
+
+// Update godoc
+// @Summary      Update contact field
+// @Description  Updates a contact field's label, type and validation. The key cannot change.
+// @Tags         contact-fields
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Contact field ID"
+// @Param        request body service.ContactFieldInput true "Contact field"
+// @Success      200 {object} Response{data=entity.ContactField}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /contact-fields/{id} [put]
+func (h *ContactFieldHandler) Update(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.ContactFieldInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	field, err := h.fieldService.Update(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, field)
+}
+
+// Delete godoc
+// @Summary      Delete contact field
+// @Description  Deletes a contact field. Values stored on contacts are kept.
+// @Tags         contact-fields
+// @Security     BearerAuth
+// @Param        id path string true "Contact field ID"
+// @Success      204
+// @Failure      404 {object} Response
+// @Router       /contact-fields/{id} [delete]
+func (h *ContactFieldHandler) Delete(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.fieldService.Delete(c.Request.Context(), tenantID, c.Param("id")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// ContactMergeHandler handles merging duplicate contacts
+type ContactMergeHandler struct {
+	mergeService *service.ContactMergeService
+}
+
+// NewContactMergeHandler creates a new contact merge handler
+func NewContactMergeHandler(mergeService *service.ContactMergeService) *ContactMergeHandler {
+	return &ContactMergeHandler{mergeService: mergeService}
+}
+
+// Merge godoc
+// @Summary      Merge contacts
+// @Description  Moves the conversations, messages and channel identities of the source contact into this one and deletes the source
+// @Tags         contacts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Target contact ID"
+// @Param        request body service.MergeContactsInput true "Source contact"
+// @Success      200 {object} Response{data=service.ContactMergeResult}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /contacts/{id}/merge [post]
+func (h *ContactMergeHandler) Merge(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.MergeContactsInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	result, err := h.mergeService.Merge(c.Request.Context(), tenantID, c.Param("id"), middleware.GetUserID(c), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, result)
+}
+
+// Duplicates godoc
+// @Summary      List duplicate contacts
+// @Description  Lists the contacts sharing this contact's phone number or email once normalized
+// @Tags         contacts
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Contact ID"
+// @Success      200 {object} Response{data=[]entity.ContactMatch}
+// @Failure      404 {object} Response
+// @Router       /contacts/{id}/duplicates [get]
+func (h *ContactMergeHandler) Duplicates(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	matches, err := h.mergeService.FindDuplicates(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, matches)
+}
+
+// ListMerges godoc
+// @Summary      List contact merges
+// @Description  Lists the contacts merged into this contact, newest first
+// @Tags         contacts
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Contact ID"
+// @Success      200 {object} Response{data=[]entity.ContactMerge}
+// @Failure      404 {object} Response
+// @Router       /contacts/{id}/merges [get]
+func (h *ContactMergeHandler) ListMerges(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	merges, err := h.mergeService.ListMerges(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, merges)
+}
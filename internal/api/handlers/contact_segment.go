@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/repository"
+)
+
+// ContactSegmentHandler handles rule-based contact segments
+type ContactSegmentHandler struct {
+	segmentService *service.ContactSegmentService
+}
+
+// NewContactSegmentHandler creates a new contact segment handler
+func NewContactSegmentHandler(segmentService *service.ContactSegmentService) *ContactSegmentHandler {
+	return &ContactSegmentHandler{segmentService: segmentService}
+}
+
+// List godoc
+// @Summary      List contact segments
+// @Tags         segments
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=[]entity.ContactSegment}
+// @Router       /segments [get]
+func (h *ContactSegmentHandler) List(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	segments, err := h.segmentService.List(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, segments)
+}
+
+// Create godoc
+// @Summary      Create contact segment
+// @Description  Creates a segment whose contacts are those matching its rules on tags, custom fields, last contact date, creation date and channel identities
+// @Tags         segments
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.ContactSegmentInput true "Contact segment"
+// @Success      201 {object} Response{data=entity.ContactSegment}
+// @Failure      400 {object} Response
+// @Router       /segments [post]
+func (h *ContactSegmentHandler) Create(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.ContactSegmentInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	segment, err := h.segmentService.Create(c.Request.Context(), tenantID, middleware.GetUserID(c), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, segment)
+}
+
+// Get godoc
+// @Summary      Get contact segment
+// @Tags         segments
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Segment ID"
+// @Success      200 {object} Response{data=entity.ContactSegment}
+// @Failure      404 {object} Response
+// @Router       /segments/{id} [get]
+func (h *ContactSegmentHandler) Get(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	segment, err := h.segmentService.Get(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, segment)
+}
+
+// Update godoc
+// @Summary      Update contact segment
+// @Tags         segments
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Segment ID"
+// @Param        request body service.ContactSegmentInput true "Contact segment"
+// @Success      200 {object} Response{data=entity.ContactSegment}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /segments/{id} [put]
+func (h *ContactSegmentHandler) Update(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.ContactSegmentInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	segment, err := h.segmentService.Update(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, segment)
+}
+
+// Delete godoc
+// @Summary      Delete contact segment
+// @Tags         segments
+// @Security     BearerAuth
+// @Param        id path string true "Segment ID"
+// @Success      204
+// @Failure      404 {object} Response
+// @Router       /segments/{id} [delete]
+func (h *ContactSegmentHandler) Delete(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.segmentService.Delete(c.Request.Context(), tenantID, c.Param("id")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
+
+// ListContacts godoc
+// @Summary      List segment contacts
+// @Description  Lists the contacts that currently match the segment's rules
+// @Tags         segments
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Segment ID"
+// @Param        page query int false "Page number" default(1)
+// @Param        page_size query int false "Page size" default(20)
+// @Success      200 {object} Response{data=[]entity.Contact}
+// @Failure      404 {object} Response
+// @Router       /segments/{id}/contacts [get]
+func (h *ContactSegmentHandler) ListContacts(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	params := repository.NewListParams()
+	if page, err := strconv.Atoi(c.DefaultQuery("page", "1")); err == nil && page > 0 {
+		params.Page = page
+	}
+	if pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "20")); err == nil && pageSize > 0 {
+		params.PageSize = pageSize
+	}
+
+	contacts, total, err := h.segmentService.ListContacts(c.Request.Context(), tenantID, c.Param("id"), params)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondPaginated(c, contacts, total, params.Page, params.Limit())
+}
+
+// Stats godoc
+// @Summary      Get segment stats
+// @Description  Counts the segment's contacts and how recently they were contacted
+// @Tags         segments
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Segment ID"
+// @Success      200 {object} Response{data=entity.SegmentStats}
+// @Failure      404 {object} Response
+// @Router       /segments/{id}/stats [get]
+func (h *ContactSegmentHandler) Stats(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	stats, err := h.segmentService.Stats(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, stats)
+}
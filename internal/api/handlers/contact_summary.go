@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// ContactSummaryHandler serves the contact profile cards shown in the agent sidebar
+type ContactSummaryHandler struct {
+	summaryService *service.ContactSummaryService
+}
+
+// NewContactSummaryHandler creates a new contact summary handler
+func NewContactSummaryHandler(summaryService *service.ContactSummaryService) *ContactSummaryHandler {
+	return &ContactSummaryHandler{summaryService: summaryService}
+}
+
+// Get godoc
+// @Summary      Get contact summary
+// @Description  Returns the profile card of a contact: lifetime conversations, common topics, sentiment trend, open payments and last order. Cards are cached and rebuilt when the contact sends a new message.
+// @Tags         contacts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Contact ID"
+// @Success      200 {object} Response{data=entity.ContactSummary}
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Router       /contacts/{id}/summary [get]
+func (h *ContactSummaryHandler) Get(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	summary, err := h.summaryService.Get(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, summary)
+}
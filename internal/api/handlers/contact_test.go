@@ -809,3 +809,53 @@ func TestContactRemoveIdentity_EmptyIdentityID_Returns400(t *testing.T) {
 		t.Fatalf("expected status 400, got %d", w.Code)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Cross-tenant access
+// ---------------------------------------------------------------------------
+
+func TestContact_OtherTenant_Returns404(t *testing.T) {
+	handler, repo := setupContactHandler()
+	seedContact(repo, "c-other", "tenant-2", "Mallory", "mallory@example.com", "+9999")
+	repo.Identities["c-other"] = []*entity.ContactIdentity{
+		{ID: "id-1", ContactID: "c-other", ChannelType: "whatsapp", Identifier: "+9999", CreatedAt: time.Now()},
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		call   func(c *gin.Context)
+	}{
+		{"Get", http.MethodGet, "", handler.Get},
+		{"Update", http.MethodPut, `{"name":"Hijacked"}`, handler.Update},
+		{"Delete", http.MethodDelete, "", handler.Delete},
+		{"AddIdentity", http.MethodPost, `{"channel_type":"telegram","identifier":"123"}`, handler.AddIdentity},
+		{"RemoveIdentity", http.MethodDelete, "", handler.RemoveIdentity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := newContactAuthContext()
+			c.Params = []gin.Param{
+				{Key: "id", Value: "c-other"},
+				{Key: "identityId", Value: "id-1"},
+			}
+			c.Request = httptest.NewRequest(tt.method, "/contacts/c-other", bytes.NewReader([]byte(tt.body)))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			tt.call(c)
+
+			if w.Code != http.StatusNotFound {
+				t.Fatalf("expected status 404, got %d; body: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+
+	contact, exists := repo.Contacts["c-other"]
+	if !exists || contact.Name != "Mallory" {
+		t.Fatal("expected the other tenant's contact to be unchanged")
+	}
+	if len(repo.Identities["c-other"]) != 1 {
+		t.Fatalf("expected the other tenant's identity to be kept, got %d", len(repo.Identities["c-other"]))
+	}
+}
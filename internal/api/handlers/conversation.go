@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"net/http"
+	"strings"
+
 	"github.com/gin-gonic/gin"
 	"github.com/msgfy/linktor/internal/api/middleware"
 	"github.com/msgfy/linktor/internal/application/service"
@@ -11,6 +14,8 @@ import (
 type ConversationHandler struct {
 	conversationService *service.ConversationService
 	escalateUC          *usecase.EscalateConversationUseCase
+	takeoverUC          *usecase.TakeoverConversationUseCase
+	reopenService       *service.ConversationReopenService
 }
 
 // NewConversationHandler creates a new conversation handler
@@ -24,6 +29,16 @@ func NewConversationHandler(
 	}
 }
 
+// SetTakeoverUseCase enables taking conversations over from the bot
+func (h *ConversationHandler) SetTakeoverUseCase(takeoverUC *usecase.TakeoverConversationUseCase) {
+	h.takeoverUC = takeoverUC
+}
+
+// SetReopenService enables the reopen and follow-up history of conversations
+func (h *ConversationHandler) SetReopenService(reopenService *service.ConversationReopenService) {
+	h.reopenService = reopenService
+}
+
 // CreateConversationRequest represents a create conversation request
 type CreateConversationRequest struct {
 	ContactID string   `json:"contact_id" binding:"required"`
@@ -45,7 +60,11 @@ type CreateConversationRequest struct {
 // @Param        status query string false "Filter by status (open, pending, resolved)"
 // @Param        assigned_to query string false "Filter by assigned user ID"
 // @Param        channel_id query string false "Filter by channel ID"
+// @Param        search query string false "Search the conversation title and subject"
+// @Param        label query string false "Filter by label ID; repeat or separate with commas to require several labels"
+// @Param        If-None-Match header string false "ETag of a previous response"
 // @Success      200 {object} Response{data=[]entity.Conversation,meta=MetaResponse}
+// @Success      304
 // @Failure      401 {object} Response
 // @Router       /conversations [get]
 func (h *ConversationHandler) List(c *gin.Context) {
@@ -63,6 +82,8 @@ func (h *ConversationHandler) List(c *gin.Context) {
 		Status:     status,
 		AssignedTo: assignedTo,
 		ChannelID:  channelID,
+		Search:     c.Query("search"),
+		LabelIDs:   splitQueryList(c.QueryArray("label")),
 	}
 
 	conversations, total, err := h.conversationService.List(c.Request.Context(), tenantID, filters, nil)
@@ -71,7 +92,7 @@ func (h *ConversationHandler) List(c *gin.Context) {
 		return
 	}
 
-	RespondWithMeta(c, conversations, &MetaResponse{
+	RespondCacheable(c, conversations, &MetaResponse{
 		Page:       1,
 		PageSize:   20,
 		TotalItems: total,
@@ -128,7 +149,9 @@ func (h *ConversationHandler) Create(c *gin.Context) {
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id path string true "Conversation ID"
+// @Param        If-None-Match header string false "ETag of a previous response"
 // @Success      200 {object} Response{data=entity.Conversation}
+// @Success      304
 // @Failure      401 {object} Response
 // @Failure      404 {object} Response
 // @Router       /conversations/{id} [get]
@@ -139,13 +162,18 @@ func (h *ConversationHandler) Get(c *gin.Context) {
 		return
 	}
 
-	conversation, err := h.conversationService.GetByID(c.Request.Context(), id)
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	conversation, err := h.conversationService.GetByID(c.Request.Context(), tenantID, id)
 	if err != nil {
 		RespondError(c, err)
 		return
 	}
 
-	RespondSuccess(c, conversation)
+	RespondCacheable(c, conversation, nil)
 }
 
 // UpdateConversationRequest represents an update conversation request
@@ -177,6 +205,11 @@ func (h *ConversationHandler) Update(c *gin.Context) {
 		return
 	}
 
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
 	var req UpdateConversationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		RespondValidationError(c, "Invalid request body", nil)
@@ -190,7 +223,7 @@ func (h *ConversationHandler) Update(c *gin.Context) {
 		Tags:     req.Tags,
 	}
 
-	conversation, err := h.conversationService.Update(c.Request.Context(), id, input)
+	conversation, err := h.conversationService.Update(c.Request.Context(), tenantID, id, input)
 	if err != nil {
 		RespondError(c, err)
 		return
@@ -225,13 +258,18 @@ func (h *ConversationHandler) Assign(c *gin.Context) {
 		return
 	}
 
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
 	var req AssignRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		RespondValidationError(c, "Invalid request body", nil)
 		return
 	}
 
-	conversation, err := h.conversationService.Assign(c.Request.Context(), id, req.UserID)
+	conversation, err := h.conversationService.Assign(c.Request.Context(), tenantID, id, req.UserID)
 	if err != nil {
 		RespondError(c, err)
 		return
@@ -240,14 +278,22 @@ func (h *ConversationHandler) Assign(c *gin.Context) {
 	RespondSuccess(c, conversation)
 }
 
+// ResolveConversationRequest represents the optional disposition and notes given
+// when resolving a conversation
+type ResolveConversationRequest struct {
+	DispositionCode string `json:"disposition_code,omitempty"`
+	Notes           string `json:"notes,omitempty"`
+}
+
 // Resolve godoc
 // @Summary      Resolve conversation
-// @Description  Mark a conversation as resolved
+// @Description  Mark a conversation as resolved, with a disposition code from the tenant's taxonomy and notes. The disposition is required when the tenant's disposition_required setting is on.
 // @Tags         conversations
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id path string true "Conversation ID"
+// @Param        request body ResolveConversationRequest false "Disposition and notes"
 // @Success      200 {object} Response{data=entity.Conversation}
 // @Failure      400 {object} Response
 // @Failure      401 {object} Response
@@ -260,7 +306,24 @@ func (h *ConversationHandler) Resolve(c *gin.Context) {
 		return
 	}
 
-	conversation, err := h.conversationService.Resolve(c.Request.Context(), id)
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req ResolveConversationRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			RespondValidationError(c, "Invalid request body", nil)
+			return
+		}
+	}
+
+	conversation, err := h.conversationService.Resolve(c.Request.Context(), tenantID, id, &service.ResolveConversationInput{
+		DispositionCode: req.DispositionCode,
+		Notes:           req.Notes,
+		ResolvedBy:      middleware.GetUserID(c),
+	})
 	if err != nil {
 		RespondError(c, err)
 		return
@@ -289,7 +352,12 @@ func (h *ConversationHandler) Reopen(c *gin.Context) {
 		return
 	}
 
-	conversation, err := h.conversationService.Reopen(c.Request.Context(), id)
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	conversation, err := h.conversationService.Reopen(c.Request.Context(), tenantID, id, middleware.GetUserID(c))
 	if err != nil {
 		RespondError(c, err)
 		return
@@ -298,6 +366,36 @@ func (h *ConversationHandler) Reopen(c *gin.Context) {
 	RespondSuccess(c, conversation)
 }
 
+// GetHistory godoc
+// @Summary      Get conversation reopen history
+// @Description  Returns when a conversation was reopened after being resolved, the conversation it follows up on and the conversations following up on it
+// @Tags         conversations
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Success      200 {object} Response{data=entity.ConversationHistory}
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/history [get]
+func (h *ConversationHandler) GetHistory(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if h.reopenService == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Conversation history is not configured"})
+		return
+	}
+
+	history, err := h.reopenService.History(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, history)
+}
+
 // GetEscalationContext godoc
 // @Summary      Get escalation context
 // @Description  Returns the escalation context for a conversation, providing human agents with full context when taking over from a bot
@@ -376,7 +474,7 @@ func (h *ConversationHandler) Escalate(c *gin.Context) {
 	}
 
 	// Get conversation to get channel and contact IDs
-	conversation, err := h.conversationService.GetByID(c.Request.Context(), id)
+	conversation, err := h.conversationService.GetByID(c.Request.Context(), tenantID, id)
 	if err != nil {
 		RespondError(c, err)
 		return
@@ -405,3 +503,115 @@ func (h *ConversationHandler) Escalate(c *gin.Context) {
 
 	RespondSuccess(c, output)
 }
+
+// TakeoverRequest represents a take over from bot request
+type TakeoverRequest struct {
+	NotifyCustomer *bool `json:"notify_customer"` // defaults to sending the bot's handover message when configured
+}
+
+// Takeover godoc
+// @Summary      Take over from bot
+// @Description  Pauses bot and flow automation for the conversation and assigns it to the current user. Bot replies still being generated are discarded. The customer receives the bot's handover message when one is configured, unless notify_customer is false.
+// @Tags         conversations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Param        request body TakeoverRequest false "Takeover options"
+// @Success      200 {object} Response{data=usecase.TakeoverConversationOutput}
+// @Failure      400 {object} Response
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/takeover [post]
+func (h *ConversationHandler) Takeover(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		RespondValidationError(c, "Conversation ID is required", nil)
+		return
+	}
+
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req TakeoverRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			RespondValidationError(c, "Invalid request body", nil)
+			return
+		}
+	}
+
+	if h.takeoverUC == nil {
+		RespondError(c, nil)
+		return
+	}
+
+	output, err := h.takeoverUC.Takeover(c.Request.Context(), &usecase.TakeoverConversationInput{
+		ConversationID: id,
+		TenantID:       tenantID,
+		UserID:         middleware.GetUserID(c),
+		NotifyCustomer: req.NotifyCustomer,
+	})
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	BroadcastConversationUpdate(tenantID, output.Conversation)
+	RespondSuccess(c, output)
+}
+
+// ResumeBot godoc
+// @Summary      Resume bot
+// @Description  Hands a taken over conversation back to the bot: automation resumes and the conversation is unassigned
+// @Tags         conversations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Success      200 {object} Response{data=usecase.TakeoverConversationOutput}
+// @Failure      400 {object} Response
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/resume-bot [post]
+func (h *ConversationHandler) ResumeBot(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		RespondValidationError(c, "Conversation ID is required", nil)
+		return
+	}
+
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if h.takeoverUC == nil {
+		RespondError(c, nil)
+		return
+	}
+
+	output, err := h.takeoverUC.Resume(c.Request.Context(), tenantID, id, middleware.GetUserID(c))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	BroadcastConversationUpdate(tenantID, output.Conversation)
+	RespondSuccess(c, output)
+}
+
+// splitQueryList flattens repeated and comma-separated query values
+func splitQueryList(values []string) []string {
+	var items []string
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
@@ -0,0 +1,440 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+)
+
+// ConversationFormHandler handles conversation forms and their submissions
+type ConversationFormHandler struct {
+	formService *service.ConversationFormService
+}
+
+// NewConversationFormHandler creates a new conversation form handler
+func NewConversationFormHandler(formService *service.ConversationFormService) *ConversationFormHandler {
+	return &ConversationFormHandler{formService: formService}
+}
+
+// ListForms godoc
+// @Summary      List conversation forms
+// @Tags         forms
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=[]entity.ConversationForm}
+// @Router       /forms [get]
+func (h *ConversationFormHandler) ListForms(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	forms, err := h.formService.ListForms(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, forms)
+}
+
+// CreateForm godoc
+// @Summary      Create conversation form
+// @Description  Defines a form with validated fields that agents fill during conversations
+// @Tags         forms
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.ConversationFormInput true "Form"
+// @Success      201 {object} Response{data=entity.ConversationForm}
+// @Failure      400 {object} Response
+// @Router       /forms [post]
+func (h *ConversationFormHandler) CreateForm(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.ConversationFormInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	form, err := h.formService.CreateForm(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, form)
+}
+
+// GetForm godoc
+// @Summary      Get conversation form
+// @Tags         forms
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Form ID"
+// @Success      200 {object} Response{data=entity.ConversationForm}
+// @Failure      404 {object} Response
+// @Router       /forms/{id} [get]
+func (h *ConversationFormHandler) GetForm(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	form, err := h.formService.GetForm(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, form)
+}
+
+// UpdateForm godoc
+// @Summary      Update conversation form
+// @Tags         forms
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Form ID"
+// @Param        request body service.ConversationFormInput true "Form"
+// @Success      200 {object} Response{data=entity.ConversationForm}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /forms/{id} [put]
+func (h *ConversationFormHandler) UpdateForm(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.ConversationFormInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	form, err := h.formService.UpdateForm(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, form)
+}
+
+// DeleteForm godoc
+// @Summary      Delete conversation form
+// @Description  Deletes a form and all of its submissions
+// @Tags         forms
+// @Security     BearerAuth
+// @Param        id path string true "Form ID"
+// @Success      204 "No Content"
+// @Failure      404 {object} Response
+// @Router       /forms/{id} [delete]
+func (h *ConversationFormHandler) DeleteForm(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.formService.DeleteForm(c.Request.Context(), tenantID, c.Param("id")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
+
+// ExportSubmissions godoc
+// @Summary      Export form submissions
+// @Description  Exports the submissions of a form as CSV with one column per field
+// @Tags         forms
+// @Produce      text/csv
+// @Security     BearerAuth
+// @Param        id path string true "Form ID"
+// @Param        status query string false "Submission status (draft, submitted)"
+// @Success      200 {file} file
+// @Failure      404 {object} Response
+// @Router       /forms/{id}/export [get]
+func (h *ConversationFormHandler) ExportSubmissions(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	filter := &entity.FormSubmissionFilter{
+		Status: entity.FormSubmissionStatus(c.Query("status")),
+	}
+
+	data, err := h.formService.ExportCSV(c.Request.Context(), tenantID, c.Param("id"), filter)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=form-submissions.csv")
+	c.Data(http.StatusOK, "text/csv", data)
+}
+
+// ListConversationSubmissions godoc
+// @Summary      List forms filled on a conversation
+// @Tags         forms
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Success      200 {object} Response{data=[]entity.FormSubmission}
+// @Router       /conversations/{id}/forms [get]
+func (h *ConversationFormHandler) ListConversationSubmissions(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	params := &repository.ListParams{Page: 1, PageSize: 100}
+	filter := &entity.FormSubmissionFilter{ConversationID: c.Param("id")}
+
+	submissions, _, err := h.formService.ListSubmissions(c.Request.Context(), tenantID, filter, params)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, submissions)
+}
+
+// StartSubmission godoc
+// @Summary      Start a form on a conversation
+// @Description  Creates a draft submission, optionally pre-filled by the AI from the transcript
+// @Tags         forms
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Param        request body service.StartFormSubmissionInput true "Form and initial values"
+// @Success      201 {object} Response{data=entity.FormSubmission}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/forms [post]
+func (h *ConversationFormHandler) StartSubmission(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	userID := middleware.MustGetUserID(c)
+	if userID == "" {
+		return
+	}
+
+	var req service.StartFormSubmissionInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	submission, err := h.formService.StartSubmission(c.Request.Context(), tenantID, userID, c.Param("id"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, submission)
+}
+
+// ListSubmissions godoc
+// @Summary      List form submissions
+// @Tags         forms
+// @Produce      json
+// @Security     BearerAuth
+// @Param        form_id query string false "Form ID"
+// @Param        conversation_id query string false "Conversation ID"
+// @Param        status query string false "Submission status (draft, submitted)"
+// @Param        page query int false "Page number" default(1)
+// @Param        page_size query int false "Page size" default(20)
+// @Success      200 {object} Response{data=[]entity.FormSubmission,meta=MetaResponse}
+// @Router       /form-submissions [get]
+func (h *ConversationFormHandler) ListSubmissions(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	params := &repository.ListParams{
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	filter := &entity.FormSubmissionFilter{
+		FormID:         c.Query("form_id"),
+		ConversationID: c.Query("conversation_id"),
+		Status:         entity.FormSubmissionStatus(c.Query("status")),
+	}
+
+	submissions, total, err := h.formService.ListSubmissions(c.Request.Context(), tenantID, filter, params)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondPaginated(c, submissions, total, params.Page, params.PageSize)
+}
+
+// GetSubmission godoc
+// @Summary      Get form submission
+// @Tags         forms
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Submission ID"
+// @Success      200 {object} Response{data=entity.FormSubmission}
+// @Failure      404 {object} Response
+// @Router       /form-submissions/{id} [get]
+func (h *ConversationFormHandler) GetSubmission(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	submission, err := h.formService.GetSubmission(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, submission)
+}
+
+// UpdateSubmission godoc
+// @Summary      Update draft form values
+// @Description  Merges values into a draft; a null value clears the field
+// @Tags         forms
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Submission ID"
+// @Param        request body service.FormValuesInput true "Values"
+// @Success      200 {object} Response{data=entity.FormSubmission}
+// @Failure      400 {object} Response
+// @Failure      409 {object} Response
+// @Router       /form-submissions/{id} [put]
+func (h *ConversationFormHandler) UpdateSubmission(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.FormValuesInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	submission, err := h.formService.UpdateSubmission(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, submission)
+}
+
+// Submit godoc
+// @Summary      Submit form
+// @Description  Validates all fields, including required ones, and marks the submission submitted
+// @Tags         forms
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Submission ID"
+// @Param        request body service.FormValuesInput false "Last changes"
+// @Success      200 {object} Response{data=entity.FormSubmission}
+// @Failure      400 {object} Response
+// @Failure      409 {object} Response
+// @Router       /form-submissions/{id}/submit [post]
+func (h *ConversationFormHandler) Submit(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	userID := middleware.MustGetUserID(c)
+	if userID == "" {
+		return
+	}
+
+	var req service.FormValuesInput
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			RespondValidationError(c, "Invalid request body", nil)
+			return
+		}
+	}
+
+	submission, err := h.formService.Submit(c.Request.Context(), tenantID, userID, c.Param("id"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, submission)
+}
+
+// Prefill godoc
+// @Summary      Pre-fill form from transcript
+// @Description  Asks the AI to fill the empty fields of a draft from the conversation
+// @Tags         forms
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Submission ID"
+// @Success      200 {object} Response{data=entity.FormSubmission}
+// @Failure      400 {object} Response
+// @Failure      409 {object} Response
+// @Router       /form-submissions/{id}/prefill [post]
+func (h *ConversationFormHandler) Prefill(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	submission, err := h.formService.Prefill(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, submission)
+}
+
+// DeleteSubmission godoc
+// @Summary      Delete form submission
+// @Tags         forms
+// @Security     BearerAuth
+// @Param        id path string true "Submission ID"
+// @Success      204 "No Content"
+// @Failure      404 {object} Response
+// @Router       /form-submissions/{id} [delete]
+func (h *ConversationFormHandler) DeleteSubmission(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.formService.DeleteSubmission(c.Request.Context(), tenantID, c.Param("id")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
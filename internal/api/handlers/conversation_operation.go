@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// ConversationOperationHandler handles merging and splitting conversations
+type ConversationOperationHandler struct {
+	operationService *service.ConversationOperationService
+}
+
+// NewConversationOperationHandler creates a new conversation operation handler
+func NewConversationOperationHandler(operationService *service.ConversationOperationService) *ConversationOperationHandler {
+	return &ConversationOperationHandler{operationService: operationService}
+}
+
+// Merge godoc
+// @Summary      Merge conversations
+// @Description  Moves every message of the source conversation into this one and closes the source. Both must belong to the same contact.
+// @Tags         conversations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Target conversation ID"
+// @Param        request body service.MergeConversationsInput true "Source conversation"
+// @Success      200 {object} Response{data=entity.ConversationOperation}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/merge [post]
+func (h *ConversationOperationHandler) Merge(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.MergeConversationsInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	op, err := h.operationService.Merge(c.Request.Context(), tenantID, c.Param("id"), middleware.GetUserID(c), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, op)
+}
+
+// Split godoc
+// @Summary      Split conversation
+// @Description  Moves the given messages into a new conversation with the same contact and channel
+// @Tags         conversations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Param        request body service.SplitConversationInput true "Messages to split off"
+// @Success      201 {object} Response{data=service.ConversationSplitResult}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/split [post]
+func (h *ConversationOperationHandler) Split(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.SplitConversationInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	result, err := h.operationService.Split(c.Request.Context(), tenantID, c.Param("id"), middleware.GetUserID(c), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, result)
+}
+
+// ListOperations godoc
+// @Summary      List merges and splits
+// @Description  Audit trail of the merges and splits involving a conversation, newest first
+// @Tags         conversations
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Success      200 {object} Response{data=[]entity.ConversationOperation}
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/operations [get]
+func (h *ConversationOperationHandler) ListOperations(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	operations, err := h.operationService.ListOperations(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, operations)
+}
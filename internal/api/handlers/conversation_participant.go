@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// ConversationParticipantHandler handles the participants of group conversations and
+// the delivery status of group messages per participant
+type ConversationParticipantHandler struct {
+	participantService *service.ConversationParticipantService
+}
+
+// NewConversationParticipantHandler creates a new conversation participant handler
+func NewConversationParticipantHandler(participantService *service.ConversationParticipantService) *ConversationParticipantHandler {
+	return &ConversationParticipantHandler{participantService: participantService}
+}
+
+// AddParticipantRequest represents a contact to add to a group conversation
+type AddParticipantRequest struct {
+	ContactID string `json:"contact_id" binding:"required"`
+}
+
+// List godoc
+// @Summary      List conversation participants
+// @Description  Lists the external participants of a group conversation, removed ones included
+// @Tags         conversations
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Success      200 {object} Response{data=[]entity.ConversationParticipant}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/participants [get]
+func (h *ConversationParticipantHandler) List(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	participants, err := h.participantService.List(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, participants)
+}
+
+// Add godoc
+// @Summary      Add conversation participant
+// @Description  Adds a contact to a group conversation. Email replies copy every active participant.
+// @Tags         conversations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Param        request body AddParticipantRequest true "Contact"
+// @Success      201 {object} Response{data=entity.ConversationParticipant}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/participants [post]
+func (h *ConversationParticipantHandler) Add(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req AddParticipantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	participant, err := h.participantService.Add(c.Request.Context(), tenantID, c.Param("id"), req.ContactID, middleware.GetUserID(c))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, participant)
+}
+
+// Remove godoc
+// @Summary      Remove conversation participant
+// @Description  Removes a contact from a group conversation. The contact of the conversation can't be removed.
+// @Tags         conversations
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Param        contactId path string true "Contact ID"
+// @Success      204
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/participants/{contactId} [delete]
+func (h *ConversationParticipantHandler) Remove(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.participantService.Remove(c.Request.Context(), tenantID, c.Param("id"), c.Param("contactId")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
+
+// ListRecipients godoc
+// @Summary      List message recipients
+// @Description  Lists the delivery status of a group message per participant
+// @Tags         messages
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Message ID"
+// @Success      200 {object} Response{data=[]entity.MessageRecipient}
+// @Failure      404 {object} Response
+// @Router       /messages/{id}/recipients [get]
+func (h *ConversationParticipantHandler) ListRecipients(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	recipients, err := h.participantService.MessageRecipients(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, recipients)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/repository"
+)
+
+// ConversationReassignmentHandler exposes the audit trail of conversations taken from
+// unavailable agents
+type ConversationReassignmentHandler struct {
+	autoUnassignService *service.AutoUnassignService
+}
+
+// NewConversationReassignmentHandler creates a new conversation reassignment handler
+func NewConversationReassignmentHandler(autoUnassignService *service.AutoUnassignService) *ConversationReassignmentHandler {
+	return &ConversationReassignmentHandler{autoUnassignService: autoUnassignService}
+}
+
+// List godoc
+// @Summary      List automatic reassignments
+// @Description  Lists the conversations taken from agents who went offline or stayed away past
+// @Description  the tenant's grace period while the conversations waited for a first reply
+// @Tags         agents
+// @Produce      json
+// @Security     BearerAuth
+// @Param        user_id   query string false "Agent the conversations were taken from or given to"
+// @Param        page      query int    false "Page number" default(1)
+// @Param        page_size query int    false "Page size" default(20)
+// @Success      200 {object} Response{data=[]entity.ConversationReassignment}
+// @Router       /agents/reassignments [get]
+func (h *ConversationReassignmentHandler) List(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	params := &repository.ListParams{
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	reassignments, total, err := h.autoUnassignService.ListReassignments(c.Request.Context(), tenantID, c.Query("user_id"), params)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondPaginated(c, reassignments, total, params.Page, params.PageSize)
+}
+
+// ListConversation godoc
+// @Summary      List automatic reassignments of a conversation
+// @Description  Lists the times the conversation was taken from an unavailable agent
+// @Tags         conversations
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Success      200 {object} Response{data=[]entity.ConversationReassignment}
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/reassignments [get]
+func (h *ConversationReassignmentHandler) ListConversation(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	reassignments, err := h.autoUnassignService.ListConversationReassignments(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, reassignments)
+}
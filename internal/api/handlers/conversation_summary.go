@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// ConversationSummaryHandler handles the AI summaries of conversations
+type ConversationSummaryHandler struct {
+	summaryService *service.ConversationSummaryService
+}
+
+// NewConversationSummaryHandler creates a new conversation summary handler
+func NewConversationSummaryHandler(summaryService *service.ConversationSummaryService) *ConversationSummaryHandler {
+	return &ConversationSummaryHandler{summaryService: summaryService}
+}
+
+// Summarize godoc
+// @Summary      Summarize conversation
+// @Description  Generates a structured summary of the issue, resolution, customer sentiment and follow-ups, replacing the previous summary
+// @Tags         conversations
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Success      200 {object} Response{data=entity.ConversationSummary}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/summarize [post]
+func (h *ConversationSummaryHandler) Summarize(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	summary, err := h.summaryService.Summarize(c.Request.Context(), tenantID, c.Param("id"), middleware.GetUserID(c))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, summary)
+}
+
+// Get godoc
+// @Summary      Get conversation summary
+// @Description  Returns the latest summary of a conversation, generated on request or when it was resolved
+// @Tags         conversations
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Success      200 {object} Response{data=entity.ConversationSummary}
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/summary [get]
+func (h *ConversationSummaryHandler) Get(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	summary, err := h.summaryService.Get(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, summary)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -186,6 +187,73 @@ func TestGet_ReturnsConversation(t *testing.T) {
 	}
 }
 
+func TestGet_IfNoneMatch_Returns304UntilChanged(t *testing.T) {
+	handler, convRepo, _, _ := setupConversationHandler()
+	conv := seedConversation(convRepo, "conv-1", "tenant-1", entity.ConversationStatusOpen)
+
+	get := func(ifNoneMatch string) (*gin.Context, *httptest.ResponseRecorder) {
+		c, w := newAuthContext()
+		c.Params = []gin.Param{{Key: "id", Value: "conv-1"}}
+		if ifNoneMatch != "" {
+			c.Request.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		handler.Get(c)
+		return c, w
+	}
+
+	_, w := get("")
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected status 200 with an ETag, got %d %q", w.Code, etag)
+	}
+
+	c, w := get(etag)
+	if c.Writer.Status() != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Fatalf("expected an empty 304, got %d with %d bytes", c.Writer.Status(), w.Body.Len())
+	}
+	c, _ = get(`"other", ` + strings.TrimPrefix(etag, "W/"))
+	if c.Writer.Status() != http.StatusNotModified {
+		t.Fatalf("expected 304 for a listed ETag, got %d", c.Writer.Status())
+	}
+
+	conv.UnreadCount = 3
+	conv.UpdatedAt = conv.UpdatedAt.Add(time.Second)
+	_, w = get(etag)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200 after a change, got %d", w.Code)
+	}
+	if w.Header().Get("ETag") == etag {
+		t.Fatal("expected a new ETag after a change")
+	}
+}
+
+func TestList_IfNoneMatch_Returns304(t *testing.T) {
+	handler, convRepo, _, _ := setupConversationHandler()
+	seedConversation(convRepo, "conv-1", "tenant-1", entity.ConversationStatusOpen)
+
+	c, w := newAuthContext()
+	handler.List(c)
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" {
+		t.Fatalf("expected status 200 with an ETag, got %d %q", w.Code, etag)
+	}
+
+	c, _ = newAuthContext()
+	c.Request.Header.Set("If-None-Match", etag)
+	handler.List(c)
+	if c.Writer.Status() != http.StatusNotModified {
+		t.Fatalf("expected 304, got %d", c.Writer.Status())
+	}
+
+	seedConversation(convRepo, "conv-2", "tenant-1", entity.ConversationStatusOpen)
+	c, w = newAuthContext()
+	c.Request.Header.Set("If-None-Match", etag)
+	handler.List(c)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200 once the inbox changed, got %d", w.Code)
+	}
+}
+
 func TestGet_NotFound_Returns404(t *testing.T) {
 	handler, _, _, _ := setupConversationHandler()
 
@@ -925,3 +993,44 @@ func TestUpdate_ChangeStatus_Returns200(t *testing.T) {
 		t.Fatalf("expected status 'pending', got %v", data["status"])
 	}
 }
+
+// ---------------------------------------------------------------------------
+// Cross-tenant access
+// ---------------------------------------------------------------------------
+
+func TestConversation_OtherTenant_Returns404(t *testing.T) {
+	handler, convRepo, _, _ := setupConversationHandler()
+	seedConversation(convRepo, "conv-other", "tenant-2", entity.ConversationStatusOpen)
+
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		call   func(c *gin.Context)
+	}{
+		{"Get", http.MethodGet, "", handler.Get},
+		{"Update", http.MethodPut, `{"subject":"Hijacked"}`, handler.Update},
+		{"Assign", http.MethodPost, `{"user_id":"user-1"}`, handler.Assign},
+		{"Resolve", http.MethodPost, "", handler.Resolve},
+		{"Reopen", http.MethodPost, "", handler.Reopen},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, w := newAuthContext()
+			c.Params = []gin.Param{{Key: "id", Value: "conv-other"}}
+			c.Request = httptest.NewRequest(tt.method, "/conversations/conv-other", bytes.NewReader([]byte(tt.body)))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			tt.call(c)
+
+			if w.Code != http.StatusNotFound {
+				t.Fatalf("expected status 404, got %d; body: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+
+	conv := convRepo.Conversations["conv-other"]
+	if conv.Subject != "" || conv.AssignedUserID != nil || conv.Status != entity.ConversationStatusOpen {
+		t.Fatal("expected the other tenant's conversation to be unchanged")
+	}
+}
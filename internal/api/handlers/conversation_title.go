@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// ConversationTitleHandler handles manual overrides and regeneration of conversation titles
+type ConversationTitleHandler struct {
+	titleService *service.ConversationTitleService
+}
+
+// NewConversationTitleHandler creates a new conversation title handler
+func NewConversationTitleHandler(titleService *service.ConversationTitleService) *ConversationTitleHandler {
+	return &ConversationTitleHandler{titleService: titleService}
+}
+
+// SetConversationTitleRequest represents a manual conversation title
+type SetConversationTitleRequest struct {
+	Title string `json:"title" binding:"required"`
+}
+
+// SetTitle godoc
+// @Summary      Set conversation title
+// @Description  Overrides the title of a conversation. Manual titles are kept until the title is regenerated.
+// @Tags         conversations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Param        request body SetConversationTitleRequest true "Title"
+// @Success      200 {object} Response{data=entity.Conversation}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/title [put]
+func (h *ConversationTitleHandler) SetTitle(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req SetConversationTitleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	conversation, err := h.titleService.SetTitle(c.Request.Context(), tenantID, c.Param("id"), req.Title)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, conversation)
+}
+
+// Regenerate godoc
+// @Summary      Regenerate conversation title
+// @Description  Generates a new AI title from the latest messages, replacing a manual override
+// @Tags         conversations
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Success      200 {object} Response{data=entity.Conversation}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/title/regenerate [post]
+func (h *ConversationTitleHandler) Regenerate(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	conversation, err := h.titleService.Regenerate(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, conversation)
+}
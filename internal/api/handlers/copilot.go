@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// CopilotHandler handles the suggestions the agent copilot makes on escalated conversations
+type CopilotHandler struct {
+	copilotService *service.CopilotService
+}
+
+// NewCopilotHandler creates a new copilot handler
+func NewCopilotHandler(copilotService *service.CopilotService) *CopilotHandler {
+	return &CopilotHandler{copilotService: copilotService}
+}
+
+// ListSuggestions godoc
+// @Summary      List copilot suggestions
+// @Description  Lists the latest replies, articles, summaries and similar conversation replies the copilot suggested in a conversation, newest first
+// @Tags         conversations
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Success      200 {object} Response{data=[]entity.CopilotSuggestion}
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/copilot [get]
+func (h *CopilotHandler) ListSuggestions(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	suggestions, err := h.copilotService.ListSuggestions(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, suggestions)
+}
+
+// Suggest godoc
+// @Summary      Ask the copilot for suggestions
+// @Description  Suggests a reply, relevant knowledge base articles and a summary based on the latest customer message
+// @Tags         conversations
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Success      200 {object} Response{data=[]entity.CopilotSuggestion}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/copilot [post]
+func (h *CopilotHandler) Suggest(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	suggestions, err := h.copilotService.Suggest(c.Request.Context(), tenantID, c.Param("id"), middleware.GetUserID(c))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, suggestions)
+}
+
+// Respond godoc
+// @Summary      Respond to a copilot suggestion
+// @Description  Records whether the agent accepted, edited or rejected a suggestion, for copilot quality metrics
+// @Tags         conversations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Suggestion ID"
+// @Param        request body service.CopilotResponseInput true "Outcome"
+// @Success      200 {object} Response{data=entity.CopilotSuggestion}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /copilot/suggestions/{id}/respond [post]
+func (h *CopilotHandler) Respond(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.CopilotResponseInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	suggestion, err := h.copilotService.Respond(c.Request.Context(), tenantID, c.Param("id"), middleware.GetUserID(c), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, suggestion)
+}
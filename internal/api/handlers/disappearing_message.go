@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// DisappearingMessageHandler handles the disappearing messages setting of conversations
+type DisappearingMessageHandler struct {
+	disappearingService *service.DisappearingMessageService
+}
+
+// NewDisappearingMessageHandler creates a new disappearing message handler
+func NewDisappearingMessageHandler(disappearingService *service.DisappearingMessageService) *DisappearingMessageHandler {
+	return &DisappearingMessageHandler{disappearingService: disappearingService}
+}
+
+// Get godoc
+// @Summary      Get disappearing messages setting
+// @Description  Returns the disappearing timer of the conversation's WhatsApp chat, as last seen
+// @Description  on the phone, and what happens to the stored messages once they disappear
+// @Tags         conversations
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Success      200 {object} Response{data=service.DisappearingSettings}
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/disappearing-messages [get]
+func (h *DisappearingMessageHandler) Get(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	settings, err := h.disappearingService.Get(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, settings)
+}
+
+// Set godoc
+// @Summary      Set disappearing messages
+// @Description  Turns disappearing messages on or off in the conversation's WhatsApp chat. The
+// @Description  timer is 0 (off), 86400 (24 hours), 604800 (7 days) or 7776000 (90 days) seconds.
+// @Tags         conversations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Param        request body service.SetDisappearingTimerInput true "Disappearing timer"
+// @Success      200 {object} Response{data=service.DisappearingSettings}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/disappearing-messages [put]
+func (h *DisappearingMessageHandler) Set(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var input service.SetDisappearingTimerInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	settings, err := h.disappearingService.SetTimer(c.Request.Context(), tenantID, c.Param("id"), &input)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, settings)
+}
@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// DispositionHandler handles the disposition taxonomy of tenants and the dispositions
+// of resolved conversations
+type DispositionHandler struct {
+	dispositionService *service.DispositionService
+}
+
+// NewDispositionHandler creates a new disposition handler
+func NewDispositionHandler(dispositionService *service.DispositionService) *DispositionHandler {
+	return &DispositionHandler{dispositionService: dispositionService}
+}
+
+// List godoc
+// @Summary      List dispositions
+// @Description  Lists the dispositions agents pick when resolving conversations, by position and name
+// @Tags         dispositions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        active query bool false "Only active dispositions"
+// @Success      200 {object} Response{data=[]entity.Disposition}
+// @Router       /dispositions [get]
+func (h *DispositionHandler) List(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	dispositions, err := h.dispositionService.List(c.Request.Context(), tenantID, c.Query("active") == "true")
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, dispositions)
+}
+
+// Create godoc
+// @Summary      Create disposition
+// @Description  Creates a disposition agents can pick when resolving conversations. Codes are unique per tenant.
+// @Tags         dispositions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.DispositionInput true "Disposition"
+// @Success      201 {object} Response{data=entity.Disposition}
+// @Failure      400 {object} Response
+// @Failure      409 {object} Response
+// @Router       /dispositions [post]
+func (h *DispositionHandler) Create(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.DispositionInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	disposition, err := h.dispositionService.Create(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, disposition)
+}
+
+// Get godoc
+// @Summary      Get disposition
+// @Tags         dispositions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Disposition ID"
+// @Success      200 {object} Response{data=entity.Disposition}
+// @Failure      404 {object} Response
+// @Router       /dispositions/{id} [get]
+func (h *DispositionHandler) Get(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	disposition, err := h.dispositionService.Get(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, disposition)
+}
+
+// Update godoc
+// @Summary      Update disposition
+// @Description  Updates a disposition. Conversations already resolved keep the code and name they were given.
+// @Tags         dispositions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Disposition ID"
+// @Param        request body service.DispositionInput true "Disposition"
+// @Success      200 {object} Response{data=entity.Disposition}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Failure      409 {object} Response
+// @Router       /dispositions/{id} [put]
+func (h *DispositionHandler) Update(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.DispositionInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	disposition, err := h.dispositionService.Update(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, disposition)
+}
+
+// Delete godoc
+// @Summary      Delete disposition
+// @Description  Deletes a disposition no conversation was resolved with. Used dispositions must be deactivated instead.
+// @Tags         dispositions
+// @Security     BearerAuth
+// @Param        id path string true "Disposition ID"
+// @Success      204
+// @Failure      404 {object} Response
+// @Failure      409 {object} Response
+// @Router       /dispositions/{id} [delete]
+func (h *DispositionHandler) Delete(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.dispositionService.Delete(c.Request.Context(), tenantID, c.Param("id")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
+
+// GetConversationDisposition godoc
+// @Summary      Get conversation disposition
+// @Description  Returns the disposition and notes given to a conversation when it was resolved
+// @Tags         conversations
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Success      200 {object} Response{data=entity.ConversationDisposition}
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/disposition [get]
+func (h *DispositionHandler) GetConversationDisposition(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	disposition, err := h.dispositionService.GetForConversation(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, disposition)
+}
+
+// SetConversationDisposition godoc
+// @Summary      Set conversation disposition
+// @Description  Sets or corrects the disposition and notes of a resolved conversation
+// @Tags         conversations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Param        request body ResolveConversationRequest true "Disposition and notes"
+// @Success      200 {object} Response{data=entity.ConversationDisposition}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/disposition [put]
+func (h *DispositionHandler) SetConversationDisposition(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req ResolveConversationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	disposition, err := h.dispositionService.SetForConversation(c.Request.Context(), tenantID, c.Param("id"), &service.ResolveConversationInput{
+		DispositionCode: req.DispositionCode,
+		Notes:           req.Notes,
+		ResolvedBy:      middleware.GetUserID(c),
+	})
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, disposition)
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// DocumentPipelineHandler handles document parsing pipelines and parsed documents
+type DocumentPipelineHandler struct {
+	documentService *service.DocumentParsingService
+}
+
+// NewDocumentPipelineHandler creates a new document pipeline handler
+func NewDocumentPipelineHandler(documentService *service.DocumentParsingService) *DocumentPipelineHandler {
+	return &DocumentPipelineHandler{documentService: documentService}
+}
+
+// List godoc
+// @Summary      List document pipelines
+// @Tags         documents
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=[]entity.DocumentPipeline}
+// @Router       /document-pipelines [get]
+func (h *DocumentPipelineHandler) List(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	pipelines, err := h.documentService.List(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, pipelines)
+}
+
+// Create godoc
+// @Summary      Create document pipeline
+// @Description  Creates a pipeline that detects the type of documents customers send and extracts their key fields into the conversation metadata
+// @Tags         documents
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.DocumentPipelineInput true "Pipeline"
+// @Success      201 {object} Response{data=entity.DocumentPipeline}
+// @Failure      400 {object} Response
+// @Router       /document-pipelines [post]
+func (h *DocumentPipelineHandler) Create(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.DocumentPipelineInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	pipeline, err := h.documentService.Create(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, pipeline)
+}
+
+// Test godoc
+// @Summary      Test document pipeline
+// @Description  Detects the document type of a sample text and extracts its fields without saving anything
+// @Tags         documents
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.TestDocumentPipelineInput true "Document types and sample text"
+// @Success      200 {object} Response{data=entity.DocumentExtraction}
+// @Failure      400 {object} Response
+// @Router       /document-pipelines/test [post]
+func (h *DocumentPipelineHandler) Test(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.TestDocumentPipelineInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	result, err := h.documentService.Test(c.Request.Context(), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, result)
+}
+
+// Get godoc
+// @Summary      Get document pipeline
+// @Tags         documents
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Pipeline ID"
+// @Success      200 {object} Response{data=entity.DocumentPipeline}
+// @Failure      404 {object} Response
+// @Router       /document-pipelines/{id} [get]
+func (h *DocumentPipelineHandler) Get(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	pipeline, err := h.documentService.Get(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, pipeline)
+}
+
+// Update godoc
+// @Summary      Update document pipeline
+// @Tags         documents
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Pipeline ID"
+// @Param        request body service.DocumentPipelineInput true "Pipeline"
+// @Success      200 {object} Response{data=entity.DocumentPipeline}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /document-pipelines/{id} [put]
+func (h *DocumentPipelineHandler) Update(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.DocumentPipelineInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	pipeline, err := h.documentService.Update(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, pipeline)
+}
+
+// Delete godoc
+// @Summary      Delete document pipeline
+// @Tags         documents
+// @Security     BearerAuth
+// @Param        id path string true "Pipeline ID"
+// @Success      204 "No Content"
+// @Failure      404 {object} Response
+// @Router       /document-pipelines/{id} [delete]
+func (h *DocumentPipelineHandler) Delete(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.documentService.Delete(c.Request.Context(), tenantID, c.Param("id")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
+
+// ListConversationDocuments godoc
+// @Summary      List parsed documents of a conversation
+// @Tags         documents
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Success      200 {object} Response{data=[]entity.DocumentExtraction}
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/documents [get]
+func (h *DocumentPipelineHandler) ListConversationDocuments(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	extractions, err := h.documentService.ListExtractions(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, extractions)
+}
+
+// ParseMessage godoc
+// @Summary      Parse message documents
+// @Description  Runs the active pipelines on the attachments of a stored message and waits for the result
+// @Tags         documents
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Param        messageId path string true "Message ID"
+// @Success      200 {object} Response{data=[]entity.DocumentExtraction}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/messages/{messageId}/parse [post]
+func (h *DocumentPipelineHandler) ParseMessage(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	extractions, err := h.documentService.ProcessMessage(c.Request.Context(), tenantID, c.Param("id"), c.Param("messageId"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, extractions)
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// EncryptionHandler handles the per-tenant encryption keys of message content
+type EncryptionHandler struct {
+	encryptionService *service.EncryptionService
+}
+
+// NewEncryptionHandler creates a new encryption handler
+func NewEncryptionHandler(encryptionService *service.EncryptionService) *EncryptionHandler {
+	return &EncryptionHandler{encryptionService: encryptionService}
+}
+
+// Status godoc
+// @Summary      Get encryption status
+// @Description  Returns whether message encryption is enabled and the key versions of the tenant
+// @Tags         encryption
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=entity.TenantEncryptionStatus}
+// @Router       /encryption [get]
+func (h *EncryptionHandler) Status(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	status, err := h.encryptionService.Status(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, status)
+}
+
+// Enable godoc
+// @Summary      Enable encryption
+// @Description  Encrypts new message content and attachment references with a tenant key managed by the platform, supplied by the tenant or held in an external KMS
+// @Tags         encryption
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.EncryptionKeyInput true "Key provider"
+// @Success      201 {object} Response{data=entity.TenantEncryptionKey}
+// @Failure      400 {object} Response
+// @Failure      409 {object} Response
+// @Router       /encryption/enable [post]
+func (h *EncryptionHandler) Enable(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.EncryptionKeyInput
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			RespondValidationError(c, "Invalid request body", nil)
+			return
+		}
+	}
+
+	key, err := h.encryptionService.Enable(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, key)
+}
+
+// Rotate godoc
+// @Summary      Rotate encryption key
+// @Description  Creates a new data key version for new data; data written under previous versions stays readable
+// @Tags         encryption
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.EncryptionKeyInput false "New key provider or key material"
+// @Success      201 {object} Response{data=entity.TenantEncryptionKey}
+// @Failure      400 {object} Response
+// @Router       /encryption/rotate [post]
+func (h *EncryptionHandler) Rotate(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.EncryptionKeyInput
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			RespondValidationError(c, "Invalid request body", nil)
+			return
+		}
+	}
+
+	key, err := h.encryptionService.Rotate(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, key)
+}
+
+// Disable godoc
+// @Summary      Disable encryption
+// @Description  Stops encrypting new data; existing data stays encrypted and readable
+// @Tags         encryption
+// @Security     BearerAuth
+// @Success      204 "No Content"
+// @Failure      400 {object} Response
+// @Router       /encryption/disable [post]
+func (h *EncryptionHandler) Disable(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.encryptionService.Disable(c.Request.Context(), tenantID); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// FAQWidgetHandler handles the public endpoints of the embeddable FAQ widget
+type FAQWidgetHandler struct {
+	faqWidgetService *service.FAQWidgetService
+}
+
+// NewFAQWidgetHandler creates a new FAQ widget handler
+func NewFAQWidgetHandler(faqWidgetService *service.FAQWidgetService) *FAQWidgetHandler {
+	return &FAQWidgetHandler{faqWidgetService: faqWidgetService}
+}
+
+// RateLimitKey identifies the visitor of an FAQ widget request for rate limiting
+func (h *FAQWidgetHandler) RateLimitKey(c *gin.Context) string {
+	return "faq:" + c.Param("channelId") + ":" + c.ClientIP()
+}
+
+// AskRequest represents a visitor question
+type AskRequest struct {
+	Question string `json:"question" binding:"required"`
+}
+
+// Ask godoc
+// @Summary      Ask the FAQ widget
+// @Description  Answers a visitor question from the knowledge base of the webchat channel, set by its faq_knowledge_base_id config or its bot. No conversation is created.
+// @Tags         public-faq
+// @Accept       json
+// @Produce      json
+// @Param        channelId path string true "Webchat channel ID"
+// @Param        request body AskRequest true "Question"
+// @Success      200 {object} Response{data=service.FAQAnswer}
+// @Failure      400 {object} Response
+// @Failure      403 {object} Response
+// @Failure      404 {object} Response
+// @Failure      429 {object} Response
+// @Router       /public/faq/{channelId}/ask [post]
+func (h *FAQWidgetHandler) Ask(c *gin.Context) {
+	var req AskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	answer, err := h.faqWidgetService.Ask(c.Request.Context(), c.Param("channelId"), req.Question)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, answer)
+}
+
+// Escalate godoc
+// @Summary      Talk to a person from the FAQ widget
+// @Description  Starts a webchat conversation for the session with the visitor's question as first message and the answer they were shown attached. The visitor continues in the chat widget with the same session ID.
+// @Tags         public-faq
+// @Accept       json
+// @Produce      json
+// @Param        channelId path string true "Webchat channel ID"
+// @Param        request body service.FAQEscalationInput true "Escalation"
+// @Success      202 {object} Response{data=service.FAQEscalation}
+// @Failure      400 {object} Response
+// @Failure      403 {object} Response
+// @Failure      404 {object} Response
+// @Failure      429 {object} Response
+// @Router       /public/faq/{channelId}/escalate [post]
+func (h *FAQWidgetHandler) Escalate(c *gin.Context) {
+	var req service.FAQEscalationInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	escalation, err := h.faqWidgetService.Escalate(c.Request.Context(), c.Param("channelId"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondAccepted(c, escalation)
+}
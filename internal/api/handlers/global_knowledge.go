@@ -0,0 +1,428 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+)
+
+// GlobalKnowledgeHandler handles the system administration of global knowledge
+// bases, which the platform maintains and attaches read-only to tenants
+type GlobalKnowledgeHandler struct {
+	knowledgeService *service.KnowledgeService
+}
+
+// NewGlobalKnowledgeHandler creates a new global knowledge handler
+func NewGlobalKnowledgeHandler(knowledgeService *service.KnowledgeService) *GlobalKnowledgeHandler {
+	return &GlobalKnowledgeHandler{knowledgeService: knowledgeService}
+}
+
+// AttachKnowledgeBaseRequest represents the attachment of a global knowledge base to a tenant
+type AttachKnowledgeBaseRequest struct {
+	TenantID   string `json:"tenant_id" binding:"required"`
+	AttachedBy string `json:"attached_by"`
+}
+
+// List godoc
+// @Summary      List global knowledge bases
+// @Tags         system
+// @Produce      json
+// @Param        X-Admin-Token header string true "System administration token"
+// @Param        page query int false "Page number" default(1)
+// @Param        page_size query int false "Page size" default(20)
+// @Success      200 {object} Response{data=[]entity.KnowledgeBase}
+// @Failure      401 {object} Response
+// @Router       /system/knowledge-bases [get]
+func (h *GlobalKnowledgeHandler) List(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	params := &repository.ListParams{
+		Page:     page,
+		PageSize: pageSize,
+		SortBy:   c.DefaultQuery("sort_by", "created_at"),
+		SortDir:  c.DefaultQuery("sort_dir", "desc"),
+	}
+
+	kbs, total, err := h.knowledgeService.ListGlobalKnowledgeBases(c.Request.Context(), params)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondPaginated(c, kbs, total, params.Page, params.PageSize)
+}
+
+// Create godoc
+// @Summary      Create global knowledge base
+// @Description  Creates a knowledge base belonging to no tenant, such as product documentation, to be attached read-only to tenants
+// @Tags         system
+// @Accept       json
+// @Produce      json
+// @Param        X-Admin-Token header string true "System administration token"
+// @Param        request body CreateKnowledgeBaseRequest true "Knowledge base data"
+// @Success      201 {object} Response{data=entity.KnowledgeBase}
+// @Failure      400 {object} Response
+// @Failure      401 {object} Response
+// @Router       /system/knowledge-bases [post]
+func (h *GlobalKnowledgeHandler) Create(c *gin.Context) {
+	var req CreateKnowledgeBaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	kb, err := h.knowledgeService.CreateKnowledgeBase(c.Request.Context(), &service.CreateKnowledgeBaseInput{
+		Name:        req.Name,
+		Description: req.Description,
+		Type:        entity.KnowledgeType(req.Type),
+		Config:      req.Config,
+		Global:      true,
+	})
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, kb)
+}
+
+// Get godoc
+// @Summary      Get global knowledge base
+// @Tags         system
+// @Produce      json
+// @Param        X-Admin-Token header string true "System administration token"
+// @Param        id path string true "Knowledge base ID"
+// @Success      200 {object} Response{data=entity.KnowledgeBase}
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Router       /system/knowledge-bases/{id} [get]
+func (h *GlobalKnowledgeHandler) Get(c *gin.Context) {
+	kb, err := h.knowledgeService.GlobalKnowledgeBase(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, kb)
+}
+
+// Update godoc
+// @Summary      Update global knowledge base
+// @Tags         system
+// @Accept       json
+// @Produce      json
+// @Param        X-Admin-Token header string true "System administration token"
+// @Param        id path string true "Knowledge base ID"
+// @Param        request body UpdateKnowledgeBaseRequest true "Knowledge base update data"
+// @Success      200 {object} Response{data=entity.KnowledgeBase}
+// @Failure      400 {object} Response
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Router       /system/knowledge-bases/{id} [put]
+func (h *GlobalKnowledgeHandler) Update(c *gin.Context) {
+	existing, err := h.knowledgeService.GlobalKnowledgeBase(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	var req UpdateKnowledgeBaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	kb, err := h.knowledgeService.UpdateKnowledgeBase(c.Request.Context(), existing.ID, &service.UpdateKnowledgeBaseInput{
+		Name:        req.Name,
+		Description: req.Description,
+		Config:      req.Config,
+	})
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, kb)
+}
+
+// Delete godoc
+// @Summary      Delete global knowledge base
+// @Description  Deletes a global knowledge base with its items, the tenants' overrides and its attachments. Bots using it stop answering from it.
+// @Tags         system
+// @Produce      json
+// @Param        X-Admin-Token header string true "System administration token"
+// @Param        id path string true "Knowledge base ID"
+// @Success      204 "No Content"
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Router       /system/knowledge-bases/{id} [delete]
+func (h *GlobalKnowledgeHandler) Delete(c *gin.Context) {
+	kb, err := h.knowledgeService.GlobalKnowledgeBase(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	if err := h.knowledgeService.DeleteKnowledgeBase(c.Request.Context(), kb.ID); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
+
+// ListItems godoc
+// @Summary      List global knowledge base items
+// @Description  Returns the items of a global knowledge base, including the override items of tenants, which carry their tenant_id
+// @Tags         system
+// @Produce      json
+// @Param        X-Admin-Token header string true "System administration token"
+// @Param        id path string true "Knowledge base ID"
+// @Param        page query int false "Page number" default(1)
+// @Param        page_size query int false "Page size" default(20)
+// @Success      200 {object} Response{data=[]entity.KnowledgeItem}
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Router       /system/knowledge-bases/{id}/items [get]
+func (h *GlobalKnowledgeHandler) ListItems(c *gin.Context) {
+	kb, err := h.knowledgeService.GlobalKnowledgeBase(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	params := &repository.ListParams{
+		Page:     page,
+		PageSize: pageSize,
+		SortBy:   c.DefaultQuery("sort_by", "created_at"),
+		SortDir:  c.DefaultQuery("sort_dir", "desc"),
+	}
+
+	items, total, err := h.knowledgeService.ListItems(c.Request.Context(), kb.ID, params)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondPaginated(c, items, total, params.Page, params.PageSize)
+}
+
+// AddItem godoc
+// @Summary      Add global knowledge base item
+// @Description  Adds a shared item to a global knowledge base, seen by every tenant it is attached to
+// @Tags         system
+// @Accept       json
+// @Produce      json
+// @Param        X-Admin-Token header string true "System administration token"
+// @Param        id path string true "Knowledge base ID"
+// @Param        request body AddItemRequest true "Item data"
+// @Success      201 {object} Response{data=entity.KnowledgeItem}
+// @Failure      400 {object} Response
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Router       /system/knowledge-bases/{id}/items [post]
+func (h *GlobalKnowledgeHandler) AddItem(c *gin.Context) {
+	kb, err := h.knowledgeService.GlobalKnowledgeBase(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	var req AddItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	item, err := h.knowledgeService.AddItem(c.Request.Context(), &service.AddItemInput{
+		KnowledgeBaseID: kb.ID,
+		Question:        req.Question,
+		Answer:          req.Answer,
+		Keywords:        req.Keywords,
+		Source:          req.Source,
+		Metadata:        req.Metadata,
+		OverridesItemID: req.OverridesItemID,
+	})
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, item)
+}
+
+// UpdateItem godoc
+// @Summary      Update global knowledge base item
+// @Description  Updates a shared item of a global knowledge base. Tenants' overrides are theirs to change.
+// @Tags         system
+// @Accept       json
+// @Produce      json
+// @Param        X-Admin-Token header string true "System administration token"
+// @Param        id path string true "Knowledge base ID"
+// @Param        itemId path string true "Item ID"
+// @Param        request body UpdateItemRequest true "Item update data"
+// @Success      200 {object} Response{data=entity.KnowledgeItem}
+// @Failure      400 {object} Response
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Router       /system/knowledge-bases/{id}/items/{itemId} [put]
+func (h *GlobalKnowledgeHandler) UpdateItem(c *gin.Context) {
+	existing, err := h.knowledgeService.SharedItem(c.Request.Context(), c.Param("id"), c.Param("itemId"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	var req UpdateItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	item, err := h.knowledgeService.UpdateItem(c.Request.Context(), existing.ID, &service.UpdateItemInput{
+		Question: req.Question,
+		Answer:   req.Answer,
+		Keywords: req.Keywords,
+		Source:   req.Source,
+		Metadata: req.Metadata,
+	})
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, item)
+}
+
+// DeleteItem godoc
+// @Summary      Delete global knowledge base item
+// @Description  Deletes a shared item of a global knowledge base. Tenants' overrides of it stay as their own items.
+// @Tags         system
+// @Produce      json
+// @Param        X-Admin-Token header string true "System administration token"
+// @Param        id path string true "Knowledge base ID"
+// @Param        itemId path string true "Item ID"
+// @Success      204 "No Content"
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Router       /system/knowledge-bases/{id}/items/{itemId} [delete]
+func (h *GlobalKnowledgeHandler) DeleteItem(c *gin.Context) {
+	item, err := h.knowledgeService.SharedItem(c.Request.Context(), c.Param("id"), c.Param("itemId"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	if err := h.knowledgeService.DeleteItem(c.Request.Context(), item.ID, ""); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
+
+// RegenerateEmbeddings godoc
+// @Summary      Regenerate global knowledge base embeddings
+// @Description  Regenerates the vector embeddings of all items of a global knowledge base, the tenants' overrides included
+// @Tags         system
+// @Produce      json
+// @Param        X-Admin-Token header string true "System administration token"
+// @Param        id path string true "Knowledge base ID"
+// @Success      200 {object} Response{data=object{message=string}}
+// @Failure      400 {object} Response
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Router       /system/knowledge-bases/{id}/regenerate-embeddings [post]
+func (h *GlobalKnowledgeHandler) RegenerateEmbeddings(c *gin.Context) {
+	kb, err := h.knowledgeService.GlobalKnowledgeBase(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	if _, err := h.knowledgeService.RegenerateEmbeddings(c.Request.Context(), kb.ID, ""); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, gin.H{"message": "Embeddings regenerated"})
+}
+
+// ListTenants godoc
+// @Summary      List global knowledge base tenants
+// @Description  Returns the tenants a global knowledge base is attached to
+// @Tags         system
+// @Produce      json
+// @Param        X-Admin-Token header string true "System administration token"
+// @Param        id path string true "Knowledge base ID"
+// @Success      200 {object} Response{data=[]entity.KnowledgeBaseAttachment}
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Router       /system/knowledge-bases/{id}/tenants [get]
+func (h *GlobalKnowledgeHandler) ListTenants(c *gin.Context) {
+	attachments, err := h.knowledgeService.ListAttachments(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, attachments)
+}
+
+// Attach godoc
+// @Summary      Attach global knowledge base
+// @Description  Attaches a global knowledge base to a tenant, which may then point its bots at it and add override items to it. Attaching it again is a no-op.
+// @Tags         system
+// @Accept       json
+// @Produce      json
+// @Param        X-Admin-Token header string true "System administration token"
+// @Param        id path string true "Knowledge base ID"
+// @Param        request body AttachKnowledgeBaseRequest true "Tenant to attach to"
+// @Success      200 {object} Response{data=entity.KnowledgeBaseAttachment}
+// @Failure      400 {object} Response
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Router       /system/knowledge-bases/{id}/tenants [post]
+func (h *GlobalKnowledgeHandler) Attach(c *gin.Context) {
+	var req AttachKnowledgeBaseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	attachment, err := h.knowledgeService.AttachKnowledgeBase(c.Request.Context(), c.Param("id"), req.TenantID, req.AttachedBy)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, attachment)
+}
+
+// Detach godoc
+// @Summary      Detach global knowledge base
+// @Description  Detaches a global knowledge base from a tenant, whose bots stop answering from it. The tenant's overrides are kept for when it is attached again.
+// @Tags         system
+// @Produce      json
+// @Param        X-Admin-Token header string true "System administration token"
+// @Param        id path string true "Knowledge base ID"
+// @Param        tenantId path string true "Tenant ID"
+// @Success      204 "No Content"
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Router       /system/knowledge-bases/{id}/tenants/{tenantId} [delete]
+func (h *GlobalKnowledgeHandler) Detach(c *gin.Context) {
+	if err := h.knowledgeService.DetachKnowledgeBase(c.Request.Context(), c.Param("id"), c.Param("tenantId")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
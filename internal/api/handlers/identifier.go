@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// IdentifierHandler handles identifier validation
+type IdentifierHandler struct {
+	identifierService *service.IdentifierService
+}
+
+// NewIdentifierHandler creates a new identifier handler
+func NewIdentifierHandler(identifierService *service.IdentifierService) *IdentifierHandler {
+	return &IdentifierHandler{identifierService: identifierService}
+}
+
+// ValidateIdentifiersRequest represents identifiers to validate for a channel type
+type ValidateIdentifiersRequest struct {
+	ChannelType string   `json:"channel_type" binding:"required"`
+	Identifiers []string `json:"identifiers" binding:"required"`
+}
+
+// Validate godoc
+// @Summary      Validate identifiers
+// @Description  Validates phone numbers, emails or Telegram usernames for a channel type and returns them normalized, e.g. to check a contact list before importing it
+// @Tags         contacts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body ValidateIdentifiersRequest true "Identifiers"
+// @Success      200 {object} Response{data=[]service.IdentifierValidation}
+// @Failure      400 {object} Response
+// @Router       /identifiers/validate [post]
+func (h *IdentifierHandler) Validate(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req ValidateIdentifiersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	results, err := h.identifierService.ValidateIdentifiers(c.Request.Context(), tenantID, entity.ChannelType(req.ChannelType), req.Identifiers)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, results)
+}
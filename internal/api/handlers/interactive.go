@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// InteractiveHandler handles the interactive message builder
+type InteractiveHandler struct {
+	builder *service.InteractiveBuilderService
+}
+
+// NewInteractiveHandler creates a new interactive message handler
+func NewInteractiveHandler(builder *service.InteractiveBuilderService) *InteractiveHandler {
+	return &InteractiveHandler{builder: builder}
+}
+
+// BuildInteractiveRequest represents a request to compose an interactive message
+type BuildInteractiveRequest struct {
+	ChannelType string                     `json:"channel_type" binding:"required"`
+	Message     *entity.InteractiveMessage `json:"message" binding:"required"`
+}
+
+// Build validates an interactive message against a channel's limits and returns its payload
+// @Summary      Build interactive message
+// @Description  Composes buttons, lists or CTA messages into a payload usable by the send API, flows and bots
+// @Tags         messages
+// @Accept       json
+// @Produce      json
+// @Param        request body BuildInteractiveRequest true "Interactive message"
+// @Success      200 {object} Response{data=service.InteractivePayload}
+// @Failure      400 {object} Response
+// @Security     BearerAuth
+// @Router       /interactive/build [post]
+func (h *InteractiveHandler) Build(c *gin.Context) {
+	var req BuildInteractiveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	payload, err := h.builder.Build(entity.ChannelType(req.ChannelType), req.Message)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, payload)
+}
+
+// GetConstraints returns the interactive message limits of a channel type
+// @Summary      Get interactive constraints
+// @Description  Returns which interactive elements a channel type supports and their limits
+// @Tags         messages
+// @Produce      json
+// @Param        channelType path string true "Channel type"
+// @Success      200 {object} Response{data=entity.InteractiveConstraints}
+// @Failure      404 {object} Response
+// @Security     BearerAuth
+// @Router       /interactive/constraints/{channelType} [get]
+func (h *InteractiveHandler) GetConstraints(c *gin.Context) {
+	constraints, ok := entity.InteractiveConstraintsFor(entity.ChannelType(c.Param("channelType")))
+	if !ok {
+		RespondNotFound(c, "Interactive constraints")
+		return
+	}
+
+	RespondSuccess(c, constraints)
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupInteractiveHandler() *InteractiveHandler {
+	return NewInteractiveHandler(service.NewInteractiveBuilderService())
+}
+
+func TestInteractiveBuild_WhatsAppButtons(t *testing.T) {
+	handler := setupInteractiveHandler()
+	w, c := newTestContext(http.MethodPost, "/interactive/build", BuildInteractiveRequest{
+		ChannelType: "whatsapp_official",
+		Message: &entity.InteractiveMessage{
+			Body:    "Confirm your order?",
+			Buttons: []entity.InteractiveButton{{ID: "yes", Title: "Yes"}, {ID: "no", Title: "No"}},
+		},
+	})
+
+	handler.Build(c)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Data service.InteractivePayload `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, entity.ContentTypeInteractive, resp.Data.ContentType)
+	assert.Equal(t, "button", resp.Data.Metadata["interactive_type"])
+	assert.True(t, resp.Data.Native)
+}
+
+func TestInteractiveBuild_TooManyButtons(t *testing.T) {
+	handler := setupInteractiveHandler()
+	w, c := newTestContext(http.MethodPost, "/interactive/build", BuildInteractiveRequest{
+		ChannelType: "whatsapp_official",
+		Message: &entity.InteractiveMessage{
+			Type: entity.InteractiveTypeButton,
+			Body: "Pick one",
+			Buttons: []entity.InteractiveButton{
+				{ID: "1", Title: "One"}, {ID: "2", Title: "Two"}, {ID: "3", Title: "Three"}, {ID: "4", Title: "Four"},
+			},
+		},
+	})
+
+	handler.Build(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "at most 3 buttons")
+}
+
+func TestInteractiveBuild_InvalidBody(t *testing.T) {
+	handler := setupInteractiveHandler()
+	w, c := newTestContext(http.MethodPost, "/interactive/build", map[string]string{"channel_type": "telegram"})
+
+	handler.Build(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestInteractiveGetConstraints(t *testing.T) {
+	handler := setupInteractiveHandler()
+
+	w, c := newTestContext(http.MethodGet, "/interactive/constraints/whatsapp_official", nil)
+	c.Params = gin.Params{{Key: "channelType", Value: "whatsapp_official"}}
+	handler.GetConstraints(c)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"max_buttons":3`)
+
+	w, c = newTestContext(http.MethodGet, "/interactive/constraints/sms", nil)
+	c.Params = gin.Params{{Key: "channelType", Value: "sms"}}
+	handler.GetConstraints(c)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+)
+
+// JobHandler handles background job endpoints
+type JobHandler struct {
+	jobService *service.JobService
+}
+
+// NewJobHandler creates a new job handler
+func NewJobHandler(jobService *service.JobService) *JobHandler {
+	return &JobHandler{jobService: jobService}
+}
+
+// List godoc
+// @Summary      List jobs
+// @Description  Lists the background jobs of the tenant, newest first
+// @Tags         jobs
+// @Produce      json
+// @Security     BearerAuth
+// @Param        type      query string false "Job type"
+// @Param        status    query string false "pending, running, succeeded, failed or cancelled"
+// @Param        page      query int    false "Page number" default(1)
+// @Param        page_size query int    false "Page size" default(20)
+// @Success      200 {object} Response{data=[]entity.Job}
+// @Router       /jobs [get]
+func (h *JobHandler) List(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	params := &repository.ListParams{
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	filter := &entity.JobFilter{
+		Type:   c.Query("type"),
+		Status: entity.JobStatus(c.Query("status")),
+	}
+
+	jobs, total, err := h.jobService.List(c.Request.Context(), tenantID, filter, params)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondPaginated(c, jobs, total, params.Page, params.PageSize)
+}
+
+// Get godoc
+// @Summary      Get a job
+// @Description  Returns the status, progress and result of a background job
+// @Tags         jobs
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Job ID"
+// @Success      200 {object} Response{data=entity.Job}
+// @Failure      404 {object} Response
+// @Router       /jobs/{id} [get]
+func (h *JobHandler) Get(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	job, err := h.jobService.Get(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, job)
+}
+
+// Cancel godoc
+// @Summary      Cancel a job
+// @Description  Cancels a pending or running job; running jobs stop at their next progress report
+// @Tags         jobs
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Job ID"
+// @Success      200 {object} Response{data=entity.Job}
+// @Failure      404 {object} Response
+// @Failure      409 {object} Response
+// @Router       /jobs/{id}/cancel [post]
+func (h *JobHandler) Cancel(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	job, err := h.jobService.Cancel(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, job)
+}
+
+// Retry godoc
+// @Summary      Retry a job
+// @Description  Runs a failed or cancelled job again
+// @Tags         jobs
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Job ID"
+// @Success      200 {object} Response{data=entity.Job}
+// @Failure      404 {object} Response
+// @Failure      409 {object} Response
+// @Router       /jobs/{id}/retry [post]
+func (h *JobHandler) Retry(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	job, err := h.jobService.Retry(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, job)
+}
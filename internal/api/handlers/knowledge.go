@@ -45,6 +45,9 @@ type AddItemRequest struct {
 	Keywords []string          `json:"keywords"`
 	Source   string            `json:"source"`
 	Metadata map[string]string `json:"metadata"`
+
+	// Shared item of a global knowledge base the new item replaces for the tenant
+	OverridesItemID string `json:"overrides_item_id"`
 }
 
 // UpdateItemRequest represents an update item request
@@ -100,6 +103,30 @@ func (h *KnowledgeHandler) ListKnowledgeBases(c *gin.Context) {
 	RespondPaginated(c, kbs, total, params.Page, params.PageSize)
 }
 
+// ListSharedKnowledgeBases godoc
+// @Summary      List shared knowledge bases
+// @Description  Returns the global knowledge bases the platform attached to the current tenant. Bots may answer from them, and the tenant may add override items to them, but not change their shared items.
+// @Tags         knowledge
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=[]entity.KnowledgeBase}
+// @Failure      401 {object} Response
+// @Router       /knowledge-bases/shared [get]
+func (h *KnowledgeHandler) ListSharedKnowledgeBases(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	kbs, err := h.knowledgeService.ListAttachedKnowledgeBases(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, kbs)
+}
+
 // CreateKnowledgeBase godoc
 // @Summary      Create knowledge base
 // @Description  Create a new knowledge base for storing FAQs, documents, or website content
@@ -154,15 +181,8 @@ func (h *KnowledgeHandler) CreateKnowledgeBase(c *gin.Context) {
 // @Failure      404 {object} Response
 // @Router       /knowledge-bases/{id} [get]
 func (h *KnowledgeHandler) GetKnowledgeBase(c *gin.Context) {
-	id := c.Param("id")
-	if id == "" {
-		RespondValidationError(c, "Knowledge base ID is required", nil)
-		return
-	}
-
-	kb, err := h.knowledgeService.GetKnowledgeBase(c.Request.Context(), id)
-	if err != nil {
-		RespondError(c, err)
+	kb, ok := h.tenantKnowledgeBase(c, false)
+	if !ok {
 		return
 	}
 
@@ -181,12 +201,12 @@ func (h *KnowledgeHandler) GetKnowledgeBase(c *gin.Context) {
 // @Success      200 {object} Response{data=entity.KnowledgeBase}
 // @Failure      400 {object} Response
 // @Failure      401 {object} Response
+// @Failure      403 {object} Response
 // @Failure      404 {object} Response
 // @Router       /knowledge-bases/{id} [put]
 func (h *KnowledgeHandler) UpdateKnowledgeBase(c *gin.Context) {
-	id := c.Param("id")
-	if id == "" {
-		RespondValidationError(c, "Knowledge base ID is required", nil)
+	existing, ok := h.tenantKnowledgeBase(c, true)
+	if !ok {
 		return
 	}
 
@@ -202,7 +222,7 @@ func (h *KnowledgeHandler) UpdateKnowledgeBase(c *gin.Context) {
 		Config:      req.Config,
 	}
 
-	kb, err := h.knowledgeService.UpdateKnowledgeBase(c.Request.Context(), id, input)
+	kb, err := h.knowledgeService.UpdateKnowledgeBase(c.Request.Context(), existing.ID, input)
 	if err != nil {
 		RespondError(c, err)
 		return
@@ -221,16 +241,16 @@ func (h *KnowledgeHandler) UpdateKnowledgeBase(c *gin.Context) {
 // @Param        id path string true "Knowledge base ID"
 // @Success      204 "No Content"
 // @Failure      401 {object} Response
+// @Failure      403 {object} Response
 // @Failure      404 {object} Response
 // @Router       /knowledge-bases/{id} [delete]
 func (h *KnowledgeHandler) DeleteKnowledgeBase(c *gin.Context) {
-	id := c.Param("id")
-	if id == "" {
-		RespondValidationError(c, "Knowledge base ID is required", nil)
+	kb, ok := h.tenantKnowledgeBase(c, true)
+	if !ok {
 		return
 	}
 
-	if err := h.knowledgeService.DeleteKnowledgeBase(c.Request.Context(), id); err != nil {
+	if err := h.knowledgeService.DeleteKnowledgeBase(c.Request.Context(), kb.ID); err != nil {
 		RespondError(c, err)
 		return
 	}
@@ -240,7 +260,7 @@ func (h *KnowledgeHandler) DeleteKnowledgeBase(c *gin.Context) {
 
 // ListItems godoc
 // @Summary      List knowledge base items
-// @Description  Returns all items in a knowledge base with pagination
+// @Description  Returns all items in a knowledge base with pagination. In a global knowledge base these are the shared items and the tenant's override items.
 // @Tags         knowledge
 // @Accept       json
 // @Produce      json
@@ -253,6 +273,10 @@ func (h *KnowledgeHandler) DeleteKnowledgeBase(c *gin.Context) {
 // @Failure      404 {object} Response
 // @Router       /knowledge-bases/{id}/items [get]
 func (h *KnowledgeHandler) ListItems(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
 	kbID := c.Param("id")
 	if kbID == "" {
 		RespondValidationError(c, "Knowledge base ID is required", nil)
@@ -270,7 +294,7 @@ func (h *KnowledgeHandler) ListItems(c *gin.Context) {
 		SortDir:  c.DefaultQuery("sort_dir", "desc"),
 	}
 
-	items, total, err := h.knowledgeService.ListItems(c.Request.Context(), kbID, params)
+	items, total, err := h.knowledgeService.ListItemsForTenant(c.Request.Context(), tenantID, kbID, params)
 	if err != nil {
 		RespondError(c, err)
 		return
@@ -281,7 +305,7 @@ func (h *KnowledgeHandler) ListItems(c *gin.Context) {
 
 // AddItem godoc
 // @Summary      Add item to knowledge base
-// @Description  Add a new FAQ or content item to a knowledge base
+// @Description  Add a new FAQ or content item to a knowledge base. The item is published right away unless the knowledge base requires review, in which case it starts as a draft. Items added to a global knowledge base are the tenant's overrides: only the tenant's bots see them, and one with overrides_item_id replaces that shared item in their searches.
 // @Tags         knowledge
 // @Accept       json
 // @Produce      json
@@ -294,28 +318,18 @@ func (h *KnowledgeHandler) ListItems(c *gin.Context) {
 // @Failure      404 {object} Response
 // @Router       /knowledge-bases/{id}/items [post]
 func (h *KnowledgeHandler) AddItem(c *gin.Context) {
-	kbID := c.Param("id")
-	if kbID == "" {
-		RespondValidationError(c, "Knowledge base ID is required", nil)
-		return
-	}
-
 	var req AddItemRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		RespondValidationError(c, "Invalid request body", nil)
 		return
 	}
 
-	input := &service.AddItemInput{
-		KnowledgeBaseID: kbID,
-		Question:        req.Question,
-		Answer:          req.Answer,
-		Keywords:        req.Keywords,
-		Source:          req.Source,
-		Metadata:        req.Metadata,
+	kb, ok := h.tenantKnowledgeBase(c, false)
+	if !ok {
+		return
 	}
 
-	item, err := h.knowledgeService.AddItem(c.Request.Context(), input)
+	item, err := h.knowledgeService.AddItem(c.Request.Context(), addItemInput(c, kb, &req))
 	if err != nil {
 		RespondError(c, err)
 		return
@@ -326,7 +340,7 @@ func (h *KnowledgeHandler) AddItem(c *gin.Context) {
 
 // GetItem godoc
 // @Summary      Get knowledge base item
-// @Description  Returns a knowledge base item by ID
+// @Description  Returns a knowledge base item by ID with the published content, and its pending changes under draft
 // @Tags         knowledge
 // @Accept       json
 // @Produce      json
@@ -338,13 +352,12 @@ func (h *KnowledgeHandler) AddItem(c *gin.Context) {
 // @Failure      404 {object} Response
 // @Router       /knowledge-bases/{id}/items/{itemId} [get]
 func (h *KnowledgeHandler) GetItem(c *gin.Context) {
-	itemID := c.Param("itemId")
-	if itemID == "" {
-		RespondValidationError(c, "Item ID is required", nil)
+	existing, ok := h.tenantItem(c, false)
+	if !ok {
 		return
 	}
 
-	item, err := h.knowledgeService.GetItem(c.Request.Context(), itemID)
+	item, err := h.knowledgeService.GetItemWithDraft(c.Request.Context(), existing.ID)
 	if err != nil {
 		RespondError(c, err)
 		return
@@ -355,7 +368,7 @@ func (h *KnowledgeHandler) GetItem(c *gin.Context) {
 
 // UpdateItem godoc
 // @Summary      Update knowledge base item
-// @Description  Update a knowledge base item's content
+// @Description  Update a knowledge base item's content as a new version. In knowledge bases requiring review the version waits as a draft and bots keep answering from the published version.
 // @Tags         knowledge
 // @Accept       json
 // @Produce      json
@@ -366,12 +379,12 @@ func (h *KnowledgeHandler) GetItem(c *gin.Context) {
 // @Success      200 {object} Response{data=entity.KnowledgeItem}
 // @Failure      400 {object} Response
 // @Failure      401 {object} Response
+// @Failure      403 {object} Response
 // @Failure      404 {object} Response
 // @Router       /knowledge-bases/{id}/items/{itemId} [put]
 func (h *KnowledgeHandler) UpdateItem(c *gin.Context) {
-	itemID := c.Param("itemId")
-	if itemID == "" {
-		RespondValidationError(c, "Item ID is required", nil)
+	existing, ok := h.tenantItem(c, true)
+	if !ok {
 		return
 	}
 
@@ -382,14 +395,15 @@ func (h *KnowledgeHandler) UpdateItem(c *gin.Context) {
 	}
 
 	input := &service.UpdateItemInput{
-		Question: req.Question,
-		Answer:   req.Answer,
-		Keywords: req.Keywords,
-		Source:   req.Source,
-		Metadata: req.Metadata,
+		Question:  req.Question,
+		Answer:    req.Answer,
+		Keywords:  req.Keywords,
+		Source:    req.Source,
+		Metadata:  req.Metadata,
+		UpdatedBy: middleware.GetUserID(c),
 	}
 
-	item, err := h.knowledgeService.UpdateItem(c.Request.Context(), itemID, input)
+	item, err := h.knowledgeService.UpdateItem(c.Request.Context(), existing.ID, input)
 	if err != nil {
 		RespondError(c, err)
 		return
@@ -409,16 +423,16 @@ func (h *KnowledgeHandler) UpdateItem(c *gin.Context) {
 // @Param        itemId path string true "Item ID"
 // @Success      204 "No Content"
 // @Failure      401 {object} Response
+// @Failure      403 {object} Response
 // @Failure      404 {object} Response
 // @Router       /knowledge-bases/{id}/items/{itemId} [delete]
 func (h *KnowledgeHandler) DeleteItem(c *gin.Context) {
-	itemID := c.Param("itemId")
-	if itemID == "" {
-		RespondValidationError(c, "Item ID is required", nil)
+	item, ok := h.tenantItem(c, true)
+	if !ok {
 		return
 	}
 
-	if err := h.knowledgeService.DeleteItem(c.Request.Context(), itemID); err != nil {
+	if err := h.knowledgeService.DeleteItem(c.Request.Context(), item.ID, middleware.GetUserID(c)); err != nil {
 		RespondError(c, err)
 		return
 	}
@@ -428,7 +442,7 @@ func (h *KnowledgeHandler) DeleteItem(c *gin.Context) {
 
 // Search godoc
 // @Summary      Search knowledge base
-// @Description  Perform semantic search on a knowledge base using embeddings
+// @Description  Perform semantic search on a knowledge base using embeddings. In a global knowledge base the tenant's override items take precedence over the shared items they override.
 // @Tags         knowledge
 // @Accept       json
 // @Produce      json
@@ -441,6 +455,10 @@ func (h *KnowledgeHandler) DeleteItem(c *gin.Context) {
 // @Failure      404 {object} Response
 // @Router       /knowledge-bases/{id}/search [post]
 func (h *KnowledgeHandler) Search(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
 	kbID := c.Param("id")
 	if kbID == "" {
 		RespondValidationError(c, "Knowledge base ID is required", nil)
@@ -461,7 +479,7 @@ func (h *KnowledgeHandler) Search(c *gin.Context) {
 		limit = 20
 	}
 
-	results, err := h.knowledgeService.Search(c.Request.Context(), kbID, req.Query, limit)
+	results, err := h.knowledgeService.Search(c.Request.Context(), tenantID, kbID, req.Query, limit)
 	if err != nil {
 		RespondError(c, err)
 		return
@@ -476,29 +494,35 @@ func (h *KnowledgeHandler) Search(c *gin.Context) {
 
 // RegenerateEmbeddings godoc
 // @Summary      Regenerate embeddings
-// @Description  Regenerate vector embeddings for all items in a knowledge base
+// @Description  Regenerate vector embeddings for all items in a knowledge base. Runs as a background job whose progress is reported at /jobs/{id}; texts embedded before are served from the embedding cache.
 // @Tags         knowledge
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id path string true "Knowledge base ID"
 // @Success      200 {object} Response{data=object{message=string}}
+// @Success      202 {object} Response{data=entity.Job}
 // @Failure      401 {object} Response
+// @Failure      403 {object} Response
 // @Failure      404 {object} Response
 // @Router       /knowledge-bases/{id}/regenerate-embeddings [post]
 func (h *KnowledgeHandler) RegenerateEmbeddings(c *gin.Context) {
-	kbID := c.Param("id")
-	if kbID == "" {
-		RespondValidationError(c, "Knowledge base ID is required", nil)
+	kb, ok := h.tenantKnowledgeBase(c, true)
+	if !ok {
 		return
 	}
 
-	if err := h.knowledgeService.RegenerateEmbeddings(c.Request.Context(), kbID); err != nil {
+	job, err := h.knowledgeService.RegenerateEmbeddings(c.Request.Context(), kb.ID, middleware.GetUserID(c))
+	if err != nil {
 		RespondError(c, err)
 		return
 	}
 
-	RespondSuccess(c, gin.H{"message": "Embeddings regeneration started"})
+	if job != nil {
+		RespondAccepted(c, job)
+		return
+	}
+	RespondSuccess(c, gin.H{"message": "Embeddings regenerated"})
 }
 
 // BulkAddItems godoc
@@ -516,12 +540,6 @@ func (h *KnowledgeHandler) RegenerateEmbeddings(c *gin.Context) {
 // @Failure      404 {object} Response
 // @Router       /knowledge-bases/{id}/items/bulk [post]
 func (h *KnowledgeHandler) BulkAddItems(c *gin.Context) {
-	kbID := c.Param("id")
-	if kbID == "" {
-		RespondValidationError(c, "Knowledge base ID is required", nil)
-		return
-	}
-
 	var req struct {
 		Items []AddItemRequest `json:"items" binding:"required"`
 	}
@@ -535,20 +553,16 @@ func (h *KnowledgeHandler) BulkAddItems(c *gin.Context) {
 		return
 	}
 
+	kb, ok := h.tenantKnowledgeBase(c, false)
+	if !ok {
+		return
+	}
+
 	results := make([]*entity.KnowledgeItem, 0, len(req.Items))
 	errors := make([]string, 0)
 
-	for i, itemReq := range req.Items {
-		input := &service.AddItemInput{
-			KnowledgeBaseID: kbID,
-			Question:        itemReq.Question,
-			Answer:          itemReq.Answer,
-			Keywords:        itemReq.Keywords,
-			Source:          itemReq.Source,
-			Metadata:        itemReq.Metadata,
-		}
-
-		item, err := h.knowledgeService.AddItem(c.Request.Context(), input)
+	for i := range req.Items {
+		item, err := h.knowledgeService.AddItem(c.Request.Context(), addItemInput(c, kb, &req.Items[i]))
 		if err != nil {
 			errors = append(errors, "Item "+strconv.Itoa(i)+": "+err.Error())
 		} else {
@@ -562,3 +576,70 @@ func (h *KnowledgeHandler) BulkAddItems(c *gin.Context) {
 		"errors":  errors,
 	})
 }
+
+// tenantKnowledgeBase loads the knowledge base of the request if the tenant may read
+// it, or change it when edit is set, responding with the error otherwise
+func (h *KnowledgeHandler) tenantKnowledgeBase(c *gin.Context, edit bool) (*entity.KnowledgeBase, bool) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return nil, false
+	}
+	id := c.Param("id")
+	if id == "" {
+		RespondValidationError(c, "Knowledge base ID is required", nil)
+		return nil, false
+	}
+
+	var kb *entity.KnowledgeBase
+	var err error
+	if edit {
+		kb, err = h.knowledgeService.EditableKnowledgeBase(c.Request.Context(), tenantID, id)
+	} else {
+		kb, err = h.knowledgeService.KnowledgeBaseForTenant(c.Request.Context(), tenantID, id)
+	}
+	if err != nil {
+		RespondError(c, err)
+		return nil, false
+	}
+	return kb, true
+}
+
+// tenantItem loads the knowledge item of the request if the tenant may read it, or
+// change it when edit is set, responding with the error otherwise
+func (h *KnowledgeHandler) tenantItem(c *gin.Context, edit bool) (*entity.KnowledgeItem, bool) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return nil, false
+	}
+	itemID := c.Param("itemId")
+	if itemID == "" {
+		RespondValidationError(c, "Item ID is required", nil)
+		return nil, false
+	}
+
+	item, err := h.knowledgeService.ItemForTenant(c.Request.Context(), tenantID, c.Param("id"), itemID, edit)
+	if err != nil {
+		RespondError(c, err)
+		return nil, false
+	}
+	return item, true
+}
+
+// addItemInput builds the input adding an item to a knowledge base; items tenants
+// add to a global knowledge base are their overrides
+func addItemInput(c *gin.Context, kb *entity.KnowledgeBase, req *AddItemRequest) *service.AddItemInput {
+	input := &service.AddItemInput{
+		KnowledgeBaseID: kb.ID,
+		Question:        req.Question,
+		Answer:          req.Answer,
+		Keywords:        req.Keywords,
+		Source:          req.Source,
+		Metadata:        req.Metadata,
+		CreatedBy:       middleware.GetUserID(c),
+		OverridesItemID: req.OverridesItemID,
+	}
+	if kb.Global {
+		input.TenantID = middleware.GetTenantID(c)
+	}
+	return input
+}
@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+)
+
+// KnowledgeCurationHandler handles mining resolved conversations for Q&A pairs and
+// their review before they are published to knowledge bases
+type KnowledgeCurationHandler struct {
+	curationService *service.KnowledgeCurationService
+}
+
+// NewKnowledgeCurationHandler creates a new knowledge curation handler
+func NewKnowledgeCurationHandler(curationService *service.KnowledgeCurationService) *KnowledgeCurationHandler {
+	return &KnowledgeCurationHandler{curationService: curationService}
+}
+
+// Mine godoc
+// @Summary      Mine conversations for knowledge
+// @Description  Asks the AI for reusable Q&A pairs in resolved conversations not mined before and queues them for review
+// @Tags         knowledge
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.MineKnowledgeInput false "Conversations to mine"
+// @Success      200 {object} Response{data=entity.KnowledgeMiningResult}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /knowledge-candidates/mine [post]
+func (h *KnowledgeCurationHandler) Mine(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.MineKnowledgeInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			RespondValidationError(c, "Invalid request body", nil)
+			return
+		}
+	}
+
+	result, err := h.curationService.Mine(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, result)
+}
+
+// ListCandidates godoc
+// @Summary      List knowledge candidates
+// @Description  Lists the Q&A pairs mined from conversations, newest first
+// @Tags         knowledge
+// @Produce      json
+// @Security     BearerAuth
+// @Param        conversation_id query string false "Conversation ID"
+// @Param        status query string false "pending, published or rejected"
+// @Param        page query int false "Page number"
+// @Param        page_size query int false "Page size"
+// @Success      200 {object} Response{data=[]entity.KnowledgeCandidate}
+// @Router       /knowledge-candidates [get]
+func (h *KnowledgeCurationHandler) ListCandidates(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	params := &repository.ListParams{
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	filter := &entity.KnowledgeCandidateFilter{
+		ConversationID: c.Query("conversation_id"),
+		Status:         entity.KnowledgeCandidateStatus(c.Query("status")),
+	}
+
+	candidates, total, err := h.curationService.ListCandidates(c.Request.Context(), tenantID, filter, params)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondPaginated(c, candidates, total, params.Page, params.PageSize)
+}
+
+// GetCandidate godoc
+// @Summary      Get knowledge candidate
+// @Tags         knowledge
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Candidate ID"
+// @Success      200 {object} Response{data=entity.KnowledgeCandidate}
+// @Failure      404 {object} Response
+// @Router       /knowledge-candidates/{id} [get]
+func (h *KnowledgeCurationHandler) GetCandidate(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	candidate, err := h.curationService.GetCandidate(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, candidate)
+}
+
+// UpdateCandidate godoc
+// @Summary      Edit knowledge candidate
+// @Description  Edits the question or answer of a mined Q&A pair before it is published
+// @Tags         knowledge
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Candidate ID"
+// @Param        request body service.KnowledgeCandidateInput true "Edits"
+// @Success      200 {object} Response{data=entity.KnowledgeCandidate}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /knowledge-candidates/{id} [put]
+func (h *KnowledgeCurationHandler) UpdateCandidate(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.KnowledgeCandidateInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	candidate, err := h.curationService.UpdateCandidate(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, candidate)
+}
+
+// PublishCandidate godoc
+// @Summary      Publish knowledge candidate
+// @Description  Adds a mined Q&A pair to a knowledge base, linked to its source conversation, optionally applying final edits
+// @Tags         knowledge
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Candidate ID"
+// @Param        request body service.PublishKnowledgeCandidateInput true "Knowledge base and final edits"
+// @Success      200 {object} Response{data=entity.KnowledgeCandidate}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /knowledge-candidates/{id}/publish [post]
+func (h *KnowledgeCurationHandler) PublishCandidate(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.PublishKnowledgeCandidateInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	candidate, err := h.curationService.Publish(c.Request.Context(), tenantID, c.Param("id"), middleware.GetUserID(c), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, candidate)
+}
+
+// RejectCandidate godoc
+// @Summary      Reject knowledge candidate
+// @Tags         knowledge
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Candidate ID"
+// @Success      200 {object} Response{data=entity.KnowledgeCandidate}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /knowledge-candidates/{id}/reject [post]
+func (h *KnowledgeCurationHandler) RejectCandidate(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	candidate, err := h.curationService.Reject(c.Request.Context(), tenantID, c.Param("id"), middleware.GetUserID(c))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, candidate)
+}
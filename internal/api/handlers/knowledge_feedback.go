@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+)
+
+// KnowledgeFeedbackHandler handles the knowledge base quality report and the
+// knowledge gap queue fed by customer feedback
+type KnowledgeFeedbackHandler struct {
+	feedbackService *service.KnowledgeFeedbackService
+}
+
+// NewKnowledgeFeedbackHandler creates a new knowledge feedback handler
+func NewKnowledgeFeedbackHandler(feedbackService *service.KnowledgeFeedbackService) *KnowledgeFeedbackHandler {
+	return &KnowledgeFeedbackHandler{feedbackService: feedbackService}
+}
+
+// QualityReport godoc
+// @Summary      Knowledge base quality report
+// @Description  Rates knowledge items by the "did this help?" feedback of the answers that used them, low-performing items first
+// @Tags         knowledge
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Knowledge base ID"
+// @Param        min_responses query int false "Ratings an item needs before it can be flagged (default 5)"
+// @Success      200 {object} Response{data=entity.KnowledgeQualityReport}
+// @Router       /knowledge-bases/{id}/quality [get]
+func (h *KnowledgeFeedbackHandler) QualityReport(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	minResponses, _ := strconv.Atoi(c.Query("min_responses"))
+
+	report, err := h.feedbackService.QualityReport(c.Request.Context(), tenantID, c.Param("id"), minResponses)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, report)
+}
+
+// ListGaps godoc
+// @Summary      List knowledge gaps
+// @Description  Lists customer questions the knowledge base failed to answer, most frequent first
+// @Tags         knowledge
+// @Produce      json
+// @Security     BearerAuth
+// @Param        knowledge_base_id query string false "Knowledge base ID"
+// @Param        status query string false "open, resolved or dismissed"
+// @Param        page query int false "Page number"
+// @Param        page_size query int false "Page size"
+// @Success      200 {object} Response{data=[]entity.KnowledgeGap}
+// @Router       /knowledge-gaps [get]
+func (h *KnowledgeFeedbackHandler) ListGaps(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	params := &repository.ListParams{
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	filter := &entity.KnowledgeGapFilter{
+		KnowledgeBaseID: c.Query("knowledge_base_id"),
+		Status:          entity.KnowledgeGapStatus(c.Query("status")),
+	}
+
+	gaps, total, err := h.feedbackService.ListGaps(c.Request.Context(), tenantID, filter, params)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondPaginated(c, gaps, total, params.Page, params.PageSize)
+}
+
+// ResolveGap godoc
+// @Summary      Resolve knowledge gap
+// @Description  Marks a gap as fixed by new or edited knowledge base content
+// @Tags         knowledge
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Knowledge gap ID"
+// @Success      200 {object} Response{data=entity.KnowledgeGap}
+// @Failure      404 {object} Response
+// @Failure      409 {object} Response
+// @Router       /knowledge-gaps/{id}/resolve [post]
+func (h *KnowledgeFeedbackHandler) ResolveGap(c *gin.Context) {
+	h.updateGapStatus(c, entity.KnowledgeGapResolved)
+}
+
+// DismissGap godoc
+// @Summary      Dismiss knowledge gap
+// @Description  Removes a gap from the queue without changing the knowledge base
+// @Tags         knowledge
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Knowledge gap ID"
+// @Success      200 {object} Response{data=entity.KnowledgeGap}
+// @Failure      404 {object} Response
+// @Failure      409 {object} Response
+// @Router       /knowledge-gaps/{id}/dismiss [post]
+func (h *KnowledgeFeedbackHandler) DismissGap(c *gin.Context) {
+	h.updateGapStatus(c, entity.KnowledgeGapDismissed)
+}
+
+func (h *KnowledgeFeedbackHandler) updateGapStatus(c *gin.Context, status entity.KnowledgeGapStatus) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	gap, err := h.feedbackService.UpdateGapStatus(c.Request.Context(), tenantID, c.Param("id"), middleware.GetUserID(c), status)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, gap)
+}
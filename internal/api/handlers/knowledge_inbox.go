@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+)
+
+// KnowledgeInboxHandler handles the email addresses that feed knowledge bases and
+// the review of the content they receive
+type KnowledgeInboxHandler struct {
+	inboxService *service.KnowledgeInboxService
+}
+
+// NewKnowledgeInboxHandler creates a new knowledge inbox handler
+func NewKnowledgeInboxHandler(inboxService *service.KnowledgeInboxService) *KnowledgeInboxHandler {
+	return &KnowledgeInboxHandler{inboxService: inboxService}
+}
+
+// ListInboxes godoc
+// @Summary      List knowledge inboxes
+// @Tags         knowledge
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=[]entity.KnowledgeInbox}
+// @Router       /knowledge-inboxes [get]
+func (h *KnowledgeInboxHandler) ListInboxes(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	inboxes, err := h.inboxService.ListInboxes(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, inboxes)
+}
+
+// CreateInbox godoc
+// @Summary      Create knowledge inbox
+// @Description  Designates an email address whose inbound bodies and attachments are ingested into a knowledge base after review
+// @Tags         knowledge
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.KnowledgeInboxInput true "Knowledge inbox"
+// @Success      201 {object} Response{data=entity.KnowledgeInbox}
+// @Failure      400 {object} Response
+// @Failure      409 {object} Response
+// @Router       /knowledge-inboxes [post]
+func (h *KnowledgeInboxHandler) CreateInbox(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.KnowledgeInboxInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	inbox, err := h.inboxService.CreateInbox(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, inbox)
+}
+
+// GetInbox godoc
+// @Summary      Get knowledge inbox
+// @Tags         knowledge
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Knowledge inbox ID"
+// @Success      200 {object} Response{data=entity.KnowledgeInbox}
+// @Failure      404 {object} Response
+// @Router       /knowledge-inboxes/{id} [get]
+func (h *KnowledgeInboxHandler) GetInbox(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	inbox, err := h.inboxService.GetInbox(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, inbox)
+}
+
+// UpdateInbox godoc
+// @Summary      Update knowledge inbox
+// @Tags         knowledge
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Knowledge inbox ID"
+// @Param        request body service.KnowledgeInboxInput true "Knowledge inbox"
+// @Success      200 {object} Response{data=entity.KnowledgeInbox}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /knowledge-inboxes/{id} [put]
+func (h *KnowledgeInboxHandler) UpdateInbox(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.KnowledgeInboxInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	inbox, err := h.inboxService.UpdateInbox(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, inbox)
+}
+
+// DeleteInbox godoc
+// @Summary      Delete knowledge inbox
+// @Tags         knowledge
+// @Security     BearerAuth
+// @Param        id path string true "Knowledge inbox ID"
+// @Success      204
+// @Failure      404 {object} Response
+// @Router       /knowledge-inboxes/{id} [delete]
+func (h *KnowledgeInboxHandler) DeleteInbox(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.inboxService.DeleteInbox(c.Request.Context(), tenantID, c.Param("id")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
+
+// ListSubmissions godoc
+// @Summary      List knowledge submissions
+// @Description  Lists the email bodies and attachments received by knowledge inboxes, newest first
+// @Tags         knowledge
+// @Produce      json
+// @Security     BearerAuth
+// @Param        inbox_id query string false "Knowledge inbox ID"
+// @Param        status query string false "pending, approved, rejected or failed"
+// @Param        page query int false "Page number"
+// @Param        page_size query int false "Page size"
+// @Success      200 {object} Response{data=[]entity.KnowledgeSubmission}
+// @Router       /knowledge-submissions [get]
+func (h *KnowledgeInboxHandler) ListSubmissions(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	params := &repository.ListParams{
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	filter := &entity.KnowledgeSubmissionFilter{
+		InboxID: c.Query("inbox_id"),
+		Status:  entity.KnowledgeSubmissionStatus(c.Query("status")),
+	}
+
+	submissions, total, err := h.inboxService.ListSubmissions(c.Request.Context(), tenantID, filter, params)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondPaginated(c, submissions, total, params.Page, params.PageSize)
+}
+
+// GetSubmission godoc
+// @Summary      Get knowledge submission
+// @Tags         knowledge
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Submission ID"
+// @Success      200 {object} Response{data=entity.KnowledgeSubmission}
+// @Failure      404 {object} Response
+// @Router       /knowledge-submissions/{id} [get]
+func (h *KnowledgeInboxHandler) GetSubmission(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	submission, err := h.inboxService.GetSubmission(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, submission)
+}
+
+// UpdateSubmission godoc
+// @Summary      Edit knowledge submission
+// @Description  Edits the title or content of a submission before it is published
+// @Tags         knowledge
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Submission ID"
+// @Param        request body service.KnowledgeSubmissionInput true "Edits"
+// @Success      200 {object} Response{data=entity.KnowledgeSubmission}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /knowledge-submissions/{id} [put]
+func (h *KnowledgeInboxHandler) UpdateSubmission(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.KnowledgeSubmissionInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	submission, err := h.inboxService.UpdateSubmission(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, submission)
+}
+
+// ApproveSubmission godoc
+// @Summary      Approve knowledge submission
+// @Description  Publishes a submission to its knowledge base, optionally applying final edits
+// @Tags         knowledge
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Submission ID"
+// @Param        request body service.KnowledgeSubmissionInput false "Final edits"
+// @Success      200 {object} Response{data=entity.KnowledgeSubmission}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /knowledge-submissions/{id}/approve [post]
+func (h *KnowledgeInboxHandler) ApproveSubmission(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.KnowledgeSubmissionInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			RespondValidationError(c, "Invalid request body", nil)
+			return
+		}
+	}
+
+	submission, err := h.inboxService.Approve(c.Request.Context(), tenantID, c.Param("id"), middleware.GetUserID(c), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, submission)
+}
+
+// RejectSubmission godoc
+// @Summary      Reject knowledge submission
+// @Tags         knowledge
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Submission ID"
+// @Success      200 {object} Response{data=entity.KnowledgeSubmission}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /knowledge-submissions/{id}/reject [post]
+func (h *KnowledgeInboxHandler) RejectSubmission(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	submission, err := h.inboxService.Reject(c.Request.Context(), tenantID, c.Param("id"), middleware.GetUserID(c))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, submission)
+}
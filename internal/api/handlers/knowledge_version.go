@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+)
+
+// RollbackItemRequest represents a rollback of a knowledge item to an earlier version
+type RollbackItemRequest struct {
+	Version int `json:"version" binding:"required,min=1"`
+}
+
+// ListItemVersions godoc
+// @Summary      List item versions
+// @Description  Returns the version history of a knowledge base item, newest first, with who created and published each version
+// @Tags         knowledge
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Knowledge base ID"
+// @Param        itemId path string true "Item ID"
+// @Success      200 {object} Response{data=[]entity.KnowledgeItemVersion}
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Router       /knowledge-bases/{id}/items/{itemId}/versions [get]
+func (h *KnowledgeHandler) ListItemVersions(c *gin.Context) {
+	item, ok := h.tenantItem(c, false)
+	if !ok {
+		return
+	}
+
+	versions, err := h.knowledgeService.ListVersions(c.Request.Context(), item.ID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, versions)
+}
+
+// DiffItemVersions godoc
+// @Summary      Diff item versions
+// @Description  Compares two versions of a knowledge base item field by field, with line diffs of the question and answer. Without versions the published version is compared with the latest one.
+// @Tags         knowledge
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Knowledge base ID"
+// @Param        itemId path string true "Item ID"
+// @Param        from query int false "Version to compare from (default published version)"
+// @Param        to query int false "Version to compare to (default latest version)"
+// @Success      200 {object} Response{data=entity.KnowledgeItemDiff}
+// @Failure      400 {object} Response
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Router       /knowledge-bases/{id}/items/{itemId}/diff [get]
+func (h *KnowledgeHandler) DiffItemVersions(c *gin.Context) {
+	item, ok := h.tenantItem(c, false)
+	if !ok {
+		return
+	}
+
+	from, err := strconv.Atoi(c.DefaultQuery("from", "0"))
+	if err != nil {
+		RespondValidationError(c, "from must be a version number", nil)
+		return
+	}
+	to, err := strconv.Atoi(c.DefaultQuery("to", "0"))
+	if err != nil {
+		RespondValidationError(c, "to must be a version number", nil)
+		return
+	}
+
+	diff, err := h.knowledgeService.DiffVersions(c.Request.Context(), item.ID, from, to)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, diff)
+}
+
+// PublishItem godoc
+// @Summary      Publish item
+// @Description  Publishes the latest version of a knowledge base item, making it the content bots retrieve
+// @Tags         knowledge
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Knowledge base ID"
+// @Param        itemId path string true "Item ID"
+// @Success      200 {object} Response{data=entity.KnowledgeItem}
+// @Failure      401 {object} Response
+// @Failure      403 {object} Response
+// @Failure      404 {object} Response
+// @Failure      409 {object} Response
+// @Router       /knowledge-bases/{id}/items/{itemId}/publish [post]
+func (h *KnowledgeHandler) PublishItem(c *gin.Context) {
+	existing, ok := h.tenantItem(c, true)
+	if !ok {
+		return
+	}
+
+	item, err := h.knowledgeService.PublishItem(c.Request.Context(), existing.ID, middleware.GetUserID(c))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, item)
+}
+
+// RollbackItem godoc
+// @Summary      Roll back item
+// @Description  Restores the content of an earlier version of a knowledge base item as a new version and publishes it right away. Pending draft changes are superseded but stay in the history.
+// @Tags         knowledge
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Knowledge base ID"
+// @Param        itemId path string true "Item ID"
+// @Param        request body RollbackItemRequest true "Version to restore"
+// @Success      200 {object} Response{data=entity.KnowledgeItem}
+// @Failure      400 {object} Response
+// @Failure      401 {object} Response
+// @Failure      403 {object} Response
+// @Failure      404 {object} Response
+// @Failure      409 {object} Response
+// @Router       /knowledge-bases/{id}/items/{itemId}/rollback [post]
+func (h *KnowledgeHandler) RollbackItem(c *gin.Context) {
+	existing, ok := h.tenantItem(c, true)
+	if !ok {
+		return
+	}
+
+	var req RollbackItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	item, err := h.knowledgeService.RollbackItem(c.Request.Context(), existing.ID, req.Version, middleware.GetUserID(c))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, item)
+}
+
+// PublishKnowledgeBase godoc
+// @Summary      Publish knowledge base
+// @Description  Publishes the latest version of every item of a knowledge base with unpublished changes
+// @Tags         knowledge
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Knowledge base ID"
+// @Success      200 {object} Response{data=entity.KnowledgePublishResult}
+// @Failure      401 {object} Response
+// @Failure      403 {object} Response
+// @Failure      404 {object} Response
+// @Router       /knowledge-bases/{id}/publish [post]
+func (h *KnowledgeHandler) PublishKnowledgeBase(c *gin.Context) {
+	kb, ok := h.tenantKnowledgeBase(c, true)
+	if !ok {
+		return
+	}
+
+	result, err := h.knowledgeService.PublishKnowledgeBase(c.Request.Context(), kb.ID, middleware.GetUserID(c))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, result)
+}
+
+// ListAuditEvents godoc
+// @Summary      Knowledge audit trail
+// @Description  Returns who created, edited, published, rolled back or deleted items of a knowledge base, newest first
+// @Tags         knowledge
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Knowledge base ID"
+// @Param        item_id query string false "Filter by item"
+// @Param        user_id query string false "Filter by user"
+// @Param        page query int false "Page number" default(1)
+// @Param        page_size query int false "Page size" default(20)
+// @Success      200 {object} Response{data=[]entity.KnowledgeAuditEvent}
+// @Failure      401 {object} Response
+// @Failure      403 {object} Response
+// @Failure      404 {object} Response
+// @Router       /knowledge-bases/{id}/audit [get]
+func (h *KnowledgeHandler) ListAuditEvents(c *gin.Context) {
+	kb, ok := h.tenantKnowledgeBase(c, true)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	params := &repository.ListParams{
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	filter := &entity.KnowledgeAuditFilter{
+		ItemID: c.Query("item_id"),
+		UserID: c.Query("user_id"),
+	}
+
+	events, total, err := h.knowledgeService.ListAuditEvents(c.Request.Context(), kb.ID, filter, params)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondPaginated(c, events, total, params.Page, params.PageSize)
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// LabelHandler handles tenant labels and their attachment to conversations
+type LabelHandler struct {
+	labelService *service.LabelService
+}
+
+// NewLabelHandler creates a new label handler
+func NewLabelHandler(labelService *service.LabelService) *LabelHandler {
+	return &LabelHandler{labelService: labelService}
+}
+
+// AttachLabelsRequest represents labels to attach to a conversation
+type AttachLabelsRequest struct {
+	LabelIDs []string `json:"label_ids" binding:"required"`
+}
+
+// List godoc
+// @Summary      List labels
+// @Tags         labels
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=[]entity.Label}
+// @Router       /labels [get]
+func (h *LabelHandler) List(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	labels, err := h.labelService.List(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, labels)
+}
+
+// Create godoc
+// @Summary      Create label
+// @Description  Creates a label agents can attach to conversations. Names are unique per tenant regardless of case.
+// @Tags         labels
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.LabelInput true "Label"
+// @Success      201 {object} Response{data=entity.Label}
+// @Failure      400 {object} Response
+// @Failure      409 {object} Response
+// @Router       /labels [post]
+func (h *LabelHandler) Create(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.LabelInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	label, err := h.labelService.Create(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, label)
+}
+
+// Get godoc
+// @Summary      Get label
+// @Tags         labels
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Label ID"
+// @Success      200 {object} Response{data=entity.Label}
+// @Failure      404 {object} Response
+// @Router       /labels/{id} [get]
+func (h *LabelHandler) Get(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	label, err := h.labelService.Get(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, label)
+}
+
+// Update godoc
+// @Summary      Update label
+// @Description  Renames or recolors a label on every conversation carrying it
+// @Tags         labels
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Label ID"
+// @Param        request body service.LabelInput true "Label"
+// @Success      200 {object} Response{data=entity.Label}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Failure      409 {object} Response
+// @Router       /labels/{id} [put]
+func (h *LabelHandler) Update(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.LabelInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	label, err := h.labelService.Update(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, label)
+}
+
+// Delete godoc
+// @Summary      Delete label
+// @Description  Deletes a label and removes it from every conversation
+// @Tags         labels
+// @Security     BearerAuth
+// @Param        id path string true "Label ID"
+// @Success      204
+// @Failure      404 {object} Response
+// @Router       /labels/{id} [delete]
+func (h *LabelHandler) Delete(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.labelService.Delete(c.Request.Context(), tenantID, c.Param("id")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
+
+// ListConversationLabels godoc
+// @Summary      List conversation labels
+// @Tags         conversations
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Success      200 {object} Response{data=[]entity.Label}
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/labels [get]
+func (h *LabelHandler) ListConversationLabels(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	labels, err := h.labelService.ListForConversation(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, labels)
+}
+
+// AttachToConversation godoc
+// @Summary      Attach labels to conversation
+// @Description  Attaches labels to a conversation and returns all its labels. Labels already attached are kept.
+// @Tags         conversations
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Param        request body AttachLabelsRequest true "Labels"
+// @Success      200 {object} Response{data=[]entity.Label}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/labels [post]
+func (h *LabelHandler) AttachToConversation(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req AttachLabelsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	labels, err := h.labelService.Attach(c.Request.Context(), tenantID, middleware.GetUserID(c), c.Param("id"), req.LabelIDs)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, labels)
+}
+
+// DetachFromConversation godoc
+// @Summary      Detach label from conversation
+// @Description  Removes a label from a conversation and returns its remaining labels
+// @Tags         conversations
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Param        labelId path string true "Label ID"
+// @Success      200 {object} Response{data=[]entity.Label}
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/labels/{labelId} [delete]
+func (h *LabelHandler) DetachFromConversation(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	labels, err := h.labelService.Detach(c.Request.Context(), tenantID, c.Param("id"), c.Param("labelId"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, labels)
+}
@@ -0,0 +1,392 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+)
+
+// LinkedObjectHandler handles orders, tickets and bookings linked to conversations
+// and the connectors that sync them from external systems
+type LinkedObjectHandler struct {
+	linkedObjectService *service.LinkedObjectService
+}
+
+// NewLinkedObjectHandler creates a new linked object handler
+func NewLinkedObjectHandler(linkedObjectService *service.LinkedObjectService) *LinkedObjectHandler {
+	return &LinkedObjectHandler{linkedObjectService: linkedObjectService}
+}
+
+// List godoc
+// @Summary      List linked objects
+// @Tags         linked-objects
+// @Produce      json
+// @Security     BearerAuth
+// @Param        type query string false "order, ticket or booking"
+// @Param        conversation_id query string false "Conversation ID"
+// @Param        contact_id query string false "Contact ID"
+// @Param        connector_id query string false "Connector ID"
+// @Param        status query string false "Status"
+// @Param        page query int false "Page number"
+// @Param        page_size query int false "Page size"
+// @Success      200 {object} Response{data=[]entity.LinkedObject}
+// @Router       /linked-objects [get]
+func (h *LinkedObjectHandler) List(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	params := &repository.ListParams{
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	filter := &entity.LinkedObjectFilter{
+		Type:           entity.LinkedObjectType(c.Query("type")),
+		ConversationID: c.Query("conversation_id"),
+		ContactID:      c.Query("contact_id"),
+		ConnectorID:    c.Query("connector_id"),
+		Status:         c.Query("status"),
+	}
+
+	objects, total, err := h.linkedObjectService.List(c.Request.Context(), tenantID, filter, params)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondPaginated(c, objects, total, params.Page, params.PageSize)
+}
+
+// Create godoc
+// @Summary      Create linked object
+// @Description  Links an order, ticket or booking to a conversation or a contact by hand
+// @Tags         linked-objects
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.LinkedObjectInput true "Linked object"
+// @Success      201 {object} Response{data=entity.LinkedObject}
+// @Failure      400 {object} Response
+// @Router       /linked-objects [post]
+func (h *LinkedObjectHandler) Create(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.LinkedObjectInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+	req.CreatedBy = middleware.GetUserID(c)
+
+	object, err := h.linkedObjectService.Create(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, object)
+}
+
+// Get godoc
+// @Summary      Get linked object
+// @Tags         linked-objects
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Linked object ID"
+// @Success      200 {object} Response{data=entity.LinkedObject}
+// @Failure      404 {object} Response
+// @Router       /linked-objects/{id} [get]
+func (h *LinkedObjectHandler) Get(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	object, err := h.linkedObjectService.Get(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, object)
+}
+
+// Update godoc
+// @Summary      Update linked object
+// @Description  Updates a linked object; its type and external ID are kept
+// @Tags         linked-objects
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Linked object ID"
+// @Param        request body service.LinkedObjectInput true "Linked object"
+// @Success      200 {object} Response{data=entity.LinkedObject}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /linked-objects/{id} [put]
+func (h *LinkedObjectHandler) Update(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.LinkedObjectInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	object, err := h.linkedObjectService.Update(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, object)
+}
+
+// Delete godoc
+// @Summary      Delete linked object
+// @Tags         linked-objects
+// @Security     BearerAuth
+// @Param        id path string true "Linked object ID"
+// @Success      204
+// @Failure      404 {object} Response
+// @Router       /linked-objects/{id} [delete]
+func (h *LinkedObjectHandler) Delete(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.linkedObjectService.Delete(c.Request.Context(), tenantID, c.Param("id")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
+
+// ListConversationObjects godoc
+// @Summary      List objects linked to a conversation
+// @Description  Returns the orders, tickets and bookings shown alongside a conversation, most recently updated first
+// @Tags         linked-objects
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Success      200 {object} Response{data=[]entity.LinkedObject}
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/linked-objects [get]
+func (h *LinkedObjectHandler) ListConversationObjects(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	objects, err := h.linkedObjectService.ListForConversation(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, objects)
+}
+
+// ListConnectors godoc
+// @Summary      List linked object connectors
+// @Tags         linked-objects
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=[]entity.LinkedObjectConnector}
+// @Router       /linked-object-connectors [get]
+func (h *LinkedObjectHandler) ListConnectors(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	connectors, err := h.linkedObjectService.ListConnectors(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, connectors)
+}
+
+// CreateConnector godoc
+// @Summary      Create linked object connector
+// @Description  Creates a connector receiving webhooks at /webhooks/linked-objects/{id}; the signing secret is only returned here and on rotation
+// @Tags         linked-objects
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.LinkedObjectConnectorInput true "Connector"
+// @Success      201 {object} Response{data=service.LinkedObjectConnectorSecret}
+// @Failure      400 {object} Response
+// @Router       /linked-object-connectors [post]
+func (h *LinkedObjectHandler) CreateConnector(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.LinkedObjectConnectorInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	connector, err := h.linkedObjectService.CreateConnector(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, connector)
+}
+
+// GetConnector godoc
+// @Summary      Get linked object connector
+// @Tags         linked-objects
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Connector ID"
+// @Success      200 {object} Response{data=entity.LinkedObjectConnector}
+// @Failure      404 {object} Response
+// @Router       /linked-object-connectors/{id} [get]
+func (h *LinkedObjectHandler) GetConnector(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	connector, err := h.linkedObjectService.GetConnector(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, connector)
+}
+
+// UpdateConnector godoc
+// @Summary      Update linked object connector
+// @Tags         linked-objects
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Connector ID"
+// @Param        request body service.LinkedObjectConnectorInput true "Connector"
+// @Success      200 {object} Response{data=entity.LinkedObjectConnector}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /linked-object-connectors/{id} [put]
+func (h *LinkedObjectHandler) UpdateConnector(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.LinkedObjectConnectorInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	connector, err := h.linkedObjectService.UpdateConnector(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, connector)
+}
+
+// RotateConnectorSecret godoc
+// @Summary      Rotate connector secret
+// @Description  Replaces the webhook signing secret; webhooks signed with the old secret are rejected
+// @Tags         linked-objects
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Connector ID"
+// @Success      200 {object} Response{data=service.LinkedObjectConnectorSecret}
+// @Failure      404 {object} Response
+// @Router       /linked-object-connectors/{id}/rotate-secret [post]
+func (h *LinkedObjectHandler) RotateConnectorSecret(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	connector, err := h.linkedObjectService.RotateConnectorSecret(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, connector)
+}
+
+// DeleteConnector godoc
+// @Summary      Delete linked object connector
+// @Description  Deletes a connector; the objects it synced are kept
+// @Tags         linked-objects
+// @Security     BearerAuth
+// @Param        id path string true "Connector ID"
+// @Success      204
+// @Failure      404 {object} Response
+// @Router       /linked-object-connectors/{id} [delete]
+func (h *LinkedObjectHandler) DeleteConnector(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.linkedObjectService.DeleteConnector(c.Request.Context(), tenantID, c.Param("id")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
+
+// HandleWebhook godoc
+// @Summary      Linked object connector webhook
+// @Description  Creates or updates the object described by an external system's webhook, verified with the connector's signing secret
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        connectorId path string true "Connector ID"
+// @Success      200 {object} Response{data=entity.LinkedObject}
+// @Failure      400 {object} Response
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Router       /webhooks/linked-objects/{connectorId} [post]
+func (h *LinkedObjectHandler) HandleWebhook(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	object, err := h.linkedObjectService.HandleWebhook(c.Request.Context(), c.Param("connectorId"), body, c.Request.Header)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, object)
+}
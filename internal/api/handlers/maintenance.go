@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// MaintenanceHandler handles the system-wide maintenance mode
+type MaintenanceHandler struct {
+	maintenanceService *service.MaintenanceService
+}
+
+// NewMaintenanceHandler creates a new maintenance handler
+func NewMaintenanceHandler(maintenanceService *service.MaintenanceService) *MaintenanceHandler {
+	return &MaintenanceHandler{maintenanceService: maintenanceService}
+}
+
+// Status godoc
+// @Summary      Get maintenance status
+// @Description  Returns whether maintenance is on and how many provider webhooks are buffered
+// @Tags         system
+// @Produce      json
+// @Param        X-Admin-Token header string true "System administration token"
+// @Success      200 {object} Response{data=entity.MaintenanceState}
+// @Failure      401 {object} Response
+// @Router       /system/maintenance [get]
+func (h *MaintenanceHandler) Status(c *gin.Context) {
+	state, err := h.maintenanceService.Status(c.Request.Context())
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, state)
+}
+
+// Enable godoc
+// @Summary      Start maintenance
+// @Description  Buffers inbound provider webhooks, answering them with 200, and pauses message processing and outbound sends on all instances
+// @Tags         system
+// @Accept       json
+// @Produce      json
+// @Param        X-Admin-Token header string true "System administration token"
+// @Param        request body service.MaintenanceInput false "Reason"
+// @Success      200 {object} Response{data=entity.MaintenanceState}
+// @Failure      400 {object} Response
+// @Failure      401 {object} Response
+// @Router       /system/maintenance/enable [post]
+func (h *MaintenanceHandler) Enable(c *gin.Context) {
+	var req service.MaintenanceInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			RespondValidationError(c, "Invalid request body", nil)
+			return
+		}
+	}
+
+	state, err := h.maintenanceService.Enable(c.Request.Context(), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, state)
+}
+
+// Disable godoc
+// @Summary      End maintenance
+// @Description  Resumes processing and replays the buffered webhooks in the order they arrived; new webhooks queue behind them until the buffer is empty
+// @Tags         system
+// @Produce      json
+// @Param        X-Admin-Token header string true "System administration token"
+// @Success      200 {object} Response{data=entity.MaintenanceState}
+// @Failure      400 {object} Response
+// @Failure      401 {object} Response
+// @Router       /system/maintenance/disable [post]
+func (h *MaintenanceHandler) Disable(c *gin.Context) {
+	state, err := h.maintenanceService.Disable(c.Request.Context())
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, state)
+}
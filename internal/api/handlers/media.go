@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// MediaHandler serves the media stored from inbound messages
+type MediaHandler struct {
+	mediaService *service.MediaService
+}
+
+// NewMediaHandler creates a new media handler
+func NewMediaHandler(mediaService *service.MediaService) *MediaHandler {
+	return &MediaHandler{mediaService: mediaService}
+}
+
+// Get godoc
+// @Summary      Get stored media
+// @Description  Serves a media object stored from an inbound message. The URL is signed, so it works without authentication, such as in image tags, and expires after an hour; message reads return fresh URLs.
+// @Tags         media
+// @Produce      octet-stream
+// @Param        key path string true "Storage key"
+// @Param        expires query int true "Unix time the URL expires at"
+// @Param        signature query string true "URL signature"
+// @Success      200 {file} binary
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Router       /media/{key} [get]
+func (h *MediaHandler) Get(c *gin.Context) {
+	data, contentType, err := h.mediaService.Open(c.Request.Context(), c.Param("key"), c.Query("expires"), c.Query("signature"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	// Stored media never changes under its key; the URL stops working after its TTL
+	c.Header("Cache-Control", "private, max-age="+strconv.Itoa(int(service.MediaURLTTL.Seconds()))+", immutable")
+	c.Data(http.StatusOK, contentType, data)
+}
@@ -4,11 +4,16 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/msgfy/linktor/internal/api/middleware"
 	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
 )
 
 // MessageHandler handles message endpoints
 type MessageHandler struct {
-	messageService *service.MessageService
+	messageService     *service.MessageService
+	translationService *service.MessageTranslationService
 }
 
 // NewMessageHandler creates a new message handler
@@ -18,6 +23,11 @@ func NewMessageHandler(messageService *service.MessageService) *MessageHandler {
 	}
 }
 
+// SetTranslationService enables machine translations in message responses
+func (h *MessageHandler) SetTranslationService(translationService *service.MessageTranslationService) {
+	h.translationService = translationService
+}
+
 // SendMessageRequest represents a send message request
 type SendMessageRequest struct {
 	ContentType string            `json:"content_type" binding:"required"`
@@ -47,7 +57,7 @@ type TypingIndicatorRequest struct {
 
 // List godoc
 // @Summary      List messages
-// @Description  Returns all messages for a conversation
+// @Description  Returns all messages for a conversation. With translate_to, or for agents whose routing profile says they work with machine translation, text messages include a translation.
 // @Tags         messages
 // @Accept       json
 // @Produce      json
@@ -55,24 +65,35 @@ type TypingIndicatorRequest struct {
 // @Param        id path string true "Conversation ID"
 // @Param        page query int false "Page number" default(1)
 // @Param        page_size query int false "Page size" default(50)
+// @Param        translate_to query string false "Language code to translate text messages into, e.g. en or pt-BR"
+// @Param        If-None-Match header string false "ETag of a previous response"
 // @Success      200 {object} Response{data=[]entity.Message,meta=MetaResponse}
+// @Success      304
 // @Failure      401 {object} Response
 // @Failure      404 {object} Response
 // @Router       /conversations/{id}/messages [get]
 func (h *MessageHandler) List(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
 	conversationID := c.Param("id")
 	if conversationID == "" {
 		RespondValidationError(c, "Conversation ID is required", nil)
 		return
 	}
 
-	messages, total, err := h.messageService.ListByConversation(c.Request.Context(), conversationID, nil)
+	messages, total, err := h.messageService.ListByConversation(c.Request.Context(), tenantID, conversationID, nil)
 	if err != nil {
 		RespondError(c, err)
 		return
 	}
+	if !h.translate(c, messages) {
+		return
+	}
 
-	RespondWithMeta(c, messages, &MetaResponse{
+	RespondCacheable(c, messages, &MetaResponse{
 		Page:       1,
 		PageSize:   50,
 		TotalItems: total,
@@ -94,6 +115,11 @@ func (h *MessageHandler) List(c *gin.Context) {
 // @Failure      404 {object} Response
 // @Router       /conversations/{id}/messages [post]
 func (h *MessageHandler) Send(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
 	conversationID := c.Param("id")
 	if conversationID == "" {
 		RespondValidationError(c, "Conversation ID is required", nil)
@@ -112,6 +138,7 @@ func (h *MessageHandler) Send(c *gin.Context) {
 	}
 
 	input := &service.SendMessageInput{
+		TenantID:       tenantID,
 		ConversationID: conversationID,
 		SenderID:       userID,
 		SenderType:     "user",
@@ -131,12 +158,13 @@ func (h *MessageHandler) Send(c *gin.Context) {
 
 // Get godoc
 // @Summary      Get message
-// @Description  Returns a message by ID
+// @Description  Returns a message by ID, with a translation like the message list
 // @Tags         messages
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id path string true "Message ID"
+// @Param        translate_to query string false "Language code to translate a text message into, e.g. en or pt-BR"
 // @Success      200 {object} Response{data=entity.Message}
 // @Failure      401 {object} Response
 // @Failure      404 {object} Response
@@ -153,10 +181,47 @@ func (h *MessageHandler) Get(c *gin.Context) {
 		RespondError(c, err)
 		return
 	}
+	if !h.translate(c, []*entity.Message{message}) {
+		return
+	}
 
 	RespondSuccess(c, message)
 }
 
+// translate adds translations to messages when the request asks for them with
+// translate_to or the agent prefers reading translated conversations. An explicit
+// request fails loudly; a preference never keeps messages from being read. It
+// reports false when it responded with an error.
+func (h *MessageHandler) translate(c *gin.Context, messages []*entity.Message) bool {
+	language := c.Query("translate_to")
+	if h.translationService == nil {
+		if language != "" {
+			RespondError(c, errors.New(errors.ErrCodeBadRequest, "message translations are not enabled"))
+			return false
+		}
+		return true
+	}
+
+	ctx := c.Request.Context()
+	tenantID := middleware.GetTenantID(c)
+	if language == "" {
+		language = h.translationService.PreferredLanguage(ctx, tenantID, middleware.GetUserID(c))
+		if language == "" {
+			return true
+		}
+		if err := h.translationService.Translate(ctx, tenantID, language, messages); err != nil {
+			logger.Warn("Failed to translate messages", zap.String("language", language), zap.Error(err))
+		}
+		return true
+	}
+
+	if err := h.translationService.Translate(ctx, tenantID, language, messages); err != nil {
+		RespondError(c, err)
+		return false
+	}
+	return true
+}
+
 // SendReaction godoc
 // @Summary      Send reaction
 // @Description  Send a reaction (emoji) to a message. Send empty emoji to remove reaction.
@@ -173,6 +238,11 @@ func (h *MessageHandler) Get(c *gin.Context) {
 // @Failure      404 {object} Response
 // @Router       /conversations/{id}/messages/{messageId}/reactions [post]
 func (h *MessageHandler) SendReaction(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
 	conversationID := c.Param("id")
 	if conversationID == "" {
 		RespondValidationError(c, "Conversation ID is required", nil)
@@ -197,7 +267,7 @@ func (h *MessageHandler) SendReaction(c *gin.Context) {
 	}
 
 	// Send reaction using the message service
-	err := h.messageService.SendReaction(c.Request.Context(), conversationID, messageID, req.Emoji, userID)
+	err := h.messageService.SendReaction(c.Request.Context(), tenantID, conversationID, messageID, req.Emoji, userID)
 	if err != nil {
 		RespondError(c, err)
 		return
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// MessageHookHandler handles scripted message hooks
+type MessageHookHandler struct {
+	hookService *service.MessageHookService
+}
+
+// NewMessageHookHandler creates a new message hook handler
+func NewMessageHookHandler(hookService *service.MessageHookService) *MessageHookHandler {
+	return &MessageHookHandler{hookService: hookService}
+}
+
+// List godoc
+// @Summary      List message hooks
+// @Tags         hooks
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=[]entity.MessageHook}
+// @Router       /hooks [get]
+func (h *MessageHookHandler) List(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	hooks, err := h.hookService.List(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, hooks)
+}
+
+// Languages godoc
+// @Summary      List hook languages
+// @Description  Lists the script languages with a runtime available on this server
+// @Tags         hooks
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=[]string}
+// @Router       /hooks/languages [get]
+func (h *MessageHookHandler) Languages(c *gin.Context) {
+	RespondSuccess(c, h.hookService.Languages())
+}
+
+// Create godoc
+// @Summary      Create message hook
+// @Description  Creates a sandboxed script that runs on inbound or outbound messages
+// @Tags         hooks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.MessageHookInput true "Hook"
+// @Success      201 {object} Response{data=entity.MessageHook}
+// @Failure      400 {object} Response
+// @Router       /hooks [post]
+func (h *MessageHookHandler) Create(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.MessageHookInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	hook, err := h.hookService.Create(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, hook)
+}
+
+// Test godoc
+// @Summary      Test message hook
+// @Description  Runs a script against a sample message without saving it
+// @Tags         hooks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.TestMessageHookInput true "Script and sample message"
+// @Success      200 {object} Response{data=entity.HookTestResult}
+// @Failure      400 {object} Response
+// @Router       /hooks/test [post]
+func (h *MessageHookHandler) Test(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.TestMessageHookInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	result, err := h.hookService.Test(c.Request.Context(), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, result)
+}
+
+// Get godoc
+// @Summary      Get message hook
+// @Tags         hooks
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Hook ID"
+// @Success      200 {object} Response{data=entity.MessageHook}
+// @Failure      404 {object} Response
+// @Router       /hooks/{id} [get]
+func (h *MessageHookHandler) Get(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	hook, err := h.hookService.Get(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, hook)
+}
+
+// Update godoc
+// @Summary      Update message hook
+// @Tags         hooks
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Hook ID"
+// @Param        request body service.MessageHookInput true "Hook"
+// @Success      200 {object} Response{data=entity.MessageHook}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /hooks/{id} [put]
+func (h *MessageHookHandler) Update(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.MessageHookInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	hook, err := h.hookService.Update(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, hook)
+}
+
+// Delete godoc
+// @Summary      Delete message hook
+// @Tags         hooks
+// @Security     BearerAuth
+// @Param        id path string true "Hook ID"
+// @Success      204
+// @Failure      404 {object} Response
+// @Router       /hooks/{id} [delete]
+func (h *MessageHookHandler) Delete(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.hookService.Delete(c.Request.Context(), tenantID, c.Param("id")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
@@ -66,6 +66,22 @@ func seedMessage(repo *testutil.MockMessageRepository, id, conversationID string
 	return msg
 }
 
+// seedMessageConversation adds a conversation of a tenant to the mock repo.
+func seedMessageConversation(repo *testutil.MockConversationRepository, id, tenantID string) *entity.Conversation {
+	now := time.Now()
+	conv := &entity.Conversation{
+		ID:        id,
+		TenantID:  tenantID,
+		ContactID: "contact-1",
+		ChannelID: "channel-1",
+		Status:    entity.ConversationStatusOpen,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	repo.Conversations[id] = conv
+	return conv
+}
+
 // parseMessageResponse unmarshals the recorder body into a Response struct.
 func parseMessageResponse(t *testing.T, w *httptest.ResponseRecorder) Response {
 	t.Helper()
@@ -80,8 +96,9 @@ func parseMessageResponse(t *testing.T, w *httptest.ResponseRecorder) Response {
 // ---------------------------------------------------------------------------
 
 func TestMessageList_ValidConversationID_Returns200(t *testing.T) {
-	handler, msgRepo, _, _, _, _ := setupMessageHandler()
+	handler, msgRepo, convRepo, _, _, _ := setupMessageHandler()
 
+	seedMessageConversation(convRepo, "conv-1", "tenant-1")
 	seedMessage(msgRepo, "msg-1", "conv-1")
 	seedMessage(msgRepo, "msg-2", "conv-1")
 	seedMessage(msgRepo, "msg-3", "conv-other") // different conversation
@@ -104,6 +121,22 @@ func TestMessageList_ValidConversationID_Returns200(t *testing.T) {
 	assert.Len(t, dataSlice, 2)
 }
 
+func TestMessageList_OtherTenantsConversation_Returns404(t *testing.T) {
+	handler, msgRepo, convRepo, _, _, _ := setupMessageHandler()
+
+	seedMessageConversation(convRepo, "conv-other", "tenant-other")
+	seedMessage(msgRepo, "msg-1", "conv-other")
+
+	c, w := newMessageAuthContext()
+	c.Params = gin.Params{{Key: "id", Value: "conv-other"}}
+	c.Request = httptest.NewRequest(http.MethodGet, "/conversations/conv-other/messages", nil)
+
+	handler.List(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NotContains(t, w.Body.String(), "Hello world")
+}
+
 func TestMessageList_EmptyConversationID_Returns400(t *testing.T) {
 	handler, _, _, _, _, _ := setupMessageHandler()
 
@@ -175,6 +208,27 @@ func TestMessageSend_ValidRequest_Returns201(t *testing.T) {
 	assert.Equal(t, "Hello from test", data["content"])
 }
 
+func TestMessageSend_OtherTenantsConversation_Returns404(t *testing.T) {
+	handler, msgRepo, convRepo, channelRepo, contactRepo, producer := setupMessageHandler()
+
+	seedMessageConversation(convRepo, "conv-other", "tenant-other")
+	channelRepo.Channels["channel-1"] = &entity.Channel{ID: "channel-1", TenantID: "tenant-other", Type: entity.ChannelTypeWhatsApp}
+	contactRepo.Contacts["contact-1"] = &entity.Contact{ID: "contact-1", TenantID: "tenant-other", Phone: "+5511999999999"}
+
+	body, _ := json.Marshal(SendMessageRequest{ContentType: "text", Content: "Hello from another tenant"})
+
+	c, w := newMessageAuthContext()
+	c.Params = gin.Params{{Key: "id", Value: "conv-other"}}
+	c.Request = httptest.NewRequest(http.MethodPost, "/conversations/conv-other/messages", bytes.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.Send(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, msgRepo.Messages)
+	assert.Empty(t, producer.OutboundMessages)
+}
+
 func TestMessageSend_EmptyConversationID_Returns400(t *testing.T) {
 	handler, _, _, _, _, _ := setupMessageHandler()
 
@@ -341,6 +395,28 @@ func TestMessageSendReaction_Valid_Returns200(t *testing.T) {
 	assert.Equal(t, "Reaction added successfully", data["message"])
 }
 
+func TestMessageSendReaction_OtherTenantsConversation_Returns404(t *testing.T) {
+	handler, msgRepo, convRepo, _, _, producer := setupMessageHandler()
+
+	seedMessageConversation(convRepo, "conv-other", "tenant-other")
+	seedMessage(msgRepo, "msg-1", "conv-other")
+
+	body, _ := json.Marshal(SendReactionRequest{Emoji: "thumbsup"})
+
+	c, w := newMessageAuthContext()
+	c.Params = gin.Params{
+		{Key: "id", Value: "conv-other"},
+		{Key: "messageId", Value: "msg-1"},
+	}
+	c.Request = httptest.NewRequest(http.MethodPost, "/conversations/conv-other/messages/msg-1/reactions", bytes.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.SendReaction(c)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, producer.Events)
+}
+
 func TestMessageSendReaction_EmptyConversationID_Returns400(t *testing.T) {
 	handler, _, _, _, _, _ := setupMessageHandler()
 
@@ -0,0 +1,380 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+)
+
+// NewsletterHandler handles newsletters, their subscribers and editions
+type NewsletterHandler struct {
+	newsletterService *service.NewsletterService
+}
+
+// NewNewsletterHandler creates a new newsletter handler
+func NewNewsletterHandler(newsletterService *service.NewsletterService) *NewsletterHandler {
+	return &NewsletterHandler{newsletterService: newsletterService}
+}
+
+// List godoc
+// @Summary      List newsletters
+// @Tags         newsletters
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=[]entity.Newsletter}
+// @Router       /newsletters [get]
+func (h *NewsletterHandler) List(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	newsletters, err := h.newsletterService.List(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, newsletters)
+}
+
+// Create godoc
+// @Summary      Create newsletter
+// @Description  Creates a recurring newsletter sent to the opted-in subscribers of a WhatsApp, Telegram or email channel
+// @Tags         newsletters
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.NewsletterInput true "Newsletter"
+// @Success      201 {object} Response{data=entity.Newsletter}
+// @Failure      400 {object} Response
+// @Router       /newsletters [post]
+func (h *NewsletterHandler) Create(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.NewsletterInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	if !authorizeComplianceOverride(c, req.ComplianceOverride) {
+		return
+	}
+
+	newsletter, err := h.newsletterService.Create(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, newsletter)
+}
+
+// Get godoc
+// @Summary      Get newsletter
+// @Tags         newsletters
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Newsletter ID"
+// @Success      200 {object} Response{data=entity.Newsletter}
+// @Failure      404 {object} Response
+// @Router       /newsletters/{id} [get]
+func (h *NewsletterHandler) Get(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	newsletter, err := h.newsletterService.Get(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, newsletter)
+}
+
+// Update godoc
+// @Summary      Update newsletter
+// @Tags         newsletters
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Newsletter ID"
+// @Param        request body service.NewsletterInput true "Newsletter"
+// @Success      200 {object} Response{data=entity.Newsletter}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /newsletters/{id} [put]
+func (h *NewsletterHandler) Update(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.NewsletterInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	if !authorizeComplianceOverride(c, req.ComplianceOverride) {
+		return
+	}
+
+	newsletter, err := h.newsletterService.Update(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, newsletter)
+}
+
+// Delete godoc
+// @Summary      Delete newsletter
+// @Tags         newsletters
+// @Security     BearerAuth
+// @Param        id path string true "Newsletter ID"
+// @Success      204 "No Content"
+// @Failure      404 {object} Response
+// @Router       /newsletters/{id} [delete]
+func (h *NewsletterHandler) Delete(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.newsletterService.Delete(c.Request.Context(), tenantID, c.Param("id")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
+
+// ListSubscribers godoc
+// @Summary      List newsletter subscribers
+// @Tags         newsletters
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Newsletter ID"
+// @Param        status query string false "Subscription status (subscribed, unsubscribed)"
+// @Param        page query int false "Page number" default(1)
+// @Param        page_size query int false "Page size" default(20)
+// @Success      200 {object} Response{data=[]entity.NewsletterSubscription,meta=MetaResponse}
+// @Failure      404 {object} Response
+// @Router       /newsletters/{id}/subscribers [get]
+func (h *NewsletterHandler) ListSubscribers(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	params := &repository.ListParams{
+		Page:     page,
+		PageSize: pageSize,
+	}
+	status := entity.NewsletterSubscriptionStatus(c.Query("status"))
+
+	subscriptions, total, err := h.newsletterService.ListSubscriptions(c.Request.Context(), tenantID, c.Param("id"), status, params)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondPaginated(c, subscriptions, total, params.Page, params.PageSize)
+}
+
+// Subscribe godoc
+// @Summary      Subscribe contacts
+// @Description  Records the consent of contacts to receive the newsletter
+// @Tags         newsletters
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Newsletter ID"
+// @Param        request body service.SubscribeContactsInput true "Contacts"
+// @Success      200 {object} Response{data=object{subscribed=int}}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /newsletters/{id}/subscribers [post]
+func (h *NewsletterHandler) Subscribe(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.SubscribeContactsInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	count, err := h.newsletterService.Subscribe(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, gin.H{"subscribed": count})
+}
+
+// Unsubscribe godoc
+// @Summary      Unsubscribe contact
+// @Tags         newsletters
+// @Security     BearerAuth
+// @Param        id path string true "Newsletter ID"
+// @Param        contactId path string true "Contact ID"
+// @Success      204 "No Content"
+// @Failure      404 {object} Response
+// @Router       /newsletters/{id}/subscribers/{contactId} [delete]
+func (h *NewsletterHandler) Unsubscribe(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.newsletterService.Unsubscribe(c.Request.Context(), tenantID, c.Param("id"), c.Param("contactId")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
+
+// Send godoc
+// @Summary      Send newsletter edition now
+// @Description  Sends an edition to the subscribers in the segment, skipping contacts over the frequency cap
+// @Tags         newsletters
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Newsletter ID"
+// @Param        request body service.SendEditionInput false "Content override"
+// @Success      201 {object} Response{data=entity.NewsletterEdition}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /newsletters/{id}/send [post]
+func (h *NewsletterHandler) Send(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.SendEditionInput
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			RespondValidationError(c, "Invalid request body", nil)
+			return
+		}
+	}
+
+	if !authorizeComplianceOverride(c, req.ComplianceOverride) {
+		return
+	}
+
+	edition, err := h.newsletterService.SendNow(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, edition)
+}
+
+// ListEditions godoc
+// @Summary      List newsletter editions
+// @Tags         newsletters
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Newsletter ID"
+// @Param        page query int false "Page number" default(1)
+// @Param        page_size query int false "Page size" default(20)
+// @Success      200 {object} Response{data=[]entity.NewsletterEdition,meta=MetaResponse}
+// @Failure      404 {object} Response
+// @Router       /newsletters/{id}/editions [get]
+func (h *NewsletterHandler) ListEditions(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	params := &repository.ListParams{
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	editions, total, err := h.newsletterService.ListEditions(c.Request.Context(), tenantID, c.Param("id"), params)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondPaginated(c, editions, total, params.Page, params.PageSize)
+}
+
+// GetEdition godoc
+// @Summary      Get newsletter edition
+// @Description  Returns an edition with its delivery, read, reply and unsubscribe analytics, comparing smart-timed and fixed-time sends for smart send editions
+// @Tags         newsletters
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Newsletter ID"
+// @Param        editionId path string true "Edition ID"
+// @Success      200 {object} Response{data=entity.NewsletterEdition}
+// @Failure      404 {object} Response
+// @Router       /newsletters/{id}/editions/{editionId} [get]
+func (h *NewsletterHandler) GetEdition(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	edition, err := h.newsletterService.GetEdition(c.Request.Context(), tenantID, c.Param("id"), c.Param("editionId"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, edition)
+}
+
+// RenderStats godoc
+// @Summary      Get newsletter render stats
+// @Description  Returns how many personalized images the newsletter rendered across its editions, how many sends reused an image rendered for identical data, failures and render times
+// @Tags         newsletters
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Newsletter ID"
+// @Success      200 {object} Response{data=entity.NewsletterRenderSummary}
+// @Failure      404 {object} Response
+// @Router       /newsletters/{id}/render-stats [get]
+func (h *NewsletterHandler) RenderStats(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	stats, err := h.newsletterService.RenderStats(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, stats)
+}
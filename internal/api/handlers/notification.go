@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+)
+
+// NotificationHandler handles the notification center endpoints
+type NotificationHandler struct {
+	notificationService *service.NotificationService
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(notificationService *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+// List godoc
+// @Summary      List notifications
+// @Description  Returns the notification center of the tenant, newest first. Resolved notifications are left out unless include_resolved is set.
+// @Tags         notifications
+// @Produce      json
+// @Security     BearerAuth
+// @Param        unread query bool false "Only unread notifications"
+// @Param        include_resolved query bool false "Include notifications whose condition cleared"
+// @Param        page query int false "Page number"
+// @Param        page_size query int false "Page size"
+// @Success      200 {object} Response{data=[]entity.Notification}
+// @Failure      401 {object} Response
+// @Router       /notifications [get]
+func (h *NotificationHandler) List(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	filter := &entity.NotificationFilter{
+		UnreadOnly:      c.Query("unread") == "true",
+		IncludeResolved: c.Query("include_resolved") == "true",
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	params := &repository.ListParams{
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	notifications, total, err := h.notificationService.List(c.Request.Context(), tenantID, filter, params)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondPaginated(c, notifications, total, params.Page, params.PageSize)
+}
+
+// UnreadCount godoc
+// @Summary      Count unread notifications
+// @Description  Returns how many open notifications of the tenant are unread
+// @Tags         notifications
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=object{unread=int}}
+// @Failure      401 {object} Response
+// @Router       /notifications/unread-count [get]
+func (h *NotificationHandler) UnreadCount(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	count, err := h.notificationService.UnreadCount(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, gin.H{"unread": count})
+}
+
+// MarkRead godoc
+// @Summary      Mark notification as read
+// @Tags         notifications
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Notification ID"
+// @Success      204
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Router       /notifications/{id}/read [post]
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.notificationService.MarkRead(c.Request.Context(), tenantID, c.Param("id")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
+
+// MarkAllRead godoc
+// @Summary      Mark all notifications as read
+// @Tags         notifications
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=object{marked=int}}
+// @Failure      401 {object} Response
+// @Router       /notifications/read-all [post]
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	marked, err := h.notificationService.MarkAllRead(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, gin.H{"marked": marked})
+}
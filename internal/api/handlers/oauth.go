@@ -13,14 +13,16 @@ import (
 	"github.com/msgfy/linktor/internal/adapters/instagram"
 	"github.com/msgfy/linktor/internal/adapters/meta"
 	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
 	"github.com/msgfy/linktor/internal/domain/entity"
 	"github.com/msgfy/linktor/internal/domain/repository"
 )
 
 // OAuthHandler handles OAuth flows for Facebook and Instagram
 type OAuthHandler struct {
-	channelRepo repository.ChannelRepository
-	baseURL     string // Base URL for callbacks (e.g., https://api.linktor.com)
+	channelRepo  repository.ChannelRepository
+	tokenService *service.ChannelTokenService
+	baseURL      string // Base URL for callbacks (e.g., https://api.linktor.com)
 }
 
 // NewOAuthHandler creates a new OAuth handler
@@ -31,6 +33,11 @@ func NewOAuthHandler(channelRepo repository.ChannelRepository, baseURL string) *
 	}
 }
 
+// SetTokenService sets the service that tracks and refreshes channel access tokens
+func (h *OAuthHandler) SetTokenService(tokenService *service.ChannelTokenService) {
+	h.tokenService = tokenService
+}
+
 // OAuthState stores OAuth state information
 type OAuthState struct {
 	TenantID    string `json:"tenant_id"`
@@ -476,6 +483,11 @@ func (h *OAuthHandler) CreateChannel(c *gin.Context) {
 		}
 	}
 
+	// Track when the access token expires so it is refreshed in time
+	if h.tokenService != nil {
+		h.tokenService.TrackInBackground(c.Request.Context(), channel)
+	}
+
 	// Build webhook URL
 	webhookURL := h.baseURL + "/api/v1/webhooks/" + string(channelType) + "/" + channel.ID
 
@@ -486,11 +498,12 @@ func (h *OAuthHandler) CreateChannel(c *gin.Context) {
 	})
 }
 
-// RefreshTokenRequest represents a request to refresh an access token
+// RefreshTokenRequest represents a request to refresh an access token. The app
+// credentials default to those stored with the channel.
 type RefreshTokenRequest struct {
 	ChannelID   string `json:"channel_id" binding:"required"`
-	AppID       string `json:"app_id" binding:"required"`
-	AppSecret   string `json:"app_secret" binding:"required"`
+	AppID       string `json:"app_id"`
+	AppSecret   string `json:"app_secret"`
 }
 
 // RefreshToken refreshes the access token for a channel
@@ -502,36 +515,21 @@ func (h *OAuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	// Get channel
-	channel, err := h.channelRepo.FindByID(c.Request.Context(), req.ChannelID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "channel not found"})
+	if h.tokenService == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "token refresh is not available"})
 		return
 	}
 
-	currentToken := channel.Credentials["access_token"]
-	if currentToken == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "no access token found"})
-		return
-	}
-
-	// Get new long-lived token
-	helper := facebook.NewOAuthHelper(req.AppID, req.AppSecret)
-	newToken, err := helper.GetLongLivedToken(c.Request.Context(), currentToken)
+	tenantID := c.GetString(middleware.TenantIDKey)
+	token, err := h.tokenService.Refresh(c.Request.Context(), tenantID, req.ChannelID, req.AppID, req.AppSecret)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to refresh token: " + err.Error()})
-		return
-	}
-
-	// Update channel credentials
-	channel.Credentials["access_token"] = newToken.AccessToken
-	if err := h.channelRepo.Update(c.Request.Context(), channel); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update channel"})
+		RespondError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"access_token": newToken.AccessToken,
-		"expires_in":   newToken.ExpiresIn,
+		"status":       token.Status,
+		"expires_at":   token.ExpiresAt,
+		"refreshed_at": token.RefreshedAt,
 	})
 }
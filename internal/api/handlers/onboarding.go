@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// OnboardingHandler handles the tenant onboarding wizard
+type OnboardingHandler struct {
+	onboardingService *service.OnboardingService
+}
+
+// NewOnboardingHandler creates a new onboarding handler
+func NewOnboardingHandler(onboardingService *service.OnboardingService) *OnboardingHandler {
+	return &OnboardingHandler{onboardingService: onboardingService}
+}
+
+// GetStatus godoc
+// @Summary      Get onboarding progress
+// @Description  Returns the status of each onboarding step and the step to show next
+// @Tags         onboarding
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=service.OnboardingStatus}
+// @Router       /onboarding [get]
+func (h *OnboardingHandler) GetStatus(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	status, err := h.onboardingService.GetStatus(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, status)
+}
+
+// SetupWorkspace godoc
+// @Summary      Set up workspace
+// @Description  Names the workspace and stores its initial settings
+// @Tags         onboarding
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.SetupWorkspaceInput true "Workspace"
+// @Success      200 {object} Response{data=service.OnboardingStatus}
+// @Failure      400 {object} Response
+// @Router       /onboarding/workspace [post]
+func (h *OnboardingHandler) SetupWorkspace(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.SetupWorkspaceInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	status, err := h.onboardingService.SetupWorkspace(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, status)
+}
+
+// InviteUsers godoc
+// @Summary      Invite teammates
+// @Description  Creates accounts for teammates; passwords left empty are generated and returned once
+// @Tags         onboarding
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.InviteUsersInput true "Teammates"
+// @Success      200 {object} Response{data=service.InviteUsersResult}
+// @Failure      400 {object} Response
+// @Router       /onboarding/invites [post]
+func (h *OnboardingHandler) InviteUsers(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.InviteUsersInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	result, err := h.onboardingService.InviteUsers(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, result)
+}
+
+// ConnectChannel godoc
+// @Summary      Connect first channel
+// @Description  Creates a channel and connects it; QR code channels complete the step once paired
+// @Tags         onboarding
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.OnboardingChannelInput true "Channel"
+// @Success      200 {object} Response{data=service.OnboardingChannelResult}
+// @Failure      400 {object} Response
+// @Router       /onboarding/channel [post]
+func (h *OnboardingHandler) ConnectChannel(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.OnboardingChannelInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	result, err := h.onboardingService.ConnectChannel(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, result)
+}
+
+// CreateBot godoc
+// @Summary      Create first bot
+// @Tags         onboarding
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.OnboardingBotInput true "Bot"
+// @Success      200 {object} Response{data=service.OnboardingBotResult}
+// @Failure      400 {object} Response
+// @Router       /onboarding/bot [post]
+func (h *OnboardingHandler) CreateBot(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.OnboardingBotInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	result, err := h.onboardingService.CreateBot(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, result)
+}
+
+// SendTestMessage godoc
+// @Summary      Send test message
+// @Description  Sends a message to a conversation, or to the sample conversation when none is given
+// @Tags         onboarding
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.TestMessageInput false "Test message"
+// @Success      200 {object} Response{data=service.TestMessageResult}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /onboarding/test-message [post]
+func (h *OnboardingHandler) SendTestMessage(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.TestMessageInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			RespondValidationError(c, "Invalid request body", nil)
+			return
+		}
+	}
+
+	result, err := h.onboardingService.SendTestMessage(c.Request.Context(), tenantID, middleware.GetUserID(c), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, result)
+}
+
+// ProvisionSamples godoc
+// @Summary      Provision sample resources
+// @Description  Creates a sample web chat channel, contact and conversation to try the inbox with
+// @Tags         onboarding
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=service.OnboardingStatus}
+// @Router       /onboarding/samples [post]
+func (h *OnboardingHandler) ProvisionSamples(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	status, err := h.onboardingService.ProvisionSamples(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, status)
+}
+
+// SkipStep godoc
+// @Summary      Skip onboarding step
+// @Tags         onboarding
+// @Produce      json
+// @Security     BearerAuth
+// @Param        step path string true "Step" Enums(workspace, invite_users, connect_channel, create_bot, send_test_message)
+// @Success      200 {object} Response{data=service.OnboardingStatus}
+// @Failure      400 {object} Response
+// @Failure      409 {object} Response
+// @Router       /onboarding/steps/{step}/skip [post]
+func (h *OnboardingHandler) SkipStep(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	status, err := h.onboardingService.SkipStep(c.Request.Context(), tenantID, entity.OnboardingStep(c.Param("step")))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, status)
+}
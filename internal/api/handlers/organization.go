@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// OrganizationHandler handles organizations, which group tenants as workspaces with
+// shared accounts and billing
+type OrganizationHandler struct {
+	organizationService *service.OrganizationService
+}
+
+// NewOrganizationHandler creates a new organization handler
+func NewOrganizationHandler(organizationService *service.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{organizationService: organizationService}
+}
+
+// Create godoc
+// @Summary      Create organization
+// @Description  Creates an organization with the current tenant as its first workspace and the caller, who must own the tenant, as its owner
+// @Tags         organization
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.CreateOrganizationInput true "Organization"
+// @Success      201 {object} Response{data=entity.Organization}
+// @Failure      400 {object} Response
+// @Failure      403 {object} Response
+// @Failure      409 {object} Response
+// @Router       /organization [post]
+func (h *OrganizationHandler) Create(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.CreateOrganizationInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	org, err := h.organizationService.Create(c.Request.Context(), tenantID, middleware.GetUserID(c), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, org)
+}
+
+// Get godoc
+// @Summary      Get organization
+// @Description  Returns the organization the current tenant is a workspace of
+// @Tags         organization
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=entity.Organization}
+// @Failure      404 {object} Response
+// @Router       /organization [get]
+func (h *OrganizationHandler) Get(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	org, err := h.organizationService.Get(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, org)
+}
+
+// Update godoc
+// @Summary      Update organization
+// @Description  Updates the organization's name or billing email; organization owners and admins only
+// @Tags         organization
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.UpdateOrganizationInput true "Organization"
+// @Success      200 {object} Response{data=entity.Organization}
+// @Failure      400 {object} Response
+// @Failure      403 {object} Response
+// @Router       /organization [put]
+func (h *OrganizationHandler) Update(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.UpdateOrganizationInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	org, err := h.organizationService.Update(c.Request.Context(), tenantID, middleware.GetUserID(c), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, org)
+}
+
+// ListWorkspaces godoc
+// @Summary      List workspaces
+// @Description  Returns the workspaces the caller can switch to with their role in each; organization owners and admins see all workspaces
+// @Tags         organization
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=[]entity.OrganizationWorkspace}
+// @Failure      403 {object} Response
+// @Router       /organization/workspaces [get]
+func (h *OrganizationHandler) ListWorkspaces(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	workspaces, err := h.organizationService.ListWorkspaces(c.Request.Context(), tenantID, middleware.GetUserID(c))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, workspaces)
+}
+
+// CreateWorkspace godoc
+// @Summary      Create workspace
+// @Description  Creates a workspace on the organization's plan with an owner account for the caller; organization owners and admins only
+// @Tags         organization
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.CreateWorkspaceInput true "Workspace"
+// @Success      201 {object} Response{data=entity.OrganizationWorkspace}
+// @Failure      400 {object} Response
+// @Failure      403 {object} Response
+// @Failure      409 {object} Response
+// @Router       /organization/workspaces [post]
+func (h *OrganizationHandler) CreateWorkspace(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.CreateWorkspaceInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	workspace, err := h.organizationService.CreateWorkspace(c.Request.Context(), tenantID, middleware.GetUserID(c), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, workspace)
+}
+
+// ListMembers godoc
+// @Summary      List organization members
+// @Description  Returns the organization's members with their accounts in each workspace
+// @Tags         organization
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=[]entity.OrganizationMember}
+// @Failure      403 {object} Response
+// @Router       /organization/members [get]
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	members, err := h.organizationService.ListMembers(c.Request.Context(), tenantID, middleware.GetUserID(c))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, members)
+}
+
+// SaveMember godoc
+// @Summary      Add or update organization member
+// @Description  Adds a person to the organization or changes their role, creating their accounts in the granted workspaces with a shared password; organization owners and admins only
+// @Tags         organization
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.AddOrganizationMemberInput true "Member"
+// @Success      200 {object} Response{data=entity.OrganizationMember}
+// @Failure      400 {object} Response
+// @Failure      403 {object} Response
+// @Router       /organization/members [post]
+func (h *OrganizationHandler) SaveMember(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.AddOrganizationMemberInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	member, err := h.organizationService.AddMember(c.Request.Context(), tenantID, middleware.GetUserID(c), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, member)
+}
+
+// RemoveMember godoc
+// @Summary      Remove organization member
+// @Description  Removes a person from the organization and deactivates their accounts in its workspaces; organization owners and admins only
+// @Tags         organization
+// @Security     BearerAuth
+// @Param        email path string true "Member email"
+// @Success      204
+// @Failure      403 {object} Response
+// @Failure      404 {object} Response
+// @Router       /organization/members/{email} [delete]
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.organizationService.RemoveMember(c.Request.Context(), tenantID, middleware.GetUserID(c), c.Param("email")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
+
+// Usage godoc
+// @Summary      Get organization usage
+// @Description  Returns the usage of every workspace in the billing period and the total against the organization's pooled limits; organization owners and admins only
+// @Tags         organization
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=entity.OrganizationUsage}
+// @Failure      403 {object} Response
+// @Router       /organization/usage [get]
+func (h *OrganizationHandler) Usage(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	usage, err := h.organizationService.Usage(c.Request.Context(), tenantID, middleware.GetUserID(c))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, usage)
+}
@@ -1,13 +1,17 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/msgfy/linktor/internal/api/middleware"
 	"github.com/msgfy/linktor/internal/whatsapp/payments"
 )
 
@@ -48,7 +52,7 @@ func (h *PaymentsHandler) getClient(channelID string) (*payments.Client, bool) {
 
 // CreatePayment godoc
 // @Summary      Create a payment request
-// @Description  Creates a new WhatsApp payment request to send to a customer
+// @Description  Creates a new WhatsApp payment request to send to a customer. Amounts are in minor units of the currency; the currency must be one the channel's gateway supports.
 // @Tags         whatsapp-payments
 // @Accept       json
 // @Produce      json
@@ -84,13 +88,16 @@ func (h *PaymentsHandler) CreatePayment(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Amount must be greater than 0"})
 		return
 	}
-	if req.Currency == "" {
-		req.Currency = "BRL" // Default to BRL for Brazil
-	}
 	if req.ReferenceID == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Reference ID is required"})
 		return
 	}
+	// Defaults the currency to the one the gateway charges and checks currency,
+	// items and taxes against the gateway
+	if err := client.ValidatePaymentRequest(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	result, err := client.CreatePayment(c.Request.Context(), &req)
 	if err != nil {
@@ -165,7 +172,7 @@ func (h *PaymentsHandler) GetPaymentByReference(c *gin.Context) {
 
 // ProcessRefund godoc
 // @Summary      Process a refund
-// @Description  Initiates a refund for a completed payment
+// @Description  Initiates a refund for a completed payment. Refunds above the channel's approval threshold are stored as pending_approval and answered with 202 until a finance user reviews them.
 // @Tags         whatsapp-payments
 // @Accept       json
 // @Produce      json
@@ -174,6 +181,7 @@ func (h *PaymentsHandler) GetPaymentByReference(c *gin.Context) {
 // @Param        paymentId path string true "Payment ID"
 // @Param        request   body object{amount=int64,reason=string} false "Refund details (empty for full refund)"
 // @Success      200 {object} payments.Refund
+// @Success      202 {object} payments.Refund
 // @Failure      400 {object} Response
 // @Failure      404 {object} Response
 // @Failure      500 {object} Response
@@ -199,9 +207,10 @@ func (h *PaymentsHandler) ProcessRefund(c *gin.Context) {
 	}
 
 	refundReq := &payments.RefundRequest{
-		PaymentID: paymentID,
-		Amount:    req.Amount,
-		Reason:    req.Reason,
+		PaymentID:   paymentID,
+		Amount:      req.Amount,
+		Reason:      req.Reason,
+		RequestedBy: middleware.GetUserID(c),
 	}
 
 	refund, err := client.ProcessRefund(c.Request.Context(), refundReq)
@@ -210,12 +219,133 @@ func (h *PaymentsHandler) ProcessRefund(c *gin.Context) {
 		return
 	}
 
+	if refund.Status == payments.RefundStatusPendingApproval {
+		c.JSON(http.StatusAccepted, refund)
+		return
+	}
+	c.JSON(http.StatusOK, refund)
+}
+
+// ApproveRefund godoc
+// @Summary      Approve a refund
+// @Description  Executes a refund waiting for approval and notifies the customer. The approver must be a finance user other than the requester.
+// @Tags         whatsapp-payments
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        channelId path string true "Channel ID"
+// @Param        refundId  path string true "Refund ID"
+// @Param        request   body object{note=string} false "Review note"
+// @Success      200 {object} payments.Refund
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Failure      500 {object} Response
+// @Router       /channels/{channelId}/payments/refunds/{refundId}/approve [post]
+func (h *PaymentsHandler) ApproveRefund(c *gin.Context) {
+	h.reviewRefund(c, (*payments.Client).ApproveRefund)
+}
+
+// RejectRefund godoc
+// @Summary      Reject a refund
+// @Description  Declines a refund waiting for approval and tells the customer. The note is included in the customer message.
+// @Tags         whatsapp-payments
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        channelId path string true "Channel ID"
+// @Param        refundId  path string true "Refund ID"
+// @Param        request   body object{note=string} false "Review note"
+// @Success      200 {object} payments.Refund
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Failure      500 {object} Response
+// @Router       /channels/{channelId}/payments/refunds/{refundId}/reject [post]
+func (h *PaymentsHandler) RejectRefund(c *gin.Context) {
+	h.reviewRefund(c, (*payments.Client).RejectRefund)
+}
+
+// reviewRefund applies an approval decision to a refund
+func (h *PaymentsHandler) reviewRefund(c *gin.Context, review func(*payments.Client, context.Context, string, string, string) (*payments.Refund, error)) {
+	channelID := c.Param("id")
+
+	client, ok := h.getClient(channelID)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Channel not found or payments not configured"})
+		return
+	}
+
+	var req struct {
+		Note string `json:"note"`
+	}
+	// The note is optional
+	_ = c.ShouldBindJSON(&req)
+
+	refund, err := review(client, c.Request.Context(), c.Param("refundId"), middleware.GetUserID(c), req.Note)
+	if err != nil {
+		switch {
+		case errors.Is(err, payments.ErrRefundNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, payments.ErrRefundNotReviewable):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
 	c.JSON(http.StatusOK, refund)
 }
 
+// GetRefundReport godoc
+// @Summary      Get refunds audit report
+// @Description  Lists the refunds of the channel, who requested and reviewed them, with totals per currency and status
+// @Tags         whatsapp-payments
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        channelId path  string true  "Channel ID"
+// @Param        status    query string false "Refund status, e.g. pending_approval"
+// @Param        from      query string false "Created at or after (RFC3339)"
+// @Param        to        query string false "Created before (RFC3339)"
+// @Success      200 {object} payments.RefundReport
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Failure      500 {object} Response
+// @Router       /channels/{channelId}/payments/refunds [get]
+func (h *PaymentsHandler) GetRefundReport(c *gin.Context) {
+	channelID := c.Param("id")
+
+	client, ok := h.getClient(channelID)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Channel not found or payments not configured"})
+		return
+	}
+
+	filter := &payments.RefundFilter{Status: payments.RefundStatus(c.Query("status"))}
+	for param, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param + ", expected RFC3339"})
+			return
+		}
+		*target = &parsed
+	}
+
+	report, err := client.GetRefundReport(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
 // GetPaymentStats godoc
 // @Summary      Get payment statistics
-// @Description  Returns aggregated payment statistics for the channel
+// @Description  Returns aggregated payment statistics for the channel, with totals per currency
 // @Tags         whatsapp-payments
 // @Accept       json
 // @Produce      json
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// PostbackHandler handles the postback registry of interactive replies
+type PostbackHandler struct {
+	postbackService *service.PostbackService
+}
+
+// NewPostbackHandler creates a new postback handler
+func NewPostbackHandler(postbackService *service.PostbackService) *PostbackHandler {
+	return &PostbackHandler{postbackService: postbackService}
+}
+
+// Register registers a postback and returns the signed token to use as button or row ID
+// @Summary      Register postback
+// @Description  Maps a button or list reply to a flow node, automation rule or poll vote. Use the returned token as the button/row ID.
+// @Tags         messages
+// @Accept       json
+// @Produce      json
+// @Param        request body service.RegisterPostbackInput true "Postback"
+// @Success      201 {object} Response{data=service.RegisteredPostback}
+// @Failure      400 {object} Response
+// @Security     BearerAuth
+// @Router       /postbacks [post]
+func (h *PostbackHandler) Register(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.RegisterPostbackInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	registered, err := h.postbackService.Register(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, registered)
+}
+
+// GetPollResults returns the votes recorded for a poll
+// @Summary      Get poll results
+// @Description  Returns the votes recorded from record_vote postbacks, grouped by option
+// @Tags         messages
+// @Produce      json
+// @Param        pollId path string true "Poll ID"
+// @Success      200 {object} Response{data=entity.PollResult}
+// @Security     BearerAuth
+// @Router       /postbacks/polls/{pollId} [get]
+func (h *PostbackHandler) GetPollResults(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	result, err := h.postbackService.GetPollResults(c.Request.Context(), tenantID, c.Param("pollId"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, result)
+}
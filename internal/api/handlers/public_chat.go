@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+const (
+	// PublicChatContactHeader carries the tenant's ID for the contact
+	PublicChatContactHeader = "X-Linktor-Contact-ID"
+	// PublicChatSignatureHeader carries the HMAC-SHA256 of the contact ID with the channel secret
+	PublicChatSignatureHeader = "X-Linktor-Signature"
+
+	publicChatSessionKey = "public_chat_session"
+)
+
+// PublicChatHandler handles the signed public API used by tenant websites and apps
+// to chat on behalf of their contacts without the webchat widget
+type PublicChatHandler struct {
+	publicChatService *service.PublicChatService
+}
+
+// NewPublicChatHandler creates a new public chat handler
+func NewPublicChatHandler(publicChatService *service.PublicChatService) *PublicChatHandler {
+	return &PublicChatHandler{publicChatService: publicChatService}
+}
+
+// RateLimitKey identifies the caller of a public chat request for rate limiting:
+// the signed contact when present, the client IP otherwise
+func (h *PublicChatHandler) RateLimitKey(c *gin.Context) string {
+	if contactID := c.GetHeader(PublicChatContactHeader); contactID != "" {
+		return "public_chat:" + c.Param("channelId") + ":" + contactID
+	}
+	return "public_chat:ip:" + c.ClientIP()
+}
+
+// Authenticate verifies the contact signature headers and stores the session on the context
+func (h *PublicChatHandler) Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		session, err := h.publicChatService.Authenticate(
+			c.Request.Context(),
+			c.Param("channelId"),
+			c.GetHeader(PublicChatContactHeader),
+			c.GetHeader(PublicChatSignatureHeader),
+		)
+		if err != nil {
+			RespondError(c, err)
+			c.Abort()
+			return
+		}
+		c.Set(publicChatSessionKey, session)
+		c.Next()
+	}
+}
+
+func publicChatSession(c *gin.Context) *service.PublicChatSession {
+	session, _ := c.MustGet(publicChatSessionKey).(*service.PublicChatSession)
+	return session
+}
+
+// StartConversation godoc
+// @Summary      Start a conversation
+// @Description  Returns the open conversation of the signed contact on the webchat channel, creating the contact and conversation when needed
+// @Tags         public-chat
+// @Accept       json
+// @Produce      json
+// @Param        channelId path string true "Webchat channel ID"
+// @Param        X-Linktor-Contact-ID header string true "Tenant's ID for the contact"
+// @Param        X-Linktor-Signature header string true "Hex HMAC-SHA256 of the contact ID with the channel secret"
+// @Param        request body service.PublicChatContactInput false "Contact details, used when the contact is new"
+// @Success      200 {object} Response{data=entity.Conversation}
+// @Failure      401 {object} Response
+// @Failure      429 {object} Response
+// @Router       /public/chat/{channelId}/conversations [post]
+func (h *PublicChatHandler) StartConversation(c *gin.Context) {
+	var req service.PublicChatContactInput
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			RespondValidationError(c, "Invalid request body", nil)
+			return
+		}
+	}
+
+	conversation, err := h.publicChatService.StartConversation(c.Request.Context(), publicChatSession(c), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, conversation)
+}
+
+// PostMessage godoc
+// @Summary      Post a message
+// @Description  Queues a message from the signed contact. It appears when polling once stored, with the same client_message_id.
+// @Tags         public-chat
+// @Accept       json
+// @Produce      json
+// @Param        channelId path string true "Webchat channel ID"
+// @Param        conversationId path string true "Conversation ID"
+// @Param        X-Linktor-Contact-ID header string true "Tenant's ID for the contact"
+// @Param        X-Linktor-Signature header string true "Hex HMAC-SHA256 of the contact ID with the channel secret"
+// @Param        request body service.PublicChatMessageInput true "Message"
+// @Success      202 {object} Response{data=service.PublicChatAccepted}
+// @Failure      400 {object} Response
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Failure      429 {object} Response
+// @Router       /public/chat/{channelId}/conversations/{conversationId}/messages [post]
+func (h *PublicChatHandler) PostMessage(c *gin.Context) {
+	var req service.PublicChatMessageInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	accepted, err := h.publicChatService.PostMessage(c.Request.Context(), publicChatSession(c), c.Param("conversationId"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondAccepted(c, accepted)
+}
+
+// ListMessages godoc
+// @Summary      Poll messages
+// @Description  Returns the messages of the conversation created after the given time, oldest first. Pass the created_at of the last message seen to poll for replies.
+// @Tags         public-chat
+// @Produce      json
+// @Param        channelId path string true "Webchat channel ID"
+// @Param        conversationId path string true "Conversation ID"
+// @Param        X-Linktor-Contact-ID header string true "Tenant's ID for the contact"
+// @Param        X-Linktor-Signature header string true "Hex HMAC-SHA256 of the contact ID with the channel secret"
+// @Param        after query string false "RFC 3339 timestamp"
+// @Param        limit query int false "Maximum messages (default 50, max 200)"
+// @Success      200 {object} Response{data=[]service.PublicChatMessage}
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Failure      429 {object} Response
+// @Router       /public/chat/{channelId}/conversations/{conversationId}/messages [get]
+func (h *PublicChatHandler) ListMessages(c *gin.Context) {
+	var after time.Time
+	if value := c.Query("after"); value != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			RespondValidationError(c, "after must be an RFC 3339 timestamp", nil)
+			return
+		}
+		after = parsed
+	}
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	messages, err := h.publicChatService.ListMessages(c.Request.Context(), publicChatSession(c), c.Param("conversationId"), after, limit)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, messages)
+}
+
+// RotateSecret godoc
+// @Summary      Rotate public chat secret
+// @Description  Generates the secret tenant backends use to sign contact IDs for the public chat API of a webchat channel. The previous secret stops working.
+// @Tags         channels
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Channel ID"
+// @Success      200 {object} Response{data=map[string]string}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /channels/{id}/public-chat-secret [post]
+func (h *PublicChatHandler) RotateSecret(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	secret, err := h.publicChatService.RotateSecret(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, gin.H{"secret": secret})
+}
@@ -0,0 +1,397 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+)
+
+// QAHandler handles quality assurance scorecards, sampling rules and reviews
+type QAHandler struct {
+	qaService *service.QAService
+}
+
+// NewQAHandler creates a new QA handler
+func NewQAHandler(qaService *service.QAService) *QAHandler {
+	return &QAHandler{qaService: qaService}
+}
+
+// ListScorecards godoc
+// @Summary      List QA scorecards
+// @Tags         qa
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=[]entity.QAScorecard}
+// @Router       /qa/scorecards [get]
+func (h *QAHandler) ListScorecards(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	scorecards, err := h.qaService.ListScorecards(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, scorecards)
+}
+
+// CreateScorecard godoc
+// @Summary      Create QA scorecard
+// @Description  Creates a review form with weighted criteria scored from 0 to max_score
+// @Tags         qa
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.ScorecardInput true "Scorecard"
+// @Success      201 {object} Response{data=entity.QAScorecard}
+// @Failure      400 {object} Response
+// @Router       /qa/scorecards [post]
+func (h *QAHandler) CreateScorecard(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.ScorecardInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	scorecard, err := h.qaService.CreateScorecard(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, scorecard)
+}
+
+// GetScorecard godoc
+// @Summary      Get QA scorecard
+// @Tags         qa
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Scorecard ID"
+// @Success      200 {object} Response{data=entity.QAScorecard}
+// @Failure      404 {object} Response
+// @Router       /qa/scorecards/{id} [get]
+func (h *QAHandler) GetScorecard(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	scorecard, err := h.qaService.GetScorecard(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, scorecard)
+}
+
+// UpdateScorecard godoc
+// @Summary      Update QA scorecard
+// @Tags         qa
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Scorecard ID"
+// @Param        request body service.ScorecardInput true "Scorecard"
+// @Success      200 {object} Response{data=entity.QAScorecard}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /qa/scorecards/{id} [put]
+func (h *QAHandler) UpdateScorecard(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.ScorecardInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	scorecard, err := h.qaService.UpdateScorecard(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, scorecard)
+}
+
+// DeleteScorecard godoc
+// @Summary      Delete QA scorecard
+// @Tags         qa
+// @Security     BearerAuth
+// @Param        id path string true "Scorecard ID"
+// @Success      204
+// @Failure      404 {object} Response
+// @Router       /qa/scorecards/{id} [delete]
+func (h *QAHandler) DeleteScorecard(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.qaService.DeleteScorecard(c.Request.Context(), tenantID, c.Param("id")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
+
+// ListRules godoc
+// @Summary      List QA sampling rules
+// @Tags         qa
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=[]entity.QASamplingRule}
+// @Router       /qa/rules [get]
+func (h *QAHandler) ListRules(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	rules, err := h.qaService.ListRules(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, rules)
+}
+
+// CreateRule godoc
+// @Summary      Create QA sampling rule
+// @Description  Samples a share of each agent's resolved conversations for review with a scorecard
+// @Tags         qa
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.SamplingRuleInput true "Sampling rule"
+// @Success      201 {object} Response{data=entity.QASamplingRule}
+// @Failure      400 {object} Response
+// @Router       /qa/rules [post]
+func (h *QAHandler) CreateRule(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.SamplingRuleInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	rule, err := h.qaService.CreateRule(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, rule)
+}
+
+// UpdateRule godoc
+// @Summary      Update QA sampling rule
+// @Tags         qa
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Rule ID"
+// @Param        request body service.SamplingRuleInput true "Sampling rule"
+// @Success      200 {object} Response{data=entity.QASamplingRule}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /qa/rules/{id} [put]
+func (h *QAHandler) UpdateRule(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.SamplingRuleInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	rule, err := h.qaService.UpdateRule(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, rule)
+}
+
+// DeleteRule godoc
+// @Summary      Delete QA sampling rule
+// @Tags         qa
+// @Security     BearerAuth
+// @Param        id path string true "Rule ID"
+// @Success      204
+// @Failure      404 {object} Response
+// @Router       /qa/rules/{id} [delete]
+func (h *QAHandler) DeleteRule(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.qaService.DeleteRule(c.Request.Context(), tenantID, c.Param("id")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
+
+// RunRule godoc
+// @Summary      Run QA sampling rule
+// @Description  Samples conversations resolved since the rule's last run and creates pending reviews
+// @Tags         qa
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Rule ID"
+// @Success      200 {object} Response{data=[]entity.QAReview}
+// @Failure      404 {object} Response
+// @Router       /qa/rules/{id}/run [post]
+func (h *QAHandler) RunRule(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	reviews, err := h.qaService.RunRule(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, reviews)
+}
+
+// ListReviews godoc
+// @Summary      List QA reviews
+// @Tags         qa
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page query int false "Page number" default(1)
+// @Param        page_size query int false "Page size" default(20)
+// @Param        agent_id query string false "Filter by reviewed agent"
+// @Param        reviewer_id query string false "Filter by reviewer"
+// @Param        scorecard_id query string false "Filter by scorecard"
+// @Param        status query string false "Filter by status (pending, completed)"
+// @Success      200 {object} Response{data=[]entity.QAReview}
+// @Router       /qa/reviews [get]
+func (h *QAHandler) ListReviews(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	params := &repository.ListParams{
+		Page:     page,
+		PageSize: pageSize,
+		SortDir:  c.DefaultQuery("sort_dir", "desc"),
+	}
+
+	filter := &entity.QAReviewFilter{
+		AgentID:     c.Query("agent_id"),
+		ReviewerID:  c.Query("reviewer_id"),
+		ScorecardID: c.Query("scorecard_id"),
+		Status:      entity.QAReviewStatus(c.Query("status")),
+	}
+
+	reviews, total, err := h.qaService.ListReviews(c.Request.Context(), tenantID, filter, params)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondPaginated(c, reviews, total, params.Page, params.PageSize)
+}
+
+// GetReview godoc
+// @Summary      Get QA review
+// @Description  Returns a review with the scorecard form to fill in
+// @Tags         qa
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Review ID"
+// @Success      200 {object} Response{data=service.QAReviewDetail}
+// @Failure      404 {object} Response
+// @Router       /qa/reviews/{id} [get]
+func (h *QAHandler) GetReview(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	detail, err := h.qaService.GetReview(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, detail)
+}
+
+// SubmitReview godoc
+// @Summary      Submit QA review
+// @Description  Scores every criterion of the review's scorecard and notifies the reviewed agent
+// @Tags         qa
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Review ID"
+// @Param        request body service.SubmitReviewInput true "Scores"
+// @Success      200 {object} Response{data=entity.QAReview}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Failure      409 {object} Response
+// @Router       /qa/reviews/{id}/submit [post]
+func (h *QAHandler) SubmitReview(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	userID := middleware.MustGetUserID(c)
+	if userID == "" {
+		return
+	}
+
+	var req service.SubmitReviewInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	review, err := h.qaService.SubmitReview(c.Request.Context(), tenantID, c.Param("id"), userID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, review)
+}
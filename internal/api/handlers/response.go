@@ -1,7 +1,11 @@
 package handlers
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/msgfy/linktor/pkg/errors"
@@ -48,6 +52,14 @@ func RespondCreated(c *gin.Context, data interface{}) {
 	})
 }
 
+// RespondAccepted sends an accepted response for work that continues in the background
+func RespondAccepted(c *gin.Context, data interface{}) {
+	c.JSON(http.StatusAccepted, Response{
+		Success: true,
+		Data:    data,
+	})
+}
+
 // RespondWithMeta sends a success response with pagination metadata
 func RespondWithMeta(c *gin.Context, data interface{}, meta *MetaResponse) {
 	c.JSON(http.StatusOK, Response{
@@ -57,6 +69,45 @@ func RespondWithMeta(c *gin.Context, data interface{}, meta *MetaResponse) {
 	})
 }
 
+// RespondCacheable sends a success response tagged with an ETag of its content, or
+// 304 Not Modified without a body when the client's If-None-Match already holds it.
+// Polling clients revalidate instead of downloading unchanged data again.
+func RespondCacheable(c *gin.Context, data interface{}, meta *MetaResponse) {
+	body, err := json.Marshal(Response{
+		Success: true,
+		Data:    data,
+		Meta:    meta,
+	})
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	// The digest covers every field, updated_at included, so it changes whenever any
+	// resource in the response does. Weak, as compression changes the bytes on the wire.
+	sum := sha256.Sum256(body)
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "private, no-cache")
+
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
+
+// etagMatches reports whether an If-None-Match header lists an ETag, comparing weakly
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
 // RespondError sends an error response
 func RespondError(c *gin.Context, err error) {
 	if appErr := errors.GetAppError(err); appErr != nil {
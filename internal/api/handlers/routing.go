@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// RoutingHandler handles the rules that assign escalated conversations to agents
+// and the agent profiles they match against
+type RoutingHandler struct {
+	routingService *service.RoutingService
+}
+
+// NewRoutingHandler creates a new routing handler
+func NewRoutingHandler(routingService *service.RoutingService) *RoutingHandler {
+	return &RoutingHandler{routingService: routingService}
+}
+
+// ListRules godoc
+// @Summary      List routing rules
+// @Description  Lists the routing rules of the tenant in evaluation order
+// @Tags         routing
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=[]entity.RoutingRule}
+// @Router       /routing-rules [get]
+func (h *RoutingHandler) ListRules(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	rules, err := h.routingService.ListRules(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, rules)
+}
+
+// CreateRule godoc
+// @Summary      Create routing rule
+// @Description  Creates a rule assigning matching escalated conversations to agents by skills, language, load or round-robin
+// @Tags         routing
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.RoutingRuleInput true "Routing rule"
+// @Success      201 {object} Response{data=entity.RoutingRule}
+// @Failure      400 {object} Response
+// @Router       /routing-rules [post]
+func (h *RoutingHandler) CreateRule(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.RoutingRuleInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	rule, err := h.routingService.CreateRule(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, rule)
+}
+
+// GetRule godoc
+// @Summary      Get routing rule
+// @Tags         routing
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Routing rule ID"
+// @Success      200 {object} Response{data=entity.RoutingRule}
+// @Failure      404 {object} Response
+// @Router       /routing-rules/{id} [get]
+func (h *RoutingHandler) GetRule(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	rule, err := h.routingService.GetRule(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, rule)
+}
+
+// UpdateRule godoc
+// @Summary      Update routing rule
+// @Tags         routing
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Routing rule ID"
+// @Param        request body service.RoutingRuleInput true "Routing rule"
+// @Success      200 {object} Response{data=entity.RoutingRule}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /routing-rules/{id} [put]
+func (h *RoutingHandler) UpdateRule(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.RoutingRuleInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	rule, err := h.routingService.UpdateRule(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, rule)
+}
+
+// DeleteRule godoc
+// @Summary      Delete routing rule
+// @Tags         routing
+// @Security     BearerAuth
+// @Param        id path string true "Routing rule ID"
+// @Success      204
+// @Failure      404 {object} Response
+// @Router       /routing-rules/{id} [delete]
+func (h *RoutingHandler) DeleteRule(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.routingService.DeleteRule(c.Request.Context(), tenantID, c.Param("id")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
+
+// ListProfiles godoc
+// @Summary      List agent routing profiles
+// @Description  Lists the skills, languages and load limits routing rules match agents against
+// @Tags         routing
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=[]entity.AgentRoutingProfile}
+// @Router       /routing-rules/agents [get]
+func (h *RoutingHandler) ListProfiles(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	profiles, err := h.routingService.ListProfiles(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, profiles)
+}
+
+// SaveProfile godoc
+// @Summary      Set agent routing profile
+// @Tags         routing
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        userId path string true "User ID"
+// @Param        request body service.AgentRoutingProfileInput true "Routing profile"
+// @Success      200 {object} Response{data=entity.AgentRoutingProfile}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /routing-rules/agents/{userId} [put]
+func (h *RoutingHandler) SaveProfile(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.AgentRoutingProfileInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	profile, err := h.routingService.SaveProfile(c.Request.Context(), tenantID, c.Param("userId"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, profile)
+}
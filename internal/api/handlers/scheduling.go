@@ -0,0 +1,470 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+)
+
+// SchedulingHandler handles booking calendars, slot offers and bookings
+type SchedulingHandler struct {
+	schedulingService *service.SchedulingService
+}
+
+// NewSchedulingHandler creates a new scheduling handler
+func NewSchedulingHandler(schedulingService *service.SchedulingService) *SchedulingHandler {
+	return &SchedulingHandler{schedulingService: schedulingService}
+}
+
+// RescheduleBookingRequest represents a request to move a booking
+type RescheduleBookingRequest struct {
+	Start time.Time `json:"start" binding:"required"`
+}
+
+// CancelBookingRequest represents a request to cancel a booking
+type CancelBookingRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ListCalendars godoc
+// @Summary      List booking calendars
+// @Tags         scheduling
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=[]entity.BookingCalendar}
+// @Router       /booking-calendars [get]
+func (h *SchedulingHandler) ListCalendars(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	calendars, err := h.schedulingService.ListCalendars(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, calendars)
+}
+
+// CreateCalendar godoc
+// @Summary      Create booking calendar
+// @Description  Creates a calendar contacts can book on, synced with Google Calendar or Microsoft 365 through an OAuth refresh token
+// @Tags         scheduling
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.BookingCalendarInput true "Booking calendar"
+// @Success      201 {object} Response{data=entity.BookingCalendar}
+// @Failure      400 {object} Response
+// @Router       /booking-calendars [post]
+func (h *SchedulingHandler) CreateCalendar(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.BookingCalendarInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	calendar, err := h.schedulingService.CreateCalendar(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, calendar)
+}
+
+// GetCalendar godoc
+// @Summary      Get booking calendar
+// @Tags         scheduling
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Calendar ID"
+// @Success      200 {object} Response{data=entity.BookingCalendar}
+// @Failure      404 {object} Response
+// @Router       /booking-calendars/{id} [get]
+func (h *SchedulingHandler) GetCalendar(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	calendar, err := h.schedulingService.GetCalendar(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, calendar)
+}
+
+// UpdateCalendar godoc
+// @Summary      Update booking calendar
+// @Description  Updates a booking calendar; stored credentials are kept unless a new refresh token is given
+// @Tags         scheduling
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Calendar ID"
+// @Param        request body service.BookingCalendarInput true "Booking calendar"
+// @Success      200 {object} Response{data=entity.BookingCalendar}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /booking-calendars/{id} [put]
+func (h *SchedulingHandler) UpdateCalendar(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.BookingCalendarInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	calendar, err := h.schedulingService.UpdateCalendar(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, calendar)
+}
+
+// DeleteCalendar godoc
+// @Summary      Delete booking calendar
+// @Description  Deletes a booking calendar and its bookings; events already on the external calendar are kept
+// @Tags         scheduling
+// @Security     BearerAuth
+// @Param        id path string true "Calendar ID"
+// @Success      204
+// @Failure      404 {object} Response
+// @Router       /booking-calendars/{id} [delete]
+func (h *SchedulingHandler) DeleteCalendar(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.schedulingService.DeleteCalendar(c.Request.Context(), tenantID, c.Param("id")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
+
+// ListSlots godoc
+// @Summary      List free slots
+// @Description  Returns the free slots of a booking calendar, soonest first
+// @Tags         scheduling
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path  string true  "Calendar ID"
+// @Param        limit query int    false "Maximum slots" default(20)
+// @Success      200 {object} Response{data=[]entity.TimeSlot}
+// @Failure      404 {object} Response
+// @Router       /booking-calendars/{id}/slots [get]
+func (h *SchedulingHandler) ListSlots(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	slots, err := h.schedulingService.AvailableSlots(c.Request.Context(), tenantID, c.Param("id"), limit)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, slots)
+}
+
+// OfferSlots godoc
+// @Summary      Offer slots in a conversation
+// @Description  Sends the free slots of a calendar to a conversation as buttons, a list or numbered options. The slot the contact picks is booked automatically.
+// @Tags         scheduling
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.OfferSlotsInput true "Offer"
+// @Success      201 {object} Response{data=entity.Booking}
+// @Failure      400 {object} Response
+// @Failure      409 {object} Response
+// @Router       /bookings/offers [post]
+func (h *SchedulingHandler) OfferSlots(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.OfferSlotsInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+	req.SenderID = middleware.GetUserID(c)
+
+	booking, err := h.schedulingService.OfferSlots(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, booking)
+}
+
+// ListBookings godoc
+// @Summary      List bookings
+// @Tags         scheduling
+// @Produce      json
+// @Security     BearerAuth
+// @Param        calendar_id     query string false "Calendar ID"
+// @Param        conversation_id query string false "Conversation ID"
+// @Param        status          query string false "pending, confirmed or cancelled"
+// @Param        from            query string false "Bookings starting at or after (RFC 3339)"
+// @Param        to              query string false "Bookings starting before (RFC 3339)"
+// @Param        page            query int    false "Page number" default(1)
+// @Param        page_size       query int    false "Page size" default(20)
+// @Success      200 {object} Response{data=[]entity.Booking}
+// @Router       /bookings [get]
+func (h *SchedulingHandler) ListBookings(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	params := &repository.ListParams{
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	filter := &entity.BookingFilter{
+		CalendarID:     c.Query("calendar_id"),
+		ConversationID: c.Query("conversation_id"),
+		Status:         entity.BookingStatus(c.Query("status")),
+	}
+	if from, err := time.Parse(time.RFC3339, c.Query("from")); err == nil {
+		filter.From = &from
+	}
+	if to, err := time.Parse(time.RFC3339, c.Query("to")); err == nil {
+		filter.To = &to
+	}
+
+	bookings, total, err := h.schedulingService.ListBookings(c.Request.Context(), tenantID, filter, params)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondPaginated(c, bookings, total, params.Page, params.PageSize)
+}
+
+// CreateBooking godoc
+// @Summary      Book a slot
+// @Description  Books a free slot for the contact of a conversation without offering slots first
+// @Tags         scheduling
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.BookInput true "Booking"
+// @Success      201 {object} Response{data=entity.Booking}
+// @Failure      400 {object} Response
+// @Failure      409 {object} Response
+// @Router       /bookings [post]
+func (h *SchedulingHandler) CreateBooking(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.BookInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+	req.CreatedBy = middleware.GetUserID(c)
+
+	booking, err := h.schedulingService.Book(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, booking)
+}
+
+// GetBooking godoc
+// @Summary      Get booking
+// @Tags         scheduling
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Booking ID"
+// @Success      200 {object} Response{data=entity.Booking}
+// @Failure      404 {object} Response
+// @Router       /bookings/{id} [get]
+func (h *SchedulingHandler) GetBooking(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	booking, err := h.schedulingService.GetBooking(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, booking)
+}
+
+// RescheduleBooking godoc
+// @Summary      Reschedule booking
+// @Description  Moves a confirmed booking to another free slot and tells the contact
+// @Tags         scheduling
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Booking ID"
+// @Param        request body RescheduleBookingRequest true "New start"
+// @Success      200 {object} Response{data=entity.Booking}
+// @Failure      404 {object} Response
+// @Failure      409 {object} Response
+// @Router       /bookings/{id}/reschedule [post]
+func (h *SchedulingHandler) RescheduleBooking(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req RescheduleBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	booking, err := h.schedulingService.Reschedule(c.Request.Context(), tenantID, c.Param("id"), req.Start)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, booking)
+}
+
+// CancelBooking godoc
+// @Summary      Cancel booking
+// @Description  Cancels a booking or an unanswered offer; confirmed bookings are removed from the external calendar and the contact is told
+// @Tags         scheduling
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Booking ID"
+// @Param        request body CancelBookingRequest false "Reason"
+// @Success      200 {object} Response{data=entity.Booking}
+// @Failure      404 {object} Response
+// @Failure      409 {object} Response
+// @Router       /bookings/{id}/cancel [post]
+func (h *SchedulingHandler) CancelBooking(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req CancelBookingRequest
+	_ = c.ShouldBindJSON(&req)
+
+	booking, err := h.schedulingService.Cancel(c.Request.Context(), tenantID, c.Param("id"), req.Reason)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, booking)
+}
+
+// GetPublicBooking godoc
+// @Summary      Get booking from a link
+// @Description  Returns the booking of a reschedule or cancel link with the free slots it can move to
+// @Tags         scheduling
+// @Produce      json
+// @Param        token path string true "Booking link token"
+// @Success      200 {object} Response{data=service.PublicBooking}
+// @Failure      404 {object} Response
+// @Router       /public/bookings/{token} [get]
+func (h *SchedulingHandler) GetPublicBooking(c *gin.Context) {
+	booking, err := h.schedulingService.GetPublicBooking(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, booking)
+}
+
+// ReschedulePublicBooking godoc
+// @Summary      Reschedule booking from a link
+// @Tags         scheduling
+// @Accept       json
+// @Produce      json
+// @Param        token path string true "Booking link token"
+// @Param        request body RescheduleBookingRequest true "New start"
+// @Success      200 {object} Response{data=entity.Booking}
+// @Failure      404 {object} Response
+// @Failure      409 {object} Response
+// @Router       /public/bookings/{token}/reschedule [post]
+func (h *SchedulingHandler) ReschedulePublicBooking(c *gin.Context) {
+	var req RescheduleBookingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	booking, err := h.schedulingService.RescheduleByToken(c.Request.Context(), c.Param("token"), req.Start)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, booking)
+}
+
+// CancelPublicBooking godoc
+// @Summary      Cancel booking from a link
+// @Tags         scheduling
+// @Accept       json
+// @Produce      json
+// @Param        token path string true "Booking link token"
+// @Param        request body CancelBookingRequest false "Reason"
+// @Success      200 {object} Response{data=entity.Booking}
+// @Failure      404 {object} Response
+// @Failure      409 {object} Response
+// @Router       /public/bookings/{token}/cancel [post]
+func (h *SchedulingHandler) CancelPublicBooking(c *gin.Context) {
+	var req CancelBookingRequest
+	_ = c.ShouldBindJSON(&req)
+
+	booking, err := h.schedulingService.CancelByToken(c.Request.Context(), c.Param("token"), req.Reason)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, booking)
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// ssoCookie binds an SSO login to the browser that started it
+const (
+	ssoCookie     = "linktor_sso"
+	ssoCookiePath = "/api/v1/auth/sso/"
+)
+
+// SSOHandler handles single sign-on with the identity providers of tenants
+type SSOHandler struct {
+	ssoService *service.SSOService
+}
+
+// NewSSOHandler creates a new SSO handler
+func NewSSOHandler(ssoService *service.SSOService) *SSOHandler {
+	return &SSOHandler{ssoService: ssoService}
+}
+
+// Login godoc
+// @Summary      Start SSO login
+// @Description  Redirects to the login page of the tenant's OIDC or SAML identity provider and sets an HttpOnly cookie the callback requires, so the login completes in this browser only. With redirect_uri, one of the tenant's redirect URLs, the callback returns there with the tokens in the fragment.
+// @Tags         auth
+// @Param        tenant path string true "Tenant slug"
+// @Param        redirect_uri query string false "App page to return to"
+// @Success      302
+// @Failure      400 {object} Response
+// @Failure      403 {object} Response
+// @Failure      404 {object} Response
+// @Router       /auth/sso/{tenant}/login [get]
+func (h *SSOHandler) Login(c *gin.Context) {
+	start, err := h.ssoService.StartLogin(c.Request.Context(), c.Param("tenant"), c.Query("redirect_uri"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	setSSOCookie(c, start.Binding, int(time.Until(start.ExpiresAt).Seconds()))
+	c.Redirect(http.StatusFound, start.AuthURL)
+}
+
+// Callback godoc
+// @Summary      Finish SSO login
+// @Description  Receives the OIDC authorization code or the posted SAMLResponse, provisions the user and logs them in. Each login completes once, with the cookie set when it started; SAML assertions are accepted once. Logins started with a redirect_uri are redirected there with access_token, refresh_token and expires_in in the fragment; failures answer with an error.
+// @Tags         auth
+// @Accept       x-www-form-urlencoded
+// @Produce      json
+// @Param        tenant path string true "Tenant slug"
+// @Success      200 {object} Response{data=LoginResponse}
+// @Success      302
+// @Failure      401 {object} Response
+// @Failure      403 {object} Response
+// @Router       /auth/sso/{tenant}/callback [get]
+// @Router       /auth/sso/{tenant}/callback [post]
+func (h *SSOHandler) Callback(c *gin.Context) {
+	callback := &service.SSOCallback{
+		State:            c.Query("state"),
+		Code:             c.Query("code"),
+		Error:            c.Query("error"),
+		ErrorDescription: c.Query("error_description"),
+	}
+	// SAML providers post the response with the relay state
+	if c.Request.Method == http.MethodPost {
+		callback.SAMLResponse = c.PostForm("SAMLResponse")
+		callback.State = c.PostForm("RelayState")
+	}
+	if binding, err := c.Cookie(ssoCookie); err == nil {
+		callback.Binding = binding
+	}
+	setSSOCookie(c, "", -1)
+
+	result, err := h.ssoService.CompleteLogin(c.Request.Context(), c.Param("tenant"), callback)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	if result.RedirectURL == "" {
+		RespondSuccess(c, toLoginResponse(result.Login))
+		return
+	}
+	// The fragment keeps the tokens out of server logs and Referer headers
+	fragment := url.Values{}
+	fragment.Set("access_token", result.Login.AccessToken)
+	fragment.Set("refresh_token", result.Login.RefreshToken)
+	fragment.Set("expires_in", strconv.FormatInt(result.Login.ExpiresIn, 10))
+	c.Redirect(http.StatusFound, result.RedirectURL+"#"+fragment.Encode())
+}
+
+// setSSOCookie sets the cookie binding a login to the browser, or removes it with a
+// negative maxAge. Over HTTPS it is sent with the cross-site POST of SAML providers.
+func setSSOCookie(c *gin.Context, value string, maxAge int) {
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	cookie := &http.Cookie{
+		Name:     ssoCookie,
+		Value:    value,
+		Path:     ssoCookiePath,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+	if secure {
+		cookie.SameSite = http.SameSiteNoneMode
+	}
+	http.SetCookie(c.Writer, cookie)
+}
+
+// Metadata godoc
+// @Summary      Get SAML metadata
+// @Description  Returns the SAML service provider metadata of a tenant, to register with its identity provider
+// @Tags         auth
+// @Produce      xml
+// @Param        tenant path string true "Tenant slug"
+// @Success      200 {string} string
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /auth/sso/{tenant}/metadata [get]
+func (h *SSOHandler) Metadata(c *gin.Context) {
+	metadata, err := h.ssoService.Metadata(c.Request.Context(), c.Param("tenant"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, "application/samlmetadata+xml", metadata)
+}
+
+// GetConfig godoc
+// @Summary      Get SSO configuration
+// @Description  Returns the tenant's identity provider; the client secret is never returned
+// @Tags         sso
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=entity.SSOConfig}
+// @Failure      404 {object} Response
+// @Router       /sso [get]
+func (h *SSOHandler) GetConfig(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	config, err := h.ssoService.GetConfig(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, config)
+}
+
+// SaveConfig godoc
+// @Summary      Configure SSO
+// @Description  Configures the tenant's OIDC or SAML identity provider, JIT provisioning and group-to-role mapping. An omitted client_secret keeps the current one; it may be a secret:// reference. Owners only.
+// @Tags         sso
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.SaveSSOConfigInput true "Identity provider"
+// @Success      200 {object} Response{data=entity.SSOConfig}
+// @Failure      400 {object} Response
+// @Failure      403 {object} Response
+// @Router       /sso [put]
+func (h *SSOHandler) SaveConfig(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var input service.SaveSSOConfigInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	config, err := h.ssoService.SaveConfig(c.Request.Context(), tenantID, entity.UserRole(middleware.GetUserRole(c)), &input)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, config)
+}
+
+// DeleteConfig godoc
+// @Summary      Remove SSO configuration
+// @Description  Removes the tenant's identity provider; users sign in with passwords again
+// @Tags         sso
+// @Security     BearerAuth
+// @Success      204
+// @Failure      404 {object} Response
+// @Router       /sso [delete]
+func (h *SSOHandler) DeleteConfig(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.ssoService.DeleteConfig(c.Request.Context(), tenantID); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
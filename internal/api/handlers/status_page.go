@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// StatusPageHandler handles tenant status pages and incidents
+type StatusPageHandler struct {
+	statusPageService *service.StatusPageService
+}
+
+// NewStatusPageHandler creates a new status page handler
+func NewStatusPageHandler(statusPageService *service.StatusPageService) *StatusPageHandler {
+	return &StatusPageHandler{statusPageService: statusPageService}
+}
+
+// GetPage godoc
+// @Summary      Get status page settings
+// @Tags         status-page
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=entity.StatusPage}
+// @Failure      404 {object} Response
+// @Router       /status-page [get]
+func (h *StatusPageHandler) GetPage(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	page, err := h.statusPageService.GetPage(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, page)
+}
+
+// SavePage godoc
+// @Summary      Configure status page
+// @Description  Creates or updates the tenant's hosted status page
+// @Tags         status-page
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.SaveStatusPageInput true "Status page settings"
+// @Success      200 {object} Response{data=entity.StatusPage}
+// @Failure      400 {object} Response
+// @Failure      409 {object} Response
+// @Router       /status-page [put]
+func (h *StatusPageHandler) SavePage(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.SaveStatusPageInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	page, err := h.statusPageService.SavePage(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, page)
+}
+
+// PreviewPage godoc
+// @Summary      Preview status page
+// @Description  Renders the tenant's status page whether or not it is published
+// @Tags         status-page
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=entity.PublicStatusPage}
+// @Failure      404 {object} Response
+// @Router       /status-page/preview [get]
+func (h *StatusPageHandler) PreviewPage(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	page, err := h.statusPageService.PreviewPage(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, page)
+}
+
+// GetPublicStatus godoc
+// @Summary      Get public status page
+// @Description  Returns channel statuses, active incidents and scheduled maintenance of a published status page
+// @Tags         status-page
+// @Produce      json
+// @Param        slug path string true "Status page slug"
+// @Success      200 {object} Response{data=entity.PublicStatusPage}
+// @Failure      404 {object} Response
+// @Router       /status/{slug} [get]
+func (h *StatusPageHandler) GetPublicStatus(c *gin.Context) {
+	page, err := h.statusPageService.GetPublicStatus(c.Request.Context(), c.Param("slug"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, page)
+}
+
+// ListIncidents godoc
+// @Summary      List incidents
+// @Tags         status-page
+// @Produce      json
+// @Security     BearerAuth
+// @Param        kind query string false "Filter by kind (incident, maintenance)"
+// @Param        unresolved query bool false "Only unresolved incidents"
+// @Success      200 {object} Response{data=[]entity.Incident}
+// @Router       /incidents [get]
+func (h *StatusPageHandler) ListIncidents(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	filter := &entity.IncidentFilter{
+		Kind:       entity.IncidentKind(c.Query("kind")),
+		Unresolved: c.Query("unresolved") == "true",
+	}
+
+	incidents, err := h.statusPageService.ListIncidents(c.Request.Context(), tenantID, filter)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, incidents)
+}
+
+// CreateIncident godoc
+// @Summary      Declare incident
+// @Description  Declares an incident or schedules a maintenance window; active ones are shown as banners in webchat widgets
+// @Tags         status-page
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.CreateIncidentInput true "Incident"
+// @Success      201 {object} Response{data=entity.Incident}
+// @Failure      400 {object} Response
+// @Router       /incidents [post]
+func (h *StatusPageHandler) CreateIncident(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.CreateIncidentInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	incident, err := h.statusPageService.CreateIncident(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, incident)
+}
+
+// GetIncident godoc
+// @Summary      Get incident
+// @Tags         status-page
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Incident ID"
+// @Success      200 {object} Response{data=entity.Incident}
+// @Failure      404 {object} Response
+// @Router       /incidents/{id} [get]
+func (h *StatusPageHandler) GetIncident(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	incident, err := h.statusPageService.GetIncident(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, incident)
+}
+
+// UpdateIncident godoc
+// @Summary      Post incident update
+// @Description  Adds a timeline update to an incident; the resolved status closes it and clears its banners
+// @Tags         status-page
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Incident ID"
+// @Param        request body service.UpdateIncidentInput true "Update"
+// @Success      200 {object} Response{data=entity.Incident}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Failure      409 {object} Response
+// @Router       /incidents/{id}/updates [post]
+func (h *StatusPageHandler) UpdateIncident(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.UpdateIncidentInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	incident, err := h.statusPageService.UpdateIncident(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, incident)
+}
+
+// DeleteIncident godoc
+// @Summary      Delete incident
+// @Tags         status-page
+// @Security     BearerAuth
+// @Param        id path string true "Incident ID"
+// @Success      204
+// @Failure      404 {object} Response
+// @Router       /incidents/{id} [delete]
+func (h *StatusPageHandler) DeleteIncident(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.statusPageService.DeleteIncident(c.Request.Context(), tenantID, c.Param("id")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
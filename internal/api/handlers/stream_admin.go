@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// StreamAdminHandler handles the system administration of the NATS streams
+type StreamAdminHandler struct {
+	streamAdminService *service.StreamAdminService
+}
+
+// NewStreamAdminHandler creates a new stream admin handler
+func NewStreamAdminHandler(streamAdminService *service.StreamAdminService) *StreamAdminHandler {
+	return &StreamAdminHandler{streamAdminService: streamAdminService}
+}
+
+// List godoc
+// @Summary      List stream settings
+// @Description  Returns the retention window, message limit and replicas of the messages, events, webhooks, ai and jobs streams
+// @Tags         system
+// @Produce      json
+// @Param        X-Admin-Token header string true "System administration token"
+// @Success      200 {object} Response{data=[]entity.StreamSettings}
+// @Failure      401 {object} Response
+// @Router       /system/streams [get]
+func (h *StreamAdminHandler) List(c *gin.Context) {
+	settings, err := h.streamAdminService.List(c.Request.Context())
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, settings)
+}
+
+// Get godoc
+// @Summary      Get stream settings
+// @Tags         system
+// @Produce      json
+// @Param        X-Admin-Token header string true "System administration token"
+// @Param        stream path string true "Stream (messages, events, webhooks, ai, jobs)"
+// @Success      200 {object} Response{data=entity.StreamSettings}
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Router       /system/streams/{stream} [get]
+func (h *StreamAdminHandler) Get(c *gin.Context) {
+	settings, err := h.streamAdminService.Get(c.Request.Context(), c.Param("stream"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, settings)
+}
+
+// Update godoc
+// @Summary      Update stream settings
+// @Description  Changes the retention window (1 to 720 hours), message limit (-1 or 10,000 to 100,000,000) or replicas (1, 3 or 5) of a stream. Lowering a limit deletes messages and needs confirm_data_loss. The settings survive restarts.
+// @Tags         system
+// @Accept       json
+// @Produce      json
+// @Param        X-Admin-Token header string true "System administration token"
+// @Param        stream path string true "Stream (messages, events, webhooks, ai, jobs)"
+// @Param        request body entity.StreamSettingsUpdate true "Settings"
+// @Success      200 {object} Response{data=entity.StreamSettings}
+// @Failure      400 {object} Response
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Router       /system/streams/{stream} [put]
+func (h *StreamAdminHandler) Update(c *gin.Context) {
+	var req entity.StreamSettingsUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	settings, err := h.streamAdminService.Update(c.Request.Context(), c.Param("stream"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, settings)
+}
+
+// Replay godoc
+// @Summary      Replay stream messages
+// @Description  Copies the messages the stream still holds from a time range of at most 24 hours into a replay consumer group, read from the returned durable consumer of the LINKTOR_REPLAY stream. The live pipeline is not affected; acknowledged work queue messages are gone and cannot be replayed.
+// @Tags         system
+// @Accept       json
+// @Produce      json
+// @Param        X-Admin-Token header string true "System administration token"
+// @Param        stream path string true "Stream (messages, events, webhooks, ai, jobs)"
+// @Param        request body entity.StreamReplayRequest true "Replay"
+// @Success      200 {object} Response{data=entity.StreamReplayResult}
+// @Failure      400 {object} Response
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Router       /system/streams/{stream}/replay [post]
+func (h *StreamAdminHandler) Replay(c *gin.Context) {
+	var req entity.StreamReplayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	result, err := h.streamAdminService.Replay(c.Request.Context(), c.Param("stream"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, result)
+}
+
+// DeadLetters godoc
+// @Summary      List dead letters
+// @Description  Lists the messages of a stream whose consumer gave up on them after exhausting their deliveries, oldest first, without payloads. Dead letters are kept for 14 days.
+// @Tags         system
+// @Produce      json
+// @Param        X-Admin-Token header string true "System administration token"
+// @Param        stream path string true "Stream (messages, events, webhooks, ai, jobs)"
+// @Param        after query int false "Only dead letters with a higher sequence, to page through them"
+// @Param        limit query int false "Page size, at most 500" default(50)
+// @Success      200 {object} Response{data=[]entity.DeadLetter}
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Router       /system/streams/{stream}/dead-letters [get]
+func (h *StreamAdminHandler) DeadLetters(c *gin.Context) {
+	after, _ := strconv.ParseUint(c.Query("after"), 10, 64)
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	letters, err := h.streamAdminService.DeadLetters(c.Request.Context(), c.Param("stream"), after, limit)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, letters)
+}
+
+// DeadLetter godoc
+// @Summary      Inspect a dead letter
+// @Description  Returns a dead letter with its headers and payload
+// @Tags         system
+// @Produce      json
+// @Param        X-Admin-Token header string true "System administration token"
+// @Param        stream path string true "Stream (messages, events, webhooks, ai, jobs)"
+// @Param        seq path int true "Dead letter sequence"
+// @Success      200 {object} Response{data=entity.DeadLetter}
+// @Failure      400 {object} Response
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Router       /system/streams/{stream}/dead-letters/{seq} [get]
+func (h *StreamAdminHandler) DeadLetter(c *gin.Context) {
+	seq, ok := deadLetterSequence(c)
+	if !ok {
+		return
+	}
+
+	letter, err := h.streamAdminService.DeadLetter(c.Request.Context(), c.Param("stream"), seq)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, letter)
+}
+
+// ReplayDeadLetter godoc
+// @Summary      Replay a dead letter
+// @Description  Publishes a dead letter again on its original subject, where its consumer handles it with fresh deliveries, and removes it from the dead letters. The replay is audited under replayed_by.
+// @Tags         system
+// @Accept       json
+// @Produce      json
+// @Param        X-Admin-Token header string true "System administration token"
+// @Param        stream path string true "Stream (messages, events, webhooks, ai, jobs)"
+// @Param        seq path int true "Dead letter sequence"
+// @Param        request body entity.DeadLetterReplayRequest true "Replay; sequences and max_messages are ignored"
+// @Success      200 {object} Response{data=entity.DeadLetterReplay}
+// @Failure      400 {object} Response
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Router       /system/streams/{stream}/dead-letters/{seq}/replay [post]
+func (h *StreamAdminHandler) ReplayDeadLetter(c *gin.Context) {
+	seq, ok := deadLetterSequence(c)
+	if !ok {
+		return
+	}
+
+	var req entity.DeadLetterReplayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	replay, err := h.streamAdminService.ReplayDeadLetter(c.Request.Context(), c.Param("stream"), seq, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, replay)
+}
+
+// ReplayDeadLetters godoc
+// @Summary      Replay dead letters in bulk
+// @Description  Replays the listed dead letters of a stream, or its oldest ones up to max_messages (at most 1000), auditing each replay under replayed_by. Dead letters that fail to replay are returned and stay listed.
+// @Tags         system
+// @Accept       json
+// @Produce      json
+// @Param        X-Admin-Token header string true "System administration token"
+// @Param        stream path string true "Stream (messages, events, webhooks, ai, jobs)"
+// @Param        request body entity.DeadLetterReplayRequest true "Replay"
+// @Success      200 {object} Response{data=entity.DeadLetterReplayResult}
+// @Failure      400 {object} Response
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Router       /system/streams/{stream}/dead-letters/replay [post]
+func (h *StreamAdminHandler) ReplayDeadLetters(c *gin.Context) {
+	var req entity.DeadLetterReplayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	result, err := h.streamAdminService.ReplayDeadLetters(c.Request.Context(), c.Param("stream"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, result)
+}
+
+// DeadLetterReplays godoc
+// @Summary      List dead letter replays
+// @Description  Returns the audit of the latest dead letter replays of a stream, newest first
+// @Tags         system
+// @Produce      json
+// @Param        X-Admin-Token header string true "System administration token"
+// @Param        stream path string true "Stream (messages, events, webhooks, ai, jobs)"
+// @Param        limit query int false "Number of replays, at most 500" default(50)
+// @Success      200 {object} Response{data=[]entity.DeadLetterReplay}
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Router       /system/streams/{stream}/dead-letters/replays [get]
+func (h *StreamAdminHandler) DeadLetterReplays(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	replays, err := h.streamAdminService.DeadLetterReplays(c.Request.Context(), c.Param("stream"), limit)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, replays)
+}
+
+// deadLetterSequence parses the dead letter sequence of the path, responding with
+// an error when it is invalid
+func deadLetterSequence(c *gin.Context) (uint64, bool) {
+	seq, err := strconv.ParseUint(c.Param("seq"), 10, 64)
+	if err != nil || seq == 0 {
+		RespondValidationError(c, "Invalid dead letter sequence", nil)
+		return 0, false
+	}
+	return seq, true
+}
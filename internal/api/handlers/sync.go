@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// SyncHandler handles delta sync for agents on mobile connections
+type SyncHandler struct {
+	syncService *service.SyncService
+}
+
+// NewSyncHandler creates a new sync handler
+func NewSyncHandler(syncService *service.SyncService) *SyncHandler {
+	return &SyncHandler{syncService: syncService}
+}
+
+// Changes godoc
+// @Summary      Sync changes
+// @Description  Returns the conversations, messages and agent presence that changed since the cursor of the previous call, oldest first and in compact shapes. Without cursor the first sync returns the conversations changed in the last 30 days and the messages of the last 24 hours. Pass the returned cursor on the next call and call again right away while has_more is set. Responses are gzip compressed for clients that accept it.
+// @Tags         sync
+// @Produce      json
+// @Security     BearerAuth
+// @Param        cursor query string false "Cursor returned by the previous sync"
+// @Param        limit query int false "Maximum conversations and messages each (default 100, max 500)"
+// @Success      200 {object} Response{data=entity.SyncChanges}
+// @Failure      400 {object} Response
+// @Failure      401 {object} Response
+// @Router       /mobile/sync [get]
+func (h *SyncHandler) Changes(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			RespondValidationError(c, "limit must be a number", nil)
+			return
+		}
+		limit = parsed
+	}
+
+	changes, err := h.syncService.Changes(c.Request.Context(), tenantID, c.Query("cursor"), limit)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, changes)
+}
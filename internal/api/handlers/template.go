@@ -33,6 +33,8 @@ type CreateTemplateRequest struct {
 	MessageSendTTLSeconds int                        `json:"message_send_ttl_seconds,omitempty"`
 	AllowCategoryChange   bool                       `json:"allow_category_change,omitempty"`
 	Components            []entity.TemplateComponent `json:"components" binding:"required"`
+	// ComplianceOverride submits content breaking blocking compliance rules (admins only)
+	ComplianceOverride *service.ComplianceOverrideInput `json:"compliance_override,omitempty"`
 }
 
 // List godoc
@@ -156,6 +158,10 @@ func (h *TemplateHandler) Create(c *gin.Context) {
 		MessageSendTTLSeconds: req.MessageSendTTLSeconds,
 		AllowCategoryChange:   req.AllowCategoryChange,
 		Components:            req.Components,
+		ComplianceOverride:    req.ComplianceOverride,
+	}
+	if !authorizeComplianceOverride(c, req.ComplianceOverride) {
+		return
 	}
 
 	template, err := h.templateService.Create(c.Request.Context(), input)
@@ -347,6 +353,8 @@ type EditTemplateRequest struct {
 	Category              string                     `json:"category,omitempty" binding:"omitempty,oneof=AUTHENTICATION MARKETING UTILITY"`
 	Components            []entity.TemplateComponent `json:"components,omitempty"`
 	MessageSendTTLSeconds int                        `json:"message_send_ttl_seconds,omitempty"`
+	// ComplianceOverride submits content breaking blocking compliance rules (admins only)
+	ComplianceOverride *service.ComplianceOverrideInput `json:"compliance_override,omitempty"`
 }
 
 // Edit godoc
@@ -380,12 +388,17 @@ func (h *TemplateHandler) Edit(c *gin.Context) {
 		return
 	}
 
+	if !authorizeComplianceOverride(c, req.ComplianceOverride) {
+		return
+	}
+
 	template, err := h.templateService.Edit(c.Request.Context(), &service.EditTemplateInput{
 		TenantID:              tenantID,
 		ID:                    id,
 		Category:              entity.TemplateCategory(req.Category),
 		Components:            req.Components,
 		MessageSendTTLSeconds: req.MessageSendTTLSeconds,
+		ComplianceOverride:    req.ComplianceOverride,
 	})
 	if err != nil {
 		RespondError(c, err)
@@ -4,11 +4,13 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/msgfy/linktor/internal/api/middleware"
 	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/pkg/errors"
 )
 
 // TenantHandler handles tenant endpoints
 type TenantHandler struct {
 	tenantService *service.TenantService
+	quotaService  *service.QuotaService
 }
 
 // NewTenantHandler creates a new tenant handler
@@ -18,6 +20,11 @@ func NewTenantHandler(tenantService *service.TenantService) *TenantHandler {
 	}
 }
 
+// SetQuotaService enables the quota dashboard
+func (h *TenantHandler) SetQuotaService(quotaService *service.QuotaService) {
+	h.quotaService = quotaService
+}
+
 // Get returns the current tenant
 func (h *TenantHandler) Get(c *gin.Context) {
 	tenantID := middleware.MustGetTenantID(c)
@@ -82,3 +89,24 @@ func (h *TenantHandler) GetUsage(c *gin.Context) {
 
 	RespondSuccess(c, usage)
 }
+
+// GetQuotas returns the current tenant's usage of metered quotas against the plan
+// limits, with exhaustion forecasts and API calls per route
+func (h *TenantHandler) GetQuotas(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+	if h.quotaService == nil {
+		RespondError(c, errors.New(errors.ErrCodeInternal, "Quota metering is not available"))
+		return
+	}
+
+	report, err := h.quotaService.GetReport(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, report)
+}
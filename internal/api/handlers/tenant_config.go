@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// TenantConfigHandler handles configuration-as-code export and import
+type TenantConfigHandler struct {
+	configService *service.TenantConfigService
+	jobService    *service.JobService
+}
+
+// NewTenantConfigHandler creates a new tenant config handler
+func NewTenantConfigHandler(configService *service.TenantConfigService, jobService *service.JobService) *TenantConfigHandler {
+	return &TenantConfigHandler{configService: configService, jobService: jobService}
+}
+
+// Export godoc
+// @Summary      Export tenant configuration
+// @Description  Downloads channels, bots, flows and message hooks as a versionable bundle. Credentials and secret values are never exported.
+// @Tags         config
+// @Produce      json
+// @Produce      application/yaml
+// @Security     BearerAuth
+// @Param        format   query string false "json (default) or yaml"
+// @Param        sections query string false "Comma separated sections: channels, bots, flows, message_hooks"
+// @Success      200 {object} entity.ConfigBundle
+// @Failure      400 {object} Response
+// @Router       /config/export [get]
+func (h *TenantConfigHandler) Export(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	format := c.DefaultQuery("format", service.ConfigFormatJSON)
+	bundle, err := h.configService.Export(c.Request.Context(), tenantID, service.ParseConfigSections(c.Query("sections")))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	data, err := service.EncodeConfigBundle(bundle, format)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	contentType := "application/json"
+	if format == service.ConfigFormatYAML {
+		contentType = "application/yaml"
+	}
+	c.Header("Content-Disposition", "attachment; filename=linktor-config."+format)
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// PreviewImport godoc
+// @Summary      Preview a configuration import
+// @Description  Compares a JSON or YAML bundle with the tenant configuration and lists what applying it would create or update
+// @Tags         config
+// @Accept       json
+// @Accept       application/yaml
+// @Produce      json
+// @Security     BearerAuth
+// @Param        sections query string false "Comma separated sections to import (all sections of the bundle by default)"
+// @Param        bundle body entity.ConfigBundle true "Config bundle"
+// @Success      200 {object} Response{data=entity.ConfigPlan}
+// @Failure      400 {object} Response
+// @Router       /config/import/preview [post]
+func (h *TenantConfigHandler) PreviewImport(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	bundle, ok := h.readBundle(c)
+	if !ok {
+		return
+	}
+
+	plan, err := h.configService.Preview(c.Request.Context(), tenantID, bundle, service.ParseConfigSections(c.Query("sections")))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, plan)
+}
+
+// Import godoc
+// @Summary      Import tenant configuration
+// @Description  Applies a JSON or YAML bundle, matching items by name. Nothing is changed when the preview reports errors. Imported channels stay disabled until credentials are configured. With async=true the bundle is applied by a background job.
+// @Tags         config
+// @Accept       json
+// @Accept       application/yaml
+// @Produce      json
+// @Security     BearerAuth
+// @Param        sections query string false "Comma separated sections to import (all sections of the bundle by default)"
+// @Param        async    query bool   false "Apply in a background job"
+// @Param        bundle body entity.ConfigBundle true "Config bundle"
+// @Success      200 {object} Response{data=entity.ConfigPlan}
+// @Success      202 {object} Response{data=entity.Job}
+// @Failure      400 {object} Response
+// @Router       /config/import [post]
+func (h *TenantConfigHandler) Import(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	bundle, ok := h.readBundle(c)
+	if !ok {
+		return
+	}
+
+	sections := service.ParseConfigSections(c.Query("sections"))
+	if c.Query("async") == "true" {
+		payload, err := service.ImportJobPayload(bundle, sections)
+		if err != nil {
+			RespondError(c, err)
+			return
+		}
+		job, err := h.jobService.Enqueue(c.Request.Context(), tenantID, &service.JobInput{
+			Type:      service.JobTypeConfigImport,
+			Payload:   payload,
+			CreatedBy: middleware.GetUserID(c),
+		})
+		if err != nil {
+			RespondError(c, err)
+			return
+		}
+		RespondAccepted(c, job)
+		return
+	}
+
+	plan, err := h.configService.Apply(c.Request.Context(), tenantID, bundle, sections)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, plan)
+}
+
+func (h *TenantConfigHandler) readBundle(c *gin.Context) (*entity.ConfigBundle, bool) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, service.MaxConfigBundleBytes+1))
+	if err != nil || len(body) == 0 {
+		RespondValidationError(c, "Invalid request body", nil)
+		return nil, false
+	}
+
+	format := service.ConfigFormatJSON
+	if strings.Contains(c.ContentType(), "yaml") {
+		format = service.ConfigFormatYAML
+	}
+
+	bundle, err := service.DecodeConfigBundle(body, format)
+	if err != nil {
+		RespondError(c, err)
+		return nil, false
+	}
+	return bundle, true
+}
@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// Resumable uploads follow the tus protocol, https://tus.io/protocols/resumable-upload
+const (
+	tusVersion = "1.0.0"
+
+	// tusChecksumMismatch is the status tus defines for a chunk failing its checksum
+	tusChecksumMismatch = 460
+
+	uploadOffsetContentType = "application/offset+octet-stream"
+)
+
+// UploadHandler handles resumable upload endpoints
+type UploadHandler struct {
+	uploadService *service.UploadService
+}
+
+// NewUploadHandler creates a new upload handler
+func NewUploadHandler(uploadService *service.UploadService) *UploadHandler {
+	return &UploadHandler{uploadService: uploadService}
+}
+
+// Create godoc
+// @Summary      Start an upload
+// @Description  Starts a resumable upload of Upload-Length bytes. Upload-Metadata carries base64
+// @Description  encoded filename, filetype, checksum (hex SHA-256 of the file), purpose
+// @Description  (campaign, knowledge or media) and channel_type (target of video transcoding).
+// @Tags         uploads
+// @Produce      json
+// @Security     BearerAuth
+// @Param        Upload-Length   header int    true  "Size of the file in bytes"
+// @Param        Upload-Metadata header string false "Comma separated key and base64 value pairs"
+// @Success      201 {object} Response{data=entity.Upload}
+// @Failure      400 {object} Response
+// @Router       /uploads [post]
+func (h *UploadHandler) Create(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" || !checkTusVersion(c) {
+		return
+	}
+
+	size, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+	if err != nil {
+		RespondValidationError(c, "Upload-Length header is required", nil)
+		return
+	}
+	metadata, err := parseUploadMetadata(c.GetHeader("Upload-Metadata"))
+	if err != nil {
+		RespondValidationError(c, "invalid Upload-Metadata header", nil)
+		return
+	}
+
+	upload, err := h.uploadService.Create(c.Request.Context(), tenantID, &service.CreateUploadInput{
+		Filename:    metadata["filename"],
+		MimeType:    metadata["filetype"],
+		Purpose:     entity.UploadPurpose(metadata["purpose"]),
+		ChannelType: entity.ChannelType(metadata["channel_type"]),
+		Size:        size,
+		Checksum:    metadata["checksum"],
+		CreatedBy:   middleware.GetUserID(c),
+	})
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.Header("Location", strings.TrimSuffix(c.Request.URL.Path, "/")+"/"+upload.ID)
+	setUploadHeaders(c, upload)
+	RespondCreated(c, upload)
+}
+
+// Head godoc
+// @Summary      Get the upload offset
+// @Description  Returns the offset to resume the upload from in the Upload-Offset header
+// @Tags         uploads
+// @Security     BearerAuth
+// @Param        id path string true "Upload ID"
+// @Success      200
+// @Failure      404
+// @Router       /uploads/{id} [head]
+func (h *UploadHandler) Head(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" || !checkTusVersion(c) {
+		return
+	}
+
+	upload, err := h.uploadService.Get(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		c.Header("Tus-Resumable", tusVersion)
+		c.Status(errorStatus(err))
+		return
+	}
+
+	setUploadHeaders(c, upload)
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+}
+
+// Patch godoc
+// @Summary      Upload a chunk
+// @Description  Appends the request body at Upload-Offset, which must be the current offset.
+// @Description  Upload-Checksum "sha256 <base64 digest>" verifies the chunk. The upload is
+// @Description  processed once its last byte arrives.
+// @Tags         uploads
+// @Accept       application/offset+octet-stream
+// @Security     BearerAuth
+// @Param        id              path   string true  "Upload ID"
+// @Param        Upload-Offset   header int    true  "Offset of the chunk"
+// @Param        Upload-Checksum header string false "Checksum of the chunk"
+// @Success      204
+// @Failure      409 {object} Response
+// @Failure      460 {object} Response
+// @Router       /uploads/{id} [patch]
+func (h *UploadHandler) Patch(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" || !checkTusVersion(c) {
+		return
+	}
+
+	if c.ContentType() != uploadOffsetContentType {
+		c.Header("Tus-Resumable", tusVersion)
+		c.JSON(http.StatusUnsupportedMediaType, Response{
+			Success: false,
+			Error: &ErrorResponse{
+				Code:    string(errors.ErrCodeBadRequest),
+				Message: "Content-Type must be " + uploadOffsetContentType,
+			},
+		})
+		return
+	}
+	offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		RespondValidationError(c, "Upload-Offset header is required", nil)
+		return
+	}
+	var checksum []byte
+	if header := c.GetHeader("Upload-Checksum"); header != "" {
+		algorithm, digest, _ := strings.Cut(header, " ")
+		if algorithm != "sha256" {
+			RespondValidationError(c, "unsupported checksum algorithm, use sha256", nil)
+			return
+		}
+		if checksum, err = base64.StdEncoding.DecodeString(digest); err != nil {
+			RespondValidationError(c, "invalid Upload-Checksum header", nil)
+			return
+		}
+	}
+
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, service.MaxUploadChunkSize+1))
+	if err != nil {
+		RespondValidationError(c, "failed to read chunk", nil)
+		return
+	}
+
+	upload, err := h.uploadService.WriteChunk(c.Request.Context(), tenantID, c.Param("id"), offset, data, checksum)
+	c.Header("Tus-Resumable", tusVersion)
+	if err == service.ErrUploadChecksumMismatch {
+		c.JSON(tusChecksumMismatch, Response{
+			Success: false,
+			Error: &ErrorResponse{
+				Code:    string(service.ErrUploadChecksumMismatch.Code),
+				Message: service.ErrUploadChecksumMismatch.Message,
+			},
+		})
+		return
+	}
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	setUploadHeaders(c, upload)
+	c.Status(http.StatusNoContent)
+}
+
+// Get godoc
+// @Summary      Get an upload
+// @Description  Returns the progress of an upload and, once processed, its scan result and URL
+// @Tags         uploads
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Upload ID"
+// @Success      200 {object} Response{data=entity.Upload}
+// @Failure      404 {object} Response
+// @Router       /uploads/{id} [get]
+func (h *UploadHandler) Get(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	upload, err := h.uploadService.Get(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, upload)
+}
+
+// Delete godoc
+// @Summary      Terminate an upload
+// @Description  Discards an upload and its file
+// @Tags         uploads
+// @Security     BearerAuth
+// @Param        id path string true "Upload ID"
+// @Success      204
+// @Failure      404 {object} Response
+// @Failure      409 {object} Response
+// @Router       /uploads/{id} [delete]
+func (h *UploadHandler) Delete(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" || !checkTusVersion(c) {
+		return
+	}
+
+	if err := h.uploadService.Terminate(c.Request.Context(), tenantID, c.Param("id")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.Header("Tus-Resumable", tusVersion)
+	c.Status(http.StatusNoContent)
+}
+
+// checkTusVersion refuses requests made for another tus version. Requests without the
+// Tus-Resumable header are served too, for clients that do not speak tus.
+func checkTusVersion(c *gin.Context) bool {
+	version := c.GetHeader("Tus-Resumable")
+	if version == "" || version == tusVersion {
+		return true
+	}
+	c.Header("Tus-Version", tusVersion)
+	c.AbortWithStatus(http.StatusPreconditionFailed)
+	return false
+}
+
+// setUploadHeaders reports the progress of an upload in tus headers
+func setUploadHeaders(c *gin.Context, upload *entity.Upload) {
+	c.Header("Tus-Resumable", tusVersion)
+	c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	c.Header("Upload-Length", strconv.FormatInt(upload.Size, 10))
+	c.Header("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+}
+
+// parseUploadMetadata decodes an Upload-Metadata header: comma separated pairs of a
+// key and a base64 encoded value, which may be omitted
+func parseUploadMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, encoded, _ := strings.Cut(pair, " ")
+		value, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, err
+		}
+		metadata[key] = string(value)
+	}
+	return metadata, nil
+}
+
+// errorStatus returns the HTTP status of an error, for responses without a body
+func errorStatus(err error) int {
+	if appErr := errors.GetAppError(err); appErr != nil {
+		return appErr.StatusCode
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/infrastructure/storage"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockUploadRepository struct {
+	uploads map[string]*entity.Upload
+}
+
+func (m *mockUploadRepository) Create(ctx context.Context, upload *entity.Upload) error {
+	stored := *upload
+	m.uploads[upload.ID] = &stored
+	return nil
+}
+
+func (m *mockUploadRepository) FindByID(ctx context.Context, id string) (*entity.Upload, error) {
+	upload, ok := m.uploads[id]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, "upload not found")
+	}
+	found := *upload
+	found.Chunks = append([]int64(nil), upload.Chunks...)
+	return &found, nil
+}
+
+func (m *mockUploadRepository) Update(ctx context.Context, upload *entity.Upload) error {
+	stored := *upload
+	m.uploads[upload.ID] = &stored
+	return nil
+}
+
+func (m *mockUploadRepository) AppendChunk(ctx context.Context, id string, offset, length int64) (bool, error) {
+	upload, ok := m.uploads[id]
+	if !ok || upload.Offset != offset {
+		return false, nil
+	}
+	upload.Offset += length
+	upload.Chunks = append(upload.Chunks, offset)
+	return true, nil
+}
+
+func (m *mockUploadRepository) ListExpired(ctx context.Context, before time.Time, limit int) ([]*entity.Upload, error) {
+	return nil, nil
+}
+
+func (m *mockUploadRepository) Delete(ctx context.Context, id string) error {
+	delete(m.uploads, id)
+	return nil
+}
+
+func setupUploadRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	repo := &mockUploadRepository{uploads: make(map[string]*entity.Upload)}
+	handler := NewUploadHandler(service.NewUploadService(repo, storage.NewLocalClient(t.TempDir(), "http://files.test"), nil))
+
+	router := gin.New()
+	group := router.Group("/api/v1/uploads", func(c *gin.Context) { c.Set("tenant_id", "tenant-1") })
+	group.POST("", handler.Create)
+	group.HEAD("/:id", handler.Head)
+	group.PATCH("/:id", handler.Patch)
+	group.GET("/:id", handler.Get)
+	group.DELETE("/:id", handler.Delete)
+	return router
+}
+
+func tusRequest(method, path string, body []byte, headers map[string]string) *http.Request {
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	req.Header.Set("Tus-Resumable", tusVersion)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	return req
+}
+
+func TestUploadHandler_TusFlow(t *testing.T) {
+	router := setupUploadRouter(t)
+	encode := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, tusRequest(http.MethodPost, "/api/v1/uploads", nil, map[string]string{
+		"Upload-Length":   "11",
+		"Upload-Metadata": "filename " + encode("notes.txt") + ",purpose " + encode("knowledge") + ",is_confidential",
+	}))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	location := w.Header().Get("Location")
+	require.Contains(t, location, "/api/v1/uploads/")
+	assert.Equal(t, "0", w.Header().Get("Upload-Offset"))
+
+	sum := sha256.Sum256([]byte("hello"))
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, tusRequest(http.MethodPatch, location, []byte("hello"), map[string]string{
+		"Content-Type":    uploadOffsetContentType,
+		"Upload-Offset":   "0",
+		"Upload-Checksum": "sha256 " + base64.StdEncoding.EncodeToString(sum[:]),
+	}))
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	assert.Equal(t, "5", w.Header().Get("Upload-Offset"))
+
+	// Resuming starts from the offset the server reports
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, tusRequest(http.MethodHead, location, nil, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "5", w.Header().Get("Upload-Offset"))
+	assert.Equal(t, "11", w.Header().Get("Upload-Length"))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, tusRequest(http.MethodPatch, location, []byte(" world"), map[string]string{
+		"Content-Type":    uploadOffsetContentType,
+		"Upload-Offset":   "5",
+		"Upload-Checksum": "sha256 " + base64.StdEncoding.EncodeToString(sum[:]),
+	}))
+	assert.Equal(t, tusChecksumMismatch, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, tusRequest(http.MethodPatch, location, []byte(" world"), map[string]string{
+		"Content-Type":  uploadOffsetContentType,
+		"Upload-Offset": "3",
+	}))
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, tusRequest(http.MethodPatch, location, []byte(" world"), map[string]string{
+		"Content-Type":  uploadOffsetContentType,
+		"Upload-Offset": "5",
+	}))
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	assert.Equal(t, "11", w.Header().Get("Upload-Offset"))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, tusRequest(http.MethodGet, location, nil, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"completed"`)
+	assert.Contains(t, w.Body.String(), `"purpose":"knowledge"`)
+}
+
+func TestUploadHandler_RejectsBadRequests(t *testing.T) {
+	router := setupUploadRouter(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, tusRequest(http.MethodPost, "/api/v1/uploads", nil, map[string]string{"Tus-Resumable": "0.2.2", "Upload-Length": "5"}))
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, tusRequest(http.MethodPost, "/api/v1/uploads", nil, nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, tusRequest(http.MethodPost, "/api/v1/uploads", nil, map[string]string{"Upload-Length": "5", "Upload-Metadata": "filename !!!"}))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, tusRequest(http.MethodPatch, "/api/v1/uploads/missing", []byte("x"), map[string]string{"Upload-Offset": "0"}))
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, tusRequest(http.MethodHead, "/api/v1/uploads/missing", nil, nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
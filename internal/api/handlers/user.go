@@ -66,7 +66,7 @@ type CreateUserRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=8"`
 	Name     string `json:"name" binding:"required"`
-	Role     string `json:"role" binding:"required,oneof=agent supervisor admin"`
+	Role     string `json:"role" binding:"required,oneof=agent supervisor admin finance"`
 }
 
 // UpdateUserRequest represents an update user request
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// VariablesHandler handles conversation variables and template previews
+type VariablesHandler struct {
+	variablesService *service.ConversationVariablesService
+}
+
+// NewVariablesHandler creates a new variables handler
+func NewVariablesHandler(variablesService *service.ConversationVariablesService) *VariablesHandler {
+	return &VariablesHandler{variablesService: variablesService}
+}
+
+// GetConversationVariables returns the variables available to templates for a conversation
+// @Summary      Get conversation variables
+// @Description  Returns contact fields, custom attributes, conversation metadata, last intent, CTWA referral and flow data usable as {{variables}}
+// @Tags         conversations
+// @Produce      json
+// @Param        id path string true "Conversation ID"
+// @Success      200 {object} Response
+// @Failure      404 {object} Response
+// @Security     BearerAuth
+// @Router       /conversations/{id}/variables [get]
+func (h *VariablesHandler) GetConversationVariables(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	vars, err := h.variablesService.Resolve(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, vars)
+}
+
+// Preview renders a template against a conversation's variables
+// @Summary      Preview template rendering
+// @Description  Renders {{variable}} placeholders of flow messages, canned responses, campaign content or VRE data and lists unresolved variables
+// @Tags         conversations
+// @Accept       json
+// @Produce      json
+// @Param        request body service.PreviewTemplateInput true "Template to preview"
+// @Success      200 {object} Response{data=service.TemplatePreview}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Security     BearerAuth
+// @Router       /variables/preview [post]
+func (h *VariablesHandler) Preview(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.PreviewTemplateInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	preview, err := h.variablesService.Preview(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, preview)
+}
@@ -11,12 +11,15 @@ import (
 	"github.com/msgfy/linktor/internal/application/service"
 	"github.com/msgfy/linktor/internal/domain/entity"
 	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
 )
 
 // VREHandler handles VRE endpoints
 type VREHandler struct {
 	vreService *service.VREService
 	producer   nats.Publisher
+	variables  *service.ConversationVariablesService
+	jobs       *service.JobService
 }
 
 // NewVREHandler creates a new VRE handler
@@ -27,6 +30,16 @@ func NewVREHandler(vreService *service.VREService, producer nats.Publisher) *VRE
 	}
 }
 
+// SetVariablesService enables filling {{variables}} in render data from a conversation
+func (h *VREHandler) SetVariablesService(variables *service.ConversationVariablesService) {
+	h.variables = variables
+}
+
+// SetJobService enables async rendering through background jobs
+func (h *VREHandler) SetJobService(jobs *service.JobService) {
+	h.jobs = jobs
+}
+
 // RenderRequest represents the API request for rendering
 type RenderRequest struct {
 	TenantID     string                 `json:"tenant_id"`
@@ -41,6 +54,8 @@ type RenderRequest struct {
 	Format       string                 `json:"format,omitempty"`
 	Quality      int                    `json:"quality,omitempty"`
 	Scale        float64                `json:"scale,omitempty"`
+	// ConversationID fills {{variables}} in string data values from the conversation
+	ConversationID string `json:"conversation_id,omitempty"`
 }
 
 // Render handles POST /api/v1/vre/render
@@ -68,6 +83,10 @@ func (h *VREHandler) Render(c *gin.Context) {
 		tenantID = middleware.MustGetTenantID(c)
 	}
 
+	if !h.renderVariables(c, tenantID, &req) {
+		return
+	}
+
 	// Build entity request
 	renderReq := &entity.RenderRequest{
 		TenantID:     tenantID,
@@ -97,6 +116,82 @@ func (h *VREHandler) Render(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// RenderAsyncRequest represents the API request for rendering in the background
+type RenderAsyncRequest struct {
+	RenderRequest
+	// CallbackURL is notified with the image URL once the render finished
+	CallbackURL string `json:"callback_url,omitempty"`
+	// CallbackSecret signs the callback in the X-Linktor-Signature header
+	CallbackSecret string `json:"callback_secret,omitempty"`
+}
+
+// RenderAsync handles POST /api/v1/vre/render-async
+// @Summary Render visual template in the background
+// @Description Queues a render job and returns it at once. Completion is reported by the job API (GET /jobs/{id}, whose result carries image_url), by a POST of a vre.render.completed event to callback_url and by webhook subscriptions to vre.render.completed.
+// @Tags VRE
+// @Accept json
+// @Produce json
+// @Param request body RenderAsyncRequest true "Render request"
+// @Success 202 {object} Response{data=entity.Job}
+// @Failure 400 {object} Response
+// @Security BearerAuth
+// @Router /api/v1/vre/render-async [post]
+func (h *VREHandler) RenderAsync(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+	if h.jobs == nil {
+		RespondError(c, errors.New(errors.ErrCodeBadRequest, "async rendering is not available"))
+		return
+	}
+
+	var req RenderAsyncRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	if !h.renderVariables(c, tenantID, &req.RenderRequest) {
+		return
+	}
+
+	renderReq := &entity.RenderRequest{
+		TenantID:     tenantID,
+		TemplateID:   req.TemplateID,
+		SVG:          req.SVG,
+		Data:         req.Data,
+		Channel:      entity.VREChannelType(req.Channel),
+		Caption:      req.Caption,
+		FollowUpText: req.FollowUpText,
+		Width:        req.Width,
+		Format:       entity.OutputFormat(req.Format),
+		Quality:      req.Quality,
+		Scale:        req.Scale,
+	}
+	var callback *service.VRERenderCallback
+	if req.CallbackURL != "" {
+		callback = &service.VRERenderCallback{URL: req.CallbackURL, Secret: req.CallbackSecret}
+	}
+
+	payload, err := service.VRERenderJobPayload(renderReq, callback)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+	job, err := h.jobs.Enqueue(c.Request.Context(), tenantID, &service.JobInput{
+		Type:      service.JobTypeVRERender,
+		Payload:   payload,
+		CreatedBy: middleware.GetUserID(c),
+	})
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondAccepted(c, job)
+}
+
 // RenderAndSend handles POST /api/v1/vre/render-and-send
 // @Summary Render and send to channel
 // @Description Renders SVG and sends the image directly to a recipient
@@ -127,6 +222,10 @@ func (h *VREHandler) RenderAndSend(c *gin.Context) {
 		tenantID = middleware.MustGetTenantID(c)
 	}
 
+	if !h.renderVariables(c, tenantID, &req) {
+		return
+	}
+
 	// Build entity request
 	renderReq := &entity.RenderRequest{
 		TenantID:     tenantID,
@@ -334,3 +433,19 @@ func (h *VREHandler) InvalidateCache(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Cache invalidated"})
 }
+
+// renderVariables fills {{variables}} in the request data from its conversation.
+// It returns false after responding with an error.
+func (h *VREHandler) renderVariables(c *gin.Context, tenantID string, req *RenderRequest) bool {
+	if req.ConversationID == "" || h.variables == nil {
+		return true
+	}
+
+	data, err := h.variables.RenderData(c.Request.Context(), tenantID, req.ConversationID, req.Data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to resolve conversation variables: " + err.Error()})
+		return false
+	}
+	req.Data = data
+	return true
+}
@@ -0,0 +1,427 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/adapters/wallet"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// applePassAuthScheme is the authorization scheme Apple Wallet authenticates web
+// service requests with
+const applePassAuthScheme = "ApplePass "
+
+// WalletPassHandler handles wallet pass templates, issued passes and the Apple Wallet
+// web service devices use to register for and download pass updates
+type WalletPassHandler struct {
+	walletPassService *service.WalletPassService
+}
+
+// NewWalletPassHandler creates a new wallet pass handler
+func NewWalletPassHandler(walletPassService *service.WalletPassService) *WalletPassHandler {
+	return &WalletPassHandler{walletPassService: walletPassService}
+}
+
+// ListTemplates godoc
+// @Summary      List wallet pass templates
+// @Tags         wallet-passes
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=[]entity.WalletPassTemplate}
+// @Router       /wallet-pass-templates [get]
+func (h *WalletPassHandler) ListTemplates(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	templates, err := h.walletPassService.ListTemplates(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, templates)
+}
+
+// CreateTemplate godoc
+// @Summary      Create wallet pass template
+// @Description  Defines the look, fields and barcode of booking confirmation or loyalty card passes. Field values, the barcode message and labels may use variables such as {{object.reference}}, {{booking.attributes.date}} and {{contact.name}}.
+// @Tags         wallet-passes
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.WalletPassTemplateInput true "Template"
+// @Success      201 {object} Response{data=entity.WalletPassTemplate}
+// @Failure      400 {object} Response
+// @Router       /wallet-pass-templates [post]
+func (h *WalletPassHandler) CreateTemplate(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.WalletPassTemplateInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	template, err := h.walletPassService.CreateTemplate(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, template)
+}
+
+// GetTemplate godoc
+// @Summary      Get wallet pass template
+// @Tags         wallet-passes
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Template ID"
+// @Success      200 {object} Response{data=entity.WalletPassTemplate}
+// @Failure      404 {object} Response
+// @Router       /wallet-pass-templates/{id} [get]
+func (h *WalletPassHandler) GetTemplate(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	template, err := h.walletPassService.GetTemplate(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, template)
+}
+
+// UpdateTemplate godoc
+// @Summary      Update wallet pass template
+// @Description  The kind and object type of a template cannot be changed
+// @Tags         wallet-passes
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Template ID"
+// @Param        request body service.WalletPassTemplateInput true "Template"
+// @Success      200 {object} Response{data=entity.WalletPassTemplate}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /wallet-pass-templates/{id} [put]
+func (h *WalletPassHandler) UpdateTemplate(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.WalletPassTemplateInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	template, err := h.walletPassService.UpdateTemplate(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, template)
+}
+
+// DeleteTemplate godoc
+// @Summary      Delete wallet pass template
+// @Description  Deletes the template and the passes it issued
+// @Tags         wallet-passes
+// @Security     BearerAuth
+// @Param        id path string true "Template ID"
+// @Success      204
+// @Failure      404 {object} Response
+// @Router       /wallet-pass-templates/{id} [delete]
+func (h *WalletPassHandler) DeleteTemplate(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.walletPassService.DeleteTemplate(c.Request.Context(), tenantID, c.Param("id")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
+
+// Issue godoc
+// @Summary      Issue wallet pass
+// @Description  Issues a booking pass for a linked object or a loyalty card for a contact. Issuing again returns the existing pass.
+// @Tags         wallet-passes
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.IssueWalletPassInput true "Pass"
+// @Success      201 {object} Response{data=service.IssuedWalletPass}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /wallet-passes [post]
+func (h *WalletPassHandler) Issue(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.IssueWalletPassInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	pass, err := h.walletPassService.Issue(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, pass)
+}
+
+// Get godoc
+// @Summary      Get wallet pass
+// @Description  Returns a pass with its Apple Wallet and Google Wallet links
+// @Tags         wallet-passes
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Pass ID"
+// @Success      200 {object} Response{data=service.IssuedWalletPass}
+// @Failure      404 {object} Response
+// @Router       /wallet-passes/{id} [get]
+func (h *WalletPassHandler) Get(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	pass, err := h.walletPassService.Get(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, pass)
+}
+
+// Send godoc
+// @Summary      Send wallet pass
+// @Description  Sends a pass to a conversation as add-to-wallet links, or as a .pkpass file
+// @Tags         wallet-passes
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Pass ID"
+// @Param        request body service.SendWalletPassInput true "Conversation and format"
+// @Success      201 {object} Response{data=entity.Message}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /wallet-passes/{id}/send [post]
+func (h *WalletPassHandler) Send(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.SendWalletPassInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+	req.SenderID = middleware.GetUserID(c)
+
+	message, err := h.walletPassService.Send(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, message)
+}
+
+// DownloadApple godoc
+// @Summary      Download Apple Wallet pass
+// @Description  Serves the .pkpass file of a pass. The token of the link sent to the contact authenticates the download.
+// @Tags         wallet-passes
+// @Produce      application/vnd.apple.pkpass
+// @Param        id path string true "Pass ID"
+// @Param        token query string true "Pass token"
+// @Success      200 {file} binary
+// @Failure      401 {object} Response
+// @Failure      404 {object} Response
+// @Router       /wallet/passes/{id}/apple [get]
+func (h *WalletPassHandler) DownloadApple(c *gin.Context) {
+	data, pass, err := h.walletPassService.Download(c.Request.Context(), c.Param("id"), c.Query("token"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=pass.pkpass")
+	c.Header("Last-Modified", pass.UpdatedAt.UTC().Format(http.TimeFormat))
+	c.Data(http.StatusOK, wallet.ApplePassMIMEType, data)
+}
+
+// AppleRegisterDevice godoc
+// @Summary      Register device for pass updates
+// @Description  Apple Wallet web service: registers a device to receive push notifications when a pass changes
+// @Tags         wallet-passes
+// @Accept       json
+// @Param        deviceId path string true "Device library identifier"
+// @Param        passTypeId path string true "Pass type identifier"
+// @Param        serial path string true "Pass serial number"
+// @Success      201
+// @Success      200
+// @Failure      401
+// @Router       /wallet/apple/v1/devices/{deviceId}/registrations/{passTypeId}/{serial} [post]
+func (h *WalletPassHandler) AppleRegisterDevice(c *gin.Context) {
+	var req struct {
+		PushToken string `json:"pushToken"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	created, err := h.walletPassService.RegisterDevice(c.Request.Context(), c.Param("passTypeId"), c.Param("serial"),
+		applePassToken(c), c.Param("deviceId"), req.PushToken)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	if created {
+		c.Status(http.StatusCreated)
+		return
+	}
+	c.Status(http.StatusOK)
+}
+
+// AppleUnregisterDevice godoc
+// @Summary      Unregister device from pass updates
+// @Description  Apple Wallet web service: stops push notifications of a pass to a device
+// @Tags         wallet-passes
+// @Param        deviceId path string true "Device library identifier"
+// @Param        passTypeId path string true "Pass type identifier"
+// @Param        serial path string true "Pass serial number"
+// @Success      200
+// @Failure      401
+// @Router       /wallet/apple/v1/devices/{deviceId}/registrations/{passTypeId}/{serial} [delete]
+func (h *WalletPassHandler) AppleUnregisterDevice(c *gin.Context) {
+	err := h.walletPassService.UnregisterDevice(c.Request.Context(), c.Param("passTypeId"), c.Param("serial"),
+		applePassToken(c), c.Param("deviceId"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// AppleUpdatedSerials godoc
+// @Summary      List updated passes of a device
+// @Description  Apple Wallet web service: lists the serial numbers of the passes on a device that changed since the given tag
+// @Tags         wallet-passes
+// @Produce      json
+// @Param        deviceId path string true "Device library identifier"
+// @Param        passTypeId path string true "Pass type identifier"
+// @Param        passesUpdatedSince query string false "Tag returned by the previous request"
+// @Success      200
+// @Success      204
+// @Router       /wallet/apple/v1/devices/{deviceId}/registrations/{passTypeId} [get]
+func (h *WalletPassHandler) AppleUpdatedSerials(c *gin.Context) {
+	var since time.Time
+	if tag := c.Query("passesUpdatedSince"); tag != "" {
+		since, _ = time.Parse(time.RFC3339Nano, tag)
+	}
+
+	serials, lastUpdated, err := h.walletPassService.UpdatedSerials(c.Request.Context(), c.Param("deviceId"), c.Param("passTypeId"), since)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+	if len(serials) == 0 {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"serialNumbers": serials,
+		"lastUpdated":   lastUpdated.UTC().Format(time.RFC3339Nano),
+	})
+}
+
+// AppleLatestPass godoc
+// @Summary      Get latest version of a pass
+// @Description  Apple Wallet web service: serves the current .pkpass of a pass, or 304 when it did not change since If-Modified-Since
+// @Tags         wallet-passes
+// @Produce      application/vnd.apple.pkpass
+// @Param        passTypeId path string true "Pass type identifier"
+// @Param        serial path string true "Pass serial number"
+// @Success      200 {file} binary
+// @Success      304
+// @Failure      401
+// @Router       /wallet/apple/v1/passes/{passTypeId}/{serial} [get]
+func (h *WalletPassHandler) AppleLatestPass(c *gin.Context) {
+	data, pass, err := h.walletPassService.LatestApplePass(c.Request.Context(), c.Param("passTypeId"), c.Param("serial"), applePassToken(c))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	// HTTP dates have second precision
+	updatedAt := pass.UpdatedAt.UTC().Truncate(time.Second)
+	if since, err := http.ParseTime(c.GetHeader("If-Modified-Since")); err == nil && !updatedAt.After(since) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Header("Last-Modified", updatedAt.Format(http.TimeFormat))
+	c.Data(http.StatusOK, wallet.ApplePassMIMEType, data)
+}
+
+// AppleLog godoc
+// @Summary      Log Apple Wallet errors
+// @Description  Apple Wallet web service: receives error messages from devices
+// @Tags         wallet-passes
+// @Accept       json
+// @Success      200
+// @Router       /wallet/apple/v1/log [post]
+func (h *WalletPassHandler) AppleLog(c *gin.Context) {
+	var req struct {
+		Logs []string `json:"logs"`
+	}
+	if err := c.ShouldBindJSON(&req); err == nil {
+		for _, message := range req.Logs {
+			logger.Warn("Apple Wallet reported an error", zap.String("message", message))
+		}
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// applePassToken returns the pass token of an Apple Wallet web service request
+func applePassToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if !strings.HasPrefix(header, applePassAuthScheme) {
+		return ""
+	}
+	return strings.TrimPrefix(header, applePassAuthScheme)
+}
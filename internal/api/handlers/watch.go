@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// WatchHandler handles the watch lists of conversations and contacts
+type WatchHandler struct {
+	watchService *service.WatchService
+}
+
+// NewWatchHandler creates a new watch handler
+func NewWatchHandler(watchService *service.WatchService) *WatchHandler {
+	return &WatchHandler{watchService: watchService}
+}
+
+// List godoc
+// @Summary      List watches
+// @Description  Lists the conversations and contacts a user watches. Supervisors can list the watches of other users.
+// @Tags         watches
+// @Produce      json
+// @Security     BearerAuth
+// @Param        user_id query string false "User ID, defaults to the current user"
+// @Success      200 {object} Response{data=[]entity.Watch}
+// @Failure      403 {object} Response
+// @Router       /watches [get]
+func (h *WatchHandler) List(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	userID, ok := watchUserID(c, c.Query("user_id"))
+	if !ok {
+		return
+	}
+
+	watches, err := h.watchService.ListForUser(c.Request.Context(), tenantID, userID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, watches)
+}
+
+// Create godoc
+// @Summary      Watch conversation or contact
+// @Description  Subscribes a user to the activity of a conversation, or of every conversation of a contact, without assigning it. Supervisors can add watches for other users.
+// @Tags         watches
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.WatchInput true "Watch"
+// @Success      201 {object} Response{data=entity.Watch}
+// @Failure      400 {object} Response
+// @Failure      403 {object} Response
+// @Router       /watches [post]
+func (h *WatchHandler) Create(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.WatchInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	userID, ok := watchUserID(c, req.UserID)
+	if !ok {
+		return
+	}
+	req.UserID = userID
+
+	watch, err := h.watchService.Watch(c.Request.Context(), tenantID, middleware.GetUserID(c), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, watch)
+}
+
+// Delete godoc
+// @Summary      Stop watching
+// @Tags         watches
+// @Security     BearerAuth
+// @Param        targetType path string true "conversation or contact"
+// @Param        targetId path string true "Conversation or contact ID"
+// @Param        user_id query string false "User ID, defaults to the current user"
+// @Success      204
+// @Failure      403 {object} Response
+// @Failure      404 {object} Response
+// @Router       /watches/{targetType}/{targetId} [delete]
+func (h *WatchHandler) Delete(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	userID, ok := watchUserID(c, c.Query("user_id"))
+	if !ok {
+		return
+	}
+
+	targetType := entity.WatchTargetType(c.Param("targetType"))
+	if err := h.watchService.Unwatch(c.Request.Context(), tenantID, userID, targetType, c.Param("targetId")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
+
+// ListConversationWatchers godoc
+// @Summary      List conversation watchers
+// @Tags         watches
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Conversation ID"
+// @Success      200 {object} Response{data=[]entity.Watch}
+// @Failure      404 {object} Response
+// @Router       /conversations/{id}/watchers [get]
+func (h *WatchHandler) ListConversationWatchers(c *gin.Context) {
+	h.listWatchers(c, entity.WatchTargetConversation)
+}
+
+// ListContactWatchers godoc
+// @Summary      List contact watchers
+// @Tags         watches
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Contact ID"
+// @Success      200 {object} Response{data=[]entity.Watch}
+// @Failure      404 {object} Response
+// @Router       /contacts/{id}/watchers [get]
+func (h *WatchHandler) ListContactWatchers(c *gin.Context) {
+	h.listWatchers(c, entity.WatchTargetContact)
+}
+
+func (h *WatchHandler) listWatchers(c *gin.Context, targetType entity.WatchTargetType) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	watches, err := h.watchService.ListWatchers(c.Request.Context(), tenantID, targetType, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, watches)
+}
+
+// watchUserID resolves whose watch list a request manages. Agents only manage their
+// own; supervisors, admins and owners may manage anyone's in the tenant.
+func watchUserID(c *gin.Context, requested string) (string, bool) {
+	currentUserID := middleware.GetUserID(c)
+	if requested == "" || requested == currentUserID {
+		return currentUserID, true
+	}
+
+	switch middleware.GetUserRole(c) {
+	case "supervisor", "admin", "owner":
+		return requested, true
+	}
+	RespondForbidden(c, "Only supervisors can manage the watches of other users")
+	return "", false
+}
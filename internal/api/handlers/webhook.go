@@ -5,21 +5,26 @@ import (
 	"context"
 	"crypto/hmac"
 	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/adapters/custom"
 	"github.com/msgfy/linktor/internal/adapters/email"
 	"github.com/msgfy/linktor/internal/adapters/facebook"
 	"github.com/msgfy/linktor/internal/adapters/instagram"
 	"github.com/msgfy/linktor/internal/adapters/rcs"
 	"github.com/msgfy/linktor/internal/adapters/sms"
+	"github.com/msgfy/linktor/internal/adapters/teams"
 	whatsappofficial "github.com/msgfy/linktor/internal/adapters/whatsapp_official"
 	appservice "github.com/msgfy/linktor/internal/application/service"
 	"github.com/msgfy/linktor/internal/domain/entity"
@@ -30,9 +35,13 @@ import (
 
 // WebhookHandler handles incoming webhooks from external channels
 type WebhookHandler struct {
-	channelRepo repository.ChannelRepository
-	producer    nats.Publisher
-	templateSvc *appservice.TemplateService
+	channelRepo    repository.ChannelRepository
+	producer       nats.Publisher
+	templateSvc    *appservice.TemplateService
+	phoneNumberSvc *appservice.WhatsAppPhoneNumberService
+	qualityGuard   *appservice.WhatsAppQualityGuardService
+	costSvc        *appservice.WhatsAppCostService
+	labelSync      *appservice.CoexistenceLabelSyncService
 }
 
 // NewWebhookHandler creates a new webhook handler
@@ -44,6 +53,27 @@ func NewWebhookHandler(channelRepo repository.ChannelRepository, producer nats.P
 	}
 }
 
+// SetPhoneNumberService enables routing of WhatsApp webhooks by phone_number_id
+// for channels that manage several numbers of the same WABA.
+func (h *WebhookHandler) SetPhoneNumberService(svc *appservice.WhatsAppPhoneNumberService) {
+	h.phoneNumberSvc = svc
+}
+
+// SetQualityGuard enables pausing marketing sends when Meta reports quality problems
+func (h *WebhookHandler) SetQualityGuard(guard *appservice.WhatsAppQualityGuardService) {
+	h.qualityGuard = guard
+}
+
+// SetCostService enables recording the pricing info of WhatsApp status webhooks
+func (h *WebhookHandler) SetCostService(svc *appservice.WhatsAppCostService) {
+	h.costSvc = svc
+}
+
+// SetLabelSyncService enables syncing WhatsApp Business App labels of coexistence channels to contact tags
+func (h *WebhookHandler) SetLabelSyncService(svc *appservice.CoexistenceLabelSyncService) {
+	h.labelSync = svc
+}
+
 // WhatsAppWebhook handles WhatsApp Cloud API webhooks
 func (h *WebhookHandler) WhatsAppWebhook(c *gin.Context) {
 	channelID := c.Param("channelId")
@@ -96,6 +126,8 @@ func (h *WebhookHandler) WhatsAppWebhook(c *gin.Context) {
 	if err := json.Unmarshal(rawBody, &officialPayload); err == nil {
 		h.processWhatsAppTemplateWebhooks(c.Request.Context(), &officialPayload)
 		h.processWhatsAppChannelWebhooks(c.Request.Context(), channel, &officialPayload)
+		h.processWhatsAppPricing(c.Request.Context(), channel, &officialPayload)
+		h.processWhatsAppStateSync(c.Request.Context(), channel, &officialPayload)
 	}
 
 	// Process messages
@@ -106,15 +138,18 @@ func (h *WebhookHandler) WhatsAppWebhook(c *gin.Context) {
 			}
 
 			if change.Field == "messages" {
+				phoneNumberID := change.Value.Metadata["phone_number_id"]
+				target := h.resolveWhatsAppChannel(c.Request.Context(), channel, phoneNumberID)
+
 				for _, msg := range change.Value.Messages {
-					if err := h.processWhatsAppMessage(c.Request.Context(), channel, msg, change.Value.Contacts); err != nil {
+					if err := h.processWhatsAppMessage(c.Request.Context(), target, msg, change.Value.Contacts, phoneNumberID); err != nil {
 						// Log error but continue processing
 					}
 				}
 
 				// Process status updates
 				for _, status := range change.Value.Statuses {
-					h.processWhatsAppStatus(c.Request.Context(), channel, status)
+					h.processWhatsAppStatus(c.Request.Context(), target, status)
 				}
 			}
 		}
@@ -123,6 +158,20 @@ func (h *WebhookHandler) WhatsAppWebhook(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
 
+// resolveWhatsAppChannel returns the channel owning phoneNumberID, falling back to the
+// channel the webhook was delivered to. Numbers of other tenants are never resolved.
+func (h *WebhookHandler) resolveWhatsAppChannel(ctx context.Context, channel *entity.Channel, phoneNumberID string) *entity.Channel {
+	if h.phoneNumberSvc == nil || phoneNumberID == "" || channel.Type != entity.ChannelTypeWhatsAppOfficial {
+		return channel
+	}
+
+	resolved, err := h.phoneNumberSvc.ResolveChannel(ctx, phoneNumberID)
+	if err != nil || resolved == nil || resolved.TenantID != channel.TenantID {
+		return channel
+	}
+	return resolved
+}
+
 func (h *WebhookHandler) updateCoexistenceLastEcho(ctx context.Context, channel *entity.Channel) {
 	channel.UpdateLastEchoAt()
 	if channel.CoexistenceStatus == entity.CoexistenceStatusWarning ||
@@ -154,6 +203,7 @@ func (h *WebhookHandler) processWhatsAppChannelWebhooks(ctx context.Context, cha
 			continue
 		}
 
+		previousTier := channel.Config["messaging_limit_tier"]
 		channel.Config["quality_rating_event"] = event.Event
 		channel.Config["messaging_limit_tier"] = event.CurrentLimit
 		if event.PhoneNumber != "" {
@@ -168,11 +218,69 @@ func (h *WebhookHandler) processWhatsAppChannelWebhooks(ctx context.Context, cha
 				channel.Config["quality_rating"] = "GREEN"
 			}
 		}
+
+		if h.qualityGuard != nil {
+			h.qualityGuard.EvaluatePhoneQuality(ctx, channel, event.Event, previousTier, event.CurrentLimit)
+		}
 	}
 
 	_ = h.channelRepo.Update(ctx, channel)
 }
 
+// processWhatsAppStateSync applies Business App contact and label changes of coexistence channels
+func (h *WebhookHandler) processWhatsAppStateSync(ctx context.Context, channel *entity.Channel, payload *whatsappofficial.WebhookPayload) {
+	if h.labelSync == nil || channel == nil || !channel.IsCoexistenceChannel() {
+		return
+	}
+
+	changes := whatsappofficial.NewWebhookProcessor(nil).ExtractStateSync(payload)
+	if len(changes) == 0 {
+		return
+	}
+	_ = h.labelSync.ApplyStateSync(ctx, channel, changes)
+}
+
+// processWhatsAppPricing records the conversation category and billability carried by status updates
+func (h *WebhookHandler) processWhatsAppPricing(ctx context.Context, channel *entity.Channel, payload *whatsappofficial.WebhookPayload) {
+	if h.costSvc == nil || channel == nil || channel.Type != entity.ChannelTypeWhatsAppOfficial {
+		return
+	}
+
+	for _, entry := range payload.Entry {
+		for _, change := range entry.Changes {
+			if change.Field != "messages" {
+				continue
+			}
+
+			var target *entity.Channel
+			for _, status := range change.Value.Statuses {
+				if status.Pricing == nil {
+					continue
+				}
+				if target == nil {
+					target = h.resolveWhatsAppChannel(ctx, channel, change.Value.Metadata.PhoneNumberID)
+				}
+
+				input := &appservice.RecordPricingInput{
+					Channel:           target,
+					ExternalMessageID: status.ID,
+					Billable:          status.Pricing.Billable,
+					PricingModel:      status.Pricing.PricingModel,
+					Category:          status.Pricing.Category,
+				}
+				if status.Conversation != nil {
+					input.WAConversationID = status.Conversation.ID
+					input.ExpirationTimestamp = status.Conversation.ExpirationTimestamp
+					if status.Conversation.Origin != nil {
+						input.Origin = status.Conversation.Origin.Type
+					}
+				}
+				_ = h.costSvc.RecordPricing(ctx, input)
+			}
+		}
+	}
+}
+
 // TelegramWebhook handles Telegram Bot API webhooks
 func (h *WebhookHandler) TelegramWebhook(c *gin.Context) {
 	channelID := c.Param("channelId")
@@ -183,6 +291,15 @@ func (h *WebhookHandler) TelegramWebhook(c *gin.Context) {
 		return
 	}
 
+	// Telegram echoes the secret token registered with setWebhook
+	if secret := channel.Credentials["webhook_secret"]; secret != "" {
+		token := c.GetHeader("X-Telegram-Bot-Api-Secret-Token")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid secret token"})
+			return
+		}
+	}
+
 	var update TelegramUpdate
 	if err := c.ShouldBindJSON(&update); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
@@ -198,7 +315,9 @@ func (h *WebhookHandler) TelegramWebhook(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
 
-// GenericWebhook handles webhooks from generic/custom channels
+// GenericWebhook handles webhooks from generic/custom channels. Payloads follow
+// GenericWebhookPayload unless the channel maps its own fields with inbound_*_path
+// settings; channels with an inbound_secret require a valid X-Linktor-Signature.
 func (h *WebhookHandler) GenericWebhook(c *gin.Context) {
 	channelID := c.Param("channelId")
 
@@ -208,8 +327,28 @@ func (h *WebhookHandler) GenericWebhook(c *gin.Context) {
 		return
 	}
 
-	var payload GenericWebhookPayload
-	if err := c.ShouldBindJSON(&payload); err != nil {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read body"})
+		return
+	}
+
+	values := make(map[string]string, len(channel.Config)+len(channel.Credentials))
+	for k, v := range channel.Config {
+		values[k] = v
+	}
+	for k, v := range channel.Credentials {
+		values[k] = v
+	}
+	config := custom.InboundConfig(values)
+
+	if err := config.VerifySignature(body, c.GetHeader(custom.SignatureHeader)); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid signature"})
+		return
+	}
+
+	payload, err := config.ParseInbound(body)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
 		return
 	}
@@ -232,6 +371,18 @@ func (h *WebhookHandler) GenericWebhook(c *gin.Context) {
 	if payload.SenderName != "" {
 		inbound.Metadata["sender_name"] = payload.SenderName
 	}
+	if payload.MediaURL != "" {
+		attachmentType := payload.ContentType
+		switch attachmentType {
+		case "image", "video", "audio", "document":
+		default:
+			attachmentType = "document"
+		}
+		inbound.Attachments = append(inbound.Attachments, nats.AttachmentData{
+			Type: attachmentType,
+			URL:  payload.MediaURL,
+		})
+	}
 
 	if err := h.producer.PublishInbound(c.Request.Context(), inbound); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process message"})
@@ -358,6 +509,7 @@ func (h *WebhookHandler) TwilioWebhook(c *gin.Context) {
 			ExternalID:   payload.MessageSID,
 			ChannelType:  "sms",
 			Status:       status,
+			ErrorCode:    payload.ErrorCode,
 			ErrorMessage: payload.ErrorMessage,
 			Timestamp:    time.Now(),
 		}
@@ -515,6 +667,7 @@ func (h *WebhookHandler) StatusCallback(c *gin.Context) {
 		ExternalID:   payload.ExternalID,
 		ChannelType:  string(channel.Type),
 		Status:       payload.Status,
+		ErrorCode:    payload.ErrorCode,
 		ErrorMessage: payload.ErrorMessage,
 		Timestamp:    time.Now(),
 	}
@@ -601,7 +754,7 @@ func (h *WebhookHandler) processWhatsAppTemplateWebhooks(ctx context.Context, pa
 	}
 }
 
-func (h *WebhookHandler) processWhatsAppMessage(ctx context.Context, channel *entity.Channel, msg WhatsAppMessage, contacts []WhatsAppContact) error {
+func (h *WebhookHandler) processWhatsAppMessage(ctx context.Context, channel *entity.Channel, msg WhatsAppMessage, contacts []WhatsAppContact, phoneNumberID string) error {
 	// Find sender info
 	senderName := ""
 	senderPhone := msg.From
@@ -621,6 +774,9 @@ func (h *WebhookHandler) processWhatsAppMessage(ctx context.Context, channel *en
 		"sender_name": senderName,
 		"sender_id":   msg.From,
 	}
+	if phoneNumberID != "" {
+		metadata["phone_number_id"] = phoneNumberID
+	}
 
 	switch msg.Type {
 	case "text":
@@ -683,6 +839,10 @@ func (h *WebhookHandler) processWhatsAppMessage(ctx context.Context, channel *en
 		if msg.Location.Address != "" {
 			metadata["location_address"] = msg.Location.Address
 		}
+		address := whatsappofficial.LocationContactAddress(msg.Location.Latitude, msg.Location.Longitude, msg.Location.Name, msg.Location.Address)
+		if data, err := json.Marshal(address); err == nil {
+			metadata["contact_address"] = string(data)
+		}
 	case "contacts":
 		contentType = "contact"
 		if len(msg.Contacts) > 0 {
@@ -699,6 +859,17 @@ func (h *WebhookHandler) processWhatsAppMessage(ctx context.Context, channel *en
 			} else if msg.Interactive.ListReply != nil {
 				content = msg.Interactive.ListReply.Title
 				metadata["list_id"] = msg.Interactive.ListReply.ID
+			} else if msg.Interactive.NfmReply != nil && msg.Interactive.NfmReply.Name == whatsappofficial.InteractiveTypeAddress {
+				content = msg.Interactive.NfmReply.Body
+				metadata["is_address_response"] = "true"
+				if response, err := whatsappofficial.ParseAddressResponse(msg.Interactive.NfmReply.ResponseJSON); err == nil {
+					if data, err := json.Marshal(response.Values.ContactAddress()); err == nil {
+						metadata["contact_address"] = string(data)
+					}
+					if response.SavedAddressID != "" {
+						metadata["saved_address_id"] = response.SavedAddressID
+					}
+				}
 			}
 		}
 	case "button":
@@ -760,8 +931,9 @@ func (h *WebhookHandler) processWhatsAppStatus(ctx context.Context, channel *ent
 		return
 	}
 
-	errorMessage := ""
+	errorCode, errorMessage := "", ""
 	if len(status.Errors) > 0 {
+		errorCode = strconv.Itoa(status.Errors[0].Code)
 		errorMessage = fmt.Sprintf("[%d] %s: %s", status.Errors[0].Code, status.Errors[0].Title, status.Errors[0].Message)
 	}
 
@@ -772,6 +944,7 @@ func (h *WebhookHandler) processWhatsAppStatus(ctx context.Context, channel *ent
 		ExternalID:   status.ID,
 		ChannelType:  channelType,
 		Status:       mappedStatus,
+		ErrorCode:    errorCode,
 		ErrorMessage: errorMessage,
 		Timestamp:    time.Now(),
 	}
@@ -1137,6 +1310,147 @@ func (h *WebhookHandler) processRCSStatus(ctx context.Context, channel *entity.C
 	}
 }
 
+// TeamsWebhook handles Bot Framework activities of a Microsoft Teams bot. Requests
+// must carry a Bot Framework token issued for the channel's bot.
+func (h *WebhookHandler) TeamsWebhook(c *gin.Context) {
+	channelID := c.Param("channelId")
+
+	// Get channel
+	channel, err := h.channelRepo.FindByID(c.Request.Context(), channelID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "channel not found"})
+		return
+	}
+
+	// Read body
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read body"})
+		return
+	}
+
+	activity, err := teams.ParseActivity(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+
+	client, err := teams.NewClient(&teams.Config{
+		AppID:             channel.Config["app_id"],
+		AppPassword:       channel.Credentials["app_password"],
+		AppTenantID:       channel.Config["app_tenant_id"],
+		ServiceURL:        channel.Config["service_url"],
+		OpenIDMetadataURL: channel.Config["openid_metadata_url"],
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create client"})
+		return
+	}
+	if err := client.ValidateRequest(c.Request.Context(), c.GetHeader("Authorization"), activity.ServiceURL); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+		return
+	}
+
+	if activity.Type == teams.ActivityTypeMessage {
+		if err := h.processTeamsMessage(c.Request.Context(), channel, activity); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to process message"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// processTeamsMessage publishes a message a user sent to the bot. The sender is
+// identified by the Teams conversation, which is also where replies are sent.
+func (h *WebhookHandler) processTeamsMessage(ctx context.Context, channel *entity.Channel, activity *teams.Activity) error {
+	if activity.Conversation == nil || activity.From == nil {
+		return nil
+	}
+
+	contentType := "text"
+	content := activity.PlainText()
+	var attachments []nats.AttachmentData
+
+	metadata := map[string]string{
+		"sender_id":         activity.Conversation.ID,
+		"sender_name":       activity.From.Name,
+		"teams_user_id":     activity.From.ID,
+		"conversation_type": activity.Conversation.ConversationType,
+		"service_url":       activity.ServiceURL,
+	}
+	if activity.From.AADObjectID != "" {
+		metadata["aad_object_id"] = activity.From.AADObjectID
+	}
+	if activity.Conversation.TenantID != "" {
+		metadata["teams_tenant_id"] = activity.Conversation.TenantID
+	}
+
+	if submit := activity.CardSubmit(); submit != nil {
+		contentType = "interactive"
+		if submit.ButtonID != "" {
+			metadata["interactive_type"] = "button_reply"
+			metadata["button_id"] = submit.ButtonID
+		} else {
+			metadata["interactive_type"] = "list_reply"
+			metadata["list_id"] = submit.ListID
+		}
+		if content == "" {
+			content = submit.Title
+		}
+		if content == "" {
+			content = submit.ListID
+		}
+	} else if files := activity.FileAttachments(); len(files) > 0 {
+		for _, file := range files {
+			fileType := "document"
+			switch {
+			case strings.HasPrefix(file.ContentType, "image/"):
+				fileType = "image"
+			case strings.HasPrefix(file.ContentType, "video/"):
+				fileType = "video"
+			case strings.HasPrefix(file.ContentType, "audio/"):
+				fileType = "audio"
+			}
+			attachments = append(attachments, nats.AttachmentData{
+				Type:     fileType,
+				URL:      file.ContentURL,
+				Filename: file.Name,
+				MimeType: file.ContentType,
+			})
+		}
+		contentType = attachments[0].Type
+	}
+
+	if content == "" && len(attachments) == 0 {
+		return nil
+	}
+
+	timestamp := time.Now()
+	if activity.Timestamp != nil {
+		timestamp = *activity.Timestamp
+	}
+
+	if h.producer != nil {
+		inboundMsg := &nats.InboundMessage{
+			ID:          uuid.New().String(),
+			TenantID:    channel.TenantID,
+			ChannelID:   channel.ID,
+			ChannelType: string(entity.ChannelTypeTeams),
+			ExternalID:  activity.ID,
+			ContentType: contentType,
+			Content:     content,
+			Metadata:    metadata,
+			Attachments: attachments,
+			Timestamp:   timestamp,
+		}
+
+		return h.producer.PublishInbound(ctx, inboundMsg)
+	}
+
+	return nil
+}
+
 // EmailWebhook handles Email webhooks from various providers (SendGrid, Mailgun, SES, Postmark)
 func (h *WebhookHandler) EmailWebhook(c *gin.Context) {
 	channelID := c.Param("channelId")
@@ -1304,12 +1618,20 @@ func (h *WebhookHandler) processEmailStatus(ctx context.Context, channel *entity
 		status = "pending"
 	}
 
+	// Spam complaints carry no SMTP code; they are reported as a complaint so the
+	// recipient is opted out like an unsubscribe
+	errorCode := report.ErrorCode
+	if report.Status == email.StatusSpam && errorCode == "" {
+		errorCode = "complaint"
+	}
+
 	if h.producer != nil {
 		h.producer.PublishStatusUpdate(ctx, &nats.StatusUpdate{
 			MessageID:    report.MessageID,
 			ExternalID:   report.ExternalID,
 			ChannelType:  "email",
 			Status:       status,
+			ErrorCode:    errorCode,
 			ErrorMessage: report.ErrorMessage,
 			Timestamp:    report.Timestamp,
 		})
@@ -1416,6 +1738,7 @@ type WhatsAppMessage struct {
 			ID    string `json:"id"`
 			Title string `json:"title"`
 		} `json:"list_reply,omitempty"`
+		NfmReply *whatsappofficial.NfmReplyData `json:"nfm_reply,omitempty"`
 	} `json:"interactive,omitempty"`
 	Button *struct {
 		Text    string `json:"text"`
@@ -1500,6 +1823,7 @@ type GenericWebhookPayload struct {
 	SenderName  string            `json:"sender_name,omitempty"`
 	ContentType string            `json:"content_type"`
 	Content     string            `json:"content"`
+	MediaURL    string            `json:"media_url,omitempty"`
 	Metadata    map[string]string `json:"metadata,omitempty"`
 }
 
@@ -1507,6 +1831,7 @@ type StatusCallbackPayload struct {
 	MessageID    string `json:"message_id"`
 	ExternalID   string `json:"external_id,omitempty"`
 	Status       string `json:"status"`
+	ErrorCode    string `json:"error_code,omitempty"`
 	ErrorMessage string `json:"error_message,omitempty"`
 }
 
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// WebhookRegistrationHandler registers channel webhooks with their providers
+type WebhookRegistrationHandler struct {
+	registrationService *service.WebhookRegistrationService
+}
+
+// NewWebhookRegistrationHandler creates a new webhook registration handler
+func NewWebhookRegistrationHandler(registrationService *service.WebhookRegistrationService) *WebhookRegistrationHandler {
+	return &WebhookRegistrationHandler{registrationService: registrationService}
+}
+
+// Register godoc
+// @Summary      Register channel webhook
+// @Description  Registers the channel's webhook with its provider (Telegram setWebhook, Twilio incoming message URL or Meta app subscription) and verifies it
+// @Tags         channels
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Channel ID"
+// @Success      200 {object} Response{data=entity.WebhookRegistration}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /channels/{id}/webhook/register [post]
+func (h *WebhookRegistrationHandler) Register(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	registration, err := h.registrationService.Register(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, registration)
+}
+
+// GetStatus godoc
+// @Summary      Get channel webhook status
+// @Description  Asks the channel's provider where it delivers the channel's events and compares it with the channel's webhook URL
+// @Tags         channels
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Channel ID"
+// @Success      200 {object} Response{data=entity.WebhookRegistration}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /channels/{id}/webhook [get]
+func (h *WebhookRegistrationHandler) GetStatus(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	registration, err := h.registrationService.Status(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, registration)
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/infrastructure/webhook"
+)
+
+// WebhookSchemaHandler publishes the versioned payload contracts of tenant webhooks
+type WebhookSchemaHandler struct{}
+
+// NewWebhookSchemaHandler creates a new webhook schema handler
+func NewWebhookSchemaHandler() *WebhookSchemaHandler {
+	return &WebhookSchemaHandler{}
+}
+
+// List godoc
+// @Summary      List webhook payload schemas
+// @Description  Returns every webhook event payload schema version with its JSON Schema, deprecation status and successor. Subscriptions pick a version or follow the latest.
+// @Tags         webhooks
+// @Produce      json
+// @Success      200 {object} Response{data=[]webhook.SchemaVersion}
+// @Router       /webhook-schemas [get]
+func (h *WebhookSchemaHandler) List(c *gin.Context) {
+	RespondSuccess(c, gin.H{
+		"current":  webhook.CurrentSchemaVersion,
+		"versions": webhook.SchemaVersions(),
+	})
+}
+
+// Get godoc
+// @Summary      Get webhook payload JSON Schema
+// @Description  Returns the JSON Schema of a webhook event payload version. "latest" resolves to the current version.
+// @Tags         webhooks
+// @Produce      json
+// @Param        version path string true "Schema version (v1, v2 or latest)"
+// @Success      200 {object} map[string]interface{}
+// @Failure      404 {object} Response
+// @Router       /webhook-schemas/{version} [get]
+func (h *WebhookSchemaHandler) Get(c *gin.Context) {
+	version, err := webhook.NegotiateSchemaVersion(c.Param("version"))
+	if err != nil {
+		RespondNotFound(c, "webhook schema version")
+		return
+	}
+	schema, _ := webhook.GetSchemaVersion(version)
+
+	if schema.Deprecated {
+		c.Header(webhook.HeaderDeprecation, "true")
+	}
+	c.Header(webhook.HeaderSchemaVersion, schema.Version)
+	c.JSON(http.StatusOK, schema.Schema)
+}
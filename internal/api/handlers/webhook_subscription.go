@@ -0,0 +1,280 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+)
+
+// WebhookSubscriptionHandler handles the endpoints tenants subscribe to Linktor events
+// and their delivery log
+type WebhookSubscriptionHandler struct {
+	webhookSubscriptionService *service.WebhookSubscriptionService
+}
+
+// NewWebhookSubscriptionHandler creates a new webhook subscription handler
+func NewWebhookSubscriptionHandler(webhookSubscriptionService *service.WebhookSubscriptionService) *WebhookSubscriptionHandler {
+	return &WebhookSubscriptionHandler{webhookSubscriptionService: webhookSubscriptionService}
+}
+
+// EventTypes godoc
+// @Summary      List webhook event types
+// @Tags         webhook-subscriptions
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=[]string}
+// @Router       /webhook-subscriptions/event-types [get]
+func (h *WebhookSubscriptionHandler) EventTypes(c *gin.Context) {
+	RespondSuccess(c, h.webhookSubscriptionService.EventTypes())
+}
+
+// List godoc
+// @Summary      List webhook subscriptions
+// @Tags         webhook-subscriptions
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=[]entity.WebhookSubscription}
+// @Router       /webhook-subscriptions [get]
+func (h *WebhookSubscriptionHandler) List(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	subscriptions, err := h.webhookSubscriptionService.List(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, subscriptions)
+}
+
+// Create godoc
+// @Summary      Create webhook subscription
+// @Description  Subscribes an endpoint to event types such as message.received, conversation.resolved and escalation.created, or * for all. Deliveries are signed with the returned secret in X-Linktor-Signature as sha256=<hex HMAC of the body>.
+// @Tags         webhook-subscriptions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.WebhookSubscriptionInput true "Subscription"
+// @Success      201 {object} Response{data=service.WebhookSubscriptionSecret}
+// @Failure      400 {object} Response
+// @Router       /webhook-subscriptions [post]
+func (h *WebhookSubscriptionHandler) Create(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.WebhookSubscriptionInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	subscription, err := h.webhookSubscriptionService.Create(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, subscription)
+}
+
+// Get godoc
+// @Summary      Get webhook subscription
+// @Tags         webhook-subscriptions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Subscription ID"
+// @Success      200 {object} Response{data=entity.WebhookSubscription}
+// @Failure      404 {object} Response
+// @Router       /webhook-subscriptions/{id} [get]
+func (h *WebhookSubscriptionHandler) Get(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	subscription, err := h.webhookSubscriptionService.Get(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, subscription)
+}
+
+// Update godoc
+// @Summary      Update webhook subscription
+// @Tags         webhook-subscriptions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Subscription ID"
+// @Param        request body service.WebhookSubscriptionInput true "Subscription"
+// @Success      200 {object} Response{data=entity.WebhookSubscription}
+// @Failure      400 {object} Response
+// @Failure      404 {object} Response
+// @Router       /webhook-subscriptions/{id} [put]
+func (h *WebhookSubscriptionHandler) Update(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.WebhookSubscriptionInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	subscription, err := h.webhookSubscriptionService.Update(c.Request.Context(), tenantID, c.Param("id"), &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, subscription)
+}
+
+// RotateSecret godoc
+// @Summary      Rotate webhook subscription secret
+// @Description  Replaces the signing secret; deliveries made afterwards are signed with the new secret
+// @Tags         webhook-subscriptions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Subscription ID"
+// @Success      200 {object} Response{data=service.WebhookSubscriptionSecret}
+// @Failure      404 {object} Response
+// @Router       /webhook-subscriptions/{id}/rotate-secret [post]
+func (h *WebhookSubscriptionHandler) RotateSecret(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	subscription, err := h.webhookSubscriptionService.RotateSecret(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, subscription)
+}
+
+// Delete godoc
+// @Summary      Delete webhook subscription
+// @Description  Deletes the subscription and its delivery log
+// @Tags         webhook-subscriptions
+// @Security     BearerAuth
+// @Param        id path string true "Subscription ID"
+// @Success      204
+// @Failure      404 {object} Response
+// @Router       /webhook-subscriptions/{id} [delete]
+func (h *WebhookSubscriptionHandler) Delete(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.webhookSubscriptionService.Delete(c.Request.Context(), tenantID, c.Param("id")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
+
+// ListDeliveries godoc
+// @Summary      List webhook deliveries
+// @Description  Lists the delivery log of a subscription, newest first, with the result of the last attempt of each delivery
+// @Tags         webhook-subscriptions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Subscription ID"
+// @Param        status query string false "pending, succeeded or failed"
+// @Param        page query int false "Page number"
+// @Param        page_size query int false "Page size"
+// @Success      200 {object} Response{data=[]entity.WebhookDelivery}
+// @Failure      404 {object} Response
+// @Router       /webhook-subscriptions/{id}/deliveries [get]
+func (h *WebhookSubscriptionHandler) ListDeliveries(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	params := &repository.ListParams{
+		Page:     page,
+		PageSize: pageSize,
+	}
+
+	deliveries, total, err := h.webhookSubscriptionService.ListDeliveries(c.Request.Context(), tenantID, c.Param("id"),
+		entity.WebhookDeliveryStatus(c.Query("status")), params)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondPaginated(c, deliveries, total, params.Page, params.PageSize)
+}
+
+// GetDelivery godoc
+// @Summary      Get webhook delivery
+// @Tags         webhook-subscriptions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Subscription ID"
+// @Param        deliveryId path string true "Delivery ID"
+// @Success      200 {object} Response{data=entity.WebhookDelivery}
+// @Failure      404 {object} Response
+// @Router       /webhook-subscriptions/{id}/deliveries/{deliveryId} [get]
+func (h *WebhookSubscriptionHandler) GetDelivery(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	delivery, err := h.webhookSubscriptionService.GetDelivery(c.Request.Context(), tenantID, c.Param("id"), c.Param("deliveryId"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, delivery)
+}
+
+// Redeliver godoc
+// @Summary      Redeliver webhook event
+// @Description  Queues the event of a delivery again as a new delivery, keeping its event ID
+// @Tags         webhook-subscriptions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id path string true "Subscription ID"
+// @Param        deliveryId path string true "Delivery ID"
+// @Success      202 {object} Response{data=entity.WebhookDelivery}
+// @Failure      404 {object} Response
+// @Router       /webhook-subscriptions/{id}/deliveries/{deliveryId}/redeliver [post]
+func (h *WebhookSubscriptionHandler) Redeliver(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	delivery, err := h.webhookSubscriptionService.Redeliver(c.Request.Context(), tenantID, c.Param("id"), c.Param("deliveryId"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondAccepted(c, delivery)
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/msgfy/linktor/internal/adapters/custom"
 	"github.com/msgfy/linktor/internal/application/service"
 	"github.com/msgfy/linktor/internal/domain/entity"
 	"github.com/msgfy/linktor/pkg/testutil"
@@ -800,6 +801,55 @@ func TestWebhookGeneric_ValidPayload(t *testing.T) {
 	assert.Equal(t, "api", msg.Metadata["source"])
 }
 
+func TestWebhookGeneric_CustomMappingAndSignature(t *testing.T) {
+	handler, channelRepo, producer, _ := setupWebhookTest()
+
+	channelRepo.Channels["ch-custom"] = &entity.Channel{
+		ID:               "ch-custom",
+		TenantID:         "tenant-1",
+		Type:             entity.ChannelTypeCustom,
+		Enabled:          true,
+		ConnectionStatus: entity.ConnectionStatusConnected,
+		Config: map[string]string{
+			"inbound_sender_id_path": "from.id",
+			"inbound_content_path":   "text",
+			"inbound_media_url_path": "file.url",
+		},
+		Credentials: map[string]string{"inbound_secret": "shh"},
+	}
+
+	body := []byte(`{"from": {"id": "agent-7"}, "text": "report", "file": {"url": "https://files.example.com/r.pdf"}}`)
+	send := func(signature string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		req := httptest.NewRequest(http.MethodPost, "/webhook/generic/ch-custom", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if signature != "" {
+			req.Header.Set(custom.SignatureHeader, signature)
+		}
+		c.Request = req
+		c.Params = []gin.Param{{Key: "channelId", Value: "ch-custom"}}
+		handler.GenericWebhook(c)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, send("").Code)
+	assert.Equal(t, http.StatusUnauthorized, send(custom.Sign(body, "wrong")).Code)
+	assert.Empty(t, producer.InboundMessages)
+
+	w := send(custom.Sign(body, "shh"))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	require.Len(t, producer.InboundMessages, 1)
+	msg := producer.InboundMessages[0]
+	assert.Equal(t, "custom", msg.ChannelType)
+	assert.Equal(t, "report", msg.Content)
+	assert.Equal(t, "document", msg.ContentType)
+	assert.Equal(t, "agent-7", msg.Metadata["sender_id"])
+	require.Len(t, msg.Attachments, 1)
+	assert.Equal(t, "https://files.example.com/r.pdf", msg.Attachments[0].URL)
+}
+
 // ---------------------------------------------------------------------------
 // 12. Generic Webhook - Channel Not Found
 // ---------------------------------------------------------------------------
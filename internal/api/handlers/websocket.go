@@ -9,6 +9,11 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/gorilla/websocket"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
 )
 
 const (
@@ -16,6 +21,9 @@ const (
 	pongWait       = 60 * time.Second
 	pingPeriod     = (pongWait * 9) / 10
 	maxMessageSize = 512 * 1024
+
+	// presenceTimeout is how long an agent stays available without a heartbeat
+	presenceTimeout = pongWait + writeWait
 )
 
 // WebSocket event types
@@ -26,8 +34,13 @@ const (
 	WSEventConversationCreated = "conversation_created"
 	WSEventTyping              = "typing"
 	WSEventPresence            = "presence"
+	WSEventHeartbeat           = "heartbeat"
 	WSEventError               = "error"
 	WSEventConnected           = "connected"
+	WSEventQAReviewCompleted   = "qa_review_completed"
+	WSEventWatchActivity       = "watch_activity"
+	WSEventAgentAway           = "agent_away"
+	WSEventCopilotSuggestions  = "copilot_suggestions"
 )
 
 // WSMessage represents a WebSocket message
@@ -50,10 +63,16 @@ type WSTypingPayload struct {
 	IsTyping       bool   `json:"is_typing"`
 }
 
+// WSCopilotPayload represents copilot suggestions for a conversation
+type WSCopilotPayload struct {
+	ConversationID string                      `json:"conversation_id"`
+	Suggestions    []*entity.CopilotSuggestion `json:"suggestions"`
+}
+
 // WSPresencePayload represents a presence event
 type WSPresencePayload struct {
 	UserID   string `json:"user_id"`
-	Status   string `json:"status"` // online, offline, away
+	Status   string `json:"status"` // online, away, busy, offline
 	LastSeen string `json:"last_seen,omitempty"`
 }
 
@@ -74,8 +93,32 @@ type AgentHub struct {
 	// Broadcast to tenant
 	broadcast chan *TenantBroadcast
 
-	mu   sync.RWMutex
-	done chan struct{}
+	// Presence by user ID, kept after disconnect so the chosen status survives reconnects
+	presence map[string]*entity.AgentPresence
+
+	// Relay to the hubs of other server instances, nil when running alone
+	relay HubRelay
+
+	mu      sync.RWMutex
+	done    chan struct{}
+	now     func() time.Time
+	started time.Time
+}
+
+// HubRelay carries hub messages between the server instances, so agents receive
+// broadcasts and direct messages whichever instance they are connected to
+type HubRelay interface {
+	Publish(topic string, data []byte) error
+	Subscribe(topic string, handler func(data []byte)) error
+}
+
+// relayedMessage is a hub message sent to the other instances: a tenant broadcast,
+// or a direct message when UserID is set
+type relayedMessage struct {
+	TenantID      string     `json:"tenant_id,omitempty"`
+	UserID        string     `json:"user_id,omitempty"`
+	ExcludeUserID string     `json:"exclude_user_id,omitempty"`
+	Message       *WSMessage `json:"message"`
 }
 
 // TenantBroadcast represents a message to broadcast to a tenant
@@ -103,7 +146,10 @@ func NewAgentHub() *AgentHub {
 		register:   make(chan *AgentClient),
 		unregister: make(chan *AgentClient),
 		broadcast:  make(chan *TenantBroadcast, 256),
+		presence:   make(map[string]*entity.AgentPresence),
 		done:       make(chan struct{}),
+		now:        time.Now,
+		started:    time.Now(),
 	}
 }
 
@@ -118,6 +164,7 @@ func (h *AgentHub) Run() {
 				h.tenants[client.TenantID] = make(map[string]*AgentClient)
 			}
 			h.tenants[client.TenantID][client.UserID] = client
+			status := h.markConnected(client)
 			h.mu.Unlock()
 
 			// Broadcast presence
@@ -125,7 +172,7 @@ func (h *AgentHub) Run() {
 				Type: WSEventPresence,
 				Payload: WSPresencePayload{
 					UserID: client.UserID,
-					Status: "online",
+					Status: string(status),
 				},
 			}, client.UserID)
 
@@ -141,6 +188,7 @@ func (h *AgentHub) Run() {
 				}
 				close(client.send)
 			}
+			lastSeen := h.markDisconnected(client)
 			h.mu.Unlock()
 
 			// Broadcast offline presence
@@ -148,8 +196,8 @@ func (h *AgentHub) Run() {
 				Type: WSEventPresence,
 				Payload: WSPresencePayload{
 					UserID:   client.UserID,
-					Status:   "offline",
-					LastSeen: time.Now().Format(time.RFC3339),
+					Status:   string(entity.PresenceStatusOffline),
+					LastSeen: lastSeen.Format(time.RFC3339),
 				},
 			}, "")
 
@@ -187,8 +235,32 @@ func (h *AgentHub) Stop() {
 	close(h.done)
 }
 
+// SetRelay relays the hub's messages through relay, delivering those of the other
+// instances to the agents connected here
+func (h *AgentHub) SetRelay(relay HubRelay) error {
+	if err := relay.Subscribe(nats.BroadcastTopicAgentHub, h.receiveRelayed); err != nil {
+		return err
+	}
+	h.mu.Lock()
+	h.relay = relay
+	h.mu.Unlock()
+	return nil
+}
+
 // BroadcastToTenant sends a message to all agents in a tenant
 func (h *AgentHub) BroadcastToTenant(tenantID string, msg *WSMessage, excludeUserID string) {
+	h.broadcastLocal(tenantID, msg, excludeUserID)
+	h.sendRelayed(&relayedMessage{TenantID: tenantID, ExcludeUserID: excludeUserID, Message: msg})
+}
+
+// SendToUser sends a message to a specific user
+func (h *AgentHub) SendToUser(userID string, msg *WSMessage) {
+	h.sendLocal(userID, msg)
+	h.sendRelayed(&relayedMessage{UserID: userID, Message: msg})
+}
+
+// broadcastLocal sends a message to the agents of a tenant connected to this instance
+func (h *AgentHub) broadcastLocal(tenantID string, msg *WSMessage, excludeUserID string) {
 	select {
 	case h.broadcast <- &TenantBroadcast{
 		TenantID:      tenantID,
@@ -200,8 +272,8 @@ func (h *AgentHub) BroadcastToTenant(tenantID string, msg *WSMessage, excludeUse
 	}
 }
 
-// SendToUser sends a message to a specific user
-func (h *AgentHub) SendToUser(userID string, msg *WSMessage) {
+// sendLocal sends a message to a user connected to this instance
+func (h *AgentHub) sendLocal(userID string, msg *WSMessage) {
 	h.mu.RLock()
 	client, ok := h.clients[userID]
 	h.mu.RUnlock()
@@ -214,6 +286,38 @@ func (h *AgentHub) SendToUser(userID string, msg *WSMessage) {
 	}
 }
 
+// sendRelayed hands a message to the other instances, if relayed
+func (h *AgentHub) sendRelayed(relayed *relayedMessage) {
+	h.mu.RLock()
+	relay := h.relay
+	h.mu.RUnlock()
+	if relay == nil {
+		return
+	}
+
+	data, err := json.Marshal(relayed)
+	if err != nil {
+		logger.Warn("Failed to encode relayed hub message", zap.String("type", relayed.Message.Type), zap.Error(err))
+		return
+	}
+	if err := relay.Publish(nats.BroadcastTopicAgentHub, data); err != nil {
+		logger.Warn("Failed to relay hub message", zap.String("type", relayed.Message.Type), zap.Error(err))
+	}
+}
+
+// receiveRelayed delivers a message of another instance to the agents connected here
+func (h *AgentHub) receiveRelayed(data []byte) {
+	var relayed relayedMessage
+	if err := json.Unmarshal(data, &relayed); err != nil || relayed.Message == nil {
+		return
+	}
+	if relayed.UserID != "" {
+		h.sendLocal(relayed.UserID, relayed.Message)
+		return
+	}
+	h.broadcastLocal(relayed.TenantID, relayed.Message, relayed.ExcludeUserID)
+}
+
 // GetOnlineUsers returns online users for a tenant
 func (h *AgentHub) GetOnlineUsers(tenantID string) []string {
 	h.mu.RLock()
@@ -228,6 +332,179 @@ func (h *AgentHub) GetOnlineUsers(tenantID string) []string {
 	return users
 }
 
+// markConnected records a new connection for the client's user and returns the
+// status it comes online with. Must be called with the lock held.
+func (h *AgentHub) markConnected(client *AgentClient) entity.PresenceStatus {
+	now := h.now()
+	presence, ok := h.presence[client.UserID]
+	if !ok {
+		presence = &entity.AgentPresence{
+			UserID: client.UserID,
+			Status: entity.PresenceStatusOnline,
+		}
+		h.presence[client.UserID] = presence
+	}
+	if !presence.Status.IsSelectable() {
+		presence.Status = entity.PresenceStatusOnline
+	}
+	presence.TenantID = client.TenantID
+	presence.Email = client.Email
+	presence.Connected = true
+	presence.LastHeartbeat = &now
+	presence.UpdatedAt = now
+	return presence.Status
+}
+
+// markDisconnected records that the client's user went offline and returns when.
+// Must be called with the lock held.
+func (h *AgentHub) markDisconnected(client *AgentClient) time.Time {
+	now := h.now()
+	if _, stillConnected := h.clients[client.UserID]; stillConnected {
+		return now
+	}
+	if presence, ok := h.presence[client.UserID]; ok {
+		presence.Connected = false
+		presence.LastSeen = &now
+		presence.UpdatedAt = now
+	}
+	return now
+}
+
+// Heartbeat records that a connected agent is still active
+func (h *AgentHub) Heartbeat(userID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if presence, ok := h.presence[userID]; ok && presence.Connected {
+		now := h.now()
+		presence.LastHeartbeat = &now
+	}
+}
+
+// SetPresence sets the status an agent chose and notifies the rest of the tenant
+func (h *AgentHub) SetPresence(tenantID, userID string, status entity.PresenceStatus) (*entity.AgentPresence, error) {
+	if !status.IsSelectable() {
+		return nil, errors.Validation("status must be one of online, away or busy")
+	}
+
+	h.mu.Lock()
+	now := h.now()
+	presence, ok := h.presence[userID]
+	if !ok || presence.TenantID != tenantID {
+		presence = &entity.AgentPresence{
+			UserID:   userID,
+			TenantID: tenantID,
+		}
+		h.presence[userID] = presence
+	}
+	presence.Status = status
+	presence.UpdatedAt = now
+	snapshot := h.snapshot(presence)
+	h.mu.Unlock()
+
+	h.BroadcastToTenant(tenantID, &WSMessage{
+		Type: WSEventPresence,
+		Payload: WSPresencePayload{
+			UserID: userID,
+			Status: string(snapshot.Status),
+		},
+	}, userID)
+
+	return snapshot, nil
+}
+
+// GetPresence returns the presence of every agent of a tenant the hub has seen
+func (h *AgentHub) GetPresence(tenantID string) []*entity.AgentPresence {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var result []*entity.AgentPresence
+	for _, presence := range h.presence {
+		if presence.TenantID == tenantID {
+			result = append(result, h.snapshot(presence))
+		}
+	}
+	return result
+}
+
+// PresenceChangedSince returns the presence of a tenant's agents whose reported status
+// changed after since, including agents reported offline because their heartbeats
+// stopped. Each entry's UpdatedAt is when the reported status changed.
+func (h *AgentHub) PresenceChangedSince(tenantID string, since time.Time) []*entity.AgentPresence {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var result []*entity.AgentPresence
+	for _, presence := range h.presence {
+		if presence.TenantID != tenantID {
+			continue
+		}
+		snapshot := h.snapshot(presence)
+		switch {
+		case !presence.Connected && presence.LastSeen != nil && presence.LastSeen.After(snapshot.UpdatedAt):
+			snapshot.UpdatedAt = *presence.LastSeen
+		case presence.Connected && !snapshot.Connected && presence.LastHeartbeat != nil:
+			snapshot.UpdatedAt = presence.LastHeartbeat.Add(presenceTimeout)
+		}
+		if snapshot.UpdatedAt.After(since) {
+			result = append(result, snapshot)
+		}
+	}
+	return result
+}
+
+// IsAgentAvailable reports whether an agent is connected, sending heartbeats and online
+func (h *AgentHub) IsAgentAvailable(tenantID, userID string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	presence, ok := h.presence[userID]
+	if !ok || presence.TenantID != tenantID {
+		return false
+	}
+	return h.snapshot(presence).IsAvailable()
+}
+
+// UnavailableSince returns the status of an agent that is offline or away and since
+// when. Agents this instance never saw are offline since it started, so a restart
+// does not look like a long absence.
+func (h *AgentHub) UnavailableSince(tenantID, userID string) (entity.PresenceStatus, time.Time, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	presence, ok := h.presence[userID]
+	if !ok || presence.TenantID != tenantID {
+		return entity.PresenceStatusOffline, h.started, true
+	}
+
+	switch {
+	case !presence.Connected:
+		if presence.LastSeen != nil {
+			return entity.PresenceStatusOffline, *presence.LastSeen, true
+		}
+		return entity.PresenceStatusOffline, presence.UpdatedAt, true
+	case presence.LastHeartbeat == nil:
+		return entity.PresenceStatusOffline, presence.UpdatedAt, true
+	case h.now().Sub(*presence.LastHeartbeat) > presenceTimeout:
+		return entity.PresenceStatusOffline, presence.LastHeartbeat.Add(presenceTimeout), true
+	case presence.Status == entity.PresenceStatusAway:
+		return entity.PresenceStatusAway, presence.UpdatedAt, true
+	}
+	return presence.Status, time.Time{}, false
+}
+
+// snapshot copies a presence entry, reporting agents whose connection dropped or
+// went quiet as offline. Must be called with the lock held.
+func (h *AgentHub) snapshot(presence *entity.AgentPresence) *entity.AgentPresence {
+	out := *presence
+	stale := out.LastHeartbeat == nil || h.now().Sub(*out.LastHeartbeat) > presenceTimeout
+	if !out.Connected || stale {
+		out.Connected = false
+		out.Status = entity.PresenceStatusOffline
+	}
+	return &out
+}
+
 // WebSocketHandler handles agent WebSocket connections
 type WebSocketHandler struct {
 	hub       *AgentHub
@@ -303,6 +580,7 @@ func (h *WebSocketHandler) HandleConnection(c *gin.Context) {
 			"user_id":      userID,
 			"tenant_id":    tenantID,
 			"online_users": h.hub.GetOnlineUsers(tenantID),
+			"presence":     h.hub.GetPresence(tenantID),
 		},
 	}
 
@@ -322,6 +600,7 @@ func (c *AgentClient) readPump() {
 	c.conn.SetReadDeadline(time.Now().Add(pongWait))
 	c.conn.SetPongHandler(func(string) error {
 		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+		c.hub.Heartbeat(c.UserID)
 		return nil
 	})
 
@@ -352,6 +631,21 @@ func (c *AgentClient) readPump() {
 					},
 				}, c.UserID)
 			}
+
+		case WSEventHeartbeat:
+			c.hub.Heartbeat(c.UserID)
+
+		case WSEventPresence:
+			// Agent changed their status
+			if payload, ok := msg.Payload.(map[string]interface{}); ok {
+				status, _ := payload["status"].(string)
+				if _, err := c.hub.SetPresence(c.TenantID, c.UserID, entity.PresenceStatus(status)); err != nil {
+					select {
+					case c.send <- &WSMessage{Type: WSEventError, Payload: map[string]string{"error": err.Error()}}:
+					default:
+					}
+				}
+			}
 		}
 	}
 }
@@ -424,3 +718,60 @@ func BroadcastConversationUpdate(tenantID string, conversation interface{}) {
 		Payload: conversation,
 	}, "")
 }
+
+// QAReviewWSNotifier notifies agents over WebSocket when one of their conversations was reviewed
+type QAReviewWSNotifier struct{}
+
+// NotifyReviewCompleted sends the completed review to the reviewed agent
+func (QAReviewWSNotifier) NotifyReviewCompleted(review *entity.QAReview) {
+	GetAgentHub().SendToUser(review.AgentID, &WSMessage{
+		Type:    WSEventQAReviewCompleted,
+		Payload: review,
+	})
+}
+
+// WatchWSNotifier delivers conversation activity over WebSocket to the users watching it
+type WatchWSNotifier struct{}
+
+// NotifyWatchers sends the activity to each watcher that is online
+func (WatchWSNotifier) NotifyWatchers(userIDs []string, activity *entity.WatchActivity) {
+	hub := GetAgentHub()
+	for _, userID := range userIDs {
+		hub.SendToUser(userID, &WSMessage{
+			Type:    WSEventWatchActivity,
+			Payload: activity,
+		})
+	}
+}
+
+// AgentAwayWSNotifier tells supervisors over WebSocket that an unavailable agent's
+// conversations were re-routed
+type AgentAwayWSNotifier struct{}
+
+// NotifySupervisors sends the notice to each supervisor that is online
+func (AgentAwayWSNotifier) NotifySupervisors(userIDs []string, notice *entity.AgentAwayNotice) {
+	hub := GetAgentHub()
+	for _, userID := range userIDs {
+		hub.SendToUser(userID, &WSMessage{
+			Type:    WSEventAgentAway,
+			Payload: notice,
+		})
+	}
+}
+
+// CopilotWSNotifier delivers agent copilot suggestions over WebSocket
+type CopilotWSNotifier struct{}
+
+// NotifySuggestions sends the suggestions to the agent handling the conversation
+func (CopilotWSNotifier) NotifySuggestions(userID string, suggestions []*entity.CopilotSuggestion) {
+	if len(suggestions) == 0 {
+		return
+	}
+	GetAgentHub().SendToUser(userID, &WSMessage{
+		Type: WSEventCopilotSuggestions,
+		Payload: &WSCopilotPayload{
+			ConversationID: suggestions[0].ConversationID,
+			Suggestions:    suggestions,
+		},
+	})
+}
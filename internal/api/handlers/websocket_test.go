@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/domain/entity"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -66,3 +69,123 @@ func TestNewWebSocketHandler(t *testing.T) {
 	assert.Equal(t, hub, handler.hub)
 	assert.Equal(t, "test-jwt-secret", handler.jwtSecret)
 }
+
+func TestAgentHub_Presence(t *testing.T) {
+	hub := NewAgentHub()
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	hub.now = func() time.Time { return now }
+
+	client := &AgentClient{hub: hub, UserID: "user-1", TenantID: "tenant-1", send: make(chan *WSMessage, 1)}
+	hub.clients[client.UserID] = client
+	assert.Equal(t, entity.PresenceStatusOnline, hub.markConnected(client))
+	assert.True(t, hub.IsAgentAvailable("tenant-1", "user-1"))
+	assert.False(t, hub.IsAgentAvailable("tenant-2", "user-1"))
+
+	presence, err := hub.SetPresence("tenant-1", "user-1", entity.PresenceStatusBusy)
+	require.NoError(t, err)
+	assert.Equal(t, entity.PresenceStatusBusy, presence.Status)
+	assert.False(t, hub.IsAgentAvailable("tenant-1", "user-1"), "busy agents take no new conversations")
+
+	_, err = hub.SetPresence("tenant-1", "user-1", entity.PresenceStatusOffline)
+	assert.Error(t, err)
+
+	// The chosen status survives a reconnect
+	delete(hub.clients, client.UserID)
+	hub.markDisconnected(client)
+	assert.Equal(t, entity.PresenceStatusOffline, hub.GetPresence("tenant-1")[0].Status)
+	hub.clients[client.UserID] = client
+	assert.Equal(t, entity.PresenceStatusBusy, hub.markConnected(client))
+}
+
+func TestAgentHub_Presence_HeartbeatTimeout(t *testing.T) {
+	hub := NewAgentHub()
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	hub.now = func() time.Time { return now }
+
+	client := &AgentClient{hub: hub, UserID: "user-1", TenantID: "tenant-1"}
+	hub.clients[client.UserID] = client
+	hub.markConnected(client)
+
+	now = now.Add(presenceTimeout - time.Second)
+	hub.Heartbeat("user-1")
+	now = now.Add(presenceTimeout - time.Second)
+	assert.True(t, hub.IsAgentAvailable("tenant-1", "user-1"))
+
+	now = now.Add(2 * time.Second)
+	assert.False(t, hub.IsAgentAvailable("tenant-1", "user-1"), "agents without heartbeats are offline")
+	assert.Equal(t, entity.PresenceStatusOffline, hub.GetPresence("tenant-1")[0].Status)
+}
+
+// fakeHubBus connects the relays of several hubs as NATS would, without echoing
+// a message back to the hub that sent it
+type fakeHubBus struct {
+	mu       sync.Mutex
+	handlers map[*fakeHubRelay]func(data []byte)
+}
+
+type fakeHubRelay struct {
+	bus *fakeHubBus
+}
+
+func (r *fakeHubRelay) Publish(topic string, data []byte) error {
+	r.bus.mu.Lock()
+	defer r.bus.mu.Unlock()
+	for relay, handler := range r.bus.handlers {
+		if relay != r {
+			handler(data)
+		}
+	}
+	return nil
+}
+
+func (r *fakeHubRelay) Subscribe(topic string, handler func(data []byte)) error {
+	r.bus.mu.Lock()
+	defer r.bus.mu.Unlock()
+	r.bus.handlers[r] = handler
+	return nil
+}
+
+func TestAgentHub_RelaysMessagesBetweenInstances(t *testing.T) {
+	bus := &fakeHubBus{handlers: map[*fakeHubRelay]func(data []byte){}}
+	first, second := NewAgentHub(), NewAgentHub()
+	for _, hub := range []*AgentHub{first, second} {
+		require.NoError(t, hub.SetRelay(&fakeHubRelay{bus: bus}))
+		go hub.Run()
+		defer hub.Stop()
+	}
+
+	agent := &AgentClient{hub: second, UserID: "user-1", TenantID: "tenant-1", send: make(chan *WSMessage, 4)}
+	other := &AgentClient{hub: second, UserID: "user-2", TenantID: "tenant-2", send: make(chan *WSMessage, 4)}
+	second.mu.Lock()
+	for _, client := range []*AgentClient{agent, other} {
+		second.clients[client.UserID] = client
+		second.tenants[client.TenantID] = map[string]*AgentClient{client.UserID: client}
+	}
+	second.mu.Unlock()
+
+	receive := func(client *AgentClient) *WSMessage {
+		select {
+		case msg := <-client.send:
+			return msg
+		case <-time.After(time.Second):
+			return nil
+		}
+	}
+
+	// A broadcast on the first instance reaches the agent connected to the second
+	first.BroadcastToTenant("tenant-1", &WSMessage{Type: WSEventConversationUpdated, Payload: map[string]string{"id": "conv-1"}}, "")
+	msg := receive(agent)
+	require.NotNil(t, msg)
+	assert.Equal(t, WSEventConversationUpdated, msg.Type)
+	assert.Equal(t, map[string]interface{}{"id": "conv-1"}, msg.Payload)
+
+	first.SendToUser("user-2", &WSMessage{Type: WSEventCopilotSuggestions})
+	msg = receive(other)
+	require.NotNil(t, msg)
+	assert.Equal(t, WSEventCopilotSuggestions, msg.Type)
+
+	// Excluded users and other tenants get nothing
+	first.BroadcastToTenant("tenant-1", &WSMessage{Type: WSEventTyping}, "user-1")
+	assert.Nil(t, receive(agent))
+	assert.Empty(t, other.send)
+}
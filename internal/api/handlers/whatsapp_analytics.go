@@ -7,13 +7,16 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
 	"github.com/msgfy/linktor/internal/whatsapp/analytics"
 )
 
 // WhatsAppAnalyticsHandler handles WhatsApp analytics HTTP requests
 type WhatsAppAnalyticsHandler struct {
-	mu      sync.RWMutex
-	clients map[string]*analytics.Client // key: channel_id
+	mu             sync.RWMutex
+	clients        map[string]*analytics.Client // key: channel_id
+	performanceSvc *service.TemplatePerformanceService
 }
 
 // NewWhatsAppAnalyticsHandler creates a new analytics handler
@@ -23,6 +26,11 @@ func NewWhatsAppAnalyticsHandler() *WhatsAppAnalyticsHandler {
 	}
 }
 
+// SetTemplatePerformanceService enables the template performance report
+func (h *WhatsAppAnalyticsHandler) SetTemplatePerformanceService(svc *service.TemplatePerformanceService) {
+	h.performanceSvc = svc
+}
+
 // RegisterClient registers an analytics client for a channel
 func (h *WhatsAppAnalyticsHandler) RegisterClient(channelID string, client *analytics.Client) {
 	h.mu.Lock()
@@ -392,3 +400,53 @@ func (h *WhatsAppAnalyticsHandler) GetDashboardData(c *gin.Context) {
 func parseDate(dateStr string) (time.Time, error) {
 	return time.Parse("2006-01-02", dateStr)
 }
+
+// GetTemplatePerformance godoc
+// @Summary      Get template performance report
+// @Description  Combines Meta template analytics with local delivery, read and reply rates, flagging templates with RED quality or falling read rates
+// @Tags         whatsapp-analytics
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        channelId  path  string true  "Channel ID"
+// @Param        start_date query string false "Start date (YYYY-MM-DD)"
+// @Param        end_date   query string false "End date (YYYY-MM-DD)"
+// @Success      200 {object} Response{data=[]entity.TemplatePerformance}
+// @Failure      404 {object} Response
+// @Failure      500 {object} Response
+// @Router       /channels/{channelId}/analytics/template-performance [get]
+func (h *WhatsAppAnalyticsHandler) GetTemplatePerformance(c *gin.Context) {
+	if h.performanceSvc == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Template performance is not configured"})
+		return
+	}
+
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+	channelID := c.Param("id")
+
+	startDate, err := parseDate(c.Query("start_date"))
+	if err != nil {
+		startDate = time.Now().AddDate(0, 0, -30)
+	}
+
+	endDate, err := parseDate(c.Query("end_date"))
+	if err != nil {
+		endDate = time.Now()
+	}
+
+	var meta service.TemplateStatsSource
+	if client, ok := h.getClient(channelID); ok {
+		meta = client
+	}
+
+	report, err := h.performanceSvc.GetReport(c.Request.Context(), tenantID, channelID, startDate, endDate, meta)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, report)
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// WhatsAppPhoneNumberHandler handles the phone numbers of WhatsApp Official channels
+type WhatsAppPhoneNumberHandler struct {
+	phoneNumberService *service.WhatsAppPhoneNumberService
+}
+
+// NewWhatsAppPhoneNumberHandler creates a new WhatsApp phone number handler
+func NewWhatsAppPhoneNumberHandler(phoneNumberService *service.WhatsAppPhoneNumberService) *WhatsAppPhoneNumberHandler {
+	return &WhatsAppPhoneNumberHandler{phoneNumberService: phoneNumberService}
+}
+
+// AddPhoneNumberRequest represents a request to attach a phone number to a channel
+type AddPhoneNumberRequest struct {
+	PhoneNumberID      string `json:"phone_number_id" binding:"required"`
+	DisplayPhoneNumber string `json:"display_phone_number"`
+	VerifiedName       string `json:"verified_name"`
+	QualityRating      string `json:"quality_rating"`
+	MessagingLimitTier string `json:"messaging_limit_tier"`
+	IsDefault          bool   `json:"is_default"`
+}
+
+// UpdatePhoneNumberRequest represents a request to update a channel phone number
+type UpdatePhoneNumberRequest struct {
+	DisplayPhoneNumber *string `json:"display_phone_number"`
+	VerifiedName       *string `json:"verified_name"`
+	QualityRating      *string `json:"quality_rating"`
+	MessagingLimitTier *string `json:"messaging_limit_tier"`
+	IsDefault          *bool   `json:"is_default"`
+	Enabled            *bool   `json:"enabled"`
+}
+
+// List returns the phone numbers of a channel
+// @Summary      List channel phone numbers
+// @Description  Returns the phone numbers managed by a WhatsApp Official channel
+// @Tags         channels
+// @Produce      json
+// @Param        id path string true "Channel ID"
+// @Success      200 {object} Response
+// @Failure      404 {object} Response
+// @Security     BearerAuth
+// @Router       /channels/{id}/phone-numbers [get]
+func (h *WhatsAppPhoneNumberHandler) List(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	numbers, err := h.phoneNumberService.List(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, numbers)
+}
+
+// Add attaches a phone number of the same WABA to a channel
+// @Summary      Add channel phone number
+// @Description  Registers an additional phone number on a WhatsApp Official channel
+// @Tags         channels
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Channel ID"
+// @Param        request body AddPhoneNumberRequest true "Phone number"
+// @Success      201 {object} Response
+// @Failure      400 {object} Response
+// @Failure      409 {object} Response
+// @Security     BearerAuth
+// @Router       /channels/{id}/phone-numbers [post]
+func (h *WhatsAppPhoneNumberHandler) Add(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req AddPhoneNumberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	number, err := h.phoneNumberService.Add(c.Request.Context(), &service.AddPhoneNumberInput{
+		TenantID:           tenantID,
+		ChannelID:          c.Param("id"),
+		PhoneNumberID:      req.PhoneNumberID,
+		DisplayPhoneNumber: req.DisplayPhoneNumber,
+		VerifiedName:       req.VerifiedName,
+		QualityRating:      req.QualityRating,
+		MessagingLimitTier: req.MessagingLimitTier,
+		IsDefault:          req.IsDefault,
+	})
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondCreated(c, number)
+}
+
+// Update updates a channel phone number
+// @Summary      Update channel phone number
+// @Tags         channels
+// @Accept       json
+// @Produce      json
+// @Param        id path string true "Channel ID"
+// @Param        numberId path string true "Phone number ID"
+// @Param        request body UpdatePhoneNumberRequest true "Fields to update"
+// @Success      200 {object} Response
+// @Failure      404 {object} Response
+// @Security     BearerAuth
+// @Router       /channels/{id}/phone-numbers/{numberId} [put]
+func (h *WhatsAppPhoneNumberHandler) Update(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req UpdatePhoneNumberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	number, err := h.phoneNumberService.Update(c.Request.Context(), tenantID, c.Param("id"), c.Param("numberId"), &service.UpdatePhoneNumberInput{
+		DisplayPhoneNumber: req.DisplayPhoneNumber,
+		VerifiedName:       req.VerifiedName,
+		QualityRating:      req.QualityRating,
+		MessagingLimitTier: req.MessagingLimitTier,
+		IsDefault:          req.IsDefault,
+		Enabled:            req.Enabled,
+	})
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, number)
+}
+
+// Remove detaches a phone number from a channel
+// @Summary      Remove channel phone number
+// @Tags         channels
+// @Param        id path string true "Channel ID"
+// @Param        numberId path string true "Phone number ID"
+// @Success      204
+// @Failure      404 {object} Response
+// @Security     BearerAuth
+// @Router       /channels/{id}/phone-numbers/{numberId} [delete]
+func (h *WhatsAppPhoneNumberHandler) Remove(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	if err := h.phoneNumberService.Remove(c.Request.Context(), tenantID, c.Param("id"), c.Param("numberId")); err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondNoContent(c)
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// WhatsAppQualityGuardHandler handles the marketing pause state of WhatsApp channels
+type WhatsAppQualityGuardHandler struct {
+	qualityGuard *service.WhatsAppQualityGuardService
+}
+
+// NewWhatsAppQualityGuardHandler creates a new quality guard handler
+func NewWhatsAppQualityGuardHandler(qualityGuard *service.WhatsAppQualityGuardService) *WhatsAppQualityGuardHandler {
+	return &WhatsAppQualityGuardHandler{qualityGuard: qualityGuard}
+}
+
+// GetStatus returns whether marketing sends are paused on a channel
+// @Summary      Get marketing pause status
+// @Description  Returns whether marketing sends are paused on a channel after a quality warning
+// @Tags         channels
+// @Produce      json
+// @Param        id path string true "Channel ID"
+// @Success      200 {object} Response
+// @Failure      404 {object} Response
+// @Security     BearerAuth
+// @Router       /channels/{id}/marketing-status [get]
+func (h *WhatsAppQualityGuardHandler) GetStatus(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	status, err := h.qualityGuard.GetStatus(c.Request.Context(), tenantID, c.Param("id"))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, status)
+}
+
+// Resume explicitly re-enables marketing sends on a paused channel
+// @Summary      Resume marketing sends
+// @Description  Re-enables marketing sends that were paused after a quality warning
+// @Tags         channels
+// @Produce      json
+// @Param        id path string true "Channel ID"
+// @Success      200 {object} Response
+// @Failure      404 {object} Response
+// @Failure      409 {object} Response
+// @Security     BearerAuth
+// @Router       /channels/{id}/marketing-resume [post]
+func (h *WhatsAppQualityGuardHandler) Resume(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	status, err := h.qualityGuard.ResumeMarketing(c.Request.Context(), tenantID, c.Param("id"), middleware.GetUserID(c))
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, status)
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/api/middleware"
+	"github.com/msgfy/linktor/internal/application/service"
+)
+
+// WidgetSettingsHandler handles the quick replies and emoji sets of webchat widgets
+type WidgetSettingsHandler struct {
+	widgetSettingsService *service.WidgetSettingsService
+}
+
+// NewWidgetSettingsHandler creates a new widget settings handler
+func NewWidgetSettingsHandler(widgetSettingsService *service.WidgetSettingsService) *WidgetSettingsHandler {
+	return &WidgetSettingsHandler{widgetSettingsService: widgetSettingsService}
+}
+
+// Get godoc
+// @Summary      Get widget settings
+// @Description  Returns the quick-reply chips and emoji sets offered in the tenant's webchat widgets
+// @Tags         webchat
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200 {object} Response{data=entity.WidgetSettings}
+// @Router       /widget-settings [get]
+func (h *WidgetSettingsHandler) Get(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	settings, err := h.widgetSettingsService.Get(c.Request.Context(), tenantID)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, settings)
+}
+
+// Save godoc
+// @Summary      Configure widget settings
+// @Description  Replaces the quick-reply chips and emoji sets of the tenant's webchat widgets.
+// @Description  Widgets already open pick them up right away.
+// @Tags         webchat
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request body service.SaveWidgetSettingsInput true "Widget settings"
+// @Success      200 {object} Response{data=entity.WidgetSettings}
+// @Failure      400 {object} Response
+// @Router       /widget-settings [put]
+func (h *WidgetSettingsHandler) Save(c *gin.Context) {
+	tenantID := middleware.MustGetTenantID(c)
+	if tenantID == "" {
+		return
+	}
+
+	var req service.SaveWidgetSettingsInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondValidationError(c, "Invalid request body", nil)
+		return
+	}
+
+	settings, err := h.widgetSettingsService.Save(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		RespondError(c, err)
+		return
+	}
+
+	RespondSuccess(c, settings)
+}
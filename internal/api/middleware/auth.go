@@ -6,6 +6,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/infrastructure/encryption"
 	"github.com/msgfy/linktor/pkg/errors"
 )
 
@@ -22,11 +24,19 @@ const (
 	UserRoleKey = "user_role"
 	// UserEmailKey is the context key for user email
 	UserEmailKey = "user_email"
+	// APIKeyHeader is the header name for API keys
+	APIKeyHeader = "X-API-Key"
+	// APIKeyIDKey is the context key for the ID of the API key a request authenticated with
+	APIKeyIDKey = "api_key_id"
+	// APIKeyRole is the role of requests authenticated with an API key. No role-gated
+	// route accepts it, so keys never reach admin endpoints.
+	APIKeyRole = "api_key"
 )
 
-// AuthMiddleware handles JWT authentication
+// AuthMiddleware handles JWT and API key authentication
 type AuthMiddleware struct {
-	authService *service.AuthService
+	authService   *service.AuthService
+	apiKeyService *service.APIKeyService
 }
 
 // NewAuthMiddleware creates a new auth middleware
@@ -36,9 +46,20 @@ func NewAuthMiddleware(authService *service.AuthService) *AuthMiddleware {
 	}
 }
 
-// Authenticate returns a gin middleware that validates JWT tokens
+// SetAPIKeyService lets requests authenticate with tenant API keys as well as JWTs
+func (m *AuthMiddleware) SetAPIKeyService(apiKeyService *service.APIKeyService) {
+	m.apiKeyService = apiKeyService
+}
+
+// Authenticate returns a gin middleware that validates JWT tokens or API keys. API keys
+// are sent in the X-API-Key header or as bearer tokens.
 func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if rawKey := apiKeyFromRequest(c); rawKey != "" {
+			m.authenticateAPIKey(c, rawKey)
+			return
+		}
+
 		// Get authorization header
 		authHeader := c.GetHeader(AuthorizationHeader)
 		if authHeader == "" {
@@ -72,10 +93,63 @@ func (m *AuthMiddleware) Authenticate() gin.HandlerFunc {
 		c.Set(UserRoleKey, claims.Role)
 		c.Set(UserEmailKey, claims.Email)
 
+		// Scope the request to the tenant so encrypted data of other tenants is never opened
+		// and the AI calls it makes are metered to the tenant
+		ctx := encryption.WithTenant(c.Request.Context(), claims.TenantID)
+		c.Request = c.Request.WithContext(service.WithAIUsage(ctx, claims.TenantID, "", ""))
+
 		c.Next()
 	}
 }
 
+// authenticateAPIKey authenticates a request with an API key whose scopes grant the route
+func (m *AuthMiddleware) authenticateAPIKey(c *gin.Context, rawKey string) {
+	if m.apiKeyService == nil {
+		abortWithError(c, errors.Unauthorized("API keys are not accepted"))
+		return
+	}
+
+	apiKey, err := m.apiKeyService.Authenticate(c.Request.Context(), rawKey)
+	if err != nil {
+		appErr := errors.GetAppError(err)
+		if appErr == nil {
+			appErr = errors.Wrap(err, errors.ErrCodeInternal, "failed to authenticate API key")
+		}
+		abortWithError(c, appErr)
+		return
+	}
+
+	scope := entity.APIKeyScopeFor(c.Request.Method, c.FullPath())
+	if !apiKey.Allows(scope) {
+		abortWithError(c, errors.Forbidden("API key lacks the "+scope+" scope"))
+		return
+	}
+
+	c.Set(TenantIDKey, apiKey.TenantID)
+	if apiKey.UserID != nil {
+		c.Set(UserIDKey, *apiKey.UserID)
+	}
+	c.Set(UserRoleKey, APIKeyRole)
+	c.Set(APIKeyIDKey, apiKey.ID)
+
+	ctx := encryption.WithTenant(c.Request.Context(), apiKey.TenantID)
+	c.Request = c.Request.WithContext(service.WithAIUsage(ctx, apiKey.TenantID, "", ""))
+
+	c.Next()
+}
+
+// apiKeyFromRequest returns the API key of a request, if it carries one
+func apiKeyFromRequest(c *gin.Context) string {
+	if rawKey := c.GetHeader(APIKeyHeader); rawKey != "" {
+		return rawKey
+	}
+	token := strings.TrimPrefix(c.GetHeader(AuthorizationHeader), BearerPrefix)
+	if service.IsAPIKey(token) {
+		return token
+	}
+	return ""
+}
+
 // RequireRole returns a gin middleware that checks user roles
 func (m *AuthMiddleware) RequireRole(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -134,6 +208,11 @@ func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 		c.Set(UserRoleKey, claims.Role)
 		c.Set(UserEmailKey, claims.Email)
 
+		// Scope the request to the tenant so encrypted data of other tenants is never opened
+		// and the AI calls it makes are metered to the tenant
+		ctx := encryption.WithTenant(c.Request.Context(), claims.TenantID)
+		c.Request = c.Request.WithContext(service.WithAIUsage(ctx, claims.TenantID, "", ""))
+
 		c.Next()
 	}
 }
@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+var gzipWriters = sync.Pool{
+	New: func() interface{} {
+		w, _ := gzip.NewWriterLevel(nil, gzip.BestSpeed)
+		return w
+	},
+}
+
+// gzipResponseWriter compresses everything written to the response
+type gzipResponseWriter struct {
+	gin.ResponseWriter
+	writer  *gzip.Writer
+	written bool
+}
+
+func (w *gzipResponseWriter) Write(data []byte) (int, error) {
+	w.written = true
+	return w.writer.Write(data)
+}
+
+func (w *gzipResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Gzip returns a middleware that compresses responses for clients accepting gzip,
+// for routes used over slow mobile connections
+func Gzip() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !strings.Contains(c.GetHeader("Accept-Encoding"), "gzip") {
+			c.Next()
+			return
+		}
+
+		writer := gzipWriters.Get().(*gzip.Writer)
+		writer.Reset(c.Writer)
+		defer gzipWriters.Put(writer)
+
+		c.Header("Content-Encoding", "gzip")
+		c.Header("Vary", "Accept-Encoding")
+		gz := &gzipResponseWriter{ResponseWriter: c.Writer, writer: writer}
+		c.Writer = gz
+		defer func() {
+			// Bodiless responses such as 204 must stay empty
+			if gz.written {
+				writer.Close()
+			}
+			writer.Reset(io.Discard)
+		}()
+
+		c.Next()
+	}
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// BufferDuringMaintenance returns a middleware that accepts provider webhooks during
+// maintenance without handling them. Requests are stored to be replayed when
+// maintenance ends and answered with 200 so providers keep the webhook active.
+// GET requests, used by providers to verify endpoints, are always handled.
+func BufferDuringMaintenance(maintenance *service.MaintenanceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodGet || !maintenance.BuffersWebhooks(c.Request.Context()) {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, service.MaintenanceMaxWebhookSize+1))
+		if err != nil {
+			abortWithError(c, errors.Validation("failed to read request body"))
+			return
+		}
+		c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+
+		err = maintenance.BufferWebhook(c.Request.Context(), &entity.BufferedWebhook{
+			Method:  c.Request.Method,
+			Host:    c.Request.Host,
+			URI:     c.Request.URL.RequestURI(),
+			Headers: c.Request.Header.Clone(),
+			Body:    body,
+		})
+		if err != nil {
+			// Handle it now rather than lose it; processing picks it up after maintenance
+			logger.Warn("Failed to buffer webhook during maintenance",
+				zap.String("path", c.Request.URL.Path),
+				zap.Error(err),
+			)
+			c.Next()
+			return
+		}
+
+		c.AbortWithStatus(http.StatusOK)
+	}
+}
+
+// RequireAdminToken returns a middleware that only lets requests carrying the system
+// administration token in the X-Admin-Token header through. Every request is refused
+// when no token is configured.
+func RequireAdminToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := c.GetHeader("X-Admin-Token")
+		if token == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			abortWithError(c, errors.Unauthorized("invalid admin token"))
+			return
+		}
+		c.Next()
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -30,10 +31,21 @@ func DefaultRateLimiterConfig() *RateLimiterConfig {
 	}
 }
 
-// RateLimiter implements token bucket rate limiting with Redis
+// RateLimiter implements token bucket rate limiting with Redis. Without a Redis
+// client the counters are kept in process memory, which is only accurate for a
+// single server instance.
 type RateLimiter struct {
 	redis  *redis.Client
 	config *RateLimiterConfig
+
+	mu    sync.Mutex
+	local map[string]*localWindow
+}
+
+// localWindow counts the requests of a key in the current window
+type localWindow struct {
+	start time.Time
+	count int
 }
 
 // NewRateLimiter creates a new rate limiter
@@ -44,6 +56,7 @@ func NewRateLimiter(redisClient *redis.Client, config *RateLimiterConfig) *RateL
 	return &RateLimiter{
 		redis:  redisClient,
 		config: config,
+		local:  make(map[string]*localWindow),
 	}
 }
 
@@ -120,6 +133,15 @@ func (rl *RateLimiter) checkRateLimit(ctx context.Context, key string) (allowed
 	windowStart := now.Truncate(rl.config.WindowDuration)
 	windowEnd := windowStart.Add(rl.config.WindowDuration)
 
+	if rl.redis == nil {
+		count := rl.incrementLocal(key, windowStart)
+		remaining = rl.config.RequestsPerWindow - count
+		if remaining < 0 {
+			remaining = 0
+		}
+		return count <= rl.config.RequestsPerWindow, remaining, windowEnd.Unix(), nil
+	}
+
 	// Use Redis transaction
 	pipe := rl.redis.TxPipeline()
 
@@ -147,8 +169,34 @@ func (rl *RateLimiter) checkRateLimit(ctx context.Context, key string) (allowed
 	return allowed, remaining, resetAt, nil
 }
 
+// incrementLocal counts a request in the in-memory window of a key, dropping
+// windows that have ended
+func (rl *RateLimiter) incrementLocal(key string, windowStart time.Time) int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	window, ok := rl.local[key]
+	if !ok || !window.start.Equal(windowStart) {
+		for k, w := range rl.local {
+			if w.start.Before(windowStart) {
+				delete(rl.local, k)
+			}
+		}
+		window = &localWindow{start: windowStart}
+		rl.local[key] = window
+	}
+	window.count++
+	return window.count
+}
+
 // Reset resets the rate limit for a key
 func (rl *RateLimiter) Reset(ctx context.Context, key string) error {
 	fullKey := fmt.Sprintf("%s%s", rl.config.KeyPrefix, key)
+	if rl.redis == nil {
+		rl.mu.Lock()
+		delete(rl.local, fullKey)
+		rl.mu.Unlock()
+		return nil
+	}
 	return rl.redis.Del(ctx, fullKey).Err()
 }
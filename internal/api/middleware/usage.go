@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/msgfy/linktor/internal/application/service"
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// MeterUsage returns a middleware that counts every authenticated request against the
+// tenant's API call quota, per route template ("GET /api/v1/contacts/:id")
+func MeterUsage(recorder service.UsageRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		tenantID := c.GetString(TenantIDKey)
+		route := c.FullPath()
+		if tenantID == "" || route == "" {
+			return
+		}
+		recorder.RecordUsage(tenantID, entity.QuotaMetricAPICalls, c.Request.Method+" "+route, 1)
+	}
+}
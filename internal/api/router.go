@@ -103,6 +103,7 @@ func (r *Router) Setup() *gin.Engine {
 				tenant.GET("", r.tenantHandler.Get)
 				tenant.PUT("", r.tenantHandler.Update)
 				tenant.GET("/usage", r.tenantHandler.GetUsage)
+				tenant.GET("/quotas", r.tenantHandler.GetQuotas)
 			}
 
 			// User management (admin only)
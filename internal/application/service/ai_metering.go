@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// meteredAIProvider meters the calls made through an AI provider in an AI usage
+// scope and refuses them once the tenant's hard budget is reached
+type meteredAIProvider struct {
+	AIProvider
+	meter *AIUsageService
+}
+
+// Complete generates a completion, metering it under the feature of the scope
+func (p *meteredAIProvider) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	scope, ok := AIUsageScopeFrom(ctx)
+	if ok {
+		if err := p.meter.Allow(ctx, scope.TenantID); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := p.AIProvider.Complete(ctx, req)
+	if err != nil || !ok {
+		return resp, err
+	}
+
+	feature := scope.Feature
+	if feature == "" {
+		feature = entity.AIFeatureResponse
+	}
+	prompt, completion := int64(resp.PromptTokens), int64(resp.CompTokens)
+	if prompt+completion == 0 {
+		prompt = int64(resp.TokensUsed)
+	}
+	p.meter.Record(scope, feature, p.Name(), p.model(resp.Model, req.Model), prompt, completion)
+	return resp, nil
+}
+
+// Embed generates an embedding, metered as an embedding call
+func (p *meteredAIProvider) Embed(ctx context.Context, req *EmbeddingRequest) (*EmbeddingResponse, error) {
+	scope, ok := AIUsageScopeFrom(ctx)
+	if ok {
+		if err := p.meter.Allow(ctx, scope.TenantID); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := p.AIProvider.Embed(ctx, req)
+	if err != nil || !ok {
+		return resp, err
+	}
+
+	tokens := int64(resp.TokensUsed)
+	if tokens == 0 {
+		tokens = estimateTokens(req.Text)
+	}
+	p.meter.Record(scope, entity.AIFeatureEmbedding, p.Name(), p.model(resp.Model, req.Model), tokens, 0)
+	return resp, nil
+}
+
+// EmbedBatch embeds texts with the batch API of the provider, or one by one without
+// it, metered as one embedding call
+func (p *meteredAIProvider) EmbedBatch(ctx context.Context, req *BatchEmbeddingRequest) (*BatchEmbeddingResponse, error) {
+	scope, ok := AIUsageScopeFrom(ctx)
+	if ok {
+		if err := p.meter.Allow(ctx, scope.TenantID); err != nil {
+			return nil, err
+		}
+	}
+
+	var resp *BatchEmbeddingResponse
+	if batcher, isBatcher := p.AIProvider.(BatchEmbeddingProvider); isBatcher {
+		var err error
+		if resp, err = batcher.EmbedBatch(ctx, req); err != nil {
+			return nil, err
+		}
+	} else {
+		resp = &BatchEmbeddingResponse{Embeddings: make([][]float64, len(req.Texts))}
+		for i, text := range req.Texts {
+			single, err := p.AIProvider.Embed(ctx, &EmbeddingRequest{Text: text, Model: req.Model})
+			if err != nil {
+				return nil, err
+			}
+			resp.Embeddings[i] = single.Embedding
+			resp.Model = single.Model
+			resp.TokensUsed += single.TokensUsed
+		}
+	}
+
+	if ok {
+		tokens := int64(resp.TokensUsed)
+		if tokens == 0 {
+			tokens = estimateTokens(req.Texts...)
+		}
+		p.meter.Record(scope, entity.AIFeatureEmbedding, p.Name(), p.model(resp.Model, req.Model), tokens, 0)
+	}
+	return resp, nil
+}
+
+// ClassifyIntent classifies a message, metered as an intent call. Providers report
+// no usage for it, so its tokens are estimated from the message and intents.
+func (p *meteredAIProvider) ClassifyIntent(ctx context.Context, req *IntentClassificationRequest) (*entity.IntentResult, error) {
+	scope, ok := AIUsageScopeFrom(ctx)
+	if ok {
+		if err := p.meter.Allow(ctx, scope.TenantID); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := p.AIProvider.ClassifyIntent(ctx, req)
+	if err != nil || !ok {
+		return result, err
+	}
+
+	prompt := estimateTokens(req.Message, strings.Join(req.Intents, ", "))
+	p.meter.Record(scope, entity.AIFeatureIntent, p.Name(), p.model("", req.Model), prompt, estimateTokens("intent confidence"))
+	return result, nil
+}
+
+// AnalyzeSentiment analyzes a message, metered as a sentiment call with estimated tokens
+func (p *meteredAIProvider) AnalyzeSentiment(ctx context.Context, req *SentimentAnalysisRequest) (*entity.SentimentResult, error) {
+	scope, ok := AIUsageScopeFrom(ctx)
+	if ok {
+		if err := p.meter.Allow(ctx, scope.TenantID); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := p.AIProvider.AnalyzeSentiment(ctx, req)
+	if err != nil || !ok {
+		return result, err
+	}
+
+	p.meter.Record(scope, entity.AIFeatureSentiment, p.Name(), p.model("", req.Model), estimateTokens(req.Message), estimateTokens("sentiment score"))
+	return result, nil
+}
+
+// SupportsTools reports whether the metered provider can call tools
+func (p *meteredAIProvider) SupportsTools() bool {
+	return SupportsTools(p.AIProvider)
+}
+
+// model returns the model a call used: the one reported, the one requested or the
+// provider default
+func (p *meteredAIProvider) model(reported, requested string) string {
+	if reported != "" {
+		return reported
+	}
+	if requested != "" {
+		return requested
+	}
+	return p.DefaultModel()
+}
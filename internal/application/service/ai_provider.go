@@ -6,12 +6,15 @@ import (
 	"sync"
 
 	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
 )
 
 // Message represents a message in the completion request
 type Message struct {
-	Role    string `json:"role"`    // system, user, assistant
-	Content string `json:"content"`
+	Role       string             `json:"role"` // system, user, assistant, tool
+	Content    string             `json:"content"`
+	ToolCalls  []*entity.ToolCall `json:"tool_calls,omitempty"`   // Tools the assistant called
+	ToolCallID string             `json:"tool_call_id,omitempty"` // Call a tool message answers
 }
 
 // CompletionRequest represents a request for AI completion
@@ -51,6 +54,19 @@ type EmbeddingResponse struct {
 	TokensUsed int       `json:"tokens_used"`
 }
 
+// BatchEmbeddingRequest represents a request for the embeddings of several texts at once
+type BatchEmbeddingRequest struct {
+	Texts []string `json:"texts"`
+	Model string   `json:"model,omitempty"`
+}
+
+// BatchEmbeddingResponse represents the embeddings of a batch, in the order of its texts
+type BatchEmbeddingResponse struct {
+	Embeddings [][]float64 `json:"embeddings"`
+	Model      string      `json:"model"`
+	TokensUsed int         `json:"tokens_used"`
+}
+
 // IntentClassificationRequest represents an intent classification request
 type IntentClassificationRequest struct {
 	Message string   `json:"message"`
@@ -91,6 +107,25 @@ type AIProvider interface {
 	IsAvailable() bool
 }
 
+// ToolCallingProvider is implemented by AI providers that support function calling:
+// they offer CompletionRequest.Tools to the model, return the calls it makes in
+// CompletionResponse.ToolCalls and accept the results back as tool messages
+type ToolCallingProvider interface {
+	SupportsTools() bool
+}
+
+// BatchEmbeddingProvider is implemented by AI providers with a batch embedding API,
+// which embeds many texts in one request
+type BatchEmbeddingProvider interface {
+	EmbedBatch(ctx context.Context, req *BatchEmbeddingRequest) (*BatchEmbeddingResponse, error)
+}
+
+// SupportsTools checks if a provider can call tools
+func SupportsTools(provider AIProvider) bool {
+	caller, ok := provider.(ToolCallingProvider)
+	return ok && caller.SupportsTools()
+}
+
 // AIProviderConfig holds common configuration for AI providers
 type AIProviderConfig struct {
 	APIKey        string  `json:"api_key"`
@@ -107,6 +142,7 @@ type AIProviderConfig struct {
 type AIProviderFactory struct {
 	mu        sync.RWMutex
 	providers map[entity.AIProviderType]AIProvider
+	meter     *AIUsageService
 }
 
 // NewAIProviderFactory creates a new AI provider factory
@@ -123,6 +159,14 @@ func (f *AIProviderFactory) Register(provider AIProvider) {
 	f.providers[provider.Name()] = provider
 }
 
+// SetUsageMeter meters the calls made through the providers the factory returns
+// and holds them to the tenant AI budgets
+func (f *AIProviderFactory) SetUsageMeter(meter *AIUsageService) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.meter = meter
+}
+
 // Get returns an AI provider by type
 func (f *AIProviderFactory) Get(providerType entity.AIProviderType) (AIProvider, error) {
 	f.mu.RLock()
@@ -137,9 +181,25 @@ func (f *AIProviderFactory) Get(providerType entity.AIProviderType) (AIProvider,
 		return nil, fmt.Errorf("AI provider not available: %s", providerType)
 	}
 
+	if f.meter != nil {
+		return &meteredAIProvider{AIProvider: provider, meter: f.meter}, nil
+	}
 	return provider, nil
 }
 
+// FirstAvailable returns the first available provider of OpenAI, Anthropic and Ollama,
+// in that order, for features that don't use a bot's provider. A nil factory has none.
+func (f *AIProviderFactory) FirstAvailable() (AIProvider, error) {
+	if f != nil {
+		for _, providerType := range []entity.AIProviderType{entity.AIProviderOpenAI, entity.AIProviderAnthropic, entity.AIProviderOllama} {
+			if provider, err := f.Get(providerType); err == nil {
+				return provider, nil
+			}
+		}
+	}
+	return nil, errors.New(errors.ErrCodeBadRequest, "no AI provider available")
+}
+
 // GetForBot returns the appropriate AI provider for a bot
 func (f *AIProviderFactory) GetForBot(bot *entity.Bot) (AIProvider, error) {
 	return f.Get(bot.Provider)
@@ -104,6 +104,27 @@ func TestAIProviderFactory_GetForBot(t *testing.T) {
 	assert.Equal(t, entity.AIProviderOpenAI, provider.Name())
 }
 
+func TestAIProviderFactory_FirstAvailable(t *testing.T) {
+	factory := NewAIProviderFactory()
+	_, err := factory.FirstAvailable()
+	assert.Error(t, err)
+
+	factory.Register(&testAIProvider{name: entity.AIProviderOllama, available: true, models: []string{"llama3"}})
+	factory.Register(&testAIProvider{name: entity.AIProviderOpenAI, available: false, models: []string{"gpt-4"}})
+	provider, err := factory.FirstAvailable()
+	require.NoError(t, err)
+	assert.Equal(t, entity.AIProviderOllama, provider.Name())
+
+	factory.Register(&testAIProvider{name: entity.AIProviderAnthropic, available: true, models: []string{"claude-3"}})
+	provider, err = factory.FirstAvailable()
+	require.NoError(t, err)
+	assert.Equal(t, entity.AIProviderAnthropic, provider.Name())
+
+	var none *AIProviderFactory
+	_, err = none.FirstAvailable()
+	assert.Error(t, err)
+}
+
 func TestAIProviderFactory_ListAvailable(t *testing.T) {
 	factory := NewAIProviderFactory()
 
@@ -0,0 +1,463 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// EventAIBudgetReached is published when a tenant's AI spending crosses a budget limit
+const EventAIBudgetReached = "ai.budget_reached"
+
+// AI usage settings
+const (
+	AIUsageRecordRetention = 90 * 24 * time.Hour // Per-call records; monthly aggregates are kept
+	aiBudgetRefresh        = time.Minute         // How long a tenant's budget and spending are cached
+	maxAIUsageRecords      = 500
+)
+
+// DefaultAIModelPrices are the USD prices per million tokens used to estimate the cost
+// of AI calls, by model name prefix. Models without a price, such as local Ollama
+// models, cost nothing.
+var DefaultAIModelPrices = map[string]entity.AIModelPrice{
+	"gpt-4o-mini":            {PromptPerMillion: 0.15, CompletionPerMillion: 0.60},
+	"gpt-4o":                 {PromptPerMillion: 2.50, CompletionPerMillion: 10.00},
+	"gpt-4-turbo":            {PromptPerMillion: 10.00, CompletionPerMillion: 30.00},
+	"gpt-4-0125-preview":     {PromptPerMillion: 10.00, CompletionPerMillion: 30.00},
+	"gpt-4-1106-preview":     {PromptPerMillion: 10.00, CompletionPerMillion: 30.00},
+	"gpt-4":                  {PromptPerMillion: 30.00, CompletionPerMillion: 60.00},
+	"gpt-3.5-turbo":          {PromptPerMillion: 0.50, CompletionPerMillion: 1.50},
+	"text-embedding-3-small": {PromptPerMillion: 0.02},
+	"text-embedding-3-large": {PromptPerMillion: 0.13},
+	"text-embedding-ada-002": {PromptPerMillion: 0.10},
+	"claude-3-5-sonnet":      {PromptPerMillion: 3.00, CompletionPerMillion: 15.00},
+	"claude-3-5-haiku":       {PromptPerMillion: 0.80, CompletionPerMillion: 4.00},
+	"claude-3-opus":          {PromptPerMillion: 15.00, CompletionPerMillion: 75.00},
+	"claude-3-sonnet":        {PromptPerMillion: 3.00, CompletionPerMillion: 15.00},
+	"claude-3-haiku":         {PromptPerMillion: 0.25, CompletionPerMillion: 1.25},
+}
+
+// aiUsageScopeKey is the context key of the AI usage scope
+type aiUsageScopeKey struct{}
+
+// AIUsageScope tells whom an AI call is made for
+type AIUsageScope struct {
+	TenantID string
+	BotID    string
+	Feature  entity.AIFeature // Of completions; other calls are metered by kind
+}
+
+// WithAIUsage scopes the AI calls made with the context to a tenant, bot and feature,
+// so they are metered and held to the tenant's budget. Calls made without a scope
+// are not metered.
+func WithAIUsage(ctx context.Context, tenantID, botID string, feature entity.AIFeature) context.Context {
+	return context.WithValue(ctx, aiUsageScopeKey{}, AIUsageScope{TenantID: tenantID, BotID: botID, Feature: feature})
+}
+
+// AIUsageScopeFrom returns the AI usage scope of a context
+func AIUsageScopeFrom(ctx context.Context) (AIUsageScope, bool) {
+	scope, ok := ctx.Value(aiUsageScopeKey{}).(AIUsageScope)
+	return scope, ok && scope.TenantID != ""
+}
+
+// aiSpend is the cached budget and month spending of a tenant
+type aiSpend struct {
+	period   time.Time
+	budget   entity.AIBudget
+	spent    float64
+	loadedAt time.Time
+}
+
+// AIUsageService meters AI calls per tenant, bot and feature and estimates their cost.
+// Calls are recorded in memory and flushed with their monthly aggregates periodically,
+// so metering adds no query to the call path. Tenants over their hard budget have
+// their AI calls refused until the month ends or the budget is raised.
+type AIUsageService struct {
+	repo       repository.AIUsageRepository
+	tenantRepo repository.TenantRepository
+	producer   nats.Publisher
+	prices     map[string]entity.AIModelPrice
+
+	mu      sync.Mutex
+	pending []*entity.AIUsageRecord
+	spend   map[string]*aiSpend
+	now     func() time.Time
+}
+
+// NewAIUsageService creates a new AI usage service
+func NewAIUsageService(repo repository.AIUsageRepository, tenantRepo repository.TenantRepository, producer nats.Publisher) *AIUsageService {
+	return &AIUsageService{
+		repo:       repo,
+		tenantRepo: tenantRepo,
+		producer:   producer,
+		prices:     DefaultAIModelPrices,
+		spend:      make(map[string]*aiSpend),
+		now:        time.Now,
+	}
+}
+
+// SetPrices replaces the model prices used to estimate costs
+func (s *AIUsageService) SetPrices(prices map[string]entity.AIModelPrice) {
+	s.prices = prices
+}
+
+// Price returns the price of a model, matching the longest known name prefix
+func (s *AIUsageService) Price(model string) entity.AIModelPrice {
+	var price entity.AIModelPrice
+	matched := -1
+	for prefix, p := range s.prices {
+		if strings.HasPrefix(model, prefix) && len(prefix) > matched {
+			price, matched = p, len(prefix)
+		}
+	}
+	return price
+}
+
+// Record meters an AI call made in a scope
+func (s *AIUsageService) Record(scope AIUsageScope, feature entity.AIFeature, provider entity.AIProviderType, model string, promptTokens, completionTokens int64) {
+	if scope.TenantID == "" {
+		return
+	}
+	now := s.now()
+	record := &entity.AIUsageRecord{
+		ID:               uuid.New().String(),
+		TenantID:         scope.TenantID,
+		BotID:            scope.BotID,
+		Feature:          feature,
+		Provider:         provider,
+		Model:            model,
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		CostUSD:          s.Price(model).Cost(promptTokens, completionTokens),
+		CreatedAt:        now,
+	}
+
+	s.mu.Lock()
+	s.pending = append(s.pending, record)
+	period, _ := entity.QuotaPeriod(now)
+	if spend := s.spend[scope.TenantID]; spend != nil && spend.period.Equal(period) {
+		spend.spent += record.CostUSD
+	}
+	s.mu.Unlock()
+}
+
+// Allow refuses AI calls of tenants that reached their hard budget this month.
+// Budgets that cannot be read do not block calls.
+func (s *AIUsageService) Allow(ctx context.Context, tenantID string) error {
+	spend, err := s.tenantSpend(ctx, tenantID, false)
+	if err != nil {
+		logger.Warn("Failed to check AI budget", zap.String("tenant_id", tenantID), zap.Error(err))
+		return nil
+	}
+	if spend.budget.Exceeded(spend.spent) == entity.AIBudgetHard {
+		return errors.New(errors.ErrCodeQuotaExceeded, "AI features are paused: the monthly AI budget is exhausted")
+	}
+	return nil
+}
+
+// Flush writes the metered calls and alerts tenants that crossed a budget limit.
+// Calls that fail to be written are kept for the next flush.
+func (s *AIUsageService) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if err := s.repo.SaveRecords(ctx, pending); err != nil {
+		s.mu.Lock()
+		s.pending = append(pending, s.pending...)
+		s.mu.Unlock()
+		return err
+	}
+
+	tenants := make(map[string]bool)
+	for _, record := range pending {
+		tenants[record.TenantID] = true
+	}
+	for tenantID := range tenants {
+		if err := s.CheckBudget(ctx, tenantID); err != nil {
+			logger.Warn("Failed to check AI budget",
+				zap.String("tenant_id", tenantID),
+				zap.Error(err),
+			)
+		}
+	}
+	return nil
+}
+
+// CheckBudget publishes an AI budget event when the tenant's spending crossed a
+// budget limit that was not alerted yet this month
+func (s *AIUsageService) CheckBudget(ctx context.Context, tenantID string) error {
+	spend, err := s.tenantSpend(ctx, tenantID, true)
+	if err != nil {
+		return err
+	}
+	level := spend.budget.Exceeded(spend.spent)
+	if level == "" {
+		return nil
+	}
+
+	// Only the highest crossed level is announced; the soft one is recorded so it
+	// does not alert later in the month
+	levels := []entity.AIBudgetLevel{entity.AIBudgetSoft}
+	if level == entity.AIBudgetHard {
+		levels = append(levels, entity.AIBudgetHard)
+	}
+	for i, l := range levels {
+		if l == entity.AIBudgetSoft && spend.budget.SoftLimitUSD == 0 {
+			continue
+		}
+		created, err := s.repo.CreateBudgetAlert(ctx, &entity.AIBudgetAlert{
+			TenantID:    tenantID,
+			PeriodStart: spend.period,
+			Level:       l,
+			CreatedAt:   s.now(),
+		})
+		if err != nil {
+			return err
+		}
+		if created && i == len(levels)-1 {
+			s.publishBudget(ctx, tenantID, spend, l)
+		}
+	}
+	return nil
+}
+
+// PruneRecords removes per-call records older than the retention
+func (s *AIUsageService) PruneRecords(ctx context.Context) (int64, error) {
+	return s.repo.DeleteRecordsBefore(ctx, s.now().Add(-AIUsageRecordRetention))
+}
+
+// GetReport returns a tenant's AI usage in the month containing the given time,
+// grouped by bot, feature and model, with the budget of the current month
+func (s *AIUsageService) GetReport(ctx context.Context, tenantID string, month time.Time) (*entity.AIUsageReport, error) {
+	periodStart, periodEnd := entity.QuotaPeriod(month)
+	aggregates, err := s.repo.FindAggregates(ctx, tenantID, periodStart)
+	if err != nil {
+		return nil, err
+	}
+	budget, err := s.GetBudget(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &entity.AIUsageReport{
+		TenantID:    tenantID,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+		Budget:      *budget,
+	}
+	byBot := make(map[string]*entity.AIUsageTotals)
+	byFeature := make(map[string]*entity.AIUsageTotals)
+	byModel := make(map[string]*entity.AIUsageTotals)
+	for _, aggregate := range aggregates {
+		addAIUsage(&report.Totals, aggregate)
+		addAIUsage(aiUsageGroup(byBot, aggregate.BotID), aggregate)
+		addAIUsage(aiUsageGroup(byFeature, string(aggregate.Feature)), aggregate)
+		addAIUsage(aiUsageGroup(byModel, aggregate.Model), aggregate)
+	}
+	report.Totals.CostUSD = roundCost(report.Totals.CostUSD)
+	report.ByBot = sortedAIUsage(byBot)
+	report.ByFeature = sortedAIUsage(byFeature)
+	report.ByModel = sortedAIUsage(byModel)
+	return report, nil
+}
+
+// GetMonths returns a tenant's AI usage per month for the last months, oldest first
+func (s *AIUsageService) GetMonths(ctx context.Context, tenantID string, months int) ([]entity.AIUsageMonth, error) {
+	if months <= 0 || months > 24 {
+		months = 12
+	}
+	since := s.now().UTC().AddDate(0, -(months - 1), 0)
+	usage, err := s.repo.SumMonths(ctx, tenantID, since)
+	if err != nil {
+		return nil, err
+	}
+	for i := range usage {
+		usage[i].CostUSD = roundCost(usage[i].CostUSD)
+	}
+	return usage, nil
+}
+
+// ListRecords lists a tenant's AI calls in the month containing the given time, newest first
+func (s *AIUsageService) ListRecords(ctx context.Context, tenantID string, month time.Time, limit int) ([]*entity.AIUsageRecord, error) {
+	if limit <= 0 || limit > maxAIUsageRecords {
+		limit = maxAIUsageRecords
+	}
+	periodStart, periodEnd := entity.QuotaPeriod(month)
+	return s.repo.FindRecords(ctx, tenantID, periodStart, periodEnd, limit)
+}
+
+// GetBudget returns the tenant's AI spending this month against its budget
+func (s *AIUsageService) GetBudget(ctx context.Context, tenantID string) (*entity.AIBudgetStatus, error) {
+	spend, err := s.tenantSpend(ctx, tenantID, true)
+	if err != nil {
+		return nil, err
+	}
+	_, periodEnd := entity.QuotaPeriod(spend.period)
+	return &entity.AIBudgetStatus{
+		AIBudget:          spend.budget,
+		PeriodStart:       spend.period,
+		PeriodEnd:         periodEnd,
+		SpentUSD:          roundCost(spend.spent),
+		SoftLimitExceeded: spend.budget.SoftLimitUSD > 0 && spend.spent >= spend.budget.SoftLimitUSD,
+		Paused:            spend.budget.Exceeded(spend.spent) == entity.AIBudgetHard,
+	}, nil
+}
+
+// UpdateBudget sets the tenant's monthly AI spending limits. Zero removes a limit.
+func (s *AIUsageService) UpdateBudget(ctx context.Context, tenantID string, budget entity.AIBudget) (*entity.AIBudgetStatus, error) {
+	for _, amount := range []float64{budget.SoftLimitUSD, budget.HardLimitUSD} {
+		if amount < 0 || math.IsNaN(amount) || math.IsInf(amount, 0) {
+			return nil, errors.Validation("budget limits must be positive amounts or zero")
+		}
+	}
+	if budget.SoftLimitUSD > 0 && budget.HardLimitUSD > 0 && budget.SoftLimitUSD > budget.HardLimitUSD {
+		return nil, errors.Validation("the soft limit cannot be above the hard limit")
+	}
+
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, errors.New(errors.ErrCodeTenantNotFound, "Tenant not found")
+	}
+	tenant.SetAIBudget(budget)
+	tenant.UpdatedAt = s.now()
+	if err := s.tenantRepo.Update(ctx, tenant); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	delete(s.spend, tenantID)
+	s.mu.Unlock()
+	return s.GetBudget(ctx, tenantID)
+}
+
+// tenantSpend returns the cached budget and month spending of a tenant, reading them
+// again when stale, when the month changed or when fresh is set
+func (s *AIUsageService) tenantSpend(ctx context.Context, tenantID string, fresh bool) (aiSpend, error) {
+	now := s.now()
+	period, _ := entity.QuotaPeriod(now)
+
+	s.mu.Lock()
+	cached := s.spend[tenantID]
+	if cached != nil && !fresh && cached.period.Equal(period) && now.Sub(cached.loadedAt) < aiBudgetRefresh {
+		spend := *cached
+		s.mu.Unlock()
+		return spend, nil
+	}
+	s.mu.Unlock()
+
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return aiSpend{}, err
+	}
+	stored, err := s.repo.SumCost(ctx, tenantID, period)
+	if err != nil {
+		return aiSpend{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	spend := &aiSpend{period: period, budget: tenant.AIBudget(), spent: stored, loadedAt: now}
+	for _, record := range s.pending {
+		if record.TenantID == tenantID && !record.CreatedAt.Before(period) {
+			spend.spent += record.CostUSD
+		}
+	}
+	s.spend[tenantID] = spend
+	return *spend, nil
+}
+
+// publishBudget publishes a budget alert for a level
+func (s *AIUsageService) publishBudget(ctx context.Context, tenantID string, spend aiSpend, level entity.AIBudgetLevel) {
+	if s.producer == nil {
+		return
+	}
+
+	if err := s.producer.PublishEvent(ctx, &nats.Event{
+		Type:     EventAIBudgetReached,
+		TenantID: tenantID,
+		Payload: map[string]interface{}{
+			"level":          string(level),
+			"spent_usd":      roundCost(spend.spent),
+			"soft_limit_usd": spend.budget.SoftLimitUSD,
+			"hard_limit_usd": spend.budget.HardLimitUSD,
+			"paused":         level == entity.AIBudgetHard,
+			"period_start":   spend.period.Format(time.RFC3339),
+		},
+		Timestamp: s.now(),
+	}); err != nil {
+		logger.Warn("Failed to publish AI budget alert",
+			zap.String("tenant_id", tenantID),
+			zap.String("level", string(level)),
+			zap.Error(err),
+		)
+	}
+}
+
+// estimateTokens approximates the tokens of a text for calls whose provider reports
+// no usage, at about four characters per token
+func estimateTokens(texts ...string) int64 {
+	var chars int
+	for _, text := range texts {
+		chars += utf8.RuneCountInString(text)
+	}
+	return int64((chars + 3) / 4)
+}
+
+func aiUsageGroup(groups map[string]*entity.AIUsageTotals, key string) *entity.AIUsageTotals {
+	group, ok := groups[key]
+	if !ok {
+		group = &entity.AIUsageTotals{Key: key}
+		groups[key] = group
+	}
+	return group
+}
+
+func addAIUsage(totals *entity.AIUsageTotals, aggregate *entity.AIUsageAggregate) {
+	totals.Requests += aggregate.Requests
+	totals.PromptTokens += aggregate.PromptTokens
+	totals.CompletionTokens += aggregate.CompletionTokens
+	totals.TotalTokens += aggregate.PromptTokens + aggregate.CompletionTokens
+	totals.CostUSD += aggregate.CostUSD
+}
+
+// sortedAIUsage lists groups by cost, then requests
+func sortedAIUsage(groups map[string]*entity.AIUsageTotals) []entity.AIUsageTotals {
+	result := make([]entity.AIUsageTotals, 0, len(groups))
+	for _, group := range groups {
+		group.CostUSD = roundCost(group.CostUSD)
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CostUSD != result[j].CostUSD {
+			return result[i].CostUSD > result[j].CostUSD
+		}
+		if result[i].Requests != result[j].Requests {
+			return result[i].Requests > result[j].Requests
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// roundCost rounds a USD amount to micro-dollars, the precision costs are stored with
+func roundCost(amount float64) float64 {
+	return math.Round(amount*1e6) / 1e6
+}
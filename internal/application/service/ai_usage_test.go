@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAIUsageRepo struct {
+	records  []*entity.AIUsageRecord
+	alerts   map[string]bool
+	failSave bool
+}
+
+func newFakeAIUsageRepo() *fakeAIUsageRepo {
+	return &fakeAIUsageRepo{alerts: make(map[string]bool)}
+}
+
+func (r *fakeAIUsageRepo) SaveRecords(ctx context.Context, records []*entity.AIUsageRecord) error {
+	if r.failSave {
+		return fmt.Errorf("database unavailable")
+	}
+	r.records = append(r.records, records...)
+	return nil
+}
+
+func (r *fakeAIUsageRepo) FindRecords(ctx context.Context, tenantID string, since, until time.Time, limit int) ([]*entity.AIUsageRecord, error) {
+	var result []*entity.AIUsageRecord
+	for i := len(r.records) - 1; i >= 0 && len(result) < limit; i-- {
+		record := r.records[i]
+		if record.TenantID == tenantID && !record.CreatedAt.Before(since) && record.CreatedAt.Before(until) {
+			result = append(result, record)
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeAIUsageRepo) FindAggregates(ctx context.Context, tenantID string, month time.Time) ([]*entity.AIUsageAggregate, error) {
+	groups := make(map[string]*entity.AIUsageAggregate)
+	var result []*entity.AIUsageAggregate
+	for _, record := range r.records {
+		period, _ := entity.QuotaPeriod(record.CreatedAt)
+		if record.TenantID != tenantID || !period.Equal(month) {
+			continue
+		}
+		key := record.BotID + "/" + string(record.Feature) + "/" + string(record.Provider) + "/" + record.Model
+		aggregate, ok := groups[key]
+		if !ok {
+			aggregate = &entity.AIUsageAggregate{
+				TenantID: tenantID,
+				Month:    month,
+				BotID:    record.BotID,
+				Feature:  record.Feature,
+				Provider: record.Provider,
+				Model:    record.Model,
+			}
+			groups[key] = aggregate
+			result = append(result, aggregate)
+		}
+		aggregate.Requests++
+		aggregate.PromptTokens += record.PromptTokens
+		aggregate.CompletionTokens += record.CompletionTokens
+		aggregate.CostUSD += record.CostUSD
+	}
+	return result, nil
+}
+
+func (r *fakeAIUsageRepo) SumMonths(ctx context.Context, tenantID string, since time.Time) ([]entity.AIUsageMonth, error) {
+	return nil, nil
+}
+
+func (r *fakeAIUsageRepo) SumCost(ctx context.Context, tenantID string, month time.Time) (float64, error) {
+	var cost float64
+	for _, record := range r.records {
+		period, _ := entity.QuotaPeriod(record.CreatedAt)
+		if record.TenantID == tenantID && period.Equal(month) {
+			cost += record.CostUSD
+		}
+	}
+	return cost, nil
+}
+
+func (r *fakeAIUsageRepo) DeleteRecordsBefore(ctx context.Context, before time.Time) (int64, error) {
+	var kept []*entity.AIUsageRecord
+	for _, record := range r.records {
+		if !record.CreatedAt.Before(before) {
+			kept = append(kept, record)
+		}
+	}
+	deleted := int64(len(r.records) - len(kept))
+	r.records = kept
+	return deleted, nil
+}
+
+func (r *fakeAIUsageRepo) CreateBudgetAlert(ctx context.Context, alert *entity.AIBudgetAlert) (bool, error) {
+	key := fmt.Sprintf("%s/%s/%s", alert.TenantID, alert.PeriodStart.Format("2006-01"), alert.Level)
+	if r.alerts[key] {
+		return false, nil
+	}
+	r.alerts[key] = true
+	return true, nil
+}
+
+// tokenAIProvider reports the tokens of its completions
+type tokenAIProvider struct {
+	testAIProvider
+	calls int
+}
+
+func (p *tokenAIProvider) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	p.calls++
+	return &CompletionResponse{Content: "ok", Model: "gpt-4o-mini-2024-07-18", PromptTokens: 1000, CompTokens: 500, TokensUsed: 1500}, nil
+}
+
+type aiUsageFixture struct {
+	service  *AIUsageService
+	repo     *fakeAIUsageRepo
+	tenant   *entity.Tenant
+	producer *testutil.MockProducer
+	inner    *tokenAIProvider
+	provider AIProvider
+}
+
+func newAIUsageFixture(t *testing.T) *aiUsageFixture {
+	tenants := testutil.NewMockTenantRepository()
+	tenant := &entity.Tenant{ID: "t1", Settings: map[string]string{}}
+	tenants.Tenants["t1"] = tenant
+
+	f := &aiUsageFixture{
+		repo:     newFakeAIUsageRepo(),
+		tenant:   tenant,
+		producer: testutil.NewMockProducer(),
+		inner: &tokenAIProvider{testAIProvider: testAIProvider{
+			name:      entity.AIProviderOpenAI,
+			available: true,
+			models:    []string{"gpt-4o-mini"},
+		}},
+	}
+	f.service = NewAIUsageService(f.repo, tenants, f.producer)
+	now := time.Date(2026, 4, 11, 0, 0, 0, 0, time.UTC)
+	f.service.now = func() time.Time { return now }
+
+	factory := NewAIProviderFactory()
+	factory.Register(f.inner)
+	factory.SetUsageMeter(f.service)
+	provider, err := factory.Get(entity.AIProviderOpenAI)
+	require.NoError(t, err)
+	f.provider = provider
+	return f
+}
+
+func TestAIUsageService_Price(t *testing.T) {
+	svc := NewAIUsageService(nil, nil, nil)
+
+	assert.Equal(t, 0.15, svc.Price("gpt-4o-mini-2024-07-18").PromptPerMillion, "the longest prefix wins")
+	assert.Equal(t, 2.50, svc.Price("gpt-4o-2024-08-06").PromptPerMillion)
+	assert.Equal(t, entity.AIModelPrice{}, svc.Price("llama3"), "unknown models cost nothing")
+	assert.InDelta(t, 0.00045, svc.Price("gpt-4o-mini").Cost(1000, 500), 1e-12)
+}
+
+func TestAIUsageService_MetersScopedCalls(t *testing.T) {
+	f := newAIUsageFixture(t)
+	ctx := WithAIUsage(context.Background(), "t1", "bot-1", entity.AIFeatureSummary)
+
+	_, err := f.provider.Complete(ctx, &CompletionRequest{})
+	require.NoError(t, err)
+	_, err = f.provider.Embed(ctx, &EmbeddingRequest{Text: "twelve chars", Model: "text-embedding-3-small"})
+	require.NoError(t, err)
+	_, err = f.provider.AnalyzeSentiment(ctx, &SentimentAnalysisRequest{Message: "I am happy"})
+	require.NoError(t, err)
+	// Calls without a scope are not metered
+	_, err = f.provider.Complete(context.Background(), &CompletionRequest{})
+	require.NoError(t, err)
+
+	require.NoError(t, f.service.Flush(context.Background()))
+	require.Len(t, f.repo.records, 3)
+	assert.Equal(t, entity.AIFeatureSummary, f.repo.records[0].Feature)
+	assert.Equal(t, "bot-1", f.repo.records[0].BotID)
+	assert.Equal(t, "gpt-4o-mini-2024-07-18", f.repo.records[0].Model)
+	assert.Equal(t, int64(1500), f.repo.records[0].TotalTokens())
+	assert.Equal(t, entity.AIFeatureEmbedding, f.repo.records[1].Feature)
+	assert.Equal(t, int64(3), f.repo.records[1].PromptTokens, "unreported tokens are estimated")
+	assert.Equal(t, entity.AIFeatureSentiment, f.repo.records[2].Feature)
+
+	report, err := f.service.GetReport(context.Background(), "t1", f.service.now())
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), report.Totals.Requests)
+	assert.Equal(t, 0.000453, report.Totals.CostUSD, "costs are rounded to micro-dollars")
+	require.Len(t, report.ByFeature, 3)
+	assert.Equal(t, string(entity.AIFeatureSummary), report.ByFeature[0].Key, "groups are ordered by cost")
+	require.Len(t, report.ByBot, 1)
+	assert.Equal(t, "bot-1", report.ByBot[0].Key)
+}
+
+func TestAIUsageService_FlushKeepsFailedRecords(t *testing.T) {
+	f := newAIUsageFixture(t)
+	ctx := WithAIUsage(context.Background(), "t1", "", entity.AIFeatureResponse)
+
+	_, err := f.provider.Complete(ctx, &CompletionRequest{})
+	require.NoError(t, err)
+
+	f.repo.failSave = true
+	require.Error(t, f.service.Flush(context.Background()))
+	f.repo.failSave = false
+	require.NoError(t, f.service.Flush(context.Background()))
+	assert.Len(t, f.repo.records, 1)
+}
+
+func TestAIUsageService_Budget(t *testing.T) {
+	f := newAIUsageFixture(t)
+	ctx := WithAIUsage(context.Background(), "t1", "", entity.AIFeatureResponse)
+
+	// Each completion costs $0.00045
+	_, err := f.service.UpdateBudget(context.Background(), "t1", entity.AIBudget{SoftLimitUSD: 0.0008, HardLimitUSD: 0.0013})
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err = f.provider.Complete(ctx, &CompletionRequest{})
+		require.NoError(t, err)
+	}
+	require.NoError(t, f.service.Flush(context.Background()))
+	require.Len(t, f.producer.Events, 1)
+	assert.Equal(t, EventAIBudgetReached, f.producer.Events[0].Type)
+	assert.Equal(t, "soft", f.producer.Events[0].Payload["level"])
+
+	status, err := f.service.GetBudget(context.Background(), "t1")
+	require.NoError(t, err)
+	assert.True(t, status.SoftLimitExceeded)
+	assert.False(t, status.Paused)
+
+	// Pending calls count toward the budget before they are flushed
+	_, err = f.provider.Complete(ctx, &CompletionRequest{})
+	require.NoError(t, err)
+	_, err = f.provider.Complete(ctx, &CompletionRequest{})
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeQuotaExceeded, errors.GetAppError(err).Code)
+	assert.Equal(t, 3, f.inner.calls, "paused calls never reach the provider")
+
+	require.NoError(t, f.service.Flush(context.Background()))
+	require.NoError(t, f.service.Flush(context.Background()))
+	require.Len(t, f.producer.Events, 2, "each level alerts once a month")
+	assert.Equal(t, "hard", f.producer.Events[1].Payload["level"])
+	assert.Equal(t, true, f.producer.Events[1].Payload["paused"])
+
+	// Raising the limit resumes AI features right away
+	_, err = f.service.UpdateBudget(context.Background(), "t1", entity.AIBudget{HardLimitUSD: 5})
+	require.NoError(t, err)
+	_, err = f.provider.Complete(ctx, &CompletionRequest{})
+	require.NoError(t, err)
+}
+
+func TestAIUsageService_UpdateBudgetValidation(t *testing.T) {
+	f := newAIUsageFixture(t)
+
+	_, err := f.service.UpdateBudget(context.Background(), "t1", entity.AIBudget{SoftLimitUSD: -1})
+	assert.True(t, errors.IsValidation(err))
+	_, err = f.service.UpdateBudget(context.Background(), "t1", entity.AIBudget{SoftLimitUSD: 20, HardLimitUSD: 10})
+	assert.True(t, errors.IsValidation(err))
+
+	status, err := f.service.UpdateBudget(context.Background(), "t1", entity.AIBudget{SoftLimitUSD: 10, HardLimitUSD: 20})
+	require.NoError(t, err)
+	assert.Equal(t, entity.AIBudget{SoftLimitUSD: 10, HardLimitUSD: 20}, status.AIBudget)
+	assert.Equal(t, entity.AIBudget{SoftLimitUSD: 10, HardLimitUSD: 20}, f.tenant.AIBudget())
+
+	_, err = f.service.UpdateBudget(context.Background(), "t1", entity.AIBudget{})
+	require.NoError(t, err)
+	assert.Empty(t, f.tenant.Settings, "zero limits are removed")
+}
@@ -75,6 +75,46 @@ func (s *AnalyticsService) GetChannelAnalytics(ctx context.Context, tenantID str
 	return s.repo.GetChannelAnalytics(ctx, filter)
 }
 
+// GetLabelAnalytics returns conversation metrics grouped by label
+func (s *AnalyticsService) GetLabelAnalytics(ctx context.Context, tenantID string, startDate, endDate time.Time) ([]entity.LabelAnalytics, error) {
+	filter := entity.AnalyticsFilter{
+		TenantID:  tenantID,
+		StartDate: startDate,
+		EndDate:   endDate,
+	}
+	return s.repo.GetLabelAnalytics(ctx, filter)
+}
+
+// GetDispositionAnalytics returns the volume, handle time and CSAT of conversations per disposition
+func (s *AnalyticsService) GetDispositionAnalytics(ctx context.Context, tenantID string, startDate, endDate time.Time) ([]entity.DispositionAnalytics, error) {
+	filter := entity.AnalyticsFilter{
+		TenantID:  tenantID,
+		StartDate: startDate,
+		EndDate:   endDate,
+	}
+	return s.repo.GetDispositionAnalytics(ctx, filter)
+}
+
+// GetReopenAnalytics returns how often resolved conversations were reopened or followed up on
+func (s *AnalyticsService) GetReopenAnalytics(ctx context.Context, tenantID string, startDate, endDate time.Time) (*entity.ReopenAnalytics, error) {
+	filter := entity.AnalyticsFilter{
+		TenantID:  tenantID,
+		StartDate: startDate,
+		EndDate:   endDate,
+	}
+	return s.repo.GetReopenAnalytics(ctx, filter)
+}
+
+// GetLanguageAnalytics returns conversation volume and resolution quality per conversation language
+func (s *AnalyticsService) GetLanguageAnalytics(ctx context.Context, tenantID string, startDate, endDate time.Time) ([]entity.LanguageAnalytics, error) {
+	filter := entity.AnalyticsFilter{
+		TenantID:  tenantID,
+		StartDate: startDate,
+		EndDate:   endDate,
+	}
+	return s.repo.GetLanguageAnalytics(ctx, filter)
+}
+
 // GetDateRange returns the start and end dates based on the period
 func (s *AnalyticsService) GetDateRange(period entity.AnalyticsPeriod, customStart, customEnd *time.Time) (time.Time, time.Time) {
 	now := time.Now().UTC()
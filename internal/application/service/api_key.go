@@ -3,22 +3,44 @@ package service
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/msgfy/linktor/internal/domain/entity"
 	"github.com/msgfy/linktor/internal/domain/repository"
 	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 )
 
-const apiKeyPrefixLength = 12
+const (
+	apiKeyPrefixLength = 12
+	apiKeyRawPrefix    = "lk_"
+	// Verified keys are cached so bcrypt does not run on every request. Revocations
+	// made on another instance apply once its cache entry expires.
+	apiKeyCacheTTL = time.Minute
+	// Last use is written at most this often per key
+	apiKeyTouchInterval = time.Minute
+)
 
-// APIKeyService handles API key generation and persistence.
+// APIKeyService handles API key generation, persistence and authentication.
 type APIKeyService struct {
 	apiKeyRepo repository.APIKeyRepository
+
+	mu       sync.Mutex
+	verified map[string]*verifiedAPIKey // By SHA-256 of the raw key
+	now      func() time.Time
+}
+
+// verifiedAPIKey is a key whose raw value matched its hash
+type verifiedAPIKey struct {
+	apiKey     *entity.APIKey
+	verifiedAt time.Time
 }
 
 // CreateAPIKeyInput represents input for creating an API key.
@@ -38,7 +60,11 @@ type CreateAPIKeyResult struct {
 
 // NewAPIKeyService creates a new API key service.
 func NewAPIKeyService(apiKeyRepo repository.APIKeyRepository) *APIKeyService {
-	return &APIKeyService{apiKeyRepo: apiKeyRepo}
+	return &APIKeyService{
+		apiKeyRepo: apiKeyRepo,
+		verified:   make(map[string]*verifiedAPIKey),
+		now:        time.Now,
+	}
 }
 
 // Create generates and stores a new API key. The raw key is returned only from this method.
@@ -61,12 +87,22 @@ func (s *APIKeyService) Create(ctx context.Context, input *CreateAPIKeyInput) (*
 		return nil, errors.Wrap(err, errors.ErrCodeInternal, "Failed to hash API key")
 	}
 
-	scopes := input.Scopes
+	scopes := make([]string, 0, len(input.Scopes))
+	for _, scope := range input.Scopes {
+		scope = strings.TrimSpace(scope)
+		if !entity.ValidAPIKeyScope(scope) {
+			return nil, errors.New(errors.ErrCodeValidation, "Invalid API key scope: "+scope)
+		}
+		scopes = append(scopes, scope)
+	}
 	if len(scopes) == 0 {
-		scopes = []string{"*"}
+		scopes = []string{entity.APIKeyScopeAll}
 	}
 
-	now := time.Now()
+	now := s.now()
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(now) {
+		return nil, errors.New(errors.ErrCodeValidation, "API key expiry must be in the future")
+	}
 	var userID *string
 	if input.UserID != "" {
 		userID = &input.UserID
@@ -99,12 +135,107 @@ func (s *APIKeyService) List(ctx context.Context, tenantID string) ([]*entity.AP
 	return s.apiKeyRepo.ListByTenant(ctx, tenantID)
 }
 
+// Revoke stops an API key of the current tenant from authenticating. The key stays
+// listed with its revocation time.
+func (s *APIKeyService) Revoke(ctx context.Context, tenantID, id string) (*entity.APIKey, error) {
+	if tenantID == "" || id == "" {
+		return nil, errors.New(errors.ErrCodeValidation, "Tenant ID and API key ID are required")
+	}
+	apiKey, err := s.apiKeyRepo.Revoke(ctx, tenantID, id, s.now())
+	if err != nil {
+		return nil, err
+	}
+	s.forget(id)
+	return apiKey, nil
+}
+
 // Delete removes an API key for the current tenant.
 func (s *APIKeyService) Delete(ctx context.Context, tenantID, id string) error {
 	if tenantID == "" || id == "" {
 		return errors.New(errors.ErrCodeValidation, "Tenant ID and API key ID are required")
 	}
-	return s.apiKeyRepo.Delete(ctx, tenantID, id)
+	if err := s.apiKeyRepo.Delete(ctx, tenantID, id); err != nil {
+		return err
+	}
+	s.forget(id)
+	return nil
+}
+
+// Authenticate returns the active API key matching a raw key.
+func (s *APIKeyService) Authenticate(ctx context.Context, rawKey string) (*entity.APIKey, error) {
+	if !IsAPIKey(rawKey) {
+		return nil, errors.Unauthorized("invalid API key")
+	}
+
+	now := s.now()
+	digest := sha256.Sum256([]byte(rawKey))
+	cacheKey := hex.EncodeToString(digest[:])
+
+	s.mu.Lock()
+	cached := s.verified[cacheKey]
+	s.mu.Unlock()
+
+	var apiKey *entity.APIKey
+	if cached != nil && now.Sub(cached.verifiedAt) < apiKeyCacheTTL {
+		apiKey = cached.apiKey
+	} else {
+		candidates, err := s.apiKeyRepo.FindByPrefix(ctx, rawKey[:apiKeyPrefixLength])
+		if err != nil {
+			return nil, err
+		}
+		for _, candidate := range candidates {
+			if bcrypt.CompareHashAndPassword([]byte(candidate.KeyHash), []byte(rawKey)) == nil {
+				apiKey = candidate
+				break
+			}
+		}
+		if apiKey == nil {
+			return nil, errors.Unauthorized("invalid API key")
+		}
+		s.mu.Lock()
+		s.verified[cacheKey] = &verifiedAPIKey{apiKey: apiKey, verifiedAt: now}
+		s.mu.Unlock()
+	}
+
+	if !apiKey.IsActive(now) {
+		return nil, errors.Unauthorized("API key is expired or revoked")
+	}
+
+	s.touch(ctx, apiKey, now)
+	return apiKey, nil
+}
+
+// IsAPIKey reports whether a credential has the shape of a raw API key rather than a JWT
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, apiKeyRawPrefix) && len(credential) > apiKeyPrefixLength
+}
+
+// touch records the use of a key, at most once per interval
+func (s *APIKeyService) touch(ctx context.Context, apiKey *entity.APIKey, now time.Time) {
+	s.mu.Lock()
+	due := apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= apiKeyTouchInterval
+	if due {
+		apiKey.LastUsedAt = &now
+	}
+	s.mu.Unlock()
+	if !due {
+		return
+	}
+
+	if err := s.apiKeyRepo.TouchLastUsed(ctx, apiKey.ID, now); err != nil {
+		logger.Warn("Failed to record API key use", zap.String("api_key_id", apiKey.ID), zap.Error(err))
+	}
+}
+
+// forget drops the cached verifications of a key
+func (s *APIKeyService) forget(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for cacheKey, cached := range s.verified {
+		if cached.apiKey.ID == id {
+			delete(s.verified, cacheKey)
+		}
+	}
 }
 
 func generateRawAPIKey() (string, error) {
@@ -112,5 +243,5 @@ func generateRawAPIKey() (string, error) {
 	if _, err := rand.Read(bytes); err != nil {
 		return "", err
 	}
-	return "lk_" + hex.EncodeToString(bytes), nil
+	return apiKeyRawPrefix + hex.EncodeToString(bytes), nil
 }
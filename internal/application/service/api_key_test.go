@@ -2,9 +2,12 @@ package service
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -12,10 +15,14 @@ import (
 type mockAPIKeyRepository struct {
 	created *entity.APIKey
 	items   []*entity.APIKey
+	lookups int
+	touches int
 }
 
 func (m *mockAPIKeyRepository) Create(ctx context.Context, apiKey *entity.APIKey) error {
 	m.created = apiKey
+	copied := *apiKey
+	m.items = append(m.items, &copied)
 	return nil
 }
 
@@ -23,6 +30,33 @@ func (m *mockAPIKeyRepository) ListByTenant(ctx context.Context, tenantID string
 	return m.items, nil
 }
 
+func (m *mockAPIKeyRepository) FindByPrefix(ctx context.Context, prefix string) ([]*entity.APIKey, error) {
+	m.lookups++
+	var result []*entity.APIKey
+	for _, item := range m.items {
+		if strings.HasPrefix(item.KeyPrefix, prefix) {
+			copied := *item
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockAPIKeyRepository) Revoke(ctx context.Context, tenantID, id string, revokedAt time.Time) (*entity.APIKey, error) {
+	for _, item := range m.items {
+		if item.TenantID == tenantID && item.ID == id {
+			item.RevokedAt = &revokedAt
+			return item, nil
+		}
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "API key not found")
+}
+
+func (m *mockAPIKeyRepository) TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	m.touches++
+	return nil
+}
+
 func (m *mockAPIKeyRepository) Delete(ctx context.Context, tenantID, id string) error {
 	return nil
 }
@@ -72,3 +106,75 @@ func TestAPIKeyServiceCreateRequiresName(t *testing.T) {
 
 	require.Error(t, err)
 }
+
+func TestAPIKeyServiceCreateValidatesScopesAndExpiry(t *testing.T) {
+	service := NewAPIKeyService(&mockAPIKeyRepository{})
+
+	_, err := service.Create(context.Background(), &CreateAPIKeyInput{
+		TenantID: "tenant-1",
+		Name:     "Bad scope",
+		Scopes:   []string{"conversations:delete"},
+	})
+	require.True(t, errors.IsValidation(err))
+
+	past := time.Now().Add(-time.Hour)
+	_, err = service.Create(context.Background(), &CreateAPIKeyInput{
+		TenantID:  "tenant-1",
+		Name:      "Expired",
+		ExpiresAt: &past,
+	})
+	require.True(t, errors.IsValidation(err))
+}
+
+func TestAPIKeyServiceAuthenticate(t *testing.T) {
+	repo := &mockAPIKeyRepository{}
+	service := NewAPIKeyService(repo)
+	ctx := context.Background()
+
+	result, err := service.Create(ctx, &CreateAPIKeyInput{
+		TenantID: "tenant-1",
+		UserID:   "user-1",
+		Name:     "CRM",
+		Scopes:   []string{"conversations:*", "contacts:read"},
+	})
+	require.NoError(t, err)
+
+	apiKey, err := service.Authenticate(ctx, result.Key)
+	require.NoError(t, err)
+	require.Equal(t, "tenant-1", apiKey.TenantID)
+	require.True(t, apiKey.Allows(entity.APIKeyScopeFor("POST", "/api/v1/conversations/:id/messages")))
+	require.True(t, apiKey.Allows(entity.APIKeyScopeFor("GET", "/api/v1/contacts/:id")))
+	require.False(t, apiKey.Allows(entity.APIKeyScopeFor("PUT", "/api/v1/contacts/:id")))
+
+	// Verified keys are cached and their use is recorded once per interval
+	_, err = service.Authenticate(ctx, result.Key)
+	require.NoError(t, err)
+	require.Equal(t, 1, repo.lookups)
+	require.Equal(t, 1, repo.touches)
+
+	_, err = service.Authenticate(ctx, result.Key[:len(result.Key)-1]+"x")
+	require.True(t, errors.IsUnauthorized(err))
+	_, err = service.Authenticate(ctx, "not-a-key")
+	require.True(t, errors.IsUnauthorized(err))
+
+	_, err = service.Revoke(ctx, "tenant-1", result.APIKey.ID)
+	require.NoError(t, err)
+	_, err = service.Authenticate(ctx, result.Key)
+	require.True(t, errors.IsUnauthorized(err), "revoked keys stop authenticating right away")
+}
+
+func TestAPIKeyServiceAuthenticateExpired(t *testing.T) {
+	repo := &mockAPIKeyRepository{}
+	service := NewAPIKeyService(repo)
+	ctx := context.Background()
+
+	expiresAt := time.Now().Add(time.Hour)
+	result, err := service.Create(ctx, &CreateAPIKeyInput{TenantID: "tenant-1", Name: "Temp", ExpiresAt: &expiresAt})
+	require.NoError(t, err)
+	_, err = service.Authenticate(ctx, result.Key)
+	require.NoError(t, err)
+
+	service.now = func() time.Time { return expiresAt.Add(time.Second) }
+	_, err = service.Authenticate(ctx, result.Key)
+	require.True(t, errors.IsUnauthorized(err))
+}
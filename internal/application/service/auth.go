@@ -18,6 +18,10 @@ type TokenClaims struct {
 	UserID   string `json:"user_id"`
 	Email    string `json:"email"`
 	Role     string `json:"role"`
+	// OrganizationID is set when the tenant is a workspace of an organization
+	OrganizationID string `json:"organization_id,omitempty"`
+	// Purpose is set on MFA challenge tokens, which are only accepted to finish a login
+	Purpose string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -27,6 +31,16 @@ type LoginResult struct {
 	AccessToken  string
 	RefreshToken string
 	ExpiresIn    int64
+
+	// MFARequired is set instead of the tokens when the password was right and a
+	// second factor must be verified with MFAToken; MFAEnrollment when the tenant
+	// requires MFA the user has not set up yet
+	MFARequired   bool
+	MFAEnrollment bool
+	MFAToken      string
+
+	// RecoveryCodes are returned once, when the login enrolled the user in MFA
+	RecoveryCodes []string
 }
 
 // RefreshResult represents the result of a token refresh operation
@@ -36,10 +50,25 @@ type RefreshResult struct {
 	ExpiresIn    int64
 }
 
+// WorkspaceDirectory links a person's accounts across the workspaces of an organization
+type WorkspaceDirectory interface {
+	// OrganizationID returns the organization a tenant is a workspace of, empty when none
+	OrganizationID(ctx context.Context, tenantID string) string
+
+	// WorkspaceAccount returns a user's account in another workspace of their organization
+	WorkspaceAccount(ctx context.Context, user *entity.User, tenantID string) (*entity.User, error)
+
+	// SyncPassword copies a user's password to their accounts in the other workspaces
+	SyncPassword(ctx context.Context, user *entity.User) error
+}
+
 // AuthService handles authentication operations
 type AuthService struct {
-	userRepo repository.UserRepository
-	config   *config.JWTConfig
+	userRepo   repository.UserRepository
+	config     *config.JWTConfig
+	workspaces WorkspaceDirectory
+	mfaRepo    repository.UserMFARepository
+	tenantRepo repository.TenantRepository
 }
 
 // NewAuthService creates a new auth service
@@ -50,6 +79,11 @@ func NewAuthService(userRepo repository.UserRepository, config *config.JWTConfig
 	}
 }
 
+// SetWorkspaceDirectory enables switching between the workspaces of an organization
+func (s *AuthService) SetWorkspaceDirectory(workspaces WorkspaceDirectory) {
+	s.workspaces = workspaces
+}
+
 // Login authenticates a user and returns tokens
 func (s *AuthService) Login(ctx context.Context, email, password string) (*LoginResult, error) {
 	// Find user by email
@@ -68,13 +102,43 @@ func (s *AuthService) Login(ctx context.Context, email, password string) (*Login
 		return nil, errors.New(errors.ErrCodeInvalidCredentials, "Invalid email or password")
 	}
 
+	return s.login(ctx, user)
+}
+
+// SwitchWorkspace exchanges the caller's tokens for tokens of their account in another
+// workspace of their organization
+func (s *AuthService) SwitchWorkspace(ctx context.Context, userID, tenantID string) (*LoginResult, error) {
+	if s.workspaces == nil {
+		return nil, errors.Forbidden("Workspaces are not enabled")
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New(errors.ErrCodeUserNotFound, "User not found")
+	}
+	if user.Status != entity.UserStatusActive {
+		return nil, errors.New(errors.ErrCodeTokenInvalid, "Account is not active")
+	}
+
+	account, err := s.workspaces.WorkspaceAccount(ctx, user, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.login(ctx, account)
+}
+
+// issueLogin generates tokens for a user and records the login
+func (s *AuthService) issueLogin(ctx context.Context, user *entity.User) (*LoginResult, error) {
+	organizationID := s.organizationID(ctx, user)
+
 	// Generate tokens
-	accessToken, err := s.generateAccessToken(user)
+	accessToken, err := s.generateAccessToken(user, organizationID)
 	if err != nil {
 		return nil, errors.Wrap(err, errors.ErrCodeInternal, "Failed to generate access token")
 	}
 
-	refreshToken, err := s.generateRefreshToken(user)
+	refreshToken, err := s.generateRefreshToken(user, organizationID)
 	if err != nil {
 		return nil, errors.Wrap(err, errors.ErrCodeInternal, "Failed to generate refresh token")
 	}
@@ -101,6 +165,9 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*R
 	if err != nil {
 		return nil, errors.New(errors.ErrCodeTokenInvalid, "Invalid refresh token")
 	}
+	if claims.Purpose != "" {
+		return nil, errors.New(errors.ErrCodeTokenInvalid, "Invalid refresh token")
+	}
 
 	// Get user
 	user, err := s.userRepo.FindByID(ctx, claims.UserID)
@@ -114,12 +181,13 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*R
 	}
 
 	// Generate new tokens
-	newAccessToken, err := s.generateAccessToken(user)
+	organizationID := s.organizationID(ctx, user)
+	newAccessToken, err := s.generateAccessToken(user, organizationID)
 	if err != nil {
 		return nil, errors.Wrap(err, errors.ErrCodeInternal, "Failed to generate access token")
 	}
 
-	newRefreshToken, err := s.generateRefreshToken(user)
+	newRefreshToken, err := s.generateRefreshToken(user, organizationID)
 	if err != nil {
 		return nil, errors.Wrap(err, errors.ErrCodeInternal, "Failed to generate refresh token")
 	}
@@ -133,7 +201,14 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*R
 
 // ValidateAccessToken validates an access token and returns claims
 func (s *AuthService) ValidateAccessToken(tokenString string) (*TokenClaims, error) {
-	return s.parseToken(tokenString)
+	claims, err := s.parseToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != "" {
+		return nil, errors.New(errors.ErrCodeTokenInvalid, "Invalid token")
+	}
+	return claims, nil
 }
 
 // ChangePassword changes a user's password
@@ -161,18 +236,34 @@ func (s *AuthService) ChangePassword(ctx context.Context, userID, currentPasswor
 		return errors.Wrap(err, errors.ErrCodeInternal, "Failed to update password")
 	}
 
+	// Accounts in the other workspaces of an organization share the password
+	if s.workspaces != nil {
+		if err := s.workspaces.SyncPassword(ctx, user); err != nil {
+			return errors.Wrap(err, errors.ErrCodeInternal, "Failed to update password in other workspaces")
+		}
+	}
+
 	return nil
 }
 
+// organizationID returns the organization of the user's tenant, empty when none
+func (s *AuthService) organizationID(ctx context.Context, user *entity.User) string {
+	if s.workspaces == nil {
+		return ""
+	}
+	return s.workspaces.OrganizationID(ctx, user.TenantID)
+}
+
 // generateAccessToken generates a new access token
-func (s *AuthService) generateAccessToken(user *entity.User) (string, error) {
+func (s *AuthService) generateAccessToken(user *entity.User, organizationID string) (string, error) {
 	expiresAt := time.Now().Add(time.Duration(s.config.AccessTokenTTL) * time.Minute)
 
 	claims := &TokenClaims{
-		TenantID: user.TenantID,
-		UserID:   user.ID,
-		Email:    user.Email,
-		Role:     string(user.Role),
+		TenantID:       user.TenantID,
+		UserID:         user.ID,
+		Email:          user.Email,
+		Role:           string(user.Role),
+		OrganizationID: organizationID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -186,14 +277,15 @@ func (s *AuthService) generateAccessToken(user *entity.User) (string, error) {
 }
 
 // generateRefreshToken generates a new refresh token
-func (s *AuthService) generateRefreshToken(user *entity.User) (string, error) {
+func (s *AuthService) generateRefreshToken(user *entity.User, organizationID string) (string, error) {
 	expiresAt := time.Now().Add(time.Duration(s.config.RefreshTokenTTL) * time.Hour)
 
 	claims := &TokenClaims{
-		TenantID: user.TenantID,
-		UserID:   user.ID,
-		Email:    user.Email,
-		Role:     string(user.Role),
+		TenantID:       user.TenantID,
+		UserID:         user.ID,
+		Email:          user.Email,
+		Role:           string(user.Role),
+		OrganizationID: organizationID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
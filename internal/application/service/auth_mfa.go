@@ -0,0 +1,528 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// Purposes of MFA challenge tokens
+const (
+	MFATokenPurposeVerify = "mfa"        // Password accepted, a code finishes the login
+	MFATokenPurposeEnroll = "mfa_enroll" // The tenant requires MFA the user must set up first
+)
+
+const (
+	mfaTokenTTL       = 5 * time.Minute
+	mfaMaxAttempts    = 5 // Consecutive wrong codes before verification locks
+	mfaLockout        = 15 * time.Minute
+	totpPeriod        = 30 // Seconds per TOTP time step
+	totpDigits        = 6
+	totpSkew          = 1 // Steps of clock drift accepted either way
+	recoveryCodeCount = 10
+)
+
+// MFASetup is a pending TOTP enrollment, to add to an authenticator app
+type MFASetup struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauth_url"`
+}
+
+// MFAStatus is the second factor state of a user
+type MFAStatus struct {
+	Enabled           bool       `json:"enabled"`
+	Required          bool       `json:"required"` // The tenant requires MFA of the user's role
+	RecoveryCodesLeft int        `json:"recovery_codes_left"`
+	EnabledAt         *time.Time `json:"enabled_at,omitempty"`
+}
+
+// SetMFARepository enables TOTP multi-factor authentication
+func (s *AuthService) SetMFARepository(mfaRepo repository.UserMFARepository) {
+	s.mfaRepo = mfaRepo
+}
+
+// SetTenantRepository enables the MFA enforcement settings of tenants
+func (s *AuthService) SetTenantRepository(tenantRepo repository.TenantRepository) {
+	s.tenantRepo = tenantRepo
+}
+
+// login issues the tokens of a user whose password was verified, or a challenge
+// token when a second factor is enabled or required
+func (s *AuthService) login(ctx context.Context, user *entity.User) (*LoginResult, error) {
+	if s.mfaRepo == nil {
+		return s.issueLogin(ctx, user)
+	}
+
+	mfa, err := s.findMFA(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	purpose := ""
+	if mfa != nil && mfa.Enabled {
+		purpose = MFATokenPurposeVerify
+	} else {
+		required, err := s.requiresMFA(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		if required {
+			purpose = MFATokenPurposeEnroll
+		}
+	}
+	if purpose == "" {
+		return s.issueLogin(ctx, user)
+	}
+
+	token, err := s.generateMFAToken(user, purpose)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "Failed to generate MFA token")
+	}
+	return &LoginResult{
+		MFARequired:   true,
+		MFAEnrollment: purpose == MFATokenPurposeEnroll,
+		MFAToken:      token,
+		ExpiresIn:     int64(mfaTokenTTL.Seconds()),
+	}, nil
+}
+
+// SetupMFA generates a new TOTP secret for a user. It protects logins once EnableMFA
+// confirmed a code of the authenticator app.
+func (s *AuthService) SetupMFA(ctx context.Context, userID string) (*MFASetup, error) {
+	if s.mfaRepo == nil {
+		return nil, errors.Forbidden("Multi-factor authentication is not enabled")
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New(errors.ErrCodeUserNotFound, "User not found")
+	}
+	mfa, err := s.findMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa != nil && mfa.Enabled {
+		return nil, errors.Conflict("Multi-factor authentication is already enabled")
+	}
+
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "Failed to generate MFA secret")
+	}
+	now := time.Now()
+	mfa = &entity.UserMFA{
+		UserID:    user.ID,
+		TenantID:  user.TenantID,
+		Secret:    base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(secret),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.mfaRepo.Save(ctx, mfa); err != nil {
+		return nil, err
+	}
+
+	return &MFASetup{
+		Secret:     mfa.Secret,
+		OTPAuthURL: s.otpauthURL(user, mfa.Secret),
+	}, nil
+}
+
+// EnableMFA confirms the pending TOTP secret of a user with a code of their
+// authenticator app and returns their recovery codes, shown only this once
+func (s *AuthService) EnableMFA(ctx context.Context, userID, code string) ([]string, error) {
+	mfa, err := s.pendingMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.enableMFA(ctx, mfa, code)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of a user, after verifying a code
+func (s *AuthService) RegenerateRecoveryCodes(ctx context.Context, userID, code string) ([]string, error) {
+	mfa, err := s.enabledMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.verifyMFA(ctx, mfa, code, ""); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "Failed to generate recovery codes")
+	}
+	mfa.RecoveryCodes = hashes
+	mfa.UpdatedAt = time.Now()
+	if err := s.mfaRepo.Save(ctx, mfa); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// DisableMFA turns off the second factor of a user, after verifying a code or recovery
+// code. Users whose tenant requires MFA can't turn it off.
+func (s *AuthService) DisableMFA(ctx context.Context, userID, code, recoveryCode string) error {
+	mfa, err := s.enabledMFA(ctx, userID)
+	if err != nil {
+		return err
+	}
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return errors.New(errors.ErrCodeUserNotFound, "User not found")
+	}
+	required, err := s.requiresMFA(ctx, user)
+	if err != nil {
+		return err
+	}
+	if required {
+		return errors.Forbidden("Your workspace requires multi-factor authentication")
+	}
+	if err := s.verifyMFA(ctx, mfa, code, recoveryCode); err != nil {
+		return err
+	}
+	return s.mfaRepo.Delete(ctx, userID)
+}
+
+// GetMFAStatus returns the second factor state of a user
+func (s *AuthService) GetMFAStatus(ctx context.Context, userID string) (*MFAStatus, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, errors.New(errors.ErrCodeUserNotFound, "User not found")
+	}
+	required, err := s.requiresMFA(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	status := &MFAStatus{Required: required}
+	if s.mfaRepo == nil {
+		return status, nil
+	}
+
+	mfa, err := s.findMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa != nil && mfa.Enabled {
+		status.Enabled = true
+		status.RecoveryCodesLeft = len(mfa.RecoveryCodes)
+		status.EnabledAt = mfa.EnabledAt
+	}
+	return status, nil
+}
+
+// SetupLoginMFA starts the enrollment of a user whose login was challenged because
+// their tenant requires MFA
+func (s *AuthService) SetupLoginMFA(ctx context.Context, mfaToken string) (*MFASetup, error) {
+	claims, err := s.parseMFAToken(mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Purpose != MFATokenPurposeEnroll {
+		return nil, errors.Conflict("Multi-factor authentication is already enabled")
+	}
+	return s.SetupMFA(ctx, claims.UserID)
+}
+
+// VerifyLoginMFA finishes a challenged login with a TOTP code or a recovery code. When
+// the login enrolls the user, the code confirms their new secret and the result
+// carries their recovery codes.
+func (s *AuthService) VerifyLoginMFA(ctx context.Context, mfaToken, code, recoveryCode string) (*LoginResult, error) {
+	if s.mfaRepo == nil {
+		return nil, errors.Forbidden("Multi-factor authentication is not enabled")
+	}
+
+	claims, err := s.parseMFAToken(mfaToken)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.FindByID(ctx, claims.UserID)
+	if err != nil {
+		return nil, errors.New(errors.ErrCodeTokenInvalid, "User not found")
+	}
+	if user.Status != entity.UserStatusActive {
+		return nil, errors.New(errors.ErrCodeTokenInvalid, "Account is not active")
+	}
+
+	mfa, err := s.findMFA(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa == nil {
+		return nil, errors.Validation("Set up multi-factor authentication first")
+	}
+
+	if mfa.Enabled {
+		if err := s.verifyMFA(ctx, mfa, code, recoveryCode); err != nil {
+			return nil, err
+		}
+		return s.issueLogin(ctx, user)
+	}
+
+	if claims.Purpose != MFATokenPurposeEnroll {
+		return nil, errors.New(errors.ErrCodeTokenInvalid, "Invalid MFA token")
+	}
+	codes, err := s.enableMFA(ctx, mfa, code)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.issueLogin(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	result.RecoveryCodes = codes
+	return result, nil
+}
+
+// enableMFA confirms a pending secret with a code and generates the recovery codes
+func (s *AuthService) enableMFA(ctx context.Context, mfa *entity.UserMFA, code string) ([]string, error) {
+	if code == "" {
+		return nil, errors.Validation("A code of the authenticator app is required")
+	}
+	if err := s.verifyMFA(ctx, mfa, code, ""); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "Failed to generate recovery codes")
+	}
+	now := time.Now()
+	mfa.Enabled = true
+	mfa.EnabledAt = &now
+	mfa.RecoveryCodes = hashes
+	mfa.UpdatedAt = now
+	if err := s.mfaRepo.Save(ctx, mfa); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// verifyMFA checks a TOTP code, or else a recovery code which is used up. Wrong codes
+// count towards a lockout, and an accepted TOTP code can't be used again.
+func (s *AuthService) verifyMFA(ctx context.Context, mfa *entity.UserMFA, code, recoveryCode string) error {
+	now := time.Now()
+	if mfa.IsLocked(now) {
+		return errors.RateLimited("Too many wrong codes, try again later")
+	}
+	if code == "" && recoveryCode == "" {
+		return errors.Validation("A code or recovery code is required")
+	}
+
+	valid := false
+	if code != "" {
+		if step, ok := validateTOTP(mfa, code, now); ok {
+			mfa.LastUsedStep = step
+			valid = true
+		}
+	} else if i := recoveryCodeIndex(mfa.RecoveryCodes, recoveryCode); i >= 0 {
+		mfa.RecoveryCodes = append(mfa.RecoveryCodes[:i:i], mfa.RecoveryCodes[i+1:]...)
+		valid = true
+	}
+
+	mfa.UpdatedAt = now
+	if valid {
+		mfa.FailedAttempts = 0
+		mfa.LockedUntil = nil
+	} else {
+		mfa.FailedAttempts++
+		if mfa.FailedAttempts >= mfaMaxAttempts {
+			lockedUntil := now.Add(mfaLockout)
+			mfa.LockedUntil = &lockedUntil
+			mfa.FailedAttempts = 0
+		}
+	}
+	if err := s.mfaRepo.Save(ctx, mfa); err != nil {
+		return err
+	}
+	if !valid {
+		return errors.New(errors.ErrCodeInvalidCredentials, "Invalid verification code")
+	}
+	return nil
+}
+
+// findMFA returns the second factor of a user, nil when they never set one up
+func (s *AuthService) findMFA(ctx context.Context, userID string) (*entity.UserMFA, error) {
+	mfa, err := s.mfaRepo.FindByUser(ctx, userID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return mfa, nil
+}
+
+// pendingMFA returns a second factor set up but not confirmed yet
+func (s *AuthService) pendingMFA(ctx context.Context, userID string) (*entity.UserMFA, error) {
+	if s.mfaRepo == nil {
+		return nil, errors.Forbidden("Multi-factor authentication is not enabled")
+	}
+	mfa, err := s.findMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa == nil {
+		return nil, errors.Validation("Set up multi-factor authentication first")
+	}
+	if mfa.Enabled {
+		return nil, errors.Conflict("Multi-factor authentication is already enabled")
+	}
+	return mfa, nil
+}
+
+// enabledMFA returns a confirmed second factor
+func (s *AuthService) enabledMFA(ctx context.Context, userID string) (*entity.UserMFA, error) {
+	if s.mfaRepo == nil {
+		return nil, errors.Forbidden("Multi-factor authentication is not enabled")
+	}
+	mfa, err := s.findMFA(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if mfa == nil || !mfa.Enabled {
+		return nil, errors.Validation("Multi-factor authentication is not enabled")
+	}
+	return mfa, nil
+}
+
+// requiresMFA reports whether the tenant of a user requires MFA of their role
+func (s *AuthService) requiresMFA(ctx context.Context, user *entity.User) (bool, error) {
+	if s.tenantRepo == nil {
+		return false, nil
+	}
+	tenant, err := s.tenantRepo.FindByID(ctx, user.TenantID)
+	if err != nil {
+		return false, err
+	}
+	return tenant.RequiresMFA(user.Role), nil
+}
+
+// otpauthURL returns the key URI authenticator apps scan as a QR code
+func (s *AuthService) otpauthURL(user *entity.User, secret string) string {
+	issuer := s.config.Issuer
+	if issuer == "" {
+		issuer = "Linktor"
+	}
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigits))
+	params.Set("period", fmt.Sprint(totpPeriod))
+	return "otpauth://totp/" + url.PathEscape(issuer+":"+user.Email) + "?" + params.Encode()
+}
+
+// generateMFAToken generates a short-lived challenge token for the second step of a login
+func (s *AuthService) generateMFAToken(user *entity.User, purpose string) (string, error) {
+	now := time.Now()
+	claims := &TokenClaims{
+		TenantID: user.TenantID,
+		UserID:   user.ID,
+		Email:    user.Email,
+		Role:     string(user.Role),
+		Purpose:  purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(mfaTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    s.config.Issuer,
+			Subject:   user.ID,
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(s.config.Secret))
+}
+
+// parseMFAToken parses a challenge token, rejecting access and refresh tokens
+func (s *AuthService) parseMFAToken(tokenString string) (*TokenClaims, error) {
+	claims, err := s.parseToken(tokenString)
+	if err != nil {
+		return nil, errors.New(errors.ErrCodeTokenInvalid, "Invalid or expired MFA token")
+	}
+	if claims.Purpose != MFATokenPurposeVerify && claims.Purpose != MFATokenPurposeEnroll {
+		return nil, errors.New(errors.ErrCodeTokenInvalid, "Invalid MFA token")
+	}
+	return claims, nil
+}
+
+// validateTOTP checks a code against the time steps around now (RFC 6238), skipping
+// steps already used, and returns the step it matched
+func validateTOTP(mfa *entity.UserMFA, code string, now time.Time) (int64, bool) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(mfa.Secret)
+	if err != nil {
+		return 0, false
+	}
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	current := now.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= mfa.LastUsedStep {
+			continue
+		}
+		if hmac.Equal([]byte(totpCode(key, step)), []byte(code)) {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// totpCode computes the code of a time step (RFC 4226 with the step as counter)
+func totpCode(key []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+// generateRecoveryCodes returns new recovery codes, formatted "xxxxx-xxxxx", and
+// the hashes to store
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+	for i := range codes {
+		raw := make([]byte, 7)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(encoding.EncodeToString(raw)[:10])
+		codes[i] = code[:5] + "-" + code[5:]
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+	return codes, hashes, nil
+}
+
+// hashRecoveryCode hashes a recovery code, ignoring case, spaces and dashes
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// recoveryCodeIndex returns the position of a recovery code among the stored hashes, -1 when absent
+func recoveryCodeIndex(hashes []string, code string) int {
+	hash := hashRecoveryCode(code)
+	for i, stored := range hashes {
+		if hmac.Equal([]byte(stored), []byte(hash)) {
+			return i
+		}
+	}
+	return -1
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	"encoding/base32"
+	"strings"
 	"testing"
 	"time"
 
@@ -268,3 +270,182 @@ func TestHashPassword(t *testing.T) {
 	err = bcrypt.CompareHashAndPassword([]byte(hash), []byte("wrong"))
 	assert.Error(t, err)
 }
+
+type mockUserMFARepository struct {
+	mfa map[string]*entity.UserMFA
+}
+
+func (m *mockUserMFARepository) FindByUser(ctx context.Context, userID string) (*entity.UserMFA, error) {
+	mfa, ok := m.mfa[userID]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, "MFA not set up")
+	}
+	copied := *mfa
+	return &copied, nil
+}
+
+func (m *mockUserMFARepository) Save(ctx context.Context, mfa *entity.UserMFA) error {
+	copied := *mfa
+	m.mfa[mfa.UserID] = &copied
+	return nil
+}
+
+func (m *mockUserMFARepository) Delete(ctx context.Context, userID string) error {
+	delete(m.mfa, userID)
+	return nil
+}
+
+func newTestMFAAuthService(t *testing.T) (*AuthService, *mockUserMFARepository, *testutil.MockTenantRepository) {
+	t.Helper()
+	svc, userRepo := newTestAuthService()
+	user := createTestUser(t, "tenant-1", "admin@test.com", "password123")
+	userRepo.Users[user.ID] = user
+
+	mfaRepo := &mockUserMFARepository{mfa: map[string]*entity.UserMFA{}}
+	tenantRepo := testutil.NewMockTenantRepository()
+	tenantRepo.Tenants["tenant-1"] = &entity.Tenant{ID: "tenant-1", Settings: map[string]string{}}
+	svc.SetMFARepository(mfaRepo)
+	svc.SetTenantRepository(tenantRepo)
+	return svc, mfaRepo, tenantRepo
+}
+
+// testTOTP returns the code of a secret some time steps from now
+func testTOTP(t *testing.T, secret string, steps int64) string {
+	t.Helper()
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	require.NoError(t, err)
+	return totpCode(key, time.Now().Unix()/totpPeriod+steps)
+}
+
+func TestTOTPCode(t *testing.T) {
+	// RFC 6238 SHA-1 test vectors, truncated to six digits
+	key := []byte("12345678901234567890")
+	assert.Equal(t, "287082", totpCode(key, 59/30))
+	assert.Equal(t, "081804", totpCode(key, 1111111109/30))
+	assert.Equal(t, "005924", totpCode(key, 1234567890/30))
+}
+
+func TestAuthService_MFA(t *testing.T) {
+	ctx := context.Background()
+	svc, mfaRepo, _ := newTestMFAAuthService(t)
+
+	setup, err := svc.SetupMFA(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Contains(t, setup.OTPAuthURL, "otpauth://totp/linktor-test:admin@test.com?")
+	assert.Contains(t, setup.OTPAuthURL, "secret="+setup.Secret)
+
+	// Logins aren't challenged until a code confirmed the setup
+	result, err := svc.Login(ctx, "admin@test.com", "password123")
+	require.NoError(t, err)
+	assert.False(t, result.MFARequired)
+
+	_, err = svc.EnableMFA(ctx, "user-1", "000000")
+	require.Error(t, err)
+	recoveryCodes, err := svc.EnableMFA(ctx, "user-1", testTOTP(t, setup.Secret, 0))
+	require.NoError(t, err)
+	assert.Len(t, recoveryCodes, recoveryCodeCount)
+	assert.NotContains(t, mfaRepo.mfa["user-1"].RecoveryCodes, recoveryCodes[0])
+
+	_, err = svc.SetupMFA(ctx, "user-1")
+	assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code)
+
+	t.Run("login is challenged", func(t *testing.T) {
+		result, err := svc.Login(ctx, "admin@test.com", "password123")
+		require.NoError(t, err)
+		assert.True(t, result.MFARequired)
+		assert.False(t, result.MFAEnrollment)
+		assert.Empty(t, result.AccessToken)
+		require.NotEmpty(t, result.MFAToken)
+
+		// The challenge token is no access or refresh token
+		_, err = svc.ValidateAccessToken(result.MFAToken)
+		assert.Error(t, err)
+		_, err = svc.RefreshToken(ctx, result.MFAToken)
+		assert.Error(t, err)
+
+		// The code that enabled MFA can't be replayed
+		_, err = svc.VerifyLoginMFA(ctx, result.MFAToken, testTOTP(t, setup.Secret, 0), "")
+		assert.Equal(t, errors.ErrCodeInvalidCredentials, errors.GetAppError(err).Code)
+
+		login, err := svc.VerifyLoginMFA(ctx, result.MFAToken, testTOTP(t, setup.Secret, 1), "")
+		require.NoError(t, err)
+		assert.NotEmpty(t, login.AccessToken)
+		assert.Equal(t, "user-1", login.User.ID)
+
+		_, err = svc.VerifyLoginMFA(ctx, login.AccessToken, testTOTP(t, setup.Secret, 1), "")
+		assert.Equal(t, errors.ErrCodeTokenInvalid, errors.GetAppError(err).Code)
+	})
+
+	t.Run("recovery codes are used once", func(t *testing.T) {
+		result, err := svc.Login(ctx, "admin@test.com", "password123")
+		require.NoError(t, err)
+
+		login, err := svc.VerifyLoginMFA(ctx, result.MFAToken, "", strings.ToUpper(recoveryCodes[2]))
+		require.NoError(t, err)
+		assert.NotEmpty(t, login.AccessToken)
+
+		_, err = svc.VerifyLoginMFA(ctx, result.MFAToken, "", recoveryCodes[2])
+		assert.Error(t, err)
+
+		status, err := svc.GetMFAStatus(ctx, "user-1")
+		require.NoError(t, err)
+		assert.True(t, status.Enabled)
+		assert.Equal(t, recoveryCodeCount-1, status.RecoveryCodesLeft)
+	})
+
+	t.Run("wrong codes lock verification", func(t *testing.T) {
+		mfaRepo.mfa["user-1"].FailedAttempts = 0
+		result, err := svc.Login(ctx, "admin@test.com", "password123")
+		require.NoError(t, err)
+
+		for i := 0; i < mfaMaxAttempts; i++ {
+			_, err = svc.VerifyLoginMFA(ctx, result.MFAToken, "12345", "")
+			assert.Equal(t, errors.ErrCodeInvalidCredentials, errors.GetAppError(err).Code)
+		}
+		_, err = svc.VerifyLoginMFA(ctx, result.MFAToken, "", recoveryCodes[3])
+		assert.Equal(t, errors.ErrCodeRateLimited, errors.GetAppError(err).Code)
+
+		mfaRepo.mfa["user-1"].LockedUntil = nil
+	})
+
+	t.Run("disable", func(t *testing.T) {
+		require.NoError(t, svc.DisableMFA(ctx, "user-1", "", recoveryCodes[4]))
+		assert.Empty(t, mfaRepo.mfa)
+
+		result, err := svc.Login(ctx, "admin@test.com", "password123")
+		require.NoError(t, err)
+		assert.False(t, result.MFARequired)
+		assert.NotEmpty(t, result.AccessToken)
+	})
+}
+
+func TestAuthService_MFAEnforcement(t *testing.T) {
+	ctx := context.Background()
+	svc, _, tenantRepo := newTestMFAAuthService(t)
+	tenantRepo.Tenants["tenant-1"].Settings[entity.TenantSettingMFARequiredRoles] = "owner, admin"
+
+	result, err := svc.Login(ctx, "admin@test.com", "password123")
+	require.NoError(t, err)
+	assert.True(t, result.MFARequired)
+	assert.True(t, result.MFAEnrollment)
+	assert.Empty(t, result.AccessToken)
+
+	_, err = svc.VerifyLoginMFA(ctx, result.MFAToken, "123456", "")
+	assert.Error(t, err)
+
+	setup, err := svc.SetupLoginMFA(ctx, result.MFAToken)
+	require.NoError(t, err)
+
+	login, err := svc.VerifyLoginMFA(ctx, result.MFAToken, testTOTP(t, setup.Secret, 0), "")
+	require.NoError(t, err)
+	assert.NotEmpty(t, login.AccessToken)
+	assert.Len(t, login.RecoveryCodes, recoveryCodeCount)
+
+	status, err := svc.GetMFAStatus(ctx, "user-1")
+	require.NoError(t, err)
+	assert.True(t, status.Enabled)
+	assert.True(t, status.Required)
+
+	err = svc.DisableMFA(ctx, "user-1", testTOTP(t, setup.Secret, 1), "")
+	assert.Equal(t, errors.ErrCodeForbidden, errors.GetAppError(err).Code)
+}
@@ -0,0 +1,367 @@
+package service
+
+import (
+	"context"
+	"maps"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// EventConversationAutoUnassigned is published when a conversation was taken from an
+// unavailable agent
+const EventConversationAutoUnassigned = "conversation.auto_unassigned"
+
+// awaitingFirstReplyBatchSize is how many waiting conversations one run checks
+const awaitingFirstReplyBatchSize = 500
+
+// maxSupervisorsNotified caps how many users are loaded to find a tenant's supervisors
+const maxSupervisorsNotified = 500
+
+// Conversation metadata written by automatic unassignment
+const (
+	conversationUnassignedFromKey = "auto_unassigned_from"
+	conversationUnassignedAtKey   = "auto_unassigned_at"
+)
+
+// AgentPresenceTracker reports the live availability of agents
+type AgentPresenceTracker interface {
+	IsAgentAvailable(tenantID, userID string) bool
+	UnavailableSince(tenantID, userID string) (entity.PresenceStatus, time.Time, bool)
+}
+
+// SupervisorNotifier delivers agent away notices to supervisors
+type SupervisorNotifier interface {
+	NotifySupervisors(userIDs []string, notice *entity.AgentAwayNotice)
+}
+
+// AutoUnassignService takes the conversations waiting for a first reply away from
+// agents who went offline or stayed away longer than their tenant's grace period,
+// routes them to available agents like escalations and tells the supervisors. Every
+// reassignment is kept as an audit record.
+type AutoUnassignService struct {
+	conversationRepo repository.ConversationRepository
+	reassignmentRepo repository.ConversationReassignmentRepository
+	tenantRepo       repository.TenantRepository
+	userRepo         repository.UserRepository
+	presence         AgentPresenceTracker
+	router           *RoutingService
+	notifier         SupervisorNotifier
+	maintenance      *ChannelMaintenanceService
+	producer         nats.Publisher
+	now              func() time.Time
+}
+
+// NewAutoUnassignService creates a new auto-unassign service
+func NewAutoUnassignService(
+	conversationRepo repository.ConversationRepository,
+	reassignmentRepo repository.ConversationReassignmentRepository,
+	tenantRepo repository.TenantRepository,
+	userRepo repository.UserRepository,
+	presence AgentPresenceTracker,
+	producer nats.Publisher,
+) *AutoUnassignService {
+	return &AutoUnassignService{
+		conversationRepo: conversationRepo,
+		reassignmentRepo: reassignmentRepo,
+		tenantRepo:       tenantRepo,
+		userRepo:         userRepo,
+		presence:         presence,
+		producer:         producer,
+		now:              time.Now,
+	}
+}
+
+// SetRouter makes re-routing follow the tenant's routing rules
+func (s *AutoUnassignService) SetRouter(router *RoutingService) {
+	s.router = router
+}
+
+// SetNotifier sets the notifier used to tell supervisors about re-routed conversations
+func (s *AutoUnassignService) SetNotifier(notifier SupervisorNotifier) {
+	s.notifier = notifier
+}
+
+// SetChannelMaintenance pauses unassignment on channels under maintenance: their
+// conversations stay put and the grace period does not run during a window
+func (s *AutoUnassignService) SetChannelMaintenance(maintenance *ChannelMaintenanceService) {
+	s.maintenance = maintenance
+}
+
+// unavailableAgent is an agent past their grace period and what was taken from them
+type unavailableAgent struct {
+	tenantID      string
+	userID        string
+	status        entity.PresenceStatus
+	since         time.Time
+	grace         time.Duration
+	reassignments []*entity.ConversationReassignment
+}
+
+// Run re-routes the waiting conversations of agents past their grace period and
+// returns how many were reassigned
+func (s *AutoUnassignService) Run(ctx context.Context) (int, error) {
+	conversations, err := s.conversationRepo.FindAwaitingFirstReply(ctx, awaitingFirstReplyBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	now := s.now()
+	tenants := make(map[string]*entity.Tenant)
+	agents := make(map[string]*unavailableAgent)
+	var order []*unavailableAgent
+	reassigned := 0
+
+	for _, conversation := range conversations {
+		userID := *conversation.AssignedUserID
+		agent, checked := agents[userID]
+		if !checked {
+			agent = s.checkAgent(ctx, conversation.TenantID, userID, now, tenants)
+			agents[userID] = agent
+			if agent != nil {
+				order = append(order, agent)
+			}
+		}
+		if agent == nil || s.pausedByMaintenance(ctx, conversation, agent, now) {
+			continue
+		}
+
+		reassignment, err := s.reassign(ctx, conversation, agent)
+		if err != nil {
+			logger.Warn("Failed to re-route conversation of unavailable agent",
+				zap.String("conversation_id", conversation.ID),
+				zap.String("agent_id", userID),
+				zap.Error(err))
+			continue
+		}
+		agent.reassignments = append(agent.reassignments, reassignment)
+		reassigned++
+	}
+
+	for _, agent := range order {
+		if len(agent.reassignments) > 0 {
+			s.notifySupervisors(ctx, agent)
+		}
+	}
+	return reassigned, nil
+}
+
+// ListReassignments lists a tenant's reassignments, newest first, optionally those
+// from or to one user
+func (s *AutoUnassignService) ListReassignments(ctx context.Context, tenantID, userID string, params *repository.ListParams) ([]*entity.ConversationReassignment, int64, error) {
+	if params == nil {
+		params = repository.NewListParams()
+	}
+	if params.Filters == nil {
+		params.Filters = make(map[string]interface{})
+	}
+	if userID != "" {
+		params.Filters["user_id"] = userID
+	}
+	return s.reassignmentRepo.ListByTenant(ctx, tenantID, params)
+}
+
+// ListConversationReassignments lists the reassignments of a tenant's conversation, newest first
+func (s *AutoUnassignService) ListConversationReassignments(ctx context.Context, tenantID, conversationID string) ([]*entity.ConversationReassignment, error) {
+	conversation, err := s.conversationRepo.FindByID(ctx, conversationID)
+	if err != nil || conversation.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+	}
+	return s.reassignmentRepo.ListByConversation(ctx, conversationID)
+}
+
+// checkAgent returns the agent when they have been unavailable past their tenant's
+// grace period, nil otherwise
+func (s *AutoUnassignService) checkAgent(ctx context.Context, tenantID, userID string, now time.Time, tenants map[string]*entity.Tenant) *unavailableAgent {
+	tenant, ok := tenants[tenantID]
+	if !ok {
+		var err error
+		if tenant, err = s.tenantRepo.FindByID(ctx, tenantID); err != nil {
+			tenant = nil
+		}
+		tenants[tenantID] = tenant
+	}
+	if tenant == nil || !tenant.AutoUnassignEnabled() {
+		return nil
+	}
+
+	status, since, unavailable := s.presence.UnavailableSince(tenantID, userID)
+	if !unavailable {
+		return nil
+	}
+	grace, ok := tenant.AutoUnassignGrace(status)
+	if !ok || now.Sub(since) < grace {
+		return nil
+	}
+	return &unavailableAgent{tenantID: tenantID, userID: userID, status: status, since: since, grace: grace}
+}
+
+// pausedByMaintenance reports whether a conversation must stay with its agent because
+// its channel is under maintenance, or was long enough that the agent's grace period,
+// which does not run during maintenance, has not elapsed yet
+func (s *AutoUnassignService) pausedByMaintenance(ctx context.Context, conversation *entity.Conversation, agent *unavailableAgent, now time.Time) bool {
+	if s.maintenance == nil {
+		return false
+	}
+	if s.maintenance.ActiveWindow(ctx, conversation.ChannelID) != nil {
+		return true
+	}
+	paused := s.maintenance.PausedDuration(ctx, conversation.ChannelID, agent.since, now)
+	return now.Sub(agent.since)-paused < agent.grace
+}
+
+// reassign routes a conversation away from an unavailable agent, back to the queue
+// when no available agent can take it, and records the reassignment
+func (s *AutoUnassignService) reassign(ctx context.Context, conversation *entity.Conversation, agent *unavailableAgent) (*entity.ConversationReassignment, error) {
+	if conversation.Metadata == nil {
+		conversation.Metadata = make(map[string]string)
+	}
+	metadata := maps.Clone(conversation.Metadata)
+	toUserID, ruleID := s.route(ctx, conversation, agent.userID)
+
+	now := s.now()
+	if toUserID != "" {
+		conversation.AssignedUserID = &toUserID
+		conversation.Status = entity.ConversationStatusOpen
+	} else {
+		conversation.AssignedUserID = nil
+		conversation.Status = entity.ConversationStatusPending
+	}
+	if ruleID != "" {
+		conversation.Metadata["routing_rule"] = ruleID
+	}
+	conversation.Metadata[conversationUnassignedFromKey] = agent.userID
+	conversation.Metadata[conversationUnassignedAtKey] = now.UTC().Format(time.RFC3339)
+	if err := s.conversationRepo.Update(ctx, conversation); err != nil {
+		return nil, err
+	}
+	if err := s.conversationRepo.SetMetadata(ctx, conversation.ID, conversation.ChangedMetadata(metadata)); err != nil {
+		return nil, err
+	}
+
+	reason := entity.ReassignmentReasonAgentOffline
+	if agent.status == entity.PresenceStatusAway {
+		reason = entity.ReassignmentReasonAgentIdle
+	}
+	reassignment := &entity.ConversationReassignment{
+		ID:               uuid.New().String(),
+		TenantID:         conversation.TenantID,
+		ConversationID:   conversation.ID,
+		FromUserID:       agent.userID,
+		ToUserID:         toUserID,
+		Reason:           reason,
+		RoutingRuleID:    ruleID,
+		UnavailableSince: agent.since,
+		CreatedAt:        now,
+	}
+	if err := s.reassignmentRepo.Create(ctx, reassignment); err != nil {
+		logger.Warn("Failed to record conversation reassignment",
+			zap.String("conversation_id", conversation.ID),
+			zap.Error(err))
+	}
+
+	logger.Info("Re-routed conversation of unavailable agent",
+		zap.String("conversation_id", conversation.ID),
+		zap.String("from_user_id", agent.userID),
+		zap.String("to_user_id", toUserID),
+		zap.String("reason", string(reason)))
+	s.publish(ctx, reassignment)
+	return reassignment, nil
+}
+
+// route picks an available agent other than the one leaving, by the tenant's routing
+// rules and otherwise by lowest load. It returns no agent when the conversation must
+// wait in the queue.
+func (s *AutoUnassignService) route(ctx context.Context, conversation *entity.Conversation, fromUserID string) (string, string) {
+	agents, err := s.userRepo.FindAvailableAgents(ctx, conversation.TenantID, conversation.ChannelID)
+	if err != nil {
+		return "", ""
+	}
+	candidates := make([]*entity.User, 0, len(agents))
+	for _, agent := range agents {
+		if agent.ID != fromUserID && s.presence.IsAgentAvailable(conversation.TenantID, agent.ID) {
+			candidates = append(candidates, agent)
+		}
+	}
+
+	if s.router != nil {
+		decision, err := s.router.Route(ctx, conversation, candidates)
+		if err == nil && decision != nil {
+			return decision.AgentID, decision.RuleID
+		}
+	}
+
+	var best string
+	lowest := int64(-1)
+	for _, agent := range candidates {
+		load, err := s.conversationRepo.CountActiveByUser(ctx, agent.ID)
+		if err != nil {
+			continue
+		}
+		if lowest < 0 || load < lowest {
+			best, lowest = agent.ID, load
+		}
+	}
+	return best, ""
+}
+
+// notifySupervisors tells the tenant's supervisors which conversations an agent lost
+func (s *AutoUnassignService) notifySupervisors(ctx context.Context, agent *unavailableAgent) {
+	if s.notifier == nil {
+		return
+	}
+	params := repository.NewListParams()
+	params.PageSize = maxSupervisorsNotified
+	users, _, err := s.userRepo.FindByTenant(ctx, agent.tenantID, params)
+	if err != nil {
+		logger.Warn("Failed to load supervisors", zap.String("tenant_id", agent.tenantID), zap.Error(err))
+		return
+	}
+
+	var supervisors []string
+	for _, user := range users {
+		if user.Status == entity.UserStatusActive && user.CanManageChannels() {
+			supervisors = append(supervisors, user.ID)
+		}
+	}
+	if len(supervisors) == 0 {
+		return
+	}
+	s.notifier.NotifySupervisors(supervisors, &entity.AgentAwayNotice{
+		AgentID:          agent.userID,
+		Status:           agent.status,
+		UnavailableSince: agent.since,
+		Reassignments:    agent.reassignments,
+	})
+}
+
+func (s *AutoUnassignService) publish(ctx context.Context, reassignment *entity.ConversationReassignment) {
+	if s.producer == nil {
+		return
+	}
+	payload := map[string]interface{}{
+		"conversation_id":   reassignment.ConversationID,
+		"from_user_id":      reassignment.FromUserID,
+		"reason":            string(reassignment.Reason),
+		"unavailable_since": reassignment.UnavailableSince.Format(time.RFC3339),
+	}
+	if reassignment.ToUserID != "" {
+		payload["to_user_id"] = reassignment.ToUserID
+	}
+	if err := s.producer.PublishEvent(ctx, &nats.Event{
+		Type:      EventConversationAutoUnassigned,
+		TenantID:  reassignment.TenantID,
+		Payload:   payload,
+		Timestamp: reassignment.CreatedAt,
+	}); err != nil {
+		logger.Warn("Failed to publish conversation reassignment",
+			zap.String("conversation_id", reassignment.ConversationID),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePresence struct {
+	unavailable map[string]entity.PresenceStatus
+	since       time.Time
+}
+
+func (f *fakePresence) IsAgentAvailable(tenantID, userID string) bool {
+	_, ok := f.unavailable[userID]
+	return !ok
+}
+
+func (f *fakePresence) UnavailableSince(tenantID, userID string) (entity.PresenceStatus, time.Time, bool) {
+	status, ok := f.unavailable[userID]
+	return status, f.since, ok
+}
+
+type fakeSupervisorNotifier struct {
+	userIDs []string
+	notices []*entity.AgentAwayNotice
+}
+
+func (f *fakeSupervisorNotifier) NotifySupervisors(userIDs []string, notice *entity.AgentAwayNotice) {
+	f.userIDs = userIDs
+	f.notices = append(f.notices, notice)
+}
+
+type mockReassignmentRepository struct {
+	reassignments []*entity.ConversationReassignment
+}
+
+func (m *mockReassignmentRepository) Create(ctx context.Context, reassignment *entity.ConversationReassignment) error {
+	m.reassignments = append(m.reassignments, reassignment)
+	return nil
+}
+
+func (m *mockReassignmentRepository) ListByTenant(ctx context.Context, tenantID string, params *repository.ListParams) ([]*entity.ConversationReassignment, int64, error) {
+	var result []*entity.ConversationReassignment
+	for _, r := range m.reassignments {
+		userID, _ := params.Filters["user_id"].(string)
+		if r.TenantID == tenantID && (userID == "" || r.FromUserID == userID || r.ToUserID == userID) {
+			result = append(result, r)
+		}
+	}
+	return result, int64(len(result)), nil
+}
+
+func (m *mockReassignmentRepository) ListByConversation(ctx context.Context, conversationID string) ([]*entity.ConversationReassignment, error) {
+	var result []*entity.ConversationReassignment
+	for _, r := range m.reassignments {
+		if r.ConversationID == conversationID {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+type autoUnassignFixture struct {
+	svc           *AutoUnassignService
+	conversations *testutil.MockConversationRepository
+	tenants       *testutil.MockTenantRepository
+	users         *testutil.MockUserRepository
+	reassignments *mockReassignmentRepository
+	presence      *fakePresence
+	notifier      *fakeSupervisorNotifier
+	producer      *testutil.MockProducer
+	now           time.Time
+}
+
+func newAutoUnassignFixture() *autoUnassignFixture {
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	f := &autoUnassignFixture{
+		conversations: testutil.NewMockConversationRepository(),
+		tenants:       testutil.NewMockTenantRepository(),
+		users:         testutil.NewMockUserRepository(),
+		reassignments: &mockReassignmentRepository{},
+		presence:      &fakePresence{unavailable: map[string]entity.PresenceStatus{}, since: now.Add(-10 * time.Minute)},
+		notifier:      &fakeSupervisorNotifier{},
+		producer:      testutil.NewMockProducer(),
+		now:           now,
+	}
+	f.tenants.Tenants["tenant-1"] = &entity.Tenant{ID: "tenant-1", Settings: map[string]string{entity.TenantSettingAutoUnassign: "true"}}
+	for _, user := range []*entity.User{
+		{ID: "agent-1", TenantID: "tenant-1", Role: entity.UserRoleAgent, Status: entity.UserStatusActive},
+		{ID: "agent-2", TenantID: "tenant-1", Role: entity.UserRoleAgent, Status: entity.UserStatusActive},
+		{ID: "agent-3", TenantID: "tenant-1", Role: entity.UserRoleAgent, Status: entity.UserStatusActive},
+		{ID: "supervisor-1", TenantID: "tenant-1", Role: entity.UserRoleSupervisor, Status: entity.UserStatusActive},
+	} {
+		f.users.Users[user.ID] = user
+	}
+
+	f.svc = NewAutoUnassignService(f.conversations, f.reassignments, f.tenants, f.users, f.presence, f.producer)
+	f.svc.SetNotifier(f.notifier)
+	f.svc.now = func() time.Time { return f.now }
+	return f
+}
+
+func (f *autoUnassignFixture) assign(id, userID string, status entity.ConversationStatus) *entity.Conversation {
+	conversation := &entity.Conversation{
+		ID:             id,
+		TenantID:       "tenant-1",
+		ChannelID:      "channel-1",
+		Status:         status,
+		AssignedUserID: &userID,
+		CreatedAt:      f.now.Add(-time.Duration(len(f.conversations.Conversations)+1) * time.Minute),
+	}
+	f.conversations.Conversations[id] = conversation
+	return conversation
+}
+
+func TestAutoUnassignService_ReroutesOfflineAgent(t *testing.T) {
+	f := newAutoUnassignFixture()
+	ctx := context.Background()
+
+	waiting := f.assign("conv-1", "agent-1", entity.ConversationStatusOpen)
+	f.assign("conv-2", "agent-2", entity.ConversationStatusOpen)
+	f.presence.unavailable["agent-1"] = entity.PresenceStatusOffline
+
+	reassigned, err := f.svc.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, reassigned)
+
+	require.NotNil(t, waiting.AssignedUserID)
+	assert.Equal(t, "agent-3", *waiting.AssignedUserID, "the least loaded available agent takes it")
+	assert.Equal(t, "agent-1", waiting.Metadata[conversationUnassignedFromKey])
+
+	require.Len(t, f.reassignments.reassignments, 1)
+	reassignment := f.reassignments.reassignments[0]
+	assert.Equal(t, "agent-1", reassignment.FromUserID)
+	assert.Equal(t, "agent-3", reassignment.ToUserID)
+	assert.Equal(t, entity.ReassignmentReasonAgentOffline, reassignment.Reason)
+
+	require.Len(t, f.producer.Events, 1)
+	assert.Equal(t, EventConversationAutoUnassigned, f.producer.Events[0].Type)
+
+	assert.Equal(t, []string{"supervisor-1"}, f.notifier.userIDs)
+	require.Len(t, f.notifier.notices, 1)
+	assert.Equal(t, "agent-1", f.notifier.notices[0].AgentID)
+	assert.Len(t, f.notifier.notices[0].Reassignments, 1)
+
+	listed, total, err := f.svc.ListReassignments(ctx, "tenant-1", "agent-3", nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, reassignment, listed[0])
+}
+
+func TestAutoUnassignService_QueuesWhenNobodyIsAvailable(t *testing.T) {
+	f := newAutoUnassignFixture()
+
+	waiting := f.assign("conv-1", "agent-1", entity.ConversationStatusOpen)
+	f.presence.unavailable["agent-1"] = entity.PresenceStatusAway
+	f.presence.unavailable["agent-2"] = entity.PresenceStatusOffline
+	f.presence.unavailable["agent-3"] = entity.PresenceStatusBusy
+	f.presence.since = f.now.Add(-20 * time.Minute)
+
+	reassigned, err := f.svc.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, reassigned)
+
+	assert.Nil(t, waiting.AssignedUserID)
+	assert.Equal(t, entity.ConversationStatusPending, waiting.Status)
+	require.Len(t, f.reassignments.reassignments, 1)
+	assert.Empty(t, f.reassignments.reassignments[0].ToUserID)
+	assert.Equal(t, entity.ReassignmentReasonAgentIdle, f.reassignments.reassignments[0].Reason)
+}
+
+func TestAutoUnassignService_RespectsGraceAndSettings(t *testing.T) {
+	f := newAutoUnassignFixture()
+	ctx := context.Background()
+
+	replied := f.assign("conv-1", "agent-1", entity.ConversationStatusOpen)
+	replied.FirstReplyAt = &f.now
+	waiting := f.assign("conv-2", "agent-1", entity.ConversationStatusOpen)
+	f.presence.unavailable["agent-1"] = entity.PresenceStatusAway
+
+	reassigned, err := f.svc.Run(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, reassigned, "away agents get the idle grace period")
+
+	f.presence.unavailable["agent-1"] = entity.PresenceStatusBusy
+	reassigned, err = f.svc.Run(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, reassigned, "busy agents keep their conversations")
+
+	f.presence.unavailable["agent-1"] = entity.PresenceStatusOffline
+	f.tenants.Tenants["tenant-1"].Settings[entity.TenantSettingAutoUnassign] = "false"
+	reassigned, err = f.svc.Run(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, reassigned, "the tenant turned it off")
+
+	f.tenants.Tenants["tenant-1"].Settings[entity.TenantSettingAutoUnassign] = "true"
+	f.tenants.Tenants["tenant-1"].Settings[entity.TenantSettingAutoUnassignOfflineGrace] = "900"
+	reassigned, err = f.svc.Run(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, reassigned, "within the tenant's offline grace period")
+
+	assert.Equal(t, "agent-1", *waiting.AssignedUserID)
+	assert.Equal(t, "agent-1", *replied.AssignedUserID)
+	assert.Empty(t, f.notifier.notices)
+}
+
+func TestAutoUnassignService_PausesDuringChannelMaintenance(t *testing.T) {
+	f := newAutoUnassignFixture()
+	ctx := context.Background()
+
+	maintenanceRepo := newMockChannelMaintenanceRepo()
+	maintenance := NewChannelMaintenanceService(maintenanceRepo, testutil.NewMockChannelRepository(), f.conversations, nil)
+	maintenance.now = func() time.Time { return f.now }
+	f.svc.SetChannelMaintenance(maintenance)
+
+	waiting := f.assign("conv-1", "agent-1", entity.ConversationStatusOpen)
+	f.presence.unavailable["agent-1"] = entity.PresenceStatusOffline
+
+	// Offline for 10 minutes, 8 of them during maintenance
+	maintenanceRepo.Create(ctx, &entity.ChannelMaintenanceWindow{
+		ID: "past", ChannelID: "channel-1", StartsAt: f.now.Add(-9 * time.Minute), EndsAt: f.now.Add(-time.Minute),
+	})
+	reassigned, err := f.svc.Run(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, reassigned, "the grace period does not run during maintenance")
+
+	// Conversations are not routed while the channel is under maintenance
+	maintenanceRepo.Delete(ctx, "past")
+	maintenanceRepo.Create(ctx, &entity.ChannelMaintenanceWindow{
+		ID: "active", ChannelID: "channel-1", StartsAt: f.now.Add(-time.Minute), EndsAt: f.now.Add(time.Hour),
+	})
+	maintenance.forget("channel-1")
+	reassigned, err = f.svc.Run(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, reassigned)
+	assert.Equal(t, "agent-1", *waiting.AssignedUserID)
+}
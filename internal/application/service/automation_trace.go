@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// AutomationTraceService stores and serves the decision snapshots taken by flows
+// and bots on each message, so automation builders can inspect the state of a
+// conversation at any point in time
+type AutomationTraceService struct {
+	repo             repository.AutomationTraceRepository
+	conversationRepo repository.ConversationRepository
+}
+
+// NewAutomationTraceService creates a new automation trace service
+func NewAutomationTraceService(repo repository.AutomationTraceRepository, conversationRepo repository.ConversationRepository) *AutomationTraceService {
+	return &AutomationTraceService{
+		repo:             repo,
+		conversationRepo: conversationRepo,
+	}
+}
+
+// Record stores a trace
+func (s *AutomationTraceService) Record(ctx context.Context, trace *entity.AutomationTrace) error {
+	if trace.ConversationID == "" {
+		return errors.New(errors.ErrCodeBadRequest, "trace has no conversation")
+	}
+	if trace.ID == "" {
+		trace.ID = uuid.New().String()
+	}
+	if trace.CreatedAt.IsZero() {
+		trace.CreatedAt = time.Now()
+	}
+	return s.repo.Create(ctx, trace)
+}
+
+// List returns the traces of a conversation, newest first
+func (s *AutomationTraceService) List(ctx context.Context, tenantID, conversationID string, filter *entity.AutomationTraceFilter, params *repository.ListParams) ([]*entity.AutomationTrace, int64, error) {
+	conversation, err := s.conversationRepo.FindByID(ctx, conversationID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if conversation.TenantID != tenantID {
+		return nil, 0, errors.NotFound("conversation")
+	}
+
+	return s.repo.FindByConversation(ctx, conversationID, filter, params)
+}
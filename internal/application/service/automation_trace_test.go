@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAutomationTraceRepository struct {
+	traces []*entity.AutomationTrace
+}
+
+func (m *mockAutomationTraceRepository) Create(ctx context.Context, trace *entity.AutomationTrace) error {
+	m.traces = append(m.traces, trace)
+	return nil
+}
+
+func (m *mockAutomationTraceRepository) FindByConversation(ctx context.Context, conversationID string, filter *entity.AutomationTraceFilter, params *repository.ListParams) ([]*entity.AutomationTrace, int64, error) {
+	var traces []*entity.AutomationTrace
+	for i := len(m.traces) - 1; i >= 0; i-- {
+		trace := m.traces[i]
+		if trace.ConversationID != conversationID {
+			continue
+		}
+		if filter != nil && filter.Before != nil && trace.CreatedAt.After(*filter.Before) {
+			continue
+		}
+		traces = append(traces, trace)
+	}
+	return traces, int64(len(traces)), nil
+}
+
+func TestAutomationTraceService_RecordAndList(t *testing.T) {
+	repo := &mockAutomationTraceRepository{}
+	conversationRepo := testutil.NewMockConversationRepository()
+	conversationRepo.Conversations["conv-1"] = &entity.Conversation{ID: "conv-1", TenantID: "tenant-1"}
+	svc := NewAutomationTraceService(repo, conversationRepo)
+	ctx := context.Background()
+
+	start := time.Now().Add(-time.Hour)
+	require.NoError(t, svc.Record(ctx, &entity.AutomationTrace{ConversationID: "conv-1", Source: entity.AutomationTraceSourceFlow, CreatedAt: start}))
+	require.NoError(t, svc.Record(ctx, &entity.AutomationTrace{ConversationID: "conv-1", Source: entity.AutomationTraceSourceAI}))
+	assert.NotEmpty(t, repo.traces[1].ID)
+	assert.False(t, repo.traces[1].CreatedAt.IsZero())
+
+	traces, total, err := svc.List(ctx, "tenant-1", "conv-1", nil, repository.NewListParams())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), total)
+	assert.Equal(t, entity.AutomationTraceSourceAI, traces[0].Source, "newest first")
+
+	before := start.Add(time.Minute)
+	traces, _, err = svc.List(ctx, "tenant-1", "conv-1", &entity.AutomationTraceFilter{Before: &before}, repository.NewListParams())
+	require.NoError(t, err)
+	require.Len(t, traces, 1)
+	assert.Equal(t, entity.AutomationTraceSourceFlow, traces[0].Source)
+
+	_, _, err = svc.List(ctx, "tenant-2", "conv-1", nil, repository.NewListParams())
+	assert.Equal(t, errors.ErrCodeNotFound, errors.GetAppError(err).Code)
+
+	assert.Error(t, svc.Record(ctx, &entity.AutomationTrace{}))
+}
@@ -20,6 +20,7 @@ type CreateBotInput struct {
 	SystemPrompt string
 	Temperature  float64
 	MaxTokens    int
+	External     *entity.ExternalBotConfig // Endpoint of the bot, required for external bots
 }
 
 // UpdateBotInput represents input for updating a bot
@@ -42,6 +43,9 @@ type BotServiceImpl struct {
 	aiFactory      *AIProviderFactory
 	flowEngine     *FlowEngineService
 	vreService     *VREService // VRE for visual responses
+	usage          UsageRecorder
+	externalBots   *ExternalBotService
+	maintenance    *ChannelMaintenanceService
 }
 
 // NewBotService creates a new bot service
@@ -68,6 +72,28 @@ func (s *BotServiceImpl) SetVREService(vreService *VREService) {
 	s.vreService = vreService
 }
 
+// SetUsageRecorder meters every completion against the tenant's AI request quota
+func (s *BotServiceImpl) SetUsageRecorder(usage UsageRecorder) {
+	s.usage = usage
+}
+
+// SetExternalBotService lets bots of type external be tested against their endpoint
+func (s *BotServiceImpl) SetExternalBotService(externalBots *ExternalBotService) {
+	s.externalBots = externalBots
+}
+
+// SetChannelMaintenance makes bots stand down on channels under maintenance
+func (s *BotServiceImpl) SetChannelMaintenance(maintenance *ChannelMaintenanceService) {
+	s.maintenance = maintenance
+}
+
+// recordAIRequest counts a completion made for a bot
+func (s *BotServiceImpl) recordAIRequest(bot *entity.Bot) {
+	if s.usage != nil {
+		s.usage.RecordUsage(bot.TenantID, entity.QuotaMetricAIRequests, "", 1)
+	}
+}
+
 // Create creates a new bot
 func (s *BotServiceImpl) Create(ctx context.Context, input *CreateBotInput) (*entity.Bot, error) {
 	bot := entity.NewBot(input.TenantID, input.Name, input.Type, input.Provider, input.Model)
@@ -83,6 +109,12 @@ func (s *BotServiceImpl) Create(ctx context.Context, input *CreateBotInput) (*en
 	if input.MaxTokens > 0 {
 		bot.Config.MaxTokens = input.MaxTokens
 	}
+	if bot.IsExternal() {
+		if err := ValidateExternalBotConfig(ctx, input.External); err != nil {
+			return nil, err
+		}
+		bot.Config.External = input.External
+	}
 
 	if err := s.botRepo.Create(ctx, bot); err != nil {
 		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to create bot")
@@ -212,6 +244,11 @@ func (s *BotServiceImpl) ShouldBotHandle(ctx context.Context, conversation *enti
 		return false, nil
 	}
 
+	// Check if an agent took over from the bot
+	if conversation.IsBotPaused() {
+		return false, nil
+	}
+
 	// Check working hours if configured
 	if bot.Config.WorkingHours != nil && bot.Config.WorkingHours.Enabled {
 		if !s.isWithinWorkingHours(bot.Config.WorkingHours) {
@@ -219,6 +256,11 @@ func (s *BotServiceImpl) ShouldBotHandle(ctx context.Context, conversation *enti
 		}
 	}
 
+	// A channel under maintenance is out of hours; its notice answers the contact
+	if s.maintenance != nil && s.maintenance.ActiveWindow(ctx, conversation.ChannelID) != nil {
+		return false, nil
+	}
+
 	return true, nil
 }
 
@@ -248,11 +290,13 @@ func (s *BotServiceImpl) ProcessMessage(ctx context.Context, message *entity.Mes
 
 // processFlowMessage processes a message through an active flow
 func (s *BotServiceImpl) processFlowMessage(ctx context.Context, message *entity.Message, conversation *entity.Conversation, bot *entity.Bot, convContext *entity.ConversationContext) (*BotResponse, error) {
+	flowID := s.flowEngine.GetActiveFlowID(convContext)
 	result, err := s.flowEngine.ContinueFlow(ctx, conversation.TenantID, message.Content, convContext)
 	if err != nil {
 		// Flow error, fall back to AI
 		return s.processAIMessage(ctx, message, conversation, bot, convContext)
 	}
+	s.flowEngine.RecordTrace(ctx, message, conversation, flowID, result, convContext)
 
 	// Update context
 	if err := s.contextRepo.Update(ctx, convContext); err != nil {
@@ -304,6 +348,7 @@ func (s *BotServiceImpl) startFlow(ctx context.Context, flow *entity.Flow, messa
 		// Flow start error, fall back to AI
 		return s.processAIMessage(ctx, message, conversation, bot, convContext)
 	}
+	s.flowEngine.RecordTrace(ctx, message, conversation, flow.ID, result, convContext)
 
 	// Update context with flow state
 	if err := s.contextRepo.Update(ctx, convContext); err != nil {
@@ -329,6 +374,7 @@ func (s *BotServiceImpl) processAIMessage(ctx context.Context, message *entity.M
 	if err != nil {
 		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get AI provider")
 	}
+	ctx = WithAIUsage(ctx, bot.TenantID, bot.ID, entity.AIFeatureResponse)
 
 	// Build messages for completion
 	messages, err := s.contextService.BuildMessagesForAI(
@@ -364,6 +410,7 @@ func (s *BotServiceImpl) processAIMessage(ctx context.Context, message *entity.M
 			EscalateReason: "AI generation failed: " + err.Error(),
 		}, nil
 	}
+	s.recordAIRequest(bot)
 
 	latencyMs := time.Since(startTime).Milliseconds()
 
@@ -535,6 +582,11 @@ func (s *BotServiceImpl) UpdateConfig(ctx context.Context, botID string, config
 	if err != nil {
 		return err
 	}
+	if bot.IsExternal() {
+		if err := ValidateExternalBotConfig(ctx, config.External); err != nil {
+			return err
+		}
+	}
 
 	bot.Config = config
 	bot.UpdatedAt = time.Now()
@@ -547,12 +599,16 @@ func (s *BotServiceImpl) TestBot(ctx context.Context, botID, message string) (*B
 	if err != nil {
 		return nil, err
 	}
+	if bot.IsExternal() {
+		return s.testExternalBot(ctx, bot, message)
+	}
 
 	// Get AI provider
 	provider, err := s.aiFactory.Get(bot.Provider)
 	if err != nil {
 		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get AI provider")
 	}
+	ctx = WithAIUsage(ctx, bot.TenantID, bot.ID, entity.AIFeatureResponse)
 
 	// Build simple message array
 	messages := []Message{
@@ -571,6 +627,7 @@ func (s *BotServiceImpl) TestBot(ctx context.Context, botID, message string) (*B
 	if err != nil {
 		return nil, errors.Wrap(err, errors.ErrCodeInternal, "AI generation failed")
 	}
+	s.recordAIRequest(bot)
 
 	latencyMs := time.Since(startTime).Milliseconds()
 
@@ -582,6 +639,34 @@ func (s *BotServiceImpl) TestBot(ctx context.Context, botID, message string) (*B
 	}, nil
 }
 
+// testExternalBot sends a test message to the endpoint of an external bot, in a
+// conversation of its own
+func (s *BotServiceImpl) testExternalBot(ctx context.Context, bot *entity.Bot, message string) (*BotResponse, error) {
+	if s.externalBots == nil {
+		return nil, errors.New(errors.ErrCodeInternal, "external bots are not enabled")
+	}
+
+	startTime := time.Now()
+	reply, err := s.externalBots.Reply(ctx, bot, &ExternalBotRequest{
+		TenantID:       bot.TenantID,
+		BotID:          bot.ID,
+		ConversationID: "test-" + bot.ID,
+		Text:           message,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "external bot failed")
+	}
+
+	return &BotResponse{
+		Content:        reply.Text(),
+		Confidence:     1,
+		QuickReplies:   reply.QuickReplies,
+		ShouldEscalate: reply.Escalate,
+		EscalateReason: reply.Reason,
+		LatencyMs:      time.Since(startTime).Milliseconds(),
+	}, nil
+}
+
 // Helper methods
 
 func (s *BotServiceImpl) isWithinWorkingHours(wh *entity.WorkingHours) bool {
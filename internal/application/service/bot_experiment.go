@@ -0,0 +1,343 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// BotVersionInput represents input for saving a bot version. The version snapshots
+// the bot's current model and configuration with the given changes applied.
+type BotVersionInput struct {
+	Description  string            `json:"description,omitempty"`
+	Model        string            `json:"model,omitempty"`
+	SystemPrompt *string           `json:"system_prompt,omitempty"`
+	Config       *entity.BotConfig `json:"config,omitempty"` // Replaces the whole configuration
+}
+
+// BotExperimentInput represents input for starting a bot experiment
+type BotExperimentInput struct {
+	Name     string                        `json:"name" binding:"required"`
+	Variants []entity.BotExperimentVariant `json:"variants" binding:"required"`
+}
+
+// BotExperimentService versions the prompt and configuration of bots and runs
+// experiments splitting conversations between versions
+type BotExperimentService struct {
+	repo    repository.BotExperimentRepository
+	botRepo repository.BotRepository
+	now     func() time.Time
+}
+
+// NewBotExperimentService creates a new bot experiment service
+func NewBotExperimentService(repo repository.BotExperimentRepository, botRepo repository.BotRepository) *BotExperimentService {
+	return &BotExperimentService{
+		repo:    repo,
+		botRepo: botRepo,
+		now:     time.Now,
+	}
+}
+
+// ListVersions returns the versions of a bot, newest first
+func (s *BotExperimentService) ListVersions(ctx context.Context, tenantID, botID string) ([]*entity.BotVersion, error) {
+	if _, err := s.getBot(ctx, tenantID, botID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListVersions(ctx, botID)
+}
+
+// GetVersion returns a version of a bot
+func (s *BotExperimentService) GetVersion(ctx context.Context, tenantID, botID, versionID string) (*entity.BotVersion, error) {
+	version, err := s.repo.FindVersionByID(ctx, versionID)
+	if err != nil {
+		return nil, err
+	}
+	if version.TenantID != tenantID || version.BotID != botID {
+		return nil, errors.NotFound("bot version")
+	}
+	return version, nil
+}
+
+// CreateVersion saves a version of a bot
+func (s *BotExperimentService) CreateVersion(ctx context.Context, tenantID, botID, userID string, input *BotVersionInput) (*entity.BotVersion, error) {
+	bot, err := s.getBot(ctx, tenantID, botID)
+	if err != nil {
+		return nil, err
+	}
+
+	version := &entity.BotVersion{
+		ID:          uuid.New().String(),
+		TenantID:    tenantID,
+		BotID:       botID,
+		Description: strings.TrimSpace(input.Description),
+		Provider:    bot.Provider,
+		Model:       bot.Model,
+		Config:      bot.Config,
+		CreatedBy:   userID,
+		CreatedAt:   s.now(),
+	}
+	if input.Model != "" {
+		version.Model = input.Model
+	}
+	if input.Config != nil {
+		version.Config = *input.Config
+	}
+	if input.SystemPrompt != nil {
+		version.Config.SystemPrompt = *input.SystemPrompt
+	}
+	if strings.TrimSpace(version.Config.SystemPrompt) == "" {
+		return nil, errors.Validation("system_prompt is required")
+	}
+
+	if err := s.repo.CreateVersion(ctx, version); err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+// RestoreVersion makes a version the bot's current model and configuration
+func (s *BotExperimentService) RestoreVersion(ctx context.Context, tenantID, botID, versionID string) (*entity.Bot, error) {
+	bot, err := s.getBot(ctx, tenantID, botID)
+	if err != nil {
+		return nil, err
+	}
+	version, err := s.GetVersion(ctx, tenantID, botID, versionID)
+	if err != nil {
+		return nil, err
+	}
+
+	restored := version.Apply(bot)
+	restored.UpdatedAt = s.now()
+	if err := s.botRepo.Update(ctx, restored); err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+// ListExperiments returns the experiments of a bot, newest first
+func (s *BotExperimentService) ListExperiments(ctx context.Context, tenantID, botID string) ([]*entity.BotExperiment, error) {
+	if _, err := s.getBot(ctx, tenantID, botID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListExperiments(ctx, botID)
+}
+
+// GetExperiment returns an experiment of a bot
+func (s *BotExperimentService) GetExperiment(ctx context.Context, tenantID, botID, experimentID string) (*entity.BotExperiment, error) {
+	experiment, err := s.repo.FindExperimentByID(ctx, experimentID)
+	if err != nil {
+		return nil, err
+	}
+	if experiment.TenantID != tenantID || experiment.BotID != botID {
+		return nil, errors.NotFound("bot experiment")
+	}
+	return experiment, nil
+}
+
+// StartExperiment starts splitting the conversations of a bot between versions
+func (s *BotExperimentService) StartExperiment(ctx context.Context, tenantID, botID, userID string, input *BotExperimentInput) (*entity.BotExperiment, error) {
+	if _, err := s.getBot(ctx, tenantID, botID); err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, errors.Validation("name is required")
+	}
+	if len(input.Variants) < 2 {
+		return nil, errors.Validation("an experiment needs at least two variants")
+	}
+	seen := make(map[string]bool, len(input.Variants))
+	variants := make([]entity.BotExperimentVariant, 0, len(input.Variants))
+	for _, variant := range input.Variants {
+		if seen[variant.VersionID] {
+			return nil, errors.Validation("each variant must use a different version")
+		}
+		seen[variant.VersionID] = true
+		if variant.Weight < 0 || variant.Weight > 100 {
+			return nil, errors.Validation("variant weights must be between 1 and 100")
+		}
+		if variant.Weight == 0 {
+			variant.Weight = 1
+		}
+		if _, err := s.GetVersion(ctx, tenantID, botID, variant.VersionID); err != nil {
+			if appErr := errors.GetAppError(err); appErr != nil && appErr.Code == errors.ErrCodeNotFound {
+				return nil, errors.Validation("version " + variant.VersionID + " is not a version of the bot")
+			}
+			return nil, err
+		}
+		variants = append(variants, variant)
+	}
+
+	if _, err := s.repo.FindRunningExperiment(ctx, botID); err == nil {
+		return nil, errors.Conflict("the bot already runs an experiment; stop it first")
+	} else if appErr := errors.GetAppError(err); appErr == nil || appErr.Code != errors.ErrCodeNotFound {
+		return nil, err
+	}
+
+	now := s.now()
+	experiment := &entity.BotExperiment{
+		ID:        uuid.New().String(),
+		TenantID:  tenantID,
+		BotID:     botID,
+		Name:      name,
+		Status:    entity.BotExperimentStatusRunning,
+		Variants:  variants,
+		CreatedBy: userID,
+		StartedAt: now,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.repo.CreateExperiment(ctx, experiment); err != nil {
+		return nil, err
+	}
+	return experiment, nil
+}
+
+// StopExperiment stops assigning conversations. Outcomes of the conversations already
+// assigned keep being recorded.
+func (s *BotExperimentService) StopExperiment(ctx context.Context, tenantID, botID, experimentID string) (*entity.BotExperiment, error) {
+	experiment, err := s.GetExperiment(ctx, tenantID, botID, experimentID)
+	if err != nil {
+		return nil, err
+	}
+	if !experiment.IsRunning() {
+		return nil, errors.Validation("experiment is not running")
+	}
+
+	now := s.now()
+	experiment.Status = entity.BotExperimentStatusStopped
+	experiment.EndedAt = &now
+	experiment.UpdatedAt = now
+	if err := s.repo.UpdateExperiment(ctx, experiment); err != nil {
+		return nil, err
+	}
+	return experiment, nil
+}
+
+// Report compares the escalation rate, resolution rate and CSAT of the versions of an experiment
+func (s *BotExperimentService) Report(ctx context.Context, tenantID, botID, experimentID string) (*entity.BotExperimentReport, error) {
+	experiment, err := s.GetExperiment(ctx, tenantID, botID, experimentID)
+	if err != nil {
+		return nil, err
+	}
+	outcomes, err := s.repo.VariantOutcomes(ctx, experimentID)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[string]*entity.BotExperimentVariantReport, len(outcomes))
+	for _, outcome := range outcomes {
+		byVersion[outcome.VersionID] = outcome
+	}
+
+	report := &entity.BotExperimentReport{Experiment: experiment}
+	for _, variant := range experiment.Variants {
+		variantReport, ok := byVersion[variant.VersionID]
+		if !ok {
+			variantReport = &entity.BotExperimentVariantReport{VersionID: variant.VersionID}
+		}
+		variantReport.Weight = variant.Weight
+		if version, err := s.repo.FindVersionByID(ctx, variant.VersionID); err == nil {
+			variantReport.Version = version.Version
+			variantReport.Description = version.Description
+		}
+		if variantReport.Conversations > 0 {
+			conversations := float64(variantReport.Conversations)
+			variantReport.EscalationRate = float64(variantReport.Escalations) / conversations
+			variantReport.ResolutionRate = float64(variantReport.Resolutions) / conversations
+		}
+		report.Variants = append(report.Variants, variantReport)
+	}
+	return report, nil
+}
+
+// Assign returns the bot to answer a conversation with: the version the conversation
+// is assigned to when the bot runs an experiment, the bot itself otherwise. Failures
+// are logged and fall back to the bot.
+func (s *BotExperimentService) Assign(ctx context.Context, bot *entity.Bot, conversationID string) *entity.Bot {
+	experiment, err := s.repo.FindRunningExperiment(ctx, bot.ID)
+	if err != nil {
+		if appErr := errors.GetAppError(err); appErr == nil || appErr.Code != errors.ErrCodeNotFound {
+			s.logFailure("Failed to find bot experiment", bot.ID, conversationID, err)
+		}
+		return bot
+	}
+
+	assignment, err := s.repo.FindAssignment(ctx, experiment.ID, conversationID)
+	if err != nil {
+		variant := experiment.PickVariant(conversationID)
+		if variant == nil {
+			return bot
+		}
+		assignment, err = s.repo.CreateAssignment(ctx, &entity.BotExperimentAssignment{
+			ExperimentID:   experiment.ID,
+			TenantID:       experiment.TenantID,
+			ConversationID: conversationID,
+			VersionID:      variant.VersionID,
+			AssignedAt:     s.now(),
+		})
+		if err != nil {
+			s.logFailure("Failed to assign conversation to bot experiment", bot.ID, conversationID, err)
+			return bot
+		}
+	}
+
+	version, err := s.repo.FindVersionByID(ctx, assignment.VersionID)
+	if err != nil {
+		s.logFailure("Failed to load bot version", bot.ID, conversationID, err)
+		return bot
+	}
+	return version.Apply(bot)
+}
+
+// RecordEscalation counts an escalation of a conversation against the version it was
+// assigned to
+func (s *BotExperimentService) RecordEscalation(ctx context.Context, conversationID string) {
+	if err := s.repo.MarkEscalated(ctx, conversationID); err != nil {
+		s.logFailure("Failed to record bot experiment escalation", "", conversationID, err)
+	}
+}
+
+// RecordResolution counts the resolution of a conversation against the version it was
+// assigned to
+func (s *BotExperimentService) RecordResolution(ctx context.Context, conversationID string) {
+	if err := s.repo.MarkResolved(ctx, conversationID); err != nil {
+		s.logFailure("Failed to record bot experiment resolution", "", conversationID, err)
+	}
+}
+
+// RecordCSAT records the satisfaction score, 1 to 5, a customer gave a conversation.
+// It counts in disposition analytics and in the bot experiment the conversation takes
+// part in, if any.
+func (s *BotExperimentService) RecordCSAT(ctx context.Context, tenantID, conversationID string, score int) error {
+	if score < 1 || score > 5 {
+		return errors.Validation("score must be between 1 and 5")
+	}
+	return s.repo.SetCSAT(ctx, tenantID, conversationID, score)
+}
+
+func (s *BotExperimentService) getBot(ctx context.Context, tenantID, botID string) (*entity.Bot, error) {
+	bot, err := s.botRepo.FindByID(ctx, botID)
+	if err != nil {
+		return nil, err
+	}
+	if bot.TenantID != tenantID {
+		return nil, errors.NotFound("bot")
+	}
+	return bot, nil
+}
+
+func (s *BotExperimentService) logFailure(message, botID, conversationID string, err error) {
+	logger.Warn(message,
+		zap.String("bot_id", botID),
+		zap.String("conversation_id", conversationID),
+		zap.Error(err),
+	)
+}
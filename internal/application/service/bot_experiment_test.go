@@ -0,0 +1,333 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBotExperimentRepository struct {
+	mu          sync.Mutex
+	versions    map[string]*entity.BotVersion
+	experiments map[string]*entity.BotExperiment
+	assignments map[string]*entity.BotExperimentAssignment // experimentID:conversationID
+}
+
+func newFakeBotExperimentRepository() *fakeBotExperimentRepository {
+	return &fakeBotExperimentRepository{
+		versions:    make(map[string]*entity.BotVersion),
+		experiments: make(map[string]*entity.BotExperiment),
+		assignments: make(map[string]*entity.BotExperimentAssignment),
+	}
+}
+
+func (r *fakeBotExperimentRepository) CreateVersion(_ context.Context, version *entity.BotVersion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	version.Version = 1
+	for _, other := range r.versions {
+		if other.BotID == version.BotID && other.Version >= version.Version {
+			version.Version = other.Version + 1
+		}
+	}
+	r.versions[version.ID] = version
+	return nil
+}
+
+func (r *fakeBotExperimentRepository) FindVersionByID(_ context.Context, id string) (*entity.BotVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	version, ok := r.versions[id]
+	if !ok {
+		return nil, errors.NotFound("bot version")
+	}
+	return version, nil
+}
+
+func (r *fakeBotExperimentRepository) ListVersions(_ context.Context, botID string) ([]*entity.BotVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var versions []*entity.BotVersion
+	for _, version := range r.versions {
+		if version.BotID == botID {
+			versions = append(versions, version)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version > versions[j].Version })
+	return versions, nil
+}
+
+func (r *fakeBotExperimentRepository) CreateExperiment(_ context.Context, experiment *entity.BotExperiment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.experiments[experiment.ID] = experiment
+	return nil
+}
+
+func (r *fakeBotExperimentRepository) UpdateExperiment(_ context.Context, experiment *entity.BotExperiment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.experiments[experiment.ID] = experiment
+	return nil
+}
+
+func (r *fakeBotExperimentRepository) FindExperimentByID(_ context.Context, id string) (*entity.BotExperiment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	experiment, ok := r.experiments[id]
+	if !ok {
+		return nil, errors.NotFound("bot experiment")
+	}
+	return experiment, nil
+}
+
+func (r *fakeBotExperimentRepository) FindRunningExperiment(_ context.Context, botID string) (*entity.BotExperiment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, experiment := range r.experiments {
+		if experiment.BotID == botID && experiment.IsRunning() {
+			return experiment, nil
+		}
+	}
+	return nil, errors.NotFound("bot experiment")
+}
+
+func (r *fakeBotExperimentRepository) ListExperiments(_ context.Context, botID string) ([]*entity.BotExperiment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var experiments []*entity.BotExperiment
+	for _, experiment := range r.experiments {
+		if experiment.BotID == botID {
+			experiments = append(experiments, experiment)
+		}
+	}
+	return experiments, nil
+}
+
+func (r *fakeBotExperimentRepository) FindAssignment(_ context.Context, experimentID, conversationID string) (*entity.BotExperimentAssignment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	assignment, ok := r.assignments[experimentID+":"+conversationID]
+	if !ok {
+		return nil, errors.NotFound("bot experiment assignment")
+	}
+	return assignment, nil
+}
+
+func (r *fakeBotExperimentRepository) CreateAssignment(_ context.Context, assignment *entity.BotExperimentAssignment) (*entity.BotExperimentAssignment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := assignment.ExperimentID + ":" + assignment.ConversationID
+	if existing, ok := r.assignments[key]; ok {
+		return existing, nil
+	}
+	r.assignments[key] = assignment
+	return assignment, nil
+}
+
+func (r *fakeBotExperimentRepository) update(conversationID string, apply func(*entity.BotExperimentAssignment)) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	updated := 0
+	for _, assignment := range r.assignments {
+		if assignment.ConversationID == conversationID {
+			apply(assignment)
+			updated++
+		}
+	}
+	return updated
+}
+
+func (r *fakeBotExperimentRepository) MarkEscalated(_ context.Context, conversationID string) error {
+	r.update(conversationID, func(a *entity.BotExperimentAssignment) { a.Escalated = true })
+	return nil
+}
+
+func (r *fakeBotExperimentRepository) MarkResolved(_ context.Context, conversationID string) error {
+	r.update(conversationID, func(a *entity.BotExperimentAssignment) { a.Resolved = true })
+	return nil
+}
+
+func (r *fakeBotExperimentRepository) SetCSAT(_ context.Context, _, conversationID string, score int) error {
+	if r.update(conversationID, func(a *entity.BotExperimentAssignment) { a.CSAT = &score }) == 0 {
+		return errors.NotFound("bot experiment assignment")
+	}
+	return nil
+}
+
+func (r *fakeBotExperimentRepository) VariantOutcomes(_ context.Context, experimentID string) ([]*entity.BotExperimentVariantReport, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	byVersion := map[string]*entity.BotExperimentVariantReport{}
+	csatTotals := map[string]int{}
+	for _, assignment := range r.assignments {
+		if assignment.ExperimentID != experimentID {
+			continue
+		}
+		outcome, ok := byVersion[assignment.VersionID]
+		if !ok {
+			outcome = &entity.BotExperimentVariantReport{VersionID: assignment.VersionID}
+			byVersion[assignment.VersionID] = outcome
+		}
+		outcome.Conversations++
+		if assignment.Escalated {
+			outcome.Escalations++
+		}
+		if assignment.Resolved {
+			outcome.Resolutions++
+		}
+		if assignment.CSAT != nil {
+			outcome.CSATResponses++
+			csatTotals[assignment.VersionID] += *assignment.CSAT
+		}
+	}
+	var outcomes []*entity.BotExperimentVariantReport
+	for versionID, outcome := range byVersion {
+		if outcome.CSATResponses > 0 {
+			outcome.AverageCSAT = float64(csatTotals[versionID]) / float64(outcome.CSATResponses)
+		}
+		outcomes = append(outcomes, outcome)
+	}
+	return outcomes, nil
+}
+
+func newTestBotExperimentService() (*BotExperimentService, *fakeBotExperimentRepository, *MockBotRepository) {
+	repo := newFakeBotExperimentRepository()
+	botRepo := NewMockBotRepository()
+	botRepo.Bots["bot-1"] = &entity.Bot{
+		ID:       "bot-1",
+		TenantID: "tenant-1",
+		Provider: entity.AIProviderOpenAI,
+		Model:    "gpt-4",
+		Config:   entity.BotConfig{SystemPrompt: "You are helpful.", Temperature: 0.7},
+	}
+	return NewBotExperimentService(repo, botRepo), repo, botRepo
+}
+
+func TestBotExperimentService_Versions(t *testing.T) {
+	ctx := context.Background()
+	svc, _, botRepo := newTestBotExperimentService()
+
+	first, err := svc.CreateVersion(ctx, "tenant-1", "bot-1", "user-1", &BotVersionInput{Description: "Baseline"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, first.Version)
+	assert.Equal(t, "You are helpful.", first.Config.SystemPrompt)
+
+	prompt := "You are concise."
+	second, err := svc.CreateVersion(ctx, "tenant-1", "bot-1", "user-1", &BotVersionInput{SystemPrompt: &prompt, Model: "gpt-4o"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, second.Version)
+	assert.Equal(t, "You are concise.", second.Config.SystemPrompt)
+	assert.Equal(t, 0.7, second.Config.Temperature)
+	assert.Equal(t, "You are helpful.", botRepo.Bots["bot-1"].Config.SystemPrompt, "saving a version leaves the bot alone")
+
+	bot, err := svc.RestoreVersion(ctx, "tenant-1", "bot-1", second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "You are concise.", bot.Config.SystemPrompt)
+	assert.Equal(t, "gpt-4o", botRepo.Bots["bot-1"].Model)
+
+	_, err = svc.CreateVersion(ctx, "tenant-2", "bot-1", "user-1", &BotVersionInput{})
+	assert.Error(t, err)
+}
+
+func TestBotExperimentService_StartExperiment(t *testing.T) {
+	ctx := context.Background()
+	svc, _, _ := newTestBotExperimentService()
+	a, err := svc.CreateVersion(ctx, "tenant-1", "bot-1", "", &BotVersionInput{})
+	require.NoError(t, err)
+	b, err := svc.CreateVersion(ctx, "tenant-1", "bot-1", "", &BotVersionInput{})
+	require.NoError(t, err)
+
+	t.Run("rejects invalid variants", func(t *testing.T) {
+		inputs := []*BotExperimentInput{
+			{Name: "one variant", Variants: []entity.BotExperimentVariant{{VersionID: a.ID}}},
+			{Name: "same version", Variants: []entity.BotExperimentVariant{{VersionID: a.ID}, {VersionID: a.ID}}},
+			{Name: "unknown version", Variants: []entity.BotExperimentVariant{{VersionID: a.ID}, {VersionID: "missing"}}},
+			{Name: "bad weight", Variants: []entity.BotExperimentVariant{{VersionID: a.ID, Weight: -1}, {VersionID: b.ID}}},
+		}
+		for _, input := range inputs {
+			_, err := svc.StartExperiment(ctx, "tenant-1", "bot-1", "", input)
+			assert.Equal(t, errors.ErrCodeValidation, errors.GetAppError(err).Code, input.Name)
+		}
+	})
+
+	t.Run("runs one experiment at a time", func(t *testing.T) {
+		input := &BotExperimentInput{Name: "Concise", Variants: []entity.BotExperimentVariant{{VersionID: a.ID}, {VersionID: b.ID}}}
+		experiment, err := svc.StartExperiment(ctx, "tenant-1", "bot-1", "", input)
+		require.NoError(t, err)
+		assert.Equal(t, 1, experiment.Variants[0].Weight)
+
+		_, err = svc.StartExperiment(ctx, "tenant-1", "bot-1", "", input)
+		assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code)
+
+		_, err = svc.StopExperiment(ctx, "tenant-1", "bot-1", experiment.ID)
+		require.NoError(t, err)
+		_, err = svc.StartExperiment(ctx, "tenant-1", "bot-1", "", input)
+		assert.NoError(t, err)
+	})
+}
+
+func TestBotExperimentService_AssignAndReport(t *testing.T) {
+	ctx := context.Background()
+	svc, _, botRepo := newTestBotExperimentService()
+	bot := botRepo.Bots["bot-1"]
+
+	assert.Same(t, bot, svc.Assign(ctx, bot, "conv-0"), "without an experiment the bot answers as is")
+
+	promptA, promptB := "Prompt A", "Prompt B"
+	a, err := svc.CreateVersion(ctx, "tenant-1", "bot-1", "", &BotVersionInput{SystemPrompt: &promptA, Description: "A"})
+	require.NoError(t, err)
+	b, err := svc.CreateVersion(ctx, "tenant-1", "bot-1", "", &BotVersionInput{SystemPrompt: &promptB, Description: "B"})
+	require.NoError(t, err)
+	experiment, err := svc.StartExperiment(ctx, "tenant-1", "bot-1", "", &BotExperimentInput{
+		Name:     "Prompt test",
+		Variants: []entity.BotExperimentVariant{{VersionID: a.ID, Weight: 1}, {VersionID: b.ID, Weight: 1}},
+	})
+	require.NoError(t, err)
+
+	prompts := map[string]string{}
+	for i := 0; i < 20; i++ {
+		conversationID := fmt.Sprintf("conv-%d", i)
+		answered := svc.Assign(ctx, bot, conversationID)
+		prompts[conversationID] = answered.Config.SystemPrompt
+		assert.Equal(t, answered.Config.SystemPrompt, svc.Assign(ctx, bot, conversationID).Config.SystemPrompt, "assignments are sticky")
+	}
+	assert.Equal(t, "You are helpful.", bot.Config.SystemPrompt)
+
+	var conversationA, conversationB string
+	for conversationID, prompt := range prompts {
+		if prompt == promptA && conversationA == "" {
+			conversationA = conversationID
+		}
+		if prompt == promptB && conversationB == "" {
+			conversationB = conversationID
+		}
+	}
+	require.NotEmpty(t, conversationA)
+	require.NotEmpty(t, conversationB)
+
+	svc.RecordEscalation(ctx, conversationA)
+	svc.RecordResolution(ctx, conversationB)
+	require.NoError(t, svc.RecordCSAT(ctx, "tenant-1", conversationB, 5))
+	assert.Error(t, svc.RecordCSAT(ctx, "tenant-1", conversationB, 6))
+	assert.Error(t, svc.RecordCSAT(ctx, "tenant-1", "conv-unknown", 4))
+
+	report, err := svc.Report(ctx, "tenant-1", "bot-1", experiment.ID)
+	require.NoError(t, err)
+	require.Len(t, report.Variants, 2)
+	reportA, reportB := report.Variants[0], report.Variants[1]
+	assert.Equal(t, "A", reportA.Description)
+	assert.Equal(t, int64(20), reportA.Conversations+reportB.Conversations)
+	assert.Equal(t, int64(1), reportA.Escalations)
+	assert.InDelta(t, 1/float64(reportA.Conversations), reportA.EscalationRate, 0.0001)
+	assert.Equal(t, int64(1), reportB.Resolutions)
+	assert.Equal(t, int64(1), reportB.CSATResponses)
+	assert.Equal(t, 5.0, reportB.AverageCSAT)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/msgfy/linktor/internal/domain/entity"
 	"github.com/msgfy/linktor/internal/domain/repository"
@@ -796,3 +797,28 @@ func TestBotShouldBotHandle_ConversationAssignedToHuman(t *testing.T) {
 		t.Error("expected false, conversation is assigned to a human")
 	}
 }
+
+func TestBotShouldBotHandle_BotPaused(t *testing.T) {
+	botRepo := NewMockBotRepository()
+	svc := newTestBotService(botRepo, nil, nil)
+
+	bot := entity.NewBot("tenant-1", "Bot", entity.BotTypeAI, entity.AIProviderOpenAI, "gpt-4")
+	bot.ID = "bot-1"
+	bot.Status = entity.BotStatusActive
+	bot.Channels = []string{"ch-1"}
+
+	pausedAt := time.Now()
+	conv := &entity.Conversation{
+		ID:          "conv-1",
+		ChannelID:   "ch-1",
+		BotPausedAt: &pausedAt,
+	}
+
+	should, err := svc.ShouldBotHandle(context.Background(), conv, bot)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if should {
+		t.Error("expected false, an agent took over from the bot")
+	}
+}
@@ -0,0 +1,371 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/egress"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Timeouts of tool calls, in seconds
+const (
+	DefaultBotToolTimeout = 10
+	MaxBotToolTimeout     = 30
+)
+
+// maxBotToolResult caps the response body of a tool given back to the AI
+const maxBotToolResult = 16 * 1024
+
+// botToolNamePattern is the tool name format accepted by every AI provider
+var botToolNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// BotToolInput represents input for registering or updating a bot tool
+type BotToolInput struct {
+	Name           string                 `json:"name" binding:"required"`
+	Description    string                 `json:"description" binding:"required"`
+	Parameters     map[string]interface{} `json:"parameters,omitempty"` // JSON Schema of the arguments; no arguments when empty
+	Method         string                 `json:"method,omitempty"`     // POST when empty
+	URL            string                 `json:"url" binding:"required"`
+	Headers        map[string]string      `json:"headers,omitempty"`
+	TimeoutSeconds int                    `json:"timeout_seconds,omitempty"`
+	Enabled        *bool                  `json:"enabled,omitempty"`
+}
+
+// ToolCallContext identifies the message a tool is called to answer, for the audit log
+type ToolCallContext struct {
+	ConversationID string
+	MessageID      string
+}
+
+// BotToolService manages the HTTP tools tenants register for their bots and calls
+// them on behalf of the AI, recording every call
+type BotToolService struct {
+	repo       repository.BotToolRepository
+	botRepo    repository.BotRepository
+	httpClient *http.Client
+	checkURL   func(ctx context.Context, endpoint string) error
+	now        func() time.Time
+}
+
+// NewBotToolService creates a new bot tool service
+func NewBotToolService(repo repository.BotToolRepository, botRepo repository.BotRepository) *BotToolService {
+	return &BotToolService{
+		repo:       repo,
+		botRepo:    botRepo,
+		httpClient: egress.NewClient(),
+		checkURL:   egress.CheckURL,
+		now:        time.Now,
+	}
+}
+
+// SetHTTPClient replaces the client tools are called with, which refuses internal
+// addresses, e.g. to reach local test servers
+func (s *BotToolService) SetHTTPClient(client *http.Client) {
+	s.httpClient = client
+}
+
+// List returns the tools of a bot
+func (s *BotToolService) List(ctx context.Context, tenantID, botID string) ([]*entity.BotTool, error) {
+	if _, err := s.getBot(ctx, tenantID, botID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByBot(ctx, botID)
+}
+
+// Create registers a tool for a bot
+func (s *BotToolService) Create(ctx context.Context, tenantID, botID string, input *BotToolInput) (*entity.BotTool, error) {
+	if _, err := s.getBot(ctx, tenantID, botID); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	tool := &entity.BotTool{
+		ID:        uuid.New().String(),
+		TenantID:  tenantID,
+		BotID:     botID,
+		Enabled:   true,
+		CreatedAt: now,
+	}
+	if err := s.apply(ctx, tool, input); err != nil {
+		return nil, err
+	}
+	tool.UpdatedAt = now
+
+	if err := s.repo.Create(ctx, tool); err != nil {
+		return nil, err
+	}
+	return tool, nil
+}
+
+// Update updates a tool of a bot
+func (s *BotToolService) Update(ctx context.Context, tenantID, botID, id string, input *BotToolInput) (*entity.BotTool, error) {
+	tool, err := s.get(ctx, tenantID, botID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, tool, input); err != nil {
+		return nil, err
+	}
+	tool.UpdatedAt = s.now()
+
+	if err := s.repo.Update(ctx, tool); err != nil {
+		return nil, err
+	}
+	return tool, nil
+}
+
+// Delete deletes a tool of a bot
+func (s *BotToolService) Delete(ctx context.Context, tenantID, botID, id string) error {
+	if _, err := s.get(ctx, tenantID, botID, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// ListExecutions returns the audit log of the tool calls of a bot, newest first
+func (s *BotToolService) ListExecutions(ctx context.Context, tenantID, botID string, params *repository.ListParams) ([]*entity.ToolExecution, int64, error) {
+	if _, err := s.getBot(ctx, tenantID, botID); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.ListExecutions(ctx, botID, params)
+}
+
+// EnabledTools returns the tools the AI of a bot can call
+func (s *BotToolService) EnabledTools(ctx context.Context, botID string) ([]*entity.BotTool, error) {
+	tools, err := s.repo.ListByBot(ctx, botID)
+	if err != nil {
+		return nil, err
+	}
+	enabled := tools[:0]
+	for _, tool := range tools {
+		if tool.Enabled {
+			enabled = append(enabled, tool)
+		}
+	}
+	return enabled, nil
+}
+
+// Execute calls a tool with the arguments the AI gave and returns the result to feed
+// back to it. Failures and timeouts are results too, so the AI can tell the customer;
+// every call is recorded.
+func (s *BotToolService) Execute(ctx context.Context, tool *entity.BotTool, call *entity.ToolCall, callCtx *ToolCallContext) *entity.ToolResult {
+	execution := &entity.ToolExecution{
+		ID:         uuid.New().String(),
+		TenantID:   tool.TenantID,
+		BotID:      tool.BotID,
+		ToolID:     tool.ID,
+		ToolName:   tool.Name,
+		ToolCallID: call.ID,
+		Arguments:  call.Arguments,
+		CreatedAt:  s.now(),
+	}
+	if callCtx != nil {
+		execution.ConversationID = callCtx.ConversationID
+		execution.MessageID = callCtx.MessageID
+	}
+
+	start := time.Now()
+	statusCode, body, err := s.call(ctx, tool, call.Arguments)
+	execution.DurationMs = time.Since(start).Milliseconds()
+	execution.StatusCode = statusCode
+
+	result := &entity.ToolResult{ToolCallID: call.ID}
+	switch {
+	case err != nil && ctx.Err() == nil && stderrors.Is(err, context.DeadlineExceeded):
+		execution.Status = entity.ToolExecutionTimeout
+		execution.Error = fmt.Sprintf("no response within %ds", botToolTimeout(tool))
+	case err != nil:
+		execution.Status = entity.ToolExecutionError
+		execution.Error = err.Error()
+	case statusCode < 200 || statusCode >= 300:
+		execution.Status = entity.ToolExecutionError
+		execution.Error = fmt.Sprintf("HTTP %d", statusCode)
+		execution.Result = body
+	default:
+		execution.Status = entity.ToolExecutionSuccess
+		execution.Result = body
+		result.Content = body
+	}
+	if execution.Status != entity.ToolExecutionSuccess {
+		result.IsError = true
+		result.Content = "The " + tool.Name + " tool failed: " + execution.Error
+	}
+
+	if err := s.repo.CreateExecution(ctx, execution); err != nil {
+		logger.Warn("Failed to record tool execution",
+			zap.String("bot_id", tool.BotID),
+			zap.String("tool", tool.Name),
+			zap.Error(err),
+		)
+	}
+	return result
+}
+
+// call sends the arguments to the tool's endpoint and returns its response
+func (s *BotToolService) call(ctx context.Context, tool *entity.BotTool, arguments map[string]interface{}) (int, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(botToolTimeout(tool))*time.Second)
+	defer cancel()
+
+	var req *http.Request
+	var err error
+	if tool.Method == http.MethodGet {
+		endpoint, parseErr := url.Parse(tool.URL)
+		if parseErr != nil {
+			return 0, "", parseErr
+		}
+		query := endpoint.Query()
+		for name, value := range arguments {
+			if text, ok := value.(string); ok {
+				query.Set(name, text)
+			} else {
+				encoded, _ := json.Marshal(value)
+				query.Set(name, string(encoded))
+			}
+		}
+		endpoint.RawQuery = query.Encode()
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	} else {
+		if arguments == nil {
+			arguments = map[string]interface{}{}
+		}
+		body, marshalErr := json.Marshal(arguments)
+		if marshalErr != nil {
+			return 0, "", marshalErr
+		}
+		req, err = http.NewRequestWithContext(ctx, http.MethodPost, tool.URL, bytes.NewReader(body))
+		if err == nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+	}
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Accept", "application/json")
+	for name, value := range tool.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBotToolResult))
+	if err != nil {
+		return resp.StatusCode, "", err
+	}
+	return resp.StatusCode, string(body), nil
+}
+
+func (s *BotToolService) getBot(ctx context.Context, tenantID, botID string) (*entity.Bot, error) {
+	bot, err := s.botRepo.FindByID(ctx, botID)
+	if err != nil {
+		return nil, err
+	}
+	if bot.TenantID != tenantID {
+		return nil, errors.NotFound("bot")
+	}
+	return bot, nil
+}
+
+func (s *BotToolService) get(ctx context.Context, tenantID, botID, id string) (*entity.BotTool, error) {
+	tool, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tool.TenantID != tenantID || tool.BotID != botID {
+		return nil, errors.NotFound("bot tool")
+	}
+	return tool, nil
+}
+
+// apply validates an input and copies it onto a tool
+func (s *BotToolService) apply(ctx context.Context, tool *entity.BotTool, input *BotToolInput) error {
+	name := strings.TrimSpace(input.Name)
+	if !botToolNamePattern.MatchString(name) {
+		return errors.Validation("name must be 1 to 64 letters, digits, underscores or hyphens")
+	}
+	if strings.TrimSpace(input.Description) == "" {
+		return errors.Validation("description is required")
+	}
+	method := strings.ToUpper(strings.TrimSpace(input.Method))
+	if method == "" {
+		method = http.MethodPost
+	}
+	if method != http.MethodPost && method != http.MethodGet {
+		return errors.Validation("method must be POST or GET")
+	}
+	if !isWebhookURL(input.URL) {
+		return errors.Validation("url must be an http or https URL")
+	}
+	if err := s.checkURL(ctx, input.URL); err != nil {
+		return errors.Validation("url must not point to a private, loopback or link-local address")
+	}
+	if input.TimeoutSeconds < 0 || input.TimeoutSeconds > MaxBotToolTimeout {
+		return errors.Validation(fmt.Sprintf("timeout_seconds must be between 1 and %d", MaxBotToolTimeout))
+	}
+	parameters := input.Parameters
+	if len(parameters) == 0 {
+		parameters = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+	}
+	if parameters["type"] != "object" {
+		return errors.Validation("parameters must be a JSON Schema of type object")
+	}
+
+	existing, err := s.repo.ListByBot(ctx, tool.BotID)
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if other.ID != tool.ID && other.Name == name {
+			return errors.Conflict("the bot already has a tool named " + name)
+		}
+	}
+
+	tool.Name = name
+	tool.Description = strings.TrimSpace(input.Description)
+	tool.Parameters = parameters
+	tool.Method = method
+	tool.URL = input.URL
+	tool.Headers = input.Headers
+	tool.TimeoutSeconds = input.TimeoutSeconds
+	if tool.TimeoutSeconds == 0 {
+		tool.TimeoutSeconds = DefaultBotToolTimeout
+	}
+	if input.Enabled != nil {
+		tool.Enabled = *input.Enabled
+	}
+	return nil
+}
+
+func botToolTimeout(tool *entity.BotTool) int {
+	if tool.TimeoutSeconds <= 0 || tool.TimeoutSeconds > MaxBotToolTimeout {
+		return DefaultBotToolTimeout
+	}
+	return tool.TimeoutSeconds
+}
+
+// BotToolDefinitions returns the definitions of tools given to the AI
+func BotToolDefinitions(tools []*entity.BotTool) []*entity.Tool {
+	definitions := make([]*entity.Tool, 0, len(tools))
+	for _, tool := range tools {
+		definitions = append(definitions, tool.ToTool())
+	}
+	return definitions
+}
@@ -0,0 +1,263 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBotToolRepository struct {
+	mu         sync.Mutex
+	tools      map[string]*entity.BotTool
+	executions []*entity.ToolExecution
+}
+
+func newFakeBotToolRepository() *fakeBotToolRepository {
+	return &fakeBotToolRepository{tools: make(map[string]*entity.BotTool)}
+}
+
+func (r *fakeBotToolRepository) Create(_ context.Context, tool *entity.BotTool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tools[tool.ID] = tool
+	return nil
+}
+
+func (r *fakeBotToolRepository) Update(_ context.Context, tool *entity.BotTool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tools[tool.ID] = tool
+	return nil
+}
+
+func (r *fakeBotToolRepository) Delete(_ context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.tools, id)
+	return nil
+}
+
+func (r *fakeBotToolRepository) FindByID(_ context.Context, id string) (*entity.BotTool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tool, ok := r.tools[id]
+	if !ok {
+		return nil, errors.NotFound("bot tool")
+	}
+	return tool, nil
+}
+
+func (r *fakeBotToolRepository) ListByBot(_ context.Context, botID string) ([]*entity.BotTool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var tools []*entity.BotTool
+	for _, tool := range r.tools {
+		if tool.BotID == botID {
+			tools = append(tools, tool)
+		}
+	}
+	return tools, nil
+}
+
+func (r *fakeBotToolRepository) CreateExecution(_ context.Context, execution *entity.ToolExecution) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.executions = append(r.executions, execution)
+	return nil
+}
+
+func (r *fakeBotToolRepository) ListExecutions(_ context.Context, botID string, _ *repository.ListParams) ([]*entity.ToolExecution, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var executions []*entity.ToolExecution
+	for _, execution := range r.executions {
+		if execution.BotID == botID {
+			executions = append(executions, execution)
+		}
+	}
+	return executions, int64(len(executions)), nil
+}
+
+func newTestBotToolService() (*BotToolService, *fakeBotToolRepository) {
+	repo := newFakeBotToolRepository()
+	botRepo := NewMockBotRepository()
+	botRepo.Bots["bot-1"] = &entity.Bot{ID: "bot-1", TenantID: "tenant-1"}
+	svc := NewBotToolService(repo, botRepo)
+	svc.httpClient, svc.checkURL = http.DefaultClient, allowAnyURL
+	return svc, repo
+}
+
+func TestBotToolService_RefusesInternalEndpoints(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeBotToolRepository()
+	botRepo := NewMockBotRepository()
+	botRepo.Bots["bot-1"] = &entity.Bot{ID: "bot-1", TenantID: "tenant-1"}
+	svc := NewBotToolService(repo, botRepo)
+
+	for _, url := range []string{"http://169.254.169.254/latest/meta-data/", "http://127.0.0.1:8080/admin", "http://10.0.0.5/internal"} {
+		_, err := svc.Create(ctx, "tenant-1", "bot-1", &BotToolInput{Name: "lookup", Description: "Looks things up", URL: url})
+		assert.True(t, errors.IsValidation(err), url)
+	}
+
+	// Tools saved before the check are refused when called
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the request must not reach the server")
+	}))
+	defer server.Close()
+	tool := &entity.BotTool{ID: "tool-1", TenantID: "tenant-1", BotID: "bot-1", Name: "lookup", Method: http.MethodPost, URL: server.URL}
+	result := svc.Execute(ctx, tool, &entity.ToolCall{ID: "call-1", Name: "lookup"}, nil)
+	assert.True(t, result.IsError)
+}
+
+func TestBotToolService_Create(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("applies defaults", func(t *testing.T) {
+		svc, _ := newTestBotToolService()
+		tool, err := svc.Create(ctx, "tenant-1", "bot-1", &BotToolInput{
+			Name:        "order_status",
+			Description: "Looks up the status of an order",
+			URL:         "https://shop.example.com/orders",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, http.MethodPost, tool.Method)
+		assert.Equal(t, DefaultBotToolTimeout, tool.TimeoutSeconds)
+		assert.Equal(t, "object", tool.Parameters["type"])
+		assert.True(t, tool.Enabled)
+	})
+
+	t.Run("rejects invalid input", func(t *testing.T) {
+		svc, _ := newTestBotToolService()
+		inputs := []*BotToolInput{
+			{Name: "order status", Description: "d", URL: "https://shop.example.com"},
+			{Name: "order_status", URL: "https://shop.example.com"},
+			{Name: "order_status", Description: "d", URL: "ftp://shop.example.com"},
+			{Name: "order_status", Description: "d", URL: "https://shop.example.com", Method: "DELETE"},
+			{Name: "order_status", Description: "d", URL: "https://shop.example.com", TimeoutSeconds: MaxBotToolTimeout + 1},
+			{Name: "order_status", Description: "d", URL: "https://shop.example.com", Parameters: map[string]interface{}{"type": "string"}},
+		}
+		for _, input := range inputs {
+			_, err := svc.Create(ctx, "tenant-1", "bot-1", input)
+			assert.Equal(t, errors.ErrCodeValidation, errors.GetAppError(err).Code, input.Name)
+		}
+	})
+
+	t.Run("rejects duplicate names", func(t *testing.T) {
+		svc, _ := newTestBotToolService()
+		input := &BotToolInput{Name: "order_status", Description: "d", URL: "https://shop.example.com"}
+		_, err := svc.Create(ctx, "tenant-1", "bot-1", input)
+		require.NoError(t, err)
+		_, err = svc.Create(ctx, "tenant-1", "bot-1", input)
+		assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code)
+	})
+
+	t.Run("rejects bots of other tenants", func(t *testing.T) {
+		svc, _ := newTestBotToolService()
+		_, err := svc.Create(ctx, "tenant-2", "bot-1", &BotToolInput{Name: "order_status", Description: "d", URL: "https://shop.example.com"})
+		assert.Equal(t, errors.ErrCodeNotFound, errors.GetAppError(err).Code)
+	})
+}
+
+func TestBotToolService_Execute(t *testing.T) {
+	ctx := context.Background()
+	call := &entity.ToolCall{ID: "call-1", Name: "order_status", Arguments: map[string]interface{}{"order_id": "A-1"}}
+
+	t.Run("posts the arguments and returns the response", func(t *testing.T) {
+		var received map[string]interface{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+			_ = json.NewDecoder(r.Body).Decode(&received)
+			_, _ = w.Write([]byte(`{"status":"shipped"}`))
+		}))
+		defer server.Close()
+
+		svc, repo := newTestBotToolService()
+		tool := &entity.BotTool{ID: "tool-1", TenantID: "tenant-1", BotID: "bot-1", Name: "order_status", Method: http.MethodPost,
+			URL: server.URL, Headers: map[string]string{"Authorization": "Bearer secret"}}
+
+		result := svc.Execute(ctx, tool, call, &ToolCallContext{ConversationID: "conv-1", MessageID: "msg-1"})
+		assert.False(t, result.IsError)
+		assert.Equal(t, `{"status":"shipped"}`, result.Content)
+		assert.Equal(t, "call-1", result.ToolCallID)
+		assert.Equal(t, "A-1", received["order_id"])
+
+		require.Len(t, repo.executions, 1)
+		execution := repo.executions[0]
+		assert.Equal(t, entity.ToolExecutionSuccess, execution.Status)
+		assert.Equal(t, http.StatusOK, execution.StatusCode)
+		assert.Equal(t, "conv-1", execution.ConversationID)
+		assert.Equal(t, "msg-1", execution.MessageID)
+	})
+
+	t.Run("sends GET arguments as query parameters", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "A-1", r.URL.Query().Get("order_id"))
+			assert.Equal(t, "json", r.URL.Query().Get("format"))
+			_, _ = w.Write([]byte(`ok`))
+		}))
+		defer server.Close()
+
+		svc, _ := newTestBotToolService()
+		tool := &entity.BotTool{ID: "tool-1", BotID: "bot-1", Name: "order_status", Method: http.MethodGet, URL: server.URL + "?format=json"}
+		result := svc.Execute(ctx, tool, call, nil)
+		assert.False(t, result.IsError)
+	})
+
+	t.Run("reports endpoint errors to the AI", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		svc, repo := newTestBotToolService()
+		tool := &entity.BotTool{ID: "tool-1", BotID: "bot-1", Name: "order_status", Method: http.MethodPost, URL: server.URL}
+		result := svc.Execute(ctx, tool, call, nil)
+		assert.True(t, result.IsError)
+		assert.Contains(t, result.Content, "HTTP 500")
+		require.Len(t, repo.executions, 1)
+		assert.Equal(t, entity.ToolExecutionError, repo.executions[0].Status)
+	})
+
+	t.Run("times out slow endpoints", func(t *testing.T) {
+		release := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}))
+		defer server.Close()
+		defer close(release)
+
+		svc, repo := newTestBotToolService()
+		tool := &entity.BotTool{ID: "tool-1", BotID: "bot-1", Name: "order_status", Method: http.MethodPost, URL: server.URL, TimeoutSeconds: 1}
+		start := time.Now()
+		result := svc.Execute(ctx, tool, call, nil)
+		assert.Less(t, time.Since(start), 3*time.Second)
+		assert.True(t, result.IsError)
+		require.Len(t, repo.executions, 1)
+		assert.Equal(t, entity.ToolExecutionTimeout, repo.executions[0].Status)
+	})
+}
+
+func TestBotToolService_EnabledTools(t *testing.T) {
+	svc, repo := newTestBotToolService()
+	repo.tools["a"] = &entity.BotTool{ID: "a", BotID: "bot-1", Enabled: true}
+	repo.tools["b"] = &entity.BotTool{ID: "b", BotID: "bot-1", Enabled: false}
+	repo.tools["c"] = &entity.BotTool{ID: "c", BotID: "bot-2", Enabled: true}
+
+	tools, err := svc.EnabledTools(context.Background(), "bot-1")
+	require.NoError(t, err)
+	require.Len(t, tools, 1)
+	assert.Equal(t, "a", tools[0].ID)
+}
@@ -0,0 +1,385 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/adapters/voice"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/egress"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// JobTypeCallTranscribe downloads and transcribes the recording of a call
+	JobTypeCallTranscribe = "call.transcribe"
+
+	// EventCallTranscribed is published when a call transcript was posted
+	EventCallTranscribed = "call.transcribed"
+
+	// maxCallRecordingSize bounds the recordings downloaded for transcription
+	maxCallRecordingSize = 100 << 20
+)
+
+// CallTranscriptionService transcribes recorded voice calls with a speech-to-text
+// provider. Each transcript is posted to the caller's conversation as a message with
+// the recording attached, and kept as a call transcript that can be searched.
+type CallTranscriptionService struct {
+	repo        repository.CallTranscriptRepository
+	channelRepo repository.ChannelRepository
+	media       *MediaService
+	transcriber SpeechTranscriber
+	provider    string
+	producer    nats.Publisher
+	jobs        *JobService
+	httpClient  *http.Client
+	checkURL    func(ctx context.Context, endpoint string) error
+	now         func() time.Time
+}
+
+// NewCallTranscriptionService creates a new call transcription service. provider
+// names the speech-to-text provider behind transcriber.
+func NewCallTranscriptionService(
+	repo repository.CallTranscriptRepository,
+	channelRepo repository.ChannelRepository,
+	media *MediaService,
+	transcriber SpeechTranscriber,
+	provider string,
+	producer nats.Publisher,
+) *CallTranscriptionService {
+	return &CallTranscriptionService{
+		repo:        repo,
+		channelRepo: channelRepo,
+		media:       media,
+		transcriber: transcriber,
+		provider:    provider,
+		producer:    producer,
+		httpClient:  newRecordingClient(),
+		checkURL:    egress.CheckURL,
+		now:         time.Now,
+	}
+}
+
+// newRecordingClient creates the client downloading recordings, which refuses internal
+// addresses as recording URLs are given by API callers
+func newRecordingClient() *http.Client {
+	client := egress.NewClient()
+	client.Timeout = 5 * time.Minute
+	return client
+}
+
+// SetJobService enables transcription requests for recordings kept elsewhere
+func (s *CallTranscriptionService) SetJobService(jobs *JobService) {
+	s.jobs = jobs
+}
+
+// Handler returns the recording handler of a voice channel, to set on its adapter
+func (s *CallTranscriptionService) Handler(channel *entity.Channel) voice.RecordingHandler {
+	return &channelRecordingHandler{service: s, channel: channel}
+}
+
+type channelRecordingHandler struct {
+	service *CallTranscriptionService
+	channel *entity.Channel
+}
+
+func (h *channelRecordingHandler) HandleRecording(ctx context.Context, recording *voice.CallRecording, audio []byte, mimeType string) error {
+	_, err := h.service.Transcribe(ctx, h.channel, recording, audio, mimeType)
+	if err != nil {
+		logger.Warn("Failed to transcribe call",
+			zap.String("channel_id", h.channel.ID),
+			zap.String("call_id", recording.CallID),
+			zap.Error(err),
+		)
+	}
+	return err
+}
+
+// Transcribe stores and transcribes a call recording, records the transcript and
+// publishes it as an inbound message of the channel. Unlike voicemails, a call is
+// not posted when its transcription fails, since the call itself already reached
+// the conversation.
+func (s *CallTranscriptionService) Transcribe(ctx context.Context, channel *entity.Channel, recording *voice.CallRecording, audio []byte, mimeType string) (*entity.CallTranscript, error) {
+	if len(audio) == 0 {
+		return nil, errors.Validation("recording is empty")
+	}
+	if mimeType == "" {
+		mimeType = "audio/wav"
+	}
+
+	filename := path.Base(recording.Path)
+	if filename == "." || filename == "/" {
+		filename = fmt.Sprintf("call-%s.wav", recording.CallID)
+	}
+	text, err := s.transcriber.Transcribe(ctx, audio, filename, recording.Language)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transcribe call: %w", err)
+	}
+
+	transcript := &entity.CallTranscript{
+		ID:        uuid.New().String(),
+		TenantID:  channel.TenantID,
+		ChannelID: channel.ID,
+		CallID:    recording.CallID,
+		Direction: string(recording.Direction),
+		From:      recording.From,
+		To:        recording.To,
+		Language:  recording.Language,
+		Provider:  s.provider,
+		Text:      strings.TrimSpace(text),
+		Duration:  recording.Duration,
+		CreatedAt: s.now(),
+	}
+
+	messageID := uuid.New().String()
+	attachment := &entity.MessageAttachment{
+		Type:     "audio",
+		Filename: filename,
+		MimeType: mimeType,
+	}
+	if err := s.media.Store(ctx, channel.ID, messageID, attachment, audio); err != nil {
+		return nil, fmt.Errorf("failed to store call recording: %w", err)
+	}
+	attachment.Metadata["duration"] = strconv.Itoa(recording.Duration)
+	transcript.RecordingURL = attachment.URL
+
+	if err := s.repo.Create(ctx, transcript); err != nil {
+		return nil, err
+	}
+
+	// The contact is the remote party of the call
+	contact := recording.From
+	if recording.Direction == voice.CallDirectionOutbound {
+		contact = recording.To
+	}
+	timestamp := recording.RecordedAt
+	if timestamp.IsZero() {
+		timestamp = s.now()
+	}
+
+	inbound := &nats.InboundMessage{
+		ID:          messageID,
+		TenantID:    channel.TenantID,
+		ChannelID:   channel.ID,
+		ChannelType: string(channel.Type),
+		// Links the message, and through it the conversation, to the transcript
+		ExternalID:  transcript.ID,
+		ContentType: string(entity.ContentTypeAudio),
+		Content:     transcript.Text,
+		Metadata: map[string]string{
+			"sender_id":     contact,
+			"sender_name":   recording.CallerName,
+			"phone":         contact,
+			"source":        "call_transcript",
+			"call_id":       recording.CallID,
+			"direction":     string(recording.Direction),
+			"duration":      strconv.Itoa(recording.Duration),
+			"transcript_id": transcript.ID,
+			"transcription": transcript.Text,
+		},
+		Attachments: []nats.AttachmentData{{
+			Type:      attachment.Type,
+			URL:       attachment.URL,
+			Filename:  attachment.Filename,
+			MimeType:  attachment.MimeType,
+			SizeBytes: attachment.SizeBytes,
+			Metadata:  attachment.Metadata,
+		}},
+		Timestamp: timestamp,
+	}
+	if err := s.producer.PublishInbound(ctx, inbound); err != nil {
+		return nil, fmt.Errorf("failed to publish call transcript: %w", err)
+	}
+
+	if err := s.producer.PublishEvent(ctx, &nats.Event{
+		Type:     EventCallTranscribed,
+		TenantID: channel.TenantID,
+		Payload: map[string]interface{}{
+			"transcript_id": transcript.ID,
+			"channel_id":    channel.ID,
+			"call_id":       transcript.CallID,
+			"direction":     transcript.Direction,
+			"from":          transcript.From,
+			"to":            transcript.To,
+			"duration":      transcript.Duration,
+			"provider":      transcript.Provider,
+		},
+		Timestamp: s.now(),
+	}); err != nil {
+		logger.Warn("Failed to publish call transcribed event", zap.String("transcript_id", transcript.ID), zap.Error(err))
+	}
+
+	return transcript, nil
+}
+
+// TranscribeCallInput requests the transcription of a recording kept at a URL
+type TranscribeCallInput struct {
+	ChannelID    string `json:"channel_id" binding:"required"`
+	CallID       string `json:"call_id" binding:"required"`
+	RecordingURL string `json:"recording_url" binding:"required"`
+	Direction    string `json:"direction"`
+	From         string `json:"from"`
+	To           string `json:"to"`
+	Language     string `json:"language"`
+	Duration     int    `json:"duration"`
+}
+
+// RequestTranscription enqueues the transcription of a call recording, for
+// providers that keep recordings rather than delivering them
+func (s *CallTranscriptionService) RequestTranscription(ctx context.Context, tenantID, userID string, input *TranscribeCallInput) (*entity.Job, error) {
+	if s.jobs == nil {
+		return nil, errors.New(errors.ErrCodeInternal, "background jobs are not available")
+	}
+	if err := s.validateRecordingURL(ctx, input.RecordingURL); err != nil {
+		return nil, err
+	}
+	if input.Direction != "" && input.Direction != string(voice.CallDirectionInbound) && input.Direction != string(voice.CallDirectionOutbound) {
+		return nil, errors.Validation("direction must be inbound or outbound")
+	}
+	channel, err := s.channelRepo.FindByID(ctx, input.ChannelID)
+	if err != nil || channel.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeNotFound, "channel not found")
+	}
+	if channel.Type != entity.ChannelTypeVoice {
+		return nil, errors.Validation("channel is not a voice channel")
+	}
+
+	return s.jobs.Enqueue(ctx, tenantID, &JobInput{
+		Type: JobTypeCallTranscribe,
+		Payload: map[string]interface{}{
+			"channel_id":    input.ChannelID,
+			"call_id":       input.CallID,
+			"recording_url": input.RecordingURL,
+			"direction":     input.Direction,
+			"from":          input.From,
+			"to":            input.To,
+			"language":      input.Language,
+			"duration":      input.Duration,
+		},
+		CreatedBy: userID,
+	})
+}
+
+// RunTranscribeJob downloads and transcribes a recording requested with
+// RequestTranscription
+func (s *CallTranscriptionService) RunTranscribeJob(ctx context.Context, job *entity.Job, progress JobProgress) (map[string]interface{}, error) {
+	// Payloads read back from the database carry numbers as float64
+	var input TranscribeCallInput
+	data, _ := json.Marshal(job.Payload)
+	if err := json.Unmarshal(data, &input); err != nil || input.ChannelID == "" || input.RecordingURL == "" {
+		return nil, errors.Validation("invalid call transcription job payload")
+	}
+	channel, err := s.channelRepo.FindByID(ctx, input.ChannelID)
+	if err != nil || channel.TenantID != job.TenantID {
+		return nil, errors.Validation("channel no longer exists")
+	}
+
+	recording := &voice.CallRecording{
+		CallID:    input.CallID,
+		Direction: voice.CallDirection(input.Direction),
+		From:      input.From,
+		To:        input.To,
+		Language:  input.Language,
+		Duration:  input.Duration,
+	}
+
+	if err := progress(10, "downloading"); err != nil {
+		return nil, err
+	}
+	audio, mimeType, err := s.download(ctx, input.RecordingURL)
+	if err != nil {
+		return nil, err
+	}
+	if parsed, err := url.Parse(input.RecordingURL); err == nil {
+		recording.Path = parsed.Path
+	}
+
+	if err := progress(40, "transcribing"); err != nil {
+		return nil, err
+	}
+	transcript, err := s.Transcribe(ctx, channel, recording, audio, mimeType)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"transcript_id": transcript.ID,
+		"characters":    len(transcript.Text),
+	}, nil
+}
+
+// download fetches a recording for transcription
+func (s *CallTranscriptionService) download(ctx context.Context, recordingURL string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, recordingURL, nil)
+	if err != nil {
+		return nil, "", errors.Validation("invalid recording URL")
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download recording: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("failed to download recording: status %d", resp.StatusCode)
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+			return nil, "", errors.Wrap(err, errors.ErrCodeValidation, "recording is not available")
+		}
+		return nil, "", err
+	}
+	audio, err := io.ReadAll(io.LimitReader(resp.Body, maxCallRecordingSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download recording: %w", err)
+	}
+	if len(audio) > maxCallRecordingSize {
+		return nil, "", errors.Validation("recording is too large")
+	}
+
+	mimeType := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(mimeType, "audio/") {
+		mimeType = "audio/wav"
+	}
+	return audio, mimeType, nil
+}
+
+// Get returns a call transcript of a tenant
+func (s *CallTranscriptionService) Get(ctx context.Context, tenantID, id string) (*entity.CallTranscript, error) {
+	transcript, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if transcript.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeNotFound, "call transcript not found")
+	}
+	return transcript, nil
+}
+
+// Search searches the call transcripts of a tenant
+func (s *CallTranscriptionService) Search(ctx context.Context, tenantID string, filter *entity.CallTranscriptFilter, params *repository.ListParams) ([]*entity.CallTranscript, int64, error) {
+	filter.Query = strings.TrimSpace(filter.Query)
+	return s.repo.Search(ctx, tenantID, filter, params)
+}
+
+// validateRecordingURL accepts absolute HTTP(S) URLs outside the internal network
+func (s *CallTranscriptionService) validateRecordingURL(ctx context.Context, raw string) error {
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return errors.Validation("recording_url must be an http or https URL")
+	}
+	if err := s.checkURL(ctx, raw); err != nil {
+		return errors.Validation("recording_url must not point to a private, loopback or link-local address")
+	}
+	return nil
+}
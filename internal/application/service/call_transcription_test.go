@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/adapters/voice"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCallTranscriptRepository struct {
+	transcripts map[string]*entity.CallTranscript
+}
+
+func (f *fakeCallTranscriptRepository) Create(ctx context.Context, transcript *entity.CallTranscript) error {
+	stored := *transcript
+	f.transcripts[transcript.ID] = &stored
+	return nil
+}
+
+func (f *fakeCallTranscriptRepository) FindByID(ctx context.Context, id string) (*entity.CallTranscript, error) {
+	transcript, ok := f.transcripts[id]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, "call transcript not found")
+	}
+	found := *transcript
+	return &found, nil
+}
+
+func (f *fakeCallTranscriptRepository) Search(ctx context.Context, tenantID string, filter *entity.CallTranscriptFilter, params *repository.ListParams) ([]*entity.CallTranscript, int64, error) {
+	var found []*entity.CallTranscript
+	for _, transcript := range f.transcripts {
+		if transcript.TenantID == tenantID {
+			found = append(found, transcript)
+		}
+	}
+	return found, int64(len(found)), nil
+}
+
+func newCallTranscriptionTestService(t *testing.T, transcriber SpeechTranscriber) (*CallTranscriptionService, *fakeCallTranscriptRepository, *testutil.MockProducer) {
+	media, _ := newMediaTestService(t)
+	repo := &fakeCallTranscriptRepository{transcripts: make(map[string]*entity.CallTranscript)}
+	channels := testutil.NewMockChannelRepository()
+	channels.Channels["channel-1"] = &entity.Channel{ID: "channel-1", TenantID: "tenant-1", Type: entity.ChannelTypeVoice}
+	channels.Channels["channel-2"] = &entity.Channel{ID: "channel-2", TenantID: "tenant-1", Type: entity.ChannelTypeWebChat}
+	producer := testutil.NewMockProducer()
+	return NewCallTranscriptionService(repo, channels, media, transcriber, "whispercpp", producer), repo, producer
+}
+
+func TestCallTranscriptionService_Transcribe(t *testing.T) {
+	transcriber := &fakeTranscriber{text: " Bom dia, quero cancelar minha assinatura. "}
+	svc, repo, producer := newCallTranscriptionTestService(t, transcriber)
+
+	ctx := context.Background()
+	channel := &entity.Channel{ID: "channel-1", TenantID: "tenant-1", Type: entity.ChannelTypeVoice}
+	recordedAt := time.Now()
+	recording := &voice.CallRecording{
+		CallID:     "call-1",
+		Direction:  voice.CallDirectionOutbound,
+		From:       "+551130000000",
+		To:         "+5511999999999",
+		Language:   "pt-BR",
+		Path:       "/var/recordings/calls/call-1.wav",
+		Duration:   95,
+		RecordedAt: recordedAt,
+	}
+
+	require.NoError(t, svc.Handler(channel).HandleRecording(ctx, recording, []byte("RIFF-call"), "audio/wav"))
+	assert.Equal(t, "pt-BR", transcriber.language)
+
+	require.Len(t, producer.InboundMessages, 1)
+	inbound := producer.InboundMessages[0]
+	transcript := repo.transcripts[inbound.ExternalID]
+	require.NotNil(t, transcript, "the message links to its transcript")
+	assert.Equal(t, "Bom dia, quero cancelar minha assinatura.", transcript.Text)
+	assert.Equal(t, "whispercpp", transcript.Provider)
+	assert.Equal(t, "outbound", transcript.Direction)
+	assert.Equal(t, 95, transcript.Duration)
+	assert.NotEmpty(t, transcript.RecordingURL)
+
+	assert.Equal(t, transcript.Text, inbound.Content)
+	assert.Equal(t, "call_transcript", inbound.Metadata["source"])
+	assert.Equal(t, "+5511999999999", inbound.Metadata["phone"], "outbound calls belong to the callee's conversation")
+	assert.True(t, inbound.Timestamp.Equal(recordedAt))
+	require.Len(t, inbound.Attachments, 1)
+	assert.Equal(t, "call-1.wav", inbound.Attachments[0].Filename)
+
+	require.Len(t, producer.Events, 1)
+	assert.Equal(t, EventCallTranscribed, producer.Events[0].Type)
+}
+
+func TestCallTranscriptionService_TranscriptionFailure(t *testing.T) {
+	svc, repo, producer := newCallTranscriptionTestService(t, &fakeTranscriber{err: fmt.Errorf("provider down")})
+
+	channel := &entity.Channel{ID: "channel-1", TenantID: "tenant-1", Type: entity.ChannelTypeVoice}
+	_, err := svc.Transcribe(context.Background(), channel, &voice.CallRecording{CallID: "call-1"}, []byte("RIFF"), "audio/wav")
+	assert.ErrorContains(t, err, "provider down")
+	assert.Empty(t, repo.transcripts)
+	assert.Empty(t, producer.InboundMessages)
+}
+
+func TestCallTranscriptionService_RunTranscribeJob(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/recordings/call-7.mp3" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Write([]byte("ID3-call"))
+	}))
+	defer server.Close()
+
+	transcriber := &fakeTranscriber{text: "Obrigado pela ligação."}
+	svc, repo, producer := newCallTranscriptionTestService(t, transcriber)
+	svc.httpClient = http.DefaultClient
+	noProgress := func(int, string) error { return nil }
+
+	// Numbers come back from the job store as float64
+	job := &entity.Job{ID: "job-1", TenantID: "tenant-1", Payload: map[string]interface{}{
+		"channel_id":    "channel-1",
+		"call_id":       "call-7",
+		"recording_url": server.URL + "/recordings/call-7.mp3",
+		"direction":     "inbound",
+		"from":          "+5511988887777",
+		"duration":      float64(42),
+	}}
+	result, err := svc.RunTranscribeJob(context.Background(), job, noProgress)
+	require.NoError(t, err)
+
+	transcript := repo.transcripts[result["transcript_id"].(string)]
+	require.NotNil(t, transcript)
+	assert.Equal(t, 42, transcript.Duration)
+	assert.Equal(t, "+5511988887777", transcript.From)
+	require.Len(t, producer.InboundMessages, 1)
+	assert.Equal(t, "audio/mpeg", producer.InboundMessages[0].Attachments[0].MimeType)
+
+	job.Payload["recording_url"] = server.URL + "/missing.wav"
+	_, err = svc.RunTranscribeJob(context.Background(), job, noProgress)
+	assert.True(t, errors.IsValidation(err), "missing recordings are not retried")
+
+	job.Payload["channel_id"] = "channel-9"
+	_, err = svc.RunTranscribeJob(context.Background(), job, noProgress)
+	assert.True(t, errors.IsValidation(err))
+}
+
+func TestCallTranscriptionService_RequestTranscription(t *testing.T) {
+	svc, _, _ := newCallTranscriptionTestService(t, &fakeTranscriber{})
+	ctx := context.Background()
+
+	_, err := svc.RequestTranscription(ctx, "tenant-1", "user-1", &TranscribeCallInput{ChannelID: "channel-1", CallID: "call-1", RecordingURL: "https://pbx.example.com/call-1.wav"})
+	assert.Error(t, err, "requests need background jobs")
+
+	svc.SetJobService(NewJobService(newMockJobRepo(), nil))
+
+	_, err = svc.RequestTranscription(ctx, "tenant-1", "user-1", &TranscribeCallInput{ChannelID: "channel-1", CallID: "call-1", RecordingURL: "file:///etc/passwd"})
+	assert.True(t, errors.IsValidation(err))
+
+	for _, url := range []string{"http://169.254.169.254/latest/meta-data/", "http://127.0.0.1:8088/recordings/call-1.wav", "http://10.0.0.5/call-1.wav"} {
+		_, err = svc.RequestTranscription(ctx, "tenant-1", "user-1", &TranscribeCallInput{ChannelID: "channel-1", CallID: "call-1", RecordingURL: url})
+		assert.True(t, errors.IsValidation(err), url)
+	}
+
+	_, err = svc.RequestTranscription(ctx, "tenant-1", "user-1", &TranscribeCallInput{ChannelID: "channel-2", CallID: "call-1", RecordingURL: "https://pbx.example.com/call-1.wav"})
+	assert.True(t, errors.IsValidation(err), "only voice channels have calls")
+
+	_, err = svc.RequestTranscription(ctx, "tenant-2", "user-1", &TranscribeCallInput{ChannelID: "channel-1", CallID: "call-1", RecordingURL: "https://pbx.example.com/call-1.wav"})
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestCallTranscriptionService_RefusesInternalRecordings(t *testing.T) {
+	// Jobs queued before the check are refused when run
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the request must not reach the server")
+	}))
+	defer server.Close()
+
+	svc, _, _ := newCallTranscriptionTestService(t, &fakeTranscriber{})
+	job := &entity.Job{ID: "job-1", TenantID: "tenant-1", Payload: map[string]interface{}{
+		"channel_id":    "channel-1",
+		"call_id":       "call-7",
+		"recording_url": server.URL + "/recordings/call-7.mp3",
+	}}
+	_, err := svc.RunTranscribeJob(context.Background(), job, func(int, string) error { return nil })
+	assert.Error(t, err)
+}
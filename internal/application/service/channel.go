@@ -55,6 +55,7 @@ type ConnectResult struct {
 // ChannelLifecycleHooks lets composition roots react to channel lifecycle changes
 // without coupling the channel service to transport-specific handlers.
 type ChannelLifecycleHooks struct {
+	OnCreated      func(ctx context.Context, channel *entity.Channel)
 	OnConnected    func(ctx context.Context, channel *entity.Channel)
 	OnDisconnected func(ctx context.Context, channel *entity.Channel)
 	OnUpdated      func(ctx context.Context, channel *entity.Channel)
@@ -62,26 +63,51 @@ type ChannelLifecycleHooks struct {
 
 // ChannelService handles channel operations
 type ChannelService struct {
-	repo     repository.ChannelRepository
-	registry *plugin.Registry
-	producer nats.Publisher
-	hooks    ChannelLifecycleHooks
+	repo       repository.ChannelRepository
+	channels   *repository.TenantScope[entity.Channel]
+	registry   *plugin.Registry
+	producer   nats.Publisher
+	hooks      ChannelLifecycleHooks
+	sessionDSN string
+
+	disappearingListener DisappearingTimerListener
 }
 
+// DisappearingTimerListener is told when the disappearing timer of a WhatsApp private
+// chat changes, chat being the contact's phone number
+type DisappearingTimerListener func(ctx context.Context, channel *entity.Channel, chat string, timer time.Duration, at time.Time) error
+
 // NewChannelService creates a new channel service
 func NewChannelService(repo repository.ChannelRepository, registry *plugin.Registry, producer nats.Publisher) *ChannelService {
 	return &ChannelService{
 		repo:     repo,
+		channels: repository.TenantChannels(repo, channelNotFound),
 		registry: registry,
 		producer: producer,
 	}
 }
 
+// channelNotFound is the error of missing channels and of those of other tenants
+func channelNotFound() error {
+	return errors.New(errors.ErrCodeChannelNotFound, "channel not found")
+}
+
 // SetLifecycleHooks configures callbacks for channel lifecycle changes.
 func (s *ChannelService) SetLifecycleHooks(hooks ChannelLifecycleHooks) {
 	s.hooks = hooks
 }
 
+// SetWhatsAppSessionStore keeps WhatsApp unofficial sessions in the given PostgreSQL
+// database instead of per-instance SQLite files, so any instance can resume them.
+func (s *ChannelService) SetWhatsAppSessionStore(dsn string) {
+	s.sessionDSN = dsn
+}
+
+// SetDisappearingListener sets the listener of WhatsApp disappearing timer changes
+func (s *ChannelService) SetDisappearingListener(listener DisappearingTimerListener) {
+	s.disappearingListener = listener
+}
+
 // List returns all channels for a tenant
 func (s *ChannelService) List(ctx context.Context, tenantID string) ([]*entity.Channel, error) {
 	if s.repo == nil {
@@ -119,17 +145,24 @@ func (s *ChannelService) Create(ctx context.Context, input *CreateChannelInput)
 		return nil, err
 	}
 
+	s.notifyChannelCreated(ctx, channel)
 	return channel, nil
 }
 
-// GetByID returns a channel by ID
-func (s *ChannelService) GetByID(ctx context.Context, id string) (*entity.Channel, error) {
+// GetByID returns a channel of a tenant by ID
+func (s *ChannelService) GetByID(ctx context.Context, tenantID, id string) (*entity.Channel, error) {
+	return s.channels.FindByID(ctx, tenantID, id)
+}
+
+// GetForWebhook returns a channel by ID alone, for provider webhooks addressed by
+// channel ID outside any tenant session
+func (s *ChannelService) GetForWebhook(ctx context.Context, id string) (*entity.Channel, error) {
 	return s.repo.FindByID(ctx, id)
 }
 
 // Update updates a channel
-func (s *ChannelService) Update(ctx context.Context, id string, input *UpdateChannelInput) (*entity.Channel, error) {
-	channel, err := s.repo.FindByID(ctx, id)
+func (s *ChannelService) Update(ctx context.Context, tenantID, id string, input *UpdateChannelInput) (*entity.Channel, error) {
+	channel, err := s.channels.FindByID(ctx, tenantID, id)
 	if err != nil {
 		return nil, err
 	}
@@ -157,13 +190,16 @@ func (s *ChannelService) Update(ctx context.Context, id string, input *UpdateCha
 }
 
 // Delete deletes a channel
-func (s *ChannelService) Delete(ctx context.Context, id string) error {
+func (s *ChannelService) Delete(ctx context.Context, tenantID, id string) error {
+	if _, err := s.channels.FindByID(ctx, tenantID, id); err != nil {
+		return err
+	}
 	return s.repo.Delete(ctx, id)
 }
 
 // UpdateEnabled updates the channel enabled state
-func (s *ChannelService) UpdateEnabled(ctx context.Context, id string, enabled bool) (*entity.Channel, error) {
-	channel, err := s.repo.FindByID(ctx, id)
+func (s *ChannelService) UpdateEnabled(ctx context.Context, tenantID, id string, enabled bool) (*entity.Channel, error) {
+	channel, err := s.channels.FindByID(ctx, tenantID, id)
 	if err != nil {
 		return nil, err
 	}
@@ -178,8 +214,8 @@ func (s *ChannelService) UpdateEnabled(ctx context.Context, id string, enabled b
 }
 
 // UpdateStatus updates channel status (handles both enabled and connection_status for backwards compatibility)
-func (s *ChannelService) UpdateStatus(ctx context.Context, id string, status string) (*entity.Channel, error) {
-	channel, err := s.repo.FindByID(ctx, id)
+func (s *ChannelService) UpdateStatus(ctx context.Context, tenantID, id string, status string) (*entity.Channel, error) {
+	channel, err := s.channels.FindByID(ctx, tenantID, id)
 	if err != nil {
 		return nil, err
 	}
@@ -205,11 +241,11 @@ func (s *ChannelService) UpdateStatus(ctx context.Context, id string, status str
 }
 
 // Connect connects a channel
-func (s *ChannelService) Connect(ctx context.Context, id string) (*ConnectResult, error) {
+func (s *ChannelService) Connect(ctx context.Context, tenantID, id string) (*ConnectResult, error) {
 	logger.Info("Connect called",
 		zap.String("channel_id", id))
 
-	channel, err := s.repo.FindByID(ctx, id)
+	channel, err := s.channels.FindByID(ctx, tenantID, id)
 	if err != nil {
 		logger.Error("Failed to find channel", zap.String("channel_id", id), zap.Error(err))
 		return nil, err
@@ -270,15 +306,7 @@ func (s *ChannelService) connectWhatsAppUnofficial(ctx context.Context, channel
 	adapter := whatsapp.NewAdapter()
 
 	// Configure adapter
-	config := map[string]string{
-		"channel_id":    channel.ID,
-		"database_path": fmt.Sprintf("storages/whatsapp_%s.db", channel.ID),
-	}
-
-	// Add device name from channel config if available
-	if deviceName, ok := channel.Config["device_name"]; ok && deviceName != "" {
-		config["device_name"] = deviceName
-	}
+	config := s.whatsAppAdapterConfig(channel)
 
 	// Initialize adapter
 	if err := adapter.Initialize(config); err != nil {
@@ -393,6 +421,53 @@ func (s *ChannelService) connectWhatsAppUnofficial(ctx context.Context, channel
 	}
 }
 
+// whatsAppAdapterConfig builds the WhatsApp unofficial adapter configuration of a channel
+func (s *ChannelService) whatsAppAdapterConfig(channel *entity.Channel) map[string]string {
+	config := map[string]string{
+		"channel_id":    channel.ID,
+		"database_path": fmt.Sprintf("storages/whatsapp_%s.db", channel.ID),
+	}
+
+	// Add device name from channel config if available
+	if deviceName, ok := channel.Config["device_name"]; ok && deviceName != "" {
+		config["device_name"] = deviceName
+	}
+
+	if s.sessionDSN != "" {
+		config["session_dsn"] = s.sessionDSN
+		config["device_jid"] = channel.Config["device_jid"]
+	}
+
+	return config
+}
+
+// rememberWhatsAppDevice stores the logged in device's JID on the channel so the
+// session can be found again in the shared session store
+func (s *ChannelService) rememberWhatsAppDevice(ctx context.Context, channelID string, adapter *whatsapp.Adapter) {
+	if s.sessionDSN == "" {
+		return
+	}
+	info := adapter.GetDeviceInfo()
+	if info == nil || info.ID == "" {
+		return
+	}
+
+	channel, err := s.repo.FindByID(ctx, channelID)
+	if err != nil || channel.Config["device_jid"] == info.ID {
+		return
+	}
+	if channel.Config == nil {
+		channel.Config = make(map[string]string)
+	}
+	channel.Config["device_jid"] = info.ID
+	channel.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, channel); err != nil {
+		logger.Error("Failed to store WhatsApp device JID",
+			zap.String("channel_id", channelID),
+			zap.Error(err))
+	}
+}
+
 // monitorWhatsAppLoginStatus monitors the QR channel for login success
 // and updates the channel status accordingly
 func (s *ChannelService) monitorWhatsAppLoginStatus(channelID string, adapter *whatsapp.Adapter, qrChan <-chan whatsapp.QRCodeEvent) {
@@ -410,6 +485,7 @@ func (s *ChannelService) monitorWhatsAppLoginStatus(channelID string, adapter *w
 				if adapter.IsLoggedIn() {
 					logger.Info("WhatsApp login successful",
 						zap.String("channel_id", channelID))
+					s.rememberWhatsAppDevice(context.Background(), channelID, adapter)
 					s.updateChannelConnectionStatus(channelID, entity.ConnectionStatusConnected)
 				} else {
 					logger.Warn("WhatsApp login failed or timed out",
@@ -535,8 +611,10 @@ func (s *ChannelService) setupWhatsAppHandlers(adapter *whatsapp.Adapter, channe
 		}
 
 		// Build status update for NATS
+		// WhatsApp receipts carry the WhatsApp message ID, which is the external ID of the message
 		statusUpdate := &nats.StatusUpdate{
-			MessageID:   status.MessageID,
+			ExternalID:  status.ExternalID,
+			Recipient:   status.Recipient,
 			ChannelType: string(channel.Type),
 			Status:      string(status.Status),
 			Timestamp:   status.Timestamp,
@@ -560,12 +638,27 @@ func (s *ChannelService) setupWhatsAppHandlers(adapter *whatsapp.Adapter, channe
 		return nil
 	})
 
+	// Set disappearing handler - called when disappearing messages change in a chat
+	adapter.SetDisappearingHandler(func(ctx context.Context, change *whatsapp.DisappearingTimerChange) error {
+		logger.Debug("WhatsApp disappearing timer changed",
+			zap.String("channel_id", channel.ID),
+			zap.String("chat", change.ChatJID.String()),
+			zap.Duration("timer", change.Timer))
+
+		// Conversations are kept per contact, group chats have none
+		if change.IsGroup || s.disappearingListener == nil {
+			return nil
+		}
+		return s.disappearingListener(ctx, channel, change.ChatJID.User, change.Timer, change.Timestamp)
+	})
+
 	// Set connection handler - called when connection state changes (login success, disconnect, etc.)
 	adapter.SetConnectionHandler(func(ctx context.Context, connected bool, reason string) error {
 		if connected {
 			logger.Info("WhatsApp channel connected",
 				zap.String("channel_id", channel.ID),
 				zap.String("reason", reason))
+			s.rememberWhatsAppDevice(ctx, channel.ID, adapter)
 		} else {
 			logger.Warn("WhatsApp channel disconnected",
 				zap.String("channel_id", channel.ID),
@@ -618,10 +711,10 @@ func (s *ChannelService) setupWhatsAppHandlers(adapter *whatsapp.Adapter, channe
 }
 
 // Disconnect disconnects a channel
-func (s *ChannelService) Disconnect(ctx context.Context, id string) error {
+func (s *ChannelService) Disconnect(ctx context.Context, tenantID, id string) error {
 	logger.Info("Disconnecting channel", zap.String("channel_id", id))
 
-	channel, err := s.repo.FindByID(ctx, id)
+	channel, err := s.channels.FindByID(ctx, tenantID, id)
 	if err != nil {
 		logger.Error("Failed to find channel for disconnect",
 			zap.String("channel_id", id),
@@ -687,13 +780,83 @@ func (s *ChannelService) ReconnectWhatsAppChannels(ctx context.Context) (int, er
 	return reconnected, nil
 }
 
+// ReloadSecretCredentials tells the lifecycle hooks that the channels with credentials
+// pointing into a rotated secret changed, so live adapters and senders pick up the
+// new values without a restart. secretRef is given as secret://<backend>/<path>.
+func (s *ChannelService) ReloadSecretCredentials(ctx context.Context, secretRef string) (int, error) {
+	channels, err := s.repo.FindBySecretRef(ctx, secretRef)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, channel := range channels {
+		logger.Info("Reloading channel credentials after secret rotation",
+			zap.String("channel_id", channel.ID),
+			zap.String("tenant_id", channel.TenantID),
+			zap.String("secret", secretRef))
+		s.notifyChannelUpdated(ctx, channel)
+	}
+	return len(channels), nil
+}
+
+// IsWhatsAppSessionLive reports whether this instance holds a logged in WhatsApp session for the channel
+func (s *ChannelService) IsWhatsAppSessionLive(channelID string) bool {
+	if s.registry == nil {
+		return false
+	}
+	adapter, err := s.registry.GetAdapterByChannelID(channelID)
+	if err != nil {
+		return false
+	}
+	waAdapter, ok := adapter.(*whatsapp.Adapter)
+	return ok && waAdapter.IsLoggedIn()
+}
+
+// SetWhatsAppDisappearingTimer turns disappearing messages on or off in a chat of the
+// channel's WhatsApp session on this instance
+func (s *ChannelService) SetWhatsAppDisappearingTimer(ctx context.Context, channelID, to string, timer time.Duration) error {
+	if s.registry == nil {
+		return errors.New(errors.ErrCodeChannelDisconnected, "no valid WhatsApp session")
+	}
+	adapter, err := s.registry.GetAdapterByChannelID(channelID)
+	if err != nil {
+		return errors.New(errors.ErrCodeChannelDisconnected, "no valid WhatsApp session")
+	}
+	waAdapter, ok := adapter.(*whatsapp.Adapter)
+	if !ok {
+		return errors.New(errors.ErrCodeBadRequest, "channel does not support disappearing messages")
+	}
+	return waAdapter.SetDisappearingTimer(ctx, to, timer)
+}
+
+// ResumeWhatsAppSession connects the channel's stored WhatsApp session on this instance
+func (s *ChannelService) ResumeWhatsAppSession(ctx context.Context, channel *entity.Channel) error {
+	if err := s.reconnectWhatsAppChannel(ctx, channel); err != nil {
+		return err
+	}
+	if !s.IsWhatsAppSessionLive(channel.ID) {
+		return errors.New(errors.ErrCodeChannelDisconnected, "no valid WhatsApp session")
+	}
+	return nil
+}
+
+// StopWhatsAppSession closes the channel's WhatsApp session on this instance without
+// logging out or changing the channel's connection status, so another instance can
+// resume it
+func (s *ChannelService) StopWhatsAppSession(ctx context.Context, channelID string) error {
+	if s.registry == nil {
+		return nil
+	}
+	return s.registry.DisconnectChannel(ctx, channelID)
+}
+
 // RequestPairCode requests a pair code for WhatsApp authentication (alternative to QR code)
-func (s *ChannelService) RequestPairCode(ctx context.Context, id string, phoneNumber string) (*ConnectResult, error) {
+func (s *ChannelService) RequestPairCode(ctx context.Context, tenantID, id string, phoneNumber string) (*ConnectResult, error) {
 	logger.Info("Requesting WhatsApp pair code",
 		zap.String("channel_id", id),
 		zap.String("phone_number", phoneNumber))
 
-	channel, err := s.repo.FindByID(ctx, id)
+	channel, err := s.channels.FindByID(ctx, tenantID, id)
 	if err != nil {
 		logger.Error("Failed to find channel for pair code",
 			zap.String("channel_id", id),
@@ -750,14 +913,7 @@ func (s *ChannelService) RequestPairCode(ctx context.Context, id string, phoneNu
 	// Create new adapter instance
 	adapter := whatsapp.NewAdapter()
 
-	config := map[string]string{
-		"channel_id":    channel.ID,
-		"database_path": fmt.Sprintf("storages/whatsapp_%s.db", channel.ID),
-	}
-
-	if deviceName, ok := channel.Config["device_name"]; ok && deviceName != "" {
-		config["device_name"] = deviceName
-	}
+	config := s.whatsAppAdapterConfig(channel)
 
 	// Initialize adapter
 	if err := adapter.Initialize(config); err != nil {
@@ -815,14 +971,7 @@ func (s *ChannelService) reconnectWhatsAppChannel(ctx context.Context, channel *
 	// Create adapter instance
 	adapter := whatsapp.NewAdapter()
 
-	config := map[string]string{
-		"channel_id":    channel.ID,
-		"database_path": fmt.Sprintf("storages/whatsapp_%s.db", channel.ID),
-	}
-
-	if deviceName, ok := channel.Config["device_name"]; ok && deviceName != "" {
-		config["device_name"] = deviceName
-	}
+	config := s.whatsAppAdapterConfig(channel)
 
 	// Initialize adapter
 	if err := adapter.Initialize(config); err != nil {
@@ -871,6 +1020,12 @@ func (s *ChannelService) reconnectWhatsAppChannel(ctx context.Context, channel *
 	return nil
 }
 
+func (s *ChannelService) notifyChannelCreated(ctx context.Context, channel *entity.Channel) {
+	if s.hooks.OnCreated != nil {
+		s.hooks.OnCreated(ctx, channel)
+	}
+}
+
 func (s *ChannelService) notifyChannelConnected(ctx context.Context, channel *entity.Channel) {
 	if s.hooks.OnConnected != nil {
 		s.hooks.OnConnected(ctx, channel)
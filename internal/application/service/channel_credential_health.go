@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Channel credential checks
+const (
+	channelCredentialCheckInterval = 24 * time.Hour      // Credentials are checked again after this long
+	channelCredentialExpiryWarning = 14 * 24 * time.Hour // Tokens expiring sooner are flagged
+	channelCredentialTimeout       = 30 * time.Second
+)
+
+// ChannelCredentialHealthService validates the stored credentials of channels with
+// their providers every day: Meta tokens are inspected with debug_token, Twilio
+// accounts fetched and SMTP servers logged in to. Channels whose credentials fail or
+// whose tokens expire soon are flagged in the channel list and raised in the tenant's
+// notification center, which is resolved once the credentials work again.
+type ChannelCredentialHealthService struct {
+	repo          repository.ChannelCredentialHealthRepository
+	channelRepo   repository.ChannelRepository
+	channels      *repository.TenantScope[entity.Channel]
+	probers       map[entity.ChannelType]ChannelProber
+	tokenProvider ChannelTokenProvider
+	notifications *NotificationService
+	now           func() time.Time
+}
+
+// NewChannelCredentialHealthService creates a new channel credential health service
+func NewChannelCredentialHealthService(repo repository.ChannelCredentialHealthRepository, channelRepo repository.ChannelRepository) *ChannelCredentialHealthService {
+	return &ChannelCredentialHealthService{
+		repo:          repo,
+		channelRepo:   channelRepo,
+		channels:      repository.TenantChannels(channelRepo, channelNotFound),
+		probers:       defaultChannelProbers(),
+		tokenProvider: metaTokenProvider{},
+		now:           time.Now,
+	}
+}
+
+// SetProber sets the prober checking the credentials of a channel type
+func (s *ChannelCredentialHealthService) SetProber(channelType entity.ChannelType, prober ChannelProber) {
+	s.probers[channelType] = prober
+}
+
+// SetTokenProvider sets the provider Meta access tokens are inspected with
+func (s *ChannelCredentialHealthService) SetTokenProvider(provider ChannelTokenProvider) {
+	s.tokenProvider = provider
+}
+
+// SetNotificationService raises flagged channels in the notification center
+func (s *ChannelCredentialHealthService) SetNotificationService(notifications *NotificationService) {
+	s.notifications = notifications
+}
+
+// Get returns the latest credential check of a channel of the tenant
+func (s *ChannelCredentialHealthService) Get(ctx context.Context, tenantID, channelID string) (*entity.ChannelCredentialHealth, error) {
+	if _, err := s.channels.FindByID(ctx, tenantID, channelID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByChannel(ctx, channelID)
+}
+
+// Check validates the credentials of a channel of the tenant now
+func (s *ChannelCredentialHealthService) Check(ctx context.Context, tenantID, channelID string) (*entity.ChannelCredentialHealth, error) {
+	channel, err := s.channels.FindByID(ctx, tenantID, channelID)
+	if err != nil {
+		return nil, err
+	}
+	return s.check(ctx, channel)
+}
+
+// List lists the latest credential checks of the tenant's channels, only those
+// needing attention when flaggedOnly is set
+func (s *ChannelCredentialHealthService) List(ctx context.Context, tenantID string, flaggedOnly bool) ([]*entity.ChannelCredentialHealth, error) {
+	checks, err := s.repo.FindByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if !flaggedOnly {
+		return checks, nil
+	}
+	flagged := make([]*entity.ChannelCredentialHealth, 0, len(checks))
+	for _, health := range checks {
+		if health.Flagged() {
+			flagged = append(flagged, health)
+		}
+	}
+	return flagged, nil
+}
+
+// Annotate sets the latest credential check on channels of the tenant
+func (s *ChannelCredentialHealthService) Annotate(ctx context.Context, tenantID string, channels []*entity.Channel) error {
+	checks, err := s.repo.FindByTenant(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	byChannel := make(map[string]*entity.ChannelCredentialHealth, len(checks))
+	for _, health := range checks {
+		byChannel[health.ChannelID] = health
+	}
+	for _, channel := range channels {
+		channel.CredentialHealth = byChannel[channel.ID]
+	}
+	return nil
+}
+
+// CheckInBackground checks the credentials of a channel that was just connected
+// without holding up the caller, clearing flags raised on its old credentials
+func (s *ChannelCredentialHealthService) CheckInBackground(ctx context.Context, channel *entity.Channel) {
+	if s.probers[channel.Type] == nil {
+		return
+	}
+	checked := *channel
+	checked.Credentials = make(map[string]string, len(channel.Credentials))
+	for key, value := range channel.Credentials {
+		checked.Credentials[key] = value
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), channelCredentialTimeout)
+		defer cancel()
+		if _, err := s.check(ctx, &checked); err != nil {
+			logger.Warn("Failed to check channel credentials",
+				zap.String("channel_id", checked.ID),
+				zap.Error(err),
+			)
+		}
+	}()
+}
+
+// Run checks the credentials of enabled channels not checked in the last day,
+// returning how many channels are flagged
+func (s *ChannelCredentialHealthService) Run(ctx context.Context) (int, error) {
+	types := make([]entity.ChannelType, 0, len(s.probers))
+	for channelType := range s.probers {
+		types = append(types, channelType)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	channels, err := s.channelRepo.FindByTypes(ctx, types)
+	if err != nil {
+		return 0, err
+	}
+
+	flagged := 0
+	for _, channel := range channels {
+		if !channel.Enabled {
+			continue
+		}
+		health, err := s.repo.FindByChannel(ctx, channel.ID)
+		if err != nil || s.now().Sub(health.CheckedAt) >= channelCredentialCheckInterval {
+			checkCtx, cancel := context.WithTimeout(ctx, channelCredentialTimeout)
+			health, err = s.check(checkCtx, channel)
+			cancel()
+			if err != nil {
+				logger.Warn("Failed to check channel credentials",
+					zap.String("channel_id", channel.ID),
+					zap.Error(err),
+				)
+				continue
+			}
+		}
+		if health.Flagged() {
+			flagged++
+		}
+	}
+	return flagged, nil
+}
+
+// check validates the credentials of a channel with its provider, stores the result
+// and raises or resolves the channel's notification as its status changes
+func (s *ChannelCredentialHealthService) check(ctx context.Context, channel *entity.Channel) (*entity.ChannelCredentialHealth, error) {
+	prober := s.probers[channel.Type]
+	if prober == nil {
+		return nil, errors.Validation("credentials of " + string(channel.Type) + " channels cannot be checked")
+	}
+
+	now := s.now()
+	previous, err := s.repo.FindByChannel(ctx, channel.ID)
+	if err != nil {
+		previous = nil
+	}
+	health := &entity.ChannelCredentialHealth{
+		ChannelID: channel.ID,
+		TenantID:  channel.TenantID,
+		Status:    entity.ChannelCredentialsHealthy,
+		CheckedAt: now,
+		UpdatedAt: now,
+	}
+
+	account, err := prober.CheckCredentials(ctx, channel)
+	if err == nil {
+		health.Account = account
+		health.ExpiresAt, err = s.tokenExpiry(ctx, channel)
+	}
+
+	switch {
+	case err != nil:
+		health.Status = entity.ChannelCredentialsFailing
+		health.LastError = err.Error()
+		health.Failures = 1
+		health.FailingSince = &now
+		if previous != nil && previous.Status == entity.ChannelCredentialsFailing {
+			health.Failures = previous.Failures + 1
+			if previous.FailingSince != nil {
+				health.FailingSince = previous.FailingSince
+			}
+		}
+	case health.ExpiresAt != nil && health.ExpiresAt.Sub(now) < channelCredentialExpiryWarning:
+		health.Status = entity.ChannelCredentialsExpiring
+	}
+
+	if err := s.repo.Upsert(ctx, health); err != nil {
+		return nil, err
+	}
+	s.notify(ctx, channel, previous, health)
+	return health, nil
+}
+
+// tokenExpiry inspects the access token of a Meta channel with debug_token, returning
+// when it expires. Tokens Meta no longer accepts are an error; failing to ask Meta is
+// not, as the credentials were just used successfully.
+func (s *ChannelCredentialHealthService) tokenExpiry(ctx context.Context, channel *entity.Channel) (*time.Time, error) {
+	key := channelTokenKey(channel)
+	if key == "" || s.tokenProvider == nil {
+		return nil, nil
+	}
+	info, err := s.tokenProvider.Inspect(ctx, channel.Credentials[key], channelSetting(channel, "app_secret"))
+	if err != nil {
+		logger.Warn("Failed to inspect channel access token",
+			zap.String("channel_id", channel.ID),
+			zap.Error(err),
+		)
+		return nil, nil
+	}
+	if !info.Valid {
+		return nil, fmt.Errorf("access token is invalid or expired")
+	}
+	return info.ExpiresAt, nil
+}
+
+// notify raises a notification when a channel becomes flagged or its status changes,
+// and resolves it once its credentials are healthy again
+func (s *ChannelCredentialHealthService) notify(ctx context.Context, channel *entity.Channel, previous, health *entity.ChannelCredentialHealth) {
+	key := entity.NotificationTypeChannelCredentials + ":" + channel.ID
+	if !health.Flagged() {
+		if previous != nil && previous.Flagged() {
+			logger.Info("Channel credentials are healthy again", zap.String("channel_id", channel.ID))
+			if s.notifications != nil {
+				if err := s.notifications.Resolve(ctx, channel.TenantID, key); err != nil {
+					logger.Error("Failed to resolve channel credentials notification", zap.String("channel_id", channel.ID), zap.Error(err))
+				}
+			}
+		}
+		return
+	}
+	if previous != nil && previous.Status == health.Status {
+		return
+	}
+
+	notification := &entity.Notification{
+		TenantID:     channel.TenantID,
+		Type:         entity.NotificationTypeChannelCredentials,
+		ResourceType: "channel",
+		ResourceID:   channel.ID,
+		Key:          key,
+	}
+	if health.Status == entity.ChannelCredentialsFailing {
+		notification.Severity = entity.NotificationSeverityCritical
+		notification.Title = "Channel credentials are failing"
+		notification.Message = fmt.Sprintf("The provider did not accept the credentials of %s: %s. Update them to keep the channel working.",
+			channel.Name, health.LastError)
+	} else {
+		notification.Severity = entity.NotificationSeverityWarning
+		notification.Title = "Channel credentials expire soon"
+		notification.Message = fmt.Sprintf("The access token of %s expires on %s. Refresh or reconnect the channel before then.",
+			channel.Name, health.ExpiresAt.UTC().Format("2006-01-02"))
+	}
+
+	logger.Warn("Channel credentials flagged",
+		zap.String("channel_id", channel.ID),
+		zap.String("status", string(health.Status)),
+		zap.String("error", health.LastError),
+	)
+	if s.notifications == nil {
+		return
+	}
+	if err := s.notifications.Raise(ctx, notification); err != nil {
+		logger.Error("Failed to raise channel credentials notification", zap.String("channel_id", channel.ID), zap.Error(err))
+	}
+}
@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockChannelCredentialHealthRepo struct {
+	mu     sync.Mutex
+	checks map[string]*entity.ChannelCredentialHealth
+}
+
+func (m *mockChannelCredentialHealthRepo) Upsert(ctx context.Context, health *entity.ChannelCredentialHealth) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := *health
+	m.checks[health.ChannelID] = &stored
+	return nil
+}
+
+func (m *mockChannelCredentialHealthRepo) FindByChannel(ctx context.Context, channelID string) (*entity.ChannelCredentialHealth, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	health, ok := m.checks[channelID]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, "channel credentials not checked yet")
+	}
+	found := *health
+	return &found, nil
+}
+
+func (m *mockChannelCredentialHealthRepo) FindByTenant(ctx context.Context, tenantID string) ([]*entity.ChannelCredentialHealth, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var checks []*entity.ChannelCredentialHealth
+	for _, health := range m.checks {
+		if health.TenantID == tenantID {
+			found := *health
+			checks = append(checks, &found)
+		}
+	}
+	return checks, nil
+}
+
+type mockNotificationRepo struct {
+	notifications []*entity.Notification
+}
+
+func (m *mockNotificationRepo) Raise(ctx context.Context, notification *entity.Notification) error {
+	for _, open := range m.notifications {
+		if notification.Key != "" && open.Key == notification.Key && open.TenantID == notification.TenantID && open.ResolvedAt == nil {
+			open.Severity, open.Title, open.Message = notification.Severity, notification.Title, notification.Message
+			open.ReadAt = nil
+			open.UpdatedAt = notification.UpdatedAt
+			return nil
+		}
+	}
+	stored := *notification
+	m.notifications = append(m.notifications, &stored)
+	return nil
+}
+
+func (m *mockNotificationRepo) Resolve(ctx context.Context, tenantID, key string, at time.Time) error {
+	for _, open := range m.notifications {
+		if open.TenantID == tenantID && open.Key == key && open.ResolvedAt == nil {
+			open.ResolvedAt = &at
+		}
+	}
+	return nil
+}
+
+func (m *mockNotificationRepo) FindByTenant(ctx context.Context, tenantID string, filter *entity.NotificationFilter, params *repository.ListParams) ([]*entity.Notification, int64, error) {
+	var result []*entity.Notification
+	for _, notification := range m.notifications {
+		if notification.TenantID != tenantID || (notification.ResolvedAt != nil && !filter.IncludeResolved) || (notification.ReadAt != nil && filter.UnreadOnly) {
+			continue
+		}
+		result = append(result, notification)
+	}
+	return result, int64(len(result)), nil
+}
+
+func (m *mockNotificationRepo) CountUnread(ctx context.Context, tenantID string) (int64, error) {
+	unread, _, _ := m.FindByTenant(ctx, tenantID, &entity.NotificationFilter{UnreadOnly: true}, nil)
+	return int64(len(unread)), nil
+}
+
+func (m *mockNotificationRepo) MarkRead(ctx context.Context, tenantID, id string, at time.Time) error {
+	for _, notification := range m.notifications {
+		if notification.TenantID == tenantID && notification.ID == id {
+			notification.ReadAt = &at
+			return nil
+		}
+	}
+	return errors.New(errors.ErrCodeNotFound, "notification not found")
+}
+
+func (m *mockNotificationRepo) MarkAllRead(ctx context.Context, tenantID string, at time.Time) (int64, error) {
+	var marked int64
+	for _, notification := range m.notifications {
+		if notification.TenantID == tenantID && notification.ReadAt == nil {
+			notification.ReadAt = &at
+			marked++
+		}
+	}
+	return marked, nil
+}
+
+func newCredentialHealthTestService(expiresIn time.Duration) (*ChannelCredentialHealthService, *mockChannelCredentialHealthRepo, *mockNotificationRepo, *fakeChannelProber, *fakeChannelTokenProvider, *time.Time) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(expiresIn)
+
+	channels := testutil.NewMockChannelRepository()
+	channels.Channels["channel-1"] = &entity.Channel{
+		ID:          "channel-1",
+		TenantID:    "tenant-1",
+		Name:        "Support page",
+		Type:        entity.ChannelTypeFacebook,
+		Enabled:     true,
+		Credentials: map[string]string{"page_access_token": "token-1"},
+	}
+	checks := &mockChannelCredentialHealthRepo{checks: map[string]*entity.ChannelCredentialHealth{}}
+	notifications := &mockNotificationRepo{}
+	prober := &fakeChannelProber{}
+	provider := &fakeChannelTokenProvider{valid: true, expiresAt: &expiresAt}
+
+	notificationService := NewNotificationService(notifications)
+	notificationService.now = func() time.Time { return now }
+	svc := NewChannelCredentialHealthService(checks, channels)
+	svc.probers = map[entity.ChannelType]ChannelProber{entity.ChannelTypeFacebook: prober}
+	svc.SetTokenProvider(provider)
+	svc.SetNotificationService(notificationService)
+	svc.now = func() time.Time { return now }
+	return svc, checks, notifications, prober, provider, &now
+}
+
+func TestChannelCredentialHealthService_FlagsExpiringTokens(t *testing.T) {
+	svc, checks, notifications, _, provider, now := newCredentialHealthTestService(5 * 24 * time.Hour)
+	ctx := context.Background()
+
+	flagged, err := svc.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, flagged)
+
+	health := checks.checks["channel-1"]
+	assert.Equal(t, entity.ChannelCredentialsExpiring, health.Status)
+	assert.Equal(t, "account Acme", health.Account)
+	require.Len(t, notifications.notifications, 1)
+	notification := notifications.notifications[0]
+	assert.Equal(t, entity.NotificationSeverityWarning, notification.Severity)
+	assert.Equal(t, "channel-1", notification.ResourceID)
+	assert.Contains(t, notification.Message, "2026-03-06")
+
+	// Checked less than a day ago, so left alone
+	provider.valid = false
+	flagged, err = svc.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, flagged)
+	assert.Equal(t, entity.ChannelCredentialsExpiring, checks.checks["channel-1"].Status)
+
+	// The token was refreshed: the notification is resolved
+	*now = now.Add(channelCredentialCheckInterval)
+	provider.valid = true
+	later := now.Add(60 * 24 * time.Hour)
+	provider.expiresAt = &later
+	flagged, err = svc.Run(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, flagged)
+	assert.Equal(t, entity.ChannelCredentialsHealthy, checks.checks["channel-1"].Status)
+	require.Len(t, notifications.notifications, 1)
+	assert.NotNil(t, notifications.notifications[0].ResolvedAt)
+}
+
+func TestChannelCredentialHealthService_FlagsFailingCredentials(t *testing.T) {
+	svc, checks, notifications, prober, provider, now := newCredentialHealthTestService(50 * 24 * time.Hour)
+	ctx := context.Background()
+	failedAt := *now
+
+	prober.credentialsErr = fmt.Errorf("authentication failed: 535 invalid credentials")
+	health, err := svc.Check(ctx, "tenant-1", "channel-1")
+	require.NoError(t, err)
+	assert.Equal(t, entity.ChannelCredentialsFailing, health.Status)
+	assert.Contains(t, health.LastError, "535")
+	assert.Equal(t, 1, health.Failures)
+
+	*now = now.Add(channelCredentialCheckInterval)
+	health, err = svc.Check(ctx, "tenant-1", "channel-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, health.Failures)
+	assert.Equal(t, failedAt, *health.FailingSince)
+
+	// Failing again does not raise another notification
+	require.Len(t, notifications.notifications, 1)
+	assert.Equal(t, entity.NotificationSeverityCritical, notifications.notifications[0].Severity)
+	count, err := svc.notifications.UnreadCount(ctx, "tenant-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	// A token Meta no longer accepts fails even when the account could be read
+	prober.credentialsErr = nil
+	provider.valid = false
+	health, err = svc.Check(ctx, "tenant-1", "channel-1")
+	require.NoError(t, err)
+	assert.Equal(t, entity.ChannelCredentialsFailing, health.Status)
+	assert.Contains(t, health.LastError, "invalid or expired")
+
+	provider.valid = true
+	_, err = svc.Check(ctx, "tenant-1", "channel-1")
+	require.NoError(t, err)
+	assert.False(t, checks.checks["channel-1"].Flagged())
+	count, err = svc.notifications.UnreadCount(ctx, "tenant-1")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestChannelCredentialHealthService_AnnotatesChannels(t *testing.T) {
+	svc, _, _, prober, _, _ := newCredentialHealthTestService(50 * 24 * time.Hour)
+	ctx := context.Background()
+	prober.credentialsErr = fmt.Errorf("session has been invalidated")
+
+	_, err := svc.Check(ctx, "tenant-2", "channel-1")
+	assert.True(t, errors.IsNotFound(err))
+
+	_, err = svc.Check(ctx, "tenant-1", "channel-1")
+	require.NoError(t, err)
+
+	channels := []*entity.Channel{{ID: "channel-1"}, {ID: "channel-2"}}
+	require.NoError(t, svc.Annotate(ctx, "tenant-1", channels))
+	require.NotNil(t, channels[0].CredentialHealth)
+	assert.Equal(t, entity.ChannelCredentialsFailing, channels[0].CredentialHealth.Status)
+	assert.Nil(t, channels[1].CredentialHealth)
+
+	flagged, err := svc.List(ctx, "tenant-1", true)
+	require.NoError(t, err)
+	assert.Len(t, flagged, 1)
+	flagged, err = svc.List(ctx, "tenant-2", true)
+	require.NoError(t, err)
+	assert.Empty(t, flagged)
+}
@@ -0,0 +1,304 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// DefaultChannelLeaseTTL is how long a channel stays owned by an instance that stops renewing it
+const DefaultChannelLeaseTTL = 30 * time.Second
+
+// ChannelFailoverStatus is the current owner of a channel and its recent ownership changes
+type ChannelFailoverStatus struct {
+	Lease  *entity.ChannelLease           `json:"lease,omitempty"`
+	Active bool                           `json:"active"` // The lease has not expired
+	Events []*entity.ChannelFailoverEvent `json:"events"`
+}
+
+// WhatsAppSessionManager starts and stops the WhatsApp sessions held by this instance
+type WhatsAppSessionManager interface {
+	IsWhatsAppSessionLive(channelID string) bool
+	ResumeWhatsAppSession(ctx context.Context, channel *entity.Channel) error
+	StopWhatsAppSession(ctx context.Context, channelID string) error
+}
+
+// ChannelFailoverService makes sure each WhatsApp unofficial channel is connected on
+// exactly one server instance. The owner renews a lease in the database; instances
+// without the channel stand by and take it over, resuming the session from the
+// shared session store, once the owner stops renewing.
+type ChannelFailoverService struct {
+	leaseRepo   repository.ChannelLeaseRepository
+	channelRepo repository.ChannelRepository
+	sessions    WhatsAppSessionManager
+	producer    nats.Publisher
+	ownerID     string
+	ttl         time.Duration
+
+	mu    sync.Mutex
+	owned map[string]*entity.Channel
+}
+
+// NewChannelFailoverService creates a new channel failover service for the instance ownerID
+func NewChannelFailoverService(
+	leaseRepo repository.ChannelLeaseRepository,
+	channelRepo repository.ChannelRepository,
+	sessions WhatsAppSessionManager,
+	producer nats.Publisher,
+	ownerID string,
+	ttl time.Duration,
+) *ChannelFailoverService {
+	if ttl <= 0 {
+		ttl = DefaultChannelLeaseTTL
+	}
+	return &ChannelFailoverService{
+		leaseRepo:   leaseRepo,
+		channelRepo: channelRepo,
+		sessions:    sessions,
+		producer:    producer,
+		ownerID:     ownerID,
+		ttl:         ttl,
+		owned:       make(map[string]*entity.Channel),
+	}
+}
+
+// OwnerID returns the identifier this instance holds leases under
+func (s *ChannelFailoverService) OwnerID() string {
+	return s.ownerID
+}
+
+// RenewInterval is how often RunLeases must run to keep leases from expiring
+func (s *ChannelFailoverService) RenewInterval() time.Duration {
+	return s.ttl / 3
+}
+
+// RunLeases renews the leases of the channels this instance holds, gives up channels
+// claimed by another instance and takes over channels whose owner stopped renewing.
+// It returns the number of channels taken over.
+func (s *ChannelFailoverService) RunLeases(ctx context.Context) (int, error) {
+	channels, err := s.channelRepo.FindByTypes(ctx, []entity.ChannelType{
+		entity.ChannelTypeWhatsApp,
+		entity.ChannelTypeWhatsAppUnofficial,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	takenOver := 0
+	for _, channel := range channels {
+		live := s.sessions.IsWhatsAppSessionLive(channel.ID)
+
+		// Only channels with a session somewhere are worth holding
+		if !channel.Enabled || (!live && channel.ConnectionStatus != entity.ConnectionStatusConnected) {
+			if s.isOwned(channel.ID) {
+				s.release(ctx, channel, "session ended")
+			}
+			continue
+		}
+
+		lease, previousOwnerID, err := s.leaseRepo.Acquire(ctx, channel.TenantID, channel.ID, s.ownerID, s.ttl)
+		if err != nil {
+			logger.Warn("Failed to acquire channel lease",
+				zap.String("channel_id", channel.ID),
+				zap.Error(err))
+			continue
+		}
+
+		if lease == nil {
+			// Another instance holds the channel; two connections on one session conflict
+			if live || s.isOwned(channel.ID) {
+				s.lose(ctx, channel)
+			}
+			continue
+		}
+
+		// Mark the channel held before resuming, as connecting it calls Claim
+		newlyOwned := s.own(channel)
+		if !live {
+			if err := s.sessions.ResumeWhatsAppSession(ctx, channel); err != nil {
+				logger.Warn("Failed to resume WhatsApp session",
+					zap.String("channel_id", channel.ID),
+					zap.Error(err))
+				s.unown(channel.ID)
+				s.leaseRepo.Release(ctx, channel.ID, s.ownerID)
+				continue
+			}
+		}
+
+		if newlyOwned && s.recordAcquired(ctx, channel, previousOwnerID) {
+			takenOver++
+		}
+	}
+
+	return takenOver, nil
+}
+
+// Claim records that this instance connected the channel itself, e.g. after a QR code
+// login, so standby instances do not resume the same session
+func (s *ChannelFailoverService) Claim(ctx context.Context, channel *entity.Channel) {
+	lease, previousOwnerID, err := s.leaseRepo.Acquire(ctx, channel.TenantID, channel.ID, s.ownerID, s.ttl)
+	if err != nil {
+		logger.Warn("Failed to claim channel lease",
+			zap.String("channel_id", channel.ID),
+			zap.Error(err))
+		return
+	}
+	if lease == nil {
+		logger.Warn("Channel connected while another instance holds its lease",
+			zap.String("channel_id", channel.ID))
+		return
+	}
+	if s.own(channel) {
+		s.recordAcquired(ctx, channel, previousOwnerID)
+	}
+}
+
+// ReleaseAll stops the sessions this instance holds and releases their leases so a
+// standby instance takes them over without waiting for the leases to expire
+func (s *ChannelFailoverService) ReleaseAll(ctx context.Context) {
+	s.mu.Lock()
+	channels := make([]*entity.Channel, 0, len(s.owned))
+	for _, channel := range s.owned {
+		channels = append(channels, channel)
+	}
+	s.mu.Unlock()
+
+	for _, channel := range channels {
+		s.release(ctx, channel, "instance shutting down")
+	}
+}
+
+// Status returns who holds a channel and its recent ownership changes
+func (s *ChannelFailoverService) Status(ctx context.Context, tenantID, channelID string) (*ChannelFailoverStatus, error) {
+	channel, err := s.channelRepo.FindByID(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if channel.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeChannelNotFound, "channel not found")
+	}
+
+	status := &ChannelFailoverStatus{}
+	lease, err := s.leaseRepo.FindByChannel(ctx, channelID)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	if lease != nil {
+		status.Lease = lease
+		status.Active = lease.ExpiresAt.After(time.Now())
+	}
+
+	status.Events, err = s.leaseRepo.ListEvents(ctx, channelID, 50)
+	if err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// recordAcquired audits a newly held channel and reports whether it was a takeover
+func (s *ChannelFailoverService) recordAcquired(ctx context.Context, channel *entity.Channel, previousOwnerID string) bool {
+	if previousOwnerID == "" || previousOwnerID == s.ownerID {
+		s.record(ctx, channel, entity.ChannelFailoverAcquired, "", "")
+		return false
+	}
+
+	logger.Info("Took over WhatsApp channel",
+		zap.String("channel_id", channel.ID),
+		zap.String("previous_owner", previousOwnerID),
+		zap.String("owner", s.ownerID))
+	s.record(ctx, channel, entity.ChannelFailoverTakeover, previousOwnerID, "previous owner stopped renewing its lease")
+
+	if s.producer != nil {
+		s.producer.PublishEvent(ctx, &nats.Event{
+			Type:     nats.EventChannelTakeover,
+			TenantID: channel.TenantID,
+			Payload: map[string]interface{}{
+				"channel_id":        channel.ID,
+				"owner_id":          s.ownerID,
+				"previous_owner_id": previousOwnerID,
+			},
+			Timestamp: time.Now(),
+		})
+	}
+	return true
+}
+
+// lose stops the local session of a channel another instance holds
+func (s *ChannelFailoverService) lose(ctx context.Context, channel *entity.Channel) {
+	if err := s.sessions.StopWhatsAppSession(ctx, channel.ID); err != nil {
+		logger.Warn("Failed to stop WhatsApp session",
+			zap.String("channel_id", channel.ID),
+			zap.Error(err))
+	}
+	if s.unown(channel.ID) {
+		s.record(ctx, channel, entity.ChannelFailoverLost, "", "lease held by another instance")
+	}
+}
+
+// release stops the local session and gives the lease up
+func (s *ChannelFailoverService) release(ctx context.Context, channel *entity.Channel, reason string) {
+	if err := s.sessions.StopWhatsAppSession(ctx, channel.ID); err != nil {
+		logger.Warn("Failed to stop WhatsApp session",
+			zap.String("channel_id", channel.ID),
+			zap.Error(err))
+	}
+	if err := s.leaseRepo.Release(ctx, channel.ID, s.ownerID); err != nil {
+		logger.Warn("Failed to release channel lease",
+			zap.String("channel_id", channel.ID),
+			zap.Error(err))
+	}
+	if s.unown(channel.ID) {
+		s.record(ctx, channel, entity.ChannelFailoverReleased, "", reason)
+	}
+}
+
+func (s *ChannelFailoverService) record(ctx context.Context, channel *entity.Channel, eventType entity.ChannelFailoverEventType, previousOwnerID, reason string) {
+	event := &entity.ChannelFailoverEvent{
+		ID:              uuid.New().String(),
+		TenantID:        channel.TenantID,
+		ChannelID:       channel.ID,
+		Type:            eventType,
+		OwnerID:         s.ownerID,
+		PreviousOwnerID: previousOwnerID,
+		Reason:          reason,
+		CreatedAt:       time.Now(),
+	}
+	if err := s.leaseRepo.CreateEvent(ctx, event); err != nil {
+		logger.Warn("Failed to record channel failover event",
+			zap.String("channel_id", channel.ID),
+			zap.Error(err))
+	}
+}
+
+// own marks the channel as held and reports whether it was not held before
+func (s *ChannelFailoverService) own(channel *entity.Channel) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, held := s.owned[channel.ID]
+	s.owned[channel.ID] = channel
+	return !held
+}
+
+// unown marks the channel as no longer held and reports whether it was held
+func (s *ChannelFailoverService) unown(channelID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, held := s.owned[channelID]
+	delete(s.owned, channelID)
+	return held
+}
+
+func (s *ChannelFailoverService) isOwned(channelID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, held := s.owned[channelID]
+	return held
+}
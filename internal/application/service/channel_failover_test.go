@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockChannelLeaseRepo keeps leases in memory with the database's claim rules
+type mockChannelLeaseRepo struct {
+	leases map[string]*entity.ChannelLease
+	events []*entity.ChannelFailoverEvent
+}
+
+func newMockChannelLeaseRepo() *mockChannelLeaseRepo {
+	return &mockChannelLeaseRepo{leases: make(map[string]*entity.ChannelLease)}
+}
+
+func (m *mockChannelLeaseRepo) Acquire(ctx context.Context, tenantID, channelID, ownerID string, ttl time.Duration) (*entity.ChannelLease, string, error) {
+	now := time.Now()
+	previousOwnerID := ""
+	if lease, ok := m.leases[channelID]; ok {
+		if lease.OwnerID != ownerID && lease.ExpiresAt.After(now) {
+			return nil, "", nil
+		}
+		previousOwnerID = lease.OwnerID
+	}
+	lease := &entity.ChannelLease{ChannelID: channelID, TenantID: tenantID, OwnerID: ownerID, AcquiredAt: now, RenewedAt: now, ExpiresAt: now.Add(ttl)}
+	m.leases[channelID] = lease
+	return lease, previousOwnerID, nil
+}
+
+func (m *mockChannelLeaseRepo) Release(ctx context.Context, channelID, ownerID string) error {
+	if lease, ok := m.leases[channelID]; ok && lease.OwnerID == ownerID {
+		delete(m.leases, channelID)
+	}
+	return nil
+}
+
+func (m *mockChannelLeaseRepo) FindByChannel(ctx context.Context, channelID string) (*entity.ChannelLease, error) {
+	lease, ok := m.leases[channelID]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, "channel lease not found")
+	}
+	return lease, nil
+}
+
+func (m *mockChannelLeaseRepo) CreateEvent(ctx context.Context, event *entity.ChannelFailoverEvent) error {
+	m.events = append([]*entity.ChannelFailoverEvent{event}, m.events...)
+	return nil
+}
+
+func (m *mockChannelLeaseRepo) ListEvents(ctx context.Context, channelID string, limit int) ([]*entity.ChannelFailoverEvent, error) {
+	var result []*entity.ChannelFailoverEvent
+	for _, event := range m.events {
+		if event.ChannelID == channelID {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+// fakeWhatsAppSessions tracks which channels an instance has connected
+type fakeWhatsAppSessions struct {
+	live      map[string]bool
+	resumable bool
+}
+
+func newFakeWhatsAppSessions() *fakeWhatsAppSessions {
+	return &fakeWhatsAppSessions{live: make(map[string]bool), resumable: true}
+}
+
+func (f *fakeWhatsAppSessions) IsWhatsAppSessionLive(channelID string) bool {
+	return f.live[channelID]
+}
+
+func (f *fakeWhatsAppSessions) ResumeWhatsAppSession(ctx context.Context, channel *entity.Channel) error {
+	if !f.resumable {
+		return errors.New(errors.ErrCodeChannelDisconnected, "no valid WhatsApp session")
+	}
+	f.live[channel.ID] = true
+	return nil
+}
+
+func (f *fakeWhatsAppSessions) StopWhatsAppSession(ctx context.Context, channelID string) error {
+	delete(f.live, channelID)
+	return nil
+}
+
+func newChannelFailoverTestEnv() (*testutil.MockChannelRepository, *mockChannelLeaseRepo, *testutil.MockProducer) {
+	channels := testutil.NewMockChannelRepository()
+	channels.Channels["wa-1"] = &entity.Channel{ID: "wa-1", TenantID: "tenant-1", Type: entity.ChannelTypeWhatsApp, Enabled: true, ConnectionStatus: entity.ConnectionStatusConnected}
+	channels.Channels["wa-2"] = &entity.Channel{ID: "wa-2", TenantID: "tenant-1", Type: entity.ChannelTypeWhatsApp, Enabled: true, ConnectionStatus: entity.ConnectionStatusDisconnected}
+	channels.Channels["tg-1"] = &entity.Channel{ID: "tg-1", TenantID: "tenant-1", Type: entity.ChannelTypeTelegram, Enabled: true, ConnectionStatus: entity.ConnectionStatusConnected}
+	return channels, newMockChannelLeaseRepo(), testutil.NewMockProducer()
+}
+
+func TestChannelFailoverService_StandbyTakesOver(t *testing.T) {
+	channels, leases, producer := newChannelFailoverTestEnv()
+	ctx := context.Background()
+
+	primarySessions := newFakeWhatsAppSessions()
+	primary := NewChannelFailoverService(leases, channels, primarySessions, producer, "primary", time.Minute)
+	standbySessions := newFakeWhatsAppSessions()
+	standby := NewChannelFailoverService(leases, channels, standbySessions, producer, "standby", time.Minute)
+
+	takenOver, err := primary.RunLeases(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, takenOver)
+	assert.True(t, primarySessions.live["wa-1"])
+	assert.False(t, primarySessions.live["wa-2"], "disconnected channels are not resumed")
+	assert.Equal(t, entity.ChannelFailoverAcquired, leases.events[0].Type)
+
+	// The standby waits while the primary's lease is valid
+	takenOver, err = standby.RunLeases(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, takenOver)
+	assert.False(t, standbySessions.live["wa-1"])
+
+	// The primary dies and its lease lapses
+	leases.leases["wa-1"].ExpiresAt = time.Now().Add(-time.Second)
+	takenOver, err = standby.RunLeases(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, takenOver)
+	assert.True(t, standbySessions.live["wa-1"])
+	assert.Equal(t, "standby", leases.leases["wa-1"].OwnerID)
+	assert.Equal(t, entity.ChannelFailoverTakeover, leases.events[0].Type)
+	assert.Equal(t, "primary", leases.events[0].PreviousOwnerID)
+	assert.Len(t, producer.Events, 1)
+
+	// A primary that comes back gives up its stale session
+	_, err = primary.RunLeases(ctx)
+	require.NoError(t, err)
+	assert.False(t, primarySessions.live["wa-1"])
+	assert.Equal(t, entity.ChannelFailoverLost, leases.events[0].Type)
+}
+
+func TestChannelFailoverService_ReleaseAll(t *testing.T) {
+	channels, leases, producer := newChannelFailoverTestEnv()
+	ctx := context.Background()
+
+	sessions := newFakeWhatsAppSessions()
+	svc := NewChannelFailoverService(leases, channels, sessions, producer, "primary", time.Minute)
+	_, err := svc.RunLeases(ctx)
+	require.NoError(t, err)
+
+	svc.ReleaseAll(ctx)
+	assert.False(t, sessions.live["wa-1"])
+	assert.Empty(t, leases.leases)
+	assert.Equal(t, entity.ChannelFailoverReleased, leases.events[0].Type)
+
+	// A standby claims the released channel right away
+	standbySessions := newFakeWhatsAppSessions()
+	standby := NewChannelFailoverService(leases, channels, standbySessions, producer, "standby", time.Minute)
+	_, err = standby.RunLeases(ctx)
+	require.NoError(t, err)
+	assert.True(t, standbySessions.live["wa-1"])
+}
+
+func TestChannelFailoverService_ResumeFailureReleasesLease(t *testing.T) {
+	channels, leases, producer := newChannelFailoverTestEnv()
+	sessions := newFakeWhatsAppSessions()
+	sessions.resumable = false
+	svc := NewChannelFailoverService(leases, channels, sessions, producer, "primary", time.Minute)
+
+	_, err := svc.RunLeases(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, leases.leases)
+	assert.Empty(t, leases.events)
+}
+
+func TestChannelFailoverService_Status(t *testing.T) {
+	channels, leases, producer := newChannelFailoverTestEnv()
+	ctx := context.Background()
+	svc := NewChannelFailoverService(leases, channels, newFakeWhatsAppSessions(), producer, "primary", time.Minute)
+
+	status, err := svc.Status(ctx, "tenant-1", "wa-1")
+	require.NoError(t, err)
+	assert.Nil(t, status.Lease)
+	assert.False(t, status.Active)
+
+	_, err = svc.RunLeases(ctx)
+	require.NoError(t, err)
+	status, err = svc.Status(ctx, "tenant-1", "wa-1")
+	require.NoError(t, err)
+	assert.Equal(t, "primary", status.Lease.OwnerID)
+	assert.True(t, status.Active)
+	assert.Len(t, status.Events, 1)
+
+	_, err = svc.Status(ctx, "tenant-2", "wa-1")
+	assert.True(t, errors.IsNotFound(err))
+}
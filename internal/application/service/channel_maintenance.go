@@ -0,0 +1,353 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// channelMaintenanceMaxDuration bounds how long a channel can be left unattended
+	channelMaintenanceMaxDuration = 7 * 24 * time.Hour
+
+	// channelMaintenanceCacheTTL is how long an instance trusts its copy of a channel's
+	// windows; changes made on other instances are followed within this delay
+	channelMaintenanceCacheTTL = 30 * time.Second
+
+	defaultMaintenanceNotice = "We're carrying out scheduled maintenance and will get back to you as soon as it's over."
+)
+
+// Conversation metadata written during channel maintenance
+const (
+	ConversationMetaMaintenanceWindow = "maintenance_window"        // Window the conversation was queued by
+	conversationMaintenanceNoticeKey  = "maintenance_notice_window" // Window whose notice the contact received
+)
+
+// ChannelMaintenanceInput represents a maintenance window to declare on a channel
+type ChannelMaintenanceInput struct {
+	Title    string    `json:"title"`
+	Notice   string    `json:"notice,omitempty"` // Defaults to a generic notice
+	StartsAt time.Time `json:"starts_at"`
+	EndsAt   time.Time `json:"ends_at"`
+}
+
+// cachedMaintenanceWindows are the current and upcoming windows of a channel
+type cachedMaintenanceWindows struct {
+	windows  []*entity.ChannelMaintenanceWindow
+	loadedAt time.Time
+}
+
+// ChannelMaintenanceService manages the maintenance windows tenants declare on their
+// channels. While a window is active, contacts writing in get its notice once per
+// conversation and their conversations wait in the queue instead of being routed;
+// bots stand down as they do outside their working hours, agent timers stop counting
+// and newsletters are held until the window ends.
+type ChannelMaintenanceService struct {
+	repo             repository.ChannelMaintenanceRepository
+	windows          *repository.TenantScope[entity.ChannelMaintenanceWindow]
+	channels         *repository.TenantScope[entity.Channel]
+	conversationRepo repository.ConversationRepository
+	messageService   *MessageService
+	now              func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]*cachedMaintenanceWindows
+}
+
+// NewChannelMaintenanceService creates a new channel maintenance service
+func NewChannelMaintenanceService(
+	repo repository.ChannelMaintenanceRepository,
+	channelRepo repository.ChannelRepository,
+	conversationRepo repository.ConversationRepository,
+	messageService *MessageService,
+) *ChannelMaintenanceService {
+	return &ChannelMaintenanceService{
+		repo: repo,
+		windows: repository.NewTenantScope(repo.FindByID,
+			func(window *entity.ChannelMaintenanceWindow) string { return window.TenantID },
+			maintenanceWindowNotFound),
+		channels:         repository.TenantChannels(channelRepo, channelNotFound),
+		conversationRepo: conversationRepo,
+		messageService:   messageService,
+		now:              time.Now,
+		cache:            make(map[string]*cachedMaintenanceWindows),
+	}
+}
+
+// maintenanceWindowNotFound is the error of missing windows and of those of other tenants
+func maintenanceWindowNotFound() error {
+	return errors.NotFound("maintenance window")
+}
+
+// List lists the current and upcoming maintenance windows of a channel of the tenant
+func (s *ChannelMaintenanceService) List(ctx context.Context, tenantID, channelID string) ([]*entity.ChannelMaintenanceWindow, error) {
+	channel, err := s.channels.FindByID(ctx, tenantID, channelID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListByChannel(ctx, channel.ID, s.now())
+}
+
+// Get returns a maintenance window of a channel of the tenant
+func (s *ChannelMaintenanceService) Get(ctx context.Context, tenantID, channelID, id string) (*entity.ChannelMaintenanceWindow, error) {
+	window, err := s.windows.FindByID(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if window.ChannelID != channelID {
+		return nil, maintenanceWindowNotFound()
+	}
+	return window, nil
+}
+
+// Create declares a maintenance window on a channel of the tenant
+func (s *ChannelMaintenanceService) Create(ctx context.Context, tenantID, channelID, createdBy string, input *ChannelMaintenanceInput) (*entity.ChannelMaintenanceWindow, error) {
+	channel, err := s.channels.FindByID(ctx, tenantID, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	window := &entity.ChannelMaintenanceWindow{
+		ID:        uuid.New().String(),
+		TenantID:  channel.TenantID,
+		ChannelID: channel.ID,
+		CreatedBy: createdBy,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.apply(ctx, window, input, now); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, window); err != nil {
+		return nil, err
+	}
+	s.forget(channel.ID)
+	return window, nil
+}
+
+// Update reschedules or rewords a maintenance window that has not ended
+func (s *ChannelMaintenanceService) Update(ctx context.Context, tenantID, channelID, id string, input *ChannelMaintenanceInput) (*entity.ChannelMaintenanceWindow, error) {
+	window, err := s.Get(ctx, tenantID, channelID, id)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if window.IsOver(now) {
+		return nil, errors.Validation("the maintenance window has already ended")
+	}
+
+	// An ongoing window keeps its start, which conversations were queued by
+	if window.IsActive(now) {
+		input.StartsAt = window.StartsAt
+	}
+	if err := s.apply(ctx, window, input, now); err != nil {
+		return nil, err
+	}
+	window.UpdatedAt = now
+	if err := s.repo.Update(ctx, window); err != nil {
+		return nil, err
+	}
+	s.forget(window.ChannelID)
+	return window, nil
+}
+
+// End ends an ongoing maintenance window now
+func (s *ChannelMaintenanceService) End(ctx context.Context, tenantID, channelID, id string) (*entity.ChannelMaintenanceWindow, error) {
+	window, err := s.Get(ctx, tenantID, channelID, id)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	if !window.IsActive(now) {
+		return nil, errors.Validation("only an ongoing maintenance window can be ended")
+	}
+
+	window.EndsAt = now
+	window.UpdatedAt = now
+	if err := s.repo.Update(ctx, window); err != nil {
+		return nil, err
+	}
+	s.forget(window.ChannelID)
+	return window, nil
+}
+
+// Delete cancels a maintenance window that has not started
+func (s *ChannelMaintenanceService) Delete(ctx context.Context, tenantID, channelID, id string) error {
+	window, err := s.Get(ctx, tenantID, channelID, id)
+	if err != nil {
+		return err
+	}
+	if !s.now().Before(window.StartsAt) {
+		return errors.Validation("the maintenance window has started; end it instead")
+	}
+	if err := s.repo.Delete(ctx, window.ID); err != nil {
+		return err
+	}
+	s.forget(window.ChannelID)
+	return nil
+}
+
+// apply validates an input and sets it on a window. Windows of a channel may not
+// overlap, so the time they pause timers is counted once.
+func (s *ChannelMaintenanceService) apply(ctx context.Context, window *entity.ChannelMaintenanceWindow, input *ChannelMaintenanceInput, now time.Time) error {
+	if input == nil || input.StartsAt.IsZero() || input.EndsAt.IsZero() {
+		return errors.Validation("starts_at and ends_at are required")
+	}
+	if !input.EndsAt.After(input.StartsAt) {
+		return errors.Validation("ends_at must be after starts_at")
+	}
+	if !input.EndsAt.After(now) {
+		return errors.Validation("ends_at must be in the future")
+	}
+	if input.EndsAt.Sub(input.StartsAt) > channelMaintenanceMaxDuration {
+		return errors.Validation("a maintenance window can last at most 7 days")
+	}
+
+	existing, err := s.repo.ListOverlapping(ctx, window.ChannelID, input.StartsAt, input.EndsAt)
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if other.ID != window.ID && other.StartsAt.Before(input.EndsAt) {
+			return errors.Validation("the channel already has a maintenance window at that time")
+		}
+	}
+
+	window.Title = strings.TrimSpace(input.Title)
+	window.Notice = strings.TrimSpace(input.Notice)
+	if window.Notice == "" {
+		window.Notice = defaultMaintenanceNotice
+	}
+	window.StartsAt = input.StartsAt
+	window.EndsAt = input.EndsAt
+	return nil
+}
+
+// ActiveWindow returns the window a channel is under maintenance by, nil when the
+// channel is attended
+func (s *ChannelMaintenanceService) ActiveWindow(ctx context.Context, channelID string) *entity.ChannelMaintenanceWindow {
+	now := s.now()
+	for _, window := range s.upcoming(ctx, channelID, now) {
+		if window.IsActive(now) {
+			return window
+		}
+	}
+	return nil
+}
+
+// PausedDuration returns how much of the period from..to a channel spent under
+// maintenance, which timers running over that period must not count
+func (s *ChannelMaintenanceService) PausedDuration(ctx context.Context, channelID string, from, to time.Time) time.Duration {
+	windows, err := s.repo.ListOverlapping(ctx, channelID, from, to)
+	if err != nil {
+		logger.Warn("Failed to list channel maintenance windows",
+			zap.String("channel_id", channelID),
+			zap.Error(err),
+		)
+		return 0
+	}
+	var paused time.Duration
+	for _, window := range windows {
+		paused += window.Overlap(from, to)
+	}
+	return paused
+}
+
+// HandleInbound answers a contact writing to a channel under maintenance with the
+// window's notice, once per conversation, and queues their conversation unless an
+// agent already has it
+func (s *ChannelMaintenanceService) HandleInbound(ctx context.Context, message *entity.Message, conversation *entity.Conversation) error {
+	if message.SenderType != entity.SenderTypeContact {
+		return nil
+	}
+	window := s.ActiveWindow(ctx, conversation.ChannelID)
+	if window == nil {
+		return nil
+	}
+
+	notify := conversation.Metadata[conversationMaintenanceNoticeKey] != window.ID
+	queue := conversation.AssignedUserID == nil && conversation.Metadata[ConversationMetaMaintenanceWindow] != window.ID
+	if !notify && !queue {
+		return nil
+	}
+
+	if conversation.Metadata == nil {
+		conversation.Metadata = make(map[string]string)
+	}
+	metadata := map[string]string{conversationMaintenanceNoticeKey: window.ID}
+	if queue {
+		metadata[ConversationMetaMaintenanceWindow] = window.ID
+		if conversation.Status == entity.ConversationStatusOpen {
+			conversation.Status = entity.ConversationStatusPending
+			if err := s.conversationRepo.UpdateStatus(ctx, conversation.ID, conversation.Status); err != nil {
+				return err
+			}
+		}
+	}
+	for key, value := range metadata {
+		conversation.Metadata[key] = value
+	}
+	if err := s.conversationRepo.SetMetadata(ctx, conversation.ID, metadata); err != nil {
+		return err
+	}
+
+	if !notify {
+		return nil
+	}
+	_, err := s.messageService.Send(ctx, &SendMessageInput{
+		TenantID:       conversation.TenantID,
+		ConversationID: conversation.ID,
+		SenderType:     string(entity.SenderTypeSystem),
+		ContentType:    string(entity.ContentTypeText),
+		Content:        window.Notice,
+		Metadata:       map[string]string{ConversationMetaMaintenanceWindow: window.ID},
+	})
+	if err != nil {
+		logger.Warn("Failed to send maintenance notice",
+			zap.String("conversation_id", conversation.ID),
+			zap.String("window_id", window.ID),
+			zap.Error(err),
+		)
+	}
+	return err
+}
+
+// upcoming returns the current and upcoming windows of a channel, from the cache
+// while it is fresh
+func (s *ChannelMaintenanceService) upcoming(ctx context.Context, channelID string, now time.Time) []*entity.ChannelMaintenanceWindow {
+	s.mu.Lock()
+	cached, ok := s.cache[channelID]
+	s.mu.Unlock()
+	if ok && now.Sub(cached.loadedAt) < channelMaintenanceCacheTTL {
+		return cached.windows
+	}
+
+	windows, err := s.repo.ListByChannel(ctx, channelID, now)
+	if err != nil {
+		logger.Warn("Failed to list channel maintenance windows",
+			zap.String("channel_id", channelID),
+			zap.Error(err),
+		)
+		return nil
+	}
+	s.mu.Lock()
+	s.cache[channelID] = &cachedMaintenanceWindows{windows: windows, loadedAt: now}
+	s.mu.Unlock()
+	return windows
+}
+
+// forget drops the cached windows of a channel after they changed
+func (s *ChannelMaintenanceService) forget(channelID string) {
+	s.mu.Lock()
+	delete(s.cache, channelID)
+	s.mu.Unlock()
+}
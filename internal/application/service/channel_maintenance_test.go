@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockChannelMaintenanceRepo struct {
+	mu      sync.Mutex
+	windows map[string]*entity.ChannelMaintenanceWindow
+	lists   int
+}
+
+func newMockChannelMaintenanceRepo() *mockChannelMaintenanceRepo {
+	return &mockChannelMaintenanceRepo{windows: make(map[string]*entity.ChannelMaintenanceWindow)}
+}
+
+func (m *mockChannelMaintenanceRepo) Create(ctx context.Context, window *entity.ChannelMaintenanceWindow) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copied := *window
+	m.windows[window.ID] = &copied
+	return nil
+}
+
+func (m *mockChannelMaintenanceRepo) FindByID(ctx context.Context, id string) (*entity.ChannelMaintenanceWindow, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	window, ok := m.windows[id]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, "maintenance window not found")
+	}
+	copied := *window
+	return &copied, nil
+}
+
+func (m *mockChannelMaintenanceRepo) ListByChannel(ctx context.Context, channelID string, since time.Time) ([]*entity.ChannelMaintenanceWindow, error) {
+	m.mu.Lock()
+	m.lists++
+	m.mu.Unlock()
+	return m.filter(func(w *entity.ChannelMaintenanceWindow) bool {
+		return w.ChannelID == channelID && w.EndsAt.After(since)
+	}), nil
+}
+
+func (m *mockChannelMaintenanceRepo) ListOverlapping(ctx context.Context, channelID string, from, to time.Time) ([]*entity.ChannelMaintenanceWindow, error) {
+	return m.filter(func(w *entity.ChannelMaintenanceWindow) bool {
+		return w.ChannelID == channelID && w.EndsAt.After(from) && !w.StartsAt.After(to)
+	}), nil
+}
+
+func (m *mockChannelMaintenanceRepo) Update(ctx context.Context, window *entity.ChannelMaintenanceWindow) error {
+	return m.Create(ctx, window)
+}
+
+func (m *mockChannelMaintenanceRepo) Delete(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.windows, id)
+	return nil
+}
+
+func (m *mockChannelMaintenanceRepo) filter(keep func(*entity.ChannelMaintenanceWindow) bool) []*entity.ChannelMaintenanceWindow {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var windows []*entity.ChannelMaintenanceWindow
+	for _, window := range m.windows {
+		if keep(window) {
+			copied := *window
+			windows = append(windows, &copied)
+		}
+	}
+	return windows
+}
+
+type channelMaintenanceFixture struct {
+	service       *ChannelMaintenanceService
+	repo          *mockChannelMaintenanceRepo
+	conversations *testutil.MockConversationRepository
+	messages      *testutil.MockMessageRepository
+	now           time.Time
+}
+
+func newChannelMaintenanceFixture() *channelMaintenanceFixture {
+	repo := newMockChannelMaintenanceRepo()
+	channels := testutil.NewMockChannelRepository()
+	conversations := testutil.NewMockConversationRepository()
+	contacts := testutil.NewMockContactRepository()
+	messages := testutil.NewMockMessageRepository()
+
+	channels.Channels["ch-1"] = &entity.Channel{ID: "ch-1", TenantID: "tenant-1", Type: entity.ChannelTypeTelegram, Enabled: true}
+	contacts.Contacts["contact-1"] = &entity.Contact{ID: "contact-1", TenantID: "tenant-1", Name: "Alice"}
+
+	messageService := NewMessageService(messages, conversations, channels, contacts, testutil.NewMockProducer())
+	service := NewChannelMaintenanceService(repo, channels, conversations, messageService)
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	return &channelMaintenanceFixture{
+		service:       service,
+		repo:          repo,
+		conversations: conversations,
+		messages:      messages,
+		now:           now,
+	}
+}
+
+func (f *channelMaintenanceFixture) schedule(t *testing.T, starts, ends time.Duration) *entity.ChannelMaintenanceWindow {
+	t.Helper()
+	window, err := f.service.Create(context.Background(), "tenant-1", "ch-1", "user-1", &ChannelMaintenanceInput{
+		Title:    "Database upgrade",
+		StartsAt: f.now.Add(starts),
+		EndsAt:   f.now.Add(ends),
+	})
+	require.NoError(t, err)
+	return window
+}
+
+func TestChannelMaintenanceService_Create(t *testing.T) {
+	f := newChannelMaintenanceFixture()
+	ctx := context.Background()
+
+	window := f.schedule(t, time.Hour, 3*time.Hour)
+	assert.Equal(t, defaultMaintenanceNotice, window.Notice)
+	assert.Equal(t, "tenant-1", window.TenantID)
+
+	tests := []struct {
+		name   string
+		tenant string
+		input  *ChannelMaintenanceInput
+	}{
+		{"other tenant's channel", "tenant-2", &ChannelMaintenanceInput{StartsAt: f.now, EndsAt: f.now.Add(time.Hour)}},
+		{"ends before it starts", "tenant-1", &ChannelMaintenanceInput{StartsAt: f.now.Add(5 * time.Hour), EndsAt: f.now.Add(4 * time.Hour)}},
+		{"already over", "tenant-1", &ChannelMaintenanceInput{StartsAt: f.now.Add(-2 * time.Hour), EndsAt: f.now.Add(-time.Hour)}},
+		{"too long", "tenant-1", &ChannelMaintenanceInput{StartsAt: f.now.Add(4 * time.Hour), EndsAt: f.now.Add(8 * 24 * time.Hour)}},
+		{"overlaps another window", "tenant-1", &ChannelMaintenanceInput{StartsAt: f.now.Add(2 * time.Hour), EndsAt: f.now.Add(5 * time.Hour)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.service.Create(ctx, tt.tenant, "ch-1", "user-1", tt.input)
+			assert.Error(t, err)
+		})
+	}
+
+	// Back-to-back windows are fine
+	_, err := f.service.Create(ctx, "tenant-1", "ch-1", "user-1", &ChannelMaintenanceInput{StartsAt: f.now.Add(3 * time.Hour), EndsAt: f.now.Add(4 * time.Hour)})
+	assert.NoError(t, err)
+}
+
+func TestChannelMaintenanceService_ActiveWindowCached(t *testing.T) {
+	f := newChannelMaintenanceFixture()
+	ctx := context.Background()
+
+	assert.Nil(t, f.service.ActiveWindow(ctx, "ch-1"))
+	window := f.schedule(t, -time.Minute, time.Hour)
+
+	active := f.service.ActiveWindow(ctx, "ch-1")
+	require.NotNil(t, active)
+	assert.Equal(t, window.ID, active.ID)
+	f.service.ActiveWindow(ctx, "ch-1")
+	assert.Equal(t, 2, f.repo.lists, "windows are cached until they change")
+
+	_, err := f.service.End(ctx, "tenant-1", "ch-1", window.ID)
+	require.NoError(t, err)
+	assert.Nil(t, f.service.ActiveWindow(ctx, "ch-1"))
+
+	err = f.service.Delete(ctx, "tenant-1", "ch-1", window.ID)
+	assert.Error(t, err, "a window that started can only be ended")
+}
+
+func TestChannelMaintenanceService_HandleInbound(t *testing.T) {
+	f := newChannelMaintenanceFixture()
+	ctx := context.Background()
+	conversation := &entity.Conversation{
+		ID:        "conv-1",
+		TenantID:  "tenant-1",
+		ChannelID: "ch-1",
+		ContactID: "contact-1",
+		Status:    entity.ConversationStatusOpen,
+		Metadata:  map[string]string{},
+	}
+	f.conversations.Conversations[conversation.ID] = conversation
+	message := &entity.Message{ConversationID: "conv-1", SenderType: entity.SenderTypeContact, Content: "Hello?"}
+
+	// Attended channels are left alone
+	require.NoError(t, f.service.HandleInbound(ctx, message, conversation))
+	assert.Empty(t, f.messages.Messages)
+
+	window, err := f.service.Create(ctx, "tenant-1", "ch-1", "user-1", &ChannelMaintenanceInput{
+		Notice:   "We're upgrading our systems until 2pm.",
+		StartsAt: f.now.Add(-time.Minute),
+		EndsAt:   f.now.Add(2 * time.Hour),
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, f.service.HandleInbound(ctx, message, conversation))
+	require.NoError(t, f.service.HandleInbound(ctx, message, conversation))
+
+	require.Len(t, f.messages.Messages, 1, "the notice is sent once per conversation")
+	for _, notice := range f.messages.Messages {
+		assert.Equal(t, "We're upgrading our systems until 2pm.", notice.Content)
+		assert.Equal(t, entity.SenderTypeSystem, notice.SenderType)
+	}
+	assert.Equal(t, entity.ConversationStatusPending, conversation.Status)
+	assert.Equal(t, window.ID, conversation.Metadata[ConversationMetaMaintenanceWindow])
+}
+
+func TestChannelMaintenanceService_PausedDuration(t *testing.T) {
+	f := newChannelMaintenanceFixture()
+	f.repo.Create(context.Background(), &entity.ChannelMaintenanceWindow{
+		ID: "past", ChannelID: "ch-1", StartsAt: f.now.Add(-5 * time.Hour), EndsAt: f.now.Add(-3 * time.Hour),
+	})
+	f.schedule(t, -30*time.Minute, time.Hour)
+	f.schedule(t, 2*time.Hour, 3*time.Hour)
+
+	// The past window only counts from the start of the period
+	paused := f.service.PausedDuration(context.Background(), "ch-1", f.now.Add(-4*time.Hour), f.now)
+	assert.Equal(t, 90*time.Minute, paused)
+}
@@ -0,0 +1,535 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/adapters/email"
+	"github.com/msgfy/linktor/internal/adapters/meta"
+	"github.com/msgfy/linktor/internal/adapters/whatsapp_official"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/egress"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	channelProbeTimeout        = 60 * time.Second
+	channelProbeWebhookTimeout = 10 * time.Second
+
+	// ChannelSettingSandboxRecipient is the channel setting, in the config or the
+	// credentials, holding the recipient probes send a test message to
+	ChannelSettingSandboxRecipient = "sandbox_recipient"
+
+	channelProbeTestText = "Linktor channel test: this channel can send messages."
+)
+
+// metaVerificationPaths are the webhook routes answering Meta's verification
+// handshake, which proves a webhook URL reaches the channel
+var metaVerificationPaths = map[entity.ChannelType]bool{
+	entity.ChannelTypeWhatsAppOfficial: true,
+	entity.ChannelTypeFacebook:         true,
+	entity.ChannelTypeInstagram:        true,
+}
+
+// ChannelProber checks a channel against its messaging provider
+type ChannelProber interface {
+	// CheckCredentials verifies the channel's credentials with the provider, including
+	// the permissions Linktor needs, and describes the account they belong to
+	CheckCredentials(ctx context.Context, channel *entity.Channel) (string, error)
+	// SendTest sends a test message to recipient
+	SendTest(ctx context.Context, channel *entity.Channel, recipient, text string) error
+}
+
+// ChannelProbeService probes the capabilities of channels when they are created or
+// connected: it checks their credentials and scopes with the provider, verifies their
+// webhook URL can be reached and sends a test message to a sandbox recipient. The
+// results are kept with the channel so misconfigurations show at setup rather than
+// on the first customer message.
+type ChannelProbeService struct {
+	repo        repository.ChannelProbeRepository
+	channelRepo repository.ChannelRepository
+	webhooks    *WebhookRegistrationService
+	probers     map[entity.ChannelType]ChannelProber
+	httpClient  *http.Client
+	now         func() time.Time
+}
+
+// NewChannelProbeService creates a new channel probe service. Webhook URLs of the
+// channels webhooks can be registered for are taken from webhooks.
+func NewChannelProbeService(repo repository.ChannelProbeRepository, channelRepo repository.ChannelRepository, webhooks *WebhookRegistrationService) *ChannelProbeService {
+	return &ChannelProbeService{
+		repo:        repo,
+		channelRepo: channelRepo,
+		webhooks:    webhooks,
+		probers:     defaultChannelProbers(),
+		httpClient:  newChannelProbeClient(),
+		now:         time.Now,
+	}
+}
+
+// newChannelProbeClient creates the client requesting webhook URLs, which tenants
+// can set to any address, so internal ones are refused
+func newChannelProbeClient() *http.Client {
+	client := egress.NewClient()
+	client.Timeout = channelProbeWebhookTimeout
+	return client
+}
+
+// defaultChannelProbers returns the probers of the channel types whose credentials
+// can be checked with their provider
+func defaultChannelProbers() map[entity.ChannelType]ChannelProber {
+	return map[entity.ChannelType]ChannelProber{
+		entity.ChannelTypeTelegram:         telegramChannelProber{},
+		entity.ChannelTypeSMS:              twilioChannelProber{},
+		entity.ChannelTypeFacebook:         facebookChannelProber{},
+		entity.ChannelTypeInstagram:        instagramChannelProber{},
+		entity.ChannelTypeWhatsAppOfficial: whatsAppChannelProber{},
+		entity.ChannelTypeEmail:            emailChannelProber{},
+	}
+}
+
+// SetProber sets the prober of a channel type
+func (s *ChannelProbeService) SetProber(channelType entity.ChannelType, prober ChannelProber) {
+	s.probers[channelType] = prober
+}
+
+// Probe probes a channel of the tenant and stores the results
+func (s *ChannelProbeService) Probe(ctx context.Context, tenantID, channelID string) (*entity.ChannelProbe, error) {
+	channel, err := s.channel(ctx, tenantID, channelID)
+	if err != nil {
+		return nil, err
+	}
+	return s.probe(ctx, channel, entity.ChannelProbeTriggerManual)
+}
+
+// Get returns the latest probe of a channel of the tenant
+func (s *ChannelProbeService) Get(ctx context.Context, tenantID, channelID string) (*entity.ChannelProbe, error) {
+	if _, err := s.channel(ctx, tenantID, channelID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByChannel(ctx, channelID)
+}
+
+// ProbeInBackground probes a channel that was just created or connected without
+// holding up the caller
+func (s *ChannelProbeService) ProbeInBackground(ctx context.Context, channel *entity.Channel, trigger entity.ChannelProbeTrigger) {
+	// The caller keeps using its channel while the probe reads this copy
+	probed := *channel
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), channelProbeTimeout)
+		defer cancel()
+		if _, err := s.probe(ctx, &probed, trigger); err != nil {
+			logger.Warn("Failed to probe channel",
+				zap.String("channel_id", probed.ID),
+				zap.Error(err),
+			)
+		}
+	}()
+}
+
+func (s *ChannelProbeService) channel(ctx context.Context, tenantID, channelID string) (*entity.Channel, error) {
+	channel, err := s.channelRepo.FindByID(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if channel.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeChannelNotFound, "channel not found")
+	}
+	return channel, nil
+}
+
+// probe runs the checks of a channel and stores the results
+func (s *ChannelProbeService) probe(ctx context.Context, channel *entity.Channel, trigger entity.ChannelProbeTrigger) (*entity.ChannelProbe, error) {
+	prober := s.probers[channel.Type]
+	credentials := s.check(entity.ChannelProbeCheckCredentials, func() (entity.ChannelProbeStatus, string) {
+		return s.checkCredentials(ctx, channel, prober)
+	})
+	webhook := s.check(entity.ChannelProbeCheckWebhook, func() (entity.ChannelProbeStatus, string) {
+		return s.checkWebhook(ctx, channel)
+	})
+	testSend := s.check(entity.ChannelProbeCheckTestSend, func() (entity.ChannelProbeStatus, string) {
+		if credentials.Status == entity.ChannelProbeFailed {
+			return entity.ChannelProbeSkipped, "credentials were rejected"
+		}
+		return s.checkTestSend(ctx, channel, prober)
+	})
+
+	probe := &entity.ChannelProbe{
+		ChannelID: channel.ID,
+		TenantID:  channel.TenantID,
+		Status:    entity.ChannelProbePassed,
+		Checks:    []entity.ChannelProbeCheck{credentials, webhook, testSend},
+		Trigger:   trigger,
+		ProbedAt:  s.now(),
+	}
+	if failed := probe.Failed(); len(failed) > 0 {
+		probe.Status = entity.ChannelProbeFailed
+		for _, check := range failed {
+			logger.Warn("Channel probe check failed",
+				zap.String("channel_id", channel.ID),
+				zap.String("channel_type", string(channel.Type)),
+				zap.String("check", string(check.Name)),
+				zap.String("detail", check.Detail),
+			)
+		}
+	}
+
+	if err := s.repo.Upsert(ctx, probe); err != nil {
+		return nil, err
+	}
+	return probe, nil
+}
+
+// check runs one check and times it
+func (s *ChannelProbeService) check(name entity.ChannelProbeCheckName, run func() (entity.ChannelProbeStatus, string)) entity.ChannelProbeCheck {
+	start := s.now()
+	status, detail := run()
+	return entity.ChannelProbeCheck{
+		Name:       name,
+		Status:     status,
+		Detail:     detail,
+		DurationMs: s.now().Sub(start).Milliseconds(),
+	}
+}
+
+func (s *ChannelProbeService) checkCredentials(ctx context.Context, channel *entity.Channel, prober ChannelProber) (entity.ChannelProbeStatus, string) {
+	if prober == nil {
+		return entity.ChannelProbeSkipped, "credentials of " + string(channel.Type) + " channels cannot be checked"
+	}
+	account, err := prober.CheckCredentials(ctx, channel)
+	if err != nil {
+		return entity.ChannelProbeFailed, err.Error()
+	}
+	return entity.ChannelProbePassed, account
+}
+
+// checkWebhook requests the channel's webhook URL from outside, as the provider would.
+// Meta channels answer the verification handshake, proving the URL reaches the
+// channel with its verify token; for the others any answer short of a server error
+// shows the URL is reachable.
+func (s *ChannelProbeService) checkWebhook(ctx context.Context, channel *entity.Channel) (entity.ChannelProbeStatus, string) {
+	webhookURL := channel.WebhookURL
+	if webhookURL == "" && s.webhooks != nil {
+		if _, ok := webhookPaths[channel.Type]; ok {
+			webhookURL = s.webhooks.WebhookURL(channel)
+		}
+	}
+	if webhookURL == "" {
+		return entity.ChannelProbeSkipped, "channel has no webhook URL"
+	}
+	if !strings.HasPrefix(webhookURL, "https://") {
+		return entity.ChannelProbeFailed, "providers only deliver webhooks to HTTPS URLs: " + webhookURL
+	}
+
+	challenge := ""
+	target := webhookURL
+	if metaVerificationPaths[channel.Type] {
+		challenge = strings.ReplaceAll(uuid.New().String(), "-", "")
+		query := url.Values{}
+		query.Set("hub.mode", "subscribe")
+		query.Set("hub.verify_token", channel.Credentials["verify_token"])
+		query.Set("hub.challenge", challenge)
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return entity.ChannelProbeFailed, "invalid webhook URL: " + err.Error()
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return entity.ChannelProbeFailed, "webhook URL is unreachable: " + err.Error()
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return entity.ChannelProbeFailed, fmt.Sprintf("webhook URL answered HTTP %d", resp.StatusCode)
+	}
+	if challenge != "" {
+		if resp.StatusCode != http.StatusOK || strings.TrimSpace(string(body)) != challenge {
+			return entity.ChannelProbeFailed, fmt.Sprintf("webhook URL did not answer the verification challenge (HTTP %d)", resp.StatusCode)
+		}
+		return entity.ChannelProbePassed, webhookURL + " answered the verification challenge"
+	}
+	return entity.ChannelProbePassed, fmt.Sprintf("%s is reachable (HTTP %d)", webhookURL, resp.StatusCode)
+}
+
+func (s *ChannelProbeService) checkTestSend(ctx context.Context, channel *entity.Channel, prober ChannelProber) (entity.ChannelProbeStatus, string) {
+	if prober == nil {
+		return entity.ChannelProbeSkipped, "test messages cannot be sent on " + string(channel.Type) + " channels"
+	}
+	recipient := channelSetting(channel, ChannelSettingSandboxRecipient)
+	if recipient == "" {
+		return entity.ChannelProbeSkipped, "set " + ChannelSettingSandboxRecipient + " in the channel config to send a test message"
+	}
+	if err := prober.SendTest(ctx, channel, recipient, channelProbeTestText); err != nil {
+		return entity.ChannelProbeFailed, "test message to " + recipient + " failed: " + err.Error()
+	}
+	return entity.ChannelProbePassed, "test message sent to " + recipient
+}
+
+// requireScopes checks a Meta token is valid and was granted one of the scopes of each
+// group
+func requireScopes(ctx context.Context, accessToken, appSecret string, groups ...[]string) error {
+	info, err := meta.NewClient(accessToken, appSecret).DebugToken(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to inspect access token: %w", err)
+	}
+	if !info.IsValid {
+		return fmt.Errorf("access token is invalid or expired")
+	}
+
+	granted := make(map[string]bool, len(info.Scopes))
+	for _, scope := range info.Scopes {
+		granted[scope] = true
+	}
+	var missing []string
+	for _, group := range groups {
+		found := false
+		for _, scope := range group {
+			if granted[scope] {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, strings.Join(group, " or "))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("access token is missing permissions: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// telegramChannelProber checks the bot token with getMe and sends to a chat ID
+type telegramChannelProber struct{}
+
+func (telegramChannelProber) CheckCredentials(ctx context.Context, channel *entity.Channel) (string, error) {
+	client, err := telegramWebhookRegistrar{}.client(channel)
+	if err != nil {
+		return "", err
+	}
+	bot, err := client.GetMe()
+	if err != nil {
+		return "", err
+	}
+	return "bot @" + bot.UserName, nil
+}
+
+func (telegramChannelProber) SendTest(ctx context.Context, channel *entity.Channel, recipient, text string) error {
+	chatID, err := strconv.ParseInt(recipient, 10, 64)
+	if err != nil {
+		return fmt.Errorf("recipient must be a Telegram chat ID")
+	}
+	client, err := telegramWebhookRegistrar{}.client(channel)
+	if err != nil {
+		return err
+	}
+	_, err = client.SendMessage(chatID, text, "", 0)
+	return err
+}
+
+// twilioChannelProber checks the account credentials and sends an SMS
+type twilioChannelProber struct{}
+
+func (twilioChannelProber) CheckCredentials(ctx context.Context, channel *entity.Channel) (string, error) {
+	client, err := twilioWebhookRegistrar{}.client(channel)
+	if err != nil {
+		return "", err
+	}
+	if client.GetPhoneNumber() == "" && client.GetMessagingServiceSID() == "" {
+		return "", fmt.Errorf("phone_number or messaging_service_sid is required")
+	}
+	name, err := client.GetAccountInfo()
+	if err != nil {
+		return "", err
+	}
+	return "account " + name, nil
+}
+
+func (twilioChannelProber) SendTest(ctx context.Context, channel *entity.Channel, recipient, text string) error {
+	client, err := twilioWebhookRegistrar{}.client(channel)
+	if err != nil {
+		return err
+	}
+	result, err := client.SendMessage(recipient, text, nil)
+	if err != nil {
+		return err
+	}
+	if !result.Success {
+		return fmt.Errorf("%s", result.Error)
+	}
+	return nil
+}
+
+// facebookChannelProber checks the page token and its messaging permission
+type facebookChannelProber struct{}
+
+func (facebookChannelProber) CheckCredentials(ctx context.Context, channel *entity.Channel) (string, error) {
+	client, err := facebookWebhookRegistrar{}.client(channel)
+	if err != nil {
+		return "", err
+	}
+	if err := requireScopes(ctx, channelSetting(channel, "page_access_token"), channelSetting(channel, "app_secret"),
+		[]string{"pages_messaging"}, []string{"pages_manage_metadata"}); err != nil {
+		return "", err
+	}
+	page, err := client.GetPageInfo(ctx)
+	if err != nil {
+		return "", err
+	}
+	return "page " + page.Name, nil
+}
+
+func (facebookChannelProber) SendTest(ctx context.Context, channel *entity.Channel, recipient, text string) error {
+	client, err := facebookWebhookRegistrar{}.client(channel)
+	if err != nil {
+		return err
+	}
+	_, err = client.SendTextMessage(ctx, recipient, text)
+	return err
+}
+
+// instagramChannelProber checks the account token. Permissions are only checked for
+// accounts connected through a Facebook page, as Instagram login tokens cannot be
+// inspected.
+type instagramChannelProber struct{}
+
+func (instagramChannelProber) CheckCredentials(ctx context.Context, channel *entity.Channel) (string, error) {
+	client, err := instagramWebhookRegistrar{}.client(channel)
+	if err != nil {
+		return "", err
+	}
+	if pageToken := channelSetting(channel, "page_access_token"); pageToken != "" {
+		if err := requireScopes(ctx, pageToken, channelSetting(channel, "app_secret"),
+			[]string{"instagram_manage_messages", "instagram_business_manage_messages"}); err != nil {
+			return "", err
+		}
+	}
+	account, err := client.GetAccountInfo(ctx)
+	if err != nil {
+		return "", err
+	}
+	return "account " + firstNonEmpty(account.Username, account.ID), nil
+}
+
+func (instagramChannelProber) SendTest(ctx context.Context, channel *entity.Channel, recipient, text string) error {
+	client, err := instagramWebhookRegistrar{}.client(channel)
+	if err != nil {
+		return err
+	}
+	_, err = client.SendTextMessage(ctx, recipient, text)
+	return err
+}
+
+// whatsAppChannelProber checks the access token's WhatsApp permissions and the phone
+// number. The test message is the hello_world template every account has, since
+// text can only be sent to customers who wrote in the last 24 hours.
+type whatsAppChannelProber struct{}
+
+func (whatsAppChannelProber) client(channel *entity.Channel) (*whatsapp_official.Client, error) {
+	accessToken := channelSetting(channel, "access_token")
+	phoneNumberID := channelSetting(channel, "phone_number_id")
+	if accessToken == "" || phoneNumberID == "" {
+		return nil, fmt.Errorf("access_token and phone_number_id are required")
+	}
+	return whatsapp_official.NewClient(&whatsapp_official.Config{
+		AccessToken:   accessToken,
+		PhoneNumberID: phoneNumberID,
+	}), nil
+}
+
+func (p whatsAppChannelProber) CheckCredentials(ctx context.Context, channel *entity.Channel) (string, error) {
+	client, err := p.client(channel)
+	if err != nil {
+		return "", err
+	}
+	if err := requireScopes(ctx, channelSetting(channel, "access_token"), channelSetting(channel, "app_secret"),
+		[]string{"whatsapp_business_messaging"}, []string{"whatsapp_business_management"}); err != nil {
+		return "", err
+	}
+	info, err := client.GetPhoneNumberInfo(ctx)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(info.DisplayPhoneNumber + " " + info.VerifiedName), nil
+}
+
+func (p whatsAppChannelProber) SendTest(ctx context.Context, channel *entity.Channel, recipient, text string) error {
+	client, err := p.client(channel)
+	if err != nil {
+		return err
+	}
+	_, err = client.SendMessage(ctx, &whatsapp_official.SendMessageRequest{
+		MessagingProduct: "whatsapp",
+		To:               recipient,
+		Type:             whatsapp_official.MessageType("template"),
+		Template: &whatsapp_official.TemplateObject{
+			Name:     "hello_world",
+			Language: &whatsapp_official.TemplateLanguage{Code: "en_US"},
+		},
+	})
+	return err
+}
+
+// emailChannelProber connects to the email provider, which for SMTP logs in to the
+// server, and sends a plain text email
+type emailChannelProber struct{}
+
+func (emailChannelProber) client(channel *entity.Channel) (*email.Client, error) {
+	settings := make(map[string]string, len(channel.Config)+len(channel.Credentials))
+	for key, value := range channel.Config {
+		settings[key] = value
+	}
+	for key, value := range channel.Credentials {
+		settings[key] = value
+	}
+	adapter := email.NewAdapter()
+	if err := adapter.Initialize(settings); err != nil {
+		return nil, err
+	}
+	if err := adapter.GetConfig().Validate(); err != nil {
+		return nil, err
+	}
+	return email.NewClient(adapter.GetConfig())
+}
+
+func (p emailChannelProber) CheckCredentials(ctx context.Context, channel *entity.Channel) (string, error) {
+	client, err := p.client(channel)
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+	if err := client.TestConnection(ctx); err != nil {
+		return "", err
+	}
+	return client.GetProviderName() + " " + client.GetConfig().FromEmail, nil
+}
+
+func (p emailChannelProber) SendTest(ctx context.Context, channel *entity.Channel, recipient, text string) error {
+	client, err := p.client(channel)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	result, err := client.SendText(ctx, recipient, "Linktor channel test", text)
+	if err != nil {
+		return err
+	}
+	if !result.Success {
+		return fmt.Errorf("%s", result.Error)
+	}
+	return nil
+}
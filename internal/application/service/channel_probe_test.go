@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockChannelProbeRepo struct {
+	mu     sync.Mutex
+	probes map[string]*entity.ChannelProbe
+}
+
+func (m *mockChannelProbeRepo) Upsert(ctx context.Context, probe *entity.ChannelProbe) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.probes[probe.ChannelID] = probe
+	return nil
+}
+
+func (m *mockChannelProbeRepo) FindByChannel(ctx context.Context, channelID string) (*entity.ChannelProbe, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	probe, ok := m.probes[channelID]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, "channel probe not found")
+	}
+	return probe, nil
+}
+
+type fakeChannelProber struct {
+	credentialsErr error
+	sendErr        error
+	sent           []string
+}
+
+func (p *fakeChannelProber) CheckCredentials(ctx context.Context, channel *entity.Channel) (string, error) {
+	if p.credentialsErr != nil {
+		return "", p.credentialsErr
+	}
+	return "account Acme", nil
+}
+
+func (p *fakeChannelProber) SendTest(ctx context.Context, channel *entity.Channel, recipient, text string) error {
+	if p.sendErr != nil {
+		return p.sendErr
+	}
+	p.sent = append(p.sent, recipient)
+	return nil
+}
+
+// newChannelProbeTestService serves webhooks over HTTPS, answering Meta's verification
+// handshake for channel-1 when its verify token matches
+func newChannelProbeTestService(t *testing.T) (*ChannelProbeService, *testutil.MockChannelRepository, *mockChannelProbeRepo, *fakeChannelProber) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/webhooks/whatsapp/channel-1":
+			if r.URL.Query().Get("hub.mode") == "subscribe" && r.URL.Query().Get("hub.verify_token") == "verify-me" {
+				fmt.Fprint(w, r.URL.Query().Get("hub.challenge"))
+				return
+			}
+			w.WriteHeader(http.StatusForbidden)
+		case "/broken":
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	channels := testutil.NewMockChannelRepository()
+	channels.Channels["channel-1"] = &entity.Channel{
+		ID:          "channel-1",
+		TenantID:    "tenant-1",
+		Type:        entity.ChannelTypeWhatsAppOfficial,
+		Config:      map[string]string{ChannelSettingSandboxRecipient: "5511999990000"},
+		Credentials: map[string]string{"verify_token": "verify-me"},
+	}
+	probes := &mockChannelProbeRepo{probes: map[string]*entity.ChannelProbe{}}
+	prober := &fakeChannelProber{}
+
+	svc := NewChannelProbeService(probes, channels, NewWebhookRegistrationService(channels, server.URL))
+	svc.httpClient = server.Client()
+	svc.SetProber(entity.ChannelTypeWhatsAppOfficial, prober)
+	return svc, channels, probes, prober
+}
+
+func probeChecks(probe *entity.ChannelProbe) map[entity.ChannelProbeCheckName]entity.ChannelProbeStatus {
+	checks := make(map[entity.ChannelProbeCheckName]entity.ChannelProbeStatus)
+	for _, check := range probe.Checks {
+		checks[check.Name] = check.Status
+	}
+	return checks
+}
+
+func TestChannelProbeService_Probe(t *testing.T) {
+	svc, _, probes, prober := newChannelProbeTestService(t)
+	ctx := context.Background()
+
+	probe, err := svc.Probe(ctx, "tenant-1", "channel-1")
+	require.NoError(t, err)
+
+	assert.Equal(t, entity.ChannelProbePassed, probe.Status)
+	assert.Equal(t, entity.ChannelProbeTriggerManual, probe.Trigger)
+	assert.Equal(t, map[entity.ChannelProbeCheckName]entity.ChannelProbeStatus{
+		entity.ChannelProbeCheckCredentials: entity.ChannelProbePassed,
+		entity.ChannelProbeCheckWebhook:     entity.ChannelProbePassed,
+		entity.ChannelProbeCheckTestSend:    entity.ChannelProbePassed,
+	}, probeChecks(probe))
+	assert.Equal(t, "account Acme", probe.Checks[0].Detail)
+	assert.Contains(t, probe.Checks[1].Detail, "verification challenge")
+	assert.Equal(t, []string{"5511999990000"}, prober.sent)
+
+	stored, err := svc.Get(ctx, "tenant-1", "channel-1")
+	require.NoError(t, err)
+	assert.Same(t, probe, stored)
+	assert.Len(t, probes.probes, 1)
+
+	_, err = svc.Get(ctx, "tenant-2", "channel-1")
+	assert.Error(t, err, "channels of other tenants are not found")
+}
+
+func TestChannelProbeService_ProbeFailures(t *testing.T) {
+	svc, channels, _, prober := newChannelProbeTestService(t)
+	ctx := context.Background()
+
+	t.Run("wrong verify token and failed send", func(t *testing.T) {
+		channels.Channels["channel-1"].Credentials["verify_token"] = "stale"
+		prober.sendErr = fmt.Errorf("recipient not in allowed list")
+
+		probe, err := svc.Probe(ctx, "tenant-1", "channel-1")
+		require.NoError(t, err)
+		assert.Equal(t, entity.ChannelProbeFailed, probe.Status)
+		assert.Equal(t, map[entity.ChannelProbeCheckName]entity.ChannelProbeStatus{
+			entity.ChannelProbeCheckCredentials: entity.ChannelProbePassed,
+			entity.ChannelProbeCheckWebhook:     entity.ChannelProbeFailed,
+			entity.ChannelProbeCheckTestSend:    entity.ChannelProbeFailed,
+		}, probeChecks(probe))
+		assert.Contains(t, probe.Checks[2].Detail, "recipient not in allowed list")
+		assert.Len(t, probe.Failed(), 2)
+	})
+
+	t.Run("rejected credentials skip the test send", func(t *testing.T) {
+		prober.credentialsErr = fmt.Errorf("access token is missing permissions: whatsapp_business_messaging")
+		prober.sendErr = nil
+		prober.sent = nil
+
+		probe, err := svc.Probe(ctx, "tenant-1", "channel-1")
+		require.NoError(t, err)
+		assert.Equal(t, entity.ChannelProbeFailed, probe.Status)
+		assert.Equal(t, entity.ChannelProbeFailed, probeChecks(probe)[entity.ChannelProbeCheckCredentials])
+		assert.Equal(t, entity.ChannelProbeSkipped, probeChecks(probe)[entity.ChannelProbeCheckTestSend])
+		assert.Empty(t, prober.sent)
+	})
+
+	t.Run("webhook URL answering a server error", func(t *testing.T) {
+		prober.credentialsErr = nil
+		channel := channels.Channels["channel-1"]
+		channel.WebhookURL = svc.webhooks.baseURL + "/broken"
+
+		probe, err := svc.Probe(ctx, "tenant-1", "channel-1")
+		require.NoError(t, err)
+		assert.Equal(t, entity.ChannelProbeFailed, probeChecks(probe)[entity.ChannelProbeCheckWebhook])
+		assert.Contains(t, probe.Checks[1].Detail, "HTTP 502")
+	})
+
+	t.Run("plain HTTP webhook URL", func(t *testing.T) {
+		channels.Channels["channel-1"].WebhookURL = "http://linktor.local/api/v1/webhooks/whatsapp/channel-1"
+
+		probe, err := svc.Probe(ctx, "tenant-1", "channel-1")
+		require.NoError(t, err)
+		assert.Equal(t, entity.ChannelProbeFailed, probeChecks(probe)[entity.ChannelProbeCheckWebhook])
+		assert.Contains(t, probe.Checks[1].Detail, "HTTPS")
+	})
+}
+
+func TestChannelProbeService_RefusesInternalWebhookURLs(t *testing.T) {
+	testSvc, channels, probes, prober := newChannelProbeTestService(t)
+	svc := NewChannelProbeService(probes, channels, testSvc.webhooks)
+	svc.SetProber(entity.ChannelTypeWhatsAppOfficial, prober)
+
+	// The test server listens on loopback
+	probe, err := svc.Probe(context.Background(), "tenant-1", "channel-1")
+	require.NoError(t, err)
+	assert.Equal(t, entity.ChannelProbeFailed, probeChecks(probe)[entity.ChannelProbeCheckWebhook])
+	assert.Contains(t, probe.Checks[1].Detail, "unreachable")
+}
+
+func TestChannelProbeService_ProbeSkipsWhatDoesNotApply(t *testing.T) {
+	svc, channels, _, prober := newChannelProbeTestService(t)
+	ctx := context.Background()
+
+	channels.Channels["channel-2"] = &entity.Channel{ID: "channel-2", TenantID: "tenant-1", Type: entity.ChannelTypeWebChat}
+	probe, err := svc.Probe(ctx, "tenant-1", "channel-2")
+	require.NoError(t, err)
+	assert.Equal(t, entity.ChannelProbePassed, probe.Status)
+	for _, check := range probe.Checks {
+		assert.Equal(t, entity.ChannelProbeSkipped, check.Status, check.Name)
+	}
+
+	delete(channels.Channels["channel-1"].Config, ChannelSettingSandboxRecipient)
+	probe, err = svc.Probe(ctx, "tenant-1", "channel-1")
+	require.NoError(t, err)
+	assert.Equal(t, entity.ChannelProbeSkipped, probeChecks(probe)[entity.ChannelProbeCheckTestSend])
+	assert.Contains(t, probe.Checks[2].Detail, ChannelSettingSandboxRecipient)
+	assert.Empty(t, prober.sent)
+}
+
+func TestChannelProbeService_ProbeInBackground(t *testing.T) {
+	svc, channels, probes, _ := newChannelProbeTestService(t)
+
+	svc.ProbeInBackground(context.Background(), channels.Channels["channel-1"], entity.ChannelProbeTriggerCreate)
+
+	require.Eventually(t, func() bool {
+		probe, err := probes.FindByChannel(context.Background(), "channel-1")
+		return err == nil && probe.Trigger == entity.ChannelProbeTriggerCreate
+	}, 5*time.Second, 10*time.Millisecond)
+}
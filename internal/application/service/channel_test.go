@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
 	"github.com/msgfy/linktor/pkg/plugin"
 	"github.com/msgfy/linktor/pkg/testutil"
 	"github.com/stretchr/testify/assert"
@@ -93,7 +94,7 @@ func TestChannelService_GetByID_Found(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	found, err := svc.GetByID(context.Background(), created.ID)
+	found, err := svc.GetByID(context.Background(), "tenant1", created.ID)
 	require.NoError(t, err)
 	assert.Equal(t, created.ID, found.ID)
 	assert.Equal(t, "Chat", found.Name)
@@ -102,7 +103,7 @@ func TestChannelService_GetByID_Found(t *testing.T) {
 func TestChannelService_GetByID_NotFound(t *testing.T) {
 	svc, _, _ := newChannelService()
 
-	_, err := svc.GetByID(context.Background(), "nonexistent-id")
+	_, err := svc.GetByID(context.Background(), "tenant1", "nonexistent-id")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "not found")
 }
@@ -165,7 +166,7 @@ func TestChannelService_Update(t *testing.T) {
 
 	newName := "New Name"
 	newIdent := "new-id"
-	updated, err := svc.Update(context.Background(), created.ID, &UpdateChannelInput{
+	updated, err := svc.Update(context.Background(), "tenant1", created.ID, &UpdateChannelInput{
 		Name:        &newName,
 		Identifier:  &newIdent,
 		Config:      map[string]string{"theme": "dark"},
@@ -193,7 +194,7 @@ func TestChannelService_Update_PartialFields(t *testing.T) {
 
 	// Only update Name, leave Identifier untouched
 	newName := "Updated Chat"
-	updated, err := svc.Update(context.Background(), created.ID, &UpdateChannelInput{
+	updated, err := svc.Update(context.Background(), "tenant1", created.ID, &UpdateChannelInput{
 		Name: &newName,
 	})
 
@@ -206,7 +207,7 @@ func TestChannelService_Update_NotFound(t *testing.T) {
 	svc, _, _ := newChannelService()
 
 	newName := "Whatever"
-	_, err := svc.Update(context.Background(), "nonexistent", &UpdateChannelInput{
+	_, err := svc.Update(context.Background(), "tenant1", "nonexistent", &UpdateChannelInput{
 		Name: &newName,
 	})
 
@@ -227,7 +228,7 @@ func TestChannelService_Delete(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, repo.Channels, 1)
 
-	err = svc.Delete(context.Background(), created.ID)
+	err = svc.Delete(context.Background(), "tenant1", created.ID)
 	require.NoError(t, err)
 	assert.Len(t, repo.Channels, 0)
 }
@@ -235,10 +236,8 @@ func TestChannelService_Delete(t *testing.T) {
 func TestChannelService_Delete_NotFound(t *testing.T) {
 	svc, _, _ := newChannelService()
 
-	// The mock's Delete removes from map silently even if not present (no error for missing key).
-	// This is consistent with the mock behavior.
-	err := svc.Delete(context.Background(), "nonexistent")
-	assert.NoError(t, err)
+	err := svc.Delete(context.Background(), "tenant1", "nonexistent")
+	assert.True(t, errors.IsNotFound(err))
 }
 
 // ---------------------------------------------------------------------------
@@ -256,7 +255,7 @@ func TestChannelService_UpdateEnabled_Enable(t *testing.T) {
 	// Disable first via repo to set up scenario
 	repo.Channels[created.ID].Enabled = false
 
-	ch, err := svc.UpdateEnabled(context.Background(), created.ID, true)
+	ch, err := svc.UpdateEnabled(context.Background(), "tenant1", created.ID, true)
 	require.NoError(t, err)
 	assert.True(t, ch.Enabled)
 }
@@ -270,7 +269,7 @@ func TestChannelService_UpdateEnabled_Disable(t *testing.T) {
 	require.NoError(t, err)
 	assert.True(t, created.Enabled, "new channel should be enabled")
 
-	ch, err := svc.UpdateEnabled(context.Background(), created.ID, false)
+	ch, err := svc.UpdateEnabled(context.Background(), "tenant1", created.ID, false)
 	require.NoError(t, err)
 	assert.False(t, ch.Enabled)
 }
@@ -278,7 +277,7 @@ func TestChannelService_UpdateEnabled_Disable(t *testing.T) {
 func TestChannelService_UpdateEnabled_NotFound(t *testing.T) {
 	svc, _, _ := newChannelService()
 
-	_, err := svc.UpdateEnabled(context.Background(), "nonexistent", true)
+	_, err := svc.UpdateEnabled(context.Background(), "tenant1", "nonexistent", true)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "not found")
 }
@@ -296,7 +295,7 @@ func TestChannelService_UpdateStatus_Active(t *testing.T) {
 	require.NoError(t, err)
 	repo.Channels[created.ID].Enabled = false
 
-	ch, err := svc.UpdateStatus(context.Background(), created.ID, "active")
+	ch, err := svc.UpdateStatus(context.Background(), "tenant1", created.ID, "active")
 	require.NoError(t, err)
 	assert.True(t, ch.Enabled)
 }
@@ -309,7 +308,7 @@ func TestChannelService_UpdateStatus_Inactive(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	ch, err := svc.UpdateStatus(context.Background(), created.ID, "inactive")
+	ch, err := svc.UpdateStatus(context.Background(), "tenant1", created.ID, "inactive")
 	require.NoError(t, err)
 	assert.False(t, ch.Enabled)
 }
@@ -322,7 +321,7 @@ func TestChannelService_UpdateStatus_InvalidStatus(t *testing.T) {
 	})
 	require.NoError(t, err)
 
-	_, err = svc.UpdateStatus(context.Background(), created.ID, "unknown")
+	_, err = svc.UpdateStatus(context.Background(), "tenant1", created.ID, "unknown")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "invalid status")
 }
@@ -330,7 +329,7 @@ func TestChannelService_UpdateStatus_InvalidStatus(t *testing.T) {
 func TestChannelService_UpdateStatus_NotFound(t *testing.T) {
 	svc, _, _ := newChannelService()
 
-	_, err := svc.UpdateStatus(context.Background(), "nonexistent", "active")
+	_, err := svc.UpdateStatus(context.Background(), "tenant1", "nonexistent", "active")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "not found")
 }
@@ -348,7 +347,7 @@ func TestChannelService_Connect_NonWhatsApp(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, entity.ConnectionStatusDisconnected, created.ConnectionStatus)
 
-	result, err := svc.Connect(context.Background(), created.ID)
+	result, err := svc.Connect(context.Background(), "tenant1", created.ID)
 	require.NoError(t, err)
 	assert.NotNil(t, result.Channel)
 	assert.Equal(t, entity.ConnectionStatusConnected, result.Channel.ConnectionStatus)
@@ -361,7 +360,7 @@ func TestChannelService_Connect_NonWhatsApp(t *testing.T) {
 func TestChannelService_Connect_NotFound(t *testing.T) {
 	svc, _, _ := newChannelService()
 
-	_, err := svc.Connect(context.Background(), "nonexistent")
+	_, err := svc.Connect(context.Background(), "tenant1", "nonexistent")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "not found")
 }
@@ -379,12 +378,12 @@ func TestChannelService_Disconnect(t *testing.T) {
 	require.NoError(t, err)
 
 	// First connect the channel
-	_, err = svc.Connect(context.Background(), created.ID)
+	_, err = svc.Connect(context.Background(), "tenant1", created.ID)
 	require.NoError(t, err)
 	assert.Equal(t, entity.ConnectionStatusConnected, repo.Channels[created.ID].ConnectionStatus)
 
 	// Now disconnect
-	err = svc.Disconnect(context.Background(), created.ID)
+	err = svc.Disconnect(context.Background(), "tenant1", created.ID)
 	require.NoError(t, err)
 	assert.Equal(t, entity.ConnectionStatusDisconnected, repo.Channels[created.ID].ConnectionStatus)
 }
@@ -392,7 +391,7 @@ func TestChannelService_Disconnect(t *testing.T) {
 func TestChannelService_Disconnect_NotFound(t *testing.T) {
 	svc, _, _ := newChannelService()
 
-	err := svc.Disconnect(context.Background(), "nonexistent")
+	err := svc.Disconnect(context.Background(), "tenant1", "nonexistent")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "not found")
 }
@@ -414,7 +413,7 @@ func TestChannelService_Disconnect_NilRegistry(t *testing.T) {
 	// Connect via repo directly since Connect for webchat uses repo
 	repo.Channels[created.ID].ConnectionStatus = entity.ConnectionStatusConnected
 
-	err = svc.Disconnect(context.Background(), created.ID)
+	err = svc.Disconnect(context.Background(), "tenant1", created.ID)
 	require.NoError(t, err)
 	assert.Equal(t, entity.ConnectionStatusDisconnected, repo.Channels[created.ID].ConnectionStatus)
 }
@@ -467,16 +466,16 @@ func TestChannelService_CreateListUpdateDelete(t *testing.T) {
 
 	// Update
 	newName := "Updated TG Bot"
-	updated, err := svc.Update(ctx, id, &UpdateChannelInput{Name: &newName})
+	updated, err := svc.Update(ctx, "tenant1", id, &UpdateChannelInput{Name: &newName})
 	require.NoError(t, err)
 	assert.Equal(t, "Updated TG Bot", updated.Name)
 
 	// Delete
-	err = svc.Delete(ctx, id)
+	err = svc.Delete(ctx, "tenant1", id)
 	require.NoError(t, err)
 
 	// Verify deleted
-	_, err = svc.GetByID(ctx, id)
+	_, err = svc.GetByID(ctx, "tenant1", id)
 	assert.Error(t, err)
 }
 
@@ -507,13 +506,13 @@ func TestChannelService_ConnectDisconnect_RoundTrip(t *testing.T) {
 	assert.Equal(t, entity.ConnectionStatusDisconnected, ch.ConnectionStatus)
 
 	// Connect
-	result, err := svc.Connect(ctx, ch.ID)
+	result, err := svc.Connect(ctx, "tenant1", ch.ID)
 	require.NoError(t, err)
 	assert.Equal(t, entity.ConnectionStatusConnected, result.Channel.ConnectionStatus)
 	assert.Equal(t, entity.ConnectionStatusConnected, repo.Channels[ch.ID].ConnectionStatus)
 
 	// Disconnect
-	err = svc.Disconnect(ctx, ch.ID)
+	err = svc.Disconnect(ctx, "tenant1", ch.ID)
 	require.NoError(t, err)
 	assert.Equal(t, entity.ConnectionStatusDisconnected, repo.Channels[ch.ID].ConnectionStatus)
 }
@@ -537,7 +536,7 @@ func TestChannelService_Update_NilFieldsPreserved(t *testing.T) {
 	require.NoError(t, err)
 
 	// Update with all-nil optional fields
-	updated, err := svc.Update(ctx, ch.ID, &UpdateChannelInput{})
+	updated, err := svc.Update(ctx, "tenant1", ch.ID, &UpdateChannelInput{})
 	require.NoError(t, err)
 	assert.Equal(t, "Original", updated.Name)
 	assert.Equal(t, "orig-ident", updated.Identifier)
@@ -561,7 +560,7 @@ func TestChannelService_GetByID_RepoError(t *testing.T) {
 	svc, repo, _ := newChannelService()
 	repo.ReturnError = assert.AnError
 
-	_, err := svc.GetByID(context.Background(), "any-id")
+	_, err := svc.GetByID(context.Background(), "tenant1", "any-id")
 	assert.Error(t, err)
 }
 
@@ -577,7 +576,7 @@ func TestChannelService_Update_RepoUpdateError(t *testing.T) {
 	repo.ReturnError = assert.AnError
 
 	newName := "Updated"
-	_, err = svc.Update(context.Background(), ch.ID, &UpdateChannelInput{Name: &newName})
+	_, err = svc.Update(context.Background(), "tenant1", ch.ID, &UpdateChannelInput{Name: &newName})
 	assert.Error(t, err)
 }
 
@@ -585,7 +584,7 @@ func TestChannelService_Delete_RepoError(t *testing.T) {
 	svc, repo, _ := newChannelService()
 	repo.ReturnError = assert.AnError
 
-	err := svc.Delete(context.Background(), "any-id")
+	err := svc.Delete(context.Background(), "tenant1", "any-id")
 	assert.Error(t, err)
 }
 
@@ -600,7 +599,7 @@ func TestChannelService_UpdateEnabled_RepoUpdateEnabledError(t *testing.T) {
 	// Set error after creation
 	repo.ReturnError = assert.AnError
 
-	_, err = svc.UpdateEnabled(context.Background(), ch.ID, false)
+	_, err = svc.UpdateEnabled(context.Background(), "tenant1", ch.ID, false)
 	assert.Error(t, err)
 }
 
@@ -615,7 +614,7 @@ func TestChannelService_Connect_RepoUpdateConnectionStatusError(t *testing.T) {
 	// Set error after creation so UpdateConnectionStatus fails
 	repo.ReturnError = assert.AnError
 
-	_, err = svc.Connect(context.Background(), ch.ID)
+	_, err = svc.Connect(context.Background(), "tenant1", ch.ID)
 	assert.Error(t, err)
 }
 
@@ -630,7 +629,7 @@ func TestChannelService_Disconnect_RepoUpdateConnectionStatusError(t *testing.T)
 	// Set error after creation
 	repo.ReturnError = assert.AnError
 
-	err = svc.Disconnect(context.Background(), ch.ID)
+	err = svc.Disconnect(context.Background(), "tenant1", ch.ID)
 	assert.Error(t, err)
 }
 
@@ -654,3 +653,28 @@ func TestChannelService_Create_UniqueIDs(t *testing.T) {
 
 	assert.NotEqual(t, ch1.ID, ch2.ID)
 }
+
+// ---------------------------------------------------------------------------
+// ReloadSecretCredentials
+// ---------------------------------------------------------------------------
+
+func TestChannelService_ReloadSecretCredentials(t *testing.T) {
+	svc, repo, _ := newChannelService()
+	ctx := context.Background()
+
+	repo.Channels["ch-1"] = &entity.Channel{ID: "ch-1", Credentials: map[string]string{"access_token": "secret://vault/meta/waba#token"}}
+	repo.Channels["ch-2"] = &entity.Channel{ID: "ch-2", Credentials: map[string]string{"access_token": "secret://vault/meta/waba-2#token"}}
+	repo.Channels["ch-3"] = &entity.Channel{ID: "ch-3", Credentials: map[string]string{"access_token": "plain"}}
+
+	var updated []string
+	svc.SetLifecycleHooks(ChannelLifecycleHooks{
+		OnUpdated: func(ctx context.Context, channel *entity.Channel) {
+			updated = append(updated, channel.ID)
+		},
+	})
+
+	count, err := svc.ReloadSecretCredentials(ctx, "secret://vault/meta/waba")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, []string{"ch-1"}, updated)
+}
@@ -0,0 +1,379 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/msgfy/linktor/internal/adapters/facebook"
+	"github.com/msgfy/linktor/internal/adapters/meta"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Channel token lifecycle
+const (
+	channelTokenRefreshWindow = 7 * 24 * time.Hour // Tokens expiring sooner are refreshed
+	channelTokenCheckInterval = 24 * time.Hour     // Tokens are inspected again after this long
+	channelTokenTimeout       = 30 * time.Second
+)
+
+// Events published as channel access tokens are refreshed or expire
+const (
+	EventChannelTokenRefreshed     = "channel.token_refreshed"
+	EventChannelTokenRefreshFailed = "channel.token_refresh_failed"
+	EventChannelTokenExpired       = "channel.token_expired"
+)
+
+// channelTokenKeys are the credentials holding the access token of each channel type
+// whose tokens expire, in order of preference
+var channelTokenKeys = map[entity.ChannelType][]string{
+	entity.ChannelTypeFacebook:         {"page_access_token", "access_token"},
+	entity.ChannelTypeInstagram:        {"access_token", "page_access_token"},
+	entity.ChannelTypeWhatsAppOfficial: {"access_token"},
+}
+
+// ChannelTokenInfo is what a provider reports about an access token
+type ChannelTokenInfo struct {
+	Valid     bool
+	ExpiresAt *time.Time // Nil when the token never expires
+}
+
+// ChannelTokenProvider inspects and refreshes access tokens with their provider
+type ChannelTokenProvider interface {
+	// Inspect reports whether a token is valid and when it expires; errors mean the
+	// provider could not be asked
+	Inspect(ctx context.Context, accessToken, appSecret string) (*ChannelTokenInfo, error)
+	// Refresh exchanges a valid long-lived token for a new one, returning how long it
+	// lasts, zero when it never expires
+	Refresh(ctx context.Context, appID, appSecret, accessToken string) (string, time.Duration, error)
+}
+
+// ChannelTokenService keeps the long-lived access tokens of Facebook, Instagram and
+// WhatsApp channels from expiring: it tracks when each expires, refreshes it through
+// the OAuth token exchange a week ahead, alerts when a refresh fails and disables the
+// channel once its token is no longer accepted.
+type ChannelTokenService struct {
+	repo        repository.ChannelTokenRepository
+	channelRepo repository.ChannelRepository
+	channels    *repository.TenantScope[entity.Channel]
+	provider    ChannelTokenProvider
+	producer    nats.Publisher
+	now         func() time.Time
+}
+
+// NewChannelTokenService creates a new channel token service
+func NewChannelTokenService(repo repository.ChannelTokenRepository, channelRepo repository.ChannelRepository, producer nats.Publisher) *ChannelTokenService {
+	return &ChannelTokenService{
+		repo:        repo,
+		channelRepo: channelRepo,
+		channels:    repository.TenantChannels(channelRepo, channelNotFound),
+		provider:    metaTokenProvider{},
+		producer:    producer,
+		now:         time.Now,
+	}
+}
+
+// SetProvider sets the provider tokens are inspected and refreshed with
+func (s *ChannelTokenService) SetProvider(provider ChannelTokenProvider) {
+	s.provider = provider
+}
+
+// Get returns the token state of a channel of the tenant, inspecting the token
+// when it was never tracked
+func (s *ChannelTokenService) Get(ctx context.Context, tenantID, channelID string) (*entity.ChannelToken, error) {
+	channel, err := s.channels.FindByID(ctx, tenantID, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if channelTokenKey(channel) == "" {
+		return nil, errors.Validation("the channel has no access token that expires")
+	}
+	if token, err := s.repo.FindByChannel(ctx, channel.ID); err == nil && token.Fingerprint == channelTokenFingerprint(channel) {
+		return token, nil
+	}
+	token, _, err := s.check(ctx, channel, false)
+	return token, err
+}
+
+// Refresh refreshes the access token of a channel of the tenant now. The app
+// credentials default to the channel's app_id and app_secret.
+func (s *ChannelTokenService) Refresh(ctx context.Context, tenantID, channelID, appID, appSecret string) (*entity.ChannelToken, error) {
+	channel, err := s.channels.FindByID(ctx, tenantID, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if channelTokenKey(channel) == "" {
+		return nil, errors.Validation("the channel has no access token that expires")
+	}
+	if appID != "" && appSecret != "" {
+		channel.Credentials["app_id"] = appID
+		channel.Credentials["app_secret"] = appSecret
+	}
+
+	token, _, err := s.check(ctx, channel, true)
+	if err != nil {
+		return nil, err
+	}
+	if token.Status != entity.ChannelTokenValid {
+		return token, errors.New(errors.ErrCodeChannelError, "failed to refresh access token: "+token.LastError)
+	}
+	return token, nil
+}
+
+// TrackInBackground starts tracking the token of a channel that was just created or
+// connected without holding up the caller
+func (s *ChannelTokenService) TrackInBackground(ctx context.Context, channel *entity.Channel) {
+	if channelTokenKey(channel) == "" {
+		return
+	}
+	tracked := *channel
+	tracked.Credentials = make(map[string]string, len(channel.Credentials))
+	for key, value := range channel.Credentials {
+		tracked.Credentials[key] = value
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), channelTokenTimeout)
+		defer cancel()
+		if _, _, err := s.check(ctx, &tracked, false); err != nil {
+			logger.Warn("Failed to track channel access token",
+				zap.String("channel_id", tracked.ID),
+				zap.Error(err),
+			)
+		}
+	}()
+}
+
+// Run inspects the tokens of enabled channels and refreshes those about to expire,
+// returning how many were refreshed
+func (s *ChannelTokenService) Run(ctx context.Context) (int, error) {
+	channels, err := s.channelRepo.FindByTypes(ctx, []entity.ChannelType{
+		entity.ChannelTypeFacebook,
+		entity.ChannelTypeInstagram,
+		entity.ChannelTypeWhatsAppOfficial,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	refreshed := 0
+	for _, channel := range channels {
+		if !channel.Enabled || channelTokenKey(channel) == "" {
+			continue
+		}
+		_, didRefresh, err := s.check(ctx, channel, false)
+		if err != nil {
+			logger.Warn("Failed to check channel access token",
+				zap.String("channel_id", channel.ID),
+				zap.Error(err),
+			)
+			continue
+		}
+		if didRefresh {
+			refreshed++
+		}
+	}
+	return refreshed, nil
+}
+
+// check brings the token state of a channel up to date: it inspects the token when
+// it was not checked lately and refreshes it when it expires soon or when forced,
+// reporting whether it was refreshed
+func (s *ChannelTokenService) check(ctx context.Context, channel *entity.Channel, forceRefresh bool) (*entity.ChannelToken, bool, error) {
+	now := s.now()
+	fingerprint := channelTokenFingerprint(channel)
+	token, err := s.repo.FindByChannel(ctx, channel.ID)
+	if err != nil || token.Fingerprint != fingerprint {
+		// A replaced token starts over
+		token = &entity.ChannelToken{
+			ChannelID:   channel.ID,
+			TenantID:    channel.TenantID,
+			Fingerprint: fingerprint,
+			Status:      entity.ChannelTokenValid,
+		}
+	}
+	if token.Status == entity.ChannelTokenExpired && !forceRefresh {
+		return token, false, nil
+	}
+
+	key := channelTokenKey(channel)
+	appSecret := channelSetting(channel, "app_secret")
+	if token.CheckedAt.IsZero() || now.Sub(token.CheckedAt) >= channelTokenCheckInterval || token.ExpiresWithin(channelTokenRefreshWindow, now) {
+		info, err := s.provider.Inspect(ctx, channel.Credentials[key], appSecret)
+		if err != nil {
+			return nil, false, errors.Wrap(err, errors.ErrCodeChannelError, "failed to inspect access token")
+		}
+		token.CheckedAt = now
+		if !info.Valid {
+			s.expire(ctx, channel, token, "access token is invalid or expired")
+			return token, false, s.save(ctx, token)
+		}
+		token.ExpiresAt = info.ExpiresAt
+	}
+
+	refreshed := false
+	if forceRefresh || token.ExpiresWithin(channelTokenRefreshWindow, now) {
+		refreshed = s.refresh(ctx, channel, token, key)
+	}
+	return token, refreshed, s.save(ctx, token)
+}
+
+// refresh exchanges the token of a channel for a new one and stores it with the
+// channel. Failures are alerted on, and disable the channel once the token expired.
+func (s *ChannelTokenService) refresh(ctx context.Context, channel *entity.Channel, token *entity.ChannelToken, key string) bool {
+	now := s.now()
+	appID, appSecret := channelSetting(channel, "app_id"), channelSetting(channel, "app_secret")
+
+	var err error
+	var accessToken string
+	var lifetime time.Duration
+	switch {
+	case channel.CredentialRefs[key].Reference != "":
+		err = errors.New(errors.ErrCodeValidation, "the access token is read from a secret; rotate it in the secrets backend")
+	case appID == "" || appSecret == "":
+		err = errors.New(errors.ErrCodeValidation, "app_id and app_secret are required to refresh the access token")
+	default:
+		accessToken, lifetime, err = s.provider.Refresh(ctx, appID, appSecret, channel.Credentials[key])
+		if err == nil {
+			channel.Credentials[key] = accessToken
+			channel.UpdatedAt = now
+			err = s.channelRepo.Update(ctx, channel)
+		}
+	}
+
+	if err != nil {
+		token.Failures++
+		token.LastError = err.Error()
+		if token.ExpiresAt != nil && !token.ExpiresAt.After(now) {
+			s.expire(ctx, channel, token, "access token expired and could not be refreshed: "+err.Error())
+			return false
+		}
+		token.Status = entity.ChannelTokenRefreshFailed
+		logger.Warn("Failed to refresh channel access token",
+			zap.String("channel_id", channel.ID),
+			zap.Int("failures", token.Failures),
+			zap.Error(err),
+		)
+		payload := map[string]interface{}{
+			"error":    err.Error(),
+			"failures": token.Failures,
+			"message":  "The channel's access token could not be refreshed. Reconnect the channel before it expires.",
+		}
+		if token.ExpiresAt != nil {
+			payload["expires_at"] = token.ExpiresAt.UTC().Format(time.RFC3339)
+		}
+		s.publish(ctx, EventChannelTokenRefreshFailed, channel, payload)
+		return false
+	}
+
+	token.Fingerprint = channelTokenFingerprint(channel)
+	token.Status = entity.ChannelTokenValid
+	token.ExpiresAt = nil
+	if lifetime > 0 {
+		expiresAt := now.Add(lifetime)
+		token.ExpiresAt = &expiresAt
+	}
+	token.CheckedAt = now
+	token.RefreshedAt = &now
+	token.Failures = 0
+	token.LastError = ""
+	logger.Info("Refreshed channel access token", zap.String("channel_id", channel.ID))
+	s.publish(ctx, EventChannelTokenRefreshed, channel, map[string]interface{}{})
+	return true
+}
+
+// expire disables a channel whose token is no longer accepted and alerts on it
+func (s *ChannelTokenService) expire(ctx context.Context, channel *entity.Channel, token *entity.ChannelToken, reason string) {
+	token.Status = entity.ChannelTokenExpired
+	token.LastError = reason
+
+	if channel.Enabled {
+		if err := s.channelRepo.UpdateEnabled(ctx, channel.ID, false); err != nil {
+			logger.Error("Failed to disable channel with expired access token", zap.String("channel_id", channel.ID), zap.Error(err))
+		}
+		if err := s.channelRepo.UpdateConnectionStatus(ctx, channel.ID, entity.ConnectionStatusError); err != nil {
+			logger.Error("Failed to update connection status of channel with expired access token", zap.String("channel_id", channel.ID), zap.Error(err))
+		}
+		channel.Enabled = false
+		channel.ConnectionStatus = entity.ConnectionStatusError
+	}
+
+	logger.Warn("Channel disabled after its access token expired",
+		zap.String("channel_id", channel.ID),
+		zap.String("reason", reason),
+	)
+	s.publish(ctx, EventChannelTokenExpired, channel, map[string]interface{}{
+		"reason":  reason,
+		"message": "The channel was disabled because its access token is no longer accepted. Reconnect it to resume messaging.",
+	})
+}
+
+func (s *ChannelTokenService) save(ctx context.Context, token *entity.ChannelToken) error {
+	token.UpdatedAt = s.now()
+	return s.repo.Upsert(ctx, token)
+}
+
+func (s *ChannelTokenService) publish(ctx context.Context, eventType string, channel *entity.Channel, payload map[string]interface{}) {
+	if s.producer == nil {
+		return
+	}
+
+	payload["channel_id"] = channel.ID
+	payload["channel_name"] = channel.Name
+	event := &nats.Event{
+		Type:      eventType,
+		TenantID:  channel.TenantID,
+		Payload:   payload,
+		Timestamp: s.now(),
+	}
+	if err := s.producer.PublishEvent(ctx, event); err != nil {
+		logger.Error("Failed to publish channel token event", zap.String("type", eventType), zap.Error(err))
+	}
+}
+
+// channelTokenKey returns the credential holding the expiring access token of a
+// channel, empty when it has none
+func channelTokenKey(channel *entity.Channel) string {
+	for _, key := range channelTokenKeys[channel.Type] {
+		if channel.Credentials[key] != "" {
+			return key
+		}
+	}
+	return ""
+}
+
+// channelTokenFingerprint identifies the access token of a channel without keeping it
+func channelTokenFingerprint(channel *entity.Channel) string {
+	sum := sha256.Sum256([]byte(channel.Credentials[channelTokenKey(channel)]))
+	return hex.EncodeToString(sum[:16])
+}
+
+// metaTokenProvider inspects tokens with debug_token and refreshes them with the
+// fb_exchange_token grant of the OAuth flow
+type metaTokenProvider struct{}
+
+func (metaTokenProvider) Inspect(ctx context.Context, accessToken, appSecret string) (*ChannelTokenInfo, error) {
+	info, err := meta.NewClient(accessToken, appSecret).DebugToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := &ChannelTokenInfo{Valid: info.IsValid}
+	if info.ExpiresAt > 0 {
+		expiresAt := time.Unix(info.ExpiresAt, 0)
+		result.ExpiresAt = &expiresAt
+	}
+	return result, nil
+}
+
+func (metaTokenProvider) Refresh(ctx context.Context, appID, appSecret, accessToken string) (string, time.Duration, error) {
+	resp, err := facebook.NewOAuthHelper(appID, appSecret).GetLongLivedToken(ctx, accessToken)
+	if err != nil {
+		return "", 0, err
+	}
+	return resp.AccessToken, time.Duration(resp.ExpiresIn) * time.Second, nil
+}
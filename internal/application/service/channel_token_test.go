@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockChannelTokenRepo struct {
+	mu     sync.Mutex
+	tokens map[string]*entity.ChannelToken
+}
+
+func (m *mockChannelTokenRepo) Upsert(ctx context.Context, token *entity.ChannelToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stored := *token
+	m.tokens[token.ChannelID] = &stored
+	return nil
+}
+
+func (m *mockChannelTokenRepo) FindByChannel(ctx context.Context, channelID string) (*entity.ChannelToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	token, ok := m.tokens[channelID]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, "channel token not tracked")
+	}
+	found := *token
+	return &found, nil
+}
+
+type fakeChannelTokenProvider struct {
+	valid      bool
+	expiresAt  *time.Time
+	refreshErr error
+	refreshed  []string
+}
+
+func (p *fakeChannelTokenProvider) Inspect(ctx context.Context, accessToken, appSecret string) (*ChannelTokenInfo, error) {
+	return &ChannelTokenInfo{Valid: p.valid, ExpiresAt: p.expiresAt}, nil
+}
+
+func (p *fakeChannelTokenProvider) Refresh(ctx context.Context, appID, appSecret, accessToken string) (string, time.Duration, error) {
+	if p.refreshErr != nil {
+		return "", 0, p.refreshErr
+	}
+	p.refreshed = append(p.refreshed, accessToken)
+	return fmt.Sprintf("token-%d", len(p.refreshed)+1), 60 * 24 * time.Hour, nil
+}
+
+func newChannelTokenTestService(expiresIn time.Duration) (*ChannelTokenService, *testutil.MockChannelRepository, *mockChannelTokenRepo, *fakeChannelTokenProvider, *testutil.MockProducer) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	expiresAt := now.Add(expiresIn)
+
+	channels := testutil.NewMockChannelRepository()
+	channels.Channels["channel-1"] = &entity.Channel{
+		ID:       "channel-1",
+		TenantID: "tenant-1",
+		Name:     "Page",
+		Type:     entity.ChannelTypeFacebook,
+		Enabled:  true,
+		Credentials: map[string]string{
+			"page_access_token": "token-1",
+			"app_id":            "app",
+			"app_secret":        "secret",
+		},
+	}
+	tokens := &mockChannelTokenRepo{tokens: map[string]*entity.ChannelToken{}}
+	provider := &fakeChannelTokenProvider{valid: true, expiresAt: &expiresAt}
+	producer := testutil.NewMockProducer()
+
+	svc := NewChannelTokenService(tokens, channels, producer)
+	svc.SetProvider(provider)
+	svc.now = func() time.Time { return now }
+	return svc, channels, tokens, provider, producer
+}
+
+func TestChannelTokenService_RunRefreshesTokensAboutToExpire(t *testing.T) {
+	svc, channels, tokens, provider, producer := newChannelTokenTestService(3 * 24 * time.Hour)
+
+	refreshed, err := svc.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, refreshed)
+	assert.Equal(t, []string{"token-1"}, provider.refreshed)
+	assert.Equal(t, "token-2", channels.Channels["channel-1"].Credentials["page_access_token"])
+	token := tokens.tokens["channel-1"]
+	assert.Equal(t, entity.ChannelTokenValid, token.Status)
+	assert.Equal(t, svc.now().Add(60*24*time.Hour), *token.ExpiresAt)
+	require.NotNil(t, token.RefreshedAt)
+	require.Len(t, producer.Events, 1)
+	assert.Equal(t, EventChannelTokenRefreshed, producer.Events[0].Type)
+
+	// The refreshed token is far from expiring, so the next run leaves it alone
+	refreshed, err = svc.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, refreshed)
+	assert.Len(t, provider.refreshed, 1)
+}
+
+func TestChannelTokenService_RunLeavesLongLivedTokens(t *testing.T) {
+	svc, channels, tokens, provider, producer := newChannelTokenTestService(50 * 24 * time.Hour)
+
+	refreshed, err := svc.Run(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, refreshed)
+	assert.Empty(t, provider.refreshed)
+	assert.Equal(t, "token-1", channels.Channels["channel-1"].Credentials["page_access_token"])
+	assert.Equal(t, entity.ChannelTokenValid, tokens.tokens["channel-1"].Status)
+	assert.Empty(t, producer.Events)
+}
+
+func TestChannelTokenService_RefreshFailureAlerts(t *testing.T) {
+	svc, channels, tokens, provider, producer := newChannelTokenTestService(3 * 24 * time.Hour)
+	provider.refreshErr = fmt.Errorf("session has been invalidated")
+
+	_, err := svc.Run(context.Background())
+	require.NoError(t, err)
+
+	token := tokens.tokens["channel-1"]
+	assert.Equal(t, entity.ChannelTokenRefreshFailed, token.Status)
+	assert.Equal(t, 1, token.Failures)
+	assert.Contains(t, token.LastError, "session has been invalidated")
+	assert.True(t, channels.Channels["channel-1"].Enabled)
+	require.Len(t, producer.Events, 1)
+	assert.Equal(t, EventChannelTokenRefreshFailed, producer.Events[0].Type)
+	assert.Equal(t, "channel-1", producer.Events[0].Payload["channel_id"])
+}
+
+func TestChannelTokenService_DisablesChannelWhenTokenExpires(t *testing.T) {
+	svc, channels, tokens, provider, producer := newChannelTokenTestService(3 * 24 * time.Hour)
+	provider.valid = false
+
+	_, err := svc.Run(context.Background())
+	require.NoError(t, err)
+
+	channel := channels.Channels["channel-1"]
+	assert.False(t, channel.Enabled)
+	assert.Equal(t, entity.ConnectionStatusError, channel.ConnectionStatus)
+	assert.Equal(t, entity.ChannelTokenExpired, tokens.tokens["channel-1"].Status)
+	require.Len(t, producer.Events, 1)
+	assert.Equal(t, EventChannelTokenExpired, producer.Events[0].Type)
+
+	// A replaced token is tracked again
+	channel.Enabled = true
+	channel.Credentials["page_access_token"] = "reconnected"
+	provider.valid = true
+	token, err := svc.Get(context.Background(), "tenant-1", "channel-1")
+	require.NoError(t, err)
+	assert.Equal(t, entity.ChannelTokenValid, token.Status)
+}
+
+func TestChannelTokenService_RefreshSkipsSecretBackedTokens(t *testing.T) {
+	svc, channels, _, provider, _ := newChannelTokenTestService(3 * 24 * time.Hour)
+	channels.Channels["channel-1"].CredentialRefs = map[string]entity.CredentialRef{
+		"page_access_token": {Reference: "secret://vault/meta/page"},
+	}
+
+	token, err := svc.Refresh(context.Background(), "tenant-1", "channel-1", "", "")
+	require.Error(t, err)
+	assert.Equal(t, entity.ChannelTokenRefreshFailed, token.Status)
+	assert.Contains(t, token.LastError, "secret")
+	assert.Empty(t, provider.refreshed)
+}
+
+func TestChannelTokenService_ScopedToTenant(t *testing.T) {
+	svc, _, _, provider, _ := newChannelTokenTestService(3 * 24 * time.Hour)
+
+	_, err := svc.Get(context.Background(), "tenant-2", "channel-1")
+	assert.True(t, errors.IsNotFound(err))
+
+	_, err = svc.Refresh(context.Background(), "tenant-2", "channel-1", "", "")
+	assert.True(t, errors.IsNotFound(err))
+	assert.Empty(t, provider.refreshed)
+}
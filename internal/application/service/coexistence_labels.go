@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/adapters/whatsapp_official"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// coexistenceLabelsConfigKey stores the Business App labels seen on a channel as a
+// JSON object of label ID to name
+const coexistenceLabelsConfigKey = "coexistence_labels"
+
+// labelSyncPageSize is the page size used when renaming or removing a label's tag
+// on every contact of a tenant
+const labelSyncPageSize = 200
+
+// CoexistenceLabelSyncService keeps contact tags in line with the labels set in the
+// WhatsApp Business App of coexistence channels. Labels map to tags of the same name.
+// Only tags named after a known label are added or removed, so tags managed in
+// Linktor are left alone. The Cloud API only reports labels through the
+// smb_app_state_sync webhook and offers no way to write them, so changes made to tags
+// in Linktor are not pushed back to the app.
+type CoexistenceLabelSyncService struct {
+	contactRepo repository.ContactRepository
+	channelRepo repository.ChannelRepository
+}
+
+// NewCoexistenceLabelSyncService creates a new coexistence label sync service
+func NewCoexistenceLabelSyncService(
+	contactRepo repository.ContactRepository,
+	channelRepo repository.ChannelRepository,
+) *CoexistenceLabelSyncService {
+	return &CoexistenceLabelSyncService{
+		contactRepo: contactRepo,
+		channelRepo: channelRepo,
+	}
+}
+
+// ApplyStateSync applies Business App contact and label changes of a channel. Label
+// renames and removals are applied to every contact of the tenant; contact changes
+// that expose labels replace the contact's label tags, creating the contact if the
+// address book entry is new.
+func (s *CoexistenceLabelSyncService) ApplyStateSync(ctx context.Context, channel *entity.Channel, changes []*whatsapp_official.ParsedStateSync) error {
+	if !channel.IsLabelSyncEnabled() || len(changes) == 0 {
+		return nil
+	}
+
+	catalog := loadLabelCatalog(channel)
+	catalogChanged := false
+
+	for _, change := range changes {
+		switch change.Type {
+		case "label":
+			if change.Label == nil || change.Label.ID == "" {
+				continue
+			}
+			if s.applyLabelChange(ctx, channel.TenantID, catalog, change) {
+				catalogChanged = true
+			}
+		case "contact":
+			if !change.HasLabels || change.PhoneNumber == "" || change.Action == "remove" {
+				continue
+			}
+			for _, label := range change.Labels {
+				if label.ID != "" && label.Name != "" && catalog[label.ID] != label.Name {
+					catalog[label.ID] = label.Name
+					catalogChanged = true
+				}
+			}
+			if err := s.applyContactLabels(ctx, channel, catalog, change); err != nil {
+				logger.Warn("Failed to sync Business App labels of contact",
+					zap.String("channel_id", channel.ID),
+					zap.String("phone", change.PhoneNumber),
+					zap.Error(err),
+				)
+			}
+		}
+	}
+
+	if !catalogChanged {
+		return nil
+	}
+	encoded, err := json.Marshal(catalog)
+	if err != nil {
+		return err
+	}
+	if channel.Config == nil {
+		channel.Config = make(map[string]string)
+	}
+	channel.Config[coexistenceLabelsConfigKey] = string(encoded)
+	channel.UpdatedAt = time.Now()
+	return s.channelRepo.Update(ctx, channel)
+}
+
+// applyLabelChange updates the catalog with a label change and renames or removes the
+// label's tag. It returns whether the catalog changed.
+func (s *CoexistenceLabelSyncService) applyLabelChange(ctx context.Context, tenantID string, catalog map[string]string, change *whatsapp_official.ParsedStateSync) bool {
+	id := change.Label.ID
+	previous := catalog[id]
+
+	if change.Action == "remove" {
+		if previous == "" {
+			previous = change.Label.Name
+		}
+		delete(catalog, id)
+		if previous != "" {
+			s.retagContacts(ctx, tenantID, previous, "")
+		}
+		return true
+	}
+
+	name := strings.TrimSpace(change.Label.Name)
+	if name == "" || name == previous {
+		return false
+	}
+	catalog[id] = name
+	if previous != "" {
+		s.retagContacts(ctx, tenantID, previous, name)
+	}
+	return true
+}
+
+// retagContacts replaces the tag from with to on every contact of a tenant, or removes
+// it when to is empty
+func (s *CoexistenceLabelSyncService) retagContacts(ctx context.Context, tenantID, from, to string) {
+	params := &repository.ListParams{Page: 1, PageSize: labelSyncPageSize}
+	for {
+		contacts, total, err := s.contactRepo.FindByTenant(ctx, tenantID, params)
+		if err != nil {
+			logger.Warn("Failed to list contacts for Business App label sync",
+				zap.String("tenant_id", tenantID),
+				zap.Error(err),
+			)
+			return
+		}
+
+		for _, contact := range contacts {
+			if !contact.HasTag(from) {
+				continue
+			}
+			tags := make([]string, 0, len(contact.Tags))
+			for _, tag := range contact.Tags {
+				if tag != from {
+					tags = append(tags, tag)
+				}
+			}
+			if to != "" && !contact.HasTag(to) {
+				tags = append(tags, to)
+			}
+			contact.Tags = tags
+			contact.UpdatedAt = time.Now()
+			if err := s.contactRepo.Update(ctx, contact); err != nil {
+				logger.Warn("Failed to update contact tags",
+					zap.String("contact_id", contact.ID),
+					zap.Error(err),
+				)
+			}
+		}
+
+		if len(contacts) == 0 || int64(params.Page*params.PageSize) >= total {
+			return
+		}
+		params.Page++
+	}
+}
+
+// applyContactLabels replaces the label tags of the contact behind a Business App
+// address book entry with its current labels
+func (s *CoexistenceLabelSyncService) applyContactLabels(ctx context.Context, channel *entity.Channel, catalog map[string]string, change *whatsapp_official.ParsedStateSync) error {
+	known := make(map[string]bool, len(catalog))
+	for _, name := range catalog {
+		known[name] = true
+	}
+	var labels []string
+	current := make(map[string]bool, len(change.Labels))
+	for _, label := range change.Labels {
+		name := label.Name
+		if name == "" {
+			name = catalog[label.ID]
+		}
+		if name != "" && !current[name] {
+			current[name] = true
+			labels = append(labels, name)
+		}
+	}
+
+	contact, created, err := s.findOrCreateContact(ctx, channel, change)
+	if err != nil {
+		return err
+	}
+
+	tags := make([]string, 0, len(contact.Tags)+len(labels))
+	changed := false
+	for _, tag := range contact.Tags {
+		if known[tag] && !current[tag] {
+			changed = true
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	for _, name := range labels {
+		if !contact.HasTag(name) {
+			tags = append(tags, name)
+			changed = true
+		}
+	}
+	if !changed && !created {
+		return nil
+	}
+
+	contact.Tags = tags
+	contact.UpdatedAt = time.Now()
+	return s.contactRepo.Update(ctx, contact)
+}
+
+// findOrCreateContact finds the contact of a Business App address book entry by its
+// WhatsApp identity or phone, creating it when the entry is new
+func (s *CoexistenceLabelSyncService) findOrCreateContact(ctx context.Context, channel *entity.Channel, change *whatsapp_official.ParsedStateSync) (*entity.Contact, bool, error) {
+	phone := strings.TrimPrefix(change.PhoneNumber, "+")
+	if contact, err := s.contactRepo.FindByIdentity(ctx, channel.TenantID, string(channel.Type), phone); err == nil && contact != nil {
+		return contact, false, nil
+	}
+	for _, candidate := range []string{phone, "+" + phone} {
+		if contact, err := s.contactRepo.FindByPhone(ctx, channel.TenantID, candidate); err == nil && contact != nil {
+			return contact, false, nil
+		}
+	}
+
+	now := time.Now()
+	name := change.FullName
+	if name == "" {
+		name = phone
+	}
+	contact := &entity.Contact{
+		ID:           uuid.New().String(),
+		TenantID:     channel.TenantID,
+		Name:         name,
+		Phone:        phone,
+		CustomFields: make(map[string]string),
+		Tags:         []string{},
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	if err := s.contactRepo.Create(ctx, contact); err != nil {
+		return nil, false, err
+	}
+
+	identity := &entity.ContactIdentity{
+		ID:          uuid.New().String(),
+		ContactID:   contact.ID,
+		ChannelType: string(channel.Type),
+		Identifier:  phone,
+		Metadata:    map[string]string{"channel_id": channel.ID},
+		CreatedAt:   now,
+	}
+	if err := s.contactRepo.AddIdentity(ctx, identity); err != nil {
+		logger.Warn("Failed to add WhatsApp identity to contact",
+			zap.String("contact_id", contact.ID),
+			zap.Error(err),
+		)
+	}
+	return contact, true, nil
+}
+
+// loadLabelCatalog returns the Business App labels seen on a channel
+func loadLabelCatalog(channel *entity.Channel) map[string]string {
+	catalog := make(map[string]string)
+	if channel.Config != nil {
+		_ = json.Unmarshal([]byte(channel.Config[coexistenceLabelsConfigKey]), &catalog)
+	}
+	return catalog
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/adapters/whatsapp_official"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLabelSyncFixture() (*CoexistenceLabelSyncService, *testutil.MockContactRepository, *entity.Channel) {
+	contacts := testutil.NewMockContactRepository()
+	channels := testutil.NewMockChannelRepository()
+
+	channel := &entity.Channel{
+		ID:            "ch-1",
+		TenantID:      "tenant-1",
+		Type:          entity.ChannelTypeWhatsAppOfficial,
+		IsCoexistence: true,
+		Config:        map[string]string{},
+	}
+	channels.Channels[channel.ID] = channel
+
+	contacts.Contacts["maria"] = &entity.Contact{
+		ID:       "maria",
+		TenantID: "tenant-1",
+		Name:     "Maria",
+		Phone:    "5511999998888",
+		Tags:     []string{"newsletter"},
+		Identities: []*entity.ContactIdentity{
+			{ChannelType: string(entity.ChannelTypeWhatsAppOfficial), Identifier: "5511999998888"},
+		},
+	}
+	contacts.Contacts["other-tenant"] = &entity.Contact{ID: "other-tenant", TenantID: "tenant-2", Tags: []string{"VIP"}}
+
+	return NewCoexistenceLabelSyncService(contacts, channels), contacts, channel
+}
+
+func contactLabels(phone string, labels ...whatsapp_official.StateSyncLabel) *whatsapp_official.ParsedStateSync {
+	if labels == nil {
+		labels = []whatsapp_official.StateSyncLabel{}
+	}
+	return &whatsapp_official.ParsedStateSync{
+		Type:        "contact",
+		Action:      "add",
+		PhoneNumber: phone,
+		Labels:      labels,
+		HasLabels:   true,
+	}
+}
+
+func TestCoexistenceLabelSync_ContactLabels(t *testing.T) {
+	svc, contacts, channel := newLabelSyncFixture()
+	ctx := context.Background()
+
+	err := svc.ApplyStateSync(ctx, channel, []*whatsapp_official.ParsedStateSync{
+		contactLabels("5511999998888", whatsapp_official.StateSyncLabel{ID: "1", Name: "VIP"}, whatsapp_official.StateSyncLabel{ID: "2", Name: "Lead"}),
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"newsletter", "VIP", "Lead"}, contacts.Contacts["maria"].Tags)
+	assert.JSONEq(t, `{"1":"VIP","2":"Lead"}`, channel.Config[coexistenceLabelsConfigKey])
+
+	// Removing a label in the app removes only that label's tag
+	err = svc.ApplyStateSync(ctx, channel, []*whatsapp_official.ParsedStateSync{
+		contactLabels("+5511999998888", whatsapp_official.StateSyncLabel{ID: "2"}),
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"newsletter", "Lead"}, contacts.Contacts["maria"].Tags)
+
+	// Entries without exposed labels leave tags untouched
+	err = svc.ApplyStateSync(ctx, channel, []*whatsapp_official.ParsedStateSync{
+		{Type: "contact", Action: "add", PhoneNumber: "5511999998888"},
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"newsletter", "Lead"}, contacts.Contacts["maria"].Tags)
+
+	// New address book entries become contacts
+	err = svc.ApplyStateSync(ctx, channel, []*whatsapp_official.ParsedStateSync{
+		{Type: "contact", Action: "add", PhoneNumber: "5511977776666", FullName: "Joao", HasLabels: true,
+			Labels: []whatsapp_official.StateSyncLabel{{ID: "1", Name: "VIP"}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, contacts.Contacts, 3)
+	for _, contact := range contacts.Contacts {
+		if contact.Phone == "5511977776666" {
+			assert.Equal(t, "Joao", contact.Name)
+			assert.Equal(t, []string{"VIP"}, contact.Tags)
+			require.Len(t, contacts.Identities[contact.ID], 1)
+			assert.Equal(t, "5511977776666", contacts.Identities[contact.ID][0].Identifier)
+		}
+	}
+}
+
+func TestCoexistenceLabelSync_LabelRenameAndRemoval(t *testing.T) {
+	svc, contacts, channel := newLabelSyncFixture()
+	ctx := context.Background()
+	channel.Config[coexistenceLabelsConfigKey] = `{"1":"VIP","2":"Lead"}`
+	contacts.Contacts["maria"].Tags = []string{"newsletter", "VIP", "Lead"}
+
+	err := svc.ApplyStateSync(ctx, channel, []*whatsapp_official.ParsedStateSync{
+		{Type: "label", Action: "edit", Label: &whatsapp_official.StateSyncLabel{ID: "1", Name: "Gold"}},
+		{Type: "label", Action: "remove", Label: &whatsapp_official.StateSyncLabel{ID: "2"}},
+	})
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []string{"newsletter", "Gold"}, contacts.Contacts["maria"].Tags)
+	assert.Equal(t, []string{"VIP"}, contacts.Contacts["other-tenant"].Tags)
+	assert.JSONEq(t, `{"1":"Gold"}`, channel.Config[coexistenceLabelsConfigKey])
+}
+
+func TestCoexistenceLabelSync_Disabled(t *testing.T) {
+	svc, contacts, channel := newLabelSyncFixture()
+	ctx := context.Background()
+	change := contactLabels("5511999998888", whatsapp_official.StateSyncLabel{ID: "1", Name: "VIP"})
+
+	channel.Config["label_sync"] = "false"
+	require.NoError(t, svc.ApplyStateSync(ctx, channel, []*whatsapp_official.ParsedStateSync{change}))
+	assert.Equal(t, []string{"newsletter"}, contacts.Contacts["maria"].Tags)
+
+	delete(channel.Config, "label_sync")
+	channel.IsCoexistence = false
+	require.NoError(t, svc.ApplyStateSync(ctx, channel, []*whatsapp_official.ParsedStateSync{change}))
+	assert.Equal(t, []string{"newsletter"}, contacts.Contacts["maria"].Tags)
+	assert.Empty(t, channel.Config[coexistenceLabelsConfigKey])
+}
@@ -0,0 +1,413 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// builtInComplianceRulePrefix marks the IDs of the rules every tenant is checked against
+const builtInComplianceRulePrefix = "builtin:"
+
+var whatsAppComplianceChannelTypes = []entity.ChannelType{
+	entity.ChannelTypeWhatsApp,
+	entity.ChannelTypeWhatsAppOfficial,
+	entity.ChannelTypeWhatsAppUnofficial,
+}
+
+// builtInComplianceRules are checked for every tenant in addition to their own rules
+var builtInComplianceRules = []*entity.ComplianceRule{
+	{
+		ID:    builtInComplianceRulePrefix + "whatsapp_prohibited_commerce",
+		Name:  "WhatsApp prohibited goods and services",
+		Check: entity.ComplianceCheckProhibitedTerms,
+		Terms: []string{
+			"casino", "gambling", "betting", "poker", "sportsbook",
+			"marijuana", "cannabis", "cocaine", "prescription drugs",
+			"firearms", "guns", "ammunition", "explosives",
+			"tobacco", "cigarettes", "vape", "e-cigarettes",
+			"escort", "adult content",
+		},
+		ChannelTypes: whatsAppComplianceChannelTypes,
+		Action:       entity.ComplianceActionBlock,
+		Message:      "WhatsApp's commerce policy does not allow promoting this category of goods or services",
+		Enabled:      true,
+		BuiltIn:      true,
+	},
+	{
+		ID:    builtInComplianceRulePrefix + "whatsapp_promotional_utility",
+		Name:  "Promotional content in utility or authentication templates",
+		Check: entity.ComplianceCheckProhibitedTerms,
+		Terms: []string{
+			"discount", "sale", "offer", "promo", "promotion", "coupon",
+			"free shipping", "limited time", "buy now", "deal",
+		},
+		ChannelTypes:       whatsAppComplianceChannelTypes,
+		TemplateCategories: []entity.TemplateCategory{entity.TemplateCategoryUtility, entity.TemplateCategoryAuthentication},
+		Action:             entity.ComplianceActionWarn,
+		Message:            "Meta moves templates with promotional content to the MARKETING category or rejects them",
+		Enabled:            true,
+		BuiltIn:            true,
+	},
+	{
+		ID:           builtInComplianceRulePrefix + "sms_opt_out_us",
+		Name:         "SMS opt-out instructions (US)",
+		Check:        entity.ComplianceCheckRequiredText,
+		Terms:        []string{"STOP", "opt out", "opt-out", "unsubscribe"},
+		Country:      "US",
+		ChannelTypes: []entity.ChannelType{entity.ChannelTypeSMS},
+		Action:       entity.ComplianceActionBlock,
+		Message:      "Marketing SMS to US recipients must say how to opt out, such as \"Reply STOP to unsubscribe\"",
+		Enabled:      true,
+		BuiltIn:      true,
+	},
+	{
+		ID:           builtInComplianceRulePrefix + "sms_opt_out_ca",
+		Name:         "SMS opt-out instructions (CA)",
+		Check:        entity.ComplianceCheckRequiredText,
+		Terms:        []string{"STOP", "opt out", "opt-out", "unsubscribe", "ARRET"},
+		Country:      "CA",
+		ChannelTypes: []entity.ChannelType{entity.ChannelTypeSMS},
+		Action:       entity.ComplianceActionBlock,
+		Message:      "Marketing SMS to Canadian recipients must say how to opt out, such as \"Reply STOP to unsubscribe\"",
+		Enabled:      true,
+		BuiltIn:      true,
+	},
+}
+
+// ComplianceContent is outbound marketing content to check against the compliance rules
+type ComplianceContent struct {
+	ChannelType      entity.ChannelType      `json:"channel_type" binding:"required"`
+	Country          string                  `json:"country,omitempty"`           // The tenant's phone region when empty
+	TemplateCategory entity.TemplateCategory `json:"template_category,omitempty"` // Set for WhatsApp templates
+	Text             string                  `json:"text" binding:"required"`
+}
+
+// ComplianceTarget identifies the content being submitted, for the override log
+type ComplianceTarget struct {
+	Type entity.ComplianceTargetType
+	ID   string
+	Name string
+}
+
+// ComplianceOverrideInput submits content despite blocking findings. UserID is
+// filled in by the API for users allowed to override.
+type ComplianceOverrideInput struct {
+	Reason string `json:"reason"`
+	UserID string `json:"-"`
+}
+
+// ComplianceRuleInput represents input for creating or updating a compliance rule
+type ComplianceRuleInput struct {
+	Name               string                    `json:"name" binding:"required"`
+	Check              entity.ComplianceCheck    `json:"check" binding:"required"`
+	Terms              []string                  `json:"terms" binding:"required"`
+	Country            string                    `json:"country,omitempty"`
+	ChannelTypes       []entity.ChannelType      `json:"channel_types,omitempty"`
+	TemplateCategories []entity.TemplateCategory `json:"template_categories,omitempty"`
+	Action             entity.ComplianceAction   `json:"action" binding:"required"`
+	Message            string                    `json:"message"`
+	Enabled            *bool                     `json:"enabled,omitempty"`
+}
+
+// ComplianceService checks campaign and template content against per-country rules
+// before it is submitted. Blocking findings stop the submission unless an authorized
+// user overrides them, which is logged; warnings let it through.
+type ComplianceService struct {
+	repo       repository.ComplianceRepository
+	tenantRepo repository.TenantRepository
+	now        func() time.Time
+}
+
+// NewComplianceService creates a new compliance service
+func NewComplianceService(repo repository.ComplianceRepository, tenantRepo repository.TenantRepository) *ComplianceService {
+	return &ComplianceService{
+		repo:       repo,
+		tenantRepo: tenantRepo,
+		now:        time.Now,
+	}
+}
+
+// Scan checks content against the built-in rules and the rules of the tenant
+func (s *ComplianceService) Scan(ctx context.Context, tenantID string, content *ComplianceContent) (*entity.ComplianceReport, error) {
+	rules, err := s.ListRules(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	country := strings.ToUpper(strings.TrimSpace(content.Country))
+	if country == "" {
+		country = s.tenantCountry(ctx, tenantID)
+	}
+
+	report := &entity.ComplianceReport{Country: country, Findings: []entity.ComplianceFinding{}}
+	for _, rule := range rules {
+		if !rule.AppliesTo(country, content.ChannelType, content.TemplateCategory) {
+			continue
+		}
+		finding, broken := checkComplianceRule(rule, content.Text)
+		if !broken {
+			continue
+		}
+		report.Findings = append(report.Findings, finding)
+		if rule.Action == entity.ComplianceActionBlock {
+			report.Blocked = true
+		}
+	}
+	return report, nil
+}
+
+// Enforce scans content about to be submitted. Blocking findings fail with a
+// validation error listing them, unless the content comes with an override, which
+// is recorded with who gave it and why.
+func (s *ComplianceService) Enforce(ctx context.Context, tenantID string, target *ComplianceTarget, content *ComplianceContent, override *ComplianceOverrideInput) (*entity.ComplianceReport, error) {
+	report, err := s.Scan(ctx, tenantID, content)
+	if err != nil {
+		return nil, err
+	}
+	if !report.Blocked {
+		return report, nil
+	}
+
+	if override == nil || strings.TrimSpace(override.Reason) == "" {
+		details := make(map[string]string)
+		for _, finding := range report.Findings {
+			if finding.Action == entity.ComplianceActionBlock {
+				details[finding.RuleID] = finding.Message
+			}
+		}
+		return nil, errors.Validation("content breaks compliance rules").WithDetails(details)
+	}
+	if override.UserID == "" {
+		return nil, errors.Forbidden("only admins can override compliance rules")
+	}
+
+	var blocking []entity.ComplianceFinding
+	for _, finding := range report.Findings {
+		if finding.Action == entity.ComplianceActionBlock {
+			blocking = append(blocking, finding)
+		}
+	}
+	record := &entity.ComplianceOverride{
+		ID:         uuid.New().String(),
+		TenantID:   tenantID,
+		TargetType: target.Type,
+		TargetID:   target.ID,
+		TargetName: target.Name,
+		UserID:     override.UserID,
+		Reason:     strings.TrimSpace(override.Reason),
+		Findings:   blocking,
+		CreatedAt:  s.now(),
+	}
+	if err := s.repo.CreateOverride(ctx, record); err != nil {
+		return nil, err
+	}
+	report.Overridden = true
+	return report, nil
+}
+
+// ListRules returns the built-in rules followed by the rules of the tenant
+func (s *ComplianceService) ListRules(ctx context.Context, tenantID string) ([]*entity.ComplianceRule, error) {
+	rules, err := s.repo.ListRules(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]*entity.ComplianceRule{}, builtInComplianceRules...), rules...), nil
+}
+
+// CreateRule adds a rule for the tenant
+func (s *ComplianceService) CreateRule(ctx context.Context, tenantID string, input *ComplianceRuleInput) (*entity.ComplianceRule, error) {
+	now := s.now()
+	rule := &entity.ComplianceRule{
+		ID:        uuid.New().String(),
+		TenantID:  tenantID,
+		Enabled:   true,
+		CreatedAt: now,
+	}
+	if err := applyComplianceRuleInput(rule, input); err != nil {
+		return nil, err
+	}
+	rule.UpdatedAt = now
+
+	if err := s.repo.CreateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// UpdateRule updates a rule of the tenant. Built-in rules cannot be changed.
+func (s *ComplianceService) UpdateRule(ctx context.Context, tenantID, id string, input *ComplianceRuleInput) (*entity.ComplianceRule, error) {
+	rule, err := s.getRule(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyComplianceRuleInput(rule, input); err != nil {
+		return nil, err
+	}
+	rule.UpdatedAt = s.now()
+
+	if err := s.repo.UpdateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteRule deletes a rule of the tenant. Built-in rules cannot be deleted.
+func (s *ComplianceService) DeleteRule(ctx context.Context, tenantID, id string) error {
+	if _, err := s.getRule(ctx, tenantID, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteRule(ctx, id)
+}
+
+// ListOverrides returns the log of compliance overrides of the tenant, newest first
+func (s *ComplianceService) ListOverrides(ctx context.Context, tenantID string, params *repository.ListParams) ([]*entity.ComplianceOverride, int64, error) {
+	return s.repo.ListOverrides(ctx, tenantID, params)
+}
+
+func (s *ComplianceService) getRule(ctx context.Context, tenantID, id string) (*entity.ComplianceRule, error) {
+	if strings.HasPrefix(id, builtInComplianceRulePrefix) {
+		return nil, errors.Validation("built-in compliance rules cannot be changed")
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, errors.NotFound("compliance rule")
+	}
+	rule, err := s.repo.FindRuleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rule.TenantID != tenantID {
+		return nil, errors.NotFound("compliance rule")
+	}
+	return rule, nil
+}
+
+// tenantCountry returns the tenant's phone region, "" when unset or unknown
+func (s *ComplianceService) tenantCountry(ctx context.Context, tenantID string) string {
+	if s.tenantRepo == nil || tenantID == "" {
+		return ""
+	}
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return ""
+	}
+	return tenant.PhoneRegion()
+}
+
+// applyComplianceRuleInput validates an input and copies it onto a rule
+func applyComplianceRuleInput(rule *entity.ComplianceRule, input *ComplianceRuleInput) error {
+	if strings.TrimSpace(input.Name) == "" {
+		return errors.Validation("name is required")
+	}
+	if !input.Check.IsValid() {
+		return errors.Validation("check must be prohibited_terms or required_text")
+	}
+	if !input.Action.IsValid() {
+		return errors.Validation("action must be block or warn")
+	}
+	var terms []string
+	for _, term := range input.Terms {
+		if term = strings.TrimSpace(term); term != "" {
+			terms = append(terms, term)
+		}
+	}
+	if len(terms) == 0 {
+		return errors.Validation("at least one term is required")
+	}
+	country := strings.ToUpper(strings.TrimSpace(input.Country))
+	if country != "" && len(country) != 2 {
+		return errors.Validation("country must be an ISO 3166-1 alpha-2 code")
+	}
+	for _, category := range input.TemplateCategories {
+		switch category {
+		case entity.TemplateCategoryMarketing, entity.TemplateCategoryUtility, entity.TemplateCategoryAuthentication:
+		default:
+			return errors.Validation("unknown template category: " + string(category))
+		}
+	}
+
+	rule.Name = strings.TrimSpace(input.Name)
+	rule.Check = input.Check
+	rule.Terms = terms
+	rule.Country = country
+	rule.ChannelTypes = input.ChannelTypes
+	rule.TemplateCategories = input.TemplateCategories
+	rule.Action = input.Action
+	rule.Message = strings.TrimSpace(input.Message)
+	if rule.Message == "" {
+		rule.Message = rule.Name
+	}
+	if input.Enabled != nil {
+		rule.Enabled = *input.Enabled
+	}
+	return nil
+}
+
+// checkComplianceRule reports whether text breaks a rule
+func checkComplianceRule(rule *entity.ComplianceRule, text string) (entity.ComplianceFinding, bool) {
+	finding := entity.ComplianceFinding{
+		RuleID:   rule.ID,
+		RuleName: rule.Name,
+		Action:   rule.Action,
+		Message:  rule.Message,
+	}
+	switch rule.Check {
+	case entity.ComplianceCheckProhibitedTerms:
+		for _, term := range rule.Terms {
+			if match := matchComplianceTerm(term, text); match != "" {
+				finding.Match = match
+				return finding, true
+			}
+		}
+	case entity.ComplianceCheckRequiredText:
+		for _, term := range rule.Terms {
+			if matchComplianceTerm(term, text) != "" {
+				return finding, false
+			}
+		}
+		return finding, true
+	}
+	return finding, false
+}
+
+// matchComplianceTerm returns the text matching term as a whole word, ignoring case,
+// or "" when it does not occur
+func matchComplianceTerm(term, text string) string {
+	pattern := regexp.MustCompile(`(?i)(?:^|[^\p{L}\p{N}])(` + regexp.QuoteMeta(term) + `)(?:$|[^\p{L}\p{N}])`)
+	match := pattern.FindStringSubmatch(text)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// templateComplianceText returns the text of a template's components, buttons and
+// carousel cards
+func templateComplianceText(components []entity.TemplateComponent) string {
+	var parts []string
+	for _, component := range components {
+		if component.Text != "" {
+			parts = append(parts, component.Text)
+		}
+		for _, button := range component.Buttons {
+			if button.Text != "" {
+				parts = append(parts, button.Text)
+			}
+		}
+		for _, card := range component.Cards {
+			if text := templateComplianceText(card.Components); text != "" {
+				parts = append(parts, text)
+			}
+		}
+		if component.LimitedTimeOffer != nil && component.LimitedTimeOffer.Text != "" {
+			parts = append(parts, component.LimitedTimeOffer.Text)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,311 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockComplianceRepo struct {
+	mu        sync.Mutex
+	rules     map[string]*entity.ComplianceRule
+	overrides []*entity.ComplianceOverride
+}
+
+func newMockComplianceRepo() *mockComplianceRepo {
+	return &mockComplianceRepo{rules: map[string]*entity.ComplianceRule{}}
+}
+
+func (m *mockComplianceRepo) CreateRule(ctx context.Context, rule *entity.ComplianceRule) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rules[rule.ID] = rule
+	return nil
+}
+
+func (m *mockComplianceRepo) UpdateRule(ctx context.Context, rule *entity.ComplianceRule) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rules[rule.ID] = rule
+	return nil
+}
+
+func (m *mockComplianceRepo) DeleteRule(ctx context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.rules, id)
+	return nil
+}
+
+func (m *mockComplianceRepo) FindRuleByID(ctx context.Context, id string) (*entity.ComplianceRule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	rule, ok := m.rules[id]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, "compliance rule not found")
+	}
+	return rule, nil
+}
+
+func (m *mockComplianceRepo) ListRules(ctx context.Context, tenantID string) ([]*entity.ComplianceRule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var rules []*entity.ComplianceRule
+	for _, rule := range m.rules {
+		if rule.TenantID == tenantID {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func (m *mockComplianceRepo) CreateOverride(ctx context.Context, override *entity.ComplianceOverride) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.overrides = append(m.overrides, override)
+	return nil
+}
+
+func (m *mockComplianceRepo) ListOverrides(ctx context.Context, tenantID string, params *repository.ListParams) ([]*entity.ComplianceOverride, int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var overrides []*entity.ComplianceOverride
+	for _, override := range m.overrides {
+		if override.TenantID == tenantID {
+			overrides = append(overrides, override)
+		}
+	}
+	return overrides, int64(len(overrides)), nil
+}
+
+func newComplianceTestService() (*ComplianceService, *mockComplianceRepo) {
+	tenants := testutil.NewMockTenantRepository()
+	tenants.Tenants["tenant-1"] = &entity.Tenant{ID: "tenant-1", Settings: map[string]string{entity.TenantSettingPhoneRegion: "us"}}
+	tenants.Tenants["tenant-2"] = &entity.Tenant{ID: "tenant-2", Settings: map[string]string{entity.TenantSettingPhoneRegion: "BR"}}
+	repo := newMockComplianceRepo()
+	return NewComplianceService(repo, tenants), repo
+}
+
+func findingRules(report *entity.ComplianceReport) []string {
+	var ids []string
+	for _, finding := range report.Findings {
+		ids = append(ids, finding.RuleID)
+	}
+	return ids
+}
+
+func TestComplianceService_ScanBuiltInRules(t *testing.T) {
+	svc, _ := newComplianceTestService()
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		tenantID string
+		content  *ComplianceContent
+		want     []string
+		blocked  bool
+	}{
+		{
+			name:     "prohibited goods on WhatsApp",
+			tenantID: "tenant-2",
+			content:  &ComplianceContent{ChannelType: entity.ChannelTypeWhatsAppOfficial, Text: "Bet on tonight's game at our Casino!"},
+			want:     []string{"builtin:whatsapp_prohibited_commerce"},
+			blocked:  true,
+		},
+		{
+			name:     "terms only match whole words",
+			tenantID: "tenant-2",
+			content:  &ComplianceContent{ChannelType: entity.ChannelTypeWhatsAppOfficial, Text: "Your Gunsmith order has shipped"},
+		},
+		{
+			name:     "promotional utility template",
+			tenantID: "tenant-2",
+			content: &ComplianceContent{
+				ChannelType:      entity.ChannelTypeWhatsAppOfficial,
+				TemplateCategory: entity.TemplateCategoryUtility,
+				Text:             "Your order shipped. Use code SAVE10 for a discount on your next one",
+			},
+			want: []string{"builtin:whatsapp_promotional_utility"},
+		},
+		{
+			name:     "promotions are fine in marketing templates",
+			tenantID: "tenant-2",
+			content: &ComplianceContent{
+				ChannelType:      entity.ChannelTypeWhatsAppOfficial,
+				TemplateCategory: entity.TemplateCategoryMarketing,
+				Text:             "Big discount this weekend",
+			},
+		},
+		{
+			name:     "US SMS without opt-out from the tenant's region",
+			tenantID: "tenant-1",
+			content:  &ComplianceContent{ChannelType: entity.ChannelTypeSMS, Text: "Big discount this weekend"},
+			want:     []string{"builtin:sms_opt_out_us"},
+			blocked:  true,
+		},
+		{
+			name:     "US SMS with opt-out",
+			tenantID: "tenant-1",
+			content:  &ComplianceContent{ChannelType: entity.ChannelTypeSMS, Text: "Big discount this weekend. Reply stop to unsubscribe"},
+		},
+		{
+			name:     "SMS to a country without opt-out rules",
+			tenantID: "tenant-1",
+			content:  &ComplianceContent{ChannelType: entity.ChannelTypeSMS, Country: "br", Text: "Big discount this weekend"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := svc.Scan(ctx, tt.tenantID, tt.content)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, findingRules(report))
+			assert.Equal(t, tt.blocked, report.Blocked)
+		})
+	}
+
+	report, err := svc.Scan(ctx, "tenant-2", &ComplianceContent{ChannelType: entity.ChannelTypeWhatsApp, Text: "Visit our casino"})
+	require.NoError(t, err)
+	assert.Equal(t, "BR", report.Country)
+	assert.Equal(t, "casino", report.Findings[0].Match)
+}
+
+func TestComplianceService_Enforce(t *testing.T) {
+	svc, repo := newComplianceTestService()
+	ctx := context.Background()
+	target := &ComplianceTarget{Type: entity.ComplianceTargetNewsletter, ID: "newsletter-1", Name: "Weekend"}
+	content := &ComplianceContent{ChannelType: entity.ChannelTypeSMS, Text: "Big discount this weekend"}
+
+	_, err := svc.Enforce(ctx, "tenant-1", target, content, nil)
+	require.Error(t, err)
+	assert.True(t, errors.IsValidation(err))
+	assert.Contains(t, errors.GetAppError(err).Details, "builtin:sms_opt_out_us")
+
+	_, err = svc.Enforce(ctx, "tenant-1", target, content, &ComplianceOverrideInput{Reason: "Transactional list"})
+	require.Error(t, err, "overrides need an authorized user")
+	assert.Equal(t, errors.ErrCodeForbidden, errors.GetAppError(err).Code)
+	assert.Empty(t, repo.overrides)
+
+	report, err := svc.Enforce(ctx, "tenant-1", target, content, &ComplianceOverrideInput{Reason: " Transactional list ", UserID: "admin-1"})
+	require.NoError(t, err)
+	assert.True(t, report.Overridden)
+	require.Len(t, repo.overrides, 1)
+	override := repo.overrides[0]
+	assert.Equal(t, "Transactional list", override.Reason)
+	assert.Equal(t, "admin-1", override.UserID)
+	assert.Equal(t, "newsletter-1", override.TargetID)
+	assert.Equal(t, "builtin:sms_opt_out_us", override.Findings[0].RuleID)
+
+	content.Text = "Big discount this weekend. Reply STOP to opt out"
+	report, err = svc.Enforce(ctx, "tenant-1", target, content, nil)
+	require.NoError(t, err)
+	assert.False(t, report.Blocked)
+	assert.Len(t, repo.overrides, 1)
+}
+
+func TestComplianceService_TenantRules(t *testing.T) {
+	svc, repo := newComplianceTestService()
+	ctx := context.Background()
+
+	_, err := svc.CreateRule(ctx, "tenant-1", &ComplianceRuleInput{Name: "Crypto", Check: "unknown", Terms: []string{"crypto"}, Action: entity.ComplianceActionBlock})
+	assert.Error(t, err)
+	_, err = svc.CreateRule(ctx, "tenant-1", &ComplianceRuleInput{Name: "Crypto", Check: entity.ComplianceCheckProhibitedTerms, Terms: []string{" "}, Action: entity.ComplianceActionBlock})
+	assert.Error(t, err)
+
+	rule, err := svc.CreateRule(ctx, "tenant-1", &ComplianceRuleInput{
+		Name:         "Crypto promotions",
+		Check:        entity.ComplianceCheckProhibitedTerms,
+		Terms:        []string{"crypto", " bitcoin "},
+		Country:      "br",
+		ChannelTypes: []entity.ChannelType{entity.ChannelTypeTelegram},
+		Action:       entity.ComplianceActionWarn,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "BR", rule.Country)
+	assert.Equal(t, []string{"crypto", "bitcoin"}, rule.Terms)
+	assert.Equal(t, "Crypto promotions", rule.Message)
+	assert.True(t, rule.Enabled)
+
+	content := &ComplianceContent{ChannelType: entity.ChannelTypeTelegram, Country: "BR", Text: "Buy Bitcoin now"}
+	report, err := svc.Scan(ctx, "tenant-1", content)
+	require.NoError(t, err)
+	assert.Equal(t, []string{rule.ID}, findingRules(report))
+	assert.Len(t, report.Warnings(), 1)
+	assert.False(t, report.Blocked)
+
+	report, err = svc.Scan(ctx, "tenant-2", content)
+	require.NoError(t, err)
+	assert.Empty(t, report.Findings, "rules only apply to their tenant")
+
+	disabled := false
+	_, err = svc.UpdateRule(ctx, "tenant-1", rule.ID, &ComplianceRuleInput{
+		Name:    rule.Name,
+		Check:   rule.Check,
+		Terms:   rule.Terms,
+		Action:  entity.ComplianceActionBlock,
+		Enabled: &disabled,
+	})
+	require.NoError(t, err)
+	report, err = svc.Scan(ctx, "tenant-1", content)
+	require.NoError(t, err)
+	assert.Empty(t, report.Findings)
+
+	_, err = svc.UpdateRule(ctx, "tenant-2", rule.ID, &ComplianceRuleInput{Name: "x", Check: rule.Check, Terms: rule.Terms, Action: rule.Action})
+	assert.True(t, errors.IsNotFound(err))
+	assert.Error(t, svc.DeleteRule(ctx, "tenant-1", "builtin:sms_opt_out_us"))
+
+	require.NoError(t, svc.DeleteRule(ctx, "tenant-1", rule.ID))
+	assert.Empty(t, repo.rules)
+
+	rules, err := svc.ListRules(ctx, "tenant-1")
+	require.NoError(t, err)
+	assert.Len(t, rules, len(builtInComplianceRules))
+}
+
+func TestTemplateComplianceText(t *testing.T) {
+	text := templateComplianceText([]entity.TemplateComponent{
+		{Type: "HEADER", Format: "TEXT", Text: "Hello"},
+		{Type: "BODY", Text: "Body {{1}}"},
+		{Type: "BUTTONS", Buttons: []entity.TemplateButton{{Type: "QUICK_REPLY", Text: "Stop promotions"}}},
+		{Type: "CAROUSEL", Cards: []entity.TemplateCarouselCard{{Components: []entity.TemplateComponent{{Type: "BODY", Text: "Card"}}}}},
+		{Type: "LIMITED_TIME_OFFER", LimitedTimeOffer: &entity.TemplateLimitedTimeOffer{Text: "Expiring"}},
+	})
+	assert.Equal(t, "Hello\nBody {{1}}\nStop promotions\nCard\nExpiring", text)
+}
+
+func TestNewsletterService_Compliance(t *testing.T) {
+	f := newNewsletterFixture()
+	ctx := context.Background()
+	compliance, repo := newComplianceTestService()
+	f.service.SetComplianceService(compliance)
+
+	_, err := compliance.CreateRule(ctx, "tenant-1", &ComplianceRuleInput{
+		Name:   "Crypto promotions",
+		Check:  entity.ComplianceCheckProhibitedTerms,
+		Terms:  []string{"crypto"},
+		Action: entity.ComplianceActionBlock,
+	})
+	require.NoError(t, err)
+
+	input := weeklyNewsletterInput()
+	input.Content = "This week's crypto deals"
+	_, err = f.service.Create(ctx, "tenant-1", input)
+	require.Error(t, err)
+	assert.True(t, errors.IsValidation(err))
+
+	input.ComplianceOverride = &ComplianceOverrideInput{Reason: "Approved by legal", UserID: "admin-1"}
+	newsletter, err := f.service.Create(ctx, "tenant-1", input)
+	require.NoError(t, err)
+	require.Len(t, repo.overrides, 1)
+	assert.Equal(t, newsletter.ID, repo.overrides[0].TargetID)
+
+	_, err = f.service.SendNow(ctx, "tenant-1", newsletter.ID, &SendEditionInput{Content: "More crypto deals"})
+	require.Error(t, err, "edition content is checked too")
+	assert.True(t, errors.IsValidation(err))
+}
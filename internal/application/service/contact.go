@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -31,23 +32,56 @@ type UpdateContactInput struct {
 	Tags         []string
 }
 
+// ContactFieldValidator checks custom field values against the tenant's contact field
+// definitions
+type ContactFieldValidator interface {
+	ApplyValues(ctx context.Context, tenantID string, values map[string]string) error
+	NormalizeFilter(ctx context.Context, tenantID string, filter map[string]string) error
+}
+
 // ContactService handles contact operations
 type ContactService struct {
-	contactRepo repository.ContactRepository
+	contactRepo    repository.ContactRepository
+	contacts       *repository.TenantScope[entity.Contact]
+	fieldValidator ContactFieldValidator
+	identifiers    *IdentifierService
 }
 
 // NewContactService creates a new contact service
 func NewContactService(contactRepo repository.ContactRepository) *ContactService {
 	return &ContactService{
 		contactRepo: contactRepo,
+		contacts:    repository.TenantContacts(contactRepo, contactNotFound),
 	}
 }
 
+// contactNotFound is the error of missing contacts and of those of other tenants
+func contactNotFound() error {
+	return errors.New(errors.ErrCodeContactNotFound, "contact not found")
+}
+
+// SetFieldValidator validates and normalizes custom field values against the tenant's
+// contact field definitions
+func (s *ContactService) SetFieldValidator(validator ContactFieldValidator) {
+	s.fieldValidator = validator
+}
+
+// SetIdentifierService validates and normalizes contact phones, emails and channel
+// identifiers
+func (s *ContactService) SetIdentifierService(identifiers *IdentifierService) {
+	s.identifiers = identifiers
+}
+
 // List returns all contacts for a tenant
 func (s *ContactService) List(ctx context.Context, tenantID string, params *repository.ListParams) ([]*entity.Contact, int64, error) {
 	if params == nil {
 		params = repository.NewListParams()
 	}
+	if filter, ok := params.Filters["custom_fields"].(map[string]string); ok && s.fieldValidator != nil {
+		if err := s.fieldValidator.NormalizeFilter(ctx, tenantID, filter); err != nil {
+			return nil, 0, err
+		}
+	}
 	return s.contactRepo.FindByTenant(ctx, tenantID, params)
 }
 
@@ -56,6 +90,9 @@ func (s *ContactService) Create(ctx context.Context, input *CreateContactInput)
 	if input.Name == "" {
 		return nil, errors.Validation("contact name is required")
 	}
+	if err := s.normalizeContactInput(ctx, input.TenantID, &input.Email, &input.Phone); err != nil {
+		return nil, err
+	}
 
 	// Check for duplicate email within tenant
 	if input.Email != "" {
@@ -73,6 +110,16 @@ func (s *ContactService) Create(ctx context.Context, input *CreateContactInput)
 		}
 	}
 
+	customFields := input.CustomFields
+	if customFields == nil {
+		customFields = make(map[string]string)
+	}
+	if s.fieldValidator != nil {
+		if err := s.fieldValidator.ApplyValues(ctx, input.TenantID, customFields); err != nil {
+			return nil, err
+		}
+	}
+
 	now := time.Now()
 	contact := &entity.Contact{
 		ID:           uuid.New().String(),
@@ -81,7 +128,7 @@ func (s *ContactService) Create(ctx context.Context, input *CreateContactInput)
 		Email:        input.Email,
 		Phone:        input.Phone,
 		AvatarURL:    input.AvatarURL,
-		CustomFields: input.CustomFields,
+		CustomFields: customFields,
 		Tags:         input.Tags,
 		CreatedAt:    now,
 		UpdatedAt:    now,
@@ -94,11 +141,11 @@ func (s *ContactService) Create(ctx context.Context, input *CreateContactInput)
 	return contact, nil
 }
 
-// GetByID returns a contact by ID
-func (s *ContactService) GetByID(ctx context.Context, id string) (*entity.Contact, error) {
-	contact, err := s.contactRepo.FindByID(ctx, id)
+// GetByID returns a contact of a tenant by ID
+func (s *ContactService) GetByID(ctx context.Context, tenantID, id string) (*entity.Contact, error) {
+	contact, err := s.contacts.FindByID(ctx, tenantID, id)
 	if err != nil {
-		return nil, errors.New(errors.ErrCodeContactNotFound, "contact not found")
+		return nil, err
 	}
 
 	// Load identities
@@ -111,15 +158,18 @@ func (s *ContactService) GetByID(ctx context.Context, id string) (*entity.Contac
 }
 
 // Update updates a contact
-func (s *ContactService) Update(ctx context.Context, id string, input *UpdateContactInput) (*entity.Contact, error) {
-	contact, err := s.contactRepo.FindByID(ctx, id)
+func (s *ContactService) Update(ctx context.Context, tenantID, id string, input *UpdateContactInput) (*entity.Contact, error) {
+	contact, err := s.contacts.FindByID(ctx, tenantID, id)
 	if err != nil {
-		return nil, errors.New(errors.ErrCodeContactNotFound, "contact not found")
+		return nil, err
 	}
 
 	if input.Name != nil {
 		contact.Name = *input.Name
 	}
+	if err := s.normalizeContactInput(ctx, contact.TenantID, input.Email, input.Phone); err != nil {
+		return nil, err
+	}
 	if input.Email != nil {
 		contact.Email = *input.Email
 	}
@@ -130,6 +180,11 @@ func (s *ContactService) Update(ctx context.Context, id string, input *UpdateCon
 		contact.AvatarURL = *input.AvatarURL
 	}
 	if input.CustomFields != nil {
+		if s.fieldValidator != nil {
+			if err := s.fieldValidator.ApplyValues(ctx, contact.TenantID, input.CustomFields); err != nil {
+				return nil, err
+			}
+		}
 		contact.CustomFields = input.CustomFields
 	}
 	if input.Tags != nil {
@@ -145,10 +200,9 @@ func (s *ContactService) Update(ctx context.Context, id string, input *UpdateCon
 }
 
 // Delete deletes a contact
-func (s *ContactService) Delete(ctx context.Context, id string) error {
-	_, err := s.contactRepo.FindByID(ctx, id)
-	if err != nil {
-		return errors.New(errors.ErrCodeContactNotFound, "contact not found")
+func (s *ContactService) Delete(ctx context.Context, tenantID, id string) error {
+	if _, err := s.contacts.FindByID(ctx, tenantID, id); err != nil {
+		return err
 	}
 
 	if err := s.contactRepo.Delete(ctx, id); err != nil {
@@ -159,10 +213,27 @@ func (s *ContactService) Delete(ctx context.Context, id string) error {
 }
 
 // AddIdentity adds an identity to a contact
-func (s *ContactService) AddIdentity(ctx context.Context, contactID, channelType, identifier string, metadata map[string]string) (*entity.Contact, error) {
-	contact, err := s.contactRepo.FindByID(ctx, contactID)
+func (s *ContactService) AddIdentity(ctx context.Context, tenantID, contactID, channelType, identifier string, metadata map[string]string) (*entity.Contact, error) {
+	contact, err := s.contacts.FindByID(ctx, tenantID, contactID)
 	if err != nil {
-		return nil, errors.New(errors.ErrCodeContactNotFound, "contact not found")
+		return nil, err
+	}
+
+	if s.identifiers != nil {
+		normalized, err := s.identifiers.NormalizeIdentifier(ctx, contact.TenantID, entity.ChannelType(channelType), identifier)
+		if err != nil {
+			return nil, err
+		}
+		// Keep the username a Telegram identity was added by for later lookups
+		if channelType == string(entity.ChannelTypeTelegram) && normalized != identifier {
+			if metadata == nil {
+				metadata = make(map[string]string)
+			}
+			if metadata["username"] == "" {
+				metadata["username"] = strings.TrimPrefix(strings.TrimSpace(identifier), "@")
+			}
+		}
+		identifier = normalized
 	}
 
 	identity := &entity.ContactIdentity{
@@ -188,10 +259,10 @@ func (s *ContactService) AddIdentity(ctx context.Context, contactID, channelType
 }
 
 // RemoveIdentity removes an identity from a contact
-func (s *ContactService) RemoveIdentity(ctx context.Context, contactID, identityID string) (*entity.Contact, error) {
-	contact, err := s.contactRepo.FindByID(ctx, contactID)
+func (s *ContactService) RemoveIdentity(ctx context.Context, tenantID, contactID, identityID string) (*entity.Contact, error) {
+	contact, err := s.contacts.FindByID(ctx, tenantID, contactID)
 	if err != nil {
-		return nil, errors.New(errors.ErrCodeContactNotFound, "contact not found")
+		return nil, err
 	}
 
 	if err := s.contactRepo.RemoveIdentity(ctx, contactID, identityID); err != nil {
@@ -208,10 +279,10 @@ func (s *ContactService) RemoveIdentity(ctx context.Context, contactID, identity
 }
 
 // BlockContact blocks a contact
-func (s *ContactService) BlockContact(ctx context.Context, contactID string) error {
-	contact, err := s.contactRepo.FindByID(ctx, contactID)
+func (s *ContactService) BlockContact(ctx context.Context, tenantID, contactID string) error {
+	contact, err := s.contacts.FindByID(ctx, tenantID, contactID)
 	if err != nil {
-		return errors.New(errors.ErrCodeContactNotFound, "contact not found")
+		return err
 	}
 
 	contact.Block()
@@ -224,10 +295,10 @@ func (s *ContactService) BlockContact(ctx context.Context, contactID string) err
 }
 
 // UnblockContact unblocks a contact
-func (s *ContactService) UnblockContact(ctx context.Context, contactID string) error {
-	contact, err := s.contactRepo.FindByID(ctx, contactID)
+func (s *ContactService) UnblockContact(ctx context.Context, tenantID, contactID string) error {
+	contact, err := s.contacts.FindByID(ctx, tenantID, contactID)
 	if err != nil {
-		return errors.New(errors.ErrCodeContactNotFound, "contact not found")
+		return err
 	}
 
 	contact.Unblock()
@@ -240,11 +311,34 @@ func (s *ContactService) UnblockContact(ctx context.Context, contactID string) e
 }
 
 // IsBlocked checks if a contact is blocked
-func (s *ContactService) IsBlocked(ctx context.Context, contactID string) (bool, error) {
-	contact, err := s.contactRepo.FindByID(ctx, contactID)
+func (s *ContactService) IsBlocked(ctx context.Context, tenantID, contactID string) (bool, error) {
+	contact, err := s.contacts.FindByID(ctx, tenantID, contactID)
 	if err != nil {
-		return false, errors.New(errors.ErrCodeContactNotFound, "contact not found")
+		return false, err
 	}
 
 	return contact.IsBlocked(), nil
 }
+
+// normalizeContactInput validates and normalizes the email and phone a contact is
+// created or updated with; empty values clear the field and are left alone
+func (s *ContactService) normalizeContactInput(ctx context.Context, tenantID string, email, phone *string) error {
+	if s.identifiers == nil {
+		return nil
+	}
+	if email != nil && *email != "" {
+		normalized, err := s.identifiers.NormalizeEmail(*email)
+		if err != nil {
+			return err
+		}
+		*email = normalized
+	}
+	if phone != nil && *phone != "" {
+		normalized, err := s.identifiers.NormalizePhone(ctx, tenantID, *phone)
+		if err != nil {
+			return err
+		}
+		*phone = normalized
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// ContactFieldInput represents input for creating or updating a custom contact field.
+// The key is only read on creation.
+type ContactFieldInput struct {
+	Key         string                  `json:"key"`
+	Label       string                  `json:"label" binding:"required"`
+	Description string                  `json:"description,omitempty"`
+	Type        entity.ContactFieldType `json:"type" binding:"required"`
+	Required    bool                    `json:"required"`
+	Options     []string                `json:"options,omitempty"`
+	Pattern     string                  `json:"pattern,omitempty"`
+	Min         *float64                `json:"min,omitempty"`
+	Max         *float64                `json:"max,omitempty"`
+}
+
+// ContactFieldService manages the custom fields tenants define for their contacts and
+// validates contact values against them
+type ContactFieldService struct {
+	repo             repository.ContactFieldRepository
+	contactRepo      repository.ContactRepository
+	conversationRepo repository.ConversationRepository
+}
+
+// NewContactFieldService creates a new contact field service
+func NewContactFieldService(repo repository.ContactFieldRepository, contactRepo repository.ContactRepository, conversationRepo repository.ConversationRepository) *ContactFieldService {
+	return &ContactFieldService{
+		repo:             repo,
+		contactRepo:      contactRepo,
+		conversationRepo: conversationRepo,
+	}
+}
+
+// Create creates a new contact field. Keys are unique per tenant.
+func (s *ContactFieldService) Create(ctx context.Context, tenantID string, input *ContactFieldInput) (*entity.ContactField, error) {
+	now := time.Now()
+	field := &entity.ContactField{
+		ID:        uuid.New().String(),
+		TenantID:  tenantID,
+		Key:       strings.TrimSpace(input.Key),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	applyContactFieldInput(field, input)
+	if err := field.Validate(); err != nil {
+		return nil, errors.Validation(err.Error())
+	}
+
+	fields, err := s.repo.FindByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range fields {
+		if existing.Key == field.Key {
+			return nil, errors.Conflict("a contact field with this key already exists")
+		}
+	}
+
+	if err := s.repo.Create(ctx, field); err != nil {
+		return nil, err
+	}
+	return field, nil
+}
+
+// Get returns a contact field of the tenant
+func (s *ContactFieldService) Get(ctx context.Context, tenantID, id string) (*entity.ContactField, error) {
+	field, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if field.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeNotFound, "contact field not found")
+	}
+	return field, nil
+}
+
+// List lists the contact fields of a tenant
+func (s *ContactFieldService) List(ctx context.Context, tenantID string) ([]*entity.ContactField, error) {
+	fields, err := s.repo.FindByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if fields == nil {
+		fields = []*entity.ContactField{}
+	}
+	return fields, nil
+}
+
+// Update updates a contact field. Existing contact values are only checked against
+// the new definition the next time they are saved.
+func (s *ContactFieldService) Update(ctx context.Context, tenantID, id string, input *ContactFieldInput) (*entity.ContactField, error) {
+	field, err := s.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if input.Key != "" && strings.TrimSpace(input.Key) != field.Key {
+		return nil, errors.Validation("the key of a contact field cannot be changed")
+	}
+
+	applyContactFieldInput(field, input)
+	if err := field.Validate(); err != nil {
+		return nil, errors.Validation(err.Error())
+	}
+	field.UpdatedAt = time.Now()
+
+	if err := s.repo.Update(ctx, field); err != nil {
+		return nil, err
+	}
+	return field, nil
+}
+
+// Delete deletes a contact field. Values stored on contacts are kept as plain custom
+// fields.
+func (s *ContactFieldService) Delete(ctx context.Context, tenantID, id string) error {
+	if _, err := s.Get(ctx, tenantID, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// ApplyValues validates custom field values against the tenant's fields, normalizing
+// them in place
+func (s *ContactFieldService) ApplyValues(ctx context.Context, tenantID string, values map[string]string) error {
+	fields, err := s.repo.FindByTenant(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if err := entity.ApplyContactFields(fields, values); err != nil {
+		return errors.Validation(err.Error())
+	}
+	return nil
+}
+
+// NormalizeFilter normalizes the values of a custom field filter so they compare equal
+// to stored values, e.g. "1" to "true" for boolean fields
+func (s *ContactFieldService) NormalizeFilter(ctx context.Context, tenantID string, filter map[string]string) error {
+	fields, err := s.repo.FindByTenant(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		value, ok := filter[field.Key]
+		if !ok {
+			continue
+		}
+		normalized, err := field.NormalizeValue(value)
+		if err != nil {
+			return errors.Validation(err.Error())
+		}
+		filter[field.Key] = normalized
+	}
+	return nil
+}
+
+// ConversationContact returns the contact of a conversation. It lets flow condition
+// nodes branch on the contact's custom fields.
+func (s *ContactFieldService) ConversationContact(ctx context.Context, conversationID string) (*entity.Contact, error) {
+	conversation, err := s.conversationRepo.FindByID(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	return s.contactRepo.FindByID(ctx, conversation.ContactID)
+}
+
+func applyContactFieldInput(field *entity.ContactField, input *ContactFieldInput) {
+	field.Label = strings.TrimSpace(input.Label)
+	field.Description = input.Description
+	field.Type = input.Type
+	field.Required = input.Required
+	field.Options = input.Options
+	field.Pattern = input.Pattern
+	field.Min = input.Min
+	field.Max = input.Max
+}
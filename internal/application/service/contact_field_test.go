@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockContactFieldRepo keeps contact fields in memory
+type mockContactFieldRepo struct {
+	fields map[string]*entity.ContactField
+}
+
+func newMockContactFieldRepo() *mockContactFieldRepo {
+	return &mockContactFieldRepo{fields: make(map[string]*entity.ContactField)}
+}
+
+func (m *mockContactFieldRepo) Create(ctx context.Context, field *entity.ContactField) error {
+	m.fields[field.ID] = field
+	return nil
+}
+
+func (m *mockContactFieldRepo) FindByID(ctx context.Context, id string) (*entity.ContactField, error) {
+	field, ok := m.fields[id]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, "contact field not found")
+	}
+	return field, nil
+}
+
+func (m *mockContactFieldRepo) FindByTenant(ctx context.Context, tenantID string) ([]*entity.ContactField, error) {
+	var result []*entity.ContactField
+	for _, field := range m.fields {
+		if field.TenantID == tenantID {
+			result = append(result, field)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockContactFieldRepo) Update(ctx context.Context, field *entity.ContactField) error {
+	m.fields[field.ID] = field
+	return nil
+}
+
+func (m *mockContactFieldRepo) Delete(ctx context.Context, id string) error {
+	delete(m.fields, id)
+	return nil
+}
+
+func newContactFieldTestEnv(t *testing.T) (*ContactFieldService, *testutil.MockContactRepository, *testutil.MockConversationRepository) {
+	contacts := testutil.NewMockContactRepository()
+	conversations := testutil.NewMockConversationRepository()
+	svc := NewContactFieldService(newMockContactFieldRepo(), contacts, conversations)
+
+	ctx := context.Background()
+	_, err := svc.Create(ctx, "tenant-1", &ContactFieldInput{Key: "plan", Label: "Plan", Type: entity.ContactFieldSelect, Options: []string{"free", "pro"}, Required: true})
+	require.NoError(t, err)
+	_, err = svc.Create(ctx, "tenant-1", &ContactFieldInput{Key: "orders", Label: "Orders", Type: entity.ContactFieldNumber})
+	require.NoError(t, err)
+	_, err = svc.Create(ctx, "tenant-1", &ContactFieldInput{Key: "vip", Label: "VIP", Type: entity.ContactFieldBoolean})
+	require.NoError(t, err)
+
+	return svc, contacts, conversations
+}
+
+func TestContactFieldService_CreateAndUpdate(t *testing.T) {
+	svc, _, _ := newContactFieldTestEnv(t)
+	ctx := context.Background()
+
+	_, err := svc.Create(ctx, "tenant-1", &ContactFieldInput{Key: "plan", Label: "Plan again", Type: entity.ContactFieldText})
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code)
+
+	_, err = svc.Create(ctx, "tenant-1", &ContactFieldInput{Key: "Bad Key", Label: "Bad", Type: entity.ContactFieldText})
+	assert.True(t, errors.IsValidation(err))
+
+	field, err := svc.Create(ctx, "tenant-2", &ContactFieldInput{Key: "plan", Label: "Plan", Type: entity.ContactFieldText})
+	require.NoError(t, err, "keys are unique per tenant")
+
+	_, err = svc.Update(ctx, "tenant-2", field.ID, &ContactFieldInput{Key: "tier", Label: "Tier", Type: entity.ContactFieldText})
+	assert.True(t, errors.IsValidation(err), "keys cannot change")
+
+	updated, err := svc.Update(ctx, "tenant-2", field.ID, &ContactFieldInput{Label: "Subscription", Type: entity.ContactFieldText, Required: true})
+	require.NoError(t, err)
+	assert.Equal(t, "plan", updated.Key)
+	assert.Equal(t, "Subscription", updated.Label)
+	assert.True(t, updated.Required)
+
+	_, err = svc.Get(ctx, "tenant-1", field.ID)
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestContactService_ValidatesCustomFields(t *testing.T) {
+	fields, contacts, _ := newContactFieldTestEnv(t)
+	svc := NewContactService(contacts)
+	svc.SetFieldValidator(fields)
+	ctx := context.Background()
+
+	_, err := svc.Create(ctx, &CreateContactInput{TenantID: "tenant-1", Name: "Ana"})
+	assert.True(t, errors.IsValidation(err), "plan is required")
+
+	_, err = svc.Create(ctx, &CreateContactInput{TenantID: "tenant-1", Name: "Ana", CustomFields: map[string]string{"plan": "gold"}})
+	assert.True(t, errors.IsValidation(err))
+
+	contact, err := svc.Create(ctx, &CreateContactInput{
+		TenantID:     "tenant-1",
+		Name:         "Ana",
+		CustomFields: map[string]string{"plan": "pro", "orders": "007", "vip": "1", "source": "import"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"plan": "pro", "orders": "7", "vip": "true", "source": "import"}, contact.CustomFields)
+
+	_, err = svc.Update(ctx, "tenant-1", contact.ID, &UpdateContactInput{CustomFields: map[string]string{"plan": "pro", "orders": "several"}})
+	assert.True(t, errors.IsValidation(err))
+
+	name := "Ana Souza"
+	updated, err := svc.Update(ctx, "tenant-1", contact.ID, &UpdateContactInput{Name: &name})
+	require.NoError(t, err, "values are only checked when custom fields change")
+	assert.Equal(t, "7", updated.CustomFields["orders"])
+
+	filter := map[string]string{"vip": "1", "source": "import"}
+	require.NoError(t, fields.NormalizeFilter(ctx, "tenant-1", filter))
+	assert.Equal(t, map[string]string{"vip": "true", "source": "import"}, filter)
+}
+
+func TestFlowEngine_ExecuteNode_ContactFieldCondition(t *testing.T) {
+	fields, contacts, conversations := newContactFieldTestEnv(t)
+	contacts.Contacts["alice"] = &entity.Contact{ID: "alice", TenantID: "tenant-1", CustomFields: map[string]string{"plan": "pro", "orders": "12"}}
+	contacts.Contacts["bob"] = &entity.Contact{ID: "bob", TenantID: "tenant-1", CustomFields: map[string]string{"plan": "free", "orders": "3"}}
+	conversations.Conversations["conv-alice"] = &entity.Conversation{ID: "conv-alice", TenantID: "tenant-1", ContactID: "alice"}
+	conversations.Conversations["conv-bob"] = &entity.Conversation{ID: "conv-bob", TenantID: "tenant-1", ContactID: "bob"}
+
+	svc, _, _ := newFlowEngine()
+	svc.SetContactLoader(fields)
+
+	flow := entity.NewFlow("tenant-1", "Routing", entity.FlowTriggerKeyword, "hi")
+	flow.Nodes = []entity.FlowNode{
+		{ID: "route", Type: entity.FlowNodeCondition, Transitions: []entity.FlowTransition{
+			{ID: "t1", ToNodeID: "loyal", Condition: entity.TransitionConditionField, Key: "orders", Operator: entity.SegmentOpGreaterThan, Value: "10"},
+			{ID: "t2", ToNodeID: "pro", Condition: entity.TransitionConditionField, Key: "plan", Value: "pro"},
+			{ID: "t3", ToNodeID: "other", Condition: entity.TransitionConditionDefault},
+		}},
+		{ID: "loyal", Type: entity.FlowNodeEnd, Content: "Thanks for being loyal"},
+		{ID: "pro", Type: entity.FlowNodeEnd, Content: "Welcome, pro"},
+		{ID: "other", Type: entity.FlowNodeEnd, Content: "Welcome"},
+	}
+
+	for conversationID, expected := range map[string]string{"conv-alice": "Thanks for being loyal", "conv-bob": "Welcome"} {
+		convCtx := &entity.ConversationContext{ConversationID: conversationID, State: map[string]interface{}{}}
+		result, err := svc.ExecuteNode(context.Background(), flow, &flow.Nodes[0], convCtx, "")
+		require.NoError(t, err)
+		assert.Equal(t, expected, result.Message, conversationID)
+	}
+}
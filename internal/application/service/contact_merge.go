@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// MergeContactsInput identifies the contact folded into another
+type MergeContactsInput struct {
+	SourceContactID string `json:"source_contact_id" binding:"required"`
+}
+
+// ContactMergeResult is the merged contact and the audit record of the merge
+type ContactMergeResult struct {
+	Contact *entity.Contact      `json:"contact"`
+	Merge   *entity.ContactMerge `json:"merge"`
+}
+
+// ContactMergeService links the records of a person who reached the tenant on several
+// channels. Duplicates are merged on request, and inbound messages from a new channel
+// identity are matched to an existing contact by normalized phone or email.
+type ContactMergeService struct {
+	repo        repository.ContactMergeRepository
+	contactRepo repository.ContactRepository
+}
+
+// NewContactMergeService creates a new contact merge service
+func NewContactMergeService(repo repository.ContactMergeRepository, contactRepo repository.ContactRepository) *ContactMergeService {
+	return &ContactMergeService{
+		repo:        repo,
+		contactRepo: contactRepo,
+	}
+}
+
+// Merge folds the source contact into the target: conversations, messages and channel
+// identities move to the target, profile fields only the source has are copied and
+// the source is deleted
+func (s *ContactMergeService) Merge(ctx context.Context, tenantID, targetID, actorID string, input *MergeContactsInput) (*ContactMergeResult, error) {
+	if input.SourceContactID == targetID {
+		return nil, errors.Validation("a contact cannot be merged into itself")
+	}
+
+	target, err := s.tenantContact(ctx, tenantID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	source, err := s.tenantContact(ctx, tenantID, input.SourceContactID)
+	if err != nil {
+		return nil, err
+	}
+
+	target.MergeFrom(source)
+	merge := &entity.ContactMerge{
+		ID:              uuid.New().String(),
+		TenantID:        tenantID,
+		SourceContactID: source.ID,
+		TargetContactID: target.ID,
+		Reason:          entity.ContactMatchManual,
+		PerformedBy:     actorID,
+		CreatedAt:       time.Now(),
+	}
+	if err := s.repo.Merge(ctx, merge, target); err != nil {
+		return nil, err
+	}
+
+	if identities, err := s.contactRepo.FindIdentitiesByContact(ctx, target.ID); err == nil {
+		target.Identities = identities
+	}
+	return &ContactMergeResult{Contact: target, Merge: merge}, nil
+}
+
+// FindDuplicates lists the other contacts of the tenant sharing the contact's
+// normalized phone or email
+func (s *ContactMergeService) FindDuplicates(ctx context.Context, tenantID, contactID string) ([]*entity.ContactMatch, error) {
+	contact, err := s.tenantContact(ctx, tenantID, contactID)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := s.repo.FindMatches(ctx, tenantID, contact.Phone, contact.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]*entity.ContactMatch, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate.ID == contact.ID {
+			continue
+		}
+		matches = append(matches, &entity.ContactMatch{
+			Contact: candidate,
+			Reason:  matchReason(contact, candidate),
+		})
+	}
+	return matches, nil
+}
+
+// MatchContact returns the oldest contact of the tenant with the same normalized
+// phone or email, preferring a phone match. It returns nil when there is none.
+func (s *ContactMergeService) MatchContact(ctx context.Context, tenantID, phone, email string) (*entity.Contact, error) {
+	candidates, err := s.repo.FindMatches(ctx, tenantID, phone, email)
+	if err != nil || len(candidates) == 0 {
+		return nil, err
+	}
+
+	if normalized := entity.NormalizePhone(phone); normalized != "" {
+		for _, candidate := range candidates {
+			if entity.NormalizePhone(candidate.Phone) == normalized {
+				return candidate, nil
+			}
+		}
+	}
+	return candidates[0], nil
+}
+
+// ListMerges lists the contacts merged into a contact
+func (s *ContactMergeService) ListMerges(ctx context.Context, tenantID, contactID string) ([]*entity.ContactMerge, error) {
+	if _, err := s.tenantContact(ctx, tenantID, contactID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByContact(ctx, contactID)
+}
+
+func (s *ContactMergeService) tenantContact(ctx context.Context, tenantID, id string) (*entity.Contact, error) {
+	contact, err := s.contactRepo.FindByID(ctx, id)
+	if err != nil || contact.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeContactNotFound, "contact not found")
+	}
+	return contact, nil
+}
+
+// matchReason tells whether two contacts matched on phone or email
+func matchReason(contact, candidate *entity.Contact) entity.ContactMatchReason {
+	if phone := entity.NormalizePhone(contact.Phone); phone != "" && phone == entity.NormalizePhone(candidate.Phone) {
+		return entity.ContactMatchPhone
+	}
+	return entity.ContactMatchEmail
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockContactMergeRepo applies merges to the in-memory repositories
+type mockContactMergeRepo struct {
+	contacts      *testutil.MockContactRepository
+	conversations *testutil.MockConversationRepository
+	messages      *testutil.MockMessageRepository
+	merges        []*entity.ContactMerge
+}
+
+func (m *mockContactMergeRepo) Merge(ctx context.Context, merge *entity.ContactMerge, target *entity.Contact) error {
+	for _, identity := range m.contacts.Identities[merge.SourceContactID] {
+		identity.ContactID = merge.TargetContactID
+		m.contacts.Identities[merge.TargetContactID] = append(m.contacts.Identities[merge.TargetContactID], identity)
+		merge.IdentityIDs = append(merge.IdentityIDs, identity.ID)
+	}
+	delete(m.contacts.Identities, merge.SourceContactID)
+
+	for _, conversation := range m.conversations.Conversations {
+		if conversation.ContactID == merge.SourceContactID {
+			conversation.ContactID = merge.TargetContactID
+			merge.ConversationIDs = append(merge.ConversationIDs, conversation.ID)
+		}
+	}
+	for _, message := range m.messages.Messages {
+		if message.SenderType == entity.SenderTypeContact && message.SenderID == merge.SourceContactID {
+			message.SenderID = merge.TargetContactID
+			merge.MessageCount++
+		}
+	}
+
+	m.contacts.Contacts[target.ID] = target
+	delete(m.contacts.Contacts, merge.SourceContactID)
+	m.merges = append(m.merges, merge)
+	return nil
+}
+
+func (m *mockContactMergeRepo) FindMatches(ctx context.Context, tenantID, phone, email string) ([]*entity.Contact, error) {
+	phone, email = entity.NormalizePhone(phone), entity.NormalizeEmail(email)
+	var matches []*entity.Contact
+	for _, contact := range m.contacts.Contacts {
+		if contact.TenantID != tenantID {
+			continue
+		}
+		if (phone != "" && entity.NormalizePhone(contact.Phone) == phone) || (email != "" && entity.NormalizeEmail(contact.Email) == email) {
+			matches = append(matches, contact)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].CreatedAt.Before(matches[j].CreatedAt) })
+	return matches, nil
+}
+
+func (m *mockContactMergeRepo) ListByContact(ctx context.Context, contactID string) ([]*entity.ContactMerge, error) {
+	var result []*entity.ContactMerge
+	for _, merge := range m.merges {
+		if merge.TargetContactID == contactID {
+			result = append(result, merge)
+		}
+	}
+	return result, nil
+}
+
+func newContactMergeTestEnv() (*ContactMergeService, *mockContactMergeRepo) {
+	contacts := testutil.NewMockContactRepository()
+	repo := &mockContactMergeRepo{
+		contacts:      contacts,
+		conversations: testutil.NewMockConversationRepository(),
+		messages:      testutil.NewMockMessageRepository(),
+	}
+
+	now := time.Now()
+	contacts.Contacts["whatsapp"] = &entity.Contact{ID: "whatsapp", TenantID: "tenant-1", Name: "John", Phone: "+55 11 99999-9999", CreatedAt: now.Add(-2 * time.Hour)}
+	contacts.Contacts["email"] = &entity.Contact{ID: "email", TenantID: "tenant-1", Name: "John Doe", Email: "John@Example.com", Phone: "5511999999999", Tags: []string{"newsletter"}, CreatedAt: now.Add(-time.Hour)}
+	contacts.Contacts["other"] = &entity.Contact{ID: "other", TenantID: "tenant-1", Name: "Mary", Email: "mary@example.com", CreatedAt: now}
+	contacts.Contacts["foreign"] = &entity.Contact{ID: "foreign", TenantID: "tenant-2", Name: "John", Phone: "5511999999999", CreatedAt: now}
+	contacts.Identities["whatsapp"] = []*entity.ContactIdentity{{ID: "id-wa", ContactID: "whatsapp", ChannelType: "whatsapp", Identifier: "5511999999999"}}
+	contacts.Identities["email"] = []*entity.ContactIdentity{{ID: "id-email", ContactID: "email", ChannelType: "email", Identifier: "john@example.com"}}
+
+	repo.conversations.Conversations["conv-wa"] = &entity.Conversation{ID: "conv-wa", TenantID: "tenant-1", ContactID: "whatsapp"}
+	repo.conversations.Conversations["conv-email"] = &entity.Conversation{ID: "conv-email", TenantID: "tenant-1", ContactID: "email"}
+	repo.messages.Messages["msg-1"] = &entity.Message{ID: "msg-1", ConversationID: "conv-email", SenderType: entity.SenderTypeContact, SenderID: "email"}
+	repo.messages.Messages["msg-2"] = &entity.Message{ID: "msg-2", ConversationID: "conv-email", SenderType: entity.SenderTypeUser, SenderID: "agent-1"}
+
+	return NewContactMergeService(repo, contacts), repo
+}
+
+func TestContactMergeService_Merge(t *testing.T) {
+	svc, repo := newContactMergeTestEnv()
+
+	result, err := svc.Merge(context.Background(), "tenant-1", "whatsapp", "agent-1", &MergeContactsInput{SourceContactID: "email"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "John", result.Contact.Name)
+	assert.Equal(t, "John@Example.com", result.Contact.Email)
+	assert.Equal(t, []string{"newsletter"}, result.Contact.Tags)
+	assert.Len(t, result.Contact.Identities, 2)
+
+	assert.Equal(t, entity.ContactMatchManual, result.Merge.Reason)
+	assert.Equal(t, "agent-1", result.Merge.PerformedBy)
+	assert.Equal(t, []string{"conv-email"}, result.Merge.ConversationIDs)
+	assert.Equal(t, []string{"id-email"}, result.Merge.IdentityIDs)
+	assert.Equal(t, int64(1), result.Merge.MessageCount)
+
+	assert.Equal(t, "whatsapp", repo.conversations.Conversations["conv-email"].ContactID)
+	assert.Equal(t, "whatsapp", repo.messages.Messages["msg-1"].SenderID)
+	assert.Equal(t, "agent-1", repo.messages.Messages["msg-2"].SenderID)
+	assert.NotContains(t, repo.contacts.Contacts, "email")
+
+	merges, err := svc.ListMerges(context.Background(), "tenant-1", "whatsapp")
+	require.NoError(t, err)
+	assert.Len(t, merges, 1)
+}
+
+func TestContactMergeService_Merge_Invalid(t *testing.T) {
+	svc, repo := newContactMergeTestEnv()
+	ctx := context.Background()
+
+	_, err := svc.Merge(ctx, "tenant-1", "whatsapp", "agent-1", &MergeContactsInput{SourceContactID: "whatsapp"})
+	assert.Equal(t, errors.ErrCodeValidation, errors.GetAppError(err).Code)
+
+	_, err = svc.Merge(ctx, "tenant-1", "whatsapp", "agent-1", &MergeContactsInput{SourceContactID: "foreign"})
+	assert.Equal(t, errors.ErrCodeContactNotFound, errors.GetAppError(err).Code)
+
+	_, err = svc.Merge(ctx, "tenant-2", "whatsapp", "agent-1", &MergeContactsInput{SourceContactID: "email"})
+	assert.Equal(t, errors.ErrCodeContactNotFound, errors.GetAppError(err).Code)
+
+	assert.Empty(t, repo.merges)
+}
+
+func TestContactMergeService_FindDuplicates(t *testing.T) {
+	svc, _ := newContactMergeTestEnv()
+
+	matches, err := svc.FindDuplicates(context.Background(), "tenant-1", "whatsapp")
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, "email", matches[0].Contact.ID)
+	assert.Equal(t, entity.ContactMatchPhone, matches[0].Reason)
+
+	matches, err = svc.FindDuplicates(context.Background(), "tenant-1", "other")
+	require.NoError(t, err)
+	assert.Empty(t, matches)
+}
+
+func TestContactMergeService_MatchContact(t *testing.T) {
+	svc, _ := newContactMergeTestEnv()
+	ctx := context.Background()
+
+	contact, err := svc.MatchContact(ctx, "tenant-1", "+55 (11) 99999-9999", "")
+	require.NoError(t, err)
+	require.NotNil(t, contact)
+	assert.Equal(t, "whatsapp", contact.ID, "the oldest contact with the phone wins")
+
+	contact, err = svc.MatchContact(ctx, "tenant-1", "", "Mary <MARY@example.com>")
+	require.NoError(t, err)
+	require.NotNil(t, contact)
+	assert.Equal(t, "other", contact.ID)
+
+	contact, err = svc.MatchContact(ctx, "tenant-1", "+1 555 0100", "nobody@example.com")
+	require.NoError(t, err)
+	assert.Nil(t, contact)
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// ContactSegmentInput represents input for creating or updating a contact segment
+type ContactSegmentInput struct {
+	Name        string               `json:"name" binding:"required"`
+	Description string               `json:"description,omitempty"`
+	Match       entity.SegmentMatch  `json:"match,omitempty"` // Defaults to all
+	Rules       []entity.SegmentRule `json:"rules"`
+}
+
+// ContactSegmentService manages contact segments and evaluates their membership.
+// Membership is never stored: every use of a segment queries the contacts that
+// currently match its rules, so campaigns, flows and analytics see the same
+// audience.
+type ContactSegmentService struct {
+	repo             repository.ContactSegmentRepository
+	contactRepo      repository.ContactRepository
+	conversationRepo repository.ConversationRepository
+}
+
+// NewContactSegmentService creates a new contact segment service
+func NewContactSegmentService(repo repository.ContactSegmentRepository, contactRepo repository.ContactRepository, conversationRepo repository.ConversationRepository) *ContactSegmentService {
+	return &ContactSegmentService{
+		repo:             repo,
+		contactRepo:      contactRepo,
+		conversationRepo: conversationRepo,
+	}
+}
+
+// Create creates a new contact segment
+func (s *ContactSegmentService) Create(ctx context.Context, tenantID, userID string, input *ContactSegmentInput) (*entity.ContactSegment, error) {
+	now := time.Now()
+	segment := &entity.ContactSegment{
+		ID:          uuid.New().String(),
+		TenantID:    tenantID,
+		Name:        input.Name,
+		Description: input.Description,
+		Match:       input.Match,
+		Rules:       input.Rules,
+		CreatedBy:   userID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := validateSegment(segment); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, segment); err != nil {
+		return nil, err
+	}
+	return segment, nil
+}
+
+// Get returns a contact segment of the tenant
+func (s *ContactSegmentService) Get(ctx context.Context, tenantID, id string) (*entity.ContactSegment, error) {
+	segment, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if segment.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeNotFound, "contact segment not found")
+	}
+	return segment, nil
+}
+
+// List lists the contact segments of a tenant
+func (s *ContactSegmentService) List(ctx context.Context, tenantID string) ([]*entity.ContactSegment, error) {
+	return s.repo.FindByTenant(ctx, tenantID)
+}
+
+// Update updates a contact segment
+func (s *ContactSegmentService) Update(ctx context.Context, tenantID, id string, input *ContactSegmentInput) (*entity.ContactSegment, error) {
+	segment, err := s.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	segment.Name = input.Name
+	segment.Description = input.Description
+	segment.Match = input.Match
+	segment.Rules = input.Rules
+	if err := validateSegment(segment); err != nil {
+		return nil, err
+	}
+	segment.UpdatedAt = time.Now()
+
+	if err := s.repo.Update(ctx, segment); err != nil {
+		return nil, err
+	}
+	return segment, nil
+}
+
+// Delete deletes a contact segment
+func (s *ContactSegmentService) Delete(ctx context.Context, tenantID, id string) error {
+	if _, err := s.Get(ctx, tenantID, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// ListContacts returns a page of the contacts currently in a segment
+func (s *ContactSegmentService) ListContacts(ctx context.Context, tenantID, id string, params *repository.ListParams) ([]*entity.Contact, int64, error) {
+	segment, err := s.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, 0, err
+	}
+	if params == nil {
+		params = repository.NewListParams()
+	}
+	return s.contactRepo.FindBySegment(ctx, tenantID, segment, params)
+}
+
+// Stats counts the contacts of a segment and how recently they were contacted
+func (s *ContactSegmentService) Stats(ctx context.Context, tenantID, id string) (*entity.SegmentStats, error) {
+	segment, err := s.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	count := func(rules ...entity.SegmentRule) (int64, error) {
+		params := &repository.ListParams{Page: 1, PageSize: 1, Filters: map[string]interface{}{}}
+		if len(rules) > 0 {
+			params.Filters["rules"] = rules
+		}
+		_, total, err := s.contactRepo.FindBySegment(ctx, tenantID, segment, params)
+		return total, err
+	}
+
+	stats := &entity.SegmentStats{SegmentID: segment.ID}
+	counts := []struct {
+		target *int64
+		rules  []entity.SegmentRule
+	}{
+		{&stats.Contacts, nil},
+		{&stats.ContactedLast7Days, []entity.SegmentRule{{Field: entity.SegmentFieldLastContacted, Operator: entity.SegmentOpWithinDays, Value: "7"}}},
+		{&stats.ContactedLast30Days, []entity.SegmentRule{{Field: entity.SegmentFieldLastContacted, Operator: entity.SegmentOpWithinDays, Value: "30"}}},
+		{&stats.NeverContacted, []entity.SegmentRule{{Field: entity.SegmentFieldLastContacted, Operator: entity.SegmentOpNever}}},
+		{&stats.NewLast30Days, []entity.SegmentRule{{Field: entity.SegmentFieldCreated, Operator: entity.SegmentOpWithinDays, Value: "30"}}},
+	}
+	for _, c := range counts {
+		if *c.target, err = count(c.rules...); err != nil {
+			return nil, err
+		}
+	}
+	return stats, nil
+}
+
+// ContactIDs returns the IDs of every contact currently in a segment
+func (s *ContactSegmentService) ContactIDs(ctx context.Context, tenantID, id string) (map[string]bool, error) {
+	segment, err := s.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]bool)
+	params := &repository.ListParams{Page: 1, PageSize: 100, Filters: map[string]interface{}{}}
+	for {
+		contacts, total, err := s.contactRepo.FindBySegment(ctx, tenantID, segment, params)
+		if err != nil {
+			return nil, err
+		}
+		for _, contact := range contacts {
+			ids[contact.ID] = true
+		}
+		if len(contacts) == 0 || int64(params.Page*params.PageSize) >= total {
+			return ids, nil
+		}
+		params.Page++
+	}
+}
+
+// ContainsContact checks whether a contact is currently in a segment
+func (s *ContactSegmentService) ContainsContact(ctx context.Context, tenantID, segmentID, contactID string) (bool, error) {
+	segment, err := s.Get(ctx, tenantID, segmentID)
+	if err != nil {
+		return false, err
+	}
+	params := &repository.ListParams{Page: 1, PageSize: 1, Filters: map[string]interface{}{"contact_id": contactID}}
+	_, total, err := s.contactRepo.FindBySegment(ctx, tenantID, segment, params)
+	if err != nil {
+		return false, err
+	}
+	return total > 0, nil
+}
+
+// ConversationInSegment checks whether the contact of a conversation is currently in a
+// segment. It lets flows branch on segment membership.
+func (s *ContactSegmentService) ConversationInSegment(ctx context.Context, conversationID, segmentID string) (bool, error) {
+	conversation, err := s.conversationRepo.FindByID(ctx, conversationID)
+	if err != nil {
+		return false, err
+	}
+	return s.ContainsContact(ctx, conversation.TenantID, segmentID, conversation.ContactID)
+}
+
+func validateSegment(segment *entity.ContactSegment) error {
+	if segment.Match == "" {
+		segment.Match = entity.SegmentMatchAll
+	}
+	if segment.Rules == nil {
+		segment.Rules = []entity.SegmentRule{}
+	}
+	if err := segment.Validate(); err != nil {
+		return errors.Validation(err.Error())
+	}
+	return nil
+}
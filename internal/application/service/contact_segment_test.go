@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockContactSegmentRepo keeps segments in memory
+type mockContactSegmentRepo struct {
+	segments map[string]*entity.ContactSegment
+}
+
+func newMockContactSegmentRepo() *mockContactSegmentRepo {
+	return &mockContactSegmentRepo{segments: make(map[string]*entity.ContactSegment)}
+}
+
+func (m *mockContactSegmentRepo) Create(ctx context.Context, segment *entity.ContactSegment) error {
+	m.segments[segment.ID] = segment
+	return nil
+}
+
+func (m *mockContactSegmentRepo) FindByID(ctx context.Context, id string) (*entity.ContactSegment, error) {
+	segment, ok := m.segments[id]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, "contact segment not found")
+	}
+	return segment, nil
+}
+
+func (m *mockContactSegmentRepo) FindByTenant(ctx context.Context, tenantID string) ([]*entity.ContactSegment, error) {
+	var result []*entity.ContactSegment
+	for _, segment := range m.segments {
+		if segment.TenantID == tenantID {
+			result = append(result, segment)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockContactSegmentRepo) Update(ctx context.Context, segment *entity.ContactSegment) error {
+	m.segments[segment.ID] = segment
+	return nil
+}
+
+func (m *mockContactSegmentRepo) Delete(ctx context.Context, id string) error {
+	delete(m.segments, id)
+	return nil
+}
+
+func newContactSegmentTestEnv() (*ContactSegmentService, *testutil.MockContactRepository, *testutil.MockConversationRepository) {
+	contacts := testutil.NewMockContactRepository()
+	conversations := testutil.NewMockConversationRepository()
+	now := time.Now()
+
+	for _, contact := range []*entity.Contact{
+		{ID: "alice", TenantID: "tenant-1", Tags: []string{"vip"}, CustomFields: map[string]string{"plan": "pro"}, CreatedAt: now.AddDate(0, 0, -90),
+			Identities: []*entity.ContactIdentity{{ChannelType: "whatsapp", Identifier: "+5511"}}},
+		{ID: "bob", TenantID: "tenant-1", Tags: []string{"vip"}, CustomFields: map[string]string{"plan": "free"}, CreatedAt: now.AddDate(0, 0, -2)},
+		{ID: "carol", TenantID: "tenant-1", CustomFields: map[string]string{"plan": "pro"}, CreatedAt: now.AddDate(0, 0, -200)},
+		{ID: "mallory", TenantID: "tenant-2", Tags: []string{"vip"}, CreatedAt: now},
+	} {
+		contacts.Contacts[contact.ID] = contact
+	}
+	contacts.LastContacted["alice"] = now.AddDate(0, 0, -3)
+	contacts.LastContacted["carol"] = now.AddDate(0, 0, -20)
+
+	conversations.Conversations["conv-alice"] = &entity.Conversation{ID: "conv-alice", TenantID: "tenant-1", ContactID: "alice"}
+	conversations.Conversations["conv-carol"] = &entity.Conversation{ID: "conv-carol", TenantID: "tenant-1", ContactID: "carol"}
+
+	return NewContactSegmentService(newMockContactSegmentRepo(), contacts, conversations), contacts, conversations
+}
+
+func TestContactSegmentService_CreateValidation(t *testing.T) {
+	svc, _, _ := newContactSegmentTestEnv()
+	ctx := context.Background()
+
+	segment, err := svc.Create(ctx, "tenant-1", "user-1", &ContactSegmentInput{Name: "Everyone"})
+	require.NoError(t, err)
+	assert.Equal(t, entity.SegmentMatchAll, segment.Match)
+	assert.Empty(t, segment.Rules)
+
+	invalid := []entity.SegmentRule{
+		{Field: "country", Operator: entity.SegmentOpEquals, Value: "BR"},
+		{Field: entity.SegmentFieldTag, Operator: entity.SegmentOpWithinDays, Value: "vip"},
+		{Field: entity.SegmentFieldCustomField, Operator: entity.SegmentOpEquals, Value: "pro"},
+		{Field: entity.SegmentFieldLastContacted, Operator: entity.SegmentOpWithinDays, Value: "soon"},
+	}
+	for _, rule := range invalid {
+		_, err := svc.Create(ctx, "tenant-1", "user-1", &ContactSegmentInput{Name: "Invalid", Rules: []entity.SegmentRule{rule}})
+		assert.True(t, errors.IsValidation(err), "%+v", rule)
+	}
+
+	_, err = svc.Get(ctx, "tenant-2", segment.ID)
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestContactSegmentService_ListContactsAndStats(t *testing.T) {
+	svc, _, _ := newContactSegmentTestEnv()
+	ctx := context.Background()
+
+	segment, err := svc.Create(ctx, "tenant-1", "user-1", &ContactSegmentInput{
+		Name:  "VIP or pro",
+		Match: entity.SegmentMatchAny,
+		Rules: []entity.SegmentRule{
+			{Field: entity.SegmentFieldTag, Operator: entity.SegmentOpHas, Value: "vip"},
+			{Field: entity.SegmentFieldCustomField, Operator: entity.SegmentOpEquals, Key: "plan", Value: "pro"},
+		},
+	})
+	require.NoError(t, err)
+
+	contacts, total, err := svc.ListContacts(ctx, "tenant-1", segment.ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), total, "mallory belongs to another tenant")
+	assert.Len(t, contacts, 3)
+
+	stats, err := svc.Stats(ctx, "tenant-1", segment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, &entity.SegmentStats{
+		SegmentID:           segment.ID,
+		Contacts:            3,
+		ContactedLast7Days:  1,
+		ContactedLast30Days: 2,
+		NeverContacted:      1,
+		NewLast30Days:       1,
+	}, stats)
+
+	ids, err := svc.ContactIDs(ctx, "tenant-1", segment.ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"alice": true, "bob": true, "carol": true}, ids)
+}
+
+func TestContactSegmentService_ConversationInSegment(t *testing.T) {
+	svc, _, _ := newContactSegmentTestEnv()
+	ctx := context.Background()
+
+	segment, err := svc.Create(ctx, "tenant-1", "user-1", &ContactSegmentInput{
+		Name:  "WhatsApp VIPs",
+		Rules: []entity.SegmentRule{{Field: entity.SegmentFieldChannel, Operator: entity.SegmentOpHas, Value: "whatsapp"}},
+	})
+	require.NoError(t, err)
+
+	in, err := svc.ConversationInSegment(ctx, "conv-alice", segment.ID)
+	require.NoError(t, err)
+	assert.True(t, in)
+
+	in, err = svc.ConversationInSegment(ctx, "conv-carol", segment.ID)
+	require.NoError(t, err)
+	assert.False(t, in)
+}
+
+func TestFlowEngine_ExecuteNode_InSegmentCondition(t *testing.T) {
+	segments, _, _ := newContactSegmentTestEnv()
+	segment, err := segments.Create(context.Background(), "tenant-1", "user-1", &ContactSegmentInput{
+		Name:  "VIP",
+		Rules: []entity.SegmentRule{{Field: entity.SegmentFieldTag, Operator: entity.SegmentOpHas, Value: "vip"}},
+	})
+	require.NoError(t, err)
+
+	svc, _, _ := newFlowEngine()
+	svc.SetSegmentChecker(segments)
+
+	flow := entity.NewFlow("tenant-1", "Routing", entity.FlowTriggerKeyword, "hi")
+	flow.Nodes = []entity.FlowNode{
+		{ID: "route", Type: entity.FlowNodeCondition, Transitions: []entity.FlowTransition{
+			{ID: "t1", ToNodeID: "vip", Condition: entity.TransitionConditionInSegment, Value: segment.ID},
+			{ID: "t2", ToNodeID: "other", Condition: entity.TransitionConditionDefault},
+		}},
+		{ID: "vip", Type: entity.FlowNodeEnd, Content: "Welcome back, VIP"},
+		{ID: "other", Type: entity.FlowNodeEnd, Content: "Welcome"},
+	}
+
+	for conversationID, expected := range map[string]string{"conv-alice": "Welcome back, VIP", "conv-carol": "Welcome"} {
+		convCtx := &entity.ConversationContext{ConversationID: conversationID, State: map[string]interface{}{}}
+		result, err := svc.ExecuteNode(context.Background(), flow, &flow.Nodes[0], convCtx, "")
+		require.NoError(t, err)
+		assert.Equal(t, expected, result.Message, conversationID)
+	}
+}
+
+func TestNewsletterService_SendEditionToSegment(t *testing.T) {
+	f := newNewsletterFixture()
+	ctx := context.Background()
+
+	segments := NewContactSegmentService(newMockContactSegmentRepo(), f.contacts, f.conversations)
+	f.service.SetSegmentService(segments)
+	segment, err := segments.Create(ctx, "tenant-1", "user-1", &ContactSegmentInput{
+		Name:  "Not VIP",
+		Rules: []entity.SegmentRule{{Field: entity.SegmentFieldTag, Operator: entity.SegmentOpNotHas, Value: "vip"}},
+	})
+	require.NoError(t, err)
+
+	input := weeklyNewsletterInput()
+	input.SegmentID = "missing"
+	_, err = f.service.Create(ctx, "tenant-1", input)
+	assert.True(t, errors.IsValidation(err))
+
+	input.SegmentID = segment.ID
+	newsletter, err := f.service.Create(ctx, "tenant-1", input)
+	require.NoError(t, err)
+	_, err = f.service.Subscribe(ctx, "tenant-1", newsletter.ID, &SubscribeContactsInput{ContactIDs: []string{"alice", "bob"}})
+	require.NoError(t, err)
+
+	edition, err := f.service.SendNow(ctx, "tenant-1", newsletter.ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, edition.Recipients, "alice is outside the segment")
+	require.Len(t, f.producer.OutboundMessages, 1)
+}
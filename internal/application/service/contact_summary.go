@@ -0,0 +1,364 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/whatsapp/payments"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// contactSummaryConversations is how many recent conversations a summary is built from
+	contactSummaryConversations = 50
+	// contactSummaryAnalyzed is how many recent conversations contribute intents and sentiment
+	contactSummaryAnalyzed = 20
+	// contactSummaryTopics caps the common and recent topics on a card
+	contactSummaryTopics = 5
+	// contactSummaryTTL bounds how stale payments and orders on a cached card can get,
+	// since those change without a message from the contact
+	contactSummaryTTL = 10 * time.Minute
+	// sentimentTrendThreshold is the change in average sentiment score that counts as a trend
+	sentimentTrendThreshold = 0.25
+)
+
+// ContactSummaryService builds the profile cards shown to agents next to a
+// conversation. Cards are cached per contact and rebuilt after the contact sends a
+// new message, so the agent sidebar is a single cheap request.
+type ContactSummaryService struct {
+	contactRepo      repository.ContactRepository
+	conversationRepo repository.ConversationRepository
+	contextRepo      repository.ConversationContextRepository
+	paymentStore     payments.PaymentStore
+	orderRepo        repository.OrderRepository
+
+	mu    sync.RWMutex
+	cache map[string]*entity.ContactSummary // keyed by contact ID
+	now   func() time.Time
+}
+
+// NewContactSummaryService creates a new contact summary service. paymentStore may be
+// nil, in which case cards carry no open payments.
+func NewContactSummaryService(
+	contactRepo repository.ContactRepository,
+	conversationRepo repository.ConversationRepository,
+	contextRepo repository.ConversationContextRepository,
+	paymentStore payments.PaymentStore,
+) *ContactSummaryService {
+	return &ContactSummaryService{
+		contactRepo:      contactRepo,
+		conversationRepo: conversationRepo,
+		contextRepo:      contextRepo,
+		paymentStore:     paymentStore,
+		cache:            make(map[string]*entity.ContactSummary),
+		now:              time.Now,
+	}
+}
+
+// SetOrderRepository configures the source of the last order shown on cards
+func (s *ContactSummaryService) SetOrderRepository(orderRepo repository.OrderRepository) {
+	s.orderRepo = orderRepo
+}
+
+// Get returns the summary card of a contact, building it when it is not cached
+func (s *ContactSummaryService) Get(ctx context.Context, tenantID, contactID string) (*entity.ContactSummary, error) {
+	s.mu.RLock()
+	cached, ok := s.cache[contactID]
+	s.mu.RUnlock()
+	if ok && cached.TenantID == tenantID && s.now().Sub(cached.GeneratedAt) < contactSummaryTTL {
+		return cached, nil
+	}
+
+	contact, err := s.contactRepo.FindByID(ctx, contactID)
+	if err != nil || contact.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeContactNotFound, "contact not found")
+	}
+
+	summary, err := s.build(ctx, contact)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cache[contactID] = summary
+	s.mu.Unlock()
+	return summary, nil
+}
+
+// Invalidate drops the cached card of a contact
+func (s *ContactSummaryService) Invalidate(contactID string) {
+	s.mu.Lock()
+	delete(s.cache, contactID)
+	s.mu.Unlock()
+}
+
+// HandleInbound refreshes the card of the contact who sent a message on its next read
+func (s *ContactSummaryService) HandleInbound(ctx context.Context, message *entity.Message, conversation *entity.Conversation) error {
+	s.Invalidate(conversation.ContactID)
+	return nil
+}
+
+func (s *ContactSummaryService) build(ctx context.Context, contact *entity.Contact) (*entity.ContactSummary, error) {
+	params := repository.NewListParams()
+	params.PageSize = contactSummaryConversations
+	conversations, total, err := s.conversationRepo.FindByContact(ctx, contact.ID, params)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to load contact conversations")
+	}
+	sort.Slice(conversations, func(i, j int) bool {
+		return conversations[i].CreatedAt.After(conversations[j].CreatedAt)
+	})
+
+	summary := &entity.ContactSummary{
+		ContactID:             contact.ID,
+		TenantID:              contact.TenantID,
+		Name:                  contact.Name,
+		LifetimeConversations: total,
+		CommonTopics:          []entity.ContactTopic{},
+		RecentTopics:          []string{},
+		SentimentTrend:        entity.SentimentTrendUnknown,
+		SentimentHistory:      []entity.Sentiment{},
+		OpenPayments:          []entity.ContactOpenPayment{},
+		GeneratedAt:           s.now(),
+	}
+
+	for _, conversation := range conversations {
+		if conversation.IsOpen() {
+			summary.OpenConversations++
+		}
+		lastActivity := conversation.CreatedAt
+		if conversation.LastMessageAt != nil {
+			lastActivity = *conversation.LastMessageAt
+		}
+		if summary.LastContactAt == nil || lastActivity.After(*summary.LastContactAt) {
+			summary.LastContactAt = &lastActivity
+		}
+	}
+	if len(conversations) > 0 {
+		first := conversations[len(conversations)-1].CreatedAt
+		summary.FirstContactAt = &first
+	}
+
+	s.summarizeTopics(ctx, summary, conversations)
+	s.summarizePayments(ctx, summary, contact)
+	s.summarizeLastOrder(ctx, summary, contact)
+
+	return summary, nil
+}
+
+// summarizeTopics collects topics from the AI titles, tags and detected intents of
+// recent conversations, along with the sentiment of each conversation
+func (s *ContactSummaryService) summarizeTopics(ctx context.Context, summary *entity.ContactSummary, conversations []*entity.Conversation) {
+	counts := make(map[string]int)
+	seenTitles := make(map[string]bool)
+
+	analyzed := conversations
+	if len(analyzed) > contactSummaryAnalyzed {
+		analyzed = analyzed[:contactSummaryAnalyzed]
+	}
+
+	sentiments := make([]entity.Sentiment, 0, len(analyzed))
+	for _, conversation := range analyzed {
+		title := strings.TrimSpace(firstNonEmpty(conversation.Title, conversation.Subject))
+		if title != "" && !seenTitles[strings.ToLower(title)] && len(summary.RecentTopics) < contactSummaryTopics {
+			seenTitles[strings.ToLower(title)] = true
+			summary.RecentTopics = append(summary.RecentTopics, title)
+		}
+		for _, tag := range conversation.Tags {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				counts[strings.ToLower(tag)]++
+			}
+		}
+
+		if s.contextRepo == nil {
+			continue
+		}
+		convContext, err := s.contextRepo.FindByConversation(ctx, conversation.ID)
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				logger.Warn("Failed to load conversation context for contact summary",
+					zap.String("conversation_id", conversation.ID),
+					zap.Error(err),
+				)
+			}
+			continue
+		}
+		if convContext.Intent != nil && convContext.Intent.Name != "" {
+			counts[strings.ToLower(convContext.Intent.Name)]++
+		}
+		if convContext.Sentiment != "" {
+			sentiments = append(sentiments, convContext.Sentiment)
+		}
+	}
+
+	for name, count := range counts {
+		summary.CommonTopics = append(summary.CommonTopics, entity.ContactTopic{Name: name, Count: count})
+	}
+	sort.Slice(summary.CommonTopics, func(i, j int) bool {
+		if summary.CommonTopics[i].Count != summary.CommonTopics[j].Count {
+			return summary.CommonTopics[i].Count > summary.CommonTopics[j].Count
+		}
+		return summary.CommonTopics[i].Name < summary.CommonTopics[j].Name
+	})
+	if len(summary.CommonTopics) > contactSummaryTopics {
+		summary.CommonTopics = summary.CommonTopics[:contactSummaryTopics]
+	}
+
+	// Conversations are newest first; the history reads oldest first
+	for i := len(sentiments) - 1; i >= 0; i-- {
+		summary.SentimentHistory = append(summary.SentimentHistory, sentiments[i])
+	}
+	if len(sentiments) > 0 {
+		summary.Sentiment = sentiments[0]
+	}
+	summary.SentimentTrend = sentimentTrend(summary.SentimentHistory)
+}
+
+// sentimentTrend compares the average sentiment of the newer half of a history with
+// the older half
+func sentimentTrend(history []entity.Sentiment) entity.SentimentTrend {
+	if len(history) < 2 {
+		return entity.SentimentTrendUnknown
+	}
+
+	half := len(history) / 2
+	older := averageSentiment(history[:half])
+	newer := averageSentiment(history[len(history)-half:])
+	switch {
+	case newer-older >= sentimentTrendThreshold:
+		return entity.SentimentTrendImproving
+	case older-newer >= sentimentTrendThreshold:
+		return entity.SentimentTrendWorsening
+	default:
+		return entity.SentimentTrendStable
+	}
+}
+
+func averageSentiment(sentiments []entity.Sentiment) float64 {
+	var total float64
+	for _, sentiment := range sentiments {
+		switch sentiment {
+		case entity.SentimentPositive:
+			total++
+		case entity.SentimentNegative:
+			total--
+		}
+	}
+	return total / float64(len(sentiments))
+}
+
+// summarizePayments lists the contact's pending payments. Payments are stored by
+// customer phone, so the contact phone and WhatsApp identities are looked up.
+func (s *ContactSummaryService) summarizePayments(ctx context.Context, summary *entity.ContactSummary, contact *entity.Contact) {
+	if s.paymentStore == nil {
+		return
+	}
+
+	seen := make(map[string]bool)
+	for _, phone := range s.contactPhones(ctx, contact) {
+		customerPayments, err := s.paymentStore.GetByCustomer(ctx, phone)
+		if err != nil {
+			logger.Warn("Failed to load payments for contact summary",
+				zap.String("contact_id", contact.ID),
+				zap.Error(err),
+			)
+			continue
+		}
+		for _, payment := range customerPayments {
+			if payment.OrganizationID != contact.TenantID || payment.Status != payments.PaymentStatusPending || seen[payment.ID] {
+				continue
+			}
+			seen[payment.ID] = true
+			summary.OpenPayments = append(summary.OpenPayments, entity.ContactOpenPayment{
+				ID:          payment.ID,
+				ReferenceID: payment.ReferenceID,
+				Amount:      payment.Amount,
+				Currency:    payment.Currency,
+				Status:      string(payment.Status),
+				Description: payment.Description,
+				ExpiresAt:   payment.ExpiresAt,
+				CreatedAt:   payment.CreatedAt,
+			})
+		}
+	}
+	sort.Slice(summary.OpenPayments, func(i, j int) bool {
+		return summary.OpenPayments[i].CreatedAt.After(summary.OpenPayments[j].CreatedAt)
+	})
+}
+
+// summarizeLastOrder adds the contact's most recent order
+func (s *ContactSummaryService) summarizeLastOrder(ctx context.Context, summary *entity.ContactSummary, contact *entity.Contact) {
+	if s.orderRepo == nil {
+		return
+	}
+
+	for _, phone := range s.contactPhones(ctx, contact) {
+		orders, _, err := s.orderRepo.GetByCustomer(ctx, contact.TenantID, phone, repository.Pagination{Page: 1, PageSize: 1})
+		if err != nil {
+			logger.Warn("Failed to load orders for contact summary",
+				zap.String("contact_id", contact.ID),
+				zap.Error(err),
+			)
+			continue
+		}
+		if len(orders) == 0 {
+			continue
+		}
+		order := orders[0]
+		if summary.LastOrder != nil && !order.CreatedAt.After(summary.LastOrder.CreatedAt) {
+			continue
+		}
+		summary.LastOrder = &entity.ContactOrderSnapshot{
+			ID:        order.ID,
+			Status:    order.Status,
+			Total:     order.Total,
+			Currency:  order.Currency,
+			Items:     len(order.Items),
+			CreatedAt: order.CreatedAt,
+		}
+	}
+}
+
+// contactPhones returns the phone numbers a contact's payments and orders may be
+// stored under, as given and as bare digits
+func (s *ContactSummaryService) contactPhones(ctx context.Context, contact *entity.Contact) []string {
+	candidates := []string{contact.Phone}
+	identities := contact.Identities
+	if identities == nil {
+		identities, _ = s.contactRepo.FindIdentitiesByContact(ctx, contact.ID)
+	}
+	for _, identity := range identities {
+		if identity.ChannelType == string(entity.ChannelTypeWhatsApp) || identity.ChannelType == string(entity.ChannelTypeWhatsAppOfficial) {
+			candidates = append(candidates, identity.Identifier)
+		}
+	}
+
+	var phones []string
+	seen := make(map[string]bool)
+	for _, candidate := range candidates {
+		for _, phone := range []string{strings.TrimSpace(candidate), phoneDigits(candidate)} {
+			if phone != "" && !seen[phone] {
+				seen[phone] = true
+				phones = append(phones, phone)
+			}
+		}
+	}
+	return phones
+}
+
+func phoneDigits(phone string) string {
+	var digits strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	return digits.String()
+}
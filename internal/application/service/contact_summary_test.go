@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/whatsapp/payments"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePaymentStore struct {
+	payments.PaymentStore
+	byPhone map[string][]*payments.Payment
+}
+
+func (f *fakePaymentStore) GetByCustomer(ctx context.Context, customerPhone string) ([]*payments.Payment, error) {
+	return f.byPhone[customerPhone], nil
+}
+
+type summaryTestEnv struct {
+	svc           *ContactSummaryService
+	conversations *testutil.MockConversationRepository
+	contexts      *mockConversationContextRepository
+	payments      *fakePaymentStore
+}
+
+func newSummaryTestEnv() *summaryTestEnv {
+	contacts := testutil.NewMockContactRepository()
+	contacts.Contacts["contact-1"] = &entity.Contact{ID: "contact-1", TenantID: "tenant-1", Name: "Maria", Phone: "+55 11 99999-0000"}
+
+	env := &summaryTestEnv{
+		conversations: testutil.NewMockConversationRepository(),
+		contexts:      newMockConversationContextRepository(),
+		payments:      &fakePaymentStore{byPhone: make(map[string][]*payments.Payment)},
+	}
+	env.svc = NewContactSummaryService(contacts, env.conversations, env.contexts, env.payments)
+	return env
+}
+
+func (e *summaryTestEnv) addConversation(id string, day int, status entity.ConversationStatus, title, intent string, sentiment entity.Sentiment) {
+	e.conversations.Conversations[id] = &entity.Conversation{
+		ID:        id,
+		TenantID:  "tenant-1",
+		ContactID: "contact-1",
+		Status:    status,
+		Title:     title,
+		CreatedAt: time.Date(2026, 3, day, 10, 0, 0, 0, time.UTC),
+	}
+	if intent == "" && sentiment == "" {
+		return
+	}
+	convContext := entity.NewConversationContext(id)
+	convContext.ID = "ctx-" + id
+	convContext.Sentiment = sentiment
+	if intent != "" {
+		convContext.Intent = entity.NewIntent(intent, 0.9)
+	}
+	e.contexts.Create(context.Background(), convContext)
+}
+
+func TestContactSummaryService_Get(t *testing.T) {
+	env := newSummaryTestEnv()
+	env.addConversation("conv-1", 1, entity.ConversationStatusResolved, "Refund request for order 4521", "refund", entity.SentimentNegative)
+	env.addConversation("conv-2", 5, entity.ConversationStatusResolved, "Delivery delay", "shipping", entity.SentimentNegative)
+	env.addConversation("conv-3", 9, entity.ConversationStatusResolved, "Second refund", "refund", entity.SentimentPositive)
+	env.addConversation("conv-4", 12, entity.ConversationStatusOpen, "New order question", "", entity.SentimentPositive)
+
+	env.payments.byPhone["5511999990000"] = []*payments.Payment{
+		{ID: "pay-1", OrganizationID: "tenant-1", ReferenceID: "ref-1", Amount: 1500, Currency: "BRL", Status: payments.PaymentStatusPending},
+		{ID: "pay-2", OrganizationID: "tenant-1", ReferenceID: "ref-2", Amount: 900, Currency: "BRL", Status: payments.PaymentStatusSuccess},
+		{ID: "pay-3", OrganizationID: "tenant-2", ReferenceID: "ref-3", Amount: 700, Currency: "BRL", Status: payments.PaymentStatusPending},
+	}
+
+	summary, err := env.svc.Get(context.Background(), "tenant-1", "contact-1")
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(4), summary.LifetimeConversations)
+	assert.Equal(t, 1, summary.OpenConversations)
+	assert.Equal(t, time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC), *summary.FirstContactAt)
+	assert.Equal(t, time.Date(2026, 3, 12, 10, 0, 0, 0, time.UTC), *summary.LastContactAt)
+	assert.Equal(t, []entity.ContactTopic{{Name: "refund", Count: 2}, {Name: "shipping", Count: 1}}, summary.CommonTopics)
+	assert.Equal(t, "New order question", summary.RecentTopics[0])
+	assert.Len(t, summary.RecentTopics, 4)
+
+	assert.Equal(t, []entity.Sentiment{entity.SentimentNegative, entity.SentimentNegative, entity.SentimentPositive, entity.SentimentPositive}, summary.SentimentHistory)
+	assert.Equal(t, entity.SentimentPositive, summary.Sentiment)
+	assert.Equal(t, entity.SentimentTrendImproving, summary.SentimentTrend)
+
+	require.Len(t, summary.OpenPayments, 1)
+	assert.Equal(t, "pay-1", summary.OpenPayments[0].ID)
+	assert.Nil(t, summary.LastOrder)
+}
+
+func TestContactSummaryService_Caching(t *testing.T) {
+	env := newSummaryTestEnv()
+	env.addConversation("conv-1", 1, entity.ConversationStatusOpen, "Refund request", "", "")
+	ctx := context.Background()
+
+	summary, err := env.svc.Get(ctx, "tenant-1", "contact-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), summary.LifetimeConversations)
+
+	env.addConversation("conv-2", 2, entity.ConversationStatusOpen, "Delivery delay", "", "")
+	cached, err := env.svc.Get(ctx, "tenant-1", "contact-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), cached.LifetimeConversations, "cached card is served until new activity")
+
+	env.svc.HandleInbound(ctx, &entity.Message{ID: "msg-1"}, env.conversations.Conversations["conv-2"])
+	refreshed, err := env.svc.Get(ctx, "tenant-1", "contact-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), refreshed.LifetimeConversations)
+
+	env.addConversation("conv-3", 3, entity.ConversationStatusOpen, "Invoice", "", "")
+	env.svc.now = func() time.Time { return time.Now().Add(contactSummaryTTL) }
+	expired, err := env.svc.Get(ctx, "tenant-1", "contact-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), expired.LifetimeConversations, "expired cards are rebuilt")
+}
+
+func TestContactSummaryService_Get_OtherTenant(t *testing.T) {
+	env := newSummaryTestEnv()
+
+	_, err := env.svc.Get(context.Background(), "tenant-2", "contact-1")
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeContactNotFound, errors.GetAppError(err).Code)
+}
+
+func TestSentimentTrend(t *testing.T) {
+	tests := []struct {
+		name    string
+		history []entity.Sentiment
+		want    entity.SentimentTrend
+	}{
+		{"no history", nil, entity.SentimentTrendUnknown},
+		{"single conversation", []entity.Sentiment{entity.SentimentNegative}, entity.SentimentTrendUnknown},
+		{"improving", []entity.Sentiment{entity.SentimentNegative, entity.SentimentNeutral, entity.SentimentPositive}, entity.SentimentTrendImproving},
+		{"worsening", []entity.Sentiment{entity.SentimentPositive, entity.SentimentNegative}, entity.SentimentTrendWorsening},
+		{"stable", []entity.Sentiment{entity.SentimentNeutral, entity.SentimentPositive, entity.SentimentNeutral, entity.SentimentPositive}, entity.SentimentTrendStable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, sentimentTrend(tt.history))
+		})
+	}
+}
@@ -57,7 +57,7 @@ func TestContactService_GetByID(t *testing.T) {
 		Name:     "Jane Doe",
 	})
 
-	found, err := svc.GetByID(context.Background(), created.ID)
+	found, err := svc.GetByID(context.Background(), "tenant1", created.ID)
 	assert.NoError(t, err)
 	assert.Equal(t, "Jane Doe", found.Name)
 }
@@ -66,6 +66,6 @@ func TestContactService_GetByID_NotFound(t *testing.T) {
 	repo := testutil.NewMockContactRepository()
 	svc := NewContactService(repo)
 
-	_, err := svc.GetByID(context.Background(), "non-existent")
+	_, err := svc.GetByID(context.Background(), "tenant1", "non-existent")
 	assert.Error(t, err)
 }
@@ -7,7 +7,10 @@ import (
 	"github.com/google/uuid"
 	"github.com/msgfy/linktor/internal/domain/entity"
 	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
 	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
 )
 
 // CreateConversationInput represents input for creating a conversation
@@ -28,20 +31,38 @@ type UpdateConversationInput struct {
 	Tags     []string
 }
 
+// ResolveConversationInput represents the disposition and notes given when resolving
+// a conversation
+type ResolveConversationInput struct {
+	DispositionCode string
+	Notes           string
+	ResolvedBy      string // User ID
+}
+
 // ConversationFilters represents conversation filter options
 type ConversationFilters struct {
 	Status     string
 	AssignedTo string
 	ChannelID  string
 	ContactID  string
+	Search     string // Matches the title or subject
 	Tags       []string
+	LabelIDs   []string // Conversations must carry every label
 }
 
 // ConversationService handles conversation operations
 type ConversationService struct {
 	conversationRepo repository.ConversationRepository
-	contactRepo      repository.ContactRepository
-	channelRepo      repository.ChannelRepository
+	conversations    *repository.TenantScope[entity.Conversation]
+	contacts         *repository.TenantScope[entity.Contact]
+	channels         *repository.TenantScope[entity.Channel]
+	watchService     *WatchService
+	summaryService   *ConversationSummaryService
+	experiments      *BotExperimentService
+	dispositions     *DispositionService
+	reopens          *ConversationReopenService
+	copilot          *CopilotService
+	producer         nats.Publisher
 }
 
 // NewConversationService creates a new conversation service
@@ -52,11 +73,55 @@ func NewConversationService(
 ) *ConversationService {
 	return &ConversationService{
 		conversationRepo: conversationRepo,
-		contactRepo:      contactRepo,
-		channelRepo:      channelRepo,
+		conversations:    repository.TenantConversations(conversationRepo, conversationNotFound),
+		contacts:         repository.TenantContacts(contactRepo, contactNotFound),
+		channels:         repository.TenantChannels(channelRepo, channelNotFound),
 	}
 }
 
+// conversationNotFound is the error of missing conversations and of those of other tenants
+func conversationNotFound() error {
+	return errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+}
+
+// SetWatchService sets the service notifying watchers of assignment and status changes
+func (s *ConversationService) SetWatchService(watchService *WatchService) {
+	s.watchService = watchService
+}
+
+// SetSummaryService sets the service summarizing conversations when they are resolved
+func (s *ConversationService) SetSummaryService(summaryService *ConversationSummaryService) {
+	s.summaryService = summaryService
+}
+
+// SetExperimentService sets the service counting resolutions against the bot version
+// of an experiment
+func (s *ConversationService) SetExperimentService(experiments *BotExperimentService) {
+	s.experiments = experiments
+}
+
+// SetDispositionService sets the service checking and storing the disposition given
+// when resolving a conversation
+func (s *ConversationService) SetDispositionService(dispositions *DispositionService) {
+	s.dispositions = dispositions
+}
+
+// SetReopenService sets the service recording reopens for the reopen rate
+func (s *ConversationService) SetReopenService(reopens *ConversationReopenService) {
+	s.reopens = reopens
+}
+
+// SetCopilotService sets the service suggesting first replies to agents when
+// conversations are assigned to them
+func (s *ConversationService) SetCopilotService(copilot *CopilotService) {
+	s.copilot = copilot
+}
+
+// SetProducer sets the publisher of conversation events
+func (s *ConversationService) SetProducer(producer nats.Publisher) {
+	s.producer = producer
+}
+
 // List returns all conversations for a tenant
 func (s *ConversationService) List(ctx context.Context, tenantID string, filters *ConversationFilters, params *repository.ListParams) ([]*entity.Conversation, int64, error) {
 	if params == nil {
@@ -78,6 +143,12 @@ func (s *ConversationService) List(ctx context.Context, tenantID string, filters
 		if filters.ContactID != "" {
 			params.Filters["contact_id"] = filters.ContactID
 		}
+		if filters.Search != "" {
+			params.Filters["search"] = filters.Search
+		}
+		if len(filters.LabelIDs) > 0 {
+			params.Filters["label_ids"] = filters.LabelIDs
+		}
 	}
 
 	return s.conversationRepo.FindByTenant(ctx, tenantID, params)
@@ -92,16 +163,12 @@ func (s *ConversationService) Create(ctx context.Context, input *CreateConversat
 		return nil, errors.Validation("channel_id is required")
 	}
 
-	// Verify contact exists
-	_, err := s.contactRepo.FindByID(ctx, input.ContactID)
-	if err != nil {
-		return nil, errors.New(errors.ErrCodeContactNotFound, "contact not found")
+	// Verify contact and channel exist in the tenant
+	if _, err := s.contacts.FindByID(ctx, input.TenantID, input.ContactID); err != nil {
+		return nil, err
 	}
-
-	// Verify channel exists
-	_, err = s.channelRepo.FindByID(ctx, input.ChannelID)
-	if err != nil {
-		return nil, errors.New(errors.ErrCodeChannelNotFound, "channel not found")
+	if _, err := s.channels.FindByID(ctx, input.TenantID, input.ChannelID); err != nil {
+		return nil, err
 	}
 
 	// Check for existing open conversation
@@ -137,20 +204,16 @@ func (s *ConversationService) Create(ctx context.Context, input *CreateConversat
 	return conversation, nil
 }
 
-// GetByID returns a conversation by ID
-func (s *ConversationService) GetByID(ctx context.Context, id string) (*entity.Conversation, error) {
-	conversation, err := s.conversationRepo.FindByID(ctx, id)
-	if err != nil {
-		return nil, errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
-	}
-	return conversation, nil
+// GetByID returns a conversation of a tenant by ID
+func (s *ConversationService) GetByID(ctx context.Context, tenantID, id string) (*entity.Conversation, error) {
+	return s.conversations.FindByID(ctx, tenantID, id)
 }
 
 // Update updates a conversation
-func (s *ConversationService) Update(ctx context.Context, id string, input *UpdateConversationInput) (*entity.Conversation, error) {
-	conversation, err := s.conversationRepo.FindByID(ctx, id)
+func (s *ConversationService) Update(ctx context.Context, tenantID, id string, input *UpdateConversationInput) (*entity.Conversation, error) {
+	conversation, err := s.conversations.FindByID(ctx, tenantID, id)
 	if err != nil {
-		return nil, errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+		return nil, err
 	}
 
 	if input.Subject != nil {
@@ -175,10 +238,10 @@ func (s *ConversationService) Update(ctx context.Context, id string, input *Upda
 }
 
 // Assign assigns a conversation to a user
-func (s *ConversationService) Assign(ctx context.Context, id, userID string) (*entity.Conversation, error) {
-	conversation, err := s.conversationRepo.FindByID(ctx, id)
+func (s *ConversationService) Assign(ctx context.Context, tenantID, id, userID string) (*entity.Conversation, error) {
+	conversation, err := s.conversations.FindByID(ctx, tenantID, id)
 	if err != nil {
-		return nil, errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+		return nil, err
 	}
 
 	conversation.Assign(userID)
@@ -188,20 +251,40 @@ func (s *ConversationService) Assign(ctx context.Context, id, userID string) (*e
 		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to assign conversation")
 	}
 
+	if s.watchService != nil {
+		s.watchService.Notify(ctx, conversation, &entity.WatchActivity{
+			Type:           entity.WatchActivityAssigned,
+			AssignedUserID: userID,
+		})
+	}
+
+	if s.copilot != nil {
+		s.copilot.HandleAssigned(ctx, conversation)
+	}
+
 	return conversation, nil
 }
 
-// Resolve marks a conversation as resolved
-func (s *ConversationService) Resolve(ctx context.Context, id string) (*entity.Conversation, error) {
-	conversation, err := s.conversationRepo.FindByID(ctx, id)
+// Resolve marks a conversation as resolved with an optional disposition and notes.
+// Tenants can require a disposition.
+func (s *ConversationService) Resolve(ctx context.Context, tenantID, id string, input *ResolveConversationInput) (*entity.Conversation, error) {
+	conversation, err := s.conversations.FindByID(ctx, tenantID, id)
 	if err != nil {
-		return nil, errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+		return nil, err
 	}
 
 	if conversation.Status == entity.ConversationStatusResolved {
 		return nil, errors.Validation("conversation is already resolved")
 	}
 
+	var disposition *entity.ConversationDisposition
+	if s.dispositions != nil {
+		disposition, err = s.dispositions.Prepare(ctx, conversation, input)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	conversation.Resolve()
 	conversation.UpdatedAt = time.Now()
 
@@ -209,20 +292,57 @@ func (s *ConversationService) Resolve(ctx context.Context, id string) (*entity.C
 		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to resolve conversation")
 	}
 
+	if disposition != nil {
+		s.dispositions.Save(ctx, disposition)
+	}
+
+	if s.watchService != nil {
+		s.watchService.Notify(ctx, conversation, &entity.WatchActivity{Type: entity.WatchActivityResolved})
+	}
+
+	if s.summaryService != nil {
+		s.summaryService.HandleResolved(ctx, conversation)
+	}
+
+	if s.experiments != nil {
+		s.experiments.RecordResolution(ctx, conversation.ID)
+	}
+
+	if s.producer != nil {
+		payload := map[string]interface{}{
+			"conversation_id": conversation.ID,
+			"channel_id":      conversation.ChannelID,
+			"contact_id":      conversation.ContactID,
+		}
+		if conversation.AssignedUserID != nil {
+			payload["assigned_user_id"] = *conversation.AssignedUserID
+		}
+		if disposition != nil && disposition.Code != "" {
+			payload["disposition_code"] = disposition.Code
+		}
+		s.producer.PublishEvent(ctx, &nats.Event{
+			Type:      nats.EventConversationResolved,
+			TenantID:  conversation.TenantID,
+			Payload:   payload,
+			Timestamp: time.Now(),
+		})
+	}
+
 	return conversation, nil
 }
 
-// Reopen reopens a resolved conversation
-func (s *ConversationService) Reopen(ctx context.Context, id string) (*entity.Conversation, error) {
-	conversation, err := s.conversationRepo.FindByID(ctx, id)
+// Reopen reopens a resolved conversation on behalf of an agent
+func (s *ConversationService) Reopen(ctx context.Context, tenantID, id, reopenedBy string) (*entity.Conversation, error) {
+	conversation, err := s.conversations.FindByID(ctx, tenantID, id)
 	if err != nil {
-		return nil, errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+		return nil, err
 	}
 
 	if conversation.IsOpen() {
 		return nil, errors.Validation("conversation is already open")
 	}
 
+	resolvedAt := conversation.ResolvedAt
 	conversation.Reopen()
 	conversation.UpdatedAt = time.Now()
 
@@ -230,5 +350,15 @@ func (s *ConversationService) Reopen(ctx context.Context, id string) (*entity.Co
 		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to reopen conversation")
 	}
 
+	if s.watchService != nil {
+		s.watchService.Notify(ctx, conversation, &entity.WatchActivity{Type: entity.WatchActivityReopened})
+	}
+
+	if s.reopens != nil && resolvedAt != nil {
+		if err := s.reopens.Record(ctx, conversation, *resolvedAt, entity.ConversationReopenAgent, reopenedBy); err != nil {
+			logger.Warn("Failed to record conversation reopen", zap.String("conversation_id", id), zap.Error(err))
+		}
+	}
+
 	return conversation, nil
 }
@@ -0,0 +1,794 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Events published by the conversation forms module
+const (
+	EventFormSubmitted = "form.submitted"
+)
+
+// formPrefillMessageLimit caps the transcript sent to the AI for pre-fill
+const formPrefillMessageLimit = 100
+
+var (
+	formFieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+	formPhonePattern    = regexp.MustCompile(`^\+?[0-9 ().-]{6,20}$`)
+)
+
+// ConversationFormInput represents input for creating or updating a form
+type ConversationFormInput struct {
+	Name        string             `json:"name" binding:"required"`
+	Description string             `json:"description"`
+	Fields      []entity.FormField `json:"fields" binding:"required"`
+	AIPrefill   bool               `json:"ai_prefill"`
+	IsActive    *bool              `json:"is_active,omitempty"`
+}
+
+// StartFormSubmissionInput represents input for starting a form on a conversation
+type StartFormSubmissionInput struct {
+	FormID  string                 `json:"form_id" binding:"required"`
+	Values  map[string]interface{} `json:"values,omitempty"`
+	Prefill *bool                  `json:"prefill,omitempty"` // Defaults to the form's ai_prefill setting
+}
+
+// FormValuesInput represents field values entered by an agent. A null value clears the field.
+type FormValuesInput struct {
+	Values map[string]interface{} `json:"values"`
+}
+
+// ConversationFormService manages forms agents fill during conversations. Submissions
+// start as drafts that accept partial values, optionally pre-filled by the AI from the
+// transcript, and are fully validated when submitted.
+type ConversationFormService struct {
+	repo             repository.ConversationFormRepository
+	conversationRepo repository.ConversationRepository
+	messageRepo      repository.MessageRepository
+	aiFactory        *AIProviderFactory
+	producer         nats.Publisher
+}
+
+// NewConversationFormService creates a new conversation form service
+func NewConversationFormService(
+	repo repository.ConversationFormRepository,
+	conversationRepo repository.ConversationRepository,
+	messageRepo repository.MessageRepository,
+	aiFactory *AIProviderFactory,
+	producer nats.Publisher,
+) *ConversationFormService {
+	return &ConversationFormService{
+		repo:             repo,
+		conversationRepo: conversationRepo,
+		messageRepo:      messageRepo,
+		aiFactory:        aiFactory,
+		producer:         producer,
+	}
+}
+
+// CreateForm creates a new form
+func (s *ConversationFormService) CreateForm(ctx context.Context, tenantID string, input *ConversationFormInput) (*entity.ConversationForm, error) {
+	if err := validateFormDefinition(input); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	form := &entity.ConversationForm{
+		ID:          uuid.New().String(),
+		TenantID:    tenantID,
+		Name:        input.Name,
+		Description: input.Description,
+		Fields:      input.Fields,
+		AIPrefill:   input.AIPrefill,
+		IsActive:    input.IsActive == nil || *input.IsActive,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.repo.CreateForm(ctx, form); err != nil {
+		return nil, err
+	}
+	return form, nil
+}
+
+// GetForm returns a form of a tenant
+func (s *ConversationFormService) GetForm(ctx context.Context, tenantID, id string) (*entity.ConversationForm, error) {
+	form, err := s.repo.FindFormByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if form.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeNotFound, "form not found")
+	}
+	return form, nil
+}
+
+// ListForms lists the forms of a tenant
+func (s *ConversationFormService) ListForms(ctx context.Context, tenantID string) ([]*entity.ConversationForm, error) {
+	return s.repo.ListForms(ctx, tenantID)
+}
+
+// UpdateForm updates a form. Existing submissions keep their values; fields removed
+// from the form are no longer exported.
+func (s *ConversationFormService) UpdateForm(ctx context.Context, tenantID, id string, input *ConversationFormInput) (*entity.ConversationForm, error) {
+	if err := validateFormDefinition(input); err != nil {
+		return nil, err
+	}
+
+	form, err := s.GetForm(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	form.Name = input.Name
+	form.Description = input.Description
+	form.Fields = input.Fields
+	form.AIPrefill = input.AIPrefill
+	if input.IsActive != nil {
+		form.IsActive = *input.IsActive
+	}
+	form.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdateForm(ctx, form); err != nil {
+		return nil, err
+	}
+	return form, nil
+}
+
+// DeleteForm deletes a form and its submissions
+func (s *ConversationFormService) DeleteForm(ctx context.Context, tenantID, id string) error {
+	if _, err := s.GetForm(ctx, tenantID, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteForm(ctx, id)
+}
+
+// StartSubmission starts a draft of a form on a conversation. When pre-fill is
+// enabled the AI fills what it can find in the transcript; values entered by the
+// agent take precedence. A failed pre-fill is logged and the draft still created.
+func (s *ConversationFormService) StartSubmission(ctx context.Context, tenantID, userID, conversationID string, input *StartFormSubmissionInput) (*entity.FormSubmission, error) {
+	form, err := s.GetForm(ctx, tenantID, input.FormID)
+	if err != nil {
+		return nil, err
+	}
+	if !form.IsActive {
+		return nil, errors.Validation("form is not active")
+	}
+
+	conversation, err := s.conversationRepo.FindByID(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeNotFound, "conversation not found")
+	}
+
+	values, err := validateFormValues(form, input.Values, false)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	submission := &entity.FormSubmission{
+		ID:             uuid.New().String(),
+		TenantID:       tenantID,
+		FormID:         form.ID,
+		ConversationID: conversation.ID,
+		ContactID:      conversation.ContactID,
+		Values:         values,
+		Status:         entity.FormSubmissionStatusDraft,
+		CreatedBy:      userID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	prefill := form.AIPrefill
+	if input.Prefill != nil {
+		prefill = *input.Prefill
+	}
+	if prefill {
+		if err := s.applyPrefill(ctx, form, submission); err != nil {
+			logger.Warn("Form AI pre-fill failed",
+				zap.String("form_id", form.ID),
+				zap.String("conversation_id", conversation.ID),
+				zap.Error(err),
+			)
+		}
+	}
+
+	if err := s.repo.CreateSubmission(ctx, submission); err != nil {
+		return nil, err
+	}
+	return submission, nil
+}
+
+// GetSubmission returns a submission of a tenant
+func (s *ConversationFormService) GetSubmission(ctx context.Context, tenantID, id string) (*entity.FormSubmission, error) {
+	submission, err := s.repo.FindSubmissionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if submission.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeNotFound, "form submission not found")
+	}
+	return submission, nil
+}
+
+// ListSubmissions lists the submissions of a tenant
+func (s *ConversationFormService) ListSubmissions(ctx context.Context, tenantID string, filter *entity.FormSubmissionFilter, params *repository.ListParams) ([]*entity.FormSubmission, int64, error) {
+	return s.repo.ListSubmissions(ctx, tenantID, filter, params)
+}
+
+// UpdateSubmission merges values into a draft
+func (s *ConversationFormService) UpdateSubmission(ctx context.Context, tenantID, id string, input *FormValuesInput) (*entity.FormSubmission, error) {
+	submission, form, err := s.getDraft(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := validateFormValues(form, mergeFormValues(submission.Values, input.Values), false)
+	if err != nil {
+		return nil, err
+	}
+	submission.Values = values
+	submission.AIPrefilledFields = withoutKeys(submission.AIPrefilledFields, input.Values)
+	submission.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdateSubmission(ctx, submission); err != nil {
+		return nil, err
+	}
+	return submission, nil
+}
+
+// Submit validates a draft with all required fields and marks it submitted
+func (s *ConversationFormService) Submit(ctx context.Context, tenantID, userID, id string, input *FormValuesInput) (*entity.FormSubmission, error) {
+	submission, form, err := s.getDraft(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes map[string]interface{}
+	if input != nil {
+		changes = input.Values
+	}
+	values, err := validateFormValues(form, mergeFormValues(submission.Values, changes), true)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	submission.Values = values
+	submission.AIPrefilledFields = withoutKeys(submission.AIPrefilledFields, changes)
+	submission.Status = entity.FormSubmissionStatusSubmitted
+	submission.SubmittedBy = userID
+	submission.SubmittedAt = &now
+	submission.UpdatedAt = now
+
+	if err := s.repo.UpdateSubmission(ctx, submission); err != nil {
+		return nil, err
+	}
+
+	s.publishEvent(ctx, EventFormSubmitted, submission)
+	return submission, nil
+}
+
+// Prefill asks the AI to fill the empty fields of a draft from the conversation transcript
+func (s *ConversationFormService) Prefill(ctx context.Context, tenantID, id string) (*entity.FormSubmission, error) {
+	submission, form, err := s.getDraft(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyPrefill(ctx, form, submission); err != nil {
+		return nil, err
+	}
+	submission.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdateSubmission(ctx, submission); err != nil {
+		return nil, err
+	}
+	return submission, nil
+}
+
+// DeleteSubmission deletes a submission
+func (s *ConversationFormService) DeleteSubmission(ctx context.Context, tenantID, id string) error {
+	if _, err := s.GetSubmission(ctx, tenantID, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteSubmission(ctx, id)
+}
+
+// ExportCSV exports the submissions of a form with one column per field
+func (s *ConversationFormService) ExportCSV(ctx context.Context, tenantID, formID string, filter *entity.FormSubmissionFilter) ([]byte, error) {
+	form, err := s.GetForm(ctx, tenantID, formID)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter == nil {
+		filter = &entity.FormSubmissionFilter{}
+	}
+	filter.FormID = form.ID
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	header := []string{"Submission ID", "Conversation ID", "Contact ID", "Status", "Created At", "Submitted By", "Submitted At"}
+	for _, field := range form.Fields {
+		header = append(header, field.Label)
+	}
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+
+	params := &repository.ListParams{Page: 1, PageSize: 500}
+	for {
+		submissions, total, err := s.repo.ListSubmissions(ctx, tenantID, filter, params)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, submission := range submissions {
+			submittedAt := ""
+			if submission.SubmittedAt != nil {
+				submittedAt = submission.SubmittedAt.UTC().Format(time.RFC3339)
+			}
+			row := []string{
+				submission.ID,
+				submission.ConversationID,
+				submission.ContactID,
+				string(submission.Status),
+				submission.CreatedAt.UTC().Format(time.RFC3339),
+				submission.SubmittedBy,
+				submittedAt,
+			}
+			for _, field := range form.Fields {
+				row = append(row, formatFormValue(submission.Values[field.Key]))
+			}
+			if err := writer.Write(row); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(submissions) == 0 || int64(params.Page*params.PageSize) >= total {
+			break
+		}
+		params.Page++
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to export form submissions")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// getDraft returns a draft submission with its form
+func (s *ConversationFormService) getDraft(ctx context.Context, tenantID, id string) (*entity.FormSubmission, *entity.ConversationForm, error) {
+	submission, err := s.GetSubmission(ctx, tenantID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if submission.IsSubmitted() {
+		return nil, nil, errors.New(errors.ErrCodeConflict, "form submission was already submitted")
+	}
+
+	form, err := s.GetForm(ctx, tenantID, submission.FormID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return submission, form, nil
+}
+
+// applyPrefill fills the empty fields of a submission with values the AI extracted
+// from the transcript. Values that fail the field validation are discarded.
+func (s *ConversationFormService) applyPrefill(ctx context.Context, form *entity.ConversationForm, submission *entity.FormSubmission) error {
+	var empty []entity.FormField
+	for _, field := range form.Fields {
+		if _, ok := submission.Values[field.Key]; !ok {
+			empty = append(empty, field)
+		}
+	}
+	if len(empty) == 0 {
+		return nil
+	}
+
+	ctx = WithAIUsage(ctx, submission.TenantID, "", entity.AIFeatureExtraction)
+	extracted, err := s.extractFromTranscript(ctx, submission.ConversationID, empty)
+	if err != nil {
+		return err
+	}
+
+	for _, field := range empty {
+		raw, ok := extracted[field.Key]
+		if !ok {
+			continue
+		}
+		value, problem := normalizeFormValue(&field, raw)
+		if problem != "" || value == nil {
+			continue
+		}
+		submission.Values[field.Key] = value
+		submission.AIPrefilledFields = append(submission.AIPrefilledFields, field.Key)
+	}
+	return nil
+}
+
+// extractFromTranscript asks the AI for the values of fields found in a conversation
+func (s *ConversationFormService) extractFromTranscript(ctx context.Context, conversationID string, fields []entity.FormField) (map[string]interface{}, error) {
+	provider, err := s.aiFactory.FirstAvailable()
+	if err != nil {
+		return nil, err
+	}
+
+	messages, _, err := s.messageRepo.FindByConversation(ctx, conversationID, &repository.ListParams{
+		Page:     1,
+		PageSize: formPrefillMessageLimit,
+		SortBy:   "created_at",
+		SortDir:  "desc",
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(messages, func(i, j int) bool { return messages[i].CreatedAt.Before(messages[j].CreatedAt) })
+
+	var transcript strings.Builder
+	for _, msg := range messages {
+		if msg.Content == "" {
+			continue
+		}
+		role := "Customer"
+		switch msg.SenderType {
+		case entity.SenderTypeUser:
+			role = "Agent"
+		case entity.SenderTypeBot:
+			role = "Bot"
+		case entity.SenderTypeSystem:
+			continue
+		}
+		fmt.Fprintf(&transcript, "%s: %s\n", role, msg.Content)
+	}
+	if transcript.Len() == 0 {
+		return map[string]interface{}{}, nil
+	}
+
+	var spec strings.Builder
+	for _, field := range fields {
+		fmt.Fprintf(&spec, "- %s (%s): %s", field.Key, field.Type, field.Label)
+		if len(field.Options) > 0 {
+			fmt.Fprintf(&spec, ". One of: %s", strings.Join(field.Options, " | "))
+		}
+		if field.Type == entity.FormFieldDate {
+			spec.WriteString(". Format YYYY-MM-DD")
+		}
+		if field.AIHint != "" {
+			fmt.Fprintf(&spec, ". %s", field.AIHint)
+		}
+		spec.WriteString("\n")
+	}
+
+	prompt := fmt.Sprintf(`Extract the following fields from the customer service conversation below.
+Reply with a single JSON object whose keys are the field keys. Use null for any value the
+conversation does not state explicitly; do not guess. Multiselect values are arrays and
+checkbox values are booleans.
+
+Fields:
+%s
+Conversation:
+%s`, spec.String(), transcript.String())
+
+	resp, err := provider.Complete(ctx, &CompletionRequest{
+		Messages: []Message{
+			{Role: "system", Content: "You extract structured data from customer service conversations and reply only with JSON."},
+			{Role: "user", Content: prompt},
+		},
+		Model:       provider.DefaultModel(),
+		MaxTokens:   800,
+		Temperature: 0,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to pre-fill form")
+	}
+
+	content := resp.Content
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, errors.New(errors.ErrCodeInternal, "AI pre-fill returned no JSON object")
+	}
+
+	var values map[string]interface{}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &values); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to parse AI pre-fill")
+	}
+	return values, nil
+}
+
+func (s *ConversationFormService) publishEvent(ctx context.Context, eventType string, submission *entity.FormSubmission) {
+	if s.producer == nil {
+		return
+	}
+	s.producer.PublishEvent(ctx, &nats.Event{
+		Type:     eventType,
+		TenantID: submission.TenantID,
+		Payload: map[string]interface{}{
+			"submission_id":   submission.ID,
+			"form_id":         submission.FormID,
+			"conversation_id": submission.ConversationID,
+			"contact_id":      submission.ContactID,
+			"values":          submission.Values,
+		},
+		Timestamp: time.Now(),
+	})
+}
+
+// validateFormDefinition checks the fields of a form
+func validateFormDefinition(input *ConversationFormInput) error {
+	if input.Name == "" {
+		return errors.Validation("name is required")
+	}
+	if len(input.Fields) == 0 {
+		return errors.Validation("at least one field is required")
+	}
+
+	keys := make(map[string]bool, len(input.Fields))
+	for _, field := range input.Fields {
+		if !formFieldKeyPattern.MatchString(field.Key) {
+			return errors.Validation(fmt.Sprintf("field key %q must be lowercase letters, digits and underscores", field.Key))
+		}
+		if keys[field.Key] {
+			return errors.Validation(fmt.Sprintf("duplicate field key %q", field.Key))
+		}
+		keys[field.Key] = true
+
+		if field.Label == "" {
+			return errors.Validation(fmt.Sprintf("field %q needs a label", field.Key))
+		}
+		if !field.Type.IsValid() {
+			return errors.Validation(fmt.Sprintf("field %q has unknown type %q", field.Key, field.Type))
+		}
+		if (field.Type == entity.FormFieldSelect || field.Type == entity.FormFieldMultiSelect) && len(field.Options) == 0 {
+			return errors.Validation(fmt.Sprintf("field %q needs options", field.Key))
+		}
+		if field.Pattern != "" {
+			if _, err := regexp.Compile(field.Pattern); err != nil {
+				return errors.Validation(fmt.Sprintf("field %q has an invalid pattern", field.Key))
+			}
+		}
+		if field.MaxLength > 0 && field.MinLength > field.MaxLength {
+			return errors.Validation(fmt.Sprintf("field %q has min_length above max_length", field.Key))
+		}
+		if field.Min != nil && field.Max != nil && *field.Min > *field.Max {
+			return errors.Validation(fmt.Sprintf("field %q has min above max", field.Key))
+		}
+	}
+	return nil
+}
+
+// validateFormValues normalizes values against the form fields. Drafts accept
+// missing required fields; complete submissions do not. All problems are returned
+// together as validation details keyed by field.
+func validateFormValues(form *entity.ConversationForm, values map[string]interface{}, complete bool) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	details := make(map[string]string)
+
+	for key := range values {
+		if form.GetField(key) == nil {
+			details[key] = "unknown field"
+		}
+	}
+
+	for i := range form.Fields {
+		field := &form.Fields[i]
+		value, problem := normalizeFormValue(field, values[field.Key])
+		if problem != "" {
+			details[field.Key] = problem
+			continue
+		}
+		if value == nil || value == false {
+			if complete && field.Required {
+				details[field.Key] = "is required"
+				continue
+			}
+		}
+		if value != nil {
+			result[field.Key] = value
+		}
+	}
+
+	if len(details) > 0 {
+		return nil, errors.New(errors.ErrCodeValidation, "Invalid form values").WithDetails(details)
+	}
+	return result, nil
+}
+
+// normalizeFormValue converts a raw JSON value to the field's type. It returns nil
+// for empty values, or a problem description when the value is invalid.
+func normalizeFormValue(field *entity.FormField, raw interface{}) (interface{}, string) {
+	if raw == nil {
+		return nil, ""
+	}
+
+	switch field.Type {
+	case entity.FormFieldNumber:
+		var number float64
+		switch v := raw.(type) {
+		case float64:
+			number = v
+		case string:
+			if strings.TrimSpace(v) == "" {
+				return nil, ""
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, "must be a number"
+			}
+			number = parsed
+		default:
+			return nil, "must be a number"
+		}
+		if field.Min != nil && number < *field.Min {
+			return nil, fmt.Sprintf("must be at least %v", *field.Min)
+		}
+		if field.Max != nil && number > *field.Max {
+			return nil, fmt.Sprintf("must be at most %v", *field.Max)
+		}
+		return number, ""
+
+	case entity.FormFieldCheckbox:
+		switch v := raw.(type) {
+		case bool:
+			return v, ""
+		case string:
+			parsed, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, "must be true or false"
+			}
+			return parsed, ""
+		}
+		return nil, "must be true or false"
+
+	case entity.FormFieldMultiSelect:
+		var items []interface{}
+		switch v := raw.(type) {
+		case []interface{}:
+			items = v
+		case []string:
+			for _, item := range v {
+				items = append(items, item)
+			}
+		default:
+			return nil, "must be a list of options"
+		}
+		selected := make([]string, 0, len(items))
+		for _, item := range items {
+			option, ok := item.(string)
+			if !ok || !field.HasOption(option) {
+				return nil, fmt.Sprintf("%v is not an option", item)
+			}
+			selected = append(selected, option)
+		}
+		if len(selected) == 0 {
+			return nil, ""
+		}
+		return selected, ""
+	}
+
+	var text string
+	switch v := raw.(type) {
+	case string:
+		text = strings.TrimSpace(v)
+	case float64:
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return nil, "must be text"
+	}
+	if text == "" {
+		return nil, ""
+	}
+
+	length := utf8.RuneCountInString(text)
+	if field.MinLength > 0 && length < field.MinLength {
+		return nil, fmt.Sprintf("must have at least %d characters", field.MinLength)
+	}
+	if field.MaxLength > 0 && length > field.MaxLength {
+		return nil, fmt.Sprintf("must have at most %d characters", field.MaxLength)
+	}
+	if field.Pattern != "" {
+		if pattern, err := regexp.Compile(field.Pattern); err == nil && !pattern.MatchString(text) {
+			return nil, "has an invalid format"
+		}
+	}
+
+	switch field.Type {
+	case entity.FormFieldEmail:
+		if address, err := mail.ParseAddress(text); err != nil || address.Address != text {
+			return nil, "must be an email address"
+		}
+	case entity.FormFieldPhone:
+		if !formPhonePattern.MatchString(text) {
+			return nil, "must be a phone number"
+		}
+	case entity.FormFieldDate:
+		if _, err := time.Parse("2006-01-02", text); err != nil {
+			return nil, "must be a date (YYYY-MM-DD)"
+		}
+	case entity.FormFieldSelect:
+		if !field.HasOption(text) {
+			return nil, "is not an option"
+		}
+	}
+	return text, ""
+}
+
+// mergeFormValues applies changes over existing values; null changes clear a field
+func mergeFormValues(existing, changes map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(existing)+len(changes))
+	for key, value := range existing {
+		merged[key] = value
+	}
+	for key, value := range changes {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
+
+// withoutKeys drops the fields an agent edited from the AI pre-filled list
+func withoutKeys(keys []string, changes map[string]interface{}) []string {
+	if len(changes) == 0 {
+		return keys
+	}
+	result := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if _, changed := changes[key]; !changed {
+			result = append(result, key)
+		}
+	}
+	return result
+}
+
+// formatFormValue renders a submission value for CSV export
+func formatFormValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []string:
+		return strings.Join(v, "; ")
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, fmt.Sprint(item))
+		}
+		return strings.Join(parts, "; ")
+	}
+	return fmt.Sprint(value)
+}
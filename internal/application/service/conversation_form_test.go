@@ -0,0 +1,299 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockConversationFormRepo struct {
+	forms       map[string]*entity.ConversationForm
+	submissions map[string]*entity.FormSubmission
+}
+
+func newMockConversationFormRepo() *mockConversationFormRepo {
+	return &mockConversationFormRepo{
+		forms:       make(map[string]*entity.ConversationForm),
+		submissions: make(map[string]*entity.FormSubmission),
+	}
+}
+
+func (m *mockConversationFormRepo) CreateForm(ctx context.Context, form *entity.ConversationForm) error {
+	m.forms[form.ID] = form
+	return nil
+}
+
+func (m *mockConversationFormRepo) FindFormByID(ctx context.Context, id string) (*entity.ConversationForm, error) {
+	if form, ok := m.forms[id]; ok {
+		return form, nil
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "form not found")
+}
+
+func (m *mockConversationFormRepo) ListForms(ctx context.Context, tenantID string) ([]*entity.ConversationForm, error) {
+	var result []*entity.ConversationForm
+	for _, form := range m.forms {
+		if form.TenantID == tenantID {
+			result = append(result, form)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockConversationFormRepo) UpdateForm(ctx context.Context, form *entity.ConversationForm) error {
+	m.forms[form.ID] = form
+	return nil
+}
+
+func (m *mockConversationFormRepo) DeleteForm(ctx context.Context, id string) error {
+	delete(m.forms, id)
+	return nil
+}
+
+func (m *mockConversationFormRepo) CreateSubmission(ctx context.Context, submission *entity.FormSubmission) error {
+	m.submissions[submission.ID] = submission
+	return nil
+}
+
+func (m *mockConversationFormRepo) FindSubmissionByID(ctx context.Context, id string) (*entity.FormSubmission, error) {
+	if submission, ok := m.submissions[id]; ok {
+		return submission, nil
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "form submission not found")
+}
+
+func (m *mockConversationFormRepo) ListSubmissions(ctx context.Context, tenantID string, filter *entity.FormSubmissionFilter, params *repository.ListParams) ([]*entity.FormSubmission, int64, error) {
+	var result []*entity.FormSubmission
+	for _, submission := range m.submissions {
+		if submission.TenantID != tenantID {
+			continue
+		}
+		if filter != nil && filter.FormID != "" && submission.FormID != filter.FormID {
+			continue
+		}
+		if filter != nil && filter.Status != "" && submission.Status != filter.Status {
+			continue
+		}
+		result = append(result, submission)
+	}
+	return result, int64(len(result)), nil
+}
+
+func (m *mockConversationFormRepo) UpdateSubmission(ctx context.Context, submission *entity.FormSubmission) error {
+	m.submissions[submission.ID] = submission
+	return nil
+}
+
+func (m *mockConversationFormRepo) DeleteSubmission(ctx context.Context, id string) error {
+	delete(m.submissions, id)
+	return nil
+}
+
+// extractingAIProvider answers every completion with a fixed reply and keeps the prompt
+type extractingAIProvider struct {
+	mockAIProvider
+	reply  string
+	prompt string
+}
+
+func (p *extractingAIProvider) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	p.prompt = req.Messages[len(req.Messages)-1].Content
+	return &CompletionResponse{Content: p.reply}, nil
+}
+
+func complaintFormInput() *ConversationFormInput {
+	maxAmount := 10000.0
+	return &ConversationFormInput{
+		Name: "Complaint intake",
+		Fields: []entity.FormField{
+			{Key: "order_number", Label: "Order number", Type: entity.FormFieldText, Required: true, Pattern: `^[0-9]{6}$`},
+			{Key: "email", Label: "Email", Type: entity.FormFieldEmail},
+			{Key: "category", Label: "Category", Type: entity.FormFieldSelect, Required: true, Options: []string{"delay", "damage", "billing"}},
+			{Key: "amount", Label: "Amount", Type: entity.FormFieldNumber, Max: &maxAmount},
+			{Key: "channels", Label: "Reached via", Type: entity.FormFieldMultiSelect, Options: []string{"phone", "chat", "email"}},
+			{Key: "refund", Label: "Refund requested", Type: entity.FormFieldCheckbox},
+		},
+	}
+}
+
+type formTestEnv struct {
+	svc      *ConversationFormService
+	repo     *mockConversationFormRepo
+	messages *testutil.MockMessageRepository
+	producer *testutil.MockProducer
+	ai       *extractingAIProvider
+	form     *entity.ConversationForm
+}
+
+func newFormTestEnv(t *testing.T) *formTestEnv {
+	repo := newMockConversationFormRepo()
+	conversations := testutil.NewMockConversationRepository()
+	conversations.Conversations["conv-1"] = &entity.Conversation{ID: "conv-1", TenantID: "tenant-1", ContactID: "contact-1"}
+	messages := testutil.NewMockMessageRepository()
+	producer := testutil.NewMockProducer()
+
+	ai := &extractingAIProvider{mockAIProvider: mockAIProvider{name: entity.AIProviderOpenAI, available: true}}
+	factory := NewAIProviderFactory()
+	factory.Register(ai)
+
+	svc := NewConversationFormService(repo, conversations, messages, factory, producer)
+	form, err := svc.CreateForm(context.Background(), "tenant-1", complaintFormInput())
+	require.NoError(t, err)
+
+	return &formTestEnv{svc: svc, repo: repo, messages: messages, producer: producer, ai: ai, form: form}
+}
+
+func TestConversationFormDefinitionValidation(t *testing.T) {
+	env := newFormTestEnv(t)
+	ctx := context.Background()
+
+	cases := map[string]func(*ConversationFormInput){
+		"bad key":        func(in *ConversationFormInput) { in.Fields[0].Key = "Order Number" },
+		"duplicate key":  func(in *ConversationFormInput) { in.Fields[1].Key = "order_number" },
+		"unknown type":   func(in *ConversationFormInput) { in.Fields[0].Type = "color" },
+		"select options": func(in *ConversationFormInput) { in.Fields[2].Options = nil },
+		"bad pattern":    func(in *ConversationFormInput) { in.Fields[0].Pattern = "([" },
+		"no fields":      func(in *ConversationFormInput) { in.Fields = nil },
+	}
+	for name, mutate := range cases {
+		input := complaintFormInput()
+		mutate(input)
+		_, err := env.svc.CreateForm(ctx, "tenant-1", input)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestFormSubmissionDraftAndSubmit(t *testing.T) {
+	env := newFormTestEnv(t)
+	ctx := context.Background()
+
+	submission, err := env.svc.StartSubmission(ctx, "tenant-1", "agent-1", "conv-1", &StartFormSubmissionInput{
+		FormID: env.form.ID,
+		Values: map[string]interface{}{"order_number": "123456", "amount": "49.90"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, entity.FormSubmissionStatusDraft, submission.Status)
+	assert.Equal(t, "contact-1", submission.ContactID)
+	assert.Equal(t, 49.9, submission.Values["amount"])
+
+	// Drafts still reject invalid values, with details per field
+	_, err = env.svc.UpdateSubmission(ctx, "tenant-1", submission.ID, &FormValuesInput{
+		Values: map[string]interface{}{"email": "not-an-email", "category": "other", "color": "red"},
+	})
+	require.Error(t, err)
+	details := errors.GetAppError(err).Details
+	assert.Contains(t, details, "email")
+	assert.Contains(t, details, "category")
+	assert.Contains(t, details, "color")
+
+	// Submitting requires the required fields
+	_, err = env.svc.Submit(ctx, "tenant-1", "agent-1", submission.ID, nil)
+	require.Error(t, err)
+	assert.Equal(t, "is required", errors.GetAppError(err).Details["category"])
+
+	submission, err = env.svc.Submit(ctx, "tenant-1", "agent-1", submission.ID, &FormValuesInput{
+		Values: map[string]interface{}{"category": "damage", "channels": []interface{}{"chat", "email"}, "amount": nil},
+	})
+	require.NoError(t, err)
+	assert.True(t, submission.IsSubmitted())
+	assert.Equal(t, "agent-1", submission.SubmittedBy)
+	assert.NotContains(t, submission.Values, "amount")
+	assert.Equal(t, []string{"chat", "email"}, submission.Values["channels"])
+
+	require.Len(t, env.producer.Events, 1)
+	assert.Equal(t, EventFormSubmitted, env.producer.Events[0].Type)
+
+	_, err = env.svc.UpdateSubmission(ctx, "tenant-1", submission.ID, &FormValuesInput{})
+	assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code)
+}
+
+func TestFormSubmissionRejectsOtherTenantConversation(t *testing.T) {
+	env := newFormTestEnv(t)
+
+	_, err := env.svc.StartSubmission(context.Background(), "tenant-2", "agent-1", "conv-1", &StartFormSubmissionInput{FormID: env.form.ID})
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestFormSubmissionAIPrefill(t *testing.T) {
+	env := newFormTestEnv(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	env.messages.Messages["m1"] = &entity.Message{ID: "m1", ConversationID: "conv-1", SenderType: entity.SenderTypeContact, Content: "My order 654321 arrived broken", CreatedAt: now}
+	env.messages.Messages["m2"] = &entity.Message{ID: "m2", ConversationID: "conv-1", SenderType: entity.SenderTypeUser, Content: "Sorry! What is your email?", CreatedAt: now.Add(time.Minute)}
+	env.ai.reply = "Here you go:\n```json\n" + `{"order_number": "654321", "category": "damage", "email": null, "amount": "lots", "refund": true}` + "\n```"
+
+	prefill := true
+	submission, err := env.svc.StartSubmission(ctx, "tenant-1", "agent-1", "conv-1", &StartFormSubmissionInput{
+		FormID:  env.form.ID,
+		Values:  map[string]interface{}{"category": "billing"},
+		Prefill: &prefill,
+	})
+	require.NoError(t, err)
+
+	assert.Contains(t, env.ai.prompt, "Customer: My order 654321 arrived broken\nAgent: Sorry!")
+	assert.NotContains(t, env.ai.prompt, "- category")
+
+	// Agent values win, invalid AI values are dropped
+	assert.Equal(t, "billing", submission.Values["category"])
+	assert.Equal(t, "654321", submission.Values["order_number"])
+	assert.Equal(t, true, submission.Values["refund"])
+	assert.NotContains(t, submission.Values, "amount")
+	assert.ElementsMatch(t, []string{"order_number", "refund"}, submission.AIPrefilledFields)
+
+	// Editing a pre-filled field makes it the agent's value
+	submission, err = env.svc.UpdateSubmission(ctx, "tenant-1", submission.ID, &FormValuesInput{
+		Values: map[string]interface{}{"order_number": "654322"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"refund"}, submission.AIPrefilledFields)
+}
+
+func TestFormPrefillWithoutProvider(t *testing.T) {
+	env := newFormTestEnv(t)
+	env.svc.aiFactory = NewAIProviderFactory()
+	ctx := context.Background()
+
+	// A failed pre-fill does not prevent the draft
+	prefill := true
+	submission, err := env.svc.StartSubmission(ctx, "tenant-1", "agent-1", "conv-1", &StartFormSubmissionInput{FormID: env.form.ID, Prefill: &prefill})
+	require.NoError(t, err)
+
+	_, err = env.svc.Prefill(ctx, "tenant-1", submission.ID)
+	assert.Error(t, err)
+}
+
+func TestFormExportCSV(t *testing.T) {
+	env := newFormTestEnv(t)
+	ctx := context.Background()
+
+	submission, err := env.svc.StartSubmission(ctx, "tenant-1", "agent-1", "conv-1", &StartFormSubmissionInput{
+		FormID: env.form.ID,
+		Values: map[string]interface{}{
+			"order_number": "123456",
+			"category":     "delay",
+			"channels":     []interface{}{"phone", "chat"},
+			"refund":       false,
+		},
+	})
+	require.NoError(t, err)
+
+	data, err := env.svc.ExportCSV(ctx, "tenant-1", env.form.ID, nil)
+	require.NoError(t, err)
+
+	records, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, []string{"Order number", "Email", "Category", "Amount", "Reached via", "Refund requested"}, records[0][7:])
+	assert.Equal(t, submission.ID, records[1][0])
+	assert.Equal(t, []string{"123456", "", "delay", "", "phone; chat", "false"}, records[1][7:])
+}
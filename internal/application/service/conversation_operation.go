@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// MergeConversationsInput identifies the conversation folded into another
+type MergeConversationsInput struct {
+	SourceConversationID string `json:"source_conversation_id" binding:"required"`
+	Reason               string `json:"reason"`
+}
+
+// SplitConversationInput lists the messages moved into a new conversation
+type SplitConversationInput struct {
+	MessageIDs []string `json:"message_ids" binding:"required"`
+	Subject    string   `json:"subject"`
+	Reason     string   `json:"reason"`
+}
+
+// ConversationSplitResult is the conversation created by a split and its audit record
+type ConversationSplitResult struct {
+	Conversation *entity.Conversation          `json:"conversation"`
+	Operation    *entity.ConversationOperation `json:"operation"`
+}
+
+// ConversationOperationService merges duplicate conversations of a contact and splits
+// new topics off into their own conversation. Messages keep their IDs and timestamps,
+// so ordering and reply references survive the move.
+type ConversationOperationService struct {
+	repo             repository.ConversationOperationRepository
+	conversationRepo repository.ConversationRepository
+	messageRepo      repository.MessageRepository
+}
+
+// NewConversationOperationService creates a new conversation operation service
+func NewConversationOperationService(
+	repo repository.ConversationOperationRepository,
+	conversationRepo repository.ConversationRepository,
+	messageRepo repository.MessageRepository,
+) *ConversationOperationService {
+	return &ConversationOperationService{
+		repo:             repo,
+		conversationRepo: conversationRepo,
+		messageRepo:      messageRepo,
+	}
+}
+
+// Merge moves the messages of the source conversation into the target and closes the
+// source. Both conversations must belong to the same contact; they may be on different
+// channels, in which case replies continue on the target's channel.
+func (s *ConversationOperationService) Merge(ctx context.Context, tenantID, targetID, actorID string, input *MergeConversationsInput) (*entity.ConversationOperation, error) {
+	if input.SourceConversationID == targetID {
+		return nil, errors.Validation("a conversation cannot be merged into itself")
+	}
+
+	target, err := s.tenantConversation(ctx, tenantID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	source, err := s.tenantConversation(ctx, tenantID, input.SourceConversationID)
+	if err != nil {
+		return nil, err
+	}
+	if source.ContactID != target.ContactID {
+		return nil, errors.Validation("only conversations of the same contact can be merged")
+	}
+	if source.Status == entity.ConversationStatusClosed {
+		return nil, errors.Validation("the source conversation is closed")
+	}
+
+	op := &entity.ConversationOperation{
+		ID:                   uuid.New().String(),
+		TenantID:             tenantID,
+		Type:                 entity.ConversationOperationMerge,
+		SourceConversationID: source.ID,
+		TargetConversationID: target.ID,
+		Reason:               strings.TrimSpace(input.Reason),
+		PerformedBy:          actorID,
+		CreatedAt:            time.Now(),
+	}
+	if err := s.repo.Merge(ctx, op); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+// Split moves the given messages into a new conversation with the same contact,
+// channel, assignee and priority. At least one message must stay behind.
+func (s *ConversationOperationService) Split(ctx context.Context, tenantID, conversationID, actorID string, input *SplitConversationInput) (*ConversationSplitResult, error) {
+	source, err := s.tenantConversation(ctx, tenantID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	messageIDs := make([]string, 0, len(input.MessageIDs))
+	seen := make(map[string]bool, len(input.MessageIDs))
+	for _, id := range input.MessageIDs {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		messageIDs = append(messageIDs, id)
+	}
+	if len(messageIDs) == 0 {
+		return nil, errors.Validation("message_ids is required")
+	}
+
+	for _, id := range messageIDs {
+		message, err := s.messageRepo.FindByID(ctx, id)
+		if err != nil || message.ConversationID != source.ID {
+			return nil, errors.Validation("message " + id + " does not belong to the conversation")
+		}
+	}
+	total, err := s.messageRepo.CountByConversation(ctx, source.ID)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(messageIDs)) >= total {
+		return nil, errors.Validation("at least one message must remain in the conversation")
+	}
+
+	now := time.Now()
+	conversation := &entity.Conversation{
+		ID:             uuid.New().String(),
+		TenantID:       source.TenantID,
+		ChannelID:      source.ChannelID,
+		ContactID:      source.ContactID,
+		AssignedUserID: source.AssignedUserID,
+		Status:         entity.ConversationStatusOpen,
+		Priority:       source.Priority,
+		Subject:        strings.TrimSpace(input.Subject),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	op := &entity.ConversationOperation{
+		ID:                   uuid.New().String(),
+		TenantID:             tenantID,
+		Type:                 entity.ConversationOperationSplit,
+		SourceConversationID: source.ID,
+		TargetConversationID: conversation.ID,
+		MessageIDs:           messageIDs,
+		Reason:               strings.TrimSpace(input.Reason),
+		PerformedBy:          actorID,
+		CreatedAt:            now,
+	}
+	if err := s.repo.Split(ctx, op, conversation); err != nil {
+		return nil, err
+	}
+	return &ConversationSplitResult{Conversation: conversation, Operation: op}, nil
+}
+
+// ListOperations lists the merges and splits involving a conversation
+func (s *ConversationOperationService) ListOperations(ctx context.Context, tenantID, conversationID string) ([]*entity.ConversationOperation, error) {
+	if _, err := s.tenantConversation(ctx, tenantID, conversationID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByConversation(ctx, conversationID)
+}
+
+func (s *ConversationOperationService) tenantConversation(ctx context.Context, tenantID, id string) (*entity.Conversation, error) {
+	conversation, err := s.conversationRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+	}
+	return conversation, nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockConversationOperationRepo applies merges and splits to the in-memory repositories
+type mockConversationOperationRepo struct {
+	conversations *testutil.MockConversationRepository
+	messages      *testutil.MockMessageRepository
+	operations    []*entity.ConversationOperation
+}
+
+func (m *mockConversationOperationRepo) Merge(ctx context.Context, op *entity.ConversationOperation) error {
+	for _, msg := range m.messages.Messages {
+		if msg.ConversationID == op.SourceConversationID {
+			msg.ConversationID = op.TargetConversationID
+			op.MessageIDs = append(op.MessageIDs, msg.ID)
+		}
+	}
+	m.conversations.Conversations[op.SourceConversationID].Status = entity.ConversationStatusClosed
+	m.operations = append(m.operations, op)
+	return nil
+}
+
+func (m *mockConversationOperationRepo) Split(ctx context.Context, op *entity.ConversationOperation, conversation *entity.Conversation) error {
+	m.conversations.Conversations[conversation.ID] = conversation
+	for _, id := range op.MessageIDs {
+		m.messages.Messages[id].ConversationID = op.TargetConversationID
+	}
+	m.operations = append(m.operations, op)
+	return nil
+}
+
+func (m *mockConversationOperationRepo) ListByConversation(ctx context.Context, conversationID string) ([]*entity.ConversationOperation, error) {
+	var result []*entity.ConversationOperation
+	for _, op := range m.operations {
+		if op.SourceConversationID == conversationID || op.TargetConversationID == conversationID {
+			result = append(result, op)
+		}
+	}
+	return result, nil
+}
+
+func newConversationOperationTestEnv() (*ConversationOperationService, *mockConversationOperationRepo) {
+	conversations := testutil.NewMockConversationRepository()
+	messages := testutil.NewMockMessageRepository()
+	repo := &mockConversationOperationRepo{conversations: conversations, messages: messages}
+
+	conversations.Conversations["conv-1"] = &entity.Conversation{ID: "conv-1", TenantID: "tenant-1", ContactID: "contact-1", ChannelID: "whatsapp", Status: entity.ConversationStatusOpen, Priority: entity.ConversationPriorityHigh}
+	conversations.Conversations["conv-2"] = &entity.Conversation{ID: "conv-2", TenantID: "tenant-1", ContactID: "contact-1", ChannelID: "email", Status: entity.ConversationStatusOpen}
+	conversations.Conversations["conv-3"] = &entity.Conversation{ID: "conv-3", TenantID: "tenant-1", ContactID: "contact-2", ChannelID: "whatsapp", Status: entity.ConversationStatusOpen}
+
+	now := time.Now()
+	for i, id := range []string{"m1", "m2", "m3"} {
+		messages.Messages[id] = &entity.Message{ID: id, ConversationID: "conv-1", CreatedAt: now.Add(time.Duration(i) * time.Second)}
+	}
+	messages.Messages["m4"] = &entity.Message{ID: "m4", ConversationID: "conv-2", CreatedAt: now.Add(-time.Hour)}
+
+	return NewConversationOperationService(repo, conversations, messages), repo
+}
+
+func TestConversationOperationService_Merge(t *testing.T) {
+	svc, repo := newConversationOperationTestEnv()
+	ctx := context.Background()
+
+	op, err := svc.Merge(ctx, "tenant-1", "conv-1", "user-1", &MergeConversationsInput{SourceConversationID: "conv-2", Reason: "duplicate"})
+	require.NoError(t, err)
+	assert.Equal(t, entity.ConversationOperationMerge, op.Type)
+	assert.Equal(t, []string{"m4"}, op.MessageIDs)
+	assert.Equal(t, "user-1", op.PerformedBy)
+	assert.Equal(t, "conv-1", repo.messages.Messages["m4"].ConversationID)
+	assert.Equal(t, entity.ConversationStatusClosed, repo.conversations.Conversations["conv-2"].Status)
+
+	_, err = svc.Merge(ctx, "tenant-1", "conv-1", "user-1", &MergeConversationsInput{SourceConversationID: "conv-2"})
+	assert.True(t, errors.IsValidation(err), "closed source")
+
+	operations, err := svc.ListOperations(ctx, "tenant-1", "conv-2")
+	require.NoError(t, err)
+	assert.Len(t, operations, 1)
+}
+
+func TestConversationOperationService_MergeValidation(t *testing.T) {
+	svc, _ := newConversationOperationTestEnv()
+	ctx := context.Background()
+
+	_, err := svc.Merge(ctx, "tenant-1", "conv-1", "user-1", &MergeConversationsInput{SourceConversationID: "conv-1"})
+	assert.True(t, errors.IsValidation(err))
+
+	_, err = svc.Merge(ctx, "tenant-1", "conv-1", "user-1", &MergeConversationsInput{SourceConversationID: "conv-3"})
+	assert.True(t, errors.IsValidation(err), "different contacts")
+
+	_, err = svc.Merge(ctx, "tenant-2", "conv-1", "user-1", &MergeConversationsInput{SourceConversationID: "conv-2"})
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestConversationOperationService_Split(t *testing.T) {
+	svc, repo := newConversationOperationTestEnv()
+	ctx := context.Background()
+
+	result, err := svc.Split(ctx, "tenant-1", "conv-1", "user-1", &SplitConversationInput{MessageIDs: []string{"m2", "m3", "m3"}, Subject: "Refund"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"m2", "m3"}, result.Operation.MessageIDs)
+	assert.Equal(t, "contact-1", result.Conversation.ContactID)
+	assert.Equal(t, "whatsapp", result.Conversation.ChannelID)
+	assert.Equal(t, entity.ConversationPriorityHigh, result.Conversation.Priority)
+	assert.Equal(t, "Refund", result.Conversation.Subject)
+	assert.Equal(t, result.Conversation.ID, repo.messages.Messages["m2"].ConversationID)
+	assert.Equal(t, "conv-1", repo.messages.Messages["m1"].ConversationID)
+}
+
+func TestConversationOperationService_SplitValidation(t *testing.T) {
+	svc, _ := newConversationOperationTestEnv()
+	ctx := context.Background()
+
+	_, err := svc.Split(ctx, "tenant-1", "conv-1", "user-1", &SplitConversationInput{})
+	assert.True(t, errors.IsValidation(err))
+
+	_, err = svc.Split(ctx, "tenant-1", "conv-1", "user-1", &SplitConversationInput{MessageIDs: []string{"m4"}})
+	assert.True(t, errors.IsValidation(err), "message of another conversation")
+
+	_, err = svc.Split(ctx, "tenant-1", "conv-1", "user-1", &SplitConversationInput{MessageIDs: []string{"m1", "m2", "m3"}})
+	assert.True(t, errors.IsValidation(err), "nothing would remain")
+}
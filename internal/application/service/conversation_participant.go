@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// ConversationParticipantService manages the external participants of group
+// conversations: WhatsApp groups and email threads with several recipients. Inbound
+// messages of a group go to one conversation whose participants are the contacts who
+// wrote in it or were copied, and group messages keep a delivery status per participant.
+type ConversationParticipantService struct {
+	participantRepo  repository.ConversationParticipantRepository
+	conversationRepo repository.ConversationRepository
+	contactRepo      repository.ContactRepository
+	channelRepo      repository.ChannelRepository
+	messageRepo      repository.MessageRepository
+}
+
+// NewConversationParticipantService creates a new conversation participant service
+func NewConversationParticipantService(
+	participantRepo repository.ConversationParticipantRepository,
+	conversationRepo repository.ConversationRepository,
+	contactRepo repository.ContactRepository,
+	channelRepo repository.ChannelRepository,
+	messageRepo repository.MessageRepository,
+) *ConversationParticipantService {
+	return &ConversationParticipantService{
+		participantRepo:  participantRepo,
+		conversationRepo: conversationRepo,
+		contactRepo:      contactRepo,
+		channelRepo:      channelRepo,
+		messageRepo:      messageRepo,
+	}
+}
+
+// InboundGroup returns the group an inbound message was sent in and the addresses of
+// the other participants the channel reported, or an empty group ID for one-to-one
+// messages. Adapters of group chats set the group ID; an email is a group message
+// when it is addressed or copied to someone outside the channel's domain besides the
+// sender, and its group is the root message of its thread.
+func (s *ConversationParticipantService) InboundGroup(channel *entity.Channel, metadata map[string]string) (string, []string) {
+	if groupID := metadata[entity.MetadataGroupID]; groupID != "" {
+		return groupID, nil
+	}
+	if channel.Type != entity.ChannelTypeEmail {
+		return "", nil
+	}
+
+	// Without a sender address, the To list is taken to be the channel's own addresses
+	ownDomain := ""
+	if from := emailAddresses(channel.Config["from_email"]); len(from) > 0 {
+		ownDomain = emailDomain(from[0])
+	}
+	addresses := emailAddresses(metadata["cc"])
+	if ownDomain != "" {
+		addresses = append(emailAddresses(metadata["to"]), addresses...)
+	}
+
+	sender := emailAddresses(metadata["sender_id"])
+	seen := make(map[string]bool)
+	var others []string
+	for _, address := range addresses {
+		if seen[address] || (len(sender) > 0 && address == sender[0]) || (ownDomain != "" && emailDomain(address) == ownDomain) {
+			continue
+		}
+		seen[address] = true
+		others = append(others, address)
+	}
+	if len(others) == 0 {
+		return "", nil
+	}
+
+	groupID := strings.TrimSpace(metadata["in_reply_to"])
+	if references := strings.Fields(metadata["references"]); len(references) > 0 {
+		groupID = references[0]
+	}
+	if groupID == "" {
+		groupID = strings.TrimSpace(metadata["message_id"])
+	}
+	if groupID == "" {
+		return "", nil
+	}
+	return groupID, others
+}
+
+// FindGroupConversation finds the latest conversation of a group on a channel, nil when none
+func (s *ConversationParticipantService) FindGroupConversation(ctx context.Context, channelID, groupID string) (*entity.Conversation, error) {
+	return s.conversationRepo.FindByGroup(ctx, channelID, groupID)
+}
+
+// Join adds a contact who wrote in or was copied on a group conversation
+func (s *ConversationParticipantService) Join(ctx context.Context, conversation *entity.Conversation, contactID, identifier string) error {
+	return s.participantRepo.Upsert(ctx, &entity.ConversationParticipant{
+		ID:             uuid.New().String(),
+		TenantID:       conversation.TenantID,
+		ConversationID: conversation.ID,
+		ContactID:      contactID,
+		Identifier:     identifier,
+		Status:         entity.ParticipantStatusActive,
+		JoinedAt:       time.Now(),
+	})
+}
+
+// List returns the participants of a group conversation, removed ones included
+func (s *ConversationParticipantService) List(ctx context.Context, tenantID, conversationID string) ([]*entity.ConversationParticipant, error) {
+	if _, err := s.groupConversation(ctx, tenantID, conversationID); err != nil {
+		return nil, err
+	}
+	participants, err := s.participantRepo.FindByConversation(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if participants == nil {
+		participants = []*entity.ConversationParticipant{}
+	}
+	return participants, nil
+}
+
+// Add adds a contact to a group conversation, addressed by their identity on the
+// conversation's channel
+func (s *ConversationParticipantService) Add(ctx context.Context, tenantID, conversationID, contactID, addedBy string) (*entity.ConversationParticipant, error) {
+	conversation, err := s.groupConversation(ctx, tenantID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	contact, err := s.contactRepo.FindByID(ctx, contactID)
+	if err != nil || contact.TenantID != tenantID {
+		return nil, errors.NotFound("contact")
+	}
+
+	channel, err := s.channelRepo.FindByID(ctx, conversation.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	identifier := s.channelIdentifier(ctx, contact, channel.Type)
+	if identifier == "" {
+		return nil, errors.Validation("the contact has no address on the conversation's channel")
+	}
+
+	participant := &entity.ConversationParticipant{
+		ID:             uuid.New().String(),
+		TenantID:       tenantID,
+		ConversationID: conversation.ID,
+		ContactID:      contact.ID,
+		Identifier:     identifier,
+		Status:         entity.ParticipantStatusActive,
+		AddedBy:        addedBy,
+		JoinedAt:       time.Now(),
+	}
+	if err := s.participantRepo.Upsert(ctx, participant); err != nil {
+		return nil, err
+	}
+	return participant, nil
+}
+
+// Remove removes a contact from a group conversation, so agent replies no longer
+// address them. The contact of the conversation can't be removed.
+func (s *ConversationParticipantService) Remove(ctx context.Context, tenantID, conversationID, contactID string) error {
+	conversation, err := s.groupConversation(ctx, tenantID, conversationID)
+	if err != nil {
+		return err
+	}
+	if contactID == conversation.ContactID {
+		return errors.Validation("the contact of the conversation can't be removed")
+	}
+	return s.participantRepo.Remove(ctx, conversationID, contactID, time.Now())
+}
+
+// GroupRecipients returns the addresses of the active participants of a group
+// conversation other than its contact, who outbound messages are also sent to
+func (s *ConversationParticipantService) GroupRecipients(ctx context.Context, conversation *entity.Conversation) ([]string, error) {
+	participants, err := s.participantRepo.FindByConversation(ctx, conversation.ID)
+	if err != nil {
+		return nil, err
+	}
+	var recipients []string
+	for _, participant := range participants {
+		if participant.IsActive() && participant.ContactID != conversation.ContactID {
+			recipients = append(recipients, participant.Identifier)
+		}
+	}
+	return recipients, nil
+}
+
+// RecordDelivery records the delivery status of a group message for the participant
+// with an address, ignoring statuses older than the one recorded
+func (s *ConversationParticipantService) RecordDelivery(ctx context.Context, messageID, identifier string, status entity.MessageStatus, errorMessage string, at time.Time) error {
+	recipient, err := s.participantRepo.FindRecipient(ctx, messageID, identifier)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		recipient = &entity.MessageRecipient{MessageID: messageID, Identifier: identifier}
+		if message, err := s.messageRepo.FindByID(ctx, messageID); err == nil {
+			if participant, err := s.participantRepo.FindByIdentifier(ctx, message.ConversationID, identifier); err == nil {
+				recipient.ContactID = participant.ContactID
+			}
+		}
+	}
+
+	if at.IsZero() {
+		at = time.Now()
+	}
+	if !recipient.Advance(status, errorMessage, at) {
+		return nil
+	}
+	return s.participantRepo.SaveRecipient(ctx, recipient)
+}
+
+// MessageRecipients returns the delivery status of a group message per participant
+func (s *ConversationParticipantService) MessageRecipients(ctx context.Context, tenantID, messageID string) ([]*entity.MessageRecipient, error) {
+	message, err := s.messageRepo.FindByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	conversation, err := s.conversationRepo.FindByID(ctx, message.ConversationID)
+	if err != nil || conversation.TenantID != tenantID {
+		return nil, errors.NotFound("message")
+	}
+	recipients, err := s.participantRepo.FindRecipients(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if recipients == nil {
+		recipients = []*entity.MessageRecipient{}
+	}
+	return recipients, nil
+}
+
+// groupConversation returns a group conversation of a tenant
+func (s *ConversationParticipantService) groupConversation(ctx context.Context, tenantID, conversationID string) (*entity.Conversation, error) {
+	conversation, err := s.conversationRepo.FindByID(ctx, conversationID)
+	if err != nil || conversation.TenantID != tenantID {
+		return nil, errors.NotFound("conversation")
+	}
+	if !conversation.IsGroup() {
+		return nil, errors.Validation("the conversation is not a group conversation")
+	}
+	return conversation, nil
+}
+
+// channelIdentifier returns the address of a contact on a type of channel
+func (s *ConversationParticipantService) channelIdentifier(ctx context.Context, contact *entity.Contact, channelType entity.ChannelType) string {
+	if identities, err := s.contactRepo.FindIdentitiesByContact(ctx, contact.ID); err == nil {
+		for _, identity := range identities {
+			if identity.ChannelType == string(channelType) {
+				return identity.Identifier
+			}
+		}
+	}
+	if channelType == entity.ChannelTypeEmail {
+		return entity.NormalizeEmail(contact.Email)
+	}
+	return contact.Phone
+}
+
+// emailDomain returns the domain of an email address
+func emailDomain(address string) string {
+	if at := strings.LastIndex(address, "@"); at >= 0 {
+		return address[at+1:]
+	}
+	return ""
+}
@@ -0,0 +1,254 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockConversationParticipantRepository is an inline mock for ConversationParticipantRepository
+type mockConversationParticipantRepository struct {
+	participants []*entity.ConversationParticipant
+	recipients   map[string]*entity.MessageRecipient
+}
+
+func newMockConversationParticipantRepository() *mockConversationParticipantRepository {
+	return &mockConversationParticipantRepository{recipients: make(map[string]*entity.MessageRecipient)}
+}
+
+func (m *mockConversationParticipantRepository) Upsert(ctx context.Context, participant *entity.ConversationParticipant) error {
+	for _, existing := range m.participants {
+		if existing.ConversationID == participant.ConversationID && existing.ContactID == participant.ContactID {
+			existing.Identifier = participant.Identifier
+			existing.Status = entity.ParticipantStatusActive
+			existing.LeftAt = nil
+			return nil
+		}
+	}
+	m.participants = append(m.participants, participant)
+	return nil
+}
+
+func (m *mockConversationParticipantRepository) FindByConversation(ctx context.Context, conversationID string) ([]*entity.ConversationParticipant, error) {
+	var result []*entity.ConversationParticipant
+	for _, participant := range m.participants {
+		if participant.ConversationID == conversationID {
+			result = append(result, participant)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockConversationParticipantRepository) FindByIdentifier(ctx context.Context, conversationID, identifier string) (*entity.ConversationParticipant, error) {
+	for _, participant := range m.participants {
+		if participant.ConversationID == conversationID && participant.Identifier == identifier {
+			return participant, nil
+		}
+	}
+	return nil, errors.NotFound("participant")
+}
+
+func (m *mockConversationParticipantRepository) Remove(ctx context.Context, conversationID, contactID string, at time.Time) error {
+	for _, participant := range m.participants {
+		if participant.ConversationID == conversationID && participant.ContactID == contactID {
+			participant.Status = entity.ParticipantStatusRemoved
+			participant.LeftAt = &at
+			return nil
+		}
+	}
+	return errors.NotFound("participant")
+}
+
+func (m *mockConversationParticipantRepository) FindRecipient(ctx context.Context, messageID, identifier string) (*entity.MessageRecipient, error) {
+	recipient, ok := m.recipients[messageID+"/"+identifier]
+	if !ok {
+		return nil, errors.NotFound("message recipient")
+	}
+	copied := *recipient
+	return &copied, nil
+}
+
+func (m *mockConversationParticipantRepository) SaveRecipient(ctx context.Context, recipient *entity.MessageRecipient) error {
+	m.recipients[recipient.MessageID+"/"+recipient.Identifier] = recipient
+	return nil
+}
+
+func (m *mockConversationParticipantRepository) FindRecipients(ctx context.Context, messageID string) ([]*entity.MessageRecipient, error) {
+	var result []*entity.MessageRecipient
+	for _, recipient := range m.recipients {
+		if recipient.MessageID == messageID {
+			result = append(result, recipient)
+		}
+	}
+	return result, nil
+}
+
+type participantTestFixture struct {
+	svc           *ConversationParticipantService
+	participants  *mockConversationParticipantRepository
+	conversations *testutil.MockConversationRepository
+	contacts      *testutil.MockContactRepository
+	messages      *testutil.MockMessageRepository
+}
+
+func newParticipantTestFixture() *participantTestFixture {
+	f := &participantTestFixture{
+		participants:  newMockConversationParticipantRepository(),
+		conversations: testutil.NewMockConversationRepository(),
+		contacts:      testutil.NewMockContactRepository(),
+		messages:      testutil.NewMockMessageRepository(),
+	}
+	channels := testutil.NewMockChannelRepository()
+	channels.Channels["channel-1"] = &entity.Channel{ID: "channel-1", TenantID: "tenant-1", Type: entity.ChannelTypeEmail}
+	f.conversations.Conversations["conv-1"] = &entity.Conversation{
+		ID:        "conv-1",
+		TenantID:  "tenant-1",
+		ChannelID: "channel-1",
+		ContactID: "contact-1",
+		Metadata:  map[string]string{entity.MetadataGroupID: "<root@example.com>"},
+	}
+	f.svc = NewConversationParticipantService(f.participants, f.conversations, f.contacts, channels, f.messages)
+	return f
+}
+
+func TestConversationParticipantService_InboundGroup(t *testing.T) {
+	svc := newParticipantTestFixture().svc
+	emailChannel := &entity.Channel{Type: entity.ChannelTypeEmail, Config: map[string]string{"from_email": "Support <support@acme.com>"}}
+
+	t.Run("group chats use the group ID set by the adapter", func(t *testing.T) {
+		channel := &entity.Channel{Type: entity.ChannelTypeWhatsApp}
+		groupID, others := svc.InboundGroup(channel, map[string]string{entity.MetadataGroupID: "123@g.us"})
+		assert.Equal(t, "123@g.us", groupID)
+		assert.Empty(t, others)
+
+		groupID, _ = svc.InboundGroup(channel, map[string]string{"sender_id": "5511999999999"})
+		assert.Empty(t, groupID)
+	})
+
+	t.Run("emails copied to others are grouped by thread root", func(t *testing.T) {
+		groupID, others := svc.InboundGroup(emailChannel, map[string]string{
+			"sender_id":   "ana@customer.com",
+			"to":          "support@acme.com, Bob <bob@customer.com>",
+			"cc":          "ana@customer.com, carol@partner.com, sales@acme.com",
+			"message_id":  "<m3@customer.com>",
+			"in_reply_to": "<m2@acme.com>",
+			"references":  "<m1@customer.com> <m2@acme.com>",
+		})
+		assert.Equal(t, "<m1@customer.com>", groupID)
+		assert.Equal(t, []string{"bob@customer.com", "carol@partner.com"}, others)
+	})
+
+	t.Run("a new thread is grouped by its own message ID", func(t *testing.T) {
+		groupID, _ := svc.InboundGroup(emailChannel, map[string]string{
+			"sender_id":  "ana@customer.com",
+			"to":         "support@acme.com",
+			"cc":         "bob@customer.com",
+			"message_id": "<m1@customer.com>",
+		})
+		assert.Equal(t, "<m1@customer.com>", groupID)
+	})
+
+	t.Run("emails only to the channel are one-to-one", func(t *testing.T) {
+		groupID, others := svc.InboundGroup(emailChannel, map[string]string{
+			"sender_id":  "ana@customer.com",
+			"to":         "support@acme.com",
+			"message_id": "<m1@customer.com>",
+		})
+		assert.Empty(t, groupID)
+		assert.Empty(t, others)
+	})
+}
+
+func TestConversationParticipantService_Participants(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("adds contacts by their address on the channel", func(t *testing.T) {
+		f := newParticipantTestFixture()
+		f.contacts.Contacts["contact-2"] = &entity.Contact{ID: "contact-2", TenantID: "tenant-1", Email: "Bob@Customer.com"}
+		require.NoError(t, f.svc.Join(ctx, f.conversations.Conversations["conv-1"], "contact-1", "ana@customer.com"))
+
+		participant, err := f.svc.Add(ctx, "tenant-1", "conv-1", "contact-2", "user-1")
+		require.NoError(t, err)
+		assert.Equal(t, "bob@customer.com", participant.Identifier)
+		assert.Equal(t, "user-1", participant.AddedBy)
+
+		participants, err := f.svc.List(ctx, "tenant-1", "conv-1")
+		require.NoError(t, err)
+		assert.Len(t, participants, 2)
+
+		recipients, err := f.svc.GroupRecipients(ctx, f.conversations.Conversations["conv-1"])
+		require.NoError(t, err)
+		assert.Equal(t, []string{"bob@customer.com"}, recipients, "the contact of the conversation is the main recipient")
+	})
+
+	t.Run("removed participants are no longer addressed", func(t *testing.T) {
+		f := newParticipantTestFixture()
+		conversation := f.conversations.Conversations["conv-1"]
+		require.NoError(t, f.svc.Join(ctx, conversation, "contact-2", "bob@customer.com"))
+
+		require.NoError(t, f.svc.Remove(ctx, "tenant-1", "conv-1", "contact-2"))
+		recipients, err := f.svc.GroupRecipients(ctx, conversation)
+		require.NoError(t, err)
+		assert.Empty(t, recipients)
+
+		err = f.svc.Remove(ctx, "tenant-1", "conv-1", "contact-1")
+		assert.Equal(t, errors.ErrCodeValidation, errors.GetAppError(err).Code)
+	})
+
+	t.Run("rejects one-to-one and other tenants' conversations", func(t *testing.T) {
+		f := newParticipantTestFixture()
+		f.conversations.Conversations["conv-2"] = &entity.Conversation{ID: "conv-2", TenantID: "tenant-1", ChannelID: "channel-1"}
+
+		_, err := f.svc.List(ctx, "tenant-1", "conv-2")
+		assert.Equal(t, errors.ErrCodeValidation, errors.GetAppError(err).Code)
+
+		_, err = f.svc.List(ctx, "tenant-2", "conv-1")
+		assert.True(t, errors.IsNotFound(err))
+	})
+}
+
+func TestMessageStatusService_GroupRecipients(t *testing.T) {
+	ctx := context.Background()
+	f := newParticipantTestFixture()
+	conversation := f.conversations.Conversations["conv-1"]
+	require.NoError(t, f.svc.Join(ctx, conversation, "contact-1", "5511911111111"))
+	require.NoError(t, f.svc.Join(ctx, conversation, "contact-2", "5511922222222"))
+	f.messages.Messages["msg-1"] = &entity.Message{ID: "msg-1", ConversationID: "conv-1", Status: entity.MessageStatusSent}
+
+	statuses := NewMessageStatusService(f.messages, f.conversations, f.contacts)
+	statuses.SetParticipantService(f.svc)
+	update := func(recipient, status string) {
+		require.NoError(t, statuses.HandleStatusUpdate(ctx, &nats.StatusUpdate{
+			MessageID: "msg-1",
+			Recipient: recipient,
+			Status:    status,
+			Timestamp: time.Now(),
+		}))
+	}
+
+	update("5511911111111", "read")
+	update("5511922222222", "delivered")
+	update("5511911111111", "delivered")
+
+	recipients, err := f.svc.MessageRecipients(ctx, "tenant-1", "msg-1")
+	require.NoError(t, err)
+	require.Len(t, recipients, 2)
+	byIdentifier := make(map[string]*entity.MessageRecipient)
+	for _, recipient := range recipients {
+		byIdentifier[recipient.Identifier] = recipient
+	}
+	assert.Equal(t, entity.MessageStatusRead, byIdentifier["5511911111111"].Status, "late receipts don't move a recipient back")
+	assert.Equal(t, "contact-1", byIdentifier["5511911111111"].ContactID)
+	assert.Equal(t, entity.MessageStatusDelivered, byIdentifier["5511922222222"].Status)
+	assert.Equal(t, entity.MessageStatusRead, f.messages.Messages["msg-1"].Status, "the message follows the furthest participant")
+
+	update("5511933333333", "failed")
+	assert.Equal(t, entity.MessageStatusRead, f.messages.Messages["msg-1"].Status, "one participant failing doesn't fail the message")
+}
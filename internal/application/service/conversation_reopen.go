@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// ConversationReopenService decides whether a customer writing after a resolution
+// reopens the resolved conversation or starts a follow-up, and records reopens for
+// the reopen rate
+type ConversationReopenService struct {
+	conversationRepo repository.ConversationRepository
+	tenantRepo       repository.TenantRepository
+	reopenRepo       repository.ConversationReopenRepository
+	now              func() time.Time
+}
+
+// NewConversationReopenService creates a new conversation reopen service
+func NewConversationReopenService(
+	conversationRepo repository.ConversationRepository,
+	tenantRepo repository.TenantRepository,
+	reopenRepo repository.ConversationReopenRepository,
+) *ConversationReopenService {
+	return &ConversationReopenService{
+		conversationRepo: conversationRepo,
+		tenantRepo:       tenantRepo,
+		reopenRepo:       reopenRepo,
+		now:              time.Now,
+	}
+}
+
+// MatchInbound is called when a contact with no open conversation on a channel writes.
+// Within the tenant's reopen window the last resolved conversation is reopened and
+// returned. Past it but within the follow-up window, the ID of that conversation is
+// returned so the new conversation can be linked to it.
+func (s *ConversationReopenService) MatchInbound(ctx context.Context, tenantID, contactID, channelID string) (*entity.Conversation, string, error) {
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	reopenWindow, followUpWindow := tenant.ReopenWindow(), tenant.FollowUpWindow()
+	window := reopenWindow
+	if followUpWindow > window {
+		window = followUpWindow
+	}
+	if window == 0 {
+		return nil, "", nil
+	}
+
+	now := s.now()
+	resolved, err := s.conversationRepo.FindLastResolvedByContactAndChannel(ctx, contactID, channelID, now.Add(-window))
+	if err != nil || resolved == nil || resolved.ResolvedAt == nil {
+		return nil, "", err
+	}
+
+	resolvedAt := *resolved.ResolvedAt
+	if reopenWindow > 0 && now.Sub(resolvedAt) <= reopenWindow {
+		resolved.Reopen()
+		resolved.UpdatedAt = now
+		if err := s.conversationRepo.Update(ctx, resolved); err != nil {
+			return nil, "", err
+		}
+		if err := s.Record(ctx, resolved, resolvedAt, entity.ConversationReopenCustomer, ""); err != nil {
+			return nil, "", err
+		}
+		return resolved, "", nil
+	}
+
+	if followUpWindow > 0 && now.Sub(resolvedAt) <= followUpWindow {
+		return nil, resolved.ID, nil
+	}
+	return nil, "", nil
+}
+
+// Record records that a conversation resolved at the given time was reopened
+func (s *ConversationReopenService) Record(ctx context.Context, conversation *entity.Conversation, resolvedAt time.Time, source entity.ConversationReopenSource, userID string) error {
+	return s.reopenRepo.Create(ctx, &entity.ConversationReopen{
+		ID:             uuid.New().String(),
+		TenantID:       conversation.TenantID,
+		ConversationID: conversation.ID,
+		Source:         source,
+		ReopenedBy:     userID,
+		ResolvedAt:     resolvedAt,
+		ReopenedAt:     s.now(),
+	})
+}
+
+// History returns the reopens of a conversation, the conversation it follows up on
+// and the conversations following up on it
+func (s *ConversationReopenService) History(ctx context.Context, tenantID, conversationID string) (*entity.ConversationHistory, error) {
+	conversation, err := s.conversationRepo.FindByID(ctx, conversationID)
+	if err != nil || conversation.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+	}
+
+	reopens, err := s.reopenRepo.FindByConversation(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	followUps, err := s.conversationRepo.FindFollowUps(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if reopens == nil {
+		reopens = []*entity.ConversationReopen{}
+	}
+	if followUps == nil {
+		followUps = []*entity.Conversation{}
+	}
+	return &entity.ConversationHistory{
+		ConversationID: conversation.ID,
+		FollowUpOf:     conversation.Metadata[entity.MetadataFollowUpOf],
+		Reopens:        reopens,
+		FollowUps:      followUps,
+	}, nil
+}
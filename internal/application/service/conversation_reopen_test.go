@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockConversationReopenRepository is an inline mock for ConversationReopenRepository
+type mockConversationReopenRepository struct {
+	reopens []*entity.ConversationReopen
+}
+
+func (m *mockConversationReopenRepository) Create(ctx context.Context, reopen *entity.ConversationReopen) error {
+	m.reopens = append(m.reopens, reopen)
+	return nil
+}
+
+func (m *mockConversationReopenRepository) FindByConversation(ctx context.Context, conversationID string) ([]*entity.ConversationReopen, error) {
+	var result []*entity.ConversationReopen
+	for _, reopen := range m.reopens {
+		if reopen.ConversationID == conversationID {
+			result = append(result, reopen)
+		}
+	}
+	return result, nil
+}
+
+func newConversationReopenTestService(now time.Time, settings map[string]string) (*ConversationReopenService, *testutil.MockConversationRepository, *mockConversationReopenRepository) {
+	tenants := testutil.NewMockTenantRepository()
+	tenants.Tenants["tenant-1"] = &entity.Tenant{ID: "tenant-1", Settings: settings}
+	conversations := testutil.NewMockConversationRepository()
+	reopens := &mockConversationReopenRepository{}
+
+	svc := NewConversationReopenService(conversations, tenants, reopens)
+	svc.now = func() time.Time { return now }
+	return svc, conversations, reopens
+}
+
+func resolvedConversation(id string, resolvedAt time.Time) *entity.Conversation {
+	return &entity.Conversation{
+		ID:         id,
+		TenantID:   "tenant-1",
+		ContactID:  "contact-1",
+		ChannelID:  "channel-1",
+		Status:     entity.ConversationStatusResolved,
+		ResolvedAt: &resolvedAt,
+	}
+}
+
+func TestConversationReopenService_MatchInbound(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	settings := map[string]string{
+		entity.TenantSettingReopenWindow:   "24",
+		entity.TenantSettingFollowUpWindow: "168",
+	}
+
+	t.Run("reopens within the reopen window", func(t *testing.T) {
+		svc, conversations, reopens := newConversationReopenTestService(now, settings)
+		resolvedAt := now.Add(-3 * time.Hour)
+		conversations.Conversations["conv-old"] = resolvedConversation("conv-old", now.Add(-20*time.Hour))
+		conversations.Conversations["conv-1"] = resolvedConversation("conv-1", resolvedAt)
+
+		reopened, followUpOf, err := svc.MatchInbound(context.Background(), "tenant-1", "contact-1", "channel-1")
+		require.NoError(t, err)
+		require.NotNil(t, reopened)
+		assert.Equal(t, "conv-1", reopened.ID, "the most recently resolved conversation is reopened")
+		assert.Empty(t, followUpOf)
+		assert.Equal(t, entity.ConversationStatusOpen, conversations.Conversations["conv-1"].Status)
+		assert.Nil(t, conversations.Conversations["conv-1"].ResolvedAt)
+
+		require.Len(t, reopens.reopens, 1)
+		assert.Equal(t, entity.ConversationReopenCustomer, reopens.reopens[0].Source)
+		assert.Equal(t, resolvedAt, reopens.reopens[0].ResolvedAt)
+		assert.Equal(t, now, reopens.reopens[0].ReopenedAt)
+	})
+
+	t.Run("links follow-ups after the reopen window", func(t *testing.T) {
+		svc, conversations, reopens := newConversationReopenTestService(now, settings)
+		conversations.Conversations["conv-1"] = resolvedConversation("conv-1", now.Add(-48*time.Hour))
+
+		reopened, followUpOf, err := svc.MatchInbound(context.Background(), "tenant-1", "contact-1", "channel-1")
+		require.NoError(t, err)
+		assert.Nil(t, reopened)
+		assert.Equal(t, "conv-1", followUpOf)
+		assert.Equal(t, entity.ConversationStatusResolved, conversations.Conversations["conv-1"].Status)
+		assert.Empty(t, reopens.reopens)
+	})
+
+	t.Run("ignores conversations resolved before both windows", func(t *testing.T) {
+		svc, conversations, _ := newConversationReopenTestService(now, settings)
+		conversations.Conversations["conv-1"] = resolvedConversation("conv-1", now.Add(-200*time.Hour))
+
+		reopened, followUpOf, err := svc.MatchInbound(context.Background(), "tenant-1", "contact-1", "channel-1")
+		require.NoError(t, err)
+		assert.Nil(t, reopened)
+		assert.Empty(t, followUpOf)
+	})
+
+	t.Run("does nothing without windows", func(t *testing.T) {
+		svc, conversations, _ := newConversationReopenTestService(now, map[string]string{})
+		conversations.Conversations["conv-1"] = resolvedConversation("conv-1", now.Add(-time.Minute))
+
+		reopened, followUpOf, err := svc.MatchInbound(context.Background(), "tenant-1", "contact-1", "channel-1")
+		require.NoError(t, err)
+		assert.Nil(t, reopened)
+		assert.Empty(t, followUpOf)
+	})
+}
+
+func TestConversationReopenService_AgentReopenAndHistory(t *testing.T) {
+	now := time.Now()
+	svc, conversations, reopens := newConversationReopenTestService(now, map[string]string{})
+	conversations.Conversations["conv-1"] = resolvedConversation("conv-1", now.Add(-time.Hour))
+	conversations.Conversations["conv-2"] = &entity.Conversation{
+		ID:       "conv-2",
+		TenantID: "tenant-1",
+		Status:   entity.ConversationStatusOpen,
+		Metadata: map[string]string{entity.MetadataFollowUpOf: "conv-1"},
+	}
+
+	conversationService := NewConversationService(conversations, testutil.NewMockContactRepository(), testutil.NewMockChannelRepository())
+	conversationService.SetReopenService(svc)
+
+	_, err := conversationService.Reopen(context.Background(), "tenant-1", "conv-1", "user-1")
+	require.NoError(t, err)
+	require.Len(t, reopens.reopens, 1)
+	assert.Equal(t, entity.ConversationReopenAgent, reopens.reopens[0].Source)
+	assert.Equal(t, "user-1", reopens.reopens[0].ReopenedBy)
+
+	history, err := svc.History(context.Background(), "tenant-1", "conv-1")
+	require.NoError(t, err)
+	assert.Len(t, history.Reopens, 1)
+	require.Len(t, history.FollowUps, 1)
+	assert.Equal(t, "conv-2", history.FollowUps[0].ID)
+
+	history, err = svc.History(context.Background(), "tenant-1", "conv-2")
+	require.NoError(t, err)
+	assert.Equal(t, "conv-1", history.FollowUpOf)
+	assert.Empty(t, history.Reopens)
+
+	_, err = svc.History(context.Background(), "tenant-2", "conv-1")
+	assert.Error(t, err)
+}
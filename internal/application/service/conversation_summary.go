@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// EventConversationSummarized is published when a conversation summary was generated
+const EventConversationSummarized = "conversation.summarized"
+
+// Conversation summary limits
+const (
+	conversationSummaryTimeout         = 60 * time.Second
+	conversationSummaryContextMessages = 100
+	conversationSummaryMessageMaxSize  = 1000
+	conversationSummaryMaxFollowUps    = 5
+)
+
+const conversationSummaryPrompt = `Summarize the customer service conversation below for the CRM and for
+agents taking it over. Reply with a single JSON object with these keys:
+- "issue": one or two sentences on what the customer needed
+- "resolution": one or two sentences on how it was resolved, or "" when it was not
+- "sentiment": the customer's sentiment by the end, one of "positive", "neutral" or "negative"
+- "follow_ups": a list of short actions still owed to the customer, empty when there are none
+
+Write in the language of the conversation.
+
+Conversation:
+%s`
+
+// ConversationSummaryService generates structured AI summaries of conversations on
+// request and when they are resolved, so CRM syncs and handovers have context
+type ConversationSummaryService struct {
+	repo             repository.ConversationSummaryRepository
+	conversationRepo repository.ConversationRepository
+	messageRepo      repository.MessageRepository
+	tenantRepo       repository.TenantRepository
+	aiFactory        *AIProviderFactory
+	embeddings       *EmbeddingService
+	producer         nats.Publisher
+	now              func() time.Time
+}
+
+// NewConversationSummaryService creates a new conversation summary service
+func NewConversationSummaryService(
+	repo repository.ConversationSummaryRepository,
+	conversationRepo repository.ConversationRepository,
+	messageRepo repository.MessageRepository,
+	tenantRepo repository.TenantRepository,
+	aiFactory *AIProviderFactory,
+) *ConversationSummaryService {
+	return &ConversationSummaryService{
+		repo:             repo,
+		conversationRepo: conversationRepo,
+		messageRepo:      messageRepo,
+		tenantRepo:       tenantRepo,
+		aiFactory:        aiFactory,
+		now:              time.Now,
+	}
+}
+
+// SetProducer sets the publisher of conversation.summarized events
+func (s *ConversationSummaryService) SetProducer(producer nats.Publisher) {
+	s.producer = producer
+}
+
+// SetEmbeddingService embeds the issue of each summary, so the copilot can find
+// resolved conversations similar to new ones
+func (s *ConversationSummaryService) SetEmbeddingService(embeddings *EmbeddingService) {
+	s.embeddings = embeddings
+}
+
+// HandleResolved summarizes a conversation in the background after it was resolved,
+// unless the tenant turned summaries on resolve off
+func (s *ConversationSummaryService) HandleResolved(ctx context.Context, conversation *entity.Conversation) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), conversationSummaryTimeout)
+		defer cancel()
+
+		tenant, err := s.tenantRepo.FindByID(ctx, conversation.TenantID)
+		if err != nil || !tenant.SummarizeOnResolveEnabled() {
+			return
+		}
+		if _, err := s.summarize(ctx, conversation, entity.ConversationSummaryTriggerResolve, ""); err != nil {
+			logger.Warn("Failed to summarize resolved conversation",
+				zap.String("conversation_id", conversation.ID),
+				zap.Error(err),
+			)
+		}
+	}()
+}
+
+// Summarize generates a new summary of a conversation right away, replacing the
+// previous one
+func (s *ConversationSummaryService) Summarize(ctx context.Context, tenantID, conversationID, userID string) (*entity.ConversationSummary, error) {
+	conversation, err := s.findConversation(ctx, tenantID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	return s.summarize(ctx, conversation, entity.ConversationSummaryTriggerManual, userID)
+}
+
+// Get returns the latest summary of a conversation
+func (s *ConversationSummaryService) Get(ctx context.Context, tenantID, conversationID string) (*entity.ConversationSummary, error) {
+	if _, err := s.findConversation(ctx, tenantID, conversationID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindByConversation(ctx, conversationID)
+}
+
+func (s *ConversationSummaryService) summarize(ctx context.Context, conversation *entity.Conversation, trigger entity.ConversationSummaryTrigger, userID string) (*entity.ConversationSummary, error) {
+	messages, total, err := s.messageRepo.FindByConversation(ctx, conversation.ID, &repository.ListParams{
+		Page:     1,
+		PageSize: conversationSummaryContextMessages,
+		SortBy:   "created_at",
+		SortDir:  "desc",
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(messages, func(i, j int) bool { return messages[i].CreatedAt.Before(messages[j].CreatedAt) })
+
+	var transcript strings.Builder
+	for _, msg := range messages {
+		if strings.TrimSpace(msg.Content) == "" {
+			continue
+		}
+		role := "Customer"
+		switch msg.SenderType {
+		case entity.SenderTypeUser:
+			role = "Agent"
+		case entity.SenderTypeBot:
+			role = "Bot"
+		case entity.SenderTypeSystem:
+			continue
+		}
+		fmt.Fprintf(&transcript, "%s: %s\n", role, truncateText(msg.Content, conversationSummaryMessageMaxSize))
+	}
+	if transcript.Len() == 0 {
+		return nil, errors.Validation("conversation has no messages to summarize")
+	}
+
+	provider, err := s.aiFactory.FirstAvailable()
+	if err != nil {
+		return nil, err
+	}
+	ctx = WithAIUsage(ctx, conversation.TenantID, "", entity.AIFeatureSummary)
+	resp, err := provider.Complete(ctx, &CompletionRequest{
+		Messages: []Message{
+			{Role: "system", Content: "You summarize customer service conversations and reply only with JSON."},
+			{Role: "user", Content: fmt.Sprintf(conversationSummaryPrompt, transcript.String())},
+		},
+		Model:       provider.DefaultModel(),
+		MaxTokens:   600,
+		Temperature: 0.2,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to generate conversation summary")
+	}
+
+	summary, err := parseConversationSummary(resp.Content)
+	if err != nil {
+		return nil, err
+	}
+	summary.ConversationID = conversation.ID
+	summary.TenantID = conversation.TenantID
+	summary.Trigger = trigger
+	summary.GeneratedBy = userID
+	summary.Messages = int(total)
+	summary.Provider = string(provider.Name())
+	summary.Model = resp.Model
+	if summary.Model == "" {
+		summary.Model = provider.DefaultModel()
+	}
+	summary.CreatedAt = s.now()
+
+	if err := s.repo.Upsert(ctx, summary); err != nil {
+		return nil, err
+	}
+	s.embed(ctx, summary)
+	s.publish(ctx, summary)
+	return summary, nil
+}
+
+// embed stores the embedding of a summary's issue. Summaries without one are only
+// left out of similar conversation searches, so failures are logged.
+func (s *ConversationSummaryService) embed(ctx context.Context, summary *entity.ConversationSummary) {
+	if s.embeddings == nil || !s.embeddings.IsAvailable() {
+		return
+	}
+	embedding, err := s.embeddings.GenerateEmbedding(ctx, summary.Issue)
+	if err == nil {
+		err = s.repo.UpdateEmbedding(ctx, summary.ConversationID, embedding)
+	}
+	if err != nil {
+		logger.Warn("Failed to embed conversation summary",
+			zap.String("conversation_id", summary.ConversationID),
+			zap.Error(err),
+		)
+		return
+	}
+	summary.Embedding = embedding
+}
+
+// publish announces a new summary so CRM integrations can pick it up
+func (s *ConversationSummaryService) publish(ctx context.Context, summary *entity.ConversationSummary) {
+	if s.producer == nil {
+		return
+	}
+	if err := s.producer.PublishEvent(ctx, &nats.Event{
+		Type:     EventConversationSummarized,
+		TenantID: summary.TenantID,
+		Payload: map[string]interface{}{
+			"conversation_id": summary.ConversationID,
+			"issue":           summary.Issue,
+			"resolution":      summary.Resolution,
+			"sentiment":       string(summary.Sentiment),
+			"follow_ups":      summary.FollowUps,
+			"trigger":         string(summary.Trigger),
+		},
+		Timestamp: summary.CreatedAt,
+	}); err != nil {
+		logger.Warn("Failed to publish conversation summary",
+			zap.String("conversation_id", summary.ConversationID),
+			zap.Error(err),
+		)
+	}
+}
+
+func (s *ConversationSummaryService) findConversation(ctx context.Context, tenantID, conversationID string) (*entity.Conversation, error) {
+	conversation, err := s.conversationRepo.FindByID(ctx, conversationID)
+	if err != nil || conversation.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+	}
+	return conversation, nil
+}
+
+// parseConversationSummary reads the JSON object of a summary completion. Unknown
+// sentiments count as neutral and blank follow-ups are dropped.
+func parseConversationSummary(content string) (*entity.ConversationSummary, error) {
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, errors.New(errors.ErrCodeInternal, "AI returned no conversation summary")
+	}
+	var parsed struct {
+		Issue      string   `json:"issue"`
+		Resolution string   `json:"resolution"`
+		Sentiment  string   `json:"sentiment"`
+		FollowUps  []string `json:"follow_ups"`
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &parsed); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to parse AI conversation summary")
+	}
+
+	summary := &entity.ConversationSummary{
+		Issue:      strings.TrimSpace(parsed.Issue),
+		Resolution: strings.TrimSpace(parsed.Resolution),
+		Sentiment:  entity.SentimentNeutral,
+		FollowUps:  []string{},
+	}
+	if summary.Issue == "" {
+		return nil, errors.New(errors.ErrCodeInternal, "AI returned a conversation summary without an issue")
+	}
+	switch sentiment := entity.Sentiment(strings.ToLower(strings.TrimSpace(parsed.Sentiment))); sentiment {
+	case entity.SentimentPositive, entity.SentimentNegative:
+		summary.Sentiment = sentiment
+	}
+	for _, followUp := range parsed.FollowUps {
+		if followUp = strings.TrimSpace(followUp); followUp != "" && len(summary.FollowUps) < conversationSummaryMaxFollowUps {
+			summary.FollowUps = append(summary.FollowUps, followUp)
+		}
+	}
+	return summary, nil
+}
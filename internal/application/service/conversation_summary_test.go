@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockConversationSummaryRepo struct {
+	mu        sync.Mutex
+	summaries map[string]*entity.ConversationSummary
+	saved     chan *entity.ConversationSummary
+}
+
+func (m *mockConversationSummaryRepo) Upsert(ctx context.Context, summary *entity.ConversationSummary) error {
+	m.mu.Lock()
+	m.summaries[summary.ConversationID] = summary
+	m.mu.Unlock()
+	select {
+	case m.saved <- summary:
+	default:
+	}
+	return nil
+}
+
+func (m *mockConversationSummaryRepo) FindByConversation(ctx context.Context, conversationID string) (*entity.ConversationSummary, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if summary, ok := m.summaries[conversationID]; ok {
+		return summary, nil
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "conversation summary not found")
+}
+
+func (m *mockConversationSummaryRepo) UpdateEmbedding(ctx context.Context, conversationID string, embedding []float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	summary, ok := m.summaries[conversationID]
+	if !ok {
+		return errors.New(errors.ErrCodeNotFound, "conversation summary not found")
+	}
+	summary.Embedding = embedding
+	return nil
+}
+
+func (m *mockConversationSummaryRepo) ListResolvedWithEmbedding(ctx context.Context, tenantID string, limit int) ([]*entity.ConversationSummary, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var summaries []*entity.ConversationSummary
+	for _, summary := range m.summaries {
+		if summary.TenantID == tenantID && summary.Embedding != nil {
+			summaries = append(summaries, summary)
+		}
+	}
+	return summaries, nil
+}
+
+type conversationSummaryTestEnv struct {
+	svc      *ConversationSummaryService
+	repo     *mockConversationSummaryRepo
+	tenant   *entity.Tenant
+	ai       *extractingAIProvider
+	producer *testutil.MockProducer
+}
+
+func newConversationSummaryTestEnv() *conversationSummaryTestEnv {
+	tenant := &entity.Tenant{ID: "tenant-1", Settings: map[string]string{}}
+	tenants := testutil.NewMockTenantRepository()
+	tenants.Tenants["tenant-1"] = tenant
+
+	conversations := testutil.NewMockConversationRepository()
+	conversations.Conversations["conv-1"] = &entity.Conversation{ID: "conv-1", TenantID: "tenant-1", Status: entity.ConversationStatusResolved}
+	conversations.Conversations["conv-2"] = &entity.Conversation{ID: "conv-2", TenantID: "tenant-1", Status: entity.ConversationStatusOpen}
+
+	messages := testutil.NewMockMessageRepository()
+	messages.Messages["msg-1"] = &entity.Message{
+		ID: "msg-1", ConversationID: "conv-1", SenderType: entity.SenderTypeContact,
+		Content: "My order 4521 arrived damaged", CreatedAt: time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC),
+	}
+	messages.Messages["msg-2"] = &entity.Message{
+		ID: "msg-2", ConversationID: "conv-1", SenderType: entity.SenderTypeSystem,
+		Content: "Conversation assigned to Ana", CreatedAt: time.Date(2026, 5, 4, 12, 1, 0, 0, time.UTC),
+	}
+	messages.Messages["msg-3"] = &entity.Message{
+		ID: "msg-3", ConversationID: "conv-1", SenderType: entity.SenderTypeUser,
+		Content: "Sorry about that, a replacement ships tomorrow", CreatedAt: time.Date(2026, 5, 4, 12, 2, 0, 0, time.UTC),
+	}
+
+	ai := &extractingAIProvider{mockAIProvider: mockAIProvider{name: entity.AIProviderOpenAI, available: true}}
+	ai.reply = "Here you go:\n" + `{"issue": "Order 4521 arrived damaged.", "resolution": "A replacement ships tomorrow.", ` +
+		`"sentiment": "Positive", "follow_ups": ["Send the replacement tracking code", " "]}`
+	factory := NewAIProviderFactory()
+	factory.Register(ai)
+
+	repo := &mockConversationSummaryRepo{
+		summaries: make(map[string]*entity.ConversationSummary),
+		saved:     make(chan *entity.ConversationSummary, 1),
+	}
+	producer := testutil.NewMockProducer()
+	svc := NewConversationSummaryService(repo, conversations, messages, tenants, factory)
+	svc.SetProducer(producer)
+
+	return &conversationSummaryTestEnv{svc: svc, repo: repo, tenant: tenant, ai: ai, producer: producer}
+}
+
+func TestConversationSummaryService_Summarize(t *testing.T) {
+	env := newConversationSummaryTestEnv()
+	ctx := context.Background()
+
+	summary, err := env.svc.Summarize(ctx, "tenant-1", "conv-1", "agent-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Order 4521 arrived damaged.", summary.Issue)
+	assert.Equal(t, "A replacement ships tomorrow.", summary.Resolution)
+	assert.Equal(t, entity.SentimentPositive, summary.Sentiment)
+	assert.Equal(t, []string{"Send the replacement tracking code"}, summary.FollowUps)
+	assert.Equal(t, entity.ConversationSummaryTriggerManual, summary.Trigger)
+	assert.Equal(t, "agent-1", summary.GeneratedBy)
+	assert.Equal(t, 3, summary.Messages)
+	assert.Equal(t, "openai", summary.Provider)
+	assert.Equal(t, "test-model", summary.Model)
+
+	assert.Contains(t, env.ai.prompt, "Customer: My order 4521 arrived damaged\nAgent: Sorry about that")
+	assert.NotContains(t, env.ai.prompt, "assigned to Ana")
+
+	stored, err := env.svc.Get(ctx, "tenant-1", "conv-1")
+	require.NoError(t, err)
+	assert.Same(t, summary, stored)
+
+	require.Len(t, env.producer.Events, 1)
+	assert.Equal(t, EventConversationSummarized, env.producer.Events[0].Type)
+	assert.Equal(t, "conv-1", env.producer.Events[0].Payload["conversation_id"])
+}
+
+func TestConversationSummaryService_EmbedsIssue(t *testing.T) {
+	env := newConversationSummaryTestEnv()
+	factory := NewAIProviderFactory()
+	factory.Register(&keywordEmbeddingProvider{
+		mockAIProvider: mockAIProvider{name: entity.AIProviderOllama, available: true},
+		keywords:       []string{"damaged", "refund"},
+	})
+	env.svc.SetEmbeddingService(NewEmbeddingService(factory, &EmbeddingConfig{DefaultProvider: entity.AIProviderOllama}))
+
+	summary, err := env.svc.Summarize(context.Background(), "tenant-1", "conv-1", "agent-1")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1, 0}, summary.Embedding)
+	assert.Equal(t, []float64{1, 0}, env.repo.summaries["conv-1"].Embedding)
+}
+
+func TestConversationSummaryService_SummarizeErrors(t *testing.T) {
+	env := newConversationSummaryTestEnv()
+	ctx := context.Background()
+
+	_, err := env.svc.Summarize(ctx, "tenant-2", "conv-1", "agent-1")
+	assert.Error(t, err)
+	_, err = env.svc.Get(ctx, "tenant-2", "conv-1")
+	assert.Error(t, err)
+
+	_, err = env.svc.Summarize(ctx, "tenant-1", "conv-2", "agent-1")
+	assert.Error(t, err, "conversations without messages are not summarized")
+
+	env.ai.reply = "I cannot summarize this."
+	_, err = env.svc.Summarize(ctx, "tenant-1", "conv-1", "agent-1")
+	assert.Error(t, err)
+	assert.Empty(t, env.repo.summaries)
+	assert.Empty(t, env.producer.Events)
+}
+
+func TestConversationSummaryService_HandleResolved(t *testing.T) {
+	env := newConversationSummaryTestEnv()
+	conversation := &entity.Conversation{ID: "conv-1", TenantID: "tenant-1", Status: entity.ConversationStatusResolved}
+
+	env.svc.HandleResolved(context.Background(), conversation)
+	select {
+	case summary := <-env.repo.saved:
+		assert.Equal(t, entity.ConversationSummaryTriggerResolve, summary.Trigger)
+		assert.Empty(t, summary.GeneratedBy)
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected a summary of the resolved conversation")
+	}
+
+	env.tenant.Settings[entity.TenantSettingSummarizeOnResolve] = "false"
+	env.svc.HandleResolved(context.Background(), conversation)
+	select {
+	case <-env.repo.saved:
+		t.Fatal("tenant turned summaries on resolve off")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestParseConversationSummary(t *testing.T) {
+	summary, err := parseConversationSummary(`{"issue": "Refund", "sentiment": "angry"}`)
+	require.NoError(t, err)
+	assert.Equal(t, entity.SentimentNeutral, summary.Sentiment)
+	assert.Empty(t, summary.Resolution)
+	assert.NotNil(t, summary.FollowUps)
+
+	_, err = parseConversationSummary(`{"issue": " "}`)
+	assert.Error(t, err)
+}
@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
 	"github.com/msgfy/linktor/pkg/testutil"
 	"github.com/stretchr/testify/assert"
 )
@@ -36,6 +38,32 @@ func TestConversationService_Create(t *testing.T) {
 	assert.Equal(t, entity.ConversationStatusOpen, conv.Status)
 }
 
+func TestConversationService_OtherTenant(t *testing.T) {
+	ctx := context.Background()
+	svc, _ := setupConversationTest()
+
+	_, err := svc.Create(ctx, &CreateConversationInput{
+		TenantID:  "tenant2",
+		ContactID: "contact1",
+		ChannelID: "channel1",
+	})
+	assert.Equal(t, errors.ErrCodeContactNotFound, errors.GetAppError(err).Code, "contact of another tenant")
+
+	conv, err := svc.Create(ctx, &CreateConversationInput{TenantID: "tenant1", ContactID: "contact1", ChannelID: "channel1"})
+	assert.NoError(t, err)
+
+	_, err = svc.GetByID(ctx, "tenant2", conv.ID)
+	assert.Equal(t, errors.ErrCodeConversationNotFound, errors.GetAppError(err).Code)
+	_, err = svc.GetByID(ctx, "", conv.ID)
+	assert.True(t, errors.IsNotFound(err), "lookups require a tenant")
+	_, err = svc.Resolve(ctx, "tenant2", conv.ID, nil)
+	assert.True(t, errors.IsNotFound(err))
+
+	found, err := svc.GetByID(ctx, "tenant1", conv.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, entity.ConversationStatusOpen, found.Status)
+}
+
 func TestConversationService_Create_MissingContact(t *testing.T) {
 	svc, _ := setupConversationTest()
 
@@ -57,7 +85,7 @@ func TestConversationService_Resolve(t *testing.T) {
 		ChannelID: "channel1",
 	})
 
-	resolved, err := svc.Resolve(context.Background(), conv.ID)
+	resolved, err := svc.Resolve(context.Background(), "tenant1", conv.ID, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, entity.ConversationStatusResolved, resolved.Status)
 
@@ -75,8 +103,17 @@ func TestConversationService_Reopen(t *testing.T) {
 		ChannelID: "channel1",
 	})
 
-	svc.Resolve(context.Background(), conv.ID)
-	reopened, err := svc.Reopen(context.Background(), conv.ID)
+	svc.Resolve(context.Background(), "tenant1", conv.ID, nil)
+	reopened, err := svc.Reopen(context.Background(), "tenant1", conv.ID, "user1")
 	assert.NoError(t, err)
 	assert.Equal(t, entity.ConversationStatusOpen, reopened.Status)
 }
+
+func TestConversationService_List_LabelFilter(t *testing.T) {
+	svc, _ := setupConversationTest()
+
+	params := repository.NewListParams()
+	_, _, err := svc.List(context.Background(), "tenant1", &ConversationFilters{LabelIDs: []string{"label-1", "label-2"}}, params)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"label-1", "label-2"}, params.Filters["label_ids"])
+}
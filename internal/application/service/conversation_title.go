@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// conversationTitleMinMessages is how many messages a conversation needs before it is titled
+	conversationTitleMinMessages = 2
+	// conversationTitleRefreshEvery is how many new messages trigger a new title as the topic evolves
+	conversationTitleRefreshEvery = 10
+	// conversationTitleContextMessages is how many recent messages the title is generated from
+	conversationTitleContextMessages = 20
+	// conversationTitleMaxLength caps generated titles
+	conversationTitleMaxLength = 80
+	// conversationTitleMaxManualLength caps titles set by agents
+	conversationTitleMaxManualLength = 255
+	conversationTitleTimeout         = 30 * time.Second
+)
+
+const conversationTitlePrompt = "You write titles for customer service conversations. " +
+	"Reply with a single title of at most 8 words naming the customer's topic and any order, ticket or product reference, " +
+	"for example \"Refund request for order 4521\". Reply with the title only, without quotes."
+
+// ConversationTitleService generates short AI titles for conversations so lists and
+// search show the topic instead of the first message, and keeps manual overrides
+type ConversationTitleService struct {
+	conversationRepo repository.ConversationRepository
+	messageRepo      repository.MessageRepository
+	tenantRepo       repository.TenantRepository
+	aiFactory        *AIProviderFactory
+}
+
+// NewConversationTitleService creates a new conversation title service
+func NewConversationTitleService(
+	conversationRepo repository.ConversationRepository,
+	messageRepo repository.MessageRepository,
+	tenantRepo repository.TenantRepository,
+	aiFactory *AIProviderFactory,
+) *ConversationTitleService {
+	return &ConversationTitleService{
+		conversationRepo: conversationRepo,
+		messageRepo:      messageRepo,
+		tenantRepo:       tenantRepo,
+		aiFactory:        aiFactory,
+	}
+}
+
+// HandleInbound titles or re-titles the conversation of an inbound message in the
+// background when the tenant enabled titles and enough messages arrived since the last one
+func (s *ConversationTitleService) HandleInbound(ctx context.Context, message *entity.Message, conversation *entity.Conversation) error {
+	if conversation.TitleSource == entity.ConversationTitleSourceManual {
+		return nil
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), conversationTitleTimeout)
+		defer cancel()
+		if _, err := s.RefreshIfDue(ctx, conversation.ID); err != nil {
+			logger.Warn("Failed to title conversation",
+				zap.String("conversation_id", conversation.ID),
+				zap.Error(err),
+			)
+		}
+	}()
+	return nil
+}
+
+// RefreshIfDue generates a new title when the conversation has none yet or its topic
+// may have moved on. It reports whether the title changed.
+func (s *ConversationTitleService) RefreshIfDue(ctx context.Context, conversationID string) (bool, error) {
+	conversation, err := s.conversationRepo.FindByID(ctx, conversationID)
+	if err != nil {
+		return false, err
+	}
+	if conversation.TitleSource == entity.ConversationTitleSourceManual {
+		return false, nil
+	}
+
+	tenant, err := s.tenantRepo.FindByID(ctx, conversation.TenantID)
+	if err != nil {
+		return false, err
+	}
+	if !tenant.ConversationTitlesEnabled() {
+		return false, nil
+	}
+
+	messages, total, err := s.recentMessages(ctx, conversation.ID)
+	if err != nil {
+		return false, err
+	}
+	if !conversationTitleDue(conversation, int(total)) {
+		return false, nil
+	}
+
+	title, err := s.generate(WithAIUsage(ctx, conversation.TenantID, "", entity.AIFeatureTitle), messages)
+	if err != nil {
+		return false, err
+	}
+	if err := s.conversationRepo.UpdateTitle(ctx, conversation.ID, title, entity.ConversationTitleSourceAI, int(total)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Regenerate generates a new AI title right away, replacing a manual override
+func (s *ConversationTitleService) Regenerate(ctx context.Context, tenantID, conversationID string) (*entity.Conversation, error) {
+	conversation, err := s.findConversation(ctx, tenantID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	messages, total, err := s.recentMessages(ctx, conversation.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, errors.Validation("conversation has no messages to title")
+	}
+
+	title, err := s.generate(WithAIUsage(ctx, conversation.TenantID, "", entity.AIFeatureTitle), messages)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.conversationRepo.UpdateTitle(ctx, conversation.ID, title, entity.ConversationTitleSourceAI, int(total)); err != nil {
+		return nil, err
+	}
+	return s.conversationRepo.FindByID(ctx, conversation.ID)
+}
+
+// SetTitle overrides the title of a conversation. Manual titles are never replaced by
+// generated ones until the title is regenerated.
+func (s *ConversationTitleService) SetTitle(ctx context.Context, tenantID, conversationID, title string) (*entity.Conversation, error) {
+	title = strings.TrimSpace(title)
+	if title == "" {
+		return nil, errors.Validation("title is required")
+	}
+	if len(title) > conversationTitleMaxManualLength {
+		return nil, errors.Validation(fmt.Sprintf("title must be at most %d characters", conversationTitleMaxManualLength))
+	}
+
+	conversation, err := s.findConversation(ctx, tenantID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.conversationRepo.UpdateTitle(ctx, conversation.ID, title, entity.ConversationTitleSourceManual, conversation.TitleMessages); err != nil {
+		return nil, err
+	}
+	return s.conversationRepo.FindByID(ctx, conversation.ID)
+}
+
+func (s *ConversationTitleService) findConversation(ctx context.Context, tenantID, conversationID string) (*entity.Conversation, error) {
+	conversation, err := s.conversationRepo.FindByID(ctx, conversationID)
+	if err != nil || conversation.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+	}
+	return conversation, nil
+}
+
+// recentMessages returns the latest messages of a conversation, oldest first, and
+// the total number of messages in it
+func (s *ConversationTitleService) recentMessages(ctx context.Context, conversationID string) ([]*entity.Message, int64, error) {
+	params := repository.NewListParams()
+	params.PageSize = conversationTitleContextMessages
+
+	messages, total, err := s.messageRepo.FindByConversation(ctx, conversationID, params)
+	if err != nil {
+		return nil, 0, err
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].CreatedAt.Before(messages[j].CreatedAt)
+	})
+	return messages, total, nil
+}
+
+func (s *ConversationTitleService) generate(ctx context.Context, messages []*entity.Message) (string, error) {
+	provider, err := s.aiFactory.FirstAvailable()
+	if err != nil {
+		return "", err
+	}
+
+	var transcript strings.Builder
+	for _, message := range messages {
+		speaker := "Agent"
+		if message.SenderType == entity.SenderTypeContact {
+			speaker = "Customer"
+		}
+		content := message.Content
+		if message.ContentType != entity.ContentTypeText && message.ContentType != "" {
+			content = fmt.Sprintf("[%s] %s", message.ContentType, content)
+		}
+		fmt.Fprintf(&transcript, "%s: %s\n", speaker, truncateText(content, 500))
+	}
+
+	resp, err := provider.Complete(ctx, &CompletionRequest{
+		Messages: []Message{
+			{Role: "system", Content: conversationTitlePrompt},
+			{Role: "user", Content: transcript.String()},
+		},
+		Model:       provider.DefaultModel(),
+		MaxTokens:   30,
+		Temperature: 0.2,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, errors.ErrCodeInternal, "failed to generate conversation title")
+	}
+
+	title := cleanConversationTitle(resp.Content)
+	if title == "" {
+		return "", errors.New(errors.ErrCodeInternal, "AI returned an empty conversation title")
+	}
+	return title, nil
+}
+
+// conversationTitleDue reports whether a conversation with messageCount messages
+// should get a new generated title
+func conversationTitleDue(conversation *entity.Conversation, messageCount int) bool {
+	if conversation.TitleSource == entity.ConversationTitleSourceManual || messageCount < conversationTitleMinMessages {
+		return false
+	}
+	if conversation.Title == "" {
+		return true
+	}
+	return messageCount-conversation.TitleMessages >= conversationTitleRefreshEvery
+}
+
+// cleanConversationTitle keeps the first line of a completion without quotes,
+// a "Title:" prefix or trailing punctuation
+func cleanConversationTitle(content string) string {
+	title := strings.TrimSpace(content)
+	if i := strings.IndexByte(title, '\n'); i >= 0 {
+		title = title[:i]
+	}
+	title = strings.TrimSpace(title)
+	if len(title) >= 6 && strings.EqualFold(title[:6], "title:") {
+		title = strings.TrimSpace(title[6:])
+	}
+	title = strings.Trim(strings.TrimRight(title, "."), "\"'`*")
+	title = strings.TrimRight(title, ".")
+	return strings.TrimSpace(truncateText(title, conversationTitleMaxLength))
+}
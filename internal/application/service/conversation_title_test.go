@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type titleTestEnv struct {
+	svc           *ConversationTitleService
+	conversations *testutil.MockConversationRepository
+	messages      *testutil.MockMessageRepository
+	tenant        *entity.Tenant
+	ai            *extractingAIProvider
+}
+
+func newTitleTestEnv() *titleTestEnv {
+	conversations := testutil.NewMockConversationRepository()
+	messages := testutil.NewMockMessageRepository()
+	tenants := testutil.NewMockTenantRepository()
+
+	tenant := &entity.Tenant{ID: "tenant-1", Settings: map[string]string{entity.TenantSettingConversationTitles: "true"}}
+	tenants.Tenants["tenant-1"] = tenant
+	conversations.Conversations["conv-1"] = &entity.Conversation{ID: "conv-1", TenantID: "tenant-1", ContactID: "contact-1"}
+
+	ai := &extractingAIProvider{mockAIProvider: mockAIProvider{name: entity.AIProviderOpenAI, available: true}}
+	factory := NewAIProviderFactory()
+	factory.Register(ai)
+
+	return &titleTestEnv{
+		svc:           NewConversationTitleService(conversations, messages, tenants, factory),
+		conversations: conversations,
+		messages:      messages,
+		tenant:        tenant,
+		ai:            ai,
+	}
+}
+
+func (e *titleTestEnv) addMessages(count int) {
+	start := len(e.messages.Messages)
+	for i := start; i < start+count; i++ {
+		senderType := entity.SenderTypeContact
+		if i%2 == 1 {
+			senderType = entity.SenderTypeUser
+		}
+		id := fmt.Sprintf("msg-%d", i)
+		e.messages.Messages[id] = &entity.Message{
+			ID:             id,
+			ConversationID: "conv-1",
+			SenderType:     senderType,
+			ContentType:    entity.ContentTypeText,
+			Content:        fmt.Sprintf("message %d about order 4521", i),
+			CreatedAt:      time.Date(2026, 3, 1, 10, i, 0, 0, time.UTC),
+		}
+	}
+}
+
+func TestConversationTitleService_RefreshIfDue(t *testing.T) {
+	env := newTitleTestEnv()
+	ctx := context.Background()
+	env.ai.reply = "Title: \"Refund request for order 4521.\""
+
+	env.addMessages(1)
+	changed, err := env.svc.RefreshIfDue(ctx, "conv-1")
+	require.NoError(t, err)
+	assert.False(t, changed, "a single message is not enough to title")
+
+	env.addMessages(1)
+	changed, err = env.svc.RefreshIfDue(ctx, "conv-1")
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	conversation := env.conversations.Conversations["conv-1"]
+	assert.Equal(t, "Refund request for order 4521", conversation.Title)
+	assert.Equal(t, entity.ConversationTitleSourceAI, conversation.TitleSource)
+	assert.Equal(t, 2, conversation.TitleMessages)
+	assert.Contains(t, env.ai.prompt, "Customer: message 0 about order 4521\nAgent: message 1")
+
+	// The title is only refreshed once the topic had room to evolve
+	env.addMessages(conversationTitleRefreshEvery - 1)
+	changed, err = env.svc.RefreshIfDue(ctx, "conv-1")
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	env.addMessages(1)
+	env.ai.reply = "Refund approved, replacement shipping for order 4521"
+	changed, err = env.svc.RefreshIfDue(ctx, "conv-1")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "Refund approved, replacement shipping for order 4521", conversation.Title)
+}
+
+func TestConversationTitleService_TenantToggle(t *testing.T) {
+	env := newTitleTestEnv()
+	env.tenant.Settings[entity.TenantSettingConversationTitles] = "false"
+	env.ai.reply = "Delivery delay"
+	env.addMessages(3)
+
+	changed, err := env.svc.RefreshIfDue(context.Background(), "conv-1")
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Empty(t, env.conversations.Conversations["conv-1"].Title)
+}
+
+func TestConversationTitleService_ManualOverride(t *testing.T) {
+	env := newTitleTestEnv()
+	ctx := context.Background()
+	env.ai.reply = "Delivery delay"
+	env.addMessages(2)
+
+	conversation, err := env.svc.SetTitle(ctx, "tenant-1", "conv-1", "  VIP escalation  ")
+	require.NoError(t, err)
+	assert.Equal(t, "VIP escalation", conversation.Title)
+	assert.Equal(t, entity.ConversationTitleSourceManual, conversation.TitleSource)
+
+	env.addMessages(conversationTitleRefreshEvery)
+	changed, err := env.svc.RefreshIfDue(ctx, "conv-1")
+	require.NoError(t, err)
+	assert.False(t, changed, "manual titles are not replaced automatically")
+
+	_, err = env.svc.SetTitle(ctx, "tenant-1", "conv-1", " ")
+	assert.Error(t, err)
+	_, err = env.svc.SetTitle(ctx, "tenant-2", "conv-1", "Other tenant")
+	assert.Error(t, err)
+
+	conversation, err = env.svc.Regenerate(ctx, "tenant-1", "conv-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Delivery delay", conversation.Title)
+	assert.Equal(t, entity.ConversationTitleSourceAI, conversation.TitleSource)
+}
+
+func TestCleanConversationTitle(t *testing.T) {
+	assert.Equal(t, "Refund request for order 4521", cleanConversationTitle("Refund request for order 4521"))
+	assert.Equal(t, "Password reset", cleanConversationTitle("  **Password reset**\nThe customer cannot log in."))
+	assert.Equal(t, "Billing question", cleanConversationTitle("title: 'Billing question'."))
+	assert.Len(t, cleanConversationTitle(string(make([]byte, 200))), conversationTitleMaxLength)
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// templateVariablePattern matches {{ name }} and {{ name | filter | ... }} placeholders
+var templateVariablePattern = regexp.MustCompile(`\{\{\s*([^{}|]+?)\s*((?:\|[^{}|]*)*)\}\}`)
+
+// RenderTemplate replaces {{variable}} placeholders in text with values from vars.
+// Placeholders accept filters separated by pipes:
+//
+//	{{contact.first_name | default:"there"}}
+//	{{intent.name | upper}}
+//
+// Placeholders without a value and without a default are left untouched and their
+// names are returned as missing, so broken templates are visible in previews.
+func RenderTemplate(text string, vars map[string]string) (string, []string) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	var missing []string
+	rendered := templateVariablePattern.ReplaceAllStringFunc(text, func(placeholder string) string {
+		match := templateVariablePattern.FindStringSubmatch(placeholder)
+		name := match[1]
+		value, found := vars[name]
+
+		for _, filter := range strings.Split(match[2], "|") {
+			filter = strings.TrimSpace(filter)
+			switch {
+			case filter == "":
+			case strings.HasPrefix(filter, "default:"):
+				if value == "" {
+					value = unquoteFilterArg(strings.TrimPrefix(filter, "default:"))
+					found = true
+				}
+			case filter == "upper":
+				value = strings.ToUpper(value)
+			case filter == "lower":
+				value = strings.ToLower(value)
+			case filter == "trim":
+				value = strings.TrimSpace(value)
+			}
+		}
+
+		if !found {
+			missing = append(missing, name)
+			return placeholder
+		}
+		return value
+	})
+
+	return rendered, missing
+}
+
+func unquoteFilterArg(arg string) string {
+	arg = strings.TrimSpace(arg)
+	if unquoted, err := strconv.Unquote(arg); err == nil {
+		return unquoted
+	}
+	return strings.Trim(arg, `'"`)
+}
+
+// ConversationVariablesService builds the variable context of a conversation: contact
+// fields, custom attributes, conversation metadata, the last detected intent, the
+// click-to-WhatsApp referral and flow data. The same variables are available to flow
+// messages, VRE data payloads and any text rendered through RenderTemplate.
+//
+// Variable names:
+//
+//	contact.id, contact.name, contact.first_name, contact.email, contact.phone, contact.tags
+//	custom.<field>                 contact custom attributes
+//	conversation.id, conversation.status, conversation.priority, conversation.subject,
+//	conversation.channel_id, conversation.tags, conversation.metadata.<key>
+//	intent.name, intent.confidence, intent.entities.<key>
+//	referral.source_type, referral.source_id, referral.headline, referral.body, ...
+//	flow.<key> (also <key>)        data collected by the active flow
+//	entity.<key>                   entities extracted by the AI
+//	order.reference, order.status, order.title, order.url, order.attributes.<key>
+//	                               latest linked order; ticket.* and booking.* likewise
+type ConversationVariablesService struct {
+	conversationRepo repository.ConversationRepository
+	contactRepo      repository.ContactRepository
+	contextService   *ConversationContextService
+	linkedObjectRepo repository.LinkedObjectRepository
+}
+
+// NewConversationVariablesService creates a new conversation variables service
+func NewConversationVariablesService(
+	conversationRepo repository.ConversationRepository,
+	contactRepo repository.ContactRepository,
+	contextService *ConversationContextService,
+) *ConversationVariablesService {
+	return &ConversationVariablesService{
+		conversationRepo: conversationRepo,
+		contactRepo:      contactRepo,
+		contextService:   contextService,
+	}
+}
+
+// SetLinkedObjectRepository exposes the orders, tickets and bookings linked to a conversation
+func (s *ConversationVariablesService) SetLinkedObjectRepository(linkedObjectRepo repository.LinkedObjectRepository) {
+	s.linkedObjectRepo = linkedObjectRepo
+}
+
+// PreviewTemplateInput represents a request to preview a rendered template
+type PreviewTemplateInput struct {
+	Template       string            `json:"template" binding:"required"`
+	ConversationID string            `json:"conversation_id,omitempty"`
+	Variables      map[string]string `json:"variables,omitempty"` // Overrides or sample values
+}
+
+// TemplatePreview is the result of rendering a template against a conversation
+type TemplatePreview struct {
+	Rendered  string            `json:"rendered"`
+	Missing   []string          `json:"missing,omitempty"`
+	Variables map[string]string `json:"variables"`
+}
+
+// Resolve returns the variables of a conversation belonging to a tenant
+func (s *ConversationVariablesService) Resolve(ctx context.Context, tenantID, conversationID string) (map[string]string, error) {
+	conversation, err := s.conversationRepo.FindByID(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+	}
+	return s.build(ctx, conversation), nil
+}
+
+// ResolveVariables returns the variables of a conversation without tenant checks. It is
+// used by the flow engine, which already runs inside the conversation's tenant.
+func (s *ConversationVariablesService) ResolveVariables(ctx context.Context, conversationID string) (map[string]string, error) {
+	conversation, err := s.conversationRepo.FindByID(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	return s.build(ctx, conversation), nil
+}
+
+// Preview renders a template against a conversation's variables. Variables given in
+// the input override the conversation's; without a conversation only they are used.
+func (s *ConversationVariablesService) Preview(ctx context.Context, tenantID string, input *PreviewTemplateInput) (*TemplatePreview, error) {
+	vars := make(map[string]string)
+	if input.ConversationID != "" {
+		resolved, err := s.Resolve(ctx, tenantID, input.ConversationID)
+		if err != nil {
+			return nil, err
+		}
+		vars = resolved
+	}
+	for key, value := range input.Variables {
+		vars[key] = value
+	}
+
+	rendered, missing := RenderTemplate(input.Template, vars)
+	sort.Strings(missing)
+
+	return &TemplatePreview{Rendered: rendered, Missing: missing, Variables: vars}, nil
+}
+
+// RenderData renders the string values of a data payload, such as VRE template data,
+// against a conversation's variables. Non-string values are kept as is.
+func (s *ConversationVariablesService) RenderData(ctx context.Context, tenantID, conversationID string, data map[string]interface{}) (map[string]interface{}, error) {
+	vars, err := s.Resolve(ctx, tenantID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered := make(map[string]interface{}, len(data))
+	for key, value := range data {
+		if text, ok := value.(string); ok {
+			value, _ = RenderTemplate(text, vars)
+		}
+		rendered[key] = value
+	}
+	return rendered, nil
+}
+
+// build collects the variables of a conversation. Missing contacts or contexts only
+// leave their variables out.
+func (s *ConversationVariablesService) build(ctx context.Context, conversation *entity.Conversation) map[string]string {
+	vars := map[string]string{
+		"conversation.id":         conversation.ID,
+		"conversation.status":     string(conversation.Status),
+		"conversation.priority":   string(conversation.Priority),
+		"conversation.subject":    conversation.Subject,
+		"conversation.channel_id": conversation.ChannelID,
+		"conversation.tags":       strings.Join(conversation.Tags, ", "),
+	}
+	for key, value := range conversation.Metadata {
+		vars["conversation.metadata."+key] = value
+		if referralKey, ok := strings.CutPrefix(key, "referral_"); ok {
+			vars["referral."+referralKey] = value
+		}
+	}
+
+	if s.contactRepo != nil && conversation.ContactID != "" {
+		if contact, err := s.contactRepo.FindByID(ctx, conversation.ContactID); err == nil && contact != nil {
+			addContactVariables(vars, contact)
+		}
+	}
+
+	if s.contextService != nil {
+		if convContext, err := s.contextService.Get(ctx, conversation.ID); err == nil && convContext != nil {
+			AddContextVariables(vars, convContext)
+		}
+	}
+
+	if s.linkedObjectRepo != nil {
+		if objects, err := s.linkedObjectRepo.ListByConversation(ctx, conversation.ID); err == nil {
+			AddLinkedObjectVariables(vars, objects)
+		}
+	}
+
+	return vars
+}
+
+func addContactVariables(vars map[string]string, contact *entity.Contact) {
+	vars["contact.id"] = contact.ID
+	vars["contact.name"] = contact.Name
+	vars["contact.first_name"], _, _ = strings.Cut(strings.TrimSpace(contact.Name), " ")
+	vars["contact.email"] = contact.Email
+	vars["contact.phone"] = contact.Phone
+	vars["contact.tags"] = strings.Join(contact.Tags, ", ")
+	for key, value := range contact.CustomFields {
+		// Underscore-prefixed fields are internal flags such as _blocked
+		if strings.HasPrefix(key, "_") {
+			continue
+		}
+		vars["custom."+key] = value
+	}
+}
+
+// AddContextVariables adds the intent, flow data and AI entities of a conversation
+// context to vars. Flow data is exposed both as flow.<key> and as <key>, which flow
+// templates have always used.
+func AddContextVariables(vars map[string]string, convContext *entity.ConversationContext) {
+	if convContext.Intent != nil {
+		vars["intent.name"] = convContext.Intent.Name
+		vars["intent.confidence"] = strconv.FormatFloat(convContext.Intent.Confidence, 'f', 2, 64)
+		for key, value := range convContext.Intent.Entities {
+			vars["intent.entities."+key] = value
+		}
+	}
+
+	if collected, ok := convContext.State["collected_data"].(map[string]string); ok {
+		for key, value := range collected {
+			vars[key] = value
+			vars["flow."+key] = value
+		}
+	} else if collected, ok := convContext.State["collected_data"].(map[string]interface{}); ok {
+		for key, value := range collected {
+			if text, ok := value.(string); ok {
+				vars[key] = text
+				vars["flow."+key] = text
+			}
+		}
+	}
+
+	for key, value := range convContext.Entities {
+		if text, ok := value.(string); ok {
+			vars["entity."+key] = text
+		}
+	}
+}
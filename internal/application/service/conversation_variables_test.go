@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestVariablesService() *ConversationVariablesService {
+	conversationRepo := testutil.NewMockConversationRepository()
+	conversationRepo.Conversations["conv-1"] = &entity.Conversation{
+		ID:        "conv-1",
+		TenantID:  "tenant-1",
+		ContactID: "contact-1",
+		ChannelID: "channel-1",
+		Status:    entity.ConversationStatusOpen,
+		Metadata: map[string]string{
+			"order_id":             "A-42",
+			"referral_source_id":   "ad-123",
+			"referral_headline":    "Summer sale",
+			"referral_source_type": "ad",
+		},
+	}
+
+	contactRepo := testutil.NewMockContactRepository()
+	contactRepo.Contacts["contact-1"] = &entity.Contact{
+		ID:           "contact-1",
+		TenantID:     "tenant-1",
+		Name:         "Maria Silva",
+		Email:        "maria@example.com",
+		CustomFields: map[string]string{"plan": "gold", "_blocked": "false"},
+	}
+
+	contextRepo := newMockConversationContextRepository()
+	convContext := entity.NewConversationContext("conv-1")
+	convContext.ID = "ctx-1"
+	convContext.Intent = entity.NewIntent("order_status", 0.92)
+	convContext.State["collected_data"] = map[string]string{"city": "Recife"}
+	contextRepo.Create(context.Background(), convContext)
+
+	return NewConversationVariablesService(conversationRepo, contactRepo, NewConversationContextService(contextRepo, nil))
+}
+
+func TestRenderTemplate(t *testing.T) {
+	vars := map[string]string{"contact.name": "Maria", "contact.email": ""}
+
+	tests := []struct {
+		template string
+		expected string
+		missing  []string
+	}{
+		{"Hi {{contact.name}}!", "Hi Maria!", nil},
+		{"Hi {{ contact.name | upper }}!", "Hi MARIA!", nil},
+		{`Hi {{contact.nickname | default:"there"}}`, "Hi there", nil},
+		{`Mail: {{contact.email | default:"none"}}`, "Mail: none", nil},
+		{"Order {{conversation.metadata.order_id}}", "Order {{conversation.metadata.order_id}}", []string{"conversation.metadata.order_id"}},
+		{"No placeholders", "No placeholders", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			rendered, missing := RenderTemplate(tt.template, vars)
+			assert.Equal(t, tt.expected, rendered)
+			assert.Equal(t, tt.missing, missing)
+		})
+	}
+}
+
+func TestConversationVariablesResolve(t *testing.T) {
+	svc := newTestVariablesService()
+
+	vars, err := svc.Resolve(context.Background(), "tenant-1", "conv-1")
+	require.NoError(t, err)
+
+	assert.Equal(t, "Maria Silva", vars["contact.name"])
+	assert.Equal(t, "Maria", vars["contact.first_name"])
+	assert.Equal(t, "gold", vars["custom.plan"])
+	assert.NotContains(t, vars, "custom._blocked")
+	assert.Equal(t, "A-42", vars["conversation.metadata.order_id"])
+	assert.Equal(t, "Summer sale", vars["referral.headline"])
+	assert.Equal(t, "order_status", vars["intent.name"])
+	assert.Equal(t, "Recife", vars["flow.city"])
+	assert.Equal(t, "Recife", vars["city"])
+}
+
+func TestConversationVariablesRejectsOtherTenant(t *testing.T) {
+	svc := newTestVariablesService()
+
+	_, err := svc.Resolve(context.Background(), "tenant-2", "conv-1")
+	assert.Error(t, err)
+}
+
+func TestConversationVariablesPreview(t *testing.T) {
+	svc := newTestVariablesService()
+
+	preview, err := svc.Preview(context.Background(), "tenant-1", &PreviewTemplateInput{
+		Template:       "Hi {{contact.first_name}}, about {{referral.headline}} in {{city}}: {{promo_code}} {{missing}}",
+		ConversationID: "conv-1",
+		Variables:      map[string]string{"promo_code": "SUN10"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Hi Maria, about Summer sale in Recife: SUN10 {{missing}}", preview.Rendered)
+	assert.Equal(t, []string{"missing"}, preview.Missing)
+}
+
+func TestFlowEngineUsesConversationVariables(t *testing.T) {
+	svc := newTestVariablesService()
+	engine, _, _ := newFlowEngine()
+	engine.SetVariableResolver(svc)
+
+	convContext := entity.NewConversationContext("conv-1")
+	assert.Equal(t, "Hello Maria, your plan is gold", engine.ProcessTemplate("Hello {{contact.first_name}}, your plan is {{custom.plan}}", convContext))
+}
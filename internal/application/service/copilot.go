@@ -0,0 +1,381 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Agent copilot limits
+const (
+	copilotTimeout             = 30 * time.Second
+	copilotContextMessages     = 30
+	copilotMessageMaxSize      = 1000
+	copilotArticleLimit        = 3
+	copilotKnowledgeBaseLimit  = 10
+	copilotSuggestionListLimit = 50
+)
+
+const copilotPrompt = `You assist a customer service agent during a live conversation. Read the
+conversation below and reply with a single JSON object with two keys:
+- "reply": the next message the agent could send to the customer, in the customer's language
+- "summary": two or three sentences on what the customer wants and what was done so far
+
+Conversation:
+%s`
+
+// CopilotNotifier delivers copilot suggestions to the agent of a conversation
+type CopilotNotifier interface {
+	NotifySuggestions(userID string, suggestions []*entity.CopilotSuggestion)
+}
+
+// CopilotResponseInput represents what an agent did with a copilot suggestion
+type CopilotResponseInput struct {
+	Status  entity.CopilotSuggestionStatus `json:"status" binding:"required"` // accepted, edited or rejected
+	Content string                         `json:"content,omitempty"`         // The edited text, for edited suggestions
+}
+
+// CopilotService helps agents on escalated conversations. Each customer message
+// prompts a suggested reply, relevant knowledge base articles and a summary, pushed
+// to the assigned agent, and assignments prompt the first replies of similar resolved
+// conversations; what agents do with them is kept for quality metrics.
+type CopilotService struct {
+	repo             repository.CopilotRepository
+	conversationRepo repository.ConversationRepository
+	messageRepo      repository.MessageRepository
+	tenantRepo       repository.TenantRepository
+	knowledgeService *KnowledgeService
+	aiFactory        *AIProviderFactory
+	summaries        repository.ConversationSummaryRepository
+	embeddings       *EmbeddingService
+	notifier         CopilotNotifier
+	now              func() time.Time
+}
+
+// NewCopilotService creates a new agent copilot service
+func NewCopilotService(
+	repo repository.CopilotRepository,
+	conversationRepo repository.ConversationRepository,
+	messageRepo repository.MessageRepository,
+	tenantRepo repository.TenantRepository,
+	knowledgeService *KnowledgeService,
+	aiFactory *AIProviderFactory,
+) *CopilotService {
+	return &CopilotService{
+		repo:             repo,
+		conversationRepo: conversationRepo,
+		messageRepo:      messageRepo,
+		tenantRepo:       tenantRepo,
+		knowledgeService: knowledgeService,
+		aiFactory:        aiFactory,
+		now:              time.Now,
+	}
+}
+
+// SetNotifier sets the notifier that delivers suggestions to agents
+func (s *CopilotService) SetNotifier(notifier CopilotNotifier) {
+	s.notifier = notifier
+}
+
+// HandleInbound prepares suggestions in the background when a customer writes in an
+// escalated conversation an agent is handling
+func (s *CopilotService) HandleInbound(ctx context.Context, message *entity.Message, conversation *entity.Conversation) error {
+	if message.SenderType != entity.SenderTypeContact || !conversation.IsEscalated() || conversation.AssignedUserID == nil {
+		return nil
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), copilotTimeout)
+		defer cancel()
+
+		tenant, err := s.tenantRepo.FindByID(ctx, conversation.TenantID)
+		if err != nil || !tenant.AgentCopilotEnabled() {
+			return
+		}
+		if _, err := s.suggest(ctx, tenant, conversation, *conversation.AssignedUserID, message); err != nil {
+			logger.Warn("Failed to prepare copilot suggestions",
+				zap.String("conversation_id", conversation.ID),
+				zap.Error(err),
+			)
+		}
+	}()
+	return nil
+}
+
+// Suggest prepares suggestions for the agent asking for them, based on the latest
+// customer message of the conversation
+func (s *CopilotService) Suggest(ctx context.Context, tenantID, conversationID, userID string) ([]*entity.CopilotSuggestion, error) {
+	conversation, err := s.findConversation(ctx, tenantID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if !tenant.AgentCopilotEnabled() {
+		return nil, errors.New(errors.ErrCodeBadRequest, "agent copilot is not enabled")
+	}
+	return s.suggest(ctx, tenant, conversation, userID, nil)
+}
+
+// ListSuggestions lists the latest suggestions made in a conversation, newest first
+func (s *CopilotService) ListSuggestions(ctx context.Context, tenantID, conversationID string) ([]*entity.CopilotSuggestion, error) {
+	if _, err := s.findConversation(ctx, tenantID, conversationID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByConversation(ctx, conversationID, copilotSuggestionListLimit)
+}
+
+// Respond records whether the agent accepted, edited or rejected a suggestion
+func (s *CopilotService) Respond(ctx context.Context, tenantID, id, userID string, input *CopilotResponseInput) (*entity.CopilotSuggestion, error) {
+	suggestion, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if suggestion.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeNotFound, "copilot suggestion not found")
+	}
+	if !suggestion.IsPending() {
+		return nil, errors.Validation(fmt.Sprintf("suggestion is already %s", suggestion.Status))
+	}
+
+	content := strings.TrimSpace(input.Content)
+	switch input.Status {
+	case entity.CopilotSuggestionAccepted, entity.CopilotSuggestionRejected:
+		content = ""
+	case entity.CopilotSuggestionEdited:
+		if content == "" {
+			return nil, errors.Validation("content is required for edited suggestions")
+		}
+	default:
+		return nil, errors.Validation("status must be accepted, edited or rejected")
+	}
+
+	now := s.now()
+	suggestion.Status = input.Status
+	suggestion.FinalContent = content
+	suggestion.RespondedBy = userID
+	suggestion.RespondedAt = &now
+	if err := s.repo.Update(ctx, suggestion); err != nil {
+		return nil, err
+	}
+	return suggestion, nil
+}
+
+// Stats reports how agents received the copilot's suggestions in a period, by type
+func (s *CopilotService) Stats(ctx context.Context, tenantID string, startDate, endDate time.Time) ([]*entity.CopilotStats, error) {
+	stats, err := s.repo.Stats(ctx, tenantID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range stats {
+		if acted := st.Accepted + st.Edited + st.Rejected; acted > 0 {
+			st.AcceptanceRate = float64(st.Accepted+st.Edited) / float64(acted)
+		}
+	}
+	return stats, nil
+}
+
+// suggest builds, stores and delivers the suggestions for a customer message, or for
+// the latest one when message is nil
+func (s *CopilotService) suggest(ctx context.Context, tenant *entity.Tenant, conversation *entity.Conversation, agentID string, message *entity.Message) ([]*entity.CopilotSuggestion, error) {
+	ctx = WithAIUsage(ctx, tenant.ID, "", entity.AIFeatureCopilot)
+	messages, _, err := s.messageRepo.FindByConversation(ctx, conversation.ID, &repository.ListParams{
+		Page:     1,
+		PageSize: copilotContextMessages,
+		SortBy:   "created_at",
+		SortDir:  "desc",
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(messages, func(i, j int) bool { return messages[i].CreatedAt.Before(messages[j].CreatedAt) })
+
+	if message == nil {
+		for i := len(messages) - 1; i >= 0; i-- {
+			if messages[i].SenderType == entity.SenderTypeContact && strings.TrimSpace(messages[i].Content) != "" {
+				message = messages[i]
+				break
+			}
+		}
+		if message == nil {
+			return nil, errors.Validation("the customer has not written anything to suggest on")
+		}
+	}
+
+	now := s.now()
+	newSuggestion := func(suggestionType entity.CopilotSuggestionType, content string) *entity.CopilotSuggestion {
+		return &entity.CopilotSuggestion{
+			ID:             uuid.New().String(),
+			TenantID:       conversation.TenantID,
+			ConversationID: conversation.ID,
+			MessageID:      message.ID,
+			AgentID:        agentID,
+			Type:           suggestionType,
+			Content:        content,
+			Status:         entity.CopilotSuggestionPending,
+			CreatedAt:      now,
+		}
+	}
+
+	var suggestions []*entity.CopilotSuggestion
+	reply, summary, err := s.generate(ctx, messages)
+	if err != nil {
+		// Articles still help when the AI is unavailable
+		logger.Warn("Failed to generate copilot reply",
+			zap.String("conversation_id", conversation.ID),
+			zap.Error(err),
+		)
+	}
+	if reply != "" {
+		suggestions = append(suggestions, newSuggestion(entity.CopilotSuggestionReply, reply))
+	}
+	for _, result := range s.searchArticles(ctx, tenant, message.Content) {
+		article := newSuggestion(entity.CopilotSuggestionArticle, result.Item.Answer)
+		article.Title = result.Item.Question
+		article.KnowledgeBaseID = result.Item.KnowledgeBaseID
+		article.KnowledgeItemID = result.Item.ID
+		article.Score = result.Score
+		suggestions = append(suggestions, article)
+	}
+	if summary != "" {
+		suggestions = append(suggestions, newSuggestion(entity.CopilotSuggestionSummary, summary))
+	}
+	if len(suggestions) == 0 {
+		return []*entity.CopilotSuggestion{}, nil
+	}
+
+	if err := s.deliver(ctx, agentID, suggestions); err != nil {
+		return nil, err
+	}
+	return suggestions, nil
+}
+
+// deliver stores suggestions and pushes them to the agent
+func (s *CopilotService) deliver(ctx context.Context, agentID string, suggestions []*entity.CopilotSuggestion) error {
+	for _, suggestion := range suggestions {
+		if err := s.repo.Create(ctx, suggestion); err != nil {
+			return err
+		}
+	}
+	if s.notifier != nil {
+		s.notifier.NotifySuggestions(agentID, suggestions)
+	}
+	return nil
+}
+
+// generate asks the AI for a reply the agent could send and a summary of the conversation
+func (s *CopilotService) generate(ctx context.Context, messages []*entity.Message) (string, string, error) {
+	provider, err := s.aiFactory.FirstAvailable()
+	if err != nil {
+		return "", "", err
+	}
+
+	var transcript strings.Builder
+	for _, msg := range messages {
+		if strings.TrimSpace(msg.Content) == "" {
+			continue
+		}
+		role := "Customer"
+		switch msg.SenderType {
+		case entity.SenderTypeUser:
+			role = "Agent"
+		case entity.SenderTypeBot:
+			role = "Bot"
+		case entity.SenderTypeSystem:
+			continue
+		}
+		fmt.Fprintf(&transcript, "%s: %s\n", role, truncateText(msg.Content, copilotMessageMaxSize))
+	}
+
+	resp, err := provider.Complete(ctx, &CompletionRequest{
+		Messages: []Message{
+			{Role: "system", Content: "You are a copilot for customer service agents and reply only with JSON."},
+			{Role: "user", Content: fmt.Sprintf(copilotPrompt, transcript.String())},
+		},
+		Model:       provider.DefaultModel(),
+		MaxTokens:   600,
+		Temperature: 0.3,
+	})
+	if err != nil {
+		return "", "", errors.Wrap(err, errors.ErrCodeInternal, "failed to generate copilot suggestions")
+	}
+
+	content := resp.Content
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return "", "", errors.New(errors.ErrCodeInternal, "AI copilot returned no JSON object")
+	}
+	var parsed struct {
+		Reply   string `json:"reply"`
+		Summary string `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &parsed); err != nil {
+		return "", "", errors.Wrap(err, errors.ErrCodeInternal, "failed to parse AI copilot reply")
+	}
+	return strings.TrimSpace(parsed.Reply), strings.TrimSpace(parsed.Summary), nil
+}
+
+// searchArticles finds the knowledge items most relevant to a customer message in the
+// knowledge bases the copilot may use. Knowledge bases that fail to search are skipped.
+func (s *CopilotService) searchArticles(ctx context.Context, tenant *entity.Tenant, query string) []entity.SearchResult {
+	if s.knowledgeService == nil || strings.TrimSpace(query) == "" {
+		return nil
+	}
+
+	var kbIDs []string
+	if ids := tenant.AgentCopilotKnowledgeBases(); len(ids) > 0 {
+		for _, id := range ids {
+			if kb, err := s.knowledgeService.KnowledgeBaseForTenant(ctx, tenant.ID, id); err == nil {
+				kbIDs = append(kbIDs, kb.ID)
+			}
+		}
+	} else {
+		kbs, _, err := s.knowledgeService.ListKnowledgeBases(ctx, tenant.ID, &repository.ListParams{Page: 1, PageSize: copilotKnowledgeBaseLimit})
+		if err != nil {
+			return nil
+		}
+		for _, kb := range kbs {
+			if kb.IsActive() {
+				kbIDs = append(kbIDs, kb.ID)
+			}
+		}
+	}
+
+	var results []entity.SearchResult
+	for _, kbID := range kbIDs {
+		found, err := s.knowledgeService.Search(ctx, tenant.ID, kbID, query, copilotArticleLimit)
+		if err != nil {
+			continue
+		}
+		for _, result := range found {
+			if result.Item != nil {
+				results = append(results, result)
+			}
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > copilotArticleLimit {
+		results = results[:copilotArticleLimit]
+	}
+	return results
+}
+
+func (s *CopilotService) findConversation(ctx context.Context, tenantID, conversationID string) (*entity.Conversation, error) {
+	conversation, err := s.conversationRepo.FindByID(ctx, conversationID)
+	if err != nil || conversation.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+	}
+	return conversation, nil
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Similar reply limits
+const (
+	similarReplyCandidates     = 500 // Latest resolved summaries compared
+	similarReplyLimit          = 3
+	similarReplyMinScore       = 0.8
+	similarReplyQueryMaxSize   = 2000
+	similarReplySourceMessages = 50 // Earliest messages searched for the first agent reply
+)
+
+// SetSimilarReplySources enables first-reply suggestions from similar resolved
+// conversations, found by the embeddings of their summaries
+func (s *CopilotService) SetSimilarReplySources(summaries repository.ConversationSummaryRepository, embeddings *EmbeddingService) {
+	s.summaries = summaries
+	s.embeddings = embeddings
+}
+
+// HandleAssigned suggests first replies in the background when a conversation is
+// assigned to an agent: what agents first answered in the resolved conversations
+// most similar to it
+func (s *CopilotService) HandleAssigned(ctx context.Context, conversation *entity.Conversation) {
+	if conversation.AssignedUserID == nil || s.summaries == nil || s.embeddings == nil {
+		return
+	}
+	agentID := *conversation.AssignedUserID
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), copilotTimeout)
+		defer cancel()
+
+		tenant, err := s.tenantRepo.FindByID(ctx, conversation.TenantID)
+		if err != nil || !tenant.AgentCopilotEnabled() {
+			return
+		}
+		if _, err := s.suggestSimilarReplies(ctx, conversation, agentID); err != nil {
+			logger.Warn("Failed to suggest replies from similar conversations",
+				zap.String("conversation_id", conversation.ID),
+				zap.Error(err),
+			)
+		}
+	}()
+}
+
+// similarConversation is a resolved conversation similar to the one being answered
+type similarConversation struct {
+	summary *entity.ConversationSummary
+	score   float64
+}
+
+// suggestSimilarReplies builds, stores and delivers the first replies of the resolved
+// conversations most similar to what the customer wrote so far. Conversations an
+// agent already answered get none.
+func (s *CopilotService) suggestSimilarReplies(ctx context.Context, conversation *entity.Conversation, agentID string) ([]*entity.CopilotSuggestion, error) {
+	messages, _, err := s.messageRepo.FindByConversation(ctx, conversation.ID, &repository.ListParams{
+		Page:     1,
+		PageSize: copilotContextMessages,
+		SortBy:   "created_at",
+		SortDir:  "desc",
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(messages, func(i, j int) bool { return messages[i].CreatedAt.Before(messages[j].CreatedAt) })
+
+	var query strings.Builder
+	var latest *entity.Message
+	for _, msg := range messages {
+		switch msg.SenderType {
+		case entity.SenderTypeUser:
+			return []*entity.CopilotSuggestion{}, nil
+		case entity.SenderTypeContact:
+			if text := strings.TrimSpace(msg.Content); text != "" {
+				query.WriteString(text + "\n")
+				latest = msg
+			}
+		}
+	}
+	if latest == nil {
+		return []*entity.CopilotSuggestion{}, nil
+	}
+
+	if !s.embeddings.IsAvailable() {
+		return nil, errors.New(errors.ErrCodeBadRequest, "no embedding provider available for similar replies")
+	}
+	ctx = WithAIUsage(ctx, conversation.TenantID, "", entity.AIFeatureCopilot)
+	embedding, err := s.embeddings.GenerateEmbedding(ctx, truncateText(query.String(), similarReplyQueryMaxSize))
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to embed the customer's messages")
+	}
+	summaries, err := s.summaries.ListResolvedWithEmbedding(ctx, conversation.TenantID, similarReplyCandidates)
+	if err != nil {
+		return nil, err
+	}
+
+	similar := rankSimilarConversations(conversation.ID, embedding, summaries)
+	now := s.now()
+	suggestions := []*entity.CopilotSuggestion{}
+	seen := make(map[string]bool)
+	for _, match := range similar {
+		if len(suggestions) >= similarReplyLimit {
+			break
+		}
+		reply := s.firstAgentReply(ctx, match.summary.ConversationID)
+		if reply == "" || seen[strings.ToLower(reply)] {
+			continue
+		}
+		seen[strings.ToLower(reply)] = true
+		suggestions = append(suggestions, &entity.CopilotSuggestion{
+			ID:                   uuid.New().String(),
+			TenantID:             conversation.TenantID,
+			ConversationID:       conversation.ID,
+			MessageID:            latest.ID,
+			AgentID:              agentID,
+			Type:                 entity.CopilotSuggestionSimilarReply,
+			Title:                match.summary.Issue,
+			Content:              reply,
+			Score:                match.score,
+			SourceConversationID: match.summary.ConversationID,
+			Status:               entity.CopilotSuggestionPending,
+			CreatedAt:            now,
+		})
+	}
+	if len(suggestions) == 0 {
+		return suggestions, nil
+	}
+	if err := s.deliver(ctx, agentID, suggestions); err != nil {
+		return nil, err
+	}
+	return suggestions, nil
+}
+
+// rankSimilarConversations orders the summaries similar enough to an embedding, most
+// similar first. Conversations that left the customer unhappy are not worth copying.
+func rankSimilarConversations(conversationID string, embedding []float64, summaries []*entity.ConversationSummary) []similarConversation {
+	var similar []similarConversation
+	for _, summary := range summaries {
+		if summary.ConversationID == conversationID || summary.Sentiment == entity.SentimentNegative {
+			continue
+		}
+		if score := CosineSimilarity(embedding, summary.Embedding); score >= similarReplyMinScore {
+			similar = append(similar, similarConversation{summary: summary, score: score})
+		}
+	}
+	sort.SliceStable(similar, func(i, j int) bool { return similar[i].score > similar[j].score })
+	return similar
+}
+
+// firstAgentReply returns the first message an agent sent in a conversation, empty
+// when none can be found
+func (s *CopilotService) firstAgentReply(ctx context.Context, conversationID string) string {
+	messages, err := s.messageRepo.FindByConversationAfter(ctx, conversationID, time.Time{}, similarReplySourceMessages)
+	if err != nil {
+		return ""
+	}
+	for _, msg := range messages {
+		if msg.SenderType == entity.SenderTypeUser && strings.TrimSpace(msg.Content) != "" {
+			return strings.TrimSpace(msg.Content)
+		}
+	}
+	return ""
+}
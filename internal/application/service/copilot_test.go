@@ -0,0 +1,338 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockCopilotRepo struct {
+	mu          sync.Mutex
+	suggestions map[string]*entity.CopilotSuggestion
+	stats       []*entity.CopilotStats
+}
+
+func newMockCopilotRepo() *mockCopilotRepo {
+	return &mockCopilotRepo{suggestions: make(map[string]*entity.CopilotSuggestion)}
+}
+
+func (m *mockCopilotRepo) Create(ctx context.Context, suggestion *entity.CopilotSuggestion) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.suggestions[suggestion.ID] = suggestion
+	return nil
+}
+
+func (m *mockCopilotRepo) FindByID(ctx context.Context, id string) (*entity.CopilotSuggestion, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if suggestion, ok := m.suggestions[id]; ok {
+		return suggestion, nil
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "copilot suggestion not found")
+}
+
+func (m *mockCopilotRepo) ListByConversation(ctx context.Context, conversationID string, limit int) ([]*entity.CopilotSuggestion, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var suggestions []*entity.CopilotSuggestion
+	for _, suggestion := range m.suggestions {
+		if suggestion.ConversationID == conversationID {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+	return suggestions, nil
+}
+
+func (m *mockCopilotRepo) Update(ctx context.Context, suggestion *entity.CopilotSuggestion) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.suggestions[suggestion.ID] = suggestion
+	return nil
+}
+
+func (m *mockCopilotRepo) Stats(ctx context.Context, tenantID string, startDate, endDate time.Time) ([]*entity.CopilotStats, error) {
+	return m.stats, nil
+}
+
+type copilotItemRepo struct {
+	stubKnowledgeItemRepo
+	matches map[string][]*entity.KnowledgeItem // By knowledge base
+}
+
+func (r *copilotItemRepo) SearchByKeywords(ctx context.Context, kbID string, keywords []string, limit int) ([]*entity.KnowledgeItem, error) {
+	return r.matches[kbID], nil
+}
+
+type fakeCopilotNotifier struct {
+	delivered chan []*entity.CopilotSuggestion
+	userID    string
+}
+
+func (n *fakeCopilotNotifier) NotifySuggestions(userID string, suggestions []*entity.CopilotSuggestion) {
+	n.userID = userID
+	n.delivered <- suggestions
+}
+
+type copilotTestEnv struct {
+	svc           *CopilotService
+	repo          *mockCopilotRepo
+	tenant        *entity.Tenant
+	conversations *testutil.MockConversationRepository
+	messages      *testutil.MockMessageRepository
+	ai            *extractingAIProvider
+	notifier      *fakeCopilotNotifier
+}
+
+func newCopilotTestEnv() *copilotTestEnv {
+	tenant := &entity.Tenant{ID: "tenant-1", Settings: map[string]string{entity.TenantSettingAgentCopilot: "true"}}
+	tenants := testutil.NewMockTenantRepository()
+	tenants.Tenants["tenant-1"] = tenant
+
+	agentID := "agent-1"
+	conversations := testutil.NewMockConversationRepository()
+	conversations.Conversations["conv-1"] = &entity.Conversation{
+		ID:             "conv-1",
+		TenantID:       "tenant-1",
+		Status:         entity.ConversationStatusOpen,
+		AssignedUserID: &agentID,
+		Metadata:       map[string]string{"escalated_at": "2026-05-04T12:00:00Z"},
+	}
+
+	messages := testutil.NewMockMessageRepository()
+	messages.Messages["msg-1"] = &entity.Message{
+		ID: "msg-1", ConversationID: "conv-1", SenderType: entity.SenderTypeBot,
+		Content: "I could not find your order, let me get a human.", CreatedAt: time.Date(2026, 5, 4, 11, 59, 0, 0, time.UTC),
+	}
+	messages.Messages["msg-2"] = &entity.Message{
+		ID: "msg-2", ConversationID: "conv-1", SenderType: entity.SenderTypeContact,
+		Content: "Where is my refund", CreatedAt: time.Date(2026, 5, 4, 12, 1, 0, 0, time.UTC),
+	}
+
+	kb := entity.NewKnowledgeBase("tenant-1", "Billing", entity.KnowledgeTypeFAQ)
+	kb.ID = "kb-1"
+	kbRepo := &stubKnowledgeBaseRepo{kbs: map[string]*entity.KnowledgeBase{kb.ID: kb}}
+	items := &copilotItemRepo{matches: map[string][]*entity.KnowledgeItem{
+		"kb-1": {{ID: "item-1", KnowledgeBaseID: "kb-1", Question: "When are refunds paid?", Answer: "Refunds are paid within 5 business days."}},
+	}}
+
+	ai := &extractingAIProvider{mockAIProvider: mockAIProvider{name: entity.AIProviderOpenAI, available: true}}
+	ai.reply = `{"reply": "Your refund was issued and arrives within 5 business days.", "summary": "The customer asks about a refund."}`
+	factory := NewAIProviderFactory()
+	factory.Register(ai)
+
+	repo := newMockCopilotRepo()
+	notifier := &fakeCopilotNotifier{delivered: make(chan []*entity.CopilotSuggestion, 1)}
+	svc := NewCopilotService(repo, conversations, messages, tenants, NewKnowledgeService(kbRepo, items, nil, nil), factory)
+	svc.SetNotifier(notifier)
+
+	return &copilotTestEnv{
+		svc:           svc,
+		repo:          repo,
+		tenant:        tenant,
+		conversations: conversations,
+		messages:      messages,
+		ai:            ai,
+		notifier:      notifier,
+	}
+}
+
+func TestCopilotService_Suggest(t *testing.T) {
+	env := newCopilotTestEnv()
+	ctx := context.Background()
+
+	suggestions, err := env.svc.Suggest(ctx, "tenant-1", "conv-1", "agent-2")
+	require.NoError(t, err)
+	require.Len(t, suggestions, 3)
+
+	assert.Equal(t, entity.CopilotSuggestionReply, suggestions[0].Type)
+	assert.Equal(t, "Your refund was issued and arrives within 5 business days.", suggestions[0].Content)
+	assert.Equal(t, entity.CopilotSuggestionArticle, suggestions[1].Type)
+	assert.Equal(t, "When are refunds paid?", suggestions[1].Title)
+	assert.Equal(t, "item-1", suggestions[1].KnowledgeItemID)
+	assert.Equal(t, entity.CopilotSuggestionSummary, suggestions[2].Type)
+	for _, suggestion := range suggestions {
+		assert.Equal(t, "msg-2", suggestion.MessageID)
+		assert.Equal(t, "agent-2", suggestion.AgentID)
+		assert.Equal(t, entity.CopilotSuggestionPending, suggestion.Status)
+	}
+	assert.Len(t, env.repo.suggestions, 3)
+
+	assert.Contains(t, env.ai.prompt, "Bot: I could not find your order")
+	assert.Contains(t, env.ai.prompt, "Customer: Where is my refund")
+	assert.Equal(t, "agent-2", env.notifier.userID)
+	assert.Len(t, <-env.notifier.delivered, 3)
+}
+
+func TestCopilotService_SuggestWithoutAI(t *testing.T) {
+	env := newCopilotTestEnv()
+	env.ai.reply = "I cannot help with that."
+
+	suggestions, err := env.svc.Suggest(context.Background(), "tenant-1", "conv-1", "agent-1")
+	require.NoError(t, err)
+	require.Len(t, suggestions, 1, "articles are still suggested")
+	assert.Equal(t, entity.CopilotSuggestionArticle, suggestions[0].Type)
+}
+
+func TestCopilotService_SuggestChecks(t *testing.T) {
+	env := newCopilotTestEnv()
+	ctx := context.Background()
+
+	_, err := env.svc.Suggest(ctx, "tenant-2", "conv-1", "agent-1")
+	assert.Error(t, err)
+
+	env.tenant.Settings[entity.TenantSettingAgentCopilot] = "false"
+	_, err = env.svc.Suggest(ctx, "tenant-1", "conv-1", "agent-1")
+	assert.Error(t, err)
+	assert.Empty(t, env.repo.suggestions)
+}
+
+func TestCopilotService_HandleInbound(t *testing.T) {
+	env := newCopilotTestEnv()
+	ctx := context.Background()
+	conversation := env.conversations.Conversations["conv-1"]
+	message := env.messages.Messages["msg-2"]
+
+	// Agent messages and conversations that were never escalated get no suggestions
+	require.NoError(t, env.svc.HandleInbound(ctx, &entity.Message{SenderType: entity.SenderTypeUser}, conversation))
+	require.NoError(t, env.svc.HandleInbound(ctx, message, &entity.Conversation{ID: "conv-2", TenantID: "tenant-1", AssignedUserID: conversation.AssignedUserID}))
+
+	require.NoError(t, env.svc.HandleInbound(ctx, message, conversation))
+	select {
+	case suggestions := <-env.notifier.delivered:
+		assert.Len(t, suggestions, 3)
+		assert.Equal(t, "agent-1", env.notifier.userID)
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected suggestions for the assigned agent")
+	}
+	assert.Empty(t, env.notifier.delivered)
+}
+
+func TestCopilotService_Respond(t *testing.T) {
+	env := newCopilotTestEnv()
+	ctx := context.Background()
+	env.repo.suggestions["sug-1"] = &entity.CopilotSuggestion{ID: "sug-1", TenantID: "tenant-1", Type: entity.CopilotSuggestionReply, Status: entity.CopilotSuggestionPending}
+	env.repo.suggestions["sug-2"] = &entity.CopilotSuggestion{ID: "sug-2", TenantID: "tenant-1", Type: entity.CopilotSuggestionReply, Status: entity.CopilotSuggestionPending}
+
+	_, err := env.svc.Respond(ctx, "tenant-1", "sug-1", "agent-1", &CopilotResponseInput{Status: entity.CopilotSuggestionEdited})
+	assert.Error(t, err, "edited suggestions need the edited text")
+	_, err = env.svc.Respond(ctx, "tenant-1", "sug-1", "agent-1", &CopilotResponseInput{Status: entity.CopilotSuggestionPending})
+	assert.Error(t, err)
+	_, err = env.svc.Respond(ctx, "tenant-2", "sug-1", "agent-1", &CopilotResponseInput{Status: entity.CopilotSuggestionAccepted})
+	assert.True(t, errors.IsNotFound(err))
+
+	suggestion, err := env.svc.Respond(ctx, "tenant-1", "sug-1", "agent-1", &CopilotResponseInput{Status: entity.CopilotSuggestionEdited, Content: " Your refund arrives Friday. "})
+	require.NoError(t, err)
+	assert.Equal(t, entity.CopilotSuggestionEdited, suggestion.Status)
+	assert.Equal(t, "Your refund arrives Friday.", suggestion.FinalContent)
+	assert.Equal(t, "agent-1", suggestion.RespondedBy)
+	require.NotNil(t, suggestion.RespondedAt)
+
+	_, err = env.svc.Respond(ctx, "tenant-1", "sug-1", "agent-1", &CopilotResponseInput{Status: entity.CopilotSuggestionRejected})
+	assert.Error(t, err, "suggestions are responded to once")
+
+	suggestion, err = env.svc.Respond(ctx, "tenant-1", "sug-2", "agent-1", &CopilotResponseInput{Status: entity.CopilotSuggestionAccepted, Content: "ignored"})
+	require.NoError(t, err)
+	assert.Empty(t, suggestion.FinalContent)
+}
+
+func TestCopilotService_Stats(t *testing.T) {
+	env := newCopilotTestEnv()
+	env.repo.stats = []*entity.CopilotStats{
+		{Type: entity.CopilotSuggestionReply, Suggestions: 10, Accepted: 4, Edited: 2, Rejected: 2, Pending: 2},
+		{Type: entity.CopilotSuggestionSummary, Suggestions: 3, Pending: 3},
+	}
+
+	stats, err := env.svc.Stats(context.Background(), "tenant-1", time.Now().AddDate(0, 0, -7), time.Now())
+	require.NoError(t, err)
+	assert.InDelta(t, 0.75, stats[0].AcceptanceRate, 0.001)
+	assert.Zero(t, stats[1].AcceptanceRate)
+}
+
+// keywordEmbeddingProvider embeds texts by which of its keywords they mention
+type keywordEmbeddingProvider struct {
+	mockAIProvider
+	keywords []string
+}
+
+func (p *keywordEmbeddingProvider) Embed(ctx context.Context, req *EmbeddingRequest) (*EmbeddingResponse, error) {
+	embedding := make([]float64, len(p.keywords))
+	for i, keyword := range p.keywords {
+		if strings.Contains(strings.ToLower(req.Text), keyword) {
+			embedding[i] = 1
+		}
+	}
+	return &EmbeddingResponse{Embedding: embedding}, nil
+}
+
+func TestCopilotService_SimilarReplies(t *testing.T) {
+	env := newCopilotTestEnv()
+	ctx := context.Background()
+	refund, shipping := []float64{1, 0}, []float64{0, 1}
+
+	summaries := &mockConversationSummaryRepo{summaries: make(map[string]*entity.ConversationSummary)}
+	resolved := func(id, issue string, sentiment entity.Sentiment, embedding []float64, reply string) {
+		summaries.summaries[id] = &entity.ConversationSummary{ConversationID: id, TenantID: "tenant-1", Issue: issue, Sentiment: sentiment, Embedding: embedding}
+		if reply != "" {
+			env.messages.Messages[id+"-reply"] = &entity.Message{
+				ID: id + "-reply", ConversationID: id, SenderType: entity.SenderTypeUser,
+				Content: reply, CreatedAt: time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC),
+			}
+		}
+	}
+	resolved("conv-10", "Refund not received", entity.SentimentPositive, refund, "Refunds take 5 business days, I have checked yours.")
+	resolved("conv-11", "Refund not received", entity.SentimentNegative, refund, "Please wait.")
+	resolved("conv-12", "Parcel is late", entity.SentimentNeutral, shipping, "Your parcel ships today.")
+	resolved("conv-13", "Refund missing", entity.SentimentNeutral, refund, "refunds take 5 business days, I have checked yours.")
+	resolved("conv-14", "Refund missing", entity.SentimentNeutral, refund, "")
+	resolved("conv-15", "Refund to another card", entity.SentimentPositive, refund, "Refunds go back to the card you paid with.")
+
+	factory := NewAIProviderFactory()
+	factory.Register(&keywordEmbeddingProvider{
+		mockAIProvider: mockAIProvider{name: entity.AIProviderOllama, available: true},
+		keywords:       []string{"refund", "parcel"},
+	})
+	env.svc.SetSimilarReplySources(summaries, NewEmbeddingService(factory, &EmbeddingConfig{DefaultProvider: entity.AIProviderOllama}))
+
+	conversation := env.conversations.Conversations["conv-1"]
+	env.svc.HandleAssigned(ctx, conversation)
+
+	var suggestions []*entity.CopilotSuggestion
+	select {
+	case suggestions = <-env.notifier.delivered:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected similar replies for the assigned agent")
+	}
+	assert.Equal(t, "agent-1", env.notifier.userID)
+
+	// Unhappy, unanswered and repeated replies are left out
+	require.Len(t, suggestions, 2)
+	var sources []string
+	for _, suggestion := range suggestions {
+		assert.Equal(t, entity.CopilotSuggestionSimilarReply, suggestion.Type)
+		assert.Equal(t, "msg-2", suggestion.MessageID)
+		assert.InDelta(t, 1, suggestion.Score, 0.001)
+		assert.NotEmpty(t, suggestion.Title)
+		sources = append(sources, suggestion.SourceConversationID)
+	}
+	assert.Subset(t, []string{"conv-10", "conv-13", "conv-15"}, sources)
+	assert.Contains(t, sources, "conv-15")
+	assert.Len(t, env.repo.suggestions, 2)
+
+	// Agents who already answered need no first reply
+	env.messages.Messages["msg-3"] = &entity.Message{
+		ID: "msg-3", ConversationID: "conv-1", SenderType: entity.SenderTypeUser,
+		Content: "Let me check", CreatedAt: time.Date(2026, 5, 4, 12, 2, 0, 0, time.UTC),
+	}
+	suggestions, err := env.svc.suggestSimilarReplies(ctx, conversation, "agent-1")
+	require.NoError(t, err)
+	assert.Empty(t, suggestions)
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/msgfy/linktor/internal/adapters/whatsapp"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// expiredMessagesBatchSize is how many expired messages one ExpireMessages run handles
+const expiredMessagesBatchSize = 200
+
+// DisappearingChats changes the disappearing timer of chats on the channel's phone
+type DisappearingChats interface {
+	SetWhatsAppDisappearingTimer(ctx context.Context, channelID, to string, timer time.Duration) error
+}
+
+// DisappearingSettings is the disappearing messages setting of a conversation
+type DisappearingSettings struct {
+	ConversationID string                       `json:"conversation_id"`
+	Timer          int                          `json:"timer"` // Seconds, zero when off
+	ChangedAt      *time.Time                   `json:"changed_at,omitempty"`
+	Retention      entity.DisappearingRetention `json:"retention"`
+}
+
+// SetDisappearingTimerInput represents the input for changing a conversation's
+// disappearing timer
+type SetDisappearingTimerInput struct {
+	Timer int `json:"timer"` // Seconds: 0 (off), 86400, 604800 or 7776000
+}
+
+// DisappearingMessageService keeps the disappearing messages setting of WhatsApp chats
+// and applies the expiration of their messages to the stored copies
+type DisappearingMessageService struct {
+	conversationRepo repository.ConversationRepository
+	contactRepo      repository.ContactRepository
+	channelRepo      repository.ChannelRepository
+	messageRepo      repository.MessageRepository
+	chats            DisappearingChats
+	now              func() time.Time
+}
+
+// NewDisappearingMessageService creates a new disappearing message service
+func NewDisappearingMessageService(
+	conversationRepo repository.ConversationRepository,
+	contactRepo repository.ContactRepository,
+	channelRepo repository.ChannelRepository,
+	messageRepo repository.MessageRepository,
+	chats DisappearingChats,
+) *DisappearingMessageService {
+	return &DisappearingMessageService{
+		conversationRepo: conversationRepo,
+		contactRepo:      contactRepo,
+		channelRepo:      channelRepo,
+		messageRepo:      messageRepo,
+		chats:            chats,
+		now:              time.Now,
+	}
+}
+
+// Get returns the disappearing messages setting of a conversation
+func (s *DisappearingMessageService) Get(ctx context.Context, tenantID, conversationID string) (*DisappearingSettings, error) {
+	conversation, channel, err := s.load(ctx, tenantID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	return disappearingSettings(conversation, channel), nil
+}
+
+// SetTimer turns disappearing messages on or off in the conversation's chat, on the
+// phone too. Only WhatsApp chats linked by QR code have disappearing messages.
+func (s *DisappearingMessageService) SetTimer(ctx context.Context, tenantID, conversationID string, input *SetDisappearingTimerInput) (*DisappearingSettings, error) {
+	timer := time.Duration(input.Timer) * time.Second
+	if !whatsapp.ValidDisappearingTimer(timer) {
+		return nil, errors.Validation("timer must be 0, 86400 (24 hours), 604800 (7 days) or 7776000 (90 days) seconds")
+	}
+
+	conversation, channel, err := s.load(ctx, tenantID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if channel.Type != entity.ChannelTypeWhatsApp && channel.Type != entity.ChannelTypeWhatsAppUnofficial {
+		return nil, errors.Validation("disappearing messages are only supported on WhatsApp channels")
+	}
+
+	contact, err := s.contactRepo.FindByID(ctx, conversation.ContactID)
+	if err != nil {
+		return nil, errors.New(errors.ErrCodeContactNotFound, "contact not found")
+	}
+	if identities, err := s.contactRepo.FindIdentitiesByContact(ctx, contact.ID); err == nil {
+		contact.Identities = identities
+	}
+	recipient := findRecipientForChannel(contact, string(channel.Type))
+	if recipient == "" {
+		return nil, errors.Validation("contact has no WhatsApp number")
+	}
+
+	if err := s.chats.SetWhatsAppDisappearingTimer(ctx, channel.ID, recipient, timer); err != nil {
+		return nil, err
+	}
+
+	if conversation.SetDisappearingTimer(timer, s.now()) {
+		if err := s.conversationRepo.SetMetadata(ctx, conversation.ID, conversation.DisappearingTimerMetadata()); err != nil {
+			return nil, err
+		}
+	}
+	return disappearingSettings(conversation, channel), nil
+}
+
+// ApplyChatTimer records a disappearing timer change made on the phone or by the
+// contact in the conversation of a channel's chat. Chats without an open conversation
+// pick the timer up from their next message.
+func (s *DisappearingMessageService) ApplyChatTimer(ctx context.Context, channel *entity.Channel, chat string, timer time.Duration, at time.Time) error {
+	contact, err := s.contactRepo.FindByIdentity(ctx, channel.TenantID, string(channel.Type), chat)
+	if err != nil {
+		return nil
+	}
+	conversation, err := s.conversationRepo.FindOpenByContactAndChannel(ctx, contact.ID, channel.ID)
+	if err != nil || conversation == nil {
+		return nil
+	}
+	if at.IsZero() {
+		at = s.now()
+	}
+	if !conversation.SetDisappearingTimer(timer, at) {
+		return nil
+	}
+	return s.conversationRepo.SetMetadata(ctx, conversation.ID, conversation.DisappearingTimerMetadata())
+}
+
+// ExpireMessages applies the retention of their channel to the stored messages that
+// disappeared from the chat. It returns how many messages it handled.
+func (s *DisappearingMessageService) ExpireMessages(ctx context.Context) (int, error) {
+	messages, err := s.messageRepo.FindExpiredEphemeral(ctx, s.now(), expiredMessagesBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	retentions := make(map[string]entity.DisappearingRetention)
+	expired := 0
+	for _, message := range messages {
+		retention, err := s.retentionOf(ctx, message.ConversationID, retentions)
+		if err != nil {
+			logger.Warn("Failed to find retention of expired message",
+				zap.String("message_id", message.ID),
+				zap.Error(err))
+			continue
+		}
+
+		switch retention {
+		case entity.DisappearingRetentionDelete:
+			err = s.messageRepo.Delete(ctx, message.ID)
+		case entity.DisappearingRetentionKeep:
+			message.Metadata[entity.MetadataEphemeralExpired] = "true"
+			err = s.messageRepo.Update(ctx, message)
+		default:
+			message.Metadata[entity.MetadataEphemeralExpired] = "true"
+			err = s.messageRepo.Redact(ctx, message.ID, message.Metadata)
+		}
+		if err != nil {
+			logger.Warn("Failed to expire disappearing message",
+				zap.String("message_id", message.ID),
+				zap.String("retention", string(retention)),
+				zap.Error(err))
+			continue
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// retentionOf returns the retention of a conversation's channel, caching it per conversation
+func (s *DisappearingMessageService) retentionOf(ctx context.Context, conversationID string, cache map[string]entity.DisappearingRetention) (entity.DisappearingRetention, error) {
+	if retention, ok := cache[conversationID]; ok {
+		return retention, nil
+	}
+	conversation, err := s.conversationRepo.FindByID(ctx, conversationID)
+	if err != nil {
+		return "", err
+	}
+	channel, err := s.channelRepo.FindByID(ctx, conversation.ChannelID)
+	if err != nil {
+		return "", err
+	}
+	cache[conversationID] = channel.DisappearingRetention()
+	return cache[conversationID], nil
+}
+
+// load finds a tenant's conversation and its channel
+func (s *DisappearingMessageService) load(ctx context.Context, tenantID, conversationID string) (*entity.Conversation, *entity.Channel, error) {
+	conversation, err := s.conversationRepo.FindByID(ctx, conversationID)
+	if err != nil || conversation.TenantID != tenantID {
+		return nil, nil, errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+	}
+	channel, err := s.channelRepo.FindByID(ctx, conversation.ChannelID)
+	if err != nil {
+		return nil, nil, errors.New(errors.ErrCodeChannelNotFound, "channel not found")
+	}
+	return conversation, channel, nil
+}
+
+func disappearingSettings(conversation *entity.Conversation, channel *entity.Channel) *DisappearingSettings {
+	settings := &DisappearingSettings{
+		ConversationID: conversation.ID,
+		Timer:          int(conversation.DisappearingTimer().Seconds()),
+		Retention:      channel.DisappearingRetention(),
+	}
+	if changedAt, err := time.Parse(time.RFC3339, conversation.Metadata[entity.MetadataDisappearingTimerAt]); err == nil {
+		settings.ChangedAt = &changedAt
+	}
+	return settings
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDisappearingChats struct {
+	to    string
+	timer time.Duration
+}
+
+func (f *fakeDisappearingChats) SetWhatsAppDisappearingTimer(ctx context.Context, channelID, to string, timer time.Duration) error {
+	f.to, f.timer = to, timer
+	return nil
+}
+
+type disappearingFixture struct {
+	svc           *DisappearingMessageService
+	conversations *testutil.MockConversationRepository
+	messages      *testutil.MockMessageRepository
+	chats         *fakeDisappearingChats
+	now           time.Time
+}
+
+func newDisappearingFixture() *disappearingFixture {
+	f := &disappearingFixture{
+		conversations: testutil.NewMockConversationRepository(),
+		messages:      testutil.NewMockMessageRepository(),
+		chats:         &fakeDisappearingChats{},
+		now:           time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC),
+	}
+	contacts := testutil.NewMockContactRepository()
+	identities := []*entity.ContactIdentity{
+		{ContactID: "contact-1", ChannelType: string(entity.ChannelTypeWhatsApp), Identifier: "5511999999999"},
+	}
+	contacts.Contacts["contact-1"] = &entity.Contact{ID: "contact-1", TenantID: "tenant-1", Identities: identities}
+	contacts.Identities["contact-1"] = identities
+	channels := testutil.NewMockChannelRepository()
+	channels.Channels["channel-1"] = &entity.Channel{ID: "channel-1", TenantID: "tenant-1", Type: entity.ChannelTypeWhatsApp}
+	channels.Channels["channel-2"] = &entity.Channel{ID: "channel-2", TenantID: "tenant-1", Type: entity.ChannelTypeWhatsApp,
+		Config: map[string]string{"disappearing_retention": "delete"}}
+	channels.Channels["channel-3"] = &entity.Channel{ID: "channel-3", TenantID: "tenant-1", Type: entity.ChannelTypeWebChat}
+	f.conversations.Conversations["conv-1"] = &entity.Conversation{ID: "conv-1", TenantID: "tenant-1", ChannelID: "channel-1", ContactID: "contact-1", Status: entity.ConversationStatusOpen}
+	f.conversations.Conversations["conv-2"] = &entity.Conversation{ID: "conv-2", TenantID: "tenant-1", ChannelID: "channel-2", ContactID: "contact-1", Status: entity.ConversationStatusOpen}
+	f.conversations.Conversations["conv-3"] = &entity.Conversation{ID: "conv-3", TenantID: "tenant-1", ChannelID: "channel-3", ContactID: "contact-1", Status: entity.ConversationStatusOpen}
+
+	f.svc = NewDisappearingMessageService(f.conversations, contacts, channels, f.messages, f.chats)
+	f.svc.now = func() time.Time { return f.now }
+	return f
+}
+
+func TestDisappearingMessageService_SetTimer(t *testing.T) {
+	f := newDisappearingFixture()
+	ctx := context.Background()
+
+	settings, err := f.svc.SetTimer(ctx, "tenant-1", "conv-1", &SetDisappearingTimerInput{Timer: 604800})
+	require.NoError(t, err)
+	assert.Equal(t, 604800, settings.Timer)
+	assert.Equal(t, entity.DisappearingRetentionRedact, settings.Retention)
+	require.NotNil(t, settings.ChangedAt)
+	assert.Equal(t, "5511999999999", f.chats.to)
+	assert.Equal(t, 7*24*time.Hour, f.chats.timer)
+	assert.Equal(t, 7*24*time.Hour, f.conversations.Conversations["conv-1"].DisappearingTimer())
+
+	_, err = f.svc.SetTimer(ctx, "tenant-1", "conv-1", &SetDisappearingTimerInput{Timer: 3600})
+	assert.True(t, errors.IsValidation(err), "WhatsApp only honours its own timers")
+
+	_, err = f.svc.SetTimer(ctx, "tenant-1", "conv-3", &SetDisappearingTimerInput{Timer: 86400})
+	assert.True(t, errors.IsValidation(err))
+
+	_, err = f.svc.Get(ctx, "tenant-2", "conv-1")
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestDisappearingMessageService_ApplyChatTimer(t *testing.T) {
+	f := newDisappearingFixture()
+	ctx := context.Background()
+	channel := &entity.Channel{ID: "channel-1", TenantID: "tenant-1", Type: entity.ChannelTypeWhatsApp}
+
+	require.NoError(t, f.svc.ApplyChatTimer(ctx, channel, "5511999999999", 24*time.Hour, f.now))
+	settings, err := f.svc.Get(ctx, "tenant-1", "conv-1")
+	require.NoError(t, err)
+	assert.Equal(t, 86400, settings.Timer)
+
+	require.NoError(t, f.svc.ApplyChatTimer(ctx, channel, "5511999999999", 0, f.now))
+	assert.Zero(t, f.conversations.Conversations["conv-1"].DisappearingTimer())
+
+	assert.NoError(t, f.svc.ApplyChatTimer(ctx, channel, "5511000000000", 24*time.Hour, f.now), "unknown chats are skipped")
+}
+
+func TestDisappearingMessageService_ExpireMessages(t *testing.T) {
+	f := newDisappearingFixture()
+	ctx := context.Background()
+
+	sentAt := f.now.Add(-25 * time.Hour)
+	redacted := &entity.Message{ID: "msg-1", ConversationID: "conv-1", Content: "see you at 5", Metadata: map[string]string{}}
+	redacted.SetEphemeralExpiry(24*time.Hour, sentAt)
+	deleted := &entity.Message{ID: "msg-2", ConversationID: "conv-2", Content: "my address", Metadata: map[string]string{}}
+	deleted.SetEphemeralExpiry(24*time.Hour, sentAt)
+	pending := &entity.Message{ID: "msg-3", ConversationID: "conv-1", Content: "still here", Metadata: map[string]string{}}
+	pending.SetEphemeralExpiry(7*24*time.Hour, sentAt)
+	f.messages.Messages = map[string]*entity.Message{"msg-1": redacted, "msg-2": deleted, "msg-3": pending}
+	f.messages.Attachments["msg-1"] = []*entity.MessageAttachment{{ID: "att-1", MessageID: "msg-1"}}
+
+	expired, err := f.svc.ExpireMessages(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, expired)
+
+	assert.Empty(t, redacted.Content)
+	assert.Equal(t, "true", redacted.Metadata[entity.MetadataEphemeralExpired])
+	assert.Empty(t, f.messages.Attachments["msg-1"])
+	assert.NotContains(t, f.messages.Messages, "msg-2")
+	assert.Equal(t, "still here", pending.Content)
+
+	expired, err = f.svc.ExpireMessages(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, expired, "expired messages are handled once")
+}
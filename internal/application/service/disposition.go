@@ -0,0 +1,273 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// DispositionInput represents input for creating or updating a disposition
+type DispositionInput struct {
+	Code        string `json:"code" binding:"required"`
+	Name        string `json:"name" binding:"required"`
+	Category    string `json:"category,omitempty"`
+	Description string `json:"description,omitempty"`
+	Active      *bool  `json:"active,omitempty"` // Defaults to true
+	Position    int    `json:"position,omitempty"`
+}
+
+// DispositionService manages the disposition taxonomy of tenants and the dispositions
+// agents give conversations when resolving them
+type DispositionService struct {
+	repo             repository.DispositionRepository
+	tenantRepo       repository.TenantRepository
+	conversationRepo repository.ConversationRepository
+	now              func() time.Time
+}
+
+// NewDispositionService creates a new disposition service
+func NewDispositionService(
+	repo repository.DispositionRepository,
+	tenantRepo repository.TenantRepository,
+	conversationRepo repository.ConversationRepository,
+) *DispositionService {
+	return &DispositionService{
+		repo:             repo,
+		tenantRepo:       tenantRepo,
+		conversationRepo: conversationRepo,
+		now:              time.Now,
+	}
+}
+
+// Create creates a new disposition
+func (s *DispositionService) Create(ctx context.Context, tenantID string, input *DispositionInput) (*entity.Disposition, error) {
+	code, err := s.validate(ctx, tenantID, "", input)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	disposition := &entity.Disposition{
+		ID:          uuid.New().String(),
+		TenantID:    tenantID,
+		Code:        code,
+		Name:        strings.TrimSpace(input.Name),
+		Category:    strings.TrimSpace(input.Category),
+		Description: input.Description,
+		Active:      input.Active == nil || *input.Active,
+		Position:    input.Position,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.repo.Create(ctx, disposition); err != nil {
+		return nil, err
+	}
+	return disposition, nil
+}
+
+// Get returns a disposition of the tenant
+func (s *DispositionService) Get(ctx context.Context, tenantID, id string) (*entity.Disposition, error) {
+	disposition, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if disposition.TenantID != tenantID {
+		return nil, errors.NotFound("disposition")
+	}
+	return disposition, nil
+}
+
+// List lists the dispositions of a tenant, optionally only the active ones
+func (s *DispositionService) List(ctx context.Context, tenantID string, activeOnly bool) ([]*entity.Disposition, error) {
+	dispositions, err := s.repo.FindByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*entity.Disposition, 0, len(dispositions))
+	for _, disposition := range dispositions {
+		if activeOnly && !disposition.Active {
+			continue
+		}
+		result = append(result, disposition)
+	}
+	return result, nil
+}
+
+// Update updates a disposition. Conversations already resolved keep the code and
+// name they were given.
+func (s *DispositionService) Update(ctx context.Context, tenantID, id string, input *DispositionInput) (*entity.Disposition, error) {
+	disposition, err := s.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	code, err := s.validate(ctx, tenantID, id, input)
+	if err != nil {
+		return nil, err
+	}
+
+	disposition.Code = code
+	disposition.Name = strings.TrimSpace(input.Name)
+	disposition.Category = strings.TrimSpace(input.Category)
+	disposition.Description = input.Description
+	if input.Active != nil {
+		disposition.Active = *input.Active
+	}
+	disposition.Position = input.Position
+	disposition.UpdatedAt = s.now()
+	if err := s.repo.Update(ctx, disposition); err != nil {
+		return nil, err
+	}
+	return disposition, nil
+}
+
+// Delete deletes a disposition no conversation was resolved with. Used dispositions
+// must be deactivated instead, so they stay in reports.
+func (s *DispositionService) Delete(ctx context.Context, tenantID, id string) error {
+	if _, err := s.Get(ctx, tenantID, id); err != nil {
+		return err
+	}
+	used, err := s.repo.IsUsed(ctx, id)
+	if err != nil {
+		return err
+	}
+	if used {
+		return errors.Conflict("disposition is used by conversations, deactivate it instead")
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// GetForConversation returns the disposition given to a conversation
+func (s *DispositionService) GetForConversation(ctx context.Context, tenantID, conversationID string) (*entity.ConversationDisposition, error) {
+	if _, err := s.getConversation(ctx, tenantID, conversationID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindConversationDisposition(ctx, conversationID)
+}
+
+// SetForConversation sets or corrects the disposition of a resolved conversation
+func (s *DispositionService) SetForConversation(ctx context.Context, tenantID, conversationID string, input *ResolveConversationInput) (*entity.ConversationDisposition, error) {
+	conversation, err := s.getConversation(ctx, tenantID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.Status != entity.ConversationStatusResolved {
+		return nil, errors.Validation("only resolved conversations can be given a disposition")
+	}
+
+	record, err := s.Prepare(ctx, conversation, input)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, errors.Validation("disposition_code or notes is required")
+	}
+	if err := s.repo.SaveConversationDisposition(ctx, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// Prepare validates the disposition given when resolving a conversation and returns
+// the record to save once it is resolved, or nil when none was given and the tenant
+// does not require one
+func (s *DispositionService) Prepare(ctx context.Context, conversation *entity.Conversation, input *ResolveConversationInput) (*entity.ConversationDisposition, error) {
+	if input == nil {
+		input = &ResolveConversationInput{}
+	}
+	code := entity.NormalizeDispositionCode(input.DispositionCode)
+	notes := strings.TrimSpace(input.Notes)
+	if utf8.RuneCountInString(notes) > entity.MaxDispositionNotesLength {
+		return nil, errors.Validation("notes must be at most 2000 characters")
+	}
+
+	if code == "" {
+		tenant, err := s.tenantRepo.FindByID(ctx, conversation.TenantID)
+		if err != nil {
+			return nil, err
+		}
+		if tenant.DispositionRequired() {
+			return nil, errors.Validation("a disposition is required to resolve this conversation")
+		}
+		if notes == "" {
+			return nil, nil
+		}
+	}
+
+	now := s.now()
+	record := &entity.ConversationDisposition{
+		ConversationID: conversation.ID,
+		TenantID:       conversation.TenantID,
+		Notes:          notes,
+		ResolvedBy:     input.ResolvedBy,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if code != "" {
+		disposition, err := s.repo.FindByCode(ctx, conversation.TenantID, code)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return nil, errors.Validation("unknown disposition code")
+			}
+			return nil, err
+		}
+		if !disposition.Active {
+			return nil, errors.Validation("disposition is inactive")
+		}
+		record.DispositionID = disposition.ID
+		record.Code = disposition.Code
+		record.Name = disposition.Name
+	}
+	return record, nil
+}
+
+// Save stores the disposition prepared for a conversation that was just resolved.
+// Failures are logged, the disposition can still be set on the resolved conversation.
+func (s *DispositionService) Save(ctx context.Context, record *entity.ConversationDisposition) {
+	if err := s.repo.SaveConversationDisposition(ctx, record); err != nil {
+		logger.Warn("Failed to save conversation disposition",
+			zap.String("conversation_id", record.ConversationID),
+			zap.Error(err),
+		)
+	}
+}
+
+func (s *DispositionService) getConversation(ctx context.Context, tenantID, conversationID string) (*entity.Conversation, error) {
+	conversation, err := s.conversationRepo.FindByID(ctx, conversationID)
+	if err != nil || conversation.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+	}
+	return conversation, nil
+}
+
+// validate checks a disposition input and returns its normalized code. Codes are
+// unique per tenant; excludeID is the disposition being updated.
+func (s *DispositionService) validate(ctx context.Context, tenantID, excludeID string, input *DispositionInput) (string, error) {
+	code := entity.NormalizeDispositionCode(input.Code)
+	if code == "" {
+		return "", errors.Validation("disposition code is required")
+	}
+	if !entity.IsValidDispositionCode(code) {
+		return "", errors.Validation("disposition code must be at most 50 letters, digits, dots, dashes or underscores")
+	}
+	if strings.TrimSpace(input.Name) == "" {
+		return "", errors.Validation("disposition name is required")
+	}
+
+	existing, err := s.repo.FindByCode(ctx, tenantID, code)
+	if err != nil && !errors.IsNotFound(err) {
+		return "", err
+	}
+	if existing != nil && existing.ID != excludeID {
+		return "", errors.Conflict("a disposition with this code already exists")
+	}
+	return code, nil
+}
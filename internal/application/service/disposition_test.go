@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockDispositionRepository is an inline mock for DispositionRepository
+type mockDispositionRepository struct {
+	dispositions  map[string]*entity.Disposition
+	conversations map[string]*entity.ConversationDisposition
+}
+
+func newMockDispositionRepository() *mockDispositionRepository {
+	return &mockDispositionRepository{
+		dispositions:  make(map[string]*entity.Disposition),
+		conversations: make(map[string]*entity.ConversationDisposition),
+	}
+}
+
+func (m *mockDispositionRepository) Create(ctx context.Context, disposition *entity.Disposition) error {
+	m.dispositions[disposition.ID] = disposition
+	return nil
+}
+
+func (m *mockDispositionRepository) FindByID(ctx context.Context, id string) (*entity.Disposition, error) {
+	disposition, ok := m.dispositions[id]
+	if !ok {
+		return nil, errors.NotFound("disposition")
+	}
+	return disposition, nil
+}
+
+func (m *mockDispositionRepository) FindByCode(ctx context.Context, tenantID, code string) (*entity.Disposition, error) {
+	for _, disposition := range m.dispositions {
+		if disposition.TenantID == tenantID && disposition.Code == code {
+			return disposition, nil
+		}
+	}
+	return nil, errors.NotFound("disposition")
+}
+
+func (m *mockDispositionRepository) FindByTenant(ctx context.Context, tenantID string) ([]*entity.Disposition, error) {
+	var result []*entity.Disposition
+	for _, disposition := range m.dispositions {
+		if disposition.TenantID == tenantID {
+			result = append(result, disposition)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockDispositionRepository) Update(ctx context.Context, disposition *entity.Disposition) error {
+	m.dispositions[disposition.ID] = disposition
+	return nil
+}
+
+func (m *mockDispositionRepository) Delete(ctx context.Context, id string) error {
+	delete(m.dispositions, id)
+	return nil
+}
+
+func (m *mockDispositionRepository) IsUsed(ctx context.Context, id string) (bool, error) {
+	for _, record := range m.conversations {
+		if record.DispositionID == id {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *mockDispositionRepository) SaveConversationDisposition(ctx context.Context, disposition *entity.ConversationDisposition) error {
+	m.conversations[disposition.ConversationID] = disposition
+	return nil
+}
+
+func (m *mockDispositionRepository) FindConversationDisposition(ctx context.Context, conversationID string) (*entity.ConversationDisposition, error) {
+	record, ok := m.conversations[conversationID]
+	if !ok {
+		return nil, errors.NotFound("conversation disposition")
+	}
+	return record, nil
+}
+
+func newDispositionTestService() (*DispositionService, *ConversationService, *mockDispositionRepository, *testutil.MockTenantRepository) {
+	repo := newMockDispositionRepository()
+	tenants := testutil.NewMockTenantRepository()
+	tenants.Tenants["tenant-1"] = &entity.Tenant{ID: "tenant-1", Settings: map[string]string{}}
+	conversations := testutil.NewMockConversationRepository()
+	conversations.Conversations["conv-1"] = &entity.Conversation{ID: "conv-1", TenantID: "tenant-1", Status: entity.ConversationStatusOpen}
+	conversations.Conversations["conv-2"] = &entity.Conversation{ID: "conv-2", TenantID: "tenant-1", Status: entity.ConversationStatusOpen}
+
+	dispositions := NewDispositionService(repo, tenants, conversations)
+	conversationService := NewConversationService(conversations, testutil.NewMockContactRepository(), testutil.NewMockChannelRepository())
+	conversationService.SetDispositionService(dispositions)
+	return dispositions, conversationService, repo, tenants
+}
+
+func TestDispositionService_Create(t *testing.T) {
+	svc, _, _, _ := newDispositionTestService()
+	ctx := context.Background()
+
+	disposition, err := svc.Create(ctx, "tenant-1", &DispositionInput{Code: " Billing.Refund ", Name: "Refund", Category: "Billing"})
+	require.NoError(t, err)
+	assert.Equal(t, "billing.refund", disposition.Code)
+	assert.True(t, disposition.Active, "dispositions are active by default")
+
+	_, err = svc.Create(ctx, "tenant-1", &DispositionInput{Code: "billing.refund", Name: "Other"})
+	assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code)
+
+	_, err = svc.Create(ctx, "tenant-2", &DispositionInput{Code: "billing.refund", Name: "Refund"})
+	assert.NoError(t, err, "other tenants may reuse the code")
+
+	_, err = svc.Create(ctx, "tenant-1", &DispositionInput{Code: "billing refund", Name: "Refund"})
+	assert.True(t, errors.IsValidation(err))
+
+	_, err = svc.Create(ctx, "tenant-1", &DispositionInput{Code: "sales.won", Name: "  "})
+	assert.True(t, errors.IsValidation(err))
+}
+
+func TestDispositionService_ResolveWithDisposition(t *testing.T) {
+	svc, conversations, repo, tenants := newDispositionTestService()
+	ctx := context.Background()
+
+	inactive := false
+	_, err := svc.Create(ctx, "tenant-1", &DispositionInput{Code: "billing.refund", Name: "Refund"})
+	require.NoError(t, err)
+	_, err = svc.Create(ctx, "tenant-1", &DispositionInput{Code: "legacy", Name: "Legacy", Active: &inactive})
+	require.NoError(t, err)
+
+	tenants.Tenants["tenant-1"].Settings[entity.TenantSettingDispositionRequired] = "true"
+
+	_, err = conversations.Resolve(ctx, "tenant-1", "conv-1", &ResolveConversationInput{Notes: "refunded"})
+	assert.True(t, errors.IsValidation(err), "a disposition is required")
+
+	_, err = conversations.Resolve(ctx, "tenant-1", "conv-1", &ResolveConversationInput{DispositionCode: "legacy"})
+	assert.True(t, errors.IsValidation(err), "inactive dispositions cannot be picked")
+
+	_, err = conversations.Resolve(ctx, "tenant-1", "conv-1", &ResolveConversationInput{DispositionCode: "unknown"})
+	assert.True(t, errors.IsValidation(err))
+
+	_, err = conversations.Resolve(ctx, "tenant-1", "conv-1", &ResolveConversationInput{DispositionCode: "billing.refund", Notes: strings.Repeat("a", entity.MaxDispositionNotesLength+1)})
+	assert.True(t, errors.IsValidation(err))
+
+	resolved, err := conversations.Resolve(ctx, "tenant-1", "conv-1", &ResolveConversationInput{DispositionCode: "Billing.Refund", Notes: " refunded ", ResolvedBy: "user-1"})
+	require.NoError(t, err)
+	assert.Equal(t, entity.ConversationStatusResolved, resolved.Status)
+
+	record := repo.conversations["conv-1"]
+	require.NotNil(t, record)
+	assert.Equal(t, "billing.refund", record.Code)
+	assert.Equal(t, "Refund", record.Name)
+	assert.Equal(t, "refunded", record.Notes)
+	assert.Equal(t, "user-1", record.ResolvedBy)
+
+	tenants.Tenants["tenant-1"].Settings[entity.TenantSettingDispositionRequired] = "false"
+	_, err = conversations.Resolve(ctx, "tenant-1", "conv-2", nil)
+	require.NoError(t, err, "dispositions are optional unless the tenant requires them")
+	assert.NotContains(t, repo.conversations, "conv-2")
+}
+
+func TestDispositionService_SetForConversation(t *testing.T) {
+	svc, conversations, _, _ := newDispositionTestService()
+	ctx := context.Background()
+
+	_, err := svc.Create(ctx, "tenant-1", &DispositionInput{Code: "sales.won", Name: "Won"})
+	require.NoError(t, err)
+
+	_, err = svc.SetForConversation(ctx, "tenant-1", "conv-1", &ResolveConversationInput{DispositionCode: "sales.won"})
+	assert.True(t, errors.IsValidation(err), "open conversations cannot be given a disposition")
+
+	_, err = conversations.Resolve(ctx, "tenant-1", "conv-1", nil)
+	require.NoError(t, err)
+
+	record, err := svc.SetForConversation(ctx, "tenant-2", "conv-1", &ResolveConversationInput{DispositionCode: "sales.won"})
+	assert.Error(t, err)
+	assert.Nil(t, record)
+
+	record, err = svc.SetForConversation(ctx, "tenant-1", "conv-1", &ResolveConversationInput{DispositionCode: "sales.won"})
+	require.NoError(t, err)
+	assert.Equal(t, "sales.won", record.Code)
+
+	found, err := svc.GetForConversation(ctx, "tenant-1", "conv-1")
+	require.NoError(t, err)
+	assert.Equal(t, record.DispositionID, found.DispositionID)
+}
+
+func TestDispositionService_Delete(t *testing.T) {
+	svc, conversations, _, _ := newDispositionTestService()
+	ctx := context.Background()
+
+	used, err := svc.Create(ctx, "tenant-1", &DispositionInput{Code: "sales.won", Name: "Won"})
+	require.NoError(t, err)
+	unused, err := svc.Create(ctx, "tenant-1", &DispositionInput{Code: "sales.lost", Name: "Lost"})
+	require.NoError(t, err)
+
+	_, err = conversations.Resolve(ctx, "tenant-1", "conv-1", &ResolveConversationInput{DispositionCode: "sales.won"})
+	require.NoError(t, err)
+
+	err = svc.Delete(ctx, "tenant-1", used.ID)
+	assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code, "used dispositions must be deactivated")
+
+	require.NoError(t, svc.Delete(ctx, "tenant-1", unused.ID))
+
+	active := false
+	updated, err := svc.Update(ctx, "tenant-1", used.ID, &DispositionInput{Code: "sales.won", Name: "Won", Active: &active})
+	require.NoError(t, err)
+	assert.False(t, updated.Active)
+
+	list, err := svc.List(ctx, "tenant-1", true)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
@@ -0,0 +1,720 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/egress"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/internal/infrastructure/ocr"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Events published by the document parsing module
+const (
+	EventDocumentParsed = "document.parsed"
+)
+
+// Document parsing limits
+const (
+	MaxDocumentBytes          = 20 * 1024 * 1024
+	documentDownloadTimeout   = 30 * time.Second
+	documentProcessingTimeout = 2 * time.Minute
+	documentTextPreviewBytes  = 4000
+	documentAITextBytes       = 12000
+	maxDocumentTypes          = 20
+)
+
+var documentPrefixPattern = regexp.MustCompile(`^[a-z0-9_]{0,32}$`)
+
+// DocumentPipelineInput represents input for creating or updating a document pipeline
+type DocumentPipelineInput struct {
+	Name           string                `json:"name" binding:"required"`
+	Description    string                `json:"description"`
+	ChannelIDs     []string              `json:"channel_ids,omitempty"`
+	MimeTypes      []string              `json:"mime_types,omitempty"`
+	DocumentTypes  []entity.DocumentType `json:"document_types" binding:"required"`
+	AIExtraction   bool                  `json:"ai_extraction"`
+	MetadataPrefix *string               `json:"metadata_prefix,omitempty"` // Defaults to "doc_"
+	Priority       int                   `json:"priority"`
+	IsActive       *bool                 `json:"is_active,omitempty"`
+}
+
+// TestDocumentPipelineInput represents a dry run of document types against sample text
+type TestDocumentPipelineInput struct {
+	DocumentTypes []entity.DocumentType `json:"document_types" binding:"required"`
+	AIExtraction  bool                  `json:"ai_extraction"`
+	Text          string                `json:"text" binding:"required"`
+}
+
+// DocumentParsingService reads the documents customers attach to their messages.
+// The text of each attachment is extracted by the OCR engine, matched against
+// the tenant's pipelines to detect the document type, and the key fields of the
+// type are copied into the conversation metadata, where flows, templates and
+// agents can use them. A document type may also start a flow on the conversation,
+// such as an onboarding or claims flow.
+type DocumentParsingService struct {
+	repo             repository.DocumentPipelineRepository
+	conversationRepo repository.ConversationRepository
+	messageRepo      repository.MessageRepository
+	engine           ocr.Engine
+	aiFactory        *AIProviderFactory
+	producer         nats.Publisher
+	flowEngine       *FlowEngineService
+	contextService   *ConversationContextService
+	httpClient       *http.Client
+}
+
+// NewDocumentParsingService creates a new document parsing service
+func NewDocumentParsingService(
+	repo repository.DocumentPipelineRepository,
+	conversationRepo repository.ConversationRepository,
+	messageRepo repository.MessageRepository,
+	engine ocr.Engine,
+	aiFactory *AIProviderFactory,
+	producer nats.Publisher,
+) *DocumentParsingService {
+	return &DocumentParsingService{
+		repo:             repo,
+		conversationRepo: conversationRepo,
+		messageRepo:      messageRepo,
+		engine:           engine,
+		aiFactory:        aiFactory,
+		producer:         producer,
+		httpClient:       newDocumentClient(),
+	}
+}
+
+// newDocumentClient creates the client downloading attachments. Attachment URLs can be
+// given by API callers, so internal addresses are refused.
+func newDocumentClient() *http.Client {
+	client := egress.NewClient()
+	client.Timeout = documentDownloadTimeout
+	return client
+}
+
+// SetFlowEngine enables starting flows when a document type is detected
+func (s *DocumentParsingService) SetFlowEngine(flowEngine *FlowEngineService, contextService *ConversationContextService) {
+	s.flowEngine = flowEngine
+	s.contextService = contextService
+}
+
+// Create creates a new document pipeline
+func (s *DocumentParsingService) Create(ctx context.Context, tenantID string, input *DocumentPipelineInput) (*entity.DocumentPipeline, error) {
+	if err := validateDocumentPipeline(input); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	pipeline := &entity.DocumentPipeline{
+		ID:             uuid.New().String(),
+		TenantID:       tenantID,
+		Name:           input.Name,
+		Description:    input.Description,
+		ChannelIDs:     input.ChannelIDs,
+		MimeTypes:      input.MimeTypes,
+		DocumentTypes:  input.DocumentTypes,
+		AIExtraction:   input.AIExtraction,
+		MetadataPrefix: "doc_",
+		Priority:       input.Priority,
+		IsActive:       input.IsActive == nil || *input.IsActive,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if input.MetadataPrefix != nil {
+		pipeline.MetadataPrefix = *input.MetadataPrefix
+	}
+
+	if err := s.repo.Create(ctx, pipeline); err != nil {
+		return nil, err
+	}
+	return pipeline, nil
+}
+
+// Get returns a document pipeline of a tenant
+func (s *DocumentParsingService) Get(ctx context.Context, tenantID, id string) (*entity.DocumentPipeline, error) {
+	pipeline, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if pipeline.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeNotFound, "document pipeline not found")
+	}
+	return pipeline, nil
+}
+
+// List lists the document pipelines of a tenant
+func (s *DocumentParsingService) List(ctx context.Context, tenantID string) ([]*entity.DocumentPipeline, error) {
+	return s.repo.ListByTenant(ctx, tenantID)
+}
+
+// Update updates a document pipeline
+func (s *DocumentParsingService) Update(ctx context.Context, tenantID, id string, input *DocumentPipelineInput) (*entity.DocumentPipeline, error) {
+	if err := validateDocumentPipeline(input); err != nil {
+		return nil, err
+	}
+
+	pipeline, err := s.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline.Name = input.Name
+	pipeline.Description = input.Description
+	pipeline.ChannelIDs = input.ChannelIDs
+	pipeline.MimeTypes = input.MimeTypes
+	pipeline.DocumentTypes = input.DocumentTypes
+	pipeline.AIExtraction = input.AIExtraction
+	if input.MetadataPrefix != nil {
+		pipeline.MetadataPrefix = *input.MetadataPrefix
+	}
+	pipeline.Priority = input.Priority
+	if input.IsActive != nil {
+		pipeline.IsActive = *input.IsActive
+	}
+	pipeline.UpdatedAt = time.Now()
+
+	if err := s.repo.Update(ctx, pipeline); err != nil {
+		return nil, err
+	}
+	return pipeline, nil
+}
+
+// Delete deletes a document pipeline
+func (s *DocumentParsingService) Delete(ctx context.Context, tenantID, id string) error {
+	if _, err := s.Get(ctx, tenantID, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// Test detects the document type of a sample text and extracts its fields
+// without saving anything
+func (s *DocumentParsingService) Test(ctx context.Context, input *TestDocumentPipelineInput) (*entity.DocumentExtraction, error) {
+	pipeline := &entity.DocumentPipeline{
+		DocumentTypes: input.DocumentTypes,
+		AIExtraction:  input.AIExtraction,
+	}
+	if err := validateDocumentTypes(pipeline.DocumentTypes); err != nil {
+		return nil, err
+	}
+
+	extraction := &entity.DocumentExtraction{MimeType: "text/plain", CreatedAt: time.Now()}
+	docType := s.parse(ctx, pipeline, input.Text, extraction)
+	if docType != nil {
+		extraction.FlowID = docType.FlowID
+	}
+	return extraction, nil
+}
+
+// ListExtractions lists the documents parsed on a conversation of a tenant
+func (s *DocumentParsingService) ListExtractions(ctx context.Context, tenantID, conversationID string) ([]*entity.DocumentExtraction, error) {
+	conversation, err := s.conversationRepo.FindByID(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeNotFound, "conversation not found")
+	}
+	return s.repo.ListExtractionsByConversation(ctx, conversationID)
+}
+
+// HandleInbound parses the documents attached to an inbound message in the
+// background, so slow OCR never delays message delivery
+func (s *DocumentParsingService) HandleInbound(ctx context.Context, message *entity.Message, conversation *entity.Conversation) error {
+	if len(message.Attachments) == 0 {
+		return nil
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), documentProcessingTimeout)
+		defer cancel()
+		if _, err := s.Process(ctx, message, conversation); err != nil {
+			logger.Warn("Failed to parse message documents",
+				zap.String("message_id", message.ID),
+				zap.Error(err),
+			)
+		}
+	}()
+	return nil
+}
+
+// ProcessMessage parses the documents of a stored message on demand, such as
+// after a pipeline was added or changed
+func (s *DocumentParsingService) ProcessMessage(ctx context.Context, tenantID, conversationID, messageID string) ([]*entity.DocumentExtraction, error) {
+	conversation, err := s.conversationRepo.FindByID(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeNotFound, "conversation not found")
+	}
+
+	message, err := s.messageRepo.FindByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message.ConversationID != conversation.ID {
+		return nil, errors.New(errors.ErrCodeNotFound, "message not found")
+	}
+	if len(message.Attachments) == 0 {
+		attachments, err := s.messageRepo.FindAttachmentsByMessage(ctx, message.ID)
+		if err != nil {
+			return nil, err
+		}
+		message.Attachments = attachments
+	}
+	if len(message.Attachments) == 0 {
+		return nil, errors.Validation("message has no attachments")
+	}
+
+	return s.Process(ctx, message, conversation)
+}
+
+// Process parses each readable attachment of a message with the first active
+// pipeline that recognizes it. Attachments no pipeline applies to are skipped;
+// documents no pipeline recognizes are recorded as unrecognized.
+func (s *DocumentParsingService) Process(ctx context.Context, message *entity.Message, conversation *entity.Conversation) ([]*entity.DocumentExtraction, error) {
+	pipelines, err := s.repo.ListActive(ctx, conversation.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	if len(pipelines) == 0 {
+		return nil, nil
+	}
+
+	var extractions []*entity.DocumentExtraction
+	for _, attachment := range message.Attachments {
+		if attachment.MimeType != "" && !s.engine.Supports(attachment.MimeType) {
+			continue
+		}
+		extraction := s.processAttachment(ctx, pipelines, message, conversation, attachment)
+		if extraction == nil {
+			continue
+		}
+		if err := s.repo.CreateExtraction(ctx, extraction); err != nil {
+			return extractions, err
+		}
+		s.publishParsed(ctx, extraction)
+		extractions = append(extractions, extraction)
+	}
+	return extractions, nil
+}
+
+// processAttachment extracts the text of an attachment and runs the applicable
+// pipelines on it. It returns nil when no pipeline applies.
+func (s *DocumentParsingService) processAttachment(ctx context.Context, pipelines []*entity.DocumentPipeline, message *entity.Message, conversation *entity.Conversation, attachment *entity.MessageAttachment) *entity.DocumentExtraction {
+	ctx = WithAIUsage(ctx, conversation.TenantID, "", entity.AIFeatureExtraction)
+	mimeType := attachment.MimeType
+	applicable := func() []*entity.DocumentPipeline {
+		var result []*entity.DocumentPipeline
+		for _, pipeline := range pipelines {
+			if pipeline.AppliesTo(conversation.ChannelID, mimeType) {
+				result = append(result, pipeline)
+			}
+		}
+		return result
+	}
+
+	matching := applicable()
+	if mimeType != "" && len(matching) == 0 {
+		return nil
+	}
+
+	extraction := &entity.DocumentExtraction{
+		ID:             uuid.New().String(),
+		TenantID:       conversation.TenantID,
+		ConversationID: conversation.ID,
+		MessageID:      message.ID,
+		AttachmentID:   attachment.ID,
+		Filename:       attachment.Filename,
+		MimeType:       mimeType,
+		Status:         entity.DocumentExtractionUnrecognized,
+		CreatedAt:      time.Now(),
+	}
+
+	data, contentType, err := s.download(ctx, attachment.URL)
+	if err != nil {
+		extraction.Status = entity.DocumentExtractionFailed
+		extraction.Error = err.Error()
+		return extraction
+	}
+	if mimeType == "" {
+		// Channels that do not report the type are matched on the downloaded content
+		mimeType = contentType
+		if mimeType == "" {
+			mimeType = http.DetectContentType(data)
+		}
+		extraction.MimeType = mimeType
+		matching = applicable()
+		if len(matching) == 0 || !s.engine.Supports(mimeType) {
+			return nil
+		}
+	}
+
+	text, err := s.engine.ExtractText(ctx, data, mimeType)
+	if err != nil {
+		extraction.Status = entity.DocumentExtractionFailed
+		extraction.Error = err.Error()
+		return extraction
+	}
+	extraction.Text = truncateText(text, documentTextPreviewBytes)
+
+	for _, pipeline := range matching {
+		docType := s.parse(ctx, pipeline, text, extraction)
+		if docType == nil {
+			continue
+		}
+		extraction.PipelineID = pipeline.ID
+		s.applyToConversation(ctx, pipeline, docType, extraction)
+		if docType.FlowID != "" {
+			s.startFlow(ctx, docType, extraction, message, conversation)
+		}
+		return extraction
+	}
+
+	extraction.PipelineID = matching[0].ID
+	return extraction
+}
+
+// parse detects the document type of the text and extracts its fields into the
+// extraction. It returns nil when the pipeline does not recognize the document.
+func (s *DocumentParsingService) parse(ctx context.Context, pipeline *entity.DocumentPipeline, text string, extraction *entity.DocumentExtraction) *entity.DocumentType {
+	docType := pipeline.DetectType(text)
+	if docType == nil {
+		return nil
+	}
+
+	extraction.DocumentType = docType.Type
+	extraction.Status = entity.DocumentExtractionCompleted
+	extraction.Fields = make(map[string]string)
+	extraction.AIFields = nil
+
+	var missing []entity.DocumentField
+	for _, field := range docType.Fields {
+		if value := matchDocumentField(field, text); value != "" {
+			extraction.Fields[field.Key] = value
+		} else {
+			missing = append(missing, field)
+		}
+	}
+
+	if pipeline.AIExtraction && len(missing) > 0 {
+		values, err := s.aiExtract(ctx, docType.Type, missing, text)
+		if err != nil {
+			// Pattern matches are still useful; the AI only fills gaps
+			logger.Warn("AI document extraction failed",
+				zap.String("document_type", docType.Type),
+				zap.Error(err),
+			)
+		}
+		for _, field := range missing {
+			if value := strings.TrimSpace(values[field.Key]); value != "" {
+				extraction.Fields[field.Key] = value
+				extraction.AIFields = append(extraction.AIFields, field.Key)
+			}
+		}
+	}
+	return docType
+}
+
+// matchDocumentField returns the first capture group of the field pattern, or
+// the whole match when the pattern has no group
+func matchDocumentField(field entity.DocumentField, text string) string {
+	if field.Pattern == "" {
+		return ""
+	}
+	re, err := regexp.Compile(field.Pattern)
+	if err != nil {
+		return ""
+	}
+	match := re.FindStringSubmatch(text)
+	if match == nil {
+		return ""
+	}
+	if len(match) > 1 {
+		return strings.TrimSpace(match[1])
+	}
+	return strings.TrimSpace(match[0])
+}
+
+// aiExtract asks the AI for the fields the patterns missed
+func (s *DocumentParsingService) aiExtract(ctx context.Context, documentType string, fields []entity.DocumentField, text string) (map[string]string, error) {
+	provider, err := s.aiFactory.FirstAvailable()
+	if err != nil {
+		return nil, err
+	}
+
+	var spec strings.Builder
+	for _, field := range fields {
+		fmt.Fprintf(&spec, "- %s: %s", field.Key, field.Label)
+		if field.AIHint != "" {
+			fmt.Fprintf(&spec, ". %s", field.AIHint)
+		}
+		spec.WriteString("\n")
+	}
+
+	prompt := fmt.Sprintf(`Extract the following fields from the %s below, read by OCR.
+Reply with a single JSON object whose keys are the field keys and whose values are strings.
+Use null for any value the document does not state; do not guess.
+
+Fields:
+%s
+Document:
+%s`, documentType, spec.String(), truncateText(text, documentAITextBytes))
+
+	resp, err := provider.Complete(ctx, &CompletionRequest{
+		Messages: []Message{
+			{Role: "system", Content: "You extract structured data from scanned documents and reply only with JSON."},
+			{Role: "user", Content: prompt},
+		},
+		Model:       provider.DefaultModel(),
+		MaxTokens:   800,
+		Temperature: 0,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to extract document fields")
+	}
+
+	content := resp.Content
+	start, end := strings.Index(content, "{"), strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, errors.New(errors.ErrCodeInternal, "AI extraction returned no JSON object")
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &raw); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to parse AI extraction")
+	}
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		if value != nil {
+			values[key] = fmt.Sprint(value)
+		}
+	}
+	return values, nil
+}
+
+// applyToConversation copies the document type and fields into the conversation
+// metadata under the pipeline prefix. The conversation is reloaded because the
+// document is parsed in the background.
+func (s *DocumentParsingService) applyToConversation(ctx context.Context, pipeline *entity.DocumentPipeline, docType *entity.DocumentType, extraction *entity.DocumentExtraction) {
+	conversation, err := s.conversationRepo.FindByID(ctx, extraction.ConversationID)
+	if err != nil {
+		logger.Warn("Failed to load conversation for document fields",
+			zap.String("conversation_id", extraction.ConversationID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	metadata := map[string]string{pipeline.MetadataPrefix + "type": docType.Type}
+	for key, value := range extraction.Fields {
+		metadata[pipeline.MetadataPrefix+key] = value
+	}
+
+	if err := s.conversationRepo.SetMetadata(ctx, conversation.ID, metadata); err != nil {
+		logger.Warn("Failed to store document fields on conversation",
+			zap.String("conversation_id", conversation.ID),
+			zap.Error(err),
+		)
+	}
+}
+
+// startFlow starts the flow of the detected document type on the conversation.
+// The fields are stored as collected data once the flow is active, so later
+// nodes can use them.
+func (s *DocumentParsingService) startFlow(ctx context.Context, docType *entity.DocumentType, extraction *entity.DocumentExtraction, message *entity.Message, conversation *entity.Conversation) {
+	if s.flowEngine == nil || s.contextService == nil {
+		return
+	}
+
+	result, err := func() (*entity.FlowExecutionResult, error) {
+		convContext, err := s.contextService.GetOrCreate(ctx, conversation.ID)
+		if err != nil {
+			return nil, err
+		}
+		result, err := s.flowEngine.ResumeAtNode(ctx, conversation.TenantID, docType.FlowID, "", "", convContext)
+		if err != nil {
+			return nil, err
+		}
+		s.flowEngine.StoreCollectedData(convContext, "document_type", docType.Type)
+		for key, value := range extraction.Fields {
+			s.flowEngine.StoreCollectedData(convContext, key, value)
+		}
+		s.flowEngine.RecordTrace(ctx, message, conversation, docType.FlowID, result, convContext)
+		return result, s.contextService.save(ctx, convContext)
+	}()
+	if err != nil {
+		logger.Warn("Failed to start document flow",
+			zap.String("flow_id", docType.FlowID),
+			zap.String("conversation_id", conversation.ID),
+			zap.Error(err),
+		)
+		return
+	}
+	extraction.FlowID = docType.FlowID
+
+	if s.producer != nil {
+		s.producer.PublishEvent(ctx, &nats.Event{
+			Type:     nats.EventBotResponse,
+			TenantID: conversation.TenantID,
+			Payload: map[string]interface{}{
+				"message_id":      message.ID,
+				"conversation_id": conversation.ID,
+				"contact_id":      conversation.ContactID,
+				"channel_id":      conversation.ChannelID,
+				"flow_id":         docType.FlowID,
+				"response":        result.Message,
+				"quick_replies":   result.QuickReplies,
+				"flow_ended":      result.FlowEnded,
+			},
+			Timestamp: time.Now(),
+		})
+	}
+}
+
+// download fetches an attachment, refusing documents over MaxDocumentBytes
+func (s *DocumentParsingService) download(ctx context.Context, url string) ([]byte, string, error) {
+	return downloadDocument(ctx, s.httpClient, url)
+}
+
+// downloadDocument fetches an attachment up to MaxDocumentBytes and returns it with
+// the content type reported by the server
+func downloadDocument(ctx context.Context, client *http.Client, url string) ([]byte, string, error) {
+	if url == "" {
+		return nil, "", fmt.Errorf("attachment has no URL")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", defaultUserAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download document: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("download returned status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxDocumentBytes+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read document: %w", err)
+	}
+	if len(data) > MaxDocumentBytes {
+		return nil, "", fmt.Errorf("document exceeds maximum size of %d bytes", MaxDocumentBytes)
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+func (s *DocumentParsingService) publishParsed(ctx context.Context, extraction *entity.DocumentExtraction) {
+	if s.producer == nil {
+		return
+	}
+	s.producer.PublishEvent(ctx, &nats.Event{
+		Type:     EventDocumentParsed,
+		TenantID: extraction.TenantID,
+		Payload: map[string]interface{}{
+			"extraction_id":   extraction.ID,
+			"pipeline_id":     extraction.PipelineID,
+			"conversation_id": extraction.ConversationID,
+			"message_id":      extraction.MessageID,
+			"document_type":   extraction.DocumentType,
+			"status":          string(extraction.Status),
+			"fields":          extraction.Fields,
+			"flow_id":         extraction.FlowID,
+		},
+		Timestamp: time.Now(),
+	})
+}
+
+// truncateText caps a text at max bytes without splitting a character
+func truncateText(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut]
+}
+
+// validateDocumentPipeline checks the definition of a pipeline
+func validateDocumentPipeline(input *DocumentPipelineInput) error {
+	if input.Name == "" {
+		return errors.Validation("name is required")
+	}
+	if input.MetadataPrefix != nil && !documentPrefixPattern.MatchString(*input.MetadataPrefix) {
+		return errors.Validation("metadata_prefix may only contain lowercase letters, digits and underscores")
+	}
+	return validateDocumentTypes(input.DocumentTypes)
+}
+
+// validateDocumentTypes checks the document types of a pipeline and their field patterns
+func validateDocumentTypes(docTypes []entity.DocumentType) error {
+	if len(docTypes) == 0 {
+		return errors.Validation("at least one document type is required")
+	}
+	if len(docTypes) > maxDocumentTypes {
+		return errors.Validation(fmt.Sprintf("a pipeline supports at most %d document types", maxDocumentTypes))
+	}
+
+	seen := make(map[string]bool)
+	for _, docType := range docTypes {
+		if !formFieldKeyPattern.MatchString(docType.Type) {
+			return errors.Validation(fmt.Sprintf("invalid document type %q: use lowercase letters, digits and underscores", docType.Type))
+		}
+		if seen[docType.Type] {
+			return errors.Validation(fmt.Sprintf("duplicate document type %q", docType.Type))
+		}
+		seen[docType.Type] = true
+		if len(docType.Keywords) == 0 {
+			return errors.Validation(fmt.Sprintf("document type %q needs at least one keyword", docType.Type))
+		}
+		if docType.MinMatches > len(docType.Keywords) {
+			return errors.Validation(fmt.Sprintf("document type %q requires more keyword matches than it has keywords", docType.Type))
+		}
+
+		keys := make([]string, 0, len(docType.Fields))
+		for _, field := range docType.Fields {
+			// "type" is reserved for the detected document type in the conversation metadata
+			if !formFieldKeyPattern.MatchString(field.Key) || field.Key == "type" {
+				return errors.Validation(fmt.Sprintf("invalid field key %q in document type %q", field.Key, docType.Type))
+			}
+			if field.Pattern != "" {
+				if _, err := regexp.Compile(field.Pattern); err != nil {
+					return errors.New(errors.ErrCodeValidation, "invalid field pattern").WithDetails(map[string]string{
+						"document_type": docType.Type,
+						"field":         field.Key,
+						"error":         err.Error(),
+					})
+				}
+			}
+			keys = append(keys, field.Key)
+		}
+		sort.Strings(keys)
+		for i := 1; i < len(keys); i++ {
+			if keys[i] == keys[i-1] {
+				return errors.Validation(fmt.Sprintf("duplicate field key %q in document type %q", keys[i], docType.Type))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/infrastructure/egress"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/internal/infrastructure/ocr"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockDocumentPipelineRepo struct {
+	pipelines   map[string]*entity.DocumentPipeline
+	extractions []*entity.DocumentExtraction
+}
+
+func newMockDocumentPipelineRepo() *mockDocumentPipelineRepo {
+	return &mockDocumentPipelineRepo{pipelines: make(map[string]*entity.DocumentPipeline)}
+}
+
+func (m *mockDocumentPipelineRepo) Create(ctx context.Context, pipeline *entity.DocumentPipeline) error {
+	m.pipelines[pipeline.ID] = pipeline
+	return nil
+}
+
+func (m *mockDocumentPipelineRepo) FindByID(ctx context.Context, id string) (*entity.DocumentPipeline, error) {
+	if pipeline, ok := m.pipelines[id]; ok {
+		return pipeline, nil
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "document pipeline not found")
+}
+
+func (m *mockDocumentPipelineRepo) ListByTenant(ctx context.Context, tenantID string) ([]*entity.DocumentPipeline, error) {
+	var result []*entity.DocumentPipeline
+	for _, pipeline := range m.pipelines {
+		if pipeline.TenantID == tenantID {
+			result = append(result, pipeline)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockDocumentPipelineRepo) ListActive(ctx context.Context, tenantID string) ([]*entity.DocumentPipeline, error) {
+	var result []*entity.DocumentPipeline
+	for _, pipeline := range m.pipelines {
+		if pipeline.TenantID == tenantID && pipeline.IsActive {
+			result = append(result, pipeline)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockDocumentPipelineRepo) Update(ctx context.Context, pipeline *entity.DocumentPipeline) error {
+	m.pipelines[pipeline.ID] = pipeline
+	return nil
+}
+
+func (m *mockDocumentPipelineRepo) Delete(ctx context.Context, id string) error {
+	delete(m.pipelines, id)
+	return nil
+}
+
+func (m *mockDocumentPipelineRepo) CreateExtraction(ctx context.Context, extraction *entity.DocumentExtraction) error {
+	m.extractions = append(m.extractions, extraction)
+	return nil
+}
+
+func (m *mockDocumentPipelineRepo) ListExtractionsByConversation(ctx context.Context, conversationID string) ([]*entity.DocumentExtraction, error) {
+	var result []*entity.DocumentExtraction
+	for _, extraction := range m.extractions {
+		if extraction.ConversationID == conversationID {
+			result = append(result, extraction)
+		}
+	}
+	return result, nil
+}
+
+// textEngine "reads" images by returning their bytes as text
+type textEngine struct{}
+
+func (textEngine) Supports(mimeType string) bool {
+	return strings.HasPrefix(mimeType, "image/") || strings.HasPrefix(mimeType, "text/")
+}
+
+func (textEngine) ExtractText(ctx context.Context, data []byte, mimeType string) (string, error) {
+	return string(data), nil
+}
+
+var _ ocr.Engine = textEngine{}
+
+func invoicePipelineInput() *DocumentPipelineInput {
+	return &DocumentPipelineInput{
+		Name:         "Claims",
+		AIExtraction: true,
+		DocumentTypes: []entity.DocumentType{
+			{
+				Type:       "invoice",
+				Keywords:   []string{"invoice", "total"},
+				MinMatches: 2,
+				FlowID:     "flow-1",
+				Fields: []entity.DocumentField{
+					{Key: "invoice_number", Label: "Invoice number", Pattern: `(?i)invoice\s*#?\s*([0-9]+)`},
+					{Key: "total", Label: "Total", Pattern: `(?i)total:\s*([0-9.,]+)`},
+					{Key: "supplier", Label: "Supplier name"},
+				},
+			},
+			{
+				Type:     "id_card",
+				Keywords: []string{"identity card"},
+				Fields: []entity.DocumentField{
+					{Key: "document_number", Label: "Document number", Pattern: `No\. ([A-Z0-9]+)`},
+				},
+			},
+		},
+	}
+}
+
+type documentTestEnv struct {
+	svc           *DocumentParsingService
+	repo          *mockDocumentPipelineRepo
+	conversations *testutil.MockConversationRepository
+	producer      *testutil.MockProducer
+	ai            *extractingAIProvider
+	files         map[string]string
+	server        *httptest.Server
+}
+
+func newDocumentTestEnv(t *testing.T) *documentTestEnv {
+	env := &documentTestEnv{
+		repo:          newMockDocumentPipelineRepo(),
+		conversations: testutil.NewMockConversationRepository(),
+		producer:      testutil.NewMockProducer(),
+		ai:            &extractingAIProvider{mockAIProvider: mockAIProvider{name: entity.AIProviderOpenAI, available: true}},
+		files:         make(map[string]string),
+	}
+	env.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := env.files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(content))
+	}))
+	t.Cleanup(env.server.Close)
+
+	env.conversations.Conversations["conv-1"] = &entity.Conversation{
+		ID: "conv-1", TenantID: "tenant-1", ChannelID: "ch-1", ContactID: "contact-1", Metadata: map[string]string{},
+	}
+
+	factory := NewAIProviderFactory()
+	factory.Register(env.ai)
+	env.svc = NewDocumentParsingService(env.repo, env.conversations, testutil.NewMockMessageRepository(), textEngine{}, factory, env.producer)
+	env.svc.httpClient = http.DefaultClient
+	return env
+}
+
+func (env *documentTestEnv) message(attachments ...*entity.MessageAttachment) *entity.Message {
+	for _, attachment := range attachments {
+		attachment.URL = env.server.URL + "/" + attachment.ID
+	}
+	return &entity.Message{ID: "msg-1", ConversationID: "conv-1", ContentType: entity.ContentTypeImage, Attachments: attachments}
+}
+
+func TestDocumentPipelineValidation(t *testing.T) {
+	env := newDocumentTestEnv(t)
+	ctx := context.Background()
+
+	cases := map[string]func(*DocumentPipelineInput){
+		"no types":        func(in *DocumentPipelineInput) { in.DocumentTypes = nil },
+		"bad type":        func(in *DocumentPipelineInput) { in.DocumentTypes[0].Type = "Invoice" },
+		"no keywords":     func(in *DocumentPipelineInput) { in.DocumentTypes[0].Keywords = nil },
+		"min matches":     func(in *DocumentPipelineInput) { in.DocumentTypes[0].MinMatches = 3 },
+		"bad pattern":     func(in *DocumentPipelineInput) { in.DocumentTypes[0].Fields[0].Pattern = `([0-9]` },
+		"reserved key":    func(in *DocumentPipelineInput) { in.DocumentTypes[0].Fields[0].Key = "type" },
+		"duplicate field": func(in *DocumentPipelineInput) { in.DocumentTypes[0].Fields[1].Key = "invoice_number" },
+	}
+	for name, mutate := range cases {
+		t.Run(name, func(t *testing.T) {
+			input := invoicePipelineInput()
+			mutate(input)
+			_, err := env.svc.Create(ctx, "tenant-1", input)
+			assert.True(t, errors.IsValidation(err), "expected validation error, got %v", err)
+		})
+	}
+
+	pipeline, err := env.svc.Create(ctx, "tenant-1", invoicePipelineInput())
+	require.NoError(t, err)
+	assert.Equal(t, "doc_", pipeline.MetadataPrefix)
+	assert.True(t, pipeline.IsActive)
+}
+
+func TestDocumentProcessExtractsFieldsAndStartsFlow(t *testing.T) {
+	env := newDocumentTestEnv(t)
+	ctx := context.Background()
+
+	flowEngine, flowRepo, _ := newFlowEngine()
+	flowRepo.flows["flow-1"] = makeSimpleFlow("tenant-1")
+	env.svc.SetFlowEngine(flowEngine, NewConversationContextService(newMockConversationContextRepository(), nil))
+
+	_, err := env.svc.Create(ctx, "tenant-1", invoicePipelineInput())
+	require.NoError(t, err)
+	env.ai.reply = `{"supplier": "ACME Ltda"}`
+	env.files["/att-1"] = "INVOICE #4521\nACME Ltda\nTotal: 1.250,00"
+	env.files["/att-2"] = "voice note"
+
+	extractions, err := env.svc.Process(ctx, env.message(
+		&entity.MessageAttachment{ID: "att-1", MimeType: "image/jpeg", Filename: "invoice.jpg"},
+		&entity.MessageAttachment{ID: "att-2", MimeType: "audio/ogg"},
+	), env.conversations.Conversations["conv-1"])
+	require.NoError(t, err)
+	require.Len(t, extractions, 1)
+
+	extraction := extractions[0]
+	assert.Equal(t, entity.DocumentExtractionCompleted, extraction.Status)
+	assert.Equal(t, "invoice", extraction.DocumentType)
+	assert.Equal(t, map[string]string{"invoice_number": "4521", "total": "1.250,00", "supplier": "ACME Ltda"}, extraction.Fields)
+	assert.Equal(t, []string{"supplier"}, extraction.AIFields)
+	assert.Equal(t, "flow-1", extraction.FlowID)
+	assert.Contains(t, env.ai.prompt, "Supplier name")
+	assert.NotContains(t, env.ai.prompt, "Invoice number")
+
+	metadata := env.conversations.Conversations["conv-1"].Metadata
+	assert.Equal(t, "invoice", metadata["doc_type"])
+	assert.Equal(t, "4521", metadata["doc_invoice_number"])
+	assert.Equal(t, "ACME Ltda", metadata["doc_supplier"])
+
+	require.Len(t, env.repo.extractions, 1)
+	var types []string
+	for _, event := range env.producer.Events {
+		types = append(types, event.Type)
+		if event.Type == nats.EventBotResponse {
+			assert.Equal(t, "Welcome! Do you need assistance?", event.Payload["response"])
+		}
+	}
+	assert.ElementsMatch(t, []string{nats.EventBotResponse, EventDocumentParsed}, types)
+}
+
+func TestDocumentProcessRecordsUnrecognizedAndFailedDocuments(t *testing.T) {
+	env := newDocumentTestEnv(t)
+	ctx := context.Background()
+
+	input := invoicePipelineInput()
+	input.AIExtraction = false
+	input.MimeTypes = []string{"image/*"}
+	_, err := env.svc.Create(ctx, "tenant-1", input)
+	require.NoError(t, err)
+	env.files["/att-1"] = "A photo of a cat"
+
+	extractions, err := env.svc.Process(ctx, env.message(
+		&entity.MessageAttachment{ID: "att-1", MimeType: "image/png"},
+		&entity.MessageAttachment{ID: "att-missing", MimeType: "image/png"},
+		&entity.MessageAttachment{ID: "att-3", MimeType: "text/plain"},
+	), env.conversations.Conversations["conv-1"])
+	require.NoError(t, err)
+	require.Len(t, extractions, 2)
+
+	assert.Equal(t, entity.DocumentExtractionUnrecognized, extractions[0].Status)
+	assert.Equal(t, "A photo of a cat", extractions[0].Text)
+	assert.Equal(t, entity.DocumentExtractionFailed, extractions[1].Status)
+	assert.Contains(t, extractions[1].Error, "404")
+	assert.Empty(t, env.conversations.Conversations["conv-1"].Metadata)
+}
+
+func TestDocumentProcessRefusesInternalAttachments(t *testing.T) {
+	env := newDocumentTestEnv(t)
+	env.svc.httpClient = newDocumentClient()
+	ctx := context.Background()
+
+	_, err := env.svc.Create(ctx, "tenant-1", invoicePipelineInput())
+	require.NoError(t, err)
+	env.files["/att-1"] = "INVOICE #4521\nACME Ltda\nTotal: 1.250,00"
+
+	// The test server listens on loopback
+	extractions, err := env.svc.Process(ctx, env.message(
+		&entity.MessageAttachment{ID: "att-1", MimeType: "image/jpeg"},
+	), env.conversations.Conversations["conv-1"])
+	require.NoError(t, err)
+	require.Len(t, extractions, 1)
+	assert.Equal(t, entity.DocumentExtractionFailed, extractions[0].Status)
+	assert.Contains(t, extractions[0].Error, egress.ErrForbiddenAddress.Error())
+}
+
+func TestDocumentPipelineTest(t *testing.T) {
+	env := newDocumentTestEnv(t)
+
+	input := invoicePipelineInput()
+	result, err := env.svc.Test(context.Background(), &TestDocumentPipelineInput{
+		DocumentTypes: input.DocumentTypes,
+		Text:          "REPUBLIC IDENTITY CARD\nNo. AB12345",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "id_card", result.DocumentType)
+	assert.Equal(t, "AB12345", result.Fields["document_number"])
+	assert.Empty(t, env.repo.extractions)
+}
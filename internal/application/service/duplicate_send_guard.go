@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// EventDuplicateSendPrevented is published when a duplicate outbound send is blocked or flagged
+const EventDuplicateSendPrevented = "message.duplicate_prevented"
+
+// MetadataAllowDuplicate lets a sender deliberately repeat a message inside the window
+const MetadataAllowDuplicate = "allow_duplicate"
+
+// duplicateSendScanLimit caps how many recent sends to a contact are compared
+const duplicateSendScanLimit = 50
+
+// DuplicateSendGuard catches identical outbound messages sent to the same contact within
+// the tenant's window, as happens when agents double-click send or automations fire twice.
+// Depending on the tenant setting the duplicate is rejected or sent marked as duplicate.
+type DuplicateSendGuard struct {
+	messageRepo      repository.MessageRepository
+	conversationRepo repository.ConversationRepository
+	tenantRepo       repository.TenantRepository
+	producer         nats.Publisher
+	observability    *ObservabilityService
+}
+
+// NewDuplicateSendGuard creates a new duplicate send guard
+func NewDuplicateSendGuard(
+	messageRepo repository.MessageRepository,
+	conversationRepo repository.ConversationRepository,
+	tenantRepo repository.TenantRepository,
+	producer nats.Publisher,
+) *DuplicateSendGuard {
+	return &DuplicateSendGuard{
+		messageRepo:      messageRepo,
+		conversationRepo: conversationRepo,
+		tenantRepo:       tenantRepo,
+		producer:         producer,
+	}
+}
+
+// SetObservability records prevented duplicates in the tenant's message logs
+func (g *DuplicateSendGuard) SetObservability(observability *ObservabilityService) {
+	g.observability = observability
+}
+
+// CheckOutbound rejects or flags a message identical to one sent to the same contact
+// within the window. Lookup failures never block a send.
+func (g *DuplicateSendGuard) CheckOutbound(ctx context.Context, channel *entity.Channel, message *entity.Message) error {
+	if channel == nil || message.Content == "" || message.Metadata[MetadataAllowDuplicate] == "true" {
+		return nil
+	}
+
+	tenant, err := g.tenantRepo.FindByID(ctx, channel.TenantID)
+	if err != nil {
+		return nil
+	}
+	window := tenant.DuplicateSendWindow()
+	if window <= 0 {
+		return nil
+	}
+
+	conversation, err := g.conversationRepo.FindByID(ctx, message.ConversationID)
+	if err != nil {
+		return nil
+	}
+
+	recent, err := g.messageRepo.FindRecentOutboundByContact(ctx, conversation.ContactID, time.Now().Add(-window), duplicateSendScanLimit)
+	if err != nil {
+		logger.Warn("Failed to check for duplicate sends",
+			zap.String("conversation_id", message.ConversationID),
+			zap.Error(err),
+		)
+		return nil
+	}
+
+	var original *entity.Message
+	for _, sent := range recent {
+		if isDuplicateSend(sent, message) {
+			original = sent
+			break
+		}
+	}
+	if original == nil {
+		return nil
+	}
+
+	action := tenant.DuplicateSendAction()
+	g.record(ctx, channel, conversation, message, original, action, window)
+
+	if action == entity.DuplicateSendActionFlag {
+		if message.Metadata == nil {
+			message.Metadata = make(map[string]string)
+		}
+		message.Metadata["duplicate_of"] = original.ID
+		return nil
+	}
+
+	seconds := int(time.Since(original.CreatedAt).Seconds())
+	return errors.New(errors.ErrCodeConflict, fmt.Sprintf("an identical message was sent to this contact %ds ago", seconds)).
+		WithDetails(map[string]string{"duplicate_of": original.ID})
+}
+
+// isDuplicateSend reports whether two outbound messages carry the same content
+func isDuplicateSend(sent, message *entity.Message) bool {
+	if sent.ContentType != message.ContentType || sent.Content != message.Content {
+		return false
+	}
+	// Templates with the same body text can still differ in the template sent
+	return sent.Metadata["template_name"] == message.Metadata["template_name"]
+}
+
+// record logs and publishes a prevented duplicate
+func (g *DuplicateSendGuard) record(ctx context.Context, channel *entity.Channel, conversation *entity.Conversation, message, original *entity.Message, action string, window time.Duration) {
+	details := map[string]interface{}{
+		"conversation_id": conversation.ID,
+		"contact_id":      conversation.ContactID,
+		"duplicate_of":    original.ID,
+		"sender_type":     string(message.SenderType),
+		"sender_id":       message.SenderID,
+		"action":          action,
+		"window_seconds":  int(window.Seconds()),
+	}
+
+	if g.observability != nil {
+		text := "Duplicate outbound message blocked"
+		if action == entity.DuplicateSendActionFlag {
+			text = "Duplicate outbound message flagged"
+		}
+		channelID := channel.ID
+		if err := g.observability.LogWarn(ctx, channel.TenantID, &channelID, entity.LogSourceSystem, text, details); err != nil {
+			logger.Warn("Failed to log duplicate send", zap.Error(err))
+		}
+	}
+
+	if g.producer != nil {
+		g.producer.PublishEvent(ctx, &nats.Event{
+			Type:      EventDuplicateSendPrevented,
+			TenantID:  channel.TenantID,
+			Payload:   details,
+			Timestamp: time.Now(),
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type duplicateGuardFixture struct {
+	guard    *DuplicateSendGuard
+	tenant   *entity.Tenant
+	channel  *entity.Channel
+	messages *testutil.MockMessageRepository
+	producer *testutil.MockProducer
+}
+
+func newDuplicateGuardFixture() *duplicateGuardFixture {
+	tenants := testutil.NewMockTenantRepository()
+	tenant := &entity.Tenant{ID: "t1", Settings: map[string]string{}}
+	tenants.Tenants["t1"] = tenant
+
+	conversations := testutil.NewMockConversationRepository()
+	conversations.Conversations["conv1"] = &entity.Conversation{ID: "conv1", TenantID: "t1", ContactID: "contact1"}
+	conversations.Conversations["conv2"] = &entity.Conversation{ID: "conv2", TenantID: "t1", ContactID: "contact1"}
+
+	messages := testutil.NewMockMessageRepository()
+	messages.ConversationContacts["conv1"] = "contact1"
+	messages.ConversationContacts["conv2"] = "contact1"
+	messages.Messages["sent1"] = &entity.Message{
+		ID:             "sent1",
+		ConversationID: "conv1",
+		SenderType:     entity.SenderTypeUser,
+		ContentType:    entity.ContentTypeText,
+		Content:        "Your order has shipped",
+		Status:         entity.MessageStatusSent,
+		CreatedAt:      time.Now().Add(-3 * time.Second),
+	}
+
+	producer := testutil.NewMockProducer()
+	return &duplicateGuardFixture{
+		guard:    NewDuplicateSendGuard(messages, conversations, tenants, producer),
+		tenant:   tenant,
+		channel:  &entity.Channel{ID: "ch1", TenantID: "t1"},
+		messages: messages,
+		producer: producer,
+	}
+}
+
+func outboundText(conversationID, content string) *entity.Message {
+	return &entity.Message{
+		ConversationID: conversationID,
+		SenderType:     entity.SenderTypeUser,
+		ContentType:    entity.ContentTypeText,
+		Content:        content,
+		Metadata:       map[string]string{},
+	}
+}
+
+func TestDuplicateSendGuard_BlocksIdenticalSend(t *testing.T) {
+	f := newDuplicateGuardFixture()
+
+	// A different conversation with the same contact still counts
+	err := f.guard.CheckOutbound(context.Background(), f.channel, outboundText("conv2", "Your order has shipped"))
+	require.Error(t, err)
+	appErr := errors.GetAppError(err)
+	assert.Equal(t, errors.ErrCodeConflict, appErr.Code)
+	assert.Equal(t, "sent1", appErr.Details["duplicate_of"])
+
+	require.Len(t, f.producer.Events, 1)
+	assert.Equal(t, EventDuplicateSendPrevented, f.producer.Events[0].Type)
+	assert.Equal(t, entity.DuplicateSendActionBlock, f.producer.Events[0].Payload["action"])
+}
+
+func TestDuplicateSendGuard_FlagAction(t *testing.T) {
+	f := newDuplicateGuardFixture()
+	f.tenant.Settings[entity.TenantSettingDuplicateSendAction] = entity.DuplicateSendActionFlag
+
+	message := outboundText("conv1", "Your order has shipped")
+	require.NoError(t, f.guard.CheckOutbound(context.Background(), f.channel, message))
+	assert.Equal(t, "sent1", message.Metadata["duplicate_of"])
+	assert.Len(t, f.producer.Events, 1)
+}
+
+func TestDuplicateSendGuard_AllowsDistinctSends(t *testing.T) {
+	f := newDuplicateGuardFixture()
+	ctx := context.Background()
+
+	assert.NoError(t, f.guard.CheckOutbound(ctx, f.channel, outboundText("conv1", "Your order was delivered")))
+
+	override := outboundText("conv1", "Your order has shipped")
+	override.Metadata[MetadataAllowDuplicate] = "true"
+	assert.NoError(t, f.guard.CheckOutbound(ctx, f.channel, override))
+
+	template := outboundText("conv1", "Your order has shipped")
+	template.Metadata["template_name"] = "order_shipped"
+	assert.NoError(t, f.guard.CheckOutbound(ctx, f.channel, template))
+
+	f.messages.Messages["sent1"].CreatedAt = time.Now().Add(-time.Minute)
+	assert.NoError(t, f.guard.CheckOutbound(ctx, f.channel, outboundText("conv1", "Your order has shipped")))
+
+	assert.Empty(t, f.producer.Events)
+}
+
+func TestDuplicateSendGuard_WindowSetting(t *testing.T) {
+	f := newDuplicateGuardFixture()
+	ctx := context.Background()
+
+	f.tenant.Settings[entity.TenantSettingDuplicateSendWindow] = "0"
+	assert.NoError(t, f.guard.CheckOutbound(ctx, f.channel, outboundText("conv1", "Your order has shipped")))
+
+	f.tenant.Settings[entity.TenantSettingDuplicateSendWindow] = "2"
+	assert.NoError(t, f.guard.CheckOutbound(ctx, f.channel, outboundText("conv1", "Your order has shipped")))
+
+	f.tenant.Settings[entity.TenantSettingDuplicateSendWindow] = "60"
+	assert.Error(t, f.guard.CheckOutbound(ctx, f.channel, outboundText("conv1", "Your order has shipped")))
+}
@@ -5,12 +5,18 @@ import (
 	"fmt"
 
 	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
 )
 
+// DefaultEmbeddingBatchSize is how many texts are sent per provider batch request
+const DefaultEmbeddingBatchSize = 100
+
 // EmbeddingService handles embedding generation for RAG
 type EmbeddingService struct {
 	aiFactory *AIProviderFactory
 	config    *EmbeddingConfig
+	cache     EmbeddingCache
 }
 
 // EmbeddingConfig holds configuration for the embedding service
@@ -18,6 +24,14 @@ type EmbeddingConfig struct {
 	DefaultProvider entity.AIProviderType
 	DefaultModel    string
 	Dimensions      int
+	BatchSize       int // Texts per provider batch request, DefaultEmbeddingBatchSize if zero
+}
+
+// EmbeddingBatchResult holds the embeddings of a batch, in the order of its texts
+type EmbeddingBatchResult struct {
+	Embeddings [][]float64
+	CacheHits  int // Texts whose embedding came from the cache
+	Generated  int // Distinct texts embedded by the provider
 }
 
 // DefaultEmbeddingConfig returns default configuration
@@ -40,6 +54,12 @@ func NewEmbeddingService(aiFactory *AIProviderFactory, config *EmbeddingConfig)
 	}
 }
 
+// SetCache sets the cache of embeddings by content hash, so unchanged texts are
+// not sent to the provider again
+func (s *EmbeddingService) SetCache(cache EmbeddingCache) {
+	s.cache = cache
+}
+
 // GenerateEmbedding generates an embedding for the given text
 func (s *EmbeddingService) GenerateEmbedding(ctx context.Context, text string) ([]float64, error) {
 	return s.GenerateEmbeddingWithProvider(ctx, text, s.config.DefaultProvider, s.config.DefaultModel)
@@ -57,6 +77,11 @@ func (s *EmbeddingService) GenerateEmbeddingWithProvider(
 		return nil, fmt.Errorf("failed to get AI provider: %w", err)
 	}
 
+	key := EmbeddingCacheKey(providerType, model, text)
+	if cached := s.lookup(ctx, []string{key}); cached[key] != nil {
+		return cached[key], nil
+	}
+
 	req := &EmbeddingRequest{
 		Text:  text,
 		Model: model,
@@ -67,25 +92,125 @@ func (s *EmbeddingService) GenerateEmbeddingWithProvider(
 		return nil, fmt.Errorf("failed to generate embedding: %w", err)
 	}
 
+	s.store(ctx, model, map[string][]float64{key: resp.Embedding})
 	return resp.Embedding, nil
 }
 
 // GenerateBatchEmbeddings generates embeddings for multiple texts
 func (s *EmbeddingService) GenerateBatchEmbeddings(ctx context.Context, texts []string) ([][]float64, error) {
-	embeddings := make([][]float64, len(texts))
+	result, err := s.EmbedBatch(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	return result.Embeddings, nil
+}
 
-	// Generate embeddings one by one (could be optimized with batch API if provider supports it)
+// EmbedBatch generates embeddings for multiple texts with the default provider.
+// Cached texts are not sent again, repeated texts are embedded once and the rest
+// goes through the provider batch API when it has one.
+func (s *EmbeddingService) EmbedBatch(ctx context.Context, texts []string) (*EmbeddingBatchResult, error) {
+	providerType, model := s.config.DefaultProvider, s.config.DefaultModel
+	provider, err := s.aiFactory.Get(providerType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get AI provider: %w", err)
+	}
+
+	result := &EmbeddingBatchResult{Embeddings: make([][]float64, len(texts))}
+	keys := make([]string, len(texts))
 	for i, text := range texts {
-		embedding, err := s.GenerateEmbedding(ctx, text)
+		keys[i] = EmbeddingCacheKey(providerType, model, text)
+	}
+	cached := s.lookup(ctx, keys)
+
+	// Texts to embed, once per distinct content
+	var pending, pendingKeys []string
+	seen := make(map[string]bool)
+	for i, key := range keys {
+		if embedding, ok := cached[key]; ok {
+			result.Embeddings[i] = embedding
+			result.CacheHits++
+			continue
+		}
+		if !seen[key] {
+			seen[key] = true
+			pending = append(pending, texts[i])
+			pendingKeys = append(pendingKeys, key)
+		}
+	}
+
+	batchSize := s.config.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultEmbeddingBatchSize
+	}
+	generated := make(map[string][]float64, len(pending))
+	for start := 0; start < len(pending); start += batchSize {
+		end := min(start+batchSize, len(pending))
+		embeddings, err := s.embedChunk(ctx, provider, model, pending[start:end])
 		if err != nil {
-			return nil, fmt.Errorf("failed to generate embedding for text %d: %w", i, err)
+			// Keep what was generated so a retry does not pay for it again
+			s.store(ctx, model, generated)
+			return nil, err
+		}
+		for i, embedding := range embeddings {
+			generated[pendingKeys[start+i]] = embedding
 		}
-		embeddings[i] = embedding
 	}
+	s.store(ctx, model, generated)
 
+	for i, key := range keys {
+		if result.Embeddings[i] == nil {
+			result.Embeddings[i] = generated[key]
+		}
+	}
+	result.Generated = len(generated)
+	return result, nil
+}
+
+// embedChunk embeds texts with the provider batch API, or one by one without it
+func (s *EmbeddingService) embedChunk(ctx context.Context, provider AIProvider, model string, texts []string) ([][]float64, error) {
+	if batcher, ok := provider.(BatchEmbeddingProvider); ok {
+		resp, err := batcher.EmbedBatch(ctx, &BatchEmbeddingRequest{Texts: texts, Model: model})
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate batch embeddings: %w", err)
+		}
+		if len(resp.Embeddings) != len(texts) {
+			return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(resp.Embeddings))
+		}
+		return resp.Embeddings, nil
+	}
+
+	embeddings := make([][]float64, len(texts))
+	for i, text := range texts {
+		resp, err := provider.Embed(ctx, &EmbeddingRequest{Text: text, Model: model})
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate embedding for text %d: %w", i, err)
+		}
+		embeddings[i] = resp.Embedding
+	}
 	return embeddings, nil
 }
 
+// lookup reads the cache; failures count as misses
+func (s *EmbeddingService) lookup(ctx context.Context, keys []string) map[string][]float64 {
+	if s.cache == nil {
+		return nil
+	}
+	cached, err := s.cache.GetMany(ctx, keys)
+	if err != nil {
+		logger.Warn("Failed to read embedding cache", zap.Error(err))
+	}
+	return cached
+}
+
+func (s *EmbeddingService) store(ctx context.Context, model string, embeddings map[string][]float64) {
+	if s.cache == nil || len(embeddings) == 0 {
+		return
+	}
+	if err := s.cache.SetMany(ctx, model, embeddings); err != nil {
+		logger.Warn("Failed to write embedding cache", zap.Error(err))
+	}
+}
+
 // GetDimensions returns the embedding dimensions for the current provider/model
 func (s *EmbeddingService) GetDimensions() int {
 	return s.config.Dimensions
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Embedding cache settings
+const (
+	embeddingCachePrefix    = "linktor:embedding:"
+	DefaultEmbeddingTTL     = 7 * 24 * time.Hour  // In Redis
+	EmbeddingCacheRetention = 90 * 24 * time.Hour // In PostgreSQL
+)
+
+// EmbeddingCache stores embeddings by the hash of the content they were generated from
+type EmbeddingCache interface {
+	// GetMany returns the cached embeddings of the given keys; misses are left out
+	GetMany(ctx context.Context, keys []string) (map[string][]float64, error)
+
+	// SetMany caches embeddings generated with a model
+	SetMany(ctx context.Context, model string, embeddings map[string][]float64) error
+}
+
+// EmbeddingCacheKey returns the cache key of the embedding of a text generated by
+// a provider and model
+func EmbeddingCacheKey(providerType entity.AIProviderType, model, text string) string {
+	sum := sha256.Sum256([]byte(string(providerType) + "\x00" + model + "\x00" + text))
+	return hex.EncodeToString(sum[:])
+}
+
+// TieredEmbeddingCache keeps embeddings in PostgreSQL and, when a Redis client is
+// set, in Redis in front of it
+type TieredEmbeddingCache struct {
+	repo  repository.EmbeddingCacheRepository
+	redis *redis.Client
+	ttl   time.Duration
+}
+
+// NewTieredEmbeddingCache creates an embedding cache. redisClient may be nil.
+func NewTieredEmbeddingCache(repo repository.EmbeddingCacheRepository, redisClient *redis.Client, ttl time.Duration) *TieredEmbeddingCache {
+	if ttl <= 0 {
+		ttl = DefaultEmbeddingTTL
+	}
+	return &TieredEmbeddingCache{
+		repo:  repo,
+		redis: redisClient,
+		ttl:   ttl,
+	}
+}
+
+// GetMany returns the cached embeddings of the given keys, reading PostgreSQL for
+// Redis misses and copying what it finds there back to Redis
+func (c *TieredEmbeddingCache) GetMany(ctx context.Context, keys []string) (map[string][]float64, error) {
+	result := make(map[string][]float64, len(keys))
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	missing := keys
+	if c.redis != nil {
+		missing = c.getFromRedis(ctx, keys, result)
+	}
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	stored, err := c.repo.FindMany(ctx, missing)
+	if err != nil {
+		return result, err
+	}
+	for key, embedding := range stored {
+		result[key] = embedding
+	}
+	if c.redis != nil && len(stored) > 0 {
+		c.setInRedis(ctx, stored)
+	}
+	return result, nil
+}
+
+// SetMany caches embeddings in PostgreSQL and Redis
+func (c *TieredEmbeddingCache) SetMany(ctx context.Context, model string, embeddings map[string][]float64) error {
+	if len(embeddings) == 0 {
+		return nil
+	}
+	if c.redis != nil {
+		c.setInRedis(ctx, embeddings)
+	}
+	return c.repo.SaveMany(ctx, model, embeddings)
+}
+
+// Prune removes embeddings cached in PostgreSQL longer than the retention
+func (c *TieredEmbeddingCache) Prune(ctx context.Context) (int64, error) {
+	return c.repo.DeleteOlderThan(ctx, time.Now().Add(-EmbeddingCacheRetention))
+}
+
+// getFromRedis fills result with the embeddings found in Redis and returns the
+// keys it did not find. Redis errors count as misses.
+func (c *TieredEmbeddingCache) getFromRedis(ctx context.Context, keys []string, result map[string][]float64) []string {
+	redisKeys := make([]string, len(keys))
+	for i, key := range keys {
+		redisKeys[i] = embeddingCachePrefix + key
+	}
+
+	values, err := c.redis.MGet(ctx, redisKeys...).Result()
+	if err != nil {
+		logger.Warn("Failed to read embedding cache", zap.Error(err))
+		return keys
+	}
+
+	var missing []string
+	for i, value := range values {
+		data, ok := value.(string)
+		var embedding []float64
+		if !ok || json.Unmarshal([]byte(data), &embedding) != nil {
+			missing = append(missing, keys[i])
+			continue
+		}
+		result[keys[i]] = embedding
+	}
+	return missing
+}
+
+func (c *TieredEmbeddingCache) setInRedis(ctx context.Context, embeddings map[string][]float64) {
+	pipe := c.redis.Pipeline()
+	for key, embedding := range embeddings {
+		data, err := json.Marshal(embedding)
+		if err != nil {
+			continue
+		}
+		pipe.Set(ctx, embeddingCachePrefix+key, data, c.ttl)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Warn("Failed to write embedding cache", zap.Error(err))
+	}
+}
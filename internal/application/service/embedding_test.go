@@ -1,7 +1,9 @@
 package service
 
 import (
+	"context"
 	"math"
+	"sync"
 	"testing"
 
 	"github.com/msgfy/linktor/internal/domain/entity"
@@ -153,3 +155,106 @@ func TestSqrt(t *testing.T) {
 		})
 	}
 }
+
+// batchEmbeddingProvider embeds a text as its length and records the requests it gets
+type batchEmbeddingProvider struct {
+	testAIProvider
+	batches [][]string
+	single  []string
+}
+
+func (p *batchEmbeddingProvider) Embed(ctx context.Context, req *EmbeddingRequest) (*EmbeddingResponse, error) {
+	p.single = append(p.single, req.Text)
+	return &EmbeddingResponse{Embedding: []float64{float64(len(req.Text))}}, nil
+}
+
+func (p *batchEmbeddingProvider) EmbedBatch(ctx context.Context, req *BatchEmbeddingRequest) (*BatchEmbeddingResponse, error) {
+	p.batches = append(p.batches, req.Texts)
+	embeddings := make([][]float64, len(req.Texts))
+	for i, text := range req.Texts {
+		embeddings[i] = []float64{float64(len(text))}
+	}
+	return &BatchEmbeddingResponse{Embeddings: embeddings}, nil
+}
+
+// memoryEmbeddingCache is an in-memory EmbeddingCache
+type memoryEmbeddingCache struct {
+	mu      sync.Mutex
+	entries map[string][]float64
+}
+
+func (c *memoryEmbeddingCache) GetMany(ctx context.Context, keys []string) (map[string][]float64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result := make(map[string][]float64)
+	for _, key := range keys {
+		if embedding, ok := c.entries[key]; ok {
+			result[key] = embedding
+		}
+	}
+	return result, nil
+}
+
+func (c *memoryEmbeddingCache) SetMany(ctx context.Context, model string, embeddings map[string][]float64) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, embedding := range embeddings {
+		c.entries[key] = embedding
+	}
+	return nil
+}
+
+func newBatchEmbeddingService(batchSize int) (*EmbeddingService, *batchEmbeddingProvider, *memoryEmbeddingCache) {
+	provider := &batchEmbeddingProvider{testAIProvider: testAIProvider{name: entity.AIProviderOpenAI, available: true}}
+	factory := NewAIProviderFactory()
+	factory.Register(provider)
+
+	cfg := DefaultEmbeddingConfig()
+	cfg.BatchSize = batchSize
+	svc := NewEmbeddingService(factory, cfg)
+	cache := &memoryEmbeddingCache{entries: make(map[string][]float64)}
+	svc.SetCache(cache)
+	return svc, provider, cache
+}
+
+func TestEmbeddingService_EmbedBatch(t *testing.T) {
+	svc, provider, _ := newBatchEmbeddingService(2)
+	ctx := context.Background()
+
+	result, err := svc.EmbedBatch(ctx, []string{"a", "bb", "a", "ccc"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float64{{1}, {2}, {1}, {3}}, result.Embeddings)
+	assert.Equal(t, 3, result.Generated, "repeated texts are embedded once")
+	assert.Equal(t, 0, result.CacheHits)
+	assert.Equal(t, [][]string{{"a", "bb"}, {"ccc"}}, provider.batches, "texts are sent in batches")
+
+	result, err = svc.EmbedBatch(ctx, []string{"bb", "dddd"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float64{{2}, {4}}, result.Embeddings)
+	assert.Equal(t, 1, result.CacheHits)
+	assert.Equal(t, 1, result.Generated)
+	assert.Equal(t, []string{"dddd"}, provider.batches[2], "cached texts are not sent again")
+
+	embedding, err := svc.GenerateEmbedding(ctx, "ccc")
+	require.NoError(t, err)
+	assert.Equal(t, []float64{3}, embedding)
+	assert.Empty(t, provider.single, "single embeddings use the cache too")
+}
+
+func TestEmbeddingService_EmbedBatch_WithoutBatchAPI(t *testing.T) {
+	factory := NewAIProviderFactory()
+	factory.Register(&testAIProvider{name: entity.AIProviderOpenAI, available: true})
+	svc := NewEmbeddingService(factory, nil)
+
+	embeddings, err := svc.GenerateBatchEmbeddings(context.Background(), []string{"a", "b"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float64{{0.1, 0.2}, {0.1, 0.2}}, embeddings)
+}
+
+func TestEmbeddingCacheKey(t *testing.T) {
+	key := EmbeddingCacheKey(entity.AIProviderOpenAI, "text-embedding-ada-002", "hello")
+	assert.Len(t, key, 64)
+	assert.Equal(t, key, EmbeddingCacheKey(entity.AIProviderOpenAI, "text-embedding-ada-002", "hello"))
+	assert.NotEqual(t, key, EmbeddingCacheKey(entity.AIProviderOpenAI, "text-embedding-3-small", "hello"))
+	assert.NotEqual(t, key, EmbeddingCacheKey(entity.AIProviderOllama, "text-embedding-ada-002", "hello"))
+}
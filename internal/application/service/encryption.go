@@ -0,0 +1,460 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/encryption"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// encryptionActiveKeyTTL bounds how long the active key of a tenant is cached.
+// After a rotation other servers may keep sealing with the previous key for this
+// long, which is harmless since retired keys still decrypt.
+const encryptionActiveKeyTTL = 30 * time.Second
+
+// EncryptionKeyInput represents input for enabling encryption or rotating the key of a tenant
+type EncryptionKeyInput struct {
+	Provider       entity.EncryptionKeyProvider `json:"provider"`                  // Defaults to platform on enable and to the current provider on rotate
+	KeyMaterial    string                       `json:"key_material,omitempty"`    // Base64 encoded 32 byte key for the tenant provider
+	KMSKeyRef      string                       `json:"kms_key_ref,omitempty"`     // Transit key name for the vault provider
+	RewrapExisting bool                         `json:"rewrap_existing,omitempty"` // Rotate only: re-wrap older data keys with the new key encryption key
+}
+
+type cachedDataKey struct {
+	tenantID string
+	key      []byte
+}
+
+type cachedActiveKey struct {
+	keyID   string // Empty when the tenant has no active key
+	expires time.Time
+}
+
+// EncryptionService manages per-tenant envelope encryption of message content
+// and attachments. It implements encryption.Cipher for the repositories, which
+// seal values with the tenant's active data key on write and open them on read.
+//
+// Rotation creates a new data key version and retires the previous one; data
+// written under retired keys stays readable. When the key encryption key
+// changes, older data keys can be re-wrapped so the previous tenant key or KMS
+// key can be discarded.
+type EncryptionService struct {
+	repo   repository.EncryptionKeyRepository
+	master encryption.KeyWrapper
+	kms    encryption.KeyWrapper
+
+	mu       sync.RWMutex
+	dataKeys map[string]*cachedDataKey
+	active   map[string]*cachedActiveKey
+}
+
+// NewEncryptionService creates a new encryption service. The master wrapper
+// protects platform managed keys and keys supplied by tenants; without it only
+// the external KMS provider is available.
+func NewEncryptionService(repo repository.EncryptionKeyRepository, master encryption.KeyWrapper) *EncryptionService {
+	return &EncryptionService{
+		repo:     repo,
+		master:   master,
+		dataKeys: make(map[string]*cachedDataKey),
+		active:   make(map[string]*cachedActiveKey),
+	}
+}
+
+// SetKMS enables wrapping tenant keys with an external KMS
+func (s *EncryptionService) SetKMS(kms encryption.KeyWrapper) {
+	s.kms = kms
+}
+
+// Providers returns the key providers available on this server
+func (s *EncryptionService) Providers() []string {
+	providers := []string{}
+	if s.master != nil {
+		providers = append(providers, string(entity.EncryptionProviderPlatform), string(entity.EncryptionProviderTenant))
+	}
+	if s.kms != nil {
+		providers = append(providers, string(entity.EncryptionProviderVault))
+	}
+	return providers
+}
+
+// Status returns the encryption status and key versions of a tenant
+func (s *EncryptionService) Status(ctx context.Context, tenantID string) (*entity.TenantEncryptionStatus, error) {
+	keys, err := s.repo.ListByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &entity.TenantEncryptionStatus{Keys: keys, Providers: s.Providers()}
+	if status.Keys == nil {
+		status.Keys = []*entity.TenantEncryptionKey{}
+	}
+	for _, key := range keys {
+		if key.Status == entity.EncryptionKeyActive {
+			status.Enabled = true
+			status.ActiveKey = key
+		}
+	}
+	return status, nil
+}
+
+// Enable turns on encryption for a tenant with a first data key
+func (s *EncryptionService) Enable(ctx context.Context, tenantID string, input *EncryptionKeyInput) (*entity.TenantEncryptionKey, error) {
+	if _, err := s.repo.FindActive(ctx, tenantID); err == nil {
+		return nil, errors.New(errors.ErrCodeConflict, "encryption is already enabled; rotate the key instead")
+	} else if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	if input.Provider == "" {
+		input.Provider = entity.EncryptionProviderPlatform
+	}
+	key, err := s.newKey(ctx, tenantID, input, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, key); err != nil {
+		return nil, err
+	}
+
+	s.forgetActive(tenantID)
+	return key, nil
+}
+
+// Rotate replaces the active data key of a tenant with a new version. Supplying
+// a new provider, tenant key or KMS key changes the key encryption key.
+func (s *EncryptionService) Rotate(ctx context.Context, tenantID string, input *EncryptionKeyInput) (*entity.TenantEncryptionKey, error) {
+	current, err := s.repo.FindActive(ctx, tenantID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, errors.Validation("encryption is not enabled")
+		}
+		return nil, err
+	}
+
+	if input.Provider == "" {
+		input.Provider = current.Provider
+	}
+	key, err := s.newKey(ctx, tenantID, input, current)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only one key may be active, so the current key is retired first and
+	// restored if the new one cannot be stored
+	now := time.Now()
+	current.Status = entity.EncryptionKeyRetired
+	current.RetiredAt = &now
+	if err := s.repo.Update(ctx, current); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, key); err != nil {
+		current.Status = entity.EncryptionKeyActive
+		current.RetiredAt = nil
+		s.repo.Update(ctx, current)
+		return nil, err
+	}
+	s.forgetActive(tenantID)
+
+	if input.RewrapExisting {
+		if err := s.rewrap(ctx, tenantID, key); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// Disable stops encrypting new data for a tenant. Existing data stays encrypted
+// and readable with the retired keys.
+func (s *EncryptionService) Disable(ctx context.Context, tenantID string) error {
+	current, err := s.repo.FindActive(ctx, tenantID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return errors.Validation("encryption is not enabled")
+		}
+		return err
+	}
+
+	now := time.Now()
+	current.Status = entity.EncryptionKeyRetired
+	current.RetiredAt = &now
+	if err := s.repo.Update(ctx, current); err != nil {
+		return err
+	}
+	s.forgetActive(tenantID)
+	return nil
+}
+
+// Encrypt seals a value with the active key of the tenant, or returns it
+// unchanged when encryption is not enabled
+func (s *EncryptionService) Encrypt(ctx context.Context, tenantID, plaintext string) (string, error) {
+	if plaintext == "" || tenantID == "" {
+		return plaintext, nil
+	}
+
+	keyID, err := s.activeKeyID(ctx, tenantID)
+	if err != nil || keyID == "" {
+		return plaintext, err
+	}
+	dataKey, err := s.dataKey(ctx, keyID)
+	if err != nil {
+		return "", err
+	}
+	return encryption.Seal(dataKey.key, keyID, plaintext)
+}
+
+// Decrypt opens a value sealed by Encrypt. In a tenant scoped context, values
+// sealed with another tenant's keys are refused.
+func (s *EncryptionService) Decrypt(ctx context.Context, value string) (string, error) {
+	if !encryption.IsEncrypted(value) {
+		return value, nil
+	}
+
+	keyID, err := encryption.KeyID(value)
+	if err != nil {
+		return "", errors.Wrap(err, errors.ErrCodeInternal, "failed to decrypt value")
+	}
+	dataKey, err := s.dataKey(ctx, keyID)
+	if err != nil {
+		return "", err
+	}
+	if scope := encryption.TenantFromContext(ctx); scope != "" && scope != dataKey.tenantID {
+		return "", errors.New(errors.ErrCodeForbidden, "encrypted value belongs to another tenant")
+	}
+
+	plaintext, err := encryption.Open(dataKey.key, value)
+	if err != nil {
+		return "", errors.Wrap(err, errors.ErrCodeInternal, "failed to decrypt value")
+	}
+	return plaintext, nil
+}
+
+// newKey generates a data key and wraps it as requested by the input. The
+// previous key supplies the tenant key when rotating without new key material.
+func (s *EncryptionService) newKey(ctx context.Context, tenantID string, input *EncryptionKeyInput, previous *entity.TenantEncryptionKey) (*entity.TenantEncryptionKey, error) {
+	if !input.Provider.IsValid() {
+		return nil, errors.Validation("provider must be platform, tenant or vault")
+	}
+
+	version := 1
+	if keys, err := s.repo.ListByTenant(ctx, tenantID); err != nil {
+		return nil, err
+	} else if len(keys) > 0 {
+		version = keys[0].Version + 1
+	}
+
+	key := &entity.TenantEncryptionKey{
+		ID:        uuid.New().String(),
+		TenantID:  tenantID,
+		Version:   version,
+		Provider:  input.Provider,
+		Status:    entity.EncryptionKeyActive,
+		CreatedAt: time.Now(),
+	}
+	if err := s.setKeyEncryptionKey(ctx, key, input, previous); err != nil {
+		return nil, err
+	}
+
+	dataKey, err := encryption.GenerateKey()
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to generate data key")
+	}
+	if err := s.wrapDataKey(ctx, key, dataKey); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// setKeyEncryptionKey records on a key which key encryption key wraps it
+func (s *EncryptionService) setKeyEncryptionKey(ctx context.Context, key *entity.TenantEncryptionKey, input *EncryptionKeyInput, previous *entity.TenantEncryptionKey) error {
+	switch input.Provider {
+	case entity.EncryptionProviderPlatform:
+		if s.master == nil {
+			return errors.New(errors.ErrCodeBadRequest, "platform encryption is not configured on this server")
+		}
+
+	case entity.EncryptionProviderTenant:
+		if s.master == nil {
+			return errors.New(errors.ErrCodeBadRequest, "tenant keys are not supported on this server")
+		}
+		if input.KeyMaterial == "" {
+			if previous == nil || previous.Provider != entity.EncryptionProviderTenant {
+				return errors.Validation("key_material is required for the tenant provider")
+			}
+			key.WrappedTenantKey = previous.WrappedTenantKey
+			key.KeyFingerprint = previous.KeyFingerprint
+			return nil
+		}
+		material, err := base64.StdEncoding.DecodeString(strings.TrimSpace(input.KeyMaterial))
+		if err != nil || len(material) != encryption.KeySize {
+			return errors.Validation("key_material must be a base64 encoded 32 byte key")
+		}
+		wrapped, err := s.master.Wrap(ctx, key.TenantID, material)
+		if err != nil {
+			return errors.Wrap(err, errors.ErrCodeInternal, "failed to protect tenant key")
+		}
+		sum := sha256.Sum256(material)
+		key.WrappedTenantKey = wrapped
+		key.KeyFingerprint = hex.EncodeToString(sum[:8])
+
+	case entity.EncryptionProviderVault:
+		if s.kms == nil {
+			return errors.New(errors.ErrCodeBadRequest, "external KMS is not configured on this server")
+		}
+		key.KMSKeyRef = input.KMSKeyRef
+		if key.KMSKeyRef == "" && previous != nil && previous.Provider == entity.EncryptionProviderVault {
+			key.KMSKeyRef = previous.KMSKeyRef
+		}
+		if key.KMSKeyRef == "" {
+			return errors.Validation("kms_key_ref is required for the vault provider")
+		}
+	}
+	return nil
+}
+
+// keyWrapper returns the wrapper of a key's key encryption key and the
+// reference bound to its wrapped data key
+func (s *EncryptionService) keyWrapper(ctx context.Context, key *entity.TenantEncryptionKey) (encryption.KeyWrapper, string, error) {
+	switch key.Provider {
+	case entity.EncryptionProviderPlatform:
+		if s.master != nil {
+			return s.master, key.TenantID, nil
+		}
+	case entity.EncryptionProviderTenant:
+		if s.master != nil {
+			material, err := s.master.Unwrap(ctx, key.TenantID, key.WrappedTenantKey)
+			if err != nil {
+				return nil, "", errors.Wrap(err, errors.ErrCodeInternal, "failed to unwrap tenant key")
+			}
+			wrapper, err := encryption.NewAESWrapper(material)
+			if err != nil {
+				return nil, "", errors.Wrap(err, errors.ErrCodeInternal, "invalid tenant key")
+			}
+			return wrapper, key.TenantID, nil
+		}
+	case entity.EncryptionProviderVault:
+		if s.kms != nil {
+			return s.kms, key.KMSKeyRef, nil
+		}
+	}
+	return nil, "", errors.New(errors.ErrCodeInternal, "key provider "+string(key.Provider)+" is not configured on this server")
+}
+
+func (s *EncryptionService) wrapDataKey(ctx context.Context, key *entity.TenantEncryptionKey, dataKey []byte) error {
+	wrapper, keyRef, err := s.keyWrapper(ctx, key)
+	if err != nil {
+		return err
+	}
+	wrapped, err := wrapper.Wrap(ctx, keyRef, dataKey)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to wrap data key")
+	}
+	key.WrappedKey = wrapped
+	return nil
+}
+
+func (s *EncryptionService) unwrapDataKey(ctx context.Context, key *entity.TenantEncryptionKey) ([]byte, error) {
+	wrapper, keyRef, err := s.keyWrapper(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := wrapper.Unwrap(ctx, keyRef, key.WrappedKey)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unwrap data key")
+	}
+	return dataKey, nil
+}
+
+// rewrap re-wraps the older data keys of a tenant with the key encryption key of
+// the given key, leaving the data they sealed untouched
+func (s *EncryptionService) rewrap(ctx context.Context, tenantID string, target *entity.TenantEncryptionKey) error {
+	keys, err := s.repo.ListByTenant(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if key.ID == target.ID {
+			continue
+		}
+		dataKey, err := s.unwrapDataKey(ctx, key)
+		if err != nil {
+			return err
+		}
+		key.Provider = target.Provider
+		key.KMSKeyRef = target.KMSKeyRef
+		key.KeyFingerprint = target.KeyFingerprint
+		key.WrappedTenantKey = target.WrappedTenantKey
+		if err := s.wrapDataKey(ctx, key, dataKey); err != nil {
+			return err
+		}
+		if err := s.repo.Update(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// activeKeyID returns the ID of the tenant's active key, empty when encryption
+// is not enabled
+func (s *EncryptionService) activeKeyID(ctx context.Context, tenantID string) (string, error) {
+	s.mu.RLock()
+	cached, ok := s.active[tenantID]
+	s.mu.RUnlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.keyID, nil
+	}
+
+	keyID := ""
+	key, err := s.repo.FindActive(ctx, tenantID)
+	if err != nil && !errors.IsNotFound(err) {
+		return "", err
+	}
+	if key != nil {
+		keyID = key.ID
+	}
+
+	s.mu.Lock()
+	s.active[tenantID] = &cachedActiveKey{keyID: keyID, expires: time.Now().Add(encryptionActiveKeyTTL)}
+	s.mu.Unlock()
+	return keyID, nil
+}
+
+// dataKey returns the unwrapped data key of a key ID. Data keys never change
+// once created, so they are cached for the life of the process.
+func (s *EncryptionService) dataKey(ctx context.Context, keyID string) (*cachedDataKey, error) {
+	s.mu.RLock()
+	cached, ok := s.dataKeys[keyID]
+	s.mu.RUnlock()
+	if ok {
+		return cached, nil
+	}
+
+	key, err := s.repo.FindByID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	dataKey, err := s.unwrapDataKey(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	cached = &cachedDataKey{tenantID: key.TenantID, key: dataKey}
+	s.mu.Lock()
+	s.dataKeys[keyID] = cached
+	s.mu.Unlock()
+	return cached, nil
+}
+
+func (s *EncryptionService) forgetActive(tenantID string) {
+	s.mu.Lock()
+	delete(s.active, tenantID)
+	s.mu.Unlock()
+}
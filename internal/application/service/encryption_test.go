@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"sort"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/infrastructure/encryption"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockEncryptionKeyRepo struct {
+	keys map[string]*entity.TenantEncryptionKey
+}
+
+func newMockEncryptionKeyRepo() *mockEncryptionKeyRepo {
+	return &mockEncryptionKeyRepo{keys: make(map[string]*entity.TenantEncryptionKey)}
+}
+
+func (m *mockEncryptionKeyRepo) Create(ctx context.Context, key *entity.TenantEncryptionKey) error {
+	copied := *key
+	m.keys[key.ID] = &copied
+	return nil
+}
+
+func (m *mockEncryptionKeyRepo) FindByID(ctx context.Context, id string) (*entity.TenantEncryptionKey, error) {
+	if key, ok := m.keys[id]; ok {
+		copied := *key
+		return &copied, nil
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "encryption key not found")
+}
+
+func (m *mockEncryptionKeyRepo) FindActive(ctx context.Context, tenantID string) (*entity.TenantEncryptionKey, error) {
+	for _, key := range m.keys {
+		if key.TenantID == tenantID && key.Status == entity.EncryptionKeyActive {
+			copied := *key
+			return &copied, nil
+		}
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "encryption key not found")
+}
+
+func (m *mockEncryptionKeyRepo) ListByTenant(ctx context.Context, tenantID string) ([]*entity.TenantEncryptionKey, error) {
+	var keys []*entity.TenantEncryptionKey
+	for _, key := range m.keys {
+		if key.TenantID == tenantID {
+			copied := *key
+			keys = append(keys, &copied)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Version > keys[j].Version })
+	return keys, nil
+}
+
+func (m *mockEncryptionKeyRepo) Update(ctx context.Context, key *entity.TenantEncryptionKey) error {
+	copied := *key
+	m.keys[key.ID] = &copied
+	return nil
+}
+
+func newEncryptionTestService(t *testing.T) (*EncryptionService, *mockEncryptionKeyRepo) {
+	masterKey, err := encryption.GenerateKey()
+	require.NoError(t, err)
+	master, err := encryption.NewAESWrapper(masterKey)
+	require.NoError(t, err)
+
+	repo := newMockEncryptionKeyRepo()
+	return NewEncryptionService(repo, master), repo
+}
+
+func tenantKeyMaterial(t *testing.T) string {
+	key, err := encryption.GenerateKey()
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func TestEncryptionDisabledPassesValuesThrough(t *testing.T) {
+	svc, _ := newEncryptionTestService(t)
+	ctx := context.Background()
+
+	value, err := svc.Encrypt(ctx, "tenant-1", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", value)
+
+	value, err = svc.Decrypt(ctx, "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", value)
+}
+
+func TestEncryptionEnableAndTenantScope(t *testing.T) {
+	svc, _ := newEncryptionTestService(t)
+	ctx := context.Background()
+
+	key, err := svc.Enable(ctx, "tenant-1", &EncryptionKeyInput{})
+	require.NoError(t, err)
+	assert.Equal(t, entity.EncryptionProviderPlatform, key.Provider)
+	assert.Equal(t, 1, key.Version)
+
+	_, err = svc.Enable(ctx, "tenant-1", &EncryptionKeyInput{})
+	assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code)
+
+	sealed, err := svc.Encrypt(ctx, "tenant-1", "card ending 4242")
+	require.NoError(t, err)
+	assert.True(t, encryption.IsEncrypted(sealed))
+
+	opened, err := svc.Decrypt(encryption.WithTenant(ctx, "tenant-1"), sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "card ending 4242", opened)
+
+	// Background work without a tenant scope can still read the value
+	opened, err = svc.Decrypt(ctx, sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "card ending 4242", opened)
+
+	_, err = svc.Decrypt(encryption.WithTenant(ctx, "tenant-2"), sealed)
+	assert.Equal(t, errors.ErrCodeForbidden, errors.GetAppError(err).Code)
+
+	// Other tenants are not affected
+	plain, err := svc.Encrypt(ctx, "tenant-2", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "hello", plain)
+}
+
+func TestEncryptionRotateKeepsOldDataReadable(t *testing.T) {
+	svc, repo := newEncryptionTestService(t)
+	ctx := context.Background()
+
+	first, err := svc.Enable(ctx, "tenant-1", &EncryptionKeyInput{Provider: entity.EncryptionProviderTenant, KeyMaterial: tenantKeyMaterial(t)})
+	require.NoError(t, err)
+	assert.NotEmpty(t, first.KeyFingerprint)
+	oldValue, err := svc.Encrypt(ctx, "tenant-1", "before rotation")
+	require.NoError(t, err)
+
+	// Rotating without new material keeps the tenant key
+	second, err := svc.Rotate(ctx, "tenant-1", &EncryptionKeyInput{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, second.Version)
+	assert.Equal(t, first.KeyFingerprint, second.KeyFingerprint)
+	assert.Equal(t, entity.EncryptionKeyRetired, repo.keys[first.ID].Status)
+
+	newValue, err := svc.Encrypt(ctx, "tenant-1", "after rotation")
+	require.NoError(t, err)
+	newKeyID, _ := encryption.KeyID(newValue)
+	assert.Equal(t, second.ID, newKeyID)
+
+	// A new tenant key with rewrap moves every version to it
+	third, err := svc.Rotate(ctx, "tenant-1", &EncryptionKeyInput{KeyMaterial: tenantKeyMaterial(t), RewrapExisting: true})
+	require.NoError(t, err)
+	assert.NotEqual(t, first.KeyFingerprint, third.KeyFingerprint)
+	for _, key := range repo.keys {
+		assert.Equal(t, third.KeyFingerprint, key.KeyFingerprint)
+	}
+
+	// A fresh process reads every version through the re-wrapped keys
+	fresh := NewEncryptionService(repo, svc.master)
+	for value, expected := range map[string]string{oldValue: "before rotation", newValue: "after rotation"} {
+		opened, err := fresh.Decrypt(ctx, value)
+		require.NoError(t, err)
+		assert.Equal(t, expected, opened)
+	}
+
+	status, err := svc.Status(ctx, "tenant-1")
+	require.NoError(t, err)
+	assert.True(t, status.Enabled)
+	assert.Equal(t, third.ID, status.ActiveKey.ID)
+	assert.Len(t, status.Keys, 3)
+}
+
+func TestEncryptionProviderValidation(t *testing.T) {
+	svc, _ := newEncryptionTestService(t)
+	ctx := context.Background()
+
+	_, err := svc.Enable(ctx, "tenant-1", &EncryptionKeyInput{Provider: entity.EncryptionProviderTenant})
+	assert.True(t, errors.IsValidation(err))
+
+	_, err = svc.Enable(ctx, "tenant-1", &EncryptionKeyInput{Provider: entity.EncryptionProviderTenant, KeyMaterial: "c2hvcnQ="})
+	assert.True(t, errors.IsValidation(err))
+
+	_, err = svc.Enable(ctx, "tenant-1", &EncryptionKeyInput{Provider: entity.EncryptionProviderVault, KMSKeyRef: "tenant-1"})
+	assert.Equal(t, errors.ErrCodeBadRequest, errors.GetAppError(err).Code)
+
+	_, err = svc.Rotate(ctx, "tenant-1", &EncryptionKeyInput{})
+	assert.True(t, errors.IsValidation(err))
+	assert.Equal(t, []string{"platform", "tenant"}, svc.Providers())
+}
+
+func TestEncryptionDisable(t *testing.T) {
+	svc, _ := newEncryptionTestService(t)
+	ctx := context.Background()
+
+	_, err := svc.Enable(ctx, "tenant-1", &EncryptionKeyInput{})
+	require.NoError(t, err)
+	sealed, err := svc.Encrypt(ctx, "tenant-1", "secret")
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Disable(ctx, "tenant-1"))
+
+	plain, err := svc.Encrypt(ctx, "tenant-1", "public")
+	require.NoError(t, err)
+	assert.Equal(t, "public", plain)
+
+	opened, err := svc.Decrypt(ctx, sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", opened)
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/infrastructure/egress"
+	"github.com/msgfy/linktor/internal/infrastructure/webhook"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// Timeouts of external bot requests, in seconds
+const (
+	DefaultExternalBotTimeout = 10
+	MaxExternalBotTimeout     = 30
+)
+
+// maxExternalBotResponse caps the response body read from an external bot
+const maxExternalBotResponse = 256 * 1024
+
+// ExternalBotRequest is an inbound message forwarded to an external bot
+type ExternalBotRequest struct {
+	TenantID       string            `json:"tenant_id"`
+	BotID          string            `json:"bot_id"`
+	ConversationID string            `json:"conversation_id"` // Identifies the sender to the bot, keeping its state per conversation
+	ChannelID      string            `json:"channel_id"`
+	MessageID      string            `json:"message_id"`
+	Text           string            `json:"text"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+}
+
+// ExternalBotReply is the answer of an external bot: the messages to send back, in
+// order, and whether the conversation goes to an agent
+type ExternalBotReply struct {
+	Messages     []string            `json:"messages"`
+	QuickReplies []entity.QuickReply `json:"quick_replies,omitempty"`
+	Escalate     bool                `json:"escalate"`
+	Reason       string              `json:"reason,omitempty"`
+}
+
+// Text returns the messages of the reply as a single text, separated by blank lines
+func (r *ExternalBotReply) Text() string {
+	return strings.Join(r.Messages, "\n\n")
+}
+
+// ExternalBotService forwards the messages of conversations answered by an external
+// bot to its Rasa, Botpress or custom endpoint and translates the answer back
+type ExternalBotService struct {
+	httpClient *http.Client
+}
+
+// NewExternalBotService creates a new external bot service
+func NewExternalBotService() *ExternalBotService {
+	return &ExternalBotService{httpClient: egress.NewClient()}
+}
+
+// SetHTTPClient replaces the client external bots are called with, which refuses
+// internal addresses, e.g. to reach local test servers
+func (s *ExternalBotService) SetHTTPClient(client *http.Client) {
+	s.httpClient = client
+}
+
+// ValidateExternalBotConfig checks the endpoint of an external bot, which must not
+// point to an internal address
+func ValidateExternalBotConfig(ctx context.Context, config *entity.ExternalBotConfig) error {
+	if err := checkExternalBotConfig(config); err != nil {
+		return err
+	}
+	if err := egress.CheckURL(ctx, config.URL); err != nil {
+		return errors.Validation("external url must not point to a private, loopback or link-local address")
+	}
+	return nil
+}
+
+// checkExternalBotConfig checks the framework, endpoint and timeout of an external bot
+func checkExternalBotConfig(config *entity.ExternalBotConfig) error {
+	if config == nil {
+		return errors.Validation("external bots require an external config")
+	}
+	switch config.Framework {
+	case entity.ExternalBotRasa, entity.ExternalBotBotpress, entity.ExternalBotCustom:
+	default:
+		return errors.Validation("external framework must be rasa, botpress or custom")
+	}
+	if !isWebhookURL(config.URL) {
+		return errors.Validation("external url must be an http or https URL")
+	}
+	if config.TimeoutSeconds < 0 || config.TimeoutSeconds > MaxExternalBotTimeout {
+		return errors.Validation(fmt.Sprintf("external timeout_seconds must be between 1 and %d", MaxExternalBotTimeout))
+	}
+	return nil
+}
+
+// Reply sends a message to the external bot of a bot and returns its answer. Timeouts,
+// transport errors and non-2xx responses are errors, for the caller to fall back on.
+func (s *ExternalBotService) Reply(ctx context.Context, bot *entity.Bot, req *ExternalBotRequest) (*ExternalBotReply, error) {
+	config := bot.Config.External
+	if err := checkExternalBotConfig(config); err != nil {
+		return nil, err
+	}
+
+	endpoint := config.URL
+	var payload interface{}
+	switch config.Framework {
+	case entity.ExternalBotRasa:
+		payload = map[string]interface{}{
+			"sender":   req.ConversationID,
+			"message":  req.Text,
+			"metadata": externalBotMetadata(req),
+		}
+	case entity.ExternalBotBotpress:
+		// The converse API keeps a session per user ID in its path
+		endpoint = strings.TrimRight(endpoint, "/") + "/" + url.PathEscape(req.ConversationID)
+		payload = map[string]interface{}{
+			"type":     "text",
+			"text":     req.Text,
+			"metadata": externalBotMetadata(req),
+		}
+	default:
+		payload = req
+	}
+
+	body, err := s.call(ctx, config, endpoint, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	switch config.Framework {
+	case entity.ExternalBotRasa:
+		return parseRasaReply(body)
+	case entity.ExternalBotBotpress:
+		return parseBotpressReply(body)
+	default:
+		var reply ExternalBotReply
+		if err := json.Unmarshal(body, &reply); err != nil {
+			return nil, fmt.Errorf("invalid external bot response: %w", err)
+		}
+		return &reply, nil
+	}
+}
+
+// call posts a payload to the external bot and returns the response body
+func (s *ExternalBotService) call(ctx context.Context, config *entity.ExternalBotConfig, endpoint string, payload interface{}) ([]byte, error) {
+	timeout := config.TimeoutSeconds
+	if timeout == 0 {
+		timeout = DefaultExternalBotTimeout
+	}
+	callCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(callCtx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+	for name, value := range config.Headers {
+		httpReq.Header.Set(name, value)
+	}
+	if config.Secret != "" {
+		httpReq.Header.Set(webhook.HeaderSignature, webhook.Sign(config.Secret, data))
+	}
+
+	resp, err := s.httpClient.Do(httpReq)
+	if err != nil {
+		if ctx.Err() == nil && stderrors.Is(err, context.DeadlineExceeded) {
+			return nil, fmt.Errorf("no response within %ds", timeout)
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxExternalBotResponse))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("external bot returned HTTP %d", resp.StatusCode)
+	}
+	return body, nil
+}
+
+// externalBotMetadata is the context sent along with the text to Rasa and Botpress
+func externalBotMetadata(req *ExternalBotRequest) map[string]interface{} {
+	metadata := map[string]interface{}{
+		"tenant_id":       req.TenantID,
+		"bot_id":          req.BotID,
+		"conversation_id": req.ConversationID,
+		"channel_id":      req.ChannelID,
+		"message_id":      req.MessageID,
+	}
+	for key, value := range req.Metadata {
+		if _, ok := metadata[key]; !ok {
+			metadata[key] = value
+		}
+	}
+	return metadata
+}
+
+// parseRasaReply reads the answer of the Rasa REST channel. A custom payload with
+// handoff set to true hands the conversation to an agent.
+func parseRasaReply(body []byte) (*ExternalBotReply, error) {
+	var messages []struct {
+		Text    string `json:"text"`
+		Image   string `json:"image"`
+		Buttons []struct {
+			Title   string `json:"title"`
+			Payload string `json:"payload"`
+		} `json:"buttons"`
+		Custom map[string]interface{} `json:"custom"`
+	}
+	if err := json.Unmarshal(body, &messages); err != nil {
+		return nil, fmt.Errorf("invalid rasa response: %w", err)
+	}
+
+	reply := &ExternalBotReply{}
+	for _, message := range messages {
+		if message.Text != "" {
+			reply.Messages = append(reply.Messages, message.Text)
+		}
+		if message.Image != "" {
+			reply.Messages = append(reply.Messages, message.Image)
+		}
+		for _, button := range message.Buttons {
+			reply.QuickReplies = append(reply.QuickReplies, entity.QuickReply{
+				ID:    button.Payload,
+				Title: button.Title,
+				Value: button.Payload,
+			})
+		}
+		if handoff, _ := message.Custom["handoff"].(bool); handoff {
+			reply.Escalate = true
+			reply.Reason, _ = message.Custom["reason"].(string)
+		}
+	}
+	return reply, nil
+}
+
+// parseBotpressReply reads the answer of the Botpress converse API. A response of
+// type handoff hands the conversation to an agent.
+func parseBotpressReply(body []byte) (*ExternalBotReply, error) {
+	var result struct {
+		Responses []struct {
+			Type    string `json:"type"`
+			Text    string `json:"text"`
+			Reason  string `json:"reason"`
+			Choices []struct {
+				Title string `json:"title"`
+				Value string `json:"value"`
+			} `json:"choices"`
+		} `json:"responses"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("invalid botpress response: %w", err)
+	}
+
+	reply := &ExternalBotReply{}
+	for _, response := range result.Responses {
+		if response.Type == "handoff" {
+			reply.Escalate = true
+			reply.Reason = response.Reason
+			continue
+		}
+		if response.Text != "" {
+			reply.Messages = append(reply.Messages, response.Text)
+		}
+		for _, choice := range response.Choices {
+			reply.QuickReplies = append(reply.QuickReplies, entity.QuickReply{
+				ID:    choice.Value,
+				Title: choice.Title,
+				Value: choice.Value,
+			})
+		}
+	}
+	return reply, nil
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/infrastructure/webhook"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateExternalBotConfig(t *testing.T) {
+	ctx := context.Background()
+	assert.Error(t, ValidateExternalBotConfig(ctx, nil))
+	assert.Error(t, ValidateExternalBotConfig(ctx, &entity.ExternalBotConfig{Framework: "dialogflow", URL: "https://bot.example.com"}))
+	assert.Error(t, ValidateExternalBotConfig(ctx, &entity.ExternalBotConfig{Framework: entity.ExternalBotRasa, URL: "bot.example.com"}))
+	assert.Error(t, ValidateExternalBotConfig(ctx, &entity.ExternalBotConfig{Framework: entity.ExternalBotRasa, URL: "https://bot.example.com", TimeoutSeconds: 31}))
+	assert.NoError(t, ValidateExternalBotConfig(ctx, &entity.ExternalBotConfig{Framework: entity.ExternalBotCustom, URL: "https://bot.example.com"}))
+
+	for _, url := range []string{"http://169.254.169.254/latest/meta-data/", "http://127.0.0.1:5005/webhooks/rest/webhook", "http://10.0.0.5/bot"} {
+		err := ValidateExternalBotConfig(ctx, &entity.ExternalBotConfig{Framework: entity.ExternalBotRasa, URL: url})
+		assert.True(t, errors.IsValidation(err), url)
+	}
+}
+
+func TestExternalBotService_Reply(t *testing.T) {
+	var path, signature, token string
+	var body map[string]interface{}
+	var response string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		signature = r.Header.Get(webhook.HeaderSignature)
+		token = r.Header.Get("Authorization")
+		data, _ := io.ReadAll(r.Body)
+		body = nil
+		require.NoError(t, json.Unmarshal(data, &body))
+		_, _ = w.Write([]byte(response))
+	}))
+	defer server.Close()
+
+	s := NewExternalBotService()
+	s.SetHTTPClient(server.Client())
+	req := &ExternalBotRequest{TenantID: "tenant-1", BotID: "bot-1", ConversationID: "conv-1", MessageID: "msg-1", Text: "hi"}
+
+	t.Run("botpress", func(t *testing.T) {
+		response = `{"responses":[{"type":"text","text":"Hi there"},{"type":"single-choice","text":"Pick one","choices":[{"title":"Sales","value":"sales"}]},{"type":"handoff","reason":"asked for a human"}]}`
+		bot := &entity.Bot{Config: entity.BotConfig{External: &entity.ExternalBotConfig{
+			Framework: entity.ExternalBotBotpress,
+			URL:       server.URL + "/api/v1/bots/support/converse/",
+			Headers:   map[string]string{"Authorization": "Bearer bp"},
+		}}}
+
+		reply, err := s.Reply(context.Background(), bot, req)
+		require.NoError(t, err)
+		assert.Equal(t, "/api/v1/bots/support/converse/conv-1", path)
+		assert.Equal(t, "Bearer bp", token)
+		assert.Equal(t, "hi", body["text"])
+		assert.Equal(t, "Hi there\n\nPick one", reply.Text())
+		assert.Equal(t, []entity.QuickReply{{ID: "sales", Title: "Sales", Value: "sales"}}, reply.QuickReplies)
+		assert.True(t, reply.Escalate)
+		assert.Equal(t, "asked for a human", reply.Reason)
+		assert.Empty(t, signature)
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		response = `{"messages":["Your order shipped"],"escalate":false}`
+		bot := &entity.Bot{Config: entity.BotConfig{External: &entity.ExternalBotConfig{
+			Framework: entity.ExternalBotCustom,
+			URL:       server.URL + "/linktor",
+			Secret:    "s3cret",
+		}}}
+
+		reply, err := s.Reply(context.Background(), bot, req)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Your order shipped"}, reply.Messages)
+		assert.Equal(t, "conv-1", body["conversation_id"])
+		assert.Equal(t, "msg-1", body["message_id"])
+
+		payload, _ := json.Marshal(req)
+		assert.Equal(t, webhook.Sign("s3cret", payload), signature)
+	})
+
+	t.Run("invalid response", func(t *testing.T) {
+		response = `not json`
+		bot := &entity.Bot{Config: entity.BotConfig{External: &entity.ExternalBotConfig{Framework: entity.ExternalBotRasa, URL: server.URL}}}
+		_, err := s.Reply(context.Background(), bot, req)
+		assert.Error(t, err)
+	})
+}
+
+func TestExternalBotService_RefusesInternalEndpoints(t *testing.T) {
+	// Bots saved before the check are refused when called
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the request must not reach the server")
+	}))
+	defer server.Close()
+
+	bot := &entity.Bot{Config: entity.BotConfig{External: &entity.ExternalBotConfig{Framework: entity.ExternalBotCustom, URL: server.URL}}}
+	_, err := NewExternalBotService().Reply(context.Background(), bot, &ExternalBotRequest{ConversationID: "conv-1", Text: "hi"})
+	assert.Error(t, err)
+}
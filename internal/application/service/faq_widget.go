@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// FAQWidgetKnowledgeBaseConfig is the webchat channel setting naming the knowledge
+// base the FAQ widget answers from. Without it the knowledge base of the channel's
+// bot is used.
+const FAQWidgetKnowledgeBaseConfig = "faq_knowledge_base_id"
+
+const (
+	maxFAQQuestionLength  = 500
+	maxFAQSessionIDLength = 128
+	faqSearchLimit        = 3
+	faqNoAnswer           = "NO_ANSWER"
+)
+
+const faqWidgetPrompt = `Answer the visitor's question using only the help articles below.
+Keep the answer short and friendly. If the articles do not answer the question, reply with exactly %s.
+
+Help articles:
+%s
+Question: %s`
+
+// FAQSource is a knowledge base article an FAQ answer is based on
+type FAQSource struct {
+	ID       string `json:"id"`
+	Question string `json:"question"`
+}
+
+// FAQAnswer is the answer to a visitor question. Answered is false when the
+// knowledge base has nothing on the question, in which case the widget offers to
+// talk to a person.
+type FAQAnswer struct {
+	Question string      `json:"question"`
+	Answer   string      `json:"answer,omitempty"`
+	Answered bool        `json:"answered"`
+	Sources  []FAQSource `json:"sources"`
+}
+
+// FAQEscalationInput hands a visitor over from the FAQ widget to a webchat conversation
+type FAQEscalationInput struct {
+	SessionID string `json:"session_id" binding:"required"` // Webchat session ID, shared with the chat widget
+	Question  string `json:"question" binding:"required"`
+	Answer    string `json:"answer"` // The answer the visitor was shown, if any
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+}
+
+// FAQEscalation acknowledges an escalation; the conversation is created
+// asynchronously by the inbound pipeline
+type FAQEscalation struct {
+	SessionID string `json:"session_id"`
+}
+
+// FAQWidgetService answers the questions of anonymous website visitors from the
+// knowledge base of a webchat channel, without creating conversations, and turns
+// the question into a webchat conversation when the visitor asks for a person
+type FAQWidgetService struct {
+	channelRepo      repository.ChannelRepository
+	botRepo          repository.BotRepository
+	knowledgeService *KnowledgeService
+	aiFactory        *AIProviderFactory
+	producer         nats.Publisher
+	now              func() time.Time
+}
+
+// NewFAQWidgetService creates a new FAQ widget service
+func NewFAQWidgetService(
+	channelRepo repository.ChannelRepository,
+	botRepo repository.BotRepository,
+	knowledgeService *KnowledgeService,
+	aiFactory *AIProviderFactory,
+	producer nats.Publisher,
+) *FAQWidgetService {
+	return &FAQWidgetService{
+		channelRepo:      channelRepo,
+		botRepo:          botRepo,
+		knowledgeService: knowledgeService,
+		aiFactory:        aiFactory,
+		producer:         producer,
+		now:              time.Now,
+	}
+}
+
+// Ask answers a visitor question from the channel's knowledge base. The answer is
+// written by the AI from the best matching articles when a provider is available,
+// and is the best matching article's answer otherwise.
+func (s *FAQWidgetService) Ask(ctx context.Context, channelID, question string) (*FAQAnswer, error) {
+	question = strings.TrimSpace(question)
+	if question == "" {
+		return nil, errors.Validation("question is required")
+	}
+	if utf8.RuneCountInString(question) > maxFAQQuestionLength {
+		return nil, errors.Validation(fmt.Sprintf("question must be at most %d characters", maxFAQQuestionLength))
+	}
+
+	channel, kb, bot, err := s.resolve(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	botID := ""
+	if bot != nil {
+		botID = bot.ID
+	}
+	ctx = WithAIUsage(ctx, channel.TenantID, botID, entity.AIFeatureFAQ)
+
+	results, err := s.knowledgeService.Search(ctx, channel.TenantID, kb.ID, question, faqSearchLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FAQAnswer{Question: question, Sources: []FAQSource{}}
+	var articles strings.Builder
+	for _, match := range results {
+		if match.Item == nil {
+			continue
+		}
+		result.Sources = append(result.Sources, FAQSource{ID: match.Item.ID, Question: match.Item.Question})
+		fmt.Fprintf(&articles, "Q: %s\nA: %s\n\n", match.Item.Question, match.Item.Answer)
+	}
+	if len(result.Sources) == 0 {
+		return result, nil
+	}
+
+	answer, err := s.generate(ctx, bot, question, articles.String())
+	if err != nil {
+		logger.Warn("FAQ widget answer generation failed, using the best match",
+			zap.String("channel_id", channel.ID), zap.Error(err))
+		answer = results[0].Item.Answer
+	}
+	if answer == "" || strings.Contains(answer, faqNoAnswer) {
+		result.Sources = []FAQSource{}
+		return result, nil
+	}
+
+	result.Answer = answer
+	result.Answered = true
+	return result, nil
+}
+
+// Escalate queues the visitor's question as the first message of a webchat
+// conversation, with the answer they were shown, so an agent picks up with the
+// context. The session ID is the chat widget's, so the visitor continues the
+// conversation in the chat widget.
+func (s *FAQWidgetService) Escalate(ctx context.Context, channelID string, input *FAQEscalationInput) (*FAQEscalation, error) {
+	sessionID := strings.TrimSpace(input.SessionID)
+	if sessionID == "" {
+		return nil, errors.Validation("session ID is required")
+	}
+	if len(sessionID) > maxFAQSessionIDLength {
+		return nil, errors.Validation("session ID is too long")
+	}
+	question := strings.TrimSpace(input.Question)
+	if question == "" {
+		return nil, errors.Validation("question is required")
+	}
+	if utf8.RuneCountInString(question) > maxFAQQuestionLength {
+		return nil, errors.Validation(fmt.Sprintf("question must be at most %d characters", maxFAQQuestionLength))
+	}
+
+	channel, _, _, err := s.resolve(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		name = "Visitor"
+	}
+	metadata := map[string]string{
+		"sender_id":   sessionID,
+		"sender_name": name,
+		"session_id":  sessionID,
+		"source":      "faq_widget",
+	}
+	if email := strings.TrimSpace(input.Email); email != "" {
+		metadata["email"] = email
+	}
+	if answer := strings.TrimSpace(input.Answer); answer != "" {
+		metadata["faq_answer"] = answer
+	}
+
+	inbound := &nats.InboundMessage{
+		ID:          uuid.New().String(),
+		TenantID:    channel.TenantID,
+		ChannelID:   channel.ID,
+		ChannelType: string(entity.ChannelTypeWebChat),
+		ExternalID:  uuid.New().String(),
+		ContentType: string(entity.ContentTypeText),
+		Content:     question,
+		Metadata:    metadata,
+		Timestamp:   s.now(),
+	}
+	if err := s.producer.PublishInbound(ctx, inbound); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to queue message")
+	}
+
+	return &FAQEscalation{SessionID: sessionID}, nil
+}
+
+// resolve loads the webchat channel and the knowledge base the widget answers from
+func (s *FAQWidgetService) resolve(ctx context.Context, channelID string) (*entity.Channel, *entity.KnowledgeBase, *entity.Bot, error) {
+	channel, err := s.channelRepo.FindByID(ctx, channelID)
+	if err != nil || channel.Type != entity.ChannelTypeWebChat {
+		return nil, nil, nil, errors.New(errors.ErrCodeChannelNotFound, "channel not found")
+	}
+	if !channel.IsEnabled() {
+		return nil, nil, nil, errors.Forbidden("channel is disabled")
+	}
+
+	bot, _ := s.botRepo.FindByChannel(ctx, channel.ID)
+	if bot != nil && bot.TenantID != channel.TenantID {
+		bot = nil
+	}
+
+	kbID := channel.Config[FAQWidgetKnowledgeBaseConfig]
+	if kbID == "" && bot != nil && bot.Config.KnowledgeBaseID != nil {
+		kbID = *bot.Config.KnowledgeBaseID
+	}
+	if kbID == "" {
+		return nil, nil, nil, errors.Forbidden("the FAQ widget is not enabled on this channel")
+	}
+
+	kb, err := s.knowledgeService.KnowledgeBaseForTenant(ctx, channel.TenantID, kbID)
+	if err != nil || kb.Status == entity.KnowledgeStatusInactive {
+		return nil, nil, nil, errors.Forbidden("the FAQ widget is not enabled on this channel")
+	}
+	return channel, kb, bot, nil
+}
+
+// generate writes the answer from the matching articles with the bot's provider,
+// or the first available one
+func (s *FAQWidgetService) generate(ctx context.Context, bot *entity.Bot, question, articles string) (string, error) {
+	var provider AIProvider
+	model := ""
+	if bot != nil && s.aiFactory != nil {
+		if p, err := s.aiFactory.GetForBot(bot); err == nil {
+			provider, model = p, bot.Model
+		}
+	}
+	if provider == nil {
+		p, err := s.aiFactory.FirstAvailable()
+		if err != nil {
+			return "", err
+		}
+		provider = p
+	}
+	if model == "" {
+		model = provider.DefaultModel()
+	}
+
+	resp, err := provider.Complete(ctx, &CompletionRequest{
+		Messages: []Message{
+			{Role: "system", Content: "You answer website visitors' questions from a company's help articles."},
+			{Role: "user", Content: fmt.Sprintf(faqWidgetPrompt, faqNoAnswer, articles, question)},
+		},
+		Model:       model,
+		MaxTokens:   400,
+		Temperature: 0.2,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, errors.ErrCodeInternal, "failed to generate FAQ answer")
+	}
+	return strings.TrimSpace(resp.Content), nil
+}
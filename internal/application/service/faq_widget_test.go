@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type faqWidgetTestEnv struct {
+	svc      *FAQWidgetService
+	channel  *entity.Channel
+	bots     *MockBotRepository
+	kbs      *mockKnowledgeBaseRepo
+	items    *mockKnowledgeItemRepo
+	ai       *extractingAIProvider
+	producer *testutil.MockProducer
+}
+
+func newFAQWidgetTestEnv() *faqWidgetTestEnv {
+	channels := testutil.NewMockChannelRepository()
+	channel := entity.NewChannel("tenant-1", entity.ChannelTypeWebChat, "Website", "site")
+	channel.ID = "channel-1"
+	channel.Enabled = true
+	channels.Channels[channel.ID] = channel
+
+	kbs := newMockKnowledgeBaseRepo()
+	kb := entity.NewKnowledgeBase("tenant-1", "Help center", entity.KnowledgeTypeFAQ)
+	kb.ID = "kb-1"
+	kbs.bases[kb.ID] = kb
+
+	items := newMockKnowledgeItemRepo()
+	items.items["item-1"] = &entity.KnowledgeItem{
+		ID: "item-1", KnowledgeBaseID: "kb-1",
+		Question: "How long do refunds take?", Answer: "Refunds are paid within 5 business days.",
+	}
+
+	kbID := kb.ID
+	bots := NewMockBotRepository()
+	bots.Bots["bot-1"] = &entity.Bot{
+		ID: "bot-1", TenantID: "tenant-1", Provider: entity.AIProviderOpenAI, Model: "gpt-4o-mini",
+		Config: entity.BotConfig{KnowledgeBaseID: &kbID},
+	}
+	bots.ChannelBotMap[channel.ID] = "bot-1"
+
+	ai := &extractingAIProvider{mockAIProvider: mockAIProvider{name: entity.AIProviderOpenAI, available: true}}
+	ai.reply = "Your refund arrives within 5 business days."
+	factory := NewAIProviderFactory()
+	factory.Register(ai)
+
+	producer := testutil.NewMockProducer()
+	svc := NewFAQWidgetService(channels, bots, NewKnowledgeService(kbs, items, nil, nil), factory, producer)
+
+	return &faqWidgetTestEnv{svc: svc, channel: channel, bots: bots, kbs: kbs, items: items, ai: ai, producer: producer}
+}
+
+func TestFAQWidgetService_Ask(t *testing.T) {
+	env := newFAQWidgetTestEnv()
+
+	answer, err := env.svc.Ask(context.Background(), "channel-1", "  refunds how long?  ")
+	require.NoError(t, err)
+
+	assert.True(t, answer.Answered)
+	assert.Equal(t, "refunds how long?", answer.Question)
+	assert.Equal(t, "Your refund arrives within 5 business days.", answer.Answer)
+	assert.Equal(t, []FAQSource{{ID: "item-1", Question: "How long do refunds take?"}}, answer.Sources)
+	assert.Contains(t, env.ai.prompt, "A: Refunds are paid within 5 business days.")
+	assert.Contains(t, env.ai.prompt, "Question: refunds how long?")
+	assert.Empty(t, env.producer.InboundMessages)
+}
+
+func TestFAQWidgetService_AskNoAnswer(t *testing.T) {
+	env := newFAQWidgetTestEnv()
+	env.ai.reply = faqNoAnswer
+
+	answer, err := env.svc.Ask(context.Background(), "channel-1", "Do you ship to Mars?")
+	require.NoError(t, err)
+	assert.False(t, answer.Answered)
+	assert.Empty(t, answer.Answer)
+	assert.Empty(t, answer.Sources)
+
+	delete(env.items.items, "item-1")
+	answer, err = env.svc.Ask(context.Background(), "channel-1", "Do you ship to Mars?")
+	require.NoError(t, err)
+	assert.False(t, answer.Answered)
+}
+
+func TestFAQWidgetService_AskWithoutAI(t *testing.T) {
+	env := newFAQWidgetTestEnv()
+	env.ai.available = false
+
+	answer, err := env.svc.Ask(context.Background(), "channel-1", "refunds")
+	require.NoError(t, err)
+	assert.True(t, answer.Answered)
+	assert.Equal(t, "Refunds are paid within 5 business days.", answer.Answer)
+}
+
+func TestFAQWidgetService_AskChannelKnowledgeBase(t *testing.T) {
+	env := newFAQWidgetTestEnv()
+	delete(env.bots.ChannelBotMap, "channel-1")
+
+	_, err := env.svc.Ask(context.Background(), "channel-1", "refunds")
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeForbidden, errors.GetAppError(err).Code)
+
+	env.channel.Config[FAQWidgetKnowledgeBaseConfig] = "kb-1"
+	answer, err := env.svc.Ask(context.Background(), "channel-1", "refunds")
+	require.NoError(t, err)
+	assert.True(t, answer.Answered)
+}
+
+func TestFAQWidgetService_AskRejected(t *testing.T) {
+	env := newFAQWidgetTestEnv()
+	ctx := context.Background()
+
+	_, err := env.svc.Ask(ctx, "channel-1", " ")
+	assert.Equal(t, errors.ErrCodeValidation, errors.GetAppError(err).Code)
+
+	_, err = env.svc.Ask(ctx, "missing", "refunds")
+	assert.Equal(t, errors.ErrCodeChannelNotFound, errors.GetAppError(err).Code)
+
+	other := entity.NewKnowledgeBase("tenant-2", "Other", entity.KnowledgeTypeFAQ)
+	other.ID = "kb-2"
+	env.kbs.bases[other.ID] = other
+	env.channel.Config[FAQWidgetKnowledgeBaseConfig] = "kb-2"
+	_, err = env.svc.Ask(ctx, "channel-1", "refunds")
+	assert.Equal(t, errors.ErrCodeForbidden, errors.GetAppError(err).Code)
+
+	env.channel.Config[FAQWidgetKnowledgeBaseConfig] = "kb-1"
+	env.channel.Enabled = false
+	_, err = env.svc.Ask(ctx, "channel-1", "refunds")
+	assert.Equal(t, errors.ErrCodeForbidden, errors.GetAppError(err).Code)
+}
+
+func TestFAQWidgetService_Escalate(t *testing.T) {
+	env := newFAQWidgetTestEnv()
+
+	escalation, err := env.svc.Escalate(context.Background(), "channel-1", &FAQEscalationInput{
+		SessionID: "session-1",
+		Question:  "Where is my refund?",
+		Answer:    "Refunds are paid within 5 business days.",
+		Email:     "ana@example.com",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "session-1", escalation.SessionID)
+
+	require.Len(t, env.producer.InboundMessages, 1)
+	inbound := env.producer.InboundMessages[0]
+	assert.Equal(t, "tenant-1", inbound.TenantID)
+	assert.Equal(t, "channel-1", inbound.ChannelID)
+	assert.Equal(t, string(entity.ChannelTypeWebChat), inbound.ChannelType)
+	assert.Equal(t, "Where is my refund?", inbound.Content)
+	assert.Equal(t, "session-1", inbound.Metadata["sender_id"])
+	assert.Equal(t, "Visitor", inbound.Metadata["sender_name"])
+	assert.Equal(t, "ana@example.com", inbound.Metadata["email"])
+	assert.Equal(t, "faq_widget", inbound.Metadata["source"])
+	assert.Equal(t, "Refunds are paid within 5 business days.", inbound.Metadata["faq_answer"])
+
+	_, err = env.svc.Escalate(context.Background(), "channel-1", &FAQEscalationInput{SessionID: "session-1"})
+	assert.Equal(t, errors.ErrCodeValidation, errors.GetAppError(err).Code)
+}
@@ -4,17 +4,44 @@ import (
 	"context"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/msgfy/linktor/internal/domain/entity"
 	"github.com/msgfy/linktor/internal/domain/repository"
 	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
 )
 
+// VariableResolver provides the variables of a conversation to flow message templates
+type VariableResolver interface {
+	ResolveVariables(ctx context.Context, conversationID string) (map[string]string, error)
+}
+
+// SegmentChecker tells whether the contact of a conversation is in a contact segment
+type SegmentChecker interface {
+	ConversationInSegment(ctx context.Context, conversationID, segmentID string) (bool, error)
+}
+
+// ContactLoader returns the contact of a conversation
+type ContactLoader interface {
+	ConversationContact(ctx context.Context, conversationID string) (*entity.Contact, error)
+}
+
+// AutomationTracer stores snapshots of automation decisions for debugging
+type AutomationTracer interface {
+	Record(ctx context.Context, trace *entity.AutomationTrace) error
+}
+
 // FlowEngineService handles conversational flow execution
 type FlowEngineService struct {
-	flowRepo    repository.FlowRepository
-	contextRepo repository.ConversationContextRepository
+	flowRepo         repository.FlowRepository
+	contextRepo      repository.ConversationContextRepository
+	variableResolver VariableResolver
+	segmentChecker   SegmentChecker
+	contactLoader    ContactLoader
+	tracer           AutomationTracer
 }
 
 // NewFlowEngineService creates a new flow engine service
@@ -28,6 +55,72 @@ func NewFlowEngineService(
 	}
 }
 
+// SetVariableResolver makes contact, conversation and referral variables available to
+// flow message templates
+func (s *FlowEngineService) SetVariableResolver(resolver VariableResolver) {
+	s.variableResolver = resolver
+}
+
+// SetSegmentChecker lets condition nodes branch on the contact's segments
+func (s *FlowEngineService) SetSegmentChecker(checker SegmentChecker) {
+	s.segmentChecker = checker
+}
+
+// SetContactLoader lets condition nodes branch on the contact's custom fields
+func (s *FlowEngineService) SetContactLoader(loader ContactLoader) {
+	s.contactLoader = loader
+}
+
+// SetTracer records a trace of the nodes visited for every message a flow handles
+func (s *FlowEngineService) SetTracer(tracer AutomationTracer) {
+	s.tracer = tracer
+}
+
+// RecordTrace stores the execution of a flow on a message. Failures are logged, as
+// traces never block the conversation.
+func (s *FlowEngineService) RecordTrace(ctx context.Context, message *entity.Message, conversation *entity.Conversation, flowID string, result *entity.FlowExecutionResult, convContext *entity.ConversationContext) {
+	if s.tracer == nil || result == nil {
+		return
+	}
+
+	trace := &entity.AutomationTrace{
+		TenantID:       conversation.TenantID,
+		ConversationID: conversation.ID,
+		Source:         entity.AutomationTraceSourceFlow,
+		FlowID:         flowID,
+		Steps:          result.Trace,
+		Decision:       entity.AutomationDecisionRespond,
+	}
+	if message != nil {
+		trace.MessageID = message.ID
+	}
+	if convContext != nil && convContext.BotID != nil {
+		trace.BotID = *convContext.BotID
+	}
+	trace.ApplyContext(convContext)
+
+	switch {
+	case result.FlowEnded:
+		trace.Decision = entity.AutomationDecisionEnd
+	case result.ShouldWait:
+		trace.Decision = entity.AutomationDecisionWait
+	}
+	for _, action := range result.Actions {
+		if action.Type == entity.FlowActionEscalate {
+			trace.Decision = entity.AutomationDecisionEscalate
+			trace.EscalateReason = "Flow triggered escalation"
+		}
+	}
+
+	if err := s.tracer.Record(ctx, trace); err != nil {
+		logger.Warn("Failed to record flow trace",
+			zap.String("flow_id", flowID),
+			zap.String("conversation_id", conversation.ID),
+			zap.Error(err),
+		)
+	}
+}
+
 // CheckTrigger checks if any flow should be triggered by the message
 func (s *FlowEngineService) CheckTrigger(ctx context.Context, tenantID string, message string, convContext *entity.ConversationContext) (*entity.Flow, bool) {
 	// Check if there's already an active flow
@@ -124,44 +217,102 @@ func (s *FlowEngineService) ContinueFlow(ctx context.Context, tenantID string, u
 	}
 
 	// Process transition based on user input
-	nextNodeID := s.ProcessTransition(currentNode, userInput)
+	nextNodeID, conditions := s.evaluateTransitions(currentNode, userInput)
+	step := entity.FlowTraceStep{
+		NodeID:     currentNode.ID,
+		NodeType:   currentNode.Type,
+		UserInput:  userInput,
+		Conditions: conditions,
+		NextNodeID: nextNodeID,
+	}
+	nextNode := currentNode
 	if nextNodeID == "" {
 		// No valid transition, repeat current node or end flow
 		if currentNode.Type == entity.FlowNodeEnd {
 			s.ClearFlowState(convContext)
-			return &entity.FlowExecutionResult{FlowEnded: true}, nil
+			return &entity.FlowExecutionResult{FlowEnded: true, Trace: []entity.FlowTraceStep{step}}, nil
+		}
+	} else {
+		// Get next node
+		nextNode = flow.GetNode(nextNodeID)
+		if nextNode == nil {
+			s.ClearFlowState(convContext)
+			return nil, errors.New(errors.ErrCodeBadRequest, "next node not found: "+nextNodeID)
 		}
-		// Re-execute current node
-		return s.ExecuteNode(ctx, flow, currentNode, convContext, userInput)
+
+		// Update current node in state
+		convContext.State["current_node_id"] = nextNodeID
 	}
 
-	// Get next node
-	nextNode := flow.GetNode(nextNodeID)
-	if nextNode == nil {
-		s.ClearFlowState(convContext)
-		return nil, errors.New(errors.ErrCodeBadRequest, "next node not found: "+nextNodeID)
+	// Execute the next node, or re-execute the current one
+	result, err := s.ExecuteNode(ctx, flow, nextNode, convContext, userInput)
+	if err != nil {
+		return nil, err
 	}
+	result.Trace = append([]entity.FlowTraceStep{step}, result.Trace...)
+	return result, nil
+}
 
-	// Update current node in state
-	convContext.State["current_node_id"] = nextNodeID
+// ResumeAtNode moves the conversation to a node of a flow and executes it. It is used
+// when an interactive reply points at a specific node, whether or not the flow is
+// still active in the context. An empty nodeID resumes at the start node.
+func (s *FlowEngineService) ResumeAtNode(ctx context.Context, tenantID, flowID, nodeID, userInput string, convContext *entity.ConversationContext) (*entity.FlowExecutionResult, error) {
+	flow, err := s.flowRepo.FindByID(ctx, flowID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeNotFound, "flow not found")
+	}
+	if flow.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeNotFound, "flow not found")
+	}
 
-	// Execute the next node
-	return s.ExecuteNode(ctx, flow, nextNode, convContext, userInput)
+	if nodeID == "" {
+		nodeID = flow.StartNodeID
+	}
+	node := flow.GetNode(nodeID)
+	if node == nil {
+		return nil, errors.New(errors.ErrCodeBadRequest, "node not found: "+nodeID)
+	}
+
+	if convContext.State == nil {
+		convContext.State = make(map[string]interface{})
+	}
+	if activeFlowID, _ := convContext.State["active_flow_id"].(string); activeFlowID != flow.ID {
+		state := entity.NewFlowExecutionState(flow.ID, node.ID)
+		convContext.State["active_flow_id"] = flow.ID
+		convContext.State["flow_started_at"] = state.StartedAt
+		convContext.State["collected_data"] = state.CollectedData
+	}
+	convContext.State["current_node_id"] = node.ID
+
+	// The reply answers the message that carried the button, not the node being
+	// resumed; only condition nodes branch on it
+	if node.Type != entity.FlowNodeCondition {
+		userInput = ""
+	}
+
+	return s.ExecuteNode(ctx, flow, node, convContext, userInput)
 }
 
 // ExecuteNode executes a flow node and returns the result
 func (s *FlowEngineService) ExecuteNode(ctx context.Context, flow *entity.Flow, node *entity.FlowNode, convContext *entity.ConversationContext, userInput string) (*entity.FlowExecutionResult, error) {
 	result := &entity.FlowExecutionResult{}
+	step := entity.FlowTraceStep{NodeID: node.ID, NodeType: node.Type, UserInput: userInput}
 
 	// Store any collected data from user input
 	if userInput != "" && node.Type == entity.FlowNodeQuestion {
 		s.StoreCollectedData(convContext, node.ID, userInput)
 	}
 
+	vars := s.templateVariables(ctx, convContext)
+	render := func(template string) string {
+		rendered, _ := RenderTemplate(template, vars)
+		return rendered
+	}
+
 	switch node.Type {
 	case entity.FlowNodeMessage:
 		// Send message and continue to next node
-		result.Message = s.ProcessTemplate(node.Content, convContext)
+		result.Message = render(node.Content)
 		result.QuickReplies = node.QuickReplies
 		result.Actions = node.Actions
 
@@ -173,26 +324,43 @@ func (s *FlowEngineService) ExecuteNode(ctx context.Context, flow *entity.Flow,
 
 	case entity.FlowNodeQuestion:
 		// Send question and wait for user response
-		result.Message = s.ProcessTemplate(node.Content, convContext)
+		result.Message = render(node.Content)
 		result.QuickReplies = node.QuickReplies
 		result.ShouldWait = true
 
 	case entity.FlowNodeCondition:
 		// Evaluate condition and transition
-		nextNodeID := s.ProcessTransition(node, userInput)
+		nextNodeID, conditions := s.segmentTransition(ctx, node, convContext)
+		if nextNodeID == "" {
+			var fieldConditions []entity.FlowTraceCondition
+			nextNodeID, fieldConditions = s.contactFieldTransition(ctx, node, convContext)
+			conditions = append(conditions, fieldConditions...)
+		}
+		if nextNodeID == "" {
+			var inputConditions []entity.FlowTraceCondition
+			nextNodeID, inputConditions = s.evaluateTransitions(node, userInput)
+			conditions = append(conditions, inputConditions...)
+		}
+		step.Conditions = conditions
 		if nextNodeID != "" {
 			result.NextNodeID = nextNodeID
 			convContext.State["current_node_id"] = nextNodeID
 			// Execute the next node immediately
 			nextNode := flow.GetNode(nextNodeID)
 			if nextNode != nil {
-				return s.ExecuteNode(ctx, flow, nextNode, convContext, "")
+				nextResult, err := s.ExecuteNode(ctx, flow, nextNode, convContext, "")
+				if err != nil {
+					return nil, err
+				}
+				step.NextNodeID = nextNodeID
+				nextResult.Trace = append([]entity.FlowTraceStep{step}, nextResult.Trace...)
+				return nextResult, nil
 			}
 		}
 
 	case entity.FlowNodeAction:
 		// Execute actions
-		result.Message = s.ProcessTemplate(node.Content, convContext)
+		result.Message = render(node.Content)
 		result.Actions = node.Actions
 
 		// Continue to next node
@@ -208,15 +376,15 @@ func (s *FlowEngineService) ExecuteNode(ctx context.Context, flow *entity.Flow,
 		if node.VREConfig != nil {
 			// Store VRE config in result for the bot orchestrator to render
 			result.Message = node.VREConfig.TemplateID // Template ID for rendering
-			result.VRECaption = s.ProcessTemplate(node.VREConfig.Caption, convContext)
-			result.VREFollowUp = s.ProcessTemplate(node.VREConfig.FollowUpText, convContext)
+			result.VRECaption = render(node.VREConfig.Caption)
+			result.VREFollowUp = render(node.VREConfig.FollowUpText)
 
 			// Build template data from flow collected data using the mapping
 			templateData := make(map[string]interface{})
 			collected := s.GetCollectedData(convContext)
 			for templateKey, flowKey := range node.VREConfig.DataMapping {
 				// Process template syntax in the flow key (e.g., {{user_name}})
-				value := s.ProcessTemplate(flowKey, convContext)
+				value := render(flowKey)
 				if collected != nil && collected[flowKey] != "" {
 					templateData[templateKey] = collected[flowKey]
 				} else {
@@ -239,22 +407,40 @@ func (s *FlowEngineService) ExecuteNode(ctx context.Context, flow *entity.Flow,
 
 	case entity.FlowNodeEnd:
 		// End the flow
-		result.Message = s.ProcessTemplate(node.Content, convContext)
+		result.Message = render(node.Content)
 		result.FlowEnded = true
 		s.ClearFlowState(convContext)
 	}
 
+	step.NextNodeID = result.NextNodeID
+	result.Trace = append([]entity.FlowTraceStep{step}, result.Trace...)
 	return result, nil
 }
 
 // ProcessTransition determines the next node based on user input
 func (s *FlowEngineService) ProcessTransition(node *entity.FlowNode, userInput string) string {
+	nextNodeID, _ := s.evaluateTransitions(node, userInput)
+	return nextNodeID
+}
+
+// evaluateTransitions determines the next node based on user input and reports the
+// transitions evaluated on the way, for automation traces
+func (s *FlowEngineService) evaluateTransitions(node *entity.FlowNode, userInput string) (string, []entity.FlowTraceCondition) {
 	if len(node.Transitions) == 0 {
-		return ""
+		return "", nil
 	}
 
 	lowerInput := strings.ToLower(strings.TrimSpace(userInput))
-	var defaultTransition *entity.FlowTransition
+	var conditions []entity.FlowTraceCondition
+	defaultIndex := -1
+	evaluated := func(transition *entity.FlowTransition, matched bool) {
+		conditions = append(conditions, entity.FlowTraceCondition{
+			Condition: transition.Condition,
+			Value:     transition.Value,
+			ToNodeID:  transition.ToNodeID,
+			Matched:   matched,
+		})
+	}
 
 	// Sort by priority (higher first) would be ideal, but for now just iterate
 	for i := range node.Transitions {
@@ -262,41 +448,118 @@ func (s *FlowEngineService) ProcessTransition(node *entity.FlowNode, userInput s
 
 		switch transition.Condition {
 		case entity.TransitionConditionDefault:
-			defaultTransition = transition
+			defaultIndex = len(conditions)
+			evaluated(transition, false)
 
 		case entity.TransitionConditionReplyEquals:
 			// Check if user input matches exactly (case-insensitive)
-			if strings.EqualFold(lowerInput, strings.ToLower(transition.Value)) {
-				return transition.ToNodeID
-			}
+			matched := strings.EqualFold(lowerInput, strings.ToLower(transition.Value))
 			// Also check quick reply IDs
 			for _, qr := range node.QuickReplies {
+				if matched {
+					break
+				}
 				if strings.EqualFold(lowerInput, strings.ToLower(qr.ID)) ||
 					strings.EqualFold(lowerInput, strings.ToLower(qr.Title)) {
-					if strings.EqualFold(qr.ID, transition.Value) || strings.EqualFold(qr.Title, transition.Value) {
-						return transition.ToNodeID
-					}
+					matched = strings.EqualFold(qr.ID, transition.Value) || strings.EqualFold(qr.Title, transition.Value)
 				}
 			}
+			evaluated(transition, matched)
+			if matched {
+				return transition.ToNodeID, conditions
+			}
 
 		case entity.TransitionConditionContains:
-			if strings.Contains(lowerInput, strings.ToLower(transition.Value)) {
-				return transition.ToNodeID
+			matched := strings.Contains(lowerInput, strings.ToLower(transition.Value))
+			evaluated(transition, matched)
+			if matched {
+				return transition.ToNodeID, conditions
 			}
 
 		case entity.TransitionConditionRegex:
-			if matched, _ := regexp.MatchString(transition.Value, userInput); matched {
-				return transition.ToNodeID
+			matched, _ := regexp.MatchString(transition.Value, userInput)
+			evaluated(transition, matched)
+			if matched {
+				return transition.ToNodeID, conditions
 			}
 		}
 	}
 
 	// Return default transition if exists
-	if defaultTransition != nil {
-		return defaultTransition.ToNodeID
+	if defaultIndex >= 0 {
+		conditions[defaultIndex].Matched = true
+		return conditions[defaultIndex].ToNodeID, conditions
 	}
 
-	return ""
+	return "", conditions
+}
+
+// segmentTransition returns the first in_segment transition whose segment contains the
+// conversation's contact, with the segment checks made. Segments that cannot be checked
+// do not match.
+func (s *FlowEngineService) segmentTransition(ctx context.Context, node *entity.FlowNode, convContext *entity.ConversationContext) (string, []entity.FlowTraceCondition) {
+	if s.segmentChecker == nil || convContext == nil || convContext.ConversationID == "" {
+		return "", nil
+	}
+	var conditions []entity.FlowTraceCondition
+	for _, transition := range node.Transitions {
+		if transition.Condition != entity.TransitionConditionInSegment || transition.Value == "" {
+			continue
+		}
+		in, err := s.segmentChecker.ConversationInSegment(ctx, convContext.ConversationID, transition.Value)
+		matched := err == nil && in
+		conditions = append(conditions, entity.FlowTraceCondition{
+			Condition: transition.Condition,
+			Value:     transition.Value,
+			ToNodeID:  transition.ToNodeID,
+			Matched:   matched,
+		})
+		if matched {
+			return transition.ToNodeID, conditions
+		}
+	}
+	return "", conditions
+}
+
+// contactFieldTransition returns the first contact_field transition matching the custom
+// fields of the conversation's contact, with the comparisons made. Transitions are not
+// matched when the contact cannot be loaded.
+func (s *FlowEngineService) contactFieldTransition(ctx context.Context, node *entity.FlowNode, convContext *entity.ConversationContext) (string, []entity.FlowTraceCondition) {
+	if s.contactLoader == nil || convContext == nil || convContext.ConversationID == "" {
+		return "", nil
+	}
+	var contact *entity.Contact
+	loaded := false
+	var conditions []entity.FlowTraceCondition
+	for _, transition := range node.Transitions {
+		if transition.Condition != entity.TransitionConditionField || transition.Key == "" {
+			continue
+		}
+		if !loaded {
+			contact, _ = s.contactLoader.ConversationContact(ctx, convContext.ConversationID)
+			loaded = true
+		}
+		rule := entity.SegmentRule{
+			Field:    entity.SegmentFieldCustomField,
+			Operator: transition.Operator,
+			Key:      transition.Key,
+			Value:    transition.Value,
+		}
+		if rule.Operator == "" {
+			rule.Operator = entity.SegmentOpEquals
+		}
+		matched := contact != nil && rule.Validate() == nil && rule.Matches(contact, nil, time.Now())
+		conditions = append(conditions, entity.FlowTraceCondition{
+			Condition: transition.Condition,
+			Value:     transition.Key + " " + string(rule.Operator) + " " + transition.Value,
+			ToNodeID:  transition.ToNodeID,
+			Matched:   matched,
+		})
+		if matched {
+			return transition.ToNodeID, conditions
+		}
+	}
+	return "", conditions
 }
 
 // HasActiveFlow checks if there's an active flow in the context
@@ -354,34 +617,33 @@ func (s *FlowEngineService) GetCollectedData(convContext *entity.ConversationCon
 	return collected
 }
 
-// ProcessTemplate processes a message template with collected data
+// ProcessTemplate processes a message template with collected data, AI entities and,
+// when a variable resolver is configured, the conversation's variables
 func (s *FlowEngineService) ProcessTemplate(template string, convContext *entity.ConversationContext) string {
 	if template == "" || convContext == nil {
 		return template
 	}
 
-	result := template
+	rendered, _ := RenderTemplate(template, s.templateVariables(context.Background(), convContext))
+	return rendered
+}
 
-	// Replace {{key}} with collected data
-	collected := s.GetCollectedData(convContext)
-	if collected != nil {
-		for key, value := range collected {
-			placeholder := "{{" + key + "}}"
-			result = strings.ReplaceAll(result, placeholder, value)
-		}
+// templateVariables merges the conversation's variables with the in-memory context,
+// which may hold flow data not yet persisted
+func (s *FlowEngineService) templateVariables(ctx context.Context, convContext *entity.ConversationContext) map[string]string {
+	vars := make(map[string]string)
+	if convContext == nil {
+		return vars
 	}
 
-	// Replace entity values
-	if convContext.Entities != nil {
-		for key, value := range convContext.Entities {
-			placeholder := "{{entity." + key + "}}"
-			if strVal, ok := value.(string); ok {
-				result = strings.ReplaceAll(result, placeholder, strVal)
-			}
+	if s.variableResolver != nil && convContext.ConversationID != "" {
+		if resolved, err := s.variableResolver.ResolveVariables(ctx, convContext.ConversationID); err == nil {
+			vars = resolved
 		}
 	}
+	AddContextVariables(vars, convContext)
 
-	return result
+	return vars
 }
 
 // FlowService handles flow CRUD operations
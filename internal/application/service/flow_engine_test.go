@@ -450,6 +450,80 @@ func TestFlowEngine_ContinueFlow_FlowDeleted(t *testing.T) {
 	assert.False(t, hasFlow)
 }
 
+type recordingTracer struct {
+	traces []*entity.AutomationTrace
+}
+
+func (r *recordingTracer) Record(ctx context.Context, trace *entity.AutomationTrace) error {
+	r.traces = append(r.traces, trace)
+	return nil
+}
+
+func TestFlowEngine_ContinueFlow_Trace(t *testing.T) {
+	svc, flowRepo, _ := newFlowEngine()
+	flow := makeSimpleFlow("t-1")
+	flowRepo.flows[flow.ID] = flow
+
+	convCtx := &entity.ConversationContext{
+		State: map[string]interface{}{
+			"active_flow_id":  flow.ID,
+			"current_node_id": "q-1",
+		},
+	}
+
+	result, err := svc.ContinueFlow(context.Background(), "t-1", "no", convCtx)
+	require.NoError(t, err)
+	require.Len(t, result.Trace, 2)
+
+	input := result.Trace[0]
+	assert.Equal(t, "q-1", input.NodeID)
+	assert.Equal(t, "no", input.UserInput)
+	assert.Equal(t, "end-no", input.NextNodeID)
+	require.Len(t, input.Conditions, 2, "evaluation stops at the first match")
+	assert.False(t, input.Conditions[0].Matched)
+	assert.True(t, input.Conditions[1].Matched)
+
+	assert.Equal(t, "end-no", result.Trace[1].NodeID)
+	assert.Equal(t, entity.FlowNodeEnd, result.Trace[1].NodeType)
+}
+
+func TestFlowEngine_RecordTrace(t *testing.T) {
+	svc, _, _ := newFlowEngine()
+	tracer := &recordingTracer{}
+	svc.SetTracer(tracer)
+
+	flow := makeSimpleFlow("t-1")
+	botID := "bot-1"
+	convCtx := &entity.ConversationContext{
+		ConversationID: "conv-1",
+		BotID:          &botID,
+		Intent:         &entity.Intent{Name: "support", Confidence: 0.9},
+		Sentiment:      entity.SentimentNegative,
+		State:          map[string]interface{}{},
+	}
+
+	result, err := svc.StartFlow(context.Background(), flow, convCtx)
+	require.NoError(t, err)
+	svc.StoreCollectedData(convCtx, "q-1", "maybe")
+
+	message := &entity.Message{ID: "msg-1"}
+	conversation := &entity.Conversation{ID: "conv-1", TenantID: "t-1"}
+	svc.RecordTrace(context.Background(), message, conversation, flow.ID, result, convCtx)
+
+	require.Len(t, tracer.traces, 1)
+	trace := tracer.traces[0]
+	assert.Equal(t, entity.AutomationTraceSourceFlow, trace.Source)
+	assert.Equal(t, "msg-1", trace.MessageID)
+	assert.Equal(t, "bot-1", trace.BotID)
+	assert.Equal(t, "support", trace.Intent)
+	assert.Equal(t, 0.9, *trace.Confidence)
+	assert.Equal(t, "negative", trace.Sentiment)
+	assert.Equal(t, map[string]string{"q-1": "maybe"}, trace.Variables)
+	require.Len(t, trace.Steps, 1)
+	assert.Equal(t, "msg-1", trace.Steps[0].NodeID)
+	assert.Equal(t, entity.AutomationDecisionRespond, trace.Decision, "a message node continues without waiting")
+}
+
 // ---------------------------------------------------------------------------
 // ProcessTransition tests
 // ---------------------------------------------------------------------------
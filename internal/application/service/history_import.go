@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -22,6 +23,7 @@ type HistoryImportService struct {
 	contactRepo      repository.ContactRepository
 	importRepo       repository.HistoryImportRepository
 	waClient         *whatsapp_official.Client
+	identifiers      *IdentifierService
 	// Track running imports for cancellation
 	runningImports map[string]context.CancelFunc
 }
@@ -44,6 +46,11 @@ func NewHistoryImportService(
 	}
 }
 
+// SetIdentifierService normalizes the phone numbers of imported contacts
+func (s *HistoryImportService) SetIdentifierService(identifiers *IdentifierService) {
+	s.identifiers = identifiers
+}
+
 // StartImportInput represents input for starting a history import
 type StartImportInput struct {
 	ChannelID   string
@@ -276,8 +283,19 @@ func (s *HistoryImportService) CancelImport(ctx context.Context, importID string
 
 // importContact creates or updates a contact from WhatsApp data
 func (s *HistoryImportService) importContact(ctx context.Context, tenantID, channelID, phone, name string) (*entity.Contact, error) {
+	// WhatsApp sends numbers without "+"; contacts keep them in E.164
+	waID := phone
+	if s.identifiers != nil {
+		normalized, err := s.identifiers.NormalizePhone(ctx, tenantID, "+"+strings.TrimPrefix(phone, "+"))
+		if err != nil {
+			return nil, err
+		}
+		phone = normalized
+		waID = strings.TrimPrefix(normalized, "+")
+	}
+
 	// Check if contact exists
-	existing, err := s.contactRepo.FindByPhone(ctx, phone, tenantID)
+	existing, err := s.contactRepo.FindByPhone(ctx, tenantID, phone)
 	if err == nil && existing != nil {
 		// Update name if needed
 		if name != "" && existing.Name != name {
@@ -311,7 +329,7 @@ func (s *HistoryImportService) importContact(ctx context.Context, tenantID, chan
 		ID:          uuid.New().String(),
 		ContactID:   contact.ID,
 		ChannelType: string(entity.ChannelTypeWhatsAppOfficial),
-		Identifier:  phone,
+		Identifier:  waID,
 		Metadata: map[string]string{
 			"channel_id": channelID,
 		},
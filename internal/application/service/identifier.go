@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/utils"
+)
+
+// telegramUsernamePattern matches Telegram usernames without the leading "@"
+var telegramUsernamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]{4,31}$`)
+
+// IdentifierService validates the identifiers contacts are reached by and normalizes
+// them to the format each channel sends to: phone numbers to E.164, lowercased email
+// addresses and Telegram usernames resolved to chat IDs. Malformed identifiers are
+// rejected up front instead of failing at the provider.
+type IdentifierService struct {
+	tenantRepo  repository.TenantRepository
+	contactRepo repository.ContactRepository
+}
+
+// NewIdentifierService creates a new identifier service
+func NewIdentifierService(tenantRepo repository.TenantRepository, contactRepo repository.ContactRepository) *IdentifierService {
+	return &IdentifierService{
+		tenantRepo:  tenantRepo,
+		contactRepo: contactRepo,
+	}
+}
+
+// maxIdentifierBatch caps how many identifiers are validated in one request
+const maxIdentifierBatch = 1000
+
+// IdentifierValidation is the outcome of validating one identifier
+type IdentifierValidation struct {
+	Identifier string `json:"identifier"`
+	Valid      bool   `json:"valid"`
+	Normalized string `json:"normalized,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// ValidateIdentifiers validates identifiers for a channel type, as when checking a
+// contact list before importing it or targeting it with a campaign
+func (s *IdentifierService) ValidateIdentifiers(ctx context.Context, tenantID string, channelType entity.ChannelType, identifiers []string) ([]*IdentifierValidation, error) {
+	if len(identifiers) == 0 {
+		return nil, errors.Validation("identifiers is required")
+	}
+	if len(identifiers) > maxIdentifierBatch {
+		return nil, errors.Validation(fmt.Sprintf("at most %d identifiers can be validated at once", maxIdentifierBatch))
+	}
+
+	results := make([]*IdentifierValidation, 0, len(identifiers))
+	for _, identifier := range identifiers {
+		result := &IdentifierValidation{Identifier: identifier}
+		normalized, err := s.NormalizeIdentifier(ctx, tenantID, channelType, identifier)
+		switch {
+		case err == nil:
+			result.Valid = true
+			result.Normalized = normalized
+		case errors.IsValidation(err):
+			result.Error = errors.GetAppError(err).Message
+		default:
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// NormalizePhone formats a phone number as E.164, reading numbers written without a
+// country code as numbers of the tenant's phone region
+func (s *IdentifierService) NormalizePhone(ctx context.Context, tenantID, phone string) (string, error) {
+	normalized, err := utils.FormatE164(phone, s.phoneRegion(ctx, tenantID))
+	if err != nil {
+		return "", errors.Validation(err.Error())
+	}
+	return normalized, nil
+}
+
+// NormalizeEmail validates an email address and lowercases it
+func (s *IdentifierService) NormalizeEmail(email string) (string, error) {
+	normalized, err := utils.NormalizeEmail(email)
+	if err != nil {
+		return "", errors.Validation(err.Error())
+	}
+	return normalized, nil
+}
+
+// ResolveTelegramUsername returns the chat ID of a Telegram user by username. Bots
+// cannot start chats by username, so only users who already wrote to one of the
+// tenant's bots resolve; in private chats the chat ID is the user ID for every bot.
+func (s *IdentifierService) ResolveTelegramUsername(ctx context.Context, tenantID, username string) (string, error) {
+	username = strings.TrimPrefix(strings.TrimSpace(username), "@")
+	if !telegramUsernamePattern.MatchString(username) {
+		return "", errors.Validation("invalid Telegram username: " + username)
+	}
+
+	identity, err := s.contactRepo.FindIdentityByUsername(ctx, tenantID, string(entity.ChannelTypeTelegram), username)
+	if err != nil {
+		if appErr := errors.GetAppError(err); appErr != nil && appErr.Code == errors.ErrCodeInternal {
+			return "", err
+		}
+		return "", errors.Validation("Telegram user @" + username + " has not started a chat with any of the tenant's bots")
+	}
+	return identity.Identifier, nil
+}
+
+// NormalizeIdentifier validates an identifier and formats it the way the channel type
+// addresses recipients
+func (s *IdentifierService) NormalizeIdentifier(ctx context.Context, tenantID string, channelType entity.ChannelType, identifier string) (string, error) {
+	identifier = strings.TrimSpace(identifier)
+	if identifier == "" {
+		return "", errors.Validation("identifier is required")
+	}
+
+	switch channelType {
+	case entity.ChannelTypeWhatsApp, entity.ChannelTypeWhatsAppOfficial, entity.ChannelTypeWhatsAppUnofficial:
+		// JIDs address users and groups directly
+		if strings.Contains(identifier, "@") {
+			return identifier, nil
+		}
+		// WhatsApp IDs are international numbers; numbers typed without country code
+		// fall back to the tenant's region
+		phone, err := utils.FormatE164(identifier, "")
+		if err != nil {
+			if phone, err = s.NormalizePhone(ctx, tenantID, identifier); err != nil {
+				return "", err
+			}
+		}
+		return strings.TrimPrefix(phone, "+"), nil
+
+	case entity.ChannelTypeSMS, entity.ChannelTypeRCS:
+		return s.NormalizePhone(ctx, tenantID, identifier)
+
+	case entity.ChannelTypeVoice:
+		if strings.HasPrefix(strings.ToLower(identifier), "sip:") {
+			return identifier, nil
+		}
+		return s.NormalizePhone(ctx, tenantID, identifier)
+
+	case entity.ChannelTypeEmail:
+		return s.NormalizeEmail(identifier)
+
+	case entity.ChannelTypeTelegram:
+		if _, err := strconv.ParseInt(identifier, 10, 64); err == nil {
+			return identifier, nil
+		}
+		return s.ResolveTelegramUsername(ctx, tenantID, identifier)
+
+	default:
+		return identifier, nil
+	}
+}
+
+// NormalizeRecipient validates the recipient of an outbound message before it is stored
+// and published, so malformed identifiers fail at send time instead of at the provider
+func (s *IdentifierService) NormalizeRecipient(ctx context.Context, channel *entity.Channel, recipientID string) (string, error) {
+	// Channels such as web chat address the conversation rather than the contact
+	if recipientID == "" {
+		return "", nil
+	}
+	normalized, err := s.NormalizeIdentifier(ctx, channel.TenantID, channel.Type, recipientID)
+	if err != nil {
+		if errors.IsValidation(err) {
+			return "", errors.Validation("invalid recipient: " + errors.GetAppError(err).Message)
+		}
+		return "", err
+	}
+	return normalized, nil
+}
+
+// phoneRegion returns the tenant's default phone region, "" when unset or unknown
+func (s *IdentifierService) phoneRegion(ctx context.Context, tenantID string) string {
+	if s.tenantRepo == nil || tenantID == "" {
+		return ""
+	}
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return ""
+	}
+	return tenant.PhoneRegion()
+}
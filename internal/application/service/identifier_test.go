@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestIdentifierService(region string) (*IdentifierService, *testutil.MockContactRepository) {
+	tenants := testutil.NewMockTenantRepository()
+	tenants.Tenants["t1"] = &entity.Tenant{ID: "t1", Settings: map[string]string{entity.TenantSettingPhoneRegion: region}}
+	contacts := testutil.NewMockContactRepository()
+	return NewIdentifierService(tenants, contacts), contacts
+}
+
+func TestIdentifierService_NormalizeIdentifier(t *testing.T) {
+	svc, _ := newTestIdentifierService("br")
+	ctx := context.Background()
+
+	tests := []struct {
+		channelType entity.ChannelType
+		identifier  string
+		want        string
+	}{
+		{entity.ChannelTypeSMS, "(11) 99999-8888", "+5511999998888"},
+		{entity.ChannelTypeVoice, "+1 415 555 1234", "+14155551234"},
+		{entity.ChannelTypeVoice, "sip:agent@pbx.example.com", "sip:agent@pbx.example.com"},
+		{entity.ChannelTypeWhatsAppOfficial, "5511999998888", "5511999998888"},
+		{entity.ChannelTypeWhatsAppOfficial, "447911123456", "447911123456"},
+		{entity.ChannelTypeWhatsApp, "(11) 99999-8888", "5511999998888"},
+		{entity.ChannelTypeWhatsApp, "120363025@g.us", "120363025@g.us"},
+		{entity.ChannelTypeEmail, "Ana@Example.com", "ana@example.com"},
+		{entity.ChannelTypeTelegram, "-1001234567890", "-1001234567890"},
+		{entity.ChannelTypeWebChat, "session-1", "session-1"},
+	}
+	for _, tt := range tests {
+		got, err := svc.NormalizeIdentifier(ctx, "t1", tt.channelType, tt.identifier)
+		require.NoError(t, err, tt.identifier)
+		assert.Equal(t, tt.want, got, tt.identifier)
+	}
+
+	_, err := svc.NormalizeIdentifier(ctx, "t1", entity.ChannelTypeSMS, "9999-8888")
+	assert.True(t, errors.IsValidation(err))
+	_, err = svc.NormalizeIdentifier(ctx, "t1", entity.ChannelTypeEmail, "ana@example")
+	assert.True(t, errors.IsValidation(err))
+}
+
+func TestIdentifierService_NormalizePhoneWithoutRegion(t *testing.T) {
+	svc, _ := newTestIdentifierService("")
+
+	_, err := svc.NormalizePhone(context.Background(), "t1", "(11) 99999-8888")
+	assert.True(t, errors.IsValidation(err))
+
+	phone, err := svc.NormalizePhone(context.Background(), "t1", "+55 11 99999-8888")
+	require.NoError(t, err)
+	assert.Equal(t, "+5511999998888", phone)
+}
+
+func TestIdentifierService_ResolveTelegramUsername(t *testing.T) {
+	svc, contacts := newTestIdentifierService("")
+	ctx := context.Background()
+	contacts.Contacts["c1"] = &entity.Contact{ID: "c1", TenantID: "t1"}
+	contacts.Identities["c1"] = []*entity.ContactIdentity{{
+		ContactID:   "c1",
+		ChannelType: string(entity.ChannelTypeTelegram),
+		Identifier:  "123456789",
+		Metadata:    map[string]string{"username": "AnaSouza"},
+	}}
+
+	chatID, err := svc.NormalizeIdentifier(ctx, "t1", entity.ChannelTypeTelegram, "@anasouza")
+	require.NoError(t, err)
+	assert.Equal(t, "123456789", chatID)
+
+	_, err = svc.ResolveTelegramUsername(ctx, "t2", "anasouza")
+	assert.True(t, errors.IsValidation(err))
+	_, err = svc.ResolveTelegramUsername(ctx, "t1", "@ana")
+	assert.True(t, errors.IsValidation(err))
+}
+
+func TestIdentifierService_ValidateIdentifiers(t *testing.T) {
+	svc, _ := newTestIdentifierService("BR")
+
+	results, err := svc.ValidateIdentifiers(context.Background(), "t1", entity.ChannelTypeSMS, []string{"11 3333-4444", "12345"})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.True(t, results[0].Valid)
+	assert.Equal(t, "+551133334444", results[0].Normalized)
+	assert.False(t, results[1].Valid)
+	assert.NotEmpty(t, results[1].Error)
+
+	_, err = svc.ValidateIdentifiers(context.Background(), "t1", entity.ChannelTypeSMS, nil)
+	assert.True(t, errors.IsValidation(err))
+}
+
+func TestContactService_NormalizesIdentifiers(t *testing.T) {
+	identifiers, contacts := newTestIdentifierService("BR")
+	svc := NewContactService(contacts)
+	svc.SetIdentifierService(identifiers)
+	ctx := context.Background()
+
+	contact, err := svc.Create(ctx, &CreateContactInput{TenantID: "t1", Name: "Ana", Email: "Ana@Example.com", Phone: "(11) 99999-8888"})
+	require.NoError(t, err)
+	assert.Equal(t, "ana@example.com", contact.Email)
+	assert.Equal(t, "+5511999998888", contact.Phone)
+
+	_, err = svc.Create(ctx, &CreateContactInput{TenantID: "t1", Name: "Bia", Phone: "8888"})
+	assert.True(t, errors.IsValidation(err))
+
+	badEmail := "bia@"
+	_, err = svc.Update(ctx, "t1", contact.ID, &UpdateContactInput{Email: &badEmail})
+	assert.True(t, errors.IsValidation(err))
+
+	_, err = svc.AddIdentity(ctx, "t1", contact.ID, string(entity.ChannelTypeSMS), "11 99999-8888", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "+5511999998888", contacts.Identities[contact.ID][0].Identifier)
+}
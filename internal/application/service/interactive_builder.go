@@ -0,0 +1,374 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// InteractiveBuilderService composes channel-agnostic interactive messages into the payload
+// a channel expects, validating them against the channel's limits. The payload can be
+// passed as is to the send API, flows and bots.
+type InteractiveBuilderService struct{}
+
+// NewInteractiveBuilderService creates a new interactive builder service
+func NewInteractiveBuilderService() *InteractiveBuilderService {
+	return &InteractiveBuilderService{}
+}
+
+// InteractivePayload is a normalized interactive message ready to be sent on a channel
+type InteractivePayload struct {
+	ChannelType entity.ChannelType         `json:"channel_type"`
+	ContentType entity.ContentType         `json:"content_type"`
+	Content     string                     `json:"content"`
+	Metadata    map[string]string          `json:"metadata"`
+	Message     *entity.InteractiveMessage `json:"message"`
+	// Native is false when the channel has no interactive messages and the
+	// options were rendered as plain text
+	Native bool `json:"native"`
+}
+
+// Build validates an interactive message for a channel type and renders its payload.
+// Channels without interactive support get a numbered text fallback.
+func (s *InteractiveBuilderService) Build(channelType entity.ChannelType, message *entity.InteractiveMessage) (*InteractivePayload, error) {
+	if message == nil {
+		return nil, errors.New(errors.ErrCodeValidation, "interactive message is required")
+	}
+
+	normalizeInteractiveMessage(message)
+
+	constraints, native := entity.InteractiveConstraintsFor(channelType)
+	if err := validateInteractiveMessage(message, constraints, native); err != nil {
+		return nil, err
+	}
+
+	payload := &InteractivePayload{
+		ChannelType: channelType,
+		Message:     message,
+		Native:      native,
+		Metadata:    map[string]string{"interactive_type": string(message.Type)},
+	}
+
+	switch {
+	case !native:
+		payload.ContentType = entity.ContentTypeText
+		payload.Content = interactiveFallbackText(message)
+	case isWhatsAppChannelType(channelType):
+		data, err := json.Marshal(whatsAppInteractive(message))
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to render interactive message")
+		}
+		payload.ContentType = entity.ContentTypeInteractive
+		payload.Content = string(data)
+		payload.Metadata["interactive"] = string(data)
+	case channelType == entity.ChannelTypeTelegram:
+		data, err := json.Marshal(interactiveKeyboard(message))
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to render interactive message")
+		}
+		payload.ContentType = entity.ContentTypeText
+		payload.Content = interactiveText(message)
+		payload.Metadata["quick_replies"] = string(data)
+	default:
+		data, err := json.Marshal(message)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to render interactive message")
+		}
+		payload.ContentType = entity.ContentTypeInteractive
+		payload.Content = string(data)
+		payload.Metadata["interactive"] = string(data)
+	}
+
+	return payload, nil
+}
+
+// normalizeInteractiveMessage trims text fields and infers the type when it was omitted
+func normalizeInteractiveMessage(message *entity.InteractiveMessage) {
+	message.Header = strings.TrimSpace(message.Header)
+	message.Body = strings.TrimSpace(message.Body)
+	message.Footer = strings.TrimSpace(message.Footer)
+	message.ListButton = strings.TrimSpace(message.ListButton)
+
+	for i := range message.Buttons {
+		message.Buttons[i].ID = strings.TrimSpace(message.Buttons[i].ID)
+		message.Buttons[i].Title = strings.TrimSpace(message.Buttons[i].Title)
+	}
+	for i := range message.Sections {
+		message.Sections[i].Title = strings.TrimSpace(message.Sections[i].Title)
+		for j := range message.Sections[i].Rows {
+			row := &message.Sections[i].Rows[j]
+			row.ID = strings.TrimSpace(row.ID)
+			row.Title = strings.TrimSpace(row.Title)
+			row.Description = strings.TrimSpace(row.Description)
+		}
+	}
+
+	if message.Type == "" {
+		switch {
+		case message.CTA != nil:
+			message.Type = entity.InteractiveTypeCTAURL
+		case len(message.Sections) > 0:
+			message.Type = entity.InteractiveTypeList
+		default:
+			message.Type = entity.InteractiveTypeButton
+		}
+	}
+}
+
+// interactiveValidation collects field errors so callers see every problem at once
+type interactiveValidation map[string]string
+
+func (v interactiveValidation) checkLength(field, value string, max int, required bool) {
+	if value == "" {
+		if required {
+			v[field] = "is required"
+		}
+		return
+	}
+	if max == 0 {
+		v[field] = "is not supported on this channel"
+		return
+	}
+	if n := utf8.RuneCountInString(value); n > max {
+		v[field] = fmt.Sprintf("must be at most %d characters (got %d)", max, n)
+	}
+}
+
+func validateInteractiveMessage(message *entity.InteractiveMessage, c entity.InteractiveConstraints, native bool) error {
+	v := interactiveValidation{}
+
+	if !native {
+		// Fallback text has no limits of its own; only the structure is checked
+		c = entity.InteractiveConstraints{
+			SupportsButtons: true, SupportsList: true, SupportsCTA: true,
+			MaxButtons: 100, MaxButtonTitle: 1024, MaxButtonID: 1024,
+			MaxSections: 100, MaxRows: 1000, MaxSectionTitle: 1024, MaxRowTitle: 1024,
+			MaxRowDescription: 1024, MaxRowID: 1024, MaxListButton: 1024,
+			MaxHeader: 1024, MaxBody: 4096, MaxFooter: 1024, MaxCTADisplayText: 1024,
+		}
+	}
+
+	switch message.Type {
+	case entity.InteractiveTypeButton, entity.InteractiveTypeList, entity.InteractiveTypeCTAURL:
+		if !c.Supports(message.Type) {
+			v["type"] = fmt.Sprintf("%s messages are not supported on this channel", message.Type)
+		}
+	default:
+		v["type"] = "must be one of button, list, cta_url"
+	}
+
+	v.checkLength("header", message.Header, c.MaxHeader, false)
+	v.checkLength("body", message.Body, c.MaxBody, true)
+	v.checkLength("footer", message.Footer, c.MaxFooter, false)
+
+	ids := make(map[string]bool)
+	checkID := func(field, id string) {
+		if id != "" && ids[id] {
+			v[field] = "must be unique"
+		}
+		ids[id] = true
+	}
+
+	switch message.Type {
+	case entity.InteractiveTypeButton:
+		if len(message.Buttons) == 0 {
+			v["buttons"] = "at least one button is required"
+		} else if len(message.Buttons) > c.MaxButtons {
+			v["buttons"] = fmt.Sprintf("at most %d buttons are allowed", c.MaxButtons)
+		}
+		for i, button := range message.Buttons {
+			v.checkLength(fmt.Sprintf("buttons[%d].id", i), button.ID, c.MaxButtonID, true)
+			v.checkLength(fmt.Sprintf("buttons[%d].title", i), button.Title, c.MaxButtonTitle, true)
+			checkID(fmt.Sprintf("buttons[%d].id", i), button.ID)
+		}
+
+	case entity.InteractiveTypeList:
+		v.checkLength("list_button", message.ListButton, c.MaxListButton, true)
+		if len(message.Sections) == 0 {
+			v["sections"] = "at least one section is required"
+		} else if len(message.Sections) > c.MaxSections {
+			v["sections"] = fmt.Sprintf("at most %d sections are allowed", c.MaxSections)
+		}
+		if rows := message.RowCount(); rows > c.MaxRows {
+			v["sections.rows"] = fmt.Sprintf("at most %d rows are allowed across all sections", c.MaxRows)
+		}
+		for i, section := range message.Sections {
+			// Section titles are required once a list has more than one section
+			v.checkLength(fmt.Sprintf("sections[%d].title", i), section.Title, c.MaxSectionTitle, len(message.Sections) > 1)
+			if len(section.Rows) == 0 {
+				v[fmt.Sprintf("sections[%d].rows", i)] = "at least one row is required"
+			}
+			for j, row := range section.Rows {
+				prefix := fmt.Sprintf("sections[%d].rows[%d]", i, j)
+				v.checkLength(prefix+".id", row.ID, c.MaxRowID, true)
+				v.checkLength(prefix+".title", row.Title, c.MaxRowTitle, true)
+				v.checkLength(prefix+".description", row.Description, c.MaxRowDescription, false)
+				checkID(prefix+".id", row.ID)
+			}
+		}
+
+	case entity.InteractiveTypeCTAURL:
+		if message.CTA == nil {
+			v["cta"] = "is required"
+			break
+		}
+		v.checkLength("cta.display_text", message.CTA.DisplayText, c.MaxCTADisplayText, true)
+		if parsed, err := url.Parse(message.CTA.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			v["cta.url"] = "must be an absolute http(s) URL"
+		}
+	}
+
+	if len(v) > 0 {
+		return errors.New(errors.ErrCodeValidation, "Invalid interactive message").WithDetails(v)
+	}
+	return nil
+}
+
+// whatsAppInteractive renders the WhatsApp Cloud API interactive object
+func whatsAppInteractive(message *entity.InteractiveMessage) map[string]interface{} {
+	interactive := map[string]interface{}{
+		"type": string(message.Type),
+		"body": map[string]string{"text": message.Body},
+	}
+	if message.Header != "" {
+		interactive["header"] = map[string]string{"type": "text", "text": message.Header}
+	}
+	if message.Footer != "" {
+		interactive["footer"] = map[string]string{"text": message.Footer}
+	}
+
+	switch message.Type {
+	case entity.InteractiveTypeButton:
+		buttons := make([]map[string]interface{}, 0, len(message.Buttons))
+		for _, button := range message.Buttons {
+			buttons = append(buttons, map[string]interface{}{
+				"type":  "reply",
+				"reply": map[string]string{"id": button.ID, "title": button.Title},
+			})
+		}
+		interactive["action"] = map[string]interface{}{"buttons": buttons}
+
+	case entity.InteractiveTypeList:
+		sections := make([]map[string]interface{}, 0, len(message.Sections))
+		for _, section := range message.Sections {
+			rows := make([]map[string]string, 0, len(section.Rows))
+			for _, row := range section.Rows {
+				r := map[string]string{"id": row.ID, "title": row.Title}
+				if row.Description != "" {
+					r["description"] = row.Description
+				}
+				rows = append(rows, r)
+			}
+			s := map[string]interface{}{"rows": rows}
+			if section.Title != "" {
+				s["title"] = section.Title
+			}
+			sections = append(sections, s)
+		}
+		interactive["action"] = map[string]interface{}{
+			"button":   message.ListButton,
+			"sections": sections,
+		}
+
+	case entity.InteractiveTypeCTAURL:
+		interactive["action"] = map[string]interface{}{
+			"name": "cta_url",
+			"parameters": map[string]string{
+				"display_text": message.CTA.DisplayText,
+				"url":          message.CTA.URL,
+			},
+		}
+	}
+
+	return interactive
+}
+
+// interactiveKeyboard flattens buttons, list rows and CTAs into keyboard buttons
+func interactiveKeyboard(message *entity.InteractiveMessage) []map[string]string {
+	var keys []map[string]string
+	switch message.Type {
+	case entity.InteractiveTypeButton:
+		for _, button := range message.Buttons {
+			keys = append(keys, map[string]string{"id": button.ID, "title": button.Title})
+		}
+	case entity.InteractiveTypeList:
+		for _, section := range message.Sections {
+			for _, row := range section.Rows {
+				keys = append(keys, map[string]string{"id": row.ID, "title": row.Title})
+			}
+		}
+	case entity.InteractiveTypeCTAURL:
+		keys = append(keys, map[string]string{"title": message.CTA.DisplayText, "url": message.CTA.URL})
+	}
+	return keys
+}
+
+// interactiveText joins header, body and footer into one text
+func interactiveText(message *entity.InteractiveMessage) string {
+	parts := make([]string, 0, 3)
+	for _, part := range []string{message.Header, message.Body, message.Footer} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// interactiveFallbackText renders the options as a numbered list for text-only channels
+func interactiveFallbackText(message *entity.InteractiveMessage) string {
+	var b strings.Builder
+	if message.Header != "" {
+		b.WriteString(message.Header)
+		b.WriteString("\n\n")
+	}
+	b.WriteString(message.Body)
+
+	n := 0
+	option := func(title, description string) {
+		n++
+		fmt.Fprintf(&b, "\n%d. %s", n, title)
+		if description != "" {
+			fmt.Fprintf(&b, " - %s", description)
+		}
+	}
+
+	switch message.Type {
+	case entity.InteractiveTypeButton:
+		b.WriteString("\n")
+		for _, button := range message.Buttons {
+			option(button.Title, "")
+		}
+	case entity.InteractiveTypeList:
+		for _, section := range message.Sections {
+			b.WriteString("\n")
+			if section.Title != "" {
+				fmt.Fprintf(&b, "\n%s", section.Title)
+			}
+			for _, row := range section.Rows {
+				option(row.Title, row.Description)
+			}
+		}
+	case entity.InteractiveTypeCTAURL:
+		fmt.Fprintf(&b, "\n\n%s: %s", message.CTA.DisplayText, message.CTA.URL)
+	}
+
+	if message.Footer != "" {
+		b.WriteString("\n\n")
+		b.WriteString(message.Footer)
+	}
+	return b.String()
+}
+
+func isWhatsAppChannelType(channelType entity.ChannelType) bool {
+	switch channelType {
+	case entity.ChannelTypeWhatsApp, entity.ChannelTypeWhatsAppOfficial, entity.ChannelTypeWhatsAppUnofficial:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testListMessage(rows int) *entity.InteractiveMessage {
+	section := entity.InteractiveSection{Title: "Plans"}
+	for i := 0; i < rows; i++ {
+		section.Rows = append(section.Rows, entity.InteractiveRow{
+			ID:    "row-" + string(rune('a'+i)),
+			Title: "Option " + string(rune('A'+i)),
+		})
+	}
+	return &entity.InteractiveMessage{
+		Body:       "Choose a plan",
+		ListButton: "See plans",
+		Sections:   []entity.InteractiveSection{section},
+	}
+}
+
+func TestInteractiveBuilderWhatsAppList(t *testing.T) {
+	svc := NewInteractiveBuilderService()
+
+	payload, err := svc.Build(entity.ChannelTypeWhatsAppOfficial, testListMessage(3))
+	require.NoError(t, err)
+	assert.Equal(t, entity.InteractiveTypeList, payload.Message.Type)
+	assert.Equal(t, "list", payload.Metadata["interactive_type"])
+
+	var rendered map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(payload.Content), &rendered))
+	action := rendered["action"].(map[string]interface{})
+	assert.Equal(t, "See plans", action["button"])
+	assert.Len(t, action["sections"], 1)
+}
+
+func TestInteractiveBuilderEnforcesWhatsAppLimits(t *testing.T) {
+	svc := NewInteractiveBuilderService()
+
+	_, err := svc.Build(entity.ChannelTypeWhatsAppOfficial, testListMessage(11))
+	require.Error(t, err)
+	appErr := errors.GetAppError(err)
+	require.NotNil(t, appErr)
+	assert.Equal(t, errors.ErrCodeValidation, appErr.Code)
+	assert.Contains(t, appErr.Details["sections.rows"], "at most 10 rows")
+
+	_, err = svc.Build(entity.ChannelTypeWhatsAppOfficial, &entity.InteractiveMessage{
+		Body:    "Pick",
+		Buttons: []entity.InteractiveButton{{ID: "a", Title: strings.Repeat("x", 21)}, {ID: "a", Title: "Dup"}},
+	})
+	appErr = errors.GetAppError(err)
+	require.NotNil(t, appErr)
+	assert.Contains(t, appErr.Details["buttons[0].title"], "at most 20 characters")
+	assert.Equal(t, "must be unique", appErr.Details["buttons[1].id"])
+}
+
+func TestInteractiveBuilderRejectsUnsupportedType(t *testing.T) {
+	svc := NewInteractiveBuilderService()
+
+	_, err := svc.Build(entity.ChannelTypeFacebook, testListMessage(2))
+	appErr := errors.GetAppError(err)
+	require.NotNil(t, appErr)
+	assert.Contains(t, appErr.Details["type"], "not supported")
+}
+
+func TestInteractiveBuilderCTAValidation(t *testing.T) {
+	svc := NewInteractiveBuilderService()
+
+	_, err := svc.Build(entity.ChannelTypeWhatsAppOfficial, &entity.InteractiveMessage{
+		Body: "Track your order",
+		CTA:  &entity.InteractiveCTA{DisplayText: "Track", URL: "ftp://example.com"},
+	})
+	appErr := errors.GetAppError(err)
+	require.NotNil(t, appErr)
+	assert.Contains(t, appErr.Details, "cta.url")
+
+	payload, err := svc.Build(entity.ChannelTypeWhatsAppOfficial, &entity.InteractiveMessage{
+		Body: "Track your order",
+		CTA:  &entity.InteractiveCTA{DisplayText: "Track", URL: "https://example.com/track"},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, payload.Content, `"name":"cta_url"`)
+}
+
+func TestInteractiveBuilderTelegramKeyboard(t *testing.T) {
+	svc := NewInteractiveBuilderService()
+
+	payload, err := svc.Build(entity.ChannelTypeTelegram, testListMessage(4))
+	require.NoError(t, err)
+	assert.Equal(t, entity.ContentTypeText, payload.ContentType)
+	assert.Equal(t, "Choose a plan", payload.Content)
+
+	var keys []map[string]string
+	require.NoError(t, json.Unmarshal([]byte(payload.Metadata["quick_replies"]), &keys))
+	assert.Len(t, keys, 4)
+}
+
+func TestInteractiveBuilderTextFallback(t *testing.T) {
+	svc := NewInteractiveBuilderService()
+
+	payload, err := svc.Build(entity.ChannelTypeSMS, &entity.InteractiveMessage{
+		Body:    "Rate us",
+		Buttons: []entity.InteractiveButton{{ID: "good", Title: "Good"}, {ID: "bad", Title: "Bad"}},
+	})
+	require.NoError(t, err)
+	assert.False(t, payload.Native)
+	assert.Equal(t, entity.ContentTypeText, payload.ContentType)
+	assert.Equal(t, "Rate us\n\n1. Good\n2. Bad", payload.Content)
+}
@@ -0,0 +1,429 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Events published by the job module
+const (
+	EventJobSucceeded = "job.succeeded"
+	EventJobFailed    = "job.failed"
+)
+
+// Job limits
+const (
+	DefaultJobMaxAttempts = 3
+	jobHeartbeatInterval  = time.Minute
+	jobStaleAfter         = 5 * time.Minute
+	jobStaleBatch         = 100
+	jobLocalTimeout       = time.Hour
+)
+
+// ErrJobCancelled is returned by JobProgress once the job was cancelled;
+// handlers should stop and return it
+var ErrJobCancelled = stderrors.New("job cancelled")
+
+// JobProgress reports the progress (0-100) of a running job
+type JobProgress func(percent int, message string) error
+
+// JobHandler runs a job and returns its result. Validation errors fail the
+// job at once; other errors are retried while attempts are left.
+type JobHandler func(ctx context.Context, job *entity.Job, progress JobProgress) (map[string]interface{}, error)
+
+// JobQueue delivers job requests to workers
+type JobQueue interface {
+	PublishJob(ctx context.Context, req *nats.JobRequest) error
+}
+
+// JobInput represents input for enqueuing a job
+type JobInput struct {
+	Type        string
+	Payload     map[string]interface{}
+	MaxAttempts int
+	CreatedBy   string
+}
+
+// JobService persists background jobs and runs them through registered
+// handlers, on NATS workers when a queue is set or in-process otherwise
+type JobService struct {
+	repo     repository.JobRepository
+	producer nats.Publisher
+	queue    JobQueue
+
+	mu       sync.RWMutex
+	handlers map[string]JobHandler
+}
+
+// NewJobService creates a new job service
+func NewJobService(repo repository.JobRepository, producer nats.Publisher) *JobService {
+	return &JobService{
+		repo:     repo,
+		producer: producer,
+		handlers: make(map[string]JobHandler),
+	}
+}
+
+// SetQueue sets the queue that delivers jobs to workers
+func (s *JobService) SetQueue(queue JobQueue) {
+	s.queue = queue
+}
+
+// Register registers the handler of a job type
+func (s *JobService) Register(jobType string, handler JobHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[jobType] = handler
+}
+
+// Types returns the registered job types
+func (s *JobService) Types() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	types := make([]string, 0, len(s.handlers))
+	for jobType := range s.handlers {
+		types = append(types, jobType)
+	}
+	sort.Strings(types)
+	return types
+}
+
+func (s *JobService) handler(jobType string) (JobHandler, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	handler, ok := s.handlers[jobType]
+	return handler, ok
+}
+
+// Enqueue persists a job and hands it to a worker
+func (s *JobService) Enqueue(ctx context.Context, tenantID string, input *JobInput) (*entity.Job, error) {
+	if _, ok := s.handler(input.Type); !ok {
+		return nil, errors.Validation(fmt.Sprintf("unknown job type %q", input.Type))
+	}
+
+	maxAttempts := input.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultJobMaxAttempts
+	}
+	if maxAttempts > nats.JobMaxDeliver {
+		return nil, errors.Validation(fmt.Sprintf("max attempts must be at most %d", nats.JobMaxDeliver))
+	}
+
+	now := time.Now()
+	job := &entity.Job{
+		ID:          uuid.New().String(),
+		TenantID:    tenantID,
+		Type:        input.Type,
+		Status:      entity.JobStatusPending,
+		Payload:     input.Payload,
+		MaxAttempts: maxAttempts,
+		CreatedBy:   input.CreatedBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.repo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	s.dispatch(ctx, job)
+	return job, nil
+}
+
+// Get returns a job of a tenant
+func (s *JobService) Get(ctx context.Context, tenantID, id string) (*entity.Job, error) {
+	job, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeNotFound, "job not found")
+	}
+	return job, nil
+}
+
+// List lists the jobs of a tenant, newest first
+func (s *JobService) List(ctx context.Context, tenantID string, filter *entity.JobFilter, params *repository.ListParams) ([]*entity.Job, int64, error) {
+	return s.repo.ListByTenant(ctx, tenantID, filter, params)
+}
+
+// Cancel cancels a job that has not finished. Running handlers stop at
+// their next progress report.
+func (s *JobService) Cancel(ctx context.Context, tenantID, id string) (*entity.Job, error) {
+	job, err := s.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status.IsTerminal() {
+		return nil, errors.New(errors.ErrCodeConflict, fmt.Sprintf("job is already %s", job.Status))
+	}
+
+	now := time.Now()
+	job.Status = entity.JobStatusCancelled
+	job.CompletedAt = &now
+	job.UpdatedAt = now
+	if err := s.repo.Update(ctx, job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// Retry runs a failed or cancelled job again with a fresh set of attempts
+func (s *JobService) Retry(ctx context.Context, tenantID, id string) (*entity.Job, error) {
+	job, err := s.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != entity.JobStatusFailed && job.Status != entity.JobStatusCancelled {
+		return nil, errors.New(errors.ErrCodeConflict, "only failed or cancelled jobs can be retried")
+	}
+
+	job.Status = entity.JobStatusPending
+	job.Attempts = 0
+	job.Progress = 0
+	job.ProgressMessage = ""
+	job.Error = ""
+	job.Result = nil
+	job.StartedAt = nil
+	job.CompletedAt = nil
+	job.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, job); err != nil {
+		return nil, err
+	}
+
+	s.dispatch(ctx, job)
+	return job, nil
+}
+
+// HandleJobRequest runs the job of a request delivered by the queue
+func (s *JobService) HandleJobRequest(ctx context.Context, req *nats.JobRequest) error {
+	return s.Process(ctx, req.JobID)
+}
+
+// Process runs one attempt of a job. It returns an error when the attempt
+// failed and should be retried; finished, cancelled and exhausted jobs
+// return nil.
+func (s *JobService) Process(ctx context.Context, jobID string) error {
+	job, err := s.repo.FindByID(ctx, jobID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if job.Status.IsTerminal() {
+		return nil
+	}
+	if job.Status == entity.JobStatusRunning && time.Since(job.UpdatedAt) < jobStaleAfter {
+		// Duplicate delivery of a job another worker is running
+		return nil
+	}
+
+	handler, ok := s.handler(job.Type)
+	if !ok {
+		s.finish(ctx, job, entity.JobStatusFailed, nil, fmt.Sprintf("unknown job type %q", job.Type))
+		return nil
+	}
+
+	now := time.Now()
+	job.Status = entity.JobStatusRunning
+	job.Attempts++
+	job.Error = ""
+	if job.StartedAt == nil {
+		job.StartedAt = &now
+	}
+	job.UpdatedAt = now
+	if err := s.repo.Update(ctx, job); err != nil {
+		return err
+	}
+
+	result, runErr := s.run(ctx, job, handler)
+
+	// The job may have been cancelled while it ran
+	current, err := s.repo.FindByID(ctx, job.ID)
+	if err == nil && current.Status == entity.JobStatusCancelled {
+		return nil
+	}
+
+	switch {
+	case runErr == nil:
+		job.Progress = 100
+		s.finish(ctx, job, entity.JobStatusSucceeded, result, "")
+		return nil
+	case stderrors.Is(runErr, ErrJobCancelled):
+		return nil
+	case errors.IsValidation(runErr) || !job.CanRetry():
+		s.finish(ctx, job, entity.JobStatusFailed, result, runErr.Error())
+		return nil
+	default:
+		job.Status = entity.JobStatusPending
+		job.Error = runErr.Error()
+		job.UpdatedAt = time.Now()
+		if err := s.repo.Update(ctx, job); err != nil {
+			logger.Warn("Failed to record job retry", zap.String("job_id", job.ID), zap.Error(err))
+		}
+		return fmt.Errorf("job %s attempt %d failed: %w", job.ID, job.Attempts, runErr)
+	}
+}
+
+// RequeueStale hands pending jobs that were never picked up, and running
+// jobs whose worker stopped reporting, to workers again
+func (s *JobService) RequeueStale(ctx context.Context) (int, error) {
+	jobs, err := s.repo.ListStale(ctx, time.Now().Add(-jobStaleAfter), jobStaleBatch)
+	if err != nil {
+		return 0, err
+	}
+	for _, job := range jobs {
+		if job.Status == entity.JobStatusRunning {
+			job.Status = entity.JobStatusPending
+		}
+		job.UpdatedAt = time.Now()
+		if err := s.repo.Update(ctx, job); err != nil {
+			return 0, err
+		}
+		s.dispatch(ctx, job)
+	}
+	return len(jobs), nil
+}
+
+// run calls the handler, keeping the job fresh while it runs
+func (s *JobService) run(ctx context.Context, job *entity.Job, handler JobHandler) (result map[string]interface{}, err error) {
+	var mu sync.Mutex
+	percent, message := job.Progress, job.ProgressMessage
+	snapshot := func() (int, string) {
+		mu.Lock()
+		defer mu.Unlock()
+		return percent, message
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(jobHeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				p, m := snapshot()
+				s.repo.UpdateProgress(ctx, job.ID, p, m)
+			}
+		}
+	}()
+
+	defer func() {
+		close(done)
+		job.Progress, job.ProgressMessage = snapshot()
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job handler panicked: %v", r)
+		}
+	}()
+
+	progress := func(p int, m string) error {
+		if p < 0 {
+			p = 0
+		} else if p > 100 {
+			p = 100
+		}
+		mu.Lock()
+		percent, message = p, m
+		mu.Unlock()
+
+		if err := s.repo.UpdateProgress(ctx, job.ID, p, m); err != nil {
+			return err
+		}
+		current, err := s.repo.FindByID(ctx, job.ID)
+		if err != nil {
+			return err
+		}
+		if current.Status == entity.JobStatusCancelled {
+			return ErrJobCancelled
+		}
+		return nil
+	}
+
+	return handler(ctx, job, progress)
+}
+
+func (s *JobService) finish(ctx context.Context, job *entity.Job, status entity.JobStatus, result map[string]interface{}, message string) {
+	now := time.Now()
+	job.Status = status
+	job.Result = result
+	job.Error = message
+	job.CompletedAt = &now
+	job.UpdatedAt = now
+	if err := s.repo.Update(ctx, job); err != nil {
+		logger.Warn("Failed to record job result", zap.String("job_id", job.ID), zap.Error(err))
+		return
+	}
+
+	if s.producer == nil {
+		return
+	}
+	eventType := EventJobSucceeded
+	if status == entity.JobStatusFailed {
+		eventType = EventJobFailed
+	}
+	s.producer.PublishEvent(ctx, &nats.Event{
+		Type:     eventType,
+		TenantID: job.TenantID,
+		Payload: map[string]interface{}{
+			"job_id":   job.ID,
+			"type":     job.Type,
+			"status":   string(job.Status),
+			"attempts": job.Attempts,
+			"error":    job.Error,
+		},
+		Timestamp: now,
+	})
+}
+
+// dispatch hands a job to the queue, or runs it in-process without one.
+// Jobs the queue refuses stay pending until RequeueStale picks them up.
+func (s *JobService) dispatch(ctx context.Context, job *entity.Job) {
+	if s.queue != nil {
+		err := s.queue.PublishJob(ctx, &nats.JobRequest{
+			JobID:     job.ID,
+			TenantID:  job.TenantID,
+			Type:      job.Type,
+			Timestamp: time.Now(),
+		})
+		if err != nil {
+			logger.Warn("Failed to queue job", zap.String("job_id", job.ID), zap.Error(err))
+		}
+		return
+	}
+
+	go s.runLocal(context.WithoutCancel(ctx), job.ID)
+}
+
+// runLocal processes a job in-process, retrying with the queue backoff
+func (s *JobService) runLocal(ctx context.Context, jobID string) {
+	ctx, cancel := context.WithTimeout(ctx, jobLocalTimeout)
+	defer cancel()
+
+	for attempt := 1; ; attempt++ {
+		err := s.Process(ctx, jobID)
+		if err == nil {
+			return
+		}
+		logger.Warn("Job attempt failed", zap.String("job_id", jobID), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(nats.JobRetryDelay(attempt)):
+		}
+	}
+}
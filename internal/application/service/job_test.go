@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockJobRepo struct {
+	mu   sync.Mutex
+	jobs map[string]*entity.Job
+}
+
+func newMockJobRepo() *mockJobRepo {
+	return &mockJobRepo{jobs: make(map[string]*entity.Job)}
+}
+
+func (m *mockJobRepo) Create(ctx context.Context, job *entity.Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copied := *job
+	m.jobs[job.ID] = &copied
+	return nil
+}
+
+func (m *mockJobRepo) FindByID(ctx context.Context, id string) (*entity.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if job, ok := m.jobs[id]; ok {
+		copied := *job
+		return &copied, nil
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "job not found")
+}
+
+func (m *mockJobRepo) ListByTenant(ctx context.Context, tenantID string, filter *entity.JobFilter, params *repository.ListParams) ([]*entity.Job, int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var jobs []*entity.Job
+	for _, job := range m.jobs {
+		if job.TenantID != tenantID {
+			continue
+		}
+		if filter != nil && filter.Status != "" && job.Status != filter.Status {
+			continue
+		}
+		copied := *job
+		jobs = append(jobs, &copied)
+	}
+	return jobs, int64(len(jobs)), nil
+}
+
+func (m *mockJobRepo) ListStale(ctx context.Context, before time.Time, limit int) ([]*entity.Job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var jobs []*entity.Job
+	for _, job := range m.jobs {
+		if !job.Status.IsTerminal() && job.UpdatedAt.Before(before) {
+			copied := *job
+			jobs = append(jobs, &copied)
+		}
+	}
+	return jobs, nil
+}
+
+func (m *mockJobRepo) Update(ctx context.Context, job *entity.Job) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	copied := *job
+	m.jobs[job.ID] = &copied
+	return nil
+}
+
+func (m *mockJobRepo) UpdateProgress(ctx context.Context, id string, progress int, message string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	job, ok := m.jobs[id]
+	if !ok {
+		return errors.New(errors.ErrCodeNotFound, "job not found")
+	}
+	job.Progress = progress
+	job.ProgressMessage = message
+	job.UpdatedAt = time.Now()
+	return nil
+}
+
+type mockJobQueue struct {
+	requests []*nats.JobRequest
+}
+
+func (q *mockJobQueue) PublishJob(ctx context.Context, req *nats.JobRequest) error {
+	q.requests = append(q.requests, req)
+	return nil
+}
+
+func newQueuedJobService() (*JobService, *mockJobRepo, *mockJobQueue, *testutil.MockProducer) {
+	repo := newMockJobRepo()
+	queue := &mockJobQueue{}
+	producer := testutil.NewMockProducer()
+	svc := NewJobService(repo, producer)
+	svc.SetQueue(queue)
+	return svc, repo, queue, producer
+}
+
+func TestJobEnqueueAndProcess(t *testing.T) {
+	svc, repo, queue, producer := newQueuedJobService()
+	ctx := context.Background()
+
+	svc.Register("export", func(ctx context.Context, job *entity.Job, progress JobProgress) (map[string]interface{}, error) {
+		require.NoError(t, progress(50, "halfway"))
+		assert.Equal(t, 50, repo.jobs[job.ID].Progress)
+		return map[string]interface{}{"rows": job.Payload["rows"]}, nil
+	})
+	assert.Equal(t, []string{"export"}, svc.Types())
+
+	_, err := svc.Enqueue(ctx, "tenant-1", &JobInput{Type: "unknown"})
+	assert.True(t, errors.IsValidation(err))
+
+	job, err := svc.Enqueue(ctx, "tenant-1", &JobInput{Type: "export", Payload: map[string]interface{}{"rows": 10}})
+	require.NoError(t, err)
+	assert.Equal(t, entity.JobStatusPending, job.Status)
+	assert.Equal(t, DefaultJobMaxAttempts, job.MaxAttempts)
+	require.Len(t, queue.requests, 1)
+	assert.Equal(t, job.ID, queue.requests[0].JobID)
+	assert.Equal(t, "export", queue.requests[0].Type)
+
+	require.NoError(t, svc.HandleJobRequest(ctx, queue.requests[0]))
+
+	done, err := svc.Get(ctx, "tenant-1", job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.JobStatusSucceeded, done.Status)
+	assert.Equal(t, 100, done.Progress)
+	assert.Equal(t, 1, done.Attempts)
+	assert.Equal(t, 10, done.Result["rows"])
+	assert.NotNil(t, done.CompletedAt)
+	require.Len(t, producer.Events, 1)
+	assert.Equal(t, EventJobSucceeded, producer.Events[0].Type)
+
+	// A redelivered request for a finished job is a no-op
+	require.NoError(t, svc.Process(ctx, job.ID))
+
+	_, err = svc.Get(ctx, "tenant-2", job.ID)
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestJobRetriesUntilAttemptsRunOut(t *testing.T) {
+	svc, _, _, producer := newQueuedJobService()
+	ctx := context.Background()
+
+	calls := 0
+	svc.Register("flaky", func(ctx context.Context, job *entity.Job, progress JobProgress) (map[string]interface{}, error) {
+		calls++
+		return nil, stderrors.New("upstream unavailable")
+	})
+
+	job, err := svc.Enqueue(ctx, "tenant-1", &JobInput{Type: "flaky", MaxAttempts: 2})
+	require.NoError(t, err)
+
+	// The first failure asks the queue to redeliver
+	assert.Error(t, svc.Process(ctx, job.ID))
+	current, _ := svc.Get(ctx, "tenant-1", job.ID)
+	assert.Equal(t, entity.JobStatusPending, current.Status)
+	assert.Equal(t, "upstream unavailable", current.Error)
+
+	// The last attempt fails the job for good
+	assert.NoError(t, svc.Process(ctx, job.ID))
+	current, _ = svc.Get(ctx, "tenant-1", job.ID)
+	assert.Equal(t, entity.JobStatusFailed, current.Status)
+	assert.Equal(t, 2, current.Attempts)
+	assert.Equal(t, 2, calls)
+	require.Len(t, producer.Events, 1)
+	assert.Equal(t, EventJobFailed, producer.Events[0].Type)
+
+	// Retrying starts over
+	retried, err := svc.Retry(ctx, "tenant-1", job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.JobStatusPending, retried.Status)
+	assert.Equal(t, 0, retried.Attempts)
+
+	_, err = svc.Retry(ctx, "tenant-1", job.ID)
+	assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code)
+
+	_, err = svc.Enqueue(ctx, "tenant-1", &JobInput{Type: "flaky", MaxAttempts: nats.JobMaxDeliver + 1})
+	assert.True(t, errors.IsValidation(err))
+}
+
+func TestJobValidationErrorsFailAtOnce(t *testing.T) {
+	svc, _, _, _ := newQueuedJobService()
+	ctx := context.Background()
+
+	svc.Register("import", func(ctx context.Context, job *entity.Job, progress JobProgress) (map[string]interface{}, error) {
+		return nil, errors.Validation("file has no header row")
+	})
+	svc.Register("crash", func(ctx context.Context, job *entity.Job, progress JobProgress) (map[string]interface{}, error) {
+		panic("boom")
+	})
+
+	job, err := svc.Enqueue(ctx, "tenant-1", &JobInput{Type: "import"})
+	require.NoError(t, err)
+	assert.NoError(t, svc.Process(ctx, job.ID))
+	current, _ := svc.Get(ctx, "tenant-1", job.ID)
+	assert.Equal(t, entity.JobStatusFailed, current.Status)
+	assert.Equal(t, 1, current.Attempts)
+
+	job, err = svc.Enqueue(ctx, "tenant-1", &JobInput{Type: "crash", MaxAttempts: 1})
+	require.NoError(t, err)
+	assert.NoError(t, svc.Process(ctx, job.ID))
+	current, _ = svc.Get(ctx, "tenant-1", job.ID)
+	assert.Equal(t, entity.JobStatusFailed, current.Status)
+	assert.Contains(t, current.Error, "panicked")
+}
+
+func TestJobCancelStopsRunningHandler(t *testing.T) {
+	svc, _, _, _ := newQueuedJobService()
+	ctx := context.Background()
+
+	var jobID string
+	svc.Register("embeddings", func(ctx context.Context, job *entity.Job, progress JobProgress) (map[string]interface{}, error) {
+		_, err := svc.Cancel(ctx, "tenant-1", jobID)
+		require.NoError(t, err)
+		return nil, progress(10, "embedding items")
+	})
+
+	job, err := svc.Enqueue(ctx, "tenant-1", &JobInput{Type: "embeddings"})
+	require.NoError(t, err)
+	jobID = job.ID
+
+	assert.NoError(t, svc.Process(ctx, job.ID))
+	current, _ := svc.Get(ctx, "tenant-1", job.ID)
+	assert.Equal(t, entity.JobStatusCancelled, current.Status)
+
+	_, err = svc.Cancel(ctx, "tenant-1", job.ID)
+	assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code)
+}
+
+func TestJobRequeueStale(t *testing.T) {
+	svc, repo, queue, _ := newQueuedJobService()
+	ctx := context.Background()
+	svc.Register("export", func(ctx context.Context, job *entity.Job, progress JobProgress) (map[string]interface{}, error) {
+		return nil, nil
+	})
+
+	job, err := svc.Enqueue(ctx, "tenant-1", &JobInput{Type: "export"})
+	require.NoError(t, err)
+
+	count, err := svc.RequeueStale(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	// A worker died while running the job
+	repo.jobs[job.ID].Status = entity.JobStatusRunning
+	repo.jobs[job.ID].UpdatedAt = time.Now().Add(-time.Hour)
+
+	count, err = svc.RequeueStale(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, entity.JobStatusPending, repo.jobs[job.ID].Status)
+	assert.Len(t, queue.requests, 2)
+}
+
+func TestJobRunsInProcessWithoutQueue(t *testing.T) {
+	repo := newMockJobRepo()
+	svc := NewJobService(repo, nil)
+	ctx := context.Background()
+
+	ran := make(chan struct{})
+	svc.Register("export", func(ctx context.Context, job *entity.Job, progress JobProgress) (map[string]interface{}, error) {
+		close(ran)
+		return nil, nil
+	})
+
+	job, err := svc.Enqueue(ctx, "tenant-1", &JobInput{Type: "export"})
+	require.NoError(t, err)
+
+	select {
+	case <-ran:
+	case <-time.After(2 * time.Second):
+		t.Fatal("job did not run")
+	}
+	assert.Eventually(t, func() bool {
+		current, _ := repo.FindByID(ctx, job.ID)
+		return current.Status == entity.JobStatusSucceeded
+	}, 2*time.Second, 10*time.Millisecond)
+}
@@ -39,6 +39,10 @@ type KnowledgeService struct {
 	itemRepo         repository.KnowledgeItemRepository
 	embeddingService *EmbeddingService
 	vectorStore      VectorStore
+	versionRepo      repository.KnowledgeVersionRepository
+	sharingRepo      repository.KnowledgeSharingRepository
+	jobs             *JobService
+	now              func() time.Time
 }
 
 // NewKnowledgeService creates a new knowledge service
@@ -53,9 +57,16 @@ func NewKnowledgeService(
 		itemRepo:         itemRepo,
 		embeddingService: embeddingService,
 		vectorStore:      vectorStore,
+		now:              time.Now,
 	}
 }
 
+// SetVersionRepository enables version history, the publish workflow and the
+// audit trail of knowledge items
+func (s *KnowledgeService) SetVersionRepository(versionRepo repository.KnowledgeVersionRepository) {
+	s.versionRepo = versionRepo
+}
+
 // CreateKnowledgeBaseInput represents input for creating a knowledge base
 type CreateKnowledgeBaseInput struct {
 	TenantID    string
@@ -63,11 +74,15 @@ type CreateKnowledgeBaseInput struct {
 	Description string
 	Type        entity.KnowledgeType
 	Config      *entity.KnowledgeConfig
+	Global      bool // Maintained by the platform and attached to tenants; TenantID is ignored
 }
 
 // CreateKnowledgeBase creates a new knowledge base
 func (s *KnowledgeService) CreateKnowledgeBase(ctx context.Context, input *CreateKnowledgeBaseInput) (*entity.KnowledgeBase, error) {
 	kb := entity.NewKnowledgeBase(input.TenantID, input.Name, input.Type)
+	if input.Global {
+		kb = entity.NewGlobalKnowledgeBase(input.Name, input.Type)
+	}
 	kb.ID = uuid.New().String()
 	kb.Description = input.Description
 
@@ -157,22 +172,46 @@ type AddItemInput struct {
 	Keywords        []string
 	Source          string
 	Metadata        map[string]string
+	CreatedBy       string // User ID, empty for automatic additions
+	Reviewed        bool   // Approved by a reviewer, so published even where the knowledge base requires review
+
+	// Set by tenants adding to a global knowledge base: the item is the tenant's
+	// own, replacing the shared item it overrides, if any, in the tenant's searches
+	TenantID        string
+	OverridesItemID string
 }
 
-// AddItem adds an item to a knowledge base
+// AddItem adds an item to a knowledge base. The item is published right away
+// unless the knowledge base requires review, in which case it starts as a draft.
 func (s *KnowledgeService) AddItem(ctx context.Context, input *AddItemInput) (*entity.KnowledgeItem, error) {
 	// Verify knowledge base exists
 	kb, err := s.kbRepo.FindByID(ctx, input.KnowledgeBaseID)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.validateOverride(ctx, kb, input); err != nil {
+		return nil, err
+	}
 
 	// Create item
 	item := entity.NewKnowledgeItem(input.KnowledgeBaseID, input.Question, input.Answer)
 	item.ID = uuid.New().String()
+	item.TenantID = input.TenantID
+	item.OverridesItemID = input.OverridesItemID
 	item.Keywords = input.Keywords
 	item.Source = input.Source
 	item.Metadata = input.Metadata
+	item.Version = 1
+	item.Status = entity.KnowledgeItemStatusDraft
+
+	publish := s.versionRepo == nil || !kb.Config.ReviewRequired || input.Reviewed
+	if publish {
+		now := s.now()
+		item.Status = entity.KnowledgeItemStatusPublished
+		item.PublishedVersion = item.Version
+		item.PublishedBy = input.CreatedBy
+		item.PublishedAt = &now
+	}
 
 	// Generate embedding
 	if s.embeddingService != nil && s.embeddingService.IsAvailable() {
@@ -184,13 +223,9 @@ func (s *KnowledgeService) AddItem(ctx context.Context, input *AddItemInput) (*e
 		} else {
 			item.SetEmbedding(embedding)
 
-			// Store in vector store
-			if s.vectorStore != nil {
-				metadata := map[string]string{
-					"knowledge_base_id": input.KnowledgeBaseID,
-					"item_id":           item.ID,
-				}
-				s.vectorStore.Store(ctx, item.ID, embedding, metadata)
+			// Store in vector store; drafts are stored when published
+			if s.vectorStore != nil && publish {
+				s.vectorStore.Store(ctx, item.ID, embedding, vectorMetadata(item))
 			}
 		}
 	}
@@ -199,28 +234,49 @@ func (s *KnowledgeService) AddItem(ctx context.Context, input *AddItemInput) (*e
 		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to create knowledge item")
 	}
 
-	// Update item count
-	kb.IncrementItemCount()
-	s.kbRepo.Update(ctx, kb)
+	if s.versionRepo != nil {
+		version := entity.NewKnowledgeItemVersion(item, item.Version, entity.KnowledgeActionCreated, input.CreatedBy)
+		version.PublishedBy = item.PublishedBy
+		version.PublishedAt = item.PublishedAt
+		if err := s.versionRepo.CreateVersion(ctx, version); err != nil {
+			return nil, err
+		}
+		s.audit(ctx, item, entity.KnowledgeActionCreated, item.Version, input.CreatedBy)
+		if publish {
+			s.audit(ctx, item, entity.KnowledgeActionPublished, item.Version, input.CreatedBy)
+		}
+	}
+
+	// Update item count; overrides are not counted in the shared knowledge base
+	if !item.IsOverride() {
+		kb.IncrementItemCount()
+		s.kbRepo.Update(ctx, kb)
+	}
 
 	return item, nil
 }
 
 // UpdateItemInput represents input for updating a knowledge item
 type UpdateItemInput struct {
-	Question *string
-	Answer   *string
-	Keywords []string
-	Source   *string
-	Metadata map[string]string
+	Question  *string
+	Answer    *string
+	Keywords  []string
+	Source    *string
+	Metadata  map[string]string
+	UpdatedBy string // User ID, empty for automatic changes
 }
 
-// UpdateItem updates a knowledge item
+// UpdateItem updates a knowledge item. With versioning every update adds a
+// version; in knowledge bases requiring review it waits as a draft while bots
+// keep retrieving the published version.
 func (s *KnowledgeService) UpdateItem(ctx context.Context, id string, input *UpdateItemInput) (*entity.KnowledgeItem, error) {
 	item, err := s.itemRepo.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
+	if s.versionRepo != nil {
+		return s.updateVersionedItem(ctx, item, input)
+	}
 
 	needsReembedding := false
 
@@ -253,12 +309,8 @@ func (s *KnowledgeService) UpdateItem(ctx context.Context, id string, input *Upd
 
 			// Update in vector store
 			if s.vectorStore != nil {
-				metadata := map[string]string{
-					"knowledge_base_id": item.KnowledgeBaseID,
-					"item_id":           item.ID,
-				}
 				s.vectorStore.Delete(ctx, item.ID)
-				s.vectorStore.Store(ctx, item.ID, embedding, metadata)
+				s.vectorStore.Store(ctx, item.ID, embedding, vectorMetadata(item))
 			}
 		}
 	}
@@ -270,8 +322,9 @@ func (s *KnowledgeService) UpdateItem(ctx context.Context, id string, input *Upd
 	return item, nil
 }
 
-// DeleteItem deletes a knowledge item
-func (s *KnowledgeService) DeleteItem(ctx context.Context, id string) error {
+// DeleteItem deletes a knowledge item with its versions; the audit trail keeps
+// a record of the deletion
+func (s *KnowledgeService) DeleteItem(ctx context.Context, id, userID string) error {
 	item, err := s.itemRepo.FindByID(ctx, id)
 	if err != nil {
 		return err
@@ -285,8 +338,12 @@ func (s *KnowledgeService) DeleteItem(ctx context.Context, id string) error {
 	if err := s.itemRepo.Delete(ctx, id); err != nil {
 		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete knowledge item")
 	}
+	s.audit(ctx, item, entity.KnowledgeActionDeleted, item.Version, userID)
 
 	// Update item count
+	if item.IsOverride() {
+		return nil
+	}
 	kb, err := s.kbRepo.FindByID(ctx, item.KnowledgeBaseID)
 	if err == nil {
 		kb.DecrementItemCount()
@@ -306,29 +363,54 @@ func (s *KnowledgeService) ListItems(ctx context.Context, knowledgeBaseID string
 	return s.itemRepo.FindByKnowledgeBase(ctx, knowledgeBaseID, params)
 }
 
-// Search performs semantic search on a knowledge base
-func (s *KnowledgeService) Search(ctx context.Context, knowledgeBaseID, query string, limit int) ([]entity.SearchResult, error) {
-	// Use vector search if available
+// Search performs semantic search over the published items of a knowledge base the
+// tenant may read. In a global knowledge base the tenant's override items are searched
+// along with the shared items and replace the items they override; other tenants'
+// overrides are never returned.
+func (s *KnowledgeService) Search(ctx context.Context, tenantID, knowledgeBaseID, query string, limit int) ([]entity.SearchResult, error) {
+	kb, err := s.KnowledgeBaseForTenant(ctx, tenantID, knowledgeBaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	var overrides []*entity.KnowledgeItem
+	if kb.Global {
+		if overrides, err = s.sharingRepo.FindOverrides(ctx, kb.ID, tenantID); err != nil {
+			return nil, err
+		}
+	}
+	// Overridden items found are dropped, so look further to still fill the limit
+	searchLimit := limit + len(overrides)
+
+	var results []entity.SearchResult
 	if s.embeddingService != nil && s.embeddingService.IsAvailable() && s.vectorStore != nil {
-		return s.vectorSearch(ctx, knowledgeBaseID, query, limit)
+		// Use vector search if available
+		results, err = s.vectorSearch(ctx, tenantID, kb.ID, query, searchLimit, overrides)
+	} else {
+		// Fallback to keyword search
+		results, err = s.keywordSearch(ctx, kb.ID, query, searchLimit, overrides)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	// Fallback to keyword search
-	return s.keywordSearch(ctx, knowledgeBaseID, query, limit)
+	return applyOverrides(results, overrides, tenantID, limit), nil
 }
 
-// vectorSearch performs vector similarity search
-func (s *KnowledgeService) vectorSearch(ctx context.Context, knowledgeBaseID, query string, limit int) ([]entity.SearchResult, error) {
+// vectorSearch performs vector similarity search over the shared items and the
+// tenant's overrides
+func (s *KnowledgeService) vectorSearch(ctx context.Context, tenantID, knowledgeBaseID, query string, limit int, overrides []*entity.KnowledgeItem) ([]entity.SearchResult, error) {
 	// Generate query embedding
 	queryEmbedding, err := s.embeddingService.GenerateEmbedding(ctx, query)
 	if err != nil {
 		// Fallback to keyword search
-		return s.keywordSearch(ctx, knowledgeBaseID, query, limit)
+		return s.keywordSearch(ctx, knowledgeBaseID, query, limit, overrides)
 	}
 
 	// Search in vector store
 	filter := map[string]string{
 		"knowledge_base_id": knowledgeBaseID,
+		"tenant_id":         tenantID,
 	}
 
 	vectorResults, err := s.vectorStore.Search(ctx, queryEmbedding, limit, filter)
@@ -340,7 +422,7 @@ func (s *KnowledgeService) vectorSearch(ctx context.Context, knowledgeBaseID, qu
 	results := make([]entity.SearchResult, 0, len(vectorResults))
 	for _, vr := range vectorResults {
 		item, err := s.itemRepo.FindByID(ctx, vr.ID)
-		if err != nil {
+		if err != nil || !item.IsPublished() {
 			continue
 		}
 		results = append(results, entity.SearchResult{
@@ -352,8 +434,9 @@ func (s *KnowledgeService) vectorSearch(ctx context.Context, knowledgeBaseID, qu
 	return results, nil
 }
 
-// keywordSearch performs keyword-based search
-func (s *KnowledgeService) keywordSearch(ctx context.Context, knowledgeBaseID, query string, limit int) ([]entity.SearchResult, error) {
+// keywordSearch performs keyword-based search over the shared items, and matches
+// the tenant's overrides against the same keywords
+func (s *KnowledgeService) keywordSearch(ctx context.Context, knowledgeBaseID, query string, limit int, overrides []*entity.KnowledgeItem) ([]entity.SearchResult, error) {
 	// Split query into keywords
 	keywords := splitKeywords(query)
 
@@ -362,11 +445,19 @@ func (s *KnowledgeService) keywordSearch(ctx context.Context, knowledgeBaseID, q
 		return nil, errors.Wrap(err, errors.ErrCodeInternal, "keyword search failed")
 	}
 
-	results := make([]entity.SearchResult, len(items))
-	for i, item := range items {
-		results[i] = entity.SearchResult{
+	results := make([]entity.SearchResult, 0, len(items))
+	for _, item := range items {
+		if !item.IsPublished() || item.IsOverride() {
+			continue
+		}
+		results = append(results, entity.SearchResult{
 			Item:  item,
 			Score: 1.0, // No score for keyword search
+		})
+	}
+	for _, item := range overrides {
+		if item.IsPublished() && matchesKeywords(item, keywords) {
+			results = append(results, entity.SearchResult{Item: item, Score: 1.0})
 		}
 	}
 
@@ -394,52 +485,17 @@ func splitKeywords(query string) []string {
 	return keywords
 }
 
-// RegenerateEmbeddings regenerates embeddings for all items in a knowledge base
-func (s *KnowledgeService) RegenerateEmbeddings(ctx context.Context, knowledgeBaseID string) error {
-	if s.embeddingService == nil || !s.embeddingService.IsAvailable() {
-		return errors.New(errors.ErrCodeBadRequest, "embedding service not available")
+// vectorMetadata returns the metadata stored with the embedding of an item, with the
+// tenant of override items so vector stores can keep them to that tenant
+func vectorMetadata(item *entity.KnowledgeItem) map[string]string {
+	metadata := map[string]string{
+		"knowledge_base_id": item.KnowledgeBaseID,
+		"item_id":           item.ID,
 	}
-
-	// Get all items
-	items, _, err := s.itemRepo.FindByKnowledgeBase(ctx, knowledgeBaseID, &repository.ListParams{Page: 1, PageSize: 10000})
-	if err != nil {
-		return err
-	}
-
-	kb, err := s.kbRepo.FindByID(ctx, knowledgeBaseID)
-	if err != nil {
-		return err
-	}
-
-	kb.MarkSyncing()
-	s.kbRepo.Update(ctx, kb)
-
-	// Regenerate embeddings
-	for _, item := range items {
-		textToEmbed := item.Question + " " + item.Answer
-		embedding, err := s.embeddingService.GenerateEmbedding(ctx, textToEmbed)
-		if err != nil {
-			continue
-		}
-
-		item.SetEmbedding(embedding)
-		s.itemRepo.Update(ctx, item)
-
-		// Update vector store
-		if s.vectorStore != nil {
-			metadata := map[string]string{
-				"knowledge_base_id": knowledgeBaseID,
-				"item_id":           item.ID,
-			}
-			s.vectorStore.Delete(ctx, item.ID)
-			s.vectorStore.Store(ctx, item.ID, embedding, metadata)
-		}
+	if item.IsOverride() {
+		metadata["tenant_id"] = item.TenantID
 	}
-
-	kb.MarkSynced()
-	s.kbRepo.Update(ctx, kb)
-
-	return nil
+	return metadata
 }
 
 // Note: KnowledgeService implements usecase.KnowledgeSearchService interface
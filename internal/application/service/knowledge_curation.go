@@ -0,0 +1,431 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Knowledge mining limits
+const (
+	defaultKnowledgeMiningLimit   = 10
+	maxKnowledgeMiningLimit       = 50
+	knowledgeMiningPageSize       = 50
+	knowledgeMiningMaxPages       = 10
+	knowledgeMiningMessageLimit   = 100
+	maxKnowledgePairsPerMining    = 5
+	knowledgeMiningMessageMaxSize = 1000
+)
+
+const knowledgeMiningPrompt = `Below is a resolved customer service conversation. Extract the questions it
+answered that other customers are likely to ask again, each with the answer the agent gave.
+
+Reply with a JSON array of at most %d objects with "question" and "answer" keys. Write the
+question as a customer would ask it and the answer as a complete, standalone reply. Leave out
+names, order numbers, addresses and anything else specific to this customer. Reply with []
+when the conversation has nothing reusable.
+
+Conversation:
+%s`
+
+// MineKnowledgeInput selects the resolved conversations mined for Q&A pairs
+type MineKnowledgeInput struct {
+	ConversationIDs []string   `json:"conversation_ids,omitempty"` // Latest resolved conversations when empty
+	Since           *time.Time `json:"since,omitempty"`            // Only conversations resolved after this time
+	Limit           int        `json:"limit,omitempty"`            // Conversations to mine, 10 by default and at most 50
+}
+
+// KnowledgeCandidateInput represents a reviewer's edits to a mined Q&A pair
+type KnowledgeCandidateInput struct {
+	Question *string `json:"question,omitempty"`
+	Answer   *string `json:"answer,omitempty"`
+}
+
+// PublishKnowledgeCandidateInput represents the knowledge base a Q&A pair is published
+// to, with the reviewer's final edits
+type PublishKnowledgeCandidateInput struct {
+	KnowledgeBaseID string  `json:"knowledge_base_id" binding:"required"`
+	Question        *string `json:"question,omitempty"`
+	Answer          *string `json:"answer,omitempty"`
+}
+
+// KnowledgeCurationService turns support history into bot knowledge. The AI proposes
+// reusable Q&A pairs from resolved conversations; a reviewer edits and approves them
+// and publishes them to a knowledge base, linked back to their conversation.
+type KnowledgeCurationService struct {
+	repo             repository.KnowledgeCurationRepository
+	conversationRepo repository.ConversationRepository
+	messageRepo      repository.MessageRepository
+	knowledgeService *KnowledgeService
+	aiFactory        *AIProviderFactory
+	now              func() time.Time
+}
+
+// NewKnowledgeCurationService creates a new knowledge curation service
+func NewKnowledgeCurationService(
+	repo repository.KnowledgeCurationRepository,
+	conversationRepo repository.ConversationRepository,
+	messageRepo repository.MessageRepository,
+	knowledgeService *KnowledgeService,
+	aiFactory *AIProviderFactory,
+) *KnowledgeCurationService {
+	return &KnowledgeCurationService{
+		repo:             repo,
+		conversationRepo: conversationRepo,
+		messageRepo:      messageRepo,
+		knowledgeService: knowledgeService,
+		aiFactory:        aiFactory,
+		now:              time.Now,
+	}
+}
+
+// Mine asks the AI for Q&A pairs in resolved conversations not mined before and
+// queues them for review. A conversation the AI fails on is left to the next run.
+func (s *KnowledgeCurationService) Mine(ctx context.Context, tenantID string, input *MineKnowledgeInput) (*entity.KnowledgeMiningResult, error) {
+	provider, err := s.aiFactory.FirstAvailable()
+	if err != nil {
+		return nil, err
+	}
+	ctx = WithAIUsage(ctx, tenantID, "", entity.AIFeatureCuration)
+
+	limit := input.Limit
+	if limit <= 0 {
+		limit = defaultKnowledgeMiningLimit
+	}
+	if limit > maxKnowledgeMiningLimit {
+		return nil, errors.Validation(fmt.Sprintf("at most %d conversations can be mined at once", maxKnowledgeMiningLimit))
+	}
+	if len(input.ConversationIDs) > maxKnowledgeMiningLimit {
+		return nil, errors.Validation(fmt.Sprintf("at most %d conversations can be mined at once", maxKnowledgeMiningLimit))
+	}
+
+	result := &entity.KnowledgeMiningResult{Candidates: []*entity.KnowledgeCandidate{}}
+	mine := func(conversation *entity.Conversation) error {
+		mined, err := s.repo.IsConversationMined(ctx, conversation.ID)
+		if err != nil {
+			return err
+		}
+		if mined {
+			result.Skipped++
+			return nil
+		}
+
+		candidates, err := s.mineConversation(ctx, provider, conversation)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				result.Skipped++
+				return nil
+			}
+			logger.Warn("Failed to mine conversation for knowledge",
+				zap.String("conversation_id", conversation.ID),
+				zap.Error(err))
+			result.Failed++
+			return nil
+		}
+		result.Conversations++
+		result.Candidates = append(result.Candidates, candidates...)
+		return nil
+	}
+
+	if len(input.ConversationIDs) > 0 {
+		for _, id := range input.ConversationIDs {
+			conversation, err := s.conversationRepo.FindByID(ctx, id)
+			if err != nil || conversation.TenantID != tenantID {
+				return nil, errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+			}
+			if !knowledgeMinable(conversation, input.Since) {
+				return nil, errors.Validation("only resolved conversations can be mined: " + id)
+			}
+			if err := mine(conversation); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+
+	// Walk the latest resolved conversations until enough unmined ones were read
+	for page := 1; page <= knowledgeMiningMaxPages && result.Conversations+result.Failed < limit; page++ {
+		params := &repository.ListParams{
+			Page:     page,
+			PageSize: knowledgeMiningPageSize,
+			SortBy:   "updated_at",
+			SortDir:  "desc",
+			Filters:  map[string]interface{}{"status": string(entity.ConversationStatusResolved)},
+		}
+		conversations, _, err := s.conversationRepo.FindByTenant(ctx, tenantID, params)
+		if err != nil {
+			return nil, err
+		}
+		for _, conversation := range conversations {
+			if result.Conversations+result.Failed >= limit {
+				break
+			}
+			if !knowledgeMinable(conversation, input.Since) {
+				continue
+			}
+			if err := mine(conversation); err != nil {
+				return nil, err
+			}
+		}
+		if len(conversations) < knowledgeMiningPageSize {
+			break
+		}
+	}
+	return result, nil
+}
+
+// ListCandidates lists the mined Q&A pairs of a tenant for review
+func (s *KnowledgeCurationService) ListCandidates(ctx context.Context, tenantID string, filter *entity.KnowledgeCandidateFilter, params *repository.ListParams) ([]*entity.KnowledgeCandidate, int64, error) {
+	return s.repo.ListCandidates(ctx, tenantID, filter, params)
+}
+
+// GetCandidate returns a mined Q&A pair of the tenant
+func (s *KnowledgeCurationService) GetCandidate(ctx context.Context, tenantID, id string) (*entity.KnowledgeCandidate, error) {
+	candidate, err := s.repo.FindCandidateByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if candidate.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeNotFound, "knowledge candidate not found")
+	}
+	return candidate, nil
+}
+
+// UpdateCandidate edits the question or answer of a Q&A pair before it is published
+func (s *KnowledgeCurationService) UpdateCandidate(ctx context.Context, tenantID, id string, input *KnowledgeCandidateInput) (*entity.KnowledgeCandidate, error) {
+	candidate, err := s.pendingCandidate(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	applyCandidateEdits(candidate, input.Question, input.Answer)
+	candidate.UpdatedAt = s.now()
+	if err := s.repo.UpdateCandidate(ctx, candidate); err != nil {
+		return nil, err
+	}
+	return candidate, nil
+}
+
+// Publish adds an approved Q&A pair to a knowledge base of the tenant. The knowledge
+// item links back to the conversation the pair was mined from.
+func (s *KnowledgeCurationService) Publish(ctx context.Context, tenantID, id, reviewerID string, input *PublishKnowledgeCandidateInput) (*entity.KnowledgeCandidate, error) {
+	candidate, err := s.pendingCandidate(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	applyCandidateEdits(candidate, input.Question, input.Answer)
+	if candidate.Question == "" || candidate.Answer == "" {
+		return nil, errors.Validation("question and answer are required")
+	}
+
+	kb, err := s.knowledgeService.GetKnowledgeBase(ctx, input.KnowledgeBaseID)
+	if err != nil || kb.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeNotFound, "knowledge base not found")
+	}
+
+	item, err := s.knowledgeService.AddItem(ctx, &AddItemInput{
+		KnowledgeBaseID: kb.ID,
+		Question:        candidate.Question,
+		Answer:          candidate.Answer,
+		Source:          "conversation:" + candidate.ConversationID,
+		Metadata: map[string]string{
+			"conversation_id": candidate.ConversationID,
+			"candidate_id":    candidate.ID,
+		},
+		CreatedBy: reviewerID,
+		Reviewed:  true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	candidate.Status = entity.KnowledgeCandidatePublished
+	candidate.KnowledgeBaseID = kb.ID
+	candidate.ItemID = item.ID
+	candidate.ReviewedBy = reviewerID
+	candidate.ReviewedAt = &now
+	candidate.UpdatedAt = now
+	if err := s.repo.UpdateCandidate(ctx, candidate); err != nil {
+		return nil, err
+	}
+	return candidate, nil
+}
+
+// Reject discards a Q&A pair without publishing it
+func (s *KnowledgeCurationService) Reject(ctx context.Context, tenantID, id, reviewerID string) (*entity.KnowledgeCandidate, error) {
+	candidate, err := s.pendingCandidate(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	candidate.Status = entity.KnowledgeCandidateRejected
+	candidate.ReviewedBy = reviewerID
+	candidate.ReviewedAt = &now
+	candidate.UpdatedAt = now
+	if err := s.repo.UpdateCandidate(ctx, candidate); err != nil {
+		return nil, err
+	}
+	return candidate, nil
+}
+
+func (s *KnowledgeCurationService) pendingCandidate(ctx context.Context, tenantID, id string) (*entity.KnowledgeCandidate, error) {
+	candidate, err := s.GetCandidate(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !candidate.IsPending() {
+		return nil, errors.Validation(fmt.Sprintf("candidate is already %s", candidate.Status))
+	}
+	return candidate, nil
+}
+
+// mineConversation stores the Q&A pairs the AI finds in a conversation. It returns a
+// not found error when the conversation has no exchange between customer and agent.
+func (s *KnowledgeCurationService) mineConversation(ctx context.Context, provider AIProvider, conversation *entity.Conversation) ([]*entity.KnowledgeCandidate, error) {
+	messages, _, err := s.messageRepo.FindByConversation(ctx, conversation.ID, &repository.ListParams{
+		Page:     1,
+		PageSize: knowledgeMiningMessageLimit,
+		SortBy:   "created_at",
+		SortDir:  "desc",
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(messages, func(i, j int) bool { return messages[i].CreatedAt.Before(messages[j].CreatedAt) })
+
+	var transcript strings.Builder
+	var fromCustomer, fromAgent bool
+	for _, msg := range messages {
+		if strings.TrimSpace(msg.Content) == "" {
+			continue
+		}
+		role := "Customer"
+		switch msg.SenderType {
+		case entity.SenderTypeUser:
+			role = "Agent"
+			fromAgent = true
+		case entity.SenderTypeBot:
+			role = "Bot"
+		case entity.SenderTypeSystem:
+			continue
+		default:
+			fromCustomer = true
+		}
+		fmt.Fprintf(&transcript, "%s: %s\n", role, truncateText(msg.Content, knowledgeMiningMessageMaxSize))
+	}
+	if !fromCustomer || !fromAgent {
+		return nil, errors.New(errors.ErrCodeNotFound, "conversation has no exchange to mine")
+	}
+
+	resp, err := provider.Complete(ctx, &CompletionRequest{
+		Messages: []Message{
+			{Role: "system", Content: "You curate knowledge base articles from customer service conversations and reply only with JSON."},
+			{Role: "user", Content: fmt.Sprintf(knowledgeMiningPrompt, maxKnowledgePairsPerMining, transcript.String())},
+		},
+		Model:       provider.DefaultModel(),
+		MaxTokens:   1500,
+		Temperature: 0,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to mine conversation")
+	}
+	pairs, err := parseKnowledgePairs(resp.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	candidates := make([]*entity.KnowledgeCandidate, 0, len(pairs))
+	for _, pair := range pairs {
+		candidate := &entity.KnowledgeCandidate{
+			ID:             uuid.New().String(),
+			TenantID:       conversation.TenantID,
+			ConversationID: conversation.ID,
+			Question:       pair.Question,
+			Answer:         pair.Answer,
+			Status:         entity.KnowledgeCandidatePending,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		if err := s.repo.CreateCandidate(ctx, candidate); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	if err := s.repo.MarkConversationMined(ctx, conversation.TenantID, conversation.ID, len(candidates)); err != nil {
+		return nil, err
+	}
+	return candidates, nil
+}
+
+type knowledgePair struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+}
+
+// parseKnowledgePairs reads the JSON array of Q&A pairs in an AI reply, dropping
+// incomplete pairs
+func parseKnowledgePairs(content string) ([]knowledgePair, error) {
+	start, end := strings.Index(content, "["), strings.LastIndex(content, "]")
+	if start < 0 || end < start {
+		return nil, errors.New(errors.ErrCodeInternal, "AI mining returned no JSON array")
+	}
+
+	var raw []knowledgePair
+	if err := json.Unmarshal([]byte(content[start:end+1]), &raw); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to parse AI mining")
+	}
+
+	pairs := make([]knowledgePair, 0, len(raw))
+	for _, pair := range raw {
+		pair.Question = strings.TrimSpace(pair.Question)
+		pair.Answer = strings.TrimSpace(pair.Answer)
+		if pair.Question == "" || pair.Answer == "" {
+			continue
+		}
+		pairs = append(pairs, pair)
+		if len(pairs) == maxKnowledgePairsPerMining {
+			break
+		}
+	}
+	return pairs, nil
+}
+
+// knowledgeMinable reports whether a conversation is resolved, after since when set
+func knowledgeMinable(conversation *entity.Conversation, since *time.Time) bool {
+	if conversation.Status != entity.ConversationStatusResolved && conversation.Status != entity.ConversationStatusClosed {
+		return false
+	}
+	if since != nil && (conversation.ResolvedAt == nil || conversation.ResolvedAt.Before(*since)) {
+		return false
+	}
+	return true
+}
+
+func applyCandidateEdits(candidate *entity.KnowledgeCandidate, question, answer *string) {
+	if question != nil {
+		if trimmed := strings.TrimSpace(*question); trimmed != candidate.Question {
+			candidate.Question = trimmed
+			candidate.Edited = true
+		}
+	}
+	if answer != nil {
+		if trimmed := strings.TrimSpace(*answer); trimmed != candidate.Answer {
+			candidate.Answer = trimmed
+			candidate.Edited = true
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockKnowledgeCurationRepo struct {
+	candidates map[string]*entity.KnowledgeCandidate
+	mined      map[string]int
+}
+
+func newMockKnowledgeCurationRepo() *mockKnowledgeCurationRepo {
+	return &mockKnowledgeCurationRepo{
+		candidates: make(map[string]*entity.KnowledgeCandidate),
+		mined:      make(map[string]int),
+	}
+}
+
+func (m *mockKnowledgeCurationRepo) CreateCandidate(ctx context.Context, candidate *entity.KnowledgeCandidate) error {
+	m.candidates[candidate.ID] = candidate
+	return nil
+}
+
+func (m *mockKnowledgeCurationRepo) FindCandidateByID(ctx context.Context, id string) (*entity.KnowledgeCandidate, error) {
+	if candidate, ok := m.candidates[id]; ok {
+		return candidate, nil
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "knowledge candidate not found")
+}
+
+func (m *mockKnowledgeCurationRepo) ListCandidates(ctx context.Context, tenantID string, filter *entity.KnowledgeCandidateFilter, params *repository.ListParams) ([]*entity.KnowledgeCandidate, int64, error) {
+	var candidates []*entity.KnowledgeCandidate
+	for _, candidate := range m.candidates {
+		if candidate.TenantID == tenantID {
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates, int64(len(candidates)), nil
+}
+
+func (m *mockKnowledgeCurationRepo) UpdateCandidate(ctx context.Context, candidate *entity.KnowledgeCandidate) error {
+	m.candidates[candidate.ID] = candidate
+	return nil
+}
+
+func (m *mockKnowledgeCurationRepo) MarkConversationMined(ctx context.Context, tenantID, conversationID string, candidates int) error {
+	m.mined[conversationID] = candidates
+	return nil
+}
+
+func (m *mockKnowledgeCurationRepo) IsConversationMined(ctx context.Context, conversationID string) (bool, error) {
+	_, ok := m.mined[conversationID]
+	return ok, nil
+}
+
+type knowledgeCurationTestEnv struct {
+	svc           *KnowledgeCurationService
+	repo          *mockKnowledgeCurationRepo
+	conversations *testutil.MockConversationRepository
+	messages      *testutil.MockMessageRepository
+	items         *stubKnowledgeItemRepo
+	ai            *extractingAIProvider
+}
+
+func newKnowledgeCurationTestEnv() *knowledgeCurationTestEnv {
+	kb := entity.NewKnowledgeBase("tenant-1", "Support answers", entity.KnowledgeTypeFAQ)
+	kb.ID = "kb-1"
+	otherKB := entity.NewKnowledgeBase("tenant-2", "Other tenant", entity.KnowledgeTypeFAQ)
+	otherKB.ID = "kb-2"
+	kbRepo := &stubKnowledgeBaseRepo{kbs: map[string]*entity.KnowledgeBase{kb.ID: kb, otherKB.ID: otherKB}}
+	items := &stubKnowledgeItemRepo{}
+
+	ai := &extractingAIProvider{mockAIProvider: mockAIProvider{name: entity.AIProviderOpenAI, available: true}}
+	factory := NewAIProviderFactory()
+	factory.Register(ai)
+
+	conversations := testutil.NewMockConversationRepository()
+	messages := testutil.NewMockMessageRepository()
+	repo := newMockKnowledgeCurationRepo()
+
+	return &knowledgeCurationTestEnv{
+		svc:           NewKnowledgeCurationService(repo, conversations, messages, NewKnowledgeService(kbRepo, items, nil, nil), factory),
+		repo:          repo,
+		conversations: conversations,
+		messages:      messages,
+		items:         items,
+		ai:            ai,
+	}
+}
+
+func (e *knowledgeCurationTestEnv) addConversation(id string, status entity.ConversationStatus, resolvedAt time.Time) {
+	e.conversations.Conversations[id] = &entity.Conversation{
+		ID:         id,
+		TenantID:   "tenant-1",
+		Status:     status,
+		ResolvedAt: &resolvedAt,
+	}
+	e.messages.Messages[id+"-1"] = &entity.Message{
+		ID: id + "-1", ConversationID: id, SenderType: entity.SenderTypeContact,
+		Content: "How do I reset my password? My email is ana@example.com", CreatedAt: resolvedAt.Add(-10 * time.Minute),
+	}
+	e.messages.Messages[id+"-2"] = &entity.Message{
+		ID: id + "-2", ConversationID: id, SenderType: entity.SenderTypeUser,
+		Content: "Click Forgot password on the login page and follow the emailed link.", CreatedAt: resolvedAt.Add(-5 * time.Minute),
+	}
+}
+
+func TestKnowledgeCurationService_Mine(t *testing.T) {
+	env := newKnowledgeCurationTestEnv()
+	ctx := context.Background()
+	resolvedAt := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+	env.addConversation("conv-1", entity.ConversationStatusResolved, resolvedAt)
+	env.addConversation("conv-open", entity.ConversationStatusOpen, resolvedAt)
+	env.ai.reply = "Here you go:\n" + `[{"question": "How do I reset my password?", "answer": "Click Forgot password on the login page and follow the emailed link."}, {"question": "", "answer": "dropped"}]`
+
+	result, err := env.svc.Mine(ctx, "tenant-1", &MineKnowledgeInput{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Conversations)
+	require.Len(t, result.Candidates, 1)
+
+	candidate := result.Candidates[0]
+	assert.Equal(t, "conv-1", candidate.ConversationID)
+	assert.Equal(t, "How do I reset my password?", candidate.Question)
+	assert.Equal(t, entity.KnowledgeCandidatePending, candidate.Status)
+	assert.Contains(t, env.ai.prompt, "Customer: How do I reset my password?")
+	assert.Contains(t, env.ai.prompt, "Agent: Click Forgot password")
+	assert.Equal(t, 1, env.repo.mined["conv-1"])
+
+	// Mined conversations are not read again
+	result, err = env.svc.Mine(ctx, "tenant-1", &MineKnowledgeInput{})
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Conversations)
+	assert.Equal(t, 1, result.Skipped)
+	assert.Len(t, env.repo.candidates, 1)
+}
+
+func TestKnowledgeCurationService_MineSelection(t *testing.T) {
+	env := newKnowledgeCurationTestEnv()
+	ctx := context.Background()
+	resolvedAt := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+	env.addConversation("conv-old", entity.ConversationStatusResolved, resolvedAt.AddDate(0, -1, 0))
+	env.addConversation("conv-open", entity.ConversationStatusOpen, resolvedAt)
+	env.ai.reply = "[]"
+
+	since := resolvedAt.AddDate(0, 0, -7)
+	result, err := env.svc.Mine(ctx, "tenant-1", &MineKnowledgeInput{Since: &since})
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Conversations, "conversations resolved before since are left out")
+
+	_, err = env.svc.Mine(ctx, "tenant-1", &MineKnowledgeInput{ConversationIDs: []string{"conv-open"}})
+	assert.Error(t, err, "open conversations cannot be mined")
+
+	_, err = env.svc.Mine(ctx, "tenant-2", &MineKnowledgeInput{ConversationIDs: []string{"conv-old"}})
+	assert.Error(t, err, "conversations of other tenants cannot be mined")
+
+	result, err = env.svc.Mine(ctx, "tenant-1", &MineKnowledgeInput{ConversationIDs: []string{"conv-old"}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Conversations)
+	assert.Empty(t, result.Candidates)
+	_, mined := env.repo.mined["conv-old"]
+	assert.True(t, mined, "conversations without reusable pairs are not mined again")
+
+	_, err = env.svc.Mine(ctx, "tenant-1", &MineKnowledgeInput{Limit: 51})
+	assert.Error(t, err)
+}
+
+func TestKnowledgeCurationService_MineFailureIsRetried(t *testing.T) {
+	env := newKnowledgeCurationTestEnv()
+	ctx := context.Background()
+	env.addConversation("conv-1", entity.ConversationStatusResolved, time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC))
+	env.ai.reply = "Sorry, I cannot help with that."
+
+	result, err := env.svc.Mine(ctx, "tenant-1", &MineKnowledgeInput{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Failed)
+	_, mined := env.repo.mined["conv-1"]
+	assert.False(t, mined)
+}
+
+func TestKnowledgeCurationService_Publish(t *testing.T) {
+	env := newKnowledgeCurationTestEnv()
+	ctx := context.Background()
+	env.repo.candidates["cand-1"] = &entity.KnowledgeCandidate{
+		ID: "cand-1", TenantID: "tenant-1", ConversationID: "conv-1",
+		Question: "How do I reset my password?", Answer: "Use the reset link.",
+		Status: entity.KnowledgeCandidatePending,
+	}
+
+	_, err := env.svc.Publish(ctx, "tenant-1", "cand-1", "user-1", &PublishKnowledgeCandidateInput{KnowledgeBaseID: "kb-2"})
+	assert.Error(t, err, "knowledge bases of other tenants cannot be used")
+
+	answer := "Click Forgot password on the login page."
+	candidate, err := env.svc.Publish(ctx, "tenant-1", "cand-1", "user-1", &PublishKnowledgeCandidateInput{KnowledgeBaseID: "kb-1", Answer: &answer})
+	require.NoError(t, err)
+	assert.Equal(t, entity.KnowledgeCandidatePublished, candidate.Status)
+	assert.True(t, candidate.Edited)
+	assert.Equal(t, "kb-1", candidate.KnowledgeBaseID)
+	assert.Equal(t, "user-1", candidate.ReviewedBy)
+
+	require.Len(t, env.items.items, 1)
+	item := env.items.items[0]
+	assert.Equal(t, item.ID, candidate.ItemID)
+	assert.Equal(t, answer, item.Answer)
+	assert.Equal(t, "conversation:conv-1", item.Source)
+	assert.Equal(t, "conv-1", item.Metadata["conversation_id"])
+
+	_, err = env.svc.Reject(ctx, "tenant-1", "cand-1", "user-1")
+	assert.Error(t, err, "published candidates cannot be rejected")
+}
+
+func TestKnowledgeCurationService_UpdateAndReject(t *testing.T) {
+	env := newKnowledgeCurationTestEnv()
+	ctx := context.Background()
+	env.repo.candidates["cand-1"] = &entity.KnowledgeCandidate{
+		ID: "cand-1", TenantID: "tenant-1", ConversationID: "conv-1",
+		Question: "Reset password?", Answer: "Use the reset link.",
+		Status: entity.KnowledgeCandidatePending,
+	}
+
+	same := "Use the reset link."
+	candidate, err := env.svc.UpdateCandidate(ctx, "tenant-1", "cand-1", &KnowledgeCandidateInput{Answer: &same})
+	require.NoError(t, err)
+	assert.False(t, candidate.Edited)
+
+	question := "  How do I reset my password? "
+	candidate, err = env.svc.UpdateCandidate(ctx, "tenant-1", "cand-1", &KnowledgeCandidateInput{Question: &question})
+	require.NoError(t, err)
+	assert.Equal(t, "How do I reset my password?", candidate.Question)
+	assert.True(t, candidate.Edited)
+
+	_, err = env.svc.GetCandidate(ctx, "tenant-2", "cand-1")
+	assert.True(t, errors.IsNotFound(err))
+
+	candidate, err = env.svc.Reject(ctx, "tenant-1", "cand-1", "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, entity.KnowledgeCandidateRejected, candidate.Status)
+	assert.Empty(t, env.items.items)
+}
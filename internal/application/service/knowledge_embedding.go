@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// JobTypeKnowledgeEmbeddings regenerates the embeddings of every item of a knowledge base
+const JobTypeKnowledgeEmbeddings = "knowledge.embeddings"
+
+// knowledgeEmbeddingPageSize is how many items are embedded between progress reports
+const knowledgeEmbeddingPageSize = 100
+
+// SetJobService regenerates embeddings as background jobs reporting their progress.
+// Without it they are regenerated within the request.
+func (s *KnowledgeService) SetJobService(jobs *JobService) {
+	s.jobs = jobs
+}
+
+// RegenerateEmbeddings regenerates the embeddings of all items in a knowledge base.
+// With a job service it returns the job doing it; otherwise it returns nil once done.
+func (s *KnowledgeService) RegenerateEmbeddings(ctx context.Context, knowledgeBaseID, userID string) (*entity.Job, error) {
+	if s.embeddingService == nil || !s.embeddingService.IsAvailable() {
+		return nil, errors.New(errors.ErrCodeBadRequest, "embedding service not available")
+	}
+
+	kb, err := s.kbRepo.FindByID(ctx, knowledgeBaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Jobs belong to tenants, so global knowledge bases are embedded right away
+	if s.jobs == nil || kb.Global {
+		_, err := s.regenerateEmbeddings(ctx, kb, nil)
+		return nil, err
+	}
+
+	return s.jobs.Enqueue(ctx, kb.TenantID, &JobInput{
+		Type:      JobTypeKnowledgeEmbeddings,
+		Payload:   map[string]interface{}{"knowledge_base_id": kb.ID},
+		CreatedBy: userID,
+	})
+}
+
+// RunEmbeddingJob is the JobHandler of JobTypeKnowledgeEmbeddings. Embeddings are
+// cached by content, so a retried job only pays for the items it had not reached.
+func (s *KnowledgeService) RunEmbeddingJob(ctx context.Context, job *entity.Job, progress JobProgress) (map[string]interface{}, error) {
+	id, _ := job.Payload["knowledge_base_id"].(string)
+	if id == "" {
+		return nil, errors.Validation("invalid knowledge embedding job payload")
+	}
+	kb, err := s.kbRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, errors.Validation("knowledge base no longer exists")
+		}
+		return nil, err
+	}
+	if kb.TenantID != job.TenantID {
+		return nil, errors.Validation("knowledge base no longer exists")
+	}
+	if s.embeddingService == nil || !s.embeddingService.IsAvailable() {
+		return nil, errors.Validation("embedding service not available")
+	}
+
+	return s.regenerateEmbeddings(ctx, kb, progress)
+}
+
+// regenerateEmbeddings embeds the items of a knowledge base page by page, reporting
+// progress after each page when progress is set
+func (s *KnowledgeService) regenerateEmbeddings(ctx context.Context, kb *entity.KnowledgeBase, progress JobProgress) (map[string]interface{}, error) {
+	ctx = WithAIUsage(ctx, kb.TenantID, "", entity.AIFeatureEmbedding)
+	kb.MarkSyncing()
+	s.kbRepo.Update(ctx, kb)
+
+	var processed, cacheHits, generated int
+	for page := 1; ; page++ {
+		items, total, err := s.itemRepo.FindByKnowledgeBase(ctx, kb.ID, &repository.ListParams{
+			Page:     page,
+			PageSize: knowledgeEmbeddingPageSize,
+			SortBy:   "created_at",
+			SortDir:  "asc",
+		})
+		if err != nil {
+			s.abortSync(ctx, kb)
+			return nil, err
+		}
+		if len(items) == 0 {
+			break
+		}
+
+		texts := make([]string, len(items))
+		for i, item := range items {
+			texts[i] = item.Question + " " + item.Answer
+		}
+		result, err := s.embeddingService.EmbedBatch(ctx, texts)
+		if err != nil {
+			s.abortSync(ctx, kb)
+			return nil, err
+		}
+		cacheHits += result.CacheHits
+		generated += result.Generated
+
+		for i, item := range items {
+			item.SetEmbedding(result.Embeddings[i])
+			if err := s.itemRepo.Update(ctx, item); err != nil {
+				s.abortSync(ctx, kb)
+				return nil, err
+			}
+
+			// Update vector store; drafts are stored when published
+			if s.vectorStore != nil && item.IsPublished() {
+				s.vectorStore.Delete(ctx, item.ID)
+				s.vectorStore.Store(ctx, item.ID, result.Embeddings[i], vectorMetadata(item))
+			}
+		}
+		processed += len(items)
+
+		if progress != nil && total > 0 {
+			percent := int(int64(processed) * 100 / total)
+			if err := progress(percent, fmt.Sprintf("%d of %d items embedded", processed, total)); err != nil {
+				s.abortSync(ctx, kb)
+				return nil, err
+			}
+		}
+		if int64(processed) >= total {
+			break
+		}
+	}
+
+	kb.MarkSynced()
+	s.kbRepo.Update(ctx, kb)
+
+	return map[string]interface{}{
+		"knowledge_base_id": kb.ID,
+		"items":             processed,
+		"cache_hits":        cacheHits,
+		"generated":         generated,
+	}, nil
+}
+
+// abortSync puts a knowledge base whose embedding run stopped back to active
+// without recording a sync
+func (s *KnowledgeService) abortSync(ctx context.Context, kb *entity.KnowledgeBase) {
+	kb.Status = entity.KnowledgeStatusActive
+	s.kbRepo.Update(ctx, kb)
+}
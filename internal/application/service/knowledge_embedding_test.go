@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKnowledgeService_RegenerateEmbeddingsJob(t *testing.T) {
+	ctx := context.Background()
+	embeddings, provider, _ := newBatchEmbeddingService(2)
+	kbRepo, itemRepo := newMockKnowledgeBaseRepo(), newMockKnowledgeItemRepo()
+	svc := NewKnowledgeService(kbRepo, itemRepo, embeddings, nil)
+
+	jobs, jobRepo, queue, _ := newQueuedJobService()
+	jobs.Register(JobTypeKnowledgeEmbeddings, svc.RunEmbeddingJob)
+	svc.SetJobService(jobs)
+
+	kb, err := svc.CreateKnowledgeBase(ctx, &CreateKnowledgeBaseInput{TenantID: "tenant-1", Name: "Support", Type: entity.KnowledgeTypeFAQ})
+	require.NoError(t, err)
+	for _, question := range []string{"How to pay?", "How to cancel?", "How to pay?"} {
+		_, err := svc.AddItem(ctx, &AddItemInput{KnowledgeBaseID: kb.ID, Question: question, Answer: "See the help center"})
+		require.NoError(t, err)
+	}
+	for _, item := range itemRepo.items {
+		item.Embedding = nil
+	}
+	provider.batches = nil
+
+	job, err := svc.RegenerateEmbeddings(ctx, kb.ID, "user-1")
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, JobTypeKnowledgeEmbeddings, job.Type)
+	assert.Equal(t, "tenant-1", job.TenantID)
+	require.Len(t, queue.requests, 1)
+
+	require.NoError(t, jobs.Process(ctx, job.ID))
+
+	stored := jobRepo.jobs[job.ID]
+	assert.Equal(t, entity.JobStatusSucceeded, stored.Status)
+	assert.Equal(t, 100, stored.Progress)
+	assert.Equal(t, 3, stored.Result["items"])
+	assert.Equal(t, 3, stored.Result["cache_hits"], "texts embedded when the items were added come from the cache")
+	assert.Empty(t, provider.batches)
+
+	for _, item := range itemRepo.items {
+		assert.True(t, item.HasEmbedding())
+	}
+	assert.Equal(t, entity.KnowledgeStatusActive, kbRepo.bases[kb.ID].Status)
+	assert.NotNil(t, kbRepo.bases[kb.ID].LastSyncAt)
+}
+
+func TestKnowledgeService_RunEmbeddingJob_OtherTenant(t *testing.T) {
+	ctx := context.Background()
+	embeddings, _, _ := newBatchEmbeddingService(0)
+	svc := NewKnowledgeService(newMockKnowledgeBaseRepo(), newMockKnowledgeItemRepo(), embeddings, nil)
+
+	kb, err := svc.CreateKnowledgeBase(ctx, &CreateKnowledgeBaseInput{TenantID: "tenant-1", Name: "Support", Type: entity.KnowledgeTypeFAQ})
+	require.NoError(t, err)
+
+	_, err = svc.RunEmbeddingJob(ctx, &entity.Job{
+		TenantID: "tenant-2",
+		Payload:  map[string]interface{}{"knowledge_base_id": kb.ID},
+	}, nil)
+	assert.True(t, errors.IsValidation(err))
+}
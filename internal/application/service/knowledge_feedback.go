@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Answer feedback defaults
+const (
+	DefaultAnswerFeedbackPrompt = "Did this help?"
+	DefaultAnswerFeedbackYes    = "Yes"
+	DefaultAnswerFeedbackNo     = "No"
+	// DefaultKnowledgeQualityMinResponses is how many ratings an item needs before it can be flagged
+	DefaultKnowledgeQualityMinResponses = 5
+	// KnowledgeLowPerformingRate is the helpful rate below which an item is flagged
+	KnowledgeLowPerformingRate = 0.5
+	// maxFeedbackQuestionLength caps the bytes of the customer question carried in a postback payload
+	maxFeedbackQuestionLength = 500
+)
+
+// AnswerFeedbackPromptInput describes the bot answer to ask feedback for
+type AnswerFeedbackPromptInput struct {
+	TenantID        string
+	ConversationID  string
+	MessageID       string // The bot answer
+	Question        string // Customer message the bot answered
+	KnowledgeBaseID string
+	ItemIDs         []string
+	Config          *entity.AnswerFeedbackConfig
+}
+
+// AnswerFeedbackPrompt is the follow-up message asking whether an answer helped
+type AnswerFeedbackPrompt struct {
+	Text         string              `json:"text"`
+	QuickReplies []entity.QuickReply `json:"quick_replies"`
+}
+
+// KnowledgeFeedbackService closes the loop between customers and the knowledge base.
+// Bot answers built from knowledge items are followed by a "did this help?" prompt
+// whose buttons are postbacks; ratings are stored against the items used, roll up
+// into a quality report, and unhelpful answers queue their question as a knowledge gap.
+type KnowledgeFeedbackService struct {
+	repo      repository.KnowledgeFeedbackRepository
+	postbacks *PostbackService
+}
+
+// NewKnowledgeFeedbackService creates a new knowledge feedback service
+func NewKnowledgeFeedbackService(repo repository.KnowledgeFeedbackRepository, postbacks *PostbackService) *KnowledgeFeedbackService {
+	return &KnowledgeFeedbackService{
+		repo:      repo,
+		postbacks: postbacks,
+	}
+}
+
+// BuildPrompt registers the yes/no postbacks of an answer and returns the prompt to send
+func (s *KnowledgeFeedbackService) BuildPrompt(ctx context.Context, input *AnswerFeedbackPromptInput) (*AnswerFeedbackPrompt, error) {
+	if input.MessageID == "" || input.KnowledgeBaseID == "" {
+		return nil, errors.Validation("message and knowledge base are required")
+	}
+
+	config := input.Config
+	if config == nil {
+		config = &entity.AnswerFeedbackConfig{}
+	}
+	prompt := &AnswerFeedbackPrompt{Text: config.Prompt}
+	if prompt.Text == "" {
+		prompt.Text = DefaultAnswerFeedbackPrompt
+	}
+	yes, no := config.YesLabel, config.NoLabel
+	if yes == "" {
+		yes = DefaultAnswerFeedbackYes
+	}
+	if no == "" {
+		no = DefaultAnswerFeedbackNo
+	}
+
+	options := []struct {
+		helpful bool
+		label   string
+	}{
+		{true, yes},
+		{false, no},
+	}
+	for _, option := range options {
+		registered, err := s.postbacks.Register(ctx, input.TenantID, &RegisterPostbackInput{
+			ConversationID: input.ConversationID,
+			Action:         entity.PostbackActionAnswerFeedback,
+			Target:         input.MessageID,
+			Payload: map[string]string{
+				"helpful":           strconv.FormatBool(option.helpful),
+				"knowledge_base_id": input.KnowledgeBaseID,
+				"item_ids":          strings.Join(input.ItemIDs, ","),
+				"question":          truncateText(input.Question, maxFeedbackQuestionLength),
+			},
+			SingleUse: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		prompt.QuickReplies = append(prompt.QuickReplies, entity.QuickReply{
+			ID:    registered.Token,
+			Title: option.label,
+		})
+	}
+
+	return prompt, nil
+}
+
+// RecordAnswerFeedback stores the rating carried by an answer feedback postback.
+// Unhelpful answers queue the customer's question as a knowledge gap.
+func (s *KnowledgeFeedbackService) RecordAnswerFeedback(ctx context.Context, postback *entity.Postback, message *entity.Message, conversation *entity.Conversation) (*entity.KnowledgeFeedback, error) {
+	knowledgeBaseID := postback.Payload["knowledge_base_id"]
+	if knowledgeBaseID == "" {
+		return nil, errors.New(errors.ErrCodeBadRequest, "answer feedback postback has no knowledge base")
+	}
+	helpful, err := strconv.ParseBool(postback.Payload["helpful"])
+	if err != nil {
+		return nil, errors.New(errors.ErrCodeBadRequest, "answer feedback postback has no rating")
+	}
+
+	now := time.Now()
+	feedback := &entity.KnowledgeFeedback{
+		ID:              uuid.New().String(),
+		TenantID:        postback.TenantID,
+		KnowledgeBaseID: knowledgeBaseID,
+		ItemIDs:         splitItemIDs(postback.Payload["item_ids"]),
+		ConversationID:  conversation.ID,
+		MessageID:       postback.Target,
+		ContactID:       conversation.ContactID,
+		Question:        postback.Payload["question"],
+		Helpful:         helpful,
+		CreatedAt:       now,
+	}
+	if err := s.repo.SaveFeedback(ctx, feedback); err != nil {
+		return nil, err
+	}
+
+	if !helpful && feedback.Question != "" {
+		gap := &entity.KnowledgeGap{
+			ID:              uuid.New().String(),
+			TenantID:        feedback.TenantID,
+			KnowledgeBaseID: feedback.KnowledgeBaseID,
+			Question:        feedback.Question,
+			Source:          entity.KnowledgeGapSourceNegativeFeedback,
+			ItemIDs:         feedback.ItemIDs,
+			ConversationID:  feedback.ConversationID,
+			Occurrences:     1,
+			Status:          entity.KnowledgeGapOpen,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		}
+		// The rating is already stored; a failed gap only delays the editor
+		if err := s.repo.RecordGap(ctx, gap); err != nil {
+			logger.Warn("Failed to queue knowledge gap",
+				zap.String("knowledge_base_id", feedback.KnowledgeBaseID),
+				zap.String("conversation_id", feedback.ConversationID),
+				zap.Error(err),
+			)
+		}
+	}
+
+	return feedback, nil
+}
+
+// QualityReport summarizes the feedback on a knowledge base. Items with at least
+// minResponses ratings and a helpful rate below KnowledgeLowPerformingRate are
+// flagged as low performing; items are sorted worst first.
+func (s *KnowledgeFeedbackService) QualityReport(ctx context.Context, tenantID, knowledgeBaseID string, minResponses int) (*entity.KnowledgeQualityReport, error) {
+	if minResponses <= 0 {
+		minResponses = DefaultKnowledgeQualityMinResponses
+	}
+
+	items, err := s.repo.ItemQuality(ctx, tenantID, knowledgeBaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &entity.KnowledgeQualityReport{
+		KnowledgeBaseID: knowledgeBaseID,
+		Items:           make([]*entity.KnowledgeItemQuality, 0, len(items)),
+	}
+	var helpful int64
+	for _, item := range items {
+		responses := item.Responses()
+		if responses == 0 {
+			continue
+		}
+		item.HelpfulRate = float64(item.Helpful) / float64(responses)
+		item.LowPerforming = responses >= int64(minResponses) && item.HelpfulRate < KnowledgeLowPerformingRate
+		if item.LowPerforming {
+			report.LowPerformingCount++
+		}
+		report.Responses += responses
+		helpful += item.Helpful
+		report.Items = append(report.Items, item)
+	}
+	if report.Responses > 0 {
+		report.HelpfulRate = float64(helpful) / float64(report.Responses)
+	}
+
+	sort.SliceStable(report.Items, func(i, j int) bool {
+		a, b := report.Items[i], report.Items[j]
+		if a.LowPerforming != b.LowPerforming {
+			return a.LowPerforming
+		}
+		if a.HelpfulRate != b.HelpfulRate {
+			return a.HelpfulRate < b.HelpfulRate
+		}
+		return a.Responses() > b.Responses()
+	})
+
+	return report, nil
+}
+
+// ListGaps lists the knowledge gaps of a tenant, most frequent first
+func (s *KnowledgeFeedbackService) ListGaps(ctx context.Context, tenantID string, filter *entity.KnowledgeGapFilter, params *repository.ListParams) ([]*entity.KnowledgeGap, int64, error) {
+	return s.repo.ListGaps(ctx, tenantID, filter, params)
+}
+
+// UpdateGapStatus resolves or dismisses a knowledge gap
+func (s *KnowledgeFeedbackService) UpdateGapStatus(ctx context.Context, tenantID, id, userID string, status entity.KnowledgeGapStatus) (*entity.KnowledgeGap, error) {
+	if status != entity.KnowledgeGapResolved && status != entity.KnowledgeGapDismissed {
+		return nil, errors.Validation("invalid knowledge gap status")
+	}
+
+	gap, err := s.repo.FindGapByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if gap.TenantID != tenantID {
+		return nil, errors.NotFound("knowledge gap")
+	}
+	if gap.Status != entity.KnowledgeGapOpen {
+		return nil, errors.New(errors.ErrCodeConflict, "knowledge gap is already "+string(gap.Status))
+	}
+
+	now := time.Now()
+	gap.Status = status
+	gap.ResolvedBy = userID
+	gap.ResolvedAt = &now
+	gap.UpdatedAt = now
+	if err := s.repo.UpdateGap(ctx, gap); err != nil {
+		return nil, err
+	}
+	return gap, nil
+}
+
+// splitItemIDs parses the comma-separated item IDs of a postback payload
+func splitItemIDs(value string) []string {
+	ids := []string{}
+	for _, id := range strings.Split(value, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockKnowledgeFeedbackRepository struct {
+	feedback map[string]*entity.KnowledgeFeedback
+	gaps     map[string]*entity.KnowledgeGap
+	quality  []*entity.KnowledgeItemQuality
+}
+
+func newMockKnowledgeFeedbackRepository() *mockKnowledgeFeedbackRepository {
+	return &mockKnowledgeFeedbackRepository{
+		feedback: make(map[string]*entity.KnowledgeFeedback),
+		gaps:     make(map[string]*entity.KnowledgeGap),
+	}
+}
+
+func (m *mockKnowledgeFeedbackRepository) SaveFeedback(ctx context.Context, feedback *entity.KnowledgeFeedback) error {
+	m.feedback[feedback.MessageID] = feedback
+	return nil
+}
+
+func (m *mockKnowledgeFeedbackRepository) ItemQuality(ctx context.Context, tenantID, knowledgeBaseID string) ([]*entity.KnowledgeItemQuality, error) {
+	return m.quality, nil
+}
+
+func (m *mockKnowledgeFeedbackRepository) RecordGap(ctx context.Context, gap *entity.KnowledgeGap) error {
+	for _, existing := range m.gaps {
+		if existing.Status == entity.KnowledgeGapOpen && existing.KnowledgeBaseID == gap.KnowledgeBaseID &&
+			strings.EqualFold(existing.Question, gap.Question) {
+			existing.Occurrences++
+			*gap = *existing
+			return nil
+		}
+	}
+	m.gaps[gap.ID] = gap
+	return nil
+}
+
+func (m *mockKnowledgeFeedbackRepository) FindGapByID(ctx context.Context, id string) (*entity.KnowledgeGap, error) {
+	gap, ok := m.gaps[id]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, "knowledge gap not found")
+	}
+	copied := *gap
+	return &copied, nil
+}
+
+func (m *mockKnowledgeFeedbackRepository) ListGaps(ctx context.Context, tenantID string, filter *entity.KnowledgeGapFilter, params *repository.ListParams) ([]*entity.KnowledgeGap, int64, error) {
+	var gaps []*entity.KnowledgeGap
+	for _, gap := range m.gaps {
+		if gap.TenantID == tenantID {
+			gaps = append(gaps, gap)
+		}
+	}
+	return gaps, int64(len(gaps)), nil
+}
+
+func (m *mockKnowledgeFeedbackRepository) UpdateGap(ctx context.Context, gap *entity.KnowledgeGap) error {
+	m.gaps[gap.ID] = gap
+	return nil
+}
+
+func newTestKnowledgeFeedbackService() (*KnowledgeFeedbackService, *PostbackService, *mockKnowledgeFeedbackRepository) {
+	postbacks, _, _, _ := newTestPostbackService()
+	repo := newMockKnowledgeFeedbackRepository()
+	svc := NewKnowledgeFeedbackService(repo, postbacks)
+	postbacks.SetAnswerFeedbackRecorder(svc)
+	return svc, postbacks, repo
+}
+
+func buildTestFeedbackPrompt(t *testing.T, svc *KnowledgeFeedbackService) *AnswerFeedbackPrompt {
+	prompt, err := svc.BuildPrompt(context.Background(), &AnswerFeedbackPromptInput{
+		TenantID:        "tenant-1",
+		ConversationID:  "conv-1",
+		MessageID:       "answer-1",
+		Question:        "How do I reset my password?",
+		KnowledgeBaseID: "kb-1",
+		ItemIDs:         []string{"item-1", "item-2"},
+		Config:          &entity.AnswerFeedbackConfig{Enabled: true, YesLabel: "Sim"},
+	})
+	require.NoError(t, err)
+	return prompt
+}
+
+func TestKnowledgeFeedbackService_BuildPrompt(t *testing.T) {
+	svc, _, _ := newTestKnowledgeFeedbackService()
+
+	prompt := buildTestFeedbackPrompt(t, svc)
+	assert.Equal(t, DefaultAnswerFeedbackPrompt, prompt.Text)
+	require.Len(t, prompt.QuickReplies, 2)
+	assert.Equal(t, "Sim", prompt.QuickReplies[0].Title)
+	assert.Equal(t, DefaultAnswerFeedbackNo, prompt.QuickReplies[1].Title)
+	for _, reply := range prompt.QuickReplies {
+		assert.True(t, IsPostbackToken(reply.ID))
+	}
+}
+
+func TestKnowledgeFeedbackService_HelpfulAnswer(t *testing.T) {
+	svc, postbacks, repo := newTestKnowledgeFeedbackService()
+	prompt := buildTestFeedbackPrompt(t, svc)
+
+	result, err := routeReply(postbacks, prompt.QuickReplies[0].ID, "Sim")
+	require.NoError(t, err)
+	require.NotNil(t, result.Feedback)
+	assert.True(t, result.Feedback.Helpful)
+	assert.Equal(t, []string{"item-1", "item-2"}, result.Feedback.ItemIDs)
+	assert.Equal(t, "contact-1", result.Feedback.ContactID)
+	assert.Equal(t, result.Feedback, repo.feedback["answer-1"])
+	assert.Empty(t, repo.gaps)
+}
+
+func TestKnowledgeFeedbackService_NegativeFeedbackQueuesGap(t *testing.T) {
+	svc, postbacks, repo := newTestKnowledgeFeedbackService()
+
+	for i := 0; i < 2; i++ {
+		prompt := buildTestFeedbackPrompt(t, svc)
+		result, err := routeReply(postbacks, prompt.QuickReplies[1].ID, "No")
+		require.NoError(t, err)
+		assert.False(t, result.Feedback.Helpful)
+	}
+
+	require.Len(t, repo.gaps, 1, "repeated questions raise the open gap")
+	for _, gap := range repo.gaps {
+		assert.Equal(t, "How do I reset my password?", gap.Question)
+		assert.Equal(t, entity.KnowledgeGapSourceNegativeFeedback, gap.Source)
+		assert.Equal(t, 2, gap.Occurrences)
+		assert.Equal(t, entity.KnowledgeGapOpen, gap.Status)
+	}
+}
+
+func TestKnowledgeFeedbackService_QualityReport(t *testing.T) {
+	svc, _, repo := newTestKnowledgeFeedbackService()
+	repo.quality = []*entity.KnowledgeItemQuality{
+		{ItemID: "good", Helpful: 9, NotHelpful: 1},
+		{ItemID: "bad", Helpful: 1, NotHelpful: 5},
+		{ItemID: "few", Helpful: 0, NotHelpful: 2},
+	}
+
+	report, err := svc.QualityReport(context.Background(), "tenant-1", "kb-1", 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(18), report.Responses)
+	assert.InDelta(t, 10.0/18.0, report.HelpfulRate, 0.001)
+	assert.Equal(t, 1, report.LowPerformingCount)
+	require.Len(t, report.Items, 3)
+	assert.Equal(t, "bad", report.Items[0].ItemID)
+	assert.True(t, report.Items[0].LowPerforming)
+	assert.Equal(t, "few", report.Items[1].ItemID)
+	assert.False(t, report.Items[1].LowPerforming, "too few ratings to flag")
+	assert.Equal(t, "good", report.Items[2].ItemID)
+}
+
+func TestKnowledgeFeedbackService_UpdateGapStatus(t *testing.T) {
+	svc, _, repo := newTestKnowledgeFeedbackService()
+	repo.gaps["gap-1"] = &entity.KnowledgeGap{ID: "gap-1", TenantID: "tenant-1", Status: entity.KnowledgeGapOpen}
+	ctx := context.Background()
+
+	_, err := svc.UpdateGapStatus(ctx, "tenant-2", "gap-1", "user-1", entity.KnowledgeGapResolved)
+	assert.Equal(t, errors.ErrCodeNotFound, errors.GetAppError(err).Code)
+
+	_, err = svc.UpdateGapStatus(ctx, "tenant-1", "gap-1", "user-1", entity.KnowledgeGapOpen)
+	assert.Error(t, err)
+
+	gap, err := svc.UpdateGapStatus(ctx, "tenant-1", "gap-1", "user-1", entity.KnowledgeGapResolved)
+	require.NoError(t, err)
+	assert.Equal(t, entity.KnowledgeGapResolved, gap.Status)
+	assert.Equal(t, "user-1", gap.ResolvedBy)
+	assert.NotNil(t, gap.ResolvedAt)
+
+	_, err = svc.UpdateGapStatus(ctx, "tenant-1", "gap-1", "user-1", entity.KnowledgeGapDismissed)
+	assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code)
+}
@@ -0,0 +1,521 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+	"net/mail"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/ocr"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Knowledge inbox limits
+const (
+	// DefaultKnowledgeChunkSize is the item size used when the knowledge base sets none
+	DefaultKnowledgeChunkSize = 2000
+	knowledgeIngestTimeout    = 5 * time.Minute
+)
+
+var (
+	htmlTagPattern    = regexp.MustCompile(`(?s)<(script|style)[^>]*>.*?</(script|style)>|<[^>]+>`)
+	blankLinesPattern = regexp.MustCompile(`\n{3,}`)
+)
+
+// KnowledgeInboxInput represents input for creating or updating a knowledge inbox
+type KnowledgeInboxInput struct {
+	Name            string   `json:"name" binding:"required"`
+	Address         string   `json:"address" binding:"required"`
+	KnowledgeBaseID string   `json:"knowledge_base_id" binding:"required"`
+	AllowedSenders  []string `json:"allowed_senders,omitempty"`
+	IncludeBody     *bool    `json:"include_body,omitempty"` // Defaults to true
+	IsActive        *bool    `json:"is_active,omitempty"`
+}
+
+// KnowledgeSubmissionInput represents a reviewer's edits to a submission
+type KnowledgeSubmissionInput struct {
+	Title   *string `json:"title,omitempty"`
+	Content *string `json:"content,omitempty"`
+}
+
+// KnowledgeInboxService turns mail sent to designated addresses into knowledge base
+// content. Bodies and attachments of inbound emails become submissions that a
+// reviewer edits and approves before they are published as knowledge items.
+type KnowledgeInboxService struct {
+	repo             repository.KnowledgeInboxRepository
+	knowledgeService *KnowledgeService
+	engine           ocr.Engine
+	httpClient       *http.Client
+}
+
+// NewKnowledgeInboxService creates a new knowledge inbox service
+func NewKnowledgeInboxService(repo repository.KnowledgeInboxRepository, knowledgeService *KnowledgeService, engine ocr.Engine) *KnowledgeInboxService {
+	return &KnowledgeInboxService{
+		repo:             repo,
+		knowledgeService: knowledgeService,
+		engine:           engine,
+		httpClient:       newDocumentClient(),
+	}
+}
+
+// CreateInbox creates a new knowledge inbox
+func (s *KnowledgeInboxService) CreateInbox(ctx context.Context, tenantID string, input *KnowledgeInboxInput) (*entity.KnowledgeInbox, error) {
+	address, err := s.validateInbox(ctx, tenantID, "", input)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	inbox := &entity.KnowledgeInbox{
+		ID:              uuid.New().String(),
+		TenantID:        tenantID,
+		Name:            input.Name,
+		Address:         address,
+		KnowledgeBaseID: input.KnowledgeBaseID,
+		AllowedSenders:  input.AllowedSenders,
+		IncludeBody:     input.IncludeBody == nil || *input.IncludeBody,
+		IsActive:        input.IsActive == nil || *input.IsActive,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := s.repo.Create(ctx, inbox); err != nil {
+		return nil, err
+	}
+	return inbox, nil
+}
+
+// GetInbox returns a knowledge inbox of the tenant
+func (s *KnowledgeInboxService) GetInbox(ctx context.Context, tenantID, id string) (*entity.KnowledgeInbox, error) {
+	inbox, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if inbox.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeNotFound, "knowledge inbox not found")
+	}
+	return inbox, nil
+}
+
+// ListInboxes lists the knowledge inboxes of a tenant
+func (s *KnowledgeInboxService) ListInboxes(ctx context.Context, tenantID string) ([]*entity.KnowledgeInbox, error) {
+	return s.repo.ListByTenant(ctx, tenantID)
+}
+
+// UpdateInbox updates a knowledge inbox
+func (s *KnowledgeInboxService) UpdateInbox(ctx context.Context, tenantID, id string, input *KnowledgeInboxInput) (*entity.KnowledgeInbox, error) {
+	inbox, err := s.GetInbox(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	address, err := s.validateInbox(ctx, tenantID, id, input)
+	if err != nil {
+		return nil, err
+	}
+
+	inbox.Name = input.Name
+	inbox.Address = address
+	inbox.KnowledgeBaseID = input.KnowledgeBaseID
+	inbox.AllowedSenders = input.AllowedSenders
+	if input.IncludeBody != nil {
+		inbox.IncludeBody = *input.IncludeBody
+	}
+	if input.IsActive != nil {
+		inbox.IsActive = *input.IsActive
+	}
+	inbox.UpdatedAt = time.Now()
+
+	if err := s.repo.Update(ctx, inbox); err != nil {
+		return nil, err
+	}
+	return inbox, nil
+}
+
+// DeleteInbox deletes a knowledge inbox and its unpublished submissions
+func (s *KnowledgeInboxService) DeleteInbox(ctx context.Context, tenantID, id string) error {
+	if _, err := s.GetInbox(ctx, tenantID, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// HandleInbound ingests an email addressed to one of the tenant's knowledge inboxes.
+// Downloads and text extraction run in the background.
+func (s *KnowledgeInboxService) HandleInbound(ctx context.Context, message *entity.Message, conversation *entity.Conversation) error {
+	if message.SenderType != entity.SenderTypeContact || message.Metadata == nil {
+		return nil
+	}
+	recipients := emailAddresses(message.Metadata["to"] + "," + message.Metadata["cc"])
+	if len(recipients) == 0 {
+		return nil
+	}
+
+	inboxes, err := s.repo.ListByTenant(ctx, conversation.TenantID)
+	if err != nil {
+		return err
+	}
+	inbox := matchKnowledgeInbox(inboxes, recipients)
+	if inbox == nil {
+		return nil
+	}
+
+	sender := message.Metadata["sender_id"]
+	if addresses := emailAddresses(sender); len(addresses) > 0 {
+		sender = addresses[0]
+	}
+	if !inbox.AcceptsSender(sender) {
+		logger.Info("Ignored email to knowledge inbox from unlisted sender",
+			zap.String("inbox_id", inbox.ID),
+			zap.String("sender", sender),
+		)
+		return nil
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), knowledgeIngestTimeout)
+		defer cancel()
+		if _, err := s.Ingest(ctx, inbox, message); err != nil {
+			logger.Warn("Failed to ingest email into knowledge inbox",
+				zap.String("inbox_id", inbox.ID),
+				zap.String("message_id", message.ID),
+				zap.Error(err),
+			)
+		}
+	}()
+	return nil
+}
+
+// Ingest stores the body and each attachment of an email as submissions awaiting review.
+// Attachments whose text cannot be extracted are kept as failed submissions, so the
+// reviewer can paste the content by hand.
+func (s *KnowledgeInboxService) Ingest(ctx context.Context, inbox *entity.KnowledgeInbox, message *entity.Message) ([]*entity.KnowledgeSubmission, error) {
+	sender := message.Metadata["sender_id"]
+	subject := strings.TrimSpace(message.Metadata["subject"])
+
+	newSubmission := func(title string) *entity.KnowledgeSubmission {
+		now := time.Now()
+		return &entity.KnowledgeSubmission{
+			ID:              uuid.New().String(),
+			TenantID:        inbox.TenantID,
+			InboxID:         inbox.ID,
+			KnowledgeBaseID: inbox.KnowledgeBaseID,
+			MessageID:       message.ID,
+			Sender:          sender,
+			Subject:         subject,
+			Title:           title,
+			Status:          entity.KnowledgeSubmissionPending,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		}
+	}
+
+	var submissions []*entity.KnowledgeSubmission
+	if body := emailBodyText(message.Content); inbox.IncludeBody && body != "" {
+		title := subject
+		if title == "" {
+			title = "Email from " + sender
+		}
+		submission := newSubmission(title)
+		submission.Content = body
+		submissions = append(submissions, submission)
+	}
+
+	for _, attachment := range message.Attachments {
+		submission := newSubmission(strings.TrimSuffix(attachment.Filename, filepath.Ext(attachment.Filename)))
+		if submission.Title == "" {
+			submission.Title = subject
+		}
+		submission.Filename = attachment.Filename
+		submission.MimeType = attachment.MimeType
+
+		text, mimeType, err := s.extract(ctx, attachment)
+		submission.MimeType = mimeType
+		if err != nil {
+			submission.Status = entity.KnowledgeSubmissionFailed
+			submission.Error = err.Error()
+		} else {
+			submission.Content = strings.TrimSpace(text)
+		}
+		submissions = append(submissions, submission)
+	}
+
+	for _, submission := range submissions {
+		if err := s.repo.CreateSubmission(ctx, submission); err != nil {
+			return nil, err
+		}
+	}
+	return submissions, nil
+}
+
+// GetSubmission returns a submission of the tenant
+func (s *KnowledgeInboxService) GetSubmission(ctx context.Context, tenantID, id string) (*entity.KnowledgeSubmission, error) {
+	submission, err := s.repo.FindSubmissionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if submission.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeNotFound, "knowledge submission not found")
+	}
+	return submission, nil
+}
+
+// ListSubmissions lists the submissions of a tenant for review
+func (s *KnowledgeInboxService) ListSubmissions(ctx context.Context, tenantID string, filter *entity.KnowledgeSubmissionFilter, params *repository.ListParams) ([]*entity.KnowledgeSubmission, int64, error) {
+	return s.repo.ListSubmissions(ctx, tenantID, filter, params)
+}
+
+// UpdateSubmission edits the title or content of a submission before it is published
+func (s *KnowledgeInboxService) UpdateSubmission(ctx context.Context, tenantID, id string, input *KnowledgeSubmissionInput) (*entity.KnowledgeSubmission, error) {
+	submission, err := s.pendingSubmission(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	applySubmissionEdits(submission, input)
+	submission.UpdatedAt = time.Now()
+	if err := s.repo.UpdateSubmission(ctx, submission); err != nil {
+		return nil, err
+	}
+	return submission, nil
+}
+
+// Approve publishes a submission to its knowledge base, split into items of the
+// knowledge base's chunk size
+func (s *KnowledgeInboxService) Approve(ctx context.Context, tenantID, id, reviewerID string, input *KnowledgeSubmissionInput) (*entity.KnowledgeSubmission, error) {
+	submission, err := s.pendingSubmission(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if input != nil {
+		applySubmissionEdits(submission, input)
+	}
+	if strings.TrimSpace(submission.Content) == "" {
+		return nil, errors.Validation("submission has no content to publish")
+	}
+	if strings.TrimSpace(submission.Title) == "" {
+		return nil, errors.Validation("title is required")
+	}
+
+	kb, err := s.knowledgeService.GetKnowledgeBase(ctx, submission.KnowledgeBaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	source := submission.Filename
+	if source == "" {
+		source = "email:" + submission.Sender
+	}
+	chunks := chunkKnowledgeText(submission.Content, kb.Config.ChunkSize)
+	itemIDs := make([]string, 0, len(chunks))
+	for i, chunk := range chunks {
+		question := submission.Title
+		if len(chunks) > 1 {
+			question = fmt.Sprintf("%s (part %d of %d)", submission.Title, i+1, len(chunks))
+		}
+		item, err := s.knowledgeService.AddItem(ctx, &AddItemInput{
+			KnowledgeBaseID: submission.KnowledgeBaseID,
+			Question:        question,
+			Answer:          chunk,
+			Source:          source,
+			Metadata: map[string]string{
+				"submission_id": submission.ID,
+				"sender":        submission.Sender,
+			},
+			CreatedBy: reviewerID,
+			Reviewed:  true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		itemIDs = append(itemIDs, item.ID)
+	}
+
+	now := time.Now()
+	submission.Status = entity.KnowledgeSubmissionApproved
+	submission.Error = ""
+	submission.ItemIDs = itemIDs
+	submission.ReviewedBy = reviewerID
+	submission.ReviewedAt = &now
+	submission.UpdatedAt = now
+	if err := s.repo.UpdateSubmission(ctx, submission); err != nil {
+		return nil, err
+	}
+	return submission, nil
+}
+
+// Reject discards a submission without publishing it
+func (s *KnowledgeInboxService) Reject(ctx context.Context, tenantID, id, reviewerID string) (*entity.KnowledgeSubmission, error) {
+	submission, err := s.pendingSubmission(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	submission.Status = entity.KnowledgeSubmissionRejected
+	submission.ReviewedBy = reviewerID
+	submission.ReviewedAt = &now
+	submission.UpdatedAt = now
+	if err := s.repo.UpdateSubmission(ctx, submission); err != nil {
+		return nil, err
+	}
+	return submission, nil
+}
+
+func (s *KnowledgeInboxService) pendingSubmission(ctx context.Context, tenantID, id string) (*entity.KnowledgeSubmission, error) {
+	submission, err := s.GetSubmission(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !submission.IsPending() {
+		return nil, errors.Validation(fmt.Sprintf("submission is already %s", submission.Status))
+	}
+	return submission, nil
+}
+
+func (s *KnowledgeInboxService) validateInbox(ctx context.Context, tenantID, id string, input *KnowledgeInboxInput) (string, error) {
+	if strings.TrimSpace(input.Name) == "" {
+		return "", errors.Validation("name is required")
+	}
+	parsed, err := mail.ParseAddress(input.Address)
+	if err != nil {
+		return "", errors.Validation("address must be a valid email address")
+	}
+	address := strings.ToLower(parsed.Address)
+
+	kb, err := s.knowledgeService.GetKnowledgeBase(ctx, input.KnowledgeBaseID)
+	if err != nil || kb.TenantID != tenantID {
+		return "", errors.New(errors.ErrCodeNotFound, "knowledge base not found")
+	}
+
+	inboxes, err := s.repo.ListByTenant(ctx, tenantID)
+	if err != nil {
+		return "", err
+	}
+	for _, other := range inboxes {
+		if other.ID != id && other.Address == address {
+			return "", errors.New(errors.ErrCodeConflict, "another knowledge inbox uses this address")
+		}
+	}
+	return address, nil
+}
+
+// extract downloads an attachment and returns its text and MIME type
+func (s *KnowledgeInboxService) extract(ctx context.Context, attachment *entity.MessageAttachment) (string, string, error) {
+	data, contentType, err := downloadDocument(ctx, s.httpClient, attachment.URL)
+	mimeType := attachment.MimeType
+	if err != nil {
+		return "", mimeType, err
+	}
+	if mimeType == "" {
+		mimeType = contentType
+		if mimeType == "" {
+			mimeType = http.DetectContentType(data)
+		}
+	}
+	if s.engine == nil || !s.engine.Supports(mimeType) {
+		return "", mimeType, fmt.Errorf("text cannot be extracted from %s documents", mimeType)
+	}
+	text, err := s.engine.ExtractText(ctx, data, mimeType)
+	return text, mimeType, err
+}
+
+func applySubmissionEdits(submission *entity.KnowledgeSubmission, input *KnowledgeSubmissionInput) {
+	if input.Title != nil {
+		submission.Title = strings.TrimSpace(*input.Title)
+	}
+	if input.Content != nil {
+		submission.Content = strings.TrimSpace(*input.Content)
+	}
+}
+
+// matchKnowledgeInbox returns the active inbox addressed by one of the recipients
+func matchKnowledgeInbox(inboxes []*entity.KnowledgeInbox, recipients []string) *entity.KnowledgeInbox {
+	for _, inbox := range inboxes {
+		if !inbox.IsActive {
+			continue
+		}
+		for _, recipient := range recipients {
+			if strings.EqualFold(inbox.Address, recipient) {
+				return inbox
+			}
+		}
+	}
+	return nil
+}
+
+// emailAddresses extracts the bare addresses of a comma separated recipient list
+func emailAddresses(list string) []string {
+	var addresses []string
+	for _, part := range strings.Split(list, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if parsed, err := mail.ParseAddress(part); err == nil {
+			part = parsed.Address
+		}
+		addresses = append(addresses, strings.ToLower(part))
+	}
+	return addresses
+}
+
+// emailBodyText returns the plain text of an email body, stripping HTML markup
+func emailBodyText(body string) string {
+	if strings.Contains(body, "</") || strings.Contains(body, "<br") {
+		body = strings.NewReplacer("<br>", "\n", "<br/>", "\n", "<br />", "\n", "</p>", "\n\n", "</div>", "\n").Replace(body)
+		body = html.UnescapeString(htmlTagPattern.ReplaceAllString(body, ""))
+	}
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	return strings.TrimSpace(blankLinesPattern.ReplaceAllString(body, "\n\n"))
+}
+
+// chunkKnowledgeText splits text into chunks of at most size bytes, breaking at
+// paragraph boundaries where possible
+func chunkKnowledgeText(text string, size int) []string {
+	if size <= 0 {
+		size = DefaultKnowledgeChunkSize
+	}
+	text = strings.TrimSpace(text)
+	if len(text) <= size {
+		return []string{text}
+	}
+
+	var chunks []string
+	var current strings.Builder
+	flush := func() {
+		if chunk := strings.TrimSpace(current.String()); chunk != "" {
+			chunks = append(chunks, chunk)
+		}
+		current.Reset()
+	}
+
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		if current.Len() > 0 && current.Len()+len(paragraph)+2 > size {
+			flush()
+		}
+		for len(paragraph) > size {
+			part := truncateText(paragraph, size)
+			if cut := strings.LastIndexAny(part, " \n"); cut > size/2 {
+				part = part[:cut]
+			}
+			current.WriteString(part)
+			flush()
+			paragraph = strings.TrimSpace(paragraph[len(part):])
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n\n")
+		}
+		current.WriteString(paragraph)
+	}
+	flush()
+	return chunks
+}
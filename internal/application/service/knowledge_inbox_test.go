@@ -0,0 +1,302 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/egress"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockKnowledgeInboxRepo struct {
+	inboxes     map[string]*entity.KnowledgeInbox
+	submissions map[string]*entity.KnowledgeSubmission
+}
+
+func newMockKnowledgeInboxRepo() *mockKnowledgeInboxRepo {
+	return &mockKnowledgeInboxRepo{
+		inboxes:     make(map[string]*entity.KnowledgeInbox),
+		submissions: make(map[string]*entity.KnowledgeSubmission),
+	}
+}
+
+func (m *mockKnowledgeInboxRepo) Create(ctx context.Context, inbox *entity.KnowledgeInbox) error {
+	m.inboxes[inbox.ID] = inbox
+	return nil
+}
+
+func (m *mockKnowledgeInboxRepo) FindByID(ctx context.Context, id string) (*entity.KnowledgeInbox, error) {
+	if inbox, ok := m.inboxes[id]; ok {
+		return inbox, nil
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "knowledge inbox not found")
+}
+
+func (m *mockKnowledgeInboxRepo) ListByTenant(ctx context.Context, tenantID string) ([]*entity.KnowledgeInbox, error) {
+	var inboxes []*entity.KnowledgeInbox
+	for _, inbox := range m.inboxes {
+		if inbox.TenantID == tenantID {
+			inboxes = append(inboxes, inbox)
+		}
+	}
+	return inboxes, nil
+}
+
+func (m *mockKnowledgeInboxRepo) Update(ctx context.Context, inbox *entity.KnowledgeInbox) error {
+	m.inboxes[inbox.ID] = inbox
+	return nil
+}
+
+func (m *mockKnowledgeInboxRepo) Delete(ctx context.Context, id string) error {
+	delete(m.inboxes, id)
+	return nil
+}
+
+func (m *mockKnowledgeInboxRepo) CreateSubmission(ctx context.Context, submission *entity.KnowledgeSubmission) error {
+	m.submissions[submission.ID] = submission
+	return nil
+}
+
+func (m *mockKnowledgeInboxRepo) FindSubmissionByID(ctx context.Context, id string) (*entity.KnowledgeSubmission, error) {
+	if submission, ok := m.submissions[id]; ok {
+		return submission, nil
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "knowledge submission not found")
+}
+
+func (m *mockKnowledgeInboxRepo) ListSubmissions(ctx context.Context, tenantID string, filter *entity.KnowledgeSubmissionFilter, params *repository.ListParams) ([]*entity.KnowledgeSubmission, int64, error) {
+	var submissions []*entity.KnowledgeSubmission
+	for _, submission := range m.submissions {
+		if submission.TenantID == tenantID {
+			submissions = append(submissions, submission)
+		}
+	}
+	return submissions, int64(len(submissions)), nil
+}
+
+func (m *mockKnowledgeInboxRepo) UpdateSubmission(ctx context.Context, submission *entity.KnowledgeSubmission) error {
+	m.submissions[submission.ID] = submission
+	return nil
+}
+
+type stubKnowledgeBaseRepo struct {
+	repository.KnowledgeBaseRepository
+	kbs map[string]*entity.KnowledgeBase
+}
+
+func (r *stubKnowledgeBaseRepo) FindByID(ctx context.Context, id string) (*entity.KnowledgeBase, error) {
+	if kb, ok := r.kbs[id]; ok {
+		return kb, nil
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "knowledge base not found")
+}
+
+func (r *stubKnowledgeBaseRepo) FindByTenant(ctx context.Context, tenantID string, params *repository.ListParams) ([]*entity.KnowledgeBase, int64, error) {
+	var kbs []*entity.KnowledgeBase
+	for _, kb := range r.kbs {
+		if kb.TenantID == tenantID {
+			kbs = append(kbs, kb)
+		}
+	}
+	return kbs, int64(len(kbs)), nil
+}
+
+func (r *stubKnowledgeBaseRepo) Update(ctx context.Context, kb *entity.KnowledgeBase) error {
+	return nil
+}
+
+type stubKnowledgeItemRepo struct {
+	repository.KnowledgeItemRepository
+	items []*entity.KnowledgeItem
+}
+
+func (r *stubKnowledgeItemRepo) Create(ctx context.Context, item *entity.KnowledgeItem) error {
+	r.items = append(r.items, item)
+	return nil
+}
+
+type knowledgeInboxTestEnv struct {
+	svc   *KnowledgeInboxService
+	repo  *mockKnowledgeInboxRepo
+	kb    *entity.KnowledgeBase
+	items *stubKnowledgeItemRepo
+}
+
+func newKnowledgeInboxTestEnv() *knowledgeInboxTestEnv {
+	kb := entity.NewKnowledgeBase("tenant-1", "Product docs", entity.KnowledgeTypeFAQ)
+	kb.ID = "kb-1"
+	kbRepo := &stubKnowledgeBaseRepo{kbs: map[string]*entity.KnowledgeBase{kb.ID: kb}}
+	items := &stubKnowledgeItemRepo{}
+	repo := newMockKnowledgeInboxRepo()
+
+	return &knowledgeInboxTestEnv{
+		svc:   NewKnowledgeInboxService(repo, NewKnowledgeService(kbRepo, items, nil, nil), nil),
+		repo:  repo,
+		kb:    kb,
+		items: items,
+	}
+}
+
+func TestKnowledgeInbox_AcceptsSender(t *testing.T) {
+	inbox := &entity.KnowledgeInbox{}
+	assert.True(t, inbox.AcceptsSender("anyone@example.com"))
+
+	inbox.AllowedSenders = []string{"docs@partner.com", "@acme.com"}
+	assert.True(t, inbox.AcceptsSender("Docs@Partner.com"))
+	assert.True(t, inbox.AcceptsSender("writer@acme.com"))
+	assert.False(t, inbox.AcceptsSender("writer@notacme.org"))
+	assert.False(t, inbox.AcceptsSender("other@partner.com"))
+}
+
+func TestKnowledgeInboxService_CreateInboxValidation(t *testing.T) {
+	env := newKnowledgeInboxTestEnv()
+	ctx := context.Background()
+
+	_, err := env.svc.CreateInbox(ctx, "tenant-1", &KnowledgeInboxInput{Name: "Docs", Address: "not-an-address", KnowledgeBaseID: "kb-1"})
+	assert.Error(t, err)
+
+	_, err = env.svc.CreateInbox(ctx, "tenant-2", &KnowledgeInboxInput{Name: "Docs", Address: "kb@tenant.com", KnowledgeBaseID: "kb-1"})
+	assert.Error(t, err, "knowledge base of another tenant")
+
+	inbox, err := env.svc.CreateInbox(ctx, "tenant-1", &KnowledgeInboxInput{Name: "Docs", Address: "Docs <KB@Tenant.com>", KnowledgeBaseID: "kb-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "kb@tenant.com", inbox.Address)
+	assert.True(t, inbox.IsActive)
+
+	_, err = env.svc.CreateInbox(ctx, "tenant-1", &KnowledgeInboxInput{Name: "Other", Address: "kb@tenant.com", KnowledgeBaseID: "kb-1"})
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code)
+}
+
+func TestKnowledgeInboxService_Ingest(t *testing.T) {
+	env := newKnowledgeInboxTestEnv()
+	inbox := &entity.KnowledgeInbox{ID: "inbox-1", TenantID: "tenant-1", KnowledgeBaseID: "kb-1", IncludeBody: true, IsActive: true}
+
+	message := &entity.Message{
+		ID:      "msg-1",
+		Content: "<p>Refunds take <b>5 days</b>.</p><p>Contact billing.</p>",
+		Metadata: map[string]string{
+			"sender_id": "writer@acme.com",
+			"subject":   "Refund policy",
+		},
+		Attachments: []*entity.MessageAttachment{
+			{Filename: "handbook.pdf", MimeType: "application/pdf"},
+		},
+	}
+
+	submissions, err := env.svc.Ingest(context.Background(), inbox, message)
+	require.NoError(t, err)
+	require.Len(t, submissions, 2)
+
+	body := submissions[0]
+	assert.Equal(t, "Refund policy", body.Title)
+	assert.Equal(t, "Refunds take 5 days.\n\nContact billing.", body.Content)
+	assert.Equal(t, entity.KnowledgeSubmissionPending, body.Status)
+	assert.Equal(t, "kb-1", body.KnowledgeBaseID)
+
+	attachment := submissions[1]
+	assert.Equal(t, "handbook", attachment.Title)
+	assert.Equal(t, "handbook.pdf", attachment.Filename)
+	assert.Equal(t, entity.KnowledgeSubmissionFailed, attachment.Status)
+	assert.NotEmpty(t, attachment.Error)
+	assert.Len(t, env.repo.submissions, 2)
+}
+
+func TestKnowledgeInboxService_IngestRefusesInternalAttachments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the request must not reach the server")
+	}))
+	defer server.Close()
+
+	env := newKnowledgeInboxTestEnv()
+	inbox := &entity.KnowledgeInbox{ID: "inbox-1", TenantID: "tenant-1", KnowledgeBaseID: "kb-1", IsActive: true}
+	message := &entity.Message{
+		ID:          "msg-1",
+		Metadata:    map[string]string{"sender_id": "writer@acme.com"},
+		Attachments: []*entity.MessageAttachment{{Filename: "handbook.pdf", MimeType: "application/pdf", URL: server.URL + "/handbook.pdf"}},
+	}
+
+	submissions, err := env.svc.Ingest(context.Background(), inbox, message)
+	require.NoError(t, err)
+	require.Len(t, submissions, 1)
+	assert.Equal(t, entity.KnowledgeSubmissionFailed, submissions[0].Status)
+	assert.Contains(t, submissions[0].Error, egress.ErrForbiddenAddress.Error())
+}
+
+func TestKnowledgeInboxService_Approve(t *testing.T) {
+	env := newKnowledgeInboxTestEnv()
+	env.kb.Config.ChunkSize = 40
+	ctx := context.Background()
+
+	env.repo.submissions["sub-1"] = &entity.KnowledgeSubmission{
+		ID:              "sub-1",
+		TenantID:        "tenant-1",
+		KnowledgeBaseID: "kb-1",
+		Sender:          "writer@acme.com",
+		Title:           "Refund policy",
+		Content:         "Refunds are processed within five days.\n\nContact billing for exceptions.",
+		Status:          entity.KnowledgeSubmissionPending,
+	}
+
+	_, err := env.svc.Approve(ctx, "tenant-2", "sub-1", "user-1", nil)
+	assert.True(t, errors.IsNotFound(err))
+
+	title := "Refunds"
+	submission, err := env.svc.Approve(ctx, "tenant-1", "sub-1", "user-1", &KnowledgeSubmissionInput{Title: &title})
+	require.NoError(t, err)
+	assert.Equal(t, entity.KnowledgeSubmissionApproved, submission.Status)
+	assert.Equal(t, "user-1", submission.ReviewedBy)
+	require.Len(t, env.items.items, 2)
+	assert.Len(t, submission.ItemIDs, 2)
+	assert.Equal(t, "Refunds (part 1 of 2)", env.items.items[0].Question)
+	assert.Equal(t, "email:writer@acme.com", env.items.items[0].Source)
+	assert.Equal(t, "sub-1", env.items.items[0].Metadata["submission_id"])
+
+	_, err = env.svc.Reject(ctx, "tenant-1", "sub-1", "user-1")
+	assert.Error(t, err, "approved submissions cannot be rejected")
+}
+
+func TestKnowledgeInboxService_ApproveRequiresContent(t *testing.T) {
+	env := newKnowledgeInboxTestEnv()
+	env.repo.submissions["sub-1"] = &entity.KnowledgeSubmission{
+		ID:              "sub-1",
+		TenantID:        "tenant-1",
+		KnowledgeBaseID: "kb-1",
+		Title:           "handbook",
+		Status:          entity.KnowledgeSubmissionFailed,
+	}
+
+	_, err := env.svc.Approve(context.Background(), "tenant-1", "sub-1", "user-1", nil)
+	assert.Error(t, err)
+
+	content := "Pasted by the reviewer"
+	submission, err := env.svc.Approve(context.Background(), "tenant-1", "sub-1", "user-1", &KnowledgeSubmissionInput{Content: &content})
+	require.NoError(t, err)
+	assert.Equal(t, entity.KnowledgeSubmissionApproved, submission.Status)
+	assert.Empty(t, submission.Error)
+}
+
+func TestChunkKnowledgeText(t *testing.T) {
+	assert.Equal(t, []string{"short"}, chunkKnowledgeText("short", 100))
+
+	text := strings.Repeat("a", 30) + "\n\n" + strings.Repeat("b", 30) + "\n\n" + strings.Repeat("c", 30)
+	chunks := chunkKnowledgeText(text, 70)
+	require.Len(t, chunks, 2)
+	assert.Equal(t, strings.Repeat("a", 30)+"\n\n"+strings.Repeat("b", 30), chunks[0])
+
+	for _, chunk := range chunkKnowledgeText(strings.Repeat("x", 250), 100) {
+		assert.LessOrEqual(t, len(chunk), 100)
+	}
+}
+
+func TestEmailAddresses(t *testing.T) {
+	assert.Equal(t, []string{"kb@tenant.com", "other@x.com"}, emailAddresses("Docs <KB@tenant.com>, other@x.com"))
+	assert.Nil(t, emailAddresses(""))
+}
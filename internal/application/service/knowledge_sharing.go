@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+const sharedKnowledgeReadOnly = "global knowledge bases are maintained by the platform; add override items to change answers for your tenant"
+
+// SetSharingRepository enables global knowledge bases, maintained by the platform and
+// attached read-only to tenants, who may layer override items over them
+func (s *KnowledgeService) SetSharingRepository(sharingRepo repository.KnowledgeSharingRepository) {
+	s.sharingRepo = sharingRepo
+}
+
+// KnowledgeBaseForTenant returns a knowledge base the tenant may read: one of its own,
+// or a global knowledge base attached to it. Any other is reported as not found.
+func (s *KnowledgeService) KnowledgeBaseForTenant(ctx context.Context, tenantID, id string) (*entity.KnowledgeBase, error) {
+	kb, err := s.kbRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tenantID != "" {
+		if !kb.Global && kb.TenantID == tenantID {
+			return kb, nil
+		}
+		if kb.Global && s.sharingRepo != nil {
+			attached, err := s.sharingRepo.IsAttached(ctx, kb.ID, tenantID)
+			if err != nil {
+				return nil, err
+			}
+			if attached {
+				return kb, nil
+			}
+		}
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "knowledge base not found")
+}
+
+// EditableKnowledgeBase returns a knowledge base the tenant may change, which global
+// knowledge bases are not
+func (s *KnowledgeService) EditableKnowledgeBase(ctx context.Context, tenantID, id string) (*entity.KnowledgeBase, error) {
+	kb, err := s.KnowledgeBaseForTenant(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if kb.Global {
+		return nil, errors.Forbidden(sharedKnowledgeReadOnly)
+	}
+	return kb, nil
+}
+
+// ItemForTenant returns an item of a knowledge base the tenant may read. With edit
+// set the shared items of global knowledge bases are refused, as tenants may only
+// change their own overrides.
+func (s *KnowledgeService) ItemForTenant(ctx context.Context, tenantID, knowledgeBaseID, itemID string, edit bool) (*entity.KnowledgeItem, error) {
+	kb, err := s.KnowledgeBaseForTenant(ctx, tenantID, knowledgeBaseID)
+	if err != nil {
+		return nil, err
+	}
+	item, err := s.itemRepo.FindByID(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item.KnowledgeBaseID != kb.ID || (item.IsOverride() && item.TenantID != tenantID) {
+		return nil, errors.New(errors.ErrCodeNotFound, "knowledge item not found")
+	}
+	if edit && kb.Global && !item.IsOverride() {
+		return nil, errors.Forbidden(sharedKnowledgeReadOnly)
+	}
+	return item, nil
+}
+
+// ListItemsForTenant lists the items of a knowledge base the tenant may read; for a
+// global knowledge base, the shared items and the tenant's overrides
+func (s *KnowledgeService) ListItemsForTenant(ctx context.Context, tenantID, knowledgeBaseID string, params *repository.ListParams) ([]*entity.KnowledgeItem, int64, error) {
+	kb, err := s.KnowledgeBaseForTenant(ctx, tenantID, knowledgeBaseID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if kb.Global {
+		return s.sharingRepo.FindItems(ctx, kb.ID, tenantID, params)
+	}
+	return s.itemRepo.FindByKnowledgeBase(ctx, kb.ID, params)
+}
+
+// ListAttachedKnowledgeBases lists the global knowledge bases attached to a tenant
+func (s *KnowledgeService) ListAttachedKnowledgeBases(ctx context.Context, tenantID string) ([]*entity.KnowledgeBase, error) {
+	if s.sharingRepo == nil {
+		return []*entity.KnowledgeBase{}, nil
+	}
+	return s.sharingRepo.FindAttached(ctx, tenantID)
+}
+
+// ListGlobalKnowledgeBases lists the global knowledge bases
+func (s *KnowledgeService) ListGlobalKnowledgeBases(ctx context.Context, params *repository.ListParams) ([]*entity.KnowledgeBase, int64, error) {
+	if err := s.requireSharing(); err != nil {
+		return nil, 0, err
+	}
+	return s.sharingRepo.FindGlobal(ctx, params)
+}
+
+// GlobalKnowledgeBase returns a global knowledge base
+func (s *KnowledgeService) GlobalKnowledgeBase(ctx context.Context, id string) (*entity.KnowledgeBase, error) {
+	if err := s.requireSharing(); err != nil {
+		return nil, err
+	}
+	kb, err := s.kbRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !kb.Global {
+		return nil, errors.New(errors.ErrCodeNotFound, "knowledge base not found")
+	}
+	return kb, nil
+}
+
+// SharedItem returns a shared item of a global knowledge base; tenants' overrides
+// are not the platform's to change
+func (s *KnowledgeService) SharedItem(ctx context.Context, knowledgeBaseID, itemID string) (*entity.KnowledgeItem, error) {
+	kb, err := s.GlobalKnowledgeBase(ctx, knowledgeBaseID)
+	if err != nil {
+		return nil, err
+	}
+	item, err := s.itemRepo.FindByID(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+	if item.KnowledgeBaseID != kb.ID || item.IsOverride() {
+		return nil, errors.New(errors.ErrCodeNotFound, "knowledge item not found")
+	}
+	return item, nil
+}
+
+// AttachKnowledgeBase attaches a global knowledge base to a tenant, whose bots may
+// then answer from it
+func (s *KnowledgeService) AttachKnowledgeBase(ctx context.Context, knowledgeBaseID, tenantID, attachedBy string) (*entity.KnowledgeBaseAttachment, error) {
+	kb, err := s.GlobalKnowledgeBase(ctx, knowledgeBaseID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := uuid.Parse(tenantID); err != nil {
+		return nil, errors.Validation("tenant_id must be a tenant ID")
+	}
+
+	attachment := &entity.KnowledgeBaseAttachment{
+		KnowledgeBaseID: kb.ID,
+		TenantID:        tenantID,
+		AttachedBy:      attachedBy,
+		AttachedAt:      s.now(),
+	}
+	if err := s.sharingRepo.Attach(ctx, attachment); err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// DetachKnowledgeBase detaches a global knowledge base from a tenant. The tenant's
+// overrides are kept for when it is attached again.
+func (s *KnowledgeService) DetachKnowledgeBase(ctx context.Context, knowledgeBaseID, tenantID string) error {
+	kb, err := s.GlobalKnowledgeBase(ctx, knowledgeBaseID)
+	if err != nil {
+		return err
+	}
+	return s.sharingRepo.Detach(ctx, kb.ID, tenantID)
+}
+
+// ListAttachments lists the tenants a global knowledge base is attached to
+func (s *KnowledgeService) ListAttachments(ctx context.Context, knowledgeBaseID string) ([]*entity.KnowledgeBaseAttachment, error) {
+	kb, err := s.GlobalKnowledgeBase(ctx, knowledgeBaseID)
+	if err != nil {
+		return nil, err
+	}
+	return s.sharingRepo.ListAttachments(ctx, kb.ID)
+}
+
+func (s *KnowledgeService) requireSharing() error {
+	if s.sharingRepo == nil {
+		return errors.New(errors.ErrCodeBadRequest, "knowledge sharing is not enabled")
+	}
+	return nil
+}
+
+// validateOverride checks the tenant and overridden item of an item being added:
+// tenants only add overrides to global knowledge bases, overriding a shared item of
+// the same knowledge base at most once
+func (s *KnowledgeService) validateOverride(ctx context.Context, kb *entity.KnowledgeBase, input *AddItemInput) error {
+	if input.TenantID == "" {
+		if input.OverridesItemID != "" {
+			return errors.Validation("only tenants override the items of global knowledge bases")
+		}
+		return nil
+	}
+	if !kb.Global {
+		return errors.Validation("override items can only be added to global knowledge bases")
+	}
+	if err := s.requireSharing(); err != nil {
+		return err
+	}
+	if input.OverridesItemID == "" {
+		return nil
+	}
+
+	overridden, err := s.itemRepo.FindByID(ctx, input.OverridesItemID)
+	if err != nil || overridden.KnowledgeBaseID != kb.ID || overridden.IsOverride() {
+		return errors.Validation("overrides_item_id must be a shared item of the knowledge base")
+	}
+	existing, err := s.sharingRepo.FindOverrides(ctx, kb.ID, input.TenantID)
+	if err != nil {
+		return err
+	}
+	for _, item := range existing {
+		if item.OverridesItemID == input.OverridesItemID {
+			return errors.Conflict("the item is already overridden; edit the existing override instead")
+		}
+	}
+	return nil
+}
+
+// applyOverrides keeps the search results the tenant may see, dropping other tenants'
+// overrides and the shared items the tenant overrides, and ranks them by score with
+// the tenant's own items first among equals
+func applyOverrides(results []entity.SearchResult, overrides []*entity.KnowledgeItem, tenantID string, limit int) []entity.SearchResult {
+	overridden := make(map[string]bool)
+	for _, item := range overrides {
+		if item.OverridesItemID != "" && item.IsPublished() {
+			overridden[item.OverridesItemID] = true
+		}
+	}
+
+	seen := make(map[string]bool)
+	kept := make([]entity.SearchResult, 0, len(results))
+	for _, result := range results {
+		item := result.Item
+		if item == nil || seen[item.ID] {
+			continue
+		}
+		if item.IsOverride() && item.TenantID != tenantID {
+			continue
+		}
+		if !item.IsOverride() && overridden[item.ID] {
+			continue
+		}
+		seen[item.ID] = true
+		kept = append(kept, result)
+	}
+
+	sort.SliceStable(kept, func(i, j int) bool {
+		if kept[i].Score != kept[j].Score {
+			return kept[i].Score > kept[j].Score
+		}
+		return kept[i].Item.IsOverride() && !kept[j].Item.IsOverride()
+	})
+	if limit > 0 && len(kept) > limit {
+		kept = kept[:limit]
+	}
+	return kept
+}
+
+// matchesKeywords reports whether an item matches any keyword the way keyword
+// search does: in its question or answer, or as one of its keywords
+func matchesKeywords(item *entity.KnowledgeItem, keywords []string) bool {
+	question := strings.ToLower(item.Question)
+	answer := strings.ToLower(item.Answer)
+	for _, keyword := range keywords {
+		lower := strings.ToLower(keyword)
+		if strings.Contains(question, lower) || strings.Contains(answer, lower) {
+			return true
+		}
+		for _, itemKeyword := range item.Keywords {
+			if itemKeyword == keyword {
+				return true
+			}
+		}
+	}
+	return false
+}
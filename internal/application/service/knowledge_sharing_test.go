@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKnowledgeSharingRepo keeps attachments in memory and reads items from the item repository
+type fakeKnowledgeSharingRepo struct {
+	bases       *mockKnowledgeBaseRepo
+	items       *mockKnowledgeItemRepo
+	attachments map[string]*entity.KnowledgeBaseAttachment
+}
+
+func newFakeKnowledgeSharingRepo(bases *mockKnowledgeBaseRepo, items *mockKnowledgeItemRepo) *fakeKnowledgeSharingRepo {
+	return &fakeKnowledgeSharingRepo{
+		bases:       bases,
+		items:       items,
+		attachments: make(map[string]*entity.KnowledgeBaseAttachment),
+	}
+}
+
+func (r *fakeKnowledgeSharingRepo) FindGlobal(ctx context.Context, params *repository.ListParams) ([]*entity.KnowledgeBase, int64, error) {
+	var result []*entity.KnowledgeBase
+	for _, kb := range r.bases.bases {
+		if kb.Global {
+			result = append(result, kb)
+		}
+	}
+	return result, int64(len(result)), nil
+}
+
+func (r *fakeKnowledgeSharingRepo) FindAttached(ctx context.Context, tenantID string) ([]*entity.KnowledgeBase, error) {
+	var result []*entity.KnowledgeBase
+	for _, attachment := range r.attachments {
+		if attachment.TenantID == tenantID {
+			result = append(result, r.bases.bases[attachment.KnowledgeBaseID])
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeKnowledgeSharingRepo) Attach(ctx context.Context, attachment *entity.KnowledgeBaseAttachment) error {
+	r.attachments[attachment.KnowledgeBaseID+"/"+attachment.TenantID] = attachment
+	return nil
+}
+
+func (r *fakeKnowledgeSharingRepo) Detach(ctx context.Context, knowledgeBaseID, tenantID string) error {
+	key := knowledgeBaseID + "/" + tenantID
+	if _, ok := r.attachments[key]; !ok {
+		return errors.New(errors.ErrCodeNotFound, "knowledge base is not attached to the tenant")
+	}
+	delete(r.attachments, key)
+	return nil
+}
+
+func (r *fakeKnowledgeSharingRepo) IsAttached(ctx context.Context, knowledgeBaseID, tenantID string) (bool, error) {
+	_, ok := r.attachments[knowledgeBaseID+"/"+tenantID]
+	return ok, nil
+}
+
+func (r *fakeKnowledgeSharingRepo) ListAttachments(ctx context.Context, knowledgeBaseID string) ([]*entity.KnowledgeBaseAttachment, error) {
+	var result []*entity.KnowledgeBaseAttachment
+	for _, attachment := range r.attachments {
+		if attachment.KnowledgeBaseID == knowledgeBaseID {
+			result = append(result, attachment)
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeKnowledgeSharingRepo) FindItems(ctx context.Context, knowledgeBaseID, tenantID string, params *repository.ListParams) ([]*entity.KnowledgeItem, int64, error) {
+	var result []*entity.KnowledgeItem
+	for _, item := range r.items.items {
+		if item.KnowledgeBaseID == knowledgeBaseID && (item.TenantID == "" || item.TenantID == tenantID) {
+			result = append(result, item)
+		}
+	}
+	return result, int64(len(result)), nil
+}
+
+func (r *fakeKnowledgeSharingRepo) FindOverrides(ctx context.Context, knowledgeBaseID, tenantID string) ([]*entity.KnowledgeItem, error) {
+	var result []*entity.KnowledgeItem
+	for _, item := range r.items.items {
+		if item.KnowledgeBaseID == knowledgeBaseID && item.TenantID == tenantID {
+			result = append(result, item)
+		}
+	}
+	return result, nil
+}
+
+func newSharedKnowledgeService(t *testing.T) (*KnowledgeService, *entity.KnowledgeBase, *entity.KnowledgeItem, string) {
+	kbRepo := newMockKnowledgeBaseRepo()
+	itemRepo := newMockKnowledgeItemRepo()
+	svc := NewKnowledgeService(kbRepo, itemRepo, nil, nil)
+	svc.SetSharingRepository(newFakeKnowledgeSharingRepo(kbRepo, itemRepo))
+	ctx := context.Background()
+
+	kb, err := svc.CreateKnowledgeBase(ctx, &CreateKnowledgeBaseInput{
+		Name:   "Carrier FAQ",
+		Type:   entity.KnowledgeTypeFAQ,
+		Global: true,
+	})
+	require.NoError(t, err)
+	shared, err := svc.AddItem(ctx, &AddItemInput{
+		KnowledgeBaseID: kb.ID,
+		Question:        "shipping time",
+		Answer:          "Five business days",
+	})
+	require.NoError(t, err)
+
+	tenantID := uuid.New().String()
+	_, err = svc.AttachKnowledgeBase(ctx, kb.ID, tenantID, "ops")
+	require.NoError(t, err)
+	return svc, kb, shared, tenantID
+}
+
+func TestKnowledgeService_SharedKnowledgeAccess(t *testing.T) {
+	svc, kb, shared, tenantID := newSharedKnowledgeService(t)
+	ctx := context.Background()
+
+	got, err := svc.KnowledgeBaseForTenant(ctx, tenantID, kb.ID)
+	require.NoError(t, err)
+	assert.True(t, got.Global)
+
+	_, err = svc.KnowledgeBaseForTenant(ctx, "tenant-unattached", kb.ID)
+	assert.True(t, errors.IsNotFound(err))
+
+	_, err = svc.EditableKnowledgeBase(ctx, tenantID, kb.ID)
+	assert.Equal(t, errors.ErrCodeForbidden, errors.GetAppError(err).Code)
+
+	_, err = svc.ItemForTenant(ctx, tenantID, kb.ID, shared.ID, false)
+	require.NoError(t, err)
+	_, err = svc.ItemForTenant(ctx, tenantID, kb.ID, shared.ID, true)
+	assert.Equal(t, errors.ErrCodeForbidden, errors.GetAppError(err).Code)
+
+	override, err := svc.AddItem(ctx, &AddItemInput{
+		KnowledgeBaseID: kb.ID,
+		TenantID:        tenantID,
+		OverridesItemID: shared.ID,
+		Question:        "shipping time",
+		Answer:          "Two business days with our express partner",
+	})
+	require.NoError(t, err)
+	_, err = svc.ItemForTenant(ctx, tenantID, kb.ID, override.ID, true)
+	require.NoError(t, err)
+
+	other := uuid.New().String()
+	_, err = svc.AttachKnowledgeBase(ctx, kb.ID, other, "ops")
+	require.NoError(t, err)
+	_, err = svc.ItemForTenant(ctx, other, kb.ID, override.ID, false)
+	assert.True(t, errors.IsNotFound(err), "tenants never see each other's overrides")
+
+	items, _, err := svc.ListItemsForTenant(ctx, other, kb.ID, &repository.ListParams{})
+	require.NoError(t, err)
+	assert.Len(t, items, 1)
+
+	// Overrides do not count as items of the shared knowledge base
+	got, err = svc.GlobalKnowledgeBase(ctx, kb.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, got.ItemCount)
+
+	require.NoError(t, svc.DetachKnowledgeBase(ctx, kb.ID, other))
+	_, err = svc.KnowledgeBaseForTenant(ctx, other, kb.ID)
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestKnowledgeService_SearchAppliesOverrides(t *testing.T) {
+	svc, kb, shared, tenantID := newSharedKnowledgeService(t)
+	ctx := context.Background()
+
+	other := uuid.New().String()
+	_, err := svc.AttachKnowledgeBase(ctx, kb.ID, other, "ops")
+	require.NoError(t, err)
+
+	override, err := svc.AddItem(ctx, &AddItemInput{
+		KnowledgeBaseID: kb.ID,
+		TenantID:        tenantID,
+		OverridesItemID: shared.ID,
+		Question:        "shipping time",
+		Answer:          "Two business days with our express partner",
+	})
+	require.NoError(t, err)
+
+	results, err := svc.Search(ctx, tenantID, kb.ID, "shipping", 5)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, override.ID, results[0].Item.ID)
+
+	results, err = svc.Search(ctx, other, kb.ID, "shipping", 5)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, shared.ID, results[0].Item.ID)
+
+	_, err = svc.Search(ctx, "tenant-unattached", kb.ID, "shipping", 5)
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestKnowledgeService_ValidateOverride(t *testing.T) {
+	svc, kb, shared, tenantID := newSharedKnowledgeService(t)
+	ctx := context.Background()
+
+	input := &AddItemInput{
+		KnowledgeBaseID: kb.ID,
+		TenantID:        tenantID,
+		OverridesItemID: shared.ID,
+		Question:        "shipping time",
+		Answer:          "Two business days",
+	}
+	_, err := svc.AddItem(ctx, input)
+	require.NoError(t, err)
+
+	_, err = svc.AddItem(ctx, input)
+	assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code)
+
+	_, err = svc.AddItem(ctx, &AddItemInput{
+		KnowledgeBaseID: kb.ID,
+		TenantID:        tenantID,
+		OverridesItemID: "missing",
+		Question:        "returns",
+		Answer:          "Thirty days",
+	})
+	assert.Equal(t, errors.ErrCodeValidation, errors.GetAppError(err).Code)
+
+	own, err := svc.CreateKnowledgeBase(ctx, &CreateKnowledgeBaseInput{
+		TenantID: tenantID,
+		Name:     "Own FAQ",
+		Type:     entity.KnowledgeTypeFAQ,
+	})
+	require.NoError(t, err)
+	_, err = svc.AddItem(ctx, &AddItemInput{
+		KnowledgeBaseID: own.ID,
+		TenantID:        tenantID,
+		Question:        "returns",
+		Answer:          "Thirty days",
+	})
+	assert.Equal(t, errors.ErrCodeValidation, errors.GetAppError(err).Code)
+
+	_, err = svc.AttachKnowledgeBase(ctx, own.ID, tenantID, "ops")
+	assert.True(t, errors.IsNotFound(err), "only global knowledge bases are attached")
+}
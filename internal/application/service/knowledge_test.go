@@ -454,7 +454,7 @@ func TestKnowledgeService_DeleteItem(t *testing.T) {
 	created, err := svc.AddItem(ctx, itemInput)
 	require.NoError(t, err)
 
-	err = svc.DeleteItem(ctx, created.ID)
+	err = svc.DeleteItem(ctx, created.ID, "user-1")
 	require.NoError(t, err)
 
 	_, ok := itemRepo.items[created.ID]
@@ -489,7 +489,7 @@ func TestKnowledgeService_Search_KeywordFallback(t *testing.T) {
 		require.NoError(t, err)
 	}
 
-	results, err := svc.Search(ctx, kb.ID, "return policy", 10)
+	results, err := svc.Search(ctx, "tenant-1", kb.ID, "return policy", 10)
 	require.NoError(t, err)
 	assert.NotEmpty(t, results)
 	// All results should have score 1.0 for keyword search
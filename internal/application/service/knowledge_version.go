@@ -0,0 +1,390 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// updateVersionedItem saves an update as a new version on top of the latest one
+func (s *KnowledgeService) updateVersionedItem(ctx context.Context, item *entity.KnowledgeItem, input *UpdateItemInput) (*entity.KnowledgeItem, error) {
+	kb, err := s.kbRepo.FindByID(ctx, item.KnowledgeBaseID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Edits apply on top of the latest version, which may be a pending draft
+	next := *item
+	if item.HasDraft() {
+		draft, err := s.versionRepo.FindVersion(ctx, item.ID, item.Version)
+		if err != nil {
+			return nil, err
+		}
+		draft.ApplyTo(&next)
+	}
+	if input.Question != nil {
+		next.Question = *input.Question
+	}
+	if input.Answer != nil {
+		next.Answer = *input.Answer
+	}
+	if input.Keywords != nil {
+		next.Keywords = input.Keywords
+	}
+	if input.Source != nil {
+		next.Source = *input.Source
+	}
+	if input.Metadata != nil {
+		next.Metadata = input.Metadata
+	}
+
+	version := entity.NewKnowledgeItemVersion(&next, item.Version+1, entity.KnowledgeActionEdited, input.UpdatedBy)
+	version.CreatedAt = s.now()
+	if err := s.versionRepo.CreateVersion(ctx, version); err != nil {
+		return nil, err
+	}
+	item.Version = version.Version
+	s.audit(ctx, item, entity.KnowledgeActionEdited, version.Version, input.UpdatedBy)
+
+	if !kb.Config.ReviewRequired {
+		return s.publishVersion(ctx, item, version, input.UpdatedBy)
+	}
+
+	// Items never published carry their latest draft; published ones keep the
+	// content bots retrieve until the draft is published
+	if !item.IsPublished() {
+		if item.Question != version.Question || item.Answer != version.Answer {
+			item.Embedding = nil
+		}
+		version.ApplyTo(item)
+	}
+	item.UpdatedAt = s.now()
+	if err := s.itemRepo.Update(ctx, item); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to update knowledge item")
+	}
+	if item.IsPublished() {
+		item.Draft = version
+	}
+	return item, nil
+}
+
+// publishVersion makes a version the content bots retrieve for an item
+func (s *KnowledgeService) publishVersion(ctx context.Context, item *entity.KnowledgeItem, version *entity.KnowledgeItemVersion, userID string) (*entity.KnowledgeItem, error) {
+	contentChanged := item.Question != version.Question || item.Answer != version.Answer
+	version.ApplyTo(item)
+
+	now := s.now()
+	item.Status = entity.KnowledgeItemStatusPublished
+	item.PublishedVersion = version.Version
+	item.PublishedBy = userID
+	item.PublishedAt = &now
+	item.UpdatedAt = now
+	item.Draft = nil
+
+	if contentChanged || !item.HasEmbedding() {
+		item.Embedding = nil
+		if s.embeddingService != nil && s.embeddingService.IsAvailable() {
+			embedding, err := s.embeddingService.GenerateEmbedding(ctx, item.Question+" "+item.Answer)
+			if err == nil {
+				item.Embedding = embedding
+			}
+		}
+	}
+
+	if err := s.itemRepo.Update(ctx, item); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to publish knowledge item")
+	}
+
+	if s.vectorStore != nil && item.HasEmbedding() {
+		s.vectorStore.Delete(ctx, item.ID)
+		s.vectorStore.Store(ctx, item.ID, item.Embedding, vectorMetadata(item))
+	}
+
+	version.PublishedBy = userID
+	version.PublishedAt = &now
+	if err := s.versionRepo.MarkVersionPublished(ctx, version); err != nil {
+		return nil, err
+	}
+	s.audit(ctx, item, entity.KnowledgeActionPublished, version.Version, userID)
+
+	return item, nil
+}
+
+// PublishItem publishes the latest version of an item
+func (s *KnowledgeService) PublishItem(ctx context.Context, id, userID string) (*entity.KnowledgeItem, error) {
+	if err := s.requireVersioning(); err != nil {
+		return nil, err
+	}
+	item, err := s.itemRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if item.IsPublished() && !item.HasDraft() {
+		return nil, errors.Conflict("item has no changes to publish")
+	}
+
+	version, err := s.versionRepo.FindVersion(ctx, item.ID, item.Version)
+	if err != nil {
+		return nil, err
+	}
+	return s.publishVersion(ctx, item, version, userID)
+}
+
+// PublishKnowledgeBase publishes the latest version of every item of a knowledge
+// base that has unpublished changes
+func (s *KnowledgeService) PublishKnowledgeBase(ctx context.Context, kbID, userID string) (*entity.KnowledgePublishResult, error) {
+	if err := s.requireVersioning(); err != nil {
+		return nil, err
+	}
+	if _, err := s.kbRepo.FindByID(ctx, kbID); err != nil {
+		return nil, err
+	}
+
+	items, _, err := s.itemRepo.FindByKnowledgeBase(ctx, kbID, &repository.ListParams{Page: 1, PageSize: 10000})
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get knowledge items")
+	}
+
+	result := &entity.KnowledgePublishResult{ItemIDs: make([]string, 0)}
+	for _, item := range items {
+		if item.IsPublished() && !item.HasDraft() {
+			continue
+		}
+		version, err := s.versionRepo.FindVersion(ctx, item.ID, item.Version)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.publishVersion(ctx, item, version, userID); err != nil {
+			return nil, err
+		}
+		result.ItemIDs = append(result.ItemIDs, item.ID)
+	}
+	result.Published = len(result.ItemIDs)
+	return result, nil
+}
+
+// RollbackItem restores the content of an earlier version as a new version and
+// publishes it right away. A pending draft is superseded but stays in the history.
+func (s *KnowledgeService) RollbackItem(ctx context.Context, id string, toVersion int, userID string) (*entity.KnowledgeItem, error) {
+	if err := s.requireVersioning(); err != nil {
+		return nil, err
+	}
+	item, err := s.itemRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if item.IsPublished() && !item.HasDraft() && toVersion == item.PublishedVersion {
+		return nil, errors.Conflict("version is already published")
+	}
+
+	target, err := s.versionRepo.FindVersion(ctx, item.ID, toVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	restored := *target
+	restored.ID = ""
+	restored.Version = item.Version + 1
+	restored.Action = entity.KnowledgeActionRolledBack
+	restored.RestoredFrom = target.Version
+	restored.CreatedBy = userID
+	restored.CreatedAt = s.now()
+	restored.PublishedBy = ""
+	restored.PublishedAt = nil
+	if err := s.versionRepo.CreateVersion(ctx, &restored); err != nil {
+		return nil, err
+	}
+	item.Version = restored.Version
+	s.audit(ctx, item, entity.KnowledgeActionRolledBack, restored.Version, userID)
+
+	return s.publishVersion(ctx, item, &restored, userID)
+}
+
+// GetItemWithDraft gets an item with its pending draft, if any
+func (s *KnowledgeService) GetItemWithDraft(ctx context.Context, id string) (*entity.KnowledgeItem, error) {
+	item, err := s.itemRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if s.versionRepo != nil && item.IsPublished() && item.HasDraft() {
+		draft, err := s.versionRepo.FindVersion(ctx, item.ID, item.Version)
+		if err != nil {
+			return nil, err
+		}
+		item.Draft = draft
+	}
+	return item, nil
+}
+
+// ListVersions lists the versions of an item, newest first
+func (s *KnowledgeService) ListVersions(ctx context.Context, id string) ([]*entity.KnowledgeItemVersion, error) {
+	if err := s.requireVersioning(); err != nil {
+		return nil, err
+	}
+	if _, err := s.itemRepo.FindByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.versionRepo.ListVersions(ctx, id)
+}
+
+// DiffVersions compares two versions of an item. Without versions it compares
+// the published version with the latest one, or the latest with the one before
+// when nothing is pending. Version 0 stands for an empty item.
+func (s *KnowledgeService) DiffVersions(ctx context.Context, id string, from, to int) (*entity.KnowledgeItemDiff, error) {
+	if err := s.requireVersioning(); err != nil {
+		return nil, err
+	}
+	item, err := s.itemRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if to <= 0 {
+		to = item.Version
+	}
+	if from <= 0 {
+		from = item.PublishedVersion
+		if from >= to {
+			from = to - 1
+		}
+	}
+
+	before := &entity.KnowledgeItemVersion{}
+	if from > 0 {
+		if before, err = s.versionRepo.FindVersion(ctx, id, from); err != nil {
+			return nil, err
+		}
+	}
+	after, err := s.versionRepo.FindVersion(ctx, id, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.KnowledgeItemDiff{
+		ItemID:  id,
+		From:    from,
+		To:      to,
+		Changes: diffKnowledgeVersions(before, after),
+	}, nil
+}
+
+// ListAuditEvents lists who changed what in a knowledge base, newest first
+func (s *KnowledgeService) ListAuditEvents(ctx context.Context, kbID string, filter *entity.KnowledgeAuditFilter, params *repository.ListParams) ([]*entity.KnowledgeAuditEvent, int64, error) {
+	if err := s.requireVersioning(); err != nil {
+		return nil, 0, err
+	}
+	return s.versionRepo.ListAuditEvents(ctx, kbID, filter, params)
+}
+
+func (s *KnowledgeService) requireVersioning() error {
+	if s.versionRepo == nil {
+		return errors.New(errors.ErrCodeBadRequest, "knowledge versioning is not enabled")
+	}
+	return nil
+}
+
+// audit records a change in the audit trail; a failure does not undo the change
+func (s *KnowledgeService) audit(ctx context.Context, item *entity.KnowledgeItem, action entity.KnowledgeAction, version int, userID string) {
+	if s.versionRepo == nil {
+		return
+	}
+	s.versionRepo.CreateAuditEvent(ctx, &entity.KnowledgeAuditEvent{
+		ID:              uuid.New().String(),
+		KnowledgeBaseID: item.KnowledgeBaseID,
+		ItemID:          item.ID,
+		Action:          action,
+		Version:         version,
+		UserID:          userID,
+		Question:        item.Question,
+		CreatedAt:       s.now(),
+	})
+}
+
+// diffKnowledgeVersions lists the fields that differ between two versions
+func diffKnowledgeVersions(before, after *entity.KnowledgeItemVersion) []entity.KnowledgeFieldChange {
+	changes := make([]entity.KnowledgeFieldChange, 0)
+	add := func(field, a, b string, lines bool) {
+		if a == b {
+			return
+		}
+		change := entity.KnowledgeFieldChange{Field: field, Before: a, After: b}
+		if lines {
+			change.Lines = diffLines(a, b)
+		}
+		changes = append(changes, change)
+	}
+
+	add("question", before.Question, after.Question, true)
+	add("answer", before.Answer, after.Answer, true)
+	add("keywords", strings.Join(before.Keywords, ", "), strings.Join(after.Keywords, ", "), false)
+	add("source", before.Source, after.Source, false)
+	add("metadata", formatKnowledgeMetadata(before.Metadata), formatKnowledgeMetadata(after.Metadata), true)
+	return changes
+}
+
+// formatKnowledgeMetadata renders metadata as sorted key=value lines
+func formatKnowledgeMetadata(metadata map[string]string) string {
+	lines := make([]string, 0, len(metadata))
+	for key, value := range metadata {
+		lines = append(lines, key+"="+value)
+	}
+	sort.Strings(lines)
+	return strings.Join(lines, "\n")
+}
+
+// diffLines computes a line diff from the longest common subsequence of lines
+func diffLines(a, b string) []entity.KnowledgeDiffLine {
+	splitLines := func(text string) []string {
+		if text == "" {
+			return nil
+		}
+		return strings.Split(text, "\n")
+	}
+	before, after := splitLines(a), splitLines(b)
+
+	// common[i][j] is the length of the longest common subsequence of before[i:] and after[j:]
+	common := make([][]int, len(before)+1)
+	for i := range common {
+		common[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else if common[i+1][j] >= common[i][j+1] {
+				common[i][j] = common[i+1][j]
+			} else {
+				common[i][j] = common[i][j+1]
+			}
+		}
+	}
+
+	lines := make([]entity.KnowledgeDiffLine, 0, len(before)+len(after))
+	i, j := 0, 0
+	for i < len(before) && j < len(after) {
+		switch {
+		case before[i] == after[j]:
+			lines = append(lines, entity.KnowledgeDiffLine{Op: entity.KnowledgeDiffEqual, Text: before[i]})
+			i++
+			j++
+		case common[i+1][j] >= common[i][j+1]:
+			lines = append(lines, entity.KnowledgeDiffLine{Op: entity.KnowledgeDiffDelete, Text: before[i]})
+			i++
+		default:
+			lines = append(lines, entity.KnowledgeDiffLine{Op: entity.KnowledgeDiffInsert, Text: after[j]})
+			j++
+		}
+	}
+	for ; i < len(before); i++ {
+		lines = append(lines, entity.KnowledgeDiffLine{Op: entity.KnowledgeDiffDelete, Text: before[i]})
+	}
+	for ; j < len(after); j++ {
+		lines = append(lines, entity.KnowledgeDiffLine{Op: entity.KnowledgeDiffInsert, Text: after[j]})
+	}
+	return lines
+}
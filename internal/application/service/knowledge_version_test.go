@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeKnowledgeVersionRepo struct {
+	mu       sync.Mutex
+	versions map[string][]*entity.KnowledgeItemVersion
+	events   []*entity.KnowledgeAuditEvent
+}
+
+func newFakeKnowledgeVersionRepo() *fakeKnowledgeVersionRepo {
+	return &fakeKnowledgeVersionRepo{versions: make(map[string][]*entity.KnowledgeItemVersion)}
+}
+
+func (r *fakeKnowledgeVersionRepo) CreateVersion(ctx context.Context, version *entity.KnowledgeItemVersion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.versions[version.ItemID] {
+		if existing.Version == version.Version {
+			return errors.Conflict("version exists")
+		}
+	}
+	stored := *version
+	r.versions[version.ItemID] = append(r.versions[version.ItemID], &stored)
+	return nil
+}
+
+func (r *fakeKnowledgeVersionRepo) FindVersion(ctx context.Context, itemID string, version int) (*entity.KnowledgeItemVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.versions[itemID] {
+		if existing.Version == version {
+			found := *existing
+			return &found, nil
+		}
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "knowledge item version not found")
+}
+
+func (r *fakeKnowledgeVersionRepo) ListVersions(ctx context.Context, itemID string) ([]*entity.KnowledgeItemVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	versions := append([]*entity.KnowledgeItemVersion(nil), r.versions[itemID]...)
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version > versions[j].Version })
+	return versions, nil
+}
+
+func (r *fakeKnowledgeVersionRepo) MarkVersionPublished(ctx context.Context, version *entity.KnowledgeItemVersion) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, existing := range r.versions[version.ItemID] {
+		if existing.Version == version.Version {
+			existing.PublishedBy = version.PublishedBy
+			existing.PublishedAt = version.PublishedAt
+			return nil
+		}
+	}
+	return errors.New(errors.ErrCodeNotFound, "knowledge item version not found")
+}
+
+func (r *fakeKnowledgeVersionRepo) CreateAuditEvent(ctx context.Context, event *entity.KnowledgeAuditEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *fakeKnowledgeVersionRepo) ListAuditEvents(ctx context.Context, kbID string, filter *entity.KnowledgeAuditFilter, params *repository.ListParams) ([]*entity.KnowledgeAuditEvent, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var events []*entity.KnowledgeAuditEvent
+	for i := len(r.events) - 1; i >= 0; i-- {
+		event := r.events[i]
+		if event.KnowledgeBaseID == kbID && (filter == nil || filter.ItemID == "" || filter.ItemID == event.ItemID) {
+			events = append(events, event)
+		}
+	}
+	return events, int64(len(events)), nil
+}
+
+func (r *fakeKnowledgeVersionRepo) actions() []entity.KnowledgeAction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	actions := make([]entity.KnowledgeAction, len(r.events))
+	for i, event := range r.events {
+		actions[i] = event.Action
+	}
+	return actions
+}
+
+func newVersionedKnowledgeService(t *testing.T, reviewRequired bool) (*KnowledgeService, *fakeKnowledgeVersionRepo, *entity.KnowledgeBase) {
+	svc := NewKnowledgeService(newMockKnowledgeBaseRepo(), newMockKnowledgeItemRepo(), nil, nil)
+	versions := newFakeKnowledgeVersionRepo()
+	svc.SetVersionRepository(versions)
+
+	kb, err := svc.CreateKnowledgeBase(context.Background(), &CreateKnowledgeBaseInput{
+		TenantID: "tenant-1",
+		Name:     "Support",
+		Type:     entity.KnowledgeTypeFAQ,
+		Config:   &entity.KnowledgeConfig{ReviewRequired: reviewRequired},
+	})
+	require.NoError(t, err)
+	return svc, versions, kb
+}
+
+func TestKnowledgeService_PublishWorkflow(t *testing.T) {
+	svc, versions, kb := newVersionedKnowledgeService(t, true)
+	ctx := context.Background()
+
+	item, err := svc.AddItem(ctx, &AddItemInput{
+		KnowledgeBaseID: kb.ID,
+		Question:        "What is the return policy?",
+		Answer:          "Returns within 30 days.",
+		CreatedBy:       "user-1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, entity.KnowledgeItemStatusDraft, item.Status)
+	assert.Equal(t, 1, item.Version)
+	assert.Equal(t, 0, item.PublishedVersion)
+
+	// Drafts are not retrieved
+	results, err := svc.Search(ctx, "tenant-1", kb.ID, "return", 5)
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	item, err = svc.PublishItem(ctx, item.ID, "supervisor-1")
+	require.NoError(t, err)
+	assert.Equal(t, entity.KnowledgeItemStatusPublished, item.Status)
+	assert.Equal(t, 1, item.PublishedVersion)
+	assert.Equal(t, "supervisor-1", item.PublishedBy)
+
+	results, err = svc.Search(ctx, "tenant-1", kb.ID, "return", 5)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	// An edit waits as a draft while bots keep the published answer
+	answer := "Returns within 30 days.\nRefunds take 5 days."
+	item, err = svc.UpdateItem(ctx, item.ID, &UpdateItemInput{Answer: &answer, UpdatedBy: "user-2"})
+	require.NoError(t, err)
+	assert.Equal(t, "Returns within 30 days.", item.Answer)
+	assert.Equal(t, 2, item.Version)
+	assert.True(t, item.HasDraft())
+	require.NotNil(t, item.Draft)
+	assert.Equal(t, answer, item.Draft.Answer)
+
+	results, err = svc.Search(ctx, "tenant-1", kb.ID, "return", 5)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Returns within 30 days.", results[0].Item.Answer)
+
+	diff, err := svc.DiffVersions(ctx, item.ID, 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, diff.From)
+	assert.Equal(t, 2, diff.To)
+	require.Len(t, diff.Changes, 1)
+	assert.Equal(t, "answer", diff.Changes[0].Field)
+	assert.Equal(t, []entity.KnowledgeDiffLine{
+		{Op: entity.KnowledgeDiffEqual, Text: "Returns within 30 days."},
+		{Op: entity.KnowledgeDiffInsert, Text: "Refunds take 5 days."},
+	}, diff.Changes[0].Lines)
+
+	item, err = svc.PublishItem(ctx, item.ID, "supervisor-1")
+	require.NoError(t, err)
+	assert.Equal(t, answer, item.Answer)
+	assert.Equal(t, 2, item.PublishedVersion)
+	assert.False(t, item.HasDraft())
+
+	_, err = svc.PublishItem(ctx, item.ID, "supervisor-1")
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code)
+
+	// Rolling back restores version 1 as a new published version
+	item, err = svc.RollbackItem(ctx, item.ID, 1, "supervisor-1")
+	require.NoError(t, err)
+	assert.Equal(t, "Returns within 30 days.", item.Answer)
+	assert.Equal(t, 3, item.Version)
+	assert.Equal(t, 3, item.PublishedVersion)
+
+	history, err := svc.ListVersions(ctx, item.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, entity.KnowledgeActionRolledBack, history[0].Action)
+	assert.Equal(t, 1, history[0].RestoredFrom)
+	assert.Equal(t, "user-2", history[1].CreatedBy)
+	assert.NotNil(t, history[1].PublishedAt)
+
+	require.NoError(t, svc.DeleteItem(ctx, item.ID, "admin-1"))
+	assert.Equal(t, []entity.KnowledgeAction{
+		entity.KnowledgeActionCreated,
+		entity.KnowledgeActionPublished,
+		entity.KnowledgeActionEdited,
+		entity.KnowledgeActionPublished,
+		entity.KnowledgeActionRolledBack,
+		entity.KnowledgeActionPublished,
+		entity.KnowledgeActionDeleted,
+	}, versions.actions())
+
+	events, _, err := svc.ListAuditEvents(ctx, kb.ID, &entity.KnowledgeAuditFilter{ItemID: item.ID}, nil)
+	require.NoError(t, err)
+	require.Len(t, events, 7)
+	assert.Equal(t, "admin-1", events[0].UserID)
+	assert.Equal(t, "What is the return policy?", events[0].Question)
+}
+
+func TestKnowledgeService_PublishKnowledgeBase(t *testing.T) {
+	svc, _, kb := newVersionedKnowledgeService(t, true)
+	ctx := context.Background()
+
+	first, err := svc.AddItem(ctx, &AddItemInput{KnowledgeBaseID: kb.ID, Question: "Hours?", Answer: "9 to 5"})
+	require.NoError(t, err)
+	second, err := svc.AddItem(ctx, &AddItemInput{KnowledgeBaseID: kb.ID, Question: "Shipping?", Answer: "2 days"})
+	require.NoError(t, err)
+
+	// A reviewed addition is published right away
+	reviewed, err := svc.AddItem(ctx, &AddItemInput{KnowledgeBaseID: kb.ID, Question: "Returns?", Answer: "30 days", Reviewed: true})
+	require.NoError(t, err)
+	assert.True(t, reviewed.IsPublished())
+
+	// Drafts of never published items carry the latest content
+	question := "Opening hours?"
+	first, err = svc.UpdateItem(ctx, first.ID, &UpdateItemInput{Question: &question})
+	require.NoError(t, err)
+	assert.Equal(t, "Opening hours?", first.Question)
+	assert.Nil(t, first.Draft)
+
+	result, err := svc.PublishKnowledgeBase(ctx, kb.ID, "supervisor-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Published)
+	assert.ElementsMatch(t, []string{first.ID, second.ID}, result.ItemIDs)
+
+	published, err := svc.GetItem(ctx, first.ID)
+	require.NoError(t, err)
+	assert.True(t, published.IsPublished())
+	assert.Equal(t, 2, published.PublishedVersion)
+
+	result, err = svc.PublishKnowledgeBase(ctx, kb.ID, "supervisor-1")
+	require.NoError(t, err)
+	assert.Zero(t, result.Published)
+}
+
+func TestKnowledgeService_VersionsWithoutReview(t *testing.T) {
+	svc, _, kb := newVersionedKnowledgeService(t, false)
+	ctx := context.Background()
+
+	item, err := svc.AddItem(ctx, &AddItemInput{KnowledgeBaseID: kb.ID, Question: "Hours?", Answer: "9 to 5"})
+	require.NoError(t, err)
+	assert.True(t, item.IsPublished())
+
+	answer := "8 to 6"
+	item, err = svc.UpdateItem(ctx, item.ID, &UpdateItemInput{Answer: &answer})
+	require.NoError(t, err)
+	assert.Equal(t, "8 to 6", item.Answer)
+	assert.Equal(t, 2, item.PublishedVersion)
+
+	_, err = svc.RollbackItem(ctx, item.ID, 2, "")
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code)
+
+	_, err = svc.RollbackItem(ctx, item.ID, 9, "")
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestDiffLines(t *testing.T) {
+	lines := diffLines("a\nb\nc", "a\nc\nd")
+	assert.Equal(t, []entity.KnowledgeDiffLine{
+		{Op: entity.KnowledgeDiffEqual, Text: "a"},
+		{Op: entity.KnowledgeDiffDelete, Text: "b"},
+		{Op: entity.KnowledgeDiffEqual, Text: "c"},
+		{Op: entity.KnowledgeDiffInsert, Text: "d"},
+	}, lines)
+
+	assert.Equal(t, []entity.KnowledgeDiffLine{{Op: entity.KnowledgeDiffInsert, Text: "new"}}, diffLines("", "new"))
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// LabelInput represents input for creating or updating a label
+type LabelInput struct {
+	Name        string `json:"name" binding:"required"`
+	Color       string `json:"color,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// LabelService manages the tenant's conversation labels and attaches them to conversations
+type LabelService struct {
+	repo             repository.LabelRepository
+	conversationRepo repository.ConversationRepository
+}
+
+// NewLabelService creates a new label service
+func NewLabelService(repo repository.LabelRepository, conversationRepo repository.ConversationRepository) *LabelService {
+	return &LabelService{
+		repo:             repo,
+		conversationRepo: conversationRepo,
+	}
+}
+
+// Create creates a new label
+func (s *LabelService) Create(ctx context.Context, tenantID string, input *LabelInput) (*entity.Label, error) {
+	name, err := s.validate(ctx, tenantID, "", input)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	label := &entity.Label{
+		ID:          uuid.New().String(),
+		TenantID:    tenantID,
+		Name:        name,
+		Color:       input.Color,
+		Description: input.Description,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.repo.Create(ctx, label); err != nil {
+		return nil, err
+	}
+	return label, nil
+}
+
+// Get returns a label of the tenant
+func (s *LabelService) Get(ctx context.Context, tenantID, id string) (*entity.Label, error) {
+	label, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if label.TenantID != tenantID {
+		return nil, errors.NotFound("label")
+	}
+	return label, nil
+}
+
+// List lists the labels of a tenant
+func (s *LabelService) List(ctx context.Context, tenantID string) ([]*entity.Label, error) {
+	return s.repo.FindByTenant(ctx, tenantID)
+}
+
+// Update updates a label. Renames show on every conversation carrying the label.
+func (s *LabelService) Update(ctx context.Context, tenantID, id string, input *LabelInput) (*entity.Label, error) {
+	label, err := s.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	name, err := s.validate(ctx, tenantID, id, input)
+	if err != nil {
+		return nil, err
+	}
+
+	label.Name = name
+	label.Color = input.Color
+	label.Description = input.Description
+	label.UpdatedAt = time.Now()
+	if err := s.repo.Update(ctx, label); err != nil {
+		return nil, err
+	}
+	return label, nil
+}
+
+// Delete deletes a label and detaches it from its conversations
+func (s *LabelService) Delete(ctx context.Context, tenantID, id string) error {
+	if _, err := s.Get(ctx, tenantID, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// ListForConversation lists the labels attached to a conversation
+func (s *LabelService) ListForConversation(ctx context.Context, tenantID, conversationID string) ([]*entity.Label, error) {
+	if err := s.checkConversation(ctx, tenantID, conversationID); err != nil {
+		return nil, err
+	}
+	return s.labelsOf(ctx, conversationID)
+}
+
+// Attach attaches labels to a conversation and returns its labels
+func (s *LabelService) Attach(ctx context.Context, tenantID, userID, conversationID string, labelIDs []string) ([]*entity.Label, error) {
+	if len(labelIDs) == 0 {
+		return nil, errors.Validation("label_ids is required")
+	}
+	if err := s.checkConversation(ctx, tenantID, conversationID); err != nil {
+		return nil, err
+	}
+	for _, labelID := range labelIDs {
+		if _, err := s.Get(ctx, tenantID, labelID); err != nil {
+			return nil, err
+		}
+	}
+	for _, labelID := range labelIDs {
+		if err := s.repo.Attach(ctx, conversationID, labelID, userID); err != nil {
+			return nil, err
+		}
+	}
+	return s.labelsOf(ctx, conversationID)
+}
+
+// Detach removes a label from a conversation and returns its remaining labels
+func (s *LabelService) Detach(ctx context.Context, tenantID, conversationID, labelID string) ([]*entity.Label, error) {
+	if err := s.checkConversation(ctx, tenantID, conversationID); err != nil {
+		return nil, err
+	}
+	if _, err := s.Get(ctx, tenantID, labelID); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Detach(ctx, conversationID, labelID); err != nil {
+		return nil, err
+	}
+	return s.labelsOf(ctx, conversationID)
+}
+
+func (s *LabelService) labelsOf(ctx context.Context, conversationID string) ([]*entity.Label, error) {
+	labels, err := s.repo.FindByConversation(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if labels == nil {
+		labels = []*entity.Label{}
+	}
+	return labels, nil
+}
+
+func (s *LabelService) checkConversation(ctx context.Context, tenantID, conversationID string) error {
+	conversation, err := s.conversationRepo.FindByID(ctx, conversationID)
+	if err != nil || conversation.TenantID != tenantID {
+		return errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+	}
+	return nil
+}
+
+// validate checks a label input and returns its normalized name. Names are unique
+// per tenant regardless of case; excludeID is the label being updated.
+func (s *LabelService) validate(ctx context.Context, tenantID, excludeID string, input *LabelInput) (string, error) {
+	name := entity.NormalizeLabelName(input.Name)
+	if name == "" {
+		return "", errors.Validation("label name is required")
+	}
+	if utf8.RuneCountInString(name) > entity.MaxLabelNameLength {
+		return "", errors.Validation("label name must be at most 50 characters")
+	}
+	if !entity.IsValidLabelColor(input.Color) {
+		return "", errors.Validation("label color must be a hex color such as #1F93FF")
+	}
+
+	existing, err := s.repo.FindByName(ctx, tenantID, name)
+	if err != nil && !errors.IsNotFound(err) {
+		return "", err
+	}
+	if existing != nil && existing.ID != excludeID {
+		return "", errors.Conflict("a label with this name already exists")
+	}
+	return name, nil
+}
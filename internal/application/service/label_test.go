@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockLabelRepository is an inline mock for LabelRepository
+type mockLabelRepository struct {
+	labels   map[string]*entity.Label
+	attached map[string]map[string]bool // conversation ID -> label IDs
+}
+
+func newMockLabelRepository() *mockLabelRepository {
+	return &mockLabelRepository{
+		labels:   make(map[string]*entity.Label),
+		attached: make(map[string]map[string]bool),
+	}
+}
+
+func (m *mockLabelRepository) Create(ctx context.Context, label *entity.Label) error {
+	m.labels[label.ID] = label
+	return nil
+}
+
+func (m *mockLabelRepository) FindByID(ctx context.Context, id string) (*entity.Label, error) {
+	label, ok := m.labels[id]
+	if !ok {
+		return nil, errors.NotFound("label")
+	}
+	return label, nil
+}
+
+func (m *mockLabelRepository) FindByName(ctx context.Context, tenantID, name string) (*entity.Label, error) {
+	for _, label := range m.labels {
+		if label.TenantID == tenantID && strings.EqualFold(label.Name, name) {
+			return label, nil
+		}
+	}
+	return nil, errors.NotFound("label")
+}
+
+func (m *mockLabelRepository) FindByTenant(ctx context.Context, tenantID string) ([]*entity.Label, error) {
+	var result []*entity.Label
+	for _, label := range m.labels {
+		if label.TenantID == tenantID {
+			result = append(result, label)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+func (m *mockLabelRepository) Update(ctx context.Context, label *entity.Label) error {
+	m.labels[label.ID] = label
+	return nil
+}
+
+func (m *mockLabelRepository) Delete(ctx context.Context, id string) error {
+	delete(m.labels, id)
+	for _, labels := range m.attached {
+		delete(labels, id)
+	}
+	return nil
+}
+
+func (m *mockLabelRepository) FindByConversation(ctx context.Context, conversationID string) ([]*entity.Label, error) {
+	var result []*entity.Label
+	for id := range m.attached[conversationID] {
+		result = append(result, m.labels[id])
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+func (m *mockLabelRepository) Attach(ctx context.Context, conversationID, labelID, userID string) error {
+	if m.attached[conversationID] == nil {
+		m.attached[conversationID] = make(map[string]bool)
+	}
+	m.attached[conversationID][labelID] = true
+	return nil
+}
+
+func (m *mockLabelRepository) Detach(ctx context.Context, conversationID, labelID string) error {
+	delete(m.attached[conversationID], labelID)
+	return nil
+}
+
+func newLabelTestService() (*LabelService, *mockLabelRepository) {
+	repo := newMockLabelRepository()
+	conversations := testutil.NewMockConversationRepository()
+	conversations.Conversations["conv-1"] = &entity.Conversation{ID: "conv-1", TenantID: "tenant-1"}
+	conversations.Conversations["conv-2"] = &entity.Conversation{ID: "conv-2", TenantID: "tenant-2"}
+	return NewLabelService(repo, conversations), repo
+}
+
+func TestLabelService_Create(t *testing.T) {
+	svc, _ := newLabelTestService()
+	ctx := context.Background()
+
+	label, err := svc.Create(ctx, "tenant-1", &LabelInput{Name: "  VIP   customer ", Color: "#1F93FF"})
+	require.NoError(t, err)
+	assert.Equal(t, "VIP customer", label.Name)
+	assert.Equal(t, "tenant-1", label.TenantID)
+
+	_, err = svc.Create(ctx, "tenant-1", &LabelInput{Name: "vip customer"})
+	assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code, "names are unique regardless of case")
+
+	_, err = svc.Create(ctx, "tenant-2", &LabelInput{Name: "VIP customer"})
+	assert.NoError(t, err, "other tenants may reuse the name")
+
+	_, err = svc.Create(ctx, "tenant-1", &LabelInput{Name: "Billing", Color: "blue"})
+	assert.True(t, errors.IsValidation(err))
+
+	_, err = svc.Create(ctx, "tenant-1", &LabelInput{Name: strings.Repeat("a", entity.MaxLabelNameLength+1)})
+	assert.True(t, errors.IsValidation(err))
+}
+
+func TestLabelService_Update(t *testing.T) {
+	svc, _ := newLabelTestService()
+	ctx := context.Background()
+
+	billing, err := svc.Create(ctx, "tenant-1", &LabelInput{Name: "Billing"})
+	require.NoError(t, err)
+	_, err = svc.Create(ctx, "tenant-1", &LabelInput{Name: "Shipping"})
+	require.NoError(t, err)
+
+	updated, err := svc.Update(ctx, "tenant-1", billing.ID, &LabelInput{Name: "billing", Color: "#FF0000"})
+	require.NoError(t, err, "a label may change the case of its own name")
+	assert.Equal(t, "billing", updated.Name)
+	assert.Equal(t, "#FF0000", updated.Color)
+
+	_, err = svc.Update(ctx, "tenant-1", billing.ID, &LabelInput{Name: "SHIPPING"})
+	assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code)
+
+	_, err = svc.Update(ctx, "tenant-2", billing.ID, &LabelInput{Name: "Other"})
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestLabelService_AttachDetach(t *testing.T) {
+	svc, _ := newLabelTestService()
+	ctx := context.Background()
+
+	billing, err := svc.Create(ctx, "tenant-1", &LabelInput{Name: "Billing"})
+	require.NoError(t, err)
+	urgent, err := svc.Create(ctx, "tenant-1", &LabelInput{Name: "Urgent"})
+	require.NoError(t, err)
+	foreign, err := svc.Create(ctx, "tenant-2", &LabelInput{Name: "Foreign"})
+	require.NoError(t, err)
+
+	labels, err := svc.Attach(ctx, "tenant-1", "user-1", "conv-1", []string{billing.ID, urgent.ID})
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.Label{billing, urgent}, labels)
+
+	labels, err = svc.Attach(ctx, "tenant-1", "user-1", "conv-1", []string{billing.ID})
+	require.NoError(t, err)
+	assert.Len(t, labels, 2, "attaching twice is a no-op")
+
+	_, err = svc.Attach(ctx, "tenant-1", "user-1", "conv-1", []string{foreign.ID})
+	assert.True(t, errors.IsNotFound(err), "labels of other tenants cannot be attached")
+
+	_, err = svc.Attach(ctx, "tenant-1", "user-1", "conv-2", []string{billing.ID})
+	assert.Equal(t, errors.ErrCodeConversationNotFound, errors.GetAppError(err).Code)
+
+	labels, err = svc.Detach(ctx, "tenant-1", "conv-1", billing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []*entity.Label{urgent}, labels)
+
+	require.NoError(t, svc.Delete(ctx, "tenant-1", urgent.ID))
+	labels, err = svc.ListForConversation(ctx, "tenant-1", "conv-1")
+	require.NoError(t, err)
+	assert.Empty(t, labels)
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// languageDetectionMinHits is how many stopwords of a language a text needs to be detected
+	languageDetectionMinHits = 2
+	// languageDetectionMinLead is how many more hits the detected language needs than the runner-up
+	languageDetectionMinLead = 1
+)
+
+// languageStopwords are frequent words that tell the languages customers write in
+// apart. Words shared by several languages, such as "a", "no" or "de", count for
+// all of them and so only decide the language together with other words.
+var languageStopwords = map[string][]string{
+	"en": {"the", "and", "is", "are", "you", "my", "i", "to", "of", "it", "this", "that", "with", "for", "have",
+		"not", "can", "please", "hello", "hi", "thanks", "what", "how", "order", "was", "do", "does", "your", "me"},
+	"pt": {"o", "a", "os", "as", "e", "é", "não", "um", "uma", "do", "da", "que", "com", "para", "meu", "minha",
+		"você", "obrigado", "obrigada", "olá", "oi", "tudo", "bem", "pedido", "por", "favor", "está", "estou", "isso"},
+	"es": {"el", "la", "los", "las", "y", "es", "no", "un", "una", "del", "que", "con", "para", "mi", "usted",
+		"gracias", "hola", "pedido", "por", "favor", "está", "estoy", "eso", "qué", "cómo", "tengo", "muy"},
+	"fr": {"le", "la", "les", "et", "est", "ne", "pas", "un", "une", "des", "que", "avec", "pour", "mon", "ma",
+		"vous", "merci", "bonjour", "commande", "je", "suis", "ce", "c'est", "s'il", "plaît", "du", "au"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "ein", "eine", "mit", "für", "mein", "meine", "sie",
+		"danke", "hallo", "bestellung", "ich", "bin", "bitte", "es", "zu", "den", "dem", "wie", "habe"},
+	"it": {"il", "lo", "la", "gli", "le", "e", "è", "non", "un", "una", "del", "che", "con", "per", "mio", "mia",
+		"grazie", "ciao", "ordine", "sono", "questo", "favore", "buongiorno", "ho", "come"},
+}
+
+// DetectLanguage guesses the language of a customer text among the languages the
+// stopword lists cover. It returns an empty string when the text is too short or
+// ambiguous to tell.
+func DetectLanguage(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	if len(words) == 0 {
+		return ""
+	}
+
+	hits := make(map[string]int, len(languageStopwords))
+	for _, word := range words {
+		for language, stopwords := range languageStopwords {
+			for _, stopword := range stopwords {
+				if word == stopword {
+					hits[language]++
+					break
+				}
+			}
+		}
+	}
+
+	best, bestHits, runnerUp := "", 0, 0
+	for language, count := range hits {
+		if count > bestHits {
+			best, bestHits, runnerUp = language, count, bestHits
+		} else if count > runnerUp {
+			runnerUp = count
+		}
+	}
+	if bestHits < languageDetectionMinHits || bestHits-runnerUp < languageDetectionMinLead {
+		return ""
+	}
+	return best
+}
@@ -0,0 +1,774 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Events published for linked objects
+const (
+	EventLinkedObjectCreated = "linked_object.created"
+	EventLinkedObjectUpdated = "linked_object.updated"
+	EventLinkedObjectDeleted = "linked_object.deleted"
+)
+
+// Signature headers of connector webhooks. Generic connectors sign the body as
+// "sha256=<hex hmac>", Shopify and Zendesk use their own schemes.
+const (
+	LinkedObjectSignatureHeader = "X-Linktor-Signature"
+	shopifySignatureHeader      = "X-Shopify-Hmac-Sha256"
+	zendeskSignatureHeader      = "X-Zendesk-Webhook-Signature"
+	zendeskTimestampHeader      = "X-Zendesk-Webhook-Signature-Timestamp"
+)
+
+// MessageMetaLinkedObjectID marks messages sent for a linked object status change
+const MessageMetaLinkedObjectID = "linked_object_id"
+
+// linkedObjectFields are the object fields a connector field map can fill, besides attributes.<key>
+var linkedObjectFields = map[string]bool{
+	"external_id":     true,
+	"reference":       true,
+	"title":           true,
+	"status":          true,
+	"url":             true,
+	"contact_email":   true,
+	"contact_phone":   true,
+	"conversation_id": true,
+	"updated_at":      true,
+}
+
+// linkedObjectProviderFields are the default field maps of each provider's webhook payload
+var linkedObjectProviderFields = map[entity.LinkedObjectProvider]map[string]string{
+	entity.LinkedObjectProviderGeneric: {
+		"external_id":     "id",
+		"reference":       "reference",
+		"title":           "title",
+		"status":          "status",
+		"url":             "url",
+		"contact_email":   "contact.email",
+		"contact_phone":   "contact.phone",
+		"conversation_id": "conversation_id",
+		"updated_at":      "updated_at",
+	},
+	// Shopify orders/create, orders/updated and orders/fulfilled topics
+	entity.LinkedObjectProviderShopify: {
+		"external_id":                 "id",
+		"reference":                   "name",
+		"status":                      "fulfillment_status",
+		"url":                         "order_status_url",
+		"contact_email":               "email",
+		"contact_phone":               "customer.phone",
+		"updated_at":                  "updated_at",
+		"attributes.total":            "total_price",
+		"attributes.currency":         "currency",
+		"attributes.financial_status": "financial_status",
+		"attributes.tracking_number":  "fulfillments.0.tracking_number",
+		"attributes.tracking_url":     "fulfillments.0.tracking_url",
+	},
+	// Zendesk webhooks with a {"ticket": {...}} JSON body built from ticket placeholders
+	entity.LinkedObjectProviderZendesk: {
+		"external_id":         "ticket.id",
+		"reference":           "ticket.id",
+		"title":               "ticket.title",
+		"status":              "ticket.status",
+		"url":                 "ticket.url",
+		"contact_email":       "ticket.requester.email",
+		"contact_phone":       "ticket.requester.phone",
+		"updated_at":          "ticket.updated_at",
+		"attributes.priority": "ticket.priority",
+	},
+}
+
+// linkedObjectProviderTypes are the object types a provider syncs when none is given
+var linkedObjectProviderTypes = map[entity.LinkedObjectProvider]entity.LinkedObjectType{
+	entity.LinkedObjectProviderShopify: entity.LinkedObjectTypeOrder,
+	entity.LinkedObjectProviderZendesk: entity.LinkedObjectTypeTicket,
+}
+
+// LinkedObjectInput represents a request to create or update a linked object
+type LinkedObjectInput struct {
+	Type           string            `json:"type"`
+	ExternalID     string            `json:"external_id,omitempty"`
+	Reference      string            `json:"reference"`
+	Title          string            `json:"title,omitempty"`
+	Status         string            `json:"status,omitempty"`
+	URL            string            `json:"url,omitempty"`
+	ConversationID string            `json:"conversation_id,omitempty"`
+	ContactID      string            `json:"contact_id,omitempty"`
+	Attributes     map[string]string `json:"attributes,omitempty"`
+	CreatedBy      string            `json:"-"`
+}
+
+// LinkedObjectConnectorInput represents a request to create or update a connector
+type LinkedObjectConnectorInput struct {
+	Name           string            `json:"name"`
+	Provider       string            `json:"provider"`
+	ObjectType     string            `json:"object_type,omitempty"`
+	FieldMap       map[string]string `json:"field_map,omitempty"`
+	StatusMessages map[string]string `json:"status_messages,omitempty"`
+	IsActive       *bool             `json:"is_active,omitempty"`
+}
+
+// LinkedObjectConnectorSecret is a connector together with its webhook signing secret,
+// returned only when the connector is created or its secret rotated
+type LinkedObjectConnectorSecret struct {
+	*entity.LinkedObjectConnector
+	Secret string `json:"secret"`
+}
+
+// LinkedObjectService manages orders, tickets and bookings linked to conversations,
+// created by hand or synced from the webhooks of external systems
+type LinkedObjectService struct {
+	objectRepo       repository.LinkedObjectRepository
+	connectorRepo    repository.LinkedObjectConnectorRepository
+	conversationRepo repository.ConversationRepository
+	contactRepo      repository.ContactRepository
+	messageService   *MessageService
+	producer         nats.Publisher
+	variables        *ConversationVariablesService
+	observer         LinkedObjectObserver
+}
+
+// LinkedObjectObserver is told when a linked object changes, so content derived from it
+// such as wallet passes can be refreshed
+type LinkedObjectObserver interface {
+	LinkedObjectChanged(ctx context.Context, object *entity.LinkedObject)
+}
+
+// NewLinkedObjectService creates a new linked object service
+func NewLinkedObjectService(
+	objectRepo repository.LinkedObjectRepository,
+	connectorRepo repository.LinkedObjectConnectorRepository,
+	conversationRepo repository.ConversationRepository,
+	contactRepo repository.ContactRepository,
+	messageService *MessageService,
+	producer nats.Publisher,
+) *LinkedObjectService {
+	return &LinkedObjectService{
+		objectRepo:       objectRepo,
+		connectorRepo:    connectorRepo,
+		conversationRepo: conversationRepo,
+		contactRepo:      contactRepo,
+		messageService:   messageService,
+		producer:         producer,
+	}
+}
+
+// SetVariablesService makes contact and conversation variables available to status messages
+func (s *LinkedObjectService) SetVariablesService(variables *ConversationVariablesService) {
+	s.variables = variables
+}
+
+// SetObserver sets the observer told about updated linked objects
+func (s *LinkedObjectService) SetObserver(observer LinkedObjectObserver) {
+	s.observer = observer
+}
+
+// Create creates a linked object by hand. The object is linked to a conversation, whose
+// contact it inherits, or only to a contact.
+func (s *LinkedObjectService) Create(ctx context.Context, tenantID string, input *LinkedObjectInput) (*entity.LinkedObject, error) {
+	objectType := entity.LinkedObjectType(strings.ToLower(strings.TrimSpace(input.Type)))
+	if !objectType.IsValid() {
+		return nil, errors.New(errors.ErrCodeValidation, "invalid linked object").
+			WithDetails(map[string]string{"type": "type must be order, ticket or booking"})
+	}
+
+	now := time.Now()
+	object := &entity.LinkedObject{
+		ID:         uuid.New().String(),
+		TenantID:   tenantID,
+		Type:       objectType,
+		Source:     entity.LinkedObjectSourceManual,
+		ExternalID: strings.TrimSpace(input.ExternalID),
+		CreatedBy:  input.CreatedBy,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := s.applyInput(ctx, object, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.objectRepo.Create(ctx, object); err != nil {
+		return nil, err
+	}
+	s.publish(ctx, EventLinkedObjectCreated, object, "")
+	return object, nil
+}
+
+// Get returns a linked object of a tenant
+func (s *LinkedObjectService) Get(ctx context.Context, tenantID, id string) (*entity.LinkedObject, error) {
+	object, err := s.objectRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if object.TenantID != tenantID {
+		return nil, errors.NotFound("linked object")
+	}
+	return object, nil
+}
+
+// List lists the linked objects of a tenant
+func (s *LinkedObjectService) List(ctx context.Context, tenantID string, filter *entity.LinkedObjectFilter, params *repository.ListParams) ([]*entity.LinkedObject, int64, error) {
+	return s.objectRepo.ListByTenant(ctx, tenantID, filter, params)
+}
+
+// ListForConversation lists the objects shown alongside a conversation
+func (s *LinkedObjectService) ListForConversation(ctx context.Context, tenantID, conversationID string) ([]*entity.LinkedObject, error) {
+	conversation, err := s.conversationRepo.FindByID(ctx, conversationID)
+	if err != nil || conversation.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+	}
+	objects, err := s.objectRepo.ListByConversation(ctx, conversation.ID)
+	if err != nil {
+		return nil, err
+	}
+	if objects == nil {
+		objects = []*entity.LinkedObject{}
+	}
+	return objects, nil
+}
+
+// Update updates a linked object. Its type and external ID cannot change.
+func (s *LinkedObjectService) Update(ctx context.Context, tenantID, id string, input *LinkedObjectInput) (*entity.LinkedObject, error) {
+	object, err := s.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	previousStatus := object.Status
+	if err := s.applyInput(ctx, object, input); err != nil {
+		return nil, err
+	}
+	object.UpdatedAt = time.Now()
+
+	if err := s.objectRepo.Update(ctx, object); err != nil {
+		return nil, err
+	}
+	s.publish(ctx, EventLinkedObjectUpdated, object, previousStatus)
+	return object, nil
+}
+
+// Delete deletes a linked object
+func (s *LinkedObjectService) Delete(ctx context.Context, tenantID, id string) error {
+	object, err := s.Get(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.objectRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.publish(ctx, EventLinkedObjectDeleted, object, "")
+	return nil
+}
+
+func (s *LinkedObjectService) applyInput(ctx context.Context, object *entity.LinkedObject, input *LinkedObjectInput) error {
+	reference := strings.TrimSpace(input.Reference)
+	if reference == "" {
+		return errors.New(errors.ErrCodeValidation, "invalid linked object").
+			WithDetails(map[string]string{"reference": "reference is required"})
+	}
+
+	conversationID := strings.TrimSpace(input.ConversationID)
+	contactID := strings.TrimSpace(input.ContactID)
+	if conversationID == "" && contactID == "" {
+		return errors.New(errors.ErrCodeValidation, "invalid linked object").
+			WithDetails(map[string]string{"conversation_id": "a conversation or a contact is required"})
+	}
+	if conversationID != "" {
+		conversation, err := s.conversationRepo.FindByID(ctx, conversationID)
+		if err != nil || conversation.TenantID != object.TenantID {
+			return errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+		}
+		contactID = conversation.ContactID
+	} else {
+		contact, err := s.contactRepo.FindByID(ctx, contactID)
+		if err != nil || contact.TenantID != object.TenantID {
+			return errors.NotFound("contact")
+		}
+	}
+
+	object.Reference = reference
+	object.Title = strings.TrimSpace(input.Title)
+	object.Status = strings.TrimSpace(input.Status)
+	object.URL = strings.TrimSpace(input.URL)
+	object.ConversationID = conversationID
+	object.ContactID = contactID
+	object.Attributes = input.Attributes
+	return nil
+}
+
+// CreateConnector creates a connector and returns its webhook signing secret
+func (s *LinkedObjectService) CreateConnector(ctx context.Context, tenantID string, input *LinkedObjectConnectorInput) (*LinkedObjectConnectorSecret, error) {
+	now := time.Now()
+	connector := &entity.LinkedObjectConnector{
+		ID:        uuid.New().String(),
+		TenantID:  tenantID,
+		Secret:    newConnectorSecret(),
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := applyLinkedObjectConnectorInput(connector, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.connectorRepo.Create(ctx, connector); err != nil {
+		return nil, err
+	}
+	return &LinkedObjectConnectorSecret{LinkedObjectConnector: connector, Secret: connector.Secret}, nil
+}
+
+// GetConnector returns a connector of a tenant
+func (s *LinkedObjectService) GetConnector(ctx context.Context, tenantID, id string) (*entity.LinkedObjectConnector, error) {
+	connector, err := s.connectorRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if connector.TenantID != tenantID {
+		return nil, errors.NotFound("linked object connector")
+	}
+	return connector, nil
+}
+
+// ListConnectors lists the connectors of a tenant
+func (s *LinkedObjectService) ListConnectors(ctx context.Context, tenantID string) ([]*entity.LinkedObjectConnector, error) {
+	connectors, err := s.connectorRepo.ListByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if connectors == nil {
+		connectors = []*entity.LinkedObjectConnector{}
+	}
+	return connectors, nil
+}
+
+// UpdateConnector updates a connector
+func (s *LinkedObjectService) UpdateConnector(ctx context.Context, tenantID, id string, input *LinkedObjectConnectorInput) (*entity.LinkedObjectConnector, error) {
+	connector, err := s.GetConnector(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyLinkedObjectConnectorInput(connector, input); err != nil {
+		return nil, err
+	}
+	connector.UpdatedAt = time.Now()
+
+	if err := s.connectorRepo.Update(ctx, connector); err != nil {
+		return nil, err
+	}
+	return connector, nil
+}
+
+// RotateConnectorSecret replaces the webhook signing secret of a connector
+func (s *LinkedObjectService) RotateConnectorSecret(ctx context.Context, tenantID, id string) (*LinkedObjectConnectorSecret, error) {
+	connector, err := s.GetConnector(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	connector.Secret = newConnectorSecret()
+	connector.UpdatedAt = time.Now()
+
+	if err := s.connectorRepo.Update(ctx, connector); err != nil {
+		return nil, err
+	}
+	return &LinkedObjectConnectorSecret{LinkedObjectConnector: connector, Secret: connector.Secret}, nil
+}
+
+// DeleteConnector deletes a connector. Objects it synced are kept as they are.
+func (s *LinkedObjectService) DeleteConnector(ctx context.Context, tenantID, id string) error {
+	if _, err := s.GetConnector(ctx, tenantID, id); err != nil {
+		return err
+	}
+	return s.connectorRepo.Delete(ctx, id)
+}
+
+func applyLinkedObjectConnectorInput(connector *entity.LinkedObjectConnector, input *LinkedObjectConnectorInput) error {
+	details := map[string]string{}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		details["name"] = "name is required"
+	}
+	provider := entity.LinkedObjectProvider(strings.ToLower(strings.TrimSpace(input.Provider)))
+	if !provider.IsValid() {
+		details["provider"] = "provider must be generic, shopify or zendesk"
+	}
+
+	objectType := entity.LinkedObjectType(strings.ToLower(strings.TrimSpace(input.ObjectType)))
+	if objectType == "" {
+		objectType = linkedObjectProviderTypes[provider]
+	}
+	if !objectType.IsValid() {
+		details["object_type"] = "object_type must be order, ticket or booking"
+	}
+
+	for field, path := range input.FieldMap {
+		if !linkedObjectFields[field] && !strings.HasPrefix(field, "attributes.") {
+			details["field_map."+field] = "unknown field"
+		} else if strings.TrimSpace(path) == "" {
+			details["field_map."+field] = "path is required"
+		}
+	}
+	for status, text := range input.StatusMessages {
+		if strings.TrimSpace(status) == "" || strings.TrimSpace(text) == "" {
+			details["status_messages"] = "status messages need a status and a text"
+		}
+	}
+
+	if len(details) > 0 {
+		return errors.New(errors.ErrCodeValidation, "invalid linked object connector").WithDetails(details)
+	}
+
+	connector.Name = name
+	connector.Provider = provider
+	connector.ObjectType = objectType
+	connector.FieldMap = input.FieldMap
+	connector.StatusMessages = input.StatusMessages
+	if input.IsActive != nil {
+		connector.IsActive = *input.IsActive
+	}
+	return nil
+}
+
+// HandleWebhook verifies a connector webhook and creates or updates the object it
+// describes. Objects are matched by external ID; deliveries older than the stored
+// object are ignored. New objects are linked to the contact with the payload's email
+// or phone and to the contact's latest conversation.
+func (s *LinkedObjectService) HandleWebhook(ctx context.Context, connectorID string, body []byte, headers http.Header) (*entity.LinkedObject, error) {
+	connector, err := s.connectorRepo.FindByID(ctx, connectorID)
+	if err != nil || !connector.IsActive {
+		return nil, errors.NotFound("linked object connector")
+	}
+	if !verifyConnectorSignature(connector, body, headers) {
+		return nil, errors.Unauthorized("invalid webhook signature")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var payload interface{}
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, errors.New(errors.ErrCodeBadRequest, "invalid webhook payload")
+	}
+
+	fields, attributes := mapConnectorPayload(connector, payload)
+	externalID := fields["external_id"]
+	if externalID == "" {
+		return nil, errors.New(errors.ErrCodeValidation, "webhook payload has no external ID")
+	}
+	var updatedAt *time.Time
+	if parsed, err := time.Parse(time.RFC3339, fields["updated_at"]); err == nil {
+		updatedAt = &parsed
+	}
+
+	now := time.Now()
+	object, err := s.objectRepo.FindByExternalID(ctx, connector.ID, externalID)
+	isNew := errors.IsNotFound(err)
+	switch {
+	case isNew:
+		object = &entity.LinkedObject{
+			ID:          uuid.New().String(),
+			TenantID:    connector.TenantID,
+			Type:        connector.ObjectType,
+			Source:      string(connector.Provider),
+			ConnectorID: connector.ID,
+			ExternalID:  externalID,
+			CreatedAt:   now,
+		}
+	case err != nil:
+		return nil, err
+	case updatedAt != nil && object.ExternalUpdatedAt != nil && !updatedAt.After(*object.ExternalUpdatedAt):
+		return object, nil
+	}
+
+	previousStatus := object.Status
+	object.Reference = fields["reference"]
+	if object.Reference == "" {
+		object.Reference = externalID
+	}
+	object.Title = fields["title"]
+	object.Status = fields["status"]
+	object.URL = fields["url"]
+	object.Attributes = attributes
+	object.ExternalUpdatedAt = updatedAt
+	object.UpdatedAt = now
+	s.linkWebhookObject(ctx, object, fields)
+
+	if isNew {
+		err = s.objectRepo.Create(ctx, object)
+	} else {
+		err = s.objectRepo.Update(ctx, object)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	connector.LastSyncAt = &now
+	if err := s.connectorRepo.Update(ctx, connector); err != nil {
+		logger.Warn("Failed to record connector sync",
+			zap.String("connector_id", connector.ID),
+			zap.Error(err),
+		)
+	}
+
+	if isNew {
+		s.publish(ctx, EventLinkedObjectCreated, object, "")
+	} else {
+		s.publish(ctx, EventLinkedObjectUpdated, object, previousStatus)
+	}
+	if object.Status != previousStatus {
+		s.sendStatusMessage(ctx, connector, object)
+	}
+	return object, nil
+}
+
+// linkWebhookObject links a synced object to the conversation named in the payload or
+// to the contact matching its email or phone. Existing links are kept when the
+// payload names nobody.
+func (s *LinkedObjectService) linkWebhookObject(ctx context.Context, object *entity.LinkedObject, fields map[string]string) {
+	if id := fields["conversation_id"]; id != "" {
+		if conversation, err := s.conversationRepo.FindByID(ctx, id); err == nil && conversation.TenantID == object.TenantID {
+			object.ConversationID = conversation.ID
+			object.ContactID = conversation.ContactID
+			return
+		}
+	}
+
+	var contact *entity.Contact
+	if email := fields["contact_email"]; email != "" {
+		contact, _ = s.contactRepo.FindByEmail(ctx, object.TenantID, email)
+	}
+	if contact == nil && fields["contact_phone"] != "" {
+		contact, _ = s.contactRepo.FindByPhone(ctx, object.TenantID, fields["contact_phone"])
+	}
+	if contact == nil || contact.ID == object.ContactID {
+		return
+	}
+
+	object.ContactID = contact.ID
+	object.ConversationID = ""
+	params := &repository.ListParams{Page: 1, PageSize: 1}
+	if conversations, _, err := s.conversationRepo.FindByContact(ctx, contact.ID, params); err == nil && len(conversations) > 0 {
+		object.ConversationID = conversations[0].ID
+	}
+}
+
+// sendStatusMessage sends the connector's message for the object's new status to its conversation
+func (s *LinkedObjectService) sendStatusMessage(ctx context.Context, connector *entity.LinkedObjectConnector, object *entity.LinkedObject) {
+	text := connector.StatusMessages[object.Status]
+	if text == "" || object.ConversationID == "" || s.messageService == nil {
+		return
+	}
+
+	vars := map[string]string{}
+	if s.variables != nil {
+		if resolved, err := s.variables.ResolveVariables(ctx, object.ConversationID); err == nil {
+			vars = resolved
+		}
+	}
+	// The object that changed wins over newer objects of the same type
+	addLinkedObjectVariables(vars, object)
+	rendered, _ := RenderTemplate(text, vars)
+
+	_, err := s.messageService.Send(ctx, &SendMessageInput{
+		TenantID:       object.TenantID,
+		ConversationID: object.ConversationID,
+		SenderType:     string(entity.SenderTypeSystem),
+		ContentType:    string(entity.ContentTypeText),
+		Content:        rendered,
+		Metadata:       map[string]string{MessageMetaLinkedObjectID: object.ID},
+	})
+	if err != nil {
+		logger.Warn("Failed to send linked object status message",
+			zap.String("linked_object_id", object.ID),
+			zap.String("status", object.Status),
+			zap.Error(err),
+		)
+	}
+}
+
+func (s *LinkedObjectService) publish(ctx context.Context, eventType string, object *entity.LinkedObject, previousStatus string) {
+	if eventType == EventLinkedObjectUpdated && s.observer != nil {
+		changed := *object
+		go s.observer.LinkedObjectChanged(context.WithoutCancel(ctx), &changed)
+	}
+	if s.producer == nil {
+		return
+	}
+	payload := map[string]interface{}{
+		"linked_object_id": object.ID,
+		"type":             string(object.Type),
+		"source":           object.Source,
+		"reference":        object.Reference,
+		"status":           object.Status,
+		"conversation_id":  object.ConversationID,
+		"contact_id":       object.ContactID,
+	}
+	if previousStatus != "" && previousStatus != object.Status {
+		payload["previous_status"] = previousStatus
+	}
+	s.producer.PublishEvent(ctx, &nats.Event{
+		Type:      eventType,
+		TenantID:  object.TenantID,
+		Payload:   payload,
+		Timestamp: time.Now(),
+	})
+}
+
+// AddLinkedObjectVariables adds the latest object of each type to vars as
+// <type>.reference, <type>.title, <type>.status, <type>.url, <type>.external_id and
+// <type>.attributes.<key>, so bot messages can say "your order {{order.reference}} shipped".
+// objects must be ordered most recently updated first.
+func AddLinkedObjectVariables(vars map[string]string, objects []*entity.LinkedObject) {
+	seen := map[entity.LinkedObjectType]bool{}
+	for _, object := range objects {
+		if seen[object.Type] {
+			continue
+		}
+		seen[object.Type] = true
+		addLinkedObjectVariables(vars, object)
+	}
+}
+
+func addLinkedObjectVariables(vars map[string]string, object *entity.LinkedObject) {
+	prefix := string(object.Type) + "."
+	vars[prefix+"reference"] = object.Reference
+	vars[prefix+"title"] = object.Title
+	vars[prefix+"status"] = object.Status
+	vars[prefix+"url"] = object.URL
+	vars[prefix+"external_id"] = object.ExternalID
+	for key, value := range object.Attributes {
+		vars[prefix+"attributes."+key] = value
+	}
+}
+
+// mapConnectorPayload extracts object fields and attributes from a webhook payload with
+// the provider's field map overlaid by the connector's. Generic payloads may also carry
+// an "attributes" object of scalar values.
+func mapConnectorPayload(connector *entity.LinkedObjectConnector, payload interface{}) (map[string]string, map[string]string) {
+	fieldMap := make(map[string]string)
+	for field, path := range linkedObjectProviderFields[connector.Provider] {
+		fieldMap[field] = path
+	}
+	for field, path := range connector.FieldMap {
+		fieldMap[field] = path
+	}
+
+	fields := make(map[string]string)
+	attributes := make(map[string]string)
+	if connector.Provider == entity.LinkedObjectProviderGeneric {
+		if raw, ok := payload.(map[string]interface{}); ok {
+			if extra, ok := raw["attributes"].(map[string]interface{}); ok {
+				for key, value := range extra {
+					if text, ok := scalarString(value); ok {
+						attributes[key] = text
+					}
+				}
+			}
+		}
+	}
+
+	for field, path := range fieldMap {
+		value, ok := lookupPayloadPath(payload, path)
+		if !ok {
+			continue
+		}
+		if key, isAttribute := strings.CutPrefix(field, "attributes."); isAttribute {
+			if value != "" {
+				attributes[key] = value
+			}
+			continue
+		}
+		fields[field] = strings.TrimSpace(value)
+	}
+
+	if len(attributes) == 0 {
+		attributes = nil
+	}
+	return fields, attributes
+}
+
+// lookupPayloadPath resolves a dotted path such as "fulfillments.0.tracking_number" to a
+// scalar value. Numeric segments index arrays.
+func lookupPayloadPath(payload interface{}, path string) (string, bool) {
+	current := payload
+	for _, segment := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			current = node[segment]
+		case []interface{}:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(node) {
+				return "", false
+			}
+			current = node[index]
+		default:
+			return "", false
+		}
+	}
+	return scalarString(current)
+}
+
+func scalarString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+// verifyConnectorSignature checks the webhook signature with the provider's scheme
+func verifyConnectorSignature(connector *entity.LinkedObjectConnector, body []byte, headers http.Header) bool {
+	mac := hmac.New(sha256.New, []byte(connector.Secret))
+	switch connector.Provider {
+	case entity.LinkedObjectProviderShopify:
+		mac.Write(body)
+		expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+		return hmac.Equal([]byte(headers.Get(shopifySignatureHeader)), []byte(expected))
+	case entity.LinkedObjectProviderZendesk:
+		mac.Write([]byte(headers.Get(zendeskTimestampHeader)))
+		mac.Write(body)
+		expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+		return hmac.Equal([]byte(headers.Get(zendeskSignatureHeader)), []byte(expected))
+	default:
+		expected := signLinkedObjectWebhook(connector.Secret, body)
+		return hmac.Equal([]byte(headers.Get(LinkedObjectSignatureHeader)), []byte(expected))
+	}
+}
+
+func signLinkedObjectWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newConnectorSecret() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return strings.ReplaceAll(uuid.New().String()+uuid.New().String(), "-", "")
+	}
+	return hex.EncodeToString(b)
+}
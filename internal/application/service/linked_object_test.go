@@ -0,0 +1,350 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"sort"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockLinkedObjectRepo struct {
+	objects map[string]*entity.LinkedObject
+}
+
+func (m *mockLinkedObjectRepo) Create(ctx context.Context, object *entity.LinkedObject) error {
+	m.objects[object.ID] = object
+	return nil
+}
+
+func (m *mockLinkedObjectRepo) FindByID(ctx context.Context, id string) (*entity.LinkedObject, error) {
+	if object, ok := m.objects[id]; ok {
+		return object, nil
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "linked object not found")
+}
+
+func (m *mockLinkedObjectRepo) FindByExternalID(ctx context.Context, connectorID, externalID string) (*entity.LinkedObject, error) {
+	for _, object := range m.objects {
+		if object.ConnectorID == connectorID && object.ExternalID == externalID {
+			return object, nil
+		}
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "linked object not found")
+}
+
+func (m *mockLinkedObjectRepo) ListByTenant(ctx context.Context, tenantID string, filter *entity.LinkedObjectFilter, params *repository.ListParams) ([]*entity.LinkedObject, int64, error) {
+	var result []*entity.LinkedObject
+	for _, object := range m.objects {
+		if object.TenantID == tenantID {
+			result = append(result, object)
+		}
+	}
+	return result, int64(len(result)), nil
+}
+
+func (m *mockLinkedObjectRepo) ListByConversation(ctx context.Context, conversationID string) ([]*entity.LinkedObject, error) {
+	var result []*entity.LinkedObject
+	for _, object := range m.objects {
+		if object.ConversationID == conversationID {
+			result = append(result, object)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].UpdatedAt.After(result[j].UpdatedAt) })
+	return result, nil
+}
+
+func (m *mockLinkedObjectRepo) Update(ctx context.Context, object *entity.LinkedObject) error {
+	m.objects[object.ID] = object
+	return nil
+}
+
+func (m *mockLinkedObjectRepo) Delete(ctx context.Context, id string) error {
+	delete(m.objects, id)
+	return nil
+}
+
+type mockLinkedObjectConnectorRepo struct {
+	connectors map[string]*entity.LinkedObjectConnector
+}
+
+func (m *mockLinkedObjectConnectorRepo) Create(ctx context.Context, connector *entity.LinkedObjectConnector) error {
+	m.connectors[connector.ID] = connector
+	return nil
+}
+
+func (m *mockLinkedObjectConnectorRepo) FindByID(ctx context.Context, id string) (*entity.LinkedObjectConnector, error) {
+	if connector, ok := m.connectors[id]; ok {
+		return connector, nil
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "linked object connector not found")
+}
+
+func (m *mockLinkedObjectConnectorRepo) ListByTenant(ctx context.Context, tenantID string) ([]*entity.LinkedObjectConnector, error) {
+	var result []*entity.LinkedObjectConnector
+	for _, connector := range m.connectors {
+		if connector.TenantID == tenantID {
+			result = append(result, connector)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockLinkedObjectConnectorRepo) Update(ctx context.Context, connector *entity.LinkedObjectConnector) error {
+	m.connectors[connector.ID] = connector
+	return nil
+}
+
+func (m *mockLinkedObjectConnectorRepo) Delete(ctx context.Context, id string) error {
+	delete(m.connectors, id)
+	return nil
+}
+
+type linkedObjectFixture struct {
+	service   *LinkedObjectService
+	objects   *mockLinkedObjectRepo
+	messages  *testutil.MockMessageRepository
+	producer  *testutil.MockProducer
+	variables *ConversationVariablesService
+}
+
+func newLinkedObjectFixture() *linkedObjectFixture {
+	objects := &mockLinkedObjectRepo{objects: make(map[string]*entity.LinkedObject)}
+	connectors := &mockLinkedObjectConnectorRepo{connectors: make(map[string]*entity.LinkedObjectConnector)}
+	contacts := testutil.NewMockContactRepository()
+	conversations := testutil.NewMockConversationRepository()
+	messages := testutil.NewMockMessageRepository()
+	channels := testutil.NewMockChannelRepository()
+	producer := testutil.NewMockProducer()
+
+	channels.Channels["ch-1"] = &entity.Channel{ID: "ch-1", TenantID: "tenant-1", Type: entity.ChannelTypeTelegram}
+	contacts.Contacts["alice"] = &entity.Contact{ID: "alice", TenantID: "tenant-1", Name: "Alice Smith", Email: "alice@example.com"}
+	contacts.Contacts["bob"] = &entity.Contact{ID: "bob", TenantID: "tenant-1", Name: "Bob", Phone: "+5511999990000"}
+	conversations.Conversations["conv-1"] = &entity.Conversation{ID: "conv-1", TenantID: "tenant-1", ChannelID: "ch-1", ContactID: "alice"}
+	conversations.Conversations["conv-2"] = &entity.Conversation{ID: "conv-2", TenantID: "tenant-1", ChannelID: "ch-1", ContactID: "bob"}
+
+	messageService := NewMessageService(messages, conversations, channels, contacts, producer)
+	variables := NewConversationVariablesService(conversations, contacts, nil)
+	variables.SetLinkedObjectRepository(objects)
+
+	svc := NewLinkedObjectService(objects, connectors, conversations, contacts, messageService, producer)
+	svc.SetVariablesService(variables)
+
+	return &linkedObjectFixture{
+		service:   svc,
+		objects:   objects,
+		messages:  messages,
+		producer:  producer,
+		variables: variables,
+	}
+}
+
+func shopifySignature(secret string, body []byte) http.Header {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	headers := http.Header{}
+	headers.Set(shopifySignatureHeader, base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	return headers
+}
+
+func TestLinkedObjectService_CreateManual(t *testing.T) {
+	f := newLinkedObjectFixture()
+	ctx := context.Background()
+
+	object, err := f.service.Create(ctx, "tenant-1", &LinkedObjectInput{
+		Type:           "Ticket",
+		Reference:      "T-42",
+		Status:         "open",
+		ConversationID: "conv-1",
+		Attributes:     map[string]string{"priority": "high"},
+		CreatedBy:      "user-1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, entity.LinkedObjectTypeTicket, object.Type)
+	assert.Equal(t, entity.LinkedObjectSourceManual, object.Source)
+	assert.Equal(t, "alice", object.ContactID)
+	require.Len(t, f.producer.Events, 1)
+	assert.Equal(t, EventLinkedObjectCreated, f.producer.Events[0].Type)
+
+	panel, err := f.service.ListForConversation(ctx, "tenant-1", "conv-1")
+	require.NoError(t, err)
+	require.Len(t, panel, 1)
+	assert.Equal(t, object.ID, panel[0].ID)
+
+	_, err = f.service.ListForConversation(ctx, "tenant-2", "conv-1")
+	assert.Error(t, err)
+	_, err = f.service.Get(ctx, "tenant-2", object.ID)
+	assert.True(t, errors.IsNotFound(err))
+
+	_, err = f.service.Create(ctx, "tenant-1", &LinkedObjectInput{Type: "invoice", Reference: "1", ContactID: "alice"})
+	assert.Contains(t, errors.GetAppError(err).Details, "type")
+	_, err = f.service.Create(ctx, "tenant-1", &LinkedObjectInput{Type: "order", Reference: "1"})
+	assert.Contains(t, errors.GetAppError(err).Details, "conversation_id")
+	_, err = f.service.Create(ctx, "tenant-2", &LinkedObjectInput{Type: "order", Reference: "1", ContactID: "alice"})
+	assert.True(t, errors.IsNotFound(err))
+
+	updated, err := f.service.Update(ctx, "tenant-1", object.ID, &LinkedObjectInput{Reference: "T-42", Status: "solved", ContactID: "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, "solved", updated.Status)
+	assert.Empty(t, updated.ConversationID)
+	assert.Equal(t, "open", f.producer.Events[1].Payload["previous_status"])
+}
+
+func TestLinkedObjectService_ShopifyWebhook(t *testing.T) {
+	f := newLinkedObjectFixture()
+	ctx := context.Background()
+
+	created, err := f.service.CreateConnector(ctx, "tenant-1", &LinkedObjectConnectorInput{
+		Name:     "Store",
+		Provider: "shopify",
+		StatusMessages: map[string]string{
+			"fulfilled": "Hi {{contact.first_name}}, your order {{order.reference}} shipped: {{order.attributes.tracking_number}}",
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, entity.LinkedObjectTypeOrder, created.ObjectType)
+	require.NotEmpty(t, created.Secret)
+	connectorID := created.ID
+
+	body := []byte(`{"id": 5512345678901234, "name": "#1001", "email": "alice@example.com", "fulfillment_status": null,
+		"order_status_url": "https://shop.example.com/orders/1", "total_price": "59.90", "currency": "BRL",
+		"updated_at": "2026-03-01T10:00:00-03:00"}`)
+
+	_, err = f.service.HandleWebhook(ctx, connectorID, body, shopifySignature("wrong", body))
+	assert.Equal(t, errors.ErrCodeUnauthorized, errors.GetAppError(err).Code)
+
+	order, err := f.service.HandleWebhook(ctx, connectorID, body, shopifySignature(created.Secret, body))
+	require.NoError(t, err)
+	assert.Equal(t, "5512345678901234", order.ExternalID)
+	assert.Equal(t, "#1001", order.Reference)
+	assert.Equal(t, "shopify", order.Source)
+	assert.Equal(t, "alice", order.ContactID)
+	assert.Equal(t, "conv-1", order.ConversationID)
+	assert.Equal(t, map[string]string{"total": "59.90", "currency": "BRL"}, order.Attributes)
+	assert.Empty(t, f.messages.Messages)
+
+	// Fulfilment sends the connector's status message to the conversation
+	body = []byte(`{"id": 5512345678901234, "name": "#1001", "email": "alice@example.com", "fulfillment_status": "fulfilled",
+		"fulfillments": [{"tracking_number": "BR123"}], "updated_at": "2026-03-02T09:00:00-03:00"}`)
+	order, err = f.service.HandleWebhook(ctx, connectorID, body, shopifySignature(created.Secret, body))
+	require.NoError(t, err)
+	assert.Equal(t, "fulfilled", order.Status)
+	assert.Len(t, f.objects.objects, 1)
+
+	require.Len(t, f.messages.Messages, 1)
+	for _, message := range f.messages.Messages {
+		assert.Equal(t, "Hi Alice, your order #1001 shipped: BR123", message.Content)
+		assert.Equal(t, order.ID, message.Metadata[MessageMetaLinkedObjectID])
+	}
+
+	// Late deliveries of older updates are ignored
+	stale := []byte(`{"id": 5512345678901234, "name": "#1001", "fulfillment_status": null, "updated_at": "2026-03-01T12:00:00-03:00"}`)
+	order, err = f.service.HandleWebhook(ctx, connectorID, stale, shopifySignature(created.Secret, stale))
+	require.NoError(t, err)
+	assert.Equal(t, "fulfilled", order.Status)
+
+	connector, err := f.service.GetConnector(ctx, "tenant-1", connectorID)
+	require.NoError(t, err)
+	assert.NotNil(t, connector.LastSyncAt)
+
+	// Rotation invalidates the old secret
+	rotated, err := f.service.RotateConnectorSecret(ctx, "tenant-1", connectorID)
+	require.NoError(t, err)
+	assert.NotEqual(t, created.Secret, rotated.Secret)
+	_, err = f.service.HandleWebhook(ctx, connectorID, body, shopifySignature(created.Secret, body))
+	assert.Error(t, err)
+}
+
+func TestLinkedObjectService_GenericWebhookAndVariables(t *testing.T) {
+	f := newLinkedObjectFixture()
+	ctx := context.Background()
+
+	_, err := f.service.CreateConnector(ctx, "tenant-1", &LinkedObjectConnectorInput{
+		Name:     "Helpdesk",
+		Provider: "generic",
+		FieldMap: map[string]string{"unknown": "x"},
+	})
+	assert.Contains(t, errors.GetAppError(err).Details, "field_map.unknown")
+	_, err = f.service.CreateConnector(ctx, "tenant-1", &LinkedObjectConnectorInput{Name: "Helpdesk", Provider: "generic"})
+	assert.Contains(t, errors.GetAppError(err).Details, "object_type")
+
+	created, err := f.service.CreateConnector(ctx, "tenant-1", &LinkedObjectConnectorInput{
+		Name:       "Helpdesk",
+		Provider:   "generic",
+		ObjectType: "ticket",
+		FieldMap:   map[string]string{"reference": "number", "attributes.team": "assignee.team"},
+	})
+	require.NoError(t, err)
+
+	body := []byte(`{"id": "abc", "number": "HD-7", "status": "pending", "contact": {"phone": "+5511999990000"},
+		"assignee": {"team": "billing"}, "attributes": {"sla_breached": false}}`)
+	headers := http.Header{}
+	headers.Set(LinkedObjectSignatureHeader, signLinkedObjectWebhook(created.Secret, body))
+
+	ticket, err := f.service.HandleWebhook(ctx, created.ID, body, headers)
+	require.NoError(t, err)
+	assert.Equal(t, "HD-7", ticket.Reference)
+	assert.Equal(t, "bob", ticket.ContactID)
+	assert.Equal(t, "conv-2", ticket.ConversationID)
+	assert.Equal(t, map[string]string{"team": "billing", "sla_breached": "false"}, ticket.Attributes)
+
+	vars, err := f.variables.Resolve(ctx, "tenant-1", "conv-2")
+	require.NoError(t, err)
+	assert.Equal(t, "HD-7", vars["ticket.reference"])
+	assert.Equal(t, "pending", vars["ticket.status"])
+	assert.Equal(t, "billing", vars["ticket.attributes.team"])
+	_, hasOrder := vars["order.reference"]
+	assert.False(t, hasOrder)
+
+	_, err = f.service.HandleWebhook(ctx, created.ID, []byte(`{"number": "HD-8"}`), http.Header{
+		LinkedObjectSignatureHeader: {signLinkedObjectWebhook(created.Secret, []byte(`{"number": "HD-8"}`))},
+	})
+	assert.Equal(t, errors.ErrCodeValidation, errors.GetAppError(err).Code)
+
+	_, err = f.service.HandleWebhook(ctx, "missing", body, headers)
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestLinkedObjectService_ZendeskSignature(t *testing.T) {
+	connector := &entity.LinkedObjectConnector{Provider: entity.LinkedObjectProviderZendesk, Secret: "s3cret"}
+	body := []byte(`{"ticket":{"id":"9"}}`)
+
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte("2026-03-01T10:00:00Z"))
+	mac.Write(body)
+	headers := http.Header{}
+	headers.Set(zendeskTimestampHeader, "2026-03-01T10:00:00Z")
+	headers.Set(zendeskSignatureHeader, base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+
+	assert.True(t, verifyConnectorSignature(connector, body, headers))
+	headers.Set(zendeskTimestampHeader, "2026-03-01T10:00:01Z")
+	assert.False(t, verifyConnectorSignature(connector, body, headers))
+}
+
+func TestLookupPayloadPath(t *testing.T) {
+	payload := map[string]interface{}{
+		"ticket": map[string]interface{}{"id": 9.0, "tags": []interface{}{"vip", "late"}},
+	}
+
+	value, ok := lookupPayloadPath(payload, "ticket.id")
+	assert.True(t, ok)
+	assert.Equal(t, "9", value)
+
+	value, ok = lookupPayloadPath(payload, "ticket.tags.1")
+	assert.True(t, ok)
+	assert.Equal(t, "late", value)
+
+	_, ok = lookupPayloadPath(payload, "ticket.tags.5")
+	assert.False(t, ok)
+	_, ok = lookupPayloadPath(payload, "ticket")
+	assert.False(t, ok)
+}
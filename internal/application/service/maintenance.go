@@ -0,0 +1,304 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// MaintenanceMaxWebhookSize bounds the webhook bodies buffered during maintenance
+	MaintenanceMaxWebhookSize = 10 << 20
+
+	// maintenanceStateTTL is how long an instance trusts its copy of the maintenance
+	// state; other instances follow a change within this delay
+	maintenanceStateTTL  = 5 * time.Second
+	maintenanceDrainSize = 100
+)
+
+// MaintenanceInput represents a request to turn maintenance on
+type MaintenanceInput struct {
+	Reason    string `json:"reason,omitempty"`
+	StartedBy string `json:"started_by,omitempty"` // Who is operating, for the record
+}
+
+type maintenanceReplayKey struct{}
+
+// IsMaintenanceReplay reports whether a request is the replay of a buffered webhook,
+// which must be handled even while webhooks are still being buffered
+func IsMaintenanceReplay(ctx context.Context) bool {
+	replay, _ := ctx.Value(maintenanceReplayKey{}).(bool)
+	return replay
+}
+
+// MaintenanceService runs the system-wide maintenance mode. While it is on, provider
+// webhooks are accepted and buffered so providers do not deactivate them, and message
+// processing and outbound sends are paused. When it ends, the buffered webhooks are
+// replayed in the order they arrived, and new webhooks queue behind them until the
+// buffer is empty.
+type MaintenanceService struct {
+	repo     repository.MaintenanceRepository
+	replayer http.Handler
+	stateTTL time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	state    *entity.MaintenanceState
+	loadedAt time.Time
+	draining bool // Whether this instance is replaying the buffer
+}
+
+// NewMaintenanceService creates a new maintenance service
+func NewMaintenanceService(repo repository.MaintenanceRepository) *MaintenanceService {
+	return &MaintenanceService{
+		repo:     repo,
+		stateTTL: maintenanceStateTTL,
+		now:      time.Now,
+	}
+}
+
+// SetReplayer sets the HTTP handler buffered webhooks are replayed through, normally
+// the API router
+func (s *MaintenanceService) SetReplayer(replayer http.Handler) {
+	s.replayer = replayer
+}
+
+// Status returns the current maintenance state with the number of buffered webhooks
+func (s *MaintenanceService) Status(ctx context.Context) (*entity.MaintenanceState, error) {
+	state, err := s.repo.GetState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.remember(state)
+
+	if state.Buffered, err = s.repo.CountBuffered(ctx); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// Enable turns maintenance on. Turning it on while the buffer drains pauses the
+// replay again.
+func (s *MaintenanceService) Enable(ctx context.Context, input *MaintenanceInput) (*entity.MaintenanceState, error) {
+	state, err := s.repo.GetState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if state.Status == entity.MaintenanceStatusActive {
+		return nil, errors.Validation("maintenance is already on")
+	}
+
+	now := s.now()
+	state.Status = entity.MaintenanceStatusActive
+	state.Reason = strings.TrimSpace(input.Reason)
+	state.StartedBy = strings.TrimSpace(input.StartedBy)
+	state.StartedAt = &now
+	state.EndedAt = nil
+	state.UpdatedAt = now
+	if err := s.repo.SaveState(ctx, state); err != nil {
+		return nil, err
+	}
+	s.remember(state)
+
+	logger.Info("Maintenance mode on", zap.String("reason", state.Reason))
+	return s.Status(ctx)
+}
+
+// Disable ends maintenance and replays the buffered webhooks in the background.
+// Calling it again while draining restarts a replay that stopped, for example when
+// the instance running it went down.
+func (s *MaintenanceService) Disable(ctx context.Context) (*entity.MaintenanceState, error) {
+	if s.replayer == nil {
+		return nil, errors.New(errors.ErrCodeInternal, "buffered webhooks cannot be replayed")
+	}
+
+	state, err := s.repo.GetState(ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch state.Status {
+	case entity.MaintenanceStatusActive:
+		now := s.now()
+		state.Status = entity.MaintenanceStatusDraining
+		state.EndedAt = &now
+		state.UpdatedAt = now
+		if err := s.repo.SaveState(ctx, state); err != nil {
+			return nil, err
+		}
+		s.remember(state)
+		logger.Info("Maintenance mode ended, replaying buffered webhooks")
+	case entity.MaintenanceStatusDraining:
+	default:
+		return nil, errors.Validation("maintenance is off")
+	}
+
+	s.mu.Lock()
+	start := !s.draining
+	s.draining = true
+	s.mu.Unlock()
+	if start {
+		go s.drain(context.WithoutCancel(ctx))
+	}
+	return s.Status(ctx)
+}
+
+// Paused reports whether message processing and outbound sends are paused
+func (s *MaintenanceService) Paused() bool {
+	return s.current(context.Background()).Status == entity.MaintenanceStatusActive
+}
+
+// BuffersWebhooks reports whether an inbound webhook request must be buffered
+func (s *MaintenanceService) BuffersWebhooks(ctx context.Context) bool {
+	return !IsMaintenanceReplay(ctx) && s.current(ctx).BuffersWebhooks()
+}
+
+// BufferWebhook stores a webhook request to replay when maintenance ends
+func (s *MaintenanceService) BufferWebhook(ctx context.Context, webhook *entity.BufferedWebhook) error {
+	if len(webhook.Body) > MaintenanceMaxWebhookSize {
+		return errors.Validation("webhook body is too large to buffer")
+	}
+	webhook.ReceivedAt = s.now()
+	return s.repo.BufferWebhook(ctx, webhook)
+}
+
+// drain replays the buffer oldest first until it is empty, then turns maintenance
+// off. It stops when maintenance is turned on again.
+func (s *MaintenanceService) drain(ctx context.Context) {
+	defer func() {
+		s.mu.Lock()
+		s.draining = false
+		s.mu.Unlock()
+	}()
+
+	for {
+		state, err := s.repo.GetState(ctx)
+		if err != nil {
+			logger.Error("Failed to drain buffered webhooks", zap.Error(err))
+			return
+		}
+		if state.Status != entity.MaintenanceStatusDraining {
+			return
+		}
+
+		replayed, err := s.replayBatch(ctx)
+		if err != nil {
+			logger.Error("Failed to drain buffered webhooks", zap.Error(err))
+			return
+		}
+		if replayed > 0 {
+			continue
+		}
+
+		state.Status = entity.MaintenanceStatusOff
+		state.UpdatedAt = s.now()
+		if err := s.repo.SaveState(ctx, state); err != nil {
+			logger.Error("Failed to end maintenance mode", zap.Error(err))
+			return
+		}
+		s.remember(state)
+		logger.Info("Buffered webhooks replayed, maintenance mode off")
+		break
+	}
+
+	// Other instances buffer until they see the state change; replay what they kept
+	time.Sleep(s.stateTTL)
+	for {
+		replayed, err := s.replayBatch(ctx)
+		if err != nil {
+			logger.Error("Failed to replay late buffered webhooks", zap.Error(err))
+			return
+		}
+		if replayed == 0 {
+			return
+		}
+	}
+}
+
+// replayBatch replays and removes the oldest buffered webhooks, returning how many
+func (s *MaintenanceService) replayBatch(ctx context.Context) (int, error) {
+	webhooks, err := s.repo.ListBuffered(ctx, maintenanceDrainSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, webhook := range webhooks {
+		s.replay(ctx, webhook)
+		if err := s.repo.DeleteBuffered(ctx, webhook.ID); err != nil {
+			return 0, err
+		}
+	}
+	return len(webhooks), nil
+}
+
+// replay sends a buffered webhook through the API as it was received. Failures are
+// logged and the webhook is dropped, as the provider would have stopped retrying.
+func (s *MaintenanceService) replay(ctx context.Context, webhook *entity.BufferedWebhook) {
+	req, err := http.NewRequestWithContext(context.WithValue(ctx, maintenanceReplayKey{}, true), webhook.Method, webhook.URI, bytes.NewReader(webhook.Body))
+	if err != nil {
+		logger.Warn("Dropped invalid buffered webhook", zap.Int64("webhook_id", webhook.ID), zap.Error(err))
+		return
+	}
+	req.Host = webhook.Host
+	req.RequestURI = webhook.URI
+	req.Header = http.Header(webhook.Headers)
+	if req.Header == nil {
+		req.Header = http.Header{}
+	}
+
+	resp := &replayResponse{header: http.Header{}, status: http.StatusOK}
+	s.replayer.ServeHTTP(resp, req)
+	if resp.status >= http.StatusInternalServerError {
+		logger.Warn("Buffered webhook failed on replay",
+			zap.Int64("webhook_id", webhook.ID),
+			zap.String("uri", webhook.URI),
+			zap.Int("status", resp.status),
+		)
+	}
+}
+
+// current returns the maintenance state, read again once the cached copy is stale.
+// The cached state is kept when it cannot be read.
+func (s *MaintenanceService) current(ctx context.Context) *entity.MaintenanceState {
+	s.mu.Lock()
+	state, fresh := s.state, s.state != nil && s.now().Sub(s.loadedAt) < s.stateTTL
+	s.mu.Unlock()
+	if fresh {
+		return state
+	}
+
+	loaded, err := s.repo.GetState(ctx)
+	if err != nil {
+		logger.Warn("Failed to read maintenance state", zap.Error(err))
+		if state == nil {
+			return &entity.MaintenanceState{Status: entity.MaintenanceStatusOff}
+		}
+		return state
+	}
+	s.remember(loaded)
+	return loaded
+}
+
+func (s *MaintenanceService) remember(state *entity.MaintenanceState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = state
+	s.loadedAt = s.now()
+}
+
+// replayResponse records the status of a replayed webhook and discards its body
+type replayResponse struct {
+	header http.Header
+	status int
+}
+
+func (r *replayResponse) Header() http.Header         { return r.header }
+func (r *replayResponse) Write(b []byte) (int, error) { return len(b), nil }
+func (r *replayResponse) WriteHeader(status int)      { r.status = status }
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockMaintenanceRepo struct {
+	mu       sync.Mutex
+	state    entity.MaintenanceState
+	webhooks []*entity.BufferedWebhook
+	nextID   int64
+}
+
+func (m *mockMaintenanceRepo) GetState(ctx context.Context) (*entity.MaintenanceState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state := m.state
+	if state.Status == "" {
+		state.Status = entity.MaintenanceStatusOff
+	}
+	return &state, nil
+}
+
+func (m *mockMaintenanceRepo) SaveState(ctx context.Context, state *entity.MaintenanceState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.state = *state
+	return nil
+}
+
+func (m *mockMaintenanceRepo) BufferWebhook(ctx context.Context, webhook *entity.BufferedWebhook) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	webhook.ID = m.nextID
+	m.webhooks = append(m.webhooks, webhook)
+	return nil
+}
+
+func (m *mockMaintenanceRepo) ListBuffered(ctx context.Context, limit int) ([]*entity.BufferedWebhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.webhooks) < limit {
+		limit = len(m.webhooks)
+	}
+	return append([]*entity.BufferedWebhook(nil), m.webhooks[:limit]...), nil
+}
+
+func (m *mockMaintenanceRepo) DeleteBuffered(ctx context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, webhook := range m.webhooks {
+		if webhook.ID == id {
+			m.webhooks = append(m.webhooks[:i], m.webhooks[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (m *mockMaintenanceRepo) CountBuffered(ctx context.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return int64(len(m.webhooks)), nil
+}
+
+// recordingReplayer records the webhooks replayed through it
+type recordingReplayer struct {
+	mu       sync.Mutex
+	svc      *MaintenanceService
+	bodies   []string
+	buffered []bool // Whether the replay would have been buffered again
+}
+
+func (r *recordingReplayer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bodies = append(r.bodies, req.Method+" "+req.Host+req.URL.RequestURI()+" "+req.Header.Get("X-Hub-Signature-256")+" "+string(body))
+	r.buffered = append(r.buffered, r.svc.BuffersWebhooks(req.Context()))
+	w.WriteHeader(http.StatusOK)
+}
+
+func (r *recordingReplayer) replayed() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.bodies...)
+}
+
+func (r *recordingReplayer) rebuffered() []bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]bool(nil), r.buffered...)
+}
+
+func newMaintenanceTestService() (*MaintenanceService, *mockMaintenanceRepo, *recordingReplayer) {
+	repo := &mockMaintenanceRepo{}
+	svc := NewMaintenanceService(repo)
+	svc.stateTTL = 0
+	replayer := &recordingReplayer{svc: svc}
+	svc.SetReplayer(replayer)
+	return svc, repo, replayer
+}
+
+func bufferTestWebhook(t *testing.T, svc *MaintenanceService, body string) {
+	t.Helper()
+	require.NoError(t, svc.BufferWebhook(context.Background(), &entity.BufferedWebhook{
+		Method:  http.MethodPost,
+		Host:    "api.example.com",
+		URI:     "/api/v1/webhooks/whatsapp/ch-1?source=meta",
+		Headers: map[string][]string{"X-Hub-Signature-256": {"sha256=abc"}},
+		Body:    []byte(body),
+	}))
+}
+
+func TestMaintenanceService_EnableDisable(t *testing.T) {
+	svc, repo, replayer := newMaintenanceTestService()
+	ctx := context.Background()
+
+	assert.False(t, svc.Paused())
+	assert.False(t, svc.BuffersWebhooks(ctx))
+	_, err := svc.Disable(ctx)
+	assert.Error(t, err, "maintenance is off")
+
+	state, err := svc.Enable(ctx, &MaintenanceInput{Reason: " Database upgrade ", StartedBy: "ops"})
+	require.NoError(t, err)
+	assert.Equal(t, entity.MaintenanceStatusActive, state.Status)
+	assert.Equal(t, "Database upgrade", state.Reason)
+	require.NotNil(t, state.StartedAt)
+	assert.True(t, svc.Paused())
+	assert.True(t, svc.BuffersWebhooks(ctx))
+
+	_, err = svc.Enable(ctx, &MaintenanceInput{})
+	assert.Error(t, err, "maintenance is already on")
+
+	bufferTestWebhook(t, svc, `{"n":1}`)
+	bufferTestWebhook(t, svc, `{"n":2}`)
+	bufferTestWebhook(t, svc, `{"n":3}`)
+	state, err = svc.Status(ctx)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, state.Buffered)
+
+	state, err = svc.Disable(ctx)
+	require.NoError(t, err)
+	assert.NotEqual(t, entity.MaintenanceStatusActive, state.Status)
+	require.NotNil(t, state.EndedAt)
+	assert.False(t, svc.Paused(), "processing resumes while the buffer drains")
+
+	require.Eventually(t, func() bool {
+		state, _ := repo.GetState(ctx)
+		return state.Status == entity.MaintenanceStatusOff
+	}, 2*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, []string{
+		`POST api.example.com/api/v1/webhooks/whatsapp/ch-1?source=meta sha256=abc {"n":1}`,
+		`POST api.example.com/api/v1/webhooks/whatsapp/ch-1?source=meta sha256=abc {"n":2}`,
+		`POST api.example.com/api/v1/webhooks/whatsapp/ch-1?source=meta sha256=abc {"n":3}`,
+	}, replayer.replayed())
+	assert.Equal(t, []bool{false, false, false}, replayer.rebuffered(), "replays are handled, not buffered again")
+	count, err := repo.CountBuffered(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	assert.False(t, svc.BuffersWebhooks(ctx))
+}
+
+func TestMaintenanceService_DrainStopsWhenReEnabled(t *testing.T) {
+	svc, repo, replayer := newMaintenanceTestService()
+	ctx := context.Background()
+
+	_, err := svc.Enable(ctx, &MaintenanceInput{})
+	require.NoError(t, err)
+	bufferTestWebhook(t, svc, "first")
+
+	// A drain left over from before maintenance was turned back on replays nothing
+	svc.drain(ctx)
+	assert.Empty(t, replayer.replayed())
+	assert.Len(t, repo.webhooks, 1)
+}
+
+func TestMaintenanceService_BufferWebhookTooLarge(t *testing.T) {
+	svc, repo, _ := newMaintenanceTestService()
+
+	err := svc.BufferWebhook(context.Background(), &entity.BufferedWebhook{
+		Method: http.MethodPost,
+		URI:    "/api/v1/webhooks/telegram/ch-1",
+		Body:   make([]byte, MaintenanceMaxWebhookSize+1),
+	})
+	assert.Error(t, err)
+	assert.Empty(t, repo.webhooks)
+}
@@ -0,0 +1,332 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/msgfy/linktor/internal/adapters/telegram"
+	whatsappofficial "github.com/msgfy/linktor/internal/adapters/whatsapp_official"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/infrastructure/storage"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	mediaStoreTimeout    = 2 * time.Minute
+	mediaSignatureBytes  = 16
+	mediaStorageKeyField = "storage_key" // attachment metadata holding the storage key
+	mediaSourceURLField  = "source_url"  // attachment metadata holding the provider reference
+)
+
+// MediaURLTTL is how long a signed media URL works. Attachments are signed again
+// whenever their messages are read, so stored URLs going stale doesn't matter.
+const MediaURLTTL = time.Hour
+
+var unsafeMediaFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// preferredMediaExtensions are the usual extensions of types with several registered ones
+var preferredMediaExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"audio/mpeg": ".mp3",
+	"audio/ogg":  ".ogg",
+	"video/mp4":  ".mp4",
+}
+
+// MediaFetcher downloads the media of an inbound attachment from a channel's provider
+type MediaFetcher interface {
+	Fetch(ctx context.Context, channel *entity.Channel, attachment *entity.MessageAttachment) ([]byte, string, error)
+}
+
+// MediaService keeps a copy of the media customers send. Inbound attachments only
+// reference provider media IDs or URLs, which need the channel's credentials and
+// expire after a while, so their media is downloaded from the provider into object
+// storage and the attachment URL is rewritten to a signed Linktor URL that serves it.
+type MediaService struct {
+	store    storage.ObjectStorage
+	baseURL  string
+	secret   []byte
+	fetchers map[entity.ChannelType]MediaFetcher
+	fallback MediaFetcher
+	now      func() time.Time
+}
+
+// NewMediaService creates a new media service. baseURL is the public URL of Linktor
+// and secret signs the media URLs.
+func NewMediaService(store storage.ObjectStorage, baseURL, secret string) *MediaService {
+	httpClient := &http.Client{Timeout: defaultMaxDownloadTimeout}
+	return &MediaService{
+		store:   store,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		secret:  []byte(secret),
+		fetchers: map[entity.ChannelType]MediaFetcher{
+			entity.ChannelTypeWhatsAppOfficial: whatsAppMediaFetcher{},
+			entity.ChannelTypeTelegram:         telegramMediaFetcher{},
+			entity.ChannelTypeSMS:              urlMediaFetcher{client: httpClient, twilioAuth: true},
+		},
+		fallback: urlMediaFetcher{client: httpClient},
+		now:      time.Now,
+	}
+}
+
+// StoreInbound stores the media of an inbound message and points its attachments at
+// the stored copies. Attachments whose media cannot be fetched keep the provider
+// reference; failures are logged and never reject the message.
+func (s *MediaService) StoreInbound(ctx context.Context, channel *entity.Channel, message *entity.Message) {
+	if len(message.Attachments) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, mediaStoreTimeout)
+	defer cancel()
+
+	for _, attachment := range message.Attachments {
+		if err := s.storeAttachment(ctx, channel, message.ID, attachment); err != nil {
+			logger.Warn("Failed to store inbound media",
+				zap.String("channel_id", channel.ID),
+				zap.String("message_id", message.ID),
+				zap.String("attachment_type", attachment.Type),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+func (s *MediaService) storeAttachment(ctx context.Context, channel *entity.Channel, messageID string, attachment *entity.MessageAttachment) error {
+	if attachment.URL == "" || attachment.Metadata[mediaStorageKeyField] != "" {
+		return nil
+	}
+
+	fetcher, ok := s.fetchers[channel.Type]
+	if !ok {
+		if !isHTTPURL(attachment.URL) {
+			// Nothing to download, e.g. media a channel adapter already resolved
+			return nil
+		}
+		fetcher = s.fallback
+	}
+
+	data, contentType, err := fetcher.Fetch(ctx, channel, attachment)
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > maxDownloadSize {
+		return fmt.Errorf("media exceeds maximum size of %d bytes", maxDownloadSize)
+	}
+
+	mimeType := firstNonEmpty(attachment.MimeType, mediaType(contentType), http.DetectContentType(data))
+	key := GenerateKey(channel.ID, messageID, mediaFilename(attachment, mimeType))
+	if _, err := s.store.Upload(ctx, key, data, mimeType); err != nil {
+		return fmt.Errorf("failed to upload media: %w", err)
+	}
+
+	if attachment.Metadata == nil {
+		attachment.Metadata = make(map[string]string)
+	}
+	attachment.Metadata[mediaStorageKeyField] = key
+	attachment.Metadata[mediaSourceURLField] = attachment.URL
+	attachment.URL = s.SignedURL(key)
+	attachment.MimeType = mimeType
+	attachment.SizeBytes = int64(len(data))
+	return nil
+}
+
+// Store stores media Linktor received itself rather than from a channel's provider,
+// such as voicemail recordings, and points the attachment at the stored copy
+func (s *MediaService) Store(ctx context.Context, channelID, messageID string, attachment *entity.MessageAttachment, data []byte) error {
+	if int64(len(data)) > maxDownloadSize {
+		return fmt.Errorf("media exceeds maximum size of %d bytes", maxDownloadSize)
+	}
+
+	mimeType := firstNonEmpty(attachment.MimeType, http.DetectContentType(data))
+	key := GenerateKey(channelID, messageID, mediaFilename(attachment, mimeType))
+	if _, err := s.store.Upload(ctx, key, data, mimeType); err != nil {
+		return fmt.Errorf("failed to upload media: %w", err)
+	}
+
+	if attachment.Metadata == nil {
+		attachment.Metadata = make(map[string]string)
+	}
+	attachment.Metadata[mediaStorageKeyField] = key
+	attachment.URL = s.SignedURL(key)
+	attachment.MimeType = mimeType
+	attachment.SizeBytes = int64(len(data))
+	return nil
+}
+
+// SignedURL returns the Linktor URL serving a stored media object for MediaURLTTL
+func (s *MediaService) SignedURL(key string) string {
+	expires := strconv.FormatInt(s.now().Add(MediaURLTTL).Unix(), 10)
+	return fmt.Sprintf("%s/api/v1/media/%s?expires=%s&signature=%s", s.baseURL, key, expires, s.signature(key, expires))
+}
+
+// SignAttachments points the stored attachments of messages at freshly signed URLs
+func (s *MediaService) SignAttachments(messages []*entity.Message) {
+	for _, message := range messages {
+		for _, attachment := range message.Attachments {
+			if key := attachment.Metadata[mediaStorageKeyField]; key != "" {
+				attachment.URL = s.SignedURL(key)
+			}
+		}
+	}
+}
+
+// Open checks the signature and expiry of a media URL and reads the object it points at
+func (s *MediaService) Open(ctx context.Context, key, expires, signature string) ([]byte, string, error) {
+	key = strings.TrimPrefix(key, "/")
+	if key == "" || strings.Contains(key, "..") {
+		return nil, "", errors.NotFound("media")
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.signature(key, expires))) {
+		return nil, "", errors.New(errors.ErrCodeUnauthorized, "invalid media signature")
+	}
+	if expiresAt, err := strconv.ParseInt(expires, 10, 64); err != nil || s.now().Unix() > expiresAt {
+		return nil, "", errors.New(errors.ErrCodeUnauthorized, "media URL expired")
+	}
+
+	data, contentType, err := s.store.Get(ctx, key)
+	if err != nil {
+		if stderrors.Is(err, storage.ErrObjectNotFound) {
+			return nil, "", errors.NotFound("media")
+		}
+		return nil, "", errors.Wrap(err, errors.ErrCodeInternal, "failed to read media")
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	return data, contentType, nil
+}
+
+func (s *MediaService) signature(key, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(key + "\n" + expires))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:mediaSignatureBytes])
+}
+
+// mediaFilename returns a storage-safe file name for an attachment, deriving one from
+// the provider reference and MIME type when the channel sent none
+func mediaFilename(attachment *entity.MessageAttachment, mimeType string) string {
+	name := attachment.Filename
+	if name == "" {
+		name = firstNonEmpty(attachment.Metadata["media_id"], attachment.Metadata["file_id"], attachment.Type, "media")
+		if extension, ok := preferredMediaExtensions[mimeType]; ok {
+			name += extension
+		} else if extensions, _ := mime.ExtensionsByType(mimeType); len(extensions) > 0 {
+			name += extensions[0]
+		}
+	}
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.Trim(unsafeMediaFilenameChars.ReplaceAllString(name, "_"), "._")
+	if name == "" {
+		name = "media"
+	}
+	return name
+}
+
+// mediaType strips the parameters of a Content-Type header
+func mediaType(contentType string) string {
+	parsed, _, err := mime.ParseMediaType(contentType)
+	if err != nil || parsed == "application/octet-stream" {
+		return ""
+	}
+	return parsed
+}
+
+func isHTTPURL(value string) bool {
+	return strings.HasPrefix(value, "https://") || strings.HasPrefix(value, "http://")
+}
+
+// whatsAppMediaFetcher resolves WhatsApp Cloud API media IDs and downloads the media
+type whatsAppMediaFetcher struct{}
+
+func (whatsAppMediaFetcher) Fetch(ctx context.Context, channel *entity.Channel, attachment *entity.MessageAttachment) ([]byte, string, error) {
+	accessToken := channelSetting(channel, "access_token")
+	if accessToken == "" {
+		return nil, "", fmt.Errorf("access_token is required")
+	}
+	client := whatsappofficial.NewClient(&whatsappofficial.Config{
+		AccessToken:   accessToken,
+		PhoneNumberID: channelSetting(channel, "phone_number_id"),
+	})
+
+	info, err := client.GetMediaInfo(ctx, firstNonEmpty(attachment.Metadata["media_id"], attachment.URL))
+	if err != nil {
+		return nil, "", err
+	}
+	data, contentType, err := client.DownloadMedia(ctx, info.URL)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, firstNonEmpty(info.MimeType, contentType), nil
+}
+
+// telegramMediaFetcher downloads Telegram files by file ID
+type telegramMediaFetcher struct{}
+
+func (telegramMediaFetcher) Fetch(ctx context.Context, channel *entity.Channel, attachment *entity.MessageAttachment) ([]byte, string, error) {
+	token := channelSetting(channel, "bot_token")
+	if token == "" {
+		return nil, "", fmt.Errorf("bot_token is required")
+	}
+	client, err := telegram.NewClient(token)
+	if err != nil {
+		return nil, "", err
+	}
+	return client.DownloadFile(firstNonEmpty(attachment.Metadata["file_id"], attachment.URL))
+}
+
+// urlMediaFetcher downloads media from its URL. Twilio media URLs are requested with
+// the channel's account credentials, which Twilio requires when media is protected.
+type urlMediaFetcher struct {
+	client     *http.Client
+	twilioAuth bool
+}
+
+func (f urlMediaFetcher) Fetch(ctx context.Context, channel *entity.Channel, attachment *entity.MessageAttachment) ([]byte, string, error) {
+	if !isHTTPURL(attachment.URL) {
+		return nil, "", fmt.Errorf("attachment has no media URL")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, attachment.URL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", defaultUserAgent)
+	if f.twilioAuth {
+		if keySID := channelSetting(channel, "api_key_sid"); keySID != "" {
+			req.SetBasicAuth(keySID, channelSetting(channel, "api_key_secret"))
+		} else if accountSID := channelSetting(channel, "account_sid"); accountSID != "" {
+			req.SetBasicAuth(accountSID, channelSetting(channel, "auth_token"))
+		}
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download media: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("media download returned status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDownloadSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read media: %w", err)
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/infrastructure/storage"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMediaFetcher struct {
+	data []byte
+	err  error
+}
+
+func (f *fakeMediaFetcher) Fetch(ctx context.Context, channel *entity.Channel, attachment *entity.MessageAttachment) ([]byte, string, error) {
+	return f.data, "image/jpeg", f.err
+}
+
+func newMediaTestService(t *testing.T) (*MediaService, *fakeMediaFetcher) {
+	fetcher := &fakeMediaFetcher{data: []byte("jpeg-data")}
+	svc := NewMediaService(storage.NewLocalClient(t.TempDir(), ""), "https://linktor.example.com/", "secret")
+	svc.fetchers[entity.ChannelTypeWhatsAppOfficial] = fetcher
+	return svc, fetcher
+}
+
+// mediaKey returns the storage key, expiry and signature of a signed media URL
+func mediaKey(t *testing.T, signedURL string) (string, string, string) {
+	parsed, err := url.Parse(signedURL)
+	require.NoError(t, err)
+	return strings.TrimPrefix(parsed.Path, "/api/v1/media/"), parsed.Query().Get("expires"), parsed.Query().Get("signature")
+}
+
+func TestMediaService_StoreInbound(t *testing.T) {
+	svc, fetcher := newMediaTestService(t)
+	ctx := context.Background()
+	channel := &entity.Channel{ID: "channel-1", Type: entity.ChannelTypeWhatsAppOfficial}
+
+	stored := &entity.MessageAttachment{Type: "image", URL: "wamid-media-1", Metadata: map[string]string{"media_id": "wamid-media-1"}}
+	failed := &entity.MessageAttachment{Type: "image", URL: "wamid-media-2"}
+	message := &entity.Message{ID: "message-1", Attachments: []*entity.MessageAttachment{stored}}
+	svc.StoreInbound(ctx, channel, message)
+
+	assert.True(t, strings.HasPrefix(stored.URL, "https://linktor.example.com/api/v1/media/channel-1/message-1/wamid-media-1.jpg?expires="))
+	assert.Equal(t, "wamid-media-1", stored.Metadata["source_url"])
+	assert.Equal(t, "image/jpeg", stored.MimeType)
+	assert.Equal(t, int64(len("jpeg-data")), stored.SizeBytes)
+
+	key, expires, signature := mediaKey(t, stored.URL)
+	assert.Equal(t, key, stored.Metadata["storage_key"])
+	data, contentType, err := svc.Open(ctx, key, expires, signature)
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg-data", string(data))
+	assert.Equal(t, "image/jpeg", contentType)
+
+	// Stored attachments are not downloaded again
+	fetcher.err = fmt.Errorf("media expired")
+	svc.StoreInbound(ctx, channel, message)
+	assert.Equal(t, key, stored.Metadata["storage_key"])
+
+	// Attachments that cannot be fetched keep the provider reference
+	svc.StoreInbound(ctx, channel, &entity.Message{ID: "message-2", Attachments: []*entity.MessageAttachment{failed}})
+	assert.Equal(t, "wamid-media-2", failed.URL)
+	assert.Empty(t, failed.Metadata["storage_key"])
+}
+
+func TestMediaService_Open(t *testing.T) {
+	svc, _ := newMediaTestService(t)
+	ctx := context.Background()
+
+	_, err := svc.store.Upload(ctx, "channel-1/message-1/photo.png", []byte("png-data"), "image/png")
+	require.NoError(t, err)
+	key, expires, signature := mediaKey(t, svc.SignedURL("channel-1/message-1/photo.png"))
+
+	_, _, err = svc.Open(ctx, key, expires, signature)
+	require.NoError(t, err)
+
+	_, _, err = svc.Open(ctx, key, expires, "forged")
+	assert.Equal(t, errors.ErrCodeUnauthorized, errors.GetAppError(err).Code)
+
+	// The expiry is signed, so it can't be pushed back
+	later := strconv.FormatInt(time.Now().Add(24*time.Hour).Unix(), 10)
+	_, _, err = svc.Open(ctx, key, later, signature)
+	assert.Equal(t, errors.ErrCodeUnauthorized, errors.GetAppError(err).Code)
+
+	_, _, err = svc.Open(ctx, "channel-1/message-1/other.png", expires, svc.signature("channel-1/message-1/other.png", expires))
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestMediaService_Open_ExpiredURL(t *testing.T) {
+	svc, _ := newMediaTestService(t)
+	ctx := context.Background()
+	now := time.Now()
+	svc.now = func() time.Time { return now }
+
+	_, err := svc.store.Upload(ctx, "channel-1/message-1/photo.png", []byte("png-data"), "image/png")
+	require.NoError(t, err)
+	key, expires, signature := mediaKey(t, svc.SignedURL("channel-1/message-1/photo.png"))
+
+	now = now.Add(MediaURLTTL + time.Second)
+	_, _, err = svc.Open(ctx, key, expires, signature)
+	assert.Equal(t, errors.ErrCodeUnauthorized, errors.GetAppError(err).Code)
+
+	// Reading the message again signs a URL that works
+	message := &entity.Message{Attachments: []*entity.MessageAttachment{
+		{URL: "https://linktor.example.com/api/v1/media/stale", Metadata: map[string]string{"storage_key": key}},
+		{URL: "https://cdn.example.com/photo.png"},
+	}}
+	svc.SignAttachments([]*entity.Message{message})
+	assert.Equal(t, "https://cdn.example.com/photo.png", message.Attachments[1].URL)
+	key, expires, signature = mediaKey(t, message.Attachments[0].URL)
+	_, _, err = svc.Open(ctx, key, expires, signature)
+	assert.NoError(t, err)
+}
+
+func TestMediaService_StoreInbound_TwilioMedia(t *testing.T) {
+	var username, password string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ = r.BasicAuth()
+		w.Header().Set("Content-Type", "image/gif")
+		w.Write([]byte("gif-data"))
+	}))
+	defer server.Close()
+
+	svc := NewMediaService(storage.NewLocalClient(t.TempDir(), ""), "https://linktor.example.com", "secret")
+	channel := &entity.Channel{
+		ID:          "channel-1",
+		Type:        entity.ChannelTypeSMS,
+		Credentials: map[string]string{"account_sid": "AC123", "auth_token": "token"},
+	}
+	attachment := &entity.MessageAttachment{Type: "image", URL: server.URL + "/Media/ME123"}
+	svc.StoreInbound(context.Background(), channel, &entity.Message{ID: "message-1", Attachments: []*entity.MessageAttachment{attachment}})
+
+	assert.Equal(t, "AC123", username)
+	assert.Equal(t, "token", password)
+	assert.Equal(t, "image/gif", attachment.MimeType)
+	assert.Contains(t, attachment.URL, "/api/v1/media/channel-1/message-1/image.gif?expires=")
+}
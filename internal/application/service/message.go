@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,6 +14,7 @@ import (
 
 // SendMessageInput represents input for sending a message
 type SendMessageInput struct {
+	TenantID       string
 	ConversationID string
 	SenderID       string
 	SenderType     string
@@ -27,7 +29,15 @@ type MessageService struct {
 	conversationRepo repository.ConversationRepository
 	channelRepo      repository.ChannelRepository
 	contactRepo      repository.ContactRepository
+	conversations    *repository.TenantScope[entity.Conversation]
 	producer         nats.Publisher
+	phoneNumberSvc   *WhatsAppPhoneNumberService
+	qualityGuard     *WhatsAppQualityGuardService
+	duplicateGuard   *DuplicateSendGuard
+	identifiers      *IdentifierService
+	watchService     *WatchService
+	participants     *ConversationParticipantService
+	media            *MediaService
 }
 
 // NewMessageService creates a new message service
@@ -43,22 +53,68 @@ func NewMessageService(
 		conversationRepo: conversationRepo,
 		channelRepo:      channelRepo,
 		contactRepo:      contactRepo,
+		conversations:    repository.TenantConversations(conversationRepo, conversationNotFound),
 		producer:         producer,
 	}
 }
 
-// ListByConversation returns all messages for a conversation
-func (s *MessageService) ListByConversation(ctx context.Context, conversationID string, params *repository.ListParams) ([]*entity.Message, int64, error) {
+// SetPhoneNumberService enables sender selection for multi-number WhatsApp Official channels
+func (s *MessageService) SetPhoneNumberService(svc *WhatsAppPhoneNumberService) {
+	s.phoneNumberSvc = svc
+}
+
+// SetQualityGuard blocks marketing sends on channels paused after quality warnings
+func (s *MessageService) SetQualityGuard(guard *WhatsAppQualityGuardService) {
+	s.qualityGuard = guard
+}
+
+// SetDuplicateGuard blocks or flags identical sends to the same contact within the tenant's window
+func (s *MessageService) SetDuplicateGuard(guard *DuplicateSendGuard) {
+	s.duplicateGuard = guard
+}
+
+// SetIdentifierService rejects messages to malformed identifiers before they are stored
+func (s *MessageService) SetIdentifierService(identifiers *IdentifierService) {
+	s.identifiers = identifiers
+}
+
+// SetWatchService sets the service notifying watchers of agent replies
+func (s *MessageService) SetWatchService(watchService *WatchService) {
+	s.watchService = watchService
+}
+
+// SetParticipantService addresses email replies in group conversations to every participant
+func (s *MessageService) SetParticipantService(participants *ConversationParticipantService) {
+	s.participants = participants
+}
+
+// SetMediaService signs the URLs of stored attachments whenever messages are read
+func (s *MessageService) SetMediaService(media *MediaService) {
+	s.media = media
+}
+
+// ListByConversation returns all messages for a conversation of a tenant
+func (s *MessageService) ListByConversation(ctx context.Context, tenantID, conversationID string, params *repository.ListParams) ([]*entity.Message, int64, error) {
+	if _, err := s.conversations.FindByID(ctx, tenantID, conversationID); err != nil {
+		return nil, 0, err
+	}
 	if params == nil {
 		params = repository.NewListParams()
 		params.PageSize = 50
 		params.SortBy = "created_at"
 		params.SortDir = "desc"
 	}
-	return s.messageRepo.FindByConversation(ctx, conversationID, params)
+	messages, total, err := s.messageRepo.FindByConversation(ctx, conversationID, params)
+	if err != nil {
+		return nil, 0, err
+	}
+	if s.media != nil {
+		s.media.SignAttachments(messages)
+	}
+	return messages, total, nil
 }
 
-// Send sends a new message
+// Send sends a new message in a conversation of the input's tenant
 func (s *MessageService) Send(ctx context.Context, input *SendMessageInput) (*entity.Message, error) {
 	if input.ConversationID == "" {
 		return nil, errors.Validation("conversation_id is required")
@@ -68,9 +124,9 @@ func (s *MessageService) Send(ctx context.Context, input *SendMessageInput) (*en
 	}
 
 	// Get conversation
-	conversation, err := s.conversationRepo.FindByID(ctx, input.ConversationID)
+	conversation, err := s.conversations.FindByID(ctx, input.TenantID, input.ConversationID)
 	if err != nil {
-		return nil, errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+		return nil, err
 	}
 
 	// Get channel
@@ -91,6 +147,17 @@ func (s *MessageService) Send(ctx context.Context, input *SendMessageInput) (*en
 		contact.Identities = identities
 	}
 
+	// Group chats are addressed as a whole
+	recipientID := findRecipientForChannel(contact, string(channel.Type))
+	groupChat := conversation.IsGroup() && channel.Type != entity.ChannelTypeEmail
+	if groupChat {
+		recipientID = conversation.GroupID()
+	} else if s.identifiers != nil {
+		if recipientID, err = s.identifiers.NormalizeRecipient(ctx, channel, recipientID); err != nil {
+			return nil, err
+		}
+	}
+
 	now := time.Now()
 	message := &entity.Message{
 		ID:             uuid.New().String(),
@@ -108,6 +175,34 @@ func (s *MessageService) Send(ctx context.Context, input *SendMessageInput) (*en
 	if message.Metadata == nil {
 		message.Metadata = make(map[string]string)
 	}
+	// Messages in chats with disappearing messages on vanish from the phone too
+	message.SetEphemeralExpiry(conversation.DisappearingTimer(), now)
+
+	if s.qualityGuard != nil {
+		if err := s.qualityGuard.CheckOutbound(ctx, channel, message); err != nil {
+			return nil, err
+		}
+	}
+	if s.duplicateGuard != nil {
+		if err := s.duplicateGuard.CheckOutbound(ctx, channel, message); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.phoneNumberSvc.AssignSender(ctx, channel, conversation, message); err != nil {
+		return nil, err
+	}
+
+	// Group email replies copy the other participants unless the sender chose who to copy
+	if conversation.IsGroup() && !groupChat && s.participants != nil && message.Metadata["cc"] == "" {
+		cc, err := s.participants.GroupRecipients(ctx, conversation)
+		if err != nil {
+			return nil, err
+		}
+		if len(cc) > 0 {
+			message.Metadata["cc"] = strings.Join(cc, ",")
+		}
+	}
 
 	// Save message to database
 	if err := s.messageRepo.Create(ctx, message); err != nil {
@@ -116,7 +211,6 @@ func (s *MessageService) Send(ctx context.Context, input *SendMessageInput) (*en
 
 	// Publish to NATS for channel delivery (if producer is available)
 	if s.producer != nil {
-		recipientID := findRecipientForChannel(contact, string(channel.Type))
 		outbound := &nats.OutboundMessage{
 			ID:             message.ID,
 			TenantID:       conversation.TenantID,
@@ -143,6 +237,10 @@ func (s *MessageService) Send(ctx context.Context, input *SendMessageInput) (*en
 		s.conversationRepo.Update(ctx, conversation)
 	}
 
+	if s.watchService != nil && message.SenderType == entity.SenderTypeUser {
+		s.watchService.NotifyMessage(ctx, message, conversation)
+	}
+
 	return message, nil
 }
 
@@ -152,6 +250,9 @@ func (s *MessageService) GetByID(ctx context.Context, id string) (*entity.Messag
 	if err != nil {
 		return nil, errors.New(errors.ErrCodeMessageNotFound, "message not found")
 	}
+	if s.media != nil {
+		s.media.SignAttachments([]*entity.Message{message})
+	}
 	return message, nil
 }
 
@@ -169,19 +270,19 @@ func (s *MessageService) UpdateStatus(ctx context.Context, id string, status ent
 	return message, nil
 }
 
-// SendReaction sends a reaction to a message
+// SendReaction sends a reaction to a message in a conversation of a tenant
 // If emoji is empty, the reaction is removed
-func (s *MessageService) SendReaction(ctx context.Context, conversationID, messageID, emoji, senderID string) error {
-	// Get the original message to find external_id
-	message, err := s.messageRepo.FindByID(ctx, messageID)
+func (s *MessageService) SendReaction(ctx context.Context, tenantID, conversationID, messageID, emoji, senderID string) error {
+	// Get the conversation to find the channel
+	conversation, err := s.conversations.FindByID(ctx, tenantID, conversationID)
 	if err != nil {
-		return errors.New(errors.ErrCodeMessageNotFound, "message not found")
+		return err
 	}
 
-	// Get the conversation to find the channel
-	conversation, err := s.conversationRepo.FindByID(ctx, conversationID)
-	if err != nil {
-		return errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+	// Get the original message to find external_id
+	message, err := s.messageRepo.FindByID(ctx, messageID)
+	if err != nil || message.ConversationID != conversation.ID {
+		return errors.New(errors.ErrCodeMessageNotFound, "message not found")
 	}
 
 	// Publish reaction event to NATS for the adapter to send
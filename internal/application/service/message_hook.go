@@ -0,0 +1,440 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/egress"
+	"github.com/msgfy/linktor/internal/infrastructure/scripting"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Message hook limits
+const (
+	MaxHookScriptBytes  = 64 * 1024
+	MaxHookLookups      = 5
+	HookLookupTimeout   = 2 * time.Second
+	maxLookupBodyBytes  = 256 * 1024
+	maxHookReasonLength = 500
+)
+
+// MessageHookInput represents input for creating or updating a message hook
+type MessageHookInput struct {
+	Name        string              `json:"name" binding:"required"`
+	Description string              `json:"description"`
+	Language    entity.HookLanguage `json:"language" binding:"required"`
+	Script      string              `json:"script" binding:"required"`
+	Events      []entity.HookEvent  `json:"events" binding:"required"`
+	ChannelIDs  []string            `json:"channel_ids,omitempty"`
+	Lookups     []entity.HookLookup `json:"lookups,omitempty"`
+	TimeoutMs   int                 `json:"timeout_ms"`
+	MemoryMB    int                 `json:"memory_mb"`
+	Priority    int                 `json:"priority"`
+	IsActive    *bool               `json:"is_active,omitempty"`
+}
+
+// TestMessageHookInput represents a dry run of a hook script against a sample message
+type TestMessageHookInput struct {
+	Language  entity.HookLanguage `json:"language" binding:"required"`
+	Script    string              `json:"script" binding:"required"`
+	Event     entity.HookEvent    `json:"event"`
+	Lookups   []entity.HookLookup `json:"lookups,omitempty"`
+	TimeoutMs int                 `json:"timeout_ms"`
+	MemoryMB  int                 `json:"memory_mb"`
+	Message   *scripting.Message  `json:"message" binding:"required"`
+}
+
+// MessageHookService manages tenant scripts that run on inbound and outbound
+// messages. Hooks run in priority order, each one seeing the content and metadata
+// left by the previous one; the first hook that blocks stops the chain. A hook
+// that fails is skipped and its error recorded, so a broken script never stops
+// message delivery.
+type MessageHookService struct {
+	repo       repository.MessageHookRepository
+	runtimes   *scripting.Registry
+	httpClient *http.Client
+	checkURL   func(ctx context.Context, endpoint string) error
+}
+
+// NewMessageHookService creates a new message hook service
+func NewMessageHookService(repo repository.MessageHookRepository, runtimes *scripting.Registry) *MessageHookService {
+	httpClient := egress.NewClient()
+	httpClient.Timeout = HookLookupTimeout
+	return &MessageHookService{
+		repo:       repo,
+		runtimes:   runtimes,
+		httpClient: httpClient,
+		checkURL:   egress.CheckURL,
+	}
+}
+
+// Languages returns the hook languages available on this server
+func (s *MessageHookService) Languages() []string {
+	return s.runtimes.Languages()
+}
+
+// Create creates a new message hook
+func (s *MessageHookService) Create(ctx context.Context, tenantID string, input *MessageHookInput) (*entity.MessageHook, error) {
+	if err := s.validateInput(ctx, input); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	hook := &entity.MessageHook{
+		ID:          uuid.New().String(),
+		TenantID:    tenantID,
+		Name:        input.Name,
+		Description: input.Description,
+		Language:    input.Language,
+		Script:      input.Script,
+		Events:      input.Events,
+		ChannelIDs:  input.ChannelIDs,
+		Lookups:     input.Lookups,
+		TimeoutMs:   input.TimeoutMs,
+		MemoryMB:    input.MemoryMB,
+		Priority:    input.Priority,
+		IsActive:    input.IsActive == nil || *input.IsActive,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.repo.Create(ctx, hook); err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+// Get returns a message hook of a tenant
+func (s *MessageHookService) Get(ctx context.Context, tenantID, id string) (*entity.MessageHook, error) {
+	hook, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if hook.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeNotFound, "message hook not found")
+	}
+	return hook, nil
+}
+
+// List lists the message hooks of a tenant
+func (s *MessageHookService) List(ctx context.Context, tenantID string) ([]*entity.MessageHook, error) {
+	return s.repo.ListByTenant(ctx, tenantID)
+}
+
+// Update updates a message hook
+func (s *MessageHookService) Update(ctx context.Context, tenantID, id string, input *MessageHookInput) (*entity.MessageHook, error) {
+	if err := s.validateInput(ctx, input); err != nil {
+		return nil, err
+	}
+
+	hook, err := s.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	hook.Name = input.Name
+	hook.Description = input.Description
+	hook.Language = input.Language
+	hook.Script = input.Script
+	hook.Events = input.Events
+	hook.ChannelIDs = input.ChannelIDs
+	hook.Lookups = input.Lookups
+	hook.TimeoutMs = input.TimeoutMs
+	hook.MemoryMB = input.MemoryMB
+	hook.Priority = input.Priority
+	if input.IsActive != nil {
+		hook.IsActive = *input.IsActive
+	}
+	hook.UpdatedAt = time.Now()
+
+	if err := s.repo.Update(ctx, hook); err != nil {
+		return nil, err
+	}
+	return hook, nil
+}
+
+// Delete deletes a message hook
+func (s *MessageHookService) Delete(ctx context.Context, tenantID, id string) error {
+	if _, err := s.Get(ctx, tenantID, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// Test runs a script against a sample message without saving it. Script errors
+// are reported in the result rather than returned.
+func (s *MessageHookService) Test(ctx context.Context, input *TestMessageHookInput) (*entity.HookTestResult, error) {
+	if input.Event == "" {
+		input.Event = entity.HookEventInbound
+	}
+	hook := &entity.MessageHook{
+		Language:  input.Language,
+		Script:    input.Script,
+		Events:    []entity.HookEvent{input.Event},
+		Lookups:   input.Lookups,
+		TimeoutMs: input.TimeoutMs,
+		MemoryMB:  input.MemoryMB,
+	}
+	if err := s.validateHook(ctx, hook); err != nil {
+		return nil, err
+	}
+
+	message := *input.Message
+	if message.Metadata == nil {
+		message.Metadata = make(map[string]string)
+	}
+
+	started := time.Now()
+	lookups := s.fetchLookups(ctx, hook, &message)
+	result, err := s.run(ctx, hook, input.Event, &message, lookups)
+
+	testResult := &entity.HookTestResult{
+		Lookups:    lookups,
+		DurationMs: time.Since(started).Milliseconds(),
+	}
+	if err != nil {
+		testResult.Error = err.Error()
+		return testResult, nil
+	}
+	testResult.Content = result.Content
+	testResult.Metadata = result.Metadata
+	testResult.Blocked = result.Blocked
+	testResult.Reason = result.Reason
+	testResult.Logs = result.Logs
+	return testResult, nil
+}
+
+// ApplyHooks runs the active hooks of the channel's tenant for an event on a
+// message, updating its content and metadata in place
+func (s *MessageHookService) ApplyHooks(ctx context.Context, event entity.HookEvent, channel *entity.Channel, message *entity.Message) *entity.HookOutcome {
+	outcome := &entity.HookOutcome{}
+
+	hooks, err := s.repo.ListActive(ctx, channel.TenantID, event)
+	if err != nil {
+		logger.Warn("Failed to load message hooks", zap.String("tenant_id", channel.TenantID), zap.Error(err))
+		return outcome
+	}
+
+	for _, hook := range hooks {
+		if !hook.AppliesToChannel(channel.ID) {
+			continue
+		}
+
+		input := toScriptMessage(channel, message)
+		lookups := s.fetchLookups(ctx, hook, input)
+		result, err := s.run(ctx, hook, event, input, lookups)
+
+		lastError := ""
+		if err != nil {
+			lastError = err.Error()
+			logger.Warn("Message hook failed",
+				zap.String("hook_id", hook.ID),
+				zap.String("message_id", message.ID),
+				zap.Error(err),
+			)
+		}
+		if err := s.repo.RecordRun(ctx, hook.ID, time.Now(), lastError); err != nil {
+			logger.Warn("Failed to record message hook run", zap.String("hook_id", hook.ID), zap.Error(err))
+		}
+		if result == nil {
+			continue
+		}
+
+		message.Content = result.Content
+		message.Metadata = result.Metadata
+
+		if result.Blocked {
+			outcome.Blocked = true
+			outcome.Reason = result.Reason
+			if len(outcome.Reason) > maxHookReasonLength {
+				outcome.Reason = outcome.Reason[:maxHookReasonLength]
+			}
+			outcome.HookID = hook.ID
+			return outcome
+		}
+	}
+
+	return outcome
+}
+
+// run executes a hook script in its language runtime
+func (s *MessageHookService) run(ctx context.Context, hook *entity.MessageHook, event entity.HookEvent, message *scripting.Message, lookups map[string]interface{}) (*scripting.Result, error) {
+	runtime, err := s.runtimes.Get(string(hook.Language))
+	if err != nil {
+		return nil, err
+	}
+
+	return runtime.Run(ctx, hook.Script, &scripting.Input{
+		Event:   string(event),
+		Message: message,
+		Lookups: lookups,
+	}, scripting.Limits{
+		Timeout:  time.Duration(hook.TimeoutMs) * time.Millisecond,
+		MemoryMB: hook.MemoryMB,
+	})
+}
+
+// fetchLookups performs the external lookups of a hook. A failed lookup is
+// passed to the script as {"error": "..."} so the script can decide what to do.
+func (s *MessageHookService) fetchLookups(ctx context.Context, hook *entity.MessageHook, message *scripting.Message) map[string]interface{} {
+	lookups := make(map[string]interface{}, len(hook.Lookups))
+	if len(hook.Lookups) == 0 {
+		return lookups
+	}
+
+	vars := map[string]string{
+		"message_id":      message.ID,
+		"conversation_id": message.ConversationID,
+		"channel_id":      message.ChannelID,
+		"content":         message.Content,
+	}
+	for key, value := range message.Metadata {
+		vars["metadata."+key] = value
+	}
+	for key, value := range vars {
+		vars[key] = url.QueryEscape(value)
+	}
+
+	for _, lookup := range hook.Lookups {
+		target, _ := RenderTemplate(lookup.URL, vars)
+		value, err := s.fetchLookup(ctx, target, lookup.Headers)
+		if err != nil {
+			lookups[lookup.Name] = map[string]interface{}{"error": err.Error()}
+			continue
+		}
+		lookups[lookup.Name] = value
+	}
+	return lookups
+}
+
+// fetchLookup GETs a lookup URL and decodes its JSON body, falling back to the raw text.
+// The rendered URL is checked before each fetch, covering hooks saved before the check.
+func (s *MessageHookService) fetchLookup(ctx context.Context, target string, headers map[string]string) (interface{}, error) {
+	if err := s.checkURL(ctx, target); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("lookup returned status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxLookupBodyBytes))
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return string(body), nil
+	}
+	return value, nil
+}
+
+func (s *MessageHookService) validateInput(ctx context.Context, input *MessageHookInput) error {
+	if input.Name == "" {
+		return errors.Validation("name is required")
+	}
+	if len(input.Events) == 0 {
+		return errors.Validation("at least one event is required")
+	}
+	return s.validateHook(ctx, &entity.MessageHook{
+		Language:  input.Language,
+		Script:    input.Script,
+		Events:    input.Events,
+		Lookups:   input.Lookups,
+		TimeoutMs: input.TimeoutMs,
+		MemoryMB:  input.MemoryMB,
+	})
+}
+
+func (s *MessageHookService) validateHook(ctx context.Context, hook *entity.MessageHook) error {
+	if _, err := s.runtimes.Get(string(hook.Language)); err != nil {
+		return errors.Validation(fmt.Sprintf("language %q is not available", hook.Language))
+	}
+	if hook.Script == "" {
+		return errors.Validation("script is required")
+	}
+	if len(hook.Script) > MaxHookScriptBytes {
+		return errors.Validation(fmt.Sprintf("script must be at most %d bytes", MaxHookScriptBytes))
+	}
+	for _, event := range hook.Events {
+		if !event.IsValid() {
+			return errors.Validation(fmt.Sprintf("unknown event %q", event))
+		}
+	}
+	if hook.TimeoutMs < 0 || time.Duration(hook.TimeoutMs)*time.Millisecond > scripting.MaxTimeout {
+		return errors.Validation(fmt.Sprintf("timeout_ms must be between 0 and %d", scripting.MaxTimeout.Milliseconds()))
+	}
+	if hook.MemoryMB < 0 || hook.MemoryMB > scripting.MaxMemoryMB {
+		return errors.Validation(fmt.Sprintf("memory_mb must be between 0 and %d", scripting.MaxMemoryMB))
+	}
+	if len(hook.Lookups) > MaxHookLookups {
+		return errors.Validation(fmt.Sprintf("at most %d lookups are allowed", MaxHookLookups))
+	}
+
+	names := make(map[string]bool, len(hook.Lookups))
+	for _, lookup := range hook.Lookups {
+		if lookup.Name == "" || names[lookup.Name] {
+			return errors.Validation("lookup names must be unique and not empty")
+		}
+		names[lookup.Name] = true
+
+		parsed, err := url.Parse(lookup.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return errors.Validation(fmt.Sprintf("lookup %q must have an http or https URL", lookup.Name))
+		}
+		if err := s.checkURL(ctx, lookup.URL); err != nil {
+			return errors.Validation(fmt.Sprintf("lookup %q must not point to a private, loopback or link-local address", lookup.Name))
+		}
+	}
+	return nil
+}
+
+// toScriptMessage copies the fields of a message a script can read
+func toScriptMessage(channel *entity.Channel, message *entity.Message) *scripting.Message {
+	metadata := make(map[string]string, len(message.Metadata))
+	for key, value := range message.Metadata {
+		metadata[key] = value
+	}
+
+	direction := "inbound"
+	if message.SenderType != entity.SenderTypeContact {
+		direction = "outbound"
+	}
+
+	return &scripting.Message{
+		ID:             message.ID,
+		ConversationID: message.ConversationID,
+		ChannelID:      channel.ID,
+		ChannelType:    string(channel.Type),
+		Direction:      direction,
+		SenderType:     string(message.SenderType),
+		ContentType:    string(message.ContentType),
+		Content:        message.Content,
+		Metadata:       metadata,
+	}
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/infrastructure/scripting"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockMessageHookRepo struct {
+	hooks  map[string]*entity.MessageHook
+	errors map[string]string
+}
+
+func newMockMessageHookRepo() *mockMessageHookRepo {
+	return &mockMessageHookRepo{
+		hooks:  make(map[string]*entity.MessageHook),
+		errors: make(map[string]string),
+	}
+}
+
+func (m *mockMessageHookRepo) Create(ctx context.Context, hook *entity.MessageHook) error {
+	m.hooks[hook.ID] = hook
+	return nil
+}
+
+func (m *mockMessageHookRepo) FindByID(ctx context.Context, id string) (*entity.MessageHook, error) {
+	if hook, ok := m.hooks[id]; ok {
+		return hook, nil
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "message hook not found")
+}
+
+func (m *mockMessageHookRepo) ListByTenant(ctx context.Context, tenantID string) ([]*entity.MessageHook, error) {
+	var result []*entity.MessageHook
+	for _, hook := range m.hooks {
+		if hook.TenantID == tenantID {
+			result = append(result, hook)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Priority < result[j].Priority })
+	return result, nil
+}
+
+func (m *mockMessageHookRepo) ListActive(ctx context.Context, tenantID string, event entity.HookEvent) ([]*entity.MessageHook, error) {
+	hooks, _ := m.ListByTenant(ctx, tenantID)
+	var result []*entity.MessageHook
+	for _, hook := range hooks {
+		if hook.IsActive && hook.HandlesEvent(event) {
+			result = append(result, hook)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockMessageHookRepo) Update(ctx context.Context, hook *entity.MessageHook) error {
+	m.hooks[hook.ID] = hook
+	return nil
+}
+
+func (m *mockMessageHookRepo) Delete(ctx context.Context, id string) error {
+	delete(m.hooks, id)
+	return nil
+}
+
+func (m *mockMessageHookRepo) RecordRun(ctx context.Context, id string, runAt time.Time, lastError string) error {
+	m.errors[id] = lastError
+	return nil
+}
+
+// fakeRuntime interprets tiny scripts: "append:<text>", "tag:<key>=<lookup>",
+// "block:<reason>" and "fail"
+type fakeRuntime struct{}
+
+func (fakeRuntime) Language() string { return scripting.LanguageJavaScript }
+
+func (fakeRuntime) Run(ctx context.Context, script string, input *scripting.Input, limits scripting.Limits) (*scripting.Result, error) {
+	result := &scripting.Result{Content: input.Message.Content, Metadata: input.Message.Metadata}
+	command, arg, _ := strings.Cut(script, ":")
+	switch command {
+	case "fail":
+		return nil, &scripting.ScriptError{Message: "boom"}
+	case "append":
+		result.Content += arg
+	case "tag":
+		key, lookup, _ := strings.Cut(arg, "=")
+		result.Metadata[key] = fmt.Sprint(input.Lookups[lookup].(map[string]interface{})["tier"])
+	case "block":
+		result.Blocked = true
+		result.Reason = arg
+	}
+	return result, nil
+}
+
+func newTestMessageHookService() (*MessageHookService, *mockMessageHookRepo) {
+	repo := newMockMessageHookRepo()
+	svc := NewMessageHookService(repo, scripting.NewRegistry(fakeRuntime{}))
+	svc.httpClient, svc.checkURL = http.DefaultClient, allowAnyURL
+	return svc, repo
+}
+
+func createTestHook(t *testing.T, svc *MessageHookService, name, script string, priority int, channelIDs ...string) *entity.MessageHook {
+	hook, err := svc.Create(context.Background(), "tenant-1", &MessageHookInput{
+		Name:       name,
+		Language:   entity.HookLanguageJavaScript,
+		Script:     script,
+		Events:     []entity.HookEvent{entity.HookEventInbound},
+		ChannelIDs: channelIDs,
+		Priority:   priority,
+	})
+	require.NoError(t, err)
+	return hook
+}
+
+func TestMessageHookValidation(t *testing.T) {
+	svc, _ := newTestMessageHookService()
+	ctx := context.Background()
+
+	valid := func() *MessageHookInput {
+		return &MessageHookInput{
+			Name:     "hook",
+			Language: entity.HookLanguageJavaScript,
+			Script:   "append:!",
+			Events:   []entity.HookEvent{entity.HookEventInbound},
+		}
+	}
+
+	cases := map[string]func(*MessageHookInput){
+		"unavailable language": func(in *MessageHookInput) { in.Language = entity.HookLanguageLua },
+		"unknown event":        func(in *MessageHookInput) { in.Events = []entity.HookEvent{"message.deleted"} },
+		"no events":            func(in *MessageHookInput) { in.Events = nil },
+		"timeout too long":     func(in *MessageHookInput) { in.TimeoutMs = 60000 },
+		"memory too high":      func(in *MessageHookInput) { in.MemoryMB = 4096 },
+		"lookup scheme": func(in *MessageHookInput) {
+			in.Lookups = []entity.HookLookup{{Name: "crm", URL: "file:///etc/passwd"}}
+		},
+	}
+	for name, mutate := range cases {
+		input := valid()
+		mutate(input)
+		_, err := svc.Create(ctx, "tenant-1", input)
+		assert.Error(t, err, name)
+	}
+
+	_, err := svc.Create(ctx, "tenant-1", valid())
+	assert.NoError(t, err)
+}
+
+func TestApplyHooksRunsInPriorityOrderAndFiltersChannels(t *testing.T) {
+	svc, _ := newTestMessageHookService()
+	createTestHook(t, svc, "second", "append: second", 2)
+	createTestHook(t, svc, "first", "append: first", 1)
+	createTestHook(t, svc, "other channel", "append: other", 0, "ch-2")
+
+	channel := &entity.Channel{ID: "ch-1", TenantID: "tenant-1", Type: entity.ChannelTypeWebChat}
+	message := &entity.Message{Content: "hi", SenderType: entity.SenderTypeContact}
+
+	outcome := svc.ApplyHooks(context.Background(), entity.HookEventInbound, channel, message)
+	assert.False(t, outcome.Blocked)
+	assert.Equal(t, "hi first second", message.Content)
+
+	// Outbound messages do not run inbound hooks
+	message = &entity.Message{Content: "hi", SenderType: entity.SenderTypeUser}
+	svc.ApplyHooks(context.Background(), entity.HookEventOutbound, channel, message)
+	assert.Equal(t, "hi", message.Content)
+}
+
+func TestApplyHooksBlockStopsChain(t *testing.T) {
+	svc, _ := newTestMessageHookService()
+	blocker := createTestHook(t, svc, "blocker", "block:spam", 1)
+	createTestHook(t, svc, "later", "append: later", 2)
+
+	channel := &entity.Channel{ID: "ch-1", TenantID: "tenant-1"}
+	message := &entity.Message{Content: "buy now"}
+
+	outcome := svc.ApplyHooks(context.Background(), entity.HookEventInbound, channel, message)
+	assert.True(t, outcome.Blocked)
+	assert.Equal(t, "spam", outcome.Reason)
+	assert.Equal(t, blocker.ID, outcome.HookID)
+	assert.Equal(t, "buy now", message.Content)
+}
+
+func TestApplyHooksFailsOpen(t *testing.T) {
+	svc, repo := newTestMessageHookService()
+	broken := createTestHook(t, svc, "broken", "fail", 1)
+	working := createTestHook(t, svc, "working", "append: ok", 2)
+
+	channel := &entity.Channel{ID: "ch-1", TenantID: "tenant-1"}
+	message := &entity.Message{Content: "hi"}
+
+	outcome := svc.ApplyHooks(context.Background(), entity.HookEventInbound, channel, message)
+	assert.False(t, outcome.Blocked)
+	assert.Equal(t, "hi ok", message.Content)
+	assert.Contains(t, repo.errors[broken.ID], "boom")
+	assert.Empty(t, repo.errors[working.ID])
+}
+
+func TestApplyHooksFetchesLookups(t *testing.T) {
+	var requestedURL string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedURL = r.URL.String()
+		assert.Equal(t, "secret", r.Header.Get("X-Api-Key"))
+		w.Write([]byte(`{"tier":"gold"}`))
+	}))
+	defer server.Close()
+
+	svc, _ := newTestMessageHookService()
+	_, err := svc.Create(context.Background(), "tenant-1", &MessageHookInput{
+		Name:     "enrich",
+		Language: entity.HookLanguageJavaScript,
+		Script:   "tag:tier=crm",
+		Events:   []entity.HookEvent{entity.HookEventInbound},
+		Lookups: []entity.HookLookup{{
+			Name:    "crm",
+			URL:     server.URL + "/customers?phone={{metadata.phone}}",
+			Headers: map[string]string{"X-Api-Key": "secret"},
+		}},
+	})
+	require.NoError(t, err)
+
+	channel := &entity.Channel{ID: "ch-1", TenantID: "tenant-1"}
+	message := &entity.Message{Content: "hi", Metadata: map[string]string{"phone": "+55 11"}}
+
+	svc.ApplyHooks(context.Background(), entity.HookEventInbound, channel, message)
+	assert.Equal(t, "/customers?phone=%2B55+11", requestedURL)
+	assert.Equal(t, "gold", message.Metadata["tier"])
+	assert.Equal(t, "+55 11", message.Metadata["phone"])
+}
+
+func TestMessageHookTestReportsScriptErrors(t *testing.T) {
+	svc, _ := newTestMessageHookService()
+
+	result, err := svc.Test(context.Background(), &TestMessageHookInput{
+		Language: entity.HookLanguageJavaScript,
+		Script:   "fail",
+		Message:  &scripting.Message{Content: "hi"},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, result.Error, "boom")
+
+	result, err = svc.Test(context.Background(), &TestMessageHookInput{
+		Language: entity.HookLanguageJavaScript,
+		Script:   "block:nope",
+		Message:  &scripting.Message{Content: "hi"},
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Blocked)
+	assert.Equal(t, "nope", result.Reason)
+}
+
+func TestMessageHookLookupsRefuseInternalAddresses(t *testing.T) {
+	svc := NewMessageHookService(newMockMessageHookRepo(), scripting.NewRegistry(fakeRuntime{}))
+	ctx := context.Background()
+
+	_, err := svc.Create(ctx, "tenant-1", &MessageHookInput{
+		Name:     "enrich",
+		Language: entity.HookLanguageJavaScript,
+		Script:   "append:x",
+		Events:   []entity.HookEvent{entity.HookEventInbound},
+		Lookups:  []entity.HookLookup{{Name: "metadata", URL: "http://169.254.169.254/latest/meta-data/"}},
+	})
+	assert.True(t, errors.IsValidation(err))
+
+	// Hooks saved before the check are refused when their lookups are fetched
+	hook := &entity.MessageHook{Lookups: []entity.HookLookup{{Name: "crm", URL: "http://127.0.0.1:8080/customers?phone={{metadata.phone}}"}}}
+	lookups := svc.fetchLookups(ctx, hook, &scripting.Message{Content: "hi", Metadata: map[string]string{"phone": "+55 11"}})
+	lookup, ok := lookups["crm"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Contains(t, lookup["error"], "not allowed")
+}
@@ -82,6 +82,7 @@ func (n *MessageNormalizer) ToEntity(msg *NormalizedMessage) *entity.Message {
 	if message.Metadata == nil {
 		message.Metadata = make(map[string]string)
 	}
+	message.SetContextCards()
 
 	return message
 }
@@ -0,0 +1,391 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// maxSuggestedTemplates caps the approved templates suggested for a message that
+// failed outside the customer service window
+const maxSuggestedTemplates = 3
+
+// messageErrorEntry is how a provider error code maps to the normalized taxonomy
+type messageErrorEntry struct {
+	category  entity.MessageErrorCategory
+	retryable bool
+	hint      string // Overrides the category hint
+}
+
+// metaErrorCatalog maps WhatsApp Cloud API and Graph API error codes
+var metaErrorCatalog = map[string]messageErrorEntry{
+	"131047": {category: entity.MessageErrorReengagementRequired},
+	"131050": {category: entity.MessageErrorMarketingOptedOut},
+	"131049": {category: entity.MessageErrorRateLimited, retryable: true, hint: "Meta limited the marketing messages this user receives. Try again in a few days, or send a utility message instead."},
+	"131026": {category: entity.MessageErrorInvalidRecipient, hint: "The number is not on WhatsApp or cannot receive messages from this business, for example because of an outdated app or unaccepted terms."},
+	"131021": {category: entity.MessageErrorInvalidRecipient, hint: "The recipient is the sender's own number."},
+	"131048": {category: entity.MessageErrorRateLimited, retryable: true, hint: "The number is limited because too many of its messages were reported as spam. Improve content quality and slow down sends."},
+	"131056": {category: entity.MessageErrorRateLimited, retryable: true, hint: "Too many messages were sent to this recipient in a short time. Wait before sending again."},
+	"130429": {category: entity.MessageErrorRateLimited, retryable: true},
+	"80007":  {category: entity.MessageErrorRateLimited, retryable: true},
+	"132000": {category: entity.MessageErrorTemplateError, hint: "The number of template parameters does not match the template. Check the variables sent."},
+	"132001": {category: entity.MessageErrorTemplateError, hint: "The template does not exist in this language or is not approved yet."},
+	"132005": {category: entity.MessageErrorTemplateError, hint: "The template text is too long once the parameters are filled in."},
+	"132007": {category: entity.MessageErrorTemplateError, hint: "The template content breaks a WhatsApp policy. Edit it and submit it again."},
+	"132012": {category: entity.MessageErrorTemplateError, hint: "A template parameter has the wrong format, such as media in a text parameter."},
+	"132015": {category: entity.MessageErrorTemplateError, hint: "The template is paused because of low quality. Improve it or use another template."},
+	"132016": {category: entity.MessageErrorTemplateError, hint: "The template was disabled after being paused too often. Create a new template."},
+	"131052": {category: entity.MessageErrorMediaError, retryable: true},
+	"131053": {category: entity.MessageErrorMediaError},
+	"131042": {category: entity.MessageErrorPaymentRequired},
+	"131031": {category: entity.MessageErrorChannelConfig, hint: "The WhatsApp Business account is restricted or locked for a policy violation. Check its status in Business Manager."},
+	"368":    {category: entity.MessageErrorChannelConfig, hint: "The account is temporarily blocked for a policy violation. Check its status in Business Manager."},
+	"190":    {category: entity.MessageErrorChannelAuth},
+	"10":     {category: entity.MessageErrorChannelAuth, hint: "The access token lacks the permission to send messages. Reconnect the channel granting whatsapp_business_messaging."},
+	"131000": {category: entity.MessageErrorTemporary, retryable: true},
+	"131016": {category: entity.MessageErrorTemporary, retryable: true},
+	"133004": {category: entity.MessageErrorTemporary, retryable: true},
+}
+
+// twilioErrorCatalog maps Twilio messaging error codes
+var twilioErrorCatalog = map[string]messageErrorEntry{
+	"21610": {category: entity.MessageErrorRecipientOptedOut, hint: "The recipient replied STOP to this number. They were marked as opted out and can opt back in by replying START."},
+	"21211": {category: entity.MessageErrorInvalidRecipient},
+	"21614": {category: entity.MessageErrorInvalidRecipient, hint: "The number is not a mobile number and cannot receive SMS."},
+	"30005": {category: entity.MessageErrorInvalidRecipient},
+	"30006": {category: entity.MessageErrorInvalidRecipient, hint: "The number is a landline or its carrier cannot receive SMS."},
+	"30003": {category: entity.MessageErrorRecipientUnreachable, retryable: true},
+	"30004": {category: entity.MessageErrorContentFiltered, hint: "The message was blocked by the recipient or their carrier."},
+	"30007": {category: entity.MessageErrorContentFiltered},
+	"30034": {category: entity.MessageErrorChannelConfig, hint: "The sender number is not registered for A2P 10DLC. Complete the registration in the Twilio console."},
+	"21408": {category: entity.MessageErrorChannelConfig, hint: "Sending to this country is not enabled. Enable it in the Twilio geo permissions."},
+	"21606": {category: entity.MessageErrorChannelConfig, hint: "The sender number cannot send SMS. Check its capabilities in the Twilio console."},
+	"20003": {category: entity.MessageErrorChannelAuth},
+	"20429": {category: entity.MessageErrorRateLimited, retryable: true},
+	"30001": {category: entity.MessageErrorRateLimited, retryable: true, hint: "The message queue overflowed. Slow down sends and retry."},
+	"30008": {category: entity.MessageErrorTemporary, retryable: true},
+}
+
+// messageErrorHints are the remediation hints of each category
+var messageErrorHints = map[entity.MessageErrorCategory]string{
+	entity.MessageErrorReengagementRequired: "The customer has not replied in the last 24 hours. Send an approved template to reopen the conversation.",
+	entity.MessageErrorRecipientOptedOut:    "The recipient opted out of messages from this sender. They were marked as opted out; do not message them until they opt back in.",
+	entity.MessageErrorMarketingOptedOut:    "The recipient stopped marketing messages from this business. Only send them utility or service messages.",
+	entity.MessageErrorInvalidRecipient:     "The address or number does not exist or cannot receive messages on this channel. Check the contact's details.",
+	entity.MessageErrorRecipientUnreachable: "The recipient could not be reached. Retry later or use another channel.",
+	entity.MessageErrorMailboxFull:          "The recipient's mailbox is full. Retry later.",
+	entity.MessageErrorContentFiltered:      "The message was blocked as spam or by a content policy. Review the content, links and sender reputation.",
+	entity.MessageErrorRateLimited:          "Too many messages were sent in a short time. Slow down and retry later.",
+	entity.MessageErrorTemplateError:        "The template or its parameters were rejected. Check that the template is approved and the parameters match it.",
+	entity.MessageErrorMediaError:           "The media could not be downloaded or is not supported. Check the file URL, type and size.",
+	entity.MessageErrorChannelAuth:          "The channel credentials are invalid or expired. Reconnect the channel.",
+	entity.MessageErrorChannelConfig:        "The channel is not allowed to send this message. Check the sender's registration and permissions with the provider.",
+	entity.MessageErrorPaymentRequired:      "The provider account has a payment issue. Update the payment method with the provider.",
+	entity.MessageErrorTemporary:            "The provider reported a temporary failure. Retry later.",
+	entity.MessageErrorUnknown:              "The provider reported an error that is not in the catalog. Check the error message for details.",
+}
+
+var (
+	bracketCodePattern  = regexp.MustCompile(`^\[(\d+)\]`)
+	enhancedSMTPPattern = regexp.MustCompile(`\b([245]\.\d{1,3}\.\d{1,3})\b`)
+	basicSMTPPattern    = regexp.MustCompile(`\b([245]\d\d)\b`)
+)
+
+// ClassifyMessageError maps a provider error of a failed message to the normalized
+// taxonomy with a remediation hint. The code is taken from the error message when the
+// provider did not report it separately.
+func ClassifyMessageError(channelType, code, message string) *entity.MessageError {
+	provider := messageErrorProvider(channelType)
+	code = strings.TrimSpace(code)
+
+	var entry messageErrorEntry
+	switch provider {
+	case "meta":
+		if code == "" {
+			if match := bracketCodePattern.FindStringSubmatch(message); match != nil {
+				code = match[1]
+			}
+		}
+		entry = lookupMessageError(metaErrorCatalog, code)
+	case "twilio":
+		entry = lookupMessageError(twilioErrorCatalog, code)
+	case "smtp":
+		code, entry = classifySMTPError(code, message)
+	default:
+		entry = messageErrorEntry{category: entity.MessageErrorUnknown}
+	}
+
+	hint := entry.hint
+	if hint == "" {
+		hint = messageErrorHints[entry.category]
+	}
+	return &entity.MessageError{
+		Provider:  provider,
+		Code:      code,
+		Category:  entry.category,
+		Retryable: entry.retryable,
+		Hint:      hint,
+	}
+}
+
+// messageErrorProvider returns the provider whose error codes a channel type reports
+func messageErrorProvider(channelType string) string {
+	switch entity.ChannelType(channelType) {
+	case entity.ChannelTypeWhatsAppOfficial, entity.ChannelTypeFacebook, entity.ChannelTypeInstagram:
+		return "meta"
+	case entity.ChannelTypeSMS:
+		return "twilio"
+	case entity.ChannelTypeEmail:
+		return "smtp"
+	}
+	return channelType
+}
+
+func lookupMessageError(catalog map[string]messageErrorEntry, code string) messageErrorEntry {
+	if entry, ok := catalog[code]; ok {
+		return entry
+	}
+	return messageErrorEntry{category: entity.MessageErrorUnknown}
+}
+
+// emailHardBounce is a permanent rejection of the recipient's address. The contact is
+// suppressed for email since mailing dead addresses hurts the sender reputation.
+var emailHardBounce = messageErrorEntry{
+	category: entity.MessageErrorInvalidRecipient,
+	hint:     "The address does not exist or no longer accepts mail. The contact was suppressed for email to protect the sender reputation; correct the address before emailing them again.",
+}
+
+// classifySMTPError classifies an email bounce by its enhanced status code (5.1.1),
+// falling back to the basic reply code (550)
+func classifySMTPError(code, message string) (string, messageErrorEntry) {
+	if code == "complaint" {
+		return code, messageErrorEntry{
+			category: entity.MessageErrorRecipientOptedOut,
+			hint:     "The recipient reported the email as spam. They were marked as opted out of email.",
+		}
+	}
+
+	text := code + " " + message
+	if match := enhancedSMTPPattern.FindStringSubmatch(text); match != nil {
+		enhanced := match[1]
+		switch {
+		case enhanced == "5.2.2" || enhanced == "4.2.2":
+			return enhanced, messageErrorEntry{category: entity.MessageErrorMailboxFull, retryable: true}
+		case strings.HasPrefix(enhanced, "5.1.") || enhanced == "5.2.1":
+			return enhanced, emailHardBounce
+		case strings.HasPrefix(enhanced, "5.7."):
+			return enhanced, messageErrorEntry{category: entity.MessageErrorContentFiltered}
+		case strings.HasPrefix(enhanced, "4."):
+			return enhanced, messageErrorEntry{category: entity.MessageErrorTemporary, retryable: true}
+		}
+	}
+
+	if match := basicSMTPPattern.FindStringSubmatch(text); match != nil {
+		basic := match[1]
+		switch {
+		case basic == "552" || basic == "452":
+			return basic, messageErrorEntry{category: entity.MessageErrorMailboxFull, retryable: true}
+		case basic == "550" || basic == "551" || basic == "553":
+			return basic, emailHardBounce
+		case basic == "554":
+			return basic, messageErrorEntry{category: entity.MessageErrorContentFiltered}
+		case strings.HasPrefix(basic, "4"):
+			return basic, messageErrorEntry{category: entity.MessageErrorTemporary, retryable: true}
+		}
+	}
+	return code, messageErrorEntry{category: entity.MessageErrorUnknown}
+}
+
+// MessageStatusService applies provider status updates to messages. Failures are
+// classified with ClassifyMessageError and stored on the message; opt-outs and email
+// hard bounces mark the contact as opted out of the channel type, and messages sent
+// outside the customer service window get approved templates suggested.
+type MessageStatusService struct {
+	messageRepo      repository.MessageRepository
+	conversationRepo repository.ConversationRepository
+	contactRepo      repository.ContactRepository
+	templateRepo     repository.TemplateRepository
+	participants     *ConversationParticipantService
+}
+
+// NewMessageStatusService creates a new message status service
+func NewMessageStatusService(
+	messageRepo repository.MessageRepository,
+	conversationRepo repository.ConversationRepository,
+	contactRepo repository.ContactRepository,
+) *MessageStatusService {
+	return &MessageStatusService{
+		messageRepo:      messageRepo,
+		conversationRepo: conversationRepo,
+		contactRepo:      contactRepo,
+	}
+}
+
+// SetTemplateRepository enables template suggestions for messages that failed
+// outside the customer service window
+func (s *MessageStatusService) SetTemplateRepository(templateRepo repository.TemplateRepository) {
+	s.templateRepo = templateRepo
+}
+
+// SetParticipantService records the statuses of group messages per participant
+func (s *MessageStatusService) SetParticipantService(participants *ConversationParticipantService) {
+	s.participants = participants
+}
+
+// HandleStatusUpdate updates the status of the message a provider reported on,
+// found by its external ID when the update has no message ID
+func (s *MessageStatusService) HandleStatusUpdate(ctx context.Context, update *nats.StatusUpdate) error {
+	messageID := update.MessageID
+	if messageID == "" && update.ExternalID != "" {
+		message, err := s.messageRepo.FindByExternalID(ctx, update.ExternalID)
+		if err != nil {
+			return err
+		}
+		messageID = message.ID
+	}
+
+	status := toMessageStatus(update.Status)
+	if update.Recipient != "" && s.participants != nil {
+		return s.handleRecipientStatus(ctx, messageID, update.Recipient, status, update)
+	}
+	if err := s.messageRepo.UpdateStatus(ctx, messageID, status, update.ErrorMessage); err != nil {
+		return err
+	}
+	if status != entity.MessageStatusFailed {
+		return nil
+	}
+
+	message, err := s.messageRepo.FindByID(ctx, messageID)
+	if err != nil {
+		return err
+	}
+	message.ErrorDetails = ClassifyMessageError(update.ChannelType, update.ErrorCode, update.ErrorMessage)
+	s.applyErrorActions(ctx, message, update.ChannelType)
+	return s.messageRepo.Update(ctx, message)
+}
+
+// handleRecipientStatus records the status of a group message for one participant.
+// The message itself moves forward with the furthest participant, and one participant
+// failing doesn't fail it for the others.
+func (s *MessageStatusService) handleRecipientStatus(ctx context.Context, messageID, recipient string, status entity.MessageStatus, update *nats.StatusUpdate) error {
+	if err := s.participants.RecordDelivery(ctx, messageID, recipient, status, update.ErrorMessage, update.Timestamp); err != nil {
+		return err
+	}
+	if status == entity.MessageStatusFailed {
+		return nil
+	}
+
+	message, err := s.messageRepo.FindByID(ctx, messageID)
+	if err != nil {
+		return err
+	}
+	if !entity.IsStatusProgress(message.Status, status) {
+		return nil
+	}
+	return s.messageRepo.UpdateStatus(ctx, messageID, status, "")
+}
+
+// applyErrorActions takes the automatic actions of a message failure and records
+// them on its error details
+func (s *MessageStatusService) applyErrorActions(ctx context.Context, message *entity.Message, channelType string) {
+	details := message.ErrorDetails
+	hardBounce := details.Provider == "smtp" && details.Category == entity.MessageErrorInvalidRecipient
+	if details.Category != entity.MessageErrorRecipientOptedOut && details.Category != entity.MessageErrorReengagementRequired && !hardBounce {
+		return
+	}
+
+	conversation, err := s.conversationRepo.FindByID(ctx, message.ConversationID)
+	if err != nil {
+		return
+	}
+
+	switch {
+	case details.Category == entity.MessageErrorRecipientOptedOut:
+		if s.optOut(ctx, conversation.ContactID, channelType) {
+			details.Actions = append(details.Actions, entity.MessageErrorActionContactOptedOut)
+		}
+
+	case hardBounce:
+		if s.optOut(ctx, conversation.ContactID, channelType) {
+			details.Actions = append(details.Actions, entity.MessageErrorActionContactSuppressed)
+		}
+
+	case details.Category == entity.MessageErrorReengagementRequired:
+		if names := s.suggestTemplates(ctx, conversation.ChannelID); len(names) > 0 {
+			details.SuggestedTemplates = names
+			details.Actions = append(details.Actions, entity.MessageErrorActionTemplateSuggested)
+		}
+	}
+}
+
+// optOut marks a contact as opted out of a channel type, reporting whether it is
+func (s *MessageStatusService) optOut(ctx context.Context, contactID, channelType string) bool {
+	contact, err := s.contactRepo.FindByID(ctx, contactID)
+	if err != nil {
+		return false
+	}
+	if contact.IsOptedOut(channelType) {
+		return true
+	}
+
+	contact.OptOut(channelType)
+	if err := s.contactRepo.Update(ctx, contact); err != nil {
+		logger.Warn("Failed to mark contact as opted out",
+			zap.String("contact_id", contact.ID),
+			zap.Error(err),
+		)
+		return false
+	}
+	return true
+}
+
+// suggestTemplates returns the names of approved templates of a channel, utility
+// templates first since they can be sent for service follow-ups
+func (s *MessageStatusService) suggestTemplates(ctx context.Context, channelID string) []string {
+	if s.templateRepo == nil {
+		return nil
+	}
+	templates, _, err := s.templateRepo.FindByChannel(ctx, channelID, &repository.ListParams{Page: 1, PageSize: 100})
+	if err != nil {
+		return nil
+	}
+
+	var utility, other []string
+	for _, template := range templates {
+		if !template.IsApproved() {
+			continue
+		}
+		if template.Category == entity.TemplateCategoryUtility {
+			utility = append(utility, template.Name)
+		} else {
+			other = append(other, template.Name)
+		}
+	}
+	names := append(utility, other...)
+	if len(names) > maxSuggestedTemplates {
+		names = names[:maxSuggestedTemplates]
+	}
+	return names
+}
+
+// toMessageStatus maps the status of a provider update to a message status
+func toMessageStatus(status string) entity.MessageStatus {
+	switch status {
+	case "sent":
+		return entity.MessageStatusSent
+	case "delivered":
+		return entity.MessageStatusDelivered
+	case "read":
+		return entity.MessageStatusRead
+	case "failed":
+		return entity.MessageStatusFailed
+	default:
+		return entity.MessageStatusPending
+	}
+}
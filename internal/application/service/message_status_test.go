@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyMessageError(t *testing.T) {
+	tests := []struct {
+		name        string
+		channelType string
+		code        string
+		message     string
+		wantCode    string
+		want        entity.MessageErrorCategory
+		retryable   bool
+	}{
+		{"meta re-engagement from message", "whatsapp_official", "", "[131047] Re-engagement message: more than 24 hours", "131047", entity.MessageErrorReengagementRequired, false},
+		{"meta rate limit", "whatsapp_official", "130429", "", "130429", entity.MessageErrorRateLimited, true},
+		{"meta template params", "whatsapp_official", "132000", "", "132000", entity.MessageErrorTemplateError, false},
+		{"twilio opt-out", "sms", "21610", "Attempt to send to unsubscribed recipient", "21610", entity.MessageErrorRecipientOptedOut, false},
+		{"twilio unreachable", "sms", "30003", "", "30003", entity.MessageErrorRecipientUnreachable, true},
+		{"smtp unknown user", "email", "", "550 5.1.1 The email account does not exist", "5.1.1", entity.MessageErrorInvalidRecipient, false},
+		{"smtp mailbox full", "email", "452", "Mailbox full", "452", entity.MessageErrorMailboxFull, true},
+		{"smtp policy", "email", "", "554 5.7.1 Message rejected as spam", "5.7.1", entity.MessageErrorContentFiltered, false},
+		{"smtp deferred", "email", "421", "", "421", entity.MessageErrorTemporary, true},
+		{"email complaint", "email", "complaint", "", "complaint", entity.MessageErrorRecipientOptedOut, false},
+		{"unknown code", "sms", "99999", "", "99999", entity.MessageErrorUnknown, false},
+		{"unknown provider", "telegram", "", "Forbidden: bot was blocked", "", entity.MessageErrorUnknown, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClassifyMessageError(tt.channelType, tt.code, tt.message)
+			assert.Equal(t, tt.want, got.Category)
+			assert.Equal(t, tt.wantCode, got.Code)
+			assert.Equal(t, tt.retryable, got.Retryable)
+			assert.NotEmpty(t, got.Hint)
+		})
+	}
+}
+
+func newMessageStatusFixture() (*MessageStatusService, *testutil.MockMessageRepository, *testutil.MockContactRepository, *mockTemplateRepository) {
+	messages := testutil.NewMockMessageRepository()
+	conversations := testutil.NewMockConversationRepository()
+	contacts := testutil.NewMockContactRepository()
+	templates := newMockTemplateRepository()
+
+	conversations.Conversations["conv-1"] = &entity.Conversation{ID: "conv-1", TenantID: "tenant-1", ChannelID: "ch-1", ContactID: "contact-1"}
+	contacts.Contacts["contact-1"] = &entity.Contact{ID: "contact-1", TenantID: "tenant-1", CustomFields: map[string]string{}}
+	messages.Messages["msg-1"] = &entity.Message{ID: "msg-1", ConversationID: "conv-1", ExternalID: "wamid.1", Status: entity.MessageStatusSent}
+
+	svc := NewMessageStatusService(messages, conversations, contacts)
+	svc.SetTemplateRepository(templates)
+	return svc, messages, contacts, templates
+}
+
+func TestMessageStatusService_OptOut(t *testing.T) {
+	svc, messages, contacts, _ := newMessageStatusFixture()
+
+	err := svc.HandleStatusUpdate(context.Background(), &nats.StatusUpdate{
+		ExternalID:   "wamid.1",
+		ChannelType:  "sms",
+		Status:       "failed",
+		ErrorCode:    "21610",
+		ErrorMessage: "Attempt to send to unsubscribed recipient",
+	})
+	require.NoError(t, err)
+
+	message := messages.Messages["msg-1"]
+	assert.Equal(t, entity.MessageStatusFailed, message.Status)
+	require.NotNil(t, message.ErrorDetails)
+	assert.Equal(t, "twilio", message.ErrorDetails.Provider)
+	assert.Equal(t, entity.MessageErrorRecipientOptedOut, message.ErrorDetails.Category)
+	assert.Equal(t, []string{entity.MessageErrorActionContactOptedOut}, message.ErrorDetails.Actions)
+	assert.True(t, contacts.Contacts["contact-1"].IsOptedOut("sms"))
+	assert.False(t, contacts.Contacts["contact-1"].IsOptedOut("email"))
+}
+
+func TestMessageStatusService_EmailHardBounceSuppresses(t *testing.T) {
+	svc, messages, contacts, _ := newMessageStatusFixture()
+
+	err := svc.HandleStatusUpdate(context.Background(), &nats.StatusUpdate{
+		MessageID:    "msg-1",
+		ChannelType:  "email",
+		Status:       "failed",
+		ErrorCode:    "550",
+		ErrorMessage: "550 5.1.1 No such user",
+	})
+	require.NoError(t, err)
+
+	details := messages.Messages["msg-1"].ErrorDetails
+	require.NotNil(t, details)
+	assert.Equal(t, entity.MessageErrorInvalidRecipient, details.Category)
+	assert.Equal(t, []string{entity.MessageErrorActionContactSuppressed}, details.Actions)
+	assert.True(t, contacts.Contacts["contact-1"].IsOptedOut("email"))
+
+	// Deferrals that finally failed don't suppress the address
+	contacts.Contacts["contact-1"].CustomFields = map[string]string{}
+	err = svc.HandleStatusUpdate(context.Background(), &nats.StatusUpdate{
+		MessageID:    "msg-1",
+		ChannelType:  "email",
+		Status:       "failed",
+		ErrorCode:    "451",
+		ErrorMessage: "451 4.7.1 Greylisted",
+	})
+	require.NoError(t, err)
+	assert.Empty(t, messages.Messages["msg-1"].ErrorDetails.Actions)
+	assert.False(t, contacts.Contacts["contact-1"].IsOptedOut("email"))
+}
+
+func TestMessageStatusService_SuggestsTemplates(t *testing.T) {
+	svc, messages, _, templates := newMessageStatusFixture()
+	templates.Templates["t-1"] = &entity.Template{ID: "t-1", ChannelID: "ch-1", Name: "promo", Category: entity.TemplateCategoryMarketing, Status: entity.TemplateStatusApproved}
+	templates.Templates["t-2"] = &entity.Template{ID: "t-2", ChannelID: "ch-1", Name: "follow_up", Category: entity.TemplateCategoryUtility, Status: entity.TemplateStatusApproved}
+	templates.Templates["t-3"] = &entity.Template{ID: "t-3", ChannelID: "ch-1", Name: "draft", Category: entity.TemplateCategoryUtility, Status: entity.TemplateStatusPending}
+	templates.Templates["t-4"] = &entity.Template{ID: "t-4", ChannelID: "ch-2", Name: "other_channel", Category: entity.TemplateCategoryUtility, Status: entity.TemplateStatusApproved}
+
+	err := svc.HandleStatusUpdate(context.Background(), &nats.StatusUpdate{
+		MessageID:    "msg-1",
+		ChannelType:  "whatsapp_official",
+		Status:       "failed",
+		ErrorMessage: "[131047] Re-engagement message: Message failed to send because more than 24 hours have passed",
+	})
+	require.NoError(t, err)
+
+	details := messages.Messages["msg-1"].ErrorDetails
+	require.NotNil(t, details)
+	assert.Equal(t, entity.MessageErrorReengagementRequired, details.Category)
+	assert.Equal(t, []string{"follow_up", "promo"}, details.SuggestedTemplates)
+	assert.Equal(t, []string{entity.MessageErrorActionTemplateSuggested}, details.Actions)
+}
+
+func TestMessageStatusService_NonFailure(t *testing.T) {
+	svc, messages, _, _ := newMessageStatusFixture()
+
+	require.NoError(t, svc.HandleStatusUpdate(context.Background(), &nats.StatusUpdate{ExternalID: "wamid.1", Status: "read"}))
+	assert.Equal(t, entity.MessageStatusRead, messages.Messages["msg-1"].Status)
+	assert.Nil(t, messages.Messages["msg-1"].ErrorDetails)
+
+	assert.Error(t, svc.HandleStatusUpdate(context.Background(), &nats.StatusUpdate{ExternalID: "wamid.unknown", Status: "read"}))
+}
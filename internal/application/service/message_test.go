@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
 	"github.com/msgfy/linktor/pkg/testutil"
 	"github.com/stretchr/testify/assert"
 )
@@ -32,7 +33,7 @@ func setupMessageTest() *MessageService {
 func TestMessageService_ListByConversation(t *testing.T) {
 	svc := setupMessageTest()
 
-	messages, count, err := svc.ListByConversation(context.Background(), "conv1", nil)
+	messages, count, err := svc.ListByConversation(context.Background(), "tenant1", "conv1", nil)
 	assert.NoError(t, err)
 	assert.Empty(t, messages)
 	assert.Equal(t, int64(0), count)
@@ -42,6 +43,7 @@ func TestMessageService_Send(t *testing.T) {
 	svc := setupMessageTest()
 
 	msg, err := svc.Send(context.Background(), &SendMessageInput{
+		TenantID:       "tenant1",
 		ConversationID: "conv1",
 		SenderType:     "user",
 		SenderID:       "user1",
@@ -59,6 +61,7 @@ func TestMessageService_Send_MissingConversation(t *testing.T) {
 	svc := setupMessageTest()
 
 	_, err := svc.Send(context.Background(), &SendMessageInput{
+		TenantID:       "tenant1",
 		ConversationID: "",
 		Content:        "Hello!",
 	})
@@ -70,6 +73,7 @@ func TestMessageService_Send_ConversationNotFound(t *testing.T) {
 	svc := setupMessageTest()
 
 	_, err := svc.Send(context.Background(), &SendMessageInput{
+		TenantID:       "tenant1",
 		ConversationID: "nonexistent",
 		SenderType:     "user",
 		Content:        "Hello!",
@@ -77,3 +81,57 @@ func TestMessageService_Send_ConversationNotFound(t *testing.T) {
 
 	assert.Error(t, err)
 }
+
+func TestMessageService_OtherTenantsConversation(t *testing.T) {
+	svc := setupMessageTest()
+	msgRepo := svc.messageRepo.(*testutil.MockMessageRepository)
+	msgRepo.Messages["msg1"] = &entity.Message{ID: "msg1", ConversationID: "conv1", ExternalID: "wamid.1"}
+	ctx := context.Background()
+
+	_, _, err := svc.ListByConversation(ctx, "tenant2", "conv1", nil)
+	assert.True(t, errors.IsNotFound(err))
+
+	_, err = svc.Send(ctx, &SendMessageInput{
+		TenantID:       "tenant2",
+		ConversationID: "conv1",
+		SenderType:     "user",
+		SenderID:       "user2",
+		ContentType:    "text",
+		Content:        "Hello!",
+	})
+	assert.True(t, errors.IsNotFound(err))
+	assert.Len(t, msgRepo.Messages, 1)
+
+	err = svc.SendReaction(ctx, "tenant2", "conv1", "msg1", "👍", "user2")
+	assert.True(t, errors.IsNotFound(err))
+	assert.NoError(t, svc.SendReaction(ctx, "tenant1", "conv1", "msg1", "👍", "user1"))
+}
+
+func TestMessageService_SendReaction_MessageOfOtherConversation(t *testing.T) {
+	svc := setupMessageTest()
+	svc.messageRepo.(*testutil.MockMessageRepository).Messages["msg2"] = &entity.Message{ID: "msg2", ConversationID: "conv2"}
+
+	err := svc.SendReaction(context.Background(), "tenant1", "conv1", "msg2", "👍", "user1")
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestMessageService_SignsStoredAttachments(t *testing.T) {
+	svc := setupMessageTest()
+	media, _ := newMediaTestService(t)
+	svc.SetMediaService(media)
+	svc.messageRepo.(*testutil.MockMessageRepository).Messages["msg1"] = &entity.Message{
+		ID:             "msg1",
+		ConversationID: "conv1",
+		Attachments: []*entity.MessageAttachment{{
+			URL:      "https://linktor.example.com/api/v1/media/channel1/msg1/photo.jpg?expires=1&signature=stale",
+			Metadata: map[string]string{"storage_key": "channel1/msg1/photo.jpg"},
+		}},
+	}
+
+	messages, _, err := svc.ListByConversation(context.Background(), "tenant1", "conv1", nil)
+	assert.NoError(t, err)
+	if assert.Len(t, messages, 1) {
+		assert.NotContains(t, messages[0].Attachments[0].URL, "signature=stale")
+		assert.Contains(t, messages[0].Attachments[0].URL, "/api/v1/media/channel1/msg1/photo.jpg?expires=")
+	}
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// messageTranslationMaxPerRequest caps the messages translated for a single
+	// response; the rest are translated as later requests page through them
+	messageTranslationMaxPerRequest = 50
+	// messageTranslationConcurrency is how many translations run at once for a response
+	messageTranslationConcurrency = 4
+	// messageTranslationMaxLength caps the text sent for translation
+	messageTranslationMaxLength = 4000
+	messageTranslationTimeout   = 20 * time.Second
+)
+
+const messageTranslationPrompt = "You translate messages of customer service conversations for agents. " +
+	"Translate the user's message into the language with the code %q, keeping names, numbers, links and order references as they are. " +
+	"Reply with the translation only."
+
+// languageCodePattern matches language codes such as "en", "pt-BR" or "zh-Hant"
+var languageCodePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z0-9]{2,8})?$`)
+
+// MessageTranslationService translates messages for agents reading conversations in
+// another language. Translations are stored per message and language, so each message
+// is translated once however often its conversation is read, and again after an edit.
+type MessageTranslationService struct {
+	repo        repository.MessageTranslationRepository
+	routingRepo repository.RoutingRepository
+	aiFactory   *AIProviderFactory
+}
+
+// NewMessageTranslationService creates a new message translation service
+func NewMessageTranslationService(
+	repo repository.MessageTranslationRepository,
+	routingRepo repository.RoutingRepository,
+	aiFactory *AIProviderFactory,
+) *MessageTranslationService {
+	return &MessageTranslationService{
+		repo:        repo,
+		routingRepo: routingRepo,
+		aiFactory:   aiFactory,
+	}
+}
+
+// PreferredLanguage returns the language an agent reads conversations in when their
+// routing profile says they work with machine translation: the first language of the
+// profile. It returns an empty string for agents who read messages as they are.
+func (s *MessageTranslationService) PreferredLanguage(ctx context.Context, tenantID, userID string) string {
+	if s.routingRepo == nil || userID == "" {
+		return ""
+	}
+	profiles, err := s.routingRepo.FindProfiles(ctx, tenantID)
+	if err != nil {
+		logger.Warn("Failed to load routing profiles for translations",
+			zap.String("tenant_id", tenantID),
+			zap.Error(err),
+		)
+		return ""
+	}
+	for _, profile := range profiles {
+		if profile.UserID == userID && profile.TranslationAssisted && len(profile.Languages) > 0 {
+			return profile.Languages[0]
+		}
+	}
+	return ""
+}
+
+// Translate sets the translation into language on the text messages that need one,
+// reusing stored translations and translating the others. Messages already in the
+// language are left as they are. A message failing to translate is logged and left
+// without a translation rather than failing the others.
+func (s *MessageTranslationService) Translate(ctx context.Context, tenantID, language string, messages []*entity.Message) error {
+	if !languageCodePattern.MatchString(language) {
+		return errors.Validation("translate_to must be a language code such as \"en\" or \"pt-BR\"")
+	}
+
+	pending := make(map[string]*entity.Message)
+	var ids []string
+	for _, message := range messages {
+		if message != nil && translatable(message) {
+			pending[message.ID] = message
+			ids = append(ids, message.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	stored, err := s.repo.FindByMessages(ctx, ids, language)
+	if err != nil {
+		return err
+	}
+	for _, translation := range stored {
+		message, ok := pending[translation.MessageID]
+		if ok && translation.SourceDigest == contentDigest(message.Content) {
+			message.Translation = translation
+			delete(pending, message.ID)
+		}
+	}
+
+	base := strings.ToLower(strings.SplitN(language, "-", 2)[0])
+	var missing []*entity.Message
+	for _, id := range ids {
+		message, ok := pending[id]
+		if !ok {
+			continue
+		}
+		if DetectLanguage(message.Content) == base {
+			continue
+		}
+		if len(missing) == messageTranslationMaxPerRequest {
+			break
+		}
+		missing = append(missing, message)
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	provider, err := s.aiFactory.FirstAvailable()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(WithAIUsage(ctx, tenantID, "", entity.AIFeatureTranslation), messageTranslationTimeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, messageTranslationConcurrency)
+	for _, message := range missing {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(message *entity.Message) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			translation, err := s.translate(ctx, provider, message, language)
+			if err != nil {
+				logger.Warn("Failed to translate message",
+					zap.String("message_id", message.ID),
+					zap.String("language", language),
+					zap.Error(err),
+				)
+				return
+			}
+			message.Translation = translation
+		}(message)
+	}
+	wg.Wait()
+	return nil
+}
+
+// translate translates a message and stores the translation
+func (s *MessageTranslationService) translate(ctx context.Context, provider AIProvider, message *entity.Message, language string) (*entity.MessageTranslation, error) {
+	resp, err := provider.Complete(ctx, &CompletionRequest{
+		Messages: []Message{
+			{Role: "system", Content: fmt.Sprintf(messageTranslationPrompt, language)},
+			{Role: "user", Content: truncateText(message.Content, messageTranslationMaxLength)},
+		},
+		Model:       provider.DefaultModel(),
+		MaxTokens:   1024,
+		Temperature: 0.1,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to translate message")
+	}
+	content := strings.TrimSpace(resp.Content)
+	if content == "" {
+		return nil, errors.New(errors.ErrCodeInternal, "AI returned an empty translation")
+	}
+
+	translation := &entity.MessageTranslation{
+		MessageID:      message.ID,
+		Language:       language,
+		SourceLanguage: DetectLanguage(message.Content),
+		Content:        content,
+		SourceDigest:   contentDigest(message.Content),
+		CreatedAt:      time.Now(),
+	}
+	if err := s.repo.Save(ctx, translation); err != nil {
+		// The translation is still shown; it is just translated again next time
+		logger.Warn("Failed to store message translation",
+			zap.String("message_id", message.ID),
+			zap.Error(err),
+		)
+	}
+	return translation, nil
+}
+
+// translatable reports whether a message has text worth translating
+func translatable(message *entity.Message) bool {
+	if message.ContentType != entity.ContentTypeText && message.ContentType != "" {
+		return false
+	}
+	return !message.IsDeleted && strings.TrimSpace(message.Content) != ""
+}
+
+// contentDigest identifies the content a translation was made from, so edited
+// messages are translated again
+func contentDigest(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
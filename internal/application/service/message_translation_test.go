@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockMessageTranslationRepo struct {
+	mu           sync.Mutex
+	translations map[string]*entity.MessageTranslation
+}
+
+func (m *mockMessageTranslationRepo) FindByMessages(ctx context.Context, messageIDs []string, language string) ([]*entity.MessageTranslation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var translations []*entity.MessageTranslation
+	for _, id := range messageIDs {
+		if translation, ok := m.translations[id+"/"+language]; ok {
+			translations = append(translations, translation)
+		}
+	}
+	return translations, nil
+}
+
+func (m *mockMessageTranslationRepo) Save(ctx context.Context, translation *entity.MessageTranslation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.translations[translation.MessageID+"/"+translation.Language] = translation
+	return nil
+}
+
+// translatingAIProvider "translates" by tagging the text, counting its calls
+type translatingAIProvider struct {
+	mockAIProvider
+	mu    sync.Mutex
+	calls int
+}
+
+func (p *translatingAIProvider) Complete(ctx context.Context, req *CompletionRequest) (*CompletionResponse, error) {
+	p.mu.Lock()
+	p.calls++
+	p.mu.Unlock()
+	return &CompletionResponse{Content: "[en] " + req.Messages[len(req.Messages)-1].Content}, nil
+}
+
+func newTranslationTestService() (*MessageTranslationService, *mockMessageTranslationRepo, *translatingAIProvider, *mockRoutingRepository) {
+	repo := &mockMessageTranslationRepo{translations: make(map[string]*entity.MessageTranslation)}
+	routing := newMockRoutingRepository()
+	ai := &translatingAIProvider{mockAIProvider: mockAIProvider{name: entity.AIProviderOpenAI, available: true}}
+	factory := NewAIProviderFactory()
+	factory.Register(ai)
+	return NewMessageTranslationService(repo, routing, factory), repo, ai, routing
+}
+
+func TestMessageTranslationService_Translate(t *testing.T) {
+	svc, repo, ai, _ := newTranslationTestService()
+	ctx := context.Background()
+	messages := func() []*entity.Message {
+		return []*entity.Message{
+			{ID: "m1", ContentType: entity.ContentTypeText, Content: "Olá, o meu pedido não chegou"},
+			{ID: "m2", ContentType: entity.ContentTypeText, Content: "Sorry, can you please send me the order number?"},
+			{ID: "m3", ContentType: entity.ContentTypeImage, Content: "https://cdn.example.com/photo.jpg"},
+			{ID: "m4", ContentType: entity.ContentTypeText, Content: "É o 4521", IsDeleted: true},
+		}
+	}
+
+	first := messages()
+	require.NoError(t, svc.Translate(ctx, "tenant-1", "en", first))
+	require.NotNil(t, first[0].Translation)
+	assert.Equal(t, "[en] Olá, o meu pedido não chegou", first[0].Translation.Content)
+	assert.Equal(t, "pt", first[0].Translation.SourceLanguage)
+	assert.Nil(t, first[1].Translation, "messages already in the language are left alone")
+	assert.Nil(t, first[2].Translation)
+	assert.Nil(t, first[3].Translation)
+	assert.Equal(t, 1, ai.calls)
+	assert.Len(t, repo.translations, 1)
+
+	// Stored translations are reused
+	second := messages()
+	require.NoError(t, svc.Translate(ctx, "tenant-1", "en", second))
+	require.NotNil(t, second[0].Translation)
+	assert.Equal(t, 1, ai.calls)
+
+	// Edited messages are translated again
+	edited := messages()
+	edited[0].Content = "Olá, o meu pedido chegou partido"
+	require.NoError(t, svc.Translate(ctx, "tenant-1", "en", edited))
+	assert.Equal(t, "[en] Olá, o meu pedido chegou partido", edited[0].Translation.Content)
+	assert.Equal(t, 2, ai.calls)
+
+	assert.Error(t, svc.Translate(ctx, "tenant-1", "english please", messages()))
+}
+
+func TestMessageTranslationService_PreferredLanguage(t *testing.T) {
+	svc, _, _, routing := newTranslationTestService()
+	ctx := context.Background()
+	routing.profiles["agent-1"] = &entity.AgentRoutingProfile{UserID: "agent-1", TenantID: "tenant-1", Languages: []string{"en", "es"}, TranslationAssisted: true}
+	routing.profiles["agent-2"] = &entity.AgentRoutingProfile{UserID: "agent-2", TenantID: "tenant-1", Languages: []string{"pt"}}
+
+	assert.Equal(t, "en", svc.PreferredLanguage(ctx, "tenant-1", "agent-1"))
+	assert.Empty(t, svc.PreferredLanguage(ctx, "tenant-1", "agent-2"), "agents not using translation read messages as they are")
+	assert.Empty(t, svc.PreferredLanguage(ctx, "tenant-1", "agent-3"))
+}
@@ -0,0 +1,1260 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Events published by newsletters
+const (
+	EventNewsletterEditionSent  = "newsletter.edition_sent"
+	EventNewsletterSubscribed   = "newsletter.subscribed"
+	EventNewsletterUnsubscribed = "newsletter.unsubscribed"
+)
+
+// Frequency cap applied when a newsletter does not set one: at most this many
+// newsletter messages per contact within the window, across all newsletters
+const (
+	DefaultNewsletterFrequencyCap      = 4
+	DefaultNewsletterFrequencyCapHours = 7 * 24
+)
+
+// Smart send settings applied when a newsletter enables smart send without them
+const (
+	DefaultSmartSendWindowHours    = 12
+	DefaultSmartSendControlPercent = 10
+	DefaultSmartSendMinEngagements = 3
+	maxSmartSendWindowHours        = 24
+)
+
+// smartSendHistory is how far back reads and replies are counted to pick a send time
+const smartSendHistory = 90 * 24 * time.Hour
+
+// DefaultUnsubscribeKeywords unsubscribe a contact from every newsletter of the channel
+var DefaultUnsubscribeKeywords = []string{"STOP", "UNSUBSCRIBE", "CANCEL", "SAIR", "PARAR", "CANCELAR"}
+
+const defaultUnsubscribeMessage = "You have been unsubscribed and will no longer receive these messages."
+
+// newsletterReplyWindow is how long after a send an inbound message counts as a reply
+const newsletterReplyWindow = 48 * time.Hour
+
+// newsletterChannelTypes are the channels newsletters can be sent on
+var newsletterChannelTypes = map[entity.ChannelType]bool{
+	entity.ChannelTypeWhatsApp:           true,
+	entity.ChannelTypeWhatsAppOfficial:   true,
+	entity.ChannelTypeWhatsAppUnofficial: true,
+	entity.ChannelTypeTelegram:           true,
+	entity.ChannelTypeEmail:              true,
+}
+
+// NewsletterInput represents input for creating or updating a newsletter
+type NewsletterInput struct {
+	Name                 string                      `json:"name" binding:"required"`
+	Description          string                      `json:"description"`
+	ChannelID            string                      `json:"channel_id" binding:"required"`
+	SegmentTags          []string                    `json:"segment_tags,omitempty"`
+	SegmentID            string                      `json:"segment_id,omitempty"`
+	ContentType          entity.ContentType          `json:"content_type"`
+	Content              string                      `json:"content" binding:"required"`
+	Metadata             map[string]string           `json:"metadata,omitempty"`
+	Schedule             entity.NewsletterSchedule   `json:"schedule"`
+	FrequencyCap         int                         `json:"frequency_cap"`
+	FrequencyCapHours    int                         `json:"frequency_cap_hours"`
+	SuppressInactiveDays int                         `json:"suppress_inactive_days"` // Skip subscribers without reads or replies in this many days
+	OptInKeyword         string                      `json:"opt_in_keyword"`
+	UnsubscribeKeywords  []string                    `json:"unsubscribe_keywords,omitempty"`
+	WelcomeMessage       string                      `json:"welcome_message"`
+	UnsubscribeMessage   string                      `json:"unsubscribe_message"`
+	SmartSend            *entity.NewsletterSmartSend `json:"smart_send,omitempty"`
+	Image                *entity.NewsletterImage     `json:"image,omitempty"`
+	IsActive             *bool                       `json:"is_active,omitempty"`
+	ComplianceOverride   *ComplianceOverrideInput    `json:"compliance_override,omitempty"` // Saves content breaking blocking compliance rules
+}
+
+// NewsletterImageRenderer renders an image and stores it, returning its public URL
+type NewsletterImageRenderer interface {
+	RenderToURL(ctx context.Context, req *entity.RenderRequest) (*entity.RenderResponse, error)
+}
+
+// SubscribeContactsInput represents contacts who gave consent to receive a newsletter
+type SubscribeContactsInput struct {
+	ContactIDs []string `json:"contact_ids" binding:"required"`
+	Source     string   `json:"source"` // Where consent was collected; api when empty
+}
+
+// SendEditionInput represents a manual send of a newsletter
+type SendEditionInput struct {
+	Content            string                   `json:"content,omitempty"` // Overrides the newsletter content for this edition
+	ComplianceOverride *ComplianceOverrideInput `json:"compliance_override,omitempty"`
+}
+
+// NewsletterService sends recurring newsletters to the opted-in contacts of a
+// channel. Contacts subscribe through the API or an opt-in keyword and leave with
+// an unsubscribe keyword; sends are capped per contact across all newsletters.
+type NewsletterService struct {
+	repo             repository.NewsletterRepository
+	contactRepo      repository.ContactRepository
+	conversationRepo repository.ConversationRepository
+	channelRepo      repository.ChannelRepository
+	messageService   *MessageService
+	producer         nats.Publisher
+	variables        *ConversationVariablesService
+	segments         *ContactSegmentService
+	renderer         NewsletterImageRenderer
+	identifiers      *IdentifierService
+	compliance       *ComplianceService
+	tenantRepo       repository.TenantRepository
+	maintenance      *ChannelMaintenanceService
+}
+
+// NewNewsletterService creates a new newsletter service
+func NewNewsletterService(
+	repo repository.NewsletterRepository,
+	contactRepo repository.ContactRepository,
+	conversationRepo repository.ConversationRepository,
+	channelRepo repository.ChannelRepository,
+	messageService *MessageService,
+	producer nats.Publisher,
+) *NewsletterService {
+	return &NewsletterService{
+		repo:             repo,
+		contactRepo:      contactRepo,
+		conversationRepo: conversationRepo,
+		channelRepo:      channelRepo,
+		messageService:   messageService,
+		producer:         producer,
+	}
+}
+
+// SetVariablesService enables {{variable}} placeholders in newsletter content
+func (s *NewsletterService) SetVariablesService(variables *ConversationVariablesService) {
+	s.variables = variables
+}
+
+// SetSegmentService lets newsletters target a contact segment
+func (s *NewsletterService) SetSegmentService(segments *ContactSegmentService) {
+	s.segments = segments
+}
+
+// SetImageRenderer enables newsletters with a personalized image per recipient
+func (s *NewsletterService) SetImageRenderer(renderer NewsletterImageRenderer) {
+	s.renderer = renderer
+}
+
+// SetIdentifierService fails deliveries to contacts without a valid identifier for the
+// channel before anything is sent
+func (s *NewsletterService) SetIdentifierService(identifiers *IdentifierService) {
+	s.identifiers = identifiers
+}
+
+// SetComplianceService checks newsletter content against the compliance rules before
+// it is saved or sent
+func (s *NewsletterService) SetComplianceService(compliance *ComplianceService) {
+	s.compliance = compliance
+}
+
+// SetTenantRepository applies the tenant's marketing frequency cap and inactivity
+// suppression to every send
+func (s *NewsletterService) SetTenantRepository(tenantRepo repository.TenantRepository) {
+	s.tenantRepo = tenantRepo
+}
+
+// SetChannelMaintenance holds editions back while their channel is under maintenance
+func (s *NewsletterService) SetChannelMaintenance(maintenance *ChannelMaintenanceService) {
+	s.maintenance = maintenance
+}
+
+// Create creates a newsletter and schedules its first edition
+func (s *NewsletterService) Create(ctx context.Context, tenantID string, input *NewsletterInput) (*entity.Newsletter, error) {
+	now := time.Now()
+	newsletter := &entity.Newsletter{
+		ID:        uuid.New().String(),
+		TenantID:  tenantID,
+		IsActive:  true,
+		CreatedAt: now,
+	}
+	if err := s.apply(ctx, newsletter, input, now); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, newsletter); err != nil {
+		return nil, err
+	}
+	return newsletter, nil
+}
+
+// Get returns a newsletter of a tenant
+func (s *NewsletterService) Get(ctx context.Context, tenantID, id string) (*entity.Newsletter, error) {
+	newsletter, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if newsletter.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeNotFound, "newsletter not found")
+	}
+	return newsletter, nil
+}
+
+// List returns the newsletters of a tenant
+func (s *NewsletterService) List(ctx context.Context, tenantID string) ([]*entity.Newsletter, error) {
+	return s.repo.ListByTenant(ctx, tenantID)
+}
+
+// Update updates a newsletter and reschedules its next edition
+func (s *NewsletterService) Update(ctx context.Context, tenantID, id string, input *NewsletterInput) (*entity.Newsletter, error) {
+	newsletter, err := s.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.apply(ctx, newsletter, input, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, newsletter); err != nil {
+		return nil, err
+	}
+	return newsletter, nil
+}
+
+// Delete deletes a newsletter with its subscriptions and editions
+func (s *NewsletterService) Delete(ctx context.Context, tenantID, id string) error {
+	if _, err := s.Get(ctx, tenantID, id); err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, id)
+}
+
+// apply validates an input and copies it onto a newsletter
+func (s *NewsletterService) apply(ctx context.Context, newsletter *entity.Newsletter, input *NewsletterInput, now time.Time) error {
+	if strings.TrimSpace(input.Name) == "" {
+		return errors.Validation("name is required")
+	}
+	if strings.TrimSpace(input.Content) == "" {
+		return errors.Validation("content is required")
+	}
+	if err := input.Schedule.Validate(); err != nil {
+		return errors.Validation("invalid schedule: " + err.Error())
+	}
+	if input.FrequencyCap < 0 || input.FrequencyCapHours < 0 {
+		return errors.Validation("frequency cap must not be negative")
+	}
+	if input.SuppressInactiveDays < 0 {
+		return errors.Validation("suppress_inactive_days must not be negative")
+	}
+	smartSend, err := normalizeSmartSend(input.SmartSend)
+	if err != nil {
+		return err
+	}
+	if input.Image != nil {
+		if s.renderer == nil {
+			return errors.Validation("image personalization is not available")
+		}
+		if strings.TrimSpace(input.Image.TemplateID) == "" {
+			return errors.Validation("image template_id is required")
+		}
+		switch input.Image.Format {
+		case "", entity.OutputFormatPNG, entity.OutputFormatWebP, entity.OutputFormatJPEG:
+		default:
+			return errors.Validation("image format must be png, webp or jpeg")
+		}
+		if input.Image.Width < 0 {
+			return errors.Validation("image width must not be negative")
+		}
+	}
+
+	channel, err := s.channelRepo.FindByID(ctx, input.ChannelID)
+	if err != nil || channel.TenantID != newsletter.TenantID {
+		return errors.Validation("channel not found")
+	}
+	if !newsletterChannelTypes[channel.Type] {
+		return errors.Validation("newsletters are not supported on " + string(channel.Type) + " channels")
+	}
+
+	if input.SegmentID != "" {
+		if s.segments == nil {
+			return errors.Validation("contact segments are not available")
+		}
+		if _, err := s.segments.Get(ctx, newsletter.TenantID, input.SegmentID); err != nil {
+			return errors.Validation("segment not found")
+		}
+	}
+
+	if s.compliance != nil {
+		report, err := s.compliance.Enforce(ctx, newsletter.TenantID,
+			&ComplianceTarget{Type: entity.ComplianceTargetNewsletter, ID: newsletter.ID, Name: input.Name},
+			&ComplianceContent{ChannelType: channel.Type, Text: input.Content},
+			input.ComplianceOverride,
+		)
+		if err != nil {
+			return err
+		}
+		newsletter.ComplianceWarnings = report.Warnings()
+	}
+
+	optInKeyword := normalizeKeyword(input.OptInKeyword)
+	unsubscribeKeywords := make([]string, 0, len(input.UnsubscribeKeywords))
+	for _, keyword := range input.UnsubscribeKeywords {
+		if keyword = normalizeKeyword(keyword); keyword != "" {
+			unsubscribeKeywords = append(unsubscribeKeywords, keyword)
+		}
+	}
+	if optInKeyword != "" && (isDefaultUnsubscribeKeyword(optInKeyword) || containsKeyword(unsubscribeKeywords, optInKeyword)) {
+		return errors.Validation("opt_in_keyword must differ from the unsubscribe keywords")
+	}
+
+	newsletter.Name = input.Name
+	newsletter.Description = input.Description
+	newsletter.ChannelID = channel.ID
+	newsletter.SegmentTags = input.SegmentTags
+	newsletter.SegmentID = input.SegmentID
+	newsletter.ContentType = input.ContentType
+	if newsletter.ContentType == "" {
+		newsletter.ContentType = entity.ContentTypeText
+	}
+	newsletter.Content = input.Content
+	newsletter.Metadata = input.Metadata
+	newsletter.Schedule = input.Schedule
+	newsletter.FrequencyCap = input.FrequencyCap
+	if newsletter.FrequencyCap == 0 {
+		newsletter.FrequencyCap = DefaultNewsletterFrequencyCap
+	}
+	newsletter.FrequencyCapHours = input.FrequencyCapHours
+	if newsletter.FrequencyCapHours == 0 {
+		newsletter.FrequencyCapHours = DefaultNewsletterFrequencyCapHours
+	}
+	newsletter.SuppressInactiveDays = input.SuppressInactiveDays
+	newsletter.OptInKeyword = optInKeyword
+	newsletter.UnsubscribeKeywords = unsubscribeKeywords
+	newsletter.WelcomeMessage = input.WelcomeMessage
+	newsletter.UnsubscribeMessage = input.UnsubscribeMessage
+	newsletter.SmartSend = smartSend
+	newsletter.Image = input.Image
+	if input.IsActive != nil {
+		newsletter.IsActive = *input.IsActive
+	}
+	newsletter.UpdatedAt = now
+
+	newsletter.NextRunAt = nil
+	if newsletter.IsActive {
+		next, _ := newsletter.Schedule.Next(now)
+		newsletter.NextRunAt = &next
+	}
+	return nil
+}
+
+// normalizeSmartSend validates smart send settings and fills in their defaults
+func normalizeSmartSend(input *entity.NewsletterSmartSend) (*entity.NewsletterSmartSend, error) {
+	if input == nil || !input.Enabled {
+		return nil, nil
+	}
+
+	smartSend := *input
+	if smartSend.WindowHours == 0 {
+		smartSend.WindowHours = DefaultSmartSendWindowHours
+	}
+	if smartSend.WindowHours < 1 || smartSend.WindowHours > maxSmartSendWindowHours {
+		return nil, errors.Validation("smart send window_hours must be between 1 and 24")
+	}
+	if smartSend.ControlPercent == 0 {
+		smartSend.ControlPercent = DefaultSmartSendControlPercent
+	}
+	if smartSend.ControlPercent < 0 || smartSend.ControlPercent > 100 {
+		return nil, errors.Validation("smart send control_percent must be between 0 and 100")
+	}
+	if smartSend.MinEngagements == 0 {
+		smartSend.MinEngagements = DefaultSmartSendMinEngagements
+	}
+	if smartSend.MinEngagements < 0 {
+		return nil, errors.Validation("smart send min_engagements must not be negative")
+	}
+	return &smartSend, nil
+}
+
+// Subscribe records the consent of contacts to receive a newsletter
+func (s *NewsletterService) Subscribe(ctx context.Context, tenantID, newsletterID string, input *SubscribeContactsInput) (int, error) {
+	newsletter, err := s.Get(ctx, tenantID, newsletterID)
+	if err != nil {
+		return 0, err
+	}
+	if len(input.ContactIDs) == 0 {
+		return 0, errors.Validation("contact_ids is required")
+	}
+
+	source := input.Source
+	if source == "" {
+		source = "api"
+	}
+
+	// Check every contact before subscribing any of them
+	for _, contactID := range input.ContactIDs {
+		contact, err := s.contactRepo.FindByID(ctx, contactID)
+		if err != nil || contact.TenantID != tenantID {
+			return 0, errors.New(errors.ErrCodeValidation, "contact not found").
+				WithDetails(map[string]string{"contact_id": contactID})
+		}
+	}
+
+	for _, contactID := range input.ContactIDs {
+		if err := s.subscribe(ctx, newsletter, contactID, source); err != nil {
+			return 0, err
+		}
+	}
+	return len(input.ContactIDs), nil
+}
+
+// Unsubscribe withdraws the consent of a contact to receive a newsletter
+func (s *NewsletterService) Unsubscribe(ctx context.Context, tenantID, newsletterID, contactID string) error {
+	newsletter, err := s.Get(ctx, tenantID, newsletterID)
+	if err != nil {
+		return err
+	}
+
+	subscription, err := s.repo.FindSubscription(ctx, newsletter.ID, contactID)
+	if err != nil {
+		return err
+	}
+	return s.unsubscribe(ctx, newsletter, subscription, "api")
+}
+
+// ListSubscriptions returns the subscriptions of a newsletter, optionally by status
+func (s *NewsletterService) ListSubscriptions(ctx context.Context, tenantID, newsletterID string, status entity.NewsletterSubscriptionStatus, params *repository.ListParams) ([]*entity.NewsletterSubscription, int64, error) {
+	if _, err := s.Get(ctx, tenantID, newsletterID); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.ListSubscriptions(ctx, newsletterID, status, params)
+}
+
+func (s *NewsletterService) subscribe(ctx context.Context, newsletter *entity.Newsletter, contactID, source string) error {
+	existing, err := s.repo.FindSubscription(ctx, newsletter.ID, contactID)
+	if err == nil && existing.Status == entity.NewsletterSubscribed {
+		return nil
+	}
+
+	now := time.Now()
+	subscription := &entity.NewsletterSubscription{
+		ID:           uuid.New().String(),
+		TenantID:     newsletter.TenantID,
+		NewsletterID: newsletter.ID,
+		ContactID:    contactID,
+		Status:       entity.NewsletterSubscribed,
+		Source:       source,
+		SubscribedAt: now,
+		UpdatedAt:    now,
+	}
+	if err := s.repo.UpsertSubscription(ctx, subscription); err != nil {
+		return err
+	}
+
+	s.publish(ctx, EventNewsletterSubscribed, newsletter.TenantID, map[string]interface{}{
+		"newsletter_id": newsletter.ID,
+		"contact_id":    contactID,
+		"source":        source,
+	})
+	return nil
+}
+
+func (s *NewsletterService) unsubscribe(ctx context.Context, newsletter *entity.Newsletter, subscription *entity.NewsletterSubscription, source string) error {
+	if subscription.Status == entity.NewsletterUnsubscribed {
+		return nil
+	}
+
+	now := time.Now()
+	subscription.Status = entity.NewsletterUnsubscribed
+	subscription.UnsubscribedAt = &now
+	subscription.UpdatedAt = now
+	if err := s.repo.UpsertSubscription(ctx, subscription); err != nil {
+		return err
+	}
+
+	// Attribute the unsubscribe to the last edition the contact received
+	if err := s.repo.MarkUnsubscribed(ctx, newsletter.ID, subscription.ContactID, now); err != nil {
+		logger.Warn("Failed to attribute newsletter unsubscribe",
+			zap.String("newsletter_id", newsletter.ID),
+			zap.Error(err),
+		)
+	}
+
+	s.publish(ctx, EventNewsletterUnsubscribed, newsletter.TenantID, map[string]interface{}{
+		"newsletter_id": newsletter.ID,
+		"contact_id":    subscription.ContactID,
+		"source":        source,
+	})
+	return nil
+}
+
+// SendNow sends an edition of a newsletter immediately
+func (s *NewsletterService) SendNow(ctx context.Context, tenantID, newsletterID string, input *SendEditionInput) (*entity.NewsletterEdition, error) {
+	newsletter, err := s.Get(ctx, tenantID, newsletterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if window := s.maintenanceWindow(ctx, newsletter.ChannelID); window != nil {
+		return nil, errors.New(errors.ErrCodeConflict, "the channel is under maintenance until "+window.EndsAt.UTC().Format(time.RFC3339))
+	}
+
+	content := newsletter.Content
+	if input != nil && strings.TrimSpace(input.Content) != "" {
+		content = input.Content
+		if err := s.checkEditionCompliance(ctx, newsletter, content, input.ComplianceOverride); err != nil {
+			return nil, err
+		}
+	}
+	return s.sendEdition(ctx, newsletter, content, "manual")
+}
+
+// checkEditionCompliance enforces the compliance rules on content sent in place of
+// the newsletter's own
+func (s *NewsletterService) checkEditionCompliance(ctx context.Context, newsletter *entity.Newsletter, content string, override *ComplianceOverrideInput) error {
+	if s.compliance == nil {
+		return nil
+	}
+	channel, err := s.channelRepo.FindByID(ctx, newsletter.ChannelID)
+	if err != nil {
+		return err
+	}
+	_, err = s.compliance.Enforce(ctx, newsletter.TenantID,
+		&ComplianceTarget{Type: entity.ComplianceTargetNewsletter, ID: newsletter.ID, Name: newsletter.Name},
+		&ComplianceContent{ChannelType: channel.Type, Text: content},
+		override,
+	)
+	return err
+}
+
+// RunDue sends the editions of the newsletters whose schedule is due and returns
+// how many were sent. Each newsletter is rescheduled before it is sent, so a failed
+// send is not retried in a loop; one due while its channel is under maintenance is
+// deferred to the end of the window. Smart sends whose time has come go out as well.
+func (s *NewsletterService) RunDue(ctx context.Context) (int, error) {
+	now := time.Now()
+	newsletters, err := s.repo.ListDue(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, newsletter := range newsletters {
+		if window := s.maintenanceWindow(ctx, newsletter.ChannelID); window != nil {
+			deferredTo := window.EndsAt
+			newsletter.NextRunAt = &deferredTo
+			if err := s.repo.Update(ctx, newsletter); err != nil {
+				logger.Warn("Failed to defer newsletter",
+					zap.String("newsletter_id", newsletter.ID),
+					zap.Error(err),
+				)
+			}
+			continue
+		}
+
+		next, err := newsletter.Schedule.Next(now)
+		if err != nil {
+			newsletter.IsActive = false
+			newsletter.NextRunAt = nil
+		} else {
+			newsletter.NextRunAt = &next
+		}
+		newsletter.LastRunAt = &now
+		if err := s.repo.Update(ctx, newsletter); err != nil {
+			logger.Warn("Failed to reschedule newsletter",
+				zap.String("newsletter_id", newsletter.ID),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		if _, err := s.sendEdition(ctx, newsletter, newsletter.Content, "schedule"); err != nil {
+			logger.Warn("Failed to send newsletter edition",
+				zap.String("tenant_id", newsletter.TenantID),
+				zap.String("newsletter_id", newsletter.ID),
+				zap.Error(err),
+			)
+			continue
+		}
+		sent++
+	}
+
+	s.runScheduledDeliveries(ctx, now)
+	return sent, nil
+}
+
+// sendEdition sends content to the subscribers of a newsletter who are in its
+// segment tags and contact segment, skipping contacts that reached a frequency
+// cap or stopped engaging. With smart send, the contacts whose best time is later
+// in the window are scheduled instead.
+func (s *NewsletterService) sendEdition(ctx context.Context, newsletter *entity.Newsletter, content, trigger string) (*entity.NewsletterEdition, error) {
+	channel, err := s.channelRepo.FindByID(ctx, newsletter.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	if !channel.IsActive() {
+		return nil, errors.New(errors.ErrCodeChannelDisconnected, "channel is not active")
+	}
+
+	contactIDs, err := s.repo.ListSubscribedContactIDs(ctx, newsletter.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var segmentContacts map[string]bool
+	if newsletter.SegmentID != "" && s.segments != nil {
+		if segmentContacts, err = s.segments.ContactIDs(ctx, newsletter.TenantID, newsletter.SegmentID); err != nil {
+			return nil, err
+		}
+	}
+
+	edition := &entity.NewsletterEdition{
+		ID:           uuid.New().String(),
+		TenantID:     newsletter.TenantID,
+		NewsletterID: newsletter.ID,
+		ContentType:  newsletter.ContentType,
+		Content:      content,
+		Trigger:      trigger,
+		Status:       entity.NewsletterEditionSending,
+		SmartSend:    newsletter.UsesSmartSend(),
+		StartedAt:    time.Now(),
+	}
+	if newsletter.Image != nil {
+		edition.Renders = &entity.NewsletterRenderStats{}
+	}
+	if err := s.repo.CreateEdition(ctx, edition); err != nil {
+		return nil, err
+	}
+
+	limits := s.marketingLimits(ctx, newsletter.TenantID)
+	inactiveWindow := limits.inactiveWindow
+	if newsletter.SuppressInactiveDays > 0 {
+		inactiveWindow = time.Duration(newsletter.SuppressInactiveDays) * 24 * time.Hour
+	}
+
+	images := make(map[string]string)
+	for _, contactID := range contactIDs {
+		if segmentContacts != nil && !segmentContacts[contactID] {
+			continue
+		}
+		contact, err := s.contactRepo.FindByID(ctx, contactID)
+		if err != nil || contact.IsBlocked() || contact.IsOptedOut(string(channel.Type)) || !newsletter.MatchesSegment(contact) {
+			continue
+		}
+		edition.Recipients++
+
+		delivery := &entity.NewsletterDelivery{
+			ID:           uuid.New().String(),
+			TenantID:     newsletter.TenantID,
+			NewsletterID: newsletter.ID,
+			EditionID:    edition.ID,
+			ContactID:    contact.ID,
+			Timing:       entity.NewsletterTimingFixed,
+			CreatedAt:    time.Now(),
+		}
+
+		if err := s.checkRecipient(ctx, channel, contact); err != nil {
+			delivery.Status = entity.NewsletterDeliveryFailed
+			delivery.Error = err.Error()
+			edition.Failed++
+		}
+		if delivery.Status == "" && s.isInactive(ctx, contact, edition.StartedAt.Add(-inactiveWindow), inactiveWindow) {
+			delivery.Status = entity.NewsletterDeliverySuppressed
+			edition.Suppressed++
+		}
+
+		if edition.SmartSend && delivery.Status == "" {
+			sendAt, timing := s.sendTime(ctx, newsletter.SmartSend, edition, contact.ID)
+			delivery.Timing = timing
+			if sendAt.After(delivery.CreatedAt) {
+				delivery.Status = entity.NewsletterDeliveryScheduled
+				delivery.ScheduledAt = &sendAt
+				edition.Scheduled++
+			}
+		}
+		if delivery.Status == "" {
+			s.attemptDelivery(ctx, newsletter, edition, contact, delivery, limits, images)
+		}
+
+		if err := s.repo.CreateDelivery(ctx, delivery); err != nil {
+			logger.Warn("Failed to record newsletter delivery",
+				zap.String("edition_id", edition.ID),
+				zap.String("contact_id", contact.ID),
+				zap.Error(err),
+			)
+		}
+	}
+
+	if edition.Scheduled > 0 {
+		if err := s.repo.UpdateEdition(ctx, edition); err != nil {
+			return nil, err
+		}
+		return edition, nil
+	}
+	if err := s.completeEdition(ctx, newsletter, edition); err != nil {
+		return nil, err
+	}
+	return edition, nil
+}
+
+// checkRecipient validates the identifier the contact would be reached by on the channel
+func (s *NewsletterService) checkRecipient(ctx context.Context, channel *entity.Channel, contact *entity.Contact) error {
+	if s.identifiers == nil {
+		return nil
+	}
+	if identities, err := s.contactRepo.FindIdentitiesByContact(ctx, contact.ID); err == nil {
+		contact.Identities = identities
+	}
+	_, err := s.identifiers.NormalizeRecipient(ctx, channel, findRecipientForChannel(contact, string(channel.Type)))
+	return err
+}
+
+// marketingLimits are the throttles a tenant applies to all of its campaigns
+type marketingLimits struct {
+	frequencyCap   int           // Max marketing messages per contact across channels, none when zero
+	capWindow      time.Duration // Window of the frequency cap
+	inactiveWindow time.Duration // Suppress contacts without engagement in this long, none when zero
+}
+
+// marketingLimits loads the marketing throttles of a tenant, none when it cannot be loaded
+func (s *NewsletterService) marketingLimits(ctx context.Context, tenantID string) marketingLimits {
+	if s.tenantRepo == nil {
+		return marketingLimits{}
+	}
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		logger.Warn("Failed to load tenant marketing limits",
+			zap.String("tenant_id", tenantID),
+			zap.Error(err),
+		)
+		return marketingLimits{}
+	}
+	limits := marketingLimits{inactiveWindow: tenant.MarketingInactiveWindow()}
+	limits.frequencyCap, limits.capWindow = tenant.MarketingFrequencyCap()
+	return limits
+}
+
+// isInactive checks if a contact neither read nor replied to a message since the
+// cutoff. Contacts created within the window are given the chance to engage first.
+func (s *NewsletterService) isInactive(ctx context.Context, contact *entity.Contact, cutoff time.Time, window time.Duration) bool {
+	if window <= 0 || !contact.CreatedAt.Before(cutoff) {
+		return false
+	}
+	last, err := s.repo.FindLastEngagement(ctx, contact.ID)
+	if err != nil {
+		logger.Warn("Failed to get last contact engagement",
+			zap.String("contact_id", contact.ID),
+			zap.Error(err),
+		)
+		return false
+	}
+	return last == nil || last.Before(cutoff)
+}
+
+// sendTime picks when a smart send edition goes to a contact: at the hour within the
+// window in which they read and reply the most, or at the start of the edition for
+// the control group and contacts without enough history
+func (s *NewsletterService) sendTime(ctx context.Context, settings *entity.NewsletterSmartSend, edition *entity.NewsletterEdition, contactID string) (time.Time, entity.NewsletterSendTiming) {
+	if inSmartSendControlGroup(edition.ID, contactID, settings.ControlPercent) {
+		return edition.StartedAt, entity.NewsletterTimingFixed
+	}
+
+	engagement, err := s.repo.GetContactEngagement(ctx, contactID, edition.StartedAt.Add(-smartSendHistory))
+	if err != nil {
+		logger.Warn("Failed to get contact engagement for smart send",
+			zap.String("contact_id", contactID),
+			zap.Error(err),
+		)
+		return edition.StartedAt, entity.NewsletterTimingFixed
+	}
+	if engagement.Total() < settings.MinEngagements {
+		return edition.StartedAt, entity.NewsletterTimingFixed
+	}
+
+	window := time.Duration(settings.WindowHours) * time.Hour
+	return engagement.BestSendTime(edition.StartedAt, window), entity.NewsletterTimingSmart
+}
+
+// attemptDelivery sends an edition to a contact unless they reached the newsletter
+// or tenant frequency cap, recording the outcome on the delivery and the edition
+// counters. Images holds the personalized images rendered for the edition so far.
+func (s *NewsletterService) attemptDelivery(ctx context.Context, newsletter *entity.Newsletter, edition *entity.NewsletterEdition, contact *entity.Contact, delivery *entity.NewsletterDelivery, limits marketingLimits, images map[string]string) {
+	capWindow := time.Duration(newsletter.FrequencyCapHours) * time.Hour
+	count, err := s.repo.CountRecentDeliveries(ctx, newsletter.TenantID, contact.ID, delivery.CreatedAt.Add(-capWindow))
+	globalCount := 0
+	if err == nil && count < newsletter.FrequencyCap && limits.frequencyCap > 0 {
+		globalCount, err = s.repo.CountRecentMarketingMessages(ctx, newsletter.TenantID, contact.ID, delivery.CreatedAt.Add(-limits.capWindow))
+	}
+	switch {
+	case err != nil:
+		delivery.Status = entity.NewsletterDeliveryFailed
+		delivery.Error = err.Error()
+		edition.Failed++
+	case count >= newsletter.FrequencyCap:
+		delivery.Status = entity.NewsletterDeliveryCapped
+		edition.Capped++
+	case limits.frequencyCap > 0 && globalCount >= limits.frequencyCap:
+		delivery.Status = entity.NewsletterDeliveryCapped
+		edition.Capped++
+		edition.GlobalCapped++
+	default:
+		message, err := s.deliver(ctx, newsletter, edition, contact, images)
+		if err != nil {
+			delivery.Status = entity.NewsletterDeliveryFailed
+			delivery.Error = err.Error()
+			edition.Failed++
+		} else {
+			delivery.Status = entity.NewsletterDeliverySent
+			delivery.ConversationID = message.ConversationID
+			delivery.MessageID = message.ID
+			edition.Sent++
+		}
+	}
+}
+
+// runScheduledDeliveries sends the smart send deliveries that are due, completing
+// the editions that have nothing left to send. Deliveries on channels under
+// maintenance stay due until the window ends.
+func (s *NewsletterService) runScheduledDeliveries(ctx context.Context, now time.Time) {
+	deliveries, err := s.repo.ListDueDeliveries(ctx, now)
+	if err != nil {
+		logger.Warn("Failed to list scheduled newsletter deliveries", zap.Error(err))
+		return
+	}
+
+	held := make(map[string]bool)
+	newsletters := make(map[string]*entity.Newsletter)
+	limits := make(map[string]marketingLimits)
+	editions := make(map[string]*entity.NewsletterEdition)
+	images := make(map[string]map[string]string)
+	for _, delivery := range deliveries {
+		newsletter, ok := newsletters[delivery.NewsletterID]
+		if !ok {
+			if newsletter, err = s.repo.FindByID(ctx, delivery.NewsletterID); err != nil {
+				continue
+			}
+			newsletters[newsletter.ID] = newsletter
+			held[newsletter.ID] = s.maintenanceWindow(ctx, newsletter.ChannelID) != nil
+		}
+		if held[newsletter.ID] {
+			continue
+		}
+		if _, ok := limits[newsletter.TenantID]; !ok {
+			limits[newsletter.TenantID] = s.marketingLimits(ctx, newsletter.TenantID)
+		}
+		edition, ok := editions[delivery.EditionID]
+		if !ok {
+			if edition, err = s.repo.FindEditionByID(ctx, delivery.EditionID); err != nil {
+				continue
+			}
+			editions[edition.ID] = edition
+			images[edition.ID] = make(map[string]string)
+		}
+
+		delivery.CreatedAt = time.Now()
+		contact, err := s.contactRepo.FindByID(ctx, delivery.ContactID)
+		if err != nil || contact.IsBlocked() {
+			delivery.Status = entity.NewsletterDeliveryFailed
+			delivery.Error = "contact is no longer reachable"
+			edition.Failed++
+		} else {
+			s.attemptDelivery(ctx, newsletter, edition, contact, delivery, limits[newsletter.TenantID], images[edition.ID])
+		}
+		edition.Scheduled--
+
+		if err := s.repo.UpdateDelivery(ctx, delivery); err != nil {
+			logger.Warn("Failed to record newsletter delivery",
+				zap.String("edition_id", edition.ID),
+				zap.String("contact_id", delivery.ContactID),
+				zap.Error(err),
+			)
+		}
+	}
+
+	for _, edition := range editions {
+		if edition.Scheduled > 0 {
+			err = s.repo.UpdateEdition(ctx, edition)
+		} else {
+			err = s.completeEdition(ctx, newsletters[edition.NewsletterID], edition)
+		}
+		if err != nil {
+			logger.Warn("Failed to update newsletter edition",
+				zap.String("edition_id", edition.ID),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// maintenanceWindow returns the window a newsletter's channel is under maintenance by
+func (s *NewsletterService) maintenanceWindow(ctx context.Context, channelID string) *entity.ChannelMaintenanceWindow {
+	if s.maintenance == nil {
+		return nil
+	}
+	return s.maintenance.ActiveWindow(ctx, channelID)
+}
+
+// completeEdition marks an edition as sent once every delivery went out
+func (s *NewsletterService) completeEdition(ctx context.Context, newsletter *entity.Newsletter, edition *entity.NewsletterEdition) error {
+	completedAt := time.Now()
+	edition.Status = entity.NewsletterEditionSent
+	edition.Scheduled = 0
+	edition.CompletedAt = &completedAt
+	if err := s.repo.UpdateEdition(ctx, edition); err != nil {
+		return err
+	}
+
+	s.publish(ctx, EventNewsletterEditionSent, newsletter.TenantID, map[string]interface{}{
+		"newsletter_id": newsletter.ID,
+		"edition_id":    edition.ID,
+		"recipients":    edition.Recipients,
+		"sent":          edition.Sent,
+		"capped":        edition.Capped,
+		"global_capped": edition.GlobalCapped,
+		"suppressed":    edition.Suppressed,
+		"failed":        edition.Failed,
+	})
+	return nil
+}
+
+// inSmartSendControlGroup checks if a contact is in the share of an edition's
+// recipients sent at the fixed time. The split is stable for an edition and
+// varies between editions.
+func inSmartSendControlGroup(editionID, contactID string, percent int) bool {
+	h := fnv.New32a()
+	h.Write([]byte(editionID + ":" + contactID))
+	return int(h.Sum32()%100) < percent
+}
+
+// deliver sends an edition to a contact in their open conversation on the
+// newsletter channel, starting one when there is none. Newsletters with an image
+// send the contact's personalized image with the content as its caption.
+func (s *NewsletterService) deliver(ctx context.Context, newsletter *entity.Newsletter, edition *entity.NewsletterEdition, contact *entity.Contact, images map[string]string) (*entity.Message, error) {
+	conversation, err := s.conversationRepo.FindOpenByContactAndChannel(ctx, contact.ID, newsletter.ChannelID)
+	if err != nil || conversation == nil {
+		now := time.Now()
+		conversation = &entity.Conversation{
+			ID:        uuid.New().String(),
+			TenantID:  newsletter.TenantID,
+			ChannelID: newsletter.ChannelID,
+			ContactID: contact.ID,
+			Status:    entity.ConversationStatusOpen,
+			Priority:  entity.ConversationPriorityNormal,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := s.conversationRepo.Create(ctx, conversation); err != nil {
+			return nil, err
+		}
+	}
+
+	content := edition.Content
+	if s.variables != nil {
+		if vars, err := s.variables.ResolveVariables(ctx, conversation.ID); err == nil {
+			content, _ = RenderTemplate(content, vars)
+		}
+	}
+
+	// campaign_id makes the send count as marketing traffic for costs and quality pauses
+	metadata := make(map[string]string, len(newsletter.Metadata)+3)
+	for key, value := range newsletter.Metadata {
+		metadata[key] = value
+	}
+	metadata["campaign_id"] = newsletter.ID
+	metadata["newsletter_id"] = newsletter.ID
+	metadata["newsletter_edition_id"] = edition.ID
+
+	contentType := string(edition.ContentType)
+	if newsletter.Image != nil {
+		imageURL, err := s.personalizedImage(ctx, newsletter, edition, conversation.ID, images)
+		if err != nil {
+			return nil, err
+		}
+		contentType = string(entity.ContentTypeImage)
+		metadata["media_url"] = imageURL
+	}
+
+	return s.messageService.Send(ctx, &SendMessageInput{
+		TenantID:       conversation.TenantID,
+		ConversationID: conversation.ID,
+		SenderType:     string(entity.SenderTypeSystem),
+		ContentType:    contentType,
+		Content:        content,
+		Metadata:       metadata,
+	})
+}
+
+// personalizedImage returns the URL of a newsletter image rendered with the variables
+// of a conversation. Recipients whose data renders the same reuse the image already
+// rendered for the edition; the edition render stats count both.
+func (s *NewsletterService) personalizedImage(ctx context.Context, newsletter *entity.Newsletter, edition *entity.NewsletterEdition, conversationID string, images map[string]string) (string, error) {
+	if edition.Renders == nil {
+		edition.Renders = &entity.NewsletterRenderStats{}
+	}
+
+	data := newsletter.Image.Data
+	if s.variables != nil && len(data) > 0 {
+		if rendered, err := s.variables.RenderData(ctx, newsletter.TenantID, conversationID, data); err == nil {
+			data = rendered
+		}
+	}
+	req := &entity.RenderRequest{
+		TenantID:   newsletter.TenantID,
+		TemplateID: newsletter.Image.TemplateID,
+		Data:       data,
+		Format:     newsletter.Image.Format,
+		Width:      newsletter.Image.Width,
+	}
+
+	key, err := newsletterImageKey(req)
+	if err != nil {
+		edition.Renders.Failed++
+		return "", err
+	}
+	if imageURL, ok := images[key]; ok {
+		edition.Renders.CacheHits++
+		return imageURL, nil
+	}
+
+	if s.renderer == nil {
+		edition.Renders.Failed++
+		return "", errors.New(errors.ErrCodeBadRequest, "image personalization is not available")
+	}
+	started := time.Now()
+	response, err := s.renderer.RenderToURL(ctx, req)
+	if err != nil {
+		edition.Renders.Failed++
+		return "", errors.Wrap(err, errors.ErrCodeInternal, "failed to render newsletter image")
+	}
+	if response.ImageURL == "" {
+		edition.Renders.Failed++
+		return "", errors.New(errors.ErrCodeBadRequest, "newsletter images require VRE storage to be configured")
+	}
+	edition.Renders.Rendered++
+	edition.Renders.RenderTimeMs += time.Since(started).Milliseconds()
+
+	images[key] = response.ImageURL
+	return response.ImageURL, nil
+}
+
+// newsletterImageKey identifies the image of a render request by its template,
+// options and data
+func newsletterImageKey(req *entity.RenderRequest) (string, error) {
+	// Map keys are encoded sorted, so identical data gives identical keys
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return "", errors.Wrap(err, errors.ErrCodeInternal, "failed to encode newsletter image data")
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// RenderStats returns the personalized image rendering of a newsletter across its editions
+func (s *NewsletterService) RenderStats(ctx context.Context, tenantID, newsletterID string) (*entity.NewsletterRenderSummary, error) {
+	if _, err := s.Get(ctx, tenantID, newsletterID); err != nil {
+		return nil, err
+	}
+	summary, err := s.repo.GetRenderStats(ctx, newsletterID)
+	if err != nil {
+		return nil, err
+	}
+	if images := summary.Rendered + summary.CacheHits; images > 0 {
+		summary.CacheHitRate = float64(summary.CacheHits) / float64(images)
+	}
+	if summary.Rendered > 0 {
+		summary.AvgRenderTimeMs = float64(summary.RenderTimeMs) / float64(summary.Rendered)
+	}
+	return summary, nil
+}
+
+// ListEditions returns the editions of a newsletter, newest first
+func (s *NewsletterService) ListEditions(ctx context.Context, tenantID, newsletterID string, params *repository.ListParams) ([]*entity.NewsletterEdition, int64, error) {
+	if _, err := s.Get(ctx, tenantID, newsletterID); err != nil {
+		return nil, 0, err
+	}
+	return s.repo.ListEditions(ctx, newsletterID, params)
+}
+
+// GetEdition returns an edition with its engagement analytics. Smart send editions
+// also compare the engagement of smart-timed sends with the sends at the fixed time.
+func (s *NewsletterService) GetEdition(ctx context.Context, tenantID, newsletterID, editionID string) (*entity.NewsletterEdition, error) {
+	edition, err := s.repo.FindEditionByID(ctx, editionID)
+	if err != nil {
+		return nil, err
+	}
+	if edition.TenantID != tenantID || edition.NewsletterID != newsletterID {
+		return nil, errors.New(errors.ErrCodeNotFound, "newsletter edition not found")
+	}
+
+	stats, err := s.editionStats(ctx, edition.ID, "")
+	if err != nil {
+		return nil, err
+	}
+	edition.Stats = stats
+
+	if edition.SmartSend {
+		smart, err := s.editionStats(ctx, edition.ID, entity.NewsletterTimingSmart)
+		if err != nil {
+			return nil, err
+		}
+		fixed, err := s.editionStats(ctx, edition.ID, entity.NewsletterTimingFixed)
+		if err != nil {
+			return nil, err
+		}
+		edition.SendTimeComparison = &entity.NewsletterSendTimeComparison{
+			Smart:         smart,
+			Fixed:         fixed,
+			ReadRateLift:  smart.ReadRate - fixed.ReadRate,
+			ReplyRateLift: smart.ReplyRate - fixed.ReplyRate,
+		}
+	}
+	return edition, nil
+}
+
+// editionStats aggregates the engagement of an edition's deliveries and their rates
+func (s *NewsletterService) editionStats(ctx context.Context, editionID string, timing entity.NewsletterSendTiming) (*entity.NewsletterEditionStats, error) {
+	stats, err := s.repo.GetEditionStats(ctx, editionID, timing)
+	if err != nil {
+		return nil, err
+	}
+	if stats.Sent > 0 {
+		sent := float64(stats.Sent)
+		stats.DeliveryRate = float64(stats.Delivered) / sent
+		stats.ReadRate = float64(stats.Read) / sent
+		stats.ReplyRate = float64(stats.Replied) / sent
+		stats.UnsubscribeRate = float64(stats.Unsubscribed) / sent
+	}
+	return stats, nil
+}
+
+// HandleInbound handles the newsletter keywords of an inbound message: an opt-in
+// keyword subscribes the contact, an unsubscribe keyword removes them from the
+// channel's newsletters. Any other message counts as a reply to a recent edition.
+func (s *NewsletterService) HandleInbound(ctx context.Context, message *entity.Message, conversation *entity.Conversation) error {
+	if message.SenderType != entity.SenderTypeContact {
+		return nil
+	}
+
+	newsletters, err := s.repo.ListByChannel(ctx, conversation.ChannelID)
+	if err != nil {
+		return err
+	}
+	if len(newsletters) == 0 {
+		return nil
+	}
+
+	if keyword := normalizeKeyword(message.Content); keyword != "" && message.ContentType == entity.ContentTypeText {
+		handled, err := s.handleKeyword(ctx, newsletters, conversation, keyword)
+		if err != nil {
+			logger.Warn("Failed to handle newsletter keyword",
+				zap.String("conversation_id", conversation.ID),
+				zap.Error(err),
+			)
+			return err
+		}
+		if handled {
+			return nil
+		}
+	}
+
+	now := time.Now()
+	return s.repo.MarkReplied(ctx, conversation.ID, now.Add(-newsletterReplyWindow), now)
+}
+
+func (s *NewsletterService) handleKeyword(ctx context.Context, newsletters []*entity.Newsletter, conversation *entity.Conversation, keyword string) (bool, error) {
+	for _, newsletter := range newsletters {
+		if newsletter.IsActive && newsletter.OptInKeyword != "" && newsletter.OptInKeyword == keyword {
+			if err := s.subscribe(ctx, newsletter, conversation.ContactID, "keyword"); err != nil {
+				return false, err
+			}
+			if newsletter.WelcomeMessage != "" {
+				s.reply(ctx, conversation, newsletter.WelcomeMessage)
+			}
+			return true, nil
+		}
+	}
+
+	confirmation := ""
+	unsubscribed := false
+	isDefault := isDefaultUnsubscribeKeyword(keyword)
+	for _, newsletter := range newsletters {
+		if !isDefault && !containsKeyword(newsletter.UnsubscribeKeywords, keyword) {
+			continue
+		}
+		subscription, err := s.repo.FindSubscription(ctx, newsletter.ID, conversation.ContactID)
+		if err != nil || subscription.Status != entity.NewsletterSubscribed {
+			continue
+		}
+		if err := s.unsubscribe(ctx, newsletter, subscription, "keyword"); err != nil {
+			return false, err
+		}
+		unsubscribed = true
+		if confirmation == "" {
+			confirmation = newsletter.UnsubscribeMessage
+		}
+	}
+
+	if !unsubscribed {
+		return false, nil
+	}
+	if confirmation == "" {
+		confirmation = defaultUnsubscribeMessage
+	}
+	s.reply(ctx, conversation, confirmation)
+	return true, nil
+}
+
+// reply sends a confirmation to a contact; failures are only logged
+func (s *NewsletterService) reply(ctx context.Context, conversation *entity.Conversation, content string) {
+	_, err := s.messageService.Send(ctx, &SendMessageInput{
+		TenantID:       conversation.TenantID,
+		ConversationID: conversation.ID,
+		SenderType:     string(entity.SenderTypeSystem),
+		ContentType:    string(entity.ContentTypeText),
+		Content:        content,
+	})
+	if err != nil {
+		logger.Warn("Failed to send newsletter confirmation",
+			zap.String("conversation_id", conversation.ID),
+			zap.Error(err),
+		)
+	}
+}
+
+func (s *NewsletterService) publish(ctx context.Context, eventType, tenantID string, payload map[string]interface{}) {
+	if s.producer == nil {
+		return
+	}
+	s.producer.PublishEvent(ctx, &nats.Event{
+		Type:      eventType,
+		TenantID:  tenantID,
+		Payload:   payload,
+		Timestamp: time.Now(),
+	})
+}
+
+// normalizeKeyword upper-cases a keyword and strips surrounding spaces and punctuation
+func normalizeKeyword(text string) string {
+	return strings.ToUpper(strings.Trim(strings.TrimSpace(text), ".!"))
+}
+
+func isDefaultUnsubscribeKeyword(keyword string) bool {
+	return containsKeyword(DefaultUnsubscribeKeywords, keyword)
+}
+
+func containsKeyword(keywords []string, keyword string) bool {
+	for _, k := range keywords {
+		if k == keyword {
+			return true
+		}
+	}
+	return false
+}
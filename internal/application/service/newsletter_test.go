@@ -0,0 +1,748 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockNewsletterRepo struct {
+	newsletters   map[string]*entity.Newsletter
+	subscriptions map[string]*entity.NewsletterSubscription // keyed by newsletter ID + contact ID
+	editions      map[string]*entity.NewsletterEdition
+	deliveries    []*entity.NewsletterDelivery
+	engagement    map[string]*entity.ContactEngagement
+	marketing     map[string]int       // Marketing messages per contact sent outside newsletters
+	lastEngaged   map[string]time.Time // Last read or reply per contact
+}
+
+func newMockNewsletterRepo() *mockNewsletterRepo {
+	return &mockNewsletterRepo{
+		newsletters:   make(map[string]*entity.Newsletter),
+		subscriptions: make(map[string]*entity.NewsletterSubscription),
+		editions:      make(map[string]*entity.NewsletterEdition),
+		engagement:    make(map[string]*entity.ContactEngagement),
+		marketing:     make(map[string]int),
+		lastEngaged:   make(map[string]time.Time),
+	}
+}
+
+func (m *mockNewsletterRepo) Create(ctx context.Context, newsletter *entity.Newsletter) error {
+	m.newsletters[newsletter.ID] = newsletter
+	return nil
+}
+
+func (m *mockNewsletterRepo) FindByID(ctx context.Context, id string) (*entity.Newsletter, error) {
+	if newsletter, ok := m.newsletters[id]; ok {
+		return newsletter, nil
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "newsletter not found")
+}
+
+func (m *mockNewsletterRepo) ListByTenant(ctx context.Context, tenantID string) ([]*entity.Newsletter, error) {
+	var result []*entity.Newsletter
+	for _, newsletter := range m.newsletters {
+		if newsletter.TenantID == tenantID {
+			result = append(result, newsletter)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockNewsletterRepo) ListByChannel(ctx context.Context, channelID string) ([]*entity.Newsletter, error) {
+	var result []*entity.Newsletter
+	for _, newsletter := range m.newsletters {
+		if newsletter.ChannelID == channelID {
+			result = append(result, newsletter)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockNewsletterRepo) ListDue(ctx context.Context, now time.Time) ([]*entity.Newsletter, error) {
+	var result []*entity.Newsletter
+	for _, newsletter := range m.newsletters {
+		if newsletter.IsActive && newsletter.NextRunAt != nil && !newsletter.NextRunAt.After(now) {
+			result = append(result, newsletter)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockNewsletterRepo) Update(ctx context.Context, newsletter *entity.Newsletter) error {
+	m.newsletters[newsletter.ID] = newsletter
+	return nil
+}
+
+func (m *mockNewsletterRepo) Delete(ctx context.Context, id string) error {
+	delete(m.newsletters, id)
+	return nil
+}
+
+func (m *mockNewsletterRepo) UpsertSubscription(ctx context.Context, subscription *entity.NewsletterSubscription) error {
+	m.subscriptions[subscription.NewsletterID+subscription.ContactID] = subscription
+	return nil
+}
+
+func (m *mockNewsletterRepo) FindSubscription(ctx context.Context, newsletterID, contactID string) (*entity.NewsletterSubscription, error) {
+	if subscription, ok := m.subscriptions[newsletterID+contactID]; ok {
+		return subscription, nil
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "newsletter subscription not found")
+}
+
+func (m *mockNewsletterRepo) ListSubscriptions(ctx context.Context, newsletterID string, status entity.NewsletterSubscriptionStatus, params *repository.ListParams) ([]*entity.NewsletterSubscription, int64, error) {
+	var result []*entity.NewsletterSubscription
+	for _, subscription := range m.subscriptions {
+		if subscription.NewsletterID == newsletterID && (status == "" || subscription.Status == status) {
+			result = append(result, subscription)
+		}
+	}
+	return result, int64(len(result)), nil
+}
+
+func (m *mockNewsletterRepo) ListSubscribedContactIDs(ctx context.Context, newsletterID string) ([]string, error) {
+	var result []string
+	for _, subscription := range m.subscriptions {
+		if subscription.NewsletterID == newsletterID && subscription.Status == entity.NewsletterSubscribed {
+			result = append(result, subscription.ContactID)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockNewsletterRepo) CreateEdition(ctx context.Context, edition *entity.NewsletterEdition) error {
+	m.editions[edition.ID] = edition
+	return nil
+}
+
+func (m *mockNewsletterRepo) UpdateEdition(ctx context.Context, edition *entity.NewsletterEdition) error {
+	m.editions[edition.ID] = edition
+	return nil
+}
+
+func (m *mockNewsletterRepo) FindEditionByID(ctx context.Context, id string) (*entity.NewsletterEdition, error) {
+	if edition, ok := m.editions[id]; ok {
+		return edition, nil
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "newsletter edition not found")
+}
+
+func (m *mockNewsletterRepo) ListEditions(ctx context.Context, newsletterID string, params *repository.ListParams) ([]*entity.NewsletterEdition, int64, error) {
+	var result []*entity.NewsletterEdition
+	for _, edition := range m.editions {
+		if edition.NewsletterID == newsletterID {
+			result = append(result, edition)
+		}
+	}
+	return result, int64(len(result)), nil
+}
+
+func (m *mockNewsletterRepo) CreateDelivery(ctx context.Context, delivery *entity.NewsletterDelivery) error {
+	m.deliveries = append(m.deliveries, delivery)
+	return nil
+}
+
+func (m *mockNewsletterRepo) UpdateDelivery(ctx context.Context, delivery *entity.NewsletterDelivery) error {
+	return nil
+}
+
+func (m *mockNewsletterRepo) ListDueDeliveries(ctx context.Context, now time.Time) ([]*entity.NewsletterDelivery, error) {
+	var result []*entity.NewsletterDelivery
+	for _, delivery := range m.deliveries {
+		if delivery.Status == entity.NewsletterDeliveryScheduled && !delivery.ScheduledAt.After(now) {
+			result = append(result, delivery)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockNewsletterRepo) CountRecentDeliveries(ctx context.Context, tenantID, contactID string, since time.Time) (int, error) {
+	count := 0
+	for _, delivery := range m.deliveries {
+		if delivery.TenantID == tenantID && delivery.ContactID == contactID &&
+			delivery.Status == entity.NewsletterDeliverySent && !delivery.CreatedAt.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *mockNewsletterRepo) CountRecentMarketingMessages(ctx context.Context, tenantID, contactID string, since time.Time) (int, error) {
+	count, err := m.CountRecentDeliveries(ctx, tenantID, contactID, since)
+	return count + m.marketing[contactID], err
+}
+
+func (m *mockNewsletterRepo) FindLastEngagement(ctx context.Context, contactID string) (*time.Time, error) {
+	if last, ok := m.lastEngaged[contactID]; ok {
+		return &last, nil
+	}
+	return nil, nil
+}
+
+func (m *mockNewsletterRepo) MarkReplied(ctx context.Context, conversationID string, since, at time.Time) error {
+	for i := len(m.deliveries) - 1; i >= 0; i-- {
+		delivery := m.deliveries[i]
+		if delivery.ConversationID == conversationID && delivery.Status == entity.NewsletterDeliverySent && !delivery.CreatedAt.Before(since) {
+			if delivery.RepliedAt == nil {
+				delivery.RepliedAt = &at
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+func (m *mockNewsletterRepo) MarkUnsubscribed(ctx context.Context, newsletterID, contactID string, at time.Time) error {
+	for i := len(m.deliveries) - 1; i >= 0; i-- {
+		delivery := m.deliveries[i]
+		if delivery.NewsletterID == newsletterID && delivery.ContactID == contactID && delivery.Status == entity.NewsletterDeliverySent {
+			if delivery.UnsubscribedAt == nil {
+				delivery.UnsubscribedAt = &at
+			}
+			return nil
+		}
+	}
+	return nil
+}
+
+func (m *mockNewsletterRepo) GetEditionStats(ctx context.Context, editionID string, timing entity.NewsletterSendTiming) (*entity.NewsletterEditionStats, error) {
+	stats := &entity.NewsletterEditionStats{}
+	for _, delivery := range m.deliveries {
+		if delivery.EditionID != editionID || (timing != "" && delivery.Timing != timing) {
+			continue
+		}
+		switch delivery.Status {
+		case entity.NewsletterDeliverySent:
+			stats.Sent++
+		case entity.NewsletterDeliveryCapped:
+			stats.Capped++
+		case entity.NewsletterDeliverySuppressed:
+			stats.Suppressed++
+		case entity.NewsletterDeliveryFailed:
+			stats.Failed++
+		}
+		if delivery.RepliedAt != nil {
+			stats.Replied++
+		}
+		if delivery.UnsubscribedAt != nil {
+			stats.Unsubscribed++
+		}
+	}
+	return stats, nil
+}
+
+func (m *mockNewsletterRepo) GetRenderStats(ctx context.Context, newsletterID string) (*entity.NewsletterRenderSummary, error) {
+	summary := &entity.NewsletterRenderSummary{}
+	for _, edition := range m.editions {
+		if edition.NewsletterID == newsletterID && edition.Renders != nil {
+			summary.Editions++
+			summary.Add(edition.Renders)
+		}
+	}
+	return summary, nil
+}
+
+func (m *mockNewsletterRepo) GetContactEngagement(ctx context.Context, contactID string, since time.Time) (*entity.ContactEngagement, error) {
+	if engagement, ok := m.engagement[contactID]; ok {
+		return engagement, nil
+	}
+	return &entity.ContactEngagement{ContactID: contactID}, nil
+}
+
+type newsletterFixture struct {
+	service       *NewsletterService
+	repo          *mockNewsletterRepo
+	contacts      *testutil.MockContactRepository
+	conversations *testutil.MockConversationRepository
+	messages      *testutil.MockMessageRepository
+	producer      *testutil.MockProducer
+}
+
+func newNewsletterFixture() *newsletterFixture {
+	repo := newMockNewsletterRepo()
+	contacts := testutil.NewMockContactRepository()
+	conversations := testutil.NewMockConversationRepository()
+	messages := testutil.NewMockMessageRepository()
+	channels := testutil.NewMockChannelRepository()
+	producer := testutil.NewMockProducer()
+
+	channels.Channels["ch-1"] = &entity.Channel{
+		ID:               "ch-1",
+		TenantID:         "tenant-1",
+		Type:             entity.ChannelTypeTelegram,
+		Enabled:          true,
+		ConnectionStatus: entity.ConnectionStatusConnected,
+	}
+	channels.Channels["ch-sms"] = &entity.Channel{ID: "ch-sms", TenantID: "tenant-1", Type: entity.ChannelTypeSMS}
+
+	for _, contact := range []*entity.Contact{
+		{ID: "alice", TenantID: "tenant-1", Name: "Alice", Tags: []string{"vip"}},
+		{ID: "bob", TenantID: "tenant-1", Name: "Bob"},
+		{ID: "carol", TenantID: "tenant-1", Name: "Carol", Tags: []string{"vip"}},
+		{ID: "mallory", TenantID: "tenant-2", Name: "Mallory"},
+	} {
+		contacts.Contacts[contact.ID] = contact
+	}
+
+	messageService := NewMessageService(messages, conversations, channels, contacts, producer)
+	return &newsletterFixture{
+		service:       NewNewsletterService(repo, contacts, conversations, channels, messageService, producer),
+		repo:          repo,
+		contacts:      contacts,
+		conversations: conversations,
+		messages:      messages,
+		producer:      producer,
+	}
+}
+
+func weeklyNewsletterInput() *NewsletterInput {
+	return &NewsletterInput{
+		Name:         "Weekly deals",
+		ChannelID:    "ch-1",
+		Content:      "This week's deals",
+		Schedule:     entity.NewsletterSchedule{Frequency: entity.NewsletterWeekly, Time: "09:00", DayOfWeek: 1},
+		OptInKeyword: "join deals",
+	}
+}
+
+func TestNewsletterSchedule_Next(t *testing.T) {
+	sao, err := time.LoadLocation("America/Sao_Paulo")
+	require.NoError(t, err)
+	after := time.Date(2026, 10, 16, 12, 0, 0, 0, sao) // Friday
+
+	tests := []struct {
+		name     string
+		schedule entity.NewsletterSchedule
+		want     time.Time
+	}{
+		{"daily later today", entity.NewsletterSchedule{Frequency: entity.NewsletterDaily, Time: "18:30", Timezone: "America/Sao_Paulo"}, time.Date(2026, 10, 16, 18, 30, 0, 0, sao)},
+		{"daily tomorrow", entity.NewsletterSchedule{Frequency: entity.NewsletterDaily, Time: "08:00", Timezone: "America/Sao_Paulo"}, time.Date(2026, 10, 17, 8, 0, 0, 0, sao)},
+		{"weekly monday", entity.NewsletterSchedule{Frequency: entity.NewsletterWeekly, Time: "09:00", DayOfWeek: 1, Timezone: "America/Sao_Paulo"}, time.Date(2026, 10, 19, 9, 0, 0, 0, sao)},
+		{"monthly next month", entity.NewsletterSchedule{Frequency: entity.NewsletterMonthly, Time: "10:00", DayOfMonth: 1, Timezone: "America/Sao_Paulo"}, time.Date(2026, 11, 1, 10, 0, 0, 0, sao)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, err := tt.schedule.Next(after)
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equal(next), "got %s, want %s", next, tt.want)
+		})
+	}
+
+	_, err = (&entity.NewsletterSchedule{Frequency: entity.NewsletterMonthly, Time: "10:00", DayOfMonth: 31}).Next(after)
+	assert.Error(t, err)
+}
+
+func TestNewsletterService_CreateValidation(t *testing.T) {
+	f := newNewsletterFixture()
+	ctx := context.Background()
+
+	newsletter, err := f.service.Create(ctx, "tenant-1", weeklyNewsletterInput())
+	require.NoError(t, err)
+	assert.Equal(t, "JOIN DEALS", newsletter.OptInKeyword)
+	assert.Equal(t, DefaultNewsletterFrequencyCap, newsletter.FrequencyCap)
+	require.NotNil(t, newsletter.NextRunAt)
+	assert.Equal(t, time.Monday, newsletter.NextRunAt.Weekday())
+
+	input := weeklyNewsletterInput()
+	input.ChannelID = "ch-sms"
+	_, err = f.service.Create(ctx, "tenant-1", input)
+	assert.Error(t, err, "sms channels are not supported")
+
+	_, err = f.service.Create(ctx, "tenant-2", weeklyNewsletterInput())
+	assert.Error(t, err, "channel of another tenant")
+
+	input = weeklyNewsletterInput()
+	input.OptInKeyword = "stop"
+	_, err = f.service.Create(ctx, "tenant-1", input)
+	assert.Error(t, err, "opt-in keyword clashes with unsubscribe")
+}
+
+func TestNewsletterService_SendEdition(t *testing.T) {
+	f := newNewsletterFixture()
+	ctx := context.Background()
+
+	input := weeklyNewsletterInput()
+	input.SegmentTags = []string{"vip"}
+	input.FrequencyCap = 1
+	newsletter, err := f.service.Create(ctx, "tenant-1", input)
+	require.NoError(t, err)
+
+	_, err = f.service.Subscribe(ctx, "tenant-1", newsletter.ID, &SubscribeContactsInput{ContactIDs: []string{"mallory"}})
+	assert.Error(t, err, "contact of another tenant")
+
+	count, err := f.service.Subscribe(ctx, "tenant-1", newsletter.ID, &SubscribeContactsInput{ContactIDs: []string{"alice", "bob"}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// Carol is in the segment but never opted in
+	edition, err := f.service.SendNow(ctx, "tenant-1", newsletter.ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, edition.Recipients, "bob is outside the segment")
+	assert.Equal(t, 1, edition.Sent)
+	assert.Equal(t, entity.NewsletterEditionSent, edition.Status)
+
+	require.Len(t, f.producer.OutboundMessages, 1)
+	outbound := f.producer.OutboundMessages[0]
+	assert.Equal(t, "alice", outbound.ContactID)
+	assert.Equal(t, "This week's deals", outbound.Content)
+	assert.Equal(t, newsletter.ID, outbound.Metadata["campaign_id"])
+	assert.Equal(t, edition.ID, outbound.Metadata["newsletter_edition_id"])
+
+	// The second edition is over Alice's frequency cap
+	second, err := f.service.SendNow(ctx, "tenant-1", newsletter.ID, &SendEditionInput{Content: "Flash sale"})
+	require.NoError(t, err)
+	assert.Equal(t, 0, second.Sent)
+	assert.Equal(t, 1, second.Capped)
+	assert.Len(t, f.producer.OutboundMessages, 1)
+}
+
+func TestNewsletterService_MarketingLimits(t *testing.T) {
+	f := newNewsletterFixture()
+	ctx := context.Background()
+	tenants := testutil.NewMockTenantRepository()
+	tenants.Tenants["tenant-1"] = &entity.Tenant{ID: "tenant-1", Settings: map[string]string{
+		entity.TenantSettingMarketingFrequencyCap: "2",
+		entity.TenantSettingMarketingInactiveDays: "90",
+	}}
+	f.service.SetTenantRepository(tenants)
+
+	longAgo := time.Now().AddDate(0, -6, 0)
+	f.contacts.Contacts["alice"].CreatedAt = longAgo
+	f.contacts.Contacts["bob"].CreatedAt = longAgo
+	f.contacts.Contacts["carol"].CreatedAt = time.Now()
+	f.repo.lastEngaged["alice"] = time.Now().AddDate(0, 0, -3)
+	f.repo.lastEngaged["bob"] = time.Now().AddDate(0, 0, -120)
+
+	input := weeklyNewsletterInput()
+	newsletter, err := f.service.Create(ctx, "tenant-1", input)
+	require.NoError(t, err)
+	_, err = f.service.Subscribe(ctx, "tenant-1", newsletter.ID, &SubscribeContactsInput{ContactIDs: []string{"alice", "bob", "carol"}})
+	require.NoError(t, err)
+
+	// Bob has not engaged in 90 days; Carol is new and gets the chance to
+	edition, err := f.service.SendNow(ctx, "tenant-1", newsletter.ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, edition.Recipients)
+	assert.Equal(t, 1, edition.Suppressed)
+	assert.Equal(t, 2, edition.Sent)
+
+	stats, err := f.service.editionStats(ctx, edition.ID, "")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Suppressed)
+
+	// Alice got a marketing template on another channel, reaching the tenant cap
+	// before the newsletter cap of 4
+	f.repo.marketing["alice"] = 1
+	second, err := f.service.SendNow(ctx, "tenant-1", newsletter.ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, second.Sent, "carol")
+	assert.Equal(t, 1, second.Capped)
+	assert.Equal(t, 1, second.GlobalCapped)
+	assert.Equal(t, 1, second.Suppressed)
+
+	// A newsletter may use its own inactivity window
+	input.SuppressInactiveDays = 180
+	_, err = f.service.Update(ctx, "tenant-1", newsletter.ID, input)
+	require.NoError(t, err)
+	third, err := f.service.SendNow(ctx, "tenant-1", newsletter.ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, third.Suppressed)
+
+	input.SuppressInactiveDays = -1
+	_, err = f.service.Update(ctx, "tenant-1", newsletter.ID, input)
+	assert.Error(t, err)
+}
+
+// mockImageRenderer renders images named after their data
+type mockImageRenderer struct {
+	requests []*entity.RenderRequest
+}
+
+func (m *mockImageRenderer) RenderToURL(ctx context.Context, req *entity.RenderRequest) (*entity.RenderResponse, error) {
+	m.requests = append(m.requests, req)
+	return &entity.RenderResponse{ImageURL: fmt.Sprintf("https://cdn.example.com/%v.png", req.Data["name"])}, nil
+}
+
+func TestNewsletterService_PersonalizedImage(t *testing.T) {
+	f := newNewsletterFixture()
+	ctx := context.Background()
+	renderer := &mockImageRenderer{}
+	f.service.SetVariablesService(NewConversationVariablesService(f.conversations, f.contacts, nil))
+
+	input := weeklyNewsletterInput()
+	input.Image = &entity.NewsletterImage{TemplateID: "card_produto", Data: map[string]interface{}{"name": "{{contact.name}}", "offer": "10% off"}}
+	_, err := f.service.Create(ctx, "tenant-1", input)
+	assert.Error(t, err, "no renderer configured")
+
+	f.service.SetImageRenderer(renderer)
+	newsletter, err := f.service.Create(ctx, "tenant-1", input)
+	require.NoError(t, err)
+
+	// A second Alice renders the same image as the first one
+	f.contacts.Contacts["alice-2"] = &entity.Contact{ID: "alice-2", TenantID: "tenant-1", Name: "Alice"}
+	_, err = f.service.Subscribe(ctx, "tenant-1", newsletter.ID, &SubscribeContactsInput{ContactIDs: []string{"alice", "bob", "alice-2"}})
+	require.NoError(t, err)
+
+	edition, err := f.service.SendNow(ctx, "tenant-1", newsletter.ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, edition.Sent)
+	require.NotNil(t, edition.Renders)
+	assert.Equal(t, 2, edition.Renders.Rendered)
+	assert.Equal(t, 1, edition.Renders.CacheHits)
+	require.Len(t, renderer.requests, 2)
+	assert.Equal(t, "10% off", renderer.requests[0].Data["offer"])
+
+	images := map[string]string{}
+	for _, outbound := range f.producer.OutboundMessages {
+		assert.Equal(t, "image", outbound.ContentType)
+		assert.Equal(t, "This week's deals", outbound.Content, "the content is the caption")
+		images[outbound.ContactID] = outbound.Metadata["media_url"]
+	}
+	assert.Equal(t, "https://cdn.example.com/Alice.png", images["alice"])
+	assert.Equal(t, "https://cdn.example.com/Bob.png", images["bob"])
+	assert.Equal(t, images["alice"], images["alice-2"])
+
+	stats, err := f.service.RenderStats(ctx, "tenant-1", newsletter.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Editions)
+	assert.Equal(t, 2, stats.Rendered)
+	assert.InDelta(t, 1.0/3, stats.CacheHitRate, 0.001)
+
+	_, err = f.service.RenderStats(ctx, "tenant-2", newsletter.ID)
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestNewsletterService_Keywords(t *testing.T) {
+	f := newNewsletterFixture()
+	ctx := context.Background()
+
+	input := weeklyNewsletterInput()
+	input.WelcomeMessage = "Welcome to weekly deals!"
+	newsletter, err := f.service.Create(ctx, "tenant-1", input)
+	require.NoError(t, err)
+
+	conversation := &entity.Conversation{
+		ID:        "conv-1",
+		TenantID:  "tenant-1",
+		ChannelID: "ch-1",
+		ContactID: "bob",
+		Status:    entity.ConversationStatusOpen,
+	}
+	f.conversations.Conversations[conversation.ID] = conversation
+	inbound := func(content string) *entity.Message {
+		return &entity.Message{SenderType: entity.SenderTypeContact, ContentType: entity.ContentTypeText, Content: content}
+	}
+
+	// Opt in by keyword
+	require.NoError(t, f.service.HandleInbound(ctx, inbound(" Join Deals! "), conversation))
+	subscription, err := f.repo.FindSubscription(ctx, newsletter.ID, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, entity.NewsletterSubscribed, subscription.Status)
+	assert.Equal(t, "keyword", subscription.Source)
+	require.Len(t, f.producer.OutboundMessages, 1)
+	assert.Equal(t, "Welcome to weekly deals!", f.producer.OutboundMessages[0].Content)
+
+	edition, err := f.service.SendNow(ctx, "tenant-1", newsletter.ID, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, edition.Sent)
+
+	// A reply counts toward the edition's engagement
+	require.NoError(t, f.service.HandleInbound(ctx, inbound("Do you have it in blue?"), conversation))
+
+	// Unsubscribe with a default keyword and get a confirmation
+	require.NoError(t, f.service.HandleInbound(ctx, inbound("stop"), conversation))
+	subscription, err = f.repo.FindSubscription(ctx, newsletter.ID, "bob")
+	require.NoError(t, err)
+	assert.Equal(t, entity.NewsletterUnsubscribed, subscription.Status)
+	assert.NotNil(t, subscription.UnsubscribedAt)
+	last := f.producer.OutboundMessages[len(f.producer.OutboundMessages)-1]
+	assert.Equal(t, defaultUnsubscribeMessage, last.Content)
+
+	withStats, err := f.service.GetEdition(ctx, "tenant-1", newsletter.ID, edition.ID)
+	require.NoError(t, err)
+	require.NotNil(t, withStats.Stats)
+	assert.Equal(t, int64(1), withStats.Stats.Replied)
+	assert.Equal(t, int64(1), withStats.Stats.Unsubscribed)
+	assert.Equal(t, 1.0, withStats.Stats.UnsubscribeRate)
+
+	// Unsubscribed contacts get nothing
+	next, err := f.service.SendNow(ctx, "tenant-1", newsletter.ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, next.Recipients)
+
+	_, err = f.service.GetEdition(ctx, "tenant-2", newsletter.ID, edition.ID)
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestNewsletterService_RunDue(t *testing.T) {
+	f := newNewsletterFixture()
+	ctx := context.Background()
+
+	newsletter, err := f.service.Create(ctx, "tenant-1", weeklyNewsletterInput())
+	require.NoError(t, err)
+	_, err = f.service.Subscribe(ctx, "tenant-1", newsletter.ID, &SubscribeContactsInput{ContactIDs: []string{"alice"}})
+	require.NoError(t, err)
+
+	sent, err := f.service.RunDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent, "not due yet")
+
+	past := time.Now().Add(-time.Minute)
+	newsletter.NextRunAt = &past
+	sent, err = f.service.RunDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	assert.Len(t, f.producer.OutboundMessages, 1)
+	assert.True(t, newsletter.NextRunAt.After(time.Now()))
+	assert.NotNil(t, newsletter.LastRunAt)
+}
+
+func TestNewsletterService_RunDueDefersDuringMaintenance(t *testing.T) {
+	f := newNewsletterFixture()
+	ctx := context.Background()
+
+	maintenanceRepo := newMockChannelMaintenanceRepo()
+	endsAt := time.Now().Add(time.Hour)
+	maintenanceRepo.Create(ctx, &entity.ChannelMaintenanceWindow{
+		ID: "window-1", TenantID: "tenant-1", ChannelID: "ch-1", StartsAt: time.Now().Add(-time.Minute), EndsAt: endsAt,
+	})
+	f.service.SetChannelMaintenance(NewChannelMaintenanceService(maintenanceRepo, testutil.NewMockChannelRepository(), f.conversations, nil))
+
+	newsletter, err := f.service.Create(ctx, "tenant-1", weeklyNewsletterInput())
+	require.NoError(t, err)
+	_, err = f.service.Subscribe(ctx, "tenant-1", newsletter.ID, &SubscribeContactsInput{ContactIDs: []string{"alice"}})
+	require.NoError(t, err)
+
+	past := time.Now().Add(-time.Minute)
+	newsletter.NextRunAt = &past
+	sent, err := f.service.RunDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+	assert.Empty(t, f.producer.OutboundMessages)
+	assert.True(t, newsletter.NextRunAt.Equal(endsAt), "deferred to the end of the window")
+	assert.Nil(t, newsletter.LastRunAt)
+
+	_, err = f.service.SendNow(ctx, "tenant-1", newsletter.ID, nil)
+	assert.Error(t, err)
+}
+
+func TestContactEngagement_BestSendTime(t *testing.T) {
+	from := time.Date(2026, 10, 16, 9, 20, 0, 0, time.UTC)
+	engagement := &entity.ContactEngagement{}
+	engagement.ReadHours[14] = 3
+	engagement.ReplyHours[19] = 2
+	engagement.ReadHours[23] = 10
+
+	assert.Equal(t, time.Date(2026, 10, 16, 19, 0, 0, 0, time.UTC), engagement.BestSendTime(from, 12*time.Hour))
+	assert.Equal(t, time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC), engagement.BestSendTime(from, 8*time.Hour))
+	assert.Equal(t, from, engagement.BestSendTime(from, 4*time.Hour), "no engagement within the window")
+	assert.Equal(t, 15, engagement.Total())
+}
+
+func TestNewsletterService_SmartSend(t *testing.T) {
+	f := newNewsletterFixture()
+	ctx := context.Background()
+
+	input := weeklyNewsletterInput()
+	input.SmartSend = &entity.NewsletterSmartSend{Enabled: true, WindowHours: 48}
+	_, err := f.service.Create(ctx, "tenant-1", input)
+	assert.Error(t, err, "window longer than a day")
+
+	input.SmartSend.WindowHours = 12
+	newsletter, err := f.service.Create(ctx, "tenant-1", input)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultSmartSendControlPercent, newsletter.SmartSend.ControlPercent)
+	assert.Equal(t, DefaultSmartSendMinEngagements, newsletter.SmartSend.MinEngagements)
+	newsletter.SmartSend.ControlPercent = 0
+
+	_, err = f.service.Subscribe(ctx, "tenant-1", newsletter.ID, &SubscribeContactsInput{ContactIDs: []string{"alice", "bob"}})
+	require.NoError(t, err)
+
+	// Alice replies five hours from now; Bob has no history and gets the fixed time
+	best := time.Now().UTC().Add(5 * time.Hour)
+	alice := &entity.ContactEngagement{ContactID: "alice"}
+	alice.ReplyHours[best.Hour()] = 4
+	f.repo.engagement["alice"] = alice
+
+	edition, err := f.service.SendNow(ctx, "tenant-1", newsletter.ID, nil)
+	require.NoError(t, err)
+	assert.True(t, edition.SmartSend)
+	assert.Equal(t, entity.NewsletterEditionSending, edition.Status)
+	assert.Equal(t, 2, edition.Recipients)
+	assert.Equal(t, 1, edition.Sent)
+	assert.Equal(t, 1, edition.Scheduled)
+	assert.Zero(t, countEvents(f.producer, EventNewsletterEditionSent), "edition is not sent until every delivery went out")
+
+	require.Len(t, f.repo.deliveries, 2)
+	var scheduled *entity.NewsletterDelivery
+	for _, delivery := range f.repo.deliveries {
+		if delivery.ContactID == "alice" {
+			scheduled = delivery
+		} else {
+			assert.Equal(t, entity.NewsletterDeliverySent, delivery.Status)
+			assert.Equal(t, entity.NewsletterTimingFixed, delivery.Timing)
+		}
+	}
+	require.NotNil(t, scheduled)
+	assert.Equal(t, entity.NewsletterDeliveryScheduled, scheduled.Status)
+	assert.Equal(t, entity.NewsletterTimingSmart, scheduled.Timing)
+	require.NotNil(t, scheduled.ScheduledAt)
+	assert.True(t, best.Truncate(time.Hour).Equal(*scheduled.ScheduledAt))
+
+	// Nothing is due yet
+	f.service.runScheduledDeliveries(ctx, time.Now())
+	assert.Equal(t, entity.NewsletterDeliveryScheduled, scheduled.Status)
+
+	f.service.runScheduledDeliveries(ctx, scheduled.ScheduledAt.Add(time.Minute))
+	assert.Equal(t, entity.NewsletterDeliverySent, scheduled.Status)
+	assert.NotEmpty(t, scheduled.MessageID)
+
+	stored := f.repo.editions[edition.ID]
+	assert.Equal(t, entity.NewsletterEditionSent, stored.Status)
+	assert.Equal(t, 2, stored.Sent)
+	assert.Equal(t, 0, stored.Scheduled)
+	require.NotNil(t, stored.CompletedAt)
+	assert.Equal(t, 1, countEvents(f.producer, EventNewsletterEditionSent))
+
+	repliedAt := time.Now()
+	scheduled.RepliedAt = &repliedAt
+	result, err := f.service.GetEdition(ctx, "tenant-1", newsletter.ID, edition.ID)
+	require.NoError(t, err)
+	require.NotNil(t, result.SendTimeComparison)
+	assert.Equal(t, int64(1), result.SendTimeComparison.Smart.Sent)
+	assert.Equal(t, int64(1), result.SendTimeComparison.Fixed.Sent)
+	assert.InDelta(t, 1.0, result.SendTimeComparison.ReplyRateLift, 0.001)
+}
+
+func countEvents(producer *testutil.MockProducer, eventType string) int {
+	count := 0
+	for _, event := range producer.Events {
+		if event.Type == eventType {
+			count++
+		}
+	}
+	return count
+}
+
+func TestInSmartSendControlGroup(t *testing.T) {
+	inControl := 0
+	for i := 0; i < 1000; i++ {
+		contactID := uuid.New().String()
+		assert.False(t, inSmartSendControlGroup("edition-1", contactID, 0))
+		assert.True(t, inSmartSendControlGroup("edition-1", contactID, 100))
+		if inSmartSendControlGroup("edition-1", contactID, 10) {
+			inControl++
+		}
+	}
+	assert.InDelta(t, 100, inControl, 50)
+}
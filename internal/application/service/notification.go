@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// NotificationService runs the notification center of tenants: the platform raises
+// notifications about what a tenant should act on, such as failing channel
+// credentials, and resolves them once the condition clears
+type NotificationService struct {
+	repo repository.NotificationRepository
+	now  func() time.Time
+}
+
+// NewNotificationService creates a new notification service
+func NewNotificationService(repo repository.NotificationRepository) *NotificationService {
+	return &NotificationService{
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+// Raise adds a notification to the tenant's notification center. A notification with
+// a key updates the open one with the same key instead of adding another.
+func (s *NotificationService) Raise(ctx context.Context, notification *entity.Notification) error {
+	if notification.TenantID == "" || notification.Type == "" || notification.Title == "" {
+		return errors.Validation("tenant_id, type and title are required")
+	}
+	if notification.Severity == "" {
+		notification.Severity = entity.NotificationSeverityInfo
+	}
+
+	now := s.now()
+	notification.ID = uuid.New().String()
+	notification.ReadAt = nil
+	notification.ResolvedAt = nil
+	notification.CreatedAt = now
+	notification.UpdatedAt = now
+	return s.repo.Raise(ctx, notification)
+}
+
+// Resolve resolves the open notification of a tenant with the key, if any
+func (s *NotificationService) Resolve(ctx context.Context, tenantID, key string) error {
+	return s.repo.Resolve(ctx, tenantID, key, s.now())
+}
+
+// List lists the notifications of a tenant, newest first
+func (s *NotificationService) List(ctx context.Context, tenantID string, filter *entity.NotificationFilter, params *repository.ListParams) ([]*entity.Notification, int64, error) {
+	return s.repo.FindByTenant(ctx, tenantID, filter, params)
+}
+
+// UnreadCount counts the unread, unresolved notifications of a tenant
+func (s *NotificationService) UnreadCount(ctx context.Context, tenantID string) (int64, error) {
+	return s.repo.CountUnread(ctx, tenantID)
+}
+
+// MarkRead marks a notification of a tenant as read
+func (s *NotificationService) MarkRead(ctx context.Context, tenantID, id string) error {
+	return s.repo.MarkRead(ctx, tenantID, id, s.now())
+}
+
+// MarkAllRead marks every notification of a tenant as read, returning how many were unread
+func (s *NotificationService) MarkAllRead(ctx context.Context, tenantID string) (int64, error) {
+	return s.repo.MarkAllRead(ctx, tenantID, s.now())
+}
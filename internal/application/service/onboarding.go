@@ -0,0 +1,606 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Events published as tenants move through onboarding, for product analytics
+const (
+	EventOnboardingStepCompleted      = "onboarding.step_completed"
+	EventOnboardingStepSkipped        = "onboarding.step_skipped"
+	EventOnboardingSamplesProvisioned = "onboarding.samples_provisioned"
+	EventOnboardingCompleted          = "onboarding.completed"
+)
+
+const (
+	onboardingSampleChannelName = "Sample Web Chat"
+	onboardingSampleContactName = "Sample Customer"
+	onboardingTestMessage       = "Hello from Linktor! This is a test message from your new workspace."
+)
+
+// OnboardingStatus is the onboarding progress of a tenant with the step to show next
+type OnboardingStatus struct {
+	*entity.OnboardingProgress
+	CurrentStep     entity.OnboardingStep `json:"current_step,omitempty"`
+	PercentComplete int                   `json:"percent_complete"`
+}
+
+// SetupWorkspaceInput represents input for the workspace step
+type SetupWorkspaceInput struct {
+	Name     string            `json:"name" binding:"required"`
+	Settings map[string]string `json:"settings,omitempty"`
+}
+
+// InviteUserInput represents a teammate invited during onboarding
+type InviteUserInput struct {
+	Email    string          `json:"email" binding:"required,email"`
+	Name     string          `json:"name" binding:"required"`
+	Role     entity.UserRole `json:"role,omitempty"`
+	Password string          `json:"password,omitempty"` // Generated when empty
+}
+
+// InviteUsersInput represents input for the invite users step
+type InviteUsersInput struct {
+	Users []InviteUserInput `json:"users" binding:"required,min=1,dive"`
+}
+
+// InvitedUser is a user created by an invitation. The temporary password is only
+// returned when it was generated, and only once.
+type InvitedUser struct {
+	User              *entity.User `json:"user"`
+	TemporaryPassword string       `json:"temporary_password,omitempty"`
+}
+
+// FailedInvite is an invitation that could not be created
+type FailedInvite struct {
+	Email string `json:"email"`
+	Error string `json:"error"`
+}
+
+// InviteUsersResult is the outcome of the invite users step
+type InviteUsersResult struct {
+	Invited []*InvitedUser    `json:"invited"`
+	Failed  []*FailedInvite   `json:"failed,omitempty"`
+	Status  *OnboardingStatus `json:"onboarding"`
+}
+
+// OnboardingChannelInput represents input for the connect channel step
+type OnboardingChannelInput struct {
+	Type        string            `json:"type" binding:"required"`
+	Name        string            `json:"name" binding:"required"`
+	Identifier  string            `json:"identifier,omitempty"`
+	Config      map[string]string `json:"config,omitempty"`
+	Credentials map[string]string `json:"credentials,omitempty"`
+}
+
+// OnboardingChannelResult is the outcome of the connect channel step. Channels that
+// need a QR code or pair code complete the step once they are connected.
+type OnboardingChannelResult struct {
+	*ConnectResult
+	Status *OnboardingStatus `json:"onboarding"`
+}
+
+// OnboardingBotInput represents input for the create bot step
+type OnboardingBotInput struct {
+	Name         string                `json:"name" binding:"required"`
+	Type         entity.BotType        `json:"type,omitempty"`
+	Provider     entity.AIProviderType `json:"provider" binding:"required"`
+	Model        string                `json:"model" binding:"required"`
+	SystemPrompt string                `json:"system_prompt,omitempty"`
+}
+
+// OnboardingBotResult is the outcome of the create bot step
+type OnboardingBotResult struct {
+	Bot    *entity.Bot       `json:"bot"`
+	Status *OnboardingStatus `json:"onboarding"`
+}
+
+// TestMessageInput represents input for the send test message step. Without a
+// conversation the message goes to the sample conversation.
+type TestMessageInput struct {
+	ConversationID string `json:"conversation_id,omitempty"`
+	Content        string `json:"content,omitempty"`
+}
+
+// TestMessageResult is the outcome of the send test message step
+type TestMessageResult struct {
+	Message *entity.Message   `json:"message"`
+	Status  *OnboardingStatus `json:"onboarding"`
+}
+
+// OnboardingService guides a new tenant through setting up its workspace. Each step
+// can be done through the wizard or the regular endpoints: channels, bots and users
+// created elsewhere complete their steps when the progress is next read.
+type OnboardingService struct {
+	repo                repository.OnboardingRepository
+	tenantService       *TenantService
+	userService         *UserService
+	channelService      *ChannelService
+	botService          *BotServiceImpl
+	contactService      *ContactService
+	conversationService *ConversationService
+	messageService      *MessageService
+	producer            nats.Publisher
+}
+
+// NewOnboardingService creates a new onboarding service
+func NewOnboardingService(
+	repo repository.OnboardingRepository,
+	tenantService *TenantService,
+	userService *UserService,
+	channelService *ChannelService,
+	botService *BotServiceImpl,
+	contactService *ContactService,
+	conversationService *ConversationService,
+	messageService *MessageService,
+	producer nats.Publisher,
+) *OnboardingService {
+	return &OnboardingService{
+		repo:                repo,
+		tenantService:       tenantService,
+		userService:         userService,
+		channelService:      channelService,
+		botService:          botService,
+		contactService:      contactService,
+		conversationService: conversationService,
+		messageService:      messageService,
+		producer:            producer,
+	}
+}
+
+// GetStatus returns the onboarding progress of a tenant, completing the steps whose
+// resources were created outside the wizard
+func (s *OnboardingService) GetStatus(ctx context.Context, tenantID string) (*OnboardingStatus, error) {
+	progress, err := s.load(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.detectCompletedSteps(ctx, progress) {
+		if err := s.save(ctx, progress); err != nil {
+			return nil, err
+		}
+	}
+	return newOnboardingStatus(progress), nil
+}
+
+// SetupWorkspace names the workspace and stores its initial settings
+func (s *OnboardingService) SetupWorkspace(ctx context.Context, tenantID string, input *SetupWorkspaceInput) (*OnboardingStatus, error) {
+	if input.Name == "" {
+		return nil, errors.Validation("name is required")
+	}
+
+	progress, err := s.load(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	tenant, err := s.tenantService.Update(ctx, tenantID, &UpdateTenantInput{
+		Name:     &input.Name,
+		Settings: input.Settings,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.completeStep(ctx, progress, entity.OnboardingStepWorkspace, tenant.ID)
+	if err := s.save(ctx, progress); err != nil {
+		return nil, err
+	}
+	return newOnboardingStatus(progress), nil
+}
+
+// InviteUsers creates accounts for teammates. Invitations that fail are reported
+// without stopping the others; the step completes once anyone was invited.
+func (s *OnboardingService) InviteUsers(ctx context.Context, tenantID string, input *InviteUsersInput) (*InviteUsersResult, error) {
+	if len(input.Users) == 0 {
+		return nil, errors.Validation("at least one user is required")
+	}
+
+	progress, err := s.load(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &InviteUsersResult{Invited: []*InvitedUser{}}
+	for _, invite := range input.Users {
+		role := invite.Role
+		if role == "" {
+			role = entity.UserRoleAgent
+		}
+		if role == entity.UserRoleOwner {
+			result.Failed = append(result.Failed, &FailedInvite{Email: invite.Email, Error: "owners cannot be invited"})
+			continue
+		}
+
+		password, generated := invite.Password, false
+		if password == "" {
+			password, generated = newTemporaryPassword(), true
+		}
+
+		user, err := s.userService.Create(ctx, &CreateUserInput{
+			TenantID: tenantID,
+			Email:    invite.Email,
+			Password: password,
+			Name:     invite.Name,
+			Role:     role,
+		})
+		if err != nil {
+			result.Failed = append(result.Failed, &FailedInvite{Email: invite.Email, Error: err.Error()})
+			continue
+		}
+
+		invited := &InvitedUser{User: user}
+		if generated {
+			invited.TemporaryPassword = password
+		}
+		result.Invited = append(result.Invited, invited)
+	}
+
+	if len(result.Invited) > 0 {
+		s.completeStep(ctx, progress, entity.OnboardingStepInviteUsers, result.Invited[0].User.ID)
+		if err := s.save(ctx, progress); err != nil {
+			return nil, err
+		}
+	}
+
+	result.Status = newOnboardingStatus(progress)
+	return result, nil
+}
+
+// ConnectChannel creates the tenant's first channel and connects it
+func (s *OnboardingService) ConnectChannel(ctx context.Context, tenantID string, input *OnboardingChannelInput) (*OnboardingChannelResult, error) {
+	if input.Type == "" || input.Name == "" {
+		return nil, errors.Validation("type and name are required")
+	}
+
+	progress, err := s.load(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	channel, err := s.channelService.Create(ctx, &CreateChannelInput{
+		TenantID:    tenantID,
+		Type:        input.Type,
+		Name:        input.Name,
+		Identifier:  input.Identifier,
+		Config:      input.Config,
+		Credentials: input.Credentials,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	connect, err := s.channelService.Connect(ctx, channel.TenantID, channel.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if connect.Channel != nil && connect.Channel.IsConnected() {
+		s.completeStep(ctx, progress, entity.OnboardingStepConnectChannel, channel.ID)
+		if err := s.save(ctx, progress); err != nil {
+			return nil, err
+		}
+	}
+
+	return &OnboardingChannelResult{ConnectResult: connect, Status: newOnboardingStatus(progress)}, nil
+}
+
+// CreateBot creates the tenant's first bot
+func (s *OnboardingService) CreateBot(ctx context.Context, tenantID string, input *OnboardingBotInput) (*OnboardingBotResult, error) {
+	if input.Name == "" || input.Provider == "" || input.Model == "" {
+		return nil, errors.Validation("name, provider and model are required")
+	}
+
+	progress, err := s.load(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	botType := input.Type
+	if botType == "" {
+		botType = entity.BotTypeAI
+	}
+	bot, err := s.botService.Create(ctx, &CreateBotInput{
+		TenantID:     tenantID,
+		Name:         input.Name,
+		Type:         botType,
+		Provider:     input.Provider,
+		Model:        input.Model,
+		SystemPrompt: input.SystemPrompt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.completeStep(ctx, progress, entity.OnboardingStepCreateBot, bot.ID)
+	if err := s.save(ctx, progress); err != nil {
+		return nil, err
+	}
+	return &OnboardingBotResult{Bot: bot, Status: newOnboardingStatus(progress)}, nil
+}
+
+// SendTestMessage sends a message to a conversation of the tenant, or to the sample
+// conversation when none is given
+func (s *OnboardingService) SendTestMessage(ctx context.Context, tenantID, userID string, input *TestMessageInput) (*TestMessageResult, error) {
+	progress, err := s.load(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	conversationID := input.ConversationID
+	if conversationID == "" {
+		conversationID = progress.SampleResources[entity.OnboardingSampleConversation]
+	}
+	if conversationID == "" {
+		return nil, errors.Validation("conversation_id is required unless the sample resources were provisioned")
+	}
+
+	if _, err := s.conversationService.GetByID(ctx, tenantID, conversationID); err != nil {
+		return nil, err
+	}
+
+	content := input.Content
+	if content == "" {
+		content = onboardingTestMessage
+	}
+	message, err := s.messageService.Send(ctx, &SendMessageInput{
+		TenantID:       tenantID,
+		ConversationID: conversationID,
+		SenderID:       userID,
+		SenderType:     string(entity.SenderTypeUser),
+		ContentType:    string(entity.ContentTypeText),
+		Content:        content,
+		Metadata:       map[string]string{"onboarding_test": "true"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.completeStep(ctx, progress, entity.OnboardingStepSendTestMessage, message.ID)
+	if err := s.save(ctx, progress); err != nil {
+		return nil, err
+	}
+	return &TestMessageResult{Message: message, Status: newOnboardingStatus(progress)}, nil
+}
+
+// SkipStep marks a pending step as skipped
+func (s *OnboardingService) SkipStep(ctx context.Context, tenantID string, step entity.OnboardingStep) (*OnboardingStatus, error) {
+	if !step.IsValid() {
+		return nil, errors.Validation("unknown onboarding step")
+	}
+
+	progress, err := s.load(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	state := progress.Step(step)
+	if state.Status == entity.OnboardingStepStatusCompleted {
+		return nil, errors.New(errors.ErrCodeConflict, "step is already completed")
+	}
+	if state.Status == entity.OnboardingStepStatusPending {
+		state.Status = entity.OnboardingStepStatusSkipped
+		s.publishEvent(ctx, progress, EventOnboardingStepSkipped, map[string]interface{}{
+			"step": string(step),
+		})
+		s.finishIfDone(ctx, progress)
+		if err := s.save(ctx, progress); err != nil {
+			return nil, err
+		}
+	}
+	return newOnboardingStatus(progress), nil
+}
+
+// ProvisionSamples creates a web chat channel with a sample contact and conversation
+// so the tenant can try the inbox before connecting a real channel. Provisioning
+// again returns the existing samples.
+func (s *OnboardingService) ProvisionSamples(ctx context.Context, tenantID string) (*OnboardingStatus, error) {
+	progress, err := s.load(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if progress.SampleResources[entity.OnboardingSampleConversation] != "" {
+		return newOnboardingStatus(progress), nil
+	}
+
+	channel, err := s.channelService.Create(ctx, &CreateChannelInput{
+		TenantID: tenantID,
+		Type:     string(entity.ChannelTypeWebChat),
+		Name:     onboardingSampleChannelName,
+		Config:   map[string]string{"sample": "true"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.channelService.Connect(ctx, channel.TenantID, channel.ID); err != nil {
+		return nil, err
+	}
+
+	contact, err := s.contactService.Create(ctx, &CreateContactInput{
+		TenantID: tenantID,
+		Name:     onboardingSampleContactName,
+		Tags:     []string{"sample"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.contactService.AddIdentity(ctx, tenantID, contact.ID, string(entity.ChannelTypeWebChat), "sample-"+contact.ID, nil); err != nil {
+		return nil, err
+	}
+
+	conversation, err := s.conversationService.Create(ctx, &CreateConversationInput{
+		TenantID:  tenantID,
+		ContactID: contact.ID,
+		ChannelID: channel.ID,
+		Subject:   "Welcome to Linktor",
+		Tags:      []string{"sample"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	progress.SampleResources[entity.OnboardingSampleChannel] = channel.ID
+	progress.SampleResources[entity.OnboardingSampleContact] = contact.ID
+	progress.SampleResources[entity.OnboardingSampleConversation] = conversation.ID
+	s.publishEvent(ctx, progress, EventOnboardingSamplesProvisioned, map[string]interface{}{
+		"channel_id":      channel.ID,
+		"conversation_id": conversation.ID,
+	})
+
+	if err := s.save(ctx, progress); err != nil {
+		return nil, err
+	}
+	return newOnboardingStatus(progress), nil
+}
+
+// load returns the progress of a tenant, starting onboarding on first access
+func (s *OnboardingService) load(ctx context.Context, tenantID string) (*entity.OnboardingProgress, error) {
+	progress, err := s.repo.FindByTenant(ctx, tenantID)
+	if err == nil {
+		if progress.SampleResources == nil {
+			progress.SampleResources = make(map[string]string)
+		}
+		return progress, nil
+	}
+	if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	if _, err := s.tenantService.GetByID(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	progress = entity.NewOnboardingProgress(tenantID)
+	progress.ID = uuid.New().String()
+	return progress, nil
+}
+
+func (s *OnboardingService) save(ctx context.Context, progress *entity.OnboardingProgress) error {
+	progress.UpdatedAt = time.Now()
+	return s.repo.Save(ctx, progress)
+}
+
+// detectCompletedSteps completes the steps whose resources already exist and
+// reports whether anything changed. Sample channels do not count as connected.
+func (s *OnboardingService) detectCompletedSteps(ctx context.Context, progress *entity.OnboardingProgress) bool {
+	changed := false
+	params := repository.NewListParams()
+
+	if progress.Step(entity.OnboardingStepInviteUsers).Status != entity.OnboardingStepStatusCompleted {
+		if users, total, err := s.userService.List(ctx, progress.TenantID, params); err == nil && total > 1 {
+			s.completeStep(ctx, progress, entity.OnboardingStepInviteUsers, users[0].ID)
+			changed = true
+		}
+	}
+
+	if progress.Step(entity.OnboardingStepConnectChannel).Status != entity.OnboardingStepStatusCompleted {
+		if channels, err := s.channelService.List(ctx, progress.TenantID); err == nil {
+			for _, channel := range channels {
+				if channel.IsConnected() && !progress.IsSampleChannel(channel.ID) {
+					s.completeStep(ctx, progress, entity.OnboardingStepConnectChannel, channel.ID)
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	if progress.Step(entity.OnboardingStepCreateBot).Status != entity.OnboardingStepStatusCompleted {
+		if bots, total, err := s.botService.List(ctx, progress.TenantID, params); err == nil && total > 0 && len(bots) > 0 {
+			s.completeStep(ctx, progress, entity.OnboardingStepCreateBot, bots[0].ID)
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+// completeStep marks a step as completed. Steps completed before are left as they
+// were, so their original completion time is kept.
+func (s *OnboardingService) completeStep(ctx context.Context, progress *entity.OnboardingProgress, step entity.OnboardingStep, resourceID string) {
+	state := progress.Step(step)
+	if state.Status == entity.OnboardingStepStatusCompleted {
+		return
+	}
+
+	now := time.Now()
+	state.Status = entity.OnboardingStepStatusCompleted
+	state.ResourceID = resourceID
+	state.CompletedAt = &now
+
+	s.publishEvent(ctx, progress, EventOnboardingStepCompleted, map[string]interface{}{
+		"step":                  string(step),
+		"resource_id":           resourceID,
+		"seconds_since_started": int(now.Sub(progress.CreatedAt).Seconds()),
+		"percent_complete":      progress.PercentComplete(),
+	})
+	s.finishIfDone(ctx, progress)
+}
+
+// finishIfDone records when the last step was completed or skipped
+func (s *OnboardingService) finishIfDone(ctx context.Context, progress *entity.OnboardingProgress) {
+	if progress.CompletedAt != nil || !progress.IsFinished() {
+		return
+	}
+
+	now := time.Now()
+	progress.CompletedAt = &now
+
+	var skipped []string
+	for _, state := range progress.Steps {
+		if state.Status == entity.OnboardingStepStatusSkipped {
+			skipped = append(skipped, string(state.Step))
+		}
+	}
+	s.publishEvent(ctx, progress, EventOnboardingCompleted, map[string]interface{}{
+		"duration_seconds": int(now.Sub(progress.CreatedAt).Seconds()),
+		"skipped_steps":    skipped,
+	})
+}
+
+func (s *OnboardingService) publishEvent(ctx context.Context, progress *entity.OnboardingProgress, eventType string, payload map[string]interface{}) {
+	if s.producer == nil {
+		return
+	}
+
+	if err := s.producer.PublishEvent(ctx, &nats.Event{
+		Type:      eventType,
+		TenantID:  progress.TenantID,
+		Payload:   payload,
+		Timestamp: time.Now(),
+	}); err != nil {
+		logger.Warn("Failed to publish onboarding event",
+			zap.String("tenant_id", progress.TenantID),
+			zap.String("event_type", eventType),
+			zap.Error(err),
+		)
+	}
+}
+
+func newOnboardingStatus(progress *entity.OnboardingProgress) *OnboardingStatus {
+	return &OnboardingStatus{
+		OnboardingProgress: progress,
+		CurrentStep:        progress.CurrentStep(),
+		PercentComplete:    progress.PercentComplete(),
+	}
+}
+
+// newTemporaryPassword generates the password of an invited user who did not get one
+func newTemporaryPassword() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return uuid.New().String()
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
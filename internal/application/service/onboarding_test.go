@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockOnboardingRepo struct {
+	progress map[string]*entity.OnboardingProgress
+}
+
+func (m *mockOnboardingRepo) FindByTenant(ctx context.Context, tenantID string) (*entity.OnboardingProgress, error) {
+	if progress, ok := m.progress[tenantID]; ok {
+		return progress, nil
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "onboarding progress not found")
+}
+
+func (m *mockOnboardingRepo) Save(ctx context.Context, progress *entity.OnboardingProgress) error {
+	m.progress[progress.TenantID] = progress
+	return nil
+}
+
+type onboardingFixture struct {
+	service  *OnboardingService
+	repo     *mockOnboardingRepo
+	users    *testutil.MockUserRepository
+	channels *testutil.MockChannelRepository
+	bots     *MockBotRepository
+	producer *testutil.MockProducer
+}
+
+func newOnboardingFixture() *onboardingFixture {
+	tenants := testutil.NewMockTenantRepository()
+	tenants.Tenants["t1"] = &entity.Tenant{ID: "t1", Name: "New tenant", Settings: map[string]string{}}
+	users := testutil.NewMockUserRepository()
+	users.Users["owner"] = &entity.User{ID: "owner", TenantID: "t1", Email: "owner@example.com", Role: entity.UserRoleOwner}
+	channels := testutil.NewMockChannelRepository()
+	contacts := testutil.NewMockContactRepository()
+	conversations := testutil.NewMockConversationRepository()
+	messages := testutil.NewMockMessageRepository()
+	bots := NewMockBotRepository()
+	producer := testutil.NewMockProducer()
+	repo := &mockOnboardingRepo{progress: make(map[string]*entity.OnboardingProgress)}
+
+	svc := NewOnboardingService(
+		repo,
+		NewTenantService(tenants, users, channels, contacts),
+		NewUserService(users, tenants),
+		NewChannelService(channels, nil, producer),
+		newTestBotService(bots, channels, nil),
+		NewContactService(contacts),
+		NewConversationService(conversations, contacts, channels),
+		NewMessageService(messages, conversations, channels, contacts, producer),
+		producer,
+	)
+	return &onboardingFixture{service: svc, repo: repo, users: users, channels: channels, bots: bots, producer: producer}
+}
+
+func (f *onboardingFixture) eventTypes() []string {
+	var types []string
+	for _, event := range f.producer.Events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func TestOnboardingService_GetStatus_StartsAtWorkspace(t *testing.T) {
+	f := newOnboardingFixture()
+
+	status, err := f.service.GetStatus(context.Background(), "t1")
+	require.NoError(t, err)
+	assert.Equal(t, entity.OnboardingStepWorkspace, status.CurrentStep)
+	assert.Equal(t, 0, status.PercentComplete)
+	assert.Len(t, status.Steps, len(entity.OnboardingSteps))
+}
+
+func TestOnboardingService_GetStatus_UnknownTenant(t *testing.T) {
+	f := newOnboardingFixture()
+
+	_, err := f.service.GetStatus(context.Background(), "missing")
+	assert.Error(t, err)
+}
+
+func TestOnboardingService_SetupWorkspace(t *testing.T) {
+	f := newOnboardingFixture()
+
+	status, err := f.service.SetupWorkspace(context.Background(), "t1", &SetupWorkspaceInput{
+		Name:     "Acme Support",
+		Settings: map[string]string{"timezone": "America/Sao_Paulo"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, entity.OnboardingStepInviteUsers, status.CurrentStep)
+	assert.Equal(t, 20, status.PercentComplete)
+	assert.Equal(t, []string{EventOnboardingStepCompleted}, f.eventTypes())
+	assert.Equal(t, "workspace", f.producer.Events[0].Payload["step"])
+}
+
+func TestOnboardingService_InviteUsers(t *testing.T) {
+	f := newOnboardingFixture()
+
+	result, err := f.service.InviteUsers(context.Background(), "t1", &InviteUsersInput{Users: []InviteUserInput{
+		{Email: "ana@example.com", Name: "Ana"},
+		{Email: "owner@example.com", Name: "Duplicate"},
+		{Email: "boss@example.com", Name: "Boss", Role: entity.UserRoleOwner},
+	}})
+	require.NoError(t, err)
+
+	require.Len(t, result.Invited, 1)
+	assert.Equal(t, entity.UserRoleAgent, result.Invited[0].User.Role)
+	assert.NotEmpty(t, result.Invited[0].TemporaryPassword)
+	assert.Len(t, result.Failed, 2)
+	assert.Equal(t, entity.OnboardingStepStatusCompleted, result.Status.Step(entity.OnboardingStepInviteUsers).Status)
+}
+
+func TestOnboardingService_DetectsStepsDoneElsewhere(t *testing.T) {
+	f := newOnboardingFixture()
+	f.channels.Channels["ch1"] = &entity.Channel{ID: "ch1", TenantID: "t1", Type: entity.ChannelTypeTelegram, ConnectionStatus: entity.ConnectionStatusConnected}
+	f.bots.Bots["b1"] = &entity.Bot{ID: "b1", TenantID: "t1"}
+
+	status, err := f.service.GetStatus(context.Background(), "t1")
+	require.NoError(t, err)
+
+	assert.Equal(t, entity.OnboardingStepStatusCompleted, status.Step(entity.OnboardingStepConnectChannel).Status)
+	assert.Equal(t, "ch1", status.Step(entity.OnboardingStepConnectChannel).ResourceID)
+	assert.Equal(t, entity.OnboardingStepStatusCompleted, status.Step(entity.OnboardingStepCreateBot).Status)
+	assert.Equal(t, entity.OnboardingStepStatusPending, status.Step(entity.OnboardingStepInviteUsers).Status)
+	assert.Contains(t, f.repo.progress, "t1")
+}
+
+func TestOnboardingService_SamplesAndTestMessage(t *testing.T) {
+	f := newOnboardingFixture()
+	ctx := context.Background()
+
+	_, err := f.service.SendTestMessage(ctx, "t1", "owner", &TestMessageInput{})
+	assert.True(t, errors.IsValidation(err))
+
+	status, err := f.service.ProvisionSamples(ctx, "t1")
+	require.NoError(t, err)
+	sampleChannel := status.SampleResources[entity.OnboardingSampleChannel]
+	require.NotEmpty(t, sampleChannel)
+	require.NotEmpty(t, status.SampleResources[entity.OnboardingSampleConversation])
+
+	// Provisioning again keeps the same samples
+	again, err := f.service.ProvisionSamples(ctx, "t1")
+	require.NoError(t, err)
+	assert.Equal(t, sampleChannel, again.SampleResources[entity.OnboardingSampleChannel])
+	assert.Len(t, f.channels.Channels, 1)
+
+	// The sample channel does not count as the tenant's first channel
+	status, err = f.service.GetStatus(ctx, "t1")
+	require.NoError(t, err)
+	assert.Equal(t, entity.OnboardingStepStatusPending, status.Step(entity.OnboardingStepConnectChannel).Status)
+
+	result, err := f.service.SendTestMessage(ctx, "t1", "owner", &TestMessageInput{})
+	require.NoError(t, err)
+	assert.Equal(t, onboardingTestMessage, result.Message.Content)
+	assert.Equal(t, "true", result.Message.Metadata["onboarding_test"])
+	assert.Equal(t, result.Message.ID, result.Status.Step(entity.OnboardingStepSendTestMessage).ResourceID)
+}
+
+func TestOnboardingService_SkipStepsFinishesOnboarding(t *testing.T) {
+	f := newOnboardingFixture()
+	ctx := context.Background()
+
+	_, err := f.service.SetupWorkspace(ctx, "t1", &SetupWorkspaceInput{Name: "Acme"})
+	require.NoError(t, err)
+
+	_, err = f.service.SkipStep(ctx, "t1", entity.OnboardingStepWorkspace)
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code)
+	_, err = f.service.SkipStep(ctx, "t1", "unknown")
+	assert.True(t, errors.IsValidation(err))
+
+	var status *OnboardingStatus
+	for _, step := range entity.OnboardingSteps[1:] {
+		status, err = f.service.SkipStep(ctx, "t1", step)
+		require.NoError(t, err)
+	}
+
+	assert.True(t, status.IsFinished())
+	assert.Empty(t, status.CurrentStep)
+	assert.Equal(t, 100, status.PercentComplete)
+	require.NotNil(t, status.CompletedAt)
+
+	events := f.eventTypes()
+	assert.Equal(t, EventOnboardingCompleted, events[len(events)-1])
+	assert.Len(t, f.producer.Events[len(events)-1].Payload["skipped_steps"], 4)
+}
@@ -0,0 +1,667 @@
+package service
+
+import (
+	"context"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+var organizationSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}$`)
+
+// CreateOrganizationInput represents input for turning a tenant into the first
+// workspace of a new organization
+type CreateOrganizationInput struct {
+	Name         string `json:"name" binding:"required"`
+	Slug         string `json:"slug" binding:"required"`
+	BillingEmail string `json:"billing_email"` // Defaults to the caller's email
+}
+
+// UpdateOrganizationInput represents input for updating an organization
+type UpdateOrganizationInput struct {
+	Name         *string `json:"name,omitempty"`
+	BillingEmail *string `json:"billing_email,omitempty"`
+}
+
+// CreateWorkspaceInput represents input for adding a workspace to an organization
+type CreateWorkspaceInput struct {
+	Name string `json:"name" binding:"required"`
+	Slug string `json:"slug" binding:"required"`
+}
+
+// WorkspaceGrant gives a member an account with a role in a workspace
+type WorkspaceGrant struct {
+	TenantID string          `json:"tenant_id" binding:"required"`
+	Role     entity.UserRole `json:"role" binding:"required"`
+}
+
+// AddOrganizationMemberInput represents input for adding a person to an organization
+// or changing their role and workspaces. A person without an account in any of the
+// organization's workspaces needs a name and password.
+type AddOrganizationMemberInput struct {
+	Email      string                  `json:"email" binding:"required,email"`
+	Role       entity.OrganizationRole `json:"role" binding:"required"`
+	Workspaces []WorkspaceGrant        `json:"workspaces"`
+	Name       string                  `json:"name"`
+	Password   string                  `json:"password"`
+}
+
+// OrganizationService manages organizations: tenants grouped as workspaces with shared
+// user accounts and pooled billing. Data stays in the workspaces; a person's account
+// is a user per workspace sharing the email and password, and switching workspaces
+// exchanges the token for one of the account in the other workspace.
+type OrganizationService struct {
+	orgRepo    repository.OrganizationRepository
+	tenantRepo repository.TenantRepository
+	userRepo   repository.UserRepository
+	quotaRepo  repository.QuotaRepository
+	now        func() time.Time
+}
+
+// NewOrganizationService creates a new organization service
+func NewOrganizationService(
+	orgRepo repository.OrganizationRepository,
+	tenantRepo repository.TenantRepository,
+	userRepo repository.UserRepository,
+	quotaRepo repository.QuotaRepository,
+) *OrganizationService {
+	return &OrganizationService{
+		orgRepo:    orgRepo,
+		tenantRepo: tenantRepo,
+		userRepo:   userRepo,
+		quotaRepo:  quotaRepo,
+		now:        time.Now,
+	}
+}
+
+// Create creates an organization with the caller's tenant as its first workspace and
+// the caller, who must own the tenant, as its owner. The organization is billed on
+// the tenant's plan.
+func (s *OrganizationService) Create(ctx context.Context, tenantID, userID string, input *CreateOrganizationInput) (*entity.Organization, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil || user.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeUserNotFound, "User not found")
+	}
+	if user.Role != entity.UserRoleOwner {
+		return nil, errors.Forbidden("Only the tenant owner can create an organization")
+	}
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, errors.New(errors.ErrCodeTenantNotFound, "Tenant not found")
+	}
+	if existing, _ := s.orgRepo.FindByTenant(ctx, tenantID); existing != nil {
+		return nil, errors.Conflict("Tenant already belongs to an organization")
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, errors.Validation("name is required")
+	}
+	slug := strings.ToLower(strings.TrimSpace(input.Slug))
+	if !organizationSlugPattern.MatchString(slug) {
+		return nil, errors.Validation("slug must be 2 to 63 lowercase letters, digits or dashes")
+	}
+	if existing, _ := s.orgRepo.FindBySlug(ctx, slug); existing != nil {
+		return nil, errors.Conflict("Slug already in use")
+	}
+	billingEmail := strings.TrimSpace(input.BillingEmail)
+	if billingEmail == "" {
+		billingEmail = user.Email
+	}
+
+	now := s.now()
+	org := &entity.Organization{
+		ID:           uuid.New().String(),
+		Name:         name,
+		Slug:         slug,
+		Plan:         tenant.Plan,
+		BillingEmail: billingEmail,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	owner := &entity.OrganizationMember{
+		OrganizationID: org.ID,
+		Email:          user.Email,
+		Role:           entity.OrganizationRoleOwner,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := s.orgRepo.Create(ctx, org, tenantID, owner); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// Get returns the organization of a tenant
+func (s *OrganizationService) Get(ctx context.Context, tenantID string) (*entity.Organization, error) {
+	org, err := s.orgRepo.FindByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// Update updates an organization; only its owners and admins can
+func (s *OrganizationService) Update(ctx context.Context, tenantID, userID string, input *UpdateOrganizationInput) (*entity.Organization, error) {
+	org, _, err := s.manager(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Name != nil {
+		name := strings.TrimSpace(*input.Name)
+		if name == "" {
+			return nil, errors.Validation("name is required")
+		}
+		org.Name = name
+	}
+	if input.BillingEmail != nil {
+		billingEmail := strings.TrimSpace(*input.BillingEmail)
+		if billingEmail == "" {
+			return nil, errors.Validation("billing_email is required")
+		}
+		org.BillingEmail = billingEmail
+	}
+	org.UpdatedAt = s.now()
+
+	if err := s.orgRepo.Update(ctx, org); err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// ListWorkspaces lists the workspaces the caller can switch to, with their role in
+// each. Owners and admins also see the workspaces they have no account in.
+func (s *OrganizationService) ListWorkspaces(ctx context.Context, tenantID, userID string) ([]*entity.OrganizationWorkspace, error) {
+	org, member, err := s.member(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	workspaces, err := s.orgRepo.ListWorkspaces(ctx, org.ID)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := s.orgRepo.ListAccounts(ctx, org.ID, member.Email)
+	if err != nil {
+		return nil, err
+	}
+	byTenant := make(map[string]*entity.User, len(accounts))
+	for _, account := range accounts {
+		if account.Status == entity.UserStatusActive {
+			byTenant[account.TenantID] = account
+		}
+	}
+
+	result := make([]*entity.OrganizationWorkspace, 0, len(workspaces))
+	for _, workspace := range workspaces {
+		account, ok := byTenant[workspace.TenantID]
+		if !ok && !member.CanManage() {
+			continue
+		}
+		if ok {
+			workspace.UserID = account.ID
+			workspace.Role = account.Role
+		}
+		workspace.Current = workspace.TenantID == tenantID
+		result = append(result, workspace)
+	}
+	return result, nil
+}
+
+// CreateWorkspace creates a tenant on the organization's plan as a new workspace and
+// gives the caller an owner account in it
+func (s *OrganizationService) CreateWorkspace(ctx context.Context, tenantID, userID string, input *CreateWorkspaceInput) (*entity.OrganizationWorkspace, error) {
+	org, caller, err := s.manager(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return nil, errors.Validation("name is required")
+	}
+	slug := strings.ToLower(strings.TrimSpace(input.Slug))
+	if !organizationSlugPattern.MatchString(slug) {
+		return nil, errors.Validation("slug must be 2 to 63 lowercase letters, digits or dashes")
+	}
+	if existing, _ := s.tenantRepo.FindBySlug(ctx, slug); existing != nil {
+		return nil, errors.Conflict("Slug already in use")
+	}
+
+	tenant := entity.NewTenant(name, slug, org.Plan)
+	tenant.ID = uuid.New().String()
+	if err := s.tenantRepo.Create(ctx, tenant); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "Failed to create workspace")
+	}
+	if err := s.orgRepo.AddWorkspace(ctx, org.ID, tenant.ID); err != nil {
+		_ = s.tenantRepo.Delete(ctx, tenant.ID)
+		return nil, err
+	}
+
+	owner := s.linkedAccount(caller, tenant.ID, caller.Name, entity.UserRoleOwner)
+	if err := s.userRepo.Create(ctx, owner); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "Failed to create workspace owner")
+	}
+
+	return &entity.OrganizationWorkspace{
+		TenantID:  tenant.ID,
+		Name:      tenant.Name,
+		Slug:      tenant.Slug,
+		Plan:      tenant.Plan,
+		Status:    tenant.Status,
+		CreatedAt: tenant.CreatedAt,
+		UserID:    owner.ID,
+		Role:      owner.Role,
+	}, nil
+}
+
+// ListMembers lists the members of the organization with their workspace accounts
+func (s *OrganizationService) ListMembers(ctx context.Context, tenantID, userID string) ([]*entity.OrganizationMember, error) {
+	org, _, err := s.member(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.orgRepo.ListMembers(ctx, org.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, member := range members {
+		if err := s.loadMemberWorkspaces(ctx, member); err != nil {
+			return nil, err
+		}
+	}
+	return members, nil
+}
+
+// AddMember adds a person to the organization or changes their role, and creates or
+// updates their accounts in the granted workspaces. New accounts share the password
+// of the person's existing accounts. Only owners can grant the owner role.
+func (s *OrganizationService) AddMember(ctx context.Context, tenantID, userID string, input *AddOrganizationMemberInput) (*entity.OrganizationMember, error) {
+	org, caller, err := s.manager(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+	callerMember, err := s.orgRepo.FindMember(ctx, org.ID, caller.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	email := strings.TrimSpace(input.Email)
+	if email == "" {
+		return nil, errors.Validation("email is required")
+	}
+	if !input.Role.IsValid() {
+		return nil, errors.Validation("role must be owner, admin or member")
+	}
+	if input.Role == entity.OrganizationRoleOwner && callerMember.Role != entity.OrganizationRoleOwner {
+		return nil, errors.Forbidden("Only owners can add owners")
+	}
+
+	workspaces, err := s.orgRepo.ListWorkspaces(ctx, org.ID)
+	if err != nil {
+		return nil, err
+	}
+	inOrganization := make(map[string]bool, len(workspaces))
+	for _, workspace := range workspaces {
+		inOrganization[workspace.TenantID] = true
+	}
+	for _, grant := range input.Workspaces {
+		if !inOrganization[grant.TenantID] {
+			return nil, errors.Validation("workspace " + grant.TenantID + " is not part of the organization")
+		}
+		if !validWorkspaceRole(grant.Role) {
+			return nil, errors.Validation("invalid workspace role " + string(grant.Role))
+		}
+		if grant.Role == entity.UserRoleOwner && callerMember.Role != entity.OrganizationRoleOwner {
+			return nil, errors.Forbidden("Only owners can grant the owner role")
+		}
+	}
+
+	existing, err := s.orgRepo.FindMember(ctx, org.ID, email)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	if existing != nil && existing.Role == entity.OrganizationRoleOwner && input.Role != entity.OrganizationRoleOwner {
+		if err := s.ensureAnotherOwner(ctx, org.ID, email); err != nil {
+			return nil, err
+		}
+	}
+
+	accounts, err := s.orgRepo.ListAccounts(ctx, org.ID, email)
+	if err != nil {
+		return nil, err
+	}
+	byTenant := make(map[string]*entity.User, len(accounts))
+	for _, account := range accounts {
+		byTenant[account.TenantID] = account
+	}
+
+	// New accounts copy an existing account so the person keeps one password
+	var template *entity.User
+	if len(accounts) > 0 {
+		template = accounts[0]
+	} else if len(input.Workspaces) > 0 {
+		if input.Password == "" || strings.TrimSpace(input.Name) == "" {
+			return nil, errors.Validation("name and password are required for a person without an account")
+		}
+		passwordHash, err := HashPassword(input.Password)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "Failed to hash password")
+		}
+		template = &entity.User{Email: email, Name: strings.TrimSpace(input.Name), PasswordHash: passwordHash}
+	} else {
+		return nil, errors.Validation("a person without an account needs at least one workspace")
+	}
+
+	now := s.now()
+	for _, grant := range input.Workspaces {
+		if account, ok := byTenant[grant.TenantID]; ok {
+			account.Role = grant.Role
+			account.Status = entity.UserStatusActive
+			account.UpdatedAt = now
+			if err := s.userRepo.Update(ctx, account); err != nil {
+				return nil, errors.Wrap(err, errors.ErrCodeInternal, "Failed to update workspace account")
+			}
+			continue
+		}
+		account := s.linkedAccount(template, grant.TenantID, template.Name, grant.Role)
+		if err := s.userRepo.Create(ctx, account); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "Failed to create workspace account")
+		}
+	}
+
+	member := &entity.OrganizationMember{
+		OrganizationID: org.ID,
+		Email:          email,
+		Role:           input.Role,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if existing != nil {
+		member.CreatedAt = existing.CreatedAt
+	}
+	if err := s.orgRepo.SaveMember(ctx, member); err != nil {
+		return nil, err
+	}
+	if err := s.loadMemberWorkspaces(ctx, member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// RemoveMember removes a person from the organization and deactivates their accounts
+// in its workspaces. The last owner cannot be removed.
+func (s *OrganizationService) RemoveMember(ctx context.Context, tenantID, userID, email string) error {
+	org, caller, err := s.manager(ctx, tenantID, userID)
+	if err != nil {
+		return err
+	}
+	email = strings.TrimSpace(email)
+
+	member, err := s.orgRepo.FindMember(ctx, org.ID, email)
+	if err != nil {
+		return err
+	}
+	if member.Role == entity.OrganizationRoleOwner {
+		callerMember, err := s.orgRepo.FindMember(ctx, org.ID, caller.Email)
+		if err != nil {
+			return err
+		}
+		if callerMember.Role != entity.OrganizationRoleOwner {
+			return errors.Forbidden("Only owners can remove owners")
+		}
+		if err := s.ensureAnotherOwner(ctx, org.ID, email); err != nil {
+			return err
+		}
+	}
+
+	accounts, err := s.orgRepo.ListAccounts(ctx, org.ID, email)
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	for _, account := range accounts {
+		if account.Status == entity.UserStatusInactive {
+			continue
+		}
+		account.Status = entity.UserStatusInactive
+		account.UpdatedAt = now
+		if err := s.userRepo.Update(ctx, account); err != nil {
+			return errors.Wrap(err, errors.ErrCodeInternal, "Failed to deactivate workspace account")
+		}
+	}
+
+	return s.orgRepo.DeleteMember(ctx, org.ID, email)
+}
+
+// Usage reports the usage of all workspaces in the current billing period against the
+// organization's pooled limits; only owners and admins can see it
+func (s *OrganizationService) Usage(ctx context.Context, tenantID, userID string) (*entity.OrganizationUsage, error) {
+	org, _, err := s.manager(ctx, tenantID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	workspaces, err := s.orgRepo.ListWorkspaces(ctx, org.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now().UTC()
+	periodStart, periodEnd := entity.QuotaPeriod(now)
+	totals := make(map[entity.QuotaMetric]int64)
+	var storageAdded int64
+
+	usage := &entity.OrganizationUsage{
+		OrganizationID: org.ID,
+		Plan:           org.Plan,
+		PeriodStart:    periodStart,
+		PeriodEnd:      periodEnd,
+		Workspaces:     make([]entity.WorkspaceUsage, 0, len(workspaces)),
+	}
+	for _, workspace := range workspaces {
+		sums, err := s.quotaRepo.SumUsage(ctx, workspace.TenantID, periodStart, periodEnd)
+		if err != nil {
+			return nil, err
+		}
+		storage, added, err := s.quotaRepo.StorageBytes(ctx, workspace.TenantID, periodStart)
+		if err != nil {
+			return nil, err
+		}
+
+		workspaceUsage := entity.WorkspaceUsage{
+			TenantID: workspace.TenantID,
+			Name:     workspace.Name,
+			Usage:    make(map[entity.QuotaMetric]int64, len(entity.QuotaMetrics)),
+		}
+		for _, metric := range entity.QuotaMetrics {
+			used := sums[metric]
+			if metric == entity.QuotaMetricStorage {
+				used = storage
+			}
+			workspaceUsage.Usage[metric] = used
+			totals[metric] += used
+		}
+		storageAdded += added
+		usage.Workspaces = append(usage.Workspaces, workspaceUsage)
+	}
+
+	// Elapsed days include today so the first day of a period has a rate
+	elapsedDays := math.Max(now.Sub(periodStart).Hours()/24, 1)
+
+	usage.Quotas = make([]entity.QuotaStatus, 0, len(entity.QuotaMetrics))
+	for _, metric := range entity.QuotaMetrics {
+		growth := totals[metric]
+		if metric == entity.QuotaMetricStorage {
+			growth = storageAdded
+		}
+		usage.Quotas = append(usage.Quotas, buildQuotaStatus(metric, totals[metric], org.QuotaLimit(metric), float64(growth)/elapsedDays, now, periodEnd))
+	}
+	return usage, nil
+}
+
+// OrganizationID returns the organization a tenant is a workspace of, empty when none
+func (s *OrganizationService) OrganizationID(ctx context.Context, tenantID string) string {
+	org, err := s.orgRepo.FindByTenant(ctx, tenantID)
+	if err != nil {
+		return ""
+	}
+	return org.ID
+}
+
+// WorkspaceAccount returns a user's account in another workspace of their organization
+func (s *OrganizationService) WorkspaceAccount(ctx context.Context, user *entity.User, tenantID string) (*entity.User, error) {
+	org, err := s.orgRepo.FindByTenant(ctx, user.TenantID)
+	if err != nil {
+		return nil, errors.Forbidden("Workspace is not part of your organization")
+	}
+	target, err := s.orgRepo.FindByTenant(ctx, tenantID)
+	if err != nil || target.ID != org.ID {
+		return nil, errors.Forbidden("Workspace is not part of your organization")
+	}
+	if _, err := s.orgRepo.FindMember(ctx, org.ID, user.Email); err != nil {
+		return nil, errors.Forbidden("You are not a member of the organization")
+	}
+
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, errors.New(errors.ErrCodeTenantNotFound, "Tenant not found")
+	}
+	if tenant.Status != entity.TenantStatusActive {
+		return nil, errors.Forbidden("Workspace is not active")
+	}
+
+	account, err := s.userRepo.FindByTenantAndEmail(ctx, tenantID, user.Email)
+	if err != nil || account.Status != entity.UserStatusActive {
+		return nil, errors.Forbidden("You have no account in this workspace")
+	}
+	return account, nil
+}
+
+// SyncPassword copies a user's password to their accounts in the other workspaces of
+// their organization
+func (s *OrganizationService) SyncPassword(ctx context.Context, user *entity.User) error {
+	org, err := s.orgRepo.FindByTenant(ctx, user.TenantID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if _, err := s.orgRepo.FindMember(ctx, org.ID, user.Email); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	accounts, err := s.orgRepo.ListAccounts(ctx, org.ID, user.Email)
+	if err != nil {
+		return err
+	}
+	for _, account := range accounts {
+		if account.ID == user.ID || account.PasswordHash == user.PasswordHash {
+			continue
+		}
+		account.PasswordHash = user.PasswordHash
+		account.UpdatedAt = user.UpdatedAt
+		if err := s.userRepo.Update(ctx, account); err != nil {
+			return errors.Wrap(err, errors.ErrCodeInternal, "Failed to update workspace account")
+		}
+	}
+	return nil
+}
+
+// member resolves the caller's organization and membership
+func (s *OrganizationService) member(ctx context.Context, tenantID, userID string) (*entity.Organization, *entity.OrganizationMember, error) {
+	org, err := s.orgRepo.FindByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil || user.TenantID != tenantID {
+		return nil, nil, errors.New(errors.ErrCodeUserNotFound, "User not found")
+	}
+	member, err := s.orgRepo.FindMember(ctx, org.ID, user.Email)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil, errors.Forbidden("You are not a member of the organization")
+		}
+		return nil, nil, err
+	}
+	return org, member, nil
+}
+
+// manager resolves the caller's organization and account, requiring them to be an
+// owner or admin of the organization
+func (s *OrganizationService) manager(ctx context.Context, tenantID, userID string) (*entity.Organization, *entity.User, error) {
+	org, member, err := s.member(ctx, tenantID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !member.CanManage() {
+		return nil, nil, errors.Forbidden("Only organization owners and admins can do this")
+	}
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, nil, errors.New(errors.ErrCodeUserNotFound, "User not found")
+	}
+	return org, user, nil
+}
+
+// ensureAnotherOwner fails when email is the organization's only owner
+func (s *OrganizationService) ensureAnotherOwner(ctx context.Context, organizationID, email string) error {
+	members, err := s.orgRepo.ListMembers(ctx, organizationID)
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		if member.Role == entity.OrganizationRoleOwner && member.Email != email {
+			return nil
+		}
+	}
+	return errors.Validation("the organization needs at least one other owner")
+}
+
+// loadMemberWorkspaces fills in the workspaces a member has an account in
+func (s *OrganizationService) loadMemberWorkspaces(ctx context.Context, member *entity.OrganizationMember) error {
+	accounts, err := s.orgRepo.ListAccounts(ctx, member.OrganizationID, member.Email)
+	if err != nil {
+		return err
+	}
+	member.Workspaces = make([]entity.MemberWorkspace, 0, len(accounts))
+	for _, account := range accounts {
+		member.Workspaces = append(member.Workspaces, entity.MemberWorkspace{
+			TenantID: account.TenantID,
+			UserID:   account.ID,
+			Role:     account.Role,
+			Status:   account.Status,
+		})
+	}
+	return nil
+}
+
+// linkedAccount builds an account in a workspace sharing the email and password of
+// another account
+func (s *OrganizationService) linkedAccount(from *entity.User, tenantID, name string, role entity.UserRole) *entity.User {
+	account := entity.NewUser(tenantID, from.Email, from.PasswordHash, name, role)
+	account.ID = uuid.New().String()
+	account.AvatarURL = from.AvatarURL
+	return account
+}
+
+func validWorkspaceRole(role entity.UserRole) bool {
+	switch role {
+	case entity.UserRoleAgent, entity.UserRoleSupervisor, entity.UserRoleAdmin, entity.UserRoleOwner, entity.UserRoleFinance:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,401 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeOrganizationRepo struct {
+	mu         sync.Mutex
+	orgs       map[string]*entity.Organization
+	workspaces map[string]string // tenant ID -> organization ID
+	members    map[string]*entity.OrganizationMember
+	tenants    *testutil.MockTenantRepository
+	users      *testutil.MockUserRepository
+}
+
+func newFakeOrganizationRepo(tenants *testutil.MockTenantRepository, users *testutil.MockUserRepository) *fakeOrganizationRepo {
+	return &fakeOrganizationRepo{
+		orgs:       make(map[string]*entity.Organization),
+		workspaces: make(map[string]string),
+		members:    make(map[string]*entity.OrganizationMember),
+		tenants:    tenants,
+		users:      users,
+	}
+}
+
+func (r *fakeOrganizationRepo) Create(ctx context.Context, org *entity.Organization, tenantID string, owner *entity.OrganizationMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *org
+	r.orgs[org.ID] = &copied
+	r.workspaces[tenantID] = org.ID
+	r.members[org.ID+"/"+owner.Email] = owner
+	return nil
+}
+
+func (r *fakeOrganizationRepo) Update(ctx context.Context, org *entity.Organization) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *org
+	r.orgs[org.ID] = &copied
+	return nil
+}
+
+func (r *fakeOrganizationRepo) FindByID(ctx context.Context, id string) (*entity.Organization, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	org, ok := r.orgs[id]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, "organization not found")
+	}
+	copied := *org
+	return &copied, nil
+}
+
+func (r *fakeOrganizationRepo) FindBySlug(ctx context.Context, slug string) (*entity.Organization, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, org := range r.orgs {
+		if org.Slug == slug {
+			copied := *org
+			return &copied, nil
+		}
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "organization not found")
+}
+
+func (r *fakeOrganizationRepo) FindByTenant(ctx context.Context, tenantID string) (*entity.Organization, error) {
+	r.mu.Lock()
+	orgID, ok := r.workspaces[tenantID]
+	r.mu.Unlock()
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, "organization not found")
+	}
+	return r.FindByID(ctx, orgID)
+}
+
+func (r *fakeOrganizationRepo) AddWorkspace(ctx context.Context, organizationID, tenantID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.workspaces[tenantID] = organizationID
+	return nil
+}
+
+func (r *fakeOrganizationRepo) ListWorkspaces(ctx context.Context, organizationID string) ([]*entity.OrganizationWorkspace, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var workspaces []*entity.OrganizationWorkspace
+	for tenantID, orgID := range r.workspaces {
+		if orgID != organizationID {
+			continue
+		}
+		tenant := r.tenants.Tenants[tenantID]
+		workspaces = append(workspaces, &entity.OrganizationWorkspace{
+			TenantID: tenant.ID, Name: tenant.Name, Slug: tenant.Slug, Plan: tenant.Plan, Status: tenant.Status,
+		})
+	}
+	sort.Slice(workspaces, func(i, j int) bool { return workspaces[i].Name < workspaces[j].Name })
+	return workspaces, nil
+}
+
+func (r *fakeOrganizationRepo) SaveMember(ctx context.Context, member *entity.OrganizationMember) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *member
+	r.members[member.OrganizationID+"/"+member.Email] = &copied
+	return nil
+}
+
+func (r *fakeOrganizationRepo) FindMember(ctx context.Context, organizationID, email string) (*entity.OrganizationMember, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	member, ok := r.members[organizationID+"/"+email]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, "organization member not found")
+	}
+	copied := *member
+	return &copied, nil
+}
+
+func (r *fakeOrganizationRepo) ListMembers(ctx context.Context, organizationID string) ([]*entity.OrganizationMember, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var members []*entity.OrganizationMember
+	for _, member := range r.members {
+		if member.OrganizationID == organizationID {
+			copied := *member
+			members = append(members, &copied)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].Email < members[j].Email })
+	return members, nil
+}
+
+func (r *fakeOrganizationRepo) DeleteMember(ctx context.Context, organizationID, email string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := organizationID + "/" + email
+	if _, ok := r.members[key]; !ok {
+		return errors.New(errors.ErrCodeNotFound, "organization member not found")
+	}
+	delete(r.members, key)
+	return nil
+}
+
+func (r *fakeOrganizationRepo) ListAccounts(ctx context.Context, organizationID, email string) ([]*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var accounts []*entity.User
+	for _, user := range r.users.Users {
+		if user.Email == email && r.workspaces[user.TenantID] == organizationID {
+			accounts = append(accounts, user)
+		}
+	}
+	sort.Slice(accounts, func(i, j int) bool { return accounts[i].TenantID < accounts[j].TenantID })
+	return accounts, nil
+}
+
+type organizationFixture struct {
+	service *OrganizationService
+	orgs    *fakeOrganizationRepo
+	tenants *testutil.MockTenantRepository
+	users   *testutil.MockUserRepository
+	quotas  *fakeQuotaRepo
+	owner   *entity.User
+	org     *entity.Organization
+	now     time.Time
+}
+
+// newOrganizationFixture creates an organization for tenant-1 owned by owner@agency.com
+func newOrganizationFixture(t *testing.T) *organizationFixture {
+	t.Helper()
+	tenants := testutil.NewMockTenantRepository()
+	users := testutil.NewMockUserRepository()
+	f := &organizationFixture{
+		orgs:    newFakeOrganizationRepo(tenants, users),
+		tenants: tenants,
+		users:   users,
+		quotas:  newFakeQuotaRepo(),
+		now:     time.Date(2026, 6, 11, 12, 0, 0, 0, time.UTC),
+	}
+	f.service = NewOrganizationService(f.orgs, tenants, users, f.quotas)
+	f.service.now = func() time.Time { return f.now }
+
+	tenant := entity.NewTenant("Brand A", "brand-a", entity.PlanProfessional)
+	tenant.ID = "tenant-1"
+	tenants.Tenants[tenant.ID] = tenant
+
+	f.owner = entity.NewUser(tenant.ID, "owner@agency.com", "hash-1", "Olivia", entity.UserRoleOwner)
+	f.owner.ID = "user-1"
+	users.Users[f.owner.ID] = f.owner
+
+	org, err := f.service.Create(context.Background(), tenant.ID, f.owner.ID, &CreateOrganizationInput{Name: "Agency", Slug: "agency"})
+	require.NoError(t, err)
+	f.org = org
+	return f
+}
+
+func TestOrganizationService_Create(t *testing.T) {
+	f := newOrganizationFixture(t)
+	ctx := context.Background()
+
+	assert.Equal(t, entity.PlanProfessional, f.org.Plan, "the organization is billed on the tenant's plan")
+	assert.Equal(t, "owner@agency.com", f.org.BillingEmail)
+
+	member, err := f.orgs.FindMember(ctx, f.org.ID, "owner@agency.com")
+	require.NoError(t, err)
+	assert.Equal(t, entity.OrganizationRoleOwner, member.Role)
+
+	_, err = f.service.Create(ctx, "tenant-1", f.owner.ID, &CreateOrganizationInput{Name: "Again", Slug: "again"})
+	assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code)
+
+	other := entity.NewTenant("Solo", "solo", entity.PlanStarter)
+	other.ID = "tenant-2"
+	f.tenants.Tenants[other.ID] = other
+	admin := entity.NewUser(other.ID, "admin@solo.com", "hash-2", "Adam", entity.UserRoleAdmin)
+	admin.ID = "user-2"
+	f.users.Users[admin.ID] = admin
+
+	_, err = f.service.Create(ctx, other.ID, admin.ID, &CreateOrganizationInput{Name: "Solo", Slug: "solo"})
+	assert.Equal(t, errors.ErrCodeForbidden, errors.GetAppError(err).Code, "only tenant owners create organizations")
+
+	admin.Role = entity.UserRoleOwner
+	_, err = f.service.Create(ctx, other.ID, admin.ID, &CreateOrganizationInput{Name: "Solo", Slug: "agency"})
+	assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code, "slugs are unique")
+}
+
+func TestOrganizationService_CreateWorkspace(t *testing.T) {
+	f := newOrganizationFixture(t)
+	ctx := context.Background()
+
+	workspace, err := f.service.CreateWorkspace(ctx, "tenant-1", f.owner.ID, &CreateWorkspaceInput{Name: "Brand B", Slug: "brand-b"})
+	require.NoError(t, err)
+	assert.Equal(t, entity.PlanProfessional, workspace.Plan)
+	assert.Equal(t, entity.UserRoleOwner, workspace.Role)
+
+	account := f.users.Users[workspace.UserID]
+	require.NotNil(t, account)
+	assert.Equal(t, workspace.TenantID, account.TenantID)
+	assert.Equal(t, "owner@agency.com", account.Email)
+	assert.Equal(t, "hash-1", account.PasswordHash, "the account shares the password")
+
+	_, err = f.service.CreateWorkspace(ctx, "tenant-1", f.owner.ID, &CreateWorkspaceInput{Name: "Brand A again", Slug: "brand-a"})
+	assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code)
+
+	workspaces, err := f.service.ListWorkspaces(ctx, "tenant-1", f.owner.ID)
+	require.NoError(t, err)
+	require.Len(t, workspaces, 2)
+	assert.True(t, workspaces[0].Current)
+	assert.False(t, workspaces[1].Current)
+}
+
+func TestOrganizationService_Members(t *testing.T) {
+	f := newOrganizationFixture(t)
+	ctx := context.Background()
+
+	brandB, err := f.service.CreateWorkspace(ctx, "tenant-1", f.owner.ID, &CreateWorkspaceInput{Name: "Brand B", Slug: "brand-b"})
+	require.NoError(t, err)
+
+	// A new person needs a password
+	_, err = f.service.AddMember(ctx, "tenant-1", f.owner.ID, &AddOrganizationMemberInput{
+		Email:      "agent@agency.com",
+		Role:       entity.OrganizationRoleMember,
+		Workspaces: []WorkspaceGrant{{TenantID: brandB.TenantID, Role: entity.UserRoleAgent}},
+	})
+	assert.Equal(t, errors.ErrCodeValidation, errors.GetAppError(err).Code)
+
+	member, err := f.service.AddMember(ctx, "tenant-1", f.owner.ID, &AddOrganizationMemberInput{
+		Email:      "agent@agency.com",
+		Role:       entity.OrganizationRoleMember,
+		Name:       "Ana",
+		Password:   "secret-password",
+		Workspaces: []WorkspaceGrant{{TenantID: brandB.TenantID, Role: entity.UserRoleAgent}},
+	})
+	require.NoError(t, err)
+	require.Len(t, member.Workspaces, 1)
+	first := f.users.Users[member.Workspaces[0].UserID]
+
+	// Granting another workspace reuses the password
+	member, err = f.service.AddMember(ctx, "tenant-1", f.owner.ID, &AddOrganizationMemberInput{
+		Email:      "agent@agency.com",
+		Role:       entity.OrganizationRoleMember,
+		Workspaces: []WorkspaceGrant{{TenantID: "tenant-1", Role: entity.UserRoleSupervisor}},
+	})
+	require.NoError(t, err)
+	require.Len(t, member.Workspaces, 2)
+	second, err := f.users.FindByTenantAndEmail(ctx, "tenant-1", "agent@agency.com")
+	require.NoError(t, err)
+	assert.Equal(t, entity.UserRoleSupervisor, second.Role)
+	assert.Equal(t, first.PasswordHash, second.PasswordHash)
+
+	// Members see only the workspaces they have an account in and cannot manage
+	workspaces, err := f.service.ListWorkspaces(ctx, brandB.TenantID, first.ID)
+	require.NoError(t, err)
+	assert.Len(t, workspaces, 2)
+
+	_, err = f.service.Usage(ctx, brandB.TenantID, first.ID)
+	assert.Equal(t, errors.ErrCodeForbidden, errors.GetAppError(err).Code)
+
+	_, err = f.service.AddMember(ctx, "tenant-1", f.owner.ID, &AddOrganizationMemberInput{
+		Email:      "agent@agency.com",
+		Role:       entity.OrganizationRoleMember,
+		Workspaces: []WorkspaceGrant{{TenantID: "tenant-elsewhere", Role: entity.UserRoleAgent}},
+	})
+	assert.Equal(t, errors.ErrCodeValidation, errors.GetAppError(err).Code, "only workspaces of the organization")
+
+	// The last owner stays; removing a member deactivates their accounts
+	err = f.service.RemoveMember(ctx, "tenant-1", f.owner.ID, "owner@agency.com")
+	assert.Equal(t, errors.ErrCodeValidation, errors.GetAppError(err).Code)
+
+	require.NoError(t, f.service.RemoveMember(ctx, "tenant-1", f.owner.ID, "agent@agency.com"))
+	assert.Equal(t, entity.UserStatusInactive, first.Status)
+	assert.Equal(t, entity.UserStatusInactive, second.Status)
+
+	members, err := f.service.ListMembers(ctx, "tenant-1", f.owner.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	assert.Equal(t, "owner@agency.com", members[0].Email)
+}
+
+func TestOrganizationService_Usage(t *testing.T) {
+	f := newOrganizationFixture(t)
+	ctx := context.Background()
+
+	brandB, err := f.service.CreateWorkspace(ctx, "tenant-1", f.owner.ID, &CreateWorkspaceInput{Name: "Brand B", Slug: "brand-b"})
+	require.NoError(t, err)
+
+	day := time.Date(2026, 6, 3, 0, 0, 0, 0, time.UTC)
+	f.quotas.usage = []entity.QuotaUsage{
+		{TenantID: "tenant-1", Metric: entity.QuotaMetricAIRequests, Day: day, Count: 300},
+		{TenantID: brandB.TenantID, Metric: entity.QuotaMetricAIRequests, Day: day, Count: 200},
+		{TenantID: brandB.TenantID, Metric: entity.QuotaMetricAIRequests, Day: day.AddDate(0, -1, 0), Count: 900},
+	}
+	limit := int64(1000)
+	f.org.Limits = &entity.TenantLimits{MaxAIRequestsPerMonth: limit}
+	require.NoError(t, f.orgs.Update(ctx, f.org))
+
+	usage, err := f.service.Usage(ctx, "tenant-1", f.owner.ID)
+	require.NoError(t, err)
+	require.Len(t, usage.Workspaces, 2)
+	assert.Equal(t, int64(300), usage.Workspaces[0].Usage[entity.QuotaMetricAIRequests])
+	assert.Equal(t, int64(200), usage.Workspaces[1].Usage[entity.QuotaMetricAIRequests])
+
+	var ai *entity.QuotaStatus
+	for i := range usage.Quotas {
+		if usage.Quotas[i].Metric == entity.QuotaMetricAIRequests {
+			ai = &usage.Quotas[i]
+		}
+	}
+	require.NotNil(t, ai)
+	assert.Equal(t, int64(500), ai.Used, "usage is pooled across workspaces")
+	assert.Equal(t, limit, ai.Limit)
+	assert.Equal(t, int64(500), ai.Remaining)
+}
+
+func TestAuthService_SwitchWorkspace(t *testing.T) {
+	f := newOrganizationFixture(t)
+	ctx := context.Background()
+
+	brandB, err := f.service.CreateWorkspace(ctx, "tenant-1", f.owner.ID, &CreateWorkspaceInput{Name: "Brand B", Slug: "brand-b"})
+	require.NoError(t, err)
+
+	auth, _ := newTestAuthService()
+	auth.userRepo = f.users
+
+	_, err = auth.SwitchWorkspace(ctx, f.owner.ID, brandB.TenantID)
+	assert.Equal(t, errors.ErrCodeForbidden, errors.GetAppError(err).Code, "switching needs the workspace directory")
+
+	auth.SetWorkspaceDirectory(f.service)
+	result, err := auth.SwitchWorkspace(ctx, f.owner.ID, brandB.TenantID)
+	require.NoError(t, err)
+	assert.Equal(t, brandB.UserID, result.User.ID)
+
+	claims, err := auth.ValidateAccessToken(result.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, brandB.TenantID, claims.TenantID)
+	assert.Equal(t, f.org.ID, claims.OrganizationID)
+
+	// A workspace outside the organization is off limits
+	outside := entity.NewTenant("Other", "other", entity.PlanFree)
+	outside.ID = "tenant-9"
+	f.tenants.Tenants[outside.ID] = outside
+	_, err = auth.SwitchWorkspace(ctx, f.owner.ID, outside.ID)
+	assert.Equal(t, errors.ErrCodeForbidden, errors.GetAppError(err).Code)
+
+	// Changing the password changes it in every workspace
+	hash, err := HashPassword("old-password")
+	require.NoError(t, err)
+	f.owner.PasswordHash = hash
+	require.NoError(t, auth.ChangePassword(ctx, f.owner.ID, "old-password", "new-password"))
+	assert.Equal(t, f.owner.PasswordHash, f.users.Users[brandB.UserID].PasswordHash)
+}
@@ -0,0 +1,377 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Events published when a postback is resolved
+const (
+	EventPostbackRuleTriggered = "postback.rule_triggered"
+	EventPostbackVoteRecorded  = "postback.vote_recorded"
+)
+
+const (
+	// PostbackTokenPrefix marks button and row IDs issued by the postback registry
+	PostbackTokenPrefix = "pb_"
+	// DefaultPostbackTTL is how long a postback stays valid when no TTL is given
+	DefaultPostbackTTL = 7 * 24 * time.Hour
+	// MaxPostbackTTL is the longest validity a postback can be registered with
+	MaxPostbackTTL = 90 * 24 * time.Hour
+	// postbackSignatureBytes is the truncated HMAC length; tokens must fit Telegram's
+	// 64-byte callback data
+	postbackSignatureBytes = 12
+)
+
+// postbackMetadataKeys are the inbound message metadata keys that may carry the ID
+// of the button, list row or quick reply that was tapped
+var postbackMetadataKeys = []string{"button_id", "list_id", "button_payload", "quick_reply", "postback_data"}
+
+// RegisterPostbackInput represents input for registering a postback
+type RegisterPostbackInput struct {
+	ConversationID string                `json:"conversation_id,omitempty"`
+	Action         entity.PostbackAction `json:"action" binding:"required"`
+	Target         string                `json:"target" binding:"required"`
+	NodeID         string                `json:"node_id,omitempty"`
+	Payload        map[string]string     `json:"payload,omitempty"`
+	SingleUse      bool                  `json:"single_use"`
+	TTLSeconds     int                   `json:"ttl_seconds,omitempty"`
+}
+
+// RegisteredPostback is a stored postback and the signed token to use as button or row ID
+type RegisteredPostback struct {
+	Token    string           `json:"token"`
+	Postback *entity.Postback `json:"postback"`
+}
+
+// PostbackResult describes what a postback reply resumed
+type PostbackResult struct {
+	Postback *entity.Postback            `json:"postback"`
+	Flow     *entity.FlowExecutionResult `json:"flow,omitempty"`
+	Vote     *entity.PostbackVote        `json:"vote,omitempty"`
+	Feedback *entity.KnowledgeFeedback   `json:"feedback,omitempty"`
+}
+
+// AnswerFeedbackRecorder records the customer's rating of a bot answer
+type AnswerFeedbackRecorder interface {
+	RecordAnswerFeedback(ctx context.Context, postback *entity.Postback, message *entity.Message, conversation *entity.Conversation) (*entity.KnowledgeFeedback, error)
+}
+
+// PostbackService maps interactive reply IDs to the automation they resume: a flow
+// node, an automation rule or a poll vote. Tokens are HMAC-signed so replies cannot
+// be forged to resume arbitrary postbacks, and expire after their TTL.
+type PostbackService struct {
+	repo           repository.PostbackRepository
+	flowEngine     *FlowEngineService
+	contextService *ConversationContextService
+	producer       nats.Publisher
+	secret         []byte
+	feedback       AnswerFeedbackRecorder
+}
+
+// NewPostbackService creates a new postback service
+func NewPostbackService(
+	repo repository.PostbackRepository,
+	flowEngine *FlowEngineService,
+	contextService *ConversationContextService,
+	producer nats.Publisher,
+	secret string,
+) *PostbackService {
+	return &PostbackService{
+		repo:           repo,
+		flowEngine:     flowEngine,
+		contextService: contextService,
+		producer:       producer,
+		secret:         []byte(secret),
+	}
+}
+
+// SetAnswerFeedbackRecorder sets the recorder of answer feedback postbacks
+func (s *PostbackService) SetAnswerFeedbackRecorder(recorder AnswerFeedbackRecorder) {
+	s.feedback = recorder
+}
+
+// Register stores a postback and returns its signed token
+func (s *PostbackService) Register(ctx context.Context, tenantID string, input *RegisterPostbackInput) (*RegisteredPostback, error) {
+	if !input.Action.IsValid() {
+		return nil, errors.Validation("invalid postback action")
+	}
+	if input.Target == "" {
+		return nil, errors.Validation("target is required")
+	}
+	if input.TTLSeconds < 0 {
+		return nil, errors.Validation("ttl_seconds must not be negative")
+	}
+
+	ttl := DefaultPostbackTTL
+	if input.TTLSeconds > 0 {
+		ttl = time.Duration(input.TTLSeconds) * time.Second
+	}
+	if ttl > MaxPostbackTTL {
+		ttl = MaxPostbackTTL
+	}
+
+	now := time.Now()
+	postback := &entity.Postback{
+		ID:             uuid.New().String(),
+		TenantID:       tenantID,
+		ConversationID: input.ConversationID,
+		Action:         input.Action,
+		Target:         input.Target,
+		NodeID:         input.NodeID,
+		Payload:        input.Payload,
+		SingleUse:      input.SingleUse,
+		ExpiresAt:      now.Add(ttl),
+		CreatedAt:      now,
+	}
+
+	if err := s.repo.Create(ctx, postback); err != nil {
+		return nil, err
+	}
+
+	return &RegisteredPostback{Token: s.sign(postback.ID), Postback: postback}, nil
+}
+
+// IsPostbackToken reports whether a reply ID was issued by the registry
+func IsPostbackToken(id string) bool {
+	return strings.HasPrefix(id, PostbackTokenPrefix)
+}
+
+// Route resolves the postback carried by an inbound message, if any. It returns nil
+// when the message is not a reply to a registered postback.
+func (s *PostbackService) Route(ctx context.Context, message *entity.Message, conversation *entity.Conversation) (*PostbackResult, error) {
+	token := postbackTokenFromMetadata(message.Metadata)
+	if token == "" {
+		return nil, nil
+	}
+
+	result, err := s.Resolve(ctx, conversation.TenantID, token, message, conversation)
+	if err != nil {
+		logger.Warn("Failed to route postback",
+			zap.String("message_id", message.ID),
+			zap.String("conversation_id", conversation.ID),
+			zap.Error(err),
+		)
+		return nil, err
+	}
+	return result, nil
+}
+
+// Resolve verifies a postback token and dispatches it to its action
+func (s *PostbackService) Resolve(ctx context.Context, tenantID, token string, message *entity.Message, conversation *entity.Conversation) (*PostbackResult, error) {
+	id, err := s.verify(token)
+	if err != nil {
+		return nil, err
+	}
+
+	postback, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if postback.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeNotFound, "postback not found")
+	}
+	if postback.ConversationID != "" && postback.ConversationID != conversation.ID {
+		return nil, errors.New(errors.ErrCodeForbidden, "postback belongs to another conversation")
+	}
+
+	now := time.Now()
+	if postback.IsExpired(now) {
+		return nil, errors.New(errors.ErrCodeBadRequest, "postback expired")
+	}
+
+	marked, err := s.repo.MarkUsed(ctx, postback.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !marked {
+		return nil, errors.New(errors.ErrCodeConflict, "postback already used")
+	}
+	postback.UsedAt = &now
+
+	result := &PostbackResult{Postback: postback}
+	switch postback.Action {
+	case entity.PostbackActionContinueFlow:
+		result.Flow, err = s.continueFlow(ctx, postback, message, conversation)
+	case entity.PostbackActionTriggerRule:
+		s.triggerRule(ctx, postback, message, conversation)
+	case entity.PostbackActionRecordVote:
+		result.Vote, err = s.recordVote(ctx, postback, message, conversation)
+	case entity.PostbackActionAnswerFeedback:
+		if s.feedback == nil {
+			err = errors.New(errors.ErrCodeBadRequest, "answer feedback is not enabled")
+			break
+		}
+		result.Feedback, err = s.feedback.RecordAnswerFeedback(ctx, postback, message, conversation)
+	default:
+		err = errors.New(errors.ErrCodeBadRequest, "unsupported postback action")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetPollResults returns the vote counts of a poll
+func (s *PostbackService) GetPollResults(ctx context.Context, tenantID, pollID string) (*entity.PollResult, error) {
+	return s.repo.CountVotes(ctx, tenantID, pollID)
+}
+
+// CleanupExpired deletes postbacks that expired before now
+func (s *PostbackService) CleanupExpired(ctx context.Context) (int64, error) {
+	return s.repo.DeleteExpired(ctx, time.Now())
+}
+
+// continueFlow resumes the target flow at the postback node and publishes the flow
+// output as a bot response
+func (s *PostbackService) continueFlow(ctx context.Context, postback *entity.Postback, message *entity.Message, conversation *entity.Conversation) (*entity.FlowExecutionResult, error) {
+	if s.flowEngine == nil || s.contextService == nil {
+		return nil, errors.New(errors.ErrCodeInternal, "flow engine is not configured")
+	}
+
+	convContext, err := s.contextService.GetOrCreate(ctx, conversation.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range postback.Payload {
+		s.flowEngine.StoreCollectedData(convContext, key, value)
+	}
+
+	result, err := s.flowEngine.ResumeAtNode(ctx, postback.TenantID, postback.Target, postback.NodeID, message.Content, convContext)
+	if err != nil {
+		return nil, err
+	}
+	s.flowEngine.RecordTrace(ctx, message, conversation, postback.Target, result, convContext)
+
+	if err := s.contextService.save(ctx, convContext); err != nil {
+		return nil, err
+	}
+
+	s.publish(ctx, nats.EventBotResponse, postback, message, conversation, map[string]interface{}{
+		"flow_id":       postback.Target,
+		"node_id":       postback.NodeID,
+		"response":      result.Message,
+		"quick_replies": result.QuickReplies,
+		"flow_ended":    result.FlowEnded,
+	})
+
+	return result, nil
+}
+
+// triggerRule publishes the rule trigger for automation consumers
+func (s *PostbackService) triggerRule(ctx context.Context, postback *entity.Postback, message *entity.Message, conversation *entity.Conversation) {
+	s.publish(ctx, EventPostbackRuleTriggered, postback, message, conversation, map[string]interface{}{
+		"rule_id": postback.Target,
+		"payload": postback.Payload,
+	})
+}
+
+// recordVote stores the contact's vote; the option comes from the postback payload
+// and falls back to the reply text
+func (s *PostbackService) recordVote(ctx context.Context, postback *entity.Postback, message *entity.Message, conversation *entity.Conversation) (*entity.PostbackVote, error) {
+	option := postback.Payload["option"]
+	if option == "" {
+		option = message.Content
+	}
+	if option == "" {
+		return nil, errors.New(errors.ErrCodeBadRequest, "vote option is empty")
+	}
+
+	vote := &entity.PostbackVote{
+		ID:             uuid.New().String(),
+		TenantID:       postback.TenantID,
+		PollID:         postback.Target,
+		Option:         option,
+		ContactID:      conversation.ContactID,
+		ConversationID: conversation.ID,
+		MessageID:      message.ID,
+		CreatedAt:      time.Now(),
+	}
+	if err := s.repo.RecordVote(ctx, vote); err != nil {
+		return nil, err
+	}
+
+	s.publish(ctx, EventPostbackVoteRecorded, postback, message, conversation, map[string]interface{}{
+		"poll_id": vote.PollID,
+		"option":  vote.Option,
+	})
+
+	return vote, nil
+}
+
+func (s *PostbackService) publish(ctx context.Context, eventType string, postback *entity.Postback, message *entity.Message, conversation *entity.Conversation, extra map[string]interface{}) {
+	if s.producer == nil {
+		return
+	}
+
+	payload := map[string]interface{}{
+		"postback_id":     postback.ID,
+		"action":          string(postback.Action),
+		"message_id":      message.ID,
+		"conversation_id": conversation.ID,
+		"contact_id":      conversation.ContactID,
+		"channel_id":      conversation.ChannelID,
+	}
+	for k, v := range extra {
+		payload[k] = v
+	}
+
+	s.producer.PublishEvent(ctx, &nats.Event{
+		Type:      eventType,
+		TenantID:  postback.TenantID,
+		Payload:   payload,
+		Timestamp: time.Now(),
+	})
+}
+
+// sign builds the token of a postback: prefix, ID and a truncated HMAC of the ID
+func (s *PostbackService) sign(id string) string {
+	return PostbackTokenPrefix + id + "." + s.signature(id)
+}
+
+// verify checks a token signature and returns the postback ID
+func (s *PostbackService) verify(token string) (string, error) {
+	if !IsPostbackToken(token) {
+		return "", errors.New(errors.ErrCodeBadRequest, "not a postback token")
+	}
+
+	id, signature, ok := strings.Cut(strings.TrimPrefix(token, PostbackTokenPrefix), ".")
+	if !ok || id == "" {
+		return "", errors.New(errors.ErrCodeBadRequest, "malformed postback token")
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.signature(id))) {
+		return "", errors.New(errors.ErrCodeUnauthorized, "invalid postback signature")
+	}
+
+	return id, nil
+}
+
+func (s *PostbackService) signature(id string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:postbackSignatureBytes])
+}
+
+// postbackTokenFromMetadata returns the first postback token found in the reply metadata
+func postbackTokenFromMetadata(metadata map[string]string) string {
+	for _, key := range postbackMetadataKeys {
+		if value := metadata[key]; IsPostbackToken(value) {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPostbackRepository struct {
+	postbacks map[string]*entity.Postback
+	votes     map[string]*entity.PostbackVote
+}
+
+func newMockPostbackRepository() *mockPostbackRepository {
+	return &mockPostbackRepository{
+		postbacks: make(map[string]*entity.Postback),
+		votes:     make(map[string]*entity.PostbackVote),
+	}
+}
+
+func (m *mockPostbackRepository) Create(ctx context.Context, postback *entity.Postback) error {
+	m.postbacks[postback.ID] = postback
+	return nil
+}
+
+func (m *mockPostbackRepository) FindByID(ctx context.Context, id string) (*entity.Postback, error) {
+	postback, ok := m.postbacks[id]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, "postback not found")
+	}
+	copied := *postback
+	return &copied, nil
+}
+
+func (m *mockPostbackRepository) MarkUsed(ctx context.Context, id string, usedAt time.Time) (bool, error) {
+	postback, ok := m.postbacks[id]
+	if !ok || postback.IsConsumed() {
+		return false, nil
+	}
+	postback.UsedAt = &usedAt
+	return true, nil
+}
+
+func (m *mockPostbackRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	for id, postback := range m.postbacks {
+		if postback.ExpiresAt.Before(before) {
+			delete(m.postbacks, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (m *mockPostbackRepository) RecordVote(ctx context.Context, vote *entity.PostbackVote) error {
+	m.votes[vote.TenantID+"/"+vote.PollID+"/"+vote.ContactID] = vote
+	return nil
+}
+
+func (m *mockPostbackRepository) CountVotes(ctx context.Context, tenantID, pollID string) (*entity.PollResult, error) {
+	result := &entity.PollResult{PollID: pollID, Votes: make(map[string]int64)}
+	for _, vote := range m.votes {
+		if vote.TenantID == tenantID && vote.PollID == pollID {
+			result.Votes[vote.Option]++
+			result.Total++
+		}
+	}
+	return result, nil
+}
+
+func newTestPostbackService() (*PostbackService, *mockPostbackRepository, *mockFlowRepo, *testutil.MockProducer) {
+	flowEngine, flowRepo, _ := newFlowEngine()
+	repo := newMockPostbackRepository()
+	producer := testutil.NewMockProducer()
+	contextService := NewConversationContextService(newMockConversationContextRepository(), nil)
+	svc := NewPostbackService(repo, flowEngine, contextService, producer, "test-secret")
+	return svc, repo, flowRepo, producer
+}
+
+// routeReply routes a button reply carrying the given ID
+func routeReply(svc *PostbackService, token, content string) (*PostbackResult, error) {
+	message := &entity.Message{ID: "msg-1", Content: content, Metadata: map[string]string{"button_id": token}}
+	conversation := &entity.Conversation{ID: "conv-1", TenantID: "tenant-1", ChannelID: "channel-1", ContactID: "contact-1"}
+	return svc.Route(context.Background(), message, conversation)
+}
+
+func TestPostbackContinuesFlowAtNode(t *testing.T) {
+	svc, _, flowRepo, producer := newTestPostbackService()
+	flow := makeSimpleFlow("tenant-1")
+	flowRepo.flows[flow.ID] = flow
+
+	registered, err := svc.Register(context.Background(), "tenant-1", &RegisterPostbackInput{
+		Action: entity.PostbackActionContinueFlow,
+		Target: flow.ID,
+		NodeID: "q-1",
+	})
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(registered.Token), 64)
+
+	result, err := routeReply(svc, registered.Token, "Start")
+	require.NoError(t, err)
+	require.NotNil(t, result.Flow)
+	assert.Equal(t, "Would you like to continue?", result.Flow.Message)
+	assert.True(t, result.Flow.ShouldWait)
+
+	require.Len(t, producer.Events, 1)
+	assert.Equal(t, nats.EventBotResponse, producer.Events[0].Type)
+	assert.Equal(t, flow.ID, producer.Events[0].Payload["flow_id"])
+}
+
+func TestPostbackRecordsVotes(t *testing.T) {
+	svc, _, _, producer := newTestPostbackService()
+
+	yes, err := svc.Register(context.Background(), "tenant-1", &RegisterPostbackInput{
+		Action:  entity.PostbackActionRecordVote,
+		Target:  "poll-1",
+		Payload: map[string]string{"option": "yes"},
+	})
+	require.NoError(t, err)
+	no, err := svc.Register(context.Background(), "tenant-1", &RegisterPostbackInput{
+		Action:  entity.PostbackActionRecordVote,
+		Target:  "poll-1",
+		Payload: map[string]string{"option": "no"},
+	})
+	require.NoError(t, err)
+
+	_, err = routeReply(svc, yes.Token, "Yes")
+	require.NoError(t, err)
+	// Voting again replaces the contact's previous vote
+	_, err = routeReply(svc, no.Token, "No")
+	require.NoError(t, err)
+
+	results, err := svc.GetPollResults(context.Background(), "tenant-1", "poll-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), results.Total)
+	assert.Equal(t, int64(1), results.Votes["no"])
+	assert.Equal(t, EventPostbackVoteRecorded, producer.Events[0].Type)
+}
+
+func TestPostbackTriggersRule(t *testing.T) {
+	svc, _, _, producer := newTestPostbackService()
+
+	registered, err := svc.Register(context.Background(), "tenant-1", &RegisterPostbackInput{
+		Action: entity.PostbackActionTriggerRule,
+		Target: "rule-1",
+	})
+	require.NoError(t, err)
+
+	_, err = routeReply(svc, registered.Token, "Go")
+	require.NoError(t, err)
+	require.Len(t, producer.Events, 1)
+	assert.Equal(t, EventPostbackRuleTriggered, producer.Events[0].Type)
+	assert.Equal(t, "rule-1", producer.Events[0].Payload["rule_id"])
+}
+
+func TestPostbackRejectsTamperedToken(t *testing.T) {
+	svc, _, _, _ := newTestPostbackService()
+
+	registered, err := svc.Register(context.Background(), "tenant-1", &RegisterPostbackInput{
+		Action: entity.PostbackActionTriggerRule,
+		Target: "rule-1",
+	})
+	require.NoError(t, err)
+
+	tampered := registered.Token[:len(registered.Token)-1] + "x"
+	if tampered == registered.Token {
+		tampered = registered.Token[:len(registered.Token)-1] + "y"
+	}
+
+	_, err = routeReply(svc, tampered, "Go")
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeUnauthorized, errors.GetAppError(err).Code)
+}
+
+func TestPostbackRejectsExpiredAndReusedTokens(t *testing.T) {
+	svc, repo, _, _ := newTestPostbackService()
+
+	registered, err := svc.Register(context.Background(), "tenant-1", &RegisterPostbackInput{
+		Action:    entity.PostbackActionTriggerRule,
+		Target:    "rule-1",
+		SingleUse: true,
+	})
+	require.NoError(t, err)
+
+	_, err = routeReply(svc, registered.Token, "Go")
+	require.NoError(t, err)
+	_, err = routeReply(svc, registered.Token, "Go")
+	assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code)
+
+	expiring, err := svc.Register(context.Background(), "tenant-1", &RegisterPostbackInput{
+		Action: entity.PostbackActionTriggerRule,
+		Target: "rule-1",
+	})
+	require.NoError(t, err)
+	repo.postbacks[expiring.Postback.ID].ExpiresAt = time.Now().Add(-time.Minute)
+
+	_, err = routeReply(svc, expiring.Token, "Go")
+	assert.Error(t, err)
+
+	deleted, err := svc.CleanupExpired(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
+
+func TestPostbackIgnoresPlainReplies(t *testing.T) {
+	svc, _, _, producer := newTestPostbackService()
+
+	result, err := routeReply(svc, "yes", "Yes")
+	require.NoError(t, err)
+	assert.Nil(t, result)
+	assert.Empty(t, producer.Events)
+}
@@ -0,0 +1,361 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/adapters/webchat"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// PublicChatSecretCredential is the webchat channel credential holding the secret
+// that tenant backends use to sign contact IDs for the public chat API
+const PublicChatSecretCredential = "public_api_secret"
+
+const (
+	maxPublicChatContactIDLength = 128
+	defaultPublicChatPollLimit   = 50
+	maxPublicChatPollLimit       = 200
+)
+
+// PublicChatSession is a contact authenticated on a webchat channel through the
+// public chat API
+type PublicChatSession struct {
+	Channel   *entity.Channel
+	ContactID string // The tenant's own ID for the contact, as signed by its backend
+}
+
+// identifier returns the contact identity used for the session
+func (s *PublicChatSession) identifier() string {
+	return webchat.PublicAPIIdentityPrefix + s.ContactID
+}
+
+// PublicChatContactInput describes the contact starting a conversation
+type PublicChatContactInput struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+}
+
+// PublicChatMessageInput is a message posted by a contact
+type PublicChatMessageInput struct {
+	ClientMessageID string                      `json:"client_message_id"` // Idempotency key, echoed back when polling
+	ContentType     string                      `json:"content_type"`
+	Content         string                      `json:"content"`
+	Attachments     []PublicChatAttachmentInput `json:"attachments"`
+}
+
+// PublicChatAttachmentInput is a file attached to a posted message
+type PublicChatAttachmentInput struct {
+	Type      string `json:"type"`
+	URL       string `json:"url" binding:"required"`
+	Filename  string `json:"filename"`
+	MimeType  string `json:"mime_type"`
+	SizeBytes int64  `json:"size_bytes"`
+}
+
+// PublicChatAccepted acknowledges a posted message, which is stored asynchronously
+type PublicChatAccepted struct {
+	ClientMessageID string `json:"client_message_id"`
+	ConversationID  string `json:"conversation_id"`
+}
+
+// PublicChatMessage is a conversation message as exposed to the contact
+type PublicChatMessage struct {
+	ID              string                      `json:"id"`
+	ClientMessageID string                      `json:"client_message_id,omitempty"`
+	FromContact     bool                        `json:"from_contact"`
+	ContentType     entity.ContentType          `json:"content_type"`
+	Content         string                      `json:"content,omitempty"`
+	Attachments     []*entity.MessageAttachment `json:"attachments,omitempty"`
+	CreatedAt       time.Time                   `json:"created_at"`
+}
+
+// PublicChatService lets tenant websites and apps run their own chat UI over plain
+// HTTP. Requests act on behalf of a contact identified by the tenant, proven by an
+// HMAC of the contact ID with the channel secret, so no user session is needed.
+type PublicChatService struct {
+	channelRepo      repository.ChannelRepository
+	contactRepo      repository.ContactRepository
+	conversationRepo repository.ConversationRepository
+	messageRepo      repository.MessageRepository
+	producer         nats.Publisher
+}
+
+// NewPublicChatService creates a new public chat service
+func NewPublicChatService(
+	channelRepo repository.ChannelRepository,
+	contactRepo repository.ContactRepository,
+	conversationRepo repository.ConversationRepository,
+	messageRepo repository.MessageRepository,
+	producer nats.Publisher,
+) *PublicChatService {
+	return &PublicChatService{
+		channelRepo:      channelRepo,
+		contactRepo:      contactRepo,
+		conversationRepo: conversationRepo,
+		messageRepo:      messageRepo,
+		producer:         producer,
+	}
+}
+
+// PublicChatSignature returns the signature a tenant backend sends along with a
+// contact ID: the hex encoded HMAC-SHA256 of the ID keyed with the channel secret
+func PublicChatSignature(secret, contactID string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(contactID))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// RotateSecret generates a new public chat secret for a webchat channel. Signatures
+// made with the previous secret stop working immediately.
+func (s *PublicChatService) RotateSecret(ctx context.Context, tenantID, channelID string) (string, error) {
+	channel, err := s.channelRepo.FindByID(ctx, channelID)
+	if err != nil {
+		return "", err
+	}
+	if channel.TenantID != tenantID {
+		return "", errors.New(errors.ErrCodeNotFound, "channel not found")
+	}
+	if channel.Type != entity.ChannelTypeWebChat {
+		return "", errors.Validation("the public chat API is only available on webchat channels")
+	}
+
+	secret := newConnectorSecret()
+	if channel.Credentials == nil {
+		channel.Credentials = make(map[string]string)
+	}
+	channel.Credentials[PublicChatSecretCredential] = secret
+	channel.UpdatedAt = time.Now()
+	if err := s.channelRepo.Update(ctx, channel); err != nil {
+		return "", err
+	}
+	return secret, nil
+}
+
+// Authenticate verifies the signed contact ID of a request against the channel secret
+func (s *PublicChatService) Authenticate(ctx context.Context, channelID, contactID, signature string) (*PublicChatSession, error) {
+	contactID = strings.TrimSpace(contactID)
+	if contactID == "" || signature == "" {
+		return nil, errors.Unauthorized("contact ID and signature are required")
+	}
+	if len(contactID) > maxPublicChatContactIDLength {
+		return nil, errors.Validation("contact ID is too long")
+	}
+
+	channel, err := s.channelRepo.FindByID(ctx, channelID)
+	if err != nil || channel.Type != entity.ChannelTypeWebChat {
+		return nil, errors.New(errors.ErrCodeChannelNotFound, "channel not found")
+	}
+
+	secret := channel.Credentials[PublicChatSecretCredential]
+	if secret == "" {
+		return nil, errors.Forbidden("the public chat API is not enabled on this channel")
+	}
+	if !hmac.Equal([]byte(strings.ToLower(signature)), []byte(PublicChatSignature(secret, contactID))) {
+		return nil, errors.Unauthorized("invalid signature")
+	}
+	if !channel.IsEnabled() {
+		return nil, errors.Forbidden("channel is disabled")
+	}
+
+	return &PublicChatSession{Channel: channel, ContactID: contactID}, nil
+}
+
+// StartConversation returns the open conversation of the contact on the channel,
+// creating the contact and the conversation when needed
+func (s *PublicChatService) StartConversation(ctx context.Context, session *PublicChatSession, input *PublicChatContactInput) (*entity.Conversation, error) {
+	contact, err := s.contact(ctx, session, input)
+	if err != nil {
+		return nil, err
+	}
+
+	conversation, err := s.conversationRepo.FindOpenByContactAndChannel(ctx, contact.ID, session.Channel.ID)
+	if err == nil && conversation != nil {
+		return conversation, nil
+	}
+
+	now := time.Now()
+	conversation = &entity.Conversation{
+		ID:        uuid.New().String(),
+		TenantID:  session.Channel.TenantID,
+		ChannelID: session.Channel.ID,
+		ContactID: contact.ID,
+		Status:    entity.ConversationStatusOpen,
+		Priority:  entity.ConversationPriorityNormal,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.conversationRepo.Create(ctx, conversation); err != nil {
+		return nil, err
+	}
+	return conversation, nil
+}
+
+// PostMessage queues a message from the contact for the inbound pipeline, the same
+// way the webchat widget does
+func (s *PublicChatService) PostMessage(ctx context.Context, session *PublicChatSession, conversationID string, input *PublicChatMessageInput) (*PublicChatAccepted, error) {
+	if strings.TrimSpace(input.Content) == "" && len(input.Attachments) == 0 {
+		return nil, errors.Validation("content or attachments are required")
+	}
+
+	conversation, contact, err := s.conversation(ctx, session, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.Status == entity.ConversationStatusResolved || conversation.Status == entity.ConversationStatusClosed {
+		return nil, errors.Validation("conversation is closed, start a new one")
+	}
+
+	contentType := input.ContentType
+	if contentType == "" {
+		contentType = string(entity.ContentTypeText)
+	}
+	clientMessageID := strings.TrimSpace(input.ClientMessageID)
+	if clientMessageID == "" {
+		clientMessageID = uuid.New().String()
+	}
+
+	attachments := make([]nats.AttachmentData, 0, len(input.Attachments))
+	for _, att := range input.Attachments {
+		attachments = append(attachments, nats.AttachmentData{
+			Type:      att.Type,
+			URL:       att.URL,
+			Filename:  att.Filename,
+			MimeType:  att.MimeType,
+			SizeBytes: att.SizeBytes,
+		})
+	}
+
+	inbound := &nats.InboundMessage{
+		ID:             uuid.New().String(),
+		TenantID:       session.Channel.TenantID,
+		ChannelID:      session.Channel.ID,
+		ChannelType:    string(entity.ChannelTypeWebChat),
+		ContactID:      contact.ID,
+		ConversationID: conversation.ID,
+		ExternalID:     clientMessageID,
+		ContentType:    contentType,
+		Content:        input.Content,
+		Metadata: map[string]string{
+			"sender_id":   session.identifier(),
+			"sender_name": contact.Name,
+			"source":      "public_api",
+		},
+		Attachments: attachments,
+		Timestamp:   time.Now(),
+	}
+	if err := s.producer.PublishInbound(ctx, inbound); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to queue message")
+	}
+
+	return &PublicChatAccepted{ClientMessageID: clientMessageID, ConversationID: conversation.ID}, nil
+}
+
+// ListMessages returns the messages of the conversation created after the given
+// time, oldest first, so clients can poll for replies with the last seen timestamp
+func (s *PublicChatService) ListMessages(ctx context.Context, session *PublicChatSession, conversationID string, after time.Time, limit int) ([]*PublicChatMessage, error) {
+	if _, _, err := s.conversation(ctx, session, conversationID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultPublicChatPollLimit
+	}
+	if limit > maxPublicChatPollLimit {
+		limit = maxPublicChatPollLimit
+	}
+
+	messages, err := s.messageRepo.FindByConversationAfter(ctx, conversationID, after, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*PublicChatMessage, 0, len(messages))
+	for _, message := range messages {
+		if message.IsDeleted {
+			continue
+		}
+		attachments, _ := s.messageRepo.FindAttachmentsByMessage(ctx, message.ID)
+		public := &PublicChatMessage{
+			ID:          message.ID,
+			FromContact: message.SenderType == entity.SenderTypeContact,
+			ContentType: message.ContentType,
+			Content:     message.Content,
+			Attachments: attachments,
+			CreatedAt:   message.CreatedAt,
+		}
+		if public.FromContact {
+			public.ClientMessageID = message.ExternalID
+		}
+		result = append(result, public)
+	}
+	return result, nil
+}
+
+// contact finds the contact of the session, creating it on first use
+func (s *PublicChatService) contact(ctx context.Context, session *PublicChatSession, input *PublicChatContactInput) (*entity.Contact, error) {
+	tenantID := session.Channel.TenantID
+	contact, err := s.contactRepo.FindByIdentity(ctx, tenantID, string(entity.ChannelTypeWebChat), session.identifier())
+	if err == nil && contact != nil {
+		return contact, nil
+	}
+	if input == nil {
+		return nil, errors.New(errors.ErrCodeContactNotFound, "start a conversation first")
+	}
+
+	now := time.Now()
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		name = "Visitor"
+	}
+	contact = &entity.Contact{
+		ID:           uuid.New().String(),
+		TenantID:     tenantID,
+		Name:         name,
+		Email:        strings.TrimSpace(input.Email),
+		Phone:        strings.TrimSpace(input.Phone),
+		CustomFields: make(map[string]string),
+		Tags:         []string{"webchat"},
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	identity := &entity.ContactIdentity{
+		ID:          uuid.New().String(),
+		ContactID:   contact.ID,
+		ChannelType: string(entity.ChannelTypeWebChat),
+		Identifier:  session.identifier(),
+		Metadata:    map[string]string{"source": "public_api"},
+		CreatedAt:   now,
+	}
+	contact.Identities = []*entity.ContactIdentity{identity}
+
+	if err := s.contactRepo.Create(ctx, contact); err != nil {
+		return nil, err
+	}
+	if err := s.contactRepo.AddIdentity(ctx, identity); err != nil {
+		return nil, err
+	}
+	return contact, nil
+}
+
+// conversation loads a conversation and checks it belongs to the session's contact
+func (s *PublicChatService) conversation(ctx context.Context, session *PublicChatSession, conversationID string) (*entity.Conversation, *entity.Contact, error) {
+	contact, err := s.contact(ctx, session, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	conversation, err := s.conversationRepo.FindByID(ctx, conversationID)
+	if err != nil || conversation.ContactID != contact.ID || conversation.ChannelID != session.Channel.ID {
+		return nil, nil, errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+	}
+	return conversation, contact, nil
+}
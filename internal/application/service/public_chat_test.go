@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type publicChatTestEnv struct {
+	svc           *PublicChatService
+	channels      *testutil.MockChannelRepository
+	contacts      *testutil.MockContactRepository
+	conversations *testutil.MockConversationRepository
+	messages      *testutil.MockMessageRepository
+	producer      *testutil.MockProducer
+}
+
+func newPublicChatTestEnv() *publicChatTestEnv {
+	env := &publicChatTestEnv{
+		channels:      testutil.NewMockChannelRepository(),
+		contacts:      testutil.NewMockContactRepository(),
+		conversations: testutil.NewMockConversationRepository(),
+		messages:      testutil.NewMockMessageRepository(),
+		producer:      testutil.NewMockProducer(),
+	}
+	env.channels.Channels["channel-1"] = &entity.Channel{
+		ID:          "channel-1",
+		TenantID:    "tenant-1",
+		Type:        entity.ChannelTypeWebChat,
+		Enabled:     true,
+		Credentials: map[string]string{PublicChatSecretCredential: "secret"},
+	}
+	env.svc = NewPublicChatService(env.channels, env.contacts, env.conversations, env.messages, env.producer)
+	return env
+}
+
+func (env *publicChatTestEnv) session(t *testing.T, contactID string) *PublicChatSession {
+	session, err := env.svc.Authenticate(context.Background(), "channel-1", contactID, PublicChatSignature("secret", contactID))
+	require.NoError(t, err)
+	return session
+}
+
+func TestPublicChatService_Authenticate(t *testing.T) {
+	env := newPublicChatTestEnv()
+	ctx := context.Background()
+
+	session, err := env.svc.Authenticate(ctx, "channel-1", "user-42", PublicChatSignature("secret", "user-42"))
+	require.NoError(t, err)
+	assert.Equal(t, "user-42", session.ContactID)
+
+	_, err = env.svc.Authenticate(ctx, "channel-1", "user-43", PublicChatSignature("secret", "user-42"))
+	assert.Equal(t, errors.ErrCodeUnauthorized, errors.GetAppError(err).Code)
+
+	_, err = env.svc.Authenticate(ctx, "channel-1", "user-42", "")
+	assert.Equal(t, errors.ErrCodeUnauthorized, errors.GetAppError(err).Code)
+
+	env.channels.Channels["channel-1"].Credentials = map[string]string{}
+	_, err = env.svc.Authenticate(ctx, "channel-1", "user-42", PublicChatSignature("", "user-42"))
+	assert.Equal(t, errors.ErrCodeForbidden, errors.GetAppError(err).Code, "API disabled without a secret")
+
+	env.channels.Channels["channel-2"] = &entity.Channel{ID: "channel-2", TenantID: "tenant-1", Type: entity.ChannelTypeTelegram, Enabled: true}
+	_, err = env.svc.Authenticate(ctx, "channel-2", "user-42", PublicChatSignature("secret", "user-42"))
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestPublicChatService_RotateSecret(t *testing.T) {
+	env := newPublicChatTestEnv()
+	ctx := context.Background()
+
+	_, err := env.svc.RotateSecret(ctx, "tenant-2", "channel-1")
+	assert.True(t, errors.IsNotFound(err))
+
+	secret, err := env.svc.RotateSecret(ctx, "tenant-1", "channel-1")
+	require.NoError(t, err)
+	assert.Len(t, secret, 64)
+
+	_, err = env.svc.Authenticate(ctx, "channel-1", "user-42", PublicChatSignature("secret", "user-42"))
+	assert.Error(t, err, "old secret no longer valid")
+	_, err = env.svc.Authenticate(ctx, "channel-1", "user-42", PublicChatSignature(secret, "user-42"))
+	assert.NoError(t, err)
+}
+
+func TestPublicChatService_StartConversation(t *testing.T) {
+	env := newPublicChatTestEnv()
+	ctx := context.Background()
+	session := env.session(t, "user-42")
+
+	conversation, err := env.svc.StartConversation(ctx, session, &PublicChatContactInput{Name: "Ana", Email: "ana@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "channel-1", conversation.ChannelID)
+	require.Len(t, env.contacts.Contacts, 1)
+
+	contact := env.contacts.Contacts[conversation.ContactID]
+	assert.Equal(t, "Ana", contact.Name)
+	assert.Equal(t, "api:user-42", env.contacts.Identities[contact.ID][0].Identifier)
+
+	again, err := env.svc.StartConversation(ctx, session, &PublicChatContactInput{})
+	require.NoError(t, err)
+	assert.Equal(t, conversation.ID, again.ID, "open conversation is reused")
+	assert.Len(t, env.contacts.Contacts, 1)
+}
+
+func TestPublicChatService_PostMessage(t *testing.T) {
+	env := newPublicChatTestEnv()
+	ctx := context.Background()
+	session := env.session(t, "user-42")
+
+	conversation, err := env.svc.StartConversation(ctx, session, &PublicChatContactInput{Name: "Ana"})
+	require.NoError(t, err)
+
+	accepted, err := env.svc.PostMessage(ctx, session, conversation.ID, &PublicChatMessageInput{ClientMessageID: "local-1", Content: "Hi"})
+	require.NoError(t, err)
+	assert.Equal(t, "local-1", accepted.ClientMessageID)
+
+	require.Len(t, env.producer.InboundMessages, 1)
+	inbound := env.producer.InboundMessages[0]
+	assert.Equal(t, "webchat", inbound.ChannelType)
+	assert.Equal(t, "local-1", inbound.ExternalID)
+	assert.Equal(t, "api:user-42", inbound.Metadata["sender_id"])
+	assert.Equal(t, "text", inbound.ContentType)
+
+	_, err = env.svc.PostMessage(ctx, session, conversation.ID, &PublicChatMessageInput{})
+	assert.True(t, errors.IsValidation(err))
+
+	// Another contact cannot post to the conversation
+	other := env.session(t, "user-43")
+	_, err = env.svc.StartConversation(ctx, other, &PublicChatContactInput{})
+	require.NoError(t, err)
+	_, err = env.svc.PostMessage(ctx, other, conversation.ID, &PublicChatMessageInput{Content: "Hi"})
+	assert.True(t, errors.IsNotFound(err))
+
+	conversation.Status = entity.ConversationStatusResolved
+	_, err = env.svc.PostMessage(ctx, session, conversation.ID, &PublicChatMessageInput{Content: "Hi"})
+	assert.True(t, errors.IsValidation(err))
+}
+
+func TestPublicChatService_ListMessages(t *testing.T) {
+	env := newPublicChatTestEnv()
+	ctx := context.Background()
+	session := env.session(t, "user-42")
+
+	conversation, err := env.svc.StartConversation(ctx, session, &PublicChatContactInput{})
+	require.NoError(t, err)
+
+	start := time.Now().Add(-time.Minute)
+	env.messages.Messages["m1"] = &entity.Message{ID: "m1", ConversationID: conversation.ID, SenderType: entity.SenderTypeContact, ExternalID: "local-1", ContentType: entity.ContentTypeText, Content: "Hi", CreatedAt: start}
+	env.messages.Messages["m2"] = &entity.Message{ID: "m2", ConversationID: conversation.ID, SenderType: entity.SenderTypeUser, SenderID: "agent-1", ExternalID: "wamid", ContentType: entity.ContentTypeText, Content: "Hello!", CreatedAt: start.Add(time.Second)}
+	env.messages.Messages["m3"] = &entity.Message{ID: "m3", ConversationID: "other", SenderType: entity.SenderTypeUser, Content: "Elsewhere", CreatedAt: start.Add(2 * time.Second)}
+
+	messages, err := env.svc.ListMessages(ctx, session, conversation.ID, time.Time{}, 0)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.True(t, messages[0].FromContact)
+	assert.Equal(t, "local-1", messages[0].ClientMessageID)
+	assert.False(t, messages[1].FromContact)
+	assert.Empty(t, messages[1].ClientMessageID)
+
+	messages, err = env.svc.ListMessages(ctx, session, conversation.ID, start, 0)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "m2", messages[0].ID)
+
+	stranger := env.session(t, "user-99")
+	_, err = env.svc.ListMessages(ctx, stranger, conversation.ID, time.Time{}, 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,530 @@
+package service
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Events published by the QA module
+const (
+	EventQAReviewCompleted = "qa.review_completed"
+)
+
+// DefaultQASamplingLookback is how far back the first run of a sampling rule looks
+const DefaultQASamplingLookback = 7 * 24 * time.Hour
+
+// QAReviewNotifier notifies an agent that one of their conversations was reviewed
+type QAReviewNotifier interface {
+	NotifyReviewCompleted(review *entity.QAReview)
+}
+
+// ScorecardInput represents input for creating or updating a scorecard
+type ScorecardInput struct {
+	Name        string               `json:"name" binding:"required"`
+	Description string               `json:"description"`
+	Criteria    []entity.QACriterion `json:"criteria" binding:"required"`
+	IsActive    *bool                `json:"is_active,omitempty"`
+}
+
+// SamplingRuleInput represents input for creating or updating a sampling rule
+type SamplingRuleInput struct {
+	Name          string   `json:"name" binding:"required"`
+	ScorecardID   string   `json:"scorecard_id" binding:"required"`
+	ChannelIDs    []string `json:"channel_ids,omitempty"`
+	MinMessages   int      `json:"min_messages"`
+	SamplePercent int      `json:"sample_percent" binding:"required"`
+	MaxPerAgent   int      `json:"max_per_agent"`
+	IsActive      *bool    `json:"is_active,omitempty"`
+}
+
+// SubmitReviewInput represents a reviewer's filled scorecard
+type SubmitReviewInput struct {
+	Scores  []entity.QACriterionScore `json:"scores" binding:"required"`
+	Comment string                    `json:"comment"`
+}
+
+// QAReviewDetail is a review with the scorecard it is scored against
+type QAReviewDetail struct {
+	Review    *entity.QAReview    `json:"review"`
+	Scorecard *entity.QAScorecard `json:"scorecard"`
+}
+
+// QAService samples resolved conversations per agent, collects reviewer scorecards
+// and aggregates the scores into agent quality analytics
+type QAService struct {
+	repo     repository.QARepository
+	producer nats.Publisher
+	notifier QAReviewNotifier
+}
+
+// NewQAService creates a new QA service
+func NewQAService(repo repository.QARepository, producer nats.Publisher) *QAService {
+	return &QAService{
+		repo:     repo,
+		producer: producer,
+	}
+}
+
+// SetNotifier sets the notifier used to tell agents about completed reviews
+func (s *QAService) SetNotifier(notifier QAReviewNotifier) {
+	s.notifier = notifier
+}
+
+// CreateScorecard creates a new scorecard
+func (s *QAService) CreateScorecard(ctx context.Context, tenantID string, input *ScorecardInput) (*entity.QAScorecard, error) {
+	if err := validateScorecardInput(input); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	scorecard := &entity.QAScorecard{
+		ID:          uuid.New().String(),
+		TenantID:    tenantID,
+		Name:        input.Name,
+		Description: input.Description,
+		Criteria:    normalizeCriteria(input.Criteria),
+		IsActive:    input.IsActive == nil || *input.IsActive,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := s.repo.CreateScorecard(ctx, scorecard); err != nil {
+		return nil, err
+	}
+	return scorecard, nil
+}
+
+// GetScorecard returns a scorecard of a tenant
+func (s *QAService) GetScorecard(ctx context.Context, tenantID, id string) (*entity.QAScorecard, error) {
+	scorecard, err := s.repo.FindScorecardByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if scorecard.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeNotFound, "scorecard not found")
+	}
+	return scorecard, nil
+}
+
+// ListScorecards lists the scorecards of a tenant
+func (s *QAService) ListScorecards(ctx context.Context, tenantID string) ([]*entity.QAScorecard, error) {
+	return s.repo.ListScorecards(ctx, tenantID)
+}
+
+// UpdateScorecard updates a scorecard. Completed reviews keep their total score.
+func (s *QAService) UpdateScorecard(ctx context.Context, tenantID, id string, input *ScorecardInput) (*entity.QAScorecard, error) {
+	if err := validateScorecardInput(input); err != nil {
+		return nil, err
+	}
+
+	scorecard, err := s.GetScorecard(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	scorecard.Name = input.Name
+	scorecard.Description = input.Description
+	scorecard.Criteria = normalizeCriteria(input.Criteria)
+	if input.IsActive != nil {
+		scorecard.IsActive = *input.IsActive
+	}
+	scorecard.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdateScorecard(ctx, scorecard); err != nil {
+		return nil, err
+	}
+	return scorecard, nil
+}
+
+// DeleteScorecard deletes a scorecard with its rules and reviews
+func (s *QAService) DeleteScorecard(ctx context.Context, tenantID, id string) error {
+	if _, err := s.GetScorecard(ctx, tenantID, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteScorecard(ctx, id)
+}
+
+// CreateRule creates a new sampling rule
+func (s *QAService) CreateRule(ctx context.Context, tenantID string, input *SamplingRuleInput) (*entity.QASamplingRule, error) {
+	if err := s.validateRuleInput(ctx, tenantID, input); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	rule := &entity.QASamplingRule{
+		ID:            uuid.New().String(),
+		TenantID:      tenantID,
+		Name:          input.Name,
+		ScorecardID:   input.ScorecardID,
+		ChannelIDs:    input.ChannelIDs,
+		MinMessages:   input.MinMessages,
+		SamplePercent: input.SamplePercent,
+		MaxPerAgent:   input.MaxPerAgent,
+		IsActive:      input.IsActive == nil || *input.IsActive,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+
+	if err := s.repo.CreateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// GetRule returns a sampling rule of a tenant
+func (s *QAService) GetRule(ctx context.Context, tenantID, id string) (*entity.QASamplingRule, error) {
+	rule, err := s.repo.FindRuleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rule.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeNotFound, "sampling rule not found")
+	}
+	return rule, nil
+}
+
+// ListRules lists the sampling rules of a tenant
+func (s *QAService) ListRules(ctx context.Context, tenantID string) ([]*entity.QASamplingRule, error) {
+	return s.repo.ListRules(ctx, tenantID)
+}
+
+// UpdateRule updates a sampling rule
+func (s *QAService) UpdateRule(ctx context.Context, tenantID, id string, input *SamplingRuleInput) (*entity.QASamplingRule, error) {
+	if err := s.validateRuleInput(ctx, tenantID, input); err != nil {
+		return nil, err
+	}
+
+	rule, err := s.GetRule(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.Name = input.Name
+	rule.ScorecardID = input.ScorecardID
+	rule.ChannelIDs = input.ChannelIDs
+	rule.MinMessages = input.MinMessages
+	rule.SamplePercent = input.SamplePercent
+	rule.MaxPerAgent = input.MaxPerAgent
+	if input.IsActive != nil {
+		rule.IsActive = *input.IsActive
+	}
+	rule.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteRule deletes a sampling rule. Reviews it created are kept.
+func (s *QAService) DeleteRule(ctx context.Context, tenantID, id string) error {
+	if _, err := s.GetRule(ctx, tenantID, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteRule(ctx, id)
+}
+
+// RunRule samples conversations resolved since the rule's last run and creates a
+// pending review for each sampled conversation
+func (s *QAService) RunRule(ctx context.Context, tenantID, id string) ([]*entity.QAReview, error) {
+	rule, err := s.GetRule(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.sample(ctx, rule)
+}
+
+// RunAllRules runs every active sampling rule and returns the number of reviews created
+func (s *QAService) RunAllRules(ctx context.Context) (int, error) {
+	rules, err := s.repo.ListActiveRules(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	created := 0
+	for _, rule := range rules {
+		reviews, err := s.sample(ctx, rule)
+		if err != nil {
+			logger.Warn("QA sampling rule failed",
+				zap.String("rule_id", rule.ID),
+				zap.String("tenant_id", rule.TenantID),
+				zap.Error(err),
+			)
+			continue
+		}
+		created += len(reviews)
+	}
+	return created, nil
+}
+
+func (s *QAService) sample(ctx context.Context, rule *entity.QASamplingRule) ([]*entity.QAReview, error) {
+	now := time.Now()
+	since := now.Add(-DefaultQASamplingLookback)
+	if rule.LastRunAt != nil {
+		since = *rule.LastRunAt
+	}
+
+	candidates, err := s.repo.FindSampleCandidates(ctx, rule, since)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group per agent so every agent is sampled at the same rate
+	byAgent := make(map[string][]entity.QASampleCandidate)
+	var agents []string
+	for _, candidate := range candidates {
+		if _, ok := byAgent[candidate.AgentID]; !ok {
+			agents = append(agents, candidate.AgentID)
+		}
+		byAgent[candidate.AgentID] = append(byAgent[candidate.AgentID], candidate)
+	}
+	sort.Strings(agents)
+
+	var reviews []*entity.QAReview
+	for _, agentID := range agents {
+		agentCandidates := byAgent[agentID]
+		rand.Shuffle(len(agentCandidates), func(i, j int) {
+			agentCandidates[i], agentCandidates[j] = agentCandidates[j], agentCandidates[i]
+		})
+
+		for _, candidate := range agentCandidates[:sampleSize(len(agentCandidates), rule)] {
+			review := &entity.QAReview{
+				ID:             uuid.New().String(),
+				TenantID:       rule.TenantID,
+				ConversationID: candidate.ConversationID,
+				AgentID:        candidate.AgentID,
+				ScorecardID:    rule.ScorecardID,
+				RuleID:         rule.ID,
+				Status:         entity.QAReviewStatusPending,
+				CreatedAt:      now,
+				UpdatedAt:      now,
+			}
+			if err := s.repo.CreateReview(ctx, review); err != nil {
+				return reviews, err
+			}
+			reviews = append(reviews, review)
+		}
+	}
+
+	rule.LastRunAt = &now
+	rule.UpdatedAt = now
+	if err := s.repo.UpdateRule(ctx, rule); err != nil {
+		return reviews, err
+	}
+
+	return reviews, nil
+}
+
+// sampleSize returns how many of an agent's candidates a rule reviews: the sample
+// percentage rounded up, so low-volume agents still get reviewed, capped per agent
+func sampleSize(candidates int, rule *entity.QASamplingRule) int {
+	if candidates == 0 || rule.SamplePercent <= 0 {
+		return 0
+	}
+
+	size := int(math.Ceil(float64(candidates) * float64(rule.SamplePercent) / 100))
+	if size > candidates {
+		size = candidates
+	}
+	if rule.MaxPerAgent > 0 && size > rule.MaxPerAgent {
+		size = rule.MaxPerAgent
+	}
+	return size
+}
+
+// ListReviews lists the reviews of a tenant
+func (s *QAService) ListReviews(ctx context.Context, tenantID string, filter *entity.QAReviewFilter, params *repository.ListParams) ([]*entity.QAReview, int64, error) {
+	return s.repo.ListReviews(ctx, tenantID, filter, params)
+}
+
+// GetReview returns a review with its scorecard
+func (s *QAService) GetReview(ctx context.Context, tenantID, id string) (*QAReviewDetail, error) {
+	review, err := s.repo.FindReviewByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if review.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeNotFound, "review not found")
+	}
+
+	scorecard, err := s.repo.FindScorecardByID(ctx, review.ScorecardID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &QAReviewDetail{Review: review, Scorecard: scorecard}, nil
+}
+
+// SubmitReview scores a pending review against its scorecard, completes it and
+// notifies the reviewed agent
+func (s *QAService) SubmitReview(ctx context.Context, tenantID, id, reviewerID string, input *SubmitReviewInput) (*entity.QAReview, error) {
+	detail, err := s.GetReview(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	review := detail.Review
+
+	if review.IsCompleted() {
+		return nil, errors.New(errors.ErrCodeConflict, "review already completed")
+	}
+	if reviewerID == review.AgentID {
+		return nil, errors.New(errors.ErrCodeForbidden, "agents cannot review their own conversations")
+	}
+
+	total, err := scoreReview(detail.Scorecard, input.Scores)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	review.ReviewerID = reviewerID
+	review.Scores = input.Scores
+	review.Comment = input.Comment
+	review.TotalScore = total
+	review.Status = entity.QAReviewStatusCompleted
+	review.CompletedAt = &now
+	review.UpdatedAt = now
+
+	if err := s.repo.UpdateReview(ctx, review); err != nil {
+		return nil, err
+	}
+
+	if s.notifier != nil {
+		s.notifier.NotifyReviewCompleted(review)
+	}
+	if s.producer != nil {
+		s.producer.PublishEvent(ctx, &nats.Event{
+			Type:     EventQAReviewCompleted,
+			TenantID: tenantID,
+			Payload: map[string]interface{}{
+				"review_id":       review.ID,
+				"conversation_id": review.ConversationID,
+				"agent_id":        review.AgentID,
+				"reviewer_id":     review.ReviewerID,
+				"scorecard_id":    review.ScorecardID,
+				"total_score":     review.TotalScore,
+			},
+			Timestamp: now,
+		})
+	}
+
+	return review, nil
+}
+
+// GetAgentQuality aggregates completed reviews per agent in a period
+func (s *QAService) GetAgentQuality(ctx context.Context, tenantID string, start, end time.Time) ([]entity.AgentQualitySummary, error) {
+	return s.repo.SummarizeByAgent(ctx, entity.AnalyticsFilter{
+		TenantID:  tenantID,
+		StartDate: start,
+		EndDate:   end,
+	})
+}
+
+// scoreReview validates that every criterion of the scorecard was scored once within
+// range and returns the weighted score from 0 to 100
+func scoreReview(scorecard *entity.QAScorecard, scores []entity.QACriterionScore) (float64, error) {
+	seen := make(map[string]bool, len(scores))
+	var weighted, totalWeight float64
+
+	for _, score := range scores {
+		criterion := scorecard.GetCriterion(score.CriterionID)
+		if criterion == nil {
+			return 0, errors.Validation("unknown criterion: " + score.CriterionID)
+		}
+		if seen[score.CriterionID] {
+			return 0, errors.Validation("criterion scored more than once: " + score.CriterionID)
+		}
+		if score.Score < 0 || score.Score > criterion.MaxScore {
+			return 0, errors.Validation("score out of range for criterion: " + score.CriterionID)
+		}
+		seen[score.CriterionID] = true
+
+		weighted += criterion.Weight * float64(score.Score) / float64(criterion.MaxScore)
+		totalWeight += criterion.Weight
+	}
+
+	for _, criterion := range scorecard.Criteria {
+		if !seen[criterion.ID] {
+			return 0, errors.Validation("missing score for criterion: " + criterion.ID)
+		}
+	}
+
+	if totalWeight == 0 {
+		return 0, nil
+	}
+	return math.Round(weighted/totalWeight*10000) / 100, nil
+}
+
+func validateScorecardInput(input *ScorecardInput) error {
+	if input.Name == "" {
+		return errors.Validation("name is required")
+	}
+	if len(input.Criteria) == 0 {
+		return errors.Validation("at least one criterion is required")
+	}
+
+	ids := make(map[string]bool, len(input.Criteria))
+	for _, criterion := range input.Criteria {
+		if criterion.Name == "" {
+			return errors.Validation("criterion name is required")
+		}
+		if criterion.MaxScore <= 0 {
+			return errors.Validation("criterion max_score must be positive")
+		}
+		if criterion.Weight < 0 {
+			return errors.Validation("criterion weight must not be negative")
+		}
+		if criterion.ID != "" {
+			if ids[criterion.ID] {
+				return errors.Validation("duplicate criterion id: " + criterion.ID)
+			}
+			ids[criterion.ID] = true
+		}
+	}
+	return nil
+}
+
+// normalizeCriteria assigns IDs to new criteria and defaults their weight to 1
+func normalizeCriteria(criteria []entity.QACriterion) []entity.QACriterion {
+	normalized := make([]entity.QACriterion, len(criteria))
+	for i, criterion := range criteria {
+		if criterion.ID == "" {
+			criterion.ID = uuid.New().String()
+		}
+		if criterion.Weight == 0 {
+			criterion.Weight = 1
+		}
+		normalized[i] = criterion
+	}
+	return normalized
+}
+
+func (s *QAService) validateRuleInput(ctx context.Context, tenantID string, input *SamplingRuleInput) error {
+	if input.Name == "" {
+		return errors.Validation("name is required")
+	}
+	if input.SamplePercent < 1 || input.SamplePercent > 100 {
+		return errors.Validation("sample_percent must be between 1 and 100")
+	}
+	if input.MinMessages < 0 {
+		return errors.Validation("min_messages must not be negative")
+	}
+	if input.MaxPerAgent < 0 {
+		return errors.Validation("max_per_agent must not be negative")
+	}
+	if _, err := s.GetScorecard(ctx, tenantID, input.ScorecardID); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockQARepo struct {
+	scorecards map[string]*entity.QAScorecard
+	rules      map[string]*entity.QASamplingRule
+	reviews    map[string]*entity.QAReview
+	candidates []entity.QASampleCandidate
+	since      time.Time
+}
+
+func newMockQARepo() *mockQARepo {
+	return &mockQARepo{
+		scorecards: make(map[string]*entity.QAScorecard),
+		rules:      make(map[string]*entity.QASamplingRule),
+		reviews:    make(map[string]*entity.QAReview),
+	}
+}
+
+func (m *mockQARepo) CreateScorecard(ctx context.Context, scorecard *entity.QAScorecard) error {
+	m.scorecards[scorecard.ID] = scorecard
+	return nil
+}
+
+func (m *mockQARepo) FindScorecardByID(ctx context.Context, id string) (*entity.QAScorecard, error) {
+	if scorecard, ok := m.scorecards[id]; ok {
+		return scorecard, nil
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "scorecard not found")
+}
+
+func (m *mockQARepo) ListScorecards(ctx context.Context, tenantID string) ([]*entity.QAScorecard, error) {
+	var result []*entity.QAScorecard
+	for _, scorecard := range m.scorecards {
+		if scorecard.TenantID == tenantID {
+			result = append(result, scorecard)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockQARepo) UpdateScorecard(ctx context.Context, scorecard *entity.QAScorecard) error {
+	m.scorecards[scorecard.ID] = scorecard
+	return nil
+}
+
+func (m *mockQARepo) DeleteScorecard(ctx context.Context, id string) error {
+	delete(m.scorecards, id)
+	return nil
+}
+
+func (m *mockQARepo) CreateRule(ctx context.Context, rule *entity.QASamplingRule) error {
+	m.rules[rule.ID] = rule
+	return nil
+}
+
+func (m *mockQARepo) FindRuleByID(ctx context.Context, id string) (*entity.QASamplingRule, error) {
+	if rule, ok := m.rules[id]; ok {
+		return rule, nil
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "sampling rule not found")
+}
+
+func (m *mockQARepo) ListRules(ctx context.Context, tenantID string) ([]*entity.QASamplingRule, error) {
+	var result []*entity.QASamplingRule
+	for _, rule := range m.rules {
+		if rule.TenantID == tenantID {
+			result = append(result, rule)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockQARepo) ListActiveRules(ctx context.Context) ([]*entity.QASamplingRule, error) {
+	var result []*entity.QASamplingRule
+	for _, rule := range m.rules {
+		if rule.IsActive {
+			result = append(result, rule)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockQARepo) UpdateRule(ctx context.Context, rule *entity.QASamplingRule) error {
+	m.rules[rule.ID] = rule
+	return nil
+}
+
+func (m *mockQARepo) DeleteRule(ctx context.Context, id string) error {
+	delete(m.rules, id)
+	return nil
+}
+
+func (m *mockQARepo) FindSampleCandidates(ctx context.Context, rule *entity.QASamplingRule, since time.Time) ([]entity.QASampleCandidate, error) {
+	m.since = since
+	var result []entity.QASampleCandidate
+	for _, candidate := range m.candidates {
+		reviewed := false
+		for _, review := range m.reviews {
+			if review.ConversationID == candidate.ConversationID {
+				reviewed = true
+			}
+		}
+		if !reviewed {
+			result = append(result, candidate)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockQARepo) CreateReview(ctx context.Context, review *entity.QAReview) error {
+	m.reviews[review.ID] = review
+	return nil
+}
+
+func (m *mockQARepo) FindReviewByID(ctx context.Context, id string) (*entity.QAReview, error) {
+	if review, ok := m.reviews[id]; ok {
+		return review, nil
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "review not found")
+}
+
+func (m *mockQARepo) ListReviews(ctx context.Context, tenantID string, filter *entity.QAReviewFilter, params *repository.ListParams) ([]*entity.QAReview, int64, error) {
+	var result []*entity.QAReview
+	for _, review := range m.reviews {
+		if review.TenantID == tenantID {
+			result = append(result, review)
+		}
+	}
+	return result, int64(len(result)), nil
+}
+
+func (m *mockQARepo) UpdateReview(ctx context.Context, review *entity.QAReview) error {
+	m.reviews[review.ID] = review
+	return nil
+}
+
+func (m *mockQARepo) SummarizeByAgent(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.AgentQualitySummary, error) {
+	return nil, nil
+}
+
+type recordingQANotifier struct {
+	reviews []*entity.QAReview
+}
+
+func (n *recordingQANotifier) NotifyReviewCompleted(review *entity.QAReview) {
+	n.reviews = append(n.reviews, review)
+}
+
+func newTestQAService(t *testing.T) (*QAService, *mockQARepo, *entity.QAScorecard) {
+	repo := newMockQARepo()
+	svc := NewQAService(repo, testutil.NewMockProducer())
+
+	scorecard, err := svc.CreateScorecard(context.Background(), "tenant-1", &ScorecardInput{
+		Name: "Support quality",
+		Criteria: []entity.QACriterion{
+			{ID: "greeting", Name: "Greeting", Weight: 1, MaxScore: 5},
+			{ID: "resolution", Name: "Resolution", Weight: 3, MaxScore: 10},
+		},
+	})
+	require.NoError(t, err)
+
+	return svc, repo, scorecard
+}
+
+func TestQAScorecardValidation(t *testing.T) {
+	svc, _, _ := newTestQAService(t)
+	ctx := context.Background()
+
+	_, err := svc.CreateScorecard(ctx, "tenant-1", &ScorecardInput{Name: "Empty"})
+	assert.Error(t, err)
+
+	_, err = svc.CreateScorecard(ctx, "tenant-1", &ScorecardInput{
+		Name:     "Bad range",
+		Criteria: []entity.QACriterion{{Name: "Tone", MaxScore: 0}},
+	})
+	assert.Error(t, err)
+
+	scorecard, err := svc.CreateScorecard(ctx, "tenant-1", &ScorecardInput{
+		Name:     "Defaults",
+		Criteria: []entity.QACriterion{{Name: "Tone", MaxScore: 5}},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, scorecard.Criteria[0].ID)
+	assert.Equal(t, float64(1), scorecard.Criteria[0].Weight)
+	assert.True(t, scorecard.IsActive)
+}
+
+func TestQARunRuleSamplesPerAgent(t *testing.T) {
+	svc, repo, scorecard := newTestQAService(t)
+	ctx := context.Background()
+
+	for i, agent := range []string{"agent-1", "agent-1", "agent-1", "agent-1", "agent-2"} {
+		repo.candidates = append(repo.candidates, entity.QASampleCandidate{
+			ConversationID: "conv-" + string(rune('a'+i)),
+			AgentID:        agent,
+		})
+	}
+
+	rule, err := svc.CreateRule(ctx, "tenant-1", &SamplingRuleInput{
+		Name:          "Weekly",
+		ScorecardID:   scorecard.ID,
+		SamplePercent: 50,
+	})
+	require.NoError(t, err)
+
+	reviews, err := svc.RunRule(ctx, "tenant-1", rule.ID)
+	require.NoError(t, err)
+
+	perAgent := map[string]int{}
+	for _, review := range reviews {
+		perAgent[review.AgentID]++
+		assert.Equal(t, entity.QAReviewStatusPending, review.Status)
+		assert.Equal(t, scorecard.ID, review.ScorecardID)
+		assert.Equal(t, rule.ID, review.RuleID)
+	}
+	assert.Equal(t, map[string]int{"agent-1": 2, "agent-2": 1}, perAgent)
+	require.NotNil(t, repo.rules[rule.ID].LastRunAt)
+	lastRun := *repo.rules[rule.ID].LastRunAt
+
+	// The next run starts from the last run and skips reviewed conversations
+	reviews, err = svc.RunRule(ctx, "tenant-1", rule.ID)
+	require.NoError(t, err)
+	assert.Equal(t, lastRun, repo.since)
+	require.Len(t, reviews, 1)
+	assert.Equal(t, "agent-1", reviews[0].AgentID)
+}
+
+func TestQASampleSize(t *testing.T) {
+	tests := []struct {
+		candidates int
+		percent    int
+		maxPer     int
+		expected   int
+	}{
+		{0, 10, 0, 0},
+		{3, 10, 0, 1},
+		{20, 10, 0, 2},
+		{20, 50, 3, 3},
+		{5, 100, 0, 5},
+	}
+
+	for _, tt := range tests {
+		rule := &entity.QASamplingRule{SamplePercent: tt.percent, MaxPerAgent: tt.maxPer}
+		assert.Equal(t, tt.expected, sampleSize(tt.candidates, rule))
+	}
+}
+
+func TestQASubmitReview(t *testing.T) {
+	svc, repo, scorecard := newTestQAService(t)
+	notifier := &recordingQANotifier{}
+	svc.SetNotifier(notifier)
+	ctx := context.Background()
+
+	repo.reviews["review-1"] = &entity.QAReview{
+		ID:             "review-1",
+		TenantID:       "tenant-1",
+		ConversationID: "conv-1",
+		AgentID:        "agent-1",
+		ScorecardID:    scorecard.ID,
+		Status:         entity.QAReviewStatusPending,
+	}
+
+	_, err := svc.SubmitReview(ctx, "tenant-1", "review-1", "supervisor-1", &SubmitReviewInput{
+		Scores: []entity.QACriterionScore{{CriterionID: "greeting", Score: 5}},
+	})
+	assert.Error(t, err, "every criterion must be scored")
+
+	_, err = svc.SubmitReview(ctx, "tenant-1", "review-1", "supervisor-1", &SubmitReviewInput{
+		Scores: []entity.QACriterionScore{{CriterionID: "greeting", Score: 6}, {CriterionID: "resolution", Score: 5}},
+	})
+	assert.Error(t, err, "scores above max_score are rejected")
+
+	_, err = svc.SubmitReview(ctx, "tenant-1", "review-1", "agent-1", &SubmitReviewInput{
+		Scores: []entity.QACriterionScore{{CriterionID: "greeting", Score: 5}, {CriterionID: "resolution", Score: 5}},
+	})
+	assert.Error(t, err, "agents cannot review themselves")
+
+	review, err := svc.SubmitReview(ctx, "tenant-1", "review-1", "supervisor-1", &SubmitReviewInput{
+		Scores:  []entity.QACriterionScore{{CriterionID: "greeting", Score: 5}, {CriterionID: "resolution", Score: 5}},
+		Comment: "Good tone, missed the refund policy",
+	})
+	require.NoError(t, err)
+
+	// (1 * 5/5 + 3 * 5/10) / 4 = 62.5%
+	assert.Equal(t, 62.5, review.TotalScore)
+	assert.True(t, review.IsCompleted())
+	assert.Equal(t, "supervisor-1", review.ReviewerID)
+	require.Len(t, notifier.reviews, 1)
+	assert.Equal(t, "agent-1", notifier.reviews[0].AgentID)
+
+	producer := svc.producer.(*testutil.MockProducer)
+	require.Len(t, producer.Events, 1)
+	assert.Equal(t, EventQAReviewCompleted, producer.Events[0].Type)
+
+	_, err = svc.SubmitReview(ctx, "tenant-1", "review-1", "supervisor-1", &SubmitReviewInput{
+		Scores: review.Scores,
+	})
+	assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code)
+}
+
+func TestQAReviewIsTenantScoped(t *testing.T) {
+	svc, repo, scorecard := newTestQAService(t)
+
+	repo.reviews["review-1"] = &entity.QAReview{
+		ID:          "review-1",
+		TenantID:    "tenant-1",
+		ScorecardID: scorecard.ID,
+		Status:      entity.QAReviewStatusPending,
+	}
+
+	_, err := svc.GetReview(context.Background(), "tenant-2", "review-1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// EventQuotaThresholdReached is published when a tenant's usage crosses an alert threshold
+const EventQuotaThresholdReached = "quota.threshold_reached"
+
+// maxReportedRoutes caps the routes listed on the quota dashboard
+const maxReportedRoutes = 50
+
+// UsageRecorder counts metered usage of a tenant
+type UsageRecorder interface {
+	RecordUsage(tenantID string, metric entity.QuotaMetric, route string, amount int64)
+}
+
+// usageKey identifies a buffered usage counter
+type usageKey struct {
+	tenantID string
+	metric   entity.QuotaMetric
+	route    string
+	day      time.Time
+}
+
+// QuotaService meters tenant usage against plan limits. Usage is counted in memory and
+// flushed to daily counters periodically, so metering adds no query to the request path.
+// After each flush the tenants that used something are checked against their alert
+// thresholds, and crossing one publishes a quota event once per period.
+type QuotaService struct {
+	quotaRepo  repository.QuotaRepository
+	tenantRepo repository.TenantRepository
+	producer   nats.Publisher
+
+	mu      sync.Mutex
+	pending map[usageKey]int64
+	now     func() time.Time
+}
+
+// NewQuotaService creates a new quota service
+func NewQuotaService(quotaRepo repository.QuotaRepository, tenantRepo repository.TenantRepository, producer nats.Publisher) *QuotaService {
+	return &QuotaService{
+		quotaRepo:  quotaRepo,
+		tenantRepo: tenantRepo,
+		producer:   producer,
+		pending:    make(map[usageKey]int64),
+		now:        time.Now,
+	}
+}
+
+// RecordUsage counts usage of a metric. Route is the API route of API calls and empty
+// for other metrics.
+func (s *QuotaService) RecordUsage(tenantID string, metric entity.QuotaMetric, route string, amount int64) {
+	if tenantID == "" || amount <= 0 || !metric.IsCounter() {
+		return
+	}
+	now := s.now().UTC()
+	key := usageKey{
+		tenantID: tenantID,
+		metric:   metric,
+		route:    route,
+		day:      time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+	}
+
+	s.mu.Lock()
+	s.pending[key] += amount
+	s.mu.Unlock()
+}
+
+// Flush writes the counted usage and alerts tenants that crossed a threshold. Usage
+// that fails to be written is kept for the next flush.
+func (s *QuotaService) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = make(map[usageKey]int64)
+	s.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	usage := make([]entity.QuotaUsage, 0, len(pending))
+	tenants := make(map[string]bool)
+	for key, count := range pending {
+		usage = append(usage, entity.QuotaUsage{
+			TenantID: key.tenantID,
+			Metric:   key.metric,
+			Route:    key.route,
+			Day:      key.day,
+			Count:    count,
+		})
+		tenants[key.tenantID] = true
+	}
+
+	if err := s.quotaRepo.AddUsage(ctx, usage); err != nil {
+		s.mu.Lock()
+		for key, count := range pending {
+			s.pending[key] += count
+		}
+		s.mu.Unlock()
+		return err
+	}
+
+	for tenantID := range tenants {
+		if err := s.CheckThresholds(ctx, tenantID); err != nil {
+			logger.Warn("Failed to check quota thresholds",
+				zap.String("tenant_id", tenantID),
+				zap.Error(err),
+			)
+		}
+	}
+	return nil
+}
+
+// GetReport returns the tenant's usage of each metered metric against its plan limits
+// for the current period, with exhaustion forecasts and API calls per route
+func (s *QuotaService) GetReport(ctx context.Context, tenantID string) (*entity.QuotaReport, error) {
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return nil, errors.New(errors.ErrCodeTenantNotFound, "Tenant not found")
+	}
+
+	now := s.now().UTC()
+	periodStart, periodEnd := entity.QuotaPeriod(now)
+
+	quotas, err := s.quotaStatuses(ctx, tenant, now)
+	if err != nil {
+		return nil, err
+	}
+
+	routes, err := s.quotaRepo.ListRouteUsage(ctx, tenantID, periodStart, periodEnd, maxReportedRoutes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.QuotaReport{
+		TenantID:        tenantID,
+		Plan:            tenant.Plan,
+		PeriodStart:     periodStart,
+		PeriodEnd:       periodEnd,
+		Quotas:          quotas,
+		Routes:          routes,
+		AlertThresholds: tenant.QuotaAlertThresholds(),
+	}, nil
+}
+
+// CheckThresholds publishes a quota event for every alert threshold the tenant's usage
+// has crossed and that was not alerted yet this period
+func (s *QuotaService) CheckThresholds(ctx context.Context, tenantID string) error {
+	tenant, err := s.tenantRepo.FindByID(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	thresholds := tenant.QuotaAlertThresholds()
+	if len(thresholds) == 0 {
+		return nil
+	}
+
+	now := s.now().UTC()
+	periodStart, _ := entity.QuotaPeriod(now)
+
+	quotas, err := s.quotaStatuses(ctx, tenant, now)
+	if err != nil {
+		return err
+	}
+
+	for _, quota := range quotas {
+		if quota.Unlimited {
+			continue
+		}
+		// Only the highest crossed threshold is announced; lower ones are recorded
+		// so they do not alert later in the period
+		var crossed []int
+		for _, threshold := range thresholds {
+			if quota.PercentUsed >= float64(threshold) {
+				crossed = append(crossed, threshold)
+			}
+		}
+		for i := len(crossed) - 1; i >= 0; i-- {
+			created, err := s.quotaRepo.CreateAlert(ctx, &entity.QuotaAlert{
+				TenantID:    tenantID,
+				Metric:      quota.Metric,
+				PeriodStart: periodStart,
+				Threshold:   crossed[i],
+				CreatedAt:   now,
+			})
+			if err != nil {
+				return err
+			}
+			if created && i == len(crossed)-1 {
+				s.publishThreshold(ctx, tenantID, quota, crossed[i])
+			}
+		}
+	}
+	return nil
+}
+
+// quotaStatuses computes the usage of every metered metric in the period containing now
+func (s *QuotaService) quotaStatuses(ctx context.Context, tenant *entity.Tenant, now time.Time) ([]entity.QuotaStatus, error) {
+	periodStart, periodEnd := entity.QuotaPeriod(now)
+
+	totals, err := s.quotaRepo.SumUsage(ctx, tenant.ID, periodStart, periodEnd)
+	if err != nil {
+		return nil, err
+	}
+	storage, storageAdded, err := s.quotaRepo.StorageBytes(ctx, tenant.ID, periodStart)
+	if err != nil {
+		return nil, err
+	}
+
+	// Elapsed days include today so the first day of a period has a rate
+	elapsedDays := math.Max(now.Sub(periodStart).Hours()/24, 1)
+
+	quotas := make([]entity.QuotaStatus, 0, len(entity.QuotaMetrics))
+	for _, metric := range entity.QuotaMetrics {
+		used, growth := totals[metric], totals[metric]
+		if metric == entity.QuotaMetricStorage {
+			used, growth = storage, storageAdded
+		}
+		quotas = append(quotas, buildQuotaStatus(metric, used, tenant.QuotaLimit(metric), float64(growth)/elapsedDays, now, periodEnd))
+	}
+	return quotas, nil
+}
+
+// buildQuotaStatus compares usage with its limit and forecasts when the limit is
+// reached at the given daily rate
+func buildQuotaStatus(metric entity.QuotaMetric, used, limit int64, dailyRate float64, now, periodEnd time.Time) entity.QuotaStatus {
+	status := entity.QuotaStatus{
+		Metric:    metric,
+		Used:      used,
+		Limit:     limit,
+		DailyRate: math.Round(dailyRate*100) / 100,
+	}
+	if limit < 0 {
+		status.Unlimited = true
+		status.Remaining = -1
+		return status
+	}
+
+	status.Remaining = limit - used
+	if status.Remaining <= 0 {
+		status.Remaining = 0
+		status.Exhausted = true
+	}
+	if limit > 0 {
+		status.PercentUsed = math.Round(float64(used)/float64(limit)*10000) / 100
+	} else {
+		status.PercentUsed = 100
+	}
+
+	if !status.Exhausted && dailyRate > 0 {
+		days := float64(status.Remaining) / dailyRate
+		exhaustsAt := now.Add(time.Duration(days * 24 * float64(time.Hour))).Truncate(time.Hour)
+		status.ExhaustsAt = &exhaustsAt
+		// Counters reset with the period, so only storage can run out after it ends
+		status.ExhaustsBeforeReset = exhaustsAt.Before(periodEnd) || !metric.IsCounter()
+	}
+	return status
+}
+
+// publishThreshold publishes a threshold alert for a metric
+func (s *QuotaService) publishThreshold(ctx context.Context, tenantID string, quota entity.QuotaStatus, threshold int) {
+	if s.producer == nil {
+		return
+	}
+
+	payload := map[string]interface{}{
+		"metric":       string(quota.Metric),
+		"threshold":    threshold,
+		"used":         quota.Used,
+		"limit":        quota.Limit,
+		"percent_used": quota.PercentUsed,
+		"exhausted":    quota.Exhausted,
+	}
+	if quota.ExhaustsAt != nil {
+		payload["exhausts_at"] = quota.ExhaustsAt.Format(time.RFC3339)
+	}
+
+	if err := s.producer.PublishEvent(ctx, &nats.Event{
+		Type:      EventQuotaThresholdReached,
+		TenantID:  tenantID,
+		Payload:   payload,
+		Timestamp: s.now(),
+	}); err != nil {
+		logger.Warn("Failed to publish quota alert",
+			zap.String("tenant_id", tenantID),
+			zap.String("metric", string(quota.Metric)),
+			zap.Error(err),
+		)
+	}
+}
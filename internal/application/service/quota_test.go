@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeQuotaRepo struct {
+	usage        []entity.QuotaUsage
+	storage      int64
+	storageAdded int64
+	alerts       map[string]bool
+	failAdd      bool
+}
+
+func newFakeQuotaRepo() *fakeQuotaRepo {
+	return &fakeQuotaRepo{alerts: make(map[string]bool)}
+}
+
+func (r *fakeQuotaRepo) AddUsage(ctx context.Context, usage []entity.QuotaUsage) error {
+	if r.failAdd {
+		return fmt.Errorf("database unavailable")
+	}
+	r.usage = append(r.usage, usage...)
+	return nil
+}
+
+func (r *fakeQuotaRepo) SumUsage(ctx context.Context, tenantID string, since, until time.Time) (map[entity.QuotaMetric]int64, error) {
+	totals := make(map[entity.QuotaMetric]int64)
+	for _, u := range r.usage {
+		if u.TenantID == tenantID && !u.Day.Before(since) && u.Day.Before(until) {
+			totals[u.Metric] += u.Count
+		}
+	}
+	return totals, nil
+}
+
+func (r *fakeQuotaRepo) ListRouteUsage(ctx context.Context, tenantID string, since, until time.Time, limit int) ([]entity.RouteUsage, error) {
+	calls := make(map[string]int64)
+	for _, u := range r.usage {
+		if u.TenantID == tenantID && u.Metric == entity.QuotaMetricAPICalls {
+			calls[u.Route] += u.Count
+		}
+	}
+	routes := make([]entity.RouteUsage, 0, len(calls))
+	for route, n := range calls {
+		routes = append(routes, entity.RouteUsage{Route: route, Calls: n})
+	}
+	sort.Slice(routes, func(i, j int) bool { return routes[i].Calls > routes[j].Calls })
+	return routes, nil
+}
+
+func (r *fakeQuotaRepo) StorageBytes(ctx context.Context, tenantID string, since time.Time) (int64, int64, error) {
+	return r.storage, r.storageAdded, nil
+}
+
+func (r *fakeQuotaRepo) CreateAlert(ctx context.Context, alert *entity.QuotaAlert) (bool, error) {
+	key := fmt.Sprintf("%s/%s/%s/%d", alert.TenantID, alert.Metric, alert.PeriodStart.Format("2006-01-02"), alert.Threshold)
+	if r.alerts[key] {
+		return false, nil
+	}
+	r.alerts[key] = true
+	return true, nil
+}
+
+type quotaFixture struct {
+	service  *QuotaService
+	repo     *fakeQuotaRepo
+	tenant   *entity.Tenant
+	producer *testutil.MockProducer
+	now      time.Time
+}
+
+func newQuotaFixture() *quotaFixture {
+	tenants := testutil.NewMockTenantRepository()
+	tenant := &entity.Tenant{
+		ID:       "t1",
+		Plan:     entity.PlanStarter,
+		Settings: map[string]string{},
+		Limits: &entity.TenantLimits{
+			MaxAPICallsPerMonth:   1000,
+			MaxAIRequestsPerMonth: 100,
+			MaxVRERendersPerMonth: -1,
+		},
+	}
+	tenants.Tenants["t1"] = tenant
+
+	f := &quotaFixture{
+		repo:     newFakeQuotaRepo(),
+		tenant:   tenant,
+		producer: testutil.NewMockProducer(),
+		// Ten days into the period
+		now: time.Date(2026, 4, 11, 0, 0, 0, 0, time.UTC),
+	}
+	f.service = NewQuotaService(f.repo, tenants, f.producer)
+	f.service.now = func() time.Time { return f.now }
+	return f
+}
+
+func findQuota(t *testing.T, report *entity.QuotaReport, metric entity.QuotaMetric) entity.QuotaStatus {
+	t.Helper()
+	for _, quota := range report.Quotas {
+		if quota.Metric == metric {
+			return quota
+		}
+	}
+	t.Fatalf("no quota for %s", metric)
+	return entity.QuotaStatus{}
+}
+
+func TestQuotaService_RecordAndFlush(t *testing.T) {
+	f := newQuotaFixture()
+
+	f.service.RecordUsage("t1", entity.QuotaMetricAPICalls, "GET /api/v1/contacts", 1)
+	f.service.RecordUsage("t1", entity.QuotaMetricAPICalls, "GET /api/v1/contacts", 1)
+	f.service.RecordUsage("t1", entity.QuotaMetricAPICalls, "POST /api/v1/messages", 1)
+	f.service.RecordUsage("t1", entity.QuotaMetricAIRequests, "", 1)
+	// Ignored: no tenant, nothing used, storage is measured rather than counted
+	f.service.RecordUsage("", entity.QuotaMetricAPICalls, "GET /x", 1)
+	f.service.RecordUsage("t1", entity.QuotaMetricAPICalls, "GET /x", 0)
+	f.service.RecordUsage("t1", entity.QuotaMetricStorage, "", 100)
+
+	require.NoError(t, f.service.Flush(context.Background()))
+	assert.Len(t, f.repo.usage, 3)
+
+	report, err := f.service.GetReport(context.Background(), "t1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), findQuota(t, report, entity.QuotaMetricAPICalls).Used)
+	assert.Equal(t, int64(1), findQuota(t, report, entity.QuotaMetricAIRequests).Used)
+	require.Len(t, report.Routes, 2)
+	assert.Equal(t, entity.RouteUsage{Route: "GET /api/v1/contacts", Calls: 2}, report.Routes[0])
+
+	// Flushing twice does not count twice
+	require.NoError(t, f.service.Flush(context.Background()))
+	assert.Len(t, f.repo.usage, 3)
+}
+
+func TestQuotaService_FlushKeepsUsageOnFailure(t *testing.T) {
+	f := newQuotaFixture()
+	f.repo.failAdd = true
+
+	f.service.RecordUsage("t1", entity.QuotaMetricAPICalls, "GET /api/v1/contacts", 5)
+	require.Error(t, f.service.Flush(context.Background()))
+
+	f.repo.failAdd = false
+	require.NoError(t, f.service.Flush(context.Background()))
+	require.Len(t, f.repo.usage, 1)
+	assert.Equal(t, int64(5), f.repo.usage[0].Count)
+}
+
+func TestQuotaService_ReportForecast(t *testing.T) {
+	f := newQuotaFixture()
+	f.service.RecordUsage("t1", entity.QuotaMetricAPICalls, "GET /api/v1/contacts", 500)
+	f.service.RecordUsage("t1", entity.QuotaMetricAIRequests, "", 20)
+	f.service.RecordUsage("t1", entity.QuotaMetricVRERenders, "", 7)
+	f.repo.storage = 4 << 30
+	f.repo.storageAdded = 2 << 30
+	f.tenant.Settings[entity.TenantSettingQuotaAlertThresholds] = "100"
+	require.NoError(t, f.service.Flush(context.Background()))
+
+	report, err := f.service.GetReport(context.Background(), "t1")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), report.PeriodStart)
+	assert.Equal(t, time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC), report.PeriodEnd)
+
+	// 500 calls in 10 days: 50 a day, the remaining 500 last 10 more days
+	api := findQuota(t, report, entity.QuotaMetricAPICalls)
+	assert.Equal(t, int64(500), api.Remaining)
+	assert.Equal(t, 50.0, api.PercentUsed)
+	assert.Equal(t, 50.0, api.DailyRate)
+	require.NotNil(t, api.ExhaustsAt)
+	assert.Equal(t, time.Date(2026, 4, 21, 0, 0, 0, 0, time.UTC), *api.ExhaustsAt)
+	assert.True(t, api.ExhaustsBeforeReset)
+
+	// 20 requests in 10 days: the remaining 80 last 40 days, past the reset
+	ai := findQuota(t, report, entity.QuotaMetricAIRequests)
+	require.NotNil(t, ai.ExhaustsAt)
+	assert.False(t, ai.ExhaustsBeforeReset)
+
+	renders := findQuota(t, report, entity.QuotaMetricVRERenders)
+	assert.True(t, renders.Unlimited)
+	assert.Equal(t, int64(-1), renders.Remaining)
+	assert.Nil(t, renders.ExhaustsAt)
+
+	// Limits stored without storage fall back to the plan's 10 GiB
+	storage := findQuota(t, report, entity.QuotaMetricStorage)
+	assert.Equal(t, int64(10<<30), storage.Limit)
+	assert.Equal(t, 40.0, storage.PercentUsed)
+	require.NotNil(t, storage.ExhaustsAt)
+	assert.True(t, storage.ExhaustsBeforeReset)
+
+	assert.Equal(t, []int{100}, report.AlertThresholds)
+}
+
+func TestQuotaService_ThresholdAlerts(t *testing.T) {
+	f := newQuotaFixture()
+
+	// 85% of the API quota crosses only the 80% threshold
+	f.service.RecordUsage("t1", entity.QuotaMetricAPICalls, "GET /api/v1/contacts", 850)
+	require.NoError(t, f.service.Flush(context.Background()))
+	require.Len(t, f.producer.Events, 1)
+	event := f.producer.Events[0]
+	assert.Equal(t, EventQuotaThresholdReached, event.Type)
+	assert.Equal(t, "t1", event.TenantID)
+	assert.Equal(t, "api_calls", event.Payload["metric"])
+	assert.Equal(t, 80, event.Payload["threshold"])
+
+	// Still between 80% and 90%: no repeated alert
+	f.service.RecordUsage("t1", entity.QuotaMetricAPICalls, "GET /api/v1/contacts", 10)
+	require.NoError(t, f.service.Flush(context.Background()))
+	assert.Len(t, f.producer.Events, 1)
+
+	// Jumping past 100% announces only the highest threshold
+	f.service.RecordUsage("t1", entity.QuotaMetricAPICalls, "GET /api/v1/contacts", 200)
+	require.NoError(t, f.service.Flush(context.Background()))
+	require.Len(t, f.producer.Events, 2)
+	assert.Equal(t, 100, f.producer.Events[1].Payload["threshold"])
+	assert.Equal(t, true, f.producer.Events[1].Payload["exhausted"])
+
+	// A new period alerts again
+	f.now = f.now.AddDate(0, 1, 0)
+	f.service.RecordUsage("t1", entity.QuotaMetricAPICalls, "GET /api/v1/contacts", 900)
+	require.NoError(t, f.service.Flush(context.Background()))
+	require.Len(t, f.producer.Events, 3)
+	assert.Equal(t, 90, f.producer.Events[2].Payload["threshold"])
+}
+
+func TestQuotaService_CustomThresholds(t *testing.T) {
+	f := newQuotaFixture()
+	f.tenant.Settings[entity.TenantSettingQuotaAlertThresholds] = "50, 75,bad,50"
+
+	f.service.RecordUsage("t1", entity.QuotaMetricAIRequests, "", 60)
+	require.NoError(t, f.service.Flush(context.Background()))
+	require.Len(t, f.producer.Events, 1)
+	assert.Equal(t, "ai_requests", f.producer.Events[0].Payload["metric"])
+	assert.Equal(t, 50, f.producer.Events[0].Payload["threshold"])
+}
+
+func TestTenant_QuotaAlertThresholds(t *testing.T) {
+	tenant := &entity.Tenant{Settings: map[string]string{}}
+	assert.Equal(t, []int{80, 90, 100}, tenant.QuotaAlertThresholds())
+
+	tenant.Settings[entity.TenantSettingQuotaAlertThresholds] = "95,50,0,-5,x"
+	assert.Equal(t, []int{50, 95}, tenant.QuotaAlertThresholds())
+
+	tenant.Settings[entity.TenantSettingQuotaAlertThresholds] = "off"
+	assert.Empty(t, tenant.QuotaAlertThresholds())
+}
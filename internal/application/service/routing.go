@@ -0,0 +1,496 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// RoutingRuleInput represents input for creating or updating a routing rule
+type RoutingRuleInput struct {
+	Name                   string                   `json:"name" binding:"required"`
+	Position               int                      `json:"position"`
+	Enabled                *bool                    `json:"enabled,omitempty"` // Defaults to true
+	Conditions             entity.RoutingConditions `json:"conditions"`
+	RequiredSkills         []string                 `json:"required_skills,omitempty"`
+	MatchLanguage          bool                     `json:"match_language"`
+	TranslationFallback    bool                     `json:"translation_fallback"`
+	MatchChannel           bool                     `json:"match_channel"`
+	AgentIDs               []string                 `json:"agent_ids,omitempty"`
+	Strategy               entity.RoutingStrategy   `json:"strategy,omitempty"` // Defaults to least_loaded
+	MaxActiveConversations int                      `json:"max_active_conversations,omitempty"`
+}
+
+// AgentRoutingProfileInput represents input for setting an agent's routing profile
+type AgentRoutingProfileInput struct {
+	Skills                 []string `json:"skills"`
+	Languages              []string `json:"languages"`
+	Channels               []string `json:"channels"`
+	TranslationAssisted    bool     `json:"translation_assisted"`
+	MaxActiveConversations int      `json:"max_active_conversations,omitempty"`
+}
+
+// RoutingDecision is the outcome of routing a conversation. AgentID is empty when
+// a rule matched but none of its agents could take the conversation. Translated is
+// set when the agent does not speak the conversation language and relies on
+// machine translation.
+type RoutingDecision struct {
+	RuleID     string `json:"rule_id"`
+	RuleName   string `json:"rule_name"`
+	AgentID    string `json:"agent_id,omitempty"`
+	Language   string `json:"language,omitempty"`
+	Translated bool   `json:"translated,omitempty"`
+}
+
+// Conversation metadata written by routing
+const (
+	conversationLanguageKey       = "language"
+	conversationLanguageSourceKey = "language_source" // "detected" when guessed from the customer's messages
+	// ConversationTranslationKey marks conversations routed to a translation-assisted agent
+	ConversationTranslationKey = "routing_translation"
+)
+
+// routingDetectionMessages is how many opening messages the language is detected from
+const routingDetectionMessages = 20
+
+// routingCandidate is an agent eligible under a rule and its current load
+type routingCandidate struct {
+	user *entity.User
+	load int64
+}
+
+// RoutingService assigns escalated conversations to agents by tenant-defined rules
+// matching the conversation's channel, language, labels and priority against the
+// skills, languages, channel competence and load of the available agents.
+type RoutingService struct {
+	repo             repository.RoutingRepository
+	userRepo         repository.UserRepository
+	conversationRepo repository.ConversationRepository
+	contactRepo      repository.ContactRepository
+	messageRepo      repository.MessageRepository
+	channelRepo      repository.ChannelRepository
+}
+
+// NewRoutingService creates a new routing service
+func NewRoutingService(
+	repo repository.RoutingRepository,
+	userRepo repository.UserRepository,
+	conversationRepo repository.ConversationRepository,
+	contactRepo repository.ContactRepository,
+) *RoutingService {
+	return &RoutingService{
+		repo:             repo,
+		userRepo:         userRepo,
+		conversationRepo: conversationRepo,
+		contactRepo:      contactRepo,
+	}
+}
+
+// SetMessageRepository enables detecting the language of conversations that have
+// none set from the customer's messages
+func (s *RoutingService) SetMessageRepository(messageRepo repository.MessageRepository) {
+	s.messageRepo = messageRepo
+}
+
+// SetChannelRepository lets agents be tagged competent on channel types rather than
+// only on individual channels
+func (s *RoutingService) SetChannelRepository(channelRepo repository.ChannelRepository) {
+	s.channelRepo = channelRepo
+}
+
+// CreateRule creates a new routing rule
+func (s *RoutingService) CreateRule(ctx context.Context, tenantID string, input *RoutingRuleInput) (*entity.RoutingRule, error) {
+	if err := validateRoutingRule(input); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	rule := &entity.RoutingRule{
+		ID:        uuid.New().String(),
+		TenantID:  tenantID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	applyRoutingRuleInput(rule, input)
+
+	if err := s.repo.CreateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// GetRule returns a routing rule of the tenant
+func (s *RoutingService) GetRule(ctx context.Context, tenantID, id string) (*entity.RoutingRule, error) {
+	rule, err := s.repo.FindRuleByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if rule.TenantID != tenantID {
+		return nil, errors.NotFound("routing rule")
+	}
+	return rule, nil
+}
+
+// ListRules lists the routing rules of a tenant in evaluation order
+func (s *RoutingService) ListRules(ctx context.Context, tenantID string) ([]*entity.RoutingRule, error) {
+	return s.repo.FindRulesByTenant(ctx, tenantID)
+}
+
+// UpdateRule updates a routing rule
+func (s *RoutingService) UpdateRule(ctx context.Context, tenantID, id string, input *RoutingRuleInput) (*entity.RoutingRule, error) {
+	rule, err := s.GetRule(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRoutingRule(input); err != nil {
+		return nil, err
+	}
+
+	applyRoutingRuleInput(rule, input)
+	rule.UpdatedAt = time.Now()
+
+	if err := s.repo.UpdateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteRule deletes a routing rule
+func (s *RoutingService) DeleteRule(ctx context.Context, tenantID, id string) error {
+	if _, err := s.GetRule(ctx, tenantID, id); err != nil {
+		return err
+	}
+	return s.repo.DeleteRule(ctx, id)
+}
+
+// ListProfiles lists the routing profiles of the tenant's agents
+func (s *RoutingService) ListProfiles(ctx context.Context, tenantID string) ([]*entity.AgentRoutingProfile, error) {
+	return s.repo.FindProfiles(ctx, tenantID)
+}
+
+// SaveProfile sets the skills, languages, channels and load limit of an agent
+func (s *RoutingService) SaveProfile(ctx context.Context, tenantID, userID string, input *AgentRoutingProfileInput) (*entity.AgentRoutingProfile, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TenantID != tenantID {
+		return nil, errors.NotFound("user")
+	}
+	if input.MaxActiveConversations < 0 {
+		return nil, errors.Validation("max_active_conversations must not be negative")
+	}
+
+	profile := &entity.AgentRoutingProfile{
+		UserID:                 userID,
+		TenantID:               tenantID,
+		Skills:                 normalizeRoutingValues(input.Skills),
+		Languages:              normalizeRoutingValues(input.Languages),
+		Channels:               normalizeRoutingValues(input.Channels),
+		TranslationAssisted:    input.TranslationAssisted,
+		MaxActiveConversations: input.MaxActiveConversations,
+		UpdatedAt:              time.Now(),
+	}
+	if err := s.repo.SaveProfile(ctx, profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// Route picks the agent for an escalated conversation among the available candidates.
+// It returns nil when no enabled rule matches, leaving assignment to the default
+// least-loaded behavior, and a decision without agent when matching rules had no
+// eligible agent and the conversation should wait in the queue. A language detected
+// from the customer's messages is recorded in the conversation metadata, which the
+// caller saves.
+func (s *RoutingService) Route(ctx context.Context, conversation *entity.Conversation, candidates []*entity.User) (*RoutingDecision, error) {
+	rules, err := s.repo.FindRulesByTenant(ctx, conversation.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	language := s.conversationLanguage(ctx, conversation)
+	var profiles map[string]*entity.AgentRoutingProfile
+	filter := routingFilter{language: language, channelID: conversation.ChannelID}
+	channelLoaded := false
+	loads := make(map[string]int64)
+
+	var queued *RoutingDecision
+	for _, rule := range rules {
+		if !rule.Enabled || !rule.Matches(conversation, language) {
+			continue
+		}
+		if queued == nil {
+			queued = &RoutingDecision{RuleID: rule.ID, RuleName: rule.Name, Language: language}
+		}
+
+		if profiles == nil {
+			if profiles, err = s.loadProfiles(ctx, conversation.TenantID); err != nil {
+				return nil, err
+			}
+		}
+		if rule.MatchChannel && !channelLoaded {
+			filter.channelType = s.channelType(ctx, conversation.ChannelID)
+			channelLoaded = true
+		}
+
+		filter.translated = false
+		eligible := s.eligibleAgents(ctx, rule, candidates, profiles, filter, loads)
+		if len(eligible) == 0 && rule.MatchLanguage && rule.TranslationFallback && language != "" {
+			filter.translated = true
+			eligible = s.eligibleAgents(ctx, rule, candidates, profiles, filter, loads)
+		}
+		if len(eligible) == 0 {
+			continue
+		}
+
+		agent := pickRoutingAgent(rule, eligible)
+		if rule.Strategy == entity.RoutingStrategyRoundRobin {
+			if err := s.repo.UpdateLastAssigned(ctx, rule.ID, agent.ID); err != nil {
+				logger.Warn("Failed to move routing rule cursor",
+					zap.String("rule_id", rule.ID),
+					zap.Error(err),
+				)
+			}
+		}
+		if filter.translated && conversation.Metadata != nil {
+			conversation.Metadata[ConversationTranslationKey] = "true"
+		}
+		return &RoutingDecision{
+			RuleID:     rule.ID,
+			RuleName:   rule.Name,
+			AgentID:    agent.ID,
+			Language:   language,
+			Translated: filter.translated,
+		}, nil
+	}
+
+	return queued, nil
+}
+
+// routingFilter is what an agent must handle to take a conversation under a rule
+type routingFilter struct {
+	language    string
+	channelID   string
+	channelType entity.ChannelType
+	translated  bool // Translation-assisted agents stand in for speakers of the language
+}
+
+// eligibleAgents keeps the candidates a rule may assign and records their load
+func (s *RoutingService) eligibleAgents(
+	ctx context.Context,
+	rule *entity.RoutingRule,
+	candidates []*entity.User,
+	profiles map[string]*entity.AgentRoutingProfile,
+	filter routingFilter,
+	loads map[string]int64,
+) []routingCandidate {
+	var eligible []routingCandidate
+	for _, user := range candidates {
+		if len(rule.AgentIDs) > 0 && !slices.Contains(rule.AgentIDs, user.ID) {
+			continue
+		}
+
+		profile := profiles[user.ID]
+		if profile == nil {
+			profile = &entity.AgentRoutingProfile{UserID: user.ID}
+		}
+		if !profile.HasSkills(rule.RequiredSkills) {
+			continue
+		}
+		if rule.MatchLanguage && filter.language != "" {
+			if filter.translated && !profile.TranslationAssisted {
+				continue
+			}
+			if !filter.translated && !profile.SpeaksLanguage(filter.language) {
+				continue
+			}
+		}
+		if rule.MatchChannel && !profile.HandlesChannel(filter.channelID, filter.channelType) {
+			continue
+		}
+
+		load, ok := loads[user.ID]
+		if !ok {
+			var err error
+			if load, err = s.conversationRepo.CountActiveByUser(ctx, user.ID); err != nil {
+				continue
+			}
+			loads[user.ID] = load
+		}
+
+		limit := rule.MaxActiveConversations
+		if limit == 0 {
+			limit = profile.MaxActiveConversations
+		}
+		if limit > 0 && load >= int64(limit) {
+			continue
+		}
+
+		eligible = append(eligible, routingCandidate{user: user, load: load})
+	}
+	return eligible
+}
+
+// loadProfiles indexes the routing profiles of a tenant by user
+func (s *RoutingService) loadProfiles(ctx context.Context, tenantID string) (map[string]*entity.AgentRoutingProfile, error) {
+	list, err := s.repo.FindProfiles(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	profiles := make(map[string]*entity.AgentRoutingProfile, len(list))
+	for _, profile := range list {
+		profiles[profile.UserID] = profile
+	}
+	return profiles, nil
+}
+
+// conversationLanguage returns the language set on the conversation or, failing
+// that, on the contact, or else the language detected from the customer's messages
+func (s *RoutingService) conversationLanguage(ctx context.Context, conversation *entity.Conversation) string {
+	if language := conversation.Metadata[conversationLanguageKey]; language != "" {
+		return language
+	}
+	if s.contactRepo != nil && conversation.ContactID != "" {
+		contact, err := s.contactRepo.FindByID(ctx, conversation.ContactID)
+		if err == nil && contact.CustomFields[conversationLanguageKey] != "" {
+			return contact.CustomFields[conversationLanguageKey]
+		}
+	}
+
+	language := s.detectLanguage(ctx, conversation.ID)
+	if language != "" && conversation.Metadata != nil {
+		conversation.Metadata[conversationLanguageKey] = language
+		conversation.Metadata[conversationLanguageSourceKey] = "detected"
+	}
+	return language
+}
+
+// detectLanguage guesses the conversation language from the opening text messages of the customer
+func (s *RoutingService) detectLanguage(ctx context.Context, conversationID string) string {
+	if s.messageRepo == nil || conversationID == "" {
+		return ""
+	}
+	messages, err := s.messageRepo.FindByConversationAfter(ctx, conversationID, time.Time{}, routingDetectionMessages)
+	if err != nil {
+		logger.Warn("Failed to load messages for language detection",
+			zap.String("conversation_id", conversationID),
+			zap.Error(err),
+		)
+		return ""
+	}
+
+	var text strings.Builder
+	for _, message := range messages {
+		if message.SenderType == entity.SenderTypeContact && message.ContentType == entity.ContentTypeText {
+			text.WriteString(message.Content)
+			text.WriteString("\n")
+		}
+	}
+	return DetectLanguage(text.String())
+}
+
+// channelType returns the type of a channel, or an empty type when it cannot be loaded
+func (s *RoutingService) channelType(ctx context.Context, channelID string) entity.ChannelType {
+	if s.channelRepo == nil || channelID == "" {
+		return ""
+	}
+	channel, err := s.channelRepo.FindByID(ctx, channelID)
+	if err != nil {
+		return ""
+	}
+	return channel.Type
+}
+
+// pickRoutingAgent applies the rule's strategy to its eligible agents
+func pickRoutingAgent(rule *entity.RoutingRule, eligible []routingCandidate) *entity.User {
+	if rule.Strategy == entity.RoutingStrategyRoundRobin {
+		sort.Slice(eligible, func(i, j int) bool { return eligible[i].user.ID < eligible[j].user.ID })
+		for _, candidate := range eligible {
+			if candidate.user.ID > rule.LastAssignedUserID {
+				return candidate.user
+			}
+		}
+		return eligible[0].user
+	}
+
+	best := eligible[0]
+	for _, candidate := range eligible[1:] {
+		if candidate.load < best.load {
+			best = candidate
+		}
+	}
+	return best.user
+}
+
+// validateRoutingRule checks a routing rule input
+func validateRoutingRule(input *RoutingRuleInput) error {
+	if strings.TrimSpace(input.Name) == "" {
+		return errors.Validation("name is required")
+	}
+	if input.Strategy != "" && !input.Strategy.IsValid() {
+		return errors.Validation("strategy must be least_loaded or round_robin")
+	}
+	if input.MaxActiveConversations < 0 {
+		return errors.Validation("max_active_conversations must not be negative")
+	}
+	if input.TranslationFallback && !input.MatchLanguage {
+		return errors.Validation("translation_fallback requires match_language")
+	}
+	for _, priority := range input.Conditions.Priorities {
+		switch priority {
+		case entity.ConversationPriorityLow, entity.ConversationPriorityNormal,
+			entity.ConversationPriorityHigh, entity.ConversationPriorityUrgent:
+		default:
+			return errors.Validation("invalid priority " + string(priority))
+		}
+	}
+	return nil
+}
+
+// applyRoutingRuleInput copies a validated input onto a rule
+func applyRoutingRuleInput(rule *entity.RoutingRule, input *RoutingRuleInput) {
+	rule.Name = strings.TrimSpace(input.Name)
+	rule.Position = input.Position
+	rule.Enabled = input.Enabled == nil || *input.Enabled
+	rule.Conditions = entity.RoutingConditions{
+		ChannelIDs: normalizeRoutingValues(input.Conditions.ChannelIDs),
+		Languages:  normalizeRoutingValues(input.Conditions.Languages),
+		Tags:       normalizeRoutingValues(input.Conditions.Tags),
+		Priorities: input.Conditions.Priorities,
+	}
+	rule.RequiredSkills = normalizeRoutingValues(input.RequiredSkills)
+	rule.MatchLanguage = input.MatchLanguage
+	rule.TranslationFallback = input.TranslationFallback
+	rule.MatchChannel = input.MatchChannel
+	rule.AgentIDs = normalizeRoutingValues(input.AgentIDs)
+	rule.Strategy = input.Strategy
+	if rule.Strategy == "" {
+		rule.Strategy = entity.RoutingStrategyLeastLoaded
+	}
+	rule.MaxActiveConversations = input.MaxActiveConversations
+}
+
+// normalizeRoutingValues trims values and drops blanks and duplicates
+func normalizeRoutingValues(values []string) []string {
+	normalized := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value != "" && !slices.Contains(normalized, value) {
+			normalized = append(normalized, value)
+		}
+	}
+	return normalized
+}
@@ -0,0 +1,352 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockRoutingRepository struct {
+	rules    []*entity.RoutingRule
+	profiles map[string]*entity.AgentRoutingProfile
+}
+
+func newMockRoutingRepository() *mockRoutingRepository {
+	return &mockRoutingRepository{profiles: make(map[string]*entity.AgentRoutingProfile)}
+}
+
+func (m *mockRoutingRepository) CreateRule(ctx context.Context, rule *entity.RoutingRule) error {
+	m.rules = append(m.rules, rule)
+	return nil
+}
+
+func (m *mockRoutingRepository) FindRuleByID(ctx context.Context, id string) (*entity.RoutingRule, error) {
+	for _, rule := range m.rules {
+		if rule.ID == id {
+			return rule, nil
+		}
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "routing rule not found")
+}
+
+func (m *mockRoutingRepository) FindRulesByTenant(ctx context.Context, tenantID string) ([]*entity.RoutingRule, error) {
+	var rules []*entity.RoutingRule
+	for _, rule := range m.rules {
+		if rule.TenantID == tenantID {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+func (m *mockRoutingRepository) UpdateRule(ctx context.Context, rule *entity.RoutingRule) error {
+	return nil
+}
+
+func (m *mockRoutingRepository) DeleteRule(ctx context.Context, id string) error {
+	for i, rule := range m.rules {
+		if rule.ID == id {
+			m.rules = append(m.rules[:i], m.rules[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New(errors.ErrCodeNotFound, "routing rule not found")
+}
+
+func (m *mockRoutingRepository) UpdateLastAssigned(ctx context.Context, ruleID, userID string) error {
+	for _, rule := range m.rules {
+		if rule.ID == ruleID {
+			rule.LastAssignedUserID = userID
+		}
+	}
+	return nil
+}
+
+func (m *mockRoutingRepository) FindProfiles(ctx context.Context, tenantID string) ([]*entity.AgentRoutingProfile, error) {
+	var profiles []*entity.AgentRoutingProfile
+	for _, profile := range m.profiles {
+		if profile.TenantID == tenantID {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles, nil
+}
+
+func (m *mockRoutingRepository) SaveProfile(ctx context.Context, profile *entity.AgentRoutingProfile) error {
+	m.profiles[profile.UserID] = profile
+	return nil
+}
+
+type routingTestDeps struct {
+	repo             *mockRoutingRepository
+	userRepo         *testutil.MockUserRepository
+	conversationRepo *testutil.MockConversationRepository
+	contactRepo      *testutil.MockContactRepository
+	svc              *RoutingService
+	agents           []*entity.User
+}
+
+func setupRoutingTest() *routingTestDeps {
+	d := &routingTestDeps{
+		repo:             newMockRoutingRepository(),
+		userRepo:         testutil.NewMockUserRepository(),
+		conversationRepo: testutil.NewMockConversationRepository(),
+		contactRepo:      testutil.NewMockContactRepository(),
+	}
+	for _, id := range []string{"agent-a", "agent-b", "agent-c"} {
+		agent := &entity.User{ID: id, TenantID: "tenant-1", Role: entity.UserRoleAgent, Status: entity.UserStatusActive}
+		d.userRepo.Users[id] = agent
+		d.agents = append(d.agents, agent)
+	}
+	d.repo.profiles["agent-a"] = &entity.AgentRoutingProfile{UserID: "agent-a", TenantID: "tenant-1", Skills: []string{"billing"}, Languages: []string{"en"}}
+	d.repo.profiles["agent-b"] = &entity.AgentRoutingProfile{UserID: "agent-b", TenantID: "tenant-1", Skills: []string{"billing", "refunds"}, Languages: []string{"pt"}}
+	d.svc = NewRoutingService(d.repo, d.userRepo, d.conversationRepo, d.contactRepo)
+	return d
+}
+
+func (d *routingTestDeps) assign(id, userID string) {
+	conv := &entity.Conversation{ID: id, TenantID: "tenant-1", Status: entity.ConversationStatusOpen, AssignedUserID: &userID}
+	d.conversationRepo.Conversations[id] = conv
+}
+
+func (d *routingTestDeps) addRule(t *testing.T, input *RoutingRuleInput) *entity.RoutingRule {
+	rule, err := d.svc.CreateRule(context.Background(), "tenant-1", input)
+	require.NoError(t, err)
+	return rule
+}
+
+func routingConversation(language string) *entity.Conversation {
+	return &entity.Conversation{
+		ID:        "conv-1",
+		TenantID:  "tenant-1",
+		ChannelID: "channel-1",
+		Priority:  entity.ConversationPriorityNormal,
+		Metadata:  map[string]string{"language": language},
+	}
+}
+
+func TestRoutingService_Route_NoRules(t *testing.T) {
+	d := setupRoutingTest()
+
+	decision, err := d.svc.Route(context.Background(), routingConversation("en"), d.agents)
+	require.NoError(t, err)
+	assert.Nil(t, decision, "without rules the default assignment applies")
+}
+
+func TestRoutingService_Route_SkillsAndLanguage(t *testing.T) {
+	d := setupRoutingTest()
+	rule := d.addRule(t, &RoutingRuleInput{
+		Name:           "Billing",
+		RequiredSkills: []string{"billing"},
+		MatchLanguage:  true,
+	})
+
+	decision, err := d.svc.Route(context.Background(), routingConversation("pt-BR"), d.agents)
+	require.NoError(t, err)
+	require.NotNil(t, decision)
+	assert.Equal(t, rule.ID, decision.RuleID)
+	assert.Equal(t, "agent-b", decision.AgentID, "only agent-b has the skill and speaks Portuguese")
+}
+
+func TestRoutingService_Route_LeastLoaded(t *testing.T) {
+	d := setupRoutingTest()
+	d.addRule(t, &RoutingRuleInput{Name: "Billing", RequiredSkills: []string{"billing"}})
+	d.assign("busy-1", "agent-a")
+	d.assign("busy-2", "agent-a")
+	d.assign("busy-3", "agent-b")
+
+	decision, err := d.svc.Route(context.Background(), routingConversation(""), d.agents)
+	require.NoError(t, err)
+	assert.Equal(t, "agent-b", decision.AgentID)
+}
+
+func TestRoutingService_Route_RoundRobin(t *testing.T) {
+	d := setupRoutingTest()
+	d.addRule(t, &RoutingRuleInput{Name: "Everyone", Strategy: entity.RoutingStrategyRoundRobin})
+	ctx := context.Background()
+
+	var assigned []string
+	for i := 0; i < 4; i++ {
+		decision, err := d.svc.Route(ctx, routingConversation(""), d.agents)
+		require.NoError(t, err)
+		assigned = append(assigned, decision.AgentID)
+	}
+	assert.Equal(t, []string{"agent-a", "agent-b", "agent-c", "agent-a"}, assigned)
+}
+
+func TestRoutingService_Route_QueuesWhenNoAgentQualifies(t *testing.T) {
+	d := setupRoutingTest()
+	rule := d.addRule(t, &RoutingRuleInput{
+		Name:                   "Refunds",
+		RequiredSkills:         []string{"refunds"},
+		MaxActiveConversations: 1,
+	})
+	d.assign("busy-1", "agent-b")
+
+	decision, err := d.svc.Route(context.Background(), routingConversation(""), d.agents)
+	require.NoError(t, err)
+	require.NotNil(t, decision)
+	assert.Equal(t, rule.ID, decision.RuleID)
+	assert.Empty(t, decision.AgentID)
+}
+
+func TestRoutingService_Route_Conditions(t *testing.T) {
+	d := setupRoutingTest()
+	disabled := false
+	d.addRule(t, &RoutingRuleInput{Name: "Disabled", Enabled: &disabled})
+	d.addRule(t, &RoutingRuleInput{
+		Name:       "Channel 2",
+		Conditions: entity.RoutingConditions{ChannelIDs: []string{"channel-2"}},
+	})
+	spanish := d.addRule(t, &RoutingRuleInput{
+		Name:       "Spanish",
+		Conditions: entity.RoutingConditions{Languages: []string{"es"}},
+		AgentIDs:   []string{"agent-c"},
+	})
+
+	conv := routingConversation("")
+	conv.ContactID = "contact-1"
+	d.contactRepo.Contacts["contact-1"] = &entity.Contact{ID: "contact-1", CustomFields: map[string]string{"language": "es"}}
+
+	decision, err := d.svc.Route(context.Background(), conv, d.agents)
+	require.NoError(t, err)
+	assert.Equal(t, spanish.ID, decision.RuleID, "language falls back to the contact")
+	assert.Equal(t, "agent-c", decision.AgentID)
+
+	decision, err = d.svc.Route(context.Background(), routingConversation("en"), d.agents)
+	require.NoError(t, err)
+	assert.Nil(t, decision)
+}
+
+func TestRoutingService_CreateRule_Validation(t *testing.T) {
+	d := setupRoutingTest()
+	ctx := context.Background()
+
+	_, err := d.svc.CreateRule(ctx, "tenant-1", &RoutingRuleInput{Name: " "})
+	assert.Error(t, err)
+
+	_, err = d.svc.CreateRule(ctx, "tenant-1", &RoutingRuleInput{Name: "Bad", Strategy: "random"})
+	assert.Error(t, err)
+
+	rule, err := d.svc.CreateRule(ctx, "tenant-1", &RoutingRuleInput{Name: "Default", RequiredSkills: []string{" billing ", "billing", ""}})
+	require.NoError(t, err)
+	assert.True(t, rule.Enabled)
+	assert.Equal(t, entity.RoutingStrategyLeastLoaded, rule.Strategy)
+	assert.Equal(t, []string{"billing"}, rule.RequiredSkills)
+
+	_, err = d.svc.GetRule(ctx, "tenant-2", rule.ID)
+	assert.Equal(t, errors.ErrCodeNotFound, errors.GetAppError(err).Code)
+}
+
+func TestRoutingService_SaveProfile(t *testing.T) {
+	d := setupRoutingTest()
+	ctx := context.Background()
+
+	profile, err := d.svc.SaveProfile(ctx, "tenant-1", "agent-c", &AgentRoutingProfileInput{Skills: []string{"sales"}, Languages: []string{"es"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sales"}, d.repo.profiles["agent-c"].Skills)
+	assert.Equal(t, "tenant-1", profile.TenantID)
+
+	_, err = d.svc.SaveProfile(ctx, "tenant-2", "agent-c", &AgentRoutingProfileInput{})
+	assert.Equal(t, errors.ErrCodeNotFound, errors.GetAppError(err).Code)
+}
+
+func TestRoutingService_Route_TranslationFallback(t *testing.T) {
+	d := setupRoutingTest()
+	d.repo.profiles["agent-c"] = &entity.AgentRoutingProfile{UserID: "agent-c", TenantID: "tenant-1", TranslationAssisted: true}
+	rule := d.addRule(t, &RoutingRuleInput{
+		Name:                "Native speakers",
+		MatchLanguage:       true,
+		TranslationFallback: true,
+	})
+
+	conv := routingConversation("de")
+	decision, err := d.svc.Route(context.Background(), conv, d.agents)
+	require.NoError(t, err)
+	require.NotNil(t, decision)
+	assert.Equal(t, rule.ID, decision.RuleID)
+	assert.Equal(t, "agent-c", decision.AgentID, "nobody speaks German, agent-c works with translation")
+	assert.True(t, decision.Translated)
+	assert.Equal(t, "true", conv.Metadata[ConversationTranslationKey])
+
+	conv = routingConversation("en")
+	decision, err = d.svc.Route(context.Background(), conv, d.agents)
+	require.NoError(t, err)
+	assert.Equal(t, "agent-a", decision.AgentID, "native speakers come first")
+	assert.False(t, decision.Translated)
+	assert.Empty(t, conv.Metadata[ConversationTranslationKey])
+
+	_, err = d.svc.CreateRule(context.Background(), "tenant-1", &RoutingRuleInput{Name: "Bad", TranslationFallback: true})
+	assert.Error(t, err, "translation fallback only applies to language matching")
+}
+
+func TestRoutingService_Route_ChannelCompetence(t *testing.T) {
+	d := setupRoutingTest()
+	channelRepo := testutil.NewMockChannelRepository()
+	channelRepo.Channels["channel-1"] = &entity.Channel{ID: "channel-1", TenantID: "tenant-1", Type: entity.ChannelTypeWhatsApp}
+	d.svc.SetChannelRepository(channelRepo)
+	d.repo.profiles["agent-a"].Channels = []string{"webchat"}
+	d.repo.profiles["agent-b"].Channels = []string{"WhatsApp"}
+	d.repo.profiles["agent-c"] = &entity.AgentRoutingProfile{UserID: "agent-c", TenantID: "tenant-1", Channels: []string{"channel-2"}}
+	d.addRule(t, &RoutingRuleInput{Name: "Channel experts", MatchChannel: true})
+
+	decision, err := d.svc.Route(context.Background(), routingConversation(""), d.agents)
+	require.NoError(t, err)
+	assert.Equal(t, "agent-b", decision.AgentID, "agent-b is tagged for the WhatsApp channel type")
+
+	conv := routingConversation("")
+	conv.ChannelID = "channel-2"
+	decision, err = d.svc.Route(context.Background(), conv, d.agents)
+	require.NoError(t, err)
+	assert.Equal(t, "agent-c", decision.AgentID, "agent-c is tagged for the channel itself")
+}
+
+func TestRoutingService_Route_DetectsLanguage(t *testing.T) {
+	d := setupRoutingTest()
+	messageRepo := testutil.NewMockMessageRepository()
+	now := time.Now()
+	for i, msg := range []*entity.Message{
+		{ID: "msg-1", SenderType: entity.SenderTypeContact, ContentType: entity.ContentTypeText, Content: "Olá, tudo bem?"},
+		{ID: "msg-2", SenderType: entity.SenderTypeSystem, ContentType: entity.ContentTypeText, Content: "Hello, how can we help you with your order?"},
+		{ID: "msg-3", SenderType: entity.SenderTypeContact, ContentType: entity.ContentTypeText, Content: "Não recebi o meu pedido"},
+	} {
+		msg.ConversationID = "conv-1"
+		msg.CreatedAt = now.Add(time.Duration(i) * time.Second)
+		messageRepo.Messages[msg.ID] = msg
+	}
+	d.svc.SetMessageRepository(messageRepo)
+	d.addRule(t, &RoutingRuleInput{Name: "Native speakers", MatchLanguage: true})
+
+	conv := routingConversation("")
+	decision, err := d.svc.Route(context.Background(), conv, d.agents)
+	require.NoError(t, err)
+	assert.Equal(t, "agent-b", decision.AgentID)
+	assert.Equal(t, "pt", decision.Language)
+	assert.Equal(t, "pt", conv.Metadata["language"])
+	assert.Equal(t, "detected", conv.Metadata["language_source"])
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Hello, I have not received my order yet", "en"},
+		{"Olá, não recebi o meu pedido", "pt"},
+		{"Hola, no he recibido mi pedido, gracias", "es"},
+		{"Bonjour, je n'ai pas reçu ma commande", "fr"},
+		{"Hallo, ich habe meine Bestellung nicht bekommen", "de"},
+		{"Ciao, non ho ricevuto il mio ordine", "it"},
+		{"ok", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, DetectLanguage(tt.text), tt.text)
+	}
+}
@@ -0,0 +1,1299 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	stderrors "errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/calendar"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Events published by scheduling
+const (
+	EventBookingConfirmed   = "booking.confirmed"
+	EventBookingRescheduled = "booking.rescheduled"
+	EventBookingCancelled   = "booking.cancelled"
+)
+
+// Booking calendar defaults and limits
+const (
+	DefaultBookingSlotMinutes  = 30
+	DefaultBookingMaxDaysAhead = 14
+	MaxBookingDaysAhead        = 90
+	DefaultOfferedSlots        = 5
+	MaxOfferedSlots            = 10
+)
+
+// Reply IDs of the booking buttons and list rows
+const (
+	bookingSlotPrefix       = "booking:" // booking:<booking id>:<slot index>
+	bookingCancelPrefix     = "booking_cancel:"
+	bookingReschedulePrefix = "booking_reschedule:"
+)
+
+// Message metadata keys linking the booking messages of a conversation to their booking
+const (
+	MessageMetaBookingID    = "booking_id"
+	MessageMetaBookingEvent = "booking_event"
+)
+
+// bookingOfferReplyWindow is how long a numbered reply can pick an offered slot
+const bookingOfferReplyWindow = 24 * time.Hour
+
+// bookingTokenRefreshMargin refreshes access tokens this long before they expire
+const bookingTokenRefreshMargin = time.Minute
+
+// bookingSlotLayout fits a slot in the 20 characters of a reply button
+const bookingSlotLayout = "Mon 02 Jan 15:04"
+
+// BookingCalendarInput represents input for creating or updating a booking calendar
+type BookingCalendarInput struct {
+	Name               string               `json:"name" binding:"required"`
+	Provider           string               `json:"provider" binding:"required"`
+	ExternalCalendarID string               `json:"external_calendar_id"`
+	Timezone           string               `json:"timezone"`
+	SlotMinutes        int                  `json:"slot_minutes"`
+	BufferMinutes      int                  `json:"buffer_minutes"`
+	MinNoticeMinutes   int                  `json:"min_notice_minutes"`
+	MaxDaysAhead       int                  `json:"max_days_ahead"`
+	Availability       []entity.DaySchedule `json:"availability" binding:"required"`
+	ReminderMinutes    []int                `json:"reminder_minutes,omitempty"`
+	EventTitle         string               `json:"event_title"`
+	RefreshToken       string               `json:"refresh_token,omitempty"` // Replaces the stored credentials when set
+	IsActive           *bool                `json:"is_active,omitempty"`
+}
+
+// OfferSlotsInput represents a request to offer the free slots of a calendar in a conversation
+type OfferSlotsInput struct {
+	CalendarID     string `json:"calendar_id" binding:"required"`
+	ConversationID string `json:"conversation_id" binding:"required"`
+	Title          string `json:"title"`
+	Message        string `json:"message"` // Text shown above the slots
+	Limit          int    `json:"limit"`
+	SenderID       string `json:"-"`
+}
+
+// BookInput represents a booking made directly by an agent or integration
+type BookInput struct {
+	CalendarID     string    `json:"calendar_id" binding:"required"`
+	ConversationID string    `json:"conversation_id" binding:"required"`
+	Start          time.Time `json:"start" binding:"required"`
+	Title          string    `json:"title"`
+	CreatedBy      string    `json:"-"`
+}
+
+// PublicBooking is what the reschedule and cancel links of a booking show
+type PublicBooking struct {
+	Booking        *entity.Booking   `json:"booking"`
+	CalendarName   string            `json:"calendar_name"`
+	Timezone       string            `json:"timezone"`
+	AvailableSlots []entity.TimeSlot `json:"available_slots,omitempty"`
+}
+
+// SchedulingService lets agents and bots offer the free slots of a booking
+// calendar in a conversation. The slot the contact picks is booked on the
+// external calendar, and reminders carrying reschedule and cancel options are
+// sent to the conversation before the appointment.
+type SchedulingService struct {
+	calendarRepo     repository.BookingCalendarRepository
+	bookingRepo      repository.BookingRepository
+	conversationRepo repository.ConversationRepository
+	contactRepo      repository.ContactRepository
+	channelRepo      repository.ChannelRepository
+	messageService   *MessageService
+	producer         nats.Publisher
+	builder          *InteractiveBuilderService
+	providers        map[entity.CalendarProvider]calendar.Provider
+	linkBaseURL      string
+}
+
+// NewSchedulingService creates a new scheduling service. linkBaseURL is the
+// address of the public booking endpoints used in reschedule and cancel links.
+func NewSchedulingService(
+	calendarRepo repository.BookingCalendarRepository,
+	bookingRepo repository.BookingRepository,
+	conversationRepo repository.ConversationRepository,
+	contactRepo repository.ContactRepository,
+	channelRepo repository.ChannelRepository,
+	messageService *MessageService,
+	producer nats.Publisher,
+	linkBaseURL string,
+) *SchedulingService {
+	return &SchedulingService{
+		calendarRepo:     calendarRepo,
+		bookingRepo:      bookingRepo,
+		conversationRepo: conversationRepo,
+		contactRepo:      contactRepo,
+		channelRepo:      channelRepo,
+		messageService:   messageService,
+		producer:         producer,
+		builder:          NewInteractiveBuilderService(),
+		providers:        make(map[entity.CalendarProvider]calendar.Provider),
+		linkBaseURL:      strings.TrimRight(linkBaseURL, "/"),
+	}
+}
+
+// SetCalendarProvider registers the external calendar used by calendars of the provider's name
+func (s *SchedulingService) SetCalendarProvider(provider calendar.Provider) {
+	s.providers[entity.CalendarProvider(provider.Name())] = provider
+}
+
+// CreateCalendar creates a booking calendar
+func (s *SchedulingService) CreateCalendar(ctx context.Context, tenantID string, input *BookingCalendarInput) (*entity.BookingCalendar, error) {
+	now := time.Now()
+	cal := &entity.BookingCalendar{
+		ID:        uuid.New().String(),
+		TenantID:  tenantID,
+		IsActive:  true,
+		CreatedAt: now,
+	}
+	if err := applyBookingCalendarInput(cal, input, now); err != nil {
+		return nil, err
+	}
+
+	if err := s.calendarRepo.Create(ctx, cal); err != nil {
+		return nil, err
+	}
+	return cal, nil
+}
+
+// GetCalendar returns a booking calendar of a tenant
+func (s *SchedulingService) GetCalendar(ctx context.Context, tenantID, id string) (*entity.BookingCalendar, error) {
+	cal, err := s.calendarRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if cal.TenantID != tenantID {
+		return nil, errors.NotFound("booking calendar")
+	}
+	return cal, nil
+}
+
+// ListCalendars lists the booking calendars of a tenant
+func (s *SchedulingService) ListCalendars(ctx context.Context, tenantID string) ([]*entity.BookingCalendar, error) {
+	return s.calendarRepo.ListByTenant(ctx, tenantID)
+}
+
+// UpdateCalendar updates a booking calendar. Stored credentials are kept unless
+// a new refresh token is given.
+func (s *SchedulingService) UpdateCalendar(ctx context.Context, tenantID, id string, input *BookingCalendarInput) (*entity.BookingCalendar, error) {
+	cal, err := s.GetCalendar(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyBookingCalendarInput(cal, input, time.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.calendarRepo.Update(ctx, cal); err != nil {
+		return nil, err
+	}
+	return cal, nil
+}
+
+// DeleteCalendar deletes a booking calendar and its bookings
+func (s *SchedulingService) DeleteCalendar(ctx context.Context, tenantID, id string) error {
+	if _, err := s.GetCalendar(ctx, tenantID, id); err != nil {
+		return err
+	}
+	return s.calendarRepo.Delete(ctx, id)
+}
+
+func applyBookingCalendarInput(cal *entity.BookingCalendar, input *BookingCalendarInput, now time.Time) error {
+	details := map[string]string{}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		details["name"] = "name is required"
+	}
+	provider := entity.CalendarProvider(strings.ToLower(strings.TrimSpace(input.Provider)))
+	if !provider.IsValid() {
+		details["provider"] = "provider must be google or microsoft"
+	}
+
+	timezone := strings.TrimSpace(input.Timezone)
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		details["timezone"] = "unknown timezone"
+	}
+
+	slotMinutes := input.SlotMinutes
+	if slotMinutes == 0 {
+		slotMinutes = DefaultBookingSlotMinutes
+	}
+	if slotMinutes < 5 || slotMinutes > 24*60 {
+		details["slot_minutes"] = "slot_minutes must be between 5 and 1440"
+	}
+	if input.BufferMinutes < 0 || input.MinNoticeMinutes < 0 {
+		details["buffer_minutes"] = "buffer and notice minutes cannot be negative"
+	}
+
+	maxDays := input.MaxDaysAhead
+	if maxDays == 0 {
+		maxDays = DefaultBookingMaxDaysAhead
+	}
+	if maxDays < 1 || maxDays > MaxBookingDaysAhead {
+		details["max_days_ahead"] = fmt.Sprintf("max_days_ahead must be between 1 and %d", MaxBookingDaysAhead)
+	}
+
+	if len(input.Availability) == 0 {
+		details["availability"] = "at least one weekly availability window is required"
+	}
+	for i, day := range input.Availability {
+		field := fmt.Sprintf("availability[%d]", i)
+		if day.Day < 0 || day.Day > 6 {
+			details[field] = "day must be between 0 (Sunday) and 6 (Saturday)"
+			continue
+		}
+		start, errStart := parseClock(day.StartTime)
+		end, errEnd := parseClock(day.EndTime)
+		if errStart != nil || errEnd != nil || end <= start {
+			details[field] = "start_time and end_time must be HH:MM with end after start"
+		}
+	}
+
+	reminders := make([]int, 0, len(input.ReminderMinutes))
+	seen := map[int]bool{}
+	for _, minutes := range input.ReminderMinutes {
+		if minutes <= 0 {
+			details["reminder_minutes"] = "reminders must be a positive number of minutes before the booking"
+			continue
+		}
+		if !seen[minutes] {
+			seen[minutes] = true
+			reminders = append(reminders, minutes)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(reminders)))
+
+	if len(details) > 0 {
+		return errors.New(errors.ErrCodeValidation, "invalid booking calendar").WithDetails(details)
+	}
+
+	cal.Name = name
+	cal.Provider = provider
+	cal.ExternalCalendarID = strings.TrimSpace(input.ExternalCalendarID)
+	cal.Timezone = timezone
+	cal.SlotMinutes = slotMinutes
+	cal.BufferMinutes = input.BufferMinutes
+	cal.MinNoticeMinutes = input.MinNoticeMinutes
+	cal.MaxDaysAhead = maxDays
+	cal.Availability = input.Availability
+	cal.ReminderMinutes = reminders
+	cal.EventTitle = strings.TrimSpace(input.EventTitle)
+	if input.IsActive != nil {
+		cal.IsActive = *input.IsActive
+	}
+	if token := strings.TrimSpace(input.RefreshToken); token != "" {
+		cal.Credentials = &entity.CalendarCredentials{RefreshToken: token}
+	}
+	cal.Connected = cal.Credentials != nil && cal.Credentials.RefreshToken != ""
+	cal.UpdatedAt = now
+	return nil
+}
+
+// parseClock parses HH:MM into minutes since midnight
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// AvailableSlots returns up to limit free slots of a calendar, soonest first
+func (s *SchedulingService) AvailableSlots(ctx context.Context, tenantID, calendarID string, limit int) ([]entity.TimeSlot, error) {
+	cal, err := s.GetCalendar(ctx, tenantID, calendarID)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	return s.freeSlots(ctx, cal, time.Time{}, time.Time{}, limit, nil)
+}
+
+// freeSlots generates the slots of the weekly availability between from and to,
+// clamped to the calendar's notice and horizon, that do not overlap a busy
+// period of the external calendar or another booking. ignore is a booking being
+// rescheduled, whose own time does not count as busy.
+func (s *SchedulingService) freeSlots(ctx context.Context, cal *entity.BookingCalendar, from, to time.Time, limit int, ignore *entity.Booking) ([]entity.TimeSlot, error) {
+	loc, err := time.LoadLocation(cal.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+
+	now := time.Now()
+	earliest := now.Add(time.Duration(cal.MinNoticeMinutes) * time.Minute)
+	if from.After(earliest) {
+		earliest = from
+	}
+	horizon := now.AddDate(0, 0, cal.MaxDaysAhead)
+	if !to.IsZero() && to.Before(horizon) {
+		horizon = to
+	}
+	if !earliest.Before(horizon) {
+		return nil, nil
+	}
+
+	busy, err := s.busyPeriods(ctx, cal, earliest, horizon, ignore)
+	if err != nil {
+		return nil, err
+	}
+
+	slotLength := time.Duration(cal.SlotMinutes) * time.Minute
+	buffer := time.Duration(cal.BufferMinutes) * time.Minute
+
+	var slots []entity.TimeSlot
+	first := earliest.In(loc)
+	for day := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, loc); day.Before(horizon) && len(slots) < limit; day = day.AddDate(0, 0, 1) {
+		var daySlots []entity.TimeSlot
+		for _, window := range cal.Availability {
+			if window.Day != int(day.Weekday()) {
+				continue
+			}
+			open, errOpen := parseClock(window.StartTime)
+			closing, errClose := parseClock(window.EndTime)
+			if errOpen != nil || errClose != nil {
+				continue
+			}
+			windowEnd := day.Add(time.Duration(closing) * time.Minute)
+			for start := day.Add(time.Duration(open) * time.Minute); !start.Add(slotLength).After(windowEnd); start = start.Add(slotLength) {
+				slot := entity.TimeSlot{Start: start, End: start.Add(slotLength)}
+				if slot.Start.Before(earliest) || slot.End.After(horizon) {
+					continue
+				}
+				if overlapsBusy(slot, busy, buffer) {
+					continue
+				}
+				daySlots = append(daySlots, slot)
+			}
+		}
+		sort.Slice(daySlots, func(i, j int) bool { return daySlots[i].Start.Before(daySlots[j].Start) })
+		slots = append(slots, daySlots...)
+	}
+
+	if len(slots) > limit {
+		slots = slots[:limit]
+	}
+	return slots, nil
+}
+
+func overlapsBusy(slot entity.TimeSlot, busy []calendar.Interval, buffer time.Duration) bool {
+	for _, b := range busy {
+		if slot.Overlaps(b.Start.Add(-buffer), b.End.Add(buffer)) {
+			return true
+		}
+	}
+	return false
+}
+
+// busyPeriods merges the busy periods of the external calendar with the
+// confirmed bookings of the calendar
+func (s *SchedulingService) busyPeriods(ctx context.Context, cal *entity.BookingCalendar, from, to time.Time, ignore *entity.Booking) ([]calendar.Interval, error) {
+	var busy []calendar.Interval
+
+	provider, accessToken, err := s.accessToken(ctx, cal)
+	if err != nil {
+		return nil, err
+	}
+	if provider != nil {
+		external, err := provider.FreeBusy(ctx, accessToken, cal.ExternalCalendarID, from, to)
+		if err != nil {
+			return nil, calendarError(err, "failed to read calendar availability")
+		}
+		for _, b := range external {
+			// The event of the booking being rescheduled
+			if ignore != nil && ignore.StartAt != nil && b.Start.Equal(*ignore.StartAt) && b.End.Equal(*ignore.EndAt) {
+				continue
+			}
+			busy = append(busy, b)
+		}
+	}
+
+	bookings, err := s.bookingRepo.ListConfirmedBetween(ctx, cal.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, booking := range bookings {
+		if ignore != nil && booking.ID == ignore.ID {
+			continue
+		}
+		busy = append(busy, calendar.Interval{Start: *booking.StartAt, End: *booking.EndAt})
+	}
+	return busy, nil
+}
+
+// accessToken returns the provider and a valid access token of a calendar, or a
+// nil provider for calendars without credentials, which only track bookings made here
+func (s *SchedulingService) accessToken(ctx context.Context, cal *entity.BookingCalendar) (calendar.Provider, string, error) {
+	if cal.Credentials == nil || cal.Credentials.RefreshToken == "" {
+		return nil, "", nil
+	}
+	provider, ok := s.providers[cal.Provider]
+	if !ok {
+		return nil, "", errors.New(errors.ErrCodeInternal, fmt.Sprintf("calendar provider %s is not available", cal.Provider))
+	}
+
+	creds := cal.Credentials
+	if creds.AccessToken != "" && time.Now().Add(bookingTokenRefreshMargin).Before(creds.ExpiresAt) {
+		return provider, creds.AccessToken, nil
+	}
+
+	token, err := provider.RefreshToken(ctx, creds.RefreshToken)
+	if err != nil {
+		return nil, "", calendarError(err, "failed to authorize with the calendar")
+	}
+	creds.AccessToken = token.AccessToken
+	creds.ExpiresAt = token.ExpiresAt
+	if token.RefreshToken != "" {
+		creds.RefreshToken = token.RefreshToken
+	}
+	cal.UpdatedAt = time.Now()
+	if err := s.calendarRepo.Update(ctx, cal); err != nil {
+		logger.Warn("Failed to store refreshed calendar token",
+			zap.String("calendar_id", cal.ID),
+			zap.Error(err),
+		)
+	}
+	return provider, token.AccessToken, nil
+}
+
+func calendarError(err error, message string) error {
+	switch {
+	case stderrors.Is(err, calendar.ErrUnauthorized):
+		return errors.New(errors.ErrCodeBadRequest, "calendar credentials were rejected; reconnect the calendar")
+	case stderrors.Is(err, calendar.ErrNotConfigured):
+		return errors.New(errors.ErrCodeBadRequest, "calendar provider is not configured on this server")
+	default:
+		return errors.Wrap(err, errors.ErrCodeInternal, message)
+	}
+}
+
+// OfferSlots sends the free slots of a calendar to a conversation as buttons or
+// a list, or numbered options on text-only channels. The offer replaces any
+// earlier offer of the conversation the contact did not answer.
+func (s *SchedulingService) OfferSlots(ctx context.Context, tenantID string, input *OfferSlotsInput) (*entity.Booking, error) {
+	cal, err := s.GetCalendar(ctx, tenantID, input.CalendarID)
+	if err != nil {
+		return nil, err
+	}
+	if !cal.IsActive {
+		return nil, errors.Validation("booking calendar is inactive")
+	}
+	conversation, channel, err := s.conversation(ctx, tenantID, input.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if previous, err := s.bookingRepo.FindPendingByConversation(ctx, conversation.ID); err == nil {
+		s.closeOffer(ctx, previous, "superseded by a new offer")
+	}
+
+	now := time.Now()
+	booking := &entity.Booking{
+		ID:             uuid.New().String(),
+		TenantID:       tenantID,
+		CalendarID:     cal.ID,
+		ConversationID: conversation.ID,
+		ContactID:      conversation.ContactID,
+		Title:          strings.TrimSpace(input.Title),
+		Status:         entity.BookingStatusPending,
+		Token:          newBookingToken(),
+		CreatedBy:      input.SenderID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	slots, err := s.freeSlots(ctx, cal, time.Time{}, time.Time{}, offerLimit(input.Limit, channel.Type), nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(slots) == 0 {
+		return nil, errors.New(errors.ErrCodeConflict, "the calendar has no free slots")
+	}
+	booking.OfferedSlots = slots
+
+	if err := s.bookingRepo.Create(ctx, booking); err != nil {
+		return nil, err
+	}
+
+	text := strings.TrimSpace(input.Message)
+	if text == "" {
+		text = "Please choose a time that works for you:"
+	}
+	senderType := entity.SenderTypeSystem
+	if input.SenderID != "" {
+		senderType = entity.SenderTypeUser
+	}
+	if err := s.sendOffer(ctx, conversation, channel, cal, booking, text, senderType, input.SenderID); err != nil {
+		return nil, err
+	}
+	return booking, nil
+}
+
+// offerLimit caps the offered slots to what the channel can show at once
+func offerLimit(limit int, channelType entity.ChannelType) int {
+	if limit <= 0 {
+		limit = DefaultOfferedSlots
+	}
+	if limit > MaxOfferedSlots {
+		limit = MaxOfferedSlots
+	}
+	if c, native := entity.InteractiveConstraintsFor(channelType); native && !c.SupportsList && c.MaxButtons < limit {
+		limit = c.MaxButtons
+	}
+	return limit
+}
+
+func (s *SchedulingService) sendOffer(ctx context.Context, conversation *entity.Conversation, channel *entity.Channel, cal *entity.BookingCalendar, booking *entity.Booking, text string, senderType entity.SenderType, senderID string) error {
+	loc := calendarLocation(cal)
+	interactive := &entity.InteractiveMessage{Body: text}
+
+	// A few slots fit in reply buttons; longer offers need a list
+	count := len(booking.OfferedSlots)
+	constraints, native := entity.InteractiveConstraintsFor(channel.Type)
+	if native && constraints.SupportsButtons && count <= constraints.MaxButtons && (count <= 3 || !constraints.SupportsList) {
+		interactive.Type = entity.InteractiveTypeButton
+		for i, slot := range booking.OfferedSlots {
+			interactive.Buttons = append(interactive.Buttons, entity.InteractiveButton{
+				ID:    bookingSlotID(booking.ID, i),
+				Title: slot.Start.In(loc).Format(bookingSlotLayout),
+			})
+		}
+	} else {
+		interactive.Type = entity.InteractiveTypeList
+		interactive.ListButton = "Choose a time"
+		section := entity.InteractiveSection{}
+		for i, slot := range booking.OfferedSlots {
+			section.Rows = append(section.Rows, entity.InteractiveRow{
+				ID:    bookingSlotID(booking.ID, i),
+				Title: slot.Start.In(loc).Format(bookingSlotLayout),
+			})
+		}
+		interactive.Sections = []entity.InteractiveSection{section}
+	}
+
+	payload, err := s.builder.Build(channel.Type, interactive)
+	if err != nil {
+		return err
+	}
+	payload.Metadata[MessageMetaBookingID] = booking.ID
+	payload.Metadata[MessageMetaBookingEvent] = "offer"
+
+	_, err = s.messageService.Send(ctx, &SendMessageInput{
+		TenantID:       conversation.TenantID,
+		ConversationID: conversation.ID,
+		SenderID:       senderID,
+		SenderType:     string(senderType),
+		ContentType:    string(payload.ContentType),
+		Content:        payload.Content,
+		Metadata:       payload.Metadata,
+	})
+	return err
+}
+
+func bookingSlotID(bookingID string, index int) string {
+	return bookingSlotPrefix + bookingID + ":" + strconv.Itoa(index)
+}
+
+// Book books a slot directly, without offering slots to the contact first
+func (s *SchedulingService) Book(ctx context.Context, tenantID string, input *BookInput) (*entity.Booking, error) {
+	cal, err := s.GetCalendar(ctx, tenantID, input.CalendarID)
+	if err != nil {
+		return nil, err
+	}
+	if !cal.IsActive {
+		return nil, errors.Validation("booking calendar is inactive")
+	}
+	conversation, channel, err := s.conversation(ctx, tenantID, input.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	booking := &entity.Booking{
+		ID:             uuid.New().String(),
+		TenantID:       tenantID,
+		CalendarID:     cal.ID,
+		ConversationID: conversation.ID,
+		ContactID:      conversation.ContactID,
+		Title:          strings.TrimSpace(input.Title),
+		Token:          newBookingToken(),
+		CreatedBy:      input.CreatedBy,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	slot, err := s.checkSlot(ctx, cal, input.Start, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.confirm(ctx, cal, booking, conversation, channel, slot, true); err != nil {
+		return nil, err
+	}
+	return booking, nil
+}
+
+// checkSlot returns the free slot starting at start
+func (s *SchedulingService) checkSlot(ctx context.Context, cal *entity.BookingCalendar, start time.Time, ignore *entity.Booking) (entity.TimeSlot, error) {
+	end := start.Add(time.Duration(cal.SlotMinutes) * time.Minute)
+	slots, err := s.freeSlots(ctx, cal, start, end, 1, ignore)
+	if err != nil {
+		return entity.TimeSlot{}, err
+	}
+	if len(slots) == 0 || !slots[0].Start.Equal(start) {
+		return entity.TimeSlot{}, errors.New(errors.ErrCodeConflict, "the requested time is not available")
+	}
+	return slots[0], nil
+}
+
+// confirm books a slot on the external calendar and tells the contact. create
+// is true for bookings not stored yet.
+func (s *SchedulingService) confirm(ctx context.Context, cal *entity.BookingCalendar, booking *entity.Booking, conversation *entity.Conversation, channel *entity.Channel, slot entity.TimeSlot, create bool) error {
+	provider, accessToken, err := s.accessToken(ctx, cal)
+	if err != nil {
+		return err
+	}
+	if provider != nil {
+		eventID, err := provider.CreateEvent(ctx, accessToken, cal.ExternalCalendarID, s.calendarEvent(ctx, cal, booking, slot))
+		if err != nil {
+			return calendarError(err, "failed to create calendar event")
+		}
+		booking.ExternalEventID = eventID
+	}
+
+	now := time.Now()
+	booking.Status = entity.BookingStatusConfirmed
+	booking.StartAt = &slot.Start
+	booking.EndAt = &slot.End
+	booking.OfferedSlots = nil
+	booking.UpdatedAt = now
+	skipPastReminders(booking, cal, now)
+
+	if create {
+		err = s.bookingRepo.Create(ctx, booking)
+	} else {
+		err = s.bookingRepo.Update(ctx, booking)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.notify(ctx, conversation, channel, booking, "confirmed",
+		fmt.Sprintf("Your appointment is confirmed for %s.", formatBookingTime(*booking.StartAt, cal)))
+	s.publish(ctx, EventBookingConfirmed, booking)
+	return nil
+}
+
+// skipPastReminders marks reminders whose time already passed as sent, so a
+// booking made shortly before it starts is not reminded at once
+func skipPastReminders(booking *entity.Booking, cal *entity.BookingCalendar, now time.Time) {
+	booking.RemindersSent = booking.DueReminders(cal.ReminderMinutes, now)
+	booking.ScheduleNextReminder(cal.ReminderMinutes, now)
+}
+
+func (s *SchedulingService) calendarEvent(ctx context.Context, cal *entity.BookingCalendar, booking *entity.Booking, slot entity.TimeSlot) *calendar.Event {
+	event := &calendar.Event{
+		Title:    booking.Title,
+		Start:    slot.Start,
+		End:      slot.End,
+		Timezone: cal.Timezone,
+	}
+	if event.Title == "" {
+		event.Title = cal.EventTitle
+	}
+
+	var contactName string
+	if contact, err := s.contactRepo.FindByID(ctx, booking.ContactID); err == nil {
+		contactName = contact.Name
+		event.AttendeeName = contact.Name
+		event.AttendeeEmail = contact.Email
+	}
+	if event.Title == "" {
+		event.Title = "Appointment"
+		if contactName != "" {
+			event.Title += " with " + contactName
+		}
+	}
+	event.Description = fmt.Sprintf("Booked from a Linktor conversation (%s).", booking.ConversationID)
+	return event
+}
+
+// Reschedule moves a confirmed booking to another free slot
+func (s *SchedulingService) Reschedule(ctx context.Context, tenantID, id string, start time.Time) (*entity.Booking, error) {
+	booking, err := s.GetBooking(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.reschedule(ctx, booking, start); err != nil {
+		return nil, err
+	}
+	return booking, nil
+}
+
+func (s *SchedulingService) reschedule(ctx context.Context, booking *entity.Booking, start time.Time) error {
+	if booking.Status != entity.BookingStatusConfirmed {
+		return errors.New(errors.ErrCodeConflict, "only confirmed bookings can be rescheduled")
+	}
+	cal, err := s.calendarRepo.FindByID(ctx, booking.CalendarID)
+	if err != nil {
+		return err
+	}
+	slot, err := s.checkSlot(ctx, cal, start, booking)
+	if err != nil {
+		return err
+	}
+
+	provider, accessToken, err := s.accessToken(ctx, cal)
+	if err != nil {
+		return err
+	}
+	if provider != nil && booking.ExternalEventID != "" {
+		if err := provider.UpdateEvent(ctx, accessToken, cal.ExternalCalendarID, booking.ExternalEventID, s.calendarEvent(ctx, cal, booking, slot)); err != nil {
+			return calendarError(err, "failed to move calendar event")
+		}
+	}
+
+	now := time.Now()
+	booking.StartAt = &slot.Start
+	booking.EndAt = &slot.End
+	booking.OfferedSlots = nil
+	booking.RemindersSent = nil
+	booking.UpdatedAt = now
+	skipPastReminders(booking, cal, now)
+	if err := s.bookingRepo.Update(ctx, booking); err != nil {
+		return err
+	}
+
+	if conversation, channel, err := s.conversation(ctx, booking.TenantID, booking.ConversationID); err == nil {
+		s.notify(ctx, conversation, channel, booking, "rescheduled",
+			fmt.Sprintf("Your appointment was moved to %s.", formatBookingTime(*booking.StartAt, cal)))
+	}
+	s.publish(ctx, EventBookingRescheduled, booking)
+	return nil
+}
+
+// Cancel cancels a booking. Confirmed bookings are removed from the external
+// calendar and the contact is told.
+func (s *SchedulingService) Cancel(ctx context.Context, tenantID, id, reason string) (*entity.Booking, error) {
+	booking, err := s.GetBooking(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cancel(ctx, booking, reason); err != nil {
+		return nil, err
+	}
+	return booking, nil
+}
+
+func (s *SchedulingService) cancel(ctx context.Context, booking *entity.Booking, reason string) error {
+	if booking.Status == entity.BookingStatusCancelled {
+		return errors.New(errors.ErrCodeConflict, "booking is already cancelled")
+	}
+	if booking.Status == entity.BookingStatusPending {
+		s.closeOffer(ctx, booking, reason)
+		return nil
+	}
+
+	cal, err := s.calendarRepo.FindByID(ctx, booking.CalendarID)
+	if err != nil {
+		return err
+	}
+	if booking.ExternalEventID != "" {
+		// The booking is cancelled even when the calendar is unreachable
+		provider, accessToken, err := s.accessToken(ctx, cal)
+		if err == nil && provider != nil {
+			err = provider.DeleteEvent(ctx, accessToken, cal.ExternalCalendarID, booking.ExternalEventID)
+		}
+		if err != nil {
+			logger.Warn("Failed to delete calendar event of cancelled booking",
+				zap.String("booking_id", booking.ID),
+				zap.Error(err),
+			)
+		}
+	}
+
+	now := time.Now()
+	booking.Status = entity.BookingStatusCancelled
+	booking.CancelReason = reason
+	booking.CancelledAt = &now
+	booking.NextReminderAt = nil
+	booking.OfferedSlots = nil
+	booking.UpdatedAt = now
+	if err := s.bookingRepo.Update(ctx, booking); err != nil {
+		return err
+	}
+
+	if conversation, channel, err := s.conversation(ctx, booking.TenantID, booking.ConversationID); err == nil {
+		s.notify(ctx, conversation, channel, booking, "cancelled",
+			fmt.Sprintf("Your appointment on %s was cancelled.", formatBookingTime(*booking.StartAt, cal)))
+	}
+	s.publish(ctx, EventBookingCancelled, booking)
+	return nil
+}
+
+// closeOffer cancels an offer the contact did not answer
+func (s *SchedulingService) closeOffer(ctx context.Context, booking *entity.Booking, reason string) {
+	now := time.Now()
+	booking.Status = entity.BookingStatusCancelled
+	booking.CancelReason = reason
+	booking.CancelledAt = &now
+	booking.UpdatedAt = now
+	if err := s.bookingRepo.Update(ctx, booking); err != nil {
+		logger.Warn("Failed to close booking offer",
+			zap.String("booking_id", booking.ID),
+			zap.Error(err),
+		)
+	}
+}
+
+// GetBooking returns a booking of a tenant
+func (s *SchedulingService) GetBooking(ctx context.Context, tenantID, id string) (*entity.Booking, error) {
+	booking, err := s.bookingRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if booking.TenantID != tenantID {
+		return nil, errors.NotFound("booking")
+	}
+	return booking, nil
+}
+
+// ListBookings lists the bookings of a tenant
+func (s *SchedulingService) ListBookings(ctx context.Context, tenantID string, filter *entity.BookingFilter, params *repository.ListParams) ([]*entity.Booking, int64, error) {
+	return s.bookingRepo.ListByTenant(ctx, tenantID, filter, params)
+}
+
+// GetPublicBooking returns the booking of a reschedule or cancel link with the
+// slots it can be moved to
+func (s *SchedulingService) GetPublicBooking(ctx context.Context, token string) (*PublicBooking, error) {
+	booking, cal, err := s.bookingByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &PublicBooking{Booking: booking, CalendarName: cal.Name, Timezone: cal.Timezone}
+	if booking.Status == entity.BookingStatusConfirmed {
+		slots, err := s.freeSlots(ctx, cal, time.Time{}, time.Time{}, 20, booking)
+		if err != nil {
+			return nil, err
+		}
+		view.AvailableSlots = slots
+	}
+	return view, nil
+}
+
+// RescheduleByToken moves the booking of a reschedule link
+func (s *SchedulingService) RescheduleByToken(ctx context.Context, token string, start time.Time) (*entity.Booking, error) {
+	booking, _, err := s.bookingByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.reschedule(ctx, booking, start); err != nil {
+		return nil, err
+	}
+	return booking, nil
+}
+
+// CancelByToken cancels the booking of a cancel link
+func (s *SchedulingService) CancelByToken(ctx context.Context, token, reason string) (*entity.Booking, error) {
+	booking, _, err := s.bookingByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if reason == "" {
+		reason = "cancelled by contact"
+	}
+	if err := s.cancel(ctx, booking, reason); err != nil {
+		return nil, err
+	}
+	return booking, nil
+}
+
+func (s *SchedulingService) bookingByToken(ctx context.Context, token string) (*entity.Booking, *entity.BookingCalendar, error) {
+	if token == "" {
+		return nil, nil, errors.NotFound("booking")
+	}
+	booking, err := s.bookingRepo.FindByToken(ctx, token)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Links are only sent for confirmed bookings
+	if booking.StartAt == nil {
+		return nil, nil, errors.NotFound("booking")
+	}
+	cal, err := s.calendarRepo.FindByID(ctx, booking.CalendarID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return booking, cal, nil
+}
+
+// RunDueReminders sends the reminders that are due and returns how many were sent
+func (s *SchedulingService) RunDueReminders(ctx context.Context) (int, error) {
+	now := time.Now()
+	bookings, err := s.bookingRepo.ListDueReminders(ctx, now, 100)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, booking := range bookings {
+		cal, err := s.calendarRepo.FindByID(ctx, booking.CalendarID)
+		if err != nil {
+			logger.Warn("Failed to load calendar of booking reminder",
+				zap.String("booking_id", booking.ID),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		due := booking.DueReminders(cal.ReminderMinutes, now)
+		if len(due) > 0 && booking.StartAt.After(now) {
+			conversation, channel, err := s.conversation(ctx, booking.TenantID, booking.ConversationID)
+			if err == nil {
+				s.notify(ctx, conversation, channel, booking, "reminder",
+					fmt.Sprintf("Reminder: your appointment is on %s.", formatBookingTime(*booking.StartAt, cal)))
+				sent++
+			}
+		}
+
+		booking.RemindersSent = append(booking.RemindersSent, due...)
+		booking.ScheduleNextReminder(cal.ReminderMinutes, now)
+		booking.UpdatedAt = now
+		if err := s.bookingRepo.Update(ctx, booking); err != nil {
+			logger.Warn("Failed to update booking reminders",
+				zap.String("booking_id", booking.ID),
+				zap.Error(err),
+			)
+		}
+	}
+	return sent, nil
+}
+
+// HandleInbound handles the replies to booking messages: a picked slot is
+// booked, and the reschedule and cancel buttons of a booking act on it.
+// Numbered replies pick a slot on channels without buttons.
+func (s *SchedulingService) HandleInbound(ctx context.Context, message *entity.Message, conversation *entity.Conversation) error {
+	if message.SenderType != entity.SenderTypeContact {
+		return nil
+	}
+
+	replyID := bookingReplyID(message.Metadata)
+	var err error
+	switch {
+	case strings.HasPrefix(replyID, bookingCancelPrefix):
+		err = s.handleCancelReply(ctx, conversation, strings.TrimPrefix(replyID, bookingCancelPrefix))
+	case strings.HasPrefix(replyID, bookingReschedulePrefix):
+		err = s.handleRescheduleReply(ctx, conversation, strings.TrimPrefix(replyID, bookingReschedulePrefix))
+	case strings.HasPrefix(replyID, bookingSlotPrefix):
+		parts := strings.Split(strings.TrimPrefix(replyID, bookingSlotPrefix), ":")
+		if len(parts) != 2 {
+			return nil
+		}
+		index, convErr := strconv.Atoi(parts[1])
+		if convErr != nil {
+			return nil
+		}
+		err = s.handleSlotReply(ctx, conversation, parts[0], index)
+	case message.ContentType == entity.ContentTypeText:
+		err = s.handleNumberedReply(ctx, conversation, strings.TrimSpace(message.Content))
+	}
+
+	if err != nil {
+		logger.Warn("Failed to handle booking reply",
+			zap.String("conversation_id", conversation.ID),
+			zap.Error(err),
+		)
+	}
+	return err
+}
+
+func bookingReplyID(metadata map[string]string) string {
+	for _, key := range postbackMetadataKeys {
+		if value := metadata[key]; strings.HasPrefix(value, bookingSlotPrefix) ||
+			strings.HasPrefix(value, bookingCancelPrefix) || strings.HasPrefix(value, bookingReschedulePrefix) {
+			return value
+		}
+	}
+	return ""
+}
+
+// conversationBooking returns a booking of the conversation, ignoring IDs from other conversations
+func (s *SchedulingService) conversationBooking(ctx context.Context, conversation *entity.Conversation, id string) (*entity.Booking, error) {
+	booking, err := s.bookingRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if booking.ConversationID != conversation.ID {
+		return nil, errors.NotFound("booking")
+	}
+	return booking, nil
+}
+
+func (s *SchedulingService) handleNumberedReply(ctx context.Context, conversation *entity.Conversation, text string) error {
+	index, err := strconv.Atoi(strings.TrimRight(text, ".)"))
+	if err != nil {
+		return nil
+	}
+	booking, err := s.bookingRepo.FindPendingByConversation(ctx, conversation.ID)
+	if err != nil || time.Since(booking.CreatedAt) > bookingOfferReplyWindow {
+		return nil
+	}
+	if index < 1 || index > len(booking.OfferedSlots) {
+		return nil
+	}
+	return s.pickSlot(ctx, conversation, booking, index-1)
+}
+
+func (s *SchedulingService) handleSlotReply(ctx context.Context, conversation *entity.Conversation, bookingID string, index int) error {
+	booking, err := s.conversationBooking(ctx, conversation, bookingID)
+	if err != nil {
+		return nil
+	}
+	if index < 0 || index >= len(booking.OfferedSlots) {
+		return nil
+	}
+	return s.pickSlot(ctx, conversation, booking, index)
+}
+
+// pickSlot books an offered slot, or moves a confirmed booking to it
+func (s *SchedulingService) pickSlot(ctx context.Context, conversation *entity.Conversation, booking *entity.Booking, index int) error {
+	slot := booking.OfferedSlots[index]
+
+	switch booking.Status {
+	case entity.BookingStatusPending:
+		cal, err := s.calendarRepo.FindByID(ctx, booking.CalendarID)
+		if err != nil {
+			return err
+		}
+		channel, err := s.channelRepo.FindByID(ctx, conversation.ChannelID)
+		if err != nil {
+			return err
+		}
+		if _, err := s.checkSlot(ctx, cal, slot.Start, nil); err != nil {
+			if isConflict(err) {
+				return s.reofferAfterConflict(ctx, conversation, channel, cal, booking)
+			}
+			return err
+		}
+		return s.confirm(ctx, cal, booking, conversation, channel, slot, false)
+	case entity.BookingStatusConfirmed:
+		err := s.reschedule(ctx, booking, slot.Start)
+		if isConflict(err) {
+			s.reply(ctx, conversation, booking, "That time is no longer available. Please pick another one.")
+			return nil
+		}
+		return err
+	default:
+		return nil
+	}
+}
+
+// reofferAfterConflict offers fresh slots when the picked one was taken meanwhile
+func (s *SchedulingService) reofferAfterConflict(ctx context.Context, conversation *entity.Conversation, channel *entity.Channel, cal *entity.BookingCalendar, booking *entity.Booking) error {
+	slots, err := s.freeSlots(ctx, cal, time.Time{}, time.Time{}, len(booking.OfferedSlots), nil)
+	if err != nil {
+		return err
+	}
+	if len(slots) == 0 {
+		s.closeOffer(ctx, booking, "no free slots left")
+		s.reply(ctx, conversation, booking, "Sorry, there are no free times left. An agent will get back to you.")
+		return nil
+	}
+
+	booking.OfferedSlots = slots
+	booking.UpdatedAt = time.Now()
+	if err := s.bookingRepo.Update(ctx, booking); err != nil {
+		return err
+	}
+	return s.sendOffer(ctx, conversation, channel, cal, booking,
+		"That time is no longer available. Please choose another one:", entity.SenderTypeSystem, "")
+}
+
+func (s *SchedulingService) handleCancelReply(ctx context.Context, conversation *entity.Conversation, bookingID string) error {
+	booking, err := s.conversationBooking(ctx, conversation, bookingID)
+	if err != nil || booking.Status != entity.BookingStatusConfirmed {
+		return nil
+	}
+	return s.cancel(ctx, booking, "cancelled by contact")
+}
+
+// handleRescheduleReply offers the free slots a confirmed booking can move to
+func (s *SchedulingService) handleRescheduleReply(ctx context.Context, conversation *entity.Conversation, bookingID string) error {
+	booking, err := s.conversationBooking(ctx, conversation, bookingID)
+	if err != nil || booking.Status != entity.BookingStatusConfirmed {
+		return nil
+	}
+	cal, err := s.calendarRepo.FindByID(ctx, booking.CalendarID)
+	if err != nil {
+		return err
+	}
+	channel, err := s.channelRepo.FindByID(ctx, conversation.ChannelID)
+	if err != nil {
+		return err
+	}
+
+	slots, err := s.freeSlots(ctx, cal, time.Time{}, time.Time{}, offerLimit(0, channel.Type), booking)
+	if err != nil {
+		return err
+	}
+	if len(slots) == 0 {
+		s.reply(ctx, conversation, booking, "Sorry, there are no other free times. An agent will get back to you.")
+		return nil
+	}
+
+	booking.OfferedSlots = slots
+	booking.UpdatedAt = time.Now()
+	if err := s.bookingRepo.Update(ctx, booking); err != nil {
+		return err
+	}
+	return s.sendOffer(ctx, conversation, channel, cal, booking, "Choose a new time for your appointment:", entity.SenderTypeSystem, "")
+}
+
+// notify tells the contact about a booking. Channels with reply buttons get
+// reschedule and cancel buttons; other channels get the links. Failures are
+// only logged.
+func (s *SchedulingService) notify(ctx context.Context, conversation *entity.Conversation, channel *entity.Channel, booking *entity.Booking, event, text string) {
+	input := &SendMessageInput{
+		TenantID:       conversation.TenantID,
+		ConversationID: conversation.ID,
+		SenderType:     string(entity.SenderTypeSystem),
+		ContentType:    string(entity.ContentTypeText),
+		Content:        text,
+		Metadata: map[string]string{
+			MessageMetaBookingID:    booking.ID,
+			MessageMetaBookingEvent: event,
+		},
+	}
+
+	if booking.Status == entity.BookingStatusConfirmed {
+		constraints, native := entity.InteractiveConstraintsFor(channel.Type)
+		if native && constraints.SupportsButtons && constraints.MaxButtons >= 2 {
+			payload, err := s.builder.Build(channel.Type, &entity.InteractiveMessage{
+				Type: entity.InteractiveTypeButton,
+				Body: text,
+				Buttons: []entity.InteractiveButton{
+					{ID: bookingReschedulePrefix + booking.ID, Title: "Reschedule"},
+					{ID: bookingCancelPrefix + booking.ID, Title: "Cancel"},
+				},
+			})
+			if err == nil {
+				input.ContentType = string(payload.ContentType)
+				input.Content = payload.Content
+				for k, v := range payload.Metadata {
+					input.Metadata[k] = v
+				}
+			}
+		} else if s.linkBaseURL != "" {
+			input.Content = fmt.Sprintf("%s\n\nReschedule: %s\nCancel: %s", text,
+				s.bookingLink(booking, "reschedule"), s.bookingLink(booking, "cancel"))
+		}
+	}
+
+	if _, err := s.messageService.Send(ctx, input); err != nil {
+		logger.Warn("Failed to send booking message",
+			zap.String("booking_id", booking.ID),
+			zap.String("event", event),
+			zap.Error(err),
+		)
+	}
+}
+
+// reply sends a plain booking message; failures are only logged
+func (s *SchedulingService) reply(ctx context.Context, conversation *entity.Conversation, booking *entity.Booking, text string) {
+	_, err := s.messageService.Send(ctx, &SendMessageInput{
+		TenantID:       conversation.TenantID,
+		ConversationID: conversation.ID,
+		SenderType:     string(entity.SenderTypeSystem),
+		ContentType:    string(entity.ContentTypeText),
+		Content:        text,
+		Metadata:       map[string]string{MessageMetaBookingID: booking.ID},
+	})
+	if err != nil {
+		logger.Warn("Failed to send booking message",
+			zap.String("booking_id", booking.ID),
+			zap.Error(err),
+		)
+	}
+}
+
+func (s *SchedulingService) bookingLink(booking *entity.Booking, action string) string {
+	return s.linkBaseURL + "/" + booking.Token + "?action=" + action
+}
+
+func (s *SchedulingService) conversation(ctx context.Context, tenantID, id string) (*entity.Conversation, *entity.Channel, error) {
+	conversation, err := s.conversationRepo.FindByID(ctx, id)
+	if err != nil || conversation.TenantID != tenantID {
+		return nil, nil, errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+	}
+	channel, err := s.channelRepo.FindByID(ctx, conversation.ChannelID)
+	if err != nil {
+		return nil, nil, errors.New(errors.ErrCodeChannelNotFound, "channel not found")
+	}
+	return conversation, channel, nil
+}
+
+func (s *SchedulingService) publish(ctx context.Context, eventType string, booking *entity.Booking) {
+	if s.producer == nil {
+		return
+	}
+	payload := map[string]interface{}{
+		"booking_id":      booking.ID,
+		"calendar_id":     booking.CalendarID,
+		"conversation_id": booking.ConversationID,
+		"contact_id":      booking.ContactID,
+		"status":          string(booking.Status),
+	}
+	if booking.StartAt != nil {
+		payload["start_at"] = booking.StartAt.Format(time.RFC3339)
+	}
+	s.producer.PublishEvent(ctx, &nats.Event{
+		Type:      eventType,
+		TenantID:  booking.TenantID,
+		Payload:   payload,
+		Timestamp: time.Now(),
+	})
+}
+
+func isConflict(err error) bool {
+	appErr := errors.GetAppError(err)
+	return appErr != nil && appErr.Code == errors.ErrCodeConflict
+}
+
+func calendarLocation(cal *entity.BookingCalendar) *time.Location {
+	loc, err := time.LoadLocation(cal.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+func formatBookingTime(t time.Time, cal *entity.BookingCalendar) string {
+	local := t.In(calendarLocation(cal))
+	return local.Format("Monday, 02 January 2006 at 15:04") + " (" + cal.Timezone + ")"
+}
+
+func newBookingToken() string {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return strings.ReplaceAll(uuid.New().String(), "-", "")
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,472 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/calendar"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockBookingCalendarRepo struct {
+	calendars map[string]*entity.BookingCalendar
+}
+
+func (m *mockBookingCalendarRepo) Create(ctx context.Context, cal *entity.BookingCalendar) error {
+	m.calendars[cal.ID] = cal
+	return nil
+}
+
+func (m *mockBookingCalendarRepo) FindByID(ctx context.Context, id string) (*entity.BookingCalendar, error) {
+	if cal, ok := m.calendars[id]; ok {
+		return cal, nil
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "booking calendar not found")
+}
+
+func (m *mockBookingCalendarRepo) ListByTenant(ctx context.Context, tenantID string) ([]*entity.BookingCalendar, error) {
+	var result []*entity.BookingCalendar
+	for _, cal := range m.calendars {
+		if cal.TenantID == tenantID {
+			result = append(result, cal)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockBookingCalendarRepo) Update(ctx context.Context, cal *entity.BookingCalendar) error {
+	m.calendars[cal.ID] = cal
+	return nil
+}
+
+func (m *mockBookingCalendarRepo) Delete(ctx context.Context, id string) error {
+	delete(m.calendars, id)
+	return nil
+}
+
+type mockBookingRepo struct {
+	bookings map[string]*entity.Booking
+}
+
+func (m *mockBookingRepo) Create(ctx context.Context, booking *entity.Booking) error {
+	m.bookings[booking.ID] = booking
+	return nil
+}
+
+func (m *mockBookingRepo) FindByID(ctx context.Context, id string) (*entity.Booking, error) {
+	if booking, ok := m.bookings[id]; ok {
+		return booking, nil
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "booking not found")
+}
+
+func (m *mockBookingRepo) FindByToken(ctx context.Context, token string) (*entity.Booking, error) {
+	for _, booking := range m.bookings {
+		if booking.Token == token {
+			return booking, nil
+		}
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "booking not found")
+}
+
+func (m *mockBookingRepo) FindPendingByConversation(ctx context.Context, conversationID string) (*entity.Booking, error) {
+	for _, booking := range m.bookings {
+		if booking.ConversationID == conversationID && booking.Status == entity.BookingStatusPending {
+			return booking, nil
+		}
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "booking not found")
+}
+
+func (m *mockBookingRepo) ListByTenant(ctx context.Context, tenantID string, filter *entity.BookingFilter, params *repository.ListParams) ([]*entity.Booking, int64, error) {
+	var result []*entity.Booking
+	for _, booking := range m.bookings {
+		if booking.TenantID == tenantID {
+			result = append(result, booking)
+		}
+	}
+	return result, int64(len(result)), nil
+}
+
+func (m *mockBookingRepo) ListConfirmedBetween(ctx context.Context, calendarID string, from, to time.Time) ([]*entity.Booking, error) {
+	var result []*entity.Booking
+	for _, booking := range m.bookings {
+		if booking.CalendarID == calendarID && booking.Status == entity.BookingStatusConfirmed &&
+			booking.StartAt.Before(to) && booking.EndAt.After(from) {
+			result = append(result, booking)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockBookingRepo) ListDueReminders(ctx context.Context, before time.Time, limit int) ([]*entity.Booking, error) {
+	var result []*entity.Booking
+	for _, booking := range m.bookings {
+		if booking.Status == entity.BookingStatusConfirmed && booking.NextReminderAt != nil && !booking.NextReminderAt.After(before) {
+			result = append(result, booking)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockBookingRepo) Update(ctx context.Context, booking *entity.Booking) error {
+	m.bookings[booking.ID] = booking
+	return nil
+}
+
+type fakeCalendarProvider struct {
+	busy    []calendar.Interval
+	events  map[string]*calendar.Event
+	deleted []string
+	nextID  int
+}
+
+func (p *fakeCalendarProvider) Name() string { return calendar.ProviderGoogle }
+
+func (p *fakeCalendarProvider) RefreshToken(ctx context.Context, refreshToken string) (*calendar.Token, error) {
+	if refreshToken == "revoked" {
+		return nil, calendar.ErrUnauthorized
+	}
+	return &calendar.Token{AccessToken: "access", ExpiresAt: time.Now().Add(time.Hour)}, nil
+}
+
+func (p *fakeCalendarProvider) FreeBusy(ctx context.Context, accessToken, calendarID string, from, to time.Time) ([]calendar.Interval, error) {
+	return p.busy, nil
+}
+
+func (p *fakeCalendarProvider) CreateEvent(ctx context.Context, accessToken, calendarID string, event *calendar.Event) (string, error) {
+	p.nextID++
+	id := "evt-" + string(rune('0'+p.nextID))
+	p.events[id] = event
+	return id, nil
+}
+
+func (p *fakeCalendarProvider) UpdateEvent(ctx context.Context, accessToken, calendarID, eventID string, event *calendar.Event) error {
+	p.events[eventID] = event
+	return nil
+}
+
+func (p *fakeCalendarProvider) DeleteEvent(ctx context.Context, accessToken, calendarID, eventID string) error {
+	p.deleted = append(p.deleted, eventID)
+	delete(p.events, eventID)
+	return nil
+}
+
+type schedulingFixture struct {
+	service  *SchedulingService
+	bookings *mockBookingRepo
+	messages *testutil.MockMessageRepository
+	producer *testutil.MockProducer
+	provider *fakeCalendarProvider
+	calendar *entity.BookingCalendar
+}
+
+func newSchedulingFixture(t *testing.T) *schedulingFixture {
+	calendars := &mockBookingCalendarRepo{calendars: make(map[string]*entity.BookingCalendar)}
+	bookings := &mockBookingRepo{bookings: make(map[string]*entity.Booking)}
+	contacts := testutil.NewMockContactRepository()
+	conversations := testutil.NewMockConversationRepository()
+	messages := testutil.NewMockMessageRepository()
+	channels := testutil.NewMockChannelRepository()
+	producer := testutil.NewMockProducer()
+
+	channels.Channels["ch-tg"] = &entity.Channel{ID: "ch-tg", TenantID: "tenant-1", Type: entity.ChannelTypeTelegram}
+	channels.Channels["ch-sms"] = &entity.Channel{ID: "ch-sms", TenantID: "tenant-1", Type: entity.ChannelTypeSMS}
+	contacts.Contacts["alice"] = &entity.Contact{ID: "alice", TenantID: "tenant-1", Name: "Alice", Email: "alice@example.com"}
+	conversations.Conversations["conv-tg"] = &entity.Conversation{ID: "conv-tg", TenantID: "tenant-1", ChannelID: "ch-tg", ContactID: "alice"}
+	conversations.Conversations["conv-sms"] = &entity.Conversation{ID: "conv-sms", TenantID: "tenant-1", ChannelID: "ch-sms", ContactID: "alice"}
+
+	messageService := NewMessageService(messages, conversations, channels, contacts, producer)
+	svc := NewSchedulingService(calendars, bookings, conversations, contacts, channels, messageService, producer, "https://book.example.com/b/")
+	provider := &fakeCalendarProvider{events: make(map[string]*calendar.Event)}
+	svc.SetCalendarProvider(provider)
+
+	// Open every day from 09:00 to 12:00 UTC
+	var availability []entity.DaySchedule
+	for day := 0; day < 7; day++ {
+		availability = append(availability, entity.DaySchedule{Day: day, StartTime: "09:00", EndTime: "12:00"})
+	}
+	cal, err := svc.CreateCalendar(context.Background(), "tenant-1", &BookingCalendarInput{
+		Name:            "Consultations",
+		Provider:        "google",
+		SlotMinutes:     60,
+		MaxDaysAhead:    7,
+		Availability:    availability,
+		ReminderMinutes: []int{60, 1440},
+		RefreshToken:    "refresh",
+	})
+	require.NoError(t, err)
+
+	return &schedulingFixture{
+		service:  svc,
+		bookings: bookings,
+		messages: messages,
+		producer: producer,
+		provider: provider,
+		calendar: cal,
+	}
+}
+
+func (f *schedulingFixture) bookingMessages(event string) []*entity.Message {
+	var result []*entity.Message
+	for _, message := range f.messages.Messages {
+		if message.Metadata[MessageMetaBookingEvent] == event {
+			result = append(result, message)
+		}
+	}
+	return result
+}
+
+func tomorrowAt(hour int) time.Time {
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	return time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), hour, 0, 0, 0, time.UTC)
+}
+
+func TestSchedulingService_CalendarValidation(t *testing.T) {
+	f := newSchedulingFixture(t)
+	ctx := context.Background()
+
+	assert.True(t, f.calendar.Connected)
+	assert.Equal(t, []int{1440, 60}, f.calendar.ReminderMinutes)
+	assert.Equal(t, "UTC", f.calendar.Timezone)
+
+	_, err := f.service.CreateCalendar(ctx, "tenant-1", &BookingCalendarInput{
+		Name:         "Broken",
+		Provider:     "outlook",
+		Timezone:     "Mars/Olympus",
+		Availability: []entity.DaySchedule{{Day: 1, StartTime: "18:00", EndTime: "09:00"}},
+	})
+	require.Error(t, err)
+	details := errors.GetAppError(err).Details
+	assert.Contains(t, details, "provider")
+	assert.Contains(t, details, "timezone")
+	assert.Contains(t, details, "availability[0]")
+
+	_, err = f.service.GetCalendar(ctx, "tenant-2", f.calendar.ID)
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestSchedulingService_AvailableSlotsSkipBusyTime(t *testing.T) {
+	f := newSchedulingFixture(t)
+	ctx := context.Background()
+
+	f.provider.busy = []calendar.Interval{{Start: tomorrowAt(10), End: tomorrowAt(11)}}
+	f.calendar.MinNoticeMinutes = 0
+
+	slots, err := f.service.AvailableSlots(ctx, "tenant-1", f.calendar.ID, 50)
+	require.NoError(t, err)
+	require.NotEmpty(t, slots)
+
+	starts := map[time.Time]bool{}
+	for i, slot := range slots {
+		assert.True(t, slot.Start.After(time.Now()))
+		assert.Equal(t, time.Hour, slot.End.Sub(slot.Start))
+		if i > 0 {
+			assert.True(t, slot.Start.After(slots[i-1].Start))
+		}
+		starts[slot.Start.UTC()] = true
+	}
+	assert.True(t, starts[tomorrowAt(9)])
+	assert.False(t, starts[tomorrowAt(10)], "busy slot must not be offered")
+	assert.True(t, starts[tomorrowAt(11)])
+
+	// A buffer keeps the neighbouring slots free as well
+	f.calendar.BufferMinutes = 15
+	slots, err = f.service.AvailableSlots(ctx, "tenant-1", f.calendar.ID, 50)
+	require.NoError(t, err)
+	for _, slot := range slots {
+		assert.False(t, slot.Start.UTC().Equal(tomorrowAt(9)) || slot.Start.UTC().Equal(tomorrowAt(11)))
+	}
+}
+
+func TestSchedulingService_OfferAndPickSlot(t *testing.T) {
+	f := newSchedulingFixture(t)
+	ctx := context.Background()
+
+	booking, err := f.service.OfferSlots(ctx, "tenant-1", &OfferSlotsInput{
+		CalendarID:     f.calendar.ID,
+		ConversationID: "conv-tg",
+		Title:          "Consultation",
+		Limit:          3,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, entity.BookingStatusPending, booking.Status)
+	require.Len(t, booking.OfferedSlots, 3)
+
+	offers := f.bookingMessages("offer")
+	require.Len(t, offers, 1)
+	assert.Contains(t, offers[0].Metadata["quick_replies"], bookingSlotID(booking.ID, 1))
+
+	// Tapping the second slot books it on the external calendar
+	reply := &entity.Message{
+		SenderType:  entity.SenderTypeContact,
+		ContentType: entity.ContentTypeText,
+		Content:     "Slot 2",
+		Metadata:    map[string]string{"postback_data": bookingSlotID(booking.ID, 1)},
+	}
+	conversation := &entity.Conversation{ID: "conv-tg", TenantID: "tenant-1", ChannelID: "ch-tg", ContactID: "alice"}
+	require.NoError(t, f.service.HandleInbound(ctx, reply, conversation))
+
+	confirmed := f.bookings.bookings[booking.ID]
+	assert.Equal(t, entity.BookingStatusConfirmed, confirmed.Status)
+	require.NotNil(t, confirmed.StartAt)
+	assert.Empty(t, confirmed.OfferedSlots)
+	require.Contains(t, f.provider.events, confirmed.ExternalEventID)
+	event := f.provider.events[confirmed.ExternalEventID]
+	assert.Equal(t, "Consultation", event.Title)
+	assert.Equal(t, "alice@example.com", event.AttendeeEmail)
+	assert.NotNil(t, confirmed.NextReminderAt)
+
+	confirmations := f.bookingMessages("confirmed")
+	require.Len(t, confirmations, 1)
+	assert.Contains(t, confirmations[0].Metadata["quick_replies"], bookingCancelPrefix+booking.ID)
+	require.Len(t, f.producer.Events, 1)
+	assert.Equal(t, EventBookingConfirmed, f.producer.Events[0].Type)
+
+	// The cancel button removes the event
+	cancel := &entity.Message{
+		SenderType:  entity.SenderTypeContact,
+		ContentType: entity.ContentTypeText,
+		Content:     "Cancel",
+		Metadata:    map[string]string{"postback_data": bookingCancelPrefix + booking.ID},
+	}
+	require.NoError(t, f.service.HandleInbound(ctx, cancel, conversation))
+	assert.Equal(t, entity.BookingStatusCancelled, f.bookings.bookings[booking.ID].Status)
+	assert.Equal(t, []string{confirmed.ExternalEventID}, f.provider.deleted)
+	assert.Len(t, f.bookingMessages("cancelled"), 1)
+}
+
+func TestSchedulingService_NumberedReplyAndLinks(t *testing.T) {
+	f := newSchedulingFixture(t)
+	ctx := context.Background()
+
+	booking, err := f.service.OfferSlots(ctx, "tenant-1", &OfferSlotsInput{CalendarID: f.calendar.ID, ConversationID: "conv-sms"})
+	require.NoError(t, err)
+	require.Len(t, booking.OfferedSlots, DefaultOfferedSlots)
+
+	offer := f.bookingMessages("offer")[0]
+	assert.Equal(t, entity.ContentTypeText, offer.ContentType)
+	assert.Contains(t, offer.Content, "2. "+booking.OfferedSlots[1].Start.Format(bookingSlotLayout))
+	picked := booking.OfferedSlots[1]
+
+	conversation := &entity.Conversation{ID: "conv-sms", TenantID: "tenant-1", ChannelID: "ch-sms", ContactID: "alice"}
+	reply := &entity.Message{SenderType: entity.SenderTypeContact, ContentType: entity.ContentTypeText, Content: "2"}
+	require.NoError(t, f.service.HandleInbound(ctx, reply, conversation))
+
+	confirmed := f.bookings.bookings[booking.ID]
+	require.Equal(t, entity.BookingStatusConfirmed, confirmed.Status)
+	assert.True(t, picked.Start.Equal(*confirmed.StartAt))
+
+	// SMS confirmations carry the reschedule and cancel links
+	confirmation := f.bookingMessages("confirmed")[0]
+	assert.Contains(t, confirmation.Content, "https://book.example.com/b/"+confirmed.Token+"?action=reschedule")
+	assert.Contains(t, confirmation.Content, "?action=cancel")
+
+	// The link moves the booking to another free slot
+	view, err := f.service.GetPublicBooking(ctx, confirmed.Token)
+	require.NoError(t, err)
+	require.NotEmpty(t, view.AvailableSlots)
+	target := view.AvailableSlots[len(view.AvailableSlots)-1].Start
+
+	moved, err := f.service.RescheduleByToken(ctx, confirmed.Token, target)
+	require.NoError(t, err)
+	assert.True(t, target.Equal(*moved.StartAt))
+	assert.Equal(t, target, f.provider.events[moved.ExternalEventID].Start)
+	assert.Len(t, f.bookingMessages("rescheduled"), 1)
+
+	// Times outside the availability cannot be booked
+	_, err = f.service.RescheduleByToken(ctx, confirmed.Token, tomorrowAt(15))
+	assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code)
+
+	_, err = f.service.CancelByToken(ctx, confirmed.Token, "")
+	require.NoError(t, err)
+	_, err = f.service.CancelByToken(ctx, confirmed.Token, "")
+	assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code)
+
+	_, err = f.service.GetPublicBooking(ctx, "unknown")
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestSchedulingService_BookRejectsTakenSlot(t *testing.T) {
+	f := newSchedulingFixture(t)
+	ctx := context.Background()
+
+	booking, err := f.service.Book(ctx, "tenant-1", &BookInput{CalendarID: f.calendar.ID, ConversationID: "conv-tg", Start: tomorrowAt(9)})
+	require.NoError(t, err)
+	assert.Equal(t, entity.BookingStatusConfirmed, booking.Status)
+	assert.Equal(t, "Appointment with Alice", f.provider.events[booking.ExternalEventID].Title)
+
+	// The slot is taken by the first booking
+	_, err = f.service.Book(ctx, "tenant-1", &BookInput{CalendarID: f.calendar.ID, ConversationID: "conv-sms", Start: tomorrowAt(9)})
+	assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code)
+
+	_, err = f.service.Book(ctx, "tenant-2", &BookInput{CalendarID: f.calendar.ID, ConversationID: "conv-tg", Start: tomorrowAt(10)})
+	assert.True(t, errors.IsNotFound(err))
+
+	// Rejected credentials surface as a request error
+	f.calendar.Credentials = &entity.CalendarCredentials{RefreshToken: "revoked"}
+	_, err = f.service.Book(ctx, "tenant-1", &BookInput{CalendarID: f.calendar.ID, ConversationID: "conv-tg", Start: tomorrowAt(11)})
+	assert.Equal(t, errors.ErrCodeBadRequest, errors.GetAppError(err).Code)
+}
+
+func TestSchedulingService_RunDueReminders(t *testing.T) {
+	f := newSchedulingFixture(t)
+	ctx := context.Background()
+
+	start := time.Now().Add(30 * time.Minute)
+	end := start.Add(time.Hour)
+	past := time.Now().Add(-time.Minute)
+	f.bookings.bookings["b-1"] = &entity.Booking{
+		ID:             "b-1",
+		TenantID:       "tenant-1",
+		CalendarID:     f.calendar.ID,
+		ConversationID: "conv-sms",
+		ContactID:      "alice",
+		Status:         entity.BookingStatusConfirmed,
+		StartAt:        &start,
+		EndAt:          &end,
+		Token:          "tok",
+		RemindersSent:  []int{1440},
+		NextReminderAt: &past,
+	}
+
+	sent, err := f.service.RunDueReminders(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, sent)
+
+	booking := f.bookings.bookings["b-1"]
+	assert.ElementsMatch(t, []int{1440, 60}, booking.RemindersSent)
+	assert.Nil(t, booking.NextReminderAt)
+
+	reminders := f.bookingMessages("reminder")
+	require.Len(t, reminders, 1)
+	assert.Contains(t, reminders[0].Content, "Reminder")
+	assert.Contains(t, reminders[0].Content, "/tok?action=cancel")
+
+	sent, err = f.service.RunDueReminders(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, sent)
+}
+
+func TestBooking_ScheduleNextReminder(t *testing.T) {
+	now := time.Date(2026, 3, 2, 8, 0, 0, 0, time.UTC)
+	start := now.Add(3 * time.Hour)
+	booking := &entity.Booking{Status: entity.BookingStatusConfirmed, StartAt: &start}
+	offsets := []int{1440, 120, 30}
+
+	// The day-before reminder is already past when booking three hours ahead
+	assert.Equal(t, []int{1440}, booking.DueReminders(offsets, now))
+	booking.RemindersSent = booking.DueReminders(offsets, now)
+	booking.ScheduleNextReminder(offsets, now)
+	require.NotNil(t, booking.NextReminderAt)
+	assert.Equal(t, start.Add(-120*time.Minute), *booking.NextReminderAt)
+
+	later := now.Add(2*time.Hour + 45*time.Minute)
+	assert.Equal(t, []int{30, 120}, booking.DueReminders(offsets, later))
+}
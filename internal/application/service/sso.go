@@ -0,0 +1,622 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	stderrors "errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/egress"
+	"github.com/msgfy/linktor/internal/infrastructure/sso"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// ssoStateTTL is how long a user has to sign in at the identity provider
+const ssoStateTTL = 10 * time.Minute
+
+// ssoClockSkew is the clock difference with identity providers tolerated when
+// remembering their assertions
+const ssoClockSkew = 2 * time.Minute
+
+// ssoReplayKeyPrefix namespaces the used login nonces and SAML assertions in Redis
+const ssoReplayKeyPrefix = "linktor:sso:used:"
+
+// SecretResolver resolves values stored as secret references
+type SecretResolver interface {
+	Resolve(ctx context.Context, value string) (string, error)
+}
+
+// SSOService signs tenant users in with the OIDC or SAML identity provider of their
+// tenant. Users are matched by email; unknown users are created on their first login
+// when the tenant enabled just-in-time provisioning, and the groups the provider sends
+// set their role. The provider is trusted with the second factor, so SSO logins don't
+// ask for the local one.
+type SSOService struct {
+	configRepo repository.SSOConfigRepository
+	tenantRepo repository.TenantRepository
+	userRepo   repository.UserRepository
+	auth       *AuthService
+	baseURL    string
+	secrets    SecretResolver
+	replays    *ssoReplayCache
+	checkURL   func(ctx context.Context, endpoint string) error
+	now        func() time.Time
+}
+
+// NewSSOService creates a new SSO service. baseURL is the public URL of the API the
+// identity providers send users back to.
+func NewSSOService(configRepo repository.SSOConfigRepository, tenantRepo repository.TenantRepository, userRepo repository.UserRepository, auth *AuthService, baseURL string) *SSOService {
+	s := &SSOService{
+		configRepo: configRepo,
+		tenantRepo: tenantRepo,
+		userRepo:   userRepo,
+		auth:       auth,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		checkURL:   egress.CheckURL,
+		now:        time.Now,
+	}
+	s.replays = &ssoReplayCache{used: make(map[string]time.Time), now: func() time.Time { return s.now() }}
+	return s
+}
+
+// SetRedis keeps the used login nonces and SAML assertions in Redis, so a login
+// can't be replayed against another server instance
+func (s *SSOService) SetRedis(client *redis.Client) {
+	s.replays.redis = client
+}
+
+// SetSecretResolver resolves OIDC client secrets stored as secret references
+func (s *SSOService) SetSecretResolver(resolver SecretResolver) {
+	s.secrets = resolver
+}
+
+// SaveSSOConfigInput represents the identity provider of a tenant
+type SaveSSOConfigInput struct {
+	Protocol string `json:"protocol" binding:"required"`
+	Enabled  bool   `json:"enabled"`
+
+	Issuer       string   `json:"issuer"`
+	ClientID     string   `json:"client_id"`
+	ClientSecret *string  `json:"client_secret"` // Kept when omitted
+	Scopes       []string `json:"scopes"`
+
+	IDPEntityID    string `json:"idp_entity_id"`
+	IDPSSOURL      string `json:"idp_sso_url"`
+	IDPCertificate string `json:"idp_certificate"`
+
+	EmailAttribute  string `json:"email_attribute"`
+	NameAttribute   string `json:"name_attribute"`
+	GroupsAttribute string `json:"groups_attribute"`
+
+	JITProvisioning bool              `json:"jit_provisioning"`
+	DefaultRole     string            `json:"default_role"`
+	GroupRoles      map[string]string `json:"group_roles"`
+	RedirectURLs    []string          `json:"redirect_urls"`
+}
+
+// GetConfig returns the identity provider of a tenant
+func (s *SSOService) GetConfig(ctx context.Context, tenantID string) (*entity.SSOConfig, error) {
+	return s.configRepo.FindByTenant(ctx, tenantID)
+}
+
+// SaveConfig configures the identity provider of a tenant. Only owners may, as the
+// provider decides who signs in to every account of the tenant.
+func (s *SSOService) SaveConfig(ctx context.Context, tenantID string, role entity.UserRole, input *SaveSSOConfigInput) (*entity.SSOConfig, error) {
+	if role != entity.UserRoleOwner {
+		return nil, errors.Forbidden("Only owners can configure SSO")
+	}
+
+	existing, err := s.configRepo.FindByTenant(ctx, tenantID)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	now := s.now()
+	config := &entity.SSOConfig{
+		TenantID:        tenantID,
+		Protocol:        entity.SSOProtocol(input.Protocol),
+		Enabled:         input.Enabled,
+		Issuer:          strings.TrimSpace(input.Issuer),
+		ClientID:        strings.TrimSpace(input.ClientID),
+		Scopes:          input.Scopes,
+		IDPEntityID:     strings.TrimSpace(input.IDPEntityID),
+		IDPSSOURL:       strings.TrimSpace(input.IDPSSOURL),
+		IDPCertificate:  strings.TrimSpace(input.IDPCertificate),
+		EmailAttribute:  input.EmailAttribute,
+		NameAttribute:   input.NameAttribute,
+		GroupsAttribute: input.GroupsAttribute,
+		JITProvisioning: input.JITProvisioning,
+		DefaultRole:     entity.UserRole(input.DefaultRole),
+		RedirectURLs:    input.RedirectURLs,
+		SavedByOwner:    true,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if existing != nil {
+		config.CreatedAt = existing.CreatedAt
+		config.ClientSecret = existing.ClientSecret
+	}
+	if input.ClientSecret != nil {
+		config.ClientSecret = *input.ClientSecret
+	}
+	if config.DefaultRole == "" {
+		config.DefaultRole = entity.UserRoleAgent
+	}
+	if len(input.GroupRoles) > 0 {
+		config.GroupRoles = make(map[string]entity.UserRole, len(input.GroupRoles))
+		for group, role := range input.GroupRoles {
+			config.GroupRoles[group] = entity.UserRole(role)
+		}
+	}
+
+	if err := validateSSOConfig(config); err != nil {
+		return nil, err
+	}
+	if config.Protocol == entity.SSOProtocolOIDC {
+		if err := s.checkURL(ctx, config.Issuer); err != nil {
+			return nil, errors.Validation("issuer must not point to a private, loopback or link-local address")
+		}
+	}
+	if err := s.configRepo.Save(ctx, config); err != nil {
+		return nil, err
+	}
+	config.HasClientSecret = config.ClientSecret != ""
+	return config, nil
+}
+
+// DeleteConfig removes the identity provider of a tenant; its users sign in with passwords again
+func (s *SSOService) DeleteConfig(ctx context.Context, tenantID string) error {
+	return s.configRepo.Delete(ctx, tenantID)
+}
+
+// validateSSOConfig checks an identity provider has what its protocol needs
+func validateSSOConfig(config *entity.SSOConfig) error {
+	switch config.Protocol {
+	case entity.SSOProtocolOIDC:
+		if !isAbsoluteURL(config.Issuer) {
+			return errors.Validation("issuer must be the URL of the OpenID Connect provider")
+		}
+		if config.ClientID == "" || config.ClientSecret == "" {
+			return errors.Validation("client_id and client_secret are required")
+		}
+	case entity.SSOProtocolSAML:
+		if !isAbsoluteURL(config.IDPSSOURL) {
+			return errors.Validation("idp_sso_url must be the single sign-on URL of the SAML provider")
+		}
+		if config.IDPEntityID == "" {
+			return errors.Validation("idp_entity_id must be the issuer of the SAML provider's assertions")
+		}
+		if _, err := sso.ParseCertificate(config.IDPCertificate); err != nil {
+			return errors.Validation("idp_certificate must be the PEM certificate of the SAML provider")
+		}
+	default:
+		return errors.Validation("protocol must be oidc or saml")
+	}
+
+	if !entity.IsSSORole(config.DefaultRole) {
+		return errors.Validation("default_role can't be granted by SSO")
+	}
+	for group, role := range config.GroupRoles {
+		if !entity.IsSSORole(role) {
+			return errors.Validation("role of group " + group + " can't be granted by SSO")
+		}
+	}
+	for _, redirectURL := range config.RedirectURLs {
+		if !isAbsoluteURL(redirectURL) {
+			return errors.Validation("redirect_urls must be absolute URLs")
+		}
+	}
+	return nil
+}
+
+func isAbsoluteURL(value string) bool {
+	parsed, err := url.Parse(value)
+	return err == nil && (parsed.Scheme == "https" || parsed.Scheme == "http") && parsed.Host != ""
+}
+
+// SSOCallback is what the identity provider sent back to the callback: an
+// authorization code for OIDC, a SAMLResponse for SAML
+type SSOCallback struct {
+	// Binding is the value of SSOLoginStart.Binding the browser kept in a cookie
+	Binding string
+
+	State            string
+	Code             string
+	Error            string
+	ErrorDescription string
+	SAMLResponse     string
+}
+
+// SSOLoginResult is a completed SSO login
+type SSOLoginResult struct {
+	Login *LoginResult
+	// RedirectURL is the app page the login started from, empty when none was given
+	RedirectURL string
+}
+
+// SSOLoginStart is a login sent to the identity provider
+type SSOLoginStart struct {
+	AuthURL string
+	// Binding must come back with the callback from the browser that started the
+	// login, in a cookie, so a callback can't be completed in another browser
+	Binding   string
+	ExpiresAt time.Time
+}
+
+// StartLogin returns the URL of the identity provider's login page for the tenant
+// with a slug. redirectURL is the app page the login returns to with the tokens and
+// must be one the tenant allowed; without it the callback answers with the tokens.
+func (s *SSOService) StartLogin(ctx context.Context, slug, redirectURL string) (*SSOLoginStart, error) {
+	tenant, config, err := s.enabledConfig(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	redirect := -1
+	if redirectURL != "" {
+		for i, allowed := range config.RedirectURLs {
+			if allowed == redirectURL {
+				redirect = i
+			}
+		}
+		if redirect < 0 {
+			return nil, errors.Validation("redirect_uri is not allowed for this tenant")
+		}
+	}
+
+	nonceBytes := make([]byte, 16)
+	if _, err := rand.Read(nonceBytes); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "Failed to generate SSO state")
+	}
+	nonce := hex.EncodeToString(nonceBytes)
+	expiresAt := s.now().Add(ssoStateTTL)
+	state := s.signState(tenant.ID, nonce, redirect, expiresAt)
+
+	var authURL string
+	switch config.Protocol {
+	case entity.SSOProtocolOIDC:
+		provider, err := s.oidcProvider(ctx, slug, config)
+		if err != nil {
+			return nil, err
+		}
+		authURL, err = provider.AuthURL(ctx, state, nonce)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "Failed to reach the identity provider")
+		}
+	case entity.SSOProtocolSAML:
+		provider, err := s.samlProvider(slug, config)
+		if err != nil {
+			return nil, err
+		}
+		authURL, err = provider.AuthURL(samlRequestID(nonce), state)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "Failed to build the SAML request")
+		}
+	}
+	return &SSOLoginStart{AuthURL: authURL, Binding: nonce, ExpiresAt: expiresAt}, nil
+}
+
+// CompleteLogin verifies what the identity provider sent back and logs the user in.
+// A login completes once, in the browser that started it.
+func (s *SSOService) CompleteLogin(ctx context.Context, slug string, callback *SSOCallback) (*SSOLoginResult, error) {
+	tenant, config, err := s.enabledConfig(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	nonce, redirect, ok := s.parseState(tenant.ID, callback.State)
+	if !ok {
+		return nil, errors.Unauthorized("SSO login expired or was not started here")
+	}
+	if !hmac.Equal([]byte(callback.Binding), []byte(nonce)) {
+		return nil, errors.Unauthorized("SSO login was not started in this browser")
+	}
+	fresh, err := s.replays.claim(ctx, "nonce:"+tenant.ID+":"+nonce, ssoStateTTL)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "Failed to check the SSO login")
+	}
+	if !fresh {
+		return nil, errors.Unauthorized("SSO login was already completed")
+	}
+	if callback.Error != "" {
+		return nil, errors.Unauthorized("Identity provider denied the login: " + firstNonEmpty(callback.ErrorDescription, callback.Error))
+	}
+
+	var identity *sso.Identity
+	switch config.Protocol {
+	case entity.SSOProtocolOIDC:
+		provider, err := s.oidcProvider(ctx, slug, config)
+		if err != nil {
+			return nil, err
+		}
+		if callback.Code == "" {
+			return nil, errors.Validation("code is required")
+		}
+		identity, err = provider.Exchange(ctx, callback.Code, nonce)
+		if err != nil {
+			return nil, ssoError(err)
+		}
+	case entity.SSOProtocolSAML:
+		provider, err := s.samlProvider(slug, config)
+		if err != nil {
+			return nil, err
+		}
+		if callback.SAMLResponse == "" {
+			return nil, errors.Validation("SAMLResponse is required")
+		}
+		identity, err = provider.ParseResponse(callback.SAMLResponse, samlRequestID(nonce))
+		if err != nil {
+			return nil, ssoError(err)
+		}
+		// Assertions are remembered until they expire, whichever login carries them
+		ttl := identity.ExpiresAt.Sub(s.now()) + ssoClockSkew
+		if ttl < ssoStateTTL {
+			ttl = ssoStateTTL
+		}
+		fresh, err := s.replays.claim(ctx, "assertion:"+tenant.ID+":"+identity.AssertionID, ttl)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "Failed to check the SAML assertion")
+		}
+		if !fresh {
+			return nil, errors.Unauthorized("SAML assertion was already used")
+		}
+	}
+
+	user, err := s.provision(ctx, tenant, config, identity)
+	if err != nil {
+		return nil, err
+	}
+	login, err := s.auth.issueLogin(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SSOLoginResult{Login: login}
+	if redirect >= 0 && redirect < len(config.RedirectURLs) {
+		result.RedirectURL = config.RedirectURLs[redirect]
+	}
+	return result, nil
+}
+
+// Metadata returns the SAML service provider metadata of a tenant, to register with
+// its identity provider
+func (s *SSOService) Metadata(ctx context.Context, slug string) ([]byte, error) {
+	tenant, err := s.tenantRepo.FindBySlug(ctx, slug)
+	if err != nil {
+		return nil, errors.NotFound("tenant")
+	}
+	config, err := s.configRepo.FindByTenant(ctx, tenant.ID)
+	if err != nil {
+		return nil, err
+	}
+	if config.Protocol != entity.SSOProtocolSAML {
+		return nil, errors.Validation("the tenant's identity provider does not use SAML")
+	}
+	provider, err := s.samlProvider(slug, config)
+	if err != nil {
+		return nil, err
+	}
+	return provider.Metadata(), nil
+}
+
+// provision finds the user an identity provider authenticated, creating them when the
+// tenant enabled it, and applies the role of their groups
+func (s *SSOService) provision(ctx context.Context, tenant *entity.Tenant, config *entity.SSOConfig, identity *sso.Identity) (*entity.User, error) {
+	email := entity.NormalizeEmail(identity.Email)
+	if email == "" {
+		return nil, errors.Unauthorized("Identity provider did not send an email address")
+	}
+	role, mapped := config.RoleForGroups(identity.Groups)
+
+	user, err := s.userRepo.FindByTenantAndEmail(ctx, tenant.ID, email)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	if user != nil {
+		if user.Status != entity.UserStatusActive {
+			return nil, errors.New(errors.ErrCodeInvalidCredentials, "Account is not active")
+		}
+		// A provider an admin configured could otherwise assert an owner's email
+		if user.Role == entity.UserRoleOwner && !config.SavedByOwner {
+			return nil, errors.Unauthorized("Owners can only sign in with SSO configured by an owner")
+		}
+		// Owners are never managed by SSO
+		if mapped && user.Role != entity.UserRoleOwner && user.Role != role {
+			user.Role = role
+			user.UpdatedAt = s.now()
+			if err := s.userRepo.Update(ctx, user); err != nil {
+				return nil, errors.Wrap(err, errors.ErrCodeInternal, "Failed to update user role")
+			}
+		}
+		return user, nil
+	}
+	if !config.JITProvisioning {
+		return nil, errors.Unauthorized("No account for " + email + "; ask an administrator for an invitation")
+	}
+
+	if tenant.Limits != nil && tenant.Limits.MaxUsers > 0 {
+		count, err := s.userRepo.CountByTenant(ctx, tenant.ID)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "Failed to count users")
+		}
+		if int(count) >= tenant.Limits.MaxUsers {
+			return nil, errors.New(errors.ErrCodeQuotaExceeded, "Maximum number of users reached")
+		}
+	}
+
+	// Provisioned users have no usable password; they sign in through the provider
+	password := make([]byte, 32)
+	if _, err := rand.Read(password); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "Failed to provision user")
+	}
+	passwordHash, err := HashPassword(hex.EncodeToString(password))
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "Failed to hash password")
+	}
+	if !mapped {
+		role = config.DefaultRole
+	}
+	name := identity.Name
+	if name == "" {
+		name = email
+	}
+
+	user = entity.NewUser(tenant.ID, email, passwordHash, name, role)
+	user.ID = uuid.New().String()
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "Failed to create user")
+	}
+	return user, nil
+}
+
+// enabledConfig returns a tenant and its identity provider, which must be enabled
+func (s *SSOService) enabledConfig(ctx context.Context, slug string) (*entity.Tenant, *entity.SSOConfig, error) {
+	tenant, err := s.tenantRepo.FindBySlug(ctx, slug)
+	if err != nil {
+		return nil, nil, errors.NotFound("tenant")
+	}
+	config, err := s.configRepo.FindByTenant(ctx, tenant.ID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil, errors.Forbidden("SSO is not enabled for this tenant")
+		}
+		return nil, nil, err
+	}
+	if !config.Enabled {
+		return nil, nil, errors.Forbidden("SSO is not enabled for this tenant")
+	}
+	return tenant, config, nil
+}
+
+// callbackURL is where identity providers send users back to
+func (s *SSOService) callbackURL(slug string) string {
+	return s.baseURL + "/api/v1/auth/sso/" + url.PathEscape(slug) + "/callback"
+}
+
+func (s *SSOService) oidcProvider(ctx context.Context, slug string, config *entity.SSOConfig) (*sso.OIDCProvider, error) {
+	secret := config.ClientSecret
+	if s.secrets != nil {
+		resolved, err := s.secrets.Resolve(ctx, secret)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "Failed to resolve the SSO client secret")
+		}
+		secret = resolved
+	}
+	return sso.NewOIDCProvider(sso.OIDCConfig{
+		Issuer:       config.Issuer,
+		ClientID:     config.ClientID,
+		ClientSecret: secret,
+		RedirectURL:  s.callbackURL(slug),
+		Scopes:       config.Scopes,
+		EmailClaim:   config.EmailAttribute,
+		NameClaim:    config.NameAttribute,
+		GroupsClaim:  config.GroupsAttribute,
+	}), nil
+}
+
+func (s *SSOService) samlProvider(slug string, config *entity.SSOConfig) (*sso.SAMLProvider, error) {
+	provider, err := sso.NewSAMLProvider(sso.SAMLConfig{
+		EntityID:        s.baseURL + "/api/v1/auth/sso/" + url.PathEscape(slug) + "/metadata",
+		ACSURL:          s.callbackURL(slug),
+		IDPEntityID:     config.IDPEntityID,
+		IDPSSOURL:       config.IDPSSOURL,
+		IDPCertificate:  config.IDPCertificate,
+		EmailAttribute:  config.EmailAttribute,
+		NameAttribute:   config.NameAttribute,
+		GroupsAttribute: config.GroupsAttribute,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "Invalid SAML provider configuration")
+	}
+	return provider, nil
+}
+
+// samlRequestID is the ID of the AuthnRequest of a login, which responses answer
+func samlRequestID(nonce string) string {
+	return "_" + nonce
+}
+
+// ssoError turns an identity provider failure into an application error
+func ssoError(err error) error {
+	if stderrors.Is(err, sso.ErrInvalidResponse) || stderrors.Is(err, sso.ErrDenied) {
+		return errors.Unauthorized("SSO login failed").WithError(err)
+	}
+	return errors.Wrap(err, errors.ErrCodeInternal, "Failed to reach the identity provider")
+}
+
+// signState returns the state of a login. It travels through the identity provider
+// and stays under the 80 bytes SAML allows for RelayState.
+func (s *SSOService) signState(tenantID, nonce string, redirect int, expiresAt time.Time) string {
+	payload := strconv.FormatInt(expiresAt.Unix(), 36) + "." + strconv.Itoa(redirect) + "." + nonce
+	return payload + "." + s.stateMAC(tenantID, payload)
+}
+
+// parseState checks the state of a login for a tenant and returns its nonce and redirect
+func (s *SSOService) parseState(tenantID, state string) (string, int, bool) {
+	parts := strings.Split(state, ".")
+	if len(parts) != 4 {
+		return "", 0, false
+	}
+	payload := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(parts[3]), []byte(s.stateMAC(tenantID, payload))) {
+		return "", 0, false
+	}
+	expiresAt, err := strconv.ParseInt(parts[0], 36, 64)
+	if err != nil || s.now().Unix() > expiresAt {
+		return "", 0, false
+	}
+	redirect, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return "", 0, false
+	}
+	return parts[2], redirect, true
+}
+
+func (s *SSOService) stateMAC(tenantID, payload string) string {
+	mac := hmac.New(sha256.New, []byte(s.auth.config.Secret))
+	mac.Write([]byte("sso:" + tenantID + ":" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// ssoReplayCache remembers the login nonces and SAML assertions already used, so
+// neither logs anyone in twice. Without Redis they are kept in process memory,
+// which only protects a single server instance.
+type ssoReplayCache struct {
+	redis *redis.Client
+	now   func() time.Time
+
+	mu   sync.Mutex
+	used map[string]time.Time // Key -> when it may be forgotten
+}
+
+// claim marks a key used for ttl, reporting false when it already was
+func (c *ssoReplayCache) claim(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	if c.redis != nil {
+		return c.redis.SetNX(ctx, ssoReplayKeyPrefix+key, 1, ttl).Result()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for used, expiresAt := range c.used {
+		if !now.Before(expiresAt) {
+			delete(c.used, used)
+		}
+	}
+	if _, ok := c.used[key]; ok {
+		return false, nil
+	}
+	c.used[key] = now.Add(ttl)
+	return true, nil
+}
@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/infrastructure/sso"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSSOConfigRepository struct {
+	configs map[string]*entity.SSOConfig
+}
+
+func (m *mockSSOConfigRepository) FindByTenant(ctx context.Context, tenantID string) (*entity.SSOConfig, error) {
+	config, ok := m.configs[tenantID]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, "SSO not configured")
+	}
+	copied := *config
+	return &copied, nil
+}
+
+func (m *mockSSOConfigRepository) Save(ctx context.Context, config *entity.SSOConfig) error {
+	copied := *config
+	m.configs[config.TenantID] = &copied
+	return nil
+}
+
+func (m *mockSSOConfigRepository) Delete(ctx context.Context, tenantID string) error {
+	delete(m.configs, tenantID)
+	return nil
+}
+
+func newTestSSOService(t *testing.T) (*SSOService, *mockSSOConfigRepository, *testutil.MockUserRepository) {
+	t.Helper()
+	auth, userRepo := newTestAuthService()
+	tenantRepo := testutil.NewMockTenantRepository()
+	tenantRepo.Tenants["tenant-1"] = &entity.Tenant{ID: "tenant-1", Slug: "acme", Limits: &entity.TenantLimits{MaxUsers: 2}}
+	configRepo := &mockSSOConfigRepository{configs: map[string]*entity.SSOConfig{}}
+	return NewSSOService(configRepo, tenantRepo, userRepo, auth, "https://api.example.com/"), configRepo, userRepo
+}
+
+func testSSOCertificate(t *testing.T) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
+
+func TestSSOService_State(t *testing.T) {
+	svc, _, _ := newTestSSOService(t)
+	state := svc.signState("tenant-1", "abcdef", 2, time.Now().Add(time.Minute))
+	assert.LessOrEqual(t, len(state), 80)
+
+	nonce, redirect, ok := svc.parseState("tenant-1", state)
+	assert.True(t, ok)
+	assert.Equal(t, "abcdef", nonce)
+	assert.Equal(t, 2, redirect)
+
+	_, _, ok = svc.parseState("tenant-2", state)
+	assert.False(t, ok, "state of another tenant")
+	_, _, ok = svc.parseState("tenant-1", strings.Replace(state, ".2.", ".0.", 1))
+	assert.False(t, ok, "tampered state")
+
+	expired := svc.signState("tenant-1", "abcdef", -1, time.Now().Add(-time.Second))
+	_, _, ok = svc.parseState("tenant-1", expired)
+	assert.False(t, ok, "expired state")
+}
+
+func TestSSOService_SaveConfig(t *testing.T) {
+	ctx := context.Background()
+	svc, configRepo, _ := newTestSSOService(t)
+	secret := "client-secret"
+
+	_, err := svc.SaveConfig(ctx, "tenant-1", entity.UserRoleOwner, &SaveSSOConfigInput{Protocol: "oidc", Issuer: "https://idp.example.com", ClientID: "linktor"})
+	assert.Error(t, err, "OIDC without client secret")
+
+	_, err = svc.SaveConfig(ctx, "tenant-1", entity.UserRoleAdmin, &SaveSSOConfigInput{Protocol: "oidc", Issuer: "https://idp.example.com", ClientID: "linktor", ClientSecret: &secret})
+	assert.Equal(t, errors.ErrCodeForbidden, errors.GetAppError(err).Code, "only owners configure SSO")
+
+	_, err = svc.SaveConfig(ctx, "tenant-1", entity.UserRoleOwner, &SaveSSOConfigInput{Protocol: "oidc", Issuer: "https://idp.example.com", ClientID: "linktor", ClientSecret: &secret, DefaultRole: "owner"})
+	assert.Error(t, err, "SSO never grants owner")
+
+	config, err := svc.SaveConfig(ctx, "tenant-1", entity.UserRoleOwner, &SaveSSOConfigInput{
+		Protocol:     "oidc",
+		Enabled:      true,
+		Issuer:       "https://idp.example.com",
+		ClientID:     "linktor",
+		ClientSecret: &secret,
+		GroupRoles:   map[string]string{"support-leads": "supervisor"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, entity.UserRoleAgent, config.DefaultRole)
+	assert.True(t, config.HasClientSecret)
+	assert.True(t, config.SavedByOwner)
+
+	// The client secret is kept when omitted
+	_, err = svc.SaveConfig(ctx, "tenant-1", entity.UserRoleOwner, &SaveSSOConfigInput{Protocol: "oidc", Issuer: "https://idp.example.com", ClientID: "linktor-2"})
+	require.NoError(t, err)
+	assert.Equal(t, "client-secret", configRepo.configs["tenant-1"].ClientSecret)
+
+	_, err = svc.SaveConfig(ctx, "tenant-1", entity.UserRoleOwner, &SaveSSOConfigInput{Protocol: "saml", IDPSSOURL: "https://idp.example.com/sso", IDPCertificate: "not a certificate"})
+	assert.Error(t, err)
+	_, err = svc.SaveConfig(ctx, "tenant-1", entity.UserRoleOwner, &SaveSSOConfigInput{Protocol: "saml", IDPSSOURL: "https://idp.example.com/sso", IDPCertificate: testSSOCertificate(t)})
+	assert.True(t, errors.IsValidation(err), "SAML without the issuer of its assertions")
+	_, err = svc.SaveConfig(ctx, "tenant-1", entity.UserRoleOwner, &SaveSSOConfigInput{Protocol: "saml", IDPEntityID: "https://idp.example.com", IDPSSOURL: "https://idp.example.com/sso", IDPCertificate: testSSOCertificate(t)})
+	assert.NoError(t, err)
+
+	_, err = svc.SaveConfig(ctx, "tenant-1", entity.UserRoleOwner, &SaveSSOConfigInput{Protocol: "oidc", Issuer: "http://169.254.169.254", ClientID: "linktor", ClientSecret: &secret})
+	assert.True(t, errors.IsValidation(err), "issuer on an internal address")
+}
+
+func TestSSOService_StartLogin(t *testing.T) {
+	ctx := context.Background()
+	svc, configRepo, _ := newTestSSOService(t)
+
+	_, err := svc.StartLogin(ctx, "acme", "")
+	assert.Error(t, err, "SSO not configured")
+
+	configRepo.configs["tenant-1"] = &entity.SSOConfig{
+		TenantID:       "tenant-1",
+		Protocol:       entity.SSOProtocolSAML,
+		Enabled:        true,
+		IDPEntityID:    "https://idp.example.com",
+		IDPSSOURL:      "https://idp.example.com/sso",
+		IDPCertificate: testSSOCertificate(t),
+		RedirectURLs:   []string{"https://app.example.com/login"},
+	}
+
+	_, err = svc.StartLogin(ctx, "acme", "https://evil.example.com")
+	assert.Error(t, err, "redirect not allowed")
+
+	start, err := svc.StartLogin(ctx, "acme", "https://app.example.com/login")
+	require.NoError(t, err)
+	parsed, err := url.Parse(start.AuthURL)
+	require.NoError(t, err)
+	assert.Equal(t, "idp.example.com", parsed.Host)
+	assert.NotEmpty(t, parsed.Query().Get("SAMLRequest"))
+
+	nonce, redirect, ok := svc.parseState("tenant-1", parsed.Query().Get("RelayState"))
+	assert.True(t, ok)
+	assert.NotEmpty(t, nonce)
+	assert.Equal(t, nonce, start.Binding)
+	assert.Equal(t, 0, redirect)
+
+	metadata, err := svc.Metadata(ctx, "acme")
+	require.NoError(t, err)
+	assert.Contains(t, string(metadata), "https://api.example.com/api/v1/auth/sso/acme/callback")
+
+	_, err = svc.CompleteLogin(ctx, "acme", &SSOCallback{State: "forged", SAMLResponse: "x"})
+	assert.Equal(t, errors.ErrCodeUnauthorized, errors.GetAppError(err).Code)
+}
+
+func TestSSOService_CompleteLogin_OnceInTheStartingBrowser(t *testing.T) {
+	ctx := context.Background()
+	svc, configRepo, _ := newTestSSOService(t)
+	configRepo.configs["tenant-1"] = &entity.SSOConfig{
+		TenantID:       "tenant-1",
+		Protocol:       entity.SSOProtocolSAML,
+		Enabled:        true,
+		IDPEntityID:    "https://idp.example.com",
+		IDPSSOURL:      "https://idp.example.com/sso",
+		IDPCertificate: testSSOCertificate(t),
+	}
+	start, err := svc.StartLogin(ctx, "acme", "")
+	require.NoError(t, err)
+	parsed, err := url.Parse(start.AuthURL)
+	require.NoError(t, err)
+	state := parsed.Query().Get("RelayState")
+
+	for _, binding := range []string{"", "another-browser"} {
+		_, err = svc.CompleteLogin(ctx, "acme", &SSOCallback{Binding: binding, State: state, SAMLResponse: "x"})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not started in this browser")
+	}
+
+	// The identity provider's answer is only looked at once the nonce is used up
+	_, err = svc.CompleteLogin(ctx, "acme", &SSOCallback{Binding: start.Binding, State: state, Error: "access_denied"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "denied")
+
+	_, err = svc.CompleteLogin(ctx, "acme", &SSOCallback{Binding: start.Binding, State: state, SAMLResponse: "x"})
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeUnauthorized, errors.GetAppError(err).Code)
+	assert.Contains(t, err.Error(), "already completed")
+}
+
+func TestSSOReplayCache(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	cache := &ssoReplayCache{used: map[string]time.Time{}, now: func() time.Time { return now }}
+
+	fresh, err := cache.claim(ctx, "assertion:tenant-1:_a1", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, fresh)
+	fresh, _ = cache.claim(ctx, "assertion:tenant-1:_a1", time.Minute)
+	assert.False(t, fresh, "replayed")
+	fresh, _ = cache.claim(ctx, "assertion:tenant-2:_a1", time.Minute)
+	assert.True(t, fresh, "same ID at another tenant")
+
+	now = now.Add(time.Minute)
+	fresh, _ = cache.claim(ctx, "assertion:tenant-1:_a1", time.Minute)
+	assert.True(t, fresh, "forgotten once expired")
+	assert.Len(t, cache.used, 1)
+}
+
+func TestSSOService_Provision(t *testing.T) {
+	ctx := context.Background()
+	svc, _, userRepo := newTestSSOService(t)
+	tenant := &entity.Tenant{ID: "tenant-1", Limits: &entity.TenantLimits{MaxUsers: 2}}
+	config := &entity.SSOConfig{
+		TenantID:    "tenant-1",
+		DefaultRole: entity.UserRoleAgent,
+		GroupRoles: map[string]entity.UserRole{
+			"support":       entity.UserRoleAgent,
+			"support-leads": entity.UserRoleSupervisor,
+		},
+	}
+
+	_, err := svc.provision(ctx, tenant, config, &sso.Identity{Email: "new@example.com"})
+	assert.Error(t, err, "unknown user without JIT provisioning")
+
+	config.JITProvisioning = true
+	user, err := svc.provision(ctx, tenant, config, &sso.Identity{Email: "New@Example.com", Name: "New", Groups: []string{"support", "support-leads"}})
+	require.NoError(t, err)
+	assert.Equal(t, "new@example.com", user.Email)
+	assert.Equal(t, entity.UserRoleSupervisor, user.Role)
+	assert.Len(t, userRepo.Users, 1)
+
+	user, err = svc.provision(ctx, tenant, config, &sso.Identity{Email: "new@example.com", Groups: []string{"support"}})
+	require.NoError(t, err)
+	assert.Equal(t, entity.UserRoleAgent, user.Role, "role follows the groups at every login")
+	assert.Len(t, userRepo.Users, 1)
+
+	user, err = svc.provision(ctx, tenant, config, &sso.Identity{Email: "other@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, entity.UserRoleAgent, user.Role, "default role without mapped groups")
+
+	_, err = svc.provision(ctx, tenant, config, &sso.Identity{Email: "third@example.com"})
+	assert.Equal(t, errors.ErrCodeQuotaExceeded, errors.GetAppError(err).Code)
+
+	owner := createTestUser(t, "tenant-1", "owner@example.com", "password123")
+	owner.ID = "owner-1"
+	owner.Role = entity.UserRoleOwner
+	userRepo.Users[owner.ID] = owner
+	_, err = svc.provision(ctx, tenant, config, &sso.Identity{Email: "owner@example.com"})
+	assert.True(t, errors.IsUnauthorized(err), "owners only sign in with SSO an owner configured")
+
+	config.SavedByOwner = true
+	user, err = svc.provision(ctx, tenant, config, &sso.Identity{Email: "owner@example.com", Groups: []string{"support"}})
+	require.NoError(t, err)
+	assert.Equal(t, entity.UserRoleOwner, user.Role, "owners are never managed by SSO")
+
+	userRepo.ReturnError = errors.New(errors.ErrCodeInternal, "database unavailable")
+	_, err = svc.provision(ctx, tenant, config, &sso.Identity{Email: "fourth@example.com"})
+	assert.Equal(t, errors.ErrCodeInternal, errors.GetAppError(err).Code, "lookup errors don't fall through to provisioning")
+}
@@ -0,0 +1,583 @@
+package service
+
+import (
+	"context"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Events published when incidents change
+const (
+	EventIncidentOpened   = "status_page.incident_opened"
+	EventIncidentUpdated  = "status_page.incident_updated"
+	EventIncidentResolved = "status_page.incident_resolved"
+)
+
+// statusPageHistory is how far back resolved incidents are shown on a public page
+const statusPageHistory = 14 * 24 * time.Hour
+
+var statusPageSlugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}$`)
+
+// IncidentNotifier is told when an incident is opened, updated or resolved, so
+// channels can show or clear incident banners
+type IncidentNotifier interface {
+	NotifyIncidentChanged(ctx context.Context, incident *entity.Incident)
+}
+
+// SaveStatusPageInput represents input for configuring a tenant's status page
+type SaveStatusPageInput struct {
+	Slug          string   `json:"slug" binding:"required"`
+	Title         string   `json:"title" binding:"required"`
+	Description   string   `json:"description"`
+	IsPublished   bool     `json:"is_published"`
+	ChannelIDs    []string `json:"channel_ids,omitempty"`
+	AutoIncidents *bool    `json:"auto_incidents,omitempty"`
+}
+
+// CreateIncidentInput represents input for declaring an incident or maintenance window
+type CreateIncidentInput struct {
+	Kind       entity.IncidentKind   `json:"kind"`
+	Title      string                `json:"title" binding:"required"`
+	Message    string                `json:"message"`
+	Impact     entity.IncidentImpact `json:"impact"`
+	Status     entity.IncidentStatus `json:"status"`
+	ChannelIDs []string              `json:"channel_ids,omitempty"`
+	StartsAt   *time.Time            `json:"starts_at,omitempty"`
+	EndsAt     *time.Time            `json:"ends_at,omitempty"`
+}
+
+// UpdateIncidentInput represents a timeline update posted on an incident
+type UpdateIncidentInput struct {
+	Status  entity.IncidentStatus  `json:"status" binding:"required"`
+	Message string                 `json:"message" binding:"required"`
+	Impact  *entity.IncidentImpact `json:"impact,omitempty"`
+	EndsAt  *time.Time             `json:"ends_at,omitempty"`
+}
+
+// StatusPageService publishes tenant status pages built from channel health and
+// declared incidents, and opens incidents automatically when channels fail
+type StatusPageService struct {
+	repo        repository.StatusPageRepository
+	channelRepo repository.ChannelRepository
+	producer    nats.Publisher
+	notifier    IncidentNotifier
+}
+
+// NewStatusPageService creates a new status page service
+func NewStatusPageService(repo repository.StatusPageRepository, channelRepo repository.ChannelRepository, producer nats.Publisher) *StatusPageService {
+	return &StatusPageService{
+		repo:        repo,
+		channelRepo: channelRepo,
+		producer:    producer,
+	}
+}
+
+// SetNotifier sets the notifier told about incident changes
+func (s *StatusPageService) SetNotifier(notifier IncidentNotifier) {
+	s.notifier = notifier
+}
+
+// GetPage returns the status page of a tenant
+func (s *StatusPageService) GetPage(ctx context.Context, tenantID string) (*entity.StatusPage, error) {
+	return s.repo.FindByTenant(ctx, tenantID)
+}
+
+// SavePage creates or updates the status page of a tenant
+func (s *StatusPageService) SavePage(ctx context.Context, tenantID string, input *SaveStatusPageInput) (*entity.StatusPage, error) {
+	if !statusPageSlugPattern.MatchString(input.Slug) {
+		return nil, errors.Validation("slug must be 2-63 lowercase letters, digits or hyphens")
+	}
+	if input.Title == "" {
+		return nil, errors.Validation("title is required")
+	}
+
+	if existing, err := s.repo.FindBySlug(ctx, input.Slug); err == nil && existing.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeConflict, "slug is already in use")
+	}
+
+	now := time.Now()
+	page, err := s.repo.FindByTenant(ctx, tenantID)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		page = &entity.StatusPage{
+			ID:            uuid.New().String(),
+			TenantID:      tenantID,
+			AutoIncidents: true,
+			CreatedAt:     now,
+		}
+	}
+
+	page.Slug = input.Slug
+	page.Title = input.Title
+	page.Description = input.Description
+	page.IsPublished = input.IsPublished
+	page.ChannelIDs = input.ChannelIDs
+	if input.AutoIncidents != nil {
+		page.AutoIncidents = *input.AutoIncidents
+	}
+	page.UpdatedAt = now
+
+	if err := s.repo.Save(ctx, page); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// GetPublicStatus renders a published status page by its slug
+func (s *StatusPageService) GetPublicStatus(ctx context.Context, slug string) (*entity.PublicStatusPage, error) {
+	page, err := s.repo.FindBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if !page.IsPublished {
+		return nil, errors.New(errors.ErrCodeNotFound, "status page not found")
+	}
+	return s.render(ctx, page)
+}
+
+// PreviewPage renders a tenant's status page whether or not it is published
+func (s *StatusPageService) PreviewPage(ctx context.Context, tenantID string) (*entity.PublicStatusPage, error) {
+	page, err := s.repo.FindByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return s.render(ctx, page)
+}
+
+func (s *StatusPageService) render(ctx context.Context, page *entity.StatusPage) (*entity.PublicStatusPage, error) {
+	now := time.Now()
+
+	channels, err := s.channelRepo.FindEnabledByTenant(ctx, page.TenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	since := now.Add(-statusPageHistory)
+	incidents, err := s.repo.ListIncidents(ctx, page.TenantID, &entity.IncidentFilter{Since: &since})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &entity.PublicStatusPage{
+		Title:               page.Title,
+		Description:         page.Description,
+		Status:              entity.ComponentStatusOperational,
+		Components:          []entity.StatusPageComponent{},
+		ActiveIncidents:     []*entity.Incident{},
+		UpcomingMaintenance: []*entity.Incident{},
+		RecentIncidents:     []*entity.Incident{},
+		UpdatedAt:           now,
+	}
+
+	var active []*entity.Incident
+	for _, incident := range incidents {
+		if !s.incidentVisible(page, incident) {
+			continue
+		}
+		switch {
+		case incident.IsActive(now):
+			active = append(active, incident)
+			result.ActiveIncidents = append(result.ActiveIncidents, incident)
+		case !incident.IsResolved() && now.Before(incident.StartsAt):
+			result.UpcomingMaintenance = append(result.UpcomingMaintenance, incident)
+		default:
+			result.RecentIncidents = append(result.RecentIncidents, incident)
+		}
+	}
+
+	for _, channel := range channels {
+		if !page.ShowsChannel(channel.ID) {
+			continue
+		}
+
+		component := entity.StatusPageComponent{
+			ChannelID: channel.ID,
+			Name:      channel.Name,
+			Type:      channel.Type,
+			Status:    channelHealthStatus(channel),
+		}
+		for _, incident := range active {
+			if !incident.Affects(channel.ID) {
+				continue
+			}
+			if status := incidentComponentStatus(incident); status.WorseThan(component.Status) {
+				component.Status = status
+			}
+		}
+
+		if component.Status.WorseThan(result.Status) {
+			result.Status = component.Status
+		}
+		result.Components = append(result.Components, component)
+	}
+
+	return result, nil
+}
+
+// incidentVisible reports whether an incident concerns a channel listed on the page
+func (s *StatusPageService) incidentVisible(page *entity.StatusPage, incident *entity.Incident) bool {
+	if len(page.ChannelIDs) == 0 || len(incident.ChannelIDs) == 0 {
+		return true
+	}
+	for _, channelID := range incident.ChannelIDs {
+		if page.ShowsChannel(channelID) {
+			return true
+		}
+	}
+	return false
+}
+
+// channelHealthStatus maps the connection state of a channel to a component status
+func channelHealthStatus(channel *entity.Channel) entity.ComponentStatus {
+	switch channel.ConnectionStatus {
+	case entity.ConnectionStatusConnected:
+		return entity.ComponentStatusOperational
+	case entity.ConnectionStatusConnecting:
+		return entity.ComponentStatusDegraded
+	case entity.ConnectionStatusError:
+		return entity.ComponentStatusMajorOutage
+	default:
+		return entity.ComponentStatusPartialOutage
+	}
+}
+
+// incidentComponentStatus maps an active incident to the status of its channels
+func incidentComponentStatus(incident *entity.Incident) entity.ComponentStatus {
+	if incident.Kind == entity.IncidentKindMaintenance {
+		return entity.ComponentStatusUnderMaintenance
+	}
+	switch incident.Impact {
+	case entity.IncidentImpactCritical:
+		return entity.ComponentStatusMajorOutage
+	case entity.IncidentImpactMajor:
+		return entity.ComponentStatusPartialOutage
+	default:
+		return entity.ComponentStatusDegraded
+	}
+}
+
+// ListIncidents lists the incidents of a tenant
+func (s *StatusPageService) ListIncidents(ctx context.Context, tenantID string, filter *entity.IncidentFilter) ([]*entity.Incident, error) {
+	return s.repo.ListIncidents(ctx, tenantID, filter)
+}
+
+// GetIncident returns an incident of a tenant
+func (s *StatusPageService) GetIncident(ctx context.Context, tenantID, id string) (*entity.Incident, error) {
+	incident, err := s.repo.FindIncidentByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if incident.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeNotFound, "incident not found")
+	}
+	return incident, nil
+}
+
+// CreateIncident declares an incident or schedules a maintenance window
+func (s *StatusPageService) CreateIncident(ctx context.Context, tenantID string, input *CreateIncidentInput) (*entity.Incident, error) {
+	now := time.Now()
+
+	incident := &entity.Incident{
+		ID:         uuid.New().String(),
+		TenantID:   tenantID,
+		Kind:       input.Kind,
+		Title:      input.Title,
+		Message:    input.Message,
+		Impact:     input.Impact,
+		Status:     input.Status,
+		ChannelIDs: input.ChannelIDs,
+		StartsAt:   now,
+		EndsAt:     input.EndsAt,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if input.StartsAt != nil {
+		incident.StartsAt = *input.StartsAt
+	}
+	if incident.Kind == "" {
+		incident.Kind = entity.IncidentKindIncident
+	}
+	if incident.Impact == "" {
+		incident.Impact = entity.IncidentImpactMinor
+	}
+	if incident.Status == "" {
+		incident.Status = entity.IncidentStatusInvestigating
+		if incident.StartsAt.After(now) {
+			incident.Status = entity.IncidentStatusScheduled
+		}
+	}
+
+	if err := validateIncident(incident); err != nil {
+		return nil, err
+	}
+	if incident.IsResolved() {
+		return nil, errors.Validation("a new incident cannot be resolved")
+	}
+
+	incident.Updates = []entity.IncidentUpdate{{
+		Status:    incident.Status,
+		Message:   incident.Message,
+		CreatedAt: now,
+	}}
+
+	if err := s.repo.CreateIncident(ctx, incident); err != nil {
+		return nil, err
+	}
+
+	s.incidentChanged(ctx, EventIncidentOpened, incident)
+	return incident, nil
+}
+
+// UpdateIncident posts a timeline update on an incident and changes its status
+func (s *StatusPageService) UpdateIncident(ctx context.Context, tenantID, id string, input *UpdateIncidentInput) (*entity.Incident, error) {
+	incident, err := s.GetIncident(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if incident.IsResolved() {
+		return nil, errors.New(errors.ErrCodeConflict, "incident already resolved")
+	}
+	if !input.Status.IsValid() {
+		return nil, errors.Validation("invalid incident status")
+	}
+
+	now := time.Now()
+	incident.Status = input.Status
+	incident.Message = input.Message
+	if input.Impact != nil {
+		incident.Impact = *input.Impact
+	}
+	if input.EndsAt != nil {
+		incident.EndsAt = input.EndsAt
+	}
+	if err := validateIncident(incident); err != nil {
+		return nil, err
+	}
+
+	incident.Updates = append(incident.Updates, entity.IncidentUpdate{
+		Status:    input.Status,
+		Message:   input.Message,
+		CreatedAt: now,
+	})
+
+	eventType := EventIncidentUpdated
+	if incident.IsResolved() {
+		incident.ResolvedAt = &now
+		eventType = EventIncidentResolved
+	} else if incident.Status != entity.IncidentStatusScheduled && incident.StartsAt.After(now) {
+		// Work on a scheduled maintenance window started early
+		incident.StartsAt = now
+	}
+	incident.UpdatedAt = now
+
+	if err := s.repo.UpdateIncident(ctx, incident); err != nil {
+		return nil, err
+	}
+
+	s.incidentChanged(ctx, eventType, incident)
+	return incident, nil
+}
+
+// DeleteIncident deletes an incident, clearing its banners
+func (s *StatusPageService) DeleteIncident(ctx context.Context, tenantID, id string) error {
+	incident, err := s.GetIncident(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteIncident(ctx, id); err != nil {
+		return err
+	}
+
+	// Report the incident as resolved so widgets drop its banner
+	incident.Status = entity.IncidentStatusResolved
+	if s.notifier != nil {
+		s.notifier.NotifyIncidentChanged(ctx, incident)
+	}
+	return nil
+}
+
+// ActiveBanners returns the banners of the incidents currently affecting a channel
+func (s *StatusPageService) ActiveBanners(ctx context.Context, tenantID, channelID string) ([]entity.IncidentBanner, error) {
+	incidents, err := s.repo.ListIncidents(ctx, tenantID, &entity.IncidentFilter{Unresolved: true})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	banners := []entity.IncidentBanner{}
+	for _, incident := range incidents {
+		if incident.IsActive(now) && incident.Affects(channelID) {
+			banners = append(banners, IncidentBannerFor(incident))
+		}
+	}
+	return banners, nil
+}
+
+// IncidentBannerFor builds the widget banner of an incident
+func IncidentBannerFor(incident *entity.Incident) entity.IncidentBanner {
+	return entity.IncidentBanner{
+		IncidentID: incident.ID,
+		Kind:       incident.Kind,
+		Title:      incident.Title,
+		Message:    incident.Message,
+		Impact:     incident.Impact,
+		Status:     incident.Status,
+		StartsAt:   incident.StartsAt,
+		EndsAt:     incident.EndsAt,
+	}
+}
+
+// SyncChannelHealth opens an incident for every failing channel of the published pages
+// with automatic incidents, and resolves those incidents once the channel reconnects.
+// It returns the number of incidents opened and resolved.
+func (s *StatusPageService) SyncChannelHealth(ctx context.Context) (int, error) {
+	pages, err := s.repo.ListAutoIncidentPages(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, page := range pages {
+		n, err := s.syncPage(ctx, page)
+		if err != nil {
+			logger.Warn("Failed to sync channel health for status page",
+				zap.String("tenant_id", page.TenantID),
+				zap.Error(err),
+			)
+		}
+		changed += n
+	}
+	return changed, nil
+}
+
+func (s *StatusPageService) syncPage(ctx context.Context, page *entity.StatusPage) (int, error) {
+	channels, err := s.channelRepo.FindEnabledByTenant(ctx, page.TenantID)
+	if err != nil {
+		return 0, err
+	}
+	open, err := s.repo.ListIncidents(ctx, page.TenantID, &entity.IncidentFilter{Unresolved: true})
+	if err != nil {
+		return 0, err
+	}
+
+	// Auto-created incidents track a single channel each
+	autoIncidents := make(map[string]*entity.Incident)
+	for _, incident := range open {
+		if incident.AutoCreated && len(incident.ChannelIDs) == 1 {
+			autoIncidents[incident.ChannelIDs[0]] = incident
+		}
+	}
+
+	changed := 0
+	healthy := make(map[string]bool)
+	for _, channel := range channels {
+		if !page.ShowsChannel(channel.ID) {
+			continue
+		}
+		healthy[channel.ID] = channel.ConnectionStatus != entity.ConnectionStatusError
+		if healthy[channel.ID] || autoIncidents[channel.ID] != nil {
+			continue
+		}
+
+		now := time.Now()
+		incident := &entity.Incident{
+			ID:          uuid.New().String(),
+			TenantID:    page.TenantID,
+			Kind:        entity.IncidentKindIncident,
+			Title:       channel.Name + " is unavailable",
+			Message:     "We are experiencing issues delivering messages on this channel and are investigating.",
+			Impact:      entity.IncidentImpactMajor,
+			Status:      entity.IncidentStatusInvestigating,
+			ChannelIDs:  []string{channel.ID},
+			StartsAt:    now,
+			AutoCreated: true,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		incident.Updates = []entity.IncidentUpdate{{Status: incident.Status, Message: incident.Message, CreatedAt: now}}
+
+		if err := s.repo.CreateIncident(ctx, incident); err != nil {
+			return changed, err
+		}
+		s.incidentChanged(ctx, EventIncidentOpened, incident)
+		changed++
+	}
+
+	for channelID, incident := range autoIncidents {
+		// Channels that were disabled or removed no longer have an outage to track
+		if isHealthy, listed := healthy[channelID]; listed && !isHealthy {
+			continue
+		}
+
+		now := time.Now()
+		incident.Status = entity.IncidentStatusResolved
+		incident.Message = "The channel has recovered and messages are being delivered normally."
+		incident.Updates = append(incident.Updates, entity.IncidentUpdate{Status: incident.Status, Message: incident.Message, CreatedAt: now})
+		incident.ResolvedAt = &now
+		incident.UpdatedAt = now
+
+		if err := s.repo.UpdateIncident(ctx, incident); err != nil {
+			return changed, err
+		}
+		s.incidentChanged(ctx, EventIncidentResolved, incident)
+		changed++
+	}
+
+	return changed, nil
+}
+
+func (s *StatusPageService) incidentChanged(ctx context.Context, eventType string, incident *entity.Incident) {
+	if s.notifier != nil {
+		s.notifier.NotifyIncidentChanged(ctx, incident)
+	}
+	if s.producer == nil {
+		return
+	}
+
+	s.producer.PublishEvent(ctx, &nats.Event{
+		Type:     eventType,
+		TenantID: incident.TenantID,
+		Payload: map[string]interface{}{
+			"incident_id":  incident.ID,
+			"kind":         string(incident.Kind),
+			"title":        incident.Title,
+			"impact":       string(incident.Impact),
+			"status":       string(incident.Status),
+			"channel_ids":  incident.ChannelIDs,
+			"auto_created": incident.AutoCreated,
+		},
+		Timestamp: time.Now(),
+	})
+}
+
+func validateIncident(incident *entity.Incident) error {
+	if incident.Title == "" {
+		return errors.Validation("title is required")
+	}
+	if incident.Kind != entity.IncidentKindIncident && incident.Kind != entity.IncidentKindMaintenance {
+		return errors.Validation("kind must be incident or maintenance")
+	}
+	if !incident.Impact.IsValid() {
+		return errors.Validation("invalid incident impact")
+	}
+	if !incident.Status.IsValid() {
+		return errors.Validation("invalid incident status")
+	}
+	if incident.Kind == entity.IncidentKindMaintenance && incident.EndsAt == nil {
+		return errors.Validation("maintenance windows require ends_at")
+	}
+	if incident.EndsAt != nil && !incident.EndsAt.After(incident.StartsAt) {
+		return errors.Validation("ends_at must be after starts_at")
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockStatusPageRepo struct {
+	pages     map[string]*entity.StatusPage
+	incidents map[string]*entity.Incident
+}
+
+func newMockStatusPageRepo() *mockStatusPageRepo {
+	return &mockStatusPageRepo{
+		pages:     make(map[string]*entity.StatusPage),
+		incidents: make(map[string]*entity.Incident),
+	}
+}
+
+func (m *mockStatusPageRepo) FindByTenant(ctx context.Context, tenantID string) (*entity.StatusPage, error) {
+	if page, ok := m.pages[tenantID]; ok {
+		return page, nil
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "status page not found")
+}
+
+func (m *mockStatusPageRepo) FindBySlug(ctx context.Context, slug string) (*entity.StatusPage, error) {
+	for _, page := range m.pages {
+		if page.Slug == slug {
+			return page, nil
+		}
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "status page not found")
+}
+
+func (m *mockStatusPageRepo) Save(ctx context.Context, page *entity.StatusPage) error {
+	m.pages[page.TenantID] = page
+	return nil
+}
+
+func (m *mockStatusPageRepo) ListAutoIncidentPages(ctx context.Context) ([]*entity.StatusPage, error) {
+	var result []*entity.StatusPage
+	for _, page := range m.pages {
+		if page.IsPublished && page.AutoIncidents {
+			result = append(result, page)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockStatusPageRepo) CreateIncident(ctx context.Context, incident *entity.Incident) error {
+	m.incidents[incident.ID] = incident
+	return nil
+}
+
+func (m *mockStatusPageRepo) FindIncidentByID(ctx context.Context, id string) (*entity.Incident, error) {
+	if incident, ok := m.incidents[id]; ok {
+		return incident, nil
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "incident not found")
+}
+
+func (m *mockStatusPageRepo) ListIncidents(ctx context.Context, tenantID string, filter *entity.IncidentFilter) ([]*entity.Incident, error) {
+	var result []*entity.Incident
+	for _, incident := range m.incidents {
+		if incident.TenantID != tenantID {
+			continue
+		}
+		if filter != nil && filter.Unresolved && incident.IsResolved() {
+			continue
+		}
+		result = append(result, incident)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].StartsAt.After(result[j].StartsAt) })
+	return result, nil
+}
+
+func (m *mockStatusPageRepo) UpdateIncident(ctx context.Context, incident *entity.Incident) error {
+	m.incidents[incident.ID] = incident
+	return nil
+}
+
+func (m *mockStatusPageRepo) DeleteIncident(ctx context.Context, id string) error {
+	delete(m.incidents, id)
+	return nil
+}
+
+type recordingIncidentNotifier struct {
+	incidents []*entity.Incident
+}
+
+func (n *recordingIncidentNotifier) NotifyIncidentChanged(ctx context.Context, incident *entity.Incident) {
+	n.incidents = append(n.incidents, incident)
+}
+
+func newTestStatusPageService(t *testing.T) (*StatusPageService, *mockStatusPageRepo, *testutil.MockChannelRepository) {
+	repo := newMockStatusPageRepo()
+	channelRepo := testutil.NewMockChannelRepository()
+	channelRepo.Channels["chat"] = &entity.Channel{ID: "chat", TenantID: "tenant-1", Name: "Site chat", Type: entity.ChannelTypeWebChat, Enabled: true, ConnectionStatus: entity.ConnectionStatusConnected}
+	channelRepo.Channels["wa"] = &entity.Channel{ID: "wa", TenantID: "tenant-1", Name: "WhatsApp", Type: entity.ChannelTypeWhatsAppOfficial, Enabled: true, ConnectionStatus: entity.ConnectionStatusConnected}
+
+	svc := NewStatusPageService(repo, channelRepo, testutil.NewMockProducer())
+	_, err := svc.SavePage(context.Background(), "tenant-1", &SaveStatusPageInput{
+		Slug:        "acme",
+		Title:       "Acme status",
+		IsPublished: true,
+	})
+	require.NoError(t, err)
+
+	return svc, repo, channelRepo
+}
+
+func TestStatusPageSlugValidation(t *testing.T) {
+	svc, _, _ := newTestStatusPageService(t)
+	ctx := context.Background()
+
+	_, err := svc.SavePage(ctx, "tenant-2", &SaveStatusPageInput{Slug: "Bad Slug", Title: "x"})
+	assert.Error(t, err)
+
+	_, err = svc.SavePage(ctx, "tenant-2", &SaveStatusPageInput{Slug: "acme", Title: "x"})
+	assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code)
+}
+
+func TestPublicStatusFollowsChannelHealthAndIncidents(t *testing.T) {
+	svc, _, channelRepo := newTestStatusPageService(t)
+	ctx := context.Background()
+
+	page, err := svc.GetPublicStatus(ctx, "acme")
+	require.NoError(t, err)
+	assert.Equal(t, entity.ComponentStatusOperational, page.Status)
+	assert.Len(t, page.Components, 2)
+
+	channelRepo.Channels["wa"].ConnectionStatus = entity.ConnectionStatusConnecting
+	_, err = svc.CreateIncident(ctx, "tenant-1", &CreateIncidentInput{
+		Title:      "Slow replies",
+		Impact:     entity.IncidentImpactMajor,
+		ChannelIDs: []string{"chat"},
+	})
+	require.NoError(t, err)
+
+	endsAt := time.Now().Add(3 * time.Hour)
+	startsAt := time.Now().Add(time.Hour)
+	_, err = svc.CreateIncident(ctx, "tenant-1", &CreateIncidentInput{
+		Kind:     entity.IncidentKindMaintenance,
+		Title:    "Database upgrade",
+		StartsAt: &startsAt,
+		EndsAt:   &endsAt,
+	})
+	require.NoError(t, err)
+
+	page, err = svc.GetPublicStatus(ctx, "acme")
+	require.NoError(t, err)
+	assert.Equal(t, entity.ComponentStatusPartialOutage, page.Status)
+	assert.Len(t, page.ActiveIncidents, 1)
+	require.Len(t, page.UpcomingMaintenance, 1)
+	assert.Equal(t, entity.IncidentStatusScheduled, page.UpcomingMaintenance[0].Status)
+
+	statuses := map[string]entity.ComponentStatus{}
+	for _, component := range page.Components {
+		statuses[component.ChannelID] = component.Status
+	}
+	assert.Equal(t, entity.ComponentStatusPartialOutage, statuses["chat"])
+	assert.Equal(t, entity.ComponentStatusDegraded, statuses["wa"])
+}
+
+func TestUnpublishedStatusPageIsHidden(t *testing.T) {
+	svc, _, _ := newTestStatusPageService(t)
+	ctx := context.Background()
+
+	_, err := svc.SavePage(ctx, "tenant-1", &SaveStatusPageInput{Slug: "acme", Title: "Acme status"})
+	require.NoError(t, err)
+
+	_, err = svc.GetPublicStatus(ctx, "acme")
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestIncidentBannersFollowIncidentLifecycle(t *testing.T) {
+	svc, _, _ := newTestStatusPageService(t)
+	notifier := &recordingIncidentNotifier{}
+	svc.SetNotifier(notifier)
+	ctx := context.Background()
+
+	incident, err := svc.CreateIncident(ctx, "tenant-1", &CreateIncidentInput{
+		Title:      "Chat outage",
+		Message:    "Messages are delayed",
+		ChannelIDs: []string{"chat"},
+	})
+	require.NoError(t, err)
+
+	banners, err := svc.ActiveBanners(ctx, "tenant-1", "chat")
+	require.NoError(t, err)
+	require.Len(t, banners, 1)
+	assert.Equal(t, "Messages are delayed", banners[0].Message)
+
+	banners, err = svc.ActiveBanners(ctx, "tenant-1", "wa")
+	require.NoError(t, err)
+	assert.Empty(t, banners)
+
+	_, err = svc.UpdateIncident(ctx, "tenant-1", incident.ID, &UpdateIncidentInput{
+		Status:  entity.IncidentStatusResolved,
+		Message: "Fixed",
+	})
+	require.NoError(t, err)
+
+	banners, err = svc.ActiveBanners(ctx, "tenant-1", "chat")
+	require.NoError(t, err)
+	assert.Empty(t, banners)
+
+	require.Len(t, notifier.incidents, 2)
+	assert.True(t, notifier.incidents[1].IsResolved())
+	assert.Len(t, notifier.incidents[1].Updates, 2)
+
+	_, err = svc.UpdateIncident(ctx, "tenant-1", incident.ID, &UpdateIncidentInput{
+		Status:  entity.IncidentStatusMonitoring,
+		Message: "Reopen",
+	})
+	assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code)
+}
+
+func TestMaintenanceRequiresEnd(t *testing.T) {
+	svc, _, _ := newTestStatusPageService(t)
+
+	_, err := svc.CreateIncident(context.Background(), "tenant-1", &CreateIncidentInput{
+		Kind:  entity.IncidentKindMaintenance,
+		Title: "Upgrade",
+	})
+	assert.Error(t, err)
+}
+
+func TestSyncChannelHealthOpensAndResolvesIncidents(t *testing.T) {
+	svc, repo, channelRepo := newTestStatusPageService(t)
+	ctx := context.Background()
+
+	channelRepo.Channels["wa"].ConnectionStatus = entity.ConnectionStatusError
+
+	changed, err := svc.SyncChannelHealth(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, changed)
+
+	// A second sync does not duplicate the open incident
+	changed, err = svc.SyncChannelHealth(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, changed)
+
+	require.Len(t, repo.incidents, 1)
+	var incident *entity.Incident
+	for _, i := range repo.incidents {
+		incident = i
+	}
+	assert.True(t, incident.AutoCreated)
+	assert.Equal(t, []string{"wa"}, incident.ChannelIDs)
+
+	channelRepo.Channels["wa"].ConnectionStatus = entity.ConnectionStatusConnected
+	changed, err = svc.SyncChannelHealth(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, changed)
+	assert.True(t, incident.IsResolved())
+	assert.NotNil(t, incident.ResolvedAt)
+}
@@ -0,0 +1,383 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	minStreamRetentionHours = 1
+	maxStreamRetentionHours = 30 * 24
+	minStreamMaxMessages    = 10000
+	maxStreamMaxMessages    = 100000000
+
+	maxStreamReplayWindow   = 24 * time.Hour
+	defaultStreamReplaySize = 1000
+	maxStreamReplaySize     = 10000
+
+	defaultDeadLetterPageSize   = 50
+	maxDeadLetterPageSize       = 500
+	defaultDeadLetterReplaySize = 100
+	maxDeadLetterReplaySize     = 1000
+	maxDeadLetterReplayedBy     = 255
+)
+
+var streamReplayGroupPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// StreamManager reads and tunes the limits of NATS streams and replays their messages
+type StreamManager interface {
+	StreamSettings(ctx context.Context, streamName string) (*entity.StreamSettings, error)
+	UpdateStreamSettings(ctx context.Context, streamName string, update *entity.StreamSettingsUpdate) (*entity.StreamSettings, error)
+	Replay(ctx context.Context, streamName string, req *entity.StreamReplayRequest) (*entity.StreamReplayResult, error)
+	DeadLetters(ctx context.Context, streamName string, after uint64, limit int) ([]*entity.DeadLetter, error)
+	DeadLetter(ctx context.Context, streamName string, seq uint64) (*entity.DeadLetter, error)
+	ReplayDeadLetter(ctx context.Context, streamName string, seq uint64) (*entity.DeadLetter, error)
+}
+
+// StreamAdminService lets system administrators tune the retention of the message
+// streams within safe bounds, replay a time range of a stream into a test consumer
+// group when debugging pipeline changes, and browse and replay the dead letters
+// consumers gave up on
+type StreamAdminService struct {
+	streams StreamManager
+	replays repository.DeadLetterReplayRepository
+	now     func() time.Time
+}
+
+// NewStreamAdminService creates a new stream admin service
+func NewStreamAdminService(streams StreamManager) *StreamAdminService {
+	return &StreamAdminService{
+		streams: streams,
+		now:     time.Now,
+	}
+}
+
+// SetDeadLetterReplays sets where dead letter replays are audited
+func (s *StreamAdminService) SetDeadLetterReplays(replays repository.DeadLetterReplayRepository) {
+	s.replays = replays
+}
+
+// List returns the settings of every tunable stream that exists
+func (s *StreamAdminService) List(ctx context.Context) ([]*entity.StreamSettings, error) {
+	names := make([]string, 0, len(nats.TunableStreams))
+	for name := range nats.TunableStreams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]*entity.StreamSettings, 0, len(names))
+	for _, name := range names {
+		settings, err := s.streams.StreamSettings(ctx, nats.TunableStreams[name])
+		if err != nil {
+			// Streams of optional consumers, like the AI one, may not exist
+			logger.Warn("Failed to read stream settings", zap.String("stream", name), zap.Error(err))
+			continue
+		}
+		settings.Stream = name
+		result = append(result, settings)
+	}
+	return result, nil
+}
+
+// Get returns the settings of a stream
+func (s *StreamAdminService) Get(ctx context.Context, stream string) (*entity.StreamSettings, error) {
+	streamName, err := tunableStream(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := s.streams.StreamSettings(ctx, streamName)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to read stream settings")
+	}
+	settings.Stream = stream
+	return settings, nil
+}
+
+// Update changes the retention window, message limit or replicas of a stream.
+// Lowering the window or the limit deletes messages, so it must be confirmed.
+func (s *StreamAdminService) Update(ctx context.Context, stream string, update *entity.StreamSettingsUpdate) (*entity.StreamSettings, error) {
+	if update.RetentionHours == nil && update.MaxMessages == nil && update.Replicas == nil {
+		return nil, errors.Validation("retention_hours, max_messages or replicas is required")
+	}
+	if hours := update.RetentionHours; hours != nil && (*hours < minStreamRetentionHours || *hours > maxStreamRetentionHours) {
+		return nil, errors.Validation(fmt.Sprintf("retention_hours must be between %d and %d", minStreamRetentionHours, maxStreamRetentionHours))
+	}
+	if limit := update.MaxMessages; limit != nil && *limit != -1 && (*limit < minStreamMaxMessages || *limit > maxStreamMaxMessages) {
+		return nil, errors.Validation(fmt.Sprintf("max_messages must be -1 for no limit or between %d and %d", minStreamMaxMessages, maxStreamMaxMessages))
+	}
+	if replicas := update.Replicas; replicas != nil && *replicas != 1 && *replicas != 3 && *replicas != 5 {
+		return nil, errors.Validation("replicas must be 1, 3 or 5")
+	}
+
+	current, err := s.Get(ctx, stream)
+	if err != nil {
+		return nil, err
+	}
+	if !update.ConfirmDataLoss && losesMessages(current, update) {
+		return nil, errors.Validation("lowering retention_hours or max_messages deletes the messages beyond the new limit, set confirm_data_loss to proceed")
+	}
+
+	settings, err := s.streams.UpdateStreamSettings(ctx, current.Name, update)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeBadRequest, "NATS rejected the stream settings: "+err.Error())
+	}
+	settings.Stream = stream
+
+	logger.Info("Stream settings updated",
+		zap.String("stream", stream),
+		zap.Int("retention_hours", settings.RetentionHours),
+		zap.Int64("max_messages", settings.MaxMessages),
+		zap.Int("replicas", settings.Replicas),
+	)
+	return settings, nil
+}
+
+// Replay copies the messages a stream still holds from a time range into a replay
+// consumer group. Test consumers read the copies from the group's durable consumer
+// while the live pipeline keeps running untouched.
+func (s *StreamAdminService) Replay(ctx context.Context, stream string, req *entity.StreamReplayRequest) (*entity.StreamReplayResult, error) {
+	streamName, err := tunableStream(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	if !streamReplayGroupPattern.MatchString(req.Group) {
+		return nil, errors.Validation("group must be 1 to 32 lowercase letters, digits or dashes")
+	}
+	if now := s.now(); req.To.After(now) {
+		req.To = now
+	}
+	if !req.From.Before(req.To) {
+		return nil, errors.Validation("from must be before to")
+	}
+	if req.To.Sub(req.From) > maxStreamReplayWindow {
+		return nil, errors.Validation(fmt.Sprintf("the replayed range must be at most %s", maxStreamReplayWindow))
+	}
+	if req.Subject != "" && (!strings.HasPrefix(req.Subject, "linktor.") || strings.ContainsAny(req.Subject, " \t\r\n")) {
+		return nil, errors.Validation("subject must be a linktor subject")
+	}
+	if req.MaxMessages <= 0 {
+		req.MaxMessages = defaultStreamReplaySize
+	}
+	if req.MaxMessages > maxStreamReplaySize {
+		return nil, errors.Validation(fmt.Sprintf("max_messages must be at most %d", maxStreamReplaySize))
+	}
+
+	result, err := s.streams.Replay(ctx, streamName, req)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to replay stream")
+	}
+
+	logger.Info("Stream replayed",
+		zap.String("stream", stream),
+		zap.String("group", req.Group),
+		zap.Time("from", req.From),
+		zap.Time("to", req.To),
+		zap.Int("copied", result.Copied),
+	)
+	return result, nil
+}
+
+// DeadLetters lists the dead letters of a stream with a sequence above after, oldest
+// first, without their payloads
+func (s *StreamAdminService) DeadLetters(ctx context.Context, stream string, after uint64, limit int) ([]*entity.DeadLetter, error) {
+	streamName, err := tunableStream(stream)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = defaultDeadLetterPageSize
+	}
+	if limit > maxDeadLetterPageSize {
+		limit = maxDeadLetterPageSize
+	}
+
+	letters, err := s.streams.DeadLetters(ctx, streamName, after, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list dead letters")
+	}
+	return letters, nil
+}
+
+// DeadLetter returns a dead letter of a stream with its headers and payload
+func (s *StreamAdminService) DeadLetter(ctx context.Context, stream string, seq uint64) (*entity.DeadLetter, error) {
+	streamName, err := tunableStream(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	letter, err := s.streams.DeadLetter(ctx, streamName, seq)
+	if err != nil {
+		return nil, deadLetterError(err, "failed to read dead letter")
+	}
+	return letter, nil
+}
+
+// ReplayDeadLetter publishes a dead letter again on its original subject and
+// audits who replayed it
+func (s *StreamAdminService) ReplayDeadLetter(ctx context.Context, stream string, seq uint64, req *entity.DeadLetterReplayRequest) (*entity.DeadLetterReplay, error) {
+	streamName, err := tunableStream(stream)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateDeadLetterReplay(req); err != nil {
+		return nil, err
+	}
+	return s.replayDeadLetter(ctx, stream, streamName, seq, req)
+}
+
+// ReplayDeadLetters replays the listed dead letters of a stream, or all of them up
+// to the limit, auditing each one. A dead letter failing to replay does not stop
+// the others.
+func (s *StreamAdminService) ReplayDeadLetters(ctx context.Context, stream string, req *entity.DeadLetterReplayRequest) (*entity.DeadLetterReplayResult, error) {
+	streamName, err := tunableStream(stream)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateDeadLetterReplay(req); err != nil {
+		return nil, err
+	}
+	if req.MaxMessages <= 0 {
+		req.MaxMessages = defaultDeadLetterReplaySize
+	}
+	if req.MaxMessages > maxDeadLetterReplaySize {
+		return nil, errors.Validation(fmt.Sprintf("max_messages must be at most %d", maxDeadLetterReplaySize))
+	}
+
+	result := &entity.DeadLetterReplayResult{Stream: stream, Replayed: make([]*entity.DeadLetterReplay, 0)}
+	sequences := req.Sequences
+	if len(sequences) == 0 {
+		letters, err := s.streams.DeadLetters(ctx, streamName, 0, req.MaxMessages+1)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list dead letters")
+		}
+		for _, letter := range letters {
+			sequences = append(sequences, letter.Sequence)
+		}
+	}
+	if len(sequences) > req.MaxMessages {
+		sequences = sequences[:req.MaxMessages]
+		result.Truncated = true
+	}
+
+	for _, seq := range sequences {
+		replay, err := s.replayDeadLetter(ctx, stream, streamName, seq, req)
+		if err != nil {
+			result.Failed = append(result.Failed, seq)
+			continue
+		}
+		result.Replayed = append(result.Replayed, replay)
+	}
+	return result, nil
+}
+
+// DeadLetterReplays lists the latest audited replays of a stream's dead letters
+func (s *StreamAdminService) DeadLetterReplays(ctx context.Context, stream string, limit int) ([]*entity.DeadLetterReplay, error) {
+	if _, err := tunableStream(stream); err != nil {
+		return nil, err
+	}
+	if s.replays == nil {
+		return nil, errors.New(errors.ErrCodeInternal, "dead letter replays are not audited")
+	}
+	if limit <= 0 {
+		limit = defaultDeadLetterPageSize
+	}
+	if limit > maxDeadLetterPageSize {
+		limit = maxDeadLetterPageSize
+	}
+	return s.replays.ListByStream(ctx, stream, limit)
+}
+
+// replayDeadLetter replays a dead letter and records the replay. A dead letter
+// replayed but left in the dead-letter stream is still audited, as it was delivered.
+func (s *StreamAdminService) replayDeadLetter(ctx context.Context, stream, streamName string, seq uint64, req *entity.DeadLetterReplayRequest) (*entity.DeadLetterReplay, error) {
+	letter, err := s.streams.ReplayDeadLetter(ctx, streamName, seq)
+	if letter == nil {
+		logger.Warn("Failed to replay dead letter", zap.String("stream", stream), zap.Uint64("sequence", seq), zap.Error(err))
+		return nil, deadLetterError(err, "failed to replay dead letter")
+	}
+
+	replay := &entity.DeadLetterReplay{
+		ID:                 uuid.New().String(),
+		Stream:             stream,
+		Subject:            letter.Subject,
+		DeadLetterSequence: seq,
+		OriginalSequence:   letter.OriginalSequence,
+		Consumer:           letter.Consumer,
+		Error:              letter.Error,
+		ReplayedBy:         strings.TrimSpace(req.ReplayedBy),
+		Reason:             strings.TrimSpace(req.Reason),
+		ReplayedAt:         s.now(),
+	}
+	logger.Info("Dead letter replayed",
+		zap.String("stream", stream),
+		zap.Uint64("sequence", seq),
+		zap.String("subject", replay.Subject),
+		zap.String("replayed_by", replay.ReplayedBy),
+	)
+	if s.replays != nil {
+		if auditErr := s.replays.Create(ctx, replay); auditErr != nil {
+			logger.Error("Failed to audit dead letter replay", zap.String("stream", stream), zap.Uint64("sequence", seq), zap.Error(auditErr))
+		}
+	}
+
+	if err != nil {
+		logger.Warn("Replayed dead letter was not removed", zap.String("stream", stream), zap.Uint64("sequence", seq), zap.Error(err))
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "the dead letter was replayed but is still listed; do not replay it again")
+	}
+	return replay, nil
+}
+
+// validateDeadLetterReplay checks that an operator named themselves for the audit
+func validateDeadLetterReplay(req *entity.DeadLetterReplayRequest) error {
+	replayedBy := strings.TrimSpace(req.ReplayedBy)
+	if replayedBy == "" {
+		return errors.Validation("replayed_by is required")
+	}
+	if len(replayedBy) > maxDeadLetterReplayedBy {
+		return errors.Validation(fmt.Sprintf("replayed_by must be at most %d characters", maxDeadLetterReplayedBy))
+	}
+	return nil
+}
+
+// deadLetterError maps a dead letter the stream does not hold to a not found error
+func deadLetterError(err error, message string) error {
+	if stderrors.Is(err, nats.ErrDeadLetterNotFound) {
+		return errors.NotFound("dead letter")
+	}
+	return errors.Wrap(err, errors.ErrCodeInternal, message)
+}
+
+// tunableStream returns the NATS stream of a logical stream name
+func tunableStream(stream string) (string, error) {
+	streamName, ok := nats.TunableStreams[stream]
+	if !ok {
+		return "", errors.NotFound("stream")
+	}
+	return streamName, nil
+}
+
+// losesMessages reports whether an update lowers a limit of the stream
+func losesMessages(current *entity.StreamSettings, update *entity.StreamSettingsUpdate) bool {
+	if hours := update.RetentionHours; hours != nil && (current.RetentionHours == 0 || *hours < current.RetentionHours) {
+		return true
+	}
+	if limit := update.MaxMessages; limit != nil && *limit != -1 && (current.MaxMessages == -1 || *limit < current.MaxMessages) {
+		return true
+	}
+	return false
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStreamManager struct {
+	mu          sync.Mutex
+	settings    map[string]*entity.StreamSettings
+	replays     []*entity.StreamReplayRequest
+	deadLetters map[uint64]*entity.DeadLetter
+}
+
+func newFakeStreamManager() *fakeStreamManager {
+	return &fakeStreamManager{settings: map[string]*entity.StreamSettings{
+		nats.StreamMessages: {Name: nats.StreamMessages, Retention: "workqueue", RetentionHours: 168, MaxMessages: -1, Replicas: 1},
+		nats.StreamEvents:   {Name: nats.StreamEvents, Retention: "interest", RetentionHours: 24, MaxMessages: 50000, Replicas: 1},
+	}}
+}
+
+func (m *fakeStreamManager) StreamSettings(ctx context.Context, streamName string) (*entity.StreamSettings, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	settings, ok := m.settings[streamName]
+	if !ok {
+		return nil, errors.NotFound("stream")
+	}
+	copied := *settings
+	return &copied, nil
+}
+
+func (m *fakeStreamManager) UpdateStreamSettings(ctx context.Context, streamName string, update *entity.StreamSettingsUpdate) (*entity.StreamSettings, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	settings := m.settings[streamName]
+	if update.RetentionHours != nil {
+		settings.RetentionHours = *update.RetentionHours
+	}
+	if update.MaxMessages != nil {
+		settings.MaxMessages = *update.MaxMessages
+	}
+	if update.Replicas != nil {
+		settings.Replicas = *update.Replicas
+	}
+	copied := *settings
+	return &copied, nil
+}
+
+func (m *fakeStreamManager) Replay(ctx context.Context, streamName string, req *entity.StreamReplayRequest) (*entity.StreamReplayResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.replays = append(m.replays, req)
+	return &entity.StreamReplayResult{Stream: streamName, Group: req.Group, Consumer: nats.ConsumerReplay(req.Group), Copied: 3}, nil
+}
+
+func (m *fakeStreamManager) DeadLetters(ctx context.Context, streamName string, after uint64, limit int) ([]*entity.DeadLetter, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var letters []*entity.DeadLetter
+	for seq := after + 1; seq <= 100 && len(letters) < limit; seq++ {
+		if letter, ok := m.deadLetters[seq]; ok && nats.TunableStreams[letter.Stream] == streamName {
+			letters = append(letters, letter)
+		}
+	}
+	return letters, nil
+}
+
+func (m *fakeStreamManager) DeadLetter(ctx context.Context, streamName string, seq uint64) (*entity.DeadLetter, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	letter, ok := m.deadLetters[seq]
+	if !ok || nats.TunableStreams[letter.Stream] != streamName {
+		return nil, nats.ErrDeadLetterNotFound
+	}
+	return letter, nil
+}
+
+func (m *fakeStreamManager) ReplayDeadLetter(ctx context.Context, streamName string, seq uint64) (*entity.DeadLetter, error) {
+	letter, err := m.DeadLetter(ctx, streamName, seq)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.deadLetters, seq)
+	return letter, nil
+}
+
+type mockDeadLetterReplayRepo struct {
+	replays []*entity.DeadLetterReplay
+}
+
+func (m *mockDeadLetterReplayRepo) Create(ctx context.Context, replay *entity.DeadLetterReplay) error {
+	m.replays = append(m.replays, replay)
+	return nil
+}
+
+func (m *mockDeadLetterReplayRepo) ListByStream(ctx context.Context, stream string, limit int) ([]*entity.DeadLetterReplay, error) {
+	var replays []*entity.DeadLetterReplay
+	for i := len(m.replays) - 1; i >= 0 && len(replays) < limit; i-- {
+		if m.replays[i].Stream == stream {
+			replays = append(replays, m.replays[i])
+		}
+	}
+	return replays, nil
+}
+
+func intPtr(v int) *int       { return &v }
+func int64Ptr(v int64) *int64 { return &v }
+
+func TestStreamAdminService_List(t *testing.T) {
+	svc := NewStreamAdminService(newFakeStreamManager())
+
+	settings, err := svc.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, settings, 2)
+	assert.Equal(t, "events", settings[0].Stream)
+	assert.Equal(t, "messages", settings[1].Stream)
+}
+
+func TestStreamAdminService_Update(t *testing.T) {
+	manager := newFakeStreamManager()
+	svc := NewStreamAdminService(manager)
+	ctx := context.Background()
+
+	settings, err := svc.Update(ctx, "events", &entity.StreamSettingsUpdate{RetentionHours: intPtr(72), Replicas: intPtr(3)})
+	require.NoError(t, err)
+	assert.Equal(t, "events", settings.Stream)
+	assert.Equal(t, 72, settings.RetentionHours)
+	assert.Equal(t, 3, settings.Replicas)
+	assert.Equal(t, int64(50000), settings.MaxMessages)
+
+	_, err = svc.Update(ctx, "events", &entity.StreamSettingsUpdate{MaxMessages: int64Ptr(20000)})
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeValidation, errors.GetAppError(err).Code)
+	assert.Equal(t, int64(50000), manager.settings[nats.StreamEvents].MaxMessages)
+
+	settings, err = svc.Update(ctx, "events", &entity.StreamSettingsUpdate{MaxMessages: int64Ptr(20000), ConfirmDataLoss: true})
+	require.NoError(t, err)
+	assert.Equal(t, int64(20000), settings.MaxMessages)
+
+	_, err = svc.Update(ctx, "messages", &entity.StreamSettingsUpdate{MaxMessages: int64Ptr(1000000)})
+	assert.Equal(t, errors.ErrCodeValidation, errors.GetAppError(err).Code, "a limit on an unlimited stream loses messages")
+}
+
+func TestStreamAdminService_UpdateGuardrails(t *testing.T) {
+	svc := NewStreamAdminService(newFakeStreamManager())
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		stream string
+		update *entity.StreamSettingsUpdate
+		code   errors.ErrorCode
+	}{
+		{"nothing to change", "events", &entity.StreamSettingsUpdate{}, errors.ErrCodeValidation},
+		{"retention too short", "events", &entity.StreamSettingsUpdate{RetentionHours: intPtr(0), ConfirmDataLoss: true}, errors.ErrCodeValidation},
+		{"retention too long", "events", &entity.StreamSettingsUpdate{RetentionHours: intPtr(24 * 60)}, errors.ErrCodeValidation},
+		{"too few messages", "events", &entity.StreamSettingsUpdate{MaxMessages: int64Ptr(10), ConfirmDataLoss: true}, errors.ErrCodeValidation},
+		{"even replicas", "events", &entity.StreamSettingsUpdate{Replicas: intPtr(2)}, errors.ErrCodeValidation},
+		{"unknown stream", "replay", &entity.StreamSettingsUpdate{Replicas: intPtr(1)}, errors.ErrCodeNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Update(ctx, tt.stream, tt.update)
+			require.Error(t, err)
+			assert.Equal(t, tt.code, errors.GetAppError(err).Code)
+		})
+	}
+}
+
+func TestStreamAdminService_Replay(t *testing.T) {
+	manager := newFakeStreamManager()
+	svc := NewStreamAdminService(manager)
+	now := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	result, err := svc.Replay(ctx, "messages", &entity.StreamReplayRequest{
+		Group: "router-v2",
+		From:  now.Add(-2 * time.Hour),
+		To:    now.Add(time.Hour),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "replay-router-v2", result.Consumer)
+	assert.Equal(t, 3, result.Copied)
+
+	require.Len(t, manager.replays, 1)
+	assert.Equal(t, now, manager.replays[0].To, "the range ends now at the latest")
+	assert.Equal(t, defaultStreamReplaySize, manager.replays[0].MaxMessages)
+
+	invalid := []*entity.StreamReplayRequest{
+		{Group: "Router V2", From: now.Add(-time.Hour), To: now},
+		{Group: "router-v2", From: now, To: now.Add(-time.Hour)},
+		{Group: "router-v2", From: now.Add(-48 * time.Hour), To: now},
+		{Group: "router-v2", From: now.Add(-time.Hour), To: now, Subject: "other.subject"},
+		{Group: "router-v2", From: now.Add(-time.Hour), To: now, MaxMessages: maxStreamReplaySize + 1},
+	}
+	for _, req := range invalid {
+		_, err := svc.Replay(ctx, "messages", req)
+		require.Error(t, err)
+		assert.Equal(t, errors.ErrCodeValidation, errors.GetAppError(err).Code)
+	}
+	assert.Len(t, manager.replays, 1)
+}
+
+func newDeadLetterTestService() (*StreamAdminService, *fakeStreamManager, *mockDeadLetterReplayRepo) {
+	manager := newFakeStreamManager()
+	manager.deadLetters = map[uint64]*entity.DeadLetter{
+		1: {Sequence: 1, Stream: "webhooks", Subject: "linktor.webhooks.tenant-1", OriginalSequence: 40, Consumer: nats.ConsumerWebhooks, Error: "endpoint returned 500"},
+		2: {Sequence: 2, Stream: "messages", Subject: "linktor.messages.outbound.whatsapp", OriginalSequence: 12, Consumer: "outbound-whatsapp"},
+		3: {Sequence: 3, Stream: "webhooks", Subject: "linktor.webhooks.tenant-2", OriginalSequence: 41, Consumer: nats.ConsumerWebhooks},
+	}
+	replays := &mockDeadLetterReplayRepo{}
+	svc := NewStreamAdminService(manager)
+	svc.SetDeadLetterReplays(replays)
+	return svc, manager, replays
+}
+
+func TestStreamAdminService_DeadLetters(t *testing.T) {
+	svc, _, _ := newDeadLetterTestService()
+	ctx := context.Background()
+
+	letters, err := svc.DeadLetters(ctx, "webhooks", 0, 0)
+	require.NoError(t, err)
+	require.Len(t, letters, 2)
+	assert.Equal(t, uint64(1), letters[0].Sequence)
+
+	letters, err = svc.DeadLetters(ctx, "webhooks", 1, 10)
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	assert.Equal(t, uint64(3), letters[0].Sequence)
+
+	_, err = svc.DeadLetter(ctx, "webhooks", 2)
+	assert.Equal(t, errors.ErrCodeNotFound, errors.GetAppError(err).Code, "dead letters of other streams are not found")
+}
+
+func TestStreamAdminService_ReplayDeadLetter(t *testing.T) {
+	svc, manager, replays := newDeadLetterTestService()
+	now := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, err := svc.ReplayDeadLetter(ctx, "webhooks", 1, &entity.DeadLetterReplayRequest{ReplayedBy: "  "})
+	assert.Equal(t, errors.ErrCodeValidation, errors.GetAppError(err).Code, "replays must name who replays")
+
+	replay, err := svc.ReplayDeadLetter(ctx, "webhooks", 1, &entity.DeadLetterReplayRequest{ReplayedBy: "ops@example.com", Reason: "endpoint fixed"})
+	require.NoError(t, err)
+	assert.Equal(t, "linktor.webhooks.tenant-1", replay.Subject)
+	assert.Equal(t, uint64(40), replay.OriginalSequence)
+	assert.Equal(t, "endpoint returned 500", replay.Error)
+	assert.Equal(t, now, replay.ReplayedAt)
+	assert.NotContains(t, manager.deadLetters, uint64(1))
+
+	require.Len(t, replays.replays, 1)
+	assert.Equal(t, "ops@example.com", replays.replays[0].ReplayedBy)
+	assert.Equal(t, "endpoint fixed", replays.replays[0].Reason)
+
+	_, err = svc.ReplayDeadLetter(ctx, "webhooks", 1, &entity.DeadLetterReplayRequest{ReplayedBy: "ops@example.com"})
+	assert.Equal(t, errors.ErrCodeNotFound, errors.GetAppError(err).Code)
+	assert.Len(t, replays.replays, 1)
+}
+
+func TestStreamAdminService_ReplayDeadLetters(t *testing.T) {
+	svc, manager, replays := newDeadLetterTestService()
+	ctx := context.Background()
+
+	result, err := svc.ReplayDeadLetters(ctx, "webhooks", &entity.DeadLetterReplayRequest{ReplayedBy: "ops@example.com", Sequences: []uint64{3, 2}})
+	require.NoError(t, err)
+	require.Len(t, result.Replayed, 1)
+	assert.Equal(t, uint64(3), result.Replayed[0].DeadLetterSequence)
+	assert.Equal(t, []uint64{2}, result.Failed, "a dead letter of another stream is not replayed")
+
+	manager.deadLetters[4] = &entity.DeadLetter{Sequence: 4, Stream: "webhooks", Subject: "linktor.webhooks.tenant-3"}
+	manager.deadLetters[5] = &entity.DeadLetter{Sequence: 5, Stream: "webhooks", Subject: "linktor.webhooks.tenant-4"}
+	result, err = svc.ReplayDeadLetters(ctx, "webhooks", &entity.DeadLetterReplayRequest{ReplayedBy: "ops@example.com", MaxMessages: 2})
+	require.NoError(t, err)
+	assert.Len(t, result.Replayed, 2)
+	assert.True(t, result.Truncated)
+	assert.Contains(t, manager.deadLetters, uint64(5))
+
+	audit, err := svc.DeadLetterReplays(ctx, "webhooks", 0)
+	require.NoError(t, err)
+	require.Len(t, audit, 3)
+	assert.Equal(t, uint64(4), audit[0].DeadLetterSequence, "newest first")
+	assert.Len(t, replays.replays, 3)
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+const (
+	defaultSyncLimit = 100
+	maxSyncLimit     = 500
+
+	// syncSettleWindow holds back changes this recent, so a write that commits after a
+	// sync read is not skipped by a cursor that already moved past its timestamp
+	syncSettleWindow = 2 * time.Second
+
+	// A first sync without cursor starts with the conversations and messages that
+	// changed within these windows; older history is loaded per conversation
+	syncConversationBootstrap = 30 * 24 * time.Hour
+	syncMessageBootstrap      = 24 * time.Hour
+)
+
+// PresenceFeed reports agent presence changes
+type PresenceFeed interface {
+	PresenceChangedSince(tenantID string, since time.Time) []*entity.AgentPresence
+}
+
+// syncCursor is the decoded position of a client in each kind of change
+type syncCursor struct {
+	Conversations entity.SyncPosition `json:"c"`
+	Messages      entity.SyncPosition `json:"m"`
+	Presence      time.Time           `json:"p"`
+}
+
+// SyncService serves delta sync for agents on mobile connections: instead of reloading
+// full lists, a client passes the cursor of its last sync and receives only the
+// conversations, messages and agent presence that changed since, in compact shapes.
+type SyncService struct {
+	syncRepo repository.SyncRepository
+	presence PresenceFeed
+	now      func() time.Time
+}
+
+// NewSyncService creates a new sync service
+func NewSyncService(syncRepo repository.SyncRepository, presence PresenceFeed) *SyncService {
+	return &SyncService{
+		syncRepo: syncRepo,
+		presence: presence,
+		now:      time.Now,
+	}
+}
+
+// Changes returns the changes of a tenant since a cursor, at most limit of each kind.
+// An empty cursor starts a first sync with recent conversations and messages.
+func (s *SyncService) Changes(ctx context.Context, tenantID, cursor string, limit int) (*entity.SyncChanges, error) {
+	if limit <= 0 {
+		limit = defaultSyncLimit
+	}
+	if limit > maxSyncLimit {
+		return nil, errors.Validation("limit must be at most 500")
+	}
+
+	now := s.now().UTC()
+	until := now.Add(-syncSettleWindow)
+
+	var position syncCursor
+	if cursor == "" {
+		position.Conversations.At = now.Add(-syncConversationBootstrap)
+		position.Messages.At = now.Add(-syncMessageBootstrap)
+	} else {
+		decoded, err := decodeSyncCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		position = *decoded
+	}
+
+	// One extra record tells whether there is more to read
+	conversations, err := s.syncRepo.ConversationsChangedSince(ctx, tenantID, position.Conversations, until, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := s.syncRepo.MessagesChangedSince(ctx, tenantID, position.Messages, until, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := &entity.SyncChanges{
+		Conversations: conversations,
+		Messages:      messages,
+		Presence:      make([]*entity.SyncPresence, 0),
+	}
+	if len(changes.Conversations) > limit {
+		changes.Conversations = changes.Conversations[:limit]
+		changes.HasMore = true
+	}
+	if len(changes.Messages) > limit {
+		changes.Messages = changes.Messages[:limit]
+		changes.HasMore = true
+	}
+	if n := len(changes.Conversations); n > 0 {
+		last := changes.Conversations[n-1]
+		position.Conversations = entity.SyncPosition{At: last.UpdatedAt, ID: last.ID}
+	}
+	if n := len(changes.Messages); n > 0 {
+		last := changes.Messages[n-1]
+		position.Messages = entity.SyncPosition{At: last.UpdatedAt, ID: last.ID}
+	}
+
+	// Presence lives in memory and is small, so it is sent whole up to now
+	if s.presence != nil {
+		for _, presence := range s.presence.PresenceChangedSince(tenantID, position.Presence) {
+			changes.Presence = append(changes.Presence, &entity.SyncPresence{
+				UserID:    presence.UserID,
+				Status:    presence.Status,
+				ChangedAt: presence.UpdatedAt,
+			})
+		}
+		position.Presence = now
+	}
+
+	encoded, err := encodeSyncCursor(&position)
+	if err != nil {
+		return nil, err
+	}
+	changes.Cursor = encoded
+	return changes, nil
+}
+
+func encodeSyncCursor(cursor *syncCursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", errors.Wrap(err, errors.ErrCodeInternal, "failed to encode sync cursor")
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeSyncCursor(cursor string) (*syncCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errors.Validation("invalid cursor")
+	}
+	var decoded syncCursor
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, errors.Validation("invalid cursor")
+	}
+	return &decoded, nil
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSyncRepo struct {
+	mu            sync.Mutex
+	conversations []*entity.SyncConversation
+	messages      []*entity.SyncMessage
+}
+
+func syncAfter(at time.Time, id string, since entity.SyncPosition) bool {
+	return at.After(since.At) || (at.Equal(since.At) && id > since.ID)
+}
+
+func (r *fakeSyncRepo) ConversationsChangedSince(ctx context.Context, tenantID string, since entity.SyncPosition, until time.Time, limit int) ([]*entity.SyncConversation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sort.Slice(r.conversations, func(i, j int) bool {
+		a, b := r.conversations[i], r.conversations[j]
+		return a.UpdatedAt.Before(b.UpdatedAt) || (a.UpdatedAt.Equal(b.UpdatedAt) && a.ID < b.ID)
+	})
+	var result []*entity.SyncConversation
+	for _, c := range r.conversations {
+		if syncAfter(c.UpdatedAt, c.ID, since) && !c.UpdatedAt.After(until) && len(result) < limit {
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+func (r *fakeSyncRepo) MessagesChangedSince(ctx context.Context, tenantID string, since entity.SyncPosition, until time.Time, limit int) ([]*entity.SyncMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sort.Slice(r.messages, func(i, j int) bool {
+		a, b := r.messages[i], r.messages[j]
+		return a.UpdatedAt.Before(b.UpdatedAt) || (a.UpdatedAt.Equal(b.UpdatedAt) && a.ID < b.ID)
+	})
+	var result []*entity.SyncMessage
+	for _, m := range r.messages {
+		if syncAfter(m.UpdatedAt, m.ID, since) && !m.UpdatedAt.After(until) && len(result) < limit {
+			result = append(result, m)
+		}
+	}
+	return result, nil
+}
+
+type fakePresenceFeed struct {
+	presence []*entity.AgentPresence
+}
+
+func (f *fakePresenceFeed) PresenceChangedSince(tenantID string, since time.Time) []*entity.AgentPresence {
+	var result []*entity.AgentPresence
+	for _, p := range f.presence {
+		if p.TenantID == tenantID && p.UpdatedAt.After(since) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+func TestSyncService_Changes(t *testing.T) {
+	now := time.Date(2026, 4, 8, 15, 0, 0, 0, time.UTC)
+	repo := &fakeSyncRepo{}
+	presence := &fakePresenceFeed{}
+	svc := NewSyncService(repo, presence)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	repo.conversations = []*entity.SyncConversation{
+		{ID: "conv-old", UpdatedAt: now.Add(-60 * 24 * time.Hour)},
+		{ID: "conv-1", UpdatedAt: now.Add(-time.Hour)},
+		{ID: "conv-2", UpdatedAt: now.Add(-time.Hour)},
+		{ID: "conv-3", UpdatedAt: now.Add(-time.Minute)},
+	}
+	repo.messages = []*entity.SyncMessage{
+		{ID: "msg-old", UpdatedAt: now.Add(-48 * time.Hour)},
+		{ID: "msg-1", UpdatedAt: now.Add(-time.Minute)},
+	}
+	presence.presence = []*entity.AgentPresence{
+		{UserID: "user-1", TenantID: "tenant-1", Status: entity.PresenceStatusOnline, UpdatedAt: now.Add(-time.Hour)},
+	}
+
+	// A first sync reads recent changes, page by page
+	changes, err := svc.Changes(ctx, "tenant-1", "", 2)
+	require.NoError(t, err)
+	assert.True(t, changes.HasMore)
+	require.Len(t, changes.Conversations, 2)
+	assert.Equal(t, "conv-1", changes.Conversations[0].ID)
+	assert.Equal(t, "conv-2", changes.Conversations[1].ID)
+	require.Len(t, changes.Messages, 1)
+	assert.Equal(t, "msg-1", changes.Messages[0].ID)
+	require.Len(t, changes.Presence, 1)
+
+	changes, err = svc.Changes(ctx, "tenant-1", changes.Cursor, 2)
+	require.NoError(t, err)
+	assert.False(t, changes.HasMore)
+	require.Len(t, changes.Conversations, 1)
+	assert.Equal(t, "conv-3", changes.Conversations[0].ID)
+	assert.Empty(t, changes.Messages)
+	assert.Empty(t, changes.Presence)
+
+	// Only new changes follow; writes inside the settle window wait for the next sync
+	cursor := changes.Cursor
+	now = now.Add(time.Minute)
+	repo.conversations[0].UpdatedAt = now.Add(-30 * time.Second)
+	repo.messages = append(repo.messages, &entity.SyncMessage{ID: "msg-2", UpdatedAt: now.Add(-time.Second)})
+	presence.presence[0].Status = entity.PresenceStatusAway
+	presence.presence[0].UpdatedAt = now.Add(-10 * time.Second)
+
+	changes, err = svc.Changes(ctx, "tenant-1", cursor, 0)
+	require.NoError(t, err)
+	require.Len(t, changes.Conversations, 1)
+	assert.Equal(t, "conv-old", changes.Conversations[0].ID)
+	assert.Empty(t, changes.Messages)
+	require.Len(t, changes.Presence, 1)
+	assert.Equal(t, entity.PresenceStatusAway, changes.Presence[0].Status)
+
+	now = now.Add(time.Minute)
+	changes, err = svc.Changes(ctx, "tenant-1", changes.Cursor, 0)
+	require.NoError(t, err)
+	require.Len(t, changes.Messages, 1)
+	assert.Equal(t, "msg-2", changes.Messages[0].ID)
+}
+
+func TestSyncService_InvalidInput(t *testing.T) {
+	svc := NewSyncService(&fakeSyncRepo{}, nil)
+	ctx := context.Background()
+
+	_, err := svc.Changes(ctx, "tenant-1", "not a cursor!", 0)
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeValidation, errors.GetAppError(err).Code)
+
+	_, err = svc.Changes(ctx, "tenant-1", "", maxSyncLimit+1)
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeValidation, errors.GetAppError(err).Code)
+
+	changes, err := svc.Changes(ctx, "tenant-1", "", 0)
+	require.NoError(t, err)
+	assert.NotEmpty(t, changes.Cursor)
+	assert.NotNil(t, changes.Presence)
+}
@@ -23,6 +23,7 @@ type TemplateService struct {
 	channelRepo  repository.ChannelRepository
 	httpClient   *http.Client
 	writeLimiter *templateWriteLimiter
+	compliance   *ComplianceService
 }
 
 // NewTemplateService creates a new template service
@@ -43,6 +44,12 @@ func NewTemplateService(
 	}
 }
 
+// SetComplianceService checks template content against the compliance rules before
+// it is submitted to Meta
+func (s *TemplateService) SetComplianceService(compliance *ComplianceService) {
+	s.compliance = compliance
+}
+
 // CreateTemplateInput represents input for creating a template
 type CreateTemplateInput struct {
 	TenantID              string
@@ -55,6 +62,7 @@ type CreateTemplateInput struct {
 	MessageSendTTLSeconds int
 	AllowCategoryChange   bool
 	Components            []entity.TemplateComponent
+	ComplianceOverride    *ComplianceOverrideInput
 }
 
 // Create creates a new template (locally and syncs to Meta if credentials available)
@@ -93,6 +101,9 @@ func (s *TemplateService) Create(ctx context.Context, input *CreateTemplateInput
 		CreatedAt:             time.Now(),
 		UpdatedAt:             time.Now(),
 	}
+	if err := s.checkCompliance(ctx, template, input.ComplianceOverride); err != nil {
+		return nil, err
+	}
 
 	// Try to create template on Meta if credentials are available.
 	creds := s.getChannelCredentials(ctx, input.ChannelID)
@@ -133,6 +144,7 @@ type EditTemplateInput struct {
 	Category              entity.TemplateCategory
 	Components            []entity.TemplateComponent
 	MessageSendTTLSeconds int
+	ComplianceOverride    *ComplianceOverrideInput
 }
 
 // Edit updates an existing template on Meta and syncs the local copy.
@@ -167,6 +179,9 @@ func (s *TemplateService) Edit(ctx context.Context, input *EditTemplateInput) (*
 	if err := validateParameterFormat(template.ParameterFormat, template.Components); err != nil {
 		return nil, fmt.Errorf("invalid template: %w", err)
 	}
+	if err := s.checkCompliance(ctx, template, input.ComplianceOverride); err != nil {
+		return nil, err
+	}
 
 	creds := s.getChannelCredentials(ctx, template.ChannelID)
 	if creds == nil {
@@ -188,6 +203,28 @@ func (s *TemplateService) Edit(ctx context.Context, input *EditTemplateInput) (*
 	return template, nil
 }
 
+// checkCompliance enforces the compliance rules on a template's content, noting the
+// warnings on the template
+func (s *TemplateService) checkCompliance(ctx context.Context, template *entity.Template, override *ComplianceOverrideInput) error {
+	if s.compliance == nil {
+		return nil
+	}
+	report, err := s.compliance.Enforce(ctx, template.TenantID,
+		&ComplianceTarget{Type: entity.ComplianceTargetTemplate, ID: template.ID, Name: template.Name},
+		&ComplianceContent{
+			ChannelType:      entity.ChannelTypeWhatsAppOfficial,
+			TemplateCategory: template.Category,
+			Text:             templateComplianceText(template.Components),
+		},
+		override,
+	)
+	if err != nil {
+		return err
+	}
+	template.ComplianceWarnings = report.Warnings()
+	return nil
+}
+
 // GetByName returns a template by name and language
 func (s *TemplateService) GetByName(ctx context.Context, tenantID, channelID, name, language string) (*entity.Template, error) {
 	return s.templateRepo.FindByName(ctx, tenantID, channelID, name, language)
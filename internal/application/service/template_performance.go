@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/whatsapp/analytics"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+const (
+	// readRateDropThreshold is the relative read rate drop (vs. the previous period)
+	// that flags a template as falling
+	readRateDropThreshold = 0.25
+	// readRateMinSample is the minimum number of delivered messages in both periods
+	// before read rates are compared
+	readRateMinSample = 50
+)
+
+// TemplateStatsSource provides Meta's analytics for a template
+type TemplateStatsSource interface {
+	GetTemplateAnalytics(ctx context.Context, templateID string, startDate, endDate time.Time) (*analytics.TemplateAnalytics, error)
+}
+
+// TemplatePerformanceService builds template performance reports by combining Meta's
+// template analytics with local delivery, read and reply rates.
+type TemplatePerformanceService struct {
+	templateRepo  repository.TemplateRepository
+	analyticsRepo repository.AnalyticsRepository
+	channelRepo   repository.ChannelRepository
+}
+
+// NewTemplatePerformanceService creates a new template performance service
+func NewTemplatePerformanceService(
+	templateRepo repository.TemplateRepository,
+	analyticsRepo repository.AnalyticsRepository,
+	channelRepo repository.ChannelRepository,
+) *TemplatePerformanceService {
+	return &TemplatePerformanceService{
+		templateRepo:  templateRepo,
+		analyticsRepo: analyticsRepo,
+		channelRepo:   channelRepo,
+	}
+}
+
+// GetReport returns the performance of every template of a channel in a period. Read rates
+// are compared with the previous period of the same length. meta may be nil when the
+// channel has no analytics client; only local metrics are reported then. Flagged templates
+// come first.
+func (s *TemplatePerformanceService) GetReport(ctx context.Context, tenantID, channelID string, startDate, endDate time.Time, meta TemplateStatsSource) ([]*entity.TemplatePerformance, error) {
+	channel, err := s.channelRepo.FindByID(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if channel.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeChannelNotFound, "channel not found")
+	}
+
+	templates, _, err := s.templateRepo.FindByChannel(ctx, channelID, &repository.ListParams{Page: 1, PageSize: 1000})
+	if err != nil {
+		return nil, err
+	}
+
+	filter := entity.AnalyticsFilter{TenantID: tenantID, ChannelID: channelID, StartDate: startDate, EndDate: endDate}
+	current, err := s.analyticsRepo.GetTemplateDeliveryStats(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "Failed to get template delivery stats")
+	}
+
+	filter.StartDate = startDate.Add(-endDate.Sub(startDate))
+	filter.EndDate = startDate
+	previous, err := s.analyticsRepo.GetTemplateDeliveryStats(ctx, filter)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "Failed to get template delivery stats")
+	}
+
+	report := make([]*entity.TemplatePerformance, 0, len(templates))
+	for _, template := range templates {
+		perf := &entity.TemplatePerformance{
+			TemplateID:   template.ID,
+			ExternalID:   template.ExternalID,
+			Name:         template.Name,
+			Language:     template.Language,
+			Category:     template.Category,
+			Status:       template.Status,
+			QualityScore: template.QualityScore,
+			Local:        findTemplateStats(current, template),
+		}
+		perf.DeliveryRate = perf.Local.DeliveryRate()
+		perf.ReadRate = perf.Local.ReadRate()
+		perf.ReplyRate = perf.Local.ReplyRate()
+
+		prev := findTemplateStats(previous, template)
+		perf.PreviousReadRate = prev.ReadRate()
+
+		if meta != nil && template.ExternalID != "" {
+			if metaStats, err := meta.GetTemplateAnalytics(ctx, template.ExternalID, startDate, endDate); err == nil && metaStats != nil {
+				perf.MetaSent = int64(metaStats.Stats.Sent)
+				perf.MetaDelivered = int64(metaStats.Stats.Delivered)
+				perf.MetaRead = int64(metaStats.Stats.Read)
+				perf.MetaClicked = int64(metaStats.Stats.Clicked)
+				if metaStats.QualityScore != nil && metaStats.QualityScore.Score != "" {
+					perf.QualityScore = entity.TemplateQuality(strings.ToUpper(metaStats.QualityScore.Score))
+				}
+			}
+		}
+
+		flagTemplatePerformance(perf, prev)
+		report = append(report, perf)
+	}
+
+	sort.SliceStable(report, func(i, j int) bool {
+		return len(report[i].Flags) > len(report[j].Flags)
+	})
+
+	return report, nil
+}
+
+// flagTemplatePerformance marks templates with RED quality or a falling read rate and
+// suggests pausing them in campaigns
+func flagTemplatePerformance(perf *entity.TemplatePerformance, previous entity.TemplateDeliveryStats) {
+	if perf.QualityScore == entity.TemplateQualityRed {
+		perf.Flags = append(perf.Flags, entity.TemplateFlagQualityRed)
+	}
+
+	if perf.Local.Delivered >= readRateMinSample && previous.Delivered >= readRateMinSample &&
+		perf.ReadRate < perf.PreviousReadRate*(1-readRateDropThreshold) {
+		perf.Flags = append(perf.Flags, entity.TemplateFlagReadRateFalling)
+	}
+
+	switch {
+	case len(perf.Flags) == 0:
+		return
+	case perf.QualityScore == entity.TemplateQualityRed:
+		perf.Recommendation = "Pause this template in campaigns; Meta rates its quality RED and may disable it"
+	default:
+		perf.Recommendation = "Consider pausing this template in campaigns; its read rate dropped compared to the previous period"
+	}
+	perf.SuggestPause = true
+}
+
+// findTemplateStats returns the local stats of a template, matching on name and language.
+// Messages stored without a language are matched on name only.
+func findTemplateStats(stats []entity.TemplateDeliveryStats, template *entity.Template) entity.TemplateDeliveryStats {
+	result := entity.TemplateDeliveryStats{TemplateName: template.Name, Language: template.Language}
+	for _, s := range stats {
+		if s.TemplateName != template.Name {
+			continue
+		}
+		if s.Language != "" && !strings.EqualFold(s.Language, template.Language) {
+			continue
+		}
+		result.Sent += s.Sent
+		result.Delivered += s.Delivered
+		result.Read += s.Read
+		result.Failed += s.Failed
+		result.Replied += s.Replied
+	}
+	return result
+}
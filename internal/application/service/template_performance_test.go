@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/whatsapp/analytics"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// templateStatsAnalyticsRepository returns different template stats for the current and previous periods
+type templateStatsAnalyticsRepository struct {
+	repository.AnalyticsRepository
+	periodStart time.Time
+	current     []entity.TemplateDeliveryStats
+	previous    []entity.TemplateDeliveryStats
+}
+
+func (m *templateStatsAnalyticsRepository) GetTemplateDeliveryStats(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.TemplateDeliveryStats, error) {
+	if filter.StartDate.Before(m.periodStart) {
+		return m.previous, nil
+	}
+	return m.current, nil
+}
+
+type stubTemplateStatsSource struct {
+	result *analytics.TemplateAnalytics
+}
+
+func (s *stubTemplateStatsSource) GetTemplateAnalytics(ctx context.Context, templateID string, startDate, endDate time.Time) (*analytics.TemplateAnalytics, error) {
+	return s.result, nil
+}
+
+func newTestTemplatePerformanceService(start time.Time) (*TemplatePerformanceService, *mockTemplateRepository, *templateStatsAnalyticsRepository) {
+	channelRepo := testutil.NewMockChannelRepository()
+	channelRepo.Channels["channel-1"] = &entity.Channel{ID: "channel-1", TenantID: "tenant-1", Type: entity.ChannelTypeWhatsAppOfficial}
+	templateRepo := newMockTemplateRepository()
+	analyticsRepo := &templateStatsAnalyticsRepository{periodStart: start}
+	return NewTemplatePerformanceService(templateRepo, analyticsRepo, channelRepo), templateRepo, analyticsRepo
+}
+
+func TestTemplatePerformanceFlagsFallingReadRate(t *testing.T) {
+	end := time.Now()
+	start := end.AddDate(0, 0, -7)
+	svc, templateRepo, analyticsRepo := newTestTemplatePerformanceService(start)
+
+	templateRepo.Templates["t-1"] = &entity.Template{ID: "t-1", ChannelID: "channel-1", Name: "promo", Language: "en", Category: entity.TemplateCategoryMarketing, QualityScore: entity.TemplateQualityGreen}
+	templateRepo.Templates["t-2"] = &entity.Template{ID: "t-2", ChannelID: "channel-1", Name: "receipt", Language: "en", Category: entity.TemplateCategoryUtility, QualityScore: entity.TemplateQualityGreen}
+	analyticsRepo.current = []entity.TemplateDeliveryStats{
+		{TemplateName: "promo", Language: "en", Sent: 120, Delivered: 100, Read: 30, Replied: 5},
+		{TemplateName: "receipt", Language: "en", Sent: 100, Delivered: 100, Read: 80},
+	}
+	analyticsRepo.previous = []entity.TemplateDeliveryStats{
+		{TemplateName: "promo", Language: "en", Sent: 100, Delivered: 100, Read: 70},
+		{TemplateName: "receipt", Language: "en", Sent: 100, Delivered: 100, Read: 85},
+	}
+
+	report, err := svc.GetReport(context.Background(), "tenant-1", "channel-1", start, end, nil)
+	require.NoError(t, err)
+	require.Len(t, report, 2)
+
+	promo := report[0]
+	assert.Equal(t, "promo", promo.Name)
+	assert.Equal(t, []string{entity.TemplateFlagReadRateFalling}, promo.Flags)
+	assert.True(t, promo.SuggestPause)
+	assert.InDelta(t, 30.0, promo.ReadRate, 0.001)
+	assert.InDelta(t, 70.0, promo.PreviousReadRate, 0.001)
+	assert.InDelta(t, 5.0, promo.ReplyRate, 0.001)
+
+	receipt := report[1]
+	assert.Empty(t, receipt.Flags)
+	assert.False(t, receipt.SuggestPause)
+}
+
+func TestTemplatePerformanceUsesMetaQuality(t *testing.T) {
+	end := time.Now()
+	start := end.AddDate(0, 0, -7)
+	svc, templateRepo, _ := newTestTemplatePerformanceService(start)
+	templateRepo.Templates["t-1"] = &entity.Template{ID: "t-1", ExternalID: "hsm-1", ChannelID: "channel-1", Name: "promo", Language: "en", QualityScore: entity.TemplateQualityGreen}
+
+	meta := &stubTemplateStatsSource{result: &analytics.TemplateAnalytics{
+		Stats:        analytics.TemplateStats{Sent: 10, Delivered: 9, Read: 4, Clicked: 1},
+		QualityScore: &analytics.TemplateQualityScore{Score: "red"},
+	}}
+
+	report, err := svc.GetReport(context.Background(), "tenant-1", "channel-1", start, end, meta)
+	require.NoError(t, err)
+	require.Len(t, report, 1)
+	assert.Equal(t, entity.TemplateQualityRed, report[0].QualityScore)
+	assert.Equal(t, int64(9), report[0].MetaDelivered)
+	assert.Contains(t, report[0].Flags, entity.TemplateFlagQualityRed)
+	assert.True(t, report[0].SuggestPause)
+}
+
+func TestTemplatePerformanceIgnoresSmallSamples(t *testing.T) {
+	end := time.Now()
+	start := end.AddDate(0, 0, -7)
+	svc, templateRepo, analyticsRepo := newTestTemplatePerformanceService(start)
+	templateRepo.Templates["t-1"] = &entity.Template{ID: "t-1", ChannelID: "channel-1", Name: "promo", Language: "en"}
+	analyticsRepo.current = []entity.TemplateDeliveryStats{{TemplateName: "promo", Sent: 10, Delivered: 10, Read: 1}}
+	analyticsRepo.previous = []entity.TemplateDeliveryStats{{TemplateName: "promo", Sent: 10, Delivered: 10, Read: 9}}
+
+	report, err := svc.GetReport(context.Background(), "tenant-1", "channel-1", start, end, nil)
+	require.NoError(t, err)
+	assert.Empty(t, report[0].Flags)
+}
+
+func TestTemplatePerformanceRejectsOtherTenant(t *testing.T) {
+	svc, _, _ := newTestTemplatePerformanceService(time.Now())
+
+	_, err := svc.GetReport(context.Background(), "tenant-2", "channel-1", time.Now().Add(-time.Hour), time.Now(), nil)
+	assert.Error(t, err)
+}
@@ -23,6 +23,7 @@ type TenantUsage struct {
 	Contacts         int64 `json:"contacts"`
 	MessagesThisMonth int64 `json:"messages_this_month"`
 	Limits           *entity.TenantLimits `json:"limits"`
+	WhatsAppCosts    *WhatsAppCostTotals  `json:"whatsapp_costs,omitempty"`
 }
 
 // TenantService handles tenant operations
@@ -31,6 +32,7 @@ type TenantService struct {
 	userRepo    repository.UserRepository
 	channelRepo repository.ChannelRepository
 	contactRepo repository.ContactRepository
+	costService *WhatsAppCostService
 }
 
 // NewTenantService creates a new tenant service
@@ -48,6 +50,11 @@ func NewTenantService(
 	}
 }
 
+// SetWhatsAppCostService includes this month's WhatsApp spend in usage statistics
+func (s *TenantService) SetWhatsAppCostService(costService *WhatsAppCostService) {
+	s.costService = costService
+}
+
 // GetByID returns a tenant by ID
 func (s *TenantService) GetByID(ctx context.Context, id string) (*entity.Tenant, error) {
 	tenant, err := s.tenantRepo.FindByID(ctx, id)
@@ -107,11 +114,19 @@ func (s *TenantService) GetUsage(ctx context.Context, tenantID string) (*TenantU
 		contactCount, _ = s.contactRepo.CountByTenant(ctx, tenantID)
 	}
 
-	return &TenantUsage{
+	usage := &TenantUsage{
 		Users:             userCount,
 		Channels:          channelCount,
 		Contacts:          contactCount,
 		MessagesThisMonth: 0,
 		Limits:            tenant.Limits,
-	}, nil
+	}
+
+	if s.costService != nil {
+		now := time.Now()
+		monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		usage.WhatsAppCosts, _ = s.costService.GetTotals(ctx, tenantID, monthStart, now)
+	}
+
+	return usage, nil
 }
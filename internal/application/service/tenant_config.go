@@ -0,0 +1,920 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// Config bundle formats
+const (
+	ConfigFormatJSON = "json"
+	ConfigFormatYAML = "yaml"
+)
+
+// MaxConfigBundleBytes limits the size of imported bundles
+const MaxConfigBundleBytes = 10 << 20
+
+// JobTypeConfigImport applies a config bundle in the background
+const JobTypeConfigImport = "config.import"
+
+// secretKeyMarkers flag config keys and headers whose values are never exported
+var secretKeyMarkers = []string{"token", "secret", "password", "key", "auth", "credential"}
+
+// TenantConfigService exports a tenant configuration as a bundle and applies
+// bundles to tenants, so configuration can be promoted between environments
+type TenantConfigService struct {
+	channelRepo repository.ChannelRepository
+	botRepo     repository.BotRepository
+	flowRepo    repository.FlowRepository
+	hookRepo    repository.MessageHookRepository
+}
+
+// NewTenantConfigService creates a new tenant config service
+func NewTenantConfigService(
+	channelRepo repository.ChannelRepository,
+	botRepo repository.BotRepository,
+	flowRepo repository.FlowRepository,
+	hookRepo repository.MessageHookRepository,
+) *TenantConfigService {
+	return &TenantConfigService{
+		channelRepo: channelRepo,
+		botRepo:     botRepo,
+		flowRepo:    flowRepo,
+		hookRepo:    hookRepo,
+	}
+}
+
+// tenantConfig is the current configuration of a tenant
+type tenantConfig struct {
+	channels []*entity.Channel
+	bots     []*entity.Bot
+	flows    []*entity.Flow
+	hooks    []*entity.MessageHook
+}
+
+// Export builds a bundle with the given sections (all when empty) of a tenant
+func (s *TenantConfigService) Export(ctx context.Context, tenantID string, sections []entity.ConfigSection) (*entity.ConfigBundle, error) {
+	sections, err := normalizeSections(sections)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.load(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	channelNames := make(map[string]string, len(current.channels))
+	for _, channel := range current.channels {
+		channelNames[channel.ID] = channel.Name
+	}
+	botNames := make(map[string]string, len(current.bots))
+	for _, bot := range current.bots {
+		botNames[bot.ID] = bot.Name
+	}
+
+	bundle := &entity.ConfigBundle{
+		Version:    entity.ConfigBundleVersion,
+		ExportedAt: time.Now().UTC(),
+		Sections:   sections,
+	}
+	for _, section := range sections {
+		switch section {
+		case entity.ConfigSectionChannels:
+			for _, channel := range current.channels {
+				bundle.Channels = append(bundle.Channels, exportChannel(channel))
+			}
+		case entity.ConfigSectionBots:
+			for _, bot := range current.bots {
+				bundle.Bots = append(bundle.Bots, exportBot(bot, channelNames))
+			}
+		case entity.ConfigSectionFlows:
+			for _, flow := range current.flows {
+				bundle.Flows = append(bundle.Flows, exportFlow(flow, botNames))
+			}
+		case entity.ConfigSectionMessageHooks:
+			for _, hook := range current.hooks {
+				bundle.MessageHooks = append(bundle.MessageHooks, exportMessageHook(hook, channelNames))
+			}
+		}
+	}
+
+	// Stable ordering keeps bundles diffable in version control
+	sort.Slice(bundle.Channels, func(i, j int) bool { return bundle.Channels[i].Name < bundle.Channels[j].Name })
+	sort.Slice(bundle.Bots, func(i, j int) bool { return bundle.Bots[i].Name < bundle.Bots[j].Name })
+	sort.Slice(bundle.Flows, func(i, j int) bool { return bundle.Flows[i].Name < bundle.Flows[j].Name })
+	sort.Slice(bundle.MessageHooks, func(i, j int) bool { return bundle.MessageHooks[i].Name < bundle.MessageHooks[j].Name })
+
+	return bundle, nil
+}
+
+// Preview compares a bundle with the tenant configuration without changing it
+func (s *TenantConfigService) Preview(ctx context.Context, tenantID string, bundle *entity.ConfigBundle, sections []entity.ConfigSection) (*entity.ConfigPlan, error) {
+	plan, _, err := s.plan(ctx, tenantID, bundle, sections)
+	return plan, err
+}
+
+// Apply creates and updates the items of a bundle in the tenant. Nothing is
+// changed when the preview of the bundle reports errors.
+func (s *TenantConfigService) Apply(ctx context.Context, tenantID string, bundle *entity.ConfigBundle, sections []entity.ConfigSection) (*entity.ConfigPlan, error) {
+	plan, current, err := s.plan(ctx, tenantID, bundle, sections)
+	if err != nil {
+		return nil, err
+	}
+	if len(plan.Errors) > 0 {
+		return nil, errors.New(errors.ErrCodeValidation, "config bundle cannot be applied").
+			WithDetails(map[string]string{"errors": strings.Join(plan.Errors, "; ")})
+	}
+
+	selected := planSections(bundle, sections)
+	now := time.Now()
+
+	channelIDs := make(map[string]string)
+	for _, channel := range current.channels {
+		channelIDs[channel.Name] = channel.ID
+	}
+	if selected[entity.ConfigSectionChannels] {
+		for _, item := range bundle.Channels {
+			existing := findChannel(current.channels, item.Name)
+			if existing == nil {
+				channel := &entity.Channel{
+					ID:               uuid.New().String(),
+					TenantID:         tenantID,
+					Type:             item.Type,
+					Name:             item.Name,
+					Enabled:          false, // Credentials are configured per environment
+					ConnectionStatus: entity.ConnectionStatusDisconnected,
+					Config:           mergeRedacted(item.Config, nil),
+					Credentials:      map[string]string{},
+					IsCoexistence:    item.IsCoexistence,
+					CreatedAt:        now,
+					UpdatedAt:        now,
+				}
+				if err := s.channelRepo.Create(ctx, channel); err != nil {
+					return nil, err
+				}
+				channelIDs[channel.Name] = channel.ID
+				continue
+			}
+			if len(changedFields(exportChannel(existing), comparableChannel(item, existing))) == 0 {
+				continue
+			}
+			existing.Config = mergeRedacted(item.Config, existing.Config)
+			existing.IsCoexistence = item.IsCoexistence
+			existing.UpdatedAt = now
+			if err := s.channelRepo.Update(ctx, existing); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	botIDs := make(map[string]string)
+	for _, bot := range current.bots {
+		botIDs[bot.Name] = bot.ID
+	}
+	if selected[entity.ConfigSectionBots] {
+		for _, item := range bundle.Bots {
+			channels := resolveNames(item.Channels, channelIDs)
+			existing := findBot(current.bots, item.Name)
+			if existing == nil {
+				bot := entity.NewBot(tenantID, item.Name, item.Type, item.Provider, item.Model)
+				bot.ID = uuid.New().String()
+				bot.Config = item.Config
+				bot.Status = item.Status
+				bot.Channels = channels
+				if err := s.botRepo.Create(ctx, bot); err != nil {
+					return nil, err
+				}
+				botIDs[bot.Name] = bot.ID
+				continue
+			}
+			if len(changedFields(exportBot(existing, invertNames(channelIDs)), item)) == 0 {
+				continue
+			}
+			existing.Type = item.Type
+			existing.Provider = item.Provider
+			existing.Model = item.Model
+			existing.Config = withKnowledgeBase(item.Config, existing.Config.KnowledgeBaseID)
+			existing.Status = item.Status
+			existing.Channels = channels
+			existing.UpdatedAt = now
+			if err := s.botRepo.Update(ctx, existing); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if selected[entity.ConfigSectionFlows] {
+		for _, item := range bundle.Flows {
+			var botID *string
+			if item.Bot != "" {
+				id := botIDs[item.Bot]
+				botID = &id
+			}
+			existing := findFlow(current.flows, item.Name)
+			if existing == nil {
+				flow := entity.NewFlow(tenantID, item.Name, item.Trigger, item.TriggerValue)
+				flow.ID = uuid.New().String()
+				applyFlow(flow, item, botID)
+				if err := s.flowRepo.Create(ctx, flow); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if len(changedFields(exportFlow(existing, invertNames(botIDs)), item)) == 0 {
+				continue
+			}
+			applyFlow(existing, item, botID)
+			existing.UpdatedAt = now
+			if err := s.flowRepo.Update(ctx, existing); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if selected[entity.ConfigSectionMessageHooks] {
+		for _, item := range bundle.MessageHooks {
+			existing := findMessageHook(current.hooks, item.Name)
+			if existing == nil {
+				hook := &entity.MessageHook{
+					ID:        uuid.New().String(),
+					TenantID:  tenantID,
+					CreatedAt: now,
+				}
+				applyMessageHook(hook, item, resolveNames(item.Channels, channelIDs), nil)
+				hook.UpdatedAt = now
+				if err := s.hookRepo.Create(ctx, hook); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if len(changedFields(exportMessageHook(existing, invertNames(channelIDs)), comparableMessageHook(item, existing))) == 0 {
+				continue
+			}
+			applyMessageHook(existing, item, resolveNames(item.Channels, channelIDs), existing.Lookups)
+			existing.UpdatedAt = now
+			if err := s.hookRepo.Update(ctx, existing); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	plan.Applied = true
+	return plan, nil
+}
+
+// ImportJobPayload builds the payload of a config import job
+func ImportJobPayload(bundle *entity.ConfigBundle, sections []entity.ConfigSection) (map[string]interface{}, error) {
+	data, err := json.Marshal(map[string]interface{}{"bundle": bundle, "sections": sections})
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to encode config import job")
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to encode config import job")
+	}
+	return payload, nil
+}
+
+// RunImportJob applies the bundle carried by a config import job
+func (s *TenantConfigService) RunImportJob(ctx context.Context, job *entity.Job, progress JobProgress) (map[string]interface{}, error) {
+	data, err := json.Marshal(job.Payload)
+	if err != nil {
+		return nil, errors.Validation("invalid config import job payload")
+	}
+	var payload struct {
+		Bundle   *entity.ConfigBundle   `json:"bundle"`
+		Sections []entity.ConfigSection `json:"sections"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, errors.Validation("invalid config import job payload")
+	}
+
+	if err := progress(10, "applying bundle"); err != nil {
+		return nil, err
+	}
+	plan, err := s.Apply(ctx, job.TenantID, payload.Bundle, payload.Sections)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"creates":   plan.Creates,
+		"updates":   plan.Updates,
+		"unchanged": plan.Unchanged,
+		"changes":   plan.Changes,
+	}, nil
+}
+
+// EncodeConfigBundle serializes a bundle as JSON or YAML
+func EncodeConfigBundle(bundle *entity.ConfigBundle, format string) ([]byte, error) {
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to encode config bundle")
+	}
+
+	switch format {
+	case "", ConfigFormatJSON:
+		return data, nil
+	case ConfigFormatYAML:
+		// Going through a generic value reuses the JSON field names in YAML
+		var generic interface{}
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to encode config bundle")
+		}
+		out, err := yaml.Marshal(generic)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to encode config bundle")
+		}
+		return out, nil
+	default:
+		return nil, errors.Validation(fmt.Sprintf("unsupported format %q", format))
+	}
+}
+
+// DecodeConfigBundle parses a JSON or YAML bundle. YAML is a superset of
+// JSON, so both are accepted when format is empty.
+func DecodeConfigBundle(data []byte, format string) (*entity.ConfigBundle, error) {
+	if len(data) > MaxConfigBundleBytes {
+		return nil, errors.Validation(fmt.Sprintf("config bundle must be at most %d bytes", MaxConfigBundleBytes))
+	}
+
+	var bundle entity.ConfigBundle
+	switch format {
+	case ConfigFormatJSON:
+		if err := json.Unmarshal(data, &bundle); err != nil {
+			return nil, errors.Validation("invalid JSON config bundle: " + err.Error())
+		}
+	case "", ConfigFormatYAML:
+		var generic interface{}
+		if err := yaml.Unmarshal(data, &generic); err != nil {
+			return nil, errors.Validation("invalid YAML config bundle: " + err.Error())
+		}
+		normalized, err := json.Marshal(generic)
+		if err != nil {
+			return nil, errors.Validation("invalid config bundle: " + err.Error())
+		}
+		if err := json.Unmarshal(normalized, &bundle); err != nil {
+			return nil, errors.Validation("invalid config bundle: " + err.Error())
+		}
+	default:
+		return nil, errors.Validation(fmt.Sprintf("unsupported format %q", format))
+	}
+	return &bundle, nil
+}
+
+// ParseConfigSections parses a comma separated list of sections
+func ParseConfigSections(value string) []entity.ConfigSection {
+	var sections []entity.ConfigSection
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			sections = append(sections, entity.ConfigSection(part))
+		}
+	}
+	return sections
+}
+
+func (s *TenantConfigService) plan(ctx context.Context, tenantID string, bundle *entity.ConfigBundle, sections []entity.ConfigSection) (*entity.ConfigPlan, *tenantConfig, error) {
+	if bundle == nil {
+		return nil, nil, errors.Validation("config bundle is required")
+	}
+	if bundle.Version != entity.ConfigBundleVersion {
+		return nil, nil, errors.Validation(fmt.Sprintf("unsupported config bundle version %d", bundle.Version))
+	}
+	if _, err := normalizeSections(bundle.Sections); err != nil {
+		return nil, nil, err
+	}
+	if _, err := normalizeSections(sections); err != nil {
+		return nil, nil, err
+	}
+
+	current, err := s.load(ctx, tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	selected := planSections(bundle, sections)
+	plan := &entity.ConfigPlan{Changes: []entity.ConfigChange{}}
+	channelNames := make(map[string]string, len(current.channels))
+	for _, channel := range current.channels {
+		channelNames[channel.ID] = channel.Name
+	}
+	botNames := make(map[string]string, len(current.bots))
+	for _, bot := range current.bots {
+		botNames[bot.ID] = bot.Name
+	}
+
+	// Names available to references once the bundle is applied
+	knownChannels := namesOf(current.channels, func(c *entity.Channel) string { return c.Name })
+	knownBots := namesOf(current.bots, func(b *entity.Bot) string { return b.Name })
+
+	if selected[entity.ConfigSectionChannels] {
+		checkDuplicates(plan, entity.ConfigSectionChannels, bundle.Channels, func(c entity.ConfigChannel) string { return c.Name })
+		for _, item := range bundle.Channels {
+			if item.Name == "" || item.Type == "" {
+				plan.Errors = append(plan.Errors, "channels: name and type are required")
+				continue
+			}
+			knownChannels[item.Name] = true
+			existing := findChannel(current.channels, item.Name)
+			if existing != nil && existing.Type != item.Type {
+				plan.Errors = append(plan.Errors, fmt.Sprintf("channels: %q is a %s channel in this tenant, not %s", item.Name, existing.Type, item.Type))
+				continue
+			}
+			if existing == nil {
+				addConfigChange(plan, entity.ConfigSectionChannels, item.Name, nil, item)
+				continue
+			}
+			addConfigChange(plan, entity.ConfigSectionChannels, item.Name, exportChannel(existing), comparableChannel(item, existing))
+		}
+	}
+
+	if selected[entity.ConfigSectionBots] {
+		checkDuplicates(plan, entity.ConfigSectionBots, bundle.Bots, func(b entity.ConfigBot) string { return b.Name })
+		for _, item := range bundle.Bots {
+			if item.Name == "" {
+				plan.Errors = append(plan.Errors, "bots: name is required")
+				continue
+			}
+			knownBots[item.Name] = true
+			checkReferences(plan, entity.ConfigSectionBots, item.Name, "channel", item.Channels, knownChannels)
+			var before interface{}
+			if existing := findBot(current.bots, item.Name); existing != nil {
+				before = exportBot(existing, channelNames)
+			}
+			addConfigChange(plan, entity.ConfigSectionBots, item.Name, before, item)
+		}
+	}
+
+	if selected[entity.ConfigSectionFlows] {
+		checkDuplicates(plan, entity.ConfigSectionFlows, bundle.Flows, func(f entity.ConfigFlow) string { return f.Name })
+		for _, item := range bundle.Flows {
+			if item.Name == "" {
+				plan.Errors = append(plan.Errors, "flows: name is required")
+				continue
+			}
+			if !flowHasNode(item, item.StartNodeID) {
+				plan.Errors = append(plan.Errors, fmt.Sprintf("flows: %q has no start node %q", item.Name, item.StartNodeID))
+			}
+			if item.Bot != "" {
+				checkReferences(plan, entity.ConfigSectionFlows, item.Name, "bot", []string{item.Bot}, knownBots)
+			}
+			var before interface{}
+			if existing := findFlow(current.flows, item.Name); existing != nil {
+				before = exportFlow(existing, botNames)
+			}
+			addConfigChange(plan, entity.ConfigSectionFlows, item.Name, before, item)
+		}
+	}
+
+	if selected[entity.ConfigSectionMessageHooks] {
+		checkDuplicates(plan, entity.ConfigSectionMessageHooks, bundle.MessageHooks, func(h entity.ConfigMessageHook) string { return h.Name })
+		for _, item := range bundle.MessageHooks {
+			if item.Name == "" || item.Script == "" || len(item.Events) == 0 {
+				plan.Errors = append(plan.Errors, "message_hooks: name, script and events are required")
+				continue
+			}
+			for _, event := range item.Events {
+				if !event.IsValid() {
+					plan.Errors = append(plan.Errors, fmt.Sprintf("message_hooks: %q has unknown event %q", item.Name, event))
+				}
+			}
+			checkReferences(plan, entity.ConfigSectionMessageHooks, item.Name, "channel", item.Channels, knownChannels)
+			existing := findMessageHook(current.hooks, item.Name)
+			if existing == nil {
+				addConfigChange(plan, entity.ConfigSectionMessageHooks, item.Name, nil, item)
+				continue
+			}
+			addConfigChange(plan, entity.ConfigSectionMessageHooks, item.Name, exportMessageHook(existing, channelNames), comparableMessageHook(item, existing))
+		}
+	}
+
+	return plan, current, nil
+}
+
+func (s *TenantConfigService) load(ctx context.Context, tenantID string) (*tenantConfig, error) {
+	current := &tenantConfig{}
+
+	params := &repository.ListParams{Page: 1, PageSize: 500, SortBy: "created_at", SortDir: "asc"}
+	for {
+		channels, total, err := s.channelRepo.FindByTenant(ctx, tenantID, params)
+		if err != nil {
+			return nil, err
+		}
+		current.channels = append(current.channels, channels...)
+		if len(channels) == 0 || int64(len(current.channels)) >= total {
+			break
+		}
+		params.Page++
+	}
+
+	params = &repository.ListParams{Page: 1, PageSize: 500, SortBy: "created_at", SortDir: "asc"}
+	for {
+		bots, total, err := s.botRepo.FindByTenant(ctx, tenantID, params)
+		if err != nil {
+			return nil, err
+		}
+		current.bots = append(current.bots, bots...)
+		if len(bots) == 0 || int64(len(current.bots)) >= total {
+			break
+		}
+		params.Page++
+	}
+
+	params = &repository.ListParams{Page: 1, PageSize: 500, SortBy: "created_at", SortDir: "asc"}
+	for {
+		flows, total, err := s.flowRepo.FindByTenant(ctx, tenantID, nil, params)
+		if err != nil {
+			return nil, err
+		}
+		current.flows = append(current.flows, flows...)
+		if len(flows) == 0 || int64(len(current.flows)) >= total {
+			break
+		}
+		params.Page++
+	}
+
+	hooks, err := s.hookRepo.ListByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	current.hooks = hooks
+
+	return current, nil
+}
+
+// addConfigChange records the change of an item from before (nil when missing) to after
+func addConfigChange(plan *entity.ConfigPlan, section entity.ConfigSection, name string, before, after interface{}) {
+	change := entity.ConfigChange{Section: section, Name: name}
+	switch {
+	case before == nil:
+		change.Action = entity.ConfigChangeCreate
+		plan.Creates++
+	default:
+		change.Fields = changedFields(before, after)
+		if len(change.Fields) == 0 {
+			change.Action = entity.ConfigChangeUnchanged
+			plan.Unchanged++
+		} else {
+			change.Action = entity.ConfigChangeUpdate
+			plan.Updates++
+		}
+	}
+	plan.Changes = append(plan.Changes, change)
+}
+
+// changedFields lists the top-level JSON fields that differ between two items
+func changedFields(before, after interface{}) []string {
+	left, right := toFieldMap(before), toFieldMap(after)
+	var fields []string
+	for key, value := range right {
+		if !reflect.DeepEqual(left[key], value) {
+			fields = append(fields, key)
+		}
+	}
+	for key := range left {
+		if _, ok := right[key]; !ok {
+			fields = append(fields, key)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func toFieldMap(item interface{}) map[string]interface{} {
+	fields := make(map[string]interface{})
+	data, err := json.Marshal(item)
+	if err != nil {
+		return fields
+	}
+	_ = json.Unmarshal(data, &fields)
+	return fields
+}
+
+func checkDuplicates[T any](plan *entity.ConfigPlan, section entity.ConfigSection, items []T, name func(T) string) {
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		n := name(item)
+		if seen[n] {
+			plan.Errors = append(plan.Errors, fmt.Sprintf("%s: %q appears more than once", section, n))
+		}
+		seen[n] = true
+	}
+}
+
+func checkReferences(plan *entity.ConfigPlan, section entity.ConfigSection, name, kind string, refs []string, known map[string]bool) {
+	for _, ref := range refs {
+		if !known[ref] {
+			plan.Errors = append(plan.Errors, fmt.Sprintf("%s: %q references unknown %s %q", section, name, kind, ref))
+		}
+	}
+}
+
+func namesOf[T any](items []T, name func(T) string) map[string]bool {
+	names := make(map[string]bool, len(items))
+	for _, item := range items {
+		names[name(item)] = true
+	}
+	return names
+}
+
+func normalizeSections(sections []entity.ConfigSection) ([]entity.ConfigSection, error) {
+	if len(sections) == 0 {
+		return entity.AllConfigSections, nil
+	}
+	requested := make(map[entity.ConfigSection]bool, len(sections))
+	for _, section := range sections {
+		requested[section] = true
+	}
+	var ordered []entity.ConfigSection
+	for _, section := range entity.AllConfigSections {
+		if requested[section] {
+			ordered = append(ordered, section)
+			delete(requested, section)
+		}
+	}
+	for section := range requested {
+		return nil, errors.Validation(fmt.Sprintf("unknown config section %q", section))
+	}
+	return ordered, nil
+}
+
+// planSections selects the sections of a bundle to apply, narrowed by the
+// requested sections when given
+func planSections(bundle *entity.ConfigBundle, sections []entity.ConfigSection) map[entity.ConfigSection]bool {
+	selected := make(map[entity.ConfigSection]bool)
+	for _, section := range bundle.Sections {
+		selected[section] = true
+	}
+	if len(sections) == 0 {
+		return selected
+	}
+	requested := make(map[entity.ConfigSection]bool, len(sections))
+	for _, section := range sections {
+		requested[section] = true
+	}
+	for section := range selected {
+		if !requested[section] {
+			delete(selected, section)
+		}
+	}
+	return selected
+}
+
+func isSecretKey(key string) bool {
+	lower := strings.ToLower(key)
+	for _, marker := range secretKeyMarkers {
+		if strings.Contains(lower, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactSecrets(values map[string]string) map[string]string {
+	if len(values) == 0 {
+		return nil
+	}
+	redacted := make(map[string]string, len(values))
+	for key, value := range values {
+		if isSecretKey(key) && value != "" {
+			value = entity.ConfigRedacted
+		}
+		redacted[key] = value
+	}
+	return redacted
+}
+
+// mergeRedacted resolves redacted values from the current values, dropping
+// them when the target has none
+func mergeRedacted(incoming, current map[string]string) map[string]string {
+	merged := make(map[string]string, len(incoming))
+	for key, value := range incoming {
+		if value == entity.ConfigRedacted {
+			existing, ok := current[key]
+			if !ok {
+				continue
+			}
+			value = existing
+		}
+		merged[key] = value
+	}
+	return merged
+}
+
+// comparableChannel drops the redacted values an existing channel does not
+// have, as applying the bundle cannot set them
+func comparableChannel(item entity.ConfigChannel, existing *entity.Channel) entity.ConfigChannel {
+	item.Config = redactSecrets(mergeRedacted(item.Config, existing.Config))
+	return item
+}
+
+// comparableMessageHook drops the redacted headers an existing hook does not
+// have, as applying the bundle cannot set them
+func comparableMessageHook(item entity.ConfigMessageHook, existing *entity.MessageHook) entity.ConfigMessageHook {
+	lookups := make([]entity.HookLookup, 0, len(item.Lookups))
+	for _, lookup := range item.Lookups {
+		var currentHeaders map[string]string
+		for _, current := range existing.Lookups {
+			if current.Name == lookup.Name {
+				currentHeaders = current.Headers
+			}
+		}
+		lookup.Headers = redactSecrets(mergeRedacted(lookup.Headers, currentHeaders))
+		lookups = append(lookups, lookup)
+	}
+	if len(lookups) == 0 {
+		lookups = nil
+	}
+	item.Lookups = lookups
+	return item
+}
+
+func exportChannel(channel *entity.Channel) entity.ConfigChannel {
+	return entity.ConfigChannel{
+		Name:          channel.Name,
+		Type:          channel.Type,
+		Config:        redactSecrets(channel.Config),
+		IsCoexistence: channel.IsCoexistence,
+	}
+}
+
+func exportBot(bot *entity.Bot, channelNames map[string]string) entity.ConfigBot {
+	// Knowledge bases hold tenant data and are not part of the configuration
+	config := withKnowledgeBase(bot.Config, nil)
+	return entity.ConfigBot{
+		Name:     bot.Name,
+		Type:     bot.Type,
+		Provider: bot.Provider,
+		Model:    bot.Model,
+		Config:   config,
+		Status:   bot.Status,
+		Channels: namesFor(bot.Channels, channelNames),
+	}
+}
+
+func exportFlow(flow *entity.Flow, botNames map[string]string) entity.ConfigFlow {
+	item := entity.ConfigFlow{
+		Name:         flow.Name,
+		Description:  flow.Description,
+		Trigger:      flow.Trigger,
+		TriggerValue: flow.TriggerValue,
+		StartNodeID:  flow.StartNodeID,
+		Nodes:        flow.Nodes,
+		IsActive:     flow.IsActive,
+		Priority:     flow.Priority,
+	}
+	if flow.BotID != nil {
+		item.Bot = botNames[*flow.BotID]
+	}
+	return item
+}
+
+func exportMessageHook(hook *entity.MessageHook, channelNames map[string]string) entity.ConfigMessageHook {
+	var lookups []entity.HookLookup
+	for _, lookup := range hook.Lookups {
+		lookups = append(lookups, entity.HookLookup{
+			Name:    lookup.Name,
+			URL:     lookup.URL,
+			Headers: redactSecrets(lookup.Headers),
+		})
+	}
+	return entity.ConfigMessageHook{
+		Name:        hook.Name,
+		Description: hook.Description,
+		Language:    hook.Language,
+		Script:      hook.Script,
+		Events:      hook.Events,
+		Channels:    namesFor(hook.ChannelIDs, channelNames),
+		Lookups:     lookups,
+		TimeoutMs:   hook.TimeoutMs,
+		MemoryMB:    hook.MemoryMB,
+		Priority:    hook.Priority,
+		IsActive:    hook.IsActive,
+	}
+}
+
+func applyFlow(flow *entity.Flow, item entity.ConfigFlow, botID *string) {
+	flow.BotID = botID
+	flow.Description = item.Description
+	flow.Trigger = item.Trigger
+	flow.TriggerValue = item.TriggerValue
+	flow.StartNodeID = item.StartNodeID
+	flow.Nodes = item.Nodes
+	flow.IsActive = item.IsActive
+	flow.Priority = item.Priority
+}
+
+func applyMessageHook(hook *entity.MessageHook, item entity.ConfigMessageHook, channelIDs []string, currentLookups []entity.HookLookup) {
+	lookups := make([]entity.HookLookup, 0, len(item.Lookups))
+	for _, lookup := range item.Lookups {
+		var currentHeaders map[string]string
+		for _, current := range currentLookups {
+			if current.Name == lookup.Name {
+				currentHeaders = current.Headers
+			}
+		}
+		lookup.Headers = mergeRedacted(lookup.Headers, currentHeaders)
+		lookups = append(lookups, lookup)
+	}
+
+	hook.Name = item.Name
+	hook.Description = item.Description
+	hook.Language = item.Language
+	hook.Script = item.Script
+	hook.Events = item.Events
+	hook.ChannelIDs = channelIDs
+	hook.Lookups = lookups
+	hook.TimeoutMs = item.TimeoutMs
+	hook.MemoryMB = item.MemoryMB
+	hook.Priority = item.Priority
+	hook.IsActive = item.IsActive
+}
+
+func withKnowledgeBase(config entity.BotConfig, knowledgeBaseID *string) entity.BotConfig {
+	config.KnowledgeBaseID = knowledgeBaseID
+	return config
+}
+
+func flowHasNode(flow entity.ConfigFlow, nodeID string) bool {
+	for _, node := range flow.Nodes {
+		if node.ID == nodeID {
+			return true
+		}
+	}
+	return false
+}
+
+func namesFor(ids []string, names map[string]string) []string {
+	var result []string
+	for _, id := range ids {
+		if name, ok := names[id]; ok {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+func resolveNames(names []string, ids map[string]string) []string {
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		if id, ok := ids[name]; ok {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+func invertNames(ids map[string]string) map[string]string {
+	names := make(map[string]string, len(ids))
+	for name, id := range ids {
+		names[id] = name
+	}
+	return names
+}
+
+func findChannel(channels []*entity.Channel, name string) *entity.Channel {
+	for _, channel := range channels {
+		if channel.Name == name {
+			return channel
+		}
+	}
+	return nil
+}
+
+func findBot(bots []*entity.Bot, name string) *entity.Bot {
+	for _, bot := range bots {
+		if bot.Name == name {
+			return bot
+		}
+	}
+	return nil
+}
+
+func findFlow(flows []*entity.Flow, name string) *entity.Flow {
+	for _, flow := range flows {
+		if flow.Name == name {
+			return flow
+		}
+	}
+	return nil
+}
+
+func findMessageHook(hooks []*entity.MessageHook, name string) *entity.MessageHook {
+	for _, hook := range hooks {
+		if hook.Name == name {
+			return hook
+		}
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tenantConfigFixture struct {
+	svc      *TenantConfigService
+	channels *testutil.MockChannelRepository
+	bots     *MockBotRepository
+	flows    *mockFlowRepo
+	hooks    *mockMessageHookRepo
+}
+
+func newTenantConfigFixture() *tenantConfigFixture {
+	f := &tenantConfigFixture{
+		channels: testutil.NewMockChannelRepository(),
+		bots:     NewMockBotRepository(),
+		flows:    newMockFlowRepo(),
+		hooks:    newMockMessageHookRepo(),
+	}
+	f.svc = NewTenantConfigService(f.channels, f.bots, f.flows, f.hooks)
+	return f
+}
+
+func seedStagingConfig(f *tenantConfigFixture) {
+	f.channels.Channels["ch-1"] = &entity.Channel{
+		ID:          "ch-1",
+		TenantID:    "staging",
+		Type:        entity.ChannelTypeWhatsAppOfficial,
+		Name:        "Support WhatsApp",
+		Enabled:     true,
+		Config:      map[string]string{"phone_number_id": "123", "verify_token": "s3cr3t"},
+		Credentials: map[string]string{"access_token": "EAAB"},
+	}
+
+	bot := entity.NewBot("staging", "Support Bot", entity.BotTypeAI, entity.AIProviderOpenAI, "gpt-4")
+	bot.ID = "bot-1"
+	bot.Channels = []string{"ch-1"}
+	f.bots.Bots[bot.ID] = bot
+
+	flow := makeSimpleFlow("staging")
+	flow.SetBotID("bot-1")
+	f.flows.flows[flow.ID] = flow
+
+	f.hooks.hooks["hook-1"] = &entity.MessageHook{
+		ID:         "hook-1",
+		TenantID:   "staging",
+		Name:       "Tag VIPs",
+		Language:   entity.HookLanguageJavaScript,
+		Script:     "message.tags.push('vip')",
+		Events:     []entity.HookEvent{entity.HookEventInbound},
+		ChannelIDs: []string{"ch-1"},
+		Lookups: []entity.HookLookup{{
+			Name:    "crm",
+			URL:     "https://crm.example.com/customers",
+			Headers: map[string]string{"Authorization": "Bearer staging", "Accept": "application/json"},
+		}},
+		IsActive: true,
+	}
+}
+
+func TestTenantConfigExportExcludesSecrets(t *testing.T) {
+	f := newTenantConfigFixture()
+	seedStagingConfig(f)
+
+	bundle, err := f.svc.Export(context.Background(), "staging", nil)
+	require.NoError(t, err)
+	assert.Equal(t, entity.ConfigBundleVersion, bundle.Version)
+	assert.Equal(t, entity.AllConfigSections, bundle.Sections)
+
+	require.Len(t, bundle.Channels, 1)
+	assert.Equal(t, "123", bundle.Channels[0].Config["phone_number_id"])
+	assert.Equal(t, entity.ConfigRedacted, bundle.Channels[0].Config["verify_token"])
+
+	require.Len(t, bundle.Bots, 1)
+	assert.Equal(t, []string{"Support WhatsApp"}, bundle.Bots[0].Channels)
+	require.Len(t, bundle.Flows, 1)
+	assert.Equal(t, "Support Bot", bundle.Flows[0].Bot)
+	require.Len(t, bundle.MessageHooks, 1)
+	assert.Equal(t, entity.ConfigRedacted, bundle.MessageHooks[0].Lookups[0].Headers["Authorization"])
+
+	data, err := EncodeConfigBundle(bundle, ConfigFormatYAML)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "s3cr3t")
+	assert.NotContains(t, string(data), "EAAB")
+	assert.Contains(t, string(data), "system_prompt:")
+
+	decoded, err := DecodeConfigBundle(data, "")
+	require.NoError(t, err)
+	assert.Equal(t, bundle.Bots[0].Config.SystemPrompt, decoded.Bots[0].Config.SystemPrompt)
+	assert.Equal(t, bundle.Flows[0].Nodes[0].ID, decoded.Flows[0].Nodes[0].ID)
+
+	only, err := f.svc.Export(context.Background(), "staging", []entity.ConfigSection{entity.ConfigSectionFlows})
+	require.NoError(t, err)
+	assert.Empty(t, only.Channels)
+	assert.Len(t, only.Flows, 1)
+
+	_, err = f.svc.Export(context.Background(), "staging", []entity.ConfigSection{"users"})
+	assert.True(t, errors.IsValidation(err))
+}
+
+func TestTenantConfigPromoteToAnotherTenant(t *testing.T) {
+	f := newTenantConfigFixture()
+	seedStagingConfig(f)
+	ctx := context.Background()
+
+	bundle, err := f.svc.Export(ctx, "staging", nil)
+	require.NoError(t, err)
+
+	plan, err := f.svc.Preview(ctx, "prod", bundle, nil)
+	require.NoError(t, err)
+	assert.Empty(t, plan.Errors)
+	assert.Equal(t, 4, plan.Creates)
+	assert.False(t, plan.Applied)
+	assert.Len(t, f.channels.Channels, 1, "preview must not change anything")
+
+	plan, err = f.svc.Apply(ctx, "prod", bundle, nil)
+	require.NoError(t, err)
+	assert.True(t, plan.Applied)
+
+	var prodChannel *entity.Channel
+	for _, channel := range f.channels.Channels {
+		if channel.TenantID == "prod" {
+			prodChannel = channel
+		}
+	}
+	require.NotNil(t, prodChannel)
+	assert.False(t, prodChannel.Enabled)
+	assert.Empty(t, prodChannel.Credentials)
+	assert.NotContains(t, prodChannel.Config, "verify_token")
+
+	var prodBot *entity.Bot
+	for _, bot := range f.bots.Bots {
+		if bot.TenantID == "prod" {
+			prodBot = bot
+		}
+	}
+	require.NotNil(t, prodBot)
+	assert.Equal(t, []string{prodChannel.ID}, prodBot.Channels)
+
+	for _, flow := range f.flows.flows {
+		if flow.TenantID == "prod" {
+			require.NotNil(t, flow.BotID)
+			assert.Equal(t, prodBot.ID, *flow.BotID)
+		}
+	}
+
+	// Applying the same bundle again is a no-op
+	plan, err = f.svc.Preview(ctx, "prod", bundle, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, plan.Creates+plan.Updates)
+	assert.Equal(t, 4, plan.Unchanged)
+}
+
+func TestTenantConfigPreviewReportsUpdatesAndKeepsSecrets(t *testing.T) {
+	f := newTenantConfigFixture()
+	seedStagingConfig(f)
+	ctx := context.Background()
+
+	bundle, err := f.svc.Export(ctx, "staging", []entity.ConfigSection{entity.ConfigSectionChannels, entity.ConfigSectionMessageHooks})
+	require.NoError(t, err)
+	bundle.MessageHooks[0].Script = "message.tags.push('gold')"
+	bundle.Channels[0].Config["phone_number_id"] = "456"
+
+	plan, err := f.svc.Preview(ctx, "staging", bundle, nil)
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 2)
+	assert.Equal(t, entity.ConfigChangeUpdate, plan.Changes[0].Action)
+	assert.Equal(t, []string{"config"}, plan.Changes[0].Fields)
+	assert.Equal(t, []string{"script"}, plan.Changes[1].Fields)
+
+	// Only the selected sections are applied
+	plan, err = f.svc.Apply(ctx, "staging", bundle, []entity.ConfigSection{entity.ConfigSectionMessageHooks})
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 1)
+	assert.Equal(t, "123", f.channels.Channels["ch-1"].Config["phone_number_id"])
+
+	hook := f.hooks.hooks["hook-1"]
+	assert.Equal(t, "message.tags.push('gold')", hook.Script)
+	assert.Equal(t, "Bearer staging", hook.Lookups[0].Headers["Authorization"])
+	assert.Equal(t, []string{"ch-1"}, hook.ChannelIDs)
+}
+
+func TestTenantConfigRejectsInvalidBundles(t *testing.T) {
+	f := newTenantConfigFixture()
+	ctx := context.Background()
+
+	_, err := f.svc.Preview(ctx, "prod", &entity.ConfigBundle{Version: 99}, nil)
+	assert.True(t, errors.IsValidation(err))
+
+	bundle := &entity.ConfigBundle{
+		Version:  entity.ConfigBundleVersion,
+		Sections: []entity.ConfigSection{entity.ConfigSectionBots, entity.ConfigSectionFlows},
+		Bots: []entity.ConfigBot{
+			{Name: "Bot", Type: entity.BotTypeAI, Channels: []string{"Missing channel"}},
+			{Name: "Bot", Type: entity.BotTypeAI},
+		},
+		Flows: []entity.ConfigFlow{{Name: "Flow", Bot: "Other bot", StartNodeID: "start"}},
+	}
+
+	plan, err := f.svc.Preview(ctx, "prod", bundle, nil)
+	require.NoError(t, err)
+	assert.Len(t, plan.Errors, 4)
+
+	_, err = f.svc.Apply(ctx, "prod", bundle, nil)
+	assert.True(t, errors.IsValidation(err))
+	assert.Empty(t, f.bots.Bots)
+
+	_, err = DecodeConfigBundle([]byte("version: [1"), "")
+	assert.True(t, errors.IsValidation(err))
+	_, err = EncodeConfigBundle(bundle, "xml")
+	assert.True(t, errors.IsValidation(err))
+}
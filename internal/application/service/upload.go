@@ -0,0 +1,580 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/antivirus"
+	"github.com/msgfy/linktor/internal/infrastructure/encryption"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/internal/infrastructure/storage"
+	"github.com/msgfy/linktor/internal/infrastructure/transcode"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// JobTypeUploadProcess verifies, scans and transcodes a completed upload
+const JobTypeUploadProcess = "upload.process"
+
+// Events published when an upload finished processing
+const (
+	EventUploadCompleted = "upload.completed"
+	EventUploadFailed    = "upload.failed"
+)
+
+// Limits of resumable uploads
+const (
+	MaxUploadSize      = 512 << 20
+	MaxUploadChunkSize = 32 << 20
+	// UploadExpiry is how long an upload can be resumed before it is discarded
+	UploadExpiry = 24 * time.Hour
+)
+
+// maxExpiredUploadsPerRun caps the uploads discarded by one cleanup run
+const maxExpiredUploadsPerRun = 100
+
+// ErrUploadChecksumMismatch is returned for a chunk that does not match its checksum
+var ErrUploadChecksumMismatch = errors.New(errors.ErrCodeBadRequest, "chunk checksum mismatch")
+
+var sha256HexPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// VirusScanner scans files for malware
+type VirusScanner interface {
+	Scan(ctx context.Context, data []byte) (*antivirus.Result, error)
+}
+
+// VideoTranscoder converts videos to a channel's format and size
+type VideoTranscoder interface {
+	Transcode(ctx context.Context, data []byte, profile transcode.Profile) ([]byte, error)
+}
+
+// CreateUploadInput represents input for starting an upload
+type CreateUploadInput struct {
+	Filename    string
+	MimeType    string
+	Purpose     entity.UploadPurpose
+	ChannelType entity.ChannelType
+	Size        int64
+	Checksum    string // Expected SHA-256 of the whole file, hex
+	CreatedBy   string
+}
+
+// UploadService receives large files in chunks that can be resumed after a dropped
+// connection. Chunks are staged in object storage encrypted with a key of their own,
+// so any node can accept the next chunk or assemble the file. Once every byte
+// arrived, a background job verifies the file checksum, scans it for viruses and
+// transcodes videos for the target channel before storing the result.
+type UploadService struct {
+	repo       repository.UploadRepository
+	storage    storage.ObjectStorage
+	producer   nats.Publisher
+	cipher     encryption.Cipher
+	scanner    VirusScanner
+	transcoder VideoTranscoder
+	jobs       *JobService
+	now        func() time.Time
+}
+
+// NewUploadService creates a new upload service
+func NewUploadService(repo repository.UploadRepository, store storage.ObjectStorage, producer nats.Publisher) *UploadService {
+	return &UploadService{
+		repo:     repo,
+		storage:  store,
+		producer: producer,
+		now:      time.Now,
+	}
+}
+
+// SetCipher seals the chunk keys with the tenant's encryption key
+func (s *UploadService) SetCipher(cipher encryption.Cipher) {
+	s.cipher = cipher
+}
+
+// SetScanner enables virus scanning of uploads
+func (s *UploadService) SetScanner(scanner VirusScanner) {
+	s.scanner = scanner
+}
+
+// SetTranscoder enables transcoding of uploaded videos
+func (s *UploadService) SetTranscoder(transcoder VideoTranscoder) {
+	s.transcoder = transcoder
+}
+
+// SetJobService processes completed uploads as background jobs. Without it they are
+// processed when the last chunk arrives.
+func (s *UploadService) SetJobService(jobs *JobService) {
+	s.jobs = jobs
+}
+
+// Create starts an upload
+func (s *UploadService) Create(ctx context.Context, tenantID string, input *CreateUploadInput) (*entity.Upload, error) {
+	filename := filepath.Base(strings.TrimSpace(input.Filename))
+	if filename == "" || filename == "." || filename == "/" {
+		return nil, errors.Validation("filename is required")
+	}
+	if input.Size <= 0 {
+		return nil, errors.Validation("upload length must be positive")
+	}
+	if input.Size > MaxUploadSize {
+		return nil, errors.Validation(fmt.Sprintf("upload length exceeds the maximum of %d bytes", MaxUploadSize))
+	}
+	purpose := input.Purpose
+	if purpose == "" {
+		purpose = entity.UploadPurposeMedia
+	}
+	if !purpose.IsValid() {
+		return nil, errors.Validation("purpose must be campaign, knowledge or media")
+	}
+	checksum := strings.ToLower(strings.TrimSpace(input.Checksum))
+	if checksum != "" && !sha256HexPattern.MatchString(checksum) {
+		return nil, errors.Validation("checksum must be a hex encoded SHA-256")
+	}
+	mimeType := strings.TrimSpace(input.MimeType)
+	if mimeType == "" {
+		mimeType = mime.TypeByExtension(filepath.Ext(filename))
+	}
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+
+	key, err := encryption.GenerateKey()
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to generate upload key")
+	}
+	sealedKey := base64.StdEncoding.EncodeToString(key)
+	if s.cipher != nil {
+		if sealedKey, err = s.cipher.Encrypt(ctx, tenantID, sealedKey); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to seal upload key")
+		}
+	}
+
+	now := s.now()
+	upload := &entity.Upload{
+		ID:            uuid.New().String(),
+		TenantID:      tenantID,
+		CreatedBy:     input.CreatedBy,
+		Filename:      filename,
+		MimeType:      mimeType,
+		Purpose:       purpose,
+		ChannelType:   input.ChannelType,
+		Size:          input.Size,
+		Chunks:        []int64{},
+		Checksum:      checksum,
+		Status:        entity.UploadStatusUploading,
+		ScanStatus:    entity.ScanStatusPending,
+		EncryptionKey: sealedKey,
+		ExpiresAt:     now.Add(UploadExpiry),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := s.repo.Create(ctx, upload); err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// Get returns an upload of a tenant
+func (s *UploadService) Get(ctx context.Context, tenantID, id string) (*entity.Upload, error) {
+	upload, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeNotFound, "upload not found")
+	}
+	return upload, nil
+}
+
+// WriteChunk stages the chunk of an upload starting at offset, which must be the
+// upload's current offset. When checksum is set the chunk must match it. Receiving
+// the last chunk starts processing the file.
+func (s *UploadService) WriteChunk(ctx context.Context, tenantID, id string, offset int64, data, checksum []byte) (*entity.Upload, error) {
+	upload, err := s.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Status != entity.UploadStatusUploading {
+		return nil, errors.New(errors.ErrCodeConflict, "upload is not accepting chunks")
+	}
+	if s.now().After(upload.ExpiresAt) {
+		return nil, errors.New(errors.ErrCodeNotFound, "upload expired")
+	}
+	if offset != upload.Offset {
+		return nil, errors.New(errors.ErrCodeConflict, "upload offset mismatch").
+			WithDetails(map[string]string{"offset": fmt.Sprint(upload.Offset)})
+	}
+	if len(data) == 0 {
+		return upload, nil
+	}
+	if len(data) > MaxUploadChunkSize {
+		return nil, errors.Validation(fmt.Sprintf("chunk exceeds the maximum of %d bytes", MaxUploadChunkSize))
+	}
+	length := int64(len(data))
+	if offset+length > upload.Size {
+		return nil, errors.Validation("chunk exceeds the upload length")
+	}
+	if checksum != nil {
+		sum := sha256.Sum256(data)
+		if !bytes.Equal(sum[:], checksum) {
+			return nil, ErrUploadChecksumMismatch
+		}
+	}
+
+	key, err := s.chunkKey(ctx, upload)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := encryption.SealData(key, data, chunkAdditionalData(upload, offset, length))
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to encrypt chunk")
+	}
+	objectKey := chunkObjectKey(upload, offset, length)
+	if _, err := s.storage.Upload(ctx, objectKey, sealed, "application/octet-stream"); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to store chunk")
+	}
+
+	appended, err := s.repo.AppendChunk(ctx, id, offset, length)
+	if err != nil {
+		return nil, err
+	}
+	if !appended {
+		// A concurrent request wrote this offset first. Its chunk shares the object
+		// only when it has the same length, otherwise ours is an orphan.
+		if current, err := s.repo.FindByID(ctx, id); err == nil && chunkLength(current, offset) != length {
+			s.storage.Delete(ctx, objectKey)
+		}
+		return nil, errors.New(errors.ErrCodeConflict, "upload offset mismatch")
+	}
+
+	upload.Offset += length
+	upload.Chunks = append(upload.Chunks, offset)
+	upload.UpdatedAt = s.now()
+	if !upload.IsComplete() {
+		return upload, nil
+	}
+
+	if err := s.startProcessing(ctx, upload); err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+// Terminate discards an upload and its staged chunks. Uploads being processed cannot
+// be terminated.
+func (s *UploadService) Terminate(ctx context.Context, tenantID, id string) error {
+	upload, err := s.Get(ctx, tenantID, id)
+	if err != nil {
+		return err
+	}
+	if upload.Status == entity.UploadStatusProcessing {
+		return errors.New(errors.ErrCodeConflict, "upload is being processed")
+	}
+	s.deleteObjects(ctx, upload)
+	return s.repo.Delete(ctx, id)
+}
+
+// CleanupExpired discards uploads that were not finished before they expired and
+// returns how many were discarded
+func (s *UploadService) CleanupExpired(ctx context.Context) (int, error) {
+	uploads, err := s.repo.ListExpired(ctx, s.now(), maxExpiredUploadsPerRun)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, upload := range uploads {
+		s.deleteObjects(ctx, upload)
+		if err := s.repo.Delete(ctx, upload.ID); err != nil {
+			logger.Warn("Failed to delete expired upload", zap.String("upload_id", upload.ID), zap.Error(err))
+			continue
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// RunProcessJob processes a completed upload. Checksum mismatches and infected files
+// fail the job at once; storage and scanner errors are retried, and the upload is
+// marked failed when no attempt is left.
+func (s *UploadService) RunProcessJob(ctx context.Context, job *entity.Job, progress JobProgress) (map[string]interface{}, error) {
+	id, _ := job.Payload["upload_id"].(string)
+	if id == "" {
+		return nil, errors.Validation("invalid upload job payload")
+	}
+	upload, err := s.Get(ctx, job.TenantID, id)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, errors.Validation("upload no longer exists")
+		}
+		return nil, err
+	}
+	if upload.Status != entity.UploadStatusProcessing {
+		return uploadResult(upload), nil
+	}
+
+	upload.JobID = job.ID
+	if err := s.process(ctx, upload, progress); err != nil {
+		// Infected uploads were already marked by the scan
+		if upload.Status == entity.UploadStatusProcessing && (errors.IsValidation(err) || !job.CanRetry()) {
+			s.fail(ctx, upload, entity.UploadStatusFailed, err)
+		}
+		return nil, err
+	}
+	return uploadResult(upload), nil
+}
+
+// startProcessing marks a complete upload as processing and hands it to a job
+func (s *UploadService) startProcessing(ctx context.Context, upload *entity.Upload) error {
+	upload.Status = entity.UploadStatusProcessing
+	if err := s.repo.Update(ctx, upload); err != nil {
+		return err
+	}
+
+	if s.jobs == nil {
+		err := s.process(ctx, upload, func(int, string) error { return nil })
+		if err != nil && upload.Status == entity.UploadStatusProcessing {
+			s.fail(ctx, upload, entity.UploadStatusFailed, err)
+		}
+		return nil
+	}
+
+	// The job records its ID on the upload when it runs
+	job, err := s.jobs.Enqueue(ctx, upload.TenantID, &JobInput{
+		Type:      JobTypeUploadProcess,
+		Payload:   map[string]interface{}{"upload_id": upload.ID},
+		CreatedBy: upload.CreatedBy,
+	})
+	if err != nil {
+		s.fail(ctx, upload, entity.UploadStatusFailed, err)
+		return err
+	}
+	upload.JobID = job.ID
+	return nil
+}
+
+// process assembles, verifies, scans and transcodes an upload and stores the result
+func (s *UploadService) process(ctx context.Context, upload *entity.Upload, progress JobProgress) error {
+	if err := progress(10, "assembling"); err != nil {
+		return err
+	}
+	data, err := s.assemble(ctx, upload)
+	if err != nil {
+		return err
+	}
+
+	if upload.Checksum != "" {
+		sum := sha256.Sum256(data)
+		if hex.EncodeToString(sum[:]) != upload.Checksum {
+			return errors.Validation("file checksum mismatch")
+		}
+	}
+
+	if err := progress(30, "scanning"); err != nil {
+		return err
+	}
+	upload.ScanStatus = entity.ScanStatusSkipped
+	if s.scanner != nil {
+		result, err := s.scanner.Scan(ctx, data)
+		if err != nil {
+			return errors.Wrap(err, errors.ErrCodeInternal, "virus scan failed")
+		}
+		if result.Infected {
+			upload.ScanStatus = entity.ScanStatusInfected
+			upload.ScanResult = result.Signature
+			s.fail(ctx, upload, entity.UploadStatusInfected, fmt.Errorf("malware detected: %s", result.Signature))
+			return errors.Validation("upload is infected")
+		}
+		upload.ScanStatus = entity.ScanStatusClean
+	}
+
+	mimeType, filename := upload.MimeType, upload.Filename
+	if upload.IsVideo() && s.transcoder != nil {
+		profile := transcode.ProfileFor(string(upload.ChannelType))
+		if transcode.NeedsTranscoding(mimeType, int64(len(data)), profile) {
+			if err := progress(50, "transcoding"); err != nil {
+				return err
+			}
+			if data, err = s.transcoder.Transcode(ctx, data, profile); err != nil {
+				return errors.Validation(err.Error())
+			}
+			mimeType = transcode.OutputMimeType
+			filename = strings.TrimSuffix(filename, filepath.Ext(filename)) + ".mp4"
+			upload.Transcoded = true
+		}
+	}
+
+	if err := progress(80, "storing"); err != nil {
+		return err
+	}
+	// Storage rejects keys that could climb out of their directory
+	storageKey := fmt.Sprintf("uploads/%s/%s/%s", upload.TenantID, upload.ID, strings.ReplaceAll(filename, "..", "_"))
+	url, err := s.storage.Upload(ctx, storageKey, data, mimeType)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to store upload")
+	}
+	s.deleteChunks(ctx, upload)
+
+	now := s.now()
+	upload.Status = entity.UploadStatusCompleted
+	upload.StorageKey = storageKey
+	upload.URL = url
+	upload.ResultSize = int64(len(data))
+	upload.ResultMime = mimeType
+	upload.Error = ""
+	upload.CompletedAt = &now
+	upload.UpdatedAt = now
+	if err := s.repo.Update(ctx, upload); err != nil {
+		return err
+	}
+	s.publish(ctx, EventUploadCompleted, upload)
+	return nil
+}
+
+// assemble reads and decrypts the staged chunks of an upload in order
+func (s *UploadService) assemble(ctx context.Context, upload *entity.Upload) ([]byte, error) {
+	key, err := s.chunkKey(ctx, upload)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, 0, upload.Size)
+	for _, offset := range upload.Chunks {
+		length := chunkLength(upload, offset)
+		sealed, _, err := s.storage.Get(ctx, chunkObjectKey(upload, offset, length))
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to read chunk")
+		}
+		chunk, err := encryption.OpenData(key, sealed, chunkAdditionalData(upload, offset, length))
+		if err != nil {
+			return nil, errors.Validation("staged chunk is corrupted")
+		}
+		data = append(data, chunk...)
+	}
+	if int64(len(data)) != upload.Size {
+		return nil, errors.Validation("assembled file does not match the upload length")
+	}
+	return data, nil
+}
+
+// fail records why an upload failed and discards its staged chunks
+func (s *UploadService) fail(ctx context.Context, upload *entity.Upload, status entity.UploadStatus, cause error) {
+	upload.Status = status
+	upload.Error = cause.Error()
+	upload.UpdatedAt = s.now()
+	s.deleteChunks(ctx, upload)
+	if err := s.repo.Update(ctx, upload); err != nil {
+		logger.Warn("Failed to record upload failure", zap.String("upload_id", upload.ID), zap.Error(err))
+	}
+	s.publish(ctx, EventUploadFailed, upload)
+}
+
+// chunkKey opens the key the chunks of an upload are encrypted with
+func (s *UploadService) chunkKey(ctx context.Context, upload *entity.Upload) ([]byte, error) {
+	encoded := upload.EncryptionKey
+	if s.cipher != nil {
+		var err error
+		if encoded, err = s.cipher.Decrypt(ctx, encoded); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to open upload key")
+		}
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != encryption.KeySize {
+		return nil, errors.New(errors.ErrCodeInternal, "invalid upload key")
+	}
+	return key, nil
+}
+
+// deleteChunks removes the staged chunks of an upload
+func (s *UploadService) deleteChunks(ctx context.Context, upload *entity.Upload) {
+	for _, offset := range upload.Chunks {
+		key := chunkObjectKey(upload, offset, chunkLength(upload, offset))
+		if err := s.storage.Delete(ctx, key); err != nil {
+			logger.Warn("Failed to delete upload chunk", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
+
+// deleteObjects removes the staged chunks and the stored file of an upload
+func (s *UploadService) deleteObjects(ctx context.Context, upload *entity.Upload) {
+	if upload.Status != entity.UploadStatusCompleted {
+		s.deleteChunks(ctx, upload)
+	}
+	if upload.StorageKey != "" {
+		if err := s.storage.Delete(ctx, upload.StorageKey); err != nil {
+			logger.Warn("Failed to delete upload", zap.String("upload_id", upload.ID), zap.Error(err))
+		}
+	}
+}
+
+// publish publishes an upload event
+func (s *UploadService) publish(ctx context.Context, eventType string, upload *entity.Upload) {
+	if s.producer == nil {
+		return
+	}
+	if err := s.producer.PublishEvent(ctx, &nats.Event{
+		Type:      eventType,
+		TenantID:  upload.TenantID,
+		Payload:   uploadResult(upload),
+		Timestamp: s.now(),
+	}); err != nil {
+		logger.Warn("Failed to publish upload event", zap.String("upload_id", upload.ID), zap.Error(err))
+	}
+}
+
+// uploadResult describes the outcome of an upload, for job results and events
+func uploadResult(upload *entity.Upload) map[string]interface{} {
+	result := map[string]interface{}{
+		"upload_id":   upload.ID,
+		"filename":    upload.Filename,
+		"purpose":     string(upload.Purpose),
+		"status":      string(upload.Status),
+		"scan_status": string(upload.ScanStatus),
+		"transcoded":  upload.Transcoded,
+	}
+	if upload.URL != "" {
+		result["url"] = upload.URL
+		result["size"] = upload.ResultSize
+		result["mime_type"] = upload.ResultMime
+	}
+	if upload.ScanResult != "" {
+		result["scan_result"] = upload.ScanResult
+	}
+	if upload.Error != "" {
+		result["error"] = upload.Error
+	}
+	return result
+}
+
+// chunkLength returns the length of the chunk staged at offset: up to the next chunk,
+// or to the current offset for the last one
+func chunkLength(upload *entity.Upload, offset int64) int64 {
+	end := upload.Offset
+	for _, next := range upload.Chunks {
+		if next > offset && next < end {
+			end = next
+		}
+	}
+	return end - offset
+}
+
+// chunkObjectKey is the storage key of a staged chunk. The length is part of the key
+// so concurrent writes of different chunks at the same offset never collide.
+func chunkObjectKey(upload *entity.Upload, offset, length int64) string {
+	return fmt.Sprintf("uploads/%s/%s/chunks/%d-%d", upload.TenantID, upload.ID, offset, length)
+}
+
+// chunkAdditionalData binds an encrypted chunk to its upload and position
+func chunkAdditionalData(upload *entity.Upload, offset, length int64) []byte {
+	return []byte(fmt.Sprintf("%s:%d:%d", upload.ID, offset, length))
+}
@@ -0,0 +1,357 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/infrastructure/antivirus"
+	"github.com/msgfy/linktor/internal/infrastructure/storage"
+	"github.com/msgfy/linktor/internal/infrastructure/transcode"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeUploadRepo struct {
+	uploads map[string]*entity.Upload
+}
+
+func newFakeUploadRepo() *fakeUploadRepo {
+	return &fakeUploadRepo{uploads: make(map[string]*entity.Upload)}
+}
+
+func (r *fakeUploadRepo) Create(ctx context.Context, upload *entity.Upload) error {
+	stored := *upload
+	r.uploads[upload.ID] = &stored
+	return nil
+}
+
+func (r *fakeUploadRepo) FindByID(ctx context.Context, id string) (*entity.Upload, error) {
+	upload, ok := r.uploads[id]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, "upload not found")
+	}
+	found := *upload
+	found.Chunks = append([]int64(nil), upload.Chunks...)
+	return &found, nil
+}
+
+func (r *fakeUploadRepo) Update(ctx context.Context, upload *entity.Upload) error {
+	if _, ok := r.uploads[upload.ID]; !ok {
+		return errors.New(errors.ErrCodeNotFound, "upload not found")
+	}
+	stored := *upload
+	stored.Chunks = append([]int64(nil), upload.Chunks...)
+	r.uploads[upload.ID] = &stored
+	return nil
+}
+
+func (r *fakeUploadRepo) AppendChunk(ctx context.Context, id string, offset, length int64) (bool, error) {
+	upload, ok := r.uploads[id]
+	if !ok || upload.Offset != offset || upload.Status != entity.UploadStatusUploading {
+		return false, nil
+	}
+	upload.Offset += length
+	upload.Chunks = append(upload.Chunks, offset)
+	return true, nil
+}
+
+func (r *fakeUploadRepo) ListExpired(ctx context.Context, before time.Time, limit int) ([]*entity.Upload, error) {
+	var expired []*entity.Upload
+	for _, upload := range r.uploads {
+		if upload.Status == entity.UploadStatusUploading && upload.ExpiresAt.Before(before) {
+			expired = append(expired, upload)
+		}
+	}
+	return expired, nil
+}
+
+func (r *fakeUploadRepo) Delete(ctx context.Context, id string) error {
+	delete(r.uploads, id)
+	return nil
+}
+
+type fakeScanner struct {
+	signature string
+}
+
+func (s *fakeScanner) Scan(ctx context.Context, data []byte) (*antivirus.Result, error) {
+	if s.signature != "" {
+		return &antivirus.Result{Infected: true, Signature: s.signature}, nil
+	}
+	return &antivirus.Result{}, nil
+}
+
+type fakeTranscoder struct {
+	profile *transcode.Profile
+}
+
+func (t *fakeTranscoder) Transcode(ctx context.Context, data []byte, profile transcode.Profile) ([]byte, error) {
+	t.profile = &profile
+	return []byte("mp4:" + string(data)), nil
+}
+
+// prefixCipher stands in for tenant encryption by marking sealed values
+type prefixCipher struct{}
+
+func (prefixCipher) Encrypt(ctx context.Context, tenantID, plaintext string) (string, error) {
+	return "sealed:" + tenantID + ":" + plaintext, nil
+}
+
+func (prefixCipher) Decrypt(ctx context.Context, value string) (string, error) {
+	parts := strings.SplitN(value, ":", 3)
+	if len(parts) != 3 || parts[0] != "sealed" {
+		return "", fmt.Errorf("not sealed")
+	}
+	return parts[2], nil
+}
+
+type uploadFixture struct {
+	service  *UploadService
+	repo     *fakeUploadRepo
+	dir      string
+	producer *testutil.MockProducer
+}
+
+func newUploadFixture(t *testing.T) *uploadFixture {
+	f := &uploadFixture{
+		repo:     newFakeUploadRepo(),
+		dir:      t.TempDir(),
+		producer: testutil.NewMockProducer(),
+	}
+	f.service = NewUploadService(f.repo, storage.NewLocalClient(f.dir, "http://files.test"), f.producer)
+	f.service.SetCipher(prefixCipher{})
+	return f
+}
+
+func (f *uploadFixture) stagedChunks(t *testing.T, upload *entity.Upload) []string {
+	t.Helper()
+	entries, err := os.ReadDir(filepath.Join(f.dir, "uploads", upload.TenantID, upload.ID, "chunks"))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	require.NoError(t, err)
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return names
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestUploadService_CreateValidation(t *testing.T) {
+	f := newUploadFixture(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		input CreateUploadInput
+	}{
+		{"missing filename", CreateUploadInput{Size: 10}},
+		{"empty", CreateUploadInput{Filename: "a.pdf"}},
+		{"too large", CreateUploadInput{Filename: "a.pdf", Size: MaxUploadSize + 1}},
+		{"unknown purpose", CreateUploadInput{Filename: "a.pdf", Size: 10, Purpose: "backup"}},
+		{"bad checksum", CreateUploadInput{Filename: "a.pdf", Size: 10, Checksum: "abc"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.service.Create(ctx, "t1", &tt.input)
+			assert.True(t, errors.IsValidation(err), "got %v", err)
+		})
+	}
+
+	upload, err := f.service.Create(ctx, "t1", &CreateUploadInput{Filename: "../docs/guide.pdf", Size: 10})
+	require.NoError(t, err)
+	assert.Equal(t, "guide.pdf", upload.Filename)
+	assert.Equal(t, "application/pdf", upload.MimeType)
+	assert.Equal(t, entity.UploadPurposeMedia, upload.Purpose)
+	assert.Equal(t, entity.UploadStatusUploading, upload.Status)
+	assert.True(t, strings.HasPrefix(upload.EncryptionKey, "sealed:t1:"))
+}
+
+func TestUploadService_ResumableUpload(t *testing.T) {
+	f := newUploadFixture(t)
+	ctx := context.Background()
+	content := []byte("The campaign brochure, in two parts.")
+
+	upload, err := f.service.Create(ctx, "t1", &CreateUploadInput{
+		Filename: "brochure.txt",
+		Purpose:  entity.UploadPurposeCampaign,
+		Size:     int64(len(content)),
+		Checksum: sha256Hex(content),
+	})
+	require.NoError(t, err)
+
+	first, second := content[:10], content[10:]
+	upload, err = f.service.WriteChunk(ctx, "t1", upload.ID, 0, first, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), upload.Offset)
+	assert.Equal(t, entity.UploadStatusUploading, upload.Status)
+
+	// Chunks are staged encrypted
+	staged := f.stagedChunks(t, upload)
+	require.Equal(t, []string{"0-10"}, staged)
+	data, err := os.ReadFile(filepath.Join(f.dir, "uploads", "t1", upload.ID, "chunks", "0-10"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), string(first))
+
+	// Resuming at a stale offset is refused with the current one
+	_, err = f.service.WriteChunk(ctx, "t1", upload.ID, 0, first, nil)
+	appErr := errors.GetAppError(err)
+	require.NotNil(t, appErr)
+	assert.Equal(t, errors.ErrCodeConflict, appErr.Code)
+	assert.Equal(t, "10", appErr.Details["offset"])
+
+	// A corrupted chunk is refused
+	badSum := sha256.Sum256([]byte("something else"))
+	_, err = f.service.WriteChunk(ctx, "t1", upload.ID, 10, second, badSum[:])
+	assert.Equal(t, ErrUploadChecksumMismatch, err)
+
+	// Other tenants cannot see the upload
+	_, err = f.service.WriteChunk(ctx, "t2", upload.ID, 10, second, nil)
+	assert.True(t, errors.IsNotFound(err))
+
+	sum := sha256.Sum256(second)
+	upload, err = f.service.WriteChunk(ctx, "t1", upload.ID, 10, second, sum[:])
+	require.NoError(t, err)
+	assert.Equal(t, entity.UploadStatusCompleted, upload.Status)
+	assert.Equal(t, entity.ScanStatusSkipped, upload.ScanStatus)
+	assert.Equal(t, "http://files.test/uploads/t1/"+upload.ID+"/brochure.txt", upload.URL)
+	assert.Equal(t, int64(len(content)), upload.ResultSize)
+	assert.NotNil(t, upload.CompletedAt)
+
+	stored, err := os.ReadFile(filepath.Join(f.dir, upload.StorageKey))
+	require.NoError(t, err)
+	assert.Equal(t, content, stored)
+	assert.Empty(t, f.stagedChunks(t, upload))
+
+	require.Len(t, f.producer.Events, 1)
+	assert.Equal(t, EventUploadCompleted, f.producer.Events[0].Type)
+	assert.Equal(t, upload.URL, f.producer.Events[0].Payload["url"])
+
+	// Completed uploads take no more chunks
+	_, err = f.service.WriteChunk(ctx, "t1", upload.ID, upload.Size, []byte("x"), nil)
+	assert.Error(t, err)
+}
+
+func TestUploadService_FileChecksumMismatch(t *testing.T) {
+	f := newUploadFixture(t)
+	ctx := context.Background()
+
+	upload, err := f.service.Create(ctx, "t1", &CreateUploadInput{
+		Filename: "notes.txt",
+		Size:     5,
+		Checksum: sha256Hex([]byte("other")),
+	})
+	require.NoError(t, err)
+
+	upload, err = f.service.WriteChunk(ctx, "t1", upload.ID, 0, []byte("hello"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, entity.UploadStatusFailed, upload.Status)
+	assert.Contains(t, upload.Error, "checksum")
+	assert.Empty(t, upload.URL)
+	assert.Empty(t, f.stagedChunks(t, upload))
+
+	require.Len(t, f.producer.Events, 1)
+	assert.Equal(t, EventUploadFailed, f.producer.Events[0].Type)
+}
+
+func TestUploadService_InfectedUpload(t *testing.T) {
+	f := newUploadFixture(t)
+	f.service.SetScanner(&fakeScanner{signature: "Eicar-Test-Signature"})
+	ctx := context.Background()
+
+	upload, err := f.service.Create(ctx, "t1", &CreateUploadInput{Filename: "invoice.pdf", Size: 4})
+	require.NoError(t, err)
+
+	upload, err = f.service.WriteChunk(ctx, "t1", upload.ID, 0, []byte("EICA"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, entity.UploadStatusInfected, upload.Status)
+	assert.Equal(t, entity.ScanStatusInfected, upload.ScanStatus)
+	assert.Equal(t, "Eicar-Test-Signature", upload.ScanResult)
+	assert.Empty(t, upload.URL)
+
+	require.Len(t, f.producer.Events, 1)
+	assert.Equal(t, EventUploadFailed, f.producer.Events[0].Type)
+	assert.Equal(t, "infected", f.producer.Events[0].Payload["status"])
+}
+
+func TestUploadService_TranscodesVideos(t *testing.T) {
+	f := newUploadFixture(t)
+	f.service.SetScanner(&fakeScanner{})
+	transcoder := &fakeTranscoder{}
+	f.service.SetTranscoder(transcoder)
+	ctx := context.Background()
+
+	upload, err := f.service.Create(ctx, "t1", &CreateUploadInput{
+		Filename:    "promo.mov",
+		Size:        5,
+		ChannelType: entity.ChannelTypeWhatsApp,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "video/quicktime", upload.MimeType)
+
+	upload, err = f.service.WriteChunk(ctx, "t1", upload.ID, 0, []byte("video"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, entity.UploadStatusCompleted, upload.Status)
+	assert.Equal(t, entity.ScanStatusClean, upload.ScanStatus)
+	assert.True(t, upload.Transcoded)
+	assert.Equal(t, "video/mp4", upload.ResultMime)
+	assert.True(t, strings.HasSuffix(upload.StorageKey, "/promo.mp4"))
+	require.NotNil(t, transcoder.profile)
+	assert.Equal(t, transcode.ProfileFor("whatsapp"), *transcoder.profile)
+
+	// MP4 videos within the channel's limits are kept as they are
+	transcoder.profile = nil
+	upload, err = f.service.Create(ctx, "t1", &CreateUploadInput{Filename: "clip.mp4", Size: 5})
+	require.NoError(t, err)
+	upload, err = f.service.WriteChunk(ctx, "t1", upload.ID, 0, []byte("video"), nil)
+	require.NoError(t, err)
+	assert.False(t, upload.Transcoded)
+	assert.Nil(t, transcoder.profile)
+}
+
+func TestUploadService_TerminateAndCleanup(t *testing.T) {
+	f := newUploadFixture(t)
+	ctx := context.Background()
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	f.service.now = func() time.Time { return now }
+
+	terminated, err := f.service.Create(ctx, "t1", &CreateUploadInput{Filename: "a.bin", Size: 10})
+	require.NoError(t, err)
+	_, err = f.service.WriteChunk(ctx, "t1", terminated.ID, 0, []byte("12345"), nil)
+	require.NoError(t, err)
+	require.NoError(t, f.service.Terminate(ctx, "t1", terminated.ID))
+	assert.Empty(t, f.stagedChunks(t, terminated))
+	_, err = f.service.Get(ctx, "t1", terminated.ID)
+	assert.True(t, errors.IsNotFound(err))
+
+	expired, err := f.service.Create(ctx, "t1", &CreateUploadInput{Filename: "b.bin", Size: 10})
+	require.NoError(t, err)
+	_, err = f.service.WriteChunk(ctx, "t1", expired.ID, 0, []byte("12345"), nil)
+	require.NoError(t, err)
+
+	// Past its expiry the upload cannot be resumed and is cleaned up
+	now = now.Add(UploadExpiry + time.Minute)
+	_, err = f.service.WriteChunk(ctx, "t1", expired.ID, 5, []byte("67890"), nil)
+	assert.True(t, errors.IsNotFound(err))
+
+	removed, err := f.service.CleanupExpired(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.Empty(t, f.stagedChunks(t, expired))
+	assert.Empty(t, f.repo.uploads)
+}
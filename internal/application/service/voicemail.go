@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/adapters/voice"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// SpeechTranscriber turns recorded speech into text
+type SpeechTranscriber interface {
+	Transcribe(ctx context.Context, audio []byte, filename, language string) (string, error)
+}
+
+// VoicemailService posts the voicemails of unanswered voice calls to the caller's
+// conversation. The recording is kept in media storage and its transcription becomes
+// the message text, so agents handle voicemails like any other inbound message.
+type VoicemailService struct {
+	media       *MediaService
+	transcriber SpeechTranscriber
+	producer    nats.Publisher
+}
+
+// NewVoicemailService creates a new voicemail service. transcriber may be nil, in
+// which case voicemails are posted without text.
+func NewVoicemailService(media *MediaService, transcriber SpeechTranscriber, producer nats.Publisher) *VoicemailService {
+	return &VoicemailService{
+		media:       media,
+		transcriber: transcriber,
+		producer:    producer,
+	}
+}
+
+// Handler returns the voicemail handler of a voice channel, to set on its adapter
+func (s *VoicemailService) Handler(channel *entity.Channel) voice.VoicemailHandler {
+	return &channelVoicemailHandler{service: s, channel: channel}
+}
+
+type channelVoicemailHandler struct {
+	service *VoicemailService
+	channel *entity.Channel
+}
+
+func (h *channelVoicemailHandler) HandleVoicemail(ctx context.Context, voicemail *voice.Voicemail, audio []byte, mimeType string) error {
+	return h.service.Deliver(ctx, h.channel, voicemail, audio, mimeType)
+}
+
+// Deliver stores and transcribes a voicemail and publishes it as an inbound message of
+// the channel. A failed transcription does not hold the voicemail back.
+func (s *VoicemailService) Deliver(ctx context.Context, channel *entity.Channel, voicemail *voice.Voicemail, audio []byte, mimeType string) error {
+	messageID := uuid.New().String()
+	filename := fmt.Sprintf("voicemail-%s.wav", voicemail.CallID)
+
+	attachment := &entity.MessageAttachment{
+		Type:     "audio",
+		Filename: filename,
+		MimeType: mimeType,
+	}
+	if err := s.media.Store(ctx, channel.ID, messageID, attachment, audio); err != nil {
+		return fmt.Errorf("failed to store voicemail: %w", err)
+	}
+	attachment.Metadata["duration"] = strconv.Itoa(voicemail.Duration)
+
+	var transcription string
+	if s.transcriber != nil {
+		text, err := s.transcriber.Transcribe(ctx, audio, filename, voicemail.Language)
+		if err != nil {
+			logger.Warn("Failed to transcribe voicemail",
+				zap.String("channel_id", channel.ID),
+				zap.String("call_id", voicemail.CallID),
+				zap.Error(err),
+			)
+		} else {
+			transcription = text
+		}
+	}
+
+	timestamp := voicemail.RequestedAt
+	if voicemail.RecordedAt != nil {
+		timestamp = *voicemail.RecordedAt
+	}
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	inbound := &nats.InboundMessage{
+		ID:          messageID,
+		TenantID:    channel.TenantID,
+		ChannelID:   channel.ID,
+		ChannelType: string(channel.Type),
+		ExternalID:  voicemail.ID,
+		ContentType: string(entity.ContentTypeAudio),
+		Content:     transcription,
+		Metadata: map[string]string{
+			"sender_id":     voicemail.From,
+			"sender_name":   voicemail.CallerName,
+			"phone":         voicemail.From,
+			"source":        "voicemail",
+			"call_id":       voicemail.CallID,
+			"queue":         voicemail.Queue,
+			"duration":      strconv.Itoa(voicemail.Duration),
+			"transcription": transcription,
+		},
+		Attachments: []nats.AttachmentData{{
+			Type:      attachment.Type,
+			URL:       attachment.URL,
+			Filename:  attachment.Filename,
+			MimeType:  attachment.MimeType,
+			SizeBytes: attachment.SizeBytes,
+			Metadata:  attachment.Metadata,
+		}},
+		Timestamp: timestamp,
+	}
+
+	if err := s.producer.PublishInbound(ctx, inbound); err != nil {
+		return fmt.Errorf("failed to publish voicemail: %w", err)
+	}
+	return nil
+}
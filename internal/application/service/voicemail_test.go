@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/adapters/voice"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTranscriber struct {
+	text     string
+	err      error
+	language string
+}
+
+func (f *fakeTranscriber) Transcribe(ctx context.Context, audio []byte, filename, language string) (string, error) {
+	f.language = language
+	return f.text, f.err
+}
+
+func TestVoicemailService_Deliver(t *testing.T) {
+	media, _ := newMediaTestService(t)
+	transcriber := &fakeTranscriber{text: "Olá, preciso de ajuda com meu pedido."}
+	producer := testutil.NewMockProducer()
+	svc := NewVoicemailService(media, transcriber, producer)
+
+	ctx := context.Background()
+	channel := &entity.Channel{ID: "channel-1", TenantID: "tenant-1", Type: entity.ChannelTypeVoice}
+	recordedAt := time.Now()
+	voicemail := &voice.Voicemail{
+		ID:         "vm-1",
+		CallID:     "call-1",
+		Queue:      "support",
+		From:       "+5511999999999",
+		CallerName: "Maria",
+		Language:   "pt-BR",
+		Duration:   14,
+		RecordedAt: &recordedAt,
+	}
+
+	require.NoError(t, svc.Handler(channel).HandleVoicemail(ctx, voicemail, []byte("RIFF-voicemail"), "audio/wav"))
+	assert.Equal(t, "pt-BR", transcriber.language)
+
+	require.Len(t, producer.InboundMessages, 1)
+	inbound := producer.InboundMessages[0]
+	assert.Equal(t, "tenant-1", inbound.TenantID)
+	assert.Equal(t, "voice", inbound.ChannelType)
+	assert.Equal(t, "audio", inbound.ContentType)
+	assert.Equal(t, "Olá, preciso de ajuda com meu pedido.", inbound.Content)
+	assert.Equal(t, "+5511999999999", inbound.Metadata["phone"])
+	assert.Equal(t, "Maria", inbound.Metadata["sender_name"])
+	assert.Equal(t, "voicemail", inbound.Metadata["source"])
+	assert.True(t, inbound.Timestamp.Equal(recordedAt))
+
+	require.Len(t, inbound.Attachments, 1)
+	attachment := inbound.Attachments[0]
+	assert.Equal(t, "audio/wav", attachment.MimeType)
+	assert.Equal(t, "14", attachment.Metadata["duration"])
+
+	key, expires, signature := mediaKey(t, attachment.URL)
+	assert.Equal(t, key, attachment.Metadata["storage_key"])
+	data, _, err := media.Open(ctx, key, expires, signature)
+	require.NoError(t, err)
+	assert.Equal(t, "RIFF-voicemail", string(data))
+}
+
+func TestVoicemailService_Deliver_TranscriptionFails(t *testing.T) {
+	media, _ := newMediaTestService(t)
+	producer := testutil.NewMockProducer()
+	svc := NewVoicemailService(media, &fakeTranscriber{err: fmt.Errorf("service unavailable")}, producer)
+
+	channel := &entity.Channel{ID: "channel-1", TenantID: "tenant-1", Type: entity.ChannelTypeVoice}
+	err := svc.Deliver(context.Background(), channel, &voice.Voicemail{ID: "vm-1", CallID: "call-1", From: "+5511999999999"}, []byte("RIFF-voicemail"), "audio/wav")
+	require.NoError(t, err)
+
+	require.Len(t, producer.InboundMessages, 1)
+	assert.Empty(t, producer.InboundMessages[0].Content)
+	assert.Len(t, producer.InboundMessages[0].Attachments, 1)
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
 	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
 	"github.com/msgfy/linktor/internal/infrastructure/storage"
 	"github.com/msgfy/linktor/internal/infrastructure/vre"
 )
@@ -26,6 +27,9 @@ type VREService struct {
 	cacheTTL    time.Duration
 	cachePrefix string
 	storage     storage.Client
+	producer    nats.Publisher
+	callbacks   WebhookSender
+	usage       UsageRecorder
 }
 
 // VREServiceConfig holds configuration for VREService
@@ -147,6 +151,10 @@ func (s *VREService) Render(ctx context.Context, req *entity.RenderRequest) (*en
 		return nil, fmt.Errorf("failed to render image: %w", err)
 	}
 
+	if s.usage != nil {
+		s.usage.RecordUsage(req.TenantID, entity.QuotaMetricVRERenders, "", 1)
+	}
+
 	// Get image dimensions
 	width, height, _ := vre.GetImageDimensions(imageData)
 
@@ -200,6 +208,11 @@ func (s *VREService) SetStorageClient(storageClient storage.Client) {
 	s.storage = storageClient
 }
 
+// SetUsageRecorder meters renders against the tenant's render quota. Cache hits are free.
+func (s *VREService) SetUsageRecorder(usage UsageRecorder) {
+	s.usage = usage
+}
+
 // RenderToURL renders and uploads to storage, returning a public URL
 func (s *VREService) RenderToURL(ctx context.Context, req *entity.RenderRequest) (*entity.RenderResponse, error) {
 	// Render the image first
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/infrastructure/events"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/internal/infrastructure/webhook"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// JobTypeVRERender renders a VRE image in the background
+const JobTypeVRERender = "vre.render"
+
+// EventVRERenderCompleted is published when an async render finished, successfully or not
+const EventVRERenderCompleted = "vre.render.completed"
+
+// vreCallbackMaxRetries is how often a render callback is attempted
+const vreCallbackMaxRetries = 3
+
+// VRERenderCallback is the endpoint notified when an async render finished. The
+// notification is signed in the X-Linktor-Signature header when Secret is set.
+type VRERenderCallback struct {
+	URL    string `json:"url"`
+	Secret string `json:"secret,omitempty"`
+}
+
+// vreRenderJob is the payload of a render job
+type vreRenderJob struct {
+	Request  *entity.RenderRequest `json:"request"`
+	Callback *VRERenderCallback    `json:"callback,omitempty"`
+}
+
+// SetProducer enables publishing render completion events, which tenants can
+// subscribe webhooks to
+func (s *VREService) SetProducer(producer nats.Publisher) {
+	s.producer = producer
+}
+
+// SetCallbackSender sets the sender of render callbacks
+func (s *VREService) SetCallbackSender(sender WebhookSender) {
+	s.callbacks = sender
+}
+
+// VRERenderJobPayload validates an async render request and builds its job payload
+func VRERenderJobPayload(req *entity.RenderRequest, callback *VRERenderCallback) (map[string]interface{}, error) {
+	if err := req.Validate(); err != nil {
+		return nil, errors.Validation(err.Error())
+	}
+	if callback != nil {
+		callback.URL = strings.TrimSpace(callback.URL)
+		if callback.URL == "" {
+			callback = nil
+		} else if !isWebhookURL(callback.URL) {
+			return nil, errors.Validation("callback_url must be an http or https URL")
+		}
+	}
+
+	data, err := json.Marshal(&vreRenderJob{Request: req, Callback: callback})
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to encode render job")
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to encode render job")
+	}
+	return payload, nil
+}
+
+// RunRenderJob renders the image of a render job and uploads it to storage. The
+// image is returned inline as base64 only when no storage is configured. Once the
+// job is finished, its callback is notified and a completion event published.
+func (s *VREService) RunRenderJob(ctx context.Context, job *entity.Job, progress JobProgress) (map[string]interface{}, error) {
+	data, err := json.Marshal(job.Payload)
+	if err != nil {
+		return nil, errors.Validation("invalid render job payload")
+	}
+	var payload vreRenderJob
+	if err := json.Unmarshal(data, &payload); err != nil || payload.Request == nil {
+		return nil, errors.Validation("invalid render job payload")
+	}
+	payload.Request.TenantID = job.TenantID
+
+	if err := progress(10, "rendering"); err != nil {
+		return nil, err
+	}
+	response, err := s.RenderToURL(ctx, payload.Request)
+	if err != nil {
+		if errors.IsValidation(err) || !job.CanRetry() {
+			s.renderCompleted(ctx, job, payload.Callback, nil, err)
+		}
+		return nil, err
+	}
+
+	result := map[string]interface{}{
+		"image_url":      response.ImageURL,
+		"caption":        response.Caption,
+		"width":          response.Width,
+		"height":         response.Height,
+		"format":         string(response.Format),
+		"size_bytes":     response.SizeBytes,
+		"render_time_ms": response.RenderTime.Milliseconds(),
+	}
+	if response.ImageURL == "" {
+		result["image_base64"] = response.ImageBase64
+	}
+	s.renderCompleted(ctx, job, payload.Callback, response, nil)
+	return result, nil
+}
+
+// renderCompleted publishes the completion event of a render job and notifies its
+// callback. The image is only referenced by URL, never sent inline.
+func (s *VREService) renderCompleted(ctx context.Context, job *entity.Job, callback *VRERenderCallback, response *entity.RenderResponse, renderErr error) {
+	data := map[string]interface{}{
+		"job_id": job.ID,
+		"status": string(entity.JobStatusSucceeded),
+	}
+	if renderErr != nil {
+		data["status"] = string(entity.JobStatusFailed)
+		data["error"] = renderErr.Error()
+	} else {
+		data["image_url"] = response.ImageURL
+		data["width"] = response.Width
+		data["height"] = response.Height
+		data["format"] = string(response.Format)
+	}
+	now := time.Now()
+
+	if s.producer != nil {
+		if err := s.producer.PublishEvent(ctx, &nats.Event{
+			Type:      EventVRERenderCompleted,
+			TenantID:  job.TenantID,
+			Payload:   data,
+			Timestamp: now,
+		}); err != nil {
+			logger.Warn("Failed to publish render completion", zap.String("job_id", job.ID), zap.Error(err))
+		}
+	}
+
+	if callback == nil || s.callbacks == nil {
+		return
+	}
+	envelope := webhook.NewEnvelope(&events.EventPayload{
+		TenantID:  job.TenantID,
+		EventType: entity.WebhookEventVRERenderCompleted,
+		Payload:   data,
+		Timestamp: now,
+	})
+	_, err := s.callbacks.DeliverEnvelope(ctx, webhook.EndpointConfig{
+		URL:            callback.URL,
+		MaxRetries:     vreCallbackMaxRetries,
+		TimeoutSeconds: webhookDeliveryTimeoutSeconds,
+		Secret:         callback.Secret,
+	}, envelope)
+	if err != nil {
+		logger.Warn("Failed to notify render callback", zap.String("job_id", job.ID), zap.Error(err))
+	}
+}
@@ -1,9 +1,13 @@
 package service
 
 import (
+	"context"
 	"testing"
 
 	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/infrastructure/webhook"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
 )
 
 func TestRenderRequest_Validate(t *testing.T) {
@@ -293,3 +297,91 @@ func TestEncodeBase64(t *testing.T) {
 		})
 	}
 }
+
+// recordingWebhookSender records the envelopes it is asked to deliver
+type recordingWebhookSender struct {
+	endpoints []webhook.EndpointConfig
+	envelopes []*webhook.Envelope
+}
+
+func (r *recordingWebhookSender) DeliverEnvelope(ctx context.Context, endpoint webhook.EndpointConfig, envelope *webhook.Envelope) (*webhook.DeliveryResult, error) {
+	r.endpoints = append(r.endpoints, endpoint)
+	r.envelopes = append(r.envelopes, envelope)
+	return &webhook.DeliveryResult{StatusCode: 200}, nil
+}
+
+func TestVRERenderJobPayload(t *testing.T) {
+	_, err := VRERenderJobPayload(&entity.RenderRequest{TenantID: "tenant-1"}, nil)
+	if !errors.IsValidation(err) {
+		t.Errorf("missing template: error = %v, want validation error", err)
+	}
+
+	req := &entity.RenderRequest{TenantID: "tenant-1", TemplateID: "card_produto"}
+	_, err = VRERenderJobPayload(req, &VRERenderCallback{URL: "ftp://example.com/done"})
+	if !errors.IsValidation(err) {
+		t.Errorf("invalid callback: error = %v, want validation error", err)
+	}
+
+	payload, err := VRERenderJobPayload(req, &VRERenderCallback{URL: " https://example.com/done ", Secret: "s3cret"})
+	if err != nil {
+		t.Fatalf("VRERenderJobPayload() error = %v", err)
+	}
+	callback, _ := payload["callback"].(map[string]interface{})
+	if callback["url"] != "https://example.com/done" {
+		t.Errorf("callback url = %v, want trimmed URL", callback["url"])
+	}
+	request, _ := payload["request"].(map[string]interface{})
+	if request["template_id"] != "card_produto" {
+		t.Errorf("request template_id = %v, want card_produto", request["template_id"])
+	}
+}
+
+func TestVREService_RunRenderJobRejectsInvalidPayload(t *testing.T) {
+	s := &VREService{}
+	job := &entity.Job{ID: "job-1", TenantID: "tenant-1", Payload: map[string]interface{}{"request": "not an object"}}
+
+	_, err := s.RunRenderJob(context.Background(), job, func(int, string) error { return nil })
+	if !errors.IsValidation(err) {
+		t.Errorf("RunRenderJob() error = %v, want validation error", err)
+	}
+}
+
+func TestVREService_RenderCompletedNotifiesCallback(t *testing.T) {
+	producer := testutil.NewMockProducer()
+	sender := &recordingWebhookSender{}
+	s := &VREService{}
+	s.SetProducer(producer)
+	s.SetCallbackSender(sender)
+
+	job := &entity.Job{ID: "job-1", TenantID: "tenant-1"}
+	response := &entity.RenderResponse{ImageURL: "https://cdn.example.com/vre/1.png", ImageBase64: "data:image/png;base64,AAAA", Width: 800, Format: entity.OutputFormatPNG}
+	s.renderCompleted(context.Background(), job, &VRERenderCallback{URL: "https://example.com/done", Secret: "s3cret"}, response, nil)
+
+	if len(producer.Events) != 1 || producer.Events[0].Type != EventVRERenderCompleted {
+		t.Fatalf("events = %v, want one %s event", producer.Events, EventVRERenderCompleted)
+	}
+	if len(sender.envelopes) != 1 {
+		t.Fatalf("callbacks = %d, want 1", len(sender.envelopes))
+	}
+	envelope := sender.envelopes[0]
+	if envelope.Type != entity.WebhookEventVRERenderCompleted || envelope.TenantID != "tenant-1" {
+		t.Errorf("envelope = %s for %s, want %s for tenant-1", envelope.Type, envelope.TenantID, entity.WebhookEventVRERenderCompleted)
+	}
+	if envelope.Data["image_url"] != response.ImageURL || envelope.Data["status"] != string(entity.JobStatusSucceeded) {
+		t.Errorf("envelope data = %v, want image URL and succeeded status", envelope.Data)
+	}
+	if _, ok := envelope.Data["image_base64"]; ok {
+		t.Error("envelope data carries the image inline")
+	}
+	if sender.endpoints[0].URL != "https://example.com/done" || sender.endpoints[0].Secret != "s3cret" {
+		t.Errorf("endpoint = %+v, want callback URL and secret", sender.endpoints[0])
+	}
+
+	s.renderCompleted(context.Background(), job, nil, nil, errors.New(errors.ErrCodeInternal, "chrome crashed"))
+	if len(sender.envelopes) != 1 {
+		t.Errorf("callbacks = %d, want no callback without URL", len(sender.envelopes))
+	}
+	if producer.Events[1].Payload["status"] != string(entity.JobStatusFailed) {
+		t.Errorf("failure event status = %v, want failed", producer.Events[1].Payload["status"])
+	}
+}
@@ -0,0 +1,778 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/adapters/wallet"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/egress"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// MessageMetaWalletPassID marks messages that deliver a wallet pass
+const MessageMetaWalletPassID = "wallet_pass_id"
+
+// Formats a wallet pass is sent in
+const (
+	WalletPassFormatLink = "link" // Add-to-wallet links in a text message
+	WalletPassFormatFile = "file" // .pkpass document, with the Google Wallet link as caption
+)
+
+const (
+	walletPassTokenBytes = 20
+	walletPassLogoLimit  = 512 * 1024
+)
+
+// ApplePassIssuer builds signed Apple Wallet passes and tells devices about updates
+type ApplePassIssuer interface {
+	PassTypeID() string
+	Build(pass *wallet.Pass) ([]byte, error)
+	Push(ctx context.Context, pushToken string) error
+}
+
+// GooglePassIssuer creates Google Wallet save links and updates saved passes
+type GooglePassIssuer interface {
+	SaveURL(pass *wallet.Pass) (string, error)
+	UpdateObject(ctx context.Context, pass *wallet.Pass) error
+}
+
+// WalletPassTemplateInput represents a request to create or update a wallet pass
+// template. Kind and object type are only read on creation.
+type WalletPassTemplateInput struct {
+	Name             string                   `json:"name" binding:"required"`
+	Kind             entity.WalletPassKind    `json:"kind"`
+	ObjectType       entity.LinkedObjectType  `json:"object_type,omitempty"`
+	OrganizationName string                   `json:"organization_name" binding:"required"`
+	Description      string                   `json:"description" binding:"required"`
+	LogoText         string                   `json:"logo_text,omitempty"`
+	LogoURL          string                   `json:"logo_url,omitempty"`
+	BackgroundColor  string                   `json:"background_color,omitempty"`
+	ForegroundColor  string                   `json:"foreground_color,omitempty"`
+	LabelColor       string                   `json:"label_color,omitempty"`
+	Fields           []entity.WalletPassField `json:"fields,omitempty"`
+	BarcodeFormat    string                   `json:"barcode_format,omitempty"`
+	BarcodeMessage   string                   `json:"barcode_message,omitempty"`
+	DateAttribute    string                   `json:"date_attribute,omitempty"`
+	VoidStatuses     []string                 `json:"void_statuses,omitempty"`
+	IsActive         *bool                    `json:"is_active,omitempty"`
+}
+
+// IssueWalletPassInput represents a request to issue a pass. Booking passes are issued
+// for a linked object, loyalty cards for a contact.
+type IssueWalletPassInput struct {
+	TemplateID     string `json:"template_id" binding:"required"`
+	LinkedObjectID string `json:"linked_object_id,omitempty"`
+	ContactID      string `json:"contact_id,omitempty"`
+}
+
+// SendWalletPassInput represents a request to send a pass to a conversation
+type SendWalletPassInput struct {
+	ConversationID string `json:"conversation_id" binding:"required"`
+	Format         string `json:"format,omitempty"` // link (default) or file
+	Message        string `json:"message,omitempty"`
+	SenderID       string `json:"-"`
+}
+
+// WalletPassLinks are the links that add a pass to each wallet
+type WalletPassLinks struct {
+	AppleURL  string `json:"apple_url,omitempty"`
+	GoogleURL string `json:"google_url,omitempty"`
+}
+
+// IssuedWalletPass is a pass together with its add-to-wallet links
+type IssuedWalletPass struct {
+	*entity.WalletPass
+	Links WalletPassLinks `json:"links"`
+}
+
+// WalletPassService issues Apple Wallet and Google Wallet passes from tenant templates,
+// such as booking confirmations and loyalty cards, sends them through any channel and
+// pushes updates to the wallets holding them when their linked object changes
+type WalletPassService struct {
+	templateRepo     repository.WalletPassTemplateRepository
+	passRepo         repository.WalletPassRepository
+	objectRepo       repository.LinkedObjectRepository
+	contactRepo      repository.ContactRepository
+	conversationRepo repository.ConversationRepository
+	messageService   *MessageService
+	baseURL          string
+	apple            ApplePassIssuer
+	google           GooglePassIssuer
+	httpClient       *http.Client
+	checkURL         func(ctx context.Context, endpoint string) error
+}
+
+// NewWalletPassService creates a new wallet pass service. baseURL is the public URL of
+// Linktor, which serves the passes and the Apple Wallet web service.
+func NewWalletPassService(
+	templateRepo repository.WalletPassTemplateRepository,
+	passRepo repository.WalletPassRepository,
+	objectRepo repository.LinkedObjectRepository,
+	contactRepo repository.ContactRepository,
+	conversationRepo repository.ConversationRepository,
+	messageService *MessageService,
+	baseURL string,
+) *WalletPassService {
+	return &WalletPassService{
+		templateRepo:     templateRepo,
+		passRepo:         passRepo,
+		objectRepo:       objectRepo,
+		contactRepo:      contactRepo,
+		conversationRepo: conversationRepo,
+		messageService:   messageService,
+		baseURL:          strings.TrimSuffix(baseURL, "/"),
+		httpClient:       newWalletPassLogoClient(),
+		checkURL:         egress.CheckURL,
+	}
+}
+
+// newWalletPassLogoClient creates the client fetching template logos, which refuses
+// internal addresses as tenants choose the logo URL
+func newWalletPassLogoClient() *http.Client {
+	client := egress.NewClient()
+	client.Timeout = 10 * time.Second
+	return client
+}
+
+// SetAppleIssuer enables Apple Wallet passes
+func (s *WalletPassService) SetAppleIssuer(issuer ApplePassIssuer) {
+	s.apple = issuer
+}
+
+// SetGoogleIssuer enables Google Wallet passes
+func (s *WalletPassService) SetGoogleIssuer(issuer GooglePassIssuer) {
+	s.google = issuer
+}
+
+// CreateTemplate creates a pass template
+func (s *WalletPassService) CreateTemplate(ctx context.Context, tenantID string, input *WalletPassTemplateInput) (*entity.WalletPassTemplate, error) {
+	now := time.Now()
+	template := &entity.WalletPassTemplate{
+		ID:         uuid.New().String(),
+		TenantID:   tenantID,
+		Kind:       input.Kind,
+		ObjectType: input.ObjectType,
+		IsActive:   true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := applyWalletPassTemplateInput(template, input); err != nil {
+		return nil, err
+	}
+	if err := s.checkLogoURL(ctx, template.LogoURL); err != nil {
+		return nil, err
+	}
+
+	if err := s.templateRepo.Create(ctx, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// GetTemplate returns a pass template of the tenant
+func (s *WalletPassService) GetTemplate(ctx context.Context, tenantID, id string) (*entity.WalletPassTemplate, error) {
+	template, err := s.templateRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if template.TenantID != tenantID {
+		return nil, errors.NotFound("wallet pass template")
+	}
+	return template, nil
+}
+
+// ListTemplates lists the pass templates of a tenant
+func (s *WalletPassService) ListTemplates(ctx context.Context, tenantID string) ([]*entity.WalletPassTemplate, error) {
+	templates, err := s.templateRepo.FindByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if templates == nil {
+		templates = []*entity.WalletPassTemplate{}
+	}
+	return templates, nil
+}
+
+// UpdateTemplate updates a pass template. Issued passes pick the changes up the next
+// time they are downloaded or their linked object changes.
+func (s *WalletPassService) UpdateTemplate(ctx context.Context, tenantID, id string, input *WalletPassTemplateInput) (*entity.WalletPassTemplate, error) {
+	template, err := s.GetTemplate(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if (input.Kind != "" && input.Kind != template.Kind) || (input.ObjectType != "" && input.ObjectType != template.ObjectType) {
+		return nil, errors.Validation("the kind and object type of a template cannot be changed")
+	}
+
+	if err := applyWalletPassTemplateInput(template, input); err != nil {
+		return nil, err
+	}
+	if err := s.checkLogoURL(ctx, template.LogoURL); err != nil {
+		return nil, err
+	}
+	template.UpdatedAt = time.Now()
+
+	if err := s.templateRepo.Update(ctx, template); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// DeleteTemplate deletes a pass template and the passes it issued
+func (s *WalletPassService) DeleteTemplate(ctx context.Context, tenantID, id string) error {
+	if _, err := s.GetTemplate(ctx, tenantID, id); err != nil {
+		return err
+	}
+	return s.templateRepo.Delete(ctx, id)
+}
+
+// checkLogoURL refuses logo URLs on internal addresses, as logos are fetched to build
+// Apple Wallet passes
+func (s *WalletPassService) checkLogoURL(ctx context.Context, logoURL string) error {
+	if logoURL == "" {
+		return nil
+	}
+	if err := s.checkURL(ctx, logoURL); err != nil {
+		return errors.Validation("logo_url must not point to a private, loopback or link-local address")
+	}
+	return nil
+}
+
+func applyWalletPassTemplateInput(template *entity.WalletPassTemplate, input *WalletPassTemplateInput) error {
+	template.Name = strings.TrimSpace(input.Name)
+	template.OrganizationName = strings.TrimSpace(input.OrganizationName)
+	template.Description = strings.TrimSpace(input.Description)
+	template.LogoText = input.LogoText
+	template.LogoURL = input.LogoURL
+	template.BackgroundColor = strings.ToUpper(input.BackgroundColor)
+	template.ForegroundColor = strings.ToUpper(input.ForegroundColor)
+	template.LabelColor = strings.ToUpper(input.LabelColor)
+	template.Fields = input.Fields
+	template.BarcodeFormat = firstNonEmpty(input.BarcodeFormat, string(wallet.BarcodeQR))
+	template.BarcodeMessage = input.BarcodeMessage
+	template.DateAttribute = input.DateAttribute
+	template.VoidStatuses = input.VoidStatuses
+	if input.IsActive != nil {
+		template.IsActive = *input.IsActive
+	}
+
+	if err := template.Validate(); err != nil {
+		return errors.Validation(err.Error())
+	}
+	if !wallet.IsValidBarcodeFormat(wallet.BarcodeFormat(template.BarcodeFormat)) {
+		return errors.Validation("barcode format must be qr, pdf417, aztec or code128")
+	}
+	for _, color := range []string{template.BackgroundColor, template.ForegroundColor, template.LabelColor} {
+		if color != "" && !wallet.IsValidColor(color) {
+			return errors.Validation("colors must be in #RRGGBB form")
+		}
+	}
+	if template.Kind == entity.WalletPassKindLoyalty && template.LogoURL == "" {
+		return errors.Validation("loyalty cards require a logo URL")
+	}
+	return nil
+}
+
+// Issue issues a pass from a template. A linked object or contact gets a single pass
+// per template, so issuing again returns the existing pass.
+func (s *WalletPassService) Issue(ctx context.Context, tenantID string, input *IssueWalletPassInput) (*IssuedWalletPass, error) {
+	template, err := s.GetTemplate(ctx, tenantID, input.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if !template.IsActive {
+		return nil, errors.Validation("wallet pass template is inactive")
+	}
+
+	var object *entity.LinkedObject
+	var existing []*entity.WalletPass
+	contactID := input.ContactID
+	switch template.Kind {
+	case entity.WalletPassKindBooking:
+		if input.LinkedObjectID == "" {
+			return nil, errors.Validation("booking passes are issued for a linked object")
+		}
+		object, err = s.objectRepo.FindByID(ctx, input.LinkedObjectID)
+		if err != nil || object.TenantID != tenantID {
+			return nil, errors.NotFound("linked object")
+		}
+		if object.Type != template.ObjectType {
+			return nil, errors.Validation(fmt.Sprintf("template issues passes for %s objects", template.ObjectType))
+		}
+		contactID = firstNonEmpty(object.ContactID, contactID)
+		existing, err = s.passRepo.FindByLinkedObject(ctx, object.ID)
+	default:
+		if contactID == "" {
+			return nil, errors.Validation("loyalty cards are issued for a contact")
+		}
+		existing, err = s.passRepo.FindByContact(ctx, contactID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	contact, err := s.loadContact(ctx, tenantID, contactID)
+	if err != nil {
+		return nil, err
+	}
+	for _, pass := range existing {
+		if pass.TemplateID == template.ID {
+			return s.withLinks(ctx, template, pass, contact), nil
+		}
+	}
+
+	now := time.Now()
+	pass := &entity.WalletPass{
+		ID:         uuid.New().String(),
+		TenantID:   tenantID,
+		TemplateID: template.ID,
+		ContactID:  contactID,
+		AuthToken:  newWalletPassToken(),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if object != nil {
+		pass.LinkedObjectID = object.ID
+	}
+	renderWalletPass(template, pass, object, contact)
+
+	if err := s.passRepo.Create(ctx, pass); err != nil {
+		return nil, err
+	}
+	return s.withLinks(ctx, template, pass, contact), nil
+}
+
+// Get returns a pass of the tenant with its add-to-wallet links
+func (s *WalletPassService) Get(ctx context.Context, tenantID, id string) (*IssuedWalletPass, error) {
+	pass, err := s.passRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if pass.TenantID != tenantID {
+		return nil, errors.NotFound("wallet pass")
+	}
+	template, err := s.templateRepo.FindByID(ctx, pass.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	contact, _ := s.loadContact(ctx, tenantID, pass.ContactID)
+	return s.withLinks(ctx, template, pass, contact), nil
+}
+
+// Send sends a pass to a conversation on its channel, as add-to-wallet links or as a
+// .pkpass file
+func (s *WalletPassService) Send(ctx context.Context, tenantID, id string, input *SendWalletPassInput) (*entity.Message, error) {
+	issued, err := s.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	conversation, err := s.conversationRepo.FindByID(ctx, input.ConversationID)
+	if err != nil || conversation.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+	}
+
+	links := issued.Links
+	if links.AppleURL == "" && links.GoogleURL == "" {
+		return nil, errors.New(errors.ErrCodeBadRequest, "no wallet is configured")
+	}
+
+	text := firstNonEmpty(strings.TrimSpace(input.Message), "Adicione seu passe à carteira do celular:")
+	sendInput := &SendMessageInput{
+		TenantID:       tenantID,
+		ConversationID: conversation.ID,
+		SenderID:       input.SenderID,
+		SenderType:     string(entity.SenderTypeUser),
+		ContentType:    string(entity.ContentTypeText),
+		Metadata:       map[string]string{MessageMetaWalletPassID: issued.ID},
+	}
+	if input.SenderID == "" {
+		sendInput.SenderType = string(entity.SenderTypeSystem)
+	}
+
+	switch firstNonEmpty(input.Format, WalletPassFormatLink) {
+	case WalletPassFormatLink:
+		lines := []string{text}
+		if links.AppleURL != "" {
+			lines = append(lines, "Apple Wallet: "+links.AppleURL)
+		}
+		if links.GoogleURL != "" {
+			lines = append(lines, "Google Wallet: "+links.GoogleURL)
+		}
+		sendInput.Content = strings.Join(lines, "\n")
+	case WalletPassFormatFile:
+		if links.AppleURL == "" {
+			return nil, errors.New(errors.ErrCodeBadRequest, "Apple Wallet is not configured")
+		}
+		caption := text
+		if links.GoogleURL != "" {
+			caption += "\nGoogle Wallet: " + links.GoogleURL
+		}
+		sendInput.ContentType = string(entity.ContentTypeDocument)
+		sendInput.Content = caption
+		sendInput.Metadata["media_url"] = links.AppleURL
+		sendInput.Metadata["filename"] = "pass.pkpass"
+		sendInput.Metadata["mime_type"] = wallet.ApplePassMIMEType
+	default:
+		return nil, errors.Validation("format must be link or file")
+	}
+
+	return s.messageService.Send(ctx, sendInput)
+}
+
+// Download builds the .pkpass file of a pass for the holder of its download link
+func (s *WalletPassService) Download(ctx context.Context, id, token string) ([]byte, *entity.WalletPass, error) {
+	pass, err := s.passRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, nil, errors.NotFound("wallet pass")
+	}
+	if !hmac.Equal([]byte(token), []byte(pass.AuthToken)) {
+		return nil, nil, errors.Unauthorized("invalid wallet pass token")
+	}
+	data, err := s.buildApplePass(ctx, pass)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, pass, nil
+}
+
+// RegisterDevice registers an Apple Wallet device for the updates of a pass. It reports
+// whether the device was not registered yet.
+func (s *WalletPassService) RegisterDevice(ctx context.Context, passTypeID, serial, token, deviceLibraryID, pushToken string) (bool, error) {
+	pass, err := s.authenticateApple(ctx, passTypeID, serial, token)
+	if err != nil {
+		return false, err
+	}
+	if deviceLibraryID == "" || pushToken == "" {
+		return false, errors.Validation("device and push token are required")
+	}
+	return s.passRepo.SaveRegistration(ctx, &entity.WalletPassRegistration{
+		PassID:          pass.ID,
+		DeviceLibraryID: deviceLibraryID,
+		PushToken:       pushToken,
+		CreatedAt:       time.Now(),
+	})
+}
+
+// UnregisterDevice stops sending the updates of a pass to an Apple Wallet device
+func (s *WalletPassService) UnregisterDevice(ctx context.Context, passTypeID, serial, token, deviceLibraryID string) error {
+	pass, err := s.authenticateApple(ctx, passTypeID, serial, token)
+	if err != nil {
+		return err
+	}
+	return s.passRepo.DeleteRegistration(ctx, pass.ID, deviceLibraryID)
+}
+
+// UpdatedSerials lists the serial numbers of the passes on a device that changed after
+// since, with the time of the latest change
+func (s *WalletPassService) UpdatedSerials(ctx context.Context, deviceLibraryID, passTypeID string, since time.Time) ([]string, time.Time, error) {
+	if s.apple == nil || passTypeID != s.apple.PassTypeID() {
+		return nil, time.Time{}, errors.NotFound("pass type")
+	}
+	passes, err := s.passRepo.FindByDevice(ctx, deviceLibraryID, since)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	serials := make([]string, 0, len(passes))
+	lastUpdated := since
+	for _, pass := range passes {
+		serials = append(serials, pass.ID)
+		if pass.UpdatedAt.After(lastUpdated) {
+			lastUpdated = pass.UpdatedAt
+		}
+	}
+	return serials, lastUpdated, nil
+}
+
+// LatestApplePass builds the current .pkpass of a pass for Apple Wallet
+func (s *WalletPassService) LatestApplePass(ctx context.Context, passTypeID, serial, token string) ([]byte, *entity.WalletPass, error) {
+	pass, err := s.authenticateApple(ctx, passTypeID, serial, token)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := s.buildApplePass(ctx, pass)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, pass, nil
+}
+
+func (s *WalletPassService) authenticateApple(ctx context.Context, passTypeID, serial, token string) (*entity.WalletPass, error) {
+	if s.apple == nil || passTypeID != s.apple.PassTypeID() {
+		return nil, errors.NotFound("pass type")
+	}
+	pass, err := s.passRepo.FindByID(ctx, serial)
+	if err != nil {
+		return nil, errors.NotFound("wallet pass")
+	}
+	if !hmac.Equal([]byte(token), []byte(pass.AuthToken)) {
+		return nil, errors.Unauthorized("invalid wallet pass token")
+	}
+	return pass, nil
+}
+
+// LinkedObjectChanged re-renders the passes issued for a linked object and pushes the
+// ones that changed to the wallets holding them
+func (s *WalletPassService) LinkedObjectChanged(ctx context.Context, object *entity.LinkedObject) {
+	passes, err := s.passRepo.FindByLinkedObject(ctx, object.ID)
+	if err != nil {
+		logger.Warn("Failed to load wallet passes of linked object",
+			zap.String("linked_object_id", object.ID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	for _, pass := range passes {
+		template, err := s.templateRepo.FindByID(ctx, pass.TemplateID)
+		if err != nil {
+			continue
+		}
+		contact, _ := s.loadContact(ctx, pass.TenantID, pass.ContactID)
+
+		before := *pass
+		renderWalletPass(template, pass, object, contact)
+		if !walletPassChanged(&before, pass) {
+			continue
+		}
+		pass.UpdatedAt = time.Now()
+		if err := s.passRepo.Update(ctx, pass); err != nil {
+			logger.Warn("Failed to update wallet pass",
+				zap.String("wallet_pass_id", pass.ID),
+				zap.Error(err),
+			)
+			continue
+		}
+		s.pushUpdate(ctx, template, pass, contact)
+	}
+}
+
+// pushUpdate tells the wallets holding a pass that it changed. Apple Wallet devices
+// download the new version themselves; Google Wallet objects are replaced.
+func (s *WalletPassService) pushUpdate(ctx context.Context, template *entity.WalletPassTemplate, pass *entity.WalletPass, contact *entity.Contact) {
+	if s.apple != nil {
+		registrations, err := s.passRepo.FindRegistrations(ctx, pass.ID)
+		if err != nil {
+			logger.Warn("Failed to load wallet pass registrations", zap.String("wallet_pass_id", pass.ID), zap.Error(err))
+		}
+		for _, registration := range registrations {
+			if err := s.apple.Push(ctx, registration.PushToken); err != nil {
+				logger.Warn("Failed to push Apple Wallet pass update",
+					zap.String("wallet_pass_id", pass.ID),
+					zap.String("device_library_id", registration.DeviceLibraryID),
+					zap.Error(err),
+				)
+			}
+		}
+	}
+	if s.google != nil {
+		if err := s.google.UpdateObject(ctx, s.walletPass(template, pass, contact)); err != nil {
+			logger.Warn("Failed to update Google Wallet pass",
+				zap.String("wallet_pass_id", pass.ID),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+func (s *WalletPassService) buildApplePass(ctx context.Context, pass *entity.WalletPass) ([]byte, error) {
+	if s.apple == nil {
+		return nil, errors.New(errors.ErrCodeBadRequest, "Apple Wallet is not configured")
+	}
+	template, err := s.templateRepo.FindByID(ctx, pass.TemplateID)
+	if err != nil {
+		return nil, err
+	}
+	contact, _ := s.loadContact(ctx, pass.TenantID, pass.ContactID)
+
+	walletPass := s.walletPass(template, pass, contact)
+	walletPass.Logo = s.fetchLogo(ctx, template.LogoURL)
+	data, err := s.apple.Build(walletPass)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to build wallet pass")
+	}
+	return data, nil
+}
+
+func (s *WalletPassService) withLinks(ctx context.Context, template *entity.WalletPassTemplate, pass *entity.WalletPass, contact *entity.Contact) *IssuedWalletPass {
+	issued := &IssuedWalletPass{WalletPass: pass}
+	if s.apple != nil {
+		issued.Links.AppleURL = fmt.Sprintf("%s/api/v1/wallet/passes/%s/apple?token=%s", s.baseURL, pass.ID, pass.AuthToken)
+	}
+	if s.google != nil {
+		link, err := s.google.SaveURL(s.walletPass(template, pass, contact))
+		if err != nil {
+			logger.Warn("Failed to create Google Wallet link", zap.String("wallet_pass_id", pass.ID), zap.Error(err))
+		} else {
+			issued.Links.GoogleURL = link
+		}
+	}
+	return issued
+}
+
+// walletPass converts a pass and its template to the form the wallet issuers build
+func (s *WalletPassService) walletPass(template *entity.WalletPassTemplate, pass *entity.WalletPass, contact *entity.Contact) *wallet.Pass {
+	fields := make([]wallet.Field, 0, len(pass.Fields))
+	for _, field := range pass.Fields {
+		fields = append(fields, wallet.Field{
+			Key:      field.Key,
+			Label:    field.Label,
+			Value:    field.Value,
+			Position: wallet.FieldPosition(field.Position),
+		})
+	}
+
+	result := &wallet.Pass{
+		SerialNumber:     pass.ID,
+		AuthToken:        pass.AuthToken,
+		WebServiceURL:    s.baseURL + "/api/v1/wallet/apple",
+		ClassID:          template.ID,
+		Kind:             wallet.PassKind(template.Kind),
+		OrganizationName: template.OrganizationName,
+		Description:      template.Description,
+		LogoText:         template.LogoText,
+		LogoURL:          template.LogoURL,
+		BackgroundColor:  template.BackgroundColor,
+		ForegroundColor:  template.ForegroundColor,
+		LabelColor:       template.LabelColor,
+		Fields:           fields,
+		Barcode: wallet.Barcode{
+			Format:  wallet.BarcodeFormat(template.BarcodeFormat),
+			Message: pass.BarcodePayload,
+			AltText: pass.BarcodePayload,
+		},
+		RelevantDate: pass.RelevantDate,
+		Voided:       pass.Voided,
+		UpdatedAt:    pass.UpdatedAt,
+	}
+	if contact != nil {
+		result.HolderName = contact.Name
+	}
+	return result
+}
+
+// fetchLogo downloads the template logo shown by Apple Wallet. Passes are built without
+// a logo when it cannot be fetched.
+func (s *WalletPassService) fetchLogo(ctx context.Context, logoURL string) []byte {
+	if !isHTTPURL(logoURL) {
+		return nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, logoURL, nil)
+	if err != nil {
+		return nil
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, walletPassLogoLimit+1))
+	if err != nil || len(data) > walletPassLogoLimit || http.DetectContentType(data) != "image/png" {
+		return nil
+	}
+	return data
+}
+
+func (s *WalletPassService) loadContact(ctx context.Context, tenantID, contactID string) (*entity.Contact, error) {
+	if contactID == "" {
+		return nil, nil
+	}
+	contact, err := s.contactRepo.FindByID(ctx, contactID)
+	if err != nil || contact.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeContactNotFound, "contact not found")
+	}
+	return contact, nil
+}
+
+// renderWalletPass renders the template fields, barcode and relevant date of a pass
+// with the variables of its linked object and contact, and voids it when the object
+// reached a void status
+func renderWalletPass(template *entity.WalletPassTemplate, pass *entity.WalletPass, object *entity.LinkedObject, contact *entity.Contact) {
+	vars := walletPassVariables(pass, object, contact)
+
+	pass.Fields = make([]entity.WalletPassField, 0, len(template.Fields))
+	for _, field := range template.Fields {
+		label, _ := RenderTemplate(field.Label, vars)
+		value, _ := RenderTemplate(field.Value, vars)
+		pass.Fields = append(pass.Fields, entity.WalletPassField{
+			Key:      field.Key,
+			Label:    label,
+			Value:    value,
+			Position: field.Position,
+		})
+	}
+
+	switch {
+	case template.BarcodeMessage != "":
+		pass.BarcodePayload, _ = RenderTemplate(template.BarcodeMessage, vars)
+	case object != nil:
+		pass.BarcodePayload = firstNonEmpty(object.Reference, object.ExternalID, object.ID)
+	default:
+		pass.BarcodePayload = firstNonEmpty(pass.ContactID, pass.ID)
+	}
+
+	if object != nil {
+		pass.Voided = template.IsVoidStatus(object.Status)
+		pass.RelevantDate = nil
+		if template.DateAttribute != "" {
+			if date, err := time.Parse(time.RFC3339, object.Attributes[template.DateAttribute]); err == nil {
+				pass.RelevantDate = &date
+			}
+		}
+	}
+}
+
+// walletPassVariables returns the variables pass templates are rendered with: the
+// linked object as object.<field> and <type>.<field>, and contact.name, contact.phone,
+// contact.email and pass.id
+func walletPassVariables(pass *entity.WalletPass, object *entity.LinkedObject, contact *entity.Contact) map[string]string {
+	vars := map[string]string{"pass.id": pass.ID}
+	if object != nil {
+		addLinkedObjectVariables(vars, object)
+		prefix := string(object.Type) + "."
+		for key, value := range vars {
+			if strings.HasPrefix(key, prefix) {
+				vars["object."+strings.TrimPrefix(key, prefix)] = value
+			}
+		}
+	}
+	if contact != nil {
+		vars["contact.name"] = contact.Name
+		vars["contact.phone"] = contact.Phone
+		vars["contact.email"] = contact.Email
+	}
+	return vars
+}
+
+func walletPassChanged(before, after *entity.WalletPass) bool {
+	if before.BarcodePayload != after.BarcodePayload || before.Voided != after.Voided {
+		return true
+	}
+	if (before.RelevantDate == nil) != (after.RelevantDate == nil) ||
+		(before.RelevantDate != nil && !before.RelevantDate.Equal(*after.RelevantDate)) {
+		return true
+	}
+	return !reflect.DeepEqual(before.Fields, after.Fields)
+}
+
+func newWalletPassToken() string {
+	b := make([]byte, walletPassTokenBytes)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,419 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/adapters/wallet"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockWalletPassTemplateRepo struct {
+	templates map[string]*entity.WalletPassTemplate
+}
+
+func (m *mockWalletPassTemplateRepo) Create(ctx context.Context, template *entity.WalletPassTemplate) error {
+	m.templates[template.ID] = template
+	return nil
+}
+
+func (m *mockWalletPassTemplateRepo) FindByID(ctx context.Context, id string) (*entity.WalletPassTemplate, error) {
+	if template, ok := m.templates[id]; ok {
+		return template, nil
+	}
+	return nil, errors.NotFound("wallet pass template")
+}
+
+func (m *mockWalletPassTemplateRepo) FindByTenant(ctx context.Context, tenantID string) ([]*entity.WalletPassTemplate, error) {
+	var result []*entity.WalletPassTemplate
+	for _, template := range m.templates {
+		if template.TenantID == tenantID {
+			result = append(result, template)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+func (m *mockWalletPassTemplateRepo) Update(ctx context.Context, template *entity.WalletPassTemplate) error {
+	m.templates[template.ID] = template
+	return nil
+}
+
+func (m *mockWalletPassTemplateRepo) Delete(ctx context.Context, id string) error {
+	delete(m.templates, id)
+	return nil
+}
+
+type mockWalletPassRepo struct {
+	passes        map[string]*entity.WalletPass
+	registrations []*entity.WalletPassRegistration
+}
+
+func (m *mockWalletPassRepo) Create(ctx context.Context, pass *entity.WalletPass) error {
+	m.passes[pass.ID] = pass
+	return nil
+}
+
+func (m *mockWalletPassRepo) FindByID(ctx context.Context, id string) (*entity.WalletPass, error) {
+	if pass, ok := m.passes[id]; ok {
+		return pass, nil
+	}
+	return nil, errors.NotFound("wallet pass")
+}
+
+func (m *mockWalletPassRepo) FindByLinkedObject(ctx context.Context, linkedObjectID string) ([]*entity.WalletPass, error) {
+	var result []*entity.WalletPass
+	for _, pass := range m.passes {
+		if pass.LinkedObjectID == linkedObjectID {
+			result = append(result, pass)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockWalletPassRepo) FindByContact(ctx context.Context, contactID string) ([]*entity.WalletPass, error) {
+	var result []*entity.WalletPass
+	for _, pass := range m.passes {
+		if pass.ContactID == contactID {
+			result = append(result, pass)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockWalletPassRepo) Update(ctx context.Context, pass *entity.WalletPass) error {
+	m.passes[pass.ID] = pass
+	return nil
+}
+
+func (m *mockWalletPassRepo) SaveRegistration(ctx context.Context, registration *entity.WalletPassRegistration) (bool, error) {
+	for _, existing := range m.registrations {
+		if existing.PassID == registration.PassID && existing.DeviceLibraryID == registration.DeviceLibraryID {
+			existing.PushToken = registration.PushToken
+			return false, nil
+		}
+	}
+	m.registrations = append(m.registrations, registration)
+	return true, nil
+}
+
+func (m *mockWalletPassRepo) DeleteRegistration(ctx context.Context, passID, deviceLibraryID string) error {
+	for i, existing := range m.registrations {
+		if existing.PassID == passID && existing.DeviceLibraryID == deviceLibraryID {
+			m.registrations = append(m.registrations[:i], m.registrations[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (m *mockWalletPassRepo) FindRegistrations(ctx context.Context, passID string) ([]*entity.WalletPassRegistration, error) {
+	var result []*entity.WalletPassRegistration
+	for _, registration := range m.registrations {
+		if registration.PassID == passID {
+			result = append(result, registration)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockWalletPassRepo) FindByDevice(ctx context.Context, deviceLibraryID string, since time.Time) ([]*entity.WalletPass, error) {
+	var result []*entity.WalletPass
+	for _, registration := range m.registrations {
+		pass := m.passes[registration.PassID]
+		if registration.DeviceLibraryID == deviceLibraryID && pass != nil && pass.UpdatedAt.After(since) {
+			result = append(result, pass)
+		}
+	}
+	return result, nil
+}
+
+type fakeApplePassIssuer struct {
+	built  []*wallet.Pass
+	pushed []string
+}
+
+func (f *fakeApplePassIssuer) PassTypeID() string { return "pass.com.example.linktor" }
+
+func (f *fakeApplePassIssuer) Build(pass *wallet.Pass) ([]byte, error) {
+	f.built = append(f.built, pass)
+	return []byte("pkpass"), nil
+}
+
+func (f *fakeApplePassIssuer) Push(ctx context.Context, pushToken string) error {
+	f.pushed = append(f.pushed, pushToken)
+	return nil
+}
+
+type fakeGooglePassIssuer struct {
+	updated []*wallet.Pass
+}
+
+func (f *fakeGooglePassIssuer) SaveURL(pass *wallet.Pass) (string, error) {
+	return "https://pay.google.com/gp/v/save/" + pass.SerialNumber, nil
+}
+
+func (f *fakeGooglePassIssuer) UpdateObject(ctx context.Context, pass *wallet.Pass) error {
+	f.updated = append(f.updated, pass)
+	return nil
+}
+
+type walletPassFixture struct {
+	service  *WalletPassService
+	passes   *mockWalletPassRepo
+	objects  *mockLinkedObjectRepo
+	messages *testutil.MockMessageRepository
+	apple    *fakeApplePassIssuer
+	google   *fakeGooglePassIssuer
+}
+
+func newWalletPassFixture() *walletPassFixture {
+	templates := &mockWalletPassTemplateRepo{templates: make(map[string]*entity.WalletPassTemplate)}
+	passes := &mockWalletPassRepo{passes: make(map[string]*entity.WalletPass)}
+	objects := &mockLinkedObjectRepo{objects: make(map[string]*entity.LinkedObject)}
+	contacts := testutil.NewMockContactRepository()
+	conversations := testutil.NewMockConversationRepository()
+	messages := testutil.NewMockMessageRepository()
+	channels := testutil.NewMockChannelRepository()
+	producer := testutil.NewMockProducer()
+
+	channels.Channels["ch-1"] = &entity.Channel{ID: "ch-1", TenantID: "tenant-1", Type: entity.ChannelTypeTelegram}
+	contacts.Contacts["alice"] = &entity.Contact{ID: "alice", TenantID: "tenant-1", Name: "Alice Smith"}
+	conversations.Conversations["conv-1"] = &entity.Conversation{ID: "conv-1", TenantID: "tenant-1", ChannelID: "ch-1", ContactID: "alice"}
+	objects.objects["booking-1"] = &entity.LinkedObject{
+		ID:         "booking-1",
+		TenantID:   "tenant-1",
+		Type:       entity.LinkedObjectTypeBooking,
+		Reference:  "BK-100",
+		Status:     "confirmed",
+		ContactID:  "alice",
+		Attributes: map[string]string{"room": "12", "check_in": "2026-11-02T14:00:00Z"},
+	}
+
+	messageService := NewMessageService(messages, conversations, channels, contacts, producer)
+	apple := &fakeApplePassIssuer{}
+	google := &fakeGooglePassIssuer{}
+	svc := NewWalletPassService(templates, passes, objects, contacts, conversations, messageService, "https://linktor.example.com/")
+	svc.SetAppleIssuer(apple)
+	svc.SetGoogleIssuer(google)
+
+	return &walletPassFixture{
+		service:  svc,
+		passes:   passes,
+		objects:  objects,
+		messages: messages,
+		apple:    apple,
+		google:   google,
+	}
+}
+
+func (f *walletPassFixture) bookingTemplate(t *testing.T) *entity.WalletPassTemplate {
+	template, err := f.service.CreateTemplate(context.Background(), "tenant-1", &WalletPassTemplateInput{
+		Name:             "Hotel booking",
+		Kind:             entity.WalletPassKindBooking,
+		ObjectType:       entity.LinkedObjectTypeBooking,
+		OrganizationName: "Hotel Example",
+		Description:      "Booking confirmation",
+		BackgroundColor:  "#1a2b3c",
+		Fields: []entity.WalletPassField{
+			{Key: "guest", Label: "Guest", Value: "{{contact.name}}", Position: "primary"},
+			{Key: "room", Label: "Room", Value: "{{object.attributes.room}}", Position: "secondary"},
+			{Key: "status", Label: "Status", Value: "{{booking.status}}", Position: "back"},
+		},
+		DateAttribute: "check_in",
+		VoidStatuses:  []string{"cancelled"},
+	})
+	require.NoError(t, err)
+	return template
+}
+
+func TestWalletPassService_CreateTemplate(t *testing.T) {
+	f := newWalletPassFixture()
+	ctx := context.Background()
+
+	template := f.bookingTemplate(t)
+	assert.Equal(t, "qr", template.BarcodeFormat)
+	assert.Equal(t, "#1A2B3C", template.BackgroundColor)
+
+	tests := []struct {
+		name  string
+		input WalletPassTemplateInput
+	}{
+		{"booking without object type", WalletPassTemplateInput{Name: "a", Kind: entity.WalletPassKindBooking, OrganizationName: "o", Description: "d"}},
+		{"loyalty with object type", WalletPassTemplateInput{Name: "a", Kind: entity.WalletPassKindLoyalty, ObjectType: entity.LinkedObjectTypeOrder, OrganizationName: "o", Description: "d", LogoURL: "https://example.com/logo.png"}},
+		{"invalid barcode", WalletPassTemplateInput{Name: "a", Kind: entity.WalletPassKindBooking, ObjectType: entity.LinkedObjectTypeOrder, OrganizationName: "o", Description: "d", BarcodeFormat: "ean13"}},
+		{"invalid color", WalletPassTemplateInput{Name: "a", Kind: entity.WalletPassKindBooking, ObjectType: entity.LinkedObjectTypeOrder, OrganizationName: "o", Description: "d", LabelColor: "red"}},
+		{"duplicate field", WalletPassTemplateInput{Name: "a", Kind: entity.WalletPassKindBooking, ObjectType: entity.LinkedObjectTypeOrder, OrganizationName: "o", Description: "d",
+			Fields: []entity.WalletPassField{{Key: "x", Value: "1"}, {Key: "x", Value: "2"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.service.CreateTemplate(ctx, "tenant-1", &tt.input)
+			assert.Error(t, err)
+		})
+	}
+
+	_, err := f.service.UpdateTemplate(ctx, "tenant-1", template.ID, &WalletPassTemplateInput{
+		Name: "Hotel booking", Kind: entity.WalletPassKindLoyalty, OrganizationName: "o", Description: "d",
+	})
+	assert.Error(t, err)
+
+	for _, logoURL := range []string{"http://169.254.169.254/latest/meta-data/", "http://127.0.0.1:8080/logo.png", "http://10.0.0.5/logo.png"} {
+		_, err = f.service.CreateTemplate(ctx, "tenant-1", &WalletPassTemplateInput{
+			Name: "Card", Kind: entity.WalletPassKindLoyalty, OrganizationName: "o", Description: "d", LogoURL: logoURL,
+		})
+		assert.True(t, errors.IsValidation(err), logoURL)
+		_, err = f.service.UpdateTemplate(ctx, "tenant-1", template.ID, &WalletPassTemplateInput{
+			Name: "Hotel booking", OrganizationName: "o", Description: "d", LogoURL: logoURL,
+		})
+		assert.True(t, errors.IsValidation(err), logoURL)
+	}
+	_, err = f.service.GetTemplate(ctx, "tenant-2", template.ID)
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestWalletPassService_IssueBooking(t *testing.T) {
+	f := newWalletPassFixture()
+	ctx := context.Background()
+	template := f.bookingTemplate(t)
+
+	issued, err := f.service.Issue(ctx, "tenant-1", &IssueWalletPassInput{TemplateID: template.ID, LinkedObjectID: "booking-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", issued.ContactID)
+	assert.Equal(t, "BK-100", issued.BarcodePayload)
+	assert.Equal(t, []entity.WalletPassField{
+		{Key: "guest", Label: "Guest", Value: "Alice Smith", Position: "primary"},
+		{Key: "room", Label: "Room", Value: "12", Position: "secondary"},
+		{Key: "status", Label: "Status", Value: "confirmed", Position: "back"},
+	}, issued.Fields)
+	require.NotNil(t, issued.RelevantDate)
+	assert.Equal(t, time.Date(2026, 11, 2, 14, 0, 0, 0, time.UTC), issued.RelevantDate.UTC())
+	assert.Equal(t, "https://linktor.example.com/api/v1/wallet/passes/"+issued.ID+"/apple?token="+issued.AuthToken, issued.Links.AppleURL)
+	assert.Equal(t, "https://pay.google.com/gp/v/save/"+issued.ID, issued.Links.GoogleURL)
+
+	again, err := f.service.Issue(ctx, "tenant-1", &IssueWalletPassInput{TemplateID: template.ID, LinkedObjectID: "booking-1"})
+	require.NoError(t, err)
+	assert.Equal(t, issued.ID, again.ID)
+	assert.Len(t, f.passes.passes, 1)
+
+	_, err = f.service.Issue(ctx, "tenant-2", &IssueWalletPassInput{TemplateID: template.ID, LinkedObjectID: "booking-1"})
+	assert.True(t, errors.IsNotFound(err))
+
+	data, pass, err := f.service.Download(ctx, issued.ID, issued.AuthToken)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("pkpass"), data)
+	assert.Equal(t, issued.ID, pass.ID)
+	require.Len(t, f.apple.built, 1)
+	assert.Equal(t, "https://linktor.example.com/api/v1/wallet/apple", f.apple.built[0].WebServiceURL)
+	assert.Equal(t, "Alice Smith", f.apple.built[0].HolderName)
+
+	_, _, err = f.service.Download(ctx, issued.ID, "wrong")
+	assert.Error(t, err)
+}
+
+func TestWalletPassService_IssueLoyalty(t *testing.T) {
+	f := newWalletPassFixture()
+	ctx := context.Background()
+
+	template, err := f.service.CreateTemplate(ctx, "tenant-1", &WalletPassTemplateInput{
+		Name:             "Club card",
+		Kind:             entity.WalletPassKindLoyalty,
+		OrganizationName: "Example Store",
+		Description:      "Loyalty card",
+		LogoURL:          "https://example.com/logo.png",
+		BarcodeMessage:   "MEMBER-{{contact.name}}",
+	})
+	require.NoError(t, err)
+
+	_, err = f.service.Issue(ctx, "tenant-1", &IssueWalletPassInput{TemplateID: template.ID})
+	assert.Error(t, err)
+
+	issued, err := f.service.Issue(ctx, "tenant-1", &IssueWalletPassInput{TemplateID: template.ID, ContactID: "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, "MEMBER-Alice Smith", issued.BarcodePayload)
+	assert.Empty(t, issued.LinkedObjectID)
+}
+
+func TestWalletPassService_LinkedObjectChanged(t *testing.T) {
+	f := newWalletPassFixture()
+	ctx := context.Background()
+	template := f.bookingTemplate(t)
+
+	issued, err := f.service.Issue(ctx, "tenant-1", &IssueWalletPassInput{TemplateID: template.ID, LinkedObjectID: "booking-1"})
+	require.NoError(t, err)
+
+	created, err := f.service.RegisterDevice(ctx, "pass.com.example.linktor", issued.ID, issued.AuthToken, "device-1", "push-1")
+	require.NoError(t, err)
+	assert.True(t, created)
+	created, err = f.service.RegisterDevice(ctx, "pass.com.example.linktor", issued.ID, issued.AuthToken, "device-1", "push-2")
+	require.NoError(t, err)
+	assert.False(t, created)
+	_, err = f.service.RegisterDevice(ctx, "pass.com.example.linktor", issued.ID, "wrong", "device-2", "push-3")
+	assert.Error(t, err)
+
+	// Unchanged objects do not push updates
+	f.service.LinkedObjectChanged(ctx, f.objects.objects["booking-1"])
+	assert.Empty(t, f.apple.pushed)
+	assert.Empty(t, f.google.updated)
+
+	before := issued.UpdatedAt
+	time.Sleep(time.Millisecond)
+	cancelled := *f.objects.objects["booking-1"]
+	cancelled.Status = "cancelled"
+	f.service.LinkedObjectChanged(ctx, &cancelled)
+
+	pass := f.passes.passes[issued.ID]
+	assert.True(t, pass.Voided)
+	assert.Equal(t, "cancelled", pass.Fields[2].Value)
+	assert.True(t, pass.UpdatedAt.After(before))
+	assert.Equal(t, []string{"push-2"}, f.apple.pushed)
+	require.Len(t, f.google.updated, 1)
+	assert.True(t, f.google.updated[0].Voided)
+
+	serials, lastUpdated, err := f.service.UpdatedSerials(ctx, "device-1", "pass.com.example.linktor", before)
+	require.NoError(t, err)
+	assert.Equal(t, []string{issued.ID}, serials)
+	assert.Equal(t, pass.UpdatedAt, lastUpdated)
+
+	serials, _, err = f.service.UpdatedSerials(ctx, "device-1", "pass.com.example.linktor", lastUpdated)
+	require.NoError(t, err)
+	assert.Empty(t, serials)
+
+	require.NoError(t, f.service.UnregisterDevice(ctx, "pass.com.example.linktor", issued.ID, issued.AuthToken, "device-1"))
+	assert.Empty(t, f.passes.registrations)
+}
+
+func TestWalletPassService_Send(t *testing.T) {
+	f := newWalletPassFixture()
+	ctx := context.Background()
+	template := f.bookingTemplate(t)
+
+	issued, err := f.service.Issue(ctx, "tenant-1", &IssueWalletPassInput{TemplateID: template.ID, LinkedObjectID: "booking-1"})
+	require.NoError(t, err)
+
+	message, err := f.service.Send(ctx, "tenant-1", issued.ID, &SendWalletPassInput{ConversationID: "conv-1", SenderID: "user-1"})
+	require.NoError(t, err)
+	assert.Equal(t, entity.ContentTypeText, message.ContentType)
+	assert.Contains(t, message.Content, "Apple Wallet: "+issued.Links.AppleURL)
+	assert.Contains(t, message.Content, "Google Wallet: "+issued.Links.GoogleURL)
+	assert.Equal(t, issued.ID, message.Metadata[MessageMetaWalletPassID])
+
+	message, err = f.service.Send(ctx, "tenant-1", issued.ID, &SendWalletPassInput{ConversationID: "conv-1", Format: WalletPassFormatFile, Message: "Sua reserva"})
+	require.NoError(t, err)
+	assert.Equal(t, entity.ContentTypeDocument, message.ContentType)
+	assert.Equal(t, issued.Links.AppleURL, message.Metadata["media_url"])
+	assert.Equal(t, wallet.ApplePassMIMEType, message.Metadata["mime_type"])
+	assert.Equal(t, entity.SenderTypeSystem, message.SenderType)
+
+	_, err = f.service.Send(ctx, "tenant-1", issued.ID, &SendWalletPassInput{ConversationID: "conv-1", Format: "sms"})
+	assert.Error(t, err)
+	_, err = f.service.Send(ctx, "tenant-2", issued.ID, &SendWalletPassInput{ConversationID: "conv-1"})
+	assert.True(t, errors.IsNotFound(err))
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// watchPreviewLength caps the message text sent to watchers
+const watchPreviewLength = 140
+
+// WatchNotifier delivers conversation activity to the users watching it
+type WatchNotifier interface {
+	NotifyWatchers(userIDs []string, activity *entity.WatchActivity)
+}
+
+// WatchInput represents a request to watch a conversation or contact
+type WatchInput struct {
+	TargetType entity.WatchTargetType `json:"target_type" binding:"required"`
+	TargetID   string                 `json:"target_id" binding:"required"`
+	UserID     string                 `json:"user_id,omitempty"` // Defaults to the requesting user
+}
+
+// WatchService lets users follow conversations and contacts they are not assigned to
+// and notifies them of the activity on those conversations
+type WatchService struct {
+	repo             repository.WatchRepository
+	conversationRepo repository.ConversationRepository
+	contactRepo      repository.ContactRepository
+	userRepo         repository.UserRepository
+	notifier         WatchNotifier
+}
+
+// NewWatchService creates a new watch service
+func NewWatchService(
+	repo repository.WatchRepository,
+	conversationRepo repository.ConversationRepository,
+	contactRepo repository.ContactRepository,
+	userRepo repository.UserRepository,
+) *WatchService {
+	return &WatchService{
+		repo:             repo,
+		conversationRepo: conversationRepo,
+		contactRepo:      contactRepo,
+		userRepo:         userRepo,
+	}
+}
+
+// SetNotifier sets the notifier used to deliver activity to watchers
+func (s *WatchService) SetNotifier(notifier WatchNotifier) {
+	s.notifier = notifier
+}
+
+// Watch subscribes a user to a conversation or contact. Watching a target twice keeps
+// the original watch.
+func (s *WatchService) Watch(ctx context.Context, tenantID, actorID string, input *WatchInput) (*entity.Watch, error) {
+	userID := input.UserID
+	if userID == "" {
+		userID = actorID
+	}
+	if err := s.checkUser(ctx, tenantID, userID); err != nil {
+		return nil, err
+	}
+	if err := s.checkTarget(ctx, tenantID, input.TargetType, input.TargetID); err != nil {
+		return nil, err
+	}
+
+	watch := &entity.Watch{
+		ID:         uuid.New().String(),
+		TenantID:   tenantID,
+		UserID:     userID,
+		TargetType: input.TargetType,
+		TargetID:   input.TargetID,
+		CreatedAt:  time.Now(),
+	}
+	if userID != actorID {
+		watch.CreatedBy = actorID
+	}
+
+	if err := s.repo.Create(ctx, watch); err != nil {
+		return nil, err
+	}
+	return watch, nil
+}
+
+// Unwatch removes the watch of a user on a conversation or contact
+func (s *WatchService) Unwatch(ctx context.Context, tenantID, userID string, targetType entity.WatchTargetType, targetID string) error {
+	if !targetType.IsValid() {
+		return errors.Validation("target_type must be conversation or contact")
+	}
+	return s.repo.Delete(ctx, tenantID, userID, targetType, targetID)
+}
+
+// ListForUser lists the conversations and contacts a user watches
+func (s *WatchService) ListForUser(ctx context.Context, tenantID, userID string) ([]*entity.Watch, error) {
+	return s.repo.ListByUser(ctx, tenantID, userID)
+}
+
+// ListWatchers lists the watches on a conversation or contact
+func (s *WatchService) ListWatchers(ctx context.Context, tenantID string, targetType entity.WatchTargetType, targetID string) ([]*entity.Watch, error) {
+	if err := s.checkTarget(ctx, tenantID, targetType, targetID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByTarget(ctx, tenantID, targetType, targetID)
+}
+
+// Notify sends an activity on a conversation to everyone watching the conversation or
+// its contact, except the user who caused it and the assigned agent, who already sees it
+func (s *WatchService) Notify(ctx context.Context, conversation *entity.Conversation, activity *entity.WatchActivity) {
+	if s.notifier == nil || conversation == nil {
+		return
+	}
+
+	watcherIDs, err := s.repo.ListWatcherIDs(ctx, conversation.TenantID, conversation.ID, conversation.ContactID)
+	if err != nil {
+		logger.Warn("Failed to list conversation watchers",
+			zap.String("conversation_id", conversation.ID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	recipients := make([]string, 0, len(watcherIDs))
+	for _, userID := range watcherIDs {
+		if userID == activity.ActorID {
+			continue
+		}
+		if activity.Type != entity.WatchActivityAssigned && conversation.AssignedUserID != nil && *conversation.AssignedUserID == userID {
+			continue
+		}
+		recipients = append(recipients, userID)
+	}
+	if len(recipients) == 0 {
+		return
+	}
+
+	activity.ConversationID = conversation.ID
+	activity.ContactID = conversation.ContactID
+	if activity.Timestamp.IsZero() {
+		activity.Timestamp = time.Now()
+	}
+	s.notifier.NotifyWatchers(recipients, activity)
+}
+
+// NotifyMessage tells watchers about a message received from or sent to the contact
+func (s *WatchService) NotifyMessage(ctx context.Context, message *entity.Message, conversation *entity.Conversation) {
+	activityType := entity.WatchActivityMessageReceived
+	if message.SenderType != entity.SenderTypeContact {
+		activityType = entity.WatchActivityAgentReplied
+	}
+
+	activity := &entity.WatchActivity{
+		Type:      activityType,
+		MessageID: message.ID,
+		Preview:   truncateText(message.Content, watchPreviewLength),
+		Timestamp: message.CreatedAt,
+	}
+	if message.SenderType == entity.SenderTypeUser {
+		activity.ActorID = message.SenderID
+	}
+	s.Notify(ctx, conversation, activity)
+}
+
+// HandleInbound notifies watchers of a message received on a watched conversation
+func (s *WatchService) HandleInbound(ctx context.Context, message *entity.Message, conversation *entity.Conversation) error {
+	s.NotifyMessage(ctx, message, conversation)
+	return nil
+}
+
+func (s *WatchService) checkUser(ctx context.Context, tenantID, userID string) error {
+	if userID == "" {
+		return errors.Validation("user_id is required")
+	}
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil || user.TenantID != tenantID {
+		return errors.New(errors.ErrCodeNotFound, "user not found")
+	}
+	return nil
+}
+
+func (s *WatchService) checkTarget(ctx context.Context, tenantID string, targetType entity.WatchTargetType, targetID string) error {
+	if targetID == "" {
+		return errors.Validation("target_id is required")
+	}
+
+	switch targetType {
+	case entity.WatchTargetConversation:
+		conversation, err := s.conversationRepo.FindByID(ctx, targetID)
+		if err != nil || conversation.TenantID != tenantID {
+			return errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+		}
+	case entity.WatchTargetContact:
+		contact, err := s.contactRepo.FindByID(ctx, targetID)
+		if err != nil || contact.TenantID != tenantID {
+			return errors.New(errors.ErrCodeContactNotFound, "contact not found")
+		}
+	default:
+		return errors.Validation("target_type must be conversation or contact")
+	}
+	return nil
+}
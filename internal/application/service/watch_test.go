@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockWatchRepo struct {
+	watches []*entity.Watch
+}
+
+func (m *mockWatchRepo) Create(ctx context.Context, watch *entity.Watch) error {
+	for _, existing := range m.watches {
+		if existing.UserID == watch.UserID && existing.TargetType == watch.TargetType && existing.TargetID == watch.TargetID {
+			*watch = *existing
+			return nil
+		}
+	}
+	m.watches = append(m.watches, watch)
+	return nil
+}
+
+func (m *mockWatchRepo) Delete(ctx context.Context, tenantID, userID string, targetType entity.WatchTargetType, targetID string) error {
+	for i, watch := range m.watches {
+		if watch.TenantID == tenantID && watch.UserID == userID && watch.TargetType == targetType && watch.TargetID == targetID {
+			m.watches = append(m.watches[:i], m.watches[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New(errors.ErrCodeNotFound, "watch not found")
+}
+
+func (m *mockWatchRepo) ListByUser(ctx context.Context, tenantID, userID string) ([]*entity.Watch, error) {
+	var result []*entity.Watch
+	for _, watch := range m.watches {
+		if watch.TenantID == tenantID && watch.UserID == userID {
+			result = append(result, watch)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockWatchRepo) ListByTarget(ctx context.Context, tenantID string, targetType entity.WatchTargetType, targetID string) ([]*entity.Watch, error) {
+	var result []*entity.Watch
+	for _, watch := range m.watches {
+		if watch.TenantID == tenantID && watch.TargetType == targetType && watch.TargetID == targetID {
+			result = append(result, watch)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockWatchRepo) ListWatcherIDs(ctx context.Context, tenantID, conversationID, contactID string) ([]string, error) {
+	seen := make(map[string]bool)
+	var result []string
+	for _, watch := range m.watches {
+		if watch.TenantID != tenantID || seen[watch.UserID] {
+			continue
+		}
+		if (watch.TargetType == entity.WatchTargetConversation && watch.TargetID == conversationID) ||
+			(watch.TargetType == entity.WatchTargetContact && watch.TargetID == contactID) {
+			seen[watch.UserID] = true
+			result = append(result, watch.UserID)
+		}
+	}
+	return result, nil
+}
+
+type recordingWatchNotifier struct {
+	userIDs    []string
+	activities []*entity.WatchActivity
+}
+
+func (n *recordingWatchNotifier) NotifyWatchers(userIDs []string, activity *entity.WatchActivity) {
+	n.userIDs = append(n.userIDs, userIDs...)
+	n.activities = append(n.activities, activity)
+}
+
+func newWatchFixture() (*WatchService, *testutil.MockConversationRepository, *recordingWatchNotifier) {
+	conversations := testutil.NewMockConversationRepository()
+	contacts := testutil.NewMockContactRepository()
+	users := testutil.NewMockUserRepository()
+
+	conversations.Conversations["conv-1"] = &entity.Conversation{ID: "conv-1", TenantID: "tenant-1", ContactID: "contact-1"}
+	contacts.Contacts["contact-1"] = &entity.Contact{ID: "contact-1", TenantID: "tenant-1"}
+	for _, id := range []string{"agent-1", "agent-2", "supervisor-1"} {
+		users.Users[id] = &entity.User{ID: id, TenantID: "tenant-1"}
+	}
+	users.Users["outsider"] = &entity.User{ID: "outsider", TenantID: "tenant-2"}
+
+	notifier := &recordingWatchNotifier{}
+	svc := NewWatchService(&mockWatchRepo{}, conversations, contacts, users)
+	svc.SetNotifier(notifier)
+	return svc, conversations, notifier
+}
+
+func TestWatchService_Watch(t *testing.T) {
+	svc, _, _ := newWatchFixture()
+	ctx := context.Background()
+
+	watch, err := svc.Watch(ctx, "tenant-1", "agent-1", &WatchInput{TargetType: entity.WatchTargetConversation, TargetID: "conv-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "agent-1", watch.UserID)
+	assert.Empty(t, watch.CreatedBy)
+
+	again, err := svc.Watch(ctx, "tenant-1", "agent-1", &WatchInput{TargetType: entity.WatchTargetConversation, TargetID: "conv-1"})
+	require.NoError(t, err)
+	assert.Equal(t, watch.ID, again.ID)
+
+	forOther, err := svc.Watch(ctx, "tenant-1", "supervisor-1", &WatchInput{TargetType: entity.WatchTargetContact, TargetID: "contact-1", UserID: "agent-2"})
+	require.NoError(t, err)
+	assert.Equal(t, "supervisor-1", forOther.CreatedBy)
+
+	_, err = svc.Watch(ctx, "tenant-1", "agent-1", &WatchInput{TargetType: "channel", TargetID: "conv-1"})
+	assert.Error(t, err)
+	_, err = svc.Watch(ctx, "tenant-2", "outsider", &WatchInput{TargetType: entity.WatchTargetConversation, TargetID: "conv-1"})
+	assert.Error(t, err)
+	_, err = svc.Watch(ctx, "tenant-1", "supervisor-1", &WatchInput{TargetType: entity.WatchTargetConversation, TargetID: "conv-1", UserID: "outsider"})
+	assert.Error(t, err)
+
+	watches, err := svc.ListForUser(ctx, "tenant-1", "agent-1")
+	require.NoError(t, err)
+	assert.Len(t, watches, 1)
+
+	require.NoError(t, svc.Unwatch(ctx, "tenant-1", "agent-1", entity.WatchTargetConversation, "conv-1"))
+	assert.Error(t, svc.Unwatch(ctx, "tenant-1", "agent-1", entity.WatchTargetConversation, "conv-1"))
+}
+
+func TestWatchService_Notify(t *testing.T) {
+	svc, conversations, notifier := newWatchFixture()
+	ctx := context.Background()
+
+	_, err := svc.Watch(ctx, "tenant-1", "agent-1", &WatchInput{TargetType: entity.WatchTargetConversation, TargetID: "conv-1"})
+	require.NoError(t, err)
+	_, err = svc.Watch(ctx, "tenant-1", "agent-2", &WatchInput{TargetType: entity.WatchTargetContact, TargetID: "contact-1"})
+	require.NoError(t, err)
+
+	conversation := conversations.Conversations["conv-1"]
+	conversation.Assign("agent-2")
+
+	// The assigned agent already sees inbound messages and is not notified twice
+	message := &entity.Message{ID: "msg-1", SenderType: entity.SenderTypeContact, Content: "Where is my order?"}
+	require.NoError(t, svc.HandleInbound(ctx, message, conversation))
+	require.Len(t, notifier.activities, 1)
+	assert.Equal(t, []string{"agent-1"}, notifier.userIDs)
+	assert.Equal(t, entity.WatchActivityMessageReceived, notifier.activities[0].Type)
+	assert.Equal(t, "conv-1", notifier.activities[0].ConversationID)
+	assert.Equal(t, "Where is my order?", notifier.activities[0].Preview)
+
+	// The replying watcher is the actor and is skipped
+	notifier.userIDs = nil
+	reply := &entity.Message{ID: "msg-2", SenderType: entity.SenderTypeUser, SenderID: "agent-1", Content: "Checking"}
+	svc.NotifyMessage(ctx, reply, conversation)
+	assert.Empty(t, notifier.userIDs)
+
+	svc.Notify(ctx, conversation, &entity.WatchActivity{Type: entity.WatchActivityAssigned, AssignedUserID: "agent-2"})
+	assert.ElementsMatch(t, []string{"agent-1", "agent-2"}, notifier.userIDs)
+}
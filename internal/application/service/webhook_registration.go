@@ -0,0 +1,376 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/msgfy/linktor/internal/adapters/facebook"
+	"github.com/msgfy/linktor/internal/adapters/instagram"
+	"github.com/msgfy/linktor/internal/adapters/meta"
+	"github.com/msgfy/linktor/internal/adapters/sms"
+	"github.com/msgfy/linktor/internal/adapters/telegram"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const webhookRegistrationTimeout = 30 * time.Second
+
+// webhookPaths are the webhook routes of the channel types whose webhooks can be
+// registered with the provider
+var webhookPaths = map[entity.ChannelType]string{
+	entity.ChannelTypeTelegram:         "telegram",
+	entity.ChannelTypeSMS:              "twilio",
+	entity.ChannelTypeFacebook:         "facebook",
+	entity.ChannelTypeInstagram:        "instagram",
+	entity.ChannelTypeWhatsAppOfficial: "whatsapp",
+}
+
+// webhookSecretCredentials are the credentials holding the secret a provider sends back
+// with each webhook; they are generated when missing
+var webhookSecretCredentials = map[entity.ChannelType]string{
+	entity.ChannelTypeTelegram:         "webhook_secret", // X-Telegram-Bot-Api-Secret-Token
+	entity.ChannelTypeWhatsAppOfficial: "verify_token",   // hub.verify_token of the verification request
+}
+
+// WebhookRegistrar registers channel webhooks with a messaging provider
+type WebhookRegistrar interface {
+	// Register points the provider's webhook for the channel at url
+	Register(ctx context.Context, channel *entity.Channel, url string) error
+	// Inspect fills in what the provider reports about the channel's webhook. When it
+	// leaves the status empty, it is derived from the provider URL.
+	Inspect(ctx context.Context, channel *entity.Channel, registration *entity.WebhookRegistration) error
+}
+
+// WebhookRegistrationService registers the webhooks of channels with their providers
+// through the providers' APIs: Telegram setWebhook with a secret token, the incoming
+// message URL of Twilio numbers and messaging services, and Meta app subscriptions.
+// This replaces configuring each webhook by hand in the provider's console.
+type WebhookRegistrationService struct {
+	channelRepo repository.ChannelRepository
+	baseURL     string
+	registrars  map[entity.ChannelType]WebhookRegistrar
+}
+
+// NewWebhookRegistrationService creates a new webhook registration service. baseURL is
+// the public URL providers reach Linktor at.
+func NewWebhookRegistrationService(channelRepo repository.ChannelRepository, baseURL string) *WebhookRegistrationService {
+	return &WebhookRegistrationService{
+		channelRepo: channelRepo,
+		baseURL:     strings.TrimSuffix(baseURL, "/"),
+		registrars: map[entity.ChannelType]WebhookRegistrar{
+			entity.ChannelTypeTelegram:         telegramWebhookRegistrar{},
+			entity.ChannelTypeSMS:              twilioWebhookRegistrar{},
+			entity.ChannelTypeFacebook:         facebookWebhookRegistrar{},
+			entity.ChannelTypeInstagram:        instagramWebhookRegistrar{},
+			entity.ChannelTypeWhatsAppOfficial: whatsAppWebhookRegistrar{},
+		},
+	}
+}
+
+// WebhookURL returns the URL the provider of a channel delivers its events to
+func (s *WebhookRegistrationService) WebhookURL(channel *entity.Channel) string {
+	return fmt.Sprintf("%s/api/v1/webhooks/%s/%s", s.baseURL, webhookPaths[channel.Type], channel.ID)
+}
+
+// Register registers the webhook of a channel with its provider and verifies it
+func (s *WebhookRegistrationService) Register(ctx context.Context, tenantID, channelID string) (*entity.WebhookRegistration, error) {
+	channel, registrar, err := s.channel(ctx, tenantID, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.register(ctx, channel, registrar); err != nil {
+		return nil, err
+	}
+	return s.inspect(ctx, channel, registrar), nil
+}
+
+// Status asks the provider of a channel where it delivers the channel's events
+func (s *WebhookRegistrationService) Status(ctx context.Context, tenantID, channelID string) (*entity.WebhookRegistration, error) {
+	channel, registrar, err := s.channel(ctx, tenantID, channelID)
+	if err != nil {
+		return nil, err
+	}
+	return s.inspect(ctx, channel, registrar), nil
+}
+
+// RegisterCreated registers the webhook of a new channel in the background. Channels
+// whose provider has no registration API are skipped, and so is every channel while
+// Linktor is not served over HTTPS, which the providers require.
+func (s *WebhookRegistrationService) RegisterCreated(ctx context.Context, channel *entity.Channel) {
+	registrar, ok := s.registrars[channel.Type]
+	if !ok || !strings.HasPrefix(s.baseURL, "https://") {
+		return
+	}
+
+	// The caller keeps using its channel while the registration updates this copy
+	created := *channel
+	created.Credentials = make(map[string]string, len(channel.Credentials))
+	for key, value := range channel.Credentials {
+		created.Credentials[key] = value
+	}
+	channel = &created
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), webhookRegistrationTimeout)
+		defer cancel()
+		if err := s.register(ctx, channel, registrar); err != nil {
+			logger.Warn("Failed to register channel webhook",
+				zap.String("channel_id", channel.ID),
+				zap.String("channel_type", string(channel.Type)),
+				zap.Error(err),
+			)
+		}
+	}()
+}
+
+func (s *WebhookRegistrationService) channel(ctx context.Context, tenantID, channelID string) (*entity.Channel, WebhookRegistrar, error) {
+	channel, err := s.channelRepo.FindByID(ctx, channelID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if channel.TenantID != tenantID {
+		return nil, nil, errors.New(errors.ErrCodeChannelNotFound, "channel not found")
+	}
+	registrar, ok := s.registrars[channel.Type]
+	if !ok {
+		return nil, nil, errors.Validation("webhooks of " + string(channel.Type) + " channels cannot be registered automatically")
+	}
+	return channel, registrar, nil
+}
+
+// register generates the channel's webhook secret when needed, registers the webhook
+// and stores its URL on the channel
+func (s *WebhookRegistrationService) register(ctx context.Context, channel *entity.Channel, registrar WebhookRegistrar) error {
+	if key, ok := webhookSecretCredentials[channel.Type]; ok && channel.Credentials[key] == "" {
+		if channel.Credentials == nil {
+			channel.Credentials = make(map[string]string)
+		}
+		channel.Credentials[key] = newConnectorSecret()
+	}
+
+	url := s.WebhookURL(channel)
+	if err := registrar.Register(ctx, channel, url); err != nil {
+		return errors.Wrap(err, errors.ErrCodeChannelError, "failed to register webhook with the provider")
+	}
+
+	channel.WebhookURL = url
+	channel.UpdatedAt = time.Now()
+	return s.channelRepo.Update(ctx, channel)
+}
+
+func (s *WebhookRegistrationService) inspect(ctx context.Context, channel *entity.Channel, registrar WebhookRegistrar) *entity.WebhookRegistration {
+	registration := &entity.WebhookRegistration{
+		ChannelID:   channel.ID,
+		ChannelType: channel.Type,
+		URL:         s.WebhookURL(channel),
+		CheckedAt:   time.Now(),
+	}
+	if err := registrar.Inspect(ctx, channel, registration); err != nil {
+		registration.Status = entity.WebhookRegistrationError
+		registration.LastError = err.Error()
+		return registration
+	}
+
+	if registration.Status == "" {
+		switch registration.ProviderURL {
+		case "":
+			registration.Status = entity.WebhookRegistrationNotRegistered
+		case registration.URL:
+			registration.Status = entity.WebhookRegistrationRegistered
+		default:
+			registration.Status = entity.WebhookRegistrationMismatch
+		}
+	}
+	return registration
+}
+
+// channelSetting returns a channel setting, which may be stored with the credentials
+// or the config
+func channelSetting(channel *entity.Channel, key string) string {
+	return firstNonEmpty(channel.Credentials[key], channel.Config[key])
+}
+
+// telegramWebhookRegistrar calls setWebhook with the channel's secret token
+type telegramWebhookRegistrar struct{}
+
+func (telegramWebhookRegistrar) client(channel *entity.Channel) (*telegram.Client, error) {
+	token := channelSetting(channel, "bot_token")
+	if token == "" {
+		return nil, fmt.Errorf("bot_token is required")
+	}
+	return telegram.NewClient(token)
+}
+
+func (r telegramWebhookRegistrar) Register(ctx context.Context, channel *entity.Channel, url string) error {
+	client, err := r.client(channel)
+	if err != nil {
+		return err
+	}
+	return client.SetWebhookWithSecret(url, channel.Credentials["webhook_secret"])
+}
+
+func (r telegramWebhookRegistrar) Inspect(ctx context.Context, channel *entity.Channel, registration *entity.WebhookRegistration) error {
+	client, err := r.client(channel)
+	if err != nil {
+		return err
+	}
+	info, err := client.GetWebhookInfo()
+	if err != nil {
+		return err
+	}
+	registration.ProviderURL = info.URL
+	registration.PendingUpdates = info.PendingUpdateCount
+	registration.LastError = info.LastErrorMessage
+	return nil
+}
+
+// twilioWebhookRegistrar sets the incoming message URL of the channel's number or
+// messaging service
+type twilioWebhookRegistrar struct{}
+
+func (twilioWebhookRegistrar) client(channel *entity.Channel) (*sms.Client, error) {
+	return sms.NewClient(&sms.TwilioConfig{
+		AccountSID:          channelSetting(channel, "account_sid"),
+		AuthToken:           channelSetting(channel, "auth_token"),
+		APIKeySID:           channelSetting(channel, "api_key_sid"),
+		APIKeySecret:        channelSetting(channel, "api_key_secret"),
+		PhoneNumber:         channelSetting(channel, "phone_number"),
+		MessagingServiceSID: channelSetting(channel, "messaging_service_sid"),
+	})
+}
+
+func (r twilioWebhookRegistrar) Register(ctx context.Context, channel *entity.Channel, url string) error {
+	client, err := r.client(channel)
+	if err != nil {
+		return err
+	}
+	// Status callbacks come to the same route, which tells them apart from messages
+	return client.SetIncomingWebhook(url, url)
+}
+
+func (r twilioWebhookRegistrar) Inspect(ctx context.Context, channel *entity.Channel, registration *entity.WebhookRegistration) error {
+	client, err := r.client(channel)
+	if err != nil {
+		return err
+	}
+	registration.ProviderURL, err = client.GetIncomingWebhook()
+	return err
+}
+
+// facebookWebhookRegistrar subscribes the channel's page to the Meta app. The callback
+// URL belongs to the app, so the registration is verified by the subscription.
+type facebookWebhookRegistrar struct{}
+
+func (facebookWebhookRegistrar) client(channel *entity.Channel) (*facebook.Client, error) {
+	return facebook.NewClient(&facebook.FacebookConfig{
+		PageID:          channelSetting(channel, "page_id"),
+		PageAccessToken: channelSetting(channel, "page_access_token"),
+		AppSecret:       channelSetting(channel, "app_secret"),
+	})
+}
+
+func (r facebookWebhookRegistrar) Register(ctx context.Context, channel *entity.Channel, url string) error {
+	client, err := r.client(channel)
+	if err != nil {
+		return err
+	}
+	return client.SubscribeToWebhooks(ctx)
+}
+
+func (r facebookWebhookRegistrar) Inspect(ctx context.Context, channel *entity.Channel, registration *entity.WebhookRegistration) error {
+	client, err := r.client(channel)
+	if err != nil {
+		return err
+	}
+	apps, err := client.GetSubscribedApps(ctx)
+	if err != nil {
+		return err
+	}
+	registration.Status = subscriptionStatus(apps)
+	return nil
+}
+
+// instagramWebhookRegistrar subscribes the channel's Instagram account to the Meta app
+type instagramWebhookRegistrar struct{}
+
+func (instagramWebhookRegistrar) client(channel *entity.Channel) (*instagram.Client, error) {
+	return instagram.NewClient(&instagram.InstagramConfig{
+		InstagramID:     channelSetting(channel, "instagram_id"),
+		AccessToken:     channelSetting(channel, "access_token"),
+		PageAccessToken: channelSetting(channel, "page_access_token"),
+		AppSecret:       channelSetting(channel, "app_secret"),
+	})
+}
+
+func (r instagramWebhookRegistrar) Register(ctx context.Context, channel *entity.Channel, url string) error {
+	client, err := r.client(channel)
+	if err != nil {
+		return err
+	}
+	return client.SubscribeToWebhooks(ctx)
+}
+
+func (r instagramWebhookRegistrar) Inspect(ctx context.Context, channel *entity.Channel, registration *entity.WebhookRegistration) error {
+	client, err := r.client(channel)
+	if err != nil {
+		return err
+	}
+	apps, err := client.GetSubscribedApps(ctx)
+	if err != nil {
+		return err
+	}
+	registration.Status = subscriptionStatus(apps)
+	return nil
+}
+
+// whatsAppWebhookRegistrar subscribes the channel's WhatsApp Business Account to the
+// Meta app, overriding the app's callback with the channel's webhook URL
+type whatsAppWebhookRegistrar struct{}
+
+func (whatsAppWebhookRegistrar) client(channel *entity.Channel) (*meta.Client, string, error) {
+	accessToken := channelSetting(channel, "access_token")
+	wabaID := firstNonEmpty(channel.WABAID, channelSetting(channel, "waba_id"), channelSetting(channel, "business_id"))
+	if accessToken == "" || wabaID == "" {
+		return nil, "", fmt.Errorf("access_token and waba_id are required")
+	}
+	return meta.NewClient(accessToken, channelSetting(channel, "app_secret")), wabaID, nil
+}
+
+func (r whatsAppWebhookRegistrar) Register(ctx context.Context, channel *entity.Channel, url string) error {
+	client, wabaID, err := r.client(channel)
+	if err != nil {
+		return err
+	}
+	return client.SubscribeWithCallback(ctx, wabaID, url, channel.Credentials["verify_token"])
+}
+
+func (r whatsAppWebhookRegistrar) Inspect(ctx context.Context, channel *entity.Channel, registration *entity.WebhookRegistration) error {
+	client, wabaID, err := r.client(channel)
+	if err != nil {
+		return err
+	}
+	apps, err := client.GetSubscribedApps(ctx, wabaID)
+	if err != nil {
+		return err
+	}
+	for _, app := range apps {
+		if app.OverrideCallbackURI != "" {
+			registration.ProviderURL = app.OverrideCallbackURI
+			break
+		}
+	}
+	return nil
+}
+
+// subscriptionStatus tells whether a page or account is subscribed to a Meta app
+func subscriptionStatus(apps []meta.SubscribedApp) entity.WebhookRegistrationStatus {
+	if len(apps) == 0 {
+		return entity.WebhookRegistrationNotRegistered
+	}
+	return entity.WebhookRegistrationRegistered
+}
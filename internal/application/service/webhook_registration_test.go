@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeWebhookRegistrar struct {
+	url         string
+	secret      string
+	registerErr error
+}
+
+func (r *fakeWebhookRegistrar) Register(ctx context.Context, channel *entity.Channel, url string) error {
+	if r.registerErr != nil {
+		return r.registerErr
+	}
+	r.url = url
+	r.secret = channel.Credentials["webhook_secret"]
+	return nil
+}
+
+func (r *fakeWebhookRegistrar) Inspect(ctx context.Context, channel *entity.Channel, registration *entity.WebhookRegistration) error {
+	registration.ProviderURL = r.url
+	return nil
+}
+
+func newWebhookRegistrationTestService() (*WebhookRegistrationService, *testutil.MockChannelRepository, *fakeWebhookRegistrar) {
+	channels := testutil.NewMockChannelRepository()
+	channels.Channels["channel-1"] = &entity.Channel{
+		ID:          "channel-1",
+		TenantID:    "tenant-1",
+		Type:        entity.ChannelTypeTelegram,
+		Credentials: map[string]string{"bot_token": "token"},
+	}
+	registrar := &fakeWebhookRegistrar{}
+	svc := NewWebhookRegistrationService(channels, "https://linktor.example.com/")
+	svc.registrars = map[entity.ChannelType]WebhookRegistrar{entity.ChannelTypeTelegram: registrar}
+	return svc, channels, registrar
+}
+
+func TestWebhookRegistrationService_Register(t *testing.T) {
+	svc, channels, registrar := newWebhookRegistrationTestService()
+	ctx := context.Background()
+
+	registration, err := svc.Register(ctx, "tenant-1", "channel-1")
+	require.NoError(t, err)
+
+	url := "https://linktor.example.com/api/v1/webhooks/telegram/channel-1"
+	assert.Equal(t, url, registrar.url)
+	assert.Len(t, registrar.secret, 64)
+	assert.Equal(t, entity.WebhookRegistrationRegistered, registration.Status)
+
+	channel := channels.Channels["channel-1"]
+	assert.Equal(t, url, channel.WebhookURL)
+	assert.Equal(t, registrar.secret, channel.Credentials["webhook_secret"])
+
+	// The secret is kept when registering again
+	_, err = svc.Register(ctx, "tenant-1", "channel-1")
+	require.NoError(t, err)
+	assert.Equal(t, channel.Credentials["webhook_secret"], registrar.secret)
+
+	_, err = svc.Register(ctx, "tenant-2", "channel-1")
+	assert.Equal(t, errors.ErrCodeChannelNotFound, errors.GetAppError(err).Code)
+
+	registrar.registerErr = fmt.Errorf("Unauthorized")
+	_, err = svc.Register(ctx, "tenant-1", "channel-1")
+	assert.Equal(t, errors.ErrCodeChannelError, errors.GetAppError(err).Code)
+}
+
+func TestWebhookRegistrationService_Status(t *testing.T) {
+	svc, channels, registrar := newWebhookRegistrationTestService()
+	ctx := context.Background()
+
+	registration, err := svc.Status(ctx, "tenant-1", "channel-1")
+	require.NoError(t, err)
+	assert.Equal(t, entity.WebhookRegistrationNotRegistered, registration.Status)
+
+	registrar.url = "https://old.example.com/telegram"
+	registration, err = svc.Status(ctx, "tenant-1", "channel-1")
+	require.NoError(t, err)
+	assert.Equal(t, entity.WebhookRegistrationMismatch, registration.Status)
+	assert.Equal(t, "https://old.example.com/telegram", registration.ProviderURL)
+
+	channels.Channels["channel-2"] = &entity.Channel{ID: "channel-2", TenantID: "tenant-1", Type: entity.ChannelTypeWebChat}
+	_, err = svc.Status(ctx, "tenant-1", "channel-2")
+	assert.True(t, errors.IsValidation(err))
+}
@@ -0,0 +1,434 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/egress"
+	"github.com/msgfy/linktor/internal/infrastructure/events"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/internal/infrastructure/webhook"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+const (
+	// WebhookMaxAttempts is how often an event is sent to an endpoint before its delivery
+	// fails. Attempts are spaced by the backoff of nats.WebhookRetryDelay.
+	WebhookMaxAttempts = 8
+
+	webhookDeliveryTimeoutSeconds = 15
+)
+
+// webhookEventTypes maps the internal events delivered to webhooks to their public type
+var webhookEventTypes = map[string]string{
+	nats.EventMessageReceived:       entity.WebhookEventMessageReceived,
+	nats.EventConversationCreated:   entity.WebhookEventConversationCreated,
+	nats.EventConversationResolved:  entity.WebhookEventConversationResolved,
+	nats.EventConversationEscalated: entity.WebhookEventEscalationCreated,
+	nats.EventContactCreated:        entity.WebhookEventContactCreated,
+	EventVRERenderCompleted:         entity.WebhookEventVRERenderCompleted,
+	EventQuotaThresholdReached:      entity.WebhookEventQuotaThreshold,
+	EventUploadCompleted:            entity.WebhookEventUploadCompleted,
+	EventUploadFailed:               entity.WebhookEventUploadFailed,
+	EventCallTranscribed:            entity.WebhookEventCallTranscribed,
+	EventConversationAutoUnassigned: entity.WebhookEventConversationAutoUnassigned,
+	EventConversationSummarized:     entity.WebhookEventConversationSummarized,
+}
+
+// WebhookSubscriptionInput represents a request to create or update a webhook subscription
+type WebhookSubscriptionInput struct {
+	Name          string   `json:"name" binding:"required"`
+	URL           string   `json:"url" binding:"required"`
+	Events        []string `json:"events" binding:"required"`
+	SchemaVersion string   `json:"schema_version,omitempty"` // v1, v2 or latest (default)
+	IsActive      *bool    `json:"is_active,omitempty"`
+}
+
+// WebhookSubscriptionSecret is a subscription together with its signing secret,
+// returned only when the subscription is created or its secret rotated
+type WebhookSubscriptionSecret struct {
+	*entity.WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+// WebhookSender delivers event envelopes to webhook endpoints
+type WebhookSender interface {
+	DeliverEnvelope(ctx context.Context, endpoint webhook.EndpointConfig, envelope *webhook.Envelope) (*webhook.DeliveryResult, error)
+}
+
+// WebhookSubscriptionService delivers tenant events to the endpoints tenants subscribed
+// to them. Events from the event stream are queued as one delivery per subscription on
+// the webhook stream, whose consumer posts them signed with the subscription secret and
+// retries failures with a backoff. Every delivery is kept in the delivery log.
+type WebhookSubscriptionService struct {
+	subscriptionRepo repository.WebhookSubscriptionRepository
+	deliveryRepo     repository.WebhookDeliveryRepository
+	producer         nats.Publisher
+	sender           WebhookSender
+	checkURL         func(ctx context.Context, endpoint string) error
+}
+
+// NewWebhookSubscriptionService creates a new webhook subscription service
+func NewWebhookSubscriptionService(
+	subscriptionRepo repository.WebhookSubscriptionRepository,
+	deliveryRepo repository.WebhookDeliveryRepository,
+	producer nats.Publisher,
+	sender WebhookSender,
+) *WebhookSubscriptionService {
+	return &WebhookSubscriptionService{
+		subscriptionRepo: subscriptionRepo,
+		deliveryRepo:     deliveryRepo,
+		producer:         producer,
+		sender:           sender,
+		checkURL:         egress.CheckURL,
+	}
+}
+
+// EventTypes lists the event types webhooks can subscribe to
+func (s *WebhookSubscriptionService) EventTypes() []string {
+	types := make([]string, len(entity.WebhookEventTypes))
+	copy(types, entity.WebhookEventTypes)
+	return types
+}
+
+// Create creates a subscription with a new signing secret
+func (s *WebhookSubscriptionService) Create(ctx context.Context, tenantID string, input *WebhookSubscriptionInput) (*WebhookSubscriptionSecret, error) {
+	now := time.Now()
+	subscription := &entity.WebhookSubscription{
+		ID:        uuid.New().String(),
+		TenantID:  tenantID,
+		Secret:    newConnectorSecret(),
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.applyInput(ctx, subscription, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.subscriptionRepo.Create(ctx, subscription); err != nil {
+		return nil, err
+	}
+	return &WebhookSubscriptionSecret{WebhookSubscription: subscription, Secret: subscription.Secret}, nil
+}
+
+// Get returns a subscription of the tenant
+func (s *WebhookSubscriptionService) Get(ctx context.Context, tenantID, id string) (*entity.WebhookSubscription, error) {
+	subscription, err := s.subscriptionRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if subscription.TenantID != tenantID {
+		return nil, errors.NotFound("webhook subscription")
+	}
+	return subscription, nil
+}
+
+// List lists the subscriptions of a tenant
+func (s *WebhookSubscriptionService) List(ctx context.Context, tenantID string) ([]*entity.WebhookSubscription, error) {
+	subscriptions, err := s.subscriptionRepo.FindByTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if subscriptions == nil {
+		subscriptions = []*entity.WebhookSubscription{}
+	}
+	return subscriptions, nil
+}
+
+// Update updates a subscription. Queued deliveries go to the new URL.
+func (s *WebhookSubscriptionService) Update(ctx context.Context, tenantID, id string, input *WebhookSubscriptionInput) (*entity.WebhookSubscription, error) {
+	subscription, err := s.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyInput(ctx, subscription, input); err != nil {
+		return nil, err
+	}
+	subscription.UpdatedAt = time.Now()
+
+	if err := s.subscriptionRepo.Update(ctx, subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// RotateSecret replaces the signing secret of a subscription
+func (s *WebhookSubscriptionService) RotateSecret(ctx context.Context, tenantID, id string) (*WebhookSubscriptionSecret, error) {
+	subscription, err := s.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	subscription.Secret = newConnectorSecret()
+	subscription.UpdatedAt = time.Now()
+
+	if err := s.subscriptionRepo.Update(ctx, subscription); err != nil {
+		return nil, err
+	}
+	return &WebhookSubscriptionSecret{WebhookSubscription: subscription, Secret: subscription.Secret}, nil
+}
+
+// Delete deletes a subscription and its delivery log
+func (s *WebhookSubscriptionService) Delete(ctx context.Context, tenantID, id string) error {
+	if _, err := s.Get(ctx, tenantID, id); err != nil {
+		return err
+	}
+	return s.subscriptionRepo.Delete(ctx, id)
+}
+
+// ListDeliveries lists the delivery log of a subscription, newest first
+func (s *WebhookSubscriptionService) ListDeliveries(ctx context.Context, tenantID, id string, status entity.WebhookDeliveryStatus, params *repository.ListParams) ([]*entity.WebhookDelivery, int64, error) {
+	if _, err := s.Get(ctx, tenantID, id); err != nil {
+		return nil, 0, err
+	}
+	deliveries, total, err := s.deliveryRepo.ListBySubscription(ctx, id, status, params)
+	if err != nil {
+		return nil, 0, err
+	}
+	if deliveries == nil {
+		deliveries = []*entity.WebhookDelivery{}
+	}
+	return deliveries, total, nil
+}
+
+// GetDelivery returns a delivery of a subscription
+func (s *WebhookSubscriptionService) GetDelivery(ctx context.Context, tenantID, id, deliveryID string) (*entity.WebhookDelivery, error) {
+	delivery, err := s.deliveryRepo.FindByID(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.TenantID != tenantID || delivery.SubscriptionID != id {
+		return nil, errors.NotFound("webhook delivery")
+	}
+	return delivery, nil
+}
+
+// Redeliver queues an event of the delivery log again, as a new delivery with the same
+// event ID so endpoints can deduplicate it
+func (s *WebhookSubscriptionService) Redeliver(ctx context.Context, tenantID, id, deliveryID string) (*entity.WebhookDelivery, error) {
+	previous, err := s.GetDelivery(ctx, tenantID, id, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	subscription, err := s.Get(ctx, tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := &entity.WebhookDelivery{
+		ID:             uuid.New().String(),
+		TenantID:       tenantID,
+		SubscriptionID: id,
+		EventID:        previous.EventID,
+		EventType:      previous.EventType,
+		Data:           previous.Data,
+		OccurredAt:     previous.OccurredAt,
+		Status:         entity.WebhookDeliveryPending,
+		CreatedAt:      time.Now(),
+	}
+	if err := s.queue(ctx, subscription, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// HandleEvent queues an event from the event stream for the subscriptions of its tenant
+func (s *WebhookSubscriptionService) HandleEvent(ctx context.Context, event *nats.Event) error {
+	eventType, ok := webhookEventTypes[event.Type]
+	if !ok || event.TenantID == "" {
+		return nil
+	}
+
+	subscriptions, err := s.subscriptionRepo.FindActiveByTenant(ctx, event.TenantID)
+	if err != nil {
+		return err
+	}
+
+	envelope := webhook.NewEnvelope(&events.EventPayload{
+		TenantID:  event.TenantID,
+		EventType: eventType,
+		Payload:   event.Payload,
+		Timestamp: event.Timestamp,
+	})
+	for _, subscription := range subscriptions {
+		if !subscription.Subscribes(eventType) {
+			continue
+		}
+		delivery := &entity.WebhookDelivery{
+			ID:             uuid.New().String(),
+			TenantID:       event.TenantID,
+			SubscriptionID: subscription.ID,
+			EventID:        envelope.ID,
+			EventType:      eventType,
+			Data:           envelope.Data,
+			OccurredAt:     envelope.OccurredAt,
+			Status:         entity.WebhookDeliveryPending,
+			CreatedAt:      time.Now(),
+		}
+		if err := s.queue(ctx, subscription, delivery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HandleDelivery makes one attempt of a queued delivery and records it in the delivery
+// log. It returns an error while attempts are left, so the request is redelivered.
+func (s *WebhookSubscriptionService) HandleDelivery(ctx context.Context, request *nats.WebhookDelivery) error {
+	delivery, err := s.deliveryRepo.FindByID(ctx, request.ID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if delivery.Status != entity.WebhookDeliveryPending {
+		return nil
+	}
+
+	subscription, err := s.subscriptionRepo.FindByID(ctx, delivery.SubscriptionID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !subscription.IsActive {
+		delivery.Status = entity.WebhookDeliveryFailed
+		delivery.Error = "subscription is inactive"
+		return s.deliveryRepo.Update(ctx, delivery)
+	}
+
+	envelope := &webhook.Envelope{
+		ID:         delivery.EventID,
+		Type:       delivery.EventType,
+		TenantID:   delivery.TenantID,
+		OccurredAt: delivery.OccurredAt.UTC(),
+		Data:       delivery.Data,
+	}
+	result, sendErr := s.sender.DeliverEnvelope(ctx, webhook.EndpointConfig{
+		URL:            subscription.URL,
+		Headers:        map[string]string{webhook.HeaderDeliveryID: delivery.ID},
+		MaxRetries:     1,
+		TimeoutSeconds: webhookDeliveryTimeoutSeconds,
+		SchemaVersion:  subscription.SchemaVersion,
+		Secret:         subscription.Secret,
+	}, envelope)
+
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.StatusCode = 0
+	delivery.ResponseBody = ""
+	delivery.Error = ""
+	if result != nil {
+		delivery.StatusCode = result.StatusCode
+		delivery.ResponseBody = result.ResponseBody
+		delivery.Error = result.Error
+	}
+	switch {
+	case sendErr == nil:
+		delivery.Status = entity.WebhookDeliverySucceeded
+		delivery.DeliveredAt = &now
+	case delivery.Attempts >= WebhookMaxAttempts:
+		delivery.Status = entity.WebhookDeliveryFailed
+	}
+	if sendErr != nil && delivery.Error == "" {
+		delivery.Error = sendErr.Error()
+	}
+
+	if err := s.deliveryRepo.Update(ctx, delivery); err != nil {
+		return err
+	}
+	if delivery.Status == entity.WebhookDeliveryPending {
+		return sendErr
+	}
+	return nil
+}
+
+// queue records a delivery in the log and requests it on the webhook stream
+func (s *WebhookSubscriptionService) queue(ctx context.Context, subscription *entity.WebhookSubscription, delivery *entity.WebhookDelivery) error {
+	if s.producer == nil {
+		return errors.New(errors.ErrCodeBadRequest, "webhook delivery requires NATS")
+	}
+	if err := s.deliveryRepo.Create(ctx, delivery); err != nil {
+		return err
+	}
+	return s.producer.PublishWebhookDelivery(ctx, &nats.WebhookDelivery{
+		ID:         delivery.ID,
+		TenantID:   delivery.TenantID,
+		URL:        subscription.URL,
+		EventType:  delivery.EventType,
+		MaxRetries: WebhookMaxAttempts,
+		Timestamp:  delivery.CreatedAt,
+	})
+}
+
+// applyInput validates input and copies it to subscription
+func (s *WebhookSubscriptionService) applyInput(ctx context.Context, subscription *entity.WebhookSubscription, input *WebhookSubscriptionInput) error {
+	details := map[string]string{}
+
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		details["name"] = "name is required"
+	}
+
+	endpoint := strings.TrimSpace(input.URL)
+	if !isWebhookURL(endpoint) {
+		details["url"] = "url must be an http or https URL"
+	} else if err := s.checkURL(ctx, endpoint); err != nil {
+		details["url"] = "url must not point to a private, loopback or link-local address"
+	}
+
+	var eventTypes []string
+	seen := map[string]bool{}
+	for _, eventType := range input.Events {
+		eventType = strings.TrimSpace(eventType)
+		if !entity.IsValidWebhookEventType(eventType) {
+			details["events"] = "unknown event type " + eventType
+			continue
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+	if len(input.Events) == 0 {
+		details["events"] = "events must list at least one event type"
+	}
+
+	schemaVersion := strings.ToLower(strings.TrimSpace(input.SchemaVersion))
+	if schemaVersion == "" {
+		schemaVersion = webhook.SchemaLatest
+	} else if schemaVersion != webhook.SchemaLatest {
+		version, err := webhook.NegotiateSchemaVersion(schemaVersion)
+		if err != nil {
+			details["schema_version"] = "schema_version must be v1, v2 or latest"
+		}
+		schemaVersion = version
+	}
+
+	if len(details) > 0 {
+		return errors.New(errors.ErrCodeValidation, "invalid webhook subscription").WithDetails(details)
+	}
+
+	subscription.Name = name
+	subscription.URL = endpoint
+	subscription.Events = eventTypes
+	subscription.SchemaVersion = schemaVersion
+	if input.IsActive != nil {
+		subscription.IsActive = *input.IsActive
+	}
+	return nil
+}
+
+// isWebhookURL checks if an endpoint URL can receive webhooks
+func isWebhookURL(endpoint string) bool {
+	u, err := url.Parse(endpoint)
+	return err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != ""
+}
@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/internal/infrastructure/webhook"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockWebhookSubscriptionRepo struct {
+	subscriptions map[string]*entity.WebhookSubscription
+}
+
+func (m *mockWebhookSubscriptionRepo) Create(ctx context.Context, subscription *entity.WebhookSubscription) error {
+	m.subscriptions[subscription.ID] = subscription
+	return nil
+}
+
+func (m *mockWebhookSubscriptionRepo) FindByID(ctx context.Context, id string) (*entity.WebhookSubscription, error) {
+	if subscription, ok := m.subscriptions[id]; ok {
+		return subscription, nil
+	}
+	return nil, errors.NotFound("webhook subscription")
+}
+
+func (m *mockWebhookSubscriptionRepo) FindByTenant(ctx context.Context, tenantID string) ([]*entity.WebhookSubscription, error) {
+	var result []*entity.WebhookSubscription
+	for _, subscription := range m.subscriptions {
+		if subscription.TenantID == tenantID {
+			result = append(result, subscription)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+func (m *mockWebhookSubscriptionRepo) FindActiveByTenant(ctx context.Context, tenantID string) ([]*entity.WebhookSubscription, error) {
+	var result []*entity.WebhookSubscription
+	for _, subscription := range m.subscriptions {
+		if subscription.TenantID == tenantID && subscription.IsActive {
+			result = append(result, subscription)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockWebhookSubscriptionRepo) Update(ctx context.Context, subscription *entity.WebhookSubscription) error {
+	m.subscriptions[subscription.ID] = subscription
+	return nil
+}
+
+func (m *mockWebhookSubscriptionRepo) Delete(ctx context.Context, id string) error {
+	delete(m.subscriptions, id)
+	return nil
+}
+
+type mockWebhookDeliveryRepo struct {
+	deliveries map[string]*entity.WebhookDelivery
+}
+
+func (m *mockWebhookDeliveryRepo) Create(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	m.deliveries[delivery.ID] = delivery
+	return nil
+}
+
+func (m *mockWebhookDeliveryRepo) FindByID(ctx context.Context, id string) (*entity.WebhookDelivery, error) {
+	if delivery, ok := m.deliveries[id]; ok {
+		return delivery, nil
+	}
+	return nil, errors.NotFound("webhook delivery")
+}
+
+func (m *mockWebhookDeliveryRepo) ListBySubscription(ctx context.Context, subscriptionID string, status entity.WebhookDeliveryStatus, params *repository.ListParams) ([]*entity.WebhookDelivery, int64, error) {
+	var result []*entity.WebhookDelivery
+	for _, delivery := range m.deliveries {
+		if delivery.SubscriptionID == subscriptionID && (status == "" || delivery.Status == status) {
+			result = append(result, delivery)
+		}
+	}
+	return result, int64(len(result)), nil
+}
+
+func (m *mockWebhookDeliveryRepo) Update(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	m.deliveries[delivery.ID] = delivery
+	return nil
+}
+
+type webhookRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// webhookEndpoint records the requests it receives and answers with status
+type webhookEndpoint struct {
+	mu       sync.Mutex
+	status   int
+	requests []webhookRequest
+}
+
+func (e *webhookEndpoint) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	e.mu.Lock()
+	e.requests = append(e.requests, webhookRequest{header: r.Header.Clone(), body: body})
+	status := e.status
+	e.mu.Unlock()
+	w.WriteHeader(status)
+}
+
+// allowAnyURL lets services accept the URLs of test servers, which listen on loopback
+func allowAnyURL(ctx context.Context, endpoint string) error { return nil }
+
+func newTestWebhookSubscriptionService(t *testing.T, status int) (*WebhookSubscriptionService, *mockWebhookDeliveryRepo, *testutil.MockProducer, *webhookEndpoint, string) {
+	t.Helper()
+	endpoint := &webhookEndpoint{status: status}
+	server := httptest.NewServer(endpoint)
+	t.Cleanup(server.Close)
+
+	deliveryRepo := &mockWebhookDeliveryRepo{deliveries: map[string]*entity.WebhookDelivery{}}
+	producer := testutil.NewMockProducer()
+	svc := NewWebhookSubscriptionService(
+		&mockWebhookSubscriptionRepo{subscriptions: map[string]*entity.WebhookSubscription{}},
+		deliveryRepo,
+		producer,
+		webhook.NewWebhookProducer(webhook.WithBackoffDelays([]time.Duration{0})),
+	)
+	svc.checkURL = allowAnyURL
+	return svc, deliveryRepo, producer, endpoint, server.URL
+}
+
+func TestWebhookSubscriptionService_CreateValidates(t *testing.T) {
+	svc, _, _, _, url := newTestWebhookSubscriptionService(t, http.StatusOK)
+	ctx := context.Background()
+
+	_, err := svc.Create(ctx, "tenant-1", &WebhookSubscriptionInput{Name: "CRM", URL: "ftp://crm.example.com", Events: []string{"message.sent"}})
+	require.Error(t, err)
+	appErr := errors.GetAppError(err)
+	require.NotNil(t, appErr)
+	assert.Equal(t, errors.ErrCodeValidation, appErr.Code)
+	assert.Contains(t, appErr.Details, "url")
+	assert.Contains(t, appErr.Details, "events")
+
+	created, err := svc.Create(ctx, "tenant-1", &WebhookSubscriptionInput{
+		Name:   "CRM",
+		URL:    url,
+		Events: []string{entity.WebhookEventMessageReceived, entity.WebhookEventMessageReceived},
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.Secret)
+	assert.Equal(t, []string{entity.WebhookEventMessageReceived}, created.Events)
+	assert.Equal(t, webhook.SchemaLatest, created.SchemaVersion)
+	assert.True(t, created.IsActive)
+
+	_, err = svc.Get(ctx, "tenant-2", created.ID)
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestWebhookSubscriptionService_RejectsInternalURLs(t *testing.T) {
+	svc := NewWebhookSubscriptionService(
+		&mockWebhookSubscriptionRepo{subscriptions: map[string]*entity.WebhookSubscription{}},
+		&mockWebhookDeliveryRepo{deliveries: map[string]*entity.WebhookDelivery{}},
+		testutil.NewMockProducer(),
+		webhook.NewWebhookProducer(),
+	)
+	ctx := context.Background()
+
+	for _, url := range []string{"http://169.254.169.254/latest/meta-data/", "http://127.0.0.1:8080/hook", "https://10.0.0.7/hook", "http://localhost/hook"} {
+		_, err := svc.Create(ctx, "tenant-1", &WebhookSubscriptionInput{Name: "CRM", URL: url, Events: []string{entity.WebhookEventAll}})
+		appErr := errors.GetAppError(err)
+		require.NotNil(t, appErr, url)
+		assert.Contains(t, appErr.Details, "url", url)
+	}
+}
+
+func TestWebhookSubscriptionService_HandleEventQueuesSubscribedEvents(t *testing.T) {
+	svc, deliveryRepo, producer, _, url := newTestWebhookSubscriptionService(t, http.StatusOK)
+	ctx := context.Background()
+
+	escalations, err := svc.Create(ctx, "tenant-1", &WebhookSubscriptionInput{Name: "Escalations", URL: url, Events: []string{entity.WebhookEventEscalationCreated}})
+	require.NoError(t, err)
+	_, err = svc.Create(ctx, "tenant-1", &WebhookSubscriptionInput{Name: "Messages", URL: url, Events: []string{entity.WebhookEventMessageReceived}})
+	require.NoError(t, err)
+	_, err = svc.Create(ctx, "tenant-2", &WebhookSubscriptionInput{Name: "Other tenant", URL: url, Events: []string{entity.WebhookEventAll}})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.HandleEvent(ctx, &nats.Event{
+		Type:      nats.EventConversationEscalated,
+		TenantID:  "tenant-1",
+		Payload:   map[string]interface{}{"conversation_id": "conv-1"},
+		Timestamp: time.Now(),
+	}))
+	require.NoError(t, svc.HandleEvent(ctx, &nats.Event{Type: nats.EventMessageSent, TenantID: "tenant-1"}))
+
+	require.Len(t, producer.Webhooks, 1)
+	delivery, err := deliveryRepo.FindByID(ctx, producer.Webhooks[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, escalations.ID, delivery.SubscriptionID)
+	assert.Equal(t, entity.WebhookEventEscalationCreated, delivery.EventType)
+	assert.Equal(t, "conv-1", delivery.Data["conversation_id"])
+	assert.Equal(t, entity.WebhookDeliveryPending, delivery.Status)
+}
+
+func TestWebhookSubscriptionService_HandleDeliverySignsRequest(t *testing.T) {
+	svc, deliveryRepo, producer, endpoint, url := newTestWebhookSubscriptionService(t, http.StatusOK)
+	ctx := context.Background()
+
+	subscription, err := svc.Create(ctx, "tenant-1", &WebhookSubscriptionInput{Name: "CRM", URL: url, Events: []string{entity.WebhookEventAll}})
+	require.NoError(t, err)
+	require.NoError(t, svc.HandleEvent(ctx, &nats.Event{
+		Type:     nats.EventConversationResolved,
+		TenantID: "tenant-1",
+		Payload:  map[string]interface{}{"conversation_id": "conv-1"},
+	}))
+	require.Len(t, producer.Webhooks, 1)
+
+	require.NoError(t, svc.HandleDelivery(ctx, producer.Webhooks[0]))
+
+	require.Len(t, endpoint.requests, 1)
+	request := endpoint.requests[0]
+	assert.Equal(t, webhook.Sign(subscription.Secret, request.body), request.header.Get(webhook.HeaderSignature))
+	assert.Equal(t, producer.Webhooks[0].ID, request.header.Get(webhook.HeaderDeliveryID))
+	assert.Equal(t, entity.WebhookEventConversationResolved, request.header.Get(webhook.HeaderEventType))
+
+	delivery, err := deliveryRepo.FindByID(ctx, producer.Webhooks[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.WebhookDeliverySucceeded, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusOK, delivery.StatusCode)
+	assert.NotNil(t, delivery.DeliveredAt)
+
+	// A redelivered request of a finished delivery is not sent again
+	require.NoError(t, svc.HandleDelivery(ctx, producer.Webhooks[0]))
+	assert.Len(t, endpoint.requests, 1)
+}
+
+func TestWebhookSubscriptionService_HandleDeliveryRetriesUntilFailed(t *testing.T) {
+	svc, deliveryRepo, producer, endpoint, url := newTestWebhookSubscriptionService(t, http.StatusInternalServerError)
+	ctx := context.Background()
+
+	_, err := svc.Create(ctx, "tenant-1", &WebhookSubscriptionInput{Name: "CRM", URL: url, Events: []string{entity.WebhookEventMessageReceived}})
+	require.NoError(t, err)
+	require.NoError(t, svc.HandleEvent(ctx, &nats.Event{Type: nats.EventMessageReceived, TenantID: "tenant-1"}))
+	require.Len(t, producer.Webhooks, 1)
+	request := producer.Webhooks[0]
+
+	for attempt := 1; attempt < WebhookMaxAttempts; attempt++ {
+		require.Error(t, svc.HandleDelivery(ctx, request))
+		delivery, _ := deliveryRepo.FindByID(ctx, request.ID)
+		assert.Equal(t, entity.WebhookDeliveryPending, delivery.Status)
+		assert.Equal(t, attempt, delivery.Attempts)
+	}
+
+	require.NoError(t, svc.HandleDelivery(ctx, request))
+	delivery, _ := deliveryRepo.FindByID(ctx, request.ID)
+	assert.Equal(t, entity.WebhookDeliveryFailed, delivery.Status)
+	assert.Equal(t, WebhookMaxAttempts, delivery.Attempts)
+	assert.Equal(t, http.StatusInternalServerError, delivery.StatusCode)
+	assert.Len(t, endpoint.requests, WebhookMaxAttempts)
+}
+
+func TestWebhookSubscriptionService_RedeliverKeepsEventID(t *testing.T) {
+	svc, _, producer, endpoint, url := newTestWebhookSubscriptionService(t, http.StatusOK)
+	ctx := context.Background()
+
+	subscription, err := svc.Create(ctx, "tenant-1", &WebhookSubscriptionInput{Name: "CRM", URL: url, Events: []string{entity.WebhookEventContactCreated}})
+	require.NoError(t, err)
+	require.NoError(t, svc.HandleEvent(ctx, &nats.Event{Type: nats.EventContactCreated, TenantID: "tenant-1"}))
+	require.Len(t, producer.Webhooks, 1)
+	require.NoError(t, svc.HandleDelivery(ctx, producer.Webhooks[0]))
+	original := producer.Webhooks[0].ID
+
+	_, err = svc.Redeliver(ctx, "tenant-2", subscription.ID, original)
+	assert.True(t, errors.IsNotFound(err))
+
+	redelivery, err := svc.Redeliver(ctx, "tenant-1", subscription.ID, original)
+	require.NoError(t, err)
+	assert.NotEqual(t, original, redelivery.ID)
+	require.Len(t, producer.Webhooks, 2)
+	require.NoError(t, svc.HandleDelivery(ctx, producer.Webhooks[1]))
+
+	previous, err := svc.GetDelivery(ctx, "tenant-1", subscription.ID, original)
+	require.NoError(t, err)
+	assert.Equal(t, previous.EventID, redelivery.EventID)
+	require.Len(t, endpoint.requests, 2)
+	assert.Equal(t, endpoint.requests[0].body, endpoint.requests[1].body)
+}
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// WhatsAppCostService attributes WhatsApp pricing to conversation windows, channels and
+// campaigns from the pricing info Meta sends with status webhooks.
+type WhatsAppCostService struct {
+	costRepo    repository.WhatsAppConversationCostRepository
+	messageRepo repository.MessageRepository
+}
+
+// NewWhatsAppCostService creates a new WhatsApp cost service
+func NewWhatsAppCostService(
+	costRepo repository.WhatsAppConversationCostRepository,
+	messageRepo repository.MessageRepository,
+) *WhatsAppCostService {
+	return &WhatsAppCostService{
+		costRepo:    costRepo,
+		messageRepo: messageRepo,
+	}
+}
+
+// RecordPricingInput represents the pricing info of one status webhook
+type RecordPricingInput struct {
+	Channel             *entity.Channel
+	ExternalMessageID   string
+	WAConversationID    string
+	Origin              string
+	ExpirationTimestamp string
+	Billable            bool
+	PricingModel        string
+	Category            string
+}
+
+// WhatsAppCostTotals summarizes the WhatsApp spend of a tenant in a period
+type WhatsAppCostTotals struct {
+	Conversations int64            `json:"conversations"`
+	Billable      int64            `json:"billable"`
+	Cost          float64          `json:"cost"`
+	Currency      string           `json:"currency"`
+	ByCategory    map[string]int64 `json:"by_category"`
+}
+
+// RecordPricing persists the category and billability of the conversation window a status
+// belongs to. Only the first status of a window is recorded; Meta repeats the pricing info
+// on every status of the same conversation. Under per-message pricing there is no
+// conversation, so each message is its own window.
+func (s *WhatsAppCostService) RecordPricing(ctx context.Context, input *RecordPricingInput) error {
+	if input == nil || input.Channel == nil || input.Category == "" {
+		return nil
+	}
+
+	windowID := input.WAConversationID
+	if windowID == "" {
+		windowID = input.ExternalMessageID
+	}
+	if windowID == "" {
+		return nil
+	}
+
+	channel := input.Channel
+	if existing, err := s.costRepo.FindByWAConversationID(ctx, channel.ID, windowID); err == nil && existing != nil {
+		return nil
+	}
+
+	category := entity.WhatsAppPricingCategory(strings.ToLower(input.Category))
+	now := time.Now()
+	cost := &entity.WhatsAppConversationCost{
+		ID:               uuid.New().String(),
+		TenantID:         channel.TenantID,
+		ChannelID:        channel.ID,
+		WAConversationID: windowID,
+		Category:         category,
+		PricingModel:     input.PricingModel,
+		Origin:           input.Origin,
+		Billable:         input.Billable,
+		Currency:         channelCostCurrency(channel),
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if input.Billable {
+		cost.Cost = channelRate(channel, category)
+	}
+	if expiresAt := parseUnixTimestamp(input.ExpirationTimestamp); expiresAt != nil {
+		cost.ExpiresAt = expiresAt
+	}
+
+	if input.ExternalMessageID != "" && s.messageRepo != nil {
+		if message, err := s.messageRepo.FindByExternalID(ctx, input.ExternalMessageID); err == nil && message != nil {
+			cost.MessageID = message.ID
+			cost.CampaignID = message.Metadata["campaign_id"]
+		}
+	}
+
+	if err := s.costRepo.Create(ctx, cost); err != nil {
+		logger.Error("Failed to record WhatsApp conversation cost",
+			zap.String("channel_id", channel.ID),
+			zap.String("wa_conversation_id", windowID),
+			zap.Error(err),
+		)
+		return err
+	}
+	return nil
+}
+
+// GetCostsByChannel aggregates WhatsApp costs per channel
+func (s *WhatsAppCostService) GetCostsByChannel(ctx context.Context, tenantID string, startDate, endDate time.Time) ([]entity.WhatsAppCostSummary, error) {
+	return s.costRepo.SummarizeByChannel(ctx, costFilter(tenantID, startDate, endDate))
+}
+
+// GetCostsByCampaign aggregates WhatsApp costs per campaign
+func (s *WhatsAppCostService) GetCostsByCampaign(ctx context.Context, tenantID string, startDate, endDate time.Time) ([]entity.WhatsAppCostSummary, error) {
+	return s.costRepo.SummarizeByCampaign(ctx, costFilter(tenantID, startDate, endDate))
+}
+
+// GetTotals summarizes the WhatsApp spend of a tenant in a period
+func (s *WhatsAppCostService) GetTotals(ctx context.Context, tenantID string, startDate, endDate time.Time) (*WhatsAppCostTotals, error) {
+	summaries, err := s.GetCostsByChannel(ctx, tenantID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := &WhatsAppCostTotals{Currency: entity.DefaultCostCurrency, ByCategory: make(map[string]int64)}
+	for _, summary := range summaries {
+		totals.Conversations += summary.Conversations
+		totals.Billable += summary.Billable
+		totals.Cost += summary.Cost
+		if summary.Currency != "" {
+			totals.Currency = summary.Currency
+		}
+		for category, count := range summary.ByCategory {
+			totals.ByCategory[category] += count
+		}
+	}
+	return totals, nil
+}
+
+// ExportCSV exports the conversation costs of a tenant for usage metering
+func (s *WhatsAppCostService) ExportCSV(ctx context.Context, tenantID string, startDate, endDate time.Time) ([]byte, error) {
+	costs, err := s.costRepo.List(ctx, costFilter(tenantID, startDate, endDate))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	header := []string{"Date", "Channel ID", "Conversation ID", "Campaign ID", "Category", "Pricing Model", "Origin", "Billable", "Cost", "Currency"}
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+
+	for _, cost := range costs {
+		row := []string{
+			cost.CreatedAt.UTC().Format(time.RFC3339),
+			cost.ChannelID,
+			cost.WAConversationID,
+			cost.CampaignID,
+			string(cost.Category),
+			cost.PricingModel,
+			cost.Origin,
+			strconv.FormatBool(cost.Billable),
+			fmt.Sprintf("%.4f", cost.Cost),
+			cost.Currency,
+		}
+		if err := writer.Write(row); err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to export WhatsApp costs")
+	}
+
+	return buf.Bytes(), nil
+}
+
+func costFilter(tenantID string, startDate, endDate time.Time) entity.AnalyticsFilter {
+	return entity.AnalyticsFilter{
+		TenantID:  tenantID,
+		StartDate: startDate,
+		EndDate:   endDate,
+	}
+}
+
+// channelRate returns the rate of a category, preferring the rate card configured on the
+// channel (config key pricing_rate_<category>) over the reference rates.
+func channelRate(channel *entity.Channel, category entity.WhatsAppPricingCategory) float64 {
+	if channel.Config != nil {
+		if raw := channel.Config["pricing_rate_"+string(category)]; raw != "" {
+			if rate, err := strconv.ParseFloat(raw, 64); err == nil && rate >= 0 {
+				return rate
+			}
+		}
+	}
+	return category.DefaultRate()
+}
+
+func channelCostCurrency(channel *entity.Channel) string {
+	if channel.Config != nil && channel.Config["pricing_currency"] != "" {
+		return strings.ToUpper(channel.Config["pricing_currency"])
+	}
+	return entity.DefaultCostCurrency
+}
+
+func parseUnixTimestamp(raw string) *time.Time {
+	if raw == "" {
+		return nil
+	}
+	seconds, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return nil
+	}
+	t := time.Unix(seconds, 0)
+	return &t
+}
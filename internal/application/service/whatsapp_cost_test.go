@@ -0,0 +1,171 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockWhatsAppConversationCostRepository struct {
+	costs []*entity.WhatsAppConversationCost
+}
+
+func (m *mockWhatsAppConversationCostRepository) Create(ctx context.Context, cost *entity.WhatsAppConversationCost) error {
+	m.costs = append(m.costs, cost)
+	return nil
+}
+
+func (m *mockWhatsAppConversationCostRepository) FindByWAConversationID(ctx context.Context, channelID, waConversationID string) (*entity.WhatsAppConversationCost, error) {
+	for _, cost := range m.costs {
+		if cost.ChannelID == channelID && cost.WAConversationID == waConversationID {
+			return cost, nil
+		}
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "WhatsApp conversation cost not found")
+}
+
+func (m *mockWhatsAppConversationCostRepository) List(ctx context.Context, filter entity.AnalyticsFilter) ([]*entity.WhatsAppConversationCost, error) {
+	var result []*entity.WhatsAppConversationCost
+	for _, cost := range m.costs {
+		if cost.TenantID == filter.TenantID {
+			result = append(result, cost)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockWhatsAppConversationCostRepository) SummarizeByChannel(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.WhatsAppCostSummary, error) {
+	return m.summarize(filter, func(c *entity.WhatsAppConversationCost) string { return c.ChannelID }), nil
+}
+
+func (m *mockWhatsAppConversationCostRepository) SummarizeByCampaign(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.WhatsAppCostSummary, error) {
+	return m.summarize(filter, func(c *entity.WhatsAppConversationCost) string { return c.CampaignID }), nil
+}
+
+func (m *mockWhatsAppConversationCostRepository) summarize(filter entity.AnalyticsFilter, key func(*entity.WhatsAppConversationCost) string) []entity.WhatsAppCostSummary {
+	byKey := make(map[string]*entity.WhatsAppCostSummary)
+	var order []string
+	for _, cost := range m.costs {
+		k := key(cost)
+		if cost.TenantID != filter.TenantID || k == "" {
+			continue
+		}
+		summary, ok := byKey[k]
+		if !ok {
+			summary = &entity.WhatsAppCostSummary{ChannelID: cost.ChannelID, CampaignID: cost.CampaignID, Currency: cost.Currency, ByCategory: map[string]int64{}}
+			byKey[k] = summary
+			order = append(order, k)
+		}
+		summary.Conversations++
+		if cost.Billable {
+			summary.Billable++
+		}
+		summary.Cost += cost.Cost
+		summary.ByCategory[string(cost.Category)]++
+	}
+
+	result := make([]entity.WhatsAppCostSummary, 0, len(order))
+	for _, k := range order {
+		result = append(result, *byKey[k])
+	}
+	return result
+}
+
+func newTestCostService() (*WhatsAppCostService, *mockWhatsAppConversationCostRepository, *testutil.MockMessageRepository) {
+	costRepo := &mockWhatsAppConversationCostRepository{}
+	messageRepo := testutil.NewMockMessageRepository()
+	return NewWhatsAppCostService(costRepo, messageRepo), costRepo, messageRepo
+}
+
+func testCostChannel() *entity.Channel {
+	return &entity.Channel{ID: "channel-1", TenantID: "tenant-1", Type: entity.ChannelTypeWhatsAppOfficial, Config: map[string]string{}}
+}
+
+func TestWhatsAppCostRecordPricingOncePerWindow(t *testing.T) {
+	svc, costRepo, messageRepo := newTestCostService()
+	ctx := context.Background()
+	messageRepo.Messages["msg-1"] = &entity.Message{ID: "msg-1", ExternalID: "wamid.1", Metadata: map[string]string{"campaign_id": "camp-1"}}
+
+	input := &RecordPricingInput{
+		Channel:             testCostChannel(),
+		ExternalMessageID:   "wamid.1",
+		WAConversationID:    "conv-1",
+		Origin:              "marketing",
+		ExpirationTimestamp: "1760000000",
+		Billable:            true,
+		PricingModel:        "CBP",
+		Category:            "MARKETING",
+	}
+	require.NoError(t, svc.RecordPricing(ctx, input))
+	require.NoError(t, svc.RecordPricing(ctx, input))
+
+	require.Len(t, costRepo.costs, 1)
+	cost := costRepo.costs[0]
+	assert.Equal(t, entity.WhatsAppPricingMarketing, cost.Category)
+	assert.Equal(t, "msg-1", cost.MessageID)
+	assert.Equal(t, "camp-1", cost.CampaignID)
+	assert.InDelta(t, entity.WhatsAppPricingMarketing.DefaultRate(), cost.Cost, 0.00001)
+	assert.Equal(t, "USD", cost.Currency)
+	require.NotNil(t, cost.ExpiresAt)
+	assert.Equal(t, int64(1760000000), cost.ExpiresAt.Unix())
+}
+
+func TestWhatsAppCostRecordPricingUsesChannelRateCard(t *testing.T) {
+	svc, costRepo, _ := newTestCostService()
+	channel := testCostChannel()
+	channel.Config["pricing_rate_utility"] = "0.0125"
+	channel.Config["pricing_currency"] = "brl"
+
+	require.NoError(t, svc.RecordPricing(context.Background(), &RecordPricingInput{
+		Channel: channel, ExternalMessageID: "wamid.2", Billable: true, Category: "utility",
+	}))
+
+	require.Len(t, costRepo.costs, 1)
+	assert.Equal(t, "wamid.2", costRepo.costs[0].WAConversationID)
+	assert.InDelta(t, 0.0125, costRepo.costs[0].Cost, 0.00001)
+	assert.Equal(t, "BRL", costRepo.costs[0].Currency)
+}
+
+func TestWhatsAppCostRecordPricingNonBillableIsFree(t *testing.T) {
+	svc, costRepo, _ := newTestCostService()
+
+	require.NoError(t, svc.RecordPricing(context.Background(), &RecordPricingInput{
+		Channel: testCostChannel(), WAConversationID: "conv-3", Billable: false, Category: "marketing",
+	}))
+
+	require.Len(t, costRepo.costs, 1)
+	assert.Zero(t, costRepo.costs[0].Cost)
+}
+
+func TestWhatsAppCostTotalsAndExport(t *testing.T) {
+	svc, _, _ := newTestCostService()
+	ctx := context.Background()
+
+	for _, in := range []*RecordPricingInput{
+		{Channel: testCostChannel(), WAConversationID: "c1", Billable: true, Category: "marketing"},
+		{Channel: testCostChannel(), WAConversationID: "c2", Billable: true, Category: "utility"},
+		{Channel: testCostChannel(), WAConversationID: "c3", Billable: false, Category: "service"},
+	} {
+		require.NoError(t, svc.RecordPricing(ctx, in))
+	}
+
+	totals, err := svc.GetTotals(ctx, "tenant-1", time.Now().Add(-time.Hour), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), totals.Conversations)
+	assert.Equal(t, int64(2), totals.Billable)
+	assert.Equal(t, int64(1), totals.ByCategory["utility"])
+	assert.InDelta(t, entity.WhatsAppPricingMarketing.DefaultRate()+entity.WhatsAppPricingUtility.DefaultRate(), totals.Cost, 0.00001)
+
+	data, err := svc.ExportCSV(ctx, "tenant-1", time.Now().Add(-time.Hour), time.Now())
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Len(t, lines, 4)
+	assert.True(t, strings.HasPrefix(lines[0], "Date,Channel ID,Conversation ID"))
+}
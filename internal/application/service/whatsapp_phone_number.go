@@ -0,0 +1,333 @@
+package service
+
+import (
+	"context"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// WhatsAppPhoneNumberService manages the phone numbers owned by WhatsApp Official channels.
+// It routes inbound traffic by phone_number_id and balances outbound sends across
+// numbers according to their messaging limit tier.
+type WhatsAppPhoneNumberService struct {
+	numberRepo  repository.WhatsAppPhoneNumberRepository
+	channelRepo repository.ChannelRepository
+	mu          sync.Mutex
+	now         func() time.Time
+}
+
+// NewWhatsAppPhoneNumberService creates a new WhatsApp phone number service
+func NewWhatsAppPhoneNumberService(
+	numberRepo repository.WhatsAppPhoneNumberRepository,
+	channelRepo repository.ChannelRepository,
+) *WhatsAppPhoneNumberService {
+	return &WhatsAppPhoneNumberService{
+		numberRepo:  numberRepo,
+		channelRepo: channelRepo,
+		now:         time.Now,
+	}
+}
+
+// AddPhoneNumberInput represents input for attaching a phone number to a channel
+type AddPhoneNumberInput struct {
+	TenantID           string
+	ChannelID          string
+	PhoneNumberID      string
+	DisplayPhoneNumber string
+	VerifiedName       string
+	QualityRating      string
+	MessagingLimitTier string
+	IsDefault          bool
+}
+
+// UpdatePhoneNumberInput represents input for updating a channel phone number
+type UpdatePhoneNumberInput struct {
+	DisplayPhoneNumber *string
+	VerifiedName       *string
+	QualityRating      *string
+	MessagingLimitTier *string
+	IsDefault          *bool
+	Enabled            *bool
+}
+
+// Add attaches a new phone number to a WhatsApp Official channel
+func (s *WhatsAppPhoneNumberService) Add(ctx context.Context, input *AddPhoneNumberInput) (*entity.WhatsAppPhoneNumber, error) {
+	phoneNumberID := strings.TrimSpace(input.PhoneNumberID)
+	if phoneNumberID == "" {
+		return nil, errors.New(errors.ErrCodeValidation, "phone_number_id is required")
+	}
+
+	channel, err := s.getChannel(ctx, input.TenantID, input.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing, err := s.numberRepo.FindByPhoneNumberID(ctx, phoneNumberID); err == nil && existing != nil {
+		return nil, errors.New(errors.ErrCodeConflict, "phone number is already registered on a channel")
+	}
+
+	numbers, err := s.numberRepo.FindByChannel(ctx, channel.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	number := entity.NewWhatsAppPhoneNumber(channel.TenantID, channel.ID, phoneNumberID)
+	number.ID = uuid.New().String()
+	number.DisplayPhoneNumber = input.DisplayPhoneNumber
+	number.VerifiedName = input.VerifiedName
+	if input.QualityRating != "" {
+		number.QualityRating = strings.ToUpper(input.QualityRating)
+	}
+	if input.MessagingLimitTier != "" {
+		number.MessagingLimitTier = entity.MessagingLimitTier(strings.ToUpper(input.MessagingLimitTier))
+	}
+	// The first number of a channel always becomes its default sender
+	number.IsDefault = input.IsDefault || len(numbers) == 0
+
+	if number.IsDefault {
+		if err := s.clearDefault(ctx, numbers); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.numberRepo.Create(ctx, number); err != nil {
+		return nil, err
+	}
+
+	return number, nil
+}
+
+// List returns the phone numbers of a channel
+func (s *WhatsAppPhoneNumberService) List(ctx context.Context, tenantID, channelID string) ([]*entity.WhatsAppPhoneNumber, error) {
+	channel, err := s.getChannel(ctx, tenantID, channelID)
+	if err != nil {
+		return nil, err
+	}
+	return s.numberRepo.FindByChannel(ctx, channel.ID)
+}
+
+// Update updates a phone number of a channel
+func (s *WhatsAppPhoneNumberService) Update(ctx context.Context, tenantID, channelID, id string, input *UpdatePhoneNumberInput) (*entity.WhatsAppPhoneNumber, error) {
+	number, err := s.getNumber(ctx, tenantID, channelID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.DisplayPhoneNumber != nil {
+		number.DisplayPhoneNumber = *input.DisplayPhoneNumber
+	}
+	if input.VerifiedName != nil {
+		number.VerifiedName = *input.VerifiedName
+	}
+	if input.QualityRating != nil {
+		number.QualityRating = strings.ToUpper(*input.QualityRating)
+	}
+	if input.MessagingLimitTier != nil {
+		number.MessagingLimitTier = entity.MessagingLimitTier(strings.ToUpper(*input.MessagingLimitTier))
+	}
+	if input.Enabled != nil {
+		number.Enabled = *input.Enabled
+	}
+	if input.IsDefault != nil && *input.IsDefault && !number.IsDefault {
+		numbers, err := s.numberRepo.FindByChannel(ctx, channelID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.clearDefault(ctx, numbers); err != nil {
+			return nil, err
+		}
+		number.IsDefault = true
+	}
+	number.UpdatedAt = s.now()
+
+	if err := s.numberRepo.Update(ctx, number); err != nil {
+		return nil, err
+	}
+	return number, nil
+}
+
+// Remove detaches a phone number from a channel
+func (s *WhatsAppPhoneNumberService) Remove(ctx context.Context, tenantID, channelID, id string) error {
+	number, err := s.getNumber(ctx, tenantID, channelID, id)
+	if err != nil {
+		return err
+	}
+	return s.numberRepo.Delete(ctx, number.ID)
+}
+
+// ResolveChannel returns the channel that owns a Meta phone_number_id. It is used to
+// route webhooks of a shared WABA to the right channel.
+func (s *WhatsAppPhoneNumberService) ResolveChannel(ctx context.Context, phoneNumberID string) (*entity.Channel, error) {
+	if phoneNumberID == "" {
+		return nil, errors.New(errors.ErrCodeValidation, "phone_number_id is required")
+	}
+	number, err := s.numberRepo.FindByPhoneNumberID(ctx, phoneNumberID)
+	if err != nil {
+		return nil, err
+	}
+	return s.channelRepo.FindByID(ctx, number.ChannelID)
+}
+
+// UpdateQuality records quality rating and tier changes reported by Meta for a number.
+// Unknown numbers are ignored.
+func (s *WhatsAppPhoneNumberService) UpdateQuality(ctx context.Context, phoneNumberID, qualityRating, tier string) error {
+	number, err := s.numberRepo.FindByPhoneNumberID(ctx, phoneNumberID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if qualityRating != "" {
+		number.QualityRating = strings.ToUpper(qualityRating)
+	}
+	if tier != "" {
+		number.MessagingLimitTier = entity.MessagingLimitTier(strings.ToUpper(tier))
+	}
+	number.UpdatedAt = s.now()
+	return s.numberRepo.Update(ctx, number)
+}
+
+// SelectSender picks the phone number an outbound send should use and counts the send
+// against its tier. Numbers with a low quality rating are only used when no healthy
+// number has capacity left. Returns an empty string when the channel has no extra numbers,
+// meaning the channel's own phone_number_id should be used.
+func (s *WhatsAppPhoneNumberService) SelectSender(ctx context.Context, channel *entity.Channel) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	numbers, err := s.numberRepo.FindByChannel(ctx, channel.ID)
+	if err != nil {
+		return "", err
+	}
+	if len(numbers) == 0 {
+		return "", nil
+	}
+
+	now := s.now()
+	selected := pickPhoneNumber(numbers, now, false)
+	if selected == nil {
+		selected = pickPhoneNumber(numbers, now, true)
+	}
+	if selected == nil {
+		return "", errors.New(errors.ErrCodeQuotaExceeded, "all phone numbers of the channel reached their messaging limit")
+	}
+
+	selected.RecordSend(now)
+	if err := s.numberRepo.Update(ctx, selected); err != nil {
+		return "", err
+	}
+
+	return selected.PhoneNumberID, nil
+}
+
+// AssignSender chooses the phone number a WhatsApp Official message is sent from and
+// records it in the message's metadata. Replies stay on the number the contact wrote
+// to; templates are balanced across numbers. A nil service only keeps replies on the
+// conversation's number.
+func (s *WhatsAppPhoneNumberService) AssignSender(ctx context.Context, channel *entity.Channel, conversation *entity.Conversation, message *entity.Message) error {
+	if channel.Type != entity.ChannelTypeWhatsAppOfficial || message.Metadata["phone_number_id"] != "" {
+		return nil
+	}
+
+	if phoneNumberID := conversation.Metadata["phone_number_id"]; phoneNumberID != "" && message.ContentType != entity.ContentTypeTemplate {
+		message.Metadata["phone_number_id"] = phoneNumberID
+		return nil
+	}
+
+	if s == nil || message.ContentType != entity.ContentTypeTemplate {
+		return nil
+	}
+
+	phoneNumberID, err := s.SelectSender(ctx, channel)
+	if err != nil {
+		return err
+	}
+	if phoneNumberID != "" {
+		message.Metadata["phone_number_id"] = phoneNumberID
+	}
+	return nil
+}
+
+// pickPhoneNumber returns the enabled number with the most remaining capacity,
+// preferring the default number on ties.
+func pickPhoneNumber(numbers []*entity.WhatsAppPhoneNumber, now time.Time, allowFlagged bool) *entity.WhatsAppPhoneNumber {
+	var best *entity.WhatsAppPhoneNumber
+	bestCapacity := 0
+
+	for _, number := range numbers {
+		if !number.Enabled || (number.IsFlagged() && !allowFlagged) {
+			continue
+		}
+
+		capacity := number.RemainingCapacity(now)
+		if capacity < 0 {
+			capacity = math.MaxInt
+		}
+		if capacity == 0 {
+			continue
+		}
+
+		if best == nil || capacity > bestCapacity || (capacity == bestCapacity && number.IsDefault) {
+			best = number
+			bestCapacity = capacity
+		}
+	}
+
+	return best
+}
+
+func (s *WhatsAppPhoneNumberService) clearDefault(ctx context.Context, numbers []*entity.WhatsAppPhoneNumber) error {
+	for _, existing := range numbers {
+		if !existing.IsDefault {
+			continue
+		}
+		existing.IsDefault = false
+		existing.UpdatedAt = s.now()
+		if err := s.numberRepo.Update(ctx, existing); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *WhatsAppPhoneNumberService) getChannel(ctx context.Context, tenantID, channelID string) (*entity.Channel, error) {
+	if tenantID == "" || channelID == "" {
+		return nil, errors.New(errors.ErrCodeValidation, "Tenant ID and channel ID are required")
+	}
+
+	channel, err := s.channelRepo.FindByID(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if channel.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeChannelNotFound, "channel not found")
+	}
+	if channel.Type != entity.ChannelTypeWhatsAppOfficial {
+		return nil, errors.New(errors.ErrCodeValidation, "phone numbers can only be managed on WhatsApp Official channels")
+	}
+	return channel, nil
+}
+
+func (s *WhatsAppPhoneNumberService) getNumber(ctx context.Context, tenantID, channelID, id string) (*entity.WhatsAppPhoneNumber, error) {
+	if _, err := s.getChannel(ctx, tenantID, channelID); err != nil {
+		return nil, err
+	}
+
+	number, err := s.numberRepo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if number.ChannelID != channelID {
+		return nil, errors.New(errors.ErrCodeNotFound, "WhatsApp phone number not found")
+	}
+	return number, nil
+}
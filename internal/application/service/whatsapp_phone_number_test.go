@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockWhatsAppPhoneNumberRepository struct {
+	numbers map[string]*entity.WhatsAppPhoneNumber
+}
+
+func newMockWhatsAppPhoneNumberRepository() *mockWhatsAppPhoneNumberRepository {
+	return &mockWhatsAppPhoneNumberRepository{numbers: make(map[string]*entity.WhatsAppPhoneNumber)}
+}
+
+func (m *mockWhatsAppPhoneNumberRepository) Create(ctx context.Context, number *entity.WhatsAppPhoneNumber) error {
+	m.numbers[number.ID] = number
+	return nil
+}
+
+func (m *mockWhatsAppPhoneNumberRepository) FindByID(ctx context.Context, id string) (*entity.WhatsAppPhoneNumber, error) {
+	number, ok := m.numbers[id]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, "WhatsApp phone number not found")
+	}
+	return number, nil
+}
+
+func (m *mockWhatsAppPhoneNumberRepository) FindByChannel(ctx context.Context, channelID string) ([]*entity.WhatsAppPhoneNumber, error) {
+	var result []*entity.WhatsAppPhoneNumber
+	for _, number := range m.numbers {
+		if number.ChannelID == channelID {
+			result = append(result, number)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockWhatsAppPhoneNumberRepository) FindByPhoneNumberID(ctx context.Context, phoneNumberID string) (*entity.WhatsAppPhoneNumber, error) {
+	for _, number := range m.numbers {
+		if number.PhoneNumberID == phoneNumberID {
+			return number, nil
+		}
+	}
+	return nil, errors.New(errors.ErrCodeNotFound, "WhatsApp phone number not found")
+}
+
+func (m *mockWhatsAppPhoneNumberRepository) Update(ctx context.Context, number *entity.WhatsAppPhoneNumber) error {
+	m.numbers[number.ID] = number
+	return nil
+}
+
+func (m *mockWhatsAppPhoneNumberRepository) Delete(ctx context.Context, id string) error {
+	delete(m.numbers, id)
+	return nil
+}
+
+func newTestPhoneNumberService() (*WhatsAppPhoneNumberService, *mockWhatsAppPhoneNumberRepository) {
+	channelRepo := testutil.NewMockChannelRepository()
+	channelRepo.Channels["channel-1"] = &entity.Channel{ID: "channel-1", TenantID: "tenant-1", Type: entity.ChannelTypeWhatsAppOfficial}
+	channelRepo.Channels["channel-tg"] = &entity.Channel{ID: "channel-tg", TenantID: "tenant-1", Type: entity.ChannelTypeTelegram}
+	numberRepo := newMockWhatsAppPhoneNumberRepository()
+	return NewWhatsAppPhoneNumberService(numberRepo, channelRepo), numberRepo
+}
+
+func TestWhatsAppPhoneNumberServiceAddMakesFirstNumberDefault(t *testing.T) {
+	svc, _ := newTestPhoneNumberService()
+	ctx := context.Background()
+
+	first, err := svc.Add(ctx, &AddPhoneNumberInput{TenantID: "tenant-1", ChannelID: "channel-1", PhoneNumberID: "pn-1"})
+	require.NoError(t, err)
+	assert.True(t, first.IsDefault)
+
+	second, err := svc.Add(ctx, &AddPhoneNumberInput{TenantID: "tenant-1", ChannelID: "channel-1", PhoneNumberID: "pn-2", IsDefault: true})
+	require.NoError(t, err)
+	assert.True(t, second.IsDefault)
+	assert.False(t, first.IsDefault, "previous default must be cleared")
+}
+
+func TestWhatsAppPhoneNumberServiceAddRejectsDuplicatesAndOtherChannelTypes(t *testing.T) {
+	svc, _ := newTestPhoneNumberService()
+	ctx := context.Background()
+
+	_, err := svc.Add(ctx, &AddPhoneNumberInput{TenantID: "tenant-1", ChannelID: "channel-1", PhoneNumberID: "pn-1"})
+	require.NoError(t, err)
+
+	_, err = svc.Add(ctx, &AddPhoneNumberInput{TenantID: "tenant-1", ChannelID: "channel-1", PhoneNumberID: "pn-1"})
+	require.Error(t, err)
+
+	_, err = svc.Add(ctx, &AddPhoneNumberInput{TenantID: "tenant-1", ChannelID: "channel-tg", PhoneNumberID: "pn-3"})
+	require.Error(t, err)
+
+	_, err = svc.Add(ctx, &AddPhoneNumberInput{TenantID: "tenant-2", ChannelID: "channel-1", PhoneNumberID: "pn-4"})
+	require.Error(t, err)
+}
+
+func TestWhatsAppPhoneNumberServiceResolveChannel(t *testing.T) {
+	svc, _ := newTestPhoneNumberService()
+	ctx := context.Background()
+
+	_, err := svc.Add(ctx, &AddPhoneNumberInput{TenantID: "tenant-1", ChannelID: "channel-1", PhoneNumberID: "pn-1"})
+	require.NoError(t, err)
+
+	channel, err := svc.ResolveChannel(ctx, "pn-1")
+	require.NoError(t, err)
+	assert.Equal(t, "channel-1", channel.ID)
+
+	_, err = svc.ResolveChannel(ctx, "unknown")
+	assert.Error(t, err)
+}
+
+func TestWhatsAppPhoneNumberServiceSelectSenderBalancesByTier(t *testing.T) {
+	svc, repo := newTestPhoneNumberService()
+	ctx := context.Background()
+	channel := &entity.Channel{ID: "channel-1", TenantID: "tenant-1", Type: entity.ChannelTypeWhatsAppOfficial}
+
+	sender, err := svc.SelectSender(ctx, channel)
+	require.NoError(t, err)
+	assert.Empty(t, sender, "channels without extra numbers keep their own phone number")
+
+	_, err = svc.Add(ctx, &AddPhoneNumberInput{TenantID: "tenant-1", ChannelID: "channel-1", PhoneNumberID: "pn-small", MessagingLimitTier: "TIER_50"})
+	require.NoError(t, err)
+	big, err := svc.Add(ctx, &AddPhoneNumberInput{TenantID: "tenant-1", ChannelID: "channel-1", PhoneNumberID: "pn-big", MessagingLimitTier: "TIER_1K"})
+	require.NoError(t, err)
+
+	sender, err = svc.SelectSender(ctx, channel)
+	require.NoError(t, err)
+	assert.Equal(t, "pn-big", sender)
+	assert.Equal(t, 1, repo.numbers[big.ID].SentInWindow)
+}
+
+func TestWhatsAppPhoneNumberServiceAssignSender(t *testing.T) {
+	svc, _ := newTestPhoneNumberService()
+	ctx := context.Background()
+	channel := &entity.Channel{ID: "channel-1", TenantID: "tenant-1", Type: entity.ChannelTypeWhatsAppOfficial}
+	conversation := &entity.Conversation{ID: "conv-1", Metadata: map[string]string{"phone_number_id": "pn-contact"}}
+	_, err := svc.Add(ctx, &AddPhoneNumberInput{TenantID: "tenant-1", ChannelID: "channel-1", PhoneNumberID: "pn-big", MessagingLimitTier: "TIER_1K"})
+	require.NoError(t, err)
+
+	reply := &entity.Message{ContentType: entity.ContentTypeText, Metadata: map[string]string{}}
+	require.NoError(t, svc.AssignSender(ctx, channel, conversation, reply))
+	assert.Equal(t, "pn-contact", reply.Metadata["phone_number_id"], "replies stay on the number the contact wrote to")
+
+	template := &entity.Message{ContentType: entity.ContentTypeTemplate, Metadata: map[string]string{}}
+	require.NoError(t, svc.AssignSender(ctx, channel, conversation, template))
+	assert.Equal(t, "pn-big", template.Metadata["phone_number_id"])
+
+	// Without the service, replies still stay on the conversation's number
+	var none *WhatsAppPhoneNumberService
+	reply = &entity.Message{ContentType: entity.ContentTypeText, Metadata: map[string]string{}}
+	require.NoError(t, none.AssignSender(ctx, channel, conversation, reply))
+	assert.Equal(t, "pn-contact", reply.Metadata["phone_number_id"])
+	template = &entity.Message{ContentType: entity.ContentTypeTemplate, Metadata: map[string]string{}}
+	require.NoError(t, none.AssignSender(ctx, channel, conversation, template))
+	assert.Empty(t, template.Metadata["phone_number_id"])
+}
+
+func TestWhatsAppPhoneNumberServiceSelectSenderSkipsFlaggedAndExhausted(t *testing.T) {
+	svc, _ := newTestPhoneNumberService()
+	ctx := context.Background()
+	channel := &entity.Channel{ID: "channel-1", TenantID: "tenant-1", Type: entity.ChannelTypeWhatsAppOfficial}
+
+	flagged, err := svc.Add(ctx, &AddPhoneNumberInput{TenantID: "tenant-1", ChannelID: "channel-1", PhoneNumberID: "pn-red", MessagingLimitTier: "TIER_10K", QualityRating: "RED"})
+	require.NoError(t, err)
+	healthy, err := svc.Add(ctx, &AddPhoneNumberInput{TenantID: "tenant-1", ChannelID: "channel-1", PhoneNumberID: "pn-green", MessagingLimitTier: "TIER_50"})
+	require.NoError(t, err)
+
+	sender, err := svc.SelectSender(ctx, channel)
+	require.NoError(t, err)
+	assert.Equal(t, "pn-green", sender)
+
+	now := time.Now()
+	healthy.WindowStartedAt = &now
+	healthy.SentInWindow = 50
+
+	sender, err = svc.SelectSender(ctx, channel)
+	require.NoError(t, err)
+	assert.Equal(t, "pn-red", sender, "flagged numbers are used only as last resort")
+
+	flagged.Enabled = false
+	_, err = svc.SelectSender(ctx, channel)
+	require.Error(t, err)
+}
+
+func TestWhatsAppPhoneNumberServiceUpdateQualityIgnoresUnknownNumbers(t *testing.T) {
+	svc, repo := newTestPhoneNumberService()
+	ctx := context.Background()
+
+	require.NoError(t, svc.UpdateQuality(ctx, "unknown", "RED", "TIER_1K"))
+
+	number, err := svc.Add(ctx, &AddPhoneNumberInput{TenantID: "tenant-1", ChannelID: "channel-1", PhoneNumberID: "pn-1"})
+	require.NoError(t, err)
+
+	require.NoError(t, svc.UpdateQuality(ctx, "pn-1", "yellow", "TIER_10K"))
+	assert.Equal(t, "YELLOW", repo.numbers[number.ID].QualityRating)
+	assert.Equal(t, entity.MessagingLimitTier10K, repo.numbers[number.ID].MessagingLimitTier)
+}
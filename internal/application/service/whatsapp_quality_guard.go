@@ -0,0 +1,228 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Events published when the quality guard changes the sending state of a channel
+const (
+	EventChannelMarketingPaused  = "channel.marketing_paused"
+	EventChannelMarketingResumed = "channel.marketing_resumed"
+)
+
+// WhatsAppQualityGuardService protects WhatsApp numbers from bans by pausing marketing
+// sends when Meta flags the number quality or downgrades its messaging tier.
+// Once paused, marketing sends stay blocked until an admin re-enables them.
+type WhatsAppQualityGuardService struct {
+	channelRepo  repository.ChannelRepository
+	templateRepo repository.TemplateRepository
+	producer     nats.Publisher
+}
+
+// NewWhatsAppQualityGuardService creates a new quality guard service
+func NewWhatsAppQualityGuardService(
+	channelRepo repository.ChannelRepository,
+	templateRepo repository.TemplateRepository,
+	producer nats.Publisher,
+) *WhatsAppQualityGuardService {
+	return &WhatsAppQualityGuardService{
+		channelRepo:  channelRepo,
+		templateRepo: templateRepo,
+		producer:     producer,
+	}
+}
+
+// MarketingPauseStatus describes the marketing sending state of a channel
+type MarketingPauseStatus struct {
+	ChannelID          string `json:"channel_id"`
+	Paused             bool   `json:"paused"`
+	Reason             string `json:"reason,omitempty"`
+	PausedAt           string `json:"paused_at,omitempty"`
+	QualityRating      string `json:"quality_rating,omitempty"`
+	MessagingLimitTier string `json:"messaging_limit_tier,omitempty"`
+}
+
+// EvaluatePhoneQuality inspects a phone_number_quality_update event and pauses marketing
+// sends on the channel when the number is flagged or its tier went down. The channel is
+// modified in place; the caller is responsible for persisting it. Returns true when the
+// channel was paused by this event.
+func (s *WhatsAppQualityGuardService) EvaluatePhoneQuality(ctx context.Context, channel *entity.Channel, event, previousTier, currentTier string) bool {
+	if channel == nil || channel.IsMarketingPaused() {
+		return false
+	}
+
+	reason := qualityPauseReason(event, previousTier, currentTier)
+	if reason == "" {
+		return false
+	}
+
+	channel.PauseMarketing(reason, time.Now())
+
+	logger.Warn("Marketing sends paused after WhatsApp quality update",
+		zap.String("channel_id", channel.ID),
+		zap.String("event", event),
+		zap.String("reason", reason),
+	)
+
+	s.publish(ctx, EventChannelMarketingPaused, channel, map[string]interface{}{
+		"reason":        reason,
+		"quality_event": event,
+		"previous_tier": previousTier,
+		"current_tier":  currentTier,
+		"message":       "Marketing sends were paused to protect the phone number. An admin must re-enable them explicitly.",
+	})
+
+	return true
+}
+
+// ResumeMarketing explicitly re-enables marketing sends on a channel
+func (s *WhatsAppQualityGuardService) ResumeMarketing(ctx context.Context, tenantID, channelID, userID string) (*MarketingPauseStatus, error) {
+	channel, err := s.getChannel(ctx, tenantID, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !channel.IsMarketingPaused() {
+		return nil, errors.New(errors.ErrCodeConflict, "marketing sends are not paused on this channel")
+	}
+
+	channel.ResumeMarketing()
+	if err := s.channelRepo.Update(ctx, channel); err != nil {
+		return nil, err
+	}
+
+	s.publish(ctx, EventChannelMarketingResumed, channel, map[string]interface{}{
+		"resumed_by": userID,
+	})
+
+	return marketingPauseStatus(channel), nil
+}
+
+// GetStatus returns the marketing sending state of a channel
+func (s *WhatsAppQualityGuardService) GetStatus(ctx context.Context, tenantID, channelID string) (*MarketingPauseStatus, error) {
+	channel, err := s.getChannel(ctx, tenantID, channelID)
+	if err != nil {
+		return nil, err
+	}
+	return marketingPauseStatus(channel), nil
+}
+
+// CheckOutbound rejects marketing messages on channels whose marketing sends are paused
+func (s *WhatsAppQualityGuardService) CheckOutbound(ctx context.Context, channel *entity.Channel, message *entity.Message) error {
+	if channel == nil || !channel.IsMarketingPaused() {
+		return nil
+	}
+	if !s.isMarketingMessage(ctx, channel, message) {
+		return nil
+	}
+
+	return errors.New(errors.ErrCodeForbidden, "marketing sends are paused on this channel after a quality warning").
+		WithDetails(map[string]string{"reason": channel.Config["marketing_paused_reason"]})
+}
+
+// isMarketingMessage reports whether a message counts as marketing traffic: campaign sends
+// and templates of the MARKETING category.
+func (s *WhatsAppQualityGuardService) isMarketingMessage(ctx context.Context, channel *entity.Channel, message *entity.Message) bool {
+	if message.Metadata["campaign_id"] != "" {
+		return true
+	}
+	if message.ContentType != entity.ContentTypeTemplate {
+		return false
+	}
+	if category := message.Metadata["template_category"]; category != "" {
+		return strings.EqualFold(category, string(entity.TemplateCategoryMarketing))
+	}
+
+	name := message.Metadata["template_name"]
+	if name == "" || s.templateRepo == nil {
+		return false
+	}
+	template, err := s.templateRepo.FindByName(ctx, channel.TenantID, channel.ID, name, message.Metadata["template_language"])
+	if err != nil || template == nil {
+		return false
+	}
+	return template.Category == entity.TemplateCategoryMarketing
+}
+
+// qualityPauseReason returns why a quality event should pause marketing sends, or an
+// empty string when it should not.
+func qualityPauseReason(event, previousTier, currentTier string) string {
+	switch strings.ToUpper(event) {
+	case "FLAGGED":
+		return "phone number quality flagged"
+	case "DOWNGRADE":
+		return "messaging limit tier downgraded"
+	}
+
+	if previousTier == "" || currentTier == "" {
+		return ""
+	}
+	if isTierDowngrade(entity.MessagingLimitTier(previousTier), entity.MessagingLimitTier(currentTier)) {
+		return "messaging limit tier downgraded"
+	}
+	return ""
+}
+
+func isTierDowngrade(previous, current entity.MessagingLimitTier) bool {
+	previousLimit := previous.DailyLimit()
+	currentLimit := current.DailyLimit()
+	if currentLimit < 0 {
+		return false
+	}
+	return previousLimit < 0 || currentLimit < previousLimit
+}
+
+func marketingPauseStatus(channel *entity.Channel) *MarketingPauseStatus {
+	status := &MarketingPauseStatus{
+		ChannelID: channel.ID,
+		Paused:    channel.IsMarketingPaused(),
+	}
+	if channel.Config != nil {
+		status.Reason = channel.Config["marketing_paused_reason"]
+		status.PausedAt = channel.Config["marketing_paused_at"]
+		status.QualityRating = channel.Config["quality_rating"]
+		status.MessagingLimitTier = channel.Config["messaging_limit_tier"]
+	}
+	return status
+}
+
+func (s *WhatsAppQualityGuardService) getChannel(ctx context.Context, tenantID, channelID string) (*entity.Channel, error) {
+	if tenantID == "" || channelID == "" {
+		return nil, errors.New(errors.ErrCodeValidation, "Tenant ID and channel ID are required")
+	}
+	channel, err := s.channelRepo.FindByID(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if channel.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeChannelNotFound, "channel not found")
+	}
+	return channel, nil
+}
+
+func (s *WhatsAppQualityGuardService) publish(ctx context.Context, eventType string, channel *entity.Channel, payload map[string]interface{}) {
+	if s.producer == nil {
+		return
+	}
+
+	payload["channel_id"] = channel.ID
+	payload["channel_name"] = channel.Name
+	event := &nats.Event{
+		Type:      eventType,
+		TenantID:  channel.TenantID,
+		Payload:   payload,
+		Timestamp: time.Now(),
+	}
+	if err := s.producer.PublishEvent(ctx, event); err != nil {
+		logger.Error("Failed to publish quality guard event", zap.String("type", eventType), zap.Error(err))
+	}
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestQualityGuard() (*WhatsAppQualityGuardService, *testutil.MockChannelRepository, *testutil.MockProducer) {
+	channelRepo := testutil.NewMockChannelRepository()
+	channelRepo.Channels["channel-1"] = &entity.Channel{
+		ID:       "channel-1",
+		TenantID: "tenant-1",
+		Type:     entity.ChannelTypeWhatsAppOfficial,
+		Config:   map[string]string{"messaging_limit_tier": "TIER_10K"},
+	}
+	producer := testutil.NewMockProducer()
+	return NewWhatsAppQualityGuardService(channelRepo, nil, producer), channelRepo, producer
+}
+
+func TestQualityGuardPausesOnFlagged(t *testing.T) {
+	svc, channelRepo, producer := newTestQualityGuard()
+	channel := channelRepo.Channels["channel-1"]
+
+	paused := svc.EvaluatePhoneQuality(context.Background(), channel, "FLAGGED", "TIER_10K", "TIER_10K")
+
+	assert.True(t, paused)
+	assert.True(t, channel.IsMarketingPaused())
+	assert.Equal(t, "phone number quality flagged", channel.Config["marketing_paused_reason"])
+	require.Len(t, producer.Events, 1)
+	assert.Equal(t, EventChannelMarketingPaused, producer.Events[0].Type)
+}
+
+func TestQualityGuardPausesOnTierDowngrade(t *testing.T) {
+	svc, channelRepo, _ := newTestQualityGuard()
+	channel := channelRepo.Channels["channel-1"]
+
+	assert.True(t, svc.EvaluatePhoneQuality(context.Background(), channel, "ONBOARDING", "TIER_10K", "TIER_1K"))
+	assert.Equal(t, "messaging limit tier downgraded", channel.Config["marketing_paused_reason"])
+}
+
+func TestQualityGuardIgnoresUpgrade(t *testing.T) {
+	svc, channelRepo, producer := newTestQualityGuard()
+	channel := channelRepo.Channels["channel-1"]
+
+	assert.False(t, svc.EvaluatePhoneQuality(context.Background(), channel, "UPGRADE", "TIER_1K", "TIER_10K"))
+	assert.False(t, channel.IsMarketingPaused())
+	assert.Empty(t, producer.Events)
+}
+
+func TestQualityGuardDoesNotPauseTwice(t *testing.T) {
+	svc, channelRepo, producer := newTestQualityGuard()
+	channel := channelRepo.Channels["channel-1"]
+
+	svc.EvaluatePhoneQuality(context.Background(), channel, "FLAGGED", "", "")
+	assert.False(t, svc.EvaluatePhoneQuality(context.Background(), channel, "DOWNGRADE", "", ""))
+	assert.Len(t, producer.Events, 1)
+}
+
+func TestQualityGuardCheckOutbound(t *testing.T) {
+	svc, channelRepo, _ := newTestQualityGuard()
+	channel := channelRepo.Channels["channel-1"]
+	ctx := context.Background()
+
+	campaign := &entity.Message{ContentType: entity.ContentTypeTemplate, Metadata: map[string]string{"campaign_id": "camp-1"}}
+	utility := &entity.Message{ContentType: entity.ContentTypeTemplate, Metadata: map[string]string{"template_category": "UTILITY"}}
+	reply := &entity.Message{ContentType: entity.ContentTypeText, Metadata: map[string]string{}}
+
+	assert.NoError(t, svc.CheckOutbound(ctx, channel, campaign))
+
+	svc.EvaluatePhoneQuality(ctx, channel, "FLAGGED", "", "")
+
+	err := svc.CheckOutbound(ctx, channel, campaign)
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeForbidden, errors.GetAppError(err).Code)
+	assert.NoError(t, svc.CheckOutbound(ctx, channel, utility))
+	assert.NoError(t, svc.CheckOutbound(ctx, channel, reply))
+}
+
+func TestQualityGuardResumeMarketing(t *testing.T) {
+	svc, channelRepo, producer := newTestQualityGuard()
+	ctx := context.Background()
+
+	_, err := svc.ResumeMarketing(ctx, "tenant-1", "channel-1", "user-1")
+	assert.Equal(t, errors.ErrCodeConflict, errors.GetAppError(err).Code)
+
+	svc.EvaluatePhoneQuality(ctx, channelRepo.Channels["channel-1"], "FLAGGED", "", "")
+
+	_, err = svc.ResumeMarketing(ctx, "tenant-2", "channel-1", "user-1")
+	assert.Equal(t, errors.ErrCodeChannelNotFound, errors.GetAppError(err).Code)
+
+	status, err := svc.ResumeMarketing(ctx, "tenant-1", "channel-1", "user-1")
+	require.NoError(t, err)
+	assert.False(t, status.Paused)
+	assert.False(t, channelRepo.Channels["channel-1"].IsMarketingPaused())
+	assert.Equal(t, EventChannelMarketingResumed, producer.Events[len(producer.Events)-1].Type)
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Limits of the widget settings, so widgets stay light to load
+const (
+	maxWidgetQuickReplies    = 12
+	maxQuickReplyLabelLength = 40
+	maxQuickReplyMessageLen  = 1000
+	maxWidgetEmojiSets       = 10
+	maxWidgetEmojisPerSet    = 200
+	maxWidgetEmojiSetNameLen = 40
+	maxWidgetQuickReplyIDLen = 64
+)
+
+var widgetEmojiShortcodePattern = regexp.MustCompile(`^[a-z0-9_+-]{1,32}$`)
+
+// WidgetSettingsNotifier is told when a tenant changes its widget settings, so
+// connected widgets pick them up
+type WidgetSettingsNotifier interface {
+	NotifyWidgetSettingsChanged(ctx context.Context, settings *entity.WidgetSettings)
+}
+
+// SaveWidgetSettingsInput represents input for configuring a tenant's webchat widgets
+type SaveWidgetSettingsInput struct {
+	QuickReplies []entity.WidgetQuickReply `json:"quick_replies"`
+	EmojiSets    []entity.WidgetEmojiSet   `json:"emoji_sets"`
+}
+
+// WidgetSettingsService manages the quick replies and emoji sets tenants offer in
+// their webchat widgets and reports which quick replies visitors click
+type WidgetSettingsService struct {
+	repo     repository.WidgetSettingsRepository
+	notifier WidgetSettingsNotifier
+	now      func() time.Time
+}
+
+// NewWidgetSettingsService creates a new widget settings service
+func NewWidgetSettingsService(repo repository.WidgetSettingsRepository) *WidgetSettingsService {
+	return &WidgetSettingsService{
+		repo: repo,
+		now:  time.Now,
+	}
+}
+
+// SetNotifier sets the notifier told about widget settings changes
+func (s *WidgetSettingsService) SetNotifier(notifier WidgetSettingsNotifier) {
+	s.notifier = notifier
+}
+
+// Get returns the widget settings of a tenant, empty when it never configured them
+func (s *WidgetSettingsService) Get(ctx context.Context, tenantID string) (*entity.WidgetSettings, error) {
+	settings, err := s.repo.FindByTenant(ctx, tenantID)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, err
+		}
+		settings = &entity.WidgetSettings{TenantID: tenantID}
+	}
+	if settings.QuickReplies == nil {
+		settings.QuickReplies = []entity.WidgetQuickReply{}
+	}
+	if settings.EmojiSets == nil {
+		settings.EmojiSets = []entity.WidgetEmojiSet{}
+	}
+	return settings, nil
+}
+
+// Save replaces the quick replies and emoji sets of a tenant's widgets and pushes them
+// to the widgets already open. Quick replies without an ID get one, and those without
+// a message send their label.
+func (s *WidgetSettingsService) Save(ctx context.Context, tenantID string, input *SaveWidgetSettingsInput) (*entity.WidgetSettings, error) {
+	quickReplies, err := normalizeQuickReplies(input.QuickReplies)
+	if err != nil {
+		return nil, err
+	}
+	emojiSets, err := normalizeEmojiSets(input.EmojiSets)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &entity.WidgetSettings{
+		TenantID:     tenantID,
+		QuickReplies: quickReplies,
+		EmojiSets:    emojiSets,
+		UpdatedAt:    s.now(),
+	}
+	if err := s.repo.Save(ctx, settings); err != nil {
+		return nil, err
+	}
+
+	if s.notifier != nil {
+		s.notifier.NotifyWidgetSettingsChanged(ctx, settings)
+	}
+	return settings, nil
+}
+
+// RecordQuickReplyClick records a visitor clicking a quick reply
+func (s *WidgetSettingsService) RecordQuickReplyClick(ctx context.Context, click *entity.QuickReplyClick) error {
+	if click.ID == "" {
+		click.ID = uuid.New().String()
+	}
+	if click.ClickedAt.IsZero() {
+		click.ClickedAt = s.now()
+	}
+	if err := s.repo.CreateQuickReplyClick(ctx, click); err != nil {
+		logger.Warn("Failed to record quick reply click",
+			zap.String("quick_reply_id", click.QuickReplyID),
+			zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetQuickReplyStats returns how often each quick reply was clicked in a period, most
+// clicked first
+func (s *WidgetSettingsService) GetQuickReplyStats(ctx context.Context, tenantID string, startDate, endDate time.Time) ([]*entity.QuickReplyStats, error) {
+	return s.repo.QuickReplyStats(ctx, tenantID, startDate, endDate)
+}
+
+func normalizeQuickReplies(input []entity.WidgetQuickReply) ([]entity.WidgetQuickReply, error) {
+	if len(input) > maxWidgetQuickReplies {
+		return nil, errors.Validation("at most 12 quick replies are allowed")
+	}
+
+	quickReplies := make([]entity.WidgetQuickReply, 0, len(input))
+	seen := make(map[string]bool, len(input))
+	for _, reply := range input {
+		reply.ID = strings.TrimSpace(reply.ID)
+		reply.Label = strings.TrimSpace(reply.Label)
+		reply.Message = strings.TrimSpace(reply.Message)
+		reply.Emoji = strings.TrimSpace(reply.Emoji)
+
+		if reply.Label == "" {
+			return nil, errors.Validation("quick replies need a label")
+		}
+		if utf8.RuneCountInString(reply.Label) > maxQuickReplyLabelLength {
+			return nil, errors.Validation("quick reply labels must be at most 40 characters")
+		}
+		if reply.Message == "" {
+			reply.Message = reply.Label
+		}
+		if utf8.RuneCountInString(reply.Message) > maxQuickReplyMessageLen {
+			return nil, errors.Validation("quick reply messages must be at most 1000 characters")
+		}
+		if reply.ID == "" {
+			reply.ID = uuid.New().String()
+		}
+		if len(reply.ID) > maxWidgetQuickReplyIDLen {
+			return nil, errors.Validation("quick reply IDs must be at most 64 characters")
+		}
+		if seen[reply.ID] {
+			return nil, errors.Validation("quick reply IDs must be unique: " + reply.ID)
+		}
+		seen[reply.ID] = true
+		quickReplies = append(quickReplies, reply)
+	}
+	return quickReplies, nil
+}
+
+func normalizeEmojiSets(input []entity.WidgetEmojiSet) ([]entity.WidgetEmojiSet, error) {
+	if len(input) > maxWidgetEmojiSets {
+		return nil, errors.Validation("at most 10 emoji sets are allowed")
+	}
+
+	sets := make([]entity.WidgetEmojiSet, 0, len(input))
+	shortcodes := make(map[string]bool)
+	for _, set := range input {
+		set.Name = strings.TrimSpace(set.Name)
+		if set.Name == "" || utf8.RuneCountInString(set.Name) > maxWidgetEmojiSetNameLen {
+			return nil, errors.Validation("emoji sets need a name of at most 40 characters")
+		}
+		if len(set.Emojis) == 0 || len(set.Emojis) > maxWidgetEmojisPerSet {
+			return nil, errors.Validation("emoji sets must have between 1 and 200 emojis")
+		}
+
+		emojis := make([]entity.WidgetEmoji, 0, len(set.Emojis))
+		for _, emoji := range set.Emojis {
+			emoji.Shortcode = strings.Trim(strings.TrimSpace(emoji.Shortcode), ":")
+			emoji.Unicode = strings.TrimSpace(emoji.Unicode)
+			emoji.ImageURL = strings.TrimSpace(emoji.ImageURL)
+
+			if !widgetEmojiShortcodePattern.MatchString(emoji.Shortcode) {
+				return nil, errors.Validation("emoji shortcodes must be 1-32 lowercase letters, digits, _, + or -")
+			}
+			if shortcodes[emoji.Shortcode] {
+				return nil, errors.Validation("emoji shortcodes must be unique: " + emoji.Shortcode)
+			}
+			shortcodes[emoji.Shortcode] = true
+
+			if (emoji.Unicode == "") == (emoji.ImageURL == "") {
+				return nil, errors.Validation("emoji " + emoji.Shortcode + " needs either a unicode character or an image URL")
+			}
+			if emoji.ImageURL != "" && !isWebURL(emoji.ImageURL) {
+				return nil, errors.Validation("emoji " + emoji.Shortcode + " has an invalid image URL")
+			}
+			emojis = append(emojis, emoji)
+		}
+		set.Emojis = emojis
+		sets = append(sets, set)
+	}
+	return sets, nil
+}
+
+// isWebURL reports whether a value is an absolute http or https URL
+func isWebURL(value string) bool {
+	u, err := url.Parse(value)
+	return err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != ""
+}
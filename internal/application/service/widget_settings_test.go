@@ -0,0 +1,136 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockWidgetSettingsRepository struct {
+	settings map[string]*entity.WidgetSettings
+	clicks   []*entity.QuickReplyClick
+}
+
+func newMockWidgetSettingsRepository() *mockWidgetSettingsRepository {
+	return &mockWidgetSettingsRepository{settings: make(map[string]*entity.WidgetSettings)}
+}
+
+func (m *mockWidgetSettingsRepository) FindByTenant(ctx context.Context, tenantID string) (*entity.WidgetSettings, error) {
+	settings, ok := m.settings[tenantID]
+	if !ok {
+		return nil, errors.New(errors.ErrCodeNotFound, "widget settings not found")
+	}
+	return settings, nil
+}
+
+func (m *mockWidgetSettingsRepository) Save(ctx context.Context, settings *entity.WidgetSettings) error {
+	m.settings[settings.TenantID] = settings
+	return nil
+}
+
+func (m *mockWidgetSettingsRepository) CreateQuickReplyClick(ctx context.Context, click *entity.QuickReplyClick) error {
+	m.clicks = append(m.clicks, click)
+	return nil
+}
+
+func (m *mockWidgetSettingsRepository) QuickReplyStats(ctx context.Context, tenantID string, startDate, endDate time.Time) ([]*entity.QuickReplyStats, error) {
+	counts := make(map[string]*entity.QuickReplyStats)
+	var stats []*entity.QuickReplyStats
+	for _, click := range m.clicks {
+		if click.TenantID != tenantID || click.ClickedAt.Before(startDate) || click.ClickedAt.After(endDate) {
+			continue
+		}
+		s, ok := counts[click.QuickReplyID]
+		if !ok {
+			s = &entity.QuickReplyStats{QuickReplyID: click.QuickReplyID, Label: click.Label}
+			counts[click.QuickReplyID] = s
+			stats = append(stats, s)
+		}
+		s.Clicks++
+	}
+	return stats, nil
+}
+
+type fakeWidgetNotifier struct {
+	notified []*entity.WidgetSettings
+}
+
+func (f *fakeWidgetNotifier) NotifyWidgetSettingsChanged(ctx context.Context, settings *entity.WidgetSettings) {
+	f.notified = append(f.notified, settings)
+}
+
+func TestWidgetSettingsService_Save(t *testing.T) {
+	repo := newMockWidgetSettingsRepository()
+	notifier := &fakeWidgetNotifier{}
+	svc := NewWidgetSettingsService(repo)
+	svc.SetNotifier(notifier)
+	ctx := context.Background()
+
+	settings, err := svc.Get(ctx, "tenant-1")
+	require.NoError(t, err)
+	assert.Empty(t, settings.QuickReplies, "tenants start without quick replies")
+
+	settings, err = svc.Save(ctx, "tenant-1", &SaveWidgetSettingsInput{
+		QuickReplies: []entity.WidgetQuickReply{
+			{ID: "pricing", Label: " Pricing ", Message: "How much does it cost?"},
+			{Label: "Talk to a human", Emoji: "🙋"},
+		},
+		EmojiSets: []entity.WidgetEmojiSet{{Name: "Brand", Emojis: []entity.WidgetEmoji{
+			{Shortcode: ":rocket:", Unicode: "🚀"},
+			{Shortcode: "logo", ImageURL: "https://cdn.example.com/logo.png"},
+		}}},
+	})
+	require.NoError(t, err)
+	require.Len(t, settings.QuickReplies, 2)
+	assert.Equal(t, "Pricing", settings.QuickReplies[0].Label)
+	assert.NotEmpty(t, settings.QuickReplies[1].ID)
+	assert.Equal(t, "Talk to a human", settings.QuickReplies[1].Message, "the label is sent when there is no message")
+	assert.Equal(t, "rocket", settings.EmojiSets[0].Emojis[0].Shortcode)
+	require.Len(t, notifier.notified, 1, "open widgets are updated")
+
+	stored, err := svc.Get(ctx, "tenant-1")
+	require.NoError(t, err)
+	assert.Equal(t, settings, stored)
+}
+
+func TestWidgetSettingsService_SaveValidation(t *testing.T) {
+	svc := NewWidgetSettingsService(newMockWidgetSettingsRepository())
+	ctx := context.Background()
+
+	cases := map[string]*SaveWidgetSettingsInput{
+		"missing label": {QuickReplies: []entity.WidgetQuickReply{{Message: "hi"}}},
+		"duplicate id":  {QuickReplies: []entity.WidgetQuickReply{{ID: "a", Label: "A"}, {ID: "a", Label: "B"}}},
+		"bad shortcode": {EmojiSets: []entity.WidgetEmojiSet{{Name: "Set", Emojis: []entity.WidgetEmoji{{Shortcode: "Not Valid", Unicode: "x"}}}}},
+		"no glyph":      {EmojiSets: []entity.WidgetEmojiSet{{Name: "Set", Emojis: []entity.WidgetEmoji{{Shortcode: "empty"}}}}},
+		"bad image url": {EmojiSets: []entity.WidgetEmojiSet{{Name: "Set", Emojis: []entity.WidgetEmoji{{Shortcode: "img", ImageURL: "javascript:alert(1)"}}}}},
+		"empty set":     {EmojiSets: []entity.WidgetEmojiSet{{Name: "Set"}}},
+	}
+	for name, input := range cases {
+		_, err := svc.Save(ctx, "tenant-1", input)
+		assert.True(t, errors.IsValidation(err), name)
+	}
+}
+
+func TestWidgetSettingsService_QuickReplyStats(t *testing.T) {
+	repo := newMockWidgetSettingsRepository()
+	svc := NewWidgetSettingsService(repo)
+	now := time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for _, id := range []string{"pricing", "hours", "pricing"} {
+		require.NoError(t, svc.RecordQuickReplyClick(ctx, &entity.QuickReplyClick{TenantID: "tenant-1", ChannelID: "channel-1", QuickReplyID: id, Label: id}))
+	}
+	assert.NotEmpty(t, repo.clicks[0].ID)
+	assert.Equal(t, now, repo.clicks[0].ClickedAt)
+
+	stats, err := svc.GetQuickReplyStats(ctx, "tenant-1", now.Add(-time.Hour), now.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	assert.Equal(t, int64(2), stats[0].Clicks)
+}
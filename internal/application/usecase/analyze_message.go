@@ -95,6 +95,7 @@ func (uc *AnalyzeMessageUseCase) Execute(ctx context.Context, input *AnalyzeMess
 	}
 
 	// Analyze message
+	ctx = service.WithAIUsage(ctx, bot.TenantID, bot.ID, entity.AIFeatureIntent)
 	analysis, err := uc.intentService.AnalyzeMessage(ctx, input.Content, bot.Provider, bot.Config.EnabledIntents)
 	if err != nil {
 		// Log error but continue with default values
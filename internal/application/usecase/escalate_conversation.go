@@ -3,6 +3,7 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"maps"
 	"strings"
 	"time"
 
@@ -46,6 +47,38 @@ type EscalateConversationUseCase struct {
 	contextRepo      repository.ConversationContextRepository
 	aiFactory        *service.AIProviderFactory
 	producer         nats.Publisher
+	availability     AgentAvailability
+	router           ConversationRouter
+	escalations      EscalationRecorder
+	assignments      AssignmentListener
+	maintenance      MaintenanceWindows
+}
+
+// AgentAvailability reports whether an agent is online and able to take conversations
+type AgentAvailability interface {
+	IsAgentAvailable(tenantID, userID string) bool
+}
+
+// ConversationRouter picks the agent for an escalated conversation by routing rules
+type ConversationRouter interface {
+	Route(ctx context.Context, conversation *entity.Conversation, candidates []*entity.User) (*service.RoutingDecision, error)
+}
+
+// EscalationRecorder counts escalations, such as against the bot version of an experiment
+type EscalationRecorder interface {
+	RecordEscalation(ctx context.Context, conversationID string)
+}
+
+// AssignmentListener is told when a conversation was assigned to an agent, such as
+// to suggest first replies
+type AssignmentListener interface {
+	HandleAssigned(ctx context.Context, conversation *entity.Conversation)
+}
+
+// MaintenanceWindows reports the maintenance window a channel is under, during which
+// conversations wait in the queue instead of being routed
+type MaintenanceWindows interface {
+	ActiveWindow(ctx context.Context, channelID string) *entity.ChannelMaintenanceWindow
 }
 
 // NewEscalateConversationUseCase creates a new escalate conversation use case
@@ -73,6 +106,31 @@ func NewEscalateConversationUseCase(
 	}
 }
 
+// SetAgentAvailability makes auto-assignment skip agents that are offline, away or busy
+func (uc *EscalateConversationUseCase) SetAgentAvailability(availability AgentAvailability) {
+	uc.availability = availability
+}
+
+// SetRouter makes auto-assignment follow the tenant's routing rules
+func (uc *EscalateConversationUseCase) SetRouter(router ConversationRouter) {
+	uc.router = router
+}
+
+// SetEscalationRecorder reports every escalation to the recorder
+func (uc *EscalateConversationUseCase) SetEscalationRecorder(escalations EscalationRecorder) {
+	uc.escalations = escalations
+}
+
+// SetAssignmentListener tells the listener about conversations auto-assigned to an agent
+func (uc *EscalateConversationUseCase) SetAssignmentListener(assignments AssignmentListener) {
+	uc.assignments = assignments
+}
+
+// SetMaintenanceWindows queues escalations on channels under maintenance
+func (uc *EscalateConversationUseCase) SetMaintenanceWindows(maintenance MaintenanceWindows) {
+	uc.maintenance = maintenance
+}
+
 // Execute escalates a conversation from bot to human agent
 func (uc *EscalateConversationUseCase) Execute(ctx context.Context, input *EscalateConversationInput) (*EscalateConversationOutput, error) {
 	// Get conversation
@@ -108,6 +166,7 @@ func (uc *EscalateConversationUseCase) Execute(ctx context.Context, input *Escal
 	if conversation.Metadata == nil {
 		conversation.Metadata = make(map[string]string)
 	}
+	metadata := maps.Clone(conversation.Metadata)
 	conversation.Metadata["escalation_reason"] = input.Reason
 	conversation.Metadata["escalated_by"] = input.RequestedBy
 	conversation.Metadata["escalated_at"] = time.Now().Format(time.RFC3339)
@@ -127,9 +186,19 @@ func (uc *EscalateConversationUseCase) Execute(ctx context.Context, input *Escal
 	if err := uc.conversationRepo.Update(ctx, conversation); err != nil {
 		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to update conversation")
 	}
+	if err := uc.conversationRepo.SetMetadata(ctx, conversation.ID, conversation.ChangedMetadata(metadata)); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to update conversation")
+	}
+
+	if assignedUserID != "" && uc.assignments != nil {
+		uc.assignments.HandleAssigned(ctx, conversation)
+	}
 
 	// Publish escalation event
 	uc.publishEscalationEvent(ctx, input, conversation, assignedUserID)
+	if uc.escalations != nil {
+		uc.escalations.RecordEscalation(ctx, conversation.ID)
+	}
 
 	output := &EscalateConversationOutput{
 		ConversationID: conversation.ID,
@@ -152,8 +221,32 @@ func (uc *EscalateConversationUseCase) Execute(ctx context.Context, input *Escal
 
 // tryAutoAssign attempts to auto-assign the conversation to an available agent
 func (uc *EscalateConversationUseCase) tryAutoAssign(ctx context.Context, conversation *entity.Conversation) (string, int) {
+	// Nobody is routed conversations of a channel under maintenance
+	if uc.maintenance != nil {
+		if window := uc.maintenance.ActiveWindow(ctx, conversation.ChannelID); window != nil {
+			conversation.Metadata[service.ConversationMetaMaintenanceWindow] = window.ID
+			return "", uc.calculateQueuePosition(ctx, conversation)
+		}
+	}
+
 	// Find available agents for this channel
 	agents, err := uc.userRepo.FindAvailableAgents(ctx, conversation.TenantID, conversation.ChannelID)
+	if err == nil && uc.availability != nil {
+		agents = uc.filterOnlineAgents(conversation.TenantID, agents)
+	}
+
+	// Routing rules take precedence over the least-loaded default
+	if err == nil && uc.router != nil {
+		decision, routeErr := uc.router.Route(ctx, conversation, agents)
+		if routeErr == nil && decision != nil {
+			conversation.Metadata["routing_rule"] = decision.RuleID
+			if decision.AgentID != "" {
+				return decision.AgentID, 0
+			}
+			return "", uc.calculateQueuePosition(ctx, conversation)
+		}
+	}
+
 	if err != nil || len(agents) == 0 {
 		// No available agents, calculate queue position
 		queuePosition := uc.calculateQueuePosition(ctx, conversation)
@@ -183,6 +276,17 @@ func (uc *EscalateConversationUseCase) tryAutoAssign(ctx context.Context, conver
 	return "", queuePosition
 }
 
+// filterOnlineAgents keeps the agents whose presence says they can take conversations
+func (uc *EscalateConversationUseCase) filterOnlineAgents(tenantID string, agents []*entity.User) []*entity.User {
+	online := make([]*entity.User, 0, len(agents))
+	for _, agent := range agents {
+		if uc.availability.IsAgentAvailable(tenantID, agent.ID) {
+			online = append(online, agent)
+		}
+	}
+	return online
+}
+
 // calculateQueuePosition calculates the queue position for a conversation
 func (uc *EscalateConversationUseCase) calculateQueuePosition(ctx context.Context, conversation *entity.Conversation) int {
 	// Count waiting conversations with same or higher priority
@@ -448,6 +552,7 @@ func (uc *EscalateConversationUseCase) generateSummary(ctx context.Context, escC
 	if provider == nil {
 		return "", errors.New(errors.ErrCodeInternal, "no AI provider available")
 	}
+	ctx = service.WithAIUsage(ctx, escCtx.TenantID, "", entity.AIFeatureSummary)
 
 	// Build conversation text
 	var conversationText strings.Builder
@@ -6,9 +6,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/msgfy/linktor/internal/application/service"
 	"github.com/msgfy/linktor/internal/domain/entity"
 	"github.com/msgfy/linktor/internal/domain/repository"
 	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
 	"github.com/msgfy/linktor/pkg/testutil"
 )
 
@@ -48,8 +50,16 @@ func (m *mockBotRepository) FindByTenant(_ context.Context, _ string, _ *reposit
 	return nil, 0, m.ReturnError
 }
 
-func (m *mockBotRepository) FindByChannel(_ context.Context, _ string) (*entity.Bot, error) {
-	return nil, m.ReturnError
+func (m *mockBotRepository) FindByChannel(_ context.Context, channelID string) (*entity.Bot, error) {
+	if m.ReturnError != nil {
+		return nil, m.ReturnError
+	}
+	for _, bot := range m.Bots {
+		if bot.HasChannel(channelID) {
+			return bot, nil
+		}
+	}
+	return nil, errors.NotFound("bot")
 }
 
 func (m *mockBotRepository) FindActiveByTenant(_ context.Context, _ string) ([]*entity.Bot, error) {
@@ -396,6 +406,71 @@ func TestEscalateConversation_NoAvailableAgents_Queued(t *testing.T) {
 	}
 }
 
+type fakeAgentAvailability map[string]bool
+
+func (f fakeAgentAvailability) IsAgentAvailable(tenantID, userID string) bool {
+	return f[userID]
+}
+
+func TestEscalateConversation_AutoAssign_SkipsUnavailableAgents(t *testing.T) {
+	d := setupEscalateTest()
+	ctx := context.Background()
+
+	d.conversationRepo.Conversations["conv-1"] = makeConversation("conv-1", "tenant-1", "channel-1")
+	d.userRepo.Users["agent-1"] = makeAgent("agent-1", "tenant-1")
+	d.userRepo.Users["agent-2"] = makeAgent("agent-2", "tenant-1")
+
+	// agent-2 has no workload but is away; agent-1 is the only one online
+	assigned1 := "agent-1"
+	d.conversationRepo.Conversations["active-1"] = &entity.Conversation{
+		ID: "active-1", TenantID: "tenant-1", ChannelID: "channel-1",
+		Status: entity.ConversationStatusOpen, AssignedUserID: &assigned1,
+	}
+	d.uc.SetAgentAvailability(fakeAgentAvailability{"agent-1": true})
+
+	output, err := d.uc.Execute(ctx, &EscalateConversationInput{
+		ConversationID: "conv-1",
+		TenantID:       "tenant-1",
+		ChannelID:      "channel-1",
+		ContactID:      "contact-1",
+		Reason:         "help",
+		RequestedBy:    "user",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if output.AssignedUserID != "agent-1" {
+		t.Errorf("expected assignment to the online agent-1, got '%s'", output.AssignedUserID)
+	}
+}
+
+func TestEscalateConversation_AutoAssign_NobodyOnline_Queued(t *testing.T) {
+	d := setupEscalateTest()
+	ctx := context.Background()
+
+	d.conversationRepo.Conversations["conv-1"] = makeConversation("conv-1", "tenant-1", "channel-1")
+	d.userRepo.Users["agent-1"] = makeAgent("agent-1", "tenant-1")
+	d.uc.SetAgentAvailability(fakeAgentAvailability{})
+
+	output, err := d.uc.Execute(ctx, &EscalateConversationInput{
+		ConversationID: "conv-1",
+		TenantID:       "tenant-1",
+		ChannelID:      "channel-1",
+		ContactID:      "contact-1",
+		Reason:         "help",
+		RequestedBy:    "user",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if output.Status != "queued" {
+		t.Errorf("expected status 'queued' when no agent is online, got '%s'", output.Status)
+	}
+	if output.AssignedUserID != "" {
+		t.Errorf("expected no assignment, got '%s'", output.AssignedUserID)
+	}
+}
+
 func TestEscalateConversation_PriorityMapping(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -737,3 +812,101 @@ func TestEscalate_MapPriority(t *testing.T) {
 		})
 	}
 }
+
+type fakeConversationRouter struct {
+	decision *service.RoutingDecision
+}
+
+func (f *fakeConversationRouter) Route(ctx context.Context, conversation *entity.Conversation, candidates []*entity.User) (*service.RoutingDecision, error) {
+	return f.decision, nil
+}
+
+func TestEscalateConversation_AutoAssign_FollowsRoutingRule(t *testing.T) {
+	d := setupEscalateTest()
+	ctx := context.Background()
+
+	d.conversationRepo.Conversations["conv-1"] = makeConversation("conv-1", "tenant-1", "channel-1")
+	d.userRepo.Users["agent-1"] = makeAgent("agent-1", "tenant-1")
+	d.userRepo.Users["agent-2"] = makeAgent("agent-2", "tenant-1")
+	d.uc.SetRouter(&fakeConversationRouter{decision: &service.RoutingDecision{RuleID: "rule-1", AgentID: "agent-2"}})
+
+	output, err := d.uc.Execute(ctx, &EscalateConversationInput{
+		ConversationID: "conv-1",
+		TenantID:       "tenant-1",
+		ChannelID:      "channel-1",
+		ContactID:      "contact-1",
+		Reason:         "help",
+		RequestedBy:    "user",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if output.AssignedUserID != "agent-2" {
+		t.Errorf("expected assignment to the routed agent-2, got '%s'", output.AssignedUserID)
+	}
+	if got := d.conversationRepo.Conversations["conv-1"].Metadata["routing_rule"]; got != "rule-1" {
+		t.Errorf("expected routing_rule metadata 'rule-1', got '%s'", got)
+	}
+}
+
+func TestEscalateConversation_AutoAssign_RoutingRuleWithoutAgent_Queued(t *testing.T) {
+	d := setupEscalateTest()
+	ctx := context.Background()
+
+	d.conversationRepo.Conversations["conv-1"] = makeConversation("conv-1", "tenant-1", "channel-1")
+	d.userRepo.Users["agent-1"] = makeAgent("agent-1", "tenant-1")
+	d.uc.SetRouter(&fakeConversationRouter{decision: &service.RoutingDecision{RuleID: "rule-1"}})
+
+	output, err := d.uc.Execute(ctx, &EscalateConversationInput{
+		ConversationID: "conv-1",
+		TenantID:       "tenant-1",
+		ChannelID:      "channel-1",
+		ContactID:      "contact-1",
+		Reason:         "help",
+		RequestedBy:    "user",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if output.Status != "queued" {
+		t.Errorf("expected status 'queued' when the matching rule has no eligible agent, got '%s'", output.Status)
+	}
+}
+
+type fakeMaintenanceWindows struct {
+	window *entity.ChannelMaintenanceWindow
+}
+
+func (f *fakeMaintenanceWindows) ActiveWindow(_ context.Context, channelID string) *entity.ChannelMaintenanceWindow {
+	if f.window != nil && f.window.ChannelID == channelID {
+		return f.window
+	}
+	return nil
+}
+
+func TestEscalateConversation_ChannelMaintenance_Queued(t *testing.T) {
+	d := setupEscalateTest()
+	ctx := context.Background()
+
+	d.conversationRepo.Conversations["conv-1"] = makeConversation("conv-1", "tenant-1", "channel-1")
+	d.userRepo.Users["agent-1"] = makeAgent("agent-1", "tenant-1")
+	d.uc.SetMaintenanceWindows(&fakeMaintenanceWindows{window: &entity.ChannelMaintenanceWindow{ID: "window-1", ChannelID: "channel-1"}})
+
+	output, err := d.uc.Execute(ctx, &EscalateConversationInput{
+		ConversationID: "conv-1",
+		TenantID:       "tenant-1",
+		ChannelID:      "channel-1",
+		ContactID:      "contact-1",
+		Reason:         "help",
+		RequestedBy:    "user",
+	})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if output.Status != "queued" {
+		t.Errorf("expected status 'queued' during channel maintenance, got '%s'", output.Status)
+	}
+	if got := d.conversationRepo.Conversations["conv-1"].Metadata[service.ConversationMetaMaintenanceWindow]; got != "window-1" {
+		t.Errorf("expected maintenance_window metadata 'window-1', got '%s'", got)
+	}
+}
@@ -34,11 +34,20 @@ type GenerateAIResponseOutput struct {
 	QuickReplies   []entity.QuickReply  `json:"quick_replies,omitempty"` // Interactive buttons
 	FlowID         string               `json:"flow_id,omitempty"`       // Active flow if any
 	FlowEnded      bool                 `json:"flow_ended,omitempty"`    // True if flow just ended
+
+	// Handover asks to send the response, if any, and escalate the conversation to an
+	// agent right away, as external bots do when they fail or hand the conversation off
+	Handover bool `json:"handover,omitempty"`
+
+	// Knowledge used by the answer, set when the bot asks customers to rate answers
+	KnowledgeBaseID  string                       `json:"knowledge_base_id,omitempty"`
+	KnowledgeItemIDs []string                     `json:"knowledge_item_ids,omitempty"`
+	AnswerFeedback   *entity.AnswerFeedbackConfig `json:"answer_feedback,omitempty"`
 }
 
 // KnowledgeSearchService interface for knowledge base search (optional)
 type KnowledgeSearchService interface {
-	Search(ctx context.Context, knowledgeBaseID, query string, limit int) ([]entity.SearchResult, error)
+	Search(ctx context.Context, tenantID, knowledgeBaseID, query string, limit int) ([]entity.SearchResult, error)
 }
 
 // GenerateAIResponseUseCase handles AI response generation
@@ -49,8 +58,16 @@ type GenerateAIResponseUseCase struct {
 	contextService   *service.ConversationContextService
 	knowledgeService KnowledgeSearchService
 	producer         nats.Publisher
+	tracer           service.AutomationTracer
+	usage            service.UsageRecorder
+	tools            *service.BotToolService
+	experiments      *service.BotExperimentService
+	externalBots     *service.ExternalBotService
 }
 
+// maxToolRounds limits how many times the AI can call tools before it has to answer
+const maxToolRounds = 3
+
 // NewGenerateAIResponseUseCase creates a new generate AI response use case
 func NewGenerateAIResponseUseCase(
 	aiFactory *service.AIProviderFactory,
@@ -70,6 +87,35 @@ func NewGenerateAIResponseUseCase(
 	}
 }
 
+// SetTracer records a trace of the confidence, model and escalation decision of
+// every generated response
+func (uc *GenerateAIResponseUseCase) SetTracer(tracer service.AutomationTracer) {
+	uc.tracer = tracer
+}
+
+// SetUsageRecorder meters every completion against the tenant's AI request quota
+func (uc *GenerateAIResponseUseCase) SetUsageRecorder(usage service.UsageRecorder) {
+	uc.usage = usage
+}
+
+// SetToolService lets the AI call the HTTP tools registered for the bot while
+// answering, on providers that support tool calling
+func (uc *GenerateAIResponseUseCase) SetToolService(tools *service.BotToolService) {
+	uc.tools = tools
+}
+
+// SetExperimentService answers conversations with the bot version they are assigned
+// to when the bot runs an experiment, and counts escalations against it
+func (uc *GenerateAIResponseUseCase) SetExperimentService(experiments *service.BotExperimentService) {
+	uc.experiments = experiments
+}
+
+// SetExternalBotService answers the conversations of external bots by forwarding their
+// messages to the bot's endpoint
+func (uc *GenerateAIResponseUseCase) SetExternalBotService(externalBots *service.ExternalBotService) {
+	uc.externalBots = externalBots
+}
+
 // Execute generates an AI response for a message
 func (uc *GenerateAIResponseUseCase) Execute(ctx context.Context, input *GenerateAIResponseInput) (*GenerateAIResponseOutput, error) {
 	output := &GenerateAIResponseOutput{}
@@ -87,20 +133,34 @@ func (uc *GenerateAIResponseUseCase) Execute(ctx context.Context, input *Generat
 	if !bot.IsActive() {
 		return nil, errors.New(errors.ErrCodeBadRequest, "bot is not active")
 	}
+	if uc.experiments != nil {
+		bot = uc.experiments.Assign(ctx, bot, input.ConversationID)
+	}
+	if bot.IsExternal() {
+		return uc.executeExternal(ctx, input, bot), nil
+	}
 
 	// Get AI provider
 	provider, err := uc.aiFactory.Get(bot.Provider)
 	if err != nil {
 		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get AI provider")
 	}
+	ctx = service.WithAIUsage(ctx, bot.TenantID, bot.ID, entity.AIFeatureResponse)
 
 	// Build system prompt with knowledge base context
 	systemPrompt := bot.Config.SystemPrompt
+	var usedItemIDs []string
 	if bot.Config.KnowledgeBaseID != nil && uc.knowledgeService != nil {
 		// Search knowledge base for relevant context
-		results, err := uc.knowledgeService.Search(ctx, *bot.Config.KnowledgeBaseID, input.Content, 3)
+		results, err := uc.knowledgeService.Search(ctx, bot.TenantID, *bot.Config.KnowledgeBaseID, input.Content, 3)
 		if err == nil && len(results) > 0 {
 			systemPrompt = uc.buildPromptWithKnowledge(systemPrompt, results)
+			usedItemIDs = knowledgeItemIDs(results)
+			if feedback := bot.Config.AnswerFeedback; feedback != nil && feedback.Enabled {
+				output.KnowledgeBaseID = *bot.Config.KnowledgeBaseID
+				output.KnowledgeItemIDs = usedItemIDs
+				output.AnswerFeedback = feedback
+			}
 		}
 	}
 
@@ -138,16 +198,28 @@ func (uc *GenerateAIResponseUseCase) Execute(ctx context.Context, input *Generat
 		MaxTokens:   maxTokens,
 		Temperature: temperature,
 	}
+	var tools []*entity.BotTool
+	if uc.tools != nil && service.SupportsTools(provider) {
+		tools, err = uc.tools.EnabledTools(ctx, bot.ID)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to load bot tools")
+		}
+		if len(tools) > 0 {
+			completionReq.Tools = service.BotToolDefinitions(tools)
+		}
+	}
 
 	// Generate completion
 	startTime := time.Now()
-	completion, err := provider.Complete(ctx, completionReq)
+	completion, tokensUsed, err := uc.complete(ctx, provider, completionReq, tools, input, bot)
 	if err != nil {
 		// Use fallback message if AI fails
 		output.Response = bot.Config.FallbackMessage
 		output.Confidence = 0.0
 		output.ShouldEscalate = true
 		output.EscalateReason = "AI generation failed: " + err.Error()
+		uc.recordEscalation(ctx, input, output)
+		uc.recordTrace(ctx, input, output, bot, usedItemIDs)
 		return output, nil
 	}
 
@@ -155,7 +227,7 @@ func (uc *GenerateAIResponseUseCase) Execute(ctx context.Context, input *Generat
 
 	// Build output
 	output.Response = completion.Content
-	output.TokensUsed = completion.TokensUsed
+	output.TokensUsed = tokensUsed
 	output.LatencyMs = latencyMs
 	output.Model = completion.Model
 
@@ -174,16 +246,172 @@ func (uc *GenerateAIResponseUseCase) Execute(ctx context.Context, input *Generat
 	}
 
 	// Save AI response for audit
-	if err := uc.saveAIResponse(ctx, input, output, bot, messages); err != nil {
+	if err := uc.saveAIResponse(ctx, input, output, bot, completionReq.Messages); err != nil {
 		// Log but continue
 	}
 
+	uc.recordEscalation(ctx, input, output)
+	uc.recordTrace(ctx, input, output, bot, usedItemIDs)
+
 	// Publish response event
 	uc.publishResponseEvent(ctx, input, output, bot)
 
 	return output, nil
 }
 
+// executeExternal relays a message to the external bot answering for the bot. When
+// the bot fails or does not answer in time, the fallback message is sent and the
+// conversation handed to an agent.
+func (uc *GenerateAIResponseUseCase) executeExternal(ctx context.Context, input *GenerateAIResponseInput, bot *entity.Bot) *GenerateAIResponseOutput {
+	output := &GenerateAIResponseOutput{}
+	if bot.Config.External != nil {
+		output.Model = string(bot.Config.External.Framework)
+	}
+
+	startTime := time.Now()
+	var reply *service.ExternalBotReply
+	var err error
+	if uc.externalBots == nil {
+		err = errors.New(errors.ErrCodeInternal, "external bots are not enabled")
+	} else {
+		reply, err = uc.externalBots.Reply(ctx, bot, &service.ExternalBotRequest{
+			TenantID:       input.TenantID,
+			BotID:          bot.ID,
+			ConversationID: input.ConversationID,
+			ChannelID:      input.ChannelID,
+			MessageID:      input.MessageID,
+			Text:           input.Content,
+		})
+	}
+	output.LatencyMs = time.Since(startTime).Milliseconds()
+
+	if err != nil {
+		output.Response = bot.Config.FallbackMessage
+		output.ShouldEscalate = true
+		output.Handover = true
+		output.EscalateReason = "External bot failed: " + err.Error()
+		uc.recordEscalation(ctx, input, output)
+		uc.recordTrace(ctx, input, output, bot, nil)
+		return output
+	}
+
+	output.Response = reply.Text()
+	output.QuickReplies = reply.QuickReplies
+	output.Confidence = 1
+	if reply.Escalate {
+		output.ShouldEscalate = true
+		output.Handover = true
+		output.EscalateReason = reply.Reason
+		if output.EscalateReason == "" {
+			output.EscalateReason = "External bot handed the conversation off"
+		}
+	}
+
+	if output.Response != "" {
+		// Keep the context complete for agents and AI features on the conversation
+		_ = uc.contextService.AddAssistantMessage(ctx, input.ConversationID, output.Response, "")
+	}
+	uc.recordEscalation(ctx, input, output)
+	uc.recordTrace(ctx, input, output, bot, nil)
+	uc.publishResponseEvent(ctx, input, output, bot)
+	return output
+}
+
+// complete generates the completion, calling the tools the AI asks for and giving it
+// their results until it answers. The tool calls and results are appended to the
+// request messages; the returned token count covers every round.
+func (uc *GenerateAIResponseUseCase) complete(
+	ctx context.Context,
+	provider service.AIProvider,
+	req *service.CompletionRequest,
+	tools []*entity.BotTool,
+	input *GenerateAIResponseInput,
+	bot *entity.Bot,
+) (*service.CompletionResponse, int, error) {
+	toolsByName := make(map[string]*entity.BotTool, len(tools))
+	for _, tool := range tools {
+		toolsByName[tool.Name] = tool
+	}
+	callCtx := &service.ToolCallContext{ConversationID: input.ConversationID, MessageID: input.MessageID}
+
+	tokensUsed := 0
+	for round := 0; ; round++ {
+		completion, err := provider.Complete(ctx, req)
+		if err != nil {
+			return nil, tokensUsed, err
+		}
+		tokensUsed += completion.TokensUsed
+		if uc.usage != nil {
+			uc.usage.RecordUsage(bot.TenantID, entity.QuotaMetricAIRequests, "", 1)
+		}
+		if len(completion.ToolCalls) == 0 || len(tools) == 0 || round == maxToolRounds {
+			return completion, tokensUsed, nil
+		}
+
+		req.Messages = append(req.Messages, service.Message{
+			Role:      "assistant",
+			Content:   completion.Content,
+			ToolCalls: completion.ToolCalls,
+		})
+		for _, call := range completion.ToolCalls {
+			var result *entity.ToolResult
+			if tool, ok := toolsByName[call.Name]; ok {
+				result = uc.tools.Execute(ctx, tool, call, callCtx)
+			} else {
+				result = &entity.ToolResult{ToolCallID: call.ID, Content: "Unknown tool " + call.Name, IsError: true}
+			}
+			req.Messages = append(req.Messages, service.Message{
+				Role:       "tool",
+				Content:    result.Content,
+				ToolCallID: call.ID,
+			})
+		}
+		if round == maxToolRounds-1 {
+			// Last round: the AI has to answer with what it has
+			req.ToolChoice = "none"
+		}
+	}
+}
+
+// recordEscalation counts a response handed to an agent against the bot version of a
+// running experiment
+func (uc *GenerateAIResponseUseCase) recordEscalation(ctx context.Context, input *GenerateAIResponseInput, output *GenerateAIResponseOutput) {
+	if uc.experiments != nil && output.ShouldEscalate {
+		uc.experiments.RecordEscalation(ctx, input.ConversationID)
+	}
+}
+
+// recordTrace stores the decision taken on the message with the conversation's intent,
+// sentiment and collected variables at that point
+func (uc *GenerateAIResponseUseCase) recordTrace(ctx context.Context, input *GenerateAIResponseInput, output *GenerateAIResponseOutput, bot *entity.Bot, itemIDs []string) {
+	if uc.tracer == nil {
+		return
+	}
+
+	confidence := output.Confidence
+	trace := &entity.AutomationTrace{
+		TenantID:         input.TenantID,
+		ConversationID:   input.ConversationID,
+		MessageID:        input.MessageID,
+		Source:           entity.AutomationTraceSourceAI,
+		BotID:            bot.ID,
+		Confidence:       &confidence,
+		Model:            output.Model,
+		KnowledgeItemIDs: itemIDs,
+		Decision:         entity.AutomationDecisionRespond,
+	}
+	if output.ShouldEscalate {
+		trace.Decision = entity.AutomationDecisionEscalate
+		trace.EscalateReason = output.EscalateReason
+	}
+	if convContext, err := uc.contextService.Get(ctx, input.ConversationID); err == nil {
+		trace.ApplyContext(convContext)
+	}
+
+	// Traces are diagnostics and never fail the response
+	_ = uc.tracer.Record(ctx, trace)
+}
+
 // buildPromptWithKnowledge enhances the system prompt with knowledge base context
 func (uc *GenerateAIResponseUseCase) buildPromptWithKnowledge(basePrompt string, results []entity.SearchResult) string {
 	if len(results) == 0 {
@@ -206,6 +434,20 @@ func (uc *GenerateAIResponseUseCase) buildPromptWithKnowledge(basePrompt string,
 	return basePrompt + knowledgeContext
 }
 
+// knowledgeItemIDs returns the IDs of the items buildPromptWithKnowledge puts in the prompt
+func knowledgeItemIDs(results []entity.SearchResult) []string {
+	var ids []string
+	for _, result := range results {
+		if result.Item != nil {
+			ids = append(ids, result.Item.ID)
+			if len(ids) == 3 {
+				break
+			}
+		}
+	}
+	return ids
+}
+
 // calculateConfidence calculates a confidence score for the response
 func (uc *GenerateAIResponseUseCase) calculateConfidence(completion *service.CompletionResponse) float64 {
 	confidence := 0.8 // Base confidence
@@ -1,11 +1,17 @@
 package usecase
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/msgfy/linktor/internal/application/service"
 	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/testutil"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGenerateAIResponseUseCase_CalculateConfidence(t *testing.T) {
@@ -70,3 +76,137 @@ func TestGenerateAIResponseOutput_Defaults(t *testing.T) {
 	assert.Equal(t, float64(0), output.Confidence)
 	assert.False(t, output.ShouldEscalate)
 }
+
+type fakeToolCallingProvider struct {
+	service.AIProvider
+	responses []*service.CompletionResponse
+	requests  []service.CompletionRequest
+}
+
+func (p *fakeToolCallingProvider) SupportsTools() bool { return true }
+
+func (p *fakeToolCallingProvider) Complete(_ context.Context, req *service.CompletionRequest) (*service.CompletionResponse, error) {
+	p.requests = append(p.requests, *req)
+	resp := p.responses[0]
+	if len(p.responses) > 1 {
+		p.responses = p.responses[1:]
+	}
+	return resp, nil
+}
+
+type fakeBotToolRepository struct {
+	repository.BotToolRepository
+	executions []*entity.ToolExecution
+}
+
+func (r *fakeBotToolRepository) CreateExecution(_ context.Context, execution *entity.ToolExecution) error {
+	r.executions = append(r.executions, execution)
+	return nil
+}
+
+func TestGenerateAIResponseUseCase_CompleteWithTools(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"shipped"}`))
+	}))
+	defer server.Close()
+
+	toolRepo := &fakeBotToolRepository{}
+	toolService := service.NewBotToolService(toolRepo, nil)
+	toolService.SetHTTPClient(server.Client())
+	uc := &GenerateAIResponseUseCase{tools: toolService}
+	bot := &entity.Bot{ID: "bot-1", TenantID: "tenant-1"}
+	tools := []*entity.BotTool{{ID: "tool-1", BotID: "bot-1", Name: "order_status", Method: http.MethodPost, URL: server.URL}}
+	input := &GenerateAIResponseInput{ConversationID: "conv-1", MessageID: "msg-1"}
+	toolCall := &entity.ToolCall{ID: "call-1", Name: "order_status", Arguments: map[string]interface{}{"order_id": "A-1"}}
+
+	t.Run("feeds tool results back until the AI answers", func(t *testing.T) {
+		toolRepo.executions = nil
+		provider := &fakeToolCallingProvider{responses: []*service.CompletionResponse{
+			{ToolCalls: []*entity.ToolCall{toolCall, {ID: "call-2", Name: "unknown"}}, TokensUsed: 10},
+			{Content: "Your order has shipped", TokensUsed: 5},
+		}}
+		req := &service.CompletionRequest{Messages: []service.Message{{Role: "user", Content: "Where is A-1?"}}}
+
+		completion, tokens, err := uc.complete(context.Background(), provider, req, tools, input, bot)
+		require.NoError(t, err)
+		assert.Equal(t, "Your order has shipped", completion.Content)
+		assert.Equal(t, 15, tokens)
+
+		require.Len(t, req.Messages, 4)
+		assert.Equal(t, "assistant", req.Messages[1].Role)
+		assert.Len(t, req.Messages[1].ToolCalls, 2)
+		assert.Equal(t, service.Message{Role: "tool", Content: `{"status":"shipped"}`, ToolCallID: "call-1"}, req.Messages[2])
+		assert.Equal(t, "call-2", req.Messages[3].ToolCallID)
+		assert.Contains(t, req.Messages[3].Content, "Unknown tool")
+
+		require.Len(t, toolRepo.executions, 1)
+		assert.Equal(t, "conv-1", toolRepo.executions[0].ConversationID)
+	})
+
+	t.Run("stops calling tools after the last round", func(t *testing.T) {
+		provider := &fakeToolCallingProvider{responses: []*service.CompletionResponse{
+			{ToolCalls: []*entity.ToolCall{toolCall}, Content: "Checking"},
+		}}
+		req := &service.CompletionRequest{Messages: []service.Message{{Role: "user", Content: "Where is A-1?"}}}
+
+		completion, _, err := uc.complete(context.Background(), provider, req, tools, input, bot)
+		require.NoError(t, err)
+		assert.Equal(t, "Checking", completion.Content)
+		assert.Len(t, provider.requests, maxToolRounds+1)
+		assert.Equal(t, "none", provider.requests[maxToolRounds].ToolChoice)
+	})
+}
+
+func TestGenerateAIResponseUseCase_ExternalBot(t *testing.T) {
+	var reply string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if reply == "" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(reply))
+	}))
+	defer server.Close()
+
+	producer := testutil.NewMockProducer()
+	contextService := service.NewConversationContextService(newMockConversationContextRepository(), nil)
+	uc := NewGenerateAIResponseUseCase(nil, nil, nil, contextService, nil, producer)
+	externalBots := service.NewExternalBotService()
+	externalBots.SetHTTPClient(server.Client())
+	uc.SetExternalBotService(externalBots)
+
+	bot := &entity.Bot{ID: "bot-1", TenantID: "tenant-1", Type: entity.BotTypeExternal, Status: entity.BotStatusActive}
+	bot.Config.FallbackMessage = "Let me get someone to help"
+	bot.Config.External = &entity.ExternalBotConfig{Framework: entity.ExternalBotRasa, URL: server.URL}
+	input := &GenerateAIResponseInput{TenantID: "tenant-1", ConversationID: "conv-1", MessageID: "msg-1", Content: "hi", Bot: bot}
+
+	t.Run("relays the answer of the bot", func(t *testing.T) {
+		reply = `[{"recipient_id":"conv-1","text":"Hello!","buttons":[{"title":"Track order","payload":"/track"}]}]`
+		output, err := uc.Execute(context.Background(), input)
+		require.NoError(t, err)
+		assert.Equal(t, "Hello!", output.Response)
+		assert.Equal(t, []entity.QuickReply{{ID: "/track", Title: "Track order", Value: "/track"}}, output.QuickReplies)
+		assert.Equal(t, "rasa", output.Model)
+		assert.False(t, output.ShouldEscalate)
+		assert.False(t, output.Handover)
+	})
+
+	t.Run("hands off when the bot asks to", func(t *testing.T) {
+		reply = `[{"text":"Transferring you"},{"custom":{"handoff":true,"reason":"billing"}}]`
+		output, err := uc.Execute(context.Background(), input)
+		require.NoError(t, err)
+		assert.Equal(t, "Transferring you", output.Response)
+		assert.True(t, output.Handover)
+		assert.Equal(t, "billing", output.EscalateReason)
+	})
+
+	t.Run("falls back and escalates when the bot fails", func(t *testing.T) {
+		reply = ""
+		output, err := uc.Execute(context.Background(), input)
+		require.NoError(t, err)
+		assert.Equal(t, "Let me get someone to help", output.Response)
+		assert.True(t, output.ShouldEscalate)
+		assert.True(t, output.Handover)
+		assert.Contains(t, output.EscalateReason, "HTTP 502")
+	})
+}
@@ -2,6 +2,9 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,6 +13,8 @@ import (
 	"github.com/msgfy/linktor/internal/domain/repository"
 	"github.com/msgfy/linktor/internal/infrastructure/nats"
 	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
 )
 
 // ReceiveMessageOutput represents the result of receiving a message
@@ -18,6 +23,91 @@ type ReceiveMessageOutput struct {
 	Conversation *entity.Conversation
 	Contact      *entity.Contact
 	IsNew        bool
+	Blocked      bool // Rejected by a message hook; nothing was stored
+	BlockReason  string
+}
+
+// PostbackRouter resumes the automation referenced by an interactive reply
+type PostbackRouter interface {
+	Route(ctx context.Context, message *entity.Message, conversation *entity.Conversation) (*service.PostbackResult, error)
+}
+
+// MessageHookRunner runs the tenant's scripted hooks on a message, transforming it in place
+type MessageHookRunner interface {
+	ApplyHooks(ctx context.Context, event entity.HookEvent, channel *entity.Channel, message *entity.Message) *entity.HookOutcome
+}
+
+// SubscriptionHandler handles newsletter opt-in and unsubscribe keywords and
+// tracks replies to newsletter editions
+type SubscriptionHandler interface {
+	HandleInbound(ctx context.Context, message *entity.Message, conversation *entity.Conversation) error
+}
+
+// MediaStore copies the media of inbound messages into Linktor's storage, rewriting
+// the attachment URLs in place
+type MediaStore interface {
+	StoreInbound(ctx context.Context, channel *entity.Channel, message *entity.Message)
+}
+
+// DocumentProcessor parses the documents attached to inbound messages
+type DocumentProcessor interface {
+	HandleInbound(ctx context.Context, message *entity.Message, conversation *entity.Conversation) error
+}
+
+// BookingHandler handles replies to booking slot offers and reminders
+type BookingHandler interface {
+	HandleInbound(ctx context.Context, message *entity.Message, conversation *entity.Conversation) error
+}
+
+// WatchHandler notifies the users watching a conversation of inbound messages
+type WatchHandler interface {
+	HandleInbound(ctx context.Context, message *entity.Message, conversation *entity.Conversation) error
+}
+
+// ConversationTitler generates titles for conversations as messages arrive
+type ConversationTitler interface {
+	HandleInbound(ctx context.Context, message *entity.Message, conversation *entity.Conversation) error
+}
+
+// KnowledgeIngester turns emails sent to knowledge inboxes into knowledge base submissions
+type KnowledgeIngester interface {
+	HandleInbound(ctx context.Context, message *entity.Message, conversation *entity.Conversation) error
+}
+
+// ContactSummaryRefresher refreshes the cached profile cards of contacts with new activity
+type ContactSummaryRefresher interface {
+	HandleInbound(ctx context.Context, message *entity.Message, conversation *entity.Conversation) error
+}
+
+// AgentCopilot prepares suggestions for the agent of an escalated conversation
+type AgentCopilot interface {
+	HandleInbound(ctx context.Context, message *entity.Message, conversation *entity.Conversation) error
+}
+
+// MaintenanceNotifier answers contacts writing to a channel under maintenance and
+// queues their conversations
+type MaintenanceNotifier interface {
+	HandleInbound(ctx context.Context, message *entity.Message, conversation *entity.Conversation) error
+}
+
+// ContactMatcher finds the existing contact of a person reaching out on a new channel
+// by normalized phone or email
+type ContactMatcher interface {
+	MatchContact(ctx context.Context, tenantID, phone, email string) (*entity.Contact, error)
+}
+
+// ConversationReopener matches a contact writing after a resolution to the resolved
+// conversation, either reopening it or returning its ID for a follow-up to link to
+type ConversationReopener interface {
+	MatchInbound(ctx context.Context, tenantID, contactID, channelID string) (reopened *entity.Conversation, followUpOf string, err error)
+}
+
+// GroupConversations threads the messages of group chats and email threads with
+// several recipients into one conversation per group, with a participant per contact
+type GroupConversations interface {
+	InboundGroup(channel *entity.Channel, metadata map[string]string) (groupID string, participants []string)
+	FindGroupConversation(ctx context.Context, channelID, groupID string) (*entity.Conversation, error)
+	Join(ctx context.Context, conversation *entity.Conversation, contactID, identifier string) error
 }
 
 // ReceiveMessageUseCase handles receiving messages from channels
@@ -28,6 +118,21 @@ type ReceiveMessageUseCase struct {
 	contactRepo      repository.ContactRepository
 	producer         nats.Publisher
 	normalizer       *service.MessageNormalizer
+	postbackRouter   PostbackRouter
+	messageHooks     MessageHookRunner
+	subscriptions    SubscriptionHandler
+	documents        DocumentProcessor
+	media            MediaStore
+	bookings         BookingHandler
+	watches          WatchHandler
+	titler           ConversationTitler
+	knowledge        KnowledgeIngester
+	summaries        ContactSummaryRefresher
+	contactMatcher   ContactMatcher
+	copilot          AgentCopilot
+	reopener         ConversationReopener
+	groups           GroupConversations
+	maintenance      MaintenanceNotifier
 }
 
 // NewReceiveMessageUseCase creates a new receive message use case
@@ -49,6 +154,82 @@ func NewReceiveMessageUseCase(
 	}
 }
 
+// SetPostbackRouter configures routing of button and list replies to their postbacks
+func (uc *ReceiveMessageUseCase) SetPostbackRouter(router PostbackRouter) {
+	uc.postbackRouter = router
+}
+
+// SetMessageHooks configures the scripted hooks run on inbound messages
+func (uc *ReceiveMessageUseCase) SetMessageHooks(hooks MessageHookRunner) {
+	uc.messageHooks = hooks
+}
+
+// SetSubscriptionHandler configures handling of newsletter keywords in inbound messages
+func (uc *ReceiveMessageUseCase) SetSubscriptionHandler(handler SubscriptionHandler) {
+	uc.subscriptions = handler
+}
+
+// SetDocumentProcessor configures parsing of documents attached to inbound messages
+func (uc *ReceiveMessageUseCase) SetDocumentProcessor(processor DocumentProcessor) {
+	uc.documents = processor
+}
+
+// SetMediaStore configures storing the media of inbound messages
+func (uc *ReceiveMessageUseCase) SetMediaStore(store MediaStore) {
+	uc.media = store
+}
+
+// SetBookingHandler configures handling of replies to booking messages
+func (uc *ReceiveMessageUseCase) SetBookingHandler(handler BookingHandler) {
+	uc.bookings = handler
+}
+
+// SetWatchHandler configures notification of conversation watchers
+func (uc *ReceiveMessageUseCase) SetWatchHandler(handler WatchHandler) {
+	uc.watches = handler
+}
+
+// SetConversationTitler configures generation of conversation titles
+func (uc *ReceiveMessageUseCase) SetConversationTitler(titler ConversationTitler) {
+	uc.titler = titler
+}
+
+// SetKnowledgeIngester configures ingestion of emails sent to knowledge inboxes
+func (uc *ReceiveMessageUseCase) SetKnowledgeIngester(ingester KnowledgeIngester) {
+	uc.knowledge = ingester
+}
+
+// SetContactSummaries configures refreshing of contact profile cards
+func (uc *ReceiveMessageUseCase) SetContactSummaries(summaries ContactSummaryRefresher) {
+	uc.summaries = summaries
+}
+
+// SetAgentCopilot configures copilot suggestions for agents
+func (uc *ReceiveMessageUseCase) SetAgentCopilot(copilot AgentCopilot) {
+	uc.copilot = copilot
+}
+
+// SetContactMatcher configures matching new channel identities to existing contacts
+func (uc *ReceiveMessageUseCase) SetContactMatcher(matcher ContactMatcher) {
+	uc.contactMatcher = matcher
+}
+
+// SetConversationReopener configures reopening recently resolved conversations and
+// linking follow-ups to them
+func (uc *ReceiveMessageUseCase) SetConversationReopener(reopener ConversationReopener) {
+	uc.reopener = reopener
+}
+
+// SetGroupConversations threads group messages into group conversations
+func (uc *ReceiveMessageUseCase) SetGroupConversations(groups GroupConversations) {
+	uc.groups = groups
+}
+
+// SetMaintenanceNotifier enables the notices of channel maintenance windows
+func (uc *ReceiveMessageUseCase) SetMaintenanceNotifier(maintenance MaintenanceNotifier) {
+	uc.maintenance = maintenance
+}
+
 // Execute processes an incoming message from a channel
 func (uc *ReceiveMessageUseCase) Execute(ctx context.Context, inbound *nats.InboundMessage) (*ReceiveMessageOutput, error) {
 	// Check for duplicate message
@@ -63,6 +244,25 @@ func (uc *ReceiveMessageUseCase) Execute(ctx context.Context, inbound *nats.Inbo
 	// Normalize the inbound message
 	normalized := uc.normalizer.NormalizeInbound(inbound)
 
+	// Get channel
+	channel, err := uc.channelRepo.FindByID(ctx, inbound.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Run message hooks before anything is stored, so a blocked message leaves no
+	// contact or conversation behind. Blocking is not an error: the message must
+	// not be redelivered.
+	if uc.messageHooks != nil {
+		hooked := uc.normalizer.ToEntity(normalized)
+		outcome := uc.messageHooks.ApplyHooks(ctx, entity.HookEventInbound, channel, hooked)
+		if outcome.Blocked {
+			return &ReceiveMessageOutput{Blocked: true, BlockReason: outcome.Reason}, nil
+		}
+		normalized.Content = hooked.Content
+		normalized.Metadata = hooked.Metadata
+	}
+
 	// Get or create contact
 	contact, _, err := uc.getOrCreateContact(ctx, inbound)
 	if err != nil {
@@ -70,19 +270,86 @@ func (uc *ReceiveMessageUseCase) Execute(ctx context.Context, inbound *nats.Inbo
 	}
 	normalized.ContactID = contact.ID
 
-	// Get channel
-	channel, err := uc.channelRepo.FindByID(ctx, inbound.ChannelID)
-	if err != nil {
-		return nil, err
+	// Keep the delivery address or location the contact shared
+	if data := inbound.Metadata["contact_address"]; data != "" {
+		var address entity.ContactAddress
+		if err := json.Unmarshal([]byte(data), &address); err == nil && address.Source != "" {
+			contact.SetAddress(address)
+			if err := uc.contactRepo.Update(ctx, contact); err != nil {
+				logger.Warn("Failed to record the address the contact shared",
+					zap.String("contact_id", contact.ID),
+					zap.Error(err))
+			}
+		}
 	}
 
-	// Get or create conversation
-	conversation, isNewConversation, err := uc.getOrCreateConversation(ctx, inbound.TenantID, channel.ID, contact.ID)
+	// Get or create conversation. Group messages are attributed to the participant
+	// who wrote them.
+	var conversation *entity.Conversation
+	var isNewConversation bool
+	groupID, groupParticipants := "", []string(nil)
+	if uc.groups != nil {
+		groupID, groupParticipants = uc.groups.InboundGroup(channel, inbound.Metadata)
+	}
+	if groupID != "" {
+		conversation, isNewConversation, err = uc.getOrCreateGroupConversation(ctx, inbound, contact, groupID, groupParticipants)
+		normalized.SenderID = contact.ID
+	} else {
+		conversation, isNewConversation, err = uc.getOrCreateConversation(ctx, inbound.TenantID, channel.ID, contact.ID)
+	}
 	if err != nil {
 		return nil, err
 	}
 	normalized.ConversationID = conversation.ID
 
+	// Remember which number of a multi-number channel the contact wrote to, so replies use it
+	if phoneNumberID := inbound.Metadata["phone_number_id"]; phoneNumberID != "" && conversation.Metadata["phone_number_id"] != phoneNumberID {
+		if conversation.Metadata == nil {
+			conversation.Metadata = make(map[string]string)
+		}
+		conversation.Metadata["phone_number_id"] = phoneNumberID
+		if err := uc.conversationRepo.SetMetadata(ctx, conversation.ID, map[string]string{"phone_number_id": phoneNumberID}); err != nil {
+			logger.Warn("Failed to record the phone number of the conversation",
+				zap.String("conversation_id", conversation.ID),
+				zap.String("phone_number_id", phoneNumberID),
+				zap.Error(err))
+		}
+	}
+
+	// Messages carry the disappearing timer of the chat they were sent in, which follows
+	// changes made on the phone
+	if value := inbound.Metadata[entity.MetadataDisappearingTimer]; value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && conversation.SetDisappearingTimer(time.Duration(seconds)*time.Second, inbound.Timestamp) {
+			if err := uc.conversationRepo.SetMetadata(ctx, conversation.ID, conversation.DisappearingTimerMetadata()); err != nil {
+				logger.Warn("Failed to record the disappearing timer of the conversation",
+					zap.String("conversation_id", conversation.ID),
+					zap.Int("seconds", seconds),
+					zap.Error(err))
+			}
+		}
+	}
+
+	// Keep the click-to-WhatsApp ad referral on the conversation so flows and templates
+	// can use it after the first message
+	if inbound.Metadata["referral_source_id"] != "" && conversation.Metadata["referral_source_id"] != inbound.Metadata["referral_source_id"] {
+		if conversation.Metadata == nil {
+			conversation.Metadata = make(map[string]string)
+		}
+		referral := make(map[string]string)
+		for key, value := range inbound.Metadata {
+			if strings.HasPrefix(key, "referral_") {
+				conversation.Metadata[key] = value
+				referral[key] = value
+			}
+		}
+		if err := uc.conversationRepo.SetMetadata(ctx, conversation.ID, referral); err != nil {
+			logger.Warn("Failed to record the ad referral of the conversation",
+				zap.String("conversation_id", conversation.ID),
+				zap.String("referral_source_id", inbound.Metadata["referral_source_id"]),
+				zap.Error(err))
+		}
+	}
+
 	// Create message entity
 	message := uc.normalizer.ToEntity(normalized)
 	message.ID = uuid.New().String()
@@ -92,6 +359,11 @@ func (uc *ReceiveMessageUseCase) Execute(ctx context.Context, inbound *nats.Inbo
 		att.MessageID = message.ID
 	}
 
+	// Keep a copy of the media before provider references expire
+	if uc.media != nil && len(message.Attachments) > 0 {
+		uc.media.StoreInbound(ctx, channel, message)
+	}
+
 	// Save message to database
 	if err := uc.messageRepo.Create(ctx, message); err != nil {
 		return nil, err
@@ -119,6 +391,49 @@ func (uc *ReceiveMessageUseCase) Execute(ctx context.Context, inbound *nats.Inbo
 	// Publish event
 	uc.publishMessageReceivedEvent(ctx, inbound.TenantID, message, conversation, contact)
 
+	// Resume the automation behind a tapped button or list row; failures are logged
+	// by the router and must not reject the message
+	if uc.postbackRouter != nil {
+		uc.postbackRouter.Route(ctx, message, conversation)
+	}
+
+	// Newsletter keywords are handled after the message is stored, so agents still see them
+	if uc.subscriptions != nil {
+		uc.subscriptions.HandleInbound(ctx, message, conversation)
+	}
+
+	if uc.maintenance != nil {
+		uc.maintenance.HandleInbound(ctx, message, conversation)
+	}
+
+	if uc.documents != nil && len(message.Attachments) > 0 {
+		uc.documents.HandleInbound(ctx, message, conversation)
+	}
+
+	if uc.bookings != nil {
+		uc.bookings.HandleInbound(ctx, message, conversation)
+	}
+
+	if uc.watches != nil {
+		uc.watches.HandleInbound(ctx, message, conversation)
+	}
+
+	if uc.titler != nil {
+		uc.titler.HandleInbound(ctx, message, conversation)
+	}
+
+	if uc.knowledge != nil && channel.Type == entity.ChannelTypeEmail {
+		uc.knowledge.HandleInbound(ctx, message, conversation)
+	}
+
+	if uc.summaries != nil {
+		uc.summaries.HandleInbound(ctx, message, conversation)
+	}
+
+	if uc.copilot != nil {
+		uc.copilot.HandleInbound(ctx, message, conversation)
+	}
+
 	return &ReceiveMessageOutput{
 		Message:      message,
 		Conversation: conversation,
@@ -129,14 +444,7 @@ func (uc *ReceiveMessageUseCase) Execute(ctx context.Context, inbound *nats.Inbo
 
 // getOrCreateContact finds or creates a contact based on the inbound message
 func (uc *ReceiveMessageUseCase) getOrCreateContact(ctx context.Context, inbound *nats.InboundMessage) (*entity.Contact, bool, error) {
-	// Extract identifier from metadata or external ID
-	identifier := inbound.ExternalID
-	if id, ok := inbound.Metadata["sender_id"]; ok {
-		identifier = id
-	}
-	if phone, ok := inbound.Metadata["phone"]; ok {
-		identifier = phone
-	}
+	identifier := senderIdentifier(inbound)
 
 	// Try to find existing contact by identity
 	contact, err := uc.contactRepo.FindByIdentity(ctx, inbound.TenantID, inbound.ChannelType, identifier)
@@ -144,22 +452,32 @@ func (uc *ReceiveMessageUseCase) getOrCreateContact(ctx context.Context, inbound
 		return contact, false, nil
 	}
 
+	// Email channels identify the sender by address
+	email := inbound.Metadata["email"]
+	if email == "" && inbound.ChannelType == string(entity.ChannelTypeEmail) {
+		email = entity.NormalizeEmail(identifier)
+	}
+
 	// Try to find by phone if available
 	if phone, ok := inbound.Metadata["phone"]; ok && phone != "" {
 		contact, err = uc.contactRepo.FindByPhone(ctx, inbound.TenantID, phone)
-		if err == nil && contact != nil {
-			// Add identity for this channel
-			identity := &entity.ContactIdentity{
-				ID:          uuid.New().String(),
-				ContactID:   contact.ID,
-				ChannelType: inbound.ChannelType,
-				Identifier:  identifier,
-				Metadata:    inbound.Metadata,
-				CreatedAt:   time.Now(),
-			}
-			uc.contactRepo.AddIdentity(ctx, identity)
-			return contact, false, nil
+	}
+	// Fall back to the same person known by another format of phone or by email
+	if (contact == nil || err != nil) && uc.contactMatcher != nil {
+		contact, err = uc.contactMatcher.MatchContact(ctx, inbound.TenantID, inbound.Metadata["phone"], email)
+	}
+	if err == nil && contact != nil {
+		// Add identity for this channel
+		identity := &entity.ContactIdentity{
+			ID:          uuid.New().String(),
+			ContactID:   contact.ID,
+			ChannelType: inbound.ChannelType,
+			Identifier:  identifier,
+			Metadata:    inbound.Metadata,
+			CreatedAt:   time.Now(),
 		}
+		uc.contactRepo.AddIdentity(ctx, identity)
+		return contact, false, nil
 	}
 
 	// Create new contact
@@ -181,6 +499,7 @@ func (uc *ReceiveMessageUseCase) getOrCreateContact(ctx context.Context, inbound
 		ID:           uuid.New().String(),
 		TenantID:     inbound.TenantID,
 		Name:         name,
+		Email:        email,
 		Phone:        phone,
 		CustomFields: make(map[string]string),
 		Tags:         []string{},
@@ -211,6 +530,77 @@ func (uc *ReceiveMessageUseCase) getOrCreateContact(ctx context.Context, inbound
 	return contact, true, nil
 }
 
+// senderIdentifier returns the address of the sender of an inbound message on its channel
+func senderIdentifier(inbound *nats.InboundMessage) string {
+	// Extract identifier from metadata or external ID
+	identifier := inbound.ExternalID
+	if id, ok := inbound.Metadata["sender_id"]; ok {
+		identifier = id
+	}
+	if phone, ok := inbound.Metadata["phone"]; ok {
+		identifier = phone
+	}
+	return identifier
+}
+
+// getOrCreateGroupConversation finds or creates the conversation of a group and adds
+// the sender and the other participants the channel reported to it
+func (uc *ReceiveMessageUseCase) getOrCreateGroupConversation(ctx context.Context, inbound *nats.InboundMessage, sender *entity.Contact, groupID string, participants []string) (*entity.Conversation, bool, error) {
+	conversation, err := uc.groups.FindGroupConversation(ctx, inbound.ChannelID, groupID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	isNew := conversation == nil
+	if isNew {
+		now := time.Now()
+		conversation = &entity.Conversation{
+			ID:        uuid.New().String(),
+			TenantID:  inbound.TenantID,
+			ChannelID: inbound.ChannelID,
+			ContactID: sender.ID,
+			Status:    entity.ConversationStatusOpen,
+			Priority:  entity.ConversationPriorityNormal,
+			Metadata:  map[string]string{entity.MetadataGroupID: groupID},
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if name := inbound.Metadata[entity.MetadataGroupName]; name != "" {
+			conversation.Metadata[entity.MetadataGroupName] = name
+		}
+		if subject := inbound.Metadata["subject"]; subject != "" {
+			conversation.Subject = subject
+		}
+		if err := uc.conversationRepo.Create(ctx, conversation); err != nil {
+			return nil, false, err
+		}
+		uc.publishConversationCreatedEvent(ctx, inbound.TenantID, conversation)
+	}
+
+	if err := uc.groups.Join(ctx, conversation, sender.ID, senderIdentifier(inbound)); err != nil {
+		return nil, false, err
+	}
+
+	// Contacts copied on the message join as well, created when unknown
+	for _, identifier := range participants {
+		copied := &nats.InboundMessage{
+			TenantID:    inbound.TenantID,
+			ChannelID:   inbound.ChannelID,
+			ChannelType: inbound.ChannelType,
+			Metadata:    map[string]string{"sender_id": identifier},
+		}
+		contact, _, err := uc.getOrCreateContact(ctx, copied)
+		if err != nil {
+			return nil, false, err
+		}
+		if err := uc.groups.Join(ctx, conversation, contact.ID, identifier); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return conversation, isNew, nil
+}
+
 // getOrCreateConversation finds or creates a conversation
 func (uc *ReceiveMessageUseCase) getOrCreateConversation(ctx context.Context, tenantID, channelID, contactID string) (*entity.Conversation, bool, error) {
 	// Try to find open conversation
@@ -219,6 +609,20 @@ func (uc *ReceiveMessageUseCase) getOrCreateConversation(ctx context.Context, te
 		return conversation, false, nil
 	}
 
+	// Reopen a conversation resolved within the tenant's reopen window, or link the new
+	// one to it as a follow-up. Failures fall back to a new, unlinked conversation.
+	var followUpOf string
+	if uc.reopener != nil {
+		reopened, resolvedID, err := uc.reopener.MatchInbound(ctx, tenantID, contactID, channelID)
+		if err == nil && reopened != nil {
+			uc.publishConversationReopenedEvent(ctx, tenantID, reopened)
+			return reopened, false, nil
+		}
+		if err == nil {
+			followUpOf = resolvedID
+		}
+	}
+
 	// Create new conversation
 	now := time.Now()
 	conversation = &entity.Conversation{
@@ -232,6 +636,9 @@ func (uc *ReceiveMessageUseCase) getOrCreateConversation(ctx context.Context, te
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
+	if followUpOf != "" {
+		conversation.Metadata = map[string]string{entity.MetadataFollowUpOf: followUpOf}
+	}
 
 	if err := uc.conversationRepo.Create(ctx, conversation); err != nil {
 		return nil, false, err
@@ -275,13 +682,32 @@ func (uc *ReceiveMessageUseCase) publishContactCreatedEvent(ctx context.Context,
 }
 
 func (uc *ReceiveMessageUseCase) publishConversationCreatedEvent(ctx context.Context, tenantID string, conversation *entity.Conversation) {
+	payload := map[string]interface{}{
+		"conversation_id": conversation.ID,
+		"channel_id":      conversation.ChannelID,
+		"contact_id":      conversation.ContactID,
+	}
+	if followUpOf := conversation.Metadata[entity.MetadataFollowUpOf]; followUpOf != "" {
+		payload[entity.MetadataFollowUpOf] = followUpOf
+	}
+	event := &nats.Event{
+		Type:      nats.EventConversationCreated,
+		TenantID:  tenantID,
+		Payload:   payload,
+		Timestamp: time.Now(),
+	}
+	uc.producer.PublishEvent(ctx, event)
+}
+
+func (uc *ReceiveMessageUseCase) publishConversationReopenedEvent(ctx context.Context, tenantID string, conversation *entity.Conversation) {
 	event := &nats.Event{
-		Type:     nats.EventConversationCreated,
+		Type:     nats.EventConversationReopened,
 		TenantID: tenantID,
 		Payload: map[string]interface{}{
 			"conversation_id": conversation.ID,
 			"channel_id":      conversation.ChannelID,
 			"contact_id":      conversation.ContactID,
+			"source":          string(entity.ConversationReopenCustomer),
 		},
 		Timestamp: time.Now(),
 	}
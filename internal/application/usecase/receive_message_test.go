@@ -601,4 +601,256 @@ func TestReceiveMessageUseCase(t *testing.T) {
 		assert.Equal(t, "text", messageEvent.Payload["content_type"])
 		assert.Equal(t, "Hello, world!", messageEvent.Payload["content"])
 	})
+
+	t.Run("Message Hooks - Transform", func(t *testing.T) {
+		f := newReceiveMessageFixture()
+		channel := makeChannel("ch-1", "tenant-1")
+		f.channelRepo.Channels[channel.ID] = channel
+		f.uc.SetMessageHooks(&stubMessageHooks{content: "Hello, hooks!", metadata: map[string]string{"tier": "gold"}})
+
+		output, err := f.uc.Execute(ctx, makeInbound("ch-1", "tenant-1"))
+		require.NoError(t, err)
+		assert.Equal(t, "Hello, hooks!", output.Message.Content)
+		assert.Equal(t, "gold", output.Message.Metadata["tier"])
+	})
+
+	t.Run("Message Hooks - Blocked Message Stores Nothing", func(t *testing.T) {
+		f := newReceiveMessageFixture()
+		channel := makeChannel("ch-1", "tenant-1")
+		f.channelRepo.Channels[channel.ID] = channel
+		f.uc.SetMessageHooks(&stubMessageHooks{block: "spam"})
+
+		output, err := f.uc.Execute(ctx, makeInbound("ch-1", "tenant-1"))
+		require.NoError(t, err)
+		assert.True(t, output.Blocked)
+		assert.Equal(t, "spam", output.BlockReason)
+		assert.Empty(t, f.messageRepo.Messages)
+		assert.Empty(t, f.producer.Events)
+	})
+
+	t.Run("Subscription Handler Sees Stored Message", func(t *testing.T) {
+		f := newReceiveMessageFixture()
+		channel := makeChannel("ch-1", "tenant-1")
+		f.channelRepo.Channels[channel.ID] = channel
+		handler := &stubSubscriptionHandler{}
+		f.uc.SetSubscriptionHandler(handler)
+
+		output, err := f.uc.Execute(ctx, makeInbound("ch-1", "tenant-1"))
+		require.NoError(t, err)
+		require.Len(t, handler.messages, 1)
+		assert.Equal(t, output.Message.ID, handler.messages[0].ID)
+		assert.Contains(t, f.messageRepo.Messages, output.Message.ID)
+	})
+}
+
+// stubSubscriptionHandler records the inbound messages it is given
+type stubSubscriptionHandler struct {
+	messages []*entity.Message
+}
+
+func (s *stubSubscriptionHandler) HandleInbound(ctx context.Context, message *entity.Message, conversation *entity.Conversation) error {
+	s.messages = append(s.messages, message)
+	return nil
+}
+
+// stubMessageHooks replaces the content and metadata of messages or blocks them
+type stubMessageHooks struct {
+	content  string
+	metadata map[string]string
+	block    string
+}
+
+func (s *stubMessageHooks) ApplyHooks(ctx context.Context, event entity.HookEvent, channel *entity.Channel, message *entity.Message) *entity.HookOutcome {
+	if s.block != "" {
+		return &entity.HookOutcome{Blocked: true, Reason: s.block}
+	}
+	message.Content = s.content
+	message.Metadata = s.metadata
+	return &entity.HookOutcome{}
+}
+
+func TestReceiveMessageUseCase_ContactAddress(t *testing.T) {
+	ctx := context.Background()
+	f := newReceiveMessageFixture()
+	channel := makeChannel("ch-1", "tenant-1")
+	f.channelRepo.Channels[channel.ID] = channel
+
+	inbound := makeInbound("ch-1", "tenant-1")
+	inbound.Metadata["contact_address"] = `{"source":"address_message","street":"MG Road","city":"Mumbai","postal_code":"400063","country":"IN"}`
+	output, err := f.uc.Execute(ctx, inbound)
+	require.NoError(t, err)
+
+	contact := f.contactRepo.Contacts[output.Contact.ID]
+	require.Len(t, contact.Addresses, 1)
+	assert.Equal(t, "MG Road", contact.Addresses[0].Street)
+
+	// A new address from the same source replaces the previous one
+	inbound = makeInbound("ch-1", "tenant-1")
+	inbound.ExternalID = "ext-456"
+	inbound.Metadata["contact_address"] = `{"source":"address_message","street":"Linking Road","city":"Mumbai","country":"IN"}`
+	_, err = f.uc.Execute(ctx, inbound)
+	require.NoError(t, err)
+
+	require.Len(t, contact.Addresses, 1)
+	assert.Equal(t, "Linking Road", contact.Addresses[0].Street)
+}
+
+// stubContactMatcher matches contacts by normalized email only
+type stubContactMatcher struct {
+	contacts map[string]*entity.Contact
+}
+
+func (m *stubContactMatcher) MatchContact(ctx context.Context, tenantID, phone, email string) (*entity.Contact, error) {
+	for _, contact := range m.contacts {
+		if contact.TenantID == tenantID && email != "" && entity.NormalizeEmail(contact.Email) == entity.NormalizeEmail(email) {
+			return contact, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestReceiveMessageUseCase_ContactMatcher(t *testing.T) {
+	ctx := context.Background()
+	f := newReceiveMessageFixture()
+	f.uc.SetContactMatcher(&stubContactMatcher{contacts: f.contactRepo.Contacts})
+	channel := makeChannel("ch-email", "tenant-1")
+	channel.Type = entity.ChannelTypeEmail
+	f.channelRepo.Channels[channel.ID] = channel
+
+	existing := &entity.Contact{ID: "contact-1", TenantID: "tenant-1", Name: "John Doe", Email: "john@example.com"}
+	f.contactRepo.Contacts[existing.ID] = existing
+
+	inbound := makeInbound("ch-email", "tenant-1")
+	inbound.ChannelType = string(entity.ChannelTypeEmail)
+	inbound.Metadata = map[string]string{"sender_id": "John Doe <John@Example.com>"}
+
+	output, err := f.uc.Execute(ctx, inbound)
+	require.NoError(t, err)
+	assert.Equal(t, "contact-1", output.Contact.ID)
+	assert.Len(t, f.contactRepo.Contacts, 1)
+	require.Len(t, f.contactRepo.Identities["contact-1"], 1)
+	assert.Equal(t, "email", f.contactRepo.Identities["contact-1"][0].ChannelType)
+
+	// Unknown senders get a contact with their address, so other channels can match it
+	inbound = makeInbound("ch-email", "tenant-1")
+	inbound.ExternalID = "ext-456"
+	inbound.ChannelType = string(entity.ChannelTypeEmail)
+	inbound.Metadata = map[string]string{"sender_id": "mary@example.com"}
+
+	output, err = f.uc.Execute(ctx, inbound)
+	require.NoError(t, err)
+	assert.NotEqual(t, "contact-1", output.Contact.ID)
+	assert.Equal(t, "mary@example.com", output.Contact.Email)
+}
+
+// stubConversationReopener reopens or links to a fixed conversation
+type stubConversationReopener struct {
+	reopened   *entity.Conversation
+	followUpOf string
+}
+
+func (m *stubConversationReopener) MatchInbound(ctx context.Context, tenantID, contactID, channelID string) (*entity.Conversation, string, error) {
+	if m.reopened != nil {
+		m.reopened.Reopen()
+	}
+	return m.reopened, m.followUpOf, nil
+}
+
+func TestReceiveMessageUseCase_ConversationReopener(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("reopens the resolved conversation", func(t *testing.T) {
+		f := newReceiveMessageFixture()
+		f.channelRepo.Channels["ch-1"] = makeChannel("ch-1", "tenant-1")
+		resolved := &entity.Conversation{ID: "conv-resolved", TenantID: "tenant-1", ChannelID: "ch-1", Status: entity.ConversationStatusResolved}
+		f.conversationRepo.Conversations[resolved.ID] = resolved
+		f.uc.SetConversationReopener(&stubConversationReopener{reopened: resolved})
+
+		output, err := f.uc.Execute(ctx, makeInbound("ch-1", "tenant-1"))
+		require.NoError(t, err)
+		assert.Equal(t, "conv-resolved", output.Conversation.ID)
+		assert.False(t, output.IsNew)
+		assert.Len(t, f.conversationRepo.Conversations, 1)
+		assert.Equal(t, entity.ConversationStatusOpen, output.Conversation.Status)
+
+		var types []string
+		for _, event := range f.producer.Events {
+			types = append(types, event.Type)
+		}
+		assert.Contains(t, types, nats.EventConversationReopened)
+		assert.NotContains(t, types, nats.EventConversationCreated)
+	})
+
+	t.Run("links a follow-up to the resolved conversation", func(t *testing.T) {
+		f := newReceiveMessageFixture()
+		f.channelRepo.Channels["ch-1"] = makeChannel("ch-1", "tenant-1")
+		f.uc.SetConversationReopener(&stubConversationReopener{followUpOf: "conv-resolved"})
+
+		output, err := f.uc.Execute(ctx, makeInbound("ch-1", "tenant-1"))
+		require.NoError(t, err)
+		assert.True(t, output.IsNew)
+		assert.Equal(t, "conv-resolved", output.Conversation.Metadata[entity.MetadataFollowUpOf])
+	})
+}
+
+// stubGroupConversations treats messages with a group ID as group messages and records joins
+type stubGroupConversations struct {
+	conversationRepo *testutil.MockConversationRepository
+	others           []string
+	joined           []string
+}
+
+func (s *stubGroupConversations) InboundGroup(channel *entity.Channel, metadata map[string]string) (string, []string) {
+	return metadata[entity.MetadataGroupID], s.others
+}
+
+func (s *stubGroupConversations) FindGroupConversation(ctx context.Context, channelID, groupID string) (*entity.Conversation, error) {
+	return s.conversationRepo.FindByGroup(ctx, channelID, groupID)
+}
+
+func (s *stubGroupConversations) Join(ctx context.Context, conversation *entity.Conversation, contactID, identifier string) error {
+	s.joined = append(s.joined, conversation.ID+"/"+identifier)
+	return nil
+}
+
+func TestReceiveMessageUseCase_GroupConversations(t *testing.T) {
+	ctx := context.Background()
+	f := newReceiveMessageFixture()
+	f.channelRepo.Channels["ch-1"] = makeChannel("ch-1", "tenant-1")
+	groups := &stubGroupConversations{conversationRepo: f.conversationRepo, others: []string{"+5511777777777"}}
+	f.uc.SetGroupConversations(groups)
+
+	inbound := makeInbound("ch-1", "tenant-1")
+	inbound.Metadata[entity.MetadataGroupID] = "120363@g.us"
+	inbound.Metadata[entity.MetadataGroupName] = "Project team"
+	first, err := f.uc.Execute(ctx, inbound)
+	require.NoError(t, err)
+	assert.True(t, first.IsNew)
+	assert.True(t, first.Conversation.IsGroup())
+	assert.Equal(t, "Project team", first.Conversation.Metadata[entity.MetadataGroupName])
+	assert.Equal(t, first.Contact.ID, first.Message.SenderID, "group messages are attributed to their sender")
+	assert.Equal(t, []string{first.Conversation.ID + "/+5511888888888", first.Conversation.ID + "/+5511777777777"}, groups.joined)
+	assert.Len(t, f.contactRepo.Contacts, 2, "copied participants become contacts")
+
+	// Another member writing in the group joins the same conversation
+	groups.others = nil
+	second := makeInbound("ch-1", "tenant-1")
+	second.ID = "inbound-2"
+	second.ExternalID = "ext-456"
+	second.Metadata["phone"] = "+5511777777777"
+	second.Metadata[entity.MetadataGroupID] = "120363@g.us"
+	output, err := f.uc.Execute(ctx, second)
+	require.NoError(t, err)
+	assert.False(t, output.IsNew)
+	assert.Equal(t, first.Conversation.ID, output.Conversation.ID)
+	assert.NotEqual(t, first.Contact.ID, output.Message.SenderID)
+
+	// One-to-one messages of a group member stay out of the group conversation
+	direct := makeInbound("ch-1", "tenant-1")
+	direct.ID = "inbound-3"
+	direct.ExternalID = "ext-789"
+	output, err = f.uc.Execute(ctx, direct)
+	require.NoError(t, err)
+	assert.True(t, output.IsNew)
+	assert.NotEqual(t, first.Conversation.ID, output.Conversation.ID)
 }
@@ -3,9 +3,11 @@ package usecase
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/application/service"
 	"github.com/msgfy/linktor/internal/domain/entity"
 	"github.com/msgfy/linktor/internal/domain/repository"
 	"github.com/msgfy/linktor/internal/infrastructure/nats"
@@ -41,6 +43,22 @@ type SendMessageOutput struct {
 	Conversation *entity.Conversation
 }
 
+// OutboundGuard decides whether a message may be sent on a channel
+type OutboundGuard interface {
+	CheckOutbound(ctx context.Context, channel *entity.Channel, message *entity.Message) error
+}
+
+// RecipientNormalizer validates a recipient identifier and formats it for the channel
+type RecipientNormalizer interface {
+	NormalizeRecipient(ctx context.Context, channel *entity.Channel, recipientID string) (string, error)
+}
+
+// GroupRecipients returns the participants a message in a group conversation is also
+// addressed to, besides the contact of the conversation
+type GroupRecipients interface {
+	GroupRecipients(ctx context.Context, conversation *entity.Conversation) ([]string, error)
+}
+
 // SendMessageUseCase handles sending messages
 type SendMessageUseCase struct {
 	messageRepo      repository.MessageRepository
@@ -48,6 +66,11 @@ type SendMessageUseCase struct {
 	channelRepo      repository.ChannelRepository
 	contactRepo      repository.ContactRepository
 	producer         nats.Publisher
+	phoneNumbers     *service.WhatsAppPhoneNumberService
+	outboundGuards   []OutboundGuard
+	messageHooks     MessageHookRunner
+	recipients       RecipientNormalizer
+	groups           GroupRecipients
 }
 
 // NewSendMessageUseCase creates a new send message use case
@@ -67,6 +90,32 @@ func NewSendMessageUseCase(
 	}
 }
 
+// SetPhoneNumberService enables sender selection for multi-number WhatsApp Official channels
+func (uc *SendMessageUseCase) SetPhoneNumberService(svc *service.WhatsAppPhoneNumberService) {
+	uc.phoneNumbers = svc
+}
+
+// AddOutboundGuard adds a guard that can reject messages before they are stored.
+// Guards run in the order they were added.
+func (uc *SendMessageUseCase) AddOutboundGuard(guard OutboundGuard) {
+	uc.outboundGuards = append(uc.outboundGuards, guard)
+}
+
+// SetRecipientNormalizer rejects messages to malformed identifiers before they are stored
+func (uc *SendMessageUseCase) SetRecipientNormalizer(recipients RecipientNormalizer) {
+	uc.recipients = recipients
+}
+
+// SetGroupRecipients addresses email replies in group conversations to every participant
+func (uc *SendMessageUseCase) SetGroupRecipients(groups GroupRecipients) {
+	uc.groups = groups
+}
+
+// SetMessageHooks configures the scripted hooks run on outbound messages
+func (uc *SendMessageUseCase) SetMessageHooks(hooks MessageHookRunner) {
+	uc.messageHooks = hooks
+}
+
 // Execute sends a message
 func (uc *SendMessageUseCase) Execute(ctx context.Context, input *SendMessageInput) (*SendMessageOutput, error) {
 	// Validate input
@@ -88,6 +137,11 @@ func (uc *SendMessageUseCase) Execute(ctx context.Context, input *SendMessageInp
 		return nil, errors.Forbidden("conversation does not belong to tenant")
 	}
 
+	// Drop bot replies generated before an agent took over the conversation
+	if input.SenderType == entity.SenderTypeBot && conversation.IsBotPaused() {
+		return nil, errors.New(errors.ErrCodeBotPaused, "bot is paused for this conversation")
+	}
+
 	// Get channel
 	channel, err := uc.channelRepo.FindByID(ctx, conversation.ChannelID)
 	if err != nil {
@@ -105,8 +159,16 @@ func (uc *SendMessageUseCase) Execute(ctx context.Context, input *SendMessageInp
 		return nil, err
 	}
 
-	// Find recipient identifier for the channel
+	// Find recipient identifier for the channel. Group chats are addressed as a whole.
 	recipientID := uc.findRecipientID(ctx, contact, string(channel.Type))
+	groupChat := conversation.IsGroup() && channel.Type != entity.ChannelTypeEmail
+	if groupChat {
+		recipientID = conversation.GroupID()
+	} else if uc.recipients != nil {
+		if recipientID, err = uc.recipients.NormalizeRecipient(ctx, channel, recipientID); err != nil {
+			return nil, err
+		}
+	}
 
 	// Create message entity
 	now := time.Now()
@@ -127,6 +189,34 @@ func (uc *SendMessageUseCase) Execute(ctx context.Context, input *SendMessageInp
 		message.Metadata = make(map[string]string)
 	}
 
+	if uc.messageHooks != nil {
+		outcome := uc.messageHooks.ApplyHooks(ctx, entity.HookEventOutbound, channel, message)
+		if outcome.Blocked {
+			return nil, errors.New(errors.ErrCodeForbidden, "message blocked by hook: "+outcome.Reason)
+		}
+	}
+
+	for _, guard := range uc.outboundGuards {
+		if err := guard.CheckOutbound(ctx, channel, message); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := uc.phoneNumbers.AssignSender(ctx, channel, conversation, message); err != nil {
+		return nil, err
+	}
+
+	// Group email replies copy the other participants unless the sender chose who to copy
+	if conversation.IsGroup() && !groupChat && uc.groups != nil && message.Metadata["cc"] == "" {
+		cc, err := uc.groups.GroupRecipients(ctx, conversation)
+		if err != nil {
+			return nil, err
+		}
+		if len(cc) > 0 {
+			message.Metadata["cc"] = strings.Join(cc, ",")
+		}
+	}
+
 	// Handle quick replies - convert to interactive message for supported channels
 	if len(input.QuickReplies) > 0 && channelSupportsInteractive(channel.Type) {
 		message.ContentType = entity.ContentTypeInteractive
@@ -244,6 +334,8 @@ func channelSupportsInteractive(channelType entity.ChannelType) bool {
 		return true
 	case entity.ChannelTypeTelegram:
 		return true // Telegram supports inline keyboards
+	case entity.ChannelTypeTeams:
+		return true // Rendered as adaptive cards
 	default:
 		return false
 	}
@@ -318,7 +410,7 @@ func buildInteractiveFromQuickReplies(bodyText string, quickReplies []entity.Qui
 			"text": bodyText,
 		},
 		"action": map[string]interface{}{
-			"button":   "Options",
+			"button": "Options",
 			"sections": []map[string]interface{}{
 				{
 					"rows": rows,
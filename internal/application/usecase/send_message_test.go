@@ -186,6 +186,39 @@ func TestSendMessageUseCase_HappyPath(t *testing.T) {
 	assert.Equal(t, "Hello, world!", outbound.Content)
 }
 
+func TestSendMessageUseCase_MessageHooks(t *testing.T) {
+	msgRepo, convRepo, chRepo, contactRepo, producer, uc := setupSendMessageTest()
+
+	convRepo.Conversations["conv1"] = &entity.Conversation{
+		ID: "conv1", TenantID: "t1", ChannelID: "ch1", ContactID: "c1",
+		Status: entity.ConversationStatusOpen,
+	}
+	chRepo.Channels["ch1"] = activeWhatsAppChannel("t1", "ch1")
+	contactRepo.Contacts["c1"] = &entity.Contact{ID: "c1", TenantID: "t1", Name: "John", Phone: "5511999"}
+
+	input := &SendMessageInput{
+		TenantID:       "t1",
+		ConversationID: "conv1",
+		SenderType:     entity.SenderTypeUser,
+		ContentType:    entity.ContentTypeText,
+		Content:        "Hello, world!",
+	}
+
+	uc.SetMessageHooks(&stubMessageHooks{content: "Hello, hooks!", metadata: map[string]string{"signed": "true"}})
+	output, err := uc.Execute(context.Background(), input)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello, hooks!", output.Message.Content)
+	require.Len(t, producer.OutboundMessages, 1)
+	assert.Equal(t, "Hello, hooks!", producer.OutboundMessages[0].Content)
+	assert.Equal(t, "true", producer.OutboundMessages[0].Metadata["signed"])
+
+	uc.SetMessageHooks(&stubMessageHooks{block: "contains card number"})
+	_, err = uc.Execute(context.Background(), input)
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeForbidden, errors.GetAppError(err).Code)
+	assert.Len(t, msgRepo.Messages, 1)
+}
+
 func TestSendMessageUseCase_TenantMismatch(t *testing.T) {
 	_, convRepo, _, _, _, uc := setupSendMessageTest()
 
@@ -205,6 +238,25 @@ func TestSendMessageUseCase_TenantMismatch(t *testing.T) {
 	assert.Equal(t, errors.ErrCodeForbidden, appErr.Code)
 }
 
+func TestSendMessageUseCase_BotPaused(t *testing.T) {
+	_, convRepo, _, _, _, uc := setupSendMessageTest()
+
+	pausedAt := time.Now()
+	convRepo.Conversations["conv1"] = &entity.Conversation{
+		ID: "conv1", TenantID: "t1", ChannelID: "ch1", ContactID: "c1",
+		Status: entity.ConversationStatusOpen, BotPausedAt: &pausedAt,
+	}
+
+	_, err := uc.Execute(context.Background(), &SendMessageInput{
+		TenantID:       "t1",
+		ConversationID: "conv1",
+		SenderType:     entity.SenderTypeBot,
+		Content:        "hello",
+	})
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeBotPaused, errors.GetAppError(err).Code)
+}
+
 func TestSendMessageUseCase_ChannelNotActive(t *testing.T) {
 	t.Run("channel disabled", func(t *testing.T) {
 		_, convRepo, chRepo, _, _, uc := setupSendMessageTest()
@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// TakeoverConversationInput represents input for taking a conversation over from the bot
+type TakeoverConversationInput struct {
+	ConversationID string
+	TenantID       string
+	UserID         string
+	NotifyCustomer *bool // nil sends the bot's handover message when one is configured
+}
+
+// TakeoverConversationOutput represents the result of a takeover or resume
+type TakeoverConversationOutput struct {
+	Conversation     *entity.Conversation `json:"conversation"`
+	CustomerNotified bool                 `json:"customer_notified"`
+}
+
+// HandoverMessageSender sends the handover notice to the customer
+type HandoverMessageSender interface {
+	Execute(ctx context.Context, input *SendMessageInput) (*SendMessageOutput, error)
+}
+
+// TakeoverConversationUseCase lets an agent take a conversation over from the bot and
+// hand it back. While taken over, bot and flow replies are dropped, including replies
+// already being generated when the takeover happened.
+type TakeoverConversationUseCase struct {
+	conversationRepo repository.ConversationRepository
+	botRepo          repository.BotRepository
+	sender           HandoverMessageSender
+	producer         nats.Publisher
+	assignments      AssignmentListener
+}
+
+// NewTakeoverConversationUseCase creates a new takeover conversation use case
+func NewTakeoverConversationUseCase(
+	conversationRepo repository.ConversationRepository,
+	botRepo repository.BotRepository,
+	sender HandoverMessageSender,
+	producer nats.Publisher,
+) *TakeoverConversationUseCase {
+	return &TakeoverConversationUseCase{
+		conversationRepo: conversationRepo,
+		botRepo:          botRepo,
+		sender:           sender,
+		producer:         producer,
+	}
+}
+
+// SetAssignmentListener tells the listener about conversations taken over by an agent
+func (uc *TakeoverConversationUseCase) SetAssignmentListener(assignments AssignmentListener) {
+	uc.assignments = assignments
+}
+
+// Takeover pauses automation for the conversation and assigns it to the user
+func (uc *TakeoverConversationUseCase) Takeover(ctx context.Context, input *TakeoverConversationInput) (*TakeoverConversationOutput, error) {
+	conversation, err := uc.findConversation(ctx, input.ConversationID, input.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	if !conversation.IsOpen() {
+		return nil, errors.New(errors.ErrCodeBadRequest, "only open conversations can be taken over")
+	}
+
+	// Pause first: bot replies still in flight are dropped once this is stored
+	alreadyPaused := conversation.IsBotPaused()
+	if err := uc.conversationRepo.UpdateBotPause(ctx, conversation.ID, &input.UserID); err != nil {
+		return nil, err
+	}
+	if err := uc.conversationRepo.UpdateAssignee(ctx, conversation.ID, &input.UserID); err != nil {
+		return nil, err
+	}
+	if conversation.Status == entity.ConversationStatusPending {
+		if err := uc.conversationRepo.UpdateStatus(ctx, conversation.ID, entity.ConversationStatusOpen); err != nil {
+			return nil, err
+		}
+	}
+
+	output := &TakeoverConversationOutput{}
+	if !alreadyPaused && (input.NotifyCustomer == nil || *input.NotifyCustomer) {
+		output.CustomerNotified = uc.notifyCustomer(ctx, conversation, input.UserID)
+	}
+
+	if output.Conversation, err = uc.conversationRepo.FindByID(ctx, conversation.ID); err != nil {
+		return nil, err
+	}
+
+	uc.publishEvent(ctx, nats.EventConversationTakenOver, output.Conversation, input.UserID)
+	if uc.assignments != nil {
+		uc.assignments.HandleAssigned(ctx, output.Conversation)
+	}
+
+	return output, nil
+}
+
+// Resume hands the conversation back to the bot and unassigns it
+func (uc *TakeoverConversationUseCase) Resume(ctx context.Context, tenantID, conversationID, userID string) (*TakeoverConversationOutput, error) {
+	conversation, err := uc.findConversation(ctx, conversationID, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if !conversation.IsBotPaused() {
+		return nil, errors.New(errors.ErrCodeBadRequest, "bot is not paused for this conversation")
+	}
+
+	if err := uc.conversationRepo.UpdateAssignee(ctx, conversation.ID, nil); err != nil {
+		return nil, err
+	}
+	if err := uc.conversationRepo.UpdateBotPause(ctx, conversation.ID, nil); err != nil {
+		return nil, err
+	}
+
+	output := &TakeoverConversationOutput{}
+	if output.Conversation, err = uc.conversationRepo.FindByID(ctx, conversation.ID); err != nil {
+		return nil, err
+	}
+
+	uc.publishEvent(ctx, nats.EventConversationBotResumed, output.Conversation, userID)
+
+	return output, nil
+}
+
+// findConversation loads a conversation and checks it belongs to the tenant
+func (uc *TakeoverConversationUseCase) findConversation(ctx context.Context, conversationID, tenantID string) (*entity.Conversation, error) {
+	conversation, err := uc.conversationRepo.FindByID(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.TenantID != tenantID {
+		return nil, errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+	}
+	return conversation, nil
+}
+
+// notifyCustomer sends the handover message of the channel's bot, if any
+func (uc *TakeoverConversationUseCase) notifyCustomer(ctx context.Context, conversation *entity.Conversation, userID string) bool {
+	if uc.sender == nil || uc.botRepo == nil {
+		return false
+	}
+	bot, err := uc.botRepo.FindByChannel(ctx, conversation.ChannelID)
+	if err != nil || bot == nil || bot.Config.HandoverMessage == "" {
+		return false
+	}
+
+	_, err = uc.sender.Execute(ctx, &SendMessageInput{
+		TenantID:       conversation.TenantID,
+		ConversationID: conversation.ID,
+		SenderID:       userID,
+		SenderType:     entity.SenderTypeSystem,
+		ContentType:    entity.ContentTypeText,
+		Content:        bot.Config.HandoverMessage,
+		Metadata:       map[string]string{"handover": "true"},
+	})
+	return err == nil
+}
+
+// publishEvent publishes a takeover or resume event
+func (uc *TakeoverConversationUseCase) publishEvent(ctx context.Context, eventType string, conversation *entity.Conversation, userID string) {
+	if uc.producer == nil {
+		return
+	}
+
+	uc.producer.PublishEvent(ctx, &nats.Event{
+		Type:     eventType,
+		TenantID: conversation.TenantID,
+		Payload: map[string]interface{}{
+			"conversation_id": conversation.ID,
+			"channel_id":      conversation.ChannelID,
+			"contact_id":      conversation.ContactID,
+			"user_id":         userID,
+			"status":          string(conversation.Status),
+		},
+		Timestamp: time.Now(),
+	})
+}
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/infrastructure/nats"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeHandoverSender struct {
+	sent []*SendMessageInput
+}
+
+func (f *fakeHandoverSender) Execute(ctx context.Context, input *SendMessageInput) (*SendMessageOutput, error) {
+	f.sent = append(f.sent, input)
+	return &SendMessageOutput{}, nil
+}
+
+type takeoverTestDeps struct {
+	conversationRepo *testutil.MockConversationRepository
+	botRepo          *mockBotRepository
+	sender           *fakeHandoverSender
+	producer         *testutil.MockProducer
+	uc               *TakeoverConversationUseCase
+}
+
+func setupTakeoverTest() *takeoverTestDeps {
+	d := &takeoverTestDeps{
+		conversationRepo: testutil.NewMockConversationRepository(),
+		botRepo:          newMockBotRepository(),
+		sender:           &fakeHandoverSender{},
+		producer:         testutil.NewMockProducer(),
+	}
+	d.conversationRepo.Conversations["conv-1"] = makeConversation("conv-1", "tenant-1", "channel-1")
+	d.botRepo.Bots["bot-1"] = &entity.Bot{
+		ID:       "bot-1",
+		TenantID: "tenant-1",
+		Status:   entity.BotStatusActive,
+		Channels: []string{"channel-1"},
+		Config:   entity.BotConfig{HandoverMessage: "You are now talking to an agent."},
+	}
+	d.uc = NewTakeoverConversationUseCase(d.conversationRepo, d.botRepo, d.sender, d.producer)
+	return d
+}
+
+func TestTakeoverConversation_Takeover(t *testing.T) {
+	d := setupTakeoverTest()
+	d.conversationRepo.Conversations["conv-1"].Status = entity.ConversationStatusPending
+
+	output, err := d.uc.Takeover(context.Background(), &TakeoverConversationInput{
+		ConversationID: "conv-1",
+		TenantID:       "tenant-1",
+		UserID:         "supervisor-1",
+	})
+	require.NoError(t, err)
+
+	conv := output.Conversation
+	assert.True(t, conv.IsBotPaused())
+	assert.Equal(t, "supervisor-1", *conv.BotPausedBy)
+	assert.Equal(t, "supervisor-1", *conv.AssignedUserID)
+	assert.Equal(t, entity.ConversationStatusOpen, conv.Status)
+
+	assert.True(t, output.CustomerNotified)
+	require.Len(t, d.sender.sent, 1)
+	assert.Equal(t, "You are now talking to an agent.", d.sender.sent[0].Content)
+	assert.Equal(t, entity.SenderTypeSystem, d.sender.sent[0].SenderType)
+
+	require.Len(t, d.producer.Events, 1)
+	assert.Equal(t, nats.EventConversationTakenOver, d.producer.Events[0].Type)
+}
+
+func TestTakeoverConversation_Takeover_WithoutNotification(t *testing.T) {
+	d := setupTakeoverTest()
+	notify := false
+
+	output, err := d.uc.Takeover(context.Background(), &TakeoverConversationInput{
+		ConversationID: "conv-1",
+		TenantID:       "tenant-1",
+		UserID:         "supervisor-1",
+		NotifyCustomer: &notify,
+	})
+	require.NoError(t, err)
+	assert.True(t, output.Conversation.IsBotPaused())
+	assert.False(t, output.CustomerNotified)
+	assert.Empty(t, d.sender.sent)
+}
+
+func TestTakeoverConversation_Takeover_OtherTenant(t *testing.T) {
+	d := setupTakeoverTest()
+
+	_, err := d.uc.Takeover(context.Background(), &TakeoverConversationInput{
+		ConversationID: "conv-1",
+		TenantID:       "tenant-2",
+		UserID:         "supervisor-1",
+	})
+	require.Error(t, err)
+	assert.Equal(t, errors.ErrCodeConversationNotFound, errors.GetAppError(err).Code)
+	assert.False(t, d.conversationRepo.Conversations["conv-1"].IsBotPaused())
+}
+
+func TestTakeoverConversation_Resume(t *testing.T) {
+	d := setupTakeoverTest()
+	ctx := context.Background()
+
+	_, err := d.uc.Resume(ctx, "tenant-1", "conv-1", "supervisor-1")
+	require.Error(t, err, "resuming a conversation the bot still handles is rejected")
+
+	_, err = d.uc.Takeover(ctx, &TakeoverConversationInput{ConversationID: "conv-1", TenantID: "tenant-1", UserID: "supervisor-1"})
+	require.NoError(t, err)
+
+	output, err := d.uc.Resume(ctx, "tenant-1", "conv-1", "supervisor-1")
+	require.NoError(t, err)
+	assert.False(t, output.Conversation.IsBotPaused())
+	assert.Nil(t, output.Conversation.AssignedUserID)
+	assert.Equal(t, nats.EventConversationBotResumed, d.producer.Events[len(d.producer.Events)-1].Type)
+}
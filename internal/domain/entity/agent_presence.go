@@ -0,0 +1,49 @@
+package entity
+
+import "time"
+
+// PresenceStatus represents an agent's availability for new conversations
+type PresenceStatus string
+
+const (
+	PresenceStatusOnline  PresenceStatus = "online"
+	PresenceStatusAway    PresenceStatus = "away"
+	PresenceStatusBusy    PresenceStatus = "busy"
+	PresenceStatusOffline PresenceStatus = "offline"
+)
+
+// IsSelectable reports whether agents may set the status themselves. Offline is
+// derived from the connection and cannot be chosen.
+func (s PresenceStatus) IsSelectable() bool {
+	switch s {
+	case PresenceStatusOnline, PresenceStatusAway, PresenceStatusBusy:
+		return true
+	}
+	return false
+}
+
+// AgentPresence is the live availability of an agent
+type AgentPresence struct {
+	UserID        string         `json:"user_id"`
+	TenantID      string         `json:"tenant_id"`
+	Name          string         `json:"name,omitempty"`
+	Email         string         `json:"email,omitempty"`
+	Status        PresenceStatus `json:"status"`
+	Connected     bool           `json:"connected"`
+	LastHeartbeat *time.Time     `json:"last_heartbeat,omitempty"`
+	LastSeen      *time.Time     `json:"last_seen,omitempty"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+}
+
+// IsAvailable reports whether the agent can take new conversations
+func (p *AgentPresence) IsAvailable() bool {
+	return p.Connected && p.Status == PresenceStatusOnline
+}
+
+// PresenceSummary counts a tenant's agents by presence status
+type PresenceSummary struct {
+	Online  int `json:"online"`
+	Away    int `json:"away"`
+	Busy    int `json:"busy"`
+	Offline int `json:"offline"`
+}
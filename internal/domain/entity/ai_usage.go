@@ -0,0 +1,140 @@
+package entity
+
+import "time"
+
+// AIFeature is the product feature an AI call was made for
+type AIFeature string
+
+const (
+	AIFeatureResponse    AIFeature = "response"    // Bot replies to customers
+	AIFeatureEmbedding   AIFeature = "embedding"   // Knowledge base embeddings and search
+	AIFeatureIntent      AIFeature = "intent"      // Intent classification
+	AIFeatureSentiment   AIFeature = "sentiment"   // Sentiment analysis
+	AIFeatureSummary     AIFeature = "summary"     // Conversation and escalation summaries
+	AIFeatureTitle       AIFeature = "title"       // Conversation titles
+	AIFeatureCopilot     AIFeature = "copilot"     // Agent reply suggestions
+	AIFeatureExtraction  AIFeature = "extraction"  // Form and document field extraction
+	AIFeatureCuration    AIFeature = "curation"    // Knowledge mined from conversations
+	AIFeatureFAQ         AIFeature = "faq"         // FAQ widget answers
+	AIFeaturePlayground  AIFeature = "playground"  // Completions requested through the API
+	AIFeatureTranslation AIFeature = "translation" // Message translations for agents
+)
+
+// AIUsageRecord is the usage and estimated cost of one AI call
+type AIUsageRecord struct {
+	ID               string         `json:"id"`
+	TenantID         string         `json:"tenant_id"`
+	BotID            string         `json:"bot_id,omitempty"`
+	Feature          AIFeature      `json:"feature"`
+	Provider         AIProviderType `json:"provider"`
+	Model            string         `json:"model"`
+	PromptTokens     int64          `json:"prompt_tokens"`
+	CompletionTokens int64          `json:"completion_tokens"`
+	CostUSD          float64        `json:"cost_usd"`
+	CreatedAt        time.Time      `json:"created_at"`
+}
+
+// TotalTokens returns the prompt and completion tokens of the call
+func (r *AIUsageRecord) TotalTokens() int64 {
+	return r.PromptTokens + r.CompletionTokens
+}
+
+// AIUsageAggregate is the monthly usage of a tenant for one bot, feature and model
+type AIUsageAggregate struct {
+	TenantID         string         `json:"tenant_id"`
+	Month            time.Time      `json:"month"`
+	BotID            string         `json:"bot_id,omitempty"`
+	Feature          AIFeature      `json:"feature"`
+	Provider         AIProviderType `json:"provider"`
+	Model            string         `json:"model"`
+	Requests         int64          `json:"requests"`
+	PromptTokens     int64          `json:"prompt_tokens"`
+	CompletionTokens int64          `json:"completion_tokens"`
+	CostUSD          float64        `json:"cost_usd"`
+}
+
+// AIUsageTotals sums AI calls of a tenant in a month, for one bot, feature or model
+// when grouped by them
+type AIUsageTotals struct {
+	Key              string  `json:"key,omitempty"` // Bot ID, feature or model of the group
+	Requests         int64   `json:"requests"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	TotalTokens      int64   `json:"total_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+}
+
+// AIUsageMonth is a tenant's AI usage and cost in one calendar month
+type AIUsageMonth struct {
+	Month time.Time `json:"month"`
+	AIUsageTotals
+}
+
+// AIBudget is a tenant's monthly AI spending limits in USD; zero means no limit.
+// Crossing the soft limit alerts the tenant, reaching the hard limit pauses AI
+// features until the month ends or the limit is raised.
+type AIBudget struct {
+	SoftLimitUSD float64 `json:"soft_limit_usd"`
+	HardLimitUSD float64 `json:"hard_limit_usd"`
+}
+
+// AIBudgetLevel is a budget limit a tenant's spending crossed
+type AIBudgetLevel string
+
+const (
+	AIBudgetSoft AIBudgetLevel = "soft"
+	AIBudgetHard AIBudgetLevel = "hard"
+)
+
+// AIBudgetStatus is a tenant's AI spending in the current month against its budget
+type AIBudgetStatus struct {
+	AIBudget
+	PeriodStart       time.Time `json:"period_start"`
+	PeriodEnd         time.Time `json:"period_end"`
+	SpentUSD          float64   `json:"spent_usd"`
+	SoftLimitExceeded bool      `json:"soft_limit_exceeded"`
+	Paused            bool      `json:"paused"` // The hard limit was reached
+}
+
+// Exceeded returns the highest budget level the spending crossed, or "" when none
+func (b AIBudget) Exceeded(spentUSD float64) AIBudgetLevel {
+	if b.HardLimitUSD > 0 && spentUSD >= b.HardLimitUSD {
+		return AIBudgetHard
+	}
+	if b.SoftLimitUSD > 0 && spentUSD >= b.SoftLimitUSD {
+		return AIBudgetSoft
+	}
+	return ""
+}
+
+// AIUsageReport is a tenant's AI usage in a month, grouped by bot, feature and model
+type AIUsageReport struct {
+	TenantID    string          `json:"tenant_id"`
+	PeriodStart time.Time       `json:"period_start"`
+	PeriodEnd   time.Time       `json:"period_end"`
+	Totals      AIUsageTotals   `json:"totals"`
+	ByBot       []AIUsageTotals `json:"by_bot"`
+	ByFeature   []AIUsageTotals `json:"by_feature"`
+	ByModel     []AIUsageTotals `json:"by_model"`
+	Budget      AIBudgetStatus  `json:"budget"`
+}
+
+// AIBudgetAlert records that a budget level alert was sent, so each level alerts
+// once per month
+type AIBudgetAlert struct {
+	TenantID    string        `json:"tenant_id"`
+	PeriodStart time.Time     `json:"period_start"`
+	Level       AIBudgetLevel `json:"level"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// AIModelPrice is the USD price of a model per million prompt and completion tokens
+type AIModelPrice struct {
+	PromptPerMillion     float64 `json:"prompt_per_million"`
+	CompletionPerMillion float64 `json:"completion_per_million"`
+}
+
+// Cost estimates the USD cost of a call
+func (p AIModelPrice) Cost(promptTokens, completionTokens int64) float64 {
+	return (float64(promptTokens)*p.PromptPerMillion + float64(completionTokens)*p.CompletionPerMillion) / 1e6
+}
@@ -73,6 +73,126 @@ type ChannelAnalytics struct {
 	ResolutionRate     float64 `json:"resolution_rate"`
 }
 
+// LabelAnalytics contains conversation metrics per label
+type LabelAnalytics struct {
+	LabelID               string  `json:"label_id"`
+	LabelName             string  `json:"label_name"`
+	LabelColor            string  `json:"label_color,omitempty"`
+	TotalConversations    int64   `json:"total_conversations"`
+	OpenConversations     int64   `json:"open_conversations"`
+	ResolvedConversations int64   `json:"resolved_conversations"`
+	AvgResolutionMinutes  float64 `json:"avg_resolution_minutes"`
+}
+
+// DispositionAnalytics contains the volume, handle time and satisfaction of the
+// conversations resolved with a disposition, to show what customers contact about.
+// Handle time runs from the first agent reply to the resolution.
+type DispositionAnalytics struct {
+	DispositionID        string  `json:"disposition_id"`
+	Code                 string  `json:"code"`
+	Name                 string  `json:"name"`
+	Category             string  `json:"category,omitempty"`
+	Conversations        int64   `json:"conversations"`
+	Share                float64 `json:"share"` // Percentage of the conversations resolved with a disposition
+	AvgHandleMinutes     float64 `json:"avg_handle_minutes"`
+	AvgResolutionMinutes float64 `json:"avg_resolution_minutes"` // From creation to resolution
+	CSATResponses        int64   `json:"csat_responses"`
+	AvgCSAT              float64 `json:"avg_csat"` // 1 to 5
+}
+
+// ReopenAnalytics measures how often resolved conversations come back, a quality
+// metric: reopens of conversations resolved in the period, whether the customer
+// wrote within the reopen window or an agent reopened them, and the follow-up
+// conversations started after the reopen window
+type ReopenAnalytics struct {
+	Resolutions           int64   `json:"resolutions"`
+	Reopens               int64   `json:"reopens"`
+	ReopenedConversations int64   `json:"reopened_conversations"`
+	ReopenRate            float64 `json:"reopen_rate"` // Percentage of resolutions that were reopened
+	CustomerReopens       int64   `json:"customer_reopens"`
+	AgentReopens          int64   `json:"agent_reopens"`
+	FollowUps             int64   `json:"follow_ups"`
+	AvgHoursToReopen      float64 `json:"avg_hours_to_reopen"`
+}
+
+// LanguageAnalytics contains conversation volume and resolution quality per
+// conversation language. Translated counts conversations routed to a
+// translation-assisted agent rather than a speaker of the language.
+type LanguageAnalytics struct {
+	Language                string  `json:"language"`
+	TotalConversations      int64   `json:"total_conversations"`
+	OpenConversations       int64   `json:"open_conversations"`
+	ResolvedConversations   int64   `json:"resolved_conversations"`
+	TranslatedConversations int64   `json:"translated_conversations"`
+	ResolutionRate          float64 `json:"resolution_rate"`
+	AvgFirstResponseMinutes float64 `json:"avg_first_response_minutes"`
+	AvgResolutionMinutes    float64 `json:"avg_resolution_minutes"`
+	ReviewedConversations   int64   `json:"reviewed_conversations"` // With a completed QA review
+	AvgQAScore              float64 `json:"avg_qa_score"`
+}
+
+// TemplateDeliveryStats contains local delivery metrics of a template's messages
+type TemplateDeliveryStats struct {
+	TemplateName string `json:"template_name"`
+	Language     string `json:"language,omitempty"`
+	Sent         int64  `json:"sent"`
+	Delivered    int64  `json:"delivered"`
+	Read         int64  `json:"read"`
+	Failed       int64  `json:"failed"`
+	Replied      int64  `json:"replied"`
+}
+
+// DeliveryRate returns the share of sent messages that were delivered
+func (s TemplateDeliveryStats) DeliveryRate() float64 {
+	return percentOf(s.Delivered, s.Sent)
+}
+
+// ReadRate returns the share of delivered messages that were read
+func (s TemplateDeliveryStats) ReadRate() float64 {
+	return percentOf(s.Read, s.Delivered)
+}
+
+// ReplyRate returns the share of delivered messages that got a reply
+func (s TemplateDeliveryStats) ReplyRate() float64 {
+	return percentOf(s.Replied, s.Delivered)
+}
+
+func percentOf(part, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
+}
+
+// Template performance flags
+const (
+	TemplateFlagQualityRed      = "quality_red"
+	TemplateFlagReadRateFalling = "read_rate_falling"
+)
+
+// TemplatePerformance combines Meta and local analytics of a template
+type TemplatePerformance struct {
+	TemplateID       string                `json:"template_id"`
+	ExternalID       string                `json:"external_id,omitempty"`
+	Name             string                `json:"name"`
+	Language         string                `json:"language"`
+	Category         TemplateCategory      `json:"category"`
+	Status           TemplateStatus        `json:"status"`
+	QualityScore     TemplateQuality       `json:"quality_score"`
+	Local            TemplateDeliveryStats `json:"local"`
+	DeliveryRate     float64               `json:"delivery_rate"`
+	ReadRate         float64               `json:"read_rate"`
+	ReplyRate        float64               `json:"reply_rate"`
+	PreviousReadRate float64               `json:"previous_read_rate"`
+	MetaSent         int64                 `json:"meta_sent,omitempty"`
+	MetaDelivered    int64                 `json:"meta_delivered,omitempty"`
+	MetaRead         int64                 `json:"meta_read,omitempty"`
+	MetaClicked      int64                 `json:"meta_clicked,omitempty"`
+	Flags            []string              `json:"flags,omitempty"`
+	SuggestPause     bool                  `json:"suggest_pause"`
+	Recommendation   string                `json:"recommendation,omitempty"`
+}
+
 // AnalyticsFilter contains filter parameters for analytics queries
 type AnalyticsFilter struct {
 	TenantID  string          `json:"tenant_id"`
@@ -1,6 +1,20 @@
 package entity
 
-import "time"
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// API key scopes are "<resource>:<access>", where resource is the first path segment of
+// a route under /api/v1 ("conversations", "contacts"...) and access is read for GET
+// and HEAD requests and write otherwise. "*" grants everything and "<resource>:*"
+// both accesses to a resource.
+const (
+	APIKeyScopeAll    = "*"
+	APIKeyAccessRead  = "read"
+	APIKeyAccessWrite = "write"
+)
 
 // APIKey represents a tenant-scoped API key. KeyHash is never exposed to clients.
 type APIKey struct {
@@ -13,5 +27,56 @@ type APIKey struct {
 	Scopes     []string   `json:"scopes"`
 	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
 	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
 	CreatedAt  time.Time  `json:"created_at"`
 }
+
+// IsActive reports whether the key can authenticate requests: it is neither revoked
+// nor expired.
+func (k *APIKey) IsActive(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+// Allows reports whether the key's scopes grant a scope.
+func (k *APIKey) Allows(scope string) bool {
+	resource, _, _ := strings.Cut(scope, ":")
+	for _, granted := range k.Scopes {
+		if granted == APIKeyScopeAll || granted == scope || granted == resource+":*" {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidAPIKeyScope reports whether a scope is "*" or "<resource>:<read|write|*>".
+func ValidAPIKeyScope(scope string) bool {
+	if scope == APIKeyScopeAll {
+		return true
+	}
+	resource, access, ok := strings.Cut(scope, ":")
+	if !ok || resource == "" {
+		return false
+	}
+	for _, r := range resource {
+		if (r < 'a' || r > 'z') && r != '-' && r != '_' {
+			return false
+		}
+	}
+	return access == APIKeyAccessRead || access == APIKeyAccessWrite || access == "*"
+}
+
+// APIKeyScopeFor returns the scope a request needs from its method and route template,
+// such as "conversations:write" for POST /api/v1/conversations/:id/messages.
+func APIKeyScopeFor(method, route string) string {
+	path := strings.TrimPrefix(strings.TrimPrefix(route, "/api/v1"), "/")
+	resource, _, _ := strings.Cut(path, "/")
+
+	access := APIKeyAccessWrite
+	if method == http.MethodGet || method == http.MethodHead {
+		access = APIKeyAccessRead
+	}
+	return resource + ":" + access
+}
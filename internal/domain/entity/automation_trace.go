@@ -0,0 +1,100 @@
+package entity
+
+import "time"
+
+// AutomationTraceSource is the automation that handled a message
+type AutomationTraceSource string
+
+const (
+	AutomationTraceSourceFlow AutomationTraceSource = "flow"
+	AutomationTraceSourceAI   AutomationTraceSource = "ai"
+)
+
+// AutomationDecision is what the automation did with a message
+type AutomationDecision string
+
+const (
+	AutomationDecisionRespond  AutomationDecision = "respond"  // Replied to the customer
+	AutomationDecisionWait     AutomationDecision = "wait"     // Asked a question and waits for the reply
+	AutomationDecisionEnd      AutomationDecision = "end"      // Ended the flow
+	AutomationDecisionEscalate AutomationDecision = "escalate" // Handed the conversation to a human
+)
+
+// FlowTraceCondition is a transition the flow engine evaluated on a node
+type FlowTraceCondition struct {
+	Condition TransitionCondition `json:"condition"`
+	Value     string              `json:"value,omitempty"`
+	ToNodeID  string              `json:"to_node_id"`
+	Matched   bool                `json:"matched"`
+}
+
+// FlowTraceStep is a node the flow engine visited while handling a message
+type FlowTraceStep struct {
+	NodeID     string               `json:"node_id"`
+	NodeType   FlowNodeType         `json:"node_type"`
+	UserInput  string               `json:"user_input,omitempty"`
+	Conditions []FlowTraceCondition `json:"conditions,omitempty"`
+	NextNodeID string               `json:"next_node_id,omitempty"`
+}
+
+// AutomationTrace is a snapshot of the flow engine or bot decision taken on a message:
+// the nodes visited and conditions evaluated, the variables at that point and the AI
+// confidence. Traces are kept so builders can replay why an automation behaved as it
+// did at any point of a conversation.
+type AutomationTrace struct {
+	ID               string                `json:"id"`
+	TenantID         string                `json:"tenant_id"`
+	ConversationID   string                `json:"conversation_id"`
+	MessageID        string                `json:"message_id,omitempty"`
+	Source           AutomationTraceSource `json:"source"`
+	FlowID           string                `json:"flow_id,omitempty"`
+	BotID            string                `json:"bot_id,omitempty"`
+	Steps            []FlowTraceStep       `json:"steps,omitempty"`
+	Variables        map[string]string     `json:"variables,omitempty"`
+	Intent           string                `json:"intent,omitempty"`
+	Sentiment        string                `json:"sentiment,omitempty"`
+	Confidence       *float64              `json:"confidence,omitempty"`
+	Model            string                `json:"model,omitempty"`
+	KnowledgeItemIDs []string              `json:"knowledge_item_ids,omitempty"`
+	Decision         AutomationDecision    `json:"decision"`
+	EscalateReason   string                `json:"escalate_reason,omitempty"`
+	CreatedAt        time.Time             `json:"created_at"`
+}
+
+// AutomationTraceFilter narrows the traces of a conversation
+type AutomationTraceFilter struct {
+	MessageID string
+	Before    *time.Time // Only traces recorded up to this time
+}
+
+// ApplyContext copies the intent, sentiment and collected variables of the
+// conversation context into the trace
+func (t *AutomationTrace) ApplyContext(convContext *ConversationContext) {
+	if convContext == nil {
+		return
+	}
+	if convContext.Intent != nil {
+		t.Intent = convContext.Intent.Name
+		if t.Confidence == nil {
+			confidence := convContext.Intent.Confidence
+			t.Confidence = &confidence
+		}
+	}
+	t.Sentiment = string(convContext.Sentiment)
+
+	// Collected data is a map[string]string in memory and a generic map once loaded
+	switch collected := convContext.State["collected_data"].(type) {
+	case map[string]string:
+		t.Variables = make(map[string]string, len(collected))
+		for key, value := range collected {
+			t.Variables[key] = value
+		}
+	case map[string]interface{}:
+		t.Variables = make(map[string]string, len(collected))
+		for key, value := range collected {
+			if s, ok := value.(string); ok {
+				t.Variables[key] = s
+			}
+		}
+	}
+}
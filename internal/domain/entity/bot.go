@@ -11,8 +11,28 @@ const (
 	BotTypeAI        BotType = "ai"
 	BotTypeRuleBased BotType = "rule_based"
 	BotTypeHybrid    BotType = "hybrid"
+	BotTypeExternal  BotType = "external" // Forwards messages to an external bot framework
 )
 
+// ExternalBotFramework represents the API an external bot speaks
+type ExternalBotFramework string
+
+const (
+	ExternalBotRasa     ExternalBotFramework = "rasa"     // Rasa REST channel webhook
+	ExternalBotBotpress ExternalBotFramework = "botpress" // Botpress converse API
+	ExternalBotCustom   ExternalBotFramework = "custom"   // Linktor's JSON request, signed with the secret
+)
+
+// ExternalBotConfig connects a bot of type external to the endpoint of a Rasa, Botpress
+// or custom bot that answers the messages of its conversations
+type ExternalBotConfig struct {
+	Framework      ExternalBotFramework `json:"framework"`
+	URL            string               `json:"url"`
+	Headers        map[string]string    `json:"headers,omitempty"`         // Sent with every request, such as an API token
+	Secret         string               `json:"secret,omitempty"`          // Signs requests in X-Linktor-Signature
+	TimeoutSeconds int                  `json:"timeout_seconds,omitempty"` // Escalates when the bot does not answer in time
+}
+
 // AIProviderType represents the AI provider
 type AIProviderType string
 
@@ -75,21 +95,24 @@ type DaySchedule struct {
 
 // BotConfig holds the bot configuration
 type BotConfig struct {
-	SystemPrompt        string           `json:"system_prompt"`
-	Temperature         float64          `json:"temperature"`
-	MaxTokens           int              `json:"max_tokens"`
-	ConfidenceThreshold float64          `json:"confidence_threshold"` // Min confidence for auto-response
-	EscalationRules     []EscalationRule `json:"escalation_rules"`
-	KnowledgeBaseID     *string          `json:"knowledge_base_id"`
-	WelcomeMessage      *string          `json:"welcome_message"`
-	FallbackMessage     string           `json:"fallback_message"`
-	WorkingHours        *WorkingHours    `json:"working_hours"`
-	ContextWindowSize   int              `json:"context_window_size"` // Number of messages to include
-	EnabledIntents      []string         `json:"enabled_intents"`     // Intents the bot can handle
-	MaxResponseLength   int              `json:"max_response_length"`
-	Tools               []*Tool          `json:"tools,omitempty"`       // Custom tools available to the bot
-	EnableVRETools      bool             `json:"enable_vre_tools"`      // Enable built-in VRE visual tools
-	ToolChoice          string           `json:"tool_choice,omitempty"` // auto, none, required
+	SystemPrompt        string                `json:"system_prompt"`
+	Temperature         float64               `json:"temperature"`
+	MaxTokens           int                   `json:"max_tokens"`
+	ConfidenceThreshold float64               `json:"confidence_threshold"` // Min confidence for auto-response
+	EscalationRules     []EscalationRule      `json:"escalation_rules"`
+	KnowledgeBaseID     *string               `json:"knowledge_base_id"`
+	WelcomeMessage      *string               `json:"welcome_message"`
+	FallbackMessage     string                `json:"fallback_message"`
+	HandoverMessage     string                `json:"handover_message,omitempty"` // Sent to the customer when an agent takes over
+	WorkingHours        *WorkingHours         `json:"working_hours"`
+	ContextWindowSize   int                   `json:"context_window_size"` // Number of messages to include
+	EnabledIntents      []string              `json:"enabled_intents"`     // Intents the bot can handle
+	MaxResponseLength   int                   `json:"max_response_length"`
+	Tools               []*Tool               `json:"tools,omitempty"`           // Custom tools available to the bot
+	EnableVRETools      bool                  `json:"enable_vre_tools"`          // Enable built-in VRE visual tools
+	ToolChoice          string                `json:"tool_choice,omitempty"`     // auto, none, required
+	AnswerFeedback      *AnswerFeedbackConfig `json:"answer_feedback,omitempty"` // Ask whether knowledge base answers helped
+	External            *ExternalBotConfig    `json:"external,omitempty"`        // Endpoint of an external bot
 }
 
 // Bot represents an AI chatbot configuration
@@ -138,6 +161,11 @@ func (b *Bot) IsActive() bool {
 	return b.Status == BotStatusActive
 }
 
+// IsExternal returns true if an external bot framework answers for the bot
+func (b *Bot) IsExternal() bool {
+	return b.Type == BotTypeExternal
+}
+
 // Activate activates the bot
 func (b *Bot) Activate() {
 	b.Status = BotStatusActive
@@ -0,0 +1,124 @@
+package entity
+
+import (
+	"hash/fnv"
+	"time"
+)
+
+// BotVersion is a saved snapshot of the prompt, model and configuration of a bot.
+// Versions are numbered per bot and can be restored or compared in an experiment.
+type BotVersion struct {
+	ID          string         `json:"id"`
+	TenantID    string         `json:"tenant_id"`
+	BotID       string         `json:"bot_id"`
+	Version     int            `json:"version"`
+	Description string         `json:"description,omitempty"`
+	Provider    AIProviderType `json:"provider"`
+	Model       string         `json:"model"`
+	Config      BotConfig      `json:"config"`
+	CreatedBy   string         `json:"created_by,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// Apply returns a copy of the bot running this version
+func (v *BotVersion) Apply(bot *Bot) *Bot {
+	applied := *bot
+	applied.Provider = v.Provider
+	applied.Model = v.Model
+	applied.Config = v.Config
+	return &applied
+}
+
+// BotExperimentStatus represents the status of a bot experiment
+type BotExperimentStatus string
+
+const (
+	BotExperimentStatusRunning BotExperimentStatus = "running"
+	BotExperimentStatusStopped BotExperimentStatus = "stopped"
+)
+
+// BotExperimentVariant is a version taking part in an experiment with its share of
+// the conversations
+type BotExperimentVariant struct {
+	VersionID string `json:"version_id"`
+	Weight    int    `json:"weight"` // Relative share of conversations
+}
+
+// BotExperiment splits the conversations of a bot between versions to compare how
+// often they escalate, get resolved and satisfy customers. A bot runs one experiment
+// at a time.
+type BotExperiment struct {
+	ID        string                 `json:"id"`
+	TenantID  string                 `json:"tenant_id"`
+	BotID     string                 `json:"bot_id"`
+	Name      string                 `json:"name"`
+	Status    BotExperimentStatus    `json:"status"`
+	Variants  []BotExperimentVariant `json:"variants"`
+	CreatedBy string                 `json:"created_by,omitempty"`
+	StartedAt time.Time              `json:"started_at"`
+	EndedAt   *time.Time             `json:"ended_at,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
+
+// IsRunning returns true if the experiment is assigning conversations
+func (e *BotExperiment) IsRunning() bool {
+	return e.Status == BotExperimentStatusRunning
+}
+
+// PickVariant returns the variant a conversation is assigned to by weight. The same
+// conversation always gets the same variant.
+func (e *BotExperiment) PickVariant(conversationID string) *BotExperimentVariant {
+	total := 0
+	for _, variant := range e.Variants {
+		total += variant.Weight
+	}
+	if total <= 0 {
+		return nil
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(e.ID + ":" + conversationID))
+	point := int(hash.Sum32() % uint32(total))
+	for i := range e.Variants {
+		point -= e.Variants[i].Weight
+		if point < 0 {
+			return &e.Variants[i]
+		}
+	}
+	return nil
+}
+
+// BotExperimentAssignment records the version a conversation was answered with in an
+// experiment and how the conversation went
+type BotExperimentAssignment struct {
+	ExperimentID   string    `json:"experiment_id"`
+	TenantID       string    `json:"tenant_id"`
+	ConversationID string    `json:"conversation_id"`
+	VersionID      string    `json:"version_id"`
+	Escalated      bool      `json:"escalated"`
+	Resolved       bool      `json:"resolved"`
+	CSAT           *int      `json:"csat,omitempty"` // Customer satisfaction score, 1 to 5
+	AssignedAt     time.Time `json:"assigned_at"`
+}
+
+// BotExperimentVariantReport holds the outcome metrics of a version in an experiment
+type BotExperimentVariantReport struct {
+	VersionID      string  `json:"version_id"`
+	Version        int     `json:"version"`
+	Description    string  `json:"description,omitempty"`
+	Weight         int     `json:"weight"`
+	Conversations  int64   `json:"conversations"`
+	Escalations    int64   `json:"escalations"`
+	EscalationRate float64 `json:"escalation_rate"`
+	Resolutions    int64   `json:"resolutions"`
+	ResolutionRate float64 `json:"resolution_rate"`
+	CSATResponses  int64   `json:"csat_responses"`
+	AverageCSAT    float64 `json:"average_csat,omitempty"`
+}
+
+// BotExperimentReport compares the versions of an experiment
+type BotExperimentReport struct {
+	Experiment *BotExperiment                `json:"experiment"`
+	Variants   []*BotExperimentVariantReport `json:"variants"`
+}
@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBotExperiment_PickVariant(t *testing.T) {
+	experiment := &BotExperiment{
+		ID: "exp-1",
+		Variants: []BotExperimentVariant{
+			{VersionID: "v1", Weight: 3},
+			{VersionID: "v2", Weight: 1},
+		},
+	}
+
+	t.Run("is sticky per conversation", func(t *testing.T) {
+		first := experiment.PickVariant("conv-1")
+		require.NotNil(t, first)
+		for i := 0; i < 5; i++ {
+			assert.Equal(t, first.VersionID, experiment.PickVariant("conv-1").VersionID)
+		}
+	})
+
+	t.Run("splits by weight", func(t *testing.T) {
+		counts := map[string]int{}
+		for i := 0; i < 4000; i++ {
+			counts[experiment.PickVariant(fmt.Sprintf("conv-%d", i)).VersionID]++
+		}
+		assert.InDelta(t, 3000, counts["v1"], 200)
+		assert.InDelta(t, 1000, counts["v2"], 200)
+	})
+
+	t.Run("no variants", func(t *testing.T) {
+		assert.Nil(t, (&BotExperiment{}).PickVariant("conv-1"))
+	})
+}
+
+func TestBotVersion_Apply(t *testing.T) {
+	bot := &Bot{ID: "bot-1", Name: "Support", Model: "gpt-4", Config: BotConfig{SystemPrompt: "Old"}}
+	version := &BotVersion{Provider: AIProviderAnthropic, Model: "claude", Config: BotConfig{SystemPrompt: "New"}}
+
+	applied := version.Apply(bot)
+	assert.Equal(t, "New", applied.Config.SystemPrompt)
+	assert.Equal(t, "claude", applied.Model)
+	assert.Equal(t, "Support", applied.Name)
+	assert.Equal(t, "Old", bot.Config.SystemPrompt)
+}
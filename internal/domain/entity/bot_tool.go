@@ -0,0 +1,59 @@
+package entity
+
+import "time"
+
+// BotTool is an HTTP endpoint a tenant registers as a tool the AI of a bot can call.
+// The AI sees its name, description and JSON Schema parameters; the arguments it
+// calls the tool with are sent to the endpoint and the response is fed back to it.
+type BotTool struct {
+	ID             string                 `json:"id"`
+	TenantID       string                 `json:"tenant_id"`
+	BotID          string                 `json:"bot_id"`
+	Name           string                 `json:"name"`
+	Description    string                 `json:"description"`
+	Parameters     map[string]interface{} `json:"parameters"` // JSON Schema of the arguments
+	Method         string                 `json:"method"`     // POST sends the arguments as a JSON body, GET as query parameters
+	URL            string                 `json:"url"`
+	Headers        map[string]string      `json:"headers,omitempty"` // Sent with every call, such as Authorization
+	TimeoutSeconds int                    `json:"timeout_seconds"`
+	Enabled        bool                   `json:"enabled"`
+	CreatedAt      time.Time              `json:"created_at"`
+	UpdatedAt      time.Time              `json:"updated_at"`
+}
+
+// ToTool returns the definition of the tool given to the AI
+func (t *BotTool) ToTool() *Tool {
+	return &Tool{
+		Name:        t.Name,
+		Description: t.Description,
+		Parameters:  t.Parameters,
+	}
+}
+
+// ToolExecutionStatus is the outcome of a tool call
+type ToolExecutionStatus string
+
+const (
+	ToolExecutionSuccess ToolExecutionStatus = "success"
+	ToolExecutionError   ToolExecutionStatus = "error"
+	ToolExecutionTimeout ToolExecutionStatus = "timeout"
+)
+
+// ToolExecution is the audit record of a tool called by the AI while answering a message
+type ToolExecution struct {
+	ID             string                 `json:"id"`
+	TenantID       string                 `json:"tenant_id"`
+	BotID          string                 `json:"bot_id"`
+	ToolID         string                 `json:"tool_id"`
+	ToolName       string                 `json:"tool_name"`
+	ConversationID string                 `json:"conversation_id,omitempty"`
+	MessageID      string                 `json:"message_id,omitempty"`
+	ToolCallID     string                 `json:"tool_call_id"`
+	Arguments      map[string]interface{} `json:"arguments"`
+	Status         ToolExecutionStatus    `json:"status"`
+	StatusCode     int                    `json:"status_code,omitempty"` // HTTP status answered by the endpoint
+	Result         string                 `json:"result,omitempty"`      // Response body given to the AI, truncated
+	Error          string                 `json:"error,omitempty"`
+	DurationMs     int64                  `json:"duration_ms"`
+	CreatedAt      time.Time              `json:"created_at"`
+}
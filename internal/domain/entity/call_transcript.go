@@ -0,0 +1,32 @@
+package entity
+
+import "time"
+
+// CallTranscript is the transcription of a recorded voice call. The transcript is
+// also posted to the caller's conversation as a message; this record keeps it
+// searchable across calls.
+type CallTranscript struct {
+	ID             string    `json:"id"`
+	TenantID       string    `json:"tenant_id"`
+	ChannelID      string    `json:"channel_id"`
+	ConversationID string    `json:"conversation_id,omitempty"` // Conversation the transcript was posted to
+	CallID         string    `json:"call_id"`
+	Direction      string    `json:"direction"`
+	From           string    `json:"from"`
+	To             string    `json:"to"`
+	Language       string    `json:"language,omitempty"`
+	Provider       string    `json:"provider"` // Speech-to-text provider that transcribed the call
+	Text           string    `json:"text"`
+	Duration       int       `json:"duration"` // in seconds
+	RecordingURL   string    `json:"recording_url,omitempty"`
+	Snippet        string    `json:"snippet,omitempty"` // Text around the search match
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// CallTranscriptFilter narrows a search of call transcripts
+type CallTranscriptFilter struct {
+	Query     string // Words the transcript must contain
+	ChannelID string
+	CallID    string
+	Phone     string // Number of either party
+}
@@ -2,6 +2,7 @@ package entity
 
 import (
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -20,6 +21,8 @@ const (
 	ChannelTypeFacebook            ChannelType = "facebook"
 	ChannelTypeEmail               ChannelType = "email"
 	ChannelTypeVoice               ChannelType = "voice"
+	ChannelTypeTeams               ChannelType = "teams"
+	ChannelTypeCustom              ChannelType = "custom"
 )
 
 // ConnectionStatus represents the connection status of a channel
@@ -83,6 +86,13 @@ type Channel struct {
 	// legacy HSM integrations or customers provisioning their own Cloud API
 	// apps do. We fetch it lazily via TemplateService.FetchNamespace.
 	MessageTemplateNamespace string `json:"message_template_namespace,omitempty"`
+
+	// CredentialRefs records the credentials resolved from a secrets backend, so
+	// they are persisted as references rather than values
+	CredentialRefs map[string]CredentialRef `json:"-"`
+
+	// CredentialHealth is the latest check of the credentials, included in API responses
+	CredentialHealth *ChannelCredentialHealth `json:"credential_health,omitempty"`
 }
 
 // NewChannel creates a new channel
@@ -102,6 +112,36 @@ func NewChannel(tenantID string, channelType ChannelType, name, identifier strin
 	}
 }
 
+// CredentialRef records a credential resolved from a secret reference
+type CredentialRef struct {
+	Reference string // secret://<backend>/<path>#<field>
+	Value     string // Value it resolved to
+}
+
+// StoredCredentials returns the credentials as they are persisted: credentials
+// resolved from a secret keep their reference unless they were replaced
+func (c *Channel) StoredCredentials() map[string]string {
+	stored := make(map[string]string, len(c.Credentials))
+	for key, value := range c.Credentials {
+		if ref, ok := c.CredentialRefs[key]; ok && ref.Value == value {
+			value = ref.Reference
+		}
+		stored[key] = value
+	}
+	return stored
+}
+
+// ReferencesSecret reports whether a stored credential points into a secret,
+// given as secret://<backend>/<path>
+func (c *Channel) ReferencesSecret(secret string) bool {
+	for _, value := range c.StoredCredentials() {
+		if value == secret || strings.HasPrefix(value, secret+"#") {
+			return true
+		}
+	}
+	return false
+}
+
 // IsEnabled returns true if the channel is enabled
 func (c *Channel) IsEnabled() bool {
 	return c.Enabled
@@ -182,6 +222,40 @@ func (c *Channel) CheckCoexistenceStatus() CoexistenceStatus {
 	}
 }
 
+// IsMarketingPaused returns true if marketing sends were paused on the channel,
+// e.g. after Meta flagged the phone number quality
+func (c *Channel) IsMarketingPaused() bool {
+	return c.Config != nil && c.Config["marketing_paused"] == "true"
+}
+
+// PauseMarketing blocks marketing sends on the channel until ResumeMarketing is called
+func (c *Channel) PauseMarketing(reason string, at time.Time) {
+	if c.Config == nil {
+		c.Config = make(map[string]string)
+	}
+	c.Config["marketing_paused"] = "true"
+	c.Config["marketing_paused_reason"] = reason
+	c.Config["marketing_paused_at"] = at.UTC().Format(time.RFC3339)
+	c.UpdatedAt = at
+}
+
+// ResumeMarketing re-enables marketing sends on the channel
+func (c *Channel) ResumeMarketing() {
+	if c.Config == nil {
+		return
+	}
+	delete(c.Config, "marketing_paused")
+	delete(c.Config, "marketing_paused_reason")
+	delete(c.Config, "marketing_paused_at")
+	c.UpdatedAt = time.Now()
+}
+
+// IsLabelSyncEnabled returns true if WhatsApp Business App labels of a coexistence
+// channel are synced to contact tags. Sync is on unless label_sync is "false".
+func (c *Channel) IsLabelSyncEnabled() bool {
+	return c.IsCoexistenceChannel() && (c.Config == nil || c.Config["label_sync"] != "false")
+}
+
 // AdvancedSettings represents configurable per-channel behavior settings
 type AdvancedSettings struct {
 	AlwaysOnline     bool   `json:"always_online"`      // Show online status always
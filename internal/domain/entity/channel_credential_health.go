@@ -0,0 +1,33 @@
+package entity
+
+import "time"
+
+// ChannelCredentialStatus is the outcome of validating a channel's stored credentials
+type ChannelCredentialStatus string
+
+const (
+	ChannelCredentialsHealthy  ChannelCredentialStatus = "healthy"
+	ChannelCredentialsExpiring ChannelCredentialStatus = "expiring" // Accepted, but the token expires soon
+	ChannelCredentialsFailing  ChannelCredentialStatus = "failing"  // Rejected by the provider, or it could not be reached
+)
+
+// ChannelCredentialHealth is the latest validation of a channel's stored credentials
+// with its provider, run daily so credentials that stopped working or are about to
+// expire are noticed before messages fail
+type ChannelCredentialHealth struct {
+	ChannelID    string                  `json:"channel_id"`
+	TenantID     string                  `json:"tenant_id"`
+	Status       ChannelCredentialStatus `json:"status"`
+	Account      string                  `json:"account,omitempty"`    // What the credentials belong to, as the provider describes it
+	ExpiresAt    *time.Time              `json:"expires_at,omitempty"` // Nil when the credentials do not expire or it is unknown
+	LastError    string                  `json:"last_error,omitempty"`
+	Failures     int                     `json:"failures"` // Consecutive failed checks
+	FailingSince *time.Time              `json:"failing_since,omitempty"`
+	CheckedAt    time.Time               `json:"checked_at"`
+	UpdatedAt    time.Time               `json:"updated_at"`
+}
+
+// Flagged reports whether the credentials need attention
+func (h *ChannelCredentialHealth) Flagged() bool {
+	return h.Status != ChannelCredentialsHealthy
+}
@@ -0,0 +1,36 @@
+package entity
+
+import "time"
+
+// ChannelLease grants one server instance the right to hold a channel's live session.
+// The owner renews it while healthy; once it expires a standby instance may take over.
+type ChannelLease struct {
+	ChannelID  string    `json:"channel_id"`
+	TenantID   string    `json:"tenant_id"`
+	OwnerID    string    `json:"owner_id"`
+	AcquiredAt time.Time `json:"acquired_at"`
+	RenewedAt  time.Time `json:"renewed_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// ChannelFailoverEventType is the kind of ownership change recorded for a channel
+type ChannelFailoverEventType string
+
+const (
+	ChannelFailoverAcquired ChannelFailoverEventType = "acquired" // claimed a channel nobody held
+	ChannelFailoverTakeover ChannelFailoverEventType = "takeover" // claimed after another owner's lease expired
+	ChannelFailoverReleased ChannelFailoverEventType = "released" // gave the channel up, e.g. on shutdown
+	ChannelFailoverLost     ChannelFailoverEventType = "lost"     // found the lease held by another instance
+)
+
+// ChannelFailoverEvent is the audit record of a channel ownership change
+type ChannelFailoverEvent struct {
+	ID              string                   `json:"id"`
+	TenantID        string                   `json:"tenant_id"`
+	ChannelID       string                   `json:"channel_id"`
+	Type            ChannelFailoverEventType `json:"type"`
+	OwnerID         string                   `json:"owner_id"`
+	PreviousOwnerID string                   `json:"previous_owner_id,omitempty"`
+	Reason          string                   `json:"reason,omitempty"`
+	CreatedAt       time.Time                `json:"created_at"`
+}
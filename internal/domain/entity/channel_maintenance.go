@@ -0,0 +1,43 @@
+package entity
+
+import "time"
+
+// ChannelMaintenanceWindow is a period declared by a tenant during which a channel is
+// not staffed: contacts writing in get an automatic notice, conversations wait in the
+// queue instead of being routed, agent timers stop and newsletters are held back
+type ChannelMaintenanceWindow struct {
+	ID        string    `json:"id"`
+	TenantID  string    `json:"tenant_id"`
+	ChannelID string    `json:"channel_id"`
+	Title     string    `json:"title"`
+	Notice    string    `json:"notice"` // Sent once per conversation to contacts writing during the window
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// IsActive reports whether the window is ongoing at the given time
+func (w *ChannelMaintenanceWindow) IsActive(now time.Time) bool {
+	return !now.Before(w.StartsAt) && now.Before(w.EndsAt)
+}
+
+// IsOver reports whether the window ended before the given time
+func (w *ChannelMaintenanceWindow) IsOver(now time.Time) bool {
+	return !now.Before(w.EndsAt)
+}
+
+// Overlap returns how much of the period from..to falls within the window
+func (w *ChannelMaintenanceWindow) Overlap(from, to time.Time) time.Duration {
+	if from.Before(w.StartsAt) {
+		from = w.StartsAt
+	}
+	if to.After(w.EndsAt) {
+		to = w.EndsAt
+	}
+	if !to.After(from) {
+		return 0
+	}
+	return to.Sub(from)
+}
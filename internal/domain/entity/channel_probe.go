@@ -0,0 +1,61 @@
+package entity
+
+import "time"
+
+// ChannelProbeTrigger is what caused a channel to be probed
+type ChannelProbeTrigger string
+
+const (
+	ChannelProbeTriggerCreate  ChannelProbeTrigger = "create"  // The channel was created
+	ChannelProbeTriggerConnect ChannelProbeTrigger = "connect" // The channel was connected
+	ChannelProbeTriggerManual  ChannelProbeTrigger = "manual"  // Requested through the API
+)
+
+// ChannelProbeStatus is the outcome of a probe or one of its checks
+type ChannelProbeStatus string
+
+const (
+	ChannelProbePassed  ChannelProbeStatus = "passed"
+	ChannelProbeFailed  ChannelProbeStatus = "failed"
+	ChannelProbeSkipped ChannelProbeStatus = "skipped" // The check does not apply or is not configured
+)
+
+// ChannelProbeCheckName names a capability checked by a probe
+type ChannelProbeCheckName string
+
+const (
+	ChannelProbeCheckCredentials ChannelProbeCheckName = "credentials" // Credentials are accepted and hold the required scopes
+	ChannelProbeCheckWebhook     ChannelProbeCheckName = "webhook"     // The channel's webhook URL can be reached from outside
+	ChannelProbeCheckTestSend    ChannelProbeCheckName = "test_send"   // A test message reaches the sandbox recipient
+)
+
+// ChannelProbeCheck is the result of one check of a probe
+type ChannelProbeCheck struct {
+	Name       ChannelProbeCheckName `json:"name"`
+	Status     ChannelProbeStatus    `json:"status"`
+	Detail     string                `json:"detail,omitempty"` // What was found, or why the check failed or was skipped
+	DurationMs int64                 `json:"duration_ms"`
+}
+
+// ChannelProbe is the latest capability probe of a channel. Probes run when a channel
+// is created or connected so misconfigurations show at setup rather than on the
+// first customer message.
+type ChannelProbe struct {
+	ChannelID string              `json:"channel_id"`
+	TenantID  string              `json:"tenant_id"`
+	Status    ChannelProbeStatus  `json:"status"` // Failed when any check failed
+	Checks    []ChannelProbeCheck `json:"checks"`
+	Trigger   ChannelProbeTrigger `json:"trigger"`
+	ProbedAt  time.Time           `json:"probed_at"`
+}
+
+// Failed returns the checks that failed
+func (p *ChannelProbe) Failed() []ChannelProbeCheck {
+	var failed []ChannelProbeCheck
+	for _, check := range p.Checks {
+		if check.Status == ChannelProbeFailed {
+			failed = append(failed, check)
+		}
+	}
+	return failed
+}
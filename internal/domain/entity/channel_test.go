@@ -116,3 +116,24 @@ func TestChannel_SetAdvancedSettings_AllFields(t *testing.T) {
 	assert.Equal(t, "admin", ch.Config["proxy_user"])
 	assert.Equal(t, "secret", ch.Config["proxy_pass"])
 }
+
+func TestChannel_StoredCredentials(t *testing.T) {
+	ch := NewChannel("t1", ChannelTypeWhatsAppOfficial, "test", "123")
+	ch.Credentials["access_token"] = "token-v1"
+	ch.Credentials["app_secret"] = "replaced"
+	ch.Credentials["verify_token"] = "plain"
+	ch.CredentialRefs = map[string]CredentialRef{
+		"access_token": {Reference: "secret://vault/meta#token", Value: "token-v1"},
+		"app_secret":   {Reference: "secret://vault/meta#app_secret", Value: "app-v1"},
+	}
+
+	assert.Equal(t, map[string]string{
+		"access_token": "secret://vault/meta#token",
+		"app_secret":   "replaced",
+		"verify_token": "plain",
+	}, ch.StoredCredentials(), "resolved values are stored as their reference unless replaced")
+
+	assert.True(t, ch.ReferencesSecret("secret://vault/meta"))
+	assert.False(t, ch.ReferencesSecret("secret://vault/me"))
+	assert.False(t, ch.ReferencesSecret("secret://aws/meta"))
+}
@@ -0,0 +1,32 @@
+package entity
+
+import "time"
+
+// ChannelTokenStatus is the state of the provider access token of a channel
+type ChannelTokenStatus string
+
+const (
+	ChannelTokenValid         ChannelTokenStatus = "valid"          // Accepted by the provider
+	ChannelTokenRefreshFailed ChannelTokenStatus = "refresh_failed" // Still valid, but the last refresh failed
+	ChannelTokenExpired       ChannelTokenStatus = "expired"        // Expired or revoked; the channel was disabled
+)
+
+// ChannelToken tracks the lifecycle of the long-lived access token of a Facebook,
+// Instagram or WhatsApp channel so it is refreshed before Meta expires it
+type ChannelToken struct {
+	ChannelID   string             `json:"channel_id"`
+	TenantID    string             `json:"tenant_id"`
+	Fingerprint string             `json:"-"` // Digest of the tracked token, to notice when it is replaced
+	Status      ChannelTokenStatus `json:"status"`
+	ExpiresAt   *time.Time         `json:"expires_at,omitempty"` // Nil when the token never expires
+	CheckedAt   time.Time          `json:"checked_at"`           // Last inspected with the provider
+	RefreshedAt *time.Time         `json:"refreshed_at,omitempty"`
+	Failures    int                `json:"failures"` // Consecutive failed refreshes
+	LastError   string             `json:"last_error,omitempty"`
+	UpdatedAt   time.Time          `json:"updated_at"`
+}
+
+// ExpiresWithin reports whether the token expires less than d after now
+func (t *ChannelToken) ExpiresWithin(d time.Duration, now time.Time) bool {
+	return t.ExpiresAt != nil && t.ExpiresAt.Sub(now) < d
+}
@@ -0,0 +1,134 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// ComplianceCheck is how a compliance rule examines content
+type ComplianceCheck string
+
+const (
+	ComplianceCheckProhibitedTerms ComplianceCheck = "prohibited_terms" // Content must not contain any of the terms
+	ComplianceCheckRequiredText    ComplianceCheck = "required_text"    // Content must contain one of the terms
+)
+
+// IsValid checks if the check is known
+func (c ComplianceCheck) IsValid() bool {
+	return c == ComplianceCheckProhibitedTerms || c == ComplianceCheckRequiredText
+}
+
+// ComplianceAction is what happens to content breaking a rule
+type ComplianceAction string
+
+const (
+	ComplianceActionBlock ComplianceAction = "block" // Submission is refused unless an authorized user overrides it
+	ComplianceActionWarn  ComplianceAction = "warn"  // Submission goes through with a warning
+)
+
+// IsValid checks if the action is known
+func (a ComplianceAction) IsValid() bool {
+	return a == ComplianceActionBlock || a == ComplianceActionWarn
+}
+
+// ComplianceTargetType is the kind of outbound marketing content being submitted
+type ComplianceTargetType string
+
+const (
+	ComplianceTargetTemplate   ComplianceTargetType = "template"
+	ComplianceTargetNewsletter ComplianceTargetType = "newsletter"
+)
+
+// ComplianceRule is a rule outbound marketing content is checked against before it is
+// submitted. Rules can be limited to a country, channel types and, for WhatsApp,
+// template categories. Built-in rules apply to every tenant.
+type ComplianceRule struct {
+	ID                 string             `json:"id"`
+	TenantID           string             `json:"tenant_id,omitempty"` // Empty for built-in rules
+	Name               string             `json:"name"`                // What the rule protects against, such as a prohibited category
+	Check              ComplianceCheck    `json:"check"`
+	Terms              []string           `json:"terms"`                         // Matched as whole words, ignoring case
+	Country            string             `json:"country,omitempty"`             // ISO 3166-1 alpha-2 code; every country when empty
+	ChannelTypes       []ChannelType      `json:"channel_types,omitempty"`       // Every channel type when empty
+	TemplateCategories []TemplateCategory `json:"template_categories,omitempty"` // Only templates of these categories when set
+	Action             ComplianceAction   `json:"action"`
+	Message            string             `json:"message"` // Shown when content breaks the rule
+	Enabled            bool               `json:"enabled"`
+	BuiltIn            bool               `json:"built_in"`
+	CreatedAt          time.Time          `json:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
+}
+
+// AppliesTo checks if the rule covers content for a country and channel type.
+// Rules limited to template categories only cover templates.
+func (r *ComplianceRule) AppliesTo(country string, channelType ChannelType, category TemplateCategory) bool {
+	if !r.Enabled {
+		return false
+	}
+	if r.Country != "" && !strings.EqualFold(r.Country, country) {
+		return false
+	}
+	if len(r.ChannelTypes) > 0 && !containsChannelType(r.ChannelTypes, channelType) {
+		return false
+	}
+	if len(r.TemplateCategories) > 0 {
+		for _, c := range r.TemplateCategories {
+			if c == category {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func containsChannelType(types []ChannelType, channelType ChannelType) bool {
+	for _, t := range types {
+		if t == channelType {
+			return true
+		}
+	}
+	return false
+}
+
+// ComplianceFinding is a rule broken by content
+type ComplianceFinding struct {
+	RuleID   string           `json:"rule_id"`
+	RuleName string           `json:"rule_name"`
+	Action   ComplianceAction `json:"action"`
+	Message  string           `json:"message"`
+	Match    string           `json:"match,omitempty"` // Prohibited term found in the content
+}
+
+// ComplianceReport is the result of checking content against the compliance rules
+type ComplianceReport struct {
+	Country    string              `json:"country,omitempty"`
+	Findings   []ComplianceFinding `json:"findings"`
+	Blocked    bool                `json:"blocked"`              // A blocking rule was broken
+	Overridden bool                `json:"overridden,omitempty"` // The block was overridden by an authorized user
+}
+
+// Warnings returns the findings that do not block submission
+func (r *ComplianceReport) Warnings() []ComplianceFinding {
+	var warnings []ComplianceFinding
+	for _, finding := range r.Findings {
+		if finding.Action == ComplianceActionWarn {
+			warnings = append(warnings, finding)
+		}
+	}
+	return warnings
+}
+
+// ComplianceOverride records content submitted despite breaking blocking rules,
+// who allowed it and why
+type ComplianceOverride struct {
+	ID         string               `json:"id"`
+	TenantID   string               `json:"tenant_id"`
+	TargetType ComplianceTargetType `json:"target_type"`
+	TargetID   string               `json:"target_id"`
+	TargetName string               `json:"target_name"`
+	UserID     string               `json:"user_id"`
+	Reason     string               `json:"reason"`
+	Findings   []ComplianceFinding  `json:"findings"`
+	CreatedAt  time.Time            `json:"created_at"`
+}
@@ -0,0 +1,126 @@
+package entity
+
+import "time"
+
+// ConfigBundleVersion is the format version of configuration bundles
+const ConfigBundleVersion = 1
+
+// ConfigRedacted replaces secret values in exported bundles. On import a
+// redacted value keeps the value already configured in the target tenant.
+const ConfigRedacted = "<redacted>"
+
+// ConfigSection names a part of the tenant configuration
+type ConfigSection string
+
+const (
+	ConfigSectionChannels     ConfigSection = "channels"
+	ConfigSectionBots         ConfigSection = "bots"
+	ConfigSectionFlows        ConfigSection = "flows"
+	ConfigSectionMessageHooks ConfigSection = "message_hooks"
+)
+
+// AllConfigSections lists the sections in the order they are applied
+var AllConfigSections = []ConfigSection{
+	ConfigSectionChannels,
+	ConfigSectionBots,
+	ConfigSectionFlows,
+	ConfigSectionMessageHooks,
+}
+
+// ConfigBundle is a versionable snapshot of a tenant configuration. Items are
+// identified by name and reference each other by name, so a bundle exported
+// from one environment can be applied to another.
+type ConfigBundle struct {
+	Version      int                 `json:"version"`
+	ExportedAt   time.Time           `json:"exported_at"`
+	Sections     []ConfigSection     `json:"sections"`
+	Channels     []ConfigChannel     `json:"channels,omitempty"`
+	Bots         []ConfigBot         `json:"bots,omitempty"`
+	Flows        []ConfigFlow        `json:"flows,omitempty"`
+	MessageHooks []ConfigMessageHook `json:"message_hooks,omitempty"`
+}
+
+// HasSection checks if the bundle carries a section
+func (b *ConfigBundle) HasSection(section ConfigSection) bool {
+	for _, s := range b.Sections {
+		if s == section {
+			return true
+		}
+	}
+	return false
+}
+
+// ConfigChannel is a channel without its credentials. Imported channels are
+// created disabled until credentials are configured in the target tenant.
+type ConfigChannel struct {
+	Name          string            `json:"name"`
+	Type          ChannelType       `json:"type"`
+	Config        map[string]string `json:"config,omitempty"`
+	IsCoexistence bool              `json:"is_coexistence,omitempty"`
+}
+
+// ConfigBot is a bot with its channels referenced by name
+type ConfigBot struct {
+	Name     string         `json:"name"`
+	Type     BotType        `json:"type"`
+	Provider AIProviderType `json:"provider"`
+	Model    string         `json:"model"`
+	Config   BotConfig      `json:"config"`
+	Status   BotStatus      `json:"status"`
+	Channels []string       `json:"channels,omitempty"`
+}
+
+// ConfigFlow is a flow with its bot referenced by name
+type ConfigFlow struct {
+	Name         string          `json:"name"`
+	Description  string          `json:"description,omitempty"`
+	Bot          string          `json:"bot,omitempty"`
+	Trigger      FlowTriggerType `json:"trigger"`
+	TriggerValue string          `json:"trigger_value,omitempty"`
+	StartNodeID  string          `json:"start_node_id"`
+	Nodes        []FlowNode      `json:"nodes"`
+	IsActive     bool            `json:"is_active"`
+	Priority     int             `json:"priority"`
+}
+
+// ConfigMessageHook is a message hook with its channels referenced by name
+type ConfigMessageHook struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Language    HookLanguage `json:"language"`
+	Script      string       `json:"script"`
+	Events      []HookEvent  `json:"events"`
+	Channels    []string     `json:"channels,omitempty"`
+	Lookups     []HookLookup `json:"lookups,omitempty"`
+	TimeoutMs   int          `json:"timeout_ms"`
+	MemoryMB    int          `json:"memory_mb"`
+	Priority    int          `json:"priority"`
+	IsActive    bool         `json:"is_active"`
+}
+
+// ConfigChangeAction is what applying a bundle does to an item
+type ConfigChangeAction string
+
+const (
+	ConfigChangeCreate    ConfigChangeAction = "create"
+	ConfigChangeUpdate    ConfigChangeAction = "update"
+	ConfigChangeUnchanged ConfigChangeAction = "unchanged"
+)
+
+// ConfigChange describes the effect of a bundle on one item
+type ConfigChange struct {
+	Section ConfigSection      `json:"section"`
+	Name    string             `json:"name"`
+	Action  ConfigChangeAction `json:"action"`
+	Fields  []string           `json:"fields,omitempty"` // Top-level fields that differ on update
+}
+
+// ConfigPlan is the preview of applying a bundle to a tenant
+type ConfigPlan struct {
+	Changes   []ConfigChange `json:"changes"`
+	Errors    []string       `json:"errors,omitempty"`
+	Creates   int            `json:"creates"`
+	Updates   int            `json:"updates"`
+	Unchanged int            `json:"unchanged"`
+	Applied   bool           `json:"applied"`
+}
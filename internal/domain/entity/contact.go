@@ -24,11 +24,34 @@ type Contact struct {
 	AvatarURL    string             `json:"avatar_url,omitempty"`
 	CustomFields map[string]string  `json:"custom_fields,omitempty"`
 	Tags         []string           `json:"tags,omitempty"`
+	Addresses    []ContactAddress   `json:"addresses,omitempty"`
 	Identities   []*ContactIdentity `json:"identities,omitempty"`
 	CreatedAt    time.Time          `json:"created_at"`
 	UpdatedAt    time.Time          `json:"updated_at"`
 }
 
+// Sources of contact addresses
+const (
+	ContactAddressSourceAddressMessage = "address_message" // WhatsApp address message reply
+	ContactAddressSourceLocation       = "location"        // Shared location
+)
+
+// ContactAddress is a delivery address or location a contact shared in a conversation
+type ContactAddress struct {
+	Source     string    `json:"source"`
+	Name       string    `json:"name,omitempty"`
+	Phone      string    `json:"phone,omitempty"`
+	Street     string    `json:"street,omitempty"` // House, floor, building and street
+	Landmark   string    `json:"landmark,omitempty"`
+	City       string    `json:"city,omitempty"`
+	State      string    `json:"state,omitempty"`
+	PostalCode string    `json:"postal_code,omitempty"`
+	Country    string    `json:"country,omitempty"` // ISO 3166-1 alpha-2 code
+	Latitude   *float64  `json:"latitude,omitempty"`
+	Longitude  *float64  `json:"longitude,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
 // NewContact creates a new contact
 func NewContact(tenantID string) *Contact {
 	now := time.Now()
@@ -58,6 +81,17 @@ func (c *Contact) GetIdentityByChannel(channelType string) *ContactIdentity {
 	return nil
 }
 
+// SetAddress stores an address, replacing the previous one from the same source
+func (c *Contact) SetAddress(address ContactAddress) {
+	for i := range c.Addresses {
+		if c.Addresses[i].Source == address.Source {
+			c.Addresses[i] = address
+			return
+		}
+	}
+	c.Addresses = append(c.Addresses, address)
+}
+
 // HasTag checks if the contact has a specific tag
 func (c *Contact) HasTag(tag string) bool {
 	for _, t := range c.Tags {
@@ -118,3 +152,20 @@ func (c *Contact) GetBlockedAt() *time.Time {
 	}
 	return &t
 }
+
+// IsOptedOut returns true if the contact opted out of messages on a channel type
+func (c *Contact) IsOptedOut(channelType string) bool {
+	if c.CustomFields == nil {
+		return false
+	}
+	return c.CustomFields["_opted_out_"+channelType] != ""
+}
+
+// OptOut marks the contact as opted out of messages on a channel type
+func (c *Contact) OptOut(channelType string) {
+	if c.CustomFields == nil {
+		c.CustomFields = make(map[string]string)
+	}
+	c.CustomFields["_opted_out_"+channelType] = time.Now().UTC().Format(time.RFC3339)
+	c.UpdatedAt = time.Now()
+}
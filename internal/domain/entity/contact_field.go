@@ -0,0 +1,183 @@
+package entity
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ContactFieldType is the type of the values a custom contact field holds
+type ContactFieldType string
+
+const (
+	ContactFieldText    ContactFieldType = "text"
+	ContactFieldNumber  ContactFieldType = "number"
+	ContactFieldBoolean ContactFieldType = "boolean" // Stored as "true" or "false"
+	ContactFieldDate    ContactFieldType = "date"    // Stored as YYYY-MM-DD
+	ContactFieldSelect  ContactFieldType = "select"  // One of the field's options
+	ContactFieldEmail   ContactFieldType = "email"
+	ContactFieldURL     ContactFieldType = "url" // http or https
+)
+
+const contactFieldDateLayout = "2006-01-02"
+
+var contactFieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// ContactField defines a custom attribute tenants keep on their contacts. Values are
+// stored in the contact's custom fields under the field's key.
+type ContactField struct {
+	ID          string           `json:"id"`
+	TenantID    string           `json:"tenant_id"`
+	Key         string           `json:"key"` // Lowercase letters, digits and underscores
+	Label       string           `json:"label"`
+	Description string           `json:"description,omitempty"`
+	Type        ContactFieldType `json:"type"`
+	Required    bool             `json:"required"`
+	Options     []string         `json:"options,omitempty"` // Allowed values of select fields
+	Pattern     string           `json:"pattern,omitempty"` // Regular expression text values must match
+	Min         *float64         `json:"min,omitempty"`     // Minimum number, or minimum length of text
+	Max         *float64         `json:"max,omitempty"`     // Maximum number, or maximum length of text
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// Validate checks the field's key, type and validation settings
+func (f *ContactField) Validate() error {
+	if !contactFieldKeyPattern.MatchString(f.Key) {
+		return fmt.Errorf("key must start with a lowercase letter and contain only lowercase letters, digits and underscores")
+	}
+	if strings.TrimSpace(f.Label) == "" {
+		return fmt.Errorf("label is required")
+	}
+
+	switch f.Type {
+	case ContactFieldText, ContactFieldNumber, ContactFieldBoolean, ContactFieldDate, ContactFieldEmail, ContactFieldURL:
+		if len(f.Options) > 0 {
+			return fmt.Errorf("options are only supported for select fields")
+		}
+	case ContactFieldSelect:
+		if len(f.Options) == 0 {
+			return fmt.Errorf("select fields require options")
+		}
+	default:
+		return fmt.Errorf("invalid field type %q", f.Type)
+	}
+
+	if f.Pattern != "" {
+		if f.Type != ContactFieldText {
+			return fmt.Errorf("pattern is only supported for text fields")
+		}
+		if _, err := regexp.Compile(f.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+	if (f.Min != nil || f.Max != nil) && f.Type != ContactFieldText && f.Type != ContactFieldNumber {
+		return fmt.Errorf("min and max are only supported for text and number fields")
+	}
+	if f.Min != nil && f.Max != nil && *f.Min > *f.Max {
+		return fmt.Errorf("min cannot be greater than max")
+	}
+	return nil
+}
+
+// NormalizeValue checks a value against the field and returns it in the form it is
+// stored in
+func (f *ContactField) NormalizeValue(value string) (string, error) {
+	value = strings.TrimSpace(value)
+
+	switch f.Type {
+	case ContactFieldText:
+		length := float64(len([]rune(value)))
+		if f.Min != nil && length < *f.Min {
+			return "", fmt.Errorf("%s must have at least %v characters", f.Key, *f.Min)
+		}
+		if f.Max != nil && length > *f.Max {
+			return "", fmt.Errorf("%s must have at most %v characters", f.Key, *f.Max)
+		}
+		if f.Pattern != "" {
+			if matched, _ := regexp.MatchString(f.Pattern, value); !matched {
+				return "", fmt.Errorf("%s does not match the required format", f.Key)
+			}
+		}
+		return value, nil
+
+	case ContactFieldNumber:
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return "", fmt.Errorf("%s must be a number", f.Key)
+		}
+		if f.Min != nil && number < *f.Min {
+			return "", fmt.Errorf("%s must be at least %v", f.Key, *f.Min)
+		}
+		if f.Max != nil && number > *f.Max {
+			return "", fmt.Errorf("%s must be at most %v", f.Key, *f.Max)
+		}
+		return strconv.FormatFloat(number, 'f', -1, 64), nil
+
+	case ContactFieldBoolean:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("%s must be true or false", f.Key)
+		}
+		return strconv.FormatBool(b), nil
+
+	case ContactFieldDate:
+		if t, err := time.Parse(contactFieldDateLayout, value); err == nil {
+			return t.Format(contactFieldDateLayout), nil
+		}
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t.Format(contactFieldDateLayout), nil
+		}
+		return "", fmt.Errorf("%s must be a date in YYYY-MM-DD format", f.Key)
+
+	case ContactFieldSelect:
+		for _, option := range f.Options {
+			if option == value {
+				return value, nil
+			}
+		}
+		return "", fmt.Errorf("%s must be one of %s", f.Key, strings.Join(f.Options, ", "))
+
+	case ContactFieldEmail:
+		address, err := mail.ParseAddress(value)
+		if err != nil || address.Address != value {
+			return "", fmt.Errorf("%s must be an email address", f.Key)
+		}
+		return strings.ToLower(value), nil
+
+	case ContactFieldURL:
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "", fmt.Errorf("%s must be an http or https URL", f.Key)
+		}
+		return value, nil
+	}
+	return "", fmt.Errorf("%s has an invalid type", f.Key)
+}
+
+// ApplyContactFields checks custom field values against the tenant's field definitions,
+// normalizing the values of defined fields in place and dropping their empty values.
+// Keys without a definition are kept as they are, since integrations and internal
+// flags store their own attributes.
+func ApplyContactFields(fields []*ContactField, values map[string]string) error {
+	for _, field := range fields {
+		value, ok := values[field.Key]
+		if !ok || strings.TrimSpace(value) == "" {
+			delete(values, field.Key)
+			if field.Required {
+				return fmt.Errorf("%s is required", field.Key)
+			}
+			continue
+		}
+		normalized, err := field.NormalizeValue(value)
+		if err != nil {
+			return err
+		}
+		values[field.Key] = normalized
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContactField_Validate(t *testing.T) {
+	min, max := 10.0, 1.0
+	invalid := []ContactField{
+		{Key: "Plan", Label: "Plan", Type: ContactFieldText},
+		{Key: "plan", Type: ContactFieldText},
+		{Key: "plan", Label: "Plan", Type: "color"},
+		{Key: "plan", Label: "Plan", Type: ContactFieldSelect},
+		{Key: "plan", Label: "Plan", Type: ContactFieldText, Options: []string{"pro"}},
+		{Key: "plan", Label: "Plan", Type: ContactFieldNumber, Pattern: "^[0-9]+$"},
+		{Key: "plan", Label: "Plan", Type: ContactFieldText, Pattern: "("},
+		{Key: "plan", Label: "Plan", Type: ContactFieldNumber, Min: &min, Max: &max},
+		{Key: "plan", Label: "Plan", Type: ContactFieldDate, Min: &min},
+	}
+	for _, field := range invalid {
+		assert.Error(t, field.Validate(), "%+v", field)
+	}
+
+	valid := ContactField{Key: "plan_2", Label: "Plan", Type: ContactFieldSelect, Options: []string{"free", "pro"}}
+	assert.NoError(t, valid.Validate())
+}
+
+func TestContactField_NormalizeValue(t *testing.T) {
+	min, max := 1.0, 5.0
+	tests := []struct {
+		field    ContactField
+		value    string
+		expected string
+		valid    bool
+	}{
+		{ContactField{Key: "n", Type: ContactFieldNumber, Min: &min, Max: &max}, " 3.50 ", "3.5", true},
+		{ContactField{Key: "n", Type: ContactFieldNumber, Min: &min, Max: &max}, "7", "", false},
+		{ContactField{Key: "n", Type: ContactFieldNumber}, "many", "", false},
+		{ContactField{Key: "b", Type: ContactFieldBoolean}, "1", "true", true},
+		{ContactField{Key: "b", Type: ContactFieldBoolean}, "yes", "", false},
+		{ContactField{Key: "d", Type: ContactFieldDate}, "2026-03-01T10:00:00Z", "2026-03-01", true},
+		{ContactField{Key: "d", Type: ContactFieldDate}, "01/03/2026", "", false},
+		{ContactField{Key: "s", Type: ContactFieldSelect, Options: []string{"free", "pro"}}, "pro", "pro", true},
+		{ContactField{Key: "s", Type: ContactFieldSelect, Options: []string{"free", "pro"}}, "gold", "", false},
+		{ContactField{Key: "e", Type: ContactFieldEmail}, "Ana@Example.com", "ana@example.com", true},
+		{ContactField{Key: "e", Type: ContactFieldEmail}, "Ana <ana@example.com>", "", false},
+		{ContactField{Key: "u", Type: ContactFieldURL}, "ftp://example.com", "", false},
+		{ContactField{Key: "t", Type: ContactFieldText, Pattern: `^[A-Z]{2}\d{4}$`}, "AB1234", "AB1234", true},
+		{ContactField{Key: "t", Type: ContactFieldText, Max: &max}, "too long", "", false},
+	}
+	for _, tt := range tests {
+		normalized, err := tt.field.NormalizeValue(tt.value)
+		if !tt.valid {
+			assert.Error(t, err, "%s %q", tt.field.Type, tt.value)
+			continue
+		}
+		require.NoError(t, err, "%s %q", tt.field.Type, tt.value)
+		assert.Equal(t, tt.expected, normalized)
+	}
+}
+
+func TestApplyContactFields(t *testing.T) {
+	fields := []*ContactField{
+		{Key: "plan", Type: ContactFieldSelect, Options: []string{"free", "pro"}, Required: true},
+		{Key: "vip", Type: ContactFieldBoolean},
+	}
+
+	values := map[string]string{"plan": "pro", "vip": " ", "_blocked": "true", "legacy": "x"}
+	require.NoError(t, ApplyContactFields(fields, values))
+	assert.Equal(t, map[string]string{"plan": "pro", "_blocked": "true", "legacy": "x"}, values)
+
+	assert.EqualError(t, ApplyContactFields(fields, map[string]string{"vip": "true"}), "plan is required")
+	assert.Error(t, ApplyContactFields(fields, map[string]string{"plan": "pro", "vip": "maybe"}))
+}
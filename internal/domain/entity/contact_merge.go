@@ -0,0 +1,105 @@
+package entity
+
+import (
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// ContactMatchReason is why two contacts were considered the same person
+type ContactMatchReason string
+
+const (
+	ContactMatchManual ContactMatchReason = "manual" // Merged by an agent
+	ContactMatchPhone  ContactMatchReason = "phone"  // Same normalized phone number
+	ContactMatchEmail  ContactMatchReason = "email"  // Same normalized email address
+)
+
+// ContactMerge is the audit record of a contact folded into another. The source
+// contact's conversations, messages and channel identities moved to the target and
+// the source was deleted.
+type ContactMerge struct {
+	ID              string             `json:"id"`
+	TenantID        string             `json:"tenant_id"`
+	SourceContactID string             `json:"source_contact_id"`
+	TargetContactID string             `json:"target_contact_id"`
+	Reason          ContactMatchReason `json:"reason"`
+	ConversationIDs []string           `json:"conversation_ids,omitempty"` // Moved conversations
+	IdentityIDs     []string           `json:"identity_ids,omitempty"`     // Moved identities; duplicates of the target's are dropped
+	MessageCount    int64              `json:"message_count"`              // Messages whose sender was re-attributed
+	PerformedBy     string             `json:"performed_by,omitempty"`
+	CreatedAt       time.Time          `json:"created_at"`
+}
+
+// ContactMatch is a contact that looks like the same person as another one
+type ContactMatch struct {
+	Contact *Contact           `json:"contact"`
+	Reason  ContactMatchReason `json:"reason"`
+}
+
+// NormalizePhone reduces a phone number to its digits, dropping the international
+// "00" prefix, so "+55 (11) 99999-9999" and "005511999999999" compare equal
+func NormalizePhone(phone string) string {
+	var b strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return strings.TrimPrefix(b.String(), "00")
+}
+
+// NormalizeEmail lowercases and trims an email address, taking the address out of
+// "Name <address>" forms
+func NormalizeEmail(email string) string {
+	if address, err := mail.ParseAddress(email); err == nil {
+		email = address.Address
+	}
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// MergeFrom fills the contact with what only the source knows: empty profile
+// fields, tags, custom fields and addresses. Values already on the contact win.
+func (c *Contact) MergeFrom(source *Contact) {
+	if c.Name == "" || c.Name == "Unknown" {
+		if source.Name != "" {
+			c.Name = source.Name
+		}
+	}
+	if c.Email == "" {
+		c.Email = source.Email
+	}
+	if c.Phone == "" {
+		c.Phone = source.Phone
+	}
+	if c.AvatarURL == "" {
+		c.AvatarURL = source.AvatarURL
+	}
+
+	for _, tag := range source.Tags {
+		if !c.HasTag(tag) {
+			c.Tags = append(c.Tags, tag)
+		}
+	}
+	for key, value := range source.CustomFields {
+		if c.CustomFields == nil {
+			c.CustomFields = make(map[string]string)
+		}
+		if _, ok := c.CustomFields[key]; !ok {
+			c.CustomFields[key] = value
+		}
+	}
+	for _, address := range source.Addresses {
+		found := false
+		for _, existing := range c.Addresses {
+			if existing.Source == address.Source {
+				found = true
+				break
+			}
+		}
+		if !found {
+			c.Addresses = append(c.Addresses, address)
+		}
+	}
+	c.UpdatedAt = time.Now()
+}
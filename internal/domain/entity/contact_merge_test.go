@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizePhone(t *testing.T) {
+	assert.Equal(t, "5511999999999", NormalizePhone("+55 (11) 99999-9999"))
+	assert.Equal(t, "5511999999999", NormalizePhone("005511999999999"))
+	assert.Equal(t, "", NormalizePhone("n/a"))
+}
+
+func TestNormalizeEmail(t *testing.T) {
+	assert.Equal(t, "john@example.com", NormalizeEmail("  John@Example.COM "))
+	assert.Equal(t, "john@example.com", NormalizeEmail("John Doe <John@example.com>"))
+	assert.Equal(t, "", NormalizeEmail(""))
+}
+
+func TestContact_MergeFrom(t *testing.T) {
+	target := &Contact{
+		Name:         "Unknown",
+		Phone:        "+5511999999999",
+		Tags:         []string{"vip"},
+		CustomFields: map[string]string{"plan": "pro"},
+		Addresses:    []ContactAddress{{Source: ContactAddressSourceLocation, City: "Recife"}},
+	}
+	source := &Contact{
+		Name:         "John Doe",
+		Email:        "john@example.com",
+		Phone:        "+5511888888888",
+		Tags:         []string{"vip", "newsletter"},
+		CustomFields: map[string]string{"plan": "free", "company": "Acme"},
+		Addresses: []ContactAddress{
+			{Source: ContactAddressSourceLocation, City: "Natal"},
+			{Source: ContactAddressSourceAddressMessage, City: "Natal"},
+		},
+	}
+
+	target.MergeFrom(source)
+
+	assert.Equal(t, "John Doe", target.Name)
+	assert.Equal(t, "john@example.com", target.Email)
+	assert.Equal(t, "+5511999999999", target.Phone)
+	assert.Equal(t, []string{"vip", "newsletter"}, target.Tags)
+	assert.Equal(t, map[string]string{"plan": "pro", "company": "Acme"}, target.CustomFields)
+	assert.Len(t, target.Addresses, 2)
+	assert.Equal(t, "Recife", target.Addresses[0].City)
+}
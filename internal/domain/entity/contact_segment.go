@@ -0,0 +1,230 @@
+package entity
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SegmentField is the contact property a segment rule filters on
+type SegmentField string
+
+const (
+	SegmentFieldTag           SegmentField = "tag"            // Value is the tag
+	SegmentFieldCustomField   SegmentField = "custom_field"   // Key is the attribute, Value what it is compared with
+	SegmentFieldLastContacted SegmentField = "last_contacted" // Value is a number of days
+	SegmentFieldChannel       SegmentField = "channel"        // Value is the channel type of an identity
+	SegmentFieldCreated       SegmentField = "created"        // Value is a number of days
+)
+
+// SegmentOperator is how a segment rule compares the field
+type SegmentOperator string
+
+const (
+	SegmentOpHas           SegmentOperator = "has"             // tag, channel
+	SegmentOpNotHas        SegmentOperator = "not_has"         // tag, channel
+	SegmentOpEquals        SegmentOperator = "equals"          // custom_field
+	SegmentOpNotEquals     SegmentOperator = "not_equals"      // custom_field
+	SegmentOpContains      SegmentOperator = "contains"        // custom_field, case-insensitive
+	SegmentOpExists        SegmentOperator = "exists"          // custom_field
+	SegmentOpNotExists     SegmentOperator = "not_exists"      // custom_field
+	SegmentOpGreaterThan   SegmentOperator = "greater_than"    // custom_field, numeric
+	SegmentOpLessThan      SegmentOperator = "less_than"       // custom_field, numeric
+	SegmentOpWithinDays    SegmentOperator = "within_days"     // last_contacted, created
+	SegmentOpOlderThanDays SegmentOperator = "older_than_days" // last_contacted, created; never contacted contacts do not match
+	SegmentOpNever         SegmentOperator = "never"           // last_contacted
+)
+
+// SegmentMatch is how the rules of a segment combine
+type SegmentMatch string
+
+const (
+	SegmentMatchAll SegmentMatch = "all"
+	SegmentMatchAny SegmentMatch = "any"
+)
+
+var segmentOperators = map[SegmentField][]SegmentOperator{
+	SegmentFieldTag:           {SegmentOpHas, SegmentOpNotHas},
+	SegmentFieldChannel:       {SegmentOpHas, SegmentOpNotHas},
+	SegmentFieldCustomField:   {SegmentOpEquals, SegmentOpNotEquals, SegmentOpContains, SegmentOpExists, SegmentOpNotExists, SegmentOpGreaterThan, SegmentOpLessThan},
+	SegmentFieldLastContacted: {SegmentOpWithinDays, SegmentOpOlderThanDays, SegmentOpNever},
+	SegmentFieldCreated:       {SegmentOpWithinDays, SegmentOpOlderThanDays},
+}
+
+// SegmentRule is a single filter of a segment
+type SegmentRule struct {
+	Field    SegmentField    `json:"field"`
+	Operator SegmentOperator `json:"operator"`
+	Key      string          `json:"key,omitempty"` // Custom field name
+	Value    string          `json:"value,omitempty"`
+}
+
+// Validate checks the rule's field, operator and value
+func (r *SegmentRule) Validate() error {
+	operators, ok := segmentOperators[r.Field]
+	if !ok {
+		return fmt.Errorf("invalid segment field %q", r.Field)
+	}
+	valid := false
+	for _, op := range operators {
+		if op == r.Operator {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return fmt.Errorf("operator %q is not supported for %s", r.Operator, r.Field)
+	}
+
+	switch r.Field {
+	case SegmentFieldTag, SegmentFieldChannel:
+		if r.Value == "" {
+			return fmt.Errorf("%s rules require a value", r.Field)
+		}
+	case SegmentFieldCustomField:
+		if r.Key == "" {
+			return fmt.Errorf("custom_field rules require a key")
+		}
+		if r.Operator == SegmentOpGreaterThan || r.Operator == SegmentOpLessThan {
+			if _, err := strconv.ParseFloat(r.Value, 64); err != nil {
+				return fmt.Errorf("%s rules require a number", r.Operator)
+			}
+		}
+	case SegmentFieldLastContacted, SegmentFieldCreated:
+		if r.Operator != SegmentOpNever {
+			if _, err := r.Days(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Days returns the number of days of a last_contacted or created rule
+func (r *SegmentRule) Days() (int, error) {
+	days, err := strconv.Atoi(r.Value)
+	if err != nil || days < 0 {
+		return 0, fmt.Errorf("%s rules require a number of days", r.Field)
+	}
+	return days, nil
+}
+
+// Matches checks the rule against a contact and when it was last contacted
+func (r *SegmentRule) Matches(contact *Contact, lastContactedAt *time.Time, now time.Time) bool {
+	switch r.Field {
+	case SegmentFieldTag:
+		return contact.HasTag(r.Value) == (r.Operator == SegmentOpHas)
+
+	case SegmentFieldChannel:
+		return (contact.GetIdentityByChannel(r.Value) != nil) == (r.Operator == SegmentOpHas)
+
+	case SegmentFieldCustomField:
+		value, exists := contact.CustomFields[r.Key]
+		switch r.Operator {
+		case SegmentOpEquals:
+			return exists && value == r.Value
+		case SegmentOpNotEquals:
+			return value != r.Value
+		case SegmentOpContains:
+			return exists && strings.Contains(strings.ToLower(value), strings.ToLower(r.Value))
+		case SegmentOpExists:
+			return exists
+		case SegmentOpNotExists:
+			return !exists
+		case SegmentOpGreaterThan, SegmentOpLessThan:
+			number, err := strconv.ParseFloat(value, 64)
+			limit, limitErr := strconv.ParseFloat(r.Value, 64)
+			if !exists || err != nil || limitErr != nil {
+				return false
+			}
+			if r.Operator == SegmentOpGreaterThan {
+				return number > limit
+			}
+			return number < limit
+		}
+
+	case SegmentFieldLastContacted:
+		if r.Operator == SegmentOpNever {
+			return lastContactedAt == nil
+		}
+		if lastContactedAt == nil {
+			return false
+		}
+		return r.matchesDays(*lastContactedAt, now)
+
+	case SegmentFieldCreated:
+		return r.matchesDays(contact.CreatedAt, now)
+	}
+	return false
+}
+
+func (r *SegmentRule) matchesDays(at, now time.Time) bool {
+	days, err := r.Days()
+	if err != nil {
+		return false
+	}
+	cutoff := now.AddDate(0, 0, -days)
+	if r.Operator == SegmentOpWithinDays {
+		return !at.Before(cutoff)
+	}
+	return at.Before(cutoff)
+}
+
+// ContactSegment is a saved, rule-based group of contacts. Membership is evaluated
+// when the segment is used, so it always reflects the current contact data.
+type ContactSegment struct {
+	ID          string        `json:"id"`
+	TenantID    string        `json:"tenant_id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	Match       SegmentMatch  `json:"match"`
+	Rules       []SegmentRule `json:"rules"`
+	CreatedBy   string        `json:"created_by,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// Validate checks the segment's match mode and rules
+func (s *ContactSegment) Validate() error {
+	if strings.TrimSpace(s.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if s.Match != SegmentMatchAll && s.Match != SegmentMatchAny {
+		return fmt.Errorf("invalid match %q", s.Match)
+	}
+	for i := range s.Rules {
+		if err := s.Rules[i].Validate(); err != nil {
+			return fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Matches checks a contact against the segment. A segment without rules matches
+// every contact.
+func (s *ContactSegment) Matches(contact *Contact, lastContactedAt *time.Time, now time.Time) bool {
+	if len(s.Rules) == 0 {
+		return true
+	}
+	for i := range s.Rules {
+		matched := s.Rules[i].Matches(contact, lastContactedAt, now)
+		if s.Match == SegmentMatchAny && matched {
+			return true
+		}
+		if s.Match != SegmentMatchAny && !matched {
+			return false
+		}
+	}
+	return s.Match != SegmentMatchAny
+}
+
+// SegmentStats summarizes the contacts of a segment
+type SegmentStats struct {
+	SegmentID           string `json:"segment_id"`
+	Contacts            int64  `json:"contacts"`
+	ContactedLast7Days  int64  `json:"contacted_last_7_days"`
+	ContactedLast30Days int64  `json:"contacted_last_30_days"`
+	NeverContacted      int64  `json:"never_contacted"`
+	NewLast30Days       int64  `json:"new_last_30_days"` // Contacts created in the last 30 days
+}
@@ -0,0 +1,64 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContactSegment_Matches(t *testing.T) {
+	now := time.Now()
+	lastContacted := now.AddDate(0, 0, -10)
+	contact := &Contact{
+		Tags:         []string{"vip"},
+		CustomFields: map[string]string{"city": "São Paulo"},
+		CreatedAt:    now.AddDate(0, 0, -40),
+		Identities:   []*ContactIdentity{{ChannelType: "telegram", Identifier: "42"}},
+	}
+
+	tests := []struct {
+		name     string
+		rule     SegmentRule
+		expected bool
+	}{
+		{"has tag", SegmentRule{Field: SegmentFieldTag, Operator: SegmentOpHas, Value: "vip"}, true},
+		{"not has tag", SegmentRule{Field: SegmentFieldTag, Operator: SegmentOpNotHas, Value: "vip"}, false},
+		{"channel", SegmentRule{Field: SegmentFieldChannel, Operator: SegmentOpHas, Value: "telegram"}, true},
+		{"custom field contains", SegmentRule{Field: SegmentFieldCustomField, Operator: SegmentOpContains, Key: "city", Value: "paulo"}, true},
+		{"custom field missing", SegmentRule{Field: SegmentFieldCustomField, Operator: SegmentOpEquals, Key: "plan", Value: "pro"}, false},
+		{"contacted within", SegmentRule{Field: SegmentFieldLastContacted, Operator: SegmentOpWithinDays, Value: "14"}, true},
+		{"contacted older", SegmentRule{Field: SegmentFieldLastContacted, Operator: SegmentOpOlderThanDays, Value: "14"}, false},
+		{"never contacted", SegmentRule{Field: SegmentFieldLastContacted, Operator: SegmentOpNever}, false},
+		{"created older", SegmentRule{Field: SegmentFieldCreated, Operator: SegmentOpOlderThanDays, Value: "30"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.rule.Validate())
+			assert.Equal(t, tt.expected, tt.rule.Matches(contact, &lastContacted, now))
+		})
+	}
+
+	segment := &ContactSegment{Match: SegmentMatchAny, Rules: []SegmentRule{tests[1].rule, tests[2].rule}}
+	assert.True(t, segment.Matches(contact, &lastContacted, now))
+	segment.Match = SegmentMatchAll
+	assert.False(t, segment.Matches(contact, &lastContacted, now))
+}
+
+func TestSegmentRule_NumericCustomField(t *testing.T) {
+	contact := &Contact{CustomFields: map[string]string{"orders": "12", "note": "many"}}
+
+	greater := SegmentRule{Field: SegmentFieldCustomField, Operator: SegmentOpGreaterThan, Key: "orders", Value: "10"}
+	require.NoError(t, greater.Validate())
+	assert.True(t, greater.Matches(contact, nil, time.Now()))
+
+	less := SegmentRule{Field: SegmentFieldCustomField, Operator: SegmentOpLessThan, Key: "orders", Value: "10"}
+	assert.False(t, less.Matches(contact, nil, time.Now()))
+
+	notNumber := SegmentRule{Field: SegmentFieldCustomField, Operator: SegmentOpGreaterThan, Key: "note", Value: "1"}
+	assert.False(t, notNumber.Matches(contact, nil, time.Now()))
+
+	invalid := SegmentRule{Field: SegmentFieldCustomField, Operator: SegmentOpGreaterThan, Key: "orders", Value: "ten"}
+	assert.Error(t, invalid.Validate())
+}
@@ -0,0 +1,61 @@
+package entity
+
+import "time"
+
+// SentimentTrend tells how a contact's sentiment moved across recent conversations
+type SentimentTrend string
+
+const (
+	SentimentTrendImproving SentimentTrend = "improving"
+	SentimentTrendStable    SentimentTrend = "stable"
+	SentimentTrendWorsening SentimentTrend = "worsening"
+	SentimentTrendUnknown   SentimentTrend = "unknown"
+)
+
+// ContactSummary is the profile card agents see next to a conversation, built from
+// the contact's conversation history, payments and orders
+type ContactSummary struct {
+	ContactID             string                `json:"contact_id"`
+	TenantID              string                `json:"tenant_id"`
+	Name                  string                `json:"name,omitempty"`
+	LifetimeConversations int64                 `json:"lifetime_conversations"`
+	OpenConversations     int                   `json:"open_conversations"`
+	FirstContactAt        *time.Time            `json:"first_contact_at,omitempty"`
+	LastContactAt         *time.Time            `json:"last_contact_at,omitempty"`
+	CommonTopics          []ContactTopic        `json:"common_topics"`
+	RecentTopics          []string              `json:"recent_topics"`
+	Sentiment             Sentiment             `json:"sentiment,omitempty"` // Sentiment of the latest analyzed conversation
+	SentimentTrend        SentimentTrend        `json:"sentiment_trend"`
+	SentimentHistory      []Sentiment           `json:"sentiment_history"` // Oldest first
+	OpenPayments          []ContactOpenPayment  `json:"open_payments"`
+	LastOrder             *ContactOrderSnapshot `json:"last_order,omitempty"`
+	GeneratedAt           time.Time             `json:"generated_at"`
+}
+
+// ContactTopic is a topic that came up in a contact's conversations
+type ContactTopic struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// ContactOpenPayment is a payment the contact has not completed yet
+type ContactOpenPayment struct {
+	ID          string     `json:"id"`
+	ReferenceID string     `json:"reference_id"`
+	Amount      int64      `json:"amount"` // In cents
+	Currency    string     `json:"currency"`
+	Status      string     `json:"status"`
+	Description string     `json:"description,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// ContactOrderSnapshot is the gist of the contact's most recent order
+type ContactOrderSnapshot struct {
+	ID        string      `json:"id"`
+	Status    OrderStatus `json:"status"`
+	Total     int64       `json:"total"`
+	Currency  string      `json:"currency"`
+	Items     int         `json:"items"`
+	CreatedAt time.Time   `json:"created_at"`
+}
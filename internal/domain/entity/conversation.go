@@ -24,24 +24,39 @@ const (
 	ConversationPriorityUrgent ConversationPriority = "urgent"
 )
 
+// ConversationTitleSource tells who set the title of a conversation
+type ConversationTitleSource string
+
+const (
+	ConversationTitleSourceAI     ConversationTitleSource = "ai"
+	ConversationTitleSourceManual ConversationTitleSource = "manual"
+)
+
 // Conversation represents a conversation thread
 type Conversation struct {
-	ID             string               `json:"id"`
-	TenantID       string               `json:"tenant_id"`
-	ContactID      string               `json:"contact_id"`
-	ChannelID      string               `json:"channel_id"`
-	AssignedUserID *string              `json:"assigned_user_id,omitempty"`
-	Status         ConversationStatus   `json:"status"`
-	Priority       ConversationPriority `json:"priority"`
-	Subject        string               `json:"subject,omitempty"`
-	Tags           []string             `json:"tags,omitempty"`
-	Metadata       map[string]string    `json:"metadata,omitempty"`
-	UnreadCount    int                  `json:"unread_count"`
-	LastMessageAt  *time.Time           `json:"last_message_at,omitempty"`
-	FirstReplyAt   *time.Time           `json:"first_reply_at,omitempty"`
-	ResolvedAt     *time.Time           `json:"resolved_at,omitempty"`
-	CreatedAt      time.Time            `json:"created_at"`
-	UpdatedAt      time.Time            `json:"updated_at"`
+	ID             string                  `json:"id"`
+	TenantID       string                  `json:"tenant_id"`
+	ContactID      string                  `json:"contact_id"`
+	ChannelID      string                  `json:"channel_id"`
+	AssignedUserID *string                 `json:"assigned_user_id,omitempty"`
+	Status         ConversationStatus      `json:"status"`
+	Priority       ConversationPriority    `json:"priority"`
+	Subject        string                  `json:"subject,omitempty"`
+	Title          string                  `json:"title,omitempty"`
+	TitleSource    ConversationTitleSource `json:"title_source,omitempty"`
+	TitleMessages  int                     `json:"-"` // Messages in the conversation when the AI title was generated
+	TitleUpdatedAt *time.Time              `json:"title_updated_at,omitempty"`
+	Tags           []string                `json:"tags,omitempty"` // Names of the attached labels
+	Labels         []*Label                `json:"labels,omitempty"`
+	Metadata       map[string]string       `json:"metadata,omitempty"`
+	UnreadCount    int                     `json:"unread_count"`
+	LastMessageAt  *time.Time              `json:"last_message_at,omitempty"`
+	FirstReplyAt   *time.Time              `json:"first_reply_at,omitempty"`
+	ResolvedAt     *time.Time              `json:"resolved_at,omitempty"`
+	BotPausedAt    *time.Time              `json:"bot_paused_at,omitempty"` // Set while a human has taken over from the bot
+	BotPausedBy    *string                 `json:"bot_paused_by,omitempty"`
+	CreatedAt      time.Time               `json:"created_at"`
+	UpdatedAt      time.Time               `json:"updated_at"`
 }
 
 // NewConversation creates a new conversation
@@ -91,3 +106,25 @@ func (c *Conversation) Unassign() {
 	c.AssignedUserID = nil
 	c.UpdatedAt = time.Now()
 }
+
+// IsBotPaused returns true if bot and flow automation is paused for the conversation
+func (c *Conversation) IsBotPaused() bool {
+	return c.BotPausedAt != nil
+}
+
+// IsEscalated returns true if the conversation was handed over from automation to a human
+func (c *Conversation) IsEscalated() bool {
+	return c.Metadata["escalated_at"] != ""
+}
+
+// ChangedMetadata returns the metadata keys set to a different value than in before,
+// a copy of the metadata taken before the conversation was changed
+func (c *Conversation) ChangedMetadata(before map[string]string) map[string]string {
+	changed := make(map[string]string)
+	for key, value := range c.Metadata {
+		if previous, ok := before[key]; !ok || previous != value {
+			changed[key] = value
+		}
+	}
+	return changed
+}
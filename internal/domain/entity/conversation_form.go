@@ -0,0 +1,117 @@
+package entity
+
+import "time"
+
+// FormFieldType represents the input type of a form field
+type FormFieldType string
+
+const (
+	FormFieldText        FormFieldType = "text"
+	FormFieldTextarea    FormFieldType = "textarea"
+	FormFieldNumber      FormFieldType = "number"
+	FormFieldEmail       FormFieldType = "email"
+	FormFieldPhone       FormFieldType = "phone"
+	FormFieldDate        FormFieldType = "date"
+	FormFieldSelect      FormFieldType = "select"
+	FormFieldMultiSelect FormFieldType = "multiselect"
+	FormFieldCheckbox    FormFieldType = "checkbox"
+)
+
+// IsValid checks if the field type is known
+func (t FormFieldType) IsValid() bool {
+	switch t {
+	case FormFieldText, FormFieldTextarea, FormFieldNumber, FormFieldEmail, FormFieldPhone,
+		FormFieldDate, FormFieldSelect, FormFieldMultiSelect, FormFieldCheckbox:
+		return true
+	}
+	return false
+}
+
+// FormField is a field of a conversation form and its validation rules
+type FormField struct {
+	Key       string        `json:"key"`
+	Label     string        `json:"label"`
+	Type      FormFieldType `json:"type"`
+	Required  bool          `json:"required"`
+	Options   []string      `json:"options,omitempty"`    // Choices of select and multiselect fields
+	Pattern   string        `json:"pattern,omitempty"`    // Regular expression text values must match
+	MinLength int           `json:"min_length,omitempty"` // Text length bounds; 0 means no bound
+	MaxLength int           `json:"max_length,omitempty"`
+	Min       *float64      `json:"min,omitempty"` // Number bounds
+	Max       *float64      `json:"max,omitempty"`
+	HelpText  string        `json:"help_text,omitempty"`
+	AIHint    string        `json:"ai_hint,omitempty"` // Extra guidance for AI pre-fill
+}
+
+// HasOption checks if a value is one of the field's options
+func (f *FormField) HasOption(value string) bool {
+	for _, option := range f.Options {
+		if option == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ConversationForm is a structured form agents fill during a conversation,
+// such as a complaint intake
+type ConversationForm struct {
+	ID          string      `json:"id"`
+	TenantID    string      `json:"tenant_id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Fields      []FormField `json:"fields"`
+	AIPrefill   bool        `json:"ai_prefill"` // Pre-fill new submissions from the transcript
+	IsActive    bool        `json:"is_active"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// GetField returns a field by key
+func (f *ConversationForm) GetField(key string) *FormField {
+	for i := range f.Fields {
+		if f.Fields[i].Key == key {
+			return &f.Fields[i]
+		}
+	}
+	return nil
+}
+
+// FormSubmissionStatus represents the state of a form submission
+type FormSubmissionStatus string
+
+const (
+	FormSubmissionStatusDraft     FormSubmissionStatus = "draft"
+	FormSubmissionStatusSubmitted FormSubmissionStatus = "submitted"
+)
+
+// FormSubmission is a form filled for a conversation
+type FormSubmission struct {
+	ID                string                 `json:"id"`
+	TenantID          string                 `json:"tenant_id"`
+	FormID            string                 `json:"form_id"`
+	ConversationID    string                 `json:"conversation_id"`
+	ContactID         string                 `json:"contact_id"`
+	Values            map[string]interface{} `json:"values"`
+	AIPrefilledFields []string               `json:"ai_prefilled_fields,omitempty"` // Fields whose value came from AI pre-fill
+	Status            FormSubmissionStatus   `json:"status"`
+	CreatedBy         string                 `json:"created_by"`
+	SubmittedBy       string                 `json:"submitted_by,omitempty"`
+	SubmittedAt       *time.Time             `json:"submitted_at,omitempty"`
+	CreatedAt         time.Time              `json:"created_at"`
+	UpdatedAt         time.Time              `json:"updated_at"`
+}
+
+// IsSubmitted checks if the submission was submitted
+func (s *FormSubmission) IsSubmitted() bool {
+	return s.Status == FormSubmissionStatusSubmitted
+}
+
+// FormSubmissionFilter filters form submissions
+type FormSubmissionFilter struct {
+	FormID         string
+	ConversationID string
+	Status         FormSubmissionStatus
+	Since          *time.Time
+	Until          *time.Time
+}
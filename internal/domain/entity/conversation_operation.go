@@ -0,0 +1,26 @@
+package entity
+
+import "time"
+
+// ConversationOperationType is the kind of structural change made to conversations
+type ConversationOperationType string
+
+const (
+	ConversationOperationMerge ConversationOperationType = "merge"
+	ConversationOperationSplit ConversationOperationType = "split"
+)
+
+// ConversationOperation is the audit record of a merge or split. For a merge the
+// source conversation's messages moved into the target and the source was closed;
+// for a split the listed messages moved from the source into the new target.
+type ConversationOperation struct {
+	ID                   string                    `json:"id"`
+	TenantID             string                    `json:"tenant_id"`
+	Type                 ConversationOperationType `json:"type"`
+	SourceConversationID string                    `json:"source_conversation_id"`
+	TargetConversationID string                    `json:"target_conversation_id"`
+	MessageIDs           []string                  `json:"message_ids,omitempty"` // Moved messages; every message of the source for a merge
+	Reason               string                    `json:"reason,omitempty"`
+	PerformedBy          string                    `json:"performed_by"`
+	CreatedAt            time.Time                 `json:"created_at"`
+}
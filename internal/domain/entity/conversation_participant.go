@@ -0,0 +1,87 @@
+package entity
+
+import "time"
+
+// Metadata keys of group conversations, which have several external participants. The
+// group ID is the address of the group on the channel: the JID of a WhatsApp group or
+// the root message of an email thread with several recipients. Adapters of channels
+// with group chats set it on inbound messages.
+const (
+	MetadataGroupID   = "group_id"
+	MetadataGroupName = "group_name"
+)
+
+// ParticipantStatus represents whether a participant is still in a group conversation
+type ParticipantStatus string
+
+const (
+	ParticipantStatusActive  ParticipantStatus = "active"
+	ParticipantStatusRemoved ParticipantStatus = "removed"
+)
+
+// ConversationParticipant is an external contact taking part in a group conversation.
+// The contact of the conversation is its first participant.
+type ConversationParticipant struct {
+	ID             string            `json:"id"`
+	TenantID       string            `json:"tenant_id"`
+	ConversationID string            `json:"conversation_id"`
+	ContactID      string            `json:"contact_id"`
+	Identifier     string            `json:"identifier"` // Address of the contact on the channel
+	Status         ParticipantStatus `json:"status"`
+	AddedBy        string            `json:"added_by,omitempty"` // User who added them; empty when they joined by writing
+	JoinedAt       time.Time         `json:"joined_at"`
+	LeftAt         *time.Time        `json:"left_at,omitempty"`
+}
+
+// IsActive reports whether the participant is still in the conversation
+func (p *ConversationParticipant) IsActive() bool {
+	return p.Status == ParticipantStatusActive
+}
+
+// MessageRecipient is the delivery status of a group message for one participant
+type MessageRecipient struct {
+	MessageID    string        `json:"message_id"`
+	ContactID    string        `json:"contact_id,omitempty"`
+	Identifier   string        `json:"identifier"`
+	Status       MessageStatus `json:"status"`
+	ErrorMessage string        `json:"error_message,omitempty"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
+
+// messageStatusProgress orders delivery statuses, so late receipts don't move a
+// recipient back
+var messageStatusProgress = map[MessageStatus]int{
+	MessageStatusPending:   0,
+	MessageStatusSent:      1,
+	MessageStatusFailed:    2,
+	MessageStatusDelivered: 3,
+	MessageStatusRead:      4,
+}
+
+// IsStatusProgress reports whether a message or recipient moves forward going from a
+// delivery status to another
+func IsStatusProgress(from, to MessageStatus) bool {
+	return from == "" || messageStatusProgress[to] > messageStatusProgress[from]
+}
+
+// Advance moves the recipient to a status unless it already got further, and reports
+// whether it changed
+func (r *MessageRecipient) Advance(status MessageStatus, errorMessage string, at time.Time) bool {
+	if !IsStatusProgress(r.Status, status) {
+		return false
+	}
+	r.Status = status
+	r.ErrorMessage = errorMessage
+	r.UpdatedAt = at
+	return true
+}
+
+// IsGroup reports whether the conversation has several external participants
+func (c *Conversation) IsGroup() bool {
+	return c.Metadata[MetadataGroupID] != ""
+}
+
+// GroupID returns the address of the group of a group conversation, empty otherwise
+func (c *Conversation) GroupID() string {
+	return c.Metadata[MetadataGroupID]
+}
@@ -0,0 +1,38 @@
+package entity
+
+import "time"
+
+// ReassignmentReason is why a conversation was taken from its agent automatically
+type ReassignmentReason string
+
+const (
+	// ReassignmentReasonAgentOffline is used when the agent disconnected or stopped
+	// sending heartbeats for longer than the tenant's offline grace period
+	ReassignmentReasonAgentOffline ReassignmentReason = "agent_offline"
+	// ReassignmentReasonAgentIdle is used when the agent stayed away for longer than
+	// the tenant's idle grace period
+	ReassignmentReasonAgentIdle ReassignmentReason = "agent_idle"
+)
+
+// ConversationReassignment is the audit record of a conversation taken from an
+// unavailable agent while it waited for a first reply. ToUserID is empty when no
+// agent could take it and it went back to the queue.
+type ConversationReassignment struct {
+	ID               string             `json:"id"`
+	TenantID         string             `json:"tenant_id"`
+	ConversationID   string             `json:"conversation_id"`
+	FromUserID       string             `json:"from_user_id"`
+	ToUserID         string             `json:"to_user_id,omitempty"`
+	Reason           ReassignmentReason `json:"reason"`
+	RoutingRuleID    string             `json:"routing_rule_id,omitempty"`
+	UnavailableSince time.Time          `json:"unavailable_since"`
+	CreatedAt        time.Time          `json:"created_at"`
+}
+
+// AgentAwayNotice tells supervisors that an agent's waiting conversations were re-routed
+type AgentAwayNotice struct {
+	AgentID          string                      `json:"agent_id"`
+	Status           PresenceStatus              `json:"status"`
+	UnavailableSince time.Time                   `json:"unavailable_since"`
+	Reassignments    []*ConversationReassignment `json:"reassignments"`
+}
@@ -0,0 +1,36 @@
+package entity
+
+import "time"
+
+// MetadataFollowUpOf is the conversation metadata key holding the ID of the resolved
+// conversation a new conversation follows up on
+const MetadataFollowUpOf = "follow_up_of"
+
+// ConversationReopenSource tells what reopened a resolved conversation
+type ConversationReopenSource string
+
+const (
+	ConversationReopenCustomer ConversationReopenSource = "customer" // The contact wrote within the reopen window
+	ConversationReopenAgent    ConversationReopenSource = "agent"    // An agent reopened it
+)
+
+// ConversationReopen records a resolved conversation being reopened, the basis of
+// the reopen rate
+type ConversationReopen struct {
+	ID             string                   `json:"id"`
+	TenantID       string                   `json:"tenant_id"`
+	ConversationID string                   `json:"conversation_id"`
+	Source         ConversationReopenSource `json:"source"`
+	ReopenedBy     string                   `json:"reopened_by,omitempty"` // User ID for agent reopens
+	ResolvedAt     time.Time                `json:"resolved_at"`           // When it had been resolved
+	ReopenedAt     time.Time                `json:"reopened_at"`
+}
+
+// ConversationHistory lists how a conversation was reopened and the conversations
+// that followed up on it after the reopen window
+type ConversationHistory struct {
+	ConversationID string                `json:"conversation_id"`
+	FollowUpOf     string                `json:"follow_up_of,omitempty"`
+	Reopens        []*ConversationReopen `json:"reopens"`
+	FollowUps      []*Conversation       `json:"follow_ups"`
+}
@@ -0,0 +1,32 @@
+package entity
+
+import "time"
+
+// ConversationSummaryTrigger is what caused a conversation to be summarized
+type ConversationSummaryTrigger string
+
+const (
+	ConversationSummaryTriggerManual  ConversationSummaryTrigger = "manual"  // Requested by an agent or the API
+	ConversationSummaryTriggerResolve ConversationSummaryTrigger = "resolve" // Generated when the conversation was resolved
+)
+
+// ConversationSummary is a structured AI summary of a conversation, kept so CRM syncs
+// and handovers have context without reading the transcript. A conversation has one
+// summary, replaced each time it is summarized again.
+type ConversationSummary struct {
+	ConversationID string                     `json:"conversation_id"`
+	TenantID       string                     `json:"tenant_id"`
+	Issue          string                     `json:"issue"`
+	Resolution     string                     `json:"resolution,omitempty"`
+	Sentiment      Sentiment                  `json:"sentiment"` // Of the customer by the end of the conversation
+	FollowUps      []string                   `json:"follow_ups"`
+	Trigger        ConversationSummaryTrigger `json:"trigger"`
+	GeneratedBy    string                     `json:"generated_by,omitempty"` // User who requested manual summaries
+	Messages       int                        `json:"messages"`               // Messages in the conversation when it was summarized
+	Provider       string                     `json:"provider"`
+	Model          string                     `json:"model"`
+	CreatedAt      time.Time                  `json:"created_at"`
+
+	// Embedding is of the issue, to find resolved conversations similar to new ones
+	Embedding []float64 `json:"-"`
+}
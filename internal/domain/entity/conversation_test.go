@@ -64,3 +64,13 @@ func TestConversation_Unassign(t *testing.T) {
 	conv.Unassign()
 	assert.Nil(t, conv.AssignedUserID)
 }
+
+func TestConversation_ChangedMetadata(t *testing.T) {
+	conv := NewConversation("t", "c", "ch")
+	conv.Metadata = map[string]string{"language": "pt", "routing_rule": "r1"}
+	before := map[string]string{"language": "pt", "routing_rule": "r0"}
+	conv.Metadata["escalated_at"] = "now"
+
+	assert.Equal(t, map[string]string{"routing_rule": "r1", "escalated_at": "now"}, conv.ChangedMetadata(before))
+	assert.Empty(t, conv.ChangedMetadata(conv.Metadata))
+}
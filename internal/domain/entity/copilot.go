@@ -0,0 +1,64 @@
+package entity
+
+import "time"
+
+// CopilotSuggestionType is what a copilot suggestion offers the agent
+type CopilotSuggestionType string
+
+const (
+	CopilotSuggestionReply   CopilotSuggestionType = "reply"   // A reply the agent can send
+	CopilotSuggestionArticle CopilotSuggestionType = "article" // A relevant knowledge base item
+	CopilotSuggestionSummary CopilotSuggestionType = "summary" // A summary of the conversation so far
+
+	// CopilotSuggestionSimilarReply is the first agent reply of a similar resolved
+	// conversation, offered when a conversation is assigned
+	CopilotSuggestionSimilarReply CopilotSuggestionType = "similar_reply"
+)
+
+// CopilotSuggestionStatus is what the agent did with a suggestion
+type CopilotSuggestionStatus string
+
+const (
+	CopilotSuggestionPending  CopilotSuggestionStatus = "pending"
+	CopilotSuggestionAccepted CopilotSuggestionStatus = "accepted" // Used as suggested
+	CopilotSuggestionEdited   CopilotSuggestionStatus = "edited"   // Used after changes
+	CopilotSuggestionRejected CopilotSuggestionStatus = "rejected"
+)
+
+// CopilotSuggestion is help the copilot pushed to the agent of an escalated
+// conversation after a customer message, or when the conversation was assigned
+type CopilotSuggestion struct {
+	ID                   string                  `json:"id"`
+	TenantID             string                  `json:"tenant_id"`
+	ConversationID       string                  `json:"conversation_id"`
+	MessageID            string                  `json:"message_id,omitempty"` // Customer message that prompted it
+	AgentID              string                  `json:"agent_id"`             // Agent it was offered to
+	Type                 CopilotSuggestionType   `json:"type"`
+	Title                string                  `json:"title,omitempty"` // Question of articles, issue of similar replies
+	Content              string                  `json:"content"`
+	KnowledgeBaseID      string                  `json:"knowledge_base_id,omitempty"`      // Articles only
+	KnowledgeItemID      string                  `json:"knowledge_item_id,omitempty"`      // Articles only
+	Score                float64                 `json:"score,omitempty"`                  // Relevance of articles and similar replies
+	SourceConversationID string                  `json:"source_conversation_id,omitempty"` // Resolved conversation of similar replies
+	Status               CopilotSuggestionStatus `json:"status"`
+	FinalContent         string                  `json:"final_content,omitempty"` // What the agent used after editing
+	RespondedBy          string                  `json:"responded_by,omitempty"`
+	RespondedAt          *time.Time              `json:"responded_at,omitempty"`
+	CreatedAt            time.Time               `json:"created_at"`
+}
+
+// IsPending reports whether the agent has not acted on the suggestion yet
+func (s *CopilotSuggestion) IsPending() bool {
+	return s.Status == CopilotSuggestionPending
+}
+
+// CopilotStats is how agents received one type of copilot suggestion in a period
+type CopilotStats struct {
+	Type           CopilotSuggestionType `json:"type"`
+	Suggestions    int64                 `json:"suggestions"`
+	Accepted       int64                 `json:"accepted"`
+	Edited         int64                 `json:"edited"`
+	Rejected       int64                 `json:"rejected"`
+	Pending        int64                 `json:"pending"`
+	AcceptanceRate float64               `json:"acceptance_rate"` // Accepted or edited, of those acted on
+}
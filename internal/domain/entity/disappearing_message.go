@@ -0,0 +1,112 @@
+package entity
+
+import (
+	"strconv"
+	"time"
+)
+
+// Metadata keys of disappearing messages
+const (
+	// MetadataDisappearingTimer is the disappearing timer of a conversation's chat, in
+	// seconds, as set on the phone. Inbound messages carry the timer they were sent with.
+	MetadataDisappearingTimer = "disappearing_timer"
+	// MetadataDisappearingTimerAt is when the timer of a conversation was last changed
+	MetadataDisappearingTimerAt = "disappearing_timer_at"
+	// MetadataEphemeralExpiresAt is when a message disappears from the chat, RFC 3339 UTC
+	MetadataEphemeralExpiresAt = "ephemeral_expires_at"
+	// MetadataEphemeralExpired marks a message whose expiration was applied
+	MetadataEphemeralExpired = "ephemeral_expired"
+)
+
+// DisappearingRetention is what happens to stored messages once they disappeared
+// from the chat on the phone
+type DisappearingRetention string
+
+const (
+	// DisappearingRetentionKeep keeps expired messages, flagged as expired
+	DisappearingRetentionKeep DisappearingRetention = "keep"
+	// DisappearingRetentionRedact clears the content and attachments of expired
+	// messages, keeping a placeholder in the conversation
+	DisappearingRetentionRedact DisappearingRetention = "redact"
+	// DisappearingRetentionDelete deletes expired messages
+	DisappearingRetentionDelete DisappearingRetention = "delete"
+)
+
+// IsValid reports whether the retention is known
+func (r DisappearingRetention) IsValid() bool {
+	switch r {
+	case DisappearingRetentionKeep, DisappearingRetentionRedact, DisappearingRetentionDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// DisappearingRetention returns what happens to the channel's messages once they
+// disappeared, set by disappearing_retention. Messages are redacted by default, like
+// they vanish from the phone.
+func (c *Channel) DisappearingRetention() DisappearingRetention {
+	if c.Config != nil {
+		if retention := DisappearingRetention(c.Config["disappearing_retention"]); retention.IsValid() {
+			return retention
+		}
+	}
+	return DisappearingRetentionRedact
+}
+
+// DisappearingTimer returns the disappearing timer of the conversation's chat, zero
+// when messages do not disappear
+func (c *Conversation) DisappearingTimer() time.Duration {
+	if c.Metadata == nil {
+		return 0
+	}
+	seconds, err := strconv.Atoi(c.Metadata[MetadataDisappearingTimer])
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// SetDisappearingTimer records the disappearing timer of the conversation's chat. It
+// reports whether the timer changed.
+func (c *Conversation) SetDisappearingTimer(timer time.Duration, at time.Time) bool {
+	if c.Metadata == nil {
+		c.Metadata = make(map[string]string)
+	}
+	value := strconv.Itoa(int(timer.Seconds()))
+	if c.Metadata[MetadataDisappearingTimer] == value {
+		return false
+	}
+	c.Metadata[MetadataDisappearingTimer] = value
+	c.Metadata[MetadataDisappearingTimerAt] = at.UTC().Format(time.RFC3339)
+	return true
+}
+
+// DisappearingTimerMetadata returns the metadata keys recording the disappearing timer
+func (c *Conversation) DisappearingTimerMetadata() map[string]string {
+	return map[string]string{
+		MetadataDisappearingTimer:   c.Metadata[MetadataDisappearingTimer],
+		MetadataDisappearingTimerAt: c.Metadata[MetadataDisappearingTimerAt],
+	}
+}
+
+// EphemeralExpiresAt returns when the message disappears from the chat
+func (m *Message) EphemeralExpiresAt() (time.Time, bool) {
+	if m.Metadata == nil || m.Metadata[MetadataEphemeralExpiresAt] == "" {
+		return time.Time{}, false
+	}
+	expiresAt, err := time.Parse(time.RFC3339, m.Metadata[MetadataEphemeralExpiresAt])
+	return expiresAt, err == nil
+}
+
+// SetEphemeralExpiry makes the message disappear after the timer of its chat
+func (m *Message) SetEphemeralExpiry(timer time.Duration, sentAt time.Time) {
+	if timer <= 0 {
+		return
+	}
+	if m.Metadata == nil {
+		m.Metadata = make(map[string]string)
+	}
+	m.Metadata[MetadataDisappearingTimer] = strconv.Itoa(int(timer.Seconds()))
+	m.Metadata[MetadataEphemeralExpiresAt] = sentAt.Add(timer).UTC().Format(time.RFC3339)
+}
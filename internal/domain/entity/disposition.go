@@ -0,0 +1,53 @@
+package entity
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MaxDispositionNotesLength caps the notes agents leave when resolving a conversation
+const MaxDispositionNotesLength = 2000
+
+var dispositionCodePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,49}$`)
+
+// Disposition is a tenant-defined reason a conversation ended, such as
+// "billing.refund" or "sales.won", that agents pick when resolving it
+type Disposition struct {
+	ID          string    `json:"id"`
+	TenantID    string    `json:"tenant_id"`
+	Code        string    `json:"code"`               // Unique per tenant, e.g. billing.refund
+	Name        string    `json:"name"`               // Shown to agents
+	Category    string    `json:"category,omitempty"` // Groups dispositions, e.g. Billing
+	Description string    `json:"description,omitempty"`
+	Active      bool      `json:"active"`   // Inactive dispositions stay in reports but cannot be picked
+	Position    int       `json:"position"` // Order in which agents see them
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// NormalizeDispositionCode trims and lowercases a disposition code
+func NormalizeDispositionCode(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
+
+// IsValidDispositionCode reports whether a normalized code is made of letters,
+// digits, dots, dashes and underscores, at most 50 characters long
+func IsValidDispositionCode(code string) bool {
+	return dispositionCodePattern.MatchString(code)
+}
+
+// ConversationDisposition is the disposition and notes an agent gave a conversation
+// when resolving it. Code and name are kept as given, so later renames do not
+// rewrite history.
+type ConversationDisposition struct {
+	ConversationID string    `json:"conversation_id"`
+	TenantID       string    `json:"tenant_id"`
+	DispositionID  string    `json:"disposition_id,omitempty"` // Empty when only notes were left
+	Code           string    `json:"code,omitempty"`
+	Name           string    `json:"name,omitempty"`
+	Notes          string    `json:"notes,omitempty"`
+	ResolvedBy     string    `json:"resolved_by,omitempty"` // User ID
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
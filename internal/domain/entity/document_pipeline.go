@@ -0,0 +1,127 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// DocumentField is a key field extracted from a document. Pattern is a regular
+// expression matched against the document text; its first capture group, or the
+// whole match, is the value. Fields the pattern misses are asked to the AI when
+// the pipeline enables AI extraction.
+type DocumentField struct {
+	Key     string `json:"key"`
+	Label   string `json:"label"`
+	Pattern string `json:"pattern,omitempty"`
+	AIHint  string `json:"ai_hint,omitempty"`
+}
+
+// DocumentType is a kind of document a pipeline recognizes, such as an invoice
+// or an ID card. A document is of the type when its text contains at least
+// MinMatches of the keywords.
+type DocumentType struct {
+	Type       string          `json:"type"`
+	Keywords   []string        `json:"keywords"`
+	MinMatches int             `json:"min_matches"`
+	Fields     []DocumentField `json:"fields,omitempty"`
+	FlowID     string          `json:"flow_id,omitempty"` // Flow started on the conversation when detected
+}
+
+// DocumentPipeline reads the documents customers send, detects their type and
+// copies the key fields into the conversation metadata
+type DocumentPipeline struct {
+	ID             string         `json:"id"`
+	TenantID       string         `json:"tenant_id"`
+	Name           string         `json:"name"`
+	Description    string         `json:"description,omitempty"`
+	ChannelIDs     []string       `json:"channel_ids,omitempty"` // Empty matches all channels
+	MimeTypes      []string       `json:"mime_types,omitempty"`  // Empty matches all readable documents
+	DocumentTypes  []DocumentType `json:"document_types"`
+	AIExtraction   bool           `json:"ai_extraction"`
+	MetadataPrefix string         `json:"metadata_prefix"` // Prepended to field keys in conversation metadata
+	Priority       int            `json:"priority"`        // Lower runs first; the first pipeline recognizing a document wins
+	IsActive       bool           `json:"is_active"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// AppliesTo checks if the pipeline reads documents of a MIME type received on a channel
+func (p *DocumentPipeline) AppliesTo(channelID, mimeType string) bool {
+	if len(p.ChannelIDs) > 0 {
+		found := false
+		for _, id := range p.ChannelIDs {
+			if id == channelID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(p.MimeTypes) == 0 {
+		return true
+	}
+	mimeType = strings.ToLower(mimeType)
+	for _, allowed := range p.MimeTypes {
+		allowed = strings.ToLower(allowed)
+		if allowed == mimeType || (strings.HasSuffix(allowed, "/*") && strings.HasPrefix(mimeType, strings.TrimSuffix(allowed, "*"))) {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectType returns the document type whose keywords best match the text, or
+// nil when none reaches its minimum
+func (p *DocumentPipeline) DetectType(text string) *DocumentType {
+	lower := strings.ToLower(text)
+	var best *DocumentType
+	bestMatches := 0
+	for i := range p.DocumentTypes {
+		docType := &p.DocumentTypes[i]
+		matches := 0
+		for _, keyword := range docType.Keywords {
+			if keyword != "" && strings.Contains(lower, strings.ToLower(keyword)) {
+				matches++
+			}
+		}
+		minMatches := docType.MinMatches
+		if minMatches < 1 {
+			minMatches = 1
+		}
+		if matches >= minMatches && matches > bestMatches {
+			best, bestMatches = docType, matches
+		}
+	}
+	return best
+}
+
+// DocumentExtractionStatus represents the outcome of parsing a document
+type DocumentExtractionStatus string
+
+const (
+	DocumentExtractionCompleted    DocumentExtractionStatus = "completed"
+	DocumentExtractionUnrecognized DocumentExtractionStatus = "unrecognized"
+	DocumentExtractionFailed       DocumentExtractionStatus = "failed"
+)
+
+// DocumentExtraction is the result of parsing one attachment
+type DocumentExtraction struct {
+	ID             string                   `json:"id"`
+	TenantID       string                   `json:"tenant_id"`
+	PipelineID     string                   `json:"pipeline_id,omitempty"`
+	ConversationID string                   `json:"conversation_id"`
+	MessageID      string                   `json:"message_id"`
+	AttachmentID   string                   `json:"attachment_id,omitempty"`
+	Filename       string                   `json:"filename,omitempty"`
+	MimeType       string                   `json:"mime_type"`
+	DocumentType   string                   `json:"document_type,omitempty"`
+	Fields         map[string]string        `json:"fields,omitempty"`
+	AIFields       []string                 `json:"ai_fields,omitempty"` // Keys whose value came from the AI
+	Text           string                   `json:"text,omitempty"`      // Leading part of the extracted text
+	Status         DocumentExtractionStatus `json:"status"`
+	Error          string                   `json:"error,omitempty"`
+	FlowID         string                   `json:"flow_id,omitempty"`
+	CreatedAt      time.Time                `json:"created_at"`
+}
@@ -0,0 +1,59 @@
+package entity
+
+import "time"
+
+// EncryptionKeyProvider identifies who holds the key encryption key of a tenant key
+type EncryptionKeyProvider string
+
+const (
+	// EncryptionProviderPlatform wraps data keys with the platform master key
+	EncryptionProviderPlatform EncryptionKeyProvider = "platform"
+	// EncryptionProviderTenant wraps data keys with key material supplied by the tenant
+	EncryptionProviderTenant EncryptionKeyProvider = "tenant"
+	// EncryptionProviderVault wraps data keys with a Vault transit key (external KMS)
+	EncryptionProviderVault EncryptionKeyProvider = "vault"
+)
+
+// IsValid checks if the provider is known
+func (p EncryptionKeyProvider) IsValid() bool {
+	switch p {
+	case EncryptionProviderPlatform, EncryptionProviderTenant, EncryptionProviderVault:
+		return true
+	}
+	return false
+}
+
+// EncryptionKeyStatus represents the lifecycle of a tenant key
+type EncryptionKeyStatus string
+
+const (
+	// EncryptionKeyActive encrypts new data; a tenant has at most one active key
+	EncryptionKeyActive EncryptionKeyStatus = "active"
+	// EncryptionKeyRetired only decrypts data written while it was active
+	EncryptionKeyRetired EncryptionKeyStatus = "retired"
+)
+
+// TenantEncryptionKey is a versioned data key of a tenant. The data key is only
+// stored wrapped by its key encryption key; keys supplied by the tenant are in
+// turn stored wrapped by the platform master key.
+type TenantEncryptionKey struct {
+	ID               string                `json:"id"`
+	TenantID         string                `json:"tenant_id"`
+	Version          int                   `json:"version"`
+	Provider         EncryptionKeyProvider `json:"provider"`
+	KMSKeyRef        string                `json:"kms_key_ref,omitempty"`     // Transit key name for the vault provider
+	KeyFingerprint   string                `json:"key_fingerprint,omitempty"` // Identifies the tenant-supplied key without revealing it
+	WrappedKey       []byte                `json:"-"`
+	WrappedTenantKey []byte                `json:"-"`
+	Status           EncryptionKeyStatus   `json:"status"`
+	CreatedAt        time.Time             `json:"created_at"`
+	RetiredAt        *time.Time            `json:"retired_at,omitempty"`
+}
+
+// TenantEncryptionStatus summarizes the encryption of a tenant
+type TenantEncryptionStatus struct {
+	Enabled   bool                   `json:"enabled"`
+	ActiveKey *TenantEncryptionKey   `json:"active_key,omitempty"`
+	Keys      []*TenantEncryptionKey `json:"keys"`
+	Providers []string               `json:"providers"` // Providers available on this server
+}
@@ -30,11 +30,13 @@ const (
 type TransitionCondition string
 
 const (
-	TransitionConditionDefault     TransitionCondition = "default"      // Always matches (fallback)
-	TransitionConditionReplyEquals TransitionCondition = "reply_equals" // Exact match
-	TransitionConditionContains    TransitionCondition = "contains"     // Contains substring
-	TransitionConditionRegex       TransitionCondition = "regex"        // Regex match
-	TransitionConditionEntity      TransitionCondition = "entity"       // Entity was extracted
+	TransitionConditionDefault     TransitionCondition = "default"       // Always matches (fallback)
+	TransitionConditionReplyEquals TransitionCondition = "reply_equals"  // Exact match
+	TransitionConditionContains    TransitionCondition = "contains"      // Contains substring
+	TransitionConditionRegex       TransitionCondition = "regex"         // Regex match
+	TransitionConditionEntity      TransitionCondition = "entity"        // Entity was extracted
+	TransitionConditionInSegment   TransitionCondition = "in_segment"    // Contact is in the contact segment whose ID is the value
+	TransitionConditionField       TransitionCondition = "contact_field" // Contact's custom field Key compares to the value with Operator
 )
 
 // FlowActionType represents the type of action to execute
@@ -75,8 +77,10 @@ type FlowTransition struct {
 	ID        string              `json:"id"`
 	ToNodeID  string              `json:"to_node_id"`
 	Condition TransitionCondition `json:"condition"`
-	Value     string              `json:"value,omitempty"` // Value to compare against
-	Priority  int                 `json:"priority"`        // Higher priority checked first
+	Value     string              `json:"value,omitempty"`    // Value to compare against
+	Key       string              `json:"key,omitempty"`      // Custom field of contact_field transitions
+	Operator  SegmentOperator     `json:"operator,omitempty"` // Custom field operator of contact_field transitions
+	Priority  int                 `json:"priority"`           // Higher priority checked first
 }
 
 // FlowNode represents a single node in the flow
@@ -216,6 +220,8 @@ type FlowExecutionResult struct {
 	VRECaption    string `json:"vre_caption,omitempty"`     // Caption for the VRE image
 	VREFollowUp   string `json:"vre_follow_up,omitempty"`   // Follow-up text after VRE image
 	IsVREResponse bool   `json:"is_vre_response,omitempty"` // True if this is a VRE response
+	// Trace lists the nodes visited and the conditions evaluated, for automation traces
+	Trace []FlowTraceStep `json:"trace,omitempty"`
 }
 
 // CreateFlowInput represents input for creating a flow
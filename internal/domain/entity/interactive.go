@@ -0,0 +1,186 @@
+package entity
+
+// InteractiveType represents the kind of interactive message
+type InteractiveType string
+
+const (
+	InteractiveTypeButton InteractiveType = "button"
+	InteractiveTypeList   InteractiveType = "list"
+	InteractiveTypeCTAURL InteractiveType = "cta_url"
+)
+
+// InteractiveMessage is a channel-agnostic interactive message: reply buttons, a list
+// with sections, or a call-to-action URL button.
+type InteractiveMessage struct {
+	Type       InteractiveType      `json:"type"`
+	Header     string               `json:"header,omitempty"`
+	Body       string               `json:"body"`
+	Footer     string               `json:"footer,omitempty"`
+	Buttons    []InteractiveButton  `json:"buttons,omitempty"`
+	ListButton string               `json:"list_button,omitempty"`
+	Sections   []InteractiveSection `json:"sections,omitempty"`
+	CTA        *InteractiveCTA      `json:"cta,omitempty"`
+}
+
+// InteractiveButton represents a reply button
+type InteractiveButton struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// InteractiveSection represents a section of a list message
+type InteractiveSection struct {
+	Title string           `json:"title,omitempty"`
+	Rows  []InteractiveRow `json:"rows"`
+}
+
+// InteractiveRow represents a selectable row of a list message
+type InteractiveRow struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+}
+
+// InteractiveCTA represents a call-to-action URL button
+type InteractiveCTA struct {
+	DisplayText string `json:"display_text"`
+	URL         string `json:"url"`
+}
+
+// RowCount returns the number of rows across all sections
+func (m *InteractiveMessage) RowCount() int {
+	count := 0
+	for _, section := range m.Sections {
+		count += len(section.Rows)
+	}
+	return count
+}
+
+// InteractiveConstraints describes what a channel accepts for interactive messages.
+// Lengths are in characters; zero means the element is not supported.
+type InteractiveConstraints struct {
+	SupportsButtons   bool `json:"supports_buttons"`
+	SupportsList      bool `json:"supports_list"`
+	SupportsCTA       bool `json:"supports_cta"`
+	MaxButtons        int  `json:"max_buttons"`
+	MaxButtonTitle    int  `json:"max_button_title"`
+	MaxButtonID       int  `json:"max_button_id"`
+	MaxSections       int  `json:"max_sections"`
+	MaxRows           int  `json:"max_rows"`
+	MaxSectionTitle   int  `json:"max_section_title"`
+	MaxRowTitle       int  `json:"max_row_title"`
+	MaxRowDescription int  `json:"max_row_description"`
+	MaxRowID          int  `json:"max_row_id"`
+	MaxListButton     int  `json:"max_list_button"`
+	MaxHeader         int  `json:"max_header"`
+	MaxBody           int  `json:"max_body"`
+	MaxFooter         int  `json:"max_footer"`
+	MaxCTADisplayText int  `json:"max_cta_display_text"`
+}
+
+var whatsAppInteractiveConstraints = InteractiveConstraints{
+	SupportsButtons:   true,
+	SupportsList:      true,
+	SupportsCTA:       true,
+	MaxButtons:        3,
+	MaxButtonTitle:    20,
+	MaxButtonID:       256,
+	MaxSections:       10,
+	MaxRows:           10,
+	MaxSectionTitle:   24,
+	MaxRowTitle:       24,
+	MaxRowDescription: 72,
+	MaxRowID:          200,
+	MaxListButton:     20,
+	MaxHeader:         60,
+	MaxBody:           1024,
+	MaxFooter:         60,
+	MaxCTADisplayText: 20,
+}
+
+// interactiveConstraints holds the limits of channels with native interactive support
+var interactiveConstraints = map[ChannelType]InteractiveConstraints{
+	ChannelTypeWhatsApp:           whatsAppInteractiveConstraints,
+	ChannelTypeWhatsAppOfficial:   whatsAppInteractiveConstraints,
+	ChannelTypeWhatsAppUnofficial: whatsAppInteractiveConstraints,
+	// Telegram renders buttons and list rows as inline keyboard buttons;
+	// callback data is limited to 64 bytes
+	ChannelTypeTelegram: {
+		SupportsButtons:   true,
+		SupportsList:      true,
+		SupportsCTA:       true,
+		MaxButtons:        100,
+		MaxButtonTitle:    64,
+		MaxButtonID:       64,
+		MaxSections:       10,
+		MaxRows:           100,
+		MaxSectionTitle:   64,
+		MaxRowTitle:       64,
+		MaxRowDescription: 0,
+		MaxRowID:          64,
+		MaxListButton:     64,
+		MaxHeader:         256,
+		MaxBody:           4096,
+		MaxFooter:         256,
+		MaxCTADisplayText: 64,
+	},
+	// Messenger and Instagram button templates accept up to 3 buttons
+	ChannelTypeFacebook: {
+		SupportsButtons:   true,
+		SupportsCTA:       true,
+		MaxButtons:        3,
+		MaxButtonTitle:    20,
+		MaxButtonID:       1000,
+		MaxBody:           640,
+		MaxCTADisplayText: 20,
+	},
+	ChannelTypeInstagram: {
+		SupportsButtons:   true,
+		SupportsCTA:       true,
+		MaxButtons:        3,
+		MaxButtonTitle:    20,
+		MaxButtonID:       1000,
+		MaxBody:           640,
+		MaxCTADisplayText: 20,
+	},
+	ChannelTypeWebChat: {
+		SupportsButtons:   true,
+		SupportsList:      true,
+		SupportsCTA:       true,
+		MaxButtons:        10,
+		MaxButtonTitle:    80,
+		MaxButtonID:       256,
+		MaxSections:       10,
+		MaxRows:           50,
+		MaxSectionTitle:   80,
+		MaxRowTitle:       80,
+		MaxRowDescription: 256,
+		MaxRowID:          256,
+		MaxListButton:     40,
+		MaxHeader:         256,
+		MaxBody:           4096,
+		MaxFooter:         256,
+		MaxCTADisplayText: 80,
+	},
+}
+
+// InteractiveConstraintsFor returns the interactive limits of a channel type. The second
+// result is false for channels without native interactive messages.
+func InteractiveConstraintsFor(channelType ChannelType) (InteractiveConstraints, bool) {
+	constraints, ok := interactiveConstraints[channelType]
+	return constraints, ok
+}
+
+// Supports reports whether the constraints allow an interactive type
+func (c InteractiveConstraints) Supports(t InteractiveType) bool {
+	switch t {
+	case InteractiveTypeButton:
+		return c.SupportsButtons
+	case InteractiveTypeList:
+		return c.SupportsList
+	case InteractiveTypeCTAURL:
+		return c.SupportsCTA
+	default:
+		return false
+	}
+}
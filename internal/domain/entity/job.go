@@ -0,0 +1,51 @@
+package entity
+
+import "time"
+
+// JobStatus represents the state of a background job
+type JobStatus string
+
+const (
+	JobStatusPending   JobStatus = "pending"   // Queued, waiting for a worker (or for a retry)
+	JobStatusRunning   JobStatus = "running"   // Picked up by a worker
+	JobStatusSucceeded JobStatus = "succeeded" // Finished successfully
+	JobStatusFailed    JobStatus = "failed"    // Failed after all attempts
+	JobStatusCancelled JobStatus = "cancelled" // Cancelled by a user
+)
+
+// IsTerminal checks if a job in this status will not run again
+func (s JobStatus) IsTerminal() bool {
+	return s == JobStatusSucceeded || s == JobStatusFailed || s == JobStatusCancelled
+}
+
+// Job is a persisted background operation such as an import, an export or
+// an embedding run. Workers report progress on it while it runs.
+type Job struct {
+	ID              string                 `json:"id"`
+	TenantID        string                 `json:"tenant_id"`
+	Type            string                 `json:"type"`
+	Status          JobStatus              `json:"status"`
+	Payload         map[string]interface{} `json:"payload,omitempty"`
+	Result          map[string]interface{} `json:"result,omitempty"`
+	Progress        int                    `json:"progress"` // 0-100
+	ProgressMessage string                 `json:"progress_message,omitempty"`
+	Error           string                 `json:"error,omitempty"`
+	Attempts        int                    `json:"attempts"`
+	MaxAttempts     int                    `json:"max_attempts"`
+	CreatedBy       string                 `json:"created_by,omitempty"`
+	StartedAt       *time.Time             `json:"started_at,omitempty"`
+	CompletedAt     *time.Time             `json:"completed_at,omitempty"`
+	CreatedAt       time.Time              `json:"created_at"`
+	UpdatedAt       time.Time              `json:"updated_at"`
+}
+
+// CanRetry checks if a failed attempt leaves attempts for a retry
+func (j *Job) CanRetry() bool {
+	return j.Attempts < j.MaxAttempts
+}
+
+// JobFilter filters job listings
+type JobFilter struct {
+	Type   string
+	Status JobStatus
+}
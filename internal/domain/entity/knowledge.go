@@ -37,6 +37,10 @@ type KnowledgeConfig struct {
 	EmbeddingModel   string `json:"embedding_model,omitempty"`
 	ChunkSize        int    `json:"chunk_size,omitempty"`
 	ChunkOverlap     int    `json:"chunk_overlap,omitempty"`
+
+	// ReviewRequired keeps items added or edited through the API as drafts until
+	// they are published, so bots keep answering from the reviewed content
+	ReviewRequired bool `json:"review_required,omitempty"`
 }
 
 // KnowledgeBase represents a knowledge base for RAG
@@ -52,6 +56,10 @@ type KnowledgeBase struct {
 	LastSyncAt  *time.Time      `json:"last_sync_at,omitempty"`
 	CreatedAt   time.Time       `json:"created_at"`
 	UpdatedAt   time.Time       `json:"updated_at"`
+
+	// Global knowledge bases belong to no tenant: the platform maintains them and
+	// attaches them read-only to tenants, whose bots may then answer from them
+	Global bool `json:"global,omitempty"`
 }
 
 // NewKnowledgeBase creates a new knowledge base
@@ -69,6 +77,14 @@ func NewKnowledgeBase(tenantID, name string, kbType KnowledgeType) *KnowledgeBas
 	}
 }
 
+// NewGlobalKnowledgeBase creates a knowledge base maintained by the platform and
+// shared with the tenants it is attached to
+func NewGlobalKnowledgeBase(name string, kbType KnowledgeType) *KnowledgeBase {
+	kb := NewKnowledgeBase("", name, kbType)
+	kb.Global = true
+	return kb
+}
+
 // IsActive returns true if the knowledge base is active
 func (kb *KnowledgeBase) IsActive() bool {
 	return kb.Status == KnowledgeStatusActive
@@ -120,6 +136,20 @@ type KnowledgeItem struct {
 	Metadata        map[string]string `json:"metadata,omitempty"`
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
+
+	// Publishing: bots only retrieve published items, with the content of the
+	// published version, while later versions wait as a draft
+	Status           KnowledgeItemStatus   `json:"status"`
+	Version          int                   `json:"version"`                // Latest version
+	PublishedVersion int                   `json:"published_version"`      // 0 until first published
+	PublishedBy      string                `json:"published_by,omitempty"` // User ID, empty for automatic publishing
+	PublishedAt      *time.Time            `json:"published_at,omitempty"`
+	Draft            *KnowledgeItemVersion `json:"draft,omitempty"` // Unpublished changes of a published item
+
+	// Overrides: items a tenant adds to a global knowledge base are only seen by that
+	// tenant, and replace the item they override in its searches
+	TenantID        string `json:"tenant_id,omitempty"`
+	OverridesItemID string `json:"overrides_item_id,omitempty"`
 }
 
 // NewKnowledgeItem creates a new knowledge item
@@ -136,6 +166,22 @@ func NewKnowledgeItem(knowledgeBaseID, question, answer string) *KnowledgeItem {
 	}
 }
 
+// IsPublished reports whether bots may retrieve the item. Items stored before
+// publishing existed have no status and count as published.
+func (ki *KnowledgeItem) IsPublished() bool {
+	return ki.Status != KnowledgeItemStatusDraft
+}
+
+// IsOverride returns true for items a tenant added to a global knowledge base
+func (ki *KnowledgeItem) IsOverride() bool {
+	return ki.TenantID != ""
+}
+
+// HasDraft reports whether the item has changes not published yet
+func (ki *KnowledgeItem) HasDraft() bool {
+	return ki.Version > ki.PublishedVersion
+}
+
 // SetEmbedding sets the embedding vector
 func (ki *KnowledgeItem) SetEmbedding(embedding []float64) {
 	ki.Embedding = embedding
@@ -196,3 +242,11 @@ type KnowledgeSearchResponse struct {
 	TotalFound   int            `json:"total_found"`
 	SearchTimeMs int64          `json:"search_time_ms"`
 }
+
+// KnowledgeBaseAttachment grants a tenant read access to a global knowledge base
+type KnowledgeBaseAttachment struct {
+	KnowledgeBaseID string    `json:"knowledge_base_id"`
+	TenantID        string    `json:"tenant_id"`
+	AttachedBy      string    `json:"attached_by,omitempty"`
+	AttachedAt      time.Time `json:"attached_at"`
+}
@@ -0,0 +1,49 @@
+package entity
+
+import "time"
+
+// KnowledgeCandidateStatus is the review state of a mined Q&A pair
+type KnowledgeCandidateStatus string
+
+const (
+	KnowledgeCandidatePending   KnowledgeCandidateStatus = "pending"
+	KnowledgeCandidatePublished KnowledgeCandidateStatus = "published"
+	KnowledgeCandidateRejected  KnowledgeCandidateStatus = "rejected"
+)
+
+// KnowledgeCandidate is a question and answer the AI proposed from a resolved
+// conversation, held until a reviewer edits and publishes it to a knowledge base
+type KnowledgeCandidate struct {
+	ID              string                   `json:"id"`
+	TenantID        string                   `json:"tenant_id"`
+	ConversationID  string                   `json:"conversation_id"` // Conversation the pair was mined from
+	Question        string                   `json:"question"`
+	Answer          string                   `json:"answer"`
+	Status          KnowledgeCandidateStatus `json:"status"`
+	Edited          bool                     `json:"edited"`                      // Changed by a reviewer after mining
+	KnowledgeBaseID string                   `json:"knowledge_base_id,omitempty"` // Set when published
+	ItemID          string                   `json:"item_id,omitempty"`           // Knowledge item created on publishing
+	ReviewedBy      string                   `json:"reviewed_by,omitempty"`
+	ReviewedAt      *time.Time               `json:"reviewed_at,omitempty"`
+	CreatedAt       time.Time                `json:"created_at"`
+	UpdatedAt       time.Time                `json:"updated_at"`
+}
+
+// IsPending reports whether the candidate still awaits review
+func (c *KnowledgeCandidate) IsPending() bool {
+	return c.Status == KnowledgeCandidatePending
+}
+
+// KnowledgeCandidateFilter narrows the candidates listed for review
+type KnowledgeCandidateFilter struct {
+	ConversationID string
+	Status         KnowledgeCandidateStatus
+}
+
+// KnowledgeMiningResult summarizes a run over resolved conversations
+type KnowledgeMiningResult struct {
+	Conversations int                   `json:"conversations"` // Conversations read by the AI
+	Skipped       int                   `json:"skipped"`       // Already mined or without a transcript
+	Failed        int                   `json:"failed"`
+	Candidates    []*KnowledgeCandidate `json:"candidates"`
+}
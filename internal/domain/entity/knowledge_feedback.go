@@ -0,0 +1,92 @@
+package entity
+
+import "time"
+
+// AnswerFeedbackConfig makes a bot ask customers whether an answer built from the
+// knowledge base helped, using the channel's native buttons
+type AnswerFeedbackConfig struct {
+	Enabled  bool   `json:"enabled"`
+	Prompt   string `json:"prompt,omitempty"`    // Defaults to "Did this help?"
+	YesLabel string `json:"yes_label,omitempty"` // Defaults to "Yes"
+	NoLabel  string `json:"no_label,omitempty"`  // Defaults to "No"
+}
+
+// KnowledgeFeedback is a customer's rating of a bot answer and the knowledge items
+// the answer was built from. A later rating of the same answer replaces it.
+type KnowledgeFeedback struct {
+	ID              string    `json:"id"`
+	TenantID        string    `json:"tenant_id"`
+	KnowledgeBaseID string    `json:"knowledge_base_id"`
+	ItemIDs         []string  `json:"item_ids"`
+	ConversationID  string    `json:"conversation_id"`
+	MessageID       string    `json:"message_id"` // The rated bot answer
+	ContactID       string    `json:"contact_id,omitempty"`
+	Question        string    `json:"question,omitempty"` // Customer message the bot answered
+	Helpful         bool      `json:"helpful"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// KnowledgeItemQuality aggregates the feedback of answers that used a knowledge item
+type KnowledgeItemQuality struct {
+	ItemID        string  `json:"item_id"`
+	Question      string  `json:"question"`
+	Helpful       int64   `json:"helpful"`
+	NotHelpful    int64   `json:"not_helpful"`
+	HelpfulRate   float64 `json:"helpful_rate"`
+	LowPerforming bool    `json:"low_performing"`
+}
+
+// Responses returns how many ratings the item received
+func (q *KnowledgeItemQuality) Responses() int64 {
+	return q.Helpful + q.NotHelpful
+}
+
+// KnowledgeQualityReport summarizes customer feedback on a knowledge base, worst items first
+type KnowledgeQualityReport struct {
+	KnowledgeBaseID    string                  `json:"knowledge_base_id"`
+	Responses          int64                   `json:"responses"`
+	HelpfulRate        float64                 `json:"helpful_rate"`
+	LowPerformingCount int                     `json:"low_performing_count"`
+	Items              []*KnowledgeItemQuality `json:"items"`
+}
+
+// KnowledgeGapStatus is the review state of a knowledge gap
+type KnowledgeGapStatus string
+
+const (
+	KnowledgeGapOpen      KnowledgeGapStatus = "open"
+	KnowledgeGapResolved  KnowledgeGapStatus = "resolved"
+	KnowledgeGapDismissed KnowledgeGapStatus = "dismissed"
+)
+
+// KnowledgeGapSource tells what revealed a knowledge gap
+type KnowledgeGapSource string
+
+const (
+	KnowledgeGapSourceNegativeFeedback KnowledgeGapSource = "negative_feedback"
+)
+
+// KnowledgeGap is a customer question the knowledge base failed to answer, queued
+// for an editor to add or fix content. Repeated questions raise the occurrence count
+// of the open gap instead of queueing it again.
+type KnowledgeGap struct {
+	ID              string             `json:"id"`
+	TenantID        string             `json:"tenant_id"`
+	KnowledgeBaseID string             `json:"knowledge_base_id"`
+	Question        string             `json:"question"`
+	Source          KnowledgeGapSource `json:"source"`
+	ItemIDs         []string           `json:"item_ids,omitempty"` // Items used by the answer that did not help
+	ConversationID  string             `json:"conversation_id,omitempty"`
+	Occurrences     int                `json:"occurrences"`
+	Status          KnowledgeGapStatus `json:"status"`
+	ResolvedBy      string             `json:"resolved_by,omitempty"`
+	ResolvedAt      *time.Time         `json:"resolved_at,omitempty"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+}
+
+// KnowledgeGapFilter narrows the knowledge gaps listed for review
+type KnowledgeGapFilter struct {
+	KnowledgeBaseID string
+	Status          KnowledgeGapStatus
+}
@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// KnowledgeInbox is an email address whose inbound mail is turned into knowledge base
+// content. Attachments and bodies are held as submissions until a reviewer publishes them.
+type KnowledgeInbox struct {
+	ID              string    `json:"id"`
+	TenantID        string    `json:"tenant_id"`
+	Name            string    `json:"name"`
+	Address         string    `json:"address"` // Recipient address, such as kb@tenant.com
+	KnowledgeBaseID string    `json:"knowledge_base_id"`
+	AllowedSenders  []string  `json:"allowed_senders,omitempty"` // Addresses or @domains; anyone when empty
+	IncludeBody     bool      `json:"include_body"`              // Ingest the email body, not only attachments
+	IsActive        bool      `json:"is_active"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// AcceptsSender reports whether mail from the address may be ingested
+func (i *KnowledgeInbox) AcceptsSender(from string) bool {
+	if len(i.AllowedSenders) == 0 {
+		return true
+	}
+	from = strings.ToLower(strings.TrimSpace(from))
+	for _, allowed := range i.AllowedSenders {
+		allowed = strings.ToLower(strings.TrimSpace(allowed))
+		if allowed == from || (strings.HasPrefix(allowed, "@") && strings.HasSuffix(from, allowed)) {
+			return true
+		}
+	}
+	return false
+}
+
+// KnowledgeSubmissionStatus is the review state of ingested content
+type KnowledgeSubmissionStatus string
+
+const (
+	KnowledgeSubmissionPending  KnowledgeSubmissionStatus = "pending"
+	KnowledgeSubmissionApproved KnowledgeSubmissionStatus = "approved"
+	KnowledgeSubmissionRejected KnowledgeSubmissionStatus = "rejected"
+	KnowledgeSubmissionFailed   KnowledgeSubmissionStatus = "failed" // Text could not be extracted
+)
+
+// KnowledgeSubmission is one email body or attachment received by a knowledge inbox,
+// waiting for review before it is published to the knowledge base
+type KnowledgeSubmission struct {
+	ID              string                    `json:"id"`
+	TenantID        string                    `json:"tenant_id"`
+	InboxID         string                    `json:"inbox_id"`
+	KnowledgeBaseID string                    `json:"knowledge_base_id"`
+	MessageID       string                    `json:"message_id,omitempty"`
+	Sender          string                    `json:"sender"`
+	Subject         string                    `json:"subject,omitempty"`
+	Filename        string                    `json:"filename,omitempty"` // Empty for the email body
+	MimeType        string                    `json:"mime_type,omitempty"`
+	Title           string                    `json:"title"`
+	Content         string                    `json:"content"`
+	Status          KnowledgeSubmissionStatus `json:"status"`
+	Error           string                    `json:"error,omitempty"`
+	ItemIDs         []string                  `json:"item_ids,omitempty"` // Knowledge items created on approval
+	ReviewedBy      string                    `json:"reviewed_by,omitempty"`
+	ReviewedAt      *time.Time                `json:"reviewed_at,omitempty"`
+	CreatedAt       time.Time                 `json:"created_at"`
+	UpdatedAt       time.Time                 `json:"updated_at"`
+}
+
+// IsPending reports whether the submission still awaits review
+func (s *KnowledgeSubmission) IsPending() bool {
+	return s.Status == KnowledgeSubmissionPending || s.Status == KnowledgeSubmissionFailed
+}
+
+// KnowledgeSubmissionFilter narrows the submissions listed for review
+type KnowledgeSubmissionFilter struct {
+	InboxID string
+	Status  KnowledgeSubmissionStatus
+}
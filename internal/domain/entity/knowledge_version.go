@@ -0,0 +1,124 @@
+package entity
+
+import "time"
+
+// KnowledgeItemStatus is the publishing state of a knowledge item
+type KnowledgeItemStatus string
+
+const (
+	KnowledgeItemStatusDraft     KnowledgeItemStatus = "draft"
+	KnowledgeItemStatusPublished KnowledgeItemStatus = "published"
+)
+
+// KnowledgeAction is a change recorded in the knowledge audit trail
+type KnowledgeAction string
+
+const (
+	KnowledgeActionCreated    KnowledgeAction = "created"
+	KnowledgeActionEdited     KnowledgeAction = "edited"
+	KnowledgeActionPublished  KnowledgeAction = "published"
+	KnowledgeActionRolledBack KnowledgeAction = "rolled_back"
+	KnowledgeActionDeleted    KnowledgeAction = "deleted"
+)
+
+// KnowledgeItemVersion is the content of a knowledge item as saved at one point.
+// Every change adds a version; publishing makes one of them the content bots retrieve.
+type KnowledgeItemVersion struct {
+	ID              string            `json:"id"`
+	ItemID          string            `json:"item_id"`
+	KnowledgeBaseID string            `json:"knowledge_base_id"`
+	Version         int               `json:"version"`
+	Question        string            `json:"question"`
+	Answer          string            `json:"answer"`
+	Keywords        []string          `json:"keywords,omitempty"`
+	Source          string            `json:"source,omitempty"`
+	Metadata        map[string]string `json:"metadata,omitempty"`
+	Action          KnowledgeAction   `json:"action"`                  // created, edited or rolled_back
+	RestoredFrom    int               `json:"restored_from,omitempty"` // Version copied by a rollback
+	CreatedBy       string            `json:"created_by,omitempty"`    // User ID, empty for automatic changes
+	CreatedAt       time.Time         `json:"created_at"`
+	PublishedBy     string            `json:"published_by,omitempty"`
+	PublishedAt     *time.Time        `json:"published_at,omitempty"`
+}
+
+// NewKnowledgeItemVersion snapshots the current content of an item
+func NewKnowledgeItemVersion(item *KnowledgeItem, version int, action KnowledgeAction, userID string) *KnowledgeItemVersion {
+	return &KnowledgeItemVersion{
+		ItemID:          item.ID,
+		KnowledgeBaseID: item.KnowledgeBaseID,
+		Version:         version,
+		Question:        item.Question,
+		Answer:          item.Answer,
+		Keywords:        item.Keywords,
+		Source:          item.Source,
+		Metadata:        item.Metadata,
+		Action:          action,
+		CreatedBy:       userID,
+		CreatedAt:       time.Now(),
+	}
+}
+
+// ApplyTo copies the content of the version to an item
+func (v *KnowledgeItemVersion) ApplyTo(item *KnowledgeItem) {
+	item.Question = v.Question
+	item.Answer = v.Answer
+	item.Keywords = v.Keywords
+	item.Source = v.Source
+	item.Metadata = v.Metadata
+}
+
+// KnowledgeAuditEvent records who changed what in a knowledge base. Events
+// outlive the items they describe, so deletions stay on record.
+type KnowledgeAuditEvent struct {
+	ID              string          `json:"id"`
+	KnowledgeBaseID string          `json:"knowledge_base_id"`
+	ItemID          string          `json:"item_id"`
+	Action          KnowledgeAction `json:"action"`
+	Version         int             `json:"version,omitempty"`
+	UserID          string          `json:"user_id,omitempty"` // Empty for automatic changes
+	Question        string          `json:"question"`          // Question of the item at the time, for deleted items
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+// KnowledgeAuditFilter narrows the audit trail of a knowledge base
+type KnowledgeAuditFilter struct {
+	ItemID string
+	UserID string
+}
+
+// KnowledgeDiffOp marks a line of a text diff
+type KnowledgeDiffOp string
+
+const (
+	KnowledgeDiffEqual  KnowledgeDiffOp = "equal"
+	KnowledgeDiffInsert KnowledgeDiffOp = "insert"
+	KnowledgeDiffDelete KnowledgeDiffOp = "delete"
+)
+
+// KnowledgeDiffLine is one line of a text diff
+type KnowledgeDiffLine struct {
+	Op   KnowledgeDiffOp `json:"op"`
+	Text string          `json:"text"`
+}
+
+// KnowledgeFieldChange is a field that differs between two versions
+type KnowledgeFieldChange struct {
+	Field  string              `json:"field"` // question, answer, keywords, source or metadata
+	Before string              `json:"before"`
+	After  string              `json:"after"`
+	Lines  []KnowledgeDiffLine `json:"lines,omitempty"` // Line diff of question, answer and metadata
+}
+
+// KnowledgeItemDiff lists the changes between two versions of an item
+type KnowledgeItemDiff struct {
+	ItemID  string                 `json:"item_id"`
+	From    int                    `json:"from"`
+	To      int                    `json:"to"`
+	Changes []KnowledgeFieldChange `json:"changes"`
+}
+
+// KnowledgePublishResult lists the items a publish of a whole knowledge base made live
+type KnowledgePublishResult struct {
+	Published int      `json:"published"`
+	ItemIDs   []string `json:"item_ids"`
+}
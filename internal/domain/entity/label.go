@@ -0,0 +1,33 @@
+package entity
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// MaxLabelNameLength caps the length of label names
+const MaxLabelNameLength = 50
+
+var labelColorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// Label is a tenant-defined tag agents attach to conversations to categorize them
+type Label struct {
+	ID          string    `json:"id"`
+	TenantID    string    `json:"tenant_id"`
+	Name        string    `json:"name"`
+	Color       string    `json:"color,omitempty"` // Hex color, e.g. #1F93FF
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// NormalizeLabelName trims a label name and collapses its inner whitespace
+func NormalizeLabelName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// IsValidLabelColor reports whether color is empty or a #RRGGBB hex color
+func IsValidLabelColor(color string) bool {
+	return color == "" || labelColorPattern.MatchString(color)
+}
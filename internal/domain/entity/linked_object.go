@@ -0,0 +1,96 @@
+package entity
+
+import (
+	"time"
+)
+
+// LinkedObjectType is the kind of business object linked to conversations
+type LinkedObjectType string
+
+const (
+	LinkedObjectTypeOrder   LinkedObjectType = "order"
+	LinkedObjectTypeTicket  LinkedObjectType = "ticket"
+	LinkedObjectTypeBooking LinkedObjectType = "booking"
+)
+
+// IsValid checks if the linked object type is known
+func (t LinkedObjectType) IsValid() bool {
+	switch t {
+	case LinkedObjectTypeOrder, LinkedObjectTypeTicket, LinkedObjectTypeBooking:
+		return true
+	}
+	return false
+}
+
+// LinkedObjectSourceManual marks objects created by agents or through the API
+const LinkedObjectSourceManual = "manual"
+
+// LinkedObject is an order, ticket or booking of an external system shown next to
+// the conversations of its contact
+type LinkedObject struct {
+	ID                string            `json:"id"`
+	TenantID          string            `json:"tenant_id"`
+	Type              LinkedObjectType  `json:"type"`
+	Source            string            `json:"source"` // manual or the provider of the connector
+	ConnectorID       string            `json:"connector_id,omitempty"`
+	ExternalID        string            `json:"external_id,omitempty"`
+	Reference         string            `json:"reference"` // Human reference such as an order number
+	Title             string            `json:"title,omitempty"`
+	Status            string            `json:"status,omitempty"`
+	URL               string            `json:"url,omitempty"`
+	ContactID         string            `json:"contact_id,omitempty"`
+	ConversationID    string            `json:"conversation_id,omitempty"`
+	Attributes        map[string]string `json:"attributes,omitempty"`
+	ExternalUpdatedAt *time.Time        `json:"external_updated_at,omitempty"`
+	CreatedBy         string            `json:"created_by,omitempty"`
+	CreatedAt         time.Time         `json:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at"`
+}
+
+// LinkedObjectFilter filters linked object listings
+type LinkedObjectFilter struct {
+	Type           LinkedObjectType
+	ConversationID string
+	ContactID      string
+	ConnectorID    string
+	Status         string
+}
+
+// LinkedObjectProvider is the external system a connector receives webhooks from
+type LinkedObjectProvider string
+
+const (
+	LinkedObjectProviderGeneric LinkedObjectProvider = "generic"
+	LinkedObjectProviderShopify LinkedObjectProvider = "shopify"
+	LinkedObjectProviderZendesk LinkedObjectProvider = "zendesk"
+)
+
+// IsValid checks if the connector provider is known
+func (p LinkedObjectProvider) IsValid() bool {
+	switch p {
+	case LinkedObjectProviderGeneric, LinkedObjectProviderShopify, LinkedObjectProviderZendesk:
+		return true
+	}
+	return false
+}
+
+// LinkedObjectConnector syncs linked objects from the webhooks of an external system.
+// FieldMap maps object fields (external_id, reference, title, status, url,
+// contact_email, contact_phone, conversation_id, updated_at and attributes.<key>)
+// to dotted paths in the webhook payload; it overrides the provider's defaults.
+// StatusMessages holds the message sent to the conversation when an object
+// reaches a status, rendered with the object's variables.
+type LinkedObjectConnector struct {
+	ID             string               `json:"id"`
+	TenantID       string               `json:"tenant_id"`
+	Name           string               `json:"name"`
+	Provider       LinkedObjectProvider `json:"provider"`
+	ObjectType     LinkedObjectType     `json:"object_type"`
+	Secret         string               `json:"-"`
+	FieldMap       map[string]string    `json:"field_map,omitempty"`
+	StatusMessages map[string]string    `json:"status_messages,omitempty"`
+	IsActive       bool                 `json:"is_active"`
+	LastSyncAt     *time.Time           `json:"last_sync_at,omitempty"`
+	CreatedAt      time.Time            `json:"created_at"`
+	UpdatedAt      time.Time            `json:"updated_at"`
+}
@@ -0,0 +1,40 @@
+package entity
+
+import "time"
+
+// MaintenanceStatus is the phase of the system-wide maintenance mode
+type MaintenanceStatus string
+
+const (
+	MaintenanceStatusOff      MaintenanceStatus = "off"
+	MaintenanceStatusActive   MaintenanceStatus = "active"   // Webhooks are buffered, processing and sends are paused
+	MaintenanceStatusDraining MaintenanceStatus = "draining" // Buffered webhooks are replayed; new ones queue behind them
+)
+
+// MaintenanceState is the system-wide maintenance mode, shared by all instances
+type MaintenanceState struct {
+	Status    MaintenanceStatus `json:"status"`
+	Reason    string            `json:"reason,omitempty"`
+	StartedBy string            `json:"started_by,omitempty"`
+	StartedAt *time.Time        `json:"started_at,omitempty"`
+	EndedAt   *time.Time        `json:"ended_at,omitempty"` // When maintenance ended and draining began
+	Buffered  int64             `json:"buffered"`           // Webhooks waiting to be replayed
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// BuffersWebhooks reports whether inbound webhooks must be buffered instead of handled
+func (s *MaintenanceState) BuffersWebhooks() bool {
+	return s.Status == MaintenanceStatusActive || s.Status == MaintenanceStatusDraining
+}
+
+// BufferedWebhook is a provider webhook request accepted during maintenance, replayed
+// in order when maintenance ends
+type BufferedWebhook struct {
+	ID         int64               `json:"id"`
+	Method     string              `json:"method"`
+	Host       string              `json:"host"`
+	URI        string              `json:"uri"` // Path and query
+	Headers    map[string][]string `json:"headers"`
+	Body       []byte              `json:"-"`
+	ReceivedAt time.Time           `json:"received_at"`
+}
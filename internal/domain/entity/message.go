@@ -78,6 +78,7 @@ type Message struct {
 	Status         MessageStatus        `json:"status"`
 	ExternalID     string               `json:"external_id,omitempty"`
 	ErrorMessage   string               `json:"error_message,omitempty"`
+	ErrorDetails   *MessageError        `json:"error_details,omitempty"` // Classified failure with remediation hint
 	Attachments    []*MessageAttachment `json:"attachments,omitempty"`
 	SentAt         *time.Time           `json:"sent_at,omitempty"`
 	DeliveredAt    *time.Time           `json:"delivered_at,omitempty"`
@@ -98,8 +99,14 @@ type Message struct {
 	// Reactions
 	Reactions []Reaction `json:"reactions,omitempty"` // Emoji reactions on this message
 
+	// Translation of the content, included in API responses when requested
+	Translation *MessageTranslation `json:"translation,omitempty"`
+
 	// Reply context
 	ReplyToID string `json:"reply_to_id,omitempty"` // ID of the message being replied to
+
+	// Structured context built from the metadata, such as the ad or order behind it
+	ContextCards []MessageContextCard `json:"context_cards,omitempty"`
 }
 
 // Reaction represents an emoji reaction on a message
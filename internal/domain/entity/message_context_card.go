@@ -0,0 +1,186 @@
+package entity
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// MessageContextCardType is the kind of context a message carries
+type MessageContextCardType string
+
+const (
+	MessageContextCardAdReferral   MessageContextCardType = "ad_referral"   // Click-to-WhatsApp ad the contact came from
+	MessageContextCardOrder        MessageContextCardType = "order"         // Cart sent from a catalog
+	MessageContextCardFlowResponse MessageContextCardType = "flow_response" // Answers submitted in a WhatsApp Flow
+)
+
+// MessageContextCard is structured context of a message for agents, built from the
+// metadata channels attach to it. Exactly one of the typed fields is set.
+type MessageContextCard struct {
+	Type         MessageContextCardType `json:"type"`
+	AdReferral   *AdReferralCard        `json:"ad_referral,omitempty"`
+	Order        *OrderCard             `json:"order,omitempty"`
+	FlowResponse *FlowResponseCard      `json:"flow_response,omitempty"`
+}
+
+// AdReferralCard is the ad a contact clicked to start the conversation
+type AdReferralCard struct {
+	SourceType   string `json:"source_type"` // ad or post
+	SourceID     string `json:"source_id"`
+	SourceURL    string `json:"source_url,omitempty"`
+	Headline     string `json:"headline,omitempty"`
+	Body         string `json:"body,omitempty"`
+	MediaType    string `json:"media_type,omitempty"`
+	ImageURL     string `json:"image_url,omitempty"`
+	VideoURL     string `json:"video_url,omitempty"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+	ClickID      string `json:"click_id,omitempty"` // ctwa_clid, for conversion reporting
+}
+
+// OrderCard is a cart a contact sent from a catalog
+type OrderCard struct {
+	CatalogID string          `json:"catalog_id,omitempty"`
+	Items     []OrderCardItem `json:"items"`
+	ItemCount int             `json:"item_count"` // Units across items
+	Total     float64         `json:"total"`
+	Currency  string          `json:"currency,omitempty"` // Empty when items use several currencies
+}
+
+// OrderCardItem is a product line of an order card
+type OrderCardItem struct {
+	ProductRetailerID string  `json:"product_retailer_id"`
+	Quantity          int     `json:"quantity"`
+	ItemPrice         float64 `json:"item_price"`
+	Currency          string  `json:"currency,omitempty"`
+	Subtotal          float64 `json:"subtotal"`
+}
+
+// FlowResponseCard holds the answers a contact submitted in a WhatsApp Flow
+type FlowResponseCard struct {
+	Name      string                 `json:"name,omitempty"`
+	Body      string                 `json:"body,omitempty"`
+	FlowToken string                 `json:"flow_token,omitempty"`
+	Fields    map[string]interface{} `json:"fields"`
+}
+
+// Message metadata keys the context cards are built from
+const (
+	MetadataReferralSourceType   = "referral_source_type"
+	MetadataReferralSourceID     = "referral_source_id"
+	MetadataReferralSourceURL    = "referral_source_url"
+	MetadataReferralHeadline     = "referral_headline"
+	MetadataReferralBody         = "referral_body"
+	MetadataReferralMediaType    = "referral_media_type"
+	MetadataReferralImageURL     = "referral_image_url"
+	MetadataReferralVideoURL     = "referral_video_url"
+	MetadataReferralThumbnailURL = "referral_thumbnail_url"
+	MetadataReferralClickID      = "referral_ctwa_clid"
+	MetadataIsOrder              = "is_order"
+	MetadataOrderCatalogID       = "catalog_id"
+	MetadataOrderItems           = "order_items"
+	MetadataIsFlowResponse       = "is_flow_response"
+	MetadataFlowName             = "flow_name"
+	MetadataFlowBody             = "flow_body"
+	MetadataFlowResponseJSON     = "flow_response_json"
+	MetadataFlowToken            = "flow_token"
+)
+
+// BuildMessageContextCards builds the context cards of a message from its metadata.
+// Metadata that cannot be read yields no card rather than an error.
+func BuildMessageContextCards(metadata map[string]string) []MessageContextCard {
+	var cards []MessageContextCard
+	if card := adReferralCard(metadata); card != nil {
+		cards = append(cards, MessageContextCard{Type: MessageContextCardAdReferral, AdReferral: card})
+	}
+	if card := orderCard(metadata); card != nil {
+		cards = append(cards, MessageContextCard{Type: MessageContextCardOrder, Order: card})
+	}
+	if card := flowResponseCard(metadata); card != nil {
+		cards = append(cards, MessageContextCard{Type: MessageContextCardFlowResponse, FlowResponse: card})
+	}
+	return cards
+}
+
+// SetContextCards builds the context cards of the message from its metadata
+func (m *Message) SetContextCards() {
+	m.ContextCards = BuildMessageContextCards(m.Metadata)
+}
+
+func adReferralCard(metadata map[string]string) *AdReferralCard {
+	if metadata[MetadataReferralSourceID] == "" && metadata[MetadataReferralSourceURL] == "" {
+		return nil
+	}
+	return &AdReferralCard{
+		SourceType:   metadata[MetadataReferralSourceType],
+		SourceID:     metadata[MetadataReferralSourceID],
+		SourceURL:    metadata[MetadataReferralSourceURL],
+		Headline:     metadata[MetadataReferralHeadline],
+		Body:         metadata[MetadataReferralBody],
+		MediaType:    metadata[MetadataReferralMediaType],
+		ImageURL:     metadata[MetadataReferralImageURL],
+		VideoURL:     metadata[MetadataReferralVideoURL],
+		ThumbnailURL: metadata[MetadataReferralThumbnailURL],
+		ClickID:      metadata[MetadataReferralClickID],
+	}
+}
+
+func orderCard(metadata map[string]string) *OrderCard {
+	if metadata[MetadataIsOrder] != "true" {
+		return nil
+	}
+
+	var items []OrderCardItem
+	if data := metadata[MetadataOrderItems]; data != "" {
+		if err := json.Unmarshal([]byte(data), &items); err != nil {
+			return nil
+		}
+	}
+
+	card := &OrderCard{CatalogID: metadata[MetadataOrderCatalogID], Items: make([]OrderCardItem, 0, len(items))}
+	for i, item := range items {
+		item.Subtotal = roundAmount(item.ItemPrice * float64(item.Quantity))
+		card.Items = append(card.Items, item)
+		card.ItemCount += item.Quantity
+		card.Total += item.Subtotal
+		if i == 0 {
+			card.Currency = item.Currency
+		} else if item.Currency != card.Currency {
+			card.Currency = ""
+		}
+	}
+	card.Total = roundAmount(card.Total)
+	return card
+}
+
+func flowResponseCard(metadata map[string]string) *FlowResponseCard {
+	if metadata[MetadataIsFlowResponse] != "true" {
+		return nil
+	}
+
+	fields := make(map[string]interface{})
+	if data := metadata[MetadataFlowResponseJSON]; data != "" {
+		if err := json.Unmarshal([]byte(data), &fields); err != nil {
+			return nil
+		}
+	}
+
+	card := &FlowResponseCard{
+		Name:      metadata[MetadataFlowName],
+		Body:      metadata[MetadataFlowBody],
+		FlowToken: metadata[MetadataFlowToken],
+		Fields:    fields,
+	}
+	// The token is echoed inside the response; it is not an answer
+	if token, ok := fields["flow_token"].(string); ok {
+		if card.FlowToken == "" {
+			card.FlowToken = token
+		}
+		delete(fields, "flow_token")
+	}
+	return card
+}
+
+// roundAmount rounds a currency amount to cents
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,88 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMessageContextCards_AdReferral(t *testing.T) {
+	cards := BuildMessageContextCards(map[string]string{
+		"referral_source_type": "ad",
+		"referral_source_id":   "ad-12345",
+		"referral_source_url":  "https://fb.me/abc",
+		"referral_headline":    "Summer Sale",
+		"referral_media_type":  "image",
+		"referral_image_url":   "https://cdn.example.com/ad.jpg",
+		"referral_ctwa_clid":   "clid-1",
+	})
+
+	require.Len(t, cards, 1)
+	assert.Equal(t, MessageContextCardAdReferral, cards[0].Type)
+	assert.Equal(t, &AdReferralCard{
+		SourceType: "ad",
+		SourceID:   "ad-12345",
+		SourceURL:  "https://fb.me/abc",
+		Headline:   "Summer Sale",
+		MediaType:  "image",
+		ImageURL:   "https://cdn.example.com/ad.jpg",
+		ClickID:    "clid-1",
+	}, cards[0].AdReferral)
+}
+
+func TestBuildMessageContextCards_Order(t *testing.T) {
+	cards := BuildMessageContextCards(map[string]string{
+		"is_order":    "true",
+		"catalog_id":  "cat-1",
+		"order_items": `[{"product_retailer_id":"sku-1","quantity":2,"item_price":10.5,"currency":"BRL"},{"product_retailer_id":"sku-2","quantity":1,"item_price":0.1,"currency":"BRL"}]`,
+	})
+
+	require.Len(t, cards, 1)
+	order := cards[0].Order
+	require.NotNil(t, order)
+	assert.Equal(t, "cat-1", order.CatalogID)
+	require.Len(t, order.Items, 2)
+	assert.Equal(t, 21.0, order.Items[0].Subtotal)
+	assert.Equal(t, 3, order.ItemCount)
+	assert.Equal(t, 21.1, order.Total)
+	assert.Equal(t, "BRL", order.Currency)
+
+	t.Run("mixed currencies", func(t *testing.T) {
+		cards := BuildMessageContextCards(map[string]string{
+			"is_order":    "true",
+			"order_items": `[{"product_retailer_id":"a","quantity":1,"item_price":1,"currency":"BRL"},{"product_retailer_id":"b","quantity":1,"item_price":1,"currency":"USD"}]`,
+		})
+		require.Len(t, cards, 1)
+		assert.Empty(t, cards[0].Order.Currency)
+	})
+
+	t.Run("unreadable items", func(t *testing.T) {
+		assert.Empty(t, BuildMessageContextCards(map[string]string{"is_order": "true", "order_items": "{"}))
+	})
+}
+
+func TestBuildMessageContextCards_FlowResponse(t *testing.T) {
+	cards := BuildMessageContextCards(map[string]string{
+		"is_flow_response":   "true",
+		"flow_name":          "flow",
+		"flow_body":          "Sent",
+		"flow_response_json": `{"flow_token":"tok-1","name":"Ana","guests":2}`,
+	})
+
+	require.Len(t, cards, 1)
+	flow := cards[0].FlowResponse
+	require.NotNil(t, flow)
+	assert.Equal(t, "tok-1", flow.FlowToken)
+	assert.Equal(t, map[string]interface{}{"name": "Ana", "guests": float64(2)}, flow.Fields)
+}
+
+func TestBuildMessageContextCards_None(t *testing.T) {
+	assert.Empty(t, BuildMessageContextCards(nil))
+	assert.Empty(t, BuildMessageContextCards(map[string]string{"phone": "5511999999999"}))
+
+	message := &Message{Metadata: map[string]string{"is_flow_response": "true"}}
+	message.SetContextCards()
+	require.Len(t, message.ContextCards, 1)
+	assert.Empty(t, message.ContextCards[0].FlowResponse.Fields)
+}
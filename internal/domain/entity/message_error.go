@@ -0,0 +1,41 @@
+package entity
+
+// MessageErrorCategory is the normalized reason a message could not be delivered,
+// independent of the provider that reported it
+type MessageErrorCategory string
+
+const (
+	MessageErrorReengagementRequired MessageErrorCategory = "reengagement_required" // Outside the customer service window
+	MessageErrorRecipientOptedOut    MessageErrorCategory = "recipient_opted_out"
+	MessageErrorMarketingOptedOut    MessageErrorCategory = "marketing_opted_out"
+	MessageErrorInvalidRecipient     MessageErrorCategory = "invalid_recipient"
+	MessageErrorRecipientUnreachable MessageErrorCategory = "recipient_unreachable"
+	MessageErrorMailboxFull          MessageErrorCategory = "mailbox_full"
+	MessageErrorContentFiltered      MessageErrorCategory = "content_filtered"
+	MessageErrorRateLimited          MessageErrorCategory = "rate_limited"
+	MessageErrorTemplateError        MessageErrorCategory = "template_error"
+	MessageErrorMediaError           MessageErrorCategory = "media_error"
+	MessageErrorChannelAuth          MessageErrorCategory = "channel_auth"
+	MessageErrorChannelConfig        MessageErrorCategory = "channel_config"
+	MessageErrorPaymentRequired      MessageErrorCategory = "payment_required"
+	MessageErrorTemporary            MessageErrorCategory = "temporary_failure"
+	MessageErrorUnknown              MessageErrorCategory = "unknown"
+)
+
+// Automatic actions taken on a failed message
+const (
+	MessageErrorActionContactOptedOut   = "contact_opted_out"
+	MessageErrorActionContactSuppressed = "contact_suppressed" // Opted out of email after a hard bounce
+	MessageErrorActionTemplateSuggested = "template_suggested"
+)
+
+// MessageError is the classified failure of a message with a hint on how to fix it
+type MessageError struct {
+	Provider           string               `json:"provider"` // meta, twilio, smtp...
+	Code               string               `json:"code,omitempty"`
+	Category           MessageErrorCategory `json:"category"`
+	Retryable          bool                 `json:"retryable"`
+	Hint               string               `json:"hint"`
+	Actions            []string             `json:"actions,omitempty"`             // Automatic actions taken
+	SuggestedTemplates []string             `json:"suggested_templates,omitempty"` // Approved templates that can reach the contact
+}
@@ -0,0 +1,96 @@
+package entity
+
+import "time"
+
+// HookLanguage is the language a message hook is written in
+type HookLanguage string
+
+const (
+	HookLanguageJavaScript HookLanguage = "javascript"
+	HookLanguageLua        HookLanguage = "lua"
+)
+
+// HookEvent is the message event a hook runs on
+type HookEvent string
+
+const (
+	HookEventInbound  HookEvent = "message.inbound"
+	HookEventOutbound HookEvent = "message.outbound"
+)
+
+// IsValid checks if the hook event is known
+func (e HookEvent) IsValid() bool {
+	return e == HookEventInbound || e == HookEventOutbound
+}
+
+// HookLookup is an external HTTP lookup made before a hook runs. The URL may
+// reference message fields such as {{sender_id}} or {{metadata.phone}}; the JSON
+// response is available to the script under the lookup name.
+type HookLookup struct {
+	Name    string            `json:"name"`
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// MessageHook is a tenant script that transforms, enriches or blocks messages
+type MessageHook struct {
+	ID          string       `json:"id"`
+	TenantID    string       `json:"tenant_id"`
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Language    HookLanguage `json:"language"`
+	Script      string       `json:"script"`
+	Events      []HookEvent  `json:"events"`
+	ChannelIDs  []string     `json:"channel_ids,omitempty"` // Empty matches all channels
+	Lookups     []HookLookup `json:"lookups,omitempty"`
+	TimeoutMs   int          `json:"timeout_ms"`
+	MemoryMB    int          `json:"memory_mb"`
+	Priority    int          `json:"priority"` // Lower runs first
+	IsActive    bool         `json:"is_active"`
+	LastError   string       `json:"last_error,omitempty"`
+	LastRunAt   *time.Time   `json:"last_run_at,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// HandlesEvent checks if the hook runs on an event
+func (h *MessageHook) HandlesEvent(event HookEvent) bool {
+	for _, e := range h.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// AppliesToChannel checks if the hook runs for messages of a channel
+func (h *MessageHook) AppliesToChannel(channelID string) bool {
+	if len(h.ChannelIDs) == 0 {
+		return true
+	}
+	for _, id := range h.ChannelIDs {
+		if id == channelID {
+			return true
+		}
+	}
+	return false
+}
+
+// HookOutcome is the combined result of the hooks run on a message
+type HookOutcome struct {
+	Blocked bool   `json:"blocked"`
+	Reason  string `json:"reason,omitempty"`
+	HookID  string `json:"hook_id,omitempty"` // Hook that blocked the message
+}
+
+// HookTestResult is the result of a hook dry run
+type HookTestResult struct {
+	Content    string                 `json:"content"`
+	Metadata   map[string]string      `json:"metadata"`
+	Blocked    bool                   `json:"blocked"`
+	Reason     string                 `json:"reason,omitempty"`
+	Logs       []string               `json:"logs,omitempty"`
+	Lookups    map[string]interface{} `json:"lookups,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+	DurationMs int64                  `json:"duration_ms"`
+}
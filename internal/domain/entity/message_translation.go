@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// MessageTranslation is a machine translation of the content of a message, cached
+// per message and language so history is only translated once
+type MessageTranslation struct {
+	MessageID      string    `json:"-"`
+	Language       string    `json:"language"`
+	SourceLanguage string    `json:"source_language,omitempty"` // Detected language of the original, when known
+	Content        string    `json:"content"`
+	SourceDigest   string    `json:"-"` // Digest of the content that was translated, to notice edits
+	CreatedAt      time.Time `json:"created_at"`
+}
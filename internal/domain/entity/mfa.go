@@ -0,0 +1,31 @@
+package entity
+
+import "time"
+
+// UserMFA is the TOTP second factor of a user. The secret is generated at setup and
+// only protects logins once a code from the authenticator app confirmed it.
+type UserMFA struct {
+	UserID   string `json:"user_id"`
+	TenantID string `json:"tenant_id"`
+	Secret   string `json:"-"` // Base32 TOTP secret shared with the authenticator app
+	Enabled  bool   `json:"enabled"`
+
+	// RecoveryCodes are the SHA-256 hashes of the unused one-time recovery codes
+	RecoveryCodes []string `json:"-"`
+
+	// LastUsedStep is the time step of the last accepted code, so a code can't be replayed
+	LastUsedStep int64 `json:"-"`
+
+	// FailedAttempts counts consecutive wrong codes; too many lock verification until LockedUntil
+	FailedAttempts int        `json:"-"`
+	LockedUntil    *time.Time `json:"-"`
+
+	EnabledAt *time.Time `json:"enabled_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// IsLocked reports whether verification is locked after too many wrong codes
+func (m *UserMFA) IsLocked(now time.Time) bool {
+	return m.LockedUntil != nil && now.Before(*m.LockedUntil)
+}
@@ -0,0 +1,350 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// NewsletterFrequency represents how often a newsletter is sent
+type NewsletterFrequency string
+
+const (
+	NewsletterDaily   NewsletterFrequency = "daily"
+	NewsletterWeekly  NewsletterFrequency = "weekly"
+	NewsletterMonthly NewsletterFrequency = "monthly"
+)
+
+// IsValid checks if the frequency is known
+func (f NewsletterFrequency) IsValid() bool {
+	switch f {
+	case NewsletterDaily, NewsletterWeekly, NewsletterMonthly:
+		return true
+	}
+	return false
+}
+
+// NewsletterSchedule defines when a recurring newsletter is sent
+type NewsletterSchedule struct {
+	Frequency  NewsletterFrequency `json:"frequency"`
+	Time       string              `json:"time"`                   // Local send time, HH:MM
+	DayOfWeek  int                 `json:"day_of_week,omitempty"`  // Weekly: 0 (Sunday) to 6
+	DayOfMonth int                 `json:"day_of_month,omitempty"` // Monthly: 1 to 28
+	Timezone   string              `json:"timezone,omitempty"`     // IANA name; UTC when empty
+}
+
+// Validate checks the schedule fields
+func (s *NewsletterSchedule) Validate() error {
+	if !s.Frequency.IsValid() {
+		return fmt.Errorf("invalid frequency %q", s.Frequency)
+	}
+	if _, _, err := s.clock(); err != nil {
+		return err
+	}
+	if _, err := s.location(); err != nil {
+		return fmt.Errorf("invalid timezone %q", s.Timezone)
+	}
+	if s.Frequency == NewsletterWeekly && (s.DayOfWeek < 0 || s.DayOfWeek > 6) {
+		return fmt.Errorf("day_of_week must be between 0 and 6")
+	}
+	if s.Frequency == NewsletterMonthly && (s.DayOfMonth < 1 || s.DayOfMonth > 28) {
+		return fmt.Errorf("day_of_month must be between 1 and 28")
+	}
+	return nil
+}
+
+// Next returns the first send time strictly after the given time
+func (s *NewsletterSchedule) Next(after time.Time) (time.Time, error) {
+	if err := s.Validate(); err != nil {
+		return time.Time{}, err
+	}
+	hour, minute, _ := s.clock()
+	loc, _ := s.location()
+
+	local := after.In(loc)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	for i := 0; i <= 31; i++ {
+		candidate := time.Date(day.Year(), day.Month(), day.Day()+i, hour, minute, 0, 0, loc)
+		if !candidate.After(after) {
+			continue
+		}
+		switch s.Frequency {
+		case NewsletterWeekly:
+			if int(candidate.Weekday()) != s.DayOfWeek {
+				continue
+			}
+		case NewsletterMonthly:
+			if candidate.Day() != s.DayOfMonth {
+				continue
+			}
+		}
+		return candidate, nil
+	}
+	return time.Time{}, fmt.Errorf("no send time found")
+}
+
+func (s *NewsletterSchedule) clock() (int, int, error) {
+	t, err := time.Parse("15:04", s.Time)
+	if err != nil {
+		return 0, 0, fmt.Errorf("time must be HH:MM")
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+func (s *NewsletterSchedule) location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(s.Timezone)
+}
+
+// Newsletter is a recurring broadcast sent to the opted-in subscribers of a channel.
+// Sends count as campaign traffic, so they show in campaign costs and respect
+// marketing pauses.
+type Newsletter struct {
+	ID                   string               `json:"id"`
+	TenantID             string               `json:"tenant_id"`
+	Name                 string               `json:"name"`
+	Description          string               `json:"description,omitempty"`
+	ChannelID            string               `json:"channel_id"`
+	SegmentTags          []string             `json:"segment_tags,omitempty"` // Subscribers must have one of the tags; all when empty
+	SegmentID            string               `json:"segment_id,omitempty"`   // Subscribers must also be in the contact segment
+	ContentType          ContentType          `json:"content_type"`
+	Content              string               `json:"content"` // Supports {{variable}} placeholders
+	Metadata             map[string]string    `json:"metadata,omitempty"`
+	Schedule             NewsletterSchedule   `json:"schedule"`
+	FrequencyCap         int                  `json:"frequency_cap"`                    // Max newsletter messages per contact within the window
+	FrequencyCapHours    int                  `json:"frequency_cap_hours"`              // Window of the cap, across all newsletters of the tenant
+	SuppressInactiveDays int                  `json:"suppress_inactive_days,omitempty"` // Skip subscribers without reads or replies in this many days; the tenant setting when zero
+	OptInKeyword         string               `json:"opt_in_keyword,omitempty"`
+	UnsubscribeKeywords  []string             `json:"unsubscribe_keywords,omitempty"` // In addition to the default keywords
+	WelcomeMessage       string               `json:"welcome_message,omitempty"`
+	UnsubscribeMessage   string               `json:"unsubscribe_message,omitempty"`
+	SmartSend            *NewsletterSmartSend `json:"smart_send,omitempty"`
+	Image                *NewsletterImage     `json:"image,omitempty"` // Personalized image sent with Content as its caption
+	IsActive             bool                 `json:"is_active"`
+	NextRunAt            *time.Time           `json:"next_run_at,omitempty"`
+	LastRunAt            *time.Time           `json:"last_run_at,omitempty"`
+	CreatedAt            time.Time            `json:"created_at"`
+	UpdatedAt            time.Time            `json:"updated_at"`
+	// Compliance rules the content was saved with warnings for; only set on
+	// create and update responses
+	ComplianceWarnings []ComplianceFinding `json:"compliance_warnings,omitempty"`
+}
+
+// MatchesSegment checks if a contact belongs to the newsletter's segment
+func (n *Newsletter) MatchesSegment(contact *Contact) bool {
+	if len(n.SegmentTags) == 0 {
+		return true
+	}
+	for _, tag := range n.SegmentTags {
+		if contact.HasTag(tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// UsesSmartSend checks if editions are sent at each subscriber's best time
+func (n *Newsletter) UsesSmartSend() bool {
+	return n.SmartSend != nil && n.SmartSend.Enabled
+}
+
+// NewsletterImage renders a VRE template for each recipient, so every contact gets an
+// image with their own name, offer or QR code. String values of Data support
+// {{variable}} placeholders. Recipients whose data renders the same share one image.
+type NewsletterImage struct {
+	TemplateID string                 `json:"template_id"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+	Format     OutputFormat           `json:"format,omitempty"`
+	Width      int                    `json:"width,omitempty"`
+}
+
+// NewsletterSmartSend spreads the sends of an edition so each subscriber gets it at
+// the hour they usually read and reply, within a window after the edition starts.
+// A control group and subscribers without enough history are sent at the fixed time,
+// which is the baseline of the edition's send time comparison.
+type NewsletterSmartSend struct {
+	Enabled        bool `json:"enabled"`
+	WindowHours    int  `json:"window_hours"`    // Latest send, in hours after the edition starts
+	ControlPercent int  `json:"control_percent"` // Share of recipients sent at the fixed time
+	MinEngagements int  `json:"min_engagements"` // Reads and replies needed to pick a time
+}
+
+// ContactEngagement counts the reads and replies of a contact by hour of the day, in UTC
+type ContactEngagement struct {
+	ContactID  string  `json:"contact_id"`
+	ReadHours  [24]int `json:"read_hours"`
+	ReplyHours [24]int `json:"reply_hours"`
+}
+
+// Total returns the number of reads and replies
+func (e *ContactEngagement) Total() int {
+	total := 0
+	for hour := 0; hour < 24; hour++ {
+		total += e.ReadHours[hour] + e.ReplyHours[hour]
+	}
+	return total
+}
+
+// BestSendTime returns the start of the hour within the window after from in which
+// the contact engages the most, with replies weighing twice as much as reads. Ties go
+// to the earliest hour, and from itself is returned when that is the first hour.
+func (e *ContactEngagement) BestSendTime(from time.Time, window time.Duration) time.Time {
+	base := from.UTC().Truncate(time.Hour)
+	best, bestScore := 0, -1
+	for i := 0; i < int(window/time.Hour); i++ {
+		hour := base.Add(time.Duration(i) * time.Hour).Hour()
+		if score := e.ReadHours[hour] + 2*e.ReplyHours[hour]; score > bestScore {
+			best, bestScore = i, score
+		}
+	}
+	if best == 0 {
+		return from
+	}
+	return base.Add(time.Duration(best) * time.Hour)
+}
+
+// NewsletterSubscriptionStatus represents the consent state of a subscriber
+type NewsletterSubscriptionStatus string
+
+const (
+	NewsletterSubscribed   NewsletterSubscriptionStatus = "subscribed"
+	NewsletterUnsubscribed NewsletterSubscriptionStatus = "unsubscribed"
+)
+
+// NewsletterSubscription records a contact's consent to receive a newsletter
+type NewsletterSubscription struct {
+	ID             string                       `json:"id"`
+	TenantID       string                       `json:"tenant_id"`
+	NewsletterID   string                       `json:"newsletter_id"`
+	ContactID      string                       `json:"contact_id"`
+	Status         NewsletterSubscriptionStatus `json:"status"`
+	Source         string                       `json:"source"` // How consent was given: api, keyword, import...
+	SubscribedAt   time.Time                    `json:"subscribed_at"`
+	UnsubscribedAt *time.Time                   `json:"unsubscribed_at,omitempty"`
+	UpdatedAt      time.Time                    `json:"updated_at"`
+}
+
+// NewsletterEditionStatus represents the state of an edition
+type NewsletterEditionStatus string
+
+const (
+	NewsletterEditionSending NewsletterEditionStatus = "sending"
+	NewsletterEditionSent    NewsletterEditionStatus = "sent"
+)
+
+// NewsletterEdition is one send of a newsletter
+type NewsletterEdition struct {
+	ID           string                  `json:"id"`
+	TenantID     string                  `json:"tenant_id"`
+	NewsletterID string                  `json:"newsletter_id"`
+	ContentType  ContentType             `json:"content_type"`
+	Content      string                  `json:"content"`
+	Trigger      string                  `json:"trigger"` // schedule or manual
+	Status       NewsletterEditionStatus `json:"status"`
+	Recipients   int                     `json:"recipients"` // Subscribers in the segment
+	Sent         int                     `json:"sent"`
+	Capped       int                     `json:"capped"`        // Skipped by the newsletter or tenant frequency cap
+	GlobalCapped int                     `json:"global_capped"` // Of Capped, skipped by the tenant's marketing cap
+	Suppressed   int                     `json:"suppressed"`    // Skipped for not engaging within the inactivity window
+	Failed       int                     `json:"failed"`
+	Scheduled    int                     `json:"scheduled"` // Smart sends waiting for their time
+	SmartSend    bool                    `json:"smart_send"`
+	StartedAt    time.Time               `json:"started_at"`
+	CompletedAt  *time.Time              `json:"completed_at,omitempty"`
+	Stats        *NewsletterEditionStats `json:"stats,omitempty"`
+	Renders      *NewsletterRenderStats  `json:"renders,omitempty"` // Personalized images of the edition
+
+	SendTimeComparison *NewsletterSendTimeComparison `json:"send_time_comparison,omitempty"`
+}
+
+// NewsletterDeliveryStatus represents the outcome of sending an edition to a contact
+type NewsletterDeliveryStatus string
+
+const (
+	NewsletterDeliverySent   NewsletterDeliveryStatus = "sent"
+	NewsletterDeliveryCapped NewsletterDeliveryStatus = "capped"
+	NewsletterDeliveryFailed NewsletterDeliveryStatus = "failed"
+	// Suppressed deliveries skip contacts who stopped engaging
+	NewsletterDeliverySuppressed NewsletterDeliveryStatus = "suppressed"
+	// Scheduled deliveries wait for the send time picked by smart send
+	NewsletterDeliveryScheduled NewsletterDeliveryStatus = "scheduled"
+)
+
+// NewsletterSendTiming tells how the send time of a delivery was chosen
+type NewsletterSendTiming string
+
+const (
+	NewsletterTimingFixed NewsletterSendTiming = "fixed"
+	NewsletterTimingSmart NewsletterSendTiming = "smart"
+)
+
+// NewsletterDelivery is the send of an edition to one contact
+type NewsletterDelivery struct {
+	ID             string                   `json:"id"`
+	TenantID       string                   `json:"tenant_id"`
+	NewsletterID   string                   `json:"newsletter_id"`
+	EditionID      string                   `json:"edition_id"`
+	ContactID      string                   `json:"contact_id"`
+	ConversationID string                   `json:"conversation_id,omitempty"`
+	MessageID      string                   `json:"message_id,omitempty"`
+	Status         NewsletterDeliveryStatus `json:"status"`
+	Timing         NewsletterSendTiming     `json:"timing"`
+	ScheduledAt    *time.Time               `json:"scheduled_at,omitempty"`
+	Error          string                   `json:"error,omitempty"`
+	RepliedAt      *time.Time               `json:"replied_at,omitempty"`
+	UnsubscribedAt *time.Time               `json:"unsubscribed_at,omitempty"`
+	CreatedAt      time.Time                `json:"created_at"`
+}
+
+// NewsletterEditionStats is the engagement of an edition. Rates are relative to
+// the messages sent.
+type NewsletterEditionStats struct {
+	Sent            int64   `json:"sent"`
+	Capped          int64   `json:"capped"`
+	Suppressed      int64   `json:"suppressed"`
+	Failed          int64   `json:"failed"`
+	Delivered       int64   `json:"delivered"`
+	Read            int64   `json:"read"`
+	Replied         int64   `json:"replied"`
+	Unsubscribed    int64   `json:"unsubscribed"`
+	DeliveryRate    float64 `json:"delivery_rate"`
+	ReadRate        float64 `json:"read_rate"`
+	ReplyRate       float64 `json:"reply_rate"`
+	UnsubscribeRate float64 `json:"unsubscribe_rate"`
+}
+
+// NewsletterRenderStats counts the personalized images of editions. Cache hits are
+// sends that reused the image rendered for a recipient with identical data.
+type NewsletterRenderStats struct {
+	Rendered     int   `json:"rendered"`
+	CacheHits    int   `json:"cache_hits"`
+	Failed       int   `json:"failed"`
+	RenderTimeMs int64 `json:"render_time_ms"` // Total time spent rendering
+}
+
+// Add adds the counts of other stats
+func (s *NewsletterRenderStats) Add(other *NewsletterRenderStats) {
+	s.Rendered += other.Rendered
+	s.CacheHits += other.CacheHits
+	s.Failed += other.Failed
+	s.RenderTimeMs += other.RenderTimeMs
+}
+
+// NewsletterRenderSummary is the personalized image rendering of a newsletter
+// across its editions
+type NewsletterRenderSummary struct {
+	NewsletterRenderStats
+	Editions        int     `json:"editions"`
+	CacheHitRate    float64 `json:"cache_hit_rate"`     // Of the images sent
+	AvgRenderTimeMs float64 `json:"avg_render_time_ms"` // Per rendered image
+}
+
+// NewsletterSendTimeComparison compares the engagement of smart-timed sends of an
+// edition with its sends at the fixed time
+type NewsletterSendTimeComparison struct {
+	Smart         *NewsletterEditionStats `json:"smart"`
+	Fixed         *NewsletterEditionStats `json:"fixed"`
+	ReadRateLift  float64                 `json:"read_rate_lift"`  // Smart minus fixed read rate
+	ReplyRateLift float64                 `json:"reply_rate_lift"` // Smart minus fixed reply rate
+}
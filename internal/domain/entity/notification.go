@@ -0,0 +1,43 @@
+package entity
+
+import "time"
+
+// NotificationSeverity is how urgently a notification needs attention
+type NotificationSeverity string
+
+const (
+	NotificationSeverityInfo     NotificationSeverity = "info"
+	NotificationSeverityWarning  NotificationSeverity = "warning"
+	NotificationSeverityCritical NotificationSeverity = "critical"
+)
+
+// Notification types
+const (
+	NotificationTypeChannelCredentials = "channel_credentials" // A channel's credentials fail or expire soon
+)
+
+// Notification is an entry of a tenant's notification center, raised by the platform
+// about something the tenant should act on. Notifications about an ongoing condition
+// carry a key, so raising it again updates the open notification rather than adding
+// another, and it is resolved once the condition clears.
+type Notification struct {
+	ID           string               `json:"id"`
+	TenantID     string               `json:"tenant_id"`
+	Type         string               `json:"type"`
+	Severity     NotificationSeverity `json:"severity"`
+	Title        string               `json:"title"`
+	Message      string               `json:"message"`
+	ResourceType string               `json:"resource_type,omitempty"` // What the notification is about, e.g. channel
+	ResourceID   string               `json:"resource_id,omitempty"`
+	Key          string               `json:"-"` // Identifies the condition of an open notification
+	ReadAt       *time.Time           `json:"read_at,omitempty"`
+	ResolvedAt   *time.Time           `json:"resolved_at,omitempty"`
+	CreatedAt    time.Time            `json:"created_at"`
+	UpdatedAt    time.Time            `json:"updated_at"`
+}
+
+// NotificationFilter narrows the notifications listed
+type NotificationFilter struct {
+	UnreadOnly      bool
+	IncludeResolved bool
+}
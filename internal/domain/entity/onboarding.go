@@ -0,0 +1,127 @@
+package entity
+
+import "time"
+
+// OnboardingStep is a step of the guided tenant setup
+type OnboardingStep string
+
+const (
+	OnboardingStepWorkspace       OnboardingStep = "workspace"
+	OnboardingStepInviteUsers     OnboardingStep = "invite_users"
+	OnboardingStepConnectChannel  OnboardingStep = "connect_channel"
+	OnboardingStepCreateBot       OnboardingStep = "create_bot"
+	OnboardingStepSendTestMessage OnboardingStep = "send_test_message"
+)
+
+// OnboardingSteps lists the onboarding steps in the order the wizard presents them
+var OnboardingSteps = []OnboardingStep{
+	OnboardingStepWorkspace,
+	OnboardingStepInviteUsers,
+	OnboardingStepConnectChannel,
+	OnboardingStepCreateBot,
+	OnboardingStepSendTestMessage,
+}
+
+// IsValid reports whether the step is known
+func (s OnboardingStep) IsValid() bool {
+	for _, step := range OnboardingSteps {
+		if step == s {
+			return true
+		}
+	}
+	return false
+}
+
+// OnboardingStepStatus represents the progress of an onboarding step
+type OnboardingStepStatus string
+
+const (
+	OnboardingStepStatusPending   OnboardingStepStatus = "pending"
+	OnboardingStepStatusCompleted OnboardingStepStatus = "completed"
+	OnboardingStepStatusSkipped   OnboardingStepStatus = "skipped"
+)
+
+// OnboardingStepState is the progress of one onboarding step
+type OnboardingStepState struct {
+	Step        OnboardingStep       `json:"step"`
+	Status      OnboardingStepStatus `json:"status"`
+	ResourceID  string               `json:"resource_id,omitempty"` // Channel, bot or message created by the step
+	CompletedAt *time.Time           `json:"completed_at,omitempty"`
+}
+
+// Sample resources provisioned for a tenant to try Linktor without connecting a real channel
+const (
+	OnboardingSampleChannel      = "channel_id"
+	OnboardingSampleContact      = "contact_id"
+	OnboardingSampleConversation = "conversation_id"
+)
+
+// OnboardingProgress tracks a tenant's way through the onboarding wizard
+type OnboardingProgress struct {
+	ID              string                 `json:"id"`
+	TenantID        string                 `json:"tenant_id"`
+	Steps           []*OnboardingStepState `json:"steps"`
+	SampleResources map[string]string      `json:"sample_resources,omitempty"`
+	CompletedAt     *time.Time             `json:"completed_at,omitempty"`
+	CreatedAt       time.Time              `json:"created_at"`
+	UpdatedAt       time.Time              `json:"updated_at"`
+}
+
+// NewOnboardingProgress creates the progress of a tenant starting onboarding
+func NewOnboardingProgress(tenantID string) *OnboardingProgress {
+	now := time.Now()
+	progress := &OnboardingProgress{
+		TenantID:        tenantID,
+		SampleResources: make(map[string]string),
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	for _, step := range OnboardingSteps {
+		progress.Steps = append(progress.Steps, &OnboardingStepState{Step: step, Status: OnboardingStepStatusPending})
+	}
+	return progress
+}
+
+// Step returns the state of a step, adding it as pending when the progress was
+// saved before the step existed
+func (p *OnboardingProgress) Step(step OnboardingStep) *OnboardingStepState {
+	for _, state := range p.Steps {
+		if state.Step == step {
+			return state
+		}
+	}
+	state := &OnboardingStepState{Step: step, Status: OnboardingStepStatusPending}
+	p.Steps = append(p.Steps, state)
+	return state
+}
+
+// CurrentStep returns the first pending step, or "" when every step is done
+func (p *OnboardingProgress) CurrentStep() OnboardingStep {
+	for _, step := range OnboardingSteps {
+		if p.Step(step).Status == OnboardingStepStatusPending {
+			return step
+		}
+	}
+	return ""
+}
+
+// IsFinished reports whether every step was completed or skipped
+func (p *OnboardingProgress) IsFinished() bool {
+	return p.CurrentStep() == ""
+}
+
+// PercentComplete returns the share of steps completed or skipped
+func (p *OnboardingProgress) PercentComplete() int {
+	done := 0
+	for _, step := range OnboardingSteps {
+		if p.Step(step).Status != OnboardingStepStatusPending {
+			done++
+		}
+	}
+	return done * 100 / len(OnboardingSteps)
+}
+
+// IsSampleChannel reports whether a channel was provisioned as a sample
+func (p *OnboardingProgress) IsSampleChannel(channelID string) bool {
+	return channelID != "" && p.SampleResources[OnboardingSampleChannel] == channelID
+}
@@ -0,0 +1,102 @@
+package entity
+
+import "time"
+
+// OrganizationRole is a member's role across the workspaces of an organization
+type OrganizationRole string
+
+const (
+	OrganizationRoleOwner  OrganizationRole = "owner"
+	OrganizationRoleAdmin  OrganizationRole = "admin"
+	OrganizationRoleMember OrganizationRole = "member"
+)
+
+// IsValid reports whether the role is known
+func (r OrganizationRole) IsValid() bool {
+	return r == OrganizationRoleOwner || r == OrganizationRoleAdmin || r == OrganizationRoleMember
+}
+
+// Organization groups tenants, called workspaces here, under one account: an agency
+// running a workspace per brand. Channels and data stay isolated per workspace;
+// people and billing are shared.
+type Organization struct {
+	ID           string        `json:"id"`
+	Name         string        `json:"name"`
+	Slug         string        `json:"slug"`
+	Plan         Plan          `json:"plan"`   // Plan billed for the organization, given to new workspaces
+	Limits       *TenantLimits `json:"limits"` // Limits pooled across the workspaces
+	BillingEmail string        `json:"billing_email"`
+	CreatedAt    time.Time     `json:"created_at"`
+	UpdatedAt    time.Time     `json:"updated_at"`
+}
+
+// QuotaLimit returns the organization-wide limit of a metered metric, -1 for unlimited
+func (o *Organization) QuotaLimit(metric QuotaMetric) int64 {
+	limits := o.Limits
+	if limits == nil {
+		limits = GetPlanLimits(o.Plan)
+	}
+	limit := quotaLimit(limits, metric)
+	if limit == 0 {
+		limit = quotaLimit(GetPlanLimits(o.Plan), metric)
+	}
+	return limit
+}
+
+// OrganizationMember is a person sharing their account across the organization's
+// workspaces. The account is a user per workspace with the same email and password.
+type OrganizationMember struct {
+	OrganizationID string           `json:"organization_id"`
+	Email          string           `json:"email"`
+	Role           OrganizationRole `json:"role"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      time.Time        `json:"updated_at"`
+
+	Workspaces []MemberWorkspace `json:"workspaces,omitempty"` // Workspaces the member has an account in
+}
+
+// MemberWorkspace is a member's account in one workspace
+type MemberWorkspace struct {
+	TenantID string     `json:"tenant_id"`
+	UserID   string     `json:"user_id,omitempty"`
+	Role     UserRole   `json:"role"`
+	Status   UserStatus `json:"status,omitempty"`
+}
+
+// CanManage reports whether the member administers the organization
+func (m *OrganizationMember) CanManage() bool {
+	return m.Role == OrganizationRoleOwner || m.Role == OrganizationRoleAdmin
+}
+
+// OrganizationWorkspace is a tenant of an organization
+type OrganizationWorkspace struct {
+	TenantID  string       `json:"tenant_id"`
+	Name      string       `json:"name"`
+	Slug      string       `json:"slug"`
+	Plan      Plan         `json:"plan"`
+	Status    TenantStatus `json:"status"`
+	CreatedAt time.Time    `json:"created_at"`
+
+	// Role and UserID of the caller's account in the workspace, when they have one
+	Role    UserRole `json:"role,omitempty"`
+	UserID  string   `json:"user_id,omitempty"`
+	Current bool     `json:"current"` // The workspace of the caller's token
+}
+
+// WorkspaceUsage is the usage of one workspace in the organization's billing period
+type WorkspaceUsage struct {
+	TenantID string                `json:"tenant_id"`
+	Name     string                `json:"name"`
+	Usage    map[QuotaMetric]int64 `json:"usage"`
+}
+
+// OrganizationUsage is the usage of all workspaces of an organization against the
+// organization's pooled limits for the current billing period
+type OrganizationUsage struct {
+	OrganizationID string           `json:"organization_id"`
+	Plan           Plan             `json:"plan"`
+	PeriodStart    time.Time        `json:"period_start"`
+	PeriodEnd      time.Time        `json:"period_end"`
+	Quotas         []QuotaStatus    `json:"quotas"`
+	Workspaces     []WorkspaceUsage `json:"workspaces"`
+}
@@ -0,0 +1,74 @@
+package entity
+
+import "time"
+
+// PostbackAction represents what an interactive reply resumes
+type PostbackAction string
+
+const (
+	// PostbackActionContinueFlow resumes a flow at a given node
+	PostbackActionContinueFlow PostbackAction = "continue_flow"
+	// PostbackActionTriggerRule fires an automation rule
+	PostbackActionTriggerRule PostbackAction = "trigger_rule"
+	// PostbackActionRecordVote records the reply as a vote in a poll
+	PostbackActionRecordVote PostbackAction = "record_vote"
+	// PostbackActionAnswerFeedback records whether a bot answer helped the customer
+	PostbackActionAnswerFeedback PostbackAction = "answer_feedback"
+)
+
+// IsValid reports whether the action is known
+func (a PostbackAction) IsValid() bool {
+	switch a {
+	case PostbackActionContinueFlow, PostbackActionTriggerRule, PostbackActionRecordVote, PostbackActionAnswerFeedback:
+		return true
+	default:
+		return false
+	}
+}
+
+// Postback maps the ID of a button or list row to the automation it resumes.
+// The signed token of a postback is used as the button/row ID of interactive
+// messages, so replies can be routed without parsing titles.
+type Postback struct {
+	ID             string            `json:"id"`
+	TenantID       string            `json:"tenant_id"`
+	ConversationID string            `json:"conversation_id,omitempty"`
+	Action         PostbackAction    `json:"action"`
+	Target         string            `json:"target"`            // Flow, rule, poll or answer message ID
+	NodeID         string            `json:"node_id,omitempty"` // Flow node to resume at
+	Payload        map[string]string `json:"payload,omitempty"`
+	SingleUse      bool              `json:"single_use"`
+	ExpiresAt      time.Time         `json:"expires_at"`
+	UsedAt         *time.Time        `json:"used_at,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+}
+
+// IsExpired reports whether the postback can no longer be used
+func (p *Postback) IsExpired(now time.Time) bool {
+	return !p.ExpiresAt.IsZero() && now.After(p.ExpiresAt)
+}
+
+// IsConsumed reports whether a single-use postback was already used
+func (p *Postback) IsConsumed() bool {
+	return p.SingleUse && p.UsedAt != nil
+}
+
+// PostbackVote is a vote recorded from a postback reply. A contact has one vote
+// per poll; voting again replaces the previous option.
+type PostbackVote struct {
+	ID             string    `json:"id"`
+	TenantID       string    `json:"tenant_id"`
+	PollID         string    `json:"poll_id"`
+	Option         string    `json:"option"`
+	ContactID      string    `json:"contact_id"`
+	ConversationID string    `json:"conversation_id"`
+	MessageID      string    `json:"message_id"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// PollResult holds the vote count of each option of a poll
+type PollResult struct {
+	PollID string           `json:"poll_id"`
+	Total  int64            `json:"total"`
+	Votes  map[string]int64 `json:"votes"`
+}
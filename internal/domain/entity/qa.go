@@ -0,0 +1,113 @@
+package entity
+
+import "time"
+
+// QACriterion is a criterion of a scorecard, scored from 0 to MaxScore
+type QACriterion struct {
+	ID          string  `json:"id"`
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Weight      float64 `json:"weight"`
+	MaxScore    int     `json:"max_score"`
+}
+
+// QAScorecard is the review form reviewers fill in for a conversation
+type QAScorecard struct {
+	ID          string        `json:"id"`
+	TenantID    string        `json:"tenant_id"`
+	Name        string        `json:"name"`
+	Description string        `json:"description,omitempty"`
+	Criteria    []QACriterion `json:"criteria"`
+	IsActive    bool          `json:"is_active"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// GetCriterion returns a criterion by ID
+func (s *QAScorecard) GetCriterion(id string) *QACriterion {
+	for i := range s.Criteria {
+		if s.Criteria[i].ID == id {
+			return &s.Criteria[i]
+		}
+	}
+	return nil
+}
+
+// QASamplingRule selects resolved conversations of each agent for review
+type QASamplingRule struct {
+	ID            string     `json:"id"`
+	TenantID      string     `json:"tenant_id"`
+	Name          string     `json:"name"`
+	ScorecardID   string     `json:"scorecard_id"`
+	ChannelIDs    []string   `json:"channel_ids,omitempty"` // Empty matches all channels
+	MinMessages   int        `json:"min_messages"`          // Minimum messages in the conversation
+	SamplePercent int        `json:"sample_percent"`        // Share of each agent's conversations to review
+	MaxPerAgent   int        `json:"max_per_agent"`         // Cap per agent and run; 0 means no cap
+	IsActive      bool       `json:"is_active"`
+	LastRunAt     *time.Time `json:"last_run_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// QASampleCandidate is a resolved conversation eligible for review
+type QASampleCandidate struct {
+	ConversationID string `json:"conversation_id"`
+	AgentID        string `json:"agent_id"`
+}
+
+// QAReviewStatus represents the state of a review
+type QAReviewStatus string
+
+const (
+	QAReviewStatusPending   QAReviewStatus = "pending"
+	QAReviewStatusCompleted QAReviewStatus = "completed"
+)
+
+// QACriterionScore is a reviewer's score for one criterion
+type QACriterionScore struct {
+	CriterionID string `json:"criterion_id"`
+	Score       int    `json:"score"`
+	Comment     string `json:"comment,omitempty"`
+}
+
+// QAReview is the quality review of an agent's conversation
+type QAReview struct {
+	ID             string             `json:"id"`
+	TenantID       string             `json:"tenant_id"`
+	ConversationID string             `json:"conversation_id"`
+	AgentID        string             `json:"agent_id"`
+	ScorecardID    string             `json:"scorecard_id"`
+	RuleID         string             `json:"rule_id,omitempty"`
+	ReviewerID     string             `json:"reviewer_id,omitempty"`
+	Status         QAReviewStatus     `json:"status"`
+	Scores         []QACriterionScore `json:"scores,omitempty"`
+	Comment        string             `json:"comment,omitempty"`
+	TotalScore     float64            `json:"total_score"` // Weighted score from 0 to 100
+	CompletedAt    *time.Time         `json:"completed_at,omitempty"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+}
+
+// IsCompleted reports whether the review was submitted
+func (r *QAReview) IsCompleted() bool {
+	return r.Status == QAReviewStatusCompleted
+}
+
+// QAReviewFilter represents filters for listing reviews
+type QAReviewFilter struct {
+	AgentID     string
+	ReviewerID  string
+	ScorecardID string
+	Status      QAReviewStatus
+}
+
+// AgentQualitySummary aggregates completed reviews of an agent
+type AgentQualitySummary struct {
+	AgentID      string     `json:"agent_id"`
+	AgentName    string     `json:"agent_name,omitempty"`
+	Reviews      int64      `json:"reviews"`
+	AverageScore float64    `json:"average_score"`
+	MinScore     float64    `json:"min_score"`
+	MaxScore     float64    `json:"max_score"`
+	LastReviewAt *time.Time `json:"last_review_at,omitempty"`
+}
@@ -0,0 +1,88 @@
+package entity
+
+import "time"
+
+// QuotaMetric is a kind of metered tenant usage
+type QuotaMetric string
+
+const (
+	QuotaMetricAPICalls   QuotaMetric = "api_calls"
+	QuotaMetricAIRequests QuotaMetric = "ai_requests"
+	QuotaMetricVRERenders QuotaMetric = "vre_renders"
+	QuotaMetricStorage    QuotaMetric = "storage_bytes"
+)
+
+// QuotaMetrics are the metrics reported on the quota dashboard
+var QuotaMetrics = []QuotaMetric{
+	QuotaMetricAPICalls,
+	QuotaMetricAIRequests,
+	QuotaMetricVRERenders,
+	QuotaMetricStorage,
+}
+
+// IsCounter reports whether usage of the metric is counted per period. Storage is a
+// running total that does not reset with the period.
+func (m QuotaMetric) IsCounter() bool {
+	return m != QuotaMetricStorage
+}
+
+// QuotaUsage is the usage of a metric by a tenant on one day, per API route for API calls
+type QuotaUsage struct {
+	TenantID string      `json:"tenant_id"`
+	Metric   QuotaMetric `json:"metric"`
+	Route    string      `json:"route,omitempty"`
+	Day      time.Time   `json:"day"`
+	Count    int64       `json:"count"`
+}
+
+// QuotaStatus is a tenant's consumption of one metric against its plan limit
+type QuotaStatus struct {
+	Metric      QuotaMetric `json:"metric"`
+	Used        int64       `json:"used"`
+	Limit       int64       `json:"limit"` // -1 for unlimited
+	Remaining   int64       `json:"remaining"`
+	PercentUsed float64     `json:"percent_used"`
+	Unlimited   bool        `json:"unlimited"`
+	Exhausted   bool        `json:"exhausted"`
+
+	// DailyRate is the average daily usage of the period so far
+	DailyRate float64 `json:"daily_rate"`
+	// ExhaustsAt forecasts when the limit is reached at the current daily rate
+	ExhaustsAt *time.Time `json:"exhausts_at,omitempty"`
+	// ExhaustsBeforeReset is set when the forecast falls inside the current period
+	ExhaustsBeforeReset bool `json:"exhausts_before_reset"`
+}
+
+// RouteUsage is the number of API calls to a route in the period
+type RouteUsage struct {
+	Route string `json:"route"`
+	Calls int64  `json:"calls"`
+}
+
+// QuotaReport is a tenant's quota dashboard for the current period
+type QuotaReport struct {
+	TenantID        string        `json:"tenant_id"`
+	Plan            Plan          `json:"plan"`
+	PeriodStart     time.Time     `json:"period_start"`
+	PeriodEnd       time.Time     `json:"period_end"`
+	Quotas          []QuotaStatus `json:"quotas"`
+	Routes          []RouteUsage  `json:"routes"`
+	AlertThresholds []int         `json:"alert_thresholds"`
+}
+
+// QuotaAlert records that a usage threshold alert was sent, so each threshold alerts
+// once per period
+type QuotaAlert struct {
+	TenantID    string      `json:"tenant_id"`
+	Metric      QuotaMetric `json:"metric"`
+	PeriodStart time.Time   `json:"period_start"`
+	Threshold   int         `json:"threshold"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// QuotaPeriod returns the billing period containing t: the calendar month in UTC
+func QuotaPeriod(t time.Time) (start, end time.Time) {
+	t = t.UTC()
+	start = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, 0)
+}
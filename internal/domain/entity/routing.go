@@ -0,0 +1,139 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// RoutingStrategy is how a routing rule picks among its eligible agents
+type RoutingStrategy string
+
+const (
+	RoutingStrategyLeastLoaded RoutingStrategy = "least_loaded" // Fewest active conversations
+	RoutingStrategyRoundRobin  RoutingStrategy = "round_robin"  // Next agent after the last one assigned by the rule
+)
+
+// IsValid reports whether the strategy is known
+func (s RoutingStrategy) IsValid() bool {
+	return s == RoutingStrategyLeastLoaded || s == RoutingStrategyRoundRobin
+}
+
+// RoutingConditions select the conversations a rule routes. Empty lists match any value;
+// set lists must all match.
+type RoutingConditions struct {
+	ChannelIDs []string               `json:"channel_ids,omitempty"`
+	Languages  []string               `json:"languages,omitempty"` // Conversation language, e.g. "pt" or "en"
+	Tags       []string               `json:"tags,omitempty"`      // Any of these labels
+	Priorities []ConversationPriority `json:"priorities,omitempty"`
+}
+
+// RoutingRule assigns escalated conversations to agents. Enabled rules are evaluated
+// by ascending position; the first matching rule with an eligible agent assigns the
+// conversation. A matching rule without eligible agents leaves it queued rather than
+// handing it to an agent who lacks the required skills. With TranslationFallback, a
+// language-matching rule without native speakers available assigns a
+// translation-assisted agent instead of queueing.
+type RoutingRule struct {
+	ID                     string            `json:"id"`
+	TenantID               string            `json:"tenant_id"`
+	Name                   string            `json:"name"`
+	Position               int               `json:"position"`
+	Enabled                bool              `json:"enabled"`
+	Conditions             RoutingConditions `json:"conditions"`
+	RequiredSkills         []string          `json:"required_skills,omitempty"` // Agents must have all of them
+	MatchLanguage          bool              `json:"match_language"`            // Agents must speak the conversation language
+	TranslationFallback    bool              `json:"translation_fallback"`      // Falls back to translation-assisted agents
+	MatchChannel           bool              `json:"match_channel"`             // Agents must be competent on the conversation channel
+	AgentIDs               []string          `json:"agent_ids,omitempty"`       // Restricts the pool; empty means every agent
+	Strategy               RoutingStrategy   `json:"strategy"`
+	MaxActiveConversations int               `json:"max_active_conversations,omitempty"` // Per agent; 0 uses the agent's own limit
+	LastAssignedUserID     string            `json:"last_assigned_user_id,omitempty"`    // Round-robin cursor
+	CreatedAt              time.Time         `json:"created_at"`
+	UpdatedAt              time.Time         `json:"updated_at"`
+}
+
+// Matches reports whether the rule routes a conversation in the given language
+func (r *RoutingRule) Matches(conversation *Conversation, language string) bool {
+	c := r.Conditions
+	if len(c.ChannelIDs) > 0 && !containsFold(c.ChannelIDs, conversation.ChannelID) {
+		return false
+	}
+	if len(c.Languages) > 0 && (language == "" || !containsFold(c.Languages, language)) {
+		return false
+	}
+	if len(c.Priorities) > 0 {
+		found := false
+		for _, p := range c.Priorities {
+			if p == conversation.Priority {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(c.Tags) > 0 {
+		found := false
+		for _, tag := range conversation.Tags {
+			if containsFold(c.Tags, tag) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// AgentRoutingProfile holds what routing rules know about an agent
+type AgentRoutingProfile struct {
+	UserID                 string    `json:"user_id"`
+	TenantID               string    `json:"tenant_id"`
+	Skills                 []string  `json:"skills"`
+	Languages              []string  `json:"languages"`
+	Channels               []string  `json:"channels"`                           // Channel IDs or types, e.g. "whatsapp", the agent is competent on
+	TranslationAssisted    bool      `json:"translation_assisted"`               // Works with machine translation in any language
+	MaxActiveConversations int       `json:"max_active_conversations,omitempty"` // 0 means no limit
+	UpdatedAt              time.Time `json:"updated_at"`
+}
+
+// HasSkills reports whether the agent has every skill
+func (p *AgentRoutingProfile) HasSkills(skills []string) bool {
+	for _, skill := range skills {
+		if !containsFold(p.Skills, skill) {
+			return false
+		}
+	}
+	return true
+}
+
+// SpeaksLanguage reports whether the agent speaks the language. Regional variants
+// match their base language, so an agent speaking "pt" takes "pt-BR".
+func (p *AgentRoutingProfile) SpeaksLanguage(language string) bool {
+	base := strings.SplitN(language, "-", 2)[0]
+	for _, spoken := range p.Languages {
+		if strings.EqualFold(spoken, language) || strings.EqualFold(spoken, base) {
+			return true
+		}
+	}
+	return false
+}
+
+// HandlesChannel reports whether the agent is competent on a channel, tagged either
+// by its ID or by its type
+func (p *AgentRoutingProfile) HandlesChannel(channelID string, channelType ChannelType) bool {
+	return containsFold(p.Channels, channelID) || (channelType != "" && containsFold(p.Channels, string(channelType)))
+}
+
+// containsFold reports whether values contain value, ignoring case
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
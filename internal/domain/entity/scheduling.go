@@ -0,0 +1,149 @@
+package entity
+
+import (
+	"sort"
+	"time"
+)
+
+// CalendarProvider represents the external calendar a booking calendar syncs with
+type CalendarProvider string
+
+const (
+	CalendarProviderGoogle    CalendarProvider = "google"
+	CalendarProviderMicrosoft CalendarProvider = "microsoft"
+)
+
+// IsValid reports whether the provider is supported
+func (p CalendarProvider) IsValid() bool {
+	return p == CalendarProviderGoogle || p == CalendarProviderMicrosoft
+}
+
+// CalendarCredentials holds the OAuth tokens of an external calendar
+type CalendarCredentials struct {
+	RefreshToken string    `json:"refresh_token"`
+	AccessToken  string    `json:"access_token,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at,omitempty"`
+}
+
+// BookingCalendar is a calendar contacts can book time on from a conversation.
+// Slots are generated from the weekly availability in the calendar timezone and
+// exclude the busy periods of the external calendar.
+type BookingCalendar struct {
+	ID                 string               `json:"id"`
+	TenantID           string               `json:"tenant_id"`
+	Name               string               `json:"name"`
+	Provider           CalendarProvider     `json:"provider"`
+	ExternalCalendarID string               `json:"external_calendar_id,omitempty"` // Provider's default calendar when empty
+	Timezone           string               `json:"timezone"`
+	SlotMinutes        int                  `json:"slot_minutes"`
+	BufferMinutes      int                  `json:"buffer_minutes"`     // Free time kept around every booking
+	MinNoticeMinutes   int                  `json:"min_notice_minutes"` // How soon the first slot can start
+	MaxDaysAhead       int                  `json:"max_days_ahead"`
+	Availability       []DaySchedule        `json:"availability"`
+	ReminderMinutes    []int                `json:"reminder_minutes,omitempty"` // Reminders sent this many minutes before a booking
+	EventTitle         string               `json:"event_title,omitempty"`
+	IsActive           bool                 `json:"is_active"`
+	Connected          bool                 `json:"connected"` // True when credentials are stored
+	Credentials        *CalendarCredentials `json:"-"`
+	CreatedAt          time.Time            `json:"created_at"`
+	UpdatedAt          time.Time            `json:"updated_at"`
+}
+
+// TimeSlot is a bookable period
+type TimeSlot struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Overlaps reports whether the slot overlaps the period from start to end
+func (s TimeSlot) Overlaps(start, end time.Time) bool {
+	return s.Start.Before(end) && start.Before(s.End)
+}
+
+// BookingStatus represents the status of a booking
+type BookingStatus string
+
+const (
+	// BookingStatusPending means slots were offered and the contact has not picked one
+	BookingStatusPending   BookingStatus = "pending"
+	BookingStatusConfirmed BookingStatus = "confirmed"
+	BookingStatusCancelled BookingStatus = "cancelled"
+)
+
+// Booking is an appointment booked from a conversation
+type Booking struct {
+	ID              string        `json:"id"`
+	TenantID        string        `json:"tenant_id"`
+	CalendarID      string        `json:"calendar_id"`
+	ConversationID  string        `json:"conversation_id"`
+	ContactID       string        `json:"contact_id"`
+	Title           string        `json:"title"`
+	Status          BookingStatus `json:"status"`
+	OfferedSlots    []TimeSlot    `json:"offered_slots,omitempty"`
+	StartAt         *time.Time    `json:"start_at,omitempty"`
+	EndAt           *time.Time    `json:"end_at,omitempty"`
+	ExternalEventID string        `json:"external_event_id,omitempty"`
+	Token           string        `json:"-"`                        // Authorizes the reschedule and cancel links
+	RemindersSent   []int         `json:"reminders_sent,omitempty"` // Reminder offsets already sent
+	NextReminderAt  *time.Time    `json:"next_reminder_at,omitempty"`
+	CancelReason    string        `json:"cancel_reason,omitempty"`
+	CreatedBy       string        `json:"created_by,omitempty"`
+	CancelledAt     *time.Time    `json:"cancelled_at,omitempty"`
+	CreatedAt       time.Time     `json:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at"`
+}
+
+// DueReminders returns the reminder offsets due at now that were not sent yet,
+// smallest first
+func (b *Booking) DueReminders(offsets []int, now time.Time) []int {
+	if b.StartAt == nil {
+		return nil
+	}
+	var due []int
+	for _, offset := range offsets {
+		if b.reminderSent(offset) {
+			continue
+		}
+		if !b.StartAt.Add(-time.Duration(offset) * time.Minute).After(now) {
+			due = append(due, offset)
+		}
+	}
+	sort.Ints(due)
+	return due
+}
+
+// ScheduleNextReminder sets NextReminderAt to the earliest reminder not sent
+// yet, or nil when none is left before the booking starts
+func (b *Booking) ScheduleNextReminder(offsets []int, now time.Time) {
+	b.NextReminderAt = nil
+	if b.Status != BookingStatusConfirmed || b.StartAt == nil || !b.StartAt.After(now) {
+		return
+	}
+	for _, offset := range offsets {
+		if b.reminderSent(offset) {
+			continue
+		}
+		at := b.StartAt.Add(-time.Duration(offset) * time.Minute)
+		if b.NextReminderAt == nil || at.Before(*b.NextReminderAt) {
+			b.NextReminderAt = &at
+		}
+	}
+}
+
+func (b *Booking) reminderSent(offset int) bool {
+	for _, sent := range b.RemindersSent {
+		if sent == offset {
+			return true
+		}
+	}
+	return false
+}
+
+// BookingFilter represents filters for listing bookings
+type BookingFilter struct {
+	CalendarID     string
+	ConversationID string
+	Status         BookingStatus
+	From           *time.Time
+	To             *time.Time
+}
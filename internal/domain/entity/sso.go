@@ -0,0 +1,78 @@
+package entity
+
+import "time"
+
+// SSOProtocol is the protocol of a tenant's identity provider
+type SSOProtocol string
+
+const (
+	SSOProtocolOIDC SSOProtocol = "oidc"
+	SSOProtocolSAML SSOProtocol = "saml"
+)
+
+// SSOConfig is the identity provider a tenant's users sign in with
+type SSOConfig struct {
+	TenantID string      `json:"tenant_id"`
+	Protocol SSOProtocol `json:"protocol"`
+	Enabled  bool        `json:"enabled"`
+
+	// OpenID Connect provider. The client secret may be a secret:// reference.
+	Issuer       string   `json:"issuer,omitempty"`
+	ClientID     string   `json:"client_id,omitempty"`
+	ClientSecret string   `json:"-"`
+	Scopes       []string `json:"scopes,omitempty"`
+
+	// SAML provider; the certificate is the PEM certificate assertions are signed with
+	IDPEntityID    string `json:"idp_entity_id,omitempty"`
+	IDPSSOURL      string `json:"idp_sso_url,omitempty"`
+	IDPCertificate string `json:"idp_certificate,omitempty"`
+
+	// Claims or attributes holding the user's profile; common names are used when empty
+	EmailAttribute  string `json:"email_attribute,omitempty"`
+	NameAttribute   string `json:"name_attribute,omitempty"`
+	GroupsAttribute string `json:"groups_attribute,omitempty"`
+
+	// JITProvisioning creates users on their first login with the role of their groups,
+	// or DefaultRole; otherwise only existing users can sign in
+	JITProvisioning bool     `json:"jit_provisioning"`
+	DefaultRole     UserRole `json:"default_role"`
+
+	// GroupRoles maps groups of the provider to roles. Users get the highest role of
+	// their groups at every login.
+	GroupRoles map[string]UserRole `json:"group_roles,omitempty"`
+
+	// RedirectURLs are the app pages a login may return to with the tokens
+	RedirectURLs []string `json:"redirect_urls,omitempty"`
+
+	// SavedByOwner is set when an owner saved the config; only then may owners sign in
+	// through the provider, as it could otherwise be used to take over their accounts
+	SavedByOwner bool `json:"saved_by_owner"`
+
+	HasClientSecret bool      `json:"has_client_secret"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// ssoRoleRank orders the roles SSO can grant; owners are never managed by SSO
+var ssoRoleRank = map[UserRole]int{
+	UserRoleAgent:      1,
+	UserRoleFinance:    2,
+	UserRoleSupervisor: 3,
+	UserRoleAdmin:      4,
+}
+
+// IsSSORole reports whether SSO can grant a role
+func IsSSORole(role UserRole) bool {
+	return ssoRoleRank[role] > 0
+}
+
+// RoleForGroups returns the highest role mapped to the groups, false when none is mapped
+func (c *SSOConfig) RoleForGroups(groups []string) (UserRole, bool) {
+	var role UserRole
+	for _, group := range groups {
+		if mapped, ok := c.GroupRoles[group]; ok && ssoRoleRank[mapped] > ssoRoleRank[role] {
+			role = mapped
+		}
+	}
+	return role, role != ""
+}
@@ -0,0 +1,194 @@
+package entity
+
+import "time"
+
+// StatusPage is a tenant's hosted status page for its messaging channels
+type StatusPage struct {
+	ID            string    `json:"id"`
+	TenantID      string    `json:"tenant_id"`
+	Slug          string    `json:"slug"`
+	Title         string    `json:"title"`
+	Description   string    `json:"description,omitempty"`
+	IsPublished   bool      `json:"is_published"`
+	ChannelIDs    []string  `json:"channel_ids,omitempty"` // Channels shown on the page; empty shows all enabled channels
+	AutoIncidents bool      `json:"auto_incidents"`        // Open and resolve incidents from channel health
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// ShowsChannel reports whether a channel is listed on the page
+func (p *StatusPage) ShowsChannel(channelID string) bool {
+	if len(p.ChannelIDs) == 0 {
+		return true
+	}
+	for _, id := range p.ChannelIDs {
+		if id == channelID {
+			return true
+		}
+	}
+	return false
+}
+
+// IncidentKind distinguishes unplanned incidents from planned maintenance
+type IncidentKind string
+
+const (
+	IncidentKindIncident    IncidentKind = "incident"
+	IncidentKindMaintenance IncidentKind = "maintenance"
+)
+
+// IncidentImpact represents how badly an incident affects its channels
+type IncidentImpact string
+
+const (
+	IncidentImpactMinor    IncidentImpact = "minor"
+	IncidentImpactMajor    IncidentImpact = "major"
+	IncidentImpactCritical IncidentImpact = "critical"
+)
+
+// IsValid reports whether the impact is known
+func (i IncidentImpact) IsValid() bool {
+	switch i {
+	case IncidentImpactMinor, IncidentImpactMajor, IncidentImpactCritical:
+		return true
+	}
+	return false
+}
+
+// IncidentStatus represents the progress of an incident
+type IncidentStatus string
+
+const (
+	IncidentStatusScheduled     IncidentStatus = "scheduled"
+	IncidentStatusInvestigating IncidentStatus = "investigating"
+	IncidentStatusIdentified    IncidentStatus = "identified"
+	IncidentStatusMonitoring    IncidentStatus = "monitoring"
+	IncidentStatusResolved      IncidentStatus = "resolved"
+)
+
+// IsValid reports whether the status is known
+func (s IncidentStatus) IsValid() bool {
+	switch s {
+	case IncidentStatusScheduled, IncidentStatusInvestigating, IncidentStatusIdentified,
+		IncidentStatusMonitoring, IncidentStatusResolved:
+		return true
+	}
+	return false
+}
+
+// IncidentUpdate is a timeline entry posted on an incident
+type IncidentUpdate struct {
+	Status    IncidentStatus `json:"status"`
+	Message   string         `json:"message"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// Incident is an outage or maintenance window declared on a tenant's channels
+type Incident struct {
+	ID          string           `json:"id"`
+	TenantID    string           `json:"tenant_id"`
+	Kind        IncidentKind     `json:"kind"`
+	Title       string           `json:"title"`
+	Message     string           `json:"message"`
+	Impact      IncidentImpact   `json:"impact"`
+	Status      IncidentStatus   `json:"status"`
+	ChannelIDs  []string         `json:"channel_ids,omitempty"` // Affected channels; empty affects all channels
+	Updates     []IncidentUpdate `json:"updates,omitempty"`
+	StartsAt    time.Time        `json:"starts_at"`
+	EndsAt      *time.Time       `json:"ends_at,omitempty"` // Scheduled end of a maintenance window
+	ResolvedAt  *time.Time       `json:"resolved_at,omitempty"`
+	AutoCreated bool             `json:"auto_created"` // Opened from channel health
+	CreatedAt   time.Time        `json:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// IsResolved reports whether the incident was resolved
+func (i *Incident) IsResolved() bool {
+	return i.Status == IncidentStatusResolved
+}
+
+// IsActive reports whether the incident is ongoing at the given time
+func (i *Incident) IsActive(now time.Time) bool {
+	if i.IsResolved() || now.Before(i.StartsAt) {
+		return false
+	}
+	return i.EndsAt == nil || now.Before(*i.EndsAt)
+}
+
+// Affects reports whether the incident affects a channel
+func (i *Incident) Affects(channelID string) bool {
+	if len(i.ChannelIDs) == 0 {
+		return true
+	}
+	for _, id := range i.ChannelIDs {
+		if id == channelID {
+			return true
+		}
+	}
+	return false
+}
+
+// IncidentFilter represents filters for listing incidents
+type IncidentFilter struct {
+	Kind       IncidentKind
+	Unresolved bool
+	Since      *time.Time // Only incidents starting or resolved after this time
+	Limit      int
+}
+
+// ComponentStatus is the status of a channel shown on a status page
+type ComponentStatus string
+
+const (
+	ComponentStatusOperational      ComponentStatus = "operational"
+	ComponentStatusDegraded         ComponentStatus = "degraded_performance"
+	ComponentStatusPartialOutage    ComponentStatus = "partial_outage"
+	ComponentStatusMajorOutage      ComponentStatus = "major_outage"
+	ComponentStatusUnderMaintenance ComponentStatus = "under_maintenance"
+)
+
+// componentStatusSeverity orders component statuses from healthy to down
+var componentStatusSeverity = map[ComponentStatus]int{
+	ComponentStatusOperational:      0,
+	ComponentStatusUnderMaintenance: 1,
+	ComponentStatusDegraded:         2,
+	ComponentStatusPartialOutage:    3,
+	ComponentStatusMajorOutage:      4,
+}
+
+// WorseThan reports whether the status is more severe than another
+func (s ComponentStatus) WorseThan(other ComponentStatus) bool {
+	return componentStatusSeverity[s] > componentStatusSeverity[other]
+}
+
+// StatusPageComponent is a channel listed on a status page
+type StatusPageComponent struct {
+	ChannelID string          `json:"channel_id"`
+	Name      string          `json:"name"`
+	Type      ChannelType     `json:"type"`
+	Status    ComponentStatus `json:"status"`
+}
+
+// PublicStatusPage is the rendered content of a published status page
+type PublicStatusPage struct {
+	Title               string                `json:"title"`
+	Description         string                `json:"description,omitempty"`
+	Status              ComponentStatus       `json:"status"`
+	Components          []StatusPageComponent `json:"components"`
+	ActiveIncidents     []*Incident           `json:"active_incidents"`
+	UpcomingMaintenance []*Incident           `json:"upcoming_maintenance"`
+	RecentIncidents     []*Incident           `json:"recent_incidents"`
+	UpdatedAt           time.Time             `json:"updated_at"`
+}
+
+// IncidentBanner is the notice shown in webchat widgets while an incident is active
+type IncidentBanner struct {
+	IncidentID string         `json:"incident_id"`
+	Kind       IncidentKind   `json:"kind"`
+	Title      string         `json:"title"`
+	Message    string         `json:"message"`
+	Impact     IncidentImpact `json:"impact"`
+	Status     IncidentStatus `json:"status"`
+	StartsAt   time.Time      `json:"starts_at"`
+	EndsAt     *time.Time     `json:"ends_at,omitempty"`
+}
@@ -0,0 +1,97 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// StreamSettings are the limits of a NATS stream that administrators can tune
+type StreamSettings struct {
+	Stream         string    `json:"stream"`          // Logical name, e.g. messages
+	Name           string    `json:"name"`            // NATS stream name
+	Retention      string    `json:"retention"`       // limits, interest or workqueue; fixed when the stream is created
+	RetentionHours int       `json:"retention_hours"` // How long messages are kept at most
+	MaxMessages    int64     `json:"max_messages"`    // -1 for no limit
+	Replicas       int       `json:"replicas"`
+	Messages       uint64    `json:"messages"`
+	FirstMessageAt time.Time `json:"first_message_at,omitempty"`
+}
+
+// StreamSettingsUpdate changes the limits of a stream; unset fields keep their value
+type StreamSettingsUpdate struct {
+	RetentionHours *int   `json:"retention_hours"`
+	MaxMessages    *int64 `json:"max_messages"`
+	Replicas       *int   `json:"replicas"`
+	// ConfirmDataLoss must be set when lowering the retention window or the
+	// message limit, which deletes the messages beyond the new limit
+	ConfirmDataLoss bool `json:"confirm_data_loss"`
+}
+
+// StreamReplayRequest copies the messages a stream still holds from a time range
+// into a replay consumer group, where a test consumer can read them without
+// affecting the live pipeline
+type StreamReplayRequest struct {
+	Group       string    `json:"group" binding:"required"` // Consumer group name, lowercase letters, digits and dashes
+	From        time.Time `json:"from" binding:"required"`
+	To          time.Time `json:"to" binding:"required"`
+	Subject     string    `json:"subject"`      // Only messages on this subject, wildcards allowed
+	MaxMessages int       `json:"max_messages"` // Defaults to 1000
+}
+
+// StreamReplayResult describes a finished replay
+type StreamReplayResult struct {
+	Stream    string `json:"stream"`
+	Group     string `json:"group"`
+	Consumer  string `json:"consumer"`  // Durable consumer to read the copies from
+	Subject   string `json:"subject"`   // Subject the copies were published on
+	Copied    int    `json:"copied"`    // Number of messages copied
+	Truncated bool   `json:"truncated"` // The range held more messages than the limit
+}
+
+// DeadLetter is a stream message that kept failing until its consumer gave up on
+// it, kept aside for inspection and replay
+type DeadLetter struct {
+	Sequence         uint64            `json:"sequence"`          // Sequence in the dead-letter stream, used to replay it
+	Stream           string            `json:"stream"`            // Logical stream it failed on, e.g. messages
+	Subject          string            `json:"subject"`           // Subject it is replayed on
+	OriginalSequence uint64            `json:"original_sequence"` // Sequence it had in its stream
+	Consumer         string            `json:"consumer"`          // Consumer that gave up on it
+	Deliveries       int               `json:"deliveries"`
+	Error            string            `json:"error,omitempty"` // Error of the last delivery
+	FailedAt         time.Time         `json:"failed_at"`
+	Size             int               `json:"size"`
+	Headers          map[string]string `json:"headers,omitempty"`     // Only when inspecting a dead letter
+	Payload          json.RawMessage   `json:"payload,omitempty"`     // Only when inspecting a dead letter with a JSON payload
+	RawPayload       []byte            `json:"raw_payload,omitempty"` // Only when inspecting a dead letter with another payload
+}
+
+// DeadLetterReplayRequest replays dead letters on their original subjects. The
+// system administration token is shared, so operators name themselves for the audit.
+type DeadLetterReplayRequest struct {
+	ReplayedBy  string   `json:"replayed_by" binding:"required"` // Operator replaying, e.g. their email
+	Reason      string   `json:"reason"`
+	Sequences   []uint64 `json:"sequences"`    // Dead letters to replay in bulk, all of the stream's when empty
+	MaxMessages int      `json:"max_messages"` // Caps a bulk replay, defaults to 100
+}
+
+// DeadLetterReplay is the audit record of a replayed dead letter
+type DeadLetterReplay struct {
+	ID                 string    `json:"id"`
+	Stream             string    `json:"stream"`
+	Subject            string    `json:"subject"`
+	DeadLetterSequence uint64    `json:"dead_letter_sequence"`
+	OriginalSequence   uint64    `json:"original_sequence"`
+	Consumer           string    `json:"consumer"`
+	Error              string    `json:"error,omitempty"` // Error the message had failed with
+	ReplayedBy         string    `json:"replayed_by"`
+	Reason             string    `json:"reason,omitempty"`
+	ReplayedAt         time.Time `json:"replayed_at"`
+}
+
+// DeadLetterReplayResult describes a bulk replay
+type DeadLetterReplayResult struct {
+	Stream    string              `json:"stream"`
+	Replayed  []*DeadLetterReplay `json:"replayed"`
+	Failed    []uint64            `json:"failed,omitempty"` // Dead letters that could not be replayed
+	Truncated bool                `json:"truncated"`        // The stream held more dead letters than the limit
+}
@@ -0,0 +1,58 @@
+package entity
+
+import "time"
+
+// SyncPosition is how far a client has read one kind of change: the change time and
+// ID of the last record it received
+type SyncPosition struct {
+	At time.Time `json:"t"`
+	ID string    `json:"id,omitempty"`
+}
+
+// SyncConversation is the compact shape of a conversation sent to mobile agents
+type SyncConversation struct {
+	ID            string               `json:"id"`
+	ChannelID     string               `json:"channel_id"`
+	ContactID     string               `json:"contact_id"`
+	ContactName   string               `json:"contact_name,omitempty"`
+	AssigneeID    string               `json:"assignee_id,omitempty"`
+	Status        ConversationStatus   `json:"status"`
+	Priority      ConversationPriority `json:"priority,omitempty"` // Omitted when normal
+	Title         string               `json:"title,omitempty"`
+	Unread        int                  `json:"unread,omitempty"`
+	LastMessageAt *time.Time           `json:"last_message_at,omitempty"`
+	UpdatedAt     time.Time            `json:"updated_at"`
+}
+
+// SyncMessage is the compact shape of a message sent to mobile agents. Attachments are
+// only counted; clients load them with the full message when shown.
+type SyncMessage struct {
+	ID             string        `json:"id"`
+	ConversationID string        `json:"conversation_id"`
+	SenderType     SenderType    `json:"sender_type"`
+	SenderID       string        `json:"sender_id,omitempty"`
+	ContentType    ContentType   `json:"content_type,omitempty"` // Omitted for text
+	Content        string        `json:"content,omitempty"`
+	Status         MessageStatus `json:"status"`
+	Attachments    int           `json:"attachments,omitempty"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+// SyncPresence is the compact shape of an agent's availability
+type SyncPresence struct {
+	UserID    string         `json:"user_id"`
+	Status    PresenceStatus `json:"status"`
+	ChangedAt time.Time      `json:"changed_at"`
+}
+
+// SyncChanges are the conversations, messages and agent presence that changed since a
+// client's cursor, oldest first. Clients pass Cursor on the next call and call again
+// right away while HasMore is set.
+type SyncChanges struct {
+	Conversations []*SyncConversation `json:"conversations"`
+	Messages      []*SyncMessage      `json:"messages"`
+	Presence      []*SyncPresence     `json:"presence"`
+	Cursor        string              `json:"cursor"`
+	HasMore       bool                `json:"has_more"`
+}
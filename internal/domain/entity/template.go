@@ -85,6 +85,9 @@ type Template struct {
 	LastSyncedAt      *time.Time       `json:"last_synced_at,omitempty"`
 	CreatedAt         time.Time        `json:"created_at"`
 	UpdatedAt         time.Time        `json:"updated_at"`
+	// ComplianceWarnings lists the compliance rules the content was submitted
+	// with warnings for. Only set on create and edit responses; not stored.
+	ComplianceWarnings []ComplianceFinding `json:"compliance_warnings,omitempty"`
 }
 
 // TemplateComponent represents a component of a template at creation time.
@@ -1,6 +1,10 @@
 package entity
 
 import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -29,6 +33,11 @@ type TenantLimits struct {
 	MaxChannels         int   `json:"max_channels"`
 	MaxContacts         int   `json:"max_contacts"`
 	MaxMessagesPerMonth int64 `json:"max_messages_per_month"`
+
+	MaxAPICallsPerMonth   int64 `json:"max_api_calls_per_month"`
+	MaxAIRequestsPerMonth int64 `json:"max_ai_requests_per_month"`
+	MaxVRERendersPerMonth int64 `json:"max_vre_renders_per_month"`
+	MaxStorageBytes       int64 `json:"max_storage_bytes"`
 }
 
 // Tenant represents an organization/company
@@ -44,6 +53,313 @@ type Tenant struct {
 	UpdatedAt time.Time         `json:"updated_at"`
 }
 
+// TenantSettingConversationTitles enables AI generated conversation titles when set to "true"
+const TenantSettingConversationTitles = "conversation_titles"
+
+// ConversationTitlesEnabled reports whether conversations of the tenant get AI generated titles
+func (t *Tenant) ConversationTitlesEnabled() bool {
+	return t.Settings[TenantSettingConversationTitles] == "true"
+}
+
+// Tenant settings of the duplicate outbound send guard
+const (
+	// TenantSettingDuplicateSendWindow is how many seconds an identical message to the same
+	// contact counts as a duplicate; "0" turns the guard off
+	TenantSettingDuplicateSendWindow = "duplicate_send_window"
+	// TenantSettingDuplicateSendAction is "block" to reject duplicates or "flag" to send
+	// them marked as duplicates
+	TenantSettingDuplicateSendAction = "duplicate_send_action"
+)
+
+// Actions taken on duplicate outbound sends
+const (
+	DuplicateSendActionBlock = "block"
+	DuplicateSendActionFlag  = "flag"
+)
+
+// DefaultDuplicateSendWindow catches double-clicked sends and automations firing twice
+const DefaultDuplicateSendWindow = 10 * time.Second
+
+// DuplicateSendWindow returns the window in which identical sends to a contact are duplicates
+func (t *Tenant) DuplicateSendWindow() time.Duration {
+	value, ok := t.Settings[TenantSettingDuplicateSendWindow]
+	if !ok || value == "" {
+		return DefaultDuplicateSendWindow
+	}
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return DefaultDuplicateSendWindow
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// DuplicateSendAction returns what happens to duplicate outbound sends of the tenant
+func (t *Tenant) DuplicateSendAction() string {
+	if t.Settings[TenantSettingDuplicateSendAction] == DuplicateSendActionFlag {
+		return DuplicateSendActionFlag
+	}
+	return DuplicateSendActionBlock
+}
+
+// TenantSettingPhoneRegion is the ISO 3166 country code ("BR") used to read phone
+// numbers written without a country code
+const TenantSettingPhoneRegion = "phone_region"
+
+// PhoneRegion returns the tenant's default phone region, or "" when phone numbers
+// must carry their country code
+func (t *Tenant) PhoneRegion() string {
+	return strings.ToUpper(strings.TrimSpace(t.Settings[TenantSettingPhoneRegion]))
+}
+
+// TenantSettingQuotaAlertThresholds lists the usage percentages ("80,90,100") at which
+// quota alerts are sent
+const TenantSettingQuotaAlertThresholds = "quota_alert_thresholds"
+
+// DefaultQuotaAlertThresholds are the usage percentages alerted when none are configured
+var DefaultQuotaAlertThresholds = []int{80, 90, 100}
+
+// QuotaAlertThresholds returns the ascending usage percentages at which the tenant is
+// alerted; invalid entries are ignored
+func (t *Tenant) QuotaAlertThresholds() []int {
+	value := strings.TrimSpace(t.Settings[TenantSettingQuotaAlertThresholds])
+	if value == "" {
+		return append([]int(nil), DefaultQuotaAlertThresholds...)
+	}
+
+	seen := make(map[int]bool)
+	var thresholds []int
+	for _, part := range strings.Split(value, ",") {
+		threshold, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || threshold <= 0 || threshold > 1000 || seen[threshold] {
+			continue
+		}
+		seen[threshold] = true
+		thresholds = append(thresholds, threshold)
+	}
+	sort.Ints(thresholds)
+	return thresholds
+}
+
+// Tenant settings of the automatic unassignment of conversations from unavailable agents
+const (
+	// TenantSettingAutoUnassign enables the automatic unassignment when set to "true"
+	TenantSettingAutoUnassign = "auto_unassign"
+	// TenantSettingAutoUnassignOfflineGrace is how many seconds an agent may be offline
+	// before their conversations waiting for a first reply are re-routed
+	TenantSettingAutoUnassignOfflineGrace = "auto_unassign_offline_grace"
+	// TenantSettingAutoUnassignIdleGrace is how many seconds an agent may be away
+	// before their conversations waiting for a first reply are re-routed
+	TenantSettingAutoUnassignIdleGrace = "auto_unassign_idle_grace"
+)
+
+// Default grace periods of the automatic unassignment
+const (
+	DefaultAutoUnassignOfflineGrace = 5 * time.Minute
+	DefaultAutoUnassignIdleGrace    = 15 * time.Minute
+)
+
+// AutoUnassignEnabled reports whether conversations of unavailable agents are re-routed
+func (t *Tenant) AutoUnassignEnabled() bool {
+	return t.Settings[TenantSettingAutoUnassign] == "true"
+}
+
+// AutoUnassignGrace returns how long an agent may stay offline or away before their
+// conversations waiting for a first reply are re-routed. Agents of other statuses
+// keep their conversations.
+func (t *Tenant) AutoUnassignGrace(status PresenceStatus) (time.Duration, bool) {
+	switch status {
+	case PresenceStatusOffline:
+		return t.durationSetting(TenantSettingAutoUnassignOfflineGrace, DefaultAutoUnassignOfflineGrace), true
+	case PresenceStatusAway:
+		return t.durationSetting(TenantSettingAutoUnassignIdleGrace, DefaultAutoUnassignIdleGrace), true
+	default:
+		return 0, false
+	}
+}
+
+// Tenant settings of the agent copilot
+const (
+	// TenantSettingAgentCopilot enables copilot suggestions for agents handling escalated
+	// conversations when set to "true"
+	TenantSettingAgentCopilot = "agent_copilot"
+	// TenantSettingAgentCopilotKnowledgeBases lists the knowledge bases ("id1,id2") the
+	// copilot searches for articles; all of the tenant's when empty
+	TenantSettingAgentCopilotKnowledgeBases = "agent_copilot_knowledge_bases"
+)
+
+// AgentCopilotEnabled reports whether agents of the tenant get copilot suggestions
+func (t *Tenant) AgentCopilotEnabled() bool {
+	return t.Settings[TenantSettingAgentCopilot] == "true"
+}
+
+// AgentCopilotKnowledgeBases returns the knowledge bases the copilot is limited to, if any
+func (t *Tenant) AgentCopilotKnowledgeBases() []string {
+	var ids []string
+	for _, id := range strings.Split(t.Settings[TenantSettingAgentCopilotKnowledgeBases], ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// TenantSettingSummarizeOnResolve turns off the AI summary of resolved conversations
+// when set to "false"
+const TenantSettingSummarizeOnResolve = "summarize_on_resolve"
+
+// SummarizeOnResolveEnabled reports whether conversations of the tenant are summarized
+// when they are resolved
+func (t *Tenant) SummarizeOnResolveEnabled() bool {
+	return t.Settings[TenantSettingSummarizeOnResolve] != "false"
+}
+
+// TenantSettingDispositionRequired makes agents pick a disposition when resolving
+// conversations when set to "true"
+const TenantSettingDispositionRequired = "disposition_required"
+
+// DispositionRequired reports whether conversations of the tenant need a disposition
+// to be resolved
+func (t *Tenant) DispositionRequired() bool {
+	return t.Settings[TenantSettingDispositionRequired] == "true"
+}
+
+// Reopen settings of a tenant, in hours. A contact writing within the reopen window
+// after resolution reopens the conversation; within the follow-up window a new
+// conversation is linked to the resolved one. Both are off when unset.
+const (
+	TenantSettingReopenWindow   = "reopen_window_hours"
+	TenantSettingFollowUpWindow = "follow_up_window_hours"
+)
+
+// ReopenWindow returns how long after resolution a customer message reopens a conversation
+func (t *Tenant) ReopenWindow() time.Duration {
+	return t.hoursSetting(TenantSettingReopenWindow)
+}
+
+// FollowUpWindow returns how long after resolution a new conversation counts as a follow-up
+func (t *Tenant) FollowUpWindow() time.Duration {
+	return t.hoursSetting(TenantSettingFollowUpWindow)
+}
+
+// Monthly AI budget of a tenant in USD, off when unset
+const (
+	TenantSettingAIBudgetSoft = "ai_budget_soft_usd"
+	TenantSettingAIBudgetHard = "ai_budget_hard_usd"
+)
+
+// AIBudget returns the tenant's monthly AI spending limits
+func (t *Tenant) AIBudget() AIBudget {
+	return AIBudget{
+		SoftLimitUSD: t.amountSetting(TenantSettingAIBudgetSoft),
+		HardLimitUSD: t.amountSetting(TenantSettingAIBudgetHard),
+	}
+}
+
+// SetAIBudget stores the tenant's monthly AI spending limits, removing zero ones
+func (t *Tenant) SetAIBudget(budget AIBudget) {
+	if t.Settings == nil {
+		t.Settings = make(map[string]string)
+	}
+	for key, amount := range map[string]float64{
+		TenantSettingAIBudgetSoft: budget.SoftLimitUSD,
+		TenantSettingAIBudgetHard: budget.HardLimitUSD,
+	} {
+		if amount > 0 {
+			t.Settings[key] = strconv.FormatFloat(amount, 'f', -1, 64)
+		} else {
+			delete(t.Settings, key)
+		}
+	}
+}
+
+// Marketing throttles of a tenant, applied to every campaign send. The frequency
+// cap counts marketing messages to a contact across all channels; inactive contacts
+// are suppressed only when the inactivity window is set.
+const (
+	TenantSettingMarketingFrequencyCap     = "marketing_frequency_cap"      // Max marketing messages per contact within the window
+	TenantSettingMarketingFrequencyCapDays = "marketing_frequency_cap_days" // Window of the cap, a week when unset
+	TenantSettingMarketingInactiveDays     = "marketing_inactive_days"      // Skip contacts without reads or replies in this many days
+)
+
+// DefaultMarketingFrequencyCapWindow is the window of the tenant marketing cap when unset
+const DefaultMarketingFrequencyCapWindow = 7 * 24 * time.Hour
+
+// MarketingFrequencyCap returns how many marketing messages a contact may get within
+// the window across channels; zero means no cap
+func (t *Tenant) MarketingFrequencyCap() (int, time.Duration) {
+	limit, err := strconv.Atoi(strings.TrimSpace(t.Settings[TenantSettingMarketingFrequencyCap]))
+	if err != nil || limit <= 0 {
+		return 0, 0
+	}
+	window := t.daysSetting(TenantSettingMarketingFrequencyCapDays)
+	if window == 0 {
+		window = DefaultMarketingFrequencyCapWindow
+	}
+	return limit, window
+}
+
+// MarketingInactiveWindow returns how long a contact may go without reading or
+// replying before campaigns skip them; zero turns suppression off
+func (t *Tenant) MarketingInactiveWindow() time.Duration {
+	return t.daysSetting(TenantSettingMarketingInactiveDays)
+}
+
+// Multi-factor enforcement of a tenant. Users it applies to must enroll a TOTP
+// authenticator before their first login completes and can't turn it off.
+const (
+	TenantSettingMFARequired      = "mfa_required"       // "true" requires MFA of every user
+	TenantSettingMFARequiredRoles = "mfa_required_roles" // Comma-separated roles requiring MFA, e.g. "owner,admin"
+)
+
+// RequiresMFA reports whether users with a role must use multi-factor authentication
+func (t *Tenant) RequiresMFA(role UserRole) bool {
+	if t.Settings[TenantSettingMFARequired] == "true" {
+		return true
+	}
+	for _, required := range strings.Split(t.Settings[TenantSettingMFARequiredRoles], ",") {
+		if strings.TrimSpace(required) == string(role) {
+			return true
+		}
+	}
+	return false
+}
+
+// daysSetting reads a whole number of days, zero when unset or invalid
+func (t *Tenant) daysSetting(key string) time.Duration {
+	days, err := strconv.Atoi(strings.TrimSpace(t.Settings[key]))
+	if err != nil || days <= 0 {
+		return 0
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// amountSetting reads a non-negative amount, zero when unset or invalid
+func (t *Tenant) amountSetting(key string) float64 {
+	amount, err := strconv.ParseFloat(strings.TrimSpace(t.Settings[key]), 64)
+	if err != nil || !(amount > 0) || math.IsInf(amount, 0) {
+		return 0
+	}
+	return amount
+}
+
+// hoursSetting reads a setting in hours, zero when unset or invalid
+func (t *Tenant) hoursSetting(key string) time.Duration {
+	hours, err := strconv.ParseFloat(strings.TrimSpace(t.Settings[key]), 64)
+	if err != nil || hours <= 0 {
+		return 0
+	}
+	return time.Duration(hours * float64(time.Hour))
+}
+
+// durationSetting reads a setting in seconds, falling back to a default when unset or invalid
+func (t *Tenant) durationSetting(key string, fallback time.Duration) time.Duration {
+	seconds, err := strconv.Atoi(strings.TrimSpace(t.Settings[key]))
+	if err != nil || seconds < 0 {
+		return fallback
+	}
+	return time.Duration(seconds) * time.Second
+}
+
 // NewTenant creates a new tenant
 func NewTenant(name, slug string, plan Plan) *Tenant {
 	now := time.Now()
@@ -68,6 +384,11 @@ func GetPlanLimits(plan Plan) *TenantLimits {
 			MaxChannels:         3,
 			MaxContacts:         1000,
 			MaxMessagesPerMonth: 10000,
+
+			MaxAPICallsPerMonth:   100000,
+			MaxAIRequestsPerMonth: 1000,
+			MaxVRERendersPerMonth: 500,
+			MaxStorageBytes:       1 << 30,
 		}
 	case PlanStarter:
 		return &TenantLimits{
@@ -75,6 +396,11 @@ func GetPlanLimits(plan Plan) *TenantLimits {
 			MaxChannels:         10,
 			MaxContacts:         10000,
 			MaxMessagesPerMonth: 50000,
+
+			MaxAPICallsPerMonth:   500000,
+			MaxAIRequestsPerMonth: 10000,
+			MaxVRERendersPerMonth: 5000,
+			MaxStorageBytes:       10 << 30,
 		}
 	case PlanProfessional:
 		return &TenantLimits{
@@ -82,6 +408,11 @@ func GetPlanLimits(plan Plan) *TenantLimits {
 			MaxChannels:         25,
 			MaxContacts:         100000,
 			MaxMessagesPerMonth: 250000,
+
+			MaxAPICallsPerMonth:   2000000,
+			MaxAIRequestsPerMonth: 50000,
+			MaxVRERendersPerMonth: 25000,
+			MaxStorageBytes:       50 << 30,
 		}
 	case PlanEnterprise:
 		return &TenantLimits{
@@ -89,12 +420,46 @@ func GetPlanLimits(plan Plan) *TenantLimits {
 			MaxChannels:         -1,
 			MaxContacts:         -1,
 			MaxMessagesPerMonth: -1,
+
+			MaxAPICallsPerMonth:   -1,
+			MaxAIRequestsPerMonth: -1,
+			MaxVRERendersPerMonth: -1,
+			MaxStorageBytes:       -1,
 		}
 	default:
 		return GetPlanLimits(PlanFree)
 	}
 }
 
+// QuotaLimit returns the tenant's limit for a metered metric, -1 for unlimited. Limits
+// stored before the metric existed fall back to the plan's.
+func (t *Tenant) QuotaLimit(metric QuotaMetric) int64 {
+	limits := t.Limits
+	if limits == nil {
+		limits = GetPlanLimits(t.Plan)
+	}
+	limit := quotaLimit(limits, metric)
+	if limit == 0 {
+		limit = quotaLimit(GetPlanLimits(t.Plan), metric)
+	}
+	return limit
+}
+
+func quotaLimit(limits *TenantLimits, metric QuotaMetric) int64 {
+	switch metric {
+	case QuotaMetricAPICalls:
+		return limits.MaxAPICallsPerMonth
+	case QuotaMetricAIRequests:
+		return limits.MaxAIRequestsPerMonth
+	case QuotaMetricVRERenders:
+		return limits.MaxVRERendersPerMonth
+	case QuotaMetricStorage:
+		return limits.MaxStorageBytes
+	default:
+		return -1
+	}
+}
+
 // IsActive returns true if tenant is active
 func (t *Tenant) IsActive() bool {
 	return t.Status == TenantStatusActive
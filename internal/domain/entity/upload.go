@@ -0,0 +1,93 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// UploadStatus is the state of a resumable upload
+type UploadStatus string
+
+const (
+	// UploadStatusUploading accepts chunks until the declared size is received
+	UploadStatusUploading UploadStatus = "uploading"
+	// UploadStatusProcessing verifies, scans and transcodes the assembled file
+	UploadStatusProcessing UploadStatus = "processing"
+	UploadStatusCompleted  UploadStatus = "completed"
+	UploadStatusFailed     UploadStatus = "failed"
+	// UploadStatusInfected is a file rejected by the virus scan
+	UploadStatusInfected UploadStatus = "infected"
+)
+
+// UploadPurpose is what an upload is used for
+type UploadPurpose string
+
+const (
+	UploadPurposeCampaign  UploadPurpose = "campaign"
+	UploadPurposeKnowledge UploadPurpose = "knowledge"
+	UploadPurposeMedia     UploadPurpose = "media"
+)
+
+// IsValid reports whether the purpose is known
+func (p UploadPurpose) IsValid() bool {
+	switch p {
+	case UploadPurposeCampaign, UploadPurposeKnowledge, UploadPurposeMedia:
+		return true
+	default:
+		return false
+	}
+}
+
+// ScanStatus is the outcome of the virus scan of an upload
+type ScanStatus string
+
+const (
+	ScanStatusPending  ScanStatus = "pending"
+	ScanStatusClean    ScanStatus = "clean"
+	ScanStatusInfected ScanStatus = "infected"
+	// ScanStatusSkipped is recorded when no scanner is configured
+	ScanStatusSkipped ScanStatus = "skipped"
+)
+
+// Upload is a large file uploaded in chunks that can be resumed after an interruption.
+// Chunks are staged encrypted with a per-upload key; once the declared size arrives the
+// file is checked against its checksum, scanned for viruses and, for videos, transcoded
+// to a format and size channels accept before it is stored.
+type Upload struct {
+	ID            string        `json:"id"`
+	TenantID      string        `json:"tenant_id"`
+	CreatedBy     string        `json:"created_by,omitempty"`
+	Filename      string        `json:"filename"`
+	MimeType      string        `json:"mime_type"`
+	Purpose       UploadPurpose `json:"purpose"`
+	ChannelType   ChannelType   `json:"channel_type,omitempty"` // Transcoding target for videos
+	Size          int64         `json:"size"`
+	Offset        int64         `json:"offset"`
+	Chunks        []int64       `json:"-"`                  // Offsets of the staged chunks
+	Checksum      string        `json:"checksum,omitempty"` // Expected SHA-256 of the file, hex
+	Status        UploadStatus  `json:"status"`
+	ScanStatus    ScanStatus    `json:"scan_status"`
+	ScanResult    string        `json:"scan_result,omitempty"` // Signature found by the scanner
+	Transcoded    bool          `json:"transcoded"`
+	StorageKey    string        `json:"-"`
+	URL           string        `json:"url,omitempty"`
+	ResultSize    int64         `json:"result_size,omitempty"`
+	ResultMime    string        `json:"result_mime_type,omitempty"`
+	JobID         string        `json:"job_id,omitempty"`
+	Error         string        `json:"error,omitempty"`
+	EncryptionKey string        `json:"-"` // Chunk key, sealed with the tenant key when enabled
+	ExpiresAt     time.Time     `json:"expires_at"`
+	CompletedAt   *time.Time    `json:"completed_at,omitempty"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+}
+
+// IsComplete reports whether every byte of the upload was received
+func (u *Upload) IsComplete() bool {
+	return u.Offset >= u.Size
+}
+
+// IsVideo reports whether the upload is a video
+func (u *Upload) IsVideo() bool {
+	return strings.HasPrefix(u.MimeType, "video/")
+}
@@ -12,6 +12,7 @@ const (
 	UserRoleSupervisor UserRole = "supervisor"
 	UserRoleAdmin      UserRole = "admin"
 	UserRoleOwner      UserRole = "owner"
+	UserRoleFinance    UserRole = "finance" // Reviews refunds above the approval thresholds
 )
 
 // UserStatus represents a user's status
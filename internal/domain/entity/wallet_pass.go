@@ -0,0 +1,127 @@
+package entity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WalletPassKind is the kind of pass a template issues
+type WalletPassKind string
+
+const (
+	WalletPassKindBooking WalletPassKind = "booking" // Confirmation of a linked object, e.g. a booking
+	WalletPassKindLoyalty WalletPassKind = "loyalty" // Loyalty card of a contact
+)
+
+// WalletPassField is a labeled value shown on a pass. Template values are rendered with
+// the variables of the pass's linked object and contact.
+type WalletPassField struct {
+	Key      string `json:"key"`
+	Label    string `json:"label,omitempty"`
+	Value    string `json:"value"`
+	Position string `json:"position,omitempty"` // header, primary, secondary, auxiliary or back
+}
+
+// WalletPassTemplate defines the look and content of the Apple Wallet and Google Wallet
+// passes a tenant issues
+type WalletPassTemplate struct {
+	ID               string            `json:"id"`
+	TenantID         string            `json:"tenant_id"`
+	Name             string            `json:"name"`
+	Kind             WalletPassKind    `json:"kind"`
+	ObjectType       LinkedObjectType  `json:"object_type,omitempty"` // Linked objects booking passes are issued for
+	OrganizationName string            `json:"organization_name"`
+	Description      string            `json:"description"`
+	LogoText         string            `json:"logo_text,omitempty"`
+	LogoURL          string            `json:"logo_url,omitempty"`
+	BackgroundColor  string            `json:"background_color,omitempty"` // #RRGGBB
+	ForegroundColor  string            `json:"foreground_color,omitempty"`
+	LabelColor       string            `json:"label_color,omitempty"`
+	Fields           []WalletPassField `json:"fields,omitempty"`
+	BarcodeFormat    string            `json:"barcode_format"`            // qr, pdf417, aztec or code128
+	BarcodeMessage   string            `json:"barcode_message,omitempty"` // Defaults to the object's reference, or the contact ID
+	DateAttribute    string            `json:"date_attribute,omitempty"`  // Object attribute holding the date the pass is relevant at
+	VoidStatuses     []string          `json:"void_statuses,omitempty"`   // Object statuses that void the pass, e.g. cancelled
+	IsActive         bool              `json:"is_active"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+}
+
+// Validate checks the template's kind and linked object type
+func (t *WalletPassTemplate) Validate() error {
+	if strings.TrimSpace(t.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if strings.TrimSpace(t.OrganizationName) == "" {
+		return fmt.Errorf("organization name is required")
+	}
+	if strings.TrimSpace(t.Description) == "" {
+		return fmt.Errorf("description is required")
+	}
+
+	switch t.Kind {
+	case WalletPassKindBooking:
+		if !t.ObjectType.IsValid() {
+			return fmt.Errorf("booking passes require the type of linked object they confirm")
+		}
+	case WalletPassKindLoyalty:
+		if t.ObjectType != "" {
+			return fmt.Errorf("loyalty passes are not tied to linked objects")
+		}
+	default:
+		return fmt.Errorf("kind must be booking or loyalty")
+	}
+
+	seen := map[string]bool{}
+	for _, field := range t.Fields {
+		if field.Key == "" {
+			return fmt.Errorf("fields require a key")
+		}
+		if seen[field.Key] {
+			return fmt.Errorf("duplicate field key %q", field.Key)
+		}
+		seen[field.Key] = true
+		switch field.Position {
+		case "", "header", "primary", "secondary", "auxiliary", "back":
+		default:
+			return fmt.Errorf("invalid position %q of field %s", field.Position, field.Key)
+		}
+	}
+	return nil
+}
+
+// IsVoidStatus checks if a linked object status voids the template's passes
+func (t *WalletPassTemplate) IsVoidStatus(status string) bool {
+	for _, s := range t.VoidStatuses {
+		if strings.EqualFold(s, status) {
+			return true
+		}
+	}
+	return false
+}
+
+// WalletPass is a pass issued to a contact, for a linked object or as a loyalty card.
+// Its ID is the serial number of the pass in both wallets.
+type WalletPass struct {
+	ID             string            `json:"id"`
+	TenantID       string            `json:"tenant_id"`
+	TemplateID     string            `json:"template_id"`
+	LinkedObjectID string            `json:"linked_object_id,omitempty"`
+	ContactID      string            `json:"contact_id,omitempty"`
+	AuthToken      string            `json:"-"` // Authenticates downloads and the Apple web service
+	BarcodePayload string            `json:"barcode_payload"`
+	Fields         []WalletPassField `json:"fields,omitempty"` // Rendered fields
+	RelevantDate   *time.Time        `json:"relevant_date,omitempty"`
+	Voided         bool              `json:"voided"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"` // Last change of the pass content
+}
+
+// WalletPassRegistration is an Apple Wallet device that asked to be told when a pass changes
+type WalletPassRegistration struct {
+	PassID          string    `json:"pass_id"`
+	DeviceLibraryID string    `json:"device_library_id"`
+	PushToken       string    `json:"push_token"`
+	CreatedAt       time.Time `json:"created_at"`
+}
@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"time"
+)
+
+// WatchTargetType is the kind of record a user can watch
+type WatchTargetType string
+
+const (
+	WatchTargetConversation WatchTargetType = "conversation"
+	WatchTargetContact      WatchTargetType = "contact"
+)
+
+// IsValid checks if the watch target type is known
+func (t WatchTargetType) IsValid() bool {
+	switch t {
+	case WatchTargetConversation, WatchTargetContact:
+		return true
+	}
+	return false
+}
+
+// Watch subscribes a user to the activity of a conversation or of every
+// conversation of a contact without being assigned to it
+type Watch struct {
+	ID         string          `json:"id"`
+	TenantID   string          `json:"tenant_id"`
+	UserID     string          `json:"user_id"`
+	TargetType WatchTargetType `json:"target_type"`
+	TargetID   string          `json:"target_id"`
+	CreatedBy  string          `json:"created_by,omitempty"` // Supervisor that added the watch for someone else
+	CreatedAt  time.Time       `json:"created_at"`
+}
+
+// WatchActivityType is the kind of activity watchers are notified about
+type WatchActivityType string
+
+const (
+	WatchActivityMessageReceived WatchActivityType = "message_received"
+	WatchActivityAgentReplied    WatchActivityType = "agent_replied"
+	WatchActivityAssigned        WatchActivityType = "assigned"
+	WatchActivityResolved        WatchActivityType = "resolved"
+	WatchActivityReopened        WatchActivityType = "reopened"
+)
+
+// WatchActivity is the notification sent to the watchers of a conversation
+type WatchActivity struct {
+	Type           WatchActivityType `json:"type"`
+	ConversationID string            `json:"conversation_id"`
+	ContactID      string            `json:"contact_id"`
+	ActorID        string            `json:"actor_id,omitempty"`
+	AssignedUserID string            `json:"assigned_user_id,omitempty"`
+	MessageID      string            `json:"message_id,omitempty"`
+	Preview        string            `json:"preview,omitempty"`
+	Timestamp      time.Time         `json:"timestamp"`
+}
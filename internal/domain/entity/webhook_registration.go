@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+// WebhookRegistrationStatus is whether a provider delivers a channel's events to Linktor
+type WebhookRegistrationStatus string
+
+const (
+	WebhookRegistrationRegistered    WebhookRegistrationStatus = "registered"     // Provider delivers to the channel's webhook URL
+	WebhookRegistrationMismatch      WebhookRegistrationStatus = "mismatch"       // Provider delivers to another URL
+	WebhookRegistrationNotRegistered WebhookRegistrationStatus = "not_registered" // Provider has no webhook for the channel
+	WebhookRegistrationError         WebhookRegistrationStatus = "error"          // Provider could not be queried
+)
+
+// WebhookRegistration is the webhook of a channel as registered with its provider
+type WebhookRegistration struct {
+	ChannelID      string                    `json:"channel_id"`
+	ChannelType    ChannelType               `json:"channel_type"`
+	URL            string                    `json:"url"`                    // Where Linktor receives the channel's events
+	ProviderURL    string                    `json:"provider_url,omitempty"` // Where the provider delivers them, when it reports it
+	Status         WebhookRegistrationStatus `json:"status"`
+	PendingUpdates int                       `json:"pending_updates,omitempty"` // Updates waiting for delivery (Telegram)
+	LastError      string                    `json:"last_error,omitempty"`      // Latest delivery or lookup error
+	CheckedAt      time.Time                 `json:"checked_at"`
+}
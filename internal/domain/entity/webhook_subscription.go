@@ -0,0 +1,104 @@
+package entity
+
+import "time"
+
+// Event types tenants can subscribe webhooks to
+const (
+	WebhookEventMessageReceived            = "message.received"
+	WebhookEventConversationCreated        = "conversation.created"
+	WebhookEventConversationResolved       = "conversation.resolved"
+	WebhookEventConversationSummarized     = "conversation.summarized"
+	WebhookEventEscalationCreated          = "escalation.created"
+	WebhookEventContactCreated             = "contact.created"
+	WebhookEventVRERenderCompleted         = "vre.render.completed"
+	WebhookEventQuotaThreshold             = "quota.threshold_reached"
+	WebhookEventUploadCompleted            = "upload.completed"
+	WebhookEventUploadFailed               = "upload.failed"
+	WebhookEventCallTranscribed            = "call.transcribed"
+	WebhookEventConversationAutoUnassigned = "conversation.auto_unassigned"
+	WebhookEventAll                        = "*"
+)
+
+// WebhookEventTypes are the event types webhooks can subscribe to
+var WebhookEventTypes = []string{
+	WebhookEventMessageReceived,
+	WebhookEventConversationCreated,
+	WebhookEventConversationResolved,
+	WebhookEventConversationSummarized,
+	WebhookEventEscalationCreated,
+	WebhookEventContactCreated,
+	WebhookEventVRERenderCompleted,
+	WebhookEventQuotaThreshold,
+	WebhookEventUploadCompleted,
+	WebhookEventUploadFailed,
+	WebhookEventCallTranscribed,
+	WebhookEventConversationAutoUnassigned,
+}
+
+// IsValidWebhookEventType checks if webhooks can subscribe to an event type
+func IsValidWebhookEventType(eventType string) bool {
+	if eventType == WebhookEventAll {
+		return true
+	}
+	for _, t := range WebhookEventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookSubscription delivers the events a tenant subscribed to to an endpoint URL,
+// signed with the subscription secret
+type WebhookSubscription struct {
+	ID            string    `json:"id"`
+	TenantID      string    `json:"tenant_id"`
+	Name          string    `json:"name"`
+	URL           string    `json:"url"`
+	Events        []string  `json:"events"`
+	SchemaVersion string    `json:"schema_version"` // v1, v2 or latest
+	Secret        string    `json:"-"`
+	IsActive      bool      `json:"is_active"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Subscribes checks if the subscription receives an event type
+func (s *WebhookSubscription) Subscribes(eventType string) bool {
+	for _, e := range s.Events {
+		if e == WebhookEventAll || e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDeliveryStatus is the state of a webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"   // Queued or waiting for a retry
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded" // Endpoint answered 2xx
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"    // Out of attempts
+)
+
+// WebhookDelivery is the delivery of an event to a subscription, kept as the delivery
+// log. Data is the event payload, so a delivery can be retried and redelivered with
+// the same event ID.
+type WebhookDelivery struct {
+	ID             string                 `json:"id"`
+	TenantID       string                 `json:"tenant_id"`
+	SubscriptionID string                 `json:"subscription_id"`
+	EventID        string                 `json:"event_id"`
+	EventType      string                 `json:"event_type"`
+	Data           map[string]interface{} `json:"data"`
+	OccurredAt     time.Time              `json:"occurred_at"`
+	Status         WebhookDeliveryStatus  `json:"status"`
+	Attempts       int                    `json:"attempts"`
+	StatusCode     int                    `json:"status_code,omitempty"` // Of the last attempt
+	ResponseBody   string                 `json:"response_body,omitempty"`
+	Error          string                 `json:"error,omitempty"`
+	LastAttemptAt  *time.Time             `json:"last_attempt_at,omitempty"`
+	DeliveredAt    *time.Time             `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time              `json:"created_at"`
+}
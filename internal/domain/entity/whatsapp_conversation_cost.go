@@ -0,0 +1,65 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// WhatsAppPricingCategory represents the pricing category Meta assigns to a conversation
+type WhatsAppPricingCategory string
+
+const (
+	WhatsAppPricingAuthentication     WhatsAppPricingCategory = "authentication"
+	WhatsAppPricingMarketing          WhatsAppPricingCategory = "marketing"
+	WhatsAppPricingUtility            WhatsAppPricingCategory = "utility"
+	WhatsAppPricingService            WhatsAppPricingCategory = "service"
+	WhatsAppPricingReferralConversion WhatsAppPricingCategory = "referral_conversion"
+)
+
+// DefaultCostCurrency is the currency used when a channel does not configure one
+const DefaultCostCurrency = "USD"
+
+// defaultWhatsAppRates are reference rates (USD) used when a channel has no rate card configured
+var defaultWhatsAppRates = map[WhatsAppPricingCategory]float64{
+	WhatsAppPricingAuthentication:     0.0135,
+	WhatsAppPricingMarketing:          0.0250,
+	WhatsAppPricingUtility:            0.0040,
+	WhatsAppPricingService:            0,
+	WhatsAppPricingReferralConversion: 0,
+}
+
+// DefaultRate returns the reference rate of a pricing category
+func (c WhatsAppPricingCategory) DefaultRate() float64 {
+	return defaultWhatsAppRates[WhatsAppPricingCategory(strings.ToLower(string(c)))]
+}
+
+// WhatsAppConversationCost records the category and billability Meta reported for one
+// WhatsApp conversation window, together with the cost attributed to it.
+type WhatsAppConversationCost struct {
+	ID               string                  `json:"id"`
+	TenantID         string                  `json:"tenant_id"`
+	ChannelID        string                  `json:"channel_id"`
+	WAConversationID string                  `json:"wa_conversation_id"`
+	MessageID        string                  `json:"message_id,omitempty"`
+	CampaignID       string                  `json:"campaign_id,omitempty"`
+	Category         WhatsAppPricingCategory `json:"category"`
+	PricingModel     string                  `json:"pricing_model,omitempty"`
+	Origin           string                  `json:"origin,omitempty"`
+	Billable         bool                    `json:"billable"`
+	Cost             float64                 `json:"cost"`
+	Currency         string                  `json:"currency"`
+	ExpiresAt        *time.Time              `json:"expires_at,omitempty"`
+	CreatedAt        time.Time               `json:"created_at"`
+	UpdatedAt        time.Time               `json:"updated_at"`
+}
+
+// WhatsAppCostSummary aggregates conversation costs for a channel or campaign
+type WhatsAppCostSummary struct {
+	ChannelID     string           `json:"channel_id,omitempty"`
+	CampaignID    string           `json:"campaign_id,omitempty"`
+	Conversations int64            `json:"conversations"`
+	Billable      int64            `json:"billable"`
+	Cost          float64          `json:"cost"`
+	Currency      string           `json:"currency"`
+	ByCategory    map[string]int64 `json:"by_category"`
+}
@@ -0,0 +1,114 @@
+package entity
+
+import (
+	"strings"
+	"time"
+)
+
+// MessagingLimitTier represents the Meta business-initiated conversation tier of a phone number
+type MessagingLimitTier string
+
+const (
+	MessagingLimitTier50        MessagingLimitTier = "TIER_50"
+	MessagingLimitTier250       MessagingLimitTier = "TIER_250"
+	MessagingLimitTier1K        MessagingLimitTier = "TIER_1K"
+	MessagingLimitTier10K       MessagingLimitTier = "TIER_10K"
+	MessagingLimitTier100K      MessagingLimitTier = "TIER_100K"
+	MessagingLimitTierUnlimited MessagingLimitTier = "TIER_UNLIMITED"
+)
+
+// phoneNumberUsageWindow is the rolling window Meta applies to tier limits
+const phoneNumberUsageWindow = 24 * time.Hour
+
+// DailyLimit returns the number of unique business-initiated conversations allowed
+// per rolling 24 hours. Unknown tiers fall back to the most conservative limit.
+func (t MessagingLimitTier) DailyLimit() int {
+	switch MessagingLimitTier(strings.ToUpper(string(t))) {
+	case MessagingLimitTier50:
+		return 50
+	case MessagingLimitTier250:
+		return 250
+	case MessagingLimitTier1K:
+		return 1000
+	case MessagingLimitTier10K:
+		return 10000
+	case MessagingLimitTier100K:
+		return 100000
+	case MessagingLimitTierUnlimited:
+		return -1
+	default:
+		return 250
+	}
+}
+
+// WhatsAppPhoneNumber represents one phone number of a WABA managed by a WhatsApp Official channel.
+// A channel may own several numbers; inbound webhooks are routed by PhoneNumberID and
+// outbound template sends are balanced across the numbers using their tier limits.
+type WhatsAppPhoneNumber struct {
+	ID                 string             `json:"id"`
+	TenantID           string             `json:"tenant_id"`
+	ChannelID          string             `json:"channel_id"`
+	PhoneNumberID      string             `json:"phone_number_id"`
+	DisplayPhoneNumber string             `json:"display_phone_number,omitempty"`
+	VerifiedName       string             `json:"verified_name,omitempty"`
+	QualityRating      string             `json:"quality_rating,omitempty"`
+	MessagingLimitTier MessagingLimitTier `json:"messaging_limit_tier,omitempty"`
+	IsDefault          bool               `json:"is_default"`
+	Enabled            bool               `json:"enabled"`
+	SentInWindow       int                `json:"sent_in_window"`
+	WindowStartedAt    *time.Time         `json:"window_started_at,omitempty"`
+	CreatedAt          time.Time          `json:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
+}
+
+// NewWhatsAppPhoneNumber creates a new phone number for a channel
+func NewWhatsAppPhoneNumber(tenantID, channelID, phoneNumberID string) *WhatsAppPhoneNumber {
+	now := time.Now()
+	return &WhatsAppPhoneNumber{
+		TenantID:           tenantID,
+		ChannelID:          channelID,
+		PhoneNumberID:      phoneNumberID,
+		QualityRating:      "GREEN",
+		MessagingLimitTier: MessagingLimitTier250,
+		Enabled:            true,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+}
+
+// IsFlagged returns true if Meta currently rates the number as low quality
+func (n *WhatsAppPhoneNumber) IsFlagged() bool {
+	return strings.EqualFold(n.QualityRating, "RED") || strings.EqualFold(n.QualityRating, "FLAGGED")
+}
+
+// SentSince returns the number of sends counted in the current usage window
+func (n *WhatsAppPhoneNumber) SentSince(now time.Time) int {
+	if n.WindowStartedAt == nil || now.Sub(*n.WindowStartedAt) >= phoneNumberUsageWindow {
+		return 0
+	}
+	return n.SentInWindow
+}
+
+// RemainingCapacity returns how many more sends fit in the current window.
+// Returns -1 for unlimited numbers.
+func (n *WhatsAppPhoneNumber) RemainingCapacity(now time.Time) int {
+	limit := n.MessagingLimitTier.DailyLimit()
+	if limit < 0 {
+		return -1
+	}
+	remaining := limit - n.SentSince(now)
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// RecordSend counts one send in the usage window, starting a new window when the previous one expired
+func (n *WhatsAppPhoneNumber) RecordSend(now time.Time) {
+	if n.WindowStartedAt == nil || now.Sub(*n.WindowStartedAt) >= phoneNumberUsageWindow {
+		n.WindowStartedAt = &now
+		n.SentInWindow = 0
+	}
+	n.SentInWindow++
+	n.UpdatedAt = now
+}
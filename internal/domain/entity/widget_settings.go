@@ -0,0 +1,64 @@
+package entity
+
+import "time"
+
+// MetadataQuickReplyID is the message metadata naming the widget quick reply a visitor clicked
+const MetadataQuickReplyID = "quick_reply_id"
+
+// WidgetQuickReply is a chip shown in webchat widgets that sends its message when clicked
+type WidgetQuickReply struct {
+	ID      string `json:"id"`
+	Label   string `json:"label"`
+	Message string `json:"message"` // Sent as the visitor's message
+	Emoji   string `json:"emoji,omitempty"`
+}
+
+// WidgetEmoji is an emoji offered by the widget's picker. Custom emoji are images.
+type WidgetEmoji struct {
+	Shortcode string `json:"shortcode"` // Without colons, e.g. "party_parrot"
+	Unicode   string `json:"unicode,omitempty"`
+	ImageURL  string `json:"image_url,omitempty"`
+}
+
+// WidgetEmojiSet is a named tab of the widget's emoji picker
+type WidgetEmojiSet struct {
+	Name   string        `json:"name"`
+	Emojis []WidgetEmoji `json:"emojis"`
+}
+
+// WidgetSettings is how a tenant personalizes its webchat widgets
+type WidgetSettings struct {
+	TenantID     string             `json:"tenant_id"`
+	QuickReplies []WidgetQuickReply `json:"quick_replies"`
+	EmojiSets    []WidgetEmojiSet   `json:"emoji_sets"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+}
+
+// QuickReply finds a quick reply by ID
+func (s *WidgetSettings) QuickReply(id string) (*WidgetQuickReply, bool) {
+	for i := range s.QuickReplies {
+		if s.QuickReplies[i].ID == id {
+			return &s.QuickReplies[i], true
+		}
+	}
+	return nil, false
+}
+
+// QuickReplyClick records a visitor clicking a quick reply in a widget
+type QuickReplyClick struct {
+	ID             string    `json:"id"`
+	TenantID       string    `json:"tenant_id"`
+	ChannelID      string    `json:"channel_id"`
+	ConversationID string    `json:"conversation_id"`
+	QuickReplyID   string    `json:"quick_reply_id"`
+	Label          string    `json:"label"` // As shown when clicked
+	ClickedAt      time.Time `json:"clicked_at"`
+}
+
+// QuickReplyStats is how often a quick reply was clicked in a period
+type QuickReplyStats struct {
+	QuickReplyID  string `json:"quick_reply_id"`
+	Label         string `json:"label"`
+	Clicks        int64  `json:"clicks"`
+	Conversations int64  `json:"conversations"` // Distinct conversations it was clicked in
+}
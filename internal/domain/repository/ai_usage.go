@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// AIUsageRepository defines persistence for metered AI calls and their monthly aggregates
+type AIUsageRepository interface {
+	// SaveRecords stores AI calls and adds them to the monthly aggregates
+	SaveRecords(ctx context.Context, records []*entity.AIUsageRecord) error
+
+	// FindRecords lists a tenant's AI calls in [since, until), newest first
+	FindRecords(ctx context.Context, tenantID string, since, until time.Time, limit int) ([]*entity.AIUsageRecord, error)
+
+	// FindAggregates returns a tenant's usage per bot, feature and model in a month
+	FindAggregates(ctx context.Context, tenantID string, month time.Time) ([]*entity.AIUsageAggregate, error)
+
+	// SumMonths returns a tenant's usage per month from the month of since, oldest first
+	SumMonths(ctx context.Context, tenantID string, since time.Time) ([]entity.AIUsageMonth, error)
+
+	// SumCost returns a tenant's AI cost in a month
+	SumCost(ctx context.Context, tenantID string, month time.Time) (float64, error)
+
+	// DeleteRecordsBefore removes AI calls made before a time; aggregates are kept
+	DeleteRecordsBefore(ctx context.Context, before time.Time) (int64, error)
+
+	// CreateBudgetAlert records a sent budget alert. It returns false when the alert
+	// was already recorded for the month.
+	CreateBudgetAlert(ctx context.Context, alert *entity.AIBudgetAlert) (bool, error)
+}
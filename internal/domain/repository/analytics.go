@@ -22,4 +22,19 @@ type AnalyticsRepository interface {
 
 	// GetChannelAnalytics returns metrics grouped by channel
 	GetChannelAnalytics(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.ChannelAnalytics, error)
+
+	// GetLabelAnalytics returns conversation metrics grouped by label
+	GetLabelAnalytics(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.LabelAnalytics, error)
+
+	// GetDispositionAnalytics returns conversation metrics grouped by disposition
+	GetDispositionAnalytics(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.DispositionAnalytics, error)
+
+	// GetReopenAnalytics returns the reopen rate and follow-ups of resolved conversations
+	GetReopenAnalytics(ctx context.Context, filter entity.AnalyticsFilter) (*entity.ReopenAnalytics, error)
+
+	// GetLanguageAnalytics returns conversation metrics grouped by conversation language
+	GetLanguageAnalytics(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.LanguageAnalytics, error)
+
+	// GetTemplateDeliveryStats returns delivery, read and reply counts per template of a channel
+	GetTemplateDeliveryStats(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.TemplateDeliveryStats, error)
 }
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/msgfy/linktor/internal/domain/entity"
 )
@@ -10,5 +11,9 @@ import (
 type APIKeyRepository interface {
 	Create(ctx context.Context, apiKey *entity.APIKey) error
 	ListByTenant(ctx context.Context, tenantID string) ([]*entity.APIKey, error)
+	// FindByPrefix returns the keys of any tenant starting with a prefix, with their hashes.
+	FindByPrefix(ctx context.Context, prefix string) ([]*entity.APIKey, error)
+	Revoke(ctx context.Context, tenantID, id string, revokedAt time.Time) (*entity.APIKey, error)
+	TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error
 	Delete(ctx context.Context, tenantID, id string) error
 }
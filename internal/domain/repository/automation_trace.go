@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// AutomationTraceRepository defines persistence for automation decision snapshots
+type AutomationTraceRepository interface {
+	// Create stores a trace
+	Create(ctx context.Context, trace *entity.AutomationTrace) error
+
+	// FindByConversation lists the traces of a conversation, newest first
+	FindByConversation(ctx context.Context, conversationID string, filter *entity.AutomationTraceFilter, params *ListParams) ([]*entity.AutomationTrace, int64, error)
+}
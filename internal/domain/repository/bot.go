@@ -137,10 +137,12 @@ type KnowledgeItemRepository interface {
 	// CountByKnowledgeBase counts items in a knowledge base
 	CountByKnowledgeBase(ctx context.Context, kbID string) (int64, error)
 
-	// SearchByKeywords searches items by keywords
+	// SearchByKeywords searches the published items by keywords, leaving out the
+	// override items tenants add to global knowledge bases
 	SearchByKeywords(ctx context.Context, kbID string, keywords []string, limit int) ([]*entity.KnowledgeItem, error)
 
-	// SearchByEmbedding searches items by vector similarity (RAG)
+	// SearchByEmbedding searches the published items by vector similarity (RAG),
+	// leaving out the override items tenants add to global knowledge bases
 	SearchByEmbedding(ctx context.Context, kbID string, embedding []float64, limit int, minScore float64) ([]*entity.SearchResult, error)
 
 	// DeleteByKnowledgeBase deletes all items in a knowledge base
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// BotExperimentRepository defines persistence for bot versions, experiments and the
+// outcomes of the conversations assigned to them
+type BotExperimentRepository interface {
+	// CreateVersion stores a version with the next version number of the bot, which is set on it
+	CreateVersion(ctx context.Context, version *entity.BotVersion) error
+
+	// FindVersionByID finds a version by ID
+	FindVersionByID(ctx context.Context, id string) (*entity.BotVersion, error)
+
+	// ListVersions lists the versions of a bot, newest first
+	ListVersions(ctx context.Context, botID string) ([]*entity.BotVersion, error)
+
+	// CreateExperiment creates an experiment
+	CreateExperiment(ctx context.Context, experiment *entity.BotExperiment) error
+
+	// UpdateExperiment updates an experiment
+	UpdateExperiment(ctx context.Context, experiment *entity.BotExperiment) error
+
+	// FindExperimentByID finds an experiment by ID
+	FindExperimentByID(ctx context.Context, id string) (*entity.BotExperiment, error)
+
+	// FindRunningExperiment finds the running experiment of a bot
+	FindRunningExperiment(ctx context.Context, botID string) (*entity.BotExperiment, error)
+
+	// ListExperiments lists the experiments of a bot, newest first
+	ListExperiments(ctx context.Context, botID string) ([]*entity.BotExperiment, error)
+
+	// FindAssignment finds the assignment of a conversation in an experiment
+	FindAssignment(ctx context.Context, experimentID, conversationID string) (*entity.BotExperimentAssignment, error)
+
+	// CreateAssignment assigns a conversation unless it already is; the stored assignment is returned
+	CreateAssignment(ctx context.Context, assignment *entity.BotExperimentAssignment) (*entity.BotExperimentAssignment, error)
+
+	// MarkEscalated flags the assignments of a conversation as escalated
+	MarkEscalated(ctx context.Context, conversationID string) error
+
+	// MarkResolved flags the assignments of a conversation as resolved
+	MarkResolved(ctx context.Context, conversationID string) error
+
+	// SetCSAT stores the satisfaction score of a conversation on the conversation and
+	// its assignments
+	SetCSAT(ctx context.Context, tenantID, conversationID string, score int) error
+
+	// VariantOutcomes aggregates the outcomes of the assignments of an experiment per version
+	VariantOutcomes(ctx context.Context, experimentID string) ([]*entity.BotExperimentVariantReport, error)
+}
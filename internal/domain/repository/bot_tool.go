@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// BotToolRepository defines persistence for the tools of bots and the audit log of their calls
+type BotToolRepository interface {
+	// Create creates a tool
+	Create(ctx context.Context, tool *entity.BotTool) error
+
+	// Update updates a tool
+	Update(ctx context.Context, tool *entity.BotTool) error
+
+	// Delete deletes a tool
+	Delete(ctx context.Context, id string) error
+
+	// FindByID finds a tool by ID
+	FindByID(ctx context.Context, id string) (*entity.BotTool, error)
+
+	// ListByBot lists the tools of a bot by name
+	ListByBot(ctx context.Context, botID string) ([]*entity.BotTool, error)
+
+	// CreateExecution records a tool call
+	CreateExecution(ctx context.Context, execution *entity.ToolExecution) error
+
+	// ListExecutions lists the tool calls of a bot, newest first
+	ListExecutions(ctx context.Context, botID string, params *ListParams) ([]*entity.ToolExecution, int64, error)
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// CallTranscriptRepository defines persistence for call transcripts
+type CallTranscriptRepository interface {
+	// Create creates a call transcript
+	Create(ctx context.Context, transcript *entity.CallTranscript) error
+
+	// FindByID finds a call transcript by ID
+	FindByID(ctx context.Context, id string) (*entity.CallTranscript, error)
+
+	// Search lists the transcripts of a tenant matching a filter, best matches first
+	// when the filter has a query and newest first otherwise
+	Search(ctx context.Context, tenantID string, filter *entity.CallTranscriptFilter, params *ListParams) ([]*entity.CallTranscript, int64, error)
+}
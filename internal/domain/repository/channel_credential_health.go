@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// ChannelCredentialHealthRepository defines persistence for the credential checks of channels
+type ChannelCredentialHealthRepository interface {
+	// Upsert stores the latest check of a channel, replacing the previous one
+	Upsert(ctx context.Context, health *entity.ChannelCredentialHealth) error
+
+	// FindByChannel finds the latest check of a channel
+	FindByChannel(ctx context.Context, channelID string) (*entity.ChannelCredentialHealth, error)
+
+	// FindByTenant lists the latest checks of the channels of a tenant
+	FindByTenant(ctx context.Context, tenantID string) ([]*entity.ChannelCredentialHealth, error)
+}
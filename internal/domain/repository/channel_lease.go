@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// ChannelLeaseRepository stores channel session ownership and its audit trail
+type ChannelLeaseRepository interface {
+	// Acquire claims the channel for ownerID, or renews the lease if ownerID already
+	// holds it, when it is free or expired. It returns the lease and the owner that
+	// held it before, or nil when another instance holds a valid lease.
+	Acquire(ctx context.Context, tenantID, channelID, ownerID string, ttl time.Duration) (lease *entity.ChannelLease, previousOwnerID string, err error)
+
+	// Release gives the channel up if ownerID holds it
+	Release(ctx context.Context, channelID, ownerID string) error
+
+	// FindByChannel returns the channel's lease
+	FindByChannel(ctx context.Context, channelID string) (*entity.ChannelLease, error)
+
+	// CreateEvent records an ownership change
+	CreateEvent(ctx context.Context, event *entity.ChannelFailoverEvent) error
+
+	// ListEvents lists the channel's ownership changes, newest first
+	ListEvents(ctx context.Context, channelID string, limit int) ([]*entity.ChannelFailoverEvent, error)
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// ChannelMaintenanceRepository defines persistence for channel maintenance windows
+type ChannelMaintenanceRepository interface {
+	// Create creates a new maintenance window
+	Create(ctx context.Context, window *entity.ChannelMaintenanceWindow) error
+
+	// FindByID finds a maintenance window by ID
+	FindByID(ctx context.Context, id string) (*entity.ChannelMaintenanceWindow, error)
+
+	// ListByChannel lists the windows of a channel ending after since, by start time
+	ListByChannel(ctx context.Context, channelID string, since time.Time) ([]*entity.ChannelMaintenanceWindow, error)
+
+	// ListOverlapping lists the windows of a channel overlapping the period from..to
+	ListOverlapping(ctx context.Context, channelID string, from, to time.Time) ([]*entity.ChannelMaintenanceWindow, error)
+
+	// Update updates a maintenance window
+	Update(ctx context.Context, window *entity.ChannelMaintenanceWindow) error
+
+	// Delete deletes a maintenance window
+	Delete(ctx context.Context, id string) error
+}
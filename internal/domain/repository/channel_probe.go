@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// ChannelProbeRepository defines persistence for channel capability probes
+type ChannelProbeRepository interface {
+	// Upsert stores the probe of a channel, replacing the previous one
+	Upsert(ctx context.Context, probe *entity.ChannelProbe) error
+
+	// FindByChannel finds the latest probe of a channel
+	FindByChannel(ctx context.Context, channelID string) (*entity.ChannelProbe, error)
+}
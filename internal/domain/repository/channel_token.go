@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// ChannelTokenRepository defines persistence for the access token lifecycle of channels
+type ChannelTokenRepository interface {
+	// Upsert stores the token state of a channel, replacing the previous one
+	Upsert(ctx context.Context, token *entity.ChannelToken) error
+
+	// FindByChannel finds the token state of a channel
+	FindByChannel(ctx context.Context, channelID string) (*entity.ChannelToken, error)
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// ComplianceRepository defines persistence for tenant compliance rules and the log
+// of compliance overrides
+type ComplianceRepository interface {
+	// CreateRule stores a tenant rule
+	CreateRule(ctx context.Context, rule *entity.ComplianceRule) error
+
+	// UpdateRule updates a tenant rule
+	UpdateRule(ctx context.Context, rule *entity.ComplianceRule) error
+
+	// DeleteRule deletes a tenant rule
+	DeleteRule(ctx context.Context, id string) error
+
+	// FindRuleByID finds a tenant rule
+	FindRuleByID(ctx context.Context, id string) (*entity.ComplianceRule, error)
+
+	// ListRules returns the rules of a tenant, oldest first
+	ListRules(ctx context.Context, tenantID string) ([]*entity.ComplianceRule, error)
+
+	// CreateOverride records an override
+	CreateOverride(ctx context.Context, override *entity.ComplianceOverride) error
+
+	// ListOverrides returns the overrides of a tenant, newest first
+	ListOverrides(ctx context.Context, tenantID string, params *ListParams) ([]*entity.ComplianceOverride, int64, error)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// ContactFieldRepository defines the interface for custom contact field persistence
+type ContactFieldRepository interface {
+	Create(ctx context.Context, field *entity.ContactField) error
+	FindByID(ctx context.Context, id string) (*entity.ContactField, error)
+	// FindByTenant lists the fields of a tenant ordered by key
+	FindByTenant(ctx context.Context, tenantID string) ([]*entity.ContactField, error)
+	Update(ctx context.Context, field *entity.ContactField) error
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// ContactMergeRepository merges duplicate contacts atomically, keeps their audit
+// records and finds contacts sharing a normalized phone or email
+type ContactMergeRepository interface {
+	// Merge saves the target contact, moves the source contact's identities,
+	// conversations, messages and other references to it, deletes the source and
+	// records the merge, filling the moved IDs and message count
+	Merge(ctx context.Context, merge *entity.ContactMerge, target *entity.Contact) error
+
+	// FindMatches finds the contacts of a tenant whose normalized phone or email equals
+	// the given ones, oldest first. Empty values are not matched.
+	FindMatches(ctx context.Context, tenantID, phone, email string) ([]*entity.Contact, error)
+
+	// ListByContact lists the merges into a contact, newest first
+	ListByContact(ctx context.Context, contactID string) ([]*entity.ContactMerge, error)
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// ContactSegmentRepository defines the interface for contact segment persistence
+type ContactSegmentRepository interface {
+	Create(ctx context.Context, segment *entity.ContactSegment) error
+	FindByID(ctx context.Context, id string) (*entity.ContactSegment, error)
+	FindByTenant(ctx context.Context, tenantID string) ([]*entity.ContactSegment, error)
+	Update(ctx context.Context, segment *entity.ContactSegment) error
+	Delete(ctx context.Context, id string) error
+}
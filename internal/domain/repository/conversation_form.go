@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// ConversationFormRepository defines persistence for conversation forms and their submissions
+type ConversationFormRepository interface {
+	// CreateForm creates a new form
+	CreateForm(ctx context.Context, form *entity.ConversationForm) error
+
+	// FindFormByID finds a form by ID
+	FindFormByID(ctx context.Context, id string) (*entity.ConversationForm, error)
+
+	// ListForms lists the forms of a tenant
+	ListForms(ctx context.Context, tenantID string) ([]*entity.ConversationForm, error)
+
+	// UpdateForm updates a form
+	UpdateForm(ctx context.Context, form *entity.ConversationForm) error
+
+	// DeleteForm deletes a form and its submissions
+	DeleteForm(ctx context.Context, id string) error
+
+	// CreateSubmission creates a new submission
+	CreateSubmission(ctx context.Context, submission *entity.FormSubmission) error
+
+	// FindSubmissionByID finds a submission by ID
+	FindSubmissionByID(ctx context.Context, id string) (*entity.FormSubmission, error)
+
+	// ListSubmissions lists the submissions of a tenant, newest first
+	ListSubmissions(ctx context.Context, tenantID string, filter *entity.FormSubmissionFilter, params *ListParams) ([]*entity.FormSubmission, int64, error)
+
+	// UpdateSubmission updates a submission
+	UpdateSubmission(ctx context.Context, submission *entity.FormSubmission) error
+
+	// DeleteSubmission deletes a submission
+	DeleteSubmission(ctx context.Context, id string) error
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// ConversationOperationRepository applies conversation merges and splits atomically
+// and keeps their audit records
+type ConversationOperationRepository interface {
+	// Merge moves every message of the source conversation into the target, closes the
+	// source and records the operation, filling op.MessageIDs with the moved messages
+	Merge(ctx context.Context, op *entity.ConversationOperation) error
+
+	// Split creates the new conversation, moves op.MessageIDs into it from the source
+	// and records the operation
+	Split(ctx context.Context, op *entity.ConversationOperation, conversation *entity.Conversation) error
+
+	// ListByConversation lists the operations involving a conversation, newest first
+	ListByConversation(ctx context.Context, conversationID string) ([]*entity.ConversationOperation, error)
+}
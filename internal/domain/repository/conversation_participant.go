@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// ConversationParticipantRepository defines persistence for the participants of group
+// conversations and the delivery status of group messages per participant
+type ConversationParticipantRepository interface {
+	// Upsert adds a participant to a conversation, reactivating them when they were removed
+	Upsert(ctx context.Context, participant *entity.ConversationParticipant) error
+
+	// FindByConversation lists the participants of a conversation, removed ones included
+	FindByConversation(ctx context.Context, conversationID string) ([]*entity.ConversationParticipant, error)
+
+	// FindByIdentifier finds a participant by their address on the channel
+	FindByIdentifier(ctx context.Context, conversationID, identifier string) (*entity.ConversationParticipant, error)
+
+	// Remove marks a participant as removed from a conversation
+	Remove(ctx context.Context, conversationID, contactID string, at time.Time) error
+
+	// FindRecipient finds the delivery status of a message for a participant
+	FindRecipient(ctx context.Context, messageID, identifier string) (*entity.MessageRecipient, error)
+
+	// SaveRecipient creates or updates the delivery status of a message for a participant
+	SaveRecipient(ctx context.Context, recipient *entity.MessageRecipient) error
+
+	// FindRecipients lists the delivery status of a message per participant
+	FindRecipients(ctx context.Context, messageID string) ([]*entity.MessageRecipient, error)
+}
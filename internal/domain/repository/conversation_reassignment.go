@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// ConversationReassignmentRepository keeps the audit records of conversations taken
+// from unavailable agents
+type ConversationReassignmentRepository interface {
+	// Create records a reassignment
+	Create(ctx context.Context, reassignment *entity.ConversationReassignment) error
+
+	// ListByTenant lists a tenant's reassignments, newest first. params.Filters may hold
+	// "user_id" (string), matching reassignments from or to the user.
+	ListByTenant(ctx context.Context, tenantID string, params *ListParams) ([]*entity.ConversationReassignment, int64, error)
+
+	// ListByConversation lists the reassignments of a conversation, newest first
+	ListByConversation(ctx context.Context, conversationID string) ([]*entity.ConversationReassignment, error)
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// ConversationReopenRepository defines persistence for the reopens of resolved conversations
+type ConversationReopenRepository interface {
+	// Create records a reopen
+	Create(ctx context.Context, reopen *entity.ConversationReopen) error
+
+	// FindByConversation lists the reopens of a conversation, oldest first
+	FindByConversation(ctx context.Context, conversationID string) ([]*entity.ConversationReopen, error)
+}
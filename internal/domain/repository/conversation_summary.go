@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// ConversationSummaryRepository defines persistence for conversation summaries
+type ConversationSummaryRepository interface {
+	// Upsert stores the summary of a conversation, replacing the previous one
+	Upsert(ctx context.Context, summary *entity.ConversationSummary) error
+
+	// FindByConversation finds the summary of a conversation
+	FindByConversation(ctx context.Context, conversationID string) (*entity.ConversationSummary, error)
+
+	// UpdateEmbedding stores the embedding of a summary's issue
+	UpdateEmbedding(ctx context.Context, conversationID string, embedding []float64) error
+
+	// ListResolvedWithEmbedding lists the embedded summaries of a tenant's resolved
+	// conversations, newest first
+	ListResolvedWithEmbedding(ctx context.Context, tenantID string, limit int) ([]*entity.ConversationSummary, error)
+}
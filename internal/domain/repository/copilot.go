@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// CopilotRepository defines persistence for the suggestions the agent copilot makes
+// and what agents did with them
+type CopilotRepository interface {
+	// Create stores a suggestion
+	Create(ctx context.Context, suggestion *entity.CopilotSuggestion) error
+
+	// FindByID finds a suggestion by ID
+	FindByID(ctx context.Context, id string) (*entity.CopilotSuggestion, error)
+
+	// ListByConversation lists the suggestions made in a conversation, newest first
+	ListByConversation(ctx context.Context, conversationID string, limit int) ([]*entity.CopilotSuggestion, error)
+
+	// Update records what the agent did with a suggestion
+	Update(ctx context.Context, suggestion *entity.CopilotSuggestion) error
+
+	// Stats counts the suggestions of a tenant made in a period and their outcomes by type
+	Stats(ctx context.Context, tenantID string, startDate, endDate time.Time) ([]*entity.CopilotStats, error)
+}
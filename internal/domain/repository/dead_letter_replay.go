@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// DeadLetterReplayRepository defines persistence for the audit of dead letter replays
+type DeadLetterReplayRepository interface {
+	// Create records a replay
+	Create(ctx context.Context, replay *entity.DeadLetterReplay) error
+
+	// ListByStream lists the latest replays of a stream, newest first
+	ListByStream(ctx context.Context, stream string, limit int) ([]*entity.DeadLetterReplay, error)
+}
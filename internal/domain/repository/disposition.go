@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// DispositionRepository defines persistence for the disposition taxonomy of tenants
+// and the dispositions given to resolved conversations
+type DispositionRepository interface {
+	Create(ctx context.Context, disposition *entity.Disposition) error
+	FindByID(ctx context.Context, id string) (*entity.Disposition, error)
+	FindByCode(ctx context.Context, tenantID, code string) (*entity.Disposition, error)
+
+	// FindByTenant lists the dispositions of a tenant by position and name
+	FindByTenant(ctx context.Context, tenantID string) ([]*entity.Disposition, error)
+
+	Update(ctx context.Context, disposition *entity.Disposition) error
+	Delete(ctx context.Context, id string) error
+
+	// IsUsed reports whether any conversation was resolved with the disposition
+	IsUsed(ctx context.Context, id string) (bool, error)
+
+	// SaveConversationDisposition stores the disposition of a conversation, replacing
+	// the one of an earlier resolution
+	SaveConversationDisposition(ctx context.Context, disposition *entity.ConversationDisposition) error
+
+	// FindConversationDisposition finds the disposition of a conversation
+	FindConversationDisposition(ctx context.Context, conversationID string) (*entity.ConversationDisposition, error)
+}
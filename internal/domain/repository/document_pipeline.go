@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// DocumentPipelineRepository defines persistence for document parsing pipelines and their results
+type DocumentPipelineRepository interface {
+	// Create creates a new pipeline
+	Create(ctx context.Context, pipeline *entity.DocumentPipeline) error
+
+	// FindByID finds a pipeline by ID
+	FindByID(ctx context.Context, id string) (*entity.DocumentPipeline, error)
+
+	// ListByTenant lists the pipelines of a tenant ordered by priority
+	ListByTenant(ctx context.Context, tenantID string) ([]*entity.DocumentPipeline, error)
+
+	// ListActive lists the active pipelines of a tenant ordered by priority
+	ListActive(ctx context.Context, tenantID string) ([]*entity.DocumentPipeline, error)
+
+	// Update updates a pipeline
+	Update(ctx context.Context, pipeline *entity.DocumentPipeline) error
+
+	// Delete deletes a pipeline
+	Delete(ctx context.Context, id string) error
+
+	// CreateExtraction records the result of parsing an attachment
+	CreateExtraction(ctx context.Context, extraction *entity.DocumentExtraction) error
+
+	// ListExtractionsByConversation lists the documents parsed on a conversation, newest first
+	ListExtractionsByConversation(ctx context.Context, conversationID string) ([]*entity.DocumentExtraction, error)
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// EmbeddingCacheRepository defines persistence for embeddings keyed by the hash of
+// the content they were generated from
+type EmbeddingCacheRepository interface {
+	// FindMany returns the cached embeddings of the given hashes; misses are left out
+	FindMany(ctx context.Context, hashes []string) (map[string][]float64, error)
+
+	// SaveMany caches embeddings generated with a model, keeping existing entries
+	SaveMany(ctx context.Context, model string, embeddings map[string][]float64) error
+
+	// DeleteOlderThan removes entries cached before the given time
+	DeleteOlderThan(ctx context.Context, before time.Time) (int64, error)
+}
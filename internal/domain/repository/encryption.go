@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// EncryptionKeyRepository defines persistence for tenant encryption keys
+type EncryptionKeyRepository interface {
+	// Create creates a new key
+	Create(ctx context.Context, key *entity.TenantEncryptionKey) error
+
+	// FindByID finds a key by ID
+	FindByID(ctx context.Context, id string) (*entity.TenantEncryptionKey, error)
+
+	// FindActive finds the active key of a tenant
+	FindActive(ctx context.Context, tenantID string) (*entity.TenantEncryptionKey, error)
+
+	// ListByTenant lists the keys of a tenant, newest version first
+	ListByTenant(ctx context.Context, tenantID string) ([]*entity.TenantEncryptionKey, error)
+
+	// Update updates the wrapped key material and status of a key
+	Update(ctx context.Context, key *entity.TenantEncryptionKey) error
+}
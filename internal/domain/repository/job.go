@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// JobRepository defines persistence for background jobs
+type JobRepository interface {
+	// Create creates a new job
+	Create(ctx context.Context, job *entity.Job) error
+
+	// FindByID finds a job by ID
+	FindByID(ctx context.Context, id string) (*entity.Job, error)
+
+	// ListByTenant lists the jobs of a tenant, newest first
+	ListByTenant(ctx context.Context, tenantID string, filter *entity.JobFilter, params *ListParams) ([]*entity.Job, int64, error)
+
+	// ListStale lists pending and running jobs not updated since before
+	ListStale(ctx context.Context, before time.Time, limit int) ([]*entity.Job, error)
+
+	// Update updates a job
+	Update(ctx context.Context, job *entity.Job) error
+
+	// UpdateProgress records the progress of a running job
+	UpdateProgress(ctx context.Context, id string, progress int, message string) error
+}
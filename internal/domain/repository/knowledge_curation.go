@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// KnowledgeCurationRepository defines persistence for Q&A pairs mined from
+// conversations and the conversations already mined
+type KnowledgeCurationRepository interface {
+	// CreateCandidate stores a mined Q&A pair
+	CreateCandidate(ctx context.Context, candidate *entity.KnowledgeCandidate) error
+
+	// FindCandidateByID finds a candidate by ID
+	FindCandidateByID(ctx context.Context, id string) (*entity.KnowledgeCandidate, error)
+
+	// ListCandidates lists the candidates of a tenant, newest first
+	ListCandidates(ctx context.Context, tenantID string, filter *entity.KnowledgeCandidateFilter, params *ListParams) ([]*entity.KnowledgeCandidate, int64, error)
+
+	// UpdateCandidate updates a candidate
+	UpdateCandidate(ctx context.Context, candidate *entity.KnowledgeCandidate) error
+
+	// MarkConversationMined records that a conversation was mined and how many pairs it gave
+	MarkConversationMined(ctx context.Context, tenantID, conversationID string, candidates int) error
+
+	// IsConversationMined reports whether a conversation was already mined
+	IsConversationMined(ctx context.Context, conversationID string) (bool, error)
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// KnowledgeFeedbackRepository defines persistence for answer feedback and the knowledge gap queue
+type KnowledgeFeedbackRepository interface {
+	// SaveFeedback stores the rating of an answer, replacing an earlier rating of the same answer
+	SaveFeedback(ctx context.Context, feedback *entity.KnowledgeFeedback) error
+
+	// ItemQuality counts helpful and unhelpful ratings per knowledge item of a knowledge base
+	ItemQuality(ctx context.Context, tenantID, knowledgeBaseID string) ([]*entity.KnowledgeItemQuality, error)
+
+	// RecordGap queues a gap, or raises the occurrences of the open gap with the same question
+	RecordGap(ctx context.Context, gap *entity.KnowledgeGap) error
+
+	// FindGapByID finds a knowledge gap by ID
+	FindGapByID(ctx context.Context, id string) (*entity.KnowledgeGap, error)
+
+	// ListGaps lists the knowledge gaps of a tenant, most frequent first
+	ListGaps(ctx context.Context, tenantID string, filter *entity.KnowledgeGapFilter, params *ListParams) ([]*entity.KnowledgeGap, int64, error)
+
+	// UpdateGap updates the review state of a knowledge gap
+	UpdateGap(ctx context.Context, gap *entity.KnowledgeGap) error
+}
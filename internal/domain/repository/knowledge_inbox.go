@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// KnowledgeInboxRepository defines persistence for knowledge inboxes and their submissions
+type KnowledgeInboxRepository interface {
+	// Create creates a new knowledge inbox
+	Create(ctx context.Context, inbox *entity.KnowledgeInbox) error
+
+	// FindByID finds a knowledge inbox by ID
+	FindByID(ctx context.Context, id string) (*entity.KnowledgeInbox, error)
+
+	// ListByTenant lists the knowledge inboxes of a tenant
+	ListByTenant(ctx context.Context, tenantID string) ([]*entity.KnowledgeInbox, error)
+
+	// Update updates a knowledge inbox
+	Update(ctx context.Context, inbox *entity.KnowledgeInbox) error
+
+	// Delete deletes a knowledge inbox
+	Delete(ctx context.Context, id string) error
+
+	// CreateSubmission stores content received by an inbox
+	CreateSubmission(ctx context.Context, submission *entity.KnowledgeSubmission) error
+
+	// FindSubmissionByID finds a submission by ID
+	FindSubmissionByID(ctx context.Context, id string) (*entity.KnowledgeSubmission, error)
+
+	// ListSubmissions lists the submissions of a tenant, newest first
+	ListSubmissions(ctx context.Context, tenantID string, filter *entity.KnowledgeSubmissionFilter, params *ListParams) ([]*entity.KnowledgeSubmission, int64, error)
+
+	// UpdateSubmission updates a submission
+	UpdateSubmission(ctx context.Context, submission *entity.KnowledgeSubmission) error
+}
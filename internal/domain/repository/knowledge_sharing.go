@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// KnowledgeSharingRepository defines persistence for global knowledge bases: the
+// tenants they are attached to and the override items tenants add to them
+type KnowledgeSharingRepository interface {
+	// FindGlobal lists the global knowledge bases
+	FindGlobal(ctx context.Context, params *ListParams) ([]*entity.KnowledgeBase, int64, error)
+
+	// FindAttached lists the global knowledge bases attached to a tenant
+	FindAttached(ctx context.Context, tenantID string) ([]*entity.KnowledgeBase, error)
+
+	// Attach attaches a global knowledge base to a tenant; attaching it again is a no-op
+	Attach(ctx context.Context, attachment *entity.KnowledgeBaseAttachment) error
+
+	// Detach detaches a global knowledge base from a tenant
+	Detach(ctx context.Context, knowledgeBaseID, tenantID string) error
+
+	// IsAttached reports whether a global knowledge base is attached to a tenant
+	IsAttached(ctx context.Context, knowledgeBaseID, tenantID string) (bool, error)
+
+	// ListAttachments lists the tenants a global knowledge base is attached to
+	ListAttachments(ctx context.Context, knowledgeBaseID string) ([]*entity.KnowledgeBaseAttachment, error)
+
+	// FindItems lists the items of a global knowledge base as a tenant sees them: the
+	// shared items and the tenant's overrides, never other tenants' overrides
+	FindItems(ctx context.Context, knowledgeBaseID, tenantID string, params *ListParams) ([]*entity.KnowledgeItem, int64, error)
+
+	// FindOverrides lists a tenant's override items in a global knowledge base
+	FindOverrides(ctx context.Context, knowledgeBaseID, tenantID string) ([]*entity.KnowledgeItem, error)
+}
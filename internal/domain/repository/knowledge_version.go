@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// KnowledgeVersionRepository defines persistence for the version history and
+// audit trail of knowledge items
+type KnowledgeVersionRepository interface {
+	// CreateVersion stores a version of an item
+	CreateVersion(ctx context.Context, version *entity.KnowledgeItemVersion) error
+
+	// FindVersion finds a version of an item by number
+	FindVersion(ctx context.Context, itemID string, version int) (*entity.KnowledgeItemVersion, error)
+
+	// ListVersions lists the versions of an item, newest first
+	ListVersions(ctx context.Context, itemID string) ([]*entity.KnowledgeItemVersion, error)
+
+	// MarkVersionPublished records who published a version and when
+	MarkVersionPublished(ctx context.Context, version *entity.KnowledgeItemVersion) error
+
+	// CreateAuditEvent records a change in the audit trail
+	CreateAuditEvent(ctx context.Context, event *entity.KnowledgeAuditEvent) error
+
+	// ListAuditEvents lists the audit trail of a knowledge base, newest first
+	ListAuditEvents(ctx context.Context, kbID string, filter *entity.KnowledgeAuditFilter, params *ListParams) ([]*entity.KnowledgeAuditEvent, int64, error)
+}
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// LabelRepository defines the interface for label persistence
+type LabelRepository interface {
+	Create(ctx context.Context, label *entity.Label) error
+	FindByID(ctx context.Context, id string) (*entity.Label, error)
+	FindByName(ctx context.Context, tenantID, name string) (*entity.Label, error)
+	FindByTenant(ctx context.Context, tenantID string) ([]*entity.Label, error)
+	Update(ctx context.Context, label *entity.Label) error
+	Delete(ctx context.Context, id string) error
+
+	// FindByConversation lists the labels attached to a conversation
+	FindByConversation(ctx context.Context, conversationID string) ([]*entity.Label, error)
+
+	// Attach attaches a label to a conversation; attaching it twice is a no-op
+	Attach(ctx context.Context, conversationID, labelID, userID string) error
+
+	// Detach removes a label from a conversation
+	Detach(ctx context.Context, conversationID, labelID string) error
+}
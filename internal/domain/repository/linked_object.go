@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// LinkedObjectRepository defines persistence for linked objects
+type LinkedObjectRepository interface {
+	// Create creates a new linked object
+	Create(ctx context.Context, object *entity.LinkedObject) error
+
+	// FindByID finds a linked object by ID
+	FindByID(ctx context.Context, id string) (*entity.LinkedObject, error)
+
+	// FindByExternalID finds the object a connector synced with an external ID
+	FindByExternalID(ctx context.Context, connectorID, externalID string) (*entity.LinkedObject, error)
+
+	// ListByTenant lists the linked objects of a tenant, most recently updated first
+	ListByTenant(ctx context.Context, tenantID string, filter *entity.LinkedObjectFilter, params *ListParams) ([]*entity.LinkedObject, int64, error)
+
+	// ListByConversation lists the objects linked to a conversation, most recently updated first
+	ListByConversation(ctx context.Context, conversationID string) ([]*entity.LinkedObject, error)
+
+	// Update updates a linked object
+	Update(ctx context.Context, object *entity.LinkedObject) error
+
+	// Delete deletes a linked object
+	Delete(ctx context.Context, id string) error
+}
+
+// LinkedObjectConnectorRepository defines persistence for linked object connectors
+type LinkedObjectConnectorRepository interface {
+	// Create creates a new connector
+	Create(ctx context.Context, connector *entity.LinkedObjectConnector) error
+
+	// FindByID finds a connector by ID
+	FindByID(ctx context.Context, id string) (*entity.LinkedObjectConnector, error)
+
+	// ListByTenant lists the connectors of a tenant
+	ListByTenant(ctx context.Context, tenantID string) ([]*entity.LinkedObjectConnector, error)
+
+	// Update updates a connector, including its secret and last sync time
+	Update(ctx context.Context, connector *entity.LinkedObjectConnector) error
+
+	// Delete deletes a connector
+	Delete(ctx context.Context, id string) error
+}
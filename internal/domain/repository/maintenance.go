@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// MaintenanceRepository defines persistence for the maintenance mode and the webhooks
+// buffered while it is on
+type MaintenanceRepository interface {
+	// GetState returns the maintenance state, off when it was never set
+	GetState(ctx context.Context) (*entity.MaintenanceState, error)
+
+	// SaveState stores the maintenance state
+	SaveState(ctx context.Context, state *entity.MaintenanceState) error
+
+	// BufferWebhook stores a webhook request, assigning its ID
+	BufferWebhook(ctx context.Context, webhook *entity.BufferedWebhook) error
+
+	// ListBuffered lists the oldest buffered webhooks, in the order they were received
+	ListBuffered(ctx context.Context, limit int) ([]*entity.BufferedWebhook, error)
+
+	// DeleteBuffered removes a replayed webhook
+	DeleteBuffered(ctx context.Context, id int64) error
+
+	// CountBuffered counts the webhooks waiting to be replayed
+	CountBuffered(ctx context.Context) (int64, error)
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/msgfy/linktor/internal/domain/entity"
 )
@@ -20,6 +21,13 @@ type MessageRepository interface {
 	// FindByConversation finds messages for a conversation with pagination
 	FindByConversation(ctx context.Context, conversationID string, params *ListParams) ([]*entity.Message, int64, error)
 
+	// FindByConversationAfter finds the messages of a conversation created after a time, oldest first
+	FindByConversationAfter(ctx context.Context, conversationID string, after time.Time, limit int) ([]*entity.Message, error)
+
+	// FindRecentOutboundByContact finds the messages sent to a contact across its conversations
+	// since a time, newest first. Failed messages are left out.
+	FindRecentOutboundByContact(ctx context.Context, contactID string, since time.Time, limit int) ([]*entity.Message, error)
+
 	// Update updates a message
 	Update(ctx context.Context, message *entity.Message) error
 
@@ -29,6 +37,13 @@ type MessageRepository interface {
 	// Delete deletes a message
 	Delete(ctx context.Context, id string) error
 
+	// FindExpiredEphemeral finds disappearing messages that expired before a time and
+	// were not handled yet, oldest first
+	FindExpiredEphemeral(ctx context.Context, before time.Time, limit int) ([]*entity.Message, error)
+
+	// Redact clears the content and attachments of a message and stores its metadata
+	Redact(ctx context.Context, id string, metadata map[string]string) error
+
 	// CountByConversation counts messages in a conversation
 	CountByConversation(ctx context.Context, conversationID string) (int64, error)
 
@@ -65,18 +80,39 @@ type ConversationRepository interface {
 	// FindByAssignee finds conversations assigned to a user
 	FindByAssignee(ctx context.Context, assigneeID string, params *ListParams) ([]*entity.Conversation, int64, error)
 
-	// FindOpenByContactAndChannel finds open conversation for a contact on a channel
+	// FindOpenByContactAndChannel finds open conversation for a contact on a channel,
+	// leaving out group conversations
 	FindOpenByContactAndChannel(ctx context.Context, contactID, channelID string) (*entity.Conversation, error)
 
+	// FindByGroup finds the latest conversation of a group on a channel. It returns nil
+	// when there is none.
+	FindByGroup(ctx context.Context, channelID, groupID string) (*entity.Conversation, error)
+
+	// FindLastResolvedByContactAndChannel finds the conversation of a contact on a channel
+	// resolved most recently since the given time. It returns nil when there is none.
+	FindLastResolvedByContactAndChannel(ctx context.Context, contactID, channelID string, since time.Time) (*entity.Conversation, error)
+
+	// FindFollowUps finds the conversations that follow up on a resolved conversation
+	FindFollowUps(ctx context.Context, conversationID string) ([]*entity.Conversation, error)
+
 	// Update updates a conversation
 	Update(ctx context.Context, conversation *entity.Conversation) error
 
+	// SetMetadata sets metadata keys of a conversation, leaving its other keys as they are
+	SetMetadata(ctx context.Context, id string, values map[string]string) error
+
 	// UpdateStatus updates only the conversation status
 	UpdateStatus(ctx context.Context, id string, status entity.ConversationStatus) error
 
 	// UpdateAssignee updates the conversation assignee
 	UpdateAssignee(ctx context.Context, id string, assigneeID *string) error
 
+	// UpdateBotPause pauses bot automation on behalf of a user, or resumes it when pausedBy is nil
+	UpdateBotPause(ctx context.Context, id string, pausedBy *string) error
+
+	// UpdateTitle sets the title of a conversation and who set it
+	UpdateTitle(ctx context.Context, id, title string, source entity.ConversationTitleSource, messageCount int) error
+
 	// IncrementUnreadCount increments the unread message count
 	IncrementUnreadCount(ctx context.Context, id string) error
 
@@ -97,6 +133,10 @@ type ConversationRepository interface {
 
 	// CountWaiting counts waiting conversations with given or higher priority
 	CountWaiting(ctx context.Context, tenantID string, minPriority entity.ConversationPriority) (int64, error)
+
+	// FindAwaitingFirstReply finds assigned open or pending conversations no agent has
+	// replied to yet, across tenants, oldest first
+	FindAwaitingFirstReply(ctx context.Context, limit int) ([]*entity.Conversation, error)
 }
 
 // ContactRepository defines the interface for contact persistence
@@ -107,7 +147,9 @@ type ContactRepository interface {
 	// FindByID finds a contact by ID
 	FindByID(ctx context.Context, id string) (*entity.Contact, error)
 
-	// FindByTenant finds contacts for a tenant with pagination
+	// FindByTenant finds contacts for a tenant with pagination. params.Filters may
+	// hold "search" (string) and "custom_fields" (map[string]string of values the
+	// contact's custom fields must equal).
 	FindByTenant(ctx context.Context, tenantID string, params *ListParams) ([]*entity.Contact, int64, error)
 
 	// FindByEmail finds a contact by email within a tenant
@@ -136,6 +178,15 @@ type ContactRepository interface {
 
 	// FindIdentitiesByContact finds all identities for a contact
 	FindIdentitiesByContact(ctx context.Context, contactID string) ([]*entity.ContactIdentity, error)
+
+	// FindIdentityByUsername finds a tenant's channel identity by the username stored
+	// in its metadata, compared case-insensitively
+	FindIdentityByUsername(ctx context.Context, tenantID, channelType, username string) (*entity.ContactIdentity, error)
+
+	// FindBySegment finds the contacts of a tenant matching a segment. params.Filters
+	// may restrict the result further with "contact_id" (string) and "rules"
+	// ([]entity.SegmentRule, all of which must also match).
+	FindBySegment(ctx context.Context, tenantID string, segment *entity.ContactSegment, params *ListParams) ([]*entity.Contact, int64, error)
 }
 
 // ChannelRepository defines the interface for channel persistence
@@ -181,5 +232,9 @@ type ChannelRepository interface {
 
 	// FindCoexistenceChannels finds all channels with coexistence enabled
 	FindCoexistenceChannels(ctx context.Context) ([]*entity.Channel, error)
+
+	// FindBySecretRef finds the channels with credentials pointing into a secret,
+	// given as secret://<backend>/<path>
+	FindBySecretRef(ctx context.Context, secretRef string) ([]*entity.Channel, error)
 }
 
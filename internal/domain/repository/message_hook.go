@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// MessageHookRepository defines persistence for scripted message hooks
+type MessageHookRepository interface {
+	// Create creates a new hook
+	Create(ctx context.Context, hook *entity.MessageHook) error
+
+	// FindByID finds a hook by ID
+	FindByID(ctx context.Context, id string) (*entity.MessageHook, error)
+
+	// ListByTenant lists the hooks of a tenant ordered by priority
+	ListByTenant(ctx context.Context, tenantID string) ([]*entity.MessageHook, error)
+
+	// ListActive lists the active hooks of a tenant for an event ordered by priority
+	ListActive(ctx context.Context, tenantID string, event entity.HookEvent) ([]*entity.MessageHook, error)
+
+	// Update updates a hook
+	Update(ctx context.Context, hook *entity.MessageHook) error
+
+	// Delete deletes a hook
+	Delete(ctx context.Context, id string) error
+
+	// RecordRun records the time and error (empty on success) of a hook run
+	RecordRun(ctx context.Context, id string, runAt time.Time, lastError string) error
+}
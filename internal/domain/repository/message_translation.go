@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// MessageTranslationRepository defines persistence for cached message translations
+type MessageTranslationRepository interface {
+	// FindByMessages finds the translations of messages into a language
+	FindByMessages(ctx context.Context, messageIDs []string, language string) ([]*entity.MessageTranslation, error)
+
+	// Save stores the translation of a message, replacing a previous one in the same language
+	Save(ctx context.Context, translation *entity.MessageTranslation) error
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// UserMFARepository defines persistence for the TOTP second factor of users
+type UserMFARepository interface {
+	// FindByUser finds the second factor of a user, not found when they never set one up
+	FindByUser(ctx context.Context, userID string) (*entity.UserMFA, error)
+
+	// Save creates or replaces the second factor of a user
+	Save(ctx context.Context, mfa *entity.UserMFA) error
+
+	// Delete removes the second factor of a user
+	Delete(ctx context.Context, userID string) error
+}
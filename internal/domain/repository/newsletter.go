@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// NewsletterRepository defines persistence for newsletters, their subscribers and editions
+type NewsletterRepository interface {
+	// Create creates a new newsletter
+	Create(ctx context.Context, newsletter *entity.Newsletter) error
+
+	// FindByID finds a newsletter by ID
+	FindByID(ctx context.Context, id string) (*entity.Newsletter, error)
+
+	// ListByTenant lists the newsletters of a tenant
+	ListByTenant(ctx context.Context, tenantID string) ([]*entity.Newsletter, error)
+
+	// ListByChannel lists the newsletters sent on a channel
+	ListByChannel(ctx context.Context, channelID string) ([]*entity.Newsletter, error)
+
+	// ListDue lists the active newsletters whose next run is at or before the given time
+	ListDue(ctx context.Context, now time.Time) ([]*entity.Newsletter, error)
+
+	// Update updates a newsletter
+	Update(ctx context.Context, newsletter *entity.Newsletter) error
+
+	// Delete deletes a newsletter with its subscriptions and editions
+	Delete(ctx context.Context, id string) error
+
+	// UpsertSubscription creates or replaces the subscription of a contact to a newsletter
+	UpsertSubscription(ctx context.Context, subscription *entity.NewsletterSubscription) error
+
+	// FindSubscription finds the subscription of a contact to a newsletter
+	FindSubscription(ctx context.Context, newsletterID, contactID string) (*entity.NewsletterSubscription, error)
+
+	// ListSubscriptions lists the subscriptions of a newsletter, optionally by status
+	ListSubscriptions(ctx context.Context, newsletterID string, status entity.NewsletterSubscriptionStatus, params *ListParams) ([]*entity.NewsletterSubscription, int64, error)
+
+	// ListSubscribedContactIDs returns the contacts currently subscribed to a newsletter
+	ListSubscribedContactIDs(ctx context.Context, newsletterID string) ([]string, error)
+
+	// CreateEdition creates a new edition
+	CreateEdition(ctx context.Context, edition *entity.NewsletterEdition) error
+
+	// UpdateEdition updates the status and counters of an edition
+	UpdateEdition(ctx context.Context, edition *entity.NewsletterEdition) error
+
+	// FindEditionByID finds an edition by ID
+	FindEditionByID(ctx context.Context, id string) (*entity.NewsletterEdition, error)
+
+	// ListEditions lists the editions of a newsletter, newest first
+	ListEditions(ctx context.Context, newsletterID string, params *ListParams) ([]*entity.NewsletterEdition, int64, error)
+
+	// CreateDelivery records the send of an edition to a contact
+	CreateDelivery(ctx context.Context, delivery *entity.NewsletterDelivery) error
+
+	// UpdateDelivery updates the outcome of a delivery
+	UpdateDelivery(ctx context.Context, delivery *entity.NewsletterDelivery) error
+
+	// ListDueDeliveries lists the scheduled deliveries whose send time is at or before the given time
+	ListDueDeliveries(ctx context.Context, now time.Time) ([]*entity.NewsletterDelivery, error)
+
+	// CountRecentDeliveries counts the newsletter messages sent to a contact since the given time
+	CountRecentDeliveries(ctx context.Context, tenantID, contactID string, since time.Time) (int, error)
+
+	// CountRecentMarketingMessages counts the marketing messages sent to a contact on any
+	// channel since the given time: campaign sends and marketing templates
+	CountRecentMarketingMessages(ctx context.Context, tenantID, contactID string, since time.Time) (int, error)
+
+	// FindLastEngagement returns when a contact last read or replied to a message, nil when never
+	FindLastEngagement(ctx context.Context, contactID string) (*time.Time, error)
+
+	// MarkReplied marks the latest delivery on a conversation sent since the given time as replied
+	MarkReplied(ctx context.Context, conversationID string, since, at time.Time) error
+
+	// MarkUnsubscribed marks the latest delivery of a newsletter to a contact as the one that caused an unsubscribe
+	MarkUnsubscribed(ctx context.Context, newsletterID, contactID string, at time.Time) error
+
+	// GetEditionStats aggregates the engagement of an edition, optionally of the
+	// deliveries with the given send timing only
+	GetEditionStats(ctx context.Context, editionID string, timing entity.NewsletterSendTiming) (*entity.NewsletterEditionStats, error)
+
+	// GetRenderStats sums the personalized image rendering of a newsletter's editions
+	GetRenderStats(ctx context.Context, newsletterID string) (*entity.NewsletterRenderSummary, error)
+
+	// GetContactEngagement counts the reads and replies of a contact since the given time by hour of the day
+	GetContactEngagement(ctx context.Context, contactID string, since time.Time) (*entity.ContactEngagement, error)
+}
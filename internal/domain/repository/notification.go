@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// NotificationRepository defines persistence for the notification center of tenants
+type NotificationRepository interface {
+	// Raise creates a notification. One with a key replaces the content of the open
+	// notification with the same key, if any, which becomes unread again.
+	Raise(ctx context.Context, notification *entity.Notification) error
+
+	// Resolve resolves the open notification of a tenant with the key, if any
+	Resolve(ctx context.Context, tenantID, key string, at time.Time) error
+
+	// FindByTenant lists the notifications of a tenant, newest first
+	FindByTenant(ctx context.Context, tenantID string, filter *entity.NotificationFilter, params *ListParams) ([]*entity.Notification, int64, error)
+
+	// CountUnread counts the unread, unresolved notifications of a tenant
+	CountUnread(ctx context.Context, tenantID string) (int64, error)
+
+	// MarkRead marks a notification of a tenant as read
+	MarkRead(ctx context.Context, tenantID, id string, at time.Time) error
+
+	// MarkAllRead marks every notification of a tenant as read, returning how many were unread
+	MarkAllRead(ctx context.Context, tenantID string, at time.Time) (int64, error)
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// OnboardingRepository defines persistence for tenant onboarding progress
+type OnboardingRepository interface {
+	// FindByTenant finds the onboarding progress of a tenant
+	FindByTenant(ctx context.Context, tenantID string) (*entity.OnboardingProgress, error)
+
+	// Save creates or updates the onboarding progress of a tenant
+	Save(ctx context.Context, progress *entity.OnboardingProgress) error
+}
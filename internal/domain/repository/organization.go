@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// OrganizationRepository defines persistence for organizations, their workspaces
+// and members
+type OrganizationRepository interface {
+	// Create creates an organization with a first workspace and its owner
+	Create(ctx context.Context, org *entity.Organization, tenantID string, owner *entity.OrganizationMember) error
+
+	// Update updates an organization
+	Update(ctx context.Context, org *entity.Organization) error
+
+	// FindByID finds an organization by ID
+	FindByID(ctx context.Context, id string) (*entity.Organization, error)
+
+	// FindBySlug finds an organization by slug
+	FindBySlug(ctx context.Context, slug string) (*entity.Organization, error)
+
+	// FindByTenant finds the organization a tenant is a workspace of
+	FindByTenant(ctx context.Context, tenantID string) (*entity.Organization, error)
+
+	// AddWorkspace adds a tenant to an organization
+	AddWorkspace(ctx context.Context, organizationID, tenantID string) error
+
+	// ListWorkspaces lists the workspaces of an organization, oldest first
+	ListWorkspaces(ctx context.Context, organizationID string) ([]*entity.OrganizationWorkspace, error)
+
+	// SaveMember creates or updates a member
+	SaveMember(ctx context.Context, member *entity.OrganizationMember) error
+
+	// FindMember finds a member by email
+	FindMember(ctx context.Context, organizationID, email string) (*entity.OrganizationMember, error)
+
+	// ListMembers lists the members of an organization
+	ListMembers(ctx context.Context, organizationID string) ([]*entity.OrganizationMember, error)
+
+	// DeleteMember removes a member
+	DeleteMember(ctx context.Context, organizationID, email string) error
+
+	// ListAccounts lists the users with an email in the workspaces of an organization
+	ListAccounts(ctx context.Context, organizationID, email string) ([]*entity.User, error)
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// PostbackRepository defines persistence for interactive reply postbacks
+type PostbackRepository interface {
+	// Create stores a new postback
+	Create(ctx context.Context, postback *entity.Postback) error
+
+	// FindByID finds a postback by ID
+	FindByID(ctx context.Context, id string) (*entity.Postback, error)
+
+	// MarkUsed records that a postback was used. It returns false when a
+	// single-use postback had already been used.
+	MarkUsed(ctx context.Context, id string, usedAt time.Time) (bool, error)
+
+	// DeleteExpired removes postbacks that expired before the given time
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+
+	// RecordVote stores a vote, replacing the contact's previous vote in the poll
+	RecordVote(ctx context.Context, vote *entity.PostbackVote) error
+
+	// CountVotes returns the votes of a poll grouped by option
+	CountVotes(ctx context.Context, tenantID, pollID string) (*entity.PollResult, error)
+}
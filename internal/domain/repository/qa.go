@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// QARepository defines persistence for quality review scorecards, sampling rules and reviews
+type QARepository interface {
+	// CreateScorecard creates a new scorecard
+	CreateScorecard(ctx context.Context, scorecard *entity.QAScorecard) error
+
+	// FindScorecardByID finds a scorecard by ID
+	FindScorecardByID(ctx context.Context, id string) (*entity.QAScorecard, error)
+
+	// ListScorecards lists the scorecards of a tenant
+	ListScorecards(ctx context.Context, tenantID string) ([]*entity.QAScorecard, error)
+
+	// UpdateScorecard updates a scorecard
+	UpdateScorecard(ctx context.Context, scorecard *entity.QAScorecard) error
+
+	// DeleteScorecard deletes a scorecard
+	DeleteScorecard(ctx context.Context, id string) error
+
+	// CreateRule creates a new sampling rule
+	CreateRule(ctx context.Context, rule *entity.QASamplingRule) error
+
+	// FindRuleByID finds a sampling rule by ID
+	FindRuleByID(ctx context.Context, id string) (*entity.QASamplingRule, error)
+
+	// ListRules lists the sampling rules of a tenant
+	ListRules(ctx context.Context, tenantID string) ([]*entity.QASamplingRule, error)
+
+	// ListActiveRules lists the active sampling rules of all tenants
+	ListActiveRules(ctx context.Context) ([]*entity.QASamplingRule, error)
+
+	// UpdateRule updates a sampling rule
+	UpdateRule(ctx context.Context, rule *entity.QASamplingRule) error
+
+	// DeleteRule deletes a sampling rule
+	DeleteRule(ctx context.Context, id string) error
+
+	// FindSampleCandidates returns conversations resolved since the given time that match
+	// the rule, have an assigned agent and were not reviewed yet
+	FindSampleCandidates(ctx context.Context, rule *entity.QASamplingRule, since time.Time) ([]entity.QASampleCandidate, error)
+
+	// CreateReview creates a new review
+	CreateReview(ctx context.Context, review *entity.QAReview) error
+
+	// FindReviewByID finds a review by ID
+	FindReviewByID(ctx context.Context, id string) (*entity.QAReview, error)
+
+	// ListReviews lists the reviews of a tenant
+	ListReviews(ctx context.Context, tenantID string, filter *entity.QAReviewFilter, params *ListParams) ([]*entity.QAReview, int64, error)
+
+	// UpdateReview updates a review
+	UpdateReview(ctx context.Context, review *entity.QAReview) error
+
+	// SummarizeByAgent aggregates completed reviews per agent in a period
+	SummarizeByAgent(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.AgentQualitySummary, error)
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// QuotaRepository defines persistence for metered tenant usage
+type QuotaRepository interface {
+	// AddUsage adds to the daily usage counters, creating missing ones
+	AddUsage(ctx context.Context, usage []entity.QuotaUsage) error
+
+	// SumUsage sums a tenant's usage per metric for days in [since, until)
+	SumUsage(ctx context.Context, tenantID string, since, until time.Time) (map[entity.QuotaMetric]int64, error)
+
+	// ListRouteUsage returns a tenant's API calls per route for days in [since, until),
+	// busiest routes first
+	ListRouteUsage(ctx context.Context, tenantID string, since, until time.Time, limit int) ([]entity.RouteUsage, error)
+
+	// StorageBytes returns the size of a tenant's stored media, in total and added since a time
+	StorageBytes(ctx context.Context, tenantID string, since time.Time) (total, added int64, err error)
+
+	// CreateAlert records a sent threshold alert. It returns false when the alert was
+	// already recorded for the period.
+	CreateAlert(ctx context.Context, alert *entity.QuotaAlert) (bool, error)
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// RoutingRepository defines persistence for routing rules and agent routing profiles
+type RoutingRepository interface {
+	// CreateRule creates a new routing rule
+	CreateRule(ctx context.Context, rule *entity.RoutingRule) error
+
+	// FindRuleByID finds a routing rule by ID
+	FindRuleByID(ctx context.Context, id string) (*entity.RoutingRule, error)
+
+	// FindRulesByTenant lists the routing rules of a tenant by position
+	FindRulesByTenant(ctx context.Context, tenantID string) ([]*entity.RoutingRule, error)
+
+	// UpdateRule updates a routing rule
+	UpdateRule(ctx context.Context, rule *entity.RoutingRule) error
+
+	// DeleteRule deletes a routing rule
+	DeleteRule(ctx context.Context, id string) error
+
+	// UpdateLastAssigned moves the round-robin cursor of a rule
+	UpdateLastAssigned(ctx context.Context, ruleID, userID string) error
+
+	// FindProfiles lists the routing profiles of a tenant's agents
+	FindProfiles(ctx context.Context, tenantID string) ([]*entity.AgentRoutingProfile, error)
+
+	// SaveProfile creates or replaces the routing profile of an agent
+	SaveProfile(ctx context.Context, profile *entity.AgentRoutingProfile) error
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// BookingCalendarRepository defines persistence for booking calendars
+type BookingCalendarRepository interface {
+	// Create creates a new booking calendar
+	Create(ctx context.Context, calendar *entity.BookingCalendar) error
+
+	// FindByID finds a booking calendar by ID
+	FindByID(ctx context.Context, id string) (*entity.BookingCalendar, error)
+
+	// ListByTenant lists the booking calendars of a tenant
+	ListByTenant(ctx context.Context, tenantID string) ([]*entity.BookingCalendar, error)
+
+	// Update updates a booking calendar, including its credentials
+	Update(ctx context.Context, calendar *entity.BookingCalendar) error
+
+	// Delete deletes a booking calendar
+	Delete(ctx context.Context, id string) error
+}
+
+// BookingRepository defines persistence for bookings
+type BookingRepository interface {
+	// Create creates a new booking
+	Create(ctx context.Context, booking *entity.Booking) error
+
+	// FindByID finds a booking by ID
+	FindByID(ctx context.Context, id string) (*entity.Booking, error)
+
+	// FindByToken finds a booking by the token of its links
+	FindByToken(ctx context.Context, token string) (*entity.Booking, error)
+
+	// FindPendingByConversation finds the latest booking of a conversation still
+	// waiting for the contact to pick a slot
+	FindPendingByConversation(ctx context.Context, conversationID string) (*entity.Booking, error)
+
+	// ListByTenant lists the bookings of a tenant, soonest first
+	ListByTenant(ctx context.Context, tenantID string, filter *entity.BookingFilter, params *ListParams) ([]*entity.Booking, int64, error)
+
+	// ListConfirmedBetween lists the confirmed bookings of a calendar overlapping from..to
+	ListConfirmedBetween(ctx context.Context, calendarID string, from, to time.Time) ([]*entity.Booking, error)
+
+	// ListDueReminders lists confirmed bookings whose next reminder is due at before
+	ListDueReminders(ctx context.Context, before time.Time, limit int) ([]*entity.Booking, error)
+
+	// Update updates a booking
+	Update(ctx context.Context, booking *entity.Booking) error
+}
@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// SSOConfigRepository defines persistence for the identity providers of tenants
+type SSOConfigRepository interface {
+	// FindByTenant finds the identity provider of a tenant, not found when none is configured
+	FindByTenant(ctx context.Context, tenantID string) (*entity.SSOConfig, error)
+
+	// Save creates or replaces the identity provider of a tenant
+	Save(ctx context.Context, config *entity.SSOConfig) error
+
+	// Delete removes the identity provider of a tenant
+	Delete(ctx context.Context, tenantID string) error
+}
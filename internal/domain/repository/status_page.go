@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// StatusPageRepository defines persistence for tenant status pages and incidents
+type StatusPageRepository interface {
+	// FindByTenant finds the status page of a tenant
+	FindByTenant(ctx context.Context, tenantID string) (*entity.StatusPage, error)
+
+	// FindBySlug finds a status page by its public slug
+	FindBySlug(ctx context.Context, slug string) (*entity.StatusPage, error)
+
+	// Save creates or updates the status page of a tenant
+	Save(ctx context.Context, page *entity.StatusPage) error
+
+	// ListAutoIncidentPages lists the published pages that open incidents from channel health
+	ListAutoIncidentPages(ctx context.Context) ([]*entity.StatusPage, error)
+
+	// CreateIncident creates a new incident
+	CreateIncident(ctx context.Context, incident *entity.Incident) error
+
+	// FindIncidentByID finds an incident by ID
+	FindIncidentByID(ctx context.Context, id string) (*entity.Incident, error)
+
+	// ListIncidents lists the incidents of a tenant, most recent first
+	ListIncidents(ctx context.Context, tenantID string, filter *entity.IncidentFilter) ([]*entity.Incident, error)
+
+	// UpdateIncident updates an incident
+	UpdateIncident(ctx context.Context, incident *entity.Incident) error
+
+	// DeleteIncident deletes an incident
+	DeleteIncident(ctx context.Context, id string) error
+}
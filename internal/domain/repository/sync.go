@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// SyncRepository reads the records of a tenant that changed after a position, in
+// change order, for delta sync
+type SyncRepository interface {
+	// ConversationsChangedSince returns up to limit conversations changed after since
+	// and no later than until
+	ConversationsChangedSince(ctx context.Context, tenantID string, since entity.SyncPosition, until time.Time, limit int) ([]*entity.SyncConversation, error)
+
+	// MessagesChangedSince returns up to limit messages created or changed after since
+	// and no later than until
+	MessagesChangedSince(ctx context.Context, tenantID string, since entity.SyncPosition, until time.Time, limit int) ([]*entity.SyncMessage, error)
+}
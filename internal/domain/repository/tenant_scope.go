@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// TenantScope finds tenant-owned records by ID on behalf of one tenant. Every lookup
+// requires the tenant ID, and records of other tenants fail exactly like missing ones,
+// so IDs can't be probed across tenants.
+type TenantScope[T any] struct {
+	find     func(ctx context.Context, id string) (*T, error)
+	tenantOf func(record *T) string
+	notFound func() error
+}
+
+// NewTenantScope wraps the ID lookup of a repository; notFound builds the error of
+// both missing and out-of-tenant records
+func NewTenantScope[T any](find func(ctx context.Context, id string) (*T, error), tenantOf func(record *T) string, notFound func() error) *TenantScope[T] {
+	return &TenantScope[T]{find: find, tenantOf: tenantOf, notFound: notFound}
+}
+
+// FindByID finds a record of a tenant by ID. Lookup failures other than a missing
+// record are returned as they are, so outages don't turn into 404s.
+func (s *TenantScope[T]) FindByID(ctx context.Context, tenantID, id string) (*T, error) {
+	if tenantID == "" || id == "" {
+		return nil, s.notFound()
+	}
+	record, err := s.find(ctx, id)
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, err
+	}
+	if err != nil || record == nil || s.tenantOf(record) != tenantID {
+		return nil, s.notFound()
+	}
+	return record, nil
+}
+
+// TenantConversations scopes conversation lookups to a tenant
+func TenantConversations(repo ConversationRepository, notFound func() error) *TenantScope[entity.Conversation] {
+	return NewTenantScope(repo.FindByID, func(conversation *entity.Conversation) string { return conversation.TenantID }, notFound)
+}
+
+// TenantContacts scopes contact lookups to a tenant
+func TenantContacts(repo ContactRepository, notFound func() error) *TenantScope[entity.Contact] {
+	return NewTenantScope(repo.FindByID, func(contact *entity.Contact) string { return contact.TenantID }, notFound)
+}
+
+// TenantChannels scopes channel lookups to a tenant
+func TenantChannels(repo ChannelRepository, notFound func() error) *TenantScope[entity.Channel] {
+	return NewTenantScope(repo.FindByID, func(channel *entity.Channel) string { return channel.TenantID }, notFound)
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newContactScope(find func(ctx context.Context, id string) (*entity.Contact, error)) *TenantScope[entity.Contact] {
+	return NewTenantScope(find, func(contact *entity.Contact) string { return contact.TenantID }, func() error {
+		return errors.New(errors.ErrCodeContactNotFound, "contact not found")
+	})
+}
+
+func TestTenantScope_FindByID(t *testing.T) {
+	contacts := map[string]*entity.Contact{"contact-1": {ID: "contact-1", TenantID: "tenant-1"}}
+	scope := newContactScope(func(ctx context.Context, id string) (*entity.Contact, error) {
+		if contact, ok := contacts[id]; ok {
+			return contact, nil
+		}
+		return nil, errors.New(errors.ErrCodeContactNotFound, "contact not found")
+	})
+	ctx := context.Background()
+
+	contact, err := scope.FindByID(ctx, "tenant-1", "contact-1")
+	require.NoError(t, err)
+	assert.Equal(t, "contact-1", contact.ID)
+
+	for name, ids := range map[string][2]string{
+		"other tenant":   {"tenant-2", "contact-1"},
+		"missing record": {"tenant-1", "contact-2"},
+		"no tenant":      {"", "contact-1"},
+		"no id":          {"tenant-1", ""},
+	} {
+		_, err := scope.FindByID(ctx, ids[0], ids[1])
+		assert.True(t, errors.IsNotFound(err), name)
+	}
+}
+
+func TestTenantScope_FindByID_ReturnsLookupFailures(t *testing.T) {
+	lookupErr := errors.Wrap(fmt.Errorf("connection refused"), errors.ErrCodeInternal, "failed to find contact")
+	scope := newContactScope(func(ctx context.Context, id string) (*entity.Contact, error) {
+		return nil, lookupErr
+	})
+
+	_, err := scope.FindByID(context.Background(), "tenant-1", "contact-1")
+	assert.Same(t, lookupErr, err)
+	assert.False(t, errors.IsNotFound(err))
+
+	nilScope := newContactScope(func(ctx context.Context, id string) (*entity.Contact, error) {
+		return nil, nil
+	})
+	_, err = nilScope.FindByID(context.Background(), "tenant-1", "contact-1")
+	assert.True(t, errors.IsNotFound(err))
+}
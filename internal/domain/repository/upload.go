@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// UploadRepository defines persistence for resumable uploads
+type UploadRepository interface {
+	// Create creates an upload
+	Create(ctx context.Context, upload *entity.Upload) error
+
+	// FindByID finds an upload by ID
+	FindByID(ctx context.Context, id string) (*entity.Upload, error)
+
+	// Update updates an upload
+	Update(ctx context.Context, upload *entity.Upload) error
+
+	// AppendChunk records a staged chunk and advances the offset, provided the upload is
+	// still at the chunk's offset. It returns false when another chunk got there first.
+	AppendChunk(ctx context.Context, id string, offset, length int64) (bool, error)
+
+	// ListExpired lists unfinished uploads that expired before a time
+	ListExpired(ctx context.Context, before time.Time, limit int) ([]*entity.Upload, error)
+
+	// Delete deletes an upload
+	Delete(ctx context.Context, id string) error
+}
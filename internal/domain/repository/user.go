@@ -14,7 +14,8 @@ type UserRepository interface {
 	// FindByID finds a user by ID
 	FindByID(ctx context.Context, id string) (*entity.User, error)
 
-	// FindByEmail finds a user by email (across all tenants)
+	// FindByEmail finds a user by email (across all tenants), the most recently used
+	// account when the email has several
 	FindByEmail(ctx context.Context, email string) (*entity.User, error)
 
 	// FindByTenantAndEmail finds a user by tenant ID and email
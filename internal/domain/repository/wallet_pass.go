@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// WalletPassTemplateRepository defines persistence for wallet pass templates
+type WalletPassTemplateRepository interface {
+	// Create creates a new template
+	Create(ctx context.Context, template *entity.WalletPassTemplate) error
+
+	// FindByID finds a template by ID
+	FindByID(ctx context.Context, id string) (*entity.WalletPassTemplate, error)
+
+	// FindByTenant lists the templates of a tenant ordered by name
+	FindByTenant(ctx context.Context, tenantID string) ([]*entity.WalletPassTemplate, error)
+
+	// Update updates a template
+	Update(ctx context.Context, template *entity.WalletPassTemplate) error
+
+	// Delete deletes a template and the passes it issued
+	Delete(ctx context.Context, id string) error
+}
+
+// WalletPassRepository defines persistence for issued wallet passes and the Apple
+// Wallet devices registered for their updates
+type WalletPassRepository interface {
+	// Create creates a new pass
+	Create(ctx context.Context, pass *entity.WalletPass) error
+
+	// FindByID finds a pass by ID, which is also its serial number
+	FindByID(ctx context.Context, id string) (*entity.WalletPass, error)
+
+	// FindByLinkedObject lists the passes issued for a linked object
+	FindByLinkedObject(ctx context.Context, linkedObjectID string) ([]*entity.WalletPass, error)
+
+	// FindByContact lists the passes issued to a contact, newest first
+	FindByContact(ctx context.Context, contactID string) ([]*entity.WalletPass, error)
+
+	// Update updates a pass
+	Update(ctx context.Context, pass *entity.WalletPass) error
+
+	// SaveRegistration registers a device for the updates of a pass, replacing its push
+	// token when already registered. It reports whether the registration is new.
+	SaveRegistration(ctx context.Context, registration *entity.WalletPassRegistration) (bool, error)
+
+	// DeleteRegistration unregisters a device from the updates of a pass
+	DeleteRegistration(ctx context.Context, passID, deviceLibraryID string) error
+
+	// FindRegistrations lists the devices registered for the updates of a pass
+	FindRegistrations(ctx context.Context, passID string) ([]*entity.WalletPassRegistration, error)
+
+	// FindByDevice lists the passes a device is registered for that changed after since;
+	// a zero since lists them all
+	FindByDevice(ctx context.Context, deviceLibraryID string, since time.Time) ([]*entity.WalletPass, error)
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// WatchRepository defines persistence for conversation and contact watches
+type WatchRepository interface {
+	// Create creates a watch, keeping the existing one if the user already watches the target
+	Create(ctx context.Context, watch *entity.Watch) error
+
+	// Delete removes the watch of a user on a target
+	Delete(ctx context.Context, tenantID, userID string, targetType entity.WatchTargetType, targetID string) error
+
+	// ListByUser lists the watches of a user, newest first
+	ListByUser(ctx context.Context, tenantID, userID string) ([]*entity.Watch, error)
+
+	// ListByTarget lists the watches on a conversation or contact
+	ListByTarget(ctx context.Context, tenantID string, targetType entity.WatchTargetType, targetID string) ([]*entity.Watch, error)
+
+	// ListWatcherIDs lists the users watching a conversation directly or through its contact
+	ListWatcherIDs(ctx context.Context, tenantID, conversationID, contactID string) ([]string, error)
+}
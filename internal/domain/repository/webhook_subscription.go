@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// WebhookSubscriptionRepository defines persistence for outbound webhook subscriptions
+type WebhookSubscriptionRepository interface {
+	// Create creates a new subscription
+	Create(ctx context.Context, subscription *entity.WebhookSubscription) error
+
+	// FindByID finds a subscription by ID
+	FindByID(ctx context.Context, id string) (*entity.WebhookSubscription, error)
+
+	// FindByTenant lists the subscriptions of a tenant ordered by name
+	FindByTenant(ctx context.Context, tenantID string) ([]*entity.WebhookSubscription, error)
+
+	// FindActiveByTenant lists the active subscriptions of a tenant
+	FindActiveByTenant(ctx context.Context, tenantID string) ([]*entity.WebhookSubscription, error)
+
+	// Update updates a subscription
+	Update(ctx context.Context, subscription *entity.WebhookSubscription) error
+
+	// Delete deletes a subscription and its delivery log
+	Delete(ctx context.Context, id string) error
+}
+
+// WebhookDeliveryRepository defines persistence for the webhook delivery log
+type WebhookDeliveryRepository interface {
+	// Create creates a new delivery
+	Create(ctx context.Context, delivery *entity.WebhookDelivery) error
+
+	// FindByID finds a delivery by ID
+	FindByID(ctx context.Context, id string) (*entity.WebhookDelivery, error)
+
+	// ListBySubscription lists the deliveries of a subscription, newest first,
+	// optionally only those in a status
+	ListBySubscription(ctx context.Context, subscriptionID string, status entity.WebhookDeliveryStatus, params *ListParams) ([]*entity.WebhookDelivery, int64, error)
+
+	// Update records an attempt of a delivery
+	Update(ctx context.Context, delivery *entity.WebhookDelivery) error
+}
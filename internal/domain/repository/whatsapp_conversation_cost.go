@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// WhatsAppConversationCostRepository defines persistence for WhatsApp conversation pricing
+type WhatsAppConversationCostRepository interface {
+	// Create stores the pricing of a new conversation window
+	Create(ctx context.Context, cost *entity.WhatsAppConversationCost) error
+
+	// FindByWAConversationID finds the pricing recorded for a Meta conversation ID
+	FindByWAConversationID(ctx context.Context, channelID, waConversationID string) (*entity.WhatsAppConversationCost, error)
+
+	// List returns the conversation costs of a tenant in a period
+	List(ctx context.Context, filter entity.AnalyticsFilter) ([]*entity.WhatsAppConversationCost, error)
+
+	// SummarizeByChannel aggregates costs per channel
+	SummarizeByChannel(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.WhatsAppCostSummary, error)
+
+	// SummarizeByCampaign aggregates costs per campaign, skipping conversations outside campaigns
+	SummarizeByCampaign(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.WhatsAppCostSummary, error)
+}
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// WhatsAppPhoneNumberRepository defines persistence for the phone numbers of WhatsApp Official channels
+type WhatsAppPhoneNumberRepository interface {
+	// Create stores a new phone number
+	Create(ctx context.Context, number *entity.WhatsAppPhoneNumber) error
+
+	// FindByID finds a phone number by ID
+	FindByID(ctx context.Context, id string) (*entity.WhatsAppPhoneNumber, error)
+
+	// FindByChannel returns all phone numbers of a channel
+	FindByChannel(ctx context.Context, channelID string) ([]*entity.WhatsAppPhoneNumber, error)
+
+	// FindByPhoneNumberID finds the number registered for a Meta phone_number_id
+	FindByPhoneNumberID(ctx context.Context, phoneNumberID string) (*entity.WhatsAppPhoneNumber, error)
+
+	// Update updates a phone number
+	Update(ctx context.Context, number *entity.WhatsAppPhoneNumber) error
+
+	// Delete deletes a phone number
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+)
+
+// WidgetSettingsRepository defines persistence for the webchat widget settings of
+// tenants and the clicks on their quick replies
+type WidgetSettingsRepository interface {
+	// FindByTenant finds the widget settings of a tenant
+	FindByTenant(ctx context.Context, tenantID string) (*entity.WidgetSettings, error)
+
+	// Save creates or updates the widget settings of a tenant
+	Save(ctx context.Context, settings *entity.WidgetSettings) error
+
+	// CreateQuickReplyClick records a click on a quick reply
+	CreateQuickReplyClick(ctx context.Context, click *entity.QuickReplyClick) error
+
+	// QuickReplyStats counts the clicks on a tenant's quick replies in a period, most
+	// clicked first
+	QuickReplyStats(ctx context.Context, tenantID string, startDate, endDate time.Time) ([]*entity.QuickReplyStats, error)
+}
@@ -0,0 +1,111 @@
+package antivirus
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	// clamdChunkSize is the size of the chunks streamed to clamd; it must stay below
+	// clamd's StreamMaxLength
+	clamdChunkSize = 64 * 1024
+
+	defaultClamdTimeout = 2 * time.Minute
+)
+
+// Result is the outcome of a scan
+type Result struct {
+	Infected  bool
+	Signature string // Name of the detected malware
+}
+
+// ClamAVScanner scans data with a clamd daemon over its INSTREAM command
+type ClamAVScanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamAVScanner creates a scanner for the clamd listening at address, either
+// "host:port" or a unix socket path
+func NewClamAVScanner(address string) *ClamAVScanner {
+	network := "tcp"
+	if strings.HasPrefix(address, "/") {
+		network = "unix"
+	}
+	return &ClamAVScanner{
+		network: network,
+		address: address,
+		timeout: defaultClamdTimeout,
+	}
+}
+
+// Scan streams data to clamd and reports whether it is infected
+func (s *ClamAVScanner) Scan(ctx context.Context, data []byte) (*Result, error) {
+	dialer := net.Dialer{Timeout: 10 * time.Second}
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(s.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return nil, fmt.Errorf("failed to start scan: %w", err)
+	}
+
+	size := make([]byte, 4)
+	for start := 0; start < len(data); start += clamdChunkSize {
+		end := start + clamdChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		binary.BigEndian.PutUint32(size, uint32(end-start))
+		if _, err := conn.Write(size); err != nil {
+			return nil, fmt.Errorf("failed to stream data: %w", err)
+		}
+		if _, err := conn.Write(data[start:end]); err != nil {
+			return nil, fmt.Errorf("failed to stream data: %w", err)
+		}
+	}
+	// A zero length chunk ends the stream
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err := conn.Write(size); err != nil {
+		return nil, fmt.Errorf("failed to end stream: %w", err)
+	}
+
+	reply, err := io.ReadAll(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scan result: %w", err)
+	}
+	return parseClamdReply(string(bytes.TrimRight(reply, "\x00\n")))
+}
+
+// parseClamdReply reads a reply like "stream: OK" or "stream: Eicar-Signature FOUND"
+func parseClamdReply(reply string) (*Result, error) {
+	reply = strings.TrimSpace(strings.TrimPrefix(reply, "stream:"))
+	switch {
+	case reply == "OK":
+		return &Result{}, nil
+	case strings.HasSuffix(reply, " FOUND"):
+		return &Result{
+			Infected:  true,
+			Signature: strings.TrimSpace(strings.TrimSuffix(reply, " FOUND")),
+		}, nil
+	case strings.HasSuffix(reply, " ERROR"):
+		return nil, fmt.Errorf("clamd error: %s", strings.TrimSuffix(reply, " ERROR"))
+	default:
+		return nil, fmt.Errorf("unexpected clamd reply: %q", reply)
+	}
+}
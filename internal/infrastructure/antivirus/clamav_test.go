@@ -0,0 +1,89 @@
+package antivirus
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClamd accepts one INSTREAM scan and answers with reply. The streamed data is
+// sent on the returned channel.
+func fakeClamd(t *testing.T, reply string) (string, <-chan []byte) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		command := make([]byte, len("zINSTREAM\x00"))
+		if _, err := io.ReadFull(conn, command); err != nil || string(command) != "zINSTREAM\x00" {
+			return
+		}
+		var data bytes.Buffer
+		size := make([]byte, 4)
+		for {
+			if _, err := io.ReadFull(conn, size); err != nil {
+				return
+			}
+			n := binary.BigEndian.Uint32(size)
+			if n == 0 {
+				break
+			}
+			if _, err := io.CopyN(&data, conn, int64(n)); err != nil {
+				return
+			}
+		}
+		received <- data.Bytes()
+		conn.Write([]byte(reply + "\x00"))
+	}()
+	return listener.Addr().String(), received
+}
+
+func TestClamAVScanner_Clean(t *testing.T) {
+	address, received := fakeClamd(t, "stream: OK")
+	data := bytes.Repeat([]byte("a"), clamdChunkSize*2+10)
+
+	result, err := NewClamAVScanner(address).Scan(context.Background(), data)
+	require.NoError(t, err)
+	assert.False(t, result.Infected)
+	assert.Equal(t, data, <-received)
+}
+
+func TestClamAVScanner_Infected(t *testing.T) {
+	address, _ := fakeClamd(t, "stream: Eicar-Test-Signature FOUND")
+
+	result, err := NewClamAVScanner(address).Scan(context.Background(), []byte("X5O!P%@AP"))
+	require.NoError(t, err)
+	assert.True(t, result.Infected)
+	assert.Equal(t, "Eicar-Test-Signature", result.Signature)
+}
+
+func TestClamAVScanner_Error(t *testing.T) {
+	address, _ := fakeClamd(t, "INSTREAM size limit exceeded. ERROR")
+
+	_, err := NewClamAVScanner(address).Scan(context.Background(), []byte("data"))
+	assert.Error(t, err)
+}
+
+func TestClamAVScanner_Unreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	_, err = NewClamAVScanner(address).Scan(context.Background(), []byte("data"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,193 @@
+// Package calendar reads availability from and writes events to the external
+// calendars customers book time on.
+//
+// Google Calendar and Microsoft 365 (Graph) are supported. Both authorize with
+// OAuth access tokens obtained from a stored refresh token and the client
+// credentials of the app registered with the provider.
+package calendar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Provider names
+const (
+	ProviderGoogle    = "google"
+	ProviderMicrosoft = "microsoft"
+)
+
+const requestTimeout = 30 * time.Second
+
+var (
+	// ErrUnauthorized is returned when the provider rejects the credentials
+	ErrUnauthorized = errors.New("calendar credentials were rejected")
+	// ErrNotConfigured is returned when the provider has no OAuth client configured
+	ErrNotConfigured = errors.New("calendar provider is not configured")
+)
+
+// Interval is a busy period of a calendar
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Event is a calendar event created for a booking
+type Event struct {
+	Title         string
+	Description   string
+	Start         time.Time
+	End           time.Time
+	Timezone      string
+	AttendeeName  string
+	AttendeeEmail string
+}
+
+// Token is an OAuth access token. RefreshToken is only set when the provider
+// rotated the refresh token.
+type Token struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+}
+
+// Provider is an external calendar
+type Provider interface {
+	// Name returns the provider name
+	Name() string
+	// RefreshToken exchanges a refresh token for an access token
+	RefreshToken(ctx context.Context, refreshToken string) (*Token, error)
+	// FreeBusy returns the busy periods of a calendar between from and to
+	FreeBusy(ctx context.Context, accessToken, calendarID string, from, to time.Time) ([]Interval, error)
+	// CreateEvent creates an event and returns its ID
+	CreateEvent(ctx context.Context, accessToken, calendarID string, event *Event) (string, error)
+	// UpdateEvent moves or renames an event
+	UpdateEvent(ctx context.Context, accessToken, calendarID, eventID string, event *Event) error
+	// DeleteEvent deletes an event; deleting a missing event is not an error
+	DeleteEvent(ctx context.Context, accessToken, calendarID, eventID string) error
+}
+
+// oauthClient exchanges refresh tokens at an OAuth token endpoint
+type oauthClient struct {
+	httpClient   *http.Client
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scope        string
+}
+
+func (c *oauthClient) refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	if c.clientID == "" || c.clientSecret == "" {
+		return nil, ErrNotConfigured
+	}
+
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {c.clientID},
+		"client_secret": {c.clientSecret},
+		"refresh_token": {refreshToken},
+	}
+	if c.scope != "" {
+		form.Set("scope", c.scope)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var resp struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int    `json:"expires_in"`
+	}
+	if err := do(c.httpClient, req, &resp); err != nil {
+		if errors.Is(err, errBadRequest) {
+			// An expired or revoked refresh token
+			return nil, ErrUnauthorized
+		}
+		return nil, err
+	}
+	if resp.AccessToken == "" {
+		return nil, fmt.Errorf("token response has no access token")
+	}
+
+	return &Token{
+		AccessToken:  resp.AccessToken,
+		RefreshToken: resp.RefreshToken,
+		ExpiresAt:    time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second),
+	}, nil
+}
+
+var errBadRequest = errors.New("bad request")
+
+// doJSON sends a JSON API request authorized with an access token
+func doJSON(ctx context.Context, client *http.Client, method, endpoint, accessToken string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	return do(client, req, out)
+}
+
+func do(client *http.Client, req *http.Request, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("calendar request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return ErrUnauthorized
+	case resp.StatusCode == http.StatusBadRequest:
+		return fmt.Errorf("%w: %s", errBadRequest, snippet(data))
+	case resp.StatusCode >= 300:
+		return fmt.Errorf("calendar API returned %d: %s", resp.StatusCode, snippet(data))
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode calendar response: %w", err)
+	}
+	return nil
+}
+
+func snippet(data []byte) string {
+	text := strings.TrimSpace(string(data))
+	if len(text) > 200 {
+		text = text[:200]
+	}
+	return text
+}
+
+func newHTTPClient() *http.Client {
+	return &http.Client{Timeout: requestTimeout}
+}
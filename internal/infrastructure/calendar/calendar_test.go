@@ -0,0 +1,112 @@
+package calendar
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoogleProvider(t *testing.T) {
+	var created map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "refresh_token", r.Form.Get("grant_type"))
+			if r.Form.Get("refresh_token") == "revoked" {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"invalid_grant"}`))
+				return
+			}
+			w.Write([]byte(`{"access_token":"access-1","expires_in":3600}`))
+		case r.URL.Path == "/freeBusy":
+			assert.Equal(t, "Bearer access-1", r.Header.Get("Authorization"))
+			w.Write([]byte(`{"calendars":{"primary":{"busy":[{"start":"2026-03-02T13:00:00Z","end":"2026-03-02T14:00:00Z"}]}}}`))
+		case r.URL.Path == "/calendars/primary/events" && r.Method == http.MethodPost:
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&created))
+			w.Write([]byte(`{"id":"evt-1"}`))
+		case r.URL.Path == "/calendars/primary/events/evt-1" && r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusGone)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p := NewGoogleProvider("client", "secret")
+	p.oauth.tokenURL = server.URL + "/token"
+	p.apiURL = server.URL
+	ctx := context.Background()
+
+	token, err := p.RefreshToken(ctx, "refresh")
+	require.NoError(t, err)
+	assert.Equal(t, "access-1", token.AccessToken)
+	assert.True(t, token.ExpiresAt.After(time.Now()))
+
+	_, err = p.RefreshToken(ctx, "revoked")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	from := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	busy, err := p.FreeBusy(ctx, token.AccessToken, "", from, from.Add(24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, busy, 1)
+	assert.Equal(t, 13, busy[0].Start.Hour())
+
+	id, err := p.CreateEvent(ctx, token.AccessToken, "primary", &Event{
+		Title:         "Consultation",
+		Start:         from.Add(9 * time.Hour),
+		End:           from.Add(10 * time.Hour),
+		Timezone:      "UTC",
+		AttendeeEmail: "ana@example.com",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "evt-1", id)
+	assert.Equal(t, "Consultation", created["summary"])
+	assert.NotNil(t, created["attendees"])
+
+	// The event is already gone
+	assert.NoError(t, p.DeleteEvent(ctx, token.AccessToken, "primary", "evt-1"))
+
+	_, err = NewGoogleProvider("", "").RefreshToken(ctx, "refresh")
+	assert.ErrorIs(t, err, ErrNotConfigured)
+}
+
+func TestMicrosoftProviderFreeBusy(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, `outlook.timezone="UTC"`, r.Header.Get("Prefer"))
+		if r.Header.Get("Authorization") != "Bearer access-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("page") == "2" {
+			w.Write([]byte(`{"value":[{"start":{"dateTime":"2026-03-02T15:00:00.0000000","timeZone":"UTC"},"end":{"dateTime":"2026-03-02T15:30:00.0000000","timeZone":"UTC"},"showAs":"busy"}]}`))
+			return
+		}
+		w.Write([]byte(`{"value":[
+			{"start":{"dateTime":"2026-03-02T13:00:00.0000000","timeZone":"UTC"},"end":{"dateTime":"2026-03-02T14:00:00.0000000","timeZone":"UTC"},"showAs":"busy"},
+			{"start":{"dateTime":"2026-03-02T10:00:00.0000000","timeZone":"UTC"},"end":{"dateTime":"2026-03-02T11:00:00.0000000","timeZone":"UTC"},"showAs":"free"}
+		],"@odata.nextLink":"` + server.URL + `/me/calendar/calendarView?page=2"}`))
+	}))
+	defer server.Close()
+
+	p := NewMicrosoftProvider("client", "secret", "")
+	p.apiURL = server.URL
+	ctx := context.Background()
+
+	from := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	busy, err := p.FreeBusy(ctx, "access-1", "", from, from.Add(24*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, busy, 2)
+	assert.Equal(t, time.Date(2026, 3, 2, 13, 0, 0, 0, time.UTC), busy[0].Start.UTC())
+	assert.Equal(t, 15, busy[1].Start.Hour())
+
+	_, err = p.FreeBusy(ctx, "expired", "", from, from.Add(24*time.Hour))
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
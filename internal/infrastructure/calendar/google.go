@@ -0,0 +1,149 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// GoogleProvider uses the Google Calendar v3 API
+type GoogleProvider struct {
+	oauth      *oauthClient
+	httpClient *http.Client
+	apiURL     string
+}
+
+// NewGoogleProvider creates a Google Calendar provider for the OAuth client
+func NewGoogleProvider(clientID, clientSecret string) *GoogleProvider {
+	httpClient := newHTTPClient()
+	return &GoogleProvider{
+		oauth: &oauthClient{
+			httpClient:   httpClient,
+			tokenURL:     "https://oauth2.googleapis.com/token",
+			clientID:     clientID,
+			clientSecret: clientSecret,
+		},
+		httpClient: httpClient,
+		apiURL:     "https://www.googleapis.com/calendar/v3",
+	}
+}
+
+// Name returns the provider name
+func (p *GoogleProvider) Name() string {
+	return ProviderGoogle
+}
+
+// RefreshToken exchanges a refresh token for an access token
+func (p *GoogleProvider) RefreshToken(ctx context.Context, refreshToken string) (*Token, error) {
+	return p.oauth.refresh(ctx, refreshToken)
+}
+
+// FreeBusy returns the busy periods of a calendar between from and to
+func (p *GoogleProvider) FreeBusy(ctx context.Context, accessToken, calendarID string, from, to time.Time) ([]Interval, error) {
+	calendarID = googleCalendarID(calendarID)
+	body := map[string]interface{}{
+		"timeMin": from.UTC().Format(time.RFC3339),
+		"timeMax": to.UTC().Format(time.RFC3339),
+		"items":   []map[string]string{{"id": calendarID}},
+	}
+
+	var resp struct {
+		Calendars map[string]struct {
+			Busy []struct {
+				Start time.Time `json:"start"`
+				End   time.Time `json:"end"`
+			} `json:"busy"`
+			Errors []struct {
+				Reason string `json:"reason"`
+			} `json:"errors"`
+		} `json:"calendars"`
+	}
+	if err := doJSON(ctx, p.httpClient, http.MethodPost, p.apiURL+"/freeBusy", accessToken, body, &resp); err != nil {
+		return nil, err
+	}
+
+	result, ok := resp.Calendars[calendarID]
+	if !ok {
+		return nil, fmt.Errorf("calendar %s missing from free/busy response", calendarID)
+	}
+	if len(result.Errors) > 0 {
+		return nil, fmt.Errorf("free/busy query failed: %s", result.Errors[0].Reason)
+	}
+
+	busy := make([]Interval, 0, len(result.Busy))
+	for _, b := range result.Busy {
+		busy = append(busy, Interval{Start: b.Start, End: b.End})
+	}
+	return busy, nil
+}
+
+// CreateEvent creates an event and returns its ID
+func (p *GoogleProvider) CreateEvent(ctx context.Context, accessToken, calendarID string, event *Event) (string, error) {
+	var resp struct {
+		ID string `json:"id"`
+	}
+	endpoint := p.eventsURL(calendarID) + "?sendUpdates=all"
+	if err := doJSON(ctx, p.httpClient, http.MethodPost, endpoint, accessToken, googleEvent(event), &resp); err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// UpdateEvent moves or renames an event
+func (p *GoogleProvider) UpdateEvent(ctx context.Context, accessToken, calendarID, eventID string, event *Event) error {
+	endpoint := p.eventsURL(calendarID) + "/" + url.PathEscape(eventID) + "?sendUpdates=all"
+	return doJSON(ctx, p.httpClient, http.MethodPatch, endpoint, accessToken, googleEvent(event), nil)
+}
+
+// DeleteEvent deletes an event; deleting a missing event is not an error
+func (p *GoogleProvider) DeleteEvent(ctx context.Context, accessToken, calendarID, eventID string) error {
+	endpoint := p.eventsURL(calendarID) + "/" + url.PathEscape(eventID) + "?sendUpdates=all"
+	err := doJSON(ctx, p.httpClient, http.MethodDelete, endpoint, accessToken, nil, nil)
+	if err != nil && isGone(err) {
+		return nil
+	}
+	return err
+}
+
+func (p *GoogleProvider) eventsURL(calendarID string) string {
+	return p.apiURL + "/calendars/" + url.PathEscape(googleCalendarID(calendarID)) + "/events"
+}
+
+func googleCalendarID(calendarID string) string {
+	if calendarID == "" {
+		return "primary"
+	}
+	return calendarID
+}
+
+func googleEvent(event *Event) map[string]interface{} {
+	start := map[string]string{"dateTime": event.Start.Format(time.RFC3339)}
+	end := map[string]string{"dateTime": event.End.Format(time.RFC3339)}
+	if event.Timezone != "" {
+		start["timeZone"] = event.Timezone
+		end["timeZone"] = event.Timezone
+	}
+	body := map[string]interface{}{
+		"summary":     event.Title,
+		"description": event.Description,
+		"start":       start,
+		"end":         end,
+	}
+	if event.AttendeeEmail != "" {
+		body["attendees"] = []map[string]string{{"email": event.AttendeeEmail, "displayName": event.AttendeeName}}
+	}
+	return body
+}
+
+// isGone reports whether an API error says the resource no longer exists
+func isGone(err error) bool {
+	if errors.Is(err, ErrUnauthorized) {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "returned 404") || strings.Contains(msg, "returned 410")
+}
@@ -0,0 +1,173 @@
+package calendar
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// graphTimeLayout is the date-time format of Microsoft Graph, without offset
+const graphTimeLayout = "2006-01-02T15:04:05.0000000"
+
+// maxCalendarViewPages bounds how many pages of events FreeBusy follows
+const maxCalendarViewPages = 20
+
+// MicrosoftProvider uses the Microsoft Graph calendar API
+type MicrosoftProvider struct {
+	oauth      *oauthClient
+	httpClient *http.Client
+	apiURL     string
+}
+
+// NewMicrosoftProvider creates a Microsoft 365 calendar provider for the OAuth
+// client. directory is the Azure AD tenant, "common" when empty.
+func NewMicrosoftProvider(clientID, clientSecret, directory string) *MicrosoftProvider {
+	if directory == "" {
+		directory = "common"
+	}
+	httpClient := newHTTPClient()
+	return &MicrosoftProvider{
+		oauth: &oauthClient{
+			httpClient:   httpClient,
+			tokenURL:     "https://login.microsoftonline.com/" + url.PathEscape(directory) + "/oauth2/v2.0/token",
+			clientID:     clientID,
+			clientSecret: clientSecret,
+			scope:        "offline_access Calendars.ReadWrite",
+		},
+		httpClient: httpClient,
+		apiURL:     "https://graph.microsoft.com/v1.0",
+	}
+}
+
+// Name returns the provider name
+func (p *MicrosoftProvider) Name() string {
+	return ProviderMicrosoft
+}
+
+// RefreshToken exchanges a refresh token for an access token
+func (p *MicrosoftProvider) RefreshToken(ctx context.Context, refreshToken string) (*Token, error) {
+	return p.oauth.refresh(ctx, refreshToken)
+}
+
+type graphDateTime struct {
+	DateTime string `json:"dateTime"`
+	TimeZone string `json:"timeZone"`
+}
+
+func (d graphDateTime) time() (time.Time, error) {
+	loc := time.UTC
+	if d.TimeZone != "" && d.TimeZone != "UTC" {
+		if l, err := time.LoadLocation(d.TimeZone); err == nil {
+			loc = l
+		}
+	}
+	return time.ParseInLocation(graphTimeLayout, d.DateTime, loc)
+}
+
+// FreeBusy returns the busy periods of a calendar between from and to. Events
+// shown as free do not block time.
+func (p *MicrosoftProvider) FreeBusy(ctx context.Context, accessToken, calendarID string, from, to time.Time) ([]Interval, error) {
+	query := url.Values{
+		"startDateTime": {from.UTC().Format(time.RFC3339)},
+		"endDateTime":   {to.UTC().Format(time.RFC3339)},
+		"$select":       {"start,end,showAs,isCancelled"},
+		"$top":          {"100"},
+	}
+	endpoint := p.calendarURL(calendarID) + "/calendarView?" + query.Encode()
+
+	var busy []Interval
+	for page := 0; endpoint != "" && page < maxCalendarViewPages; page++ {
+		var resp struct {
+			Value []struct {
+				Start       graphDateTime `json:"start"`
+				End         graphDateTime `json:"end"`
+				ShowAs      string        `json:"showAs"`
+				IsCancelled bool          `json:"isCancelled"`
+			} `json:"value"`
+			NextLink string `json:"@odata.nextLink"`
+		}
+		if err := p.get(ctx, endpoint, accessToken, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, item := range resp.Value {
+			if item.IsCancelled || strings.EqualFold(item.ShowAs, "free") {
+				continue
+			}
+			start, err := item.Start.time()
+			if err != nil {
+				return nil, fmt.Errorf("invalid event start: %w", err)
+			}
+			end, err := item.End.time()
+			if err != nil {
+				return nil, fmt.Errorf("invalid event end: %w", err)
+			}
+			busy = append(busy, Interval{Start: start, End: end})
+		}
+		endpoint = resp.NextLink
+	}
+	return busy, nil
+}
+
+func (p *MicrosoftProvider) get(ctx context.Context, endpoint, accessToken string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+	// Event times are returned in UTC
+	req.Header.Set("Prefer", `outlook.timezone="UTC"`)
+	return do(p.httpClient, req, out)
+}
+
+// CreateEvent creates an event and returns its ID
+func (p *MicrosoftProvider) CreateEvent(ctx context.Context, accessToken, calendarID string, event *Event) (string, error) {
+	var resp struct {
+		ID string `json:"id"`
+	}
+	if err := doJSON(ctx, p.httpClient, http.MethodPost, p.calendarURL(calendarID)+"/events", accessToken, graphEvent(event), &resp); err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// UpdateEvent moves or renames an event
+func (p *MicrosoftProvider) UpdateEvent(ctx context.Context, accessToken, calendarID, eventID string, event *Event) error {
+	return doJSON(ctx, p.httpClient, http.MethodPatch, p.apiURL+"/me/events/"+url.PathEscape(eventID), accessToken, graphEvent(event), nil)
+}
+
+// DeleteEvent deletes an event; deleting a missing event is not an error
+func (p *MicrosoftProvider) DeleteEvent(ctx context.Context, accessToken, calendarID, eventID string) error {
+	err := doJSON(ctx, p.httpClient, http.MethodDelete, p.apiURL+"/me/events/"+url.PathEscape(eventID), accessToken, nil, nil)
+	if err != nil && isGone(err) {
+		return nil
+	}
+	return err
+}
+
+func (p *MicrosoftProvider) calendarURL(calendarID string) string {
+	if calendarID == "" {
+		return p.apiURL + "/me/calendar"
+	}
+	return p.apiURL + "/me/calendars/" + url.PathEscape(calendarID)
+}
+
+func graphEvent(event *Event) map[string]interface{} {
+	body := map[string]interface{}{
+		"subject": event.Title,
+		"body":    map[string]string{"contentType": "text", "content": event.Description},
+		"start":   graphDateTime{DateTime: event.Start.UTC().Format(graphTimeLayout), TimeZone: "UTC"},
+		"end":     graphDateTime{DateTime: event.End.UTC().Format(graphTimeLayout), TimeZone: "UTC"},
+	}
+	if event.AttendeeEmail != "" {
+		body["attendees"] = []map[string]interface{}{{
+			"emailAddress": map[string]string{"address": event.AttendeeEmail, "name": event.AttendeeName},
+			"type":         "required",
+		}}
+	}
+	return body
+}
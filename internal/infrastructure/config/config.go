@@ -13,6 +13,8 @@ type Config struct {
 	Database DatabaseConfig `mapstructure:"database"`
 	Redis    RedisConfig    `mapstructure:"redis"`
 	NATS     NATSConfig     `mapstructure:"nats"`
+	WhatsApp WhatsAppConfig `mapstructure:"whatsapp"`
+	Storage  StorageConfig  `mapstructure:"storage"`
 	JWT      JWTConfig      `mapstructure:"jwt"`
 	Log      LogConfig      `mapstructure:"log"`
 }
@@ -64,6 +66,31 @@ type NATSConfig struct {
 	URL       string `mapstructure:"url"`
 	ClusterID string `mapstructure:"cluster_id"`
 	ClientID  string `mapstructure:"client_id"`
+	// Concurrency is the number of messages handled in parallel per subscription;
+	// messages of the same conversation are always handled in order
+	Concurrency int `mapstructure:"concurrency"`
+}
+
+// WhatsAppConfig holds WhatsApp unofficial (whatsmeow) session configuration
+type WhatsAppConfig struct {
+	SessionStore string `mapstructure:"session_store"` // sqlite (per-instance files) or postgres (shared)
+	Failover     bool   `mapstructure:"failover"`      // standby instances take over channels, requires the postgres store
+	LeaseTTL     int    `mapstructure:"lease_ttl"`     // in seconds
+	LeaseStore   string `mapstructure:"lease_store"`   // postgres or redis (REDIS_URL) for channel ownership locks
+	InstanceID   string `mapstructure:"instance_id"`   // defaults to hostname and process ID
+}
+
+// StorageConfig holds object storage configuration for inbound media
+type StorageConfig struct {
+	Driver     string `mapstructure:"driver"`    // none, local, minio or s3
+	LocalDir   string `mapstructure:"local_dir"` // local driver directory
+	Endpoint   string `mapstructure:"endpoint"`  // minio host:port
+	Region     string `mapstructure:"region"`
+	Bucket     string `mapstructure:"bucket"`
+	AccessKey  string `mapstructure:"access_key"`
+	SecretKey  string `mapstructure:"secret_key"`
+	UseSSL     bool   `mapstructure:"use_ssl"`
+	SigningKey string `mapstructure:"signing_key"` // signs media URLs, defaults to the JWT secret
 }
 
 // JWTConfig holds JWT authentication configuration
@@ -140,6 +167,22 @@ func setDefaults() {
 	viper.SetDefault("nats.url", "nats://localhost:4222")
 	viper.SetDefault("nats.cluster_id", "linktor-cluster")
 	viper.SetDefault("nats.client_id", "linktor-server")
+	viper.SetDefault("nats.concurrency", 4)
+
+	// WhatsApp defaults
+	viper.SetDefault("whatsapp.session_store", "sqlite")
+	viper.SetDefault("whatsapp.failover", false)
+	viper.SetDefault("whatsapp.lease_ttl", 30)
+	viper.SetDefault("whatsapp.lease_store", "postgres")
+	viper.SetDefault("whatsapp.instance_id", "")
+
+	// Storage defaults
+	viper.SetDefault("storage.driver", "none")
+	viper.SetDefault("storage.local_dir", "./data/media")
+	viper.SetDefault("storage.region", "us-east-1")
+	viper.SetDefault("storage.bucket", "linktor")
+	viper.SetDefault("storage.use_ssl", false)
+	viper.SetDefault("storage.signing_key", "")
 
 	// JWT defaults
 	viper.SetDefault("jwt.secret", "change-me-in-production")
@@ -0,0 +1,223 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// AIUsageRepository implements repository.AIUsageRepository with PostgreSQL
+type AIUsageRepository struct {
+	db *PostgresDB
+}
+
+// NewAIUsageRepository creates a new PostgreSQL AI usage repository
+func NewAIUsageRepository(db *PostgresDB) *AIUsageRepository {
+	return &AIUsageRepository{db: db}
+}
+
+// SaveRecords stores AI calls and adds them to the monthly aggregates
+func (r *AIUsageRepository) SaveRecords(ctx context.Context, records []*entity.AIUsageRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to begin AI usage update")
+	}
+	defer tx.Rollback(ctx)
+
+	for _, record := range records {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO ai_usage_records (
+				id, tenant_id, bot_id, feature, provider, model, prompt_tokens, completion_tokens, cost_usd, created_at
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`,
+			record.ID,
+			record.TenantID,
+			record.BotID,
+			string(record.Feature),
+			string(record.Provider),
+			record.Model,
+			record.PromptTokens,
+			record.CompletionTokens,
+			record.CostUSD,
+			record.CreatedAt,
+		); err != nil {
+			return errors.Wrap(err, errors.ErrCodeInternal, "failed to record AI usage")
+		}
+
+		month, _ := entity.QuotaPeriod(record.CreatedAt)
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO ai_usage_monthly (
+				tenant_id, month, bot_id, feature, provider, model, requests, prompt_tokens, completion_tokens, cost_usd
+			) VALUES ($1, $2, $3, $4, $5, $6, 1, $7, $8, $9)
+			ON CONFLICT (tenant_id, month, bot_id, feature, provider, model) DO UPDATE SET
+				requests = ai_usage_monthly.requests + 1,
+				prompt_tokens = ai_usage_monthly.prompt_tokens + EXCLUDED.prompt_tokens,
+				completion_tokens = ai_usage_monthly.completion_tokens + EXCLUDED.completion_tokens,
+				cost_usd = ai_usage_monthly.cost_usd + EXCLUDED.cost_usd
+		`,
+			record.TenantID,
+			month,
+			record.BotID,
+			string(record.Feature),
+			string(record.Provider),
+			record.Model,
+			record.PromptTokens,
+			record.CompletionTokens,
+			record.CostUSD,
+		); err != nil {
+			return errors.Wrap(err, errors.ErrCodeInternal, "failed to aggregate AI usage")
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to commit AI usage update")
+	}
+	return nil
+}
+
+// FindRecords lists a tenant's AI calls in [since, until), newest first
+func (r *AIUsageRepository) FindRecords(ctx context.Context, tenantID string, since, until time.Time, limit int) ([]*entity.AIUsageRecord, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT id, tenant_id, bot_id, feature, provider, model, prompt_tokens, completion_tokens, cost_usd::float8, created_at
+		FROM ai_usage_records
+		WHERE tenant_id = $1 AND created_at >= $2 AND created_at < $3
+		ORDER BY created_at DESC
+		LIMIT $4
+	`, tenantID, since, until, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list AI usage")
+	}
+	defer rows.Close()
+
+	records := make([]*entity.AIUsageRecord, 0)
+	for rows.Next() {
+		var record entity.AIUsageRecord
+		var feature, provider string
+		if err := rows.Scan(
+			&record.ID,
+			&record.TenantID,
+			&record.BotID,
+			&feature,
+			&provider,
+			&record.Model,
+			&record.PromptTokens,
+			&record.CompletionTokens,
+			&record.CostUSD,
+			&record.CreatedAt,
+		); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan AI usage")
+		}
+		record.Feature = entity.AIFeature(feature)
+		record.Provider = entity.AIProviderType(provider)
+		records = append(records, &record)
+	}
+	return records, rows.Err()
+}
+
+// FindAggregates returns a tenant's usage per bot, feature and model in a month
+func (r *AIUsageRepository) FindAggregates(ctx context.Context, tenantID string, month time.Time) ([]*entity.AIUsageAggregate, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT tenant_id, month, bot_id, feature, provider, model, requests, prompt_tokens, completion_tokens, cost_usd::float8
+		FROM ai_usage_monthly
+		WHERE tenant_id = $1 AND month = $2
+		ORDER BY cost_usd DESC, requests DESC
+	`, tenantID, month)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list AI usage aggregates")
+	}
+	defer rows.Close()
+
+	var aggregates []*entity.AIUsageAggregate
+	for rows.Next() {
+		var aggregate entity.AIUsageAggregate
+		var feature, provider string
+		if err := rows.Scan(
+			&aggregate.TenantID,
+			&aggregate.Month,
+			&aggregate.BotID,
+			&feature,
+			&provider,
+			&aggregate.Model,
+			&aggregate.Requests,
+			&aggregate.PromptTokens,
+			&aggregate.CompletionTokens,
+			&aggregate.CostUSD,
+		); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan AI usage aggregate")
+		}
+		aggregate.Feature = entity.AIFeature(feature)
+		aggregate.Provider = entity.AIProviderType(provider)
+		aggregates = append(aggregates, &aggregate)
+	}
+	return aggregates, rows.Err()
+}
+
+// SumMonths returns a tenant's usage per month from the month of since, oldest first
+func (r *AIUsageRepository) SumMonths(ctx context.Context, tenantID string, since time.Time) ([]entity.AIUsageMonth, error) {
+	firstMonth, _ := entity.QuotaPeriod(since)
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT month, SUM(requests), SUM(prompt_tokens), SUM(completion_tokens), SUM(cost_usd)::float8
+		FROM ai_usage_monthly
+		WHERE tenant_id = $1 AND month >= $2
+		GROUP BY month
+		ORDER BY month
+	`, tenantID, firstMonth)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to sum AI usage")
+	}
+	defer rows.Close()
+
+	months := make([]entity.AIUsageMonth, 0)
+	for rows.Next() {
+		var month entity.AIUsageMonth
+		if err := rows.Scan(&month.Month, &month.Requests, &month.PromptTokens, &month.CompletionTokens, &month.CostUSD); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan AI usage")
+		}
+		month.TotalTokens = month.PromptTokens + month.CompletionTokens
+		months = append(months, month)
+	}
+	return months, rows.Err()
+}
+
+// SumCost returns a tenant's AI cost in a month
+func (r *AIUsageRepository) SumCost(ctx context.Context, tenantID string, month time.Time) (float64, error) {
+	var cost float64
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT COALESCE(SUM(cost_usd), 0)::float8
+		FROM ai_usage_monthly
+		WHERE tenant_id = $1 AND month = $2
+	`, tenantID, month).Scan(&cost)
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to sum AI cost")
+	}
+	return cost, nil
+}
+
+// DeleteRecordsBefore removes AI calls made before a time; aggregates are kept
+func (r *AIUsageRepository) DeleteRecordsBefore(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM ai_usage_records WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to prune AI usage")
+	}
+	return tag.RowsAffected(), nil
+}
+
+// CreateBudgetAlert records a sent budget alert. It returns false when the alert
+// was already recorded for the month.
+func (r *AIUsageRepository) CreateBudgetAlert(ctx context.Context, alert *entity.AIBudgetAlert) (bool, error) {
+	tag, err := r.db.Pool.Exec(ctx, `
+		INSERT INTO ai_budget_alerts (tenant_id, period_start, level, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id, period_start, level) DO NOTHING
+	`, alert.TenantID, alert.PeriodStart, string(alert.Level), alert.CreatedAt)
+	if err != nil {
+		return false, errors.Wrap(err, errors.ErrCodeInternal, "failed to record AI budget alert")
+	}
+	return tag.RowsAffected() == 1, nil
+}
@@ -282,3 +282,238 @@ func (r *AnalyticsRepository) GetChannelAnalytics(ctx context.Context, filter en
 
 	return result, rows.Err()
 }
+
+// GetLabelAnalytics returns conversation metrics grouped by label. Conversations
+// count towards every label they carry, so totals may exceed the conversation count.
+func (r *AnalyticsRepository) GetLabelAnalytics(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.LabelAnalytics, error) {
+	query := `
+		SELECT
+			l.id,
+			l.name,
+			l.color,
+			COUNT(c.id) as total,
+			COUNT(c.id) FILTER (WHERE c.status IN ('open', 'pending')) as open,
+			COUNT(c.id) FILTER (WHERE c.status IN ('resolved', 'closed')) as resolved,
+			COALESCE(AVG(EXTRACT(EPOCH FROM (c.resolved_at - c.created_at)) / 60)
+				FILTER (WHERE c.resolved_at IS NOT NULL), 0) as avg_resolution_minutes
+		FROM labels l
+		LEFT JOIN conversation_labels cl ON cl.label_id = l.id
+		LEFT JOIN conversations c ON c.id = cl.conversation_id
+			AND c.created_at >= $2
+			AND c.created_at < $3
+		WHERE l.tenant_id = $1
+		GROUP BY l.id, l.name, l.color
+		ORDER BY total DESC, l.name
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, filter.TenantID, filter.StartDate, filter.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []entity.LabelAnalytics
+	for rows.Next() {
+		var la entity.LabelAnalytics
+		if err := rows.Scan(&la.LabelID, &la.LabelName, &la.LabelColor, &la.TotalConversations,
+			&la.OpenConversations, &la.ResolvedConversations, &la.AvgResolutionMinutes); err != nil {
+			return nil, err
+		}
+		result = append(result, la)
+	}
+
+	return result, rows.Err()
+}
+
+// GetDispositionAnalytics returns the metrics of the conversations given each disposition
+// in the period, including active dispositions not used yet
+func (r *AnalyticsRepository) GetDispositionAnalytics(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.DispositionAnalytics, error) {
+	query := `
+		WITH disposed AS (
+			SELECT cd.disposition_id, c.created_at, c.first_reply_at, c.resolved_at, c.csat
+			FROM conversation_dispositions cd
+			JOIN conversations c ON c.id = cd.conversation_id
+			WHERE cd.tenant_id = $1
+			  AND cd.disposition_id IS NOT NULL
+			  AND cd.updated_at >= $2
+			  AND cd.updated_at < $3
+		)
+		SELECT
+			d.id,
+			d.code,
+			d.name,
+			d.category,
+			COUNT(x.disposition_id) as conversations,
+			COALESCE(COUNT(x.disposition_id) * 100.0 / NULLIF((SELECT COUNT(*) FROM disposed), 0), 0)::float8 as share,
+			COALESCE(AVG(EXTRACT(EPOCH FROM (x.resolved_at - x.first_reply_at)) / 60)
+				FILTER (WHERE x.resolved_at IS NOT NULL AND x.first_reply_at IS NOT NULL), 0)::float8 as avg_handle_minutes,
+			COALESCE(AVG(EXTRACT(EPOCH FROM (x.resolved_at - x.created_at)) / 60)
+				FILTER (WHERE x.resolved_at IS NOT NULL), 0)::float8 as avg_resolution_minutes,
+			COUNT(x.csat) as csat_responses,
+			COALESCE(AVG(x.csat), 0)::float8 as avg_csat
+		FROM dispositions d
+		LEFT JOIN disposed x ON x.disposition_id = d.id
+		WHERE d.tenant_id = $1
+		  AND (d.active OR x.disposition_id IS NOT NULL)
+		GROUP BY d.id, d.code, d.name, d.category, d.position
+		ORDER BY conversations DESC, d.position, d.name
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, filter.TenantID, filter.StartDate, filter.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []entity.DispositionAnalytics
+	for rows.Next() {
+		var da entity.DispositionAnalytics
+		if err := rows.Scan(&da.DispositionID, &da.Code, &da.Name, &da.Category, &da.Conversations,
+			&da.Share, &da.AvgHandleMinutes, &da.AvgResolutionMinutes, &da.CSATResponses, &da.AvgCSAT); err != nil {
+			return nil, err
+		}
+		result = append(result, da)
+	}
+
+	return result, rows.Err()
+}
+
+// GetReopenAnalytics returns the reopens of conversations resolved in the period.
+// Reopening clears resolved_at, so resolutions are the conversations still resolved
+// plus the reopened resolutions.
+func (r *AnalyticsRepository) GetReopenAnalytics(ctx context.Context, filter entity.AnalyticsFilter) (*entity.ReopenAnalytics, error) {
+	query := `
+		WITH reopens AS (
+			SELECT conversation_id, source, resolved_at, reopened_at
+			FROM conversation_reopens
+			WHERE tenant_id = $1 AND resolved_at >= $2 AND resolved_at < $3
+		)
+		SELECT
+			(SELECT COUNT(*) FROM conversations
+				WHERE tenant_id = $1 AND resolved_at >= $2 AND resolved_at < $3) + (SELECT COUNT(*) FROM reopens) as resolutions,
+			(SELECT COUNT(*) FROM reopens) as reopens,
+			(SELECT COUNT(DISTINCT conversation_id) FROM reopens) as reopened_conversations,
+			(SELECT COUNT(*) FROM reopens WHERE source = 'customer') as customer_reopens,
+			(SELECT COUNT(*) FROM reopens WHERE source = 'agent') as agent_reopens,
+			(SELECT COUNT(*) FROM conversations
+				WHERE tenant_id = $1 AND created_at >= $2 AND created_at < $3
+				  AND COALESCE(metadata->>'follow_up_of', '') <> '') as follow_ups,
+			(SELECT COALESCE(AVG(EXTRACT(EPOCH FROM (reopened_at - resolved_at)) / 3600), 0)::float8 FROM reopens) as avg_hours_to_reopen
+	`
+
+	var ra entity.ReopenAnalytics
+	err := r.db.Pool.QueryRow(ctx, query, filter.TenantID, filter.StartDate, filter.EndDate).Scan(
+		&ra.Resolutions, &ra.Reopens, &ra.ReopenedConversations, &ra.CustomerReopens,
+		&ra.AgentReopens, &ra.FollowUps, &ra.AvgHoursToReopen,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if ra.Resolutions > 0 {
+		ra.ReopenRate = float64(ra.Reopens) * 100 / float64(ra.Resolutions)
+	}
+
+	return &ra, nil
+}
+
+// GetLanguageAnalytics returns conversation metrics grouped by the language routing
+// set or detected on the conversation. Conversations without language are left out.
+func (r *AnalyticsRepository) GetLanguageAnalytics(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.LanguageAnalytics, error) {
+	query := `
+		SELECT
+			LOWER(c.metadata->>'language') as language,
+			COUNT(*) as total,
+			COUNT(*) FILTER (WHERE c.status IN ('open', 'pending')) as open,
+			COUNT(*) FILTER (WHERE c.status IN ('resolved', 'closed')) as resolved,
+			COUNT(*) FILTER (WHERE c.metadata->>'routing_translation' = 'true') as translated,
+			COALESCE(AVG(EXTRACT(EPOCH FROM (c.first_reply_at - c.created_at)) / 60)
+				FILTER (WHERE c.first_reply_at IS NOT NULL), 0) as avg_first_response_minutes,
+			COALESCE(AVG(EXTRACT(EPOCH FROM (c.resolved_at - c.created_at)) / 60)
+				FILTER (WHERE c.resolved_at IS NOT NULL), 0) as avg_resolution_minutes,
+			COUNT(q.score) as reviewed,
+			COALESCE(AVG(q.score), 0) as avg_qa_score
+		FROM conversations c
+		LEFT JOIN LATERAL (
+			SELECT AVG(total_score) as score
+			FROM qa_reviews
+			WHERE conversation_id = c.id::text AND status = 'completed'
+		) q ON true
+		WHERE c.tenant_id = $1
+		  AND c.created_at >= $2
+		  AND c.created_at < $3
+		  AND COALESCE(c.metadata->>'language', '') <> ''
+		GROUP BY language
+		ORDER BY total DESC, language
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, filter.TenantID, filter.StartDate, filter.EndDate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []entity.LanguageAnalytics
+	for rows.Next() {
+		var la entity.LanguageAnalytics
+		if err := rows.Scan(&la.Language, &la.TotalConversations, &la.OpenConversations, &la.ResolvedConversations,
+			&la.TranslatedConversations, &la.AvgFirstResponseMinutes, &la.AvgResolutionMinutes,
+			&la.ReviewedConversations, &la.AvgQAScore); err != nil {
+			return nil, err
+		}
+		if la.TotalConversations > 0 {
+			la.ResolutionRate = float64(la.ResolvedConversations) / float64(la.TotalConversations)
+		}
+		result = append(result, la)
+	}
+
+	return result, rows.Err()
+}
+
+// GetTemplateDeliveryStats returns delivery, read and reply counts per template of a channel.
+// A template message counts as replied when the contact answered within 24 hours.
+func (r *AnalyticsRepository) GetTemplateDeliveryStats(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.TemplateDeliveryStats, error) {
+	query := `
+		SELECT
+			m.metadata->>'template_name' as template_name,
+			COALESCE(m.metadata->>'template_language', '') as language,
+			COUNT(*) as sent,
+			COUNT(*) FILTER (WHERE m.status IN ('delivered', 'read')) as delivered,
+			COUNT(*) FILTER (WHERE m.status = 'read') as read,
+			COUNT(*) FILTER (WHERE m.status = 'failed') as failed,
+			COUNT(*) FILTER (WHERE EXISTS (
+				SELECT 1 FROM messages r
+				WHERE r.conversation_id = m.conversation_id
+				  AND r.sender_type = 'contact'
+				  AND r.created_at > m.created_at
+				  AND r.created_at <= m.created_at + INTERVAL '24 hours'
+			)) as replied
+		FROM messages m
+		JOIN conversations c ON c.id = m.conversation_id
+		WHERE c.tenant_id = $1
+		  AND c.channel_id = $4
+		  AND m.content_type = 'template'
+		  AND m.metadata->>'template_name' IS NOT NULL
+		  AND m.created_at >= $2
+		  AND m.created_at < $3
+		GROUP BY template_name, language
+		ORDER BY sent DESC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, filter.TenantID, filter.StartDate, filter.EndDate, filter.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []entity.TemplateDeliveryStats
+	for rows.Next() {
+		var ts entity.TemplateDeliveryStats
+		if err := rows.Scan(&ts.TemplateName, &ts.Language, &ts.Sent, &ts.Delivered, &ts.Read, &ts.Failed, &ts.Replied); err != nil {
+			return nil, err
+		}
+		result = append(result, ts)
+	}
+
+	return result, rows.Err()
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/msgfy/linktor/internal/domain/entity"
@@ -49,7 +50,7 @@ func (r *APIKeyRepository) Create(ctx context.Context, apiKey *entity.APIKey) er
 func (r *APIKeyRepository) ListByTenant(ctx context.Context, tenantID string) ([]*entity.APIKey, error) {
 	query := `
 		SELECT id, tenant_id, user_id, name, key_hash, key_prefix, scopes,
-		       last_used_at, expires_at, created_at
+		       last_used_at, expires_at, revoked_at, created_at
 		FROM api_keys
 		WHERE tenant_id = $1
 		ORDER BY created_at DESC
@@ -77,6 +78,76 @@ func (r *APIKeyRepository) ListByTenant(ctx context.Context, tenantID string) ([
 	return apiKeys, nil
 }
 
+// FindByPrefix returns the API keys starting with a prefix, including their hashes so a
+// presented key can be verified.
+func (r *APIKeyRepository) FindByPrefix(ctx context.Context, prefix string) ([]*entity.APIKey, error) {
+	query := `
+		SELECT id, tenant_id, user_id, name, key_hash, key_prefix, scopes,
+		       last_used_at, expires_at, revoked_at, created_at
+		FROM api_keys
+		WHERE key_prefix = $1
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, prefix)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find API keys")
+	}
+	defer rows.Close()
+
+	var apiKeys []*entity.APIKey
+	for rows.Next() {
+		apiKey, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan API key")
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate API keys")
+	}
+
+	return apiKeys, nil
+}
+
+// Revoke marks an API key of a tenant as revoked. Revoking a revoked key keeps the
+// original revocation time.
+func (r *APIKeyRepository) Revoke(ctx context.Context, tenantID, id string, revokedAt time.Time) (*entity.APIKey, error) {
+	query := `
+		UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $3)
+		WHERE tenant_id = $1 AND id = $2
+		RETURNING id, tenant_id, user_id, name, key_hash, key_prefix, scopes,
+		          last_used_at, expires_at, revoked_at, created_at
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, tenantID, id, revokedAt)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to revoke API key")
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to revoke API key")
+		}
+		return nil, errors.New(errors.ErrCodeNotFound, "API key not found")
+	}
+	apiKey, err := scanAPIKey(rows)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan API key")
+	}
+	apiKey.KeyHash = ""
+	return apiKey, nil
+}
+
+// TouchLastUsed records when an API key last authenticated a request.
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id string, usedAt time.Time) error {
+	_, err := r.db.Pool.Exec(ctx, "UPDATE api_keys SET last_used_at = $2 WHERE id = $1", id, usedAt)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update API key usage")
+	}
+	return nil
+}
+
 // Delete removes an API key by tenant and ID.
 func (r *APIKeyRepository) Delete(ctx context.Context, tenantID, id string) error {
 	result, err := r.db.Pool.Exec(ctx, "DELETE FROM api_keys WHERE tenant_id = $1 AND id = $2", tenantID, id)
@@ -101,6 +172,7 @@ func scanAPIKey(rows pgx.Rows) (*entity.APIKey, error) {
 		&apiKey.Scopes,
 		&apiKey.LastUsedAt,
 		&apiKey.ExpiresAt,
+		&apiKey.RevokedAt,
 		&apiKey.CreatedAt,
 	)
 	if err != nil {
@@ -0,0 +1,167 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// AutomationTraceRepository implements repository.AutomationTraceRepository with PostgreSQL
+type AutomationTraceRepository struct {
+	db *PostgresDB
+}
+
+// NewAutomationTraceRepository creates a new PostgreSQL automation trace repository
+func NewAutomationTraceRepository(db *PostgresDB) *AutomationTraceRepository {
+	return &AutomationTraceRepository{db: db}
+}
+
+const automationTraceColumns = `
+	id, tenant_id, conversation_id, message_id, source, flow_id, bot_id, steps, variables,
+	intent, sentiment, confidence, model, knowledge_item_ids, decision, escalate_reason, created_at
+`
+
+// Create stores a trace
+func (r *AutomationTraceRepository) Create(ctx context.Context, trace *entity.AutomationTrace) error {
+	steps, err := json.Marshal(trace.Steps)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal trace steps")
+	}
+	variables, err := json.Marshal(trace.Variables)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal trace variables")
+	}
+	itemIDs := trace.KnowledgeItemIDs
+	if itemIDs == nil {
+		itemIDs = []string{}
+	}
+
+	query := `INSERT INTO automation_traces (` + automationTraceColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)`
+	_, err = r.db.Pool.Exec(ctx, query,
+		trace.ID,
+		trace.TenantID,
+		trace.ConversationID,
+		trace.MessageID,
+		string(trace.Source),
+		trace.FlowID,
+		trace.BotID,
+		steps,
+		variables,
+		trace.Intent,
+		trace.Sentiment,
+		trace.Confidence,
+		trace.Model,
+		itemIDs,
+		string(trace.Decision),
+		trace.EscalateReason,
+		trace.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create automation trace")
+	}
+	return nil
+}
+
+// FindByConversation lists the traces of a conversation, newest first
+func (r *AutomationTraceRepository) FindByConversation(ctx context.Context, conversationID string, filter *entity.AutomationTraceFilter, params *repository.ListParams) ([]*entity.AutomationTrace, int64, error) {
+	if params == nil {
+		params = repository.NewListParams()
+	}
+
+	conditions := "conversation_id = $1"
+	args := []interface{}{conversationID}
+	if filter != nil {
+		if filter.MessageID != "" {
+			args = append(args, filter.MessageID)
+			conditions += fmt.Sprintf(" AND message_id = $%d", len(args))
+		}
+		if filter.Before != nil {
+			args = append(args, *filter.Before)
+			conditions += fmt.Sprintf(" AND created_at <= $%d", len(args))
+		}
+	}
+
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM automation_traces WHERE "+conditions, args...).Scan(&total); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count automation traces")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM automation_traces
+		WHERE %s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, automationTraceColumns, conditions, len(args)+1, len(args)+2)
+	args = append(args, params.Limit(), params.Offset())
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to list automation traces")
+	}
+	defer rows.Close()
+
+	var traces []*entity.AutomationTrace
+	for rows.Next() {
+		trace, err := r.scanTrace(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		traces = append(traces, trace)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate automation traces")
+	}
+	return traces, total, nil
+}
+
+func (r *AutomationTraceRepository) scanTrace(row pgx.Row) (*entity.AutomationTrace, error) {
+	var trace entity.AutomationTrace
+	var source, decision string
+	var steps, variables []byte
+
+	err := row.Scan(
+		&trace.ID,
+		&trace.TenantID,
+		&trace.ConversationID,
+		&trace.MessageID,
+		&source,
+		&trace.FlowID,
+		&trace.BotID,
+		&steps,
+		&variables,
+		&trace.Intent,
+		&trace.Sentiment,
+		&trace.Confidence,
+		&trace.Model,
+		&trace.KnowledgeItemIDs,
+		&decision,
+		&trace.EscalateReason,
+		&trace.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "automation trace not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan automation trace")
+	}
+
+	if len(steps) > 0 {
+		if err := json.Unmarshal(steps, &trace.Steps); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal trace steps")
+		}
+	}
+	if len(variables) > 0 {
+		if err := json.Unmarshal(variables, &trace.Variables); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal trace variables")
+		}
+	}
+	trace.Source = entity.AutomationTraceSource(source)
+	trace.Decision = entity.AutomationDecision(decision)
+	return &trace, nil
+}
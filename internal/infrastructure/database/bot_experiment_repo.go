@@ -0,0 +1,386 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// BotExperimentRepository implements repository.BotExperimentRepository with PostgreSQL
+type BotExperimentRepository struct {
+	db *PostgresDB
+}
+
+// NewBotExperimentRepository creates a new PostgreSQL bot experiment repository
+func NewBotExperimentRepository(db *PostgresDB) *BotExperimentRepository {
+	return &BotExperimentRepository{db: db}
+}
+
+const botVersionColumns = `id, tenant_id, bot_id, version, description, provider, model, config, created_by, created_at`
+
+// CreateVersion stores a version with the next version number of the bot, which is set on it
+func (r *BotExperimentRepository) CreateVersion(ctx context.Context, version *entity.BotVersion) error {
+	config, err := json.Marshal(version.Config)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal bot version config")
+	}
+
+	query := `
+		INSERT INTO bot_versions (` + botVersionColumns + `)
+		VALUES ($1, $2, $3, (SELECT COALESCE(MAX(version), 0) + 1 FROM bot_versions WHERE bot_id = $3), $4, $5, $6, $7, $8, $9)
+		RETURNING version
+	`
+	err = r.db.Pool.QueryRow(ctx, query,
+		version.ID,
+		version.TenantID,
+		version.BotID,
+		version.Description,
+		string(version.Provider),
+		version.Model,
+		config,
+		version.CreatedBy,
+		version.CreatedAt,
+	).Scan(&version.Version)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create bot version")
+	}
+	return nil
+}
+
+// FindVersionByID finds a version by ID
+func (r *BotExperimentRepository) FindVersionByID(ctx context.Context, id string) (*entity.BotVersion, error) {
+	query := `SELECT ` + botVersionColumns + ` FROM bot_versions WHERE id = $1`
+	version, err := r.scanVersion(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "bot version not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find bot version")
+	}
+	return version, nil
+}
+
+// ListVersions lists the versions of a bot, newest first
+func (r *BotExperimentRepository) ListVersions(ctx context.Context, botID string) ([]*entity.BotVersion, error) {
+	query := `SELECT ` + botVersionColumns + ` FROM bot_versions WHERE bot_id = $1 ORDER BY version DESC`
+	rows, err := r.db.Pool.Query(ctx, query, botID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list bot versions")
+	}
+	defer rows.Close()
+
+	var versions []*entity.BotVersion
+	for rows.Next() {
+		version, err := r.scanVersion(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan bot version")
+		}
+		versions = append(versions, version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate bot versions")
+	}
+	return versions, nil
+}
+
+func (r *BotExperimentRepository) scanVersion(row pgx.Row) (*entity.BotVersion, error) {
+	var version entity.BotVersion
+	var provider string
+	var config []byte
+	err := row.Scan(
+		&version.ID,
+		&version.TenantID,
+		&version.BotID,
+		&version.Version,
+		&version.Description,
+		&provider,
+		&version.Model,
+		&config,
+		&version.CreatedBy,
+		&version.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	version.Provider = entity.AIProviderType(provider)
+	if len(config) > 0 {
+		if err := json.Unmarshal(config, &version.Config); err != nil {
+			return nil, err
+		}
+	}
+	return &version, nil
+}
+
+const botExperimentColumns = `id, tenant_id, bot_id, name, status, variants, created_by, started_at, ended_at,
+	created_at, updated_at`
+
+// CreateExperiment creates an experiment
+func (r *BotExperimentRepository) CreateExperiment(ctx context.Context, experiment *entity.BotExperiment) error {
+	variants, err := json.Marshal(experiment.Variants)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal experiment variants")
+	}
+
+	query := `
+		INSERT INTO bot_experiments (` + botExperimentColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+	_, err = r.db.Pool.Exec(ctx, query,
+		experiment.ID,
+		experiment.TenantID,
+		experiment.BotID,
+		experiment.Name,
+		string(experiment.Status),
+		variants,
+		experiment.CreatedBy,
+		experiment.StartedAt,
+		experiment.EndedAt,
+		experiment.CreatedAt,
+		experiment.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create bot experiment")
+	}
+	return nil
+}
+
+// UpdateExperiment updates an experiment
+func (r *BotExperimentRepository) UpdateExperiment(ctx context.Context, experiment *entity.BotExperiment) error {
+	variants, err := json.Marshal(experiment.Variants)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal experiment variants")
+	}
+
+	query := `
+		UPDATE bot_experiments SET
+			name = $2, status = $3, variants = $4, ended_at = $5, updated_at = $6
+		WHERE id = $1
+	`
+	result, err := r.db.Pool.Exec(ctx, query,
+		experiment.ID,
+		experiment.Name,
+		string(experiment.Status),
+		variants,
+		experiment.EndedAt,
+		experiment.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update bot experiment")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "bot experiment not found")
+	}
+	return nil
+}
+
+// FindExperimentByID finds an experiment by ID
+func (r *BotExperimentRepository) FindExperimentByID(ctx context.Context, id string) (*entity.BotExperiment, error) {
+	query := `SELECT ` + botExperimentColumns + ` FROM bot_experiments WHERE id = $1`
+	experiment, err := r.scanExperiment(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "bot experiment not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find bot experiment")
+	}
+	return experiment, nil
+}
+
+// FindRunningExperiment finds the running experiment of a bot
+func (r *BotExperimentRepository) FindRunningExperiment(ctx context.Context, botID string) (*entity.BotExperiment, error) {
+	query := `SELECT ` + botExperimentColumns + ` FROM bot_experiments WHERE bot_id = $1 AND status = $2 LIMIT 1`
+	experiment, err := r.scanExperiment(r.db.Pool.QueryRow(ctx, query, botID, string(entity.BotExperimentStatusRunning)))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "no running bot experiment")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find running bot experiment")
+	}
+	return experiment, nil
+}
+
+// ListExperiments lists the experiments of a bot, newest first
+func (r *BotExperimentRepository) ListExperiments(ctx context.Context, botID string) ([]*entity.BotExperiment, error) {
+	query := `SELECT ` + botExperimentColumns + ` FROM bot_experiments WHERE bot_id = $1 ORDER BY started_at DESC`
+	rows, err := r.db.Pool.Query(ctx, query, botID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list bot experiments")
+	}
+	defer rows.Close()
+
+	var experiments []*entity.BotExperiment
+	for rows.Next() {
+		experiment, err := r.scanExperiment(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan bot experiment")
+		}
+		experiments = append(experiments, experiment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate bot experiments")
+	}
+	return experiments, nil
+}
+
+func (r *BotExperimentRepository) scanExperiment(row pgx.Row) (*entity.BotExperiment, error) {
+	var experiment entity.BotExperiment
+	var status string
+	var variants []byte
+	err := row.Scan(
+		&experiment.ID,
+		&experiment.TenantID,
+		&experiment.BotID,
+		&experiment.Name,
+		&status,
+		&variants,
+		&experiment.CreatedBy,
+		&experiment.StartedAt,
+		&experiment.EndedAt,
+		&experiment.CreatedAt,
+		&experiment.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	experiment.Status = entity.BotExperimentStatus(status)
+	if len(variants) > 0 {
+		if err := json.Unmarshal(variants, &experiment.Variants); err != nil {
+			return nil, err
+		}
+	}
+	return &experiment, nil
+}
+
+// FindAssignment finds the assignment of a conversation in an experiment
+func (r *BotExperimentRepository) FindAssignment(ctx context.Context, experimentID, conversationID string) (*entity.BotExperimentAssignment, error) {
+	query := `
+		SELECT experiment_id, tenant_id, conversation_id, version_id, escalated, resolved, csat, assigned_at
+		FROM bot_experiment_assignments
+		WHERE experiment_id = $1 AND conversation_id = $2
+	`
+	var assignment entity.BotExperimentAssignment
+	err := r.db.Pool.QueryRow(ctx, query, experimentID, conversationID).Scan(
+		&assignment.ExperimentID,
+		&assignment.TenantID,
+		&assignment.ConversationID,
+		&assignment.VersionID,
+		&assignment.Escalated,
+		&assignment.Resolved,
+		&assignment.CSAT,
+		&assignment.AssignedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "bot experiment assignment not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find bot experiment assignment")
+	}
+	return &assignment, nil
+}
+
+// CreateAssignment assigns a conversation unless it already is; the stored assignment is returned
+func (r *BotExperimentRepository) CreateAssignment(ctx context.Context, assignment *entity.BotExperimentAssignment) (*entity.BotExperimentAssignment, error) {
+	query := `
+		INSERT INTO bot_experiment_assignments (experiment_id, tenant_id, conversation_id, version_id, assigned_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (experiment_id, conversation_id) DO NOTHING
+	`
+	_, err := r.db.Pool.Exec(ctx, query,
+		assignment.ExperimentID,
+		assignment.TenantID,
+		assignment.ConversationID,
+		assignment.VersionID,
+		assignment.AssignedAt,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to create bot experiment assignment")
+	}
+	return r.FindAssignment(ctx, assignment.ExperimentID, assignment.ConversationID)
+}
+
+// MarkEscalated flags the assignments of a conversation as escalated
+func (r *BotExperimentRepository) MarkEscalated(ctx context.Context, conversationID string) error {
+	_, err := r.db.Pool.Exec(ctx, `UPDATE bot_experiment_assignments SET escalated = TRUE WHERE conversation_id = $1`, conversationID)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to mark bot experiment escalation")
+	}
+	return nil
+}
+
+// MarkResolved flags the assignments of a conversation as resolved
+func (r *BotExperimentRepository) MarkResolved(ctx context.Context, conversationID string) error {
+	_, err := r.db.Pool.Exec(ctx, `UPDATE bot_experiment_assignments SET resolved = TRUE WHERE conversation_id = $1`, conversationID)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to mark bot experiment resolution")
+	}
+	return nil
+}
+
+// SetCSAT stores the satisfaction score of a conversation on the conversation, for
+// disposition analytics, and on its bot experiment assignments if any
+func (r *BotExperimentRepository) SetCSAT(ctx context.Context, tenantID, conversationID string, score int) error {
+	result, err := r.db.Pool.Exec(ctx,
+		`UPDATE conversations SET csat = $3 WHERE tenant_id = $1 AND id = $2`,
+		tenantID, conversationID, score,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to record CSAT")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "conversation not found")
+	}
+
+	_, err = r.db.Pool.Exec(ctx,
+		`UPDATE bot_experiment_assignments SET csat = $3 WHERE tenant_id = $1 AND conversation_id = $2`,
+		tenantID, conversationID, score,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to record CSAT")
+	}
+	return nil
+}
+
+// VariantOutcomes aggregates the outcomes of the assignments of an experiment per version
+func (r *BotExperimentRepository) VariantOutcomes(ctx context.Context, experimentID string) ([]*entity.BotExperimentVariantReport, error) {
+	query := `
+		SELECT version_id,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE escalated),
+			COUNT(*) FILTER (WHERE resolved),
+			COUNT(csat),
+			COALESCE(AVG(csat), 0)::float8
+		FROM bot_experiment_assignments
+		WHERE experiment_id = $1
+		GROUP BY version_id
+	`
+	rows, err := r.db.Pool.Query(ctx, query, experimentID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to aggregate bot experiment outcomes")
+	}
+	defer rows.Close()
+
+	var outcomes []*entity.BotExperimentVariantReport
+	for rows.Next() {
+		var outcome entity.BotExperimentVariantReport
+		if err := rows.Scan(
+			&outcome.VersionID,
+			&outcome.Conversations,
+			&outcome.Escalations,
+			&outcome.Resolutions,
+			&outcome.CSATResponses,
+			&outcome.AverageCSAT,
+		); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan bot experiment outcome")
+		}
+		outcomes = append(outcomes, &outcome)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate bot experiment outcomes")
+	}
+	return outcomes, nil
+}
@@ -0,0 +1,280 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// BotToolRepository implements repository.BotToolRepository with PostgreSQL
+type BotToolRepository struct {
+	db *PostgresDB
+}
+
+// NewBotToolRepository creates a new PostgreSQL bot tool repository
+func NewBotToolRepository(db *PostgresDB) *BotToolRepository {
+	return &BotToolRepository{db: db}
+}
+
+const botToolColumns = `id, tenant_id, bot_id, name, description, parameters, method, url, headers,
+	timeout_seconds, enabled, created_at, updated_at`
+
+// Create creates a tool
+func (r *BotToolRepository) Create(ctx context.Context, tool *entity.BotTool) error {
+	parameters, headers, err := marshalBotTool(tool)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO bot_tools (` + botToolColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+	_, err = r.db.Pool.Exec(ctx, query,
+		tool.ID,
+		tool.TenantID,
+		tool.BotID,
+		tool.Name,
+		tool.Description,
+		parameters,
+		tool.Method,
+		tool.URL,
+		headers,
+		tool.TimeoutSeconds,
+		tool.Enabled,
+		tool.CreatedAt,
+		tool.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create bot tool")
+	}
+	return nil
+}
+
+// Update updates a tool
+func (r *BotToolRepository) Update(ctx context.Context, tool *entity.BotTool) error {
+	parameters, headers, err := marshalBotTool(tool)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE bot_tools SET
+			name = $2, description = $3, parameters = $4, method = $5, url = $6, headers = $7,
+			timeout_seconds = $8, enabled = $9, updated_at = $10
+		WHERE id = $1
+	`
+	result, err := r.db.Pool.Exec(ctx, query,
+		tool.ID,
+		tool.Name,
+		tool.Description,
+		parameters,
+		tool.Method,
+		tool.URL,
+		headers,
+		tool.TimeoutSeconds,
+		tool.Enabled,
+		tool.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update bot tool")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "bot tool not found")
+	}
+	return nil
+}
+
+// Delete deletes a tool
+func (r *BotToolRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.Pool.Exec(ctx, `DELETE FROM bot_tools WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete bot tool")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "bot tool not found")
+	}
+	return nil
+}
+
+// FindByID finds a tool by ID
+func (r *BotToolRepository) FindByID(ctx context.Context, id string) (*entity.BotTool, error) {
+	query := `SELECT ` + botToolColumns + ` FROM bot_tools WHERE id = $1`
+	tool, err := r.scanTool(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "bot tool not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find bot tool")
+	}
+	return tool, nil
+}
+
+// ListByBot lists the tools of a bot by name
+func (r *BotToolRepository) ListByBot(ctx context.Context, botID string) ([]*entity.BotTool, error) {
+	query := `SELECT ` + botToolColumns + ` FROM bot_tools WHERE bot_id = $1 ORDER BY name`
+	rows, err := r.db.Pool.Query(ctx, query, botID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list bot tools")
+	}
+	defer rows.Close()
+
+	var tools []*entity.BotTool
+	for rows.Next() {
+		tool, err := r.scanTool(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan bot tool")
+		}
+		tools = append(tools, tool)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate bot tools")
+	}
+	return tools, nil
+}
+
+func (r *BotToolRepository) scanTool(row pgx.Row) (*entity.BotTool, error) {
+	var tool entity.BotTool
+	var parameters, headers []byte
+	err := row.Scan(
+		&tool.ID,
+		&tool.TenantID,
+		&tool.BotID,
+		&tool.Name,
+		&tool.Description,
+		&parameters,
+		&tool.Method,
+		&tool.URL,
+		&headers,
+		&tool.TimeoutSeconds,
+		&tool.Enabled,
+		&tool.CreatedAt,
+		&tool.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(parameters) > 0 {
+		if err := json.Unmarshal(parameters, &tool.Parameters); err != nil {
+			return nil, err
+		}
+	}
+	if len(headers) > 0 {
+		if err := json.Unmarshal(headers, &tool.Headers); err != nil {
+			return nil, err
+		}
+	}
+	return &tool, nil
+}
+
+func marshalBotTool(tool *entity.BotTool) ([]byte, []byte, error) {
+	parameters, err := json.Marshal(tool.Parameters)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal tool parameters")
+	}
+	headers, err := json.Marshal(tool.Headers)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal tool headers")
+	}
+	return parameters, headers, nil
+}
+
+// CreateExecution records a tool call
+func (r *BotToolRepository) CreateExecution(ctx context.Context, execution *entity.ToolExecution) error {
+	arguments, err := json.Marshal(execution.Arguments)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal tool arguments")
+	}
+
+	query := `
+		INSERT INTO bot_tool_executions (
+			id, tenant_id, bot_id, tool_id, tool_name, conversation_id, message_id, tool_call_id,
+			arguments, status, status_code, result, error, duration_ms, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	`
+	_, err = r.db.Pool.Exec(ctx, query,
+		execution.ID,
+		execution.TenantID,
+		execution.BotID,
+		execution.ToolID,
+		execution.ToolName,
+		execution.ConversationID,
+		execution.MessageID,
+		execution.ToolCallID,
+		arguments,
+		string(execution.Status),
+		execution.StatusCode,
+		execution.Result,
+		execution.Error,
+		execution.DurationMs,
+		execution.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to record tool execution")
+	}
+	return nil
+}
+
+// ListExecutions lists the tool calls of a bot, newest first
+func (r *BotToolRepository) ListExecutions(ctx context.Context, botID string, params *repository.ListParams) ([]*entity.ToolExecution, int64, error) {
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM bot_tool_executions WHERE bot_id = $1`, botID).Scan(&total); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count tool executions")
+	}
+
+	query := `
+		SELECT id, tenant_id, bot_id, tool_id, tool_name, conversation_id, message_id, tool_call_id,
+			arguments, status, status_code, result, error, duration_ms, created_at
+		FROM bot_tool_executions
+		WHERE bot_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Pool.Query(ctx, query, botID, params.Limit(), params.Offset())
+	if err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to list tool executions")
+	}
+	defer rows.Close()
+
+	var executions []*entity.ToolExecution
+	for rows.Next() {
+		var execution entity.ToolExecution
+		var arguments []byte
+		var status string
+		if err := rows.Scan(
+			&execution.ID,
+			&execution.TenantID,
+			&execution.BotID,
+			&execution.ToolID,
+			&execution.ToolName,
+			&execution.ConversationID,
+			&execution.MessageID,
+			&execution.ToolCallID,
+			&arguments,
+			&status,
+			&execution.StatusCode,
+			&execution.Result,
+			&execution.Error,
+			&execution.DurationMs,
+			&execution.CreatedAt,
+		); err != nil {
+			return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan tool execution")
+		}
+		if len(arguments) > 0 {
+			if err := json.Unmarshal(arguments, &execution.Arguments); err != nil {
+				return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal tool arguments")
+			}
+		}
+		execution.Status = entity.ToolExecutionStatus(status)
+		executions = append(executions, &execution)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate tool executions")
+	}
+	return executions, total, nil
+}
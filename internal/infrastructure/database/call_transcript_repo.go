@@ -0,0 +1,170 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// CallTranscriptRepository implements repository.CallTranscriptRepository with PostgreSQL
+type CallTranscriptRepository struct {
+	db *PostgresDB
+}
+
+// NewCallTranscriptRepository creates a new PostgreSQL call transcript repository
+func NewCallTranscriptRepository(db *PostgresDB) *CallTranscriptRepository {
+	return &CallTranscriptRepository{db: db}
+}
+
+// callTranscriptColumns selects a transcript with the conversation its message was
+// posted to. The message carries the transcript ID as its external ID.
+const callTranscriptColumns = `
+	t.id, t.tenant_id, t.channel_id, COALESCE(m.conversation_id::text, ''), t.call_id,
+	t.direction, t.from_number, t.to_number, t.language, t.provider, t.text, t.duration,
+	t.recording_url, t.created_at
+`
+
+const callTranscriptFrom = `
+	FROM call_transcripts t
+	LEFT JOIN LATERAL (
+		SELECT conversation_id FROM messages WHERE external_id = t.id::text LIMIT 1
+	) m ON TRUE
+`
+
+func scanCallTranscript(row pgx.Row, extra ...interface{}) (*entity.CallTranscript, error) {
+	var transcript entity.CallTranscript
+	dest := []interface{}{
+		&transcript.ID,
+		&transcript.TenantID,
+		&transcript.ChannelID,
+		&transcript.ConversationID,
+		&transcript.CallID,
+		&transcript.Direction,
+		&transcript.From,
+		&transcript.To,
+		&transcript.Language,
+		&transcript.Provider,
+		&transcript.Text,
+		&transcript.Duration,
+		&transcript.RecordingURL,
+		&transcript.CreatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return &transcript, nil
+}
+
+// Create creates a call transcript
+func (r *CallTranscriptRepository) Create(ctx context.Context, transcript *entity.CallTranscript) error {
+	query := `
+		INSERT INTO call_transcripts (
+			id, tenant_id, channel_id, call_id, direction, from_number, to_number,
+			language, provider, text, duration, recording_url, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+	_, err := r.db.Pool.Exec(ctx, query,
+		transcript.ID,
+		transcript.TenantID,
+		transcript.ChannelID,
+		transcript.CallID,
+		transcript.Direction,
+		transcript.From,
+		transcript.To,
+		transcript.Language,
+		transcript.Provider,
+		transcript.Text,
+		transcript.Duration,
+		transcript.RecordingURL,
+		transcript.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create call transcript")
+	}
+	return nil
+}
+
+// FindByID finds a call transcript by ID
+func (r *CallTranscriptRepository) FindByID(ctx context.Context, id string) (*entity.CallTranscript, error) {
+	query := `SELECT ` + callTranscriptColumns + callTranscriptFrom + ` WHERE t.id = $1`
+	transcript, err := scanCallTranscript(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "call transcript not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find call transcript")
+	}
+	return transcript, nil
+}
+
+// Search lists the transcripts of a tenant matching a filter. Queries match whole
+// words anywhere in the transcript, ranked by relevance, with the matching passage
+// as snippet.
+func (r *CallTranscriptRepository) Search(ctx context.Context, tenantID string, filter *entity.CallTranscriptFilter, params *repository.ListParams) ([]*entity.CallTranscript, int64, error) {
+	if params == nil {
+		params = repository.NewListParams()
+	}
+	if filter == nil {
+		filter = &entity.CallTranscriptFilter{}
+	}
+
+	conditions := "t.tenant_id = $1"
+	args := []interface{}{tenantID}
+	if filter.ChannelID != "" {
+		args = append(args, filter.ChannelID)
+		conditions += fmt.Sprintf(" AND t.channel_id = $%d", len(args))
+	}
+	if filter.CallID != "" {
+		args = append(args, filter.CallID)
+		conditions += fmt.Sprintf(" AND t.call_id = $%d", len(args))
+	}
+	if filter.Phone != "" {
+		args = append(args, filter.Phone)
+		conditions += fmt.Sprintf(" AND (t.from_number = $%d OR t.to_number = $%d)", len(args), len(args))
+	}
+
+	orderBy := "t.created_at DESC"
+	snippet := "''"
+	if filter.Query != "" {
+		args = append(args, filter.Query)
+		tsquery := fmt.Sprintf("plainto_tsquery('simple', $%d)", len(args))
+		conditions += " AND t.search_vector @@ " + tsquery
+		orderBy = "ts_rank(t.search_vector, " + tsquery + ") DESC, t.created_at DESC"
+		snippet = "ts_headline('simple', t.text, " + tsquery + ", 'MaxWords=30, MinWords=10, StartSel=**, StopSel=**')"
+	}
+
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM call_transcripts t WHERE "+conditions, args...).Scan(&total); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count call transcripts")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s, %s %s
+		WHERE %s
+		ORDER BY %s
+		LIMIT $%d OFFSET $%d
+	`, callTranscriptColumns, snippet, callTranscriptFrom, conditions, orderBy, len(args)+1, len(args)+2)
+	args = append(args, params.Limit(), params.Offset())
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to search call transcripts")
+	}
+	defer rows.Close()
+
+	transcripts := make([]*entity.CallTranscript, 0)
+	for rows.Next() {
+		var snippet string
+		transcript, err := scanCallTranscript(rows, &snippet)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan call transcript")
+		}
+		transcript.Snippet = snippet
+		transcripts = append(transcripts, transcript)
+	}
+	return transcripts, total, rows.Err()
+}
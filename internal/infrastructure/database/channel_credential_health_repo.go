@@ -0,0 +1,112 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// ChannelCredentialHealthRepository implements repository.ChannelCredentialHealthRepository with PostgreSQL
+type ChannelCredentialHealthRepository struct {
+	db *PostgresDB
+}
+
+// NewChannelCredentialHealthRepository creates a new PostgreSQL channel credential health repository
+func NewChannelCredentialHealthRepository(db *PostgresDB) *ChannelCredentialHealthRepository {
+	return &ChannelCredentialHealthRepository{db: db}
+}
+
+const channelCredentialHealthColumns = `
+	channel_id, tenant_id, status, account, expires_at, last_error, failures, failing_since,
+	checked_at, updated_at
+`
+
+// Upsert stores the latest check of a channel, replacing the previous one
+func (r *ChannelCredentialHealthRepository) Upsert(ctx context.Context, health *entity.ChannelCredentialHealth) error {
+	query := `INSERT INTO channel_credential_health (` + channelCredentialHealthColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (channel_id) DO UPDATE SET
+			status = EXCLUDED.status,
+			account = EXCLUDED.account,
+			expires_at = EXCLUDED.expires_at,
+			last_error = EXCLUDED.last_error,
+			failures = EXCLUDED.failures,
+			failing_since = EXCLUDED.failing_since,
+			checked_at = EXCLUDED.checked_at,
+			updated_at = EXCLUDED.updated_at`
+	_, err := r.db.Pool.Exec(ctx, query,
+		health.ChannelID,
+		health.TenantID,
+		string(health.Status),
+		health.Account,
+		health.ExpiresAt,
+		health.LastError,
+		health.Failures,
+		health.FailingSince,
+		health.CheckedAt,
+		health.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to save channel credential health")
+	}
+	return nil
+}
+
+// FindByChannel finds the latest check of a channel
+func (r *ChannelCredentialHealthRepository) FindByChannel(ctx context.Context, channelID string) (*entity.ChannelCredentialHealth, error) {
+	query := `SELECT ` + channelCredentialHealthColumns + ` FROM channel_credential_health WHERE channel_id = $1`
+	return r.scanHealth(r.db.Pool.QueryRow(ctx, query, channelID))
+}
+
+// FindByTenant lists the latest checks of the channels of a tenant
+func (r *ChannelCredentialHealthRepository) FindByTenant(ctx context.Context, tenantID string) ([]*entity.ChannelCredentialHealth, error) {
+	query := `SELECT ` + channelCredentialHealthColumns + `
+		FROM channel_credential_health
+		WHERE tenant_id = $1
+		ORDER BY checked_at DESC`
+	rows, err := r.db.Pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list channel credential health")
+	}
+	defer rows.Close()
+
+	checks := make([]*entity.ChannelCredentialHealth, 0)
+	for rows.Next() {
+		health, err := r.scanHealth(rows)
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, health)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate channel credential health")
+	}
+	return checks, nil
+}
+
+func (r *ChannelCredentialHealthRepository) scanHealth(row pgx.Row) (*entity.ChannelCredentialHealth, error) {
+	var health entity.ChannelCredentialHealth
+	var status string
+	err := row.Scan(
+		&health.ChannelID,
+		&health.TenantID,
+		&status,
+		&health.Account,
+		&health.ExpiresAt,
+		&health.LastError,
+		&health.Failures,
+		&health.FailingSince,
+		&health.CheckedAt,
+		&health.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "channel credentials not checked yet")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan channel credential health")
+	}
+	health.Status = entity.ChannelCredentialStatus(status)
+	return &health, nil
+}
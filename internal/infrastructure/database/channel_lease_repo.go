@@ -0,0 +1,149 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// ChannelLeaseRepository implements repository.ChannelLeaseRepository with PostgreSQL
+type ChannelLeaseRepository struct {
+	db *PostgresDB
+}
+
+// NewChannelLeaseRepository creates a new PostgreSQL channel lease repository
+func NewChannelLeaseRepository(db *PostgresDB) *ChannelLeaseRepository {
+	return &ChannelLeaseRepository{db: db}
+}
+
+const channelLeaseColumns = `channel_id, tenant_id, owner_id, acquired_at, renewed_at, expires_at`
+
+const channelFailoverEventColumns = `
+	id, tenant_id, channel_id, type, owner_id, previous_owner_id, reason, created_at
+`
+
+// Acquire claims or renews the channel's lease. Expiry is computed with the database
+// clock so instances with skewed clocks agree on when a lease lapses.
+func (r *ChannelLeaseRepository) Acquire(ctx context.Context, tenantID, channelID, ownerID string, ttl time.Duration) (*entity.ChannelLease, string, error) {
+	query := `
+		WITH previous AS (SELECT owner_id FROM channel_leases WHERE channel_id = $1)
+		INSERT INTO channel_leases (` + channelLeaseColumns + `)
+		VALUES ($1, $2, $3, NOW(), NOW(), NOW() + make_interval(secs => $4))
+		ON CONFLICT (channel_id) DO UPDATE SET
+			owner_id = EXCLUDED.owner_id,
+			acquired_at = CASE WHEN channel_leases.owner_id = EXCLUDED.owner_id
+				THEN channel_leases.acquired_at ELSE EXCLUDED.acquired_at END,
+			renewed_at = EXCLUDED.renewed_at,
+			expires_at = EXCLUDED.expires_at
+		WHERE channel_leases.owner_id = EXCLUDED.owner_id OR channel_leases.expires_at < NOW()
+		RETURNING ` + channelLeaseColumns + `, COALESCE((SELECT owner_id FROM previous), '')
+	`
+
+	var lease entity.ChannelLease
+	var previousOwnerID string
+	err := r.db.Pool.QueryRow(ctx, query, channelID, tenantID, ownerID, ttl.Seconds()).Scan(
+		&lease.ChannelID,
+		&lease.TenantID,
+		&lease.OwnerID,
+		&lease.AcquiredAt,
+		&lease.RenewedAt,
+		&lease.ExpiresAt,
+		&previousOwnerID,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", errors.Wrap(err, errors.ErrCodeInternal, "failed to acquire channel lease")
+	}
+	return &lease, previousOwnerID, nil
+}
+
+// Release deletes the lease if ownerID holds it
+func (r *ChannelLeaseRepository) Release(ctx context.Context, channelID, ownerID string) error {
+	if _, err := r.db.Pool.Exec(ctx, `DELETE FROM channel_leases WHERE channel_id = $1 AND owner_id = $2`, channelID, ownerID); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to release channel lease")
+	}
+	return nil
+}
+
+// FindByChannel returns the channel's lease
+func (r *ChannelLeaseRepository) FindByChannel(ctx context.Context, channelID string) (*entity.ChannelLease, error) {
+	query := `SELECT ` + channelLeaseColumns + ` FROM channel_leases WHERE channel_id = $1`
+
+	var lease entity.ChannelLease
+	err := r.db.Pool.QueryRow(ctx, query, channelID).Scan(
+		&lease.ChannelID,
+		&lease.TenantID,
+		&lease.OwnerID,
+		&lease.AcquiredAt,
+		&lease.RenewedAt,
+		&lease.ExpiresAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, errors.New(errors.ErrCodeNotFound, "channel lease not found")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find channel lease")
+	}
+	return &lease, nil
+}
+
+// CreateEvent records an ownership change
+func (r *ChannelLeaseRepository) CreateEvent(ctx context.Context, event *entity.ChannelFailoverEvent) error {
+	query := `INSERT INTO channel_failover_events (` + channelFailoverEventColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	if _, err := r.db.Pool.Exec(ctx, query,
+		event.ID,
+		event.TenantID,
+		event.ChannelID,
+		string(event.Type),
+		event.OwnerID,
+		event.PreviousOwnerID,
+		event.Reason,
+		event.CreatedAt,
+	); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to record channel failover event")
+	}
+	return nil
+}
+
+// ListEvents lists the channel's ownership changes, newest first
+func (r *ChannelLeaseRepository) ListEvents(ctx context.Context, channelID string, limit int) ([]*entity.ChannelFailoverEvent, error) {
+	query := `SELECT ` + channelFailoverEventColumns + ` FROM channel_failover_events
+		WHERE channel_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2`
+	rows, err := r.db.Pool.Query(ctx, query, channelID, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list channel failover events")
+	}
+	defer rows.Close()
+
+	var events []*entity.ChannelFailoverEvent
+	for rows.Next() {
+		var event entity.ChannelFailoverEvent
+		var eventType string
+		if err := rows.Scan(
+			&event.ID,
+			&event.TenantID,
+			&event.ChannelID,
+			&eventType,
+			&event.OwnerID,
+			&event.PreviousOwnerID,
+			&event.Reason,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan channel failover event")
+		}
+		event.Type = entity.ChannelFailoverEventType(eventType)
+		events = append(events, &event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate channel failover events")
+	}
+	return events, nil
+}
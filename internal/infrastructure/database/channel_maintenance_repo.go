@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// ChannelMaintenanceRepository implements repository.ChannelMaintenanceRepository with PostgreSQL
+type ChannelMaintenanceRepository struct {
+	db *PostgresDB
+}
+
+// NewChannelMaintenanceRepository creates a new PostgreSQL channel maintenance repository
+func NewChannelMaintenanceRepository(db *PostgresDB) *ChannelMaintenanceRepository {
+	return &ChannelMaintenanceRepository{db: db}
+}
+
+const channelMaintenanceColumns = `id, tenant_id, channel_id, title, notice, starts_at, ends_at, created_by, created_at, updated_at`
+
+// Create creates a new maintenance window
+func (r *ChannelMaintenanceRepository) Create(ctx context.Context, window *entity.ChannelMaintenanceWindow) error {
+	query := `INSERT INTO channel_maintenance_windows (` + channelMaintenanceColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+	_, err := r.db.Pool.Exec(ctx, query,
+		window.ID,
+		window.TenantID,
+		window.ChannelID,
+		window.Title,
+		window.Notice,
+		window.StartsAt,
+		window.EndsAt,
+		window.CreatedBy,
+		window.CreatedAt,
+		window.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create maintenance window")
+	}
+	return nil
+}
+
+// FindByID finds a maintenance window by ID
+func (r *ChannelMaintenanceRepository) FindByID(ctx context.Context, id string) (*entity.ChannelMaintenanceWindow, error) {
+	query := `SELECT ` + channelMaintenanceColumns + ` FROM channel_maintenance_windows WHERE id = $1`
+	return r.scan(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// ListByChannel lists the windows of a channel ending after since, by start time
+func (r *ChannelMaintenanceRepository) ListByChannel(ctx context.Context, channelID string, since time.Time) ([]*entity.ChannelMaintenanceWindow, error) {
+	query := `SELECT ` + channelMaintenanceColumns + ` FROM channel_maintenance_windows
+		WHERE channel_id = $1 AND ends_at > $2 ORDER BY starts_at`
+	return r.list(ctx, query, channelID, since)
+}
+
+// ListOverlapping lists the windows of a channel overlapping the period from..to
+func (r *ChannelMaintenanceRepository) ListOverlapping(ctx context.Context, channelID string, from, to time.Time) ([]*entity.ChannelMaintenanceWindow, error) {
+	query := `SELECT ` + channelMaintenanceColumns + ` FROM channel_maintenance_windows
+		WHERE channel_id = $1 AND ends_at > $2 AND starts_at <= $3 ORDER BY starts_at`
+	return r.list(ctx, query, channelID, from, to)
+}
+
+// Update updates a maintenance window
+func (r *ChannelMaintenanceRepository) Update(ctx context.Context, window *entity.ChannelMaintenanceWindow) error {
+	query := `
+		UPDATE channel_maintenance_windows
+		SET title = $2, notice = $3, starts_at = $4, ends_at = $5, updated_at = $6
+		WHERE id = $1
+	`
+	result, err := r.db.Pool.Exec(ctx, query,
+		window.ID,
+		window.Title,
+		window.Notice,
+		window.StartsAt,
+		window.EndsAt,
+		window.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update maintenance window")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "maintenance window not found")
+	}
+	return nil
+}
+
+// Delete deletes a maintenance window
+func (r *ChannelMaintenanceRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.Pool.Exec(ctx, `DELETE FROM channel_maintenance_windows WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete maintenance window")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "maintenance window not found")
+	}
+	return nil
+}
+
+func (r *ChannelMaintenanceRepository) list(ctx context.Context, query string, args ...interface{}) ([]*entity.ChannelMaintenanceWindow, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list maintenance windows")
+	}
+	defer rows.Close()
+
+	var windows []*entity.ChannelMaintenanceWindow
+	for rows.Next() {
+		window, err := r.scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, window)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate maintenance windows")
+	}
+	return windows, nil
+}
+
+func (r *ChannelMaintenanceRepository) scan(row pgx.Row) (*entity.ChannelMaintenanceWindow, error) {
+	var window entity.ChannelMaintenanceWindow
+	err := row.Scan(
+		&window.ID,
+		&window.TenantID,
+		&window.ChannelID,
+		&window.Title,
+		&window.Notice,
+		&window.StartsAt,
+		&window.EndsAt,
+		&window.CreatedBy,
+		&window.CreatedAt,
+		&window.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "maintenance window not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan maintenance window")
+	}
+	return &window, nil
+}
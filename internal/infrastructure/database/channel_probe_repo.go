@@ -0,0 +1,86 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// ChannelProbeRepository implements repository.ChannelProbeRepository with PostgreSQL
+type ChannelProbeRepository struct {
+	db *PostgresDB
+}
+
+// NewChannelProbeRepository creates a new PostgreSQL channel probe repository
+func NewChannelProbeRepository(db *PostgresDB) *ChannelProbeRepository {
+	return &ChannelProbeRepository{db: db}
+}
+
+// Upsert stores the probe of a channel, replacing the previous one
+func (r *ChannelProbeRepository) Upsert(ctx context.Context, probe *entity.ChannelProbe) error {
+	checks, err := json.Marshal(probe.Checks)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal probe checks")
+	}
+
+	query := `
+		INSERT INTO channel_probes (channel_id, tenant_id, status, checks, triggered_by, probed_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (channel_id) DO UPDATE SET
+			status = EXCLUDED.status,
+			checks = EXCLUDED.checks,
+			triggered_by = EXCLUDED.triggered_by,
+			probed_at = EXCLUDED.probed_at
+	`
+	_, err = r.db.Pool.Exec(ctx, query,
+		probe.ChannelID,
+		probe.TenantID,
+		string(probe.Status),
+		checks,
+		string(probe.Trigger),
+		probe.ProbedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to save channel probe")
+	}
+	return nil
+}
+
+// FindByChannel finds the latest probe of a channel
+func (r *ChannelProbeRepository) FindByChannel(ctx context.Context, channelID string) (*entity.ChannelProbe, error) {
+	query := `
+		SELECT channel_id, tenant_id, status, checks, triggered_by, probed_at
+		FROM channel_probes
+		WHERE channel_id = $1
+	`
+
+	var probe entity.ChannelProbe
+	var status, trigger string
+	var checks []byte
+	err := r.db.Pool.QueryRow(ctx, query, channelID).Scan(
+		&probe.ChannelID,
+		&probe.TenantID,
+		&status,
+		&checks,
+		&trigger,
+		&probe.ProbedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "channel probe not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find channel probe")
+	}
+
+	if len(checks) > 0 {
+		if err := json.Unmarshal(checks, &probe.Checks); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal probe checks")
+		}
+	}
+	probe.Status = entity.ChannelProbeStatus(status)
+	probe.Trigger = entity.ChannelProbeTrigger(trigger)
+	return &probe, nil
+}
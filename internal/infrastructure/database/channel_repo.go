@@ -10,12 +10,16 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/msgfy/linktor/internal/domain/entity"
 	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/secrets"
 	"github.com/msgfy/linktor/pkg/errors"
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
 )
 
 // ChannelRepository implements repository.ChannelRepository with PostgreSQL
 type ChannelRepository struct {
-	db *PostgresDB
+	db      *PostgresDB
+	secrets *secrets.Resolver
 }
 
 // NewChannelRepository creates a new PostgreSQL channel repository
@@ -23,9 +27,15 @@ func NewChannelRepository(db *PostgresDB) *ChannelRepository {
 	return &ChannelRepository{db: db}
 }
 
+// SetSecretResolver resolves credentials stored as secret references when
+// channels are loaded. They are persisted as references again on save.
+func (r *ChannelRepository) SetSecretResolver(resolver *secrets.Resolver) {
+	r.secrets = resolver
+}
+
 // Create creates a new channel
 func (r *ChannelRepository) Create(ctx context.Context, channel *entity.Channel) error {
-	credentials, err := json.Marshal(channel.Credentials)
+	credentials, err := json.Marshal(channel.StoredCredentials())
 	if err != nil {
 		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal credentials")
 	}
@@ -78,7 +88,7 @@ func (r *ChannelRepository) FindByID(ctx context.Context, id string) (*entity.Ch
 		WHERE id = $1
 	`
 
-	channel, err := r.scanChannel(r.db.Pool.QueryRow(ctx, query, id))
+	channel, err := r.scanChannel(ctx, r.db.Pool.QueryRow(ctx, query, id))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, errors.New(errors.ErrCodeChannelNotFound, "channel not found")
@@ -122,7 +132,7 @@ func (r *ChannelRepository) FindByTenant(ctx context.Context, tenantID string, p
 
 	var channels []*entity.Channel
 	for rows.Next() {
-		channel, err := r.scanChannelFromRows(rows)
+		channel, err := r.scanChannelFromRows(ctx, rows)
 		if err != nil {
 			return nil, 0, err
 		}
@@ -151,7 +161,7 @@ func (r *ChannelRepository) FindByType(ctx context.Context, tenantID string, cha
 
 	var channels []*entity.Channel
 	for rows.Next() {
-		channel, err := r.scanChannelFromRows(rows)
+		channel, err := r.scanChannelFromRows(ctx, rows)
 		if err != nil {
 			return nil, err
 		}
@@ -180,7 +190,7 @@ func (r *ChannelRepository) FindEnabledByTenant(ctx context.Context, tenantID st
 
 	var channels []*entity.Channel
 	for rows.Next() {
-		channel, err := r.scanChannelFromRows(rows)
+		channel, err := r.scanChannelFromRows(ctx, rows)
 		if err != nil {
 			return nil, err
 		}
@@ -209,7 +219,7 @@ func (r *ChannelRepository) FindActiveByTenant(ctx context.Context, tenantID str
 
 	var channels []*entity.Channel
 	for rows.Next() {
-		channel, err := r.scanChannelFromRows(rows)
+		channel, err := r.scanChannelFromRows(ctx, rows)
 		if err != nil {
 			return nil, err
 		}
@@ -223,7 +233,7 @@ func (r *ChannelRepository) FindActiveByTenant(ctx context.Context, tenantID str
 func (r *ChannelRepository) Update(ctx context.Context, channel *entity.Channel) error {
 	channel.UpdatedAt = time.Now()
 
-	credentials, err := json.Marshal(channel.Credentials)
+	credentials, err := json.Marshal(channel.StoredCredentials())
 	if err != nil {
 		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal credentials")
 	}
@@ -373,7 +383,7 @@ func (r *ChannelRepository) FindByTypes(ctx context.Context, types []entity.Chan
 
 	var channels []*entity.Channel
 	for rows.Next() {
-		channel, err := r.scanChannelFromRows(rows)
+		channel, err := r.scanChannelFromRows(ctx, rows)
 		if err != nil {
 			return nil, err
 		}
@@ -403,7 +413,7 @@ func (r *ChannelRepository) FindByConfigValue(ctx context.Context, key, value st
 
 	var channels []*entity.Channel
 	for rows.Next() {
-		channel, err := r.scanChannelFromRows(rows)
+		channel, err := r.scanChannelFromRows(ctx, rows)
 		if err != nil {
 			return nil, err
 		}
@@ -425,7 +435,7 @@ func (r *ChannelRepository) FindWhatsAppByPhoneNumberID(ctx context.Context, pho
 		LIMIT 1
 	`
 
-	channel, err := r.scanChannel(r.db.Pool.QueryRow(ctx, query, phoneNumberID))
+	channel, err := r.scanChannel(ctx, r.db.Pool.QueryRow(ctx, query, phoneNumberID))
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, errors.New(errors.ErrCodeChannelNotFound, "WhatsApp channel not found")
@@ -438,7 +448,7 @@ func (r *ChannelRepository) FindWhatsAppByPhoneNumberID(ctx context.Context, pho
 
 // Helper methods
 
-func (r *ChannelRepository) scanChannel(row pgx.Row) (*entity.Channel, error) {
+func (r *ChannelRepository) scanChannel(ctx context.Context, row pgx.Row) (*entity.Channel, error) {
 	var c entity.Channel
 	var channelType, connectionStatus string
 	var credentials, config []byte
@@ -472,6 +482,7 @@ func (r *ChannelRepository) scanChannel(row pgx.Row) (*entity.Channel, error) {
 	if err := json.Unmarshal(credentials, &c.Credentials); err != nil {
 		c.Credentials = make(map[string]string)
 	}
+	r.resolveCredentials(ctx, &c)
 
 	if err := json.Unmarshal(config, &c.Config); err != nil {
 		c.Config = make(map[string]string)
@@ -480,7 +491,7 @@ func (r *ChannelRepository) scanChannel(row pgx.Row) (*entity.Channel, error) {
 	return &c, nil
 }
 
-func (r *ChannelRepository) scanChannelFromRows(rows pgx.Rows) (*entity.Channel, error) {
+func (r *ChannelRepository) scanChannelFromRows(ctx context.Context, rows pgx.Rows) (*entity.Channel, error) {
 	var c entity.Channel
 	var channelType, connectionStatus string
 	var credentials, config []byte
@@ -514,6 +525,7 @@ func (r *ChannelRepository) scanChannelFromRows(rows pgx.Rows) (*entity.Channel,
 	if err := json.Unmarshal(credentials, &c.Credentials); err != nil {
 		c.Credentials = make(map[string]string)
 	}
+	r.resolveCredentials(ctx, &c)
 
 	if err := json.Unmarshal(config, &c.Config); err != nil {
 		c.Config = make(map[string]string)
@@ -522,6 +534,31 @@ func (r *ChannelRepository) scanChannelFromRows(rows pgx.Rows) (*entity.Channel,
 	return &c, nil
 }
 
+// resolveCredentials replaces the secret references among the credentials of a
+// channel with their values. References that cannot be resolved are kept, so the
+// adapter fails to authenticate instead of the channel failing to load.
+func (r *ChannelRepository) resolveCredentials(ctx context.Context, c *entity.Channel) {
+	if r.secrets == nil {
+		return
+	}
+	for key, value := range c.Credentials {
+		if !secrets.IsRef(value) {
+			continue
+		}
+		resolved, err := r.secrets.Resolve(ctx, value)
+		if err != nil {
+			logger.Warn("Failed to resolve channel credential",
+				zap.String("channel_id", c.ID), zap.String("credential", key), zap.Error(err))
+			continue
+		}
+		if c.CredentialRefs == nil {
+			c.CredentialRefs = make(map[string]entity.CredentialRef)
+		}
+		c.CredentialRefs[key] = entity.CredentialRef{Reference: value, Value: resolved}
+		c.Credentials[key] = resolved
+	}
+}
+
 func normalizeCoexistenceStatus(status entity.CoexistenceStatus) string {
 	if status == "" {
 		return string(entity.CoexistenceStatusInactive)
@@ -548,7 +585,39 @@ func (r *ChannelRepository) FindCoexistenceChannels(ctx context.Context) ([]*ent
 
 	var channels []*entity.Channel
 	for rows.Next() {
-		channel, err := r.scanChannelFromRows(rows)
+		channel, err := r.scanChannelFromRows(ctx, rows)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, channel)
+	}
+
+	return channels, nil
+}
+
+// FindBySecretRef finds the channels with credentials pointing into a secret,
+// given as secret://<backend>/<path>
+func (r *ChannelRepository) FindBySecretRef(ctx context.Context, secretRef string) ([]*entity.Channel, error) {
+	query := `
+		SELECT id, tenant_id, name, type, enabled, connection_status, credentials, config,
+		       webhook_url, is_coexistence, waba_id, last_echo_at, coexistence_status,
+		       created_at, updated_at
+		FROM channels
+		WHERE CASE WHEN jsonb_typeof(credentials) = 'object' THEN EXISTS (
+			SELECT 1 FROM jsonb_each_text(credentials) AS credential
+			WHERE credential.value = $1 OR starts_with(credential.value, $1 || '#')
+		) ELSE false END
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, secretRef)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to query channels by secret")
+	}
+	defer rows.Close()
+
+	var channels []*entity.Channel
+	for rows.Next() {
+		channel, err := r.scanChannelFromRows(ctx, rows)
 		if err != nil {
 			return nil, err
 		}
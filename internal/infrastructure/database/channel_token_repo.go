@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// ChannelTokenRepository implements repository.ChannelTokenRepository with PostgreSQL
+type ChannelTokenRepository struct {
+	db *PostgresDB
+}
+
+// NewChannelTokenRepository creates a new PostgreSQL channel token repository
+func NewChannelTokenRepository(db *PostgresDB) *ChannelTokenRepository {
+	return &ChannelTokenRepository{db: db}
+}
+
+// Upsert stores the token state of a channel, replacing the previous one
+func (r *ChannelTokenRepository) Upsert(ctx context.Context, token *entity.ChannelToken) error {
+	query := `
+		INSERT INTO channel_tokens (channel_id, tenant_id, fingerprint, status, expires_at, checked_at,
+			refreshed_at, failures, last_error, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (channel_id) DO UPDATE SET
+			fingerprint = EXCLUDED.fingerprint,
+			status = EXCLUDED.status,
+			expires_at = EXCLUDED.expires_at,
+			checked_at = EXCLUDED.checked_at,
+			refreshed_at = EXCLUDED.refreshed_at,
+			failures = EXCLUDED.failures,
+			last_error = EXCLUDED.last_error,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err := r.db.Pool.Exec(ctx, query,
+		token.ChannelID,
+		token.TenantID,
+		token.Fingerprint,
+		string(token.Status),
+		token.ExpiresAt,
+		token.CheckedAt,
+		token.RefreshedAt,
+		token.Failures,
+		token.LastError,
+		token.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to save channel token")
+	}
+	return nil
+}
+
+// FindByChannel finds the token state of a channel
+func (r *ChannelTokenRepository) FindByChannel(ctx context.Context, channelID string) (*entity.ChannelToken, error) {
+	query := `
+		SELECT channel_id, tenant_id, fingerprint, status, expires_at, checked_at, refreshed_at,
+		       failures, last_error, updated_at
+		FROM channel_tokens
+		WHERE channel_id = $1
+	`
+
+	var token entity.ChannelToken
+	var status string
+	err := r.db.Pool.QueryRow(ctx, query, channelID).Scan(
+		&token.ChannelID,
+		&token.TenantID,
+		&token.Fingerprint,
+		&status,
+		&token.ExpiresAt,
+		&token.CheckedAt,
+		&token.RefreshedAt,
+		&token.Failures,
+		&token.LastError,
+		&token.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "channel token not tracked")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find channel token")
+	}
+
+	token.Status = entity.ChannelTokenStatus(status)
+	return &token, nil
+}
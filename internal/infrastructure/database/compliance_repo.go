@@ -0,0 +1,267 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// ComplianceRepository implements repository.ComplianceRepository with PostgreSQL
+type ComplianceRepository struct {
+	db *PostgresDB
+}
+
+// NewComplianceRepository creates a new PostgreSQL compliance repository
+func NewComplianceRepository(db *PostgresDB) *ComplianceRepository {
+	return &ComplianceRepository{db: db}
+}
+
+const complianceRuleColumns = `id, tenant_id, name, check_type, terms, country, channel_types,
+	template_categories, action, message, enabled, created_at, updated_at`
+
+// CreateRule stores a tenant rule
+func (r *ComplianceRepository) CreateRule(ctx context.Context, rule *entity.ComplianceRule) error {
+	query := `
+		INSERT INTO compliance_rules (` + complianceRuleColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`
+	_, err := r.db.Pool.Exec(ctx, query,
+		rule.ID,
+		rule.TenantID,
+		rule.Name,
+		string(rule.Check),
+		nonNilStrings(rule.Terms),
+		rule.Country,
+		channelTypeStrings(rule.ChannelTypes),
+		templateCategoryStrings(rule.TemplateCategories),
+		string(rule.Action),
+		rule.Message,
+		rule.Enabled,
+		rule.CreatedAt,
+		rule.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create compliance rule")
+	}
+	return nil
+}
+
+// UpdateRule updates a tenant rule
+func (r *ComplianceRepository) UpdateRule(ctx context.Context, rule *entity.ComplianceRule) error {
+	query := `
+		UPDATE compliance_rules SET
+			name = $2, check_type = $3, terms = $4, country = $5, channel_types = $6,
+			template_categories = $7, action = $8, message = $9, enabled = $10, updated_at = $11
+		WHERE id = $1
+	`
+	result, err := r.db.Pool.Exec(ctx, query,
+		rule.ID,
+		rule.Name,
+		string(rule.Check),
+		nonNilStrings(rule.Terms),
+		rule.Country,
+		channelTypeStrings(rule.ChannelTypes),
+		templateCategoryStrings(rule.TemplateCategories),
+		string(rule.Action),
+		rule.Message,
+		rule.Enabled,
+		rule.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update compliance rule")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "compliance rule not found")
+	}
+	return nil
+}
+
+// DeleteRule deletes a tenant rule
+func (r *ComplianceRepository) DeleteRule(ctx context.Context, id string) error {
+	result, err := r.db.Pool.Exec(ctx, `DELETE FROM compliance_rules WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete compliance rule")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "compliance rule not found")
+	}
+	return nil
+}
+
+// FindRuleByID finds a tenant rule
+func (r *ComplianceRepository) FindRuleByID(ctx context.Context, id string) (*entity.ComplianceRule, error) {
+	query := `SELECT ` + complianceRuleColumns + ` FROM compliance_rules WHERE id = $1`
+	rule, err := r.scanRule(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "compliance rule not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find compliance rule")
+	}
+	return rule, nil
+}
+
+// ListRules returns the rules of a tenant, oldest first
+func (r *ComplianceRepository) ListRules(ctx context.Context, tenantID string) ([]*entity.ComplianceRule, error) {
+	query := `SELECT ` + complianceRuleColumns + ` FROM compliance_rules WHERE tenant_id = $1 ORDER BY created_at`
+	rows, err := r.db.Pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list compliance rules")
+	}
+	defer rows.Close()
+
+	var rules []*entity.ComplianceRule
+	for rows.Next() {
+		rule, err := r.scanRule(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan compliance rule")
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate compliance rules")
+	}
+	return rules, nil
+}
+
+func (r *ComplianceRepository) scanRule(row pgx.Row) (*entity.ComplianceRule, error) {
+	var rule entity.ComplianceRule
+	var check, action string
+	var channelTypes, categories []string
+	err := row.Scan(
+		&rule.ID,
+		&rule.TenantID,
+		&rule.Name,
+		&check,
+		&rule.Terms,
+		&rule.Country,
+		&channelTypes,
+		&categories,
+		&action,
+		&rule.Message,
+		&rule.Enabled,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	rule.Check = entity.ComplianceCheck(check)
+	rule.Action = entity.ComplianceAction(action)
+	for _, t := range channelTypes {
+		rule.ChannelTypes = append(rule.ChannelTypes, entity.ChannelType(t))
+	}
+	for _, c := range categories {
+		rule.TemplateCategories = append(rule.TemplateCategories, entity.TemplateCategory(c))
+	}
+	return &rule, nil
+}
+
+// CreateOverride records an override
+func (r *ComplianceRepository) CreateOverride(ctx context.Context, override *entity.ComplianceOverride) error {
+	findings, err := json.Marshal(override.Findings)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal compliance findings")
+	}
+
+	query := `
+		INSERT INTO compliance_overrides (
+			id, tenant_id, target_type, target_id, target_name, user_id, reason, findings, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err = r.db.Pool.Exec(ctx, query,
+		override.ID,
+		override.TenantID,
+		string(override.TargetType),
+		override.TargetID,
+		override.TargetName,
+		override.UserID,
+		override.Reason,
+		findings,
+		override.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to record compliance override")
+	}
+	return nil
+}
+
+// ListOverrides returns the overrides of a tenant, newest first
+func (r *ComplianceRepository) ListOverrides(ctx context.Context, tenantID string, params *repository.ListParams) ([]*entity.ComplianceOverride, int64, error) {
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM compliance_overrides WHERE tenant_id = $1`, tenantID).Scan(&total); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count compliance overrides")
+	}
+
+	query := `
+		SELECT id, tenant_id, target_type, target_id, target_name, user_id, reason, findings, created_at
+		FROM compliance_overrides
+		WHERE tenant_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+	rows, err := r.db.Pool.Query(ctx, query, tenantID, params.Limit(), params.Offset())
+	if err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to list compliance overrides")
+	}
+	defer rows.Close()
+
+	var overrides []*entity.ComplianceOverride
+	for rows.Next() {
+		var override entity.ComplianceOverride
+		var targetType string
+		var findings []byte
+		if err := rows.Scan(
+			&override.ID,
+			&override.TenantID,
+			&targetType,
+			&override.TargetID,
+			&override.TargetName,
+			&override.UserID,
+			&override.Reason,
+			&findings,
+			&override.CreatedAt,
+		); err != nil {
+			return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan compliance override")
+		}
+		if len(findings) > 0 {
+			if err := json.Unmarshal(findings, &override.Findings); err != nil {
+				return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal compliance findings")
+			}
+		}
+		override.TargetType = entity.ComplianceTargetType(targetType)
+		overrides = append(overrides, &override)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate compliance overrides")
+	}
+	return overrides, total, nil
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+func channelTypeStrings(types []entity.ChannelType) []string {
+	values := make([]string, len(types))
+	for i, t := range types {
+		values[i] = string(t)
+	}
+	return values
+}
+
+func templateCategoryStrings(categories []entity.TemplateCategory) []string {
+	values := make([]string, len(categories))
+	for i, c := range categories {
+		values[i] = string(c)
+	}
+	return values
+}
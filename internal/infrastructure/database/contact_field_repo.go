@@ -0,0 +1,149 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// ContactFieldRepository implements repository.ContactFieldRepository with PostgreSQL
+type ContactFieldRepository struct {
+	db *PostgresDB
+}
+
+// NewContactFieldRepository creates a new PostgreSQL contact field repository
+func NewContactFieldRepository(db *PostgresDB) *ContactFieldRepository {
+	return &ContactFieldRepository{db: db}
+}
+
+const contactFieldColumns = `
+	id, tenant_id, key, label, description, type, required, options, pattern,
+	min_value, max_value, created_at, updated_at
+`
+
+// Create creates a new contact field
+func (r *ContactFieldRepository) Create(ctx context.Context, field *entity.ContactField) error {
+	query := `INSERT INTO contact_fields (` + contactFieldColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+	_, err := r.db.Pool.Exec(ctx, query,
+		field.ID,
+		field.TenantID,
+		field.Key,
+		field.Label,
+		field.Description,
+		string(field.Type),
+		field.Required,
+		field.Options,
+		field.Pattern,
+		field.Min,
+		field.Max,
+		field.CreatedAt,
+		field.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create contact field")
+	}
+	return nil
+}
+
+// FindByID finds a contact field by ID
+func (r *ContactFieldRepository) FindByID(ctx context.Context, id string) (*entity.ContactField, error) {
+	query := `SELECT ` + contactFieldColumns + ` FROM contact_fields WHERE id = $1`
+	return r.scanField(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// FindByTenant lists the contact fields of a tenant ordered by key
+func (r *ContactFieldRepository) FindByTenant(ctx context.Context, tenantID string) ([]*entity.ContactField, error) {
+	query := `SELECT ` + contactFieldColumns + ` FROM contact_fields WHERE tenant_id = $1 ORDER BY key`
+	rows, err := r.db.Pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list contact fields")
+	}
+	defer rows.Close()
+
+	var fields []*entity.ContactField
+	for rows.Next() {
+		field, err := r.scanField(rows)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate contact fields")
+	}
+	return fields, nil
+}
+
+// Update updates a contact field. The key cannot change.
+func (r *ContactFieldRepository) Update(ctx context.Context, field *entity.ContactField) error {
+	query := `
+		UPDATE contact_fields
+		SET label = $2, description = $3, type = $4, required = $5, options = $6,
+		    pattern = $7, min_value = $8, max_value = $9, updated_at = $10
+		WHERE id = $1
+	`
+	tag, err := r.db.Pool.Exec(ctx, query,
+		field.ID,
+		field.Label,
+		field.Description,
+		string(field.Type),
+		field.Required,
+		field.Options,
+		field.Pattern,
+		field.Min,
+		field.Max,
+		field.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update contact field")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "contact field not found")
+	}
+	return nil
+}
+
+// Delete deletes a contact field. Values already stored on contacts are kept.
+func (r *ContactFieldRepository) Delete(ctx context.Context, id string) error {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM contact_fields WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete contact field")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "contact field not found")
+	}
+	return nil
+}
+
+func (r *ContactFieldRepository) scanField(row pgx.Row) (*entity.ContactField, error) {
+	var field entity.ContactField
+	var fieldType string
+
+	err := row.Scan(
+		&field.ID,
+		&field.TenantID,
+		&field.Key,
+		&field.Label,
+		&field.Description,
+		&fieldType,
+		&field.Required,
+		&field.Options,
+		&field.Pattern,
+		&field.Min,
+		&field.Max,
+		&field.CreatedAt,
+		&field.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "contact field not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan contact field")
+	}
+
+	field.Type = entity.ContactFieldType(fieldType)
+	return &field, nil
+}
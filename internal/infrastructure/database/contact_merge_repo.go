@@ -0,0 +1,234 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/lib/pq"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// ContactMergeRepository implements repository.ContactMergeRepository with PostgreSQL
+type ContactMergeRepository struct {
+	db *PostgresDB
+}
+
+// NewContactMergeRepository creates a new PostgreSQL contact merge repository
+func NewContactMergeRepository(db *PostgresDB) *ContactMergeRepository {
+	return &ContactMergeRepository{db: db}
+}
+
+const contactMergeColumns = `
+	id, tenant_id, source_contact_id, target_contact_id, reason, conversation_ids, identity_ids, message_count, performed_by, created_at
+`
+
+// contactMergeReferences are the tables that point at a contact without a uniqueness
+// constraint, re-pointed to the target as they are
+var contactMergeReferences = []string{
+	`UPDATE newsletter_deliveries SET contact_id = $2 WHERE contact_id = $1`,
+	`UPDATE bookings SET contact_id = $2 WHERE contact_id = $1`,
+	`UPDATE linked_objects SET contact_id = $2 WHERE contact_id = $1`,
+	`UPDATE form_submissions SET contact_id = $2 WHERE contact_id = $1`,
+	`UPDATE knowledge_feedback SET contact_id = $2 WHERE contact_id = $1`,
+}
+
+// contactMergeUniqueReferences are the tables where the target may already hold the
+// same row; the source's duplicate is dropped before the rest are re-pointed
+var contactMergeUniqueReferences = []string{
+	`DELETE FROM newsletter_subscriptions s WHERE s.contact_id = $1 AND EXISTS (
+		SELECT 1 FROM newsletter_subscriptions t WHERE t.contact_id = $2 AND t.newsletter_id = s.newsletter_id)`,
+	`UPDATE newsletter_subscriptions SET contact_id = $2 WHERE contact_id = $1`,
+	`DELETE FROM postback_votes s WHERE s.contact_id = $1 AND EXISTS (
+		SELECT 1 FROM postback_votes t WHERE t.contact_id = $2 AND t.tenant_id = s.tenant_id AND t.poll_id = s.poll_id)`,
+	`UPDATE postback_votes SET contact_id = $2 WHERE contact_id = $1`,
+	`DELETE FROM watches s WHERE s.target_type = 'contact' AND s.target_id = $1 AND EXISTS (
+		SELECT 1 FROM watches t WHERE t.target_type = 'contact' AND t.target_id = $2 AND t.user_id = s.user_id)`,
+	`UPDATE watches SET target_id = $2 WHERE target_type = 'contact' AND target_id = $1`,
+}
+
+// Merge saves the target contact, moves everything of the source to it, deletes the
+// source and records the merge
+func (r *ContactMergeRepository) Merge(ctx context.Context, merge *entity.ContactMerge, target *entity.Contact) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to begin contact merge")
+	}
+	defer tx.Rollback(ctx)
+
+	source, dest := merge.SourceContactID, merge.TargetContactID
+
+	// Identities the target already has would violate the unique constraint
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM contact_identities s
+		WHERE s.contact_id = $1 AND EXISTS (
+			SELECT 1 FROM contact_identities t
+			WHERE t.contact_id = $2 AND t.channel_type = s.channel_type AND t.identifier = s.identifier
+		)
+	`, source, dest); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to drop duplicate identities")
+	}
+	rows, err := tx.Query(ctx, `UPDATE contact_identities SET contact_id = $2 WHERE contact_id = $1 RETURNING id`, source, dest)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to move identities")
+	}
+	merge.IdentityIDs, err = pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to move identities")
+	}
+
+	rows, err = tx.Query(ctx, `UPDATE conversations SET contact_id = $2 WHERE contact_id = $1 RETURNING id`, source, dest)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to move conversations")
+	}
+	merge.ConversationIDs, err = pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to move conversations")
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE messages SET sender_id = $2
+		WHERE sender_type = $3 AND sender_id = $1
+	`, source, dest, string(entity.SenderTypeContact))
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to move messages")
+	}
+	merge.MessageCount = tag.RowsAffected()
+
+	for _, query := range append(contactMergeUniqueReferences, contactMergeReferences...) {
+		if _, err := tx.Exec(ctx, query, source, dest); err != nil {
+			return errors.Wrap(err, errors.ErrCodeInternal, "failed to move contact references")
+		}
+	}
+
+	customFields, err := json.Marshal(target.CustomFields)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal custom fields")
+	}
+	addresses, err := marshalContactAddresses(target.Addresses)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `
+		UPDATE contacts SET
+			name = $1, email = $2, phone = $3, avatar_url = $4,
+			custom_fields = $5, tags = $6, addresses = $7, updated_at = $8
+		WHERE id = $9
+	`,
+		nullString(target.Name),
+		nullString(target.Email),
+		nullString(target.Phone),
+		nullString(target.AvatarURL),
+		customFields,
+		pq.Array(target.Tags),
+		addresses,
+		target.UpdatedAt,
+		target.ID,
+	); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update contact")
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM contacts WHERE id = $1`, source); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete merged contact")
+	}
+
+	query := `INSERT INTO contact_merges (` + contactMergeColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+	if _, err := tx.Exec(ctx, query,
+		merge.ID,
+		merge.TenantID,
+		merge.SourceContactID,
+		merge.TargetContactID,
+		string(merge.Reason),
+		merge.ConversationIDs,
+		merge.IdentityIDs,
+		merge.MessageCount,
+		merge.PerformedBy,
+		merge.CreatedAt,
+	); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to record contact merge")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to commit contact merge")
+	}
+	return nil
+}
+
+// FindMatches finds the contacts sharing a normalized phone or email, oldest first
+func (r *ContactMergeRepository) FindMatches(ctx context.Context, tenantID, phone, email string) ([]*entity.Contact, error) {
+	phone = entity.NormalizePhone(phone)
+	email = entity.NormalizeEmail(email)
+	if phone == "" && email == "" {
+		return nil, nil
+	}
+
+	query := `
+		SELECT id, tenant_id, name, email, phone, avatar_url,
+		       custom_fields, tags, addresses, created_at, updated_at
+		FROM contacts
+		WHERE tenant_id = $1 AND (
+			($2 <> '' AND regexp_replace(regexp_replace(COALESCE(phone, ''), '[^0-9]', '', 'g'), '^00', '') = $2)
+			OR ($3 <> '' AND LOWER(TRIM(COALESCE(email, ''))) = $3)
+		)
+		ORDER BY created_at ASC
+	`
+	rows, err := r.db.Pool.Query(ctx, query, tenantID, phone, email)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to query matching contacts")
+	}
+	defer rows.Close()
+
+	contacts := &ContactRepository{db: r.db}
+	var matches []*entity.Contact
+	for rows.Next() {
+		contact, err := contacts.scanContactFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, contact)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate matching contacts")
+	}
+	return matches, nil
+}
+
+// ListByContact lists the merges into a contact, newest first
+func (r *ContactMergeRepository) ListByContact(ctx context.Context, contactID string) ([]*entity.ContactMerge, error) {
+	query := `SELECT ` + contactMergeColumns + ` FROM contact_merges
+		WHERE target_contact_id = $1
+		ORDER BY created_at DESC`
+	rows, err := r.db.Pool.Query(ctx, query, contactID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list contact merges")
+	}
+	defer rows.Close()
+
+	var merges []*entity.ContactMerge
+	for rows.Next() {
+		var merge entity.ContactMerge
+		var reason string
+		if err := rows.Scan(
+			&merge.ID,
+			&merge.TenantID,
+			&merge.SourceContactID,
+			&merge.TargetContactID,
+			&reason,
+			&merge.ConversationIDs,
+			&merge.IdentityIDs,
+			&merge.MessageCount,
+			&merge.PerformedBy,
+			&merge.CreatedAt,
+		); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan contact merge")
+		}
+		merge.Reason = entity.ContactMatchReason(reason)
+		merges = append(merges, &merge)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate contact merges")
+	}
+	return merges, nil
+}
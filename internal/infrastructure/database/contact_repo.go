@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -30,12 +31,16 @@ func (r *ContactRepository) Create(ctx context.Context, contact *entity.Contact)
 	if err != nil {
 		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal custom fields")
 	}
+	addresses, err := marshalContactAddresses(contact.Addresses)
+	if err != nil {
+		return err
+	}
 
 	query := `
 		INSERT INTO contacts (
 			id, tenant_id, name, email, phone, avatar_url,
-			custom_fields, tags, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			custom_fields, tags, addresses, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`
 
 	_, err = r.db.Pool.Exec(ctx, query,
@@ -47,6 +52,7 @@ func (r *ContactRepository) Create(ctx context.Context, contact *entity.Contact)
 		nullString(contact.AvatarURL),
 		customFields,
 		pq.Array(contact.Tags),
+		addresses,
 		contact.CreatedAt,
 		contact.UpdatedAt,
 	)
@@ -62,7 +68,7 @@ func (r *ContactRepository) Create(ctx context.Context, contact *entity.Contact)
 func (r *ContactRepository) FindByID(ctx context.Context, id string) (*entity.Contact, error) {
 	query := `
 		SELECT id, tenant_id, name, email, phone, avatar_url,
-		       custom_fields, tags, created_at, updated_at
+		       custom_fields, tags, addresses, created_at, updated_at
 		FROM contacts
 		WHERE id = $1
 	`
@@ -99,6 +105,46 @@ func (r *ContactRepository) FindByTenant(ctx context.Context, tenantID string, p
 		}
 	}
 
+	if customFields, ok := params.Filters["custom_fields"].(map[string]string); ok && len(customFields) > 0 {
+		values, err := json.Marshal(customFields)
+		if err != nil {
+			return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal custom field filter")
+		}
+		conditions = append(conditions, fmt.Sprintf("custom_fields @> $%d::jsonb", nextArg))
+		args = append(args, string(values))
+		nextArg++
+	}
+
+	return r.findWhere(ctx, conditions, args, params)
+}
+
+// FindBySegment finds the contacts of a tenant matching a segment
+func (r *ContactRepository) FindBySegment(ctx context.Context, tenantID string, segment *entity.ContactSegment, params *repository.ListParams) ([]*entity.Contact, int64, error) {
+	if params == nil {
+		params = repository.NewListParams()
+	}
+
+	conditions := []string{"tenant_id = $1"}
+	args := []interface{}{tenantID}
+
+	if contactID, ok := params.Filters["contact_id"].(string); ok && contactID != "" {
+		args = append(args, contactID)
+		conditions = append(conditions, fmt.Sprintf("id = $%d", len(args)))
+	}
+	if condition := segmentRulesCondition(segment.Rules, segment.Match == entity.SegmentMatchAny, &args); condition != "" {
+		conditions = append(conditions, condition)
+	}
+	if rules, ok := params.Filters["rules"].([]entity.SegmentRule); ok {
+		if condition := segmentRulesCondition(rules, false, &args); condition != "" {
+			conditions = append(conditions, condition)
+		}
+	}
+
+	return r.findWhere(ctx, conditions, args, params)
+}
+
+// findWhere lists the contacts matching all conditions, a page at a time
+func (r *ContactRepository) findWhere(ctx context.Context, conditions []string, args []interface{}, params *repository.ListParams) ([]*entity.Contact, int64, error) {
 	whereClause := "WHERE " + strings.Join(conditions, " AND ")
 
 	// Count total
@@ -111,12 +157,12 @@ func (r *ContactRepository) FindByTenant(ctx context.Context, tenantID string, p
 	// Get contacts
 	query := fmt.Sprintf(`
 		SELECT id, tenant_id, name, email, phone, avatar_url,
-		       custom_fields, tags, created_at, updated_at
+		       custom_fields, tags, addresses, created_at, updated_at
 		FROM contacts
 		%s
 		ORDER BY %s %s
 		LIMIT $%d OFFSET $%d
-	`, whereClause, sanitizeContactColumn(params.SortBy), sanitizeDirection(params.SortDir), nextArg, nextArg+1)
+	`, whereClause, sanitizeContactColumn(params.SortBy), sanitizeDirection(params.SortDir), len(args)+1, len(args)+2)
 
 	queryArgs := append(append([]interface{}{}, args...), params.Limit(), params.Offset())
 	rows, err := r.db.Pool.Query(ctx, query, queryArgs...)
@@ -137,11 +183,102 @@ func (r *ContactRepository) FindByTenant(ctx context.Context, tenantID string, p
 	return contacts, total, nil
 }
 
+// contactLastContactedAt is when the latest message of any of the contact's conversations was sent
+const contactLastContactedAt = `(SELECT MAX(m.created_at) FROM messages m
+	JOIN conversations cv ON cv.id = m.conversation_id
+	WHERE cv.contact_id = contacts.id)`
+
+// segmentRulesCondition builds the SQL condition of segment rules, appending their
+// values to args. It returns "" when there are no rules.
+func segmentRulesCondition(rules []entity.SegmentRule, matchAny bool, args *[]interface{}) string {
+	if len(rules) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(rules))
+	for i := range rules {
+		parts = append(parts, segmentRuleCondition(&rules[i], args))
+	}
+	joiner := " AND "
+	if matchAny {
+		joiner = " OR "
+	}
+	return "(" + strings.Join(parts, joiner) + ")"
+}
+
+func segmentRuleCondition(rule *entity.SegmentRule, args *[]interface{}) string {
+	arg := func(value interface{}) string {
+		*args = append(*args, value)
+		return fmt.Sprintf("$%d", len(*args))
+	}
+	days := func() string {
+		n, _ := rule.Days()
+		return "NOW() - make_interval(days => " + arg(n) + ")"
+	}
+
+	switch rule.Field {
+	case entity.SegmentFieldTag:
+		condition := arg(rule.Value) + " = ANY(COALESCE(tags, '{}'))"
+		if rule.Operator == entity.SegmentOpNotHas {
+			return "NOT (" + condition + ")"
+		}
+		return condition
+
+	case entity.SegmentFieldChannel:
+		condition := "EXISTS (SELECT 1 FROM contact_identities ci WHERE ci.contact_id = contacts.id AND ci.channel_type = " + arg(rule.Value) + ")"
+		if rule.Operator == entity.SegmentOpNotHas {
+			return "NOT " + condition
+		}
+		return condition
+
+	case entity.SegmentFieldCustomField:
+		field := "(custom_fields->>" + arg(rule.Key) + ")"
+		switch rule.Operator {
+		case entity.SegmentOpEquals:
+			return field + " = " + arg(rule.Value)
+		case entity.SegmentOpNotEquals:
+			return "COALESCE(" + field + ", '') <> " + arg(rule.Value)
+		case entity.SegmentOpContains:
+			return field + " ILIKE " + arg("%"+rule.Value+"%")
+		case entity.SegmentOpExists:
+			return field + " IS NOT NULL"
+		case entity.SegmentOpNotExists:
+			return field + " IS NULL"
+		case entity.SegmentOpGreaterThan, entity.SegmentOpLessThan:
+			comparison := " > "
+			if rule.Operator == entity.SegmentOpLessThan {
+				comparison = " < "
+			}
+			limit, _ := strconv.ParseFloat(rule.Value, 64)
+			// Values that are not numbers never match; CASE keeps them from being cast
+			return "(CASE WHEN " + field + " ~ '^-?[0-9]+(\\.[0-9]+)?$' THEN " + field + "::numeric" + comparison + arg(limit) + " ELSE FALSE END)"
+		}
+
+	case entity.SegmentFieldLastContacted:
+		switch rule.Operator {
+		case entity.SegmentOpNever:
+			return contactLastContactedAt + " IS NULL"
+		case entity.SegmentOpWithinDays:
+			return contactLastContactedAt + " >= " + days()
+		case entity.SegmentOpOlderThanDays:
+			return contactLastContactedAt + " < " + days()
+		}
+
+	case entity.SegmentFieldCreated:
+		if rule.Operator == entity.SegmentOpWithinDays {
+			return "created_at >= " + days()
+		}
+		return "created_at < " + days()
+	}
+
+	// Unknown rules never match
+	return "FALSE"
+}
+
 // FindByEmail finds a contact by email within a tenant
 func (r *ContactRepository) FindByEmail(ctx context.Context, tenantID, email string) (*entity.Contact, error) {
 	query := `
 		SELECT id, tenant_id, name, email, phone, avatar_url,
-		       custom_fields, tags, created_at, updated_at
+		       custom_fields, tags, addresses, created_at, updated_at
 		FROM contacts
 		WHERE tenant_id = $1 AND email = $2
 	`
@@ -161,7 +298,7 @@ func (r *ContactRepository) FindByEmail(ctx context.Context, tenantID, email str
 func (r *ContactRepository) FindByPhone(ctx context.Context, tenantID, phone string) (*entity.Contact, error) {
 	query := `
 		SELECT id, tenant_id, name, email, phone, avatar_url,
-		       custom_fields, tags, created_at, updated_at
+		       custom_fields, tags, addresses, created_at, updated_at
 		FROM contacts
 		WHERE tenant_id = $1 AND phone = $2
 	`
@@ -181,7 +318,7 @@ func (r *ContactRepository) FindByPhone(ctx context.Context, tenantID, phone str
 func (r *ContactRepository) FindByIdentity(ctx context.Context, tenantID, channelType, identifier string) (*entity.Contact, error) {
 	query := `
 		SELECT c.id, c.tenant_id, c.name, c.email, c.phone, c.avatar_url,
-		       c.custom_fields, c.tags, c.created_at, c.updated_at
+		       c.custom_fields, c.tags, c.addresses, c.created_at, c.updated_at
 		FROM contacts c
 		JOIN contact_identities ci ON c.id = ci.contact_id
 		WHERE c.tenant_id = $1 AND ci.channel_type = $2 AND ci.identifier = $3
@@ -206,6 +343,10 @@ func (r *ContactRepository) Update(ctx context.Context, contact *entity.Contact)
 	if err != nil {
 		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal custom fields")
 	}
+	addresses, err := marshalContactAddresses(contact.Addresses)
+	if err != nil {
+		return err
+	}
 
 	query := `
 		UPDATE contacts SET
@@ -215,8 +356,9 @@ func (r *ContactRepository) Update(ctx context.Context, contact *entity.Contact)
 			avatar_url = $4,
 			custom_fields = $5,
 			tags = $6,
-			updated_at = $7
-		WHERE id = $8
+			addresses = $7,
+			updated_at = $8
+		WHERE id = $9
 	`
 
 	result, err := r.db.Pool.Exec(ctx, query,
@@ -226,6 +368,7 @@ func (r *ContactRepository) Update(ctx context.Context, contact *entity.Contact)
 		nullString(contact.AvatarURL),
 		customFields,
 		pq.Array(contact.Tags),
+		addresses,
 		contact.UpdatedAt,
 		contact.ID,
 	)
@@ -359,17 +502,52 @@ func (r *ContactRepository) FindIdentitiesByContact(ctx context.Context, contact
 	return identities, nil
 }
 
+// FindIdentityByUsername finds a tenant's channel identity by the username in its metadata
+func (r *ContactRepository) FindIdentityByUsername(ctx context.Context, tenantID, channelType, username string) (*entity.ContactIdentity, error) {
+	query := `
+		SELECT ci.id, ci.contact_id, ci.channel_type, ci.identifier, ci.metadata, ci.created_at
+		FROM contact_identities ci
+		JOIN contacts c ON c.id = ci.contact_id
+		WHERE c.tenant_id = $1 AND ci.channel_type = $2 AND LOWER(ci.metadata->>'username') = LOWER($3)
+		ORDER BY ci.created_at DESC
+		LIMIT 1
+	`
+
+	var identity entity.ContactIdentity
+	var metadata []byte
+	err := r.db.Pool.QueryRow(ctx, query, tenantID, channelType, username).Scan(
+		&identity.ID,
+		&identity.ContactID,
+		&identity.ChannelType,
+		&identity.Identifier,
+		&metadata,
+		&identity.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "identity not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find identity")
+	}
+
+	if err := json.Unmarshal(metadata, &identity.Metadata); err != nil {
+		identity.Metadata = make(map[string]string)
+	}
+
+	return &identity, nil
+}
+
 // Helper methods
 
 func (r *ContactRepository) scanContact(row pgx.Row) (*entity.Contact, error) {
 	var c entity.Contact
 	var name, email, phone, avatarURL *string
-	var customFields []byte
+	var customFields, addresses []byte
 	var tags []string
 
 	err := row.Scan(
 		&c.ID, &c.TenantID, &name, &email, &phone, &avatarURL,
-		&customFields, &tags, &c.CreatedAt, &c.UpdatedAt,
+		&customFields, &tags, &addresses, &c.CreatedAt, &c.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -393,6 +571,9 @@ func (r *ContactRepository) scanContact(row pgx.Row) (*entity.Contact, error) {
 	}
 
 	c.Tags = tags
+	if len(addresses) > 0 {
+		_ = json.Unmarshal(addresses, &c.Addresses)
+	}
 
 	return &c, nil
 }
@@ -400,12 +581,12 @@ func (r *ContactRepository) scanContact(row pgx.Row) (*entity.Contact, error) {
 func (r *ContactRepository) scanContactFromRows(rows pgx.Rows) (*entity.Contact, error) {
 	var c entity.Contact
 	var name, email, phone, avatarURL *string
-	var customFields []byte
+	var customFields, addresses []byte
 	var tags []string
 
 	err := rows.Scan(
 		&c.ID, &c.TenantID, &name, &email, &phone, &avatarURL,
-		&customFields, &tags, &c.CreatedAt, &c.UpdatedAt,
+		&customFields, &tags, &addresses, &c.CreatedAt, &c.UpdatedAt,
 	)
 	if err != nil {
 		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan contact")
@@ -429,10 +610,24 @@ func (r *ContactRepository) scanContactFromRows(rows pgx.Rows) (*entity.Contact,
 	}
 
 	c.Tags = tags
+	if len(addresses) > 0 {
+		_ = json.Unmarshal(addresses, &c.Addresses)
+	}
 
 	return &c, nil
 }
 
+func marshalContactAddresses(addresses []entity.ContactAddress) ([]byte, error) {
+	if addresses == nil {
+		addresses = []entity.ContactAddress{}
+	}
+	data, err := json.Marshal(addresses)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal addresses")
+	}
+	return data, nil
+}
+
 func sanitizeContactColumn(col string) string {
 	allowed := map[string]bool{
 		"created_at": true,
@@ -0,0 +1,152 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// ContactSegmentRepository implements repository.ContactSegmentRepository with PostgreSQL
+type ContactSegmentRepository struct {
+	db *PostgresDB
+}
+
+// NewContactSegmentRepository creates a new PostgreSQL contact segment repository
+func NewContactSegmentRepository(db *PostgresDB) *ContactSegmentRepository {
+	return &ContactSegmentRepository{db: db}
+}
+
+const contactSegmentColumns = `
+	id, tenant_id, name, description, match, rules, created_by, created_at, updated_at
+`
+
+// Create creates a new contact segment
+func (r *ContactSegmentRepository) Create(ctx context.Context, segment *entity.ContactSegment) error {
+	rules, err := json.Marshal(segment.Rules)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal segment rules")
+	}
+
+	query := `INSERT INTO contact_segments (` + contactSegmentColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	_, err = r.db.Pool.Exec(ctx, query,
+		segment.ID,
+		segment.TenantID,
+		segment.Name,
+		segment.Description,
+		string(segment.Match),
+		rules,
+		segment.CreatedBy,
+		segment.CreatedAt,
+		segment.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create contact segment")
+	}
+	return nil
+}
+
+// FindByID finds a contact segment by ID
+func (r *ContactSegmentRepository) FindByID(ctx context.Context, id string) (*entity.ContactSegment, error) {
+	query := `SELECT ` + contactSegmentColumns + ` FROM contact_segments WHERE id = $1`
+	return r.scanSegment(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// FindByTenant lists the contact segments of a tenant
+func (r *ContactSegmentRepository) FindByTenant(ctx context.Context, tenantID string) ([]*entity.ContactSegment, error) {
+	query := `SELECT ` + contactSegmentColumns + ` FROM contact_segments WHERE tenant_id = $1 ORDER BY name`
+	rows, err := r.db.Pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list contact segments")
+	}
+	defer rows.Close()
+
+	var segments []*entity.ContactSegment
+	for rows.Next() {
+		segment, err := r.scanSegment(rows)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, segment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate contact segments")
+	}
+	return segments, nil
+}
+
+// Update updates a contact segment
+func (r *ContactSegmentRepository) Update(ctx context.Context, segment *entity.ContactSegment) error {
+	rules, err := json.Marshal(segment.Rules)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal segment rules")
+	}
+
+	query := `
+		UPDATE contact_segments
+		SET name = $2, description = $3, match = $4, rules = $5, updated_at = $6
+		WHERE id = $1
+	`
+	tag, err := r.db.Pool.Exec(ctx, query,
+		segment.ID,
+		segment.Name,
+		segment.Description,
+		string(segment.Match),
+		rules,
+		segment.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update contact segment")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "contact segment not found")
+	}
+	return nil
+}
+
+// Delete deletes a contact segment
+func (r *ContactSegmentRepository) Delete(ctx context.Context, id string) error {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM contact_segments WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete contact segment")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "contact segment not found")
+	}
+	return nil
+}
+
+func (r *ContactSegmentRepository) scanSegment(row pgx.Row) (*entity.ContactSegment, error) {
+	var segment entity.ContactSegment
+	var match string
+	var rules []byte
+
+	err := row.Scan(
+		&segment.ID,
+		&segment.TenantID,
+		&segment.Name,
+		&segment.Description,
+		&match,
+		&rules,
+		&segment.CreatedBy,
+		&segment.CreatedAt,
+		&segment.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "contact segment not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan contact segment")
+	}
+
+	segment.Match = entity.SegmentMatch(match)
+	if len(rules) > 0 {
+		if err := json.Unmarshal(rules, &segment.Rules); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal segment rules")
+		}
+	}
+	return &segment, nil
+}
@@ -0,0 +1,332 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// ConversationFormRepository implements repository.ConversationFormRepository with PostgreSQL
+type ConversationFormRepository struct {
+	db *PostgresDB
+}
+
+// NewConversationFormRepository creates a new PostgreSQL conversation form repository
+func NewConversationFormRepository(db *PostgresDB) *ConversationFormRepository {
+	return &ConversationFormRepository{db: db}
+}
+
+const conversationFormColumns = `id, tenant_id, name, description, fields, ai_prefill, is_active, created_at, updated_at`
+
+const formSubmissionColumns = `
+	id, tenant_id, form_id, conversation_id, contact_id, field_values, ai_prefilled_fields, status,
+	created_by, submitted_by, submitted_at, created_at, updated_at
+`
+
+// CreateForm creates a new form
+func (r *ConversationFormRepository) CreateForm(ctx context.Context, form *entity.ConversationForm) error {
+	fields, err := json.Marshal(form.Fields)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal form fields")
+	}
+
+	query := `INSERT INTO conversation_forms (` + conversationFormColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	_, err = r.db.Pool.Exec(ctx, query,
+		form.ID,
+		form.TenantID,
+		form.Name,
+		form.Description,
+		fields,
+		form.AIPrefill,
+		form.IsActive,
+		form.CreatedAt,
+		form.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create form")
+	}
+	return nil
+}
+
+// FindFormByID finds a form by ID
+func (r *ConversationFormRepository) FindFormByID(ctx context.Context, id string) (*entity.ConversationForm, error) {
+	query := `SELECT ` + conversationFormColumns + ` FROM conversation_forms WHERE id = $1`
+	return r.scanForm(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// ListForms lists the forms of a tenant
+func (r *ConversationFormRepository) ListForms(ctx context.Context, tenantID string) ([]*entity.ConversationForm, error) {
+	query := `SELECT ` + conversationFormColumns + ` FROM conversation_forms WHERE tenant_id = $1 ORDER BY name`
+
+	rows, err := r.db.Pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list forms")
+	}
+	defer rows.Close()
+
+	var forms []*entity.ConversationForm
+	for rows.Next() {
+		form, err := r.scanForm(rows)
+		if err != nil {
+			return nil, err
+		}
+		forms = append(forms, form)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate forms")
+	}
+	return forms, nil
+}
+
+// UpdateForm updates a form
+func (r *ConversationFormRepository) UpdateForm(ctx context.Context, form *entity.ConversationForm) error {
+	fields, err := json.Marshal(form.Fields)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal form fields")
+	}
+
+	query := `
+		UPDATE conversation_forms
+		SET name = $2, description = $3, fields = $4, ai_prefill = $5, is_active = $6, updated_at = $7
+		WHERE id = $1
+	`
+	result, err := r.db.Pool.Exec(ctx, query,
+		form.ID,
+		form.Name,
+		form.Description,
+		fields,
+		form.AIPrefill,
+		form.IsActive,
+		form.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update form")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "form not found")
+	}
+	return nil
+}
+
+// DeleteForm deletes a form and its submissions
+func (r *ConversationFormRepository) DeleteForm(ctx context.Context, id string) error {
+	result, err := r.db.Pool.Exec(ctx, `DELETE FROM conversation_forms WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete form")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "form not found")
+	}
+	return nil
+}
+
+// CreateSubmission creates a new submission
+func (r *ConversationFormRepository) CreateSubmission(ctx context.Context, submission *entity.FormSubmission) error {
+	values, err := json.Marshal(submission.Values)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal submission values")
+	}
+
+	query := `INSERT INTO form_submissions (` + formSubmissionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+	_, err = r.db.Pool.Exec(ctx, query,
+		submission.ID,
+		submission.TenantID,
+		submission.FormID,
+		submission.ConversationID,
+		submission.ContactID,
+		values,
+		submission.AIPrefilledFields,
+		string(submission.Status),
+		submission.CreatedBy,
+		submission.SubmittedBy,
+		submission.SubmittedAt,
+		submission.CreatedAt,
+		submission.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create form submission")
+	}
+	return nil
+}
+
+// FindSubmissionByID finds a submission by ID
+func (r *ConversationFormRepository) FindSubmissionByID(ctx context.Context, id string) (*entity.FormSubmission, error) {
+	query := `SELECT ` + formSubmissionColumns + ` FROM form_submissions WHERE id = $1`
+	return r.scanSubmission(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// ListSubmissions lists the submissions of a tenant, newest first
+func (r *ConversationFormRepository) ListSubmissions(ctx context.Context, tenantID string, filter *entity.FormSubmissionFilter, params *repository.ListParams) ([]*entity.FormSubmission, int64, error) {
+	conditions := "tenant_id = $1"
+	args := []interface{}{tenantID}
+
+	if filter != nil {
+		if filter.FormID != "" {
+			args = append(args, filter.FormID)
+			conditions += fmt.Sprintf(" AND form_id = $%d", len(args))
+		}
+		if filter.ConversationID != "" {
+			args = append(args, filter.ConversationID)
+			conditions += fmt.Sprintf(" AND conversation_id = $%d", len(args))
+		}
+		if filter.Status != "" {
+			args = append(args, string(filter.Status))
+			conditions += fmt.Sprintf(" AND status = $%d", len(args))
+		}
+		if filter.Since != nil {
+			args = append(args, *filter.Since)
+			conditions += fmt.Sprintf(" AND created_at >= $%d", len(args))
+		}
+		if filter.Until != nil {
+			args = append(args, *filter.Until)
+			conditions += fmt.Sprintf(" AND created_at < $%d", len(args))
+		}
+	}
+
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM form_submissions WHERE "+conditions, args...).Scan(&total); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count form submissions")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM form_submissions
+		WHERE %s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, formSubmissionColumns, conditions, len(args)+1, len(args)+2)
+	args = append(args, params.Limit(), params.Offset())
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to list form submissions")
+	}
+	defer rows.Close()
+
+	var submissions []*entity.FormSubmission
+	for rows.Next() {
+		submission, err := r.scanSubmission(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		submissions = append(submissions, submission)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate form submissions")
+	}
+	return submissions, total, nil
+}
+
+// UpdateSubmission updates a submission
+func (r *ConversationFormRepository) UpdateSubmission(ctx context.Context, submission *entity.FormSubmission) error {
+	values, err := json.Marshal(submission.Values)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal submission values")
+	}
+
+	query := `
+		UPDATE form_submissions
+		SET field_values = $2, ai_prefilled_fields = $3, status = $4, submitted_by = $5, submitted_at = $6, updated_at = $7
+		WHERE id = $1
+	`
+	result, err := r.db.Pool.Exec(ctx, query,
+		submission.ID,
+		values,
+		submission.AIPrefilledFields,
+		string(submission.Status),
+		submission.SubmittedBy,
+		submission.SubmittedAt,
+		submission.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update form submission")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "form submission not found")
+	}
+	return nil
+}
+
+// DeleteSubmission deletes a submission
+func (r *ConversationFormRepository) DeleteSubmission(ctx context.Context, id string) error {
+	result, err := r.db.Pool.Exec(ctx, `DELETE FROM form_submissions WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete form submission")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "form submission not found")
+	}
+	return nil
+}
+
+func (r *ConversationFormRepository) scanForm(row pgx.Row) (*entity.ConversationForm, error) {
+	var form entity.ConversationForm
+	var fields []byte
+
+	err := row.Scan(
+		&form.ID,
+		&form.TenantID,
+		&form.Name,
+		&form.Description,
+		&fields,
+		&form.AIPrefill,
+		&form.IsActive,
+		&form.CreatedAt,
+		&form.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "form not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan form")
+	}
+
+	if len(fields) > 0 {
+		if err := json.Unmarshal(fields, &form.Fields); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal form fields")
+		}
+	}
+	return &form, nil
+}
+
+func (r *ConversationFormRepository) scanSubmission(row pgx.Row) (*entity.FormSubmission, error) {
+	var submission entity.FormSubmission
+	var status string
+	var values []byte
+
+	err := row.Scan(
+		&submission.ID,
+		&submission.TenantID,
+		&submission.FormID,
+		&submission.ConversationID,
+		&submission.ContactID,
+		&values,
+		&submission.AIPrefilledFields,
+		&status,
+		&submission.CreatedBy,
+		&submission.SubmittedBy,
+		&submission.SubmittedAt,
+		&submission.CreatedAt,
+		&submission.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "form submission not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan form submission")
+	}
+
+	submission.Status = entity.FormSubmissionStatus(status)
+	submission.Values = make(map[string]interface{})
+	if len(values) > 0 {
+		if err := json.Unmarshal(values, &submission.Values); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal submission values")
+		}
+	}
+	return &submission, nil
+}
@@ -0,0 +1,180 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// ConversationOperationRepository implements repository.ConversationOperationRepository with PostgreSQL
+type ConversationOperationRepository struct {
+	db *PostgresDB
+}
+
+// NewConversationOperationRepository creates a new PostgreSQL conversation operation repository
+func NewConversationOperationRepository(db *PostgresDB) *ConversationOperationRepository {
+	return &ConversationOperationRepository{db: db}
+}
+
+const conversationOperationColumns = `
+	id, tenant_id, type, source_conversation_id, target_conversation_id, message_ids, reason, performed_by, created_at
+`
+
+// Merge moves every message of the source conversation into the target, closes the
+// source and records the operation
+func (r *ConversationOperationRepository) Merge(ctx context.Context, op *entity.ConversationOperation) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to begin merge")
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		UPDATE messages SET conversation_id = $2
+		WHERE conversation_id = $1
+		RETURNING id
+	`, op.SourceConversationID, op.TargetConversationID)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to move messages")
+	}
+	op.MessageIDs, err = pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to move messages")
+	}
+
+	now := time.Now()
+	if _, err := tx.Exec(ctx, `
+		UPDATE conversations SET
+			unread_count = unread_count + (SELECT unread_count FROM conversations WHERE id = $1),
+			updated_at = $3
+		WHERE id = $2
+	`, op.SourceConversationID, op.TargetConversationID, now); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update target conversation")
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE conversations SET status = $2, unread_count = 0, resolved_at = COALESCE(resolved_at, $3), updated_at = $3
+		WHERE id = $1
+	`, op.SourceConversationID, string(entity.ConversationStatusClosed), now); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to close source conversation")
+	}
+
+	if err := r.insert(ctx, tx, op); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to commit merge")
+	}
+	return nil
+}
+
+// Split creates the new conversation, moves the operation's messages into it from the
+// source and records the operation
+func (r *ConversationOperationRepository) Split(ctx context.Context, op *entity.ConversationOperation, conversation *entity.Conversation) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to begin split")
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO conversations (
+			id, tenant_id, channel_id, contact_id, assignee_id, status, priority,
+			subject, unread_count, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`,
+		conversation.ID,
+		conversation.TenantID,
+		conversation.ChannelID,
+		conversation.ContactID,
+		conversation.AssignedUserID,
+		string(conversation.Status),
+		string(conversation.Priority),
+		nullString(conversation.Subject),
+		conversation.UnreadCount,
+		conversation.CreatedAt,
+		conversation.UpdatedAt,
+	); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create conversation")
+	}
+
+	tag, err := tx.Exec(ctx, `
+		UPDATE messages SET conversation_id = $2
+		WHERE conversation_id = $1 AND id = ANY($3)
+	`, op.SourceConversationID, op.TargetConversationID, op.MessageIDs)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to move messages")
+	}
+	if tag.RowsAffected() != int64(len(op.MessageIDs)) {
+		return errors.Validation("some messages do not belong to the conversation")
+	}
+
+	if err := r.insert(ctx, tx, op); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to commit split")
+	}
+	return nil
+}
+
+// ListByConversation lists the operations involving a conversation, newest first
+func (r *ConversationOperationRepository) ListByConversation(ctx context.Context, conversationID string) ([]*entity.ConversationOperation, error) {
+	query := `SELECT ` + conversationOperationColumns + ` FROM conversation_operations
+		WHERE source_conversation_id = $1 OR target_conversation_id = $1
+		ORDER BY created_at DESC`
+	rows, err := r.db.Pool.Query(ctx, query, conversationID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list conversation operations")
+	}
+	defer rows.Close()
+
+	var operations []*entity.ConversationOperation
+	for rows.Next() {
+		var op entity.ConversationOperation
+		var opType string
+		if err := rows.Scan(
+			&op.ID,
+			&op.TenantID,
+			&opType,
+			&op.SourceConversationID,
+			&op.TargetConversationID,
+			&op.MessageIDs,
+			&op.Reason,
+			&op.PerformedBy,
+			&op.CreatedAt,
+		); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan conversation operation")
+		}
+		op.Type = entity.ConversationOperationType(opType)
+		operations = append(operations, &op)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate conversation operations")
+	}
+	return operations, nil
+}
+
+func (r *ConversationOperationRepository) insert(ctx context.Context, tx pgx.Tx, op *entity.ConversationOperation) error {
+	query := `INSERT INTO conversation_operations (` + conversationOperationColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	if _, err := tx.Exec(ctx, query,
+		op.ID,
+		op.TenantID,
+		string(op.Type),
+		op.SourceConversationID,
+		op.TargetConversationID,
+		op.MessageIDs,
+		op.Reason,
+		op.PerformedBy,
+		op.CreatedAt,
+	); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to record conversation operation")
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// ConversationParticipantRepository implements repository.ConversationParticipantRepository with PostgreSQL
+type ConversationParticipantRepository struct {
+	db *PostgresDB
+}
+
+// NewConversationParticipantRepository creates a new PostgreSQL conversation participant repository
+func NewConversationParticipantRepository(db *PostgresDB) *ConversationParticipantRepository {
+	return &ConversationParticipantRepository{db: db}
+}
+
+const conversationParticipantColumns = `id, tenant_id, conversation_id, contact_id, identifier, status, added_by, joined_at, left_at`
+
+// Upsert adds a participant to a conversation, reactivating them when they were removed
+func (r *ConversationParticipantRepository) Upsert(ctx context.Context, participant *entity.ConversationParticipant) error {
+	query := `
+		INSERT INTO conversation_participants (` + conversationParticipantColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (conversation_id, contact_id) DO UPDATE SET
+			identifier = EXCLUDED.identifier,
+			status = EXCLUDED.status,
+			added_by = CASE WHEN conversation_participants.status = EXCLUDED.status
+				THEN conversation_participants.added_by ELSE EXCLUDED.added_by END,
+			joined_at = CASE WHEN conversation_participants.status = EXCLUDED.status
+				THEN conversation_participants.joined_at ELSE EXCLUDED.joined_at END,
+			left_at = NULL
+		RETURNING id, added_by, joined_at
+	`
+	err := r.db.Pool.QueryRow(ctx, query,
+		participant.ID,
+		participant.TenantID,
+		participant.ConversationID,
+		participant.ContactID,
+		participant.Identifier,
+		participant.Status,
+		participant.AddedBy,
+		participant.JoinedAt,
+		participant.LeftAt,
+	).Scan(&participant.ID, &participant.AddedBy, &participant.JoinedAt)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to save conversation participant")
+	}
+	return nil
+}
+
+// FindByConversation lists the participants of a conversation in the order they joined
+func (r *ConversationParticipantRepository) FindByConversation(ctx context.Context, conversationID string) ([]*entity.ConversationParticipant, error) {
+	query := `SELECT ` + conversationParticipantColumns + ` FROM conversation_participants
+		WHERE conversation_id = $1 ORDER BY joined_at, id`
+	rows, err := r.db.Pool.Query(ctx, query, conversationID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find conversation participants")
+	}
+	defer rows.Close()
+
+	var participants []*entity.ConversationParticipant
+	for rows.Next() {
+		participant, err := scanConversationParticipant(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan conversation participant")
+		}
+		participants = append(participants, participant)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate conversation participants")
+	}
+	return participants, nil
+}
+
+// FindByIdentifier finds a participant by their address on the channel
+func (r *ConversationParticipantRepository) FindByIdentifier(ctx context.Context, conversationID, identifier string) (*entity.ConversationParticipant, error) {
+	query := `SELECT ` + conversationParticipantColumns + ` FROM conversation_participants
+		WHERE conversation_id = $1 AND identifier = $2`
+	participant, err := scanConversationParticipant(r.db.Pool.QueryRow(ctx, query, conversationID, identifier))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "participant not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find conversation participant")
+	}
+	return participant, nil
+}
+
+// Remove marks a participant as removed from a conversation
+func (r *ConversationParticipantRepository) Remove(ctx context.Context, conversationID, contactID string, at time.Time) error {
+	query := `
+		UPDATE conversation_participants SET status = $3, left_at = $4
+		WHERE conversation_id = $1 AND contact_id = $2 AND status = $5
+	`
+	result, err := r.db.Pool.Exec(ctx, query, conversationID, contactID, entity.ParticipantStatusRemoved, at, entity.ParticipantStatusActive)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to remove conversation participant")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "participant not found")
+	}
+	return nil
+}
+
+// FindRecipient finds the delivery status of a message for a participant
+func (r *ConversationParticipantRepository) FindRecipient(ctx context.Context, messageID, identifier string) (*entity.MessageRecipient, error) {
+	query := `
+		SELECT message_id, COALESCE(contact_id::text, ''), identifier, status, error_message, updated_at
+		FROM message_recipients
+		WHERE message_id = $1 AND identifier = $2
+	`
+	var recipient entity.MessageRecipient
+	err := r.db.Pool.QueryRow(ctx, query, messageID, identifier).Scan(
+		&recipient.MessageID,
+		&recipient.ContactID,
+		&recipient.Identifier,
+		&recipient.Status,
+		&recipient.ErrorMessage,
+		&recipient.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "message recipient not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find message recipient")
+	}
+	return &recipient, nil
+}
+
+// SaveRecipient creates or updates the delivery status of a message for a participant
+func (r *ConversationParticipantRepository) SaveRecipient(ctx context.Context, recipient *entity.MessageRecipient) error {
+	query := `
+		INSERT INTO message_recipients (message_id, contact_id, identifier, status, error_message, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (message_id, identifier) DO UPDATE SET
+			contact_id = EXCLUDED.contact_id,
+			status = EXCLUDED.status,
+			error_message = EXCLUDED.error_message,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err := r.db.Pool.Exec(ctx, query,
+		recipient.MessageID,
+		nullString(recipient.ContactID),
+		recipient.Identifier,
+		recipient.Status,
+		recipient.ErrorMessage,
+		recipient.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to save message recipient")
+	}
+	return nil
+}
+
+// FindRecipients lists the delivery status of a message per participant
+func (r *ConversationParticipantRepository) FindRecipients(ctx context.Context, messageID string) ([]*entity.MessageRecipient, error) {
+	query := `
+		SELECT message_id, COALESCE(contact_id::text, ''), identifier, status, error_message, updated_at
+		FROM message_recipients
+		WHERE message_id = $1
+		ORDER BY identifier
+	`
+	rows, err := r.db.Pool.Query(ctx, query, messageID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find message recipients")
+	}
+	defer rows.Close()
+
+	var recipients []*entity.MessageRecipient
+	for rows.Next() {
+		var recipient entity.MessageRecipient
+		if err := rows.Scan(
+			&recipient.MessageID,
+			&recipient.ContactID,
+			&recipient.Identifier,
+			&recipient.Status,
+			&recipient.ErrorMessage,
+			&recipient.UpdatedAt,
+		); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan message recipient")
+		}
+		recipients = append(recipients, &recipient)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate message recipients")
+	}
+	return recipients, nil
+}
+
+func scanConversationParticipant(row pgx.Row) (*entity.ConversationParticipant, error) {
+	var participant entity.ConversationParticipant
+	err := row.Scan(
+		&participant.ID,
+		&participant.TenantID,
+		&participant.ConversationID,
+		&participant.ContactID,
+		&participant.Identifier,
+		&participant.Status,
+		&participant.AddedBy,
+		&participant.JoinedAt,
+		&participant.LeftAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &participant, nil
+}
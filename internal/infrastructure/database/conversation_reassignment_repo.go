@@ -0,0 +1,126 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// ConversationReassignmentRepository implements repository.ConversationReassignmentRepository with PostgreSQL
+type ConversationReassignmentRepository struct {
+	db *PostgresDB
+}
+
+// NewConversationReassignmentRepository creates a new PostgreSQL conversation reassignment repository
+func NewConversationReassignmentRepository(db *PostgresDB) *ConversationReassignmentRepository {
+	return &ConversationReassignmentRepository{db: db}
+}
+
+const conversationReassignmentColumns = `
+	id, tenant_id, conversation_id, from_user_id, COALESCE(to_user_id::text, ''), reason,
+	routing_rule_id, unavailable_since, created_at
+`
+
+// Create records a reassignment
+func (r *ConversationReassignmentRepository) Create(ctx context.Context, reassignment *entity.ConversationReassignment) error {
+	query := `
+		INSERT INTO conversation_reassignments (
+			id, tenant_id, conversation_id, from_user_id, to_user_id, reason,
+			routing_rule_id, unavailable_since, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := r.db.Pool.Exec(ctx, query,
+		reassignment.ID,
+		reassignment.TenantID,
+		reassignment.ConversationID,
+		reassignment.FromUserID,
+		nullString(reassignment.ToUserID),
+		string(reassignment.Reason),
+		reassignment.RoutingRuleID,
+		reassignment.UnavailableSince,
+		reassignment.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create conversation reassignment")
+	}
+	return nil
+}
+
+// ListByTenant lists a tenant's reassignments, newest first
+func (r *ConversationReassignmentRepository) ListByTenant(ctx context.Context, tenantID string, params *repository.ListParams) ([]*entity.ConversationReassignment, int64, error) {
+	if params == nil {
+		params = repository.NewListParams()
+	}
+
+	conditions := "tenant_id = $1"
+	args := []interface{}{tenantID}
+	if userID, ok := params.Filters["user_id"].(string); ok && userID != "" {
+		args = append(args, userID)
+		conditions += fmt.Sprintf(" AND (from_user_id = $%d OR to_user_id = $%d)", len(args), len(args))
+	}
+
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM conversation_reassignments WHERE "+conditions, args...).Scan(&total); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count conversation reassignments")
+	}
+
+	query := fmt.Sprintf(`SELECT `+conversationReassignmentColumns+` FROM conversation_reassignments
+		WHERE %s ORDER BY created_at DESC LIMIT $%d OFFSET $%d`, conditions, len(args)+1, len(args)+2)
+	args = append(args, params.Limit(), params.Offset())
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to list conversation reassignments")
+	}
+	defer rows.Close()
+
+	reassignments, err := scanConversationReassignments(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return reassignments, total, nil
+}
+
+// ListByConversation lists the reassignments of a conversation, newest first
+func (r *ConversationReassignmentRepository) ListByConversation(ctx context.Context, conversationID string) ([]*entity.ConversationReassignment, error) {
+	query := `SELECT ` + conversationReassignmentColumns + ` FROM conversation_reassignments
+		WHERE conversation_id = $1 ORDER BY created_at DESC`
+	rows, err := r.db.Pool.Query(ctx, query, conversationID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list conversation reassignments")
+	}
+	defer rows.Close()
+
+	return scanConversationReassignments(rows)
+}
+
+func scanConversationReassignments(rows pgx.Rows) ([]*entity.ConversationReassignment, error) {
+	var reassignments []*entity.ConversationReassignment
+	for rows.Next() {
+		var reassignment entity.ConversationReassignment
+		var reason string
+		if err := rows.Scan(
+			&reassignment.ID,
+			&reassignment.TenantID,
+			&reassignment.ConversationID,
+			&reassignment.FromUserID,
+			&reassignment.ToUserID,
+			&reason,
+			&reassignment.RoutingRuleID,
+			&reassignment.UnavailableSince,
+			&reassignment.CreatedAt,
+		); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan conversation reassignment")
+		}
+		reassignment.Reason = entity.ReassignmentReason(reason)
+		reassignments = append(reassignments, &reassignment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate conversation reassignments")
+	}
+	return reassignments, nil
+}
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// ConversationReopenRepository implements repository.ConversationReopenRepository with PostgreSQL
+type ConversationReopenRepository struct {
+	db *PostgresDB
+}
+
+// NewConversationReopenRepository creates a new PostgreSQL conversation reopen repository
+func NewConversationReopenRepository(db *PostgresDB) *ConversationReopenRepository {
+	return &ConversationReopenRepository{db: db}
+}
+
+// Create records a reopen
+func (r *ConversationReopenRepository) Create(ctx context.Context, reopen *entity.ConversationReopen) error {
+	query := `
+		INSERT INTO conversation_reopens (id, tenant_id, conversation_id, source, reopened_by, resolved_at, reopened_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := r.db.Pool.Exec(ctx, query,
+		reopen.ID,
+		reopen.TenantID,
+		reopen.ConversationID,
+		string(reopen.Source),
+		reopen.ReopenedBy,
+		reopen.ResolvedAt,
+		reopen.ReopenedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to record conversation reopen")
+	}
+	return nil
+}
+
+// FindByConversation lists the reopens of a conversation, oldest first
+func (r *ConversationReopenRepository) FindByConversation(ctx context.Context, conversationID string) ([]*entity.ConversationReopen, error) {
+	query := `
+		SELECT id, tenant_id, conversation_id, source, reopened_by, resolved_at, reopened_at
+		FROM conversation_reopens
+		WHERE conversation_id = $1
+		ORDER BY reopened_at
+	`
+	rows, err := r.db.Pool.Query(ctx, query, conversationID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list conversation reopens")
+	}
+	defer rows.Close()
+
+	var reopens []*entity.ConversationReopen
+	for rows.Next() {
+		var reopen entity.ConversationReopen
+		var source string
+		if err := rows.Scan(
+			&reopen.ID,
+			&reopen.TenantID,
+			&reopen.ConversationID,
+			&source,
+			&reopen.ReopenedBy,
+			&reopen.ResolvedAt,
+			&reopen.ReopenedAt,
+		); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan conversation reopen")
+		}
+		reopen.Source = entity.ConversationReopenSource(source)
+		reopens = append(reopens, &reopen)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate conversation reopens")
+	}
+	return reopens, nil
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 
@@ -11,6 +12,16 @@ import (
 	"github.com/msgfy/linktor/pkg/errors"
 )
 
+// conversationLabelsColumn selects the labels attached to a conversation as a JSON array
+const conversationLabelsColumn = `COALESCE((
+			SELECT json_agg(json_build_object(
+				'id', l.id, 'tenant_id', l.tenant_id, 'name', l.name, 'color', l.color,
+				'description', l.description, 'created_at', l.created_at, 'updated_at', l.updated_at
+			) ORDER BY l.name)
+			FROM conversation_labels cl JOIN labels l ON l.id = cl.label_id
+			WHERE cl.conversation_id = c.id
+		), '[]') as labels`
+
 // ConversationRepository implements repository.ConversationRepository with PostgreSQL
 type ConversationRepository struct {
 	db *PostgresDB
@@ -26,11 +37,16 @@ func (r *ConversationRepository) Create(ctx context.Context, conversation *entit
 	query := `
 		INSERT INTO conversations (
 			id, tenant_id, channel_id, contact_id, assignee_id, status, priority,
-			subject, unread_count, first_reply_at, resolved_at, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+			subject, unread_count, first_reply_at, resolved_at, created_at, updated_at, metadata
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`
 
-	_, err := r.db.Pool.Exec(ctx, query,
+	metadata, err := marshalConversationMetadata(conversation.Metadata)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Pool.Exec(ctx, query,
 		conversation.ID,
 		conversation.TenantID,
 		conversation.ChannelID,
@@ -44,6 +60,7 @@ func (r *ConversationRepository) Create(ctx context.Context, conversation *entit
 		conversation.ResolvedAt,
 		conversation.CreatedAt,
 		conversation.UpdatedAt,
+		metadata,
 	)
 
 	if err != nil {
@@ -57,8 +74,11 @@ func (r *ConversationRepository) Create(ctx context.Context, conversation *entit
 func (r *ConversationRepository) FindByID(ctx context.Context, id string) (*entity.Conversation, error) {
 	query := `
 		SELECT c.id, c.tenant_id, c.channel_id, c.contact_id, c.assignee_id, c.status, c.priority,
-		       c.subject, c.unread_count, c.first_reply_at, c.resolved_at, c.created_at, c.updated_at,
-		       (SELECT MAX(m.created_at) FROM messages m WHERE m.conversation_id = c.id) as last_message_at
+		       c.subject, c.title, c.title_source, c.title_messages, c.title_updated_at,
+		       c.unread_count, c.first_reply_at, c.resolved_at, c.created_at, c.updated_at,
+		       c.bot_paused_at, c.bot_paused_by, c.metadata,
+		       (SELECT MAX(m.created_at) FROM messages m WHERE m.conversation_id = c.id) as last_message_at,
+		       ` + conversationLabelsColumn + `
 		FROM conversations c
 		WHERE c.id = $1
 	`
@@ -98,10 +118,14 @@ func (r *ConversationRepository) FindByAssignee(ctx context.Context, assigneeID
 func (r *ConversationRepository) FindOpenByContactAndChannel(ctx context.Context, contactID, channelID string) (*entity.Conversation, error) {
 	query := `
 		SELECT c.id, c.tenant_id, c.channel_id, c.contact_id, c.assignee_id, c.status, c.priority,
-		       c.subject, c.unread_count, c.first_reply_at, c.resolved_at, c.created_at, c.updated_at,
-		       (SELECT MAX(m.created_at) FROM messages m WHERE m.conversation_id = c.id) as last_message_at
+		       c.subject, c.title, c.title_source, c.title_messages, c.title_updated_at,
+		       c.unread_count, c.first_reply_at, c.resolved_at, c.created_at, c.updated_at,
+		       c.bot_paused_at, c.bot_paused_by, c.metadata,
+		       (SELECT MAX(m.created_at) FROM messages m WHERE m.conversation_id = c.id) as last_message_at,
+		       ` + conversationLabelsColumn + `
 		FROM conversations c
 		WHERE c.contact_id = $1 AND c.channel_id = $2 AND c.status IN ('open', 'pending')
+		  AND COALESCE(c.metadata->>'group_id', '') = ''
 		ORDER BY c.created_at DESC
 		LIMIT 1
 	`
@@ -117,6 +141,92 @@ func (r *ConversationRepository) FindOpenByContactAndChannel(ctx context.Context
 	return conversation, nil
 }
 
+// FindByGroup finds the latest conversation of a group on a channel
+func (r *ConversationRepository) FindByGroup(ctx context.Context, channelID, groupID string) (*entity.Conversation, error) {
+	query := `
+		SELECT c.id, c.tenant_id, c.channel_id, c.contact_id, c.assignee_id, c.status, c.priority,
+		       c.subject, c.title, c.title_source, c.title_messages, c.title_updated_at,
+		       c.unread_count, c.first_reply_at, c.resolved_at, c.created_at, c.updated_at,
+		       c.bot_paused_at, c.bot_paused_by, c.metadata,
+		       (SELECT MAX(m.created_at) FROM messages m WHERE m.conversation_id = c.id) as last_message_at,
+		       ` + conversationLabelsColumn + `
+		FROM conversations c
+		WHERE c.channel_id = $1 AND c.metadata->>'group_id' = $2
+		ORDER BY c.created_at DESC
+		LIMIT 1
+	`
+
+	conversation, err := r.scanConversation(r.db.Pool.QueryRow(ctx, query, channelID, groupID))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find group conversation")
+	}
+
+	return conversation, nil
+}
+
+// FindLastResolvedByContactAndChannel finds the conversation of a contact on a channel
+// resolved most recently since the given time
+func (r *ConversationRepository) FindLastResolvedByContactAndChannel(ctx context.Context, contactID, channelID string, since time.Time) (*entity.Conversation, error) {
+	query := `
+		SELECT c.id, c.tenant_id, c.channel_id, c.contact_id, c.assignee_id, c.status, c.priority,
+		       c.subject, c.title, c.title_source, c.title_messages, c.title_updated_at,
+		       c.unread_count, c.first_reply_at, c.resolved_at, c.created_at, c.updated_at,
+		       c.bot_paused_at, c.bot_paused_by, c.metadata,
+		       (SELECT MAX(m.created_at) FROM messages m WHERE m.conversation_id = c.id) as last_message_at,
+		       ` + conversationLabelsColumn + `
+		FROM conversations c
+		WHERE c.contact_id = $1 AND c.channel_id = $2 AND c.status = 'resolved' AND c.resolved_at >= $3
+		  AND COALESCE(c.metadata->>'group_id', '') = ''
+		ORDER BY c.resolved_at DESC
+		LIMIT 1
+	`
+
+	conversation, err := r.scanConversation(r.db.Pool.QueryRow(ctx, query, contactID, channelID, since))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find resolved conversation")
+	}
+
+	return conversation, nil
+}
+
+// FindFollowUps finds the conversations that follow up on a resolved conversation
+func (r *ConversationRepository) FindFollowUps(ctx context.Context, conversationID string) ([]*entity.Conversation, error) {
+	query := `
+		SELECT c.id, c.tenant_id, c.channel_id, c.contact_id, c.assignee_id, c.status, c.priority,
+		       c.subject, c.title, c.title_source, c.title_messages, c.title_updated_at,
+		       c.unread_count, c.first_reply_at, c.resolved_at, c.created_at, c.updated_at,
+		       c.bot_paused_at, c.bot_paused_by, c.metadata,
+		       (SELECT MAX(m.created_at) FROM messages m WHERE m.conversation_id = c.id) as last_message_at,
+		       ` + conversationLabelsColumn + `
+		FROM conversations c
+		WHERE c.metadata->>'` + entity.MetadataFollowUpOf + `' = $1
+		ORDER BY c.created_at ASC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, conversationID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find follow-up conversations")
+	}
+	defer rows.Close()
+
+	var conversations []*entity.Conversation
+	for rows.Next() {
+		conversation, err := r.scanConversationFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		conversations = append(conversations, conversation)
+	}
+
+	return conversations, nil
+}
+
 // Update updates a conversation
 func (r *ConversationRepository) Update(ctx context.Context, conversation *entity.Conversation) error {
 	conversation.UpdatedAt = time.Now()
@@ -157,6 +267,28 @@ func (r *ConversationRepository) Update(ctx context.Context, conversation *entit
 	return nil
 }
 
+// SetMetadata sets metadata keys of a conversation, leaving its other keys as they
+// are, so writers of different keys don't overwrite each other
+func (r *ConversationRepository) SetMetadata(ctx context.Context, id string, values map[string]string) error {
+	metadata, err := marshalConversationMetadata(values)
+	if err != nil {
+		return err
+	}
+
+	query := `UPDATE conversations SET metadata = metadata || $1::jsonb, updated_at = $2 WHERE id = $3`
+
+	result, err := r.db.Pool.Exec(ctx, query, metadata, time.Now(), id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update conversation metadata")
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+	}
+
+	return nil
+}
+
 // UpdateStatus updates only the conversation status
 func (r *ConversationRepository) UpdateStatus(ctx context.Context, id string, status entity.ConversationStatus) error {
 	now := time.Now()
@@ -199,6 +331,44 @@ func (r *ConversationRepository) UpdateAssignee(ctx context.Context, id string,
 	return nil
 }
 
+// UpdateBotPause pauses bot automation on behalf of a user, or resumes it when pausedBy is nil
+func (r *ConversationRepository) UpdateBotPause(ctx context.Context, id string, pausedBy *string) error {
+	var pausedAt *time.Time
+	if pausedBy != nil {
+		now := time.Now()
+		pausedAt = &now
+	}
+
+	query := `UPDATE conversations SET bot_paused_at = $1, bot_paused_by = $2, updated_at = $3 WHERE id = $4`
+
+	result, err := r.db.Pool.Exec(ctx, query, pausedAt, pausedBy, time.Now(), id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update conversation bot pause")
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+	}
+
+	return nil
+}
+
+// UpdateTitle sets the title of a conversation and who set it
+func (r *ConversationRepository) UpdateTitle(ctx context.Context, id, title string, source entity.ConversationTitleSource, messageCount int) error {
+	query := `UPDATE conversations SET title = $1, title_source = $2, title_messages = $3, title_updated_at = $4 WHERE id = $5`
+
+	result, err := r.db.Pool.Exec(ctx, query, title, string(source), messageCount, time.Now(), id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update conversation title")
+	}
+
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+	}
+
+	return nil
+}
+
 // IncrementUnreadCount increments the unread message count
 func (r *ConversationRepository) IncrementUnreadCount(ctx context.Context, id string) error {
 	query := `UPDATE conversations SET unread_count = unread_count + 1, updated_at = $1 WHERE id = $2`
@@ -278,6 +448,9 @@ func (r *ConversationRepository) CountByStatus(ctx context.Context, tenantID str
 // Helper methods
 
 func (r *ConversationRepository) findWithFilter(ctx context.Context, whereClause string, args []interface{}, params *repository.ListParams) ([]*entity.Conversation, int64, error) {
+	// Apply filters
+	whereClause, args = applyConversationFilters(whereClause, args, params.Filters)
+
 	// Count total
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM conversations c WHERE %s", whereClause)
 	var total int64
@@ -285,14 +458,14 @@ func (r *ConversationRepository) findWithFilter(ctx context.Context, whereClause
 		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count conversations")
 	}
 
-	// Apply filters
-	whereClause, args = applyConversationFilters(whereClause, args, params.Filters)
-
 	// Get conversations with last_message_at computed via subquery
 	query := fmt.Sprintf(`
 		SELECT c.id, c.tenant_id, c.channel_id, c.contact_id, c.assignee_id, c.status, c.priority,
-		       c.subject, c.unread_count, c.first_reply_at, c.resolved_at, c.created_at, c.updated_at,
-		       (SELECT MAX(m.created_at) FROM messages m WHERE m.conversation_id = c.id) as last_message_at
+		       c.subject, c.title, c.title_source, c.title_messages, c.title_updated_at,
+		       c.unread_count, c.first_reply_at, c.resolved_at, c.created_at, c.updated_at,
+		       c.bot_paused_at, c.bot_paused_by, c.metadata,
+		       (SELECT MAX(m.created_at) FROM messages m WHERE m.conversation_id = c.id) as last_message_at,
+		       `+conversationLabelsColumn+`
 		FROM conversations c
 		WHERE %s
 		ORDER BY %s %s
@@ -322,12 +495,14 @@ func (r *ConversationRepository) findWithFilter(ctx context.Context, whereClause
 func (r *ConversationRepository) scanConversation(row pgx.Row) (*entity.Conversation, error) {
 	var c entity.Conversation
 	var assigneeID, subject *string
-	var status, priority string
+	var status, priority, titleSource string
+	var labels, metadata []byte
 
 	err := row.Scan(
 		&c.ID, &c.TenantID, &c.ChannelID, &c.ContactID, &assigneeID, &status, &priority,
-		&subject, &c.UnreadCount, &c.FirstReplyAt, &c.ResolvedAt, &c.CreatedAt, &c.UpdatedAt,
-		&c.LastMessageAt,
+		&subject, &c.Title, &titleSource, &c.TitleMessages, &c.TitleUpdatedAt,
+		&c.UnreadCount, &c.FirstReplyAt, &c.ResolvedAt, &c.CreatedAt, &c.UpdatedAt,
+		&c.BotPausedAt, &c.BotPausedBy, &metadata, &c.LastMessageAt, &labels,
 	)
 	if err != nil {
 		return nil, err
@@ -335,11 +510,20 @@ func (r *ConversationRepository) scanConversation(row pgx.Row) (*entity.Conversa
 
 	c.Status = entity.ConversationStatus(status)
 	c.Priority = entity.ConversationPriority(priority)
+	c.TitleSource = entity.ConversationTitleSource(titleSource)
 	c.AssignedUserID = assigneeID
 
 	if subject != nil {
 		c.Subject = *subject
 	}
+	if err := setConversationLabels(&c, labels); err != nil {
+		return nil, err
+	}
+	if len(metadata) > 0 {
+		if err := json.Unmarshal(metadata, &c.Metadata); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal conversation metadata")
+		}
+	}
 
 	return &c, nil
 }
@@ -347,12 +531,14 @@ func (r *ConversationRepository) scanConversation(row pgx.Row) (*entity.Conversa
 func (r *ConversationRepository) scanConversationFromRows(rows pgx.Rows) (*entity.Conversation, error) {
 	var c entity.Conversation
 	var assigneeID, subject *string
-	var status, priority string
+	var status, priority, titleSource string
+	var labels, metadata []byte
 
 	err := rows.Scan(
 		&c.ID, &c.TenantID, &c.ChannelID, &c.ContactID, &assigneeID, &status, &priority,
-		&subject, &c.UnreadCount, &c.FirstReplyAt, &c.ResolvedAt, &c.CreatedAt, &c.UpdatedAt,
-		&c.LastMessageAt,
+		&subject, &c.Title, &titleSource, &c.TitleMessages, &c.TitleUpdatedAt,
+		&c.UnreadCount, &c.FirstReplyAt, &c.ResolvedAt, &c.CreatedAt, &c.UpdatedAt,
+		&c.BotPausedAt, &c.BotPausedBy, &metadata, &c.LastMessageAt, &labels,
 	)
 	if err != nil {
 		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan conversation")
@@ -360,15 +546,41 @@ func (r *ConversationRepository) scanConversationFromRows(rows pgx.Rows) (*entit
 
 	c.Status = entity.ConversationStatus(status)
 	c.Priority = entity.ConversationPriority(priority)
+	c.TitleSource = entity.ConversationTitleSource(titleSource)
 	c.AssignedUserID = assigneeID
 
 	if subject != nil {
 		c.Subject = *subject
 	}
+	if err := setConversationLabels(&c, labels); err != nil {
+		return nil, err
+	}
+	if len(metadata) > 0 {
+		if err := json.Unmarshal(metadata, &c.Metadata); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal conversation metadata")
+		}
+	}
 
 	return &c, nil
 }
 
+// setConversationLabels decodes the labels column and mirrors the label names in Tags
+func setConversationLabels(c *entity.Conversation, data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, &c.Labels); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to decode conversation labels")
+	}
+	if len(c.Labels) > 0 {
+		c.Tags = make([]string, 0, len(c.Labels))
+		for _, label := range c.Labels {
+			c.Tags = append(c.Tags, label.Name)
+		}
+	}
+	return nil
+}
+
 func sanitizeConversationColumn(col string) string {
 	allowed := map[string]string{
 		"created_at":      "c.created_at",
@@ -402,6 +614,17 @@ func applyConversationFilters(whereClause string, args []interface{}, filters ma
 			whereClause += fmt.Sprintf(" AND c.assignee_id = $%d", len(args))
 		}
 	}
+	if search, ok := filters["search"].(string); ok && search != "" {
+		args = append(args, "%"+search+"%")
+		whereClause += fmt.Sprintf(" AND (c.title ILIKE $%d OR c.subject ILIKE $%d)", len(args), len(args))
+	}
+	// Conversations must carry every requested label
+	if labelIDs, ok := filters["label_ids"].([]string); ok {
+		for _, labelID := range labelIDs {
+			args = append(args, labelID)
+			whereClause += fmt.Sprintf(" AND EXISTS (SELECT 1 FROM conversation_labels cl WHERE cl.conversation_id = c.id AND cl.label_id = $%d)", len(args))
+		}
+	}
 	return whereClause, args
 }
 
@@ -422,6 +645,40 @@ func (r *ConversationRepository) CountActiveByUser(ctx context.Context, userID s
 	return count, nil
 }
 
+// FindAwaitingFirstReply finds assigned open or pending conversations no agent has
+// replied to yet, across tenants, oldest first
+func (r *ConversationRepository) FindAwaitingFirstReply(ctx context.Context, limit int) ([]*entity.Conversation, error) {
+	query := `
+		SELECT c.id, c.tenant_id, c.channel_id, c.contact_id, c.assignee_id, c.status, c.priority,
+		       c.subject, c.title, c.title_source, c.title_messages, c.title_updated_at,
+		       c.unread_count, c.first_reply_at, c.resolved_at, c.created_at, c.updated_at,
+		       c.bot_paused_at, c.bot_paused_by, c.metadata,
+		       (SELECT MAX(m.created_at) FROM messages m WHERE m.conversation_id = c.id) as last_message_at,
+		       ` + conversationLabelsColumn + `
+		FROM conversations c
+		WHERE c.assignee_id IS NOT NULL AND c.first_reply_at IS NULL AND c.status IN ('open', 'pending')
+		ORDER BY c.created_at
+		LIMIT $1
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to query conversations")
+	}
+	defer rows.Close()
+
+	var conversations []*entity.Conversation
+	for rows.Next() {
+		conversation, err := r.scanConversationFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		conversations = append(conversations, conversation)
+	}
+
+	return conversations, nil
+}
+
 // CountWaiting counts waiting (pending) conversations with given or higher priority
 func (r *ConversationRepository) CountWaiting(ctx context.Context, tenantID string, minPriority entity.ConversationPriority) (int64, error) {
 	// Priority order: urgent > high > normal > low
@@ -462,3 +719,15 @@ func (r *ConversationRepository) CountWaiting(ctx context.Context, tenantID stri
 
 	return count, nil
 }
+
+// marshalConversationMetadata encodes conversation metadata for the metadata column
+func marshalConversationMetadata(metadata map[string]string) ([]byte, error) {
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal conversation metadata")
+	}
+	return data, nil
+}
@@ -0,0 +1,163 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// ConversationSummaryRepository implements repository.ConversationSummaryRepository with PostgreSQL
+type ConversationSummaryRepository struct {
+	db *PostgresDB
+}
+
+// NewConversationSummaryRepository creates a new PostgreSQL conversation summary repository
+func NewConversationSummaryRepository(db *PostgresDB) *ConversationSummaryRepository {
+	return &ConversationSummaryRepository{db: db}
+}
+
+// Upsert stores the summary of a conversation, replacing the previous one
+func (r *ConversationSummaryRepository) Upsert(ctx context.Context, summary *entity.ConversationSummary) error {
+	query := `
+		INSERT INTO conversation_summaries (
+			conversation_id, tenant_id, issue, resolution, sentiment, follow_ups, triggered_by,
+			generated_by, messages, provider, model, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (conversation_id) DO UPDATE SET
+			issue = EXCLUDED.issue,
+			resolution = EXCLUDED.resolution,
+			sentiment = EXCLUDED.sentiment,
+			follow_ups = EXCLUDED.follow_ups,
+			triggered_by = EXCLUDED.triggered_by,
+			generated_by = EXCLUDED.generated_by,
+			messages = EXCLUDED.messages,
+			provider = EXCLUDED.provider,
+			model = EXCLUDED.model,
+			created_at = EXCLUDED.created_at,
+			embedding = NULL
+	`
+	followUps := summary.FollowUps
+	if followUps == nil {
+		followUps = []string{}
+	}
+	_, err := r.db.Pool.Exec(ctx, query,
+		summary.ConversationID,
+		summary.TenantID,
+		summary.Issue,
+		summary.Resolution,
+		string(summary.Sentiment),
+		followUps,
+		string(summary.Trigger),
+		summary.GeneratedBy,
+		summary.Messages,
+		summary.Provider,
+		summary.Model,
+		summary.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to save conversation summary")
+	}
+	return nil
+}
+
+// FindByConversation finds the summary of a conversation
+func (r *ConversationSummaryRepository) FindByConversation(ctx context.Context, conversationID string) (*entity.ConversationSummary, error) {
+	query := `
+		SELECT conversation_id, tenant_id, issue, resolution, sentiment, follow_ups, triggered_by,
+			generated_by, messages, provider, model, created_at
+		FROM conversation_summaries
+		WHERE conversation_id = $1
+	`
+
+	var summary entity.ConversationSummary
+	var sentiment, trigger string
+	err := r.db.Pool.QueryRow(ctx, query, conversationID).Scan(
+		&summary.ConversationID,
+		&summary.TenantID,
+		&summary.Issue,
+		&summary.Resolution,
+		&sentiment,
+		&summary.FollowUps,
+		&trigger,
+		&summary.GeneratedBy,
+		&summary.Messages,
+		&summary.Provider,
+		&summary.Model,
+		&summary.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "conversation summary not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find conversation summary")
+	}
+
+	summary.Sentiment = entity.Sentiment(sentiment)
+	summary.Trigger = entity.ConversationSummaryTrigger(trigger)
+	return &summary, nil
+}
+
+// UpdateEmbedding stores the embedding of a summary's issue
+func (r *ConversationSummaryRepository) UpdateEmbedding(ctx context.Context, conversationID string, embedding []float64) error {
+	query := `UPDATE conversation_summaries SET embedding = $2 WHERE conversation_id = $1`
+	tag, err := r.db.Pool.Exec(ctx, query, conversationID, embedding)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update conversation summary embedding")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "conversation summary not found")
+	}
+	return nil
+}
+
+// ListResolvedWithEmbedding lists the embedded summaries of a tenant's resolved
+// conversations, newest first
+func (r *ConversationSummaryRepository) ListResolvedWithEmbedding(ctx context.Context, tenantID string, limit int) ([]*entity.ConversationSummary, error) {
+	query := `
+		SELECT s.conversation_id, s.tenant_id, s.issue, s.resolution, s.sentiment, s.follow_ups,
+			s.triggered_by, s.generated_by, s.messages, s.provider, s.model, s.created_at, s.embedding
+		FROM conversation_summaries s
+		JOIN conversations c ON c.id = s.conversation_id
+		WHERE s.tenant_id = $1 AND s.embedding IS NOT NULL AND c.status = 'resolved'
+		ORDER BY s.created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, tenantID, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list conversation summaries")
+	}
+	defer rows.Close()
+
+	summaries := []*entity.ConversationSummary{}
+	for rows.Next() {
+		var summary entity.ConversationSummary
+		var sentiment, trigger string
+		if err := rows.Scan(
+			&summary.ConversationID,
+			&summary.TenantID,
+			&summary.Issue,
+			&summary.Resolution,
+			&sentiment,
+			&summary.FollowUps,
+			&trigger,
+			&summary.GeneratedBy,
+			&summary.Messages,
+			&summary.Provider,
+			&summary.Model,
+			&summary.CreatedAt,
+			&summary.Embedding,
+		); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan conversation summary")
+		}
+		summary.Sentiment = entity.Sentiment(sentiment)
+		summary.Trigger = entity.ConversationSummaryTrigger(trigger)
+		summaries = append(summaries, &summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate conversation summaries")
+	}
+	return summaries, nil
+}
@@ -0,0 +1,180 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// CopilotRepository implements repository.CopilotRepository with PostgreSQL
+type CopilotRepository struct {
+	db *PostgresDB
+}
+
+// NewCopilotRepository creates a new PostgreSQL copilot repository
+func NewCopilotRepository(db *PostgresDB) *CopilotRepository {
+	return &CopilotRepository{db: db}
+}
+
+const copilotSuggestionColumns = `
+	id, tenant_id, conversation_id, message_id, agent_id, type, title, content, knowledge_base_id,
+	knowledge_item_id, score, status, final_content, responded_by, responded_at, created_at,
+	source_conversation_id
+`
+
+// Create stores a suggestion
+func (r *CopilotRepository) Create(ctx context.Context, suggestion *entity.CopilotSuggestion) error {
+	query := `INSERT INTO copilot_suggestions (` + copilotSuggestionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)`
+	_, err := r.db.Pool.Exec(ctx, query,
+		suggestion.ID,
+		suggestion.TenantID,
+		suggestion.ConversationID,
+		suggestion.MessageID,
+		suggestion.AgentID,
+		string(suggestion.Type),
+		suggestion.Title,
+		suggestion.Content,
+		suggestion.KnowledgeBaseID,
+		suggestion.KnowledgeItemID,
+		suggestion.Score,
+		string(suggestion.Status),
+		suggestion.FinalContent,
+		suggestion.RespondedBy,
+		suggestion.RespondedAt,
+		suggestion.CreatedAt,
+		suggestion.SourceConversationID,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create copilot suggestion")
+	}
+	return nil
+}
+
+// FindByID finds a suggestion by ID
+func (r *CopilotRepository) FindByID(ctx context.Context, id string) (*entity.CopilotSuggestion, error) {
+	query := `SELECT ` + copilotSuggestionColumns + ` FROM copilot_suggestions WHERE id = $1`
+	return r.scanSuggestion(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// ListByConversation lists the suggestions made in a conversation, newest first
+func (r *CopilotRepository) ListByConversation(ctx context.Context, conversationID string, limit int) ([]*entity.CopilotSuggestion, error) {
+	query := `SELECT ` + copilotSuggestionColumns + ` FROM copilot_suggestions
+		WHERE conversation_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2`
+
+	rows, err := r.db.Pool.Query(ctx, query, conversationID, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list copilot suggestions")
+	}
+	defer rows.Close()
+
+	suggestions := []*entity.CopilotSuggestion{}
+	for rows.Next() {
+		suggestion, err := r.scanSuggestion(rows)
+		if err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate copilot suggestions")
+	}
+	return suggestions, nil
+}
+
+// Update records what the agent did with a suggestion
+func (r *CopilotRepository) Update(ctx context.Context, suggestion *entity.CopilotSuggestion) error {
+	query := `
+		UPDATE copilot_suggestions
+		SET status = $2, final_content = $3, responded_by = $4, responded_at = $5
+		WHERE id = $1
+	`
+	tag, err := r.db.Pool.Exec(ctx, query,
+		suggestion.ID,
+		string(suggestion.Status),
+		suggestion.FinalContent,
+		suggestion.RespondedBy,
+		suggestion.RespondedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update copilot suggestion")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "copilot suggestion not found")
+	}
+	return nil
+}
+
+// Stats counts the suggestions of a tenant made in a period and their outcomes by type
+func (r *CopilotRepository) Stats(ctx context.Context, tenantID string, startDate, endDate time.Time) ([]*entity.CopilotStats, error) {
+	query := `
+		SELECT type,
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'accepted'),
+			COUNT(*) FILTER (WHERE status = 'edited'),
+			COUNT(*) FILTER (WHERE status = 'rejected'),
+			COUNT(*) FILTER (WHERE status = 'pending')
+		FROM copilot_suggestions
+		WHERE tenant_id = $1 AND created_at >= $2 AND created_at <= $3
+		GROUP BY type
+		ORDER BY type
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, tenantID, startDate, endDate)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to count copilot suggestions")
+	}
+	defer rows.Close()
+
+	stats := []*entity.CopilotStats{}
+	for rows.Next() {
+		var s entity.CopilotStats
+		var suggestionType string
+		if err := rows.Scan(&suggestionType, &s.Suggestions, &s.Accepted, &s.Edited, &s.Rejected, &s.Pending); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan copilot stats")
+		}
+		s.Type = entity.CopilotSuggestionType(suggestionType)
+		stats = append(stats, &s)
+	}
+	return stats, nil
+}
+
+func (r *CopilotRepository) scanSuggestion(row pgx.Row) (*entity.CopilotSuggestion, error) {
+	var suggestion entity.CopilotSuggestion
+	var suggestionType, status string
+
+	err := row.Scan(
+		&suggestion.ID,
+		&suggestion.TenantID,
+		&suggestion.ConversationID,
+		&suggestion.MessageID,
+		&suggestion.AgentID,
+		&suggestionType,
+		&suggestion.Title,
+		&suggestion.Content,
+		&suggestion.KnowledgeBaseID,
+		&suggestion.KnowledgeItemID,
+		&suggestion.Score,
+		&status,
+		&suggestion.FinalContent,
+		&suggestion.RespondedBy,
+		&suggestion.RespondedAt,
+		&suggestion.CreatedAt,
+		&suggestion.SourceConversationID,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "copilot suggestion not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan copilot suggestion")
+	}
+
+	suggestion.Type = entity.CopilotSuggestionType(suggestionType)
+	suggestion.Status = entity.CopilotSuggestionStatus(status)
+	return &suggestion, nil
+}
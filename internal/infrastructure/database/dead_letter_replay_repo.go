@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// DeadLetterReplayRepository implements repository.DeadLetterReplayRepository with PostgreSQL
+type DeadLetterReplayRepository struct {
+	db *PostgresDB
+}
+
+// NewDeadLetterReplayRepository creates a new PostgreSQL dead letter replay repository
+func NewDeadLetterReplayRepository(db *PostgresDB) *DeadLetterReplayRepository {
+	return &DeadLetterReplayRepository{db: db}
+}
+
+// Create records a replay
+func (r *DeadLetterReplayRepository) Create(ctx context.Context, replay *entity.DeadLetterReplay) error {
+	query := `
+		INSERT INTO dead_letter_replays (id, stream, subject, dead_letter_sequence, original_sequence, consumer, error, replayed_by, reason, replayed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`
+	_, err := r.db.Pool.Exec(ctx, query,
+		replay.ID,
+		replay.Stream,
+		replay.Subject,
+		int64(replay.DeadLetterSequence),
+		int64(replay.OriginalSequence),
+		replay.Consumer,
+		replay.Error,
+		replay.ReplayedBy,
+		replay.Reason,
+		replay.ReplayedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to record dead letter replay")
+	}
+	return nil
+}
+
+// ListByStream lists the latest replays of a stream, newest first
+func (r *DeadLetterReplayRepository) ListByStream(ctx context.Context, stream string, limit int) ([]*entity.DeadLetterReplay, error) {
+	query := `
+		SELECT id, stream, subject, dead_letter_sequence, original_sequence, consumer, error, replayed_by, reason, replayed_at
+		FROM dead_letter_replays
+		WHERE stream = $1
+		ORDER BY replayed_at DESC
+		LIMIT $2
+	`
+	rows, err := r.db.Pool.Query(ctx, query, stream, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list dead letter replays")
+	}
+	defer rows.Close()
+
+	replays := make([]*entity.DeadLetterReplay, 0)
+	for rows.Next() {
+		var replay entity.DeadLetterReplay
+		var deadLetterSequence, originalSequence int64
+		if err := rows.Scan(
+			&replay.ID,
+			&replay.Stream,
+			&replay.Subject,
+			&deadLetterSequence,
+			&originalSequence,
+			&replay.Consumer,
+			&replay.Error,
+			&replay.ReplayedBy,
+			&replay.Reason,
+			&replay.ReplayedAt,
+		); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan dead letter replay")
+		}
+		replay.DeadLetterSequence = uint64(deadLetterSequence)
+		replay.OriginalSequence = uint64(originalSequence)
+		replays = append(replays, &replay)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate dead letter replays")
+	}
+	return replays, nil
+}
@@ -0,0 +1,215 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// DispositionRepository implements repository.DispositionRepository with PostgreSQL
+type DispositionRepository struct {
+	db *PostgresDB
+}
+
+// NewDispositionRepository creates a new PostgreSQL disposition repository
+func NewDispositionRepository(db *PostgresDB) *DispositionRepository {
+	return &DispositionRepository{db: db}
+}
+
+const dispositionColumns = `id, tenant_id, code, name, category, description, active, position, created_at, updated_at`
+
+// Create creates a new disposition
+func (r *DispositionRepository) Create(ctx context.Context, disposition *entity.Disposition) error {
+	query := `INSERT INTO dispositions (` + dispositionColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+	_, err := r.db.Pool.Exec(ctx, query,
+		disposition.ID,
+		disposition.TenantID,
+		disposition.Code,
+		disposition.Name,
+		disposition.Category,
+		disposition.Description,
+		disposition.Active,
+		disposition.Position,
+		disposition.CreatedAt,
+		disposition.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create disposition")
+	}
+	return nil
+}
+
+// FindByID finds a disposition by ID
+func (r *DispositionRepository) FindByID(ctx context.Context, id string) (*entity.Disposition, error) {
+	query := `SELECT ` + dispositionColumns + ` FROM dispositions WHERE id = $1`
+	return r.scanDisposition(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// FindByCode finds a disposition of a tenant by code
+func (r *DispositionRepository) FindByCode(ctx context.Context, tenantID, code string) (*entity.Disposition, error) {
+	query := `SELECT ` + dispositionColumns + ` FROM dispositions WHERE tenant_id = $1 AND code = $2`
+	return r.scanDisposition(r.db.Pool.QueryRow(ctx, query, tenantID, code))
+}
+
+// FindByTenant lists the dispositions of a tenant by position and name
+func (r *DispositionRepository) FindByTenant(ctx context.Context, tenantID string) ([]*entity.Disposition, error) {
+	query := `SELECT ` + dispositionColumns + ` FROM dispositions WHERE tenant_id = $1 ORDER BY position, name`
+	rows, err := r.db.Pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list dispositions")
+	}
+	defer rows.Close()
+
+	var dispositions []*entity.Disposition
+	for rows.Next() {
+		disposition, err := r.scanDisposition(rows)
+		if err != nil {
+			return nil, err
+		}
+		dispositions = append(dispositions, disposition)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate dispositions")
+	}
+	return dispositions, nil
+}
+
+// Update updates a disposition
+func (r *DispositionRepository) Update(ctx context.Context, disposition *entity.Disposition) error {
+	query := `
+		UPDATE dispositions
+		SET code = $2, name = $3, category = $4, description = $5, active = $6, position = $7, updated_at = $8
+		WHERE id = $1
+	`
+	tag, err := r.db.Pool.Exec(ctx, query,
+		disposition.ID,
+		disposition.Code,
+		disposition.Name,
+		disposition.Category,
+		disposition.Description,
+		disposition.Active,
+		disposition.Position,
+		disposition.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update disposition")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "disposition not found")
+	}
+	return nil
+}
+
+// Delete deletes a disposition
+func (r *DispositionRepository) Delete(ctx context.Context, id string) error {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM dispositions WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete disposition")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "disposition not found")
+	}
+	return nil
+}
+
+// IsUsed reports whether any conversation was resolved with the disposition
+func (r *DispositionRepository) IsUsed(ctx context.Context, id string) (bool, error) {
+	var used bool
+	err := r.db.Pool.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM conversation_dispositions WHERE disposition_id = $1)`,
+		id,
+	).Scan(&used)
+	if err != nil {
+		return false, errors.Wrap(err, errors.ErrCodeInternal, "failed to check disposition usage")
+	}
+	return used, nil
+}
+
+// SaveConversationDisposition stores the disposition of a conversation, replacing
+// the one of an earlier resolution
+func (r *DispositionRepository) SaveConversationDisposition(ctx context.Context, disposition *entity.ConversationDisposition) error {
+	query := `
+		INSERT INTO conversation_dispositions (
+			conversation_id, tenant_id, disposition_id, code, name, notes, resolved_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (conversation_id) DO UPDATE SET
+			disposition_id = EXCLUDED.disposition_id,
+			code = EXCLUDED.code,
+			name = EXCLUDED.name,
+			notes = EXCLUDED.notes,
+			resolved_by = EXCLUDED.resolved_by,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err := r.db.Pool.Exec(ctx, query,
+		disposition.ConversationID,
+		disposition.TenantID,
+		nullString(disposition.DispositionID),
+		disposition.Code,
+		disposition.Name,
+		disposition.Notes,
+		disposition.ResolvedBy,
+		disposition.CreatedAt,
+		disposition.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to save conversation disposition")
+	}
+	return nil
+}
+
+// FindConversationDisposition finds the disposition of a conversation
+func (r *DispositionRepository) FindConversationDisposition(ctx context.Context, conversationID string) (*entity.ConversationDisposition, error) {
+	query := `
+		SELECT conversation_id, tenant_id, disposition_id, code, name, notes, resolved_by, created_at, updated_at
+		FROM conversation_dispositions
+		WHERE conversation_id = $1
+	`
+	var disposition entity.ConversationDisposition
+	var dispositionID *string
+	err := r.db.Pool.QueryRow(ctx, query, conversationID).Scan(
+		&disposition.ConversationID,
+		&disposition.TenantID,
+		&dispositionID,
+		&disposition.Code,
+		&disposition.Name,
+		&disposition.Notes,
+		&disposition.ResolvedBy,
+		&disposition.CreatedAt,
+		&disposition.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "conversation disposition not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find conversation disposition")
+	}
+	if dispositionID != nil {
+		disposition.DispositionID = *dispositionID
+	}
+	return &disposition, nil
+}
+
+func (r *DispositionRepository) scanDisposition(row pgx.Row) (*entity.Disposition, error) {
+	var disposition entity.Disposition
+	err := row.Scan(
+		&disposition.ID,
+		&disposition.TenantID,
+		&disposition.Code,
+		&disposition.Name,
+		&disposition.Category,
+		&disposition.Description,
+		&disposition.Active,
+		&disposition.Position,
+		&disposition.CreatedAt,
+		&disposition.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "disposition not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan disposition")
+	}
+	return &disposition, nil
+}
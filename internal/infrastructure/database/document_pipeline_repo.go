@@ -0,0 +1,266 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// DocumentPipelineRepository implements repository.DocumentPipelineRepository with PostgreSQL
+type DocumentPipelineRepository struct {
+	db *PostgresDB
+}
+
+// NewDocumentPipelineRepository creates a new PostgreSQL document pipeline repository
+func NewDocumentPipelineRepository(db *PostgresDB) *DocumentPipelineRepository {
+	return &DocumentPipelineRepository{db: db}
+}
+
+const documentPipelineColumns = `
+	id, tenant_id, name, description, channel_ids, mime_types, document_types,
+	ai_extraction, metadata_prefix, priority, is_active, created_at, updated_at
+`
+
+const documentExtractionColumns = `
+	id, tenant_id, pipeline_id, conversation_id, message_id, attachment_id, filename, mime_type,
+	document_type, fields, ai_fields, text, status, error, flow_id, created_at
+`
+
+// Create creates a new pipeline
+func (r *DocumentPipelineRepository) Create(ctx context.Context, pipeline *entity.DocumentPipeline) error {
+	documentTypes, err := json.Marshal(pipeline.DocumentTypes)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal document types")
+	}
+
+	query := `INSERT INTO document_pipelines (` + documentPipelineColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+	_, err = r.db.Pool.Exec(ctx, query,
+		pipeline.ID,
+		pipeline.TenantID,
+		pipeline.Name,
+		pipeline.Description,
+		pipeline.ChannelIDs,
+		pipeline.MimeTypes,
+		documentTypes,
+		pipeline.AIExtraction,
+		pipeline.MetadataPrefix,
+		pipeline.Priority,
+		pipeline.IsActive,
+		pipeline.CreatedAt,
+		pipeline.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create document pipeline")
+	}
+	return nil
+}
+
+// FindByID finds a pipeline by ID
+func (r *DocumentPipelineRepository) FindByID(ctx context.Context, id string) (*entity.DocumentPipeline, error) {
+	query := `SELECT ` + documentPipelineColumns + ` FROM document_pipelines WHERE id = $1`
+	return r.scanPipeline(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// ListByTenant lists the pipelines of a tenant ordered by priority
+func (r *DocumentPipelineRepository) ListByTenant(ctx context.Context, tenantID string) ([]*entity.DocumentPipeline, error) {
+	query := `SELECT ` + documentPipelineColumns + ` FROM document_pipelines WHERE tenant_id = $1 ORDER BY priority, created_at`
+	return r.queryPipelines(ctx, query, tenantID)
+}
+
+// ListActive lists the active pipelines of a tenant ordered by priority
+func (r *DocumentPipelineRepository) ListActive(ctx context.Context, tenantID string) ([]*entity.DocumentPipeline, error) {
+	query := `
+		SELECT ` + documentPipelineColumns + ` FROM document_pipelines
+		WHERE tenant_id = $1 AND is_active = TRUE
+		ORDER BY priority, created_at
+	`
+	return r.queryPipelines(ctx, query, tenantID)
+}
+
+// Update updates a pipeline
+func (r *DocumentPipelineRepository) Update(ctx context.Context, pipeline *entity.DocumentPipeline) error {
+	documentTypes, err := json.Marshal(pipeline.DocumentTypes)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal document types")
+	}
+
+	query := `
+		UPDATE document_pipelines
+		SET name = $2, description = $3, channel_ids = $4, mime_types = $5, document_types = $6,
+		    ai_extraction = $7, metadata_prefix = $8, priority = $9, is_active = $10, updated_at = $11
+		WHERE id = $1
+	`
+	result, err := r.db.Pool.Exec(ctx, query,
+		pipeline.ID,
+		pipeline.Name,
+		pipeline.Description,
+		pipeline.ChannelIDs,
+		pipeline.MimeTypes,
+		documentTypes,
+		pipeline.AIExtraction,
+		pipeline.MetadataPrefix,
+		pipeline.Priority,
+		pipeline.IsActive,
+		pipeline.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update document pipeline")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "document pipeline not found")
+	}
+	return nil
+}
+
+// Delete deletes a pipeline
+func (r *DocumentPipelineRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.Pool.Exec(ctx, `DELETE FROM document_pipelines WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete document pipeline")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "document pipeline not found")
+	}
+	return nil
+}
+
+// CreateExtraction records the result of parsing an attachment
+func (r *DocumentPipelineRepository) CreateExtraction(ctx context.Context, extraction *entity.DocumentExtraction) error {
+	fields, err := json.Marshal(extraction.Fields)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal extracted fields")
+	}
+
+	query := `INSERT INTO document_extractions (` + documentExtractionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`
+	_, err = r.db.Pool.Exec(ctx, query,
+		extraction.ID,
+		extraction.TenantID,
+		extraction.PipelineID,
+		extraction.ConversationID,
+		extraction.MessageID,
+		extraction.AttachmentID,
+		extraction.Filename,
+		extraction.MimeType,
+		extraction.DocumentType,
+		fields,
+		extraction.AIFields,
+		extraction.Text,
+		string(extraction.Status),
+		extraction.Error,
+		extraction.FlowID,
+		extraction.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create document extraction")
+	}
+	return nil
+}
+
+// ListExtractionsByConversation lists the documents parsed on a conversation, newest first
+func (r *DocumentPipelineRepository) ListExtractionsByConversation(ctx context.Context, conversationID string) ([]*entity.DocumentExtraction, error) {
+	query := `SELECT ` + documentExtractionColumns + ` FROM document_extractions WHERE conversation_id = $1 ORDER BY created_at DESC`
+	rows, err := r.db.Pool.Query(ctx, query, conversationID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list document extractions")
+	}
+	defer rows.Close()
+
+	var extractions []*entity.DocumentExtraction
+	for rows.Next() {
+		var extraction entity.DocumentExtraction
+		var status string
+		var fields []byte
+		err := rows.Scan(
+			&extraction.ID,
+			&extraction.TenantID,
+			&extraction.PipelineID,
+			&extraction.ConversationID,
+			&extraction.MessageID,
+			&extraction.AttachmentID,
+			&extraction.Filename,
+			&extraction.MimeType,
+			&extraction.DocumentType,
+			&fields,
+			&extraction.AIFields,
+			&extraction.Text,
+			&status,
+			&extraction.Error,
+			&extraction.FlowID,
+			&extraction.CreatedAt,
+		)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan document extraction")
+		}
+		extraction.Status = entity.DocumentExtractionStatus(status)
+		if len(fields) > 0 {
+			if err := json.Unmarshal(fields, &extraction.Fields); err != nil {
+				return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal extracted fields")
+			}
+		}
+		extractions = append(extractions, &extraction)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate document extractions")
+	}
+	return extractions, nil
+}
+
+func (r *DocumentPipelineRepository) queryPipelines(ctx context.Context, query string, args ...interface{}) ([]*entity.DocumentPipeline, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list document pipelines")
+	}
+	defer rows.Close()
+
+	var pipelines []*entity.DocumentPipeline
+	for rows.Next() {
+		pipeline, err := r.scanPipeline(rows)
+		if err != nil {
+			return nil, err
+		}
+		pipelines = append(pipelines, pipeline)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate document pipelines")
+	}
+	return pipelines, nil
+}
+
+func (r *DocumentPipelineRepository) scanPipeline(row pgx.Row) (*entity.DocumentPipeline, error) {
+	var pipeline entity.DocumentPipeline
+	var documentTypes []byte
+
+	err := row.Scan(
+		&pipeline.ID,
+		&pipeline.TenantID,
+		&pipeline.Name,
+		&pipeline.Description,
+		&pipeline.ChannelIDs,
+		&pipeline.MimeTypes,
+		&documentTypes,
+		&pipeline.AIExtraction,
+		&pipeline.MetadataPrefix,
+		&pipeline.Priority,
+		&pipeline.IsActive,
+		&pipeline.CreatedAt,
+		&pipeline.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "document pipeline not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan document pipeline")
+	}
+
+	if len(documentTypes) > 0 {
+		if err := json.Unmarshal(documentTypes, &pipeline.DocumentTypes); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal document types")
+		}
+	}
+	return &pipeline, nil
+}
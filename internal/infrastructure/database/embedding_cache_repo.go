@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// EmbeddingCacheRepository implements repository.EmbeddingCacheRepository with PostgreSQL
+type EmbeddingCacheRepository struct {
+	db *PostgresDB
+}
+
+// NewEmbeddingCacheRepository creates a new PostgreSQL embedding cache repository
+func NewEmbeddingCacheRepository(db *PostgresDB) *EmbeddingCacheRepository {
+	return &EmbeddingCacheRepository{db: db}
+}
+
+// FindMany returns the cached embeddings of the given hashes; misses are left out
+func (r *EmbeddingCacheRepository) FindMany(ctx context.Context, hashes []string) (map[string][]float64, error) {
+	result := make(map[string][]float64, len(hashes))
+	if len(hashes) == 0 {
+		return result, nil
+	}
+
+	rows, err := r.db.Pool.Query(ctx,
+		`SELECT content_hash, embedding FROM embedding_cache WHERE content_hash = ANY($1)`,
+		hashes,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find cached embeddings")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hash string
+		var embedding []float64
+		if err := rows.Scan(&hash, &embedding); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan cached embedding")
+		}
+		result[hash] = embedding
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate cached embeddings")
+	}
+	return result, nil
+}
+
+// SaveMany caches embeddings generated with a model, keeping existing entries
+func (r *EmbeddingCacheRepository) SaveMany(ctx context.Context, model string, embeddings map[string][]float64) error {
+	if len(embeddings) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for hash, embedding := range embeddings {
+		batch.Queue(
+			`INSERT INTO embedding_cache (content_hash, model, embedding, created_at)
+			 VALUES ($1, $2, $3, NOW())
+			 ON CONFLICT (content_hash) DO NOTHING`,
+			hash, model, embedding,
+		)
+	}
+	if err := r.db.Pool.SendBatch(ctx, batch).Close(); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to cache embeddings")
+	}
+	return nil
+}
+
+// DeleteOlderThan removes entries cached before the given time
+func (r *EmbeddingCacheRepository) DeleteOlderThan(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM embedding_cache WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to prune embedding cache")
+	}
+	return tag.RowsAffected(), nil
+}
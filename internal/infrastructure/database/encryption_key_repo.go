@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// EncryptionKeyRepository implements repository.EncryptionKeyRepository with PostgreSQL
+type EncryptionKeyRepository struct {
+	db *PostgresDB
+}
+
+// NewEncryptionKeyRepository creates a new PostgreSQL encryption key repository
+func NewEncryptionKeyRepository(db *PostgresDB) *EncryptionKeyRepository {
+	return &EncryptionKeyRepository{db: db}
+}
+
+const encryptionKeyColumns = `
+	id, tenant_id, version, provider, kms_key_ref, key_fingerprint, wrapped_key,
+	wrapped_tenant_key, status, created_at, retired_at
+`
+
+// Create creates a new key
+func (r *EncryptionKeyRepository) Create(ctx context.Context, key *entity.TenantEncryptionKey) error {
+	query := `INSERT INTO tenant_encryption_keys (` + encryptionKeyColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+	_, err := r.db.Pool.Exec(ctx, query,
+		key.ID,
+		key.TenantID,
+		key.Version,
+		string(key.Provider),
+		key.KMSKeyRef,
+		key.KeyFingerprint,
+		key.WrappedKey,
+		key.WrappedTenantKey,
+		string(key.Status),
+		key.CreatedAt,
+		key.RetiredAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create encryption key")
+	}
+	return nil
+}
+
+// FindByID finds a key by ID
+func (r *EncryptionKeyRepository) FindByID(ctx context.Context, id string) (*entity.TenantEncryptionKey, error) {
+	query := `SELECT ` + encryptionKeyColumns + ` FROM tenant_encryption_keys WHERE id = $1`
+	return r.scanKey(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// FindActive finds the active key of a tenant
+func (r *EncryptionKeyRepository) FindActive(ctx context.Context, tenantID string) (*entity.TenantEncryptionKey, error) {
+	query := `SELECT ` + encryptionKeyColumns + ` FROM tenant_encryption_keys WHERE tenant_id = $1 AND status = 'active'`
+	return r.scanKey(r.db.Pool.QueryRow(ctx, query, tenantID))
+}
+
+// ListByTenant lists the keys of a tenant, newest version first
+func (r *EncryptionKeyRepository) ListByTenant(ctx context.Context, tenantID string) ([]*entity.TenantEncryptionKey, error) {
+	query := `SELECT ` + encryptionKeyColumns + ` FROM tenant_encryption_keys WHERE tenant_id = $1 ORDER BY version DESC`
+	rows, err := r.db.Pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list encryption keys")
+	}
+	defer rows.Close()
+
+	var keys []*entity.TenantEncryptionKey
+	for rows.Next() {
+		key, err := r.scanKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate encryption keys")
+	}
+	return keys, nil
+}
+
+// Update updates the wrapped key material and status of a key
+func (r *EncryptionKeyRepository) Update(ctx context.Context, key *entity.TenantEncryptionKey) error {
+	query := `
+		UPDATE tenant_encryption_keys
+		SET provider = $2, kms_key_ref = $3, key_fingerprint = $4, wrapped_key = $5,
+		    wrapped_tenant_key = $6, status = $7, retired_at = $8
+		WHERE id = $1
+	`
+	result, err := r.db.Pool.Exec(ctx, query,
+		key.ID,
+		string(key.Provider),
+		key.KMSKeyRef,
+		key.KeyFingerprint,
+		key.WrappedKey,
+		key.WrappedTenantKey,
+		string(key.Status),
+		key.RetiredAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update encryption key")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "encryption key not found")
+	}
+	return nil
+}
+
+func (r *EncryptionKeyRepository) scanKey(row pgx.Row) (*entity.TenantEncryptionKey, error) {
+	var key entity.TenantEncryptionKey
+	var provider, status string
+
+	err := row.Scan(
+		&key.ID,
+		&key.TenantID,
+		&key.Version,
+		&provider,
+		&key.KMSKeyRef,
+		&key.KeyFingerprint,
+		&key.WrappedKey,
+		&key.WrappedTenantKey,
+		&status,
+		&key.CreatedAt,
+		&key.RetiredAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "encryption key not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan encryption key")
+	}
+
+	key.Provider = entity.EncryptionKeyProvider(provider)
+	key.Status = entity.EncryptionKeyStatus(status)
+	return &key, nil
+}
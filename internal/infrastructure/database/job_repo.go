@@ -0,0 +1,239 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// JobRepository implements repository.JobRepository with PostgreSQL
+type JobRepository struct {
+	db *PostgresDB
+}
+
+// NewJobRepository creates a new PostgreSQL job repository
+func NewJobRepository(db *PostgresDB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+const jobColumns = `
+	id, tenant_id, type, status, payload, result, progress, progress_message, error,
+	attempts, max_attempts, created_by, started_at, completed_at, created_at, updated_at
+`
+
+// Create creates a new job
+func (r *JobRepository) Create(ctx context.Context, job *entity.Job) error {
+	payload, err := json.Marshal(job.Payload)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal job payload")
+	}
+	result, err := json.Marshal(job.Result)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal job result")
+	}
+
+	query := `INSERT INTO jobs (` + jobColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`
+	_, err = r.db.Pool.Exec(ctx, query,
+		job.ID,
+		job.TenantID,
+		job.Type,
+		string(job.Status),
+		payload,
+		result,
+		job.Progress,
+		job.ProgressMessage,
+		job.Error,
+		job.Attempts,
+		job.MaxAttempts,
+		job.CreatedBy,
+		job.StartedAt,
+		job.CompletedAt,
+		job.CreatedAt,
+		job.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create job")
+	}
+	return nil
+}
+
+// FindByID finds a job by ID
+func (r *JobRepository) FindByID(ctx context.Context, id string) (*entity.Job, error) {
+	query := `SELECT ` + jobColumns + ` FROM jobs WHERE id = $1`
+	return r.scanJob(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// ListByTenant lists the jobs of a tenant, newest first
+func (r *JobRepository) ListByTenant(ctx context.Context, tenantID string, filter *entity.JobFilter, params *repository.ListParams) ([]*entity.Job, int64, error) {
+	if params == nil {
+		params = repository.NewListParams()
+	}
+
+	conditions := "tenant_id = $1"
+	args := []interface{}{tenantID}
+	if filter != nil {
+		if filter.Type != "" {
+			args = append(args, filter.Type)
+			conditions += fmt.Sprintf(" AND type = $%d", len(args))
+		}
+		if filter.Status != "" {
+			args = append(args, string(filter.Status))
+			conditions += fmt.Sprintf(" AND status = $%d", len(args))
+		}
+	}
+
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM jobs WHERE "+conditions, args...).Scan(&total); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count jobs")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM jobs
+		WHERE %s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, jobColumns, conditions, len(args)+1, len(args)+2)
+	args = append(args, params.Limit(), params.Offset())
+
+	jobs, err := r.queryJobs(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return jobs, total, nil
+}
+
+// ListStale lists pending and running jobs not updated since before, oldest first
+func (r *JobRepository) ListStale(ctx context.Context, before time.Time, limit int) ([]*entity.Job, error) {
+	query := `
+		SELECT ` + jobColumns + ` FROM jobs
+		WHERE status IN ('pending', 'running') AND updated_at < $1
+		ORDER BY updated_at
+		LIMIT $2
+	`
+	return r.queryJobs(ctx, query, before, limit)
+}
+
+// Update updates a job
+func (r *JobRepository) Update(ctx context.Context, job *entity.Job) error {
+	payload, err := json.Marshal(job.Payload)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal job payload")
+	}
+	result, err := json.Marshal(job.Result)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal job result")
+	}
+
+	query := `
+		UPDATE jobs
+		SET status = $2, payload = $3, result = $4, progress = $5, progress_message = $6, error = $7,
+		    attempts = $8, max_attempts = $9, started_at = $10, completed_at = $11, updated_at = $12
+		WHERE id = $1
+	`
+	tag, err := r.db.Pool.Exec(ctx, query,
+		job.ID,
+		string(job.Status),
+		payload,
+		result,
+		job.Progress,
+		job.ProgressMessage,
+		job.Error,
+		job.Attempts,
+		job.MaxAttempts,
+		job.StartedAt,
+		job.CompletedAt,
+		job.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update job")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "job not found")
+	}
+	return nil
+}
+
+// UpdateProgress records the progress of a running job
+func (r *JobRepository) UpdateProgress(ctx context.Context, id string, progress int, message string) error {
+	query := `UPDATE jobs SET progress = $2, progress_message = $3, updated_at = NOW() WHERE id = $1`
+	tag, err := r.db.Pool.Exec(ctx, query, id, progress, message)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update job progress")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "job not found")
+	}
+	return nil
+}
+
+func (r *JobRepository) queryJobs(ctx context.Context, query string, args ...interface{}) ([]*entity.Job, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list jobs")
+	}
+	defer rows.Close()
+
+	var jobs []*entity.Job
+	for rows.Next() {
+		job, err := r.scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate jobs")
+	}
+	return jobs, nil
+}
+
+func (r *JobRepository) scanJob(row pgx.Row) (*entity.Job, error) {
+	var job entity.Job
+	var status string
+	var payload, result []byte
+
+	err := row.Scan(
+		&job.ID,
+		&job.TenantID,
+		&job.Type,
+		&status,
+		&payload,
+		&result,
+		&job.Progress,
+		&job.ProgressMessage,
+		&job.Error,
+		&job.Attempts,
+		&job.MaxAttempts,
+		&job.CreatedBy,
+		&job.StartedAt,
+		&job.CompletedAt,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "job not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan job")
+	}
+
+	job.Status = entity.JobStatus(status)
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, &job.Payload); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal job payload")
+		}
+	}
+	if len(result) > 0 {
+		if err := json.Unmarshal(result, &job.Result); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal job result")
+		}
+	}
+	return &job, nil
+}
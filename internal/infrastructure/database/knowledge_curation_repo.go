@@ -0,0 +1,198 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// KnowledgeCurationRepository implements repository.KnowledgeCurationRepository with PostgreSQL
+type KnowledgeCurationRepository struct {
+	db *PostgresDB
+}
+
+// NewKnowledgeCurationRepository creates a new PostgreSQL knowledge curation repository
+func NewKnowledgeCurationRepository(db *PostgresDB) *KnowledgeCurationRepository {
+	return &KnowledgeCurationRepository{db: db}
+}
+
+const knowledgeCandidateColumns = `
+	id, tenant_id, conversation_id, question, answer, status, edited, knowledge_base_id, item_id,
+	reviewed_by, reviewed_at, created_at, updated_at
+`
+
+// CreateCandidate stores a mined Q&A pair
+func (r *KnowledgeCurationRepository) CreateCandidate(ctx context.Context, candidate *entity.KnowledgeCandidate) error {
+	query := `INSERT INTO knowledge_candidates (` + knowledgeCandidateColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+	_, err := r.db.Pool.Exec(ctx, query,
+		candidate.ID,
+		candidate.TenantID,
+		candidate.ConversationID,
+		candidate.Question,
+		candidate.Answer,
+		string(candidate.Status),
+		candidate.Edited,
+		nullString(candidate.KnowledgeBaseID),
+		candidate.ItemID,
+		candidate.ReviewedBy,
+		candidate.ReviewedAt,
+		candidate.CreatedAt,
+		candidate.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create knowledge candidate")
+	}
+	return nil
+}
+
+// FindCandidateByID finds a candidate by ID
+func (r *KnowledgeCurationRepository) FindCandidateByID(ctx context.Context, id string) (*entity.KnowledgeCandidate, error) {
+	query := `SELECT ` + knowledgeCandidateColumns + ` FROM knowledge_candidates WHERE id = $1`
+	return r.scanCandidate(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// ListCandidates lists the candidates of a tenant, newest first
+func (r *KnowledgeCurationRepository) ListCandidates(ctx context.Context, tenantID string, filter *entity.KnowledgeCandidateFilter, params *repository.ListParams) ([]*entity.KnowledgeCandidate, int64, error) {
+	if params == nil {
+		params = repository.NewListParams()
+	}
+
+	conditions := "tenant_id = $1"
+	args := []interface{}{tenantID}
+	if filter != nil {
+		if filter.ConversationID != "" {
+			args = append(args, filter.ConversationID)
+			conditions += fmt.Sprintf(" AND conversation_id = $%d", len(args))
+		}
+		if filter.Status != "" {
+			args = append(args, string(filter.Status))
+			conditions += fmt.Sprintf(" AND status = $%d", len(args))
+		}
+	}
+
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM knowledge_candidates WHERE "+conditions, args...).Scan(&total); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count knowledge candidates")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM knowledge_candidates
+		WHERE %s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, knowledgeCandidateColumns, conditions, len(args)+1, len(args)+2)
+	args = append(args, params.Limit(), params.Offset())
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to list knowledge candidates")
+	}
+	defer rows.Close()
+
+	var candidates []*entity.KnowledgeCandidate
+	for rows.Next() {
+		candidate, err := r.scanCandidate(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		candidates = append(candidates, candidate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate knowledge candidates")
+	}
+	return candidates, total, nil
+}
+
+// UpdateCandidate updates a candidate
+func (r *KnowledgeCurationRepository) UpdateCandidate(ctx context.Context, candidate *entity.KnowledgeCandidate) error {
+	query := `
+		UPDATE knowledge_candidates
+		SET question = $2, answer = $3, status = $4, edited = $5, knowledge_base_id = $6, item_id = $7,
+		    reviewed_by = $8, reviewed_at = $9, updated_at = $10
+		WHERE id = $1
+	`
+	tag, err := r.db.Pool.Exec(ctx, query,
+		candidate.ID,
+		candidate.Question,
+		candidate.Answer,
+		string(candidate.Status),
+		candidate.Edited,
+		nullString(candidate.KnowledgeBaseID),
+		candidate.ItemID,
+		candidate.ReviewedBy,
+		candidate.ReviewedAt,
+		candidate.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update knowledge candidate")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "knowledge candidate not found")
+	}
+	return nil
+}
+
+// MarkConversationMined records that a conversation was mined and how many pairs it gave
+func (r *KnowledgeCurationRepository) MarkConversationMined(ctx context.Context, tenantID, conversationID string, candidates int) error {
+	query := `
+		INSERT INTO knowledge_mined_conversations (conversation_id, tenant_id, candidates, mined_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (conversation_id) DO UPDATE SET candidates = EXCLUDED.candidates, mined_at = NOW()
+	`
+	if _, err := r.db.Pool.Exec(ctx, query, conversationID, tenantID, candidates); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to mark conversation as mined")
+	}
+	return nil
+}
+
+// IsConversationMined reports whether a conversation was already mined
+func (r *KnowledgeCurationRepository) IsConversationMined(ctx context.Context, conversationID string) (bool, error) {
+	var mined bool
+	err := r.db.Pool.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM knowledge_mined_conversations WHERE conversation_id = $1)`,
+		conversationID,
+	).Scan(&mined)
+	if err != nil {
+		return false, errors.Wrap(err, errors.ErrCodeInternal, "failed to check mined conversation")
+	}
+	return mined, nil
+}
+
+func (r *KnowledgeCurationRepository) scanCandidate(row pgx.Row) (*entity.KnowledgeCandidate, error) {
+	var candidate entity.KnowledgeCandidate
+	var status string
+	var knowledgeBaseID *string
+
+	err := row.Scan(
+		&candidate.ID,
+		&candidate.TenantID,
+		&candidate.ConversationID,
+		&candidate.Question,
+		&candidate.Answer,
+		&status,
+		&candidate.Edited,
+		&knowledgeBaseID,
+		&candidate.ItemID,
+		&candidate.ReviewedBy,
+		&candidate.ReviewedAt,
+		&candidate.CreatedAt,
+		&candidate.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "knowledge candidate not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan knowledge candidate")
+	}
+
+	candidate.Status = entity.KnowledgeCandidateStatus(status)
+	if knowledgeBaseID != nil {
+		candidate.KnowledgeBaseID = *knowledgeBaseID
+	}
+	return &candidate, nil
+}
@@ -0,0 +1,236 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// KnowledgeFeedbackRepository implements repository.KnowledgeFeedbackRepository with PostgreSQL
+type KnowledgeFeedbackRepository struct {
+	db *PostgresDB
+}
+
+// NewKnowledgeFeedbackRepository creates a new PostgreSQL knowledge feedback repository
+func NewKnowledgeFeedbackRepository(db *PostgresDB) *KnowledgeFeedbackRepository {
+	return &KnowledgeFeedbackRepository{db: db}
+}
+
+const knowledgeGapColumns = `
+	id, tenant_id, knowledge_base_id, question, source, item_ids, conversation_id, occurrences,
+	status, resolved_by, resolved_at, created_at, updated_at
+`
+
+// SaveFeedback stores the rating of an answer, replacing an earlier rating of the same answer
+func (r *KnowledgeFeedbackRepository) SaveFeedback(ctx context.Context, feedback *entity.KnowledgeFeedback) error {
+	query := `
+		INSERT INTO knowledge_feedback (
+			id, tenant_id, knowledge_base_id, item_ids, conversation_id, message_id, contact_id,
+			question, helpful, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (message_id) DO UPDATE SET
+			helpful = EXCLUDED.helpful,
+			contact_id = EXCLUDED.contact_id,
+			created_at = EXCLUDED.created_at
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query,
+		feedback.ID,
+		feedback.TenantID,
+		feedback.KnowledgeBaseID,
+		feedback.ItemIDs,
+		feedback.ConversationID,
+		feedback.MessageID,
+		feedback.ContactID,
+		feedback.Question,
+		feedback.Helpful,
+		feedback.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to save knowledge feedback")
+	}
+	return nil
+}
+
+// ItemQuality counts helpful and unhelpful ratings per knowledge item of a knowledge base
+func (r *KnowledgeFeedbackRepository) ItemQuality(ctx context.Context, tenantID, knowledgeBaseID string) ([]*entity.KnowledgeItemQuality, error) {
+	query := `
+		SELECT i.id, i.question,
+		       COUNT(*) FILTER (WHERE f.helpful),
+		       COUNT(*) FILTER (WHERE NOT f.helpful)
+		FROM knowledge_feedback f
+		CROSS JOIN LATERAL unnest(f.item_ids) AS used(item_id)
+		JOIN knowledge_items i ON i.id::text = used.item_id
+		WHERE f.tenant_id = $1 AND f.knowledge_base_id = $2
+		GROUP BY i.id, i.question
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, tenantID, knowledgeBaseID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to aggregate knowledge feedback")
+	}
+	defer rows.Close()
+
+	var items []*entity.KnowledgeItemQuality
+	for rows.Next() {
+		var item entity.KnowledgeItemQuality
+		if err := rows.Scan(&item.ItemID, &item.Question, &item.Helpful, &item.NotHelpful); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan knowledge item quality")
+		}
+		items = append(items, &item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate knowledge item quality")
+	}
+	return items, nil
+}
+
+// RecordGap queues a gap, or raises the occurrences of the open gap with the same question
+func (r *KnowledgeFeedbackRepository) RecordGap(ctx context.Context, gap *entity.KnowledgeGap) error {
+	query := `
+		INSERT INTO knowledge_gaps (` + knowledgeGapColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (knowledge_base_id, LOWER(question)) WHERE status = 'open' DO UPDATE SET
+			occurrences = knowledge_gaps.occurrences + 1,
+			item_ids = EXCLUDED.item_ids,
+			conversation_id = EXCLUDED.conversation_id,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, occurrences, created_at
+	`
+
+	err := r.db.Pool.QueryRow(ctx, query,
+		gap.ID,
+		gap.TenantID,
+		gap.KnowledgeBaseID,
+		gap.Question,
+		string(gap.Source),
+		gap.ItemIDs,
+		gap.ConversationID,
+		gap.Occurrences,
+		string(gap.Status),
+		gap.ResolvedBy,
+		gap.ResolvedAt,
+		gap.CreatedAt,
+		gap.UpdatedAt,
+	).Scan(&gap.ID, &gap.Occurrences, &gap.CreatedAt)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to record knowledge gap")
+	}
+	return nil
+}
+
+// FindGapByID finds a knowledge gap by ID
+func (r *KnowledgeFeedbackRepository) FindGapByID(ctx context.Context, id string) (*entity.KnowledgeGap, error) {
+	query := `SELECT ` + knowledgeGapColumns + ` FROM knowledge_gaps WHERE id = $1`
+	return r.scanGap(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// ListGaps lists the knowledge gaps of a tenant, most frequent first
+func (r *KnowledgeFeedbackRepository) ListGaps(ctx context.Context, tenantID string, filter *entity.KnowledgeGapFilter, params *repository.ListParams) ([]*entity.KnowledgeGap, int64, error) {
+	if params == nil {
+		params = repository.NewListParams()
+	}
+
+	conditions := "tenant_id = $1"
+	args := []interface{}{tenantID}
+	if filter != nil {
+		if filter.KnowledgeBaseID != "" {
+			args = append(args, filter.KnowledgeBaseID)
+			conditions += fmt.Sprintf(" AND knowledge_base_id = $%d", len(args))
+		}
+		if filter.Status != "" {
+			args = append(args, string(filter.Status))
+			conditions += fmt.Sprintf(" AND status = $%d", len(args))
+		}
+	}
+
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM knowledge_gaps WHERE "+conditions, args...).Scan(&total); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count knowledge gaps")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM knowledge_gaps
+		WHERE %s
+		ORDER BY occurrences DESC, updated_at DESC
+		LIMIT $%d OFFSET $%d
+	`, knowledgeGapColumns, conditions, len(args)+1, len(args)+2)
+	args = append(args, params.Limit(), params.Offset())
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to list knowledge gaps")
+	}
+	defer rows.Close()
+
+	var gaps []*entity.KnowledgeGap
+	for rows.Next() {
+		gap, err := r.scanGap(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		gaps = append(gaps, gap)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate knowledge gaps")
+	}
+	return gaps, total, nil
+}
+
+// UpdateGap updates the review state of a knowledge gap
+func (r *KnowledgeFeedbackRepository) UpdateGap(ctx context.Context, gap *entity.KnowledgeGap) error {
+	query := `
+		UPDATE knowledge_gaps
+		SET status = $2, resolved_by = $3, resolved_at = $4, updated_at = $5
+		WHERE id = $1
+	`
+	tag, err := r.db.Pool.Exec(ctx, query,
+		gap.ID,
+		string(gap.Status),
+		gap.ResolvedBy,
+		gap.ResolvedAt,
+		gap.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update knowledge gap")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "knowledge gap not found")
+	}
+	return nil
+}
+
+func (r *KnowledgeFeedbackRepository) scanGap(row pgx.Row) (*entity.KnowledgeGap, error) {
+	var gap entity.KnowledgeGap
+	var source, status string
+
+	err := row.Scan(
+		&gap.ID,
+		&gap.TenantID,
+		&gap.KnowledgeBaseID,
+		&gap.Question,
+		&source,
+		&gap.ItemIDs,
+		&gap.ConversationID,
+		&gap.Occurrences,
+		&status,
+		&gap.ResolvedBy,
+		&gap.ResolvedAt,
+		&gap.CreatedAt,
+		&gap.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "knowledge gap not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan knowledge gap")
+	}
+
+	gap.Source = entity.KnowledgeGapSource(source)
+	gap.Status = entity.KnowledgeGapStatus(status)
+	return &gap, nil
+}
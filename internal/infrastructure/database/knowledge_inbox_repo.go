@@ -0,0 +1,298 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// KnowledgeInboxRepository implements repository.KnowledgeInboxRepository with PostgreSQL
+type KnowledgeInboxRepository struct {
+	db *PostgresDB
+}
+
+// NewKnowledgeInboxRepository creates a new PostgreSQL knowledge inbox repository
+func NewKnowledgeInboxRepository(db *PostgresDB) *KnowledgeInboxRepository {
+	return &KnowledgeInboxRepository{db: db}
+}
+
+const knowledgeInboxColumns = `
+	id, tenant_id, name, address, knowledge_base_id, allowed_senders, include_body, is_active, created_at, updated_at
+`
+
+const knowledgeSubmissionColumns = `
+	id, tenant_id, inbox_id, knowledge_base_id, message_id, sender, subject, filename, mime_type,
+	title, content, status, error, item_ids, reviewed_by, reviewed_at, created_at, updated_at
+`
+
+// Create creates a new knowledge inbox
+func (r *KnowledgeInboxRepository) Create(ctx context.Context, inbox *entity.KnowledgeInbox) error {
+	query := `INSERT INTO knowledge_inboxes (` + knowledgeInboxColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+	_, err := r.db.Pool.Exec(ctx, query,
+		inbox.ID,
+		inbox.TenantID,
+		inbox.Name,
+		inbox.Address,
+		inbox.KnowledgeBaseID,
+		inbox.AllowedSenders,
+		inbox.IncludeBody,
+		inbox.IsActive,
+		inbox.CreatedAt,
+		inbox.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create knowledge inbox")
+	}
+	return nil
+}
+
+// FindByID finds a knowledge inbox by ID
+func (r *KnowledgeInboxRepository) FindByID(ctx context.Context, id string) (*entity.KnowledgeInbox, error) {
+	query := `SELECT ` + knowledgeInboxColumns + ` FROM knowledge_inboxes WHERE id = $1`
+	return r.scanInbox(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// ListByTenant lists the knowledge inboxes of a tenant
+func (r *KnowledgeInboxRepository) ListByTenant(ctx context.Context, tenantID string) ([]*entity.KnowledgeInbox, error) {
+	query := `SELECT ` + knowledgeInboxColumns + ` FROM knowledge_inboxes WHERE tenant_id = $1 ORDER BY name`
+	rows, err := r.db.Pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list knowledge inboxes")
+	}
+	defer rows.Close()
+
+	var inboxes []*entity.KnowledgeInbox
+	for rows.Next() {
+		inbox, err := r.scanInbox(rows)
+		if err != nil {
+			return nil, err
+		}
+		inboxes = append(inboxes, inbox)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate knowledge inboxes")
+	}
+	return inboxes, nil
+}
+
+// Update updates a knowledge inbox
+func (r *KnowledgeInboxRepository) Update(ctx context.Context, inbox *entity.KnowledgeInbox) error {
+	query := `
+		UPDATE knowledge_inboxes
+		SET name = $2, address = $3, knowledge_base_id = $4, allowed_senders = $5, include_body = $6,
+		    is_active = $7, updated_at = $8
+		WHERE id = $1
+	`
+	tag, err := r.db.Pool.Exec(ctx, query,
+		inbox.ID,
+		inbox.Name,
+		inbox.Address,
+		inbox.KnowledgeBaseID,
+		inbox.AllowedSenders,
+		inbox.IncludeBody,
+		inbox.IsActive,
+		inbox.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update knowledge inbox")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "knowledge inbox not found")
+	}
+	return nil
+}
+
+// Delete deletes a knowledge inbox
+func (r *KnowledgeInboxRepository) Delete(ctx context.Context, id string) error {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM knowledge_inboxes WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete knowledge inbox")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "knowledge inbox not found")
+	}
+	return nil
+}
+
+// CreateSubmission stores content received by an inbox
+func (r *KnowledgeInboxRepository) CreateSubmission(ctx context.Context, submission *entity.KnowledgeSubmission) error {
+	query := `INSERT INTO knowledge_submissions (` + knowledgeSubmissionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)`
+	_, err := r.db.Pool.Exec(ctx, query,
+		submission.ID,
+		submission.TenantID,
+		submission.InboxID,
+		submission.KnowledgeBaseID,
+		nullString(submission.MessageID),
+		submission.Sender,
+		submission.Subject,
+		submission.Filename,
+		submission.MimeType,
+		submission.Title,
+		submission.Content,
+		string(submission.Status),
+		submission.Error,
+		submission.ItemIDs,
+		submission.ReviewedBy,
+		submission.ReviewedAt,
+		submission.CreatedAt,
+		submission.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create knowledge submission")
+	}
+	return nil
+}
+
+// FindSubmissionByID finds a submission by ID
+func (r *KnowledgeInboxRepository) FindSubmissionByID(ctx context.Context, id string) (*entity.KnowledgeSubmission, error) {
+	query := `SELECT ` + knowledgeSubmissionColumns + ` FROM knowledge_submissions WHERE id = $1`
+	return r.scanSubmission(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// ListSubmissions lists the submissions of a tenant, newest first
+func (r *KnowledgeInboxRepository) ListSubmissions(ctx context.Context, tenantID string, filter *entity.KnowledgeSubmissionFilter, params *repository.ListParams) ([]*entity.KnowledgeSubmission, int64, error) {
+	if params == nil {
+		params = repository.NewListParams()
+	}
+
+	conditions := "tenant_id = $1"
+	args := []interface{}{tenantID}
+	if filter != nil {
+		if filter.InboxID != "" {
+			args = append(args, filter.InboxID)
+			conditions += fmt.Sprintf(" AND inbox_id = $%d", len(args))
+		}
+		if filter.Status != "" {
+			args = append(args, string(filter.Status))
+			conditions += fmt.Sprintf(" AND status = $%d", len(args))
+		}
+	}
+
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM knowledge_submissions WHERE "+conditions, args...).Scan(&total); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count knowledge submissions")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM knowledge_submissions
+		WHERE %s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, knowledgeSubmissionColumns, conditions, len(args)+1, len(args)+2)
+	args = append(args, params.Limit(), params.Offset())
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to list knowledge submissions")
+	}
+	defer rows.Close()
+
+	var submissions []*entity.KnowledgeSubmission
+	for rows.Next() {
+		submission, err := r.scanSubmission(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		submissions = append(submissions, submission)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate knowledge submissions")
+	}
+	return submissions, total, nil
+}
+
+// UpdateSubmission updates a submission
+func (r *KnowledgeInboxRepository) UpdateSubmission(ctx context.Context, submission *entity.KnowledgeSubmission) error {
+	query := `
+		UPDATE knowledge_submissions
+		SET title = $2, content = $3, status = $4, error = $5, item_ids = $6, reviewed_by = $7,
+		    reviewed_at = $8, updated_at = $9
+		WHERE id = $1
+	`
+	tag, err := r.db.Pool.Exec(ctx, query,
+		submission.ID,
+		submission.Title,
+		submission.Content,
+		string(submission.Status),
+		submission.Error,
+		submission.ItemIDs,
+		submission.ReviewedBy,
+		submission.ReviewedAt,
+		submission.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update knowledge submission")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "knowledge submission not found")
+	}
+	return nil
+}
+
+func (r *KnowledgeInboxRepository) scanInbox(row pgx.Row) (*entity.KnowledgeInbox, error) {
+	var inbox entity.KnowledgeInbox
+	err := row.Scan(
+		&inbox.ID,
+		&inbox.TenantID,
+		&inbox.Name,
+		&inbox.Address,
+		&inbox.KnowledgeBaseID,
+		&inbox.AllowedSenders,
+		&inbox.IncludeBody,
+		&inbox.IsActive,
+		&inbox.CreatedAt,
+		&inbox.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "knowledge inbox not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan knowledge inbox")
+	}
+	return &inbox, nil
+}
+
+func (r *KnowledgeInboxRepository) scanSubmission(row pgx.Row) (*entity.KnowledgeSubmission, error) {
+	var submission entity.KnowledgeSubmission
+	var status string
+	var messageID *string
+
+	err := row.Scan(
+		&submission.ID,
+		&submission.TenantID,
+		&submission.InboxID,
+		&submission.KnowledgeBaseID,
+		&messageID,
+		&submission.Sender,
+		&submission.Subject,
+		&submission.Filename,
+		&submission.MimeType,
+		&submission.Title,
+		&submission.Content,
+		&status,
+		&submission.Error,
+		&submission.ItemIDs,
+		&submission.ReviewedBy,
+		&submission.ReviewedAt,
+		&submission.CreatedAt,
+		&submission.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "knowledge submission not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan knowledge submission")
+	}
+
+	submission.Status = entity.KnowledgeSubmissionStatus(status)
+	if messageID != nil {
+		submission.MessageID = *messageID
+	}
+	return &submission, nil
+}
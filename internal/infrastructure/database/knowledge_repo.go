@@ -34,13 +34,13 @@ func (r *KnowledgeBaseRepository) Create(ctx context.Context, kb *entity.Knowled
 	query := `
 		INSERT INTO knowledge_bases (
 			id, tenant_id, name, description, type, config, status,
-			item_count, last_sync_at, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			item_count, last_sync_at, created_at, updated_at, is_global
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 	`
 
 	_, err = r.db.Pool.Exec(ctx, query,
 		kb.ID,
-		kb.TenantID,
+		nullString(kb.TenantID),
 		kb.Name,
 		kb.Description,
 		string(kb.Type),
@@ -50,6 +50,7 @@ func (r *KnowledgeBaseRepository) Create(ctx context.Context, kb *entity.Knowled
 		kb.LastSyncAt,
 		kb.CreatedAt,
 		kb.UpdatedAt,
+		kb.Global,
 	)
 
 	if err != nil {
@@ -63,7 +64,7 @@ func (r *KnowledgeBaseRepository) Create(ctx context.Context, kb *entity.Knowled
 func (r *KnowledgeBaseRepository) FindByID(ctx context.Context, id string) (*entity.KnowledgeBase, error) {
 	query := `
 		SELECT id, tenant_id, name, description, type, config, status,
-		       item_count, last_sync_at, created_at, updated_at
+		       item_count, last_sync_at, created_at, updated_at, is_global
 		FROM knowledge_bases
 		WHERE id = $1
 	`
@@ -93,7 +94,7 @@ func (r *KnowledgeBaseRepository) FindByTenant(ctx context.Context, tenantID str
 	// Get knowledge bases
 	query := fmt.Sprintf(`
 		SELECT id, tenant_id, name, description, type, config, status,
-		       item_count, last_sync_at, created_at, updated_at
+		       item_count, last_sync_at, created_at, updated_at, is_global
 		FROM knowledge_bases
 		WHERE tenant_id = $1
 		ORDER BY %s %s
@@ -198,11 +199,11 @@ func (r *KnowledgeBaseRepository) scanKnowledgeBase(row pgx.Row) (*entity.Knowle
 	var kb entity.KnowledgeBase
 	var kbType, status string
 	var configJSON []byte
-	var description *string
+	var tenantID, description *string
 
 	err := row.Scan(
-		&kb.ID, &kb.TenantID, &kb.Name, &description, &kbType, &configJSON, &status,
-		&kb.ItemCount, &kb.LastSyncAt, &kb.CreatedAt, &kb.UpdatedAt,
+		&kb.ID, &tenantID, &kb.Name, &description, &kbType, &configJSON, &status,
+		&kb.ItemCount, &kb.LastSyncAt, &kb.CreatedAt, &kb.UpdatedAt, &kb.Global,
 	)
 	if err != nil {
 		return nil, err
@@ -210,6 +211,9 @@ func (r *KnowledgeBaseRepository) scanKnowledgeBase(row pgx.Row) (*entity.Knowle
 
 	kb.Type = entity.KnowledgeType(kbType)
 	kb.Status = entity.KnowledgeStatus(status)
+	if tenantID != nil {
+		kb.TenantID = *tenantID
+	}
 	if description != nil {
 		kb.Description = *description
 	}
@@ -225,11 +229,11 @@ func (r *KnowledgeBaseRepository) scanKnowledgeBaseFromRows(rows pgx.Rows) (*ent
 	var kb entity.KnowledgeBase
 	var kbType, status string
 	var configJSON []byte
-	var description *string
+	var tenantID, description *string
 
 	err := rows.Scan(
-		&kb.ID, &kb.TenantID, &kb.Name, &description, &kbType, &configJSON, &status,
-		&kb.ItemCount, &kb.LastSyncAt, &kb.CreatedAt, &kb.UpdatedAt,
+		&kb.ID, &tenantID, &kb.Name, &description, &kbType, &configJSON, &status,
+		&kb.ItemCount, &kb.LastSyncAt, &kb.CreatedAt, &kb.UpdatedAt, &kb.Global,
 	)
 	if err != nil {
 		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan knowledge base")
@@ -237,6 +241,9 @@ func (r *KnowledgeBaseRepository) scanKnowledgeBaseFromRows(rows pgx.Rows) (*ent
 
 	kb.Type = entity.KnowledgeType(kbType)
 	kb.Status = entity.KnowledgeStatus(status)
+	if tenantID != nil {
+		kb.TenantID = *tenantID
+	}
 	if description != nil {
 		kb.Description = *description
 	}
@@ -284,8 +291,10 @@ func (r *KnowledgeItemRepository) Create(ctx context.Context, item *entity.Knowl
 	query := `
 		INSERT INTO knowledge_items (
 			id, knowledge_base_id, question, answer, keywords,
-			embedding, source, metadata, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			embedding, source, metadata, created_at, updated_at,
+			status, version, published_version, published_by, published_at,
+			tenant_id, overrides_item_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 	`
 
 	var embeddingStr *string
@@ -305,6 +314,13 @@ func (r *KnowledgeItemRepository) Create(ctx context.Context, item *entity.Knowl
 		metadataJSON,
 		item.CreatedAt,
 		item.UpdatedAt,
+		string(item.Status),
+		item.Version,
+		item.PublishedVersion,
+		item.PublishedBy,
+		item.PublishedAt,
+		nullString(item.TenantID),
+		nullString(item.OverridesItemID),
 	)
 
 	if err != nil {
@@ -318,7 +334,9 @@ func (r *KnowledgeItemRepository) Create(ctx context.Context, item *entity.Knowl
 func (r *KnowledgeItemRepository) FindByID(ctx context.Context, id string) (*entity.KnowledgeItem, error) {
 	query := `
 		SELECT id, knowledge_base_id, question, answer, keywords,
-		       embedding::text, source, metadata, created_at, updated_at
+		       embedding::text, source, metadata, created_at, updated_at,
+		       status, version, published_version, published_by, published_at,
+		       tenant_id, overrides_item_id
 		FROM knowledge_items
 		WHERE id = $1
 	`
@@ -348,7 +366,9 @@ func (r *KnowledgeItemRepository) FindByKnowledgeBase(ctx context.Context, kbID
 	// Get items
 	query := fmt.Sprintf(`
 		SELECT id, knowledge_base_id, question, answer, keywords,
-		       embedding::text, source, metadata, created_at, updated_at
+		       embedding::text, source, metadata, created_at, updated_at,
+		       status, version, published_version, published_by, published_at,
+		       tenant_id, overrides_item_id
 		FROM knowledge_items
 		WHERE knowledge_base_id = $1
 		ORDER BY %s %s
@@ -396,8 +416,13 @@ func (r *KnowledgeItemRepository) Update(ctx context.Context, item *entity.Knowl
 			embedding = $4,
 			source = $5,
 			metadata = $6,
-			updated_at = $7
-		WHERE id = $8
+			updated_at = $7,
+			status = $8,
+			version = $9,
+			published_version = $10,
+			published_by = $11,
+			published_at = $12
+		WHERE id = $13
 	`
 
 	result, err := r.db.Pool.Exec(ctx, query,
@@ -408,6 +433,11 @@ func (r *KnowledgeItemRepository) Update(ctx context.Context, item *entity.Knowl
 		item.Source,
 		metadataJSON,
 		item.UpdatedAt,
+		string(item.Status),
+		item.Version,
+		item.PublishedVersion,
+		item.PublishedBy,
+		item.PublishedAt,
 		item.ID,
 	)
 
@@ -472,7 +502,8 @@ func (r *KnowledgeItemRepository) CountByKnowledgeBase(ctx context.Context, kbID
 	return count, nil
 }
 
-// SearchByKeywords searches items by keywords
+// SearchByKeywords searches the published items by keywords, leaving out the
+// override items tenants add to global knowledge bases
 func (r *KnowledgeItemRepository) SearchByKeywords(ctx context.Context, kbID string, keywords []string, limit int) ([]*entity.KnowledgeItem, error) {
 	if len(keywords) == 0 {
 		return []*entity.KnowledgeItem{}, nil
@@ -494,9 +525,11 @@ func (r *KnowledgeItemRepository) SearchByKeywords(ctx context.Context, kbID str
 
 	query := fmt.Sprintf(`
 		SELECT id, knowledge_base_id, question, answer, keywords,
-		       embedding::text, source, metadata, created_at, updated_at
+		       embedding::text, source, metadata, created_at, updated_at,
+		       status, version, published_version, published_by, published_at,
+		       tenant_id, overrides_item_id
 		FROM knowledge_items
-		WHERE knowledge_base_id = $1 AND (%s)
+		WHERE knowledge_base_id = $1 AND tenant_id IS NULL AND status = 'published' AND (%s)
 		ORDER BY created_at DESC
 		LIMIT %d
 	`, strings.Join(searchConditions, " OR "), limit)
@@ -519,7 +552,8 @@ func (r *KnowledgeItemRepository) SearchByKeywords(ctx context.Context, kbID str
 	return items, nil
 }
 
-// SearchByEmbedding searches items by vector similarity (RAG)
+// SearchByEmbedding searches the published items by vector similarity (RAG),
+// leaving out the override items tenants add to global knowledge bases
 func (r *KnowledgeItemRepository) SearchByEmbedding(ctx context.Context, kbID string, embedding []float64, limit int, minScore float64) ([]*entity.SearchResult, error) {
 	if len(embedding) == 0 {
 		return []*entity.SearchResult{}, nil
@@ -532,9 +566,13 @@ func (r *KnowledgeItemRepository) SearchByEmbedding(ctx context.Context, kbID st
 	query := fmt.Sprintf(`
 		SELECT id, knowledge_base_id, question, answer, keywords,
 		       embedding::text, source, metadata, created_at, updated_at,
+		       status, version, published_version, published_by, published_at,
+		       tenant_id, overrides_item_id,
 		       1 - (embedding <=> '%s') as similarity
 		FROM knowledge_items
 		WHERE knowledge_base_id = $1
+		  AND tenant_id IS NULL
+		  AND status = 'published'
 		  AND embedding IS NOT NULL
 		  AND 1 - (embedding <=> '%s') >= $2
 		ORDER BY embedding <=> '%s'
@@ -552,17 +590,23 @@ func (r *KnowledgeItemRepository) SearchByEmbedding(ctx context.Context, kbID st
 		var item entity.KnowledgeItem
 		var embeddingText *string
 		var metadataJSON []byte
+		var status string
+		var tenantID, overridesItemID *string
 		var similarity float64
 
 		err := rows.Scan(
 			&item.ID, &item.KnowledgeBaseID, &item.Question, &item.Answer, &item.Keywords,
 			&embeddingText, &item.Source, &metadataJSON, &item.CreatedAt, &item.UpdatedAt,
+			&status, &item.Version, &item.PublishedVersion, &item.PublishedBy, &item.PublishedAt,
+			&tenantID, &overridesItemID,
 			&similarity,
 		)
 		if err != nil {
 			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan search result")
 		}
 
+		item.Status = entity.KnowledgeItemStatus(status)
+		setItemOwnership(&item, tenantID, overridesItemID)
 		if embeddingText != nil {
 			item.Embedding = stringToVector(*embeddingText)
 		}
@@ -594,15 +638,21 @@ func (r *KnowledgeItemRepository) scanKnowledgeItem(row pgx.Row) (*entity.Knowle
 	var item entity.KnowledgeItem
 	var embeddingText *string
 	var metadataJSON []byte
+	var status string
+	var tenantID, overridesItemID *string
 
 	err := row.Scan(
 		&item.ID, &item.KnowledgeBaseID, &item.Question, &item.Answer, &item.Keywords,
 		&embeddingText, &item.Source, &metadataJSON, &item.CreatedAt, &item.UpdatedAt,
+		&status, &item.Version, &item.PublishedVersion, &item.PublishedBy, &item.PublishedAt,
+		&tenantID, &overridesItemID,
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	item.Status = entity.KnowledgeItemStatus(status)
+	setItemOwnership(&item, tenantID, overridesItemID)
 	if embeddingText != nil {
 		item.Embedding = stringToVector(*embeddingText)
 	}
@@ -617,15 +667,21 @@ func (r *KnowledgeItemRepository) scanKnowledgeItemFromRows(rows pgx.Rows) (*ent
 	var item entity.KnowledgeItem
 	var embeddingText *string
 	var metadataJSON []byte
+	var status string
+	var tenantID, overridesItemID *string
 
 	err := rows.Scan(
 		&item.ID, &item.KnowledgeBaseID, &item.Question, &item.Answer, &item.Keywords,
 		&embeddingText, &item.Source, &metadataJSON, &item.CreatedAt, &item.UpdatedAt,
+		&status, &item.Version, &item.PublishedVersion, &item.PublishedBy, &item.PublishedAt,
+		&tenantID, &overridesItemID,
 	)
 	if err != nil {
 		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan knowledge item")
 	}
 
+	item.Status = entity.KnowledgeItemStatus(status)
+	setItemOwnership(&item, tenantID, overridesItemID)
 	if embeddingText != nil {
 		item.Embedding = stringToVector(*embeddingText)
 	}
@@ -636,6 +692,16 @@ func (r *KnowledgeItemRepository) scanKnowledgeItemFromRows(rows pgx.Rows) (*ent
 	return &item, nil
 }
 
+// setItemOwnership sets the tenant and overridden item of override items
+func setItemOwnership(item *entity.KnowledgeItem, tenantID, overridesItemID *string) {
+	if tenantID != nil {
+		item.TenantID = *tenantID
+	}
+	if overridesItemID != nil {
+		item.OverridesItemID = *overridesItemID
+	}
+}
+
 func sanitizeKIColumn(col string) string {
 	allowed := map[string]bool{
 		"created_at": true,
@@ -698,7 +764,9 @@ func (s *PgVectorStore) Store(ctx context.Context, id string, embedding []float6
 	return nil
 }
 
-// Search performs vector similarity search
+// Search performs vector similarity search over the published items. The shared
+// items of the knowledge base are searched, with the override items of the tenant
+// in the tenant_id filter; other tenants' overrides are never returned.
 func (s *PgVectorStore) Search(ctx context.Context, embedding []float64, topK int, filter map[string]string) ([]service.VectorSearchResult, error) {
 	kbID := filter["knowledge_base_id"]
 	if kbID == "" {
@@ -708,15 +776,17 @@ func (s *PgVectorStore) Search(ctx context.Context, embedding []float64, topK in
 	embeddingStr := vectorToString(embedding)
 
 	query := fmt.Sprintf(`
-		SELECT id, 1 - (embedding <=> '%s') as similarity
+		SELECT id, 1 - (embedding <=> '%s') as similarity, COALESCE(tenant_id::text, '')
 		FROM knowledge_items
 		WHERE knowledge_base_id = $1
+		  AND (tenant_id IS NULL OR tenant_id::text = $3)
+		  AND status = 'published'
 		  AND embedding IS NOT NULL
 		ORDER BY embedding <=> '%s'
 		LIMIT $2
 	`, embeddingStr, embeddingStr)
 
-	rows, err := s.db.Pool.Query(ctx, query, kbID, topK)
+	rows, err := s.db.Pool.Query(ctx, query, kbID, topK, filter["tenant_id"])
 	if err != nil {
 		return nil, errors.Wrap(err, errors.ErrCodeInternal, "vector search failed")
 	}
@@ -726,10 +796,12 @@ func (s *PgVectorStore) Search(ctx context.Context, embedding []float64, topK in
 	for rows.Next() {
 		var r service.VectorSearchResult
 		var similarity float64
-		if err := rows.Scan(&r.ID, &similarity); err != nil {
+		var tenantID string
+		if err := rows.Scan(&r.ID, &similarity, &tenantID); err != nil {
 			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan result")
 		}
 		r.Score = similarity
+		r.Metadata = map[string]string{"knowledge_base_id": kbID, "tenant_id": tenantID}
 		results = append(results, r)
 	}
 
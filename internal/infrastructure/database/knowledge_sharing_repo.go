@@ -0,0 +1,232 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// KnowledgeSharingRepository implements repository.KnowledgeSharingRepository with PostgreSQL
+type KnowledgeSharingRepository struct {
+	db    *PostgresDB
+	bases *KnowledgeBaseRepository
+	items *KnowledgeItemRepository
+}
+
+// NewKnowledgeSharingRepository creates a new knowledge sharing repository
+func NewKnowledgeSharingRepository(db *PostgresDB) *KnowledgeSharingRepository {
+	return &KnowledgeSharingRepository{
+		db:    db,
+		bases: NewKnowledgeBaseRepository(db),
+		items: NewKnowledgeItemRepository(db),
+	}
+}
+
+// FindGlobal lists the global knowledge bases
+func (r *KnowledgeSharingRepository) FindGlobal(ctx context.Context, params *repository.ListParams) ([]*entity.KnowledgeBase, int64, error) {
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx,
+		"SELECT COUNT(*) FROM knowledge_bases WHERE is_global",
+	).Scan(&total); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count global knowledge bases")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, tenant_id, name, description, type, config, status,
+		       item_count, last_sync_at, created_at, updated_at, is_global
+		FROM knowledge_bases
+		WHERE is_global
+		ORDER BY %s %s
+		LIMIT $1 OFFSET $2
+	`, sanitizeKBColumn(params.SortBy), sanitizeDirection(params.SortDir))
+
+	rows, err := r.db.Pool.Query(ctx, query, params.Limit(), params.Offset())
+	if err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to query global knowledge bases")
+	}
+	defer rows.Close()
+
+	kbs := make([]*entity.KnowledgeBase, 0)
+	for rows.Next() {
+		kb, err := r.bases.scanKnowledgeBaseFromRows(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		kbs = append(kbs, kb)
+	}
+	return kbs, total, nil
+}
+
+// FindAttached lists the global knowledge bases attached to a tenant
+func (r *KnowledgeSharingRepository) FindAttached(ctx context.Context, tenantID string) ([]*entity.KnowledgeBase, error) {
+	query := `
+		SELECT kb.id, kb.tenant_id, kb.name, kb.description, kb.type, kb.config, kb.status,
+		       kb.item_count, kb.last_sync_at, kb.created_at, kb.updated_at, kb.is_global
+		FROM knowledge_bases kb
+		JOIN knowledge_base_attachments a ON a.knowledge_base_id = kb.id
+		WHERE a.tenant_id = $1 AND kb.is_global
+		ORDER BY kb.name
+	`
+	rows, err := r.db.Pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to query attached knowledge bases")
+	}
+	defer rows.Close()
+
+	kbs := make([]*entity.KnowledgeBase, 0)
+	for rows.Next() {
+		kb, err := r.bases.scanKnowledgeBaseFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		kbs = append(kbs, kb)
+	}
+	return kbs, nil
+}
+
+// Attach attaches a global knowledge base to a tenant; attaching it again is a no-op
+func (r *KnowledgeSharingRepository) Attach(ctx context.Context, attachment *entity.KnowledgeBaseAttachment) error {
+	query := `
+		INSERT INTO knowledge_base_attachments (knowledge_base_id, tenant_id, attached_by, attached_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (knowledge_base_id, tenant_id) DO NOTHING
+	`
+	_, err := r.db.Pool.Exec(ctx, query,
+		attachment.KnowledgeBaseID,
+		attachment.TenantID,
+		attachment.AttachedBy,
+		attachment.AttachedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to attach knowledge base")
+	}
+	return nil
+}
+
+// Detach detaches a global knowledge base from a tenant
+func (r *KnowledgeSharingRepository) Detach(ctx context.Context, knowledgeBaseID, tenantID string) error {
+	result, err := r.db.Pool.Exec(ctx,
+		"DELETE FROM knowledge_base_attachments WHERE knowledge_base_id = $1 AND tenant_id = $2",
+		knowledgeBaseID, tenantID,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to detach knowledge base")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "knowledge base is not attached to the tenant")
+	}
+	return nil
+}
+
+// IsAttached reports whether a global knowledge base is attached to a tenant
+func (r *KnowledgeSharingRepository) IsAttached(ctx context.Context, knowledgeBaseID, tenantID string) (bool, error) {
+	var attached bool
+	err := r.db.Pool.QueryRow(ctx,
+		"SELECT EXISTS (SELECT 1 FROM knowledge_base_attachments WHERE knowledge_base_id = $1 AND tenant_id = $2)",
+		knowledgeBaseID, tenantID,
+	).Scan(&attached)
+	if err != nil {
+		return false, errors.Wrap(err, errors.ErrCodeInternal, "failed to check knowledge base attachment")
+	}
+	return attached, nil
+}
+
+// ListAttachments lists the tenants a global knowledge base is attached to
+func (r *KnowledgeSharingRepository) ListAttachments(ctx context.Context, knowledgeBaseID string) ([]*entity.KnowledgeBaseAttachment, error) {
+	query := `
+		SELECT knowledge_base_id, tenant_id, attached_by, attached_at
+		FROM knowledge_base_attachments
+		WHERE knowledge_base_id = $1
+		ORDER BY attached_at
+	`
+	rows, err := r.db.Pool.Query(ctx, query, knowledgeBaseID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list knowledge base attachments")
+	}
+	defer rows.Close()
+
+	attachments := make([]*entity.KnowledgeBaseAttachment, 0)
+	for rows.Next() {
+		var attachment entity.KnowledgeBaseAttachment
+		if err := rows.Scan(
+			&attachment.KnowledgeBaseID,
+			&attachment.TenantID,
+			&attachment.AttachedBy,
+			&attachment.AttachedAt,
+		); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan knowledge base attachment")
+		}
+		attachments = append(attachments, &attachment)
+	}
+	return attachments, nil
+}
+
+// FindItems lists the items of a global knowledge base as a tenant sees them: the
+// shared items and the tenant's overrides, never other tenants' overrides
+func (r *KnowledgeSharingRepository) FindItems(ctx context.Context, knowledgeBaseID, tenantID string, params *repository.ListParams) ([]*entity.KnowledgeItem, int64, error) {
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx,
+		"SELECT COUNT(*) FROM knowledge_items WHERE knowledge_base_id = $1 AND (tenant_id IS NULL OR tenant_id = $2)",
+		knowledgeBaseID, tenantID,
+	).Scan(&total); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count knowledge items")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, knowledge_base_id, question, answer, keywords,
+		       embedding::text, source, metadata, created_at, updated_at,
+		       status, version, published_version, published_by, published_at,
+		       tenant_id, overrides_item_id
+		FROM knowledge_items
+		WHERE knowledge_base_id = $1 AND (tenant_id IS NULL OR tenant_id = $2)
+		ORDER BY %s %s
+		LIMIT $3 OFFSET $4
+	`, sanitizeKIColumn(params.SortBy), sanitizeDirection(params.SortDir))
+
+	rows, err := r.db.Pool.Query(ctx, query, knowledgeBaseID, tenantID, params.Limit(), params.Offset())
+	if err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to query knowledge items")
+	}
+	defer rows.Close()
+
+	var items []*entity.KnowledgeItem
+	for rows.Next() {
+		item, err := r.items.scanKnowledgeItemFromRows(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		items = append(items, item)
+	}
+	return items, total, nil
+}
+
+// FindOverrides lists a tenant's override items in a global knowledge base
+func (r *KnowledgeSharingRepository) FindOverrides(ctx context.Context, knowledgeBaseID, tenantID string) ([]*entity.KnowledgeItem, error) {
+	query := `
+		SELECT id, knowledge_base_id, question, answer, keywords,
+		       embedding::text, source, metadata, created_at, updated_at,
+		       status, version, published_version, published_by, published_at,
+		       tenant_id, overrides_item_id
+		FROM knowledge_items
+		WHERE knowledge_base_id = $1 AND tenant_id = $2
+		ORDER BY created_at
+	`
+	rows, err := r.db.Pool.Query(ctx, query, knowledgeBaseID, tenantID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to query knowledge item overrides")
+	}
+	defer rows.Close()
+
+	items := make([]*entity.KnowledgeItem, 0)
+	for rows.Next() {
+		item, err := r.items.scanKnowledgeItemFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
@@ -0,0 +1,236 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// KnowledgeVersionRepository implements repository.KnowledgeVersionRepository with PostgreSQL
+type KnowledgeVersionRepository struct {
+	db *PostgresDB
+}
+
+// NewKnowledgeVersionRepository creates a new PostgreSQL knowledge version repository
+func NewKnowledgeVersionRepository(db *PostgresDB) *KnowledgeVersionRepository {
+	return &KnowledgeVersionRepository{db: db}
+}
+
+const knowledgeVersionColumns = `
+	id, item_id, knowledge_base_id, version, question, answer, keywords, source, metadata,
+	action, restored_from, created_by, created_at, published_by, published_at
+`
+
+// CreateVersion stores a version of an item
+func (r *KnowledgeVersionRepository) CreateVersion(ctx context.Context, version *entity.KnowledgeItemVersion) error {
+	if version.ID == "" {
+		version.ID = uuid.New().String()
+	}
+	metadataJSON, err := json.Marshal(version.Metadata)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal metadata")
+	}
+
+	query := `INSERT INTO knowledge_item_versions (` + knowledgeVersionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
+	_, err = r.db.Pool.Exec(ctx, query,
+		version.ID,
+		version.ItemID,
+		version.KnowledgeBaseID,
+		version.Version,
+		version.Question,
+		version.Answer,
+		version.Keywords,
+		version.Source,
+		metadataJSON,
+		string(version.Action),
+		version.RestoredFrom,
+		version.CreatedBy,
+		version.CreatedAt,
+		version.PublishedBy,
+		version.PublishedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create knowledge item version")
+	}
+	return nil
+}
+
+// FindVersion finds a version of an item by number
+func (r *KnowledgeVersionRepository) FindVersion(ctx context.Context, itemID string, version int) (*entity.KnowledgeItemVersion, error) {
+	query := `SELECT ` + knowledgeVersionColumns + ` FROM knowledge_item_versions WHERE item_id = $1 AND version = $2`
+	return r.scanVersion(r.db.Pool.QueryRow(ctx, query, itemID, version))
+}
+
+// ListVersions lists the versions of an item, newest first
+func (r *KnowledgeVersionRepository) ListVersions(ctx context.Context, itemID string) ([]*entity.KnowledgeItemVersion, error) {
+	query := `SELECT ` + knowledgeVersionColumns + ` FROM knowledge_item_versions WHERE item_id = $1 ORDER BY version DESC`
+	rows, err := r.db.Pool.Query(ctx, query, itemID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list knowledge item versions")
+	}
+	defer rows.Close()
+
+	var versions []*entity.KnowledgeItemVersion
+	for rows.Next() {
+		version, err := r.scanVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate knowledge item versions")
+	}
+	return versions, nil
+}
+
+// MarkVersionPublished records who published a version and when
+func (r *KnowledgeVersionRepository) MarkVersionPublished(ctx context.Context, version *entity.KnowledgeItemVersion) error {
+	tag, err := r.db.Pool.Exec(ctx,
+		`UPDATE knowledge_item_versions SET published_by = $3, published_at = $4 WHERE item_id = $1 AND version = $2`,
+		version.ItemID, version.Version, version.PublishedBy, version.PublishedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to mark knowledge item version published")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "knowledge item version not found")
+	}
+	return nil
+}
+
+// CreateAuditEvent records a change in the audit trail
+func (r *KnowledgeVersionRepository) CreateAuditEvent(ctx context.Context, event *entity.KnowledgeAuditEvent) error {
+	if event.ID == "" {
+		event.ID = uuid.New().String()
+	}
+	query := `
+		INSERT INTO knowledge_audit_events (id, knowledge_base_id, item_id, action, version, user_id, question, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := r.db.Pool.Exec(ctx, query,
+		event.ID,
+		event.KnowledgeBaseID,
+		event.ItemID,
+		string(event.Action),
+		event.Version,
+		event.UserID,
+		event.Question,
+		event.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create knowledge audit event")
+	}
+	return nil
+}
+
+// ListAuditEvents lists the audit trail of a knowledge base, newest first
+func (r *KnowledgeVersionRepository) ListAuditEvents(ctx context.Context, kbID string, filter *entity.KnowledgeAuditFilter, params *repository.ListParams) ([]*entity.KnowledgeAuditEvent, int64, error) {
+	if params == nil {
+		params = repository.NewListParams()
+	}
+
+	conditions := "knowledge_base_id = $1"
+	args := []interface{}{kbID}
+	if filter != nil {
+		if filter.ItemID != "" {
+			args = append(args, filter.ItemID)
+			conditions += fmt.Sprintf(" AND item_id = $%d", len(args))
+		}
+		if filter.UserID != "" {
+			args = append(args, filter.UserID)
+			conditions += fmt.Sprintf(" AND user_id = $%d", len(args))
+		}
+	}
+
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM knowledge_audit_events WHERE "+conditions, args...).Scan(&total); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count knowledge audit events")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, knowledge_base_id, item_id, action, version, user_id, question, created_at
+		FROM knowledge_audit_events
+		WHERE %s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, conditions, len(args)+1, len(args)+2)
+	args = append(args, params.Limit(), params.Offset())
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to list knowledge audit events")
+	}
+	defer rows.Close()
+
+	var events []*entity.KnowledgeAuditEvent
+	for rows.Next() {
+		var event entity.KnowledgeAuditEvent
+		var action string
+		if err := rows.Scan(
+			&event.ID,
+			&event.KnowledgeBaseID,
+			&event.ItemID,
+			&action,
+			&event.Version,
+			&event.UserID,
+			&event.Question,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan knowledge audit event")
+		}
+		event.Action = entity.KnowledgeAction(action)
+		events = append(events, &event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate knowledge audit events")
+	}
+	return events, total, nil
+}
+
+func (r *KnowledgeVersionRepository) scanVersion(row pgx.Row) (*entity.KnowledgeItemVersion, error) {
+	var version entity.KnowledgeItemVersion
+	var source *string
+	var metadataJSON []byte
+	var action string
+
+	err := row.Scan(
+		&version.ID,
+		&version.ItemID,
+		&version.KnowledgeBaseID,
+		&version.Version,
+		&version.Question,
+		&version.Answer,
+		&version.Keywords,
+		&source,
+		&metadataJSON,
+		&action,
+		&version.RestoredFrom,
+		&version.CreatedBy,
+		&version.CreatedAt,
+		&version.PublishedBy,
+		&version.PublishedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "knowledge item version not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan knowledge item version")
+	}
+
+	version.Action = entity.KnowledgeAction(action)
+	if source != nil {
+		version.Source = *source
+	}
+	if len(metadataJSON) > 0 {
+		json.Unmarshal(metadataJSON, &version.Metadata)
+	}
+	return &version, nil
+}
@@ -0,0 +1,157 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// LabelRepository implements repository.LabelRepository with PostgreSQL
+type LabelRepository struct {
+	db *PostgresDB
+}
+
+// NewLabelRepository creates a new PostgreSQL label repository
+func NewLabelRepository(db *PostgresDB) *LabelRepository {
+	return &LabelRepository{db: db}
+}
+
+const labelColumns = `id, tenant_id, name, color, description, created_at, updated_at`
+
+// Create creates a new label
+func (r *LabelRepository) Create(ctx context.Context, label *entity.Label) error {
+	query := `INSERT INTO labels (` + labelColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	_, err := r.db.Pool.Exec(ctx, query,
+		label.ID,
+		label.TenantID,
+		label.Name,
+		label.Color,
+		label.Description,
+		label.CreatedAt,
+		label.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create label")
+	}
+	return nil
+}
+
+// FindByID finds a label by ID
+func (r *LabelRepository) FindByID(ctx context.Context, id string) (*entity.Label, error) {
+	query := `SELECT ` + labelColumns + ` FROM labels WHERE id = $1`
+	return r.scanLabel(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// FindByName finds a label of a tenant by name, ignoring case
+func (r *LabelRepository) FindByName(ctx context.Context, tenantID, name string) (*entity.Label, error) {
+	query := `SELECT ` + labelColumns + ` FROM labels WHERE tenant_id = $1 AND LOWER(name) = LOWER($2)`
+	return r.scanLabel(r.db.Pool.QueryRow(ctx, query, tenantID, name))
+}
+
+// FindByTenant lists the labels of a tenant
+func (r *LabelRepository) FindByTenant(ctx context.Context, tenantID string) ([]*entity.Label, error) {
+	query := `SELECT ` + labelColumns + ` FROM labels WHERE tenant_id = $1 ORDER BY name`
+	return r.queryLabels(ctx, query, tenantID)
+}
+
+// FindByConversation lists the labels attached to a conversation
+func (r *LabelRepository) FindByConversation(ctx context.Context, conversationID string) ([]*entity.Label, error) {
+	query := `
+		SELECT l.id, l.tenant_id, l.name, l.color, l.description, l.created_at, l.updated_at
+		FROM labels l
+		JOIN conversation_labels cl ON cl.label_id = l.id
+		WHERE cl.conversation_id = $1
+		ORDER BY l.name
+	`
+	return r.queryLabels(ctx, query, conversationID)
+}
+
+// Update updates a label
+func (r *LabelRepository) Update(ctx context.Context, label *entity.Label) error {
+	query := `UPDATE labels SET name = $2, color = $3, description = $4, updated_at = $5 WHERE id = $1`
+	tag, err := r.db.Pool.Exec(ctx, query, label.ID, label.Name, label.Color, label.Description, label.UpdatedAt)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update label")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "label not found")
+	}
+	return nil
+}
+
+// Delete deletes a label, detaching it from every conversation
+func (r *LabelRepository) Delete(ctx context.Context, id string) error {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM labels WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete label")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "label not found")
+	}
+	return nil
+}
+
+// Attach attaches a label to a conversation
+func (r *LabelRepository) Attach(ctx context.Context, conversationID, labelID, userID string) error {
+	query := `
+		INSERT INTO conversation_labels (conversation_id, label_id, created_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (conversation_id, label_id) DO NOTHING
+	`
+	if _, err := r.db.Pool.Exec(ctx, query, conversationID, labelID, userID); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to attach label")
+	}
+	return nil
+}
+
+// Detach removes a label from a conversation
+func (r *LabelRepository) Detach(ctx context.Context, conversationID, labelID string) error {
+	query := `DELETE FROM conversation_labels WHERE conversation_id = $1 AND label_id = $2`
+	if _, err := r.db.Pool.Exec(ctx, query, conversationID, labelID); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to detach label")
+	}
+	return nil
+}
+
+func (r *LabelRepository) queryLabels(ctx context.Context, query string, args ...interface{}) ([]*entity.Label, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list labels")
+	}
+	defer rows.Close()
+
+	var labels []*entity.Label
+	for rows.Next() {
+		label, err := r.scanLabel(rows)
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, label)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate labels")
+	}
+	return labels, nil
+}
+
+func (r *LabelRepository) scanLabel(row pgx.Row) (*entity.Label, error) {
+	var label entity.Label
+	err := row.Scan(
+		&label.ID,
+		&label.TenantID,
+		&label.Name,
+		&label.Color,
+		&label.Description,
+		&label.CreatedAt,
+		&label.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "label not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan label")
+	}
+	return &label, nil
+}
@@ -0,0 +1,423 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// LinkedObjectRepository implements repository.LinkedObjectRepository with PostgreSQL
+type LinkedObjectRepository struct {
+	db *PostgresDB
+}
+
+// NewLinkedObjectRepository creates a new PostgreSQL linked object repository
+func NewLinkedObjectRepository(db *PostgresDB) *LinkedObjectRepository {
+	return &LinkedObjectRepository{db: db}
+}
+
+const linkedObjectColumns = `
+	id, tenant_id, type, source, connector_id, external_id, reference, title, status, url,
+	contact_id, conversation_id, attributes, external_updated_at, created_by, created_at, updated_at
+`
+
+// Create creates a new linked object
+func (r *LinkedObjectRepository) Create(ctx context.Context, object *entity.LinkedObject) error {
+	attributes, err := json.Marshal(object.Attributes)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal attributes")
+	}
+
+	query := `INSERT INTO linked_objects (` + linkedObjectColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)`
+	_, err = r.db.Pool.Exec(ctx, query,
+		object.ID,
+		object.TenantID,
+		string(object.Type),
+		object.Source,
+		nullString(object.ConnectorID),
+		object.ExternalID,
+		object.Reference,
+		object.Title,
+		object.Status,
+		object.URL,
+		nullString(object.ContactID),
+		nullString(object.ConversationID),
+		attributes,
+		object.ExternalUpdatedAt,
+		object.CreatedBy,
+		object.CreatedAt,
+		object.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create linked object")
+	}
+	return nil
+}
+
+// FindByID finds a linked object by ID
+func (r *LinkedObjectRepository) FindByID(ctx context.Context, id string) (*entity.LinkedObject, error) {
+	query := `SELECT ` + linkedObjectColumns + ` FROM linked_objects WHERE id = $1`
+	return r.scanLinkedObject(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// FindByExternalID finds the object a connector synced with an external ID
+func (r *LinkedObjectRepository) FindByExternalID(ctx context.Context, connectorID, externalID string) (*entity.LinkedObject, error) {
+	query := `SELECT ` + linkedObjectColumns + ` FROM linked_objects WHERE connector_id = $1 AND external_id = $2`
+	return r.scanLinkedObject(r.db.Pool.QueryRow(ctx, query, connectorID, externalID))
+}
+
+// ListByTenant lists the linked objects of a tenant, most recently updated first
+func (r *LinkedObjectRepository) ListByTenant(ctx context.Context, tenantID string, filter *entity.LinkedObjectFilter, params *repository.ListParams) ([]*entity.LinkedObject, int64, error) {
+	if params == nil {
+		params = repository.NewListParams()
+	}
+
+	conditions := "tenant_id = $1"
+	args := []interface{}{tenantID}
+	if filter != nil {
+		if filter.Type != "" {
+			args = append(args, string(filter.Type))
+			conditions += fmt.Sprintf(" AND type = $%d", len(args))
+		}
+		if filter.ConversationID != "" {
+			args = append(args, filter.ConversationID)
+			conditions += fmt.Sprintf(" AND conversation_id = $%d", len(args))
+		}
+		if filter.ContactID != "" {
+			args = append(args, filter.ContactID)
+			conditions += fmt.Sprintf(" AND contact_id = $%d", len(args))
+		}
+		if filter.ConnectorID != "" {
+			args = append(args, filter.ConnectorID)
+			conditions += fmt.Sprintf(" AND connector_id = $%d", len(args))
+		}
+		if filter.Status != "" {
+			args = append(args, filter.Status)
+			conditions += fmt.Sprintf(" AND status = $%d", len(args))
+		}
+	}
+
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM linked_objects WHERE "+conditions, args...).Scan(&total); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count linked objects")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM linked_objects
+		WHERE %s
+		ORDER BY updated_at DESC
+		LIMIT $%d OFFSET $%d
+	`, linkedObjectColumns, conditions, len(args)+1, len(args)+2)
+	args = append(args, params.Limit(), params.Offset())
+
+	objects, err := r.queryLinkedObjects(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return objects, total, nil
+}
+
+// ListByConversation lists the objects linked to a conversation, most recently updated first
+func (r *LinkedObjectRepository) ListByConversation(ctx context.Context, conversationID string) ([]*entity.LinkedObject, error) {
+	query := `
+		SELECT ` + linkedObjectColumns + ` FROM linked_objects
+		WHERE conversation_id = $1
+		ORDER BY updated_at DESC
+	`
+	return r.queryLinkedObjects(ctx, query, conversationID)
+}
+
+// Update updates a linked object
+func (r *LinkedObjectRepository) Update(ctx context.Context, object *entity.LinkedObject) error {
+	attributes, err := json.Marshal(object.Attributes)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal attributes")
+	}
+
+	query := `
+		UPDATE linked_objects
+		SET reference = $2, title = $3, status = $4, url = $5, contact_id = $6, conversation_id = $7,
+		    attributes = $8, external_updated_at = $9, updated_at = $10
+		WHERE id = $1
+	`
+	tag, err := r.db.Pool.Exec(ctx, query,
+		object.ID,
+		object.Reference,
+		object.Title,
+		object.Status,
+		object.URL,
+		nullString(object.ContactID),
+		nullString(object.ConversationID),
+		attributes,
+		object.ExternalUpdatedAt,
+		object.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update linked object")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "linked object not found")
+	}
+	return nil
+}
+
+// Delete deletes a linked object
+func (r *LinkedObjectRepository) Delete(ctx context.Context, id string) error {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM linked_objects WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete linked object")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "linked object not found")
+	}
+	return nil
+}
+
+func (r *LinkedObjectRepository) queryLinkedObjects(ctx context.Context, query string, args ...interface{}) ([]*entity.LinkedObject, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list linked objects")
+	}
+	defer rows.Close()
+
+	var objects []*entity.LinkedObject
+	for rows.Next() {
+		object, err := r.scanLinkedObject(rows)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, object)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate linked objects")
+	}
+	return objects, nil
+}
+
+func (r *LinkedObjectRepository) scanLinkedObject(row pgx.Row) (*entity.LinkedObject, error) {
+	var object entity.LinkedObject
+	var objectType string
+	var connectorID, contactID, conversationID *string
+	var attributes []byte
+
+	err := row.Scan(
+		&object.ID,
+		&object.TenantID,
+		&objectType,
+		&object.Source,
+		&connectorID,
+		&object.ExternalID,
+		&object.Reference,
+		&object.Title,
+		&object.Status,
+		&object.URL,
+		&contactID,
+		&conversationID,
+		&attributes,
+		&object.ExternalUpdatedAt,
+		&object.CreatedBy,
+		&object.CreatedAt,
+		&object.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "linked object not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan linked object")
+	}
+
+	object.Type = entity.LinkedObjectType(objectType)
+	if connectorID != nil {
+		object.ConnectorID = *connectorID
+	}
+	if contactID != nil {
+		object.ContactID = *contactID
+	}
+	if conversationID != nil {
+		object.ConversationID = *conversationID
+	}
+	if len(attributes) > 0 {
+		if err := json.Unmarshal(attributes, &object.Attributes); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal attributes")
+		}
+	}
+	return &object, nil
+}
+
+// LinkedObjectConnectorRepository implements repository.LinkedObjectConnectorRepository with PostgreSQL
+type LinkedObjectConnectorRepository struct {
+	db *PostgresDB
+}
+
+// NewLinkedObjectConnectorRepository creates a new PostgreSQL linked object connector repository
+func NewLinkedObjectConnectorRepository(db *PostgresDB) *LinkedObjectConnectorRepository {
+	return &LinkedObjectConnectorRepository{db: db}
+}
+
+const linkedObjectConnectorColumns = `
+	id, tenant_id, name, provider, object_type, secret, field_map, status_messages, is_active,
+	last_sync_at, created_at, updated_at
+`
+
+// Create creates a new connector
+func (r *LinkedObjectConnectorRepository) Create(ctx context.Context, connector *entity.LinkedObjectConnector) error {
+	fieldMap, statusMessages, err := marshalLinkedObjectConnector(connector)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO linked_object_connectors (` + linkedObjectConnectorColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+	_, err = r.db.Pool.Exec(ctx, query,
+		connector.ID,
+		connector.TenantID,
+		connector.Name,
+		string(connector.Provider),
+		string(connector.ObjectType),
+		connector.Secret,
+		fieldMap,
+		statusMessages,
+		connector.IsActive,
+		connector.LastSyncAt,
+		connector.CreatedAt,
+		connector.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create linked object connector")
+	}
+	return nil
+}
+
+// FindByID finds a connector by ID
+func (r *LinkedObjectConnectorRepository) FindByID(ctx context.Context, id string) (*entity.LinkedObjectConnector, error) {
+	query := `SELECT ` + linkedObjectConnectorColumns + ` FROM linked_object_connectors WHERE id = $1`
+	return r.scanConnector(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// ListByTenant lists the connectors of a tenant
+func (r *LinkedObjectConnectorRepository) ListByTenant(ctx context.Context, tenantID string) ([]*entity.LinkedObjectConnector, error) {
+	query := `SELECT ` + linkedObjectConnectorColumns + ` FROM linked_object_connectors WHERE tenant_id = $1 ORDER BY name`
+	rows, err := r.db.Pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list linked object connectors")
+	}
+	defer rows.Close()
+
+	var connectors []*entity.LinkedObjectConnector
+	for rows.Next() {
+		connector, err := r.scanConnector(rows)
+		if err != nil {
+			return nil, err
+		}
+		connectors = append(connectors, connector)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate linked object connectors")
+	}
+	return connectors, nil
+}
+
+// Update updates a connector, including its secret and last sync time
+func (r *LinkedObjectConnectorRepository) Update(ctx context.Context, connector *entity.LinkedObjectConnector) error {
+	fieldMap, statusMessages, err := marshalLinkedObjectConnector(connector)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE linked_object_connectors
+		SET name = $2, provider = $3, object_type = $4, secret = $5, field_map = $6, status_messages = $7,
+		    is_active = $8, last_sync_at = $9, updated_at = $10
+		WHERE id = $1
+	`
+	tag, err := r.db.Pool.Exec(ctx, query,
+		connector.ID,
+		connector.Name,
+		string(connector.Provider),
+		string(connector.ObjectType),
+		connector.Secret,
+		fieldMap,
+		statusMessages,
+		connector.IsActive,
+		connector.LastSyncAt,
+		connector.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update linked object connector")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "linked object connector not found")
+	}
+	return nil
+}
+
+// Delete deletes a connector
+func (r *LinkedObjectConnectorRepository) Delete(ctx context.Context, id string) error {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM linked_object_connectors WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete linked object connector")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "linked object connector not found")
+	}
+	return nil
+}
+
+func marshalLinkedObjectConnector(connector *entity.LinkedObjectConnector) ([]byte, []byte, error) {
+	fieldMap, err := json.Marshal(connector.FieldMap)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal field map")
+	}
+	statusMessages, err := json.Marshal(connector.StatusMessages)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal status messages")
+	}
+	return fieldMap, statusMessages, nil
+}
+
+func (r *LinkedObjectConnectorRepository) scanConnector(row pgx.Row) (*entity.LinkedObjectConnector, error) {
+	var connector entity.LinkedObjectConnector
+	var provider, objectType string
+	var fieldMap, statusMessages []byte
+
+	err := row.Scan(
+		&connector.ID,
+		&connector.TenantID,
+		&connector.Name,
+		&provider,
+		&objectType,
+		&connector.Secret,
+		&fieldMap,
+		&statusMessages,
+		&connector.IsActive,
+		&connector.LastSyncAt,
+		&connector.CreatedAt,
+		&connector.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "linked object connector not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan linked object connector")
+	}
+
+	connector.Provider = entity.LinkedObjectProvider(provider)
+	connector.ObjectType = entity.LinkedObjectType(objectType)
+	if len(fieldMap) > 0 {
+		if err := json.Unmarshal(fieldMap, &connector.FieldMap); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal field map")
+		}
+	}
+	if len(statusMessages) > 0 {
+		if err := json.Unmarshal(statusMessages, &connector.StatusMessages); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal status messages")
+		}
+	}
+	return &connector, nil
+}
@@ -0,0 +1,152 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// MaintenanceRepository implements repository.MaintenanceRepository with PostgreSQL
+type MaintenanceRepository struct {
+	db *PostgresDB
+}
+
+// NewMaintenanceRepository creates a new PostgreSQL maintenance repository
+func NewMaintenanceRepository(db *PostgresDB) *MaintenanceRepository {
+	return &MaintenanceRepository{db: db}
+}
+
+// GetState returns the maintenance state, off when it was never set
+func (r *MaintenanceRepository) GetState(ctx context.Context) (*entity.MaintenanceState, error) {
+	query := `
+		SELECT status, reason, started_by, started_at, ended_at, updated_at
+		FROM maintenance_state
+		WHERE id = 1
+	`
+
+	var state entity.MaintenanceState
+	var status string
+	err := r.db.Pool.QueryRow(ctx, query).Scan(
+		&status,
+		&state.Reason,
+		&state.StartedBy,
+		&state.StartedAt,
+		&state.EndedAt,
+		&state.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return &entity.MaintenanceState{Status: entity.MaintenanceStatusOff}, nil
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get maintenance state")
+	}
+
+	state.Status = entity.MaintenanceStatus(status)
+	return &state, nil
+}
+
+// SaveState stores the maintenance state
+func (r *MaintenanceRepository) SaveState(ctx context.Context, state *entity.MaintenanceState) error {
+	query := `
+		INSERT INTO maintenance_state (id, status, reason, started_by, started_at, ended_at, updated_at)
+		VALUES (1, $1, $2, $3, $4, $5, $6)
+		ON CONFLICT (id) DO UPDATE SET
+			status = EXCLUDED.status,
+			reason = EXCLUDED.reason,
+			started_by = EXCLUDED.started_by,
+			started_at = EXCLUDED.started_at,
+			ended_at = EXCLUDED.ended_at,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err := r.db.Pool.Exec(ctx, query,
+		string(state.Status),
+		state.Reason,
+		state.StartedBy,
+		state.StartedAt,
+		state.EndedAt,
+		state.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to save maintenance state")
+	}
+	return nil
+}
+
+// BufferWebhook stores a webhook request, assigning its ID
+func (r *MaintenanceRepository) BufferWebhook(ctx context.Context, webhook *entity.BufferedWebhook) error {
+	headers, err := json.Marshal(webhook.Headers)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to encode webhook headers")
+	}
+
+	query := `
+		INSERT INTO maintenance_webhooks (method, host, uri, headers, body, received_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`
+	err = r.db.Pool.QueryRow(ctx, query,
+		webhook.Method,
+		webhook.Host,
+		webhook.URI,
+		headers,
+		webhook.Body,
+		webhook.ReceivedAt,
+	).Scan(&webhook.ID)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to buffer webhook")
+	}
+	return nil
+}
+
+// ListBuffered lists the oldest buffered webhooks, in the order they were received
+func (r *MaintenanceRepository) ListBuffered(ctx context.Context, limit int) ([]*entity.BufferedWebhook, error) {
+	query := `
+		SELECT id, method, host, uri, headers, body, received_at
+		FROM maintenance_webhooks
+		ORDER BY id
+		LIMIT $1
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list buffered webhooks")
+	}
+	defer rows.Close()
+
+	webhooks := []*entity.BufferedWebhook{}
+	for rows.Next() {
+		var webhook entity.BufferedWebhook
+		var headers []byte
+		if err := rows.Scan(&webhook.ID, &webhook.Method, &webhook.Host, &webhook.URI, &headers, &webhook.Body, &webhook.ReceivedAt); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan buffered webhook")
+		}
+		if err := json.Unmarshal(headers, &webhook.Headers); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to decode webhook headers")
+		}
+		webhooks = append(webhooks, &webhook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate buffered webhooks")
+	}
+	return webhooks, nil
+}
+
+// DeleteBuffered removes a replayed webhook
+func (r *MaintenanceRepository) DeleteBuffered(ctx context.Context, id int64) error {
+	if _, err := r.db.Pool.Exec(ctx, `DELETE FROM maintenance_webhooks WHERE id = $1`, id); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete buffered webhook")
+	}
+	return nil
+}
+
+// CountBuffered counts the webhooks waiting to be replayed
+func (r *MaintenanceRepository) CountBuffered(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM maintenance_webhooks`).Scan(&count); err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count buffered webhooks")
+	}
+	return count, nil
+}
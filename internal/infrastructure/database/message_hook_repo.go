@@ -0,0 +1,213 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// MessageHookRepository implements repository.MessageHookRepository with PostgreSQL
+type MessageHookRepository struct {
+	db *PostgresDB
+}
+
+// NewMessageHookRepository creates a new PostgreSQL message hook repository
+func NewMessageHookRepository(db *PostgresDB) *MessageHookRepository {
+	return &MessageHookRepository{db: db}
+}
+
+const messageHookColumns = `
+	id, tenant_id, name, description, language, script, events, channel_ids, lookups,
+	timeout_ms, memory_mb, priority, is_active, last_error, last_run_at, created_at, updated_at
+`
+
+// Create creates a new hook
+func (r *MessageHookRepository) Create(ctx context.Context, hook *entity.MessageHook) error {
+	lookups, err := json.Marshal(hook.Lookups)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal hook lookups")
+	}
+
+	query := `INSERT INTO message_hooks (` + messageHookColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)`
+	_, err = r.db.Pool.Exec(ctx, query,
+		hook.ID,
+		hook.TenantID,
+		hook.Name,
+		hook.Description,
+		string(hook.Language),
+		hook.Script,
+		hookEventsToStrings(hook.Events),
+		hook.ChannelIDs,
+		lookups,
+		hook.TimeoutMs,
+		hook.MemoryMB,
+		hook.Priority,
+		hook.IsActive,
+		hook.LastError,
+		hook.LastRunAt,
+		hook.CreatedAt,
+		hook.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create message hook")
+	}
+	return nil
+}
+
+// FindByID finds a hook by ID
+func (r *MessageHookRepository) FindByID(ctx context.Context, id string) (*entity.MessageHook, error) {
+	query := `SELECT ` + messageHookColumns + ` FROM message_hooks WHERE id = $1`
+	return r.scanHook(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// ListByTenant lists the hooks of a tenant ordered by priority
+func (r *MessageHookRepository) ListByTenant(ctx context.Context, tenantID string) ([]*entity.MessageHook, error) {
+	query := `SELECT ` + messageHookColumns + ` FROM message_hooks WHERE tenant_id = $1 ORDER BY priority, created_at`
+	return r.queryHooks(ctx, query, tenantID)
+}
+
+// ListActive lists the active hooks of a tenant for an event ordered by priority
+func (r *MessageHookRepository) ListActive(ctx context.Context, tenantID string, event entity.HookEvent) ([]*entity.MessageHook, error) {
+	query := `
+		SELECT ` + messageHookColumns + ` FROM message_hooks
+		WHERE tenant_id = $1 AND is_active = TRUE AND $2 = ANY(events)
+		ORDER BY priority, created_at
+	`
+	return r.queryHooks(ctx, query, tenantID, string(event))
+}
+
+// Update updates a hook
+func (r *MessageHookRepository) Update(ctx context.Context, hook *entity.MessageHook) error {
+	lookups, err := json.Marshal(hook.Lookups)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal hook lookups")
+	}
+
+	query := `
+		UPDATE message_hooks
+		SET name = $2, description = $3, language = $4, script = $5, events = $6, channel_ids = $7,
+		    lookups = $8, timeout_ms = $9, memory_mb = $10, priority = $11, is_active = $12, updated_at = $13
+		WHERE id = $1
+	`
+	result, err := r.db.Pool.Exec(ctx, query,
+		hook.ID,
+		hook.Name,
+		hook.Description,
+		string(hook.Language),
+		hook.Script,
+		hookEventsToStrings(hook.Events),
+		hook.ChannelIDs,
+		lookups,
+		hook.TimeoutMs,
+		hook.MemoryMB,
+		hook.Priority,
+		hook.IsActive,
+		hook.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update message hook")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "message hook not found")
+	}
+	return nil
+}
+
+// Delete deletes a hook
+func (r *MessageHookRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.Pool.Exec(ctx, `DELETE FROM message_hooks WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete message hook")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "message hook not found")
+	}
+	return nil
+}
+
+// RecordRun records the time and error (empty on success) of a hook run
+func (r *MessageHookRepository) RecordRun(ctx context.Context, id string, runAt time.Time, lastError string) error {
+	query := `UPDATE message_hooks SET last_run_at = $2, last_error = $3 WHERE id = $1`
+	if _, err := r.db.Pool.Exec(ctx, query, id, runAt, lastError); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to record message hook run")
+	}
+	return nil
+}
+
+func (r *MessageHookRepository) queryHooks(ctx context.Context, query string, args ...interface{}) ([]*entity.MessageHook, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list message hooks")
+	}
+	defer rows.Close()
+
+	var hooks []*entity.MessageHook
+	for rows.Next() {
+		hook, err := r.scanHook(rows)
+		if err != nil {
+			return nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate message hooks")
+	}
+	return hooks, nil
+}
+
+func (r *MessageHookRepository) scanHook(row pgx.Row) (*entity.MessageHook, error) {
+	var hook entity.MessageHook
+	var language string
+	var events []string
+	var lookups []byte
+
+	err := row.Scan(
+		&hook.ID,
+		&hook.TenantID,
+		&hook.Name,
+		&hook.Description,
+		&language,
+		&hook.Script,
+		&events,
+		&hook.ChannelIDs,
+		&lookups,
+		&hook.TimeoutMs,
+		&hook.MemoryMB,
+		&hook.Priority,
+		&hook.IsActive,
+		&hook.LastError,
+		&hook.LastRunAt,
+		&hook.CreatedAt,
+		&hook.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "message hook not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan message hook")
+	}
+
+	hook.Language = entity.HookLanguage(language)
+	for _, event := range events {
+		hook.Events = append(hook.Events, entity.HookEvent(event))
+	}
+	if len(lookups) > 0 {
+		if err := json.Unmarshal(lookups, &hook.Lookups); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal hook lookups")
+		}
+	}
+	return &hook, nil
+}
+
+func hookEventsToStrings(events []entity.HookEvent) []string {
+	result := make([]string, len(events))
+	for i, event := range events {
+		result[i] = string(event)
+	}
+	return result
+}
@@ -9,12 +9,14 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/msgfy/linktor/internal/domain/entity"
 	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/internal/infrastructure/encryption"
 	"github.com/msgfy/linktor/pkg/errors"
 )
 
 // MessageRepository implements repository.MessageRepository with PostgreSQL
 type MessageRepository struct {
-	db *PostgresDB
+	db     *PostgresDB
+	cipher encryption.Cipher
 }
 
 // NewMessageRepository creates a new PostgreSQL message repository
@@ -22,19 +24,29 @@ func NewMessageRepository(db *PostgresDB) *MessageRepository {
 	return &MessageRepository{db: db}
 }
 
+// SetCipher enables encryption of message content and attachment references
+// for tenants that turned it on. Values are sealed on write and opened on read.
+func (r *MessageRepository) SetCipher(cipher encryption.Cipher) {
+	r.cipher = cipher
+}
+
 // Create creates a new message
 func (r *MessageRepository) Create(ctx context.Context, message *entity.Message) error {
 	metadata, err := json.Marshal(message.Metadata)
 	if err != nil {
 		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal metadata")
 	}
+	errorDetails, err := marshalMessageError(message.ErrorDetails)
+	if err != nil {
+		return err
+	}
 
 	query := `
 		INSERT INTO messages (
 			id, conversation_id, sender_type, sender_id, content_type, content,
-			metadata, status, external_id, error_message, sent_at, delivered_at,
+			metadata, status, external_id, error_message, error_details, sent_at, delivered_at,
 			read_at, created_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 	`
 
 	var senderID *string
@@ -42,17 +54,23 @@ func (r *MessageRepository) Create(ctx context.Context, message *entity.Message)
 		senderID = &message.SenderID
 	}
 
+	content := message.Content
+	if err := r.encrypt(ctx, r.conversationTenant, message.ConversationID, &content); err != nil {
+		return err
+	}
+
 	_, err = r.db.Pool.Exec(ctx, query,
 		message.ID,
 		message.ConversationID,
 		string(message.SenderType),
 		senderID,
 		string(message.ContentType),
-		message.Content,
+		content,
 		metadata,
 		string(message.Status),
 		nullString(message.ExternalID),
 		nullString(message.ErrorMessage),
+		errorDetails,
 		message.SentAt,
 		message.DeliveredAt,
 		message.ReadAt,
@@ -70,7 +88,7 @@ func (r *MessageRepository) Create(ctx context.Context, message *entity.Message)
 func (r *MessageRepository) FindByID(ctx context.Context, id string) (*entity.Message, error) {
 	query := `
 		SELECT id, conversation_id, sender_type, sender_id, content_type, content,
-		       metadata, status, external_id, error_message, sent_at, delivered_at,
+		       metadata, status, external_id, error_message, error_details, sent_at, delivered_at,
 		       read_at, created_at
 		FROM messages
 		WHERE id = $1
@@ -83,6 +101,9 @@ func (r *MessageRepository) FindByID(ctx context.Context, id string) (*entity.Me
 		}
 		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find message")
 	}
+	if err := r.decrypt(ctx, &message.Content); err != nil {
+		return nil, err
+	}
 
 	// Load attachments
 	attachments, err := r.FindAttachmentsByMessage(ctx, id)
@@ -98,7 +119,7 @@ func (r *MessageRepository) FindByID(ctx context.Context, id string) (*entity.Me
 func (r *MessageRepository) FindByExternalID(ctx context.Context, externalID string) (*entity.Message, error) {
 	query := `
 		SELECT id, conversation_id, sender_type, sender_id, content_type, content,
-		       metadata, status, external_id, error_message, sent_at, delivered_at,
+		       metadata, status, external_id, error_message, error_details, sent_at, delivered_at,
 		       read_at, created_at
 		FROM messages
 		WHERE external_id = $1
@@ -111,6 +132,9 @@ func (r *MessageRepository) FindByExternalID(ctx context.Context, externalID str
 		}
 		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find message")
 	}
+	if err := r.decrypt(ctx, &message.Content); err != nil {
+		return nil, err
+	}
 
 	return message, nil
 }
@@ -127,7 +151,7 @@ func (r *MessageRepository) FindByConversation(ctx context.Context, conversation
 	// Get messages
 	query := fmt.Sprintf(`
 		SELECT id, conversation_id, sender_type, sender_id, content_type, content,
-		       metadata, status, external_id, error_message, sent_at, delivered_at,
+		       metadata, status, external_id, error_message, error_details, sent_at, delivered_at,
 		       read_at, created_at
 		FROM messages
 		WHERE conversation_id = $1
@@ -147,18 +171,93 @@ func (r *MessageRepository) FindByConversation(ctx context.Context, conversation
 		if err != nil {
 			return nil, 0, err
 		}
+		if err := r.decrypt(ctx, &message.Content); err != nil {
+			return nil, 0, err
+		}
 		messages = append(messages, message)
 	}
 
 	return messages, total, nil
 }
 
+// FindByConversationAfter finds the messages of a conversation created after a time, oldest first
+func (r *MessageRepository) FindByConversationAfter(ctx context.Context, conversationID string, after time.Time, limit int) ([]*entity.Message, error) {
+	query := `
+		SELECT id, conversation_id, sender_type, sender_id, content_type, content,
+		       metadata, status, external_id, error_message, error_details, sent_at, delivered_at,
+		       read_at, created_at
+		FROM messages
+		WHERE conversation_id = $1 AND created_at > $2
+		ORDER BY created_at ASC
+		LIMIT $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, conversationID, after, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to query messages")
+	}
+	defer rows.Close()
+
+	var messages []*entity.Message
+	for rows.Next() {
+		message, err := r.scanMessageFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.decrypt(ctx, &message.Content); err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, nil
+}
+
+// FindRecentOutboundByContact finds the messages sent to a contact across its conversations
+// since a time, newest first. Failed messages are left out.
+func (r *MessageRepository) FindRecentOutboundByContact(ctx context.Context, contactID string, since time.Time, limit int) ([]*entity.Message, error) {
+	query := `
+		SELECT m.id, m.conversation_id, m.sender_type, m.sender_id, m.content_type, m.content,
+		       m.metadata, m.status, m.external_id, m.error_message, m.error_details, m.sent_at, m.delivered_at,
+		       m.read_at, m.created_at
+		FROM messages m
+		JOIN conversations c ON c.id = m.conversation_id
+		WHERE c.contact_id = $1 AND m.sender_type <> 'contact' AND m.status <> 'failed' AND m.created_at >= $2
+		ORDER BY m.created_at DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, contactID, since, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to query messages")
+	}
+	defer rows.Close()
+
+	var messages []*entity.Message
+	for rows.Next() {
+		message, err := r.scanMessageFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.decrypt(ctx, &message.Content); err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, nil
+}
+
 // Update updates a message
 func (r *MessageRepository) Update(ctx context.Context, message *entity.Message) error {
 	metadata, err := json.Marshal(message.Metadata)
 	if err != nil {
 		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal metadata")
 	}
+	errorDetails, err := marshalMessageError(message.ErrorDetails)
+	if err != nil {
+		return err
+	}
 
 	query := `
 		UPDATE messages SET
@@ -168,19 +267,26 @@ func (r *MessageRepository) Update(ctx context.Context, message *entity.Message)
 			status = $4,
 			external_id = $5,
 			error_message = $6,
-			sent_at = $7,
-			delivered_at = $8,
-			read_at = $9
-		WHERE id = $10
+			error_details = $7,
+			sent_at = $8,
+			delivered_at = $9,
+			read_at = $10
+		WHERE id = $11
 	`
 
+	content := message.Content
+	if err := r.encrypt(ctx, r.conversationTenant, message.ConversationID, &content); err != nil {
+		return err
+	}
+
 	result, err := r.db.Pool.Exec(ctx, query,
 		string(message.ContentType),
-		message.Content,
+		content,
 		metadata,
 		string(message.Status),
 		nullString(message.ExternalID),
 		nullString(message.ErrorMessage),
+		errorDetails,
 		message.SentAt,
 		message.DeliveredAt,
 		message.ReadAt,
@@ -249,6 +355,71 @@ func (r *MessageRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+// FindExpiredEphemeral finds disappearing messages that expired before a time and were
+// not handled yet, oldest first. Expirations are stored as RFC 3339 UTC, which sorts as text.
+func (r *MessageRepository) FindExpiredEphemeral(ctx context.Context, before time.Time, limit int) ([]*entity.Message, error) {
+	query := `
+		SELECT id, conversation_id, sender_type, sender_id, content_type, content,
+		       metadata, status, external_id, error_message, error_details, sent_at, delivered_at,
+		       read_at, created_at
+		FROM messages
+		WHERE metadata ? 'ephemeral_expires_at' AND NOT metadata ? 'ephemeral_expired'
+		  AND metadata->>'ephemeral_expires_at' <= $1
+		ORDER BY metadata->>'ephemeral_expires_at'
+		LIMIT $2
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, before.UTC().Format(time.RFC3339), limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to query expired messages")
+	}
+	defer rows.Close()
+
+	var messages []*entity.Message
+	for rows.Next() {
+		message, err := r.scanMessageFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		if err := r.decrypt(ctx, &message.Content); err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, nil
+}
+
+// Redact clears the content and attachments of a message and stores its metadata
+func (r *MessageRepository) Redact(ctx context.Context, id string, metadata map[string]string) error {
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal metadata")
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to begin transaction")
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, "UPDATE messages SET content = '', metadata = $1 WHERE id = $2", data, id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to redact message")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeMessageNotFound, "message not found")
+	}
+	if _, err := tx.Exec(ctx, "DELETE FROM message_attachments WHERE message_id = $1", id); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete message attachments")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to commit transaction")
+	}
+	return nil
+}
+
 // CountByConversation counts messages in a conversation
 func (r *MessageRepository) CountByConversation(ctx context.Context, conversationID string) (int64, error) {
 	var count int64
@@ -312,15 +483,20 @@ func (r *MessageRepository) CreateAttachment(ctx context.Context, attachment *en
 		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 
+	filename, url, thumbnailURL := attachment.Filename, attachment.URL, attachment.ThumbnailURL
+	if err := r.encrypt(ctx, r.messageTenant, attachment.MessageID, &filename, &url, &thumbnailURL); err != nil {
+		return err
+	}
+
 	_, err = r.db.Pool.Exec(ctx, query,
 		attachment.ID,
 		attachment.MessageID,
 		attachment.Type,
-		nullString(attachment.Filename),
+		nullString(filename),
 		nullString(attachment.MimeType),
 		attachment.SizeBytes,
-		attachment.URL,
-		nullString(attachment.ThumbnailURL),
+		url,
+		nullString(thumbnailURL),
 		metadata,
 		attachment.CreatedAt,
 	)
@@ -375,6 +551,9 @@ func (r *MessageRepository) FindAttachmentsByMessage(ctx context.Context, messag
 		if err := json.Unmarshal(metadata, &a.Metadata); err != nil {
 			a.Metadata = make(map[string]string)
 		}
+		if err := r.decrypt(ctx, &a.Filename, &a.URL, &a.ThumbnailURL); err != nil {
+			return nil, err
+		}
 
 		attachments = append(attachments, &a)
 	}
@@ -386,12 +565,12 @@ func (r *MessageRepository) FindAttachmentsByMessage(ctx context.Context, messag
 func (r *MessageRepository) scanMessage(row pgx.Row) (*entity.Message, error) {
 	var m entity.Message
 	var senderID, externalID, errorMessage *string
-	var metadata []byte
+	var metadata, errorDetails []byte
 	var senderType, contentType, status string
 
 	err := row.Scan(
 		&m.ID, &m.ConversationID, &senderType, &senderID, &contentType, &m.Content,
-		&metadata, &status, &externalID, &errorMessage, &m.SentAt, &m.DeliveredAt,
+		&metadata, &status, &externalID, &errorMessage, &errorDetails, &m.SentAt, &m.DeliveredAt,
 		&m.ReadAt, &m.CreatedAt,
 	)
 	if err != nil {
@@ -411,10 +590,14 @@ func (r *MessageRepository) scanMessage(row pgx.Row) (*entity.Message, error) {
 	if errorMessage != nil {
 		m.ErrorMessage = *errorMessage
 	}
+	if len(errorDetails) > 0 {
+		_ = json.Unmarshal(errorDetails, &m.ErrorDetails)
+	}
 
 	if err := json.Unmarshal(metadata, &m.Metadata); err != nil {
 		m.Metadata = make(map[string]string)
 	}
+	m.SetContextCards()
 
 	return &m, nil
 }
@@ -423,12 +606,12 @@ func (r *MessageRepository) scanMessage(row pgx.Row) (*entity.Message, error) {
 func (r *MessageRepository) scanMessageFromRows(rows pgx.Rows) (*entity.Message, error) {
 	var m entity.Message
 	var senderID, externalID, errorMessage *string
-	var metadata []byte
+	var metadata, errorDetails []byte
 	var senderType, contentType, status string
 
 	err := rows.Scan(
 		&m.ID, &m.ConversationID, &senderType, &senderID, &contentType, &m.Content,
-		&metadata, &status, &externalID, &errorMessage, &m.SentAt, &m.DeliveredAt,
+		&metadata, &status, &externalID, &errorMessage, &errorDetails, &m.SentAt, &m.DeliveredAt,
 		&m.ReadAt, &m.CreatedAt,
 	)
 	if err != nil {
@@ -448,14 +631,92 @@ func (r *MessageRepository) scanMessageFromRows(rows pgx.Rows) (*entity.Message,
 	if errorMessage != nil {
 		m.ErrorMessage = *errorMessage
 	}
+	if len(errorDetails) > 0 {
+		_ = json.Unmarshal(errorDetails, &m.ErrorDetails)
+	}
 
 	if err := json.Unmarshal(metadata, &m.Metadata); err != nil {
 		m.Metadata = make(map[string]string)
 	}
+	m.SetContextCards()
 
 	return &m, nil
 }
 
+// marshalMessageError encodes the classified failure of a message, or NULL when there is none
+func marshalMessageError(details *entity.MessageError) ([]byte, error) {
+	if details == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(details)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal message error")
+	}
+	return data, nil
+}
+
+// encrypt seals values in place for the tenant owning the record, which is
+// resolved from the stored rows rather than trusted from the caller
+func (r *MessageRepository) encrypt(ctx context.Context, tenantOf func(context.Context, string) (string, error), id string, values ...*string) error {
+	if r.cipher == nil {
+		return nil
+	}
+	tenantID, err := tenantOf(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, value := range values {
+		sealed, err := r.cipher.Encrypt(ctx, tenantID, *value)
+		if err != nil {
+			return errors.Wrap(err, errors.ErrCodeInternal, "failed to encrypt message")
+		}
+		*value = sealed
+	}
+	return nil
+}
+
+// decrypt opens sealed values in place; plain values are left as they are
+func (r *MessageRepository) decrypt(ctx context.Context, values ...*string) error {
+	if r.cipher == nil {
+		return nil
+	}
+	for _, value := range values {
+		if !encryption.IsEncrypted(*value) {
+			continue
+		}
+		opened, err := r.cipher.Decrypt(ctx, *value)
+		if err != nil {
+			return err
+		}
+		*value = opened
+	}
+	return nil
+}
+
+func (r *MessageRepository) conversationTenant(ctx context.Context, conversationID string) (string, error) {
+	var tenantID string
+	err := r.db.Pool.QueryRow(ctx, `SELECT tenant_id FROM conversations WHERE id = $1`, conversationID).Scan(&tenantID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", errors.New(errors.ErrCodeConversationNotFound, "conversation not found")
+		}
+		return "", errors.Wrap(err, errors.ErrCodeInternal, "failed to resolve message tenant")
+	}
+	return tenantID, nil
+}
+
+func (r *MessageRepository) messageTenant(ctx context.Context, messageID string) (string, error) {
+	var tenantID string
+	query := `SELECT c.tenant_id FROM messages m JOIN conversations c ON c.id = m.conversation_id WHERE m.id = $1`
+	if err := r.db.Pool.QueryRow(ctx, query, messageID).Scan(&tenantID); err != nil {
+		if err == pgx.ErrNoRows {
+			return "", errors.New(errors.ErrCodeMessageNotFound, "message not found")
+		}
+		return "", errors.Wrap(err, errors.ErrCodeInternal, "failed to resolve attachment tenant")
+	}
+	return tenantID, nil
+}
+
 // Helper functions
 
 func nullString(s string) *string {
@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// MessageTranslationRepository implements repository.MessageTranslationRepository with PostgreSQL
+type MessageTranslationRepository struct {
+	db *PostgresDB
+}
+
+// NewMessageTranslationRepository creates a new PostgreSQL message translation repository
+func NewMessageTranslationRepository(db *PostgresDB) *MessageTranslationRepository {
+	return &MessageTranslationRepository{db: db}
+}
+
+// FindByMessages finds the translations of messages into a language
+func (r *MessageTranslationRepository) FindByMessages(ctx context.Context, messageIDs []string, language string) ([]*entity.MessageTranslation, error) {
+	if len(messageIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT message_id, language, source_language, content, source_digest, created_at
+		FROM message_translations
+		WHERE message_id = ANY($1) AND language = $2
+	`
+	rows, err := r.db.Pool.Query(ctx, query, messageIDs, language)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find message translations")
+	}
+	defer rows.Close()
+
+	var translations []*entity.MessageTranslation
+	for rows.Next() {
+		var translation entity.MessageTranslation
+		if err := rows.Scan(
+			&translation.MessageID,
+			&translation.Language,
+			&translation.SourceLanguage,
+			&translation.Content,
+			&translation.SourceDigest,
+			&translation.CreatedAt,
+		); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan message translation")
+		}
+		translations = append(translations, &translation)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate message translations")
+	}
+	return translations, nil
+}
+
+// Save stores the translation of a message, replacing a previous one in the same language
+func (r *MessageTranslationRepository) Save(ctx context.Context, translation *entity.MessageTranslation) error {
+	query := `
+		INSERT INTO message_translations (message_id, language, source_language, content, source_digest, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (message_id, language) DO UPDATE SET
+			source_language = EXCLUDED.source_language,
+			content = EXCLUDED.content,
+			source_digest = EXCLUDED.source_digest,
+			created_at = EXCLUDED.created_at
+	`
+	_, err := r.db.Pool.Exec(ctx, query,
+		translation.MessageID,
+		translation.Language,
+		translation.SourceLanguage,
+		translation.Content,
+		translation.SourceDigest,
+		translation.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to save message translation")
+	}
+	return nil
+}
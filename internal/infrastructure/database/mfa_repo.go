@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// UserMFARepository implements repository.UserMFARepository with PostgreSQL
+type UserMFARepository struct {
+	db *PostgresDB
+}
+
+// NewUserMFARepository creates a new PostgreSQL user MFA repository
+func NewUserMFARepository(db *PostgresDB) *UserMFARepository {
+	return &UserMFARepository{db: db}
+}
+
+// FindByUser finds the second factor of a user
+func (r *UserMFARepository) FindByUser(ctx context.Context, userID string) (*entity.UserMFA, error) {
+	query := `
+		SELECT user_id, tenant_id, secret, enabled, recovery_codes, last_used_step,
+		       failed_attempts, locked_until, enabled_at, created_at, updated_at
+		FROM user_mfa
+		WHERE user_id = $1
+	`
+
+	var mfa entity.UserMFA
+	err := r.db.Pool.QueryRow(ctx, query, userID).Scan(
+		&mfa.UserID,
+		&mfa.TenantID,
+		&mfa.Secret,
+		&mfa.Enabled,
+		&mfa.RecoveryCodes,
+		&mfa.LastUsedStep,
+		&mfa.FailedAttempts,
+		&mfa.LockedUntil,
+		&mfa.EnabledAt,
+		&mfa.CreatedAt,
+		&mfa.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "MFA not set up")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find user MFA")
+	}
+	return &mfa, nil
+}
+
+// Save creates or replaces the second factor of a user
+func (r *UserMFARepository) Save(ctx context.Context, mfa *entity.UserMFA) error {
+	query := `
+		INSERT INTO user_mfa (
+			user_id, tenant_id, secret, enabled, recovery_codes, last_used_step,
+			failed_attempts, locked_until, enabled_at, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (user_id) DO UPDATE SET
+			secret = EXCLUDED.secret,
+			enabled = EXCLUDED.enabled,
+			recovery_codes = EXCLUDED.recovery_codes,
+			last_used_step = EXCLUDED.last_used_step,
+			failed_attempts = EXCLUDED.failed_attempts,
+			locked_until = EXCLUDED.locked_until,
+			enabled_at = EXCLUDED.enabled_at,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	recoveryCodes := mfa.RecoveryCodes
+	if recoveryCodes == nil {
+		recoveryCodes = []string{}
+	}
+	_, err := r.db.Pool.Exec(ctx, query,
+		mfa.UserID,
+		mfa.TenantID,
+		mfa.Secret,
+		mfa.Enabled,
+		recoveryCodes,
+		mfa.LastUsedStep,
+		mfa.FailedAttempts,
+		mfa.LockedUntil,
+		mfa.EnabledAt,
+		mfa.CreatedAt,
+		mfa.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to save user MFA")
+	}
+	return nil
+}
+
+// Delete removes the second factor of a user
+func (r *UserMFARepository) Delete(ctx context.Context, userID string) error {
+	_, err := r.db.Pool.Exec(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, userID)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete user MFA")
+	}
+	return nil
+}
@@ -0,0 +1,886 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// NewsletterRepository implements repository.NewsletterRepository with PostgreSQL
+type NewsletterRepository struct {
+	db *PostgresDB
+}
+
+// NewNewsletterRepository creates a new PostgreSQL newsletter repository
+func NewNewsletterRepository(db *PostgresDB) *NewsletterRepository {
+	return &NewsletterRepository{db: db}
+}
+
+const newsletterColumns = `
+	id, tenant_id, name, description, channel_id, segment_tags, content_type, content, metadata,
+	schedule, frequency_cap, frequency_cap_hours, opt_in_keyword, unsubscribe_keywords,
+	welcome_message, unsubscribe_message, smart_send, is_active, next_run_at, last_run_at, created_at, updated_at,
+	segment_id, image, suppress_inactive_days
+`
+
+const newsletterSubscriptionColumns = `
+	id, tenant_id, newsletter_id, contact_id, status, source, subscribed_at, unsubscribed_at, updated_at
+`
+
+const newsletterEditionColumns = `
+	id, tenant_id, newsletter_id, content_type, content, triggered_by, status,
+	recipients, sent, capped, failed, scheduled, smart_send, started_at, completed_at, render_stats,
+	global_capped, suppressed
+`
+
+const newsletterDeliveryColumns = `
+	id, tenant_id, newsletter_id, edition_id, contact_id, conversation_id, message_id,
+	status, timing, scheduled_at, error, replied_at, unsubscribed_at, created_at
+`
+
+// Create creates a new newsletter
+func (r *NewsletterRepository) Create(ctx context.Context, newsletter *entity.Newsletter) error {
+	metadata, schedule, err := marshalNewsletter(newsletter)
+	if err != nil {
+		return err
+	}
+	smartSend, err := marshalSmartSend(newsletter.SmartSend)
+	if err != nil {
+		return err
+	}
+	image, err := marshalNewsletterJSON(newsletter.Image, "image")
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO newsletters (` + newsletterColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25)`
+	_, err = r.db.Pool.Exec(ctx, query,
+		newsletter.ID,
+		newsletter.TenantID,
+		newsletter.Name,
+		newsletter.Description,
+		newsletter.ChannelID,
+		newsletter.SegmentTags,
+		string(newsletter.ContentType),
+		newsletter.Content,
+		metadata,
+		schedule,
+		newsletter.FrequencyCap,
+		newsletter.FrequencyCapHours,
+		newsletter.OptInKeyword,
+		newsletter.UnsubscribeKeywords,
+		newsletter.WelcomeMessage,
+		newsletter.UnsubscribeMessage,
+		smartSend,
+		newsletter.IsActive,
+		newsletter.NextRunAt,
+		newsletter.LastRunAt,
+		newsletter.CreatedAt,
+		newsletter.UpdatedAt,
+		nullString(newsletter.SegmentID),
+		image,
+		newsletter.SuppressInactiveDays,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create newsletter")
+	}
+	return nil
+}
+
+// FindByID finds a newsletter by ID
+func (r *NewsletterRepository) FindByID(ctx context.Context, id string) (*entity.Newsletter, error) {
+	query := `SELECT ` + newsletterColumns + ` FROM newsletters WHERE id = $1`
+	return r.scanNewsletter(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// ListByTenant lists the newsletters of a tenant
+func (r *NewsletterRepository) ListByTenant(ctx context.Context, tenantID string) ([]*entity.Newsletter, error) {
+	query := `SELECT ` + newsletterColumns + ` FROM newsletters WHERE tenant_id = $1 ORDER BY name`
+	return r.queryNewsletters(ctx, query, tenantID)
+}
+
+// ListByChannel lists the newsletters sent on a channel
+func (r *NewsletterRepository) ListByChannel(ctx context.Context, channelID string) ([]*entity.Newsletter, error) {
+	query := `SELECT ` + newsletterColumns + ` FROM newsletters WHERE channel_id = $1 ORDER BY created_at`
+	return r.queryNewsletters(ctx, query, channelID)
+}
+
+// ListDue lists the active newsletters whose next run is at or before the given time
+func (r *NewsletterRepository) ListDue(ctx context.Context, now time.Time) ([]*entity.Newsletter, error) {
+	query := `SELECT ` + newsletterColumns + ` FROM newsletters
+		WHERE is_active = TRUE AND next_run_at IS NOT NULL AND next_run_at <= $1
+		ORDER BY next_run_at`
+	return r.queryNewsletters(ctx, query, now)
+}
+
+// Update updates a newsletter
+func (r *NewsletterRepository) Update(ctx context.Context, newsletter *entity.Newsletter) error {
+	metadata, schedule, err := marshalNewsletter(newsletter)
+	if err != nil {
+		return err
+	}
+	smartSend, err := marshalSmartSend(newsletter.SmartSend)
+	if err != nil {
+		return err
+	}
+	image, err := marshalNewsletterJSON(newsletter.Image, "image")
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE newsletters
+		SET name = $2, description = $3, channel_id = $4, segment_tags = $5, content_type = $6,
+		    content = $7, metadata = $8, schedule = $9, frequency_cap = $10, frequency_cap_hours = $11,
+		    opt_in_keyword = $12, unsubscribe_keywords = $13, welcome_message = $14,
+		    unsubscribe_message = $15, smart_send = $16, is_active = $17, next_run_at = $18, last_run_at = $19,
+		    updated_at = $20, segment_id = $21, image = $22, suppress_inactive_days = $23
+		WHERE id = $1
+	`
+	result, err := r.db.Pool.Exec(ctx, query,
+		newsletter.ID,
+		newsletter.Name,
+		newsletter.Description,
+		newsletter.ChannelID,
+		newsletter.SegmentTags,
+		string(newsletter.ContentType),
+		newsletter.Content,
+		metadata,
+		schedule,
+		newsletter.FrequencyCap,
+		newsletter.FrequencyCapHours,
+		newsletter.OptInKeyword,
+		newsletter.UnsubscribeKeywords,
+		newsletter.WelcomeMessage,
+		newsletter.UnsubscribeMessage,
+		smartSend,
+		newsletter.IsActive,
+		newsletter.NextRunAt,
+		newsletter.LastRunAt,
+		newsletter.UpdatedAt,
+		nullString(newsletter.SegmentID),
+		image,
+		newsletter.SuppressInactiveDays,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update newsletter")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "newsletter not found")
+	}
+	return nil
+}
+
+// Delete deletes a newsletter with its subscriptions and editions
+func (r *NewsletterRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.Pool.Exec(ctx, `DELETE FROM newsletters WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete newsletter")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "newsletter not found")
+	}
+	return nil
+}
+
+// UpsertSubscription creates or replaces the subscription of a contact to a newsletter
+func (r *NewsletterRepository) UpsertSubscription(ctx context.Context, subscription *entity.NewsletterSubscription) error {
+	query := `
+		INSERT INTO newsletter_subscriptions (` + newsletterSubscriptionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (newsletter_id, contact_id) DO UPDATE
+		SET status = EXCLUDED.status, source = EXCLUDED.source, subscribed_at = EXCLUDED.subscribed_at,
+		    unsubscribed_at = EXCLUDED.unsubscribed_at, updated_at = EXCLUDED.updated_at
+		RETURNING id
+	`
+	err := r.db.Pool.QueryRow(ctx, query,
+		subscription.ID,
+		subscription.TenantID,
+		subscription.NewsletterID,
+		subscription.ContactID,
+		string(subscription.Status),
+		subscription.Source,
+		subscription.SubscribedAt,
+		subscription.UnsubscribedAt,
+		subscription.UpdatedAt,
+	).Scan(&subscription.ID)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to save newsletter subscription")
+	}
+	return nil
+}
+
+// FindSubscription finds the subscription of a contact to a newsletter
+func (r *NewsletterRepository) FindSubscription(ctx context.Context, newsletterID, contactID string) (*entity.NewsletterSubscription, error) {
+	query := `SELECT ` + newsletterSubscriptionColumns + ` FROM newsletter_subscriptions
+		WHERE newsletter_id = $1 AND contact_id = $2`
+	return r.scanSubscription(r.db.Pool.QueryRow(ctx, query, newsletterID, contactID))
+}
+
+// ListSubscriptions lists the subscriptions of a newsletter, optionally by status
+func (r *NewsletterRepository) ListSubscriptions(ctx context.Context, newsletterID string, status entity.NewsletterSubscriptionStatus, params *repository.ListParams) ([]*entity.NewsletterSubscription, int64, error) {
+	conditions := "newsletter_id = $1"
+	args := []interface{}{newsletterID}
+	if status != "" {
+		args = append(args, string(status))
+		conditions += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM newsletter_subscriptions WHERE "+conditions, args...).Scan(&total); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count newsletter subscriptions")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM newsletter_subscriptions
+		WHERE %s
+		ORDER BY updated_at DESC
+		LIMIT $%d OFFSET $%d
+	`, newsletterSubscriptionColumns, conditions, len(args)+1, len(args)+2)
+	args = append(args, params.Limit(), params.Offset())
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to list newsletter subscriptions")
+	}
+	defer rows.Close()
+
+	var subscriptions []*entity.NewsletterSubscription
+	for rows.Next() {
+		subscription, err := r.scanSubscription(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate newsletter subscriptions")
+	}
+	return subscriptions, total, nil
+}
+
+// ListSubscribedContactIDs returns the contacts currently subscribed to a newsletter
+func (r *NewsletterRepository) ListSubscribedContactIDs(ctx context.Context, newsletterID string) ([]string, error) {
+	query := `SELECT contact_id FROM newsletter_subscriptions
+		WHERE newsletter_id = $1 AND status = 'subscribed'
+		ORDER BY subscribed_at`
+
+	rows, err := r.db.Pool.Query(ctx, query, newsletterID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list newsletter subscribers")
+	}
+	defer rows.Close()
+
+	var contactIDs []string
+	for rows.Next() {
+		var contactID string
+		if err := rows.Scan(&contactID); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan newsletter subscriber")
+		}
+		contactIDs = append(contactIDs, contactID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate newsletter subscribers")
+	}
+	return contactIDs, nil
+}
+
+// CreateEdition creates a new edition
+func (r *NewsletterRepository) CreateEdition(ctx context.Context, edition *entity.NewsletterEdition) error {
+	renders, err := marshalNewsletterJSON(edition.Renders, "render stats")
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO newsletter_editions (` + newsletterEditionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)`
+	_, err = r.db.Pool.Exec(ctx, query,
+		edition.ID,
+		edition.TenantID,
+		edition.NewsletterID,
+		string(edition.ContentType),
+		edition.Content,
+		edition.Trigger,
+		string(edition.Status),
+		edition.Recipients,
+		edition.Sent,
+		edition.Capped,
+		edition.Failed,
+		edition.Scheduled,
+		edition.SmartSend,
+		edition.StartedAt,
+		edition.CompletedAt,
+		renders,
+		edition.GlobalCapped,
+		edition.Suppressed,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create newsletter edition")
+	}
+	return nil
+}
+
+// UpdateEdition updates the status and counters of an edition
+func (r *NewsletterRepository) UpdateEdition(ctx context.Context, edition *entity.NewsletterEdition) error {
+	renders, err := marshalNewsletterJSON(edition.Renders, "render stats")
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE newsletter_editions
+		SET status = $2, recipients = $3, sent = $4, capped = $5, failed = $6, scheduled = $7, completed_at = $8,
+		    render_stats = $9, global_capped = $10, suppressed = $11
+		WHERE id = $1
+	`
+	result, err := r.db.Pool.Exec(ctx, query,
+		edition.ID,
+		string(edition.Status),
+		edition.Recipients,
+		edition.Sent,
+		edition.Capped,
+		edition.Failed,
+		edition.Scheduled,
+		edition.CompletedAt,
+		renders,
+		edition.GlobalCapped,
+		edition.Suppressed,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update newsletter edition")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "newsletter edition not found")
+	}
+	return nil
+}
+
+// FindEditionByID finds an edition by ID
+func (r *NewsletterRepository) FindEditionByID(ctx context.Context, id string) (*entity.NewsletterEdition, error) {
+	query := `SELECT ` + newsletterEditionColumns + ` FROM newsletter_editions WHERE id = $1`
+	return r.scanEdition(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// ListEditions lists the editions of a newsletter, newest first
+func (r *NewsletterRepository) ListEditions(ctx context.Context, newsletterID string, params *repository.ListParams) ([]*entity.NewsletterEdition, int64, error) {
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx, `SELECT COUNT(*) FROM newsletter_editions WHERE newsletter_id = $1`, newsletterID).Scan(&total); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count newsletter editions")
+	}
+
+	query := `SELECT ` + newsletterEditionColumns + ` FROM newsletter_editions
+		WHERE newsletter_id = $1
+		ORDER BY started_at DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.Pool.Query(ctx, query, newsletterID, params.Limit(), params.Offset())
+	if err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to list newsletter editions")
+	}
+	defer rows.Close()
+
+	var editions []*entity.NewsletterEdition
+	for rows.Next() {
+		edition, err := r.scanEdition(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		editions = append(editions, edition)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate newsletter editions")
+	}
+	return editions, total, nil
+}
+
+// CreateDelivery records the send of an edition to a contact
+func (r *NewsletterRepository) CreateDelivery(ctx context.Context, delivery *entity.NewsletterDelivery) error {
+	query := `INSERT INTO newsletter_deliveries (` + newsletterDeliveryColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+	_, err := r.db.Pool.Exec(ctx, query,
+		delivery.ID,
+		delivery.TenantID,
+		delivery.NewsletterID,
+		delivery.EditionID,
+		delivery.ContactID,
+		delivery.ConversationID,
+		delivery.MessageID,
+		string(delivery.Status),
+		string(delivery.Timing),
+		delivery.ScheduledAt,
+		delivery.Error,
+		delivery.RepliedAt,
+		delivery.UnsubscribedAt,
+		delivery.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create newsletter delivery")
+	}
+	return nil
+}
+
+// UpdateDelivery updates the outcome of a delivery
+func (r *NewsletterRepository) UpdateDelivery(ctx context.Context, delivery *entity.NewsletterDelivery) error {
+	query := `
+		UPDATE newsletter_deliveries
+		SET conversation_id = $2, message_id = $3, status = $4, error = $5, created_at = $6
+		WHERE id = $1
+	`
+	result, err := r.db.Pool.Exec(ctx, query,
+		delivery.ID,
+		delivery.ConversationID,
+		delivery.MessageID,
+		string(delivery.Status),
+		delivery.Error,
+		delivery.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update newsletter delivery")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "newsletter delivery not found")
+	}
+	return nil
+}
+
+// ListDueDeliveries lists the scheduled deliveries whose send time is at or before the given time
+func (r *NewsletterRepository) ListDueDeliveries(ctx context.Context, now time.Time) ([]*entity.NewsletterDelivery, error) {
+	query := `SELECT ` + newsletterDeliveryColumns + ` FROM newsletter_deliveries
+		WHERE status = 'scheduled' AND scheduled_at <= $1
+		ORDER BY scheduled_at`
+
+	rows, err := r.db.Pool.Query(ctx, query, now)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list scheduled newsletter deliveries")
+	}
+	defer rows.Close()
+
+	var deliveries []*entity.NewsletterDelivery
+	for rows.Next() {
+		delivery, err := r.scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate scheduled newsletter deliveries")
+	}
+	return deliveries, nil
+}
+
+// CountRecentDeliveries counts the newsletter messages sent to a contact since the given time
+func (r *NewsletterRepository) CountRecentDeliveries(ctx context.Context, tenantID, contactID string, since time.Time) (int, error) {
+	query := `SELECT COUNT(*) FROM newsletter_deliveries
+		WHERE tenant_id = $1 AND contact_id = $2 AND status = 'sent' AND created_at >= $3`
+
+	var count int
+	if err := r.db.Pool.QueryRow(ctx, query, tenantID, contactID, since).Scan(&count); err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count newsletter deliveries")
+	}
+	return count, nil
+}
+
+// CountRecentMarketingMessages counts the marketing messages sent to a contact on any
+// channel since the given time: campaign sends and marketing templates
+func (r *NewsletterRepository) CountRecentMarketingMessages(ctx context.Context, tenantID, contactID string, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM messages m
+		JOIN conversations c ON c.id = m.conversation_id
+		WHERE c.tenant_id = $1 AND c.contact_id = $2
+		  AND m.sender_type <> 'contact' AND m.status <> 'failed' AND m.created_at >= $3
+		  AND (COALESCE(m.metadata->>'campaign_id', '') <> ''
+		    OR UPPER(COALESCE(m.metadata->>'template_category', '')) = 'MARKETING')
+	`
+
+	var count int
+	if err := r.db.Pool.QueryRow(ctx, query, tenantID, contactID, since).Scan(&count); err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count marketing messages")
+	}
+	return count, nil
+}
+
+// FindLastEngagement returns when a contact last read or replied to a message, nil when never
+func (r *NewsletterRepository) FindLastEngagement(ctx context.Context, contactID string) (*time.Time, error) {
+	query := `
+		SELECT MAX(CASE WHEN m.sender_type = 'contact' THEN m.created_at ELSE m.read_at END)
+		FROM messages m
+		JOIN conversations c ON c.id = m.conversation_id
+		WHERE c.contact_id = $1
+	`
+
+	var last *time.Time
+	if err := r.db.Pool.QueryRow(ctx, query, contactID).Scan(&last); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get last contact engagement")
+	}
+	return last, nil
+}
+
+// MarkReplied marks the latest delivery on a conversation sent since the given time as replied
+func (r *NewsletterRepository) MarkReplied(ctx context.Context, conversationID string, since, at time.Time) error {
+	query := `
+		UPDATE newsletter_deliveries SET replied_at = $3
+		WHERE id = (
+			SELECT id FROM newsletter_deliveries
+			WHERE conversation_id = $1 AND status = 'sent' AND created_at >= $2
+			ORDER BY created_at DESC
+			LIMIT 1
+		) AND replied_at IS NULL
+	`
+	if _, err := r.db.Pool.Exec(ctx, query, conversationID, since, at); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to mark newsletter reply")
+	}
+	return nil
+}
+
+// MarkUnsubscribed marks the latest delivery of a newsletter to a contact as the one that caused an unsubscribe
+func (r *NewsletterRepository) MarkUnsubscribed(ctx context.Context, newsletterID, contactID string, at time.Time) error {
+	query := `
+		UPDATE newsletter_deliveries SET unsubscribed_at = $3
+		WHERE id = (
+			SELECT id FROM newsletter_deliveries
+			WHERE newsletter_id = $1 AND contact_id = $2 AND status = 'sent'
+			ORDER BY created_at DESC
+			LIMIT 1
+		) AND unsubscribed_at IS NULL
+	`
+	if _, err := r.db.Pool.Exec(ctx, query, newsletterID, contactID, at); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to mark newsletter unsubscribe")
+	}
+	return nil
+}
+
+// GetEditionStats aggregates the engagement of an edition, optionally of the
+// deliveries with the given send timing only
+func (r *NewsletterRepository) GetEditionStats(ctx context.Context, editionID string, timing entity.NewsletterSendTiming) (*entity.NewsletterEditionStats, error) {
+	query := `
+		SELECT
+			COUNT(*) FILTER (WHERE d.status = 'sent'),
+			COUNT(*) FILTER (WHERE d.status = 'capped'),
+			COUNT(*) FILTER (WHERE d.status = 'suppressed'),
+			COUNT(*) FILTER (WHERE d.status = 'failed'),
+			COUNT(*) FILTER (WHERE d.status = 'sent' AND m.status IN ('delivered', 'read')),
+			COUNT(*) FILTER (WHERE d.status = 'sent' AND m.status = 'read'),
+			COUNT(*) FILTER (WHERE d.replied_at IS NOT NULL),
+			COUNT(*) FILTER (WHERE d.unsubscribed_at IS NOT NULL)
+		FROM newsletter_deliveries d
+		LEFT JOIN messages m ON m.id::text = d.message_id
+		WHERE d.edition_id = $1 AND ($2 = '' OR d.timing = $2)
+	`
+
+	var stats entity.NewsletterEditionStats
+	err := r.db.Pool.QueryRow(ctx, query, editionID, string(timing)).Scan(
+		&stats.Sent,
+		&stats.Capped,
+		&stats.Suppressed,
+		&stats.Failed,
+		&stats.Delivered,
+		&stats.Read,
+		&stats.Replied,
+		&stats.Unsubscribed,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get newsletter edition stats")
+	}
+	return &stats, nil
+}
+
+// GetRenderStats sums the personalized image rendering of a newsletter's editions
+func (r *NewsletterRepository) GetRenderStats(ctx context.Context, newsletterID string) (*entity.NewsletterRenderSummary, error) {
+	query := `
+		SELECT
+			COUNT(*),
+			COALESCE(SUM((render_stats->>'rendered')::int), 0),
+			COALESCE(SUM((render_stats->>'cache_hits')::int), 0),
+			COALESCE(SUM((render_stats->>'failed')::int), 0),
+			COALESCE(SUM((render_stats->>'render_time_ms')::bigint), 0)
+		FROM newsletter_editions
+		WHERE newsletter_id = $1 AND render_stats IS NOT NULL
+	`
+
+	var summary entity.NewsletterRenderSummary
+	err := r.db.Pool.QueryRow(ctx, query, newsletterID).Scan(
+		&summary.Editions,
+		&summary.Rendered,
+		&summary.CacheHits,
+		&summary.Failed,
+		&summary.RenderTimeMs,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get newsletter render stats")
+	}
+	return &summary, nil
+}
+
+// GetContactEngagement counts the reads and replies of a contact since the given time by hour of the day
+func (r *NewsletterRepository) GetContactEngagement(ctx context.Context, contactID string, since time.Time) (*entity.ContactEngagement, error) {
+	query := `
+		SELECT m.sender_type = 'contact',
+		       EXTRACT(HOUR FROM COALESCE(m.read_at, m.created_at) AT TIME ZONE 'UTC')::int,
+		       COUNT(*)
+		FROM messages m
+		JOIN conversations c ON c.id = m.conversation_id
+		WHERE c.contact_id = $1
+		  AND ((m.sender_type = 'contact' AND m.created_at >= $2)
+		    OR (m.sender_type <> 'contact' AND m.read_at >= $2))
+		GROUP BY 1, 2
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, contactID, since)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to get contact engagement")
+	}
+	defer rows.Close()
+
+	engagement := &entity.ContactEngagement{ContactID: contactID}
+	for rows.Next() {
+		var reply bool
+		var hour, count int
+		if err := rows.Scan(&reply, &hour, &count); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan contact engagement")
+		}
+		if hour < 0 || hour > 23 {
+			continue
+		}
+		if reply {
+			engagement.ReplyHours[hour] += count
+		} else {
+			engagement.ReadHours[hour] += count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate contact engagement")
+	}
+	return engagement, nil
+}
+
+func marshalNewsletter(newsletter *entity.Newsletter) ([]byte, []byte, error) {
+	metadata, err := json.Marshal(newsletter.Metadata)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal newsletter metadata")
+	}
+	schedule, err := json.Marshal(newsletter.Schedule)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal newsletter schedule")
+	}
+	return metadata, schedule, nil
+}
+
+// marshalNewsletterJSON encodes an optional JSONB value, storing NULL for nil
+func marshalNewsletterJSON[T any](value *T, name string) ([]byte, error) {
+	if value == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal newsletter "+name)
+	}
+	return data, nil
+}
+
+func marshalSmartSend(smartSend *entity.NewsletterSmartSend) ([]byte, error) {
+	if smartSend == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(smartSend)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal newsletter smart send")
+	}
+	return data, nil
+}
+
+func (r *NewsletterRepository) queryNewsletters(ctx context.Context, query string, args ...interface{}) ([]*entity.Newsletter, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list newsletters")
+	}
+	defer rows.Close()
+
+	var newsletters []*entity.Newsletter
+	for rows.Next() {
+		newsletter, err := r.scanNewsletter(rows)
+		if err != nil {
+			return nil, err
+		}
+		newsletters = append(newsletters, newsletter)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate newsletters")
+	}
+	return newsletters, nil
+}
+
+func (r *NewsletterRepository) scanNewsletter(row pgx.Row) (*entity.Newsletter, error) {
+	var newsletter entity.Newsletter
+	var contentType string
+	var metadata, schedule, smartSend, image []byte
+	var segmentID *string
+
+	err := row.Scan(
+		&newsletter.ID,
+		&newsletter.TenantID,
+		&newsletter.Name,
+		&newsletter.Description,
+		&newsletter.ChannelID,
+		&newsletter.SegmentTags,
+		&contentType,
+		&newsletter.Content,
+		&metadata,
+		&schedule,
+		&newsletter.FrequencyCap,
+		&newsletter.FrequencyCapHours,
+		&newsletter.OptInKeyword,
+		&newsletter.UnsubscribeKeywords,
+		&newsletter.WelcomeMessage,
+		&newsletter.UnsubscribeMessage,
+		&smartSend,
+		&newsletter.IsActive,
+		&newsletter.NextRunAt,
+		&newsletter.LastRunAt,
+		&newsletter.CreatedAt,
+		&newsletter.UpdatedAt,
+		&segmentID,
+		&image,
+		&newsletter.SuppressInactiveDays,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "newsletter not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan newsletter")
+	}
+
+	newsletter.ContentType = entity.ContentType(contentType)
+	if segmentID != nil {
+		newsletter.SegmentID = *segmentID
+	}
+	if len(metadata) > 0 {
+		if err := json.Unmarshal(metadata, &newsletter.Metadata); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal newsletter metadata")
+		}
+	}
+	if len(schedule) > 0 {
+		if err := json.Unmarshal(schedule, &newsletter.Schedule); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal newsletter schedule")
+		}
+	}
+	if len(smartSend) > 0 {
+		if err := json.Unmarshal(smartSend, &newsletter.SmartSend); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal newsletter smart send")
+		}
+	}
+	if len(image) > 0 {
+		if err := json.Unmarshal(image, &newsletter.Image); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal newsletter image")
+		}
+	}
+	return &newsletter, nil
+}
+
+func (r *NewsletterRepository) scanSubscription(row pgx.Row) (*entity.NewsletterSubscription, error) {
+	var subscription entity.NewsletterSubscription
+	var status string
+
+	err := row.Scan(
+		&subscription.ID,
+		&subscription.TenantID,
+		&subscription.NewsletterID,
+		&subscription.ContactID,
+		&status,
+		&subscription.Source,
+		&subscription.SubscribedAt,
+		&subscription.UnsubscribedAt,
+		&subscription.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "newsletter subscription not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan newsletter subscription")
+	}
+
+	subscription.Status = entity.NewsletterSubscriptionStatus(status)
+	return &subscription, nil
+}
+
+func (r *NewsletterRepository) scanEdition(row pgx.Row) (*entity.NewsletterEdition, error) {
+	var edition entity.NewsletterEdition
+	var contentType, status string
+	var renders []byte
+
+	err := row.Scan(
+		&edition.ID,
+		&edition.TenantID,
+		&edition.NewsletterID,
+		&contentType,
+		&edition.Content,
+		&edition.Trigger,
+		&status,
+		&edition.Recipients,
+		&edition.Sent,
+		&edition.Capped,
+		&edition.Failed,
+		&edition.Scheduled,
+		&edition.SmartSend,
+		&edition.StartedAt,
+		&edition.CompletedAt,
+		&renders,
+		&edition.GlobalCapped,
+		&edition.Suppressed,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "newsletter edition not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan newsletter edition")
+	}
+
+	edition.ContentType = entity.ContentType(contentType)
+	edition.Status = entity.NewsletterEditionStatus(status)
+	if len(renders) > 0 {
+		if err := json.Unmarshal(renders, &edition.Renders); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal newsletter render stats")
+		}
+	}
+	return &edition, nil
+}
+
+func (r *NewsletterRepository) scanDelivery(row pgx.Row) (*entity.NewsletterDelivery, error) {
+	var delivery entity.NewsletterDelivery
+	var status, timing string
+
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.TenantID,
+		&delivery.NewsletterID,
+		&delivery.EditionID,
+		&delivery.ContactID,
+		&delivery.ConversationID,
+		&delivery.MessageID,
+		&status,
+		&timing,
+		&delivery.ScheduledAt,
+		&delivery.Error,
+		&delivery.RepliedAt,
+		&delivery.UnsubscribedAt,
+		&delivery.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "newsletter delivery not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan newsletter delivery")
+	}
+
+	delivery.Status = entity.NewsletterDeliveryStatus(status)
+	delivery.Timing = entity.NewsletterSendTiming(timing)
+	return &delivery, nil
+}
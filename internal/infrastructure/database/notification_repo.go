@@ -0,0 +1,192 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// NotificationRepository implements repository.NotificationRepository with PostgreSQL
+type NotificationRepository struct {
+	db *PostgresDB
+}
+
+// NewNotificationRepository creates a new PostgreSQL notification repository
+func NewNotificationRepository(db *PostgresDB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+const notificationColumns = `
+	id, tenant_id, type, severity, title, message, resource_type, resource_id, key,
+	read_at, resolved_at, created_at, updated_at
+`
+
+// Raise creates a notification. One with a key replaces the content of the open
+// notification with the same key, if any, which becomes unread again.
+func (r *NotificationRepository) Raise(ctx context.Context, notification *entity.Notification) error {
+	query := `INSERT INTO notifications (` + notificationColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (tenant_id, key) WHERE resolved_at IS NULL AND key <> '' DO UPDATE SET
+			type = EXCLUDED.type,
+			severity = EXCLUDED.severity,
+			title = EXCLUDED.title,
+			message = EXCLUDED.message,
+			resource_type = EXCLUDED.resource_type,
+			resource_id = EXCLUDED.resource_id,
+			read_at = NULL,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at`
+	err := r.db.Pool.QueryRow(ctx, query,
+		notification.ID,
+		notification.TenantID,
+		notification.Type,
+		string(notification.Severity),
+		notification.Title,
+		notification.Message,
+		notification.ResourceType,
+		notification.ResourceID,
+		notification.Key,
+		notification.ReadAt,
+		notification.ResolvedAt,
+		notification.CreatedAt,
+		notification.UpdatedAt,
+	).Scan(&notification.ID, &notification.CreatedAt)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to raise notification")
+	}
+	notification.ReadAt = nil
+	return nil
+}
+
+// Resolve resolves the open notification of a tenant with the key, if any
+func (r *NotificationRepository) Resolve(ctx context.Context, tenantID, key string, at time.Time) error {
+	_, err := r.db.Pool.Exec(ctx, `
+		UPDATE notifications SET resolved_at = $3, updated_at = $3
+		WHERE tenant_id = $1 AND key = $2 AND resolved_at IS NULL
+	`, tenantID, key, at)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to resolve notification")
+	}
+	return nil
+}
+
+// FindByTenant lists the notifications of a tenant, newest first
+func (r *NotificationRepository) FindByTenant(ctx context.Context, tenantID string, filter *entity.NotificationFilter, params *repository.ListParams) ([]*entity.Notification, int64, error) {
+	if params == nil {
+		params = repository.NewListParams()
+	}
+
+	conditions := "tenant_id = $1"
+	args := []interface{}{tenantID}
+	if filter == nil || !filter.IncludeResolved {
+		conditions += " AND resolved_at IS NULL"
+	}
+	if filter != nil && filter.UnreadOnly {
+		conditions += " AND read_at IS NULL"
+	}
+
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM notifications WHERE "+conditions, args...).Scan(&total); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count notifications")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM notifications
+		WHERE %s
+		ORDER BY updated_at DESC
+		LIMIT $%d OFFSET $%d
+	`, notificationColumns, conditions, len(args)+1, len(args)+2)
+	args = append(args, params.Limit(), params.Offset())
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to list notifications")
+	}
+	defer rows.Close()
+
+	notifications := make([]*entity.Notification, 0)
+	for rows.Next() {
+		notification, err := r.scanNotification(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		notifications = append(notifications, notification)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate notifications")
+	}
+	return notifications, total, nil
+}
+
+// CountUnread counts the unread, unresolved notifications of a tenant
+func (r *NotificationRepository) CountUnread(ctx context.Context, tenantID string) (int64, error) {
+	var count int64
+	err := r.db.Pool.QueryRow(ctx,
+		"SELECT COUNT(*) FROM notifications WHERE tenant_id = $1 AND read_at IS NULL AND resolved_at IS NULL",
+		tenantID,
+	).Scan(&count)
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count unread notifications")
+	}
+	return count, nil
+}
+
+// MarkRead marks a notification of a tenant as read
+func (r *NotificationRepository) MarkRead(ctx context.Context, tenantID, id string, at time.Time) error {
+	result, err := r.db.Pool.Exec(ctx,
+		"UPDATE notifications SET read_at = COALESCE(read_at, $3) WHERE tenant_id = $1 AND id = $2",
+		tenantID, id, at,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to mark notification as read")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "notification not found")
+	}
+	return nil
+}
+
+// MarkAllRead marks every notification of a tenant as read, returning how many were unread
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, tenantID string, at time.Time) (int64, error) {
+	result, err := r.db.Pool.Exec(ctx,
+		"UPDATE notifications SET read_at = $2 WHERE tenant_id = $1 AND read_at IS NULL",
+		tenantID, at,
+	)
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to mark notifications as read")
+	}
+	return result.RowsAffected(), nil
+}
+
+func (r *NotificationRepository) scanNotification(row pgx.Row) (*entity.Notification, error) {
+	var notification entity.Notification
+	var severity string
+	err := row.Scan(
+		&notification.ID,
+		&notification.TenantID,
+		&notification.Type,
+		&severity,
+		&notification.Title,
+		&notification.Message,
+		&notification.ResourceType,
+		&notification.ResourceID,
+		&notification.Key,
+		&notification.ReadAt,
+		&notification.ResolvedAt,
+		&notification.CreatedAt,
+		&notification.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "notification not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan notification")
+	}
+	notification.Severity = entity.NotificationSeverity(severity)
+	return &notification, nil
+}
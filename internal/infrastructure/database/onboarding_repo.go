@@ -0,0 +1,97 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// OnboardingRepository implements repository.OnboardingRepository with PostgreSQL
+type OnboardingRepository struct {
+	db *PostgresDB
+}
+
+// NewOnboardingRepository creates a new PostgreSQL onboarding repository
+func NewOnboardingRepository(db *PostgresDB) *OnboardingRepository {
+	return &OnboardingRepository{db: db}
+}
+
+const onboardingColumns = `
+	id, tenant_id, steps, sample_resources, completed_at, created_at, updated_at
+`
+
+// FindByTenant finds the onboarding progress of a tenant
+func (r *OnboardingRepository) FindByTenant(ctx context.Context, tenantID string) (*entity.OnboardingProgress, error) {
+	query := `SELECT ` + onboardingColumns + ` FROM onboarding_progress WHERE tenant_id = $1`
+
+	var progress entity.OnboardingProgress
+	var steps, samples []byte
+
+	err := r.db.Pool.QueryRow(ctx, query, tenantID).Scan(
+		&progress.ID,
+		&progress.TenantID,
+		&steps,
+		&samples,
+		&progress.CompletedAt,
+		&progress.CreatedAt,
+		&progress.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "onboarding progress not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find onboarding progress")
+	}
+
+	if len(steps) > 0 {
+		if err := json.Unmarshal(steps, &progress.Steps); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal onboarding steps")
+		}
+	}
+	progress.SampleResources = make(map[string]string)
+	if len(samples) > 0 {
+		if err := json.Unmarshal(samples, &progress.SampleResources); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal onboarding sample resources")
+		}
+	}
+	return &progress, nil
+}
+
+// Save creates or updates the onboarding progress of a tenant
+func (r *OnboardingRepository) Save(ctx context.Context, progress *entity.OnboardingProgress) error {
+	query := `
+		INSERT INTO onboarding_progress (` + onboardingColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			steps = EXCLUDED.steps,
+			sample_resources = EXCLUDED.sample_resources,
+			completed_at = EXCLUDED.completed_at,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	steps, err := json.Marshal(progress.Steps)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal onboarding steps")
+	}
+	samples, err := json.Marshal(progress.SampleResources)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal onboarding sample resources")
+	}
+
+	_, err = r.db.Pool.Exec(ctx, query,
+		progress.ID,
+		progress.TenantID,
+		steps,
+		samples,
+		progress.CompletedAt,
+		progress.CreatedAt,
+		progress.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to save onboarding progress")
+	}
+	return nil
+}
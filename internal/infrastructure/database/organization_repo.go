@@ -0,0 +1,275 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// OrganizationRepository implements repository.OrganizationRepository with PostgreSQL
+type OrganizationRepository struct {
+	db *PostgresDB
+}
+
+// NewOrganizationRepository creates a new PostgreSQL organization repository
+func NewOrganizationRepository(db *PostgresDB) *OrganizationRepository {
+	return &OrganizationRepository{db: db}
+}
+
+const organizationColumns = `id, name, slug, plan, limits, billing_email, created_at, updated_at`
+
+// Create creates an organization with a first workspace and its owner in one transaction
+func (r *OrganizationRepository) Create(ctx context.Context, org *entity.Organization, tenantID string, owner *entity.OrganizationMember) error {
+	limits, err := json.Marshal(org.Limits)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal organization limits")
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to begin organization creation")
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO organizations (`+organizationColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, org.ID, org.Name, org.Slug, string(org.Plan), limits, org.BillingEmail, org.CreatedAt, org.UpdatedAt); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create organization")
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO organization_workspaces (tenant_id, organization_id, created_at)
+		VALUES ($1, $2, $3)
+	`, tenantID, org.ID, org.CreatedAt); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to add workspace")
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO organization_members (organization_id, email, role, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, org.ID, owner.Email, string(owner.Role), owner.CreatedAt, owner.UpdatedAt); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to add organization owner")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to commit organization creation")
+	}
+	return nil
+}
+
+// Update updates an organization
+func (r *OrganizationRepository) Update(ctx context.Context, org *entity.Organization) error {
+	limits, err := json.Marshal(org.Limits)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal organization limits")
+	}
+
+	result, err := r.db.Pool.Exec(ctx, `
+		UPDATE organizations SET name = $2, plan = $3, limits = $4, billing_email = $5, updated_at = $6
+		WHERE id = $1
+	`, org.ID, org.Name, string(org.Plan), limits, org.BillingEmail, org.UpdatedAt)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update organization")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "organization not found")
+	}
+	return nil
+}
+
+// FindByID finds an organization by ID
+func (r *OrganizationRepository) FindByID(ctx context.Context, id string) (*entity.Organization, error) {
+	query := `SELECT ` + organizationColumns + ` FROM organizations WHERE id = $1`
+	return r.findOne(ctx, query, id)
+}
+
+// FindBySlug finds an organization by slug
+func (r *OrganizationRepository) FindBySlug(ctx context.Context, slug string) (*entity.Organization, error) {
+	query := `SELECT ` + organizationColumns + ` FROM organizations WHERE slug = $1`
+	return r.findOne(ctx, query, slug)
+}
+
+// FindByTenant finds the organization a tenant is a workspace of
+func (r *OrganizationRepository) FindByTenant(ctx context.Context, tenantID string) (*entity.Organization, error) {
+	query := `
+		SELECT o.id, o.name, o.slug, o.plan, o.limits, o.billing_email, o.created_at, o.updated_at
+		FROM organizations o
+		JOIN organization_workspaces w ON w.organization_id = o.id
+		WHERE w.tenant_id = $1
+	`
+	return r.findOne(ctx, query, tenantID)
+}
+
+func (r *OrganizationRepository) findOne(ctx context.Context, query string, arg string) (*entity.Organization, error) {
+	org, err := r.scanOrganization(r.db.Pool.QueryRow(ctx, query, arg))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "organization not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find organization")
+	}
+	return org, nil
+}
+
+// AddWorkspace adds a tenant to an organization
+func (r *OrganizationRepository) AddWorkspace(ctx context.Context, organizationID, tenantID string) error {
+	_, err := r.db.Pool.Exec(ctx, `
+		INSERT INTO organization_workspaces (tenant_id, organization_id) VALUES ($1, $2)
+	`, tenantID, organizationID)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to add workspace")
+	}
+	return nil
+}
+
+// ListWorkspaces lists the workspaces of an organization, oldest first
+func (r *OrganizationRepository) ListWorkspaces(ctx context.Context, organizationID string) ([]*entity.OrganizationWorkspace, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT t.id, t.name, t.slug, t.plan, t.status, w.created_at
+		FROM organization_workspaces w
+		JOIN tenants t ON t.id = w.tenant_id
+		WHERE w.organization_id = $1
+		ORDER BY w.created_at, t.name
+	`, organizationID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list workspaces")
+	}
+	defer rows.Close()
+
+	var workspaces []*entity.OrganizationWorkspace
+	for rows.Next() {
+		var w entity.OrganizationWorkspace
+		var plan, status string
+		if err := rows.Scan(&w.TenantID, &w.Name, &w.Slug, &plan, &status, &w.CreatedAt); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan workspace")
+		}
+		w.Plan = entity.Plan(plan)
+		w.Status = entity.TenantStatus(status)
+		workspaces = append(workspaces, &w)
+	}
+	return workspaces, rows.Err()
+}
+
+// SaveMember creates or updates a member
+func (r *OrganizationRepository) SaveMember(ctx context.Context, member *entity.OrganizationMember) error {
+	_, err := r.db.Pool.Exec(ctx, `
+		INSERT INTO organization_members (organization_id, email, role, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (organization_id, email) DO UPDATE SET
+			role = EXCLUDED.role,
+			updated_at = EXCLUDED.updated_at
+	`, member.OrganizationID, member.Email, string(member.Role), member.CreatedAt, member.UpdatedAt)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to save organization member")
+	}
+	return nil
+}
+
+// FindMember finds a member by email
+func (r *OrganizationRepository) FindMember(ctx context.Context, organizationID, email string) (*entity.OrganizationMember, error) {
+	var m entity.OrganizationMember
+	var role string
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT organization_id, email, role, created_at, updated_at
+		FROM organization_members
+		WHERE organization_id = $1 AND email = $2
+	`, organizationID, email).Scan(&m.OrganizationID, &m.Email, &role, &m.CreatedAt, &m.UpdatedAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "organization member not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find organization member")
+	}
+	m.Role = entity.OrganizationRole(role)
+	return &m, nil
+}
+
+// ListMembers lists the members of an organization
+func (r *OrganizationRepository) ListMembers(ctx context.Context, organizationID string) ([]*entity.OrganizationMember, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT organization_id, email, role, created_at, updated_at
+		FROM organization_members
+		WHERE organization_id = $1
+		ORDER BY email
+	`, organizationID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list organization members")
+	}
+	defer rows.Close()
+
+	var members []*entity.OrganizationMember
+	for rows.Next() {
+		var m entity.OrganizationMember
+		var role string
+		if err := rows.Scan(&m.OrganizationID, &m.Email, &role, &m.CreatedAt, &m.UpdatedAt); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan organization member")
+		}
+		m.Role = entity.OrganizationRole(role)
+		members = append(members, &m)
+	}
+	return members, rows.Err()
+}
+
+// DeleteMember removes a member
+func (r *OrganizationRepository) DeleteMember(ctx context.Context, organizationID, email string) error {
+	result, err := r.db.Pool.Exec(ctx, `
+		DELETE FROM organization_members WHERE organization_id = $1 AND email = $2
+	`, organizationID, email)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete organization member")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "organization member not found")
+	}
+	return nil
+}
+
+// ListAccounts lists the users with an email in the workspaces of an organization
+func (r *OrganizationRepository) ListAccounts(ctx context.Context, organizationID, email string) ([]*entity.User, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT u.id, u.tenant_id, u.email, u.password_hash, u.name, u.role, u.avatar_url,
+		       u.status, u.last_login_at, u.created_at, u.updated_at
+		FROM users u
+		JOIN organization_workspaces w ON w.tenant_id = u.tenant_id
+		WHERE w.organization_id = $1 AND u.email = $2
+		ORDER BY u.created_at
+	`, organizationID, email)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list accounts")
+	}
+	defer rows.Close()
+
+	users := &UserRepository{db: r.db}
+	var accounts []*entity.User
+	for rows.Next() {
+		user, err := users.scanUserFromRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, user)
+	}
+	return accounts, rows.Err()
+}
+
+func (r *OrganizationRepository) scanOrganization(row pgx.Row) (*entity.Organization, error) {
+	var o entity.Organization
+	var plan string
+	var limitsJSON []byte
+
+	err := row.Scan(&o.ID, &o.Name, &o.Slug, &plan, &limitsJSON, &o.BillingEmail, &o.CreatedAt, &o.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	o.Plan = entity.Plan(plan)
+	if len(limitsJSON) > 0 && string(limitsJSON) != "null" {
+		if err := json.Unmarshal(limitsJSON, &o.Limits); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal limits")
+		}
+	}
+	return &o, nil
+}
@@ -0,0 +1,160 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/whatsapp/payments"
+)
+
+const paymentRefundColumns = `
+	id, payment_id, organization_id, channel_id, amount, currency, status, reason,
+	gateway_refund_id, failure_reason, requested_by, reviewed_by, review_note,
+	reviewed_at, created_at, processed_at
+`
+
+// CreateRefund creates a refund record
+func (r *PaymentRepository) CreateRefund(ctx context.Context, refund *payments.Refund) error {
+	query := `INSERT INTO whatsapp_payment_refunds (` + paymentRefundColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`
+
+	_, err := r.db.Pool.Exec(ctx, query,
+		refund.ID,
+		refund.PaymentID,
+		refund.OrganizationID,
+		refund.ChannelID,
+		refund.Amount,
+		refund.Currency,
+		string(refund.Status),
+		refund.Reason,
+		refund.GatewayRefundID,
+		refund.FailureReason,
+		refund.RequestedBy,
+		refund.ReviewedBy,
+		refund.ReviewNote,
+		refund.ReviewedAt,
+		refund.CreatedAt,
+		refund.ProcessedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create refund: %w", err)
+	}
+
+	return nil
+}
+
+// GetRefund retrieves a refund by its ID
+func (r *PaymentRepository) GetRefund(ctx context.Context, id string) (*payments.Refund, error) {
+	query := `SELECT ` + paymentRefundColumns + ` FROM whatsapp_payment_refunds WHERE id = $1`
+
+	refund, err := scanPaymentRefund(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("refund not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to get refund: %w", err)
+	}
+
+	return refund, nil
+}
+
+// UpdateRefund updates the state of a refund
+func (r *PaymentRepository) UpdateRefund(ctx context.Context, refund *payments.Refund) error {
+	query := `
+		UPDATE whatsapp_payment_refunds SET
+			status = $1,
+			gateway_refund_id = $2,
+			failure_reason = $3,
+			reviewed_by = $4,
+			review_note = $5,
+			reviewed_at = $6,
+			processed_at = $7
+		WHERE id = $8
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query,
+		string(refund.Status),
+		refund.GatewayRefundID,
+		refund.FailureReason,
+		refund.ReviewedBy,
+		refund.ReviewNote,
+		refund.ReviewedAt,
+		refund.ProcessedAt,
+		refund.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update refund: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("refund not found: %s", refund.ID)
+	}
+
+	return nil
+}
+
+// ListRefunds lists the refunds of an organization, newest first
+func (r *PaymentRepository) ListRefunds(ctx context.Context, orgID string, filter *payments.RefundFilter) ([]*payments.Refund, error) {
+	conditions := "organization_id = $1"
+	args := []interface{}{orgID}
+	if filter != nil {
+		if filter.ChannelID != "" {
+			args = append(args, filter.ChannelID)
+			conditions += fmt.Sprintf(" AND channel_id = $%d", len(args))
+		}
+		if filter.Status != "" {
+			args = append(args, string(filter.Status))
+			conditions += fmt.Sprintf(" AND status = $%d", len(args))
+		}
+		if filter.From != nil {
+			args = append(args, *filter.From)
+			conditions += fmt.Sprintf(" AND created_at >= $%d", len(args))
+		}
+		if filter.To != nil {
+			args = append(args, *filter.To)
+			conditions += fmt.Sprintf(" AND created_at < $%d", len(args))
+		}
+	}
+
+	query := `SELECT ` + paymentRefundColumns + ` FROM whatsapp_payment_refunds WHERE ` + conditions + ` ORDER BY created_at DESC`
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query refunds: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*payments.Refund
+	for rows.Next() {
+		refund, err := scanPaymentRefund(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan refund: %w", err)
+		}
+		result = append(result, refund)
+	}
+
+	return result, rows.Err()
+}
+
+// scanPaymentRefund scans a single refund row
+func scanPaymentRefund(row pgx.Row) (*payments.Refund, error) {
+	var refund payments.Refund
+	var status string
+
+	err := row.Scan(
+		&refund.ID, &refund.PaymentID, &refund.OrganizationID, &refund.ChannelID,
+		&refund.Amount, &refund.Currency, &status, &refund.Reason,
+		&refund.GatewayRefundID, &refund.FailureReason, &refund.RequestedBy, &refund.ReviewedBy, &refund.ReviewNote,
+		&refund.ReviewedAt, &refund.CreatedAt, &refund.ProcessedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	refund.Status = payments.RefundStatus(status)
+	return &refund, nil
+}
+
+// Ensure PaymentRepository implements payments.RefundStore
+var _ payments.RefundStore = (*PaymentRepository)(nil)
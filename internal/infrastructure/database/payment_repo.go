@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/jackc/pgx/v5"
@@ -29,10 +30,12 @@ func (r *PaymentRepository) Create(ctx context.Context, payment *payments.Paymen
 		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23)
 	`
 
-	// Payment struct does not have a Metadata field; store as empty JSON object
-	metadata := []byte("{}")
+	metadata, err := marshalPaymentMetadata(payment)
+	if err != nil {
+		return err
+	}
 
-	_, err := r.db.Pool.Exec(ctx, query,
+	_, err = r.db.Pool.Exec(ctx, query,
 		payment.ID,
 		payment.OrganizationID,
 		payment.ChannelID,
@@ -130,8 +133,10 @@ func (r *PaymentRepository) Update(ctx context.Context, payment *payments.Paymen
 		WHERE id = $14
 	`
 
-	// Payment struct does not have a Metadata field; store as empty JSON object
-	metadata := []byte("{}")
+	metadata, err := marshalPaymentMetadata(payment)
+	if err != nil {
+		return err
+	}
 
 	result, err := r.db.Pool.Exec(ctx, query,
 		string(payment.Status),
@@ -227,6 +232,45 @@ func (r *PaymentRepository) GetStats(ctx context.Context, orgID string) (*paymen
 		stats.SuccessRate = float64(stats.SuccessfulPayments) / float64(stats.TotalPayments) * 100
 	}
 
+	// Get totals by currency; amounts in different currencies are never added up
+	currencyQuery := `
+		SELECT
+			currency,
+			COUNT(*) AS total_payments,
+			COALESCE(SUM(CASE WHEN status = 'success' THEN 1 ELSE 0 END), 0) AS successful_payments,
+			COALESCE(SUM(CASE WHEN status = 'success' THEN amount ELSE 0 END), 0) AS total_amount,
+			COALESCE(SUM(CASE WHEN status = 'refunded' THEN amount ELSE 0 END), 0) AS refunded_amount,
+			COALESCE(SUM(CASE WHEN status = 'success' THEN COALESCE((metadata->>'tax_amount')::bigint, 0) ELSE 0 END), 0) AS tax_amount
+		FROM whatsapp_payments
+		WHERE organization_id = $1
+		GROUP BY currency
+		ORDER BY currency
+	`
+
+	currencyRows, err := r.db.Pool.Query(ctx, currencyQuery, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment stats by currency: %w", err)
+	}
+	defer currencyRows.Close()
+
+	for currencyRows.Next() {
+		var cs payments.CurrencyPaymentStats
+		if err := currencyRows.Scan(
+			&cs.Currency,
+			&cs.TotalPayments,
+			&cs.SuccessfulPayments,
+			&cs.TotalAmount,
+			&cs.RefundedAmount,
+			&cs.TaxAmount,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan currency stat: %w", err)
+		}
+		stats.ByCurrency = append(stats.ByCurrency, cs)
+	}
+	if len(stats.ByCurrency) > 1 {
+		stats.Currency = ""
+	}
+
 	// Get counts by status
 	statusQuery := `
 		SELECT status, COUNT(*) AS count
@@ -294,6 +338,10 @@ func scanPayment(row pgx.Row) (*payments.Payment, error) {
 		return nil, err
 	}
 
+	if err := unmarshalPaymentMetadata(metadata, &p); err != nil {
+		return nil, err
+	}
+
 	p.Status = payments.PaymentStatus(status)
 	p.Type = payments.PaymentType(paymentType)
 
@@ -340,6 +388,10 @@ func scanPaymentFromRows(rows pgx.Rows) (*payments.Payment, error) {
 		return nil, fmt.Errorf("failed to scan payment: %w", err)
 	}
 
+	if err := unmarshalPaymentMetadata(metadata, &p); err != nil {
+		return nil, err
+	}
+
 	p.Status = payments.PaymentStatus(status)
 	p.Type = payments.PaymentType(paymentType)
 
@@ -368,6 +420,41 @@ func scanPaymentFromRows(rows pgx.Rows) (*payments.Payment, error) {
 	return &p, nil
 }
 
+// paymentMetadata holds the payment fields kept in the metadata column
+type paymentMetadata struct {
+	Taxes      []payments.TaxLine           `json:"taxes,omitempty"`
+	TaxAmount  int64                        `json:"tax_amount,omitempty"`
+	Conversion *payments.CurrencyConversion `json:"conversion,omitempty"`
+}
+
+// marshalPaymentMetadata encodes the taxes and currency conversion of a payment
+func marshalPaymentMetadata(payment *payments.Payment) ([]byte, error) {
+	metadata, err := json.Marshal(paymentMetadata{
+		Taxes:      payment.Taxes,
+		TaxAmount:  payment.TaxAmount,
+		Conversion: payment.Conversion,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payment metadata: %w", err)
+	}
+	return metadata, nil
+}
+
+// unmarshalPaymentMetadata decodes the taxes and currency conversion of a payment
+func unmarshalPaymentMetadata(data []byte, p *payments.Payment) error {
+	if len(data) == 0 {
+		return nil
+	}
+	var metadata paymentMetadata
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return fmt.Errorf("failed to unmarshal payment metadata: %w", err)
+	}
+	p.Taxes = metadata.Taxes
+	p.TaxAmount = metadata.TaxAmount
+	p.Conversion = metadata.Conversion
+	return nil
+}
+
 // paymentNullString converts an empty string to nil for nullable DB columns
 func paymentNullString(s string) *string {
 	if s == "" {
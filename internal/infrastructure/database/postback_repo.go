@@ -0,0 +1,182 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// PostbackRepository implements repository.PostbackRepository with PostgreSQL
+type PostbackRepository struct {
+	db *PostgresDB
+}
+
+// NewPostbackRepository creates a new PostgreSQL postback repository
+func NewPostbackRepository(db *PostgresDB) *PostbackRepository {
+	return &PostbackRepository{db: db}
+}
+
+// Create stores a new postback
+func (r *PostbackRepository) Create(ctx context.Context, postback *entity.Postback) error {
+	payload, err := json.Marshal(postback.Payload)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal postback payload")
+	}
+
+	query := `
+		INSERT INTO postbacks (
+			id, tenant_id, conversation_id, action, target, node_id, payload,
+			single_use, expires_at, used_at, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`
+
+	_, err = r.db.Pool.Exec(ctx, query,
+		postback.ID,
+		postback.TenantID,
+		postback.ConversationID,
+		string(postback.Action),
+		postback.Target,
+		postback.NodeID,
+		payload,
+		postback.SingleUse,
+		postback.ExpiresAt,
+		postback.UsedAt,
+		postback.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create postback")
+	}
+	return nil
+}
+
+// FindByID finds a postback by ID
+func (r *PostbackRepository) FindByID(ctx context.Context, id string) (*entity.Postback, error) {
+	query := `
+		SELECT id, tenant_id, conversation_id, action, target, node_id, payload,
+		       single_use, expires_at, used_at, created_at
+		FROM postbacks
+		WHERE id = $1
+	`
+
+	var postback entity.Postback
+	var action string
+	var payload []byte
+
+	err := r.db.Pool.QueryRow(ctx, query, id).Scan(
+		&postback.ID,
+		&postback.TenantID,
+		&postback.ConversationID,
+		&action,
+		&postback.Target,
+		&postback.NodeID,
+		&payload,
+		&postback.SingleUse,
+		&postback.ExpiresAt,
+		&postback.UsedAt,
+		&postback.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "postback not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find postback")
+	}
+
+	postback.Action = entity.PostbackAction(action)
+	if len(payload) > 0 {
+		if err := json.Unmarshal(payload, &postback.Payload); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal postback payload")
+		}
+	}
+
+	return &postback, nil
+}
+
+// MarkUsed records that a postback was used. Single-use postbacks are only marked once,
+// so concurrent replies cannot both resume the automation.
+func (r *PostbackRepository) MarkUsed(ctx context.Context, id string, usedAt time.Time) (bool, error) {
+	query := `
+		UPDATE postbacks SET used_at = $2
+		WHERE id = $1 AND (single_use = FALSE OR used_at IS NULL)
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query, id, usedAt)
+	if err != nil {
+		return false, errors.Wrap(err, errors.ErrCodeInternal, "failed to mark postback as used")
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// DeleteExpired removes postbacks that expired before the given time
+func (r *PostbackRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.Pool.Exec(ctx, `DELETE FROM postbacks WHERE expires_at < $1`, before)
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to delete expired postbacks")
+	}
+	return result.RowsAffected(), nil
+}
+
+// RecordVote stores a vote, replacing the contact's previous vote in the poll
+func (r *PostbackRepository) RecordVote(ctx context.Context, vote *entity.PostbackVote) error {
+	query := `
+		INSERT INTO postback_votes (
+			id, tenant_id, poll_id, option, contact_id, conversation_id, message_id, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (tenant_id, poll_id, contact_id) DO UPDATE SET
+			option = EXCLUDED.option,
+			conversation_id = EXCLUDED.conversation_id,
+			message_id = EXCLUDED.message_id,
+			created_at = EXCLUDED.created_at
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query,
+		vote.ID,
+		vote.TenantID,
+		vote.PollID,
+		vote.Option,
+		vote.ContactID,
+		vote.ConversationID,
+		vote.MessageID,
+		vote.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to record vote")
+	}
+	return nil
+}
+
+// CountVotes returns the votes of a poll grouped by option
+func (r *PostbackRepository) CountVotes(ctx context.Context, tenantID, pollID string) (*entity.PollResult, error) {
+	query := `
+		SELECT option, COUNT(*)
+		FROM postback_votes
+		WHERE tenant_id = $1 AND poll_id = $2
+		GROUP BY option
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, tenantID, pollID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to count votes")
+	}
+	defer rows.Close()
+
+	result := &entity.PollResult{PollID: pollID, Votes: make(map[string]int64)}
+	for rows.Next() {
+		var option string
+		var count int64
+		if err := rows.Scan(&option, &count); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan vote count")
+		}
+		result.Votes[option] = count
+		result.Total += count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate vote counts")
+	}
+
+	return result, nil
+}
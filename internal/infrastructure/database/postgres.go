@@ -98,6 +98,78 @@ func (db *PostgresDB) RunMigrations(ctx context.Context) error {
 		createWhatsAppPaymentsTables,
 		createWhatsAppHistoryImportsTable,
 		createWhatsAppCoexistenceTables,
+		createWhatsAppPhoneNumbersTable,
+		addConversationMetadataColumn,
+		createWhatsAppConversationCostsTable,
+		createPostbacksTable,
+		createQATables,
+		createStatusPageTables,
+		createMessageHooksTable,
+		createConversationFormTables,
+		createNewsletterTables,
+		createDocumentPipelineTables,
+		createTenantEncryptionKeysTable,
+		createJobsTable,
+		createSchedulingTables,
+		createLinkedObjectTables,
+		addNewsletterSmartSendColumns,
+		addMessageErrorDetailsColumn,
+		createWatchTables,
+		addConversationTitleColumns,
+		createKnowledgeInboxTables,
+		createConversationOperationsTable,
+		createChannelLeaseTables,
+		createContactSegmentsTable,
+		addNewsletterSegmentColumn,
+		addContactAddressesColumn,
+		createLabelTables,
+		addConversationBotPauseColumns,
+		createKnowledgeFeedbackTables,
+		createRoutingTables,
+		createAutomationTraceTable,
+		createPaymentRefundsTable,
+		createContactMergesTable,
+		createContactFieldsTable,
+		createWalletPassTables,
+		createWebhookSubscriptionTables,
+		addNewsletterImageColumns,
+		addRoutingLanguageColumns,
+		createOnboardingProgressTable,
+		createQuotaTables,
+		createUploadsTable,
+		createCallTranscriptsTable,
+		addEphemeralMessageIndex,
+		createConversationReassignmentsTable,
+		createWidgetSettingsTables,
+		createKnowledgeCurationTables,
+		createCopilotSuggestionsTable,
+		createConversationSummariesTable,
+		createMaintenanceTables,
+		createChannelProbesTable,
+		createComplianceTables,
+		createBotToolsTables,
+		createBotExperimentTables,
+		createOrganizationTables,
+		addSyncChangeTracking,
+		createKnowledgeVersionTables,
+		createDispositionTables,
+		createEmbeddingCacheTable,
+		createConversationReopenTables,
+		createAIUsageTables,
+		addAPIKeyRevokedAtColumn,
+		addNewsletterThrottlingColumns,
+		createUserMFATable,
+		createConversationParticipantTables,
+		createTenantSSOConfigsTable,
+		addSimilarReplyColumns,
+		createChannelTokensTable,
+		createChannelMaintenanceWindowsTable,
+		createMessageTranslationsTable,
+		createDeadLetterReplaysTable,
+		createKnowledgeSharingTables,
+		createNotificationsTable,
+		createChannelCredentialHealthTable,
+		addSSOSavedByOwnerColumn,
 	}
 
 	for _, migration := range migrations {
@@ -755,3 +827,1710 @@ CREATE INDEX IF NOT EXISTS idx_wa_coexistence_notifications_channel ON whatsapp_
 CREATE INDEX IF NOT EXISTS idx_wa_coexistence_notifications_tenant ON whatsapp_coexistence_notifications(tenant_id);
 CREATE INDEX IF NOT EXISTS idx_wa_coexistence_notifications_read_at ON whatsapp_coexistence_notifications(read_at);
 `
+
+const createWhatsAppPhoneNumbersTable = `
+CREATE TABLE IF NOT EXISTS whatsapp_phone_numbers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    channel_id UUID NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    phone_number_id VARCHAR(64) NOT NULL UNIQUE,
+    display_phone_number VARCHAR(50) NOT NULL DEFAULT '',
+    verified_name VARCHAR(255) NOT NULL DEFAULT '',
+    quality_rating VARCHAR(20) NOT NULL DEFAULT 'GREEN',
+    messaging_limit_tier VARCHAR(32) NOT NULL DEFAULT 'TIER_250',
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    sent_in_window INT NOT NULL DEFAULT 0,
+    window_started_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_wa_phone_numbers_channel ON whatsapp_phone_numbers(channel_id);
+CREATE INDEX IF NOT EXISTS idx_wa_phone_numbers_tenant ON whatsapp_phone_numbers(tenant_id);
+
+DROP TRIGGER IF EXISTS update_whatsapp_phone_numbers_updated_at ON whatsapp_phone_numbers;
+CREATE TRIGGER update_whatsapp_phone_numbers_updated_at
+    BEFORE UPDATE ON whatsapp_phone_numbers
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+`
+
+const addConversationMetadataColumn = `
+ALTER TABLE conversations ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}';
+`
+
+const createWhatsAppConversationCostsTable = `
+CREATE TABLE IF NOT EXISTS whatsapp_conversation_costs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    channel_id UUID NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    wa_conversation_id VARCHAR(128) NOT NULL,
+    message_id VARCHAR(255) NOT NULL DEFAULT '',
+    campaign_id VARCHAR(255) NOT NULL DEFAULT '',
+    category VARCHAR(32) NOT NULL,
+    pricing_model VARCHAR(32) NOT NULL DEFAULT '',
+    origin VARCHAR(32) NOT NULL DEFAULT '',
+    billable BOOLEAN NOT NULL DEFAULT FALSE,
+    cost NUMERIC(12, 6) NOT NULL DEFAULT 0,
+    currency VARCHAR(3) NOT NULL DEFAULT 'USD',
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (channel_id, wa_conversation_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_wa_conversation_costs_tenant_created ON whatsapp_conversation_costs(tenant_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_wa_conversation_costs_campaign ON whatsapp_conversation_costs(campaign_id) WHERE campaign_id <> '';
+`
+
+const createPostbacksTable = `
+CREATE TABLE IF NOT EXISTS postbacks (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    conversation_id VARCHAR(255) NOT NULL DEFAULT '',
+    action VARCHAR(32) NOT NULL,
+    target VARCHAR(255) NOT NULL,
+    node_id VARCHAR(255) NOT NULL DEFAULT '',
+    payload JSONB DEFAULT '{}',
+    single_use BOOLEAN NOT NULL DEFAULT FALSE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_postbacks_expires_at ON postbacks(expires_at);
+
+CREATE TABLE IF NOT EXISTS postback_votes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    poll_id VARCHAR(255) NOT NULL,
+    option VARCHAR(255) NOT NULL,
+    contact_id VARCHAR(255) NOT NULL,
+    conversation_id VARCHAR(255) NOT NULL DEFAULT '',
+    message_id VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (tenant_id, poll_id, contact_id)
+);
+`
+
+const createQATables = `
+CREATE TABLE IF NOT EXISTS qa_scorecards (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    criteria JSONB NOT NULL DEFAULT '[]',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS qa_sampling_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    scorecard_id UUID NOT NULL REFERENCES qa_scorecards(id) ON DELETE CASCADE,
+    channel_ids TEXT[] DEFAULT '{}',
+    min_messages INT NOT NULL DEFAULT 0,
+    sample_percent INT NOT NULL DEFAULT 10,
+    max_per_agent INT NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    last_run_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS qa_reviews (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    conversation_id VARCHAR(255) NOT NULL,
+    agent_id VARCHAR(255) NOT NULL,
+    scorecard_id UUID NOT NULL REFERENCES qa_scorecards(id) ON DELETE CASCADE,
+    rule_id VARCHAR(255) NOT NULL DEFAULT '',
+    reviewer_id VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(32) NOT NULL DEFAULT 'pending',
+    scores JSONB NOT NULL DEFAULT '[]',
+    comment TEXT NOT NULL DEFAULT '',
+    total_score NUMERIC(5, 2) NOT NULL DEFAULT 0,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_qa_reviews_tenant_agent ON qa_reviews(tenant_id, agent_id, status);
+CREATE INDEX IF NOT EXISTS idx_qa_reviews_conversation ON qa_reviews(conversation_id);
+`
+
+const createStatusPageTables = `
+CREATE TABLE IF NOT EXISTS status_pages (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL UNIQUE REFERENCES tenants(id) ON DELETE CASCADE,
+    slug VARCHAR(100) NOT NULL UNIQUE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    is_published BOOLEAN NOT NULL DEFAULT FALSE,
+    channel_ids TEXT[] DEFAULT '{}',
+    auto_incidents BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS incidents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    kind VARCHAR(32) NOT NULL DEFAULT 'incident',
+    title VARCHAR(255) NOT NULL,
+    message TEXT NOT NULL DEFAULT '',
+    impact VARCHAR(32) NOT NULL DEFAULT 'minor',
+    status VARCHAR(32) NOT NULL DEFAULT 'investigating',
+    channel_ids TEXT[] DEFAULT '{}',
+    updates JSONB NOT NULL DEFAULT '[]',
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    ends_at TIMESTAMP WITH TIME ZONE,
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    auto_created BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_incidents_tenant_starts ON incidents(tenant_id, starts_at DESC);
+CREATE INDEX IF NOT EXISTS idx_incidents_unresolved ON incidents(tenant_id) WHERE status <> 'resolved';
+`
+
+const createMessageHooksTable = `
+CREATE TABLE IF NOT EXISTS message_hooks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    language VARCHAR(32) NOT NULL,
+    script TEXT NOT NULL,
+    events TEXT[] NOT NULL DEFAULT '{}',
+    channel_ids TEXT[] DEFAULT '{}',
+    lookups JSONB NOT NULL DEFAULT '[]',
+    timeout_ms INTEGER NOT NULL DEFAULT 500,
+    memory_mb INTEGER NOT NULL DEFAULT 64,
+    priority INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    last_error TEXT NOT NULL DEFAULT '',
+    last_run_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_message_hooks_tenant_active ON message_hooks(tenant_id, priority) WHERE is_active = TRUE;
+`
+
+const createConversationFormTables = `
+CREATE TABLE IF NOT EXISTS conversation_forms (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    fields JSONB NOT NULL DEFAULT '[]',
+    ai_prefill BOOLEAN NOT NULL DEFAULT FALSE,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS form_submissions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    form_id UUID NOT NULL REFERENCES conversation_forms(id) ON DELETE CASCADE,
+    conversation_id VARCHAR(255) NOT NULL,
+    contact_id VARCHAR(255) NOT NULL DEFAULT '',
+    field_values JSONB NOT NULL DEFAULT '{}',
+    ai_prefilled_fields TEXT[] DEFAULT '{}',
+    status VARCHAR(32) NOT NULL DEFAULT 'draft',
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    submitted_by VARCHAR(255) NOT NULL DEFAULT '',
+    submitted_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_form_submissions_tenant_form ON form_submissions(tenant_id, form_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_form_submissions_conversation ON form_submissions(conversation_id);
+`
+
+const createNewsletterTables = `
+CREATE TABLE IF NOT EXISTS newsletters (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    channel_id UUID NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    segment_tags TEXT[] DEFAULT '{}',
+    content_type VARCHAR(50) NOT NULL DEFAULT 'text',
+    content TEXT NOT NULL DEFAULT '',
+    metadata JSONB NOT NULL DEFAULT '{}',
+    schedule JSONB NOT NULL DEFAULT '{}',
+    frequency_cap INT NOT NULL DEFAULT 0,
+    frequency_cap_hours INT NOT NULL DEFAULT 0,
+    opt_in_keyword VARCHAR(100) NOT NULL DEFAULT '',
+    unsubscribe_keywords TEXT[] DEFAULT '{}',
+    welcome_message TEXT NOT NULL DEFAULT '',
+    unsubscribe_message TEXT NOT NULL DEFAULT '',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMP WITH TIME ZONE,
+    last_run_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_newsletters_tenant ON newsletters(tenant_id);
+CREATE INDEX IF NOT EXISTS idx_newsletters_channel ON newsletters(channel_id);
+CREATE INDEX IF NOT EXISTS idx_newsletters_due ON newsletters(next_run_at) WHERE is_active = TRUE;
+
+CREATE TABLE IF NOT EXISTS newsletter_subscriptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    newsletter_id UUID NOT NULL REFERENCES newsletters(id) ON DELETE CASCADE,
+    contact_id UUID NOT NULL REFERENCES contacts(id) ON DELETE CASCADE,
+    status VARCHAR(32) NOT NULL DEFAULT 'subscribed',
+    source VARCHAR(100) NOT NULL DEFAULT '',
+    subscribed_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    unsubscribed_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(newsletter_id, contact_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_newsletter_subscriptions_status ON newsletter_subscriptions(newsletter_id, status);
+
+CREATE TABLE IF NOT EXISTS newsletter_editions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    newsletter_id UUID NOT NULL REFERENCES newsletters(id) ON DELETE CASCADE,
+    content_type VARCHAR(50) NOT NULL DEFAULT 'text',
+    content TEXT NOT NULL DEFAULT '',
+    triggered_by VARCHAR(32) NOT NULL DEFAULT '',
+    status VARCHAR(32) NOT NULL DEFAULT 'sending',
+    recipients INT NOT NULL DEFAULT 0,
+    sent INT NOT NULL DEFAULT 0,
+    capped INT NOT NULL DEFAULT 0,
+    failed INT NOT NULL DEFAULT 0,
+    started_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    completed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_newsletter_editions_newsletter ON newsletter_editions(newsletter_id, started_at DESC);
+
+CREATE TABLE IF NOT EXISTS newsletter_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    newsletter_id UUID NOT NULL REFERENCES newsletters(id) ON DELETE CASCADE,
+    edition_id UUID NOT NULL REFERENCES newsletter_editions(id) ON DELETE CASCADE,
+    contact_id UUID NOT NULL REFERENCES contacts(id) ON DELETE CASCADE,
+    conversation_id VARCHAR(255) NOT NULL DEFAULT '',
+    message_id VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(32) NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    replied_at TIMESTAMP WITH TIME ZONE,
+    unsubscribed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_newsletter_deliveries_edition ON newsletter_deliveries(edition_id);
+CREATE INDEX IF NOT EXISTS idx_newsletter_deliveries_contact ON newsletter_deliveries(tenant_id, contact_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_newsletter_deliveries_conversation ON newsletter_deliveries(conversation_id, created_at DESC);
+`
+
+const createDocumentPipelineTables = `
+CREATE TABLE IF NOT EXISTS document_pipelines (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    channel_ids TEXT[] DEFAULT '{}',
+    mime_types TEXT[] DEFAULT '{}',
+    document_types JSONB NOT NULL DEFAULT '[]',
+    ai_extraction BOOLEAN NOT NULL DEFAULT FALSE,
+    metadata_prefix VARCHAR(64) NOT NULL DEFAULT '',
+    priority INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_pipelines_tenant_active ON document_pipelines(tenant_id, priority) WHERE is_active = TRUE;
+
+CREATE TABLE IF NOT EXISTS document_extractions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    pipeline_id VARCHAR(255) NOT NULL DEFAULT '',
+    conversation_id VARCHAR(255) NOT NULL,
+    message_id VARCHAR(255) NOT NULL,
+    attachment_id VARCHAR(255) NOT NULL DEFAULT '',
+    filename VARCHAR(500) NOT NULL DEFAULT '',
+    mime_type VARCHAR(255) NOT NULL DEFAULT '',
+    document_type VARCHAR(100) NOT NULL DEFAULT '',
+    fields JSONB NOT NULL DEFAULT '{}',
+    ai_fields TEXT[] DEFAULT '{}',
+    text TEXT NOT NULL DEFAULT '',
+    status VARCHAR(32) NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    flow_id VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_document_extractions_conversation ON document_extractions(conversation_id, created_at DESC);
+`
+
+const createTenantEncryptionKeysTable = `
+CREATE TABLE IF NOT EXISTS tenant_encryption_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    provider VARCHAR(32) NOT NULL,
+    kms_key_ref VARCHAR(255) NOT NULL DEFAULT '',
+    key_fingerprint VARCHAR(64) NOT NULL DEFAULT '',
+    wrapped_key BYTEA NOT NULL,
+    wrapped_tenant_key BYTEA,
+    status VARCHAR(32) NOT NULL DEFAULT 'active',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    retired_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE(tenant_id, version)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tenant_encryption_keys_active ON tenant_encryption_keys(tenant_id) WHERE status = 'active';
+
+-- Encrypted attachment references outgrow the original column sizes
+ALTER TABLE message_attachments ALTER COLUMN filename TYPE TEXT;
+ALTER TABLE message_attachments ALTER COLUMN url TYPE TEXT;
+ALTER TABLE message_attachments ALTER COLUMN thumbnail_url TYPE TEXT;
+`
+
+const createJobsTable = `
+CREATE TABLE IF NOT EXISTS jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    type VARCHAR(100) NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT 'pending',
+    payload JSONB NOT NULL DEFAULT '{}',
+    result JSONB NOT NULL DEFAULT '{}',
+    progress INTEGER NOT NULL DEFAULT 0,
+    progress_message TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 3,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    started_at TIMESTAMP WITH TIME ZONE,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_tenant_created ON jobs(tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_jobs_unfinished ON jobs(updated_at) WHERE status IN ('pending', 'running');
+`
+
+const createSchedulingTables = `
+CREATE TABLE IF NOT EXISTS booking_calendars (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    provider VARCHAR(32) NOT NULL,
+    external_calendar_id VARCHAR(512) NOT NULL DEFAULT '',
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    slot_minutes INTEGER NOT NULL DEFAULT 30,
+    buffer_minutes INTEGER NOT NULL DEFAULT 0,
+    min_notice_minutes INTEGER NOT NULL DEFAULT 0,
+    max_days_ahead INTEGER NOT NULL DEFAULT 14,
+    availability JSONB NOT NULL DEFAULT '[]',
+    reminder_minutes JSONB NOT NULL DEFAULT '[]',
+    event_title VARCHAR(255) NOT NULL DEFAULT '',
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    credentials JSONB,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_booking_calendars_tenant ON booking_calendars(tenant_id);
+
+CREATE TABLE IF NOT EXISTS bookings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    calendar_id UUID NOT NULL REFERENCES booking_calendars(id) ON DELETE CASCADE,
+    conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    contact_id UUID NOT NULL REFERENCES contacts(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(32) NOT NULL DEFAULT 'pending',
+    offered_slots JSONB NOT NULL DEFAULT '[]',
+    start_at TIMESTAMP WITH TIME ZONE,
+    end_at TIMESTAMP WITH TIME ZONE,
+    external_event_id VARCHAR(1024) NOT NULL DEFAULT '',
+    token VARCHAR(64) NOT NULL UNIQUE,
+    reminders_sent JSONB NOT NULL DEFAULT '[]',
+    next_reminder_at TIMESTAMP WITH TIME ZONE,
+    cancel_reason TEXT NOT NULL DEFAULT '',
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    cancelled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_bookings_tenant_start ON bookings(tenant_id, start_at);
+CREATE INDEX IF NOT EXISTS idx_bookings_conversation ON bookings(conversation_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_bookings_calendar_confirmed ON bookings(calendar_id, start_at) WHERE status = 'confirmed';
+CREATE INDEX IF NOT EXISTS idx_bookings_reminders ON bookings(next_reminder_at) WHERE status = 'confirmed';
+`
+
+const createLinkedObjectTables = `
+CREATE TABLE IF NOT EXISTS linked_object_connectors (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    provider VARCHAR(32) NOT NULL,
+    object_type VARCHAR(32) NOT NULL,
+    secret VARCHAR(128) NOT NULL,
+    field_map JSONB NOT NULL DEFAULT '{}',
+    status_messages JSONB NOT NULL DEFAULT '{}',
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    last_sync_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_linked_object_connectors_tenant ON linked_object_connectors(tenant_id);
+
+CREATE TABLE IF NOT EXISTS linked_objects (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    type VARCHAR(32) NOT NULL,
+    source VARCHAR(32) NOT NULL DEFAULT 'manual',
+    connector_id UUID REFERENCES linked_object_connectors(id) ON DELETE SET NULL,
+    external_id VARCHAR(255) NOT NULL DEFAULT '',
+    reference VARCHAR(255) NOT NULL,
+    title VARCHAR(512) NOT NULL DEFAULT '',
+    status VARCHAR(64) NOT NULL DEFAULT '',
+    url TEXT NOT NULL DEFAULT '',
+    contact_id UUID REFERENCES contacts(id) ON DELETE SET NULL,
+    conversation_id UUID REFERENCES conversations(id) ON DELETE SET NULL,
+    attributes JSONB NOT NULL DEFAULT '{}',
+    external_updated_at TIMESTAMP WITH TIME ZONE,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_linked_objects_external ON linked_objects(connector_id, external_id) WHERE connector_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_linked_objects_tenant ON linked_objects(tenant_id, type, updated_at DESC);
+CREATE INDEX IF NOT EXISTS idx_linked_objects_conversation ON linked_objects(conversation_id, updated_at DESC);
+CREATE INDEX IF NOT EXISTS idx_linked_objects_contact ON linked_objects(contact_id, updated_at DESC);
+`
+
+const addNewsletterSmartSendColumns = `
+ALTER TABLE newsletters ADD COLUMN IF NOT EXISTS smart_send JSONB;
+ALTER TABLE newsletter_editions ADD COLUMN IF NOT EXISTS smart_send BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE newsletter_editions ADD COLUMN IF NOT EXISTS scheduled INT NOT NULL DEFAULT 0;
+ALTER TABLE newsletter_deliveries ADD COLUMN IF NOT EXISTS timing VARCHAR(16) NOT NULL DEFAULT 'fixed';
+ALTER TABLE newsletter_deliveries ADD COLUMN IF NOT EXISTS scheduled_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_newsletter_deliveries_scheduled ON newsletter_deliveries(scheduled_at) WHERE status = 'scheduled';
+`
+
+const addMessageErrorDetailsColumn = `
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS error_details JSONB;
+`
+
+const createWatchTables = `
+CREATE TABLE IF NOT EXISTS watches (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    target_type VARCHAR(32) NOT NULL,
+    target_id UUID NOT NULL,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (user_id, target_type, target_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_watches_target ON watches(target_type, target_id);
+`
+
+const addConversationTitleColumns = `
+ALTER TABLE conversations ADD COLUMN IF NOT EXISTS title VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE conversations ADD COLUMN IF NOT EXISTS title_source VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE conversations ADD COLUMN IF NOT EXISTS title_messages INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE conversations ADD COLUMN IF NOT EXISTS title_updated_at TIMESTAMP WITH TIME ZONE;
+`
+
+const createKnowledgeInboxTables = `
+CREATE TABLE IF NOT EXISTS knowledge_inboxes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    address VARCHAR(255) NOT NULL,
+    knowledge_base_id UUID NOT NULL REFERENCES knowledge_bases(id) ON DELETE CASCADE,
+    allowed_senders TEXT[] DEFAULT '{}',
+    include_body BOOLEAN NOT NULL DEFAULT true,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (tenant_id, address)
+);
+
+CREATE TABLE IF NOT EXISTS knowledge_submissions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    inbox_id UUID NOT NULL REFERENCES knowledge_inboxes(id) ON DELETE CASCADE,
+    knowledge_base_id UUID NOT NULL REFERENCES knowledge_bases(id) ON DELETE CASCADE,
+    message_id UUID REFERENCES messages(id) ON DELETE SET NULL,
+    sender VARCHAR(255) NOT NULL DEFAULT '',
+    subject TEXT NOT NULL DEFAULT '',
+    filename VARCHAR(512) NOT NULL DEFAULT '',
+    mime_type VARCHAR(255) NOT NULL DEFAULT '',
+    title TEXT NOT NULL DEFAULT '',
+    content TEXT NOT NULL DEFAULT '',
+    status VARCHAR(32) NOT NULL DEFAULT 'pending',
+    error TEXT NOT NULL DEFAULT '',
+    item_ids TEXT[] DEFAULT '{}',
+    reviewed_by VARCHAR(255) NOT NULL DEFAULT '',
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_knowledge_submissions_tenant ON knowledge_submissions(tenant_id, status, created_at DESC);
+`
+
+const createConversationOperationsTable = `
+CREATE TABLE IF NOT EXISTS conversation_operations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    type VARCHAR(16) NOT NULL,
+    source_conversation_id UUID NOT NULL,
+    target_conversation_id UUID NOT NULL,
+    message_ids TEXT[] DEFAULT '{}',
+    reason TEXT NOT NULL DEFAULT '',
+    performed_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_conversation_operations_source ON conversation_operations(source_conversation_id);
+CREATE INDEX IF NOT EXISTS idx_conversation_operations_target ON conversation_operations(target_conversation_id);
+`
+
+const createChannelLeaseTables = `
+CREATE TABLE IF NOT EXISTS channel_leases (
+    channel_id UUID PRIMARY KEY REFERENCES channels(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    owner_id VARCHAR(255) NOT NULL,
+    acquired_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    renewed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS channel_failover_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    channel_id UUID NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    type VARCHAR(16) NOT NULL,
+    owner_id VARCHAR(255) NOT NULL,
+    previous_owner_id VARCHAR(255) NOT NULL DEFAULT '',
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_channel_failover_events_channel ON channel_failover_events(channel_id, created_at DESC);
+`
+
+const createContactSegmentsTable = `
+CREATE TABLE IF NOT EXISTS contact_segments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    match VARCHAR(8) NOT NULL DEFAULT 'all',
+    rules JSONB NOT NULL DEFAULT '[]',
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_contact_segments_tenant ON contact_segments(tenant_id);
+`
+
+const addNewsletterSegmentColumn = `
+ALTER TABLE newsletters ADD COLUMN IF NOT EXISTS segment_id UUID REFERENCES contact_segments(id) ON DELETE SET NULL;
+`
+
+const addContactAddressesColumn = `
+ALTER TABLE contacts ADD COLUMN IF NOT EXISTS addresses JSONB NOT NULL DEFAULT '[]';
+`
+
+const createLabelTables = `
+CREATE TABLE IF NOT EXISTS labels (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    color VARCHAR(7) NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_labels_tenant_name ON labels(tenant_id, LOWER(name));
+
+CREATE TABLE IF NOT EXISTS conversation_labels (
+    conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    label_id UUID NOT NULL REFERENCES labels(id) ON DELETE CASCADE,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (conversation_id, label_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_conversation_labels_label ON conversation_labels(label_id);
+`
+
+const addConversationBotPauseColumns = `
+ALTER TABLE conversations ADD COLUMN IF NOT EXISTS bot_paused_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE conversations ADD COLUMN IF NOT EXISTS bot_paused_by UUID REFERENCES users(id) ON DELETE SET NULL;
+`
+
+const createKnowledgeFeedbackTables = `
+CREATE TABLE IF NOT EXISTS knowledge_feedback (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    knowledge_base_id UUID NOT NULL REFERENCES knowledge_bases(id) ON DELETE CASCADE,
+    item_ids TEXT[] NOT NULL DEFAULT '{}',
+    conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    message_id UUID NOT NULL,
+    contact_id VARCHAR(255) NOT NULL DEFAULT '',
+    question TEXT NOT NULL DEFAULT '',
+    helpful BOOLEAN NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_knowledge_feedback_message ON knowledge_feedback(message_id);
+CREATE INDEX IF NOT EXISTS idx_knowledge_feedback_base ON knowledge_feedback(tenant_id, knowledge_base_id);
+
+CREATE TABLE IF NOT EXISTS knowledge_gaps (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    knowledge_base_id UUID NOT NULL REFERENCES knowledge_bases(id) ON DELETE CASCADE,
+    question TEXT NOT NULL,
+    source VARCHAR(32) NOT NULL,
+    item_ids TEXT[] NOT NULL DEFAULT '{}',
+    conversation_id VARCHAR(255) NOT NULL DEFAULT '',
+    occurrences INTEGER NOT NULL DEFAULT 1,
+    status VARCHAR(32) NOT NULL DEFAULT 'open',
+    resolved_by VARCHAR(255) NOT NULL DEFAULT '',
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_knowledge_gaps_open_question ON knowledge_gaps(knowledge_base_id, LOWER(question)) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_knowledge_gaps_tenant ON knowledge_gaps(tenant_id, status, occurrences DESC);
+`
+
+const createRoutingTables = `
+CREATE TABLE IF NOT EXISTS routing_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    conditions JSONB NOT NULL DEFAULT '{}',
+    required_skills TEXT[] NOT NULL DEFAULT '{}',
+    match_language BOOLEAN NOT NULL DEFAULT FALSE,
+    agent_ids TEXT[] NOT NULL DEFAULT '{}',
+    strategy VARCHAR(32) NOT NULL DEFAULT 'least_loaded',
+    max_active_conversations INTEGER NOT NULL DEFAULT 0,
+    last_assigned_user_id VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_routing_rules_tenant ON routing_rules(tenant_id, position);
+
+CREATE TABLE IF NOT EXISTS agent_routing_profiles (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    skills TEXT[] NOT NULL DEFAULT '{}',
+    languages TEXT[] NOT NULL DEFAULT '{}',
+    max_active_conversations INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_routing_profiles_tenant ON agent_routing_profiles(tenant_id);
+`
+
+const createAutomationTraceTable = `
+CREATE TABLE IF NOT EXISTS automation_traces (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    message_id VARCHAR(255) NOT NULL DEFAULT '',
+    source VARCHAR(32) NOT NULL,
+    flow_id VARCHAR(255) NOT NULL DEFAULT '',
+    bot_id VARCHAR(255) NOT NULL DEFAULT '',
+    steps JSONB NOT NULL DEFAULT '[]',
+    variables JSONB NOT NULL DEFAULT '{}',
+    intent VARCHAR(255) NOT NULL DEFAULT '',
+    sentiment VARCHAR(32) NOT NULL DEFAULT '',
+    confidence DOUBLE PRECISION,
+    model VARCHAR(255) NOT NULL DEFAULT '',
+    knowledge_item_ids TEXT[] NOT NULL DEFAULT '{}',
+    decision VARCHAR(32) NOT NULL,
+    escalate_reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_automation_traces_conversation ON automation_traces(conversation_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_automation_traces_message ON automation_traces(message_id) WHERE message_id <> '';
+`
+
+const createPaymentRefundsTable = `
+CREATE TABLE IF NOT EXISTS whatsapp_payment_refunds (
+    id VARCHAR(128) PRIMARY KEY,
+    payment_id VARCHAR(128) NOT NULL REFERENCES whatsapp_payments(id) ON DELETE CASCADE,
+    organization_id VARCHAR(64) NOT NULL,
+    channel_id VARCHAR(64) NOT NULL,
+    amount BIGINT NOT NULL,
+    currency VARCHAR(10) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    gateway_refund_id VARCHAR(255) NOT NULL DEFAULT '',
+    failure_reason TEXT NOT NULL DEFAULT '',
+    requested_by VARCHAR(64) NOT NULL DEFAULT '',
+    reviewed_by VARCHAR(64) NOT NULL DEFAULT '',
+    review_note TEXT NOT NULL DEFAULT '',
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    processed_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_wa_payment_refunds_payment ON whatsapp_payment_refunds(payment_id);
+CREATE INDEX IF NOT EXISTS idx_wa_payment_refunds_channel ON whatsapp_payment_refunds(organization_id, channel_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_wa_payment_refunds_pending ON whatsapp_payment_refunds(channel_id) WHERE status = 'pending_approval';
+`
+
+const createContactMergesTable = `
+CREATE TABLE IF NOT EXISTS contact_merges (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    source_contact_id UUID NOT NULL,
+    target_contact_id UUID NOT NULL,
+    reason VARCHAR(16) NOT NULL,
+    conversation_ids TEXT[] DEFAULT '{}',
+    identity_ids TEXT[] DEFAULT '{}',
+    message_count BIGINT NOT NULL DEFAULT 0,
+    performed_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_contact_merges_target ON contact_merges(target_contact_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_contact_merges_source ON contact_merges(source_contact_id);
+`
+
+const createContactFieldsTable = `
+CREATE TABLE IF NOT EXISTS contact_fields (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    key VARCHAR(64) NOT NULL,
+    label VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    type VARCHAR(16) NOT NULL,
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    options TEXT[] DEFAULT '{}',
+    pattern TEXT NOT NULL DEFAULT '',
+    min_value DOUBLE PRECISION,
+    max_value DOUBLE PRECISION,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(tenant_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_contacts_custom_fields ON contacts USING GIN (custom_fields);
+`
+
+const createWalletPassTables = `
+CREATE TABLE IF NOT EXISTS wallet_pass_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    kind VARCHAR(16) NOT NULL,
+    object_type VARCHAR(32) NOT NULL DEFAULT '',
+    organization_name VARCHAR(255) NOT NULL,
+    description VARCHAR(512) NOT NULL,
+    logo_text VARCHAR(255) NOT NULL DEFAULT '',
+    logo_url TEXT NOT NULL DEFAULT '',
+    background_color VARCHAR(7) NOT NULL DEFAULT '',
+    foreground_color VARCHAR(7) NOT NULL DEFAULT '',
+    label_color VARCHAR(7) NOT NULL DEFAULT '',
+    fields JSONB NOT NULL DEFAULT '[]',
+    barcode_format VARCHAR(16) NOT NULL DEFAULT 'qr',
+    barcode_message TEXT NOT NULL DEFAULT '',
+    date_attribute VARCHAR(255) NOT NULL DEFAULT '',
+    void_statuses TEXT[] DEFAULT '{}',
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_pass_templates_tenant ON wallet_pass_templates(tenant_id);
+
+CREATE TABLE IF NOT EXISTS wallet_passes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    template_id UUID NOT NULL REFERENCES wallet_pass_templates(id) ON DELETE CASCADE,
+    linked_object_id UUID REFERENCES linked_objects(id) ON DELETE SET NULL,
+    contact_id UUID REFERENCES contacts(id) ON DELETE SET NULL,
+    auth_token VARCHAR(64) NOT NULL,
+    barcode_payload TEXT NOT NULL DEFAULT '',
+    fields JSONB NOT NULL DEFAULT '[]',
+    relevant_date TIMESTAMP WITH TIME ZONE,
+    voided BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_passes_linked_object ON wallet_passes(linked_object_id);
+CREATE INDEX IF NOT EXISTS idx_wallet_passes_contact ON wallet_passes(contact_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS wallet_pass_registrations (
+    pass_id UUID NOT NULL REFERENCES wallet_passes(id) ON DELETE CASCADE,
+    device_library_id VARCHAR(255) NOT NULL,
+    push_token VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (pass_id, device_library_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_pass_registrations_device ON wallet_pass_registrations(device_library_id);
+`
+
+const createWebhookSubscriptionTables = `
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    url TEXT NOT NULL,
+    events TEXT[] NOT NULL DEFAULT '{}',
+    schema_version VARCHAR(16) NOT NULL DEFAULT 'latest',
+    secret VARCHAR(128) NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_tenant ON webhook_subscriptions(tenant_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    subscription_id UUID NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id UUID NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}',
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    response_body TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    last_attempt_at TIMESTAMP WITH TIME ZONE,
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription ON webhook_deliveries(subscription_id, created_at DESC);
+`
+
+const addNewsletterImageColumns = `
+ALTER TABLE newsletters ADD COLUMN IF NOT EXISTS image JSONB;
+ALTER TABLE newsletter_editions ADD COLUMN IF NOT EXISTS render_stats JSONB;
+`
+
+const addRoutingLanguageColumns = `
+ALTER TABLE routing_rules ADD COLUMN IF NOT EXISTS translation_fallback BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE routing_rules ADD COLUMN IF NOT EXISTS match_channel BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE agent_routing_profiles ADD COLUMN IF NOT EXISTS channels TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE agent_routing_profiles ADD COLUMN IF NOT EXISTS translation_assisted BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_conversations_language ON conversations(tenant_id, (metadata->>'language'));
+`
+
+const createOnboardingProgressTable = `
+CREATE TABLE IF NOT EXISTS onboarding_progress (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL UNIQUE REFERENCES tenants(id) ON DELETE CASCADE,
+    steps JSONB NOT NULL DEFAULT '[]',
+    sample_resources JSONB NOT NULL DEFAULT '{}',
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+`
+
+const createQuotaTables = `
+CREATE TABLE IF NOT EXISTS quota_usage (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    metric VARCHAR(50) NOT NULL,
+    route VARCHAR(255) NOT NULL DEFAULT '',
+    day DATE NOT NULL,
+    count BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (tenant_id, metric, route, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_quota_usage_tenant_day ON quota_usage(tenant_id, day);
+
+CREATE TABLE IF NOT EXISTS quota_alerts (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    metric VARCHAR(50) NOT NULL,
+    period_start DATE NOT NULL,
+    threshold INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, metric, period_start, threshold)
+);
+`
+
+const createUploadsTable = `
+CREATE TABLE IF NOT EXISTS uploads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    filename VARCHAR(255) NOT NULL,
+    mime_type VARCHAR(100) NOT NULL,
+    purpose VARCHAR(50) NOT NULL,
+    channel_type VARCHAR(50) NOT NULL DEFAULT '',
+    size BIGINT NOT NULL,
+    upload_offset BIGINT NOT NULL DEFAULT 0,
+    chunks BIGINT[] NOT NULL DEFAULT '{}',
+    checksum VARCHAR(64) NOT NULL DEFAULT '',
+    status VARCHAR(50) NOT NULL DEFAULT 'uploading',
+    scan_status VARCHAR(50) NOT NULL DEFAULT 'pending',
+    scan_result VARCHAR(255) NOT NULL DEFAULT '',
+    transcoded BOOLEAN NOT NULL DEFAULT FALSE,
+    storage_key VARCHAR(500) NOT NULL DEFAULT '',
+    url TEXT NOT NULL DEFAULT '',
+    result_size BIGINT NOT NULL DEFAULT 0,
+    result_mime_type VARCHAR(100) NOT NULL DEFAULT '',
+    job_id VARCHAR(255) NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    encryption_key TEXT NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_uploads_tenant ON uploads(tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_uploads_expiry ON uploads(status, expires_at);
+`
+
+const createCallTranscriptsTable = `
+CREATE TABLE IF NOT EXISTS call_transcripts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    channel_id UUID NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    call_id VARCHAR(255) NOT NULL,
+    direction VARCHAR(20) NOT NULL DEFAULT '',
+    from_number VARCHAR(100) NOT NULL DEFAULT '',
+    to_number VARCHAR(100) NOT NULL DEFAULT '',
+    language VARCHAR(20) NOT NULL DEFAULT '',
+    provider VARCHAR(50) NOT NULL,
+    text TEXT NOT NULL,
+    duration INTEGER NOT NULL DEFAULT 0,
+    recording_url TEXT NOT NULL DEFAULT '',
+    search_vector TSVECTOR GENERATED ALWAYS AS (to_tsvector('simple', text)) STORED,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_call_transcripts_tenant ON call_transcripts(tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_call_transcripts_call ON call_transcripts(tenant_id, call_id);
+CREATE INDEX IF NOT EXISTS idx_call_transcripts_search ON call_transcripts USING GIN(search_vector);
+`
+
+const addEphemeralMessageIndex = `
+CREATE INDEX IF NOT EXISTS idx_messages_ephemeral_expiry ON messages((metadata->>'ephemeral_expires_at'))
+    WHERE metadata ? 'ephemeral_expires_at' AND NOT metadata ? 'ephemeral_expired';
+`
+
+const createConversationReassignmentsTable = `
+CREATE TABLE IF NOT EXISTS conversation_reassignments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    from_user_id UUID NOT NULL,
+    to_user_id UUID,
+    reason VARCHAR(32) NOT NULL,
+    routing_rule_id VARCHAR(255) NOT NULL DEFAULT '',
+    unavailable_since TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_conversation_reassignments_tenant ON conversation_reassignments(tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_conversation_reassignments_conversation ON conversation_reassignments(conversation_id);
+CREATE INDEX IF NOT EXISTS idx_conversations_awaiting_first_reply ON conversations(assignee_id)
+    WHERE first_reply_at IS NULL AND status IN ('open', 'pending');
+`
+
+const createWidgetSettingsTables = `
+CREATE TABLE IF NOT EXISTS widget_settings (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    quick_replies JSONB NOT NULL DEFAULT '[]',
+    emoji_sets JSONB NOT NULL DEFAULT '[]',
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS quick_reply_clicks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    channel_id UUID NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    conversation_id UUID,
+    quick_reply_id VARCHAR(64) NOT NULL,
+    label VARCHAR(255) NOT NULL DEFAULT '',
+    clicked_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_quick_reply_clicks_tenant ON quick_reply_clicks(tenant_id, clicked_at);
+`
+
+const createKnowledgeCurationTables = `
+CREATE TABLE IF NOT EXISTS knowledge_candidates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    question TEXT NOT NULL,
+    answer TEXT NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT 'pending',
+    edited BOOLEAN NOT NULL DEFAULT false,
+    knowledge_base_id UUID REFERENCES knowledge_bases(id) ON DELETE SET NULL,
+    item_id VARCHAR(255) NOT NULL DEFAULT '',
+    reviewed_by VARCHAR(255) NOT NULL DEFAULT '',
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_knowledge_candidates_tenant ON knowledge_candidates(tenant_id, status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_knowledge_candidates_conversation ON knowledge_candidates(conversation_id);
+
+CREATE TABLE IF NOT EXISTS knowledge_mined_conversations (
+    conversation_id UUID PRIMARY KEY REFERENCES conversations(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    candidates INTEGER NOT NULL DEFAULT 0,
+    mined_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+`
+
+const createCopilotSuggestionsTable = `
+CREATE TABLE IF NOT EXISTS copilot_suggestions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    message_id VARCHAR(255) NOT NULL DEFAULT '',
+    agent_id VARCHAR(255) NOT NULL DEFAULT '',
+    type VARCHAR(32) NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    content TEXT NOT NULL DEFAULT '',
+    knowledge_base_id VARCHAR(255) NOT NULL DEFAULT '',
+    knowledge_item_id VARCHAR(255) NOT NULL DEFAULT '',
+    score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    status VARCHAR(32) NOT NULL DEFAULT 'pending',
+    final_content TEXT NOT NULL DEFAULT '',
+    responded_by VARCHAR(255) NOT NULL DEFAULT '',
+    responded_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_copilot_suggestions_conversation ON copilot_suggestions(conversation_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_copilot_suggestions_tenant ON copilot_suggestions(tenant_id, created_at);
+`
+
+const createConversationSummariesTable = `
+CREATE TABLE IF NOT EXISTS conversation_summaries (
+    conversation_id UUID PRIMARY KEY REFERENCES conversations(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    issue TEXT NOT NULL DEFAULT '',
+    resolution TEXT NOT NULL DEFAULT '',
+    sentiment VARCHAR(32) NOT NULL DEFAULT 'neutral',
+    follow_ups TEXT[] NOT NULL DEFAULT '{}',
+    triggered_by VARCHAR(32) NOT NULL,
+    generated_by VARCHAR(255) NOT NULL DEFAULT '',
+    messages INTEGER NOT NULL DEFAULT 0,
+    provider VARCHAR(50) NOT NULL DEFAULT '',
+    model VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_conversation_summaries_tenant ON conversation_summaries(tenant_id, created_at);
+`
+
+const createMaintenanceTables = `
+CREATE TABLE IF NOT EXISTS maintenance_state (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    status VARCHAR(32) NOT NULL DEFAULT 'off',
+    reason TEXT NOT NULL DEFAULT '',
+    started_by VARCHAR(255) NOT NULL DEFAULT '',
+    started_at TIMESTAMP WITH TIME ZONE,
+    ended_at TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS maintenance_webhooks (
+    id BIGSERIAL PRIMARY KEY,
+    method VARCHAR(16) NOT NULL,
+    host VARCHAR(255) NOT NULL DEFAULT '',
+    uri TEXT NOT NULL,
+    headers JSONB NOT NULL DEFAULT '{}',
+    body BYTEA,
+    received_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+`
+
+const createChannelProbesTable = `
+CREATE TABLE IF NOT EXISTS channel_probes (
+    channel_id UUID PRIMARY KEY REFERENCES channels(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    status VARCHAR(32) NOT NULL,
+    checks JSONB NOT NULL DEFAULT '[]',
+    triggered_by VARCHAR(32) NOT NULL,
+    probed_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_channel_probes_tenant ON channel_probes(tenant_id, status);
+`
+
+const createComplianceTables = `
+CREATE TABLE IF NOT EXISTS compliance_rules (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    check_type VARCHAR(32) NOT NULL,
+    terms TEXT[] NOT NULL DEFAULT '{}',
+    country VARCHAR(2) NOT NULL DEFAULT '',
+    channel_types TEXT[] NOT NULL DEFAULT '{}',
+    template_categories TEXT[] NOT NULL DEFAULT '{}',
+    action VARCHAR(16) NOT NULL,
+    message TEXT NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_compliance_rules_tenant ON compliance_rules(tenant_id);
+
+CREATE TABLE IF NOT EXISTS compliance_overrides (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    target_type VARCHAR(32) NOT NULL,
+    target_id VARCHAR(255) NOT NULL DEFAULT '',
+    target_name VARCHAR(255) NOT NULL DEFAULT '',
+    user_id VARCHAR(255) NOT NULL DEFAULT '',
+    reason TEXT NOT NULL,
+    findings JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_compliance_overrides_tenant ON compliance_overrides(tenant_id, created_at DESC);
+`
+
+const createBotToolsTables = `
+CREATE TABLE IF NOT EXISTS bot_tools (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    bot_id UUID NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+    name VARCHAR(64) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    parameters JSONB NOT NULL DEFAULT '{}',
+    method VARCHAR(8) NOT NULL DEFAULT 'POST',
+    url TEXT NOT NULL,
+    headers JSONB NOT NULL DEFAULT '{}',
+    timeout_seconds INTEGER NOT NULL DEFAULT 10,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (bot_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS bot_tool_executions (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    bot_id UUID NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+    tool_id VARCHAR(64) NOT NULL DEFAULT '',
+    tool_name VARCHAR(64) NOT NULL,
+    conversation_id VARCHAR(64) NOT NULL DEFAULT '',
+    message_id VARCHAR(64) NOT NULL DEFAULT '',
+    tool_call_id VARCHAR(255) NOT NULL DEFAULT '',
+    arguments JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(16) NOT NULL,
+    status_code INTEGER NOT NULL DEFAULT 0,
+    result TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_bot_tool_executions_bot ON bot_tool_executions(bot_id, created_at DESC);
+`
+
+const createBotExperimentTables = `
+CREATE TABLE IF NOT EXISTS bot_versions (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    bot_id UUID NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    provider VARCHAR(20) NOT NULL,
+    model VARCHAR(100) NOT NULL,
+    config JSONB NOT NULL DEFAULT '{}',
+    created_by VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (bot_id, version)
+);
+
+CREATE TABLE IF NOT EXISTS bot_experiments (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    bot_id UUID NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    variants JSONB NOT NULL DEFAULT '[]',
+    created_by VARCHAR(64) NOT NULL DEFAULT '',
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ended_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_bot_experiments_running ON bot_experiments(bot_id) WHERE status = 'running';
+
+CREATE TABLE IF NOT EXISTS bot_experiment_assignments (
+    experiment_id UUID NOT NULL REFERENCES bot_experiments(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    conversation_id UUID NOT NULL,
+    version_id UUID NOT NULL REFERENCES bot_versions(id) ON DELETE CASCADE,
+    escalated BOOLEAN NOT NULL DEFAULT FALSE,
+    resolved BOOLEAN NOT NULL DEFAULT FALSE,
+    csat SMALLINT,
+    assigned_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (experiment_id, conversation_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bot_experiment_assignments_conversation ON bot_experiment_assignments(conversation_id);
+`
+
+const createOrganizationTables = `
+CREATE TABLE IF NOT EXISTS organizations (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    slug VARCHAR(100) NOT NULL UNIQUE,
+    plan VARCHAR(50) NOT NULL DEFAULT 'free',
+    limits JSONB,
+    billing_email VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS organization_workspaces (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_workspaces_organization ON organization_workspaces(organization_id);
+
+CREATE TABLE IF NOT EXISTS organization_members (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (organization_id, email)
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_members_email ON organization_members(email);
+`
+
+const addSyncChangeTracking = `
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW();
+
+DROP TRIGGER IF EXISTS update_messages_updated_at ON messages;
+CREATE TRIGGER update_messages_updated_at
+    BEFORE UPDATE ON messages
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+CREATE INDEX IF NOT EXISTS idx_messages_updated_at ON messages(updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_conversations_tenant_updated_at ON conversations(tenant_id, updated_at, id);
+`
+
+const createKnowledgeVersionTables = `
+ALTER TABLE knowledge_items ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'published';
+ALTER TABLE knowledge_items ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE knowledge_items ADD COLUMN IF NOT EXISTS published_version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE knowledge_items ADD COLUMN IF NOT EXISTS published_by VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE knowledge_items ADD COLUMN IF NOT EXISTS published_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_knowledge_items_kb_status ON knowledge_items(knowledge_base_id, status);
+
+CREATE TABLE IF NOT EXISTS knowledge_item_versions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    item_id UUID NOT NULL REFERENCES knowledge_items(id) ON DELETE CASCADE,
+    knowledge_base_id UUID NOT NULL REFERENCES knowledge_bases(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    question TEXT NOT NULL,
+    answer TEXT NOT NULL,
+    keywords TEXT[] DEFAULT '{}',
+    source VARCHAR(255),
+    metadata JSONB DEFAULT '{}',
+    action VARCHAR(20) NOT NULL,
+    restored_from INTEGER NOT NULL DEFAULT 0,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    published_by VARCHAR(255) NOT NULL DEFAULT '',
+    published_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE (item_id, version)
+);
+
+-- Items stored before versioning start their history with their current content
+INSERT INTO knowledge_item_versions (item_id, knowledge_base_id, version, question, answer, keywords, source, metadata, action, created_at, published_at)
+SELECT i.id, i.knowledge_base_id, 1, i.question, i.answer, COALESCE(i.keywords, '{}'), COALESCE(i.source, ''), COALESCE(i.metadata, '{}'), 'created', i.created_at, i.created_at
+FROM knowledge_items i
+WHERE NOT EXISTS (SELECT 1 FROM knowledge_item_versions v WHERE v.item_id = i.id);
+
+CREATE TABLE IF NOT EXISTS knowledge_audit_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    knowledge_base_id UUID NOT NULL REFERENCES knowledge_bases(id) ON DELETE CASCADE,
+    item_id UUID NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    version INTEGER NOT NULL DEFAULT 0,
+    user_id VARCHAR(255) NOT NULL DEFAULT '',
+    question TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_knowledge_audit_events_kb ON knowledge_audit_events(knowledge_base_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_knowledge_audit_events_item ON knowledge_audit_events(item_id, created_at DESC);
+`
+
+const createDispositionTables = `
+CREATE TABLE IF NOT EXISTS dispositions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    code VARCHAR(50) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    category VARCHAR(100) NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (tenant_id, code)
+);
+
+CREATE TABLE IF NOT EXISTS conversation_dispositions (
+    conversation_id UUID PRIMARY KEY REFERENCES conversations(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    disposition_id UUID REFERENCES dispositions(id) ON DELETE RESTRICT,
+    code VARCHAR(50) NOT NULL DEFAULT '',
+    name VARCHAR(100) NOT NULL DEFAULT '',
+    notes TEXT NOT NULL DEFAULT '',
+    resolved_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_conversation_dispositions_tenant ON conversation_dispositions(tenant_id, disposition_id);
+
+-- Satisfaction score the customer gave the conversation, 1 to 5
+ALTER TABLE conversations ADD COLUMN IF NOT EXISTS csat SMALLINT;
+`
+
+const createEmbeddingCacheTable = `
+-- Embeddings keyed by the hash of the provider, model and text they were generated from
+CREATE TABLE IF NOT EXISTS embedding_cache (
+    content_hash VARCHAR(64) PRIMARY KEY,
+    model VARCHAR(100) NOT NULL DEFAULT '',
+    embedding DOUBLE PRECISION[] NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_embedding_cache_created ON embedding_cache(created_at);
+`
+
+const createConversationReopenTables = `
+-- Resolved conversations being reopened, by the customer writing within the reopen
+-- window or by an agent
+CREATE TABLE IF NOT EXISTS conversation_reopens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    source VARCHAR(20) NOT NULL,
+    reopened_by VARCHAR(255) NOT NULL DEFAULT '',
+    resolved_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    reopened_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_conversation_reopens_tenant ON conversation_reopens(tenant_id, reopened_at);
+CREATE INDEX IF NOT EXISTS idx_conversation_reopens_conversation ON conversation_reopens(conversation_id);
+CREATE INDEX IF NOT EXISTS idx_conversations_follow_up_of ON conversations((metadata->>'follow_up_of'));
+`
+
+const createAIUsageTables = `
+-- Metered AI calls with their tokens and estimated cost
+CREATE TABLE IF NOT EXISTS ai_usage_records (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    bot_id VARCHAR(36) NOT NULL DEFAULT '',
+    feature VARCHAR(30) NOT NULL,
+    provider VARCHAR(30) NOT NULL DEFAULT '',
+    model VARCHAR(100) NOT NULL DEFAULT '',
+    prompt_tokens BIGINT NOT NULL DEFAULT 0,
+    completion_tokens BIGINT NOT NULL DEFAULT 0,
+    cost_usd NUMERIC(14, 6) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_ai_usage_records_tenant ON ai_usage_records(tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_ai_usage_records_created ON ai_usage_records(created_at);
+
+-- Monthly AI usage per tenant, bot, feature and model, kept after the records are pruned
+CREATE TABLE IF NOT EXISTS ai_usage_monthly (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    month DATE NOT NULL,
+    bot_id VARCHAR(36) NOT NULL DEFAULT '',
+    feature VARCHAR(30) NOT NULL,
+    provider VARCHAR(30) NOT NULL DEFAULT '',
+    model VARCHAR(100) NOT NULL DEFAULT '',
+    requests BIGINT NOT NULL DEFAULT 0,
+    prompt_tokens BIGINT NOT NULL DEFAULT 0,
+    completion_tokens BIGINT NOT NULL DEFAULT 0,
+    cost_usd NUMERIC(16, 6) NOT NULL DEFAULT 0,
+    PRIMARY KEY (tenant_id, month, bot_id, feature, provider, model)
+);
+
+CREATE TABLE IF NOT EXISTS ai_budget_alerts (
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    period_start DATE NOT NULL,
+    level VARCHAR(10) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, period_start, level)
+);
+`
+
+const addAPIKeyRevokedAtColumn = `
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS revoked_at TIMESTAMP WITH TIME ZONE;
+`
+
+const addNewsletterThrottlingColumns = `
+ALTER TABLE newsletters ADD COLUMN IF NOT EXISTS suppress_inactive_days INT NOT NULL DEFAULT 0;
+ALTER TABLE newsletter_editions ADD COLUMN IF NOT EXISTS global_capped INT NOT NULL DEFAULT 0;
+ALTER TABLE newsletter_editions ADD COLUMN IF NOT EXISTS suppressed INT NOT NULL DEFAULT 0;
+`
+
+const createUserMFATable = `
+-- TOTP second factor of users, with the hashes of their unused recovery codes
+CREATE TABLE IF NOT EXISTS user_mfa (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    secret VARCHAR(64) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    recovery_codes TEXT[] NOT NULL DEFAULT '{}',
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    failed_attempts INT NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    enabled_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+`
+
+const createConversationParticipantTables = `
+-- External participants of group conversations (WhatsApp groups, email threads with
+-- several recipients)
+CREATE TABLE IF NOT EXISTS conversation_participants (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    contact_id UUID NOT NULL REFERENCES contacts(id) ON DELETE CASCADE,
+    identifier VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'active',
+    added_by VARCHAR(255) NOT NULL DEFAULT '',
+    joined_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    left_at TIMESTAMP WITH TIME ZONE,
+    UNIQUE (conversation_id, contact_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_conversation_participants_identifier ON conversation_participants(conversation_id, identifier);
+CREATE INDEX IF NOT EXISTS idx_conversation_participants_contact ON conversation_participants(contact_id);
+CREATE INDEX IF NOT EXISTS idx_conversations_group ON conversations(channel_id, (metadata->>'group_id'));
+
+-- Delivery status of group messages per participant
+CREATE TABLE IF NOT EXISTS message_recipients (
+    message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    contact_id UUID REFERENCES contacts(id) ON DELETE SET NULL,
+    identifier VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    error_message TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (message_id, identifier)
+);
+`
+
+const createTenantSSOConfigsTable = `
+-- OIDC or SAML identity provider tenant users sign in with
+CREATE TABLE IF NOT EXISTS tenant_sso_configs (
+    tenant_id UUID PRIMARY KEY REFERENCES tenants(id) ON DELETE CASCADE,
+    protocol VARCHAR(10) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    issuer TEXT NOT NULL DEFAULT '',
+    client_id TEXT NOT NULL DEFAULT '',
+    client_secret TEXT NOT NULL DEFAULT '',
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    idp_entity_id TEXT NOT NULL DEFAULT '',
+    idp_sso_url TEXT NOT NULL DEFAULT '',
+    idp_certificate TEXT NOT NULL DEFAULT '',
+    email_attribute VARCHAR(255) NOT NULL DEFAULT '',
+    name_attribute VARCHAR(255) NOT NULL DEFAULT '',
+    groups_attribute VARCHAR(255) NOT NULL DEFAULT '',
+    jit_provisioning BOOLEAN NOT NULL DEFAULT FALSE,
+    default_role VARCHAR(50) NOT NULL DEFAULT 'agent',
+    group_roles JSONB NOT NULL DEFAULT '{}',
+    redirect_urls TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+`
+
+const addSimilarReplyColumns = `
+-- Embeddings of summary issues find resolved conversations similar to a new one
+ALTER TABLE conversation_summaries ADD COLUMN IF NOT EXISTS embedding DOUBLE PRECISION[];
+ALTER TABLE copilot_suggestions ADD COLUMN IF NOT EXISTS source_conversation_id VARCHAR(255) NOT NULL DEFAULT '';
+`
+
+const createChannelTokensTable = `
+CREATE TABLE IF NOT EXISTS channel_tokens (
+    channel_id UUID PRIMARY KEY REFERENCES channels(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    fingerprint VARCHAR(64) NOT NULL DEFAULT '',
+    status VARCHAR(32) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    checked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    refreshed_at TIMESTAMP WITH TIME ZONE,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+`
+
+const createChannelMaintenanceWindowsTable = `
+CREATE TABLE IF NOT EXISTS channel_maintenance_windows (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    channel_id UUID NOT NULL REFERENCES channels(id) ON DELETE CASCADE,
+    title VARCHAR(255) NOT NULL DEFAULT '',
+    notice TEXT NOT NULL DEFAULT '',
+    starts_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    ends_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_by VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_channel_maintenance_windows_channel ON channel_maintenance_windows(channel_id, ends_at);
+`
+
+const createMessageTranslationsTable = `
+CREATE TABLE IF NOT EXISTS message_translations (
+    message_id UUID NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    language VARCHAR(16) NOT NULL,
+    source_language VARCHAR(16) NOT NULL DEFAULT '',
+    content TEXT NOT NULL,
+    source_digest VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (message_id, language)
+);
+`
+
+const createDeadLetterReplaysTable = `
+CREATE TABLE IF NOT EXISTS dead_letter_replays (
+    id UUID PRIMARY KEY,
+    stream VARCHAR(32) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    dead_letter_sequence BIGINT NOT NULL,
+    original_sequence BIGINT NOT NULL DEFAULT 0,
+    consumer VARCHAR(100) NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    replayed_by VARCHAR(255) NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    replayed_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_dead_letter_replays_stream ON dead_letter_replays(stream, replayed_at DESC);
+`
+
+const createKnowledgeSharingTables = `
+-- Global knowledge bases belong to no tenant
+ALTER TABLE knowledge_bases ADD COLUMN IF NOT EXISTS is_global BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE knowledge_bases ALTER COLUMN tenant_id DROP NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_knowledge_bases_global ON knowledge_bases(is_global) WHERE is_global;
+
+CREATE TABLE IF NOT EXISTS knowledge_base_attachments (
+    knowledge_base_id UUID NOT NULL REFERENCES knowledge_bases(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    attached_by VARCHAR(255) NOT NULL DEFAULT '',
+    attached_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (knowledge_base_id, tenant_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_knowledge_base_attachments_tenant ON knowledge_base_attachments(tenant_id);
+
+-- Items tenants add to global knowledge bases, optionally overriding a shared item;
+-- overrides of a deleted shared item stay as the tenant's own items
+ALTER TABLE knowledge_items ADD COLUMN IF NOT EXISTS tenant_id UUID REFERENCES tenants(id) ON DELETE CASCADE;
+ALTER TABLE knowledge_items ADD COLUMN IF NOT EXISTS overrides_item_id UUID REFERENCES knowledge_items(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_knowledge_items_tenant ON knowledge_items(knowledge_base_id, tenant_id) WHERE tenant_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_knowledge_items_override ON knowledge_items(tenant_id, overrides_item_id) WHERE overrides_item_id IS NOT NULL;
+`
+
+const createNotificationsTable = `
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    type VARCHAR(64) NOT NULL,
+    severity VARCHAR(20) NOT NULL DEFAULT 'info',
+    title VARCHAR(255) NOT NULL,
+    message TEXT NOT NULL DEFAULT '',
+    resource_type VARCHAR(64) NOT NULL DEFAULT '',
+    resource_id VARCHAR(255) NOT NULL DEFAULT '',
+    key VARCHAR(255) NOT NULL DEFAULT '',
+    read_at TIMESTAMP WITH TIME ZONE,
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_tenant ON notifications(tenant_id, updated_at DESC);
+-- A condition has at most one open notification
+CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_open_key ON notifications(tenant_id, key) WHERE resolved_at IS NULL AND key <> '';
+`
+
+const createChannelCredentialHealthTable = `
+CREATE TABLE IF NOT EXISTS channel_credential_health (
+    channel_id UUID PRIMARY KEY REFERENCES channels(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL,
+    account VARCHAR(255) NOT NULL DEFAULT '',
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT NOT NULL DEFAULT '',
+    failures INTEGER NOT NULL DEFAULT 0,
+    failing_since TIMESTAMP WITH TIME ZONE,
+    checked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_channel_credential_health_tenant ON channel_credential_health(tenant_id);
+`
+
+const addSSOSavedByOwnerColumn = `
+-- Owners only sign in through identity providers an owner configured
+ALTER TABLE tenant_sso_configs ADD COLUMN IF NOT EXISTS saved_by_owner BOOLEAN NOT NULL DEFAULT FALSE;
+`
@@ -0,0 +1,532 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// QARepository implements repository.QARepository with PostgreSQL
+type QARepository struct {
+	db *PostgresDB
+}
+
+// NewQARepository creates a new PostgreSQL QA repository
+func NewQARepository(db *PostgresDB) *QARepository {
+	return &QARepository{db: db}
+}
+
+const qaScorecardColumns = `id, tenant_id, name, description, criteria, is_active, created_at, updated_at`
+
+const qaRuleColumns = `
+	id, tenant_id, name, scorecard_id, channel_ids, min_messages, sample_percent,
+	max_per_agent, is_active, last_run_at, created_at, updated_at
+`
+
+const qaReviewColumns = `
+	id, tenant_id, conversation_id, agent_id, scorecard_id, rule_id, reviewer_id, status,
+	scores, comment, total_score, completed_at, created_at, updated_at
+`
+
+// CreateScorecard creates a new scorecard
+func (r *QARepository) CreateScorecard(ctx context.Context, scorecard *entity.QAScorecard) error {
+	criteria, err := json.Marshal(scorecard.Criteria)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal scorecard criteria")
+	}
+
+	query := `INSERT INTO qa_scorecards (` + qaScorecardColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	_, err = r.db.Pool.Exec(ctx, query,
+		scorecard.ID,
+		scorecard.TenantID,
+		scorecard.Name,
+		scorecard.Description,
+		criteria,
+		scorecard.IsActive,
+		scorecard.CreatedAt,
+		scorecard.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create scorecard")
+	}
+	return nil
+}
+
+// FindScorecardByID finds a scorecard by ID
+func (r *QARepository) FindScorecardByID(ctx context.Context, id string) (*entity.QAScorecard, error) {
+	query := `SELECT ` + qaScorecardColumns + ` FROM qa_scorecards WHERE id = $1`
+	return r.scanScorecard(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// ListScorecards lists the scorecards of a tenant
+func (r *QARepository) ListScorecards(ctx context.Context, tenantID string) ([]*entity.QAScorecard, error) {
+	query := `SELECT ` + qaScorecardColumns + ` FROM qa_scorecards WHERE tenant_id = $1 ORDER BY created_at DESC`
+
+	rows, err := r.db.Pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list scorecards")
+	}
+	defer rows.Close()
+
+	var scorecards []*entity.QAScorecard
+	for rows.Next() {
+		scorecard, err := r.scanScorecard(rows)
+		if err != nil {
+			return nil, err
+		}
+		scorecards = append(scorecards, scorecard)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate scorecards")
+	}
+	return scorecards, nil
+}
+
+// UpdateScorecard updates a scorecard
+func (r *QARepository) UpdateScorecard(ctx context.Context, scorecard *entity.QAScorecard) error {
+	criteria, err := json.Marshal(scorecard.Criteria)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal scorecard criteria")
+	}
+
+	query := `
+		UPDATE qa_scorecards
+		SET name = $2, description = $3, criteria = $4, is_active = $5, updated_at = $6
+		WHERE id = $1
+	`
+	result, err := r.db.Pool.Exec(ctx, query,
+		scorecard.ID,
+		scorecard.Name,
+		scorecard.Description,
+		criteria,
+		scorecard.IsActive,
+		scorecard.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update scorecard")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "scorecard not found")
+	}
+	return nil
+}
+
+// DeleteScorecard deletes a scorecard
+func (r *QARepository) DeleteScorecard(ctx context.Context, id string) error {
+	result, err := r.db.Pool.Exec(ctx, `DELETE FROM qa_scorecards WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete scorecard")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "scorecard not found")
+	}
+	return nil
+}
+
+// CreateRule creates a new sampling rule
+func (r *QARepository) CreateRule(ctx context.Context, rule *entity.QASamplingRule) error {
+	query := `INSERT INTO qa_sampling_rules (` + qaRuleColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+	_, err := r.db.Pool.Exec(ctx, query,
+		rule.ID,
+		rule.TenantID,
+		rule.Name,
+		rule.ScorecardID,
+		rule.ChannelIDs,
+		rule.MinMessages,
+		rule.SamplePercent,
+		rule.MaxPerAgent,
+		rule.IsActive,
+		rule.LastRunAt,
+		rule.CreatedAt,
+		rule.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create sampling rule")
+	}
+	return nil
+}
+
+// FindRuleByID finds a sampling rule by ID
+func (r *QARepository) FindRuleByID(ctx context.Context, id string) (*entity.QASamplingRule, error) {
+	query := `SELECT ` + qaRuleColumns + ` FROM qa_sampling_rules WHERE id = $1`
+	return r.scanRule(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// ListRules lists the sampling rules of a tenant
+func (r *QARepository) ListRules(ctx context.Context, tenantID string) ([]*entity.QASamplingRule, error) {
+	query := `SELECT ` + qaRuleColumns + ` FROM qa_sampling_rules WHERE tenant_id = $1 ORDER BY created_at DESC`
+	return r.queryRules(ctx, query, tenantID)
+}
+
+// ListActiveRules lists the active sampling rules of all tenants
+func (r *QARepository) ListActiveRules(ctx context.Context) ([]*entity.QASamplingRule, error) {
+	query := `SELECT ` + qaRuleColumns + ` FROM qa_sampling_rules WHERE is_active = TRUE`
+	return r.queryRules(ctx, query)
+}
+
+// UpdateRule updates a sampling rule
+func (r *QARepository) UpdateRule(ctx context.Context, rule *entity.QASamplingRule) error {
+	query := `
+		UPDATE qa_sampling_rules
+		SET name = $2, scorecard_id = $3, channel_ids = $4, min_messages = $5, sample_percent = $6,
+		    max_per_agent = $7, is_active = $8, last_run_at = $9, updated_at = $10
+		WHERE id = $1
+	`
+	result, err := r.db.Pool.Exec(ctx, query,
+		rule.ID,
+		rule.Name,
+		rule.ScorecardID,
+		rule.ChannelIDs,
+		rule.MinMessages,
+		rule.SamplePercent,
+		rule.MaxPerAgent,
+		rule.IsActive,
+		rule.LastRunAt,
+		rule.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update sampling rule")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "sampling rule not found")
+	}
+	return nil
+}
+
+// DeleteRule deletes a sampling rule
+func (r *QARepository) DeleteRule(ctx context.Context, id string) error {
+	result, err := r.db.Pool.Exec(ctx, `DELETE FROM qa_sampling_rules WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete sampling rule")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "sampling rule not found")
+	}
+	return nil
+}
+
+// FindSampleCandidates returns conversations resolved since the given time that match
+// the rule, have an assigned agent and were not reviewed yet
+func (r *QARepository) FindSampleCandidates(ctx context.Context, rule *entity.QASamplingRule, since time.Time) ([]entity.QASampleCandidate, error) {
+	query := `
+		SELECT c.id, c.assignee_id
+		FROM conversations c
+		WHERE c.tenant_id = $1
+		  AND c.status IN ('resolved', 'closed')
+		  AND c.assignee_id IS NOT NULL
+		  AND c.resolved_at >= $2
+		  AND (cardinality($3::text[]) = 0 OR c.channel_id::text = ANY($3::text[]))
+		  AND (SELECT COUNT(*) FROM messages m WHERE m.conversation_id = c.id) >= $4
+		  AND NOT EXISTS (SELECT 1 FROM qa_reviews qr WHERE qr.conversation_id = c.id::text)
+		ORDER BY c.resolved_at ASC
+	`
+
+	channelIDs := rule.ChannelIDs
+	if channelIDs == nil {
+		channelIDs = []string{}
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, rule.TenantID, since, channelIDs, rule.MinMessages)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find QA sample candidates")
+	}
+	defer rows.Close()
+
+	var candidates []entity.QASampleCandidate
+	for rows.Next() {
+		var candidate entity.QASampleCandidate
+		if err := rows.Scan(&candidate.ConversationID, &candidate.AgentID); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan QA sample candidate")
+		}
+		candidates = append(candidates, candidate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate QA sample candidates")
+	}
+	return candidates, nil
+}
+
+// CreateReview creates a new review
+func (r *QARepository) CreateReview(ctx context.Context, review *entity.QAReview) error {
+	scores, err := json.Marshal(review.Scores)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal review scores")
+	}
+
+	query := `INSERT INTO qa_reviews (` + qaReviewColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+	_, err = r.db.Pool.Exec(ctx, query,
+		review.ID,
+		review.TenantID,
+		review.ConversationID,
+		review.AgentID,
+		review.ScorecardID,
+		review.RuleID,
+		review.ReviewerID,
+		string(review.Status),
+		scores,
+		review.Comment,
+		review.TotalScore,
+		review.CompletedAt,
+		review.CreatedAt,
+		review.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create review")
+	}
+	return nil
+}
+
+// FindReviewByID finds a review by ID
+func (r *QARepository) FindReviewByID(ctx context.Context, id string) (*entity.QAReview, error) {
+	query := `SELECT ` + qaReviewColumns + ` FROM qa_reviews WHERE id = $1`
+	return r.scanReview(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// ListReviews lists the reviews of a tenant
+func (r *QARepository) ListReviews(ctx context.Context, tenantID string, filter *entity.QAReviewFilter, params *repository.ListParams) ([]*entity.QAReview, int64, error) {
+	conditions := "tenant_id = $1"
+	args := []interface{}{tenantID}
+
+	if filter != nil {
+		if filter.AgentID != "" {
+			args = append(args, filter.AgentID)
+			conditions += fmt.Sprintf(" AND agent_id = $%d", len(args))
+		}
+		if filter.ReviewerID != "" {
+			args = append(args, filter.ReviewerID)
+			conditions += fmt.Sprintf(" AND reviewer_id = $%d", len(args))
+		}
+		if filter.ScorecardID != "" {
+			args = append(args, filter.ScorecardID)
+			conditions += fmt.Sprintf(" AND scorecard_id = $%d", len(args))
+		}
+		if filter.Status != "" {
+			args = append(args, string(filter.Status))
+			conditions += fmt.Sprintf(" AND status = $%d", len(args))
+		}
+	}
+
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM qa_reviews WHERE "+conditions, args...).Scan(&total); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count reviews")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM qa_reviews
+		WHERE %s
+		ORDER BY created_at %s
+		LIMIT $%d OFFSET $%d
+	`, qaReviewColumns, conditions, sanitizeDirection(params.SortDir), len(args)+1, len(args)+2)
+	args = append(args, params.Limit(), params.Offset())
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to list reviews")
+	}
+	defer rows.Close()
+
+	var reviews []*entity.QAReview
+	for rows.Next() {
+		review, err := r.scanReview(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		reviews = append(reviews, review)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate reviews")
+	}
+	return reviews, total, nil
+}
+
+// UpdateReview updates a review
+func (r *QARepository) UpdateReview(ctx context.Context, review *entity.QAReview) error {
+	scores, err := json.Marshal(review.Scores)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal review scores")
+	}
+
+	query := `
+		UPDATE qa_reviews
+		SET reviewer_id = $2, status = $3, scores = $4, comment = $5, total_score = $6,
+		    completed_at = $7, updated_at = $8
+		WHERE id = $1
+	`
+	result, err := r.db.Pool.Exec(ctx, query,
+		review.ID,
+		review.ReviewerID,
+		string(review.Status),
+		scores,
+		review.Comment,
+		review.TotalScore,
+		review.CompletedAt,
+		review.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update review")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "review not found")
+	}
+	return nil
+}
+
+// SummarizeByAgent aggregates completed reviews per agent in a period
+func (r *QARepository) SummarizeByAgent(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.AgentQualitySummary, error) {
+	query := `
+		SELECT qr.agent_id, COALESCE(u.name, ''), COUNT(*), AVG(qr.total_score),
+		       MIN(qr.total_score), MAX(qr.total_score), MAX(qr.completed_at)
+		FROM qa_reviews qr
+		LEFT JOIN users u ON u.id::text = qr.agent_id
+		WHERE qr.tenant_id = $1 AND qr.status = 'completed'
+		  AND qr.completed_at BETWEEN $2 AND $3
+		GROUP BY qr.agent_id, u.name
+		ORDER BY AVG(qr.total_score) DESC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, filter.TenantID, filter.StartDate, filter.EndDate)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to summarize agent quality")
+	}
+	defer rows.Close()
+
+	var summaries []entity.AgentQualitySummary
+	for rows.Next() {
+		var summary entity.AgentQualitySummary
+		if err := rows.Scan(
+			&summary.AgentID,
+			&summary.AgentName,
+			&summary.Reviews,
+			&summary.AverageScore,
+			&summary.MinScore,
+			&summary.MaxScore,
+			&summary.LastReviewAt,
+		); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan agent quality")
+		}
+		summaries = append(summaries, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate agent quality")
+	}
+	return summaries, nil
+}
+
+func (r *QARepository) queryRules(ctx context.Context, query string, args ...interface{}) ([]*entity.QASamplingRule, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list sampling rules")
+	}
+	defer rows.Close()
+
+	var rules []*entity.QASamplingRule
+	for rows.Next() {
+		rule, err := r.scanRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate sampling rules")
+	}
+	return rules, nil
+}
+
+func (r *QARepository) scanScorecard(row pgx.Row) (*entity.QAScorecard, error) {
+	var scorecard entity.QAScorecard
+	var criteria []byte
+
+	err := row.Scan(
+		&scorecard.ID,
+		&scorecard.TenantID,
+		&scorecard.Name,
+		&scorecard.Description,
+		&criteria,
+		&scorecard.IsActive,
+		&scorecard.CreatedAt,
+		&scorecard.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "scorecard not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan scorecard")
+	}
+
+	if len(criteria) > 0 {
+		if err := json.Unmarshal(criteria, &scorecard.Criteria); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal scorecard criteria")
+		}
+	}
+	return &scorecard, nil
+}
+
+func (r *QARepository) scanRule(row pgx.Row) (*entity.QASamplingRule, error) {
+	var rule entity.QASamplingRule
+
+	err := row.Scan(
+		&rule.ID,
+		&rule.TenantID,
+		&rule.Name,
+		&rule.ScorecardID,
+		&rule.ChannelIDs,
+		&rule.MinMessages,
+		&rule.SamplePercent,
+		&rule.MaxPerAgent,
+		&rule.IsActive,
+		&rule.LastRunAt,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "sampling rule not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan sampling rule")
+	}
+	return &rule, nil
+}
+
+func (r *QARepository) scanReview(row pgx.Row) (*entity.QAReview, error) {
+	var review entity.QAReview
+	var status string
+	var scores []byte
+
+	err := row.Scan(
+		&review.ID,
+		&review.TenantID,
+		&review.ConversationID,
+		&review.AgentID,
+		&review.ScorecardID,
+		&review.RuleID,
+		&review.ReviewerID,
+		&status,
+		&scores,
+		&review.Comment,
+		&review.TotalScore,
+		&review.CompletedAt,
+		&review.CreatedAt,
+		&review.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "review not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan review")
+	}
+
+	review.Status = entity.QAReviewStatus(status)
+	if len(scores) > 0 {
+		if err := json.Unmarshal(scores, &review.Scores); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal review scores")
+		}
+	}
+	return &review, nil
+}
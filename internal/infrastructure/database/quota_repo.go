@@ -0,0 +1,132 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// QuotaRepository implements repository.QuotaRepository with PostgreSQL
+type QuotaRepository struct {
+	db *PostgresDB
+}
+
+// NewQuotaRepository creates a new PostgreSQL quota repository
+func NewQuotaRepository(db *PostgresDB) *QuotaRepository {
+	return &QuotaRepository{db: db}
+}
+
+// AddUsage adds to the daily usage counters, creating missing ones
+func (r *QuotaRepository) AddUsage(ctx context.Context, usage []entity.QuotaUsage) error {
+	if len(usage) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to begin usage update")
+	}
+	defer tx.Rollback(ctx)
+
+	for _, u := range usage {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO quota_usage (tenant_id, metric, route, day, count)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (tenant_id, metric, route, day) DO UPDATE SET
+				count = quota_usage.count + EXCLUDED.count
+		`, u.TenantID, u.Metric, u.Route, u.Day, u.Count); err != nil {
+			return errors.Wrap(err, errors.ErrCodeInternal, "failed to add usage")
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to commit usage update")
+	}
+	return nil
+}
+
+// SumUsage sums a tenant's usage per metric for days in [since, until)
+func (r *QuotaRepository) SumUsage(ctx context.Context, tenantID string, since, until time.Time) (map[entity.QuotaMetric]int64, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT metric, COALESCE(SUM(count), 0)
+		FROM quota_usage
+		WHERE tenant_id = $1 AND day >= $2 AND day < $3
+		GROUP BY metric
+	`, tenantID, since, until)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to sum usage")
+	}
+	defer rows.Close()
+
+	totals := make(map[entity.QuotaMetric]int64)
+	for rows.Next() {
+		var metric string
+		var total int64
+		if err := rows.Scan(&metric, &total); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan usage")
+		}
+		totals[entity.QuotaMetric(metric)] = total
+	}
+	return totals, rows.Err()
+}
+
+// ListRouteUsage returns a tenant's API calls per route for days in [since, until),
+// busiest routes first
+func (r *QuotaRepository) ListRouteUsage(ctx context.Context, tenantID string, since, until time.Time, limit int) ([]entity.RouteUsage, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT route, SUM(count) AS calls
+		FROM quota_usage
+		WHERE tenant_id = $1 AND metric = $2 AND day >= $3 AND day < $4
+		GROUP BY route
+		ORDER BY calls DESC, route
+		LIMIT $5
+	`, tenantID, entity.QuotaMetricAPICalls, since, until, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list route usage")
+	}
+	defer rows.Close()
+
+	routes := make([]entity.RouteUsage, 0)
+	for rows.Next() {
+		var route entity.RouteUsage
+		if err := rows.Scan(&route.Route, &route.Calls); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan route usage")
+		}
+		routes = append(routes, route)
+	}
+	return routes, rows.Err()
+}
+
+// StorageBytes returns the size of a tenant's stored media, in total and added since a time
+func (r *QuotaRepository) StorageBytes(ctx context.Context, tenantID string, since time.Time) (int64, int64, error) {
+	var total, added int64
+	err := r.db.Pool.QueryRow(ctx, `
+		SELECT
+			COALESCE(SUM(a.size_bytes), 0),
+			COALESCE(SUM(a.size_bytes) FILTER (WHERE a.created_at >= $2), 0)
+		FROM message_attachments a
+		JOIN messages m ON m.id = a.message_id
+		JOIN conversations c ON c.id = m.conversation_id
+		WHERE c.tenant_id = $1
+	`, tenantID, since).Scan(&total, &added)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to sum storage")
+	}
+	return total, added, nil
+}
+
+// CreateAlert records a sent threshold alert. It returns false when the alert was
+// already recorded for the period.
+func (r *QuotaRepository) CreateAlert(ctx context.Context, alert *entity.QuotaAlert) (bool, error) {
+	tag, err := r.db.Pool.Exec(ctx, `
+		INSERT INTO quota_alerts (tenant_id, metric, period_start, threshold, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (tenant_id, metric, period_start, threshold) DO NOTHING
+	`, alert.TenantID, alert.Metric, alert.PeriodStart, alert.Threshold, alert.CreatedAt)
+	if err != nil {
+		return false, errors.Wrap(err, errors.ErrCodeInternal, "failed to record quota alert")
+	}
+	return tag.RowsAffected() == 1, nil
+}
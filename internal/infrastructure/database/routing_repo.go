@@ -0,0 +1,252 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// RoutingRepository implements repository.RoutingRepository with PostgreSQL
+type RoutingRepository struct {
+	db *PostgresDB
+}
+
+// NewRoutingRepository creates a new PostgreSQL routing repository
+func NewRoutingRepository(db *PostgresDB) *RoutingRepository {
+	return &RoutingRepository{db: db}
+}
+
+const routingRuleColumns = `
+	id, tenant_id, name, position, enabled, conditions, required_skills, match_language, agent_ids,
+	strategy, max_active_conversations, last_assigned_user_id, created_at, updated_at,
+	translation_fallback, match_channel
+`
+
+// CreateRule creates a new routing rule
+func (r *RoutingRepository) CreateRule(ctx context.Context, rule *entity.RoutingRule) error {
+	conditions, err := json.Marshal(rule.Conditions)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal routing conditions")
+	}
+
+	query := `INSERT INTO routing_rules (` + routingRuleColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`
+	_, err = r.db.Pool.Exec(ctx, query,
+		rule.ID,
+		rule.TenantID,
+		rule.Name,
+		rule.Position,
+		rule.Enabled,
+		conditions,
+		rule.RequiredSkills,
+		rule.MatchLanguage,
+		rule.AgentIDs,
+		string(rule.Strategy),
+		rule.MaxActiveConversations,
+		rule.LastAssignedUserID,
+		rule.CreatedAt,
+		rule.UpdatedAt,
+		rule.TranslationFallback,
+		rule.MatchChannel,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create routing rule")
+	}
+	return nil
+}
+
+// FindRuleByID finds a routing rule by ID
+func (r *RoutingRepository) FindRuleByID(ctx context.Context, id string) (*entity.RoutingRule, error) {
+	query := `SELECT ` + routingRuleColumns + ` FROM routing_rules WHERE id = $1`
+	return r.scanRule(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// FindRulesByTenant lists the routing rules of a tenant by position
+func (r *RoutingRepository) FindRulesByTenant(ctx context.Context, tenantID string) ([]*entity.RoutingRule, error) {
+	query := `SELECT ` + routingRuleColumns + ` FROM routing_rules WHERE tenant_id = $1 ORDER BY position, created_at`
+	rows, err := r.db.Pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list routing rules")
+	}
+	defer rows.Close()
+
+	var rules []*entity.RoutingRule
+	for rows.Next() {
+		rule, err := r.scanRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate routing rules")
+	}
+	return rules, nil
+}
+
+// UpdateRule updates a routing rule
+func (r *RoutingRepository) UpdateRule(ctx context.Context, rule *entity.RoutingRule) error {
+	conditions, err := json.Marshal(rule.Conditions)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal routing conditions")
+	}
+
+	query := `
+		UPDATE routing_rules
+		SET name = $2, position = $3, enabled = $4, conditions = $5, required_skills = $6,
+		    match_language = $7, agent_ids = $8, strategy = $9, max_active_conversations = $10,
+		    updated_at = $11, translation_fallback = $12, match_channel = $13
+		WHERE id = $1
+	`
+	tag, err := r.db.Pool.Exec(ctx, query,
+		rule.ID,
+		rule.Name,
+		rule.Position,
+		rule.Enabled,
+		conditions,
+		rule.RequiredSkills,
+		rule.MatchLanguage,
+		rule.AgentIDs,
+		string(rule.Strategy),
+		rule.MaxActiveConversations,
+		rule.UpdatedAt,
+		rule.TranslationFallback,
+		rule.MatchChannel,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update routing rule")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "routing rule not found")
+	}
+	return nil
+}
+
+// DeleteRule deletes a routing rule
+func (r *RoutingRepository) DeleteRule(ctx context.Context, id string) error {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM routing_rules WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete routing rule")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "routing rule not found")
+	}
+	return nil
+}
+
+// UpdateLastAssigned moves the round-robin cursor of a rule
+func (r *RoutingRepository) UpdateLastAssigned(ctx context.Context, ruleID, userID string) error {
+	_, err := r.db.Pool.Exec(ctx, `UPDATE routing_rules SET last_assigned_user_id = $2 WHERE id = $1`, ruleID, userID)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update routing rule cursor")
+	}
+	return nil
+}
+
+// FindProfiles lists the routing profiles of a tenant's agents
+func (r *RoutingRepository) FindProfiles(ctx context.Context, tenantID string) ([]*entity.AgentRoutingProfile, error) {
+	query := `
+		SELECT user_id, tenant_id, skills, languages, channels, translation_assisted, max_active_conversations, updated_at
+		FROM agent_routing_profiles
+		WHERE tenant_id = $1
+	`
+	rows, err := r.db.Pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list agent routing profiles")
+	}
+	defer rows.Close()
+
+	var profiles []*entity.AgentRoutingProfile
+	for rows.Next() {
+		var profile entity.AgentRoutingProfile
+		if err := rows.Scan(
+			&profile.UserID,
+			&profile.TenantID,
+			&profile.Skills,
+			&profile.Languages,
+			&profile.Channels,
+			&profile.TranslationAssisted,
+			&profile.MaxActiveConversations,
+			&profile.UpdatedAt,
+		); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan agent routing profile")
+		}
+		profiles = append(profiles, &profile)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate agent routing profiles")
+	}
+	return profiles, nil
+}
+
+// SaveProfile creates or replaces the routing profile of an agent
+func (r *RoutingRepository) SaveProfile(ctx context.Context, profile *entity.AgentRoutingProfile) error {
+	query := `
+		INSERT INTO agent_routing_profiles (user_id, tenant_id, skills, languages, channels, translation_assisted,
+			max_active_conversations, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (user_id) DO UPDATE SET
+			skills = EXCLUDED.skills,
+			languages = EXCLUDED.languages,
+			channels = EXCLUDED.channels,
+			translation_assisted = EXCLUDED.translation_assisted,
+			max_active_conversations = EXCLUDED.max_active_conversations,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err := r.db.Pool.Exec(ctx, query,
+		profile.UserID,
+		profile.TenantID,
+		profile.Skills,
+		profile.Languages,
+		profile.Channels,
+		profile.TranslationAssisted,
+		profile.MaxActiveConversations,
+		profile.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to save agent routing profile")
+	}
+	return nil
+}
+
+func (r *RoutingRepository) scanRule(row pgx.Row) (*entity.RoutingRule, error) {
+	var rule entity.RoutingRule
+	var conditions []byte
+	var strategy string
+
+	err := row.Scan(
+		&rule.ID,
+		&rule.TenantID,
+		&rule.Name,
+		&rule.Position,
+		&rule.Enabled,
+		&conditions,
+		&rule.RequiredSkills,
+		&rule.MatchLanguage,
+		&rule.AgentIDs,
+		&strategy,
+		&rule.MaxActiveConversations,
+		&rule.LastAssignedUserID,
+		&rule.CreatedAt,
+		&rule.UpdatedAt,
+		&rule.TranslationFallback,
+		&rule.MatchChannel,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "routing rule not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan routing rule")
+	}
+
+	if len(conditions) > 0 {
+		if err := json.Unmarshal(conditions, &rule.Conditions); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal routing conditions")
+		}
+	}
+	rule.Strategy = entity.RoutingStrategy(strategy)
+	return &rule, nil
+}
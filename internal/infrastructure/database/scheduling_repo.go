@@ -0,0 +1,475 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// BookingCalendarRepository implements repository.BookingCalendarRepository with PostgreSQL
+type BookingCalendarRepository struct {
+	db *PostgresDB
+}
+
+// NewBookingCalendarRepository creates a new PostgreSQL booking calendar repository
+func NewBookingCalendarRepository(db *PostgresDB) *BookingCalendarRepository {
+	return &BookingCalendarRepository{db: db}
+}
+
+const bookingCalendarColumns = `
+	id, tenant_id, name, provider, external_calendar_id, timezone, slot_minutes, buffer_minutes,
+	min_notice_minutes, max_days_ahead, availability, reminder_minutes, event_title, is_active,
+	credentials, created_at, updated_at
+`
+
+// Create creates a new booking calendar
+func (r *BookingCalendarRepository) Create(ctx context.Context, calendar *entity.BookingCalendar) error {
+	availability, reminders, credentials, err := marshalBookingCalendar(calendar)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO booking_calendars (` + bookingCalendarColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)`
+	_, err = r.db.Pool.Exec(ctx, query,
+		calendar.ID,
+		calendar.TenantID,
+		calendar.Name,
+		string(calendar.Provider),
+		calendar.ExternalCalendarID,
+		calendar.Timezone,
+		calendar.SlotMinutes,
+		calendar.BufferMinutes,
+		calendar.MinNoticeMinutes,
+		calendar.MaxDaysAhead,
+		availability,
+		reminders,
+		calendar.EventTitle,
+		calendar.IsActive,
+		credentials,
+		calendar.CreatedAt,
+		calendar.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create booking calendar")
+	}
+	return nil
+}
+
+// FindByID finds a booking calendar by ID
+func (r *BookingCalendarRepository) FindByID(ctx context.Context, id string) (*entity.BookingCalendar, error) {
+	query := `SELECT ` + bookingCalendarColumns + ` FROM booking_calendars WHERE id = $1`
+	return r.scanCalendar(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// ListByTenant lists the booking calendars of a tenant
+func (r *BookingCalendarRepository) ListByTenant(ctx context.Context, tenantID string) ([]*entity.BookingCalendar, error) {
+	query := `SELECT ` + bookingCalendarColumns + ` FROM booking_calendars WHERE tenant_id = $1 ORDER BY name`
+	rows, err := r.db.Pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list booking calendars")
+	}
+	defer rows.Close()
+
+	var calendars []*entity.BookingCalendar
+	for rows.Next() {
+		calendar, err := r.scanCalendar(rows)
+		if err != nil {
+			return nil, err
+		}
+		calendars = append(calendars, calendar)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate booking calendars")
+	}
+	return calendars, nil
+}
+
+// Update updates a booking calendar, including its credentials
+func (r *BookingCalendarRepository) Update(ctx context.Context, calendar *entity.BookingCalendar) error {
+	availability, reminders, credentials, err := marshalBookingCalendar(calendar)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE booking_calendars
+		SET name = $2, provider = $3, external_calendar_id = $4, timezone = $5, slot_minutes = $6,
+		    buffer_minutes = $7, min_notice_minutes = $8, max_days_ahead = $9, availability = $10,
+		    reminder_minutes = $11, event_title = $12, is_active = $13, credentials = $14, updated_at = $15
+		WHERE id = $1
+	`
+	tag, err := r.db.Pool.Exec(ctx, query,
+		calendar.ID,
+		calendar.Name,
+		string(calendar.Provider),
+		calendar.ExternalCalendarID,
+		calendar.Timezone,
+		calendar.SlotMinutes,
+		calendar.BufferMinutes,
+		calendar.MinNoticeMinutes,
+		calendar.MaxDaysAhead,
+		availability,
+		reminders,
+		calendar.EventTitle,
+		calendar.IsActive,
+		credentials,
+		calendar.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update booking calendar")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "booking calendar not found")
+	}
+	return nil
+}
+
+// Delete deletes a booking calendar
+func (r *BookingCalendarRepository) Delete(ctx context.Context, id string) error {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM booking_calendars WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete booking calendar")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "booking calendar not found")
+	}
+	return nil
+}
+
+func marshalBookingCalendar(calendar *entity.BookingCalendar) ([]byte, []byte, []byte, error) {
+	availability, err := json.Marshal(calendar.Availability)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal availability")
+	}
+	reminders, err := json.Marshal(calendar.ReminderMinutes)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal reminders")
+	}
+	credentials, err := json.Marshal(calendar.Credentials)
+	if err != nil {
+		return nil, nil, nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal calendar credentials")
+	}
+	return availability, reminders, credentials, nil
+}
+
+func (r *BookingCalendarRepository) scanCalendar(row pgx.Row) (*entity.BookingCalendar, error) {
+	var calendar entity.BookingCalendar
+	var provider string
+	var availability, reminders, credentials []byte
+
+	err := row.Scan(
+		&calendar.ID,
+		&calendar.TenantID,
+		&calendar.Name,
+		&provider,
+		&calendar.ExternalCalendarID,
+		&calendar.Timezone,
+		&calendar.SlotMinutes,
+		&calendar.BufferMinutes,
+		&calendar.MinNoticeMinutes,
+		&calendar.MaxDaysAhead,
+		&availability,
+		&reminders,
+		&calendar.EventTitle,
+		&calendar.IsActive,
+		&credentials,
+		&calendar.CreatedAt,
+		&calendar.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "booking calendar not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan booking calendar")
+	}
+
+	calendar.Provider = entity.CalendarProvider(provider)
+	if len(availability) > 0 {
+		if err := json.Unmarshal(availability, &calendar.Availability); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal availability")
+		}
+	}
+	if len(reminders) > 0 {
+		if err := json.Unmarshal(reminders, &calendar.ReminderMinutes); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal reminders")
+		}
+	}
+	if len(credentials) > 0 {
+		if err := json.Unmarshal(credentials, &calendar.Credentials); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal calendar credentials")
+		}
+	}
+	calendar.Connected = calendar.Credentials != nil && calendar.Credentials.RefreshToken != ""
+	return &calendar, nil
+}
+
+// BookingRepository implements repository.BookingRepository with PostgreSQL
+type BookingRepository struct {
+	db *PostgresDB
+}
+
+// NewBookingRepository creates a new PostgreSQL booking repository
+func NewBookingRepository(db *PostgresDB) *BookingRepository {
+	return &BookingRepository{db: db}
+}
+
+const bookingColumns = `
+	id, tenant_id, calendar_id, conversation_id, contact_id, title, status, offered_slots,
+	start_at, end_at, external_event_id, token, reminders_sent, next_reminder_at, cancel_reason,
+	created_by, cancelled_at, created_at, updated_at
+`
+
+// Create creates a new booking
+func (r *BookingRepository) Create(ctx context.Context, booking *entity.Booking) error {
+	offered, sent, err := marshalBooking(booking)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO bookings (` + bookingColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)`
+	_, err = r.db.Pool.Exec(ctx, query,
+		booking.ID,
+		booking.TenantID,
+		booking.CalendarID,
+		booking.ConversationID,
+		booking.ContactID,
+		booking.Title,
+		string(booking.Status),
+		offered,
+		booking.StartAt,
+		booking.EndAt,
+		booking.ExternalEventID,
+		booking.Token,
+		sent,
+		booking.NextReminderAt,
+		booking.CancelReason,
+		booking.CreatedBy,
+		booking.CancelledAt,
+		booking.CreatedAt,
+		booking.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create booking")
+	}
+	return nil
+}
+
+// FindByID finds a booking by ID
+func (r *BookingRepository) FindByID(ctx context.Context, id string) (*entity.Booking, error) {
+	query := `SELECT ` + bookingColumns + ` FROM bookings WHERE id = $1`
+	return r.scanBooking(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// FindByToken finds a booking by the token of its links
+func (r *BookingRepository) FindByToken(ctx context.Context, token string) (*entity.Booking, error) {
+	query := `SELECT ` + bookingColumns + ` FROM bookings WHERE token = $1`
+	return r.scanBooking(r.db.Pool.QueryRow(ctx, query, token))
+}
+
+// FindPendingByConversation finds the latest booking of a conversation still
+// waiting for the contact to pick a slot
+func (r *BookingRepository) FindPendingByConversation(ctx context.Context, conversationID string) (*entity.Booking, error) {
+	query := `
+		SELECT ` + bookingColumns + ` FROM bookings
+		WHERE conversation_id = $1 AND status = 'pending'
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+	return r.scanBooking(r.db.Pool.QueryRow(ctx, query, conversationID))
+}
+
+// ListByTenant lists the bookings of a tenant, soonest first
+func (r *BookingRepository) ListByTenant(ctx context.Context, tenantID string, filter *entity.BookingFilter, params *repository.ListParams) ([]*entity.Booking, int64, error) {
+	if params == nil {
+		params = repository.NewListParams()
+	}
+
+	conditions := "tenant_id = $1"
+	args := []interface{}{tenantID}
+	if filter != nil {
+		if filter.CalendarID != "" {
+			args = append(args, filter.CalendarID)
+			conditions += fmt.Sprintf(" AND calendar_id = $%d", len(args))
+		}
+		if filter.ConversationID != "" {
+			args = append(args, filter.ConversationID)
+			conditions += fmt.Sprintf(" AND conversation_id = $%d", len(args))
+		}
+		if filter.Status != "" {
+			args = append(args, string(filter.Status))
+			conditions += fmt.Sprintf(" AND status = $%d", len(args))
+		}
+		if filter.From != nil {
+			args = append(args, *filter.From)
+			conditions += fmt.Sprintf(" AND start_at >= $%d", len(args))
+		}
+		if filter.To != nil {
+			args = append(args, *filter.To)
+			conditions += fmt.Sprintf(" AND start_at < $%d", len(args))
+		}
+	}
+
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM bookings WHERE "+conditions, args...).Scan(&total); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count bookings")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM bookings
+		WHERE %s
+		ORDER BY start_at NULLS LAST, created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, bookingColumns, conditions, len(args)+1, len(args)+2)
+	args = append(args, params.Limit(), params.Offset())
+
+	bookings, err := r.queryBookings(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return bookings, total, nil
+}
+
+// ListConfirmedBetween lists the confirmed bookings of a calendar overlapping from..to
+func (r *BookingRepository) ListConfirmedBetween(ctx context.Context, calendarID string, from, to time.Time) ([]*entity.Booking, error) {
+	query := `
+		SELECT ` + bookingColumns + ` FROM bookings
+		WHERE calendar_id = $1 AND status = 'confirmed' AND start_at < $3 AND end_at > $2
+		ORDER BY start_at
+	`
+	return r.queryBookings(ctx, query, calendarID, from, to)
+}
+
+// ListDueReminders lists confirmed bookings whose next reminder is due at before
+func (r *BookingRepository) ListDueReminders(ctx context.Context, before time.Time, limit int) ([]*entity.Booking, error) {
+	query := `
+		SELECT ` + bookingColumns + ` FROM bookings
+		WHERE status = 'confirmed' AND next_reminder_at <= $1
+		ORDER BY next_reminder_at
+		LIMIT $2
+	`
+	return r.queryBookings(ctx, query, before, limit)
+}
+
+// Update updates a booking
+func (r *BookingRepository) Update(ctx context.Context, booking *entity.Booking) error {
+	offered, sent, err := marshalBooking(booking)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE bookings
+		SET title = $2, status = $3, offered_slots = $4, start_at = $5, end_at = $6, external_event_id = $7,
+		    reminders_sent = $8, next_reminder_at = $9, cancel_reason = $10, cancelled_at = $11, updated_at = $12
+		WHERE id = $1
+	`
+	tag, err := r.db.Pool.Exec(ctx, query,
+		booking.ID,
+		booking.Title,
+		string(booking.Status),
+		offered,
+		booking.StartAt,
+		booking.EndAt,
+		booking.ExternalEventID,
+		sent,
+		booking.NextReminderAt,
+		booking.CancelReason,
+		booking.CancelledAt,
+		booking.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update booking")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "booking not found")
+	}
+	return nil
+}
+
+func marshalBooking(booking *entity.Booking) ([]byte, []byte, error) {
+	offered, err := json.Marshal(booking.OfferedSlots)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal offered slots")
+	}
+	sent, err := json.Marshal(booking.RemindersSent)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal sent reminders")
+	}
+	return offered, sent, nil
+}
+
+func (r *BookingRepository) queryBookings(ctx context.Context, query string, args ...interface{}) ([]*entity.Booking, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list bookings")
+	}
+	defer rows.Close()
+
+	var bookings []*entity.Booking
+	for rows.Next() {
+		booking, err := r.scanBooking(rows)
+		if err != nil {
+			return nil, err
+		}
+		bookings = append(bookings, booking)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate bookings")
+	}
+	return bookings, nil
+}
+
+func (r *BookingRepository) scanBooking(row pgx.Row) (*entity.Booking, error) {
+	var booking entity.Booking
+	var status string
+	var offered, sent []byte
+
+	err := row.Scan(
+		&booking.ID,
+		&booking.TenantID,
+		&booking.CalendarID,
+		&booking.ConversationID,
+		&booking.ContactID,
+		&booking.Title,
+		&status,
+		&offered,
+		&booking.StartAt,
+		&booking.EndAt,
+		&booking.ExternalEventID,
+		&booking.Token,
+		&sent,
+		&booking.NextReminderAt,
+		&booking.CancelReason,
+		&booking.CreatedBy,
+		&booking.CancelledAt,
+		&booking.CreatedAt,
+		&booking.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "booking not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan booking")
+	}
+
+	booking.Status = entity.BookingStatus(status)
+	if len(offered) > 0 {
+		if err := json.Unmarshal(offered, &booking.OfferedSlots); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal offered slots")
+		}
+	}
+	if len(sent) > 0 {
+		if err := json.Unmarshal(sent, &booking.RemindersSent); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal sent reminders")
+		}
+	}
+	return &booking, nil
+}
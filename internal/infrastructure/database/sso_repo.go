@@ -0,0 +1,153 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// SSOConfigRepository implements repository.SSOConfigRepository with PostgreSQL
+type SSOConfigRepository struct {
+	db *PostgresDB
+}
+
+// NewSSOConfigRepository creates a new PostgreSQL SSO config repository
+func NewSSOConfigRepository(db *PostgresDB) *SSOConfigRepository {
+	return &SSOConfigRepository{db: db}
+}
+
+// FindByTenant finds the identity provider of a tenant
+func (r *SSOConfigRepository) FindByTenant(ctx context.Context, tenantID string) (*entity.SSOConfig, error) {
+	query := `
+		SELECT tenant_id, protocol, enabled, issuer, client_id, client_secret, scopes,
+		       idp_entity_id, idp_sso_url, idp_certificate, email_attribute, name_attribute,
+		       groups_attribute, jit_provisioning, default_role, group_roles, redirect_urls,
+		       saved_by_owner, created_at, updated_at
+		FROM tenant_sso_configs
+		WHERE tenant_id = $1
+	`
+
+	var config entity.SSOConfig
+	var protocol, defaultRole string
+	var groupRoles []byte
+	err := r.db.Pool.QueryRow(ctx, query, tenantID).Scan(
+		&config.TenantID,
+		&protocol,
+		&config.Enabled,
+		&config.Issuer,
+		&config.ClientID,
+		&config.ClientSecret,
+		&config.Scopes,
+		&config.IDPEntityID,
+		&config.IDPSSOURL,
+		&config.IDPCertificate,
+		&config.EmailAttribute,
+		&config.NameAttribute,
+		&config.GroupsAttribute,
+		&config.JITProvisioning,
+		&defaultRole,
+		&groupRoles,
+		&config.RedirectURLs,
+		&config.SavedByOwner,
+		&config.CreatedAt,
+		&config.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "SSO not configured")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find SSO config")
+	}
+	config.Protocol = entity.SSOProtocol(protocol)
+	config.DefaultRole = entity.UserRole(defaultRole)
+	if len(groupRoles) > 0 {
+		if err := json.Unmarshal(groupRoles, &config.GroupRoles); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal SSO group roles")
+		}
+	}
+	config.HasClientSecret = config.ClientSecret != ""
+	return &config, nil
+}
+
+// Save creates or replaces the identity provider of a tenant
+func (r *SSOConfigRepository) Save(ctx context.Context, config *entity.SSOConfig) error {
+	query := `
+		INSERT INTO tenant_sso_configs (
+			tenant_id, protocol, enabled, issuer, client_id, client_secret, scopes,
+			idp_entity_id, idp_sso_url, idp_certificate, email_attribute, name_attribute,
+			groups_attribute, jit_provisioning, default_role, group_roles, redirect_urls,
+			saved_by_owner, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			protocol = EXCLUDED.protocol,
+			enabled = EXCLUDED.enabled,
+			issuer = EXCLUDED.issuer,
+			client_id = EXCLUDED.client_id,
+			client_secret = EXCLUDED.client_secret,
+			scopes = EXCLUDED.scopes,
+			idp_entity_id = EXCLUDED.idp_entity_id,
+			idp_sso_url = EXCLUDED.idp_sso_url,
+			idp_certificate = EXCLUDED.idp_certificate,
+			email_attribute = EXCLUDED.email_attribute,
+			name_attribute = EXCLUDED.name_attribute,
+			groups_attribute = EXCLUDED.groups_attribute,
+			jit_provisioning = EXCLUDED.jit_provisioning,
+			default_role = EXCLUDED.default_role,
+			group_roles = EXCLUDED.group_roles,
+			redirect_urls = EXCLUDED.redirect_urls,
+			saved_by_owner = EXCLUDED.saved_by_owner,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	groupRoles, err := json.Marshal(config.GroupRoles)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal SSO group roles")
+	}
+	scopes := config.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	redirectURLs := config.RedirectURLs
+	if redirectURLs == nil {
+		redirectURLs = []string{}
+	}
+
+	_, err = r.db.Pool.Exec(ctx, query,
+		config.TenantID,
+		string(config.Protocol),
+		config.Enabled,
+		config.Issuer,
+		config.ClientID,
+		config.ClientSecret,
+		scopes,
+		config.IDPEntityID,
+		config.IDPSSOURL,
+		config.IDPCertificate,
+		config.EmailAttribute,
+		config.NameAttribute,
+		config.GroupsAttribute,
+		config.JITProvisioning,
+		string(config.DefaultRole),
+		groupRoles,
+		redirectURLs,
+		config.SavedByOwner,
+		config.CreatedAt,
+		config.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to save SSO config")
+	}
+	return nil
+}
+
+// Delete removes the identity provider of a tenant
+func (r *SSOConfigRepository) Delete(ctx context.Context, tenantID string) error {
+	_, err := r.db.Pool.Exec(ctx, `DELETE FROM tenant_sso_configs WHERE tenant_id = $1`, tenantID)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete SSO config")
+	}
+	return nil
+}
@@ -0,0 +1,309 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// StatusPageRepository implements repository.StatusPageRepository with PostgreSQL
+type StatusPageRepository struct {
+	db *PostgresDB
+}
+
+// NewStatusPageRepository creates a new PostgreSQL status page repository
+func NewStatusPageRepository(db *PostgresDB) *StatusPageRepository {
+	return &StatusPageRepository{db: db}
+}
+
+const statusPageColumns = `
+	id, tenant_id, slug, title, description, is_published, channel_ids, auto_incidents,
+	created_at, updated_at
+`
+
+const incidentColumns = `
+	id, tenant_id, kind, title, message, impact, status, channel_ids, updates,
+	starts_at, ends_at, resolved_at, auto_created, created_at, updated_at
+`
+
+// FindByTenant finds the status page of a tenant
+func (r *StatusPageRepository) FindByTenant(ctx context.Context, tenantID string) (*entity.StatusPage, error) {
+	query := `SELECT ` + statusPageColumns + ` FROM status_pages WHERE tenant_id = $1`
+	return r.scanPage(r.db.Pool.QueryRow(ctx, query, tenantID))
+}
+
+// FindBySlug finds a status page by its public slug
+func (r *StatusPageRepository) FindBySlug(ctx context.Context, slug string) (*entity.StatusPage, error) {
+	query := `SELECT ` + statusPageColumns + ` FROM status_pages WHERE slug = $1`
+	return r.scanPage(r.db.Pool.QueryRow(ctx, query, slug))
+}
+
+// Save creates or updates the status page of a tenant
+func (r *StatusPageRepository) Save(ctx context.Context, page *entity.StatusPage) error {
+	query := `
+		INSERT INTO status_pages (` + statusPageColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			slug = EXCLUDED.slug,
+			title = EXCLUDED.title,
+			description = EXCLUDED.description,
+			is_published = EXCLUDED.is_published,
+			channel_ids = EXCLUDED.channel_ids,
+			auto_incidents = EXCLUDED.auto_incidents,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	channelIDs := page.ChannelIDs
+	if channelIDs == nil {
+		channelIDs = []string{}
+	}
+
+	_, err := r.db.Pool.Exec(ctx, query,
+		page.ID,
+		page.TenantID,
+		page.Slug,
+		page.Title,
+		page.Description,
+		page.IsPublished,
+		channelIDs,
+		page.AutoIncidents,
+		page.CreatedAt,
+		page.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to save status page")
+	}
+	return nil
+}
+
+// ListAutoIncidentPages lists the published pages that open incidents from channel health
+func (r *StatusPageRepository) ListAutoIncidentPages(ctx context.Context) ([]*entity.StatusPage, error) {
+	query := `SELECT ` + statusPageColumns + ` FROM status_pages WHERE is_published = TRUE AND auto_incidents = TRUE`
+
+	rows, err := r.db.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list status pages")
+	}
+	defer rows.Close()
+
+	var pages []*entity.StatusPage
+	for rows.Next() {
+		page, err := r.scanPage(rows)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, page)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate status pages")
+	}
+	return pages, nil
+}
+
+// CreateIncident creates a new incident
+func (r *StatusPageRepository) CreateIncident(ctx context.Context, incident *entity.Incident) error {
+	updates, err := json.Marshal(incident.Updates)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal incident updates")
+	}
+
+	channelIDs := incident.ChannelIDs
+	if channelIDs == nil {
+		channelIDs = []string{}
+	}
+
+	query := `INSERT INTO incidents (` + incidentColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
+	_, err = r.db.Pool.Exec(ctx, query,
+		incident.ID,
+		incident.TenantID,
+		string(incident.Kind),
+		incident.Title,
+		incident.Message,
+		string(incident.Impact),
+		string(incident.Status),
+		channelIDs,
+		updates,
+		incident.StartsAt,
+		incident.EndsAt,
+		incident.ResolvedAt,
+		incident.AutoCreated,
+		incident.CreatedAt,
+		incident.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create incident")
+	}
+	return nil
+}
+
+// FindIncidentByID finds an incident by ID
+func (r *StatusPageRepository) FindIncidentByID(ctx context.Context, id string) (*entity.Incident, error) {
+	query := `SELECT ` + incidentColumns + ` FROM incidents WHERE id = $1`
+	return r.scanIncident(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// ListIncidents lists the incidents of a tenant, most recent first
+func (r *StatusPageRepository) ListIncidents(ctx context.Context, tenantID string, filter *entity.IncidentFilter) ([]*entity.Incident, error) {
+	conditions := "tenant_id = $1"
+	args := []interface{}{tenantID}
+	limit := 100
+
+	if filter != nil {
+		if filter.Kind != "" {
+			args = append(args, string(filter.Kind))
+			conditions += fmt.Sprintf(" AND kind = $%d", len(args))
+		}
+		if filter.Unresolved {
+			conditions += " AND status <> 'resolved'"
+		}
+		if filter.Since != nil {
+			args = append(args, *filter.Since)
+			conditions += fmt.Sprintf(" AND (starts_at >= $%d OR resolved_at >= $%d OR resolved_at IS NULL)", len(args), len(args))
+		}
+		if filter.Limit > 0 {
+			limit = filter.Limit
+		}
+	}
+
+	args = append(args, limit)
+	query := fmt.Sprintf(`SELECT %s FROM incidents WHERE %s ORDER BY starts_at DESC LIMIT $%d`, incidentColumns, conditions, len(args))
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list incidents")
+	}
+	defer rows.Close()
+
+	var incidents []*entity.Incident
+	for rows.Next() {
+		incident, err := r.scanIncident(rows)
+		if err != nil {
+			return nil, err
+		}
+		incidents = append(incidents, incident)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate incidents")
+	}
+	return incidents, nil
+}
+
+// UpdateIncident updates an incident
+func (r *StatusPageRepository) UpdateIncident(ctx context.Context, incident *entity.Incident) error {
+	updates, err := json.Marshal(incident.Updates)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal incident updates")
+	}
+
+	channelIDs := incident.ChannelIDs
+	if channelIDs == nil {
+		channelIDs = []string{}
+	}
+
+	query := `
+		UPDATE incidents
+		SET title = $2, message = $3, impact = $4, status = $5, channel_ids = $6, updates = $7,
+		    starts_at = $8, ends_at = $9, resolved_at = $10, updated_at = $11
+		WHERE id = $1
+	`
+	result, err := r.db.Pool.Exec(ctx, query,
+		incident.ID,
+		incident.Title,
+		incident.Message,
+		string(incident.Impact),
+		string(incident.Status),
+		channelIDs,
+		updates,
+		incident.StartsAt,
+		incident.EndsAt,
+		incident.ResolvedAt,
+		incident.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update incident")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "incident not found")
+	}
+	return nil
+}
+
+// DeleteIncident deletes an incident
+func (r *StatusPageRepository) DeleteIncident(ctx context.Context, id string) error {
+	result, err := r.db.Pool.Exec(ctx, `DELETE FROM incidents WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete incident")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "incident not found")
+	}
+	return nil
+}
+
+func (r *StatusPageRepository) scanPage(row pgx.Row) (*entity.StatusPage, error) {
+	var page entity.StatusPage
+
+	err := row.Scan(
+		&page.ID,
+		&page.TenantID,
+		&page.Slug,
+		&page.Title,
+		&page.Description,
+		&page.IsPublished,
+		&page.ChannelIDs,
+		&page.AutoIncidents,
+		&page.CreatedAt,
+		&page.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "status page not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan status page")
+	}
+	return &page, nil
+}
+
+func (r *StatusPageRepository) scanIncident(row pgx.Row) (*entity.Incident, error) {
+	var incident entity.Incident
+	var kind, impact, status string
+	var updates []byte
+
+	err := row.Scan(
+		&incident.ID,
+		&incident.TenantID,
+		&kind,
+		&incident.Title,
+		&incident.Message,
+		&impact,
+		&status,
+		&incident.ChannelIDs,
+		&updates,
+		&incident.StartsAt,
+		&incident.EndsAt,
+		&incident.ResolvedAt,
+		&incident.AutoCreated,
+		&incident.CreatedAt,
+		&incident.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "incident not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan incident")
+	}
+
+	incident.Kind = entity.IncidentKind(kind)
+	incident.Impact = entity.IncidentImpact(impact)
+	incident.Status = entity.IncidentStatus(status)
+	if len(updates) > 0 {
+		if err := json.Unmarshal(updates, &incident.Updates); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal incident updates")
+		}
+	}
+	return &incident, nil
+}
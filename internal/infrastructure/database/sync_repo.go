@@ -0,0 +1,134 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/infrastructure/encryption"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// SyncRepository implements repository.SyncRepository with PostgreSQL. Changes are read
+// in (updated_at, id) order so a position resumes exactly after the last record sent.
+type SyncRepository struct {
+	db     *PostgresDB
+	cipher encryption.Cipher
+}
+
+// NewSyncRepository creates a new PostgreSQL sync repository
+func NewSyncRepository(db *PostgresDB) *SyncRepository {
+	return &SyncRepository{db: db}
+}
+
+// SetCipher opens message content sealed for tenants that turned encryption on
+func (r *SyncRepository) SetCipher(cipher encryption.Cipher) {
+	r.cipher = cipher
+}
+
+// ConversationsChangedSince returns up to limit conversations changed after since and
+// no later than until
+func (r *SyncRepository) ConversationsChangedSince(ctx context.Context, tenantID string, since entity.SyncPosition, until time.Time, limit int) ([]*entity.SyncConversation, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT c.id, c.channel_id, c.contact_id, COALESCE(ct.name, ''), c.assignee_id, c.status, c.priority,
+		       c.title, c.unread_count, c.updated_at,
+		       (SELECT MAX(m.created_at) FROM messages m WHERE m.conversation_id = c.id)
+		FROM conversations c
+		LEFT JOIN contacts ct ON ct.id = c.contact_id
+		WHERE c.tenant_id = $1
+		  AND (c.updated_at, c.id::text) > ($2, $3)
+		  AND c.updated_at <= $4
+		ORDER BY c.updated_at, c.id::text
+		LIMIT $5
+	`, tenantID, since.At, since.ID, until, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to query changed conversations")
+	}
+	defer rows.Close()
+
+	conversations := make([]*entity.SyncConversation, 0)
+	for rows.Next() {
+		var c entity.SyncConversation
+		var assigneeID *string
+		var status, priority string
+		if err := rows.Scan(
+			&c.ID, &c.ChannelID, &c.ContactID, &c.ContactName, &assigneeID, &status, &priority,
+			&c.Title, &c.Unread, &c.UpdatedAt, &c.LastMessageAt,
+		); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan changed conversation")
+		}
+		c.Status = entity.ConversationStatus(status)
+		if priority != string(entity.ConversationPriorityNormal) {
+			c.Priority = entity.ConversationPriority(priority)
+		}
+		if assigneeID != nil {
+			c.AssigneeID = *assigneeID
+		}
+		conversations = append(conversations, &c)
+	}
+	return conversations, rows.Err()
+}
+
+// MessagesChangedSince returns up to limit messages created or changed after since and
+// no later than until
+func (r *SyncRepository) MessagesChangedSince(ctx context.Context, tenantID string, since entity.SyncPosition, until time.Time, limit int) ([]*entity.SyncMessage, error) {
+	rows, err := r.db.Pool.Query(ctx, `
+		SELECT m.id, m.conversation_id, m.sender_type, m.sender_id, m.content_type, m.content, m.status,
+		       (SELECT COUNT(*) FROM message_attachments a WHERE a.message_id = m.id),
+		       m.created_at, m.updated_at
+		FROM messages m
+		JOIN conversations c ON c.id = m.conversation_id
+		WHERE c.tenant_id = $1
+		  AND (m.updated_at, m.id::text) > ($2, $3)
+		  AND m.updated_at <= $4
+		ORDER BY m.updated_at, m.id::text
+		LIMIT $5
+	`, tenantID, since.At, since.ID, until, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to query changed messages")
+	}
+	defer rows.Close()
+
+	messages := make([]*entity.SyncMessage, 0)
+	for rows.Next() {
+		var m entity.SyncMessage
+		var senderID, content *string
+		var senderType, contentType, status string
+		if err := rows.Scan(
+			&m.ID, &m.ConversationID, &senderType, &senderID, &contentType, &content, &status,
+			&m.Attachments, &m.CreatedAt, &m.UpdatedAt,
+		); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan changed message")
+		}
+		m.SenderType = entity.SenderType(senderType)
+		if contentType != string(entity.ContentTypeText) {
+			m.ContentType = entity.ContentType(contentType)
+		}
+		m.Status = entity.MessageStatus(status)
+		if senderID != nil {
+			m.SenderID = *senderID
+		}
+		if content != nil {
+			m.Content = *content
+		}
+		messages = append(messages, &m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to read changed messages")
+	}
+
+	// Decrypt after the rows are released; the cipher may query key material
+	if r.cipher != nil {
+		for _, m := range messages {
+			if !encryption.IsEncrypted(m.Content) {
+				continue
+			}
+			opened, err := r.cipher.Decrypt(ctx, m.Content)
+			if err != nil {
+				return nil, err
+			}
+			m.Content = opened
+		}
+	}
+	return messages, nil
+}
@@ -0,0 +1,218 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// UploadRepository implements repository.UploadRepository with PostgreSQL
+type UploadRepository struct {
+	db *PostgresDB
+}
+
+// NewUploadRepository creates a new PostgreSQL upload repository
+func NewUploadRepository(db *PostgresDB) *UploadRepository {
+	return &UploadRepository{db: db}
+}
+
+const uploadColumns = `
+	id, tenant_id, created_by, filename, mime_type, purpose, channel_type, size, upload_offset,
+	chunks, checksum, status, scan_status, scan_result, transcoded, storage_key, url,
+	result_size, result_mime_type, job_id, error, encryption_key, expires_at, completed_at,
+	created_at, updated_at
+`
+
+func scanUpload(row pgx.Row) (*entity.Upload, error) {
+	var upload entity.Upload
+	err := row.Scan(
+		&upload.ID,
+		&upload.TenantID,
+		&upload.CreatedBy,
+		&upload.Filename,
+		&upload.MimeType,
+		&upload.Purpose,
+		&upload.ChannelType,
+		&upload.Size,
+		&upload.Offset,
+		&upload.Chunks,
+		&upload.Checksum,
+		&upload.Status,
+		&upload.ScanStatus,
+		&upload.ScanResult,
+		&upload.Transcoded,
+		&upload.StorageKey,
+		&upload.URL,
+		&upload.ResultSize,
+		&upload.ResultMime,
+		&upload.JobID,
+		&upload.Error,
+		&upload.EncryptionKey,
+		&upload.ExpiresAt,
+		&upload.CompletedAt,
+		&upload.CreatedAt,
+		&upload.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
+
+// Create creates an upload
+func (r *UploadRepository) Create(ctx context.Context, upload *entity.Upload) error {
+	query := `
+		INSERT INTO uploads (` + uploadColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17,
+			$18, $19, $20, $21, $22, $23, $24, $25, $26)
+	`
+	if upload.Chunks == nil {
+		upload.Chunks = []int64{}
+	}
+
+	_, err := r.db.Pool.Exec(ctx, query,
+		upload.ID,
+		upload.TenantID,
+		upload.CreatedBy,
+		upload.Filename,
+		upload.MimeType,
+		upload.Purpose,
+		upload.ChannelType,
+		upload.Size,
+		upload.Offset,
+		upload.Chunks,
+		upload.Checksum,
+		upload.Status,
+		upload.ScanStatus,
+		upload.ScanResult,
+		upload.Transcoded,
+		upload.StorageKey,
+		upload.URL,
+		upload.ResultSize,
+		upload.ResultMime,
+		upload.JobID,
+		upload.Error,
+		upload.EncryptionKey,
+		upload.ExpiresAt,
+		upload.CompletedAt,
+		upload.CreatedAt,
+		upload.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create upload")
+	}
+	return nil
+}
+
+// FindByID finds an upload by ID
+func (r *UploadRepository) FindByID(ctx context.Context, id string) (*entity.Upload, error) {
+	query := `SELECT ` + uploadColumns + ` FROM uploads WHERE id = $1`
+
+	upload, err := scanUpload(r.db.Pool.QueryRow(ctx, query, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "upload not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find upload")
+	}
+	return upload, nil
+}
+
+// Update updates an upload
+func (r *UploadRepository) Update(ctx context.Context, upload *entity.Upload) error {
+	query := `
+		UPDATE uploads SET
+			mime_type = $2,
+			status = $3,
+			scan_status = $4,
+			scan_result = $5,
+			transcoded = $6,
+			storage_key = $7,
+			url = $8,
+			result_size = $9,
+			result_mime_type = $10,
+			job_id = $11,
+			error = $12,
+			completed_at = $13,
+			updated_at = $14
+		WHERE id = $1
+	`
+
+	tag, err := r.db.Pool.Exec(ctx, query,
+		upload.ID,
+		upload.MimeType,
+		upload.Status,
+		upload.ScanStatus,
+		upload.ScanResult,
+		upload.Transcoded,
+		upload.StorageKey,
+		upload.URL,
+		upload.ResultSize,
+		upload.ResultMime,
+		upload.JobID,
+		upload.Error,
+		upload.CompletedAt,
+		upload.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update upload")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "upload not found")
+	}
+	return nil
+}
+
+// AppendChunk records a staged chunk and advances the offset, provided the upload is
+// still at the chunk's offset. It returns false when another chunk got there first.
+func (r *UploadRepository) AppendChunk(ctx context.Context, id string, offset, length int64) (bool, error) {
+	tag, err := r.db.Pool.Exec(ctx, `
+		UPDATE uploads SET
+			upload_offset = upload_offset + $3,
+			chunks = array_append(chunks, $2::BIGINT),
+			updated_at = NOW()
+		WHERE id = $1 AND upload_offset = $2 AND status = $4
+	`, id, offset, length, entity.UploadStatusUploading)
+	if err != nil {
+		return false, errors.Wrap(err, errors.ErrCodeInternal, "failed to record upload chunk")
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ListExpired lists unfinished uploads that expired before a time
+func (r *UploadRepository) ListExpired(ctx context.Context, before time.Time, limit int) ([]*entity.Upload, error) {
+	query := `
+		SELECT ` + uploadColumns + ` FROM uploads
+		WHERE status = $1 AND expires_at < $2
+		ORDER BY expires_at
+		LIMIT $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, entity.UploadStatusUploading, before, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list expired uploads")
+	}
+	defer rows.Close()
+
+	var uploads []*entity.Upload
+	for rows.Next() {
+		upload, err := scanUpload(rows)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan upload")
+		}
+		uploads = append(uploads, upload)
+	}
+	return uploads, rows.Err()
+}
+
+// Delete deletes an upload
+func (r *UploadRepository) Delete(ctx context.Context, id string) error {
+	_, err := r.db.Pool.Exec(ctx, `DELETE FROM uploads WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete upload")
+	}
+	return nil
+}
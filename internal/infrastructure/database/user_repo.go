@@ -71,13 +71,16 @@ func (r *UserRepository) FindByID(ctx context.Context, id string) (*entity.User,
 	return user, nil
 }
 
-// FindByEmail finds a user by email (across all tenants)
+// FindByEmail finds a user by email (across all tenants). An email with accounts in
+// several workspaces resolves to the account used most recently.
 func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
 	query := `
 		SELECT id, tenant_id, email, password_hash, name, role, avatar_url,
 		       status, last_login_at, created_at, updated_at
 		FROM users
 		WHERE email = $1
+		ORDER BY last_login_at DESC NULLS LAST, created_at
+		LIMIT 1
 	`
 
 	user, err := r.scanUser(r.db.Pool.QueryRow(ctx, query, email))
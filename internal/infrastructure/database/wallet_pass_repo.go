@@ -0,0 +1,416 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// WalletPassTemplateRepository implements repository.WalletPassTemplateRepository with PostgreSQL
+type WalletPassTemplateRepository struct {
+	db *PostgresDB
+}
+
+// NewWalletPassTemplateRepository creates a new PostgreSQL wallet pass template repository
+func NewWalletPassTemplateRepository(db *PostgresDB) *WalletPassTemplateRepository {
+	return &WalletPassTemplateRepository{db: db}
+}
+
+const walletPassTemplateColumns = `
+	id, tenant_id, name, kind, object_type, organization_name, description, logo_text, logo_url,
+	background_color, foreground_color, label_color, fields, barcode_format, barcode_message,
+	date_attribute, void_statuses, is_active, created_at, updated_at
+`
+
+// Create creates a new template
+func (r *WalletPassTemplateRepository) Create(ctx context.Context, template *entity.WalletPassTemplate) error {
+	fields, err := json.Marshal(template.Fields)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal fields")
+	}
+
+	query := `INSERT INTO wallet_pass_templates (` + walletPassTemplateColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)`
+	_, err = r.db.Pool.Exec(ctx, query,
+		template.ID,
+		template.TenantID,
+		template.Name,
+		string(template.Kind),
+		string(template.ObjectType),
+		template.OrganizationName,
+		template.Description,
+		template.LogoText,
+		template.LogoURL,
+		template.BackgroundColor,
+		template.ForegroundColor,
+		template.LabelColor,
+		fields,
+		template.BarcodeFormat,
+		template.BarcodeMessage,
+		template.DateAttribute,
+		template.VoidStatuses,
+		template.IsActive,
+		template.CreatedAt,
+		template.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create wallet pass template")
+	}
+	return nil
+}
+
+// FindByID finds a template by ID
+func (r *WalletPassTemplateRepository) FindByID(ctx context.Context, id string) (*entity.WalletPassTemplate, error) {
+	query := `SELECT ` + walletPassTemplateColumns + ` FROM wallet_pass_templates WHERE id = $1`
+	return r.scanTemplate(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// FindByTenant lists the templates of a tenant ordered by name
+func (r *WalletPassTemplateRepository) FindByTenant(ctx context.Context, tenantID string) ([]*entity.WalletPassTemplate, error) {
+	query := `SELECT ` + walletPassTemplateColumns + ` FROM wallet_pass_templates WHERE tenant_id = $1 ORDER BY name`
+	rows, err := r.db.Pool.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list wallet pass templates")
+	}
+	defer rows.Close()
+
+	var templates []*entity.WalletPassTemplate
+	for rows.Next() {
+		template, err := r.scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate wallet pass templates")
+	}
+	return templates, nil
+}
+
+// Update updates a template. Its kind and object type cannot change.
+func (r *WalletPassTemplateRepository) Update(ctx context.Context, template *entity.WalletPassTemplate) error {
+	fields, err := json.Marshal(template.Fields)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal fields")
+	}
+
+	query := `
+		UPDATE wallet_pass_templates
+		SET name = $2, organization_name = $3, description = $4, logo_text = $5, logo_url = $6,
+		    background_color = $7, foreground_color = $8, label_color = $9, fields = $10,
+		    barcode_format = $11, barcode_message = $12, date_attribute = $13, void_statuses = $14,
+		    is_active = $15, updated_at = $16
+		WHERE id = $1
+	`
+	tag, err := r.db.Pool.Exec(ctx, query,
+		template.ID,
+		template.Name,
+		template.OrganizationName,
+		template.Description,
+		template.LogoText,
+		template.LogoURL,
+		template.BackgroundColor,
+		template.ForegroundColor,
+		template.LabelColor,
+		fields,
+		template.BarcodeFormat,
+		template.BarcodeMessage,
+		template.DateAttribute,
+		template.VoidStatuses,
+		template.IsActive,
+		template.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update wallet pass template")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "wallet pass template not found")
+	}
+	return nil
+}
+
+// Delete deletes a template and the passes it issued
+func (r *WalletPassTemplateRepository) Delete(ctx context.Context, id string) error {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM wallet_pass_templates WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete wallet pass template")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "wallet pass template not found")
+	}
+	return nil
+}
+
+func (r *WalletPassTemplateRepository) scanTemplate(row pgx.Row) (*entity.WalletPassTemplate, error) {
+	var template entity.WalletPassTemplate
+	var kind, objectType string
+	var fields []byte
+
+	err := row.Scan(
+		&template.ID,
+		&template.TenantID,
+		&template.Name,
+		&kind,
+		&objectType,
+		&template.OrganizationName,
+		&template.Description,
+		&template.LogoText,
+		&template.LogoURL,
+		&template.BackgroundColor,
+		&template.ForegroundColor,
+		&template.LabelColor,
+		&fields,
+		&template.BarcodeFormat,
+		&template.BarcodeMessage,
+		&template.DateAttribute,
+		&template.VoidStatuses,
+		&template.IsActive,
+		&template.CreatedAt,
+		&template.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "wallet pass template not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan wallet pass template")
+	}
+
+	template.Kind = entity.WalletPassKind(kind)
+	template.ObjectType = entity.LinkedObjectType(objectType)
+	if len(fields) > 0 {
+		if err := json.Unmarshal(fields, &template.Fields); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal fields")
+		}
+	}
+	return &template, nil
+}
+
+// WalletPassRepository implements repository.WalletPassRepository with PostgreSQL
+type WalletPassRepository struct {
+	db *PostgresDB
+}
+
+// NewWalletPassRepository creates a new PostgreSQL wallet pass repository
+func NewWalletPassRepository(db *PostgresDB) *WalletPassRepository {
+	return &WalletPassRepository{db: db}
+}
+
+const walletPassColumns = `
+	id, tenant_id, template_id, linked_object_id, contact_id, auth_token, barcode_payload,
+	fields, relevant_date, voided, created_at, updated_at
+`
+
+// Create creates a new pass
+func (r *WalletPassRepository) Create(ctx context.Context, pass *entity.WalletPass) error {
+	fields, err := json.Marshal(pass.Fields)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal fields")
+	}
+
+	query := `INSERT INTO wallet_passes (` + walletPassColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
+	_, err = r.db.Pool.Exec(ctx, query,
+		pass.ID,
+		pass.TenantID,
+		pass.TemplateID,
+		nullString(pass.LinkedObjectID),
+		nullString(pass.ContactID),
+		pass.AuthToken,
+		pass.BarcodePayload,
+		fields,
+		pass.RelevantDate,
+		pass.Voided,
+		pass.CreatedAt,
+		pass.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create wallet pass")
+	}
+	return nil
+}
+
+// FindByID finds a pass by ID, which is also its serial number
+func (r *WalletPassRepository) FindByID(ctx context.Context, id string) (*entity.WalletPass, error) {
+	query := `SELECT ` + walletPassColumns + ` FROM wallet_passes WHERE id = $1`
+	return r.scanPass(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// FindByLinkedObject lists the passes issued for a linked object
+func (r *WalletPassRepository) FindByLinkedObject(ctx context.Context, linkedObjectID string) ([]*entity.WalletPass, error) {
+	query := `SELECT ` + walletPassColumns + ` FROM wallet_passes WHERE linked_object_id = $1 ORDER BY created_at`
+	return r.queryPasses(ctx, query, linkedObjectID)
+}
+
+// FindByContact lists the passes issued to a contact, newest first
+func (r *WalletPassRepository) FindByContact(ctx context.Context, contactID string) ([]*entity.WalletPass, error) {
+	query := `SELECT ` + walletPassColumns + ` FROM wallet_passes WHERE contact_id = $1 ORDER BY created_at DESC`
+	return r.queryPasses(ctx, query, contactID)
+}
+
+// Update updates a pass
+func (r *WalletPassRepository) Update(ctx context.Context, pass *entity.WalletPass) error {
+	fields, err := json.Marshal(pass.Fields)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal fields")
+	}
+
+	query := `
+		UPDATE wallet_passes
+		SET contact_id = $2, barcode_payload = $3, fields = $4, relevant_date = $5, voided = $6, updated_at = $7
+		WHERE id = $1
+	`
+	tag, err := r.db.Pool.Exec(ctx, query,
+		pass.ID,
+		nullString(pass.ContactID),
+		pass.BarcodePayload,
+		fields,
+		pass.RelevantDate,
+		pass.Voided,
+		pass.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update wallet pass")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "wallet pass not found")
+	}
+	return nil
+}
+
+// SaveRegistration registers a device for the updates of a pass, replacing its push
+// token when already registered. It reports whether the registration is new.
+func (r *WalletPassRepository) SaveRegistration(ctx context.Context, registration *entity.WalletPassRegistration) (bool, error) {
+	query := `
+		INSERT INTO wallet_pass_registrations (pass_id, device_library_id, push_token, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (pass_id, device_library_id) DO UPDATE SET push_token = EXCLUDED.push_token
+		RETURNING (xmax = 0)
+	`
+	var created bool
+	err := r.db.Pool.QueryRow(ctx, query,
+		registration.PassID,
+		registration.DeviceLibraryID,
+		registration.PushToken,
+		registration.CreatedAt,
+	).Scan(&created)
+	if err != nil {
+		return false, errors.Wrap(err, errors.ErrCodeInternal, "failed to save wallet pass registration")
+	}
+	return created, nil
+}
+
+// DeleteRegistration unregisters a device from the updates of a pass
+func (r *WalletPassRepository) DeleteRegistration(ctx context.Context, passID, deviceLibraryID string) error {
+	query := `DELETE FROM wallet_pass_registrations WHERE pass_id = $1 AND device_library_id = $2`
+	if _, err := r.db.Pool.Exec(ctx, query, passID, deviceLibraryID); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete wallet pass registration")
+	}
+	return nil
+}
+
+// FindRegistrations lists the devices registered for the updates of a pass
+func (r *WalletPassRepository) FindRegistrations(ctx context.Context, passID string) ([]*entity.WalletPassRegistration, error) {
+	query := `
+		SELECT pass_id, device_library_id, push_token, created_at
+		FROM wallet_pass_registrations
+		WHERE pass_id = $1
+	`
+	rows, err := r.db.Pool.Query(ctx, query, passID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list wallet pass registrations")
+	}
+	defer rows.Close()
+
+	var registrations []*entity.WalletPassRegistration
+	for rows.Next() {
+		var registration entity.WalletPassRegistration
+		if err := rows.Scan(&registration.PassID, &registration.DeviceLibraryID, &registration.PushToken, &registration.CreatedAt); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan wallet pass registration")
+		}
+		registrations = append(registrations, &registration)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate wallet pass registrations")
+	}
+	return registrations, nil
+}
+
+// FindByDevice lists the passes a device is registered for that changed after since;
+// a zero since lists them all
+func (r *WalletPassRepository) FindByDevice(ctx context.Context, deviceLibraryID string, since time.Time) ([]*entity.WalletPass, error) {
+	query := `
+		SELECT p.id, p.tenant_id, p.template_id, p.linked_object_id, p.contact_id, p.auth_token,
+		       p.barcode_payload, p.fields, p.relevant_date, p.voided, p.created_at, p.updated_at
+		FROM wallet_passes p
+		JOIN wallet_pass_registrations r ON r.pass_id = p.id
+		WHERE r.device_library_id = $1 AND p.updated_at > $2
+		ORDER BY p.updated_at
+	`
+	return r.queryPasses(ctx, query, deviceLibraryID, since)
+}
+
+func (r *WalletPassRepository) queryPasses(ctx context.Context, query string, args ...interface{}) ([]*entity.WalletPass, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list wallet passes")
+	}
+	defer rows.Close()
+
+	var passes []*entity.WalletPass
+	for rows.Next() {
+		pass, err := r.scanPass(rows)
+		if err != nil {
+			return nil, err
+		}
+		passes = append(passes, pass)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate wallet passes")
+	}
+	return passes, nil
+}
+
+func (r *WalletPassRepository) scanPass(row pgx.Row) (*entity.WalletPass, error) {
+	var pass entity.WalletPass
+	var linkedObjectID, contactID *string
+	var fields []byte
+
+	err := row.Scan(
+		&pass.ID,
+		&pass.TenantID,
+		&pass.TemplateID,
+		&linkedObjectID,
+		&contactID,
+		&pass.AuthToken,
+		&pass.BarcodePayload,
+		&fields,
+		&pass.RelevantDate,
+		&pass.Voided,
+		&pass.CreatedAt,
+		&pass.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "wallet pass not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan wallet pass")
+	}
+
+	if linkedObjectID != nil {
+		pass.LinkedObjectID = *linkedObjectID
+	}
+	if contactID != nil {
+		pass.ContactID = *contactID
+	}
+	if len(fields) > 0 {
+		if err := json.Unmarshal(fields, &pass.Fields); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal fields")
+		}
+	}
+	return &pass, nil
+}
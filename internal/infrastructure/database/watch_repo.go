@@ -0,0 +1,149 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// WatchRepository implements repository.WatchRepository with PostgreSQL
+type WatchRepository struct {
+	db *PostgresDB
+}
+
+// NewWatchRepository creates a new PostgreSQL watch repository
+func NewWatchRepository(db *PostgresDB) *WatchRepository {
+	return &WatchRepository{db: db}
+}
+
+const watchColumns = `id, tenant_id, user_id, target_type, target_id, created_by, created_at`
+
+// Create creates a watch, keeping the existing one if the user already watches the target
+func (r *WatchRepository) Create(ctx context.Context, watch *entity.Watch) error {
+	query := `
+		INSERT INTO watches (` + watchColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id, target_type, target_id) DO UPDATE SET user_id = EXCLUDED.user_id
+		RETURNING id, created_by, created_at
+	`
+	err := r.db.Pool.QueryRow(ctx, query,
+		watch.ID,
+		watch.TenantID,
+		watch.UserID,
+		string(watch.TargetType),
+		watch.TargetID,
+		watch.CreatedBy,
+		watch.CreatedAt,
+	).Scan(&watch.ID, &watch.CreatedBy, &watch.CreatedAt)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create watch")
+	}
+	return nil
+}
+
+// Delete removes the watch of a user on a target
+func (r *WatchRepository) Delete(ctx context.Context, tenantID, userID string, targetType entity.WatchTargetType, targetID string) error {
+	query := `DELETE FROM watches WHERE tenant_id = $1 AND user_id = $2 AND target_type = $3 AND target_id = $4`
+	tag, err := r.db.Pool.Exec(ctx, query, tenantID, userID, string(targetType), targetID)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete watch")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "watch not found")
+	}
+	return nil
+}
+
+// ListByUser lists the watches of a user, newest first
+func (r *WatchRepository) ListByUser(ctx context.Context, tenantID, userID string) ([]*entity.Watch, error) {
+	query := `
+		SELECT ` + watchColumns + ` FROM watches
+		WHERE tenant_id = $1 AND user_id = $2
+		ORDER BY created_at DESC
+	`
+	return r.queryWatches(ctx, query, tenantID, userID)
+}
+
+// ListByTarget lists the watches on a conversation or contact
+func (r *WatchRepository) ListByTarget(ctx context.Context, tenantID string, targetType entity.WatchTargetType, targetID string) ([]*entity.Watch, error) {
+	query := `
+		SELECT ` + watchColumns + ` FROM watches
+		WHERE tenant_id = $1 AND target_type = $2 AND target_id = $3
+		ORDER BY created_at
+	`
+	return r.queryWatches(ctx, query, tenantID, string(targetType), targetID)
+}
+
+// ListWatcherIDs lists the users watching a conversation directly or through its contact
+func (r *WatchRepository) ListWatcherIDs(ctx context.Context, tenantID, conversationID, contactID string) ([]string, error) {
+	query := `
+		SELECT DISTINCT user_id FROM watches
+		WHERE tenant_id = $1
+		  AND ((target_type = 'conversation' AND target_id = $2) OR (target_type = 'contact' AND target_id = $3))
+	`
+	rows, err := r.db.Pool.Query(ctx, query, tenantID, conversationID, contactID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list watchers")
+	}
+	defer rows.Close()
+
+	var userIDs []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan watcher")
+		}
+		userIDs = append(userIDs, userID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate watchers")
+	}
+	return userIDs, nil
+}
+
+func (r *WatchRepository) queryWatches(ctx context.Context, query string, args ...interface{}) ([]*entity.Watch, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list watches")
+	}
+	defer rows.Close()
+
+	var watches []*entity.Watch
+	for rows.Next() {
+		watch, err := r.scanWatch(rows)
+		if err != nil {
+			return nil, err
+		}
+		watches = append(watches, watch)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate watches")
+	}
+	return watches, nil
+}
+
+func (r *WatchRepository) scanWatch(row pgx.Row) (*entity.Watch, error) {
+	var watch entity.Watch
+	var targetType string
+
+	err := row.Scan(
+		&watch.ID,
+		&watch.TenantID,
+		&watch.UserID,
+		&targetType,
+		&watch.TargetID,
+		&watch.CreatedBy,
+		&watch.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "watch not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan watch")
+	}
+
+	watch.TargetType = entity.WatchTargetType(targetType)
+	return &watch, nil
+}
@@ -0,0 +1,312 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// WebhookSubscriptionRepository implements repository.WebhookSubscriptionRepository with PostgreSQL
+type WebhookSubscriptionRepository struct {
+	db *PostgresDB
+}
+
+// NewWebhookSubscriptionRepository creates a new PostgreSQL webhook subscription repository
+func NewWebhookSubscriptionRepository(db *PostgresDB) *WebhookSubscriptionRepository {
+	return &WebhookSubscriptionRepository{db: db}
+}
+
+const webhookSubscriptionColumns = `
+	id, tenant_id, name, url, events, schema_version, secret, is_active, created_at, updated_at
+`
+
+// Create creates a new subscription
+func (r *WebhookSubscriptionRepository) Create(ctx context.Context, subscription *entity.WebhookSubscription) error {
+	query := `INSERT INTO webhook_subscriptions (` + webhookSubscriptionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+	_, err := r.db.Pool.Exec(ctx, query,
+		subscription.ID,
+		subscription.TenantID,
+		subscription.Name,
+		subscription.URL,
+		subscription.Events,
+		subscription.SchemaVersion,
+		subscription.Secret,
+		subscription.IsActive,
+		subscription.CreatedAt,
+		subscription.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create webhook subscription")
+	}
+	return nil
+}
+
+// FindByID finds a subscription by ID
+func (r *WebhookSubscriptionRepository) FindByID(ctx context.Context, id string) (*entity.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE id = $1`
+	return r.scanSubscription(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// FindByTenant lists the subscriptions of a tenant ordered by name
+func (r *WebhookSubscriptionRepository) FindByTenant(ctx context.Context, tenantID string) ([]*entity.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE tenant_id = $1 ORDER BY name`
+	return r.querySubscriptions(ctx, query, tenantID)
+}
+
+// FindActiveByTenant lists the active subscriptions of a tenant
+func (r *WebhookSubscriptionRepository) FindActiveByTenant(ctx context.Context, tenantID string) ([]*entity.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE tenant_id = $1 AND is_active = true`
+	return r.querySubscriptions(ctx, query, tenantID)
+}
+
+// Update updates a subscription
+func (r *WebhookSubscriptionRepository) Update(ctx context.Context, subscription *entity.WebhookSubscription) error {
+	query := `
+		UPDATE webhook_subscriptions
+		SET name = $2, url = $3, events = $4, schema_version = $5, secret = $6, is_active = $7, updated_at = $8
+		WHERE id = $1
+	`
+	tag, err := r.db.Pool.Exec(ctx, query,
+		subscription.ID,
+		subscription.Name,
+		subscription.URL,
+		subscription.Events,
+		subscription.SchemaVersion,
+		subscription.Secret,
+		subscription.IsActive,
+		subscription.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update webhook subscription")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "webhook subscription not found")
+	}
+	return nil
+}
+
+// Delete deletes a subscription and its delivery log
+func (r *WebhookSubscriptionRepository) Delete(ctx context.Context, id string) error {
+	tag, err := r.db.Pool.Exec(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete webhook subscription")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "webhook subscription not found")
+	}
+	return nil
+}
+
+func (r *WebhookSubscriptionRepository) querySubscriptions(ctx context.Context, query string, args ...interface{}) ([]*entity.WebhookSubscription, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list webhook subscriptions")
+	}
+	defer rows.Close()
+
+	var subscriptions []*entity.WebhookSubscription
+	for rows.Next() {
+		subscription, err := r.scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate webhook subscriptions")
+	}
+	return subscriptions, nil
+}
+
+func (r *WebhookSubscriptionRepository) scanSubscription(row pgx.Row) (*entity.WebhookSubscription, error) {
+	var subscription entity.WebhookSubscription
+	err := row.Scan(
+		&subscription.ID,
+		&subscription.TenantID,
+		&subscription.Name,
+		&subscription.URL,
+		&subscription.Events,
+		&subscription.SchemaVersion,
+		&subscription.Secret,
+		&subscription.IsActive,
+		&subscription.CreatedAt,
+		&subscription.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "webhook subscription not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan webhook subscription")
+	}
+	return &subscription, nil
+}
+
+// WebhookDeliveryRepository implements repository.WebhookDeliveryRepository with PostgreSQL
+type WebhookDeliveryRepository struct {
+	db *PostgresDB
+}
+
+// NewWebhookDeliveryRepository creates a new PostgreSQL webhook delivery repository
+func NewWebhookDeliveryRepository(db *PostgresDB) *WebhookDeliveryRepository {
+	return &WebhookDeliveryRepository{db: db}
+}
+
+const webhookDeliveryColumns = `
+	id, tenant_id, subscription_id, event_id, event_type, data, occurred_at, status, attempts,
+	status_code, response_body, error, last_attempt_at, delivered_at, created_at
+`
+
+// Create creates a new delivery
+func (r *WebhookDeliveryRepository) Create(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	data, err := json.Marshal(delivery.Data)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to marshal event data")
+	}
+
+	query := `INSERT INTO webhook_deliveries (` + webhookDeliveryColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
+	_, err = r.db.Pool.Exec(ctx, query,
+		delivery.ID,
+		delivery.TenantID,
+		delivery.SubscriptionID,
+		delivery.EventID,
+		delivery.EventType,
+		data,
+		delivery.OccurredAt,
+		string(delivery.Status),
+		delivery.Attempts,
+		delivery.StatusCode,
+		delivery.ResponseBody,
+		delivery.Error,
+		delivery.LastAttemptAt,
+		delivery.DeliveredAt,
+		delivery.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create webhook delivery")
+	}
+	return nil
+}
+
+// FindByID finds a delivery by ID
+func (r *WebhookDeliveryRepository) FindByID(ctx context.Context, id string) (*entity.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE id = $1`
+	return r.scanDelivery(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// ListBySubscription lists the deliveries of a subscription, newest first
+func (r *WebhookDeliveryRepository) ListBySubscription(ctx context.Context, subscriptionID string, status entity.WebhookDeliveryStatus, params *repository.ListParams) ([]*entity.WebhookDelivery, int64, error) {
+	if params == nil {
+		params = repository.NewListParams()
+	}
+
+	conditions := "subscription_id = $1"
+	args := []interface{}{subscriptionID}
+	if status != "" {
+		args = append(args, string(status))
+		conditions += fmt.Sprintf(" AND status = $%d", len(args))
+	}
+
+	var total int64
+	if err := r.db.Pool.QueryRow(ctx, "SELECT COUNT(*) FROM webhook_deliveries WHERE "+conditions, args...).Scan(&total); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to count webhook deliveries")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s FROM webhook_deliveries
+		WHERE %s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, webhookDeliveryColumns, conditions, len(args)+1, len(args)+2)
+	args = append(args, params.Limit(), params.Offset())
+
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to list webhook deliveries")
+	}
+	defer rows.Close()
+
+	var deliveries []*entity.WebhookDelivery
+	for rows.Next() {
+		delivery, err := r.scanDelivery(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate webhook deliveries")
+	}
+	return deliveries, total, nil
+}
+
+// Update records an attempt of a delivery
+func (r *WebhookDeliveryRepository) Update(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, status_code = $4, response_body = $5, error = $6,
+		    last_attempt_at = $7, delivered_at = $8
+		WHERE id = $1
+	`
+	tag, err := r.db.Pool.Exec(ctx, query,
+		delivery.ID,
+		string(delivery.Status),
+		delivery.Attempts,
+		delivery.StatusCode,
+		delivery.ResponseBody,
+		delivery.Error,
+		delivery.LastAttemptAt,
+		delivery.DeliveredAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update webhook delivery")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "webhook delivery not found")
+	}
+	return nil
+}
+
+func (r *WebhookDeliveryRepository) scanDelivery(row pgx.Row) (*entity.WebhookDelivery, error) {
+	var delivery entity.WebhookDelivery
+	var status string
+	var data []byte
+
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.TenantID,
+		&delivery.SubscriptionID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&data,
+		&delivery.OccurredAt,
+		&status,
+		&delivery.Attempts,
+		&delivery.StatusCode,
+		&delivery.ResponseBody,
+		&delivery.Error,
+		&delivery.LastAttemptAt,
+		&delivery.DeliveredAt,
+		&delivery.CreatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "webhook delivery not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan webhook delivery")
+	}
+
+	delivery.Status = entity.WebhookDeliveryStatus(status)
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &delivery.Data); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to unmarshal event data")
+		}
+	}
+	return &delivery, nil
+}
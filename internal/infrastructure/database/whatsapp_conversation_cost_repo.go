@@ -0,0 +1,185 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// WhatsAppConversationCostRepository implements repository.WhatsAppConversationCostRepository with PostgreSQL
+type WhatsAppConversationCostRepository struct {
+	db *PostgresDB
+}
+
+// NewWhatsAppConversationCostRepository creates a new PostgreSQL WhatsApp conversation cost repository
+func NewWhatsAppConversationCostRepository(db *PostgresDB) *WhatsAppConversationCostRepository {
+	return &WhatsAppConversationCostRepository{db: db}
+}
+
+const whatsAppConversationCostColumns = `
+	id, tenant_id, channel_id, wa_conversation_id, message_id, campaign_id, category,
+	pricing_model, origin, billable, cost, currency, expires_at, created_at, updated_at
+`
+
+// Create stores the pricing of a new conversation window. Duplicate webhooks for the
+// same conversation are ignored.
+func (r *WhatsAppConversationCostRepository) Create(ctx context.Context, cost *entity.WhatsAppConversationCost) error {
+	query := `
+		INSERT INTO whatsapp_conversation_costs (` + whatsAppConversationCostColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		ON CONFLICT (channel_id, wa_conversation_id) DO NOTHING
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query,
+		cost.ID,
+		cost.TenantID,
+		cost.ChannelID,
+		cost.WAConversationID,
+		cost.MessageID,
+		cost.CampaignID,
+		string(cost.Category),
+		cost.PricingModel,
+		cost.Origin,
+		cost.Billable,
+		cost.Cost,
+		cost.Currency,
+		cost.ExpiresAt,
+		cost.CreatedAt,
+		cost.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create WhatsApp conversation cost")
+	}
+	return nil
+}
+
+// FindByWAConversationID finds the pricing recorded for a Meta conversation ID
+func (r *WhatsAppConversationCostRepository) FindByWAConversationID(ctx context.Context, channelID, waConversationID string) (*entity.WhatsAppConversationCost, error) {
+	query := `SELECT ` + whatsAppConversationCostColumns + ` FROM whatsapp_conversation_costs WHERE channel_id = $1 AND wa_conversation_id = $2`
+	return r.scanCost(r.db.Pool.QueryRow(ctx, query, channelID, waConversationID))
+}
+
+// List returns the conversation costs of a tenant in a period
+func (r *WhatsAppConversationCostRepository) List(ctx context.Context, filter entity.AnalyticsFilter) ([]*entity.WhatsAppConversationCost, error) {
+	query := `
+		SELECT ` + whatsAppConversationCostColumns + `
+		FROM whatsapp_conversation_costs
+		WHERE tenant_id = $1 AND created_at BETWEEN $2 AND $3
+		  AND ($4 = '' OR channel_id::text = $4)
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, filter.TenantID, filter.StartDate, filter.EndDate, filter.ChannelID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list WhatsApp conversation costs")
+	}
+	defer rows.Close()
+
+	var costs []*entity.WhatsAppConversationCost
+	for rows.Next() {
+		cost, err := r.scanCost(rows)
+		if err != nil {
+			return nil, err
+		}
+		costs = append(costs, cost)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate WhatsApp conversation costs")
+	}
+
+	return costs, nil
+}
+
+// SummarizeByChannel aggregates costs per channel
+func (r *WhatsAppConversationCostRepository) SummarizeByChannel(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.WhatsAppCostSummary, error) {
+	return r.summarize(ctx, "channel_id::text", filter, func(s *entity.WhatsAppCostSummary, key string) { s.ChannelID = key })
+}
+
+// SummarizeByCampaign aggregates costs per campaign, skipping conversations outside campaigns
+func (r *WhatsAppConversationCostRepository) SummarizeByCampaign(ctx context.Context, filter entity.AnalyticsFilter) ([]entity.WhatsAppCostSummary, error) {
+	return r.summarize(ctx, "campaign_id", filter, func(s *entity.WhatsAppCostSummary, key string) { s.CampaignID = key })
+}
+
+// summarize groups costs by keyColumn and category, folding the categories into one summary per key
+func (r *WhatsAppConversationCostRepository) summarize(ctx context.Context, keyColumn string, filter entity.AnalyticsFilter, setKey func(*entity.WhatsAppCostSummary, string)) ([]entity.WhatsAppCostSummary, error) {
+	query := `
+		SELECT ` + keyColumn + ` AS key, category, MAX(currency),
+			COUNT(*), COUNT(*) FILTER (WHERE billable), COALESCE(SUM(cost), 0)
+		FROM whatsapp_conversation_costs
+		WHERE tenant_id = $1 AND created_at BETWEEN $2 AND $3
+		  AND ($4 = '' OR channel_id::text = $4)
+		  AND ` + keyColumn + ` <> ''
+		GROUP BY key, category
+		ORDER BY key
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, filter.TenantID, filter.StartDate, filter.EndDate, filter.ChannelID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to summarize WhatsApp conversation costs")
+	}
+	defer rows.Close()
+
+	var summaries []entity.WhatsAppCostSummary
+	index := make(map[string]int)
+	for rows.Next() {
+		var key, category, currency string
+		var conversations, billable int64
+		var cost float64
+		if err := rows.Scan(&key, &category, &currency, &conversations, &billable, &cost); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan WhatsApp cost summary")
+		}
+
+		i, ok := index[key]
+		if !ok {
+			summary := entity.WhatsAppCostSummary{Currency: currency, ByCategory: make(map[string]int64)}
+			setKey(&summary, key)
+			summaries = append(summaries, summary)
+			i = len(summaries) - 1
+			index[key] = i
+		}
+
+		summaries[i].Conversations += conversations
+		summaries[i].Billable += billable
+		summaries[i].Cost += cost
+		summaries[i].ByCategory[category] += conversations
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate WhatsApp cost summaries")
+	}
+
+	return summaries, nil
+}
+
+func (r *WhatsAppConversationCostRepository) scanCost(row pgx.Row) (*entity.WhatsAppConversationCost, error) {
+	var cost entity.WhatsAppConversationCost
+	var category string
+
+	err := row.Scan(
+		&cost.ID,
+		&cost.TenantID,
+		&cost.ChannelID,
+		&cost.WAConversationID,
+		&cost.MessageID,
+		&cost.CampaignID,
+		&category,
+		&cost.PricingModel,
+		&cost.Origin,
+		&cost.Billable,
+		&cost.Cost,
+		&cost.Currency,
+		&cost.ExpiresAt,
+		&cost.CreatedAt,
+		&cost.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "WhatsApp conversation cost not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan WhatsApp conversation cost")
+	}
+
+	cost.Category = entity.WhatsAppPricingCategory(category)
+	return &cost, nil
+}
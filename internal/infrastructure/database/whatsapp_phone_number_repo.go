@@ -0,0 +1,176 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// WhatsAppPhoneNumberRepository implements repository.WhatsAppPhoneNumberRepository with PostgreSQL
+type WhatsAppPhoneNumberRepository struct {
+	db *PostgresDB
+}
+
+// NewWhatsAppPhoneNumberRepository creates a new PostgreSQL WhatsApp phone number repository
+func NewWhatsAppPhoneNumberRepository(db *PostgresDB) *WhatsAppPhoneNumberRepository {
+	return &WhatsAppPhoneNumberRepository{db: db}
+}
+
+const whatsAppPhoneNumberColumns = `
+	id, tenant_id, channel_id, phone_number_id, display_phone_number, verified_name,
+	quality_rating, messaging_limit_tier, is_default, enabled, sent_in_window,
+	window_started_at, created_at, updated_at
+`
+
+// Create stores a new phone number
+func (r *WhatsAppPhoneNumberRepository) Create(ctx context.Context, number *entity.WhatsAppPhoneNumber) error {
+	query := `
+		INSERT INTO whatsapp_phone_numbers (` + whatsAppPhoneNumberColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query,
+		number.ID,
+		number.TenantID,
+		number.ChannelID,
+		number.PhoneNumberID,
+		number.DisplayPhoneNumber,
+		number.VerifiedName,
+		number.QualityRating,
+		string(number.MessagingLimitTier),
+		number.IsDefault,
+		number.Enabled,
+		number.SentInWindow,
+		number.WindowStartedAt,
+		number.CreatedAt,
+		number.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to create WhatsApp phone number")
+	}
+	return nil
+}
+
+// FindByID finds a phone number by ID
+func (r *WhatsAppPhoneNumberRepository) FindByID(ctx context.Context, id string) (*entity.WhatsAppPhoneNumber, error) {
+	query := `SELECT ` + whatsAppPhoneNumberColumns + ` FROM whatsapp_phone_numbers WHERE id = $1`
+	return r.scanPhoneNumber(r.db.Pool.QueryRow(ctx, query, id))
+}
+
+// FindByChannel returns all phone numbers of a channel
+func (r *WhatsAppPhoneNumberRepository) FindByChannel(ctx context.Context, channelID string) ([]*entity.WhatsAppPhoneNumber, error) {
+	query := `
+		SELECT ` + whatsAppPhoneNumberColumns + `
+		FROM whatsapp_phone_numbers
+		WHERE channel_id = $1
+		ORDER BY is_default DESC, created_at ASC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, channelID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to list WhatsApp phone numbers")
+	}
+	defer rows.Close()
+
+	var numbers []*entity.WhatsAppPhoneNumber
+	for rows.Next() {
+		number, err := r.scanPhoneNumber(rows)
+		if err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, number)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to iterate WhatsApp phone numbers")
+	}
+
+	return numbers, nil
+}
+
+// FindByPhoneNumberID finds the number registered for a Meta phone_number_id
+func (r *WhatsAppPhoneNumberRepository) FindByPhoneNumberID(ctx context.Context, phoneNumberID string) (*entity.WhatsAppPhoneNumber, error) {
+	query := `SELECT ` + whatsAppPhoneNumberColumns + ` FROM whatsapp_phone_numbers WHERE phone_number_id = $1`
+	return r.scanPhoneNumber(r.db.Pool.QueryRow(ctx, query, phoneNumberID))
+}
+
+// Update updates a phone number
+func (r *WhatsAppPhoneNumberRepository) Update(ctx context.Context, number *entity.WhatsAppPhoneNumber) error {
+	query := `
+		UPDATE whatsapp_phone_numbers SET
+			display_phone_number = $2,
+			verified_name = $3,
+			quality_rating = $4,
+			messaging_limit_tier = $5,
+			is_default = $6,
+			enabled = $7,
+			sent_in_window = $8,
+			window_started_at = $9,
+			updated_at = $10
+		WHERE id = $1
+	`
+
+	result, err := r.db.Pool.Exec(ctx, query,
+		number.ID,
+		number.DisplayPhoneNumber,
+		number.VerifiedName,
+		number.QualityRating,
+		string(number.MessagingLimitTier),
+		number.IsDefault,
+		number.Enabled,
+		number.SentInWindow,
+		number.WindowStartedAt,
+		number.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to update WhatsApp phone number")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "WhatsApp phone number not found")
+	}
+	return nil
+}
+
+// Delete deletes a phone number
+func (r *WhatsAppPhoneNumberRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.Pool.Exec(ctx, "DELETE FROM whatsapp_phone_numbers WHERE id = $1", id)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to delete WhatsApp phone number")
+	}
+	if result.RowsAffected() == 0 {
+		return errors.New(errors.ErrCodeNotFound, "WhatsApp phone number not found")
+	}
+	return nil
+}
+
+func (r *WhatsAppPhoneNumberRepository) scanPhoneNumber(row pgx.Row) (*entity.WhatsAppPhoneNumber, error) {
+	var number entity.WhatsAppPhoneNumber
+	var tier string
+
+	err := row.Scan(
+		&number.ID,
+		&number.TenantID,
+		&number.ChannelID,
+		&number.PhoneNumberID,
+		&number.DisplayPhoneNumber,
+		&number.VerifiedName,
+		&number.QualityRating,
+		&tier,
+		&number.IsDefault,
+		&number.Enabled,
+		&number.SentInWindow,
+		&number.WindowStartedAt,
+		&number.CreatedAt,
+		&number.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "WhatsApp phone number not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan WhatsApp phone number")
+	}
+
+	number.MessagingLimitTier = entity.MessagingLimitTier(tier)
+	return &number, nil
+}
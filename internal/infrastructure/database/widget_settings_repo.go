@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+// WidgetSettingsRepository implements repository.WidgetSettingsRepository with PostgreSQL
+type WidgetSettingsRepository struct {
+	db *PostgresDB
+}
+
+// NewWidgetSettingsRepository creates a new PostgreSQL widget settings repository
+func NewWidgetSettingsRepository(db *PostgresDB) *WidgetSettingsRepository {
+	return &WidgetSettingsRepository{db: db}
+}
+
+// FindByTenant finds the widget settings of a tenant
+func (r *WidgetSettingsRepository) FindByTenant(ctx context.Context, tenantID string) (*entity.WidgetSettings, error) {
+	query := `
+		SELECT tenant_id, quick_replies, emoji_sets, updated_at
+		FROM widget_settings WHERE tenant_id = $1
+	`
+
+	var settings entity.WidgetSettings
+	var quickReplies, emojiSets []byte
+	err := r.db.Pool.QueryRow(ctx, query, tenantID).Scan(
+		&settings.TenantID,
+		&quickReplies,
+		&emojiSets,
+		&settings.UpdatedAt,
+	)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, errors.New(errors.ErrCodeNotFound, "widget settings not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find widget settings")
+	}
+
+	if err := json.Unmarshal(quickReplies, &settings.QuickReplies); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to decode quick replies")
+	}
+	if err := json.Unmarshal(emojiSets, &settings.EmojiSets); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to decode emoji sets")
+	}
+	return &settings, nil
+}
+
+// Save creates or updates the widget settings of a tenant
+func (r *WidgetSettingsRepository) Save(ctx context.Context, settings *entity.WidgetSettings) error {
+	query := `
+		INSERT INTO widget_settings (tenant_id, quick_replies, emoji_sets, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			quick_replies = EXCLUDED.quick_replies,
+			emoji_sets = EXCLUDED.emoji_sets,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	quickReplies := settings.QuickReplies
+	if quickReplies == nil {
+		quickReplies = []entity.WidgetQuickReply{}
+	}
+	emojiSets := settings.EmojiSets
+	if emojiSets == nil {
+		emojiSets = []entity.WidgetEmojiSet{}
+	}
+	quickRepliesJSON, err := json.Marshal(quickReplies)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to encode quick replies")
+	}
+	emojiSetsJSON, err := json.Marshal(emojiSets)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to encode emoji sets")
+	}
+
+	_, err = r.db.Pool.Exec(ctx, query, settings.TenantID, quickRepliesJSON, emojiSetsJSON, settings.UpdatedAt)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to save widget settings")
+	}
+	return nil
+}
+
+// CreateQuickReplyClick records a click on a quick reply
+func (r *WidgetSettingsRepository) CreateQuickReplyClick(ctx context.Context, click *entity.QuickReplyClick) error {
+	query := `
+		INSERT INTO quick_reply_clicks (id, tenant_id, channel_id, conversation_id, quick_reply_id, label, clicked_at)
+		VALUES ($1, $2, $3, NULLIF($4, '')::uuid, $5, $6, $7)
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query,
+		click.ID,
+		click.TenantID,
+		click.ChannelID,
+		click.ConversationID,
+		click.QuickReplyID,
+		click.Label,
+		click.ClickedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to record quick reply click")
+	}
+	return nil
+}
+
+// QuickReplyStats counts the clicks on a tenant's quick replies in a period, most
+// clicked first. Each quick reply is reported with its latest label.
+func (r *WidgetSettingsRepository) QuickReplyStats(ctx context.Context, tenantID string, startDate, endDate time.Time) ([]*entity.QuickReplyStats, error) {
+	query := `
+		SELECT quick_reply_id,
+			(ARRAY_AGG(label ORDER BY clicked_at DESC))[1],
+			COUNT(*),
+			COUNT(DISTINCT conversation_id)
+		FROM quick_reply_clicks
+		WHERE tenant_id = $1 AND clicked_at >= $2 AND clicked_at <= $3
+		GROUP BY quick_reply_id
+		ORDER BY COUNT(*) DESC, quick_reply_id
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, tenantID, startDate, endDate)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to count quick reply clicks")
+	}
+	defer rows.Close()
+
+	stats := []*entity.QuickReplyStats{}
+	for rows.Next() {
+		var s entity.QuickReplyStats
+		if err := rows.Scan(&s.QuickReplyID, &s.Label, &s.Clicks, &s.Conversations); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to scan quick reply stats")
+		}
+		stats = append(stats, &s)
+	}
+	return stats, nil
+}
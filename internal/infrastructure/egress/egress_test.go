@@ -0,0 +1,59 @@
+package egress
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllowed(t *testing.T) {
+	for _, ip := range []string{"93.184.216.34", "8.8.8.8", "2606:4700:4700::1111"} {
+		assert.True(t, Allowed(net.ParseIP(ip)), ip)
+	}
+	for _, ip := range []string{
+		"127.0.0.1", "10.1.2.3", "172.16.0.5", "192.168.1.1", "169.254.169.254",
+		"100.64.0.1", "0.0.0.0", "224.0.0.1", "255.255.255.255",
+		"::1", "::", "fe80::1", "fd00::1", "ff02::1", "::ffff:169.254.169.254", "64:ff9b::a9fe:a9fe",
+	} {
+		assert.False(t, Allowed(net.ParseIP(ip)), ip)
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	ctx := context.Background()
+	for _, raw := range []string{
+		"http://169.254.169.254/latest/meta-data/",
+		"http://127.0.0.1:8080/hook",
+		"https://10.0.0.7/hook",
+		"http://[::1]/hook",
+		"http://[::ffff:127.0.0.1]/hook",
+		"http://localhost:3000/hook",
+		"http://api.localhost/hook",
+	} {
+		assert.ErrorIs(t, CheckURL(ctx, raw), ErrForbiddenAddress, raw)
+	}
+	assert.NoError(t, CheckURL(ctx, "https://93.184.216.34/hook"))
+}
+
+func TestClient_RefusesInternalAddressesWhenDialing(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the request must not reach the server")
+	}))
+	defer server.Close()
+
+	// A name resolving to an internal address is caught too, whatever it resolved
+	// to when the URL was saved
+	port := server.URL[strings.LastIndex(server.URL, ":")+1:]
+	for _, target := range []string{server.URL, "http://localhost:" + port} {
+		req, err := http.NewRequest(http.MethodGet, target, nil)
+		require.NoError(t, err)
+		_, err = NewClient().Do(req)
+		assert.ErrorIs(t, err, ErrForbiddenAddress, target)
+	}
+}
@@ -0,0 +1,168 @@
+// Package encryption implements envelope encryption of tenant data.
+//
+// Each tenant has data keys (DEKs) that encrypt stored values with AES-256-GCM.
+// Data keys are never stored in clear: they are wrapped by a key encryption key
+// (KEK) held by a KeyWrapper, either the platform master key, a key supplied by
+// the tenant, or an external KMS. Encrypted values are self-describing strings
+// carrying the ID of the data key that sealed them, so values written under
+// older keys stay readable after a rotation.
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// KeySize is the size in bytes of data and key encryption keys
+const KeySize = 32
+
+// valuePrefix marks encrypted values; the key ID and the sealed data follow
+const valuePrefix = "enc:v1:"
+
+var (
+	// ErrInvalidKey is returned for keys that are not KeySize bytes long
+	ErrInvalidKey = errors.New("encryption key must be 32 bytes")
+	// ErrMalformed is returned for encrypted values that cannot be parsed
+	ErrMalformed = errors.New("malformed encrypted value")
+)
+
+// Cipher encrypts and decrypts stored values with the keys of a tenant.
+// Repositories use it to encrypt on write and decrypt on read.
+type Cipher interface {
+	// Encrypt seals a value with the active key of the tenant. The value is
+	// returned unchanged when the tenant has no active key.
+	Encrypt(ctx context.Context, tenantID, plaintext string) (string, error)
+	// Decrypt opens a value sealed by Encrypt. Values that are not encrypted are
+	// returned unchanged.
+	Decrypt(ctx context.Context, value string) (string, error)
+}
+
+// GenerateKey returns a new random key
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// IsEncrypted reports whether a value was sealed by Seal
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, valuePrefix)
+}
+
+// KeyID returns the ID of the key that sealed a value
+func KeyID(value string) (string, error) {
+	keyID, _, err := split(value)
+	return keyID, err
+}
+
+// Seal encrypts a value with a data key. The key ID is authenticated with the
+// data, so a value cannot be moved to another key.
+func Seal(key []byte, keyID, plaintext string) (string, error) {
+	sealed, err := sealBytes(key, []byte(plaintext), []byte(keyID))
+	if err != nil {
+		return "", err
+	}
+	return valuePrefix + keyID + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Open decrypts a value sealed by Seal
+func Open(key []byte, value string) (string, error) {
+	keyID, data, err := split(value)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := openBytes(key, data, []byte(keyID))
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+func split(value string) (string, []byte, error) {
+	if !IsEncrypted(value) {
+		return "", nil, ErrMalformed
+	}
+	rest := strings.TrimPrefix(value, valuePrefix)
+	i := strings.Index(rest, ":")
+	if i <= 0 {
+		return "", nil, ErrMalformed
+	}
+	data, err := base64.RawStdEncoding.DecodeString(rest[i+1:])
+	if err != nil {
+		return "", nil, ErrMalformed
+	}
+	return rest[:i], data, nil
+}
+
+// SealData encrypts binary data with a key, for data too large to keep as a sealed
+// string. The additional data is authenticated, binding the result to its context.
+func SealData(key, plaintext, additionalData []byte) ([]byte, error) {
+	return sealBytes(key, plaintext, additionalData)
+}
+
+// OpenData decrypts data sealed by SealData with the same additional data
+func OpenData(key, data, additionalData []byte) ([]byte, error) {
+	return openBytes(key, data, additionalData)
+}
+
+// sealBytes encrypts with AES-256-GCM, prefixing the random nonce
+func sealBytes(key, plaintext, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+// openBytes decrypts data produced by sealBytes
+func openBytes(key, data, additionalData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, ErrMalformed
+	}
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], additionalData)
+	if err != nil {
+		return nil, fmt.Errorf("decryption failed: %w", err)
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+type tenantKey struct{}
+
+// WithTenant scopes a context to a tenant. Decryption in a tenant scoped
+// context refuses values sealed with another tenant's keys.
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant a context is scoped to, if any
+func TenantFromContext(ctx context.Context) string {
+	tenantID, _ := ctx.Value(tenantKey{}).(string)
+	return tenantID
+}
@@ -0,0 +1,106 @@
+package encryption
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSealAndOpen(t *testing.T) {
+	key, err := GenerateKey()
+	require.NoError(t, err)
+
+	sealed, err := Seal(key, "key-1", "Olá, mundo")
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(sealed))
+	assert.NotContains(t, sealed, "mundo")
+
+	keyID, err := KeyID(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "key-1", keyID)
+
+	plaintext, err := Open(key, sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "Olá, mundo", plaintext)
+
+	other, _ := GenerateKey()
+	_, err = Open(other, sealed)
+	assert.Error(t, err)
+
+	// The key ID is authenticated, so relabelling a value breaks it
+	_, err = Open(key, strings.Replace(sealed, "key-1", "key-2", 1))
+	assert.Error(t, err)
+
+	assert.False(t, IsEncrypted("plain text"))
+	_, err = KeyID("enc:v1:nokey")
+	assert.ErrorIs(t, err, ErrMalformed)
+}
+
+func TestAESWrapper(t *testing.T) {
+	_, err := NewAESWrapperFromBase64(base64.StdEncoding.EncodeToString([]byte("short")))
+	assert.ErrorIs(t, err, ErrInvalidKey)
+
+	kek, _ := GenerateKey()
+	wrapper, err := NewAESWrapperFromBase64(base64.StdEncoding.EncodeToString(kek))
+	require.NoError(t, err)
+
+	dek, _ := GenerateKey()
+	wrapped, err := wrapper.Wrap(context.Background(), "tenant-1", dek)
+	require.NoError(t, err)
+
+	unwrapped, err := wrapper.Unwrap(context.Background(), "tenant-1", wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, dek, unwrapped)
+
+	_, err = wrapper.Unwrap(context.Background(), "tenant-2", wrapped)
+	assert.Error(t, err)
+}
+
+func TestVaultTransitWrapper(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "vault-token", r.Header.Get("X-Vault-Token"))
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		switch r.URL.Path {
+		case "/v1/transit/encrypt/tenant-key":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]string{"ciphertext": "vault:v1:" + body["plaintext"]},
+			})
+		case "/v1/transit/decrypt/tenant-key":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]string{"plaintext": strings.TrimPrefix(body["ciphertext"], "vault:v1:")},
+			})
+		default:
+			http.Error(w, `{"errors":["no such key"]}`, http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	wrapper := NewVaultTransitWrapper(server.URL+"/", "vault-token", "")
+	dek, _ := GenerateKey()
+
+	wrapped, err := wrapper.Wrap(context.Background(), "tenant-key", dek)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(wrapped), "vault:v1:"))
+
+	unwrapped, err := wrapper.Unwrap(context.Background(), "tenant-key", wrapped)
+	require.NoError(t, err)
+	assert.Equal(t, dek, unwrapped)
+
+	_, err = wrapper.Wrap(context.Background(), "missing", dek)
+	assert.ErrorContains(t, err, "status 400")
+}
+
+func TestTenantContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Empty(t, TenantFromContext(ctx))
+	assert.Equal(t, "tenant-1", TenantFromContext(WithTenant(ctx, "tenant-1")))
+}
@@ -0,0 +1,152 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// KeyWrapper protects data keys with a key encryption key
+type KeyWrapper interface {
+	// Name identifies the wrapper
+	Name() string
+	// Wrap encrypts a data key with the key encryption key named by keyRef
+	Wrap(ctx context.Context, keyRef string, key []byte) ([]byte, error)
+	// Unwrap decrypts a data key wrapped by Wrap
+	Unwrap(ctx context.Context, keyRef string, wrapped []byte) ([]byte, error)
+}
+
+// AESWrapper wraps data keys with a local AES-256 key. It backs both the
+// platform master key and keys supplied by tenants.
+type AESWrapper struct {
+	key []byte
+}
+
+// NewAESWrapper creates a wrapper using a 32 byte key
+func NewAESWrapper(key []byte) (*AESWrapper, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+	return &AESWrapper{key: key}, nil
+}
+
+// NewAESWrapperFromBase64 creates a wrapper from a base64 encoded 32 byte key
+func NewAESWrapperFromBase64(encoded string) (*AESWrapper, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("key is not valid base64: %w", err)
+	}
+	return NewAESWrapper(key)
+}
+
+// Name identifies the wrapper
+func (w *AESWrapper) Name() string {
+	return "aes"
+}
+
+// Wrap encrypts a data key; keyRef is bound to the result
+func (w *AESWrapper) Wrap(ctx context.Context, keyRef string, key []byte) ([]byte, error) {
+	return sealBytes(w.key, key, []byte(keyRef))
+}
+
+// Unwrap decrypts a data key wrapped by Wrap with the same keyRef
+func (w *AESWrapper) Unwrap(ctx context.Context, keyRef string, wrapped []byte) ([]byte, error) {
+	return openBytes(w.key, wrapped, []byte(keyRef))
+}
+
+// VaultTransitWrapper wraps data keys with the transit secrets engine of
+// HashiCorp Vault, so key encryption keys never leave the KMS. keyRef is the
+// name of the transit key.
+type VaultTransitWrapper struct {
+	address    string
+	token      string
+	mount      string
+	httpClient *http.Client
+}
+
+// NewVaultTransitWrapper creates a wrapper for the Vault server at address.
+// The transit engine is expected at the "transit" mount when mount is empty.
+func NewVaultTransitWrapper(address, token, mount string) *VaultTransitWrapper {
+	if mount == "" {
+		mount = "transit"
+	}
+	return &VaultTransitWrapper{
+		address:    strings.TrimRight(address, "/"),
+		token:      token,
+		mount:      strings.Trim(mount, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name identifies the wrapper
+func (w *VaultTransitWrapper) Name() string {
+	return "vault"
+}
+
+// Wrap encrypts a data key with a transit key
+func (w *VaultTransitWrapper) Wrap(ctx context.Context, keyRef string, key []byte) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	body := map[string]string{"plaintext": base64.StdEncoding.EncodeToString(key)}
+	if err := w.call(ctx, "encrypt", keyRef, body, &resp); err != nil {
+		return nil, err
+	}
+	return []byte(resp.Data.Ciphertext), nil
+}
+
+// Unwrap decrypts a data key wrapped by a transit key
+func (w *VaultTransitWrapper) Unwrap(ctx context.Context, keyRef string, wrapped []byte) ([]byte, error) {
+	var resp struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	body := map[string]string{"ciphertext": string(wrapped)}
+	if err := w.call(ctx, "decrypt", keyRef, body, &resp); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(resp.Data.Plaintext)
+}
+
+func (w *VaultTransitWrapper) call(ctx context.Context, operation, keyRef string, body, out interface{}) error {
+	if keyRef == "" {
+		return fmt.Errorf("vault transit key name is required")
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/%s/%s/%s", w.address, w.mount, operation, url.PathEscape(keyRef))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Vault-Token", w.token)
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read vault response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("vault %s returned status %d: %s", operation, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return json.Unmarshal(data, out)
+}
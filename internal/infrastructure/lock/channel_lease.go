@@ -0,0 +1,141 @@
+package lock
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
+)
+
+const (
+	channelLeaseKeyPrefix = "linktor:channel-lease:"
+	// channelLeaseRecordTTL is how long the owner of a channel is remembered after its
+	// lock expired, so the next owner records the change as a takeover
+	channelLeaseRecordTTL = 7 * 24 * time.Hour
+)
+
+// releaseRecordScript forgets the channel's owner only when ownerID still is it
+var releaseRecordScript = redis.NewScript(`
+if redis.call('HGET', KEYS[1], 'owner') == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// ChannelLeaseRepository implements repository.ChannelLeaseRepository with a Redis
+// lock per channel, so instances coordinate channel ownership without a database
+// round trip on every renewal. The audit trail stays with events.
+type ChannelLeaseRepository struct {
+	client *redis.Client
+	locker *RedisLocker
+	events repository.ChannelLeaseRepository
+	now    func() time.Time
+}
+
+// NewChannelLeaseRepository creates a Redis channel lease repository recording
+// ownership changes in events
+func NewChannelLeaseRepository(client *redis.Client, events repository.ChannelLeaseRepository) *ChannelLeaseRepository {
+	return &ChannelLeaseRepository{
+		client: client,
+		locker: NewRedisLocker(client, DefaultPrefix),
+		events: events,
+		now:    time.Now,
+	}
+}
+
+// Acquire takes or extends the channel's lock for ownerID and records the lease
+func (r *ChannelLeaseRepository) Acquire(ctx context.Context, tenantID, channelID, ownerID string, ttl time.Duration) (*entity.ChannelLease, string, error) {
+	held, err := r.locker.Acquire(ctx, channelLockName(channelID), ownerID, ttl)
+	if err != nil {
+		return nil, "", errors.Wrap(err, errors.ErrCodeInternal, "failed to acquire channel lease")
+	}
+	if !held {
+		return nil, "", nil
+	}
+
+	key := channelLeaseKeyPrefix + channelID
+	record, err := r.client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, "", errors.Wrap(err, errors.ErrCodeInternal, "failed to read channel lease")
+	}
+
+	now := r.now()
+	previousOwnerID := record["owner"]
+	lease := &entity.ChannelLease{
+		ChannelID:  channelID,
+		TenantID:   tenantID,
+		OwnerID:    ownerID,
+		AcquiredAt: now,
+		RenewedAt:  now,
+		ExpiresAt:  now.Add(ttl),
+	}
+	if previousOwnerID == ownerID {
+		if acquiredAt, err := time.Parse(time.RFC3339Nano, record["acquired_at"]); err == nil {
+			lease.AcquiredAt = acquiredAt
+		}
+	}
+
+	pipe := r.client.TxPipeline()
+	pipe.HSet(ctx, key,
+		"owner", lease.OwnerID,
+		"tenant_id", lease.TenantID,
+		"acquired_at", lease.AcquiredAt.Format(time.RFC3339Nano),
+		"renewed_at", lease.RenewedAt.Format(time.RFC3339Nano),
+		"expires_at", lease.ExpiresAt.Format(time.RFC3339Nano),
+	)
+	pipe.Expire(ctx, key, channelLeaseRecordTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, "", errors.Wrap(err, errors.ErrCodeInternal, "failed to record channel lease")
+	}
+	return lease, previousOwnerID, nil
+}
+
+// Release gives the channel's lock up if ownerID holds it
+func (r *ChannelLeaseRepository) Release(ctx context.Context, channelID, ownerID string) error {
+	if err := r.locker.Release(ctx, channelLockName(channelID), ownerID); err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to release channel lease")
+	}
+	if err := releaseRecordScript.Run(ctx, r.client, []string{channelLeaseKeyPrefix + channelID}, ownerID).Err(); err != nil && err != redis.Nil {
+		return errors.Wrap(err, errors.ErrCodeInternal, "failed to release channel lease")
+	}
+	return nil
+}
+
+// FindByChannel returns the channel's lease
+func (r *ChannelLeaseRepository) FindByChannel(ctx context.Context, channelID string) (*entity.ChannelLease, error) {
+	record, err := r.client.HGetAll(ctx, channelLeaseKeyPrefix+channelID).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternal, "failed to find channel lease")
+	}
+	if record["owner"] == "" {
+		return nil, errors.New(errors.ErrCodeNotFound, "channel lease not found")
+	}
+
+	lease := &entity.ChannelLease{
+		ChannelID: channelID,
+		TenantID:  record["tenant_id"],
+		OwnerID:   record["owner"],
+	}
+	lease.AcquiredAt, _ = time.Parse(time.RFC3339Nano, record["acquired_at"])
+	lease.RenewedAt, _ = time.Parse(time.RFC3339Nano, record["renewed_at"])
+	lease.ExpiresAt, _ = time.Parse(time.RFC3339Nano, record["expires_at"])
+	return lease, nil
+}
+
+// CreateEvent records an ownership change
+func (r *ChannelLeaseRepository) CreateEvent(ctx context.Context, event *entity.ChannelFailoverEvent) error {
+	return r.events.CreateEvent(ctx, event)
+}
+
+// ListEvents lists the channel's ownership changes, newest first
+func (r *ChannelLeaseRepository) ListEvents(ctx context.Context, channelID string, limit int) ([]*entity.ChannelFailoverEvent, error) {
+	return r.events.ListEvents(ctx, channelID, limit)
+}
+
+// channelLockName is the name of the lock owning a channel's adapter
+func channelLockName(channelID string) string {
+	return "channel:" + channelID
+}
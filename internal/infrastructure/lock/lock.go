@@ -0,0 +1,88 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// DefaultPrefix namespaces the lock keys in Redis
+const DefaultPrefix = "linktor:lock:"
+
+// acquireScript takes the lock when it is free and extends it when the caller
+// already holds it, in one step so two instances never both win
+var acquireScript = redis.NewScript(`
+local owner = redis.call('GET', KEYS[1])
+if owner and owner ~= ARGV[1] then
+	return 0
+end
+redis.call('SET', KEYS[1], ARGV[1], 'PX', ARGV[2])
+return 1
+`)
+
+// releaseScript deletes the lock only when the caller holds it, so an owner whose
+// lock expired cannot release the lock of the instance that took it over
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// RedisLocker hands out distributed locks kept in Redis. A lock is held by one owner,
+// usually a server instance, until it releases the lock or stops extending it for
+// longer than its TTL.
+type RedisLocker struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisLocker creates a locker keeping its locks under prefix, DefaultPrefix when empty
+func NewRedisLocker(client *redis.Client, prefix string) *RedisLocker {
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+	return &RedisLocker{client: client, prefix: prefix}
+}
+
+// Acquire takes the named lock for owner for ttl, or extends it when owner already
+// holds it. It reports false when another owner holds the lock.
+func (l *RedisLocker) Acquire(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	if ttl < time.Millisecond {
+		return false, fmt.Errorf("lock ttl must be at least a millisecond")
+	}
+	acquired, err := acquireScript.Run(ctx, l.client, []string{l.key(name)}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lock %s: %w", name, err)
+	}
+	return acquired == 1, nil
+}
+
+// Release gives the named lock up if owner holds it
+func (l *RedisLocker) Release(ctx context.Context, name, owner string) error {
+	if err := releaseScript.Run(ctx, l.client, []string{l.key(name)}, owner).Err(); err != nil && err != redis.Nil {
+		return fmt.Errorf("failed to release lock %s: %w", name, err)
+	}
+	return nil
+}
+
+// Holder returns the owner of the named lock and how long it stays held without
+// being extended, or "" when nobody holds it
+func (l *RedisLocker) Holder(ctx context.Context, name string) (string, time.Duration, error) {
+	pipe := l.client.Pipeline()
+	owner := pipe.Get(ctx, l.key(name))
+	ttl := pipe.PTTL(ctx, l.key(name))
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return "", 0, fmt.Errorf("failed to read lock %s: %w", name, err)
+	}
+	if owner.Err() == redis.Nil {
+		return "", 0, nil
+	}
+	return owner.Val(), ttl.Val(), nil
+}
+
+func (l *RedisLocker) key(name string) string {
+	return l.prefix + name
+}
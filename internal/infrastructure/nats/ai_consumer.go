@@ -57,20 +57,46 @@ type BotEscalationHandler func(ctx context.Context, req *BotEscalationRequest) e
 
 // AIConsumer consumes AI-related messages from NATS JetStream
 type AIConsumer struct {
-	client     *Client
-	consumers  []jetstream.Consumer
-	cancelFunc context.CancelFunc
+	client      *Client
+	consumers   []jetstream.Consumer
+	cancelFunc  context.CancelFunc
+	concurrency int
+	paused      func() bool
+	locker      KeyLocker
 }
 
 // NewAIConsumer creates a new AI consumer
 func NewAIConsumer(client *Client) *AIConsumer {
 	return &AIConsumer{
-		client:    client,
-		consumers: make([]jetstream.Consumer, 0),
+		client:      client,
+		consumers:   make([]jetstream.Consumer, 0),
+		concurrency: DefaultConsumerConcurrency,
 	}
 }
 
-// EnsureStream ensures the AI stream exists
+// SetConcurrency sets how many messages of each subscription are handled in parallel.
+// Messages of the same conversation are still handled one at a time, in order.
+// It applies to subscriptions made afterwards.
+func (c *AIConsumer) SetConcurrency(concurrency int) {
+	if concurrency > 0 {
+		c.concurrency = concurrency
+	}
+}
+
+// SetPause holds back AI requests while paused reports true. It applies to
+// subscriptions made afterwards.
+func (c *AIConsumer) SetPause(paused func() bool) {
+	c.paused = paused
+}
+
+// SetOrderingLock locks the ordering key of each message with locker while it is
+// handled, so AI requests of a conversation are handled by one server instance at a time
+// when several share the consumers. It applies to subscriptions made afterwards.
+func (c *AIConsumer) SetOrderingLock(locker KeyLocker) {
+	c.locker = locker
+}
+
+// EnsureStream ensures the AI stream exists, once NATS is connected when it is not yet
 func (c *AIConsumer) EnsureStream(ctx context.Context) error {
 	streamCfg := jetstream.StreamConfig{
 		Name:        StreamAI,
@@ -88,7 +114,9 @@ func (c *AIConsumer) EnsureStream(ctx context.Context) error {
 		Duplicates:   5 * time.Minute,
 	}
 
-	_, err := c.client.js.CreateOrUpdateStream(ctx, streamCfg)
+	err := c.client.setUpOnConnect(ctx, func(ctx context.Context) error {
+		return c.client.ensureStream(ctx, streamCfg)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to create AI stream: %w", err)
 	}
@@ -105,6 +133,7 @@ func (c *AIConsumer) SubscribeBotAnalysis(ctx context.Context, handler BotAnalys
 		MaxDeliver:    3,
 		AckWait:       30 * time.Second,
 		MaxAckPending: 100,
+		OrderingKey:   orderingKey[BotAnalysisRequest],
 	}
 
 	return c.subscribe(ctx, cfg, func(msg jetstream.Msg) error {
@@ -125,6 +154,7 @@ func (c *AIConsumer) SubscribeBotResponse(ctx context.Context, handler BotRespon
 		MaxDeliver:    3,
 		AckWait:       60 * time.Second, // Longer timeout for AI calls
 		MaxAckPending: 50,
+		OrderingKey:   orderingKey[BotResponseRequest],
 	}
 
 	return c.subscribe(ctx, cfg, func(msg jetstream.Msg) error {
@@ -145,6 +175,7 @@ func (c *AIConsumer) SubscribeBotEscalation(ctx context.Context, handler BotEsca
 		MaxDeliver:    5,
 		AckWait:       30 * time.Second,
 		MaxAckPending: 100,
+		OrderingKey:   orderingKey[BotEscalationRequest],
 	}
 
 	return c.subscribe(ctx, cfg, func(msg jetstream.Msg) error {
@@ -156,60 +187,44 @@ func (c *AIConsumer) SubscribeBotEscalation(ctx context.Context, handler BotEsca
 	})
 }
 
-// subscribe creates a consumer and starts consuming messages
+// subscribe creates a consumer and starts consuming messages, once NATS is connected
+// when it is not yet
 func (c *AIConsumer) subscribe(ctx context.Context, cfg ConsumerConfig, handler func(jetstream.Msg) error) error {
-	stream, err := c.client.js.Stream(ctx, cfg.Stream)
-	if err != nil {
-		return fmt.Errorf("failed to get stream %s: %w", cfg.Stream, err)
-	}
+	concurrency, paused, locker := c.concurrency, c.paused, c.locker
+	started := false
+	return c.client.setUpOnConnect(ctx, func(setupCtx context.Context) error {
+		stream, err := c.client.js.Stream(setupCtx, cfg.Stream)
+		if err != nil {
+			return fmt.Errorf("failed to get stream %s: %w", cfg.Stream, err)
+		}
 
-	consumerCfg := jetstream.ConsumerConfig{
-		Name:          cfg.Name,
-		Durable:       cfg.Name,
-		FilterSubject: cfg.FilterSubject,
-		AckPolicy:     jetstream.AckExplicitPolicy,
-		MaxDeliver:    cfg.MaxDeliver,
-		AckWait:       cfg.AckWait,
-		MaxAckPending: cfg.MaxAckPending,
-		DeliverPolicy: jetstream.DeliverAllPolicy,
-	}
+		consumerCfg := jetstream.ConsumerConfig{
+			Name:          cfg.Name,
+			Durable:       cfg.Name,
+			FilterSubject: cfg.FilterSubject,
+			AckPolicy:     jetstream.AckExplicitPolicy,
+			MaxDeliver:    cfg.MaxDeliver,
+			AckWait:       cfg.AckWait,
+			MaxAckPending: cfg.MaxAckPending,
+			DeliverPolicy: jetstream.DeliverAllPolicy,
+		}
 
-	consumer, err := stream.CreateOrUpdateConsumer(ctx, consumerCfg)
-	if err != nil {
-		return fmt.Errorf("failed to create consumer %s: %w", cfg.Name, err)
-	}
+		consumer, err := stream.CreateOrUpdateConsumer(setupCtx, consumerCfg)
+		if err != nil {
+			return fmt.Errorf("failed to create consumer %s: %w", cfg.Name, err)
+		}
 
-	c.consumers = append(c.consumers, consumer)
-
-	// Start consuming in a goroutine
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			default:
-				msgs, err := consumer.Fetch(10, jetstream.FetchMaxWait(5*time.Second))
-				if err != nil {
-					if err == context.Canceled || err == context.DeadlineExceeded {
-						continue
-					}
-					time.Sleep(1 * time.Second)
-					continue
-				}
-
-				for msg := range msgs.Messages() {
-					if err := handler(msg); err != nil {
-						// NAK with delay for retry
-						msg.NakWithDelay(5 * time.Second)
-					} else {
-						msg.Ack()
-					}
-				}
-			}
+		if started {
+			return nil
 		}
-	}()
+		started = true
+		c.consumers = append(c.consumers, consumer)
 
-	return nil
+		// Start consuming in a goroutine
+		go consume(ctx, consumer, concurrency, paused, cfg.OrderingKey, newKeyLock(locker, cfg.Name), cfg.RetryDelay, c.client.deadLetterer(cfg.Name, cfg.MaxDeliver), handler)
+
+		return nil
+	})
 }
 
 // Stop stops all consumers
@@ -229,7 +244,7 @@ func (p *Producer) PublishBotAnalysis(ctx context.Context, req *BotAnalysisReque
 	}
 
 	subject := SubjectBotAnalyze(req.TenantID)
-	_, err = p.client.js.Publish(ctx, subject, data)
+	err = p.client.publish(ctx, subject, data)
 	if err != nil {
 		return fmt.Errorf("failed to publish bot analysis request: %w", err)
 	}
@@ -245,7 +260,7 @@ func (p *Producer) PublishBotResponse(ctx context.Context, req *BotResponseReque
 	}
 
 	subject := SubjectBotResponse(req.TenantID)
-	_, err = p.client.js.Publish(ctx, subject, data)
+	err = p.client.publish(ctx, subject, data)
 	if err != nil {
 		return fmt.Errorf("failed to publish bot response request: %w", err)
 	}
@@ -261,7 +276,7 @@ func (p *Producer) PublishBotEscalation(ctx context.Context, req *BotEscalationR
 	}
 
 	subject := SubjectBotEscalate(req.TenantID)
-	_, err = p.client.js.Publish(ctx, subject, data)
+	err = p.client.publish(ctx, subject, data)
 	if err != nil {
 		return fmt.Errorf("failed to publish bot escalation request: %w", err)
 	}
@@ -0,0 +1,85 @@
+package nats
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+)
+
+// broadcastEnvelope carries a broadcast with the instance that sent it
+type broadcastEnvelope struct {
+	Origin string `json:"origin"`
+	Data   []byte `json:"data"`
+}
+
+// Broadcaster fans messages out to every server instance over core NATS, e.g. to
+// reach WebSocket clients connected to other replicas. The JetStream consumers work
+// the other way round: replicas bind to the same durable consumer and share its
+// messages like a queue group, so each message is handled once.
+type Broadcaster struct {
+	conn       *nats.Conn
+	instanceID string
+
+	mu   sync.Mutex
+	subs []*nats.Subscription
+}
+
+// NewBroadcaster creates a broadcaster for the instance instanceID
+func NewBroadcaster(client *Client, instanceID string) *Broadcaster {
+	return &Broadcaster{
+		conn:       client.conn,
+		instanceID: instanceID,
+	}
+}
+
+// Publish sends data to the other instances subscribed to the topic
+func (b *Broadcaster) Publish(topic string, data []byte) error {
+	payload, err := json.Marshal(&broadcastEnvelope{Origin: b.instanceID, Data: data})
+	if err != nil {
+		return fmt.Errorf("failed to marshal broadcast: %w", err)
+	}
+	if err := b.conn.Publish(SubjectBroadcast(topic), payload); err != nil {
+		return fmt.Errorf("failed to publish broadcast: %w", err)
+	}
+	return nil
+}
+
+// Subscribe runs handler for every broadcast of the topic sent by another instance
+func (b *Broadcaster) Subscribe(topic string, handler func(data []byte)) error {
+	sub, err := b.conn.Subscribe(SubjectBroadcast(topic), func(msg *nats.Msg) {
+		b.deliver(msg, handler)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to broadcasts of %s: %w", topic, err)
+	}
+
+	b.mu.Lock()
+	b.subs = append(b.subs, sub)
+	b.mu.Unlock()
+	return nil
+}
+
+// Close stops the subscriptions
+func (b *Broadcaster) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, sub := range b.subs {
+		sub.Unsubscribe()
+	}
+	b.subs = nil
+}
+
+// deliver hands a broadcast to handler unless this instance sent it. Malformed
+// broadcasts are dropped; nothing redelivers them.
+func (b *Broadcaster) deliver(msg *nats.Msg, handler func(data []byte)) {
+	var envelope broadcastEnvelope
+	if err := json.Unmarshal(msg.Data, &envelope); err != nil {
+		return
+	}
+	if envelope.Origin == b.instanceID {
+		return
+	}
+	handler(envelope.Data)
+}
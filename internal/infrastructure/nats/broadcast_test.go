@@ -0,0 +1,32 @@
+package nats
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroadcaster_DeliverSkipsOwnBroadcasts(t *testing.T) {
+	b := &Broadcaster{instanceID: "instance-1"}
+	var received [][]byte
+	handler := func(data []byte) { received = append(received, data) }
+
+	own, err := json.Marshal(&broadcastEnvelope{Origin: "instance-1", Data: []byte(`{"n":1}`)})
+	require.NoError(t, err)
+	remote, err := json.Marshal(&broadcastEnvelope{Origin: "instance-2", Data: []byte(`{"n":2}`)})
+	require.NoError(t, err)
+
+	b.deliver(&nats.Msg{Subject: SubjectBroadcast(BroadcastTopicAgentHub), Data: own}, handler)
+	b.deliver(&nats.Msg{Subject: SubjectBroadcast(BroadcastTopicAgentHub), Data: []byte("not json")}, handler)
+	b.deliver(&nats.Msg{Subject: SubjectBroadcast(BroadcastTopicAgentHub), Data: remote}, handler)
+
+	require.Len(t, received, 1)
+	assert.JSONEq(t, `{"n":2}`, string(received[0]))
+}
+
+func TestSubjectBroadcast(t *testing.T) {
+	assert.Equal(t, "linktor.broadcast.agent-hub", SubjectBroadcast(BroadcastTopicAgentHub))
+}
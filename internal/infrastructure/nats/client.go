@@ -10,16 +10,27 @@ import (
 	"github.com/msgfy/linktor/internal/infrastructure/config"
 )
 
-// Client wraps a NATS connection with JetStream support
+// Client wraps a NATS connection with JetStream support. It keeps connecting while
+// NATS is down, at startup too, and sets the streams and consumers up again whenever
+// it reconnects.
 type Client struct {
 	conn      *nats.Conn
 	js        jetstream.JetStream
 	clientID  string
 	clusterID string
+	state     *connectionState
 }
 
-// NewClient creates a new NATS client with JetStream
+// NewClient creates a new NATS client with JetStream. When NATS cannot be reached the
+// client is returned anyway and connects in the background: publishes are held
+// briefly and subscriptions start once it is connected.
 func NewClient(cfg *config.NATSConfig) (*Client, error) {
+	client := &Client{
+		clientID:  cfg.ClientID,
+		clusterID: cfg.ClusterID,
+		state:     newConnectionState(),
+	}
+
 	// Connect to NATS
 	opts := []nats.Option{
 		nats.Name(cfg.ClientID),
@@ -28,6 +39,10 @@ func NewClient(cfg *config.NATSConfig) (*Client, error) {
 		nats.PingInterval(20 * time.Second),
 		nats.MaxPingsOutstanding(5),
 		nats.ReconnectBufSize(5 * 1024 * 1024), // 5MB buffer
+		nats.RetryOnFailedConnect(true),
+		nats.ReconnectHandler(func(*nats.Conn) { client.state.connected() }),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) { client.state.disconnected(err) }),
+		nats.ClosedHandler(func(*nats.Conn) { client.state.closed() }),
 	}
 
 	conn, err := nats.Connect(cfg.URL, opts...)
@@ -41,19 +56,17 @@ func NewClient(cfg *config.NATSConfig) (*Client, error) {
 		conn.Close()
 		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
 	}
-
-	client := &Client{
-		conn:      conn,
-		js:        js,
-		clientID:  cfg.ClientID,
-		clusterID: cfg.ClusterID,
-	}
-
-	// Initialize streams
-	if err := client.initializeStreams(context.Background()); err != nil {
-		conn.Close()
-		return nil, fmt.Errorf("failed to initialize streams: %w", err)
+	client.conn = conn
+	client.js = js
+
+	// Initialize streams now when connected, otherwise once connected
+	if conn.IsConnected() {
+		client.state.connected()
+		ctx, cancel := context.WithTimeout(context.Background(), setupTimeout)
+		client.setUp(ctx)
+		cancel()
 	}
+	go client.maintain()
 
 	return client, nil
 }
@@ -64,6 +77,7 @@ func (c *Client) Close() {
 		c.conn.Drain()
 		c.conn.Close()
 	}
+	c.state.closed()
 }
 
 // Conn returns the underlying NATS connection
@@ -135,10 +149,26 @@ func (c *Client) initializeStreams(ctx context.Context) error {
 			Storage:      jetstream.FileStorage,
 			Replicas:     1,
 		},
+		{
+			Name:        StreamDeadLetter,
+			Description: "Linktor messages that exhausted their deliveries, kept for inspection and replay",
+			Subjects: []string{
+				SubjectDeadLetterAll,
+			},
+			Retention:    jetstream.LimitsPolicy,
+			MaxConsumers: -1,
+			MaxMsgs:      -1,
+			MaxBytes:     256 * 1024 * 1024,   // 256MB
+			MaxAge:       deadLetterRetention, // 14 days
+			MaxMsgSize:   4 * 1024 * 1024,     // 4MB per message, as the messages stream
+			Discard:      jetstream.DiscardOld,
+			Storage:      jetstream.FileStorage,
+			Replicas:     1,
+		},
 	}
 
 	for _, streamCfg := range streams {
-		_, err := c.js.CreateOrUpdateStream(ctx, streamCfg)
+		err := c.ensureStream(ctx, streamCfg)
 		if err != nil {
 			return fmt.Errorf("failed to create stream %s: %w", streamCfg.Name, err)
 		}
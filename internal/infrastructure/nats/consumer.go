@@ -24,21 +24,92 @@ type EventHandler func(ctx context.Context, event *Event) error
 // WebhookHandler is a function that handles webhook deliveries
 type WebhookHandler func(ctx context.Context, webhook *WebhookDelivery) error
 
+// Webhook delivery retry settings
+const (
+	WebhookMaxDeliver     = 10
+	WebhookRetryBaseDelay = 10 * time.Second
+	WebhookRetryMaxDelay  = 30 * time.Minute
+)
+
+// WebhookRetryDelay returns the exponential backoff before redelivering a webhook that
+// failed on its given delivery
+func WebhookRetryDelay(delivery int) time.Duration {
+	delay := WebhookRetryBaseDelay
+	for i := 1; i < delivery; i++ {
+		delay *= 2
+		if delay >= WebhookRetryMaxDelay {
+			return WebhookRetryMaxDelay
+		}
+	}
+	return delay
+}
+
+// Email delivery retry settings. Mail servers defer messages with 4xx replies for
+// greylisting, rate limits or full queues, often for longer than other providers.
+const (
+	EmailMaxDeliver     = 10
+	EmailRetryBaseDelay = 1 * time.Minute
+	EmailRetryMaxDelay  = 2 * time.Hour
+)
+
+// EmailRetryDelay returns the exponential backoff before redelivering an email that
+// was deferred on its given delivery
+func EmailRetryDelay(delivery int) time.Duration {
+	delay := EmailRetryBaseDelay
+	for i := 1; i < delivery; i++ {
+		delay *= 2
+		if delay >= EmailRetryMaxDelay {
+			return EmailRetryMaxDelay
+		}
+	}
+	return delay
+}
+
+// OutboundDeliveryHandler handles an outbound message on its given delivery, starting at 1
+type OutboundDeliveryHandler func(ctx context.Context, msg *OutboundMessage, delivery int) error
+
 // Consumer consumes messages from NATS JetStream
 type Consumer struct {
-	client     *Client
-	consumers  []jetstream.Consumer
-	cancelFunc context.CancelFunc
+	client      *Client
+	consumers   []jetstream.Consumer
+	cancelFunc  context.CancelFunc
+	concurrency int
+	paused      func() bool
+	locker      KeyLocker
 }
 
 // NewConsumer creates a new message consumer
 func NewConsumer(client *Client) *Consumer {
 	return &Consumer{
-		client:    client,
-		consumers: make([]jetstream.Consumer, 0),
+		client:      client,
+		consumers:   make([]jetstream.Consumer, 0),
+		concurrency: DefaultConsumerConcurrency,
+	}
+}
+
+// SetConcurrency sets how many messages of each subscription are handled in parallel.
+// Messages of the same conversation are still handled one at a time, in order.
+// It applies to subscriptions made afterwards.
+func (c *Consumer) SetConcurrency(concurrency int) {
+	if concurrency > 0 {
+		c.concurrency = concurrency
 	}
 }
 
+// SetPause sets a check consulted before each fetch; nothing is fetched while it
+// reports true, so messages wait in the stream. It applies to subscriptions made
+// afterwards.
+func (c *Consumer) SetPause(paused func() bool) {
+	c.paused = paused
+}
+
+// SetOrderingLock locks the ordering key of each message with locker while it is
+// handled, so messages of a conversation are handled by one server instance at a time
+// when several share the consumers. It applies to subscriptions made afterwards.
+func (c *Consumer) SetOrderingLock(locker KeyLocker) {
+	c.locker = locker
+}
+
 // ConsumerConfig holds configuration for a consumer
 type ConsumerConfig struct {
 	Stream       string
@@ -47,6 +118,12 @@ type ConsumerConfig struct {
 	MaxDeliver   int
 	AckWait      time.Duration
 	MaxAckPending int
+	// OrderingKey extracts the key whose messages must be handled in order, usually
+	// the conversation ID. Messages without a key may be handled in any order.
+	OrderingKey func(data []byte) string
+	// RetryDelay returns the delay before redelivering a message that failed on its
+	// given delivery; failed messages are redelivered after 5 seconds when nil
+	RetryDelay func(delivery int) time.Duration
 }
 
 // SubscribeInbound subscribes to inbound messages for a specific channel type
@@ -58,6 +135,7 @@ func (c *Consumer) SubscribeInbound(ctx context.Context, channelType string, han
 		MaxDeliver:    5,
 		AckWait:       30 * time.Second,
 		MaxAckPending: 100,
+		OrderingKey:   orderingKey[InboundMessage],
 	}
 
 	return c.subscribe(ctx, cfg, func(msg jetstream.Msg) error {
@@ -78,6 +156,7 @@ func (c *Consumer) SubscribeAllInbound(ctx context.Context, handler MessageHandl
 		MaxDeliver:    5,
 		AckWait:       30 * time.Second,
 		MaxAckPending: 100,
+		OrderingKey:   orderingKey[InboundMessage],
 	}
 
 	return c.subscribe(ctx, cfg, func(msg jetstream.Msg) error {
@@ -98,6 +177,7 @@ func (c *Consumer) SubscribeOutbound(ctx context.Context, channelType string, ha
 		MaxDeliver:    5,
 		AckWait:       60 * time.Second,
 		MaxAckPending: 50,
+		OrderingKey:   orderingKey[OutboundMessage],
 	}
 
 	return c.subscribe(ctx, cfg, func(msg jetstream.Msg) error {
@@ -109,6 +189,34 @@ func (c *Consumer) SubscribeOutbound(ctx context.Context, channelType string, ha
 	})
 }
 
+// SubscribeEmailOutbound subscribes to outbound email messages. Returning an error
+// redelivers the message after an exponential backoff, up to EmailMaxDeliver times,
+// so the handler can give up on the last delivery.
+func (c *Consumer) SubscribeEmailOutbound(ctx context.Context, handler OutboundDeliveryHandler) error {
+	cfg := ConsumerConfig{
+		Stream:        StreamMessages,
+		Name:          ConsumerOutbound("email"),
+		FilterSubject: SubjectOutbound("email"),
+		MaxDeliver:    EmailMaxDeliver,
+		AckWait:       2 * time.Minute,
+		MaxAckPending: 100,
+		OrderingKey:   orderingKey[OutboundMessage],
+		RetryDelay:    EmailRetryDelay,
+	}
+
+	return c.subscribe(ctx, cfg, func(msg jetstream.Msg) error {
+		var outbound OutboundMessage
+		if err := json.Unmarshal(msg.Data(), &outbound); err != nil {
+			return fmt.Errorf("failed to unmarshal outbound message: %w", err)
+		}
+		delivery := 1
+		if meta, err := msg.Metadata(); err == nil {
+			delivery = int(meta.NumDelivered)
+		}
+		return handler(ctx, &outbound, delivery)
+	})
+}
+
 // SubscribeStatus subscribes to message status updates
 func (c *Consumer) SubscribeStatus(ctx context.Context, handler StatusHandler) error {
 	cfg := ConsumerConfig{
@@ -118,6 +226,7 @@ func (c *Consumer) SubscribeStatus(ctx context.Context, handler StatusHandler) e
 		MaxDeliver:    3,
 		AckWait:       10 * time.Second,
 		MaxAckPending: 200,
+		OrderingKey:   orderingKey[StatusUpdate],
 	}
 
 	return c.subscribe(ctx, cfg, func(msg jetstream.Msg) error {
@@ -149,15 +258,17 @@ func (c *Consumer) SubscribeEvents(ctx context.Context, handler EventHandler) er
 	})
 }
 
-// SubscribeWebhooks subscribes to webhook delivery requests
+// SubscribeWebhooks subscribes to webhook delivery requests. Returning an error
+// redelivers the request after an exponential backoff.
 func (c *Consumer) SubscribeWebhooks(ctx context.Context, handler WebhookHandler) error {
 	cfg := ConsumerConfig{
 		Stream:        StreamWebhooks,
 		Name:          ConsumerWebhooks,
 		FilterSubject: SubjectWebhooksAll,
-		MaxDeliver:    10,
+		MaxDeliver:    WebhookMaxDeliver,
 		AckWait:       30 * time.Second,
 		MaxAckPending: 50,
+		RetryDelay:    WebhookRetryDelay,
 	}
 
 	return c.subscribe(ctx, cfg, func(msg jetstream.Msg) error {
@@ -169,60 +280,48 @@ func (c *Consumer) SubscribeWebhooks(ctx context.Context, handler WebhookHandler
 	})
 }
 
-// subscribe creates a consumer and starts consuming messages
+// subscribe creates a consumer and starts consuming messages, once NATS is connected
+// when it is not yet. The consumer is durable and named by cfg.Name, so every server
+// instance binds to the same consumer and the instances share its messages like a
+// queue group: each message is handled by one. With an ordering lock, the messages of
+// a conversation are handled by one instance at a time.
 func (c *Consumer) subscribe(ctx context.Context, cfg ConsumerConfig, handler func(jetstream.Msg) error) error {
-	stream, err := c.client.js.Stream(ctx, cfg.Stream)
-	if err != nil {
-		return fmt.Errorf("failed to get stream %s: %w", cfg.Stream, err)
-	}
+	concurrency, paused, locker := c.concurrency, c.paused, c.locker
+	started := false
+	return c.client.setUpOnConnect(ctx, func(setupCtx context.Context) error {
+		stream, err := c.client.js.Stream(setupCtx, cfg.Stream)
+		if err != nil {
+			return fmt.Errorf("failed to get stream %s: %w", cfg.Stream, err)
+		}
 
-	consumerCfg := jetstream.ConsumerConfig{
-		Name:          cfg.Name,
-		Durable:       cfg.Name,
-		FilterSubject: cfg.FilterSubject,
-		AckPolicy:     jetstream.AckExplicitPolicy,
-		MaxDeliver:    cfg.MaxDeliver,
-		AckWait:       cfg.AckWait,
-		MaxAckPending: cfg.MaxAckPending,
-		DeliverPolicy: jetstream.DeliverAllPolicy,
-	}
+		consumerCfg := jetstream.ConsumerConfig{
+			Name:          cfg.Name,
+			Durable:       cfg.Name,
+			FilterSubject: cfg.FilterSubject,
+			AckPolicy:     jetstream.AckExplicitPolicy,
+			MaxDeliver:    cfg.MaxDeliver,
+			AckWait:       cfg.AckWait,
+			MaxAckPending: cfg.MaxAckPending,
+			DeliverPolicy: jetstream.DeliverAllPolicy,
+		}
 
-	consumer, err := stream.CreateOrUpdateConsumer(ctx, consumerCfg)
-	if err != nil {
-		return fmt.Errorf("failed to create consumer %s: %w", cfg.Name, err)
-	}
+		consumer, err := stream.CreateOrUpdateConsumer(setupCtx, consumerCfg)
+		if err != nil {
+			return fmt.Errorf("failed to create consumer %s: %w", cfg.Name, err)
+		}
 
-	c.consumers = append(c.consumers, consumer)
-
-	// Start consuming in a goroutine
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			default:
-				msgs, err := consumer.Fetch(10, jetstream.FetchMaxWait(5*time.Second))
-				if err != nil {
-					if err == context.Canceled || err == context.DeadlineExceeded {
-						continue
-					}
-					time.Sleep(1 * time.Second)
-					continue
-				}
-
-				for msg := range msgs.Messages() {
-					if err := handler(msg); err != nil {
-						// NAK with delay for retry
-						msg.NakWithDelay(5 * time.Second)
-					} else {
-						msg.Ack()
-					}
-				}
-			}
+		// After a reconnect the consumer only needs recreating; fetching resumes on its own
+		if started {
+			return nil
 		}
-	}()
+		started = true
+		c.consumers = append(c.consumers, consumer)
 
-	return nil
+		// Start consuming in a goroutine
+		go consume(ctx, consumer, concurrency, paused, cfg.OrderingKey, newKeyLock(locker, cfg.Name), cfg.RetryDelay, c.client.deadLetterer(cfg.Name, cfg.MaxDeliver), handler)
+
+		return nil
+	})
 }
 
 // Stop stops all consumers
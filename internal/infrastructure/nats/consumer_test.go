@@ -0,0 +1,39 @@
+package nats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookRetryDelay(t *testing.T) {
+	tests := []struct {
+		delivery int
+		expected time.Duration
+	}{
+		{1, 10 * time.Second},
+		{2, 20 * time.Second},
+		{5, 160 * time.Second},
+		{9, WebhookRetryMaxDelay},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, WebhookRetryDelay(tt.delivery), "delivery %d", tt.delivery)
+	}
+}
+
+func TestEmailRetryDelay(t *testing.T) {
+	tests := []struct {
+		delivery int
+		expected time.Duration
+	}{
+		{1, 1 * time.Minute},
+		{2, 2 * time.Minute},
+		{7, 64 * time.Minute},
+		{8, EmailRetryMaxDelay},
+		{9, EmailRetryMaxDelay},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, EmailRetryDelay(tt.delivery), "delivery %d", tt.delivery)
+	}
+}
@@ -0,0 +1,209 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Dead letters are kept for two weeks
+const deadLetterRetention = 14 * 24 * time.Hour
+
+// deadLetterMaxError caps the error kept on a dead letter
+const deadLetterMaxError = 1024
+
+// Headers describing where a dead letter comes from; they are dropped on replay
+const (
+	deadLetterHeaderPrefix     = "Linktor-Dead-Letter-"
+	DeadLetterStreamHeader     = "Linktor-Dead-Letter-Stream"
+	DeadLetterSubjectHeader    = "Linktor-Dead-Letter-Subject"
+	DeadLetterSequenceHeader   = "Linktor-Dead-Letter-Sequence"
+	DeadLetterConsumerHeader   = "Linktor-Dead-Letter-Consumer"
+	DeadLetterDeliveriesHeader = "Linktor-Dead-Letter-Deliveries"
+	DeadLetterErrorHeader      = "Linktor-Dead-Letter-Error"
+)
+
+// ErrDeadLetterNotFound is returned for dead letters that do not exist or belong to
+// another stream
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// deadLetterer returns the function a consumer calls when its handler fails. On the
+// last delivery it copies the message to the dead-letter stream and terminates it,
+// reporting true; otherwise, or when the copy fails, the message is left to be
+// nacked as usual.
+func (c *Client) deadLetterer(consumer string, maxDeliver int) func(msg jetstream.Msg, cause error) bool {
+	return func(msg jetstream.Msg, cause error) bool {
+		meta, err := msg.Metadata()
+		if err != nil || maxDeliver <= 0 || int(meta.NumDelivered) < maxDeliver {
+			return false
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := c.js.PublishMsg(ctx, deadLetterMsg(msg, meta, consumer, cause)); err != nil {
+			return false
+		}
+		msg.Term()
+		return true
+	}
+}
+
+// deadLetterMsg returns the copy of a failed message published to the dead-letter stream
+func deadLetterMsg(msg jetstream.Msg, meta *jetstream.MsgMetadata, consumer string, cause error) *nats.Msg {
+	dead := &nats.Msg{
+		Subject: SubjectDeadLetter(meta.Stream, msg.Subject()),
+		Data:    msg.Data(),
+		Header:  nats.Header{},
+	}
+	for key, values := range msg.Headers() {
+		if key != nats.MsgIdHdr {
+			dead.Header[key] = values
+		}
+	}
+	reason := ""
+	if cause != nil {
+		reason = cause.Error()
+		if len(reason) > deadLetterMaxError {
+			reason = reason[:deadLetterMaxError]
+		}
+	}
+	dead.Header.Set(DeadLetterStreamHeader, meta.Stream)
+	dead.Header.Set(DeadLetterSubjectHeader, msg.Subject())
+	dead.Header.Set(DeadLetterSequenceHeader, strconv.FormatUint(meta.Sequence.Stream, 10))
+	dead.Header.Set(DeadLetterConsumerHeader, consumer)
+	dead.Header.Set(DeadLetterDeliveriesHeader, strconv.FormatUint(meta.NumDelivered, 10))
+	dead.Header.Set(DeadLetterErrorHeader, reason)
+	return dead
+}
+
+// DeadLetters lists up to limit dead letters of a stream with a sequence above after,
+// oldest first, without their payloads
+func (m *Monitor) DeadLetters(ctx context.Context, streamName string, after uint64, limit int) ([]*entity.DeadLetter, error) {
+	stream, err := m.client.JetStream().Stream(ctx, StreamDeadLetter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stream %s: %w", StreamDeadLetter, err)
+	}
+
+	subject := SubjectDeadLetter(streamName, ">")
+	letters := make([]*entity.DeadLetter, 0)
+	for seq := after + 1; len(letters) < limit; {
+		msg, err := stream.GetMsg(ctx, seq, jetstream.WithGetMsgSubject(subject))
+		if errors.Is(err, jetstream.ErrMsgNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read dead letter %d: %w", seq, err)
+		}
+		letters = append(letters, deadLetter(msg, false))
+		seq = msg.Sequence + 1
+	}
+	return letters, nil
+}
+
+// DeadLetter returns a dead letter of a stream with its headers and payload
+func (m *Monitor) DeadLetter(ctx context.Context, streamName string, seq uint64) (*entity.DeadLetter, error) {
+	msg, err := m.deadLetterMsg(ctx, streamName, seq)
+	if err != nil {
+		return nil, err
+	}
+	return deadLetter(msg, true), nil
+}
+
+// ReplayDeadLetter publishes a dead letter of a stream again on its original subject,
+// where its consumer picks it up with a fresh delivery count, and removes it from the
+// dead-letter stream
+func (m *Monitor) ReplayDeadLetter(ctx context.Context, streamName string, seq uint64) (*entity.DeadLetter, error) {
+	msg, err := m.deadLetterMsg(ctx, streamName, seq)
+	if err != nil {
+		return nil, err
+	}
+	letter := deadLetter(msg, false)
+
+	replayed := &nats.Msg{
+		Subject: letter.Subject,
+		Data:    msg.Data,
+		Header:  nats.Header{},
+	}
+	for key, values := range msg.Header {
+		if !strings.HasPrefix(key, deadLetterHeaderPrefix) {
+			replayed.Header[key] = values
+		}
+	}
+	js := m.client.JetStream()
+	if _, err := js.PublishMsg(ctx, replayed); err != nil {
+		return nil, fmt.Errorf("failed to replay dead letter %d: %w", seq, err)
+	}
+
+	stream, err := js.Stream(ctx, StreamDeadLetter)
+	if err == nil {
+		err = stream.DeleteMsg(ctx, seq)
+	}
+	if err != nil {
+		return letter, fmt.Errorf("replayed dead letter %d but failed to remove it: %w", seq, err)
+	}
+	return letter, nil
+}
+
+// deadLetterMsg reads a dead letter, making sure it belongs to the stream
+func (m *Monitor) deadLetterMsg(ctx context.Context, streamName string, seq uint64) (*jetstream.RawStreamMsg, error) {
+	stream, err := m.client.JetStream().Stream(ctx, StreamDeadLetter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stream %s: %w", StreamDeadLetter, err)
+	}
+	msg, err := stream.GetMsg(ctx, seq)
+	if errors.Is(err, jetstream.ErrMsgNotFound) {
+		return nil, ErrDeadLetterNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dead letter %d: %w", seq, err)
+	}
+	if msg.Header.Get(DeadLetterStreamHeader) != streamName {
+		return nil, ErrDeadLetterNotFound
+	}
+	return msg, nil
+}
+
+// deadLetter converts a message of the dead-letter stream, with its headers and
+// payload when inspected
+func deadLetter(msg *jetstream.RawStreamMsg, inspect bool) *entity.DeadLetter {
+	letter := &entity.DeadLetter{
+		Sequence: msg.Sequence,
+		Stream:   msg.Header.Get(DeadLetterStreamHeader),
+		Subject:  msg.Header.Get(DeadLetterSubjectHeader),
+		Consumer: msg.Header.Get(DeadLetterConsumerHeader),
+		Error:    msg.Header.Get(DeadLetterErrorHeader),
+		FailedAt: msg.Time,
+		Size:     len(msg.Data),
+	}
+	for name, streamName := range TunableStreams {
+		if streamName == letter.Stream {
+			letter.Stream = name
+		}
+	}
+	letter.OriginalSequence, _ = strconv.ParseUint(msg.Header.Get(DeadLetterSequenceHeader), 10, 64)
+	letter.Deliveries, _ = strconv.Atoi(msg.Header.Get(DeadLetterDeliveriesHeader))
+	if !inspect {
+		return letter
+	}
+
+	letter.Headers = make(map[string]string)
+	for key := range msg.Header {
+		if !strings.HasPrefix(key, deadLetterHeaderPrefix) {
+			letter.Headers[key] = msg.Header.Get(key)
+		}
+	}
+	if json.Valid(msg.Data) {
+		letter.Payload = json.RawMessage(msg.Data)
+	} else {
+		letter.RawPayload = msg.Data
+	}
+	return letter
+}
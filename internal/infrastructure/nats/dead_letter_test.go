@@ -0,0 +1,65 @@
+package nats
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failedMsg is a delivered message; only what dead-lettering reads is implemented
+type failedMsg struct {
+	jetstream.Msg
+	subject string
+	data    []byte
+	header  nats.Header
+}
+
+func (m *failedMsg) Subject() string      { return m.subject }
+func (m *failedMsg) Data() []byte         { return m.data }
+func (m *failedMsg) Headers() nats.Header { return m.header }
+
+func TestDeadLetterMsg(t *testing.T) {
+	msg := &failedMsg{
+		subject: "linktor.webhooks.tenant-1",
+		data:    []byte(`{"url":"https://example.com/hook"}`),
+		header:  nats.Header{nats.MsgIdHdr: {"delivery-1"}, "Trace-Id": {"abc"}},
+	}
+	meta := &jetstream.MsgMetadata{
+		Stream:       StreamWebhooks,
+		Sequence:     jetstream.SequencePair{Stream: 42},
+		NumDelivered: WebhookMaxDeliver,
+	}
+
+	dead := deadLetterMsg(msg, meta, ConsumerWebhooks, errors.New("endpoint returned 500: "+strings.Repeat("x", 2000)))
+	assert.Equal(t, "linktor.deadletter.LINKTOR_WEBHOOKS.linktor.webhooks.tenant-1", dead.Subject)
+	assert.Empty(t, dead.Header.Get(nats.MsgIdHdr), "replays must not be dropped as duplicates")
+	assert.Equal(t, "abc", dead.Header.Get("Trace-Id"))
+	assert.Len(t, dead.Header.Get(DeadLetterErrorHeader), deadLetterMaxError)
+
+	failedAt := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	stored := &jetstream.RawStreamMsg{Subject: dead.Subject, Sequence: 7, Header: dead.Header, Data: dead.Data, Time: failedAt}
+
+	letter := deadLetter(stored, false)
+	assert.Equal(t, uint64(7), letter.Sequence)
+	assert.Equal(t, "webhooks", letter.Stream)
+	assert.Equal(t, "linktor.webhooks.tenant-1", letter.Subject)
+	assert.Equal(t, uint64(42), letter.OriginalSequence)
+	assert.Equal(t, ConsumerWebhooks, letter.Consumer)
+	assert.Equal(t, WebhookMaxDeliver, letter.Deliveries)
+	assert.Equal(t, failedAt, letter.FailedAt)
+	assert.Nil(t, letter.Payload, "payloads are only returned when inspecting")
+
+	inspected := deadLetter(stored, true)
+	require.NotNil(t, inspected.Payload)
+	assert.JSONEq(t, `{"url":"https://example.com/hook"}`, string(inspected.Payload))
+	assert.Equal(t, map[string]string{"Trace-Id": "abc"}, inspected.Headers)
+
+	stored.Data = []byte("not json")
+	assert.Equal(t, []byte("not json"), deadLetter(stored, true).RawPayload)
+}
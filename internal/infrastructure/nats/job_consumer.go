@@ -0,0 +1,213 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// JobRequest asks a worker to run a persisted job
+type JobRequest struct {
+	JobID     string    `json:"job_id"`
+	TenantID  string    `json:"tenant_id"`
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// JobHandler handles job requests. Returning an error redelivers the request
+// after a backoff.
+type JobHandler func(ctx context.Context, req *JobRequest) error
+
+// Job worker settings
+const (
+	JobMaxDeliver     = 10
+	JobAckWait        = 2 * time.Minute
+	JobRetryBaseDelay = 5 * time.Second
+	JobRetryMaxDelay  = 10 * time.Minute
+)
+
+// JobConsumer consumes background jobs from NATS JetStream
+type JobConsumer struct {
+	client      *Client
+	concurrency int
+}
+
+// NewJobConsumer creates a new job consumer running up to concurrency jobs at once
+func NewJobConsumer(client *Client, concurrency int) *JobConsumer {
+	if concurrency <= 0 {
+		concurrency = 4
+	}
+	return &JobConsumer{
+		client:      client,
+		concurrency: concurrency,
+	}
+}
+
+// EnsureStream ensures the jobs stream exists, once NATS is connected when it is not yet
+func (c *JobConsumer) EnsureStream(ctx context.Context) error {
+	streamCfg := jetstream.StreamConfig{
+		Name:        StreamJobs,
+		Description: "Linktor background jobs stream",
+		Subjects: []string{
+			SubjectJobsAll,
+		},
+		Retention:    jetstream.WorkQueuePolicy,
+		MaxConsumers: -1,
+		MaxMsgs:      -1,
+		MaxBytes:     -1,
+		MaxAge:       7 * 24 * time.Hour,
+		Storage:      jetstream.FileStorage,
+		Replicas:     1,
+		Duplicates:   5 * time.Minute,
+	}
+
+	err := c.client.setUpOnConnect(ctx, func(ctx context.Context) error {
+		return c.client.ensureStream(ctx, streamCfg)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create jobs stream: %w", err)
+	}
+
+	return nil
+}
+
+// SubscribeJobs runs handler for every job request until ctx is cancelled.
+// Requests run concurrently and are kept in progress while handler runs, so
+// jobs may take longer than the ack wait.
+func (c *JobConsumer) SubscribeJobs(ctx context.Context, handler JobHandler) error {
+	started := false
+	return c.client.setUpOnConnect(ctx, func(setupCtx context.Context) error {
+		stream, err := c.client.js.Stream(setupCtx, StreamJobs)
+		if err != nil {
+			return fmt.Errorf("failed to get stream %s: %w", StreamJobs, err)
+		}
+
+		consumer, err := stream.CreateOrUpdateConsumer(setupCtx, jetstream.ConsumerConfig{
+			Name:          ConsumerJobs,
+			Durable:       ConsumerJobs,
+			FilterSubject: SubjectJobsAll,
+			AckPolicy:     jetstream.AckExplicitPolicy,
+			MaxDeliver:    JobMaxDeliver,
+			AckWait:       JobAckWait,
+			MaxAckPending: c.concurrency * 2,
+			DeliverPolicy: jetstream.DeliverAllPolicy,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create consumer %s: %w", ConsumerJobs, err)
+		}
+
+		if started {
+			return nil
+		}
+		started = true
+		go c.work(ctx, consumer, handler)
+		return nil
+	})
+}
+
+// work fetches job requests until ctx is cancelled, running up to concurrency at once
+func (c *JobConsumer) work(ctx context.Context, consumer jetstream.Consumer, handler JobHandler) {
+	slots := make(chan struct{}, c.concurrency)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case slots <- struct{}{}:
+		}
+
+		msgs, err := consumer.Fetch(1, jetstream.FetchMaxWait(5*time.Second))
+		if err != nil {
+			<-slots
+			if err != context.Canceled && err != context.DeadlineExceeded {
+				time.Sleep(1 * time.Second)
+			}
+			continue
+		}
+
+		received := false
+		for msg := range msgs.Messages() {
+			received = true
+			go func(msg jetstream.Msg) {
+				defer func() { <-slots }()
+				c.handle(ctx, msg, handler)
+			}(msg)
+		}
+		if !received {
+			<-slots
+		}
+	}
+}
+
+func (c *JobConsumer) handle(ctx context.Context, msg jetstream.Msg, handler JobHandler) {
+	var req JobRequest
+	if err := json.Unmarshal(msg.Data(), &req); err != nil {
+		// A malformed request never succeeds
+		msg.Term()
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(JobAckWait / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				msg.InProgress()
+			}
+		}
+	}()
+
+	err := handler(ctx, &req)
+	close(done)
+	if err == nil {
+		msg.Ack()
+		return
+	}
+	if c.client.deadLetterer(ConsumerJobs, JobMaxDeliver)(msg, err) {
+		return
+	}
+
+	delay := JobRetryBaseDelay
+	if meta, metaErr := msg.Metadata(); metaErr == nil {
+		delay = JobRetryDelay(int(meta.NumDelivered))
+	}
+	msg.NakWithDelay(delay)
+}
+
+// JobRetryDelay returns the exponential backoff before redelivering a job
+// request that failed on its given delivery
+func JobRetryDelay(delivery int) time.Duration {
+	delay := JobRetryBaseDelay
+	for i := 1; i < delivery; i++ {
+		delay *= 2
+		if delay >= JobRetryMaxDelay {
+			return JobRetryMaxDelay
+		}
+	}
+	return delay
+}
+
+// PublishJob publishes a job request
+func (p *Producer) PublishJob(ctx context.Context, req *JobRequest) error {
+	if req.Timestamp.IsZero() {
+		req.Timestamp = time.Now()
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job request: %w", err)
+	}
+
+	err = p.client.publish(ctx, SubjectJob(req.Type), data)
+	if err != nil {
+		return fmt.Errorf("failed to publish job request: %w", err)
+	}
+
+	return nil
+}
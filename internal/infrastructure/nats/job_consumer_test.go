@@ -0,0 +1,23 @@
+package nats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobRetryDelay(t *testing.T) {
+	tests := []struct {
+		delivery int
+		expected time.Duration
+	}{
+		{1, 5 * time.Second},
+		{2, 10 * time.Second},
+		{4, 40 * time.Second},
+		{10, JobRetryMaxDelay},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, JobRetryDelay(tt.delivery), "delivery %d", tt.delivery)
+	}
+}
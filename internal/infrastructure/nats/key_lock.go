@@ -0,0 +1,94 @@
+package nats
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// Ordering lock settings
+const (
+	orderingLockTTL      = 30 * time.Second // Extended while the message is handled
+	orderingLockRetry    = 100 * time.Millisecond
+	orderingLockProgress = 5 * time.Second // Below the shortest AckWait of the consumers
+	orderingLockPrefix   = "ordering:"
+)
+
+// KeyLocker hands out locks shared by the server instances, such as lock.RedisLocker
+type KeyLocker interface {
+	Acquire(ctx context.Context, name, owner string, ttl time.Duration) (bool, error)
+	Release(ctx context.Context, name, owner string) error
+}
+
+// keyLock serializes messages sharing an ordering key across server instances. The
+// workers of one instance keep a key on a single worker; as every instance binds to
+// the same durable consumer, the lock keeps another instance from handling a message
+// of the key at the same time.
+type keyLock struct {
+	locker KeyLocker
+	prefix string
+	owner  string
+	ttl    time.Duration
+	retry  time.Duration
+}
+
+// newKeyLock creates the ordering lock of a consumer, or nil without a locker
+func newKeyLock(locker KeyLocker, consumer string) *keyLock {
+	if locker == nil {
+		return nil
+	}
+	return &keyLock{
+		locker: locker,
+		prefix: orderingLockPrefix + consumer + ":",
+		owner:  uuid.New().String(),
+		ttl:    orderingLockTTL,
+		retry:  orderingLockRetry,
+	}
+}
+
+// hold takes the lock of key, waiting while another instance holds it. The message is
+// marked in progress meanwhile, so it isn't redelivered, and the lock is extended until
+// release is called. It reports false when ctx is done before the lock was taken. When
+// the locker fails the message is handled without the lock rather than held up.
+func (l *keyLock) hold(ctx context.Context, msg jetstream.Msg, key string) (release func(), ok bool) {
+	name := l.prefix + key
+	progressed := time.Now()
+	for {
+		held, err := l.locker.Acquire(ctx, name, l.owner, l.ttl)
+		if err != nil {
+			return func() {}, ctx.Err() == nil
+		}
+		if held {
+			break
+		}
+		if time.Since(progressed) >= orderingLockProgress {
+			msg.InProgress()
+			progressed = time.Now()
+		}
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-time.After(l.retry):
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(l.ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				l.locker.Acquire(context.Background(), name, l.owner, l.ttl)
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		l.locker.Release(context.Background(), name, l.owner)
+	}, true
+}
@@ -0,0 +1,171 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sharedQueue is a durable consumer bound by several instances; each fetch takes the
+// next messages, whichever instance makes it
+type sharedQueue struct {
+	mu   sync.Mutex
+	msgs []jetstream.Msg
+}
+
+type queueConsumer struct {
+	jetstream.Consumer
+	queue *sharedQueue
+}
+
+func (c *queueConsumer) Fetch(batch int, opts ...jetstream.FetchOpt) (jetstream.MessageBatch, error) {
+	c.queue.mu.Lock()
+	taken := c.queue.msgs[:min(batch, len(c.queue.msgs))]
+	c.queue.msgs = c.queue.msgs[len(taken):]
+	c.queue.mu.Unlock()
+
+	msgs := make(chan jetstream.Msg, len(taken))
+	for _, msg := range taken {
+		msgs <- msg
+	}
+	close(msgs)
+	if len(taken) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	return &fetchedBatch{msgs: msgs}, nil
+}
+
+type fetchedBatch struct {
+	jetstream.MessageBatch
+	msgs chan jetstream.Msg
+}
+
+func (b *fetchedBatch) Messages() <-chan jetstream.Msg { return b.msgs }
+
+// queuedMsg records how it was acknowledged
+type queuedMsg struct {
+	jetstream.Msg
+	data  []byte
+	acked func()
+	naks  *atomic.Int32
+}
+
+func (m *queuedMsg) Data() []byte { return m.data }
+func (m *queuedMsg) Ack() error   { m.acked(); return nil }
+func (m *queuedMsg) Nak() error   { m.naks.Add(1); return nil }
+func (m *queuedMsg) NakWithDelay(time.Duration) error {
+	m.naks.Add(1)
+	return nil
+}
+func (m *queuedMsg) InProgress() error { return nil }
+
+// memoryLocker is a KeyLocker shared by the instances of a test
+type memoryLocker struct {
+	mu     sync.Mutex
+	owners map[string]string
+}
+
+func (l *memoryLocker) Acquire(ctx context.Context, name, owner string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if current, ok := l.owners[name]; ok && current != owner {
+		return false, nil
+	}
+	l.owners[name] = owner
+	return true, nil
+}
+
+func (l *memoryLocker) Release(ctx context.Context, name, owner string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.owners[name] == owner {
+		delete(l.owners, name)
+	}
+	return nil
+}
+
+func TestConsume_HandlesAKeyOnOneInstanceAtATime(t *testing.T) {
+	const perKey = 20
+	keys := []string{"conv-1", "conv-2", "conv-3"}
+
+	var handled sync.WaitGroup
+	var naks atomic.Int32
+	queue := &sharedQueue{}
+	for i := 0; i < perKey; i++ {
+		for _, key := range keys {
+			handled.Add(1)
+			queue.msgs = append(queue.msgs, &queuedMsg{
+				data:  []byte(fmt.Sprintf(`{"conversation_id":%q,"n":%d}`, key, i)),
+				acked: handled.Done,
+				naks:  &naks,
+			})
+		}
+	}
+
+	var mu sync.Mutex
+	active := map[string]int{}
+	overlaps := 0
+	handler := func(msg jetstream.Msg) error {
+		key := orderingKey[OutboundMessage](msg.Data())
+		mu.Lock()
+		active[key]++
+		if active[key] > 1 {
+			overlaps++
+		}
+		mu.Unlock()
+
+		time.Sleep(time.Millisecond)
+
+		mu.Lock()
+		active[key]--
+		mu.Unlock()
+		return nil
+	}
+
+	// Two instances bound to the same consumer, sharing the Redis locks
+	ctx, cancel := context.WithCancel(context.Background())
+	locker := &memoryLocker{owners: map[string]string{}}
+	var stopped sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		stopped.Add(1)
+		go func() {
+			defer stopped.Done()
+			consume(ctx, &queueConsumer{queue: queue}, 2, nil, orderingKey[OutboundMessage], newKeyLock(locker, ConsumerOutbound("teams")), nil, nil, handler)
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		handled.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("messages were not all handled")
+	}
+	cancel()
+	stopped.Wait()
+
+	assert.Zero(t, overlaps, "a conversation was handled by both instances at once")
+	assert.Zero(t, naks.Load(), "messages waiting for the lock must not be redelivered")
+	require.Empty(t, locker.owners, "locks are released once handled")
+}
+
+func TestKeyLock_GivesUpWhenCancelled(t *testing.T) {
+	locker := &memoryLocker{owners: map[string]string{"ordering:" + ConsumerOutbound("teams") + ":conv-1": "other-instance"}}
+	lock := newKeyLock(locker, ConsumerOutbound("teams"))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, ok := lock.hold(ctx, &queuedMsg{}, "conv-1")
+	assert.False(t, ok)
+	assert.Nil(t, newKeyLock(nil, ConsumerOutbound("teams")))
+}
@@ -0,0 +1,191 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// DefaultConsumerConcurrency is the number of workers per subscription when none is configured
+const DefaultConsumerConcurrency = 4
+
+// orderedWorkers runs tasks on a fixed set of workers. Tasks sharing an ordering key
+// always run on the same worker, one after another, so a conversation's messages are
+// never processed out of order while different conversations proceed in parallel.
+type orderedWorkers struct {
+	queues []chan func()
+	next   uint32
+	wg     sync.WaitGroup
+}
+
+// newOrderedWorkers starts concurrency workers
+func newOrderedWorkers(concurrency int) *orderedWorkers {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	w := &orderedWorkers{queues: make([]chan func(), concurrency)}
+	for i := range w.queues {
+		queue := make(chan func(), 1)
+		w.queues[i] = queue
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			for task := range queue {
+				task()
+			}
+		}()
+	}
+	return w
+}
+
+// dispatch queues a task on the worker owning the key, blocking while that worker
+// is busy. Tasks without a key are spread round robin.
+func (w *orderedWorkers) dispatch(key string, task func()) {
+	w.queues[w.worker(key)] <- task
+}
+
+// worker returns the index of the worker owning the key
+func (w *orderedWorkers) worker(key string) int {
+	if key == "" {
+		return int(atomic.AddUint32(&w.next, 1) % uint32(len(w.queues)))
+	}
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(w.queues)))
+}
+
+// stop lets the workers finish the queued tasks and waits for them
+func (w *orderedWorkers) stop() {
+	for _, queue := range w.queues {
+		close(queue)
+	}
+	w.wg.Wait()
+}
+
+// consume fetches messages until ctx is cancelled and runs handler for each of them on
+// concurrency workers, keeping messages with the same ordering key in sequence. With
+// lock, a key is also handled by one server instance at a time. A failed message is
+// redelivered after a delay, so ordering is best effort on retries, unless deadLetter
+// took it over on its last delivery. Nothing is fetched while paused reports true;
+// messages wait in the stream.
+func consume(ctx context.Context, consumer jetstream.Consumer, concurrency int, paused func() bool, orderingKey func(data []byte) string, lock *keyLock, retryDelay func(delivery int) time.Duration, deadLetter func(jetstream.Msg, error) bool, handler func(jetstream.Msg) error) {
+	workers := newOrderedWorkers(concurrency)
+	defer workers.stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+			if paused != nil && paused() {
+				time.Sleep(1 * time.Second)
+				continue
+			}
+			msgs, err := consumer.Fetch(10, jetstream.FetchMaxWait(5*time.Second))
+			if err != nil {
+				if err == context.Canceled || err == context.DeadlineExceeded {
+					continue
+				}
+				time.Sleep(1 * time.Second)
+				continue
+			}
+
+			for msg := range msgs.Messages() {
+				key := ""
+				if orderingKey != nil {
+					key = orderingKey(msg.Data())
+				}
+				msg := msg
+				workers.dispatch(key, func() {
+					if lock != nil && key != "" {
+						release, ok := lock.hold(ctx, msg, key)
+						if !ok {
+							msg.Nak()
+							return
+						}
+						defer release()
+					}
+					if err := handler(msg); err != nil {
+						if deadLetter != nil && deadLetter(msg, err) {
+							return
+						}
+						// NAK with delay for retry
+						delay := 5 * time.Second
+						if retryDelay != nil {
+							if meta, metaErr := msg.Metadata(); metaErr == nil {
+								delay = retryDelay(int(meta.NumDelivered))
+							}
+						}
+						msg.NakWithDelay(delay)
+					} else {
+						msg.Ack()
+					}
+				})
+			}
+		}
+	}
+}
+
+// orderingKey decodes a payload and returns its ordering key, or "" when it cannot be decoded
+func orderingKey[T any, PT interface {
+	*T
+	OrderingKey() string
+}](data []byte) string {
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return ""
+	}
+	return PT(&v).OrderingKey()
+}
+
+// OrderingKey returns the conversation of the message. Adapters rarely know it, so the
+// sender on the channel is used instead, which is what the conversation is resolved from.
+func (m *InboundMessage) OrderingKey() string {
+	if m.ConversationID != "" {
+		return m.ConversationID
+	}
+	sender := m.ContactID
+	if id := m.Metadata["sender_id"]; id != "" {
+		sender = id
+	}
+	if phone := m.Metadata["phone"]; phone != "" {
+		sender = phone
+	}
+	if sender == "" {
+		return ""
+	}
+	return m.ChannelID + ":" + sender
+}
+
+// OrderingKey returns the conversation of the message
+func (m *OutboundMessage) OrderingKey() string {
+	return m.ConversationID
+}
+
+// OrderingKey returns the message the update belongs to, so its statuses apply in order
+func (s *StatusUpdate) OrderingKey() string {
+	if s.MessageID != "" {
+		return s.MessageID
+	}
+	return s.ExternalID
+}
+
+// OrderingKey returns the conversation of the request
+func (r *BotAnalysisRequest) OrderingKey() string {
+	return r.ConversationID
+}
+
+// OrderingKey returns the conversation of the request
+func (r *BotResponseRequest) OrderingKey() string {
+	return r.ConversationID
+}
+
+// OrderingKey returns the conversation of the request
+func (r *BotEscalationRequest) OrderingKey() string {
+	return r.ConversationID
+}
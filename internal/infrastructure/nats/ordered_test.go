@@ -0,0 +1,115 @@
+package nats
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderedWorkers_KeepsPerKeyOrder(t *testing.T) {
+	workers := newOrderedWorkers(4)
+
+	var mu sync.Mutex
+	handled := make(map[string][]int)
+	for i := 0; i < 50; i++ {
+		for _, key := range []string{"conv-1", "conv-2", "conv-3"} {
+			key, i := key, i
+			workers.dispatch(key, func() {
+				if i%7 == 0 {
+					time.Sleep(time.Millisecond)
+				}
+				mu.Lock()
+				handled[key] = append(handled[key], i)
+				mu.Unlock()
+			})
+		}
+	}
+	workers.stop()
+
+	for key, sequence := range handled {
+		require.Len(t, sequence, 50, key)
+		for i, n := range sequence {
+			assert.Equal(t, i, n, "%s handled out of order", key)
+		}
+	}
+}
+
+func TestOrderedWorkers_SpreadsKeys(t *testing.T) {
+	workers := newOrderedWorkers(4)
+	defer workers.stop()
+
+	used := make(map[int]bool)
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("conv-%d", i)
+		assert.Equal(t, workers.worker(key), workers.worker(key))
+		used[workers.worker(key)] = true
+	}
+	assert.Len(t, used, 4)
+}
+
+func TestOrderedWorkers_RunsInParallel(t *testing.T) {
+	workers := newOrderedWorkers(2)
+
+	// Two keys on different workers block until both have started
+	keys := []string{"a"}
+	for i := 0; len(keys) < 2; i++ {
+		if key := fmt.Sprintf("k%d", i); workers.worker(key) != workers.worker("a") {
+			keys = append(keys, key)
+		}
+	}
+	var started sync.WaitGroup
+	started.Add(2)
+	done := make(chan struct{})
+	for _, key := range keys {
+		workers.dispatch(key, func() {
+			started.Done()
+			started.Wait()
+		})
+	}
+	go func() {
+		workers.stop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("workers did not run concurrently")
+	}
+}
+
+func TestInboundMessage_OrderingKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		msg      InboundMessage
+		expected string
+	}{
+		{"conversation", InboundMessage{ConversationID: "conv-1", ChannelID: "ch-1", Metadata: map[string]string{"phone": "+5511"}}, "conv-1"},
+		{"phone", InboundMessage{ChannelID: "ch-1", ContactID: "contact-1", Metadata: map[string]string{"phone": "+5511", "sender_id": "s-1"}}, "ch-1:+5511"},
+		{"sender", InboundMessage{ChannelID: "ch-1", Metadata: map[string]string{"sender_id": "s-1"}}, "ch-1:s-1"},
+		{"contact", InboundMessage{ChannelID: "ch-1", ContactID: "contact-1"}, "ch-1:contact-1"},
+		{"unknown", InboundMessage{ChannelID: "ch-1"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.msg.OrderingKey())
+		})
+	}
+}
+
+func TestOrderingKey_DecodesPayload(t *testing.T) {
+	data, err := json.Marshal(&OutboundMessage{ID: "m-1", ConversationID: "conv-1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "conv-1", orderingKey[OutboundMessage](data))
+	assert.Equal(t, "", orderingKey[OutboundMessage]([]byte("not json")))
+
+	data, err = json.Marshal(&StatusUpdate{ExternalID: "wamid.1"})
+	require.NoError(t, err)
+	assert.Equal(t, "wamid.1", orderingKey[StatusUpdate](data))
+}
@@ -67,8 +67,10 @@ type OutboundMessage struct {
 type StatusUpdate struct {
 	MessageID    string    `json:"message_id"`
 	ExternalID   string    `json:"external_id,omitempty"`
+	Recipient    string    `json:"recipient,omitempty"` // Participant a group message status is for
 	ChannelType  string    `json:"channel_type"`
 	Status       string    `json:"status"`
+	ErrorCode    string    `json:"error_code,omitempty"` // Provider error code of failed messages
 	ErrorMessage string    `json:"error_message,omitempty"`
 	Timestamp    time.Time `json:"timestamp"`
 }
@@ -113,7 +115,7 @@ func (p *Producer) PublishInbound(ctx context.Context, msg *InboundMessage) erro
 	}
 
 	subject := SubjectInbound(msg.ChannelType)
-	_, err = p.client.js.Publish(ctx, subject, data,
+	err = p.client.publish(ctx, subject, data,
 		jetstream.WithMsgID(msg.ID),
 	)
 	if err != nil {
@@ -131,7 +133,7 @@ func (p *Producer) PublishOutbound(ctx context.Context, msg *OutboundMessage) er
 	}
 
 	subject := SubjectOutbound(msg.ChannelType)
-	_, err = p.client.js.Publish(ctx, subject, data,
+	err = p.client.publish(ctx, subject, data,
 		jetstream.WithMsgID(msg.ID),
 	)
 	if err != nil {
@@ -150,7 +152,7 @@ func (p *Producer) PublishStatusUpdate(ctx context.Context, status *StatusUpdate
 
 	subject := SubjectStatus(status.ChannelType)
 	msgID := fmt.Sprintf("%s-%s-%d", status.MessageID, status.Status, status.Timestamp.UnixNano())
-	_, err = p.client.js.Publish(ctx, subject, data,
+	err = p.client.publish(ctx, subject, data,
 		jetstream.WithMsgID(msgID),
 	)
 	if err != nil {
@@ -169,7 +171,7 @@ func (p *Producer) PublishEvent(ctx context.Context, event *Event) error {
 
 	subject := SubjectEvent(event.Type)
 	msgID := fmt.Sprintf("%s-%s-%d", event.TenantID, event.Type, event.Timestamp.UnixNano())
-	_, err = p.client.js.Publish(ctx, subject, data,
+	err = p.client.publish(ctx, subject, data,
 		jetstream.WithMsgID(msgID),
 	)
 	if err != nil {
@@ -187,7 +189,7 @@ func (p *Producer) PublishWebhookDelivery(ctx context.Context, webhook *WebhookD
 	}
 
 	subject := SubjectWebhook(webhook.TenantID)
-	_, err = p.client.js.Publish(ctx, subject, data,
+	err = p.client.publish(ctx, subject, data,
 		jetstream.WithMsgID(webhook.ID),
 	)
 	if err != nil {
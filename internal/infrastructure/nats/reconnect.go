@@ -0,0 +1,374 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// ConnectionStatus is the state of the connection to NATS
+type ConnectionStatus string
+
+const (
+	ConnectionConnecting   ConnectionStatus = "connecting"   // Not connected since startup yet
+	ConnectionSettingUp    ConnectionStatus = "setting_up"   // Connected, streams not set up yet
+	ConnectionConnected    ConnectionStatus = "connected"    // Connected with the streams set up
+	ConnectionReconnecting ConnectionStatus = "reconnecting" // Connection lost, connecting again
+	ConnectionClosed       ConnectionStatus = "closed"
+)
+
+// Degradation of a messaging feature while NATS is not connected
+const (
+	FeatureAvailable   = "available"
+	FeatureBuffered    = "buffered"    // Publishes wait in memory until NATS is back
+	FeatureUnavailable = "unavailable" // Publishes are refused, the buffer is full
+	FeaturePaused      = "paused"      // Consumers wait; messages stay in the streams
+	FeatureDegraded    = "degraded"    // Some consumers are not set up yet, see last_error
+	FeatureDelayed     = "delayed"     // Broadcasts reach other instances once reconnected
+)
+
+// Publish buffer and setup settings
+const (
+	PublishBufferSize   = 1000            // Publishes held while NATS is not connected
+	PublishBufferMaxAge = 2 * time.Minute // Older held publishes are dropped
+	SetupRetryInterval  = 5 * time.Second
+	setupTimeout        = 30 * time.Second
+)
+
+// ErrPublishBufferFull is returned for publishes made while NATS is not connected
+// once the publish buffer is full
+var ErrPublishBufferFull = errors.New("nats is not connected and the publish buffer is full")
+
+// MessagingState reports the connection to NATS and how messaging is degraded
+// while it is down
+type MessagingState struct {
+	Status     ConnectionStatus  `json:"status"`
+	Since      time.Time         `json:"since"`
+	Reconnects int               `json:"reconnects"`
+	LastError  string            `json:"last_error,omitempty"`
+	Buffered   int               `json:"buffered"` // Publishes waiting for NATS
+	Dropped    int64             `json:"dropped"`  // Held publishes that expired
+	Pending    int               `json:"pending"`  // Setup steps, mostly consumers, not done yet
+	Features   map[string]string `json:"features"` // Publishing, consuming and broadcasts
+}
+
+// setupStep sets up streams or consumers after every (re)connect
+type setupStep struct {
+	run  func(ctx context.Context) error
+	done bool
+}
+
+// bufferedPublish is a publish held while NATS is not connected
+type bufferedPublish struct {
+	subject string
+	data    []byte
+	opts    []jetstream.PublishOpt
+	at      time.Time
+}
+
+// connectionState is the reconnect bookkeeping of a client
+type connectionState struct {
+	mu         sync.Mutex
+	status     ConnectionStatus
+	since      time.Time
+	reconnects int
+	lastError  string
+	generation int // Incremented on every disconnect
+	steps      []*setupStep
+	buffer     []bufferedPublish
+	dropped    int64
+	now        func() time.Time
+
+	wake chan struct{}
+	done chan struct{}
+	stop sync.Once
+}
+
+func newConnectionState() *connectionState {
+	return &connectionState{
+		status: ConnectionConnecting,
+		since:  time.Now(),
+		now:    time.Now,
+		wake:   make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+}
+
+// State returns the connection to NATS and the degradation of messaging
+func (c *Client) State() *MessagingState {
+	connected := c.IsConnected()
+	s := c.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending := 0
+	for _, step := range s.steps {
+		if !step.done {
+			pending++
+		}
+	}
+	state := &MessagingState{
+		Status:     s.status,
+		Since:      s.since,
+		Reconnects: s.reconnects,
+		LastError:  s.lastError,
+		Buffered:   len(s.buffer),
+		Dropped:    s.dropped,
+		Pending:    pending,
+		Features: map[string]string{
+			"publishing": FeatureAvailable,
+			"consuming":  FeatureAvailable,
+			"broadcasts": FeatureAvailable,
+		},
+	}
+	if s.status != ConnectionConnected || !connected {
+		state.Features["publishing"] = FeatureBuffered
+		if len(s.buffer) >= PublishBufferSize {
+			state.Features["publishing"] = FeatureUnavailable
+		}
+		state.Features["consuming"] = FeaturePaused
+	} else if pending > 0 {
+		state.Features["consuming"] = FeatureDegraded
+	}
+	if !connected {
+		state.Features["broadcasts"] = FeatureDelayed
+	}
+	return state
+}
+
+// setUpOnConnect runs setup now when NATS is connected and set up, returning its
+// error, and again after every reconnect, as the server may have lost its streams and
+// consumers. Before NATS is first reached it runs as soon as it is, retried until it
+// succeeds.
+func (c *Client) setUpOnConnect(ctx context.Context, setup func(ctx context.Context) error) error {
+	s := c.state
+	s.mu.Lock()
+	ready := s.status == ConnectionConnected
+	generation := s.generation
+	s.mu.Unlock()
+
+	if ready {
+		if err := setup(ctx); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	s.steps = append(s.steps, &setupStep{run: setup, done: ready && s.generation == generation})
+	s.mu.Unlock()
+	return nil
+}
+
+// publish publishes to JetStream, holding the message in memory while NATS is not
+// connected; held messages are published in order once it is
+func (c *Client) publish(ctx context.Context, subject string, data []byte, opts ...jetstream.PublishOpt) error {
+	s := c.state
+	s.mu.Lock()
+	if s.status == ConnectionConnected && len(s.buffer) == 0 && c.IsConnected() {
+		s.mu.Unlock()
+		_, err := c.js.Publish(ctx, subject, data, opts...)
+		return err
+	}
+	defer s.mu.Unlock()
+
+	s.dropExpired()
+	if len(s.buffer) >= PublishBufferSize {
+		return ErrPublishBufferFull
+	}
+	s.buffer = append(s.buffer, bufferedPublish{subject: subject, data: data, opts: opts, at: s.now()})
+	s.notify()
+	return nil
+}
+
+// maintain sets the client up whenever NATS is (re)connected, retrying failed setup
+// steps, and flushes held publishes, until the client is closed
+func (c *Client) maintain() {
+	ticker := time.NewTicker(SetupRetryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.state.done:
+			return
+		case <-c.state.wake:
+		case <-ticker.C:
+		}
+		if !c.IsConnected() {
+			continue
+		}
+
+		// Streams are created once per connect; failed steps are retried on every tick
+		ctx, cancel := context.WithTimeout(context.Background(), setupTimeout)
+		ready := c.state.isSetUp()
+		if ready {
+			c.state.runSteps(ctx)
+		} else {
+			ready = c.setUp(ctx)
+		}
+		if ready {
+			c.flush(ctx)
+		}
+		cancel()
+	}
+}
+
+// setUp creates the streams and runs the setup steps not done since the last
+// connect, reporting whether the streams are set up
+func (c *Client) setUp(ctx context.Context) bool {
+	if err := c.initializeStreams(ctx); err != nil {
+		c.state.fail(err)
+		return false
+	}
+	return c.state.runSteps(ctx)
+}
+
+// runSteps runs the setup steps not done since the last connect, in the order they
+// were added, and marks the client connected. Failed steps are retried by the next
+// run; the first failure is kept as the last error meanwhile.
+func (s *connectionState) runSteps(ctx context.Context) bool {
+	s.mu.Lock()
+	generation := s.generation
+	pending := make([]*setupStep, 0, len(s.steps))
+	for _, step := range s.steps {
+		if !step.done {
+			pending = append(pending, step)
+		}
+	}
+	s.mu.Unlock()
+
+	var failed error
+	for _, step := range pending {
+		if err := step.run(ctx); err != nil {
+			if failed == nil {
+				failed = err
+			}
+			continue
+		}
+		s.mu.Lock()
+		step.done = s.generation == generation
+		s.mu.Unlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.generation != generation {
+		return false
+	}
+	if s.status != ConnectionConnected {
+		s.status = ConnectionConnected
+		s.since = s.now()
+	}
+	s.lastError = ""
+	if failed != nil {
+		s.lastError = failed.Error()
+	}
+	return true
+}
+
+// flush publishes the held messages in order, dropping those held too long. It stops
+// at the first failure, keeping the rest for the next attempt.
+func (c *Client) flush(ctx context.Context) {
+	for {
+		next, ok := c.state.nextBuffered()
+		if !ok {
+			return
+		}
+		if _, err := c.js.Publish(ctx, next.subject, next.data, next.opts...); err != nil {
+			c.state.fail(err)
+			return
+		}
+		c.state.popBuffered()
+	}
+}
+
+// nextBuffered returns the oldest held publish, dropping the expired ones
+func (s *connectionState) nextBuffered() (bufferedPublish, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dropExpired()
+	if len(s.buffer) == 0 {
+		return bufferedPublish{}, false
+	}
+	return s.buffer[0], true
+}
+
+// dropExpired drops the publishes held too long. Must be called with the lock held.
+func (s *connectionState) dropExpired() {
+	now := s.now()
+	for len(s.buffer) > 0 && now.Sub(s.buffer[0].at) > PublishBufferMaxAge {
+		s.buffer = s.buffer[1:]
+		s.dropped++
+	}
+}
+
+// popBuffered removes the oldest held publish once it was published
+func (s *connectionState) popBuffered() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.buffer) > 0 {
+		s.buffer = s.buffer[1:]
+	}
+}
+
+// connected records a (re)connect and wakes maintain to set the client up
+func (s *connectionState) connected() {
+	s.mu.Lock()
+	if s.status == ConnectionReconnecting {
+		s.reconnects++
+	}
+	s.status = ConnectionSettingUp
+	s.since = s.now()
+	s.mu.Unlock()
+	s.notify()
+}
+
+// disconnected records a lost connection; everything is set up again on reconnect
+func (s *connectionState) disconnected(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.generation++
+	for _, step := range s.steps {
+		step.done = false
+	}
+	if s.status == ConnectionClosed {
+		return
+	}
+	s.status = ConnectionReconnecting
+	s.since = s.now()
+	if err != nil {
+		s.lastError = err.Error()
+	}
+}
+
+// closed records the client was closed and stops maintain
+func (s *connectionState) closed() {
+	s.mu.Lock()
+	s.status = ConnectionClosed
+	s.since = s.now()
+	s.mu.Unlock()
+	s.stop.Do(func() { close(s.done) })
+}
+
+// isSetUp reports whether the streams are set up since the last connect
+func (s *connectionState) isSetUp() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status == ConnectionConnected
+}
+
+// fail records the last setup or flush error
+func (s *connectionState) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastError = err.Error()
+}
+
+// notify wakes maintain without blocking
+func (s *connectionState) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
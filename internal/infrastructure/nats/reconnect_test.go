@@ -0,0 +1,94 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDisconnectedClient(now *time.Time) *Client {
+	client := &Client{state: newConnectionState()}
+	client.state.now = func() time.Time { return *now }
+	return client
+}
+
+func TestClient_BuffersPublishesWhileDisconnected(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	client := newDisconnectedClient(&now)
+	ctx := context.Background()
+
+	for i := 0; i < PublishBufferSize; i++ {
+		require.NoError(t, client.publish(ctx, SubjectEvent(EventMessageSent), []byte(fmt.Sprintf(`{"n":%d}`, i))))
+	}
+	assert.ErrorIs(t, client.publish(ctx, SubjectEvent(EventMessageSent), []byte(`{}`)), ErrPublishBufferFull)
+
+	state := client.State()
+	assert.Equal(t, ConnectionConnecting, state.Status)
+	assert.Equal(t, PublishBufferSize, state.Buffered)
+	assert.Equal(t, FeatureUnavailable, state.Features["publishing"])
+	assert.Equal(t, FeaturePaused, state.Features["consuming"])
+	assert.Equal(t, FeatureDelayed, state.Features["broadcasts"])
+
+	// Publishes held too long are dropped, making room for new ones
+	now = now.Add(PublishBufferMaxAge + time.Second)
+	require.NoError(t, client.publish(ctx, SubjectEvent(EventMessageSent), []byte(`{"n":"new"}`)))
+	state = client.State()
+	assert.Equal(t, 1, state.Buffered)
+	assert.Equal(t, int64(PublishBufferSize), state.Dropped)
+	assert.Equal(t, FeatureBuffered, state.Features["publishing"])
+
+	next, ok := client.state.nextBuffered()
+	require.True(t, ok)
+	assert.JSONEq(t, `{"n":"new"}`, string(next.data))
+}
+
+func TestClient_RunsSetupStepsOnEveryConnect(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	client := newDisconnectedClient(&now)
+	ctx := context.Background()
+
+	var runs []string
+	consumerErr := fmt.Errorf("consumer config conflicts")
+	require.NoError(t, client.setUpOnConnect(ctx, func(context.Context) error {
+		runs = append(runs, "stream")
+		return nil
+	}))
+	require.NoError(t, client.setUpOnConnect(ctx, func(context.Context) error {
+		runs = append(runs, "consumer")
+		return consumerErr
+	}))
+	assert.Empty(t, runs, "nothing runs before NATS is reached")
+
+	client.state.connected()
+	assert.Equal(t, ConnectionSettingUp, client.State().Status)
+	assert.True(t, client.state.runSteps(ctx))
+	assert.Equal(t, []string{"stream", "consumer"}, runs)
+
+	state := client.State()
+	assert.Equal(t, ConnectionConnected, state.Status)
+	assert.Equal(t, 1, state.Pending)
+	assert.Contains(t, state.LastError, "conflicts")
+
+	// Only the failed step is retried
+	consumerErr = nil
+	assert.True(t, client.state.runSteps(ctx))
+	assert.Equal(t, []string{"stream", "consumer", "consumer"}, runs)
+	assert.Zero(t, client.State().Pending)
+	assert.Empty(t, client.State().LastError)
+
+	// A reconnect sets everything up again
+	client.state.disconnected(fmt.Errorf("connection reset"))
+	state = client.State()
+	assert.Equal(t, ConnectionReconnecting, state.Status)
+	assert.Equal(t, "connection reset", state.LastError)
+	assert.Equal(t, 2, state.Pending)
+
+	client.state.connected()
+	assert.True(t, client.state.runSteps(ctx))
+	assert.Equal(t, []string{"stream", "consumer", "consumer", "stream", "consumer"}, runs)
+	assert.Equal(t, 1, client.State().Reconnects)
+}
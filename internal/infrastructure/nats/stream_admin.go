@@ -0,0 +1,253 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/msgfy/linktor/internal/domain/entity"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// TunableStreams maps the logical stream names used by the admin API to the
+// NATS streams whose limits can be tuned and whose messages can be replayed
+var TunableStreams = map[string]string{
+	"messages": StreamMessages,
+	"events":   StreamEvents,
+	"webhooks": StreamWebhooks,
+	"ai":       StreamAI,
+	"jobs":     StreamJobs,
+}
+
+// Replayed copies are kept for a day, as is their consumer when nobody reads it
+const replayRetention = 24 * time.Hour
+
+// ReplayedSequenceHeader carries the sequence of the original message on a replayed copy
+const ReplayedSequenceHeader = "Linktor-Replayed-Sequence"
+
+// ensureStream creates a stream or updates its subjects and policies, keeping the
+// retention window, message limit and replicas an administrator tuned on it
+func (c *Client) ensureStream(ctx context.Context, cfg jetstream.StreamConfig) error {
+	if stream, err := c.js.Stream(ctx, cfg.Name); err == nil {
+		cfg = keepTunedLimits(cfg, stream.CachedInfo().Config)
+	}
+	_, err := c.js.CreateOrUpdateStream(ctx, cfg)
+	return err
+}
+
+// keepTunedLimits returns the desired configuration with the tunable limits of
+// the existing stream
+func keepTunedLimits(desired, existing jetstream.StreamConfig) jetstream.StreamConfig {
+	desired.MaxAge = existing.MaxAge
+	desired.MaxMsgs = existing.MaxMsgs
+	desired.Replicas = existing.Replicas
+	return desired
+}
+
+// StreamSettings returns the tunable limits of a stream
+func (m *Monitor) StreamSettings(ctx context.Context, streamName string) (*entity.StreamSettings, error) {
+	stream, err := m.client.JetStream().Stream(ctx, streamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stream %s: %w", streamName, err)
+	}
+
+	info, err := stream.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stream info for %s: %w", streamName, err)
+	}
+	return streamSettings(info), nil
+}
+
+// UpdateStreamSettings changes the tunable limits of a stream
+func (m *Monitor) UpdateStreamSettings(ctx context.Context, streamName string, update *entity.StreamSettingsUpdate) (*entity.StreamSettings, error) {
+	js := m.client.JetStream()
+
+	stream, err := js.Stream(ctx, streamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stream %s: %w", streamName, err)
+	}
+
+	cfg := stream.CachedInfo().Config
+	if update.RetentionHours != nil {
+		cfg.MaxAge = time.Duration(*update.RetentionHours) * time.Hour
+	}
+	if update.MaxMessages != nil {
+		cfg.MaxMsgs = *update.MaxMessages
+	}
+	if update.Replicas != nil {
+		cfg.Replicas = *update.Replicas
+	}
+
+	stream, err = js.UpdateStream(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update stream %s: %w", streamName, err)
+	}
+
+	info, err := stream.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stream info for %s: %w", streamName, err)
+	}
+	return streamSettings(info), nil
+}
+
+// Replay copies the messages of a stream stored in the requested time range to
+// the replay stream, on subjects of the consumer group, and makes sure the group's
+// durable consumer exists. The original stream and its consumers are not touched,
+// so messages already removed from the stream, like acknowledged work queue
+// messages, cannot be replayed.
+func (m *Monitor) Replay(ctx context.Context, streamName string, req *entity.StreamReplayRequest) (*entity.StreamReplayResult, error) {
+	js := m.client.JetStream()
+
+	stream, err := js.Stream(ctx, streamName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stream %s: %w", streamName, err)
+	}
+	info, err := stream.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stream info for %s: %w", streamName, err)
+	}
+
+	replay, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:        StreamReplay,
+		Description: "Linktor copies of stream messages replayed for debugging",
+		Subjects:    []string{SubjectReplayAll},
+		Retention:   jetstream.LimitsPolicy,
+		MaxMsgs:     -1,
+		MaxBytes:    256 * 1024 * 1024, // 256MB
+		MaxAge:      replayRetention,
+		Discard:     jetstream.DiscardOld,
+		Storage:     jetstream.FileStorage,
+		Replicas:    1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create replay stream: %w", err)
+	}
+
+	consumer := ConsumerReplay(req.Group)
+	_, err = replay.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:           consumer,
+		Description:       "Replay of " + streamName,
+		FilterSubject:     SubjectReplay(req.Group, ">"),
+		DeliverPolicy:     jetstream.DeliverAllPolicy,
+		AckPolicy:         jetstream.AckExplicitPolicy,
+		InactiveThreshold: replayRetention,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create replay consumer %s: %w", consumer, err)
+	}
+
+	subject := req.Subject
+	if subject == "" {
+		subject = ">"
+	}
+	result := &entity.StreamReplayResult{
+		Stream:   streamName,
+		Group:    req.Group,
+		Consumer: consumer,
+		Subject:  SubjectReplay(req.Group, ">"),
+	}
+	if info.State.Msgs == 0 {
+		return result, nil
+	}
+
+	seq, err := firstSeqFrom(ctx, stream, subject, req.From, info.State.FirstSeq, info.State.LastSeq+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find replay start in %s: %w", streamName, err)
+	}
+
+	for {
+		msg, err := stream.GetMsg(ctx, seq, jetstream.WithGetMsgSubject(subject))
+		if errors.Is(err, jetstream.ErrMsgNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read message %d of %s: %w", seq, streamName, err)
+		}
+		if !msg.Time.Before(req.To) {
+			break
+		}
+		if result.Copied >= req.MaxMessages {
+			result.Truncated = true
+			break
+		}
+
+		copied := &nats.Msg{
+			Subject: SubjectReplay(req.Group, msg.Subject),
+			Data:    msg.Data,
+			Header:  nats.Header{},
+		}
+		for key, values := range msg.Header {
+			if key != nats.MsgIdHdr {
+				copied.Header[key] = values
+			}
+		}
+		copied.Header.Set(ReplayedSequenceHeader, strconv.FormatUint(msg.Sequence, 10))
+		if _, err := js.PublishMsg(ctx, copied); err != nil {
+			return nil, fmt.Errorf("failed to replay message %d of %s: %w", msg.Sequence, streamName, err)
+		}
+
+		result.Copied++
+		seq = msg.Sequence + 1
+	}
+
+	return result, nil
+}
+
+// firstSeqFrom returns the lowest sequence in [lo, hi) from which the next message
+// on the subject was stored at or after from, or hi when there is none. Message
+// times grow with sequences, so it binary searches over them.
+func firstSeqFrom(ctx context.Context, stream jetstream.Stream, subject string, from time.Time, lo, hi uint64) (uint64, error) {
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		msg, err := stream.GetMsg(ctx, mid, jetstream.WithGetMsgSubject(subject))
+		if errors.Is(err, jetstream.ErrMsgNotFound) {
+			hi = mid
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if msg.Time.Before(from) {
+			lo = msg.Sequence + 1
+		} else {
+			hi = mid
+		}
+	}
+	return lo, nil
+}
+
+// streamSettings converts stream info to its tunable settings
+func streamSettings(info *jetstream.StreamInfo) *entity.StreamSettings {
+	settings := &entity.StreamSettings{
+		Name:           info.Config.Name,
+		Retention:      retentionName(info.Config.Retention),
+		RetentionHours: int(info.Config.MaxAge / time.Hour),
+		MaxMessages:    info.Config.MaxMsgs,
+		Replicas:       info.Config.Replicas,
+		Messages:       info.State.Msgs,
+	}
+	for name, streamName := range TunableStreams {
+		if streamName == info.Config.Name {
+			settings.Stream = name
+		}
+	}
+	if info.State.Msgs > 0 {
+		settings.FirstMessageAt = info.State.FirstTime
+	}
+	return settings
+}
+
+// retentionName returns the API name of a retention policy
+func retentionName(policy jetstream.RetentionPolicy) string {
+	switch policy {
+	case jetstream.InterestPolicy:
+		return "interest"
+	case jetstream.WorkQueuePolicy:
+		return "workqueue"
+	default:
+		return "limits"
+	}
+}
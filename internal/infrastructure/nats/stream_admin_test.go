@@ -0,0 +1,57 @@
+package nats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeepTunedLimits(t *testing.T) {
+	desired := jetstream.StreamConfig{
+		Name:     StreamEvents,
+		Subjects: []string{SubjectEventsAll},
+		MaxAge:   24 * time.Hour,
+		MaxMsgs:  -1,
+		MaxBytes: 256 * 1024 * 1024,
+		Replicas: 1,
+	}
+	existing := jetstream.StreamConfig{
+		Name:     StreamEvents,
+		Subjects: []string{"linktor.events.old.>"},
+		MaxAge:   72 * time.Hour,
+		MaxMsgs:  50000,
+		MaxBytes: 1024,
+		Replicas: 3,
+	}
+
+	cfg := keepTunedLimits(desired, existing)
+	assert.Equal(t, []string{SubjectEventsAll}, cfg.Subjects)
+	assert.Equal(t, int64(256*1024*1024), cfg.MaxBytes)
+	assert.Equal(t, 72*time.Hour, cfg.MaxAge)
+	assert.Equal(t, int64(50000), cfg.MaxMsgs)
+	assert.Equal(t, 3, cfg.Replicas)
+}
+
+func TestStreamSettings(t *testing.T) {
+	first := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+	settings := streamSettings(&jetstream.StreamInfo{
+		Config: jetstream.StreamConfig{
+			Name:      StreamWebhooks,
+			Retention: jetstream.WorkQueuePolicy,
+			MaxAge:    72 * time.Hour,
+			MaxMsgs:   -1,
+			Replicas:  1,
+		},
+		State: jetstream.StreamState{Msgs: 12, FirstTime: first},
+	})
+
+	assert.Equal(t, "webhooks", settings.Stream)
+	assert.Equal(t, StreamWebhooks, settings.Name)
+	assert.Equal(t, "workqueue", settings.Retention)
+	assert.Equal(t, 72, settings.RetentionHours)
+	assert.Equal(t, int64(-1), settings.MaxMessages)
+	assert.Equal(t, uint64(12), settings.Messages)
+	assert.Equal(t, first, settings.FirstMessageAt)
+}
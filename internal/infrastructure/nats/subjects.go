@@ -74,6 +74,60 @@ const (
 	SubjectBotContext = "linktor.bot.context"
 )
 
+// Stream for background jobs
+const (
+	StreamJobs = "LINKTOR_JOBS"
+)
+
+// Subject patterns for background jobs
+const (
+	SubjectJobsAll     = "linktor.jobs.>"
+	SubjectJobsPattern = "linktor.jobs.%s" // %s = job type
+)
+
+// Consumer names for background jobs
+const (
+	ConsumerJobs = "job-worker"
+)
+
+// Stream holding copies of stream messages replayed for debugging
+const (
+	StreamReplay = "LINKTOR_REPLAY"
+)
+
+// Subject patterns for replayed messages
+const (
+	SubjectReplayAll     = "linktor.replay.>"
+	SubjectReplayPattern = "linktor.replay.%s.%s" // %s = consumer group, %s = original subject
+)
+
+// Consumer names for replayed messages
+const (
+	ConsumerReplayPrefix = "replay-" // + consumer group
+)
+
+// Stream holding the messages that exhausted their deliveries
+const (
+	StreamDeadLetter = "LINKTOR_DEAD_LETTER"
+)
+
+// Subject patterns for dead letters
+const (
+	SubjectDeadLetterAll     = "linktor.deadletter.>"
+	SubjectDeadLetterPattern = "linktor.deadletter.%s.%s" // %s = original stream, %s = original subject
+)
+
+// Subject patterns for broadcasts to every server instance. They go over core NATS,
+// not a stream: instances not running when a broadcast is sent never see it.
+const (
+	SubjectBroadcastPattern = "linktor.broadcast.%s" // %s = topic
+)
+
+// Broadcast topics
+const (
+	BroadcastTopicAgentHub = "agent-hub"
+)
+
 // Event types
 const (
 	EventMessageReceived  = "message.received"
@@ -83,11 +137,13 @@ const (
 	EventMessageFailed    = "message.failed"
 	EventMessageAnalyzed  = "message.analyzed"
 
-	EventConversationCreated   = "conversation.created"
-	EventConversationAssigned  = "conversation.assigned"
-	EventConversationResolved  = "conversation.resolved"
-	EventConversationReopened  = "conversation.reopened"
-	EventConversationEscalated = "conversation.escalated"
+	EventConversationCreated    = "conversation.created"
+	EventConversationAssigned   = "conversation.assigned"
+	EventConversationResolved   = "conversation.resolved"
+	EventConversationReopened   = "conversation.reopened"
+	EventConversationEscalated  = "conversation.escalated"
+	EventConversationTakenOver  = "conversation.taken_over"
+	EventConversationBotResumed = "conversation.bot_resumed"
 
 	EventContactCreated = "contact.created"
 	EventContactUpdated = "contact.updated"
@@ -95,6 +151,7 @@ const (
 	EventChannelConnected    = "channel.connected"
 	EventChannelDisconnected = "channel.disconnected"
 	EventChannelError        = "channel.error"
+	EventChannelTakeover     = "channel.takeover"
 
 	// AI/Bot events
 	EventBotResponse   = "bot.response"
@@ -127,6 +184,31 @@ func SubjectWebhook(tenantID string) string {
 	return fmt.Sprintf(SubjectWebhooksPattern, tenantID)
 }
 
+// SubjectBroadcast returns the subject broadcasts of a topic are sent on
+func SubjectBroadcast(topic string) string {
+	return fmt.Sprintf(SubjectBroadcastPattern, topic)
+}
+
+// SubjectJob returns the subject for jobs of a type
+func SubjectJob(jobType string) string {
+	return fmt.Sprintf(SubjectJobsPattern, jobType)
+}
+
+// SubjectReplay returns the subject a message of the original subject is replayed on for a consumer group
+func SubjectReplay(group, subject string) string {
+	return fmt.Sprintf(SubjectReplayPattern, group, subject)
+}
+
+// SubjectDeadLetter returns the subject of the dead letters of a stream's subject
+func SubjectDeadLetter(stream, subject string) string {
+	return fmt.Sprintf(SubjectDeadLetterPattern, stream, subject)
+}
+
+// ConsumerReplay returns the consumer name of a replay consumer group
+func ConsumerReplay(group string) string {
+	return ConsumerReplayPrefix + group
+}
+
 // ConsumerOutbound returns the consumer name for a channel type
 func ConsumerOutbound(channelType string) string {
 	return ConsumerOutboundPrefix + channelType
@@ -153,6 +153,32 @@ func TestSubjectBotEscalate(t *testing.T) {
 	}
 }
 
+func TestSubjectJob(t *testing.T) {
+	tests := []struct {
+		jobType  string
+		expected string
+	}{
+		{"config.import", "linktor.jobs.config.import"},
+		{"embeddings", "linktor.jobs.embeddings"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.jobType, func(t *testing.T) {
+			assert.Equal(t, tt.expected, SubjectJob(tt.jobType))
+		})
+	}
+}
+
+func TestSubjectReplay(t *testing.T) {
+	assert.Equal(t, "linktor.replay.debug-1.linktor.messages.inbound.whatsapp", SubjectReplay("debug-1", "linktor.messages.inbound.whatsapp"))
+	assert.Equal(t, "linktor.replay.debug-1.>", SubjectReplay("debug-1", ">"))
+	assert.Equal(t, "replay-debug-1", ConsumerReplay("debug-1"))
+}
+
+func TestSubjectDeadLetter(t *testing.T) {
+	assert.Equal(t, "linktor.deadletter.LINKTOR_WEBHOOKS.linktor.webhooks.tenant-1", SubjectDeadLetter(StreamWebhooks, "linktor.webhooks.tenant-1"))
+	assert.Equal(t, "linktor.deadletter.LINKTOR_MESSAGES.>", SubjectDeadLetter(StreamMessages, ">"))
+}
+
 func TestSubjectWhatsAppTemplateStatus(t *testing.T) {
 	tests := []struct {
 		tenantID string
@@ -363,6 +389,7 @@ func TestWildcardSubjectsEndWithWildcard(t *testing.T) {
 		"SubjectWhatsAppSecurityAll":           SubjectWhatsAppSecurityAll,
 		"SubjectWhatsAppCapabilityAll":         SubjectWhatsAppCapabilityAll,
 		"SubjectWhatsAppEchoAll":               SubjectWhatsAppEchoAll,
+		"SubjectJobsAll":                       SubjectJobsAll,
 	}
 	for name, value := range wildcardSubjects {
 		t.Run(name, func(t *testing.T) {
@@ -0,0 +1,182 @@
+// Package ocr extracts text from documents customers send as attachments.
+//
+// Text is extracted by external tools run as separate processes: tesseract for
+// images and pdftotext for PDFs. Plain text documents are read as is. Document
+// types whose tool is not installed on the host are reported as unsupported.
+package ocr
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Limits of a text extraction
+const (
+	DefaultTimeout = 60 * time.Second
+	MaxTextBytes   = 1 << 20
+)
+
+var (
+	// ErrUnsupported is returned for documents no available tool can read
+	ErrUnsupported = errors.New("document type not supported for text extraction")
+	// ErrTimeout is returned when an extraction runs past its time limit
+	ErrTimeout = errors.New("text extraction timed out")
+)
+
+// Engine extracts the text of a document
+type Engine interface {
+	// Supports reports whether documents of the MIME type can be read
+	Supports(mimeType string) bool
+	// ExtractText returns the text of the document
+	ExtractText(ctx context.Context, data []byte, mimeType string) (string, error)
+}
+
+// CommandEngine extracts text with tesseract and pdftotext
+type CommandEngine struct {
+	tesseractPath string
+	pdftotextPath string
+	languages     string
+	timeout       time.Duration
+}
+
+// NewCommandEngine creates an engine using the tesseract and pdftotext binaries
+// at the given paths, or found on PATH when empty. A missing binary disables the
+// document types it reads. Languages are tesseract language codes joined by "+",
+// English when empty.
+func NewCommandEngine(tesseractPath, pdftotextPath, languages string) *CommandEngine {
+	if languages == "" {
+		languages = "eng"
+	}
+	return &CommandEngine{
+		tesseractPath: lookPath(tesseractPath, "tesseract"),
+		pdftotextPath: lookPath(pdftotextPath, "pdftotext"),
+		languages:     languages,
+		timeout:       DefaultTimeout,
+	}
+}
+
+func lookPath(path, fallback string) string {
+	if path == "" {
+		path = fallback
+	}
+	resolved, err := exec.LookPath(path)
+	if err != nil {
+		return ""
+	}
+	return resolved
+}
+
+// Supports reports whether documents of the MIME type can be read
+func (e *CommandEngine) Supports(mimeType string) bool {
+	switch {
+	case isText(mimeType):
+		return true
+	case isPDF(mimeType):
+		return e.pdftotextPath != ""
+	case isImage(mimeType):
+		return e.tesseractPath != ""
+	}
+	return false
+}
+
+// ExtractText returns the text of the document
+func (e *CommandEngine) ExtractText(ctx context.Context, data []byte, mimeType string) (string, error) {
+	if !e.Supports(mimeType) {
+		return "", ErrUnsupported
+	}
+
+	switch {
+	case isText(mimeType):
+		if !utf8.Valid(data) {
+			return "", fmt.Errorf("text document is not valid UTF-8")
+		}
+		return truncate(string(data)), nil
+	case isPDF(mimeType):
+		// pdftotext needs a seekable input, so the document goes through a temp file
+		file, err := os.CreateTemp("", "ocr-*.pdf")
+		if err != nil {
+			return "", err
+		}
+		defer os.Remove(file.Name())
+		if _, err := file.Write(data); err != nil {
+			file.Close()
+			return "", err
+		}
+		if err := file.Close(); err != nil {
+			return "", err
+		}
+		return e.run(ctx, e.pdftotextPath, []string{"-layout", "-enc", "UTF-8", file.Name(), "-"}, nil)
+	default:
+		return e.run(ctx, e.tesseractPath, []string{"stdin", "stdout", "-l", e.languages}, data)
+	}
+}
+
+// run executes a tool and returns its output as text
+func (e *CommandEngine) run(ctx context.Context, name string, args []string, stdin []byte) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = os.TempDir()
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", ErrTimeout
+	}
+	if err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return "", fmt.Errorf("text extraction failed: %s", message)
+	}
+	return truncate(strings.ToValidUTF8(stdout.String(), "")), nil
+}
+
+// truncate caps the text at MaxTextBytes without splitting a character
+func truncate(text string) string {
+	if len(text) <= MaxTextBytes {
+		return text
+	}
+	cut := MaxTextBytes
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut]
+}
+
+func baseType(mimeType string) string {
+	if i := strings.Index(mimeType, ";"); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(mimeType))
+}
+
+func isText(mimeType string) bool {
+	return strings.HasPrefix(baseType(mimeType), "text/")
+}
+
+func isPDF(mimeType string) bool {
+	return baseType(mimeType) == "application/pdf"
+}
+
+func isImage(mimeType string) bool {
+	switch baseType(mimeType) {
+	case "image/png", "image/jpeg", "image/jpg", "image/tiff", "image/bmp", "image/webp", "image/gif":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package ocr
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractPlainText(t *testing.T) {
+	engine := NewCommandEngine("", "", "")
+
+	text, err := engine.ExtractText(context.Background(), []byte("Invoice 123\nTotal: 10,00"), "text/plain; charset=utf-8")
+	require.NoError(t, err)
+	assert.Equal(t, "Invoice 123\nTotal: 10,00", text)
+}
+
+func TestMissingToolsAreUnsupported(t *testing.T) {
+	engine := NewCommandEngine("/nonexistent/tesseract", "/nonexistent/pdftotext", "")
+
+	assert.False(t, engine.Supports("image/png"))
+	assert.False(t, engine.Supports("application/pdf"))
+	assert.False(t, engine.Supports("application/zip"))
+	assert.True(t, engine.Supports("text/csv"))
+
+	_, err := engine.ExtractText(context.Background(), []byte("%PDF-1.4"), "application/pdf")
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
+
+func TestTruncateKeepsCharactersWhole(t *testing.T) {
+	text := strings.Repeat("a", MaxTextBytes-1) + "ç"
+
+	truncated := truncate(text)
+	assert.Len(t, truncated, MaxTextBytes-1)
+}
@@ -0,0 +1,129 @@
+package scripting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+)
+
+// LanguageJavaScript is the language name of JavaScript hooks
+const LanguageJavaScript = "javascript"
+
+// javascriptWrapper reads the script and its input from stdin and runs the script
+// in a fresh V8 context that only holds the message, the lookups and the helpers.
+// The message and lookups are parsed inside the context, so their constructors are
+// the context's own. Code generation from strings is disabled so the helpers'
+// Function constructor cannot be used to reach the host context, and the permission
+// model denies the process file system and child process access should it anyway.
+const javascriptWrapper = `
+const vm = require('vm');
+let data = '';
+process.stdin.setEncoding('utf8');
+process.stdin.on('data', (chunk) => { data += chunk; });
+process.stdin.on('end', () => {
+  const request = JSON.parse(data);
+  const input = request.input;
+  const result = { blocked: false, reason: '', logs: [] };
+  const sandbox = {
+    event: String(input.event),
+    block: (reason) => { result.blocked = true; result.reason = String(reason === undefined ? '' : reason); },
+    log: (...args) => { if (result.logs.length < 50) { result.logs.push(args.map(String).join(' ').slice(0, 1000)); } },
+  };
+  const context = vm.createContext(sandbox, { codeGeneration: { strings: false, wasm: false } });
+  const parse = vm.runInContext('JSON.parse', context);
+  sandbox.message = parse(JSON.stringify(input.message));
+  sandbox.lookups = parse(JSON.stringify(input.lookups || {}));
+  try {
+    vm.runInContext(request.script, context, { timeout: request.timeout_ms, filename: 'hook.js' });
+  } catch (e) {
+    if (e && e.code === 'ERR_SCRIPT_EXECUTION_TIMEOUT') {
+      process.stdout.write(JSON.stringify({ timeout: true }));
+    } else {
+      process.stdout.write(JSON.stringify({ error: String((e && e.message) || e) }));
+    }
+    return;
+  }
+  const message = sandbox.message || {};
+  result.content = message.content === undefined || message.content === null ? '' : String(message.content);
+  result.metadata = {};
+  for (const [key, value] of Object.entries(message.metadata || {})) {
+    if (value !== undefined && value !== null) { result.metadata[key] = String(value); }
+  }
+  process.stdout.write(JSON.stringify(result));
+});
+`
+
+// JavaScriptRuntime runs JavaScript hooks with Node.js
+type JavaScriptRuntime struct {
+	nodePath string
+}
+
+// NewJavaScriptRuntime creates a JavaScript runtime using the node binary at the
+// given path, or found on PATH when empty
+func NewJavaScriptRuntime(nodePath string) (*JavaScriptRuntime, error) {
+	if nodePath == "" {
+		nodePath = "node"
+	}
+	path, err := exec.LookPath(nodePath)
+	if err != nil {
+		return nil, fmt.Errorf("node not found: %w", err)
+	}
+	return &JavaScriptRuntime{nodePath: path}, nil
+}
+
+// Language returns the language the runtime executes
+func (r *JavaScriptRuntime) Language() string {
+	return LanguageJavaScript
+}
+
+// Run executes a script against the input within the limits
+func (r *JavaScriptRuntime) Run(ctx context.Context, script string, input *Input, limits Limits) (*Result, error) {
+	limits = limits.normalize()
+
+	request, err := json.Marshal(map[string]interface{}{
+		"script":     script,
+		"input":      input,
+		"timeout_ms": limits.Timeout.Milliseconds(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode script input: %w", err)
+	}
+
+	args := append(javascriptFlags(limits), "-e", javascriptWrapper)
+	output, err := runProcess(ctx, r.nodePath, args, request, limits.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	var response struct {
+		Result
+		Timeout bool   `json:"timeout"`
+		Error   string `json:"error"`
+	}
+	if err := json.Unmarshal(output, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode script output: %w", err)
+	}
+	if response.Timeout {
+		return nil, ErrTimeout
+	}
+	if response.Error != "" {
+		return nil, &ScriptError{Message: response.Error}
+	}
+
+	result := response.Result
+	result.finalize()
+	return &result, nil
+}
+
+// javascriptFlags are the node options scripts run with. The permission model
+// (Node.js 20+) grants no file system, child process or worker access; stdin and
+// stdout stay usable.
+func javascriptFlags(limits Limits) []string {
+	return []string{
+		"--experimental-permission",
+		"--no-warnings",
+		fmt.Sprintf("--max-old-space-size=%d", limits.MemoryMB),
+		"--disallow-code-generation-from-strings",
+	}
+}
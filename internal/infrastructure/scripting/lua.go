@@ -0,0 +1,290 @@
+package scripting
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LanguageLua is the language name of Lua hooks
+const LanguageLua = "lua"
+
+// luaRunner runs the script in an environment holding only the message, the
+// lookups, the helpers and the pure string, table and math libraries. A debug
+// hook enforces the time and memory limits; pcall is wrapped so scripts cannot
+// swallow the limit errors. Results are written as "tag length\n" records
+// followed by the raw bytes, so no JSON library is needed inside Lua.
+const luaRunner = `
+local clock, gcount, sethook = os.clock, collectgarbage, debug.sethook
+local write, tostring, type, pairs, error, pcall = io.write, tostring, type, pairs, error, pcall
+local deadline = clock() + limits.timeout
+local limit_error = nil
+
+local function emit(tag, value)
+  value = tostring(value)
+  write(tag, " ", #value, "\n", value)
+end
+
+local function copy(lib)
+  local t = {}
+  for k, v in pairs(lib) do t[k] = v end
+  return t
+end
+
+local blocked, reason, logs = false, "", 0
+local env = {
+  message = input.message,
+  event = input.event,
+  lookups = input.lookups,
+  block = function(r) blocked = true; reason = r == nil and "" or tostring(r) end,
+  log = function(...)
+    if logs >= 50 then return end
+    logs = logs + 1
+    local parts = {}
+    for i = 1, select("#", ...) do parts[#parts + 1] = tostring((select(i, ...))) end
+    emit("log", string.sub(table.concat(parts, " "), 1, 1000))
+  end,
+  string = copy(string), table = copy(table), math = copy(math),
+  pairs = pairs, ipairs = ipairs, next = next, select = select, type = type,
+  tostring = tostring, tonumber = tonumber, error = error, assert = assert,
+  unpack = table.unpack or unpack,
+}
+env.string.dump = nil
+local function rethrow(ok, ...)
+  if not ok and limit_error then error(limit_error, 0) end
+  return ok, ...
+end
+env.pcall = function(f, ...) return rethrow(pcall(f, ...)) end
+
+if source:byte(1) == 27 then
+  emit("error", "binary chunks are not allowed")
+  return
+end
+
+local chunk, err
+if setfenv then
+  chunk, err = loadstring(source, "=hook")
+  if chunk then setfenv(chunk, env) end
+else
+  chunk, err = load(source, "=hook", "t", env)
+end
+if not chunk then
+  emit("error", err)
+  return
+end
+
+sethook(function()
+  if clock() > deadline then
+    limit_error = "timeout"
+    error(limit_error, 0)
+  end
+  if gcount("count") > limits.memory_kb then
+    limit_error = "memory"
+    error(limit_error, 0)
+  end
+end, "", 1000)
+local ok, run_err = pcall(chunk)
+sethook()
+
+if limit_error == "timeout" then emit("timeout", "") return end
+if limit_error == "memory" then emit("memory", "") return end
+if not ok then emit("error", run_err) return end
+
+local message = env.message
+if type(message) ~= "table" then message = {} end
+emit("content", message.content == nil and "" or message.content)
+if type(message.metadata) == "table" then
+  for k, v in pairs(message.metadata) do
+    emit("key", k)
+    emit("value", v)
+  end
+end
+if blocked then emit("blocked", reason) end
+`
+
+// LuaRuntime runs Lua hooks with a standalone Lua interpreter (5.1 or later,
+// including LuaJIT)
+type LuaRuntime struct {
+	luaPath string
+}
+
+// NewLuaRuntime creates a Lua runtime using the interpreter at the given path, or
+// the first of lua, lua5.4, lua5.3 and luajit found on PATH when empty
+func NewLuaRuntime(luaPath string) (*LuaRuntime, error) {
+	candidates := []string{luaPath}
+	if luaPath == "" {
+		candidates = []string{"lua", "lua5.4", "lua5.3", "luajit"}
+	}
+	for _, candidate := range candidates {
+		if path, err := exec.LookPath(candidate); err == nil {
+			return &LuaRuntime{luaPath: path}, nil
+		}
+	}
+	return nil, fmt.Errorf("lua interpreter not found")
+}
+
+// Language returns the language the runtime executes
+func (r *LuaRuntime) Language() string {
+	return LanguageLua
+}
+
+// Run executes a script against the input within the limits
+func (r *LuaRuntime) Run(ctx context.Context, script string, input *Input, limits Limits) (*Result, error) {
+	limits = limits.normalize()
+
+	inputValue, err := toGeneric(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode script input: %w", err)
+	}
+
+	var program strings.Builder
+	fmt.Fprintf(&program, "local limits = {timeout = %s, memory_kb = %d}\n",
+		strconv.FormatFloat(limits.Timeout.Seconds(), 'f', -1, 64), limits.MemoryMB*1024)
+	program.WriteString("local input = ")
+	writeLuaValue(&program, inputValue)
+	program.WriteString("\nlocal source = ")
+	program.WriteString(luaQuote(script))
+	program.WriteString("\n")
+	program.WriteString(luaRunner)
+
+	// The address space limit is a backstop for allocations the debug hook cannot
+	// see, such as a single huge string.rep
+	args := []string{
+		"-c", `ulimit -v "$1" && exec "$2" -`, "sh",
+		strconv.Itoa((limits.MemoryMB + 64) * 1024), r.luaPath,
+	}
+
+	output, err := runProcess(ctx, "/bin/sh", args, []byte(program.String()), limits.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseLuaOutput(output)
+}
+
+// parseLuaOutput decodes the "tag length\n" records written by the Lua runner
+func parseLuaOutput(output []byte) (*Result, error) {
+	result := &Result{Metadata: make(map[string]string)}
+	reader := bufio.NewReader(bytes.NewReader(output))
+	var key string
+
+	for {
+		header, err := reader.ReadString('\n')
+		if err == io.EOF && header == "" {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode script output: %w", err)
+		}
+
+		tag, lengthStr, found := strings.Cut(strings.TrimSuffix(header, "\n"), " ")
+		length, convErr := strconv.Atoi(lengthStr)
+		if !found || convErr != nil || length < 0 {
+			return nil, fmt.Errorf("failed to decode script output: bad record %q", header)
+		}
+		value := make([]byte, length)
+		if _, err := io.ReadFull(reader, value); err != nil {
+			return nil, fmt.Errorf("failed to decode script output: %w", err)
+		}
+
+		switch tag {
+		case "content":
+			result.Content = string(value)
+		case "key":
+			key = string(value)
+		case "value":
+			result.Metadata[key] = string(value)
+		case "blocked":
+			result.Blocked = true
+			result.Reason = string(value)
+		case "log":
+			result.Logs = append(result.Logs, string(value))
+		case "timeout":
+			return nil, ErrTimeout
+		case "memory":
+			return nil, ErrMemoryLimit
+		case "error":
+			return nil, &ScriptError{Message: string(value)}
+		}
+	}
+
+	result.finalize()
+	return result, nil
+}
+
+// toGeneric converts a value to its JSON-decoded generic form
+func toGeneric(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+	return generic, nil
+}
+
+// writeLuaValue writes a JSON-decoded value as a Lua literal
+func writeLuaValue(b *strings.Builder, v interface{}) {
+	switch value := v.(type) {
+	case nil:
+		b.WriteString("nil")
+	case bool:
+		b.WriteString(strconv.FormatBool(value))
+	case float64:
+		b.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	case string:
+		b.WriteString(luaQuote(value))
+	case []interface{}:
+		b.WriteString("{")
+		for _, item := range value {
+			writeLuaValue(b, item)
+			b.WriteString(",")
+		}
+		b.WriteString("}")
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		b.WriteString("{")
+		for _, k := range keys {
+			b.WriteString("[")
+			b.WriteString(luaQuote(k))
+			b.WriteString("]=")
+			writeLuaValue(b, value[k])
+			b.WriteString(",")
+		}
+		b.WriteString("}")
+	default:
+		b.WriteString("nil")
+	}
+}
+
+// luaQuote returns a Lua string literal, escaping every byte that is not plain
+// printable ASCII with a three-digit decimal escape understood by all Lua versions
+func luaQuote(s string) string {
+	var b strings.Builder
+	b.Grow(len(s) + 2)
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 32 || c >= 127 || c == '"' || c == '\\' {
+			fmt.Fprintf(&b, "\\%03d", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,76 @@
+package scripting
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ErrMemoryLimit is returned when a script exceeds its memory limit
+var ErrMemoryLimit = errors.New("script exceeded its memory limit")
+
+// limitedBuffer collects up to max bytes and remembers whether more were written
+type limitedBuffer struct {
+	buf      bytes.Buffer
+	max      int
+	overflow bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if remaining := b.max - b.buf.Len(); len(p) > remaining {
+		b.overflow = true
+		if remaining > 0 {
+			b.buf.Write(p[:remaining])
+		}
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+// runProcess runs an interpreter with an empty environment, feeding stdin and
+// returning stdout. The process is killed once the script timeout plus the
+// interpreter startup grace has passed.
+func runProcess(ctx context.Context, name string, args []string, stdin []byte, timeout time.Duration) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout+startupGrace)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = []string{}
+	cmd.Dir = os.TempDir()
+	cmd.Stdin = bytes.NewReader(stdin)
+
+	stdout := &limitedBuffer{max: MaxOutputBytes}
+	stderr := &limitedBuffer{max: 4096}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, ErrTimeout
+	}
+	if stdout.overflow {
+		return nil, ErrOutputTooLarge
+	}
+	if err != nil {
+		message := strings.TrimSpace(stderr.buf.String())
+		if isOutOfMemory(message) {
+			return nil, ErrMemoryLimit
+		}
+		if message == "" {
+			message = err.Error()
+		}
+		return nil, &ScriptError{Message: message}
+	}
+
+	return stdout.buf.Bytes(), nil
+}
+
+// isOutOfMemory reports whether interpreter output shows an allocation failure
+func isOutOfMemory(stderr string) bool {
+	lower := strings.ToLower(stderr)
+	return strings.Contains(lower, "out of memory") || strings.Contains(lower, "not enough memory")
+}
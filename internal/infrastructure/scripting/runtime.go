@@ -0,0 +1,150 @@
+// Package scripting runs tenant-provided scripts in sandboxed, resource limited
+// interpreter processes.
+//
+// Scripts never run inside the server process. Each execution starts a fresh
+// interpreter with an empty environment and a memory cap, feeds it the message
+// on stdin and kills it when the time limit passes. Inside the interpreter the
+// script only sees the message, the lookup results and a few helpers; module
+// loading, file and process access are not exposed.
+package scripting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Default and maximum resource limits of a script execution
+const (
+	DefaultTimeout  = 500 * time.Millisecond
+	MaxTimeout      = 5 * time.Second
+	DefaultMemoryMB = 64
+	MaxMemoryMB     = 256
+	MaxOutputBytes  = 1 << 20
+
+	// startupGrace is added to the script timeout for the interpreter to start
+	startupGrace = 2 * time.Second
+	// maxLogs caps the log lines a script can return
+	maxLogs = 50
+)
+
+var (
+	// ErrTimeout is returned when a script runs past its time limit
+	ErrTimeout = errors.New("script timed out")
+	// ErrOutputTooLarge is returned when a script produces too much output
+	ErrOutputTooLarge = errors.New("script output too large")
+)
+
+// ScriptError is an error raised by the script itself
+type ScriptError struct {
+	Message string
+}
+
+func (e *ScriptError) Error() string {
+	return "script error: " + e.Message
+}
+
+// Message is the message a script can read and transform
+type Message struct {
+	ID             string            `json:"id"`
+	ConversationID string            `json:"conversation_id"`
+	ChannelID      string            `json:"channel_id"`
+	ChannelType    string            `json:"channel_type"`
+	Direction      string            `json:"direction"`
+	SenderType     string            `json:"sender_type"`
+	ContentType    string            `json:"content_type"`
+	Content        string            `json:"content"`
+	Metadata       map[string]string `json:"metadata"`
+}
+
+// Input is everything a script can see
+type Input struct {
+	Event   string                 `json:"event"`
+	Message *Message               `json:"message"`
+	Lookups map[string]interface{} `json:"lookups"`
+}
+
+// Result is the outcome of a script: the possibly transformed content and
+// metadata, and whether the message must be blocked
+type Result struct {
+	Content  string            `json:"content"`
+	Metadata map[string]string `json:"metadata"`
+	Blocked  bool              `json:"blocked"`
+	Reason   string            `json:"reason,omitempty"`
+	Logs     []string          `json:"logs,omitempty"`
+}
+
+// Limits bounds the resources of a script execution
+type Limits struct {
+	Timeout  time.Duration
+	MemoryMB int
+}
+
+// normalize fills unset limits with defaults and caps them at the maximums
+func (l Limits) normalize() Limits {
+	if l.Timeout <= 0 {
+		l.Timeout = DefaultTimeout
+	}
+	if l.Timeout > MaxTimeout {
+		l.Timeout = MaxTimeout
+	}
+	if l.MemoryMB <= 0 {
+		l.MemoryMB = DefaultMemoryMB
+	}
+	if l.MemoryMB > MaxMemoryMB {
+		l.MemoryMB = MaxMemoryMB
+	}
+	return l
+}
+
+// Runtime executes scripts of one language
+type Runtime interface {
+	// Language returns the language the runtime executes
+	Language() string
+
+	// Run executes a script against the input within the limits
+	Run(ctx context.Context, script string, input *Input, limits Limits) (*Result, error)
+}
+
+// Registry holds the runtimes available to hooks, by language
+type Registry struct {
+	runtimes map[string]Runtime
+}
+
+// NewRegistry creates a registry with the given runtimes
+func NewRegistry(runtimes ...Runtime) *Registry {
+	r := &Registry{runtimes: make(map[string]Runtime)}
+	for _, runtime := range runtimes {
+		r.runtimes[runtime.Language()] = runtime
+	}
+	return r
+}
+
+// Get returns the runtime of a language
+func (r *Registry) Get(language string) (Runtime, error) {
+	runtime, ok := r.runtimes[language]
+	if !ok {
+		return nil, fmt.Errorf("no runtime available for language %q", language)
+	}
+	return runtime, nil
+}
+
+// Languages returns the languages with an available runtime
+func (r *Registry) Languages() []string {
+	languages := make([]string, 0, len(r.runtimes))
+	for language := range r.runtimes {
+		languages = append(languages, language)
+	}
+	return languages
+}
+
+// finalize caps what a script returned and fills nil maps
+func (r *Result) finalize() {
+	if r.Metadata == nil {
+		r.Metadata = make(map[string]string)
+	}
+	if len(r.Logs) > maxLogs {
+		r.Logs = r.Logs[:maxLogs]
+	}
+}
@@ -0,0 +1,207 @@
+package scripting
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testInput() *Input {
+	return &Input{
+		Event: "message.inbound",
+		Message: &Message{
+			ID:          "msg-1",
+			ContentType: "text",
+			Content:     "hello \"world\"\nçá",
+			Metadata:    map[string]string{"source": "test"},
+		},
+		Lookups: map[string]interface{}{
+			"crm": map[string]interface{}{"tier": "gold", "score": 42.0},
+		},
+	}
+}
+
+func newJavaScript(t *testing.T) *JavaScriptRuntime {
+	runtime, err := NewJavaScriptRuntime("")
+	if err != nil {
+		t.Skip("node not available")
+	}
+	return runtime
+}
+
+func newLua(t *testing.T) *LuaRuntime {
+	runtime, err := NewLuaRuntime("")
+	if err != nil {
+		t.Skip("lua not available")
+	}
+	return runtime
+}
+
+func TestJavaScriptTransformsMessage(t *testing.T) {
+	runtime := newJavaScript(t)
+
+	result, err := runtime.Run(context.Background(), `
+		message.content = message.content.toUpperCase();
+		message.metadata.tier = lookups.crm.tier;
+		log('event', event);
+	`, testInput(), Limits{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "HELLO \"WORLD\"\nÇÁ", result.Content)
+	assert.Equal(t, "gold", result.Metadata["tier"])
+	assert.Equal(t, "test", result.Metadata["source"])
+	assert.Equal(t, []string{"event message.inbound"}, result.Logs)
+	assert.False(t, result.Blocked)
+}
+
+func TestJavaScriptBlocksMessage(t *testing.T) {
+	runtime := newJavaScript(t)
+
+	result, err := runtime.Run(context.Background(), `if (message.content.includes('hello')) block('greeting');`, testInput(), Limits{})
+	require.NoError(t, err)
+	assert.True(t, result.Blocked)
+	assert.Equal(t, "greeting", result.Reason)
+}
+
+func TestJavaScriptSandbox(t *testing.T) {
+	runtime := newJavaScript(t)
+	ctx := context.Background()
+
+	for _, script := range []string{
+		`require('fs')`,
+		`require('child_process').execSync('id')`,
+		`process.exit(0)`,
+		`process.mainModule.require('fs')`,
+		`this.constructor.constructor('return process')()`,
+		`message.constructor.constructor('return process')()`,
+		`lookups.crm.constructor.constructor('return process')()`,
+		`block.constructor('return process')()`,
+		`log.constructor.constructor('return process')()`,
+		`eval('1')`,
+	} {
+		_, err := runtime.Run(ctx, script, testInput(), Limits{})
+		var scriptErr *ScriptError
+		assert.ErrorAs(t, err, &scriptErr, script)
+	}
+
+	// Even the host's own code has no file system or child process access
+	result, err := runtime.Run(ctx, `
+		const host = block.constructor.constructor;
+		try { host('return process')(); log('escaped'); } catch (e) { log(e.name); }
+	`, testInput(), Limits{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"EvalError"}, result.Logs)
+}
+
+func TestJavaScriptPermissions(t *testing.T) {
+	runtime := newJavaScript(t)
+	limits := Limits{}.normalize()
+	flags := javascriptFlags(limits)
+	assert.Contains(t, flags, "--experimental-permission")
+	for _, flag := range flags {
+		assert.False(t, strings.HasPrefix(flag, "--allow-"), flag)
+	}
+
+	// Code outside the sandbox context is denied too
+	for _, code := range []string{
+		`require('fs').readFileSync('/etc/hostname')`,
+		`require('child_process').execSync('id')`,
+	} {
+		_, err := runProcess(context.Background(), runtime.nodePath, append(flags, "-e", code), nil, limits.Timeout)
+		var scriptErr *ScriptError
+		require.ErrorAs(t, err, &scriptErr, code)
+		assert.Contains(t, scriptErr.Message, "ERR_ACCESS_DENIED", code)
+	}
+}
+
+func TestJavaScriptLimits(t *testing.T) {
+	runtime := newJavaScript(t)
+	ctx := context.Background()
+
+	_, err := runtime.Run(ctx, `while (true) {}`, testInput(), Limits{Timeout: 100 * time.Millisecond})
+	assert.ErrorIs(t, err, ErrTimeout)
+
+	_, err = runtime.Run(ctx, `const a = []; while (true) { a.push(new Array(100000).fill(1)); }`, testInput(), Limits{Timeout: MaxTimeout, MemoryMB: 16})
+	assert.ErrorIs(t, err, ErrMemoryLimit)
+}
+
+func TestLuaTransformsMessage(t *testing.T) {
+	runtime := newLua(t)
+
+	result, err := runtime.Run(context.Background(), `
+		message.content = string.upper(message.content)
+		message.metadata.tier = lookups.crm.tier
+		log("event", event)
+		if lookups.crm.score > 40 then block("high score") end
+	`, testInput(), Limits{})
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(result.Content, "HELLO \"WORLD\"\n"))
+	assert.Equal(t, "gold", result.Metadata["tier"])
+	assert.Equal(t, []string{"event message.inbound"}, result.Logs)
+	assert.True(t, result.Blocked)
+	assert.Equal(t, "high score", result.Reason)
+}
+
+func TestLuaSandbox(t *testing.T) {
+	runtime := newLua(t)
+	ctx := context.Background()
+
+	for _, script := range []string{
+		`os.execute("true")`,
+		`io.open("/etc/passwd")`,
+		`require("os")`,
+		`load("return 1")()`,
+	} {
+		_, err := runtime.Run(ctx, script, testInput(), Limits{})
+		var scriptErr *ScriptError
+		assert.ErrorAs(t, err, &scriptErr, script)
+	}
+
+	_, err := runtime.Run(ctx, `while true do pcall(function() while true do end end) end`, testInput(), Limits{Timeout: 100 * time.Millisecond})
+	assert.ErrorIs(t, err, ErrTimeout)
+}
+
+func TestLuaQuote(t *testing.T) {
+	assert.Equal(t, `"a\034b\092\010\195\167"`, luaQuote("a\"b\\\nç"))
+}
+
+func TestParseLuaOutput(t *testing.T) {
+	result, err := parseLuaOutput([]byte("content 5\nhi\nyokey 1\navalue 1\nblog 3\nx y"))
+	require.NoError(t, err)
+	assert.Equal(t, "hi\nyo", result.Content)
+	assert.Equal(t, map[string]string{"a": "b"}, result.Metadata)
+	assert.Equal(t, []string{"x y"}, result.Logs)
+
+	_, err = parseLuaOutput([]byte("timeout 0\n"))
+	assert.ErrorIs(t, err, ErrTimeout)
+
+	_, err = parseLuaOutput([]byte("content 10\nshort"))
+	assert.Error(t, err)
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry(&LuaRuntime{luaPath: "lua"})
+
+	runtime, err := registry.Get(LanguageLua)
+	require.NoError(t, err)
+	assert.Equal(t, LanguageLua, runtime.Language())
+
+	_, err = registry.Get(LanguageJavaScript)
+	assert.Error(t, err)
+	assert.Equal(t, []string{LanguageLua}, registry.Languages())
+}
+
+func TestLimitsNormalize(t *testing.T) {
+	limits := Limits{}.normalize()
+	assert.Equal(t, DefaultTimeout, limits.Timeout)
+	assert.Equal(t, DefaultMemoryMB, limits.MemoryMB)
+
+	limits = Limits{Timeout: time.Minute, MemoryMB: 4096}.normalize()
+	assert.Equal(t, MaxTimeout, limits.Timeout)
+	assert.Equal(t, MaxMemoryMB, limits.MemoryMB)
+}
@@ -0,0 +1,148 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AWSBackend reads secrets from AWS Secrets Manager. Secrets stored as a JSON
+// object expose its fields; any other secret string is exposed as DefaultField.
+type AWSBackend struct {
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+	endpoint        string
+	httpClient      *http.Client
+	now             func() time.Time
+}
+
+// NewAWSBackend creates a backend for Secrets Manager in region, authenticated
+// with static or session credentials
+func NewAWSBackend(region, accessKeyID, secretAccessKey, sessionToken string) *AWSBackend {
+	return &AWSBackend{
+		region:          region,
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+		sessionToken:    sessionToken,
+		endpoint:        fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", region),
+		httpClient:      &http.Client{Timeout: 10 * time.Second},
+		now:             time.Now,
+	}
+}
+
+// Name identifies the backend
+func (b *AWSBackend) Name() string {
+	return "aws"
+}
+
+// Read returns the current version of the secret named or identified by path
+func (b *AWSBackend) Read(ctx context.Context, path string) (*Secret, error) {
+	payload, err := json.Marshal(map[string]string{"SecretId": path})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.endpoint+"/", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	b.signRequest(req, payload)
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("secrets manager request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets manager response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("secrets manager returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	var result struct {
+		SecretString *string `json:"SecretString"`
+		VersionID    string  `json:"VersionId"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	if result.SecretString == nil {
+		return nil, fmt.Errorf("secret %s has no string value", path)
+	}
+
+	secret := &Secret{Data: make(map[string]string), Version: result.VersionID}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(*result.SecretString), &fields); err != nil {
+		secret.Data[DefaultField] = *result.SecretString
+		return secret, nil
+	}
+	for key, value := range fields {
+		if s, ok := value.(string); ok {
+			secret.Data[key] = s
+		} else if value != nil {
+			encoded, _ := json.Marshal(value)
+			secret.Data[key] = string(encoded)
+		}
+	}
+	return secret, nil
+}
+
+// signRequest signs a request with AWS Signature Version 4
+func (b *AWSBackend) signRequest(req *http.Request, body []byte) {
+	now := b.now().UTC()
+	dateStamp := now.Format("20060102")
+	amzDate := now.Format("20060102T150405Z")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if b.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", b.sessionToken)
+	}
+
+	signedHeaders := "content-type;host;x-amz-date;x-amz-target"
+	canonicalHeaders := fmt.Sprintf("content-type:%s\nhost:%s\nx-amz-date:%s\nx-amz-target:%s\n",
+		req.Header.Get("Content-Type"), req.URL.Host, amzDate, req.Header.Get("X-Amz-Target"))
+	if b.sessionToken != "" {
+		signedHeaders += ";x-amz-security-token"
+		canonicalHeaders += "x-amz-security-token:" + b.sessionToken + "\n"
+	}
+
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n%s",
+		req.Method, req.URL.Path, req.URL.RawQuery, canonicalHeaders, signedHeaders, hex.EncodeToString(payloadHash[:]))
+
+	algorithm := "AWS4-HMAC-SHA256"
+	credentialScope := fmt.Sprintf("%s/%s/secretsmanager/aws4_request", dateStamp, b.region)
+	canonicalRequestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := fmt.Sprintf("%s\n%s\n%s\n%s",
+		algorithm, amzDate, credentialScope, hex.EncodeToString(canonicalRequestHash[:]))
+
+	kDate := hmacSHA256([]byte("AWS4"+b.secretAccessKey), []byte(dateStamp))
+	kRegion := hmacSHA256(kDate, []byte(b.region))
+	kService := hmacSHA256(kRegion, []byte("secretsmanager"))
+	kSigning := hmacSHA256(kService, []byte("aws4_request"))
+	signature := hex.EncodeToString(hmacSHA256(kSigning, []byte(stringToSign)))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		algorithm, b.accessKeyID, credentialScope, signedHeaders, signature))
+}
+
+func hmacSHA256(key, data []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write(data)
+	return h.Sum(nil)
+}
@@ -0,0 +1,270 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/msgfy/linktor/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// DefaultTTL is how long a secret is served from cache before it is read again
+const DefaultTTL = 5 * time.Minute
+
+// Resolver resolves secret references against the registered backends. Secrets
+// are cached for a TTL, leases are renewed before they expire and hooks are told
+// when a secret in use changes, so callers can pick up rotated credentials
+// without a restart.
+type Resolver struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu       sync.Mutex
+	backends map[string]Backend
+	cache    map[string]*cachedSecret
+	hooks    []RotationHook
+}
+
+type cachedSecret struct {
+	backend   string
+	path      string
+	secret    *Secret
+	refreshAt time.Time
+}
+
+// NewResolver creates a resolver caching secrets for ttl, DefaultTTL when zero
+func NewResolver(ttl time.Duration) *Resolver {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Resolver{
+		ttl:      ttl,
+		now:      time.Now,
+		backends: make(map[string]Backend),
+		cache:    make(map[string]*cachedSecret),
+	}
+}
+
+// Register makes a backend available to references by its name
+func (r *Resolver) Register(backend Backend) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.backends[backend.Name()] = backend
+}
+
+// Backends lists the names of the registered backends
+func (r *Resolver) Backends() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names := make([]string, 0, len(r.backends))
+	for name := range r.backends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// OnRotate adds a hook called after a cached secret changed
+func (r *Resolver) OnRotate(hook RotationHook) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks = append(r.hooks, hook)
+}
+
+// Resolve returns the secret a value references, or the value itself when it is
+// not a reference
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	if !IsRef(value) {
+		return value, nil
+	}
+	ref, err := ParseRef(value)
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := r.secret(ctx, ref.Backend, ref.Path)
+	if err != nil {
+		return "", err
+	}
+	field, ok := secret.Data[ref.Field]
+	if !ok {
+		return "", fmt.Errorf("secret %s has no field %q", ref.Secret(), ref.Field)
+	}
+	return field, nil
+}
+
+// ResolveMap resolves the references among values. The result holds every value,
+// references that failed to resolve left as they are and reported in the error.
+func (r *Resolver) ResolveMap(ctx context.Context, values map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string, len(values))
+	var failed []string
+	for key, value := range values {
+		resolved[key] = value
+		if !IsRef(value) {
+			continue
+		}
+		secret, err := r.Resolve(ctx, value)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", key, err))
+			continue
+		}
+		resolved[key] = secret
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return resolved, fmt.Errorf("failed to resolve secrets: %s", strings.Join(failed, "; "))
+	}
+	return resolved, nil
+}
+
+// Refresh renews backend credentials and leases, and reads again the cached
+// secrets that are due, calling the rotation hooks for those that changed
+func (r *Resolver) Refresh(ctx context.Context) {
+	r.mu.Lock()
+	backends := make([]Backend, 0, len(r.backends))
+	for _, backend := range r.backends {
+		backends = append(backends, backend)
+	}
+	now := r.now()
+	var due []*cachedSecret
+	for _, entry := range r.cache {
+		if !now.Before(entry.refreshAt) {
+			due = append(due, entry)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, backend := range backends {
+		if renewer, ok := backend.(Renewer); ok {
+			if err := renewer.Renew(ctx); err != nil {
+				logger.Warn("Failed to renew secrets backend credentials", zap.String("backend", backend.Name()), zap.Error(err))
+			}
+		}
+	}
+
+	for _, entry := range due {
+		if r.renewLease(ctx, entry) {
+			continue
+		}
+		if _, err := r.load(ctx, entry.backend, entry.path); err != nil {
+			logger.Warn("Failed to refresh secret, serving the cached version",
+				zap.String("secret", Ref{Backend: entry.backend, Path: entry.path}.Secret()), zap.Error(err))
+		}
+	}
+}
+
+// Run refreshes secrets every interval until ctx is done
+func (r *Resolver) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Refresh(ctx)
+		}
+	}
+}
+
+// secret returns a secret from cache, reading it when missing or due. A due
+// secret that cannot be read is served from cache until the backend recovers.
+func (r *Resolver) secret(ctx context.Context, backend, path string) (*Secret, error) {
+	r.mu.Lock()
+	entry, ok := r.cache[cacheKey(backend, path)]
+	r.mu.Unlock()
+	if ok && r.now().Before(entry.refreshAt) {
+		return entry.secret, nil
+	}
+
+	secret, err := r.load(ctx, backend, path)
+	if err != nil {
+		if ok {
+			logger.Warn("Failed to refresh secret, serving the cached version",
+				zap.String("secret", Ref{Backend: backend, Path: path}.Secret()), zap.Error(err))
+			return entry.secret, nil
+		}
+		return nil, err
+	}
+	return secret, nil
+}
+
+// load reads a secret into the cache and calls the rotation hooks when it
+// replaced a different version
+func (r *Resolver) load(ctx context.Context, backend, path string) (*Secret, error) {
+	r.mu.Lock()
+	b, ok := r.backends[backend]
+	r.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown secrets backend %q", backend)
+	}
+
+	secret, err := b.Read(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	key := cacheKey(backend, path)
+	previous, cached := r.cache[key]
+	r.cache[key] = &cachedSecret{backend: backend, path: path, secret: secret, refreshAt: r.refreshAt(secret.LeaseDuration)}
+	hooks := append([]RotationHook(nil), r.hooks...)
+	r.mu.Unlock()
+
+	if cached && rotated(previous.secret, secret) {
+		rotation := Rotation{Backend: backend, Path: path, Version: secret.Version}
+		logger.Info("Secret rotated", zap.String("secret", rotation.Secret()), zap.String("version", secret.Version))
+		for _, hook := range hooks {
+			hook(ctx, rotation)
+		}
+	}
+	return secret, nil
+}
+
+// renewLease extends the lease of a renewable secret, reporting whether it did
+func (r *Resolver) renewLease(ctx context.Context, entry *cachedSecret) bool {
+	if entry.secret.LeaseID == "" || !entry.secret.Renewable {
+		return false
+	}
+	r.mu.Lock()
+	renewer, ok := r.backends[entry.backend].(LeaseRenewer)
+	r.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	duration, err := renewer.RenewLease(ctx, entry.secret)
+	if err != nil || duration <= 0 {
+		return false
+	}
+	r.mu.Lock()
+	entry.refreshAt = r.refreshAt(duration)
+	r.mu.Unlock()
+	return true
+}
+
+// refreshAt returns when a secret read now is due, within two thirds of its lease
+func (r *Resolver) refreshAt(lease time.Duration) time.Time {
+	wait := r.ttl
+	if lease > 0 && lease*2/3 < wait {
+		wait = lease * 2 / 3
+	}
+	return r.now().Add(wait)
+}
+
+// rotated reports whether a secret changed, by version when the backend has one
+func rotated(previous, current *Secret) bool {
+	if previous.Version != "" || current.Version != "" {
+		return previous.Version != current.Version
+	}
+	return !maps.Equal(previous.Data, current.Data)
+}
+
+func cacheKey(backend, path string) string {
+	return backend + "/" + path
+}
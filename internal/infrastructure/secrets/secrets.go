@@ -0,0 +1,115 @@
+// Package secrets resolves provider credentials from external secrets backends,
+// so channel credentials and AI API keys can be referenced by secret path instead
+// of being stored in PostgreSQL or the environment.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RefScheme prefixes values that reference a secret instead of holding it
+const RefScheme = "secret://"
+
+// DefaultField is the field read when a reference names none. Backends store
+// secrets that are a plain string under it.
+const DefaultField = "value"
+
+// Secret is a secret read from a backend
+type Secret struct {
+	Data          map[string]string
+	Version       string        // Changes when the secret is rotated
+	LeaseID       string        // Set for leased (dynamic) secrets
+	LeaseDuration time.Duration // Zero when the secret does not expire
+	Renewable     bool
+}
+
+// Backend reads secrets from a secrets manager
+type Backend interface {
+	// Name identifies the backend in references, as in secret://<name>/<path>
+	Name() string
+	// Read returns the current version of the secret at path
+	Read(ctx context.Context, path string) (*Secret, error)
+}
+
+// LeaseRenewer is implemented by backends whose leased secrets can be extended
+type LeaseRenewer interface {
+	// RenewLease extends the lease of a secret and returns its new duration
+	RenewLease(ctx context.Context, secret *Secret) (time.Duration, error)
+}
+
+// Renewer is implemented by backends holding credentials of their own that
+// expire, such as a Vault token. Renew is called periodically and should only
+// contact the backend once the credentials are due.
+type Renewer interface {
+	Renew(ctx context.Context) error
+}
+
+// Ref is a parsed secret reference: secret://<backend>/<path>#<field>
+type Ref struct {
+	Backend string
+	Path    string
+	Field   string
+}
+
+// IsRef reports whether a value references a secret
+func IsRef(value string) bool {
+	return strings.HasPrefix(value, RefScheme)
+}
+
+// ParseRef parses a secret reference. The field defaults to DefaultField.
+func ParseRef(value string) (Ref, error) {
+	if !IsRef(value) {
+		return Ref{}, fmt.Errorf("not a secret reference")
+	}
+	rest := strings.TrimPrefix(value, RefScheme)
+
+	var ref Ref
+	if i := strings.LastIndex(rest, "#"); i >= 0 {
+		ref.Field = rest[i+1:]
+		rest = rest[:i]
+	}
+	backend, path, ok := strings.Cut(rest, "/")
+	if !ok || backend == "" || strings.Trim(path, "/") == "" {
+		return Ref{}, fmt.Errorf("secret reference must be formatted as %s<backend>/<path>#<field>", RefScheme)
+	}
+	ref.Backend = backend
+	ref.Path = strings.Trim(path, "/")
+	if ref.Field == "" {
+		ref.Field = DefaultField
+	}
+	return ref, nil
+}
+
+// Secret returns the reference of the whole secret, without the field
+func (r Ref) Secret() string {
+	return RefScheme + r.Backend + "/" + r.Path
+}
+
+// String formats the reference
+func (r Ref) String() string {
+	return r.Secret() + "#" + r.Field
+}
+
+// Rotation tells that the secret of a backend changed
+type Rotation struct {
+	Backend string
+	Path    string
+	Version string
+}
+
+// Secret returns the reference of the rotated secret, without field
+func (r Rotation) Secret() string {
+	return Ref{Backend: r.Backend, Path: r.Path}.Secret()
+}
+
+// Affects reports whether a reference points into the rotated secret
+func (r Rotation) Affects(value string) bool {
+	ref, err := ParseRef(value)
+	return err == nil && ref.Backend == r.Backend && ref.Path == r.Path
+}
+
+// RotationHook is called after a secret in use was rotated
+type RotationHook func(ctx context.Context, rotation Rotation)
@@ -0,0 +1,217 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryBackend serves secrets from memory and counts its reads
+type memoryBackend struct {
+	secrets map[string]*Secret
+	reads   int
+	fail    bool
+	renewed int
+}
+
+func (b *memoryBackend) Name() string {
+	return "memory"
+}
+
+func (b *memoryBackend) Read(ctx context.Context, path string) (*Secret, error) {
+	b.reads++
+	if b.fail {
+		return nil, fmt.Errorf("backend unavailable")
+	}
+	secret, ok := b.secrets[path]
+	if !ok {
+		return nil, fmt.Errorf("secret %s not found", path)
+	}
+	return secret, nil
+}
+
+func (b *memoryBackend) RenewLease(ctx context.Context, secret *Secret) (time.Duration, error) {
+	b.renewed++
+	return secret.LeaseDuration, nil
+}
+
+func TestParseRef(t *testing.T) {
+	ref, err := ParseRef("secret://vault/linktor/meta/waba#access_token")
+	require.NoError(t, err)
+	assert.Equal(t, Ref{Backend: "vault", Path: "linktor/meta/waba", Field: "access_token"}, ref)
+	assert.Equal(t, "secret://vault/linktor/meta/waba", ref.Secret())
+
+	ref, err = ParseRef("secret://aws/prod/openai")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultField, ref.Field)
+
+	for _, value := range []string{"plain", "secret://vault", "secret://vault/", "secret:///path"} {
+		_, err := ParseRef(value)
+		assert.Error(t, err, value)
+	}
+
+	rotation := Rotation{Backend: "vault", Path: "linktor/meta/waba"}
+	assert.True(t, rotation.Affects("secret://vault/linktor/meta/waba#app_secret"))
+	assert.False(t, rotation.Affects("secret://vault/linktor/meta/waba-2#app_secret"))
+	assert.False(t, rotation.Affects("plain"))
+}
+
+func TestResolver_CachesAndRotates(t *testing.T) {
+	backend := &memoryBackend{secrets: map[string]*Secret{
+		"openai": {Data: map[string]string{"api_key": "sk-1"}, Version: "1"},
+	}}
+	resolver := NewResolver(time.Minute)
+	resolver.Register(backend)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	resolver.now = func() time.Time { return now }
+
+	var rotations []Rotation
+	resolver.OnRotate(func(ctx context.Context, rotation Rotation) {
+		rotations = append(rotations, rotation)
+	})
+
+	ctx := context.Background()
+	value, err := resolver.Resolve(ctx, "secret://memory/openai#api_key")
+	require.NoError(t, err)
+	assert.Equal(t, "sk-1", value)
+
+	value, err = resolver.Resolve(ctx, "plain-key")
+	require.NoError(t, err)
+	assert.Equal(t, "plain-key", value, "values that are not references are returned as they are")
+
+	_, err = resolver.Resolve(ctx, "secret://memory/openai#missing")
+	assert.Error(t, err)
+	_, err = resolver.Resolve(ctx, "secret://unknown/openai#api_key")
+	assert.Error(t, err)
+	assert.Equal(t, 1, backend.reads, "secrets are served from cache")
+
+	// The secret is rotated in the backend and read again once due
+	backend.secrets["openai"] = &Secret{Data: map[string]string{"api_key": "sk-2"}, Version: "2"}
+	resolver.Refresh(ctx)
+	assert.Empty(t, rotations, "secrets are not read before the TTL")
+
+	now = now.Add(time.Minute)
+	resolver.Refresh(ctx)
+	require.Len(t, rotations, 1)
+	assert.Equal(t, Rotation{Backend: "memory", Path: "openai", Version: "2"}, rotations[0])
+
+	value, err = resolver.Resolve(ctx, "secret://memory/openai#api_key")
+	require.NoError(t, err)
+	assert.Equal(t, "sk-2", value)
+
+	// A backend outage serves the cached version
+	backend.fail = true
+	now = now.Add(time.Minute)
+	value, err = resolver.Resolve(ctx, "secret://memory/openai#api_key")
+	require.NoError(t, err)
+	assert.Equal(t, "sk-2", value)
+	assert.Len(t, rotations, 1)
+}
+
+func TestResolver_ResolveMapAndLeases(t *testing.T) {
+	backend := &memoryBackend{secrets: map[string]*Secret{
+		"db": {Data: map[string]string{"value": "pw"}, LeaseID: "lease-1", LeaseDuration: 30 * time.Second, Renewable: true},
+	}}
+	resolver := NewResolver(time.Hour)
+	resolver.Register(backend)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	resolver.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	resolved, err := resolver.ResolveMap(ctx, map[string]string{
+		"password": "secret://memory/db",
+		"user":     "linktor",
+		"token":    "secret://memory/missing#token",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "token")
+	assert.Equal(t, map[string]string{
+		"password": "pw",
+		"user":     "linktor",
+		"token":    "secret://memory/missing#token",
+	}, resolved)
+
+	// Leased secrets are renewed within two thirds of their lease
+	now = now.Add(19 * time.Second)
+	resolver.Refresh(ctx)
+	assert.Equal(t, 0, backend.renewed)
+	now = now.Add(time.Second)
+	resolver.Refresh(ctx)
+	assert.Equal(t, 1, backend.renewed)
+	assert.Equal(t, 2, backend.reads, "renewed secrets are not read again")
+}
+
+func TestVaultBackend(t *testing.T) {
+	var renewals int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "vault-token", r.Header.Get("X-Vault-Token"))
+		switch r.URL.Path {
+		case "/v1/kv/data/linktor/openai":
+			_, _ = w.Write([]byte(`{"data":{"data":{"api_key":"sk-1","port":5432},"metadata":{"version":3}}}`))
+		case "/v1/auth/token/renew-self":
+			renewals++
+			_, _ = w.Write([]byte(`{"auth":{"lease_duration":3600,"renewable":true}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	backend := NewVaultBackend(server.URL+"/", "vault-token", "kv")
+	secret, err := backend.Read(context.Background(), "linktor/openai")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"api_key": "sk-1", "port": "5432"}, secret.Data)
+	assert.Equal(t, "3", secret.Version)
+
+	_, err = backend.Read(context.Background(), "linktor/missing")
+	assert.Error(t, err)
+
+	require.NoError(t, backend.Renew(context.Background()))
+	require.NoError(t, backend.Renew(context.Background()))
+	assert.Equal(t, 1, renewals, "the token is renewed once half of its TTL passed")
+}
+
+func TestAWSBackend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secretsmanager.GetSecretValue", r.Header.Get("X-Amz-Target"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/"))
+		assert.Contains(t, r.Header.Get("Authorization"), "/us-east-1/secretsmanager/aws4_request")
+		assert.Equal(t, "session", r.Header.Get("X-Amz-Security-Token"))
+
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		switch body["SecretId"] {
+		case "prod/meta":
+			_, _ = w.Write([]byte(`{"SecretString":"{\"access_token\":\"EAAB\"}","VersionId":"v-2"}`))
+		case "prod/openai":
+			_, _ = w.Write([]byte(`{"SecretString":"sk-1","VersionId":"v-1"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"ResourceNotFoundException"}`))
+		}
+	}))
+	defer server.Close()
+
+	backend := NewAWSBackend("us-east-1", "AKID", "secret", "session")
+	backend.endpoint = server.URL
+
+	secret, err := backend.Read(context.Background(), "prod/meta")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"access_token": "EAAB"}, secret.Data)
+	assert.Equal(t, "v-2", secret.Version)
+
+	secret, err = backend.Read(context.Background(), "prod/openai")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{DefaultField: "sk-1"}, secret.Data, "plain strings are exposed as the default field")
+
+	_, err = backend.Read(context.Background(), "prod/missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,170 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// VaultBackend reads secrets from the KV version 2 engine of HashiCorp Vault and
+// keeps its token alive by renewing it
+type VaultBackend struct {
+	address    string
+	mount      string
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	renewable bool
+	renewAt   time.Time
+}
+
+// NewVaultBackend creates a backend for the Vault server at address. The KV
+// engine is expected at the "secret" mount when mount is empty.
+func NewVaultBackend(address, token, mount string) *VaultBackend {
+	if mount == "" {
+		mount = "secret"
+	}
+	return &VaultBackend{
+		address:    strings.TrimRight(address, "/"),
+		mount:      strings.Trim(mount, "/"),
+		token:      token,
+		renewable:  true,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		now:        time.Now,
+	}
+}
+
+// Name identifies the backend
+func (b *VaultBackend) Name() string {
+	return "vault"
+}
+
+// Read returns the latest version of the secret at path
+func (b *VaultBackend) Read(ctx context.Context, path string) (*Secret, error) {
+	var resp struct {
+		LeaseID       string `json:"lease_id"`
+		LeaseDuration int    `json:"lease_duration"`
+		Renewable     bool   `json:"renewable"`
+		Data          struct {
+			Data     map[string]interface{} `json:"data"`
+			Metadata struct {
+				Version int `json:"version"`
+			} `json:"metadata"`
+		} `json:"data"`
+	}
+	endpoint := fmt.Sprintf("/v1/%s/data/%s", b.mount, strings.Trim(path, "/"))
+	if err := b.call(ctx, http.MethodGet, endpoint, nil, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Data.Data == nil {
+		return nil, fmt.Errorf("vault secret %s has no data", path)
+	}
+
+	secret := &Secret{
+		Data:          make(map[string]string, len(resp.Data.Data)),
+		Version:       strconv.Itoa(resp.Data.Metadata.Version),
+		LeaseID:       resp.LeaseID,
+		LeaseDuration: time.Duration(resp.LeaseDuration) * time.Second,
+		Renewable:     resp.Renewable,
+	}
+	for key, value := range resp.Data.Data {
+		switch v := value.(type) {
+		case string:
+			secret.Data[key] = v
+		case nil:
+		default:
+			encoded, _ := json.Marshal(v)
+			secret.Data[key] = string(encoded)
+		}
+	}
+	return secret, nil
+}
+
+// RenewLease extends the lease of a leased secret
+func (b *VaultBackend) RenewLease(ctx context.Context, secret *Secret) (time.Duration, error) {
+	var resp struct {
+		LeaseDuration int `json:"lease_duration"`
+	}
+	body := map[string]interface{}{"lease_id": secret.LeaseID}
+	if secret.LeaseDuration > 0 {
+		body["increment"] = int(secret.LeaseDuration.Seconds())
+	}
+	if err := b.call(ctx, http.MethodPut, "/v1/sys/leases/renew", body, &resp); err != nil {
+		return 0, err
+	}
+	return time.Duration(resp.LeaseDuration) * time.Second, nil
+}
+
+// Renew renews the Vault token once half of its TTL has passed. Tokens that
+// cannot be renewed, such as root tokens, are left alone.
+func (b *VaultBackend) Renew(ctx context.Context) error {
+	b.mu.Lock()
+	due := b.renewable && !b.now().Before(b.renewAt)
+	b.mu.Unlock()
+	if !due {
+		return nil
+	}
+
+	var resp struct {
+		Auth struct {
+			LeaseDuration int  `json:"lease_duration"`
+			Renewable     bool `json:"renewable"`
+		} `json:"auth"`
+	}
+	if err := b.call(ctx, http.MethodPost, "/v1/auth/token/renew-self", map[string]interface{}{}, &resp); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ttl := time.Duration(resp.Auth.LeaseDuration) * time.Second
+	b.renewable = resp.Auth.Renewable && ttl > 0
+	b.renewAt = b.now().Add(ttl / 2)
+	return nil
+}
+
+func (b *VaultBackend) call(ctx context.Context, method, endpoint string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, b.address+endpoint, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	b.mu.Lock()
+	req.Header.Set("X-Vault-Token", b.token)
+	b.mu.Unlock()
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("vault request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read vault response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("vault %s returned status %d: %s", endpoint, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,297 @@
+package sso
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// OIDCConfig configures an OpenID Connect identity provider
+type OIDCConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string // openid is always requested; email and profile when none are set
+	EmailClaim   string   // Defaults to email
+	NameClaim    string   // Defaults to name
+	GroupsClaim  string   // Defaults to groups
+}
+
+// OIDCProvider signs users in with an OpenID Connect identity provider
+type OIDCProvider struct {
+	config     OIDCConfig
+	httpClient *http.Client
+	now        func() time.Time
+}
+
+// NewOIDCProvider creates an OpenID Connect provider
+func NewOIDCProvider(config OIDCConfig) *OIDCProvider {
+	if config.EmailClaim == "" {
+		config.EmailClaim = "email"
+	}
+	if config.NameClaim == "" {
+		config.NameClaim = "name"
+	}
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+	return &OIDCProvider{
+		config:     config,
+		httpClient: newHTTPClient(),
+		now:        time.Now,
+	}
+}
+
+// oidcDiscovery is the part of the provider's discovery document we use
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// AuthURL returns the URL of the provider's login page. The provider redirects back
+// to the redirect URL with the state and an authorization code.
+func (p *OIDCProvider) AuthURL(ctx context.Context, state, nonce string) (string, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	scopes := []string{"openid"}
+	configured := p.config.Scopes
+	if len(configured) == 0 {
+		configured = []string{"email", "profile"}
+	}
+	for _, scope := range configured {
+		if scope != "openid" {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	authURL, err := url.Parse(discovery.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization endpoint: %w", err)
+	}
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", p.config.RedirectURL)
+	query.Set("scope", strings.Join(scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	authURL.RawQuery = query.Encode()
+	return authURL.String(), nil
+}
+
+// Exchange redeems the authorization code of a callback and returns the user the
+// verified ID token identifies. The nonce must be the one the login started with.
+func (p *OIDCProvider) Exchange(ctx context.Context, code, nonce string) (*Identity, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {p.config.RedirectURL},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+
+	var token struct {
+		IDToken     string `json:"id_token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := do(p.httpClient, req, &token); err != nil {
+		return nil, err
+	}
+	if token.IDToken == "" {
+		return nil, invalid("token response has no ID token")
+	}
+
+	claims, err := p.verifyIDToken(ctx, discovery, token.IDToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	// Providers that keep ID tokens small only return the profile from the userinfo endpoint
+	if stringClaim(claims, p.config.EmailClaim) == "" && discovery.UserinfoEndpoint != "" && token.AccessToken != "" {
+		var userinfo map[string]interface{}
+		if err := getJSON(ctx, p.httpClient, discovery.UserinfoEndpoint, token.AccessToken, &userinfo); err != nil {
+			return nil, err
+		}
+		if stringClaim(userinfo, "sub") != stringClaim(claims, "sub") {
+			return nil, invalid("userinfo is for another subject")
+		}
+		for name, value := range userinfo {
+			if _, ok := claims[name]; !ok {
+				claims[name] = value
+			}
+		}
+	}
+
+	if verified, ok := claims["email_verified"].(bool); ok && !verified {
+		return nil, invalid("email address is not verified")
+	}
+	return &Identity{
+		Subject: stringClaim(claims, "sub"),
+		Email:   stringClaim(claims, p.config.EmailClaim),
+		Name:    stringClaim(claims, p.config.NameClaim),
+		Groups:  stringsClaim(claims, p.config.GroupsClaim),
+	}, nil
+}
+
+// discover fetches the provider's discovery document
+func (p *OIDCProvider) discover(ctx context.Context) (*oidcDiscovery, error) {
+	issuer := strings.TrimSuffix(p.config.Issuer, "/")
+	var discovery oidcDiscovery
+	if err := getJSON(ctx, p.httpClient, issuer+"/.well-known/openid-configuration", "", &discovery); err != nil {
+		return nil, err
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != issuer {
+		return nil, invalid("discovery document is for issuer %q", discovery.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, invalid("discovery document is incomplete")
+	}
+	return &discovery, nil
+}
+
+// idTokenMethods are the signing algorithms accepted for ID tokens
+var idTokenMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// verifyIDToken checks the signature, issuer, audience, expiry and nonce of an ID token
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, discovery *oidcDiscovery, raw, nonce string) (jwt.MapClaims, error) {
+	var keys struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := getJSON(ctx, p.httpClient, discovery.JWKSURI, "", &keys); err != nil {
+		return nil, err
+	}
+
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(idTokenMethods),
+		jwt.WithIssuer(discovery.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(clockSkew),
+		jwt.WithTimeFunc(p.now),
+	)
+	claims := jwt.MapClaims{}
+	_, err := parser.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		for _, key := range keys.Keys {
+			if key.Use == "enc" || (kid != "" && key.Kid != kid) {
+				continue
+			}
+			return key.publicKey()
+		}
+		return nil, fmt.Errorf("no signing key %q", kid)
+	})
+	if err != nil {
+		return nil, invalid("ID token: %v", err)
+	}
+
+	if stringClaim(claims, "nonce") != nonce {
+		return nil, invalid("ID token nonce does not match the login")
+	}
+	if azp := stringClaim(claims, "azp"); azp != "" && azp != p.config.ClientID {
+		return nil, invalid("ID token was issued to another client")
+	}
+	if stringClaim(claims, "sub") == "" {
+		return nil, invalid("ID token has no subject")
+	}
+	return claims, nil
+}
+
+// jwk is a public key of the provider's key set
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA exponent: %w", err)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC key: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC key: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+func stringClaim(claims map[string]interface{}, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+// stringsClaim reads a claim holding a list of strings, or a single one
+func stringsClaim(claims map[string]interface{}, name string) []string {
+	switch value := claims[name].(type) {
+	case string:
+		if value != "" {
+			return []string{value}
+		}
+	case []interface{}:
+		var values []string
+		for _, item := range value {
+			if s, ok := item.(string); ok && s != "" {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
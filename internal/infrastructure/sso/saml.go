@@ -0,0 +1,329 @@
+package sso
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/beevik/etree"
+)
+
+// SAML namespaces and values
+const (
+	nsSAMLProtocol  = "urn:oasis:names:tc:SAML:2.0:protocol"
+	nsSAMLAssertion = "urn:oasis:names:tc:SAML:2.0:assertion"
+	statusSuccess   = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	bindingPOST     = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	nameIDEmail     = "urn:oasis:names:tc:SAML:1.1:nameid-format:emailAddress"
+	bearerMethod    = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+)
+
+// Attribute names identity providers commonly use, tried when none is configured
+var (
+	defaultEmailAttributes = []string{"email", "mail", "emailAddress", "http://schemas.xmlsoap.org/ws/2005/05/identity/claims/emailaddress", "urn:oid:0.9.2342.19200300.100.1.3"}
+	defaultNameAttributes  = []string{"name", "displayName", "http://schemas.xmlsoap.org/ws/2005/05/identity/claims/name", "urn:oid:2.16.840.1.113730.3.1.241"}
+	defaultGroupAttributes = []string{"groups", "memberOf", "http://schemas.microsoft.com/ws/2008/06/identity/claims/groups"}
+)
+
+// SAMLConfig configures a SAML 2.0 identity provider
+type SAMLConfig struct {
+	EntityID        string // Our service provider entity ID
+	ACSURL          string // Assertion consumer service URL the provider posts responses to
+	IDPEntityID     string // Issuer of the provider's assertions
+	IDPSSOURL       string // Single sign-on URL of the provider
+	IDPCertificate  string // PEM certificate the provider signs with
+	EmailAttribute  string // Defaults to the common email attributes, then the NameID
+	NameAttribute   string
+	GroupsAttribute string
+}
+
+// SAMLProvider signs users in with a SAML 2.0 identity provider
+type SAMLProvider struct {
+	config SAMLConfig
+	cert   *x509.Certificate
+	now    func() time.Time
+}
+
+// NewSAMLProvider creates a SAML provider, failing when the issuer is missing or the
+// certificate can't be read
+func NewSAMLProvider(config SAMLConfig) (*SAMLProvider, error) {
+	if config.IDPEntityID == "" {
+		return nil, fmt.Errorf("identity provider entity ID is required")
+	}
+	cert, err := ParseCertificate(config.IDPCertificate)
+	if err != nil {
+		return nil, err
+	}
+	return &SAMLProvider{config: config, cert: cert, now: time.Now}, nil
+}
+
+// ParseCertificate reads a PEM certificate, or the base64 DER body of one as
+// identity provider metadata shows it
+func ParseCertificate(data string) (*x509.Certificate, error) {
+	data = strings.TrimSpace(data)
+	if block, _ := pem.Decode([]byte(data)); block != nil {
+		return x509.ParseCertificate(block.Bytes)
+	}
+	der, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(data), ""))
+	if err != nil {
+		return nil, fmt.Errorf("certificate is neither PEM nor base64")
+	}
+	return x509.ParseCertificate(der)
+}
+
+// AuthURL returns the URL sending the user to the provider with an AuthnRequest over
+// the HTTP-Redirect binding. The provider posts its response to the ACS URL with the
+// relay state.
+func (p *SAMLProvider) AuthURL(requestID, relayState string) (string, error) {
+	request := fmt.Sprintf(`<samlp:AuthnRequest xmlns:samlp="%s" xmlns:saml="%s" ID="%s" Version="2.0" IssueInstant="%s" Destination="%s" AssertionConsumerServiceURL="%s" ProtocolBinding="%s">`+
+		`<saml:Issuer>%s</saml:Issuer><samlp:NameIDPolicy Format="%s" AllowCreate="true"/></samlp:AuthnRequest>`,
+		nsSAMLProtocol, nsSAMLAssertion, requestID, p.now().UTC().Format(time.RFC3339),
+		escapeAttr(p.config.IDPSSOURL), escapeAttr(p.config.ACSURL), bindingPOST,
+		escapeText(p.config.EntityID), nameIDEmail)
+
+	var compressed bytes.Buffer
+	writer, _ := flate.NewWriter(&compressed, flate.DefaultCompression)
+	writer.Write([]byte(request))
+	writer.Close()
+
+	ssoURL, err := url.Parse(p.config.IDPSSOURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid single sign-on URL: %w", err)
+	}
+	query := ssoURL.Query()
+	query.Set("SAMLRequest", base64.StdEncoding.EncodeToString(compressed.Bytes()))
+	query.Set("RelayState", relayState)
+	ssoURL.RawQuery = query.Encode()
+	return ssoURL.String(), nil
+}
+
+// Metadata returns the service provider metadata to register with the provider
+func (p *SAMLProvider) Metadata() []byte {
+	return []byte(fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" entityID="%s">
+  <md:SPSSODescriptor AuthnRequestsSigned="false" WantAssertionsSigned="true" protocolSupportEnumeration="%s">
+    <md:NameIDFormat>%s</md:NameIDFormat>
+    <md:AssertionConsumerService Binding="%s" Location="%s" index="0" isDefault="true"/>
+  </md:SPSSODescriptor>
+</md:EntityDescriptor>
+`, escapeAttr(p.config.EntityID), nsSAMLProtocol, nameIDEmail, bindingPOST, escapeAttr(p.config.ACSURL)))
+}
+
+// ParseResponse verifies a base64 SAMLResponse posted to the ACS URL in answer to
+// the AuthnRequest with requestID, and returns the user it asserts
+func (p *SAMLProvider) ParseResponse(encoded, requestID string) (*Identity, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(encoded), ""))
+	if err != nil {
+		return nil, invalid("response is not base64")
+	}
+	response, err := parseXML(data)
+	if err != nil {
+		return nil, invalid("%v", err)
+	}
+	if !is(response, nsSAMLProtocol, "Response") {
+		return nil, invalid("not a SAML response")
+	}
+
+	responseSigned := false
+	if child(response, nsDSig, "Signature") != nil {
+		if response, err = verifySignature(response, p.cert, p.now()); err != nil {
+			return nil, invalid("response signature: %v", err)
+		}
+		responseSigned = true
+	}
+
+	if status := child(response, nsSAMLProtocol, "Status"); status != nil {
+		code := child(status, nsSAMLProtocol, "StatusCode")
+		if code == nil || attr(code, "Value") != statusSuccess {
+			return nil, ErrDenied
+		}
+	}
+	if attr(response, "InResponseTo") != requestID {
+		return nil, invalid("response does not answer the login request")
+	}
+	if destination := attr(response, "Destination"); destination != "" && destination != p.config.ACSURL {
+		return nil, invalid("response is for another destination")
+	}
+
+	if child(response, nsSAMLAssertion, "EncryptedAssertion") != nil {
+		return nil, invalid("encrypted assertions are not supported")
+	}
+	var assertions []*etree.Element
+	for _, c := range response.ChildElements() {
+		if is(c, nsSAMLAssertion, "Assertion") {
+			assertions = append(assertions, c)
+		}
+	}
+	if len(assertions) != 1 {
+		return nil, invalid("response must have one assertion")
+	}
+	assertion := assertions[0]
+	if child(assertion, nsDSig, "Signature") != nil {
+		if assertion, err = verifySignature(assertion, p.cert, p.now()); err != nil {
+			return nil, invalid("assertion signature: %v", err)
+		}
+	} else if !responseSigned {
+		return nil, invalid("neither the response nor the assertion is signed")
+	}
+
+	return p.readAssertion(assertion, requestID)
+}
+
+// readAssertion checks the conditions of a verified assertion and reads the user
+func (p *SAMLProvider) readAssertion(assertion *etree.Element, requestID string) (*Identity, error) {
+	now := p.now()
+	identity := &Identity{AssertionID: attr(assertion, "ID")}
+	if identity.AssertionID == "" {
+		return nil, invalid("assertion has no ID")
+	}
+	if issuer := child(assertion, nsSAMLAssertion, "Issuer"); p.config.IDPEntityID == "" || issuer == nil || text(issuer) != p.config.IDPEntityID {
+		return nil, invalid("assertion is from another issuer")
+	}
+
+	// The assertion must be restricted to us, or one issued to another service
+	// provider of the identity provider would be accepted
+	conditions := child(assertion, nsSAMLAssertion, "Conditions")
+	if conditions == nil {
+		return nil, invalid("assertion has no conditions")
+	}
+	if !within(now, attr(conditions, "NotBefore"), attr(conditions, "NotOnOrAfter")) {
+		return nil, invalid("assertion is expired or not yet valid")
+	}
+	identity.ExpiresAt = latest(identity.ExpiresAt, attr(conditions, "NotOnOrAfter"))
+	restricted := false
+	for _, restriction := range conditions.ChildElements() {
+		if !is(restriction, nsSAMLAssertion, "AudienceRestriction") {
+			continue
+		}
+		allowed := false
+		for _, audience := range restriction.ChildElements() {
+			if is(audience, nsSAMLAssertion, "Audience") && text(audience) == p.config.EntityID {
+				allowed = true
+			}
+		}
+		if !allowed {
+			return nil, invalid("assertion is for another audience")
+		}
+		restricted = true
+	}
+	if !restricted {
+		return nil, invalid("assertion has no audience restriction")
+	}
+
+	subject := child(assertion, nsSAMLAssertion, "Subject")
+	if subject == nil {
+		return nil, invalid("assertion has no subject")
+	}
+	confirmed := false
+	for _, confirmation := range subject.ChildElements() {
+		if !is(confirmation, nsSAMLAssertion, "SubjectConfirmation") || attr(confirmation, "Method") != bearerMethod {
+			continue
+		}
+		data := child(confirmation, nsSAMLAssertion, "SubjectConfirmationData")
+		if data == nil {
+			continue
+		}
+		if recipient := attr(data, "Recipient"); recipient != "" && recipient != p.config.ACSURL {
+			continue
+		}
+		// Only answers to the request we issued are accepted, never unsolicited ones
+		if attr(data, "InResponseTo") != requestID {
+			continue
+		}
+		if !within(now, attr(data, "NotBefore"), attr(data, "NotOnOrAfter")) {
+			continue
+		}
+		identity.ExpiresAt = latest(identity.ExpiresAt, attr(data, "NotOnOrAfter"))
+		confirmed = true
+	}
+	if !confirmed {
+		return nil, invalid("subject confirmation failed")
+	}
+
+	if nameID := child(subject, nsSAMLAssertion, "NameID"); nameID != nil {
+		identity.Subject = text(nameID)
+	}
+	if identity.Subject == "" {
+		return nil, invalid("assertion has no NameID")
+	}
+
+	attributes := make(map[string][]string)
+	for _, statement := range assertion.ChildElements() {
+		if !is(statement, nsSAMLAssertion, "AttributeStatement") {
+			continue
+		}
+		for _, attribute := range statement.ChildElements() {
+			if !is(attribute, nsSAMLAssertion, "Attribute") {
+				continue
+			}
+			name := attr(attribute, "Name")
+			for _, value := range attribute.ChildElements() {
+				if is(value, nsSAMLAssertion, "AttributeValue") {
+					if v := text(value); v != "" {
+						attributes[name] = append(attributes[name], v)
+					}
+				}
+			}
+		}
+	}
+
+	identity.Email = firstAttribute(attributes, p.config.EmailAttribute, defaultEmailAttributes)
+	if identity.Email == "" && strings.Contains(identity.Subject, "@") {
+		identity.Email = identity.Subject
+	}
+	identity.Name = firstAttribute(attributes, p.config.NameAttribute, defaultNameAttributes)
+	if p.config.GroupsAttribute != "" {
+		identity.Groups = attributes[p.config.GroupsAttribute]
+	} else {
+		for _, name := range defaultGroupAttributes {
+			identity.Groups = append(identity.Groups, attributes[name]...)
+		}
+	}
+	return identity, nil
+}
+
+// firstAttribute returns the first value of the configured attribute, or of the
+// first default one present
+func firstAttribute(attributes map[string][]string, configured string, defaults []string) string {
+	names := defaults
+	if configured != "" {
+		names = []string{configured}
+	}
+	for _, name := range names {
+		if values := attributes[name]; len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+// latest returns the later of a time and a timestamp of an assertion
+func latest(t time.Time, timestamp string) time.Time {
+	if parsed, err := time.Parse(time.RFC3339, timestamp); err == nil && parsed.After(t) {
+		return parsed
+	}
+	return t
+}
+
+// within reports whether now is in a validity window, with clock skew tolerated
+func within(now time.Time, notBefore, notOnOrAfter string) bool {
+	if notBefore != "" {
+		t, err := time.Parse(time.RFC3339, notBefore)
+		if err != nil || now.Add(clockSkew).Before(t) {
+			return false
+		}
+	}
+	if notOnOrAfter != "" {
+		t, err := time.Parse(time.RFC3339, notOnOrAfter)
+		if err != nil || !now.Add(-clockSkew).Before(t) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,323 @@
+package sso
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/beevik/etree"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/msgfy/linktor/internal/infrastructure/egress"
+	dsig "github.com/russellhaering/goxmldsig"
+	"github.com/russellhaering/goxmldsig/etreeutils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseXML(t *testing.T) {
+	root, err := parseXML([]byte(`<a:root xmlns:a="urn:a"><a:child b="1"> text <!-- note --></a:child></a:root>`))
+	require.NoError(t, err)
+	c := child(root, "urn:a", "child")
+	require.NotNil(t, c)
+	assert.Equal(t, "1", attr(c, "b"))
+	assert.Equal(t, "text", text(c))
+
+	_, err = parseXML([]byte(`<!DOCTYPE r [<!ENTITY x "y">]><r>&x;</r>`))
+	assert.Error(t, err, "DTDs are rejected")
+}
+
+// testIdP is an identity provider signing with a self-signed certificate
+type testIdP struct {
+	cert    tls.Certificate
+	certPEM string
+}
+
+func newTestIdP(t *testing.T) *testIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp.example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return &testIdP{
+		cert:    tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key},
+		certPEM: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+	}
+}
+
+// sign adds an enveloped signature to the element with an ID, as identity providers do
+func (idp *testIdP) sign(t *testing.T, document, id string) string {
+	doc := etree.NewDocument()
+	require.NoError(t, doc.ReadFromString(document))
+	el := doc.FindElement("//*[@ID='" + id + "']")
+	require.NotNil(t, el)
+
+	nsContext, err := etreeutils.NSBuildParentContext(el)
+	require.NoError(t, err)
+	detached, err := etreeutils.NSDetatch(nsContext, el)
+	require.NoError(t, err)
+	ctx := dsig.NewDefaultSigningContext(dsig.TLSCertKeyStore(idp.cert))
+	ctx.Canonicalizer = dsig.MakeC14N10ExclusiveCanonicalizerWithPrefixList("")
+	signed, err := ctx.SignEnveloped(detached)
+	require.NoError(t, err)
+
+	if parent := el.Parent(); parent != nil && parent != &doc.Element {
+		parent.InsertChildAt(el.Index(), signed)
+		parent.RemoveChild(el)
+	} else {
+		doc.SetRoot(signed)
+	}
+	out, err := doc.WriteToString()
+	require.NoError(t, err)
+	return out
+}
+
+func testSAMLResponse(requestID, audience string, notOnOrAfter time.Time) string {
+	expiry := notOnOrAfter.UTC().Format(time.RFC3339)
+	return `<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" ID="_response" InResponseTo="` + requestID + `" Destination="https://app.example.com/acs">` +
+		`<samlp:Status><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"/></samlp:Status>` +
+		`<saml:Assertion ID="_assertion" Version="2.0">` +
+		`<saml:Issuer>https://idp.example.com</saml:Issuer>` +
+		`<saml:Subject><saml:NameID>ana@acme.com</saml:NameID><saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer">` +
+		`<saml:SubjectConfirmationData InResponseTo="` + requestID + `" Recipient="https://app.example.com/acs" NotOnOrAfter="` + expiry + `"/></saml:SubjectConfirmation></saml:Subject>` +
+		`<saml:Conditions NotOnOrAfter="` + expiry + `"><saml:AudienceRestriction><saml:Audience>` + audience + `</saml:Audience></saml:AudienceRestriction></saml:Conditions>` +
+		`<saml:AttributeStatement>` +
+		`<saml:Attribute Name="email"><saml:AttributeValue>ana@acme.com</saml:AttributeValue></saml:Attribute>` +
+		`<saml:Attribute Name="displayName"><saml:AttributeValue>Ana Souza</saml:AttributeValue></saml:Attribute>` +
+		`<saml:Attribute Name="groups"><saml:AttributeValue>support</saml:AttributeValue><saml:AttributeValue>leads</saml:AttributeValue></saml:Attribute>` +
+		`</saml:AttributeStatement></saml:Assertion></samlp:Response>`
+}
+
+func TestSAMLProvider(t *testing.T) {
+	idp := newTestIdP(t)
+	provider, err := NewSAMLProvider(SAMLConfig{
+		EntityID:       "https://app.example.com/metadata",
+		ACSURL:         "https://app.example.com/acs",
+		IDPEntityID:    "https://idp.example.com",
+		IDPSSOURL:      "https://idp.example.com/sso",
+		IDPCertificate: idp.certPEM,
+	})
+	require.NoError(t, err)
+	expiry := time.Now().Add(5 * time.Minute)
+	encode := func(document string) string { return base64.StdEncoding.EncodeToString([]byte(document)) }
+
+	t.Run("redirects with a deflated AuthnRequest", func(t *testing.T) {
+		authURL, err := provider.AuthURL("_req1", "state-1")
+		require.NoError(t, err)
+		parsed, err := url.Parse(authURL)
+		require.NoError(t, err)
+		assert.Equal(t, "idp.example.com", parsed.Host)
+		assert.Equal(t, "state-1", parsed.Query().Get("RelayState"))
+		assert.NotEmpty(t, parsed.Query().Get("SAMLRequest"))
+	})
+
+	t.Run("accepts a signed assertion", func(t *testing.T) {
+		response := idp.sign(t, testSAMLResponse("_req1", "https://app.example.com/metadata", expiry), "_assertion")
+		identity, err := provider.ParseResponse(encode(response), "_req1")
+		require.NoError(t, err)
+		assert.Equal(t, "ana@acme.com", identity.Subject)
+		assert.Equal(t, "ana@acme.com", identity.Email)
+		assert.Equal(t, "Ana Souza", identity.Name)
+		assert.Equal(t, []string{"support", "leads"}, identity.Groups)
+		assert.Equal(t, "_assertion", identity.AssertionID)
+		assert.Equal(t, expiry.UTC().Truncate(time.Second), identity.ExpiresAt)
+	})
+
+	t.Run("accepts a signed response", func(t *testing.T) {
+		response := idp.sign(t, testSAMLResponse("_req1", "https://app.example.com/metadata", expiry), "_response")
+		identity, err := provider.ParseResponse(encode(response), "_req1")
+		require.NoError(t, err)
+		assert.Equal(t, "ana@acme.com", identity.Email)
+
+		// An assertion added next to the signed one is refused
+		injected := strings.Replace(response, "<saml:Assertion ", `<saml:Assertion ID="_evil"><saml:Issuer>https://idp.example.com</saml:Issuer></saml:Assertion><saml:Assertion `, 1)
+		_, err = provider.ParseResponse(encode(injected), "_req1")
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+	})
+
+	t.Run("rejects tampered assertions", func(t *testing.T) {
+		response := idp.sign(t, testSAMLResponse("_req1", "https://app.example.com/metadata", expiry), "_assertion")
+		tampered := strings.Replace(response, "<saml:AttributeValue>leads", "<saml:AttributeValue>admins", 1)
+		_, err := provider.ParseResponse(encode(tampered), "_req1")
+		assert.ErrorIs(t, err, ErrInvalidResponse)
+	})
+
+	t.Run("rejects unsigned, foreign and expired responses", func(t *testing.T) {
+		_, err := provider.ParseResponse(encode(testSAMLResponse("_req1", "https://app.example.com/metadata", expiry)), "_req1")
+		assert.ErrorIs(t, err, ErrInvalidResponse, "unsigned")
+
+		other := newTestIdP(t)
+		response := other.sign(t, testSAMLResponse("_req1", "https://app.example.com/metadata", expiry), "_assertion")
+		_, err = provider.ParseResponse(encode(response), "_req1")
+		assert.ErrorIs(t, err, ErrInvalidResponse, "signed by another certificate")
+
+		response = idp.sign(t, testSAMLResponse("_req1", "https://app.example.com/metadata", expiry), "_assertion")
+		_, err = provider.ParseResponse(encode(response), "_req2")
+		assert.ErrorIs(t, err, ErrInvalidResponse, "answer to another request")
+
+		unsolicited := strings.Replace(testSAMLResponse("_req1", "https://app.example.com/metadata", expiry), `<saml:SubjectConfirmationData InResponseTo="_req1"`, `<saml:SubjectConfirmationData`, 1)
+		response = idp.sign(t, unsolicited, "_assertion")
+		_, err = provider.ParseResponse(encode(response), "_req1")
+		assert.ErrorIs(t, err, ErrInvalidResponse, "assertion not answering the request")
+
+		response = idp.sign(t, testSAMLResponse("_req1", "https://other.example.com", expiry), "_assertion")
+		_, err = provider.ParseResponse(encode(response), "_req1")
+		assert.ErrorIs(t, err, ErrInvalidResponse, "another audience")
+
+		unrestricted := strings.Replace(testSAMLResponse("_req1", "https://app.example.com/metadata", expiry), `<saml:AudienceRestriction><saml:Audience>https://app.example.com/metadata</saml:Audience></saml:AudienceRestriction>`, "", 1)
+		response = idp.sign(t, unrestricted, "_assertion")
+		_, err = provider.ParseResponse(encode(response), "_req1")
+		assert.ErrorIs(t, err, ErrInvalidResponse, "no audience restriction")
+
+		foreign := strings.Replace(testSAMLResponse("_req1", "https://app.example.com/metadata", expiry), "<saml:Issuer>https://idp.example.com</saml:Issuer>", "<saml:Issuer>https://other.example.com</saml:Issuer>", 1)
+		response = idp.sign(t, foreign, "_assertion")
+		_, err = provider.ParseResponse(encode(response), "_req1")
+		assert.ErrorIs(t, err, ErrInvalidResponse, "another issuer")
+
+		response = idp.sign(t, testSAMLResponse("_req1", "https://app.example.com/metadata", time.Now().Add(-10*time.Minute)), "_assertion")
+		_, err = provider.ParseResponse(encode(response), "_req1")
+		assert.ErrorIs(t, err, ErrInvalidResponse, "expired")
+	})
+
+	t.Run("requires the issuer of the provider", func(t *testing.T) {
+		_, err := NewSAMLProvider(SAMLConfig{IDPSSOURL: "https://idp.example.com/sso", IDPCertificate: idp.certPEM})
+		assert.Error(t, err)
+	})
+}
+
+func TestOIDCProvider(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	var server *httptest.Server
+	var idTokenClaims jwt.MapClaims
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]string{
+				"issuer":                 server.URL,
+				"authorization_endpoint": server.URL + "/authorize",
+				"token_endpoint":         server.URL + "/token",
+				"userinfo_endpoint":      server.URL + "/userinfo",
+				"jwks_uri":               server.URL + "/jwks",
+			})
+		case "/jwks":
+			json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "key-1",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}}})
+		case "/token":
+			require.NoError(t, r.ParseForm())
+			id, secret, _ := r.BasicAuth()
+			if r.Form.Get("code") != "code-1" || id != "client-1" || secret != "secret-1" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			token := jwt.NewWithClaims(jwt.SigningMethodRS256, idTokenClaims)
+			token.Header["kid"] = "key-1"
+			signed, err := token.SignedString(key)
+			require.NoError(t, err)
+			json.NewEncoder(w).Encode(map[string]string{"id_token": signed, "access_token": "access-1"})
+		case "/userinfo":
+			assert.Equal(t, "Bearer access-1", r.Header.Get("Authorization"))
+			json.NewEncoder(w).Encode(map[string]interface{}{"sub": "user-1", "email": "bob@acme.com", "groups": []string{"agents"}})
+		}
+	}))
+	defer server.Close()
+
+	provider := NewOIDCProvider(OIDCConfig{
+		Issuer:       server.URL,
+		ClientID:     "client-1",
+		ClientSecret: "secret-1",
+		RedirectURL:  "https://app.example.com/callback",
+	})
+	ctx := context.Background()
+
+	// Issuers on internal addresses are refused
+	_, err = provider.AuthURL(ctx, "state-1", "nonce-1")
+	assert.ErrorIs(t, err, egress.ErrForbiddenAddress)
+	provider.httpClient = server.Client()
+	claims := func(overrides jwt.MapClaims) jwt.MapClaims {
+		c := jwt.MapClaims{
+			"iss":   server.URL,
+			"aud":   "client-1",
+			"sub":   "user-1",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+			"nonce": "nonce-1",
+			"email": "ana@acme.com",
+			"name":  "Ana Souza",
+		}
+		for name, value := range overrides {
+			if value == nil {
+				delete(c, name)
+			} else {
+				c[name] = value
+			}
+		}
+		return c
+	}
+
+	t.Run("sends the user to the authorization endpoint", func(t *testing.T) {
+		authURL, err := provider.AuthURL(ctx, "state-1", "nonce-1")
+		require.NoError(t, err)
+		parsed, err := url.Parse(authURL)
+		require.NoError(t, err)
+		assert.Equal(t, "/authorize", parsed.Path)
+		assert.Equal(t, "openid email profile", parsed.Query().Get("scope"))
+		assert.Equal(t, "nonce-1", parsed.Query().Get("nonce"))
+		assert.Equal(t, "https://app.example.com/callback", parsed.Query().Get("redirect_uri"))
+	})
+
+	t.Run("verifies the ID token", func(t *testing.T) {
+		idTokenClaims = claims(jwt.MapClaims{"groups": []string{"support"}})
+		identity, err := provider.Exchange(ctx, "code-1", "nonce-1")
+		require.NoError(t, err)
+		assert.Equal(t, &Identity{Subject: "user-1", Email: "ana@acme.com", Name: "Ana Souza", Groups: []string{"support"}}, identity)
+	})
+
+	t.Run("reads the profile from userinfo when the ID token has no email", func(t *testing.T) {
+		idTokenClaims = claims(jwt.MapClaims{"email": nil})
+		identity, err := provider.Exchange(ctx, "code-1", "nonce-1")
+		require.NoError(t, err)
+		assert.Equal(t, "bob@acme.com", identity.Email)
+		assert.Equal(t, []string{"agents"}, identity.Groups)
+	})
+
+	t.Run("rejects tokens for another login or client", func(t *testing.T) {
+		idTokenClaims = claims(nil)
+		_, err := provider.Exchange(ctx, "code-1", "nonce-2")
+		assert.True(t, errors.Is(err, ErrInvalidResponse))
+
+		idTokenClaims = claims(jwt.MapClaims{"aud": "client-2"})
+		_, err = provider.Exchange(ctx, "code-1", "nonce-1")
+		assert.True(t, errors.Is(err, ErrInvalidResponse))
+
+		idTokenClaims = claims(jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()})
+		_, err = provider.Exchange(ctx, "code-1", "nonce-1")
+		assert.True(t, errors.Is(err, ErrInvalidResponse))
+
+		idTokenClaims = claims(jwt.MapClaims{"email_verified": false})
+		_, err = provider.Exchange(ctx, "code-1", "nonce-1")
+		assert.True(t, errors.Is(err, ErrInvalidResponse))
+	})
+}
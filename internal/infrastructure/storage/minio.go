@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"net/url"
 	"time"
 
@@ -11,8 +12,8 @@ import (
 	"github.com/minio/minio-go/v7/pkg/credentials"
 )
 
-// Ensure MinIOClient implements Client
-var _ Client = (*MinIOClient)(nil)
+// Ensure MinIOClient implements ObjectStorage
+var _ ObjectStorage = (*MinIOClient)(nil)
 
 // MinIOClient stores files in MinIO/S3-compatible object storage
 type MinIOClient struct {
@@ -53,6 +54,11 @@ func NewMinIOClient(endpoint, accessKey, secretKey, bucketName, region string, u
 	}, nil
 }
 
+// NewS3Client creates a storage client for an Amazon S3 bucket
+func NewS3Client(accessKey, secretKey, bucketName, region string) (*MinIOClient, error) {
+	return NewMinIOClient(fmt.Sprintf("s3.%s.amazonaws.com", region), accessKey, secretKey, bucketName, region, true)
+}
+
 // Upload stores data in MinIO and returns a presigned URL
 func (c *MinIOClient) Upload(ctx context.Context, key string, data []byte, contentType string) (string, error) {
 	filePath := fmt.Sprintf("linktor-media/%s", key)
@@ -90,3 +96,28 @@ func (c *MinIOClient) GetURL(ctx context.Context, key string) (string, error) {
 
 	return presignedURL.String(), nil
 }
+
+// Get reads an object from MinIO
+func (c *MinIOClient) Get(ctx context.Context, key string) ([]byte, string, error) {
+	filePath := fmt.Sprintf("linktor-media/%s", key)
+
+	object, err := c.client.GetObject(ctx, c.bucketName, filePath, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get object: %w", err)
+	}
+	defer object.Close()
+
+	info, err := object.Stat()
+	if err != nil {
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, "", ErrObjectNotFound
+		}
+		return nil, "", fmt.Errorf("failed to stat object: %w", err)
+	}
+
+	data, err := io.ReadAll(object)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read object: %w", err)
+	}
+	return data, info.ContentType, nil
+}
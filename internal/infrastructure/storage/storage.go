@@ -2,13 +2,20 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"mime"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/google/uuid"
 )
 
+// ErrObjectNotFound is returned when reading an object that does not exist
+var ErrObjectNotFound = errors.New("object not found")
+
 // Client defines the interface for object storage
 type Client interface {
 	// Upload uploads data and returns the public URL
@@ -19,6 +26,17 @@ type Client interface {
 	GetURL(ctx context.Context, key string) (string, error)
 }
 
+// ObjectStorage is a Client that can also read objects back, so they can be served
+// through Linktor instead of from the storage's own URLs
+type ObjectStorage interface {
+	Client
+	// Get reads an object and returns its data and content type
+	Get(ctx context.Context, key string) ([]byte, string, error)
+}
+
+// Ensure LocalClient implements ObjectStorage
+var _ ObjectStorage = (*LocalClient)(nil)
+
 // LocalClient stores files on the local filesystem and returns a URL
 type LocalClient struct {
 	uploadDir string
@@ -69,3 +87,25 @@ func (c *LocalClient) Delete(ctx context.Context, key string) error {
 func (c *LocalClient) GetURL(ctx context.Context, key string) (string, error) {
 	return fmt.Sprintf("%s/%s", c.baseURL, key), nil
 }
+
+// Get reads a file from the local filesystem. The content type is derived from the
+// key's extension, or from the data when the key has none.
+func (c *LocalClient) Get(ctx context.Context, key string) ([]byte, string, error) {
+	if strings.Contains(key, "..") {
+		return nil, "", fmt.Errorf("invalid key: %s", key)
+	}
+
+	data, err := os.ReadFile(filepath.Join(c.uploadDir, key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, "", ErrObjectNotFound
+		}
+		return nil, "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	contentType := mime.TypeByExtension(filepath.Ext(key))
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	return data, contentType, nil
+}
@@ -201,3 +201,28 @@ func TestLocalClient_Upload_DifferentBaseURLs(t *testing.T) {
 		assert.Equal(t, tt.expectedURL, url)
 	}
 }
+
+func TestLocalClient_Get(t *testing.T) {
+	tmpDir := t.TempDir()
+	client := NewLocalClient(tmpDir, "http://cdn.example.com")
+
+	_, err := client.Upload(context.Background(), "media/photo.png", []byte("png-data"), "image/png")
+	require.NoError(t, err)
+
+	data, contentType, err := client.Get(context.Background(), "media/photo.png")
+	require.NoError(t, err)
+	assert.Equal(t, "png-data", string(data))
+	assert.Equal(t, "image/png", contentType)
+
+	_, err = client.Upload(context.Background(), "media/note", []byte("plain text"), "text/plain")
+	require.NoError(t, err)
+	_, contentType, err = client.Get(context.Background(), "media/note")
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain; charset=utf-8", contentType)
+
+	_, _, err = client.Get(context.Background(), "media/missing.png")
+	assert.ErrorIs(t, err, ErrObjectNotFound)
+
+	_, _, err = client.Get(context.Background(), "../outside.txt")
+	assert.Error(t, err)
+}
@@ -0,0 +1,157 @@
+package stt
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	googleSpeechURL = "https://speech.googleapis.com/v1"
+
+	// googleDefaultLanguage is used when a recording has no language, which Google
+	// requires
+	googleDefaultLanguage = "en-US"
+
+	// googleMaxInlineBytes is the largest recording Google accepts inline
+	googleMaxInlineBytes = 10 << 20
+)
+
+// GoogleTranscriber transcribes with Google Cloud Speech-to-Text. Recordings are
+// sent for long running recognition, which accepts calls of any length, and the
+// operation is polled until the transcript is ready.
+type GoogleTranscriber struct {
+	apiKey       string
+	baseURL      string
+	httpClient   *http.Client
+	pollInterval time.Duration
+}
+
+// NewGoogleTranscriber creates a transcriber authenticating with an API key
+func NewGoogleTranscriber(apiKey string) *GoogleTranscriber {
+	return &GoogleTranscriber{
+		apiKey:       apiKey,
+		baseURL:      googleSpeechURL,
+		httpClient:   &http.Client{Timeout: 60 * time.Second},
+		pollInterval: 5 * time.Second,
+	}
+}
+
+type googleOperation struct {
+	Name     string `json:"name"`
+	Done     bool   `json:"done"`
+	Response *struct {
+		Results []struct {
+			Alternatives []struct {
+				Transcript string `json:"transcript"`
+			} `json:"alternatives"`
+		} `json:"results"`
+	} `json:"response"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Transcribe transcribes a recording. WAV and FLAC recordings carry their encoding
+// and sample rate in their header; Ogg recordings are read as Opus.
+func (t *GoogleTranscriber) Transcribe(ctx context.Context, audio []byte, filename, language string) (string, error) {
+	if len(audio) > googleMaxInlineBytes {
+		return "", fmt.Errorf("recording of %d bytes exceeds the %d bytes Google accepts inline", len(audio), googleMaxInlineBytes)
+	}
+	if language == "" {
+		language = googleDefaultLanguage
+	}
+
+	config := map[string]interface{}{
+		"languageCode":               language,
+		"enableAutomaticPunctuation": true,
+		"model":                      "phone_call",
+	}
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".ogg", ".opus":
+		config["encoding"] = "OGG_OPUS"
+		config["sampleRateHertz"] = 48000
+	}
+	request := map[string]interface{}{
+		"config": config,
+		"audio":  map[string]string{"content": base64.StdEncoding.EncodeToString(audio)},
+	}
+
+	var operation googleOperation
+	if err := t.do(ctx, http.MethodPost, "/speech:longrunningrecognize", request, &operation); err != nil {
+		return "", err
+	}
+
+	for !operation.Done {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(t.pollInterval):
+		}
+		name := operation.Name
+		operation = googleOperation{}
+		if err := t.do(ctx, http.MethodGet, "/operations/"+url.PathEscape(name), nil, &operation); err != nil {
+			return "", err
+		}
+	}
+
+	if operation.Error != nil {
+		return "", fmt.Errorf("google speech error (%d): %s", operation.Error.Code, operation.Error.Message)
+	}
+	if operation.Response == nil {
+		return "", nil
+	}
+	var parts []string
+	for _, result := range operation.Response.Results {
+		if len(result.Alternatives) > 0 {
+			if text := strings.TrimSpace(result.Alternatives[0].Transcript); text != "" {
+				parts = append(parts, text)
+			}
+		}
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// do calls the Speech-to-Text API
+func (t *GoogleTranscriber) do(ctx context.Context, method, path string, body, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, t.baseURL+path+"?key="+url.QueryEscape(t.apiKey), reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("google speech request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read google speech response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("google speech error (%d): %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return json.Unmarshal(respBody, result)
+}
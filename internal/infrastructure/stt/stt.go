@@ -0,0 +1,34 @@
+// Package stt transcribes recorded speech with speech-to-text services.
+//
+// Two providers are implemented here: a local whisper.cpp server and Google Cloud
+// Speech-to-Text. OpenAI Whisper is served by the OpenAI client of the AI adapters,
+// which has the same Transcribe method.
+package stt
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Provider names
+const (
+	ProviderOpenAI     = "openai"
+	ProviderWhisperCpp = "whispercpp"
+	ProviderGoogle     = "google"
+)
+
+// audioMimeType guesses the MIME type of a recording from its filename
+func audioMimeType(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".mp3":
+		return "audio/mpeg"
+	case ".ogg", ".opus":
+		return "audio/ogg"
+	case ".flac":
+		return "audio/flac"
+	case ".m4a", ".mp4":
+		return "audio/mp4"
+	default:
+		return "audio/wav"
+	}
+}
@@ -0,0 +1,102 @@
+package stt
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWhisperCppTranscriber(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/inference", r.URL.Path)
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		assert.Equal(t, "pt", r.FormValue("language"))
+		assert.Equal(t, "json", r.FormValue("response_format"))
+
+		file, header, err := r.FormFile("file")
+		require.NoError(t, err)
+		audio, _ := io.ReadAll(file)
+		assert.Equal(t, "call-1.wav", header.Filename)
+		assert.Equal(t, "RIFF-audio", string(audio))
+
+		w.Write([]byte(`{"text": " Olá, preciso de ajuda com meu pedido. "}`))
+	}))
+	defer server.Close()
+
+	text, err := NewWhisperCppTranscriber(server.URL+"/").Transcribe(context.Background(), []byte("RIFF-audio"), "call-1.wav", "pt-BR")
+	require.NoError(t, err)
+	assert.Equal(t, "Olá, preciso de ajuda com meu pedido.", text)
+}
+
+func TestWhisperCppTranscriber_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("failed to load model"))
+	}))
+	defer server.Close()
+
+	_, err := NewWhisperCppTranscriber(server.URL).Transcribe(context.Background(), []byte("audio"), "", "")
+	assert.ErrorContains(t, err, "failed to load model")
+}
+
+func TestGoogleTranscriber(t *testing.T) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.URL.Query().Get("key"))
+		switch r.URL.Path {
+		case "/speech:longrunningrecognize":
+			var request struct {
+				Config map[string]interface{} `json:"config"`
+				Audio  map[string]string      `json:"audio"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			assert.Equal(t, "pt-BR", request.Config["languageCode"])
+			assert.Equal(t, base64.StdEncoding.EncodeToString([]byte("audio")), request.Audio["content"])
+			w.Write([]byte(`{"name": "op-1"}`))
+		case "/operations/op-1":
+			polls++
+			if polls == 1 {
+				w.Write([]byte(`{"name": "op-1", "done": false}`))
+				return
+			}
+			w.Write([]byte(`{"name": "op-1", "done": true, "response": {"results": [
+				{"alternatives": [{"transcript": "Bom dia."}]},
+				{"alternatives": [{"transcript": " Quero cancelar."}, {"transcript": "Quero cantar."}]}
+			]}}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	transcriber := NewGoogleTranscriber("secret")
+	transcriber.baseURL = server.URL
+	transcriber.pollInterval = time.Millisecond
+
+	text, err := transcriber.Transcribe(context.Background(), []byte("audio"), "call.wav", "pt-BR")
+	require.NoError(t, err)
+	assert.Equal(t, "Bom dia. Quero cancelar.", text)
+	assert.Equal(t, 2, polls)
+}
+
+func TestGoogleTranscriber_OperationError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"name": "op-1", "done": true, "error": {"code": 3, "message": "bad encoding"}}`))
+	}))
+	defer server.Close()
+
+	transcriber := NewGoogleTranscriber("secret")
+	transcriber.baseURL = server.URL
+
+	_, err := transcriber.Transcribe(context.Background(), []byte("audio"), "call.wav", "")
+	assert.ErrorContains(t, err, "bad encoding")
+}
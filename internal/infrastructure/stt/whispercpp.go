@@ -0,0 +1,110 @@
+package stt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// WhisperCppTranscriber transcribes with a whisper.cpp server running on premises,
+// so recordings never leave the network
+type WhisperCppTranscriber struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewWhisperCppTranscriber creates a transcriber for the whisper.cpp server at
+// baseURL, such as http://localhost:8080
+func NewWhisperCppTranscriber(baseURL string) *WhisperCppTranscriber {
+	return &WhisperCppTranscriber{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		// Long calls take a while to transcribe on CPU
+		httpClient: &http.Client{Timeout: 15 * time.Minute},
+	}
+}
+
+// Transcribe transcribes a recording. Language tags such as "pt-BR" are reduced to
+// their ISO-639-1 code; without one the server detects the language.
+func (t *WhisperCppTranscriber) Transcribe(ctx context.Context, audio []byte, filename, language string) (string, error) {
+	if i := strings.IndexAny(language, "-_"); i > 0 {
+		language = language[:i]
+	}
+	if language == "" {
+		language = "auto"
+	}
+
+	// The recording is streamed into the request instead of being copied into a
+	// buffered form
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+	go func() {
+		writer.CloseWithError(writeWhisperForm(form, audio, filename, strings.ToLower(language)))
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.baseURL+"/inference", body)
+	if err != nil {
+		body.Close()
+		return "", err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("whisper.cpp request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read whisper.cpp response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("whisper.cpp error (%d): %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var result struct {
+		Text  string `json:"text"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("invalid whisper.cpp response: %w", err)
+	}
+	if result.Error != "" {
+		return "", fmt.Errorf("whisper.cpp error: %s", result.Error)
+	}
+	return strings.TrimSpace(result.Text), nil
+}
+
+func writeWhisperForm(form *multipart.Writer, audio []byte, filename, language string) error {
+	if filename == "" {
+		filename = "audio.wav"
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename="%s"`, strings.ReplaceAll(filename, `"`, "")))
+	header.Set("Content-Type", audioMimeType(filename))
+	part, err := form.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, bytes.NewReader(audio)); err != nil {
+		return err
+	}
+	fields := map[string]string{
+		"language":        language,
+		"response_format": "json",
+		"temperature":     "0",
+	}
+	for name, value := range fields {
+		if err := form.WriteField(name, value); err != nil {
+			return err
+		}
+	}
+	return form.Close()
+}
@@ -0,0 +1,174 @@
+// Package transcode converts videos to formats and sizes messaging channels accept.
+//
+// Videos are converted by ffmpeg run as a separate process to H.264 video with AAC
+// audio in an MP4 container, the format every supported channel plays, scaled down
+// to the channel's maximum height. When ffmpeg is not installed on the host,
+// transcoding is reported as unavailable.
+package transcode
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout is the time limit of a transcoding
+const DefaultTimeout = 10 * time.Minute
+
+// OutputMimeType is the MIME type of transcoded videos
+const OutputMimeType = "video/mp4"
+
+var (
+	// ErrUnavailable is returned when ffmpeg is not installed
+	ErrUnavailable = errors.New("video transcoding not available")
+	// ErrTimeout is returned when a transcoding runs past its time limit
+	ErrTimeout = errors.New("video transcoding timed out")
+)
+
+// Profile is the video a channel accepts
+type Profile struct {
+	MaxBytes  int64 // Largest video the channel accepts
+	MaxHeight int   // Videos are scaled down to this height
+}
+
+// defaultProfile fits channels without a profile of their own
+var defaultProfile = Profile{MaxBytes: 16 << 20, MaxHeight: 720}
+
+// profiles are the video limits of channels, keyed by channel type
+var profiles = map[string]Profile{
+	"whatsapp":            {MaxBytes: 16 << 20, MaxHeight: 720},
+	"whatsapp_official":   {MaxBytes: 16 << 20, MaxHeight: 720},
+	"whatsapp_unofficial": {MaxBytes: 16 << 20, MaxHeight: 720},
+	"telegram":            {MaxBytes: 50 << 20, MaxHeight: 1080},
+	"facebook":            {MaxBytes: 25 << 20, MaxHeight: 720},
+	"instagram":           {MaxBytes: 25 << 20, MaxHeight: 1080},
+	"teams":               {MaxBytes: 100 << 20, MaxHeight: 1080},
+	"rcs":                 {MaxBytes: 100 << 20, MaxHeight: 1080},
+	"sms":                 {MaxBytes: 5 << 20, MaxHeight: 480},
+	"email":               {MaxBytes: 20 << 20, MaxHeight: 720},
+}
+
+// ProfileFor returns the video profile of a channel type
+func ProfileFor(channelType string) Profile {
+	if profile, ok := profiles[channelType]; ok {
+		return profile
+	}
+	return defaultProfile
+}
+
+// NeedsTranscoding reports whether a video must be converted before channels of the
+// profile accept it
+func NeedsTranscoding(mimeType string, size int64, profile Profile) bool {
+	return baseType(mimeType) != OutputMimeType || size > profile.MaxBytes
+}
+
+// FFmpegTranscoder transcodes videos with ffmpeg
+type FFmpegTranscoder struct {
+	ffmpegPath string
+	timeout    time.Duration
+}
+
+// NewFFmpegTranscoder creates a transcoder using the ffmpeg binary at the given path,
+// or found on PATH when empty
+func NewFFmpegTranscoder(ffmpegPath string) *FFmpegTranscoder {
+	if ffmpegPath == "" {
+		ffmpegPath = "ffmpeg"
+	}
+	resolved, err := exec.LookPath(ffmpegPath)
+	if err != nil {
+		resolved = ""
+	}
+	return &FFmpegTranscoder{ffmpegPath: resolved, timeout: DefaultTimeout}
+}
+
+// Available reports whether ffmpeg was found
+func (t *FFmpegTranscoder) Available() bool {
+	return t.ffmpegPath != ""
+}
+
+// Transcode converts a video to an MP4 fitting the profile. The result is rejected
+// when it is still larger than the profile allows.
+func (t *FFmpegTranscoder) Transcode(ctx context.Context, data []byte, profile Profile) ([]byte, error) {
+	if !t.Available() {
+		return nil, ErrUnavailable
+	}
+
+	// Containers like MP4 keep their index at the end, so ffmpeg needs seekable
+	// files rather than pipes
+	dir, err := os.MkdirTemp("", "transcode-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	input := filepath.Join(dir, "input")
+	output := filepath.Join(dir, "output.mp4")
+	if err := os.WriteFile(input, data, 0600); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, t.ffmpegPath, ffmpegArgs(input, output, profile)...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, ErrTimeout
+	}
+	if err != nil {
+		return nil, fmt.Errorf("video transcoding failed: %s", lastLine(stderr.String(), err))
+	}
+
+	result, err := os.ReadFile(output)
+	if err != nil {
+		return nil, err
+	}
+	if profile.MaxBytes > 0 && int64(len(result)) > profile.MaxBytes {
+		return nil, fmt.Errorf("transcoded video is %d bytes, larger than the %d bytes the channel accepts", len(result), profile.MaxBytes)
+	}
+	return result, nil
+}
+
+// ffmpegArgs builds the ffmpeg command line converting input to an MP4 fitting the
+// profile
+func ffmpegArgs(input, output string, profile Profile) []string {
+	args := []string{"-hide_banner", "-loglevel", "error", "-y", "-i", input}
+	if profile.MaxHeight > 0 {
+		// Width -2 keeps the aspect ratio with the even width H.264 needs
+		args = append(args, "-vf", "scale=-2:'min("+strconv.Itoa(profile.MaxHeight)+",ih)'")
+	}
+	args = append(args,
+		"-c:v", "libx264", "-preset", "veryfast", "-crf", "28", "-pix_fmt", "yuv420p",
+		"-c:a", "aac", "-b:a", "128k",
+		"-movflags", "+faststart",
+		output,
+	)
+	return args
+}
+
+// lastLine returns the last line ffmpeg wrote to stderr, which holds the error
+func lastLine(stderr string, err error) string {
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	if line := strings.TrimSpace(lines[len(lines)-1]); line != "" {
+		return line
+	}
+	return err.Error()
+}
+
+func baseType(mimeType string) string {
+	if i := strings.Index(mimeType, ";"); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(mimeType))
+}
@@ -0,0 +1,40 @@
+package transcode
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfileFor(t *testing.T) {
+	assert.Equal(t, int64(16<<20), ProfileFor("whatsapp").MaxBytes)
+	assert.Equal(t, 1080, ProfileFor("telegram").MaxHeight)
+	assert.Equal(t, defaultProfile, ProfileFor(""))
+	assert.Equal(t, defaultProfile, ProfileFor("webchat"))
+}
+
+func TestNeedsTranscoding(t *testing.T) {
+	profile := ProfileFor("whatsapp")
+
+	assert.False(t, NeedsTranscoding("video/mp4", 1<<20, profile))
+	assert.True(t, NeedsTranscoding("video/mp4", 20<<20, profile))
+	assert.True(t, NeedsTranscoding("video/quicktime", 1<<20, profile))
+	assert.True(t, NeedsTranscoding("video/webm; codecs=vp9", 1<<20, profile))
+}
+
+func TestFFmpegArgs(t *testing.T) {
+	args := ffmpegArgs("in", "out.mp4", Profile{MaxHeight: 720})
+
+	assert.Contains(t, args, "scale=-2:'min(720,ih)'")
+	assert.Contains(t, args, "libx264")
+	assert.Equal(t, "out.mp4", args[len(args)-1])
+}
+
+func TestMissingFFmpegIsUnavailable(t *testing.T) {
+	transcoder := NewFFmpegTranscoder("/nonexistent/ffmpeg")
+
+	assert.False(t, transcoder.Available())
+	_, err := transcoder.Transcode(context.Background(), []byte("video"), defaultProfile)
+	assert.ErrorIs(t, err, ErrUnavailable)
+}
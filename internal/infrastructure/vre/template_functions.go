@@ -1,10 +1,13 @@
 package vre
 
 import (
+	"encoding/base64"
 	"fmt"
 	"strings"
 	"text/template"
 	"time"
+
+	qrcode "github.com/skip2/go-qrcode"
 )
 
 // TemplateFuncs provides custom functions for VRE templates
@@ -298,6 +301,15 @@ var TemplateFuncs = template.FuncMap{
 		alphaHex := fmt.Sprintf("%02X", alpha*255/100)
 		return hexColor + alphaHex
 	},
+
+	// QR code of a text as a PNG data URI, for <image href="{{qrcode .link 256}}"/>
+	"qrcode": func(content string, size int) string {
+		png, err := qrcode.Encode(content, qrcode.Medium, size)
+		if err != nil {
+			return ""
+		}
+		return "data:image/png;base64," + base64.StdEncoding.EncodeToString(png)
+	},
 }
 
 // formatWithThousands adds thousand separators (Brazilian format: 1.234.567)
@@ -1,6 +1,7 @@
 package vre
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -261,3 +262,12 @@ func TestFormatWithThousands(t *testing.T) {
 		})
 	}
 }
+
+func TestQRCode(t *testing.T) {
+	qr := TemplateFuncs["qrcode"].(func(string, int) string)
+
+	result := qr("https://example.com/offer/42", 128)
+	if !strings.HasPrefix(result, "data:image/png;base64,") {
+		t.Errorf("qrcode() = %.40q, want a PNG data URI", result)
+	}
+}
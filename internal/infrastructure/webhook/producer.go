@@ -3,10 +3,15 @@ package webhook
 import (
 	"bytes"
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
 	"time"
+
+	"github.com/msgfy/linktor/internal/infrastructure/events"
 )
 
 // Default backoff delays for retry attempts
@@ -25,17 +30,22 @@ type EndpointConfig struct {
 	SubscribedEvents []string          // filter events; empty = all
 	MaxRetries       int               // default 5
 	TimeoutSeconds   int               // per-request timeout; default 30
+	SchemaVersion    string            // payload schema (v1, v2 or latest); empty = current
+	Secret           string            // signs payloads in HeaderSignature when set
 }
 
 // DeliveryResult tracks a webhook delivery attempt
 type DeliveryResult struct {
-	WebhookID    string    `json:"webhook_id"`
-	URL          string    `json:"url"`
-	StatusCode   int       `json:"status_code"`
-	ResponseBody string    `json:"response_body,omitempty"`
-	Attempt      int       `json:"attempt"`
-	Error        string    `json:"error,omitempty"`
-	DeliveredAt  time.Time `json:"delivered_at"`
+	WebhookID     string    `json:"webhook_id"`
+	URL           string    `json:"url"`
+	StatusCode    int       `json:"status_code"`
+	ResponseBody  string    `json:"response_body,omitempty"`
+	Attempt       int       `json:"attempt"`
+	Error         string    `json:"error,omitempty"`
+	DeliveredAt   time.Time `json:"delivered_at"`
+	EventID       string    `json:"event_id,omitempty"`
+	SchemaVersion string    `json:"schema_version,omitempty"`
+	Warnings      []string  `json:"warnings,omitempty"` // e.g. deprecated schema versions
 }
 
 // WebhookProducer delivers webhook events to external URLs with retry
@@ -138,6 +148,50 @@ func (p *WebhookProducer) Deliver(ctx context.Context, endpoint EndpointConfig,
 	return lastResult, fmt.Errorf("webhook delivery failed after %d attempts: status %d", maxRetries, lastResult.StatusCode)
 }
 
+// DeliverEvent renders an event in the schema version negotiated for the endpoint
+// and delivers it. Deliveries in deprecated versions carry a Deprecation header and
+// a warning in the delivery result.
+func (p *WebhookProducer) DeliverEvent(ctx context.Context, endpoint EndpointConfig, event *events.EventPayload) (*DeliveryResult, error) {
+	return p.DeliverEnvelope(ctx, endpoint, NewEnvelope(event))
+}
+
+// DeliverEnvelope delivers an event envelope built earlier, so retries of the event
+// keep its ID
+func (p *WebhookProducer) DeliverEnvelope(ctx context.Context, endpoint EndpointConfig, envelope *Envelope) (*DeliveryResult, error) {
+	version, err := NegotiateSchemaVersion(endpoint.SchemaVersion)
+	if err != nil {
+		return nil, err
+	}
+	schema, _ := GetSchemaVersion(version)
+
+	payload, err := EncodeEnvelope(envelope, version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode webhook event: %w", err)
+	}
+
+	headers := make(map[string]string, len(endpoint.Headers)+3)
+	for k, v := range endpoint.Headers {
+		headers[k] = v
+	}
+	headers[HeaderSchemaVersion] = version
+	headers[HeaderEventType] = envelope.Type
+	warning := schema.DeprecationWarning()
+	if warning != "" {
+		headers[HeaderDeprecation] = "true"
+	}
+	endpoint.Headers = headers
+
+	result, err := p.Deliver(ctx, endpoint, envelope.Type, payload)
+	if result != nil {
+		result.EventID = envelope.ID
+		result.SchemaVersion = version
+		if warning != "" {
+			result.Warnings = append(result.Warnings, warning)
+		}
+	}
+	return result, err
+}
+
 func (p *WebhookProducer) doRequest(ctx context.Context, endpoint EndpointConfig, payload []byte, timeout time.Duration, attempt int) *DeliveryResult {
 	result := &DeliveryResult{
 		URL:         endpoint.URL,
@@ -158,6 +212,9 @@ func (p *WebhookProducer) doRequest(ctx context.Context, endpoint EndpointConfig
 	for k, v := range endpoint.Headers {
 		req.Header.Set(k, v)
 	}
+	if endpoint.Secret != "" {
+		req.Header.Set(HeaderSignature, Sign(endpoint.Secret, payload))
+	}
 
 	resp, err := p.httpClient.Do(req)
 	if err != nil {
@@ -176,3 +233,11 @@ func (p *WebhookProducer) doRequest(ctx context.Context, endpoint EndpointConfig
 
 	return result
 }
+
+// Sign returns the signature of a payload sent in HeaderSignature: "sha256=" followed
+// by the hex HMAC-SHA256 of the payload keyed with the endpoint secret
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
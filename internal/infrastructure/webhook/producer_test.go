@@ -2,12 +2,18 @@ package webhook
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/msgfy/linktor/internal/infrastructure/events"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -237,3 +243,83 @@ func TestDeliveryResult_Fields(t *testing.T) {
 	assert.Equal(t, 1, result.Attempt)
 	assert.False(t, result.DeliveredAt.IsZero())
 }
+
+func TestDeliverEvent_SchemaVersions(t *testing.T) {
+	var received *http.Request
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body = nil
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	event := &events.EventPayload{
+		TenantID:  "tenant-1",
+		EventType: "message.inbound",
+		Payload:   map[string]interface{}{"message_id": "msg-1"},
+		Timestamp: time.Now(),
+	}
+	p := testProducer()
+
+	t.Run("current version", func(t *testing.T) {
+		result, err := p.DeliverEvent(context.Background(), EndpointConfig{URL: server.URL, MaxRetries: 1}, event)
+		require.NoError(t, err)
+
+		assert.Equal(t, SchemaV2, result.SchemaVersion)
+		assert.Equal(t, body["id"], result.EventID)
+		assert.Empty(t, result.Warnings)
+		assert.Equal(t, SchemaV2, received.Header.Get(HeaderSchemaVersion))
+		assert.Equal(t, "message.inbound", received.Header.Get(HeaderEventType))
+		assert.Empty(t, received.Header.Get(HeaderDeprecation))
+	})
+
+	t.Run("deprecated version", func(t *testing.T) {
+		result, err := p.DeliverEvent(context.Background(), EndpointConfig{
+			URL:           server.URL,
+			MaxRetries:    1,
+			SchemaVersion: "v1",
+			Headers:       map[string]string{"X-Custom": "yes"},
+		}, event)
+		require.NoError(t, err)
+
+		assert.Equal(t, SchemaV1, result.SchemaVersion)
+		require.Len(t, result.Warnings, 1)
+		assert.Contains(t, result.Warnings[0], "v1 is deprecated")
+		assert.Equal(t, "true", received.Header.Get(HeaderDeprecation))
+		assert.Equal(t, "yes", received.Header.Get("X-Custom"))
+		assert.Equal(t, map[string]interface{}{"message_id": "msg-1"}, body["payload"])
+	})
+
+	t.Run("unsupported version", func(t *testing.T) {
+		_, err := p.DeliverEvent(context.Background(), EndpointConfig{URL: server.URL, SchemaVersion: "v9"}, event)
+		assert.Error(t, err)
+	})
+}
+
+func TestDeliverEnvelope_Signed(t *testing.T) {
+	var signature string
+	var payload []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get(HeaderSignature)
+		payload, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	envelope := NewEnvelope(&events.EventPayload{TenantID: "tenant-1", EventType: "message.received"})
+	p := testProducer()
+
+	for i := 0; i < 2; i++ {
+		result, err := p.DeliverEnvelope(context.Background(), EndpointConfig{URL: server.URL, MaxRetries: 1, Secret: "s3cret"}, envelope)
+		require.NoError(t, err)
+		assert.Equal(t, envelope.ID, result.EventID)
+		assert.Equal(t, Sign("s3cret", payload), signature)
+		assert.Contains(t, string(payload), envelope.ID)
+	}
+
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(payload)
+	assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), signature)
+}
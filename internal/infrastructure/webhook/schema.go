@@ -0,0 +1,182 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/msgfy/linktor/internal/infrastructure/events"
+)
+
+// Event payload schema versions
+const (
+	SchemaV1 = "v1"
+	SchemaV2 = "v2"
+
+	// CurrentSchemaVersion is the version new subscriptions receive unless they pin another
+	CurrentSchemaVersion = SchemaV2
+	// SchemaLatest lets a subscription follow the current version
+	SchemaLatest = "latest"
+)
+
+// Headers describing the payload contract of a delivery
+const (
+	HeaderSchemaVersion = "X-Linktor-Schema-Version"
+	HeaderDeprecation   = "Deprecation"
+	HeaderEventType     = "X-Linktor-Event"
+	HeaderSignature     = "X-Linktor-Signature"
+	HeaderDeliveryID    = "X-Linktor-Delivery"
+)
+
+// Envelope is the canonical form of an event delivered to webhooks. Older schema
+// versions are rendered from it by the compatibility layer.
+type Envelope struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	Version    string                 `json:"schema_version"`
+	TenantID   string                 `json:"tenant_id"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	Data       map[string]interface{} `json:"data"`
+}
+
+// envelopeV1 is the original payload format, mirroring the internal event stream
+type envelopeV1 struct {
+	Type      string                 `json:"type"`
+	TenantID  string                 `json:"tenant_id"`
+	Payload   map[string]interface{} `json:"payload"`
+	Timestamp time.Time              `json:"timestamp"`
+}
+
+// SchemaVersion describes a published webhook payload contract
+type SchemaVersion struct {
+	Version     string                 `json:"version"`
+	Description string                 `json:"description"`
+	Deprecated  bool                   `json:"deprecated"`
+	Successor   string                 `json:"successor,omitempty"`
+	Schema      map[string]interface{} `json:"schema"` // JSON Schema of the payload
+	encode      func(envelope *Envelope) interface{}
+}
+
+// DeprecationWarning returns the warning recorded for deliveries in a deprecated version
+func (v *SchemaVersion) DeprecationWarning() string {
+	if !v.Deprecated {
+		return ""
+	}
+	return fmt.Sprintf("webhook schema %s is deprecated; move the subscription to %s", v.Version, v.Successor)
+}
+
+var schemaVersions = []*SchemaVersion{
+	{
+		Version:     SchemaV1,
+		Description: "Original format: event type, tenant, payload and timestamp.",
+		Deprecated:  true,
+		Successor:   SchemaV2,
+		Schema: jsonSchema(SchemaV1, "Linktor webhook event (v1)", []string{"type", "tenant_id", "payload", "timestamp"}, map[string]interface{}{
+			"type":      map[string]interface{}{"type": "string", "description": "Event type, e.g. message.inbound"},
+			"tenant_id": map[string]interface{}{"type": "string"},
+			"payload":   map[string]interface{}{"type": "object"},
+			"timestamp": map[string]interface{}{"type": "string", "format": "date-time"},
+		}),
+		encode: func(envelope *Envelope) interface{} {
+			return &envelopeV1{
+				Type:      envelope.Type,
+				TenantID:  envelope.TenantID,
+				Payload:   envelope.Data,
+				Timestamp: envelope.OccurredAt,
+			}
+		},
+	},
+	{
+		Version:     SchemaV2,
+		Description: "Envelope with a unique event ID for deduplication and the schema version it follows.",
+		Schema: jsonSchema(SchemaV2, "Linktor webhook event (v2)", []string{"id", "type", "schema_version", "tenant_id", "occurred_at", "data"}, map[string]interface{}{
+			"id":             map[string]interface{}{"type": "string", "description": "Unique event ID, stable across retries"},
+			"type":           map[string]interface{}{"type": "string", "description": "Event type, e.g. message.inbound"},
+			"schema_version": map[string]interface{}{"const": SchemaV2},
+			"tenant_id":      map[string]interface{}{"type": "string"},
+			"occurred_at":    map[string]interface{}{"type": "string", "format": "date-time"},
+			"data":           map[string]interface{}{"type": "object"},
+		}),
+		encode: func(envelope *Envelope) interface{} {
+			return envelope
+		},
+	},
+}
+
+func jsonSchema(version, title string, required []string, properties map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"$schema":              "https://json-schema.org/draft/2020-12/schema",
+		"$id":                  "https://linktor.io/schemas/webhooks/" + version + ".json",
+		"title":                title,
+		"type":                 "object",
+		"required":             required,
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// SchemaVersions returns the published webhook payload schemas, oldest first
+func SchemaVersions() []*SchemaVersion {
+	out := make([]*SchemaVersion, len(schemaVersions))
+	copy(out, schemaVersions)
+	return out
+}
+
+// GetSchemaVersion returns a published schema version
+func GetSchemaVersion(version string) (*SchemaVersion, bool) {
+	for _, v := range schemaVersions {
+		if v.Version == version {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// NegotiateSchemaVersion resolves the version a subscription asked for. An empty
+// request or "latest" selects the current version; "1" is accepted for "v1".
+func NegotiateSchemaVersion(requested string) (string, error) {
+	requested = strings.ToLower(strings.TrimSpace(requested))
+	if requested == "" || requested == SchemaLatest {
+		return CurrentSchemaVersion, nil
+	}
+	if !strings.HasPrefix(requested, "v") {
+		requested = "v" + requested
+	}
+	if _, ok := GetSchemaVersion(requested); !ok {
+		return "", fmt.Errorf("unsupported webhook schema version %q", requested)
+	}
+	return requested, nil
+}
+
+// NewEnvelope builds the canonical envelope of an event
+func NewEnvelope(event *events.EventPayload) *Envelope {
+	occurredAt := event.Timestamp
+	if occurredAt.IsZero() {
+		occurredAt = time.Now()
+	}
+	data := event.Payload
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	return &Envelope{
+		ID:         uuid.New().String(),
+		Type:       event.EventType,
+		Version:    CurrentSchemaVersion,
+		TenantID:   event.TenantID,
+		OccurredAt: occurredAt.UTC(),
+		Data:       data,
+	}
+}
+
+// EncodeEnvelope renders an envelope in the given schema version
+func EncodeEnvelope(envelope *Envelope, version string) ([]byte, error) {
+	schema, ok := GetSchemaVersion(version)
+	if !ok {
+		return nil, fmt.Errorf("unsupported webhook schema version %q", version)
+	}
+	rendered := *envelope
+	rendered.Version = schema.Version
+	return json.Marshal(schema.encode(&rendered))
+}
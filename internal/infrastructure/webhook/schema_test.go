@@ -0,0 +1,112 @@
+package webhook
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/msgfy/linktor/internal/infrastructure/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateSchemaVersion(t *testing.T) {
+	tests := []struct {
+		requested string
+		want      string
+		wantErr   bool
+	}{
+		{"", CurrentSchemaVersion, false},
+		{"latest", CurrentSchemaVersion, false},
+		{"v1", SchemaV1, false},
+		{"1", SchemaV1, false},
+		{" V2 ", SchemaV2, false},
+		{"v9", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.requested, func(t *testing.T) {
+			got, err := NegotiateSchemaVersion(tt.requested)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEncodeEnvelope(t *testing.T) {
+	occurredAt := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	envelope := NewEnvelope(&events.EventPayload{
+		TenantID:  "tenant-1",
+		EventType: "message.inbound",
+		Payload:   map[string]interface{}{"message_id": "msg-1"},
+		Timestamp: occurredAt,
+	})
+	require.NotEmpty(t, envelope.ID)
+
+	t.Run("v2", func(t *testing.T) {
+		data, err := EncodeEnvelope(envelope, SchemaV2)
+		require.NoError(t, err)
+
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, envelope.ID, decoded["id"])
+		assert.Equal(t, "message.inbound", decoded["type"])
+		assert.Equal(t, SchemaV2, decoded["schema_version"])
+		assert.Equal(t, "2026-03-01T10:00:00Z", decoded["occurred_at"])
+		assert.Equal(t, map[string]interface{}{"message_id": "msg-1"}, decoded["data"])
+	})
+
+	t.Run("v1 compatibility", func(t *testing.T) {
+		data, err := EncodeEnvelope(envelope, SchemaV1)
+		require.NoError(t, err)
+
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, map[string]interface{}{
+			"type":      "message.inbound",
+			"tenant_id": "tenant-1",
+			"payload":   map[string]interface{}{"message_id": "msg-1"},
+			"timestamp": "2026-03-01T10:00:00Z",
+		}, decoded)
+	})
+
+	t.Run("unknown version", func(t *testing.T) {
+		_, err := EncodeEnvelope(envelope, "v9")
+		assert.Error(t, err)
+	})
+}
+
+func TestSchemaVersions_MatchPayloads(t *testing.T) {
+	envelope := NewEnvelope(&events.EventPayload{TenantID: "tenant-1", EventType: "contact.created"})
+
+	for _, version := range SchemaVersions() {
+		t.Run(version.Version, func(t *testing.T) {
+			data, err := EncodeEnvelope(envelope, version.Version)
+			require.NoError(t, err)
+
+			var decoded map[string]interface{}
+			require.NoError(t, json.Unmarshal(data, &decoded))
+
+			properties := version.Schema["properties"].(map[string]interface{})
+			for _, field := range version.Schema["required"].([]string) {
+				assert.Contains(t, decoded, field)
+			}
+			for field := range decoded {
+				assert.Contains(t, properties, field, "payload field missing from the published schema")
+			}
+		})
+	}
+}
+
+func TestSchemaVersion_DeprecationWarning(t *testing.T) {
+	v1, ok := GetSchemaVersion(SchemaV1)
+	require.True(t, ok)
+	assert.Contains(t, v1.DeprecationWarning(), "deprecated")
+
+	v2, ok := GetSchemaVersion(SchemaV2)
+	require.True(t, ok)
+	assert.Empty(t, v2.DeprecationWarning())
+}
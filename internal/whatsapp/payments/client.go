@@ -10,8 +10,10 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/msgfy/linktor/pkg/graphapi"
 )
 
@@ -27,6 +29,8 @@ type Client struct {
 	gatewayConfig  *GatewayConfig
 
 	store          PaymentStore
+	refundStore    RefundStore
+	refundPolicy   *RefundPolicy
 	organizationID string
 	channelID      string
 }
@@ -40,6 +44,8 @@ type ClientConfig struct {
 	OrganizationID string
 	ChannelID      string
 	Store          PaymentStore
+	RefundStore    RefundStore   // Keeps the refund audit trail and refunds waiting for approval
+	RefundPolicy   *RefundPolicy // Refunds above its thresholds need a second user's approval
 }
 
 // Gateway defines the interface for payment gateways
@@ -67,6 +73,8 @@ func NewClient(config *ClientConfig) *Client {
 		baseURL:        graphapi.BaseURL(),
 		gatewayConfig:  config.GatewayConfig,
 		store:          config.Store,
+		refundStore:    config.RefundStore,
+		refundPolicy:   config.RefundPolicy,
 		organizationID: config.OrganizationID,
 		channelID:      config.ChannelID,
 	}
@@ -152,6 +160,10 @@ func (c *Client) CreatePayment(ctx context.Context, req *PaymentRequest) (*Payme
 		return nil, fmt.Errorf("payment gateway not configured")
 	}
 
+	if err := c.ValidatePaymentRequest(req); err != nil {
+		return nil, err
+	}
+
 	// Create payment via gateway
 	gatewayResp, err := c.gateway.CreatePayment(ctx, req)
 	if err != nil {
@@ -170,6 +182,9 @@ func (c *Client) CreatePayment(ctx context.Context, req *PaymentRequest) (*Payme
 		Description:      req.Description,
 		GatewayPaymentID: gatewayResp.PaymentID,
 		ExpiresAt:        gatewayResp.ExpiresAt,
+		Taxes:            req.Taxes,
+		TaxAmount:        req.TaxAmount(),
+		Conversion:       req.Conversion,
 		CreatedAt:        time.Now(),
 		UpdatedAt:        time.Now(),
 	}
@@ -195,9 +210,59 @@ func (c *Client) CreatePayment(ctx context.Context, req *PaymentRequest) (*Payme
 	return gatewayResp, nil
 }
 
+// ValidatePaymentRequest checks the currency, items and taxes of a request against
+// the configured gateway
+func (c *Client) ValidatePaymentRequest(req *PaymentRequest) error {
+	if req.Currency == "" {
+		req.Currency = c.DefaultCurrency()
+	}
+	return ValidatePaymentRequest(c.gatewayType(), req)
+}
+
+// DefaultCurrency returns the currency the configured gateway charges by default
+func (c *Client) DefaultCurrency() string {
+	return DefaultCurrency(c.gatewayType())
+}
+
+// gatewayType returns the type of the configured gateway
+func (c *Client) gatewayType() GatewayType {
+	if c.gatewayConfig == nil {
+		return ""
+	}
+	return c.gatewayConfig.Type
+}
+
 // sendPaymentMessage sends a payment request message via WhatsApp
 func (c *Client) sendPaymentMessage(ctx context.Context, req *PaymentRequest, resp *PaymentResponse) (string, error) {
 	apiURL := c.buildURL(fmt.Sprintf("/%s/messages", c.phoneNumberID))
+	offset := CurrencyOffset(req.Currency)
+
+	// Taxes included in the item prices are already part of the subtotal
+	order := map[string]interface{}{
+		"status": "pending",
+		"items":  c.buildItemsPayload(req.Items, req.Currency),
+		"subtotal": map[string]interface{}{
+			"value":  req.Amount - req.AddedTaxAmount(),
+			"offset": offset,
+		},
+		"total": map[string]interface{}{
+			"value":  req.Amount,
+			"offset": offset,
+		},
+	}
+	if added := req.AddedTaxAmount(); added > 0 {
+		var names []string
+		for _, tax := range req.Taxes {
+			if !tax.Included {
+				names = append(names, tax.Name)
+			}
+		}
+		order["tax"] = map[string]interface{}{
+			"value":       added,
+			"offset":      offset,
+			"description": strings.Join(names, ", "),
+		}
+	}
 
 	// Build interactive order message
 	body := map[string]interface{}{
@@ -213,21 +278,11 @@ func (c *Client) sendPaymentMessage(ctx context.Context, req *PaymentRequest, re
 			"action": map[string]interface{}{
 				"name": "review_and_pay",
 				"parameters": map[string]interface{}{
-					"reference_id":   req.ReferenceID,
-					"type":           "digital-goods",
-					"payment_status": "pending",
-					"order": map[string]interface{}{
-						"status": "pending",
-						"items":  c.buildItemsPayload(req.Items),
-						"subtotal": map[string]interface{}{
-							"value":  req.Amount,
-							"offset": 100,
-						},
-						"total": map[string]interface{}{
-							"value":  req.Amount,
-							"offset": 100,
-						},
-					},
+					"reference_id":     req.ReferenceID,
+					"type":             "digital-goods",
+					"payment_status":   "pending",
+					"currency":         req.Currency,
+					"order":            order,
 					"payment_settings": c.buildPaymentSettings(req),
 				},
 			},
@@ -256,7 +311,7 @@ func (c *Client) sendPaymentMessage(ctx context.Context, req *PaymentRequest, re
 }
 
 // buildItemsPayload builds the items payload for WhatsApp message
-func (c *Client) buildItemsPayload(items []PaymentItem) []map[string]interface{} {
+func (c *Client) buildItemsPayload(items []PaymentItem, currency string) []map[string]interface{} {
 	result := make([]map[string]interface{}, len(items))
 	for i, item := range items {
 		result[i] = map[string]interface{}{
@@ -264,7 +319,7 @@ func (c *Client) buildItemsPayload(items []PaymentItem) []map[string]interface{}
 			"name":        item.Name,
 			"amount": map[string]interface{}{
 				"value":  item.TotalPrice,
-				"offset": 100,
+				"offset": CurrencyOffset(currency),
 			},
 			"quantity": item.Quantity,
 		}
@@ -344,7 +399,8 @@ func (c *Client) UpdatePaymentStatus(ctx context.Context, paymentID string, stat
 // Refund Operations
 // =============================================================================
 
-// ProcessRefund processes a refund for a payment
+// ProcessRefund processes a refund for a payment. Refunds above the approval
+// threshold are stored as pending approval instead of being sent to the gateway.
 func (c *Client) ProcessRefund(ctx context.Context, req *RefundRequest) (*Refund, error) {
 	if c.gateway == nil {
 		return nil, fmt.Errorf("payment gateway not configured")
@@ -365,35 +421,60 @@ func (c *Client) ProcessRefund(ctx context.Context, req *RefundRequest) (*Refund
 	if req.Amount == 0 {
 		req.Amount = payment.Amount
 	}
-
-	// Process refund via gateway
-	refund, err := c.gateway.ProcessRefund(ctx, req)
-	if err != nil {
-		return nil, fmt.Errorf("gateway error: %w", err)
+	if req.Amount < 0 || req.Amount > payment.Amount {
+		return nil, fmt.Errorf("refund amount must be between 1 and %d", payment.Amount)
 	}
 
-	// Update payment status
-	if req.Amount == payment.Amount {
-		c.UpdatePaymentStatus(ctx, req.PaymentID, PaymentStatusRefunded)
+	refund := &Refund{
+		ID:             uuid.New().String(),
+		PaymentID:      payment.ID,
+		OrganizationID: c.organizationID,
+		ChannelID:      c.channelID,
+		Amount:         req.Amount,
+		Currency:       payment.Currency,
+		Reason:         req.Reason,
+		RequestedBy:    req.RequestedBy,
+		CreatedAt:      time.Now(),
 	}
 
-	// Send refund notification
-	c.sendRefundNotification(ctx, payment, refund)
+	if c.refundPolicy.RequiresApproval(refund.Amount, refund.Currency) {
+		if c.refundStore == nil {
+			return nil, fmt.Errorf("refund store not configured")
+		}
+		refund.Status = RefundStatusPendingApproval
+		if err := c.refundStore.CreateRefund(ctx, refund); err != nil {
+			return nil, fmt.Errorf("failed to store refund: %w", err)
+		}
+		return refund, nil
+	}
 
+	if err := c.executeRefund(ctx, payment, refund, false); err != nil {
+		return nil, err
+	}
 	return refund, nil
 }
 
 // sendRefundNotification sends a refund notification message
 func (c *Client) sendRefundNotification(ctx context.Context, payment *Payment, refund *Refund) {
-	message := fmt.Sprintf("Your refund of %.2f %s has been processed for payment %s.",
-		float64(refund.Amount)/100, refund.Currency, payment.ReferenceID)
+	// Gateways do not always echo the currency; refunds are in the payment currency
+	currency := refund.Currency
+	if currency == "" {
+		currency = payment.Currency
+	}
+	message := fmt.Sprintf("Your refund of %s has been processed for payment %s.",
+		FormatAmount(refund.Amount, currency), payment.ReferenceID)
+
+	c.sendText(ctx, payment.CustomerPhone, message)
+}
 
+// sendText sends a plain text message to a customer
+func (c *Client) sendText(ctx context.Context, to, message string) {
 	apiURL := c.buildURL(fmt.Sprintf("/%s/messages", c.phoneNumberID))
 
 	body := map[string]interface{}{
 		"messaging_product": "whatsapp",
 		"recipient_type":    "individual",
-		"to":                payment.CustomerPhone,
+		"to":                to,
 		"type":              "text",
 		"text": map[string]string{
 			"body": message,
@@ -95,6 +95,49 @@ func TestClient_SendPaymentMessage_Success(t *testing.T) {
 	assert.Equal(t, float64(100), subtotal["offset"])
 }
 
+func TestClient_SendPaymentMessage_CurrencyOffsetAndTax(t *testing.T) {
+	var captured map[string]interface{}
+
+	client, server := newHTTPTestClient(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &captured)
+		_, _ = w.Write([]byte(`{"messages":[{"id":"wamid.pay-2"}]}`))
+	})
+	defer server.Close()
+
+	req := &PaymentRequest{
+		To:          "+815551234567",
+		ReferenceID: "ref-jp",
+		Amount:      1100,
+		Currency:    "JPY",
+		Items: []PaymentItem{
+			{Name: "Ticket", Quantity: 1, TotalPrice: 1000, Currency: "JPY"},
+		},
+		Taxes: []TaxLine{{Name: "Consumption tax", Rate: 10, Amount: 100}},
+	}
+
+	_, err := client.sendPaymentMessage(context.Background(), req, &PaymentResponse{PaymentID: "pay-2"})
+	require.NoError(t, err)
+
+	params := captured["interactive"].(map[string]interface{})["action"].(map[string]interface{})["parameters"].(map[string]interface{})
+	assert.Equal(t, "JPY", params["currency"])
+	order := params["order"].(map[string]interface{})
+
+	subtotal := order["subtotal"].(map[string]interface{})
+	assert.Equal(t, float64(1000), subtotal["value"])
+	assert.Equal(t, float64(1), subtotal["offset"])
+
+	tax := order["tax"].(map[string]interface{})
+	assert.Equal(t, float64(100), tax["value"])
+	assert.Equal(t, "Consumption tax", tax["description"])
+
+	total := order["total"].(map[string]interface{})
+	assert.Equal(t, float64(1100), total["value"])
+
+	item := order["items"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, float64(1), item["amount"].(map[string]interface{})["offset"])
+}
+
 func TestClient_SendPaymentMessage_APIError(t *testing.T) {
 	client, server := newHTTPTestClient(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
@@ -162,6 +205,7 @@ func TestClient_SendRefundNotification_HitsMessagesEndpoint(t *testing.T) {
 	text := captured["text"].(map[string]interface{})["body"].(string)
 	assert.Contains(t, text, "refund")
 	assert.Contains(t, text, "ref-42")
+	assert.Contains(t, text, "50.00 USD")
 }
 
 // -----------------------------------------------------------------------------
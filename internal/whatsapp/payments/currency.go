@@ -0,0 +1,145 @@
+package payments
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// currencyExponents lists the ISO 4217 currencies whose minor unit is not the cent.
+// Every other currency has two decimal places.
+var currencyExponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// gatewayCurrencies lists the currencies each gateway settles. Gateways not listed
+// accept any currency.
+var gatewayCurrencies = map[GatewayType][]string{
+	GatewayRazorpay:    {"INR"},
+	GatewayPayU:        {"INR"},
+	GatewayPagSeguro:   {"BRL"},
+	GatewayMercadoPago: {"BRL", "ARS", "CLP", "COP", "MXN", "PEN", "UYU"},
+}
+
+// NormalizeCurrency returns the upper case ISO 4217 code of a currency
+func NormalizeCurrency(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
+
+// CurrencyOffset returns the number of minor units in one unit of the currency,
+// the "offset" WhatsApp order messages expect (100 for cents, 1 for JPY)
+func CurrencyOffset(currency string) int {
+	exponent, ok := currencyExponents[NormalizeCurrency(currency)]
+	if !ok {
+		exponent = 2
+	}
+	return int(math.Pow10(exponent))
+}
+
+// FormatAmount formats an amount in minor units with the currency code, e.g. "12.34 USD"
+func FormatAmount(amount int64, currency string) string {
+	currency = NormalizeCurrency(currency)
+	exponent, ok := currencyExponents[currency]
+	if !ok {
+		exponent = 2
+	}
+	value := strconv.FormatFloat(float64(amount)/math.Pow10(exponent), 'f', exponent, 64)
+	return strings.TrimSpace(value + " " + currency)
+}
+
+// SupportedCurrencies returns the currencies a gateway settles, or nil when the
+// gateway accepts any currency
+func SupportedCurrencies(gateway GatewayType) []string {
+	return gatewayCurrencies[gateway]
+}
+
+// DefaultCurrency returns the currency used when a request does not set one
+func DefaultCurrency(gateway GatewayType) string {
+	if currencies := gatewayCurrencies[gateway]; len(currencies) > 0 {
+		return currencies[0]
+	}
+	return "BRL"
+}
+
+// validCurrencyCode tells whether a currency is a three letter code
+func validCurrencyCode(currency string) bool {
+	if len(currency) != 3 {
+		return false
+	}
+	for _, r := range currency {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidatePaymentRequest normalizes the currencies of a payment request and checks
+// them against the gateway, and that the items and taxes add up to the amount
+func ValidatePaymentRequest(gateway GatewayType, req *PaymentRequest) error {
+	if req.Amount <= 0 {
+		return fmt.Errorf("amount must be greater than 0")
+	}
+
+	req.Currency = NormalizeCurrency(req.Currency)
+	if !validCurrencyCode(req.Currency) {
+		return fmt.Errorf("invalid currency: %q", req.Currency)
+	}
+	if supported := gatewayCurrencies[gateway]; len(supported) > 0 {
+		allowed := false
+		for _, currency := range supported {
+			if currency == req.Currency {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Errorf("gateway %s does not support %s, supported currencies: %s",
+				gateway, req.Currency, strings.Join(supported, ", "))
+		}
+	}
+
+	var itemsTotal int64
+	for i := range req.Items {
+		item := &req.Items[i]
+		if item.Currency != "" && NormalizeCurrency(item.Currency) != req.Currency {
+			return fmt.Errorf("item %q is in %s, payment is in %s", item.Name, item.Currency, req.Currency)
+		}
+		item.Currency = req.Currency
+		itemsTotal += item.TotalPrice
+	}
+
+	for _, tax := range req.Taxes {
+		if tax.Name == "" {
+			return fmt.Errorf("tax name is required")
+		}
+		if tax.Amount < 0 || tax.Rate < 0 {
+			return fmt.Errorf("tax %q cannot be negative", tax.Name)
+		}
+	}
+	if req.AddedTaxAmount() >= req.Amount {
+		return fmt.Errorf("taxes exceed the payment amount")
+	}
+	if len(req.Items) > 0 && itemsTotal+req.AddedTaxAmount() != req.Amount {
+		return fmt.Errorf("amount %d does not match items total %d plus taxes %d",
+			req.Amount, itemsTotal, req.AddedTaxAmount())
+	}
+
+	if conversion := req.Conversion; conversion != nil {
+		conversion.SourceCurrency = NormalizeCurrency(conversion.SourceCurrency)
+		if !validCurrencyCode(conversion.SourceCurrency) {
+			return fmt.Errorf("invalid conversion source currency: %q", conversion.SourceCurrency)
+		}
+		if conversion.SourceCurrency == req.Currency {
+			return fmt.Errorf("conversion source currency must differ from the payment currency")
+		}
+		if conversion.Rate <= 0 || conversion.SourceAmount <= 0 {
+			return fmt.Errorf("conversion rate and source amount must be greater than 0")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package payments
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrencyOffset(t *testing.T) {
+	assert.Equal(t, 100, CurrencyOffset("BRL"))
+	assert.Equal(t, 100, CurrencyOffset("usd"))
+	assert.Equal(t, 1, CurrencyOffset("JPY"))
+	assert.Equal(t, 1000, CurrencyOffset("KWD"))
+}
+
+func TestFormatAmount(t *testing.T) {
+	assert.Equal(t, "12.34 USD", FormatAmount(1234, "usd"))
+	assert.Equal(t, "1234 JPY", FormatAmount(1234, "JPY"))
+	assert.Equal(t, "1.234 BHD", FormatAmount(1234, "BHD"))
+}
+
+func TestDefaultCurrency(t *testing.T) {
+	assert.Equal(t, "INR", DefaultCurrency(GatewayRazorpay))
+	assert.Equal(t, "BRL", DefaultCurrency(GatewayPagSeguro))
+	assert.Equal(t, "BRL", DefaultCurrency(GatewayStripe))
+	assert.Nil(t, SupportedCurrencies(GatewayStripe))
+}
+
+func TestValidatePaymentRequest(t *testing.T) {
+	valid := func() *PaymentRequest {
+		return &PaymentRequest{
+			Amount:   11000,
+			Currency: "brl",
+			Items: []PaymentItem{
+				{Name: "Plan", Quantity: 1, TotalPrice: 10000},
+			},
+			Taxes: []TaxLine{
+				{Name: "ISS", Rate: 10, Amount: 1000},
+				{Name: "ICMS", Amount: 500, Included: true},
+			},
+		}
+	}
+
+	req := valid()
+	require.NoError(t, ValidatePaymentRequest(GatewayPagSeguro, req))
+	assert.Equal(t, "BRL", req.Currency)
+	assert.Equal(t, "BRL", req.Items[0].Currency)
+	assert.Equal(t, int64(1500), req.TaxAmount())
+	assert.Equal(t, int64(1000), req.AddedTaxAmount())
+
+	tests := []struct {
+		name   string
+		mutate func(req *PaymentRequest)
+	}{
+		{"unsupported currency", func(req *PaymentRequest) { req.Currency = "USD"; req.Items[0].Currency = "USD" }},
+		{"invalid currency", func(req *PaymentRequest) { req.Currency = "R$" }},
+		{"item in another currency", func(req *PaymentRequest) { req.Items[0].Currency = "USD" }},
+		{"amount does not add up", func(req *PaymentRequest) { req.Amount = 10000 }},
+		{"negative tax", func(req *PaymentRequest) { req.Taxes[0].Amount = -1 }},
+		{"unnamed tax", func(req *PaymentRequest) { req.Taxes[0].Name = "" }},
+		{"conversion to the same currency", func(req *PaymentRequest) {
+			req.Conversion = &CurrencyConversion{SourceCurrency: "BRL", SourceAmount: 2000, Rate: 5.5}
+		}},
+		{"conversion without rate", func(req *PaymentRequest) {
+			req.Conversion = &CurrencyConversion{SourceCurrency: "USD", SourceAmount: 2000}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid()
+			tt.mutate(req)
+			assert.Error(t, ValidatePaymentRequest(GatewayPagSeguro, req))
+		})
+	}
+
+	req = valid()
+	req.Currency = "usd"
+	req.Items[0].Currency = "USD"
+	req.Conversion = &CurrencyConversion{SourceCurrency: "brl", SourceAmount: 55000, Rate: 0.2}
+	require.NoError(t, ValidatePaymentRequest(GatewayStripe, req), "gateways without constraints accept any currency")
+	assert.Equal(t, "BRL", req.Conversion.SourceCurrency)
+}
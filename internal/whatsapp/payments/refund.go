@@ -0,0 +1,234 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Refund review errors, for callers that map them to responses
+var (
+	ErrRefundNotFound      = errors.New("refund not found")
+	ErrRefundNotReviewable = errors.New("refund cannot be reviewed")
+)
+
+// RefundPolicy decides which refunds need a second user's approval. Thresholds
+// are in minor units per currency; "*" applies to currencies not listed. Refunds
+// above the threshold wait for approval, currencies without a threshold never do.
+type RefundPolicy struct {
+	Thresholds map[string]int64 `json:"thresholds"`
+}
+
+// ParseRefundPolicy parses thresholds written as "BRL:50000,USD:10000,*:0"
+func ParseRefundPolicy(spec string) (*RefundPolicy, error) {
+	policy := &RefundPolicy{Thresholds: make(map[string]int64)}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		currency, value, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid refund threshold %q, expected CURRENCY:AMOUNT", entry)
+		}
+		currency = NormalizeCurrency(currency)
+		if currency != "*" && !validCurrencyCode(currency) {
+			return nil, fmt.Errorf("invalid refund threshold currency %q", currency)
+		}
+		threshold, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil || threshold < 0 {
+			return nil, fmt.Errorf("invalid refund threshold amount %q", value)
+		}
+		policy.Thresholds[currency] = threshold
+	}
+	return policy, nil
+}
+
+// RequiresApproval tells whether a refund of the amount needs approval
+func (p *RefundPolicy) RequiresApproval(amount int64, currency string) bool {
+	if p == nil {
+		return false
+	}
+	threshold, ok := p.Thresholds[NormalizeCurrency(currency)]
+	if !ok {
+		threshold, ok = p.Thresholds["*"]
+	}
+	return ok && amount > threshold
+}
+
+// ApproveRefund executes a refund waiting for approval. The approver must not be the
+// user who requested it; the customer is notified of the outcome.
+func (c *Client) ApproveRefund(ctx context.Context, refundID, approverID, note string) (*Refund, error) {
+	if c.gateway == nil {
+		return nil, fmt.Errorf("payment gateway not configured")
+	}
+
+	refund, payment, err := c.pendingRefund(ctx, refundID, approverID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	refund.ReviewedBy = approverID
+	refund.ReviewNote = note
+	refund.ReviewedAt = &now
+
+	if err := c.executeRefund(ctx, payment, refund, true); err != nil {
+		return nil, err
+	}
+	return refund, nil
+}
+
+// RejectRefund declines a refund waiting for approval and tells the customer
+func (c *Client) RejectRefund(ctx context.Context, refundID, reviewerID, note string) (*Refund, error) {
+	refund, payment, err := c.pendingRefund(ctx, refundID, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	refund.Status = RefundStatusRejected
+	refund.ReviewedBy = reviewerID
+	refund.ReviewNote = note
+	refund.ReviewedAt = &now
+	if err := c.refundStore.UpdateRefund(ctx, refund); err != nil {
+		return nil, fmt.Errorf("failed to update refund: %w", err)
+	}
+
+	c.sendRefundRejectedNotification(ctx, payment, refund)
+	return refund, nil
+}
+
+// GetRefundReport returns the refunds of the channel with totals per currency and status
+func (c *Client) GetRefundReport(ctx context.Context, filter *RefundFilter) (*RefundReport, error) {
+	if c.refundStore == nil {
+		return nil, fmt.Errorf("refund store not configured")
+	}
+	if filter == nil {
+		filter = &RefundFilter{}
+	}
+	filter.ChannelID = c.channelID
+
+	refunds, err := c.refundStore.ListRefunds(ctx, c.organizationID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RefundReport{Refunds: refunds}
+	totals := make(map[[2]string]*RefundTotals)
+	for _, refund := range refunds {
+		key := [2]string{refund.Currency, string(refund.Status)}
+		total, ok := totals[key]
+		if !ok {
+			total = &RefundTotals{Currency: refund.Currency, Status: refund.Status}
+			totals[key] = total
+		}
+		total.Count++
+		total.Amount += refund.Amount
+	}
+	for _, total := range totals {
+		report.Totals = append(report.Totals, *total)
+	}
+	sort.Slice(report.Totals, func(i, j int) bool {
+		if report.Totals[i].Currency != report.Totals[j].Currency {
+			return report.Totals[i].Currency < report.Totals[j].Currency
+		}
+		return report.Totals[i].Status < report.Totals[j].Status
+	})
+
+	return report, nil
+}
+
+// pendingRefund loads a refund of the channel waiting for approval and its payment
+func (c *Client) pendingRefund(ctx context.Context, refundID, reviewerID string) (*Refund, *Payment, error) {
+	if c.refundStore == nil {
+		return nil, nil, fmt.Errorf("refund store not configured")
+	}
+
+	refund, err := c.refundStore.GetRefund(ctx, refundID)
+	if err != nil || refund.ChannelID != c.channelID {
+		return nil, nil, fmt.Errorf("%w: %s", ErrRefundNotFound, refundID)
+	}
+	if refund.Status != RefundStatusPendingApproval {
+		return nil, nil, fmt.Errorf("%w: status is %s", ErrRefundNotReviewable, refund.Status)
+	}
+	if reviewerID == "" || reviewerID == refund.RequestedBy {
+		return nil, nil, fmt.Errorf("%w: it must be reviewed by a user other than the requester", ErrRefundNotReviewable)
+	}
+
+	payment, err := c.GetPayment(ctx, refund.PaymentID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("payment not found: %s", refund.PaymentID)
+	}
+	return refund, payment, nil
+}
+
+// executeRefund sends a refund to the gateway, records the result and notifies the
+// customer when it succeeds. stored tells whether the refund already has a record.
+func (c *Client) executeRefund(ctx context.Context, payment *Payment, refund *Refund, stored bool) error {
+	gatewayRefund, err := c.gateway.ProcessRefund(ctx, &RefundRequest{
+		PaymentID: payment.ID,
+		Amount:    refund.Amount,
+		Reason:    refund.Reason,
+	})
+	if err != nil {
+		refund.Status = RefundStatusFailed
+		refund.FailureReason = err.Error()
+		c.saveRefund(ctx, refund, stored)
+		return fmt.Errorf("gateway error: %w", err)
+	}
+
+	refund.Status = gatewayRefund.Status
+	refund.GatewayRefundID = gatewayRefund.GatewayRefundID
+	if refund.GatewayRefundID == "" {
+		refund.GatewayRefundID = gatewayRefund.ID
+	}
+	refund.ProcessedAt = gatewayRefund.ProcessedAt
+	if refund.ProcessedAt == nil {
+		now := time.Now()
+		refund.ProcessedAt = &now
+	}
+	if err := c.saveRefund(ctx, refund, stored); err != nil {
+		return err
+	}
+
+	// Update payment status
+	if refund.Amount == payment.Amount {
+		c.UpdatePaymentStatus(ctx, payment.ID, PaymentStatusRefunded)
+	}
+
+	// Send refund notification
+	c.sendRefundNotification(ctx, payment, refund)
+	return nil
+}
+
+// saveRefund creates or updates the audit record of a refund, when refunds are stored
+func (c *Client) saveRefund(ctx context.Context, refund *Refund, stored bool) error {
+	if c.refundStore == nil {
+		return nil
+	}
+	if !stored {
+		if err := c.refundStore.CreateRefund(ctx, refund); err != nil {
+			return fmt.Errorf("failed to store refund: %w", err)
+		}
+		return nil
+	}
+	if err := c.refundStore.UpdateRefund(ctx, refund); err != nil {
+		return fmt.Errorf("failed to update refund: %w", err)
+	}
+	return nil
+}
+
+// sendRefundRejectedNotification tells the customer a refund request was declined
+func (c *Client) sendRefundRejectedNotification(ctx context.Context, payment *Payment, refund *Refund) {
+	message := fmt.Sprintf("Your refund request of %s for payment %s was not approved.",
+		FormatAmount(refund.Amount, refund.Currency), payment.ReferenceID)
+	if refund.ReviewNote != "" {
+		message += " " + refund.ReviewNote
+	}
+	c.sendText(ctx, payment.CustomerPhone, message)
+}
@@ -0,0 +1,241 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockRefundStore struct {
+	refunds map[string]*Refund
+}
+
+func newMockRefundStore() *mockRefundStore {
+	return &mockRefundStore{refunds: make(map[string]*Refund)}
+}
+
+func (m *mockRefundStore) CreateRefund(ctx context.Context, refund *Refund) error {
+	stored := *refund
+	m.refunds[refund.ID] = &stored
+	return nil
+}
+
+func (m *mockRefundStore) GetRefund(ctx context.Context, id string) (*Refund, error) {
+	refund, ok := m.refunds[id]
+	if !ok {
+		return nil, fmt.Errorf("refund not found")
+	}
+	loaded := *refund
+	return &loaded, nil
+}
+
+func (m *mockRefundStore) UpdateRefund(ctx context.Context, refund *Refund) error {
+	stored := *refund
+	m.refunds[refund.ID] = &stored
+	return nil
+}
+
+func (m *mockRefundStore) ListRefunds(ctx context.Context, orgID string, filter *RefundFilter) ([]*Refund, error) {
+	var result []*Refund
+	for _, refund := range m.refunds {
+		if refund.OrganizationID != orgID || refund.ChannelID != filter.ChannelID {
+			continue
+		}
+		if filter.Status != "" && refund.Status != filter.Status {
+			continue
+		}
+		result = append(result, refund)
+	}
+	return result, nil
+}
+
+// newRefundTestClient returns a client with a mock gateway and a successful BRL payment
+// of 100.00, capturing the text messages sent to the customer
+func newRefundTestClient(t *testing.T, policy string) (*Client, *mockRefundStore, *[]string) {
+	var mu sync.Mutex
+	var texts []string
+	c, server := newHTTPTestClient(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if text, ok := body["text"].(map[string]interface{}); ok {
+			mu.Lock()
+			texts = append(texts, text["body"].(string))
+			mu.Unlock()
+		}
+		w.Write([]byte(`{"messages":[{"id":"wamid.1"}]}`))
+	})
+	t.Cleanup(server.Close)
+
+	refundPolicy, err := ParseRefundPolicy(policy)
+	require.NoError(t, err)
+
+	refundStore := newMockRefundStore()
+	store := newMockPaymentStore()
+	c.store = store
+	c.refundStore = refundStore
+	c.refundPolicy = refundPolicy
+	c.gateway = &MockGateway{}
+	c.organizationID = "org-1"
+	c.channelID = "channel-1"
+
+	require.NoError(t, store.Create(context.Background(), &Payment{
+		ID:            "pay-1",
+		ReferenceID:   "ORDER-1",
+		CustomerPhone: "5511999999999",
+		Amount:        10000,
+		Currency:      "BRL",
+		Status:        PaymentStatusSuccess,
+	}))
+	return c, refundStore, &texts
+}
+
+func TestParseRefundPolicy(t *testing.T) {
+	policy, err := ParseRefundPolicy("brl:50000, USD:10000,*:0")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"BRL": 50000, "USD": 10000, "*": 0}, policy.Thresholds)
+
+	assert.False(t, policy.RequiresApproval(50000, "BRL"))
+	assert.True(t, policy.RequiresApproval(50001, "BRL"))
+	assert.True(t, policy.RequiresApproval(1, "EUR"))
+
+	empty, err := ParseRefundPolicy("")
+	require.NoError(t, err)
+	assert.False(t, empty.RequiresApproval(1000000, "BRL"))
+
+	var none *RefundPolicy
+	assert.False(t, none.RequiresApproval(1000000, "BRL"))
+
+	for _, spec := range []string{"BRL", "BRL:abc", "BRL:-1", "XX:100"} {
+		_, err := ParseRefundPolicy(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestClient_ProcessRefund_BelowThresholdExecutes(t *testing.T) {
+	c, store, texts := newRefundTestClient(t, "BRL:50000")
+
+	refund, err := c.ProcessRefund(context.Background(), &RefundRequest{PaymentID: "pay-1", Amount: 5000, RequestedBy: "user-1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, RefundStatusSuccess, refund.Status)
+	assert.Equal(t, RefundStatusSuccess, store.refunds[refund.ID].Status)
+	assert.Equal(t, "user-1", store.refunds[refund.ID].RequestedBy)
+	assert.Len(t, *texts, 1)
+}
+
+func TestClient_ProcessRefund_AboveThresholdWaitsForApproval(t *testing.T) {
+	c, store, texts := newRefundTestClient(t, "BRL:1000")
+
+	refund, err := c.ProcessRefund(context.Background(), &RefundRequest{PaymentID: "pay-1", RequestedBy: "user-1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, RefundStatusPendingApproval, refund.Status)
+	assert.Equal(t, int64(10000), refund.Amount)
+	assert.Equal(t, RefundStatusPendingApproval, store.refunds[refund.ID].Status)
+	assert.Nil(t, refund.ProcessedAt)
+	assert.Empty(t, *texts)
+
+	payment, err := c.GetPayment(context.Background(), "pay-1")
+	require.NoError(t, err)
+	assert.Equal(t, PaymentStatusSuccess, payment.Status)
+}
+
+func TestClient_ProcessRefund_ApprovalNeedsStore(t *testing.T) {
+	c, _, _ := newRefundTestClient(t, "*:0")
+	c.refundStore = nil
+
+	_, err := c.ProcessRefund(context.Background(), &RefundRequest{PaymentID: "pay-1"})
+	assert.Error(t, err)
+}
+
+func TestClient_ApproveRefund(t *testing.T) {
+	c, store, texts := newRefundTestClient(t, "*:0")
+	ctx := context.Background()
+
+	pending, err := c.ProcessRefund(ctx, &RefundRequest{PaymentID: "pay-1", RequestedBy: "user-1"})
+	require.NoError(t, err)
+
+	_, err = c.ApproveRefund(ctx, pending.ID, "user-1", "")
+	assert.ErrorIs(t, err, ErrRefundNotReviewable)
+
+	refund, err := c.ApproveRefund(ctx, pending.ID, "finance-1", "checked with the customer")
+	require.NoError(t, err)
+	assert.Equal(t, RefundStatusSuccess, refund.Status)
+	assert.Equal(t, "finance-1", refund.ReviewedBy)
+	assert.NotNil(t, refund.ReviewedAt)
+	assert.NotNil(t, refund.ProcessedAt)
+	assert.Equal(t, RefundStatusSuccess, store.refunds[pending.ID].Status)
+	require.Len(t, *texts, 1)
+	assert.Contains(t, (*texts)[0], "100.00 BRL")
+
+	payment, err := c.GetPayment(ctx, "pay-1")
+	require.NoError(t, err)
+	assert.Equal(t, PaymentStatusRefunded, payment.Status)
+
+	// Refunds are reviewed once
+	_, err = c.ApproveRefund(ctx, pending.ID, "finance-2", "")
+	assert.ErrorIs(t, err, ErrRefundNotReviewable)
+}
+
+func TestClient_RejectRefund(t *testing.T) {
+	c, store, texts := newRefundTestClient(t, "*:0")
+	ctx := context.Background()
+
+	pending, err := c.ProcessRefund(ctx, &RefundRequest{PaymentID: "pay-1", RequestedBy: "user-1"})
+	require.NoError(t, err)
+
+	refund, err := c.RejectRefund(ctx, pending.ID, "finance-1", "Outside the refund window.")
+	require.NoError(t, err)
+	assert.Equal(t, RefundStatusRejected, refund.Status)
+	assert.Equal(t, RefundStatusRejected, store.refunds[pending.ID].Status)
+	require.Len(t, *texts, 1)
+	assert.Contains(t, (*texts)[0], "not approved")
+	assert.Contains(t, (*texts)[0], "Outside the refund window.")
+
+	payment, err := c.GetPayment(ctx, "pay-1")
+	require.NoError(t, err)
+	assert.Equal(t, PaymentStatusSuccess, payment.Status)
+}
+
+func TestClient_ReviewRefund_OtherChannel(t *testing.T) {
+	c, store, _ := newRefundTestClient(t, "*:0")
+	store.refunds["ref-x"] = &Refund{ID: "ref-x", ChannelID: "channel-2", Status: RefundStatusPendingApproval}
+
+	_, err := c.ApproveRefund(context.Background(), "ref-x", "finance-1", "")
+	assert.ErrorIs(t, err, ErrRefundNotFound)
+	_, err = c.RejectRefund(context.Background(), "missing", "finance-1", "")
+	assert.ErrorIs(t, err, ErrRefundNotFound)
+}
+
+func TestClient_GetRefundReport(t *testing.T) {
+	c, store, _ := newRefundTestClient(t, "")
+	for _, refund := range []*Refund{
+		{ID: "r1", OrganizationID: "org-1", ChannelID: "channel-1", Currency: "BRL", Amount: 1000, Status: RefundStatusSuccess},
+		{ID: "r2", OrganizationID: "org-1", ChannelID: "channel-1", Currency: "BRL", Amount: 2500, Status: RefundStatusSuccess},
+		{ID: "r3", OrganizationID: "org-1", ChannelID: "channel-1", Currency: "BRL", Amount: 90000, Status: RefundStatusPendingApproval},
+		{ID: "r4", OrganizationID: "org-1", ChannelID: "channel-1", Currency: "USD", Amount: 700, Status: RefundStatusRejected},
+		{ID: "r5", OrganizationID: "org-1", ChannelID: "channel-2", Currency: "BRL", Amount: 100, Status: RefundStatusSuccess},
+	} {
+		store.refunds[refund.ID] = refund
+	}
+
+	report, err := c.GetRefundReport(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Len(t, report.Refunds, 4)
+	assert.Equal(t, []RefundTotals{
+		{Currency: "BRL", Status: RefundStatusPendingApproval, Count: 1, Amount: 90000},
+		{Currency: "BRL", Status: RefundStatusSuccess, Count: 2, Amount: 3500},
+		{Currency: "USD", Status: RefundStatusRejected, Count: 1, Amount: 700},
+	}, report.Totals)
+
+	pending, err := c.GetRefundReport(context.Background(), &RefundFilter{Status: RefundStatusPendingApproval})
+	require.NoError(t, err)
+	require.Len(t, pending.Refunds, 1)
+	assert.Equal(t, "r3", pending.Refunds[0].ID)
+}
@@ -11,3 +11,11 @@ type PaymentStore interface {
 	GetByCustomer(ctx context.Context, customerPhone string) ([]*Payment, error)
 	GetStats(ctx context.Context, orgID string) (*PaymentStats, error)
 }
+
+// RefundStore defines the interface for refund persistence
+type RefundStore interface {
+	CreateRefund(ctx context.Context, refund *Refund) error
+	GetRefund(ctx context.Context, id string) (*Refund, error)
+	UpdateRefund(ctx context.Context, refund *Refund) error
+	ListRefunds(ctx context.Context, orgID string, filter *RefundFilter) ([]*Refund, error)
+}
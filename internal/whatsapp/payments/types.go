@@ -69,6 +69,29 @@ type Payment struct {
 	MessageID         string        `json:"message_id,omitempty"`
 	CreatedAt         time.Time     `json:"created_at"`
 	UpdatedAt         time.Time     `json:"updated_at"`
+
+	Taxes      []TaxLine           `json:"taxes,omitempty"`
+	TaxAmount  int64               `json:"tax_amount,omitempty"` // Sum of the tax lines, in minor units
+	Conversion *CurrencyConversion `json:"conversion,omitempty"`
+}
+
+// TaxLine represents a tax applied to a payment. Amounts are in minor units of
+// the payment currency.
+type TaxLine struct {
+	Name     string  `json:"name"`
+	Rate     float64 `json:"rate,omitempty"` // Percentage, informational
+	Amount   int64   `json:"amount"`
+	Included bool    `json:"included"` // Already part of the item prices, e.g. VAT
+}
+
+// CurrencyConversion records how a payment amount was converted from the currency
+// the price was set in to the currency the gateway charges
+type CurrencyConversion struct {
+	SourceCurrency string     `json:"source_currency"`
+	SourceAmount   int64      `json:"source_amount"` // In minor units of the source currency
+	Rate           float64    `json:"rate"`          // Payment currency units per source currency unit
+	Provider       string     `json:"provider,omitempty"`
+	ConvertedAt    *time.Time `json:"converted_at,omitempty"`
 }
 
 // PaymentItem represents an item in a payment
@@ -98,6 +121,29 @@ type PaymentRequest struct {
 	ExpiresIn       time.Duration  `json:"expires_in,omitempty"` // Duration until expiry
 	CallbackURL     string         `json:"callback_url,omitempty"`
 	PaymentSettings *PaymentSettings `json:"payment_settings,omitempty"`
+
+	Taxes      []TaxLine           `json:"taxes,omitempty"`
+	Conversion *CurrencyConversion `json:"conversion,omitempty"`
+}
+
+// TaxAmount returns the sum of all tax lines
+func (r *PaymentRequest) TaxAmount() int64 {
+	var total int64
+	for _, tax := range r.Taxes {
+		total += tax.Amount
+	}
+	return total
+}
+
+// AddedTaxAmount returns the taxes charged on top of the item prices
+func (r *PaymentRequest) AddedTaxAmount() int64 {
+	var total int64
+	for _, tax := range r.Taxes {
+		if !tax.Included {
+			total += tax.Amount
+		}
+	}
+	return total
 }
 
 // PaymentSettings represents payment configuration
@@ -174,31 +220,62 @@ const (
 type RefundStatus string
 
 const (
-	RefundStatusPending    RefundStatus = "pending"
-	RefundStatusProcessing RefundStatus = "processing"
-	RefundStatusSuccess    RefundStatus = "success"
-	RefundStatusFailed     RefundStatus = "failed"
+	RefundStatusPendingApproval RefundStatus = "pending_approval" // Above the approval threshold, waiting for finance
+	RefundStatusPending         RefundStatus = "pending"
+	RefundStatusProcessing      RefundStatus = "processing"
+	RefundStatusSuccess         RefundStatus = "success"
+	RefundStatusFailed          RefundStatus = "failed"
+	RefundStatusRejected        RefundStatus = "rejected" // Declined by the approver, never sent to the gateway
 )
 
 // Refund represents a payment refund
 type Refund struct {
 	ID              string       `json:"id"`
 	PaymentID       string       `json:"payment_id"`
+	OrganizationID  string       `json:"organization_id,omitempty"`
+	ChannelID       string       `json:"channel_id,omitempty"`
 	Amount          int64        `json:"amount"`
 	Currency        string       `json:"currency"`
 	Status          RefundStatus `json:"status"`
 	Reason          string       `json:"reason,omitempty"`
 	GatewayRefundID string       `json:"gateway_refund_id,omitempty"`
 	FailureReason   string       `json:"failure_reason,omitempty"`
+	RequestedBy     string       `json:"requested_by,omitempty"`
+	ReviewedBy      string       `json:"reviewed_by,omitempty"`
+	ReviewNote      string       `json:"review_note,omitempty"`
+	ReviewedAt      *time.Time   `json:"reviewed_at,omitempty"`
 	CreatedAt       time.Time    `json:"created_at"`
 	ProcessedAt     *time.Time   `json:"processed_at,omitempty"`
 }
 
 // RefundRequest represents a request to refund a payment
 type RefundRequest struct {
-	PaymentID string `json:"payment_id"`
-	Amount    int64  `json:"amount,omitempty"` // If 0, full refund
-	Reason    string `json:"reason,omitempty"`
+	PaymentID   string `json:"payment_id"`
+	Amount      int64  `json:"amount,omitempty"` // If 0, full refund
+	Reason      string `json:"reason,omitempty"`
+	RequestedBy string `json:"requested_by,omitempty"` // User asking for the refund
+}
+
+// RefundFilter narrows the refunds of a report
+type RefundFilter struct {
+	ChannelID string
+	Status    RefundStatus
+	From      *time.Time
+	To        *time.Time
+}
+
+// RefundReport is the audit report of the refunds of a channel
+type RefundReport struct {
+	Refunds []*Refund      `json:"refunds"`
+	Totals  []RefundTotals `json:"totals"`
+}
+
+// RefundTotals sums the refunds of a report by currency and status
+type RefundTotals struct {
+	Currency string       `json:"currency"`
+	Status   RefundStatus `json:"status"`
+	Count    int          `json:"count"`
+	Amount   int64        `json:"amount"`
 }
 
 // =============================================================================
@@ -255,11 +332,23 @@ type PaymentStats struct {
 	FailedPayments    int                      `json:"failed_payments"`
 	TotalAmount       int64                    `json:"total_amount"`
 	RefundedAmount    int64                    `json:"refunded_amount"`
-	Currency          string                   `json:"currency"`
+	Currency          string                   `json:"currency"` // Empty when payments use several currencies, see ByCurrency
 	SuccessRate       float64                  `json:"success_rate"`
 	ByStatus          map[PaymentStatus]int    `json:"by_status"`
 	ByMethod          map[PaymentMethod]int    `json:"by_method"`
 	DailyStats        []DailyPaymentStats      `json:"daily_stats"`
+	ByCurrency        []CurrencyPaymentStats   `json:"by_currency"`
+}
+
+// CurrencyPaymentStats represents payment totals in one currency. Amounts of
+// different currencies are never added together.
+type CurrencyPaymentStats struct {
+	Currency           string `json:"currency"`
+	TotalPayments      int    `json:"total_payments"`
+	SuccessfulPayments int    `json:"successful_payments"`
+	TotalAmount        int64  `json:"total_amount"`
+	RefundedAmount     int64  `json:"refunded_amount"`
+	TaxAmount          int64  `json:"tax_amount"`
 }
 
 // DailyPaymentStats represents daily payment statistics
@@ -292,7 +381,7 @@ type PaymentMessage struct {
 type Amount struct {
 	Value    int64  `json:"value"` // In cents
 	Currency string `json:"currency"`
-	Offset   int    `json:"offset"` // Decimal places (e.g., 100 for cents)
+	Offset   int    `json:"offset"` // Minor units per unit (e.g., 100 for cents), see CurrencyOffset
 }
 
 // PaymentLink represents a payment link in a message
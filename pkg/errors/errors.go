@@ -35,6 +35,9 @@ const (
 	ErrCodeChannelDisconnected ErrorCode = "CHANNEL_DISCONNECTED"
 	ErrCodeChannelError        ErrorCode = "CHANNEL_ERROR"
 
+	// Automation errors
+	ErrCodeBotPaused ErrorCode = "BOT_PAUSED"
+
 	// Rate limiting
 	ErrCodeRateLimited ErrorCode = "RATE_LIMITED"
 
@@ -114,7 +117,7 @@ func codeToStatus(code ErrorCode) int {
 		return http.StatusUnauthorized
 	case ErrCodeForbidden:
 		return http.StatusForbidden
-	case ErrCodeConflict:
+	case ErrCodeConflict, ErrCodeBotPaused:
 		return http.StatusConflict
 	case ErrCodeRateLimited:
 		return http.StatusTooManyRequests
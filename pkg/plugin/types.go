@@ -18,6 +18,8 @@ const (
 	ChannelTypeFacebook         ChannelType = "facebook"
 	ChannelTypeEmail            ChannelType = "email"
 	ChannelTypeVoice            ChannelType = "voice"
+	ChannelTypeTeams            ChannelType = "teams"
+	ChannelTypeCustom           ChannelType = "custom"
 )
 
 // MessageStatus represents the delivery status of a message
@@ -159,6 +161,7 @@ type InboundMessage struct {
 type StatusCallback struct {
 	MessageID    string        `json:"message_id"`
 	ExternalID   string        `json:"external_id"`
+	Recipient    string        `json:"recipient,omitempty"` // Participant a group message status is for
 	Status       MessageStatus `json:"status"`
 	ErrorMessage string        `json:"error_message,omitempty"`
 	Timestamp    time.Time     `json:"timestamp"`
@@ -18,6 +18,7 @@ type MockProducer struct {
 	InboundMessages  []*nats.InboundMessage
 	StatusUpdates    []*nats.StatusUpdate
 	Events           []*nats.Event
+	Webhooks         []*nats.WebhookDelivery
 	ReturnError      error
 }
 
@@ -70,5 +71,8 @@ func (m *MockProducer) PublishWebhookDelivery(ctx context.Context, webhook *nats
 	if m.ReturnError != nil {
 		return m.ReturnError
 	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Webhooks = append(m.Webhooks, webhook)
 	return nil
 }
@@ -3,9 +3,13 @@ package testutil
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/msgfy/linktor/internal/domain/entity"
 	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
 )
 
 // ============================================================================
@@ -14,16 +18,18 @@ import (
 
 // MockContactRepository is a mock implementation of repository.ContactRepository
 type MockContactRepository struct {
-	Contacts    map[string]*entity.Contact
-	Identities  map[string][]*entity.ContactIdentity
-	ReturnError error
+	Contacts      map[string]*entity.Contact
+	Identities    map[string][]*entity.ContactIdentity
+	LastContacted map[string]time.Time // Contact ID to last message time, for segments
+	ReturnError   error
 }
 
 // NewMockContactRepository creates a new MockContactRepository
 func NewMockContactRepository() *MockContactRepository {
 	return &MockContactRepository{
-		Contacts:   make(map[string]*entity.Contact),
-		Identities: make(map[string][]*entity.ContactIdentity),
+		Contacts:      make(map[string]*entity.Contact),
+		Identities:    make(map[string][]*entity.ContactIdentity),
+		LastContacted: make(map[string]time.Time),
 	}
 }
 
@@ -41,7 +47,7 @@ func (m *MockContactRepository) FindByID(ctx context.Context, id string) (*entit
 	}
 	contact, ok := m.Contacts[id]
 	if !ok {
-		return nil, fmt.Errorf("contact not found: %s", id)
+		return nil, errors.New(errors.ErrCodeContactNotFound, fmt.Sprintf("contact not found: %s", id))
 	}
 	return contact, nil
 }
@@ -162,6 +168,52 @@ func (m *MockContactRepository) FindIdentitiesByContact(ctx context.Context, con
 	return identities, nil
 }
 
+func (m *MockContactRepository) FindIdentityByUsername(ctx context.Context, tenantID, channelType, username string) (*entity.ContactIdentity, error) {
+	if m.ReturnError != nil {
+		return nil, m.ReturnError
+	}
+	for contactID, identities := range m.Identities {
+		if c, ok := m.Contacts[contactID]; !ok || c.TenantID != tenantID {
+			continue
+		}
+		for _, identity := range identities {
+			if identity.ChannelType == channelType && strings.EqualFold(identity.Metadata["username"], username) {
+				return identity, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("identity not found by username: %s", username)
+}
+
+func (m *MockContactRepository) FindBySegment(ctx context.Context, tenantID string, segment *entity.ContactSegment, params *repository.ListParams) ([]*entity.Contact, int64, error) {
+	if m.ReturnError != nil {
+		return nil, 0, m.ReturnError
+	}
+	extra := &entity.ContactSegment{Match: entity.SegmentMatchAll}
+	contactID := ""
+	if params != nil {
+		extra.Rules, _ = params.Filters["rules"].([]entity.SegmentRule)
+		contactID, _ = params.Filters["contact_id"].(string)
+	}
+
+	now := time.Now()
+	var result []*entity.Contact
+	for _, c := range m.Contacts {
+		if c.TenantID != tenantID || (contactID != "" && c.ID != contactID) {
+			continue
+		}
+		var lastContactedAt *time.Time
+		if at, ok := m.LastContacted[c.ID]; ok {
+			lastContactedAt = &at
+		}
+		if segment.Matches(c, lastContactedAt, now) && extra.Matches(c, lastContactedAt, now) {
+			result = append(result, c)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result, int64(len(result)), nil
+}
+
 // ============================================================================
 // MockConversationRepository
 // ============================================================================
@@ -193,7 +245,7 @@ func (m *MockConversationRepository) FindByID(ctx context.Context, id string) (*
 	}
 	conv, ok := m.Conversations[id]
 	if !ok {
-		return nil, fmt.Errorf("conversation not found: %s", id)
+		return nil, errors.New(errors.ErrCodeConversationNotFound, fmt.Sprintf("conversation not found: %s", id))
 	}
 	return conv, nil
 }
@@ -255,13 +307,59 @@ func (m *MockConversationRepository) FindOpenByContactAndChannel(ctx context.Con
 		return nil, m.ReturnError
 	}
 	for _, c := range m.Conversations {
-		if c.ContactID == contactID && c.ChannelID == channelID && c.IsOpen() {
+		if c.ContactID == contactID && c.ChannelID == channelID && c.IsOpen() && !c.IsGroup() {
 			return c, nil
 		}
 	}
 	return nil, fmt.Errorf("no open conversation found for contact %s on channel %s", contactID, channelID)
 }
 
+func (m *MockConversationRepository) FindByGroup(ctx context.Context, channelID, groupID string) (*entity.Conversation, error) {
+	if m.ReturnError != nil {
+		return nil, m.ReturnError
+	}
+	var latest *entity.Conversation
+	for _, c := range m.Conversations {
+		if c.ChannelID == channelID && c.GroupID() == groupID && (latest == nil || c.CreatedAt.After(latest.CreatedAt)) {
+			latest = c
+		}
+	}
+	return latest, nil
+}
+
+func (m *MockConversationRepository) FindLastResolvedByContactAndChannel(ctx context.Context, contactID, channelID string, since time.Time) (*entity.Conversation, error) {
+	if m.ReturnError != nil {
+		return nil, m.ReturnError
+	}
+	var last *entity.Conversation
+	for _, c := range m.Conversations {
+		if c.ContactID != contactID || c.ChannelID != channelID || c.Status != entity.ConversationStatusResolved || c.IsGroup() {
+			continue
+		}
+		if c.ResolvedAt == nil || c.ResolvedAt.Before(since) {
+			continue
+		}
+		if last == nil || c.ResolvedAt.After(*last.ResolvedAt) {
+			last = c
+		}
+	}
+	return last, nil
+}
+
+func (m *MockConversationRepository) FindFollowUps(ctx context.Context, conversationID string) ([]*entity.Conversation, error) {
+	if m.ReturnError != nil {
+		return nil, m.ReturnError
+	}
+	var result []*entity.Conversation
+	for _, c := range m.Conversations {
+		if c.Metadata[entity.MetadataFollowUpOf] == conversationID {
+			result = append(result, c)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	return result, nil
+}
+
 func (m *MockConversationRepository) Update(ctx context.Context, conversation *entity.Conversation) error {
 	if m.ReturnError != nil {
 		return m.ReturnError
@@ -270,6 +368,23 @@ func (m *MockConversationRepository) Update(ctx context.Context, conversation *e
 	return nil
 }
 
+func (m *MockConversationRepository) SetMetadata(ctx context.Context, id string, values map[string]string) error {
+	if m.ReturnError != nil {
+		return m.ReturnError
+	}
+	conv, ok := m.Conversations[id]
+	if !ok {
+		return fmt.Errorf("conversation not found: %s", id)
+	}
+	if conv.Metadata == nil {
+		conv.Metadata = make(map[string]string)
+	}
+	for key, value := range values {
+		conv.Metadata[key] = value
+	}
+	return nil
+}
+
 func (m *MockConversationRepository) UpdateStatus(ctx context.Context, id string, status entity.ConversationStatus) error {
 	if m.ReturnError != nil {
 		return m.ReturnError
@@ -294,6 +409,39 @@ func (m *MockConversationRepository) UpdateAssignee(ctx context.Context, id stri
 	return nil
 }
 
+func (m *MockConversationRepository) UpdateBotPause(ctx context.Context, id string, pausedBy *string) error {
+	if m.ReturnError != nil {
+		return m.ReturnError
+	}
+	conv, ok := m.Conversations[id]
+	if !ok {
+		return fmt.Errorf("conversation not found: %s", id)
+	}
+	conv.BotPausedBy = pausedBy
+	conv.BotPausedAt = nil
+	if pausedBy != nil {
+		now := time.Now()
+		conv.BotPausedAt = &now
+	}
+	return nil
+}
+
+func (m *MockConversationRepository) UpdateTitle(ctx context.Context, id, title string, source entity.ConversationTitleSource, messageCount int) error {
+	if m.ReturnError != nil {
+		return m.ReturnError
+	}
+	conv, ok := m.Conversations[id]
+	if !ok {
+		return fmt.Errorf("conversation not found: %s", id)
+	}
+	now := time.Now()
+	conv.Title = title
+	conv.TitleSource = source
+	conv.TitleMessages = messageCount
+	conv.TitleUpdatedAt = &now
+	return nil
+}
+
 func (m *MockConversationRepository) IncrementUnreadCount(ctx context.Context, id string) error {
 	if m.ReturnError != nil {
 		return m.ReturnError
@@ -378,22 +526,41 @@ func (m *MockConversationRepository) CountWaiting(ctx context.Context, tenantID
 	return count, nil
 }
 
+func (m *MockConversationRepository) FindAwaitingFirstReply(ctx context.Context, limit int) ([]*entity.Conversation, error) {
+	if m.ReturnError != nil {
+		return nil, m.ReturnError
+	}
+	var result []*entity.Conversation
+	for _, c := range m.Conversations {
+		if c.AssignedUserID != nil && c.FirstReplyAt == nil && c.IsOpen() {
+			result = append(result, c)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
 // ============================================================================
 // MockMessageRepository
 // ============================================================================
 
 // MockMessageRepository is a mock implementation of repository.MessageRepository
 type MockMessageRepository struct {
-	Messages    map[string]*entity.Message
-	Attachments map[string][]*entity.MessageAttachment
-	ReturnError error
+	Messages             map[string]*entity.Message
+	Attachments          map[string][]*entity.MessageAttachment
+	ConversationContacts map[string]string // conversationID -> contactID
+	ReturnError          error
 }
 
 // NewMockMessageRepository creates a new MockMessageRepository
 func NewMockMessageRepository() *MockMessageRepository {
 	return &MockMessageRepository{
-		Messages:    make(map[string]*entity.Message),
-		Attachments: make(map[string][]*entity.MessageAttachment),
+		Messages:             make(map[string]*entity.Message),
+		Attachments:          make(map[string][]*entity.MessageAttachment),
+		ConversationContacts: make(map[string]string),
 	}
 }
 
@@ -441,6 +608,44 @@ func (m *MockMessageRepository) FindByConversation(ctx context.Context, conversa
 	return result, int64(len(result)), nil
 }
 
+func (m *MockMessageRepository) FindByConversationAfter(ctx context.Context, conversationID string, after time.Time, limit int) ([]*entity.Message, error) {
+	if m.ReturnError != nil {
+		return nil, m.ReturnError
+	}
+	var result []*entity.Message
+	for _, msg := range m.Messages {
+		if msg.ConversationID == conversationID && msg.CreatedAt.After(after) {
+			result = append(result, msg)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.Before(result[j].CreatedAt) })
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *MockMessageRepository) FindRecentOutboundByContact(ctx context.Context, contactID string, since time.Time, limit int) ([]*entity.Message, error) {
+	if m.ReturnError != nil {
+		return nil, m.ReturnError
+	}
+	var result []*entity.Message
+	for _, msg := range m.Messages {
+		if m.ConversationContacts[msg.ConversationID] != contactID || msg.SenderType == entity.SenderTypeContact {
+			continue
+		}
+		if msg.Status == entity.MessageStatusFailed || msg.CreatedAt.Before(since) {
+			continue
+		}
+		result = append(result, msg)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].CreatedAt.After(result[j].CreatedAt) })
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
 func (m *MockMessageRepository) Update(ctx context.Context, message *entity.Message) error {
 	if m.ReturnError != nil {
 		return m.ReturnError
@@ -470,6 +675,41 @@ func (m *MockMessageRepository) Delete(ctx context.Context, id string) error {
 	return nil
 }
 
+func (m *MockMessageRepository) FindExpiredEphemeral(ctx context.Context, before time.Time, limit int) ([]*entity.Message, error) {
+	if m.ReturnError != nil {
+		return nil, m.ReturnError
+	}
+	var result []*entity.Message
+	for _, msg := range m.Messages {
+		expiresAt, ok := msg.EphemeralExpiresAt()
+		if !ok || expiresAt.After(before) || msg.Metadata[entity.MetadataEphemeralExpired] != "" {
+			continue
+		}
+		result = append(result, msg)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Metadata[entity.MetadataEphemeralExpiresAt] < result[j].Metadata[entity.MetadataEphemeralExpiresAt]
+	})
+	if limit > 0 && len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *MockMessageRepository) Redact(ctx context.Context, id string, metadata map[string]string) error {
+	if m.ReturnError != nil {
+		return m.ReturnError
+	}
+	msg, ok := m.Messages[id]
+	if !ok {
+		return fmt.Errorf("message not found: %s", id)
+	}
+	msg.Content = ""
+	msg.Metadata = metadata
+	delete(m.Attachments, id)
+	return nil
+}
+
 func (m *MockMessageRepository) CountByConversation(ctx context.Context, conversationID string) (int64, error) {
 	if m.ReturnError != nil {
 		return 0, m.ReturnError
@@ -553,7 +793,7 @@ func (m *MockChannelRepository) FindByID(ctx context.Context, id string) (*entit
 	}
 	ch, ok := m.Channels[id]
 	if !ok {
-		return nil, fmt.Errorf("channel not found: %s", id)
+		return nil, errors.New(errors.ErrCodeChannelNotFound, fmt.Sprintf("channel not found: %s", id))
 	}
 	return ch, nil
 }
@@ -703,3 +943,16 @@ func (m *MockChannelRepository) FindCoexistenceChannels(ctx context.Context) ([]
 	}
 	return result, nil
 }
+
+func (m *MockChannelRepository) FindBySecretRef(ctx context.Context, secretRef string) ([]*entity.Channel, error) {
+	if m.ReturnError != nil {
+		return nil, m.ReturnError
+	}
+	var result []*entity.Channel
+	for _, ch := range m.Channels {
+		if ch.ReferencesSecret(secretRef) {
+			result = append(result, ch)
+		}
+	}
+	return result, nil
+}
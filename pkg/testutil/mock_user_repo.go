@@ -6,6 +6,7 @@ import (
 
 	"github.com/msgfy/linktor/internal/domain/entity"
 	"github.com/msgfy/linktor/internal/domain/repository"
+	"github.com/msgfy/linktor/pkg/errors"
 )
 
 // MockUserRepository is a mock implementation of repository.UserRepository
@@ -61,7 +62,7 @@ func (m *MockUserRepository) FindByTenantAndEmail(ctx context.Context, tenantID,
 			return u, nil
 		}
 	}
-	return nil, fmt.Errorf("user not found: %s/%s", tenantID, email)
+	return nil, errors.New(errors.ErrCodeUserNotFound, fmt.Sprintf("user not found: %s/%s", tenantID, email))
 }
 
 func (m *MockUserRepository) FindByTenant(ctx context.Context, tenantID string, params *repository.ListParams) ([]*entity.User, int64, error) {
@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// NormalizeEmail validates an email address and returns it lowercased. Addresses in
+// "Name <address>" form are reduced to the address.
+func NormalizeEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		return "", fmt.Errorf("email is empty")
+	}
+
+	address, err := mail.ParseAddress(email)
+	if err != nil {
+		return "", fmt.Errorf("email %q is not a valid address", email)
+	}
+
+	normalized := strings.ToLower(address.Address)
+	at := strings.LastIndex(normalized, "@")
+	local, domain := normalized[:at], normalized[at+1:]
+	if len(normalized) > 254 || len(local) > 64 {
+		return "", fmt.Errorf("email %q is too long", email)
+	}
+	if !validEmailDomain(domain) {
+		return "", fmt.Errorf("email %q has an invalid domain", email)
+	}
+	return normalized, nil
+}
+
+// validEmailDomain checks a domain is a dotted host name with an alphabetic top
+// level domain. IP literals are not accepted.
+func validEmailDomain(domain string) bool {
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for _, label := range labels {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+				return false
+			}
+		}
+	}
+	tld := labels[len(labels)-1]
+	if len(tld) < 2 {
+		return false
+	}
+	for _, r := range tld {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
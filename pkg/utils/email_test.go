@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	email, err := NormalizeEmail("  Ana.Souza@Example.COM ")
+	require.NoError(t, err)
+	assert.Equal(t, "ana.souza@example.com", email)
+
+	email, err = NormalizeEmail("Ana Souza <ana+news@mail.example.com.br>")
+	require.NoError(t, err)
+	assert.Equal(t, "ana+news@mail.example.com.br", email)
+}
+
+func TestNormalizeEmail_Invalid(t *testing.T) {
+	for _, email := range []string{
+		"",
+		"ana",
+		"ana@",
+		"@example.com",
+		"ana@localhost",
+		"ana@example",
+		"ana@-example.com",
+		"ana@example.c",
+		"ana@[192.168.0.1]",
+		"ana souza@example.com",
+		strings.Repeat("a", 65) + "@example.com",
+	} {
+		_, err := NormalizeEmail(email)
+		assert.Error(t, err, email)
+	}
+}
@@ -0,0 +1,176 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// phoneRegion holds the numbering rules of a country: its calling code, the trunk
+// prefix dialed before national numbers and the lengths of a national significant
+// number (the number without country code or trunk prefix)
+type phoneRegion struct {
+	CallingCode string
+	TrunkPrefix string
+	MinLength   int
+	MaxLength   int
+}
+
+// phoneRegions are the regions with known numbering rules, keyed by ISO 3166 code.
+// Numbers of other countries are accepted when they fit the general E.164 limits.
+var phoneRegions = map[string]phoneRegion{
+	"AR": {CallingCode: "54", TrunkPrefix: "0", MinLength: 10, MaxLength: 11}, // Mobiles carry a 9 in international format
+	"AU": {CallingCode: "61", TrunkPrefix: "0", MinLength: 9, MaxLength: 9},
+	"BR": {CallingCode: "55", TrunkPrefix: "0", MinLength: 10, MaxLength: 11},
+	"CA": {CallingCode: "1", TrunkPrefix: "1", MinLength: 10, MaxLength: 10},
+	"CL": {CallingCode: "56", MinLength: 9, MaxLength: 9},
+	"CO": {CallingCode: "57", MinLength: 10, MaxLength: 10},
+	"DE": {CallingCode: "49", TrunkPrefix: "0", MinLength: 6, MaxLength: 13},
+	"ES": {CallingCode: "34", MinLength: 9, MaxLength: 9},
+	"FR": {CallingCode: "33", TrunkPrefix: "0", MinLength: 9, MaxLength: 9},
+	"GB": {CallingCode: "44", TrunkPrefix: "0", MinLength: 9, MaxLength: 10},
+	"IN": {CallingCode: "91", TrunkPrefix: "0", MinLength: 10, MaxLength: 10},
+	"IT": {CallingCode: "39", MinLength: 6, MaxLength: 11},
+	"MX": {CallingCode: "52", MinLength: 10, MaxLength: 10},
+	"PE": {CallingCode: "51", TrunkPrefix: "0", MinLength: 8, MaxLength: 9},
+	"PT": {CallingCode: "351", MinLength: 9, MaxLength: 9},
+	"PY": {CallingCode: "595", TrunkPrefix: "0", MinLength: 9, MaxLength: 9},
+	"US": {CallingCode: "1", TrunkPrefix: "1", MinLength: 10, MaxLength: 10},
+	"UY": {CallingCode: "598", TrunkPrefix: "0", MinLength: 8, MaxLength: 8},
+}
+
+// callingCodeRegions maps calling codes to their numbering rules. Regions sharing a
+// calling code (US and CA) share their rules.
+var callingCodeRegions = func() map[string]phoneRegion {
+	regions := make(map[string]phoneRegion, len(phoneRegions))
+	for _, region := range phoneRegions {
+		regions[region.CallingCode] = region
+	}
+	return regions
+}()
+
+// valid checks a national significant number, which never starts with the trunk
+// prefix and must fit the region's lengths
+func (r phoneRegion) valid(nsn string) bool {
+	if r.TrunkPrefix != "" && strings.HasPrefix(nsn, r.TrunkPrefix) {
+		return false
+	}
+	return len(nsn) >= r.MinLength && len(nsn) <= r.MaxLength
+}
+
+// IsPhoneRegion reports whether a region code has known numbering rules
+func IsPhoneRegion(region string) bool {
+	_, ok := phoneRegions[strings.ToUpper(region)]
+	return ok
+}
+
+// IsE164 reports whether a phone number is already in E.164 format
+func IsE164(phone string) bool {
+	if len(phone) < 9 || len(phone) > 16 || phone[0] != '+' || phone[1] == '0' {
+		return false
+	}
+	for _, r := range phone[1:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatE164 formats a phone number as E.164 ("+5511999998888"). Numbers written
+// with "+" or an international prefix ("00", "011" in North America) are read as
+// international; other numbers are read as national numbers of the default region,
+// dropping the trunk prefix, unless they already start with the region's calling
+// code. Without a default region a number must carry its country code.
+func FormatE164(phone, defaultRegion string) (string, error) {
+	raw := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(phone), "tel:"))
+	if raw == "" {
+		return "", fmt.Errorf("phone number is empty")
+	}
+
+	var digits strings.Builder
+	international := false
+	for i, r := range raw {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+			international = true
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')' || r == '/':
+		default:
+			return "", fmt.Errorf("phone number %q contains invalid characters", phone)
+		}
+	}
+
+	number := digits.String()
+	region, hasRegion := phoneRegions[strings.ToUpper(defaultRegion)]
+	if !international {
+		switch {
+		case strings.HasPrefix(number, "00"):
+			number, international = number[2:], true
+		case strings.HasPrefix(number, "011") && hasRegion && region.CallingCode == "1":
+			number, international = number[3:], true
+		}
+	}
+
+	if international || !hasRegion {
+		return formatInternational(phone, number)
+	}
+
+	if nsn, ok := nationalNumber(number, region); ok {
+		return "+" + region.CallingCode + nsn, nil
+	}
+	// Written with the country code but without "+"
+	if strings.HasPrefix(number, region.CallingCode) {
+		return formatInternational(phone, number)
+	}
+	return "", fmt.Errorf("phone number %q is not valid for region %s", phone, strings.ToUpper(defaultRegion))
+}
+
+// nationalNumber extracts the national significant number from a nationally
+// written number
+func nationalNumber(number string, region phoneRegion) (string, bool) {
+	if region.TrunkPrefix == "" || !strings.HasPrefix(number, region.TrunkPrefix) {
+		return number, region.valid(number)
+	}
+	nsn := strings.TrimPrefix(number, region.TrunkPrefix)
+	if region.valid(nsn) {
+		return nsn, true
+	}
+	// Brazilian long distance calls select a carrier after the trunk prefix: 0 21 11 ...
+	if region.CallingCode == "55" && len(nsn) > 2 && region.valid(nsn[2:]) {
+		return nsn[2:], true
+	}
+	return "", false
+}
+
+// formatInternational formats a number that starts with its calling code
+func formatInternational(phone, number string) (string, error) {
+	if number == "" || number[0] == '0' {
+		return "", fmt.Errorf("phone number %q has no valid country code", phone)
+	}
+
+	for size := 1; size <= 3 && size < len(number); size++ {
+		region, ok := callingCodeRegions[number[:size]]
+		if !ok {
+			continue
+		}
+		nsn := number[size:]
+		// Mexican mobiles used to be dialed with a 1 after the country code
+		if region.CallingCode == "52" && len(nsn) == 11 && nsn[0] == '1' {
+			nsn = nsn[1:]
+		}
+		// A trunk prefix kept after the country code: +44 (0) 20 ...
+		if !region.valid(nsn) && region.TrunkPrefix != "" && region.TrunkPrefix != "1" && strings.HasPrefix(nsn, region.TrunkPrefix) {
+			nsn = strings.TrimPrefix(nsn, region.TrunkPrefix)
+		}
+		if !region.valid(nsn) {
+			return "", fmt.Errorf("phone number %q has an invalid length for country code +%s", phone, region.CallingCode)
+		}
+		return "+" + region.CallingCode + nsn, nil
+	}
+
+	if len(number) < 8 || len(number) > 15 {
+		return "", fmt.Errorf("phone number %q must have between 8 and 15 digits including the country code", phone)
+	}
+	return "+" + number, nil
+}
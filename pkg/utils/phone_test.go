@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatE164(t *testing.T) {
+	tests := []struct {
+		phone  string
+		region string
+		want   string
+	}{
+		{"+55 (11) 99999-8888", "", "+5511999998888"},
+		{"(11) 99999-8888", "BR", "+5511999998888"},
+		{"011 99999-8888", "BR", "+5511999998888"},
+		{"0 21 11 99999-8888", "BR", "+5511999998888"},
+		{"5511999998888", "BR", "+5511999998888"},
+		{"5511999998888", "", "+5511999998888"},
+		{"0055 11 3333-4444", "US", "+551133334444"},
+		{"(415) 555-1234", "us", "+14155551234"},
+		{"1-415-555-1234", "US", "+14155551234"},
+		{"011 44 20 7946 0958", "US", "+442079460958"},
+		{"+44 (0) 20 7946 0958", "", "+442079460958"},
+		{"+52 1 55 1234 5678", "", "+525512345678"},
+		{"+54 9 11 2345-6789", "", "+5491123456789"},
+		{"tel:+351 912 345 678", "", "+351912345678"},
+		{"+81 90 1234 5678", "BR", "+819012345678"},
+	}
+	for _, tt := range tests {
+		got, err := FormatE164(tt.phone, tt.region)
+		require.NoError(t, err, tt.phone)
+		assert.Equal(t, tt.want, got, tt.phone)
+		assert.True(t, IsE164(got), got)
+	}
+}
+
+func TestFormatE164_Invalid(t *testing.T) {
+	tests := []struct {
+		phone  string
+		region string
+	}{
+		{"", "BR"},
+		{"not a phone", "BR"},
+		{"+55 11 9999", ""},
+		{"99999-8888", "BR"},
+		{"(11) 99999-8888", ""},
+		{"+0 123 456 789", ""},
+		{"+999 1234", ""},
+		{"11 99999-8888 ext 12", "BR"},
+	}
+	for _, tt := range tests {
+		_, err := FormatE164(tt.phone, tt.region)
+		assert.Error(t, err, tt.phone)
+	}
+}
+
+func TestIsE164(t *testing.T) {
+	assert.True(t, IsE164("+5511999998888"))
+	assert.False(t, IsE164("5511999998888"))
+	assert.False(t, IsE164("+55 11 99999"))
+	assert.False(t, IsE164("+0511999998888"))
+}
+
+func TestIsPhoneRegion(t *testing.T) {
+	assert.True(t, IsPhoneRegion("br"))
+	assert.False(t, IsPhoneRegion("ZZ"))
+}
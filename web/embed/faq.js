@@ -0,0 +1,280 @@
+/**
+ * Linktor FAQ Widget
+ * Embeddable help search answering visitor questions from a knowledge base
+ *
+ * Usage:
+ * <div id="linktor-faq"></div>
+ * <script>
+ *   window.LinktorFAQSettings = {
+ *     channelId: 'your-webchat-channel-id',
+ *     baseUrl: 'https://your-linktor-instance.com',
+ *     container: '#linktor-faq'
+ *   };
+ * </script>
+ * <script src="https://your-linktor-instance.com/widget/faq.js" async></script>
+ *
+ * "Talk to a person" starts a webchat conversation with the question and opens
+ * the chat widget, loading it when the page does not embed it already.
+ */
+
+(function() {
+  'use strict';
+
+  // Configuration
+  const settings = window.LinktorFAQSettings || {};
+  const channelId = settings.channelId;
+  const baseUrl = settings.baseUrl || '';
+  const apiUrl = baseUrl + '/api/v1/public/faq/' + channelId;
+
+  if (!channelId) {
+    console.error('Linktor FAQ: channelId is required');
+    return;
+  }
+
+  // Same key as the chat widget, so the escalated conversation continues there
+  const SESSION_KEY = 'linktor_session_' + channelId;
+
+  // State
+  let lastQuestion = '';
+  let lastAnswer = '';
+
+  // Get or create session ID
+  function getSessionId() {
+    let id = sessionStorage.getItem(SESSION_KEY);
+    if (!id) {
+      id = generateUUID();
+      sessionStorage.setItem(SESSION_KEY, id);
+    }
+    return id;
+  }
+
+  // Generate UUID
+  function generateUUID() {
+    return 'xxxxxxxx-xxxx-4xxx-yxxx-xxxxxxxxxxxx'.replace(/[xy]/g, function(c) {
+      const r = Math.random() * 16 | 0;
+      const v = c === 'x' ? r : (r & 0x3 | 0x8);
+      return v.toString(16);
+    });
+  }
+
+  // Create styles
+  function createStyles() {
+    const style = document.createElement('style');
+    style.textContent = `
+      .linktor-faq {
+        font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, Oxygen, Ubuntu, sans-serif;
+        font-size: 14px;
+        max-width: 560px;
+      }
+
+      .linktor-faq-form {
+        display: flex;
+        gap: 8px;
+      }
+
+      .linktor-faq-input {
+        flex: 1;
+        border: 1px solid #ddd;
+        border-radius: 8px;
+        padding: 10px 12px;
+        font-size: 14px;
+        font-family: inherit;
+        outline: none;
+      }
+
+      .linktor-faq-input:focus {
+        border-color: var(--linktor-color, #007bff);
+      }
+
+      .linktor-faq-button {
+        border: none;
+        border-radius: 8px;
+        padding: 10px 16px;
+        background-color: var(--linktor-color, #007bff);
+        color: white;
+        font-size: 14px;
+        cursor: pointer;
+      }
+
+      .linktor-faq-button:disabled {
+        opacity: 0.5;
+        cursor: not-allowed;
+      }
+
+      .linktor-faq-result {
+        margin-top: 12px;
+        display: none;
+      }
+
+      .linktor-faq-result.show {
+        display: block;
+      }
+
+      .linktor-faq-answer {
+        background: #f8f9fa;
+        border-radius: 8px;
+        padding: 12px;
+        line-height: 1.4;
+        white-space: pre-wrap;
+      }
+
+      .linktor-faq-sources {
+        margin: 8px 0 0;
+        padding-left: 18px;
+        color: #666;
+        font-size: 12px;
+      }
+
+      .linktor-faq-escalate {
+        margin-top: 12px;
+        background: none;
+        border: 1px solid var(--linktor-color, #007bff);
+        color: var(--linktor-color, #007bff);
+      }
+    `;
+    document.head.appendChild(style);
+  }
+
+  // Create widget HTML
+  function createWidget(container) {
+    const widget = document.createElement('div');
+    widget.className = 'linktor-faq';
+    widget.innerHTML = `
+      <form class="linktor-faq-form" id="linktor-faq-form">
+        <input class="linktor-faq-input" id="linktor-faq-input" type="text" maxlength="500" placeholder="How can we help?" />
+        <button class="linktor-faq-button" id="linktor-faq-ask" type="submit">Ask</button>
+      </form>
+      <div class="linktor-faq-result" id="linktor-faq-result">
+        <div class="linktor-faq-answer" id="linktor-faq-answer"></div>
+        <ul class="linktor-faq-sources" id="linktor-faq-sources"></ul>
+        <button class="linktor-faq-button linktor-faq-escalate" id="linktor-faq-escalate" type="button">Talk to a person</button>
+      </div>
+    `;
+    container.appendChild(widget);
+    return widget;
+  }
+
+  // Initialize widget
+  function init() {
+    const container = settings.container
+      ? document.querySelector(settings.container)
+      : document.body;
+    if (!container) {
+      console.error('Linktor FAQ: container not found');
+      return;
+    }
+
+    createStyles();
+    createWidget(container);
+
+    // DOM elements
+    const form = document.getElementById('linktor-faq-form');
+    const input = document.getElementById('linktor-faq-input');
+    const askBtn = document.getElementById('linktor-faq-ask');
+    const result = document.getElementById('linktor-faq-result');
+    const answerEl = document.getElementById('linktor-faq-answer');
+    const sourcesEl = document.getElementById('linktor-faq-sources');
+    const escalateBtn = document.getElementById('linktor-faq-escalate');
+
+    form.addEventListener('submit', async (e) => {
+      e.preventDefault();
+      const question = input.value.trim();
+      if (!question) return;
+
+      askBtn.disabled = true;
+      try {
+        const data = await post('/ask', { question: question });
+        lastQuestion = question;
+        lastAnswer = data.answered ? data.answer : '';
+        showAnswer(data);
+      } catch (err) {
+        console.error('Linktor FAQ: Failed to get an answer', err);
+        lastQuestion = question;
+        lastAnswer = '';
+        showAnswer({ answered: false, sources: [] });
+      } finally {
+        askBtn.disabled = false;
+      }
+    });
+
+    escalateBtn.addEventListener('click', async () => {
+      if (!lastQuestion) return;
+
+      escalateBtn.disabled = true;
+      try {
+        await post('/escalate', {
+          session_id: getSessionId(),
+          question: lastQuestion,
+          answer: lastAnswer,
+          name: settings.visitorName || '',
+          email: settings.visitorEmail || ''
+        });
+        openChat(lastQuestion);
+      } catch (err) {
+        console.error('Linktor FAQ: Failed to reach a person', err);
+      } finally {
+        escalateBtn.disabled = false;
+      }
+    });
+
+    // Show the answer, or offer a person when there is none
+    function showAnswer(data) {
+      answerEl.textContent = data.answered
+        ? data.answer
+        : "We couldn't find an answer to that. A person from our team can help.";
+
+      sourcesEl.innerHTML = '';
+      (data.sources || []).forEach((source) => {
+        const item = document.createElement('li');
+        item.textContent = source.question;
+        sourcesEl.appendChild(item);
+      });
+
+      result.classList.add('show');
+    }
+  }
+
+  // Post to the FAQ API and return the response data
+  async function post(path, body) {
+    const response = await fetch(apiUrl + path, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify(body)
+    });
+    const json = await response.json();
+    if (!response.ok || !json.success) {
+      throw new Error((json.error && json.error.message) || 'request failed');
+    }
+    return json.data;
+  }
+
+  // Open the chat widget with the escalated question, loading it when needed
+  function openChat(question) {
+    if (window.Linktor) {
+      window.Linktor.open({ message: question });
+      return;
+    }
+
+    window.LinktorSettings = window.LinktorSettings || {
+      channelId: channelId,
+      baseUrl: baseUrl,
+      visitorName: settings.visitorName,
+      visitorEmail: settings.visitorEmail
+    };
+    const script = document.createElement('script');
+    script.src = baseUrl + '/widget/widget.js';
+    script.onload = () => {
+      if (window.Linktor) {
+        window.Linktor.open({ message: question });
+      }
+    };
+    document.body.appendChild(script);
+  }
+
+  // Initialize when DOM is ready
+  if (document.readyState === 'loading') {
+    document.addEventListener('DOMContentLoaded', init);
+  } else {
+    init();
+  }
+})();
@@ -10,6 +10,9 @@
  *   };
  * </script>
  * <script src="https://your-linktor-instance.com/widget.js" async></script>
+ *
+ * Once loaded, window.Linktor.open() opens the chat. Passing { message } shows a
+ * message the visitor already sent, e.g. a question escalated from the FAQ widget.
  */
 
 (function() {
@@ -386,6 +389,25 @@
       }
     });
 
+    // Public API
+    window.Linktor = {
+      open: function(options) {
+        isOpen = true;
+        chat.classList.add('open');
+        if (!ws) {
+          connect();
+        }
+        if (options && options.message) {
+          addMessage({
+            content_type: 'text',
+            content: options.message,
+            sender_type: 'contact',
+            timestamp: new Date().toISOString()
+          });
+        }
+      }
+    };
+
     closeBtn.addEventListener('click', () => {
       isOpen = false;
       chat.classList.remove('open');